@@ -6,12 +6,13 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
-	"sort"
-	"strings"
+	"strconv"
 	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/database"
+	"github.com/opena2a/identity/backend/migrations"
 )
 
 const (
@@ -21,18 +22,40 @@ const (
 	colorGreen  = "\033[32m"
 	colorYellow = "\033[33m"
 	colorBlue   = "\033[34m"
-	colorPurple = "\033[35m"
 	colorCyan   = "\033[36m"
-	colorWhite  = "\033[37m"
 )
 
-type Migration struct {
-	Version  string
-	Filename string
-	SQL      string
-}
+const usage = `Usage: migrate [command]
+
+Commands:
+  up             Apply the pending migrations (default)
+  status         Show the schema version, pending migrations and history
+  force VERSION  Record VERSION as the clean schema version, after the migration that left the
+                 schema dirty was completed or undone by hand
+
+Environment:
+  DATABASE_URL             PostgreSQL connection URL (required)
+  MIGRATIONS_LOCK_TIMEOUT  How long each online migration statement waits for locks (default: 5s)
+`
 
 func main() {
+	command := "up"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+	forceVersion := 0
+	switch {
+	case command == "force" && len(os.Args) == 3:
+		var err error
+		if forceVersion, err = strconv.Atoi(os.Args[2]); err != nil {
+			fmt.Print(usage)
+			os.Exit(2)
+		}
+	case command != "up" && command != "status":
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+
 	// Get database URL from environment
 	databaseURL := os.Getenv("DATABASE_URL")
 	if databaseURL == "" {
@@ -54,227 +77,107 @@ func main() {
 		log.Fatalf("❌ Failed to ping database: %v", err)
 	}
 
+	lockTimeout := 5 * time.Second
+	if value := os.Getenv("MIGRATIONS_LOCK_TIMEOUT"); value != "" {
+		if lockTimeout, err = time.ParseDuration(value); err != nil {
+			log.Fatalf("❌ Invalid MIGRATIONS_LOCK_TIMEOUT: %v", err)
+		}
+	}
+	migrator := database.NewMigrator(db, migrations.Files, lockTimeout)
+
 	fmt.Printf("%s════════════════════════════════════════%s\n", colorCyan, colorReset)
 	fmt.Printf("%s  AIM Database Migration System%s\n", colorCyan, colorReset)
 	fmt.Printf("%s════════════════════════════════════════%s\n\n", colorCyan, colorReset)
 
-	// Create schema_migrations table if it doesn't exist
-	if err := ensureMigrationsTable(ctx, db); err != nil {
-		log.Fatalf("❌ Failed to create migrations table: %v", err)
-	}
-
-	// Check if database is empty (fresh deployment)
-	isFresh, err := isDatabaseFresh(ctx, db)
-	if err != nil {
-		log.Fatalf("❌ Failed to check database state: %v", err)
-	}
-
-	if isFresh {
-		fmt.Printf("%s🆕 Fresh database detected%s\n", colorGreen, colorReset)
-		fmt.Printf("   Using consolidated V1 schema for fast deployment\n\n")
-		
-		if err := applyConsolidatedSchema(ctx, db); err != nil {
-			log.Fatalf("❌ Failed to apply consolidated schema: %v", err)
-		}
-	} else {
-		fmt.Printf("%s📦 Existing database detected%s\n", colorYellow, colorReset)
-		fmt.Printf("   Using incremental migrations\n\n")
-		
-		if err := applyIncrementalMigrations(ctx, db); err != nil {
-			log.Fatalf("❌ Failed to apply incremental migrations: %v", err)
+	if command == "status" {
+		if err := printStatus(context.Background(), migrator); err != nil {
+			log.Fatalf("❌ Failed to get schema status: %v", err)
 		}
+		return
 	}
 
-	fmt.Printf("\n%s════════════════════════════════════════%s\n", colorGreen, colorReset)
-	fmt.Printf("%s  ✅ All migrations applied successfully%s\n", colorGreen, colorReset)
-	fmt.Printf("%s════════════════════════════════════════%s\n\n", colorGreen, colorReset)
-}
-
-func ensureMigrationsTable(ctx context.Context, db *sql.DB) error {
-	query := `
-		CREATE TABLE IF NOT EXISTS schema_migrations (
-			version VARCHAR(255) PRIMARY KEY,
-			applied_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
-		)
-	`
-	_, err := db.ExecContext(ctx, query)
-	return err
-}
-
-func isDatabaseFresh(ctx context.Context, db *sql.DB) (bool, error) {
-	// Check if organizations table exists
-	var exists bool
-	err := db.QueryRowContext(ctx, `
-		SELECT EXISTS (
-			SELECT FROM information_schema.tables 
-			WHERE table_schema = 'public' 
-			AND table_name = 'organizations'
-		)
-	`).Scan(&exists)
-	
-	if err != nil {
-		return false, err
+	if command == "force" {
+		if err := migrator.Force(context.Background(), forceVersion); err != nil {
+			log.Fatalf("❌ Failed to force schema version: %v", err)
+		}
+		fmt.Printf("%s✓ Schema version set to %d%s\n", colorGreen, forceVersion, colorReset)
+		return
 	}
 
-	return !exists, nil
-}
-
-func applyConsolidatedSchema(ctx context.Context, db *sql.DB) error {
-	// Read V1 consolidated schema
-	schemaPath := "migrations/V1__consolidated_schema.sql"
-	
-	content, err := os.ReadFile(schemaPath)
-	if err != nil {
-		return fmt.Errorf("failed to read consolidated schema: %w", err)
+	// Migrations on large tables may run far longer than the connection check allows
+	applied, err := migrator.Apply(context.Background())
+	for _, migration := range applied {
+		fmt.Printf("%s✓ %s%s (%dms)\n", colorGreen, migration.Version, colorReset, migration.DurationMs)
 	}
-
-	fmt.Printf("%s⚡ Applying consolidated V1 schema...%s\n", colorBlue, colorReset)
-	
-	// Execute schema in a transaction
-	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		fmt.Printf("\n%s❌ %v%s\n", colorRed, err, colorReset)
+		os.Exit(1)
 	}
-	defer tx.Rollback()
 
-	if _, err := tx.ExecContext(ctx, string(content)); err != nil {
-		return fmt.Errorf("failed to execute consolidated schema: %w", err)
-	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	if len(applied) == 0 {
+		fmt.Printf("%s✓ No pending migrations%s\n", colorGreen, colorReset)
 	}
-
-	fmt.Printf("%s✓ Consolidated schema applied%s\n", colorGreen, colorReset)
-	return nil
+	fmt.Printf("\n%s════════════════════════════════════════%s\n", colorGreen, colorReset)
+	fmt.Printf("%s  ✅ All migrations applied successfully%s\n", colorGreen, colorReset)
+	fmt.Printf("%s════════════════════════════════════════%s\n\n", colorGreen, colorReset)
 }
 
-func applyIncrementalMigrations(ctx context.Context, db *sql.DB) error {
-	// Get already applied migrations
-	applied, err := getAppliedMigrations(ctx, db)
-	if err != nil {
-		return fmt.Errorf("failed to get applied migrations: %w", err)
-	}
-
-	// Read all migration files
-	migrations, err := readMigrationFiles("migrations")
+func printStatus(ctx context.Context, migrator *database.Migrator) error {
+	status, err := migrator.Status(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to read migration files: %w", err)
-	}
-
-	// Filter out already applied migrations
-	pending := filterPendingMigrations(migrations, applied)
-
-	if len(pending) == 0 {
-		fmt.Printf("%s✓ No pending migrations%s\n", colorGreen, colorReset)
-		return nil
+		return err
 	}
 
-	fmt.Printf("%s📝 Found %d pending migration(s)%s\n\n", colorYellow, len(pending), colorReset)
-
-	// Apply each pending migration
-	for _, migration := range pending {
-		fmt.Printf("%s▶ Applying: %s%s\n", colorBlue, migration.Filename, colorReset)
-		
-		if err := applyMigration(ctx, db, migration); err != nil {
-			return fmt.Errorf("failed to apply migration %s: %w", migration.Filename, err)
-		}
-
-		fmt.Printf("%s  ✓ Applied%s\n", colorGreen, colorReset)
+	current := status.CurrentVersion
+	if current == "" {
+		current = "(empty database)"
 	}
+	fmt.Printf("Current version: %s\n", current)
+	fmt.Printf("Latest version:  %s\n\n", status.LatestVersion)
 
-	return nil
-}
-
-func getAppliedMigrations(ctx context.Context, db *sql.DB) (map[string]bool, error) {
-	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations")
-	if err != nil {
-		return nil, err
+	if status.Dirty {
+		fmt.Printf("%s❌ %s failed part way; complete or undo it by hand, then run: migrate force VERSION%s\n\n", colorRed, current, colorReset)
 	}
-	defer rows.Close()
 
-	applied := make(map[string]bool)
-	for rows.Next() {
-		var version string
-		if err := rows.Scan(&version); err != nil {
-			return nil, err
+	if len(status.Pending) == 0 {
+		fmt.Printf("%s✓ No pending migrations%s\n", colorGreen, colorReset)
+	} else {
+		fmt.Printf("%s📝 %d pending migration(s)%s\n", colorYellow, len(status.Pending), colorReset)
+		for _, migration := range status.Pending {
+			fmt.Printf("   %s%s\n", migration.Version, onlineLabel(migration))
 		}
-		applied[version] = true
-	}
-
-	return applied, rows.Err()
-}
-
-func readMigrationFiles(dir string) ([]Migration, error) {
-	files, err := os.ReadDir(dir)
-	if err != nil {
-		return nil, err
 	}
 
-	var migrations []Migration
-	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".sql") {
-			continue
+	var warnings []string
+	for _, migration := range status.History {
+		if migration.Modified {
+			warnings = append(warnings, fmt.Sprintf("%s was modified after it was applied", migration.Version))
 		}
-
-		// Skip consolidated schema (only for fresh deployments)
-		if strings.HasPrefix(file.Name(), "V1__consolidated") {
-			continue
+		if migration.Missing {
+			warnings = append(warnings, fmt.Sprintf("%s is applied but its file is missing", migration.Version))
 		}
-
-		// Read file content
-		content, err := os.ReadFile(filepath.Join(dir, file.Name()))
-		if err != nil {
-			return nil, fmt.Errorf("failed to read %s: %w", file.Name(), err)
+	}
+	if len(warnings) > 0 {
+		fmt.Println()
+		for _, warning := range warnings {
+			fmt.Printf("%s⚠️  %s%s\n", colorYellow, warning, colorReset)
 		}
-
-		// Extract version from filename (e.g., "001_initial_schema.sql" -> "001")
-		version := strings.TrimSuffix(file.Name(), ".sql")
-
-		migrations = append(migrations, Migration{
-			Version:  version,
-			Filename: file.Name(),
-			SQL:      string(content),
-		})
 	}
 
-	// Sort migrations by version
-	sort.Slice(migrations, func(i, j int) bool {
-		return migrations[i].Version < migrations[j].Version
-	})
-
-	return migrations, nil
-}
-
-func filterPendingMigrations(migrations []Migration, applied map[string]bool) []Migration {
-	var pending []Migration
-	for _, m := range migrations {
-		if !applied[m.Version] {
-			pending = append(pending, m)
+	fmt.Printf("\n%sRecently applied:%s\n", colorBlue, colorReset)
+	for i, migration := range status.History {
+		if i == 10 {
+			fmt.Printf("   … %d more\n", len(status.History)-i)
+			break
 		}
+		fmt.Printf("   %s  %s%s\n", migration.AppliedAt.Format(time.RFC3339), migration.Version, onlineLabel(migration))
 	}
-	return pending
+	return nil
 }
 
-func applyMigration(ctx context.Context, db *sql.DB, migration Migration) error {
-	tx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
+func onlineLabel(migration domain.SchemaMigration) string {
+	if migration.Online {
+		return " (online)"
 	}
-	defer tx.Rollback()
-
-	// Execute migration
-	if _, err := tx.ExecContext(ctx, migration.SQL); err != nil {
-		return err
-	}
-
-	// Record migration
-	_, err = tx.ExecContext(ctx,
-		"INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)",
-		migration.Version, time.Now())
-	if err != nil {
-		return err
-	}
-
-	return tx.Commit()
+	return ""
 }
@@ -2,15 +2,15 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
 	"fmt"
-	"io/ioutil"
 	"log"
+	"maps"
 	"os"
 	"os/signal"
-	"path/filepath"
-	"sort"
-	"strings"
+	"slices"
 	"syscall"
 	"time"
 
@@ -27,11 +27,29 @@ import (
 	"github.com/opena2a/identity/backend/internal/domain"
 	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
 	"github.com/opena2a/identity/backend/internal/infrastructure/cache"
+	"github.com/opena2a/identity/backend/internal/infrastructure/captcha"
+	"github.com/opena2a/identity/backend/internal/infrastructure/database"
 	"github.com/opena2a/identity/backend/internal/infrastructure/email"
+	"github.com/opena2a/identity/backend/internal/infrastructure/eventbus"
+	"github.com/opena2a/identity/backend/internal/infrastructure/export"
+	"github.com/opena2a/identity/backend/internal/infrastructure/geoip"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 	"github.com/opena2a/identity/backend/internal/infrastructure/metrics"
+	"github.com/opena2a/identity/backend/internal/infrastructure/notification"
+	"github.com/opena2a/identity/backend/internal/infrastructure/opa"
+	"github.com/opena2a/identity/backend/internal/infrastructure/osv"
+	"github.com/opena2a/identity/backend/internal/infrastructure/report"
 	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
+	"github.com/opena2a/identity/backend/internal/infrastructure/siem"
+	"github.com/opena2a/identity/backend/internal/infrastructure/storage"
+	"github.com/opena2a/identity/backend/internal/infrastructure/ticketing"
+	"github.com/opena2a/identity/backend/internal/infrastructure/tracing"
+	"github.com/opena2a/identity/backend/internal/interfaces/graphql"
+	"github.com/opena2a/identity/backend/internal/interfaces/grpc"
 	"github.com/opena2a/identity/backend/internal/interfaces/http/handlers"
 	"github.com/opena2a/identity/backend/internal/interfaces/http/middleware"
+	"github.com/opena2a/identity/backend/migrations"
 )
 
 // @title Agent Identity Management API
@@ -60,6 +78,35 @@ func main() {
 		log.Fatal("Failed to load config:", err)
 	}
 
+	// Log lines below LOG_LEVEL are dropped; operators can change the level at runtime
+	logLevel, err := logging.ParseLevel(cfg.Server.LogLevel)
+	if err != nil {
+		log.Printf("⚠️  %v; logging at info level", err)
+	}
+	logging.SetLevel(logLevel)
+	log.SetOutput(logging.NewLevelWriter(os.Stderr))
+
+	// Outbound calls to external dependencies fail fast while a dependency endpoint is down
+	resilience.Default.Configure(resilience.Settings{
+		FailureThreshold: cfg.CircuitBreakers.FailureThreshold,
+		Cooldown:         cfg.CircuitBreakers.Cooldown,
+	})
+
+	// Spans of the verification pipeline are exported over OTLP when a collector is configured
+	tracer, err := tracing.New(tracing.Config{
+		Endpoint:    cfg.Tracing.Endpoint,
+		Headers:     cfg.Tracing.Headers,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatal("Failed to configure tracing:", err)
+	}
+	if tracer != nil {
+		tracing.SetTracer(tracer)
+		log.Printf("🔭 Exporting traces to %s (sampling %.0f%% of new traces)", cfg.Tracing.Endpoint, cfg.Tracing.SampleRatio*100)
+	}
+
 	// Initialize database
 	db, err := initDatabase(cfg)
 	if err != nil {
@@ -67,9 +114,10 @@ func main() {
 	}
 	defer db.Close()
 
-	// ⚡ Run database migrations automatically on startup
+	// ⚡ Run database migrations automatically on startup (unless MIGRATIONS_AUTO_APPLY=false)
 	// This ensures production deployments have correct schema without manual intervention
-	if err := runMigrations(db); err != nil {
+	migrator := database.NewMigrator(db, migrations.Files, cfg.Database.MigrationLockTimeout)
+	if err := runMigrations(migrator, cfg.Database.AutoMigrate); err != nil {
 		log.Fatal("❌ Database migrations failed:", err)
 	}
 	log.Println("✅ Database migrations completed successfully")
@@ -87,6 +135,18 @@ func main() {
 	// Initialize repositories
 	repos, oauthRepo := initRepositories(db)
 
+	// 🌍 Store the agents, verification events and MCP attestations of organizations resident
+	// outside the home region in their region's cluster
+	if cfg.Residency.Enabled() {
+		clusters, err := initRegionalRepositories(repos, cfg, db)
+		if err != nil {
+			log.Fatal("❌ Regional database clusters failed:", err)
+		}
+		for _, cluster := range clusters {
+			defer cluster.Close()
+		}
+	}
+
 	// Initialize cache (optional - skip if Redis is unavailable)
 	var cacheService *cache.RedisCache
 	if redisClient != nil {
@@ -108,6 +168,20 @@ func main() {
 		cacheService = nil
 	}
 
+	// ✅ Cache the agent, API key and MCP server lookups of every verification; entries are shared
+	// through Redis when it is available
+	var identityCache domain.IdentityCache = cache.NewMemoryIdentityCache()
+	if cacheService != nil {
+		identityCache = cache.NewRedisIdentityCache(cacheService)
+	} else {
+		log.Println("ℹ️  Identity lookups are cached per server instance (Redis unavailable)")
+	}
+	for _, agentRepo := range repos.AgentClusters {
+		agentRepo.UseCache(identityCache, cfg.IdentityCache.AgentTTL)
+	}
+	repos.APIKey.UseCache(identityCache, cfg.IdentityCache.APIKeyTTL)
+	repos.MCPServer.UseCache(identityCache, cfg.IdentityCache.MCPServerTTL)
+
 	// Initialize infrastructure services
 	jwtService := auth.NewJWTService()
 
@@ -120,11 +194,47 @@ func main() {
 	}
 
 	// Initialize application services
-	services, keyVault := initServices(db, repos, cacheService, oauthRepo, jwtService, emailService)
+	services, keyVault := initServices(db, repos, cacheService, oauthRepo, jwtService, emailService, cfg)
 
 	// Initialize handlers
 	h := initHandlers(services, repos, jwtService, keyVault, cfg, db)
 
+	// Start background workers (stopped on shutdown)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go services.Notification.Start(workerCtx)  // Notification delivery queue
+	go services.Report.Start(workerCtx)        // Scheduled report subscriptions
+	go services.TrustBoundary.Start(workerCtx) // Trust score floor/ceiling actions
+	go services.Webhook.Start(workerCtx)       // Webhook delivery queue
+	go services.SIEMExport.Start(workerCtx)    // Buffered SIEM syslog export
+	// Attestation expiry and MCP confidence jobs, run by one elected instance
+	scheduler := initJobScheduler(services, repos, cfg)
+	services.PlatformOperator.UseScheduler(scheduler) // Operators inspect and control the jobs
+	// ✅ Runtime settings operators tune without a restart. Each instance applies the changes made
+	// on the others every refresh interval, and right away on SIGHUP.
+	defineRuntimeSettings(services, repos, scheduler, cfg)
+	if err := services.RuntimeConfig.Reload(context.Background()); err != nil {
+		log.Printf("⚠️  Failed to apply runtime settings: %v", err)
+	}
+	go services.RuntimeConfig.Watch(workerCtx, cfg.Operator.SettingsRefreshInterval)
+	go reloadRuntimeSettingsOnSIGHUP(services.RuntimeConfig)
+	go scheduler.Start(workerCtx)
+	// Daily rescan of agent SBOMs against OSV
+	go services.SBOM.Start(workerCtx)
+
+	// ✅ gRPC agent verification API (VerifyAction, CreateAttestation, Heartbeat) for high-throughput agents
+	if cfg.GRPC.Port != "" {
+		startGRPCServer(workerCtx, services, cfg)
+	}
+
+	// ✅ Platform operator API for the hosting team, on its own port apart from the tenant API
+	if err := services.PlatformOperator.Bootstrap(context.Background(), cfg.Operator.BootstrapToken); err != nil {
+		log.Fatal("Failed to bootstrap platform operator:", err)
+	}
+	if cfg.Operator.Port != "" {
+		startOperatorServer(workerCtx, h, services, cfg)
+	}
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		AppName:           "Agent Identity Management",
@@ -135,6 +245,8 @@ func main() {
 		ReadBufferSize:    16384, // 16KB header buffer (default is 4096) for OAuth callback URLs
 		DisableKeepalive:  false,
 		StreamRequestBody: false,
+		// Endpoint classes hold bodies to their own limits (see PayloadBudgetMiddleware)
+		BodyLimit: cfg.Payloads.MaxRequestBody(fiber.DefaultBodyLimit),
 	})
 
 	// Prometheus metrics endpoint (no auth required)
@@ -143,9 +255,31 @@ func main() {
 
 	// Global middleware
 	app.Use(middleware.RecoveryMiddleware())
+	// Server span per request, continuing the caller's trace; a no-op unless tracing is configured
+	app.Use(middleware.TracingMiddleware())
+	// Request deadlines by endpoint class; slow dependencies answer 504 instead of holding workers
+	app.Use(middleware.RequestTimeoutMiddleware(cfg.Timeouts, ""))
+	// Request body limits by endpoint class; attestation and bulk ingest paths are claimed first
+	app.Use([]string{
+		"/api/v1/mcp-servers/attest",
+		"/api/v1/mcp-servers/:id/attest",
+		"/api/v1/mcp-servers/:id/manual-attest",
+		"/api/v1/sdk-api/agents/:id/mcp-connections",
+	}, middleware.PayloadBudgetMiddleware(cfg.Payloads, domain.EndpointClassAttestation))
+	app.Use([]string{
+		"/api/v1/agents/import",
+		"/api/v1/agents/:id/sboms",
+		"/api/v1/detection",
+		"/api/v1/sdk-api/agents/:id/detection/report",
+	}, middleware.PayloadBudgetMiddleware(cfg.Payloads, domain.EndpointClassBulk))
+	app.Use(middleware.PayloadBudgetMiddleware(cfg.Payloads, ""))
+	// Brotli or gzip responses, whichever the client accepts
+	app.Use(middleware.CompressionMiddleware(cfg.ResponseCompression))
 	app.Use(middleware.LoggerMiddleware())
 	app.Use(metrics.PrometheusMiddleware())   // Prometheus metrics collection
 	app.Use(middleware.AnalyticsTracking(db)) // Real-time API call tracking
+	// Sign file downloads (exports, reports, archives) so recipients can verify them
+	app.Use(middleware.ExportSigningMiddleware(services.ExportSigner))
 	// app.Use(middleware.RequestLoggerMiddleware())
 
 	// CORS with allowed origins from environment
@@ -157,6 +291,10 @@ func main() {
 		allowedOrigins = []string{customOrigins}
 	}
 	app.Use(middleware.CORSMiddleware(allowedOrigins))
+	// Personal access tokens ("Bearer aimpat_...") authenticate as their user, limited to their scopes
+	app.Use(middleware.PersonalAccessTokenMiddleware(services.PersonalAccessToken))
+	// Consented operator sessions ("Bearer aimimp_...") act as the approving admin until revoked
+	app.Use(middleware.ImpersonationMiddleware(services.PlatformOperator))
 
 	// Health check (no auth required)
 	app.Get("/health", func(c fiber.Ctx) error {
@@ -227,8 +365,19 @@ func main() {
 			emailStatus = "healthy"
 		}
 
+		// External dependencies degrade features, never the identity plane; endpoints can be
+		// customer URLs, so only admins see them (/api/v1/admin/dependencies)
+		status := "operational"
+		dependencies := resilience.Default.Health()
+		for i := range dependencies {
+			if dependencies[i].Status != resilience.HealthHealthy {
+				status = "degraded"
+			}
+			dependencies[i].Circuits = nil
+		}
+
 		return c.JSON(fiber.Map{
-			"status":      "operational",
+			"status":      status,
 			"version":     "1.0.0",
 			"environment": environment,
 			"uptime":      time.Since(startTime).Seconds(),
@@ -237,6 +386,7 @@ func main() {
 				"redis":    redisStatus,
 				"email":    emailStatus,
 			},
+			"dependencies": dependencies,
 			"features": fiber.Map{
 				"oauth":              false, // OAuth disabled
 				"email_registration": true,
@@ -249,27 +399,61 @@ func main() {
 	// ✅ Action verification for SDK (signature-based auth, NO API key required)
 	// IMPORTANT: Register directly on app (not through group) to avoid API key middleware
 	// These endpoints verify Ed25519 signatures instead of requiring API keys
-	app.Post("/api/v1/sdk-api/verifications", middleware.RateLimitMiddleware(), h.Verification.CreateVerification)
-	app.Get("/api/v1/sdk-api/verifications/:id", middleware.RateLimitMiddleware(), h.Verification.GetVerification)
-	app.Post("/api/v1/sdk-api/verifications/:id/result", middleware.RateLimitMiddleware(), h.Verification.SubmitVerificationResult)
+	// An API key sent along (X-API-Key) must carry the matching verify scope
+	// Each agent is held to its organization's plan tier quota (verification throughput and concurrency)
+	// and API keys to their organization's verification rate limit
+	sdkAPIKey := middleware.OptionalAPIKeyMiddleware(services.APIKey)
+	// Agents may present their platform-issued certificate (mTLS) instead of an API key
+	sdkAgentCertificate := middleware.AgentCertificateMiddleware(services.AgentCertificate, cfg.MTLS.ClientCertHeader)
+	sdkVerificationTimeout := middleware.RequestTimeoutMiddleware(cfg.Timeouts, domain.EndpointClassVerification)
+	sdkVerificationBodyLimit := middleware.PayloadBudgetMiddleware(cfg.Payloads, domain.EndpointClassVerification)
+	// Retries sent with the same Idempotency-Key get the original response instead of a duplicate verification
+	sdkIdempotency := middleware.IdempotencyMiddleware(services.Idempotency)
+	app.Post("/api/v1/sdk-api/verifications", middleware.RateLimitMiddleware(), sdkVerificationTimeout, sdkVerificationBodyLimit, sdkAPIKey, sdkAgentCertificate, middleware.RequireAPIKeyScope(domain.APIKeyScopeVerifyCreate), middleware.OrganizationRateLimitMiddleware(services.RateLimit, domain.EndpointClassVerification), sdkIdempotency, middleware.AgentVerificationQuotaMiddleware(services.AgentQuota), h.Verification.CreateVerification)
+	app.Get("/api/v1/sdk-api/verifications/:id", middleware.RateLimitMiddleware(), sdkVerificationTimeout, sdkVerificationBodyLimit, sdkAPIKey, sdkAgentCertificate, middleware.RequireAPIKeyScope(domain.APIKeyScopeVerifyRead), middleware.OrganizationRateLimitMiddleware(services.RateLimit, domain.EndpointClassVerification), middleware.AgentQuotaMiddleware(services.AgentQuota), h.Verification.GetVerification)
+	app.Post("/api/v1/sdk-api/verifications/:id/result", middleware.RateLimitMiddleware(), sdkVerificationTimeout, sdkVerificationBodyLimit, sdkAPIKey, sdkAgentCertificate, middleware.RequireAPIKeyScope(domain.APIKeyScopeVerifyCreate), middleware.OrganizationRateLimitMiddleware(services.RateLimit, domain.EndpointClassVerification), sdkIdempotency, middleware.AgentQuotaMiddleware(services.AgentQuota), h.Verification.SubmitVerificationResult)
+
+	// ✅ OAuth token introspection (RFC 7662) and metadata (RFC 8414) for relying parties
+	// Relying parties authenticate with an API key carrying the tokens:introspect scope
+	app.Get("/.well-known/oauth-authorization-server", h.Introspection.Metadata)
+	app.Post("/oauth/introspect", middleware.RateLimitMiddleware(), middleware.APIKeyMiddleware(services.APIKey), middleware.RequireAPIKeyScope(domain.APIKeyScopeIntrospect), h.Introspection.Introspect)
 
 	// ⭐ SDK API routes - MUST be at app level to avoid middleware inheritance
 	// These routes use Ed25519 agent authentication for SDK/programmatic access
 	// Allows both Ed25519 (agent signatures) and JWT (user tokens) authentication
 	sdkAPI := app.Group("/api/v1/sdk-api")
+	sdkAPI.Use(sdkAgentCertificate)                               // Platform-issued agent certificates (mTLS)
 	sdkAPI.Use(middleware.Ed25519AgentMiddleware(services.Agent)) // Validates agent signatures, passes through JWT
 	sdkAPI.Use(middleware.RateLimitMiddleware())
-	sdkAPI.Get("/agents/:identifier", h.Agent.GetAgentByIdentifier)                             // Get agent by ID or name (SDK)
-	sdkAPI.Post("/agents/:id/capabilities", h.Capability.GrantCapability)                       // SDK capability reporting
-	sdkAPI.Post("/agents/:id/capability-requests", h.CapabilityRequest.CreateCapabilityRequest) // SDK capability request creation
-	sdkAPI.Post("/agents/:id/mcp-servers", h.MCP.CreateMCPServer)                               // SDK MCP registration (create new MCP server)
-	sdkAPI.Get("/agents/:id/mcp-servers", h.MCP.ListMCPServers)                                 // SDK list MCP servers for agent's org
-	sdkAPI.Post("/agents/:id/mcp-connections", h.MCPAttestation.RecordMCPConnection)            // SDK record agent-MCP connection (use_mcp_tool)
-	sdkAPI.Post("/agents/:id/detection/report", h.Detection.ReportDetection)                    // SDK MCP detection and integration reporting
+	sdkAPI.Use(middleware.OrganizationRateLimitMiddleware(services.RateLimit, ""))
+	sdkAPI.Use(sdkIdempotency)
+	// Per-agent concurrency of the organization's plan tier
+	sdkAPI.Use(middleware.AgentQuotaMiddleware(services.AgentQuota))
+	sdkAPI.Get("/agents/:identifier", h.Agent.GetAgentByIdentifier)                                  // Get agent by ID or name (SDK)
+	sdkAPI.Post("/agents/:id/capabilities", h.Capability.GrantCapability)                            // SDK capability reporting
+	sdkAPI.Post("/agents/:id/capability-requests", h.CapabilityRequest.CreateCapabilityRequest)      // SDK capability request creation
+	sdkAPI.Post("/agents/:id/capabilities/:capabilityId/renew", h.CapabilityRequest.RenewCapability) // SDK capability renewal request
+	sdkAPI.Post("/agents/:id/mcp-servers", h.MCP.CreateMCPServer)                                    // SDK MCP registration (create new MCP server)
+	sdkAPI.Get("/agents/:id/mcp-servers", h.MCP.ListMCPServers)                                      // SDK list MCP servers for agent's org
+	sdkAPI.Post("/agents/:id/mcp-connections", h.MCPAttestation.RecordMCPConnection)                 // SDK record agent-MCP connection (use_mcp_tool)
+	sdkAPI.Post("/agents/:id/detection/report", h.Detection.ReportDetection)                         // SDK MCP detection and integration reporting
+	sdkAPI.Get("/features", h.FeatureFlag.ListSDKFeatures)                                           // Protocol-level previews on for the organization
+
+	// ✅ Public verification lookup for third parties - agents their organization made publicly discoverable
+	// Unauthenticated and rate limited by client IP
+	app.Get("/public/v1/verify", middleware.RateLimitMiddleware(), h.AgentListing.VerifyPublicAgent)
+	// Embeddable "Verified by OpenA2A" badges and the status pages they link to; responses are signed
+	app.Get("/public/v1/signing-key", h.AgentListing.GetSigningKey)
+	app.Get("/public/v1/agents/:domain/:name/badge.svg", middleware.RateLimitMiddleware(), h.AgentListing.GetAgentBadgeSVG)
+	app.Get("/public/v1/agents/:domain/:name/badge.json", middleware.RateLimitMiddleware(), h.AgentListing.GetAgentBadgeJSON)
+	app.Get("/public/v1/agents/:domain/:name", middleware.RateLimitMiddleware(), h.AgentListing.GetAgentStatusPage)
+
+	// ✅ DID document of the agent credential issuer (did:web), so third parties verify credentials offline
+	app.Get("/.well-known/did.json", h.AgentCredential.GetDIDDocument)
 
 	// API v1 routes (JWT authenticated)
 	v1 := app.Group("/api/v1")
-	setupRoutes(v1, h, services, jwtService, repos.SDKToken, db)
+	setupRoutes(v1, h, services, jwtService, repos.SDKToken, db, cfg.Timeouts, cfg.Payloads, cfg.MTLS.ClientCertHeader)
 
 	// Start server
 	port := cfg.Server.Port
@@ -283,7 +467,7 @@ func main() {
 
 	// Graceful shutdown
 	go func() {
-		if err := app.Listen(":" + port); err != nil {
+		if err := app.Listen(":"+port, httpListenConfig(cfg, services.AgentCertificate)); err != nil {
 			log.Fatal(err)
 		}
 	}()
@@ -294,14 +478,221 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopWorkers()
 
 	if err := app.Shutdown(); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := tracer.Shutdown(flushCtx); err != nil {
+		log.Printf("⚠️  Failed to export the remaining spans: %v", err)
+	}
+	cancel()
+
 	log.Println("Server exited")
 }
 
+// httpListenConfig serves the API over TLS when HTTP_TLS_CERT_FILE is set, accepting the client
+// certificates the platform CA issues to agents. Clients without a certificate are still served.
+func httpListenConfig(cfg *config.Config, certificates *application.AgentCertificateService) fiber.ListenConfig {
+	if cfg.MTLS.TLSCertFile == "" {
+		return fiber.ListenConfig{}
+	}
+	log.Printf("🔐 HTTP API served over TLS; agents may authenticate with their certificates (mTLS)")
+	return fiber.ListenConfig{
+		CertFile:    cfg.MTLS.TLSCertFile,
+		CertKeyFile: cfg.MTLS.TLSKeyFile,
+		TLSConfigFunc: func(tlsConfig *tls.Config) {
+			clientCAs := x509.NewCertPool()
+			clientCAs.AppendCertsFromPEM(certificates.CACertificatePEM())
+			tlsConfig.ClientCAs = clientCAs
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		},
+	}
+}
+
+// startGRPCServer serves the gRPC API on GRPC_PORT, sharing the services of the HTTP API.
+// Agents authenticate with an API key or their platform-issued agent certificate (mTLS).
+func startGRPCServer(ctx context.Context, services *Services, cfg *config.Config) {
+	certificate, err := tls.LoadX509KeyPair(cfg.GRPC.TLSCertFile, cfg.GRPC.TLSKeyFile)
+	if err != nil {
+		log.Fatal("Failed to load gRPC TLS certificate:", err)
+	}
+
+	server := grpc.NewServer(
+		services.Agent,
+		services.MCPAttestation,
+		services.APIKey,
+		services.AgentCertificate,
+		services.AgentQuota,
+		services.Audit,
+		services.VerificationEvent,
+		services.Alert,
+	)
+	go func() {
+		if err := server.Serve(ctx, ":"+cfg.GRPC.Port, certificate); err != nil {
+			log.Fatal("gRPC server failed:", err)
+		}
+	}()
+	log.Printf("🚀 gRPC agent verification API starting on port %s", cfg.GRPC.Port)
+}
+
+// startOperatorServer serves the platform operator API on OPERATOR_PORT. It is a separate app with
+// its own authentication: operator tokens are not accepted by the tenant API, and tenant
+// credentials are not accepted here.
+func startOperatorServer(ctx context.Context, h *Handlers, services *Services, cfg *config.Config) {
+	app := fiber.New(fiber.Config{
+		AppName:      "AIM Operator API",
+		ErrorHandler: customErrorHandler,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	})
+	app.Use(middleware.RecoveryMiddleware())
+	app.Use(middleware.LoggerMiddleware())
+	app.Use(middleware.OperatorNetworkMiddleware(cfg.Operator.AllowedNetworks))
+
+	operator := app.Group("/operator/v1")
+	operator.Use(middleware.OperatorAuthMiddleware(services.PlatformOperator))
+	setupOperatorRoutes(operator, h)
+
+	go func() {
+		<-ctx.Done()
+		app.Shutdown()
+	}()
+	go func() {
+		if err := app.Listen(":"+cfg.Operator.Port, fiber.ListenConfig{DisableStartupMessage: true}); err != nil {
+			log.Fatal("Operator API failed:", err)
+		}
+	}()
+	log.Printf("🛠️  Platform operator API starting on port %s", cfg.Operator.Port)
+}
+
+// setupOperatorRoutes registers the operator API. Viewers read, support operators throttle,
+// control jobs and impersonate, and admins manage operators.
+func setupOperatorRoutes(operator fiber.Router, h *Handlers) {
+	viewer := middleware.OperatorRoleMiddleware(domain.OperatorRoleViewer)
+	support := middleware.OperatorRoleMiddleware(domain.OperatorRoleSupport)
+	admin := middleware.OperatorRoleMiddleware(domain.OperatorRoleAdmin)
+
+	operator.Get("/me", h.Operator.Me)
+
+	// Organizations with their usage and health, and throttles of abusive ones
+	operator.Get("/organizations", h.Operator.ListOrganizations, viewer)
+	operator.Get("/organizations/:id", h.Operator.GetOrganization, viewer)
+	operator.Put("/organizations/:id/throttle", h.Operator.SetThrottle, support)
+	operator.Delete("/organizations/:id/throttle", h.Operator.RemoveThrottle, support)
+	operator.Get("/throttles", h.Operator.ListThrottles, viewer)
+
+	// Background jobs of the instance answering the request
+	operator.Get("/jobs", h.Operator.ListJobs, viewer)
+	operator.Post("/jobs/:name/cancel", h.Operator.CancelJob, support)
+	operator.Post("/jobs/:name/trigger", h.Operator.TriggerJob, support)
+
+	// Impersonation with an organization admin's consent
+	operator.Get("/impersonations", h.Operator.ListImpersonations, viewer)
+	operator.Post("/impersonations", h.Operator.RequestImpersonation, support)
+	operator.Post("/impersonations/:id/start", h.Operator.StartImpersonation, support)
+	operator.Post("/impersonations/:id/end", h.Operator.EndImpersonation, support)
+
+	// Operators and the operator log
+	operator.Get("/operators", h.Operator.ListOperators, admin)
+	operator.Post("/operators", h.Operator.CreateOperator, admin)
+	operator.Patch("/operators/:id", h.Operator.UpdateOperator, admin)
+	operator.Get("/actions", h.Operator.ListActions, viewer)
+
+	// Runtime settings, applied without a restart
+	operator.Get("/settings", h.RuntimeConfig.ListSettings, viewer)
+	operator.Get("/settings/history", h.RuntimeConfig.ListChanges, viewer)
+	operator.Post("/settings/reload", h.RuntimeConfig.Reload, admin)
+	operator.Put("/settings/:key", h.RuntimeConfig.SetSetting, admin)
+	operator.Delete("/settings/:key", h.RuntimeConfig.ResetSetting, admin)
+	operator.Get("/settings/:key/history", h.RuntimeConfig.ListChanges, viewer)
+
+	// Addresses suppressed after hard bounces and spam complaints
+	operator.Get("/email/suppressions", h.Email.ListSuppressions, viewer)
+	operator.Delete("/email/suppressions/:address", h.Email.RemoveSuppression, support)
+}
+
+// defineRuntimeSettings defines the knobs operators can change at runtime, with the values
+// configured at startup as their defaults
+func defineRuntimeSettings(services *Services, repos *Repositories, scheduler *application.JobScheduler, cfg *config.Config) {
+	runtimeConfig := services.RuntimeConfig
+	perMinute, strictPerMinute := middleware.RateLimits()
+	runtimeConfig.Define(
+		application.IntSetting("rate_limit.requests_per_minute",
+			"Requests per minute of each user or IP address on rate-limited endpoints",
+			perMinute, 1, 100000, middleware.SetRateLimit),
+		application.IntSetting("rate_limit.strict_requests_per_minute",
+			"Requests per minute of each user or IP address on sensitive endpoints (MFA, emergency access)",
+			strictPerMinute, 1, 10000, middleware.SetStrictRateLimit),
+		application.DurationSetting("cache.agent_ttl",
+			"How long agent lookups are cached; 0s turns caching off",
+			cfg.IdentityCache.AgentTTL, 0, 24*time.Hour, func(ttl time.Duration) {
+				for _, agentRepo := range repos.AgentClusters {
+					agentRepo.SetCacheTTL(ttl)
+				}
+			}),
+		application.DurationSetting("cache.api_key_ttl",
+			"How long API key lookups are cached; 0s turns caching off",
+			cfg.IdentityCache.APIKeyTTL, 0, 24*time.Hour, repos.APIKey.SetCacheTTL),
+		application.DurationSetting("cache.mcp_server_ttl",
+			"How long MCP server lookups are cached; 0s turns caching off",
+			cfg.IdentityCache.MCPServerTTL, 0, 24*time.Hour, repos.MCPServer.SetCacheTTL),
+		application.EnumSetting("log.level",
+			"Lowest level of the log lines written; request logs are info",
+			logging.CurrentLevel().String(), logging.LevelNames(), func(name string) {
+				level, _ := logging.ParseLevel(name)
+				logging.SetLevel(level)
+			}),
+	)
+
+	// Intervals of the background jobs; disabled jobs stay disabled until restarted with an interval
+	for _, job := range scheduler.Jobs() {
+		name := job.Name
+		runtimeConfig.Define(application.DurationSetting("jobs."+name+".interval",
+			"How often the "+name+" background job runs",
+			job.Interval, 10*time.Second, 7*24*time.Hour, func(interval time.Duration) {
+				if err := scheduler.SetInterval(name, interval); err != nil {
+					log.Printf("⚠️  Failed to change the interval of background job %s: %v", name, err)
+				}
+			}))
+	}
+
+	// Feature toggles forcing a feature on or off for every organization
+	flags, err := services.FeatureFlag.ListFlags()
+	if err != nil {
+		log.Printf("⚠️  Feature toggles are not runtime settings: %v", err)
+		return
+	}
+	for _, flag := range flags {
+		key := flag.Key
+		runtimeConfig.Define(application.EnumSetting("features."+key,
+			"Forces the "+key+" feature on or off for every organization; default leaves it to organizations and users",
+			"default", []string{"default", "on", "off"}, func(value string) {
+				var enabled *bool
+				if value != "default" {
+					on := value == "on"
+					enabled = &on
+				}
+				services.FeatureFlag.ForceFeature(key, enabled)
+			}))
+	}
+}
+
+// reloadRuntimeSettingsOnSIGHUP applies the stored runtime settings whenever the process
+// receives SIGHUP, without waiting for the next refresh
+func reloadRuntimeSettingsOnSIGHUP(runtimeConfig *application.RuntimeConfigService) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		log.Println("🔄 SIGHUP received, reloading runtime settings")
+		if err := runtimeConfig.Reload(context.Background()); err != nil {
+			log.Printf("⚠️  Failed to reload runtime settings: %v", err)
+		}
+	}
+}
+
 func initDatabase(cfg *config.Config) (*sql.DB, error) {
 	// Build connection string using key=value format to avoid URL encoding issues
 	// This format works better with passwords containing special characters
@@ -313,6 +704,10 @@ func initDatabase(cfg *config.Config) (*sql.DB, error) {
 		cfg.Database.Database,
 		cfg.Database.SSLMode,
 	)
+	// The database budget is enforced by PostgreSQL, so a slow query fails instead of holding a handler
+	if budget := cfg.Timeouts.Dependencies[domain.DependencyDatabase]; budget > 0 {
+		connStr += fmt.Sprintf(" statement_timeout=%d", budget.Milliseconds())
+	}
 
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
@@ -332,6 +727,58 @@ func initDatabase(cfg *config.Config) (*sql.DB, error) {
 	return db, nil
 }
 
+// initRegionalRepositories connects to the cluster of every region besides the home region,
+// brings its schema up to date, and routes the agent, verification event and MCP attestation
+// repositories to the cluster of each organization's region. It returns the clusters' connections.
+func initRegionalRepositories(repos *Repositories, cfg *config.Config, db *sql.DB) ([]*sql.DB, error) {
+	ctx := context.Background()
+	if _, err := database.DetachRegionalForeignKeys(ctx, db, true); err != nil {
+		return nil, err
+	}
+
+	home := cfg.Residency.HomeRegion
+	agents := map[domain.DataRegion]domain.AgentRepository{home: repos.Agent}
+	events := map[domain.DataRegion]domain.VerificationEventRepository{home: repos.VerificationEvent}
+	attestations := map[domain.DataRegion]domain.MCPAttestationRepository{home: repos.MCPAttestation}
+
+	var clusters []*sql.DB
+	for _, region := range slices.Sorted(maps.Keys(cfg.Residency.RegionClusters)) {
+		cluster, err := sql.Open("postgres", cfg.Residency.RegionClusters[region])
+		if err != nil {
+			return clusters, fmt.Errorf("region %s: %w", region, err)
+		}
+		clusters = append(clusters, cluster)
+		cluster.SetMaxOpenConns(cfg.Database.MaxConnections)
+		cluster.SetMaxIdleConns(cfg.Database.MaxConnections / 2)
+		cluster.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+		if err := cluster.Ping(); err != nil {
+			return clusters, fmt.Errorf("region %s: %w", region, err)
+		}
+
+		// Regional clusters have the full schema; only the regional tables are used
+		migrator := database.NewMigrator(cluster, migrations.Files, cfg.Database.MigrationLockTimeout)
+		if err := runMigrations(migrator, cfg.Database.AutoMigrate); err != nil {
+			return clusters, fmt.Errorf("region %s: migrations failed: %w", region, err)
+		}
+		if _, err := database.DetachRegionalForeignKeys(ctx, cluster, false); err != nil {
+			return clusters, fmt.Errorf("region %s: %w", region, err)
+		}
+
+		agentRepo := repository.NewAgentRepository(cluster)
+		repos.AgentClusters = append(repos.AgentClusters, agentRepo)
+		agents[region] = agentRepo
+		events[region] = repository.NewVerificationEventRepository(cluster)
+		attestations[region] = repository.NewMCPAttestationRepository(cluster)
+		log.Printf("🌍 Region %s connected; organizations resident in it store their agents, verification events and MCP attestations there", region)
+	}
+
+	router := repository.NewRegionRouter(home, repos.Organization, repos.MCPServer, repos.DataPlacement)
+	repos.Agent = repository.NewRegionalAgentRepository(router, agents)
+	repos.VerificationEvent = repository.NewRegionalVerificationEventRepository(router, events)
+	repos.MCPAttestation = repository.NewRegionalMCPAttestationRepository(router, attestations)
+	return clusters, nil
+}
+
 func initRedis(cfg *config.Config) (*redis.Client, error) {
 	client := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
@@ -353,23 +800,120 @@ func initRedis(cfg *config.Config) (*redis.Client, error) {
 type Repositories struct {
 	User               *repository.UserRepository
 	Organization       *repository.OrganizationRepository
-	Agent              *repository.AgentRepository
+	Agent              domain.MCPServerAgentRepository
 	APIKey             *repository.APIKeyRepository
 	TrustScore         *repository.TrustScoreRepository
 	AuditLog           *repository.AuditLogRepository
 	Alert              *repository.AlertRepository
 	MCPServer          *repository.MCPServerRepository
 	MCPCapability      *repository.MCPServerCapabilityRepository // ✅ For MCP server capabilities
-	MCPAttestation     *repository.MCPAttestationRepository      // ✅ For agent attestation of MCPs
+	MCPAttestation     domain.MCPAttestationRepository           // ✅ For agent attestation of MCPs
+	AttestationNonce   *repository.AttestationNonceRepository    // ✅ Single-use attestation nonces
 	AgentMCPConnection *repository.AgentMCPConnectionRepository  // ✅ For agent-MCP connections
 	Security           *repository.SecurityRepository
 	SecurityPolicy     *repository.SecurityPolicyRepository // ✅ For configurable security policies
 	Webhook            *repository.WebhookRepository
-	VerificationEvent  *repository.VerificationEventRepositorySimple
+	VerificationEvent  domain.VerificationEventRepository
 	Tag                *repository.TagRepository
 	SDKToken           domain.SDKTokenRepository
 	Capability         domain.CapabilityRepository
-	CapabilityRequest  domain.CapabilityRequestRepository // ✅ For capability expansion approval workflow
+	CapabilityRequest  domain.CapabilityRequestRepository       // ✅ For capability expansion approval workflow
+	Notification       *repository.NotificationRepository       // ✅ For queue-backed notification fan-out
+	Report             *repository.ReportRepository             // ✅ For scheduled report subscriptions
+	PolicyDecision     *repository.PolicyDecisionRepository     // ✅ For external policy decision points (OPA)
+	CompromiseResponse *repository.CompromiseResponseRepository // ✅ For compromised-agent response bundles
+	Entitlement        *repository.EntitlementRepository        // ✅ For entitlement (access) reviews
+	DriftAnalytics     *repository.DriftAnalyticsRepository     // ✅ For drift trend analytics
+	TrustBoundary      *repository.TrustBoundaryRepository      // ✅ For trust score floor/ceiling actions
+	Tombstone          *repository.TombstoneRepository          // ✅ For records of deleted entities
+	AlertSuppression   *repository.AlertSuppressionRepository   // ✅ For alert suppression rules
+	Emergency          domain.EmergencyCredentialRepository     // ✅ For sealed break-glass credentials
+	// ✅ For agent SBOMs and their vulnerability scans
+	SBOM *repository.AgentSBOMRepository
+	// ✅ For multi-step approval chains and the requests they gate
+	ApprovalChain   *repository.ApprovalChainRepository
+	ApprovalRequest *repository.ApprovalRequestRepository
+	// ✅ For SAML 2.0 single sign-on
+	SAMLConfig *repository.SAMLConfigRepository
+	// ✅ For agent capabilities referring to tools their MCP server dropped
+	CapabilityDeprecation *repository.CapabilityDeprecationRepository
+	// ✅ For leader election of background jobs
+	JobLease *repository.JobLeaseRepository
+	// ✅ For per-agent activity timelines
+	AgentTimeline *repository.AgentTimelineRepository
+	// ✅ For X.509 certificates issued to verified agents
+	AgentCertificate *repository.AgentCertificateRepository
+	// ✅ For incident-driven containment playbooks
+	Playbook *repository.PlaybookRepository
+	// ✅ For publicly discoverable agents
+	AgentListing *repository.AgentListingRepository
+	// ✅ For staged configuration changes
+	ChangeRequest *repository.ChangeRequestRepository
+	// ✅ For public keys shared across agents
+	SharedKey *repository.SharedKeyRepository
+	// ✅ For asynchronous verification event exports
+	VerificationExport *repository.VerificationExportRepository
+	// ✅ For agent-to-agent peer policies
+	AgentPeerPolicy *repository.AgentPeerPolicyRepository
+	// ✅ For per-tool MCP grants
+	MCPToolGrant *repository.MCPToolGrantRepository
+	// ✅ For preview feature flags
+	FeatureFlag *repository.FeatureFlagRepository
+	// ✅ For agent-MCP connection latency SLOs
+	ConnectionLatencySLO *repository.ConnectionLatencySLORepository
+	// ✅ For MCP server health checks and uptime history
+	MCPHealth *repository.MCPHealthRepository
+	// ✅ For responses replayed to retries sent with an Idempotency-Key
+	Idempotency *repository.IdempotencyRepository
+	// ✅ For change sets proposed with configuration drift alerts
+	DriftRemediation *repository.DriftRemediationRepository
+	// ✅ For daily per-agent and per-API key usage rollups
+	UsageRollup *repository.UsageRollupRepository
+	// ✅ For custom roles and their assignments to users
+	CustomRole *repository.CustomRoleRepository
+	// ✅ For domain events waiting to be relayed to the event bus
+	DomainEventOutbox *repository.DomainEventOutboxRepository
+	// ✅ For GDPR erasure requests and the anonymization of erased users' data
+	DataErasureRequest *repository.DataErasureRequestRepository
+	PersonalData       *repository.PersonalDataRepository
+	// ✅ For invitations to join an organization
+	Invitation *repository.InvitationRepository
+	// ✅ For trust score triggers and their firings
+	TrustScoreTrigger *repository.TrustScoreTriggerRepository
+	// ✅ For the transactional email send queue, its suppression list and organizations' senders
+	EmailQueue       *repository.EmailQueueRepository
+	EmailSuppression *repository.EmailSuppressionRepository
+	EmailSender      *repository.OrganizationEmailSenderRepository
+	// ✅ For password login attempts, their backoff and lockouts
+	LoginAttempt *repository.LoginAttemptRepository
+	// ✅ For the regions agents, verification events and MCP attestations are stored in, and the
+	// agent repository of each region's cluster (for the identity cache)
+	DataPlacement *repository.DataPlacementRepository
+	AgentClusters []*repository.AgentRepository
+	// ✅ For agent identity import lineage
+	AgentLineage *repository.AgentLineageRepository
+	// ✅ For incident comments, links and timeline
+	Incident *repository.IncidentRepository
+	// ✅ For personal access tokens
+	PersonalAccessToken *repository.PersonalAccessTokenRepository
+	// ✅ For attestation cadences by MCP server criticality
+	AttestationCadencePolicy *repository.AttestationCadencePolicyRepository
+	// ✅ For alert notification routing rules
+	NotificationRoute *repository.NotificationRouteRepository
+	// ✅ For demo data seeded by admins
+	DemoRecord *repository.DemoRecordRepository
+	// ✅ For sub-agents delegated by agents
+	AgentDelegation *repository.AgentDelegationRepository
+	// ✅ For Jira / ServiceNow ticket connectors of organizations
+	TicketConnector *repository.TicketConnectorRepository
+	// ✅ For the platform operator API
+	PlatformOperator *repository.PlatformOperatorRepository
+	// ✅ For runtime settings changed through the operator API
+	RuntimeSetting *repository.RuntimeSettingRepository
+	// ✅ For SIEM syslog exporters of organizations
+	SIEMExporter *repository.SIEMExporterRepository
+	// ✅ For naming policies of agents and MCP servers
+	NamingPolicy *repository.NamingPolicyRepository
 }
 
 func initRepositories(db *sql.DB) (*Repositories, *repository.OAuthRepositoryPostgres) {
@@ -378,11 +922,12 @@ func initRepositories(db *sql.DB) (*Repositories, *repository.OAuthRepositoryPos
 
 	// Initialize registration repository for user registration workflow
 	oauthRepo := repository.NewOAuthRepositoryPostgres(dbx)
+	agents := repository.NewAgentRepository(db)
 
 	return &Repositories{
 		User:               repository.NewUserRepository(db),
 		Organization:       repository.NewOrganizationRepository(db),
-		Agent:              repository.NewAgentRepository(db),
+		Agent:              agents,
 		APIKey:             repository.NewAPIKeyRepository(db),
 		TrustScore:         repository.NewTrustScoreRepository(db),
 		AuditLog:           repository.NewAuditLogRepository(db),
@@ -390,6 +935,7 @@ func initRepositories(db *sql.DB) (*Repositories, *repository.OAuthRepositoryPos
 		MCPServer:          repository.NewMCPServerRepository(db),
 		MCPCapability:      repository.NewMCPServerCapabilityRepository(db), // ✅ For MCP server capabilities
 		MCPAttestation:     repository.NewMCPAttestationRepository(db),      // ✅ For agent attestation of MCPs
+		AttestationNonce:   repository.NewAttestationNonceRepository(db),    // ✅ Single-use attestation nonces
 		AgentMCPConnection: repository.NewAgentMCPConnectionRepository(dbx), // ✅ For agent-MCP connections
 		Security:           repository.NewSecurityRepository(db),
 		SecurityPolicy:     repository.NewSecurityPolicyRepository(db), // ✅ For configurable security policies
@@ -399,6 +945,103 @@ func initRepositories(db *sql.DB) (*Repositories, *repository.OAuthRepositoryPos
 		SDKToken:           repository.NewSDKTokenRepository(db),
 		Capability:         repository.NewCapabilityRepository(dbx),
 		CapabilityRequest:  repository.NewCapabilityRequestRepository(dbx), // ✅ For capability expansion approval workflow
+		Notification:       repository.NewNotificationRepository(db),       // ✅ For queue-backed notification fan-out
+		Report:             repository.NewReportRepository(db),             // ✅ For scheduled report subscriptions
+		PolicyDecision:     repository.NewPolicyDecisionRepository(db),     // ✅ For external policy decision points (OPA)
+		CompromiseResponse: repository.NewCompromiseResponseRepository(db), // ✅ For compromised-agent response bundles
+		Entitlement:        repository.NewEntitlementRepository(db),        // ✅ For entitlement (access) reviews
+		DriftAnalytics:     repository.NewDriftAnalyticsRepository(db),     // ✅ For drift trend analytics
+		TrustBoundary:      repository.NewTrustBoundaryRepository(db),      // ✅ For trust score floor/ceiling actions
+		Tombstone:          repository.NewTombstoneRepository(db),          // ✅ For records of deleted entities
+		AlertSuppression:   repository.NewAlertSuppressionRepository(db),   // ✅ For alert suppression rules
+
+		// ✅ For sealed break-glass credentials
+		Emergency: repository.NewEmergencyCredentialRepository(db),
+		// ✅ For agent SBOMs and their vulnerability scans
+		SBOM: repository.NewAgentSBOMRepository(db),
+		// ✅ For multi-step approval chains and the requests they gate
+		ApprovalChain:   repository.NewApprovalChainRepository(db),
+		ApprovalRequest: repository.NewApprovalRequestRepository(db),
+		// ✅ For SAML 2.0 single sign-on
+		SAMLConfig: repository.NewSAMLConfigRepository(db),
+		// ✅ For agent capabilities referring to tools their MCP server dropped
+		CapabilityDeprecation: repository.NewCapabilityDeprecationRepository(db),
+		// ✅ For leader election of background jobs
+		JobLease: repository.NewJobLeaseRepository(db),
+		// ✅ For per-agent activity timelines
+		AgentTimeline: repository.NewAgentTimelineRepository(db),
+		// ✅ For X.509 certificates issued to verified agents
+		AgentCertificate: repository.NewAgentCertificateRepository(db),
+		// ✅ For incident-driven containment playbooks
+		Playbook: repository.NewPlaybookRepository(db),
+		// ✅ For publicly discoverable agents
+		AgentListing: repository.NewAgentListingRepository(db),
+		// ✅ For staged configuration changes
+		ChangeRequest: repository.NewChangeRequestRepository(db),
+		// ✅ For public keys shared across agents
+		SharedKey: repository.NewSharedKeyRepository(db),
+		// ✅ For asynchronous verification event exports
+		VerificationExport: repository.NewVerificationExportRepository(db),
+		// ✅ For agent-to-agent peer policies
+		AgentPeerPolicy: repository.NewAgentPeerPolicyRepository(db),
+		// ✅ For per-tool MCP grants
+		MCPToolGrant: repository.NewMCPToolGrantRepository(db),
+		// ✅ For preview feature flags
+		FeatureFlag: repository.NewFeatureFlagRepository(db),
+		// ✅ For agent-MCP connection latency SLOs
+		ConnectionLatencySLO: repository.NewConnectionLatencySLORepository(db),
+		// ✅ For MCP server health checks and uptime history
+		MCPHealth: repository.NewMCPHealthRepository(db),
+		// ✅ For responses replayed to retries sent with an Idempotency-Key
+		Idempotency: repository.NewIdempotencyRepository(db),
+		// ✅ For change sets proposed with configuration drift alerts
+		DriftRemediation: repository.NewDriftRemediationRepository(db),
+		// ✅ For daily per-agent and per-API key usage rollups
+		UsageRollup: repository.NewUsageRollupRepository(db),
+		// ✅ For custom roles and their assignments to users
+		CustomRole: repository.NewCustomRoleRepository(db),
+		// ✅ For domain events waiting to be relayed to the event bus
+		DomainEventOutbox: repository.NewDomainEventOutboxRepository(db),
+		// ✅ For GDPR erasure requests and the anonymization of erased users' data
+		DataErasureRequest: repository.NewDataErasureRequestRepository(db),
+		PersonalData:       repository.NewPersonalDataRepository(db),
+		// ✅ For invitations to join an organization
+		Invitation: repository.NewInvitationRepository(db),
+		// ✅ For trust score triggers and their firings
+		TrustScoreTrigger: repository.NewTrustScoreTriggerRepository(db),
+		// ✅ For the transactional email send queue, its suppression list and organizations' senders
+		EmailQueue:       repository.NewEmailQueueRepository(db),
+		EmailSuppression: repository.NewEmailSuppressionRepository(db),
+		EmailSender:      repository.NewOrganizationEmailSenderRepository(db),
+		// ✅ For password login attempts, their backoff and lockouts
+		LoginAttempt: repository.NewLoginAttemptRepository(db),
+		// ✅ For the regions agents, verification events and MCP attestations are stored in
+		DataPlacement: repository.NewDataPlacementRepository(db),
+		AgentClusters: []*repository.AgentRepository{agents},
+		// ✅ For agent identity import lineage
+		AgentLineage: repository.NewAgentLineageRepository(db),
+		// ✅ For incident comments, links and timeline
+		Incident: repository.NewIncidentRepository(db),
+		// ✅ For personal access tokens
+		PersonalAccessToken: repository.NewPersonalAccessTokenRepository(db),
+		// ✅ For attestation cadences by MCP server criticality
+		AttestationCadencePolicy: repository.NewAttestationCadencePolicyRepository(db),
+		// ✅ For alert notification routing rules
+		NotificationRoute: repository.NewNotificationRouteRepository(db),
+		// ✅ For demo data seeded by admins
+		DemoRecord: repository.NewDemoRecordRepository(db),
+		// ✅ For sub-agents delegated by agents
+		AgentDelegation: repository.NewAgentDelegationRepository(db),
+		// ✅ For Jira / ServiceNow ticket connectors of organizations
+		TicketConnector: repository.NewTicketConnectorRepository(db),
+		// ✅ For the platform operator API
+		PlatformOperator: repository.NewPlatformOperatorRepository(db),
+		// ✅ For runtime settings changed through the operator API
+		RuntimeSetting: repository.NewRuntimeSettingRepository(db),
+		// ✅ For SIEM syslog exporters of organizations
+		SIEMExporter: repository.NewSIEMExporterRepository(db),
+		// ✅ For naming policies of agents and MCP servers
+		NamingPolicy: repository.NewNamingPolicyRepository(db),
 	}, oauthRepo
 }
 
@@ -422,11 +1065,120 @@ type Services struct {
 	Tag               *application.TagService
 	SDKToken          *application.SDKTokenService
 	Capability        *application.CapabilityService
-	CapabilityRequest *application.CapabilityRequestService // ✅ For capability expansion approval workflow
-	Detection         *application.DetectionService         // ✅ For MCP auto-detection (SDK + Direct API)
+	CapabilityRequest *application.CapabilityRequestService  // ✅ For capability expansion approval workflow
+	Detection         *application.DetectionService          // ✅ For MCP auto-detection (SDK + Direct API)
+	Notification      *application.NotificationService       // ✅ For queue-backed notification fan-out
+	Report            *application.ReportService             // ✅ For scheduled report subscriptions
+	PolicyDecision    *application.PolicyDecisionService     // ✅ For external policy decision points (OPA)
+	Compromise        *application.CompromiseResponseService // ✅ For compromised-agent response bundles
+	Entitlement       *application.EntitlementService        // ✅ For entitlement (access) reviews
+	DriftAnalytics    *application.DriftAnalyticsService     // ✅ For drift trend analytics
+	TrustBoundary     *application.TrustBoundaryService      // ✅ For trust score floor/ceiling actions
+	Tombstone         *application.TombstoneService          // ✅ For records of deleted entities
+	Introspection     *application.TokenIntrospectionService // ✅ For relying-party token validation
+	AlertSuppression  *application.AlertSuppressionService   // ✅ For alert suppression rules
+	Emergency         *application.EmergencyAccessService    // ✅ For sealed break-glass credentials
+	// ✅ For agent SBOMs and their vulnerability scans
+	SBOM *application.SBOMService
+	// ✅ For multi-step approval chains
+	Approval *application.ApprovalChainService
+	// ✅ For SAML 2.0 single sign-on
+	SAML *application.SAMLService
+	// ✅ For signed export files
+	ExportSigner *crypto.ExportSigner
+	// ✅ For signed public agent lookups, badges and status pages
+	PublicStatusSigner *crypto.PublicStatusSigner
+	// ✅ For per-agent activity timelines
+	AgentTimeline *application.AgentTimelineService
+	// ✅ For artifacts kept in object storage (exports, archived events, attestations, reports, SBOMs)
+	Artifacts *storage.ArtifactStore
+	// ✅ For per-agent quotas by plan tier
+	AgentQuota *application.AgentQuotaService
+	// ✅ For X.509 certificates issued to verified agents
+	AgentCertificate *application.AgentCertificateService
+	// ✅ For incident-driven containment playbooks
+	Playbook *application.PlaybookService
+	// ✅ For publicly discoverable agents
+	AgentListing *application.AgentListingService
+	// ✅ For staged configuration changes
+	ChangeRequest *application.ChangeRequestService
+	// ✅ For TOTP multi-factor authentication
+	MFA *application.MFAService
+	// ✅ For behavioral baselines and anomaly detection
+	AnomalyBaseline *application.AnomalyBaselineService
+	// ✅ For public keys shared across agents
+	SharedKey *application.SharedKeyService
+	// ✅ For talks_to suggestions from actual MCP server usage
+	TalksToRecommendation *application.TalksToRecommendationService
+	// ✅ For asynchronous verification event exports
+	VerificationExport *application.VerificationExportService
+	// ✅ For agent-to-agent peer policies
+	AgentPeer *application.AgentPeerService
+	// ✅ For per-tool MCP grants
+	MCPToolGrant *application.MCPToolGrantService
+	// ✅ For database schema version reporting
+	Schema *application.SchemaService
+	// ✅ For per-organization API rate limits
+	RateLimit *application.RateLimitService
+	// ✅ For preview feature flags
+	FeatureFlag *application.FeatureFlagService
+	// ✅ For agent-MCP connection latency SLOs
+	ConnectionLatency *application.ConnectionLatencyService
+	// ✅ For agent identity export and import between deployments
+	AgentPortability *application.AgentPortabilityService
+	// ✅ For the incident management workflow
+	Incident *application.IncidentService
+	// ✅ For scoped personal access tokens
+	PersonalAccessToken *application.PersonalAccessTokenService
+	// ✅ For attestation cadences by MCP server criticality
+	AttestationCadence *application.MCPAttestationCadenceService
+	// ✅ For sub-organizations
+	OrganizationHierarchy *application.OrganizationHierarchyService
+	// ✅ For W3C verifiable credentials issued to agents
+	AgentCredential *application.AgentCredentialService
+	// ✅ For demo data seeded by admins
+	DemoData *application.DemoDataService
+	// ✅ For sub-agents delegated by agents
+	AgentDelegation *application.AgentDelegationService
+	// ✅ For Jira / ServiceNow ticket connectors of organizations
+	TicketConnector *application.TicketConnectorService
+	// ✅ For the platform operator API
+	PlatformOperator *application.PlatformOperatorService
+	// ✅ For runtime settings changed through the operator API
+	RuntimeConfig *application.RuntimeConfigService
+	// ✅ For SIEM syslog exporters of organizations
+	SIEMExport *application.SIEMExportService
+	// ✅ For naming policies of agents and MCP servers
+	NamingPolicy *application.NamingPolicyService
+	// ✅ For time-bound capabilities removed once they expire
+	CapabilityExpiry *application.CapabilityExpiryService
+	// ✅ For MCP server health checks and uptime
+	MCPHealth *application.MCPHealthService
+	// ✅ For responses replayed to retries sent with an Idempotency-Key
+	Idempotency *application.IdempotencyService
+	// ✅ For approving or rejecting the change sets proposed with configuration drift alerts
+	DriftRemediation *application.DriftRemediationService
+	// ✅ For per-agent and per-API key usage analytics
+	UsageAnalytics *application.UsageAnalyticsService
+	// ✅ For custom roles and the permissions routes require
+	Role *application.RoleService
+	// ✅ For verification, drift, trust score and alert events published to NATS or Kafka
+	DomainEvents *application.DomainEventService
+	// ✅ For GDPR data exports and approved erasure of users' personal data
+	PersonalData *application.PersonalDataService
+	// ✅ For onboarding users through expiring invitation links
+	Invitation *application.InvitationService
+	// ✅ For actions run when trust scores cross a threshold
+	TrustScoreTrigger *application.TrustScoreTriggerService
+	// ✅ For queued transactional email, organizations' senders and bounce suppression
+	EmailQueue *application.EmailQueueService
+	// ✅ For brute force and credential stuffing protection of password logins
+	LoginProtection *application.LoginProtectionService
+	// ✅ For the region organizations' agents, verification events and MCP attestations are stored in
+	DataResidency *application.DataResidencyService
 }
 
-func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCache, oauthRepo *repository.OAuthRepositoryPostgres, jwtService *auth.JWTService, emailService domain.EmailService) (*Services, *crypto.KeyVault) {
+func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCache, oauthRepo *repository.OAuthRepositoryPostgres, jwtService *auth.JWTService, emailService domain.EmailService, cfg *config.Config) (*Services, *crypto.KeyVault) {
 	// ✅ Initialize KeyVault for secure private key storage
 	keyVault, err := crypto.NewKeyVaultFromEnv()
 	if err != nil {
@@ -434,11 +1186,129 @@ func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCach
 	}
 	log.Println("✅ KeyVault initialized for automatic key generation")
 
+	// ✅ Signs export files so recipients can verify them (key derived from the KeyVault unless configured)
+	exportSigner, err := crypto.NewExportSignerFromEnv(keyVault)
+	if err != nil {
+		log.Fatal("Failed to initialize export signer:", err)
+	}
+
+	// ✅ Signs agent identity bundles other deployments import (key derived from the KeyVault unless configured)
+	identityBundleSigner, err := crypto.NewIdentityBundleSignerFromEnv(keyVault)
+	if err != nil {
+		log.Fatal("Failed to initialize identity bundle signer:", err)
+	}
+
+	// ✅ Signs public agent lookups, badges and status pages (key derived from the KeyVault unless configured)
+	publicStatusSigner, err := crypto.NewPublicStatusSignerFromEnv(keyVault)
+	if err != nil {
+		log.Fatal("Failed to initialize public status signer:", err)
+	}
+
+	// ✅ Signs the verifiable credentials issued to agents (key derived from the KeyVault unless configured)
+	agentCredentialSigner, err := crypto.NewAgentCredentialSignerFromEnv(keyVault)
+	if err != nil {
+		log.Fatal("Failed to initialize agent credential signer:", err)
+	}
+
+	// ✅ Object storage for artifacts, isolated per organization (STORAGE_PROVIDER: local, s3, gcs or azure)
+	artifactStore, err := storage.NewArtifactStoreFromEnv()
+	if err != nil {
+		log.Fatal("Failed to initialize artifact storage:", err)
+	}
+	log.Printf("✅ Artifact storage initialized (%s)", artifactStore.Provider())
+
+	// ✅ Transactional email is queued and sent in the background with retries, from organizations'
+	// own senders; services keep getting nil without an email provider
+	emailRenderer, err := email.NewTemplateRenderer(os.Getenv("EMAIL_TEMPLATES_DIR"))
+	if err != nil {
+		log.Fatal("Failed to load email templates:", err)
+	}
+	emailQueueService := application.NewEmailQueueService(
+		repos.EmailQueue,
+		repos.EmailSuppression,
+		repos.EmailSender,
+		repos.PlatformOperator, // ✅ Suppression removals are kept in the operator log
+		emailService,
+		emailRenderer,
+		cfg.EmailQueue.MaxAttempts,
+		cfg.EmailQueue.BounceWebhookSecret,
+	)
+	if emailQueueService.Enabled() {
+		emailService = emailQueueService
+	}
+
+	// ✅ Initialize webhook service FIRST (alerts, drift, verification and attestation events are delivered to webhooks)
+	webhookService := application.NewWebhookService(
+		repos.Webhook,
+		repos.Tag, // ✅ For agent tags in webhook filter expressions
+		keyVault,  // ✅ For per-organization mTLS client keys
+		cfg.Webhooks.EgressProxyURL,
+		cfg.Webhooks.EgressIPs,
+	)
+
+	// ✅ Streams threats, anomalies, alerts and verification failures to organizations' SIEMs
+	siemExportService := application.NewSIEMExportService(
+		repos.SIEMExporter,
+		func(exporter *domain.SIEMExporter) (domain.SIEMSink, error) {
+			return siem.ForExporter(exporter)
+		},
+	)
+	// ✅ Verification, drift, trust score and alert events are published to NATS or Kafka (EVENT_BUS_DRIVER)
+	eventBus, err := eventbus.New(eventbus.Config{
+		Driver:      cfg.EventBus.Driver,
+		URL:         cfg.EventBus.URL,
+		TopicPrefix: cfg.EventBus.TopicPrefix,
+	})
+	if err != nil {
+		log.Fatal("Failed to initialize event bus:", err)
+	}
+	if eventBus != nil {
+		log.Printf("✅ Domain events are published to %s", eventBus.Name())
+	}
+	domainEventService := application.NewDomainEventService(repos.DomainEventOutbox, eventBus)
+
+	verificationEvents := domainEventService.VerificationEventRepository(siemExportService.VerificationEventRepository(repos.VerificationEvent))
+
+	// ✅ Alerts that services create directly are also published to webhooks, SIEMs and the event bus
+	webhookAlerts := domainEventService.AlertRepository(siemExportService.AlertRepository(webhookService.AlertRepository(repos.Alert)))
+
+	auditService := application.NewAuditService(repos.AuditLog)
+
+	// ✅ Runs the organization's trust score triggers (alert, webhook, suspend, revoke keys, open incident)
+	trustScoreTriggerService := application.NewTrustScoreTriggerService(
+		repos.TrustScoreTrigger,
+		repos.Agent,
+		repos.APIKey,
+		webhookAlerts,
+		webhookService,
+		auditService,
+	)
+
+	// ✅ Trust score changes are published to the event bus and evaluated against trust score triggers
+	scoredAgents := trustScoreTriggerService.AgentRepository(domainEventService.AgentRepository(repos.Agent))
+
+	// ✅ Approval chains gate capability grants, agent verification and policy disabling
+	approvalChainService := application.NewApprovalChainService(
+		repos.ApprovalChain,
+		repos.ApprovalRequest,
+		repos.User,
+		repos.Tag, // ✅ Agent tags scope agent_verification chains
+	)
+
+	// ✅ Sub-organizations: scoped visibility, roll-up metrics and inherited policies
+	organizationHierarchyService := application.NewOrganizationHierarchyService(
+		repos.Organization,
+		repos.Agent,
+		repos.Security,
+	)
+
 	// ✅ Initialize Security Policy Service for policy-based enforcement
 	securityPolicyService := application.NewSecurityPolicyService(
-		repos.SecurityPolicy,
-		repos.Alert,
+		organizationHierarchyService.SecurityPolicyRepository(repos.SecurityPolicy), // ✅ Sub-organizations inherit their ancestors' policies
+		webhookAlerts,
 		repos.AuditLog,
+		repos.MCPCapability, // ✅ Risk overrides for sensitive MCP tool policies
+		approvalChainService,
 	)
 
 	// Create services
@@ -453,42 +1323,94 @@ func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCach
 	adminService := application.NewAdminService(
 		repos.User,
 		repos.Organization,
+		repos.Agent, // ✅ For dropping escrowed private keys when client-side key generation is turned on
 	)
 
-	auditService := application.NewAuditService(repos.AuditLog)
-
 	trustCalculator := application.NewTrustCalculatorWithVerification(
 		repos.TrustScore,
 		repos.APIKey,
 		repos.AuditLog,
 		repos.Capability,
-		repos.Agent,             // For fetching agent data
+		scoredAgents,            // For fetching agent data
 		repos.Alert,             // For security alerts scoring
 		repos.VerificationEvent, // For real verification statistics
+		repos.SBOM,              // For known vulnerabilities (compliance)
 	)
+	// ✅ Uptime of the MCP servers agents talk to feeds their Uptime factor
+	trustCalculator.UseMCPHealth(repos.MCPServer, repos.MCPHealth)
 
 	// ✅ Initialize drift detection service BEFORE verification event service
 	driftDetectionService := application.NewDriftDetectionService(
-		repos.Agent,
-		repos.Alert,
+		scoredAgents,
+		webhookAlerts,
 	)
+	driftDetectionService.UseRemediations(repos.DriftRemediation) // ✅ Drift alerts link to a proposed change set admins approve or reject
+	driftDetectionService.UseToolGrants(repos.MCPToolGrant)       // ✅ Tool calls outside an agent's tool grants are flagged as tool drift
 
 	// ✅ Initialize verification event service BEFORE agent service
 	verificationEventService := application.NewVerificationEventService(
-		repos.VerificationEvent,
+		verificationEvents, // ✅ Failed verifications are streamed to SIEMs
 		repos.Agent,
 		driftDetectionService,
+		webhookService,
+		securityPolicyService, // ✅ Expression policies can deny verifications
+		repos.Security,        // ✅ Recent anomalies raise the risk level of verification events
 	)
+	verificationEventService.UseBroker(application.NewVerificationEventBroker()) // ✅ Recorded events are pushed to dashboard streams
 
-	agentService := application.NewAgentService(
+	// ✅ Initialize policy decision service BEFORE agent service (verify-action can be delegated to OPA)
+	policyDecisionService := application.NewPolicyDecisionService(
+		repos.PolicyDecision,
+		opa.NewClient(),
+	)
+
+	// ✅ Report download links, certificate and CRL locations point at the public API URL
+	// (also advertised as the issuer in the OAuth authorization server metadata)
+	publicURL := os.Getenv("AIM_PUBLIC_URL")
+	if publicURL == "" {
+		publicURL = "http://localhost:8080"
+	}
+
+	// ✅ Internal CA issuing short-lived X.509 certificates to verified agents (key derived from the KeyVault unless configured)
+	agentCA, err := crypto.NewAgentCAFromEnv(keyVault)
+	if err != nil {
+		log.Fatal("Failed to initialize agent CA:", err)
+	}
+	agentCertificateService := application.NewAgentCertificateService(
+		repos.AgentCertificate,
 		repos.Agent,
+		agentCA,
+		cfg.AgentCertificateValidity,
+		publicURL,
+	)
+
+	// ✅ Verifiable credentials are issued under the did:web of the public API URL's host
+	agentCredentialService, err := application.NewAgentCredentialService(repos.Agent, repos.Capability, agentCredentialSigner, publicURL)
+	if err != nil {
+		log.Fatal("Failed to initialize agent credential service:", err)
+	}
+
+	// ✅ Public keys registered to several agents are blocked at registration and flagged as threats
+	sharedKeyService := application.NewSharedKeyService(repos.SharedKey, webhookAlerts)
+
+	// ✅ Naming policies are checked on agent and MCP server registration, rename and tagging
+	namingPolicyService := application.NewNamingPolicyService(repos.NamingPolicy, repos.Agent, repos.MCPServer, repos.Tag)
+
+	agentService := application.NewAgentService(
+		scoredAgents,
 		trustCalculator,
 		repos.TrustScore,
 		keyVault,                 // ✅ NEW: Inject KeyVault for automatic key generation
-		repos.Alert,              // ✅ NEW: Inject AlertRepository for security alerts
+		webhookAlerts,            // ✅ NEW: Inject AlertRepository for security alerts
 		securityPolicyService,    // ✅ NEW: Inject SecurityPolicyService for policy evaluation
 		repos.Capability,         // ✅ NEW: Inject CapabilityRepository for capability checks
 		verificationEventService, // ✅ NEW: Inject VerificationEventService for creating verification events
+		policyDecisionService,    // ✅ NEW: Inject PolicyDecisionService for external PDP (OPA) decisions
+		repos.Organization,       // ✅ NEW: Inject OrganizationRepository for agent quota checks
+		approvalChainService,     // ✅ NEW: Inject ApprovalChainService for multi-step verification approval
+		agentCertificateService,  // ✅ NEW: Inject AgentCertificateService for X.509 agent certificates
+		sharedKeyService,         // ✅ NEW: Inject SharedKeyService to block reuse of other agents' public keys
+		namingPolicyService,      // ✅ NEW: Inject NamingPolicyService to enforce the organization's naming policies
 	)
 
 	apiKeyService := application.NewAPIKeyService(
@@ -496,10 +1418,39 @@ func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCach
 		repos.Agent,
 	)
 
+	// ✅ Initialize notification service BEFORE alert service (new alerts are fanned out to channels)
+	notificationService := application.NewNotificationService(
+		repos.Notification,
+		notification.NewEmailSender(emailService),
+		notification.NewSlackSender(),
+		notification.NewWebhookSender(),
+		notification.NewPagerDutySender(),
+	)
+	notificationService.UseRoutes(repos.NotificationRoute, repos.Tag) // ✅ Alert routing rules by type, severity and resource tags
+
+	// ✅ Reports are pushed through notification channels; download links point at the public API URL
+	reportService := application.NewReportService(
+		repos.Report,
+		notificationService,
+		artifactStore, // ✅ Rendered report files
+		publicURL,
+		report.NewCSVRenderer(),
+		report.NewPDFRenderer(),
+	)
+
+	// ✅ Suppression rules drop or downgrade known-noisy alerts before notification
+	alertSuppressionService := application.NewAlertSuppressionService(
+		repos.AlertSuppression,
+		repos.Tag,
+	)
+
 	alertService := application.NewAlertService(
 		repos.Alert,
 		repos.Agent,
 		db,
+		notificationService,     // ✅ For fanning new alerts out to notification channels
+		alertSuppressionService, // ✅ Applied before notification
+		webhookService,          // ✅ Publishes new alerts to webhook subscriptions
 	)
 
 	complianceService := application.NewComplianceService(
@@ -508,40 +1459,126 @@ func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCach
 		repos.User,
 	)
 
+	// ✅ Deprecates agent capabilities referring to tools MCP servers no longer expose
+	capabilityDeprecationService := application.NewCapabilityDeprecationService(
+		repos.CapabilityDeprecation,
+		repos.Capability,
+		repos.Agent,
+		repos.MCPServer,
+		repos.MCPCapability,
+		repos.User,
+		alertService,
+		emailService,
+	)
+
 	// ✅ Initialize MCP capability service BEFORE MCP service
 	mcpCapabilityService := application.NewMCPCapabilityService(
 		repos.MCPCapability,
 		repos.MCPServer,
+		capabilityDeprecationService, // ✅ Deprecates agent capabilities of tools discovery no longer finds
+		alertService,                 // ✅ Alerts when a verified server's tool list changes
 	)
 
 	mcpService := application.NewMCPService(
 		repos.MCPServer,
-		repos.VerificationEvent,
+		verificationEvents,
 		repos.User,
 		keyVault,                 // ✅ For automatic key generation
 		mcpCapabilityService,     // ✅ For automatic capability detection
 		repos.MCPCapability,      // ✅ For creating SDK capabilities
 		repos.AgentMCPConnection, // ✅ For tracking agent-MCP connections
 		repos.Agent,              // ✅ For connected agents tracking
+		repos.MCPAttestation,     // ✅ For private network relay checks
+		namingPolicyService,      // ✅ For naming policies on registration and rename
 	)
 
-	// ✅ Initialize MCP Attestation Service for agent attestation of MCPs
-	mcpAttestationService := application.NewMCPAttestationService(
-		repos.MCPAttestation,
+	// ✅ Single-use nonces agents sign into attestations
+	attestationNonceService := application.NewAttestationNonceService(repos.AttestationNonce)
+
+	// ✅ Runs containment playbooks when incidents open; services that open incidents go through
+	// its security repository wrapper
+	playbookService := application.NewPlaybookService(
+		repos.Playbook,
+		repos.Security,
+		agentService,
+		alertSuppressionService,
+		notificationService,
+		ticketing.NewClient(ticketing.ConfigFromEnv()), // ✅ Jira / ServiceNow credentials from env
+	)
+	// ✅ Opens Jira / ServiceNow tickets for new capability requests and incidents and syncs them back
+	ticketConnectorService := application.NewTicketConnectorService(
+		repos.TicketConnector,
 		repos.Agent,
-		repos.MCPServer,
-		repos.User,
-		repos.AgentMCPConnection,
+		keyVault, // ✅ Connector API tokens are stored encrypted
+		func(connector *domain.TicketConnector, apiToken string) domain.TicketSystem {
+			return ticketing.ForConnector(connector, apiToken)
+		},
 	)
+	// ✅ Threats and anomalies are streamed to SIEMs
+	incidentRepo := siemExportService.SecurityRepository(ticketConnectorService.SecurityRepository(playbookService.SecurityRepository(repos.Security)))
+	trustScoreTriggerService.UseIncidents(incidentRepo) // ✅ Incidents opened by triggers start matching playbooks
 
 	securityService := application.NewSecurityService(
-		repos.Security,
+		incidentRepo,
 		repos.Agent,
 		repos.Alert, // ✅ For converting alerts to threats (NO MOCK DATA!)
 	)
 
-	webhookService := application.NewWebhookService(
-		repos.Webhook,
+	// ✅ Backs off, challenges and locks out repeated failed logins; lockouts open brute force threats
+	loginProtectionService := application.NewLoginProtectionService(
+		repos.LoginAttempt,
+		repos.User,
+		securityService,
+		application.LoginProtectionPolicy{
+			Window: cfg.LoginProtection.Window,
+			Email: application.LoginThresholds{
+				BackoffAfter: cfg.LoginProtection.EmailBackoffAfter,
+				CaptchaAfter: cfg.LoginProtection.EmailCaptchaAfter,
+				LockoutAfter: cfg.LoginProtection.EmailLockoutAfter,
+			},
+			IP: application.LoginThresholds{
+				BackoffAfter: cfg.LoginProtection.IPBackoffAfter,
+				CaptchaAfter: cfg.LoginProtection.IPCaptchaAfter,
+				LockoutAfter: cfg.LoginProtection.IPLockoutAfter,
+			},
+			BackoffBase:     cfg.LoginProtection.BackoffBase,
+			BackoffMax:      cfg.LoginProtection.BackoffMax,
+			LockoutDuration: cfg.LoginProtection.LockoutDuration,
+		},
+	)
+	if cfg.LoginProtection.CaptchaSecret != "" {
+		loginProtectionService.UseCaptcha(captcha.NewSiteVerifier(cfg.LoginProtection.CaptchaVerifyURL, cfg.LoginProtection.CaptchaSecret))
+	}
+
+	// ✅ Flags agents whose attestations keep disagreeing with an MCP server's capabilities
+	capabilityClaimService := application.NewCapabilityClaimService(
+		repos.MCPAttestation,
+		repos.MCPCapability,
+		securityService,
+	)
+
+	// ✅ Holds MCP servers to the attestation cadence of their criticality
+	attestationCadenceService := application.NewMCPAttestationCadenceService(
+		repos.AttestationCadencePolicy,
+		repos.MCPServer,
+		repos.MCPAttestation,
+		webhookAlerts,
+	)
+
+	// ✅ Initialize MCP Attestation Service for agent attestation of MCPs
+	mcpAttestationService := application.NewMCPAttestationService(
+		repos.MCPAttestation,
+		repos.Agent,
+		repos.MCPServer,
+		repos.User,
+		repos.AgentMCPConnection,
+		repos.Organization,           // ✅ For attestation-driven MCP auto-registration
+		alertService,                 // ✅ Queues auto-registered MCP servers for admin review
+		attestationNonceService,      // ✅ Rejects replayed attestations
+		webhookService,               // ✅ Publishes expired attestations
+		capabilityDeprecationService, // ✅ Deprecates agent capabilities of tools attestations no longer find
+		capabilityClaimService,       // ✅ Raises anomalies for attestations that keep disagreeing with the server
+		attestationCadenceService,    // ✅ Lowers the confidence of servers out of their attestation cadence
 	)
 
 	// Initialize RegistrationService for email/password user registration workflow
@@ -557,25 +1594,55 @@ func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCach
 		repos.Tag,
 		repos.Agent,
 		repos.MCPServer,
+		namingPolicyService, // ✅ Naming policies scoped to the added tags
 	)
 
+	// ✅ Locates the addresses SDK devices were last used from
+	ipLocator, err := geoip.NewLocator(cfg.GeoIPDatabasePath)
+	if err != nil {
+		log.Fatal("Failed to load GeoIP database:", err)
+	}
+
 	sdkTokenService := application.NewSDKTokenService(
 		repos.SDKToken,
+		ipLocator,
+	)
+
+	// ✅ Runs the incident response bundle whenever an agent is marked compromised
+	compromiseService := application.NewCompromiseResponseService(
+		repos.CompromiseResponse,
+		repos.Agent,
+		repos.APIKey,
+		repos.SDKToken,
+		incidentRepo, // ✅ Opened incidents start matching playbooks
+		repos.MCPServer,
+		repos.User,
+		mcpAttestationService,
+		notificationService,
+		emailService,
+		agentCertificateService, // ✅ Compromised agents' certificates go on the revocation list
 	)
 
 	capabilityService := application.NewCapabilityService(
 		repos.Capability,
-		repos.Agent,
+		scoredAgents,
 		repos.AuditLog,
 		trustCalculator,
 		repos.TrustScore,
+		compromiseService,           // ✅ Capability violations past the threshold trigger the response bundle
+		repos.CapabilityDeprecation, // ✅ Flags capabilities referring to dropped MCP tools
+		repos.Organization,          // ✅ For the organization's key algorithm policy
 	)
 
 	capabilityRequestService := application.NewCapabilityRequestService(
-		repos.CapabilityRequest,
+		ticketConnectorService.CapabilityRequestRepository(repos.CapabilityRequest), // ✅ New requests get tickets
 		repos.Capability,
 		repos.Agent,
+		approvalChainService, // ✅ Grants may need approvals from several admins
 	)
+	if emailService != nil {
+		capabilityRequestService.UseEmail(emailService) // ✅ Requesters are emailed the decision
+	}
 
 	detectionService := application.NewDetectionService(
 		db,
@@ -583,6 +1650,98 @@ func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCach
 		repos.Agent,     // ✅ NEW: Inject agent repository to fetch agent data
 	)
 
+	entitlementService := application.NewEntitlementService(
+		repos.Entitlement,
+		repos.MCPServer,
+	)
+
+	driftAnalyticsService := application.NewDriftAnalyticsService(
+		repos.DriftAnalytics,
+		repos.Agent,
+		repos.Tag,
+	)
+
+	// ✅ Turns trust scores into automation (suspend below the floor, reward sustained high trust)
+	trustBoundaryService := application.NewTrustBoundaryService(
+		organizationHierarchyService.TrustBoundaryRepository(repos.TrustBoundary), // ✅ Sub-organizations inherit the nearest ancestor's boundaries
+		repos.Agent,
+		repos.APIKey,
+		incidentRepo, // ✅ Opened incidents start matching playbooks
+		auditService,
+	)
+
+	// ✅ Keeps a redacted record of deleted agents, MCP servers and API keys
+	tombstoneService := application.NewTombstoneService(
+		repos.Tombstone,
+		repos.Agent,
+		repos.MCPServer,
+		repos.APIKey,
+		repos.User,
+	)
+
+	// ✅ Lets relying parties validate our tokens (RFC 7662 introspection)
+	tokenIntrospectionService := application.NewTokenIntrospectionService(
+		jwtService,
+		repos.SDKToken,
+		repos.APIKey,
+		repos.Agent,
+		repos.User,
+		publicURL,
+	)
+
+	// ✅ Break-glass access for agents whose SDK token refresh chain is broken
+	emergencyAccessService := application.NewEmergencyAccessService(
+		repos.Emergency,
+		repos.Agent,
+		repos.User,
+		webhookAlerts,
+		jwtService,
+	)
+
+	// ✅ SAML 2.0 SSO; SP metadata and ACS URLs are served from the public API URL
+	samlService := application.NewSAMLService(
+		repos.SAMLConfig,
+		repos.User,
+		repos.Organization,
+		publicURL,
+	)
+
+	// ✅ SBOM ingestion; components are checked against OSV (OSV_API_URL overrides api.osv.dev)
+	sbomService := application.NewSBOMService(
+		repos.SBOM,
+		repos.Agent,
+		webhookAlerts,
+		osv.NewClient(os.Getenv("OSV_API_URL")),
+	)
+
+	// ✅ Per-organization API rate limits; buckets are shared through Redis when it is available
+	var rateLimitStore domain.RateLimitStore = cache.NewMemoryRateLimitStore()
+	if cacheService != nil {
+		rateLimitStore = cache.NewRedisRateLimitStore(cacheService)
+	} else {
+		log.Println("ℹ️  Organization rate limits are tracked per server instance (Redis unavailable)")
+	}
+	rateLimitService := application.NewRateLimitService(
+		repos.Organization,
+		repos.PlatformOperator, // ✅ Operators throttle abusive organizations
+		securityService,
+		rateLimitStore,
+		cfg.OrgRateLimits,
+	)
+
+	// ✅ For the incident management workflow
+	incidentService := application.NewIncidentService(
+		incidentRepo,
+		repos.Incident,
+		webhookAlerts,
+		repos.VerificationEvent,
+		repos.User,
+		emailService,
+		notificationService,
+	)
+	// ✅ Ticket statuses decide capability requests and move incidents
+	ticketConnectorService.UseWorkflows(capabilityRequestService, incidentService)
+
 	return &Services{
 		Auth:              authService,
 		Admin:             adminService,
@@ -603,8 +1762,238 @@ func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCach
 		Tag:               tagService,
 		SDKToken:          sdkTokenService,
 		Capability:        capabilityService,
-		CapabilityRequest: capabilityRequestService, // ✅ For capability expansion approval workflow
-		Detection:         detectionService,         // ✅ For MCP auto-detection (SDK + Direct API)
+		CapabilityRequest: capabilityRequestService,  // ✅ For capability expansion approval workflow
+		Detection:         detectionService,          // ✅ For MCP auto-detection (SDK + Direct API)
+		Notification:      notificationService,       // ✅ For queue-backed notification fan-out
+		Report:            reportService,             // ✅ For scheduled report subscriptions
+		PolicyDecision:    policyDecisionService,     // ✅ For external policy decision points (OPA)
+		Compromise:        compromiseService,         // ✅ For compromised-agent response bundles
+		Entitlement:       entitlementService,        // ✅ For entitlement (access) reviews
+		DriftAnalytics:    driftAnalyticsService,     // ✅ For drift trend analytics
+		TrustBoundary:     trustBoundaryService,      // ✅ For trust score floor/ceiling actions
+		Tombstone:         tombstoneService,          // ✅ For records of deleted entities
+		Introspection:     tokenIntrospectionService, // ✅ For relying-party token validation
+		AlertSuppression:  alertSuppressionService,   // ✅ For alert suppression rules
+		Emergency:         emergencyAccessService,    // ✅ For sealed break-glass credentials
+		// ✅ For agent SBOMs and their vulnerability scans
+		SBOM: sbomService,
+		// ✅ For multi-step approval chains
+		Approval: approvalChainService,
+		// ✅ For SAML 2.0 single sign-on
+		SAML: samlService,
+		// ✅ For signed export files
+		ExportSigner: exportSigner,
+		// ✅ For signed public agent lookups, badges and status pages
+		PublicStatusSigner: publicStatusSigner,
+		// ✅ For per-agent activity timelines
+		AgentTimeline: application.NewAgentTimelineService(repos.AgentTimeline, repos.Agent),
+		// ✅ For artifacts kept in object storage (exports, archived events, attestations, reports, SBOMs)
+		Artifacts: artifactStore,
+		// ✅ For per-agent quotas by plan tier
+		AgentQuota: application.NewAgentQuotaService(repos.Agent, repos.Organization, cfg.AgentQuotas),
+		// ✅ For X.509 certificates issued to verified agents
+		AgentCertificate: agentCertificateService,
+		// ✅ For incident-driven containment playbooks
+		Playbook: playbookService,
+		// ✅ For publicly discoverable agents
+		AgentListing: application.NewAgentListingService(repos.AgentListing, repos.Agent, repos.Organization, publicURL),
+		// ✅ For staged configuration changes
+		ChangeRequest: application.NewChangeRequestService(
+			repos.ChangeRequest,
+			repos.Agent,
+			repos.SecurityPolicy,
+			repos.VerificationEvent,
+			approvalChainService,
+			alertService,
+		),
+		// ✅ For TOTP multi-factor authentication
+		MFA: application.NewMFAService(repos.User, repos.Organization, keyVault),
+		// ✅ For behavioral baselines and anomaly detection
+		AnomalyBaseline: application.NewAnomalyBaselineService(
+			repos.VerificationEvent,
+			repos.Agent,
+			repos.Organization,
+			securityService,
+			cfg.Jobs.AnomalyBaselineWindow,
+			cfg.Jobs.AnomalyZScoreThreshold,
+		),
+		// ✅ For public keys shared across agents
+		SharedKey: sharedKeyService,
+		// ✅ For talks_to suggestions from actual MCP server usage
+		TalksToRecommendation: application.NewTalksToRecommendationService(
+			repos.Agent,
+			repos.VerificationEvent,
+			repos.MCPAttestation,
+			repos.MCPServer,
+		),
+		// ✅ For asynchronous verification event exports
+		VerificationExport: application.NewVerificationExportService(
+			repos.VerificationExport,
+			repos.VerificationEvent,
+			repos.User,
+			artifactStore,
+			emailService,
+			publicURL,
+			export.NewCSVEncoder(),
+			export.NewParquetEncoder(),
+		),
+		// ✅ For agent-to-agent peer policies
+		AgentPeer: application.NewAgentPeerService(
+			repos.AgentPeerPolicy,
+			repos.Agent,
+			verificationEvents,
+			driftDetectionService,
+			webhookService,
+		),
+		// ✅ For per-tool MCP grants
+		MCPToolGrant: application.NewMCPToolGrantService(repos.MCPToolGrant, repos.Agent),
+		// ✅ For database schema version reporting
+		Schema: application.NewSchemaService(
+			database.NewMigrator(db, migrations.Files, cfg.Database.MigrationLockTimeout),
+			cfg.Database.AutoMigrate,
+		),
+		// ✅ For per-organization API rate limits
+		RateLimit: rateLimitService,
+		// ✅ For preview feature flags
+		FeatureFlag: application.NewFeatureFlagService(repos.FeatureFlag),
+		// ✅ For agent-MCP connection latency SLOs
+		ConnectionLatency: application.NewConnectionLatencyService(
+			repos.ConnectionLatencySLO,
+			repos.MCPAttestation,
+			repos.MCPServer,
+			repos.Agent,
+			webhookAlerts,
+		),
+		// ✅ For agent identity export and import between deployments
+		AgentPortability: application.NewAgentPortabilityService(
+			repos.Agent,
+			repos.TrustScore,
+			repos.MCPAttestation,
+			repos.MCPServer,
+			repos.AgentLineage,
+			sharedKeyService,
+			identityBundleSigner,
+			publicURL,
+		),
+		// ✅ For the incident management workflow
+		Incident: incidentService,
+		// ✅ For scoped personal access tokens
+		PersonalAccessToken: application.NewPersonalAccessTokenService(repos.PersonalAccessToken, repos.User),
+		// ✅ For attestation cadences by MCP server criticality
+		AttestationCadence: attestationCadenceService,
+		// ✅ For sub-organizations
+		OrganizationHierarchy: organizationHierarchyService,
+		// ✅ For W3C verifiable credentials issued to agents
+		AgentCredential: agentCredentialService,
+		// ✅ For demo data seeded by admins
+		DemoData: application.NewDemoDataService(
+			agentService,
+			verificationEventService,
+			securityService,
+			repos.MCPServer,
+			repos.MCPAttestation,
+			repos.TrustScore,
+			repos.Alert,
+			repos.Security,
+			repos.DemoRecord,
+		),
+		// ✅ For sub-agents delegated by agents
+		AgentDelegation: application.NewAgentDelegationService(
+			repos.AgentDelegation,
+			scoredAgents,
+			repos.Capability,
+			agentService,
+		),
+		// ✅ For Jira / ServiceNow ticket connectors of organizations
+		TicketConnector: ticketConnectorService,
+		// ✅ For the platform operator API
+		PlatformOperator: application.NewPlatformOperatorService(
+			repos.PlatformOperator,
+			repos.Organization,
+			repos.User,
+			repos.Agent,
+			repos.MCPServer,
+			repos.Alert,
+			repos.Security,
+			repos.VerificationEvent,
+			rateLimitService,
+		),
+		// ✅ For runtime settings changed through the operator API
+		RuntimeConfig: application.NewRuntimeConfigService(repos.RuntimeSetting, repos.PlatformOperator),
+		// ✅ For SIEM syslog exporters of organizations
+		SIEMExport: siemExportService,
+		// ✅ For naming policies of agents and MCP servers
+		NamingPolicy: namingPolicyService,
+		// ✅ For time-bound capabilities removed once they expire
+		CapabilityExpiry: application.NewCapabilityExpiryService(
+			repos.Capability,
+			repos.Agent,
+			repos.User,
+			alertService,
+			emailService,
+		),
+		// ✅ For MCP server health checks and uptime
+		MCPHealth: application.NewMCPHealthService(
+			repos.MCPHealth,
+			repos.MCPServer,
+			webhookAlerts,
+			cfg.Jobs.MCPHealthFailureThreshold,
+		),
+		// ✅ For responses replayed to retries sent with an Idempotency-Key
+		Idempotency: application.NewIdempotencyService(repos.Idempotency, cfg.IdempotencyWindow),
+		// ✅ For approving or rejecting the change sets proposed with configuration drift alerts
+		DriftRemediation: application.NewDriftRemediationService(
+			repos.DriftRemediation,
+			repos.Agent,
+			webhookAlerts,
+			agentService,
+			securityPolicyService,
+		),
+		// ✅ For per-agent and per-API key usage analytics
+		UsageAnalytics: application.NewUsageAnalyticsService(
+			repos.UsageRollup,
+			repos.VerificationEvent,
+			repos.Agent,
+			repos.APIKey,
+		),
+		// ✅ For custom roles and the permissions routes require
+		Role: application.NewRoleService(repos.CustomRole, repos.User),
+		// ✅ For verification, drift, trust score and alert events published to NATS or Kafka
+		DomainEvents: domainEventService,
+		// ✅ For GDPR data exports and approved erasure of users' personal data
+		PersonalData: application.NewPersonalDataService(
+			repos.User,
+			repos.SDKToken,
+			repos.PersonalAccessToken,
+			repos.AuditLog,
+			repos.ApprovalRequest,
+			repos.DataErasureRequest,
+			repos.PersonalData,
+		),
+		// ✅ For onboarding users through expiring invitation links
+		Invitation: application.NewInvitationService(
+			repos.Invitation,
+			repos.User,
+			repos.Organization,
+			jwtService,
+			auditService,
+			emailService,
+		),
+		// ✅ For actions run when trust scores cross a threshold
+		TrustScoreTrigger: trustScoreTriggerService,
+		// ✅ For queued transactional email, organizations' senders and bounce suppression
+		EmailQueue: emailQueueService,
+		// ✅ For brute force and credential stuffing protection of password logins
+		LoginProtection: loginProtectionService,
+		// ✅ For the region organizations' agents, verification events and MCP attestations are stored in
+		DataResidency: application.NewDataResidencyService(
+			repos.Organization,
+			repos.Agent,
+			repos.VerificationEvent,
+			repos.DataPlacement,
+			cfg.Residency.HomeRegion,
+			slices.Sorted(maps.Keys(cfg.Residency.RegionClusters)),
+		),
 	}, keyVault
 }
 
@@ -633,14 +2022,295 @@ type Handlers struct {
 	Capability         *handlers.CapabilityHandler
 	Detection          *handlers.DetectionHandler          // ✅ For MCP auto-detection (SDK + Direct API)
 	CapabilityRequest  *handlers.CapabilityRequestHandlers // ✅ For capability request approval
+	Notification       *handlers.NotificationHandler       // ✅ For notification channels and delivery status
+	Report             *handlers.ReportHandler             // ✅ For scheduled report subscriptions
+	PolicyDecision     *handlers.PolicyDecisionHandler     // ✅ For external policy decision points (OPA)
+	Compromise         *handlers.CompromiseResponseHandler // ✅ For compromised-agent response bundles
+	Entitlement        *handlers.EntitlementHandler        // ✅ For entitlement (access) reviews
+	DriftAnalytics     *handlers.DriftAnalyticsHandler     // ✅ For drift trend analytics
+	TrustBoundary      *handlers.TrustBoundaryHandler      // ✅ For trust score floor/ceiling actions
+	Tombstone          *handlers.TombstoneHandler          // ✅ For records of deleted entities
+	Introspection      *handlers.TokenIntrospectionHandler // ✅ For relying-party token validation
+	AlertSuppression   *handlers.AlertSuppressionHandler   // ✅ For alert suppression rules
+	Emergency          *handlers.EmergencyAccessHandler    // ✅ For sealed break-glass credentials
+	// ✅ For agent SBOMs and their vulnerability scans
+	SBOM *handlers.SBOMHandler
+	// ✅ For multi-step approval chains
+	Approval *handlers.ApprovalChainHandler
+	// ✅ For SAML 2.0 single sign-on
+	SAML *handlers.SAMLHandler
+	// ✅ For verifying signed export files
+	Export *handlers.ExportHandler
+	// ✅ For per-agent activity timelines
+	AgentTimeline *handlers.AgentTimelineHandler
+	// ✅ For agent certificates and their revocation list
+	AgentCertificate *handlers.AgentCertificateHandler
+	// ✅ For incident-driven containment playbooks
+	Playbook *handlers.PlaybookHandler
+	// ✅ For publicly discoverable agents
+	AgentListing *handlers.AgentListingHandler
+	// ✅ For staged configuration changes
+	ChangeRequest *handlers.ChangeRequestHandler
+	// ✅ For TOTP multi-factor authentication
+	MFA *handlers.MFAHandler
+	// ✅ For public keys shared across agents
+	SharedKey *handlers.SharedKeyHandler
+	// ✅ For talks_to suggestions from actual MCP server usage
+	TalksToRecommendation *handlers.TalksToRecommendationHandler
+	// ✅ For asynchronous verification event exports
+	VerificationExport *handlers.VerificationExportHandler
+	// ✅ For agent-to-agent peer policies
+	AgentPeer *handlers.AgentPeerHandler
+	// ✅ For per-tool MCP grants
+	MCPToolGrant *handlers.MCPToolGrantHandler
+	// ✅ For database schema version reporting
+	Schema *handlers.SchemaHandler
+	// ✅ For preview feature flags
+	FeatureFlag *handlers.FeatureFlagHandler
+	// ✅ For agent-MCP connection latency SLOs
+	ConnectionLatency *handlers.ConnectionLatencyHandler
+	// ✅ For agent identity export and import between deployments
+	AgentPortability *handlers.AgentPortabilityHandler
+	// ✅ For the incident management workflow
+	Incident *handlers.IncidentHandler
+	// ✅ For circuit breaker state of external dependencies
+	DependencyHealth *handlers.DependencyHealthHandler
+	// ✅ For scoped personal access tokens
+	PersonalAccessToken *handlers.PersonalAccessTokenHandler
+	// ✅ For attestation cadences by MCP server criticality
+	AttestationCadence *handlers.AttestationCadenceHandler
+	// ✅ For sub-organizations
+	OrganizationHierarchy *handlers.OrganizationHierarchyHandler
+	// ✅ For W3C verifiable credentials issued to agents
+	AgentCredential *handlers.AgentCredentialHandler
+	// ✅ For demo data seeded by admins
+	DemoData *handlers.DemoDataHandler
+	// ✅ For sub-agents delegated by agents
+	AgentDelegation *handlers.AgentDelegationHandler
+	// ✅ For Jira / ServiceNow ticket connectors of organizations
+	TicketConnector *handlers.TicketConnectorHandler
+	// ✅ For the platform operator API and organization consent to impersonation
+	Operator      *handlers.OperatorHandler
+	Impersonation *handlers.ImpersonationHandler
+	RuntimeConfig *handlers.RuntimeConfigHandler
+	// ✅ For SIEM syslog exporters of organizations
+	SIEMExporter *handlers.SIEMExporterHandler
+	// ✅ For naming policies of agents and MCP servers
+	NamingPolicy *handlers.NamingPolicyHandler
+	// ✅ For the dashboard GraphQL API
+	GraphQL *handlers.GraphQLHandler
+	// ✅ For MCP server health checks and uptime
+	MCPHealth *handlers.MCPHealthHandler
+	// ✅ For approving or rejecting the change sets proposed with configuration drift alerts
+	DriftRemediation *handlers.DriftRemediationHandler
+	// ✅ For per-agent and per-API key usage analytics
+	UsageAnalytics *handlers.UsageAnalyticsHandler
+	// ✅ For custom roles and the permission catalog
+	Role *handlers.RoleHandler
+	// ✅ For GDPR data exports and erasure requests
+	PersonalData *handlers.PersonalDataHandler
+	// ✅ For invitations and their acceptance
+	Invitation *handlers.InvitationHandler
+	// ✅ For trust score triggers and their firings
+	TrustScoreTrigger *handlers.TrustScoreTriggerHandler
+	// ✅ For organizations' email senders, their email log, provider bounce webhooks and the suppression list
+	Email *handlers.EmailHandler
+	// ✅ For the login attempts of the organization's users and unlocking locked out users
+	LoginProtection *handlers.LoginProtectionHandler
+	// ✅ For choosing the region the organization's records are stored in
+	DataResidency *handlers.DataResidencyHandler
+}
+
+// initJobScheduler registers the background jobs that must run once per deployment rather than
+// once per instance
+func initJobScheduler(services *Services, repos *Repositories, cfg *config.Config) *application.JobScheduler {
+	scheduler := application.NewJobScheduler(repos.JobLease, cfg.Jobs.LeaseTTL)
+
+	// Invalidates expired attestations and publishes attestation.expired webhook events
+	scheduler.Register("attestation-expiry", cfg.Jobs.AttestationExpiryInterval, func(ctx context.Context) error {
+		count, err := services.MCPAttestation.InvalidateExpiredAttestations(ctx)
+		if count > 0 {
+			log.Printf("✅ Invalidated %d expired attestations", count)
+		}
+		return err
+	})
+	// Recalculates MCP confidence scores and alerts on servers that fall below the threshold
+	scheduler.Register("mcp-confidence-recalculation", cfg.Jobs.ConfidenceRecalculationInterval, func(ctx context.Context) error {
+		_, err := services.MCPAttestation.RecalculateAllConfidenceScores(ctx, cfg.Jobs.ConfidenceAlertThreshold)
+		return err
+	})
+	// Connects to registered MCP servers and refreshes their tools, resources and prompts
+	scheduler.Register("mcp-capability-discovery", cfg.Jobs.MCPDiscoveryInterval, func(ctx context.Context) error {
+		count, err := services.MCPCapability.DiscoverAllCapabilities(ctx)
+		if count > 0 {
+			log.Printf("✅ Discovered capabilities of %d MCP servers", count)
+		}
+		return err
+	})
+	// Applies approved configuration changes when their window opens and rolls them back when errors rise
+	scheduler.Register("config-change-windows", cfg.Jobs.ChangeWindowInterval, func(ctx context.Context) error {
+		applied, rolledBack, err := services.ChangeRequest.ProcessChangeWindows(ctx)
+		if applied > 0 || rolledBack > 0 {
+			log.Printf("✅ Applied %d configuration changes, rolled back %d", applied, rolledBack)
+		}
+		return err
+	})
+	// Compares agents' last hour of verifications to their learned baselines and records anomalies
+	scheduler.Register("anomaly-detection", cfg.Jobs.AnomalyDetectionInterval, func(ctx context.Context) error {
+		count, err := services.AnomalyBaseline.DetectAllAnomalies(ctx)
+		if count > 0 {
+			log.Printf("✅ Detected %d agent behavior anomalies", count)
+		}
+		return err
+	})
+	// Raises shared_credential alerts for agents holding a public key another agent also holds
+	scheduler.Register("shared-key-detection", cfg.Jobs.SharedKeyDetectionInterval, func(ctx context.Context) error {
+		count, err := services.SharedKey.DetectSharedKeys(ctx)
+		if count > 0 {
+			log.Printf("✅ Raised %d shared public key alerts", count)
+		}
+		return err
+	})
+	// Computes agent-MCP connection p95 latencies from attestations and alerts on sustained SLO breaches
+	scheduler.Register("connection-latency-slos", cfg.Jobs.LatencySLOInterval, func(ctx context.Context) error {
+		count, err := services.ConnectionLatency.EvaluateSLOs(ctx)
+		if count > 0 {
+			log.Printf("✅ Raised %d connection latency SLO alerts", count)
+		}
+		return err
+	})
+	// Alerts on verified MCP servers not attested as often, or by as many agents, as their criticality requires
+	scheduler.Register("attestation-cadence", cfg.Jobs.AttestationCadenceInterval, func(ctx context.Context) error {
+		count, err := services.AttestationCadence.EvaluateCadences(ctx)
+		if count > 0 {
+			log.Printf("✅ Raised %d attestation cadence alerts", count)
+		}
+		return err
+	})
+	// Records incidents not acknowledged or resolved within the SLA of their severity
+	scheduler.Register("incident-slas", cfg.Jobs.IncidentSLAInterval, func(ctx context.Context) error {
+		count, err := services.Incident.EvaluateSLAs(ctx)
+		if count > 0 {
+			log.Printf("✅ Recorded %d incident SLA breaches", count)
+		}
+		return err
+	})
+	// Revokes time-bound capabilities past their expiry and notifies the agents' owners
+	scheduler.Register("capability-expiry", cfg.Jobs.CapabilityExpiryInterval, func(ctx context.Context) error {
+		result, err := services.CapabilityExpiry.ExpireCapabilities(ctx)
+		if result != nil && result.Expired > 0 {
+			log.Printf("✅ Expired %d capabilities of %d agents", result.Expired, result.Agents)
+		}
+		return err
+	})
+	// Probes the URLs of MCP servers, suspends servers failing repeatedly and restores them once they recover
+	scheduler.Register("mcp-health-checks", cfg.Jobs.MCPHealthCheckInterval, func(ctx context.Context) error {
+		result, err := services.MCPHealth.CheckAllServers(ctx)
+		if result != nil && result.Checked > 0 {
+			log.Printf("✅ Health checked %d MCP servers (%d unhealthy, %d suspended, %d restored)",
+				result.Checked, result.Unhealthy, result.Downgraded, result.Restored)
+		}
+		return err
+	})
+	// Deletes the recorded responses of idempotency keys past their replay window
+	scheduler.Register("idempotency-purge", cfg.Jobs.IdempotencyPurgeInterval, func(ctx context.Context) error {
+		count, err := services.Idempotency.PurgeExpired(ctx)
+		if count > 0 {
+			log.Printf("✅ Purged %d expired idempotency records", count)
+		}
+		return err
+	})
+	// Rolls up verification events into daily per-agent and per-API key usage for the usage analytics endpoints
+	scheduler.Register("usage-rollup", cfg.Jobs.UsageRollupInterval, func(ctx context.Context) error {
+		count, err := services.UsageAnalytics.AggregateUsage(ctx)
+		if count > 0 {
+			log.Printf("✅ Rolled up %d daily usage records", count)
+		}
+		return err
+	})
+	// Relays recorded verification, drift, trust score and alert events to the event bus and
+	// deletes those published before the retention period
+	if services.DomainEvents.Enabled() {
+		scheduler.Register("domain-event-relay", cfg.Jobs.DomainEventRelayInterval, func(ctx context.Context) error {
+			if _, err := services.DomainEvents.Relay(ctx); err != nil {
+				return err
+			}
+			count, err := services.DomainEvents.PurgePublished(ctx, cfg.EventBus.Retention)
+			if count > 0 {
+				log.Printf("✅ Purged %d published domain events", count)
+			}
+			return err
+		})
+	}
+	// Sends queued transactional email, retrying failed sends, and deletes sent and failed emails
+	// past the retention period
+	if services.EmailQueue.Enabled() {
+		scheduler.Register("email-queue", cfg.Jobs.EmailQueueInterval, func(ctx context.Context) error {
+			if _, err := services.EmailQueue.ProcessQueue(ctx); err != nil {
+				return err
+			}
+			count, err := services.EmailQueue.PurgeFinished(ctx, cfg.EmailQueue.Retention)
+			if count > 0 {
+				log.Printf("✅ Purged %d sent and failed emails", count)
+			}
+			return err
+		})
+	}
+	// Deletes login attempts past the retention period
+	scheduler.Register("login-attempt-purge", cfg.Jobs.LoginAttemptPurgeInterval, func(ctx context.Context) error {
+		count, err := services.LoginProtection.PurgeAttempts(ctx, cfg.LoginProtection.Retention)
+		if count > 0 {
+			log.Printf("✅ Purged %d login attempts", count)
+		}
+		return err
+	})
+	// Syncs the status and comments of Jira / ServiceNow tickets of open capability requests and incidents
+	scheduler.Register("ticket-sync", cfg.Jobs.TicketSyncInterval, func(ctx context.Context) error {
+		count, err := services.TicketConnector.SyncOpenTickets(ctx)
+		if count > 0 {
+			log.Printf("✅ Synced %d tickets into capability requests and incidents", count)
+		}
+		return err
+	})
+	// Writes queued verification event exports to object storage and emails their requesters
+	scheduler.Register("verification-exports", cfg.Jobs.VerificationExportInterval, func(ctx context.Context) error {
+		count, err := services.VerificationExport.ProcessPendingExports(ctx)
+		if count > 0 {
+			log.Printf("✅ Processed %d verification event exports", count)
+		}
+		return err
+	})
+	// Deletes stored artifacts that are past the retention of their kind (STORAGE_RETENTION_*)
+	scheduler.Register("artifact-lifecycle", cfg.Jobs.ArtifactLifecycleInterval, func(ctx context.Context) error {
+		orgs, err := repos.Organization.List()
+		if err != nil {
+			return err
+		}
+		deleted := 0
+		for _, org := range orgs {
+			count, err := services.Artifacts.ApplyLifecycle(ctx, org.ID)
+			deleted += count
+			if err != nil {
+				log.Printf("⚠️  Artifact lifecycle failed for organization %s: %v", org.ID, err)
+			}
+		}
+		if deleted > 0 {
+			log.Printf("✅ Deleted %d expired artifacts", deleted)
+		}
+		return nil
+	})
+
+	return scheduler
 }
 
 func initHandlers(services *Services, repos *Repositories, jwtService *auth.JWTService, keyVault *crypto.KeyVault, cfg *config.Config, db *sql.DB) *Handlers {
 	return &Handlers{
 		Auth: handlers.NewAuthHandler(
 			services.Auth,
+			services.MFA,
 			jwtService,
 			repos.Organization,
+			services.LoginProtection,
 		),
 		Agent: handlers.NewAgentHandler(
 			services.Agent,
@@ -651,10 +2321,12 @@ func initHandlers(services *Services, repos *Repositories, jwtService *auth.JWTS
 			services.Alert,             // ✅ For creating security alerts on capability violations
 			services.VerificationEvent, // ✅ For recording action verification attempts in Security Dashboard
 			services.Capability,
+			services.Tombstone, // ✅ Deleted agents leave a tombstone
 		),
 		APIKey: handlers.NewAPIKeyHandler(
 			services.APIKey,
 			services.Audit,
+			services.Tombstone, // ✅ Deleted API keys leave a tombstone
 		),
 		TrustScore: handlers.NewTrustScoreHandler(
 			services.Trust,
@@ -681,6 +2353,7 @@ func initHandlers(services *Services, repos *Repositories, jwtService *auth.JWTS
 			services.Audit,
 			repos.Agent,             // ✅ For agent relationships ("Talks To")
 			repos.VerificationEvent, // ✅ For verification events endpoint
+			services.Tombstone,      // ✅ Deleted MCP servers leave a tombstone
 		),
 		MCPAttestation: handlers.NewMCPAttestationHandler(
 			services.MCPAttestation,
@@ -715,6 +2388,7 @@ func initHandlers(services *Services, repos *Repositories, jwtService *auth.JWTS
 			services.Alert,
 			services.Trust,
 			services.VerificationEvent,
+			services.TrustBoundary, // ✅ Sustained high trust relaxes the verification cadence
 		),
 		VerificationEvent: handlers.NewVerificationEventHandler(
 			services.VerificationEvent,
@@ -727,7 +2401,9 @@ func initHandlers(services *Services, repos *Repositories, jwtService *auth.JWTS
 		PublicRegistration: handlers.NewPublicRegistrationHandler(
 			services.Registration, // ✅ Renamed from OAuth to Registration
 			services.Auth,
+			services.MFA,
 			jwtService,
+			services.LoginProtection,
 		),
 		Tag: handlers.NewTagHandler(
 			services.Tag,
@@ -758,6 +2434,152 @@ func initHandlers(services *Services, repos *Repositories, jwtService *auth.JWTS
 			services.CapabilityRequest,
 			repos.Agent,
 		),
+		Notification: handlers.NewNotificationHandler(
+			services.Notification,
+			services.Audit,
+		),
+		Report: handlers.NewReportHandler(
+			services.Report,
+			services.Audit,
+		),
+		PolicyDecision: handlers.NewPolicyDecisionHandler(
+			services.PolicyDecision,
+			services.Audit,
+		),
+		Compromise: handlers.NewCompromiseResponseHandler(
+			services.Compromise,
+			services.Agent,
+			services.Audit,
+		),
+		Entitlement: handlers.NewEntitlementHandler(
+			services.Entitlement,
+			services.Audit,
+		),
+		DriftAnalytics: handlers.NewDriftAnalyticsHandler(
+			services.DriftAnalytics,
+		),
+		TrustBoundary: handlers.NewTrustBoundaryHandler(
+			services.TrustBoundary,
+			services.Audit,
+		),
+		Tombstone: handlers.NewTombstoneHandler(
+			services.Tombstone,
+		),
+		Introspection: handlers.NewTokenIntrospectionHandler(
+			services.Introspection,
+		),
+		AlertSuppression: handlers.NewAlertSuppressionHandler(
+			services.AlertSuppression,
+			services.Audit,
+		),
+		Emergency: handlers.NewEmergencyAccessHandler(
+			services.Emergency,
+			services.Agent,
+			services.Audit,
+		),
+		SBOM: handlers.NewSBOMHandler(
+			services.SBOM,
+			services.Agent,
+			services.Audit,
+		),
+		Approval: handlers.NewApprovalChainHandler(
+			services.Approval,
+			services.Audit,
+		),
+		SAML: handlers.NewSAMLHandler(
+			services.SAML,
+			jwtService,
+			services.Audit,
+		),
+		// ✅ For verifying signed export files
+		Export: handlers.NewExportHandler(services.ExportSigner),
+		// ✅ For per-agent activity timelines
+		AgentTimeline: handlers.NewAgentTimelineHandler(services.AgentTimeline),
+		// ✅ For agent certificates and their revocation list
+		AgentCertificate: handlers.NewAgentCertificateHandler(services.AgentCertificate),
+		// ✅ For incident-driven containment playbooks
+		Playbook: handlers.NewPlaybookHandler(services.Playbook, services.Audit),
+		// ✅ For publicly discoverable agents
+		AgentListing: handlers.NewAgentListingHandler(services.AgentListing, services.Audit, services.PublicStatusSigner),
+		// ✅ For staged configuration changes
+		ChangeRequest: handlers.NewChangeRequestHandler(services.ChangeRequest, services.Audit),
+		// ✅ For TOTP multi-factor authentication
+		MFA: handlers.NewMFAHandler(services.MFA, services.Auth, jwtService, services.Audit),
+		// ✅ For public keys shared across agents
+		SharedKey: handlers.NewSharedKeyHandler(services.SharedKey, services.Audit),
+		// ✅ For talks_to suggestions from actual MCP server usage
+		TalksToRecommendation: handlers.NewTalksToRecommendationHandler(services.TalksToRecommendation),
+		// ✅ For asynchronous verification event exports
+		VerificationExport: handlers.NewVerificationExportHandler(services.VerificationExport, services.Audit),
+		// ✅ For agent-to-agent peer policies
+		AgentPeer: handlers.NewAgentPeerHandler(services.AgentPeer, services.Audit),
+		// ✅ For per-tool MCP grants
+		MCPToolGrant: handlers.NewMCPToolGrantHandler(services.MCPToolGrant, services.Audit),
+		// ✅ For database schema version reporting
+		Schema: handlers.NewSchemaHandler(services.Schema),
+		// ✅ For preview feature flags
+		FeatureFlag: handlers.NewFeatureFlagHandler(services.FeatureFlag, services.Audit),
+		// ✅ For agent-MCP connection latency SLOs
+		ConnectionLatency: handlers.NewConnectionLatencyHandler(services.ConnectionLatency, services.Audit),
+		// ✅ For agent identity export and import between deployments
+		AgentPortability: handlers.NewAgentPortabilityHandler(services.AgentPortability, services.Audit),
+		// ✅ For the incident management workflow
+		Incident: handlers.NewIncidentHandler(services.Incident, services.Audit),
+		// ✅ For scoped personal access tokens
+		PersonalAccessToken: handlers.NewPersonalAccessTokenHandler(services.PersonalAccessToken, services.Audit),
+		// ✅ For circuit breaker state of external dependencies
+		DependencyHealth: handlers.NewDependencyHealthHandler(resilience.Default),
+		// ✅ For attestation cadences by MCP server criticality
+		AttestationCadence: handlers.NewAttestationCadenceHandler(services.AttestationCadence, services.Audit),
+		// ✅ For sub-organizations
+		OrganizationHierarchy: handlers.NewOrganizationHierarchyHandler(services.OrganizationHierarchy, services.Audit),
+		// ✅ For W3C verifiable credentials issued to agents
+		AgentCredential: handlers.NewAgentCredentialHandler(services.AgentCredential, services.Audit),
+		// ✅ For demo data seeded by admins
+		DemoData: handlers.NewDemoDataHandler(services.DemoData, services.Audit),
+		// ✅ For sub-agents delegated by agents
+		AgentDelegation: handlers.NewAgentDelegationHandler(services.AgentDelegation, services.Audit),
+		// ✅ For Jira / ServiceNow ticket connectors of organizations
+		TicketConnector: handlers.NewTicketConnectorHandler(services.TicketConnector, services.Audit),
+		// ✅ For the platform operator API and organization consent to impersonation
+		Operator:      handlers.NewOperatorHandler(services.PlatformOperator),
+		Impersonation: handlers.NewImpersonationHandler(services.PlatformOperator, services.Audit),
+		RuntimeConfig: handlers.NewRuntimeConfigHandler(services.RuntimeConfig),
+		// ✅ For SIEM syslog exporters of organizations
+		SIEMExporter: handlers.NewSIEMExporterHandler(services.SIEMExport, services.Audit),
+		// ✅ For naming policies of agents and MCP servers
+		NamingPolicy: handlers.NewNamingPolicyHandler(services.NamingPolicy, services.Audit),
+		// ✅ For the dashboard GraphQL API
+		GraphQL: handlers.NewGraphQLHandler(graphql.NewDashboard(
+			services.Agent,
+			services.MCP,
+			services.Alert,
+			services.Trust,
+			services.Tag,
+			services.MCPAttestation,
+			services.MCPHealth,
+			cfg.GraphQL.MaxDepth,
+			cfg.GraphQL.MaxComplexity,
+		)),
+		// ✅ For MCP server health checks and uptime
+		MCPHealth: handlers.NewMCPHealthHandler(services.MCPHealth),
+		// ✅ For approving or rejecting the change sets proposed with configuration drift alerts
+		DriftRemediation: handlers.NewDriftRemediationHandler(services.DriftRemediation, services.Audit),
+		// ✅ For per-agent and per-API key usage analytics
+		UsageAnalytics: handlers.NewUsageAnalyticsHandler(services.UsageAnalytics),
+		// ✅ For custom roles and the permission catalog
+		Role: handlers.NewRoleHandler(services.Role, services.Audit),
+		// ✅ For GDPR data exports and erasure requests
+		PersonalData: handlers.NewPersonalDataHandler(services.PersonalData, services.Audit),
+		// ✅ For invitations and their acceptance
+		Invitation: handlers.NewInvitationHandler(services.Invitation, services.Audit),
+		// ✅ For trust score triggers and their firings
+		TrustScoreTrigger: handlers.NewTrustScoreTriggerHandler(services.TrustScoreTrigger, services.Audit),
+		// ✅ For organizations' email senders, their email log, provider bounce webhooks and the suppression list
+		Email: handlers.NewEmailHandler(services.EmailQueue, services.Audit),
+		LoginProtection: handlers.NewLoginProtectionHandler(services.LoginProtection, services.Audit),
+		// ✅ For choosing the region the organization's records are stored in
+		DataResidency: handlers.NewDataResidencyHandler(services.DataResidency, services.Audit),
 	}
 }
 
@@ -784,11 +2606,29 @@ func initEmailService() (domain.EmailService, error) {
 	return service, nil
 }
 
-func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *auth.JWTService, sdkTokenRepo domain.SDKTokenRepository, db *sql.DB) {
+func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *auth.JWTService, sdkTokenRepo domain.SDKTokenRepository, db *sql.DB, timeouts domain.TimeoutBudgets, payloads domain.PayloadBudgets, clientCertHeader string) {
 	// SDK Token Tracking Middleware - TEMPORARILY DISABLED for debugging
 	// sdkTokenTrackingMiddleware := middleware.NewSDKTokenTrackingMiddleware(sdkTokenRepo)
 	// v1.Use(sdkTokenTrackingMiddleware.Handler()) // Apply to all API routes
 
+	// ✅ Agents may authenticate with the certificate the platform CA issued them (mTLS)
+	agentCertificate := middleware.AgentCertificateMiddleware(services.AgentCertificate, clientCertHeader)
+
+	// ✅ Per-organization API rate limits by endpoint class; they run after authentication
+	orgRateLimit := middleware.OrganizationRateLimitMiddleware(services.RateLimit, "")
+	verificationRateLimit := middleware.OrganizationRateLimitMiddleware(services.RateLimit, domain.EndpointClassVerification)
+	// ✅ Mutating requests sent with an Idempotency-Key replay their response to retries; they run after authentication
+	idempotency := middleware.IdempotencyMiddleware(services.Idempotency)
+	// ✅ Routes require permissions, which users hold through their built-in role or custom roles.
+	// Fiber runs a route's middleware arguments before its handler, so pass can(...) after the handler.
+	can := func(permission domain.Permission) fiber.Handler {
+		return middleware.RequirePermission(services.Role, permission)
+	}
+	// ✅ Verification endpoints get the verification class's (shorter) timeout budget
+	verificationTimeout := middleware.RequestTimeoutMiddleware(timeouts, domain.EndpointClassVerification)
+	// ✅ ... and the verification class's (smaller) request body limit
+	verificationBodyLimit := middleware.PayloadBudgetMiddleware(payloads, domain.EndpointClassVerification)
+
 	// ✅ Public routes (NO authentication required) - Self-registration API
 	public := v1.Group("/public")
 	public.Use(middleware.OptionalAuthMiddleware(jwtService))                               // Try to extract user from JWT if present
@@ -800,6 +2640,23 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	public.Post("/forgot-password", h.PublicRegistration.ForgotPassword)                    // 🚀 Password reset request
 	public.Post("/reset-password", h.PublicRegistration.ResetPassword)                      // 🚀 Password reset with token
 	public.Post("/request-access", h.PublicRegistration.RequestAccess)                      // 🚀 Request platform access (no password required)
+	// Invitation links: look up the invitation, then set a name and password to create the account
+	public.Post("/invitations/accept", middleware.StrictRateLimitMiddleware(), h.Invitation.AcceptInvitation)
+	public.Get("/invitations/:token", middleware.RateLimitMiddleware(), h.Invitation.GetInvitation)
+	public.Get("/reports/:token", h.Report.DownloadByToken)                                 // Report download link from notifications (expiring token)
+	// Verify an export file against the signature it was downloaded with
+	public.Post("/exports/verify", middleware.RateLimitMiddleware(), h.Export.VerifyExport)
+	// Agent CA: CA certificate, revocation list and OCSP-style certificate status for relying parties
+	public.Get("/agent-ca/certificate", h.AgentCertificate.GetCACertificate)
+	public.Get("/agent-ca/crl", h.AgentCertificate.GetCRL)
+	public.Get("/agent-ca/certificates/:serial/status", h.AgentCertificate.GetCertificateStatus)
+	// Check a verifiable credential an agent presented
+	public.Post("/credentials/verify", middleware.RateLimitMiddleware(), h.AgentCredential.VerifyCredential)
+	// Jira / ServiceNow report ticket changes, authenticated with the connector's webhook secret
+	public.Post("/ticketing/:connectorId/webhook", middleware.RateLimitMiddleware(), h.TicketConnector.ReceiveWebhook)
+	// Bounces and complaints reported by the email provider, authenticated with EMAIL_WEBHOOK_SECRET
+	public.Post("/email/events/sendgrid", middleware.RateLimitMiddleware(), h.Email.ReceiveSendGridEvents)
+	public.Post("/email/events/ses", middleware.RateLimitMiddleware(), h.Email.ReceiveSESEvents)
 
 	// Auth routes (no authentication required)
 	auth := v1.Group("/auth")
@@ -808,16 +2665,37 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	auth.Post("/refresh", h.AuthRefresh.RefreshToken)                 // Refresh access token (with token rotation)
 	auth.Post("/sdk/recover", h.SDKTokenRecovery.RecoverRevokedToken) // Recover revoked SDK tokens (zero downtime!)
 
+	// Break-glass for deployments whose SDK token refresh chain is broken (sealed emergency credentials)
+	auth.Post("/emergency-access", middleware.StrictRateLimitMiddleware(), h.Emergency.Activate)
+
+	// Second factor for password logins that need MFA (exchange the mfa_token from the login response)
+	auth.Post("/login/mfa", middleware.StrictRateLimitMiddleware(), h.MFA.CompleteLogin)
+	auth.Post("/login/mfa/enroll", middleware.StrictRateLimitMiddleware(), h.MFA.BeginLoginEnrollment)
+
+	// SAML 2.0 SSO per organization (the IdP posts signed assertions to the ACS URL)
+	auth.Get("/saml/:orgId/metadata", h.SAML.Metadata)
+	auth.Get("/saml/:orgId/login", h.SAML.Login)
+	auth.Post("/saml/:orgId/acs", h.SAML.ACS)
+
 	// Authenticated auth routes (authentication required)
 	authProtected := v1.Group("/auth")
 	authProtected.Use(middleware.AuthMiddleware(jwtService)) // Apply middleware using Use() instead of inline
 	authProtected.Get("/me", h.Auth.Me)
+	authProtected.Get("/me/permissions", h.Role.GetMyPermissions) // Permissions of the built-in role and custom roles
 	authProtected.Post("/change-password", h.Auth.ChangePassword)
+	authProtected.Get("/mfa", h.MFA.GetStatus)
+	authProtected.Post("/mfa/enroll", h.MFA.BeginEnrollment)
+	authProtected.Post("/mfa/confirm", middleware.StrictRateLimitMiddleware(), h.MFA.ConfirmEnrollment)
+	authProtected.Post("/mfa/disable", middleware.StrictRateLimitMiddleware(), h.MFA.Disable)
+	authProtected.Post("/mfa/recovery-codes", middleware.StrictRateLimitMiddleware(), h.MFA.RegenerateRecoveryCodes)
 
 	// Organization routes (authentication required)
 	organizations := v1.Group("/organizations")
 	organizations.Use(middleware.AuthMiddleware(jwtService))
 	organizations.Get("/current", h.Auth.GetCurrentOrganization)
+	organizations.Get("/hierarchy", h.OrganizationHierarchy.GetHierarchy)
+	organizations.Get("/hierarchy/security-metrics", h.OrganizationHierarchy.GetSecurityRollup, can(domain.PermissionOrganizationManage))
+	organizations.Get("/:id/agents", h.OrganizationHierarchy.ListOrganizationAgents, can(domain.PermissionOrganizationManage)) // Own or a sub-organization's
 
 	// SDK routes (authentication required) - Download pre-configured SDK
 	sdk := v1.Group("/sdk")
@@ -832,15 +2710,39 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	sdkTokens.Post("/:id/revoke", h.SDKToken.RevokeToken)     // Revoke specific token
 	sdkTokens.Post("/revoke-all", h.SDKToken.RevokeAllTokens) // Revoke all tokens
 
+	// Personal access tokens for scripting against the API (scoped, expiring)
+	personalTokens := v1.Group("/users/me/tokens")
+	personalTokens.Use(middleware.AuthMiddleware(jwtService))
+	personalTokens.Get("/", h.PersonalAccessToken.ListTokens)
+	personalTokens.Get("/scopes", h.PersonalAccessToken.ListScopes)
+	personalTokens.Post("/", h.PersonalAccessToken.CreateToken)
+	personalTokens.Post("/:id/revoke", h.PersonalAccessToken.RevokeToken)
+
+	// Personal data (GDPR): download everything held about you, or ask for it to be erased
+	personalData := v1.Group("/users/me/data")
+	personalData.Use(middleware.AuthMiddleware(jwtService))
+	personalData.Get("/export", h.PersonalData.ExportMyData)
+	personalData.Post("/erasure", h.PersonalData.RequestMyErasure) // An admin other than you approves it
+
+	// SDK device routes (authentication required) - devices holding the user's active SDK tokens
+	sdkDevices := v1.Group("/users/me/sdk-devices")
+	sdkDevices.Use(middleware.AuthMiddleware(jwtService))
+	sdkDevices.Get("/", h.SDKToken.ListDevices)                // List devices with last-used address and location
+	sdkDevices.Post("/:id/revoke", h.SDKToken.RevokeDevice)    // Revoke every token on a device
+	sdkDevices.Post("/revoke-all", h.SDKToken.RevokeAllTokens) // Revoke all devices
+
 	// Note: SDK API routes moved to app level (main.go line 159) to avoid middleware inheritance
 
 	// ⭐ MCP Detection endpoints - Using DIFFERENT path to avoid agents group conflict
 	// Path: /api/v1/detection/agents/:id/report (instead of /api/v1/agents/:id/detection/report)
 	// ✅ FIX: Use JWT authentication for web UI access, API key for SDK programmatic access
 	detection := v1.Group("/detection")
+	detection.Use(agentCertificate)                                  // ✅ Agent certificates (mTLS)
 	detection.Use(middleware.Ed25519AgentMiddleware(services.Agent)) // ✅ Try Ed25519 first (for SDK agents)
 	detection.Use(middleware.AuthMiddleware(jwtService))             // ✅ Fallback to JWT (for web UI)
 	detection.Use(middleware.RateLimitMiddleware())
+	detection.Use(orgRateLimit)
+	detection.Use(idempotency)
 	detection.Post("/agents/:id/report", h.Detection.ReportDetection)
 	detection.Get("/agents/:id/status", h.Detection.GetDetectionStatus) // ✅ Now accessible from web UI with JWT
 	// ⭐ Agent Capability Detection endpoints - Report detected agent capabilities
@@ -849,121 +2751,321 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 
 	// Agents routes - All other agent endpoints with dual authentication (Ed25519 or JWT)
 	agents := v1.Group("/agents")
+	agents.Use(middleware.OptionalAPIKeyMiddleware(services.APIKey))           // ✅ Scoped API keys (X-API-Key or "Bearer aim_...")
+	agents.Use(agentCertificate)                                  // ✅ Agent certificates (mTLS)
 	agents.Use(middleware.Ed25519AgentMiddleware(services.Agent)) // ✅ Try Ed25519 first (for SDK agents)
 	agents.Use(middleware.AuthMiddleware(jwtService))             // ✅ Fallback to JWT (for web UI)
 	agents.Use(middleware.RateLimitMiddleware())
+	agents.Use(orgRateLimit)
+	agents.Use(idempotency)
+	// ✅ API keys only read agents (agents:read); the other agent routes reject them
+	agents.Use(middleware.RequireAPIKeyRoute())
 	agents.Get("/", h.Agent.ListAgents)
-	agents.Post("/", middleware.MemberMiddleware(), h.Agent.CreateAgent)
+	agents.Post("/", h.Agent.CreateAgent, can(domain.PermissionAgentsCreate))
+	agents.Post("/batch", h.Agent.GetAgentsBatch)                                   // Look up many agents in one query
+	agents.Get("/mcp-servers/suggestions", h.TalksToRecommendation.ListSuggestions) // talks_to suggestions of every agent
+
+	// Agent-to-agent (A2A) peer policies
+	agents.Get("/peer-policies", h.AgentPeer.ListPolicies)
+	agents.Post("/peer-policies", h.AgentPeer.CreatePolicy, can(domain.PermissionPeerPoliciesManage))
+	agents.Put("/peer-policies/:id", h.AgentPeer.UpdatePolicy, can(domain.PermissionPeerPoliciesManage))
+	agents.Delete("/peer-policies/:id", h.AgentPeer.DeletePolicy, can(domain.PermissionPeerPoliciesManage))
+
+	// Agent identity portability between deployments (self-hosted, cloud)
+	agents.Get("/identity-bundles/issuer", h.AgentPortability.GetIssuer)
+	agents.Post("/export", h.AgentPortability.ExportIdentities, can(domain.PermissionAgentsImportExport))
+	agents.Post("/import", h.AgentPortability.ImportIdentities, can(domain.PermissionAgentsImportExport))
+
 	agents.Get("/:id", h.Agent.GetAgent)
-	agents.Put("/:id", middleware.MemberMiddleware(), h.Agent.UpdateAgent)
-	agents.Delete("/:id", middleware.ManagerMiddleware(), h.Agent.DeleteAgent)
-	agents.Post("/:id/verify", middleware.ManagerMiddleware(), h.Agent.VerifyAgent)
+	agents.Put("/:id", h.Agent.UpdateAgent, can(domain.PermissionAgentsUpdate))
+	agents.Delete("/:id", h.Agent.DeleteAgent, can(domain.PermissionAgentsDelete))
+	agents.Post("/:id/verify", h.Agent.VerifyAgent, can(domain.PermissionAgentsLifecycle))
 	// Agent lifecycle management endpoints
-	agents.Post("/:id/suspend", middleware.ManagerMiddleware(), h.Agent.SuspendAgent)
-	agents.Post("/:id/reactivate", middleware.ManagerMiddleware(), h.Agent.ReactivateAgent)
-	agents.Post("/:id/rotate-credentials", middleware.MemberMiddleware(), h.Agent.RotateCredentials)
-	agents.Put("/:id/keys", middleware.MemberMiddleware(), h.Agent.UpdateAgentKeys) // SDK key registration
+	agents.Post("/:id/suspend", h.Agent.SuspendAgent, can(domain.PermissionAgentsLifecycle))
+	agents.Post("/:id/reactivate", h.Agent.ReactivateAgent, can(domain.PermissionAgentsLifecycle))
+	agents.Post("/:id/compromise", h.Compromise.MarkCompromised, can(domain.PermissionAgentsLifecycle)) // Mark compromised + run response bundle
+	agents.Post("/:id/revoke", h.AgentDelegation.RevokeAgent, can(domain.PermissionAgentsLifecycle))    // Revoke permanently, cascading to delegated agents
+	agents.Get("/:id/compromise-responses", h.Compromise.ListResponses)
+	agents.Post("/:id/rotate-credentials", h.Agent.RotateCredentials, can(domain.PermissionAgentsUpdate))
+	// Key rotation; signatures from the previous key verify until the grace period ends
+	agents.Post("/:id/rotate-key", h.Agent.RotateKey, can(domain.PermissionAgentsUpdate))
+	agents.Put("/:id/keys", h.Agent.UpdateAgentKeys, can(domain.PermissionAgentsUpdate)) // SDK key registration
 	// Runtime verification endpoints - CORE functionality
-	agents.Post("/:id/verify-action", h.Agent.VerifyAction)
+	agents.Post("/:id/verify-action", h.Agent.VerifyAction, verificationTimeout, verificationBodyLimit) // Fiber v3 runs the middleware after the handler argument first
 	agents.Post("/:id/log-action/:audit_id", h.Agent.LogActionResult)
 	// SDK download endpoint - Download Python/Node.js/Go SDK with embedded credentials
 	agents.Get("/:id/sdk", h.Agent.DownloadSDK)
 	// Credentials endpoint - Get raw Ed25519 public/private keys for manual integration
 	agents.Get("/:id/credentials", h.Agent.GetCredentials)
 	// MCP Server relationship management - "talks_to" endpoints
-	agents.Get("/:id/mcp-servers", h.MCPAttestation.GetAgentMCPServers)                                        // ✅ Get MCP servers agent is connected to (via attestation)
-	agents.Put("/:id/mcp-servers", middleware.MemberMiddleware(), h.Agent.AddMCPServersToAgent)                // Add MCP servers (bulk)
-	agents.Delete("/:id/mcp-servers/:mcp_id", middleware.MemberMiddleware(), h.Agent.RemoveMCPServerFromAgent) // Remove single MCP
-	agents.Post("/:id/mcp-servers/detect", middleware.MemberMiddleware(), h.Agent.DetectAndMapMCPServers)      // Auto-detect MCPs from config
+	agents.Get("/:id/mcp-servers", h.MCPAttestation.GetAgentMCPServers)                                             // ✅ Get MCP servers agent is connected to (via attestation)
+	agents.Put("/:id/mcp-servers", h.Agent.AddMCPServersToAgent, can(domain.PermissionAgentsUpdate))                // Add MCP servers (bulk)
+	agents.Delete("/:id/mcp-servers/:mcp_id", h.Agent.RemoveMCPServerFromAgent, can(domain.PermissionAgentsUpdate)) // Remove single MCP
+	agents.Post("/:id/mcp-servers/detect", h.Agent.DetectAndMapMCPServers, can(domain.PermissionAgentsUpdate))      // Auto-detect MCPs from config
+	agents.Get("/:id/mcp-servers/suggestions", h.TalksToRecommendation.GetAgentSuggestions)                         // talks_to suggestions from actual usage
+	// Per-tool grants narrowing a talks_to MCP server to individual tools
+	agents.Get("/:id/mcp-tool-grants", h.MCPToolGrant.ListGrants)
+	agents.Put("/:id/mcp-servers/:mcp_id/tools", h.MCPToolGrant.SetGrant, can(domain.PermissionAgentsUpdate))
+	agents.Delete("/:id/mcp-servers/:mcp_id/tools", h.MCPToolGrant.DeleteGrant, can(domain.PermissionAgentsUpdate))
+
+	agents.Get("/:id/peers", h.AgentPeer.GetAgentPeers)
+	agents.Post("/:id/peers/verify", h.AgentPeer.VerifyPeerCall) // Authorize a call to another agent (A2A)
+	agents.Get("/:id/lineage", h.AgentPortability.GetLineage)    // Deployments the agent was imported from
+	// Sub-agents spawned by the agent with a subset of its capabilities
+	agents.Get("/:id/delegations", h.AgentDelegation.GetDelegations)
+	agents.Post("/:id/delegations", h.AgentDelegation.DelegateAgent)
 	// Trust Score management - RESTful endpoints under /agents/:id/trust-score/*
-	agents.Get("/:id/trust-score", h.Agent.GetAgentTrustScore)                                                      // Get current trust score
-	agents.Get("/:id/trust-score/history", h.Agent.GetAgentTrustScoreHistory)                                       // Get trust score history
-	agents.Put("/:id/trust-score", middleware.AdminMiddleware(), h.Agent.UpdateAgentTrustScore)                     // Manually update score (admin)
-	agents.Post("/:id/trust-score/recalculate", middleware.ManagerMiddleware(), h.Agent.RecalculateAgentTrustScore) // Recalculate score
+	agents.Get("/:id/trust-score", h.Agent.GetAgentTrustScore) // Get current trust score
+	agents.Get("/:id/trust-score/history", h.Agent.GetAgentTrustScoreHistory)
+	agents.Get("/:id/drift/trends", h.DriftAnalytics.GetAgentDriftTrends)                                                  // Get trust score history
+	agents.Put("/:id/trust-score", h.Agent.UpdateAgentTrustScore, can(domain.PermissionTrustScoresOverride))               // Manually update score (admin)
+	agents.Post("/:id/trust-score/recalculate", h.Agent.RecalculateAgentTrustScore, can(domain.PermissionAgentsLifecycle)) // Recalculate score
 	// Agent security endpoints - Key vault and audit logs per agent
 	agents.Get("/:id/key-vault", h.Agent.GetAgentKeyVault)   // Get agent's key vault info (public key, expiration, rotation status)
 	agents.Get("/:id/audit-logs", h.Agent.GetAgentAuditLogs) // Get audit logs for specific agent (with pagination)
+	// Verifications, attestations, drift, trust changes, key rotations, capability changes and alerts in one feed
+	agents.Get("/:id/timeline", h.AgentTimeline.GetAgentTimeline)
+	agents.Get("/:id/usage", h.UsageAnalytics.GetAgentUsage) // Requests per day, outcomes, error rate and MCP servers contacted
+	// Short-lived X.509 certificate of a verified agent
+	agents.Get("/:id/certificate", h.AgentCertificate.GetAgentCertificate)
+	// W3C verifiable credential of a verified agent, checked by third parties at /api/v1/public/credentials/verify
+	agents.Post("/:id/credentials", h.AgentCredential.IssueCredential, can(domain.PermissionAgentsUpdate))
+	// Public listing - listed agents can be looked up at /public/v1/verify
+	agents.Get("/:id/public-listing", h.AgentListing.GetAgentListing)
+	agents.Put("/:id/public-listing", h.AgentListing.ListAgent, can(domain.PermissionAgentsLifecycle))
+	agents.Delete("/:id/public-listing", h.AgentListing.UnlistAgent, can(domain.PermissionAgentsLifecycle))
+	// Agent SBOMs - dependency attestation, scanned against OSV for known vulnerabilities
+	agents.Get("/:id/sboms", h.SBOM.ListSBOMs)
+	agents.Post("/:id/sboms", h.SBOM.UploadSBOM, can(domain.PermissionAgentsUpdate))
+	agents.Get("/:id/sboms/:sbom_id", h.SBOM.GetSBOM)
+	agents.Post("/:id/sboms/:sbom_id/rescan", h.SBOM.RescanSBOM, can(domain.PermissionAgentsUpdate))
 
 	// API keys routes (authentication required)
 	apiKeys := v1.Group("/api-keys")
 	apiKeys.Use(middleware.AuthMiddleware(jwtService))
 	apiKeys.Use(middleware.RateLimitMiddleware())
+	apiKeys.Use(orgRateLimit)
+	apiKeys.Use(idempotency)
 	apiKeys.Get("/", h.APIKey.ListAPIKeys)
-	apiKeys.Post("/", middleware.MemberMiddleware(), h.APIKey.CreateAPIKey)
-	apiKeys.Patch("/:id/disable", middleware.MemberMiddleware(), h.APIKey.DisableAPIKey)
-	apiKeys.Delete("/:id", middleware.MemberMiddleware(), h.APIKey.DeleteAPIKey)
+	apiKeys.Get("/:id/usage", h.UsageAnalytics.GetAPIKeyUsage)
+	apiKeys.Post("/", h.APIKey.CreateAPIKey, can(domain.PermissionAPIKeysManage))
+	apiKeys.Patch("/:id/disable", h.APIKey.DisableAPIKey, can(domain.PermissionAPIKeysManage))
+	apiKeys.Delete("/:id", h.APIKey.DeleteAPIKey, can(domain.PermissionAPIKeysManage))
 
 	// Trust score routes (authentication required)
 	trust := v1.Group("/trust-score")
 	trust.Use(middleware.AuthMiddleware(jwtService))
-	trust.Post("/calculate/:id", middleware.ManagerMiddleware(), h.TrustScore.CalculateTrustScore)
+	trust.Post("/calculate/:id", h.TrustScore.CalculateTrustScore, can(domain.PermissionAgentsLifecycle))
 	trust.Get("/agents/:id", h.TrustScore.GetTrustScore)
 	trust.Get("/agents/:id/breakdown", h.TrustScore.GetTrustScoreBreakdown) // Detailed breakdown with weights and contributions
 	trust.Get("/agents/:id/history", h.TrustScore.GetTrustScoreHistory)
 
-	// Admin routes (admin only)
+	// Admin routes (each requires its permission; admins hold them all)
 	admin := v1.Group("/admin")
 	admin.Use(middleware.AuthMiddleware(jwtService))
-	admin.Use(middleware.AdminMiddleware())
 	admin.Use(middleware.RateLimitMiddleware())
+	admin.Use(orgRateLimit)
+	admin.Use(idempotency)
 
 	// User management
-	admin.Get("/users", h.Admin.ListUsers)
-	admin.Get("/users/pending", h.Admin.GetPendingUsers)
-	admin.Post("/users/:id/approve", h.Admin.ApproveUser)
-	admin.Post("/users/:id/reject", h.Admin.RejectUser)
-	admin.Put("/users/:id/role", h.Admin.UpdateUserRole)
+	admin.Get("/users", h.Admin.ListUsers, can(domain.PermissionUsersManage))
+	admin.Get("/users/pending", h.Admin.GetPendingUsers, can(domain.PermissionUsersManage))
+	admin.Post("/users/batch", h.Admin.GetUsersBatch, can(domain.PermissionUsersManage)) // Look up many users in one query
+	admin.Post("/users/:id/approve", h.Admin.ApproveUser, can(domain.PermissionUsersManage))
+	admin.Post("/users/:id/reject", h.Admin.RejectUser, can(domain.PermissionUsersManage))
+	admin.Put("/users/:id/role", h.Admin.UpdateUserRole, can(domain.PermissionUsersManage))
 
 	// User lifecycle management (soft delete and hard delete)
-	admin.Post("/users/:id/deactivate", h.Admin.DeactivateUser) // Soft delete - sets deleted_at
-	admin.Post("/users/:id/activate", h.Admin.ActivateUser)     // Reactivate - clears deleted_at
-	admin.Delete("/users/:id", h.Admin.PermanentlyDeleteUser)   // Hard delete - removes from database
+	admin.Post("/users/:id/deactivate", h.Admin.DeactivateUser, can(domain.PermissionUsersManage)) // Soft delete - sets deleted_at
+	admin.Post("/users/:id/activate", h.Admin.ActivateUser, can(domain.PermissionUsersManage))     // Reactivate - clears deleted_at
+	admin.Delete("/users/:id", h.Admin.PermanentlyDeleteUser, can(domain.PermissionUsersManage))   // Hard delete - removes from database
+	admin.Post("/users/:id/unlock", h.LoginProtection.UnlockUser, can(domain.PermissionUsersManage)) // Lift a lockout after too many failed logins
+	admin.Get("/login-attempts", h.LoginProtection.ListAttempts, can(domain.PermissionUsersManage)) // ?email= and ?outcome= narrow
+	admin.Post("/users/:id/mfa/reset", h.MFA.ResetUserMFA, can(domain.PermissionUsersManage))      // Clear MFA for a user who lost their authenticator
+
+	// Invitations: signed links that expire; accepting one creates the user (pending when inviteesRequireApproval is on)
+	admin.Post("/invitations", h.Invitation.CreateInvitation, can(domain.PermissionUsersManage))
+	admin.Get("/invitations", h.Invitation.ListInvitations, can(domain.PermissionUsersManage)) // Pending unless ?status= says otherwise
+	admin.Post("/invitations/:id/revoke", h.Invitation.RevokeInvitation, can(domain.PermissionUsersManage))
+
+	// Personal data (GDPR): exports, and erasure requests another admin approves before the user is anonymized
+	admin.Get("/users/:id/data/export", h.PersonalData.ExportUserData, can(domain.PermissionPrivacyManage))
+	admin.Post("/users/:id/data/erasure", h.PersonalData.RequestUserErasure, can(domain.PermissionPrivacyManage))
+	admin.Get("/data-erasure-requests", h.PersonalData.ListErasureRequests, can(domain.PermissionPrivacyManage))
+	admin.Get("/data-erasure-requests/:id", h.PersonalData.GetErasureRequest, can(domain.PermissionPrivacyManage))
+	admin.Post("/data-erasure-requests/:id/approve", h.PersonalData.ApproveErasureRequest, can(domain.PermissionPrivacyManage)) // Erases the data; the response carries the completion report
+	admin.Post("/data-erasure-requests/:id/reject", h.PersonalData.RejectErasureRequest, can(domain.PermissionPrivacyManage))
+
+	// Custom roles: sets of permissions users hold on top of their built-in role
+	admin.Get("/permissions", h.Role.ListPermissions, can(domain.PermissionRolesManage)) // Permission catalog with built-in role defaults
+	admin.Get("/roles", h.Role.ListRoles, can(domain.PermissionRolesManage))
+	admin.Post("/roles", h.Role.CreateRole, can(domain.PermissionRolesManage))
+	admin.Get("/roles/:id", h.Role.GetRole, can(domain.PermissionRolesManage))
+	admin.Put("/roles/:id", h.Role.UpdateRole, can(domain.PermissionRolesManage))
+	admin.Delete("/roles/:id", h.Role.DeleteRole, can(domain.PermissionRolesManage))
+	admin.Get("/roles/:id/users", h.Role.ListRoleUsers, can(domain.PermissionRolesManage))
+	admin.Post("/roles/:id/users", h.Role.AssignRole, can(domain.PermissionRolesManage))
+	admin.Delete("/roles/:id/users/:userId", h.Role.UnassignRole, can(domain.PermissionRolesManage))
+	admin.Get("/users/:id/roles", h.Role.ListUserRoles, can(domain.PermissionRolesManage))
 
 	// Registration request management (for pending OAuth registrations)
-	admin.Post("/registration-requests/:id/approve", h.Admin.ApproveRegistrationRequest)
-	admin.Post("/registration-requests/:id/reject", h.Admin.RejectRegistrationRequest)
+	admin.Post("/registration-requests/:id/approve", h.Admin.ApproveRegistrationRequest, can(domain.PermissionUsersManage))
+	admin.Post("/registration-requests/:id/reject", h.Admin.RejectRegistrationRequest, can(domain.PermissionUsersManage))
+
+	// Organization settings (no SSO auto-approve in Community)
+	admin.Get("/organization/settings", h.Admin.GetOrganizationSettings, can(domain.PermissionOrganizationManage))
+	admin.Put("/organization/settings", h.Admin.UpdateOrganizationSettings, can(domain.PermissionOrganizationManage))
+	admin.Get("/organization/key-algorithms", h.Admin.GetKeyAlgorithmUsage, can(domain.PermissionOrganizationManage)) // Agents still on algorithms the organization no longer allows
+	admin.Post("/organization/sub-organizations", h.OrganizationHierarchy.CreateSubOrganization, can(domain.PermissionOrganizationManage))
 
-	// Organization settings (read-only - no SSO auto-approve in Community)
-	admin.Get("/organization/settings", h.Admin.GetOrganizationSettings)
+	// Preview features for the organization; turning one off overrides users' opt-ins
+	admin.Put("/features/:key", h.FeatureFlag.SetOrganizationFeature, can(domain.PermissionOrganizationManage))
+	admin.Delete("/features/:key", h.FeatureFlag.ClearOrganizationFeature, can(domain.PermissionOrganizationManage))
 
 	// Audit logs
-	admin.Get("/audit-logs", h.Admin.GetAuditLogs)
+	admin.Get("/audit-logs", h.Admin.GetAuditLogs, can(domain.PermissionAuditLogsRead))
+
+	// Personal access tokens of every user, and revoking them
+	admin.Get("/personal-access-tokens", h.PersonalAccessToken.ListOrganizationTokens, can(domain.PermissionUsersManage))
+	admin.Post("/personal-access-tokens/:id/revoke", h.PersonalAccessToken.AdminRevokeToken, can(domain.PermissionUsersManage))
+
+	// Platform operators' requests to act in the organization, and consent to them
+	admin.Get("/impersonation-requests", h.Impersonation.ListRequests, can(domain.PermissionUsersManage))
+	admin.Post("/impersonation-requests/:id/approve", h.Impersonation.ApproveRequest, can(domain.PermissionUsersManage))
+	admin.Post("/impersonation-requests/:id/deny", h.Impersonation.DenyRequest, can(domain.PermissionUsersManage))
+	admin.Post("/impersonation-requests/:id/revoke", h.Impersonation.RevokeRequest, can(domain.PermissionUsersManage))
+
+	// Circuit breakers of external dependencies, by endpoint
+	admin.Get("/dependencies", h.DependencyHealth.GetDependencies, can(domain.PermissionSystemManage))
 
 	// Alerts
-	admin.Get("/alerts", h.Admin.GetAlerts)
-	admin.Get("/alerts/unacknowledged/count", h.Admin.GetUnacknowledgedAlertCount)
-	admin.Post("/alerts/bulk-acknowledge", h.Admin.BulkAcknowledgeAlerts)
-	admin.Post("/alerts/:id/acknowledge", h.Admin.AcknowledgeAlert)
-	admin.Post("/alerts/:id/resolve", h.Admin.ResolveAlert)
+	admin.Get("/alerts", h.Admin.GetAlerts, can(domain.PermissionAlertsManage))
+	admin.Get("/alerts/unacknowledged/count", h.Admin.GetUnacknowledgedAlertCount, can(domain.PermissionAlertsManage))
+	admin.Post("/alerts/bulk-acknowledge", h.Admin.BulkAcknowledgeAlerts, can(domain.PermissionAlertsManage))
+	admin.Post("/alerts/:id/acknowledge", h.Admin.AcknowledgeAlert, can(domain.PermissionAlertsManage))
+	admin.Post("/alerts/:id/resolve", h.Admin.ResolveAlert, can(domain.PermissionAlertsManage))
+	admin.Get("/alerts/:id/remediation", h.DriftRemediation.GetAlertRemediation, can(domain.PermissionAlertsManage))
+
+	// Drift remediations - change sets proposed with configuration drift alerts
+	admin.Get("/drift-remediations", h.DriftRemediation.ListRemediations, can(domain.PermissionAlertsManage))
+	admin.Get("/drift-remediations/:id", h.DriftRemediation.GetRemediation, can(domain.PermissionAlertsManage))
+	admin.Post("/drift-remediations/:id/approve", h.DriftRemediation.ApproveRemediation, can(domain.PermissionAlertsManage))
+	admin.Post("/drift-remediations/:id/reject", h.DriftRemediation.RejectRemediation, can(domain.PermissionAlertsManage))
+
+	// Alert suppression rules - drop or downgrade known-noisy alerts before notification
+	admin.Get("/alert-suppression-rules", h.AlertSuppression.ListRules, can(domain.PermissionAlertsManage))
+	admin.Post("/alert-suppression-rules", h.AlertSuppression.CreateRule, can(domain.PermissionAlertsManage))
+	admin.Get("/alert-suppression-rules/:id", h.AlertSuppression.GetRule, can(domain.PermissionAlertsManage))
+	admin.Put("/alert-suppression-rules/:id", h.AlertSuppression.UpdateRule, can(domain.PermissionAlertsManage))
+	admin.Delete("/alert-suppression-rules/:id", h.AlertSuppression.DeleteRule, can(domain.PermissionAlertsManage))
+	admin.Get("/alert-suppression-rules/:id/hits", h.AlertSuppression.GetRuleHits, can(domain.PermissionAlertsManage))
+
+	// Emergency access (sealed break-glass credentials for critical agents)
+	admin.Get("/agents/:id/emergency-credentials", h.Emergency.ListCredentials, can(domain.PermissionEmergencyAccessManage))
+	admin.Post("/agents/:id/emergency-credentials", h.Emergency.GenerateCredential, can(domain.PermissionEmergencyAccessManage))
+	admin.Delete("/emergency-credentials/:id", h.Emergency.RevokeCredential, can(domain.PermissionEmergencyAccessManage))
 
 	// Dashboard stats
-	admin.Get("/dashboard/stats", h.Admin.GetDashboardStats)
+	admin.Get("/dashboard/stats", h.Admin.GetDashboardStats, can(domain.PermissionSystemManage))
+
+	// Database schema version, pending migrations and history
+	admin.Get("/schema", h.Schema.GetSchemaStatus, can(domain.PermissionSystemManage))
 
 	// Security Policy Management routes (admin only)
-	admin.Get("/security-policies", h.SecurityPolicy.ListPolicies)
-	admin.Get("/security-policies/:id", h.SecurityPolicy.GetPolicy)
-	admin.Post("/security-policies", h.SecurityPolicy.CreatePolicy)
-	admin.Put("/security-policies/:id", h.SecurityPolicy.UpdatePolicy)
-	admin.Delete("/security-policies/:id", h.SecurityPolicy.DeletePolicy)
-	admin.Patch("/security-policies/:id/toggle", h.SecurityPolicy.TogglePolicy)
+	admin.Get("/security-policies", h.SecurityPolicy.ListPolicies, can(domain.PermissionSecurityPoliciesManage))
+	admin.Get("/security-policies/:id", h.SecurityPolicy.GetPolicy, can(domain.PermissionSecurityPoliciesManage))
+	admin.Post("/security-policies", h.SecurityPolicy.CreatePolicy, can(domain.PermissionSecurityPoliciesManage))
+	admin.Put("/security-policies/:id", h.SecurityPolicy.UpdatePolicy, can(domain.PermissionSecurityPoliciesManage))
+	admin.Delete("/security-policies/:id", h.SecurityPolicy.DeletePolicy, can(domain.PermissionSecurityPoliciesManage))
+	admin.Patch("/security-policies/:id/toggle", h.SecurityPolicy.TogglePolicy, can(domain.PermissionSecurityPoliciesManage))
+
+	// External policy decision point (OPA) - delegate verify-action / A2A decisions
+	admin.Get("/policy-decision-point", h.PolicyDecision.GetPDP, can(domain.PermissionSecurityPoliciesManage))
+	admin.Put("/policy-decision-point", h.PolicyDecision.ConfigurePDP, can(domain.PermissionSecurityPoliciesManage))
+	admin.Delete("/policy-decision-point", h.PolicyDecision.DeletePDP, can(domain.PermissionSecurityPoliciesManage))
+	admin.Post("/policy-decision-point/test", h.PolicyDecision.TestPDP, can(domain.PermissionSecurityPoliciesManage))
+	admin.Get("/policy-decisions", h.PolicyDecision.ListDecisions, can(domain.PermissionSecurityPoliciesManage)) // Native vs external decision log
+
+	// Compromised-agent response bundle
+	admin.Get("/compromise-response-policy", h.Compromise.GetPolicy, can(domain.PermissionSecurityPoliciesManage))
+	admin.Put("/compromise-response-policy", h.Compromise.UpdatePolicy, can(domain.PermissionSecurityPoliciesManage))
+
+	// Automatic actions at trust score boundaries (floor and ceiling)
+	admin.Get("/trust-boundary-policy", h.TrustBoundary.GetPolicy, can(domain.PermissionSecurityPoliciesManage))
+	admin.Put("/trust-boundary-policy", h.TrustBoundary.UpdatePolicy, can(domain.PermissionSecurityPoliciesManage))
+	admin.Post("/trust-boundary-policy/evaluate", h.TrustBoundary.EvaluateNow, can(domain.PermissionSecurityPoliciesManage))
+
+	// Trust score triggers: actions run when a score crosses a threshold, e.g. "below 0.5: suspend and alert"
+	admin.Get("/trust-score-triggers", h.TrustScoreTrigger.ListTriggers, can(domain.PermissionSecurityPoliciesManage))
+	admin.Post("/trust-score-triggers", h.TrustScoreTrigger.CreateTrigger, can(domain.PermissionSecurityPoliciesManage))
+	admin.Get("/trust-score-triggers/firings", h.TrustScoreTrigger.ListFirings, can(domain.PermissionSecurityPoliciesManage))
+	admin.Get("/trust-score-triggers/:id", h.TrustScoreTrigger.GetTrigger, can(domain.PermissionSecurityPoliciesManage))
+	admin.Put("/trust-score-triggers/:id", h.TrustScoreTrigger.UpdateTrigger, can(domain.PermissionSecurityPoliciesManage))
+	admin.Delete("/trust-score-triggers/:id", h.TrustScoreTrigger.DeleteTrigger, can(domain.PermissionSecurityPoliciesManage))
+
+	// Email: the organization's own sender and the log of its emails
+	admin.Get("/organization/email-sender", h.Email.GetSender, can(domain.PermissionOrganizationManage))
+	admin.Put("/organization/email-sender", h.Email.SetSender, can(domain.PermissionOrganizationManage))
+	admin.Get("/organization/residency", h.DataResidency.GetResidency, can(domain.PermissionOrganizationManage))
+	admin.Put("/organization/residency", h.DataResidency.SetResidency, can(domain.PermissionOrganizationManage)) // Only before the first agent, verification event or MCP attestation
+	admin.Delete("/organization/email-sender", h.Email.DeleteSender, can(domain.PermissionOrganizationManage))
+	admin.Get("/emails", h.Email.ListMessages, can(domain.PermissionOrganizationManage)) // ?status= narrows
+
+	// Naming policies of agents and MCP servers
+	admin.Get("/naming-policies", h.NamingPolicy.ListPolicies, can(domain.PermissionSecurityPoliciesManage))
+	admin.Post("/naming-policies", h.NamingPolicy.CreatePolicy, can(domain.PermissionSecurityPoliciesManage))
+	admin.Get("/naming-policies/:id", h.NamingPolicy.GetPolicy, can(domain.PermissionSecurityPoliciesManage))
+	admin.Put("/naming-policies/:id", h.NamingPolicy.UpdatePolicy, can(domain.PermissionSecurityPoliciesManage))
+	admin.Delete("/naming-policies/:id", h.NamingPolicy.DeletePolicy, can(domain.PermissionSecurityPoliciesManage))
+
+	// Attestation cadence of each MCP server criticality
+	admin.Get("/attestation-cadence-policies", h.AttestationCadence.ListCadencePolicies, can(domain.PermissionSecurityPoliciesManage))
+	admin.Put("/attestation-cadence-policies/:criticality", h.AttestationCadence.SetCadencePolicy, can(domain.PermissionSecurityPoliciesManage))
+	admin.Delete("/attestation-cadence-policies/:criticality", h.AttestationCadence.ResetCadencePolicy, can(domain.PermissionSecurityPoliciesManage))
+
+	// SAML 2.0 identity provider, JIT provisioning and role mapping
+	admin.Get("/saml", h.SAML.GetConfig, can(domain.PermissionOrganizationManage))
+	admin.Put("/saml", h.SAML.UpdateConfig, can(domain.PermissionOrganizationManage))
+	admin.Delete("/saml", h.SAML.DeleteConfig, can(domain.PermissionOrganizationManage))
+
+	// Records of deleted agents, MCP servers and API keys
+	admin.Get("/tombstones", h.Tombstone.SearchTombstones, can(domain.PermissionAuditLogsRead))
+
+	// Demo data: seed a realistic sample dataset and tear it down again
+	admin.Get("/demo-data", h.DemoData.GetDemoData, can(domain.PermissionSystemManage))
+	admin.Post("/demo-data", h.DemoData.SeedDemoData, can(domain.PermissionSystemManage))
+	admin.Delete("/demo-data", h.DemoData.TeardownDemoData, can(domain.PermissionSystemManage))
+	admin.Get("/tombstones/:entityId", h.Tombstone.GetTombstone, can(domain.PermissionAuditLogsRead))
+
+	// Entitlement reviews ("who can access what") for quarterly access reviews
+	admin.Get("/entitlements/mcp-servers/:id", h.Entitlement.ReviewMCPServer, can(domain.PermissionAuditLogsRead))
+	admin.Get("/entitlements/capabilities/:capability", h.Entitlement.ReviewCapability, can(domain.PermissionAuditLogsRead))
 
 	// Capability Request Management routes (admin only)
-	admin.Get("/capability-requests", h.CapabilityRequest.ListCapabilityRequests)
-	admin.Get("/capability-requests/:id", h.CapabilityRequest.GetCapabilityRequest)
-	admin.Post("/capability-requests/:id/approve", h.CapabilityRequest.ApproveCapabilityRequest)
-	admin.Post("/capability-requests/:id/reject", h.CapabilityRequest.RejectCapabilityRequest)
+	admin.Get("/capability-requests", h.CapabilityRequest.ListCapabilityRequests, can(domain.PermissionCapabilityRequestsApprove))
+	admin.Get("/capability-requests/:id", h.CapabilityRequest.GetCapabilityRequest, can(domain.PermissionCapabilityRequestsApprove))
+	admin.Post("/capability-requests/:id/approve", h.CapabilityRequest.ApproveCapabilityRequest, can(domain.PermissionCapabilityRequestsApprove))
+	admin.Post("/capability-requests/:id/reject", h.CapabilityRequest.RejectCapabilityRequest, can(domain.PermissionCapabilityRequestsApprove))
+
+	// Approval chains (admin only) - multi-step approvals for capability grants, agent verification and policy disabling
+	admin.Get("/approval-chains", h.Approval.ListChains, can(domain.PermissionSecurityPoliciesManage))
+	admin.Post("/approval-chains", h.Approval.CreateChain, can(domain.PermissionSecurityPoliciesManage))
+	admin.Put("/approval-chains/:id", h.Approval.UpdateChain, can(domain.PermissionSecurityPoliciesManage))
+	admin.Delete("/approval-chains/:id", h.Approval.DeleteChain, can(domain.PermissionSecurityPoliciesManage))
 
 	// Verification Approval Management routes (admin only - for require_approval decorator)
-	admin.Get("/verifications/pending", h.Verification.ListPendingVerifications)
-	admin.Post("/verifications/:id/approve", h.Verification.ApproveVerification)
-	admin.Post("/verifications/:id/deny", h.Verification.DenyVerification)
+	admin.Get("/verifications/pending", h.Verification.ListPendingVerifications, can(domain.PermissionVerificationsApprove))
+	admin.Post("/verifications/:id/approve", h.Verification.ApproveVerification, can(domain.PermissionVerificationsApprove))
+	admin.Post("/verifications/:id/deny", h.Verification.DenyVerification, can(domain.PermissionVerificationsApprove))
 
 	// Compliance routes (admin only)
 	// Basic compliance features - Advanced features (SOC 2, HIPAA, GDPR, ISO 27001) reserved for premium
 	compliance := v1.Group("/compliance")
 	compliance.Use(middleware.AuthMiddleware(jwtService))
-	compliance.Use(middleware.AdminMiddleware())
+	compliance.Use(can(domain.PermissionComplianceRead))
 	compliance.Use(middleware.RateLimitMiddleware()) // Changed from StrictRateLimitMiddleware to allow multiple simultaneous requests
+	compliance.Use(orgRateLimit)
+	compliance.Use(idempotency)
 	compliance.Get("/status", h.Compliance.GetComplianceStatus)
 	compliance.Get("/metrics", h.Compliance.GetComplianceMetrics)
 	compliance.Get("/audit-log/access-review", h.Compliance.GetAccessReview)
@@ -977,106 +3079,325 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	// CRITICAL: These MUST be registered BEFORE JWT-protected routes to avoid middleware conflicts
 	// These endpoints use Ed25519 authentication (agent-to-backend) instead of JWT (user-to-backend)
 	mcpServersAgentAuth := v1.Group("/mcp-servers")
+	mcpServersAgentAuth.Use(agentCertificate)                                  // Agent certificates (mTLS)
 	mcpServersAgentAuth.Use(middleware.Ed25519AgentMiddleware(services.Agent)) // Ed25519 signature verification
 	mcpServersAgentAuth.Use(middleware.RateLimitMiddleware())
-	mcpServersAgentAuth.Post("/:id/attest", h.MCPAttestation.AttestMCP)               // ✅ Submit agent attestation (Ed25519 signed)
-	mcpServersAgentAuth.Get("/:id/attestations", h.MCPAttestation.GetMCPAttestations) // ✅ Get all attestations for this MCP
-	mcpServersAgentAuth.Get("/:id/agents", h.MCPAttestation.GetConnectedAgents)       // ✅ Get agents connected to this MCP (via attestation)
+	mcpServersAgentAuth.Use(orgRateLimit)
+	mcpServersAgentAuth.Use(idempotency)
+	mcpServersAgentAuth.Post("/attestation-nonce", h.MCPAttestation.IssueAttestationNonce) // ✅ Single-use nonce to sign into the next attestation
+	mcpServersAgentAuth.Post("/attest", h.MCPAttestation.AttestMCPByURL)                   // ✅ Attest by mcp_url (auto-registers unknown servers when enabled)
+	mcpServersAgentAuth.Post("/:id/attest", h.MCPAttestation.AttestMCP)                    // ✅ Submit agent attestation (Ed25519 signed)
+	mcpServersAgentAuth.Get("/:id/attestations", h.MCPAttestation.GetMCPAttestations)      // ✅ Get all attestations for this MCP
+	mcpServersAgentAuth.Get("/:id/agents", h.MCPAttestation.GetConnectedAgents)            // ✅ Get agents connected to this MCP (via attestation)
 
 	// Standard MCP Server management endpoints - Use JWT authentication (user-to-backend)
 	mcpServers := v1.Group("/mcp-servers")
 	mcpServers.Use(middleware.AuthMiddleware(jwtService))
 	mcpServers.Use(middleware.RateLimitMiddleware())
+	mcpServers.Use(orgRateLimit)
+	mcpServers.Use(idempotency)
 	mcpServers.Get("/", h.MCP.ListMCPServers)
-	mcpServers.Post("/", middleware.MemberMiddleware(), h.MCP.CreateMCPServer)
+	mcpServers.Post("/", h.MCP.CreateMCPServer, can(domain.PermissionMCPServersWrite))
+	mcpServers.Post("/batch", h.MCP.GetMCPServersBatch) // Look up many MCP servers in one query
 	mcpServers.Get("/:id", h.MCP.GetMCPServer)
-	mcpServers.Put("/:id", middleware.MemberMiddleware(), h.MCP.UpdateMCPServer)
-	mcpServers.Delete("/:id", middleware.ManagerMiddleware(), h.MCP.DeleteMCPServer)
-	mcpServers.Post("/:id/verify", middleware.ManagerMiddleware(), h.MCP.VerifyMCPServer)
-	mcpServers.Post("/:id/keys", middleware.MemberMiddleware(), h.MCP.AddPublicKey)
+	mcpServers.Put("/:id", h.MCP.UpdateMCPServer, can(domain.PermissionMCPServersWrite))
+	mcpServers.Delete("/:id", h.MCP.DeleteMCPServer, can(domain.PermissionMCPServersManage))
+	mcpServers.Post("/:id/verify", h.MCP.VerifyMCPServer, can(domain.PermissionMCPServersManage))
+	mcpServers.Post("/:id/keys", h.MCP.AddPublicKey, can(domain.PermissionMCPServersWrite))
 	mcpServers.Get("/:id/verification-status", h.MCP.GetVerificationStatus)
-	mcpServers.Get("/:id/capabilities", h.MCP.GetMCPServerCapabilities)                                    // ✅ Get detected capabilities
-	mcpServers.Get("/:id/verification-events", h.MCP.GetMCPVerificationEvents)                             // ✅ Get verification events for MCP server
-	mcpServers.Post("/:id/manual-attest", middleware.MemberMiddleware(), h.MCPAttestation.ManualAttestMCP) // ✅ Manual attestation (non-SDK users)
+	// ✅ Discover capabilities now through the MCP handshake
+	mcpServers.Post("/:id/capabilities/discover", h.MCP.DiscoverMCPServerCapabilities, can(domain.PermissionMCPServersWrite))
+	mcpServers.Get("/:id/capabilities", h.MCP.GetMCPServerCapabilities)                                                        // ✅ Get detected capabilities
+	mcpServers.Put("/:id/capabilities/:capabilityId/risk", h.MCP.SetMCPCapabilityRisk, can(domain.PermissionMCPServersManage)) // ✅ Override capability risk classification
+	mcpServers.Get("/:id/verification-events", h.MCP.GetMCPVerificationEvents)                                                 // ✅ Get verification events for MCP server
+	mcpServers.Post("/:id/manual-attest", h.MCPAttestation.ManualAttestMCP, can(domain.PermissionMCPServersWrite))             // ✅ Manual attestation (non-SDK users)
+	// ✅ Private network relays - agents that reach MCP servers the backend cannot
+	mcpServers.Get("/:id/relays", h.MCPAttestation.ListNetworkRelays)
+	mcpServers.Post("/:id/relays", h.MCPAttestation.CreateNetworkRelay, can(domain.PermissionMCPServersManage))
+	mcpServers.Delete("/:id/relays/:relayId", h.MCPAttestation.DeleteNetworkRelay, can(domain.PermissionMCPServersManage))
+	// Latency SLOs of the agents' connections to the server
+	mcpServers.Get("/:id/latency-slos", h.ConnectionLatency.ListLatencySLOs)
+	mcpServers.Put("/:id/latency-slos/:agentId", h.ConnectionLatency.SetLatencySLO, can(domain.PermissionMCPServersManage))
+	mcpServers.Delete("/:id/latency-slos/:agentId", h.ConnectionLatency.DeleteLatencySLO, can(domain.PermissionMCPServersManage))
+	mcpServers.Get("/:id/attestation-cadence", h.AttestationCadence.GetAttestationCadence)
+	// Health checks and uptime of the server
+	mcpServers.Get("/:id/health", h.MCPHealth.GetMCPServerHealth)
+	mcpServers.Post("/:id/health/check", h.MCPHealth.CheckMCPServerHealth, can(domain.PermissionMCPServersWrite))
+	mcpServers.Put("/:id/criticality", h.AttestationCadence.SetCriticality, can(domain.PermissionMCPServersManage))
 	// Runtime verification endpoint - CORE functionality
-	mcpServers.Post("/:id/verify-action", h.MCP.VerifyMCPAction)
+	mcpServers.Post("/:id/verify-action", h.MCP.VerifyMCPAction, verificationTimeout, verificationBodyLimit) // Fiber v3 runs the middleware after the handler argument first
 
 	// Security routes (admin/manager)
 	security := v1.Group("/security")
 	security.Use(middleware.AuthMiddleware(jwtService))
-	security.Use(middleware.ManagerMiddleware())
+	security.Use(can(domain.PermissionSecurityManage))
 	security.Use(middleware.RateLimitMiddleware())
+	security.Use(orgRateLimit)
+	security.Use(idempotency)
 	security.Get("/dashboard", h.Security.GetSecurityDashboard)
 	security.Get("/alerts", h.Security.ListSecurityAlerts)
 	security.Get("/threats", h.Security.GetThreats)
+	// ✅ Threat triage: acknowledging a threat blocks it; annotations record analyst notes
+	security.Post("/threats/:id/acknowledge", h.Security.AcknowledgeThreat)
+	security.Get("/threats/:id/annotations", h.Security.ListThreatAnnotations)
+	security.Post("/threats/:id/annotations", h.Security.AnnotateThreat)
 	security.Get("/anomalies", h.Security.GetAnomalies)
 	security.Get("/metrics", h.Security.GetSecurityMetrics)
+	// ✅ Public keys registered to several agents, and the keys the organization shares on purpose
+	security.Get("/shared-keys", h.SharedKey.ListSharedKeys)
+	security.Get("/shared-keys/allowlist", h.SharedKey.ListAllowlist)
+	security.Post("/shared-keys/allowlist", h.SharedKey.AddAllowlistEntry, can(domain.PermissionSharedKeysManage))
+	security.Delete("/shared-keys/allowlist/:id", h.SharedKey.DeleteAllowlistEntry, can(domain.PermissionSharedKeysManage))
+	// ✅ Incident workflow: status, assignment, comments, linked resources and the timeline recording them
+	security.Get("/incidents", h.Incident.ListIncidents)
+	security.Get("/incidents/:id", h.Incident.GetIncident)
+	security.Put("/incidents/:id/status", h.Incident.ChangeStatus)
+	security.Put("/incidents/:id/assignee", h.Incident.AssignIncident)
+	security.Get("/incidents/:id/comments", h.Incident.ListComments)
+	security.Post("/incidents/:id/comments", h.Incident.AddComment)
+	security.Get("/incidents/:id/timeline", h.Incident.GetTimeline)
+	security.Post("/incidents/:id/links", h.Incident.LinkResource)
+	security.Delete("/incidents/:id/links/:type/:resourceId", h.Incident.UnlinkResource)
 
 	// Analytics routes (authentication required)
 	analytics := v1.Group("/analytics")
 	analytics.Use(middleware.AuthMiddleware(jwtService))
 	analytics.Use(middleware.RateLimitMiddleware())
+	analytics.Use(orgRateLimit)
+	analytics.Use(idempotency)
 	analytics.Get("/dashboard", h.Analytics.GetDashboardStats) // Viewer-accessible dashboard stats
 	analytics.Get("/usage", h.Analytics.GetUsageStatistics)
+	analytics.Get("/usage/agents", h.UsageAnalytics.ListAgentUsage)    // Per-agent usage, busiest first; idle agents flagged
+	analytics.Get("/usage/api-keys", h.UsageAnalytics.ListAPIKeyUsage) // Per-API key usage, busiest first; abandoned keys flagged
 	analytics.Get("/activity", h.Analytics.GetActivitySummary)
 	analytics.Get("/trends", h.Analytics.GetTrustScoreTrends)
 	analytics.Get("/verification-activity", h.Analytics.GetVerificationActivity) // New endpoint for chart
 	analytics.Get("/agents/activity", h.Analytics.GetAgentActivity)
+	analytics.Get("/drift", h.DriftAnalytics.GetOrganizationDriftTrends) // Drift trends across the organization
+	analytics.Get("/drift/fleet", h.DriftAnalytics.GetFleetDriftTrends)  // Per-agent drift breakdown, optionally by tag
 
 	// Webhook routes (authentication required)
 	webhooks := v1.Group("/webhooks")
 	webhooks.Use(middleware.AuthMiddleware(jwtService))
 	webhooks.Use(middleware.RateLimitMiddleware())
-	webhooks.Post("/", middleware.MemberMiddleware(), h.Webhook.CreateWebhook)
+	webhooks.Use(orgRateLimit)
+	webhooks.Use(idempotency)
+	webhooks.Post("/", h.Webhook.CreateWebhook, can(domain.PermissionWebhooksWrite))
 	webhooks.Get("/", h.Webhook.ListWebhooks)
+	webhooks.Get("/egress", h.Webhook.GetEgressSettings)                                               // Egress IPs and mTLS client certificate
+	webhooks.Put("/egress", h.Webhook.UpdateEgressSettings, can(domain.PermissionWebhookEgressManage)) // Admin only
 	webhooks.Get("/:id", h.Webhook.GetWebhook)
-	webhooks.Put("/:id", middleware.MemberMiddleware(), h.Webhook.UpdateWebhook) // Update webhook
-	webhooks.Delete("/:id", middleware.MemberMiddleware(), h.Webhook.DeleteWebhook)
+	webhooks.Put("/:id", h.Webhook.UpdateWebhook, can(domain.PermissionWebhooksWrite)) // Update webhook
+	webhooks.Delete("/:id", h.Webhook.DeleteWebhook, can(domain.PermissionWebhooksWrite))
 	webhooks.Post("/:id/test", h.Webhook.TestWebhook) // Test webhook endpoint
 
+	// Notification routes (authentication required) - Channel fan-out with per-recipient delivery state
+	notifications := v1.Group("/notifications")
+	notifications.Use(middleware.AuthMiddleware(jwtService))
+	notifications.Use(middleware.RateLimitMiddleware())
+	notifications.Use(orgRateLimit)
+	notifications.Use(idempotency)
+	notifications.Get("/channels", h.Notification.ListChannels)
+	notifications.Post("/channels", h.Notification.CreateChannel, can(domain.PermissionNotificationsManage))
+	notifications.Get("/channels/:id", h.Notification.GetChannel)
+	notifications.Put("/channels/:id", h.Notification.UpdateChannel, can(domain.PermissionNotificationsManage))
+	notifications.Delete("/channels/:id", h.Notification.DeleteChannel, can(domain.PermissionNotificationsManage))
+	notifications.Post("/channels/:id/test", h.Notification.TestChannel, can(domain.PermissionNotificationsManage))
+	// Routing rules: alert type, severity and resource tags to channels, immediately or as digests
+	notifications.Get("/routes", h.Notification.ListRoutes)
+	notifications.Post("/routes", h.Notification.CreateRoute, can(domain.PermissionNotificationsManage))
+	notifications.Get("/routes/:id", h.Notification.GetRoute)
+	notifications.Put("/routes/:id", h.Notification.UpdateRoute, can(domain.PermissionNotificationsManage))
+	notifications.Delete("/routes/:id", h.Notification.DeleteRoute, can(domain.PermissionNotificationsManage))
+	notifications.Post("/deliveries/:id/resend", h.Notification.ResendDelivery, can(domain.PermissionNotificationsResend)) // Resend a single delivery
+	notifications.Get("/", h.Notification.ListNotifications)
+	notifications.Get("/:id", h.Notification.GetNotification)                                                       // Notification with per-channel per-recipient status
+	notifications.Post("/:id/resend", h.Notification.ResendNotification, can(domain.PermissionNotificationsResend)) // Resend all failed deliveries
+
+	// ✅ Jira / ServiceNow ticket connectors: tickets for capability requests and incidents, synced back
+	ticketing := v1.Group("/ticketing")
+	ticketing.Use(middleware.AuthMiddleware(jwtService))
+	ticketing.Use(middleware.RateLimitMiddleware())
+	ticketing.Use(orgRateLimit)
+	ticketing.Use(idempotency)
+	ticketing.Get("/connectors", h.TicketConnector.ListConnectors)
+	ticketing.Post("/connectors", h.TicketConnector.CreateConnector, can(domain.PermissionIntegrationsManage))
+	ticketing.Get("/connectors/:id", h.TicketConnector.GetConnector)
+	ticketing.Put("/connectors/:id", h.TicketConnector.UpdateConnector, can(domain.PermissionIntegrationsManage))
+	ticketing.Delete("/connectors/:id", h.TicketConnector.DeleteConnector, can(domain.PermissionIntegrationsManage))
+	ticketing.Get("/links", h.TicketConnector.ListLinks) // Tickets opened for a capability request or incident
+
+	// ✅ SIEM exporters: security events streamed over syslog in CEF or LEEF (admin only)
+	siemExporters := v1.Group("/siem/exporters")
+	siemExporters.Use(middleware.AuthMiddleware(jwtService))
+	siemExporters.Use(can(domain.PermissionIntegrationsManage))
+	siemExporters.Use(middleware.RateLimitMiddleware())
+	siemExporters.Use(orgRateLimit)
+	siemExporters.Use(idempotency)
+	siemExporters.Get("/", h.SIEMExporter.ListExporters)
+	siemExporters.Post("/", h.SIEMExporter.CreateExporter)
+	siemExporters.Get("/:id", h.SIEMExporter.GetExporter)
+	siemExporters.Put("/:id", h.SIEMExporter.UpdateExporter)
+	siemExporters.Delete("/:id", h.SIEMExporter.DeleteExporter)
+	siemExporters.Post("/:id/test", h.SIEMExporter.TestExporter) // Sends one test event
+
+	// ✅ Naming policy preview: any member checks a name before registering or renaming
+	namingPolicies := v1.Group("/naming-policies")
+	namingPolicies.Use(middleware.AuthMiddleware(jwtService))
+	namingPolicies.Use(middleware.RateLimitMiddleware())
+	namingPolicies.Use(orgRateLimit)
+	namingPolicies.Use(idempotency)
+	namingPolicies.Post("/preview", h.NamingPolicy.PreviewName)
+
+	// ✅ Dashboard GraphQL API: read-only queries over the organization's agents and MCP servers
+	graphQL := v1.Group("/graphql")
+	graphQL.Use(middleware.AuthMiddleware(jwtService))
+	graphQL.Use(middleware.RateLimitMiddleware())
+	graphQL.Use(orgRateLimit)
+	graphQL.Use(idempotency)
+	graphQL.Post("/", h.GraphQL.Query)
+
+	// Preview feature routes (authentication required) - flags and the user's opt-ins
+	features := v1.Group("/features")
+	features.Use(middleware.AuthMiddleware(jwtService))
+	features.Use(middleware.RateLimitMiddleware())
+	features.Use(orgRateLimit)
+	features.Use(idempotency)
+	features.Get("/", h.FeatureFlag.ListFeatures)
+	features.Put("/:key/opt-in", h.FeatureFlag.SetUserFeature)
+	features.Delete("/:key/opt-in", h.FeatureFlag.ClearUserFeature)
+
+	// Approval request routes (manager+) - actions waiting on an approval chain. Approving means
+	// repeating the guarded action (verify, approve, disable) as another authorized user.
+	approvalRequests := v1.Group("/approval-requests")
+	approvalRequests.Use(middleware.AuthMiddleware(jwtService))
+	approvalRequests.Use(can(domain.PermissionApprovalsDecide))
+	approvalRequests.Use(middleware.RateLimitMiddleware())
+	approvalRequests.Use(orgRateLimit)
+	approvalRequests.Use(idempotency)
+	approvalRequests.Get("/", h.Approval.ListRequests)
+	approvalRequests.Get("/:id", h.Approval.GetRequest)
+	approvalRequests.Post("/:id/reject", h.Approval.RejectRequest)
+
+	// Playbook routes (authentication required) - incident-driven containment. Managers edit
+	// playbooks, run them and decide approval-gated steps.
+	playbooks := v1.Group("/playbooks")
+	playbooks.Use(middleware.AuthMiddleware(jwtService))
+	playbooks.Use(middleware.RateLimitMiddleware())
+	playbooks.Use(orgRateLimit)
+	playbooks.Use(idempotency)
+	playbooks.Get("/", h.Playbook.ListPlaybooks)
+	playbooks.Post("/", h.Playbook.CreatePlaybook, can(domain.PermissionPlaybooksManage))
+	playbooks.Get("/executions", h.Playbook.ListExecutions)
+	playbooks.Get("/executions/:id", h.Playbook.GetExecution)
+	playbooks.Post("/executions/:id/approve", h.Playbook.ApproveStep, can(domain.PermissionPlaybooksManage))
+	playbooks.Post("/executions/:id/reject", h.Playbook.RejectStep, can(domain.PermissionPlaybooksManage))
+	playbooks.Post("/executions/:id/cancel", h.Playbook.CancelExecution, can(domain.PermissionPlaybooksManage))
+	playbooks.Get("/:id", h.Playbook.GetPlaybook)
+	playbooks.Put("/:id", h.Playbook.UpdatePlaybook, can(domain.PermissionPlaybooksManage))
+	playbooks.Delete("/:id", h.Playbook.DeletePlaybook, can(domain.PermissionPlaybooksManage))
+	playbooks.Post("/:id/run", h.Playbook.RunPlaybook, can(domain.PermissionPlaybooksManage))
+
+	// Change request routes (authentication required) - configuration changes staged for a change
+	// window. Members stage agent changes, admins policy changes; managers approve and roll back.
+	changeRequests := v1.Group("/change-requests")
+	changeRequests.Use(middleware.AuthMiddleware(jwtService))
+	changeRequests.Use(middleware.RateLimitMiddleware())
+	changeRequests.Use(orgRateLimit)
+	changeRequests.Use(idempotency)
+	changeRequests.Get("/", h.ChangeRequest.ListChangeRequests)
+	changeRequests.Post("/", h.ChangeRequest.CreateChangeRequest, can(domain.PermissionChangeRequestsCreate))
+	changeRequests.Get("/:id", h.ChangeRequest.GetChangeRequest)
+	changeRequests.Post("/:id/approve", h.ChangeRequest.ApproveChangeRequest, can(domain.PermissionChangeRequestsApprove))
+	changeRequests.Post("/:id/reject", h.ChangeRequest.RejectChangeRequest, can(domain.PermissionChangeRequestsApprove))
+	changeRequests.Post("/:id/cancel", h.ChangeRequest.CancelChangeRequest, can(domain.PermissionChangeRequestsCreate))
+	changeRequests.Post("/:id/rollback", h.ChangeRequest.RollbackChangeRequest, can(domain.PermissionChangeRequestsApprove))
+
+	// Report routes (authentication required) - Scheduled reports delivered through notification channels
+	reports := v1.Group("/reports")
+	reports.Use(middleware.AuthMiddleware(jwtService))
+	reports.Use(middleware.RateLimitMiddleware())
+	reports.Use(orgRateLimit)
+	reports.Use(idempotency)
+	reports.Get("/subscriptions", h.Report.ListSubscriptions)
+	reports.Post("/subscriptions", h.Report.CreateSubscription, can(domain.PermissionReportsManage))
+	reports.Get("/subscriptions/:id", h.Report.GetSubscription)
+	reports.Put("/subscriptions/:id", h.Report.UpdateSubscription, can(domain.PermissionReportsManage))
+	reports.Delete("/subscriptions/:id", h.Report.DeleteSubscription, can(domain.PermissionReportsManage))
+	reports.Post("/subscriptions/:id/run", h.Report.RunSubscription, can(domain.PermissionReportsRun)) // Generate and deliver now
+	reports.Get("/subscriptions/:id/runs", h.Report.ListRuns)
+	reports.Get("/runs/:id/download", h.Report.DownloadRun)
+
 	// Verification routes (authentication required) - Agent action verification
 	verifications := v1.Group("/verifications")
+	verifications.Use(middleware.OptionalAPIKeyMiddleware(services.APIKey)) // ✅ Scoped API keys (X-API-Key or "Bearer aim_...")
+	verifications.Use(agentCertificate)                        // ✅ Agent certificates (mTLS)
 	verifications.Use(middleware.AuthMiddleware(jwtService))
 	verifications.Use(middleware.RateLimitMiddleware())
+	verifications.Use(verificationRateLimit)
+	verifications.Use(idempotency)
+	verifications.Use(verificationTimeout)
+	verifications.Use(verificationBodyLimit)
+	// ✅ API keys only request, read and stream verifications (verify:create / verify:read)
+	verifications.Use(middleware.RequireAPIKeyRoute())
+
+	// ✅ Asynchronous CSV / Parquet exports of verification events (before /:id)
+	verifications.Post("/export", h.VerificationExport.RequestExport)
+	verifications.Get("/exports", h.VerificationExport.ListExports)
+	verifications.Get("/exports/:id", h.VerificationExport.GetExport)
+	verifications.Get("/exports/:id/download", h.VerificationExport.DownloadExport)
+
+	// ✅ Push new verification events to dashboards as they are recorded (SSE, before /:id)
+	verifications.Get("/stream", h.VerificationEvent.StreamVerificationEvents)
+
 	verifications.Post("/", h.Verification.CreateVerification)                 // Request verification for agent action
 	verifications.Get("/:id", h.Verification.GetVerification)                  // Get verification status by ID
 	verifications.Post("/:id/result", h.Verification.SubmitVerificationResult) // Submit verification result
+	// ✅ Admins approve/deny many pending verifications in one transaction
+	verifications.Post("/bulk-decision", h.Verification.BulkDecideVerifications, can(domain.PermissionVerificationsApprove))
 
 	// Verification Event routes (authentication required) - Real-time monitoring
 	verificationEvents := v1.Group("/verification-events")
 	verificationEvents.Use(middleware.AuthMiddleware(jwtService))
 	verificationEvents.Use(middleware.RateLimitMiddleware())
+	verificationEvents.Use(orgRateLimit)
+	verificationEvents.Use(idempotency)
 	verificationEvents.Get("/", h.VerificationEvent.ListVerificationEvents)
 	verificationEvents.Get("/recent", h.VerificationEvent.GetRecentEvents)
 	verificationEvents.Get("/statistics", h.VerificationEvent.GetStatistics)
 	verificationEvents.Get("/stats", h.VerificationEvent.GetVerificationStats)           // ✅ Get aggregated verification stats
 	verificationEvents.Get("/agent/:id", h.VerificationEvent.GetAgentVerificationEvents) // ✅ Get events for specific agent
 	verificationEvents.Get("/mcp/:id", h.VerificationEvent.GetMCPVerificationEvents)     // ✅ Get events for specific MCP server
+	// ✅ Live tail of matching events (SSE or NDJSON) with resume tokens
+	verificationEvents.Get("/tail", h.VerificationEvent.TailVerificationEvents)
 	verificationEvents.Get("/:id", h.VerificationEvent.GetVerificationEvent)
-	verificationEvents.Post("/", middleware.MemberMiddleware(), h.VerificationEvent.CreateVerificationEvent)
-	verificationEvents.Delete("/:id", middleware.ManagerMiddleware(), h.VerificationEvent.DeleteVerificationEvent)
+	verificationEvents.Post("/", h.VerificationEvent.CreateVerificationEvent, can(domain.PermissionVerificationEventsCreate))
+	verificationEvents.Delete("/:id", h.VerificationEvent.DeleteVerificationEvent, can(domain.PermissionVerificationEventsDelete))
 
 	// Tag routes (authentication required)
 	tags := v1.Group("/tags")
 	tags.Use(middleware.AuthMiddleware(jwtService))
 	tags.Use(middleware.RateLimitMiddleware())
+	tags.Use(orgRateLimit)
+	tags.Use(idempotency)
 	tags.Get("/", h.Tag.GetTags)
-	tags.Post("/", middleware.MemberMiddleware(), h.Tag.CreateTag)
-	tags.Put("/:id", middleware.MemberMiddleware(), h.Tag.UpdateTag)
+	tags.Post("/", h.Tag.CreateTag, can(domain.PermissionTagsWrite))
+	tags.Put("/:id", h.Tag.UpdateTag, can(domain.PermissionTagsWrite))
 	tags.Get("/popular", h.Tag.GetPopularTags)
 	tags.Get("/search", h.Tag.SearchTags)
-	tags.Delete("/:id", middleware.ManagerMiddleware(), h.Tag.DeleteTag)
+	tags.Delete("/:id", h.Tag.DeleteTag, can(domain.PermissionTagsDelete))
 
 	// Agent tag routes (under /agents/:id/tags)
 	agents.Get("/:id/tags", h.Tag.GetAgentTags)
-	agents.Post("/:id/tags", middleware.MemberMiddleware(), h.Tag.AddTagsToAgent)
-	agents.Delete("/:id/tags/:tagId", middleware.MemberMiddleware(), h.Tag.RemoveTagFromAgent)
+	agents.Post("/:id/tags", h.Tag.AddTagsToAgent, can(domain.PermissionTagsWrite))
+	agents.Delete("/:id/tags/:tagId", h.Tag.RemoveTagFromAgent, can(domain.PermissionTagsWrite))
 	agents.Get("/:id/tags/suggestions", h.Tag.SuggestTagsForAgent)
 
 	// Agent capability routes (under /agents/:id/capabilities)
 	agents.Get("/:id/capabilities", h.Capability.GetAgentCapabilities)
-	agents.Post("/:id/capabilities", middleware.ManagerMiddleware(), h.Capability.GrantCapability)
-	agents.Delete("/:id/capabilities/:capabilityId", middleware.ManagerMiddleware(), h.Capability.RevokeCapability)
+	agents.Post("/:id/capabilities", h.Capability.GrantCapability, can(domain.PermissionCapabilitiesGrant))
+	agents.Delete("/:id/capabilities/:capabilityId", h.Capability.RevokeCapability, can(domain.PermissionCapabilitiesGrant))
+	agents.Post("/:id/capabilities/:capabilityId/renew", h.CapabilityRequest.RenewCapability, can(domain.PermissionCapabilitiesRenew))
 
 	// Agent violation routes (under /agents/:id/violations)
 	agents.Get("/:id/violations", h.Capability.GetViolationsByAgent)
@@ -1090,11 +3411,13 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	capabilityRequests := v1.Group("/capability-requests")
 	capabilityRequests.Use(middleware.AuthMiddleware(jwtService))
 	capabilityRequests.Use(middleware.RateLimitMiddleware())
+	capabilityRequests.Use(orgRateLimit)
+	capabilityRequests.Use(idempotency)
 
 	// MCP server tag routes (under /mcp-servers/:id/tags)
 	mcpServers.Get("/:id/tags", h.Tag.GetMCPServerTags)
-	mcpServers.Post("/:id/tags", middleware.MemberMiddleware(), h.Tag.AddTagsToMCPServer)
-	mcpServers.Delete("/:id/tags/:tagId", middleware.MemberMiddleware(), h.Tag.RemoveTagFromMCPServer)
+	mcpServers.Post("/:id/tags", h.Tag.AddTagsToMCPServer, can(domain.PermissionTagsWrite))
+	mcpServers.Delete("/:id/tags/:tagId", h.Tag.RemoveTagFromMCPServer, can(domain.PermissionTagsWrite))
 	mcpServers.Get("/:id/tags/suggestions", h.Tag.SuggestTagsForMCPServer)
 }
 
@@ -1117,148 +3440,34 @@ func customErrorHandler(c fiber.Ctx, err error) error {
 	})
 }
 
-// runMigrations executes all pending database migrations automatically on startup
-// This ensures production deployments have the correct schema without manual intervention
-func runMigrations(db *sql.DB) error {
-	log.Println("🔄 Running database migrations...")
-
-	// Create migrations table if it doesn't exist
-	if err := createMigrationsTable(db); err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
-	}
-
-	// Get migration files from migrations directory
-	files, err := getMigrationFiles()
-	if err != nil {
-		return fmt.Errorf("failed to read migration files: %w", err)
-	}
-
-	if len(files) == 0 {
-		log.Println("ℹ️  No migration files found")
-		return nil
-	}
-
-	// Get applied migrations from database
-	applied, err := getAppliedMigrations(db)
-	if err != nil {
-		return fmt.Errorf("failed to get applied migrations: %w", err)
-	}
-
-	// Apply pending migrations
-	pendingCount := 0
-	for _, file := range files {
-		version := getMigrationVersion(file)
-		if applied[version] {
-			log.Printf("⏭️  Skipping %s (already applied)", file)
-			continue
-		}
-
-		log.Printf("🔄 Applying %s...", file)
-
-		// Read migration file
-		content, err := ioutil.ReadFile(filepath.Join("migrations", file))
-		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", file, err)
-		}
-
-		// Execute migration in a transaction for safety
-		tx, err := db.Begin()
+// runMigrations applies the pending database migrations on startup, so deployments get the
+// schema they need without manual intervention. Operators who upgrade the schema separately
+// (MIGRATIONS_AUTO_APPLY=false) run cmd/migrate instead; the server then only reports what is pending.
+func runMigrations(migrator *database.Migrator, autoApply bool) error {
+	ctx := context.Background()
+	if !autoApply {
+		status, err := migrator.Status(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to start transaction for %s: %w", file, err)
+			return err
 		}
-
-		// Execute migration SQL
-		if _, err := tx.Exec(string(content)); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to execute %s: %w", file, err)
+		if status.Dirty {
+			log.Printf("⚠️  Migration %s failed part way; complete or undo it, then run cmd/migrate force", status.CurrentVersion)
 		}
-
-		// Record migration
-		if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", version); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to record migration %s: %w", version, err)
+		if len(status.Pending) > 0 {
+			log.Printf("⚠️  %d pending migration(s) not applied (MIGRATIONS_AUTO_APPLY=false); run cmd/migrate to apply them", len(status.Pending))
 		}
-
-		// Commit transaction
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("failed to commit migration %s: %w", file, err)
-		}
-
-		log.Printf("✅ Applied %s", file)
-		pendingCount++
+		return nil
 	}
 
-	if pendingCount == 0 {
+	log.Println("🔄 Running database migrations...")
+	applied, err := migrator.Apply(ctx)
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
 		log.Println("ℹ️  All migrations already applied (database is up to date)")
 	} else {
-		log.Printf("✅ Successfully applied %d pending migration(s)", pendingCount)
+		log.Printf("✅ Successfully applied %d pending migration(s)", len(applied))
 	}
-
 	return nil
 }
-
-// createMigrationsTable creates the schema_migrations table if it doesn't exist
-func createMigrationsTable(db *sql.DB) error {
-	query := `
-		CREATE TABLE IF NOT EXISTS schema_migrations (
-			id SERIAL PRIMARY KEY,
-			version VARCHAR(255) NOT NULL UNIQUE,
-			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
-		)
-	`
-	_, err := db.Exec(query)
-	return err
-}
-
-// getMigrationFiles returns sorted list of .up.sql migration files
-func getMigrationFiles() ([]string, error) {
-	files, err := ioutil.ReadDir("migrations")
-	if err != nil {
-		// If migrations directory doesn't exist, return empty list (not an error)
-		if os.IsNotExist(err) {
-			return []string{}, nil
-		}
-		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
-	}
-
-	var migrations []string
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
-		// Only include .up.sql files for forward migrations
-		if strings.HasSuffix(file.Name(), ".up.sql") ||
-			(strings.HasSuffix(file.Name(), ".sql") && !strings.Contains(file.Name(), ".down.sql")) {
-			migrations = append(migrations, file.Name())
-		}
-	}
-
-	sort.Strings(migrations)
-	return migrations, nil
-}
-
-// getAppliedMigrations returns map of already-applied migration versions
-func getAppliedMigrations(db *sql.DB) (map[string]bool, error) {
-	rows, err := db.Query("SELECT version FROM schema_migrations")
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	applied := make(map[string]bool)
-	for rows.Next() {
-		var version string
-		if err := rows.Scan(&version); err != nil {
-			return nil, err
-		}
-		applied[version] = true
-	}
-
-	return applied, nil
-}
-
-// getMigrationVersion extracts version from migration filename
-func getMigrationVersion(filename string) string {
-	// Use full filename as version for unique tracking
-	return filename
-}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/crypto"
+)
+
+// Verifies that an AIM export file was not modified after download.
+//
+// Recipients verify against the AIM API:
+//
+//	verify_export -url https://aim.example.com -signature <hex> -key-id <id> report.csv
+//
+// Operators holding the signing key can verify offline by setting EXPORT_SIGNING_KEY
+// (and EXPORT_SIGNING_PREVIOUS_KEYS after a rotation) and leaving out -url.
+func main() {
+	apiURL := flag.String("url", os.Getenv("AIM_API_URL"), "AIM API base URL (verifies online)")
+	signature := flag.String("signature", "", "Signature from the X-AIM-Export-Signature header")
+	keyID := flag.String("key-id", "", "Key ID from the X-AIM-Export-Key-Id header (optional)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -signature <hex> [-key-id <id>] [-url <aim url>] <file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 || *signature == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	path := flag.Arg(0)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("❌ Failed to read export file: %v", err)
+	}
+
+	var valid bool
+	var signedBy string
+	if *apiURL != "" {
+		valid, signedBy, err = verifyOnline(*apiURL, path, data, *signature, *keyID)
+	} else {
+		valid, signedBy, err = verifyOffline(data, *signature, *keyID)
+	}
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	if !valid {
+		fmt.Printf("❌ %s does NOT match its signature: it was modified or signed with another key\n", path)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ %s is unchanged (signed with key %s)\n", path, signedBy)
+}
+
+// verifyOffline checks the signature with the keys in the environment
+func verifyOffline(data []byte, signature, keyID string) (bool, string, error) {
+	if os.Getenv("EXPORT_SIGNING_KEY") == "" {
+		return false, "", fmt.Errorf("either -url or EXPORT_SIGNING_KEY is required")
+	}
+	signer, err := crypto.NewExportSignerFromEnv(nil)
+	if err != nil {
+		return false, "", err
+	}
+	signedBy, valid := signer.Verify(data, signature, keyID)
+	return valid, signedBy, nil
+}
+
+// verifyOnline uploads the file to the AIM verification endpoint
+func verifyOnline(apiURL, path string, data []byte, signature, keyID string) (bool, string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return false, "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return false, "", err
+	}
+	if err := form.WriteField("signature", signature); err != nil {
+		return false, "", err
+	}
+	if err := form.WriteField("key_id", keyID); err != nil {
+		return false, "", err
+	}
+	if err := form.Close(); err != nil {
+		return false, "", err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(strings.TrimRight(apiURL, "/")+"/api/v1/public/exports/verify", form.FormDataContentType(), &body)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to reach AIM: %w", err)
+	}
+	defer resp.Body.Close()
+
+	payload, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return false, "", fmt.Errorf("verification failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(payload)))
+	}
+
+	var result struct {
+		Valid bool   `json:"valid"`
+		KeyID string `json:"keyId"`
+	}
+	if err := json.Unmarshal(payload, &result); err != nil {
+		return false, "", fmt.Errorf("failed to decode verification response: %w", err)
+	}
+	return result.Valid, result.KeyID, nil
+}
@@ -2,7 +2,10 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,18 +14,21 @@ import (
 
 // AdminService handles administrative operations
 type AdminService struct {
-	userRepo domain.UserRepository
-	orgRepo  domain.OrganizationRepository
+	userRepo  domain.UserRepository
+	orgRepo   domain.OrganizationRepository
+	agentRepo domain.AgentRepository
 }
 
 // NewAdminService creates a new admin service
 func NewAdminService(
 	userRepo domain.UserRepository,
 	orgRepo domain.OrganizationRepository,
+	agentRepo domain.AgentRepository, // Escrowed private keys are dropped when client-side key generation is turned on
 ) *AdminService {
 	return &AdminService{
-		userRepo: userRepo,
-		orgRepo:  orgRepo,
+		userRepo:  userRepo,
+		orgRepo:   orgRepo,
+		agentRepo: agentRepo,
 	}
 }
 
@@ -197,3 +203,134 @@ func (s *AdminService) PermanentlyDeleteUser(ctx context.Context, userID, adminI
 func (s *AdminService) GetOrganizationSettings(ctx context.Context, orgID uuid.UUID) (*domain.Organization, error) {
 	return s.orgRepo.GetByID(orgID)
 }
+
+// ErrInvalidOrganizationSettings is returned when a settings update has an invalid value
+var ErrInvalidOrganizationSettings = errors.New("invalid organization settings")
+
+// UpdateOrganizationSettingsRequest holds the organization settings admins can change.
+// Omitted fields keep their current value.
+type UpdateOrganizationSettingsRequest struct {
+	AutoRegisterAttestedMCPs *bool `json:"autoRegisterAttestedMcps,omitempty"`
+	ClientSideKeyGeneration  *bool `json:"clientSideKeyGeneration,omitempty"`
+	// MFARequiredRoles replaces the roles that must sign in with a second factor; [] requires it for none
+	MFARequiredRoles *[]domain.UserRole `json:"mfaRequiredRoles,omitempty"`
+	// RateLimits replaces the API rate limits by endpoint class; classes left out use the
+	// server's limits and {} restores them all
+	RateLimits map[string]domain.RateLimit `json:"rateLimits,omitempty"`
+	// AllowedKeyAlgorithms replaces the key algorithms agents may register, rotate to and sign
+	// with; [] allows every supported algorithm
+	AllowedKeyAlgorithms *[]string `json:"allowedKeyAlgorithms,omitempty"`
+	// InviteesRequireApproval makes users who accept an invitation wait for an admin's approval
+	InviteesRequireApproval *bool `json:"inviteesRequireApproval,omitempty"`
+	// RequireAttestationNonce rejects MCP attestations without a server-issued nonce; while it is
+	// off, attestations from SDKs that predate nonces are accepted and logged
+	RequireAttestationNonce *bool `json:"requireAttestationNonce,omitempty"`
+}
+
+// UpdateOrganizationSettings applies a partial settings update
+func (s *AdminService) UpdateOrganizationSettings(ctx context.Context, orgID uuid.UUID, req *UpdateOrganizationSettingsRequest) (*domain.Organization, error) {
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.AutoRegisterAttestedMCPs != nil {
+		org.AutoRegisterAttestedMCPs = *req.AutoRegisterAttestedMCPs
+	}
+	if req.ClientSideKeyGeneration != nil {
+		org.ClientSideKeyGeneration = *req.ClientSideKeyGeneration
+	}
+	if req.MFARequiredRoles != nil {
+		roles := []domain.UserRole{}
+		for _, role := range *req.MFARequiredRoles {
+			switch role {
+			case domain.RoleAdmin, domain.RoleManager, domain.RoleMember, domain.RoleViewer:
+			default:
+				return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidOrganizationSettings, role)
+			}
+			if !slices.Contains(roles, role) {
+				roles = append(roles, role)
+			}
+		}
+		org.MFARequiredRoles = roles
+	}
+	if req.RateLimits != nil {
+		for class, limit := range req.RateLimits {
+			if !domain.IsEndpointClass(class) {
+				return nil, fmt.Errorf("%w: unknown rate limit class %q", ErrInvalidOrganizationSettings, class)
+			}
+			if limit.RequestsPerMinute < 0 || limit.Burst < 0 {
+				return nil, fmt.Errorf("%w: %s rate limit must not be negative", ErrInvalidOrganizationSettings, class)
+			}
+		}
+		settings := make(map[string]interface{}, len(org.Settings)+1)
+		for key, value := range org.Settings {
+			settings[key] = value
+		}
+		if len(req.RateLimits) > 0 {
+			settings[domain.OrganizationSettingRateLimits] = req.RateLimits
+		} else {
+			delete(settings, domain.OrganizationSettingRateLimits)
+		}
+		org.Settings = settings
+	}
+	if req.AllowedKeyAlgorithms != nil {
+		algorithms := []string{}
+		for _, name := range *req.AllowedKeyAlgorithms {
+			algorithm, ok := domain.NormalizeKeyAlgorithm(name)
+			if !ok || strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("%w: unknown key algorithm %q; supported algorithms are %s", ErrInvalidOrganizationSettings, name, strings.Join(domain.KeyAlgorithms, ", "))
+			}
+			if !slices.Contains(algorithms, algorithm) {
+				algorithms = append(algorithms, algorithm)
+			}
+		}
+		settings := make(map[string]interface{}, len(org.Settings)+1)
+		for key, value := range org.Settings {
+			settings[key] = value
+		}
+		if len(algorithms) > 0 {
+			settings[domain.OrganizationSettingKeyAlgorithms] = algorithms
+		} else {
+			delete(settings, domain.OrganizationSettingKeyAlgorithms)
+		}
+		org.Settings = settings
+	}
+	if req.InviteesRequireApproval != nil {
+		settings := make(map[string]interface{}, len(org.Settings)+1)
+		for key, value := range org.Settings {
+			settings[key] = value
+		}
+		if *req.InviteesRequireApproval {
+			settings[domain.OrganizationSettingInviteesRequireApproval] = true
+		} else {
+			delete(settings, domain.OrganizationSettingInviteesRequireApproval)
+		}
+		org.Settings = settings
+	}
+	if req.RequireAttestationNonce != nil {
+		settings := make(map[string]interface{}, len(org.Settings)+1)
+		for key, value := range org.Settings {
+			settings[key] = value
+		}
+		if *req.RequireAttestationNonce {
+			settings[domain.OrganizationSettingRequireAttestationNonce] = true
+		} else {
+			delete(settings, domain.OrganizationSettingRequireAttestationNonce)
+		}
+		org.Settings = settings
+	}
+
+	if err := s.orgRepo.Update(org); err != nil {
+		return nil, fmt.Errorf("failed to update organization settings: %w", err)
+	}
+
+	// Opting out of key escrow removes the private keys AIM generated for existing agents; those
+	// agents keep signing with the keys they were given
+	if org.ClientSideKeyGeneration && s.agentRepo != nil {
+		if _, err := s.agentRepo.DropEscrowedPrivateKeys(orgID); err != nil {
+			return nil, fmt.Errorf("failed to drop escrowed private keys: %w", err)
+		}
+	}
+	return org, nil
+}
@@ -0,0 +1,291 @@
+package application
+
+import (
+	"context"
+	stdcrypto "crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// DefaultAgentCertificateValidity is how long an agent certificate is valid
+	DefaultAgentCertificateValidity = 24 * time.Hour
+	// agentCertificateBackdate tolerates clock skew of relying parties
+	agentCertificateBackdate = 5 * time.Minute
+	// agentCRLValidity is how long relying parties may cache the revocation list
+	agentCRLValidity = time.Hour
+)
+
+var (
+	// ErrAgentNotCertifiable is returned when a certificate is requested for an agent that is not
+	// verified or has no public key
+	ErrAgentNotCertifiable = errors.New("agent cannot be issued a certificate")
+	// ErrCertificateAgentNotFound is returned when the agent does not exist in the organization
+	ErrCertificateAgentNotFound = errors.New("agent not found")
+	// ErrClientCertificateRejected is returned when a TLS client certificate does not
+	// authenticate an agent
+	ErrClientCertificateRejected = errors.New("client certificate rejected")
+)
+
+// AgentCertificateService issues short-lived X.509 certificates to verified agents from the
+// platform CA and keeps the revocation list that verification checks against
+type AgentCertificateService struct {
+	certificateRepo domain.AgentCertificateRepository
+	agentRepo       domain.AgentRepository
+	ca              *crypto.AgentCA
+	validity        time.Duration
+	publicURL       string
+	now             func() time.Time
+}
+
+// NewAgentCertificateService creates a new agent certificate service. A zero validity uses
+// DefaultAgentCertificateValidity. Certificates link to the CRL under publicURL.
+func NewAgentCertificateService(
+	certificateRepo domain.AgentCertificateRepository,
+	agentRepo domain.AgentRepository,
+	ca *crypto.AgentCA,
+	validity time.Duration,
+	publicURL string,
+) *AgentCertificateService {
+	if validity <= 0 {
+		validity = DefaultAgentCertificateValidity
+	}
+	return &AgentCertificateService{
+		certificateRepo: certificateRepo,
+		agentRepo:       agentRepo,
+		ca:              ca,
+		validity:        validity,
+		publicURL:       strings.TrimRight(publicURL, "/"),
+		now:             time.Now,
+	}
+}
+
+// CACertificatePEM returns the PEM-encoded CA certificate relying parties trust
+func (s *AgentCertificateService) CACertificatePEM() []byte {
+	return s.ca.CertificatePEM()
+}
+
+// Issue issues a new certificate for the agent's current public key. Earlier certificates of
+// the agent are revoked as superseded.
+func (s *AgentCertificateService) Issue(ctx context.Context, agent *domain.Agent) (*domain.AgentCertificate, error) {
+	if agent.Status != domain.AgentStatusVerified || agent.IsCompromised {
+		return nil, fmt.Errorf("%w: agent status is %s", ErrAgentNotCertifiable, agent.Status)
+	}
+	if agent.PublicKey == nil || *agent.PublicKey == "" {
+		return nil, fmt.Errorf("%w: agent has no public key", ErrAgentNotCertifiable)
+	}
+	publicKey, err := crypto.ParsePublicKey(agent.KeyAlgorithm, *agent.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAgentNotCertifiable, err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate certificate serial number: %w", err)
+	}
+
+	now := s.now().UTC()
+	req := &crypto.AgentCertificateRequest{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:         agent.Name,
+			Organization:       []string{agent.OrganizationID.String()},
+			OrganizationalUnit: []string{string(agent.AgentType)},
+		},
+		URIs: []*url.URL{
+			{Scheme: "urn", Opaque: "aim:agent:" + agent.ID.String()},
+			{Scheme: "urn", Opaque: "aim:organization:" + agent.OrganizationID.String()},
+		},
+		PublicKey: publicKey,
+		NotBefore: now.Add(-agentCertificateBackdate),
+		NotAfter:  now.Add(s.validity),
+	}
+	if s.publicURL != "" {
+		req.CRLURL = s.publicURL + "/api/v1/public/agent-ca/crl"
+	}
+
+	certificatePEM, err := s.ca.Issue(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.certificateRepo.RevokeByAgent(agent.ID, domain.CertificateRevocationSuperseded, now); err != nil {
+		return nil, fmt.Errorf("failed to supersede previous certificates: %w", err)
+	}
+
+	certificate := &domain.AgentCertificate{
+		ID:             uuid.New(),
+		OrganizationID: agent.OrganizationID,
+		AgentID:        agent.ID,
+		SerialNumber:   serial.Text(16),
+		PublicKey:      *agent.PublicKey,
+		CertificatePEM: string(certificatePEM),
+		NotBefore:      req.NotBefore,
+		NotAfter:       req.NotAfter,
+		CreatedAt:      now,
+	}
+	if err := s.certificateRepo.Create(certificate); err != nil {
+		return nil, fmt.Errorf("failed to store agent certificate: %w", err)
+	}
+
+	// Point the agent's certificate URL at the platform unless the agent brought its own
+	if agent.CertificateURL == "" && s.publicURL != "" {
+		agent.CertificateURL = fmt.Sprintf("%s/api/v1/agents/%s/certificate", s.publicURL, agent.ID)
+		if err := s.agentRepo.Update(agent); err != nil {
+			log.Printf("⚠️  Failed to store certificate URL of agent %s: %v", agent.ID, err)
+		}
+	}
+
+	return certificate, nil
+}
+
+// GetCurrent returns the agent's latest certificate. Verified agents get a new certificate
+// when theirs expires within the last quarter of its validity or no longer matches their key.
+func (s *AgentCertificateService) GetCurrent(ctx context.Context, orgID, agentID uuid.UUID) (*domain.AgentCertificate, error) {
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil || agent.OrganizationID != orgID {
+		return nil, ErrCertificateAgentNotFound
+	}
+
+	certificates, err := s.certificateRepo.GetByAgent(agentID)
+	if err != nil {
+		return nil, err
+	}
+	var latest *domain.AgentCertificate
+	if len(certificates) > 0 {
+		latest = certificates[0]
+	}
+
+	now := s.now()
+	renew := latest == nil ||
+		latest.Status(now.Add(s.validity/4)) != domain.AgentCertificateStatusValid ||
+		agent.PublicKey == nil || latest.PublicKey != *agent.PublicKey
+	if renew && agent.Status == domain.AgentStatusVerified && !agent.IsCompromised {
+		return s.Issue(ctx, agent)
+	}
+	if latest == nil {
+		return nil, domain.ErrAgentCertificateNotFound
+	}
+	return latest, nil
+}
+
+// GetBySerialNumber returns a certificate by serial number (OCSP-style status lookups)
+func (s *AgentCertificateService) GetBySerialNumber(ctx context.Context, serialNumber string) (*domain.AgentCertificate, error) {
+	return s.certificateRepo.GetBySerialNumber(strings.ToLower(strings.TrimLeft(serialNumber, "0")))
+}
+
+// Revoke revokes every unrevoked certificate of the agent
+func (s *AgentCertificateService) Revoke(ctx context.Context, agentID uuid.UUID, reason domain.CertificateRevocationReason) (int, error) {
+	return s.certificateRepo.RevokeByAgent(agentID, reason, s.now().UTC())
+}
+
+// AuthenticateClientCertificate returns the agent a TLS client certificate authenticates. The
+// certificate must chain to the platform CA, be one the platform issued to the agent, not be
+// revoked (certificates superseded by a renewal stay usable until they expire) and carry the
+// public key the agent has registered, so rotating the agent's key retires its certificates.
+func (s *AgentCertificateService) AuthenticateClientCertificate(ctx context.Context, certificate *x509.Certificate) (*domain.Agent, *domain.AgentCertificate, error) {
+	if err := s.ca.VerifyCertificate(certificate, s.now()); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrClientCertificateRejected, err)
+	}
+
+	var agentID, orgID uuid.UUID
+	for _, uri := range certificate.URIs {
+		if uri.Scheme != "urn" {
+			continue
+		}
+		if id, ok := strings.CutPrefix(uri.Opaque, "aim:agent:"); ok {
+			agentID, _ = uuid.Parse(id)
+		}
+		if id, ok := strings.CutPrefix(uri.Opaque, "aim:organization:"); ok {
+			orgID, _ = uuid.Parse(id)
+		}
+	}
+	if agentID == uuid.Nil || orgID == uuid.Nil {
+		return nil, nil, fmt.Errorf("%w: certificate does not identify an agent", ErrClientCertificateRejected)
+	}
+
+	issued, err := s.certificateRepo.GetBySerialNumber(certificate.SerialNumber.Text(16))
+	if err != nil || issued.AgentID != agentID || issued.OrganizationID != orgID {
+		return nil, nil, fmt.Errorf("%w: unknown certificate", ErrClientCertificateRejected)
+	}
+	if issued.RevokedAt != nil && issued.RevocationReason != nil &&
+		*issued.RevocationReason != domain.CertificateRevocationSuperseded {
+		return nil, nil, fmt.Errorf("%w: certificate revoked (%s)", ErrClientCertificateRejected, *issued.RevocationReason)
+	}
+
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil || agent.OrganizationID != orgID {
+		return nil, nil, fmt.Errorf("%w: agent not found", ErrClientCertificateRejected)
+	}
+	if agent.IsCompromised || agent.Status == domain.AgentStatusRevoked {
+		return nil, nil, fmt.Errorf("%w: agent status is %s", ErrClientCertificateRejected, agent.Status)
+	}
+
+	if agent.PublicKey == nil {
+		return nil, nil, fmt.Errorf("%w: certificate key does not match the agent's registered key", ErrClientCertificateRejected)
+	}
+	registered, err := crypto.ParsePublicKey(agent.KeyAlgorithm, *agent.PublicKey)
+	if err != nil || !registered.(interface {
+		Equal(stdcrypto.PublicKey) bool
+	}).Equal(certificate.PublicKey) {
+		return nil, nil, fmt.Errorf("%w: certificate key does not match the agent's registered key", ErrClientCertificateRejected)
+	}
+
+	return agent, issued, nil
+}
+
+// CheckRevocation fails with domain.ErrAgentCertificateRevoked when the latest certificate bound
+// to the public key was revoked for a reason other than being superseded by a newer certificate
+func (s *AgentCertificateService) CheckRevocation(ctx context.Context, agent *domain.Agent, publicKey string) error {
+	certificates, err := s.certificateRepo.GetByAgent(agent.ID)
+	if err != nil {
+		return err
+	}
+	for _, certificate := range certificates {
+		if certificate.PublicKey != publicKey {
+			continue
+		}
+		if certificate.RevokedAt != nil && certificate.RevocationReason != nil &&
+			*certificate.RevocationReason != domain.CertificateRevocationSuperseded {
+			return fmt.Errorf("%w: certificate %s revoked (%s)", domain.ErrAgentCertificateRevoked, certificate.SerialNumber, *certificate.RevocationReason)
+		}
+		return nil
+	}
+	return nil
+}
+
+// CRL returns the DER-encoded certificate revocation list of the unexpired revoked certificates
+func (s *AgentCertificateService) CRL(ctx context.Context) ([]byte, error) {
+	now := s.now().UTC()
+	revoked, err := s.certificateRepo.ListRevoked(now)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]x509.RevocationListEntry, 0, len(revoked))
+	for _, certificate := range revoked {
+		serial, ok := new(big.Int).SetString(certificate.SerialNumber, 16)
+		if !ok {
+			continue
+		}
+		entry := x509.RevocationListEntry{SerialNumber: serial, RevocationTime: *certificate.RevokedAt}
+		if certificate.RevocationReason != nil {
+			entry.ReasonCode = certificate.RevocationReason.ReasonCode()
+		}
+		entries = append(entries, entry)
+	}
+
+	return s.ca.CreateCRL(entries, big.NewInt(now.Unix()), now, now.Add(agentCRLValidity))
+}
@@ -0,0 +1,106 @@
+package application
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentCertificatesFollowVerificationAndRevocation(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	ctx := context.Background()
+
+	keyPair, err := crypto.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	publicKey := crypto.EncodeKeyPair(keyPair).PublicKeyBase64
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) {
+		a.Status = domain.AgentStatusPending
+		a.PublicKey = &publicKey
+	})
+	require.NoError(t, repos.Agent.Create(agent))
+
+	ca, err := crypto.NewAgentCA(make([]byte, 32))
+	require.NoError(t, err)
+	certificates := NewAgentCertificateService(repos.AgentCertificate, repos.Agent, ca, time.Hour, "https://aim.example.com")
+	trustCalc := NewTrustCalculator(repos.TrustScore, repos.APIKey, repos.AuditLog, repos.Capability, repos.Agent, repos.Alert)
+	agentService := NewAgentService(repos.Agent, trustCalc, repos.TrustScore, nil, repos.Alert, nil, repos.Capability, nil, nil, repos.Organization, nil, certificates, nil, nil)
+
+	// Unverified agents get no certificate
+	_, err = certificates.GetCurrent(ctx, org.ID, agent.ID)
+	assert.ErrorIs(t, err, domain.ErrAgentCertificateNotFound)
+	_, err = certificates.GetCurrent(ctx, uuid.New(), agent.ID)
+	assert.ErrorIs(t, err, ErrCertificateAgentNotFound)
+
+	// Verification issues a certificate bound to the agent's key, signed by the CA
+	_, err = agentService.VerifyAgent(ctx, agent.ID, uuid.New())
+	require.NoError(t, err)
+	issued, err := certificates.GetCurrent(ctx, org.ID, agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, issued.PublicKey)
+	cert, err := ca.Verify([]byte(issued.CertificatePEM), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, agent.Name, cert.Subject.CommonName)
+	assert.Equal(t, []string{"https://aim.example.com/api/v1/public/agent-ca/crl"}, cert.CRLDistributionPoints)
+	assert.Equal(t, keyPair.PublicKey, cert.PublicKey)
+	stored, err := repos.Agent.GetByID(agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://aim.example.com/api/v1/agents/"+agent.ID.String()+"/certificate", stored.CertificateURL)
+
+	again, err := certificates.GetCurrent(ctx, org.ID, agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, issued.SerialNumber, again.SerialNumber, "a fresh certificate is not renewed")
+	require.NoError(t, agentService.CheckCertificateRevocation(ctx, stored, publicKey))
+
+	// Suspension revokes the certificate; the key stops verifying and the CRL lists it
+	require.NoError(t, agentService.SuspendAgent(ctx, agent.ID))
+	assert.ErrorIs(t, agentService.CheckCertificateRevocation(ctx, stored, publicKey), domain.ErrAgentCertificateRevoked)
+	status, err := certificates.GetBySerialNumber(ctx, issued.SerialNumber)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AgentCertificateStatusRevoked, status.Status(time.Now()))
+
+	der, err := certificates.CRL(ctx)
+	require.NoError(t, err)
+	crl, err := x509.ParseRevocationList(der)
+	require.NoError(t, err)
+	require.NoError(t, crl.CheckSignatureFrom(ca.Certificate()))
+	require.Len(t, crl.RevokedCertificateEntries, 1)
+	assert.Equal(t, issued.SerialNumber, crl.RevokedCertificateEntries[0].SerialNumber.Text(16))
+	assert.Equal(t, 5, crl.RevokedCertificateEntries[0].ReasonCode)
+
+	// Reactivation issues a new certificate for the same key, which verifies again
+	require.NoError(t, agentService.ReactivateAgent(ctx, agent.ID))
+	renewed, err := certificates.GetCurrent(ctx, org.ID, agent.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, issued.SerialNumber, renewed.SerialNumber)
+	assert.NoError(t, agentService.CheckCertificateRevocation(ctx, stored, publicKey))
+
+	// A key rotation supersedes the certificate; the previous key is not blocked during its grace period
+	rotatedKeyPair, err := crypto.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	rotatedKey := crypto.EncodeKeyPair(rotatedKeyPair).PublicKeyBase64
+	_, err = agentService.RotateKey(ctx, agent.ID, &RotateKeyRequest{PublicKey: rotatedKey})
+	require.NoError(t, err)
+	rotated, err := certificates.GetCurrent(ctx, org.ID, agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, rotatedKey, rotated.PublicKey)
+	assert.NoError(t, agentService.CheckCertificateRevocation(ctx, stored, publicKey))
+
+	// Compromise revokes every certificate for good
+	compromise := NewCompromiseResponseService(repos.CompromiseResponse, repos.Agent, nil, nil, nil, nil, nil, nil, nil, nil, certificates)
+	response, err := compromise.MarkAsCompromised(ctx, agent.ID, domain.CompromiseTriggerManual, "leaked key", nil)
+	require.NoError(t, err)
+	assert.Contains(t, response.Actions, domain.CompromiseAction{
+		Action: domain.CompromiseActionRevokeCertificates, Status: domain.CompromiseActionCompleted, Count: 1, Detail: "Revoked 1 certificate(s)",
+	})
+	assert.ErrorIs(t, agentService.CheckCertificateRevocation(ctx, stored, rotatedKey), domain.ErrAgentCertificateRevoked)
+}
@@ -0,0 +1,224 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// agentCredentialLifetime is how long an agent credential is valid after issuance. Credentials
+// are checked offline, so a short lifetime bounds how long a revoked agent's credential is honored.
+const agentCredentialLifetime = 24 * time.Hour
+
+var (
+	// ErrCredentialAgentNotFound is returned when the agent to issue a credential to is not in the organization
+	ErrCredentialAgentNotFound = errors.New("agent not found")
+	// ErrAgentNotCredentialable is returned when the agent is not verified, or is compromised
+	ErrAgentNotCredentialable = errors.New("credentials are only issued to verified agents that are not compromised")
+)
+
+// agentCredentialClaims are the claims of a VC-JWT: the registered claims mirror the credential's
+// id, issuer, subject and validity, and vc carries the credential itself
+type agentCredentialClaims struct {
+	jwt.RegisteredClaims
+	VC *domain.VerifiableCredential `json:"vc"`
+}
+
+// AgentCredentialService issues W3C verifiable credentials to agents and checks credentials
+// presented to third parties. Credentials are signed with the deployment's key, published in the
+// DID document of the issuer DID, so third parties can check them without calling the API.
+type AgentCredentialService struct {
+	agentRepo      domain.AgentRepository
+	capabilityRepo domain.CapabilityRepository
+	signer         *crypto.AgentCredentialSigner
+	issuer         string // did:web of the deployment
+	now            func() time.Time
+}
+
+// NewAgentCredentialService creates a new agent credential service; the issuer DID is the did:web
+// of publicURL's host
+func NewAgentCredentialService(
+	agentRepo domain.AgentRepository,
+	capabilityRepo domain.CapabilityRepository,
+	signer *crypto.AgentCredentialSigner,
+	publicURL string,
+) (*AgentCredentialService, error) {
+	issuer, err := didWeb(publicURL)
+	if err != nil {
+		return nil, err
+	}
+	return &AgentCredentialService{
+		agentRepo:      agentRepo,
+		capabilityRepo: capabilityRepo,
+		signer:         signer,
+		issuer:         issuer,
+		now:            time.Now,
+	}, nil
+}
+
+// didWeb returns the did:web identifying the host of publicURL; the DID document is served
+// from /.well-known/did.json at the root of the host
+func didWeb(publicURL string) (string, error) {
+	u, err := url.Parse(publicURL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid public URL for credential issuer: %q", publicURL)
+	}
+	return "did:web:" + strings.ReplaceAll(u.Host, ":", "%3A"), nil
+}
+
+// Issuer returns the DID credentials are issued under
+func (s *AgentCredentialService) Issuer() string {
+	return s.issuer
+}
+
+func (s *AgentCredentialService) keyRef() string {
+	return s.issuer + "#" + domain.AgentCredentialKeyFrag
+}
+
+// DIDDocument returns the document the issuer DID resolves to, carrying the signing key
+func (s *AgentCredentialService) DIDDocument() *domain.DIDDocument {
+	return &domain.DIDDocument{
+		Context: []string{domain.DIDContextV1, domain.JWS2020ContextV1},
+		ID:      s.issuer,
+		VerificationMethod: []domain.DIDVerificationMethod{{
+			ID:         s.keyRef(),
+			Type:       domain.JSONWebKey2020Type,
+			Controller: s.issuer,
+			PublicKeyJWK: map[string]string{
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"x":   base64.RawURLEncoding.EncodeToString(s.signer.PublicKey()),
+				"kid": s.signer.KeyID(),
+			},
+		}},
+		AssertionMethod: []string{s.keyRef()},
+	}
+}
+
+// Issue signs a credential asserting the agent's current identity, verification status, trust
+// score band and granted capabilities
+func (s *AgentCredentialService) Issue(ctx context.Context, orgID, agentID uuid.UUID) (*domain.IssuedAgentCredential, error) {
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil || agent.OrganizationID != orgID {
+		return nil, ErrCredentialAgentNotFound
+	}
+	if agent.Status != domain.AgentStatusVerified || agent.IsCompromised {
+		return nil, ErrAgentNotCredentialable
+	}
+
+	capabilities, err := s.capabilities(agent)
+	if err != nil {
+		return nil, err
+	}
+
+	issuedAt := s.now().UTC().Truncate(time.Second)
+	credential := &domain.VerifiableCredential{
+		Context:        []string{domain.CredentialsContextV1},
+		ID:             "urn:uuid:" + uuid.New().String(),
+		Type:           []string{domain.VerifiableCredentialType, domain.AgentIdentityCredentialType},
+		Issuer:         s.issuer,
+		IssuanceDate:   issuedAt,
+		ExpirationDate: issuedAt.Add(agentCredentialLifetime),
+		CredentialSubject: domain.AgentCredentialSubject{
+			ID:                 "urn:uuid:" + agent.ID.String(),
+			Name:               agent.Name,
+			DisplayName:        agent.DisplayName,
+			OrganizationID:     agent.OrganizationID,
+			AgentType:          agent.AgentType,
+			VerificationStatus: agent.Status,
+			VerifiedAt:         agent.VerifiedAt,
+			TrustScoreBand:     domain.TrustTierOf(agent.TrustScore),
+			Capabilities:       capabilities,
+		},
+	}
+	if agent.PublicKey != nil {
+		credential.CredentialSubject.PublicKey = *agent.PublicKey
+	}
+
+	signed, err := s.signer.SignJWT(&agentCredentialClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        credential.ID,
+			Issuer:    credential.Issuer,
+			Subject:   credential.CredentialSubject.ID,
+			IssuedAt:  jwt.NewNumericDate(credential.IssuanceDate),
+			NotBefore: jwt.NewNumericDate(credential.IssuanceDate),
+			ExpiresAt: jwt.NewNumericDate(credential.ExpirationDate),
+		},
+		VC: credential,
+	}, s.keyRef())
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.IssuedAgentCredential{JWT: signed, Credential: credential}, nil
+}
+
+// capabilities returns the capability types granted to the agent and not revoked, falling back
+// to the capabilities the agent declared at registration
+func (s *AgentCredentialService) capabilities(agent *domain.Agent) ([]string, error) {
+	granted, err := s.capabilityRepo.GetActiveCapabilitiesByAgentID(agent.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent capabilities: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	capabilities := make([]string, 0, len(granted))
+	for _, capability := range granted {
+		if !seen[capability.CapabilityType] {
+			seen[capability.CapabilityType] = true
+			capabilities = append(capabilities, capability.CapabilityType)
+		}
+	}
+	if len(capabilities) == 0 {
+		capabilities = append(capabilities, agent.Capabilities...)
+	}
+	sort.Strings(capabilities)
+	return capabilities, nil
+}
+
+// Verify checks a presented credential: its signature, its validity period, and that the agent
+// has not since been revoked, suspended or marked compromised. A credential that fails a check is
+// reported invalid with the reason, not as an error.
+func (s *AgentCredentialService) Verify(ctx context.Context, signed string) *domain.AgentCredentialVerification {
+	result := &domain.AgentCredentialVerification{CheckedAt: s.now().UTC()}
+
+	claims := &agentCredentialClaims{}
+	err := s.signer.ParseJWT(signed, claims, jwt.WithIssuer(s.issuer), jwt.WithTimeFunc(s.now))
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		result.Reason = "credential has expired"
+		return result
+	case err != nil || claims.VC == nil:
+		result.Reason = "credential is not signed by this issuer"
+		return result
+	}
+	result.Credential = claims.VC
+
+	agentID, err := uuid.Parse(strings.TrimPrefix(claims.Subject, "urn:uuid:"))
+	if err != nil {
+		result.Reason = "credential subject is not an agent"
+		return result
+	}
+	agent, err := s.agentRepo.GetByID(agentID)
+	switch {
+	case err != nil:
+		result.Reason = "agent no longer exists"
+	case agent.IsCompromised:
+		result.Reason = "agent has been marked compromised"
+	case agent.Status != domain.AgentStatusVerified:
+		result.Reason = fmt.Sprintf("agent is %s", agent.Status)
+	default:
+		result.Valid = true
+	}
+	return result
+}
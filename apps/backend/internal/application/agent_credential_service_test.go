@@ -0,0 +1,93 @@
+package application
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentCredentialsVerifyOfflineAndStopVerifyingOnceTheAgentIsRevoked(t *testing.T) {
+	ctx := context.Background()
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.TrustScore = 0.92 })
+	require.NoError(t, repos.Agent.Create(agent))
+	require.NoError(t, repos.Capability.CreateCapability(&domain.AgentCapability{AgentID: agent.ID, CapabilityType: "file:read"}))
+	require.NoError(t, repos.Capability.CreateCapability(&domain.AgentCapability{AgentID: agent.ID, CapabilityType: "api:call"}))
+
+	seed := make([]byte, ed25519.SeedSize)
+	_, err := rand.Read(seed)
+	require.NoError(t, err)
+	signer, err := crypto.NewAgentCredentialSigner(seed)
+	require.NoError(t, err)
+	service, err := NewAgentCredentialService(repos.Agent, repos.Capability, signer, "https://aim.example.com:8443/api")
+	require.NoError(t, err)
+	assert.Equal(t, "did:web:aim.example.com%3A8443", service.Issuer())
+
+	_, err = service.Issue(ctx, uuid.New(), agent.ID)
+	assert.ErrorIs(t, err, ErrCredentialAgentNotFound)
+
+	issued, err := service.Issue(ctx, org.ID, agent.ID)
+	require.NoError(t, err)
+	subject := issued.Credential.CredentialSubject
+	assert.Equal(t, []string{domain.VerifiableCredentialType, domain.AgentIdentityCredentialType}, issued.Credential.Type)
+	assert.Equal(t, "urn:uuid:"+agent.ID.String(), subject.ID)
+	assert.Equal(t, domain.AgentStatusVerified, subject.VerificationStatus)
+	assert.Equal(t, domain.TrustTierExcellent, subject.TrustScoreBand)
+	assert.Equal(t, []string{"api:call", "file:read"}, subject.Capabilities)
+
+	// A third party checks the signature with the key of the issuer's DID document alone
+	document := service.DIDDocument()
+	require.Len(t, document.VerificationMethod, 1)
+	publicKey, err := base64.RawURLEncoding.DecodeString(document.VerificationMethod[0].PublicKeyJWK["x"])
+	require.NoError(t, err)
+	parts := strings.Split(issued.JWT, ".")
+	require.Len(t, parts, 3)
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	assert.True(t, ed25519.Verify(publicKey, []byte(parts[0]+"."+parts[1]), signature))
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims struct {
+		Issuer string                      `json:"iss"`
+		VC     domain.VerifiableCredential `json:"vc"`
+	}
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	assert.Equal(t, service.Issuer(), claims.Issuer)
+	assert.Equal(t, issued.Credential.ID, claims.VC.ID)
+
+	verification := service.Verify(ctx, issued.JWT)
+	assert.True(t, verification.Valid, verification.Reason)
+	require.NotNil(t, verification.Credential)
+	assert.Equal(t, subject.Name, verification.Credential.CredentialSubject.Name)
+
+	// Tampered credentials and credentials of other issuers are rejected
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(string(payload), "excellent", "good", 1))) + "." + parts[2]
+	assert.False(t, service.Verify(ctx, tampered).Valid)
+	otherSigner, err := crypto.NewAgentCredentialSigner(make([]byte, ed25519.SeedSize))
+	require.NoError(t, err)
+	other, err := NewAgentCredentialService(repos.Agent, repos.Capability, otherSigner, "https://aim.example.com:8443")
+	require.NoError(t, err)
+	assert.False(t, other.Verify(ctx, issued.JWT).Valid)
+
+	// Once the agent is revoked its credentials no longer verify, and it gets no new ones
+	agent.Status = domain.AgentStatusRevoked
+	require.NoError(t, repos.Agent.Update(agent))
+	verification = service.Verify(ctx, issued.JWT)
+	assert.False(t, verification.Valid)
+	assert.Equal(t, "agent is revoked", verification.Reason)
+	_, err = service.Issue(ctx, org.ID, agent.ID)
+	assert.ErrorIs(t, err, ErrAgentNotCredentialable)
+}
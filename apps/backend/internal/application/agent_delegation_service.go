@@ -0,0 +1,328 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrDelegationAgentNotFound is returned when the agent is not in the organization
+	ErrDelegationAgentNotFound = errors.New("agent not found")
+	// ErrDelegationNotAllowed is returned when the parent agent may not delegate: it is not
+	// verified, is compromised or revoked, or sits at the maximum delegation depth
+	ErrDelegationNotAllowed = errors.New("agent may not delegate")
+	// ErrDelegationScopeExceeded is returned when a child would get a capability or MCP server
+	// its parent doesn't have
+	ErrDelegationScopeExceeded = errors.New("delegated scope exceeds the parent's")
+	// ErrAgentRevoked is returned when verifying or reactivating a revoked agent; revocation is final
+	ErrAgentRevoked = errors.New("agent is revoked")
+)
+
+// AgentDelegationService lets agents spawn sub-agents with a scoped identity and revokes
+// delegation trees as a whole
+type AgentDelegationService struct {
+	delegationRepo domain.AgentDelegationRepository
+	agentRepo      domain.AgentRepository
+	capabilityRepo domain.CapabilityRepository
+	agentService   *AgentService
+}
+
+// NewAgentDelegationService creates a new agent delegation service
+func NewAgentDelegationService(
+	delegationRepo domain.AgentDelegationRepository,
+	agentRepo domain.AgentRepository,
+	capabilityRepo domain.CapabilityRepository,
+	agentService *AgentService,
+) *AgentDelegationService {
+	return &AgentDelegationService{
+		delegationRepo: delegationRepo,
+		agentRepo:      agentRepo,
+		capabilityRepo: capabilityRepo,
+		agentService:   agentService,
+	}
+}
+
+// DelegateAgentRequest asks for a child identity of the parent agent. Capabilities and TalksTo
+// must be covered by the parent's; the child gets none of the parent's scope implicitly.
+type DelegateAgentRequest struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+	PublicKey   string `json:"publicKey,omitempty"`
+	// Algorithm of publicKey; detected from the key when omitted
+	KeyAlgorithm string `json:"keyAlgorithm,omitempty"`
+	// Base64 signature of crypto.ProofOfPossessionMessage(name, publicKey)
+	ProofOfPossession string   `json:"proofOfPossession,omitempty"`
+	Capabilities      []string `json:"capabilities,omitempty"`
+	TalksTo           []string `json:"talksTo,omitempty"`
+	Purpose           string   `json:"purpose,omitempty"`
+}
+
+// AgentDelegationResult is a newly delegated child agent
+type AgentDelegationResult struct {
+	Agent      *domain.Agent           `json:"agent"`
+	Delegation *domain.AgentDelegation `json:"delegation"`
+}
+
+// AgentRevocation lists the agents a revocation revoked: the agent and every agent delegated
+// below it, parents before their children
+type AgentRevocation struct {
+	AgentID         uuid.UUID   `json:"agentId"`
+	RevokedAgentIDs []uuid.UUID `json:"revokedAgentIds"`
+	RevokedAt       time.Time   `json:"revokedAt"`
+}
+
+// Delegate creates a child agent of the parent. The child is registered like any agent, then
+// restricted to the requested scope and given the parent's trust score minus
+// domain.DelegationTrustPenalty. requestedBy is the user acting, or uuid.Nil when the parent
+// agent requested the child itself; the child is then owned by the parent's creator.
+func (s *AgentDelegationService) Delegate(
+	ctx context.Context,
+	orgID, parentID uuid.UUID,
+	req *DelegateAgentRequest,
+	requestedBy uuid.UUID,
+) (*AgentDelegationResult, error) {
+	parent, err := s.getAgent(orgID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.IsCompromised {
+		return nil, fmt.Errorf("%w: agent is compromised", ErrDelegationNotAllowed)
+	}
+	if parent.Status != domain.AgentStatusVerified {
+		return nil, fmt.Errorf("%w: agent is %s", ErrDelegationNotAllowed, parent.Status)
+	}
+
+	depth := 1
+	if parentDelegation, err := s.delegationRepo.GetByChild(parent.ID); err == nil {
+		depth = parentDelegation.Depth + 1
+	} else if !errors.Is(err, domain.ErrAgentDelegationNotFound) {
+		return nil, fmt.Errorf("failed to get parent delegation: %w", err)
+	}
+	if depth > domain.MaxDelegationDepth {
+		return nil, fmt.Errorf("%w: delegation chains are limited to %d levels", ErrDelegationNotAllowed, domain.MaxDelegationDepth)
+	}
+
+	if err := s.checkScope(parent, req); err != nil {
+		return nil, err
+	}
+
+	initiator := domain.InitiatorTypeUser
+	userID := requestedBy
+	if requestedBy == uuid.Nil {
+		initiator = domain.InitiatorTypeAgent
+		userID = parent.CreatedBy
+	}
+
+	child, err := s.agentService.CreateAgent(ctx, &CreateAgentRequest{
+		Name:              req.Name,
+		DisplayName:       req.DisplayName,
+		Description:       req.Description,
+		AgentType:         parent.AgentType,
+		Version:           req.Version,
+		PublicKey:         req.PublicKey,
+		KeyAlgorithm:      req.KeyAlgorithm,
+		ProofOfPossession: req.ProofOfPossession,
+		TalksTo:           req.TalksTo,
+		Capabilities:      req.Capabilities,
+	}, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	inheritedTrust := math.Max(0, parent.TrustScore-domain.DelegationTrustPenalty)
+	if err := s.agentRepo.UpdateTrustScore(child.ID, inheritedTrust); err != nil {
+		log.Printf("⚠️  Failed to set inherited trust score of delegated agent %s: %v", child.ID, err)
+	} else {
+		child.TrustScore = inheritedTrust
+	}
+
+	delegation := &domain.AgentDelegation{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		ParentAgentID:  parent.ID,
+		ChildAgentID:   child.ID,
+		Depth:          depth,
+		Capabilities:   nonNilStrings(req.Capabilities),
+		TalksTo:        nonNilStrings(req.TalksTo),
+		Purpose:        req.Purpose,
+		InheritedTrust: inheritedTrust,
+		RequestedBy:    initiator,
+		CreatedBy:      userID,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := s.delegationRepo.Create(delegation); err != nil {
+		// A child without its delegation would escape the parent's revocation; remove it
+		if deleteErr := s.agentService.DeleteAgent(ctx, child.ID); deleteErr != nil {
+			log.Printf("⚠️  Failed to remove agent %s after its delegation failed: %v", child.ID, deleteErr)
+		}
+		return nil, fmt.Errorf("failed to record delegation: %w", err)
+	}
+
+	return &AgentDelegationResult{Agent: child, Delegation: delegation}, nil
+}
+
+// checkScope fails with ErrDelegationScopeExceeded unless every requested capability is covered
+// by an active capability of the parent and every MCP server is one the parent talks to
+func (s *AgentDelegationService) checkScope(parent *domain.Agent, req *DelegateAgentRequest) error {
+	granted, err := s.capabilityRepo.GetActiveCapabilitiesByAgentID(parent.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get parent capabilities: %w", err)
+	}
+
+	var exceeded []string
+	for _, capability := range req.Capabilities {
+		covered := slices.ContainsFunc(granted, func(g *domain.AgentCapability) bool {
+			return s.agentService.matchesCapability(capability, "", g.CapabilityType)
+		})
+		if !covered {
+			exceeded = append(exceeded, "capability "+capability)
+		}
+	}
+	for _, server := range req.TalksTo {
+		if !slices.Contains(parent.TalksTo, server) {
+			exceeded = append(exceeded, "MCP server "+server)
+		}
+	}
+
+	if len(exceeded) > 0 {
+		return fmt.Errorf("%w: the parent doesn't have %s", ErrDelegationScopeExceeded, strings.Join(exceeded, ", "))
+	}
+	return nil
+}
+
+// GetChain returns the agent's place in its delegation tree
+func (s *AgentDelegationService) GetChain(ctx context.Context, orgID, agentID uuid.UUID) (*domain.AgentDelegationChain, error) {
+	if _, err := s.getAgent(orgID, agentID); err != nil {
+		return nil, err
+	}
+
+	chain := &domain.AgentDelegationChain{
+		AgentID: agentID,
+		Chain:   make([]*domain.AgentDelegation, 0),
+	}
+
+	// Walk up to the root; depth bounds the walk should the stored chain ever contain a cycle
+	current := agentID
+	for range domain.MaxDelegationDepth + 1 {
+		delegation, err := s.delegationRepo.GetByChild(current)
+		if errors.Is(err, domain.ErrAgentDelegationNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get delegation: %w", err)
+		}
+		chain.Chain = append(chain.Chain, delegation)
+		current = delegation.ParentAgentID
+	}
+	slices.Reverse(chain.Chain)
+	chain.RootAgentID = current
+	chain.Depth = len(chain.Chain)
+
+	children, err := s.delegationRepo.ListByParent(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delegated agents: %w", err)
+	}
+	chain.Children = children
+
+	return chain, nil
+}
+
+// Revoke revokes the agent and, transitively, every agent delegated below it. Revoked agents
+// fail verification and lose their certificates. Agents that were already revoked are skipped,
+// their children are not.
+func (s *AgentDelegationService) Revoke(ctx context.Context, orgID, agentID uuid.UUID, reason string) (*AgentRevocation, error) {
+	agent, err := s.getAgent(orgID, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	revocation := &AgentRevocation{
+		AgentID:         agent.ID,
+		RevokedAgentIDs: make([]uuid.UUID, 0),
+		RevokedAt:       time.Now().UTC(),
+	}
+	if err := s.revokeAgent(ctx, agent, revocation); err != nil {
+		return nil, err
+	}
+	if err := s.delegationRepo.MarkRevoked(agent.ID, revocation.RevokedAt, reason); err != nil {
+		return nil, fmt.Errorf("failed to record revocation: %w", err)
+	}
+
+	// Cascade breadth first, so every parent is revoked before its children
+	cascadeReason := fmt.Sprintf("Parent agent %s revoked", agent.Name)
+	queue := []uuid.UUID{agent.ID}
+	seen := map[uuid.UUID]bool{agent.ID: true}
+	for len(queue) > 0 {
+		delegations, err := s.delegationRepo.ListByParent(queue[0])
+		if err != nil {
+			return revocation, fmt.Errorf("failed to list delegated agents: %w", err)
+		}
+		queue = queue[1:]
+
+		for _, delegation := range delegations {
+			if seen[delegation.ChildAgentID] {
+				continue
+			}
+			seen[delegation.ChildAgentID] = true
+			queue = append(queue, delegation.ChildAgentID)
+
+			child, err := s.agentRepo.GetByID(delegation.ChildAgentID)
+			if err != nil {
+				log.Printf("⚠️  Failed to get delegated agent %s to revoke: %v", delegation.ChildAgentID, err)
+				continue
+			}
+			if err := s.revokeAgent(ctx, child, revocation); err != nil {
+				log.Printf("⚠️  Failed to revoke delegated agent %s: %v", child.ID, err)
+				continue
+			}
+			if err := s.delegationRepo.MarkRevoked(child.ID, revocation.RevokedAt, cascadeReason); err != nil {
+				log.Printf("⚠️  Failed to record revocation of delegated agent %s: %v", child.ID, err)
+			}
+		}
+	}
+
+	log.Printf("✅ Revoked agent %s and %d delegated agent(s)", agent.ID, max(len(revocation.RevokedAgentIDs)-1, 0))
+	return revocation, nil
+}
+
+// revokeAgent sets the agent's status to revoked and revokes its certificates
+func (s *AgentDelegationService) revokeAgent(ctx context.Context, agent *domain.Agent, revocation *AgentRevocation) error {
+	if agent.Status == domain.AgentStatusRevoked {
+		return nil
+	}
+	agent.Status = domain.AgentStatusRevoked
+	if err := s.agentRepo.Update(agent); err != nil {
+		return fmt.Errorf("failed to revoke agent: %w", err)
+	}
+	s.agentService.revokeCertificates(ctx, agent.ID, domain.CertificateRevocationCessationOfOperation)
+	revocation.RevokedAgentIDs = append(revocation.RevokedAgentIDs, agent.ID)
+	return nil
+}
+
+// getAgent returns the agent if it belongs to the organization
+func (s *AgentDelegationService) getAgent(orgID, agentID uuid.UUID) (*domain.Agent, error) {
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil || agent == nil || agent.OrganizationID != orgID {
+		return nil, ErrDelegationAgentNotFound
+	}
+	return agent, nil
+}
+
+// nonNilStrings returns values, or an empty slice when values is nil
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,124 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentDelegationScopesChildrenAndCascadesRevocation(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	user := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(user))
+	ctx := context.Background()
+
+	ca, err := crypto.NewAgentCA(make([]byte, 32))
+	require.NoError(t, err)
+	certificates := NewAgentCertificateService(repos.AgentCertificate, repos.Agent, ca, time.Hour, "https://aim.example.com")
+	trustCalc := NewTrustCalculator(repos.TrustScore, repos.APIKey, repos.AuditLog, repos.Capability, repos.Agent, repos.Alert)
+	agentService := NewAgentService(repos.Agent, trustCalc, repos.TrustScore, nil, repos.Alert, nil, repos.Capability, nil, nil, repos.Organization, nil, certificates, nil, nil)
+	service := NewAgentDelegationService(repos.AgentDelegation, repos.Agent, repos.Capability, agentService)
+
+	parent := testsupport.NewAgent(org.ID, func(a *domain.Agent) {
+		a.TrustScore = 0.9
+		a.TalksTo = []string{"filesystem-mcp", "postgres-mcp"}
+		a.CreatedBy = user.ID
+	})
+	require.NoError(t, repos.Agent.Create(parent))
+	require.NoError(t, repos.Capability.CreateCapability(testsupport.NewAgentCapability(parent, "file:read")))
+	require.NoError(t, repos.Capability.CreateCapability(testsupport.NewAgentCapability(parent, "db:*")))
+
+	childRequest := func(capabilities, talksTo []string) *DelegateAgentRequest {
+		keyPair, err := crypto.GenerateEd25519KeyPair()
+		require.NoError(t, err)
+		publicKey := crypto.EncodeKeyPair(keyPair).PublicKeyBase64
+		name := "sub-agent-" + uuid.NewString()[:8]
+		return &DelegateAgentRequest{
+			Name:              name,
+			DisplayName:       name,
+			Description:       "Delegated sub-agent",
+			PublicKey:         publicKey,
+			ProofOfPossession: base64.StdEncoding.EncodeToString(crypto.SignMessage(keyPair.PrivateKey, crypto.ProofOfPossessionMessage(name, publicKey))),
+			Capabilities:      capabilities,
+			TalksTo:           talksTo,
+		}
+	}
+
+	// The child's scope must be covered by the parent's
+	_, err = service.Delegate(ctx, org.ID, parent.ID, childRequest([]string{"file:write"}, nil), user.ID)
+	assert.ErrorIs(t, err, ErrDelegationScopeExceeded)
+	_, err = service.Delegate(ctx, org.ID, parent.ID, childRequest([]string{"file:read"}, []string{"slack-mcp"}), user.ID)
+	assert.ErrorIs(t, err, ErrDelegationScopeExceeded)
+	_, err = service.Delegate(ctx, uuid.New(), parent.ID, childRequest(nil, nil), user.ID)
+	assert.ErrorIs(t, err, ErrDelegationAgentNotFound)
+
+	child, err := service.Delegate(ctx, org.ID, parent.ID, childRequest([]string{"file:read", "db:query"}, []string{"postgres-mcp"}), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, child.Delegation.Depth)
+	assert.Equal(t, domain.InitiatorTypeUser, child.Delegation.RequestedBy)
+	assert.InDelta(t, 0.8, child.Agent.TrustScore, 0.0001)
+	stored, err := repos.Agent.GetByID(child.Agent.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.8, stored.TrustScore, 0.0001)
+	assert.Equal(t, domain.AgentStatusVerified, stored.Status)
+	granted, err := repos.Capability.GetActiveCapabilitiesByAgentID(child.Agent.ID)
+	require.NoError(t, err)
+	assert.Len(t, granted, 2)
+
+	// The child requests a grandchild itself; the penalty compounds and the parent's creator owns it
+	grandchildRequest := childRequest([]string{"db:query"}, nil)
+	_, err = service.Delegate(ctx, org.ID, child.Agent.ID, childRequest([]string{"db:write"}, nil), uuid.Nil)
+	assert.ErrorIs(t, err, ErrDelegationScopeExceeded, "db:* of the root does not reach past the child")
+	grandchild, err := service.Delegate(ctx, org.ID, child.Agent.ID, grandchildRequest, uuid.Nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, grandchild.Delegation.Depth)
+	assert.Equal(t, domain.InitiatorTypeAgent, grandchild.Delegation.RequestedBy)
+	assert.Equal(t, user.ID, grandchild.Agent.CreatedBy)
+	assert.InDelta(t, 0.7, grandchild.Agent.TrustScore, 0.0001)
+
+	chain, err := service.GetChain(ctx, org.ID, grandchild.Agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, parent.ID, chain.RootAgentID)
+	assert.Equal(t, 2, chain.Depth)
+	require.Len(t, chain.Chain, 2)
+	assert.Equal(t, parent.ID, chain.Chain[0].ParentAgentID)
+	assert.Equal(t, grandchild.Agent.ID, chain.Chain[1].ChildAgentID)
+	chain, err = service.GetChain(ctx, org.ID, child.Agent.ID)
+	require.NoError(t, err)
+	require.Len(t, chain.Children, 1)
+	assert.Equal(t, grandchild.Agent.ID, chain.Children[0].ChildAgentID)
+
+	// Revoking the root revokes the whole tree, parents first
+	revocation, err := service.Revoke(ctx, org.ID, parent.ID, "decommissioned")
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{parent.ID, child.Agent.ID, grandchild.Agent.ID}, revocation.RevokedAgentIDs)
+	for _, id := range revocation.RevokedAgentIDs {
+		agent, err := repos.Agent.GetByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.AgentStatusRevoked, agent.Status)
+	}
+	revoked, err := repos.Agent.GetByID(grandchild.Agent.ID)
+	require.NoError(t, err)
+	assert.ErrorIs(t, agentService.CheckCertificateRevocation(ctx, revoked, grandchildRequest.PublicKey), domain.ErrAgentCertificateRevoked)
+	delegation, err := repos.AgentDelegation.GetByChild(grandchild.Agent.ID)
+	require.NoError(t, err)
+	require.NotNil(t, delegation.RevokedAt)
+	assert.Contains(t, delegation.RevocationReason, parent.Name)
+
+	allowed, _, _, err := agentService.VerifyAction(ctx, child.Agent.ID, "db:query", "orders", nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.ErrorIs(t, agentService.ReactivateAgent(ctx, child.Agent.ID), ErrAgentRevoked)
+	_, err = service.Delegate(ctx, org.ID, parent.ID, childRequest([]string{"file:read"}, nil), user.ID)
+	assert.ErrorIs(t, err, ErrDelegationNotAllowed)
+}
@@ -0,0 +1,148 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// DefaultKeyRotationGracePeriod is how long the previous key keeps verifying after a rotation
+	DefaultKeyRotationGracePeriod = 24 * time.Hour
+	// MaxKeyRotationGracePeriod bounds the grace period a caller may request
+	MaxKeyRotationGracePeriod = 30 * 24 * time.Hour
+	// agentKeyLifetime is how long a rotated key is valid before it expires
+	agentKeyLifetime = 365 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidKeyRotation is returned when a key rotation request is malformed
+	ErrInvalidKeyRotation = errors.New("invalid key rotation request")
+	// ErrPrivateKeyNotEscrowed is returned when an agent's private key is held only by the agent
+	ErrPrivateKeyNotEscrowed = errors.New("agent private key is not held by the platform")
+)
+
+// RotateKeyRequest rotates an agent's key. Without a public key AIM generates a new Ed25519
+// keypair and returns the private key once; with one, the agent keeps its private key.
+// Organizations that generate keys client-side must supply the public key and its proof of possession.
+// The new key may use another algorithm than the current one; the previous key keeps verifying
+// with its own algorithm during the grace period.
+type RotateKeyRequest struct {
+	PublicKey          string `json:"publicKey"`
+	KeyAlgorithm       string `json:"keyAlgorithm"`       // Algorithm of publicKey; detected from the key when omitted
+	ProofOfPossession  string `json:"proofOfPossession"`  // Base64 signature of crypto.ProofOfPossessionMessage(agent name, publicKey)
+	GracePeriodMinutes *int   `json:"gracePeriodMinutes"` // Defaults to 24 hours; 0 revokes the previous key at once
+}
+
+// KeyRotationResult is the outcome of a key rotation
+type KeyRotationResult struct {
+	Agent      *domain.Agent
+	PrivateKey string // Only set when AIM generated the keypair
+}
+
+// RotateKey replaces the agent's key and lets signatures from the previous key verify until
+// the grace period ends. Compromised agents get no grace period.
+func (s *AgentService) RotateKey(ctx context.Context, agentID uuid.UUID, req *RotateKeyRequest) (*KeyRotationResult, error) {
+	grace := DefaultKeyRotationGracePeriod
+	if req.GracePeriodMinutes != nil {
+		grace = time.Duration(*req.GracePeriodMinutes) * time.Minute
+		if grace < 0 || grace > MaxKeyRotationGracePeriod {
+			return nil, fmt.Errorf("%w: gracePeriodMinutes must be between 0 and %d", ErrInvalidKeyRotation, int(MaxKeyRotationGracePeriod/time.Minute))
+		}
+	}
+
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("agent not found: %w", err)
+	}
+	if agent.IsCompromised {
+		grace = 0
+	}
+
+	clientSideKeys := false
+	var org *domain.Organization
+	if s.orgRepo != nil {
+		org, err = s.orgRepo.GetByID(agent.OrganizationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get organization: %w", err)
+		}
+		clientSideKeys = org.ClientSideKeyGeneration
+	}
+
+	result := &KeyRotationResult{Agent: agent}
+	keyAlgorithm := domain.KeyAlgorithmEd25519
+	if req.PublicKey != "" {
+		keyAlgorithm, err = publicKeyAlgorithm(req.KeyAlgorithm, req.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: publicKey must be a base64-encoded %s public key: %v", ErrInvalidKeyRotation, strings.Join(domain.KeyAlgorithms, ", "), err)
+		}
+		if org != nil && !org.AllowsKeyAlgorithm(keyAlgorithm) {
+			return nil, fmt.Errorf("%w: %w: %s; allowed algorithms are %s", ErrInvalidKeyRotation, domain.ErrKeyAlgorithmNotAllowed, keyAlgorithm, strings.Join(org.AllowedKeyAlgorithms(), ", "))
+		}
+		if agent.PublicKey != nil && *agent.PublicKey == req.PublicKey {
+			return nil, fmt.Errorf("%w: publicKey is already the agent's current key", ErrInvalidKeyRotation)
+		}
+		if s.sharedKeys != nil {
+			if err := s.sharedKeys.CheckPublicKey(ctx, agent.OrganizationID, agent.ID, req.PublicKey); errors.Is(err, ErrPublicKeyInUse) {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidKeyRotation, err)
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		if req.ProofOfPossession != "" {
+			if err := crypto.VerifyProofOfPossession(keyAlgorithm, agent.Name, req.PublicKey, req.ProofOfPossession); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidKeyRotation, err)
+			}
+		} else if clientSideKeys {
+			return nil, fmt.Errorf("%w: the organization generates agent keys client-side; proofOfPossession is required", ErrInvalidKeyRotation)
+		}
+		publicKey := req.PublicKey
+		agent.PublicKey = &publicKey
+		agent.EncryptedPrivateKey = nil // The agent holds the private key of a supplied keypair
+	} else {
+		if clientSideKeys {
+			return nil, fmt.Errorf("%w: the organization generates agent keys client-side; supply a publicKey and proofOfPossession", ErrInvalidKeyRotation)
+		}
+		if algorithm, _ := domain.NormalizeKeyAlgorithm(req.KeyAlgorithm); algorithm != domain.KeyAlgorithmEd25519 {
+			return nil, fmt.Errorf("%w: keys generated by AIM are Ed25519; supply the publicKey of your %s key pair", ErrInvalidKeyRotation, req.KeyAlgorithm)
+		}
+		if org != nil && !org.AllowsKeyAlgorithm(domain.KeyAlgorithmEd25519) {
+			return nil, fmt.Errorf("%w: %w: keys generated by AIM are Ed25519; supply the publicKey of a %s key pair", ErrInvalidKeyRotation, domain.ErrKeyAlgorithmNotAllowed, strings.Join(org.AllowedKeyAlgorithms(), " or "))
+		}
+		if s.keyVault == nil {
+			return nil, fmt.Errorf("key vault is not configured; supply a publicKey instead")
+		}
+		keyPair, err := crypto.GenerateEd25519KeyPair()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate new cryptographic keys: %w", err)
+		}
+		encodedKeys := crypto.EncodeKeyPair(keyPair)
+		encryptedPrivateKey, err := s.keyVault.EncryptPrivateKey(encodedKeys.PrivateKeyBase64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt private key: %w", err)
+		}
+		agent.PublicKey = &encodedKeys.PublicKeyBase64
+		agent.EncryptedPrivateKey = &encryptedPrivateKey
+		result.PrivateKey = encodedKeys.PrivateKeyBase64
+	}
+
+	now := time.Now()
+	keyExpiry := now.Add(agentKeyLifetime)
+	graceUntil := now.Add(grace)
+	agent.KeyAlgorithm = keyAlgorithm
+	agent.KeyCreatedAt = &now
+	agent.KeyExpiresAt = &keyExpiry
+	agent.KeyRotationGraceUntil = &graceUntil
+
+	if err := s.agentRepo.RotateKey(agent); err != nil {
+		return nil, fmt.Errorf("failed to update agent credentials: %w", err)
+	}
+	s.reissueCertificate(ctx, agent)
+	return result, nil
+}
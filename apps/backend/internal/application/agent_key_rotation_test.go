@@ -0,0 +1,188 @@
+package application
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentKeyRotationGracePeriod(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	ctx := context.Background()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+
+	masterKey := make([]byte, 32)
+	_, err := rand.Read(masterKey)
+	require.NoError(t, err)
+	vault, err := crypto.NewKeyVault(base64.StdEncoding.EncodeToString(masterKey))
+	require.NoError(t, err)
+
+	newPublicKey := func() string {
+		keyPair, err := crypto.GenerateEd25519KeyPair()
+		require.NoError(t, err)
+		return crypto.EncodeKeyPair(keyPair).PublicKeyBase64
+	}
+	originalKey := newPublicKey()
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.PublicKey = &originalKey })
+	require.NoError(t, repos.Agent.Create(agent))
+
+	agentService := NewAgentService(repos.Agent, nil, repos.TrustScore, vault, repos.Alert, nil, repos.Capability, nil, nil, repos.Organization, nil, nil, nil, nil)
+
+	// Generated keypair with the default grace period
+	result, err := agentService.RotateKey(ctx, agent.ID, &RotateKeyRequest{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.PrivateKey)
+	stored, err := repos.Agent.GetByID(agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.RotationCount)
+	assert.Equal(t, originalKey, *stored.PreviousPublicKey)
+	generatedKey := *stored.PublicKey
+	now := time.Now()
+	assert.True(t, stored.AcceptsPublicKey(generatedKey, now))
+	assert.True(t, stored.AcceptsPublicKey(originalKey, now), "the previous key verifies during the grace period")
+	assert.False(t, stored.AcceptsPublicKey(originalKey, now.Add(DefaultKeyRotationGracePeriod+time.Minute)))
+
+	// Supplied public key without a grace period: the previous key stops verifying at once
+	suppliedKey, noGrace := newPublicKey(), 0
+	result, err = agentService.RotateKey(ctx, agent.ID, &RotateKeyRequest{PublicKey: suppliedKey, GracePeriodMinutes: &noGrace})
+	require.NoError(t, err)
+	assert.Empty(t, result.PrivateKey)
+	stored, err = repos.Agent.GetByID(agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.RotationCount)
+	assert.Nil(t, stored.EncryptedPrivateKey)
+	assert.Equal(t, []string{suppliedKey}, stored.VerificationPublicKeys(time.Now()))
+	assert.False(t, stored.AcceptsPublicKey(originalKey, time.Now()))
+
+	tooLong := int(MaxKeyRotationGracePeriod/time.Minute) + 1
+	for _, req := range []*RotateKeyRequest{
+		{PublicKey: suppliedKey},
+		{PublicKey: "not-a-key"},
+		{GracePeriodMinutes: &tooLong},
+	} {
+		_, err := agentService.RotateKey(ctx, agent.ID, req)
+		assert.ErrorIs(t, err, ErrInvalidKeyRotation)
+	}
+}
+
+func TestAgentsSwitchKeyAlgorithmsUnderOrganizationPolicy(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	ctx := context.Background()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	admin := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(admin))
+
+	admins := NewAdminService(repos.User, repos.Organization, repos.Agent)
+	agentService := NewAgentService(repos.Agent, nil, repos.TrustScore, nil, repos.Alert, nil, repos.Capability, nil, nil, repos.Organization, nil, nil, nil, nil)
+	allow := func(algorithms ...string) {
+		_, err := admins.UpdateOrganizationSettings(ctx, org.ID, &UpdateOrganizationSettingsRequest{AllowedKeyAlgorithms: &algorithms})
+		require.NoError(t, err)
+	}
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecdsaDER, err := x509.MarshalPKIXPublicKey(&ecdsaKey.PublicKey)
+	require.NoError(t, err)
+	ecdsaPublicKey := base64.StdEncoding.EncodeToString(ecdsaDER)
+	signECDSA := func(message []byte) []byte {
+		digest := sha256.Sum256(message)
+		signature, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, digest[:])
+		require.NoError(t, err)
+		return signature
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	rsaPublicKey := base64.StdEncoding.EncodeToString(rsaDER)
+
+	// Settings take any spelling of the supported algorithms and refuse others
+	allow("ed25519", "ES256")
+	stored, err := repos.Organization.GetByID(org.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.KeyAlgorithmEd25519, domain.KeyAlgorithmECDSAP256}, stored.AllowedKeyAlgorithms())
+	unknown := []string{"DSA"}
+	_, err = admins.UpdateOrganizationSettings(ctx, org.ID, &UpdateOrganizationSettingsRequest{AllowedKeyAlgorithms: &unknown})
+	assert.ErrorIs(t, err, ErrInvalidOrganizationSettings)
+
+	// Registrations detect the algorithm of the key and check it against the policy
+	register := func(name, publicKey, algorithm string) (*domain.Agent, *RegistrationValidation) {
+		agent, validation, err := agentService.ValidateAgentRegistration(ctx, &CreateAgentRequest{
+			Name: name, DisplayName: name, AgentType: domain.AgentTypeAI, PublicKey: publicKey, KeyAlgorithm: algorithm,
+		}, org.ID, admin.ID)
+		require.NoError(t, err)
+		return agent, validation
+	}
+	registered, validation := register("p256-agent", ecdsaPublicKey, "")
+	assert.True(t, validation.Valid, "%v", validation.Errors)
+	assert.Equal(t, domain.KeyAlgorithmECDSAP256, registered.KeyAlgorithm)
+	_, validation = register("rsa-agent", rsaPublicKey, "")
+	assert.True(t, validation.HasError(RegistrationIssueKeyAlgorithm))
+	_, validation = register("mislabeled", ecdsaPublicKey, domain.KeyAlgorithmRSAPSS)
+	assert.True(t, validation.HasError(RegistrationIssueInvalidKey))
+
+	// An agent registered with an Ed25519 key signs with it until the organization drops Ed25519
+	keyPair, err := crypto.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	edPublicKey := crypto.EncodeKeyPair(keyPair).PublicKeyBase64
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.PublicKey = &edPublicKey })
+	require.NoError(t, repos.Agent.Create(agent))
+	message := []byte("POST\n/api/v1/sdk-api/verifications\n1700000000")
+	edSignature := crypto.SignMessage(keyPair.PrivateKey, message)
+	key, err := agentService.VerifyAgentSignature(ctx, agent, edPublicKey, message, edSignature)
+	require.NoError(t, err)
+	assert.Equal(t, domain.KeyAlgorithmEd25519, key.Algorithm)
+
+	allow(domain.KeyAlgorithmECDSAP256)
+	_, err = agentService.VerifyAgentSignature(ctx, agent, edPublicKey, message, edSignature)
+	assert.ErrorIs(t, err, domain.ErrKeyAlgorithmNotAllowed)
+	usage, err := agentService.KeyAlgorithmUsage(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, usage.Agents[domain.KeyAlgorithmEd25519])
+	require.Len(t, usage.NeedsMigration, 1)
+	assert.Equal(t, agent.ID, usage.NeedsMigration[0].AgentID)
+
+	// Rotating migrates the agent: the new key needs an allowed algorithm, and the previous
+	// Ed25519 key keeps verifying with its own algorithm during the grace period
+	_, err = agentService.RotateKey(ctx, agent.ID, &RotateKeyRequest{PublicKey: rsaPublicKey})
+	assert.ErrorIs(t, err, domain.ErrKeyAlgorithmNotAllowed)
+	_, err = agentService.RotateKey(ctx, agent.ID, &RotateKeyRequest{})
+	assert.ErrorIs(t, err, domain.ErrKeyAlgorithmNotAllowed, "keys AIM generates are Ed25519")
+	_, err = agentService.RotateKey(ctx, agent.ID, &RotateKeyRequest{
+		PublicKey:         ecdsaPublicKey,
+		ProofOfPossession: base64.StdEncoding.EncodeToString(signECDSA(crypto.ProofOfPossessionMessage(agent.Name, ecdsaPublicKey))),
+	})
+	require.NoError(t, err)
+	rotated, err := repos.Agent.GetByID(agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.KeyAlgorithmECDSAP256, rotated.KeyAlgorithm)
+	assert.Equal(t, domain.KeyAlgorithmEd25519, rotated.PreviousKeyAlgorithm)
+
+	key, err = agentService.VerifyAgentSignature(ctx, rotated, "", message, signECDSA(message))
+	require.NoError(t, err)
+	assert.Equal(t, domain.KeyAlgorithmECDSAP256, key.Algorithm)
+	key, err = agentService.VerifyAgentSignature(ctx, rotated, edPublicKey, message, edSignature)
+	require.NoError(t, err)
+	assert.True(t, key.Previous)
+	_, err = agentService.VerifyAgentSignature(ctx, rotated, ecdsaPublicKey, message, edSignature)
+	assert.ErrorIs(t, err, crypto.ErrInvalidSignature)
+
+	usage, err = agentService.KeyAlgorithmUsage(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Empty(t, usage.NeedsMigration)
+}
@@ -0,0 +1,192 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrInvalidAgentReference is returned when a public lookup is not of the form <org-domain>/<agent-name>
+	ErrInvalidAgentReference = errors.New("agent must be given as <org-domain>/<agent-name>")
+	// ErrPublicAgentNotFound is returned when a looked up agent does not exist or is not publicly
+	// discoverable; the two are deliberately indistinguishable
+	ErrPublicAgentNotFound = errors.New("agent not found")
+	// ErrListingAgentNotFound is returned when the agent to list does not exist in the organization
+	ErrListingAgentNotFound = errors.New("agent not found")
+)
+
+// AgentListingService lets organizations make agents publicly discoverable and answers the
+// unauthenticated lookups of their verification status
+type AgentListingService struct {
+	listingRepo domain.AgentListingRepository
+	agentRepo   domain.AgentRepository
+	orgRepo     domain.OrganizationRepository
+	publicURL   string // Base URL of the status pages badges link to
+	now         func() time.Time
+}
+
+// NewAgentListingService creates a new agent listing service
+func NewAgentListingService(
+	listingRepo domain.AgentListingRepository,
+	agentRepo domain.AgentRepository,
+	orgRepo domain.OrganizationRepository,
+	publicURL string,
+) *AgentListingService {
+	return &AgentListingService{
+		listingRepo: listingRepo,
+		agentRepo:   agentRepo,
+		orgRepo:     orgRepo,
+		publicURL:   strings.TrimRight(publicURL, "/"),
+		now:         time.Now,
+	}
+}
+
+// GetListing returns the agent's listing, or domain.ErrAgentListingNotFound when it is not listed
+func (s *AgentListingService) GetListing(ctx context.Context, orgID, agentID uuid.UUID) (*domain.AgentListing, error) {
+	if _, err := s.getAgent(orgID, agentID); err != nil {
+		return nil, err
+	}
+	return s.listingRepo.Get(agentID)
+}
+
+// List makes the agent publicly discoverable; listing a listed agent keeps the original listing
+func (s *AgentListingService) List(ctx context.Context, orgID, agentID, userID uuid.UUID) (*domain.AgentListing, error) {
+	if _, err := s.getAgent(orgID, agentID); err != nil {
+		return nil, err
+	}
+
+	listing := &domain.AgentListing{
+		AgentID:        agentID,
+		OrganizationID: orgID,
+		ListedBy:       &userID,
+		ListedAt:       s.now().UTC(),
+	}
+	if err := s.listingRepo.Upsert(listing); err != nil {
+		return nil, fmt.Errorf("failed to list agent: %w", err)
+	}
+	return listing, nil
+}
+
+// Unlist stops the agent from being publicly discoverable
+func (s *AgentListingService) Unlist(ctx context.Context, orgID, agentID uuid.UUID) error {
+	if _, err := s.getAgent(orgID, agentID); err != nil {
+		return err
+	}
+	if err := s.listingRepo.Delete(agentID); err != nil {
+		return fmt.Errorf("failed to unlist agent: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the verification standing of a publicly discoverable agent, referenced as
+// <org-domain>/<agent-name>. Agents of inactive organizations are not discoverable.
+func (s *AgentListingService) Lookup(ctx context.Context, reference string) (*domain.PublicAgentVerification, error) {
+	orgDomain, name, ok := strings.Cut(strings.TrimSpace(reference), "/")
+	orgDomain = strings.ToLower(strings.TrimSpace(orgDomain))
+	name = strings.TrimSpace(name)
+	if !ok || orgDomain == "" || name == "" {
+		return nil, ErrInvalidAgentReference
+	}
+
+	org, err := s.orgRepo.GetByDomain(orgDomain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	if org == nil || !org.IsActive {
+		return nil, ErrPublicAgentNotFound
+	}
+
+	named, err := s.agentRepo.GetByName(org.ID, name)
+	if err != nil {
+		return nil, ErrPublicAgentNotFound
+	}
+	if _, err := s.listingRepo.Get(named.ID); err != nil {
+		if errors.Is(err, domain.ErrAgentListingNotFound) {
+			return nil, ErrPublicAgentNotFound
+		}
+		return nil, fmt.Errorf("failed to get agent listing: %w", err)
+	}
+
+	// GetByID carries the agent's last activity
+	agent, err := s.agentRepo.GetByID(named.ID)
+	if err != nil {
+		return nil, ErrPublicAgentNotFound
+	}
+
+	return &domain.PublicAgentVerification{
+		Agent:          org.Domain + "/" + agent.Name,
+		DisplayName:    agent.DisplayName,
+		Organization:   org.Name,
+		Status:         agent.Status,
+		Verified:       agent.Status == domain.AgentStatusVerified && !agent.IsCompromised,
+		Compromised:    agent.IsCompromised,
+		TrustScore:     agent.TrustScore,
+		TrustTier:      domain.TrustTierOf(agent.TrustScore),
+		LastVerifiedAt: lastVerifiedAt(agent),
+		CheckedAt:      s.now().UTC(),
+	}, nil
+}
+
+// Badge returns the badge of a publicly discoverable agent, referenced as
+// <org-domain>/<agent-name>: "verified" with its trust tier, "not verified" or "compromised"
+func (s *AgentListingService) Badge(ctx context.Context, reference string) (*domain.PublicAgentBadge, error) {
+	verification, err := s.Lookup(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	badge := &domain.PublicAgentBadge{
+		Label:        "OpenA2A",
+		StatusURL:    s.StatusURL(verification.Agent),
+		Verification: *verification,
+	}
+	switch {
+	case verification.Compromised:
+		badge.Message, badge.Color = "compromised", domain.BadgeColorCompromised
+	case !verification.Verified:
+		badge.Message, badge.Color = "not verified", domain.BadgeColorNotVerified
+	default:
+		badge.Message = "verified | " + verification.TrustTier + " trust"
+		badge.Color = map[string]string{
+			domain.TrustTierExcellent: domain.BadgeColorExcellent,
+			domain.TrustTierGood:      domain.BadgeColorGood,
+			domain.TrustTierFair:      domain.BadgeColorFair,
+			domain.TrustTierPoor:      domain.BadgeColorPoor,
+		}[verification.TrustTier]
+	}
+	return badge, nil
+}
+
+// StatusURL returns the public status page of an agent referenced as <org-domain>/<agent-name>
+func (s *AgentListingService) StatusURL(reference string) string {
+	orgDomain, name, _ := strings.Cut(reference, "/")
+	return fmt.Sprintf("%s/public/v1/agents/%s/%s", s.publicURL, url.PathEscape(orgDomain), url.PathEscape(name))
+}
+
+func (s *AgentListingService) getAgent(orgID, agentID uuid.UUID) (*domain.Agent, error) {
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil || agent.OrganizationID != orgID {
+		return nil, ErrListingAgentNotFound
+	}
+	return agent, nil
+}
+
+// lastVerifiedAt is when a verified agent last proved its identity: its verification or its
+// latest verified action, whichever is later
+func lastVerifiedAt(agent *domain.Agent) *time.Time {
+	if agent.Status != domain.AgentStatusVerified {
+		return nil
+	}
+	last := agent.VerifiedAt
+	if agent.LastActive != nil && (last == nil || agent.LastActive.After(*last)) {
+		last = agent.LastActive
+	}
+	return last
+}
@@ -0,0 +1,85 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicAgentLookupOnlyFindsListedAgents(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	ctx := context.Background()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	other := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(other))
+	manager := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(manager))
+
+	lastActive := time.Now().Add(-time.Minute).UTC()
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) {
+		verifiedAt := time.Now().Add(-24 * time.Hour)
+		a.VerifiedAt = &verifiedAt
+		a.LastActive = &lastActive
+		a.TrustScore = 0.75
+	})
+	require.NoError(t, repos.Agent.Create(agent))
+
+	listings := NewAgentListingService(repos.AgentListing, repos.Agent, repos.Organization, "https://aim.example.com/")
+	reference := org.Domain + "/" + agent.Name
+
+	// Unlisted agents are indistinguishable from missing ones
+	_, err := listings.Lookup(ctx, reference)
+	assert.ErrorIs(t, err, ErrPublicAgentNotFound)
+	_, err = listings.GetListing(ctx, org.ID, agent.ID)
+	assert.ErrorIs(t, err, domain.ErrAgentListingNotFound)
+
+	// Other organizations cannot list the agent
+	_, err = listings.List(ctx, other.ID, agent.ID, manager.ID)
+	assert.ErrorIs(t, err, ErrListingAgentNotFound)
+
+	listing, err := listings.List(ctx, org.ID, agent.ID, manager.ID)
+	require.NoError(t, err)
+	assert.Equal(t, manager.ID, *listing.ListedBy)
+
+	verification, err := listings.Lookup(ctx, " "+strings.ToUpper(org.Domain)+"/"+agent.Name)
+	require.NoError(t, err)
+	assert.Equal(t, reference, verification.Agent)
+	assert.Equal(t, org.Name, verification.Organization)
+	assert.True(t, verification.Verified)
+	assert.Equal(t, domain.TrustTierGood, verification.TrustTier)
+	require.NotNil(t, verification.LastVerifiedAt)
+	assert.True(t, verification.LastVerifiedAt.Equal(lastActive))
+
+	for _, invalid := range []string{"", agent.Name, org.Domain + "/", "/" + agent.Name} {
+		_, err = listings.Lookup(ctx, invalid)
+		assert.ErrorIs(t, err, ErrInvalidAgentReference, invalid)
+	}
+	_, err = listings.Lookup(ctx, other.Domain+"/"+agent.Name)
+	assert.ErrorIs(t, err, ErrPublicAgentNotFound)
+
+	// A compromised agent stays listed but is no longer reported as verified
+	require.NoError(t, repos.Agent.MarkAsCompromised(agent.ID))
+	verification, err = listings.Lookup(ctx, reference)
+	require.NoError(t, err)
+	assert.False(t, verification.Verified)
+	assert.True(t, verification.Compromised)
+
+	// Agents of deactivated organizations are not discoverable
+	org.IsActive = false
+	require.NoError(t, repos.Organization.Update(org))
+	_, err = listings.Lookup(ctx, reference)
+	assert.ErrorIs(t, err, ErrPublicAgentNotFound)
+	org.IsActive = true
+	require.NoError(t, repos.Organization.Update(org))
+
+	require.NoError(t, listings.Unlist(ctx, org.ID, agent.ID))
+	_, err = listings.Lookup(ctx, reference)
+	assert.ErrorIs(t, err, ErrPublicAgentNotFound)
+}
@@ -0,0 +1,412 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// DefaultPeerMinTrustScore is the trust score both agents of a peer policy need when the policy
+// sets no minimum; it matches the auto-verification threshold
+const DefaultPeerMinTrustScore = 0.3
+
+var (
+	// ErrPeerAgentNotFound is returned when an agent of a peer policy or call is not in the organization
+	ErrPeerAgentNotFound = errors.New("agent not found")
+	// ErrInvalidAgentPeerPolicy is returned for peer policies with missing agents or out of range minimums
+	ErrInvalidAgentPeerPolicy = errors.New("invalid agent peer policy")
+)
+
+// Reasons of an A2A call decision
+const (
+	PeerDecisionAllowed        = "allowed"
+	PeerDecisionUndeclared     = "undeclared_peer"
+	PeerDecisionNotVerified    = "agent_not_verified"
+	PeerDecisionCompromised    = "agent_compromised"
+	PeerDecisionSourceTrustLow = "source_trust_too_low"
+	PeerDecisionTargetTrustLow = "target_trust_too_low"
+	PeerDecisionSelfCall       = "self_call"
+)
+
+// CreateAgentPeerPolicyRequest declares that the source agent may call the target agent
+type CreateAgentPeerPolicyRequest struct {
+	SourceAgentID       uuid.UUID `json:"sourceAgentId"`
+	TargetAgentID       uuid.UUID `json:"targetAgentId"`
+	Bidirectional       bool      `json:"bidirectional"`
+	AllowedActions      []string  `json:"allowedActions,omitempty"`
+	MinSourceTrustScore *float64  `json:"minSourceTrustScore,omitempty"` // Defaults to DefaultPeerMinTrustScore
+	MinTargetTrustScore *float64  `json:"minTargetTrustScore,omitempty"` // Defaults to DefaultPeerMinTrustScore
+	Description         string    `json:"description,omitempty"`
+}
+
+// UpdateAgentPeerPolicyRequest changes the set fields of a peer policy; its agents can't change
+type UpdateAgentPeerPolicyRequest struct {
+	Bidirectional       *bool     `json:"bidirectional,omitempty"`
+	AllowedActions      *[]string `json:"allowedActions,omitempty"`
+	MinSourceTrustScore *float64  `json:"minSourceTrustScore,omitempty"`
+	MinTargetTrustScore *float64  `json:"minTargetTrustScore,omitempty"`
+	Description         *string   `json:"description,omitempty"`
+	IsEnabled           *bool     `json:"isEnabled,omitempty"`
+}
+
+// AgentPeerCall is a request by one agent to call another
+type AgentPeerCall struct {
+	OrganizationID uuid.UUID
+	CallerAgentID  uuid.UUID
+	TargetAgentID  uuid.UUID
+	Action         string
+	InitiatorType  domain.InitiatorType
+	InitiatorID    *uuid.UUID
+	InitiatorIP    string
+}
+
+// AgentPeerDecision is the outcome of an A2A call authorization
+type AgentPeerDecision struct {
+	Allowed             bool       `json:"allowed"`
+	Reason              string     `json:"reason"`
+	Message             string     `json:"message"`
+	PolicyID            *uuid.UUID `json:"policyId,omitempty"`
+	CallerTrustScore    float64    `json:"callerTrustScore"`
+	TargetTrustScore    float64    `json:"targetTrustScore"`
+	DriftDetected       bool       `json:"driftDetected"`
+	VerificationEventID uuid.UUID  `json:"verificationEventId"`
+}
+
+// AgentPeerService manages agent peer policies and authorizes agent-to-agent calls against
+// them. Every call is recorded as an A2A permission verification event; calls no policy
+// declares are denied and flagged as peer drift.
+type AgentPeerService struct {
+	policyRepo     domain.AgentPeerPolicyRepository
+	agentRepo      domain.AgentRepository
+	eventRepo      domain.VerificationEventRepository
+	driftDetection *DriftDetectionService
+	webhookService *WebhookService // Optional: publishes the recorded verification events
+	now            func() time.Time
+}
+
+// NewAgentPeerService creates a new agent peer service
+func NewAgentPeerService(
+	policyRepo domain.AgentPeerPolicyRepository,
+	agentRepo domain.AgentRepository,
+	eventRepo domain.VerificationEventRepository,
+	driftDetection *DriftDetectionService,
+	webhookService *WebhookService,
+) *AgentPeerService {
+	return &AgentPeerService{
+		policyRepo:     policyRepo,
+		agentRepo:      agentRepo,
+		eventRepo:      eventRepo,
+		driftDetection: driftDetection,
+		webhookService: webhookService,
+		now:            time.Now,
+	}
+}
+
+// ListPolicies returns the organization's peer policies
+func (s *AgentPeerService) ListPolicies(ctx context.Context, orgID uuid.UUID) ([]*domain.AgentPeerPolicy, error) {
+	policies, err := s.policyRepo.ListByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent peer policies: %w", err)
+	}
+	return policies, nil
+}
+
+// ListAgentPolicies returns the peer policies the agent is the source or the target of
+func (s *AgentPeerService) ListAgentPolicies(ctx context.Context, orgID, agentID uuid.UUID) ([]*domain.AgentPeerPolicy, error) {
+	if _, err := s.getAgent(orgID, agentID); err != nil {
+		return nil, err
+	}
+	policies, err := s.policyRepo.ListByAgent(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent peer policies: %w", err)
+	}
+	return policies, nil
+}
+
+// GetPolicy returns a peer policy of the organization
+func (s *AgentPeerService) GetPolicy(ctx context.Context, orgID, policyID uuid.UUID) (*domain.AgentPeerPolicy, error) {
+	policy, err := s.policyRepo.GetByID(policyID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.OrganizationID != orgID {
+		return nil, domain.ErrAgentPeerPolicyNotFound
+	}
+	return policy, nil
+}
+
+// CreatePolicy declares a peer relationship between two agents of the organization
+func (s *AgentPeerService) CreatePolicy(ctx context.Context, orgID, userID uuid.UUID, req *CreateAgentPeerPolicyRequest) (*domain.AgentPeerPolicy, error) {
+	if req.SourceAgentID == uuid.Nil || req.TargetAgentID == uuid.Nil {
+		return nil, fmt.Errorf("%w: sourceAgentId and targetAgentId are required", ErrInvalidAgentPeerPolicy)
+	}
+	if req.SourceAgentID == req.TargetAgentID {
+		return nil, fmt.Errorf("%w: an agent can't be its own peer", ErrInvalidAgentPeerPolicy)
+	}
+	for _, agentID := range []uuid.UUID{req.SourceAgentID, req.TargetAgentID} {
+		if _, err := s.getAgent(orgID, agentID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := s.now().UTC()
+	policy := &domain.AgentPeerPolicy{
+		ID:                  uuid.New(),
+		OrganizationID:      orgID,
+		SourceAgentID:       req.SourceAgentID,
+		TargetAgentID:       req.TargetAgentID,
+		Bidirectional:       req.Bidirectional,
+		AllowedActions:      normalizePeerActions(req.AllowedActions),
+		MinSourceTrustScore: DefaultPeerMinTrustScore,
+		MinTargetTrustScore: DefaultPeerMinTrustScore,
+		Description:         strings.TrimSpace(req.Description),
+		IsEnabled:           true,
+		CreatedBy:           userID,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	if req.MinSourceTrustScore != nil {
+		policy.MinSourceTrustScore = *req.MinSourceTrustScore
+	}
+	if req.MinTargetTrustScore != nil {
+		policy.MinTargetTrustScore = *req.MinTargetTrustScore
+	}
+	if err := validatePeerTrustScores(policy); err != nil {
+		return nil, err
+	}
+
+	if err := s.policyRepo.Create(policy); err != nil {
+		if errors.Is(err, domain.ErrAgentPeerPolicyExists) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create agent peer policy: %w", err)
+	}
+	return policy, nil
+}
+
+// UpdatePolicy changes a peer policy of the organization
+func (s *AgentPeerService) UpdatePolicy(ctx context.Context, orgID, policyID uuid.UUID, req *UpdateAgentPeerPolicyRequest) (*domain.AgentPeerPolicy, error) {
+	policy, err := s.GetPolicy(ctx, orgID, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Bidirectional != nil {
+		policy.Bidirectional = *req.Bidirectional
+	}
+	if req.AllowedActions != nil {
+		policy.AllowedActions = normalizePeerActions(*req.AllowedActions)
+	}
+	if req.MinSourceTrustScore != nil {
+		policy.MinSourceTrustScore = *req.MinSourceTrustScore
+	}
+	if req.MinTargetTrustScore != nil {
+		policy.MinTargetTrustScore = *req.MinTargetTrustScore
+	}
+	if req.Description != nil {
+		policy.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.IsEnabled != nil {
+		policy.IsEnabled = *req.IsEnabled
+	}
+	if err := validatePeerTrustScores(policy); err != nil {
+		return nil, err
+	}
+	policy.UpdatedAt = s.now().UTC()
+
+	if err := s.policyRepo.Update(policy); err != nil {
+		return nil, fmt.Errorf("failed to update agent peer policy: %w", err)
+	}
+	return policy, nil
+}
+
+// DeletePolicy removes a peer policy of the organization
+func (s *AgentPeerService) DeletePolicy(ctx context.Context, orgID, policyID uuid.UUID) error {
+	if _, err := s.GetPolicy(ctx, orgID, policyID); err != nil {
+		return err
+	}
+	if err := s.policyRepo.Delete(policyID); err != nil {
+		return fmt.Errorf("failed to delete agent peer policy: %w", err)
+	}
+	return nil
+}
+
+// AuthorizeCall decides whether the caller may call the target agent. The call is allowed when an
+// enabled peer policy declares it and both agents are verified, not compromised and at or above
+// the policy's trust score minimums. The decision is recorded as a verification event; undeclared
+// calls also raise a peer drift alert.
+func (s *AgentPeerService) AuthorizeCall(ctx context.Context, call *AgentPeerCall) (*AgentPeerDecision, error) {
+	caller, err := s.getAgent(call.OrganizationID, call.CallerAgentID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.getAgent(call.OrganizationID, call.TargetAgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	started := s.now()
+	decision := &AgentPeerDecision{
+		CallerTrustScore: caller.TrustScore,
+		TargetTrustScore: target.TrustScore,
+	}
+
+	var policy *domain.AgentPeerPolicy
+	if caller.ID != target.ID {
+		policies, err := s.policyRepo.ListByAgent(caller.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list agent peer policies: %w", err)
+		}
+		for _, candidate := range policies {
+			if candidate.Declares(caller.ID, target.ID, call.Action) {
+				policy = candidate
+				break
+			}
+		}
+	}
+
+	switch {
+	case caller.ID == target.ID:
+		decision.Reason = PeerDecisionSelfCall
+		decision.Message = "An agent can't call itself as a peer"
+	case caller.IsCompromised || target.IsCompromised:
+		decision.Reason = PeerDecisionCompromised
+		decision.Message = "Calls from or to a compromised agent are denied"
+	case caller.Status != domain.AgentStatusVerified || target.Status != domain.AgentStatusVerified:
+		decision.Reason = PeerDecisionNotVerified
+		decision.Message = "Both agents must be verified"
+	case policy == nil:
+		decision.Reason = PeerDecisionUndeclared
+		decision.Message = fmt.Sprintf("No peer policy lets '%s' call '%s'", caller.Name, target.Name)
+		decision.DriftDetected = true
+	case caller.TrustScore < policy.MinSourceTrustScore:
+		decision.Reason = PeerDecisionSourceTrustLow
+		decision.Message = fmt.Sprintf("Caller trust score %.2f is below the policy minimum %.2f", caller.TrustScore, policy.MinSourceTrustScore)
+	case target.TrustScore < policy.MinTargetTrustScore:
+		decision.Reason = PeerDecisionTargetTrustLow
+		decision.Message = fmt.Sprintf("Target trust score %.2f is below the policy minimum %.2f", target.TrustScore, policy.MinTargetTrustScore)
+	default:
+		decision.Allowed = true
+		decision.Reason = PeerDecisionAllowed
+		decision.Message = fmt.Sprintf("'%s' may call '%s'", caller.Name, target.Name)
+	}
+	if policy != nil {
+		decision.PolicyID = &policy.ID
+	}
+
+	if decision.DriftDetected && s.driftDetection != nil {
+		if _, err := s.driftDetection.DetectPeerDrift(caller, target); err != nil {
+			// Log error but don't fail the authorization
+			log.Printf("⚠️  Failed to raise peer drift alert for agent %s: %v", caller.ID, err)
+		}
+	}
+
+	event := s.newCallEvent(call, caller, target, decision, started)
+	event.RecordCaller(ctx)
+	if err := s.eventRepo.Create(event); err != nil {
+		return nil, fmt.Errorf("failed to create verification event: %w", err)
+	}
+	decision.VerificationEventID = event.ID
+
+	recordVerificationMetrics(event)
+	if s.webhookService != nil {
+		s.webhookService.TriggerVerificationEvent(ctx, event, caller)
+	}
+
+	return decision, nil
+}
+
+// newCallEvent builds the A2A permission verification event recording a call decision
+func (s *AgentPeerService) newCallEvent(call *AgentPeerCall, caller, target *domain.Agent, decision *AgentPeerDecision, started time.Time) *domain.VerificationEvent {
+	completed := s.now()
+	action := call.Action
+	resourceType := "agent"
+	resourceID := target.ID.String()
+
+	event := &domain.VerificationEvent{
+		OrganizationID:   call.OrganizationID,
+		AgentID:          &caller.ID,
+		AgentName:        &caller.DisplayName,
+		Protocol:         domain.VerificationProtocolA2A,
+		VerificationType: domain.VerificationTypePermission,
+		Status:           domain.VerificationEventStatusSuccess,
+		Confidence:       1.0,
+		TrustScore:       caller.TrustScore,
+		DurationMs:       int(completed.Sub(started).Milliseconds()),
+		InitiatorType:    call.InitiatorType,
+		InitiatorID:      call.InitiatorID,
+		ResourceType:     &resourceType,
+		ResourceID:       &resourceID,
+		DriftDetected:    decision.DriftDetected,
+		StartedAt:        started,
+		CompletedAt:      &completed,
+		CreatedAt:        completed,
+		Metadata: map[string]interface{}{
+			"targetAgentId":    target.ID.String(),
+			"targetAgentName":  target.Name,
+			"targetTrustScore": target.TrustScore,
+			"decision":         decision.Reason,
+		},
+	}
+	if action != "" {
+		event.Action = &action
+	}
+	if call.InitiatorIP != "" {
+		event.InitiatorIP = &call.InitiatorIP
+	}
+	if decision.PolicyID != nil {
+		event.Metadata["peerPolicyId"] = decision.PolicyID.String()
+	}
+
+	result := domain.VerificationResultVerified
+	if !decision.Allowed {
+		result = domain.VerificationResultDenied
+		event.Status = domain.VerificationEventStatusFailed
+		event.ErrorCode = &decision.Reason
+		event.ErrorReason = &decision.Message
+	}
+	event.Result = &result
+	if decision.DriftDetected {
+		event.PeerAgentDrift = []string{target.Name}
+	}
+	event.RiskLevel = ClassifyVerificationRisk(event, VerificationRiskContext{AgentCompromised: caller.IsCompromised})
+	return event
+}
+
+func (s *AgentPeerService) getAgent(orgID, agentID uuid.UUID) (*domain.Agent, error) {
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil || agent.OrganizationID != orgID {
+		return nil, ErrPeerAgentNotFound
+	}
+	return agent, nil
+}
+
+// normalizePeerActions trims the actions and drops empty and duplicate ones
+func normalizePeerActions(actions []string) []string {
+	normalized := make([]string, 0, len(actions))
+	seen := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		action = strings.TrimSpace(action)
+		if action == "" || seen[action] {
+			continue
+		}
+		seen[action] = true
+		normalized = append(normalized, action)
+	}
+	return normalized
+}
+
+func validatePeerTrustScores(policy *domain.AgentPeerPolicy) error {
+	for _, score := range []float64{policy.MinSourceTrustScore, policy.MinTargetTrustScore} {
+		if score < 0 || score > 1 {
+			return fmt.Errorf("%w: trust score minimums must be between 0 and 1", ErrInvalidAgentPeerPolicy)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentToAgentCallsAreAuthorizedByPeerPolicies(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	admin := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(admin))
+	ctx := context.Background()
+
+	planner := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.Name = "planner" })
+	executor := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.Name = "executor" })
+	untrusted := testsupport.NewAgent(org.ID, func(a *domain.Agent) {
+		a.Name = "untrusted"
+		a.TrustScore = 0.2
+	})
+	for _, agent := range []*domain.Agent{planner, executor, untrusted} {
+		require.NoError(t, repos.Agent.Create(agent))
+	}
+
+	drift := NewDriftDetectionService(repos.Agent, repos.Alert)
+	service := NewAgentPeerService(repos.AgentPeerPolicy, repos.Agent, repos.VerificationEvent, drift, nil)
+	call := func(caller, target *domain.Agent, action string) *AgentPeerDecision {
+		decision, err := service.AuthorizeCall(ctx, &AgentPeerCall{
+			OrganizationID: org.ID,
+			CallerAgentID:  caller.ID,
+			TargetAgentID:  target.ID,
+			Action:         action,
+			InitiatorType:  domain.InitiatorTypeAgent,
+			InitiatorID:    &caller.ID,
+		})
+		require.NoError(t, err)
+		return decision
+	}
+
+	// Without a policy the call is denied and flagged as peer drift, alerting once
+	decision := call(planner, executor, "run_task")
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, PeerDecisionUndeclared, decision.Reason)
+	assert.True(t, decision.DriftDetected)
+	call(planner, executor, "run_task")
+	alerts, err := repos.Alert.GetUnacknowledgedByResourceID(planner.ID)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domain.AlertTypePeerDrift, alerts[0].AlertType)
+	assert.Equal(t, "Peer Drift Detected: planner → executor", alerts[0].Title)
+
+	event, err := repos.VerificationEvent.GetByID(decision.VerificationEventID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.VerificationProtocolA2A, event.Protocol)
+	assert.Equal(t, domain.VerificationEventStatusFailed, event.Status)
+	assert.Equal(t, []string{"executor"}, event.PeerAgentDrift)
+
+	// A declared, bidirectional relationship allows the listed actions both ways
+	_, err = service.CreatePolicy(ctx, org.ID, admin.ID, &CreateAgentPeerPolicyRequest{
+		SourceAgentID:  planner.ID,
+		TargetAgentID:  executor.ID,
+		Bidirectional:  true,
+		AllowedActions: []string{"run_task", " report ", "run_task"},
+	})
+	require.NoError(t, err)
+	_, err = service.CreatePolicy(ctx, org.ID, admin.ID, &CreateAgentPeerPolicyRequest{
+		SourceAgentID: planner.ID,
+		TargetAgentID: executor.ID,
+	})
+	assert.ErrorIs(t, err, domain.ErrAgentPeerPolicyExists)
+
+	decision = call(planner, executor, "run_task")
+	assert.True(t, decision.Allowed)
+	assert.NotNil(t, decision.PolicyID)
+	assert.True(t, call(executor, planner, "report").Allowed)
+	assert.Equal(t, PeerDecisionUndeclared, call(planner, executor, "delete_data").Reason)
+
+	// Both agents must meet the policy's trust score minimums
+	policy, err := service.CreatePolicy(ctx, org.ID, admin.ID, &CreateAgentPeerPolicyRequest{
+		SourceAgentID: planner.ID,
+		TargetAgentID: untrusted.ID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPeerMinTrustScore, policy.MinTargetTrustScore)
+	assert.Equal(t, PeerDecisionTargetTrustLow, call(planner, untrusted, "").Reason)
+
+	lower := 0.1
+	_, err = service.UpdatePolicy(ctx, org.ID, policy.ID, &UpdateAgentPeerPolicyRequest{MinTargetTrustScore: &lower})
+	require.NoError(t, err)
+	assert.True(t, call(planner, untrusted, "").Allowed)
+
+	// Compromised agents can't call or be called, whatever the policies say
+	require.NoError(t, repos.Agent.MarkAsCompromised(executor.ID))
+	assert.Equal(t, PeerDecisionCompromised, call(planner, executor, "run_task").Reason)
+
+	peers, err := service.ListAgentPolicies(ctx, org.ID, planner.ID)
+	require.NoError(t, err)
+	assert.Len(t, peers, 2)
+	require.NoError(t, service.DeletePolicy(ctx, org.ID, policy.ID))
+	assert.ErrorIs(t, service.DeletePolicy(ctx, org.ID, policy.ID), domain.ErrAgentPeerPolicyNotFound)
+}
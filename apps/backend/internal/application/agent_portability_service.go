@@ -0,0 +1,541 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	infracrypto "github.com/opena2a/identity/backend/internal/infrastructure/crypto"
+)
+
+const (
+	// identityBundleMaxAge is how long after export a bundle can be imported
+	identityBundleMaxAge = 7 * 24 * time.Hour
+	// identityBundleTrustHistoryLimit is how many trust score calculations a trust history summarizes
+	identityBundleTrustHistoryLimit = 1000
+)
+
+// ErrPortableAgentNotFound is returned when an agent to export is not in the organization
+var ErrPortableAgentNotFound = errors.New("agent not found")
+
+// Outcomes of importing an agent from an identity bundle
+const (
+	IdentityImportImported = "imported"
+	IdentityImportSkipped  = "skipped"
+)
+
+// ExportIdentitiesRequest selects the agents to export; none exports every agent of the organization
+type ExportIdentitiesRequest struct {
+	AgentIDs []uuid.UUID `json:"agentIds"`
+}
+
+// IdentityImportResult is what became of one agent of an imported bundle
+type IdentityImportResult struct {
+	SourceID uuid.UUID  `json:"sourceId"`
+	Name     string     `json:"name"`
+	Status   string     `json:"status"` // imported or skipped
+	Reason   string     `json:"reason,omitempty"`
+	AgentID  *uuid.UUID `json:"agentId,omitempty"`
+	// Attestations are imported when their signature verifies with the agent's key and the MCP
+	// server they attest is registered here under the same URL
+	AttestationsImported  int `json:"attestationsImported"`
+	AttestationsUnmatched int `json:"attestationsUnmatched"` // No MCP server with that URL
+	AttestationsRejected  int `json:"attestationsRejected"`  // Signature did not verify
+}
+
+// IdentityImportReport is the outcome of importing an identity bundle
+type IdentityImportReport struct {
+	Issuer   domain.IdentityBundleIssuer `json:"issuer"`
+	Imported int                         `json:"imported"`
+	Skipped  int                         `json:"skipped"`
+	Agents   []IdentityImportResult      `json:"agents"`
+}
+
+// IdentityBundleIssuerInfo is what other deployments need to trust this deployment's bundles
+type IdentityBundleIssuerInfo struct {
+	Name           string `json:"name"`
+	PublicKey      string `json:"publicKey"`
+	KeyFingerprint string `json:"keyFingerprint"`
+}
+
+// AgentPortabilityService moves agents between deployments with signed identity bundles. An
+// export carries each agent's metadata, public keys, trust history summary, signed attestations
+// and lineage; an import recreates the agents with their trust score and the attestations that
+// still verify, and records where they came from, so agents don't start over at a new deployment.
+type AgentPortabilityService struct {
+	agentRepo        domain.AgentRepository
+	trustScoreRepo   domain.TrustScoreRepository
+	attestationRepo  domain.MCPAttestationRepository
+	mcpRepo          domain.MCPServerRepository
+	lineageRepo      domain.AgentLineageRepository
+	sharedKeyService *SharedKeyService // Optional: refuses public keys other agents here hold
+	signer           *crypto.IdentityBundleSigner
+	issuerName       string
+	cryptoService    *infracrypto.ED25519Service
+	now              func() time.Time
+}
+
+// NewAgentPortabilityService creates a new agent portability service; issuerName identifies
+// this deployment in the bundles it signs
+func NewAgentPortabilityService(
+	agentRepo domain.AgentRepository,
+	trustScoreRepo domain.TrustScoreRepository,
+	attestationRepo domain.MCPAttestationRepository,
+	mcpRepo domain.MCPServerRepository,
+	lineageRepo domain.AgentLineageRepository,
+	sharedKeyService *SharedKeyService,
+	signer *crypto.IdentityBundleSigner,
+	issuerName string,
+) *AgentPortabilityService {
+	return &AgentPortabilityService{
+		agentRepo:        agentRepo,
+		trustScoreRepo:   trustScoreRepo,
+		attestationRepo:  attestationRepo,
+		mcpRepo:          mcpRepo,
+		lineageRepo:      lineageRepo,
+		sharedKeyService: sharedKeyService,
+		signer:           signer,
+		issuerName:       issuerName,
+		cryptoService:    infracrypto.NewED25519Service(),
+		now:              time.Now,
+	}
+}
+
+// Issuer returns this deployment's bundle signing identity
+func (s *AgentPortabilityService) Issuer() *IdentityBundleIssuerInfo {
+	return &IdentityBundleIssuerInfo{
+		Name:           s.issuerName,
+		PublicKey:      s.signer.PublicKey(),
+		KeyFingerprint: domain.PublicKeyFingerprint(s.signer.PublicKey()),
+	}
+}
+
+// ExportIdentities signs a bundle of the organization's agents
+func (s *AgentPortabilityService) ExportIdentities(ctx context.Context, orgID uuid.UUID, req *ExportIdentitiesRequest) (*domain.SignedIdentityBundle, error) {
+	var agents []*domain.Agent
+	var err error
+	if len(req.AgentIDs) == 0 {
+		if agents, err = s.agentRepo.GetByOrganization(orgID); err != nil {
+			return nil, fmt.Errorf("failed to list agents: %w", err)
+		}
+	} else {
+		for _, id := range req.AgentIDs {
+			agent, err := s.agentRepo.GetByID(id)
+			if err != nil || agent.OrganizationID != orgID {
+				return nil, fmt.Errorf("%w: %s", ErrPortableAgentNotFound, id)
+			}
+			agents = append(agents, agent)
+		}
+	}
+
+	bundle := &domain.IdentityBundle{
+		Format: domain.IdentityBundleFormat,
+		Issuer: domain.IdentityBundleIssuer{
+			Name:      s.issuerName,
+			PublicKey: s.signer.PublicKey(),
+		},
+		SourceOrganizationID: orgID,
+		ExportedAt:           s.now().UTC(),
+		Agents:               make([]domain.PortableAgent, 0, len(agents)),
+	}
+	servers := make(map[uuid.UUID]*domain.MCPServer)
+	for _, agent := range agents {
+		portable, err := s.portableAgent(agent, servers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export agent %s: %w", agent.ID, err)
+		}
+		bundle.Agents = append(bundle.Agents, *portable)
+	}
+
+	raw, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode identity bundle: %w", err)
+	}
+	return &domain.SignedIdentityBundle{
+		Bundle:    raw,
+		Signature: s.signer.Sign(raw),
+	}, nil
+}
+
+// portableAgent collects the agent's identity; servers caches the MCP servers its attestations name
+func (s *AgentPortabilityService) portableAgent(agent *domain.Agent, servers map[uuid.UUID]*domain.MCPServer) (*domain.PortableAgent, error) {
+	portable := &domain.PortableAgent{
+		SourceID:         agent.ID,
+		Name:             agent.Name,
+		DisplayName:      agent.DisplayName,
+		Description:      agent.Description,
+		AgentType:        agent.AgentType,
+		Status:           agent.Status,
+		Version:          agent.Version,
+		KeyAlgorithm:     agent.KeyAlgorithm,
+		RotationCount:    agent.RotationCount,
+		RepositoryURL:    agent.RepositoryURL,
+		DocumentationURL: agent.DocumentationURL,
+		TalksTo:          agent.TalksTo,
+		Capabilities:     agent.Capabilities,
+		IsCompromised:    agent.IsCompromised,
+		RegisteredAt:     agent.CreatedAt,
+		VerifiedAt:       agent.VerifiedAt,
+		Attestations:     make([]domain.PortableAttestation, 0),
+		Lineage:          make([]domain.AgentLineageHop, 0),
+	}
+	if agent.PublicKey != nil {
+		portable.PublicKey = *agent.PublicKey
+	}
+	if agent.PreviousPublicKey != nil {
+		portable.PreviousPublicKey = *agent.PreviousPublicKey
+	}
+
+	history, err := s.trustScoreRepo.GetHistory(agent.ID, identityBundleTrustHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trust history: %w", err)
+	}
+	portable.TrustHistory = summarizeTrustHistory(agent, history)
+
+	attestations, err := s.attestationRepo.GetAttestationsByAgent(agent.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load attestations: %w", err)
+	}
+	for _, attestation := range attestations {
+		// Only the agent's own signed attestations verify elsewhere
+		if !attestation.SignatureVerified || attestation.Signature == "" {
+			continue
+		}
+		server, ok := servers[attestation.MCPServerID]
+		if !ok {
+			if server, err = s.mcpRepo.GetByID(attestation.MCPServerID); err != nil {
+				continue
+			}
+			servers[attestation.MCPServerID] = server
+		}
+		portable.Attestations = append(portable.Attestations, domain.PortableAttestation{
+			MCPServerURL: server.URL,
+			Payload:      attestation.AttestationData,
+			Signature:    attestation.Signature,
+			VerifiedAt:   attestation.VerifiedAt,
+			ExpiresAt:    attestation.ExpiresAt,
+			IsValid:      attestation.IsValid,
+			CreatedAt:    attestation.CreatedAt,
+		})
+	}
+
+	lineage, err := s.lineageRepo.ListByAgent(agent.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lineage: %w", err)
+	}
+	for _, entry := range lineage {
+		portable.Lineage = append(portable.Lineage, entry.AgentLineageHop)
+	}
+	return portable, nil
+}
+
+// summarizeTrustHistory summarizes the agent's trust score calculations, newest first
+func summarizeTrustHistory(agent *domain.Agent, history []*domain.TrustScore) domain.PortableTrustHistory {
+	summary := domain.PortableTrustHistory{
+		CurrentScore: agent.TrustScore,
+		Samples:      len(history),
+	}
+	if len(history) == 0 {
+		return summary
+	}
+
+	latest := history[0]
+	factors := latest.Factors
+	summary.Factors = &factors
+	summary.Confidence = latest.Confidence
+	summary.MinScore, summary.MaxScore = latest.Score, latest.Score
+	total := 0.0
+	for _, score := range history {
+		summary.MinScore = min(summary.MinScore, score.Score)
+		summary.MaxScore = max(summary.MaxScore, score.Score)
+		total += score.Score
+	}
+	summary.AverageScore = total / float64(len(history))
+	first, last := history[len(history)-1].CreatedAt, latest.CreatedAt
+	summary.FirstRecordedAt, summary.LastRecordedAt = &first, &last
+	return summary
+}
+
+// ImportIdentities verifies a bundle signed by this or a trusted deployment and recreates its
+// agents in the organization. Agents whose name is taken or whose public key another agent here
+// holds are skipped.
+func (s *AgentPortabilityService) ImportIdentities(
+	ctx context.Context,
+	orgID, userID uuid.UUID,
+	signed *domain.SignedIdentityBundle,
+) (*IdentityImportReport, error) {
+	bundle, err := s.verifyBundle(signed)
+	if err != nil {
+		return nil, err
+	}
+
+	servers, err := s.mcpRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mcp servers: %w", err)
+	}
+	serversByURL := make(map[string]*domain.MCPServer, len(servers))
+	for _, server := range servers {
+		serversByURL[normalizeMCPURL(server.URL)] = server
+	}
+
+	report := &IdentityImportReport{
+		Issuer: bundle.Issuer,
+		Agents: make([]IdentityImportResult, 0, len(bundle.Agents)),
+	}
+	for i := range bundle.Agents {
+		result := s.importAgent(ctx, orgID, userID, bundle, &bundle.Agents[i], serversByURL)
+		if result.Status == IdentityImportImported {
+			report.Imported++
+		} else {
+			report.Skipped++
+		}
+		report.Agents = append(report.Agents, result)
+	}
+	return report, nil
+}
+
+// verifyBundle checks the bundle's issuer, signature, format and age
+func (s *AgentPortabilityService) verifyBundle(signed *domain.SignedIdentityBundle) (*domain.IdentityBundle, error) {
+	var bundle domain.IdentityBundle
+	if len(signed.Bundle) == 0 || json.Unmarshal(signed.Bundle, &bundle) != nil {
+		return nil, fmt.Errorf("%w: bundle is not an identity bundle", domain.ErrIdentityBundleInvalid)
+	}
+	if !s.signer.Trusts(bundle.Issuer.PublicKey) {
+		return nil, fmt.Errorf("%w: add %s's public key to IDENTITY_BUNDLE_TRUSTED_KEYS", domain.ErrIdentityBundleUntrusted, bundle.Issuer.Name)
+	}
+	if !crypto.VerifyIdentityBundle(bundle.Issuer.PublicKey, signed.Bundle, signed.Signature) {
+		return nil, fmt.Errorf("%w: signature does not verify", domain.ErrIdentityBundleInvalid)
+	}
+	if bundle.Format != domain.IdentityBundleFormat {
+		return nil, fmt.Errorf("%w: unsupported format %q", domain.ErrIdentityBundleInvalid, bundle.Format)
+	}
+	if s.now().Sub(bundle.ExportedAt) > identityBundleMaxAge {
+		return nil, fmt.Errorf("%w: bundle was exported more than %s ago", domain.ErrIdentityBundleInvalid, identityBundleMaxAge)
+	}
+	return &bundle, nil
+}
+
+// importAgent recreates one agent of the bundle with its trust score, attestations and lineage
+func (s *AgentPortabilityService) importAgent(
+	ctx context.Context,
+	orgID, userID uuid.UUID,
+	bundle *domain.IdentityBundle,
+	portable *domain.PortableAgent,
+	serversByURL map[string]*domain.MCPServer,
+) IdentityImportResult {
+	result := IdentityImportResult{
+		SourceID: portable.SourceID,
+		Name:     portable.Name,
+		Status:   IdentityImportSkipped,
+	}
+
+	if existing, err := s.agentRepo.GetByName(orgID, portable.Name); err == nil && existing != nil {
+		result.Reason = "an agent with this name already exists"
+		return result
+	}
+	if portable.PublicKey != "" && s.sharedKeyService != nil {
+		if err := s.sharedKeyService.CheckPublicKey(ctx, orgID, uuid.Nil, portable.PublicKey); err != nil {
+			result.Reason = err.Error()
+			return result
+		}
+	}
+
+	agent := &domain.Agent{
+		OrganizationID:   orgID,
+		Name:             portable.Name,
+		DisplayName:      portable.DisplayName,
+		Description:      portable.Description,
+		AgentType:        portable.AgentType,
+		Status:           portable.Status,
+		Version:          portable.Version,
+		KeyAlgorithm:     portable.KeyAlgorithm,
+		RepositoryURL:    portable.RepositoryURL,
+		DocumentationURL: portable.DocumentationURL,
+		TrustScore:       portable.TrustHistory.CurrentScore,
+		TalksTo:          portable.TalksTo,
+		Capabilities:     portable.Capabilities,
+		CreatedBy:        userID,
+	}
+	if portable.PublicKey != "" {
+		publicKey := portable.PublicKey
+		agent.PublicKey = &publicKey
+	}
+	if err := s.agentRepo.Create(agent); err != nil {
+		result.Reason = "failed to create agent"
+		log.Printf("⚠️  Failed to import agent %s: %v", portable.SourceID, err)
+		return result
+	}
+	if portable.VerifiedAt != nil {
+		// Create does not store the verification time
+		agent.VerifiedAt = portable.VerifiedAt
+		if err := s.agentRepo.Update(agent); err != nil {
+			log.Printf("⚠️  Failed to restore verification time of imported agent %s: %v", agent.ID, err)
+		}
+	}
+	if portable.IsCompromised {
+		if err := s.agentRepo.MarkAsCompromised(agent.ID); err != nil {
+			log.Printf("⚠️  Failed to mark imported agent %s compromised: %v", agent.ID, err)
+		}
+	}
+
+	result.Status = IdentityImportImported
+	result.AgentID = &agent.ID
+	now := s.now().UTC()
+
+	if portable.TrustHistory.Factors != nil {
+		lastCalculated := now
+		if portable.TrustHistory.LastRecordedAt != nil {
+			lastCalculated = *portable.TrustHistory.LastRecordedAt
+		}
+		if err := s.trustScoreRepo.Create(&domain.TrustScore{
+			ID:             uuid.New(),
+			AgentID:        agent.ID,
+			Score:          portable.TrustHistory.CurrentScore,
+			Factors:        *portable.TrustHistory.Factors,
+			Confidence:     portable.TrustHistory.Confidence,
+			LastCalculated: lastCalculated,
+			CreatedAt:      now,
+		}); err != nil {
+			log.Printf("⚠️  Failed to import trust score of agent %s: %v", agent.ID, err)
+		}
+	}
+
+	s.importAttestations(agent, portable, serversByURL, &result, now)
+
+	hops := append(append([]domain.AgentLineageHop{}, portable.Lineage...), domain.AgentLineageHop{
+		IssuerName:           bundle.Issuer.Name,
+		IssuerKeyFingerprint: domain.PublicKeyFingerprint(bundle.Issuer.PublicKey),
+		SourceAgentID:        portable.SourceID,
+		SourceOrganizationID: bundle.SourceOrganizationID,
+		RegisteredAt:         portable.RegisteredAt,
+		ExportedAt:           bundle.ExportedAt,
+		ImportedAt:           now,
+	})
+	for _, hop := range hops {
+		if err := s.lineageRepo.Create(&domain.AgentLineageEntry{
+			ID:              uuid.New(),
+			AgentID:         agent.ID,
+			OrganizationID:  orgID,
+			AgentLineageHop: hop,
+		}); err != nil {
+			log.Printf("⚠️  Failed to record lineage of imported agent %s: %v", agent.ID, err)
+		}
+	}
+	return result
+}
+
+// importAttestations stores the portable agent's attestations whose signature verifies with one
+// of its keys, on the organization's MCP server with the same URL, and connects the agent to it
+func (s *AgentPortabilityService) importAttestations(
+	agent *domain.Agent,
+	portable *domain.PortableAgent,
+	serversByURL map[string]*domain.MCPServer,
+	result *IdentityImportResult,
+	now time.Time,
+) {
+	// Attestations name the agent's ID at the deployment they were signed at
+	formerIDs := map[string]bool{portable.SourceID.String(): true}
+	for _, hop := range portable.Lineage {
+		formerIDs[hop.SourceAgentID.String()] = true
+	}
+
+	attested := make(map[uuid.UUID][]time.Time)
+	for _, imported := range portable.Attestations {
+		payload := imported.Payload
+		if !formerIDs[payload.AgentID] || !s.verifiesWithAgentKey(portable, &payload, imported.Signature) {
+			result.AttestationsRejected++
+			continue
+		}
+		server, ok := serversByURL[normalizeMCPURL(imported.MCPServerURL)]
+		if !ok {
+			result.AttestationsUnmatched++
+			continue
+		}
+
+		agentID := agent.ID
+		if err := s.attestationRepo.CreateAttestation(&domain.MCPAttestation{
+			ID:                uuid.New(),
+			MCPServerID:       server.ID,
+			AgentID:           &agentID,
+			AttestationData:   payload,
+			Signature:         imported.Signature,
+			SignatureVerified: true,
+			VerifiedAt:        imported.VerifiedAt,
+			ExpiresAt:         imported.ExpiresAt,
+			IsValid:           imported.IsValid && imported.ExpiresAt.After(now),
+			CreatedAt:         imported.CreatedAt,
+		}); err != nil {
+			log.Printf("⚠️  Failed to import attestation of agent %s: %v", agent.ID, err)
+			continue
+		}
+		result.AttestationsImported++
+		attested[server.ID] = append(attested[server.ID], imported.CreatedAt)
+	}
+
+	for serverID, times := range attested {
+		first, last := times[0], times[0]
+		for _, at := range times {
+			first, last = minTime(first, at), maxTime(last, at)
+		}
+		if err := s.attestationRepo.CreateConnection(&domain.AgentMCPConnection{
+			ID:               uuid.New(),
+			AgentID:          agent.ID,
+			MCPServerID:      serverID,
+			ConnectionType:   domain.ConnectionTypeAttested,
+			FirstConnectedAt: first,
+			LastAttestedAt:   &last,
+			AttestationCount: len(times),
+			IsActive:         true,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}); err != nil {
+			log.Printf("⚠️  Failed to connect imported agent %s to MCP server %s: %v", agent.ID, serverID, err)
+		}
+	}
+}
+
+// verifiesWithAgentKey checks the attestation's signature against the agent's current and previous keys
+func (s *AgentPortabilityService) verifiesWithAgentKey(portable *domain.PortableAgent, payload *domain.AttestationPayload, signature string) bool {
+	message, err := payload.ToCanonicalJSON()
+	if err != nil {
+		return false
+	}
+	for _, key := range []string{portable.PublicKey, portable.PreviousPublicKey} {
+		if key == "" {
+			continue
+		}
+		if valid, err := s.cryptoService.Verify(key, message, signature); err == nil && valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ListLineage returns the deployments an agent of the organization was imported from, oldest first
+func (s *AgentPortabilityService) ListLineage(ctx context.Context, orgID, agentID uuid.UUID) ([]*domain.AgentLineageEntry, error) {
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil || agent.OrganizationID != orgID {
+		return nil, ErrPortableAgentNotFound
+	}
+	return s.lineageRepo.ListByAgent(agentID)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,159 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityBundlesMoveAgentsBetweenDeploymentsWithTheirLineage(t *testing.T) {
+	ctx := context.Background()
+	selfHosted, cloud := testsupport.NewRepositories(), testsupport.NewRepositories()
+	selfHostedSigner, err := crypto.NewIdentityBundleSigner(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	cloudSigner, err := crypto.NewIdentityBundleSigner(bytes.Repeat([]byte{2}, 32), selfHostedSigner.PublicKey())
+	require.NoError(t, err)
+	newService := func(repos *testsupport.Repositories, signer *crypto.IdentityBundleSigner, name string) *AgentPortabilityService {
+		return NewAgentPortabilityService(
+			repos.Agent,
+			repos.TrustScore,
+			repos.MCPAttestation,
+			repos.MCPServer,
+			repos.AgentLineage,
+			NewSharedKeyService(repos.SharedKey, repos.Alert),
+			signer,
+			name,
+		)
+	}
+	selfHostedService := newService(selfHosted, selfHostedSigner, "https://aim.internal.example.com")
+	cloudService := newService(cloud, cloudSigner, "https://aim.example.com")
+
+	// A self-hosted agent with a trust history and one genuine and one forged attestation
+	sourceOrg := testsupport.NewOrganization()
+	require.NoError(t, selfHosted.Organization.Create(sourceOrg))
+	server := testsupport.NewMCPServer(sourceOrg.ID)
+	require.NoError(t, selfHosted.MCPServer.Create(server))
+	keyPair, err := crypto.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	publicKey := crypto.EncodeKeyPair(keyPair).PublicKeyBase64
+	agent := testsupport.NewAgent(sourceOrg.ID, func(a *domain.Agent) {
+		a.PublicKey = &publicKey
+		a.TrustScore = 0.9
+	})
+	require.NoError(t, selfHosted.Agent.Create(agent))
+	for i, score := range []float64{0.7, 0.9} {
+		require.NoError(t, selfHosted.TrustScore.Create(&domain.TrustScore{
+			ID:             uuid.New(),
+			AgentID:        agent.ID,
+			Score:          score,
+			Confidence:     0.8,
+			LastCalculated: time.Now(),
+			CreatedAt:      time.Now().Add(time.Duration(i-2) * time.Hour),
+		}))
+	}
+	genuine := testsupport.NewMCPAttestation(server, agent)
+	message, err := genuine.AttestationData.ToCanonicalJSON()
+	require.NoError(t, err)
+	genuine.Signature = base64.StdEncoding.EncodeToString(crypto.SignMessage(keyPair.PrivateKey, message))
+	require.NoError(t, selfHosted.MCPAttestation.CreateAttestation(genuine))
+	require.NoError(t, selfHosted.MCPAttestation.CreateAttestation(testsupport.NewMCPAttestation(server, agent)))
+
+	signed, err := selfHostedService.ExportIdentities(ctx, sourceOrg.ID, &ExportIdentitiesRequest{AgentIDs: []uuid.UUID{agent.ID}})
+	require.NoError(t, err)
+	_, err = selfHostedService.ExportIdentities(ctx, uuid.New(), &ExportIdentitiesRequest{AgentIDs: []uuid.UUID{agent.ID}})
+	assert.ErrorIs(t, err, ErrPortableAgentNotFound)
+
+	var bundle domain.IdentityBundle
+	require.NoError(t, json.Unmarshal(signed.Bundle, &bundle))
+	require.Len(t, bundle.Agents, 1)
+	history := bundle.Agents[0].TrustHistory
+	assert.Equal(t, 0.9, history.CurrentScore)
+	assert.Equal(t, 2, history.Samples)
+	assert.InDelta(t, 0.8, history.AverageScore, 1e-9)
+	assert.Equal(t, 0.7, history.MinScore)
+	assert.Len(t, bundle.Agents[0].Attestations, 2)
+
+	// The cloud has the same MCP server, under a differently written URL
+	cloudOrg := testsupport.NewOrganization()
+	require.NoError(t, cloud.Organization.Create(cloudOrg))
+	cloudServer := testsupport.NewMCPServer(cloudOrg.ID, func(s *domain.MCPServer) {
+		s.URL = strings.ToUpper(server.URL) + "/"
+	})
+	require.NoError(t, cloud.MCPServer.Create(cloudServer))
+	admin := testsupport.NewUser(cloudOrg.ID)
+	require.NoError(t, cloud.User.Create(admin))
+
+	// Tampered bundles and bundles from deployments the importer doesn't trust are refused
+	tampered := &domain.SignedIdentityBundle{
+		Bundle:    []byte(strings.Replace(string(signed.Bundle), `"isCompromised":false`, `"isCompromised":true`, 1)),
+		Signature: signed.Signature,
+	}
+	_, err = cloudService.ImportIdentities(ctx, cloudOrg.ID, admin.ID, tampered)
+	assert.ErrorIs(t, err, domain.ErrIdentityBundleInvalid)
+	cloudBundle, err := cloudService.ExportIdentities(ctx, cloudOrg.ID, &ExportIdentitiesRequest{})
+	require.NoError(t, err)
+	_, err = selfHostedService.ImportIdentities(ctx, sourceOrg.ID, uuid.New(), cloudBundle)
+	assert.ErrorIs(t, err, domain.ErrIdentityBundleUntrusted)
+
+	report, err := cloudService.ImportIdentities(ctx, cloudOrg.ID, admin.ID, signed)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+	require.Len(t, report.Agents, 1)
+	result := report.Agents[0]
+	require.Equal(t, IdentityImportImported, result.Status)
+	assert.Equal(t, 1, result.AttestationsImported)
+	assert.Equal(t, 1, result.AttestationsRejected, "the forged attestation does not verify with the agent's key")
+
+	imported, err := cloud.Agent.GetByID(*result.AgentID)
+	require.NoError(t, err)
+	assert.Equal(t, agent.Name, imported.Name)
+	assert.Equal(t, publicKey, *imported.PublicKey)
+	assert.Equal(t, 0.9, imported.TrustScore)
+	assert.NotNil(t, imported.VerifiedAt)
+	connection, err := cloud.MCPAttestation.GetConnectionByAgentAndMCP(imported.ID, cloudServer.ID)
+	require.NoError(t, err)
+	require.NotNil(t, connection)
+	assert.Equal(t, 1, connection.AttestationCount)
+
+	lineage, err := cloudService.ListLineage(ctx, cloudOrg.ID, imported.ID)
+	require.NoError(t, err)
+	require.Len(t, lineage, 1)
+	assert.Equal(t, "https://aim.internal.example.com", lineage[0].IssuerName)
+	assert.Equal(t, domain.PublicKeyFingerprint(selfHostedSigner.PublicKey()), lineage[0].IssuerKeyFingerprint)
+	assert.Equal(t, agent.ID, lineage[0].SourceAgentID)
+
+	// Importing again skips the agent, whose name is now taken
+	report, err = cloudService.ImportIdentities(ctx, cloudOrg.ID, admin.ID, signed)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Skipped)
+
+	// Moving the agent on carries its lineage along
+	movedOn, err := cloudService.ExportIdentities(ctx, cloudOrg.ID, &ExportIdentitiesRequest{AgentIDs: []uuid.UUID{imported.ID}})
+	require.NoError(t, err)
+	regionOrg := testsupport.NewOrganization()
+	require.NoError(t, cloud.Organization.Create(regionOrg))
+	regionServer := testsupport.NewMCPServer(regionOrg.ID, func(s *domain.MCPServer) { s.URL = server.URL })
+	require.NoError(t, cloud.MCPServer.Create(regionServer))
+	require.NoError(t, cloud.Agent.Delete(imported.ID))
+	report, err = cloudService.ImportIdentities(ctx, regionOrg.ID, admin.ID, movedOn)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Imported)
+	assert.Equal(t, 1, report.Agents[0].AttestationsImported, "attestations keep verifying with the agent's key")
+	lineage, err = cloudService.ListLineage(ctx, regionOrg.ID, *report.Agents[0].AgentID)
+	require.NoError(t, err)
+	require.Len(t, lineage, 2)
+	assert.Equal(t, agent.ID, lineage[0].SourceAgentID)
+	assert.Equal(t, imported.ID, lineage[1].SourceAgentID)
+	assert.Equal(t, "https://aim.example.com", lineage[1].IssuerName)
+}
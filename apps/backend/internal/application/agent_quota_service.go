@@ -0,0 +1,180 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrAgentRateLimited is returned when an agent has used up its verification throughput
+	ErrAgentRateLimited = errors.New("agent verification rate limit exceeded")
+	// ErrAgentConcurrencyLimited is returned when an agent already has its maximum number of requests in flight
+	ErrAgentConcurrencyLimited = errors.New("agent concurrency limit exceeded")
+)
+
+// agentQuotaTierTTL is how long an agent's plan tier is cached before it is looked up again
+const agentQuotaTierTTL = time.Minute
+
+// AgentQuotaService enforces the per-agent limits of the organization's plan tier, so a
+// single agent cannot starve its organization's (or the platform's) capacity. Limits are
+// tracked in memory per server instance.
+type AgentQuotaService struct {
+	agentRepo domain.AgentRepository
+	orgRepo   domain.OrganizationRepository
+	quotas    map[string]domain.AgentQuota
+	agents    map[uuid.UUID]*agentQuotaState
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+// agentQuotaState is the token bucket and in-flight count of one agent
+type agentQuotaState struct {
+	tier          string
+	tierCheckedAt time.Time
+	tokens        float64
+	refilledAt    time.Time
+	inFlight      int
+}
+
+// AgentQuotaUsage describes an agent's quota after a request was admitted or rejected
+type AgentQuotaUsage struct {
+	Tier       string
+	Quota      domain.AgentQuota
+	Remaining  int           // Verifications the agent can send right now
+	ResetAfter time.Duration // Until the agent's allowance is full again
+	RetryAfter time.Duration // Until the next request can be admitted, when rejected
+}
+
+// NewAgentQuotaService creates a new agent quota service. Tiers missing from quotas use
+// domain.DefaultAgentQuotas; organizations on an unknown tier get the free tier's limits.
+func NewAgentQuotaService(
+	agentRepo domain.AgentRepository,
+	orgRepo domain.OrganizationRepository,
+	quotas map[string]domain.AgentQuota,
+) *AgentQuotaService {
+	merged := make(map[string]domain.AgentQuota, len(domain.DefaultAgentQuotas))
+	for tier, quota := range domain.DefaultAgentQuotas {
+		merged[tier] = quota
+	}
+	for tier, quota := range quotas {
+		merged[tier] = quota
+	}
+
+	return &AgentQuotaService{
+		agentRepo: agentRepo,
+		orgRepo:   orgRepo,
+		quotas:    merged,
+		agents:    make(map[uuid.UUID]*agentQuotaState),
+		now:       time.Now,
+	}
+}
+
+// Acquire admits a request of the agent. Verification requests also consume the agent's
+// verification throughput. The returned release function must be called when the request
+// finishes. Unknown agents are not limited here; usage is nil for them.
+func (s *AgentQuotaService) Acquire(ctx context.Context, agentID uuid.UUID, verification bool) (*AgentQuotaUsage, func(), error) {
+	noop := func() {}
+
+	s.mu.Lock()
+	state, ok := s.agents[agentID]
+	stale := !ok || s.now().Sub(state.tierCheckedAt) > agentQuotaTierTTL
+	s.mu.Unlock()
+
+	// Look up the tier outside the lock; the repositories may be slow. A failed refresh
+	// keeps the tier already known.
+	var tier string
+	if stale {
+		var err error
+		if tier, err = s.lookupTier(agentID); err != nil && !ok {
+			return nil, noop, nil
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	state, ok = s.agents[agentID]
+	if !ok {
+		state = &agentQuotaState{refilledAt: now, tokens: -1}
+		s.agents[agentID] = state
+	}
+	if tier != "" {
+		state.tier = tier
+		state.tierCheckedAt = now
+	}
+	quota := s.quotaFor(state.tier)
+	capacity := float64(quota.VerificationsPerMinute + quota.Burst)
+	perSecond := float64(quota.VerificationsPerMinute) / 60
+
+	// Refill the bucket; a new agent starts full
+	if state.tokens < 0 {
+		state.tokens = capacity
+	} else {
+		state.tokens = math.Min(capacity, state.tokens+now.Sub(state.refilledAt).Seconds()*perSecond)
+	}
+	state.refilledAt = now
+
+	usage := &AgentQuotaUsage{Tier: state.tier, Quota: quota}
+	fill := func() {
+		usage.Remaining = int(math.Floor(state.tokens))
+		if perSecond > 0 {
+			usage.ResetAfter = time.Duration((capacity - state.tokens) / perSecond * float64(time.Second))
+		}
+	}
+
+	if quota.MaxConcurrent > 0 && state.inFlight >= quota.MaxConcurrent {
+		fill()
+		usage.RetryAfter = time.Second
+		return usage, noop, fmt.Errorf("%w: %d requests in flight", ErrAgentConcurrencyLimited, quota.MaxConcurrent)
+	}
+	if verification && quota.VerificationsPerMinute > 0 {
+		if state.tokens < 1 {
+			fill()
+			usage.RetryAfter = time.Duration((1 - state.tokens) / perSecond * float64(time.Second))
+			return usage, noop, fmt.Errorf("%w: %d verifications per minute", ErrAgentRateLimited, quota.VerificationsPerMinute)
+		}
+		state.tokens--
+	}
+	fill()
+
+	state.inFlight++
+	var once sync.Once
+	return usage, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			state.inFlight--
+		})
+	}, nil
+}
+
+// lookupTier returns the plan tier of the agent's organization
+func (s *AgentQuotaService) lookupTier(agentID uuid.UUID) (string, error) {
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil {
+		return "", err
+	}
+	org, err := s.orgRepo.GetByID(agent.OrganizationID)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := s.quotas[org.PlanType]; !ok {
+		return domain.PlanTierFree, nil
+	}
+	return org.PlanType, nil
+}
+
+func (s *AgentQuotaService) quotaFor(tier string) domain.AgentQuota {
+	if quota, ok := s.quotas[tier]; ok {
+		return quota
+	}
+	return s.quotas[domain.PlanTierFree]
+}
@@ -0,0 +1,64 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentQuotasFollowPlanTier(t *testing.T) {
+	ctx := context.Background()
+	repos := testsupport.NewRepositories()
+	service := NewAgentQuotaService(repos.Agent, repos.Organization, map[string]domain.AgentQuota{
+		domain.PlanTierFree: {VerificationsPerMinute: 2, Burst: 1, MaxConcurrent: 1},
+	})
+
+	freeOrg := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(freeOrg))
+	proOrg := testsupport.NewOrganization(func(o *domain.Organization) { o.PlanType = domain.PlanTierPro })
+	require.NoError(t, repos.Organization.Create(proOrg))
+	noisy := testsupport.NewAgent(freeOrg.ID)
+	require.NoError(t, repos.Agent.Create(noisy))
+	quiet := testsupport.NewAgent(freeOrg.ID)
+	require.NoError(t, repos.Agent.Create(quiet))
+	pro := testsupport.NewAgent(proOrg.ID)
+	require.NoError(t, repos.Agent.Create(pro))
+
+	// The per-minute rate plus the burst is available at once, then the agent is limited
+	for i := 0; i < 3; i++ {
+		usage, release, err := service.Acquire(ctx, noisy.ID, true)
+		require.NoError(t, err)
+		assert.Equal(t, 2-i, usage.Remaining)
+		release()
+	}
+	usage, release, err := service.Acquire(ctx, noisy.ID, true)
+	release()
+	assert.ErrorIs(t, err, ErrAgentRateLimited)
+	assert.Positive(t, usage.RetryAfter)
+
+	// Other agents of the organization keep their own allowance; non-verification requests are not counted
+	_, release, err = service.Acquire(ctx, quiet.ID, true)
+	require.NoError(t, err)
+	_, _, err = service.Acquire(ctx, quiet.ID, false)
+	assert.ErrorIs(t, err, ErrAgentConcurrencyLimited, "one request in flight on the free tier")
+	release()
+	_, release, err = service.Acquire(ctx, noisy.ID, false)
+	assert.NoError(t, err)
+	release()
+
+	// Tiers without configured limits use the defaults
+	usage, release, err = service.Acquire(ctx, pro.ID, true)
+	require.NoError(t, err)
+	release()
+	assert.Equal(t, domain.DefaultAgentQuotas[domain.PlanTierPro], usage.Quota)
+
+	usage, release, err = service.Acquire(ctx, uuid.New(), true)
+	release()
+	assert.NoError(t, err, "unknown agents are left to the handlers")
+	assert.Nil(t, usage)
+}
@@ -3,13 +3,16 @@ package application
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/crypto"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/tracing"
 )
 
 // AgentService handles agent business logic
@@ -17,11 +20,17 @@ type AgentService struct {
 	agentRepo                domain.AgentRepository
 	trustCalc                domain.TrustScoreCalculator
 	trustScoreRepo           domain.TrustScoreRepository
-	keyVault                 *crypto.KeyVault            // ✅ For secure private key storage
-	alertRepo                domain.AlertRepository      // ✅ For creating security alerts
-	policyService            *SecurityPolicyService      // ✅ For policy-based enforcement
-	capabilityRepo           domain.CapabilityRepository // ✅ For checking agent capabilities
-	verificationEventService *VerificationEventService   // ✅ For creating verification events
+	keyVault                 *crypto.KeyVault              // ✅ For secure private key storage
+	alertRepo                domain.AlertRepository        // ✅ For creating security alerts
+	policyService            *SecurityPolicyService        // ✅ For policy-based enforcement
+	capabilityRepo           domain.CapabilityRepository   // ✅ For checking agent capabilities
+	verificationEventService *VerificationEventService     // ✅ For creating verification events
+	policyDecisionService    *PolicyDecisionService        // ✅ For external policy decision points (OPA)
+	orgRepo                  domain.OrganizationRepository // ✅ For agent quota checks during registration
+	approvals                *ApprovalChainService         // ✅ For approval chains on agent verification
+	certificates             *AgentCertificateService      // ✅ For X.509 certificates of verified agents
+	sharedKeys               *SharedKeyService             // ✅ For blocking public keys registered to other agents
+	namingPolicies           *NamingPolicyService          // ✅ For the organization's naming policies
 }
 
 // NewAgentService creates a new agent service
@@ -34,6 +43,12 @@ func NewAgentService(
 	policyService *SecurityPolicyService, // ✅ NEW: Security Policy Service
 	capabilityRepo domain.CapabilityRepository, // ✅ NEW: CapabilityRepository for capability checks
 	verificationEventService *VerificationEventService, // ✅ NEW: For creating verification events
+	policyDecisionService *PolicyDecisionService, // ✅ NEW: For delegating decisions to an external PDP (OPA)
+	orgRepo domain.OrganizationRepository, // ✅ NEW: For enforcing the organization's agent quota
+	approvals *ApprovalChainService, // ✅ NEW: For multi-step approval of agent verification
+	certificates *AgentCertificateService, // ✅ NEW: For issuing and revoking X.509 agent certificates
+	sharedKeys *SharedKeyService, // ✅ NEW: For blocking registrations that reuse another agent's public key
+	namingPolicies *NamingPolicyService, // ✅ NEW: For enforcing the organization's naming policies
 ) *AgentService {
 	return &AgentService{
 		agentRepo:                agentRepo,
@@ -44,33 +59,160 @@ func NewAgentService(
 		policyService:            policyService,
 		capabilityRepo:           capabilityRepo,
 		verificationEventService: verificationEventService,
+		policyDecisionService:    policyDecisionService,
+		orgRepo:                  orgRepo,
+		approvals:                approvals,
+		certificates:             certificates,
+		sharedKeys:               sharedKeys,
+		namingPolicies:           namingPolicies,
 	}
 }
 
 // CreateAgentRequest represents agent creation request
 type CreateAgentRequest struct {
-	Name             string           `json:"name"`
-	DisplayName      string           `json:"displayName"`
-	Description      string           `json:"description"`
-	AgentType        domain.AgentType `json:"agentType"`
-	Version          string           `json:"version"`
-	PublicKey        string           `json:"publicKey,omitempty"` // ✅ OPTIONAL: SDK can provide its own public key
-	CertificateURL   string           `json:"certificateUrl"`
-	RepositoryURL    string           `json:"repositoryUrl"`
-	DocumentationURL string           `json:"documentationUrl"`
-	TalksTo          []string         `json:"talksTo,omitempty"`      // MCP servers this agent communicates with
-	Capabilities     []string         `json:"capabilities,omitempty"` // Agent capabilities
+	Name        string           `json:"name"`
+	DisplayName string           `json:"displayName"`
+	Description string           `json:"description"`
+	AgentType   domain.AgentType `json:"agentType"`
+	Version     string           `json:"version"`
+	PublicKey   string           `json:"publicKey,omitempty"` // ✅ OPTIONAL: SDK can provide its own public key
+	// Algorithm of publicKey (Ed25519, ECDSA-P256 or RSA-PSS); detected from the key when omitted.
+	// Keys generated server-side are Ed25519.
+	KeyAlgorithm string `json:"keyAlgorithm,omitempty"`
+	// Base64 signature of crypto.ProofOfPossessionMessage(name, publicKey) made with the new key;
+	// required when the organization generates keys client-side
+	ProofOfPossession string   `json:"proofOfPossession,omitempty"`
+	CertificateURL    string   `json:"certificateUrl"`
+	RepositoryURL     string   `json:"repositoryUrl"`
+	DocumentationURL  string   `json:"documentationUrl"`
+	TalksTo           []string `json:"talksTo,omitempty"`      // MCP servers this agent communicates with
+	Capabilities      []string `json:"capabilities,omitempty"` // Agent capabilities
+}
+
+// validateAgentRegistration runs every check an agent registration must pass:
+// required fields, agent type, name uniqueness and naming policies, the organization's
+// agent quota, key format, key reuse and capability taxonomy
+func (s *AgentService) validateAgentRegistration(ctx context.Context, req *CreateAgentRequest, orgID uuid.UUID) (*RegistrationValidation, error) {
+	validation := newRegistrationValidation()
+
+	validateRegistrationName(validation, "name", req.Name)
+	validateRegistrationName(validation, "displayName", req.DisplayName)
+
+	if req.AgentType != domain.AgentTypeAI && req.AgentType != domain.AgentTypeMCP {
+		validation.addError("agentType", RegistrationIssueInvalidValue,
+			fmt.Sprintf("agentType must be %q or %q", domain.AgentTypeAI, domain.AgentTypeMCP))
+	}
+
+	if req.Name != "" {
+		if existing, _ := s.agentRepo.GetByName(orgID, req.Name); existing != nil {
+			validation.addError("name", RegistrationIssueNameTaken, fmt.Sprintf("an agent named %q already exists", req.Name))
+		}
+	}
+	if err := validateRegistrationNamingPolicies(ctx, validation, s.namingPolicies, orgID, domain.NamingResourceAgent, req.Name); err != nil {
+		return nil, err
+	}
+
+	clientSideKeys := false
+	var org *domain.Organization
+	if s.orgRepo != nil {
+		var err error
+		org, err = s.orgRepo.GetByID(orgID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get organization: %w", err)
+		}
+		clientSideKeys = org.ClientSideKeyGeneration
+		// A non-positive limit means the organization has no agent quota
+		if org.MaxAgents > 0 {
+			agents, err := s.agentRepo.GetByOrganization(orgID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to count agents: %w", err)
+			}
+			if len(agents) >= org.MaxAgents {
+				validation.addError("organization", RegistrationIssueQuotaExceeded,
+					fmt.Sprintf("organization has reached its limit of %d agents", org.MaxAgents))
+			}
+		}
+	}
+
+	if req.PublicKey != "" {
+		if algorithm := validateRegistrationPublicKey(validation, req.KeyAlgorithm, req.PublicKey); algorithm != "" {
+			if org != nil && !org.AllowsKeyAlgorithm(algorithm) {
+				validation.addError("keyAlgorithm", RegistrationIssueKeyAlgorithm,
+					fmt.Sprintf("the organization does not allow %s keys; allowed algorithms are %s", algorithm, strings.Join(org.AllowedKeyAlgorithms(), ", ")))
+			}
+			validateRegistrationKeyPossession(validation, algorithm, req.Name, req.PublicKey, req.ProofOfPossession, clientSideKeys)
+		}
+		if s.sharedKeys != nil {
+			if err := s.sharedKeys.CheckPublicKey(ctx, orgID, uuid.Nil, req.PublicKey); errors.Is(err, ErrPublicKeyInUse) {
+				validation.addError("publicKey", RegistrationIssueKeyInUse,
+					"publicKey is already registered to another agent; generate a new key pair, or allowlist the key if sharing it is intended")
+			} else if err != nil {
+				return nil, err
+			}
+		}
+	} else if clientSideKeys {
+		validation.addError("publicKey", RegistrationIssueClientSideKey,
+			"the organization generates agent keys client-side; publicKey and proofOfPossession are required")
+	} else if algorithm, _ := domain.NormalizeKeyAlgorithm(req.KeyAlgorithm); algorithm != domain.KeyAlgorithmEd25519 {
+		validation.addError("publicKey", RegistrationIssueRequired,
+			fmt.Sprintf("keys generated server-side are Ed25519; supply the publicKey of your %s key pair", req.KeyAlgorithm))
+	} else if org != nil && !org.AllowsKeyAlgorithm(domain.KeyAlgorithmEd25519) {
+		validation.addError("publicKey", RegistrationIssueKeyAlgorithm,
+			fmt.Sprintf("the organization does not allow the Ed25519 keys generated server-side; supply the publicKey of a %s key pair", strings.Join(org.AllowedKeyAlgorithms(), " or ")))
+	} else {
+		validation.addWarning("publicKey", RegistrationIssueKeyGenerated,
+			"no publicKey provided; an Ed25519 key pair will be generated server-side on registration")
+	}
+
+	validateRegistrationCapabilities(validation, req.Capabilities, true)
+
+	return validation, nil
+}
+
+// ValidateAgentRegistration runs every registration check without persisting anything and
+// returns the agent that CreateAgent would create. Used by validate=true pre-flight requests.
+func (s *AgentService) ValidateAgentRegistration(ctx context.Context, req *CreateAgentRequest, orgID, userID uuid.UUID) (*domain.Agent, *RegistrationValidation, error) {
+	validation, err := s.validateAgentRegistration(ctx, req, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	agent := &domain.Agent{
+		OrganizationID:   orgID,
+		Name:             req.Name,
+		DisplayName:      req.DisplayName,
+		Description:      req.Description,
+		AgentType:        req.AgentType,
+		Version:          req.Version,
+		KeyAlgorithm:     domain.KeyAlgorithmEd25519,
+		CertificateURL:   req.CertificateURL,
+		RepositoryURL:    req.RepositoryURL,
+		DocumentationURL: req.DocumentationURL,
+		TalksTo:          req.TalksTo,
+		Capabilities:     req.Capabilities,
+		Status:           domain.AgentStatusVerified,
+		CreatedBy:        userID,
+	}
+	if req.PublicKey != "" {
+		publicKey := req.PublicKey
+		agent.PublicKey = &publicKey
+		if algorithm, err := publicKeyAlgorithm(req.KeyAlgorithm, req.PublicKey); err == nil {
+			agent.KeyAlgorithm = algorithm
+		}
+	}
+
+	return agent, validation, nil
 }
 
 // CreateAgent creates a new agent
 func (s *AgentService) CreateAgent(ctx context.Context, req *CreateAgentRequest, orgID, userID uuid.UUID) (*domain.Agent, error) {
-	// Validate inputs
-	if req.Name == "" || req.DisplayName == "" {
-		return nil, fmt.Errorf("name and display_name are required")
+	// Validate inputs (the same checks a validate=true dry run reports)
+	validation, err := s.validateAgentRegistration(ctx, req, orgID)
+	if err != nil {
+		return nil, err
 	}
-
-	if req.AgentType != domain.AgentTypeAI && req.AgentType != domain.AgentTypeMCP {
-		return nil, fmt.Errorf("invalid agent_type")
+	if !validation.Valid {
+		return nil, &RegistrationValidationError{Validation: validation}
 	}
 
 	// ✅ KEY MANAGEMENT - Support both SDK-provided and auto-generated keys
@@ -82,7 +224,10 @@ func (s *AgentService) CreateAgent(ctx context.Context, req *CreateAgentRequest,
 		// SDK provided its own public key (client-side keypair generation)
 		// This is more secure as the private key never leaves the client
 		publicKeyBase64 = req.PublicKey
-		keyAlgorithm = "Ed25519"
+		keyAlgorithm, err = publicKeyAlgorithm(req.KeyAlgorithm, req.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
 		// No private key to store - SDK keeps it client-side
 		encryptedPrivateKey = ""
 	} else {
@@ -119,12 +264,12 @@ func (s *AgentService) CreateAgent(ctx context.Context, req *CreateAgentRequest,
 		AgentType:        req.AgentType,
 		Version:          req.Version,
 		PublicKey:        &publicKeyBase64, // ✅ Stored for verification (SDK-provided or generated)
-		KeyAlgorithm:     keyAlgorithm,     // ✅ "Ed25519"
+		KeyAlgorithm:     keyAlgorithm,     // ✅ Ed25519, ECDSA-P256 or RSA-PSS
 		CertificateURL:   req.CertificateURL,
 		RepositoryURL:    req.RepositoryURL,
 		DocumentationURL: req.DocumentationURL,
-		TalksTo:          req.TalksTo,      // MCP servers this agent communicates with
-		Capabilities:     req.Capabilities, // ✅ Store detected capabilities from SDK
+		TalksTo:          req.TalksTo,                // MCP servers this agent communicates with
+		Capabilities:     req.Capabilities,           // ✅ Store detected capabilities from SDK
 		Status:           domain.AgentStatusVerified, // ✅ Auto-verified for authenticated users
 		CreatedBy:        userID,
 	}
@@ -155,7 +300,8 @@ func (s *AgentService) CreateAgent(ctx context.Context, req *CreateAgentRequest,
 
 	// ✅ AUTO-VERIFICATION: Automatically verify agent if it meets basic criteria
 	// This eliminates manual verification step for legitimate agents
-	shouldAutoVerify := s.shouldAutoVerifyAgent(agent)
+	// (a proof of possession was checked during validation)
+	shouldAutoVerify := s.shouldAutoVerifyAgent(agent, req.PublicKey != "" && req.ProofOfPossession != "")
 	if shouldAutoVerify {
 		now := time.Now()
 		agent.Status = domain.AgentStatusVerified
@@ -197,6 +343,9 @@ func (s *AgentService) CreateAgent(ctx context.Context, req *CreateAgentRequest,
 			s.trustScoreRepo.Create(updatedTrustScore)
 			fmt.Printf("✅ Updated trust score after verification: %.2f\n", agent.TrustScore)
 		}
+
+		// ✅ Issue the agent's X.509 certificate
+		s.issueCertificate(ctx, agent)
 	}
 
 	// ✅ AUTO-GRANT CAPABILITIES: Auto-grant declared capabilities during registration
@@ -229,12 +378,13 @@ func (s *AgentService) CreateAgent(ctx context.Context, req *CreateAgentRequest,
 
 // shouldAutoVerifyAgent determines if an agent meets criteria for automatic verification
 // Auto-verification criteria:
-// 1. Has valid cryptographic keys (public + encrypted private key)
-// 2. Trust score >= 0.3 (30% minimum threshold)
-// 3. Has required metadata (name, description, type)
-func (s *AgentService) shouldAutoVerifyAgent(agent *domain.Agent) bool {
+//  1. Has valid cryptographic keys (public + encrypted private key, or a client-side public key
+//     whose possession was proven)
+//  2. Trust score >= 0.3 (30% minimum threshold)
+//  3. Has required metadata (name, description, type)
+func (s *AgentService) shouldAutoVerifyAgent(agent *domain.Agent, keyPossessionProven bool) bool {
 	// ✅ Check 1: Must have cryptographic keys
-	if agent.PublicKey == nil || agent.EncryptedPrivateKey == nil {
+	if agent.PublicKey == nil || (agent.EncryptedPrivateKey == nil && !keyPossessionProven) {
 		fmt.Printf("⚠️  Agent %s cannot be auto-verified: missing cryptographic keys\n", agent.Name)
 		return false
 	}
@@ -260,6 +410,28 @@ func (s *AgentService) GetAgent(ctx context.Context, id uuid.UUID) (*domain.Agen
 	return s.agentRepo.GetByID(id)
 }
 
+// GetAgentsByIDs returns the organization's agents with the given IDs in one query.
+// IDs that do not exist or belong to another organization are skipped.
+func (s *AgentService) GetAgentsByIDs(ctx context.Context, orgID uuid.UUID, ids []uuid.UUID) ([]*domain.Agent, error) {
+	ids, err := uniqueBatchIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	agents, err := s.agentRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agents: %w", err)
+	}
+
+	result := make([]*domain.Agent, 0, len(agents))
+	for _, agent := range agents {
+		if agent.OrganizationID == orgID {
+			result = append(result, agent)
+		}
+	}
+	return result, nil
+}
+
 // ListAgents lists agents for an organization
 func (s *AgentService) ListAgents(ctx context.Context, orgID uuid.UUID) ([]*domain.Agent, error) {
 	return s.agentRepo.GetByOrganization(orgID)
@@ -364,14 +536,42 @@ func (s *AgentService) UpdateAgent(ctx context.Context, id uuid.UUID, req *Creat
 
 // DeleteAgent deletes an agent
 func (s *AgentService) DeleteAgent(ctx context.Context, id uuid.UUID) error {
-	return s.agentRepo.Delete(id)
+	if err := s.agentRepo.Delete(id); err != nil {
+		return err
+	}
+	s.revokeCertificates(ctx, id, domain.CertificateRevocationCessationOfOperation)
+	return nil
 }
 
-// VerifyAgent verifies an agent
-func (s *AgentService) VerifyAgent(ctx context.Context, id uuid.UUID) error {
+// VerifyAgent verifies an agent on behalf of the user. A non-nil approval request that is still
+// pending means the agent stays unverified until the remaining approvals arrive.
+func (s *AgentService) VerifyAgent(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*domain.ApprovalRequest, error) {
 	agent, err := s.agentRepo.GetByID(id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if agent.Status == domain.AgentStatusRevoked {
+		return nil, ErrAgentRevoked
+	}
+
+	// Agents in scope of an approval chain (e.g. tagged environment:prod) are only verified
+	// once every step of the chain is approved; each call records one approval
+	var approval *domain.ApprovalRequest
+	if s.approvals != nil {
+		approval, err = s.approvals.Approve(ctx, ApprovalSubject{
+			OrganizationID: agent.OrganizationID,
+			Action:         domain.ApprovalActionAgentVerification,
+			ResourceType:   "agent",
+			ResourceID:     agent.ID,
+			Summary:        fmt.Sprintf("Verify agent '%s'", agent.Name),
+			AgentID:        &agent.ID,
+		}, userID, "")
+		if err != nil {
+			return approval, err
+		}
+		if approval != nil && approval.Status != domain.ApprovalRequestStatusApproved {
+			return approval, nil
+		}
 	}
 
 	now := time.Now()
@@ -379,8 +579,9 @@ func (s *AgentService) VerifyAgent(ctx context.Context, id uuid.UUID) error {
 	agent.VerifiedAt = &now
 
 	if err := s.agentRepo.Update(agent); err != nil {
-		return fmt.Errorf("failed to verify agent: %w", err)
+		return approval, fmt.Errorf("failed to verify agent: %w", err)
 	}
+	s.issueCertificate(ctx, agent)
 
 	// Recalculate trust score
 	trustScore, err := s.trustCalc.Calculate(agent)
@@ -390,7 +591,7 @@ func (s *AgentService) VerifyAgent(ctx context.Context, id uuid.UUID) error {
 		s.trustScoreRepo.Create(trustScore)
 	}
 
-	return nil
+	return approval, nil
 }
 
 // RecalculateTrustScore recalculates trust score for an agent
@@ -514,7 +715,13 @@ func (s *AgentService) checkAndCreateTrustScoreDropAlert(ctx context.Context, ag
 
 // CreateSecurityAlert creates a security alert in the database
 func (s *AgentService) CreateSecurityAlert(ctx context.Context, alert *domain.Alert) error {
-	return s.alertRepo.Create(alert)
+	ctx, span := tracing.Start(ctx, "AgentService.CreateSecurityAlert",
+		tracing.String("aim.alert_type", string(alert.AlertType)),
+		tracing.String("aim.alert_severity", string(alert.Severity)))
+	defer span.End()
+	err := traceRepository(ctx, "AlertRepository", "Create", func() error { return s.alertRepo.Create(alert) })
+	span.RecordError(err)
+	return err
 }
 
 // HasCapability checks if an agent has a specific capability
@@ -551,9 +758,21 @@ func (s *AgentService) VerifyAction(
 	metadata map[string]interface{},
 ) (allowed bool, reason string, auditID uuid.UUID, err error) {
 	auditID = uuid.New()
+	ctx, span := tracing.Start(ctx, "AgentService.VerifyAction",
+		tracing.String("aim.agent_id", agentID.String()),
+		tracing.String("aim.action_type", actionType))
+	defer func() {
+		span.SetAttributes(tracing.Bool("aim.allowed", allowed))
+		span.RecordError(err)
+		span.End()
+	}()
 
 	// 1. Fetch agent
-	agent, err := s.agentRepo.GetByID(agentID)
+	var agent *domain.Agent
+	err = traceRepository(ctx, "AgentRepository", "GetByID", func() (err error) {
+		agent, err = s.agentRepo.GetByID(agentID)
+		return err
+	})
 	if err != nil {
 		return false, "Agent not found", uuid.Nil, err
 	}
@@ -568,6 +787,39 @@ func (s *AgentService) VerifyAction(
 		return false, "Agent is marked as compromised - all actions denied", auditID, nil
 	}
 
+	// 4-7. Capability-based access control and native security policies
+	allowed, reason, err = s.evaluateActionPolicies(ctx, agent, actionType, resource, metadata, auditID)
+	if err != nil || s.policyDecisionService == nil {
+		return allowed, reason, auditID, err
+	}
+
+	// 8. ✅ EXTERNAL POLICY DECISION POINT (OPA)
+	// Organizations that standardize on OPA get the final say. The native result is part
+	// of the input document and is recorded next to the external decision.
+	allowed, reason = s.policyDecisionService.DecideAction(ctx, &ActionDecisionInput{
+		Agent:         agent,
+		ActionType:    actionType,
+		Resource:      resource,
+		Metadata:      metadata,
+		AuditID:       auditID,
+		NativeAllowed: allowed,
+		NativeReason:  reason,
+	})
+	return allowed, reason, auditID, nil
+}
+
+// evaluateActionPolicies runs capability-based access control and the native security policies
+// for an agent that already passed the identity checks in VerifyAction
+func (s *AgentService) evaluateActionPolicies(
+	ctx context.Context,
+	agent *domain.Agent,
+	actionType string,
+	resource string,
+	metadata map[string]interface{},
+	auditID uuid.UUID,
+) (bool, string, error) {
+	agentID := agent.ID
+
 	// 4. ✅ CAPABILITY-BASED ACCESS CONTROL (CBAC)
 	// This is what prevents EchoLeak and similar attacks
 	//
@@ -588,7 +840,7 @@ func (s *AgentService) VerifyAction(
 	// ✅ Fetch GRANTED capabilities (single source of truth for enforcement)
 	activeCapabilities, err := s.capabilityRepo.GetActiveCapabilitiesByAgentID(agentID)
 	if err != nil {
-		return false, fmt.Sprintf("Failed to fetch agent capabilities: %v", err), auditID, err
+		return false, fmt.Sprintf("Failed to fetch agent capabilities: %v", err), err
 	}
 
 	// Build list of granted capability types for error messages
@@ -604,7 +856,7 @@ func (s *AgentService) VerifyAction(
 
 	// ⚠️  CRITICAL: If agent has NO GRANTED capabilities, DENY ALL actions
 	if len(capabilityTypes) == 0 {
-		return false, "Agent has no granted capabilities - action denied (admin must grant capabilities first)", auditID, nil
+		return false, "Agent has no granted capabilities - action denied (admin must grant capabilities first)", nil
 	}
 
 	if !hasCapability {
@@ -706,7 +958,7 @@ func (s *AgentService) VerifyAction(
 			return false, fmt.Sprintf(
 				"Capability violation blocked by security policy '%s': Agent does not have permission for action '%s' (allowed: %v)",
 				policyName, actionType, capabilityTypes,
-			), auditID, nil
+			), nil
 		} else {
 			// Policy says alert-only mode - allow the action but log it
 			fmt.Printf("⚠️  Capability violation ALLOWED by policy '%s' (alert-only mode): %s attempting %s\n",
@@ -714,7 +966,7 @@ func (s *AgentService) VerifyAction(
 			return true, fmt.Sprintf(
 				"Action allowed by security policy '%s' (alert-only mode) - capability violation logged",
 				policyName,
-			), auditID, nil
+			), nil
 		}
 	}
 
@@ -736,7 +988,7 @@ func (s *AgentService) VerifyAction(
 		return false, fmt.Sprintf(
 			"Action blocked by trust score policy '%s': Agent trust score too low (%.2f)",
 			trustScorePolicyName, agent.TrustScore,
-		), auditID, nil
+		), nil
 	}
 
 	// 6.2 Data Exfiltration Policy Evaluation
@@ -755,7 +1007,7 @@ func (s *AgentService) VerifyAction(
 		return false, fmt.Sprintf(
 			"Action blocked by data exfiltration policy '%s': Suspicious pattern detected",
 			exfilPolicyName,
-		), auditID, nil
+		), nil
 	}
 
 	// 6.3 Unusual Activity Policy Evaluation (stub - needs historical data)
@@ -773,7 +1025,7 @@ func (s *AgentService) VerifyAction(
 		return false, fmt.Sprintf(
 			"Action blocked by unusual activity policy '%s'",
 			unusualPolicyName,
-		), auditID, nil
+		), nil
 	}
 
 	// 6.4 Config Drift Policy Evaluation (stub - needs baseline)
@@ -791,7 +1043,7 @@ func (s *AgentService) VerifyAction(
 		return false, fmt.Sprintf(
 			"Action blocked by config drift policy '%s'",
 			driftPolicyName,
-		), auditID, nil
+		), nil
 	}
 
 	// 6.5 Unauthorized Access Policy Evaluation (stub)
@@ -809,11 +1061,43 @@ func (s *AgentService) VerifyAction(
 		return false, fmt.Sprintf(
 			"Action blocked by unauthorized access policy '%s'",
 			unauthPolicyName,
-		), auditID, nil
+		), nil
+	}
+
+	// 6.6 Sensitive MCP Tool Policy Evaluation (destructive / exfiltration-capable tools)
+	toolDecision, err := s.policyService.EvaluateSensitiveTool(ctx, agent, actionType, metadata)
+	if err != nil {
+		fmt.Printf("⚠️  Sensitive tool policy evaluation failed: %v\n", err)
+	}
+	if toolDecision != nil {
+		if toolDecision.ShouldAlert {
+			s.createPolicyAlert(agent, "Sensitive MCP Tool Use", toolDecision.PolicyName, toolDecision.ShouldBlock,
+				toolDecision.Reason, domain.AlertSeverityHigh, auditID)
+		}
+		if toolDecision.RequiresApproval {
+			return false, fmt.Sprintf("%s by sensitive tool policy '%s': %s",
+				ActionApprovalRequired, toolDecision.PolicyName, toolDecision.Reason), nil
+		}
+		if toolDecision.ShouldBlock {
+			return false, fmt.Sprintf(
+				"Action blocked by sensitive tool policy '%s': %s",
+				toolDecision.PolicyName, toolDecision.Reason,
+			), nil
+		}
 	}
 
 	// 7. ✅ ALL POLICIES PASSED - Action is allowed
-	return true, "Action matches registered capabilities and passes all security policies", auditID, nil
+	return true, "Action matches registered capabilities and passes all security policies", nil
+}
+
+// ActionApprovalRequired starts the reason of actions that are held for just-in-time admin
+// approval rather than denied; see IsApprovalRequired
+const ActionApprovalRequired = "Approval required"
+
+// IsApprovalRequired reports whether a VerifyAction denial can be lifted by an admin
+// approving the pending verification
+func IsApprovalRequired(reason string) bool {
+	return strings.HasPrefix(reason, ActionApprovalRequired)
 }
 
 // matchesCapability checks if an action matches a registered capability
@@ -932,9 +1216,12 @@ func (s *AgentService) GetAgentCredentials(ctx context.Context, agentID uuid.UUI
 		return "", "", fmt.Errorf("agent not found: %w", err)
 	}
 
-	if agent.PublicKey == nil || agent.EncryptedPrivateKey == nil {
+	if agent.PublicKey == nil {
 		return "", "", fmt.Errorf("agent keys not generated")
 	}
+	if agent.EncryptedPrivateKey == nil {
+		return "", "", ErrPrivateKeyNotEscrowed
+	}
 
 	// Decrypt private key
 	privateKeyBase64, err := s.keyVault.DecryptPrivateKey(*agent.EncryptedPrivateKey)
@@ -1305,6 +1592,7 @@ func (s *AgentService) SuspendAgent(ctx context.Context, id uuid.UUID) error {
 	if err := s.agentRepo.Update(agent); err != nil {
 		return fmt.Errorf("failed to suspend agent: %w", err)
 	}
+	s.revokeCertificates(ctx, agent.ID, domain.CertificateRevocationCessationOfOperation)
 
 	// Recalculate trust score (suspension affects trust)
 	trustScore, err := s.trustCalc.Calculate(agent)
@@ -1323,6 +1611,9 @@ func (s *AgentService) ReactivateAgent(ctx context.Context, id uuid.UUID) error
 	if err != nil {
 		return fmt.Errorf("agent not found: %w", err)
 	}
+	if agent.Status == domain.AgentStatusRevoked {
+		return ErrAgentRevoked
+	}
 
 	// Update status to verified
 	now := time.Now()
@@ -1332,6 +1623,7 @@ func (s *AgentService) ReactivateAgent(ctx context.Context, id uuid.UUID) error
 	if err := s.agentRepo.Update(agent); err != nil {
 		return fmt.Errorf("failed to reactivate agent: %w", err)
 	}
+	s.issueCertificate(ctx, agent)
 
 	// Recalculate trust score (reactivation affects trust)
 	trustScore, err := s.trustCalc.Calculate(agent)
@@ -1345,54 +1637,13 @@ func (s *AgentService) ReactivateAgent(ctx context.Context, id uuid.UUID) error
 }
 
 // RotateCredentials rotates an agent's cryptographic credentials by generating new Ed25519 keypair
+// The previous key keeps verifying for DefaultKeyRotationGracePeriod (see RotateKey)
 func (s *AgentService) RotateCredentials(ctx context.Context, id uuid.UUID) (publicKey, privateKey string, err error) {
-	// 1. Fetch agent
-	agent, err := s.agentRepo.GetByID(id)
+	result, err := s.RotateKey(ctx, id, &RotateKeyRequest{})
 	if err != nil {
-		return "", "", fmt.Errorf("agent not found: %w", err)
+		return "", "", err
 	}
-
-	// 2. Generate new Ed25519 key pair
-	keyPair, err := crypto.GenerateEd25519KeyPair()
-	if err != nil {
-		return "", "", fmt.Errorf("failed to generate new cryptographic keys: %w", err)
-	}
-
-	// 3. Encode keys to base64
-	encodedKeys := crypto.EncodeKeyPair(keyPair)
-
-	// 4. Encrypt new private key before storing
-	encryptedPrivateKey, err := s.keyVault.EncryptPrivateKey(encodedKeys.PrivateKeyBase64)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to encrypt private key: %w", err)
-	}
-
-	// 5. Store previous public key for grace period (allows existing SDKs to work temporarily)
-	if agent.PublicKey != nil {
-		agent.PreviousPublicKey = agent.PublicKey
-	}
-
-	// 6. Update agent with new keys
-	agent.PublicKey = &encodedKeys.PublicKeyBase64
-	agent.EncryptedPrivateKey = &encryptedPrivateKey
-	agent.KeyAlgorithm = encodedKeys.Algorithm
-	now := time.Now()
-	agent.KeyCreatedAt = &now
-
-	// Set key expiration to 1 year from now (standard practice)
-	keyExpiry := time.Now().AddDate(1, 0, 0)
-	agent.KeyExpiresAt = &keyExpiry
-
-	// Increment rotation count
-	agent.RotationCount++
-
-	// 7. Update agent in database
-	if err := s.agentRepo.Update(agent); err != nil {
-		return "", "", fmt.Errorf("failed to update agent credentials: %w", err)
-	}
-
-	// 8. Return new credentials (for immediate use by caller)
-	return encodedKeys.PublicKeyBase64, encodedKeys.PrivateKeyBase64, nil
+	return *result.Agent.PublicKey, result.PrivateKey, nil
 }
 
 // UpdateAgentPublicKey allows SDK to register/update its own public key
@@ -1404,10 +1655,23 @@ func (s *AgentService) UpdateAgentPublicKey(ctx context.Context, agentID uuid.UU
 		return fmt.Errorf("agent not found: %w", err)
 	}
 
-	// 2. Validate public key format (should be base64-encoded 32-byte Ed25519 public key)
+	// 2. Validate public key format (a base64-encoded Ed25519, ECDSA P-256 or RSA public key)
 	if publicKey == "" {
 		return fmt.Errorf("public_key is required")
 	}
+	keyAlgorithm, err := publicKeyAlgorithm("", publicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+	if s.orgRepo != nil {
+		org, err := s.orgRepo.GetByID(agent.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("failed to get organization: %w", err)
+		}
+		if !org.AllowsKeyAlgorithm(keyAlgorithm) {
+			return fmt.Errorf("%w: %s", domain.ErrKeyAlgorithmNotAllowed, keyAlgorithm)
+		}
+	}
 
 	// 3. Store previous public key for grace period
 	if agent.PublicKey != nil {
@@ -1416,7 +1680,7 @@ func (s *AgentService) UpdateAgentPublicKey(ctx context.Context, agentID uuid.UU
 
 	// 4. Update agent with new public key
 	agent.PublicKey = &publicKey
-	agent.KeyAlgorithm = "Ed25519"
+	agent.KeyAlgorithm = keyAlgorithm
 	now := time.Now()
 	agent.KeyCreatedAt = &now
 
@@ -1431,10 +1695,50 @@ func (s *AgentService) UpdateAgentPublicKey(ctx context.Context, agentID uuid.UU
 	if err := s.agentRepo.Update(agent); err != nil {
 		return fmt.Errorf("failed to update agent public key: %w", err)
 	}
+	s.reissueCertificate(ctx, agent)
 
 	return nil
 }
 
+// issueCertificate issues the agent a new X.509 certificate once it is verified
+func (s *AgentService) issueCertificate(ctx context.Context, agent *domain.Agent) {
+	if s.certificates == nil || agent.Status != domain.AgentStatusVerified {
+		return
+	}
+	if _, err := s.certificates.Issue(ctx, agent); err != nil {
+		fmt.Printf("⚠️  Warning: failed to issue certificate for agent %s: %v\n", agent.ID, err)
+	}
+}
+
+// reissueCertificate replaces the agent's certificate after its key changed; certificates of
+// unverified agents are only revoked
+func (s *AgentService) reissueCertificate(ctx context.Context, agent *domain.Agent) {
+	if agent.Status == domain.AgentStatusVerified {
+		s.issueCertificate(ctx, agent)
+		return
+	}
+	s.revokeCertificates(ctx, agent.ID, domain.CertificateRevocationSuperseded)
+}
+
+// revokeCertificates revokes every certificate of the agent
+func (s *AgentService) revokeCertificates(ctx context.Context, agentID uuid.UUID, reason domain.CertificateRevocationReason) {
+	if s.certificates == nil {
+		return
+	}
+	if _, err := s.certificates.Revoke(ctx, agentID, reason); err != nil {
+		fmt.Printf("⚠️  Warning: failed to revoke certificates of agent %s: %v\n", agentID, err)
+	}
+}
+
+// CheckCertificateRevocation fails with domain.ErrAgentCertificateRevoked when the public key's
+// certificate is on the revocation list
+func (s *AgentService) CheckCertificateRevocation(ctx context.Context, agent *domain.Agent, publicKey string) error {
+	if s.certificates == nil {
+		return nil
+	}
+	return s.certificates.CheckRevocation(ctx, agent, publicKey)
+}
+
 // UpdateLastActive updates the last_active timestamp for an agent
 func (s *AgentService) UpdateLastActive(ctx context.Context, agentID uuid.UUID) error {
 	return s.agentRepo.UpdateLastActive(ctx, agentID)
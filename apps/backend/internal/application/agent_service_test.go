@@ -2,6 +2,7 @@ package application
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"testing"
 	"time"
@@ -9,8 +10,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/crypto"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // ===========================
@@ -64,6 +67,14 @@ func (m *AgentServiceMockTrustScoreRepository) GetLatest(agentID uuid.UUID) (*do
 	return args.Get(0).(*domain.TrustScore), args.Error(1)
 }
 
+func (m *AgentServiceMockTrustScoreRepository) GetLatestByAgents(agentIDs []uuid.UUID) (map[uuid.UUID]*domain.TrustScore, error) {
+	args := m.Called(agentIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*domain.TrustScore), args.Error(1)
+}
+
 func (m *AgentServiceMockTrustScoreRepository) GetHistory(agentID uuid.UUID, limit int) ([]*domain.TrustScore, error) {
 	args := m.Called(agentID, limit)
 	if args.Get(0) == nil {
@@ -529,6 +540,7 @@ func TestAgentService_shouldAutoVerifyAgent_Conditions(t *testing.T) {
 	tests := []struct {
 		name     string
 		agent    *domain.Agent
+		proven   bool
 		expected bool
 	}{
 		{
@@ -567,13 +579,110 @@ func TestAgentService_shouldAutoVerifyAgent_Conditions(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "client-side key with proof of possession - should auto-verify",
+			agent: &domain.Agent{
+				Name:        "test",
+				DisplayName: "Test",
+				Description: "Test description",
+				TrustScore:  0.85,
+				PublicKey:   stringPtr("key"),
+			},
+			proven:   true,
+			expected: true,
+		},
+		{
+			name: "client-side key without proof of possession - should NOT auto-verify",
+			agent: &domain.Agent{
+				Name:        "test",
+				DisplayName: "Test",
+				Description: "Test description",
+				TrustScore:  0.85,
+				PublicKey:   stringPtr("key"),
+			},
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := service.shouldAutoVerifyAgent(tt.agent)
+			result := service.shouldAutoVerifyAgent(tt.agent, tt.proven)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
+func TestClientSideKeyGenerationRequiresProofOfPossession(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	ctx := context.Background()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	admin := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(admin))
+
+	escrowed := "encrypted-private-key"
+	existing := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.EncryptedPrivateKey = &escrowed })
+	require.NoError(t, repos.Agent.Create(existing))
+
+	// Opting out of escrow drops the private keys AIM holds
+	admins := NewAdminService(repos.User, repos.Organization, repos.Agent)
+	enabled := true
+	updated, err := admins.UpdateOrganizationSettings(ctx, org.ID, &UpdateOrganizationSettingsRequest{ClientSideKeyGeneration: &enabled})
+	require.NoError(t, err)
+	assert.True(t, updated.ClientSideKeyGeneration)
+	stored, err := repos.Agent.GetByID(existing.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.EncryptedPrivateKey)
+
+	agentService := NewAgentService(repos.Agent, nil, repos.TrustScore, nil, repos.Alert, nil, repos.Capability, nil, nil, repos.Organization, nil, nil, nil, nil)
+	_, _, err = agentService.GetAgentCredentials(ctx, existing.ID)
+	assert.ErrorIs(t, err, ErrPrivateKeyNotEscrowed)
+
+	keyPair, err := crypto.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	publicKey := crypto.EncodeKeyPair(keyPair).PublicKeyBase64
+	prove := func(pair *crypto.KeyPair, name, key string) string {
+		return base64.StdEncoding.EncodeToString(crypto.SignMessage(pair.PrivateKey, crypto.ProofOfPossessionMessage(name, key)))
+	}
+	issues := func(validation *RegistrationValidation) []string {
+		codes := make([]string, 0, len(validation.Errors))
+		for _, issue := range validation.Errors {
+			codes = append(codes, issue.Field+":"+issue.Code)
+		}
+		return codes
+	}
+	register := func(req *CreateAgentRequest) *RegistrationValidation {
+		req.DisplayName, req.AgentType = req.Name, domain.AgentTypeAI
+		_, validation, err := agentService.ValidateAgentRegistration(ctx, req, org.ID, admin.ID)
+		require.NoError(t, err)
+		return validation
+	}
+
+	// Server-side generation, a missing proof and a proof for another name or key are all refused
+	assert.Equal(t, []string{"publicKey:client_side_key_required"}, issues(register(&CreateAgentRequest{Name: "generated"})))
+	assert.Equal(t, []string{"proofOfPossession:client_side_key_required"}, issues(register(&CreateAgentRequest{Name: "unproven", PublicKey: publicKey})))
+	assert.Equal(t, []string{"proofOfPossession:invalid_proof_of_possession"}, issues(register(&CreateAgentRequest{
+		Name: "replayed", PublicKey: publicKey, ProofOfPossession: prove(keyPair, "someone-else", publicKey),
+	})))
+	validation := register(&CreateAgentRequest{Name: "client-keys", PublicKey: publicKey, ProofOfPossession: prove(keyPair, "client-keys", publicKey)})
+	assert.True(t, validation.Valid, "%v", validation.Errors)
+
+	// Rotations follow the same rules
+	rotated, err := crypto.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	rotatedKey := crypto.EncodeKeyPair(rotated).PublicKeyBase64
+	_, err = agentService.RotateKey(ctx, existing.ID, &RotateKeyRequest{})
+	assert.ErrorIs(t, err, ErrInvalidKeyRotation)
+	_, err = agentService.RotateKey(ctx, existing.ID, &RotateKeyRequest{PublicKey: rotatedKey})
+	assert.ErrorIs(t, err, ErrInvalidKeyRotation)
+	_, err = agentService.RotateKey(ctx, existing.ID, &RotateKeyRequest{PublicKey: rotatedKey, ProofOfPossession: prove(keyPair, existing.Name, rotatedKey)})
+	assert.ErrorIs(t, err, ErrInvalidKeyRotation)
+
+	result, err := agentService.RotateKey(ctx, existing.ID, &RotateKeyRequest{PublicKey: rotatedKey, ProofOfPossession: prove(rotated, existing.Name, rotatedKey)})
+	require.NoError(t, err)
+	assert.Empty(t, result.PrivateKey)
+	stored, err = repos.Agent.GetByID(existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, rotatedKey, *stored.PublicKey)
+	assert.Nil(t, stored.EncryptedPrivateKey)
+}
@@ -0,0 +1,120 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ErrAgentKeyNotAccepted is returned for signatures made with a key that is neither the agent's
+// current key nor its previous key within the rotation grace period
+var ErrAgentKeyNotAccepted = errors.New("public key is not an accepted key of the agent")
+
+// publicKeyAlgorithm returns the algorithm of a caller-provided key: the requested algorithm
+// once the key parses as one of its keys, or the algorithm detected from the key
+func publicKeyAlgorithm(requested, publicKey string) (string, error) {
+	if strings.TrimSpace(requested) == "" {
+		return crypto.DetectKeyAlgorithm(publicKey)
+	}
+	algorithm, ok := domain.NormalizeKeyAlgorithm(requested)
+	if !ok {
+		return "", fmt.Errorf("%w %q; supported algorithms are %s", crypto.ErrUnsupportedKeyAlgorithm, requested, strings.Join(domain.KeyAlgorithms, ", "))
+	}
+	if _, err := crypto.ParsePublicKey(algorithm, publicKey); err != nil {
+		return "", err
+	}
+	return algorithm, nil
+}
+
+// verifyAgentSignature checks a signature made by the agent. With publicKey set the signature must
+// be made with that key, otherwise with any key the agent's signatures are accepted from. Each key
+// verifies with its own algorithm. Keys of an algorithm the organization does not allow are
+// rejected, except the previous key, so agents keep working while they rotate to an allowed
+// algorithm. A nil organization applies no algorithm policy.
+func verifyAgentSignature(org *domain.Organization, agent *domain.Agent, publicKey string, message, signature []byte, at time.Time) (domain.AgentKey, error) {
+	keys := agent.VerificationKeys(at)
+	if publicKey != "" {
+		key, ok := agent.VerificationKey(publicKey, at)
+		if !ok {
+			return domain.AgentKey{}, ErrAgentKeyNotAccepted
+		}
+		keys = []domain.AgentKey{key}
+	}
+	if len(keys) == 0 {
+		return domain.AgentKey{}, ErrAgentKeyNotAccepted
+	}
+
+	var err error
+	for _, key := range keys {
+		if org != nil && !key.Previous && !org.AllowsKeyAlgorithm(key.Algorithm) {
+			err = fmt.Errorf("%w: the agent's key is %s; rotate it to a %s key", domain.ErrKeyAlgorithmNotAllowed, key.Algorithm, strings.Join(org.AllowedKeyAlgorithms(), " or "))
+			continue
+		}
+		if verifyErr := crypto.VerifyAlgorithmSignature(key.Algorithm, key.PublicKey, message, signature); verifyErr != nil {
+			if err == nil || errors.Is(err, crypto.ErrInvalidSignature) {
+				err = verifyErr
+			}
+			continue
+		}
+		return key, nil
+	}
+	return domain.AgentKey{}, err
+}
+
+// VerifyAgentSignature checks a signature made by the agent against its current key, or the
+// previous key during a rotation grace period, with the algorithm of that key and under the
+// organization's key algorithm policy. With publicKey set only that key is tried.
+func (s *AgentService) VerifyAgentSignature(ctx context.Context, agent *domain.Agent, publicKey string, message, signature []byte) (domain.AgentKey, error) {
+	var org *domain.Organization
+	if s.orgRepo != nil {
+		var err error
+		org, err = s.orgRepo.GetByID(agent.OrganizationID)
+		if err != nil {
+			return domain.AgentKey{}, fmt.Errorf("failed to get organization: %w", err)
+		}
+	}
+	return verifyAgentSignature(org, agent, publicKey, message, signature, time.Now())
+}
+
+// KeyAlgorithmUsage reports the key algorithms of the organization's agents and which agents have
+// to rotate their key after the organization restricted the allowed algorithms
+func (s *AgentService) KeyAlgorithmUsage(ctx context.Context, orgID uuid.UUID) (*domain.KeyAlgorithmUsage, error) {
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	agents, err := s.agentRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agents: %w", err)
+	}
+
+	usage := &domain.KeyAlgorithmUsage{
+		AllowedAlgorithms: org.AllowedKeyAlgorithms(),
+		Agents:            make(map[string]int, len(domain.KeyAlgorithms)),
+		NeedsMigration:    []domain.KeyAlgorithmMigration{},
+	}
+	for _, agent := range agents {
+		if agent.PublicKey == nil || *agent.PublicKey == "" {
+			continue
+		}
+		algorithm, ok := domain.NormalizeKeyAlgorithm(agent.KeyAlgorithm)
+		if !ok {
+			algorithm = agent.KeyAlgorithm
+		}
+		usage.Agents[algorithm]++
+		if !org.AllowsKeyAlgorithm(algorithm) {
+			usage.NeedsMigration = append(usage.NeedsMigration, domain.KeyAlgorithmMigration{
+				AgentID:      agent.ID,
+				AgentName:    agent.Name,
+				KeyAlgorithm: algorithm,
+			})
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,181 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrInvalidAgentTimelineRequest is returned for unknown entry types, bad periods or page sizes
+	ErrInvalidAgentTimelineRequest = errors.New("invalid agent timeline request")
+	// ErrAgentTimelineAgentNotFound is returned when the agent is not in the organization
+	ErrAgentTimelineAgentNotFound = errors.New("agent not found")
+)
+
+const (
+	defaultAgentTimelineLimit = 50
+	maxAgentTimelineLimit     = 200
+)
+
+// AgentTimelineService answers "what happened to this agent" from one feed merging its
+// verifications, attestations, drift, trust changes, key rotations, capability changes and alerts
+type AgentTimelineService struct {
+	timelineRepo domain.AgentTimelineRepository
+	agentRepo    domain.AgentRepository
+}
+
+// NewAgentTimelineService creates a new agent timeline service
+func NewAgentTimelineService(timelineRepo domain.AgentTimelineRepository, agentRepo domain.AgentRepository) *AgentTimelineService {
+	return &AgentTimelineService{
+		timelineRepo: timelineRepo,
+		agentRepo:    agentRepo,
+	}
+}
+
+// AgentTimelineRequest selects a page of an agent's timeline. Zero values return the 50 most
+// recent entries of every type.
+type AgentTimelineRequest struct {
+	OrganizationID uuid.UUID
+	AgentID        uuid.UUID
+	Types          []string
+	Start          *time.Time
+	End            *time.Time
+	Limit          int
+	Offset         int
+}
+
+// AgentTimeline is a page of an agent's timeline, newest first
+type AgentTimeline struct {
+	AgentID   uuid.UUID                    `json:"agentId"`
+	AgentName string                       `json:"agentName"`
+	Entries   []*domain.AgentTimelineEntry `json:"entries"`
+	Total     int                          `json:"total"`
+	Limit     int                          `json:"limit"`
+	Offset    int                          `json:"offset"`
+}
+
+// GetTimeline returns a page of the agent's timeline
+func (s *AgentTimelineService) GetTimeline(ctx context.Context, req *AgentTimelineRequest) (*AgentTimeline, error) {
+	query := domain.AgentTimelineQuery{Start: req.Start, End: req.End, Limit: req.Limit, Offset: req.Offset}
+	if query.Limit == 0 {
+		query.Limit = defaultAgentTimelineLimit
+	}
+	if query.Limit < 1 || query.Limit > maxAgentTimelineLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidAgentTimelineRequest, maxAgentTimelineLimit)
+	}
+	if query.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", ErrInvalidAgentTimelineRequest)
+	}
+	if query.Start != nil && query.End != nil && !query.Start.Before(*query.End) {
+		return nil, fmt.Errorf("%w: start must be before end", ErrInvalidAgentTimelineRequest)
+	}
+
+	seen := make(map[domain.AgentTimelineEntryType]bool)
+	for _, value := range req.Types {
+		entryType := domain.AgentTimelineEntryType(strings.TrimSpace(value))
+		if !isAgentTimelineEntryType(entryType) {
+			return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidAgentTimelineRequest, value)
+		}
+		if !seen[entryType] {
+			seen[entryType] = true
+			query.Types = append(query.Types, entryType)
+		}
+	}
+
+	agent, err := s.agentRepo.GetByID(req.AgentID)
+	if err != nil || agent.OrganizationID != req.OrganizationID {
+		return nil, ErrAgentTimelineAgentNotFound
+	}
+
+	entries, total, err := s.timelineRepo.GetTimeline(agent.ID, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent timeline: %w", err)
+	}
+	for _, entry := range entries {
+		entry.Summary = summarizeTimelineEntry(entry)
+	}
+
+	return &AgentTimeline{
+		AgentID:   agent.ID,
+		AgentName: agent.Name,
+		Entries:   entries,
+		Total:     total,
+		Limit:     query.Limit,
+		Offset:    query.Offset,
+	}, nil
+}
+
+func isAgentTimelineEntryType(entryType domain.AgentTimelineEntryType) bool {
+	for _, known := range domain.AgentTimelineEntryTypes {
+		if entryType == known {
+			return true
+		}
+	}
+	return false
+}
+
+// summarizeTimelineEntry describes the entry in one line from its details
+func summarizeTimelineEntry(entry *domain.AgentTimelineEntry) string {
+	detail := func(key string) string {
+		if value, ok := entry.Details[key]; ok && value != nil {
+			return fmt.Sprint(value)
+		}
+		return ""
+	}
+
+	switch entry.Type {
+	case domain.TimelineVerification:
+		summary := fmt.Sprintf("Verification %s", detail("status"))
+		if action := detail("action"); action != "" {
+			summary = fmt.Sprintf("Verification of %s %s", action, detail("status"))
+		}
+		if reason := detail("errorReason"); reason != "" {
+			summary += ": " + reason
+		}
+		return summary
+	case domain.TimelineAttestation:
+		server := detail("mcpServerName")
+		if server == "" {
+			server = detail("mcpUrl")
+		}
+		return fmt.Sprintf("Attested MCP server %s", server)
+	case domain.TimelineDrift:
+		var drifted []string
+		for _, key := range []string{"mcpServerDrift", "mcpToolDrift", "capabilityDrift"} {
+			values, _ := entry.Details[key].([]interface{})
+			for _, value := range values {
+				drifted = append(drifted, fmt.Sprint(value))
+			}
+		}
+		return fmt.Sprintf("Configuration drift detected: %s", strings.Join(drifted, ", "))
+	case domain.TimelineTrustChange:
+		summary := fmt.Sprintf("Trust score changed to %s", detail("trustScore"))
+		if previous := detail("previousScore"); previous != "" {
+			summary = fmt.Sprintf("Trust score changed from %s to %s", previous, detail("trustScore"))
+		}
+		if reason := detail("reason"); reason != "" {
+			summary += " (" + reason + ")"
+		}
+		return summary
+	case domain.TimelineKeyRotation:
+		if detail("action") == "rotate_credentials" {
+			return "Credentials rotated"
+		}
+		return "Signing key rotated"
+	case domain.TimelineCapabilityChange:
+		summary := fmt.Sprintf("Capability %s %s", detail("capabilityType"), detail("change"))
+		if tool := detail("toolName"); tool != "" {
+			summary += fmt.Sprintf(" (MCP tool %s was dropped)", tool)
+		}
+		return summary
+	case domain.TimelineAlert:
+		return fmt.Sprintf("Alert (%s): %s", detail("severity"), detail("title"))
+	}
+	return string(entry.Type)
+}
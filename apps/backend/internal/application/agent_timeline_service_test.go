@@ -0,0 +1,107 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentTimelineMergesAgentActivity(t *testing.T) {
+	ctx := context.Background()
+	repos := testsupport.NewRepositories()
+	service := NewAgentTimelineService(repos.AgentTimeline, repos.Agent)
+
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	agent := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(agent))
+	server := testsupport.NewMCPServer(org.ID)
+	require.NoError(t, repos.MCPServer.Create(server))
+
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+
+	require.NoError(t, repos.VerificationEvent.Create(testsupport.NewVerificationEvent(agent, func(e *domain.VerificationEvent) {
+		e.StartedAt = at(0)
+	})))
+	require.NoError(t, repos.VerificationEvent.Create(testsupport.NewVerificationEvent(agent, func(e *domain.VerificationEvent) {
+		e.StartedAt = at(10)
+		e.DriftDetected = true
+		e.MCPServerDrift = []string{"https://rogue.example.com"}
+	})))
+	require.NoError(t, repos.MCPAttestation.CreateAttestation(testsupport.NewMCPAttestation(server, agent, func(a *domain.MCPAttestation) {
+		verifiedAt := at(20)
+		a.VerifiedAt = &verifiedAt
+	})))
+	require.NoError(t, repos.TrustScore.Create(&domain.TrustScore{AgentID: agent.ID, Score: 0.7, LastCalculated: at(30)}))
+	require.NoError(t, repos.AuditLog.Create(&domain.AuditLog{
+		OrganizationID: org.ID, Action: domain.AuditActionUpdate, ResourceType: "agent", ResourceID: agent.ID,
+		Metadata: map[string]interface{}{"action": "rotate_key"}, Timestamp: at(40),
+	}))
+	capability := testsupport.NewAgentCapability(agent, "file:read", func(c *domain.AgentCapability) {
+		c.GrantedAt = at(5)
+	})
+	require.NoError(t, repos.Capability.CreateCapability(capability))
+	require.NoError(t, repos.Capability.RevokeCapability(capability.ID, at(50)))
+	require.NoError(t, repos.Alert.Create(testsupport.NewAlert(org.ID, agent.ID, func(a *domain.Alert) {
+		a.CreatedAt = at(55)
+	})))
+
+	// Another agent's activity stays out of the timeline
+	other := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(other))
+	require.NoError(t, repos.Alert.Create(testsupport.NewAlert(org.ID, other.ID)))
+
+	timeline, err := service.GetTimeline(ctx, &AgentTimelineRequest{OrganizationID: org.ID, AgentID: agent.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 9, timeline.Total)
+	var types []domain.AgentTimelineEntryType
+	for i, entry := range timeline.Entries {
+		types = append(types, entry.Type)
+		assert.NotEmpty(t, entry.Summary)
+		if i > 0 {
+			assert.False(t, entry.OccurredAt.After(timeline.Entries[i-1].OccurredAt), "entries are newest first")
+		}
+	}
+	assert.Equal(t, []domain.AgentTimelineEntryType{
+		domain.TimelineAlert,
+		domain.TimelineCapabilityChange, // revoked
+		domain.TimelineKeyRotation,
+		domain.TimelineTrustChange,
+		domain.TimelineAttestation,
+		domain.TimelineDrift,
+		domain.TimelineVerification,
+		domain.TimelineCapabilityChange, // granted
+		domain.TimelineVerification,
+	}, types)
+	assert.Equal(t, "Configuration drift detected: https://rogue.example.com", timeline.Entries[5].Summary)
+	assert.Equal(t, "Capability file:read revoked", timeline.Entries[1].Summary)
+
+	// Type filters and pagination apply to the merged feed
+	page, err := service.GetTimeline(ctx, &AgentTimelineRequest{
+		OrganizationID: org.ID, AgentID: agent.ID,
+		Types: []string{"verification", "capability_change"}, Limit: 2, Offset: 1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, page.Total)
+	require.Len(t, page.Entries, 2)
+	assert.Equal(t, domain.TimelineVerification, page.Entries[0].Type)
+	assert.Equal(t, at(10), page.Entries[0].OccurredAt)
+	assert.Equal(t, domain.TimelineCapabilityChange, page.Entries[1].Type)
+
+	start, end := at(15), at(45)
+	window, err := service.GetTimeline(ctx, &AgentTimelineRequest{OrganizationID: org.ID, AgentID: agent.ID, Start: &start, End: &end})
+	require.NoError(t, err)
+	assert.Equal(t, 3, window.Total, "attestation, trust change and key rotation")
+
+	_, err = service.GetTimeline(ctx, &AgentTimelineRequest{OrganizationID: org.ID, AgentID: agent.ID, Types: []string{"logins"}})
+	assert.ErrorIs(t, err, ErrInvalidAgentTimelineRequest)
+	_, err = service.GetTimeline(ctx, &AgentTimelineRequest{OrganizationID: uuid.New(), AgentID: agent.ID})
+	assert.ErrorIs(t, err, ErrAgentTimelineAgentNotFound)
+}
@@ -12,9 +12,12 @@ import (
 
 // AlertService handles alert management
 type AlertService struct {
-	alertRepo domain.AlertRepository
-	agentRepo domain.AgentRepository
-	db        *sql.DB // For anomaly detection queries
+	alertRepo           domain.AlertRepository
+	agentRepo           domain.AgentRepository
+	db                  *sql.DB                  // For anomaly detection queries
+	notificationService *NotificationService     // Optional: fans new alerts out to notification channels
+	suppressionService  *AlertSuppressionService // Optional: drops or downgrades known-noisy alerts
+	webhookService      *WebhookService          // Optional: publishes new alerts to webhook subscriptions
 }
 
 // NewAlertService creates a new alert service
@@ -22,17 +25,56 @@ func NewAlertService(
 	alertRepo domain.AlertRepository,
 	agentRepo domain.AgentRepository,
 	db *sql.DB,
+	notificationService *NotificationService,
+	suppressionService *AlertSuppressionService,
+	webhookService *WebhookService,
 ) *AlertService {
 	return &AlertService{
-		alertRepo: alertRepo,
-		agentRepo: agentRepo,
-		db:        db,
+		alertRepo:           alertRepo,
+		agentRepo:           agentRepo,
+		db:                  db,
+		notificationService: notificationService,
+		suppressionService:  suppressionService,
+		webhookService:      webhookService,
 	}
 }
 
 // CreateAlert creates a new alert
 func (s *AlertService) CreateAlert(ctx context.Context, alert *domain.Alert) error {
-	return s.alertRepo.Create(alert)
+	return s.createAlert(ctx, alert)
+}
+
+// createAlert persists an alert, enqueues its notifications and publishes it to webhooks.
+// Suppression rules may downgrade the alert or skip its notifications; the alert is always stored.
+// Notification failures are logged but never fail alert creation.
+func (s *AlertService) createAlert(ctx context.Context, alert *domain.Alert) error {
+	var decision *SuppressionDecision
+	if s.suppressionService != nil {
+		decision = s.suppressionService.Apply(ctx, alert)
+	}
+
+	if err := s.alertRepo.Create(alert); err != nil {
+		return err
+	}
+
+	if decision != nil {
+		s.suppressionService.RecordHit(ctx, decision, alert)
+		if decision.Suppressed() {
+			return nil
+		}
+	}
+
+	if s.notificationService != nil {
+		if _, err := s.notificationService.DispatchAlert(ctx, alert); err != nil {
+			fmt.Printf("⚠️  Failed to enqueue notifications for alert %s: %v\n", alert.ID, err)
+		}
+	}
+
+	if s.webhookService != nil {
+		s.webhookService.PublishAlert(ctx, alert)
+	}
+
+	return nil
 }
 
 // GetUnacknowledgedAlerts retrieves unacknowledged alerts
@@ -102,7 +144,7 @@ func (s *AlertService) CheckTrustScores(ctx context.Context, orgID uuid.UUID) er
 			}
 
 			if !exists {
-				s.alertRepo.Create(alert)
+				s.createAlert(ctx, alert)
 			}
 		}
 	}
@@ -145,6 +187,23 @@ func (s *AlertService) GetAlerts(
 	return alerts, total, nil
 }
 
+// GetResourceAlerts retrieves the newest alerts raised for an agent or MCP server of the
+// organization
+func (s *AlertService) GetResourceAlerts(ctx context.Context, orgID, resourceID uuid.UUID, limit int) ([]*domain.Alert, error) {
+	alerts, err := s.alertRepo.GetByResourceID(resourceID, limit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource alerts: %w", err)
+	}
+
+	result := make([]*domain.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		if alert.OrganizationID == orgID {
+			result = append(result, alert)
+		}
+	}
+	return result, nil
+}
+
 // AcknowledgeAlert acknowledges an alert
 func (s *AlertService) AcknowledgeAlert(
 	ctx context.Context,
@@ -276,7 +335,7 @@ func (s *AlertService) DetectUnusualAccessPatterns(ctx context.Context, orgID uu
 			}
 		}
 		if !exists {
-			if err := s.alertRepo.Create(alert); err != nil {
+			if err := s.createAlert(ctx, alert); err != nil {
 				fmt.Printf("⚠️  [ANOMALY-DETECTION] Failed to create alert: %v\n", err)
 			} else {
 				alertsCreated++
@@ -539,7 +598,7 @@ func (s *AlertService) CheckTrustScoreDrop(ctx context.Context, orgID uuid.UUID,
 		}
 	}
 
-	return s.alertRepo.Create(alert)
+	return s.createAlert(ctx, alert)
 }
 
 // ApproveDrift approves configuration drift by updating the agent's registered configuration
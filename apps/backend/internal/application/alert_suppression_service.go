@@ -0,0 +1,322 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ErrInvalidSuppressionRule is returned when a suppression rule fails validation
+var ErrInvalidSuppressionRule = errors.New("invalid suppression rule")
+
+// MaxSuppressionRuleLifetime caps how far in the future a suppression rule may expire,
+// so no rule can silence a signal permanently
+const MaxSuppressionRuleLifetime = 90 * 24 * time.Hour
+
+// AlertSuppressionRuleRequest represents the request to create or update a suppression rule
+type AlertSuppressionRuleRequest struct {
+	Name                string                        `json:"name"`
+	Description         string                        `json:"description"`
+	Enabled             *bool                         `json:"enabled,omitempty"` // Pointer to distinguish between false and not provided
+	AlertTypes          []domain.AlertType            `json:"alertTypes"`
+	ResourceType        string                        `json:"resourceType"`
+	ResourceID          *uuid.UUID                    `json:"resourceId"`
+	TagID               *uuid.UUID                    `json:"tagId"`
+	WindowStart         string                        `json:"windowStart"`
+	WindowEnd           string                        `json:"windowEnd"`
+	RepeatThreshold     int                           `json:"repeatThreshold"`
+	RepeatWindowMinutes int                           `json:"repeatWindowMinutes"`
+	Action              domain.AlertSuppressionAction `json:"action"`
+	DowngradeTo         domain.AlertSeverity          `json:"downgradeTo"`
+	ExpiresAt           time.Time                     `json:"expiresAt"`
+}
+
+// SuppressionDecision is the outcome of running an alert through the suppression rules
+type SuppressionDecision struct {
+	Rule             *domain.AlertSuppressionRule // Nil when no rule matched
+	OriginalSeverity domain.AlertSeverity
+}
+
+// Suppressed reports whether the alert must not be notified at all
+func (d *SuppressionDecision) Suppressed() bool {
+	return d.Rule != nil && d.Rule.Action == domain.AlertSuppressionDrop
+}
+
+// AlertSuppressionService drops or downgrades known-noisy alerts before notification
+type AlertSuppressionService struct {
+	suppressionRepo domain.AlertSuppressionRepository
+	tagRepo         domain.TagRepository
+}
+
+// NewAlertSuppressionService creates a new alert suppression service
+func NewAlertSuppressionService(
+	suppressionRepo domain.AlertSuppressionRepository,
+	tagRepo domain.TagRepository,
+) *AlertSuppressionService {
+	return &AlertSuppressionService{
+		suppressionRepo: suppressionRepo,
+		tagRepo:         tagRepo,
+	}
+}
+
+// CreateRule creates a new suppression rule
+func (s *AlertSuppressionService) CreateRule(ctx context.Context, req *AlertSuppressionRuleRequest, orgID, userID uuid.UUID) (*domain.AlertSuppressionRule, error) {
+	rule := &domain.AlertSuppressionRule{
+		OrganizationID: orgID,
+		Enabled:        true,
+		CreatedBy:      userID,
+	}
+	if err := applySuppressionRuleRequest(rule, req, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.suppressionRepo.Create(rule); err != nil {
+		return nil, fmt.Errorf("failed to create suppression rule: %w", err)
+	}
+	return rule, nil
+}
+
+// ListRules lists all suppression rules of an organization, including expired ones
+func (s *AlertSuppressionService) ListRules(ctx context.Context, orgID uuid.UUID) ([]*domain.AlertSuppressionRule, error) {
+	return s.suppressionRepo.GetByOrganization(orgID)
+}
+
+// GetRule retrieves a suppression rule by ID
+func (s *AlertSuppressionService) GetRule(ctx context.Context, id uuid.UUID) (*domain.AlertSuppressionRule, error) {
+	return s.suppressionRepo.GetByID(id)
+}
+
+// UpdateRule updates a suppression rule. Extending an expired rule re-activates it.
+func (s *AlertSuppressionService) UpdateRule(ctx context.Context, id uuid.UUID, req *AlertSuppressionRuleRequest) (*domain.AlertSuppressionRule, error) {
+	rule, err := s.suppressionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applySuppressionRuleRequest(rule, req, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.suppressionRepo.Update(rule); err != nil {
+		return nil, fmt.Errorf("failed to update suppression rule: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteRule deletes a suppression rule
+func (s *AlertSuppressionService) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	return s.suppressionRepo.Delete(id)
+}
+
+// GetRuleHits lists the alerts a rule matched, newest first
+func (s *AlertSuppressionService) GetRuleHits(ctx context.Context, ruleID uuid.UUID, limit, offset int) ([]*domain.AlertSuppressionHit, error) {
+	return s.suppressionRepo.GetHits(ruleID, limit, offset)
+}
+
+// applySuppressionRuleRequest validates a request and copies it onto the rule
+func applySuppressionRuleRequest(rule *domain.AlertSuppressionRule, req *AlertSuppressionRuleRequest, now time.Time) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidSuppressionRule)
+	}
+
+	switch req.Action {
+	case domain.AlertSuppressionDrop:
+	case domain.AlertSuppressionDowngrade:
+		if severityRank(req.DowngradeTo) < 0 {
+			return fmt.Errorf("%w: downgradeTo must be info, warning, high or critical", ErrInvalidSuppressionRule)
+		}
+	default:
+		return fmt.Errorf("%w: action must be drop or downgrade", ErrInvalidSuppressionRule)
+	}
+
+	if req.ExpiresAt.IsZero() {
+		return fmt.Errorf("%w: expiresAt is required", ErrInvalidSuppressionRule)
+	}
+	if !req.ExpiresAt.After(now) {
+		return fmt.Errorf("%w: expiresAt must be in the future", ErrInvalidSuppressionRule)
+	}
+	if req.ExpiresAt.After(now.Add(MaxSuppressionRuleLifetime)) {
+		return fmt.Errorf("%w: expiresAt must be within %d days", ErrInvalidSuppressionRule, int(MaxSuppressionRuleLifetime.Hours()/24))
+	}
+
+	if (req.WindowStart == "") != (req.WindowEnd == "") {
+		return fmt.Errorf("%w: windowStart and windowEnd must be set together", ErrInvalidSuppressionRule)
+	}
+	if req.WindowStart != "" {
+		if _, err := time.Parse("15:04", req.WindowStart); err != nil {
+			return fmt.Errorf("%w: windowStart must be HH:MM", ErrInvalidSuppressionRule)
+		}
+		if _, err := time.Parse("15:04", req.WindowEnd); err != nil {
+			return fmt.Errorf("%w: windowEnd must be HH:MM", ErrInvalidSuppressionRule)
+		}
+	}
+
+	if req.RepeatThreshold < 0 || req.RepeatWindowMinutes < 0 {
+		return fmt.Errorf("%w: repeatThreshold and repeatWindowMinutes cannot be negative", ErrInvalidSuppressionRule)
+	}
+	if req.RepeatThreshold > 0 && req.RepeatWindowMinutes == 0 {
+		return fmt.Errorf("%w: repeatWindowMinutes is required with repeatThreshold", ErrInvalidSuppressionRule)
+	}
+
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.Description = req.Description
+	rule.AlertTypes = req.AlertTypes
+	if rule.AlertTypes == nil {
+		rule.AlertTypes = []domain.AlertType{}
+	}
+	rule.ResourceType = req.ResourceType
+	rule.ResourceID = req.ResourceID
+	rule.TagID = req.TagID
+	rule.WindowStart = req.WindowStart
+	rule.WindowEnd = req.WindowEnd
+	rule.RepeatThreshold = req.RepeatThreshold
+	rule.RepeatWindowMinutes = req.RepeatWindowMinutes
+	rule.Action = req.Action
+	rule.DowngradeTo = ""
+	if req.Action == domain.AlertSuppressionDowngrade {
+		rule.DowngradeTo = req.DowngradeTo
+	}
+	rule.ExpiresAt = req.ExpiresAt.UTC()
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	return nil
+}
+
+// Apply runs an alert that is about to be stored through the organization's active rules.
+// The first matching rule (oldest first) wins; a downgrade rule lowers the alert's severity
+// in place. Rule lookup failures are logged and leave the alert untouched.
+func (s *AlertSuppressionService) Apply(ctx context.Context, alert *domain.Alert) *SuppressionDecision {
+	decision := &SuppressionDecision{OriginalSeverity: alert.Severity}
+
+	now := time.Now().UTC()
+	rules, err := s.suppressionRepo.GetActiveByOrganization(alert.OrganizationID, now)
+	if err != nil {
+		log.Printf("⚠️  Failed to load alert suppression rules for organization %s: %v", alert.OrganizationID, err)
+		return decision
+	}
+
+	for _, rule := range rules {
+		if s.matches(ctx, rule, alert, now) {
+			decision.Rule = rule
+			break
+		}
+	}
+
+	if decision.Rule != nil && decision.Rule.Action == domain.AlertSuppressionDowngrade &&
+		severityRank(decision.Rule.DowngradeTo) < severityRank(alert.Severity) {
+		alert.Severity = decision.Rule.DowngradeTo
+	}
+	return decision
+}
+
+// RecordHit counts a decision's rule hit once the alert has been stored
+func (s *AlertSuppressionService) RecordHit(ctx context.Context, decision *SuppressionDecision, alert *domain.Alert) {
+	if decision == nil || decision.Rule == nil {
+		return
+	}
+
+	hit := &domain.AlertSuppressionHit{
+		RuleID:           decision.Rule.ID,
+		OrganizationID:   alert.OrganizationID,
+		AlertID:          alert.ID,
+		Action:           decision.Rule.Action,
+		OriginalSeverity: decision.OriginalSeverity,
+	}
+	if err := s.suppressionRepo.RecordHit(hit); err != nil {
+		log.Printf("⚠️  Failed to record hit of suppression rule %s: %v", decision.Rule.ID, err)
+	}
+}
+
+// matches reports whether every condition of the rule holds for the alert
+func (s *AlertSuppressionService) matches(ctx context.Context, rule *domain.AlertSuppressionRule, alert *domain.Alert, now time.Time) bool {
+	if len(rule.AlertTypes) > 0 {
+		found := false
+		for _, alertType := range rule.AlertTypes {
+			if alertType == alert.AlertType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if rule.ResourceType != "" && rule.ResourceType != alert.ResourceType {
+		return false
+	}
+	if rule.ResourceID != nil && *rule.ResourceID != alert.ResourceID {
+		return false
+	}
+
+	if rule.WindowStart != "" && !inDailyWindow(rule.WindowStart, rule.WindowEnd, now) {
+		return false
+	}
+
+	if rule.TagID != nil {
+		if alert.ResourceType != "agent" || s.tagRepo == nil {
+			return false
+		}
+		tags, err := s.tagRepo.GetAgentTags(ctx, alert.ResourceID)
+		if err != nil {
+			log.Printf("⚠️  Failed to load tags of agent %s: %v", alert.ResourceID, err)
+			return false
+		}
+		tagged := false
+		for _, tag := range tags {
+			if tag.ID == *rule.TagID {
+				tagged = true
+				break
+			}
+		}
+		if !tagged {
+			return false
+		}
+	}
+
+	if rule.RepeatThreshold > 0 {
+		since := now.Add(-time.Duration(rule.RepeatWindowMinutes) * time.Minute)
+		previous, err := s.suppressionRepo.CountMatchingAlerts(alert, since)
+		if err != nil {
+			log.Printf("⚠️  Failed to count repeats of alert fingerprint: %v", err)
+			return false
+		}
+		// The alert being evaluated has not been stored yet
+		if previous+1 < rule.RepeatThreshold {
+			return false
+		}
+	}
+
+	return true
+}
+
+// inDailyWindow reports whether the UTC time of day of now falls in [start, end).
+// A window whose end is before its start spans midnight.
+func inDailyWindow(start, end string, now time.Time) bool {
+	startTime, err := time.Parse("15:04", start)
+	if err != nil {
+		return false
+	}
+	endTime, err := time.Parse("15:04", end)
+	if err != nil {
+		return false
+	}
+
+	now = now.UTC()
+	minute := now.Hour()*60 + now.Minute()
+	startMinute := startTime.Hour()*60 + startTime.Minute()
+	endMinute := endTime.Hour()*60 + endTime.Minute()
+
+	if startMinute <= endMinute {
+		return minute >= startMinute && minute < endMinute
+	}
+	return minute >= startMinute || minute < endMinute
+}
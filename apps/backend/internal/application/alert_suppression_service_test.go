@@ -0,0 +1,147 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInDailyWindow(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 11, 12, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		start, end string
+		now        time.Time
+		expected   bool
+	}{
+		{"inside daytime window", "09:00", "17:00", at(12, 0), true},
+		{"window start is inclusive", "09:00", "17:00", at(9, 0), true},
+		{"window end is exclusive", "09:00", "17:00", at(17, 0), false},
+		{"outside daytime window", "09:00", "17:00", at(20, 0), false},
+		{"overnight window before midnight", "22:00", "06:00", at(23, 30), true},
+		{"overnight window after midnight", "22:00", "06:00", at(2, 0), true},
+		{"outside overnight window", "22:00", "06:00", at(12, 0), false},
+		{"invalid window never matches", "25:00", "06:00", at(2, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, inDailyWindow(tt.start, tt.end, tt.now))
+		})
+	}
+}
+
+func TestAlertSuppressionRulesDropAndDowngradeBeforeNotification(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	admin := testsupport.NewUser(org.ID)
+	noisy := testsupport.NewAgent(org.ID)
+	tagged := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(noisy))
+	require.NoError(t, repos.Agent.Create(tagged))
+
+	ctx := context.Background()
+	tag := &domain.Tag{OrganizationID: org.ID, Key: "env", Value: "staging"}
+	require.NoError(t, repos.Tag.Create(ctx, tag))
+	require.NoError(t, repos.Tag.AddTagsToAgent(ctx, tagged.ID, []uuid.UUID{tag.ID}))
+
+	suppression := NewAlertSuppressionService(repos.AlertSuppression, repos.Tag)
+	notifications := NewNotificationService(repos.Notification)
+	alerts := NewAlertService(repos.Alert, repos.Agent, nil, notifications, suppression, nil)
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	dropRule, err := suppression.CreateRule(ctx, &AlertSuppressionRuleRequest{
+		Name:                "noisy offline agent",
+		AlertTypes:          []domain.AlertType{domain.AlertAgentOffline},
+		ResourceID:          &noisy.ID,
+		RepeatThreshold:     2,
+		RepeatWindowMinutes: 60,
+		Action:              domain.AlertSuppressionDrop,
+		ExpiresAt:           expiresAt,
+	}, org.ID, admin.ID)
+	require.NoError(t, err)
+	downgradeRule, err := suppression.CreateRule(ctx, &AlertSuppressionRuleRequest{
+		Name:        "staging trust drops",
+		TagID:       &tag.ID,
+		Action:      domain.AlertSuppressionDowngrade,
+		DowngradeTo: domain.AlertSeverityInfo,
+		ExpiresAt:   expiresAt,
+	}, org.ID, admin.ID)
+	require.NoError(t, err)
+
+	newAlert := func(agent *domain.Agent, alertType domain.AlertType) *domain.Alert {
+		return &domain.Alert{
+			OrganizationID: org.ID,
+			AlertType:      alertType,
+			Severity:       domain.AlertSeverityHigh,
+			Title:          "Agent signal: " + agent.Name,
+			ResourceType:   "agent",
+			ResourceID:     agent.ID,
+		}
+	}
+	notified := func() int {
+		_, total, err := repos.Notification.GetNotificationsByOrganization(org.ID, 100, 0)
+		require.NoError(t, err)
+		return total
+	}
+
+	// The first offline alert is below the repeat threshold and is notified
+	require.NoError(t, alerts.CreateAlert(ctx, newAlert(noisy, domain.AlertAgentOffline)))
+	assert.Equal(t, 1, notified())
+
+	// The repeat is recorded but not notified
+	repeat := newAlert(noisy, domain.AlertAgentOffline)
+	require.NoError(t, alerts.CreateAlert(ctx, repeat))
+	assert.Equal(t, 1, notified())
+	stored, err := repos.Alert.GetByID(repeat.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AlertSeverityHigh, stored.Severity)
+
+	// Alerts about tagged agents are notified at the downgraded severity
+	drop := newAlert(tagged, domain.AlertTrustScoreDrop)
+	require.NoError(t, alerts.CreateAlert(ctx, drop))
+	assert.Equal(t, 2, notified())
+	assert.Equal(t, domain.AlertSeverityInfo, drop.Severity)
+
+	// Other alerts are untouched
+	untouched := newAlert(noisy, domain.AlertTrustScoreDrop)
+	require.NoError(t, alerts.CreateAlert(ctx, untouched))
+	assert.Equal(t, domain.AlertSeverityHigh, untouched.Severity)
+
+	rule, err := suppression.GetRule(ctx, dropRule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rule.HitCount)
+	hits, err := suppression.GetRuleHits(ctx, downgradeRule.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, drop.ID, hits[0].AlertID)
+	assert.Equal(t, domain.AlertSeverityHigh, hits[0].OriginalSeverity)
+
+	// Expired rules stop matching
+	rule.ExpiresAt = time.Now().Add(-time.Minute)
+	require.NoError(t, repos.AlertSuppression.Update(rule))
+	require.NoError(t, alerts.CreateAlert(ctx, newAlert(noisy, domain.AlertAgentOffline)))
+	assert.Equal(t, 4, notified())
+
+	// Rules cannot be permanent
+	_, err = suppression.CreateRule(ctx, &AlertSuppressionRuleRequest{
+		Name:      "forever",
+		Action:    domain.AlertSuppressionDrop,
+		ExpiresAt: time.Now().Add(365 * 24 * time.Hour),
+	}, org.ID, admin.ID)
+	assert.ErrorIs(t, err, ErrInvalidSuppressionRule)
+	_, err = suppression.CreateRule(ctx, &AlertSuppressionRuleRequest{
+		Name:   "no expiry",
+		Action: domain.AlertSuppressionDrop,
+	}, org.ID, admin.ID)
+	assert.ErrorIs(t, err, ErrInvalidSuppressionRule)
+}
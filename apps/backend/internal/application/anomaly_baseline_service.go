@@ -0,0 +1,307 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// minBaselineHours is how much history an agent needs before its activity is compared to it
+	minBaselineHours = 24
+	// minBaselineStdDev keeps agents with perfectly regular activity from flagging a single extra
+	// verification: deviations are measured in at least one verification per hour
+	minBaselineStdDev = 1.0
+)
+
+// AgentBaseline is an agent's normal hourly activity, learned from its verification events
+type AgentBaseline struct {
+	AgentID       uuid.UUID
+	Hours         int                     // Hours of history the baseline covers
+	Verifications BaselineStat            // Verifications per hour
+	HourOfDay     [24]BaselineStat        // Verifications in each hour of the day (UTC), per day
+	MCPServers    map[string]BaselineStat // Verifications per hour reporting each MCP server
+}
+
+// BaselineStat is the mean and standard deviation of an hourly count
+type BaselineStat struct {
+	Mean   float64
+	StdDev float64
+}
+
+// ZScore returns how many standard deviations value lies above the mean
+func (b BaselineStat) ZScore(value float64) float64 {
+	return (value - b.Mean) / math.Max(b.StdDev, minBaselineStdDev)
+}
+
+// AnomalyBaselineService learns per-agent baselines of verification rate, active hours and MCP
+// server usage, and records anomalies for the last complete hour when an agent's activity rises
+// more than the z-score threshold above its baseline. Drops in activity are left to agent
+// offline alerts.
+type AnomalyBaselineService struct {
+	eventRepo       domain.VerificationEventRepository
+	agentRepo       domain.AgentRepository
+	orgRepo         domain.OrganizationRepository
+	securityService *SecurityService
+	baselineWindow  time.Duration
+	zScoreThreshold float64
+	now             func() time.Time
+}
+
+// NewAnomalyBaselineService creates the anomaly baseline service. baselineWindow is how much
+// history the baselines are learned from.
+func NewAnomalyBaselineService(
+	eventRepo domain.VerificationEventRepository,
+	agentRepo domain.AgentRepository,
+	orgRepo domain.OrganizationRepository,
+	securityService *SecurityService,
+	baselineWindow time.Duration,
+	zScoreThreshold float64,
+) *AnomalyBaselineService {
+	return &AnomalyBaselineService{
+		eventRepo:       eventRepo,
+		agentRepo:       agentRepo,
+		orgRepo:         orgRepo,
+		securityService: securityService,
+		baselineWindow:  baselineWindow,
+		zScoreThreshold: zScoreThreshold,
+		now:             time.Now,
+	}
+}
+
+// DetectAllAnomalies runs anomaly detection for every organization and returns the number of
+// anomalies recorded
+func (s *AnomalyBaselineService) DetectAllAnomalies(ctx context.Context) (int, error) {
+	orgs, err := s.orgRepo.List()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	detected := 0
+	for _, org := range orgs {
+		if ctx.Err() != nil {
+			return detected, ctx.Err()
+		}
+		anomalies, err := s.DetectAnomalies(ctx, org.ID)
+		detected += len(anomalies)
+		if err != nil {
+			log.Printf("⚠️  Anomaly detection failed for organization %s: %v", org.ID, err)
+		}
+	}
+	return detected, nil
+}
+
+// DetectAnomalies compares each agent's activity in the last complete hour to its baseline and
+// records an anomaly for every deviation above the threshold. Running it again within the same
+// hour records nothing new.
+func (s *AnomalyBaselineService) DetectAnomalies(ctx context.Context, orgID uuid.UUID) ([]*domain.Anomaly, error) {
+	windowEnd := s.now().UTC().Truncate(time.Hour)
+	windowStart := windowEnd.Add(-time.Hour)
+	baselineStart := windowStart.Add(-s.baselineWindow)
+
+	activity, err := s.eventRepo.GetHourlyAgentActivity(orgID, baselineStart, windowEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent activity: %w", err)
+	}
+
+	history := make(map[uuid.UUID][]*domain.AgentHourlyActivity)
+	current := make(map[uuid.UUID]*domain.AgentHourlyActivity)
+	for _, hour := range activity {
+		if hour.Hour.Equal(windowStart) {
+			current[hour.AgentID] = hour
+		} else {
+			history[hour.AgentID] = append(history[hour.AgentID], hour)
+		}
+	}
+
+	agentIDs := make([]uuid.UUID, 0, len(current))
+	for agentID := range current {
+		agentIDs = append(agentIDs, agentID)
+	}
+	sort.Slice(agentIDs, func(i, j int) bool { return agentIDs[i].String() < agentIDs[j].String() })
+
+	var anomalies []*domain.Anomaly
+	for _, agentID := range agentIDs {
+		baseline := learnBaseline(agentID, history[agentID], windowStart)
+		if baseline == nil {
+			continue
+		}
+		agent, err := s.agentRepo.GetByID(agentID)
+		if err != nil {
+			continue // Deleted since
+		}
+		for _, anomaly := range s.deviations(agent, baseline, current[agentID], windowStart) {
+			recorded, err := s.record(ctx, anomaly, windowEnd)
+			if err != nil {
+				return anomalies, err
+			}
+			if recorded {
+				anomalies = append(anomalies, anomaly)
+			}
+		}
+	}
+	return anomalies, nil
+}
+
+// learnBaseline computes the baseline from the agent's hourly activity before windowStart. The
+// baseline starts at the agent's first active hour, so quiet hours before it was deployed don't
+// lower it. It returns nil while there is less than a day of history.
+func learnBaseline(agentID uuid.UUID, history []*domain.AgentHourlyActivity, windowStart time.Time) *AgentBaseline {
+	if len(history) == 0 {
+		return nil
+	}
+	first := history[0].Hour
+	for _, hour := range history {
+		if hour.Hour.Before(first) {
+			first = hour.Hour
+		}
+	}
+	hours := int(windowStart.Sub(first) / time.Hour)
+	if hours < minBaselineHours {
+		return nil
+	}
+
+	// Hourly counts, including the hours without verifications
+	verifications := make([]float64, hours)
+	servers := make(map[string][]float64)
+	for _, hour := range history {
+		i := int(hour.Hour.Sub(first) / time.Hour)
+		verifications[i] = float64(hour.Verifications)
+		for server, count := range hour.MCPServers {
+			if servers[server] == nil {
+				servers[server] = make([]float64, hours)
+			}
+			servers[server][i] = float64(count)
+		}
+	}
+
+	baseline := &AgentBaseline{
+		AgentID:       agentID,
+		Hours:         hours,
+		Verifications: newBaselineStat(verifications),
+		MCPServers:    make(map[string]BaselineStat, len(servers)),
+	}
+	var hourOfDay [24][]float64
+	for i, count := range verifications {
+		h := first.Add(time.Duration(i) * time.Hour).Hour()
+		hourOfDay[h] = append(hourOfDay[h], count)
+	}
+	for h, counts := range hourOfDay {
+		baseline.HourOfDay[h] = newBaselineStat(counts)
+	}
+	for server, counts := range servers {
+		baseline.MCPServers[server] = newBaselineStat(counts)
+	}
+	return baseline
+}
+
+func newBaselineStat(values []float64) BaselineStat {
+	if len(values) == 0 {
+		return BaselineStat{}
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return BaselineStat{Mean: mean, StdDev: math.Sqrt(variance / float64(len(values)))}
+}
+
+// deviations returns the anomalies of the agent's activity in the hour starting at windowStart:
+// a verification rate above its baseline (abnormal_traffic), otherwise activity at an hour of the
+// day the agent is normally quiet, and heavier use of an MCP server than usual (unusual_api_usage)
+func (s *AnomalyBaselineService) deviations(agent *domain.Agent, baseline *AgentBaseline, current *domain.AgentHourlyActivity, windowStart time.Time) []*domain.Anomaly {
+	var anomalies []*domain.Anomaly
+	count := float64(current.Verifications)
+
+	if z := baseline.Verifications.ZScore(count); z > s.zScoreThreshold {
+		anomalies = append(anomalies, s.newAnomaly(agent, domain.AnomalyTypeAbnormalTraffic, z,
+			fmt.Sprintf("Abnormal verification rate: %s", agent.DisplayName),
+			fmt.Sprintf("Agent '%s' made %d verifications between %s and %s UTC, against a baseline of %.1f ± %.1f per hour over the last %d hours (z-score %.1f).",
+				agent.DisplayName, current.Verifications, windowStart.Format("2006-01-02 15:04"), windowStart.Add(time.Hour).Format("15:04"),
+				baseline.Verifications.Mean, baseline.Verifications.StdDev, baseline.Hours, z)))
+	} else if hourOfDay := baseline.HourOfDay[windowStart.Hour()]; hourOfDay.ZScore(count) > s.zScoreThreshold {
+		z := hourOfDay.ZScore(count)
+		anomalies = append(anomalies, s.newAnomaly(agent, domain.AnomalyTypeUnusualAPIUsage, z,
+			fmt.Sprintf("Activity outside usual hours: %s", agent.DisplayName),
+			fmt.Sprintf("Agent '%s' made %d verifications between %s and %s UTC, an hour in which it averages %.1f ± %.1f (z-score %.1f).",
+				agent.DisplayName, current.Verifications, windowStart.Format("15:04"), windowStart.Add(time.Hour).Format("15:04"),
+				hourOfDay.Mean, hourOfDay.StdDev, z)))
+	}
+
+	servers := make([]string, 0, len(current.MCPServers))
+	for server := range current.MCPServers {
+		servers = append(servers, server)
+	}
+	sort.Strings(servers)
+	for _, server := range servers {
+		usage := float64(current.MCPServers[server])
+		stat, known := baseline.MCPServers[server]
+		z := stat.ZScore(usage)
+		if z <= s.zScoreThreshold {
+			continue
+		}
+		usual := fmt.Sprintf("a baseline of %.1f ± %.1f per hour", stat.Mean, stat.StdDev)
+		if !known {
+			usual = fmt.Sprintf("no use in the last %d hours", baseline.Hours)
+		}
+		anomalies = append(anomalies, s.newAnomaly(agent, domain.AnomalyTypeUnusualAPIUsage, z,
+			fmt.Sprintf("Unusual MCP server usage: %s → %s", agent.DisplayName, server),
+			fmt.Sprintf("Agent '%s' used MCP server '%s' in %d verifications between %s and %s UTC, against %s (z-score %.1f).",
+				agent.DisplayName, server, current.MCPServers[server], windowStart.Format("15:04"), windowStart.Add(time.Hour).Format("15:04"),
+				usual, z)))
+	}
+	return anomalies
+}
+
+// newAnomaly rates the anomaly by its z-score: twice the threshold is high severity (warning below), and the
+// confidence grows from 50 at the threshold towards 100
+func (s *AnomalyBaselineService) newAnomaly(agent *domain.Agent, anomalyType domain.AnomalyType, z float64, title, description string) *domain.Anomaly {
+	severity := domain.AlertSeverityWarning
+	if z >= 2*s.zScoreThreshold {
+		severity = domain.AlertSeverityHigh
+	}
+	confidence := 100 - 50*s.zScoreThreshold/z
+	return &domain.Anomaly{
+		OrganizationID: agent.OrganizationID,
+		AnomalyType:    anomalyType,
+		Severity:       severity,
+		Title:          title,
+		Description:    description,
+		ResourceType:   "agent",
+		ResourceID:     agent.ID,
+		Confidence:     math.Round(confidence*10) / 10,
+	}
+}
+
+// record creates the anomaly unless the same one was already recorded for this hour
+func (s *AnomalyBaselineService) record(ctx context.Context, anomaly *domain.Anomaly, windowEnd time.Time) (bool, error) {
+	existing, _, err := s.securityService.SearchAnomalies(ctx, anomaly.OrganizationID, domain.AnomalyQueryParams{
+		AnomalyTypes: []domain.AnomalyType{anomaly.AnomalyType},
+		ResourceType: anomaly.ResourceType,
+		ResourceID:   &anomaly.ResourceID,
+		From:         &windowEnd,
+		Limit:        100,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check for recorded anomalies: %w", err)
+	}
+	for _, e := range existing {
+		if e.Title == anomaly.Title {
+			return false, nil
+		}
+	}
+	if err := s.securityService.CreateAnomaly(ctx, anomaly); err != nil {
+		return false, fmt.Errorf("failed to record anomaly: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,88 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnomalyBaselinesFlagDeviationsFromAgentBehavior(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	steady := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.DisplayName = "Steady" })
+	nightOwl := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.DisplayName = "Night Owl" })
+	newcomer := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.DisplayName = "Newcomer" })
+	for _, agent := range []*domain.Agent{steady, nightOwl, newcomer} {
+		require.NoError(t, repos.Agent.Create(agent))
+	}
+	ctx := context.Background()
+
+	windowStart := time.Now().UTC().Truncate(time.Hour).Add(-time.Hour)
+	verify := func(agent *domain.Agent, hour time.Time, count int, servers ...string) {
+		for i := 0; i < count; i++ {
+			at := hour.Add(time.Duration(i) * time.Second)
+			require.NoError(t, repos.VerificationEvent.Create(testsupport.NewVerificationEvent(agent, func(e *domain.VerificationEvent) {
+				e.CreatedAt = at
+				e.CurrentMCPServers = servers
+			})))
+		}
+	}
+
+	// Three days of history: Steady verifies twice an hour through its filesystem server, Night Owl
+	// three times an hour except at this hour of the day, Newcomer only started recently
+	for h := 1; h <= 72; h++ {
+		hour := windowStart.Add(-time.Duration(h) * time.Hour)
+		verify(steady, hour, 2, "filesystem")
+		if hour.Hour() != windowStart.Hour() {
+			verify(nightOwl, hour, 3)
+		}
+		if h <= 5 {
+			verify(newcomer, hour, 1)
+		}
+	}
+	verify(steady, windowStart, 2, "filesystem")
+	verify(steady, windowStart, 5, "payments")
+	verify(steady, windowStart, 33)
+	verify(nightOwl, windowStart, 5)
+	verify(newcomer, windowStart, 50)
+
+	security := NewSecurityService(repos.Security, repos.Agent, repos.Alert)
+	service := NewAnomalyBaselineService(repos.VerificationEvent, repos.Agent, repos.Organization, security, 14*24*time.Hour, 3)
+
+	detected, err := service.DetectAllAnomalies(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, detected)
+
+	anomalies, total, err := security.SearchAnomalies(ctx, org.ID, domain.AnomalyQueryParams{SortBy: "title", SortOrder: "asc"})
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+
+	traffic := anomalies[0]
+	assert.Equal(t, "Abnormal verification rate: Steady", traffic.Title)
+	assert.Equal(t, domain.AnomalyTypeAbnormalTraffic, traffic.AnomalyType)
+	assert.Equal(t, domain.AlertSeverityHigh, traffic.Severity, "40 verifications against 2 ± 0 an hour")
+	assert.Equal(t, steady.ID, traffic.ResourceID)
+	assert.Equal(t, "agent", traffic.ResourceType)
+	assert.Greater(t, traffic.Confidence, 90.0)
+
+	offHours := anomalies[1]
+	assert.Equal(t, "Activity outside usual hours: Night Owl", offHours.Title)
+	assert.Equal(t, domain.AnomalyTypeUnusualAPIUsage, offHours.AnomalyType)
+	assert.Equal(t, domain.AlertSeverityWarning, offHours.Severity)
+
+	server := anomalies[2]
+	assert.Equal(t, "Unusual MCP server usage: Steady → payments", server.Title)
+	assert.Equal(t, domain.AnomalyTypeUnusualAPIUsage, server.AnomalyType)
+	assert.Contains(t, server.Description, "no use in the last 72 hours")
+
+	// Detection is idempotent within the hour
+	detected, err = service.DetectAllAnomalies(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, detected)
+}
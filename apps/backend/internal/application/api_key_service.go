@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,6 +13,15 @@ import (
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
+// ErrInvalidAPIKeyScope is returned when an API key is requested with an unknown scope
+var ErrInvalidAPIKeyScope = errors.New("invalid API key scope")
+
+// ErrInvalidAPIKey is returned when a key is unknown or was revoked
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// ErrAPIKeyExpired is returned when a key is past its expiry
+var ErrAPIKeyExpired = errors.New("API key expired")
+
 // APIKeyService handles API key operations
 type APIKeyService struct {
 	apiKeyRepo domain.APIKeyRepository
@@ -29,8 +39,14 @@ func NewAPIKeyService(
 	}
 }
 
-// GenerateAPIKey generates a new API key for an agent
-func (s *APIKeyService) GenerateAPIKey(ctx context.Context, agentID, orgID, userID uuid.UUID, name string, expiresInDays int) (string, *domain.APIKey, error) {
+// GenerateAPIKey generates a new API key for an agent.
+// Without scopes the key carries domain.APIKeyLegacyScopes, as keys did before scoping.
+func (s *APIKeyService) GenerateAPIKey(ctx context.Context, agentID, orgID, userID uuid.UUID, name string, expiresInDays int, scopes []string) (string, *domain.APIKey, error) {
+	scopes, err := normalizeAPIKeyScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
 	// Verify agent exists and belongs to organization
 	agent, err := s.agentRepo.GetByID(agentID)
 	if err != nil {
@@ -77,6 +93,7 @@ func (s *APIKeyService) GenerateAPIKey(ctx context.Context, agentID, orgID, user
 		ExpiresAt:      expiresAt,
 		IsActive:       true,
 		CreatedBy:      userID,
+		Scopes:         scopes,
 	}
 
 	if err := s.apiKeyRepo.Create(apiKey); err != nil {
@@ -86,6 +103,22 @@ func (s *APIKeyService) GenerateAPIKey(ctx context.Context, agentID, orgID, user
 	return fullKey, apiKey, nil
 }
 
+// normalizeAPIKeyScopes validates requested scopes and drops duplicates
+func normalizeAPIKeyScopes(scopes []string) ([]string, error) {
+	normalized := make([]string, 0, len(scopes))
+	seen := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		if !domain.IsValidAPIKeyScope(scope) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAPIKeyScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized, nil
+}
+
 // ListAPIKeys lists all API keys for an organization
 func (s *APIKeyService) ListAPIKeys(ctx context.Context, orgID uuid.UUID) ([]*domain.APIKey, error) {
 	return s.apiKeyRepo.GetByOrganization(orgID)
@@ -126,7 +159,8 @@ func (s *APIKeyService) DeleteAPIKey(ctx context.Context, keyID, orgID uuid.UUID
 	return s.apiKeyRepo.Delete(keyID)
 }
 
-// ValidateAPIKey validates an API key and returns the associated API key record
+// ValidateAPIKey validates an API key and returns the associated API key record. Keys are looked
+// up through the repository's cache, which revoking and expiring a key invalidate.
 func (s *APIKeyService) ValidateAPIKey(ctx context.Context, fullKey string) (*domain.APIKey, error) {
 	// Hash the provided key
 	hash := sha256.Sum256([]byte(fullKey))
@@ -138,13 +172,13 @@ func (s *APIKeyService) ValidateAPIKey(ctx context.Context, fullKey string) (*do
 		return nil, err
 	}
 
-	if apiKey == nil {
-		return nil, fmt.Errorf("invalid API key")
+	if apiKey == nil || !apiKey.IsActive {
+		return nil, ErrInvalidAPIKey
 	}
 
 	// Check if expired
 	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
-		return nil, fmt.Errorf("API key expired")
+		return nil, ErrAPIKeyExpired
 	}
 
 	// Update last used timestamp
@@ -0,0 +1,40 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopedAPIKeys(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	agent := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(agent))
+	apiKeyService := NewAPIKeyService(repos.APIKey, repos.Agent)
+
+	_, _, err := apiKeyService.GenerateAPIKey(context.Background(), agent.ID, org.ID, agent.CreatedBy, "bad", 30, []string{"agents:delete"})
+	assert.ErrorIs(t, err, ErrInvalidAPIKeyScope)
+
+	rawKey, key, err := apiKeyService.GenerateAPIKey(context.Background(), agent.ID, org.ID, agent.CreatedBy, "ci", 30,
+		[]string{domain.APIKeyScopeVerifyCreate, domain.APIKeyScopeVerifyCreate, "agents:*"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.APIKeyScopeVerifyCreate, "agents:*"}, key.Scopes, "duplicates are dropped")
+
+	validated, err := apiKeyService.ValidateAPIKey(context.Background(), rawKey)
+	require.NoError(t, err)
+	assert.True(t, validated.HasScope(domain.APIKeyScopeVerifyCreate))
+	assert.False(t, validated.HasScope(domain.APIKeyScopeVerifyRead))
+	assert.True(t, validated.HasScope(domain.APIKeyScopeAgentsWrite), "resource wildcard")
+
+	_, legacy, err := apiKeyService.GenerateAPIKey(context.Background(), agent.ID, org.ID, agent.CreatedBy, "legacy", 30, nil)
+	require.NoError(t, err)
+	assert.True(t, legacy.HasScope(domain.APIKeyScopeVerifyCreate), "keys without scopes keep the SDK scopes")
+	assert.True(t, legacy.HasScope(domain.APIKeyScopeAgentsWrite), "keys without scopes keep registering agents")
+	assert.False(t, legacy.HasScope(domain.APIKeyScopeAgentsRead))
+	assert.False(t, legacy.HasScope(domain.APIKeyScopeIntrospect))
+}
@@ -0,0 +1,407 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// MaxApprovalChainSteps caps the number of approvals one chain can require
+const MaxApprovalChainSteps = 5
+
+var (
+	// ErrInvalidApprovalChain is returned when an approval chain definition is malformed
+	ErrInvalidApprovalChain = errors.New("invalid approval chain")
+	// ErrApprovalChainNotFound is returned for unknown chains and chains of another organization
+	ErrApprovalChainNotFound = errors.New("approval chain not found")
+	// ErrApprovalRequestNotFound is returned for unknown requests and requests of another organization
+	ErrApprovalRequestNotFound = errors.New("approval request not found")
+	// ErrApprovalNotAllowed is returned when the user cannot approve the request's next step:
+	// they requested the action, already approved it, or lack the step's role
+	ErrApprovalNotAllowed = errors.New("approval not allowed")
+	// ErrApprovalRequestClosed is returned when deciding on a request that is no longer pending
+	ErrApprovalRequestClosed = errors.New("approval request is not pending")
+	// ErrApprovalConflict is returned when another decision was recorded at the same time
+	ErrApprovalConflict = errors.New("approval request was decided concurrently, retry")
+)
+
+// ApprovalSubject describes an action on a resource that may be guarded by an approval chain
+type ApprovalSubject struct {
+	OrganizationID uuid.UUID
+	Action         domain.ApprovalAction
+	ResourceType   string
+	ResourceID     uuid.UUID
+	Summary        string
+	RequestedBy    *uuid.UUID           // Initiator of the action (e.g. the capability requester)
+	RiskSeverity   domain.AlertSeverity // Risk of the capability, for capability grants
+	AgentID        *uuid.UUID           // Agent whose tags scope agent verification chains
+}
+
+// ApprovalChainRequest creates or replaces an approval chain
+type ApprovalChainRequest struct {
+	Name            string                `json:"name"`
+	Action          domain.ApprovalAction `json:"action"`
+	Steps           []domain.ApprovalStep `json:"steps"`
+	MinRiskSeverity domain.AlertSeverity  `json:"minRiskSeverity"`
+	AgentTags       []string              `json:"agentTags"`
+	IsEnabled       *bool                 `json:"isEnabled"` // Defaults to true
+}
+
+// ApprovalChainService is the approval engine shared by capability grants, agent verification and
+// policy changes. Guarded services call Approve each time a user performs the action: every call
+// records one approval, and the action may only take effect once the chain's last step is approved.
+type ApprovalChainService struct {
+	chainRepo   domain.ApprovalChainRepository
+	requestRepo domain.ApprovalRequestRepository
+	userRepo    domain.UserRepository
+	tagRepo     domain.TagRepository
+}
+
+// NewApprovalChainService creates a new approval chain service
+func NewApprovalChainService(
+	chainRepo domain.ApprovalChainRepository,
+	requestRepo domain.ApprovalRequestRepository,
+	userRepo domain.UserRepository,
+	tagRepo domain.TagRepository,
+) *ApprovalChainService {
+	return &ApprovalChainService{
+		chainRepo:   chainRepo,
+		requestRepo: requestRepo,
+		userRepo:    userRepo,
+		tagRepo:     tagRepo,
+	}
+}
+
+// Approve records the user's approval of the subject. It returns a nil request when no chain
+// guards the subject, so the action can proceed at once. Otherwise the action can proceed only
+// when the returned request is approved; while it is pending, later calls by other users fill
+// the remaining steps.
+func (s *ApprovalChainService) Approve(ctx context.Context, subject ApprovalSubject, approverID uuid.UUID, comment string) (*domain.ApprovalRequest, error) {
+	request, err := s.requestRepo.GetPending(subject.OrganizationID, subject.Action, subject.ResourceID)
+	if err != nil {
+		request, err = s.openRequest(ctx, subject)
+		if err != nil || request == nil {
+			return nil, err
+		}
+	}
+
+	approver, err := s.userRepo.GetByID(approverID)
+	if err != nil || approver.OrganizationID != subject.OrganizationID {
+		return nil, fmt.Errorf("%w: unknown approver", ErrApprovalNotAllowed)
+	}
+
+	step := request.NextStep()
+	if request.RequestedBy != nil && *request.RequestedBy == approverID {
+		return request, fmt.Errorf("%w: the requester cannot approve their own request", ErrApprovalNotAllowed)
+	}
+	for _, decision := range request.Decisions {
+		if decision.UserID == approverID {
+			return request, fmt.Errorf("%w: each step must be approved by a different user", ErrApprovalNotAllowed)
+		}
+	}
+	if required := request.Steps[step].Role; roleRank(approver.Role) < roleRank(required) {
+		return request, fmt.Errorf("%w: step %d of %d requires the %s role", ErrApprovalNotAllowed, step+1, len(request.Steps), required)
+	}
+
+	expected := len(request.Decisions)
+	request.Decisions = append(request.Decisions, domain.ApprovalDecision{
+		Step:      step,
+		UserID:    approver.ID,
+		UserEmail: approver.Email,
+		Role:      approver.Role,
+		Approved:  true,
+		Comment:   comment,
+		DecidedAt: time.Now().UTC(),
+	})
+	if request.NextStep() == -1 {
+		now := time.Now().UTC()
+		request.Status = domain.ApprovalRequestStatusApproved
+		request.CompletedAt = &now
+	}
+
+	recorded, err := s.requestRepo.RecordDecision(request, expected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record approval: %w", err)
+	}
+	if !recorded {
+		return nil, ErrApprovalConflict
+	}
+	return request, nil
+}
+
+// openRequest opens an approval request for the strictest enabled chain guarding the subject,
+// or returns nil when no chain applies
+func (s *ApprovalChainService) openRequest(ctx context.Context, subject ApprovalSubject) (*domain.ApprovalRequest, error) {
+	chains, err := s.chainRepo.GetEnabledByAction(subject.OrganizationID, subject.Action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load approval chains: %w", err)
+	}
+	if len(chains) == 0 {
+		return nil, nil
+	}
+
+	var agentTags []string
+	if subject.AgentID != nil && s.tagRepo != nil {
+		tags, err := s.tagRepo.GetAgentTags(ctx, *subject.AgentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load agent tags: %w", err)
+		}
+		for _, tag := range tags {
+			agentTags = append(agentTags, strings.ToLower(tag.Key+":"+tag.Value))
+		}
+	}
+
+	var chain *domain.ApprovalChain
+	for _, candidate := range chains {
+		if chainApplies(candidate, subject, agentTags) && (chain == nil || len(candidate.Steps) > len(chain.Steps)) {
+			chain = candidate
+		}
+	}
+	if chain == nil {
+		return nil, nil
+	}
+
+	request := &domain.ApprovalRequest{
+		ID:             uuid.New(),
+		OrganizationID: subject.OrganizationID,
+		ChainID:        chain.ID,
+		ChainName:      chain.Name,
+		Action:         subject.Action,
+		ResourceType:   subject.ResourceType,
+		ResourceID:     subject.ResourceID,
+		Summary:        subject.Summary,
+		Steps:          chain.Steps,
+		Decisions:      []domain.ApprovalDecision{},
+		Status:         domain.ApprovalRequestStatusPending,
+		RequestedBy:    subject.RequestedBy,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := s.requestRepo.Create(request); err != nil {
+		// Another approver opened the request first
+		if pending, getErr := s.requestRepo.GetPending(subject.OrganizationID, subject.Action, subject.ResourceID); getErr == nil {
+			return pending, nil
+		}
+		return nil, fmt.Errorf("failed to open approval request: %w", err)
+	}
+	return request, nil
+}
+
+// chainApplies reports whether the chain's scope covers the subject
+func chainApplies(chain *domain.ApprovalChain, subject ApprovalSubject, agentTags []string) bool {
+	switch chain.Action {
+	case domain.ApprovalActionCapabilityGrant:
+		return chain.MinRiskSeverity == "" || severityRank(subject.RiskSeverity) >= severityRank(chain.MinRiskSeverity)
+	case domain.ApprovalActionAgentVerification:
+		if len(chain.AgentTags) == 0 {
+			return true
+		}
+		for _, tag := range chain.AgentTags {
+			if slices.Contains(agentTags, strings.ToLower(tag)) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// Reject rejects a pending approval request, which stops the guarded action
+func (s *ApprovalChainService) Reject(ctx context.Context, orgID, requestID, userID uuid.UUID, comment string) (*domain.ApprovalRequest, error) {
+	request, err := s.requestRepo.GetByID(requestID)
+	if err != nil || request.OrganizationID != orgID {
+		return nil, ErrApprovalRequestNotFound
+	}
+	return s.reject(request, userID, comment)
+}
+
+// RejectPending rejects the pending approval request for an action on a resource, if there is one
+func (s *ApprovalChainService) RejectPending(ctx context.Context, orgID uuid.UUID, action domain.ApprovalAction, resourceID, userID uuid.UUID, comment string) error {
+	request, err := s.requestRepo.GetPending(orgID, action, resourceID)
+	if err != nil {
+		return nil
+	}
+	_, err = s.reject(request, userID, comment)
+	return err
+}
+
+func (s *ApprovalChainService) reject(request *domain.ApprovalRequest, userID uuid.UUID, comment string) (*domain.ApprovalRequest, error) {
+	if request.Status != domain.ApprovalRequestStatusPending {
+		return nil, ErrApprovalRequestClosed
+	}
+
+	decision := domain.ApprovalDecision{
+		Step:      request.NextStep(),
+		UserID:    userID,
+		Approved:  false,
+		Comment:   comment,
+		DecidedAt: time.Now().UTC(),
+	}
+	if user, err := s.userRepo.GetByID(userID); err == nil {
+		decision.UserEmail = user.Email
+		decision.Role = user.Role
+	}
+
+	expected := len(request.Decisions)
+	request.Decisions = append(request.Decisions, decision)
+	request.Status = domain.ApprovalRequestStatusRejected
+	request.CompletedAt = &decision.DecidedAt
+
+	recorded, err := s.requestRepo.RecordDecision(request, expected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record rejection: %w", err)
+	}
+	if !recorded {
+		return nil, ErrApprovalConflict
+	}
+	return request, nil
+}
+
+// GetRequest retrieves an approval request of the organization
+func (s *ApprovalChainService) GetRequest(ctx context.Context, orgID, requestID uuid.UUID) (*domain.ApprovalRequest, error) {
+	request, err := s.requestRepo.GetByID(requestID)
+	if err != nil || request.OrganizationID != orgID {
+		return nil, ErrApprovalRequestNotFound
+	}
+	return request, nil
+}
+
+// ListRequests lists the organization's approval requests newest first; an empty status lists all
+func (s *ApprovalChainService) ListRequests(ctx context.Context, orgID uuid.UUID, status domain.ApprovalRequestStatus, limit, offset int) ([]*domain.ApprovalRequest, error) {
+	return s.requestRepo.GetByOrganization(orgID, status, limit, offset)
+}
+
+// ListChains lists the organization's approval chains
+func (s *ApprovalChainService) ListChains(ctx context.Context, orgID uuid.UUID) ([]*domain.ApprovalChain, error) {
+	return s.chainRepo.GetByOrganization(orgID)
+}
+
+// CreateChain creates an approval chain for the organization
+func (s *ApprovalChainService) CreateChain(ctx context.Context, orgID, userID uuid.UUID, req *ApprovalChainRequest) (*domain.ApprovalChain, error) {
+	chain := &domain.ApprovalChain{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		CreatedBy:      userID,
+	}
+	if err := applyApprovalChainRequest(chain, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.chainRepo.Create(chain); err != nil {
+		return nil, fmt.Errorf("failed to create approval chain: %w", err)
+	}
+	return chain, nil
+}
+
+// UpdateChain replaces an approval chain's definition; its action cannot change.
+// Requests already collecting approvals keep the steps they were opened with.
+func (s *ApprovalChainService) UpdateChain(ctx context.Context, orgID, chainID uuid.UUID, req *ApprovalChainRequest) (*domain.ApprovalChain, error) {
+	chain, err := s.chainRepo.GetByID(chainID)
+	if err != nil || chain.OrganizationID != orgID {
+		return nil, ErrApprovalChainNotFound
+	}
+	if req.Action == "" {
+		req.Action = chain.Action
+	}
+	if req.Action != chain.Action {
+		return nil, fmt.Errorf("%w: the action of a chain cannot change", ErrInvalidApprovalChain)
+	}
+	if err := applyApprovalChainRequest(chain, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.chainRepo.Update(chain); err != nil {
+		return nil, fmt.Errorf("failed to update approval chain: %w", err)
+	}
+	return chain, nil
+}
+
+// DeleteChain deletes an approval chain of the organization
+func (s *ApprovalChainService) DeleteChain(ctx context.Context, orgID, chainID uuid.UUID) error {
+	chain, err := s.chainRepo.GetByID(chainID)
+	if err != nil || chain.OrganizationID != orgID {
+		return ErrApprovalChainNotFound
+	}
+	return s.chainRepo.Delete(chainID)
+}
+
+// applyApprovalChainRequest validates the request and copies it onto the chain
+func applyApprovalChainRequest(chain *domain.ApprovalChain, req *ApprovalChainRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidApprovalChain)
+	}
+	if !req.Action.IsValid() {
+		return fmt.Errorf("%w: action must be capability_grant, agent_verification, policy_disable or config_change", ErrInvalidApprovalChain)
+	}
+	if len(req.Steps) == 0 || len(req.Steps) > MaxApprovalChainSteps {
+		return fmt.Errorf("%w: a chain needs between 1 and %d steps", ErrInvalidApprovalChain, MaxApprovalChainSteps)
+	}
+	for i, step := range req.Steps {
+		switch step.Role {
+		case "", domain.RoleMember, domain.RoleManager, domain.RoleAdmin:
+		default:
+			return fmt.Errorf("%w: step %d has an invalid role %q", ErrInvalidApprovalChain, i+1, step.Role)
+		}
+	}
+
+	if req.MinRiskSeverity != "" {
+		if req.Action != domain.ApprovalActionCapabilityGrant {
+			return fmt.Errorf("%w: minRiskSeverity only applies to capability_grant chains", ErrInvalidApprovalChain)
+		}
+		if severityRank(req.MinRiskSeverity) < 0 {
+			return fmt.Errorf("%w: invalid minRiskSeverity %q", ErrInvalidApprovalChain, req.MinRiskSeverity)
+		}
+	}
+
+	tags := make([]string, 0, len(req.AgentTags))
+	for _, tag := range req.AgentTags {
+		tag = strings.TrimSpace(tag)
+		if key, value, ok := strings.Cut(tag, ":"); !ok || key == "" || value == "" {
+			return fmt.Errorf("%w: agent tags must be key:value, got %q", ErrInvalidApprovalChain, tag)
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) > 0 && req.Action != domain.ApprovalActionAgentVerification {
+		return fmt.Errorf("%w: agentTags only apply to agent_verification chains", ErrInvalidApprovalChain)
+	}
+
+	chain.Name = name
+	chain.Action = req.Action
+	chain.Steps = req.Steps
+	chain.MinRiskSeverity = req.MinRiskSeverity
+	chain.AgentTags = tags
+	chain.IsEnabled = req.IsEnabled == nil || *req.IsEnabled
+	return nil
+}
+
+// roleRank orders user roles for approval steps; an empty role is satisfied by anyone
+func roleRank(role domain.UserRole) int {
+	switch role {
+	case domain.RoleViewer:
+		return 1
+	case domain.RoleMember:
+		return 2
+	case domain.RoleManager:
+		return 3
+	case domain.RoleAdmin:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// capabilityRiskSeverity rates a capability for approval chain scoping, using the same
+// ratings as capability drift alerts; capabilities outside the standard taxonomy are high
+func capabilityRiskSeverity(capabilityType string) domain.AlertSeverity {
+	if severity, ok := capabilityDriftSeverities[capabilityType]; ok {
+		return severity
+	}
+	return domain.AlertSeverityHigh
+}
@@ -0,0 +1,160 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApprovalChainsRequireDistinctApprovers(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	ctx := context.Background()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+
+	newUser := func(role domain.UserRole) *domain.User {
+		user := testsupport.NewUser(org.ID, func(u *domain.User) { u.Role = role })
+		require.NoError(t, repos.User.Create(user))
+		return user
+	}
+	requester, manager := newUser(domain.RoleMember), newUser(domain.RoleManager)
+	admin, otherAdmin := newUser(domain.RoleAdmin), newUser(domain.RoleAdmin)
+
+	approvals := NewApprovalChainService(repos.ApprovalChain, repos.ApprovalRequest, repos.User, repos.Tag)
+	_, err := approvals.CreateChain(ctx, org.ID, admin.ID, &ApprovalChainRequest{
+		Name:            "Critical grants",
+		Action:          domain.ApprovalActionCapabilityGrant,
+		Steps:           []domain.ApprovalStep{{Role: domain.RoleManager}, {Role: domain.RoleAdmin}},
+		MinRiskSeverity: domain.AlertSeverityCritical,
+	})
+	require.NoError(t, err)
+	_, err = approvals.CreateChain(ctx, org.ID, admin.ID, &ApprovalChainRequest{
+		Name:   "Tags on the wrong action",
+		Action: domain.ApprovalActionCapabilityGrant, Steps: []domain.ApprovalStep{{}}, AgentTags: []string{"environment:prod"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidApprovalChain)
+
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.Status = domain.AgentStatusPending })
+	require.NoError(t, repos.Agent.Create(agent))
+	capabilityService := NewCapabilityRequestService(repos.CapabilityRequest, repos.Capability, repos.Agent, approvals)
+	granted := func(capabilityType string) bool {
+		capabilities, err := repos.Capability.GetActiveCapabilitiesByAgentID(agent.ID)
+		require.NoError(t, err)
+		for _, capability := range capabilities {
+			if capability.CapabilityType == capabilityType {
+				return true
+			}
+		}
+		return false
+	}
+	request := func(capabilityType string) *domain.CapabilityRequest {
+		req := &domain.CapabilityRequest{AgentID: agent.ID, CapabilityType: capabilityType, Reason: "needed", RequestedBy: requester.ID}
+		require.NoError(t, repos.CapabilityRequest.Create(req))
+		return req
+	}
+
+	// Below the chain's risk threshold, one approval grants the capability
+	approval, err := capabilityService.ApproveRequest(ctx, request(domain.CapabilityFileRead).ID, manager.ID)
+	require.NoError(t, err)
+	assert.Nil(t, approval)
+
+	export := request(domain.CapabilityDataExport)
+	_, err = capabilityService.ApproveRequest(ctx, export.ID, requester.ID)
+	assert.ErrorIs(t, err, ErrApprovalNotAllowed, "requesters cannot approve their own grant")
+	_, err = capabilityService.ApproveRequest(ctx, export.ID, otherAdmin.ID)
+	require.NoError(t, err, "an admin satisfies the manager step")
+
+	approval, err = capabilityService.ApproveRequest(ctx, export.ID, otherAdmin.ID)
+	assert.ErrorIs(t, err, ErrApprovalNotAllowed, "each step needs a different user")
+	_, err = capabilityService.ApproveRequest(ctx, export.ID, manager.ID)
+	assert.ErrorIs(t, err, ErrApprovalNotAllowed, "the second step requires an admin")
+	assert.False(t, granted(domain.CapabilityDataExport), "nothing is granted while the chain is pending")
+	require.NotNil(t, approval)
+	assert.Equal(t, domain.ApprovalRequestStatusPending, approval.Status)
+	assert.Equal(t, 1, approval.NextStep())
+
+	approval, err = capabilityService.ApproveRequest(ctx, export.ID, admin.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ApprovalRequestStatusApproved, approval.Status)
+	require.Len(t, approval.Decisions, 2)
+	assert.Equal(t, []uuid.UUID{otherAdmin.ID, admin.ID}, []uuid.UUID{approval.Decisions[0].UserID, approval.Decisions[1].UserID})
+	assert.True(t, granted(domain.CapabilityDataExport))
+
+	// Rejecting the capability request closes its approval request
+	impersonate := request(domain.CapabilityUserImpersonate)
+	pending, err := capabilityService.ApproveRequest(ctx, impersonate.ID, manager.ID)
+	require.NoError(t, err)
+	require.NoError(t, capabilityService.RejectRequest(ctx, impersonate.ID, admin.ID))
+	closed, err := approvals.GetRequest(ctx, org.ID, pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ApprovalRequestStatusRejected, closed.Status)
+
+	// Verification chains are scoped by agent tags
+	_, err = approvals.CreateChain(ctx, org.ID, admin.ID, &ApprovalChainRequest{
+		Name:      "Production agents",
+		Action:    domain.ApprovalActionAgentVerification,
+		Steps:     []domain.ApprovalStep{{}, {}},
+		AgentTags: []string{"Environment:Prod"},
+	})
+	require.NoError(t, err)
+	prod := &domain.Tag{OrganizationID: org.ID, Key: "environment", Value: "prod", Category: domain.TagCategoryEnvironment}
+	require.NoError(t, repos.Tag.Create(ctx, prod))
+	prodAgent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.Status = domain.AgentStatusPending })
+	require.NoError(t, repos.Agent.Create(prodAgent))
+	require.NoError(t, repos.Tag.AddTagsToAgent(ctx, prodAgent.ID, []uuid.UUID{prod.ID}))
+
+	trustCalc := NewTrustCalculator(repos.TrustScore, repos.APIKey, repos.AuditLog, repos.Capability, repos.Agent, repos.Alert)
+	agentService := NewAgentService(repos.Agent, trustCalc, repos.TrustScore, nil, repos.Alert, nil, repos.Capability, nil, nil, repos.Organization, approvals, nil, nil, nil)
+
+	approval, err = agentService.VerifyAgent(ctx, agent.ID, manager.ID)
+	require.NoError(t, err)
+	assert.Nil(t, approval, "untagged agents are verified at once")
+	approval, err = agentService.VerifyAgent(ctx, prodAgent.ID, manager.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ApprovalRequestStatusPending, approval.Status)
+	stored, err := repos.Agent.GetByID(prodAgent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AgentStatusPending, stored.Status)
+	approval, err = agentService.VerifyAgent(ctx, prodAgent.ID, requester.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ApprovalRequestStatusApproved, approval.Status)
+	stored, err = repos.Agent.GetByID(prodAgent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AgentStatusVerified, stored.Status)
+
+	// Disabling a policy waits for the chain; a rejection leaves it enabled
+	_, err = approvals.CreateChain(ctx, org.ID, admin.ID, &ApprovalChainRequest{
+		Name:   "Policy changes",
+		Action: domain.ApprovalActionPolicyDisable,
+		Steps:  []domain.ApprovalStep{{Role: domain.RoleAdmin}, {Role: domain.RoleAdmin}},
+	})
+	require.NoError(t, err)
+	policyService := NewSecurityPolicyService(repos.SecurityPolicy, repos.Alert, repos.AuditLog, repos.MCPServerCapability, approvals)
+	policy := &domain.SecurityPolicy{OrganizationID: org.ID, Name: "Block low trust", PolicyType: domain.PolicyTypeTrustScoreLow, AppliesTo: "all", IsEnabled: true}
+	require.NoError(t, repos.SecurityPolicy.Create(policy))
+
+	pending, err = policyService.DisablePolicy(ctx, policy.ID, admin.ID)
+	require.NoError(t, err)
+	_, err = approvals.Reject(ctx, org.ID, pending.ID, otherAdmin.ID, "keep it on")
+	require.NoError(t, err)
+	_, err = approvals.Reject(ctx, org.ID, pending.ID, otherAdmin.ID, "")
+	assert.ErrorIs(t, err, ErrApprovalRequestClosed)
+
+	_, err = policyService.DisablePolicy(ctx, policy.ID, admin.ID)
+	require.NoError(t, err, "a rejected request does not block a new one")
+	approval, err = policyService.DisablePolicy(ctx, policy.ID, otherAdmin.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ApprovalRequestStatusApproved, approval.Status)
+	disabled, err := repos.SecurityPolicy.GetByID(policy.ID)
+	require.NoError(t, err)
+	assert.False(t, disabled.IsEnabled)
+
+	rejected, err := approvals.ListRequests(ctx, org.ID, domain.ApprovalRequestStatusRejected, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, rejected, 2)
+}
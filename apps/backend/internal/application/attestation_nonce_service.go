@@ -0,0 +1,87 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// AttestationNonceTTL is how long an agent has to sign an issued nonce into an attestation
+const AttestationNonceTTL = 5 * time.Minute
+
+var (
+	// ErrAttestationNonceRequired is returned when an attestation carries no nonce
+	ErrAttestationNonceRequired = errors.New("attestation nonce is required")
+	// ErrAttestationNonceInvalid is returned for unknown or expired nonces and nonces issued to another agent
+	ErrAttestationNonceInvalid = errors.New("attestation nonce is invalid or expired")
+	// ErrAttestationReplayed is returned when a nonce was already used by an earlier attestation
+	ErrAttestationReplayed = errors.New("attestation replayed: nonce already used")
+)
+
+// AttestationNonceService issues single-use nonces and enforces that each one is consumed
+// by at most one attestation
+type AttestationNonceService struct {
+	nonceRepo domain.AttestationNonceRepository
+}
+
+func NewAttestationNonceService(nonceRepo domain.AttestationNonceRepository) *AttestationNonceService {
+	return &AttestationNonceService{nonceRepo: nonceRepo}
+}
+
+// Issue creates a nonce for the agent that expires after AttestationNonceTTL
+func (s *AttestationNonceService) Issue(ctx context.Context, agent *domain.Agent) (*domain.AttestationNonce, error) {
+	value := make([]byte, 32)
+	if _, err := rand.Read(value); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	now := time.Now().UTC()
+	nonce := &domain.AttestationNonce{
+		ID:             uuid.New(),
+		Nonce:          hex.EncodeToString(value),
+		AgentID:        agent.ID,
+		OrganizationID: agent.OrganizationID,
+		ExpiresAt:      now.Add(AttestationNonceTTL),
+		CreatedAt:      now,
+	}
+	if err := s.nonceRepo.Create(nonce); err != nil {
+		return nil, fmt.Errorf("failed to store nonce: %w", err)
+	}
+
+	// Expired nonces can no longer be used, so they are pruned as new ones are issued
+	if _, err := s.nonceRepo.DeleteExpired(now); err != nil {
+		fmt.Printf("⚠️  Failed to delete expired attestation nonces: %v\n", err)
+	}
+
+	return nonce, nil
+}
+
+// Consume uses up the agent's nonce. A nonce that was already used is reported as a replay.
+func (s *AttestationNonceService) Consume(ctx context.Context, agentID uuid.UUID, value string) error {
+	if value == "" {
+		return ErrAttestationNonceRequired
+	}
+
+	consumed, err := s.nonceRepo.Consume(value, agentID, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to consume nonce: %w", err)
+	}
+	if consumed {
+		return nil
+	}
+
+	nonce, err := s.nonceRepo.GetByNonce(value)
+	if err != nil || nonce.AgentID != agentID {
+		return ErrAttestationNonceInvalid
+	}
+	if nonce.ConsumedAt != nil {
+		return ErrAttestationReplayed
+	}
+	return ErrAttestationNonceInvalid
+}
@@ -0,0 +1,46 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttestationNoncesAreSingleUse(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	agent := testsupport.NewAgent(org.ID)
+	other := testsupport.NewAgent(org.ID)
+	ctx := context.Background()
+	service := NewAttestationNonceService(repos.AttestationNonce)
+
+	nonce, err := service.Issue(ctx, agent)
+	require.NoError(t, err)
+	assert.Len(t, nonce.Nonce, 64)
+	assert.WithinDuration(t, time.Now().Add(AttestationNonceTTL), nonce.ExpiresAt, time.Minute)
+
+	assert.ErrorIs(t, service.Consume(ctx, agent.ID, ""), ErrAttestationNonceRequired)
+	assert.ErrorIs(t, service.Consume(ctx, other.ID, nonce.Nonce), ErrAttestationNonceInvalid)
+	require.NoError(t, service.Consume(ctx, agent.ID, nonce.Nonce))
+	assert.ErrorIs(t, service.Consume(ctx, agent.ID, nonce.Nonce), ErrAttestationReplayed)
+
+	expired := &domain.AttestationNonce{
+		Nonce:          "expired",
+		AgentID:        agent.ID,
+		OrganizationID: org.ID,
+		ExpiresAt:      time.Now().Add(-time.Minute),
+	}
+	require.NoError(t, repos.AttestationNonce.Create(expired))
+	assert.ErrorIs(t, service.Consume(ctx, agent.ID, expired.Nonce), ErrAttestationNonceInvalid)
+
+	// Issuing prunes expired nonces
+	_, err = service.Issue(ctx, agent)
+	require.NoError(t, err)
+	_, err = repos.AttestationNonce.GetByNonce(expired.Nonce)
+	assert.Error(t, err)
+}
@@ -35,6 +35,17 @@ func (s *AuditService) LogAction(
 	ipAddress, userAgent string,
 	metadata map[string]interface{},
 ) error {
+	// Actions taken in an impersonation session are attributed to the consenting admin; tag
+	// them so the organization can tell them apart
+	if caller, ok := domain.CallerFromContext(ctx); ok && caller.ImpersonationID != nil {
+		tagged := make(map[string]interface{}, len(metadata)+1)
+		for key, value := range metadata {
+			tagged[key] = value
+		}
+		tagged["impersonation_id"] = *caller.ImpersonationID
+		metadata = tagged
+	}
+
 	log := &domain.AuditLog{
 		OrganizationID: orgID,
 		UserID:         userID,
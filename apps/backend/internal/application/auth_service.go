@@ -90,6 +90,28 @@ func (s *AuthService) GetUserByID(ctx context.Context, userID uuid.UUID) (*domai
 	return s.userRepo.GetByID(userID)
 }
 
+// GetUsersByIDs returns the organization's users with the given IDs in one query.
+// IDs that do not exist or belong to another organization are skipped.
+func (s *AuthService) GetUsersByIDs(ctx context.Context, orgID uuid.UUID, ids []uuid.UUID) ([]*domain.User, error) {
+	ids, err := uniqueBatchIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.userRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	result := make([]*domain.User, 0, len(users))
+	for _, user := range users {
+		if user.OrganizationID == orgID {
+			result = append(result, user)
+		}
+	}
+	return result, nil
+}
+
 // GetUserByEmail retrieves a user by email
 func (s *AuthService) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	return s.userRepo.GetByEmail(email)
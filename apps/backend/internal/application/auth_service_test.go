@@ -37,6 +37,14 @@ func (m *MockUserRepository) GetByID(id uuid.UUID) (*domain.User, error) {
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetByIDs(ids []uuid.UUID) ([]*domain.User, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.User), args.Error(1)
+}
+
 func (m *MockUserRepository) GetByEmail(email string) (*domain.User, error) {
 	args := m.Called(email)
 	if args.Get(0) == nil {
@@ -74,6 +82,11 @@ func (m *MockUserRepository) Update(user *domain.User) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateMFA(user *domain.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) UpdateRole(id uuid.UUID, role domain.UserRole) error {
 	args := m.Called(id, role)
 	return args.Error(0)
@@ -125,6 +138,22 @@ func (m *MockOrganizationRepository) Delete(id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockOrganizationRepository) List() ([]*domain.Organization, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) GetChildren(parentID uuid.UUID) ([]*domain.Organization, error) {
+	args := m.Called(parentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Organization), args.Error(1)
+}
+
 // MockAPIKeyRepository for testing
 type MockAPIKeyRepository struct {
 	mock.Mock
@@ -172,6 +201,11 @@ func (m *MockAPIKeyRepository) UpdateLastUsed(id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockAPIKeyRepository) UpdateExpiry(id uuid.UUID, expiresAt *time.Time) error {
+	args := m.Called(id, expiresAt)
+	return args.Error(0)
+}
+
 func (m *MockAPIKeyRepository) Revoke(id uuid.UUID) error {
 	args := m.Called(id)
 	return args.Error(0)
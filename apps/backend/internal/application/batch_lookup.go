@@ -0,0 +1,51 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// MaxBatchLookupIDs bounds how many records a single batch lookup may request
+const MaxBatchLookupIDs = 500
+
+// ErrBatchLookupTooLarge is returned when a batch lookup requests more than MaxBatchLookupIDs records
+var ErrBatchLookupTooLarge = errors.New("too many IDs in batch lookup")
+
+// uniqueBatchIDs drops nil and duplicate IDs, keeping the request order
+func uniqueBatchIDs(ids []uuid.UUID) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if id == uuid.Nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+
+	if len(unique) > MaxBatchLookupIDs {
+		return nil, fmt.Errorf("%w: %d requested, at most %d allowed", ErrBatchLookupTooLarge, len(unique), MaxBatchLookupIDs)
+	}
+	return unique, nil
+}
+
+// agentsByID indexes agents by ID for hydrating joined data
+func agentsByID(agents []*domain.Agent) map[uuid.UUID]*domain.Agent {
+	index := make(map[uuid.UUID]*domain.Agent, len(agents))
+	for _, agent := range agents {
+		index[agent.ID] = agent
+	}
+	return index
+}
+
+// usersByID indexes users by ID for hydrating joined data
+func usersByID(users []*domain.User) map[uuid.UUID]*domain.User {
+	index := make(map[uuid.UUID]*domain.User, len(users))
+	for _, user := range users {
+		index[user.ID] = user
+	}
+	return index
+}
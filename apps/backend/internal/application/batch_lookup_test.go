@@ -0,0 +1,70 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueBatchIDs(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+
+	ids, err := uniqueBatchIDs([]uuid.UUID{b, uuid.Nil, a, b, a})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b, a}, ids, "nil and duplicate IDs are dropped, order is kept")
+
+	tooMany := make([]uuid.UUID, MaxBatchLookupIDs+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.New()
+	}
+	_, err = uniqueBatchIDs(tooMany)
+	assert.ErrorIs(t, err, ErrBatchLookupTooLarge)
+
+	// Duplicates do not count towards the limit
+	_, err = uniqueBatchIDs(append(tooMany[:MaxBatchLookupIDs], tooMany[0]))
+	assert.NoError(t, err)
+}
+
+func TestBatchLookupsAreScopedToTheOrganization(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	other := testsupport.NewOrganization()
+
+	first := testsupport.NewAgent(org.ID)
+	second := testsupport.NewAgent(org.ID)
+	foreign := testsupport.NewAgent(other.ID)
+	for _, agent := range []*domain.Agent{first, second, foreign} {
+		require.NoError(t, repos.Agent.Create(agent))
+	}
+
+	fetched, err := repos.Agent.GetByIDs([]uuid.UUID{first.ID, foreign.ID, uuid.New()})
+	require.NoError(t, err)
+	assert.Len(t, fetched, 2, "unknown IDs are skipped")
+
+	agentService := NewAgentService(repos.Agent, nil, repos.TrustScore, nil, repos.Alert, nil, repos.Capability, nil, nil, repos.Organization, nil, nil, nil, nil)
+	agents, err := agentService.GetAgentsByIDs(context.Background(), org.ID, []uuid.UUID{first.ID, second.ID, foreign.ID, first.ID})
+	require.NoError(t, err)
+	ids := make([]uuid.UUID, 0, len(agents))
+	for _, agent := range agents {
+		ids = append(ids, agent.ID)
+	}
+	assert.ElementsMatch(t, []uuid.UUID{first.ID, second.ID}, ids, "agents of other organizations are never returned")
+
+	user := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(user))
+	users, err := repos.User.GetByIDs([]uuid.UUID{user.ID})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, user.Email, users[0].Email)
+
+	server := testsupport.NewMCPServer(org.ID)
+	require.NoError(t, repos.MCPServer.Create(server))
+	servers, err := repos.MCPServer.GetByIDs(nil)
+	require.NoError(t, err)
+	assert.Empty(t, servers)
+}
@@ -0,0 +1,241 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// capabilityMismatchStreak is how many of an agent's attestations of a server in a row must
+// disagree with the server's capabilities before a capability mismatch anomaly is recorded
+const capabilityMismatchStreak = 3
+
+const (
+	// capabilityDiscrepancyPenalty is the share of confidence a server loses for each capability
+	// its attestations consistently report as missing or extra
+	capabilityDiscrepancyPenalty = 0.1
+	// minCapabilityDiscrepancyScale bounds how much discrepancies alone can lower confidence
+	minCapabilityDiscrepancyScale = 0.5
+)
+
+// CapabilityClaimService compares the capabilities agent attestations claim to have found on an
+// MCP server with the capabilities the server was registered with and discovery found on it.
+// An agent whose attestations keep claiming capabilities the server does not expose, or keep
+// missing tools it does, points at a spoofed server or a broken SDK.
+type CapabilityClaimService struct {
+	attestationRepo   domain.MCPAttestationRepository
+	mcpCapabilityRepo domain.MCPServerCapabilityRepository
+	securityService   *SecurityService
+}
+
+// NewCapabilityClaimService creates a new capability claim service
+func NewCapabilityClaimService(
+	attestationRepo domain.MCPAttestationRepository,
+	mcpCapabilityRepo domain.MCPServerCapabilityRepository,
+	securityService *SecurityService,
+) *CapabilityClaimService {
+	return &CapabilityClaimService{
+		attestationRepo:   attestationRepo,
+		mcpCapabilityRepo: mcpCapabilityRepo,
+		securityService:   securityService,
+	}
+}
+
+// CapabilityClaimDiff is how an attestation's capabilities differ from the server's
+type CapabilityClaimDiff = domain.CapabilityDiscrepancy
+
+// serverCapabilities is what an MCP server is known to expose
+type serverCapabilities struct {
+	known map[string]bool // Registered and discovered capability names
+	tools []string        // Active tools found by discovery
+}
+
+// CheckAttestation compares the agent's latest attestation of the server with the server's
+// capabilities. When it and the attestations before it disagree capabilityMismatchStreak times
+// in a row, a capability mismatch anomaly is recorded; once per streak. Attestations without
+// capabilities are not compared, nor are servers nothing is known about.
+func (s *CapabilityClaimService) CheckAttestation(
+	ctx context.Context,
+	server *domain.MCPServer,
+	agent *domain.Agent,
+) (*CapabilityClaimDiff, error) {
+	capabilities, err := s.serverCapabilities(server)
+	if err != nil {
+		return nil, err
+	}
+	if len(capabilities.known) == 0 {
+		return &CapabilityClaimDiff{}, nil
+	}
+
+	attestations, err := s.attestationRepo.GetValidAttestationsByMCP(server.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load attestations: %w", err)
+	}
+	var history []*domain.MCPAttestation
+	for _, attestation := range attestations {
+		if attestation.AgentID != nil && *attestation.AgentID == agent.ID &&
+			len(attestation.AttestationData.CapabilitiesFound) > 0 {
+			history = append(history, attestation)
+		}
+	}
+	if len(history) == 0 {
+		return &CapabilityClaimDiff{}, nil
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].CreatedAt.After(history[j].CreatedAt)
+	})
+
+	latest := capabilities.diff(history[0].AttestationData.CapabilitiesFound)
+	if latest.Empty() {
+		return latest, nil
+	}
+	streak := 0
+	for _, attestation := range history {
+		if capabilities.diff(attestation.AttestationData.CapabilitiesFound).Empty() {
+			break
+		}
+		streak++
+	}
+	if streak == capabilityMismatchStreak {
+		s.recordMismatch(ctx, server, agent, latest, streak)
+	}
+	return latest, nil
+}
+
+// Discrepancy diffs the capabilities an attestation found against the server's registered and
+// discovered capabilities. It returns nil when nothing is known about the server's capabilities
+// or the attestation found none, as there is nothing to compare.
+func (s *CapabilityClaimService) Discrepancy(server *domain.MCPServer, found []string) (*domain.CapabilityDiscrepancy, error) {
+	if len(found) == 0 {
+		return nil, nil
+	}
+	capabilities, err := s.serverCapabilities(server)
+	if err != nil {
+		return nil, err
+	}
+	if len(capabilities.known) == 0 {
+		return nil, nil
+	}
+	return capabilities.diff(found), nil
+}
+
+// CapabilityDiscrepancyScale is the factor by which a server's confidence score is lowered for capabilities
+// its attestors consistently see missing or extra: those reported by at least
+// capabilityMismatchStreak and more than half of the attestations whose discrepancy was recorded.
+// Each such capability costs capabilityDiscrepancyPenalty, down to minCapabilityDiscrepancyScale.
+// One-off disagreements, e.g. while a deployment rolls out, do not count.
+func CapabilityDiscrepancyScale(attestations []*domain.MCPAttestation) float64 {
+	compared := 0
+	reports := make(map[string]int)
+	for _, attestation := range attestations {
+		if attestation.CapabilityDiscrepancy == nil {
+			continue
+		}
+		compared++
+		for _, name := range attestation.CapabilityDiscrepancy.Unexposed {
+			reports["unexposed:"+name]++
+		}
+		for _, name := range attestation.CapabilityDiscrepancy.Missing {
+			reports["missing:"+name]++
+		}
+	}
+
+	consistent := 0
+	for _, count := range reports {
+		if count >= capabilityMismatchStreak && count*2 > compared {
+			consistent++
+		}
+	}
+	scale := 1 - capabilityDiscrepancyPenalty*float64(consistent)
+	if scale < minCapabilityDiscrepancyScale {
+		scale = minCapabilityDiscrepancyScale
+	}
+	return scale
+}
+
+// serverCapabilities loads the capabilities the server was registered with and discovery found
+func (s *CapabilityClaimService) serverCapabilities(server *domain.MCPServer) (*serverCapabilities, error) {
+	discovered, err := s.mcpCapabilityRepo.GetByServerID(server.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mcp server capabilities: %w", err)
+	}
+	capabilities := &serverCapabilities{known: make(map[string]bool)}
+	for _, name := range server.Capabilities {
+		capabilities.known[name] = true
+	}
+	for _, capability := range discovered {
+		if !capability.IsActive {
+			continue
+		}
+		capabilities.known[capability.Name] = true
+		if capability.CapabilityType == domain.MCPCapabilityTypeTool {
+			capabilities.tools = append(capabilities.tools, capability.Name)
+		}
+	}
+	return capabilities, nil
+}
+
+// diff compares the capabilities an attestation found with the server's
+func (c *serverCapabilities) diff(found []string) *CapabilityClaimDiff {
+	diff := &CapabilityClaimDiff{}
+	claimed := make(map[string]bool, len(found))
+	for _, name := range found {
+		if !c.known[name] && !claimed[name] {
+			diff.Unexposed = append(diff.Unexposed, name)
+		}
+		claimed[name] = true
+	}
+	for _, tool := range c.tools {
+		if !claimed[tool] {
+			diff.Missing = append(diff.Missing, tool)
+		}
+	}
+	sort.Strings(diff.Unexposed)
+	sort.Strings(diff.Missing)
+	return diff
+}
+
+// recordMismatch records a capability mismatch anomaly against the server
+func (s *CapabilityClaimService) recordMismatch(
+	ctx context.Context,
+	server *domain.MCPServer,
+	agent *domain.Agent,
+	diff *CapabilityClaimDiff,
+	streak int,
+) {
+	if s.securityService == nil {
+		return
+	}
+
+	var details []string
+	if len(diff.Unexposed) > 0 {
+		details = append(details, "claimed capabilities the server does not expose: "+strings.Join(diff.Unexposed, ", "))
+	}
+	if len(diff.Missing) > 0 {
+		details = append(details, "missed tools the server exposes: "+strings.Join(diff.Missing, ", "))
+	}
+	severity := domain.AlertSeverityWarning
+	if len(diff.Unexposed) > 0 {
+		// Capabilities nobody else has seen on the server are the mark of a spoofed server
+		severity = domain.AlertSeverityHigh
+	}
+
+	anomaly := &domain.Anomaly{
+		OrganizationID: server.OrganizationID,
+		AnomalyType:    domain.AnomalyTypeCapabilityMismatch,
+		Severity:       severity,
+		Title:          fmt.Sprintf("Attestations of %s disagree with its capabilities", server.Name),
+		Description: fmt.Sprintf("The last %d attestations of MCP server %s by agent %s (%s) %s. The server may be spoofed or the agent's SDK broken.",
+			streak, server.Name, agent.Name, agent.ID, strings.Join(details, "; ")),
+		ResourceType: "mcp_server",
+		ResourceID:   server.ID,
+		Confidence:   90,
+	}
+	if err := s.securityService.CreateAnomaly(ctx, anomaly); err != nil {
+		log.Printf("⚠️  Failed to record capability mismatch for %s: %v", server.Name, err)
+	}
+}
@@ -0,0 +1,160 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttestationsThatKeepDisagreeingWithAServersCapabilitiesRecordAnAnomaly(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	server := testsupport.NewMCPServer(org.ID, func(s *domain.MCPServer) {
+		s.Capabilities = []string{"read_file"}
+	})
+	require.NoError(t, repos.MCPServer.Create(server))
+	for _, name := range []string{"read_file", "search"} {
+		require.NoError(t, repos.MCPServerCapability.Create(&domain.MCPServerCapability{
+			ID:             uuid.New(),
+			MCPServerID:    server.ID,
+			Name:           name,
+			CapabilityType: domain.MCPCapabilityTypeTool,
+			IsActive:       true,
+		}))
+	}
+	honest := testsupport.NewAgent(org.ID)
+	spoofed := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(honest))
+	require.NoError(t, repos.Agent.Create(spoofed))
+
+	security := NewSecurityService(repos.Security, repos.Agent, repos.Alert)
+	service := NewCapabilityClaimService(repos.MCPAttestation, repos.MCPServerCapability, security)
+	ctx := context.Background()
+	start := time.Now().Add(-time.Hour)
+	attest := func(agent *domain.Agent, at time.Duration, found ...string) *CapabilityClaimDiff {
+		require.NoError(t, repos.MCPAttestation.CreateAttestation(testsupport.NewMCPAttestation(server, agent, func(a *domain.MCPAttestation) {
+			a.AttestationData.CapabilitiesFound = found
+			a.CreatedAt = start.Add(at)
+		})))
+		diff, err := service.CheckAttestation(ctx, server, agent)
+		require.NoError(t, err)
+		return diff
+	}
+	mismatches := func() []*domain.Anomaly {
+		anomalies, _, err := security.SearchAnomalies(ctx, org.ID, domain.AnomalyQueryParams{
+			AnomalyTypes: []domain.AnomalyType{domain.AnomalyTypeCapabilityMismatch},
+		})
+		require.NoError(t, err)
+		return anomalies
+	}
+
+	// Attestations that agree with the server, or that found nothing, are left alone
+	assert.True(t, attest(honest, time.Minute, "search", "read_file").Empty())
+	assert.True(t, attest(honest, 2*time.Minute).Empty())
+
+	// One disagreeing attestation is not enough; a matching one in between breaks the streak
+	diff := attest(spoofed, time.Minute, "read_file", "search", "delete_everything")
+	assert.Equal(t, []string{"delete_everything"}, diff.Unexposed)
+	assert.Empty(t, diff.Missing)
+	attest(spoofed, 2*time.Minute, "read_file")
+	attest(spoofed, 3*time.Minute, "read_file", "search")
+	attest(spoofed, 4*time.Minute, "read_file")
+	assert.Empty(t, mismatches())
+
+	// The third disagreement in a row records one anomaly, later ones in the streak do not
+	attest(spoofed, 5*time.Minute, "read_file")
+	diff = attest(spoofed, 6*time.Minute, "read_file", "exfiltrate")
+	assert.Equal(t, []string{"exfiltrate"}, diff.Unexposed)
+	assert.Equal(t, []string{"search"}, diff.Missing)
+	attest(spoofed, 7*time.Minute, "read_file")
+
+	anomalies := mismatches()
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, "mcp_server", anomalies[0].ResourceType)
+	assert.Equal(t, server.ID, anomalies[0].ResourceID)
+	assert.Equal(t, domain.AlertSeverityHigh, anomalies[0].Severity)
+	assert.Contains(t, anomalies[0].Description, spoofed.Name)
+	assert.Contains(t, anomalies[0].Description, "does not expose: exfiltrate")
+	assert.Contains(t, anomalies[0].Description, "missed tools the server exposes: search")
+}
+
+func TestConsistentCapabilityDiscrepanciesLowerConfidence(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	server := testsupport.NewMCPServer(org.ID, func(s *domain.MCPServer) {
+		s.Capabilities = []string{"read_file"}
+	})
+	require.NoError(t, repos.MCPServer.Create(server))
+	require.NoError(t, repos.MCPServerCapability.Create(&domain.MCPServerCapability{
+		ID:             uuid.New(),
+		MCPServerID:    server.ID,
+		Name:           "search",
+		CapabilityType: domain.MCPCapabilityTypeTool,
+		IsActive:       true,
+	}))
+	service := NewCapabilityClaimService(repos.MCPAttestation, repos.MCPServerCapability, nil)
+
+	// Nothing to compare: the attestation found nothing, or nothing is known about the server
+	discrepancy, err := service.Discrepancy(server, nil)
+	require.NoError(t, err)
+	assert.Nil(t, discrepancy)
+	unknown := testsupport.NewMCPServer(org.ID, func(s *domain.MCPServer) { s.Capabilities = nil })
+	require.NoError(t, repos.MCPServer.Create(unknown))
+	discrepancy, err = service.Discrepancy(unknown, []string{"search"})
+	require.NoError(t, err)
+	assert.Nil(t, discrepancy)
+
+	discrepancy, err = service.Discrepancy(server, []string{"read_file", "exfiltrate"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"exfiltrate"}, discrepancy.Unexposed)
+	assert.Equal(t, []string{"search"}, discrepancy.Missing)
+
+	attestations := func(discrepancies ...*domain.CapabilityDiscrepancy) []*domain.MCPAttestation {
+		var result []*domain.MCPAttestation
+		for _, d := range discrepancies {
+			result = append(result, &domain.MCPAttestation{CapabilityDiscrepancy: d})
+		}
+		return result
+	}
+	agrees := &domain.CapabilityDiscrepancy{}
+	extra := &domain.CapabilityDiscrepancy{Unexposed: []string{"exfiltrate"}}
+	both := &domain.CapabilityDiscrepancy{Unexposed: []string{"exfiltrate"}, Missing: []string{"search"}}
+
+	// Attestations that were not compared, agree, or disagree only now and then cost nothing
+	assert.Equal(t, 1.0, CapabilityDiscrepancyScale(attestations(nil, nil, nil)))
+	assert.Equal(t, 1.0, CapabilityDiscrepancyScale(attestations(agrees, agrees, extra)))
+	assert.Equal(t, 1.0, CapabilityDiscrepancyScale(attestations(extra, extra, agrees, agrees, agrees)))
+
+	// Each capability most attestors consistently see missing or extra lowers confidence
+	assert.InDelta(t, 0.9, CapabilityDiscrepancyScale(attestations(extra, extra, both, agrees)), 1e-9)
+	assert.InDelta(t, 0.8, CapabilityDiscrepancyScale(attestations(both, both, both, nil)), 1e-9)
+
+	// ... down to a floor
+	var many []*domain.MCPAttestation
+	for i := 0; i < 3; i++ {
+		d := &domain.CapabilityDiscrepancy{}
+		for j := 0; j < 10; j++ {
+			d.Unexposed = append(d.Unexposed, fmt.Sprintf("tool_%d", j))
+		}
+		many = append(many, &domain.MCPAttestation{CapabilityDiscrepancy: d})
+	}
+	assert.Equal(t, 0.5, CapabilityDiscrepancyScale(many))
+
+	// The discrepancy is stored with the attestation
+	agent := testsupport.NewAgent(org.ID)
+	attestation := testsupport.NewMCPAttestation(server, agent, func(a *domain.MCPAttestation) {
+		a.CapabilityDiscrepancy = both
+	})
+	require.NoError(t, repos.MCPAttestation.CreateAttestation(attestation))
+	stored, err := repos.MCPAttestation.GetAttestationByID(attestation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, both, stored.CapabilityDiscrepancy)
+}
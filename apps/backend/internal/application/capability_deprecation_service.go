@@ -0,0 +1,370 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// mcpToolUseCapabilityPrefix is the capability type prefix of auto-detected MCP tool capabilities
+const mcpToolUseCapabilityPrefix = domain.CapabilityMCPToolUse + ":"
+
+// CapabilityDeprecationService deprecates agent capabilities that refer to tools their MCP
+// server no longer exposes, as seen by capability auto-discovery or agent attestations, and
+// tells the affected agents' owners. Deprecations are resolved when the tool comes back.
+type CapabilityDeprecationService struct {
+	deprecationRepo   domain.CapabilityDeprecationRepository
+	capabilityRepo    domain.CapabilityRepository
+	agentRepo         domain.AgentRepository
+	mcpRepo           domain.MCPServerRepository
+	mcpCapabilityRepo domain.MCPServerCapabilityRepository
+	userRepo          domain.UserRepository
+	alertService      *AlertService       // Optional: raises one alert per affected agent
+	emailService      domain.EmailService // Optional: emails the owners of affected agents
+}
+
+// NewCapabilityDeprecationService creates a new capability deprecation service
+func NewCapabilityDeprecationService(
+	deprecationRepo domain.CapabilityDeprecationRepository,
+	capabilityRepo domain.CapabilityRepository,
+	agentRepo domain.AgentRepository,
+	mcpRepo domain.MCPServerRepository,
+	mcpCapabilityRepo domain.MCPServerCapabilityRepository,
+	userRepo domain.UserRepository,
+	alertService *AlertService,
+	emailService domain.EmailService,
+) *CapabilityDeprecationService {
+	return &CapabilityDeprecationService{
+		deprecationRepo:   deprecationRepo,
+		capabilityRepo:    capabilityRepo,
+		agentRepo:         agentRepo,
+		mcpRepo:           mcpRepo,
+		mcpCapabilityRepo: mcpCapabilityRepo,
+		userRepo:          userRepo,
+		alertService:      alertService,
+		emailService:      emailService,
+	}
+}
+
+// CapabilityDeprecationResult is the outcome of reconciling an MCP server's tools
+type CapabilityDeprecationResult struct {
+	Deprecated     []*domain.CapabilityDeprecation `json:"deprecated"`
+	Resolved       int                             `json:"resolved"`
+	OwnersNotified int                             `json:"ownersNotified"`
+}
+
+// ReconcileAttestedTools reconciles the tools an agent attestation saw on the server against the
+// tools the server is known to expose. An attestation without tools is ignored.
+func (s *CapabilityDeprecationService) ReconcileAttestedTools(
+	ctx context.Context,
+	server *domain.MCPServer,
+	attested []string,
+) (*CapabilityDeprecationResult, error) {
+	if len(attested) == 0 {
+		return &CapabilityDeprecationResult{}, nil
+	}
+
+	known, err := s.mcpCapabilityRepo.GetByServerIDAndType(server.ID, domain.MCPCapabilityTypeTool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mcp server tools: %w", err)
+	}
+	seen := make(map[string]bool, len(attested))
+	for _, tool := range attested {
+		seen[tool] = true
+	}
+	var removed []string
+	for _, tool := range known {
+		if !seen[tool.Name] {
+			removed = append(removed, tool.Name)
+		}
+	}
+
+	return s.ReconcileServerTools(ctx, server, attested, removed, domain.CapabilityDeprecationSourceAttestation)
+}
+
+// ReconcileServerTools deprecates the agent capabilities that refer to a removed tool of the
+// server and resolves deprecations of tools the server exposes again.
+//
+// A capability refers to the server when its scope names the server (mcp_server_id), or when it
+// names none and the agent talks to the server and to no other server exposing the tool.
+func (s *CapabilityDeprecationService) ReconcileServerTools(
+	ctx context.Context,
+	server *domain.MCPServer,
+	exposed []string,
+	removed []string,
+	source domain.CapabilityDeprecationSource,
+) (*CapabilityDeprecationResult, error) {
+	result := &CapabilityDeprecationResult{Deprecated: make([]*domain.CapabilityDeprecation, 0)}
+	now := time.Now().UTC()
+
+	exposedTools := make(map[string]bool, len(exposed))
+	for _, tool := range exposed {
+		exposedTools[tool] = true
+	}
+
+	// 1. Resolve deprecations whose tool is back or whose capability is gone
+	open, err := s.deprecationRepo.GetActiveByServer(server.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load capability deprecations: %w", err)
+	}
+	stillOpen := make(map[uuid.UUID]bool, len(open))
+	for _, deprecation := range open {
+		capability, err := s.capabilityRepo.GetCapabilityByID(deprecation.CapabilityID)
+		if exposedTools[deprecation.ToolName] || err != nil || capability.RevokedAt != nil {
+			if err := s.deprecationRepo.Resolve(deprecation.ID, now); err != nil {
+				log.Printf("⚠️  Failed to resolve capability deprecation %s: %v", deprecation.ID, err)
+				continue
+			}
+			result.Resolved++
+			continue
+		}
+		stillOpen[deprecation.CapabilityID] = true
+	}
+
+	removedTools := make(map[string]bool, len(removed))
+	for _, tool := range removed {
+		if !exposedTools[tool] {
+			removedTools[tool] = true
+		}
+	}
+	if len(removedTools) == 0 {
+		return result, nil
+	}
+
+	// 2. Deprecate the capabilities that refer to a removed tool
+	agents, err := s.agentRepo.GetByOrganization(server.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agents: %w", err)
+	}
+	servers, err := s.mcpRepo.GetByOrganization(server.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mcp servers: %w", err)
+	}
+	otherTools := make(map[uuid.UUID]map[string]bool)
+
+	affected := make(map[uuid.UUID][]*domain.CapabilityDeprecation)
+	var affectedAgents []*domain.Agent
+	for _, agent := range agents {
+		capabilities, err := s.capabilityRepo.GetActiveCapabilitiesByAgentID(agent.ID)
+		if err != nil {
+			log.Printf("⚠️  Failed to load capabilities of agent %s: %v", agent.ID, err)
+			continue
+		}
+
+		connected := agentTalksToServer(agent, server)
+		for _, capability := range capabilities {
+			tool, ok := referencedMCPTool(capability)
+			if !ok || !removedTools[tool] || stillOpen[capability.ID] {
+				continue
+			}
+			if scoped, _ := capability.CapabilityScope["mcp_server_id"].(string); scoped != "" {
+				if scoped != server.ID.String() {
+					continue
+				}
+			} else if !connected || s.exposedByOtherServer(agent, server, tool, servers, otherTools) {
+				continue
+			}
+
+			deprecation := &domain.CapabilityDeprecation{
+				ID:             uuid.New(),
+				OrganizationID: server.OrganizationID,
+				AgentID:        agent.ID,
+				AgentName:      agent.DisplayName,
+				CapabilityID:   capability.ID,
+				CapabilityType: capability.CapabilityType,
+				MCPServerID:    server.ID,
+				MCPServerName:  server.Name,
+				ToolName:       tool,
+				Source:         source,
+				DeprecatedAt:   now,
+			}
+			if err := s.deprecationRepo.Create(deprecation); err != nil {
+				log.Printf("⚠️  Failed to deprecate capability %s of agent %s: %v", capability.CapabilityType, agent.ID, err)
+				continue
+			}
+			stillOpen[capability.ID] = true
+			result.Deprecated = append(result.Deprecated, deprecation)
+			if len(affected[agent.ID]) == 0 {
+				affectedAgents = append(affectedAgents, agent)
+			}
+			affected[agent.ID] = append(affected[agent.ID], deprecation)
+		}
+	}
+
+	if len(result.Deprecated) > 0 {
+		fmt.Printf("⚠️  Deprecated %d capabilities of %d agents: MCP server %s dropped tools (%s)\n",
+			len(result.Deprecated), len(affectedAgents), server.Name, source)
+	}
+
+	// 3. Tell admins and the agents' owners
+	for _, agent := range affectedAgents {
+		s.raiseDeprecationAlert(ctx, agent, server, affected[agent.ID])
+	}
+	result.OwnersNotified = s.notifyAgentOwners(server, affectedAgents, affected)
+
+	return result, nil
+}
+
+// exposedByOtherServer reports whether another server the agent talks to still exposes the tool.
+// Tools are loaded once per server and cached in otherTools.
+func (s *CapabilityDeprecationService) exposedByOtherServer(
+	agent *domain.Agent,
+	server *domain.MCPServer,
+	tool string,
+	servers []*domain.MCPServer,
+	otherTools map[uuid.UUID]map[string]bool,
+) bool {
+	for _, other := range servers {
+		if other.ID == server.ID || !agentTalksToServer(agent, other) {
+			continue
+		}
+		tools, ok := otherTools[other.ID]
+		if !ok {
+			tools = make(map[string]bool)
+			capabilities, err := s.mcpCapabilityRepo.GetByServerIDAndType(other.ID, domain.MCPCapabilityTypeTool)
+			if err != nil {
+				log.Printf("⚠️  Failed to load tools of MCP server %s: %v", other.ID, err)
+			}
+			for _, capability := range capabilities {
+				tools[capability.Name] = true
+			}
+			otherTools[other.ID] = tools
+		}
+		if tools[tool] {
+			return true
+		}
+	}
+	return false
+}
+
+// raiseDeprecationAlert raises one alert per agent listing its deprecated capabilities
+func (s *CapabilityDeprecationService) raiseDeprecationAlert(
+	ctx context.Context,
+	agent *domain.Agent,
+	server *domain.MCPServer,
+	deprecations []*domain.CapabilityDeprecation,
+) {
+	if s.alertService == nil {
+		return
+	}
+
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: agent.OrganizationID,
+		AlertType:      domain.AlertCapabilityDeprecated,
+		Severity:       domain.AlertSeverityWarning,
+		Title:          fmt.Sprintf("Deprecated capabilities: %s", agent.Name),
+		Description: fmt.Sprintf("MCP server %s no longer exposes %s. Agent %s still has capabilities for them; "+
+			"update or revoke them.", server.Name, strings.Join(deprecatedToolNames(deprecations), ", "), agent.Name),
+		ResourceType: "agent",
+		ResourceID:   agent.ID,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := s.alertService.CreateAlert(ctx, alert); err != nil {
+		log.Printf("⚠️  Failed to create capability deprecation alert for agent %s: %v", agent.ID, err)
+	}
+}
+
+// notifyAgentOwners emails the users who registered the affected agents and returns how many were emailed
+func (s *CapabilityDeprecationService) notifyAgentOwners(
+	server *domain.MCPServer,
+	agents []*domain.Agent,
+	affected map[uuid.UUID][]*domain.CapabilityDeprecation,
+) int {
+	if s.emailService == nil || s.userRepo == nil || len(agents) == 0 {
+		return 0
+	}
+
+	owned := make(map[uuid.UUID][]*domain.Agent)
+	ownerIDs := make([]uuid.UUID, 0)
+	for _, agent := range agents {
+		if agent.CreatedBy == uuid.Nil {
+			continue
+		}
+		if len(owned[agent.CreatedBy]) == 0 {
+			ownerIDs = append(ownerIDs, agent.CreatedBy)
+		}
+		owned[agent.CreatedBy] = append(owned[agent.CreatedBy], agent)
+	}
+	if len(ownerIDs) == 0 {
+		return 0
+	}
+
+	owners, err := s.userRepo.GetByIDs(ownerIDs)
+	if err != nil {
+		log.Printf("⚠️  Failed to load owners of agents with deprecated capabilities: %v", err)
+		return 0
+	}
+
+	notified := 0
+	for _, owner := range owners {
+		if owner.Email == "" {
+			continue
+		}
+
+		var items strings.Builder
+		for _, agent := range owned[owner.ID] {
+			fmt.Fprintf(&items, "<li><strong>%s</strong>: %s</li>",
+				html.EscapeString(agent.Name),
+				html.EscapeString(strings.Join(deprecatedToolNames(affected[agent.ID]), ", ")))
+		}
+		subject := fmt.Sprintf("[AIM] MCP server %s no longer exposes tools your agents use", server.Name)
+		body := fmt.Sprintf(
+			"<h2>Deprecated agent capabilities</h2><p>MCP server %s stopped exposing tools your agents have capabilities for:</p><ul>%s</ul><p>Update or revoke these capabilities.</p>",
+			html.EscapeString(server.Name),
+			items.String(),
+		)
+		if err := organizationEmail(s.emailService, owner.OrganizationID).SendEmail(owner.Email, subject, body, true); err != nil {
+			log.Printf("⚠️  Failed to email %s about deprecated capabilities: %v", owner.Email, err)
+			continue
+		}
+		notified++
+	}
+	return notified
+}
+
+// referencedMCPTool returns the MCP tool an agent capability refers to: "mcp_tool:<name>" and
+// "mcp:tool_use:<name>" name it, and auto-detected capabilities keep the tool's metadata as scope
+func referencedMCPTool(capability *domain.AgentCapability) (string, bool) {
+	tool, ok := strings.CutPrefix(capability.CapabilityType, mcpToolActionPrefix)
+	if !ok {
+		tool, ok = strings.CutPrefix(capability.CapabilityType, mcpToolUseCapabilityPrefix)
+	}
+	if !ok && capability.GrantedBy == nil {
+		tool, ok = capability.CapabilityScope["name"].(string)
+	}
+	if !ok || tool == "" || tool == "*" {
+		return "", false
+	}
+	return tool, true
+}
+
+// agentTalksToServer reports whether the agent lists the server (by name or ID) in talks_to
+func agentTalksToServer(agent *domain.Agent, server *domain.MCPServer) bool {
+	for _, entry := range agent.TalksTo {
+		if entry == server.Name || entry == server.ID.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func deprecatedToolNames(deprecations []*domain.CapabilityDeprecation) []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0, len(deprecations))
+	for _, deprecation := range deprecations {
+		if !seen[deprecation.ToolName] {
+			seen[deprecation.ToolName] = true
+			names = append(names, deprecation.ToolName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,99 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDroppedMCPToolsDeprecateAgentCapabilities(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	owner := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(owner))
+
+	server := testsupport.NewMCPServer(org.ID)
+	other := testsupport.NewMCPServer(org.ID)
+	require.NoError(t, repos.MCPServer.Create(server))
+	require.NoError(t, repos.MCPServer.Create(other))
+	for _, tool := range []string{"search", "fetch"} {
+		require.NoError(t, repos.MCPServerCapability.Create(&domain.MCPServerCapability{
+			MCPServerID: server.ID, Name: tool, CapabilityType: domain.MCPCapabilityTypeTool, IsActive: true,
+		}))
+	}
+
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) {
+		a.TalksTo = []string{server.Name}
+		a.CreatedBy = owner.ID
+	})
+	require.NoError(t, repos.Agent.Create(agent))
+	search := testsupport.NewAgentCapability(agent, "mcp_tool:search")
+	fetch := testsupport.NewAgentCapability(agent, "mcp_tool:fetch")
+	scopedElsewhere := testsupport.NewAgentCapability(agent, "mcp:tool_use:search", func(c *domain.AgentCapability) {
+		c.CapabilityScope = map[string]interface{}{"mcp_server_id": other.ID.String()}
+	})
+	for _, capability := range []*domain.AgentCapability{search, fetch, scopedElsewhere} {
+		require.NoError(t, repos.Capability.CreateCapability(capability))
+	}
+
+	alerts := NewAlertService(repos.Alert, repos.Agent, nil, nil, nil, nil)
+	service := NewCapabilityDeprecationService(
+		repos.CapabilityDeprecation, repos.Capability, repos.Agent, repos.MCPServer, repos.MCPServerCapability, repos.User, alerts, nil,
+	)
+	ctx := context.Background()
+
+	result, err := service.ReconcileServerTools(ctx, server, []string{"fetch"}, []string{"search"}, domain.CapabilityDeprecationSourceDiscovery)
+	require.NoError(t, err)
+	require.Len(t, result.Deprecated, 1, "the capability scoped to another server is untouched")
+	assert.Equal(t, search.ID, result.Deprecated[0].CapabilityID)
+	assert.Equal(t, "search", result.Deprecated[0].ToolName)
+
+	again, err := service.ReconcileServerTools(ctx, server, []string{"fetch"}, []string{"search"}, domain.CapabilityDeprecationSourceDiscovery)
+	require.NoError(t, err)
+	assert.Empty(t, again.Deprecated, "an open deprecation is not recorded twice")
+
+	raised, err := repos.Alert.GetByResourceID(agent.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, raised, 1)
+	assert.Equal(t, domain.AlertCapabilityDeprecated, raised[0].AlertType)
+
+	capabilities, err := NewCapabilityService(repos.Capability, repos.Agent, repos.AuditLog, nil, repos.TrustScore, nil, repos.CapabilityDeprecation, repos.Organization).
+		GetAgentCapabilities(ctx, agent.ID, true)
+	require.NoError(t, err)
+	for _, capability := range capabilities {
+		if capability.ID == search.ID {
+			require.NotNil(t, capability.Deprecation)
+			assert.Equal(t, server.Name, capability.Deprecation.MCPServerName)
+		} else {
+			assert.Nil(t, capability.Deprecation, capability.CapabilityType)
+		}
+	}
+
+	drift := NewDriftAnalyticsService(repos.DriftAnalytics, repos.Agent, repos.Tag)
+	report, err := drift.GetDriftTrends(ctx, &DriftTrendRequest{OrganizationID: org.ID, Scope: domain.DriftTrendScopeAgent, AgentID: &agent.ID})
+	require.NoError(t, err)
+	require.Len(t, report.DeprecatedCapabilities, 1)
+	assert.Equal(t, agent.DisplayName, report.DeprecatedCapabilities[0].AgentName)
+
+	// An attestation that sees the tool again resolves the deprecation; one without tools is ignored
+	result, err = service.ReconcileAttestedTools(ctx, server, nil)
+	require.NoError(t, err)
+	assert.Zero(t, result.Resolved)
+	result, err = service.ReconcileAttestedTools(ctx, server, []string{"search", "fetch"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Resolved)
+	open, err := repos.CapabilityDeprecation.GetActiveByAgent(agent.ID)
+	require.NoError(t, err)
+	assert.Empty(t, open)
+
+	// Tools an attestation no longer sees are deprecated too
+	result, err = service.ReconcileAttestedTools(ctx, server, []string{"search"})
+	require.NoError(t, err)
+	require.Len(t, result.Deprecated, 1)
+	assert.Equal(t, fetch.ID, result.Deprecated[0].CapabilityID)
+	assert.Equal(t, domain.CapabilityDeprecationSourceAttestation, result.Deprecated[0].Source)
+}
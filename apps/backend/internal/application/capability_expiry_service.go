@@ -0,0 +1,175 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// CapabilityExpiryService removes time-bound capabilities once they expire and tells the
+// affected agents' owners, who can request a renewal through the capability request flow.
+type CapabilityExpiryService struct {
+	capabilityRepo domain.CapabilityRepository
+	agentRepo      domain.AgentRepository
+	userRepo       domain.UserRepository
+	alertService   *AlertService       // Optional: raises one alert per affected agent
+	emailService   domain.EmailService // Optional: emails the owners of affected agents
+}
+
+// NewCapabilityExpiryService creates a new capability expiry service
+func NewCapabilityExpiryService(
+	capabilityRepo domain.CapabilityRepository,
+	agentRepo domain.AgentRepository,
+	userRepo domain.UserRepository,
+	alertService *AlertService,
+	emailService domain.EmailService,
+) *CapabilityExpiryService {
+	return &CapabilityExpiryService{
+		capabilityRepo: capabilityRepo,
+		agentRepo:      agentRepo,
+		userRepo:       userRepo,
+		alertService:   alertService,
+		emailService:   emailService,
+	}
+}
+
+// CapabilityExpiryResult is the outcome of an expiry run
+type CapabilityExpiryResult struct {
+	Expired        int `json:"expired"`
+	Agents         int `json:"agents"`
+	OwnersNotified int `json:"ownersNotified"`
+}
+
+// ExpireCapabilities revokes every capability whose expiry has passed, raises an alert per
+// affected agent and emails the agents' owners
+func (s *CapabilityExpiryService) ExpireCapabilities(ctx context.Context) (*CapabilityExpiryResult, error) {
+	now := time.Now().UTC()
+	expired, err := s.capabilityRepo.GetExpiredCapabilities(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load expired capabilities: %w", err)
+	}
+
+	result := &CapabilityExpiryResult{}
+	affected := make(map[uuid.UUID][]*domain.AgentCapability)
+	var affectedAgents []*domain.Agent
+	for _, capability := range expired {
+		if err := s.capabilityRepo.RevokeCapability(capability.ID, now); err != nil {
+			log.Printf("⚠️  Failed to revoke expired capability %s: %v", capability.ID, err)
+			continue
+		}
+		result.Expired++
+
+		if _, seen := affected[capability.AgentID]; !seen {
+			agent, err := s.agentRepo.GetByID(capability.AgentID)
+			if err != nil {
+				log.Printf("⚠️  Failed to load agent %s of expired capability: %v", capability.AgentID, err)
+				continue
+			}
+			affectedAgents = append(affectedAgents, agent)
+		}
+		affected[capability.AgentID] = append(affected[capability.AgentID], capability)
+	}
+	result.Agents = len(affectedAgents)
+
+	if result.Expired > 0 {
+		fmt.Printf("⚠️  Expired %d capabilities of %d agents\n", result.Expired, result.Agents)
+	}
+
+	for _, agent := range affectedAgents {
+		s.raiseExpiryAlert(ctx, agent, affected[agent.ID])
+	}
+	result.OwnersNotified = s.notifyAgentOwners(affectedAgents, affected)
+
+	return result, nil
+}
+
+// expiredCapabilityTypes lists the capability types of the expired capabilities
+func expiredCapabilityTypes(capabilities []*domain.AgentCapability) []string {
+	types := make([]string, 0, len(capabilities))
+	for _, capability := range capabilities {
+		types = append(types, capability.CapabilityType)
+	}
+	return types
+}
+
+// raiseExpiryAlert raises one alert per agent listing its expired capabilities
+func (s *CapabilityExpiryService) raiseExpiryAlert(ctx context.Context, agent *domain.Agent, capabilities []*domain.AgentCapability) {
+	if s.alertService == nil {
+		return
+	}
+
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: agent.OrganizationID,
+		AlertType:      domain.AlertCapabilityExpired,
+		Severity:       domain.AlertSeverityWarning,
+		Title:          fmt.Sprintf("Capabilities expired: %s", agent.Name),
+		Description: fmt.Sprintf("The time-bound capabilities %s of agent %s expired and were removed. "+
+			"Request a renewal if the agent still needs them.", strings.Join(expiredCapabilityTypes(capabilities), ", "), agent.Name),
+		ResourceType: "agent",
+		ResourceID:   agent.ID,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := s.alertService.CreateAlert(ctx, alert); err != nil {
+		log.Printf("⚠️  Failed to create capability expiry alert for agent %s: %v", agent.ID, err)
+	}
+}
+
+// notifyAgentOwners emails the users who registered the affected agents and returns how many were emailed
+func (s *CapabilityExpiryService) notifyAgentOwners(agents []*domain.Agent, affected map[uuid.UUID][]*domain.AgentCapability) int {
+	if s.emailService == nil || s.userRepo == nil || len(agents) == 0 {
+		return 0
+	}
+
+	owned := make(map[uuid.UUID][]*domain.Agent)
+	ownerIDs := make([]uuid.UUID, 0)
+	for _, agent := range agents {
+		if agent.CreatedBy == uuid.Nil {
+			continue
+		}
+		if len(owned[agent.CreatedBy]) == 0 {
+			ownerIDs = append(ownerIDs, agent.CreatedBy)
+		}
+		owned[agent.CreatedBy] = append(owned[agent.CreatedBy], agent)
+	}
+	if len(ownerIDs) == 0 {
+		return 0
+	}
+
+	owners, err := s.userRepo.GetByIDs(ownerIDs)
+	if err != nil {
+		log.Printf("⚠️  Failed to load owners of agents with expired capabilities: %v", err)
+		return 0
+	}
+
+	notified := 0
+	for _, owner := range owners {
+		if owner.Email == "" {
+			continue
+		}
+
+		var items strings.Builder
+		for _, agent := range owned[owner.ID] {
+			fmt.Fprintf(&items, "<li><strong>%s</strong>: %s</li>",
+				html.EscapeString(agent.Name),
+				html.EscapeString(strings.Join(expiredCapabilityTypes(affected[agent.ID]), ", ")))
+		}
+		subject := "[AIM] Capabilities of your agents expired"
+		body := fmt.Sprintf(
+			"<h2>Expired agent capabilities</h2><p>These time-bound capabilities of your agents expired and were removed:</p><ul>%s</ul><p>Request a renewal for any capability an agent still needs.</p>",
+			items.String(),
+		)
+		if err := organizationEmail(s.emailService, owner.OrganizationID).SendEmail(owner.Email, subject, body, true); err != nil {
+			log.Printf("⚠️  Failed to email %s about expired capabilities: %v", owner.Email, err)
+			continue
+		}
+		notified++
+	}
+	return notified
+}
@@ -0,0 +1,137 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeBoundCapabilitiesExpireAndRenewWithTheirOriginalJustification(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	owner := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(owner))
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.CreatedBy = owner.ID })
+	require.NoError(t, repos.Agent.Create(agent))
+	reviewer := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(reviewer))
+	ctx := context.Background()
+
+	requests := NewCapabilityRequestService(repos.CapabilityRequest, repos.Capability, repos.Agent, nil)
+	past := time.Now().Add(-time.Minute)
+	_, err := requests.CreateRequest(ctx, &domain.CreateCapabilityRequestInput{
+		AgentID: agent.ID, CapabilityType: domain.CapabilityDBQuery, Reason: "Nightly reporting queries", RequestedBy: owner.ID, ExpiresAt: &past,
+	})
+	assert.ErrorIs(t, err, ErrInvalidCapabilityExpiry)
+
+	inAnHour := time.Now().Add(time.Hour)
+	request, err := requests.CreateRequest(ctx, &domain.CreateCapabilityRequestInput{
+		AgentID: agent.ID, CapabilityType: domain.CapabilityDBQuery, Reason: "Nightly reporting queries", RequestedBy: owner.ID, ExpiresAt: &inAnHour,
+	})
+	require.NoError(t, err)
+	_, err = requests.ApproveRequest(ctx, request.ID, reviewer.ID)
+	require.NoError(t, err)
+	granted, err := repos.Capability.GetActiveCapabilitiesByAgentID(agent.ID)
+	require.NoError(t, err)
+	require.Len(t, granted, 1)
+	capability := granted[0]
+	require.NotNil(t, capability.ExpiresAt)
+	assert.WithinDuration(t, inAnHour, *capability.ExpiresAt, time.Second)
+
+	// A renewal asks for a later expiry and carries the original justification
+	_, err = requests.RenewCapability(ctx, &domain.RenewCapabilityInput{
+		AgentID: agent.ID, CapabilityID: capability.ID, ExpiresAt: inAnHour.Add(-time.Minute), RequestedBy: owner.ID,
+	})
+	assert.ErrorIs(t, err, ErrInvalidCapabilityExpiry, "a renewal extends the expiry")
+	_, err = requests.RenewCapability(ctx, &domain.RenewCapabilityInput{
+		AgentID: uuid.New(), CapabilityID: capability.ID, ExpiresAt: inAnHour.Add(time.Hour), RequestedBy: owner.ID,
+	})
+	assert.Error(t, err, "the capability belongs to another agent")
+
+	inADay := time.Now().Add(24 * time.Hour)
+	renewal, err := requests.RenewCapability(ctx, &domain.RenewCapabilityInput{
+		AgentID: agent.ID, CapabilityID: capability.ID, ExpiresAt: inADay, Reason: "ignored", RequestedBy: owner.ID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Nightly reporting queries", renewal.Reason)
+	require.NotNil(t, renewal.RenewsCapabilityID)
+	assert.Equal(t, capability.ID, *renewal.RenewsCapabilityID)
+	_, err = requests.RenewCapability(ctx, &domain.RenewCapabilityInput{
+		AgentID: agent.ID, CapabilityID: capability.ID, ExpiresAt: inADay.Add(time.Hour), RequestedBy: owner.ID,
+	})
+	assert.Error(t, err, "one renewal is pending at a time")
+
+	_, err = requests.ApproveRequest(ctx, renewal.ID, reviewer.ID)
+	require.NoError(t, err)
+	renewed, err := repos.Capability.GetCapabilityByID(capability.ID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, inADay, *renewed.ExpiresAt, time.Second, "the approved renewal extends the same capability")
+	all, err := repos.Capability.GetCapabilitiesByAgentID(agent.ID)
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+
+	// Once the expiry passes the capability is no longer active and the job removes it
+	require.NoError(t, repos.Capability.SetCapabilityExpiry(capability.ID, &past))
+	active, err := repos.Capability.GetActiveCapabilitiesByAgentID(agent.ID)
+	require.NoError(t, err)
+	assert.Empty(t, active, "an expired capability is not active before the job runs")
+
+	emails := &recordingEmailService{}
+	alerts := NewAlertService(repos.Alert, repos.Agent, nil, nil, nil, nil)
+	expiry := NewCapabilityExpiryService(repos.Capability, repos.Agent, repos.User, alerts, emails)
+	result, err := expiry.ExpireCapabilities(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Expired)
+	assert.Equal(t, 1, result.OwnersNotified)
+	require.Len(t, emails.emails, 1)
+	assert.Equal(t, owner.Email, emails.emails[0].to)
+	assert.Contains(t, emails.emails[0].body, domain.CapabilityDBQuery)
+	raised, err := repos.Alert.GetByResourceID(agent.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, raised, 1)
+	assert.Equal(t, domain.AlertCapabilityExpired, raised[0].AlertType)
+	expired, err := repos.Capability.GetCapabilityByID(capability.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, expired.RevokedAt)
+
+	again, err := expiry.ExpireCapabilities(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, again.Expired, "revoked capabilities are not expired twice")
+
+	// An expired capability is granted again when its renewal is approved
+	renewal, err = requests.RenewCapability(ctx, &domain.RenewCapabilityInput{
+		AgentID: agent.ID, CapabilityID: capability.ID, ExpiresAt: inADay, RequestedBy: owner.ID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Nightly reporting queries", renewal.Reason)
+	_, err = requests.ApproveRequest(ctx, renewal.ID, reviewer.ID)
+	require.NoError(t, err)
+	active, err = repos.Capability.GetActiveCapabilitiesByAgentID(agent.ID)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.NotEqual(t, capability.ID, active[0].ID)
+	_, err = requests.RenewCapability(ctx, &domain.RenewCapabilityInput{
+		AgentID: agent.ID, CapabilityID: capability.ID, ExpiresAt: inADay.Add(time.Hour), RequestedBy: owner.ID,
+	})
+	assert.ErrorIs(t, err, ErrCapabilityNotRenewable, "the capability granted again is renewed instead")
+
+	// Capabilities that never expire, or were revoked by a reviewer, are not renewable
+	permanent := testsupport.NewAgentCapability(agent, domain.CapabilityFileRead)
+	require.NoError(t, repos.Capability.CreateCapability(permanent))
+	_, err = requests.RenewCapability(ctx, &domain.RenewCapabilityInput{
+		AgentID: agent.ID, CapabilityID: permanent.ID, ExpiresAt: inADay, RequestedBy: owner.ID,
+	})
+	assert.ErrorIs(t, err, ErrCapabilityNotRenewable)
+	require.NoError(t, repos.Capability.RevokeCapability(active[0].ID, time.Now()))
+	_, err = requests.RenewCapability(ctx, &domain.RenewCapabilityInput{
+		AgentID: agent.ID, CapabilityID: active[0].ID, ExpiresAt: inADay.Add(time.Hour), RequestedBy: owner.ID,
+	})
+	assert.ErrorIs(t, err, ErrCapabilityNotRenewable)
+}
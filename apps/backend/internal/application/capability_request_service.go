@@ -2,31 +2,53 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"os"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
+var (
+	// ErrInvalidCapabilityExpiry is returned for capability expiries that are not in the future
+	ErrInvalidCapabilityExpiry = errors.New("invalid capability expiry")
+	// ErrCapabilityNotRenewable is returned when renewing a capability that never expires or was revoked
+	ErrCapabilityNotRenewable = errors.New("capability cannot be renewed")
+	// ErrRenewalReasonRequired is returned when renewing a capability that was not granted through a
+	// capability request, so there is no original justification to carry over
+	ErrRenewalReasonRequired = errors.New("renewal reason is required")
+)
+
 type CapabilityRequestService struct {
 	requestRepo    domain.CapabilityRequestRepository
 	capabilityRepo domain.CapabilityRepository
 	agentRepo      domain.AgentRepository
+	approvals      *ApprovalChainService // Optional: approval chains for capability grants
+	emailService   domain.EmailService   // Optional: emails requesters about decisions
 }
 
 func NewCapabilityRequestService(
 	requestRepo domain.CapabilityRequestRepository,
 	capabilityRepo domain.CapabilityRepository,
 	agentRepo domain.AgentRepository,
+	approvals *ApprovalChainService,
 ) *CapabilityRequestService {
 	return &CapabilityRequestService{
 		requestRepo:    requestRepo,
 		capabilityRepo: capabilityRepo,
 		agentRepo:      agentRepo,
+		approvals:      approvals,
 	}
 }
 
+// UseEmail emails the requester of a capability request when it is approved or rejected
+func (s *CapabilityRequestService) UseEmail(emailService domain.EmailService) {
+	s.emailService = emailService
+}
+
 // CreateRequest creates a new capability request
 func (s *CapabilityRequestService) CreateRequest(ctx context.Context, input *domain.CreateCapabilityRequestInput) (*domain.CapabilityRequest, error) {
 	// Verify agent exists
@@ -35,14 +57,18 @@ func (s *CapabilityRequestService) CreateRequest(ctx context.Context, input *dom
 		return nil, fmt.Errorf("agent not found: %w", err)
 	}
 
-	// Check if capability already granted
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expiresAt must be in the future", ErrInvalidCapabilityExpiry)
+	}
+
+	// Check if capability already granted (expired capabilities can be requested again)
 	capabilities, err := s.capabilityRepo.GetCapabilitiesByAgentID(input.AgentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check existing capabilities: %w", err)
 	}
 
 	for _, cap := range capabilities {
-		if cap.CapabilityType == input.CapabilityType {
+		if cap.CapabilityType == input.CapabilityType && !cap.Expired(time.Now()) && !expiredOut(cap) {
 			return nil, fmt.Errorf("capability '%s' already granted to agent '%s'", input.CapabilityType, agent.Name)
 		}
 	}
@@ -67,6 +93,7 @@ func (s *CapabilityRequestService) CreateRequest(ctx context.Context, input *dom
 		CapabilityType: input.CapabilityType,
 		Reason:         input.Reason,
 		RequestedBy:    input.RequestedBy,
+		ExpiresAt:      input.ExpiresAt,
 	}
 
 	if err := s.requestRepo.Create(request); err != nil {
@@ -79,6 +106,100 @@ func (s *CapabilityRequestService) CreateRequest(ctx context.Context, input *dom
 	return request, nil
 }
 
+// expiredOut reports whether a capability was revoked because it expired, rather than revoked
+// by a reviewer before its expiry
+func expiredOut(capability *domain.AgentCapability) bool {
+	return capability.RevokedAt != nil && capability.ExpiresAt != nil && !capability.RevokedAt.Before(*capability.ExpiresAt)
+}
+
+// RenewCapability requests a later expiry for a time-bound capability. The renewal goes through
+// the same review as the original request and carries its justification; once approved the
+// capability's expiry is extended, or the capability is granted again if it already expired.
+func (s *CapabilityRequestService) RenewCapability(ctx context.Context, input *domain.RenewCapabilityInput) (*domain.CapabilityRequest, error) {
+	capability, err := s.capabilityRepo.GetCapabilityByID(input.CapabilityID)
+	if err != nil {
+		return nil, fmt.Errorf("capability not found: %w", err)
+	}
+	if capability.AgentID != input.AgentID {
+		return nil, fmt.Errorf("capability not found")
+	}
+	if capability.ExpiresAt == nil {
+		return nil, fmt.Errorf("%w: capability '%s' does not expire", ErrCapabilityNotRenewable, capability.CapabilityType)
+	}
+	if capability.RevokedAt != nil && !expiredOut(capability) {
+		return nil, fmt.Errorf("%w: capability '%s' was revoked", ErrCapabilityNotRenewable, capability.CapabilityType)
+	}
+	if !input.ExpiresAt.After(time.Now()) || !input.ExpiresAt.After(*capability.ExpiresAt) {
+		return nil, fmt.Errorf("%w: expiresAt must be in the future and later than the current expiry %s",
+			ErrInvalidCapabilityExpiry, capability.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+
+	agent, err := s.agentRepo.GetByID(capability.AgentID)
+	if err != nil {
+		return nil, fmt.Errorf("agent not found: %w", err)
+	}
+
+	// An expired capability that was granted again is renewed through its successor
+	if capability.RevokedAt != nil {
+		active, err := s.capabilityRepo.GetActiveCapabilitiesByAgentID(capability.AgentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check existing capabilities: %w", err)
+		}
+		for _, other := range active {
+			if other.CapabilityType == capability.CapabilityType {
+				return nil, fmt.Errorf("%w: capability '%s' was granted again", ErrCapabilityNotRenewable, capability.CapabilityType)
+			}
+		}
+	}
+
+	existingRequests, err := s.requestRepo.List(domain.CapabilityRequestFilter{
+		AgentID: &capability.AgentID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing requests: %w", err)
+	}
+
+	// Requests are listed newest first, so the first approved one holds the latest justification
+	reason := ""
+	for _, req := range existingRequests {
+		if req.CapabilityType != capability.CapabilityType {
+			continue
+		}
+		if req.Status == domain.CapabilityRequestStatusPending {
+			return nil, fmt.Errorf("pending request already exists for capability '%s'", capability.CapabilityType)
+		}
+		if req.Status == domain.CapabilityRequestStatusApproved && reason == "" {
+			reason = req.Reason
+		}
+	}
+	if reason == "" {
+		if len(input.Reason) < 10 {
+			return nil, fmt.Errorf("%w: capability '%s' was not granted through a capability request; give a reason of at least 10 characters",
+				ErrRenewalReasonRequired, capability.CapabilityType)
+		}
+		reason = input.Reason
+	}
+
+	expiresAt := input.ExpiresAt
+	request := &domain.CapabilityRequest{
+		AgentID:            capability.AgentID,
+		CapabilityType:     capability.CapabilityType,
+		Reason:             reason,
+		RequestedBy:        input.RequestedBy,
+		ExpiresAt:          &expiresAt,
+		RenewsCapabilityID: &capability.ID,
+	}
+
+	if err := s.requestRepo.Create(request); err != nil {
+		return nil, fmt.Errorf("failed to create capability renewal request: %w", err)
+	}
+
+	fmt.Printf("✅ Capability renewal requested: agent=%s, capability=%s, expires=%s\n",
+		agent.Name, capability.CapabilityType, expiresAt.UTC().Format(time.RFC3339))
+
+	return request, nil
+}
+
 // ListRequests lists capability requests with optional filtering
 func (s *CapabilityRequestService) ListRequests(ctx context.Context, filter domain.CapabilityRequestFilter) ([]*domain.CapabilityRequestWithDetails, error) {
 	requests, err := s.requestRepo.List(filter)
@@ -99,42 +220,100 @@ func (s *CapabilityRequestService) GetRequest(ctx context.Context, id uuid.UUID)
 	return request, nil
 }
 
-// ApproveRequest approves a capability request and grants the capability
-func (s *CapabilityRequestService) ApproveRequest(ctx context.Context, id uuid.UUID, reviewerID uuid.UUID) error {
+// ApproveRequest approves a capability request and grants the capability. When an approval
+// chain guards the grant, the call records the reviewer's approval and the capability is only
+// granted once the returned approval request is approved.
+func (s *CapabilityRequestService) ApproveRequest(ctx context.Context, id uuid.UUID, reviewerID uuid.UUID) (*domain.ApprovalRequest, error) {
 	// Get the request details
 	request, err := s.requestRepo.GetByID(id)
 	if err != nil {
-		return fmt.Errorf("capability request not found: %w", err)
+		return nil, fmt.Errorf("capability request not found: %w", err)
 	}
 
 	// Verify status is pending
 	if request.Status != domain.CapabilityRequestStatusPending {
-		return fmt.Errorf("capability request is not pending (current status: %s)", request.Status)
+		return nil, fmt.Errorf("capability request is not pending (current status: %s)", request.Status)
+	}
+
+	// A grant that would already have expired is pointless; the request has to be made again
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: the requested expiry passed before the request was approved", ErrInvalidCapabilityExpiry)
+	}
+
+	summary := fmt.Sprintf("Grant capability '%s' to agent '%s'", request.CapabilityType, request.AgentName)
+	if request.RenewsCapabilityID != nil {
+		summary = fmt.Sprintf("Renew capability '%s' of agent '%s' until %s", request.CapabilityType, request.AgentName,
+			request.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+
+	// Collect the approvals required for the capability's risk level
+	var approval *domain.ApprovalRequest
+	if s.approvals != nil {
+		agent, err := s.agentRepo.GetByID(request.AgentID)
+		if err != nil {
+			return nil, fmt.Errorf("agent not found: %w", err)
+		}
+
+		approval, err = s.approvals.Approve(ctx, ApprovalSubject{
+			OrganizationID: agent.OrganizationID,
+			Action:         domain.ApprovalActionCapabilityGrant,
+			ResourceType:   "capability_request",
+			ResourceID:     request.ID,
+			Summary:        summary,
+			RequestedBy:    &request.RequestedBy,
+			RiskSeverity:   capabilityRiskSeverity(request.CapabilityType),
+		}, reviewerID, "")
+		if err != nil {
+			return approval, err
+		}
+		if approval != nil && approval.Status != domain.ApprovalRequestStatusApproved {
+			return approval, nil
+		}
 	}
 
 	// Update request status to approved
 	if err := s.requestRepo.UpdateStatus(id, domain.CapabilityRequestStatusApproved, reviewerID); err != nil {
-		return fmt.Errorf("failed to approve capability request: %w", err)
+		return approval, fmt.Errorf("failed to approve capability request: %w", err)
+	}
+
+	// Grant the capability to the agent, or extend the capability a renewal request is for
+	if err := s.grantRequestedCapability(&request.CapabilityRequest, reviewerID); err != nil {
+		// Rollback the approval if capability grant fails
+		_ = s.requestRepo.UpdateStatus(id, domain.CapabilityRequestStatusPending, reviewerID)
+		return approval, fmt.Errorf("failed to grant capability: %w", err)
 	}
 
-	// Grant the capability to the agent
+	fmt.Printf("✅ Capability request approved and capability granted: agent=%s, capability=%s, reviewer=%s\n",
+		request.AgentName, request.CapabilityType, reviewerID)
+
+	s.notifyDecision(id, domain.TemplateCapabilityRequestApproved)
+
+	return approval, nil
+}
+
+// grantRequestedCapability grants the capability of an approved request. A renewal extends the
+// expiry of its capability, or grants it again with the same scope once it expired.
+func (s *CapabilityRequestService) grantRequestedCapability(request *domain.CapabilityRequest, reviewerID uuid.UUID) error {
 	capability := &domain.AgentCapability{
 		AgentID:        request.AgentID,
 		CapabilityType: request.CapabilityType,
 		GrantedBy:      &reviewerID,
 		GrantedAt:      time.Now(),
+		ExpiresAt:      request.ExpiresAt,
 	}
 
-	if err := s.capabilityRepo.CreateCapability(capability); err != nil {
-		// Rollback the approval if capability grant fails
-		_ = s.requestRepo.UpdateStatus(id, domain.CapabilityRequestStatusPending, reviewerID)
-		return fmt.Errorf("failed to grant capability: %w", err)
+	if request.RenewsCapabilityID != nil {
+		renewed, err := s.capabilityRepo.GetCapabilityByID(*request.RenewsCapabilityID)
+		if err != nil {
+			return fmt.Errorf("renewed capability not found: %w", err)
+		}
+		if renewed.RevokedAt == nil {
+			return s.capabilityRepo.SetCapabilityExpiry(renewed.ID, request.ExpiresAt)
+		}
+		capability.CapabilityScope = renewed.CapabilityScope
 	}
 
-	fmt.Printf("✅ Capability request approved and capability granted: agent=%s, capability=%s, reviewer=%s\n",
-		request.AgentName, request.CapabilityType, reviewerID)
-
-	return nil
+	return s.capabilityRepo.CreateCapability(capability)
 }
 
 // RejectRequest rejects a capability request
@@ -155,8 +334,61 @@ func (s *CapabilityRequestService) RejectRequest(ctx context.Context, id uuid.UU
 		return fmt.Errorf("failed to reject capability request: %w", err)
 	}
 
+	// A rejection also closes any approval chain collecting approvals for the grant
+	if s.approvals != nil {
+		if agent, err := s.agentRepo.GetByID(request.AgentID); err == nil {
+			_ = s.approvals.RejectPending(ctx, agent.OrganizationID, domain.ApprovalActionCapabilityGrant, request.ID, reviewerID, "capability request rejected")
+		}
+	}
+
 	fmt.Printf("❌ Capability request rejected: agent=%s, capability=%s, reviewer=%s\n",
 		request.AgentName, request.CapabilityType, reviewerID)
 
+	s.notifyDecision(id, domain.TemplateCapabilityRequestRejected)
+
 	return nil
 }
+
+// notifyDecision emails the requester the decision on their request, from the sender of the
+// agent's organization. Email is non-critical: failures are logged.
+func (s *CapabilityRequestService) notifyDecision(id uuid.UUID, template domain.EmailTemplate) {
+	if s.emailService == nil {
+		return
+	}
+
+	// Reload the request for the reviewer's email
+	request, err := s.requestRepo.GetByID(id)
+	if err != nil || request.RequestedByEmail == "" {
+		return
+	}
+	agent, err := s.agentRepo.GetByID(request.AgentID)
+	if err != nil {
+		log.Printf("⚠️  Failed to load agent of capability request %s for the decision email: %v", id, err)
+		return
+	}
+
+	frontendURL := os.Getenv("FRONTEND_URL")
+	if frontendURL == "" {
+		frontendURL = "http://localhost:3000"
+	}
+	customData := map[string]interface{}{"Capability": request.CapabilityType}
+	if request.ExpiresAt != nil {
+		customData["ExpiresAt"] = request.ExpiresAt.UTC().Format(time.RFC1123)
+	}
+	if request.ReviewedByEmail != nil {
+		customData["ReviewedBy"] = *request.ReviewedByEmail
+	}
+
+	data := domain.EmailTemplateData{
+		UserName:     request.RequestedByEmail,
+		UserEmail:    request.RequestedByEmail,
+		AgentID:      agent.ID.String(),
+		AgentName:    request.AgentName,
+		DashboardURL: frontendURL,
+		Timestamp:    time.Now(),
+		CustomData:   customData,
+	}
+	if err := organizationEmail(s.emailService, agent.OrganizationID).SendTemplatedEmail(template, request.RequestedByEmail, data); err != nil {
+		log.Printf("⚠️  Failed to email %s about capability request %s: %v", request.RequestedByEmail, id, err)
+	}
+}
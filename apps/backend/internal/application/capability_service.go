@@ -2,8 +2,7 @@ package application
 
 import (
 	"context"
-	"crypto/ed25519"
-	"encoding/base64"
+	"errors"
 	"fmt"
 	"time"
 
@@ -27,6 +26,12 @@ type CapabilityService struct {
 	auditRepo      domain.AuditLogRepository
 	trustCalc      domain.TrustScoreCalculator
 	trustScoreRepo domain.TrustScoreRepository
+	// Optional: runs the incident response bundle when an agent is marked compromised
+	compromiseService *CompromiseResponseService
+	// Optional: marks capabilities that refer to MCP tools their server dropped
+	deprecationRepo domain.CapabilityDeprecationRepository
+	// Optional: enforces the organization's key algorithm policy on signatures
+	orgRepo domain.OrganizationRepository
 }
 
 // NewCapabilityService creates a new capability service
@@ -36,13 +41,19 @@ func NewCapabilityService(
 	auditRepo domain.AuditLogRepository,
 	trustCalc domain.TrustScoreCalculator,
 	trustScoreRepo domain.TrustScoreRepository,
+	compromiseService *CompromiseResponseService,
+	deprecationRepo domain.CapabilityDeprecationRepository,
+	orgRepo domain.OrganizationRepository,
 ) *CapabilityService {
 	return &CapabilityService{
-		capabilityRepo: capabilityRepo,
-		agentRepo:      agentRepo,
-		auditRepo:      auditRepo,
-		trustCalc:      trustCalc,
-		trustScoreRepo: trustScoreRepo,
+		capabilityRepo:    capabilityRepo,
+		agentRepo:         agentRepo,
+		auditRepo:         auditRepo,
+		trustCalc:         trustCalc,
+		trustScoreRepo:    trustScoreRepo,
+		compromiseService: compromiseService,
+		deprecationRepo:   deprecationRepo,
+		orgRepo:           orgRepo,
 	}
 }
 
@@ -69,14 +80,25 @@ func (s *CapabilityService) VerifyAction(
 
 	// 2. Verify signature (identity verification)
 	if agent.PublicKey != nil && len(signature) > 0 && len(payload) > 0 {
-		valid := s.verifySignature(*agent.PublicKey, agent.KeyAlgorithm, signature, payload)
-		if !valid {
+		var org *domain.Organization
+		if s.orgRepo != nil {
+			if org, err = s.orgRepo.GetByID(agent.OrganizationID); err != nil {
+				return nil, fmt.Errorf("failed to get organization: %w", err)
+			}
+		}
+		// Each key verifies with its own algorithm; the previous key still verifies until its
+		// rotation grace period ends
+		if _, err := verifyAgentSignature(org, agent, "", payload, signature, time.Now()); err != nil {
+			message := "Invalid signature"
+			if errors.Is(err, domain.ErrKeyAlgorithmNotAllowed) {
+				message = err.Error()
+			}
 			return &VerificationResult{
 				IsValid:      false,
 				IsAuthorized: false,
 				InScope:      false,
 				TrustScore:   agent.TrustScore,
-				Message:      "Invalid signature",
+				Message:      message,
 			}, nil
 		}
 	}
@@ -124,8 +146,16 @@ func (s *CapabilityService) VerifyAction(
 		// Check if agent should be marked as compromised
 		// IMPORTANT: trust_score is 0.0-1.0 scale, so 30% = 0.30
 		if newViolationCount >= 3 || newTrustScore < 0.30 {
-			if err := s.agentRepo.MarkAsCompromised(agentID); err != nil {
-				return nil, err
+			// The response bundle runs once, when the agent transitions to compromised
+			if s.compromiseService == nil {
+				if err := s.agentRepo.MarkAsCompromised(agentID); err != nil {
+					return nil, err
+				}
+			} else if agent.Status != domain.AgentStatusSuspended {
+				reason := fmt.Sprintf("Capability violation threshold reached (%d violations, trust score %.2f)", newViolationCount, newTrustScore)
+				if _, err := s.compromiseService.MarkAsCompromised(ctx, agentID, domain.CompromiseTriggerCapabilityViolation, reason, nil); err != nil {
+					return nil, err
+				}
 			}
 		}
 
@@ -184,13 +214,15 @@ func (s *CapabilityService) VerifyAction(
 	}, nil
 }
 
-// GrantCapability grants a new capability to an agent
+// GrantCapability grants a new capability to an agent. A capability with expiresAt set is
+// removed by the capability expiry job once that time passes.
 func (s *CapabilityService) GrantCapability(
 	ctx context.Context,
 	agentID uuid.UUID,
 	capabilityType string,
 	scope map[string]interface{},
 	grantedBy *uuid.UUID,
+	expiresAt *time.Time,
 ) (*domain.AgentCapability, error) {
 	// Verify agent exists
 	agent, err := s.agentRepo.GetByID(agentID)
@@ -198,6 +230,10 @@ func (s *CapabilityService) GrantCapability(
 		return nil, fmt.Errorf("agent not found: %w", err)
 	}
 
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expiresAt must be in the future", ErrInvalidCapabilityExpiry)
+	}
+
 	// Create capability
 	capability := &domain.AgentCapability{
 		AgentID:         agentID,
@@ -205,6 +241,7 @@ func (s *CapabilityService) GrantCapability(
 		CapabilityScope: scope,
 		GrantedBy:       grantedBy,
 		GrantedAt:       time.Now(),
+		ExpiresAt:       expiresAt,
 	}
 
 	if err := s.capabilityRepo.CreateCapability(capability); err != nil {
@@ -367,16 +404,38 @@ func (s *CapabilityService) mcpToolToCapabilityType(toolName string) string {
 	return fmt.Sprintf("%s:%s", domain.CapabilityMCPToolUse, toolName)
 }
 
-// GetAgentCapabilities retrieves all capabilities for an agent
+// GetAgentCapabilities retrieves all capabilities for an agent, marking the ones that refer
+// to MCP tools their server no longer exposes
 func (s *CapabilityService) GetAgentCapabilities(
 	ctx context.Context,
 	agentID uuid.UUID,
 	activeOnly bool,
 ) ([]*domain.AgentCapability, error) {
+	var capabilities []*domain.AgentCapability
+	var err error
 	if activeOnly {
-		return s.capabilityRepo.GetActiveCapabilitiesByAgentID(agentID)
+		capabilities, err = s.capabilityRepo.GetActiveCapabilitiesByAgentID(agentID)
+	} else {
+		capabilities, err = s.capabilityRepo.GetCapabilitiesByAgentID(agentID)
+	}
+	if err != nil || s.deprecationRepo == nil {
+		return capabilities, err
+	}
+
+	deprecations, err := s.deprecationRepo.GetActiveByAgent(agentID)
+	if err != nil {
+		// Log error but still return the capabilities
+		fmt.Printf("Warning: failed to load capability deprecations: %v\n", err)
+		return capabilities, nil
+	}
+	deprecated := make(map[uuid.UUID]*domain.CapabilityDeprecation, len(deprecations))
+	for _, deprecation := range deprecations {
+		deprecated[deprecation.CapabilityID] = deprecation
 	}
-	return s.capabilityRepo.GetCapabilitiesByAgentID(agentID)
+	for _, capability := range capabilities {
+		capability.Deprecation = deprecated[capability.ID]
+	}
+	return capabilities, nil
 }
 
 // CapabilityDefinition represents a capability type available in the system
@@ -494,27 +553,6 @@ func (s *CapabilityService) GetRecentViolations(
 	return s.capabilityRepo.GetRecentViolations(orgID, minutes)
 }
 
-// Helper: Verify cryptographic signature
-func (s *CapabilityService) verifySignature(publicKeyStr string, algorithm string, signature []byte, payload []byte) bool {
-	// Decode public key from base64
-	publicKey, err := base64.StdEncoding.DecodeString(publicKeyStr)
-	if err != nil {
-		return false
-	}
-
-	// For now, only Ed25519 is implemented
-	// TODO: Add support for RSA and ECDSA
-	if algorithm == "Ed25519" || algorithm == "" {
-		if len(publicKey) != ed25519.PublicKeySize {
-			return false
-		}
-		return ed25519.Verify(publicKey, payload, signature)
-	}
-
-	// Unsupported algorithm
-	return false
-}
-
 // Helper: Check if agent has a specific capability
 func (s *CapabilityService) hasCapability(capabilities []*domain.AgentCapability, requestedCapability string) bool {
 	for _, cap := range capabilities {
@@ -0,0 +1,569 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// defaultChangeMonitorMinutes is how long an applied change is watched when the request does not say
+	defaultChangeMonitorMinutes = 60
+	// maxChangeMonitorMinutes caps the monitoring period at one week
+	maxChangeMonitorMinutes = 7 * 24 * 60
+	// defaultChangeRollbackErrorRate is the verification error rate that rolls a change back by default
+	defaultChangeRollbackErrorRate = 0.25
+	// changeRollbackMinVerifications is the number of verifications needed before an error rate
+	// is trusted enough to roll a change back
+	changeRollbackMinVerifications = 10
+)
+
+var (
+	// ErrInvalidChangeRequest is returned when a change request is malformed
+	ErrInvalidChangeRequest = errors.New("invalid change request")
+	// ErrChangeTargetNotFound is returned when the agent or policy to change does not exist in the organization
+	ErrChangeTargetNotFound = errors.New("change target not found")
+	// ErrChangeRequestNotOpen is returned when approving, rejecting or cancelling a change that
+	// is no longer waiting to be applied
+	ErrChangeRequestNotOpen = errors.New("change request is not awaiting approval or scheduled")
+	// ErrChangeRequestNotApplied is returned when rolling back a change that is not in effect
+	ErrChangeRequestNotApplied = errors.New("change request is not applied")
+)
+
+// changeableAgentFields and changeablePolicyFields are the JSON fields a change request may set.
+// Identity, keys and status have dedicated flows, and so does disabling a policy.
+var (
+	changeableAgentFields = []string{
+		"displayName", "description", "version", "certificateUrl", "repositoryUrl", "documentationUrl", "talksTo",
+	}
+	changeablePolicyFields = []string{
+		"name", "description", "enforcementAction", "severityThreshold", "rules", "appliesTo", "priority",
+	}
+)
+
+// ChangeRequestService stages configuration changes to agents and security policies. A change is
+// approved in advance, applied by the change window job once its effective time arrives, and
+// rolled back automatically when the verification error rate rises while it is monitored.
+type ChangeRequestService struct {
+	changeRepo   domain.ChangeRequestRepository
+	agentRepo    domain.AgentRepository
+	policyRepo   domain.SecurityPolicyRepository
+	eventRepo    domain.VerificationEventRepository
+	approvals    *ApprovalChainService
+	alertService *AlertService
+	now          func() time.Time
+}
+
+// NewChangeRequestService creates a new change request service.
+// approvals and alertService may be nil; changes are then approved by a single call and
+// rollbacks raise no alert.
+func NewChangeRequestService(
+	changeRepo domain.ChangeRequestRepository,
+	agentRepo domain.AgentRepository,
+	policyRepo domain.SecurityPolicyRepository,
+	eventRepo domain.VerificationEventRepository,
+	approvals *ApprovalChainService,
+	alertService *AlertService,
+) *ChangeRequestService {
+	return &ChangeRequestService{
+		changeRepo:   changeRepo,
+		agentRepo:    agentRepo,
+		policyRepo:   policyRepo,
+		eventRepo:    eventRepo,
+		approvals:    approvals,
+		alertService: alertService,
+		now:          time.Now,
+	}
+}
+
+// CreateChangeRequestRequest represents the request to stage a configuration change
+type CreateChangeRequestRequest struct {
+	TargetType        domain.ChangeTargetType `json:"targetType"`
+	TargetID          uuid.UUID               `json:"targetId"`
+	Summary           string                  `json:"summary"`
+	Changes           map[string]interface{}  `json:"changes"`
+	EffectiveAt       *time.Time              `json:"effectiveAt,omitempty"`       // Defaults to now
+	WindowEndsAt      *time.Time              `json:"windowEndsAt,omitempty"`      // The change expires if not applied by then
+	MonitorMinutes    *int                    `json:"monitorMinutes,omitempty"`    // Defaults to 60; 0 disables automatic rollback
+	RollbackErrorRate *float64                `json:"rollbackErrorRate,omitempty"` // Defaults to 0.25
+}
+
+// CreateChangeRequest stages a change; it waits for approval before it can be applied
+func (s *ChangeRequestService) CreateChangeRequest(ctx context.Context, orgID, userID uuid.UUID, req *CreateChangeRequestRequest) (*domain.ChangeRequest, error) {
+	now := s.now().UTC()
+	change := &domain.ChangeRequest{
+		OrganizationID:    orgID,
+		TargetType:        req.TargetType,
+		TargetID:          req.TargetID,
+		Summary:           strings.TrimSpace(req.Summary),
+		Changes:           req.Changes,
+		EffectiveAt:       now,
+		WindowEndsAt:      req.WindowEndsAt,
+		MonitorMinutes:    defaultChangeMonitorMinutes,
+		RollbackErrorRate: defaultChangeRollbackErrorRate,
+		Status:            domain.ChangeRequestStatusPendingApproval,
+		RequestedBy:       userID,
+	}
+	if req.EffectiveAt != nil {
+		change.EffectiveAt = req.EffectiveAt.UTC()
+	}
+	if req.MonitorMinutes != nil {
+		change.MonitorMinutes = *req.MonitorMinutes
+	}
+	if req.RollbackErrorRate != nil {
+		change.RollbackErrorRate = *req.RollbackErrorRate
+	}
+
+	if err := s.validate(change, now); err != nil {
+		return nil, err
+	}
+	if change.Summary == "" {
+		change.Summary = fmt.Sprintf("Change %s of %s %s", strings.Join(sortedKeys(change.Changes), ", "), change.TargetType, change.TargetID)
+	}
+
+	if err := s.changeRepo.Create(change); err != nil {
+		return nil, fmt.Errorf("failed to create change request: %w", err)
+	}
+	return change, nil
+}
+
+// validate checks the schedule and dry-runs the change against the current target
+func (s *ChangeRequestService) validate(change *domain.ChangeRequest, now time.Time) error {
+	var allowed []string
+	switch change.TargetType {
+	case domain.ChangeTargetAgent:
+		allowed = changeableAgentFields
+	case domain.ChangeTargetSecurityPolicy:
+		allowed = changeablePolicyFields
+	default:
+		return fmt.Errorf("%w: targetType must be agent or security_policy", ErrInvalidChangeRequest)
+	}
+	if len(change.Changes) == 0 {
+		return fmt.Errorf("%w: changes are required", ErrInvalidChangeRequest)
+	}
+	for field := range change.Changes {
+		if !slices.Contains(allowed, field) {
+			return fmt.Errorf("%w: %s cannot be changed on a %s; allowed fields are %s",
+				ErrInvalidChangeRequest, field, change.TargetType, strings.Join(allowed, ", "))
+		}
+	}
+
+	if change.WindowEndsAt != nil {
+		if !change.WindowEndsAt.After(change.EffectiveAt) {
+			return fmt.Errorf("%w: windowEndsAt must be after effectiveAt", ErrInvalidChangeRequest)
+		}
+		if !change.WindowEndsAt.After(now) {
+			return fmt.Errorf("%w: windowEndsAt must be in the future", ErrInvalidChangeRequest)
+		}
+	}
+	if change.MonitorMinutes < 0 || change.MonitorMinutes > maxChangeMonitorMinutes {
+		return fmt.Errorf("%w: monitorMinutes must be between 0 and %d", ErrInvalidChangeRequest, maxChangeMonitorMinutes)
+	}
+	if change.RollbackErrorRate <= 0 || change.RollbackErrorRate > 1 {
+		return fmt.Errorf("%w: rollbackErrorRate must be above 0 and at most 1", ErrInvalidChangeRequest)
+	}
+
+	// The change is applied to a copy of the target, which is not saved
+	_, err := s.patchTarget(change, change.Changes, false)
+	return err
+}
+
+// ListChangeRequests lists a page of the organization's change requests, newest first
+func (s *ChangeRequestService) ListChangeRequests(ctx context.Context, orgID uuid.UUID, status domain.ChangeRequestStatus, limit, offset int) ([]*domain.ChangeRequest, int, error) {
+	return s.changeRepo.GetByOrganization(orgID, status, limit, offset)
+}
+
+// GetChangeRequest retrieves a change request of the organization
+func (s *ChangeRequestService) GetChangeRequest(ctx context.Context, orgID, id uuid.UUID) (*domain.ChangeRequest, error) {
+	change, err := s.changeRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if change.OrganizationID != orgID {
+		return nil, domain.ErrChangeRequestNotFound
+	}
+	return change, nil
+}
+
+// ApproveChangeRequest approves a change so it is applied when its window opens. When an approval
+// chain guards configuration changes, the call records the user's approval and the change is only
+// scheduled once the returned approval request is approved.
+func (s *ChangeRequestService) ApproveChangeRequest(ctx context.Context, orgID, id, userID uuid.UUID, comment string) (*domain.ChangeRequest, *domain.ApprovalRequest, error) {
+	change, err := s.GetChangeRequest(ctx, orgID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if change.Status != domain.ChangeRequestStatusPendingApproval {
+		return change, nil, ErrChangeRequestNotOpen
+	}
+
+	var approval *domain.ApprovalRequest
+	if s.approvals != nil {
+		approval, err = s.approvals.Approve(ctx, ApprovalSubject{
+			OrganizationID: orgID,
+			Action:         domain.ApprovalActionConfigChange,
+			ResourceType:   "change_request",
+			ResourceID:     change.ID,
+			Summary:        change.Summary,
+			RequestedBy:    &change.RequestedBy,
+		}, userID, comment)
+		if err != nil {
+			return change, approval, err
+		}
+		if approval != nil {
+			change.ApprovalRequestID = &approval.ID
+			if approval.Status != domain.ApprovalRequestStatusApproved {
+				if err := s.changeRepo.Update(change); err != nil {
+					return change, approval, fmt.Errorf("failed to update change request: %w", err)
+				}
+				return change, approval, nil
+			}
+		}
+	}
+
+	now := s.now().UTC()
+	change.Status = domain.ChangeRequestStatusScheduled
+	change.ApprovedBy = &userID
+	change.ApprovedAt = &now
+	if err := s.changeRepo.Update(change); err != nil {
+		return change, approval, fmt.Errorf("failed to approve change request: %w", err)
+	}
+	return change, approval, nil
+}
+
+// RejectChangeRequest rejects a change that has not been applied yet
+func (s *ChangeRequestService) RejectChangeRequest(ctx context.Context, orgID, id, userID uuid.UUID, reason string) (*domain.ChangeRequest, error) {
+	return s.close(ctx, orgID, id, userID, domain.ChangeRequestStatusRejected, reason)
+}
+
+// CancelChangeRequest withdraws a change that has not been applied yet
+func (s *ChangeRequestService) CancelChangeRequest(ctx context.Context, orgID, id, userID uuid.UUID, reason string) (*domain.ChangeRequest, error) {
+	return s.close(ctx, orgID, id, userID, domain.ChangeRequestStatusCancelled, reason)
+}
+
+func (s *ChangeRequestService) close(ctx context.Context, orgID, id, userID uuid.UUID, status domain.ChangeRequestStatus, reason string) (*domain.ChangeRequest, error) {
+	change, err := s.GetChangeRequest(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !change.Status.IsOpen() {
+		return change, ErrChangeRequestNotOpen
+	}
+
+	now := s.now().UTC()
+	change.Status = status
+	change.Reason = strings.TrimSpace(reason)
+	change.ClosedAt = &now
+	if err := s.changeRepo.Update(change); err != nil {
+		return nil, fmt.Errorf("failed to update change request: %w", err)
+	}
+
+	// Closing the change also closes any approval chain collecting approvals for it
+	if s.approvals != nil {
+		_ = s.approvals.RejectPending(ctx, orgID, domain.ApprovalActionConfigChange, change.ID, userID, "change request "+string(status))
+	}
+	return change, nil
+}
+
+// RollbackChangeRequest restores the values a change replaced, during or after its monitoring
+func (s *ChangeRequestService) RollbackChangeRequest(ctx context.Context, orgID, id, userID uuid.UUID, reason string) (*domain.ChangeRequest, error) {
+	change, err := s.GetChangeRequest(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if change.Status != domain.ChangeRequestStatusApplied && change.Status != domain.ChangeRequestStatusCompleted {
+		return change, ErrChangeRequestNotApplied
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reason = "rolled back manually by user " + userID.String()
+	}
+	if err := s.rollback(change, reason); err != nil {
+		return change, err
+	}
+	return change, nil
+}
+
+// ProcessChangeWindows runs one pass of the change window job: it applies approved changes whose
+// window has opened, expires changes whose window closed before they were applied, and checks
+// the error rate of applied changes, rolling back those that made it rise.
+func (s *ChangeRequestService) ProcessChangeWindows(ctx context.Context) (applied, rolledBack int, err error) {
+	changes, err := s.changeRepo.GetByStatus(
+		domain.ChangeRequestStatusPendingApproval,
+		domain.ChangeRequestStatusScheduled,
+		domain.ChangeRequestStatusApplied,
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get open change requests: %w", err)
+	}
+
+	now := s.now().UTC()
+	for _, change := range changes {
+		if ctx.Err() != nil {
+			return applied, rolledBack, ctx.Err()
+		}
+
+		switch change.Status {
+		case domain.ChangeRequestStatusPendingApproval, domain.ChangeRequestStatusScheduled:
+			if change.WindowEndsAt != nil && !now.Before(*change.WindowEndsAt) {
+				change.Status = domain.ChangeRequestStatusExpired
+				change.Reason = "change window ended before the change was applied"
+				change.ClosedAt = &now
+				if err := s.changeRepo.Update(change); err != nil {
+					log.Printf("⚠️  Failed to expire change request %s: %v", change.ID, err)
+				}
+				continue
+			}
+			if change.Status == domain.ChangeRequestStatusScheduled && !now.Before(change.EffectiveAt) {
+				if s.apply(change, now) {
+					applied++
+				}
+			}
+
+		case domain.ChangeRequestStatusApplied:
+			if s.monitor(ctx, change, now) {
+				rolledBack++
+			}
+		}
+	}
+	return applied, rolledBack, nil
+}
+
+// apply puts a scheduled change into effect; a change that cannot be applied is marked failed
+func (s *ChangeRequestService) apply(change *domain.ChangeRequest, now time.Time) bool {
+	// The error rate before the change is the baseline its monitoring compares against
+	if change.MonitorMinutes > 0 {
+		if rate, samples, err := s.errorRate(change, now.Add(-time.Duration(change.MonitorMinutes)*time.Minute), now); err == nil && samples >= changeRollbackMinVerifications {
+			change.BaselineErrorRate = &rate
+		}
+	}
+
+	previous, err := s.patchTarget(change, change.Changes, true)
+	if err != nil {
+		change.Status = domain.ChangeRequestStatusFailed
+		change.Reason = err.Error()
+		change.ClosedAt = &now
+		if err := s.changeRepo.Update(change); err != nil {
+			log.Printf("⚠️  Failed to update change request %s: %v", change.ID, err)
+		}
+		return false
+	}
+
+	change.Previous = previous
+	change.AppliedAt = &now
+	change.Status = domain.ChangeRequestStatusApplied
+	if change.MonitorMinutes == 0 {
+		change.Status = domain.ChangeRequestStatusCompleted
+		change.ClosedAt = &now
+	}
+	if err := s.changeRepo.Update(change); err != nil {
+		log.Printf("⚠️  Failed to update applied change request %s: %v", change.ID, err)
+	}
+	return true
+}
+
+// monitor rolls an applied change back when the verification error rate since it was applied
+// reached the threshold and exceeds the baseline, and completes it once monitoring ends.
+// It reports whether the change was rolled back.
+func (s *ChangeRequestService) monitor(ctx context.Context, change *domain.ChangeRequest, now time.Time) bool {
+	if change.AppliedAt == nil {
+		return false
+	}
+
+	rate, samples, err := s.errorRate(change, *change.AppliedAt, now)
+	if err != nil {
+		log.Printf("⚠️  Failed to get error rate for change request %s: %v", change.ID, err)
+		return false
+	}
+	baseline := 0.0
+	if change.BaselineErrorRate != nil {
+		baseline = *change.BaselineErrorRate
+	}
+
+	if samples >= changeRollbackMinVerifications && rate >= change.RollbackErrorRate && rate > baseline {
+		reason := fmt.Sprintf("verification error rate rose to %.0f%% over %d verifications (threshold %.0f%%, %.0f%% before the change)",
+			rate*100, samples, change.RollbackErrorRate*100, baseline*100)
+		if err := s.rollback(change, reason); err != nil {
+			log.Printf("⚠️  Failed to roll back change request %s: %v", change.ID, err)
+			return false
+		}
+		s.alertRollback(ctx, change)
+		return true
+	}
+
+	if !now.Before(change.AppliedAt.Add(time.Duration(change.MonitorMinutes) * time.Minute)) {
+		change.Status = domain.ChangeRequestStatusCompleted
+		change.ClosedAt = &now
+		if err := s.changeRepo.Update(change); err != nil {
+			log.Printf("⚠️  Failed to complete change request %s: %v", change.ID, err)
+		}
+	}
+	return false
+}
+
+// rollback restores the previous values of the changed fields; a failed rollback marks the change failed
+func (s *ChangeRequestService) rollback(change *domain.ChangeRequest, reason string) error {
+	now := s.now().UTC()
+	change.ClosedAt = &now
+	if _, err := s.patchTarget(change, change.Previous, true); err != nil {
+		change.Status = domain.ChangeRequestStatusFailed
+		change.Reason = "rollback failed: " + err.Error()
+		if updateErr := s.changeRepo.Update(change); updateErr != nil {
+			log.Printf("⚠️  Failed to update change request %s: %v", change.ID, updateErr)
+		}
+		return err
+	}
+
+	change.Status = domain.ChangeRequestStatusRolledBack
+	change.Reason = reason
+	if err := s.changeRepo.Update(change); err != nil {
+		return fmt.Errorf("failed to update change request: %w", err)
+	}
+	return nil
+}
+
+func (s *ChangeRequestService) alertRollback(ctx context.Context, change *domain.ChangeRequest) {
+	if s.alertService == nil {
+		return
+	}
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: change.OrganizationID,
+		AlertType:      domain.AlertConfigChangeRolledBack,
+		Severity:       domain.AlertSeverityHigh,
+		Title:          fmt.Sprintf("Configuration change rolled back: %s", change.Summary),
+		Description: fmt.Sprintf("The change to %s %s was rolled back automatically: %s.",
+			change.TargetType, change.TargetID, change.Reason),
+		ResourceType: string(change.TargetType),
+		ResourceID:   change.TargetID,
+		CreatedAt:    s.now().UTC(),
+	}
+	if err := s.alertService.CreateAlert(ctx, alert); err != nil {
+		log.Printf("⚠️  Failed to create rollback alert for change request %s: %v", change.ID, err)
+	}
+}
+
+// errorRate is the share of failed verifications between from and to: the target agent's, or
+// the organization's for a policy change, since a policy affects every agent it applies to
+func (s *ChangeRequestService) errorRate(change *domain.ChangeRequest, from, to time.Time) (float64, int, error) {
+	var succeeded, failed int
+	if change.TargetType == domain.ChangeTargetAgent {
+		stats, err := s.eventRepo.GetAgentStatistics(change.TargetID, from, to)
+		if err != nil {
+			return 0, 0, err
+		}
+		succeeded, failed = stats.SuccessCount, stats.FailedCount
+	} else {
+		stats, err := s.eventRepo.GetStatistics(change.OrganizationID, from, to)
+		if err != nil {
+			return 0, 0, err
+		}
+		succeeded, failed = stats.SuccessCount, stats.FailedCount
+	}
+
+	samples := succeeded + failed
+	if samples == 0 {
+		return 0, 0, nil
+	}
+	return float64(failed) / float64(samples), samples, nil
+}
+
+// patchTarget sets the given fields of the change's target and returns the values they had.
+// The target is only saved when save is set.
+func (s *ChangeRequestService) patchTarget(change *domain.ChangeRequest, values map[string]interface{}, save bool) (map[string]interface{}, error) {
+	switch change.TargetType {
+	case domain.ChangeTargetAgent:
+		agent, err := s.agentRepo.GetByID(change.TargetID)
+		if err != nil || agent.OrganizationID != change.OrganizationID {
+			return nil, ErrChangeTargetNotFound
+		}
+		previous, err := patchJSONFields(agent, values, nil)
+		if err != nil {
+			return nil, err
+		}
+		if save {
+			if err := s.agentRepo.Update(agent); err != nil {
+				return nil, fmt.Errorf("failed to update agent: %w", err)
+			}
+		}
+		return previous, nil
+
+	case domain.ChangeTargetSecurityPolicy:
+		policy, err := s.policyRepo.GetByID(change.TargetID)
+		if err != nil || policy.OrganizationID != change.OrganizationID {
+			return nil, ErrChangeTargetNotFound
+		}
+		// Rules are replaced as a whole rather than merged into
+		var reset func()
+		if _, ok := values["rules"]; ok {
+			reset = func() { policy.Rules = nil }
+		}
+		previous, err := patchJSONFields(policy, values, reset)
+		if err != nil {
+			return nil, err
+		}
+		switch policy.EnforcementAction {
+		case domain.EnforcementAlertOnly, domain.EnforcementBlockAndAlert, domain.EnforcementAllow:
+		default:
+			return nil, fmt.Errorf("%w: invalid enforcementAction %q", ErrInvalidChangeRequest, policy.EnforcementAction)
+		}
+		if _, ok := values["severityThreshold"]; ok && severityRank(policy.SeverityThreshold) < 0 {
+			return nil, fmt.Errorf("%w: invalid severityThreshold %q", ErrInvalidChangeRequest, policy.SeverityThreshold)
+		}
+		if save {
+			if err := s.policyRepo.Update(policy); err != nil {
+				return nil, fmt.Errorf("failed to update security policy: %w", err)
+			}
+		}
+		return previous, nil
+	}
+	return nil, fmt.Errorf("%w: unknown target type %q", ErrInvalidChangeRequest, change.TargetType)
+}
+
+// patchJSONFields records the JSON values of the fields about to change, then decodes the new
+// values onto the target. reset, when set, runs between the two, e.g. to clear maps that must
+// be replaced rather than merged.
+func patchJSONFields(target interface{}, values map[string]interface{}, reset func()) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(target)
+	if err != nil {
+		return nil, err
+	}
+	current := map[string]interface{}{}
+	if err := json.Unmarshal(encoded, &current); err != nil {
+		return nil, err
+	}
+	previous := make(map[string]interface{}, len(values))
+	for field := range values {
+		previous[field] = current[field]
+	}
+
+	if reset != nil {
+		reset()
+	}
+	patch, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidChangeRequest, err)
+	}
+	if err := json.Unmarshal(patch, target); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidChangeRequest, err)
+	}
+	return previous, nil
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,208 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeRequestsApplyInWindowAndRollBackOnErrors(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	ctx := context.Background()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+
+	newUser := func(role domain.UserRole) *domain.User {
+		user := testsupport.NewUser(org.ID, func(u *domain.User) { u.Role = role })
+		require.NoError(t, repos.User.Create(user))
+		return user
+	}
+	requester, manager, admin := newUser(domain.RoleMember), newUser(domain.RoleManager), newUser(domain.RoleAdmin)
+
+	approvals := NewApprovalChainService(repos.ApprovalChain, repos.ApprovalRequest, repos.User, repos.Tag)
+	_, err := approvals.CreateChain(ctx, org.ID, admin.ID, &ApprovalChainRequest{
+		Name:   "Config changes",
+		Action: domain.ApprovalActionConfigChange,
+		Steps:  []domain.ApprovalStep{{Role: domain.RoleAdmin}},
+	})
+	require.NoError(t, err)
+
+	alerts := NewAlertService(repos.Alert, repos.Agent, nil, nil, nil, nil)
+	changes := NewChangeRequestService(repos.ChangeRequest, repos.Agent, repos.SecurityPolicy, repos.VerificationEvent, approvals, alerts)
+
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) {
+		a.DisplayName = "Billing Agent"
+		a.TalksTo = []string{"ledger"}
+	})
+	require.NoError(t, repos.Agent.Create(agent))
+
+	// Only configuration fields can be staged, with values of the right type
+	_, err = changes.CreateChangeRequest(ctx, org.ID, requester.ID, &CreateChangeRequestRequest{
+		TargetType: domain.ChangeTargetAgent, TargetID: agent.ID, Changes: map[string]interface{}{"status": "verified"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidChangeRequest)
+	_, err = changes.CreateChangeRequest(ctx, org.ID, requester.ID, &CreateChangeRequestRequest{
+		TargetType: domain.ChangeTargetAgent, TargetID: agent.ID, Changes: map[string]interface{}{"talksTo": "ledger"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidChangeRequest)
+	_, err = changes.CreateChangeRequest(ctx, org.ID, requester.ID, &CreateChangeRequestRequest{
+		TargetType: domain.ChangeTargetAgent, TargetID: uuid.New(), Changes: map[string]interface{}{"version": "2.0.0"},
+	})
+	assert.ErrorIs(t, err, ErrChangeTargetNotFound)
+
+	// Verifications before the change set its baseline error rate
+	for i := 0; i < 10; i++ {
+		require.NoError(t, repos.VerificationEvent.Create(testsupport.NewVerificationEvent(agent, func(e *domain.VerificationEvent) {
+			e.CreatedAt = time.Now().Add(-5 * time.Minute)
+		})))
+	}
+
+	change, err := changes.CreateChangeRequest(ctx, org.ID, requester.ID, &CreateChangeRequestRequest{
+		TargetType: domain.ChangeTargetAgent,
+		TargetID:   agent.ID,
+		Changes:    map[string]interface{}{"displayName": "Billing Agent v2", "talksTo": []string{"ledger", "payments"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChangeRequestStatusPendingApproval, change.Status)
+
+	// Nothing is applied before approval; the chain needs an admin other than the requester
+	applied, _, err := changes.ProcessChangeWindows(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, applied)
+	_, _, err = changes.ApproveChangeRequest(ctx, org.ID, change.ID, requester.ID, "")
+	assert.ErrorIs(t, err, ErrApprovalNotAllowed)
+	_, _, err = changes.ApproveChangeRequest(ctx, org.ID, change.ID, manager.ID, "")
+	assert.ErrorIs(t, err, ErrApprovalNotAllowed)
+	change, approval, err := changes.ApproveChangeRequest(ctx, org.ID, change.ID, admin.ID, "ship it")
+	require.NoError(t, err)
+	require.NotNil(t, approval)
+	assert.Equal(t, domain.ApprovalRequestStatusApproved, approval.Status)
+	assert.Equal(t, domain.ChangeRequestStatusScheduled, change.Status)
+
+	applied, rolledBack, err := changes.ProcessChangeWindows(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	assert.Zero(t, rolledBack)
+	stored, err := repos.Agent.GetByID(agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Billing Agent v2", stored.DisplayName)
+	assert.Equal(t, []string{"ledger", "payments"}, stored.TalksTo)
+	change, err = changes.GetChangeRequest(ctx, org.ID, change.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChangeRequestStatusApplied, change.Status)
+	assert.Equal(t, "Billing Agent", change.Previous["displayName"])
+	require.NotNil(t, change.BaselineErrorRate)
+	assert.Zero(t, *change.BaselineErrorRate)
+
+	// Errors below the rollback threshold keep the change
+	for i := 0; i < 10; i++ {
+		require.NoError(t, repos.VerificationEvent.Create(testsupport.NewVerificationEvent(agent)))
+	}
+	_, rolledBack, err = changes.ProcessChangeWindows(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, rolledBack)
+
+	// A rising error rate rolls the change back and raises an alert
+	for i := 0; i < 10; i++ {
+		require.NoError(t, repos.VerificationEvent.Create(testsupport.NewVerificationEvent(agent, func(e *domain.VerificationEvent) {
+			e.Status = domain.VerificationEventStatusFailed
+		})))
+	}
+	_, rolledBack, err = changes.ProcessChangeWindows(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rolledBack)
+	stored, err = repos.Agent.GetByID(agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Billing Agent", stored.DisplayName)
+	assert.Equal(t, []string{"ledger"}, stored.TalksTo)
+	change, err = changes.GetChangeRequest(ctx, org.ID, change.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChangeRequestStatusRolledBack, change.Status)
+	assert.Contains(t, change.Reason, "error rate rose to 50%")
+
+	raised, err := repos.Alert.GetByOrganization(org.ID, 100, 0)
+	require.NoError(t, err)
+	require.Len(t, raised, 1)
+	assert.Equal(t, domain.AlertConfigChangeRolledBack, raised[0].AlertType)
+	assert.Equal(t, agent.ID, raised[0].ResourceID)
+
+	// Policy rules are replaced rather than merged; without monitoring the change completes at once
+	policy := &domain.SecurityPolicy{
+		OrganizationID:    org.ID,
+		Name:              "Low trust",
+		PolicyType:        domain.PolicyTypeTrustScoreLow,
+		EnforcementAction: domain.EnforcementAlertOnly,
+		SeverityThreshold: domain.AlertSeverityWarning,
+		Rules:             map[string]interface{}{"trust_threshold": 0.3, "legacy": true},
+		AppliesTo:         "all",
+		IsEnabled:         true,
+		CreatedBy:         admin.ID,
+	}
+	require.NoError(t, repos.SecurityPolicy.Create(policy))
+	_, err = changes.CreateChangeRequest(ctx, org.ID, admin.ID, &CreateChangeRequestRequest{
+		TargetType: domain.ChangeTargetSecurityPolicy, TargetID: policy.ID, Changes: map[string]interface{}{"enforcementAction": "deny_everything"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidChangeRequest)
+	noMonitoring := 0
+	policyChange, err := changes.CreateChangeRequest(ctx, org.ID, manager.ID, &CreateChangeRequestRequest{
+		TargetType:     domain.ChangeTargetSecurityPolicy,
+		TargetID:       policy.ID,
+		Changes:        map[string]interface{}{"enforcementAction": "block_and_alert", "rules": map[string]interface{}{"trust_threshold": 0.5}},
+		MonitorMinutes: &noMonitoring,
+	})
+	require.NoError(t, err)
+	_, _, err = changes.ApproveChangeRequest(ctx, org.ID, policyChange.ID, admin.ID, "")
+	require.NoError(t, err)
+	applied, _, err = changes.ProcessChangeWindows(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	updatedPolicy, err := repos.SecurityPolicy.GetByID(policy.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EnforcementBlockAndAlert, updatedPolicy.EnforcementAction)
+	assert.Equal(t, map[string]interface{}{"trust_threshold": 0.5}, updatedPolicy.Rules)
+	policyChange, err = changes.GetChangeRequest(ctx, org.ID, policyChange.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChangeRequestStatusCompleted, policyChange.Status)
+
+	// A completed change can still be rolled back by hand
+	policyChange, err = changes.RollbackChangeRequest(ctx, org.ID, policyChange.ID, manager.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChangeRequestStatusRolledBack, policyChange.Status)
+	updatedPolicy, err = repos.SecurityPolicy.GetByID(policy.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EnforcementAlertOnly, updatedPolicy.EnforcementAction)
+	assert.Equal(t, map[string]interface{}{"trust_threshold": 0.3, "legacy": true}, updatedPolicy.Rules)
+
+	// A change whose window closes before it is approved expires; cancelled changes cannot be approved
+	windowEndsAt := time.Now().Add(50 * time.Millisecond)
+	expiring, err := changes.CreateChangeRequest(ctx, org.ID, requester.ID, &CreateChangeRequestRequest{
+		TargetType: domain.ChangeTargetAgent, TargetID: agent.ID, Changes: map[string]interface{}{"version": "3.0.0"}, WindowEndsAt: &windowEndsAt,
+	})
+	require.NoError(t, err)
+	time.Sleep(60 * time.Millisecond)
+	_, _, err = changes.ProcessChangeWindows(ctx)
+	require.NoError(t, err)
+	expiring, err = changes.GetChangeRequest(ctx, org.ID, expiring.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChangeRequestStatusExpired, expiring.Status)
+
+	cancelled, err := changes.CreateChangeRequest(ctx, org.ID, requester.ID, &CreateChangeRequestRequest{
+		TargetType: domain.ChangeTargetAgent, TargetID: agent.ID, Changes: map[string]interface{}{"version": "3.0.0"},
+	})
+	require.NoError(t, err)
+	_, err = changes.CancelChangeRequest(ctx, org.ID, cancelled.ID, requester.ID, "not needed")
+	require.NoError(t, err)
+	_, _, err = changes.ApproveChangeRequest(ctx, org.ID, cancelled.ID, admin.ID, "")
+	assert.ErrorIs(t, err, ErrChangeRequestNotOpen)
+
+	listed, total, err := changes.ListChangeRequests(ctx, org.ID, domain.ChangeRequestStatusRolledBack, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, listed, 2)
+}
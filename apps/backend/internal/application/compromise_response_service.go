@@ -0,0 +1,412 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// CompromiseResponseService marks agents as compromised and runs the organization's
+// incident response bundle, returning a report of every action taken
+type CompromiseResponseService struct {
+	responseRepo        domain.CompromiseResponseRepository
+	agentRepo           domain.AgentRepository
+	apiKeyRepo          domain.APIKeyRepository
+	sdkTokenRepo        domain.SDKTokenRepository
+	securityRepo        domain.SecurityRepository
+	mcpRepo             domain.MCPServerRepository
+	userRepo            domain.UserRepository
+	attestationService  *MCPAttestationService
+	notificationService *NotificationService
+	emailService        domain.EmailService
+	certificates        *AgentCertificateService
+}
+
+// NewCompromiseResponseService creates a new compromise response service.
+// Optional dependencies may be nil; the steps that need them are reported as skipped.
+func NewCompromiseResponseService(
+	responseRepo domain.CompromiseResponseRepository,
+	agentRepo domain.AgentRepository,
+	apiKeyRepo domain.APIKeyRepository,
+	sdkTokenRepo domain.SDKTokenRepository,
+	securityRepo domain.SecurityRepository,
+	mcpRepo domain.MCPServerRepository,
+	userRepo domain.UserRepository,
+	attestationService *MCPAttestationService,
+	notificationService *NotificationService,
+	emailService domain.EmailService,
+	certificates *AgentCertificateService,
+) *CompromiseResponseService {
+	return &CompromiseResponseService{
+		responseRepo:        responseRepo,
+		agentRepo:           agentRepo,
+		apiKeyRepo:          apiKeyRepo,
+		sdkTokenRepo:        sdkTokenRepo,
+		securityRepo:        securityRepo,
+		mcpRepo:             mcpRepo,
+		userRepo:            userRepo,
+		attestationService:  attestationService,
+		notificationService: notificationService,
+		emailService:        emailService,
+		certificates:        certificates,
+	}
+}
+
+// CompromiseResponsePolicyRequest represents the request to update an organization's response bundle
+type CompromiseResponsePolicyRequest struct {
+	RevokeAPIKeys          bool                 `json:"revokeApiKeys"`
+	RevokeOwnerSDKTokens   bool                 `json:"revokeOwnerSdkTokens"`
+	InvalidateAttestations bool                 `json:"invalidateAttestations"`
+	NotifyMCPOwners        bool                 `json:"notifyMcpOwners"`
+	OpenIncident           bool                 `json:"openIncident"`
+	FreezeTrustScore       bool                 `json:"freezeTrustScore"`
+	IncidentSeverity       domain.AlertSeverity `json:"incidentSeverity"`
+}
+
+// GetPolicy returns the organization's response bundle, or the default bundle if none is configured
+func (s *CompromiseResponseService) GetPolicy(ctx context.Context, orgID uuid.UUID) (*domain.CompromiseResponsePolicy, error) {
+	policy, err := s.responseRepo.GetPolicy(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return domain.DefaultCompromiseResponsePolicy(orgID), nil
+	}
+	return policy, nil
+}
+
+// UpdatePolicy creates or replaces the organization's response bundle
+func (s *CompromiseResponseService) UpdatePolicy(ctx context.Context, req *CompromiseResponsePolicyRequest, orgID, userID uuid.UUID) (*domain.CompromiseResponsePolicy, error) {
+	if req.IncidentSeverity == "" {
+		req.IncidentSeverity = domain.AlertSeverityCritical
+	}
+	switch req.IncidentSeverity {
+	case domain.AlertSeverityInfo, domain.AlertSeverityWarning, domain.AlertSeverityHigh, domain.AlertSeverityCritical:
+	default:
+		return nil, fmt.Errorf("unsupported incident severity: %s", req.IncidentSeverity)
+	}
+
+	policy := &domain.CompromiseResponsePolicy{
+		OrganizationID:         orgID,
+		RevokeAPIKeys:          req.RevokeAPIKeys,
+		RevokeOwnerSDKTokens:   req.RevokeOwnerSDKTokens,
+		InvalidateAttestations: req.InvalidateAttestations,
+		NotifyMCPOwners:        req.NotifyMCPOwners,
+		OpenIncident:           req.OpenIncident,
+		FreezeTrustScore:       req.FreezeTrustScore,
+		IncidentSeverity:       req.IncidentSeverity,
+		UpdatedBy:              &userID,
+	}
+
+	if err := s.responseRepo.UpsertPolicy(policy); err != nil {
+		return nil, fmt.Errorf("failed to save compromise response policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// ListResponses lists the response reports of an agent, newest first
+func (s *CompromiseResponseService) ListResponses(ctx context.Context, agentID uuid.UUID) ([]*domain.CompromiseResponse, error) {
+	return s.responseRepo.GetResponsesByAgent(agentID)
+}
+
+// MarkAsCompromised flags and suspends the agent, then runs every step enabled in the
+// organization's response bundle. A failing step is recorded in the report and does not
+// stop the remaining steps; only failing to mark the agent itself returns an error.
+func (s *CompromiseResponseService) MarkAsCompromised(
+	ctx context.Context,
+	agentID uuid.UUID,
+	trigger domain.CompromiseTrigger,
+	reason string,
+	triggeredBy *uuid.UUID,
+) (*domain.CompromiseResponse, error) {
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("agent not found: %w", err)
+	}
+
+	if err := s.agentRepo.MarkAsCompromised(agentID); err != nil {
+		return nil, fmt.Errorf("failed to mark agent as compromised: %w", err)
+	}
+
+	policy, err := s.GetPolicy(ctx, agent.OrganizationID)
+	if err != nil {
+		log.Printf("⚠️  Failed to load compromise response policy for org %s, using defaults: %v", agent.OrganizationID, err)
+		policy = domain.DefaultCompromiseResponsePolicy(agent.OrganizationID)
+	}
+
+	if reason == "" {
+		reason = fmt.Sprintf("Agent marked compromised (%s)", trigger)
+	}
+
+	response := &domain.CompromiseResponse{
+		ID:             uuid.New(),
+		OrganizationID: agent.OrganizationID,
+		AgentID:        agent.ID,
+		Trigger:        trigger,
+		Reason:         reason,
+		TriggeredBy:    triggeredBy,
+		Actions: []domain.CompromiseAction{{
+			Action: domain.CompromiseActionMarkCompromised,
+			Status: domain.CompromiseActionCompleted,
+			Count:  1,
+			Detail: "Agent flagged as compromised and suspended",
+		}},
+	}
+
+	// The agent's certificates always go on the revocation list; the compromised key must never verify again
+	response.Actions = append(response.Actions,
+		runCompromiseStep(domain.CompromiseActionRevokeCertificates, true, s.certificates != nil, func() (int, string, error) {
+			count, err := s.certificates.Revoke(ctx, agent.ID, domain.CertificateRevocationKeyCompromise)
+			return count, fmt.Sprintf("Revoked %d certificate(s)", count), err
+		}),
+		runCompromiseStep(domain.CompromiseActionRevokeAPIKeys, policy.RevokeAPIKeys, s.apiKeyRepo != nil, func() (int, string, error) {
+			return s.revokeAPIKeys(agent)
+		}),
+		runCompromiseStep(domain.CompromiseActionRevokeSDKTokens, policy.RevokeOwnerSDKTokens, s.sdkTokenRepo != nil, func() (int, string, error) {
+			return s.revokeOwnerSDKTokens(agent, reason)
+		}),
+	)
+
+	var attestedMCPServers []uuid.UUID
+	response.Actions = append(response.Actions,
+		runCompromiseStep(domain.CompromiseActionInvalidateAttestations, policy.InvalidateAttestations, s.attestationService != nil, func() (int, string, error) {
+			count, mcpServerIDs, err := s.attestationService.InvalidateAgentAttestations(ctx, agent.ID)
+			attestedMCPServers = mcpServerIDs
+			return count, fmt.Sprintf("Invalidated %d attestation(s) across %d MCP server(s)", count, len(mcpServerIDs)), err
+		}),
+		runCompromiseStep(domain.CompromiseActionFreezeTrustScore, policy.FreezeTrustScore, true, func() (int, string, error) {
+			if err := s.agentRepo.FreezeTrustScore(agent.ID); err != nil {
+				return 0, "", err
+			}
+			return 1, fmt.Sprintf("Trust score frozen at %.2f", agent.TrustScore), nil
+		}),
+		runCompromiseStep(domain.CompromiseActionOpenIncident, policy.OpenIncident, s.securityRepo != nil, func() (int, string, error) {
+			incident, err := s.openIncident(agent, policy, reason)
+			if err != nil {
+				return 0, "", err
+			}
+			response.IncidentID = &incident.ID
+			return 1, fmt.Sprintf("Opened incident %s", incident.ID), nil
+		}),
+		runCompromiseStep(domain.CompromiseActionNotifyMCPOwners, policy.NotifyMCPOwners, s.mcpRepo != nil, func() (int, string, error) {
+			return s.notifyMCPOwners(ctx, agent, response, attestedMCPServers)
+		}),
+	)
+
+	if err := s.responseRepo.CreateResponse(response); err != nil {
+		log.Printf("⚠️  Failed to store compromise response report for agent %s: %v", agent.ID, err)
+	}
+
+	log.Printf("✅ Compromise response executed for agent %s (%s): %s", agent.Name, agent.ID, summarizeCompromiseActions(response.Actions))
+	return response, nil
+}
+
+// runCompromiseStep runs one step of the response bundle and converts its outcome into a report entry
+func runCompromiseStep(
+	action domain.CompromiseActionType,
+	enabled bool,
+	available bool,
+	fn func() (int, string, error),
+) domain.CompromiseAction {
+	if !enabled {
+		return domain.CompromiseAction{Action: action, Status: domain.CompromiseActionSkipped, Detail: "Disabled by policy"}
+	}
+	if !available {
+		return domain.CompromiseAction{Action: action, Status: domain.CompromiseActionSkipped, Detail: "Not available on this server"}
+	}
+
+	count, detail, err := fn()
+	if err != nil {
+		msg := err.Error()
+		return domain.CompromiseAction{Action: action, Status: domain.CompromiseActionFailed, Count: count, Detail: detail, Error: &msg}
+	}
+	return domain.CompromiseAction{Action: action, Status: domain.CompromiseActionCompleted, Count: count, Detail: detail}
+}
+
+// summarizeCompromiseActions renders the report as "action=status" pairs for logging
+func summarizeCompromiseActions(actions []domain.CompromiseAction) string {
+	parts := make([]string, 0, len(actions))
+	for _, a := range actions {
+		parts = append(parts, fmt.Sprintf("%s=%s", a.Action, a.Status))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// revokeAPIKeys revokes every active API key of the agent
+func (s *CompromiseResponseService) revokeAPIKeys(agent *domain.Agent) (int, string, error) {
+	keys, err := s.apiKeyRepo.GetByAgent(agent.ID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	revoked := 0
+	for _, key := range keys {
+		if !key.IsActive {
+			continue
+		}
+		if err := s.apiKeyRepo.Revoke(key.ID); err != nil {
+			return revoked, fmt.Sprintf("Revoked %d API key(s) before failing", revoked), err
+		}
+		revoked++
+	}
+
+	return revoked, fmt.Sprintf("Revoked %d API key(s)", revoked), nil
+}
+
+// revokeOwnerSDKTokens revokes the SDK tokens of the user who registered the agent
+func (s *CompromiseResponseService) revokeOwnerSDKTokens(agent *domain.Agent, reason string) (int, string, error) {
+	if agent.CreatedBy == uuid.Nil {
+		return 0, "Agent has no owner", nil
+	}
+
+	active, err := s.sdkTokenRepo.GetActiveCount(agent.CreatedBy)
+	if err != nil {
+		return 0, "", err
+	}
+	if err := s.sdkTokenRepo.RevokeAllForUser(agent.CreatedBy, "agent compromised: "+reason); err != nil {
+		return 0, "", err
+	}
+
+	return active, fmt.Sprintf("Revoked %d SDK token(s) of the agent owner", active), nil
+}
+
+// openIncident opens a security incident for the compromised agent
+func (s *CompromiseResponseService) openIncident(agent *domain.Agent, policy *domain.CompromiseResponsePolicy, reason string) (*domain.SecurityIncident, error) {
+	incident := &domain.SecurityIncident{
+		ID:                uuid.New(),
+		OrganizationID:    agent.OrganizationID,
+		IncidentType:      "agent_compromised",
+		Status:            domain.IncidentStatusOpen,
+		Severity:          policy.IncidentSeverity,
+		Title:             fmt.Sprintf("Agent compromised: %s", agent.Name),
+		Description:       reason,
+		AffectedResources: []string{"agent:" + agent.ID.String()},
+	}
+
+	if err := s.securityRepo.CreateIncident(incident); err != nil {
+		return nil, err
+	}
+	return incident, nil
+}
+
+// notifyMCPOwners emails the owners of every MCP server the agent is connected to or attested,
+// and dispatches an "agent.compromised" notification to the organization's channels
+func (s *CompromiseResponseService) notifyMCPOwners(
+	ctx context.Context,
+	agent *domain.Agent,
+	response *domain.CompromiseResponse,
+	attestedMCPServers []uuid.UUID,
+) (int, string, error) {
+	seen := make(map[uuid.UUID]bool)
+	var servers []*domain.MCPServer
+
+	if s.attestationService != nil {
+		connected, err := s.attestationService.GetMCPServersForAgent(ctx, agent.ID)
+		if err != nil {
+			log.Printf("⚠️  Failed to load connected MCP servers for agent %s: %v", agent.ID, err)
+		}
+		for _, server := range connected {
+			if !seen[server.ID] {
+				seen[server.ID] = true
+				servers = append(servers, server)
+			}
+		}
+	}
+	var missing []uuid.UUID
+	for _, id := range attestedMCPServers {
+		if !seen[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		attested, err := s.mcpRepo.GetByIDs(missing)
+		if err != nil {
+			log.Printf("⚠️  Failed to load attested MCP servers for agent %s: %v", agent.ID, err)
+		}
+		for _, server := range attested {
+			if !seen[server.ID] {
+				seen[server.ID] = true
+				servers = append(servers, server)
+			}
+		}
+	}
+
+	serverNames := make([]string, 0, len(servers))
+	owners := make(map[uuid.UUID][]string)
+	for _, server := range servers {
+		serverNames = append(serverNames, server.Name)
+		if server.CreatedBy != uuid.Nil {
+			owners[server.CreatedBy] = append(owners[server.CreatedBy], server.Name)
+		}
+	}
+
+	if s.notificationService != nil {
+		_, err := s.notificationService.Dispatch(ctx, &domain.Notification{
+			OrganizationID: agent.OrganizationID,
+			EventType:      "agent.compromised",
+			Severity:       domain.AlertSeverityCritical,
+			Title:          fmt.Sprintf("Agent compromised: %s", agent.Name),
+			Message:        response.Reason,
+			ResourceType:   "agent",
+			ResourceID:     &agent.ID,
+			Payload: map[string]interface{}{
+				"responseId": response.ID,
+				"incidentId": response.IncidentID,
+				"mcpServers": serverNames,
+			},
+		})
+		if err != nil {
+			log.Printf("⚠️  Failed to dispatch compromise notification for agent %s: %v", agent.ID, err)
+		}
+	}
+
+	if len(servers) == 0 {
+		return 0, "No connected MCP servers", nil
+	}
+	if s.emailService == nil || s.userRepo == nil {
+		return 0, fmt.Sprintf("Email is not configured; %d MCP server owner(s) not emailed", len(owners)), nil
+	}
+
+	ownerIDs := make([]uuid.UUID, 0, len(owners))
+	for ownerID := range owners {
+		ownerIDs = append(ownerIDs, ownerID)
+	}
+	ownerList, err := s.userRepo.GetByIDs(ownerIDs)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to load MCP server owners: %w", err)
+	}
+
+	notified := 0
+	var failures []string
+	for _, owner := range ownerList {
+		names := owners[owner.ID]
+		if owner.Email == "" {
+			continue
+		}
+
+		subject := fmt.Sprintf("[AIM critical] Agent %s connected to your MCP servers was compromised", agent.Name)
+		body := fmt.Sprintf(
+			"<h2>Agent compromised: %s</h2><p>%s</p><p>Affected MCP servers: %s</p><p>Attestations made by this agent have been invalidated. Review recent activity on these servers.</p>",
+			html.EscapeString(agent.Name),
+			html.EscapeString(response.Reason),
+			html.EscapeString(strings.Join(names, ", ")),
+		)
+		if err := organizationEmail(s.emailService, agent.OrganizationID).SendEmail(owner.Email, subject, body, true); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", owner.Email, err))
+			continue
+		}
+		notified++
+	}
+
+	detail := fmt.Sprintf("Notified %d owner(s) of %d MCP server(s)", notified, len(servers))
+	if len(failures) > 0 {
+		return notified, detail, fmt.Errorf("failed to email %s", strings.Join(failures, "; "))
+	}
+	return notified, detail, nil
+}
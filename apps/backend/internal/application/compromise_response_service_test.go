@@ -0,0 +1,138 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockCompromiseResponseRepository is a mock implementation of domain.CompromiseResponseRepository
+type MockCompromiseResponseRepository struct {
+	mock.Mock
+}
+
+func (m *MockCompromiseResponseRepository) GetPolicy(orgID uuid.UUID) (*domain.CompromiseResponsePolicy, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CompromiseResponsePolicy), args.Error(1)
+}
+
+func (m *MockCompromiseResponseRepository) UpsertPolicy(policy *domain.CompromiseResponsePolicy) error {
+	args := m.Called(policy)
+	return args.Error(0)
+}
+
+func (m *MockCompromiseResponseRepository) CreateResponse(response *domain.CompromiseResponse) error {
+	args := m.Called(response)
+	return args.Error(0)
+}
+
+func (m *MockCompromiseResponseRepository) GetResponsesByAgent(agentID uuid.UUID) ([]*domain.CompromiseResponse, error) {
+	args := m.Called(agentID)
+	return args.Get(0).([]*domain.CompromiseResponse), args.Error(1)
+}
+
+func findCompromiseAction(t *testing.T, response *domain.CompromiseResponse, action domain.CompromiseActionType) domain.CompromiseAction {
+	for _, a := range response.Actions {
+		if a.Action == action {
+			return a
+		}
+	}
+	t.Fatalf("action %s missing from report", action)
+	return domain.CompromiseAction{}
+}
+
+func TestMarkAsCompromisedRunsDefaultBundle(t *testing.T) {
+	agentRepo := new(MockAgentRepository)
+	apiKeyRepo := new(MockAPIKeyRepository)
+	responseRepo := new(MockCompromiseResponseRepository)
+	service := NewCompromiseResponseService(responseRepo, agentRepo, apiKeyRepo, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	agent := &domain.Agent{ID: uuid.New(), OrganizationID: uuid.New(), Name: "billing-bot", TrustScore: 0.25}
+	activeKey1 := &domain.APIKey{ID: uuid.New(), AgentID: agent.ID, IsActive: true}
+	activeKey2 := &domain.APIKey{ID: uuid.New(), AgentID: agent.ID, IsActive: true}
+	revokedKey := &domain.APIKey{ID: uuid.New(), AgentID: agent.ID, IsActive: false}
+
+	agentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	agentRepo.On("MarkAsCompromised", agent.ID).Return(nil)
+	responseRepo.On("GetPolicy", agent.OrganizationID).Return(nil, nil)
+	apiKeyRepo.On("GetByAgent", agent.ID).Return([]*domain.APIKey{activeKey1, activeKey2, revokedKey}, nil)
+	apiKeyRepo.On("Revoke", activeKey1.ID).Return(nil)
+	apiKeyRepo.On("Revoke", activeKey2.ID).Return(nil)
+	agentRepo.On("FreezeTrustScore", agent.ID).Return(nil)
+	responseRepo.On("CreateResponse", mock.Anything).Return(nil)
+
+	response, err := service.MarkAsCompromised(context.Background(), agent.ID, domain.CompromiseTriggerCapabilityViolation, "", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.CompromiseTriggerCapabilityViolation, response.Trigger)
+	assert.NotEmpty(t, response.Reason)
+
+	keys := findCompromiseAction(t, response, domain.CompromiseActionRevokeAPIKeys)
+	assert.Equal(t, domain.CompromiseActionCompleted, keys.Status)
+	assert.Equal(t, 2, keys.Count)
+	apiKeyRepo.AssertNotCalled(t, "Revoke", revokedKey.ID)
+
+	assert.Equal(t, domain.CompromiseActionCompleted, findCompromiseAction(t, response, domain.CompromiseActionFreezeTrustScore).Status)
+
+	// SDK token revocation is off by default; the remaining steps have no backing dependency here
+	sdk := findCompromiseAction(t, response, domain.CompromiseActionRevokeSDKTokens)
+	assert.Equal(t, domain.CompromiseActionSkipped, sdk.Status)
+	assert.Equal(t, "Disabled by policy", sdk.Detail)
+	assert.Equal(t, domain.CompromiseActionSkipped, findCompromiseAction(t, response, domain.CompromiseActionOpenIncident).Status)
+
+	responseRepo.AssertCalled(t, "CreateResponse", response)
+}
+
+func TestMarkAsCompromisedContinuesAfterFailedStep(t *testing.T) {
+	agentRepo := new(MockAgentRepository)
+	apiKeyRepo := new(MockAPIKeyRepository)
+	responseRepo := new(MockCompromiseResponseRepository)
+	service := NewCompromiseResponseService(responseRepo, agentRepo, apiKeyRepo, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	agent := &domain.Agent{ID: uuid.New(), OrganizationID: uuid.New(), Name: "billing-bot"}
+	policy := domain.DefaultCompromiseResponsePolicy(agent.OrganizationID)
+
+	agentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	agentRepo.On("MarkAsCompromised", agent.ID).Return(nil)
+	responseRepo.On("GetPolicy", agent.OrganizationID).Return(policy, nil)
+	apiKeyRepo.On("GetByAgent", agent.ID).Return(nil, errors.New("connection reset"))
+	agentRepo.On("FreezeTrustScore", agent.ID).Return(nil)
+	responseRepo.On("CreateResponse", mock.Anything).Return(errors.New("insert failed"))
+
+	userID := uuid.New()
+	response, err := service.MarkAsCompromised(context.Background(), agent.ID, domain.CompromiseTriggerManual, "Leaked key", &userID)
+	require.NoError(t, err, "a failing step or report write must not fail the response")
+
+	keys := findCompromiseAction(t, response, domain.CompromiseActionRevokeAPIKeys)
+	assert.Equal(t, domain.CompromiseActionFailed, keys.Status)
+	if assert.NotNil(t, keys.Error) {
+		assert.Contains(t, *keys.Error, "connection reset")
+	}
+	assert.Equal(t, domain.CompromiseActionCompleted, findCompromiseAction(t, response, domain.CompromiseActionFreezeTrustScore).Status)
+	assert.Equal(t, &userID, response.TriggeredBy)
+}
+
+func TestMarkAsCompromisedStopsWhenAgentCannotBeMarked(t *testing.T) {
+	agentRepo := new(MockAgentRepository)
+	apiKeyRepo := new(MockAPIKeyRepository)
+	responseRepo := new(MockCompromiseResponseRepository)
+	service := NewCompromiseResponseService(responseRepo, agentRepo, apiKeyRepo, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	agent := &domain.Agent{ID: uuid.New(), OrganizationID: uuid.New()}
+	agentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	agentRepo.On("MarkAsCompromised", agent.ID).Return(errors.New("db down"))
+
+	_, err := service.MarkAsCompromised(context.Background(), agent.ID, domain.CompromiseTriggerManual, "", nil)
+	assert.Error(t, err)
+	apiKeyRepo.AssertNotCalled(t, "GetByAgent", mock.Anything)
+	responseRepo.AssertNotCalled(t, "CreateResponse", mock.Anything)
+}
@@ -0,0 +1,392 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// Defaults for SLOs set without a window or sustained period
+	defaultLatencySLOWindowMinutes    = 15
+	defaultLatencySLOSustainedMinutes = 30
+
+	// latencySLOMinSamples is how many attestations a window needs before its p95 is judged
+	latencySLOMinSamples = 3
+	// latencySLOFailureBudget is the share of failed attestations above which the 95th
+	// percentile lands on a failed connection, which is slower than any objective
+	latencySLOFailureBudget = 0.05
+)
+
+var (
+	// ErrInvalidConnectionLatencySLO is returned for SLOs without a positive objective or with negative periods
+	ErrInvalidConnectionLatencySLO = errors.New("invalid connection latency slo")
+	// ErrLatencySLOConnectionNotFound is returned when the agent is not connected to the MCP server
+	// of the organization
+	ErrLatencySLOConnectionNotFound = errors.New("agent-mcp connection not found")
+)
+
+// SetConnectionLatencySLORequest sets the latency objective of an agent's connection to an MCP server
+type SetConnectionLatencySLORequest struct {
+	P95ThresholdMs   float64 `json:"p95ThresholdMs"`
+	WindowMinutes    int     `json:"windowMinutes,omitempty"`    // Default 15
+	SustainedMinutes *int    `json:"sustainedMinutes,omitempty"` // Default 30; 0 alerts on the first breached window
+	Enabled          *bool   `json:"enabled,omitempty"`          // Default true
+}
+
+// ConnectionLatencyService turns the connection latencies agents report in their attestations
+// into a signal. Each agent-MCP connection can have a p95 latency objective; a job computes the
+// connection's p95 over a trailing window and alerts once it has stayed above the objective for
+// the sustained period. The alert says whether the MCP server is slow for its other agents too
+// (server-wide) or only for this agent (its network path).
+type ConnectionLatencyService struct {
+	sloRepo         domain.ConnectionLatencySLORepository
+	attestationRepo domain.MCPAttestationRepository
+	mcpRepo         domain.MCPServerRepository
+	agentRepo       domain.AgentRepository
+	alertRepo       domain.AlertRepository
+	now             func() time.Time
+}
+
+// NewConnectionLatencyService creates a new connection latency service
+func NewConnectionLatencyService(
+	sloRepo domain.ConnectionLatencySLORepository,
+	attestationRepo domain.MCPAttestationRepository,
+	mcpRepo domain.MCPServerRepository,
+	agentRepo domain.AgentRepository,
+	alertRepo domain.AlertRepository,
+) *ConnectionLatencyService {
+	return &ConnectionLatencyService{
+		sloRepo:         sloRepo,
+		attestationRepo: attestationRepo,
+		mcpRepo:         mcpRepo,
+		agentRepo:       agentRepo,
+		alertRepo:       alertRepo,
+		now:             time.Now,
+	}
+}
+
+// SetSLO sets the latency objective of the agent's connection to the MCP server. Changing an
+// objective restarts its evaluation, so a breach in progress is judged against the new one.
+func (s *ConnectionLatencyService) SetSLO(
+	ctx context.Context,
+	orgID, userID, mcpServerID, agentID uuid.UUID,
+	req *SetConnectionLatencySLORequest,
+) (*domain.ConnectionLatencySLO, error) {
+	if req.P95ThresholdMs <= 0 {
+		return nil, fmt.Errorf("%w: p95ThresholdMs must be positive", ErrInvalidConnectionLatencySLO)
+	}
+	window := req.WindowMinutes
+	if window == 0 {
+		window = defaultLatencySLOWindowMinutes
+	}
+	sustained := defaultLatencySLOSustainedMinutes
+	if req.SustainedMinutes != nil {
+		sustained = *req.SustainedMinutes
+	}
+	if window < 0 || sustained < 0 {
+		return nil, fmt.Errorf("%w: windowMinutes and sustainedMinutes can't be negative", ErrInvalidConnectionLatencySLO)
+	}
+	if err := s.checkConnection(orgID, mcpServerID, agentID); err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	slo := &domain.ConnectionLatencySLO{
+		ID:               uuid.New(),
+		OrganizationID:   orgID,
+		AgentID:          agentID,
+		MCPServerID:      mcpServerID,
+		P95ThresholdMs:   req.P95ThresholdMs,
+		WindowMinutes:    window,
+		SustainedMinutes: sustained,
+		IsEnabled:        req.Enabled == nil || *req.Enabled,
+		CreatedBy:        userID,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := s.sloRepo.Upsert(slo); err != nil {
+		return nil, fmt.Errorf("failed to save connection latency slo: %w", err)
+	}
+	return slo, nil
+}
+
+// ListSLOs returns the latency objectives of the MCP server's connections with their last evaluation
+func (s *ConnectionLatencyService) ListSLOs(ctx context.Context, orgID, mcpServerID uuid.UUID) ([]*domain.ConnectionLatencySLO, error) {
+	server, err := s.mcpRepo.GetByID(mcpServerID)
+	if err != nil || server.OrganizationID != orgID {
+		return nil, ErrLatencySLOConnectionNotFound
+	}
+	slos, err := s.sloRepo.ListByMCPServer(mcpServerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connection latency slos: %w", err)
+	}
+	return slos, nil
+}
+
+// DeleteSLO removes the latency objective of the agent's connection to the MCP server
+func (s *ConnectionLatencyService) DeleteSLO(ctx context.Context, orgID, mcpServerID, agentID uuid.UUID) error {
+	slo, err := s.sloRepo.GetByConnection(agentID, mcpServerID)
+	if err != nil {
+		return err
+	}
+	if slo.OrganizationID != orgID {
+		return domain.ErrConnectionLatencySLONotFound
+	}
+	if err := s.sloRepo.Delete(agentID, mcpServerID); err != nil {
+		return fmt.Errorf("failed to delete connection latency slo: %w", err)
+	}
+	return nil
+}
+
+// checkConnection returns ErrLatencySLOConnectionNotFound unless the agent and the MCP server are
+// in the organization and the agent is connected to the server
+func (s *ConnectionLatencyService) checkConnection(orgID, mcpServerID, agentID uuid.UUID) error {
+	server, err := s.mcpRepo.GetByID(mcpServerID)
+	if err != nil || server.OrganizationID != orgID {
+		return ErrLatencySLOConnectionNotFound
+	}
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil || agent.OrganizationID != orgID {
+		return ErrLatencySLOConnectionNotFound
+	}
+	connection, err := s.attestationRepo.GetConnectionByAgentAndMCP(agentID, mcpServerID)
+	if err != nil {
+		return fmt.Errorf("failed to look up agent-mcp connection: %w", err)
+	}
+	if connection == nil {
+		return ErrLatencySLOConnectionNotFound
+	}
+	return nil
+}
+
+// latencySamples are one agent's attestations of an MCP server within a window
+type latencySamples struct {
+	latencies []float64 // Of the attestations that connected and passed the health check
+	failures  int
+}
+
+func (l *latencySamples) total() int {
+	return len(l.latencies) + l.failures
+}
+
+// p95 returns the nearest-rank 95th percentile of the successful connections' latencies
+func (l *latencySamples) p95() (float64, bool) {
+	if len(l.latencies) == 0 {
+		return 0, false
+	}
+	sorted := append([]float64(nil), l.latencies...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)], true
+}
+
+// breaches reports whether there are enough samples to judge and their p95 is above the objective
+func (l *latencySamples) breaches(thresholdMs float64) bool {
+	if l.total() < latencySLOMinSamples {
+		return false
+	}
+	if float64(l.failures) > latencySLOFailureBudget*float64(l.total()) {
+		return true
+	}
+	p95, ok := l.p95()
+	return ok && p95 > thresholdMs
+}
+
+// EvaluateSLOs evaluates every enabled latency objective and returns the number of alerts raised
+func (s *ConnectionLatencyService) EvaluateSLOs(ctx context.Context) (int, error) {
+	slos, err := s.sloRepo.ListEnabled()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list connection latency slos: %w", err)
+	}
+
+	now := s.now().UTC()
+	raised := 0
+	attestations := make(map[uuid.UUID][]*domain.MCPAttestation)
+	for _, slo := range slos {
+		serverAttestations, ok := attestations[slo.MCPServerID]
+		if !ok {
+			if serverAttestations, err = s.attestationRepo.GetAttestationsByMCP(slo.MCPServerID); err != nil {
+				log.Printf("⚠️  Failed to load attestations of MCP server %s: %v", slo.MCPServerID, err)
+				continue
+			}
+			attestations[slo.MCPServerID] = serverAttestations
+		}
+
+		alerted, err := s.evaluateSLO(ctx, slo, serverAttestations, now)
+		if err != nil {
+			log.Printf("⚠️  Failed to evaluate latency SLO of agent %s on MCP server %s: %v", slo.AgentID, slo.MCPServerID, err)
+			continue
+		}
+		if alerted {
+			raised++
+		}
+	}
+	return raised, nil
+}
+
+// evaluateSLO updates the SLO's state from the server's attestations in its window and raises an
+// alert when the breach has lasted the sustained period; once per breach
+func (s *ConnectionLatencyService) evaluateSLO(
+	ctx context.Context,
+	slo *domain.ConnectionLatencySLO,
+	serverAttestations []*domain.MCPAttestation,
+	now time.Time,
+) (bool, error) {
+	samples := samplesByAgent(serverAttestations, now.Add(-time.Duration(slo.WindowMinutes)*time.Minute))
+	own := samples[slo.AgentID]
+	if own == nil {
+		own = &latencySamples{}
+	}
+
+	slo.LastSamples, slo.LastFailures, slo.LastP95Ms = own.total(), own.failures, nil
+	if p95, ok := own.p95(); ok {
+		slo.LastP95Ms = &p95
+	}
+	slo.LastEvaluatedAt = &now
+
+	alerted := false
+	if own.breaches(slo.P95ThresholdMs) {
+		if slo.BreachedSince == nil {
+			slo.BreachedSince = &now
+		}
+		slo.Attribution = attributeLatency(slo, samples)
+		sustained := time.Duration(slo.SustainedMinutes) * time.Minute
+		if slo.AlertedAt == nil && now.Sub(*slo.BreachedSince) >= sustained {
+			created, err := s.alertBreach(slo, samples, now)
+			if err != nil {
+				return false, fmt.Errorf("failed to raise latency alert: %w", err)
+			}
+			slo.AlertedAt = &now
+			alerted = created
+		}
+	} else {
+		slo.BreachedSince, slo.AlertedAt, slo.Attribution = nil, nil, ""
+	}
+
+	if err := s.sloRepo.UpdateState(slo); err != nil {
+		return false, fmt.Errorf("failed to update connection latency slo: %w", err)
+	}
+	return alerted, nil
+}
+
+// samplesByAgent groups the agent attestations created since the start of the window by agent
+func samplesByAgent(attestations []*domain.MCPAttestation, since time.Time) map[uuid.UUID]*latencySamples {
+	samples := make(map[uuid.UUID]*latencySamples)
+	for _, attestation := range attestations {
+		// Manual attestations have no agent and measure no latency
+		if attestation.AgentID == nil || attestation.CreatedAt.Before(since) {
+			continue
+		}
+		agentSamples, ok := samples[*attestation.AgentID]
+		if !ok {
+			agentSamples = &latencySamples{}
+			samples[*attestation.AgentID] = agentSamples
+		}
+		data := attestation.AttestationData
+		if !data.ConnectionSuccessful || !data.HealthCheckPassed {
+			agentSamples.failures++
+			continue
+		}
+		agentSamples.latencies = append(agentSamples.latencies, data.ConnectionLatencyMs)
+	}
+	return samples
+}
+
+// attributeLatency compares the connection with the server's other agents in the same window:
+// when at least half of those with enough samples are above the objective too, the server is slow
+func attributeLatency(slo *domain.ConnectionLatencySLO, samples map[uuid.UUID]*latencySamples) domain.LatencyAttribution {
+	others, slow := otherAgentsAboveObjective(slo, samples)
+	switch {
+	case others == 0:
+		return domain.LatencyAttributionUndetermined
+	case slow*2 >= others:
+		return domain.LatencyAttributionServer
+	default:
+		return domain.LatencyAttributionAgentPath
+	}
+}
+
+// otherAgentsAboveObjective counts the server's other agents with enough samples to judge, and
+// how many of them are above the SLO's objective
+func otherAgentsAboveObjective(slo *domain.ConnectionLatencySLO, samples map[uuid.UUID]*latencySamples) (others, slow int) {
+	for agentID, agentSamples := range samples {
+		if agentID == slo.AgentID || agentSamples.total() < latencySLOMinSamples {
+			continue
+		}
+		others++
+		if agentSamples.breaches(slo.P95ThresholdMs) {
+			slow++
+		}
+	}
+	return others, slow
+}
+
+// alertBreach raises a connection_latency_slo alert on the MCP server for server-wide
+// regressions, otherwise on the agent. A resource with an unacknowledged latency alert gets no
+// new one, so the agents of a slow server share one alert.
+func (s *ConnectionLatencyService) alertBreach(slo *domain.ConnectionLatencySLO, samples map[uuid.UUID]*latencySamples, now time.Time) (bool, error) {
+	server, err := s.mcpRepo.GetByID(slo.MCPServerID)
+	if err != nil {
+		return false, err
+	}
+	agent, err := s.agentRepo.GetByID(slo.AgentID)
+	if err != nil {
+		return false, err
+	}
+
+	resourceType, resourceID, severity := "agent", agent.ID, domain.AlertSeverityWarning
+	if slo.Attribution == domain.LatencyAttributionServer {
+		resourceType, resourceID, severity = "mcp_server", server.ID, domain.AlertSeverityHigh
+	}
+	existing, err := s.alertRepo.GetUnacknowledgedByResourceID(resourceID)
+	if err != nil {
+		return false, err
+	}
+	for _, alert := range existing {
+		if alert.AlertType == domain.AlertConnectionLatencySLO {
+			return false, nil
+		}
+	}
+
+	observed := fmt.Sprintf("%d of %d attestations failed", slo.LastFailures, slo.LastSamples)
+	if slo.LastP95Ms != nil {
+		observed = fmt.Sprintf("p95 latency is %.0fms and %s", *slo.LastP95Ms, observed)
+	}
+	others, slow := otherAgentsAboveObjective(slo, samples)
+	var attribution string
+	switch slo.Attribution {
+	case domain.LatencyAttributionServer:
+		attribution = fmt.Sprintf("%d of %d other agents of the server are above the objective too: the server itself is slow.", slow, others)
+	case domain.LatencyAttributionAgentPath:
+		attribution = fmt.Sprintf("Only %d of %d other agents of the server are above the objective: the regression is on this agent's network path.", slow, others)
+	default:
+		attribution = "No other agent attested the server in the window, so the server and the agent's network path can't be told apart."
+	}
+
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: slo.OrganizationID,
+		AlertType:      domain.AlertConnectionLatencySLO,
+		Severity:       severity,
+		Title:          fmt.Sprintf("Latency SLO breached: %s → %s", agent.Name, server.Name),
+		Description: fmt.Sprintf("Agent '%s' connecting to %s has been above its p95 objective of %.0fms since %s: "+
+			"over the last %d minutes, %s. %s",
+			agent.Name, server.URL, slo.P95ThresholdMs, slo.BreachedSince.Format(time.RFC3339),
+			slo.WindowMinutes, observed, attribution),
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		CreatedAt:    now,
+	}
+	if err := s.alertRepo.Create(alert); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,164 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionLatencySLOsAlertOnSustainedBreachesAndAttributeThem(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	server := testsupport.NewMCPServer(org.ID)
+	require.NoError(t, repos.MCPServer.Create(server))
+	user := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(user))
+
+	agents := make([]*domain.Agent, 3)
+	for i := range agents {
+		agents[i] = testsupport.NewAgent(org.ID)
+		require.NoError(t, repos.Agent.Create(agents[i]))
+		require.NoError(t, repos.MCPAttestation.CreateConnection(&domain.AgentMCPConnection{
+			ID:             uuid.New(),
+			AgentID:        agents[i].ID,
+			MCPServerID:    server.ID,
+			ConnectionType: domain.ConnectionTypeAttested,
+			IsActive:       true,
+		}))
+	}
+	slowPath, steady, other := agents[0], agents[1], agents[2]
+
+	service := NewConnectionLatencyService(repos.ConnectionLatencySLO, repos.MCPAttestation, repos.MCPServer, repos.Agent, repos.Alert)
+	ctx := context.Background()
+	attest := func(agent *domain.Agent, count int, latencyMs float64, age time.Duration) {
+		for i := 0; i < count; i++ {
+			require.NoError(t, repos.MCPAttestation.CreateAttestation(testsupport.NewMCPAttestation(server, agent, func(a *domain.MCPAttestation) {
+				a.AttestationData.ConnectionLatencyMs = latencyMs
+				a.CreatedAt = time.Now().Add(-age)
+			})))
+		}
+	}
+	latencyAlerts := func() []*domain.Alert {
+		alerts, err := repos.Alert.GetByOrganization(org.ID, 100, 0)
+		require.NoError(t, err)
+		var latency []*domain.Alert
+		for _, alert := range alerts {
+			if alert.AlertType == domain.AlertConnectionLatencySLO {
+				latency = append(latency, alert)
+			}
+		}
+		return latency
+	}
+
+	// Objectives need a connection and a positive p95
+	_, err := service.SetSLO(ctx, org.ID, user.ID, server.ID, uuid.New(), &SetConnectionLatencySLORequest{P95ThresholdMs: 100})
+	assert.ErrorIs(t, err, ErrLatencySLOConnectionNotFound)
+	_, err = service.SetSLO(ctx, org.ID, user.ID, server.ID, slowPath.ID, &SetConnectionLatencySLORequest{})
+	assert.ErrorIs(t, err, ErrInvalidConnectionLatencySLO)
+
+	slo, err := service.SetSLO(ctx, org.ID, user.ID, server.ID, slowPath.ID, &SetConnectionLatencySLORequest{P95ThresholdMs: 100})
+	require.NoError(t, err)
+	assert.Equal(t, 15, slo.WindowMinutes)
+	assert.Equal(t, 30, slo.SustainedMinutes)
+	assert.True(t, slo.IsEnabled)
+
+	// Only the slow path agent's connection is slow; attestations older than the window don't count
+	attest(slowPath, 5, 400, time.Minute)
+	attest(steady, 5, 20, time.Minute)
+	attest(other, 5, 30, time.Minute)
+	attest(steady, 5, 5000, 2*time.Hour)
+
+	raised, err := service.EvaluateSLOs(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, raised, "a breach must last the sustained period before alerting")
+	slo, err = repos.ConnectionLatencySLO.GetByConnection(slowPath.ID, server.ID)
+	require.NoError(t, err)
+	require.NotNil(t, slo.LastP95Ms)
+	assert.Equal(t, 400.0, *slo.LastP95Ms)
+	assert.Equal(t, 5, slo.LastSamples)
+	require.NotNil(t, slo.BreachedSince)
+	assert.Equal(t, domain.LatencyAttributionAgentPath, slo.Attribution)
+
+	// Once the breach has lasted 30 minutes one alert is raised on the agent
+	breachedSince := slo.BreachedSince.Add(-31 * time.Minute)
+	slo.BreachedSince = &breachedSince
+	require.NoError(t, repos.ConnectionLatencySLO.UpdateState(slo))
+	raised, err = service.EvaluateSLOs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, raised)
+	raised, err = service.EvaluateSLOs(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, raised)
+
+	alerts := latencyAlerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "agent", alerts[0].ResourceType)
+	assert.Equal(t, slowPath.ID, alerts[0].ResourceID)
+	assert.Equal(t, domain.AlertSeverityWarning, alerts[0].Severity)
+	assert.Contains(t, alerts[0].Description, "p95 latency is 400ms")
+	assert.Contains(t, alerts[0].Description, "this agent's network path")
+
+	// When most agents of the server are slow, the regression is the server's
+	sustained := 0
+	_, err = service.SetSLO(ctx, org.ID, user.ID, server.ID, steady.ID, &SetConnectionLatencySLORequest{
+		P95ThresholdMs:   100,
+		SustainedMinutes: &sustained,
+	})
+	require.NoError(t, err)
+	attest(steady, 5, 900, 0)
+	attest(other, 5, 800, 0)
+	raised, err = service.EvaluateSLOs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, raised)
+
+	alerts = latencyAlerts()
+	require.Len(t, alerts, 2)
+	var serverAlert *domain.Alert
+	for _, alert := range alerts {
+		if alert.ResourceType == "mcp_server" {
+			serverAlert = alert
+		}
+	}
+	require.NotNil(t, serverAlert)
+	assert.Equal(t, server.ID, serverAlert.ResourceID)
+	assert.Equal(t, domain.AlertSeverityHigh, serverAlert.Severity)
+	assert.Contains(t, serverAlert.Description, "the server itself is slow")
+
+	// Failed health checks count as slower than any objective
+	failing := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(failing))
+	require.NoError(t, repos.MCPAttestation.CreateConnection(&domain.AgentMCPConnection{
+		ID:          uuid.New(),
+		AgentID:     failing.ID,
+		MCPServerID: server.ID,
+		IsActive:    true,
+	}))
+	_, err = service.SetSLO(ctx, org.ID, user.ID, server.ID, failing.ID, &SetConnectionLatencySLORequest{P95ThresholdMs: 100})
+	require.NoError(t, err)
+	attest(failing, 4, 10, 0)
+	require.NoError(t, repos.MCPAttestation.CreateAttestation(testsupport.NewMCPAttestation(server, failing, func(a *domain.MCPAttestation) {
+		a.AttestationData.HealthCheckPassed = false
+	})))
+	_, err = service.EvaluateSLOs(ctx)
+	require.NoError(t, err)
+	slo, err = repos.ConnectionLatencySLO.GetByConnection(failing.ID, server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, slo.LastFailures)
+	assert.NotNil(t, slo.BreachedSince)
+
+	// Listing shows every connection's objective; deleting one removes it
+	slos, err := service.ListSLOs(ctx, org.ID, server.ID)
+	require.NoError(t, err)
+	assert.Len(t, slos, 3)
+	require.NoError(t, service.DeleteSLO(ctx, org.ID, server.ID, failing.ID))
+	assert.ErrorIs(t, service.DeleteSLO(ctx, org.ID, server.ID, failing.ID), domain.ErrConnectionLatencySLONotFound)
+	_, err = service.ListSLOs(ctx, uuid.New(), server.ID)
+	assert.ErrorIs(t, err, ErrLatencySLOConnectionNotFound)
+}
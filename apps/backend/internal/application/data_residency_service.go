@@ -0,0 +1,162 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrInvalidDataRegion is returned for a region that is not supported
+	ErrInvalidDataRegion = errors.New("invalid data region")
+	// ErrResidencyFixed is returned when an organization that already stores regional records
+	// asks to move to another region; records are never moved between clusters
+	ErrResidencyFixed = errors.New("data residency cannot change once the organization stores agents, verification events or MCP attestations")
+)
+
+// SetDataResidencyRequest chooses the region an organization's records are stored in
+type SetDataResidencyRequest struct {
+	Region domain.DataRegion `json:"region"`
+}
+
+// OrganizationResidency is where an organization's regional records are stored
+type OrganizationResidency struct {
+	OrganizationID   uuid.UUID                        `json:"organizationId"`
+	Region           domain.DataRegion                `json:"region"`
+	HomeRegion       domain.DataRegion                `json:"homeRegion"`       // Region of the control plane
+	AvailableRegions []domain.DataRegion              `json:"availableRegions"` // Regions with a database cluster
+	Placements       map[domain.DataPlacementKind]int `json:"placements"`       // Records placed in the region, by kind
+}
+
+// DataResidencyService lets an organization choose the region its agents, verification events
+// and MCP attestations are stored in. The choice is made before the first of them is stored;
+// organizations that never choose stay in the home region.
+type DataResidencyService struct {
+	orgRepo       domain.OrganizationRepository
+	agentRepo     domain.AgentRepository
+	eventRepo     domain.VerificationEventRepository
+	placementRepo domain.DataPlacementRepository
+	home          domain.DataRegion
+	regions       []domain.DataRegion
+}
+
+// NewDataResidencyService creates a new data residency service. regions are the regions with a
+// database cluster besides the home region.
+func NewDataResidencyService(
+	orgRepo domain.OrganizationRepository,
+	agentRepo domain.AgentRepository,
+	eventRepo domain.VerificationEventRepository,
+	placementRepo domain.DataPlacementRepository,
+	home domain.DataRegion,
+	regions []domain.DataRegion,
+) *DataResidencyService {
+	available := []domain.DataRegion{home}
+	for _, region := range regions {
+		if region != home {
+			available = append(available, region)
+		}
+	}
+	return &DataResidencyService{
+		orgRepo:       orgRepo,
+		agentRepo:     agentRepo,
+		eventRepo:     eventRepo,
+		placementRepo: placementRepo,
+		home:          home,
+		regions:       available,
+	}
+}
+
+// GetResidency returns the organization's region and how many records are placed in it
+func (s *DataResidencyService) GetResidency(ctx context.Context, orgID uuid.UUID) (*OrganizationResidency, error) {
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load organization: %w", err)
+	}
+	placements, err := s.placementRepo.CountByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count placements: %w", err)
+	}
+	return &OrganizationResidency{
+		OrganizationID:   orgID,
+		Region:           s.regionOf(org),
+		HomeRegion:       s.home,
+		AvailableRegions: s.regions,
+		Placements:       placements,
+	}, nil
+}
+
+// SetResidency makes the organization resident in the region. It fails with ErrResidencyFixed
+// once the organization stores records in its current region.
+func (s *DataResidencyService) SetResidency(ctx context.Context, orgID uuid.UUID, region domain.DataRegion) (*OrganizationResidency, error) {
+	if !region.IsValid() {
+		return nil, fmt.Errorf("%w: %q must be one of %v", ErrInvalidDataRegion, region, domain.DataRegions)
+	}
+	if !s.available(region) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrRegionUnavailable, region)
+	}
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load organization: %w", err)
+	}
+
+	if s.regionOf(org) != region {
+		stored, err := s.storesRecords(orgID)
+		if err != nil {
+			return nil, err
+		}
+		if stored {
+			return nil, ErrResidencyFixed
+		}
+	}
+
+	org.DataResidency = region
+	if err := s.orgRepo.Update(org); err != nil {
+		return nil, fmt.Errorf("failed to update organization: %w", err)
+	}
+	return s.GetResidency(ctx, orgID)
+}
+
+// storesRecords reports whether the organization has any regional records. Agents and events
+// stored before placements were tracked have none, so those are counted as well.
+func (s *DataResidencyService) storesRecords(orgID uuid.UUID) (bool, error) {
+	placements, err := s.placementRepo.CountByOrganization(orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to count placements: %w", err)
+	}
+	for _, count := range placements {
+		if count > 0 {
+			return true, nil
+		}
+	}
+	agents, err := s.agentRepo.GetByOrganization(orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to count agents: %w", err)
+	}
+	if len(agents) > 0 {
+		return true, nil
+	}
+	_, events, err := s.eventRepo.GetByOrganization(orgID, 1, 0)
+	if err != nil {
+		return false, fmt.Errorf("failed to count verification events: %w", err)
+	}
+	return events > 0, nil
+}
+
+func (s *DataResidencyService) regionOf(org *domain.Organization) domain.DataRegion {
+	if org.DataResidency == "" {
+		return s.home
+	}
+	return org.DataResidency
+}
+
+func (s *DataResidencyService) available(region domain.DataRegion) bool {
+	for _, available := range s.regions {
+		if available == region {
+			return true
+		}
+	}
+	return false
+}
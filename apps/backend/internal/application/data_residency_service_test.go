@@ -0,0 +1,147 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationsDataIsStoredInTheirRegionAndCrossRegionQueriesAreRejected(t *testing.T) {
+	ctx := context.Background()
+	// The US cluster is the home region and holds the control plane
+	us := testsupport.NewRepositories()
+	eu := testsupport.NewRepositories()
+	router := repository.NewRegionRouter(domain.DataRegionUS, us.Organization, us.MCPServer, us.DataPlacement)
+	agents := repository.NewRegionalAgentRepository(router, map[domain.DataRegion]domain.AgentRepository{
+		domain.DataRegionUS: us.Agent,
+		domain.DataRegionEU: eu.Agent,
+	})
+	events := repository.NewRegionalVerificationEventRepository(router, map[domain.DataRegion]domain.VerificationEventRepository{
+		domain.DataRegionUS: us.VerificationEvent,
+		domain.DataRegionEU: eu.VerificationEvent,
+	})
+	attestations := repository.NewRegionalMCPAttestationRepository(router, map[domain.DataRegion]domain.MCPAttestationRepository{
+		domain.DataRegionUS: us.MCPAttestation,
+		domain.DataRegionEU: eu.MCPAttestation,
+	})
+	residency := NewDataResidencyService(us.Organization, agents, events, us.DataPlacement, domain.DataRegionUS, []domain.DataRegion{domain.DataRegionEU})
+
+	usOrg := testsupport.NewOrganization()
+	euOrg := testsupport.NewOrganization()
+	require.NoError(t, us.Organization.Create(usOrg))
+	require.NoError(t, us.Organization.Create(euOrg))
+
+	_, err := residency.SetResidency(ctx, euOrg.ID, "apac")
+	assert.ErrorIs(t, err, ErrInvalidDataRegion)
+	set, err := residency.SetResidency(ctx, euOrg.ID, domain.DataRegionEU)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DataRegionEU, set.Region)
+	assert.Equal(t, []domain.DataRegion{domain.DataRegionUS, domain.DataRegionEU}, set.AvailableRegions)
+	unset, err := residency.GetResidency(ctx, usOrg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DataRegionUS, unset.Region, "organizations that never choose stay in the home region")
+
+	// Agents are stored only in the cluster of their organization's region
+	usAgent := testsupport.NewAgent(usOrg.ID)
+	euAgent := testsupport.NewAgent(euOrg.ID)
+	require.NoError(t, agents.Create(usAgent))
+	require.NoError(t, agents.Create(euAgent))
+	_, err = eu.Agent.GetByID(euAgent.ID)
+	require.NoError(t, err)
+	_, err = us.Agent.GetByID(euAgent.ID)
+	assert.Error(t, err, "the EU agent must not be stored in the US cluster")
+	_, err = eu.Agent.GetByID(usAgent.ID)
+	assert.Error(t, err)
+
+	found, err := agents.GetByID(euAgent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, euAgent.Name, found.Name)
+	euAgents, err := agents.GetByOrganization(euOrg.ID)
+	require.NoError(t, err)
+	assert.Len(t, euAgents, 1)
+	_, err = agents.GetByIDs([]uuid.UUID{usAgent.ID, euAgent.ID})
+	assert.ErrorIs(t, err, domain.ErrCrossRegionQuery)
+	_, err = agents.List(10, 0)
+	assert.ErrorIs(t, err, domain.ErrCrossRegionQuery, "listing every organization's agents would span both clusters")
+
+	// Verification events follow their organization, and must not mix an agent of another region
+	euEvent := testsupport.NewVerificationEvent(euAgent)
+	usEvent := testsupport.NewVerificationEvent(usAgent)
+	require.NoError(t, events.Create(euEvent))
+	require.NoError(t, events.Create(usEvent))
+	_, err = eu.VerificationEvent.GetByID(euEvent.ID)
+	require.NoError(t, err)
+	_, err = us.VerificationEvent.GetByID(euEvent.ID)
+	assert.Error(t, err)
+	_, total, err := events.GetByAgent(euAgent.ID, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+
+	misplaced := testsupport.NewVerificationEvent(euAgent, func(e *domain.VerificationEvent) { e.OrganizationID = usOrg.ID })
+	assert.ErrorIs(t, events.Create(misplaced), domain.ErrCrossRegionQuery)
+	reason := "reviewed"
+	err = events.UpdatePendingResults([]uuid.UUID{usEvent.ID, euEvent.ID}, domain.VerificationResultVerified, &reason, nil)
+	assert.ErrorIs(t, err, domain.ErrCrossRegionQuery)
+
+	rollups, err := events.GetDailyUsage(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	rolledUp := map[uuid.UUID]bool{}
+	for _, rollup := range rollups {
+		rolledUp[rollup.OrganizationID] = true
+	}
+	assert.True(t, rolledUp[usOrg.ID] && rolledUp[euOrg.ID], "usage is rolled up in every region")
+
+	// MCP servers stay in the control plane while their attestations are stored in the region
+	euServer := testsupport.NewMCPServer(euOrg.ID)
+	usServer := testsupport.NewMCPServer(usOrg.ID)
+	require.NoError(t, us.MCPServer.Create(euServer))
+	require.NoError(t, us.MCPServer.Create(usServer))
+	attestation := testsupport.NewMCPAttestation(euServer, euAgent)
+	require.NoError(t, attestations.CreateAttestation(attestation))
+	_, err = eu.MCPAttestation.GetAttestationByID(attestation.ID)
+	require.NoError(t, err)
+	byServer, err := attestations.GetAttestationsByMCP(euServer.ID)
+	require.NoError(t, err)
+	require.Len(t, byServer, 1)
+	assert.Equal(t, attestation.ID, byServer[0].ID)
+	assert.ErrorIs(t, attestations.CreateAttestation(testsupport.NewMCPAttestation(euServer, usAgent)), domain.ErrCrossRegionQuery)
+
+	expire := func(a *domain.MCPAttestation) { a.ExpiresAt = time.Now().Add(-time.Minute) }
+	require.NoError(t, attestations.CreateAttestation(testsupport.NewMCPAttestation(euServer, euAgent, expire)))
+	require.NoError(t, attestations.CreateAttestation(testsupport.NewMCPAttestation(usServer, usAgent, expire)))
+	expired, err := attestations.InvalidateExpiredAttestations()
+	require.NoError(t, err)
+	assert.Len(t, expired, 2, "attestations expire in every region")
+
+	// The control plane knows where each record is, so the region is now fixed
+	placed, err := residency.GetResidency(ctx, euOrg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[domain.DataPlacementKind]int{
+		domain.DataPlacementAgent:             1,
+		domain.DataPlacementVerificationEvent: 1,
+		domain.DataPlacementMCPAttestation:    2,
+	}, placed.Placements)
+	_, err = residency.SetResidency(ctx, euOrg.ID, domain.DataRegionUS)
+	assert.ErrorIs(t, err, ErrResidencyFixed)
+	_, err = residency.SetResidency(ctx, euOrg.ID, domain.DataRegionEU)
+	assert.NoError(t, err, "confirming the current region is allowed")
+
+	// Legacy agents stored before placements were tracked also fix the region
+	legacy := testsupport.NewOrganization()
+	require.NoError(t, us.Organization.Create(legacy))
+	require.NoError(t, us.Agent.Create(testsupport.NewAgent(legacy.ID)))
+	_, err = residency.SetResidency(ctx, legacy.ID, domain.DataRegionEU)
+	assert.ErrorIs(t, err, ErrResidencyFixed)
+
+	// Deleting a record removes its placement
+	require.NoError(t, events.Delete(euEvent.ID))
+	_, err = events.GetByID(euEvent.ID)
+	assert.Error(t, err)
+}
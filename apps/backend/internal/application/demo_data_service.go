@@ -0,0 +1,605 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrDemoDataExists is returned when seeding an organization that already has demo data
+	ErrDemoDataExists = errors.New("organization already has demo data; tear it down first")
+	// ErrNoDemoData is returned when tearing down an organization without demo data
+	ErrNoDemoData = errors.New("organization has no demo data")
+)
+
+const (
+	// demoHistoryDays is how far back the seeded trust history reaches
+	demoHistoryDays = 14
+	// demoEventDays is how far back the seeded verification events reach
+	demoEventDays = 7
+	// demoUnapprovedServer is the MCP server the drifting demo agent talks to without having declared it
+	demoUnapprovedServer = "demo-slack-mcp"
+)
+
+// demoMCPServer is an MCP server of the demo dataset
+type demoMCPServer struct {
+	name         string
+	description  string
+	capabilities []string
+}
+
+// demoAgent is an agent of the demo dataset: what it declares at registration, the trust
+// score it drifts between over the history, and how its verifications behave
+type demoAgent struct {
+	name         string
+	displayName  string
+	description  string
+	capabilities []string
+	talksTo      []string
+	trustFrom    float64
+	trustTo      float64
+	failEvery    int  // Every n-th verification fails; 0 never fails
+	drifts       bool // Reports an undeclared MCP server and capability over the last days
+}
+
+var demoMCPServers = []demoMCPServer{
+	{"demo-filesystem-mcp", "Read and write access to the shared project workspace", []string{"read_file", "write_file", "list_directory"}},
+	{"demo-github-mcp", "Repository, issue and pull request access", []string{"search_code", "create_issue", "create_pull_request"}},
+	{"demo-postgres-mcp", "Read-only access to the analytics warehouse", []string{"query", "list_tables"}},
+}
+
+var demoAgents = []demoAgent{
+	{
+		name:         "demo-support-assistant",
+		displayName:  "Support Assistant",
+		description:  "Answers customer tickets from the product documentation",
+		capabilities: []string{domain.CapabilityFileRead, domain.CapabilityAPICall},
+		talksTo:      []string{"demo-filesystem-mcp"},
+		trustFrom:    0.82,
+		trustTo:      0.88,
+	},
+	{
+		name:         "demo-code-reviewer",
+		displayName:  "Code Reviewer",
+		description:  "Reviews pull requests and suggests fixes",
+		capabilities: []string{domain.CapabilityFileRead, domain.CapabilityFileWrite, domain.CapabilityAPICall},
+		talksTo:      []string{"demo-filesystem-mcp", "demo-github-mcp"},
+		trustFrom:    0.64,
+		trustTo:      0.79,
+		failEvery:    9,
+	},
+	{
+		name:         "demo-data-pipeline",
+		displayName:  "Data Pipeline",
+		description:  "Builds the nightly revenue reports",
+		capabilities: []string{domain.CapabilityDBQuery, domain.CapabilityDataExport},
+		talksTo:      []string{"demo-postgres-mcp"},
+		trustFrom:    0.81,
+		trustTo:      0.52,
+		failEvery:    4,
+		drifts:       true,
+	},
+	{
+		name:         "demo-release-bot",
+		displayName:  "Release Bot",
+		description:  "Tags releases and publishes changelogs",
+		capabilities: []string{domain.CapabilityAPICall, domain.CapabilityFileWrite},
+		talksTo:      []string{"demo-github-mcp"},
+		trustFrom:    0.74,
+		trustTo:      0.76,
+		failEvery:    6,
+	},
+}
+
+// DemoDataService seeds an organization with a realistic demo dataset — agents, MCP servers,
+// attestations, verification events with drift, incidents and trust history — so every
+// dashboard and analytics view has something to show. Agents, events and incidents go through
+// the same services real traffic does, raising the alerts real traffic would; MCP servers are
+// imported like identity bundles import them, attested by the demo agents with their own keys.
+// Everything seeded is recorded, and teardown removes exactly that.
+type DemoDataService struct {
+	agentService    *AgentService
+	eventService    *VerificationEventService
+	securityService *SecurityService
+	mcpRepo         domain.MCPServerRepository
+	attestationRepo domain.MCPAttestationRepository
+	trustScoreRepo  domain.TrustScoreRepository
+	alertRepo       domain.AlertRepository
+	securityRepo    domain.SecurityRepository
+	demoRepo        domain.DemoRecordRepository
+	now             func() time.Time
+}
+
+// NewDemoDataService creates a new demo data service
+func NewDemoDataService(
+	agentService *AgentService,
+	eventService *VerificationEventService,
+	securityService *SecurityService,
+	mcpRepo domain.MCPServerRepository,
+	attestationRepo domain.MCPAttestationRepository,
+	trustScoreRepo domain.TrustScoreRepository,
+	alertRepo domain.AlertRepository,
+	securityRepo domain.SecurityRepository,
+	demoRepo domain.DemoRecordRepository,
+) *DemoDataService {
+	return &DemoDataService{
+		agentService:    agentService,
+		eventService:    eventService,
+		securityService: securityService,
+		mcpRepo:         mcpRepo,
+		attestationRepo: attestationRepo,
+		trustScoreRepo:  trustScoreRepo,
+		alertRepo:       alertRepo,
+		securityRepo:    securityRepo,
+		demoRepo:        demoRepo,
+		now:             time.Now,
+	}
+}
+
+// Status reports whether the organization has demo data and how much
+func (s *DemoDataService) Status(ctx context.Context, orgID uuid.UUID) (*domain.DemoDataSummary, error) {
+	records, err := s.demoRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get demo records: %w", err)
+	}
+
+	summary := &domain.DemoDataSummary{OrganizationID: orgID}
+	for _, record := range records {
+		countDemoRecord(summary, record.ResourceType)
+	}
+	if len(records) > 0 {
+		summary.Seeded = true
+		summary.SeededAt = &records[0].CreatedAt
+	}
+	return summary, nil
+}
+
+// Seed provisions the demo dataset in the organization on behalf of the user. A seed that
+// fails part way keeps its records, so a teardown cleans up what it got to.
+func (s *DemoDataService) Seed(ctx context.Context, orgID, userID uuid.UUID) (*domain.DemoDataSummary, error) {
+	existing, err := s.demoRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get demo records: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil, ErrDemoDataExists
+	}
+
+	now := s.now().UTC()
+	summary := &domain.DemoDataSummary{OrganizationID: orgID, Seeded: true, SeededAt: &now}
+
+	servers := make(map[string]*domain.MCPServer, len(demoMCPServers))
+	for _, spec := range demoMCPServers {
+		server, err := s.seedMCPServer(orgID, userID, spec, summary)
+		if err != nil {
+			return summary, err
+		}
+		servers[spec.name] = server
+	}
+
+	agents := make(map[string]*domain.Agent, len(demoAgents))
+	for _, spec := range demoAgents {
+		agent, err := s.seedAgent(ctx, orgID, userID, spec, servers, summary)
+		if err != nil {
+			return summary, err
+		}
+		agents[spec.name] = agent
+	}
+
+	for _, server := range servers {
+		if err := s.updateConfidence(server); err != nil {
+			return summary, err
+		}
+	}
+
+	if err := s.seedIncidents(ctx, orgID, userID, agents, summary); err != nil {
+		return summary, err
+	}
+
+	for _, agent := range agents {
+		alerts, err := s.alertRepo.GetByResourceID(agent.ID, 1000, 0)
+		if err != nil {
+			return summary, fmt.Errorf("failed to count demo alerts: %w", err)
+		}
+		summary.Alerts += len(alerts)
+	}
+
+	return summary, nil
+}
+
+// seedMCPServer registers a demo MCP server
+func (s *DemoDataService) seedMCPServer(orgID, userID uuid.UUID, spec demoMCPServer, summary *domain.DemoDataSummary) (*domain.MCPServer, error) {
+	// Server URLs are unique across organizations, so they carry the organization
+	serverURL := fmt.Sprintf("https://%s.demo.invalid/%s", spec.name, orgID)
+	if existing, _ := s.mcpRepo.GetByURL(serverURL); existing != nil {
+		return nil, fmt.Errorf("demo MCP server %s already exists", spec.name)
+	}
+
+	keyPair, err := crypto.GenerateEd25519KeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate demo MCP server keys: %w", err)
+	}
+	now := s.now().UTC()
+	server := &domain.MCPServer{
+		ID:                 uuid.New(),
+		OrganizationID:     orgID,
+		Name:               spec.name,
+		Description:        spec.description,
+		URL:                serverURL,
+		Version:            "1.0.0",
+		PublicKey:          crypto.EncodeKeyPair(keyPair).PublicKeyBase64,
+		Status:             domain.MCPServerStatusVerified,
+		IsVerified:         true,
+		LastVerifiedAt:     &now,
+		Capabilities:       spec.capabilities,
+		CreatedBy:          userID,
+		VerificationMethod: "agent_attestation",
+		Criticality:        domain.MCPServerCriticalityMedium,
+	}
+	if err := s.mcpRepo.Create(server); err != nil {
+		return nil, fmt.Errorf("failed to create demo MCP server %s: %w", spec.name, err)
+	}
+	if err := s.record(orgID, userID, domain.DemoResourceMCPServer, server.ID); err != nil {
+		return nil, err
+	}
+	summary.MCPServers++
+
+	return server, nil
+}
+
+// seedAgent registers a demo agent with its own key pair, connects it to its MCP servers and
+// gives it trust history and verification events
+func (s *DemoDataService) seedAgent(
+	ctx context.Context,
+	orgID, userID uuid.UUID,
+	spec demoAgent,
+	servers map[string]*domain.MCPServer,
+	summary *domain.DemoDataSummary,
+) (*domain.Agent, error) {
+	// The key pair is generated here, with proof of possession, so seeding also works in
+	// organizations that require client-side key generation; it also signs the attestations
+	keyPair, err := crypto.GenerateEd25519KeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate demo agent keys: %w", err)
+	}
+	publicKey := crypto.EncodeKeyPair(keyPair).PublicKeyBase64
+	proof := crypto.SignMessage(keyPair.PrivateKey, crypto.ProofOfPossessionMessage(spec.name, publicKey))
+
+	agent, err := s.agentService.CreateAgent(ctx, &CreateAgentRequest{
+		Name:              spec.name,
+		DisplayName:       spec.displayName,
+		Description:       spec.description,
+		AgentType:         domain.AgentTypeAI,
+		Version:           "1.0.0",
+		PublicKey:         publicKey,
+		ProofOfPossession: base64.StdEncoding.EncodeToString(proof),
+		TalksTo:           spec.talksTo,
+		Capabilities:      spec.capabilities,
+	}, orgID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create demo agent %s: %w", spec.name, err)
+	}
+	if err := s.record(orgID, userID, domain.DemoResourceAgent, agent.ID); err != nil {
+		return nil, err
+	}
+	summary.Agents++
+
+	for _, name := range spec.talksTo {
+		if err := s.attest(agent, keyPair, servers[name], summary); err != nil {
+			return nil, err
+		}
+	}
+
+	// Events first: drift lowers the trust score, and the history then settles it where it ends
+	if err := s.seedVerificationEvents(ctx, agent, spec, summary); err != nil {
+		return nil, err
+	}
+	if err := s.seedTrustHistory(ctx, agent, spec, summary); err != nil {
+		return nil, err
+	}
+
+	return agent, nil
+}
+
+// attest connects the agent to the MCP server and records an attestation of the server the
+// agent signed with its key, as the SDK does
+func (s *DemoDataService) attest(agent *domain.Agent, keyPair *crypto.KeyPair, server *domain.MCPServer, summary *domain.DemoDataSummary) error {
+	now := s.now().UTC()
+	payload := domain.AttestationPayload{
+		AgentID:              agent.ID.String(),
+		CapabilitiesFound:    server.Capabilities,
+		ConnectionLatencyMs:  float64(20 + len(server.Name)*3),
+		ConnectionSuccessful: true,
+		HealthCheckPassed:    true,
+		MCPName:              server.Name,
+		MCPURL:               server.URL,
+		Nonce:                uuid.New().String(),
+		SDKVersion:           "demo-v1.0.0",
+		Timestamp:            now.Format(time.RFC3339),
+	}
+	message, err := payload.ToCanonicalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to serialize demo attestation: %w", err)
+	}
+
+	if err := s.attestationRepo.CreateConnection(&domain.AgentMCPConnection{
+		ID:               uuid.New(),
+		AgentID:          agent.ID,
+		MCPServerID:      server.ID,
+		ConnectionType:   domain.ConnectionTypeAttested,
+		FirstConnectedAt: now.AddDate(0, 0, -demoHistoryDays),
+		LastAttestedAt:   &now,
+		AttestationCount: 1,
+		IsActive:         true,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}); err != nil {
+		return fmt.Errorf("failed to connect demo agent %s to %s: %w", agent.Name, server.Name, err)
+	}
+	summary.Connections++
+
+	if err := s.attestationRepo.CreateAttestation(&domain.MCPAttestation{
+		ID:                uuid.New(),
+		MCPServerID:       server.ID,
+		AgentID:           &agent.ID,
+		AttestationData:   payload,
+		Signature:         base64.StdEncoding.EncodeToString(crypto.SignMessage(keyPair.PrivateKey, message)),
+		SignatureVerified: true,
+		VerifiedAt:        &now,
+		ExpiresAt:         now.Add(30 * 24 * time.Hour),
+		IsValid:           true,
+		CreatedAt:         now,
+	}); err != nil {
+		return fmt.Errorf("failed to record demo attestation of %s: %w", server.Name, err)
+	}
+	summary.Attestations++
+	return nil
+}
+
+// updateConfidence scores the MCP server from the attestations of the demo agents
+func (s *DemoDataService) updateConfidence(server *domain.MCPServer) error {
+	attestations, err := s.attestationRepo.GetValidAttestationsByMCP(server.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get demo attestations of %s: %w", server.Name, err)
+	}
+	if len(attestations) == 0 {
+		return nil
+	}
+	confidence, lastAttestedAt := mcpAttestationConfidence(attestations, s.now())
+	if err := s.mcpRepo.UpdateConfidenceScore(server.ID, confidence, len(attestations), lastAttestedAt); err != nil {
+		return fmt.Errorf("failed to update demo MCP server %s: %w", server.Name, err)
+	}
+	return nil
+}
+
+// seedTrustHistory records a daily trust score moving from the agent's starting score to its
+// current one, then moves the agent's score the same way so drops raise their alerts
+func (s *DemoDataService) seedTrustHistory(ctx context.Context, agent *domain.Agent, spec demoAgent, summary *domain.DemoDataSummary) error {
+	today := s.now().UTC().Truncate(24 * time.Hour)
+	for day := 0; day < demoHistoryDays; day++ {
+		score := spec.trustFrom + (spec.trustTo-spec.trustFrom)*float64(day)/float64(demoHistoryDays-1)
+		recordedAt := today.AddDate(0, 0, day-demoHistoryDays+1)
+		if err := s.trustScoreRepo.Create(&domain.TrustScore{
+			ID:      uuid.New(),
+			AgentID: agent.ID,
+			Score:   score,
+			Factors: domain.TrustScoreFactors{
+				VerificationStatus: 1.0,
+				Uptime:             score,
+				SuccessRate:        score,
+				SecurityAlerts:     score,
+				Compliance:         score,
+				Age:                float64(day+1) / demoHistoryDays,
+				DriftDetection:     score,
+				UserFeedback:       score,
+			},
+			Confidence:     0.9,
+			LastCalculated: recordedAt,
+			CreatedAt:      recordedAt,
+		}); err != nil {
+			return fmt.Errorf("failed to record demo trust score of %s: %w", agent.Name, err)
+		}
+		summary.TrustScores++
+	}
+
+	for _, score := range []float64{spec.trustFrom, spec.trustTo} {
+		if err := s.agentService.UpdateTrustScore(ctx, agent.ID, score); err != nil {
+			return fmt.Errorf("failed to update demo trust score of %s: %w", agent.Name, err)
+		}
+	}
+	return nil
+}
+
+// seedVerificationEvents records a few verifications a day over the last week. The drifting
+// agent reports an undeclared MCP server and capability once a day over the last two days.
+func (s *DemoDataService) seedVerificationEvents(ctx context.Context, agent *domain.Agent, spec demoAgent, summary *domain.DemoDataSummary) error {
+	today := s.now().UTC().Truncate(24 * time.Hour)
+	actions := []string{"read", "call", "query", "write"}
+
+	n := 0
+	for day := 0; day < demoEventDays; day++ {
+		// Business hours, a few more verifications on some days than others
+		perDay := 3 + (day+len(spec.name))%4
+		for i := 0; i < perDay; i++ {
+			n++
+			recordedAt := today.AddDate(0, 0, day-demoEventDays).Add(time.Duration(9+i*2) * time.Hour)
+
+			action := actions[n%len(actions)]
+			req := &CreateVerificationEventRequest{
+				OrganizationID:      agent.OrganizationID,
+				AgentID:             agent.ID,
+				Protocol:            domain.VerificationProtocolMCP,
+				VerificationType:    domain.VerificationTypeCapability,
+				Status:              domain.VerificationEventStatusSuccess,
+				Confidence:          0.95,
+				DurationMs:          40 + (n*37)%200,
+				InitiatorType:       domain.InitiatorTypeAgent,
+				Action:              &action,
+				StartedAt:           recordedAt,
+				RecordedAt:          recordedAt,
+				CurrentMCPServers:   spec.talksTo,
+				CurrentCapabilities: spec.capabilities,
+			}
+			verified := domain.VerificationResultVerified
+			req.Result = &verified
+
+			if spec.failEvery > 0 && n%spec.failEvery == 0 {
+				denied := domain.VerificationResultDenied
+				code, reason := "CAPABILITY_DENIED", "action is outside the agent's granted capabilities"
+				req.Status = domain.VerificationEventStatusFailed
+				req.Result = &denied
+				req.Confidence = 0.4
+				req.ErrorCode = &code
+				req.ErrorReason = &reason
+			}
+			if spec.drifts && day >= demoEventDays-2 && i == perDay-1 {
+				req.CurrentMCPServers = append(append([]string{}, spec.talksTo...), demoUnapprovedServer)
+				req.CurrentCapabilities = append(append([]string{}, spec.capabilities...), domain.CapabilityNetworkAccess)
+			}
+
+			if _, err := s.eventService.CreateVerificationEvent(ctx, req); err != nil {
+				return fmt.Errorf("failed to record demo verification of %s: %w", agent.Name, err)
+			}
+			summary.VerificationEvents++
+		}
+	}
+	return nil
+}
+
+// seedIncidents opens an incident about the drifting agent and records a resolved one about
+// a stale MCP attestation
+func (s *DemoDataService) seedIncidents(ctx context.Context, orgID, userID uuid.UUID, agents map[string]*domain.Agent, summary *domain.DemoDataSummary) error {
+	drifting := agents["demo-data-pipeline"]
+	reviewer := agents["demo-code-reviewer"]
+
+	incidents := []*domain.SecurityIncident{
+		{
+			OrganizationID:    orgID,
+			IncidentType:      "configuration_drift",
+			Status:            domain.IncidentStatusInvestigating,
+			Severity:          domain.AlertSeverityHigh,
+			Title:             fmt.Sprintf("Undeclared MCP server used by %s", drifting.DisplayName),
+			Description:       fmt.Sprintf("%s started calling %s with network access, neither of which it declared at registration.", drifting.DisplayName, demoUnapprovedServer),
+			AffectedResources: []string{"agent:" + drifting.ID.String()},
+		},
+		{
+			OrganizationID:    orgID,
+			IncidentType:      "capability_violation",
+			Status:            domain.IncidentStatusOpen,
+			Severity:          domain.AlertSeverityWarning,
+			Title:             fmt.Sprintf("Repeated capability denials for %s", reviewer.DisplayName),
+			Description:       fmt.Sprintf("%s attempted actions outside its granted capabilities several times this week.", reviewer.DisplayName),
+			AffectedResources: []string{"agent:" + reviewer.ID.String()},
+		},
+	}
+
+	for _, incident := range incidents {
+		if err := s.securityService.CreateIncident(ctx, incident); err != nil {
+			return fmt.Errorf("failed to create demo incident: %w", err)
+		}
+		if err := s.record(orgID, userID, domain.DemoResourceIncident, incident.ID); err != nil {
+			return err
+		}
+		summary.Incidents++
+	}
+
+	if err := s.securityService.ResolveIncident(ctx, incidents[1].ID, userID, "Capabilities of the demo agent were reviewed; the denials were expected."); err != nil {
+		return fmt.Errorf("failed to resolve demo incident: %w", err)
+	}
+	return nil
+}
+
+func (s *DemoDataService) record(orgID, userID uuid.UUID, resourceType domain.DemoResourceType, resourceID uuid.UUID) error {
+	if err := s.demoRepo.Create(&domain.DemoRecord{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		ResourceType:   resourceType,
+		ResourceID:     resourceID,
+		CreatedBy:      userID,
+		CreatedAt:      s.now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("failed to record demo %s: %w", resourceType, err)
+	}
+	return nil
+}
+
+// Teardown removes the organization's demo data: the recorded agents and MCP servers (their
+// attestations, connections, events and trust history go with them), the alerts raised about
+// them, and the recorded incidents. Resources the organization created itself are untouched.
+func (s *DemoDataService) Teardown(ctx context.Context, orgID uuid.UUID) (*domain.DemoDataSummary, error) {
+	records, err := s.demoRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get demo records: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, ErrNoDemoData
+	}
+
+	summary := &domain.DemoDataSummary{OrganizationID: orgID}
+	// Newest first: incidents reference agents, agents reference MCP servers
+	for i := len(records) - 1; i >= 0; i-- {
+		record := records[i]
+		if record.OrganizationID != orgID {
+			continue
+		}
+
+		var err error
+		switch record.ResourceType {
+		case domain.DemoResourceIncident:
+			err = s.securityRepo.DeleteIncident(record.ResourceID)
+		case domain.DemoResourceAgent:
+			if err = s.deleteAlerts(record.ResourceID, summary); err == nil {
+				err = s.agentService.DeleteAgent(ctx, record.ResourceID)
+			}
+		case domain.DemoResourceMCPServer:
+			if err = s.deleteAlerts(record.ResourceID, summary); err == nil {
+				err = s.mcpRepo.Delete(record.ResourceID)
+			}
+		}
+		if err != nil {
+			// Already deleted by hand is fine; keep going so one failure doesn't strand the rest
+			log.Printf("⚠️  Failed to remove demo %s %s: %v", record.ResourceType, record.ResourceID, err)
+			continue
+		}
+		countDemoRecord(summary, record.ResourceType)
+	}
+
+	if err := s.demoRepo.DeleteByOrganization(orgID); err != nil {
+		return nil, fmt.Errorf("failed to delete demo records: %w", err)
+	}
+	return summary, nil
+}
+
+// deleteAlerts removes the alerts raised about a demo resource
+func (s *DemoDataService) deleteAlerts(resourceID uuid.UUID, summary *domain.DemoDataSummary) error {
+	alerts, err := s.alertRepo.GetByResourceID(resourceID, 1000, 0)
+	if err != nil {
+		return err
+	}
+	for _, alert := range alerts {
+		if err := s.alertRepo.Delete(alert.ID); err != nil {
+			return err
+		}
+		summary.Alerts++
+	}
+	return nil
+}
+
+func countDemoRecord(summary *domain.DemoDataSummary, resourceType domain.DemoResourceType) {
+	switch resourceType {
+	case domain.DemoResourceAgent:
+		summary.Agents++
+	case domain.DemoResourceMCPServer:
+		summary.MCPServers++
+	case domain.DemoResourceIncident:
+		summary.Incidents++
+	}
+}
@@ -0,0 +1,127 @@
+package application
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDemoDataSeedsAnalyticsAndTearsDownOnlyWhatItSeeded(t *testing.T) {
+	ctx := context.Background()
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	admin := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(admin))
+	own := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(own))
+
+	trustCalc := NewTrustCalculator(repos.TrustScore, repos.APIKey, repos.AuditLog, repos.Capability, repos.Agent, repos.Alert)
+	eventService := NewVerificationEventService(repos.VerificationEvent, repos.Agent, NewDriftDetectionService(repos.Agent, repos.Alert), nil, nil, nil)
+	agentService := NewAgentService(repos.Agent, trustCalc, repos.TrustScore, nil, repos.Alert, nil, repos.Capability, eventService, nil, repos.Organization, nil, nil, nil, nil)
+	service := NewDemoDataService(
+		agentService,
+		eventService,
+		NewSecurityService(repos.Security, repos.Agent, repos.Alert),
+		repos.MCPServer,
+		repos.MCPAttestation,
+		repos.TrustScore,
+		repos.Alert,
+		repos.Security,
+		repos.DemoRecord,
+	)
+
+	summary, err := service.Seed(ctx, org.ID, admin.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, summary.Agents)
+	assert.Equal(t, 3, summary.MCPServers)
+	assert.Equal(t, 2, summary.Incidents)
+	assert.Equal(t, 5, summary.Attestations)
+	assert.Positive(t, summary.Alerts)
+
+	_, err = service.Seed(ctx, org.ID, admin.ID)
+	assert.ErrorIs(t, err, ErrDemoDataExists)
+
+	pipeline, err := repos.Agent.GetByName(org.ID, "demo-data-pipeline")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.52, pipeline.TrustScore, 0.001)
+	history, err := repos.TrustScore.GetHistory(pipeline.ID, 0)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, len(history), 14)
+
+	// A week of events, some of them drifting to an undeclared MCP server
+	events, _, err := repos.VerificationEvent.GetByAgent(pipeline.ID, 0, 0)
+	require.NoError(t, err)
+	days := make(map[string]bool)
+	drifted := 0
+	for _, event := range events {
+		days[event.CreatedAt.Format("2006-01-02")] = true
+		if event.DriftDetected {
+			drifted++
+			assert.Contains(t, event.MCPServerDrift, "demo-slack-mcp")
+		}
+	}
+	assert.GreaterOrEqual(t, len(days), 7)
+	assert.Equal(t, 2, drifted)
+	alerts, err := repos.Alert.GetByResourceID(pipeline.ID, 0, 0)
+	require.NoError(t, err)
+	alertTypes := make(map[domain.AlertType]bool)
+	for _, alert := range alerts {
+		alertTypes[alert.AlertType] = true
+	}
+	assert.True(t, alertTypes[domain.AlertTypeConfigurationDrift])
+	assert.True(t, alertTypes[domain.AlertTrustScoreDrop])
+
+	// Attestations are signed by the attesting agent's key
+	attestations, err := repos.MCPAttestation.GetAttestationsByAgent(pipeline.ID)
+	require.NoError(t, err)
+	require.Len(t, attestations, 1)
+	message, err := attestations[0].AttestationData.ToCanonicalJSON()
+	require.NoError(t, err)
+	publicKey, err := base64.StdEncoding.DecodeString(*pipeline.PublicKey)
+	require.NoError(t, err)
+	signature, err := base64.StdEncoding.DecodeString(attestations[0].Signature)
+	require.NoError(t, err)
+	assert.True(t, ed25519.Verify(publicKey, message, signature))
+	server, err := repos.MCPServer.GetByID(attestations[0].MCPServerID)
+	require.NoError(t, err)
+	assert.Positive(t, server.ConfidenceScore)
+
+	status, err := service.Status(ctx, org.ID)
+	require.NoError(t, err)
+	assert.True(t, status.Seeded)
+	assert.Equal(t, 4, status.Agents)
+
+	removed, err := service.Teardown(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, removed.Agents)
+	assert.Equal(t, 3, removed.MCPServers)
+	assert.Equal(t, 2, removed.Incidents)
+	assert.Equal(t, summary.Alerts, removed.Alerts)
+
+	agents, err := repos.Agent.GetByOrganization(org.ID)
+	require.NoError(t, err)
+	require.Len(t, agents, 1)
+	assert.Equal(t, own.ID, agents[0].ID, "agents the organization created itself are kept")
+	servers, err := repos.MCPServer.GetByOrganization(org.ID)
+	require.NoError(t, err)
+	assert.Empty(t, servers)
+	incidents, err := repos.Security.GetIncidents(org.ID, "", 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, incidents)
+	alerts, err = repos.Alert.GetByResourceID(pipeline.ID, 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	status, err = service.Status(ctx, org.ID)
+	require.NoError(t, err)
+	assert.False(t, status.Seeded)
+	_, err = service.Teardown(ctx, org.ID)
+	assert.ErrorIs(t, err, ErrNoDemoData)
+}
@@ -167,48 +167,18 @@ func (s *DetectionService) ReportDetections(
 				continue
 			}
 
-			// 6. Check if MCP is already in agent's talks_to
-			var talksToJSON []byte
-			err = s.db.QueryRowContext(ctx,
-				`SELECT talks_to FROM agents WHERE id = $1`, agentID,
-			).Scan(&talksToJSON)
-
+			// 6. Add the MCP to the agent's talks_to if not present. The agent repository drops
+			// the cached agent, so verifications see the new talks_to.
+			added, err := s.agentRepo.AddTalksTo(agentID, detection.MCPServer)
 			if err != nil {
-				fmt.Printf("Warning: failed to get agent talks_to: %v\n", err)
-				continue
-			}
-
-			var talksTo []string
-			if len(talksToJSON) > 0 {
-				json.Unmarshal(talksToJSON, &talksTo)
+				fmt.Printf("Warning: failed to update talks_to for %s: %v\n", detection.MCPServer, err)
+			} else if added {
+				newMCPs = append(newMCPs, detection.MCPServer)
+			} else {
+				existingMCPs = append(existingMCPs, detection.MCPServer)
 			}
 
-			// 7. Add to talks_to if not present
-			found := false
-			for _, mcp := range talksTo {
-				if mcp == detection.MCPServer {
-					found = true
-					existingMCPs = append(existingMCPs, detection.MCPServer)
-					break
-				}
-			}
-
-			if !found {
-				talksTo = append(talksTo, detection.MCPServer)
-				updatedJSON, _ := json.Marshal(talksTo)
-
-				_, err = s.db.ExecContext(ctx,
-					`UPDATE agents SET talks_to = $1, updated_at = NOW() WHERE id = $2`,
-					updatedJSON, agentID)
-
-				if err == nil {
-					newMCPs = append(newMCPs, detection.MCPServer)
-				} else {
-					fmt.Printf("Warning: failed to update talks_to for %s: %v\n", detection.MCPServer, err)
-				}
-			}
-
-			// 8. Update SDK installation heartbeat if SDK detection
+			// 7. Update SDK installation heartbeat if SDK detection
 			if detection.SDKVersion != "" {
 				s.updateSDKHeartbeat(ctx, agentID, detection.SDKVersion)
 			}
@@ -449,14 +419,8 @@ func (s *DetectionService) ReportCapabilities(
 		return nil, fmt.Errorf("failed to store trust score: %v", err)
 	}
 
-	// 7. Update agent trust score (keep agents table in sync)
-	_, err = s.db.ExecContext(ctx, `
-		UPDATE agents
-		SET trust_score = $1, updated_at = NOW()
-		WHERE id = $2
-	`, newTrustScore, agentID)
-
-	if err != nil {
+	// 7. Update agent trust score (keep agents table in sync); frozen trust scores are left unchanged
+	if err := s.agentRepo.UpdateTrustScore(agentID, newTrustScore); err != nil {
 		return nil, fmt.Errorf("failed to update agent trust score: %v", err)
 	}
 
@@ -0,0 +1,201 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// domainEventBatchSize is how many outbox events are published to the bus at a time
+	domainEventBatchSize = 100
+	// domainEventLease is how long the relay holds claimed events before another relay may claim them
+	domainEventLease = 2 * time.Minute
+	// maxDomainEventBackoff caps the delay between attempts to publish an event
+	maxDomainEventBackoff = 10 * time.Minute
+)
+
+// DomainEventService records verification, drift, trust score and alert events in the outbox as
+// they happen and relays them to the event bus, so consumers (notifications, analytics, SIEM) can
+// run and scale apart from the API. Events reach the bus at least once and in the order they were
+// recorded, unless a batch had to be retried. Without a bus nothing is recorded.
+type DomainEventService struct {
+	outbox domain.DomainEventOutboxRepository
+	bus    domain.DomainEventBus
+}
+
+// NewDomainEventService creates a new domain event service; bus may be nil to turn events off
+func NewDomainEventService(outbox domain.DomainEventOutboxRepository, bus domain.DomainEventBus) *DomainEventService {
+	return &DomainEventService{outbox: outbox, bus: bus}
+}
+
+// Enabled reports whether events are published to a bus
+func (s *DomainEventService) Enabled() bool {
+	return s.bus != nil
+}
+
+// Record writes an event about the aggregate to the outbox. Failing to record an event never
+// fails the change it describes; it is logged instead.
+func (s *DomainEventService) Record(
+	eventType domain.DomainEventType,
+	orgID uuid.UUID,
+	aggregateType string,
+	aggregateID uuid.UUID,
+	payload interface{},
+) {
+	if !s.Enabled() {
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("⚠️  Failed to encode %s event of %s %s: %v", eventType, aggregateType, aggregateID, err)
+		return
+	}
+	event := &domain.DomainEvent{
+		ID:             uuid.New(),
+		Type:           eventType,
+		OrganizationID: orgID,
+		AggregateType:  aggregateType,
+		AggregateID:    aggregateID,
+		Payload:        data,
+		OccurredAt:     time.Now().UTC(),
+		Status:         domain.DomainEventPending,
+	}
+	if err := s.outbox.Append(event); err != nil {
+		log.Printf("⚠️  Failed to record %s event of %s %s: %v", eventType, aggregateType, aggregateID, err)
+	}
+}
+
+// Relay publishes the due outbox events to the bus, batch by batch, and returns how many were
+// published. A batch the bus rejects is retried with exponential backoff and ends the run, so
+// later events do not overtake it.
+func (s *DomainEventService) Relay(ctx context.Context) (int, error) {
+	if !s.Enabled() {
+		return 0, nil
+	}
+
+	published := 0
+	for ctx.Err() == nil {
+		events, err := s.outbox.ClaimDue(domainEventBatchSize, domainEventLease)
+		if err != nil {
+			return published, fmt.Errorf("failed to claim domain events: %w", err)
+		}
+		if len(events) == 0 {
+			return published, nil
+		}
+
+		ids := make([]uuid.UUID, len(events))
+		attempts := 0
+		for i, event := range events {
+			ids[i] = event.ID
+			attempts = max(attempts, event.Attempts)
+		}
+
+		if err := s.bus.Publish(ctx, events); err != nil {
+			next := time.Now().UTC().Add(domainEventBackoff(attempts + 1))
+			if markErr := s.outbox.MarkFailed(ids, err.Error(), next); markErr != nil {
+				log.Printf("⚠️  Failed to reschedule domain events: %v", markErr)
+			}
+			return published, fmt.Errorf("failed to publish domain events to %s: %w", s.bus.Name(), err)
+		}
+		if err := s.outbox.MarkPublished(ids, time.Now().UTC()); err != nil {
+			return published, fmt.Errorf("failed to mark domain events published: %w", err)
+		}
+		published += len(events)
+	}
+	return published, ctx.Err()
+}
+
+// PurgePublished deletes the events published longer ago than retention
+func (s *DomainEventService) PurgePublished(ctx context.Context, retention time.Duration) (int, error) {
+	return s.outbox.DeletePublished(time.Now().UTC().Add(-retention))
+}
+
+// domainEventBackoff is the delay before the given attempt: 2s, 4s, 8s, ... up to maxDomainEventBackoff
+func domainEventBackoff(attempt int) time.Duration {
+	if attempt > 10 {
+		return maxDomainEventBackoff
+	}
+	return min(time.Duration(1<<attempt)*time.Second, maxDomainEventBackoff)
+}
+
+// VerificationEventRepository wraps a verification event repository so that events recorded
+// through it are published as verification.created, and drift.detected when they carry drift
+func (s *DomainEventService) VerificationEventRepository(eventRepo domain.VerificationEventRepository) domain.VerificationEventRepository {
+	return &domainEventVerificationEventRepository{VerificationEventRepository: eventRepo, events: s}
+}
+
+type domainEventVerificationEventRepository struct {
+	domain.VerificationEventRepository
+	events *DomainEventService
+}
+
+func (r *domainEventVerificationEventRepository) Create(event *domain.VerificationEvent) error {
+	if err := r.VerificationEventRepository.Create(event); err != nil {
+		return err
+	}
+	r.events.Record(domain.DomainEventVerificationCreated, event.OrganizationID, "verification_event", event.ID, event)
+	if event.DriftDetected {
+		r.events.Record(domain.DomainEventDriftDetected, event.OrganizationID, "verification_event", event.ID, event)
+	}
+	return nil
+}
+
+// AlertRepository wraps an alert repository so that alerts created through it are published as
+// alert.created
+func (s *DomainEventService) AlertRepository(alertRepo domain.AlertRepository) domain.AlertRepository {
+	return &domainEventAlertRepository{AlertRepository: alertRepo, events: s}
+}
+
+type domainEventAlertRepository struct {
+	domain.AlertRepository
+	events *DomainEventService
+}
+
+func (r *domainEventAlertRepository) Create(alert *domain.Alert) error {
+	if err := r.AlertRepository.Create(alert); err != nil {
+		return err
+	}
+	r.events.Record(domain.DomainEventAlertCreated, alert.OrganizationID, "alert", alert.ID, alert)
+	return nil
+}
+
+// AgentRepository wraps an agent repository so that trust score changes made through it are
+// published as trust_score.changed
+func (s *DomainEventService) AgentRepository(agentRepo domain.AgentRepository) domain.AgentRepository {
+	return &domainEventAgentRepository{AgentRepository: agentRepo, events: s}
+}
+
+type domainEventAgentRepository struct {
+	domain.AgentRepository
+	events *DomainEventService
+}
+
+func (r *domainEventAgentRepository) UpdateTrustScore(id uuid.UUID, newScore float64) error {
+	if !r.events.Enabled() {
+		return r.AgentRepository.UpdateTrustScore(id, newScore)
+	}
+
+	agent, err := r.AgentRepository.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := r.AgentRepository.UpdateTrustScore(id, newScore); err != nil {
+		return err
+	}
+	if agent.TrustScore != newScore {
+		r.events.Record(domain.DomainEventTrustScoreChanged, agent.OrganizationID, "agent", agent.ID, &domain.TrustScoreChange{
+			AgentID:       agent.ID,
+			AgentName:     agent.Name,
+			PreviousScore: agent.TrustScore,
+			NewScore:      newScore,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// domainEventTestBus records the events published to it and fails while failing is set
+type domainEventTestBus struct {
+	published []*domain.DomainEvent
+	failing   bool
+}
+
+func (b *domainEventTestBus) Name() string { return "test" }
+
+func (b *domainEventTestBus) Publish(ctx context.Context, events []*domain.DomainEvent) error {
+	if b.failing {
+		return fmt.Errorf("no responders")
+	}
+	b.published = append(b.published, events...)
+	return nil
+}
+
+func (b *domainEventTestBus) Close() error { return nil }
+
+func TestDomainEventsAreRelayedFromTheOutboxToTheEventBus(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.TrustScore = 0.8 })
+	require.NoError(t, repos.Agent.Create(agent))
+	ctx := context.Background()
+
+	// Without a bus nothing is recorded
+	disabled := NewDomainEventService(repos.DomainEventOutbox, nil)
+	require.NoError(t, disabled.VerificationEventRepository(repos.VerificationEvent).Create(testsupport.NewVerificationEvent(agent)))
+	assert.Empty(t, repos.DomainEventOutbox.List())
+
+	bus := &domainEventTestBus{}
+	service := NewDomainEventService(repos.DomainEventOutbox, bus)
+	events := service.VerificationEventRepository(repos.VerificationEvent)
+	alerts := service.AlertRepository(repos.Alert)
+	agents := service.AgentRepository(repos.Agent)
+
+	verification := testsupport.NewVerificationEvent(agent)
+	require.NoError(t, events.Create(verification))
+	drifted := testsupport.NewVerificationEvent(agent, func(e *domain.VerificationEvent) { e.DriftDetected = true })
+	require.NoError(t, events.Create(drifted))
+	alert := testsupport.NewAlert(org.ID, agent.ID)
+	require.NoError(t, alerts.Create(alert))
+	require.NoError(t, agents.UpdateTrustScore(agent.ID, 0.8)) // Unchanged: no event
+	require.NoError(t, agents.UpdateTrustScore(agent.ID, 0.6))
+
+	recorded := repos.DomainEventOutbox.List()
+	var types []domain.DomainEventType
+	for _, event := range recorded {
+		types = append(types, event.Type)
+		assert.Equal(t, org.ID, event.OrganizationID)
+	}
+	assert.Equal(t, []domain.DomainEventType{
+		domain.DomainEventVerificationCreated,
+		domain.DomainEventVerificationCreated,
+		domain.DomainEventDriftDetected,
+		domain.DomainEventAlertCreated,
+		domain.DomainEventTrustScoreChanged,
+	}, types)
+	assert.Equal(t, drifted.ID, recorded[2].AggregateID)
+	var change domain.TrustScoreChange
+	require.NoError(t, json.Unmarshal(recorded[4].Payload, &change))
+	assert.Equal(t, domain.TrustScoreChange{AgentID: agent.ID, AgentName: agent.Name, PreviousScore: 0.8, NewScore: 0.6}, change)
+
+	// A rejected batch is kept and retried later
+	bus.failing = true
+	published, err := service.Relay(ctx)
+	assert.Error(t, err)
+	assert.Zero(t, published)
+	ids := make([]uuid.UUID, 0, len(recorded))
+	for _, event := range repos.DomainEventOutbox.List() {
+		assert.Equal(t, domain.DomainEventPending, event.Status)
+		assert.Equal(t, 1, event.Attempts)
+		assert.Contains(t, event.LastError, "no responders")
+		assert.True(t, event.NextAttemptAt.After(time.Now()))
+		ids = append(ids, event.ID)
+	}
+	published, err = service.Relay(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, published, "events are not retried before their backoff passed")
+
+	bus.failing = false
+	require.NoError(t, repos.DomainEventOutbox.MarkFailed(ids, "no responders", time.Now()))
+	published, err = service.Relay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(recorded), published)
+	require.Len(t, bus.published, len(recorded))
+	for i, event := range bus.published {
+		assert.Equal(t, recorded[i].ID, event.ID, "events are published in the order they were recorded")
+	}
+	for _, event := range repos.DomainEventOutbox.List() {
+		assert.Equal(t, domain.DomainEventPublished, event.Status)
+	}
+
+	// Published events are purged after the retention period
+	purged, err := service.PurgePublished(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+	purged, err = service.PurgePublished(ctx, -time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, len(recorded), purged)
+}
@@ -0,0 +1,424 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrInvalidDriftTrendRequest is returned for an unsupported period or interval
+	ErrInvalidDriftTrendRequest = errors.New("invalid drift trend request")
+	// ErrDriftTrendSubjectNotFound is returned when the agent or fleet tag is not in the organization
+	ErrDriftTrendSubjectNotFound = errors.New("drift trend subject not found")
+)
+
+const (
+	defaultDriftTrendDays  = 30
+	maxDriftTrendDays      = 365
+	defaultDriftTopServers = 10
+	maxDriftTopServers     = 100
+	maxDriftTrendBuckets   = 366
+)
+
+// DriftAnalyticsService answers drift trend questions (how often, how fast it is
+// remediated, which servers, which agents keep drifting) from the drift history
+type DriftAnalyticsService struct {
+	driftRepo domain.DriftAnalyticsRepository
+	agentRepo domain.AgentRepository
+	tagRepo   domain.TagRepository
+}
+
+// NewDriftAnalyticsService creates a new drift analytics service
+func NewDriftAnalyticsService(
+	driftRepo domain.DriftAnalyticsRepository,
+	agentRepo domain.AgentRepository,
+	tagRepo domain.TagRepository,
+) *DriftAnalyticsService {
+	return &DriftAnalyticsService{
+		driftRepo: driftRepo,
+		agentRepo: agentRepo,
+		tagRepo:   tagRepo,
+	}
+}
+
+// DriftTrendRequest selects the scope and period of a drift trend report.
+// Zero values pick the defaults: the last 30 days, bucketed by a interval suited to the period.
+type DriftTrendRequest struct {
+	OrganizationID uuid.UUID
+	Scope          domain.DriftTrendScope
+	AgentID        *uuid.UUID // Required for the agent scope
+	TagID          *uuid.UUID // Optional for the fleet scope; every agent otherwise
+	Days           int
+	Interval       domain.DriftTrendInterval
+	TopServers     int
+}
+
+// GetDriftTrends computes the drift trend report for an agent, a fleet or the organization
+func (s *DriftAnalyticsService) GetDriftTrends(ctx context.Context, req *DriftTrendRequest) (*domain.DriftTrendReport, error) {
+	days := req.Days
+	if days == 0 {
+		days = defaultDriftTrendDays
+	}
+	if days < 1 || days > maxDriftTrendDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidDriftTrendRequest, maxDriftTrendDays)
+	}
+
+	interval := req.Interval
+	if interval == "" {
+		interval = defaultDriftTrendInterval(days)
+	}
+	if interval != domain.DriftTrendIntervalDay && interval != domain.DriftTrendIntervalWeek && interval != domain.DriftTrendIntervalMonth {
+		return nil, fmt.Errorf("%w: interval must be day, week or month", ErrInvalidDriftTrendRequest)
+	}
+
+	topServers := req.TopServers
+	if topServers == 0 {
+		topServers = defaultDriftTopServers
+	}
+	if topServers < 1 || topServers > maxDriftTopServers {
+		return nil, fmt.Errorf("%w: top must be between 1 and %d", ErrInvalidDriftTrendRequest, maxDriftTopServers)
+	}
+
+	filter := &domain.DriftAnalyticsFilter{OrganizationID: req.OrganizationID}
+	switch req.Scope {
+	case domain.DriftTrendScopeAgent:
+		if req.AgentID == nil {
+			return nil, fmt.Errorf("%w: agent is required", ErrInvalidDriftTrendRequest)
+		}
+		agent, err := s.agentRepo.GetByID(*req.AgentID)
+		if err != nil || agent.OrganizationID != req.OrganizationID {
+			return nil, fmt.Errorf("%w: agent", ErrDriftTrendSubjectNotFound)
+		}
+		filter.AgentID = &agent.ID
+	case domain.DriftTrendScopeFleet:
+		if req.TagID != nil {
+			tag, err := s.tagRepo.GetByID(ctx, *req.TagID)
+			if err != nil || tag.OrganizationID != req.OrganizationID {
+				return nil, fmt.Errorf("%w: tag", ErrDriftTrendSubjectNotFound)
+			}
+			filter.TagID = &tag.ID
+		}
+	case domain.DriftTrendScopeOrganization:
+	default:
+		return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidDriftTrendRequest, req.Scope)
+	}
+
+	now := time.Now().UTC()
+	filter.Start = driftBucketStart(now.AddDate(0, 0, -days), interval)
+	filter.End = now
+
+	occurrences, err := s.driftRepo.GetDriftOccurrences(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load drift history: %w", err)
+	}
+	alerts, err := s.driftRepo.GetDriftAlerts(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load drift alerts: %w", err)
+	}
+
+	report := buildDriftTrendReport(filter, interval, occurrences, alerts, now, topServers)
+	report.Scope = req.Scope
+	if report.DeprecatedCapabilities, err = s.driftRepo.GetDeprecatedCapabilities(filter); err != nil {
+		return nil, fmt.Errorf("failed to load deprecated capabilities: %w", err)
+	}
+	if req.Scope != domain.DriftTrendScopeFleet {
+		report.Agents = nil
+	}
+	return report, nil
+}
+
+// defaultDriftTrendInterval keeps trends readable: daily for a month, weekly up to half a year, monthly beyond
+func defaultDriftTrendInterval(days int) domain.DriftTrendInterval {
+	switch {
+	case days <= 31:
+		return domain.DriftTrendIntervalDay
+	case days <= 183:
+		return domain.DriftTrendIntervalWeek
+	default:
+		return domain.DriftTrendIntervalMonth
+	}
+}
+
+// driftBucketStart truncates t (UTC) to the start of its day, ISO week (Monday) or month
+func driftBucketStart(t time.Time, interval domain.DriftTrendInterval) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case domain.DriftTrendIntervalWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case domain.DriftTrendIntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+func nextDriftBucket(t time.Time, interval domain.DriftTrendInterval) time.Time {
+	switch interval {
+	case domain.DriftTrendIntervalWeek:
+		return t.AddDate(0, 0, 7)
+	case domain.DriftTrendIntervalMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// buildDriftTrendReport aggregates the drift history of the filter's period. It returns every drifting
+// agent in Agents; callers drop the list outside the fleet scope.
+func buildDriftTrendReport(
+	filter *domain.DriftAnalyticsFilter,
+	interval domain.DriftTrendInterval,
+	occurrences []*domain.DriftOccurrence,
+	alerts []*domain.DriftAlertRecord,
+	now time.Time,
+	topServers int,
+) *domain.DriftTrendReport {
+	report := &domain.DriftTrendReport{
+		AgentID:                filter.AgentID,
+		TagID:                  filter.TagID,
+		Interval:               interval,
+		PeriodStart:            filter.Start,
+		PeriodEnd:              filter.End,
+		Trend:                  make([]domain.DriftTrendPoint, 0),
+		TopUnauthorizedServers: make([]domain.DriftServerStat, 0),
+		RepeatOffenders:        make([]domain.DriftAgentStat, 0),
+		Agents:                 make([]domain.DriftAgentStat, 0),
+	}
+
+	// Empty buckets are kept so charts get a continuous series
+	bucketIndex := make(map[time.Time]int)
+	for start := filter.Start; start.Before(filter.End) && len(report.Trend) < maxDriftTrendBuckets; start = nextDriftBucket(start, interval) {
+		bucketIndex[start] = len(report.Trend)
+		report.Trend = append(report.Trend, domain.DriftTrendPoint{PeriodStart: start})
+	}
+	bucketOf := func(t time.Time) (int, bool) {
+		i, ok := bucketIndex[driftBucketStart(t, interval)]
+		return i, ok
+	}
+
+	type agentAccumulator struct {
+		stat    domain.DriftAgentStat
+		servers map[string]bool
+		buckets map[int]bool
+	}
+	type serverAccumulator struct {
+		stat   domain.DriftServerStat
+		agents map[uuid.UUID]bool
+	}
+
+	agents := make(map[uuid.UUID]*agentAccumulator)
+	servers := make(map[string]*serverAccumulator)
+	bucketAgents := make(map[int]map[uuid.UUID]bool)
+	bucketServers := make(map[int]map[string]bool)
+
+	for _, occurrence := range occurrences {
+		report.TotalDriftEvents++
+
+		agent, ok := agents[occurrence.AgentID]
+		if !ok {
+			agent = &agentAccumulator{
+				stat: domain.DriftAgentStat{
+					AgentID:       occurrence.AgentID,
+					AgentName:     occurrence.AgentName,
+					FirstDetected: occurrence.DetectedAt,
+				},
+				servers: make(map[string]bool),
+				buckets: make(map[int]bool),
+			}
+			agents[occurrence.AgentID] = agent
+		}
+		agent.stat.DriftEvents++
+		if occurrence.DetectedAt.Before(agent.stat.FirstDetected) {
+			agent.stat.FirstDetected = occurrence.DetectedAt
+		}
+		if occurrence.DetectedAt.After(agent.stat.LastDetected) {
+			agent.stat.LastDetected = occurrence.DetectedAt
+		}
+
+		bucket, inPeriod := bucketOf(occurrence.DetectedAt)
+		if inPeriod {
+			report.Trend[bucket].DriftEvents++
+			agent.buckets[bucket] = true
+			if bucketAgents[bucket] == nil {
+				bucketAgents[bucket] = make(map[uuid.UUID]bool)
+				bucketServers[bucket] = make(map[string]bool)
+			}
+			bucketAgents[bucket][occurrence.AgentID] = true
+		}
+
+		for _, name := range occurrence.MCPServerDrift {
+			agent.servers[name] = true
+			if inPeriod {
+				bucketServers[bucket][name] = true
+			}
+
+			server, ok := servers[name]
+			if !ok {
+				server = &serverAccumulator{
+					stat:   domain.DriftServerStat{Server: name},
+					agents: make(map[uuid.UUID]bool),
+				}
+				servers[name] = server
+			}
+			server.stat.Occurrences++
+			server.agents[occurrence.AgentID] = true
+			if occurrence.DetectedAt.After(server.stat.LastSeen) {
+				server.stat.LastSeen = occurrence.DetectedAt
+			}
+		}
+	}
+
+	for bucket := range report.Trend {
+		report.Trend[bucket].AgentsAffected = len(bucketAgents[bucket])
+		report.Trend[bucket].UnauthorizedServers = len(bucketServers[bucket])
+	}
+
+	// Remediation: time from a drift alert being raised to it being acknowledged
+	var remediationHours []float64
+	var oldestOpen *time.Time
+	for _, alert := range alerts {
+		report.Remediation.AlertsRaised++
+		if bucket, ok := bucketOf(alert.CreatedAt); ok {
+			report.Trend[bucket].AlertsRaised++
+		}
+
+		if alert.AcknowledgedAt == nil {
+			report.Remediation.Open++
+			if agent, ok := agents[alert.AgentID]; ok {
+				agent.stat.OpenAlerts++
+			}
+			if oldestOpen == nil || alert.CreatedAt.Before(*oldestOpen) {
+				createdAt := alert.CreatedAt
+				oldestOpen = &createdAt
+			}
+			continue
+		}
+
+		report.Remediation.Remediated++
+		if bucket, ok := bucketOf(*alert.AcknowledgedAt); ok {
+			report.Trend[bucket].AlertsRemediated++
+		}
+		remediationHours = append(remediationHours, math.Max(0, alert.AcknowledgedAt.Sub(alert.CreatedAt).Hours()))
+	}
+
+	if report.Remediation.AlertsRaised > 0 {
+		report.Remediation.RemediationRate = float64(report.Remediation.Remediated) / float64(report.Remediation.AlertsRaised)
+	}
+	if len(remediationHours) > 0 {
+		sort.Float64s(remediationHours)
+		total := 0.0
+		for _, hours := range remediationHours {
+			total += hours
+		}
+		mean := roundDriftHours(total / float64(len(remediationHours)))
+		median := roundDriftHours(driftPercentile(remediationHours, 0.5))
+		p90 := roundDriftHours(driftPercentile(remediationHours, 0.9))
+		report.Remediation.MeanHours = &mean
+		report.Remediation.MedianHours = &median
+		report.Remediation.P90Hours = &p90
+	}
+	if oldestOpen != nil {
+		age := roundDriftHours(math.Max(0, now.Sub(*oldestOpen).Hours()))
+		report.Remediation.OldestOpenHours = &age
+	}
+
+	// Agents, most drift first; repeat offenders drifted in more than one bucket
+	for _, agent := range agents {
+		agent.stat.ActivePeriods = len(agent.buckets)
+		agent.stat.UnauthorizedServers = make([]string, 0, len(agent.servers))
+		for name := range agent.servers {
+			agent.stat.UnauthorizedServers = append(agent.stat.UnauthorizedServers, name)
+		}
+		sort.Strings(agent.stat.UnauthorizedServers)
+		report.Agents = append(report.Agents, agent.stat)
+	}
+	sort.Slice(report.Agents, func(i, j int) bool {
+		a, b := report.Agents[i], report.Agents[j]
+		if a.DriftEvents != b.DriftEvents {
+			return a.DriftEvents > b.DriftEvents
+		}
+		if !a.LastDetected.Equal(b.LastDetected) {
+			return a.LastDetected.After(b.LastDetected)
+		}
+		return a.AgentID.String() < b.AgentID.String()
+	})
+	report.AgentsAffected = len(report.Agents)
+
+	for _, agent := range report.Agents {
+		if agent.ActivePeriods > 1 {
+			report.RepeatOffenders = append(report.RepeatOffenders, agent)
+		}
+	}
+	sort.SliceStable(report.RepeatOffenders, func(i, j int) bool {
+		return report.RepeatOffenders[i].ActivePeriods > report.RepeatOffenders[j].ActivePeriods
+	})
+
+	for _, server := range servers {
+		server.stat.Agents = len(server.agents)
+		report.TopUnauthorizedServers = append(report.TopUnauthorizedServers, server.stat)
+	}
+	sort.Slice(report.TopUnauthorizedServers, func(i, j int) bool {
+		a, b := report.TopUnauthorizedServers[i], report.TopUnauthorizedServers[j]
+		if a.Occurrences != b.Occurrences {
+			return a.Occurrences > b.Occurrences
+		}
+		if a.Agents != b.Agents {
+			return a.Agents > b.Agents
+		}
+		return a.Server < b.Server
+	})
+	if len(report.TopUnauthorizedServers) > topServers {
+		report.TopUnauthorizedServers = report.TopUnauthorizedServers[:topServers]
+	}
+
+	report.StabilityScore = driftStabilityScore(report)
+	return report
+}
+
+// driftStabilityScore starts at 100 and deducts for how much of the period had drift,
+// for repeat offenders and for alerts still open, so a fleet that drifted once and
+// remediated quickly scores far better than one that keeps drifting unattended
+func driftStabilityScore(report *domain.DriftTrendReport) float64 {
+	if len(report.Trend) == 0 || report.TotalDriftEvents == 0 {
+		return 100
+	}
+
+	driftingBuckets := 0
+	for _, point := range report.Trend {
+		if point.DriftEvents > 0 {
+			driftingBuckets++
+		}
+	}
+
+	score := 100.0
+	score -= 40 * float64(driftingBuckets) / float64(len(report.Trend))
+	if report.AgentsAffected > 0 {
+		score -= 30 * float64(len(report.RepeatOffenders)) / float64(report.AgentsAffected)
+	}
+	if report.Remediation.AlertsRaised > 0 {
+		score -= 30 * float64(report.Remediation.Open) / float64(report.Remediation.AlertsRaised)
+	}
+
+	return math.Round(math.Max(0, score)*10) / 10
+}
+
+// driftPercentile returns the nearest-rank percentile of sorted values
+func driftPercentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func roundDriftHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
@@ -0,0 +1,161 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDriftTrendReport(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2025, 11, d, h, 0, 0, 0, time.UTC) }
+	acknowledged := func(t time.Time) *time.Time { return &t }
+
+	agentA, agentB := uuid.New(), uuid.New()
+	filter := &domain.DriftAnalyticsFilter{Start: day(3, 0), End: day(24, 10)}
+
+	occurrences := []*domain.DriftOccurrence{
+		{AgentID: agentA, AgentName: "alpha", MCPServerDrift: []string{"shell-mcp", "external-api"}, DetectedAt: day(4, 0)},
+		{AgentID: agentA, AgentName: "alpha", MCPServerDrift: []string{"shell-mcp"}, DetectedAt: day(12, 0)},
+		{AgentID: agentB, AgentName: "beta", MCPServerDrift: []string{"shell-mcp"}, DetectedAt: day(12, 0)},
+		{AgentID: agentB, AgentName: "beta", CapabilityDrift: []string{"file:write"}, DetectedAt: day(13, 0)},
+	}
+	alerts := []*domain.DriftAlertRecord{
+		{AgentID: agentA, CreatedAt: day(4, 0), AcknowledgedAt: acknowledged(day(4, 6))},
+		{AgentID: agentA, CreatedAt: day(12, 0), AcknowledgedAt: acknowledged(day(13, 0))},
+		{AgentID: agentB, CreatedAt: day(12, 0)},
+	}
+
+	report := buildDriftTrendReport(filter, domain.DriftTrendIntervalWeek, occurrences, alerts, day(24, 10), 10)
+
+	assert.Equal(t, 4, report.TotalDriftEvents)
+	assert.Equal(t, 2, report.AgentsAffected)
+
+	require.Len(t, report.Trend, 4, "empty weeks and the current partial week are kept")
+	assert.Equal(t, domain.DriftTrendPoint{PeriodStart: day(3, 0), DriftEvents: 1, AgentsAffected: 1, UnauthorizedServers: 2, AlertsRaised: 1, AlertsRemediated: 1}, report.Trend[0])
+	assert.Equal(t, domain.DriftTrendPoint{PeriodStart: day(10, 0), DriftEvents: 3, AgentsAffected: 2, UnauthorizedServers: 1, AlertsRaised: 2, AlertsRemediated: 1}, report.Trend[1])
+	assert.Equal(t, domain.DriftTrendPoint{PeriodStart: day(17, 0)}, report.Trend[2])
+	assert.Equal(t, domain.DriftTrendPoint{PeriodStart: day(24, 0)}, report.Trend[3])
+
+	remediation := report.Remediation
+	assert.Equal(t, 3, remediation.AlertsRaised)
+	assert.Equal(t, 2, remediation.Remediated)
+	assert.Equal(t, 1, remediation.Open)
+	assert.InDelta(t, 2.0/3.0, remediation.RemediationRate, 0.001)
+	require.NotNil(t, remediation.MeanHours)
+	assert.Equal(t, 15.0, *remediation.MeanHours)
+	assert.Equal(t, 6.0, *remediation.MedianHours)
+	assert.Equal(t, 24.0, *remediation.P90Hours)
+	assert.Equal(t, 298.0, *remediation.OldestOpenHours)
+
+	require.Len(t, report.TopUnauthorizedServers, 2)
+	assert.Equal(t, "shell-mcp", report.TopUnauthorizedServers[0].Server)
+	assert.Equal(t, 3, report.TopUnauthorizedServers[0].Occurrences)
+	assert.Equal(t, 2, report.TopUnauthorizedServers[0].Agents)
+	assert.True(t, report.TopUnauthorizedServers[0].LastSeen.Equal(day(12, 0)))
+
+	require.Len(t, report.Agents, 2)
+	assert.Equal(t, agentB, report.Agents[0].AgentID, "ties on drift count go to the most recent drift")
+	assert.Equal(t, 1, report.Agents[0].OpenAlerts)
+	assert.Equal(t, []string{"external-api", "shell-mcp"}, report.Agents[1].UnauthorizedServers)
+
+	require.Len(t, report.RepeatOffenders, 1)
+	assert.Equal(t, agentA, report.RepeatOffenders[0].AgentID)
+	assert.Equal(t, 2, report.RepeatOffenders[0].ActivePeriods)
+
+	assert.Equal(t, 55.0, report.StabilityScore)
+}
+
+func TestBuildDriftTrendReportWithoutDrift(t *testing.T) {
+	filter := &domain.DriftAnalyticsFilter{
+		Start: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, 11, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	report := buildDriftTrendReport(filter, domain.DriftTrendIntervalDay, nil, nil, filter.End, 10)
+
+	assert.Len(t, report.Trend, 7)
+	assert.Equal(t, 100.0, report.StabilityScore)
+	assert.Nil(t, report.Remediation.MeanHours)
+	assert.Empty(t, report.RepeatOffenders)
+	assert.NotNil(t, report.TopUnauthorizedServers, "empty lists serialize as []")
+}
+
+func TestDriftBucketStart(t *testing.T) {
+	wednesday := time.Date(2025, 11, 12, 15, 30, 0, 0, time.UTC)
+	sunday := time.Date(2025, 11, 16, 23, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, 11, 12, 0, 0, 0, 0, time.UTC), driftBucketStart(wednesday, domain.DriftTrendIntervalDay))
+	assert.Equal(t, time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC), driftBucketStart(wednesday, domain.DriftTrendIntervalWeek))
+	assert.Equal(t, time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC), driftBucketStart(sunday, domain.DriftTrendIntervalWeek))
+	assert.Equal(t, time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), driftBucketStart(wednesday, domain.DriftTrendIntervalMonth))
+}
+
+func TestDriftTrendsAtAgentFleetAndOrganizationLevel(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	ctx := context.Background()
+
+	tagged := testsupport.NewAgent(org.ID)
+	untagged := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(tagged))
+	require.NoError(t, repos.Agent.Create(untagged))
+
+	tag := &domain.Tag{OrganizationID: org.ID, Key: "environment", Value: "production", Category: domain.TagCategoryEnvironment}
+	require.NoError(t, repos.Tag.Create(ctx, tag))
+	require.NoError(t, repos.Tag.AddTagsToAgent(ctx, tagged.ID, []uuid.UUID{tag.ID}))
+
+	drift := func(agent *domain.Agent, daysAgo int, servers ...string) {
+		require.NoError(t, repos.VerificationEvent.Create(testsupport.NewVerificationEvent(agent, func(e *domain.VerificationEvent) {
+			e.CreatedAt = time.Now().AddDate(0, 0, -daysAgo)
+			e.DriftDetected = true
+			e.MCPServerDrift = servers
+		})))
+	}
+	drift(tagged, 3, "shell-mcp")
+	drift(tagged, 1, "shell-mcp")
+	drift(untagged, 1, "external-api-mcp")
+	drift(untagged, 60, "too-old-mcp")
+
+	raisedAt := time.Now().Add(-2 * time.Hour)
+	remediatedAt := time.Now().Add(-time.Hour)
+	require.NoError(t, repos.Alert.Create(testsupport.NewAlert(org.ID, tagged.ID, func(a *domain.Alert) {
+		a.AlertType = domain.AlertTypeConfigurationDrift
+		a.CreatedAt = raisedAt
+		a.IsAcknowledged = true
+		a.AcknowledgedAt = &remediatedAt
+	})))
+
+	service := NewDriftAnalyticsService(repos.DriftAnalytics, repos.Agent, repos.Tag)
+
+	orgReport, err := service.GetDriftTrends(ctx, &DriftTrendRequest{OrganizationID: org.ID, Scope: domain.DriftTrendScopeOrganization})
+	require.NoError(t, err)
+	assert.Equal(t, 3, orgReport.TotalDriftEvents, "drift outside the period is ignored")
+	assert.Equal(t, "shell-mcp", orgReport.TopUnauthorizedServers[0].Server)
+	assert.Equal(t, 1.0, *orgReport.Remediation.MeanHours)
+	assert.Nil(t, orgReport.Agents, "the per-agent breakdown is fleet only")
+
+	fleet, err := service.GetDriftTrends(ctx, &DriftTrendRequest{OrganizationID: org.ID, Scope: domain.DriftTrendScopeFleet, TagID: &tag.ID})
+	require.NoError(t, err)
+	require.Len(t, fleet.Agents, 1)
+	assert.Equal(t, tagged.ID, fleet.Agents[0].AgentID)
+	require.Len(t, fleet.RepeatOffenders, 1, "drift on two different days")
+
+	agentReport, err := service.GetDriftTrends(ctx, &DriftTrendRequest{OrganizationID: org.ID, Scope: domain.DriftTrendScopeAgent, AgentID: &untagged.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 1, agentReport.TotalDriftEvents)
+	assert.Equal(t, 0, agentReport.Remediation.AlertsRaised)
+
+	otherOrg := uuid.New()
+	_, err = service.GetDriftTrends(ctx, &DriftTrendRequest{OrganizationID: otherOrg, Scope: domain.DriftTrendScopeAgent, AgentID: &untagged.ID})
+	assert.ErrorIs(t, err, ErrDriftTrendSubjectNotFound)
+	_, err = service.GetDriftTrends(ctx, &DriftTrendRequest{OrganizationID: otherOrg, Scope: domain.DriftTrendScopeFleet, TagID: &tag.ID})
+	assert.ErrorIs(t, err, ErrDriftTrendSubjectNotFound)
+	_, err = service.GetDriftTrends(ctx, &DriftTrendRequest{OrganizationID: org.ID, Scope: domain.DriftTrendScopeOrganization, Interval: "hour"})
+	assert.ErrorIs(t, err, ErrInvalidDriftTrendRequest)
+}
@@ -1,11 +1,15 @@
 package application
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/metrics"
+	"github.com/opena2a/identity/backend/internal/infrastructure/tracing"
 )
 
 // Trust score penalty constants
@@ -22,8 +26,10 @@ const (
 
 // DriftDetectionService handles configuration drift detection for agents
 type DriftDetectionService struct {
-	agentRepo domain.AgentRepository
-	alertRepo domain.AlertRepository
+	agentRepo    domain.AgentRepository
+	alertRepo    domain.AlertRepository
+	remediations domain.DriftRemediationRepository
+	toolGrants   domain.MCPToolGrantRepository
 }
 
 // NewDriftDetectionService creates a new drift detection service
@@ -34,84 +40,172 @@ func NewDriftDetectionService(agentRepo domain.AgentRepository, alertRepo domain
 	}
 }
 
+// UseRemediations proposes a change set with each configuration drift alert, which admins
+// approve to register the MCP servers or reject to enforce the config drift policy
+func (s *DriftDetectionService) UseRemediations(remediations domain.DriftRemediationRepository) {
+	s.remediations = remediations
+}
+
+// UseToolGrants checks the MCP tools an agent calls against its tool grants, flagging calls
+// outside the granted set as tool drift
+func (s *DriftDetectionService) UseToolGrants(toolGrants domain.MCPToolGrantRepository) {
+	s.toolGrants = toolGrants
+}
+
 // DriftResult contains the results of drift detection
 type DriftResult struct {
 	DriftDetected     bool
 	MCPServerDrift    []string
+	MCPToolDrift      []string // MCP tools called outside the agent's tool grants, as server:tool
 	CapabilityDrift   []string
-	Alert             *domain.Alert
+	Alert             *domain.Alert // Configuration drift alert (MCP servers)
+	ToolAlert         *domain.Alert // MCP tool drift alert
+	CapabilityAlert   *domain.Alert // Capability drift alert
+	Remediation       *domain.DriftRemediation // Change set proposed with the configuration drift alert
+}
+
+// capabilityDriftSeverities maps undeclared capabilities to the severity of the
+// capability drift alert; capabilities outside the standard taxonomy are high
+var capabilityDriftSeverities = map[string]domain.AlertSeverity{
+	domain.CapabilitySystemAdmin:     domain.AlertSeverityCritical,
+	domain.CapabilityUserImpersonate: domain.AlertSeverityCritical,
+	domain.CapabilityDataExport:      domain.AlertSeverityCritical,
+	domain.CapabilityFileDelete:      domain.AlertSeverityHigh,
+	domain.CapabilityFileWrite:       domain.AlertSeverityHigh,
+	domain.CapabilityDBWrite:         domain.AlertSeverityHigh,
+	domain.CapabilityNetworkAccess:   domain.AlertSeverityHigh,
+	domain.CapabilityFileRead:        domain.AlertSeverityWarning,
+	domain.CapabilityDBQuery:         domain.AlertSeverityWarning,
+	domain.CapabilityAPICall:         domain.AlertSeverityWarning,
+	domain.CapabilityMCPToolUse:      domain.AlertSeverityWarning,
 }
 
 // DetectDrift checks if an agent's runtime configuration drifts from registered values
 func (s *DriftDetectionService) DetectDrift(
+	ctx context.Context,
 	agentID uuid.UUID,
 	currentMCPServers []string,
 	currentCapabilities []string,
 ) (*DriftResult, error) {
+	return s.DetectDriftWithTools(ctx, agentID, currentMCPServers, nil, currentCapabilities)
+}
+
+// DetectDriftWithTools is DetectDrift that also checks the MCP tools called at runtime, as
+// server:tool, against the agent's tool grants. The servers of the tool calls are checked
+// against talks_to like the other MCP servers.
+func (s *DriftDetectionService) DetectDriftWithTools(
+	ctx context.Context,
+	agentID uuid.UUID,
+	currentMCPServers []string,
+	currentMCPTools []string,
+	currentCapabilities []string,
+) (*DriftResult, error) {
+	ctx, span := tracing.Start(ctx, "DriftDetectionService.DetectDrift",
+		tracing.String("aim.agent_id", agentID.String()))
+	defer span.End()
+
 	// 1. Get agent's registered configuration
-	agent, err := s.agentRepo.GetByID(agentID)
+	var agent *domain.Agent
+	err := traceRepository(ctx, "AgentRepository", "GetByID", func() (err error) {
+		agent, err = s.agentRepo.GetByID(agentID)
+		return err
+	})
 	if err != nil {
+		span.RecordError(err)
 		return nil, fmt.Errorf("failed to get agent: %w", err)
 	}
 
 	// 2. Detect MCP server drift
-	mcpDrift := detectArrayDrift(agent.TalksTo, currentMCPServers)
+	mcpDrift := detectArrayDrift(agent.TalksTo, MergeMCPToolCallServers(currentMCPServers, currentMCPTools))
+
+	// 3. Detect tool drift on the servers the agent talks to against its tool grants
+	var grants []*domain.MCPToolGrant
+	if s.toolGrants != nil && len(currentMCPTools) > 0 {
+		err := traceRepository(ctx, "MCPToolGrantRepository", "ListByAgent", func() (err error) {
+			grants, err = s.toolGrants.ListByAgent(agent.ID)
+			return err
+		})
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to get MCP tool grants: %w", err)
+		}
+	}
+	toolDrift := detectToolDrift(agent.TalksTo, grants, currentMCPTools)
 
-	// 3. Detect capability drift (if agent has registered capabilities)
-	// Note: Capabilities are currently stored in separate table, so this is for future use
-	capabilityDrift := []string{}
+	// 4. Detect capability drift against the agent's declared capabilities
+	capabilityDrift := detectArrayDrift(agent.Capabilities, currentCapabilities)
 
-	// 4. If no drift detected, return early
-	if len(mcpDrift) == 0 && len(capabilityDrift) == 0 {
+	span.SetAttributes(
+		tracing.Int("aim.drift.mcp_servers", len(mcpDrift)),
+		tracing.Int("aim.drift.mcp_tools", len(toolDrift)),
+		tracing.Int("aim.drift.capabilities", len(capabilityDrift)),
+	)
+
+	// 5. If no drift detected, return early
+	if len(mcpDrift) == 0 && len(toolDrift) == 0 && len(capabilityDrift) == 0 {
 		return &DriftResult{
 			DriftDetected:     false,
 			MCPServerDrift:    []string{},
+			MCPToolDrift:      []string{},
 			CapabilityDrift:   []string{},
 		}, nil
 	}
 
-	// 5. Drift detected - create high-severity alert
-	alert, err := s.createDriftAlert(agent, mcpDrift, capabilityDrift)
-	if err != nil {
-		// Log error but don't fail the drift detection
-		fmt.Printf("Failed to create drift alert: %v\n", err)
+	result := &DriftResult{
+		DriftDetected:     true,
+		MCPServerDrift:    mcpDrift,
+		MCPToolDrift:      toolDrift,
+		CapabilityDrift:   capabilityDrift,
 	}
 
-	// 6. Apply trust score penalty
+	// 6. Drift detected - raise a separate alert per kind of drift
+	if len(mcpDrift) > 0 {
+		metrics.RecordDriftDetection("mcp_server")
+		result.Alert, result.Remediation, err = s.createDriftAlert(ctx, agent, mcpDrift)
+		if err != nil {
+			// Log error but don't fail the drift detection
+			fmt.Printf("Failed to create drift alert: %v\n", err)
+		}
+	}
+	if len(toolDrift) > 0 {
+		metrics.RecordDriftDetection("mcp_tool")
+		result.ToolAlert, err = s.createToolDriftAlert(ctx, agent, grants, toolDrift)
+		if err != nil {
+			// Log error but don't fail the drift detection
+			fmt.Printf("Failed to create MCP tool drift alert: %v\n", err)
+		}
+	}
+	if len(capabilityDrift) > 0 {
+		metrics.RecordDriftDetection("capability")
+		result.CapabilityAlert, err = s.createCapabilityDriftAlert(ctx, agent, capabilityDrift)
+		if err != nil {
+			// Log error but don't fail the drift detection
+			fmt.Printf("Failed to create capability drift alert: %v\n", err)
+		}
+	}
+
+	// 7. Apply trust score penalty
 	if err := s.applyTrustScorePenalty(agent, mcpDrift, capabilityDrift); err != nil {
 		// Log error but don't fail the drift detection
 		fmt.Printf("Failed to apply trust score penalty: %v\n", err)
 	}
 
-	return &DriftResult{
-		DriftDetected:     true,
-		MCPServerDrift:    mcpDrift,
-		CapabilityDrift:   capabilityDrift,
-		Alert:             alert,
-	}, nil
+	return result, nil
 }
 
-// createDriftAlert creates a high-severity alert for configuration drift
+// createDriftAlert creates a high-severity alert for MCP server drift and, when remediations
+// are enabled, the change set proposed with it
 func (s *DriftDetectionService) createDriftAlert(
+	ctx context.Context,
 	agent *domain.Agent,
 	mcpDrift []string,
-	capabilityDrift []string,
-) (*domain.Alert, error) {
+) (*domain.Alert, *domain.DriftRemediation, error) {
 	// Build alert message
 	message := fmt.Sprintf("Agent '%s' is deviating from registered configuration.", agent.Name)
 
-	if len(mcpDrift) > 0 {
-		message += fmt.Sprintf("\n\n**Unauthorized MCP Server Communication:**\n")
-		for _, mcp := range mcpDrift {
-			message += fmt.Sprintf("- `%s` (not registered)\n", mcp)
-		}
-	}
-
-	if len(capabilityDrift) > 0 {
-		message += fmt.Sprintf("\n\n**Undeclared Capability Usage:**\n")
-		for _, cap := range capabilityDrift {
-			message += fmt.Sprintf("- `%s` (not declared)\n", cap)
-		}
+	message += fmt.Sprintf("\n\n**Unauthorized MCP Server Communication:**\n")
+	for _, mcp := range mcpDrift {
+		message += fmt.Sprintf("- `%s` (not registered)\n", mcp)
 	}
 
 	message += "\n\n**Registered Configuration:**\n"
@@ -133,6 +227,21 @@ func (s *DriftDetectionService) createDriftAlert(
 	message += "2. If legitimate, approve drift and update registration\n"
 	message += "3. If suspicious, investigate for potential compromise\n"
 
+	var remediation *domain.DriftRemediation
+	if s.remediations != nil {
+		remediation = &domain.DriftRemediation{
+			ID:             uuid.New(),
+			OrganizationID: agent.OrganizationID,
+			AgentID:        agent.ID,
+			MCPServers:     mcpDrift,
+			Status:         domain.DriftRemediationStatusPending,
+			CreatedAt:      time.Now(),
+		}
+		message += "\n**Proposed Remediation:**\n"
+		message += fmt.Sprintf("Change set `%s` adds the MCP servers above to the agent's registration. ", remediation.ID)
+		message += "Approve it to update the registration, or reject it to block them and enforce the config drift policy.\n"
+	}
+
 	// Create alert
 	alert := &domain.Alert{
 		ID:             uuid.New(),
@@ -148,6 +257,171 @@ func (s *DriftDetectionService) createDriftAlert(
 	}
 
 	// Save alert
+	if err := traceRepository(ctx, "AlertRepository", "Create", func() error { return s.alertRepo.Create(alert) }); err != nil {
+		return nil, nil, fmt.Errorf("failed to create alert: %w", err)
+	}
+
+	if remediation != nil {
+		remediation.AlertID = alert.ID
+		if err := s.remediations.Create(remediation); err != nil {
+			return alert, nil, fmt.Errorf("failed to create drift remediation: %w", err)
+		}
+	}
+
+	return alert, remediation, nil
+}
+
+// createToolDriftAlert creates a high-severity alert for MCP tools called outside the agent's
+// tool grants
+func (s *DriftDetectionService) createToolDriftAlert(
+	ctx context.Context,
+	agent *domain.Agent,
+	grants []*domain.MCPToolGrant,
+	toolDrift []string,
+) (*domain.Alert, error) {
+	message := fmt.Sprintf("Agent '%s' is calling MCP tools it was not granted.", agent.Name)
+
+	message += "\n\n**Ungranted Tool Calls:**\n"
+	for _, call := range toolDrift {
+		message += fmt.Sprintf("- `%s` (not granted)\n", call)
+	}
+
+	message += "\n\n**Granted Tools:**\n"
+	for _, grant := range grants {
+		if !slices.ContainsFunc(toolDrift, func(call string) bool {
+			server, _, _ := domain.ParseMCPToolCall(call)
+			return server == grant.MCPServer
+		}) {
+			continue
+		}
+		if len(grant.Tools) == 0 {
+			message += fmt.Sprintf("- `%s`: None granted\n", grant.MCPServer)
+			continue
+		}
+		message += fmt.Sprintf("- `%s`: ", grant.MCPServer)
+		for i, tool := range grant.Tools {
+			if i > 0 {
+				message += ", "
+			}
+			message += fmt.Sprintf("`%s`", tool)
+		}
+		message += "\n"
+	}
+
+	message += "\n**Recommended Actions:**\n"
+	message += "1. Investigate why agent is calling tools outside its grants\n"
+	message += "2. If legitimate, add the tools to the agent's tool grant for the server\n"
+	message += "3. If suspicious, investigate for potential compromise\n"
+
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: agent.OrganizationID,
+		AlertType:      domain.AlertTypeMCPToolDrift,
+		Severity:       domain.AlertSeverityHigh,
+		Title:          fmt.Sprintf("MCP Tool Drift Detected: %s", agent.Name),
+		Description:    message,
+		ResourceType:   "agent",
+		ResourceID:     agent.ID,
+		IsAcknowledged: false,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := traceRepository(ctx, "AlertRepository", "Create", func() error { return s.alertRepo.Create(alert) }); err != nil {
+		return nil, fmt.Errorf("failed to create alert: %w", err)
+	}
+
+	return alert, nil
+}
+
+// createCapabilityDriftAlert creates an alert for undeclared capability usage.
+// Its severity is that of the riskiest undeclared capability.
+func (s *DriftDetectionService) createCapabilityDriftAlert(
+	ctx context.Context,
+	agent *domain.Agent,
+	capabilityDrift []string,
+) (*domain.Alert, error) {
+	message := fmt.Sprintf("Agent '%s' is using capabilities it never declared.", agent.Name)
+
+	message += "\n\n**Undeclared Capability Usage:**\n"
+	for _, capability := range capabilityDrift {
+		message += fmt.Sprintf("- `%s` (not declared)\n", capability)
+	}
+
+	message += "\n\n**Declared Capabilities:**\n"
+	if len(agent.Capabilities) > 0 {
+		message += "- "
+		for i, capability := range agent.Capabilities {
+			if i > 0 {
+				message += ", "
+			}
+			message += fmt.Sprintf("`%s`", capability)
+		}
+		message += "\n"
+	} else {
+		message += "- None declared\n"
+	}
+
+	message += "\n**Recommended Actions:**\n"
+	message += "1. Investigate why agent is using undeclared capabilities\n"
+	message += "2. If legitimate, file a capability request to declare them\n"
+	message += "3. If suspicious, investigate for potential compromise\n"
+
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: agent.OrganizationID,
+		AlertType:      domain.AlertTypeCapabilityDrift,
+		Severity:       capabilityDriftSeverity(capabilityDrift),
+		Title:          fmt.Sprintf("Capability Drift Detected: %s", agent.Name),
+		Description:    message,
+		ResourceType:   "agent",
+		ResourceID:     agent.ID,
+		IsAcknowledged: false,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := traceRepository(ctx, "AlertRepository", "Create", func() error { return s.alertRepo.Create(alert) }); err != nil {
+		return nil, fmt.Errorf("failed to create alert: %w", err)
+	}
+
+	return alert, nil
+}
+
+// DetectPeerDrift raises a high-severity alert when an agent calls another agent no peer
+// policy declares. Calls repeated while the alert is unacknowledged don't raise another one,
+// and the trust score is left alone: the call is denied, so nothing undeclared happened.
+func (s *DriftDetectionService) DetectPeerDrift(caller, target *domain.Agent) (*domain.Alert, error) {
+	metrics.RecordDriftDetection("peer_agent")
+
+	title := fmt.Sprintf("Peer Drift Detected: %s → %s", caller.Name, target.Name)
+	existing, err := s.alertRepo.GetUnacknowledgedByResourceID(caller.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alerts: %w", err)
+	}
+	for _, alert := range existing {
+		if alert.AlertType == domain.AlertTypePeerDrift && alert.Title == title {
+			return nil, nil
+		}
+	}
+
+	message := fmt.Sprintf("Agent '%s' tried to call agent '%s', but no peer policy declares that relationship.", caller.Name, target.Name)
+	message += "\n\n**Recommended Actions:**\n"
+	message += "1. Investigate why the agent is calling an undeclared peer\n"
+	message += "2. If legitimate, add a peer policy for the two agents\n"
+	message += "3. If suspicious, investigate for potential compromise\n"
+
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: caller.OrganizationID,
+		AlertType:      domain.AlertTypePeerDrift,
+		Severity:       domain.AlertSeverityHigh,
+		Title:          title,
+		Description:    message,
+		ResourceType:   "agent",
+		ResourceID:     caller.ID,
+		IsAcknowledged: false,
+		CreatedAt:      time.Now(),
+	}
+
 	if err := s.alertRepo.Create(alert); err != nil {
 		return nil, fmt.Errorf("failed to create alert: %w", err)
 	}
@@ -155,6 +429,21 @@ func (s *DriftDetectionService) createDriftAlert(
 	return alert, nil
 }
 
+// capabilityDriftSeverity returns the highest severity among the undeclared capabilities
+func capabilityDriftSeverity(capabilityDrift []string) domain.AlertSeverity {
+	severity := domain.AlertSeverityInfo
+	for _, capability := range capabilityDrift {
+		capabilitySeverity, ok := capabilityDriftSeverities[capability]
+		if !ok {
+			capabilitySeverity = domain.AlertSeverityHigh
+		}
+		if severityRank(capabilitySeverity) > severityRank(severity) {
+			severity = capabilitySeverity
+		}
+	}
+	return severity
+}
+
 // applyTrustScorePenalty reduces agent trust score based on drift severity
 func (s *DriftDetectionService) applyTrustScorePenalty(
 	agent *domain.Agent,
@@ -211,3 +500,35 @@ func detectArrayDrift(registered []string, runtime []string) []string {
 
 	return drift
 }
+
+// detectToolDrift finds the 'calls' (server:tool) outside the agent's tool grants. Only calls on
+// servers in 'talksTo' are checked, since calls on other servers are MCP server drift; servers
+// without a grant allow every tool.
+func detectToolDrift(talksTo []string, grants []*domain.MCPToolGrant, calls []string) []string {
+	drift := []string{}
+	for _, call := range calls {
+		server, tool, ok := domain.ParseMCPToolCall(call)
+		if !ok || !slices.Contains(talksTo, server) || slices.Contains(drift, call) {
+			continue
+		}
+		for _, grant := range grants {
+			if grant.MCPServer == server && !grant.Allows(tool) {
+				drift = append(drift, call)
+				break
+			}
+		}
+	}
+	return drift
+}
+
+// MergeMCPToolCallServers adds the servers of the tool calls (server:tool) missing from 'servers'
+func MergeMCPToolCallServers(servers []string, calls []string) []string {
+	merged := servers
+	for _, call := range calls {
+		server, _, ok := domain.ParseMCPToolCall(call)
+		if ok && !slices.Contains(merged, server) {
+			merged = append(slices.Clip(merged), server)
+		}
+	}
+	return merged
+}
@@ -28,6 +28,14 @@ func (m *MockAgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
 	return args.Get(0).(*domain.Agent), args.Error(1)
 }
 
+func (m *MockAgentRepository) GetByIDs(ids []uuid.UUID) ([]*domain.Agent, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Agent), args.Error(1)
+}
+
 func (m *MockAgentRepository) GetByName(orgID uuid.UUID, name string) (*domain.Agent, error) {
 	args := m.Called(orgID, name)
 	if args.Get(0) == nil {
@@ -82,6 +90,26 @@ func (m *MockAgentRepository) UpdateLastActive(ctx context.Context, agentID uuid
 	return args.Error(0)
 }
 
+func (m *MockAgentRepository) RotateKey(agent *domain.Agent) error {
+	args := m.Called(agent)
+	return args.Error(0)
+}
+
+func (m *MockAgentRepository) DropEscrowedPrivateKeys(orgID uuid.UUID) (int, error) {
+	args := m.Called(orgID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAgentRepository) FreezeTrustScore(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAgentRepository) AddTalksTo(id uuid.UUID, mcpServer string) (bool, error) {
+	args := m.Called(id, mcpServer)
+	return args.Bool(0), args.Error(1)
+}
+
 // MockAlertRepository mocks the AlertRepository interface
 type MockAlertRepository struct {
 	mock.Mock
@@ -186,6 +214,7 @@ func TestDetectDrift_NoDrift(t *testing.T) {
 
 	// Test: Runtime matches registered configuration
 	result, err := service.DetectDrift(
+		context.Background(),
 		agentID,
 		[]string{"filesystem-mcp", "github-mcp"},
 		[]string{},
@@ -229,6 +258,7 @@ func TestDetectDrift_MCPServerDrift(t *testing.T) {
 
 	// Test: Runtime includes unregistered MCP server
 	result, err := service.DetectDrift(
+		context.Background(),
 		agentID,
 		[]string{"filesystem-mcp", "external-api-mcp"},
 		[]string{},
@@ -279,6 +309,7 @@ func TestDetectDrift_MultipleUnauthorizedServers(t *testing.T) {
 
 	// Test: Runtime includes multiple unregistered MCP servers
 	result, err := service.DetectDrift(
+		context.Background(),
 		agentID,
 		[]string{"unauthorized-mcp-1", "unauthorized-mcp-2", "malicious-mcp"},
 		[]string{},
@@ -326,6 +357,7 @@ func TestDetectDrift_RepeatedViolation(t *testing.T) {
 
 	// Test: Repeated drift violation
 	result, err := service.DetectDrift(
+		context.Background(),
 		agentID,
 		[]string{"filesystem-mcp", "malicious-mcp"},
 		[]string{},
@@ -367,6 +399,7 @@ func TestDetectDrift_TrustScoreFloor(t *testing.T) {
 
 	// Test: Drift violation should not go below 0
 	result, err := service.DetectDrift(
+		context.Background(),
 		agentID,
 		[]string{"filesystem-mcp", "evil-mcp"},
 		[]string{},
@@ -380,6 +413,104 @@ func TestDetectDrift_TrustScoreFloor(t *testing.T) {
 	mockAlertRepo.AssertExpectations(t)
 }
 
+func TestDetectDrift_CapabilityDrift(t *testing.T) {
+	// Setup
+	mockAgentRepo := new(MockAgentRepository)
+	mockAlertRepo := new(MockAlertRepository)
+	service := NewDriftDetectionService(mockAgentRepo, mockAlertRepo)
+
+	agentID := uuid.New()
+	orgID := uuid.New()
+
+	// Agent with declared capabilities and no MCP server drift
+	agent := &domain.Agent{
+		ID:                       agentID,
+		OrganizationID:           orgID,
+		Name:                     "report-agent",
+		TalksTo:                  []string{"filesystem-mcp"},
+		Capabilities:             []string{domain.CapabilityFileRead},
+		TrustScore:               85.0,
+		CapabilityViolationCount: 0,
+	}
+
+	mockAgentRepo.On("GetByID", agentID).Return(agent, nil)
+	mockAlertRepo.On("Create", mock.MatchedBy(func(alert *domain.Alert) bool {
+		return alert.AlertType == domain.AlertTypeCapabilityDrift
+	})).Return(nil).Once()
+	mockAgentRepo.On("UpdateTrustScore", agentID, 80.0).Return(nil)
+
+	// Test: Runtime uses undeclared capabilities
+	result, err := service.DetectDrift(
+		context.Background(),
+		agentID,
+		[]string{"filesystem-mcp"},
+		[]string{domain.CapabilityFileRead, domain.CapabilityFileWrite, domain.CapabilityDataExport},
+	)
+
+	// Verify
+	assert.NoError(t, err)
+	assert.True(t, result.DriftDetected)
+	assert.Empty(t, result.MCPServerDrift)
+	assert.Equal(t, []string{domain.CapabilityFileWrite, domain.CapabilityDataExport}, result.CapabilityDrift)
+	assert.Nil(t, result.Alert, "no MCP server drift, no configuration drift alert")
+	assert.NotNil(t, result.CapabilityAlert)
+
+	// Severity follows the riskiest undeclared capability
+	assert.Equal(t, domain.AlertSeverityCritical, result.CapabilityAlert.Severity)
+	assert.Equal(t, "Capability Drift Detected: report-agent", result.CapabilityAlert.Title)
+	assert.Contains(t, result.CapabilityAlert.Description, "data:export")
+	assert.Contains(t, result.CapabilityAlert.Description, "not declared")
+
+	mockAgentRepo.AssertExpectations(t)
+	mockAlertRepo.AssertExpectations(t)
+}
+
+func TestDetectDrift_MCPAndCapabilityDriftRaiseSeparateAlerts(t *testing.T) {
+	// Setup
+	mockAgentRepo := new(MockAgentRepository)
+	mockAlertRepo := new(MockAlertRepository)
+	service := NewDriftDetectionService(mockAgentRepo, mockAlertRepo)
+
+	agentID := uuid.New()
+	agent := &domain.Agent{
+		ID:             agentID,
+		OrganizationID: uuid.New(),
+		Name:           "busy-agent",
+		TalksTo:        []string{"filesystem-mcp"},
+		Capabilities:   []string{domain.CapabilityFileRead},
+		TrustScore:     85.0,
+	}
+
+	mockAgentRepo.On("GetByID", agentID).Return(agent, nil)
+	mockAlertRepo.On("Create", mock.AnythingOfType("*domain.Alert")).Return(nil).Twice()
+	// One penalty for the verification, however many kinds of drift it shows
+	mockAgentRepo.On("UpdateTrustScore", agentID, 80.0).Return(nil).Once()
+
+	result, err := service.DetectDrift(
+		context.Background(),
+		agentID,
+		[]string{"filesystem-mcp", "external-api-mcp"},
+		[]string{domain.CapabilityDBQuery},
+	)
+
+	// Verify
+	assert.NoError(t, err)
+	assert.Equal(t, domain.AlertTypeConfigurationDrift, result.Alert.AlertType)
+	assert.NotContains(t, result.Alert.Description, "db:query")
+	assert.Equal(t, domain.AlertTypeCapabilityDrift, result.CapabilityAlert.AlertType)
+	assert.Equal(t, domain.AlertSeverityWarning, result.CapabilityAlert.Severity)
+
+	mockAgentRepo.AssertExpectations(t)
+	mockAlertRepo.AssertExpectations(t)
+}
+
+func TestCapabilityDriftSeverity(t *testing.T) {
+	assert.Equal(t, domain.AlertSeverityWarning, capabilityDriftSeverity([]string{domain.CapabilityFileRead, domain.CapabilityAPICall}))
+	assert.Equal(t, domain.AlertSeverityHigh, capabilityDriftSeverity([]string{domain.CapabilityDBQuery, domain.CapabilityDBWrite}))
+	assert.Equal(t, domain.AlertSeverityCritical, capabilityDriftSeverity([]string{domain.CapabilityFileWrite, domain.CapabilitySystemAdmin}))
+	assert.Equal(t, domain.AlertSeverityHigh, capabilityDriftSeverity([]string{"custom:unknown"}), "unknown capabilities are high")
+}
+
 func TestDetectArrayDrift(t *testing.T) {
 	tests := []struct {
 		name       string
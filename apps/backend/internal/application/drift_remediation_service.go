@@ -0,0 +1,205 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ErrDriftRemediationDecided is returned when a drift remediation was already approved or rejected
+var ErrDriftRemediationDecided = errors.New("drift remediation was already decided")
+
+// DriftRemediationService lets admins decide the change sets proposed with configuration drift
+// alerts. Approval registers the MCP servers in the agent's talks_to; rejection applies the
+// enforcement action of the organization's config_drift policy to the agent. Either decision
+// acknowledges the drift alert.
+type DriftRemediationService struct {
+	repo      domain.DriftRemediationRepository
+	agentRepo domain.AgentRepository
+	alertRepo domain.AlertRepository
+	agents    *AgentService
+	policies  *SecurityPolicyService
+}
+
+// NewDriftRemediationService creates a new drift remediation service
+func NewDriftRemediationService(
+	repo domain.DriftRemediationRepository,
+	agentRepo domain.AgentRepository,
+	alertRepo domain.AlertRepository,
+	agents *AgentService,
+	policies *SecurityPolicyService,
+) *DriftRemediationService {
+	return &DriftRemediationService{
+		repo:      repo,
+		agentRepo: agentRepo,
+		alertRepo: alertRepo,
+		agents:    agents,
+		policies:  policies,
+	}
+}
+
+// ListRemediations returns the organization's drift remediations, newest first; an empty status lists all
+func (s *DriftRemediationService) ListRemediations(ctx context.Context, orgID uuid.UUID, status domain.DriftRemediationStatus, limit, offset int) ([]*domain.DriftRemediation, error) {
+	remediations, err := s.repo.ListByOrganization(orgID, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drift remediations: %w", err)
+	}
+	return remediations, nil
+}
+
+// GetRemediation returns a drift remediation of the organization
+func (s *DriftRemediationService) GetRemediation(ctx context.Context, orgID, id uuid.UUID) (*domain.DriftRemediation, error) {
+	remediation, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if remediation.OrganizationID != orgID {
+		return nil, domain.ErrDriftRemediationNotFound
+	}
+	return remediation, nil
+}
+
+// GetRemediationForAlert returns the change set proposed with a configuration drift alert
+func (s *DriftRemediationService) GetRemediationForAlert(ctx context.Context, orgID, alertID uuid.UUID) (*domain.DriftRemediation, error) {
+	remediation, err := s.repo.GetByAlertID(alertID)
+	if err != nil {
+		return nil, err
+	}
+	if remediation.OrganizationID != orgID {
+		return nil, domain.ErrDriftRemediationNotFound
+	}
+	return remediation, nil
+}
+
+// Approve adds the change set's MCP servers to the agent's registered talks_to
+func (s *DriftRemediationService) Approve(ctx context.Context, orgID, id, userID uuid.UUID, reason string) (*domain.DriftRemediation, error) {
+	remediation, agent, err := s.pending(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	remediation.Status = domain.DriftRemediationStatusApproved
+	if err := s.decide(remediation, userID, reason); err != nil {
+		return nil, err
+	}
+
+	registered := make(map[string]bool, len(agent.TalksTo))
+	for _, mcp := range agent.TalksTo {
+		registered[mcp] = true
+	}
+	for _, mcp := range remediation.MCPServers {
+		if !registered[mcp] {
+			agent.TalksTo = append(agent.TalksTo, mcp)
+			registered[mcp] = true
+		}
+	}
+	if err := s.agentRepo.Update(agent); err != nil {
+		return nil, fmt.Errorf("failed to update agent: %w", err)
+	}
+
+	s.acknowledgeAlert(remediation, userID)
+	return remediation, nil
+}
+
+// Reject leaves the agent's registration unchanged and applies the config drift enforcement
+// action: block_and_alert suspends the agent and raises a policy violation alert, alert_only
+// only raises the alert, allow does neither
+func (s *DriftRemediationService) Reject(ctx context.Context, orgID, id, userID uuid.UUID, reason string) (*domain.DriftRemediation, error) {
+	remediation, agent, err := s.pending(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	action, policyName, err := s.policies.ConfigDriftEnforcement(ctx, agent)
+	if err != nil {
+		return nil, err
+	}
+	remediation.Status = domain.DriftRemediationStatusRejected
+	remediation.EnforcementAction = action
+	remediation.PolicyName = policyName
+	if err := s.decide(remediation, userID, reason); err != nil {
+		return nil, err
+	}
+
+	if action == domain.EnforcementBlockAndAlert {
+		if err := s.agents.SuspendAgent(ctx, agent.ID); err != nil {
+			return nil, fmt.Errorf("failed to suspend agent: %w", err)
+		}
+	}
+	if action == domain.EnforcementBlockAndAlert || action == domain.EnforcementAlertOnly {
+		if err := s.alertRepo.Create(rejectedDriftAlert(remediation, agent)); err != nil {
+			fmt.Printf("⚠️  Warning: failed to create rejected drift alert for agent %s: %v\n", agent.ID, err)
+		}
+	}
+
+	s.acknowledgeAlert(remediation, userID)
+	return remediation, nil
+}
+
+// pending returns a pending remediation of the organization and its agent
+func (s *DriftRemediationService) pending(ctx context.Context, orgID, id uuid.UUID) (*domain.DriftRemediation, *domain.Agent, error) {
+	remediation, err := s.GetRemediation(ctx, orgID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if remediation.Status != domain.DriftRemediationStatusPending {
+		return nil, nil, ErrDriftRemediationDecided
+	}
+	agent, err := s.agentRepo.GetByID(remediation.AgentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	return remediation, agent, nil
+}
+
+// decide records the decision; only one of concurrent decisions wins
+func (s *DriftRemediationService) decide(remediation *domain.DriftRemediation, userID uuid.UUID, reason string) error {
+	now := time.Now()
+	remediation.Reason = reason
+	remediation.DecidedBy = &userID
+	remediation.DecidedAt = &now
+	decided, err := s.repo.Decide(remediation)
+	if err != nil {
+		return fmt.Errorf("failed to record drift remediation decision: %w", err)
+	}
+	if !decided {
+		return ErrDriftRemediationDecided
+	}
+	return nil
+}
+
+func (s *DriftRemediationService) acknowledgeAlert(remediation *domain.DriftRemediation, userID uuid.UUID) {
+	if err := s.alertRepo.Acknowledge(remediation.AlertID, userID); err != nil {
+		fmt.Printf("⚠️  Warning: failed to acknowledge drift alert %s: %v\n", remediation.AlertID, err)
+	}
+}
+
+// rejectedDriftAlert describes the enforcement action applied for a rejected change set
+func rejectedDriftAlert(remediation *domain.DriftRemediation, agent *domain.Agent) *domain.Alert {
+	message := fmt.Sprintf("An admin rejected the MCP servers agent '%s' talked to without registering them.", agent.Name)
+	message += "\n\n**Rejected MCP Servers:**\n"
+	for _, mcp := range remediation.MCPServers {
+		message += fmt.Sprintf("- `%s`\n", mcp)
+	}
+	message += fmt.Sprintf("\n**Enforcement:** %s (policy: %s)\n", remediation.EnforcementAction, remediation.PolicyName)
+	if remediation.EnforcementAction == domain.EnforcementBlockAndAlert {
+		message += "The agent was suspended. Reactivate it once it no longer talks to the rejected MCP servers.\n"
+	}
+
+	return &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: agent.OrganizationID,
+		AlertType:      domain.AlertPolicyViolation,
+		Severity:       domain.AlertSeverityHigh,
+		Title:          fmt.Sprintf("Configuration Drift Rejected: %s", agent.Name),
+		Description:    message,
+		ResourceType:   "agent",
+		ResourceID:     agent.ID,
+		IsAcknowledged: false,
+		CreatedAt:      time.Now(),
+	}
+}
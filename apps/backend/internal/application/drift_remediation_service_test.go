@@ -0,0 +1,106 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftAlertsProposeAChangeSetAdminsApproveOrReject(t *testing.T) {
+	ctx := context.Background()
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	admin := uuid.New()
+	approved := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.TalksTo = []string{"filesystem-mcp"} })
+	rejected := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.TalksTo = []string{"filesystem-mcp"} })
+	watched := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.TalksTo = []string{"filesystem-mcp"} })
+	for _, agent := range []*domain.Agent{approved, rejected, watched} {
+		require.NoError(t, repos.Agent.Create(agent))
+	}
+
+	drift := NewDriftDetectionService(repos.Agent, repos.Alert)
+	drift.UseRemediations(repos.DriftRemediation)
+	trustCalc := NewTrustCalculator(repos.TrustScore, repos.APIKey, repos.AuditLog, repos.Capability, repos.Agent, repos.Alert)
+	agentService := NewAgentService(repos.Agent, trustCalc, repos.TrustScore, nil, repos.Alert, nil, repos.Capability, nil, nil, repos.Organization, nil, nil, nil, nil)
+	policies := NewSecurityPolicyService(repos.SecurityPolicy, repos.Alert, repos.AuditLog, repos.MCPServerCapability, nil)
+	remediations := NewDriftRemediationService(repos.DriftRemediation, repos.Agent, repos.Alert, agentService, policies)
+
+	detect := func(agent *domain.Agent) *domain.DriftRemediation {
+		result, err := drift.DetectDrift(ctx, agent.ID, []string{"filesystem-mcp", "external-api-mcp"}, nil)
+		require.NoError(t, err)
+		require.NotNil(t, result.Remediation)
+		assert.Equal(t, []string{"external-api-mcp"}, result.Remediation.MCPServers)
+		assert.Equal(t, domain.DriftRemediationStatusPending, result.Remediation.Status)
+		assert.Contains(t, result.Alert.Description, result.Remediation.ID.String(), "the alert names the proposed change set")
+		linked, err := remediations.GetRemediationForAlert(ctx, org.ID, result.Alert.ID)
+		require.NoError(t, err)
+		assert.Equal(t, result.Remediation.ID, linked.ID)
+		return result.Remediation
+	}
+
+	// Approval registers the MCP server, so the next verification no longer drifts
+	proposed := detect(approved)
+	decided, err := remediations.Approve(ctx, org.ID, proposed.ID, admin, "external API is part of the rollout")
+	require.NoError(t, err)
+	assert.Equal(t, domain.DriftRemediationStatusApproved, decided.Status)
+	stored, err := repos.Agent.GetByID(approved.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"filesystem-mcp", "external-api-mcp"}, stored.TalksTo)
+	assert.Equal(t, domain.AgentStatusVerified, stored.Status)
+	alert, err := repos.Alert.GetByID(proposed.AlertID)
+	require.NoError(t, err)
+	assert.True(t, alert.IsAcknowledged)
+	result, err := drift.DetectDrift(ctx, approved.ID, []string{"filesystem-mcp", "external-api-mcp"}, nil)
+	require.NoError(t, err)
+	assert.False(t, result.DriftDetected)
+
+	// A change set is decided once, and only by its organization
+	_, err = remediations.Reject(ctx, org.ID, proposed.ID, admin, "")
+	assert.ErrorIs(t, err, ErrDriftRemediationDecided)
+	_, err = remediations.Approve(ctx, uuid.New(), detect(rejected).ID, admin, "")
+	assert.ErrorIs(t, err, domain.ErrDriftRemediationNotFound)
+
+	// Without a config drift policy, rejection blocks: the agent is suspended and an alert raised
+	pending, err := remediations.ListRemediations(ctx, org.ID, domain.DriftRemediationStatusPending, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	decided, err = remediations.Reject(ctx, org.ID, pending[0].ID, admin, "unknown endpoint")
+	require.NoError(t, err)
+	assert.Equal(t, domain.DriftRemediationStatusRejected, decided.Status)
+	assert.Equal(t, domain.EnforcementBlockAndAlert, decided.EnforcementAction)
+	assert.Equal(t, "default_policy", decided.PolicyName)
+	stored, err = repos.Agent.GetByID(rejected.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AgentStatusSuspended, stored.Status)
+	assert.Equal(t, []string{"filesystem-mcp"}, stored.TalksTo, "rejection leaves the registration unchanged")
+	alerts, err := repos.Alert.GetUnacknowledgedByResourceID(rejected.ID)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domain.AlertPolicyViolation, alerts[0].AlertType)
+
+	// An alert_only config drift policy raises the alert without suspending the agent
+	require.NoError(t, repos.SecurityPolicy.Create(&domain.SecurityPolicy{
+		OrganizationID: org.ID, Name: "Watch drift", PolicyType: domain.PolicyTypeConfigDrift,
+		EnforcementAction: domain.EnforcementAlertOnly, AppliesTo: "all", IsEnabled: true,
+	}))
+	decided, err = remediations.Reject(ctx, org.ID, detect(watched).ID, admin, "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.EnforcementAlertOnly, decided.EnforcementAction)
+	assert.Equal(t, "Watch drift", decided.PolicyName)
+	stored, err = repos.Agent.GetByID(watched.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AgentStatusVerified, stored.Status)
+	alerts, err = repos.Alert.GetUnacknowledgedByResourceID(watched.ID)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domain.AlertPolicyViolation, alerts[0].AlertType)
+
+	all, err := remediations.ListRemediations(ctx, org.ID, "", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+}
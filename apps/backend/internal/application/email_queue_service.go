@@ -0,0 +1,420 @@
+package application
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// emailQueueBatchSize is how many queued emails the worker claims at a time
+	emailQueueBatchSize = 50
+	// emailQueueLease is how long the worker holds claimed emails before another worker may claim them
+	emailQueueLease = 5 * time.Minute
+	// defaultEmailMaxAttempts is how often an email is tried before it is given up on
+	defaultEmailMaxAttempts = 8
+	// maxEmailBackoff caps the delay between attempts to send an email
+	maxEmailBackoff = time.Hour
+)
+
+var (
+	// ErrInvalidEmailSender is returned for sender configurations without a valid from address
+	ErrInvalidEmailSender = errors.New("invalid email sender")
+	// ErrInvalidEmailWebhookSecret is returned for bounce webhooks without the configured secret
+	ErrInvalidEmailWebhookSecret = errors.New("invalid email webhook secret")
+)
+
+// SetEmailSenderRequest is the address an organization's mail is sent from
+type SetEmailSenderRequest struct {
+	FromAddress string `json:"fromAddress"`
+	FromName    string `json:"fromName"`
+	ReplyTo     string `json:"replyTo"`
+}
+
+// EmailQueueService queues transactional mail and sends it in the background, retrying
+// failed sends with exponential backoff. Organizations' mail is sent from their own sender when
+// they configured one. Addresses the provider reports hard bounced or complained about are put
+// on a suppression list and receive no more mail.
+//
+// It implements domain.EmailService, so services sending mail queue it without knowing.
+type EmailQueueService struct {
+	queueRepo       domain.EmailQueueRepository
+	suppressionRepo domain.EmailSuppressionRepository
+	senderRepo      domain.OrganizationEmailSenderRepository
+	operatorRepo    domain.PlatformOperatorRepository
+	transport       domain.EmailService
+	renderer        domain.EmailTemplateRenderer
+	maxAttempts     int
+	webhookSecret   string
+	now             func() time.Time
+}
+
+// NewEmailQueueService creates a new email queue service sending through transport; transport
+// may be nil when no email provider is configured, which turns the queue off
+func NewEmailQueueService(
+	queueRepo domain.EmailQueueRepository,
+	suppressionRepo domain.EmailSuppressionRepository,
+	senderRepo domain.OrganizationEmailSenderRepository,
+	operatorRepo domain.PlatformOperatorRepository,
+	transport domain.EmailService,
+	renderer domain.EmailTemplateRenderer,
+	maxAttempts int,
+	webhookSecret string,
+) *EmailQueueService {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultEmailMaxAttempts
+	}
+	return &EmailQueueService{
+		queueRepo:       queueRepo,
+		suppressionRepo: suppressionRepo,
+		senderRepo:      senderRepo,
+		operatorRepo:    operatorRepo,
+		transport:       transport,
+		renderer:        renderer,
+		maxAttempts:     maxAttempts,
+		webhookSecret:   webhookSecret,
+		now:             time.Now,
+	}
+}
+
+// Enabled reports whether an email provider is configured to send queued mail through
+func (s *EmailQueueService) Enabled() bool {
+	return s.transport != nil
+}
+
+// SendEmail queues an email from the platform's sender
+func (s *EmailQueueService) SendEmail(to, subject, body string, isHTML bool) error {
+	return s.enqueue(nil, "", to, subject, body, isHTML)
+}
+
+// SendTemplatedEmail renders the template and queues the email from the platform's sender
+func (s *EmailQueueService) SendTemplatedEmail(template domain.EmailTemplate, to string, data interface{}) error {
+	return s.enqueueTemplate(nil, template, to, data)
+}
+
+// SendBulkEmail queues the email to each recipient separately
+func (s *EmailQueueService) SendBulkEmail(recipients []string, subject, body string, isHTML bool) error {
+	return s.enqueueBulk(nil, recipients, subject, body, isHTML)
+}
+
+// ValidateConnection validates the connection of the email provider
+func (s *EmailQueueService) ValidateConnection() error {
+	if !s.Enabled() {
+		return fmt.Errorf("email service is not configured")
+	}
+	return s.transport.ValidateConnection()
+}
+
+// ForOrganization returns an email service queueing mail on behalf of the organization, from
+// its sender when it configured one
+func (s *EmailQueueService) ForOrganization(orgID uuid.UUID) domain.EmailService {
+	return &organizationEmailQueue{queue: s, orgID: orgID}
+}
+
+// organizationEmailQueue queues mail on behalf of an organization
+type organizationEmailQueue struct {
+	queue *EmailQueueService
+	orgID uuid.UUID
+}
+
+func (q *organizationEmailQueue) SendEmail(to, subject, body string, isHTML bool) error {
+	return q.queue.enqueue(&q.orgID, "", to, subject, body, isHTML)
+}
+
+func (q *organizationEmailQueue) SendTemplatedEmail(template domain.EmailTemplate, to string, data interface{}) error {
+	return q.queue.enqueueTemplate(&q.orgID, template, to, data)
+}
+
+func (q *organizationEmailQueue) SendBulkEmail(recipients []string, subject, body string, isHTML bool) error {
+	return q.queue.enqueueBulk(&q.orgID, recipients, subject, body, isHTML)
+}
+
+func (q *organizationEmailQueue) ValidateConnection() error {
+	return q.queue.ValidateConnection()
+}
+
+// organizationEmail returns the email service sending on behalf of the organization, from its
+// sender, when the email service supports it; otherwise the email service itself
+func organizationEmail(emailService domain.EmailService, orgID uuid.UUID) domain.EmailService {
+	if orgEmail, ok := emailService.(domain.OrganizationEmailService); ok {
+		return orgEmail.ForOrganization(orgID)
+	}
+	return emailService
+}
+
+func (s *EmailQueueService) enqueueTemplate(orgID *uuid.UUID, template domain.EmailTemplate, to string, data interface{}) error {
+	if s.renderer == nil {
+		return fmt.Errorf("email templates are not configured")
+	}
+	subject, body, err := s.renderer.Render(template, data)
+	if err != nil {
+		return fmt.Errorf("failed to render template %s: %w", template, err)
+	}
+	return s.enqueue(orgID, template, to, subject, body, true)
+}
+
+func (s *EmailQueueService) enqueueBulk(orgID *uuid.UUID, recipients []string, subject, body string, isHTML bool) error {
+	for _, recipient := range recipients {
+		if err := s.enqueue(orgID, "", recipient, subject, body, isHTML); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueue queues an email, fixing its sender: the organization's, or the platform's when it
+// has none or the email isn't sent on behalf of an organization
+func (s *EmailQueueService) enqueue(orgID *uuid.UUID, template domain.EmailTemplate, to, subject, body string, isHTML bool) error {
+	if !s.Enabled() {
+		return fmt.Errorf("email service is not configured")
+	}
+	to = strings.TrimSpace(to)
+	if to == "" {
+		return fmt.Errorf("recipient is required")
+	}
+
+	now := s.now().UTC()
+	message := &domain.EmailMessage{
+		OrganizationID: orgID,
+		To:             to,
+		Subject:        subject,
+		Body:           body,
+		IsHTML:         isHTML,
+		Template:       template,
+		Status:         domain.EmailMessageQueued,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}
+	if orgID != nil {
+		sender, err := s.senderRepo.Get(*orgID)
+		switch {
+		case err == nil:
+			message.FromAddress, message.FromName, message.ReplyTo = sender.FromAddress, sender.FromName, sender.ReplyTo
+		case !errors.Is(err, domain.ErrEmailSenderNotFound):
+			log.Printf("⚠️  Failed to look up the email sender of organization %s, sending from the default: %v", *orgID, err)
+		}
+	}
+
+	if err := s.queueRepo.Enqueue(message); err != nil {
+		return fmt.Errorf("failed to queue email: %w", err)
+	}
+	return nil
+}
+
+// ProcessQueue sends the due queued emails, batch by batch, and returns how many were sent.
+// Failed sends are retried with exponential backoff until the last attempt, after which the
+// email is given up on; emails to suppressed addresses are not sent.
+func (s *EmailQueueService) ProcessQueue(ctx context.Context) (int, error) {
+	if !s.Enabled() {
+		return 0, nil
+	}
+
+	sent := 0
+	for ctx.Err() == nil {
+		messages, err := s.queueRepo.ClaimDue(emailQueueBatchSize, emailQueueLease)
+		if err != nil {
+			return sent, fmt.Errorf("failed to claim queued emails: %w", err)
+		}
+		if len(messages) == 0 {
+			return sent, nil
+		}
+		for _, message := range messages {
+			if ctx.Err() != nil {
+				break
+			}
+			if s.deliver(message) {
+				sent++
+			}
+		}
+	}
+	return sent, ctx.Err()
+}
+
+// deliver makes one attempt to send the message and records its outcome; it reports whether
+// the message was sent
+func (s *EmailQueueService) deliver(message *domain.EmailMessage) bool {
+	suppressed, err := s.suppressionRepo.IsSuppressed(strings.ToLower(message.To))
+	if err != nil {
+		log.Printf("⚠️  Failed to check the suppression list for email %s: %v", message.ID, err)
+	}
+	if suppressed {
+		if err := s.queueRepo.MarkFinal(message.ID, domain.EmailMessageSuppressed, "recipient is on the suppression list"); err != nil {
+			log.Printf("⚠️  Failed to mark email %s suppressed: %v", message.ID, err)
+		}
+		return false
+	}
+
+	sendErr := s.send(message)
+	if sendErr == nil {
+		if err := s.queueRepo.MarkSent(message.ID, s.now().UTC()); err != nil {
+			log.Printf("⚠️  Failed to mark email %s sent: %v", message.ID, err)
+		}
+		return true
+	}
+
+	attempt := message.Attempts + 1
+	if attempt >= s.maxAttempts {
+		log.Printf("⚠️  Giving up on email %s to %s after %d attempts: %v", message.ID, message.To, attempt, sendErr)
+		if err := s.queueRepo.MarkFinal(message.ID, domain.EmailMessageFailed, sendErr.Error()); err != nil {
+			log.Printf("⚠️  Failed to mark email %s failed: %v", message.ID, err)
+		}
+		return false
+	}
+	if err := s.queueRepo.MarkRetry(message.ID, sendErr.Error(), s.now().UTC().Add(emailBackoff(attempt))); err != nil {
+		log.Printf("⚠️  Failed to reschedule email %s: %v", message.ID, err)
+	}
+	return false
+}
+
+// send sends the message from its sender, or from the provider's default sender when the
+// provider can't send from another one
+func (s *EmailQueueService) send(message *domain.EmailMessage) error {
+	if sender := message.Sender(); sender != nil {
+		if senderTransport, ok := s.transport.(domain.SenderEmailService); ok {
+			return senderTransport.SendEmailAs(sender, message.To, message.Subject, message.Body, message.IsHTML)
+		}
+	}
+	return s.transport.SendEmail(message.To, message.Subject, message.Body, message.IsHTML)
+}
+
+// emailBackoff is the delay after the given failed attempt: 30s, 1m, 2m, ... up to maxEmailBackoff
+func emailBackoff(attempt int) time.Duration {
+	if attempt > 10 {
+		return maxEmailBackoff
+	}
+	return min(time.Duration(1<<max(attempt-1, 0))*30*time.Second, maxEmailBackoff)
+}
+
+// PurgeFinished deletes the emails that were sent or given up on longer ago than retention
+func (s *EmailQueueService) PurgeFinished(ctx context.Context, retention time.Duration) (int, error) {
+	return s.queueRepo.DeleteFinishedBefore(s.now().UTC().Add(-retention))
+}
+
+// RecordBounces records bounces and complaints the provider reported through its webhook,
+// authenticated with the configured secret. The latest email sent to each address is marked
+// bounced; hard bounces and complaints also put the address on the suppression list. It returns
+// how many emails were marked bounced.
+func (s *EmailQueueService) RecordBounces(ctx context.Context, secret string, bounces []domain.EmailBounce) (int, error) {
+	if s.webhookSecret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(s.webhookSecret)) != 1 {
+		return 0, ErrInvalidEmailWebhookSecret
+	}
+
+	now := s.now().UTC()
+	bounced := 0
+	for _, bounce := range bounces {
+		address := strings.ToLower(strings.TrimSpace(bounce.Address))
+		if address == "" {
+			continue
+		}
+		marked, err := s.queueRepo.MarkBounced(address, bounce.Reason, now)
+		if err != nil {
+			return bounced, fmt.Errorf("failed to mark email bounced: %w", err)
+		}
+		if marked {
+			bounced++
+		}
+		if bounce.Type == domain.EmailBounceSoft {
+			continue
+		}
+		if err := s.suppressionRepo.Add(&domain.EmailSuppression{
+			Address:   address,
+			Type:      bounce.Type,
+			Reason:    bounce.Reason,
+			CreatedAt: now,
+		}); err != nil {
+			return bounced, fmt.Errorf("failed to suppress %s: %w", address, err)
+		}
+	}
+	return bounced, nil
+}
+
+// ListMessages returns the organization's queued and sent emails, newest first, and how many
+// match the filter
+func (s *EmailQueueService) ListMessages(ctx context.Context, filter domain.EmailMessageFilter) ([]*domain.EmailMessage, int, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	filter.Offset = max(filter.Offset, 0)
+	return s.queueRepo.List(filter)
+}
+
+// GetSender returns the organization's sender; domain.ErrEmailSenderNotFound when it sends from
+// the platform's
+func (s *EmailQueueService) GetSender(ctx context.Context, orgID uuid.UUID) (*domain.OrganizationEmailSender, error) {
+	return s.senderRepo.Get(orgID)
+}
+
+// SetSender makes the organization's mail be sent from the given address. Emails already
+// queued keep the sender they were queued with.
+func (s *EmailQueueService) SetSender(ctx context.Context, orgID, userID uuid.UUID, req *SetEmailSenderRequest) (*domain.OrganizationEmailSender, error) {
+	from, err := mail.ParseAddress(strings.TrimSpace(req.FromAddress))
+	if err != nil || from.Name != "" {
+		return nil, fmt.Errorf("%w: from address must be a plain email address", ErrInvalidEmailSender)
+	}
+	replyTo := strings.TrimSpace(req.ReplyTo)
+	if replyTo != "" {
+		parsed, err := mail.ParseAddress(replyTo)
+		if err != nil || parsed.Name != "" {
+			return nil, fmt.Errorf("%w: reply-to must be a plain email address", ErrInvalidEmailSender)
+		}
+		replyTo = parsed.Address
+	}
+	fromName := strings.TrimSpace(req.FromName)
+	if strings.ContainsAny(fromName, "\r\n") {
+		return nil, fmt.Errorf("%w: from name must be a single line", ErrInvalidEmailSender)
+	}
+
+	sender := &domain.OrganizationEmailSender{
+		OrganizationID: orgID,
+		FromAddress:    from.Address,
+		FromName:       fromName,
+		ReplyTo:        replyTo,
+		UpdatedBy:      userID,
+		UpdatedAt:      s.now().UTC(),
+	}
+	if err := s.senderRepo.Upsert(sender); err != nil {
+		return nil, fmt.Errorf("failed to store email sender: %w", err)
+	}
+	return sender, nil
+}
+
+// DeleteSender makes the organization's mail be sent from the platform's sender again
+func (s *EmailQueueService) DeleteSender(ctx context.Context, orgID uuid.UUID) error {
+	return s.senderRepo.Delete(orgID)
+}
+
+// ListSuppressions returns the suppressed addresses, newest first, and how many there are
+func (s *EmailQueueService) ListSuppressions(ctx context.Context, limit, offset int) ([]*domain.EmailSuppression, int, error) {
+	return s.suppressionRepo.List(operatorPageSize(limit), max(offset, 0))
+}
+
+// RemoveSuppression lets mail be sent to the address again, e.g. once its mailbox was fixed.
+// The removal is kept in the operator log.
+func (s *EmailQueueService) RemoveSuppression(ctx context.Context, actor *domain.PlatformOperator, ip, address string) error {
+	address = strings.ToLower(strings.TrimSpace(address))
+	if err := s.suppressionRepo.Remove(address); err != nil {
+		return err
+	}
+
+	if s.operatorRepo != nil {
+		entry := &domain.OperatorAction{
+			OperatorID: actor.ID,
+			Action:     "email_suppression.remove",
+			Details:    map[string]interface{}{"address": address},
+			IPAddress:  ip,
+			CreatedAt:  s.now(),
+		}
+		if err := s.operatorRepo.RecordAction(entry); err != nil {
+			log.Printf("⚠️  Failed to record operator action email_suppression.remove by %s: %v", actor.Email, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/email"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyEmailTransport fails the first sends it is asked for and records the rest with their sender
+type flakyEmailTransport struct {
+	recordingEmailService
+	failures int
+	senders  []*domain.OrganizationEmailSender
+}
+
+func (f *flakyEmailTransport) SendEmail(to, subject, body string, isHTML bool) error {
+	return f.SendEmailAs(nil, to, subject, body, isHTML)
+}
+
+func (f *flakyEmailTransport) SendEmailAs(sender *domain.OrganizationEmailSender, to, subject, body string, isHTML bool) error {
+	if f.failures > 0 {
+		f.failures--
+		return fmt.Errorf("provider unavailable")
+	}
+	f.senders = append(f.senders, sender)
+	return f.recordingEmailService.SendEmail(to, subject, body, isHTML)
+}
+
+func TestEmailQueueRetriesSendsFromOrganizationSenderAndSuppressesBounces(t *testing.T) {
+	ctx := context.Background()
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	admin := testsupport.NewUser(org.ID)
+
+	renderer, err := email.NewTemplateRenderer("")
+	require.NoError(t, err)
+	transport := &flakyEmailTransport{failures: 1}
+	queue := NewEmailQueueService(repos.EmailQueue, repos.EmailSuppression, repos.EmailSender,
+		repos.PlatformOperator, transport, renderer, 3, "bounce-secret")
+	require.True(t, queue.Enabled())
+
+	// Organization senders must be plain addresses
+	_, err = queue.SetSender(ctx, org.ID, admin.ID, &SetEmailSenderRequest{FromAddress: "Security <security@acme.example>"})
+	assert.ErrorIs(t, err, ErrInvalidEmailSender)
+	_, err = queue.SetSender(ctx, org.ID, admin.ID, &SetEmailSenderRequest{
+		FromAddress: "security@acme.example", FromName: "Acme Security", ReplyTo: "soc@acme.example",
+	})
+	require.NoError(t, err)
+
+	// Sending queues the rendered email with the organization's sender; nothing goes out yet
+	orgEmail := queue.ForOrganization(org.ID)
+	require.NoError(t, orgEmail.SendTemplatedEmail(domain.TemplateCapabilityRequestApproved, "Dev@Acme.example", domain.EmailTemplateData{
+		AgentName: "billing-agent", CustomData: map[string]interface{}{"Capability": "db:write"},
+	}))
+	require.NoError(t, queue.SendEmail("ops@platform.example", "Platform notice", "<p>hi</p>", true))
+	assert.Empty(t, transport.emails)
+
+	// The first attempt fails and is retried after a backoff
+	sent, err := queue.ProcessQueue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	messages, total, err := queue.ListMessages(ctx, domain.EmailMessageFilter{OrganizationID: org.ID})
+	require.NoError(t, err)
+	require.Equal(t, 1, total, "platform mail is not listed for the organization")
+	message := messages[0]
+	assert.Equal(t, domain.EmailMessageQueued, message.Status)
+	assert.Equal(t, 1, message.Attempts)
+	assert.Equal(t, "provider unavailable", message.LastError)
+	assert.True(t, message.NextAttemptAt.After(time.Now()))
+
+	repos.EmailQueue.Reschedule(message.ID, time.Now())
+	sent, err = queue.ProcessQueue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, transport.emails, 2)
+	delivered := transport.emails[1]
+	assert.Equal(t, "Dev@Acme.example", delivered.to)
+	assert.Contains(t, delivered.subject, "db:write")
+	assert.Contains(t, delivered.body, "billing-agent")
+	require.NotNil(t, transport.senders[1])
+	assert.Equal(t, "security@acme.example", transport.senders[1].FromAddress)
+	assert.Equal(t, "soc@acme.example", transport.senders[1].ReplyTo)
+	assert.Nil(t, transport.senders[0], "platform mail is sent from the default sender")
+
+	// Bounce webhooks need the secret; a hard bounce marks the email and suppresses the address
+	bounces, err := email.ParseSendGridEvents([]byte(`[{"email":"dev@acme.example","event":"bounce","type":"bounce","reason":"550 no such user"},{"email":"ops@platform.example","event":"delivered"}]`))
+	require.NoError(t, err)
+	_, err = queue.RecordBounces(ctx, "wrong", bounces)
+	assert.ErrorIs(t, err, ErrInvalidEmailWebhookSecret)
+	bounced, err := queue.RecordBounces(ctx, "bounce-secret", bounces)
+	require.NoError(t, err)
+	assert.Equal(t, 1, bounced)
+	messages, _, err = queue.ListMessages(ctx, domain.EmailMessageFilter{OrganizationID: org.ID, Status: domain.EmailMessageBounced})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "550 no such user", messages[0].BounceReason)
+
+	// Later mail to the suppressed address is not sent, until an operator removes the suppression
+	require.NoError(t, orgEmail.SendEmail("DEV@acme.example", "Again", "<p>again</p>", true))
+	sent, err = queue.ProcessQueue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	messages, _, err = queue.ListMessages(ctx, domain.EmailMessageFilter{OrganizationID: org.ID, Status: domain.EmailMessageSuppressed})
+	require.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	operator := &domain.PlatformOperator{ID: uuid.New(), Email: "oncall@platform.example", Role: domain.OperatorRoleSupport}
+	suppressions, total, err := queue.ListSuppressions(ctx, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	assert.Equal(t, "dev@acme.example", suppressions[0].Address)
+	require.NoError(t, queue.RemoveSuppression(ctx, operator, "10.0.0.1", "Dev@Acme.example"))
+	assert.ErrorIs(t, queue.RemoveSuppression(ctx, operator, "10.0.0.1", "dev@acme.example"), domain.ErrEmailSuppressionNotFound)
+
+	// A soft bounce is recorded without suppressing the address
+	require.NoError(t, orgEmail.SendEmail("dev@acme.example", "Again", "<p>again</p>", true))
+	sent, err = queue.ProcessQueue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	_, err = queue.RecordBounces(ctx, "bounce-secret", []domain.EmailBounce{{Address: "dev@acme.example", Type: domain.EmailBounceSoft}})
+	require.NoError(t, err)
+	_, total, err = queue.ListSuppressions(ctx, 0, 0)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+
+	// Removing the sender sends the organization's mail from the platform's sender again
+	require.NoError(t, queue.DeleteSender(ctx, org.ID))
+	_, err = queue.GetSender(ctx, org.ID)
+	assert.ErrorIs(t, err, domain.ErrEmailSenderNotFound)
+}
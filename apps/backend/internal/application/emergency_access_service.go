@@ -0,0 +1,321 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+	"golang.org/x/crypto/nacl/box"
+)
+
+const (
+	// EmergencyAccessTTL is how long the access token issued by a break-glass activation lives.
+	// No refresh token is issued, so the deployment must rotate to fresh SDK credentials within it.
+	EmergencyAccessTTL = time.Hour
+	// DefaultEmergencyCredentialValidDays is used when a generate request does not set valid_days
+	DefaultEmergencyCredentialValidDays = 90
+	// MaxEmergencyCredentialValidDays caps how long a sealed credential can wait to be used
+	MaxEmergencyCredentialValidDays = 365
+
+	emergencyCredentialPrefix = "aim_ec_"
+)
+
+var (
+	// ErrInvalidEmergencyCredentialRequest is returned for malformed generate requests
+	ErrInvalidEmergencyCredentialRequest = errors.New("invalid emergency credential request")
+	// ErrEmergencyReasonRequired is returned when a break-glass activation gives no reason
+	ErrEmergencyReasonRequired = errors.New("a reason is required to activate emergency access")
+	// ErrEmergencyCredentialInvalid is returned for unknown, expired and revoked credentials
+	ErrEmergencyCredentialInvalid = errors.New("emergency credential is invalid, expired or revoked")
+	// ErrEmergencyCredentialUsed is returned when a credential was already activated
+	ErrEmergencyCredentialUsed = errors.New("emergency credential was already used")
+)
+
+// GenerateEmergencyCredentialRequest asks for a sealed emergency credential for an agent
+type GenerateEmergencyCredentialRequest struct {
+	SealingPublicKey string `json:"sealing_public_key"` // Base64 X25519 public key held by the organization
+	ValidDays        int    `json:"valid_days"`
+}
+
+// SealedEmergencyCredential is a generated credential and its secret sealed to the organization key.
+// The sealed secret is the only copy: it is never stored and can only be opened with the private key.
+type SealedEmergencyCredential struct {
+	Credential   *domain.EmergencyCredential `json:"credential"`
+	SealedSecret string                      `json:"sealed_secret"`
+}
+
+// EmergencyAccessGrant is the result of a successful break-glass activation
+type EmergencyAccessGrant struct {
+	AccessToken  string                     `json:"access_token"`
+	TokenType    string                     `json:"token_type"`
+	ExpiresIn    int                        `json:"expires_in"`
+	ExpiresAt    time.Time                  `json:"expires_at"`
+	AgentID      uuid.UUID                  `json:"agent_id"`
+	CredentialID uuid.UUID                  `json:"credential_id"`
+	Replacement  *SealedEmergencyCredential `json:"replacement,omitempty"`
+}
+
+// EmergencyAccessService manages sealed break-glass credentials that restore API access for
+// critical agents whose SDK token refresh chain is broken
+type EmergencyAccessService struct {
+	credentialRepo domain.EmergencyCredentialRepository
+	agentRepo      domain.AgentRepository
+	userRepo       domain.UserRepository
+	alertRepo      domain.AlertRepository
+	jwtService     *auth.JWTService
+}
+
+// NewEmergencyAccessService creates a new emergency access service
+func NewEmergencyAccessService(
+	credentialRepo domain.EmergencyCredentialRepository,
+	agentRepo domain.AgentRepository,
+	userRepo domain.UserRepository,
+	alertRepo domain.AlertRepository,
+	jwtService *auth.JWTService,
+) *EmergencyAccessService {
+	return &EmergencyAccessService{
+		credentialRepo: credentialRepo,
+		agentRepo:      agentRepo,
+		userRepo:       userRepo,
+		alertRepo:      alertRepo,
+		jwtService:     jwtService,
+	}
+}
+
+// GenerateCredential creates a single-use emergency credential for the agent. Emergency access
+// tokens are issued to userID, the admin pre-generating the credential.
+func (s *EmergencyAccessService) GenerateCredential(
+	ctx context.Context,
+	agent *domain.Agent,
+	req *GenerateEmergencyCredentialRequest,
+	userID uuid.UUID,
+) (*SealedEmergencyCredential, error) {
+	validDays := req.ValidDays
+	if validDays == 0 {
+		validDays = DefaultEmergencyCredentialValidDays
+	}
+	if validDays < 0 || validDays > MaxEmergencyCredentialValidDays {
+		return nil, fmt.Errorf("%w: valid_days must be between 1 and %d", ErrInvalidEmergencyCredentialRequest, MaxEmergencyCredentialValidDays)
+	}
+
+	return s.sealCredential(agent.OrganizationID, agent.ID, userID, req.SealingPublicKey, time.Duration(validDays)*24*time.Hour, nil)
+}
+
+// ListCredentials returns the agent's emergency credentials with expiry applied to their status
+func (s *EmergencyAccessService) ListCredentials(ctx context.Context, agentID uuid.UUID) ([]*domain.EmergencyCredential, error) {
+	credentials, err := s.credentialRepo.GetByAgent(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	for _, credential := range credentials {
+		credential.Status = credential.EffectiveStatus(now)
+	}
+	return credentials, nil
+}
+
+// GetCredential retrieves an emergency credential by ID
+func (s *EmergencyAccessService) GetCredential(ctx context.Context, id uuid.UUID) (*domain.EmergencyCredential, error) {
+	credential, err := s.credentialRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	credential.Status = credential.EffectiveStatus(time.Now().UTC())
+	return credential, nil
+}
+
+// RevokeCredential revokes an unused emergency credential
+func (s *EmergencyAccessService) RevokeCredential(ctx context.Context, id uuid.UUID, reason string) error {
+	if reason == "" {
+		reason = "revoked by administrator"
+	}
+	return s.credentialRepo.Revoke(id, reason)
+}
+
+// Activate is the break-glass path. It uses up the credential, issues an access token that
+// expires after EmergencyAccessTTL, raises a critical alert and rotates the credential by
+// sealing a replacement to the same organization key. The credential is returned whenever it
+// was identified, even on failure, so the attempt can be audited against its organization.
+func (s *EmergencyAccessService) Activate(
+	ctx context.Context,
+	secret string,
+	reason string,
+	ipAddress string,
+) (*EmergencyAccessGrant, *domain.EmergencyCredential, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, nil, ErrEmergencyReasonRequired
+	}
+
+	credential, err := s.credentialRepo.GetBySecretHash(hashEmergencySecret(secret))
+	if err != nil {
+		return nil, nil, ErrEmergencyCredentialInvalid
+	}
+
+	agent, err := s.agentRepo.GetByID(credential.AgentID)
+	if err != nil || agent.Status == domain.AgentStatusRevoked {
+		return nil, credential, ErrEmergencyCredentialInvalid
+	}
+	user, err := s.userRepo.GetByID(credential.UserID)
+	if err != nil || (user.Status != "" && user.Status != domain.UserStatusActive) {
+		return nil, credential, ErrEmergencyCredentialInvalid
+	}
+
+	now := time.Now().UTC()
+	accessExpiresAt := now.Add(EmergencyAccessTTL)
+	activated, err := s.credentialRepo.Activate(credential.ID, now, accessExpiresAt, ipAddress, reason)
+	if err != nil {
+		return nil, credential, fmt.Errorf("failed to activate emergency credential: %w", err)
+	}
+	if !activated {
+		// Re-read so a concurrent activation is reported as a second use
+		if current, err := s.credentialRepo.GetByID(credential.ID); err == nil {
+			credential = current
+		}
+		if credential.Status == domain.EmergencyCredentialStatusActivated {
+			return nil, credential, ErrEmergencyCredentialUsed
+		}
+		return nil, credential, ErrEmergencyCredentialInvalid
+	}
+	credential.Status = domain.EmergencyCredentialStatusActivated
+	credential.ActivatedAt = &now
+	credential.AccessExpiresAt = &accessExpiresAt
+	credential.ActivatedIP = &ipAddress
+	credential.ActivationReason = &reason
+
+	accessToken, err := s.jwtService.GenerateAccessTokenWithTTL(
+		user.ID.String(),
+		user.OrganizationID.String(),
+		user.Email,
+		string(user.Role),
+		EmergencyAccessTTL,
+	)
+	if err != nil {
+		return nil, credential, fmt.Errorf("failed to generate emergency access token: %w", err)
+	}
+
+	grant := &EmergencyAccessGrant{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(EmergencyAccessTTL.Seconds()),
+		ExpiresAt:    accessExpiresAt,
+		AgentID:      agent.ID,
+		CredentialID: credential.ID,
+	}
+
+	// Forced rotation: a used credential is never valid again, so a replacement is sealed to the
+	// same organization key with the same validity. Only the key holder can open it.
+	validity := credential.ExpiresAt.Sub(credential.CreatedAt)
+	replacement, err := s.sealCredential(credential.OrganizationID, agent.ID, credential.UserID, credential.SealingPublicKey, validity, &credential.ID)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to rotate emergency credential %s: %v\n", credential.ID, err)
+	} else {
+		grant.Replacement = replacement
+	}
+
+	if err := s.raiseActivationAlert(agent, credential, reason); err != nil {
+		fmt.Printf("⚠️  Failed to create emergency access alert: %v\n", err)
+	}
+
+	return grant, credential, nil
+}
+
+// sealCredential generates a secret, stores its hash and seals it to the organization key
+func (s *EmergencyAccessService) sealCredential(
+	orgID, agentID, userID uuid.UUID,
+	sealingPublicKey string,
+	validity time.Duration,
+	rotatedFrom *uuid.UUID,
+) (*SealedEmergencyCredential, error) {
+	sealingPublicKey = strings.TrimSpace(sealingPublicKey)
+	publicKey, err := parseSealingPublicKey(sealingPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate emergency secret: %w", err)
+	}
+	secret := emergencyCredentialPrefix + hex.EncodeToString(raw)
+
+	sealed, err := box.SealAnonymous(nil, []byte(secret), publicKey, rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal emergency secret: %w", err)
+	}
+
+	fingerprint := sha256.Sum256(publicKey[:])
+	now := time.Now().UTC()
+	credential := &domain.EmergencyCredential{
+		ID:               uuid.New(),
+		OrganizationID:   orgID,
+		AgentID:          agentID,
+		UserID:           userID,
+		SecretHash:       hashEmergencySecret(secret),
+		SealingPublicKey: sealingPublicKey,
+		KeyFingerprint:   hex.EncodeToString(fingerprint[:]),
+		Status:           domain.EmergencyCredentialStatusActive,
+		ExpiresAt:        now.Add(validity),
+		RotatedFrom:      rotatedFrom,
+		CreatedBy:        userID,
+		CreatedAt:        now,
+	}
+	if err := s.credentialRepo.Create(credential); err != nil {
+		return nil, fmt.Errorf("failed to store emergency credential: %w", err)
+	}
+
+	return &SealedEmergencyCredential{
+		Credential:   credential,
+		SealedSecret: base64.StdEncoding.EncodeToString(sealed),
+	}, nil
+}
+
+// raiseActivationAlert tells admins that break-glass access was used and what must be rotated
+func (s *EmergencyAccessService) raiseActivationAlert(agent *domain.Agent, credential *domain.EmergencyCredential, reason string) error {
+	message := fmt.Sprintf("Emergency access was activated for agent '%s' from %s.\n\n", agent.Name, *credential.ActivatedIP)
+	message += fmt.Sprintf("**Reason:** %s\n", reason)
+	message += fmt.Sprintf("**Access expires:** %s\n", credential.AccessExpiresAt.Format(time.RFC3339))
+	message += "\n**Recommended Actions:**\n"
+	message += "1. Confirm the activation was authorized\n"
+	message += "2. Issue fresh SDK credentials for the agent before emergency access expires\n"
+	message += "3. Store the replacement sealed credential returned by the activation\n"
+
+	return s.alertRepo.Create(&domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: credential.OrganizationID,
+		AlertType:      domain.AlertEmergencyAccessUsed,
+		Severity:       domain.AlertSeverityCritical,
+		Title:          fmt.Sprintf("Emergency Access Activated: %s", agent.Name),
+		Description:    message,
+		ResourceType:   "agent",
+		ResourceID:     agent.ID,
+		CreatedAt:      time.Now(),
+	})
+}
+
+// parseSealingPublicKey decodes a base64 X25519 public key
+func parseSealingPublicKey(value string) (*[32]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(raw) != 32 {
+		return nil, fmt.Errorf("%w: sealing_public_key must be a base64-encoded 32-byte X25519 public key", ErrInvalidEmergencyCredentialRequest)
+	}
+
+	var publicKey [32]byte
+	copy(publicKey[:], raw)
+	return &publicKey, nil
+}
+
+func hashEmergencySecret(secret string) string {
+	hash := sha256.Sum256([]byte(strings.TrimSpace(secret)))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,124 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/nacl/box"
+)
+
+func TestEmergencyCredentialsAreSealedSingleUseAndRotated(t *testing.T) {
+	t.Setenv("JWT_SECRET", "emergency-test-secret")
+	jwtService := auth.NewJWTService()
+
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	admin := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(admin))
+	agent := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(agent))
+	ctx := context.Background()
+	service := NewEmergencyAccessService(repos.EmergencyCredential, repos.Agent, repos.User, repos.Alert, jwtService)
+
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sealingKey := base64.StdEncoding.EncodeToString(publicKey[:])
+	open := func(sealed *SealedEmergencyCredential) string {
+		ciphertext, err := base64.StdEncoding.DecodeString(sealed.SealedSecret)
+		require.NoError(t, err)
+		secret, ok := box.OpenAnonymous(nil, ciphertext, publicKey, privateKey)
+		require.True(t, ok, "only the organization key opens the sealed secret")
+		return string(secret)
+	}
+
+	_, err = service.GenerateCredential(ctx, agent, &GenerateEmergencyCredentialRequest{SealingPublicKey: "not-a-key"}, admin.ID)
+	assert.ErrorIs(t, err, ErrInvalidEmergencyCredentialRequest)
+	_, err = service.GenerateCredential(ctx, agent, &GenerateEmergencyCredentialRequest{SealingPublicKey: sealingKey, ValidDays: 400}, admin.ID)
+	assert.ErrorIs(t, err, ErrInvalidEmergencyCredentialRequest)
+
+	sealed, err := service.GenerateCredential(ctx, agent, &GenerateEmergencyCredentialRequest{SealingPublicKey: sealingKey}, admin.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EmergencyCredentialStatusActive, sealed.Credential.Status)
+	assert.WithinDuration(t, time.Now().Add(90*24*time.Hour), sealed.Credential.ExpiresAt, time.Minute)
+	secret := open(sealed)
+	assert.Regexp(t, `^aim_ec_[0-9a-f]{64}$`, secret)
+	secretHash := sha256.Sum256([]byte(secret))
+	assert.Equal(t, hex.EncodeToString(secretHash[:]), sealed.Credential.SecretHash, "only the hash is stored")
+
+	// Break-glass activation
+	_, _, err = service.Activate(ctx, secret, " ", "203.0.113.7")
+	assert.ErrorIs(t, err, ErrEmergencyReasonRequired)
+	_, _, err = service.Activate(ctx, "aim_ec_unknown", "refresh broken", "203.0.113.7")
+	assert.ErrorIs(t, err, ErrEmergencyCredentialInvalid)
+
+	grant, credential, err := service.Activate(ctx, secret, "refresh token chain broken after key rotation", "203.0.113.7")
+	require.NoError(t, err)
+	assert.Equal(t, sealed.Credential.ID, credential.ID)
+	assert.Equal(t, agent.ID, grant.AgentID)
+	assert.Equal(t, int(EmergencyAccessTTL.Seconds()), grant.ExpiresIn)
+
+	claims, err := jwtService.ValidateToken(grant.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, admin.ID.String(), claims.UserID)
+	assert.WithinDuration(t, time.Now().Add(EmergencyAccessTTL), claims.ExpiresAt.Time, time.Minute)
+
+	// Single use
+	_, credential, err = service.Activate(ctx, secret, "second try", "203.0.113.7")
+	assert.ErrorIs(t, err, ErrEmergencyCredentialUsed)
+	assert.Equal(t, org.ID, credential.OrganizationID, "failed attempts on known credentials can be audited")
+
+	// Forced rotation: a replacement is sealed to the same key
+	require.NotNil(t, grant.Replacement)
+	replacement := grant.Replacement.Credential
+	assert.Equal(t, &sealed.Credential.ID, replacement.RotatedFrom)
+	assert.Equal(t, sealed.Credential.KeyFingerprint, replacement.KeyFingerprint)
+	replacementSecret := open(grant.Replacement)
+	assert.NotEqual(t, secret, replacementSecret)
+
+	alerts, err := repos.Alert.GetByResourceID(agent.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domain.AlertEmergencyAccessUsed, alerts[0].AlertType)
+	assert.Equal(t, domain.AlertSeverityCritical, alerts[0].Severity)
+
+	// Revoked and expired credentials cannot be activated
+	require.NoError(t, service.RevokeCredential(ctx, replacement.ID, ""))
+	_, _, err = service.Activate(ctx, replacementSecret, "refresh broken", "203.0.113.7")
+	assert.ErrorIs(t, err, ErrEmergencyCredentialInvalid)
+
+	expiredSecret := "aim_ec_expired"
+	expiredHash := sha256.Sum256([]byte(expiredSecret))
+	require.NoError(t, repos.EmergencyCredential.Create(&domain.EmergencyCredential{
+		OrganizationID: org.ID,
+		AgentID:        agent.ID,
+		UserID:         admin.ID,
+		SecretHash:     hex.EncodeToString(expiredHash[:]),
+		Status:         domain.EmergencyCredentialStatusActive,
+		ExpiresAt:      time.Now().Add(-time.Minute),
+		CreatedBy:      admin.ID,
+	}))
+	_, _, err = service.Activate(ctx, expiredSecret, "refresh broken", "203.0.113.7")
+	assert.ErrorIs(t, err, ErrEmergencyCredentialInvalid)
+
+	credentials, err := service.ListCredentials(ctx, agent.ID)
+	require.NoError(t, err)
+	statuses := map[domain.EmergencyCredentialStatus]int{}
+	for _, c := range credentials {
+		statuses[c.Status]++
+	}
+	assert.Equal(t, map[domain.EmergencyCredentialStatus]int{
+		domain.EmergencyCredentialStatusActivated: 1,
+		domain.EmergencyCredentialStatusRevoked:   1,
+		domain.EmergencyCredentialStatusExpired:   1,
+	}, statuses)
+}
@@ -0,0 +1,122 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// EntitlementService builds "who can access what" views for periodic access reviews
+type EntitlementService struct {
+	entitlementRepo domain.EntitlementRepository
+	mcpRepo         domain.MCPServerRepository
+}
+
+// NewEntitlementService creates a new entitlement service
+func NewEntitlementService(
+	entitlementRepo domain.EntitlementRepository,
+	mcpRepo domain.MCPServerRepository,
+) *EntitlementService {
+	return &EntitlementService{
+		entitlementRepo: entitlementRepo,
+		mcpRepo:         mcpRepo,
+	}
+}
+
+// ReviewMCPServer lists every agent currently authorized to use an MCP server of the organization
+func (s *EntitlementService) ReviewMCPServer(ctx context.Context, orgID, mcpServerID uuid.UUID) (*domain.EntitlementReview, error) {
+	server, err := s.mcpRepo.GetByID(mcpServerID)
+	if err != nil || server.OrganizationID != orgID {
+		return nil, fmt.Errorf("mcp server not found")
+	}
+
+	rows, err := s.entitlementRepo.GetMCPServerEntitlements(orgID, server)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEntitlementReview(orgID, domain.EntitlementResourceMCPServer, &server.ID, server.Name, rows), nil
+}
+
+// ReviewCapability lists every agent currently holding a capability
+func (s *EntitlementService) ReviewCapability(ctx context.Context, orgID uuid.UUID, capabilityType string) (*domain.EntitlementReview, error) {
+	capabilityType = strings.TrimSpace(capabilityType)
+	if capabilityType == "" {
+		return nil, fmt.Errorf("capability is required")
+	}
+
+	rows, err := s.entitlementRepo.GetCapabilityEntitlements(orgID, capabilityType)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEntitlementReview(orgID, domain.EntitlementResourceCapability, nil, capabilityType, rows), nil
+}
+
+func newEntitlementReview(
+	orgID uuid.UUID,
+	resourceType domain.EntitlementResourceType,
+	resourceID *uuid.UUID,
+	resourceName string,
+	rows []*domain.Entitlement,
+) *domain.EntitlementReview {
+	entitlements := mergeEntitlements(rows)
+	return &domain.EntitlementReview{
+		OrganizationID: orgID,
+		ResourceType:   resourceType,
+		ResourceID:     resourceID,
+		ResourceName:   resourceName,
+		Entitlements:   entitlements,
+		Total:          len(entitlements),
+		GeneratedAt:    time.Now().UTC(),
+	}
+}
+
+// mergeEntitlements folds the per-grant rows into one entry per agent, keeping row order,
+// collecting every grant and the most recent use
+func mergeEntitlements(rows []*domain.Entitlement) []*domain.Entitlement {
+	byAgent := make(map[uuid.UUID]*domain.Entitlement)
+	merged := make([]*domain.Entitlement, 0, len(rows))
+
+	for _, row := range rows {
+		existing, ok := byAgent[row.AgentID]
+		if !ok {
+			entry := *row
+			entry.Grants = append([]domain.EntitlementGrant(nil), row.Grants...)
+			byAgent[row.AgentID] = &entry
+			merged = append(merged, &entry)
+			continue
+		}
+
+		for _, grant := range row.Grants {
+			if !hasEntitlementGrant(existing.Grants, grant) {
+				existing.Grants = append(existing.Grants, grant)
+			}
+		}
+		if row.LastUsedAt != nil && (existing.LastUsedAt == nil || row.LastUsedAt.After(*existing.LastUsedAt)) {
+			existing.LastUsedAt = row.LastUsedAt
+		}
+	}
+
+	return merged
+}
+
+// hasEntitlementGrant reports whether the same grant (type and reference) is already listed
+func hasEntitlementGrant(grants []domain.EntitlementGrant, grant domain.EntitlementGrant) bool {
+	for _, g := range grants {
+		if g.GrantType != grant.GrantType {
+			continue
+		}
+		if g.ReferenceID == nil && grant.ReferenceID == nil {
+			return true
+		}
+		if g.ReferenceID != nil && grant.ReferenceID != nil && *g.ReferenceID == *grant.ReferenceID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeEntitlements(t *testing.T) {
+	agentA := uuid.New()
+	agentB := uuid.New()
+	connectionID := uuid.New()
+	older := time.Now().Add(-48 * time.Hour)
+	newer := time.Now().Add(-time.Hour)
+
+	rows := []*domain.Entitlement{
+		{AgentID: agentA, AgentName: "alpha", LastUsedAt: &older,
+			Grants: []domain.EntitlementGrant{{GrantType: domain.EntitlementGrantRegistration}}},
+		{AgentID: agentA, AgentName: "alpha", LastUsedAt: &newer,
+			Grants: []domain.EntitlementGrant{{GrantType: domain.EntitlementGrantAttestation, ReferenceID: &connectionID}}},
+		{AgentID: agentA, AgentName: "alpha",
+			Grants: []domain.EntitlementGrant{{GrantType: domain.EntitlementGrantAttestation, ReferenceID: &connectionID}}},
+		{AgentID: agentB, AgentName: "beta",
+			Grants: []domain.EntitlementGrant{{GrantType: domain.EntitlementGrantCapabilityRequest}}},
+	}
+
+	merged := mergeEntitlements(rows)
+	require.Len(t, merged, 2)
+
+	assert.Equal(t, agentA, merged[0].AgentID, "row order is preserved")
+	assert.Len(t, merged[0].Grants, 2, "duplicate grants are collapsed")
+	if assert.NotNil(t, merged[0].LastUsedAt) {
+		assert.True(t, merged[0].LastUsedAt.Equal(newer), "most recent use wins")
+	}
+
+	assert.Equal(t, agentB, merged[1].AgentID)
+	assert.Nil(t, merged[1].LastUsedAt)
+	assert.Len(t, rows[0].Grants, 1, "input rows are not modified")
+}
+
+func TestEntitlementsDerivedFromRepositories(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	owner := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(owner))
+
+	server := testsupport.NewMCPServer(org.ID)
+	require.NoError(t, repos.MCPServer.Create(server))
+
+	declared := testsupport.NewAgent(org.ID, func(a *domain.Agent) {
+		a.Name = "declared"
+		a.CreatedBy = owner.ID
+		a.TalksTo = []string{server.Name}
+	})
+	attested := testsupport.NewAgent(org.ID, func(a *domain.Agent) {
+		a.Name = "attested"
+		a.CreatedBy = owner.ID
+	})
+	require.NoError(t, repos.Agent.Create(declared))
+	require.NoError(t, repos.Agent.Create(attested))
+
+	require.NoError(t, repos.MCPAttestation.CreateConnection(&domain.AgentMCPConnection{
+		AgentID:        attested.ID,
+		MCPServerID:    server.ID,
+		ConnectionType: domain.ConnectionTypeAttested,
+		IsActive:       true,
+	}))
+	require.NoError(t, repos.MCPAttestation.CreateAttestation(testsupport.NewMCPAttestation(server, attested)))
+
+	service := NewEntitlementService(repos.Entitlement, repos.MCPServer)
+	review, err := service.ReviewMCPServer(context.Background(), org.ID, server.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, review.Total)
+
+	assert.Equal(t, "attested", review.Entitlements[0].AgentName)
+	assert.Equal(t, domain.EntitlementGrantAttestation, review.Entitlements[0].Grants[0].GrantType)
+	assert.NotNil(t, review.Entitlements[0].LastUsedAt)
+	assert.Equal(t, owner.Email, review.Entitlements[0].OwnerEmail)
+
+	assert.Equal(t, "declared", review.Entitlements[1].AgentName)
+	assert.Equal(t, domain.EntitlementGrantRegistration, review.Entitlements[1].Grants[0].GrantType)
+	assert.Nil(t, review.Entitlements[1].LastUsedAt)
+}
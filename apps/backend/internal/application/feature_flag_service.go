@@ -0,0 +1,291 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ErrFeatureOptInNotAllowed is returned when a user chooses a feature flag that only organizations may turn on
+var ErrFeatureOptInNotAllowed = errors.New("feature flag does not allow user opt-in")
+
+// featureFlagsTTL is how long flags and an organization's overrides are cached. Changes made on
+// another server instance show up here within it.
+const featureFlagsTTL = 30 * time.Second
+
+// FeatureFlagService gates preview features per organization and per user.
+//
+// A flag is off for a user when their organization turned it off. Otherwise the user's own
+// choice counts, if the flag allows user opt-in, then the organization's, then the flag's default.
+// SDKs are told the organization's state of SDK-visible flags. Platform operators can force a
+// feature on or off for the whole deployment, which overrides every other choice.
+type FeatureFlagService struct {
+	flagRepo  domain.FeatureFlagRepository
+	flags     []*domain.FeatureFlag
+	flagsAt   time.Time
+	overrides map[uuid.UUID]*orgFeatureOverrides
+	forced    map[string]bool // Deployment-wide states, by flag key
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+// orgFeatureOverrides is an organization's cached overrides
+type orgFeatureOverrides struct {
+	overrides []*domain.FeatureFlagOverride
+	loadedAt  time.Time
+}
+
+// SetFeatureRequest turns a feature on or off
+type SetFeatureRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// NewFeatureFlagService creates a new feature flag service
+func NewFeatureFlagService(flagRepo domain.FeatureFlagRepository) *FeatureFlagService {
+	return &FeatureFlagService{
+		flagRepo:  flagRepo,
+		overrides: make(map[uuid.UUID]*orgFeatureOverrides),
+		forced:    make(map[string]bool),
+		now:       time.Now,
+	}
+}
+
+// ListFeatures returns the state of every feature flag for the user
+func (s *FeatureFlagService) ListFeatures(ctx context.Context, orgID, userID uuid.UUID) ([]*domain.FeatureState, error) {
+	return s.evaluate(orgID, &userID, false)
+}
+
+// ListSDKFeatures returns the organization's state of the flags SDKs are told about
+func (s *FeatureFlagService) ListSDKFeatures(ctx context.Context, orgID uuid.UUID) ([]*domain.FeatureState, error) {
+	return s.evaluate(orgID, nil, true)
+}
+
+// IsEnabled reports whether a feature is on for the organization, or for the user when userID is
+// set. Unknown flags, and flags that cannot be loaded, are off.
+func (s *FeatureFlagService) IsEnabled(ctx context.Context, orgID uuid.UUID, userID *uuid.UUID, key string) bool {
+	states, err := s.evaluate(orgID, userID, false)
+	if err != nil {
+		log.Printf("⚠️  Failed to evaluate feature flag %s: %v", key, err)
+		return false
+	}
+	for _, state := range states {
+		if state.Key == key {
+			return state.Enabled
+		}
+	}
+	return false
+}
+
+// SetOrganizationFeature turns a feature on or off for the organization
+func (s *FeatureFlagService) SetOrganizationFeature(ctx context.Context, orgID, actorID uuid.UUID, key string, enabled bool) (*domain.FeatureState, error) {
+	if _, err := s.flag(key); err != nil {
+		return nil, err
+	}
+	return s.setOverride(orgID, nil, actorID, key, enabled)
+}
+
+// ClearOrganizationFeature returns the organization to the flag's default
+func (s *FeatureFlagService) ClearOrganizationFeature(ctx context.Context, orgID uuid.UUID, key string) error {
+	if _, err := s.flag(key); err != nil {
+		return err
+	}
+	return s.deleteOverride(orgID, nil, key)
+}
+
+// SetUserFeature records the user's choice for a feature flag that allows user opt-in
+func (s *FeatureFlagService) SetUserFeature(ctx context.Context, orgID, userID uuid.UUID, key string, enabled bool) (*domain.FeatureState, error) {
+	flag, err := s.flag(key)
+	if err != nil {
+		return nil, err
+	}
+	if !flag.UserOptIn {
+		return nil, ErrFeatureOptInNotAllowed
+	}
+	return s.setOverride(orgID, &userID, userID, key, enabled)
+}
+
+// ClearUserFeature drops the user's choice, leaving the feature to their organization
+func (s *FeatureFlagService) ClearUserFeature(ctx context.Context, orgID, userID uuid.UUID, key string) error {
+	if _, err := s.flag(key); err != nil {
+		return err
+	}
+	return s.deleteOverride(orgID, &userID, key)
+}
+
+func (s *FeatureFlagService) setOverride(orgID uuid.UUID, userID *uuid.UUID, actorID uuid.UUID, key string, enabled bool) (*domain.FeatureState, error) {
+	override := &domain.FeatureFlagOverride{
+		FlagKey:        key,
+		OrganizationID: orgID,
+		UserID:         userID,
+		Enabled:        enabled,
+		UpdatedBy:      &actorID,
+		UpdatedAt:      s.now().UTC(),
+	}
+	if err := s.flagRepo.SetOverride(override); err != nil {
+		return nil, fmt.Errorf("failed to save feature flag override: %w", err)
+	}
+	s.forget(orgID)
+
+	states, err := s.evaluate(orgID, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, state := range states {
+		if state.Key == key {
+			return state, nil
+		}
+	}
+	return nil, domain.ErrFeatureFlagNotFound
+}
+
+func (s *FeatureFlagService) deleteOverride(orgID uuid.UUID, userID *uuid.UUID, key string) error {
+	if err := s.flagRepo.DeleteOverride(key, orgID, userID); err != nil {
+		return fmt.Errorf("failed to delete feature flag override: %w", err)
+	}
+	s.forget(orgID)
+	return nil
+}
+
+// ListFlags returns every feature flag
+func (s *FeatureFlagService) ListFlags() ([]*domain.FeatureFlag, error) {
+	return s.loadFlags()
+}
+
+// ForceFeature turns a feature on or off for every organization and user of the deployment;
+// a nil enabled lifts the forced state
+func (s *FeatureFlagService) ForceFeature(key string, enabled *bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if enabled == nil {
+		delete(s.forced, key)
+	} else {
+		s.forced[key] = *enabled
+	}
+}
+
+// evaluate returns the state of the flags for the organization, or for the user when userID is set
+func (s *FeatureFlagService) evaluate(orgID uuid.UUID, userID *uuid.UUID, sdkOnly bool) ([]*domain.FeatureState, error) {
+	flags, err := s.loadFlags()
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.loadOverrides(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	forced := make(map[string]bool, len(s.forced))
+	for key, enabled := range s.forced {
+		forced[key] = enabled
+	}
+	s.mu.Unlock()
+
+	orgChoices := make(map[string]bool)
+	userChoices := make(map[string]bool)
+	for _, override := range overrides {
+		switch {
+		case override.UserID == nil:
+			orgChoices[override.FlagKey] = override.Enabled
+		case userID != nil && *override.UserID == *userID:
+			userChoices[override.FlagKey] = override.Enabled
+		}
+	}
+
+	states := make([]*domain.FeatureState, 0, len(flags))
+	for _, flag := range flags {
+		if sdkOnly && !flag.SDKVisible {
+			continue
+		}
+		state := &domain.FeatureState{
+			Key:         flag.Key,
+			Description: flag.Description,
+			Enabled:     flag.DefaultEnabled,
+			Source:      domain.FeatureSourceDefault,
+			UserOptIn:   flag.UserOptIn,
+			SDKVisible:  flag.SDKVisible,
+		}
+		orgEnabled, orgChose := orgChoices[flag.Key]
+		userEnabled, userChose := userChoices[flag.Key]
+		forcedEnabled, isForced := forced[flag.Key]
+		switch {
+		case isForced:
+			state.Enabled, state.Source = forcedEnabled, domain.FeatureSourceDeployment
+		case orgChose && !orgEnabled:
+			state.Enabled, state.Source = false, domain.FeatureSourceOrganization
+		case userChose && flag.UserOptIn:
+			state.Enabled, state.Source = userEnabled, domain.FeatureSourceUser
+		case orgChose:
+			state.Enabled, state.Source = orgEnabled, domain.FeatureSourceOrganization
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// flag returns the flag with the key
+func (s *FeatureFlagService) flag(key string) (*domain.FeatureFlag, error) {
+	flags, err := s.loadFlags()
+	if err != nil {
+		return nil, err
+	}
+	for _, flag := range flags {
+		if flag.Key == key {
+			return flag, nil
+		}
+	}
+	return nil, domain.ErrFeatureFlagNotFound
+}
+
+// loadFlags returns the flags, from the cache while it is fresh
+func (s *FeatureFlagService) loadFlags() ([]*domain.FeatureFlag, error) {
+	s.mu.Lock()
+	if s.flags != nil && s.now().Sub(s.flagsAt) < featureFlagsTTL {
+		flags := s.flags
+		s.mu.Unlock()
+		return flags, nil
+	}
+	s.mu.Unlock()
+
+	flags, err := s.flagRepo.ListFlags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feature flags: %w", err)
+	}
+	s.mu.Lock()
+	s.flags, s.flagsAt = flags, s.now()
+	s.mu.Unlock()
+	return flags, nil
+}
+
+// loadOverrides returns the organization's overrides, from the cache while it is fresh
+func (s *FeatureFlagService) loadOverrides(orgID uuid.UUID) ([]*domain.FeatureFlagOverride, error) {
+	s.mu.Lock()
+	cached, ok := s.overrides[orgID]
+	if ok && s.now().Sub(cached.loadedAt) < featureFlagsTTL {
+		s.mu.Unlock()
+		return cached.overrides, nil
+	}
+	s.mu.Unlock()
+
+	overrides, err := s.flagRepo.ListOverrides(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feature flag overrides: %w", err)
+	}
+	s.mu.Lock()
+	s.overrides[orgID] = &orgFeatureOverrides{overrides: overrides, loadedAt: s.now()}
+	s.mu.Unlock()
+	return overrides, nil
+}
+
+// forget drops the organization's cached overrides after a change
+func (s *FeatureFlagService) forget(orgID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.overrides, orgID)
+	s.mu.Unlock()
+}
@@ -0,0 +1,126 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// DefaultIdempotencyWindow is how long completed requests are replayed without a configured window
+	DefaultIdempotencyWindow = 24 * time.Hour
+	// maxIdempotencyKeyLength bounds the keys clients send
+	maxIdempotencyKeyLength = 255
+)
+
+var (
+	// ErrInvalidIdempotencyKey is returned for empty or overlong keys
+	ErrInvalidIdempotencyKey = errors.New("idempotency key must be 1 to 255 characters")
+	// ErrIdempotencyKeyReused is returned when a key is sent again with a different request body
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request body")
+	// ErrIdempotentRequestInProgress is returned when the first request with the key has not completed yet
+	ErrIdempotentRequestInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// IdempotencyService makes mutating requests safe to retry. The first request with an
+// Idempotency-Key reserves the key for its organization and endpoint and records its response;
+// retries within the replay window get that response back instead of running again.
+type IdempotencyService struct {
+	repo   domain.IdempotencyRepository
+	window time.Duration
+	now    func() time.Time
+}
+
+// NewIdempotencyService creates a new idempotency service; a non-positive window uses
+// DefaultIdempotencyWindow
+func NewIdempotencyService(repo domain.IdempotencyRepository, window time.Duration) *IdempotencyService {
+	if window <= 0 {
+		window = DefaultIdempotencyWindow
+	}
+	return &IdempotencyService{repo: repo, window: window, now: time.Now}
+}
+
+// Begin starts a request made with an idempotency key. When the key was already used for the
+// same request and it completed, the original record is returned with replay set; otherwise
+// the key is reserved and the returned record must be passed to Complete or Release.
+func (s *IdempotencyService) Begin(ctx context.Context, orgID uuid.UUID, key, endpoint string, body []byte) (record *domain.IdempotencyRecord, replay bool, err error) {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return nil, false, ErrInvalidIdempotencyKey
+	}
+
+	hash := sha256.Sum256(body)
+	now := s.now().UTC()
+	record = &domain.IdempotencyRecord{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Key:            key,
+		Endpoint:       endpoint,
+		RequestHash:    hex.EncodeToString(hash[:]),
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(domain.IdempotencyLockTimeout),
+	}
+	reserved, err := s.repo.Reserve(record)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if reserved {
+		return record, false, nil
+	}
+
+	existing, err := s.repo.Get(orgID, key, endpoint)
+	if errors.Is(err, domain.ErrIdempotencyRecordNotFound) {
+		// The first request failed and released the key after our reservation attempt; a retry reserves it
+		return nil, false, ErrIdempotentRequestInProgress
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+	if existing.RequestHash != record.RequestHash {
+		return nil, false, ErrIdempotencyKeyReused
+	}
+	if !existing.Completed {
+		return nil, false, ErrIdempotentRequestInProgress
+	}
+	return existing, true, nil
+}
+
+// Complete records the response of a request started with Begin. Server errors are not
+// recorded: the key is released so the client can retry.
+func (s *IdempotencyService) Complete(ctx context.Context, record *domain.IdempotencyRecord, statusCode int, contentType string, body []byte) {
+	if statusCode >= 500 {
+		s.Release(ctx, record)
+		return
+	}
+
+	record.Completed = true
+	record.StatusCode = statusCode
+	record.ContentType = contentType
+	record.ResponseBody = append([]byte(nil), body...)
+	record.ExpiresAt = record.CreatedAt.Add(s.window)
+	if err := s.repo.Complete(record); err != nil {
+		log.Printf("⚠️  Failed to record response of idempotency key %q: %v", record.Key, err)
+	}
+}
+
+// Release frees the key of a request that failed, so a retry runs it again
+func (s *IdempotencyService) Release(ctx context.Context, record *domain.IdempotencyRecord) {
+	if err := s.repo.Delete(record.ID); err != nil {
+		log.Printf("⚠️  Failed to release idempotency key %q: %v", record.Key, err)
+	}
+}
+
+// PurgeExpired removes the records past their replay window (background job)
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int, error) {
+	purged, err := s.repo.DeleteExpired(s.now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency records: %w", err)
+	}
+	return purged, nil
+}
@@ -0,0 +1,182 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncidentsAreWorkedThroughATimelineWithSeveritySLAs(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	responder := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(responder))
+	analyst := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(analyst))
+	outsider := testsupport.NewUser(testsupport.NewOrganization().ID)
+	require.NoError(t, repos.User.Create(outsider))
+	agent := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(agent))
+
+	emails := &recordingEmailService{}
+	service := NewIncidentService(repos.Security, repos.Incident, repos.Alert, repos.VerificationEvent, repos.User, emails, nil)
+	ctx := context.Background()
+
+	// A critical incident opened 20 minutes ago is already past its 15 minute response SLA
+	incident := &domain.SecurityIncident{
+		ID:             uuid.New(),
+		OrganizationID: org.ID,
+		IncidentType:   "agent_compromised",
+		Severity:       domain.AlertSeverityCritical,
+		Title:          "Agent compromised",
+		CreatedAt:      time.Now().UTC().Add(-20 * time.Minute),
+	}
+	require.NoError(t, repos.Security.CreateIncident(incident))
+
+	detail, err := service.GetIncident(ctx, org.ID, incident.ID)
+	require.NoError(t, err)
+	assert.True(t, detail.SLA.Response.Breached)
+	assert.Nil(t, detail.SLA.Response.MetAt)
+	assert.Negative(t, detail.SLA.Response.RemainingMs)
+	assert.False(t, detail.SLA.Resolution.Breached)
+	assert.Equal(t, incident.CreatedAt.Add(4*time.Hour), detail.SLA.Resolution.DueAt)
+	_, err = service.GetIncident(ctx, uuid.New(), incident.ID)
+	assert.ErrorIs(t, err, ErrIncidentNotFound)
+
+	// The breach is recorded once, on the timeline and as an alert on the incident
+	breaches, err := service.EvaluateSLAs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, breaches)
+	breaches, err = service.EvaluateSLAs(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, breaches)
+	alerts, err := repos.Alert.GetUnacknowledgedByResourceID(incident.ID)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domain.AlertIncidentSLABreached, alerts[0].AlertType)
+	assert.Equal(t, domain.AlertSeverityCritical, alerts[0].Severity)
+
+	// Assignment is limited to the organization's users and emails the assignee
+	_, err = service.AssignIncident(ctx, org.ID, incident.ID, responder.ID, &outsider.ID)
+	assert.ErrorIs(t, err, ErrIncidentAssigneeNotFound)
+	detail, err = service.AssignIncident(ctx, org.ID, incident.ID, responder.ID, &analyst.ID)
+	require.NoError(t, err)
+	require.NotNil(t, detail.AssignedTo)
+	assert.Equal(t, analyst.ID, *detail.AssignedTo)
+	require.Len(t, emails.emails, 1)
+	assert.Equal(t, analyst.Email, emails.emails[0].to)
+	assert.Contains(t, emails.emails[0].subject, "Agent compromised")
+	_, err = service.AssignIncident(ctx, org.ID, incident.ID, responder.ID, &analyst.ID)
+	require.NoError(t, err)
+	assert.Len(t, emails.emails, 1, "reassigning to the same user sends nothing")
+
+	// Investigating acknowledges the incident, late
+	detail, err = service.ChangeStatus(ctx, org.ID, incident.ID, analyst.ID, domain.IncidentStatusInvestigating, "")
+	require.NoError(t, err)
+	require.NotNil(t, detail.AcknowledgedAt)
+	require.NotNil(t, detail.SLA.Response.MetAt)
+	assert.True(t, detail.SLA.Response.Breached)
+	_, err = service.ChangeStatus(ctx, org.ID, incident.ID, analyst.ID, "closed", "")
+	assert.ErrorIs(t, err, ErrInvalidIncidentStatus)
+
+	// Threats, anomalies and verification events of the organization can be linked once
+	threat := testsupport.NewAlert(org.ID, agent.ID)
+	require.NoError(t, repos.Alert.Create(threat))
+	anomaly := &domain.Anomaly{
+		ID:             uuid.New(),
+		OrganizationID: org.ID,
+		AnomalyType:    domain.AnomalyTypeAbnormalTraffic,
+		Severity:       domain.AlertSeverityHigh,
+		Title:          "Verification rate spike",
+		ResourceType:   "agent",
+		ResourceID:     agent.ID,
+	}
+	require.NoError(t, repos.Security.CreateAnomaly(anomaly))
+	event := testsupport.NewVerificationEvent(agent)
+	require.NoError(t, repos.VerificationEvent.Create(event))
+	foreignThreat := testsupport.NewAlert(uuid.New(), agent.ID)
+	require.NoError(t, repos.Alert.Create(foreignThreat))
+
+	link, err := service.LinkResource(ctx, org.ID, incident.ID, analyst.ID, domain.IncidentLinkThreat, threat.ID)
+	require.NoError(t, err)
+	assert.Equal(t, threat.Title, link.Summary)
+	_, err = service.LinkResource(ctx, org.ID, incident.ID, analyst.ID, domain.IncidentLinkAnomaly, anomaly.ID)
+	require.NoError(t, err)
+	_, err = service.LinkResource(ctx, org.ID, incident.ID, analyst.ID, domain.IncidentLinkVerificationEvent, event.ID)
+	require.NoError(t, err)
+	_, err = service.LinkResource(ctx, org.ID, incident.ID, analyst.ID, domain.IncidentLinkThreat, threat.ID)
+	assert.ErrorIs(t, err, domain.ErrIncidentLinkExists)
+	_, err = service.LinkResource(ctx, org.ID, incident.ID, analyst.ID, domain.IncidentLinkThreat, foreignThreat.ID)
+	assert.ErrorIs(t, err, ErrIncidentResourceNotFound)
+	_, err = service.LinkResource(ctx, org.ID, incident.ID, analyst.ID, "agent", agent.ID)
+	assert.ErrorIs(t, err, ErrInvalidIncidentLinkType)
+
+	require.NoError(t, service.UnlinkResource(ctx, org.ID, incident.ID, analyst.ID, domain.IncidentLinkAnomaly, anomaly.ID))
+	assert.ErrorIs(t, service.UnlinkResource(ctx, org.ID, incident.ID, analyst.ID, domain.IncidentLinkAnomaly, anomaly.ID), domain.ErrIncidentLinkNotFound)
+	detail, err = service.GetIncident(ctx, org.ID, incident.ID)
+	require.NoError(t, err)
+	require.Len(t, detail.Links, 2)
+	assert.Equal(t, domain.IncidentLinkThreat, detail.Links[0].ResourceType)
+	assert.Equal(t, domain.IncidentLinkVerificationEvent, detail.Links[1].ResourceType)
+
+	// Comments are validated and can still be left once the incident is closed
+	_, err = service.AddComment(ctx, org.ID, incident.ID, analyst.ID, "   ")
+	assert.ErrorIs(t, err, ErrInvalidIncidentComment)
+	_, err = service.AddComment(ctx, org.ID, incident.ID, analyst.ID, strings.Repeat("x", MaxIncidentCommentLength+1))
+	assert.ErrorIs(t, err, ErrInvalidIncidentComment)
+	_, err = service.AddComment(ctx, org.ID, incident.ID, analyst.ID, "Key rotated, agent quarantined")
+	require.NoError(t, err)
+
+	detail, err = service.ChangeStatus(ctx, org.ID, incident.ID, analyst.ID, domain.IncidentStatusResolved, "Rotated the leaked key")
+	require.NoError(t, err)
+	assert.Equal(t, "Rotated the leaked key", detail.ResolutionNotes)
+	require.NotNil(t, detail.SLA.Resolution.MetAt)
+	assert.False(t, detail.SLA.Resolution.Breached)
+	_, err = service.ChangeStatus(ctx, org.ID, incident.ID, analyst.ID, domain.IncidentStatusInvestigating, "")
+	assert.ErrorIs(t, err, ErrIncidentClosed)
+	_, err = service.AddComment(ctx, org.ID, incident.ID, responder.ID, "Post-mortem scheduled")
+	require.NoError(t, err)
+
+	comments, err := service.ListComments(ctx, org.ID, incident.ID)
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	assert.Equal(t, "Key rotated, agent quarantined", comments[0].Body)
+
+	// Every step was recorded on the timeline in order; the SLA breach has no actor
+	timeline, err := service.ListTimeline(ctx, org.ID, incident.ID)
+	require.NoError(t, err)
+	var eventTypes []domain.IncidentTimelineEventType
+	for _, entry := range timeline {
+		eventTypes = append(eventTypes, entry.EventType)
+	}
+	assert.Equal(t, []domain.IncidentTimelineEventType{
+		domain.IncidentTimelineSLABreached,
+		domain.IncidentTimelineAssigned,
+		domain.IncidentTimelineStatusChanged,
+		domain.IncidentTimelineLinked,
+		domain.IncidentTimelineLinked,
+		domain.IncidentTimelineLinked,
+		domain.IncidentTimelineUnlinked,
+		domain.IncidentTimelineCommented,
+		domain.IncidentTimelineStatusChanged,
+		domain.IncidentTimelineCommented,
+	}, eventTypes)
+	assert.Nil(t, timeline[0].ActorID)
+	assert.Equal(t, "response", timeline[0].Details["sla"])
+	require.NotNil(t, timeline[1].ActorID)
+	assert.Equal(t, responder.ID, *timeline[1].ActorID)
+	assert.Contains(t, timeline[4].Summary, anomaly.Title)
+
+	// Resolved incidents are no longer checked
+	breaches, err = service.EvaluateSLAs(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, breaches)
+}
@@ -0,0 +1,341 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+)
+
+var (
+	// ErrInvalidInvitation is returned when an invitation request has an invalid email, role or lifetime
+	ErrInvalidInvitation = errors.New("invalid invitation")
+	// ErrInvitationAlreadyPending is returned when the address already has an open invitation to the organization
+	ErrInvitationAlreadyPending = errors.New("an invitation for this email is already pending")
+	// ErrInvitationNotPending is returned when an invitation was already accepted or revoked, or has expired
+	ErrInvitationNotPending = errors.New("invitation is no longer pending")
+	// ErrInvalidInvitationToken is returned when an invitation link was not issued by AIM or has expired
+	ErrInvalidInvitationToken = errors.New("invalid or expired invitation link")
+)
+
+// InviteUserRequest invites someone to the organization
+type InviteUserRequest struct {
+	Email string          `json:"email"`
+	Role  domain.UserRole `json:"role"`
+	// ExpiresInHours is how long the link works; DefaultInvitationTTL when zero
+	ExpiresInHours int `json:"expiresInHours,omitempty"`
+}
+
+// AcceptInvitationRequest sets up the account of an invitee
+type AcceptInvitationRequest struct {
+	Token    string `json:"token"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// InvitationPreview is what the accept page shows about an invitation before the invitee sets
+// up their account
+type InvitationPreview struct {
+	Email            string          `json:"email"`
+	Role             domain.UserRole `json:"role"`
+	OrganizationName string          `json:"organizationName"`
+	ExpiresAt        time.Time       `json:"expiresAt"`
+	RequiresApproval bool            `json:"requiresApproval"` // The account waits for an admin's approval after acceptance
+}
+
+// InvitationService onboards users by invitation: admins invite an address with a role, the
+// invitee receives a signed link that expires, and accepting it creates their account. The
+// account is active at once unless the organization requires invitees to be approved.
+type InvitationService struct {
+	invitationRepo domain.InvitationRepository
+	userRepo       domain.UserRepository
+	orgRepo        domain.OrganizationRepository
+	jwtService     *auth.JWTService
+	auditService   *AuditService
+	emailService   domain.EmailService
+}
+
+// NewInvitationService creates a new invitation service
+func NewInvitationService(
+	invitationRepo domain.InvitationRepository,
+	userRepo domain.UserRepository,
+	orgRepo domain.OrganizationRepository,
+	jwtService *auth.JWTService,
+	auditService *AuditService,
+	emailService domain.EmailService,
+) *InvitationService {
+	return &InvitationService{
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		orgRepo:        orgRepo,
+		jwtService:     jwtService,
+		auditService:   auditService,
+		emailService:   emailService,
+	}
+}
+
+// Invite creates an invitation and emails its link to the invitee. The link is returned as well,
+// so admins can pass it on themselves when email is not configured.
+func (s *InvitationService) Invite(ctx context.Context, orgID, inviterID uuid.UUID, req *InviteUserRequest) (*domain.Invitation, string, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if !strings.Contains(email, "@") || strings.ContainsAny(email, " \t\r\n") {
+		return nil, "", fmt.Errorf("%w: email address is invalid", ErrInvalidInvitation)
+	}
+	switch req.Role {
+	case domain.RoleAdmin, domain.RoleManager, domain.RoleMember, domain.RoleViewer:
+	default:
+		return nil, "", fmt.Errorf("%w: role must be admin, manager, member or viewer", ErrInvalidInvitation)
+	}
+	ttl := domain.DefaultInvitationTTL
+	if req.ExpiresInHours != 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+		if ttl < time.Hour || ttl > domain.MaxInvitationTTL {
+			return nil, "", fmt.Errorf("%w: expiresInHours must be between 1 and %d", ErrInvalidInvitation, int(domain.MaxInvitationTTL.Hours()))
+		}
+	}
+
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get organization: %w", err)
+	}
+	if existing, err := s.userRepo.GetByEmail(email); err == nil && existing != nil {
+		return nil, "", ErrUserAlreadyExists
+	}
+	invitations, err := s.invitationRepo.ListByEmail(orgID, email)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to check invitations: %w", err)
+	}
+	now := time.Now()
+	for _, invitation := range invitations {
+		if invitation.IsOpen(now) {
+			return nil, "", ErrInvitationAlreadyPending
+		}
+	}
+
+	invitation := &domain.Invitation{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Email:          email,
+		Role:           req.Role,
+		InvitedBy:      inviterID,
+		Status:         domain.InvitationStatusPending,
+		ExpiresAt:      now.Add(ttl),
+		CreatedAt:      now,
+	}
+	if err := s.invitationRepo.Create(invitation); err != nil {
+		return nil, "", fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	token, err := s.jwtService.GenerateInvitationToken(invitation.ID.String(), orgID.String(), email, string(req.Role), invitation.ExpiresAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to sign invitation: %w", err)
+	}
+	frontendURL := os.Getenv("FRONTEND_URL")
+	if frontendURL == "" {
+		frontendURL = "http://localhost:3000"
+	}
+	link := fmt.Sprintf("%s/auth/accept-invitation?token=%s", frontendURL, url.QueryEscape(token))
+
+	if s.emailService != nil {
+		supportEmail := os.Getenv("SUPPORT_EMAIL")
+		if supportEmail == "" {
+			supportEmail = "info@opena2a.org"
+		}
+		inviterName := "An administrator"
+		if inviter, err := s.userRepo.GetByID(inviterID); err == nil && inviter != nil && inviter.Name != "" {
+			inviterName = inviter.Name
+		}
+
+		templateData := domain.EmailTemplateData{
+			UserEmail:    email,
+			DashboardURL: frontendURL,
+			SupportEmail: supportEmail,
+			Timestamp:    now,
+			ExpiresAt:    invitation.ExpiresAt,
+			CustomData: map[string]interface{}{
+				"InviteLink":       link,
+				"OrganizationName": org.Name,
+				"InvitedBy":        inviterName,
+				"Role":             string(req.Role),
+				"ExpiresIn":        invitationLifetime(ttl),
+			},
+		}
+
+		if err := organizationEmail(s.emailService, orgID).SendTemplatedEmail(domain.TemplateInvitation, email, templateData); err != nil {
+			// Log error but don't fail the request (the admin still gets the link)
+			fmt.Printf("⚠️  Failed to send invitation email to %s: %v\n", email, err)
+		}
+	}
+
+	return invitation, link, nil
+}
+
+// ListInvitations lists the organization's invitations, newest first; an empty status lists all
+func (s *InvitationService) ListInvitations(ctx context.Context, orgID uuid.UUID, status domain.InvitationStatus, limit, offset int) ([]*domain.Invitation, error) {
+	return s.invitationRepo.ListByOrganization(orgID, status, limit, offset)
+}
+
+// RevokeInvitation withdraws a pending invitation; its link stops working
+func (s *InvitationService) RevokeInvitation(ctx context.Context, orgID, invitationID, adminID uuid.UUID) (*domain.Invitation, error) {
+	invitation, err := s.invitationRepo.GetByID(invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.OrganizationID != orgID {
+		return nil, domain.ErrInvitationNotFound
+	}
+
+	revoked, err := s.invitationRepo.Revoke(invitationID, adminID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+	if !revoked {
+		return nil, ErrInvitationNotPending
+	}
+	return s.invitationRepo.GetByID(invitationID)
+}
+
+// PreviewInvitation describes the invitation of a link that can still be accepted
+func (s *InvitationService) PreviewInvitation(ctx context.Context, token string) (*InvitationPreview, error) {
+	invitation, org, err := s.openInvitation(token)
+	if err != nil {
+		return nil, err
+	}
+	return &InvitationPreview{
+		Email:            invitation.Email,
+		Role:             invitation.Role,
+		OrganizationName: org.Name,
+		ExpiresAt:        invitation.ExpiresAt,
+		RequiresApproval: org.InviteesRequireApproval(),
+	}, nil
+}
+
+// AcceptInvitation creates the invitee's account with the invited role. It is pending until an
+// admin approves it when the organization requires invitees to be approved, and active otherwise.
+func (s *InvitationService) AcceptInvitation(ctx context.Context, req *AcceptInvitationRequest) (*domain.User, error) {
+	invitation, org, err := s.openInvitation(req.Token)
+	if err != nil {
+		return nil, err
+	}
+	if existing, err := s.userRepo.GetByEmail(invitation.Email); err == nil && existing != nil {
+		return nil, ErrUserAlreadyExists
+	}
+
+	passwordHasher := auth.NewPasswordHasher()
+	if err := passwordHasher.ValidatePassword(req.Password); err != nil {
+		return nil, fmt.Errorf("password validation failed: %w", err)
+	}
+	passwordHash, err := passwordHasher.HashPassword(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	now := time.Now()
+	user := &domain.User{
+		ID:             uuid.New(),
+		OrganizationID: invitation.OrganizationID,
+		Email:          invitation.Email,
+		Name:           strings.TrimSpace(req.Name),
+		Role:           invitation.Role,
+		Provider:       "local",
+		ProviderID:     invitation.Email,
+		PasswordHash:   &passwordHash,
+		Status:         domain.UserStatusActive,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if user.Name == "" {
+		user.Name = invitation.Email // Fallback to email if no name provided
+	}
+	if org.InviteesRequireApproval() {
+		user.Status = domain.UserStatusPending
+	} else {
+		// The invitation is the inviter's approval
+		user.ApprovedBy = &invitation.InvitedBy
+		user.ApprovedAt = &now
+	}
+
+	// Claim the invitation first, so a link accepted twice at once creates one account
+	accepted, err := s.invitationRepo.Accept(invitation.ID, user.ID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+	if !accepted {
+		return nil, ErrInvitationNotPending
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, fmt.Errorf("failed to create user '%s' in database: %w", user.Email, err)
+	}
+
+	s.auditService.LogAction(
+		ctx,
+		user.OrganizationID,
+		user.ID,
+		domain.AuditActionCreate,
+		"user",
+		user.ID,
+		"", // IP address
+		"", // User agent
+		map[string]interface{}{
+			"invitation_id":       invitation.ID,
+			"invited_by":          invitation.InvitedBy,
+			"registration_method": "invitation",
+			"status":              user.Status,
+		},
+	)
+
+	return user, nil
+}
+
+// openInvitation returns the invitation of a link, and its organization, when it can still be accepted
+func (s *InvitationService) openInvitation(token string) (*domain.Invitation, *domain.Organization, error) {
+	claims, err := s.jwtService.ValidateInvitationToken(strings.TrimSpace(token))
+	if err != nil {
+		return nil, nil, ErrInvalidInvitationToken
+	}
+	invitationID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, nil, ErrInvalidInvitationToken
+	}
+
+	invitation, err := s.invitationRepo.GetByID(invitationID)
+	if errors.Is(err, domain.ErrInvitationNotFound) {
+		return nil, nil, ErrInvalidInvitationToken
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if invitation.OrganizationID.String() != claims.OrganizationID || invitation.Email != claims.Email {
+		return nil, nil, ErrInvalidInvitationToken
+	}
+	if !invitation.IsOpen(time.Now()) {
+		return nil, nil, ErrInvitationNotPending
+	}
+
+	org, err := s.orgRepo.GetByID(invitation.OrganizationID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	return invitation, org, nil
+}
+
+// invitationLifetime describes an invitation's lifetime for the email, e.g. "7 days"
+func invitationLifetime(ttl time.Duration) string {
+	if ttl%(24*time.Hour) == 0 {
+		if days := int(ttl / (24 * time.Hour)); days != 1 {
+			return fmt.Sprintf("%d days", days)
+		}
+		return "1 day"
+	}
+	if hours := int(ttl.Hours()); hours != 1 {
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return "1 hour"
+}
@@ -0,0 +1,108 @@
+package application
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitationsCreateUsersPendingOrActiveByOrganizationPolicy(t *testing.T) {
+	t.Setenv("JWT_SECRET", "invitation-test-secret")
+	ctx := context.Background()
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	admin := testsupport.NewUser(org.ID, func(u *domain.User) { u.Role = domain.RoleAdmin })
+	require.NoError(t, repos.User.Create(admin))
+
+	service := NewInvitationService(repos.Invitation, repos.User, repos.Organization,
+		auth.NewJWTService(), NewAuditService(repos.AuditLog), nil)
+	token := func(link string) string {
+		parsed, err := url.Parse(link)
+		require.NoError(t, err)
+		return parsed.Query().Get("token")
+	}
+
+	_, _, err := service.Invite(ctx, org.ID, admin.ID, &InviteUserRequest{Email: "new@example.com", Role: "owner"})
+	assert.ErrorIs(t, err, ErrInvalidInvitation)
+	_, _, err = service.Invite(ctx, org.ID, admin.ID, &InviteUserRequest{Email: admin.Email, Role: domain.RoleMember})
+	assert.ErrorIs(t, err, ErrUserAlreadyExists)
+
+	invitation, link, err := service.Invite(ctx, org.ID, admin.ID, &InviteUserRequest{Email: " New@Example.com ", Role: domain.RoleManager})
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", invitation.Email)
+	assert.WithinDuration(t, time.Now().Add(domain.DefaultInvitationTTL), invitation.ExpiresAt, time.Minute)
+	_, _, err = service.Invite(ctx, org.ID, admin.ID, &InviteUserRequest{Email: "new@example.com", Role: domain.RoleViewer})
+	assert.ErrorIs(t, err, ErrInvitationAlreadyPending)
+
+	preview, err := service.PreviewInvitation(ctx, token(link))
+	require.NoError(t, err)
+	assert.Equal(t, org.Name, preview.OrganizationName)
+	assert.Equal(t, domain.RoleManager, preview.Role)
+	assert.False(t, preview.RequiresApproval)
+	_, err = service.PreviewInvitation(ctx, token(link)+"x")
+	assert.ErrorIs(t, err, ErrInvalidInvitationToken)
+
+	// Without the approval policy the invitee is active at once, approved by the inviter
+	_, err = service.AcceptInvitation(ctx, &AcceptInvitationRequest{Token: token(link), Password: "weak"})
+	assert.ErrorIs(t, err, auth.ErrPasswordTooShort)
+	user, err := service.AcceptInvitation(ctx, &AcceptInvitationRequest{Token: token(link), Name: "New User", Password: "Str0ng!Passw0rd"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserStatusActive, user.Status)
+	assert.Equal(t, domain.RoleManager, user.Role)
+	assert.Equal(t, &admin.ID, user.ApprovedBy)
+	stored, err := repos.User.GetByEmail("new@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, stored.ID)
+	_, err = service.AcceptInvitation(ctx, &AcceptInvitationRequest{Token: token(link), Password: "Str0ng!Passw0rd"})
+	assert.ErrorIs(t, err, ErrInvitationNotPending, "a link works once")
+
+	// With the policy on, invitees wait for approval
+	org.Settings = map[string]interface{}{domain.OrganizationSettingInviteesRequireApproval: true}
+	require.NoError(t, repos.Organization.Update(org))
+	_, link, err = service.Invite(ctx, org.ID, admin.ID, &InviteUserRequest{Email: "pending@example.com", Role: domain.RoleViewer, ExpiresInHours: 2})
+	require.NoError(t, err)
+	user, err = service.AcceptInvitation(ctx, &AcceptInvitationRequest{Token: token(link), Password: "Str0ng!Passw0rd"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserStatusPending, user.Status)
+	assert.Nil(t, user.ApprovedBy)
+	assert.Equal(t, "pending@example.com", user.Name)
+
+	// Revoked invitations stop working; expired ones are reported as expired
+	revoked, link, err := service.Invite(ctx, org.ID, admin.ID, &InviteUserRequest{Email: "revoked@example.com", Role: domain.RoleMember})
+	require.NoError(t, err)
+	_, err = service.RevokeInvitation(ctx, uuid.New(), revoked.ID, admin.ID)
+	assert.ErrorIs(t, err, domain.ErrInvitationNotFound, "invitations are scoped to the organization")
+	revoked, err = service.RevokeInvitation(ctx, org.ID, revoked.ID, admin.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.InvitationStatusRevoked, revoked.Status)
+	_, err = service.RevokeInvitation(ctx, org.ID, revoked.ID, admin.ID)
+	assert.ErrorIs(t, err, ErrInvitationNotPending)
+	_, err = service.AcceptInvitation(ctx, &AcceptInvitationRequest{Token: token(link), Password: "Str0ng!Passw0rd"})
+	assert.ErrorIs(t, err, ErrInvitationNotPending)
+
+	require.NoError(t, repos.Invitation.Create(&domain.Invitation{
+		OrganizationID: org.ID, Email: "late@example.com", Role: domain.RoleMember, InvitedBy: admin.ID,
+		Status: domain.InvitationStatusPending, ExpiresAt: time.Now().Add(-time.Minute),
+	}))
+	pending, err := service.ListInvitations(ctx, org.ID, domain.InvitationStatusPending, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	expired, err := service.ListInvitations(ctx, org.ID, domain.InvitationStatusExpired, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "late@example.com", expired[0].Email)
+	_, _, err = service.Invite(ctx, org.ID, admin.ID, &InviteUserRequest{Email: "late@example.com", Role: domain.RoleMember})
+	assert.NoError(t, err, "an expired invitation can be replaced")
+	all, err := service.ListInvitations(ctx, org.ID, "", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 5)
+}
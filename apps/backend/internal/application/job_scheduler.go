@@ -0,0 +1,389 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// jobSchedulerLeaseName is the lease held by the instance that runs the scheduled jobs
+	jobSchedulerLeaseName = "background-jobs"
+	// DefaultJobLeaseTTL is how long a leader keeps the lease without renewing it
+	DefaultJobLeaseTTL = 30 * time.Second
+	// jobStuckIntervals is how many of its intervals a run may take before the job is reported stuck
+	jobStuckIntervals = 3
+)
+
+var (
+	// ErrJobNotFound is returned for jobs that are not registered
+	ErrJobNotFound = errors.New("background job not found")
+	// ErrJobNotRunning is returned when cancelling a job that is not running
+	ErrJobNotRunning = errors.New("background job is not running")
+	// ErrJobAlreadyRunning is returned when triggering a job that is running
+	ErrJobAlreadyRunning = errors.New("background job is already running")
+	// ErrNotJobLeader is returned when triggering a job on an instance that does not run the jobs
+	ErrNotJobLeader = errors.New("this instance does not run background jobs")
+)
+
+// ScheduledJob is a background job run every Interval by the scheduler leader
+type ScheduledJob struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// JobStatus is the state of a job's runs on this instance
+type JobStatus struct {
+	Name           string     `json:"name"`
+	Interval       string     `json:"interval"`
+	Running        bool       `json:"running"`
+	RunningSince   *time.Time `json:"runningSince,omitempty"`
+	Stuck          bool       `json:"stuck"` // Running for more than three intervals
+	LastStartedAt  *time.Time `json:"lastStartedAt,omitempty"`
+	LastFinishedAt *time.Time `json:"lastFinishedAt,omitempty"`
+	LastDurationMs int64      `json:"lastDurationMs"`
+	LastError      string     `json:"lastError,omitempty"`
+	Runs           int        `json:"runs"`
+	Failures       int        `json:"failures"`
+}
+
+// jobState tracks the runs of one job
+type jobState struct {
+	running        bool
+	cancel         context.CancelFunc
+	startedAt      time.Time
+	lastFinishedAt time.Time
+	lastDuration   time.Duration
+	lastErr        error
+	runs           int
+	failures       int
+}
+
+// JobScheduler runs registered jobs on their own interval. In multi-instance deployments
+// the instances elect a leader through a shared lease and only the leader runs jobs;
+// when it stops renewing the lease, another instance takes over once the lease expires.
+type JobScheduler struct {
+	leaseRepo   domain.JobLeaseRepository // Optional: without it this instance always runs the jobs
+	leaseTTL    time.Duration
+	instanceID  string
+	jobs        []ScheduledJob
+	leader      atomic.Bool
+	states      map[string]*jobState
+	rescheduled map[string]chan struct{} // Signals runEvery that the job's interval changed
+	mu          sync.Mutex
+	ctx         context.Context // Set by Start; runs triggered by operators stop with it
+}
+
+// NewJobScheduler creates a scheduler electing its leader through leaseRepo
+func NewJobScheduler(leaseRepo domain.JobLeaseRepository, leaseTTL time.Duration) *JobScheduler {
+	if leaseTTL <= 0 {
+		leaseTTL = DefaultJobLeaseTTL
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "aim"
+	}
+	return &JobScheduler{
+		leaseRepo:   leaseRepo,
+		leaseTTL:    leaseTTL,
+		instanceID:  fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8]),
+		states:      make(map[string]*jobState),
+		rescheduled: make(map[string]chan struct{}),
+	}
+}
+
+// InstanceID identifies this instance in the job lease
+func (s *JobScheduler) InstanceID() string {
+	return s.instanceID
+}
+
+// Register adds a job; a job with a non-positive interval is disabled.
+// Jobs must be registered before Start.
+func (s *JobScheduler) Register(name string, interval time.Duration, run func(ctx context.Context) error) {
+	if interval <= 0 {
+		log.Printf("ℹ️  Background job %s disabled", name)
+		return
+	}
+	s.jobs = append(s.jobs, ScheduledJob{Name: name, Interval: interval, Run: run})
+	s.rescheduled[name] = make(chan struct{}, 1)
+}
+
+// Jobs returns the registered jobs with their current intervals, in registration order
+func (s *JobScheduler) Jobs() []ScheduledJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ScheduledJob(nil), s.jobs...)
+}
+
+// SetInterval changes how often a registered job runs, without a restart. The job's next run
+// is one new interval after the change.
+func (s *JobScheduler) SetInterval(name string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("interval of background job %s must be positive", name)
+	}
+
+	s.mu.Lock()
+	index := -1
+	for i := range s.jobs {
+		if s.jobs[i].Name == name {
+			index = i
+		}
+	}
+	if index < 0 {
+		s.mu.Unlock()
+		return ErrJobNotFound
+	}
+	changed := s.jobs[index].Interval != interval
+	s.jobs[index].Interval = interval
+	s.mu.Unlock()
+	if !changed {
+		return nil
+	}
+
+	select {
+	case s.rescheduled[name] <- struct{}{}:
+	default: // runEvery has not picked up an earlier change yet; it reads the latest interval
+	}
+	log.Printf("🔄 Background job %s now runs every %s", name, interval)
+	return nil
+}
+
+// IsLeader reports whether this instance currently runs the jobs
+func (s *JobScheduler) IsLeader() bool {
+	return s.leader.Load()
+}
+
+// ElectLeader takes or renews the job lease and returns whether this instance is the leader.
+// A failure to reach the lease store costs the leadership, so that two instances never both
+// believe they lead.
+func (s *JobScheduler) ElectLeader() bool {
+	leader := true
+	if s.leaseRepo != nil {
+		acquired, err := s.leaseRepo.Acquire(jobSchedulerLeaseName, s.instanceID, s.leaseTTL)
+		if err != nil {
+			log.Printf("⚠️  Failed to renew background job lease: %v", err)
+		}
+		leader = acquired && err == nil
+	}
+
+	if was := s.leader.Swap(leader); was != leader {
+		if leader {
+			log.Printf("✅ Instance %s is now running background jobs", s.instanceID)
+		} else {
+			log.Printf("ℹ️  Instance %s stopped running background jobs", s.instanceID)
+		}
+	}
+	return leader
+}
+
+// RunJob runs the job now if this instance is the leader and the job is not already running.
+// Returns whether it ran; failures are logged and the job runs again on its next tick.
+func (s *JobScheduler) RunJob(ctx context.Context, job ScheduledJob) bool {
+	if !s.IsLeader() {
+		return false
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	started, ok := s.begin(job.Name, cancel)
+	if !ok {
+		log.Printf("ℹ️  Background job %s skipped: the previous run has not finished", job.Name)
+		return false
+	}
+
+	err := job.Run(runCtx)
+	s.finish(job.Name, started, err)
+	if err != nil {
+		log.Printf("⚠️  Background job %s failed after %s: %v", job.Name, time.Since(started).Round(time.Millisecond), err)
+	}
+	return true
+}
+
+// begin marks the job running; false when a run is in progress
+func (s *JobScheduler) begin(name string, cancel context.CancelFunc) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[name]
+	if !ok {
+		state = &jobState{}
+		s.states[name] = state
+	}
+	if state.running {
+		return time.Time{}, false
+	}
+	state.running = true
+	state.cancel = cancel
+	state.startedAt = time.Now()
+	return state.startedAt, true
+}
+
+// finish records the outcome of the job's run
+func (s *JobScheduler) finish(name string, started time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.states[name]
+	state.running = false
+	state.cancel = nil
+	state.lastFinishedAt = time.Now()
+	state.lastDuration = state.lastFinishedAt.Sub(started)
+	state.lastErr = err
+	state.runs++
+	if err != nil {
+		state.failures++
+	}
+}
+
+// Statuses returns the state of every registered job on this instance, in registration order
+func (s *JobScheduler) Statuses() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		status := JobStatus{Name: job.Name, Interval: job.Interval.String()}
+		if state, ok := s.states[job.Name]; ok {
+			status.Running = state.running
+			status.Runs = state.runs
+			status.Failures = state.failures
+			status.LastDurationMs = state.lastDuration.Milliseconds()
+			if !state.startedAt.IsZero() {
+				startedAt := state.startedAt
+				status.LastStartedAt = &startedAt
+			}
+			if !state.lastFinishedAt.IsZero() {
+				finishedAt := state.lastFinishedAt
+				status.LastFinishedAt = &finishedAt
+			}
+			if state.lastErr != nil {
+				status.LastError = state.lastErr.Error()
+			}
+			if state.running {
+				status.RunningSince = status.LastStartedAt
+				status.Stuck = now.Sub(state.startedAt) > jobStuckIntervals*job.Interval
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// CancelJob cancels the context of the job's run in progress. The run stops as soon as the
+// job checks its context; the job runs again on its next tick.
+func (s *JobScheduler) CancelJob(name string) error {
+	if _, ok := s.job(name); !ok {
+		return ErrJobNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[name]
+	if !ok || !state.running {
+		return ErrJobNotRunning
+	}
+	state.cancel()
+	return nil
+}
+
+// TriggerJob starts a run of the job now, in the background, without waiting for its next tick
+func (s *JobScheduler) TriggerJob(name string) error {
+	job, ok := s.job(name)
+	if !ok {
+		return ErrJobNotFound
+	}
+	s.mu.Lock()
+	ctx := s.ctx
+	state, ok := s.states[name]
+	running := ok && state.running
+	s.mu.Unlock()
+
+	if ctx == nil || !s.IsLeader() {
+		return ErrNotJobLeader
+	}
+	if running {
+		return ErrJobAlreadyRunning
+	}
+	go s.RunJob(ctx, job)
+	return nil
+}
+
+func (s *JobScheduler) job(name string) (ScheduledJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		if job.Name == name {
+			return job, true
+		}
+	}
+	return ScheduledJob{}, false
+}
+
+// Start elects the leader and runs the jobs until ctx is cancelled, then gives up the lease
+func (s *JobScheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+	s.ElectLeader()
+
+	var wg sync.WaitGroup
+	for _, job := range s.Jobs() {
+		wg.Add(1)
+		go func(job ScheduledJob) {
+			defer wg.Done()
+			s.runEvery(ctx, job)
+		}(job)
+	}
+	log.Printf("✅ Background job scheduler started with %d jobs", len(s.jobs))
+
+	// Renew well before the lease expires so a slow database does not cost the leadership
+	ticker := time.NewTicker(s.leaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			if s.leader.Swap(false) && s.leaseRepo != nil {
+				if err := s.leaseRepo.Release(jobSchedulerLeaseName, s.instanceID); err != nil {
+					log.Printf("⚠️  Failed to release background job lease: %v", err)
+				}
+			}
+			log.Println("Background job scheduler stopped")
+			return
+		case <-ticker.C:
+			s.ElectLeader()
+		}
+	}
+}
+
+// runEvery runs the job on its interval, restarting the ticker when SetInterval changes the
+// interval; runs of one job never overlap
+func (s *JobScheduler) runEvery(ctx context.Context, job ScheduledJob) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.rescheduled[job.Name]:
+			if current, ok := s.job(job.Name); ok {
+				job = current
+				ticker.Reset(job.Interval)
+			}
+		case <-ticker.C:
+			s.RunJob(ctx, job)
+		}
+	}
+}
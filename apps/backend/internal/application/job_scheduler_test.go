@@ -0,0 +1,49 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobSchedulerRunsJobsOnElectedLeaderOnly(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	first := NewJobScheduler(repos.JobLease, time.Minute)
+	second := NewJobScheduler(repos.JobLease, time.Minute)
+
+	runs := 0
+	job := ScheduledJob{Name: "count", Interval: time.Hour, Run: func(ctx context.Context) error {
+		runs++
+		return nil
+	}}
+
+	require.True(t, first.ElectLeader())
+	assert.False(t, second.ElectLeader(), "the lease is held by the first instance")
+	assert.True(t, first.ElectLeader(), "the leader renews its own lease")
+
+	assert.True(t, first.RunJob(context.Background(), job))
+	assert.False(t, second.RunJob(context.Background(), job))
+	assert.Equal(t, 1, runs)
+
+	// The leader gives up the lease on shutdown so another instance takes over without waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		first.Start(ctx)
+		close(stopped)
+	}()
+	cancel()
+	<-stopped
+
+	assert.False(t, first.IsLeader())
+	assert.True(t, second.ElectLeader())
+	assert.False(t, first.ElectLeader())
+	assert.True(t, second.RunJob(context.Background(), job))
+	assert.Equal(t, 2, runs)
+
+	assert.True(t, NewJobScheduler(nil, 0).ElectLeader(), "without a lease store the instance always leads")
+}
@@ -0,0 +1,373 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrLoginLocked is returned while an account is locked or an address blocked after too many failed logins
+	ErrLoginLocked = errors.New("too many failed logins; try again later")
+	// ErrLoginThrottled is returned while a login has to wait out the backoff of earlier failures
+	ErrLoginThrottled = errors.New("too many failed logins; wait before trying again")
+	// ErrCaptchaRequired is returned when failed logins require a CAPTCHA and none was sent
+	ErrCaptchaRequired = errors.New("complete the CAPTCHA to log in")
+	// ErrInvalidCaptcha is returned when the CAPTCHA sent with a login was not solved
+	ErrInvalidCaptcha = errors.New("the CAPTCHA was not solved")
+	// ErrLoginUserNotFound is returned when unlocking unknown users and users of another organization
+	ErrLoginUserNotFound = errors.New("user not found in organization")
+)
+
+// LoginBlockedError is returned when a login is refused before its password is checked. It
+// unwraps to ErrLoginLocked, ErrLoginThrottled, ErrCaptchaRequired or ErrInvalidCaptcha.
+type LoginBlockedError struct {
+	Reason     error
+	RetryAfter time.Duration // Until the next attempt is let through; zero when a CAPTCHA is what's missing
+}
+
+func (e *LoginBlockedError) Error() string {
+	return e.Reason.Error()
+}
+
+func (e *LoginBlockedError) Unwrap() error {
+	return e.Reason
+}
+
+// LoginThresholds are the failures of an email or address at which each protection sets in;
+// zero turns a protection off
+type LoginThresholds struct {
+	BackoffAfter int // Failures after which every further attempt waits, BackoffBase doubling with each failure
+	CaptchaAfter int // Failures after which logins need a CAPTCHA, when a verifier is configured
+	LockoutAfter int // Failures that lock the account or block the address for LockoutDuration
+}
+
+// LoginProtectionPolicy tunes the brute force and credential stuffing protection of password
+// logins. Failures are counted per email, until a successful login or an unlock clears them,
+// and per IP address, where successes don't clear them.
+type LoginProtectionPolicy struct {
+	Window          time.Duration // Failures older than this no longer count
+	Email           LoginThresholds
+	IP              LoginThresholds
+	BackoffBase     time.Duration
+	BackoffMax      time.Duration
+	LockoutDuration time.Duration
+}
+
+// DefaultLoginProtectionPolicy backs off after 3 failures of an email and locks it after 10. An
+// address guessing many emails is blocked after 50.
+var DefaultLoginProtectionPolicy = LoginProtectionPolicy{
+	Window:          15 * time.Minute,
+	Email:           LoginThresholds{BackoffAfter: 3, CaptchaAfter: 3, LockoutAfter: 10},
+	IP:              LoginThresholds{BackoffAfter: 20, CaptchaAfter: 10, LockoutAfter: 50},
+	BackoffBase:     time.Second,
+	BackoffMax:      time.Minute,
+	LockoutDuration: 15 * time.Minute,
+}
+
+// LoginProtectionService tracks password logins per email and IP address and refuses them once
+// they fail too often: first with an exponential backoff, then by asking for a CAPTCHA, and
+// finally by locking the account or blocking the address for a while. Reaching a lockout opens
+// a brute force threat in the organizations whose users were targeted.
+type LoginProtectionService struct {
+	attemptRepo     domain.LoginAttemptRepository
+	userRepo        domain.UserRepository
+	securityService *SecurityService
+	captcha         domain.CaptchaVerifier
+	policy          LoginProtectionPolicy
+	now             func() time.Time
+}
+
+// NewLoginProtectionService creates a new login protection service
+func NewLoginProtectionService(
+	attemptRepo domain.LoginAttemptRepository,
+	userRepo domain.UserRepository,
+	securityService *SecurityService,
+	policy LoginProtectionPolicy,
+) *LoginProtectionService {
+	if policy.Window <= 0 {
+		policy.Window = DefaultLoginProtectionPolicy.Window
+	}
+	if policy.BackoffBase <= 0 {
+		policy.BackoffBase = DefaultLoginProtectionPolicy.BackoffBase
+	}
+	if policy.BackoffMax < policy.BackoffBase {
+		policy.BackoffMax = policy.BackoffBase
+	}
+	if policy.LockoutDuration <= 0 {
+		policy.LockoutDuration = DefaultLoginProtectionPolicy.LockoutDuration
+	}
+	return &LoginProtectionService{
+		attemptRepo:     attemptRepo,
+		userRepo:        userRepo,
+		securityService: securityService,
+		policy:          policy,
+		now:             time.Now,
+	}
+}
+
+// UseCaptcha makes logins past the CAPTCHA thresholds prove they come from a person. Without a
+// verifier those logins only back off.
+func (s *LoginProtectionService) UseCaptcha(verifier domain.CaptchaVerifier) {
+	s.captcha = verifier
+}
+
+// normalizeLoginEmail lowercases an email, so one account's failures are counted together
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Check is called before a login's password is checked. It returns a *LoginBlockedError, and
+// records the attempt as blocked, when the account is locked, the address blocked, the login
+// has to back off or a CAPTCHA is missing or unsolved.
+func (s *LoginProtectionService) Check(ctx context.Context, email, ipAddress, captchaToken string) error {
+	email = normalizeLoginEmail(email)
+	now := s.now()
+	emailFailures, ipFailures, err := s.failures(email, ipAddress, now)
+	if err != nil {
+		return err
+	}
+
+	blocked := s.blocked(emailFailures, ipFailures, now)
+	if blocked == nil && s.captcha != nil && s.captchaDue(emailFailures, ipFailures) {
+		if captchaToken == "" {
+			blocked = &LoginBlockedError{Reason: ErrCaptchaRequired}
+		} else {
+			solved, err := s.captcha.Verify(ctx, captchaToken, ipAddress)
+			if err != nil {
+				return err
+			}
+			if !solved {
+				blocked = &LoginBlockedError{Reason: ErrInvalidCaptcha}
+			}
+		}
+	}
+	if blocked == nil {
+		return nil
+	}
+
+	if err := s.attemptRepo.Record(&domain.LoginAttempt{
+		Email:     email,
+		IPAddress: ipAddress,
+		Outcome:   domain.LoginAttemptBlocked,
+		CreatedAt: now,
+	}); err != nil {
+		log.Printf("⚠️  Failed to record blocked login: %v", err)
+	}
+	return blocked
+}
+
+// CaptchaRequired reports whether the next login to the email from the address needs a CAPTCHA,
+// so login forms can show one up front
+func (s *LoginProtectionService) CaptchaRequired(ctx context.Context, email, ipAddress string) (bool, error) {
+	if s.captcha == nil {
+		return false, nil
+	}
+	emailFailures, ipFailures, err := s.failures(normalizeLoginEmail(email), ipAddress, s.now())
+	if err != nil {
+		return false, err
+	}
+	return s.captchaDue(emailFailures, ipFailures), nil
+}
+
+// RecordFailure records a login with an unknown email or a wrong password; user is nil for
+// unknown emails. The failure that reaches a lockout threshold locks the account or blocks the
+// address and opens a brute force threat.
+func (s *LoginProtectionService) RecordFailure(ctx context.Context, email, ipAddress string, user *domain.User) error {
+	email = normalizeLoginEmail(email)
+	now := s.now()
+	emailFailures, ipFailures, err := s.failures(email, ipAddress, now)
+	if err != nil {
+		return err
+	}
+
+	attempt := newLoginAttempt(email, ipAddress, user, domain.LoginAttemptFailed, now)
+	lockedUntil := now.Add(s.policy.LockoutDuration)
+	lockAccount := reachesLockout(emailFailures, s.policy.Email.LockoutAfter, now)
+	if lockAccount {
+		attempt.AccountLockedUntil = &lockedUntil
+	}
+	lockAddress := reachesLockout(ipFailures, s.policy.IP.LockoutAfter, now)
+	if lockAddress {
+		attempt.AddressLockedUntil = &lockedUntil
+	}
+	if err := s.attemptRepo.Record(attempt); err != nil {
+		return fmt.Errorf("failed to record login attempt: %w", err)
+	}
+
+	if lockAccount && user != nil {
+		s.createThreat(ctx, &domain.Threat{
+			OrganizationID: user.OrganizationID,
+			ThreatType:     domain.ThreatTypeBruteForce,
+			Severity:       domain.AlertSeverityHigh,
+			Title:          fmt.Sprintf("Brute force login attempts against %s", user.Email),
+			Description: fmt.Sprintf("%d failed logins within %s, the last from %s. The account is locked until %s.",
+				emailFailures.Count+1, s.policy.Window, ipAddress, lockedUntil.UTC().Format(time.RFC3339)),
+			Source:     ipAddress,
+			TargetType: "user",
+			TargetID:   user.ID,
+			IsBlocked:  true,
+		})
+	}
+	if lockAddress {
+		s.reportBlockedAddress(ctx, ipAddress, now, lockedUntil)
+	}
+	return nil
+}
+
+// reportBlockedAddress opens a threat in each organization whose users a blocked address
+// failed to log in as
+func (s *LoginProtectionService) reportBlockedAddress(ctx context.Context, ipAddress string, now, lockedUntil time.Time) {
+	since := now.Add(-s.policy.Window)
+	ipFailures, err := s.attemptRepo.IPFailures(ipAddress, since)
+	if err != nil {
+		log.Printf("⚠️  Failed to count failed logins from %s: %v", ipAddress, err)
+		return
+	}
+	orgIDs, err := s.attemptRepo.TargetedOrganizations(ipAddress, since)
+	if err != nil {
+		log.Printf("⚠️  Failed to find organizations targeted from %s: %v", ipAddress, err)
+		return
+	}
+	for _, orgID := range orgIDs {
+		s.createThreat(ctx, &domain.Threat{
+			OrganizationID: orgID,
+			ThreatType:     domain.ThreatTypeBruteForce,
+			Severity:       domain.AlertSeverityHigh,
+			Title:          fmt.Sprintf("Credential stuffing from %s", ipAddress),
+			Description: fmt.Sprintf("%d failed logins to %d accounts within %s, some of them this organization's. The address is blocked until %s.",
+				ipFailures.Count, ipFailures.Emails, s.policy.Window, lockedUntil.UTC().Format(time.RFC3339)),
+			Source:     ipAddress,
+			TargetType: "ip_address",
+			IsBlocked:  true,
+		})
+	}
+}
+
+func (s *LoginProtectionService) createThreat(ctx context.Context, threat *domain.Threat) {
+	if s.securityService == nil {
+		return
+	}
+	if err := s.securityService.CreateThreat(ctx, threat); err != nil {
+		log.Printf("⚠️  Failed to create brute force threat for organization %s: %v", threat.OrganizationID, err)
+	}
+}
+
+// RecordSuccess records a login with the right password, which clears the failures of its email.
+// Failures from the address keep counting.
+func (s *LoginProtectionService) RecordSuccess(ctx context.Context, email, ipAddress string, user *domain.User) error {
+	if err := s.attemptRepo.Record(newLoginAttempt(normalizeLoginEmail(email), ipAddress, user, domain.LoginAttemptSucceeded, s.now())); err != nil {
+		return fmt.Errorf("failed to record login attempt: %w", err)
+	}
+	return nil
+}
+
+// Unlock lifts the lockout of one of the organization's users and clears their failed logins.
+// A block of the address the failures came from stays in place.
+func (s *LoginProtectionService) Unlock(ctx context.Context, orgID, userID uuid.UUID, ipAddress string) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || user == nil || user.OrganizationID != orgID {
+		return nil, ErrLoginUserNotFound
+	}
+	if err := s.attemptRepo.Record(newLoginAttempt(normalizeLoginEmail(user.Email), ipAddress, user, domain.LoginAttemptUnlocked, s.now())); err != nil {
+		return nil, fmt.Errorf("failed to unlock user: %w", err)
+	}
+	return user, nil
+}
+
+// ListAttempts returns a page of the logins to the organization's users, newest first
+func (s *LoginProtectionService) ListAttempts(ctx context.Context, filter domain.LoginAttemptFilter) ([]*domain.LoginAttempt, int, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	filter.Offset = max(filter.Offset, 0)
+	filter.Email = normalizeLoginEmail(filter.Email)
+	return s.attemptRepo.List(filter)
+}
+
+// PurgeAttempts deletes the login attempts older than the retention
+func (s *LoginProtectionService) PurgeAttempts(ctx context.Context, retention time.Duration) (int, error) {
+	return s.attemptRepo.DeleteBefore(s.now().Add(-retention))
+}
+
+func (s *LoginProtectionService) failures(email, ipAddress string, now time.Time) (*domain.LoginFailures, *domain.LoginFailures, error) {
+	since := now.Add(-s.policy.Window)
+	emailFailures, err := s.attemptRepo.EmailFailures(email, since)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to count failed logins: %w", err)
+	}
+	ipFailures, err := s.attemptRepo.IPFailures(ipAddress, since)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to count failed logins: %w", err)
+	}
+	return emailFailures, ipFailures, nil
+}
+
+// blocked returns why a login is refused outright: a lockout, or else the longer of the email's
+// and the address's backoffs
+func (s *LoginProtectionService) blocked(emailFailures, ipFailures *domain.LoginFailures, now time.Time) *LoginBlockedError {
+	var lockedUntil time.Time
+	for _, failures := range []*domain.LoginFailures{emailFailures, ipFailures} {
+		if failures.LockedUntil != nil && failures.LockedUntil.After(lockedUntil) {
+			lockedUntil = *failures.LockedUntil
+		}
+	}
+	if lockedUntil.After(now) {
+		return &LoginBlockedError{Reason: ErrLoginLocked, RetryAfter: lockedUntil.Sub(now)}
+	}
+
+	waitUntil := s.backoffUntil(emailFailures, s.policy.Email.BackoffAfter)
+	if ipWaitUntil := s.backoffUntil(ipFailures, s.policy.IP.BackoffAfter); ipWaitUntil.After(waitUntil) {
+		waitUntil = ipWaitUntil
+	}
+	if waitUntil.After(now) {
+		return &LoginBlockedError{Reason: ErrLoginThrottled, RetryAfter: waitUntil.Sub(now)}
+	}
+	return nil
+}
+
+// backoffUntil returns when the next login may follow the failures: BackoffBase after the last
+// one once there are as many as the threshold, doubling with each further failure up to BackoffMax
+func (s *LoginProtectionService) backoffUntil(failures *domain.LoginFailures, threshold int) time.Time {
+	if threshold <= 0 || failures.Count < threshold || failures.LastFailedAt == nil {
+		return time.Time{}
+	}
+	delay := s.policy.BackoffBase
+	for i := threshold; i < failures.Count && delay < s.policy.BackoffMax; i++ {
+		delay *= 2
+	}
+	return failures.LastFailedAt.Add(min(delay, s.policy.BackoffMax))
+}
+
+func (s *LoginProtectionService) captchaDue(emailFailures, ipFailures *domain.LoginFailures) bool {
+	return (s.policy.Email.CaptchaAfter > 0 && emailFailures.Count >= s.policy.Email.CaptchaAfter) ||
+		(s.policy.IP.CaptchaAfter > 0 && ipFailures.Count >= s.policy.IP.CaptchaAfter)
+}
+
+// reachesLockout reports whether one more failure reaches the threshold while no lockout is in place
+func reachesLockout(failures *domain.LoginFailures, threshold int, now time.Time) bool {
+	if threshold <= 0 || failures.Count+1 < threshold {
+		return false
+	}
+	return failures.LockedUntil == nil || !failures.LockedUntil.After(now)
+}
+
+func newLoginAttempt(email, ipAddress string, user *domain.User, outcome domain.LoginAttemptOutcome, now time.Time) *domain.LoginAttempt {
+	attempt := &domain.LoginAttempt{
+		Email:     email,
+		IPAddress: ipAddress,
+		Outcome:   outcome,
+		CreatedAt: now,
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+		attempt.OrganizationID = &user.OrganizationID
+	}
+	return attempt
+}
@@ -0,0 +1,150 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// solvedCaptcha accepts only the token "solved"
+type solvedCaptcha struct{}
+
+func (solvedCaptcha) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	return token == "solved", nil
+}
+
+func TestFailedLoginsBackOffEscalateToCaptchaAndLockOutWithABruteForceThreat(t *testing.T) {
+	ctx := context.Background()
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	user := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(user))
+	other := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(other))
+
+	security := NewSecurityService(repos.Security, repos.Agent, repos.Alert)
+	protection := NewLoginProtectionService(repos.LoginAttempt, repos.User, security, LoginProtectionPolicy{
+		Window:          15 * time.Minute,
+		Email:           LoginThresholds{BackoffAfter: 2, CaptchaAfter: 3, LockoutAfter: 5},
+		IP:              LoginThresholds{LockoutAfter: 8},
+		BackoffBase:     time.Second,
+		BackoffMax:      4 * time.Second,
+		LockoutDuration: 10 * time.Minute,
+	})
+	const attacker = "203.0.113.7"
+	var blocked *LoginBlockedError
+
+	// Two failures make the next attempt wait, whatever its password; emails are matched case-insensitively
+	require.NoError(t, protection.Check(ctx, user.Email, attacker, ""))
+	require.NoError(t, protection.RecordFailure(ctx, user.Email, attacker, user))
+	require.NoError(t, protection.RecordFailure(ctx, strings.ToUpper(user.Email), attacker, user))
+	err := protection.Check(ctx, user.Email, attacker, "")
+	require.ErrorAs(t, err, &blocked)
+	assert.ErrorIs(t, err, ErrLoginThrottled)
+	assert.True(t, blocked.RetryAfter > 0 && blocked.RetryAfter <= time.Second)
+	repos.LoginAttempt.Backdate(2 * time.Second)
+	require.NoError(t, protection.Check(ctx, user.Email, attacker, ""))
+
+	// Each further failure doubles the wait
+	require.NoError(t, protection.RecordFailure(ctx, user.Email, attacker, user))
+	err = protection.Check(ctx, user.Email, attacker, "")
+	require.ErrorAs(t, err, &blocked)
+	assert.True(t, blocked.RetryAfter > time.Second && blocked.RetryAfter <= 2*time.Second)
+	repos.LoginAttempt.Backdate(3 * time.Second)
+
+	// Without a verifier the CAPTCHA threshold does nothing; with one, logins need a solved CAPTCHA
+	require.NoError(t, protection.Check(ctx, user.Email, attacker, ""))
+	protection.UseCaptcha(solvedCaptcha{})
+	required, err := protection.CaptchaRequired(ctx, user.Email, attacker)
+	require.NoError(t, err)
+	assert.True(t, required)
+	assert.ErrorIs(t, protection.Check(ctx, user.Email, attacker, ""), ErrCaptchaRequired)
+	assert.ErrorIs(t, protection.Check(ctx, user.Email, attacker, "guessed"), ErrInvalidCaptcha)
+	require.NoError(t, protection.Check(ctx, user.Email, attacker, "solved"))
+	required, err = protection.CaptchaRequired(ctx, other.Email, "198.51.100.1")
+	require.NoError(t, err)
+	assert.False(t, required, "other accounts and addresses are not affected")
+
+	// The fifth failure locks the account and opens a brute force threat against the user
+	require.NoError(t, protection.RecordFailure(ctx, user.Email, attacker, user))
+	require.NoError(t, protection.RecordFailure(ctx, user.Email, attacker, user))
+	err = protection.Check(ctx, user.Email, "198.51.100.1", "solved")
+	require.ErrorAs(t, err, &blocked)
+	assert.ErrorIs(t, err, ErrLoginLocked)
+	assert.InDelta(t, (10 * time.Minute).Seconds(), blocked.RetryAfter.Seconds(), 5)
+
+	threats, err := repos.Security.GetThreats(org.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, threats, 1)
+	assert.Equal(t, domain.ThreatTypeBruteForce, threats[0].ThreatType)
+	assert.Equal(t, "user", threats[0].TargetType)
+	assert.Equal(t, user.ID, threats[0].TargetID)
+	assert.Equal(t, attacker, threats[0].Source)
+	assert.True(t, threats[0].IsBlocked)
+
+	// The lockout outlasts the failure window until it expires, unless an admin unlocks the user
+	repos.LoginAttempt.Backdate(9 * time.Minute)
+	assert.ErrorIs(t, protection.Check(ctx, user.Email, attacker, "solved"), ErrLoginLocked)
+	_, err = protection.Unlock(ctx, uuid.New(), user.ID, "192.0.2.1")
+	assert.ErrorIs(t, err, ErrLoginUserNotFound)
+	_, err = protection.Unlock(ctx, org.ID, user.ID, "192.0.2.1")
+	require.NoError(t, err)
+	require.NoError(t, protection.Check(ctx, user.Email, attacker, ""), "unlocking clears the user's failures")
+
+	// Successful logins clear an email's failures too
+	require.NoError(t, protection.RecordFailure(ctx, other.Email, "198.51.100.1", other))
+	require.NoError(t, protection.RecordFailure(ctx, other.Email, "198.51.100.1", other))
+	require.NoError(t, protection.RecordSuccess(ctx, other.Email, "198.51.100.1", other))
+	require.NoError(t, protection.Check(ctx, other.Email, "198.51.100.1", ""))
+
+	// An address failing across many accounts is blocked for every email, and the organizations
+	// whose users it tried get a credential stuffing threat. Addresses are not cleared by successes.
+	const stuffer = "192.0.2.50"
+	require.NoError(t, protection.RecordFailure(ctx, other.Email, stuffer, other))
+	require.NoError(t, protection.RecordSuccess(ctx, other.Email, stuffer, other))
+	for i := 0; i < 7; i++ {
+		require.NoError(t, protection.RecordFailure(ctx, fmt.Sprintf("nobody%d@example.net", i), stuffer, nil))
+	}
+	err = protection.Check(ctx, "fresh@example.net", stuffer, "solved")
+	require.ErrorAs(t, err, &blocked)
+	assert.ErrorIs(t, err, ErrLoginLocked)
+	require.NoError(t, protection.Check(ctx, "fresh@example.net", "198.51.100.2", ""))
+
+	threats, err = repos.Security.GetThreats(org.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, threats, 2)
+	var stuffing *domain.Threat
+	for _, threat := range threats {
+		if threat.Source == stuffer {
+			stuffing = threat
+		}
+	}
+	require.NotNil(t, stuffing)
+	assert.Equal(t, domain.ThreatTypeBruteForce, stuffing.ThreatType)
+	assert.Equal(t, "ip_address", stuffing.TargetType)
+	assert.Contains(t, stuffing.Description, "8 failed logins to 8 accounts")
+
+	// Admins see the logins to their users, with the failure that locked the account
+	attempts, total, err := protection.ListAttempts(ctx, domain.LoginAttemptFilter{OrganizationID: org.ID, Email: strings.ToUpper(user.Email), Outcome: domain.LoginAttemptFailed})
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+	require.Len(t, attempts, 5)
+	assert.NotNil(t, attempts[0].AccountLockedUntil)
+	assert.Nil(t, attempts[1].AccountLockedUntil)
+	_, total, err = protection.ListAttempts(ctx, domain.LoginAttemptFilter{OrganizationID: org.ID, Outcome: domain.LoginAttemptBlocked})
+	require.NoError(t, err)
+	assert.Zero(t, total, "blocked attempts are recorded before the user is known")
+
+	purged, err := protection.PurgeAttempts(ctx, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 10, purged, "the five failures and five blocked attempts before the lockout are past the retention")
+}
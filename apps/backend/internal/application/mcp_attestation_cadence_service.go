@@ -0,0 +1,352 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// attestationCadenceBatchSize is how many MCP servers the cadence job loads at a time
+const attestationCadenceBatchSize = 500
+
+var (
+	// ErrInvalidAttestationCadencePolicy is returned for unknown criticalities and cadences without
+	// a positive maximum age and agent count
+	ErrInvalidAttestationCadencePolicy = errors.New("invalid attestation cadence policy")
+	// ErrCadenceMCPServerNotFound is returned when the MCP server is not in the organization
+	ErrCadenceMCPServerNotFound = errors.New("mcp server not found")
+)
+
+// SetAttestationCadencePolicyRequest sets the attestation cadence of a criticality
+type SetAttestationCadencePolicyRequest struct {
+	MaxAttestationAgeHours int `json:"maxAttestationAgeHours"`
+	MinIndependentAgents   int `json:"minIndependentAgents"`
+}
+
+// MCPAttestationCadenceService holds MCP servers to the attestation cadence of their criticality:
+// critical servers must be attested often and by several independent agents, low criticality
+// ones rarely and by one. Organizations can override the default cadence of each level. Servers
+// out of cadence have their confidence score lowered, and a job alerts on the verified ones.
+type MCPAttestationCadenceService struct {
+	policyRepo      domain.AttestationCadencePolicyRepository
+	mcpRepo         domain.MCPServerRepository
+	attestationRepo domain.MCPAttestationRepository
+	alertRepo       domain.AlertRepository
+	now             func() time.Time
+}
+
+// NewMCPAttestationCadenceService creates a new MCP attestation cadence service
+func NewMCPAttestationCadenceService(
+	policyRepo domain.AttestationCadencePolicyRepository,
+	mcpRepo domain.MCPServerRepository,
+	attestationRepo domain.MCPAttestationRepository,
+	alertRepo domain.AlertRepository,
+) *MCPAttestationCadenceService {
+	return &MCPAttestationCadenceService{
+		policyRepo:      policyRepo,
+		mcpRepo:         mcpRepo,
+		attestationRepo: attestationRepo,
+		alertRepo:       alertRepo,
+		now:             time.Now,
+	}
+}
+
+// Policies returns the organization's cadence of every criticality, least critical first;
+// levels the organization did not customize have their default
+func (s *MCPAttestationCadenceService) Policies(ctx context.Context, orgID uuid.UUID) ([]*domain.AttestationCadencePolicy, error) {
+	customized, err := s.policyRepo.ListByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attestation cadence policies: %w", err)
+	}
+	byCriticality := make(map[domain.MCPServerCriticality]*domain.AttestationCadencePolicy, len(customized))
+	for _, policy := range customized {
+		byCriticality[policy.Criticality] = policy
+	}
+
+	policies := make([]*domain.AttestationCadencePolicy, 0, len(domain.MCPServerCriticalities))
+	for _, criticality := range domain.MCPServerCriticalities {
+		policy, ok := byCriticality[criticality]
+		if !ok {
+			fallback := domain.DefaultAttestationCadencePolicies[criticality]
+			fallback.OrganizationID = orgID
+			fallback.IsDefault = true
+			policy = &fallback
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// SetPolicy overrides the organization's cadence of the criticality
+func (s *MCPAttestationCadenceService) SetPolicy(
+	ctx context.Context,
+	orgID, userID uuid.UUID,
+	criticality domain.MCPServerCriticality,
+	req *SetAttestationCadencePolicyRequest,
+) (*domain.AttestationCadencePolicy, error) {
+	if !criticality.IsValid() {
+		return nil, fmt.Errorf("%w: unknown criticality %q", ErrInvalidAttestationCadencePolicy, criticality)
+	}
+	if req.MaxAttestationAgeHours <= 0 || req.MinIndependentAgents <= 0 {
+		return nil, fmt.Errorf("%w: maxAttestationAgeHours and minIndependentAgents must be positive", ErrInvalidAttestationCadencePolicy)
+	}
+
+	now := s.now().UTC()
+	policy := &domain.AttestationCadencePolicy{
+		OrganizationID:         orgID,
+		Criticality:            criticality,
+		MaxAttestationAgeHours: req.MaxAttestationAgeHours,
+		MinIndependentAgents:   req.MinIndependentAgents,
+		UpdatedBy:              &userID,
+		UpdatedAt:              &now,
+	}
+	if err := s.policyRepo.Upsert(policy); err != nil {
+		return nil, fmt.Errorf("failed to save attestation cadence policy: %w", err)
+	}
+	return policy, nil
+}
+
+// ResetPolicy restores the default cadence of the criticality for the organization
+func (s *MCPAttestationCadenceService) ResetPolicy(ctx context.Context, orgID uuid.UUID, criticality domain.MCPServerCriticality) error {
+	if !criticality.IsValid() {
+		return fmt.Errorf("%w: unknown criticality %q", ErrInvalidAttestationCadencePolicy, criticality)
+	}
+	if err := s.policyRepo.Delete(orgID, criticality); err != nil {
+		return fmt.Errorf("failed to delete attestation cadence policy: %w", err)
+	}
+	return nil
+}
+
+// SetCriticality tags the MCP server with a criticality level and returns its cadence status
+// under the new level
+func (s *MCPAttestationCadenceService) SetCriticality(
+	ctx context.Context,
+	orgID, mcpServerID uuid.UUID,
+	criticality domain.MCPServerCriticality,
+) (*domain.AttestationCadenceStatus, error) {
+	if !criticality.IsValid() {
+		return nil, fmt.Errorf("%w: unknown criticality %q", ErrInvalidAttestationCadencePolicy, criticality)
+	}
+	server, err := s.mcpRepo.GetByID(mcpServerID)
+	if err != nil || server.OrganizationID != orgID {
+		return nil, ErrCadenceMCPServerNotFound
+	}
+
+	server.Criticality = criticality
+	server.UpdatedAt = s.now().UTC()
+	if err := s.mcpRepo.Update(server); err != nil {
+		return nil, fmt.Errorf("failed to update mcp server criticality: %w", err)
+	}
+	return s.status(ctx, server)
+}
+
+// GetStatus returns whether the MCP server keeps up with the attestation cadence of its criticality
+func (s *MCPAttestationCadenceService) GetStatus(ctx context.Context, orgID, mcpServerID uuid.UUID) (*domain.AttestationCadenceStatus, error) {
+	server, err := s.mcpRepo.GetByID(mcpServerID)
+	if err != nil || server.OrganizationID != orgID {
+		return nil, ErrCadenceMCPServerNotFound
+	}
+	return s.status(ctx, server)
+}
+
+// Evaluate returns the cadence status of the MCP server from its attestations
+func (s *MCPAttestationCadenceService) Evaluate(
+	ctx context.Context,
+	server *domain.MCPServer,
+	attestations []*domain.MCPAttestation,
+) (*domain.AttestationCadenceStatus, error) {
+	policy, err := s.policy(ctx, server.OrganizationID, serverCriticality(server))
+	if err != nil {
+		return nil, err
+	}
+	return evaluateCadence(server, policy, attestations, s.now().UTC()), nil
+}
+
+func (s *MCPAttestationCadenceService) status(ctx context.Context, server *domain.MCPServer) (*domain.AttestationCadenceStatus, error) {
+	attestations, err := s.attestationRepo.GetValidAttestationsByMCP(server.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load attestations: %w", err)
+	}
+	return s.Evaluate(ctx, server, attestations)
+}
+
+// policy returns the organization's cadence of the criticality
+func (s *MCPAttestationCadenceService) policy(
+	ctx context.Context,
+	orgID uuid.UUID,
+	criticality domain.MCPServerCriticality,
+) (*domain.AttestationCadencePolicy, error) {
+	policies, err := s.Policies(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return findCadencePolicy(policies, criticality), nil
+}
+
+// findCadencePolicy returns the cadence of the criticality among an organization's Policies,
+// which cover every level
+func findCadencePolicy(policies []*domain.AttestationCadencePolicy, criticality domain.MCPServerCriticality) *domain.AttestationCadencePolicy {
+	for _, policy := range policies {
+		if policy.Criticality == criticality {
+			return policy
+		}
+	}
+	fallback := domain.DefaultAttestationCadencePolicies[domain.DefaultMCPServerCriticality]
+	return &fallback
+}
+
+// serverCriticality is the server's criticality; servers nobody tagged have the default
+func serverCriticality(server *domain.MCPServer) domain.MCPServerCriticality {
+	if server.Criticality.IsValid() {
+		return server.Criticality
+	}
+	return domain.DefaultMCPServerCriticality
+}
+
+// evaluateCadence counts the agents with a valid, signature-verified attestation of the server
+// within the policy's maximum age. Only distinct agents count: one agent attesting repeatedly is
+// not independent confirmation. Manual attestations have no agent and don't count.
+func evaluateCadence(
+	server *domain.MCPServer,
+	policy *domain.AttestationCadencePolicy,
+	attestations []*domain.MCPAttestation,
+	now time.Time,
+) *domain.AttestationCadenceStatus {
+	since := now.Add(-policy.MaxAttestationAge())
+	latest := make(map[uuid.UUID]time.Time)
+	for _, attestation := range attestations {
+		if attestation.AgentID == nil || !attestation.IsValid || !attestation.SignatureVerified {
+			continue
+		}
+		attestedAt := attestation.CreatedAt
+		if attestation.VerifiedAt != nil {
+			attestedAt = *attestation.VerifiedAt
+		}
+		if attestedAt.Before(since) {
+			continue
+		}
+		if attestedAt.After(latest[*attestation.AgentID]) {
+			latest[*attestation.AgentID] = attestedAt
+		}
+	}
+
+	status := &domain.AttestationCadenceStatus{
+		MCPServerID:            server.ID,
+		Criticality:            policy.Criticality,
+		MaxAttestationAgeHours: policy.MaxAttestationAgeHours,
+		RequiredAgents:         policy.MinIndependentAgents,
+		FreshAgents:            len(latest),
+		Compliant:              len(latest) >= policy.MinIndependentAgents,
+		ConfidenceScale:        1,
+		EvaluatedAt:            now,
+	}
+
+	if status.Compliant {
+		// The server stays in cadence until the RequiredAgents-th freshest attestation gets too old
+		attestedAt := make([]time.Time, 0, len(latest))
+		for _, at := range latest {
+			attestedAt = append(attestedAt, at)
+		}
+		sort.Slice(attestedAt, func(i, j int) bool { return attestedAt[i].After(attestedAt[j]) })
+		dueAt := attestedAt[policy.MinIndependentAgents-1].Add(policy.MaxAttestationAge())
+		status.DueAt = &dueAt
+	} else {
+		// Out of cadence: confidence is halved without fresh attestations and recovers as
+		// independent agents attest
+		status.ConfidenceScale = 0.5 + 0.5*float64(status.FreshAgents)/float64(status.RequiredAgents)
+	}
+	return status
+}
+
+// EvaluateCadences checks every verified MCP server against the cadence of its criticality and
+// raises an attestation_cadence alert for each one out of cadence. A server with an
+// unacknowledged cadence alert gets no new one. Returns the number of alerts raised.
+func (s *MCPAttestationCadenceService) EvaluateCadences(ctx context.Context) (int, error) {
+	policies := make(map[uuid.UUID][]*domain.AttestationCadencePolicy)
+	raised := 0
+	for offset := 0; ; offset += attestationCadenceBatchSize {
+		servers, err := s.mcpRepo.List(attestationCadenceBatchSize, offset)
+		if err != nil {
+			return raised, fmt.Errorf("failed to list mcp servers: %w", err)
+		}
+
+		for _, server := range servers {
+			if server.Status != domain.MCPServerStatusVerified {
+				continue
+			}
+			orgPolicies, ok := policies[server.OrganizationID]
+			if !ok {
+				if orgPolicies, err = s.Policies(ctx, server.OrganizationID); err != nil {
+					log.Printf("⚠️  Failed to load attestation cadences of organization %s: %v", server.OrganizationID, err)
+					continue
+				}
+				policies[server.OrganizationID] = orgPolicies
+			}
+
+			attestations, err := s.attestationRepo.GetValidAttestationsByMCP(server.ID)
+			if err != nil {
+				log.Printf("⚠️  Failed to load attestations of MCP server %s: %v", server.ID, err)
+				continue
+			}
+			status := evaluateCadence(server, findCadencePolicy(orgPolicies, serverCriticality(server)), attestations, s.now().UTC())
+			if status.Compliant {
+				continue
+			}
+			alerted, err := s.alertMissedCadence(server, status)
+			if err != nil {
+				log.Printf("⚠️  Failed to raise attestation cadence alert for MCP server %s: %v", server.ID, err)
+				continue
+			}
+			if alerted {
+				raised++
+			}
+		}
+
+		if len(servers) < attestationCadenceBatchSize {
+			return raised, nil
+		}
+	}
+}
+
+// alertMissedCadence raises an attestation_cadence alert on the MCP server unless it already has
+// an unacknowledged one
+func (s *MCPAttestationCadenceService) alertMissedCadence(server *domain.MCPServer, status *domain.AttestationCadenceStatus) (bool, error) {
+	existing, err := s.alertRepo.GetUnacknowledgedByResourceID(server.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, alert := range existing {
+		if alert.AlertType == domain.AlertAttestationCadence {
+			return false, nil
+		}
+	}
+
+	severity := domain.AlertSeverityWarning
+	if status.Criticality == domain.MCPServerCriticalityCritical {
+		severity = domain.AlertSeverityHigh
+	}
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: server.OrganizationID,
+		AlertType:      domain.AlertAttestationCadence,
+		Severity:       severity,
+		Title:          fmt.Sprintf("Attestation cadence missed: %s", server.Name),
+		Description: fmt.Sprintf("MCP server '%s' (%s) is %s criticality and needs attestations from %d independent agents "+
+			"within the last %d hours, but only %d agents attested it. Its confidence score is scaled by %.2f until agents attest it again.",
+			server.Name, server.URL, status.Criticality, status.RequiredAgents,
+			status.MaxAttestationAgeHours, status.FreshAgents, status.ConfidenceScale),
+		ResourceType: "mcp_server",
+		ResourceID:   server.ID,
+		CreatedAt:    s.now().UTC(),
+	}
+	if err := s.alertRepo.Create(alert); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,116 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPServersAreHeldToTheAttestationCadenceOfTheirCriticality(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	user := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(user))
+	server := testsupport.NewMCPServer(org.ID)
+	require.NoError(t, repos.MCPServer.Create(server))
+	fresh, stale, second := testsupport.NewAgent(org.ID), testsupport.NewAgent(org.ID), testsupport.NewAgent(org.ID)
+	for _, agent := range []*domain.Agent{fresh, stale, second} {
+		require.NoError(t, repos.Agent.Create(agent))
+	}
+
+	service := NewMCPAttestationCadenceService(repos.AttestationCadence, repos.MCPServer, repos.MCPAttestation, repos.Alert)
+	ctx := context.Background()
+	attest := func(agent *domain.Agent, age time.Duration) {
+		at := time.Now().Add(-age)
+		require.NoError(t, repos.MCPAttestation.CreateAttestation(testsupport.NewMCPAttestation(server, agent, func(a *domain.MCPAttestation) {
+			a.VerifiedAt, a.CreatedAt = &at, at
+		})))
+	}
+	cadenceAlerts := func() int {
+		alerts, err := repos.Alert.GetByOrganization(org.ID, 100, 0)
+		require.NoError(t, err)
+		count := 0
+		for _, alert := range alerts {
+			if alert.AlertType == domain.AlertAttestationCadence {
+				count++
+			}
+		}
+		return count
+	}
+
+	// Untagged servers are medium criticality: one agent within a week is enough
+	attest(fresh, 2*time.Hour)
+	attest(fresh, time.Hour)
+	attest(stale, 30*time.Hour)
+	status, err := service.GetStatus(ctx, org.ID, server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MCPServerCriticalityMedium, status.Criticality)
+	assert.True(t, status.Compliant)
+	assert.Equal(t, 2, status.FreshAgents)
+	assert.Equal(t, 1.0, status.ConfidenceScale)
+
+	// Critical servers need two independent agents within 24h; repeated attestations of one agent don't count twice
+	status, err = service.SetCriticality(ctx, org.ID, server.ID, domain.MCPServerCriticalityCritical)
+	require.NoError(t, err)
+	assert.False(t, status.Compliant)
+	assert.Equal(t, 1, status.FreshAgents)
+	assert.Equal(t, 2, status.RequiredAgents)
+	assert.Nil(t, status.DueAt)
+	assert.InDelta(t, 0.75, status.ConfidenceScale, 0.001)
+	stored, err := repos.MCPServer.GetByID(server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MCPServerCriticalityCritical, stored.Criticality)
+
+	_, err = service.SetCriticality(ctx, org.ID, server.ID, "urgent")
+	assert.ErrorIs(t, err, ErrInvalidAttestationCadencePolicy)
+	_, err = service.GetStatus(ctx, uuid.New(), server.ID)
+	assert.ErrorIs(t, err, ErrCadenceMCPServerNotFound)
+
+	// The job alerts once per missed cadence until the alert is acknowledged
+	raised, err := service.EvaluateCadences(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, raised)
+	raised, err = service.EvaluateCadences(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, raised)
+	assert.Equal(t, 1, cadenceAlerts())
+
+	// A second fresh agent brings the server back in cadence until the older of the two attestations ages out
+	attest(second, 4*time.Hour)
+	status, err = service.GetStatus(ctx, org.ID, server.ID)
+	require.NoError(t, err)
+	assert.True(t, status.Compliant)
+	require.NotNil(t, status.DueAt)
+	assert.WithinDuration(t, time.Now().Add(20*time.Hour), *status.DueAt, time.Minute)
+
+	// Organizations can tighten or loosen the cadence of a level and reset it to the default
+	policies, err := service.Policies(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, policies, 4)
+	assert.True(t, policies[3].IsDefault)
+	_, err = service.SetPolicy(ctx, org.ID, user.ID, domain.MCPServerCriticalityCritical, &SetAttestationCadencePolicyRequest{MaxAttestationAgeHours: 48})
+	assert.ErrorIs(t, err, ErrInvalidAttestationCadencePolicy)
+	_, err = service.SetPolicy(ctx, org.ID, user.ID, domain.MCPServerCriticalityCritical, &SetAttestationCadencePolicyRequest{MaxAttestationAgeHours: 48, MinIndependentAgents: 3})
+	require.NoError(t, err)
+	status, err = service.GetStatus(ctx, org.ID, server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, status.FreshAgents)
+	assert.True(t, status.Compliant)
+	policies, err = service.Policies(ctx, org.ID)
+	require.NoError(t, err)
+	assert.False(t, policies[3].IsDefault)
+	assert.Equal(t, 48, policies[3].MaxAttestationAgeHours)
+
+	require.NoError(t, service.ResetPolicy(ctx, org.ID, domain.MCPServerCriticalityCritical))
+	status, err = service.GetStatus(ctx, org.ID, server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 24, status.MaxAttestationAgeHours)
+	assert.Equal(t, 2, status.FreshAgents)
+}
@@ -2,40 +2,113 @@ package application
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
 	"github.com/opena2a/identity/backend/internal/domain"
-	infracrypto "github.com/opena2a/identity/backend/internal/infrastructure/crypto"
+	"github.com/opena2a/identity/backend/internal/infrastructure/metrics"
 	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
 )
 
+// confidenceRecalculationBatchSize is how many MCP servers are loaded at a time when recalculating confidence scores
+const confidenceRecalculationBatchSize = 500
+
+// agentVerifiedOnlyPenalty is how much lower a server is scored when all of its attestations are
+// agent-verified only: the backend could not check any of them itself
+const agentVerifiedOnlyPenalty = 0.25
+
+// ErrPrivateNetworkMCP is returned when the backend is asked to contact an MCP server
+// that is only reachable through private network relays
+var ErrPrivateNetworkMCP = errors.New("mcp server is on a private network and is verified through agent attestations only")
+
+// ErrMCPServerNotRegistered is returned when an agent attests an MCP URL that is not registered
+// in its organization and the organization has not enabled automatic registration
+var ErrMCPServerNotRegistered = errors.New("mcp server is not registered in this organization")
+
+// ErrInvalidAttestationCallback is returned when an attestation's callback URL is not an absolute
+// http(s) URL of a public host
+var ErrInvalidAttestationCallback = errors.New("callback_url must be an absolute http(s) URL of a public host")
+
+// AttestationRejection is an attestation that failed verification, with the reason reported to
+// the agent's owner. Its message is the underlying error's.
+type AttestationRejection struct {
+	Reason string // domain.AttestationRejected* reason code
+	Err    error
+}
+
+func (e *AttestationRejection) Error() string { return e.Err.Error() }
+func (e *AttestationRejection) Unwrap() error { return e.Err }
+
+func rejectAttestation(reason string, err error) error {
+	return &AttestationRejection{Reason: reason, Err: err}
+}
+
+// attestationRejectionReason returns the reason code an attestation failed with
+func attestationRejectionReason(err error) string {
+	var rejection *AttestationRejection
+	switch {
+	case errors.As(err, &rejection):
+		return rejection.Reason
+	case errors.Is(err, ErrAttestationNonceRequired), errors.Is(err, ErrAttestationNonceInvalid), errors.Is(err, ErrAttestationReplayed):
+		return domain.AttestationRejectedNonce
+	case errors.Is(err, ErrMCPServerNotRegistered):
+		return domain.AttestationRejectedServerNotFound
+	default:
+		return domain.AttestationRejectedInternal
+	}
+}
+
 // MCPAttestationService handles Agent Attestation operations
 type MCPAttestationService struct {
-	attestationRepo *repository.MCPAttestationRepository
-	agentRepo       *repository.AgentRepository
-	mcpRepo         *repository.MCPServerRepository
-	userRepo        *repository.UserRepository
-	connectionRepo  *repository.AgentMCPConnectionRepository
-	cryptoService   *infracrypto.ED25519Service
+	attestationRepo    domain.MCPAttestationRepository
+	agentRepo          domain.AgentRepository
+	mcpRepo            domain.MCPServerRepository
+	userRepo           *repository.UserRepository
+	connectionRepo     *repository.AgentMCPConnectionRepository
+	orgRepo            domain.OrganizationRepository      // Auto-registration, key algorithm and attestation nonce settings
+	alertService       *AlertService                      // Optional: queues auto-registered servers for admin review
+	nonceService       *AttestationNonceService           // Single-use nonces against replayed attestations
+	webhookService     *WebhookService                    // Optional: publishes expired attestations and attestation results
+	deprecationService *CapabilityDeprecationService // Optional: deprecates agent capabilities of tools attestations no longer see
+	claimService       *CapabilityClaimService       // Optional: flags attestations that keep disagreeing with the server's capabilities
+	cadenceService     *MCPAttestationCadenceService // Optional: lowers the confidence of servers out of their attestation cadence
 }
 
 func NewMCPAttestationService(
-	attestationRepo *repository.MCPAttestationRepository,
-	agentRepo *repository.AgentRepository,
-	mcpRepo *repository.MCPServerRepository,
+	attestationRepo domain.MCPAttestationRepository,
+	agentRepo domain.AgentRepository,
+	mcpRepo domain.MCPServerRepository,
 	userRepo *repository.UserRepository,
 	connectionRepo *repository.AgentMCPConnectionRepository,
+	orgRepo domain.OrganizationRepository,
+	alertService *AlertService,
+	nonceService *AttestationNonceService,
+	webhookService *WebhookService,
+	deprecationService *CapabilityDeprecationService,
+	claimService *CapabilityClaimService,
+	cadenceService *MCPAttestationCadenceService,
 ) *MCPAttestationService {
 	return &MCPAttestationService{
-		attestationRepo: attestationRepo,
-		agentRepo:       agentRepo,
-		mcpRepo:         mcpRepo,
-		userRepo:        userRepo,
-		connectionRepo:  connectionRepo,
-		cryptoService:   infracrypto.NewED25519Service(),
+		attestationRepo:    attestationRepo,
+		agentRepo:          agentRepo,
+		mcpRepo:            mcpRepo,
+		userRepo:           userRepo,
+		connectionRepo:     connectionRepo,
+		orgRepo:            orgRepo,
+		alertService:       alertService,
+		nonceService:       nonceService,
+		webhookService:     webhookService,
+		deprecationService: deprecationService,
+		claimService:       claimService,
+		cadenceService:     cadenceService,
 	}
 }
 
@@ -51,7 +124,41 @@ type AttestMCPResponse struct {
 	AttestationID      string  `json:"attestation_id"`
 	MCPConfidenceScore float64 `json:"mcp_confidence_score"`
 	AttestationCount   int     `json:"attestation_count"`
+	AgentVerifiedOnly  bool    `json:"agent_verified_only"` // Private network MCP, not independently verifiable by the backend
+	MCPServerID        string  `json:"mcp_server_id,omitempty"`
+	AutoRegistered     bool    `json:"auto_registered,omitempty"` // Server was created from this attestation and awaits admin review
 	Message            string  `json:"message"`
+
+	// CapabilityDiscrepancy is how the capabilities found differ from the server's registered and
+	// discovered capabilities; omitted when they were not compared
+	CapabilityDiscrepancy *domain.CapabilityDiscrepancy `json:"capability_discrepancy,omitempty"`
+}
+
+// AttestationNonceResponse carries a nonce the agent must sign into its next attestation
+type AttestationNonceResponse struct {
+	Nonce     string    `json:"nonce"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// IssueAttestationNonce issues a single-use nonce to a verified agent
+func (s *MCPAttestationService) IssueAttestationNonce(ctx context.Context, agentID uuid.UUID) (*AttestationNonceResponse, error) {
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("agent not found: %w", err)
+	}
+	if agent.Status != domain.AgentStatusVerified {
+		return nil, fmt.Errorf("only verified agents can attest MCPs (agent status: %s)", agent.Status)
+	}
+
+	nonce, err := s.nonceService.Issue(ctx, agent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttestationNonceResponse{
+		Nonce:     nonce.Nonce,
+		ExpiresAt: nonce.ExpiresAt,
+	}, nil
 }
 
 // VerifyAndRecordAttestation verifies and records an agent's attestation of an MCP server
@@ -59,30 +166,271 @@ func (s *MCPAttestationService) VerifyAndRecordAttestation(
 	ctx context.Context,
 	mcpServerID uuid.UUID,
 	req *AttestMCPRequest,
-) (*AttestMCPResponse, error) {
+) (response *AttestMCPResponse, err error) {
+	if err := validateAttestationCallback(req.Attestation.CallbackURL); err != nil {
+		return nil, err
+	}
+	defer func() { s.publishAttestationResult(ctx, req, &mcpServerID, response, err) }()
+
+	agent, err := s.verifyAttestation(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 6. Verify MCP server exists
+	if _, err := s.mcpRepo.GetByID(mcpServerID); err != nil {
+		return nil, rejectAttestation(domain.AttestationRejectedServerNotFound, fmt.Errorf("mcp server not found: %w", err))
+	}
+
+	return s.recordAttestation(ctx, agent, mcpServerID, req)
+}
+
+// AttestMCPByURL records an attestation for the MCP server at the payload's mcp_url within the
+// agent's organization. An unregistered URL is rejected with ErrMCPServerNotRegistered unless the
+// organization enables auto-registration, in which case a pending server is created from the
+// attestation and raised to admins for review.
+func (s *MCPAttestationService) AttestMCPByURL(ctx context.Context, req *AttestMCPRequest) (response *AttestMCPResponse, err error) {
+	if err := validateAttestationCallback(req.Attestation.CallbackURL); err != nil {
+		return nil, err
+	}
+	defer func() { s.publishAttestationResult(ctx, req, nil, response, err) }()
+
+	agent, err := s.verifyAttestation(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	mcpURL := normalizeMCPURL(req.Attestation.MCPURL)
+	if mcpURL == "" {
+		return nil, rejectAttestation(domain.AttestationRejectedSchema, fmt.Errorf("attestation has no mcp_url"))
+	}
+
+	servers, err := s.mcpRepo.GetByOrganization(agent.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up mcp servers: %w", err)
+	}
+
+	var server *domain.MCPServer
+	for _, candidate := range servers {
+		if normalizeMCPURL(candidate.URL) == mcpURL {
+			server = candidate
+			break
+		}
+	}
+
+	autoRegistered := false
+	if server == nil {
+		if server, err = s.autoRegisterMCPServer(ctx, agent, &req.Attestation, servers); err != nil {
+			return nil, err
+		}
+		autoRegistered = true
+	}
+
+	response, err = s.recordAttestation(ctx, agent, server.ID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response.MCPServerID = server.ID.String()
+	response.AutoRegistered = autoRegistered
+	if autoRegistered {
+		response.Message = "MCP server auto-registered as pending admin review and attestation recorded"
+	}
+	return response, nil
+}
+
+// autoRegisterMCPServer creates a pending MCP server from an attestation of an unregistered URL,
+// if the agent's organization allows it, and alerts admins to review it
+func (s *MCPAttestationService) autoRegisterMCPServer(
+	ctx context.Context,
+	agent *domain.Agent,
+	payload *domain.AttestationPayload,
+	existing []*domain.MCPServer,
+) (*domain.MCPServer, error) {
+	org, err := s.orgRepo.GetByID(agent.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load organization: %w", err)
+	}
+	if !org.AutoRegisterAttestedMCPs {
+		return nil, ErrMCPServerNotRegistered
+	}
+
+	capabilities := payload.CapabilitiesFound
+	if capabilities == nil {
+		capabilities = []string{}
+	}
+
+	agentID := agent.ID
+	server := &domain.MCPServer{
+		ID:                 uuid.New(),
+		OrganizationID:     agent.OrganizationID,
+		Name:               autoRegisteredMCPName(payload, existing),
+		Description:        fmt.Sprintf("Auto-registered from an attestation by agent %s", agent.Name),
+		URL:                strings.TrimSpace(payload.MCPURL),
+		Status:             domain.MCPServerStatusPending,
+		IsVerified:         false,
+		Capabilities:       capabilities,
+		TrustScore:         0.0,
+		RegisteredByAgent:  &agentID,
+		CreatedBy:          agent.CreatedBy,
+		VerificationMethod: "agent_attestation",
+	}
+	if err := s.mcpRepo.Create(server); err != nil {
+		return nil, fmt.Errorf("failed to auto-register mcp server: %w", err)
+	}
+
+	fmt.Printf("🆕 Auto-registered MCP server %s (%s) from attestation by agent %s\n", server.Name, server.URL, agent.ID)
+
+	if s.alertService != nil {
+		alert := &domain.Alert{
+			ID:             uuid.New(),
+			OrganizationID: agent.OrganizationID,
+			AlertType:      domain.AlertMCPServerPendingReview,
+			Severity:       domain.AlertSeverityWarning,
+			Title:          fmt.Sprintf("MCP server pending review: %s", server.Name),
+			Description: fmt.Sprintf("Agent %s attested %s, which was not registered. The server was auto-registered "+
+				"as pending; verify it or delete it.", agent.Name, server.URL),
+			ResourceType: "mcp_server",
+			ResourceID:   server.ID,
+			CreatedAt:    time.Now().UTC(),
+		}
+		if err := s.alertService.CreateAlert(ctx, alert); err != nil {
+			fmt.Printf("⚠️  Failed to create review alert for auto-registered MCP server %s: %v\n", server.ID, err)
+		}
+	}
+
+	return server, nil
+}
+
+// autoRegisteredMCPName picks a name for an auto-registered server: the attested name, falling
+// back to the URL host, suffixed when needed since names are unique per organization
+func autoRegisteredMCPName(payload *domain.AttestationPayload, existing []*domain.MCPServer) string {
+	base := strings.TrimSpace(payload.MCPName)
+	if base == "" {
+		if parsed, err := url.Parse(strings.TrimSpace(payload.MCPURL)); err == nil && parsed.Host != "" {
+			base = parsed.Host
+		} else {
+			base = "attested-mcp"
+		}
+	}
+
+	taken := make(map[string]bool, len(existing))
+	for _, server := range existing {
+		taken[server.Name] = true
+	}
+
+	name := base
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	return name
+}
+
+// normalizeMCPURL compares MCP URLs case-insensitively and without a trailing slash
+func normalizeMCPURL(raw string) string {
+	return strings.TrimRight(strings.ToLower(strings.TrimSpace(raw)), "/")
+}
+
+// validateAttestationCallback accepts an empty callback URL or an absolute http(s) URL. Hosts are
+// checked again after DNS resolution when the callback is delivered.
+func validateAttestationCallback(callbackURL string) error {
+	if callbackURL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return ErrInvalidAttestationCallback
+	}
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrInvalidAttestationCallback
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !attestationCallbackAddressAllowed(addr) {
+		return ErrInvalidAttestationCallback
+	}
+	return nil
+}
+
+// publishAttestationResult notifies the attesting agent's owner of whether the attestation was
+// verified. Only verified results are sent to the callback URL: it is part of the signed attestation,
+// and an unverified request could name any agent. Attestations naming an unknown agent have no
+// owner to notify and are only reported to the caller.
+func (s *MCPAttestationService) publishAttestationResult(
+	ctx context.Context,
+	req *AttestMCPRequest,
+	mcpServerID *uuid.UUID,
+	response *AttestMCPResponse,
+	verifyErr error,
+) {
+	if s.webhookService == nil {
+		return
+	}
+	agentID, err := uuid.Parse(req.Attestation.AgentID)
+	if err != nil {
+		return
+	}
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil {
+		return
+	}
+
+	result := &domain.AttestationResult{
+		AgentID:     agent.ID,
+		AgentName:   agent.Name,
+		OwnerID:     agent.CreatedBy,
+		MCPServerID: mcpServerID,
+		MCPURL:      req.Attestation.MCPURL,
+		SubmittedAt: req.Attestation.Timestamp,
+		CompletedAt: time.Now().UTC(),
+	}
+	callbackURL := ""
+	if verifyErr != nil {
+		result.Status = domain.AttestationResultRejected
+		result.Reason = attestationRejectionReason(verifyErr)
+		result.Message = verifyErr.Error()
+	} else {
+		result.Status = domain.AttestationResultVerified
+		result.Message = response.Message
+		result.ConfidenceScore = response.MCPConfidenceScore
+		result.AgentVerifiedOnly = response.AgentVerifiedOnly
+		callbackURL = req.Attestation.CallbackURL
+		if id, err := uuid.Parse(response.AttestationID); err == nil {
+			result.AttestationID = &id
+		}
+		if id, err := uuid.Parse(response.MCPServerID); err == nil {
+			result.MCPServerID = &id
+		}
+	}
+
+	s.webhookService.PublishAttestationResult(ctx, agent.OrganizationID, result, callbackURL, req.Attestation.Nonce)
+}
+
+// verifyAttestation checks the attesting agent and the attestation's signature and freshness,
+// then consumes its nonce so the same signed attestation cannot be submitted again
+func (s *MCPAttestationService) verifyAttestation(ctx context.Context, req *AttestMCPRequest) (*domain.Agent, error) {
 	// 1. Parse agent ID from attestation
 	agentID, err := uuid.Parse(req.Attestation.AgentID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid agent_id in attestation: %w", err)
+		return nil, rejectAttestation(domain.AttestationRejectedSchema, fmt.Errorf("invalid agent_id in attestation: %w", err))
 	}
 
-	// 2. Fetch agent (MUST be Ed25519 verified)
+	// 2. Fetch agent (MUST be verified)
 	agent, err := s.agentRepo.GetByID(agentID)
 	if err != nil {
-		return nil, fmt.Errorf("agent not found: %w", err)
+		return nil, rejectAttestation(domain.AttestationRejectedAgentNotFound, fmt.Errorf("agent not found: %w", err))
 	}
 
 	fmt.Printf("🔍 Agent fetched from DB: ID=%s, Status=%s, VerifiedAt=%v\n", agent.ID, agent.Status, agent.VerifiedAt)
 
 	if agent.Status != domain.AgentStatusVerified {
 		fmt.Printf("❌ Agent status check failed: expected %s, got %s\n", domain.AgentStatusVerified, agent.Status)
-		return nil, fmt.Errorf("only verified agents can attest MCPs (agent status: %s)", agent.Status)
+		return nil, rejectAttestation(domain.AttestationRejectedAgentNotVerified, fmt.Errorf("only verified agents can attest MCPs (agent status: %s)", agent.Status))
 	}
 
 	fmt.Printf("✅ Agent status check passed: %s\n", agent.Status)
 
 	if agent.PublicKey == nil || *agent.PublicKey == "" {
-		return nil, fmt.Errorf("agent has no public key registered")
+		return nil, rejectAttestation(domain.AttestationRejectedNoPublicKey, fmt.Errorf("agent has no public key registered"))
 	}
 
 	// 3. Verify signature using agent's public key
@@ -97,15 +445,31 @@ func (s *MCPAttestationService) VerifyAndRecordAttestation(
 	fmt.Printf("   Signature (first 40): %s\n", req.Signature[:min(40, len(req.Signature))])
 	fmt.Printf("   Agent public key (first 20): %s\n", (*agent.PublicKey)[:min(20, len(*agent.PublicKey))])
 
-	valid, err := s.cryptoService.Verify(*agent.PublicKey, attestationJSON, req.Signature)
+	signature, err := base64.StdEncoding.DecodeString(req.Signature)
 	if err != nil {
 		fmt.Printf("❌ Crypto verification error: %v\n", err)
-		return nil, fmt.Errorf("signature verification failed: %w", err)
+		metrics.RecordAttestationSignatureFailure("malformed")
+		return nil, rejectAttestation(domain.AttestationRejectedSignatureMismatch, fmt.Errorf("signature verification failed: invalid signature encoding: %w", err))
 	}
 
-	if !valid {
-		fmt.Printf("❌ Signature verification returned FALSE\n")
-		return nil, fmt.Errorf("invalid attestation signature")
+	var org *domain.Organization
+	if s.orgRepo != nil {
+		if org, err = s.orgRepo.GetByID(agent.OrganizationID); err != nil {
+			return nil, fmt.Errorf("failed to get organization: %w", err)
+		}
+	}
+
+	// The agent's key verifies with its own algorithm; attestations signed with the previous key
+	// are accepted until its rotation grace period ends
+	if _, err := verifyAgentSignature(org, agent, "", attestationJSON, signature, time.Now()); err != nil {
+		if errors.Is(err, crypto.ErrInvalidSignature) {
+			fmt.Printf("❌ Signature verification returned FALSE\n")
+			metrics.RecordAttestationSignatureFailure("invalid")
+			return nil, rejectAttestation(domain.AttestationRejectedSignatureMismatch, fmt.Errorf("invalid attestation signature"))
+		}
+		fmt.Printf("❌ Crypto verification error: %v\n", err)
+		metrics.RecordAttestationSignatureFailure("malformed")
+		return nil, rejectAttestation(domain.AttestationRejectedSignatureMismatch, fmt.Errorf("signature verification failed: %w", err))
 	}
 
 	fmt.Printf("✅ Attestation signature verification PASSED\n")
@@ -113,19 +477,73 @@ func (s *MCPAttestationService) VerifyAndRecordAttestation(
 	// 4. Check attestation is recent (< 5 minutes old)
 	attestationTime, err := time.Parse(time.RFC3339, req.Attestation.Timestamp)
 	if err != nil {
-		return nil, fmt.Errorf("invalid timestamp format: %w", err)
+		return nil, rejectAttestation(domain.AttestationRejectedSchema, fmt.Errorf("invalid timestamp format: %w", err))
 	}
 
 	if time.Since(attestationTime) > 5*time.Minute {
-		return nil, fmt.Errorf("attestation expired (older than 5 minutes)")
+		return nil, rejectAttestation(domain.AttestationRejectedExpired, fmt.Errorf("attestation expired (older than 5 minutes)"))
 	}
 
-	// 5. Verify MCP server exists
-	if _, err := s.mcpRepo.GetByID(mcpServerID); err != nil {
-		return nil, fmt.Errorf("mcp server not found: %w", err)
+	// 5. Consume the nonce only after the signature checks out, so forged
+	// submissions cannot burn an agent's nonces. SDKs that predate nonces send none; their
+	// attestations are accepted until the organization requires nonces.
+	if req.Attestation.Nonce == "" {
+		if org == nil || !org.RequiresAttestationNonce() {
+			fmt.Printf("⚠️  Attestation without nonce accepted from agent %s (outdated SDK %q); set %s to reject these\n",
+				agent.ID, req.Attestation.SDKVersion, domain.OrganizationSettingRequireAttestationNonce)
+			return agent, nil
+		}
+		return nil, ErrAttestationNonceRequired
+	}
+	if err := s.nonceService.Consume(ctx, agent.ID, req.Attestation.Nonce); err != nil {
+		if errors.Is(err, ErrAttestationReplayed) {
+			fmt.Printf("🚨 Replayed attestation rejected for agent %s\n", agent.ID)
+		}
+		return nil, err
 	}
 
-	// 6. Store attestation
+	return agent, nil
+}
+
+// recordAttestation stores a verified attestation and updates the server's confidence
+// score and the agent's connection to it
+func (s *MCPAttestationService) recordAttestation(
+	ctx context.Context,
+	agent *domain.Agent,
+	mcpServerID uuid.UUID,
+	req *AttestMCPRequest,
+) (*AttestMCPResponse, error) {
+	agentID := agent.ID
+
+	// 7. Private network MCPs: the backend cannot reach these itself, so the
+	// attestation stands on the agent's word and is tagged "agent-verified only"
+	relay, err := s.attestationRepo.GetActiveRelay(mcpServerID, agentID)
+	if err != nil {
+		return nil, err
+	}
+	agentVerifiedOnly, err := s.isPrivateNetworkAttestation(mcpServerID, relay, req.Attestation.NetworkContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// 8. Diff the capabilities found against the server's registered and discovered capabilities,
+	// so that the discrepancy is recorded on the attestation
+	var server *domain.MCPServer
+	if (s.deprecationService != nil || s.claimService != nil) && len(req.Attestation.CapabilitiesFound) > 0 {
+		if server, err = s.mcpRepo.GetByID(mcpServerID); err != nil {
+			fmt.Printf("⚠️  Failed to load MCP server %s for capability reconciliation: %v\n", mcpServerID, err)
+			server = nil
+		}
+	}
+	var discrepancy *domain.CapabilityDiscrepancy
+	if server != nil && s.claimService != nil {
+		if discrepancy, err = s.claimService.Discrepancy(server, req.Attestation.CapabilitiesFound); err != nil {
+			fmt.Printf("⚠️  Failed to diff attested capabilities of %s: %v\n", server.Name, err)
+			discrepancy = nil
+		}
+	}
+
+	// 9. Store attestation
 	now := time.Now().UTC()
 	attestation := &domain.MCPAttestation{
 		ID:                uuid.New(),
@@ -138,48 +556,101 @@ func (s *MCPAttestationService) VerifyAndRecordAttestation(
 		ExpiresAt:         now.Add(30 * 24 * time.Hour), // 30 days
 		IsValid:           true,
 		CreatedAt:         now,
+		AgentVerifiedOnly: agentVerifiedOnly,
+
+		CapabilityDiscrepancy: discrepancy,
 	}
 
 	if err := s.attestationRepo.CreateAttestation(attestation); err != nil {
 		return nil, fmt.Errorf("failed to store attestation: %w", err)
 	}
 
-	// 7. Update MCP confidence score
+	if relay != nil {
+		if err := s.attestationRepo.TouchRelay(relay.ID, now); err != nil {
+			fmt.Printf("⚠️  Failed to update network relay %s: %v\n", relay.ID, err)
+		}
+	}
+
+	// 10. Update MCP confidence score
 	confidenceScore, attestationCount, err := s.updateMCPConfidenceScore(ctx, mcpServerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update confidence score: %w", err)
 	}
 
-	// 8. Update or create agent-MCP connection
+	// 11. Update or create agent-MCP connection
 	if err := s.updateAgentMCPConnection(ctx, agentID, mcpServerID, now); err != nil {
 		return nil, fmt.Errorf("failed to update agent-MCP connection: %w", err)
 	}
 
+	// 12. Deprecate agent capabilities referring to tools the attestation no longer found, and
+	// check whether the agent's attestations keep disagreeing with the server
+	if server != nil {
+		if s.deprecationService != nil {
+			if _, err := s.deprecationService.ReconcileAttestedTools(ctx, server, req.Attestation.CapabilitiesFound); err != nil {
+				fmt.Printf("⚠️  Failed to reconcile capability deprecations for %s: %v\n", server.Name, err)
+			}
+		}
+		if s.claimService != nil {
+			if _, err := s.claimService.CheckAttestation(ctx, server, agent); err != nil {
+				fmt.Printf("⚠️  Failed to compare attested capabilities of %s: %v\n", server.Name, err)
+			}
+		}
+	}
+
+	message := "MCP attestation verified and recorded"
+	if agentVerifiedOnly {
+		message = "MCP attestation verified and recorded (agent-verified only: private network MCP)"
+	}
+
 	return &AttestMCPResponse{
 		Success:            true,
 		AttestationID:      attestation.ID.String(),
 		MCPConfidenceScore: confidenceScore,
 		AttestationCount:   attestationCount,
-		Message:            "MCP attestation verified and recorded",
+		AgentVerifiedOnly:  agentVerifiedOnly,
+		Message:            message,
+
+		CapabilityDiscrepancy: discrepancy,
 	}, nil
 }
 
-// updateMCPConfidenceScore calculates and updates the confidence score for an MCP server
-func (s *MCPAttestationService) updateMCPConfidenceScore(
-	ctx context.Context,
+// isPrivateNetworkAttestation decides whether an attestation can only be taken on the
+// agent's word: the agent is a registered relay, the SDK reported private network
+// evidence, or the MCP server is known to sit behind relays
+func (s *MCPAttestationService) isPrivateNetworkAttestation(
 	mcpServerID uuid.UUID,
-) (float64, int, error) {
-	// Get all valid attestations for this MCP
-	attestations, err := s.attestationRepo.GetValidAttestationsByMCP(mcpServerID)
-	if err != nil {
-		return 0, 0, err
+	relay *domain.MCPNetworkRelay,
+	networkContext *domain.NetworkContext,
+) (bool, error) {
+	if relay != nil {
+		return true, nil
+	}
+	if networkContext != nil && networkContext.PrivateNetwork {
+		return true, nil
 	}
+	return s.attestationRepo.HasActiveRelays(mcpServerID)
+}
 
+// AgentVerifiedOnlyScale is the factor by which a server's confidence score is lowered for its
+// agent-verified only attestations: agentVerifiedOnlyPenalty in proportion to their share of the
+// server's attestations
+func AgentVerifiedOnlyScale(attestations []*domain.MCPAttestation) float64 {
 	if len(attestations) == 0 {
-		// No attestations - confidence is 0
-		return 0, 0, nil
+		return 1
+	}
+	agentVerifiedOnly := 0
+	for _, attestation := range attestations {
+		if attestation.AgentVerifiedOnly {
+			agentVerifiedOnly++
+		}
 	}
+	return 1 - agentVerifiedOnlyPenalty*float64(agentVerifiedOnly)/float64(len(attestations))
+}
 
+// mcpAttestationConfidence scores an MCP server's valid attestations 0-100 from the number of
+// agents attesting it, their average trust score and how recent the attestations are, and
+// returns the time of the most recent one
+func mcpAttestationConfidence(attestations []*domain.MCPAttestation, now time.Time) (float64, time.Time) {
 	// Confidence calculation factors:
 	// 1. Number of unique agents attesting (20 points each, max 5 agents = 100)
 	// 2. Average trust score of attesting agents (0-50 points)
@@ -215,7 +686,7 @@ func (s *MCPAttestationService) updateMCPConfidenceScore(
 	// Factor 3: Recency factor (% of attestations in last 7 days)
 	recentCount := 0
 	for _, att := range attestations {
-		if att.VerifiedAt != nil && time.Since(*att.VerifiedAt) < 7*24*time.Hour {
+		if att.VerifiedAt != nil && now.Sub(*att.VerifiedAt) < 7*24*time.Hour {
 			recentCount++
 		}
 	}
@@ -228,8 +699,46 @@ func (s *MCPAttestationService) updateMCPConfidenceScore(
 		confidenceScore = 100.0
 	}
 
+	return confidenceScore, mostRecentAttestation
+}
+
+// updateMCPConfidenceScore calculates and updates the confidence score for an MCP server
+func (s *MCPAttestationService) updateMCPConfidenceScore(
+	ctx context.Context,
+	mcpServerID uuid.UUID,
+) (float64, int, error) {
+	// Get all valid attestations for this MCP
+	attestations, err := s.attestationRepo.GetValidAttestationsByMCP(mcpServerID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(attestations) == 0 {
+		// No attestations - confidence is 0
+		return 0, 0, nil
+	}
+
+	confidenceScore, mostRecentAttestation := mcpAttestationConfidence(attestations, time.Now())
+
+	// Servers out of the attestation cadence of their criticality are trusted less
+	if s.cadenceService != nil {
+		if server, err := s.mcpRepo.GetByID(mcpServerID); err == nil {
+			if status, err := s.cadenceService.Evaluate(ctx, server, attestations); err == nil {
+				confidenceScore *= status.ConfidenceScale
+			} else {
+				fmt.Printf("Failed to evaluate attestation cadence of MCP %s: %v\n", mcpServerID, err)
+			}
+		}
+	}
+
+	// Servers whose attestors consistently see missing or extra capabilities are trusted less
+	confidenceScore *= CapabilityDiscrepancyScale(attestations)
+
+	// Private network servers stand on their agents' word and are trusted less
+	confidenceScore *= AgentVerifiedOnlyScale(attestations)
+
 	// Update MCP server
-	err = s.attestationRepo.UpdateMCPConfidenceScore(
+	err = s.mcpRepo.UpdateConfidenceScore(
 		mcpServerID,
 		confidenceScore,
 		len(attestations),
@@ -299,6 +808,34 @@ func (s *MCPAttestationService) GetMCPAttestations(
 		return nil, 0, time.Time{}, err
 	}
 
+	// Load the attesting agents, their owners and the users behind manual attestations
+	// with one query each instead of one per attestation
+	var agentIDs, userIDs []uuid.UUID
+	for _, att := range attestations {
+		if att.Signature == "manual-attestation" {
+			if userID, err := uuid.Parse(att.AttestationData.AgentID); err == nil {
+				userIDs = append(userIDs, userID)
+			}
+		} else if att.AgentID != nil {
+			agentIDs = append(agentIDs, *att.AgentID)
+		}
+	}
+
+	agentList, err := s.agentRepo.GetByIDs(agentIDs)
+	if err != nil {
+		return nil, 0, time.Time{}, fmt.Errorf("failed to load attesting agents: %w", err)
+	}
+	agents := agentsByID(agentList)
+	for _, agent := range agentList {
+		userIDs = append(userIDs, agent.CreatedBy)
+	}
+
+	userList, err := s.userRepo.GetByIDs(userIDs)
+	if err != nil {
+		return nil, 0, time.Time{}, fmt.Errorf("failed to load attesting users: %w", err)
+	}
+	users := usersByID(userList)
+
 	// Convert to response format with enriched metadata
 	var result []*domain.AttestationWithAgentDetails
 	var lastAttestedAt time.Time
@@ -324,8 +861,7 @@ func (s *MCPAttestationService) GetMCPAttestations(
 
 			// Parse user ID from AttestationData.AgentID (for manual attestations, this holds the userID)
 			if userID, err := uuid.Parse(att.AttestationData.AgentID); err == nil {
-				// Fetch user details
-				if user, err := s.userRepo.GetByID(userID); err == nil && user != nil {
+				if user, ok := users[userID]; ok {
 					attestedBy = user.Name
 				} else {
 					attestedBy = "Unknown User"
@@ -344,8 +880,8 @@ func (s *MCPAttestationService) GetMCPAttestations(
 			if att.AgentName != "" {
 				attestedBy = att.AgentName
 			} else if att.AgentID != nil {
-				// Fallback: fetch agent name if not already populated
-				if agent, err := s.agentRepo.GetByID(*att.AgentID); err == nil && agent != nil {
+				// Fallback: use the agent name if not already populated
+				if agent, ok := agents[*att.AgentID]; ok {
 					attestedBy = agent.Name
 				} else {
 					attestedBy = "Unknown Agent"
@@ -354,12 +890,12 @@ func (s *MCPAttestationService) GetMCPAttestations(
 				attestedBy = "Unknown Agent"
 			}
 
-			// Fetch agent owner information for SDK attestations
+			// Agent owner information for SDK attestations
 			if att.AgentID != nil {
-				if agent, err := s.agentRepo.GetByID(*att.AgentID); err == nil && agent != nil {
+				if agent, ok := agents[*att.AgentID]; ok {
 					agentOwnerID = agent.CreatedBy
-					// Fetch the user who created/owns this agent
-					if owner, err := s.userRepo.GetByID(agentOwnerID); err == nil && owner != nil {
+					// The user who created/owns this agent
+					if owner, ok := users[agentOwnerID]; ok {
 						agentOwnerName = owner.Name
 					}
 				}
@@ -392,6 +928,9 @@ func (s *MCPAttestationService) GetMCPAttestations(
 				ConnectionSuccessful: att.AttestationData.ConnectionSuccessful,
 				AgentOwnerName:       agentOwnerName,
 				AgentOwnerID:         agentOwnerID,
+				AgentVerifiedOnly:    att.AgentVerifiedOnly,
+
+				CapabilityDiscrepancy: att.CapabilityDiscrepancy,
 			})
 			continue
 		}
@@ -416,6 +955,9 @@ func (s *MCPAttestationService) GetMCPAttestations(
 			SignatureVerified:    att.SignatureVerified,
 			SDKVersion:           att.AttestationData.SDKVersion,
 			ConnectionSuccessful: att.AttestationData.ConnectionSuccessful,
+			AgentVerifiedOnly:    att.AgentVerifiedOnly,
+
+			CapabilityDiscrepancy: att.CapabilityDiscrepancy,
 		})
 	}
 
@@ -433,19 +975,25 @@ func (s *MCPAttestationService) GetConnectedAgentsForMCP(
 		return nil, err
 	}
 
-	// Fetch agent details
-	var agents []*domain.Agent
+	// Fetch agent details in one query, keeping the connection order
+	var agentIDs []uuid.UUID
 	for _, conn := range connections {
-		if !conn.IsActive {
-			continue // Skip inactive connections
+		if conn.IsActive { // Skip inactive connections
+			agentIDs = append(agentIDs, conn.AgentID)
 		}
+	}
 
-		agent, err := s.agentRepo.GetByID(conn.AgentID)
-		if err != nil {
-			continue // Skip if agent not found
-		}
+	agentList, err := s.agentRepo.GetByIDs(agentIDs)
+	if err != nil {
+		return nil, err
+	}
+	index := agentsByID(agentList)
 
-		agents = append(agents, agent)
+	var agents []*domain.Agent
+	for _, agentID := range agentIDs {
+		if agent, ok := index[agentID]; ok { // Skip if agent not found
+			agents = append(agents, agent)
+		}
 	}
 
 	return agents, nil
@@ -462,47 +1010,176 @@ func (s *MCPAttestationService) GetMCPServersForAgent(
 		return nil, err
 	}
 
-	// Fetch MCP server details
-	var mcpServers []*domain.MCPServer
+	// Fetch MCP server details in one query, keeping the connection order
+	var mcpServerIDs []uuid.UUID
 	for _, conn := range connections {
-		if !conn.IsActive {
-			continue // Skip inactive connections
+		if conn.IsActive { // Skip inactive connections
+			mcpServerIDs = append(mcpServerIDs, conn.MCPServerID)
 		}
+	}
 
-		mcpServer, err := s.mcpRepo.GetByID(conn.MCPServerID)
-		if err != nil {
-			continue // Skip if MCP not found
-		}
+	serverList, err := s.mcpRepo.GetByIDs(mcpServerIDs)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[uuid.UUID]*domain.MCPServer, len(serverList))
+	for _, server := range serverList {
+		index[server.ID] = server
+	}
 
-		mcpServers = append(mcpServers, mcpServer)
+	var mcpServers []*domain.MCPServer
+	for _, mcpServerID := range mcpServerIDs {
+		if mcpServer, ok := index[mcpServerID]; ok { // Skip if MCP not found
+			mcpServers = append(mcpServers, mcpServer)
+		}
 	}
 
 	return mcpServers, nil
 }
 
-// InvalidateExpiredAttestations is a background job to invalidate expired attestations
-func (s *MCPAttestationService) InvalidateExpiredAttestations(ctx context.Context) error {
-	return s.attestationRepo.InvalidateExpiredAttestations()
+// InvalidateAgentAttestations invalidates every valid attestation made by an agent and
+// recalculates the confidence score of the attested MCP servers.
+// Returns the number of invalidated attestations and the affected MCP server IDs.
+func (s *MCPAttestationService) InvalidateAgentAttestations(
+	ctx context.Context,
+	agentID uuid.UUID,
+) (int, []uuid.UUID, error) {
+	attestations, err := s.attestationRepo.GetAttestationsByAgent(agentID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	invalidated := 0
+	lastAttested := make(map[uuid.UUID]time.Time)
+	var mcpServerIDs []uuid.UUID
+	for _, att := range attestations {
+		if !att.IsValid {
+			continue
+		}
+		if err := s.attestationRepo.InvalidateAttestation(att.ID); err != nil {
+			return invalidated, mcpServerIDs, err
+		}
+		invalidated++
+
+		if _, seen := lastAttested[att.MCPServerID]; !seen {
+			mcpServerIDs = append(mcpServerIDs, att.MCPServerID)
+			lastAttested[att.MCPServerID] = time.Time{}
+		}
+		if att.VerifiedAt != nil && att.VerifiedAt.After(lastAttested[att.MCPServerID]) {
+			lastAttested[att.MCPServerID] = *att.VerifiedAt
+		}
+	}
+
+	for _, mcpServerID := range mcpServerIDs {
+		_, count, err := s.updateMCPConfidenceScore(ctx, mcpServerID)
+		if err != nil {
+			fmt.Printf("Failed to update confidence score for MCP %s: %v\n", mcpServerID, err)
+			continue
+		}
+		if count == 0 {
+			// updateMCPConfidenceScore leaves the score untouched when no attestation remains
+			if err := s.mcpRepo.UpdateConfidenceScore(mcpServerID, 0, 0, lastAttested[mcpServerID]); err != nil {
+				fmt.Printf("Failed to reset confidence score for MCP %s: %v\n", mcpServerID, err)
+			}
+		}
+	}
+
+	return invalidated, mcpServerIDs, nil
 }
 
-// RecalculateAllConfidenceScores recalculates confidence scores for all MCPs (background job)
-func (s *MCPAttestationService) RecalculateAllConfidenceScores(ctx context.Context) error {
-	// Get all MCP servers
-	mcpServers, err := s.mcpRepo.List(1000, 0) // Get up to 1000 MCPs
+// InvalidateExpiredAttestations is a background job to invalidate expired attestations.
+// Each expired attestation is published to the MCP server organization's webhooks.
+// Returns the number of attestations expired.
+func (s *MCPAttestationService) InvalidateExpiredAttestations(ctx context.Context) (int, error) {
+	expired, err := s.attestationRepo.InvalidateExpiredAttestations()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	// Recalculate each one
-	for _, mcp := range mcpServers {
-		_, _, err := s.updateMCPConfidenceScore(ctx, mcp.ID)
+	if s.webhookService != nil {
+		servers := make(map[uuid.UUID]*domain.MCPServer)
+		for _, attestation := range expired {
+			server, ok := servers[attestation.MCPServerID]
+			if !ok {
+				server, err = s.mcpRepo.GetByID(attestation.MCPServerID)
+				if err != nil {
+					fmt.Printf("⚠️  Failed to load MCP server %s for expired attestation %s: %v\n", attestation.MCPServerID, attestation.ID, err)
+					continue
+				}
+				servers[attestation.MCPServerID] = server
+			}
+			s.webhookService.PublishAttestationExpired(ctx, server.OrganizationID, attestation)
+		}
+	}
+
+	return len(expired), nil
+}
+
+// RecalculateAllConfidenceScores recalculates confidence scores for all MCPs (background job).
+// Servers left without valid attestations drop to zero. When alertThreshold is positive, an
+// alert is raised for each server whose score falls from at or above the threshold to below it.
+// Returns the number of servers recalculated.
+func (s *MCPAttestationService) RecalculateAllConfidenceScores(ctx context.Context, alertThreshold float64) (int, error) {
+	recalculated := 0
+	for offset := 0; ; offset += confidenceRecalculationBatchSize {
+		mcpServers, err := s.mcpRepo.List(confidenceRecalculationBatchSize, offset)
 		if err != nil {
-			// Log error but continue with next MCP
-			fmt.Printf("Failed to update confidence score for MCP %s: %v\n", mcp.ID, err)
+			return recalculated, err
+		}
+
+		for _, mcp := range mcpServers {
+			score, count, err := s.updateMCPConfidenceScore(ctx, mcp.ID)
+			if err != nil {
+				// Log error but continue with next MCP
+				fmt.Printf("Failed to update confidence score for MCP %s: %v\n", mcp.ID, err)
+				continue
+			}
+			if count == 0 && mcp.AttestationCount > 0 {
+				// updateMCPConfidenceScore leaves the score untouched when no attestation remains
+				var lastAttestedAt time.Time
+				if mcp.LastAttestedAt != nil {
+					lastAttestedAt = *mcp.LastAttestedAt
+				}
+				if err := s.mcpRepo.UpdateConfidenceScore(mcp.ID, 0, 0, lastAttestedAt); err != nil {
+					fmt.Printf("Failed to reset confidence score for MCP %s: %v\n", mcp.ID, err)
+					continue
+				}
+			}
+			recalculated++
+
+			if alertThreshold > 0 && mcp.ConfidenceScore >= alertThreshold && score < alertThreshold {
+				s.alertLowConfidence(ctx, mcp, score, alertThreshold)
+			}
+		}
+
+		if len(mcpServers) < confidenceRecalculationBatchSize {
+			return recalculated, nil
 		}
 	}
+}
 
-	return nil
+// alertLowConfidence raises an alert for an MCP server whose confidence score fell below the threshold
+func (s *MCPAttestationService) alertLowConfidence(ctx context.Context, mcp *domain.MCPServer, score, threshold float64) {
+	if s.alertService == nil {
+		return
+	}
+
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: mcp.OrganizationID,
+		AlertType:      domain.AlertMCPConfidenceLow,
+		Severity:       domain.AlertSeverityWarning,
+		Title:          fmt.Sprintf("MCP server below attestation threshold: %s", mcp.Name),
+		Description: fmt.Sprintf("Confidence score of %s dropped from %.1f to %.1f, below the threshold of %.1f. "+
+			"Attestations expired or were invalidated; agents using this server should attest it again.",
+			mcp.URL, mcp.ConfidenceScore, score, threshold),
+		ResourceType: "mcp_server",
+		ResourceID:   mcp.ID,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := s.alertService.CreateAlert(ctx, alert); err != nil {
+		fmt.Printf("⚠️  Failed to create low confidence alert for MCP server %s: %v\n", mcp.ID, err)
+	}
 }
 
 // ToCanonicalJSON is a helper to ensure consistent JSON serialization
@@ -628,3 +1305,69 @@ func (s *MCPAttestationService) RecordAgentMCPConnection(
 
 	return connection, nil
 }
+
+// CreateNetworkRelayRequest registers an agent as the relay for a private network MCP server
+type CreateNetworkRelayRequest struct {
+	AgentID     uuid.UUID `json:"agent_id"`
+	NetworkZone string    `json:"network_zone"`
+	Description string    `json:"description"`
+}
+
+// CreateNetworkRelay records that an agent reaches an MCP server over a private network.
+// From then on the backend skips its own connectivity checks for that server.
+func (s *MCPAttestationService) CreateNetworkRelay(
+	ctx context.Context,
+	mcpServerID uuid.UUID,
+	orgID uuid.UUID,
+	userID uuid.UUID,
+	req *CreateNetworkRelayRequest,
+) (*domain.MCPNetworkRelay, error) {
+	mcpServer, err := s.mcpRepo.GetByID(mcpServerID)
+	if err != nil {
+		return nil, fmt.Errorf("mcp server not found")
+	}
+	if mcpServer.OrganizationID != orgID {
+		return nil, fmt.Errorf("mcp server not found")
+	}
+
+	agent, err := s.agentRepo.GetByID(req.AgentID)
+	if err != nil || agent.OrganizationID != orgID {
+		return nil, fmt.Errorf("agent not found")
+	}
+
+	now := time.Now().UTC()
+	relay := &domain.MCPNetworkRelay{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		MCPServerID:    mcpServerID,
+		AgentID:        agent.ID,
+		NetworkZone:    req.NetworkZone,
+		Description:    req.Description,
+		IsActive:       true,
+		CreatedBy:      &userID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := s.attestationRepo.CreateRelay(relay); err != nil {
+		return nil, err
+	}
+
+	return relay, nil
+}
+
+// ListNetworkRelays returns the relay records for an MCP server
+func (s *MCPAttestationService) ListNetworkRelays(ctx context.Context, mcpServerID uuid.UUID) ([]*domain.MCPNetworkRelay, error) {
+	return s.attestationRepo.GetRelaysByMCP(mcpServerID)
+}
+
+// GetNetworkRelay retrieves a relay record by ID
+func (s *MCPAttestationService) GetNetworkRelay(ctx context.Context, id uuid.UUID) (*domain.MCPNetworkRelay, error) {
+	return s.attestationRepo.GetRelayByID(id)
+}
+
+// DeleteNetworkRelay removes a relay record; once no relays remain the backend
+// resumes its own connectivity checks for the MCP server
+func (s *MCPAttestationService) DeleteNetworkRelay(ctx context.Context, id uuid.UUID) error {
+	return s.attestationRepo.DeleteRelay(id)
+}
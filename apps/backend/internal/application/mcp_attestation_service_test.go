@@ -0,0 +1,214 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoRegisteredMCPName(t *testing.T) {
+	existing := []*domain.MCPServer{
+		{Name: "filesystem-mcp"},
+		{Name: "filesystem-mcp-2"},
+		{Name: "tools.internal:8080"},
+	}
+
+	tests := []struct {
+		name     string
+		payload  domain.AttestationPayload
+		expected string
+	}{
+		{"attested name", domain.AttestationPayload{MCPName: "github-mcp", MCPURL: "https://github-mcp.example.com"}, "github-mcp"},
+		{"taken name gets the next free suffix", domain.AttestationPayload{MCPName: "filesystem-mcp"}, "filesystem-mcp-3"},
+		{"falls back to the URL host", domain.AttestationPayload{MCPName: "  ", MCPURL: "https://search.example.com/mcp"}, "search.example.com"},
+		{"host fallback is suffixed too", domain.AttestationPayload{MCPURL: "http://tools.internal:8080"}, "tools.internal:8080-2"},
+		{"no usable name or host", domain.AttestationPayload{MCPURL: "stdio"}, "attested-mcp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, autoRegisteredMCPName(&tt.payload, existing))
+		})
+	}
+}
+
+func TestNormalizeMCPURL(t *testing.T) {
+	assert.Equal(t, "https://mcp.example.com/sse", normalizeMCPURL(" https://MCP.example.com/sse/ "))
+	assert.Equal(t, normalizeMCPURL("https://mcp.example.com"), normalizeMCPURL("https://mcp.example.com/"))
+	assert.Empty(t, normalizeMCPURL("   "))
+}
+
+func TestAttestationRejectionReason(t *testing.T) {
+	signature := rejectAttestation(domain.AttestationRejectedSignatureMismatch, errors.New("invalid attestation signature"))
+	assert.Equal(t, "invalid attestation signature", signature.Error())
+	assert.Equal(t, domain.AttestationRejectedSignatureMismatch, attestationRejectionReason(signature))
+	assert.Equal(t, domain.AttestationRejectedSignatureMismatch, attestationRejectionReason(fmt.Errorf("wrapped: %w", signature)))
+
+	assert.Equal(t, domain.AttestationRejectedNonce, attestationRejectionReason(ErrAttestationReplayed))
+	assert.Equal(t, domain.AttestationRejectedServerNotFound, attestationRejectionReason(ErrMCPServerNotRegistered))
+	assert.Equal(t, domain.AttestationRejectedInternal, attestationRejectionReason(errors.New("database unavailable")))
+}
+
+func TestValidateAttestationCallback(t *testing.T) {
+	assert.NoError(t, validateAttestationCallback(""))
+	assert.NoError(t, validateAttestationCallback("https://sdk.example.com/attestations"))
+	assert.ErrorIs(t, validateAttestationCallback("/attestations"), ErrInvalidAttestationCallback)
+	assert.ErrorIs(t, validateAttestationCallback("ftp://sdk.example.com"), ErrInvalidAttestationCallback)
+	assert.ErrorIs(t, validateAttestationCallback("http://localhost:8080/attestations"), ErrInvalidAttestationCallback)
+	assert.ErrorIs(t, validateAttestationCallback("http://10.0.0.5/attestations"), ErrInvalidAttestationCallback)
+	assert.ErrorIs(t, validateAttestationCallback("http://169.254.169.254/latest/meta-data"), ErrInvalidAttestationCallback)
+	assert.ErrorIs(t, validateAttestationCallback("http://[::1]:9000/"), ErrInvalidAttestationCallback)
+}
+
+func TestIsPrivateNetworkAttestation(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	public := testsupport.NewMCPServer(org.ID)
+	private := testsupport.NewMCPServer(org.ID)
+	retired := testsupport.NewMCPServer(org.ID)
+	relayAgent := testsupport.NewAgent(org.ID)
+	relay := &domain.MCPNetworkRelay{OrganizationID: org.ID, MCPServerID: private.ID, AgentID: relayAgent.ID, IsActive: true}
+	require.NoError(t, repos.MCPAttestation.CreateRelay(relay))
+	require.NoError(t, repos.MCPAttestation.CreateRelay(&domain.MCPNetworkRelay{
+		OrganizationID: org.ID, MCPServerID: retired.ID, AgentID: relayAgent.ID, IsActive: false,
+	}))
+	service := &MCPAttestationService{attestationRepo: repos.MCPAttestation}
+
+	tests := []struct {
+		name           string
+		server         *domain.MCPServer
+		relay          *domain.MCPNetworkRelay
+		networkContext *domain.NetworkContext
+		expected       bool
+	}{
+		{name: "public server", server: public},
+		{name: "public network evidence", server: public, networkContext: &domain.NetworkContext{NetworkZone: "dmz"}},
+		{name: "private network evidence", server: public, networkContext: &domain.NetworkContext{PrivateNetwork: true}, expected: true},
+		{name: "registered relay", server: private, relay: relay, expected: true},
+		{name: "other agent of a relayed server", server: private, expected: true},
+		{name: "inactive relays only", server: retired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privateNetwork, err := service.isPrivateNetworkAttestation(tt.server.ID, tt.relay, tt.networkContext)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, privateNetwork)
+		})
+	}
+}
+
+func TestRecordAttestationThroughNetworkRelay(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	ctx := context.Background()
+	org := testsupport.NewOrganization()
+	public := testsupport.NewMCPServer(org.ID)
+	private := testsupport.NewMCPServer(org.ID)
+	relayAgent := testsupport.NewAgent(org.ID)
+	for _, server := range []*domain.MCPServer{public, private} {
+		require.NoError(t, repos.MCPServer.Create(server))
+	}
+	require.NoError(t, repos.Agent.Create(relayAgent))
+	relay := &domain.MCPNetworkRelay{OrganizationID: org.ID, MCPServerID: private.ID, AgentID: relayAgent.ID, NetworkZone: "vpc-a", IsActive: true}
+	require.NoError(t, repos.MCPAttestation.CreateRelay(relay))
+	service := &MCPAttestationService{attestationRepo: repos.MCPAttestation, agentRepo: repos.Agent, mcpRepo: repos.MCPServer}
+
+	attest := func(server *domain.MCPServer) *AttestMCPResponse {
+		attestation := testsupport.NewMCPAttestation(server, relayAgent)
+		response, err := service.recordAttestation(ctx, relayAgent, server.ID, &AttestMCPRequest{
+			Attestation: attestation.AttestationData,
+			Signature:   attestation.Signature,
+		})
+		require.NoError(t, err)
+		return response
+	}
+
+	// The relay's attestation is recorded without the backend reaching the server, tagged
+	// agent-verified only, and the relay records when it last attested
+	relayed := attest(private)
+	assert.True(t, relayed.AgentVerifiedOnly)
+	assert.Contains(t, relayed.Message, "agent-verified only")
+	stored, err := repos.MCPAttestation.GetAttestationByID(uuid.MustParse(relayed.AttestationID))
+	require.NoError(t, err)
+	assert.True(t, stored.AgentVerifiedOnly)
+	touched, err := repos.MCPAttestation.GetRelayByID(relay.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, touched.LastAttestedAt)
+
+	// The same attestation of a server the backend can reach scores higher
+	direct := attest(public)
+	assert.False(t, direct.AgentVerifiedOnly)
+	assert.InDelta(t, direct.MCPConfidenceScore*(1-agentVerifiedOnlyPenalty), relayed.MCPConfidenceScore, 1e-9)
+	updated, err := repos.MCPServer.GetByID(private.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, relayed.MCPConfidenceScore, updated.ConfidenceScore, 1e-9)
+}
+
+func TestAgentVerifiedOnlyScale(t *testing.T) {
+	attestations := func(agentVerifiedOnly ...bool) []*domain.MCPAttestation {
+		var result []*domain.MCPAttestation
+		for _, flag := range agentVerifiedOnly {
+			result = append(result, &domain.MCPAttestation{AgentVerifiedOnly: flag})
+		}
+		return result
+	}
+	assert.Equal(t, 1.0, AgentVerifiedOnlyScale(nil))
+	assert.Equal(t, 1.0, AgentVerifiedOnlyScale(attestations(false, false)))
+	assert.InDelta(t, 1-agentVerifiedOnlyPenalty/2, AgentVerifiedOnlyScale(attestations(true, false)), 1e-9)
+	assert.InDelta(t, 1-agentVerifiedOnlyPenalty, AgentVerifiedOnlyScale(attestations(true, true, true)), 1e-9)
+}
+
+func TestVerifyAttestationWithoutNonceFromOlderSDKs(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	keyPair, err := crypto.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	publicKey := crypto.EncodeKeyPair(keyPair).PublicKeyBase64
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.PublicKey = &publicKey })
+	require.NoError(t, repos.Agent.Create(agent))
+	nonceService := NewAttestationNonceService(repos.AttestationNonce)
+	service := &MCPAttestationService{agentRepo: repos.Agent, orgRepo: repos.Organization, nonceService: nonceService}
+
+	attest := func(nonce string) error {
+		payload := domain.AttestationPayload{
+			AgentID:              agent.ID.String(),
+			CapabilitiesFound:    []string{"query_stock"},
+			ConnectionSuccessful: true,
+			MCPName:              "inventory-mcp",
+			MCPURL:               "https://mcp.example.com",
+			Nonce:                nonce,
+			SDKVersion:           "aim-sdk-python@1.0.0",
+			Timestamp:            time.Now().UTC().Format(time.RFC3339),
+		}
+		message, err := payload.ToCanonicalJSON()
+		require.NoError(t, err)
+		_, err = service.verifyAttestation(context.Background(), &AttestMCPRequest{
+			Attestation: payload,
+			Signature:   base64.StdEncoding.EncodeToString(crypto.SignMessage(keyPair.PrivateKey, message)),
+		})
+		return err
+	}
+
+	// SDKs that predate nonces keep working until the organization requires them
+	assert.NoError(t, attest(""))
+
+	org.Settings = map[string]interface{}{domain.OrganizationSettingRequireAttestationNonce: true}
+	require.NoError(t, repos.Organization.Update(org))
+	assert.ErrorIs(t, attest(""), ErrAttestationNonceRequired)
+
+	// Nonces are consumed either way
+	nonce, err := nonceService.Issue(context.Background(), agent)
+	require.NoError(t, err)
+	require.NoError(t, attest(nonce.Nonce))
+	assert.ErrorIs(t, attest(nonce.Nonce), ErrAttestationReplayed)
+}
@@ -0,0 +1,106 @@
+package application
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// mcpToolRiskKeywords maps verbs found in MCP capability names to the risk they imply.
+// Names are split into words ("deleteFile", "send_email", "http-post") before lookup.
+var mcpToolRiskKeywords = map[string]domain.MCPToolRisk{
+	// Deletes data or runs arbitrary code
+	"delete": domain.MCPToolRiskDestructive, "remove": domain.MCPToolRiskDestructive,
+	"rm": domain.MCPToolRiskDestructive, "drop": domain.MCPToolRiskDestructive,
+	"destroy": domain.MCPToolRiskDestructive, "truncate": domain.MCPToolRiskDestructive,
+	"purge": domain.MCPToolRiskDestructive, "wipe": domain.MCPToolRiskDestructive,
+	"erase": domain.MCPToolRiskDestructive, "kill": domain.MCPToolRiskDestructive,
+	"terminate": domain.MCPToolRiskDestructive, "shutdown": domain.MCPToolRiskDestructive,
+	"revoke": domain.MCPToolRiskDestructive, "reset": domain.MCPToolRiskDestructive,
+	"exec": domain.MCPToolRiskDestructive, "execute": domain.MCPToolRiskDestructive,
+	"shell": domain.MCPToolRiskDestructive, "command": domain.MCPToolRiskDestructive,
+	"eval": domain.MCPToolRiskDestructive,
+
+	// Moves data out of the organization
+	"send": domain.MCPToolRiskExfiltration, "upload": domain.MCPToolRiskExfiltration,
+	"export": domain.MCPToolRiskExfiltration, "share": domain.MCPToolRiskExfiltration,
+	"publish": domain.MCPToolRiskExfiltration, "forward": domain.MCPToolRiskExfiltration,
+	"transfer": domain.MCPToolRiskExfiltration, "notify": domain.MCPToolRiskExfiltration,
+	"webhook": domain.MCPToolRiskExfiltration, "http": domain.MCPToolRiskExfiltration,
+	"tweet": domain.MCPToolRiskExfiltration,
+
+	// Creates or modifies data
+	"write": domain.MCPToolRiskWrite, "create": domain.MCPToolRiskWrite,
+	"update": domain.MCPToolRiskWrite, "edit": domain.MCPToolRiskWrite,
+	"modify": domain.MCPToolRiskWrite, "set": domain.MCPToolRiskWrite,
+	"insert": domain.MCPToolRiskWrite, "upsert": domain.MCPToolRiskWrite,
+	"put": domain.MCPToolRiskWrite, "patch": domain.MCPToolRiskWrite,
+	"save": domain.MCPToolRiskWrite, "add": domain.MCPToolRiskWrite,
+	"append": domain.MCPToolRiskWrite, "move": domain.MCPToolRiskWrite,
+	"rename": domain.MCPToolRiskWrite, "commit": domain.MCPToolRiskWrite,
+	"push": domain.MCPToolRiskWrite, "deploy": domain.MCPToolRiskWrite,
+	"install": domain.MCPToolRiskWrite, "upgrade": domain.MCPToolRiskWrite,
+}
+
+// mcpToolRiskRank orders risks so the most dangerous keyword in a name wins
+var mcpToolRiskRank = map[domain.MCPToolRisk]int{
+	domain.MCPToolRiskRead:         0,
+	domain.MCPToolRiskWrite:        1,
+	domain.MCPToolRiskExfiltration: 2,
+	domain.MCPToolRiskDestructive:  3,
+}
+
+// ClassifyMCPToolRisk classifies a capability from the words in its name. Resources and
+// prompts only expose data to the agent, so they are always read. Descriptions are not
+// used: they are free text ("never deletes anything") and would cause false positives.
+func ClassifyMCPToolRisk(name string, capabilityType domain.MCPCapabilityType) domain.MCPToolRisk {
+	if capabilityType == domain.MCPCapabilityTypeResource || capabilityType == domain.MCPCapabilityTypePrompt {
+		return domain.MCPToolRiskRead
+	}
+
+	risk := domain.MCPToolRiskRead
+	for _, word := range splitCapabilityName(name) {
+		if candidate, ok := mcpToolRiskKeywords[word]; ok && mcpToolRiskRank[candidate] > mcpToolRiskRank[risk] {
+			risk = candidate
+		}
+	}
+	return risk
+}
+
+// classifyMCPCapability fills in the capability's effective risk, preferring an admin override
+func classifyMCPCapability(capability *domain.MCPServerCapability) {
+	capability.RiskLevel = ClassifyMCPToolRisk(capability.Name, capability.CapabilityType)
+	if capability.RiskOverride != nil && capability.RiskOverride.IsValid() {
+		capability.RiskLevel = *capability.RiskOverride
+	}
+	capability.Sensitive = capability.RiskLevel.IsSensitive()
+}
+
+// splitCapabilityName lowercases a capability name and splits it into words on
+// separators and camelCase boundaries
+func splitCapabilityName(name string) []string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+	return words
+}
@@ -0,0 +1,38 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyMCPToolRisk(t *testing.T) {
+	tests := []struct {
+		name           string
+		capabilityType domain.MCPCapabilityType
+		expected       domain.MCPToolRisk
+	}{
+		{"search_code", domain.MCPCapabilityTypeTool, domain.MCPToolRiskRead},
+		{"get_settings", domain.MCPCapabilityTypeTool, domain.MCPToolRiskRead},
+		{"writeFile", domain.MCPCapabilityTypeTool, domain.MCPToolRiskWrite},
+		{"send_email", domain.MCPCapabilityTypeTool, domain.MCPToolRiskExfiltration},
+		{"http-get", domain.MCPCapabilityTypeTool, domain.MCPToolRiskExfiltration},
+		{"DeleteRecords", domain.MCPCapabilityTypeTool, domain.MCPToolRiskDestructive},
+		{"execute_shell_command", domain.MCPCapabilityTypeTool, domain.MCPToolRiskDestructive},
+		{"export_and_delete", domain.MCPCapabilityTypeTool, domain.MCPToolRiskDestructive},
+		{"delete_log", domain.MCPCapabilityTypeResource, domain.MCPToolRiskRead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassifyMCPToolRisk(tt.name, tt.capabilityType))
+		})
+	}
+
+	override := domain.MCPToolRiskExfiltration
+	capability := &domain.MCPServerCapability{Name: "get_report", CapabilityType: domain.MCPCapabilityTypeTool, RiskOverride: &override}
+	classifyMCPCapability(capability)
+	assert.Equal(t, domain.MCPToolRiskExfiltration, capability.RiskLevel)
+	assert.True(t, capability.Sensitive)
+}
@@ -3,6 +3,7 @@ package application
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,13 +12,18 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
-	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
 )
 
+// ErrInvalidMCPToolRisk is returned when a risk override is not a known classification
+var ErrInvalidMCPToolRisk = errors.New("invalid mcp tool risk")
+
 type MCPCapabilityService struct {
-	capabilityRepo *repository.MCPServerCapabilityRepository
-	mcpRepo        *repository.MCPServerRepository
-	httpClient     *http.Client
+	capabilityRepo     domain.MCPServerCapabilityRepository
+	mcpRepo            domain.MCPServerRepository
+	deprecationService *CapabilityDeprecationService // Optional: deprecates agent capabilities of dropped tools
+	alertService       *AlertService                 // Optional: alerts when a verified server's tool list changes
+	httpClient         *http.Client
 }
 
 // MCPCapabilitiesResponse represents the standard MCP protocol capabilities response
@@ -53,15 +59,19 @@ type MCPPrompt struct {
 }
 
 func NewMCPCapabilityService(
-	capabilityRepo *repository.MCPServerCapabilityRepository,
-	mcpRepo *repository.MCPServerRepository,
+	capabilityRepo domain.MCPServerCapabilityRepository,
+	mcpRepo domain.MCPServerRepository,
+	deprecationService *CapabilityDeprecationService,
+	alertService *AlertService,
 ) *MCPCapabilityService {
 	return &MCPCapabilityService{
-		capabilityRepo: capabilityRepo,
-		mcpRepo:        mcpRepo,
-		httpClient: &http.Client{
+		capabilityRepo:     capabilityRepo,
+		mcpRepo:            mcpRepo,
+		deprecationService: deprecationService,
+		alertService:       alertService,
+		httpClient: resilience.WrapClient(&http.Client{
 			Timeout: 30 * time.Second, // 30 second timeout for capability discovery
-		},
+		}, resilience.DependencyMCPServers),
 	}
 }
 
@@ -94,33 +104,39 @@ func (s *MCPCapabilityService) DetectCapabilities(ctx context.Context, serverID
 	fmt.Printf("   Base URL: %s\n", baseURL)
 	fmt.Printf("   Capabilities URL: %s\n", capabilitiesURL)
 
-	// Step 2: Make HTTP GET request to MCP server
-	req, err := http.NewRequestWithContext(ctx, "GET", capabilitiesURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
-	}
+	// Step 2: Make HTTP GET request to MCP server, within the HTTP probe budget
+	var body []byte
+	err = domain.CallDependency(ctx, domain.DependencyHTTP, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, "GET", capabilitiesURL, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create HTTP request: %w", err)
+		}
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", "AIM/1.0 (Agent Identity Management)")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", "AIM/1.0 (Agent Identity Management)")
 
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		fmt.Printf("❌ Failed to fetch capabilities: %v\n", err)
-		return fmt.Errorf("failed to fetch capabilities from %s: %w", capabilitiesURL, err)
-	}
-	defer resp.Body.Close()
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			fmt.Printf("❌ Failed to fetch capabilities: %v\n", err)
+			return fmt.Errorf("failed to fetch capabilities from %s: %w", capabilitiesURL, err)
+		}
+		defer resp.Body.Close()
 
-	fmt.Printf("   Response Status: %d\n", resp.StatusCode)
+		fmt.Printf("   Response Status: %d\n", resp.StatusCode)
 
-	if resp.StatusCode != http.StatusOK {
-		fmt.Printf("❌ Non-200 status: %d\n", resp.StatusCode)
-		return fmt.Errorf("MCP server returned non-200 status: %d", resp.StatusCode)
-	}
+		if resp.StatusCode != http.StatusOK {
+			fmt.Printf("❌ Non-200 status: %d\n", resp.StatusCode)
+			return fmt.Errorf("MCP server returned non-200 status: %d", resp.StatusCode)
+		}
 
-	// Step 3: Parse MCP protocol response
-	body, err := io.ReadAll(resp.Body)
+		// Step 3: Parse MCP protocol response
+		if body, err = io.ReadAll(resp.Body); err != nil {
+			return fmt.Errorf("failed to read response body: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return err
 	}
 
 	var mcpResp MCPCapabilitiesResponse
@@ -128,7 +144,18 @@ func (s *MCPCapabilityService) DetectCapabilities(ctx context.Context, serverID
 		return fmt.Errorf("failed to parse MCP capabilities response: %w", err)
 	}
 
-	// Step 4: Convert MCP protocol capabilities to domain objects
+	// Step 4: Store the capabilities, deactivating the ones the server no longer exposes
+	capabilities := toMCPServerCapabilities(serverID, &mcpResp)
+	if _, err := s.reconcileCapabilities(ctx, server, capabilities); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Successfully detected %d real capabilities from MCP server %s\n", len(capabilities), server.Name)
+	return nil
+}
+
+// toMCPServerCapabilities converts MCP protocol capabilities to domain objects
+func toMCPServerCapabilities(serverID uuid.UUID, mcpResp *MCPCapabilitiesResponse) []*domain.MCPServerCapability {
 	capabilities := []*domain.MCPServerCapability{}
 
 	// Convert tools
@@ -183,29 +210,178 @@ func (s *MCPCapabilityService) DetectCapabilities(ctx context.Context, serverID
 		})
 	}
 
-	// Step 5: Store detected capabilities in database
+	return capabilities
+}
+
+// MCPToolChanges lists the tools a server started and stopped exposing since its last detection
+type MCPToolChanges struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// reconcileCapabilities stores the detected capabilities (existing ones are refreshed), deactivates
+// the ones the server dropped, deprecates agent capabilities using its dropped tools and alerts
+// when the tool list of a verified server changed
+func (s *MCPCapabilityService) reconcileCapabilities(ctx context.Context, server *domain.MCPServer, capabilities []*domain.MCPServerCapability) (*MCPToolChanges, error) {
+	// Capabilities detected before, to find the ones the server no longer exposes
+	previous, err := s.capabilityRepo.GetByServerID(server.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing capabilities: %w", err)
+	}
+	previouslyActive := make(map[string]bool, len(previous))
+	for _, cap := range previous {
+		if cap.IsActive {
+			previouslyActive[string(cap.CapabilityType)+":"+cap.Name] = true
+		}
+	}
+
+	changes := &MCPToolChanges{}
 	for _, cap := range capabilities {
 		if err := s.capabilityRepo.Create(cap); err != nil {
 			// Log error but continue with other capabilities
 			fmt.Printf("⚠️  Failed to store capability %s: %v\n", cap.Name, err)
 			continue
 		}
+		if cap.CapabilityType == domain.MCPCapabilityTypeTool && !previouslyActive[string(cap.CapabilityType)+":"+cap.Name] {
+			changes.Added = append(changes.Added, cap.Name)
+		}
 
 		fmt.Printf("✅ Detected %s capability: %s\n", cap.CapabilityType, cap.Name)
 	}
 
-	fmt.Printf("✅ Successfully detected %d real capabilities from MCP server %s\n", len(capabilities), server.Name)
-	return nil
+	detected := make(map[string]bool, len(capabilities))
+	var exposedTools []string
+	for _, cap := range capabilities {
+		detected[string(cap.CapabilityType)+":"+cap.Name] = true
+		if cap.CapabilityType == domain.MCPCapabilityTypeTool {
+			exposedTools = append(exposedTools, cap.Name)
+		}
+	}
+	var removedTools []string
+	for _, cap := range previous {
+		if detected[string(cap.CapabilityType)+":"+cap.Name] {
+			continue
+		}
+		wasActive := cap.IsActive
+		cap.IsActive = false
+		if err := s.capabilityRepo.Update(cap); err != nil {
+			fmt.Printf("⚠️  Failed to deactivate capability %s: %v\n", cap.Name, err)
+			continue
+		}
+		fmt.Printf("🗑️  %s capability no longer exposed: %s\n", cap.CapabilityType, cap.Name)
+		if cap.CapabilityType == domain.MCPCapabilityTypeTool {
+			removedTools = append(removedTools, cap.Name)
+			if wasActive {
+				changes.Removed = append(changes.Removed, cap.Name)
+			}
+		}
+	}
+	if s.deprecationService != nil {
+		if _, err := s.deprecationService.ReconcileServerTools(ctx, server, exposedTools, removedTools, domain.CapabilityDeprecationSourceDiscovery); err != nil {
+			fmt.Printf("⚠️  Failed to reconcile capability deprecations for %s: %v\n", server.Name, err)
+		}
+	}
+
+	// The first detection of a server establishes its tool list; later changes are alerted
+	if len(previous) > 0 && (len(changes.Added) > 0 || len(changes.Removed) > 0) &&
+		(server.IsVerified || server.Status == domain.MCPServerStatusVerified) {
+		s.alertToolsChanged(ctx, server, changes)
+	}
+
+	return changes, nil
+}
+
+// alertToolsChanged raises an alert for a verified MCP server whose tool list changed. New
+// tools are rated higher than removed ones: a verified server gaining tools is how a trusted
+// server turns malicious.
+func (s *MCPCapabilityService) alertToolsChanged(ctx context.Context, server *domain.MCPServer, changes *MCPToolChanges) {
+	if s.alertService == nil {
+		return
+	}
+
+	severity := domain.AlertSeverityWarning
+	if len(changes.Added) > 0 {
+		severity = domain.AlertSeverityHigh
+	}
+	var parts []string
+	if len(changes.Added) > 0 {
+		parts = append(parts, "added "+strings.Join(changes.Added, ", "))
+	}
+	if len(changes.Removed) > 0 {
+		parts = append(parts, "removed "+strings.Join(changes.Removed, ", "))
+	}
+
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: server.OrganizationID,
+		AlertType:      domain.AlertMCPToolsChanged,
+		Severity:       severity,
+		Title:          fmt.Sprintf("Tools of verified MCP server changed: %s", server.Name),
+		Description: fmt.Sprintf("Capability discovery found that %s %s. "+
+			"Review the tools before agents rely on them.", server.URL, strings.Join(parts, "; ")),
+		ResourceType: "mcp_server",
+		ResourceID:   server.ID,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := s.alertService.CreateAlert(ctx, alert); err != nil {
+		fmt.Printf("⚠️  Failed to create tool change alert for MCP server %s: %v\n", server.ID, err)
+	}
 }
 
-// GetCapabilities retrieves all capabilities for an MCP server
+// GetCapabilities retrieves all capabilities for an MCP server with their risk classification
 func (s *MCPCapabilityService) GetCapabilities(ctx context.Context, serverID uuid.UUID) ([]*domain.MCPServerCapability, error) {
-	return s.capabilityRepo.GetByServerID(serverID)
+	capabilities, err := s.capabilityRepo.GetByServerID(serverID)
+	if err != nil {
+		return nil, err
+	}
+	for _, capability := range capabilities {
+		classifyMCPCapability(capability)
+	}
+	return capabilities, nil
 }
 
-// GetCapabilitiesByType retrieves capabilities by type
+// GetCapabilitiesByType retrieves capabilities by type with their risk classification
 func (s *MCPCapabilityService) GetCapabilitiesByType(ctx context.Context, serverID uuid.UUID, capType domain.MCPCapabilityType) ([]*domain.MCPServerCapability, error) {
-	return s.capabilityRepo.GetByServerIDAndType(serverID, capType)
+	capabilities, err := s.capabilityRepo.GetByServerIDAndType(serverID, capType)
+	if err != nil {
+		return nil, err
+	}
+	for _, capability := range capabilities {
+		classifyMCPCapability(capability)
+	}
+	return capabilities, nil
+}
+
+// SetCapabilityRisk overrides the heuristic risk classification of one of the server's
+// capabilities. An empty risk clears the override.
+func (s *MCPCapabilityService) SetCapabilityRisk(
+	ctx context.Context,
+	serverID uuid.UUID,
+	capabilityID uuid.UUID,
+	risk domain.MCPToolRisk,
+) (*domain.MCPServerCapability, error) {
+	if risk != "" && !risk.IsValid() {
+		return nil, fmt.Errorf("%w: %q (expected read, write, destructive or exfiltration)", ErrInvalidMCPToolRisk, risk)
+	}
+
+	capability, err := s.capabilityRepo.GetByID(capabilityID)
+	if err != nil {
+		return nil, err
+	}
+	if capability.MCPServerID != serverID {
+		return nil, fmt.Errorf("mcp server capability not found")
+	}
+
+	capability.RiskOverride = nil
+	if risk != "" {
+		capability.RiskOverride = &risk
+	}
+	if err := s.capabilityRepo.Update(capability); err != nil {
+		return nil, err
+	}
+
+	classifyMCPCapability(capability)
+	return capability, nil
 }
 
 // ===== LEGACY SIMULATED METHODS (NO LONGER USED) =====
@@ -0,0 +1,151 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPCapabilityDiscoveryTracksToolChanges(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	tools := []string{"read_file", "search"}
+	sessions := 0
+	mcp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			assert.Equal(t, "session-1", r.Header.Get("Mcp-Session-Id"))
+			return
+		}
+		var message struct {
+			ID     *int            `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		if message.ID == nil {
+			assert.Equal(t, "notifications/initialized", message.Method)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		var result interface{}
+		switch message.Method {
+		case "initialize":
+			sessions++
+			w.Header().Set("Mcp-Session-Id", "session-1")
+			result = map[string]interface{}{
+				"protocolVersion": "2025-03-26",
+				"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}, "prompts": map[string]interface{}{}},
+				"serverInfo":      map[string]string{"name": "files", "version": "1.2.0"},
+			}
+		case "tools/list":
+			assert.Equal(t, "session-1", r.Header.Get("Mcp-Session-Id"))
+			// Tools come one page at a time
+			var params struct {
+				Cursor string `json:"cursor"`
+			}
+			require.NoError(t, json.Unmarshal(message.Params, &params))
+			index := 0
+			if params.Cursor != "" {
+				index, _ = strconv.Atoi(params.Cursor)
+			}
+			page := map[string]interface{}{
+				"tools": []map[string]interface{}{{"name": tools[index], "inputSchema": map[string]string{"type": "object"}}},
+			}
+			if index+1 < len(tools) {
+				page["nextCursor"] = strconv.Itoa(index + 1)
+			}
+			result = page
+		case "prompts/list":
+			// Streamed response, preceded by a notification
+			response, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": *message.ID, "result": map[string]interface{}{
+				"prompts": []map[string]string{{"name": "summarize"}},
+			}})
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/message\"}\n\ndata: %s\n\n", response)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": *message.ID, "result": result})
+	}))
+	defer mcp.Close()
+
+	server := testsupport.NewMCPServer(org.ID, func(s *domain.MCPServer) {
+		s.URL = mcp.URL + "/mcp"
+		s.Status = domain.MCPServerStatusVerified
+		s.IsVerified = true
+	})
+	require.NoError(t, repos.MCPServer.Create(server))
+	suspended := testsupport.NewMCPServer(org.ID, func(s *domain.MCPServer) {
+		s.URL = "http://127.0.0.1:1/mcp"
+		s.Status = domain.MCPServerStatusSuspended
+	})
+	require.NoError(t, repos.MCPServer.Create(suspended))
+
+	alerts := NewAlertService(repos.Alert, repos.Agent, nil, nil, nil, nil)
+	capabilities := NewMCPCapabilityService(repos.MCPServerCapability, repos.MCPServer, nil, alerts)
+
+	// The first discovery establishes the tool list without alerting; suspended servers are skipped
+	count, err := capabilities.DiscoverAllCapabilities(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	stored, err := capabilities.GetCapabilities(ctx, server.ID)
+	require.NoError(t, err)
+	names := map[string]bool{}
+	for _, capability := range stored {
+		names[string(capability.CapabilityType)+":"+capability.Name] = capability.IsActive
+	}
+	assert.Equal(t, map[string]bool{"tool:read_file": true, "tool:search": true, "prompt:summarize": true}, names)
+	existing, err := repos.Alert.GetByOrganization(org.ID, 100, 0)
+	require.NoError(t, err)
+	assert.Empty(t, existing)
+
+	// A changed tool list deactivates the dropped tool and alerts on the verified server
+	mu.Lock()
+	tools = []string{"read_file", "delete_file"}
+	mu.Unlock()
+	result, err := capabilities.DiscoverCapabilities(ctx, server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "handshake", result.Method)
+	assert.Equal(t, "files", result.ServerName)
+	assert.Equal(t, []string{"delete_file"}, result.Changes.Added)
+	assert.Equal(t, []string{"search"}, result.Changes.Removed)
+	assert.Equal(t, 2, sessions)
+
+	stored, err = capabilities.GetCapabilities(ctx, server.ID)
+	require.NoError(t, err)
+	for _, capability := range stored {
+		assert.Equal(t, capability.Name != "search", capability.IsActive, capability.Name)
+	}
+
+	raised, err := repos.Alert.GetByOrganization(org.ID, 100, 0)
+	require.NoError(t, err)
+	require.Len(t, raised, 1)
+	assert.Equal(t, domain.AlertMCPToolsChanged, raised[0].AlertType)
+	assert.Equal(t, domain.AlertSeverityHigh, raised[0].Severity)
+	assert.Equal(t, server.ID, raised[0].ResourceID)
+	assert.Contains(t, raised[0].Description, "added delete_file; removed search")
+
+	// An unchanged tool list raises no further alert
+	_, err = capabilities.DiscoverCapabilities(ctx, server.ID)
+	require.NoError(t, err)
+	raised, err = repos.Alert.GetByOrganization(org.ID, 100, 0)
+	require.NoError(t, err)
+	assert.Len(t, raised, 1)
+}
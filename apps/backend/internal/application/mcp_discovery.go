@@ -0,0 +1,367 @@
+package application
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// mcpProtocolVersion is the MCP protocol revision AIM requests in the initialize handshake
+	mcpProtocolVersion = "2025-03-26"
+	// mcpDiscoveryBatchSize is how many MCP servers the discovery job loads at a time
+	mcpDiscoveryBatchSize = 100
+	// mcpDiscoveryMaxPages bounds the pages read from one paginated list method
+	mcpDiscoveryMaxPages = 20
+	// mcpDiscoveryMaxResponseBytes bounds each JSON-RPC response read from an MCP server
+	mcpDiscoveryMaxResponseBytes = 4 << 20
+)
+
+// MCPDiscoveryResult is the outcome of discovering the capabilities of one MCP server
+type MCPDiscoveryResult struct {
+	ServerID        uuid.UUID       `json:"serverId"`
+	Method          string          `json:"method"` // "handshake" (MCP initialize) or "well_known" fallback
+	ProtocolVersion string          `json:"protocolVersion,omitempty"`
+	ServerName      string          `json:"serverName,omitempty"`
+	ServerVersion   string          `json:"serverVersion,omitempty"`
+	Capabilities    int             `json:"capabilities"`
+	Changes         *MCPToolChanges `json:"changes,omitempty"`
+}
+
+// DiscoverCapabilities connects to the MCP server, performs the initialize handshake and lists its
+// tools, resources and prompts over the Streamable HTTP transport (JSON-RPC 2.0). Servers that do
+// not speak the protocol at their URL fall back to /.well-known/mcp/capabilities.
+func (s *MCPCapabilityService) DiscoverCapabilities(ctx context.Context, serverID uuid.UUID) (*MCPDiscoveryResult, error) {
+	server, err := s.mcpRepo.GetByID(serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MCP server: %w", err)
+	}
+
+	result := &MCPDiscoveryResult{ServerID: server.ID, Method: "handshake"}
+	listing, handshakeErr := s.handshake(ctx, server.URL, result)
+	if handshakeErr != nil {
+		if err := s.DetectCapabilities(ctx, server.ID); err != nil {
+			return nil, fmt.Errorf("mcp handshake failed: %v; well-known capabilities: %w", handshakeErr, err)
+		}
+		capabilities, err := s.capabilityRepo.GetByServerID(server.ID)
+		if err != nil {
+			return nil, err
+		}
+		result.Method = "well_known"
+		for _, capability := range capabilities {
+			if capability.IsActive {
+				result.Capabilities++
+			}
+		}
+		return result, nil
+	}
+
+	capabilities := toMCPServerCapabilities(server.ID, listing)
+	changes, err := s.reconcileCapabilities(ctx, server, capabilities)
+	if err != nil {
+		return nil, err
+	}
+	result.Capabilities = len(capabilities)
+	result.Changes = changes
+	return result, nil
+}
+
+// DiscoverAllCapabilities discovers the capabilities of every MCP server that is not suspended or
+// revoked (background job). A server that cannot be reached does not stop the others. Returns the
+// number of servers discovered.
+func (s *MCPCapabilityService) DiscoverAllCapabilities(ctx context.Context) (int, error) {
+	discovered := 0
+	for offset := 0; ; offset += mcpDiscoveryBatchSize {
+		servers, err := s.mcpRepo.List(mcpDiscoveryBatchSize, offset)
+		if err != nil {
+			return discovered, err
+		}
+
+		for _, server := range servers {
+			if server.URL == "" || server.Status == domain.MCPServerStatusSuspended || server.Status == domain.MCPServerStatusRevoked {
+				continue
+			}
+			if _, err := s.DiscoverCapabilities(ctx, server.ID); err != nil {
+				log.Printf("⚠️  Capability discovery failed for MCP server %s (%s): %v", server.Name, server.ID, err)
+				continue
+			}
+			discovered++
+		}
+
+		if len(servers) < mcpDiscoveryBatchSize {
+			return discovered, nil
+		}
+	}
+}
+
+type jsonRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonRPCResponse struct {
+	ID     *int            `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *jsonRPCError   `json:"error"`
+}
+
+// mcpSession is one Streamable HTTP session with an MCP server
+type mcpSession struct {
+	client          *http.Client
+	url             string
+	sessionID       string
+	protocolVersion string
+	nextID          int
+}
+
+// handshake initializes a session and lists the capabilities the server advertises
+func (s *MCPCapabilityService) handshake(ctx context.Context, url string, result *MCPDiscoveryResult) (*MCPCapabilitiesResponse, error) {
+	session := &mcpSession{client: s.httpClient, url: url}
+	defer session.close(ctx)
+
+	var initialized struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		Capabilities    struct {
+			Tools     *json.RawMessage `json:"tools"`
+			Resources *json.RawMessage `json:"resources"`
+			Prompts   *json.RawMessage `json:"prompts"`
+		} `json:"capabilities"`
+		ServerInfo struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+	}
+	err := session.call(ctx, "initialize", map[string]interface{}{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]string{"name": "AIM", "version": "1.0"},
+	}, &initialized)
+	if err != nil {
+		return nil, err
+	}
+	session.protocolVersion = initialized.ProtocolVersion
+	result.ProtocolVersion = initialized.ProtocolVersion
+	result.ServerName = initialized.ServerInfo.Name
+	result.ServerVersion = initialized.ServerInfo.Version
+
+	if err := session.notify(ctx, "notifications/initialized"); err != nil {
+		return nil, err
+	}
+
+	listing := &MCPCapabilitiesResponse{}
+	if initialized.Capabilities.Tools != nil {
+		err := session.list(ctx, "tools/list", func(page json.RawMessage) (string, error) {
+			var tools struct {
+				Tools      []MCPTool `json:"tools"`
+				NextCursor string    `json:"nextCursor"`
+			}
+			err := json.Unmarshal(page, &tools)
+			listing.Tools = append(listing.Tools, tools.Tools...)
+			return tools.NextCursor, err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if initialized.Capabilities.Resources != nil {
+		err := session.list(ctx, "resources/list", func(page json.RawMessage) (string, error) {
+			var resources struct {
+				Resources []struct {
+					Name        string `json:"name"`
+					URI         string `json:"uri"`
+					Description string `json:"description"`
+					MimeType    string `json:"mimeType"`
+				} `json:"resources"`
+				NextCursor string `json:"nextCursor"`
+			}
+			err := json.Unmarshal(page, &resources)
+			for _, resource := range resources.Resources {
+				converted := MCPResource{Name: resource.Name, URI: resource.URI, Description: resource.Description}
+				if resource.MimeType != "" {
+					converted.MimeTypes = []string{resource.MimeType}
+				}
+				listing.Resources = append(listing.Resources, converted)
+			}
+			return resources.NextCursor, err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if initialized.Capabilities.Prompts != nil {
+		err := session.list(ctx, "prompts/list", func(page json.RawMessage) (string, error) {
+			var prompts struct {
+				Prompts    []MCPPrompt `json:"prompts"`
+				NextCursor string      `json:"nextCursor"`
+			}
+			err := json.Unmarshal(page, &prompts)
+			listing.Prompts = append(listing.Prompts, prompts.Prompts...)
+			return prompts.NextCursor, err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return listing, nil
+}
+
+// list calls a paginated list method until the server returns no cursor
+func (m *mcpSession) list(ctx context.Context, method string, page func(json.RawMessage) (string, error)) error {
+	cursor := ""
+	for i := 0; i < mcpDiscoveryMaxPages; i++ {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var raw json.RawMessage
+		if err := m.call(ctx, method, params, &raw); err != nil {
+			return err
+		}
+		next, err := page(raw)
+		if err != nil {
+			return fmt.Errorf("invalid %s result: %w", method, err)
+		}
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+	return fmt.Errorf("%s returned more than %d pages", method, mcpDiscoveryMaxPages)
+}
+
+// call sends a JSON-RPC request and decodes its result
+func (m *mcpSession) call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	m.nextID++
+	id := m.nextID
+	resp, err := m.post(ctx, map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: mcp server returned status %d", method, resp.StatusCode)
+	}
+	if sessionID := resp.Header.Get("Mcp-Session-Id"); sessionID != "" {
+		m.sessionID = sessionID
+	}
+
+	response, err := readJSONRPCResponse(resp, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("%s: mcp error %d: %s", method, response.Error.Code, response.Error.Message)
+	}
+	if err := json.Unmarshal(response.Result, out); err != nil {
+		return fmt.Errorf("invalid %s result: %w", method, err)
+	}
+	return nil
+}
+
+// notify sends a JSON-RPC notification, which has no response
+func (m *mcpSession) notify(ctx context.Context, method string) error {
+	resp, err := m.post(ctx, map[string]interface{}{"jsonrpc": "2.0", "method": method})
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: mcp server returned status %d", method, resp.StatusCode)
+	}
+	return nil
+}
+
+func (m *mcpSession) post(ctx context.Context, message interface{}) (*http.Response, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	m.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	return m.client.Do(req)
+}
+
+// close ends the session on servers that issued a session ID
+func (m *mcpSession) close(ctx context.Context) {
+	if m.sessionID == "" {
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, m.url, nil)
+	if err != nil {
+		return
+	}
+	m.setHeaders(req)
+	if resp, err := m.client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
+
+func (m *mcpSession) setHeaders(req *http.Request) {
+	req.Header.Set("User-Agent", "AIM/1.0 (Agent Identity Management)")
+	if m.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", m.sessionID)
+	}
+	if m.protocolVersion != "" {
+		req.Header.Set("MCP-Protocol-Version", m.protocolVersion)
+	}
+}
+
+// readJSONRPCResponse reads the response to request id from a JSON body or an SSE stream
+func readJSONRPCResponse(resp *http.Response, id int) (*jsonRPCResponse, error) {
+	body := io.LimitReader(resp.Body, mcpDiscoveryMaxResponseBytes)
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		var response jsonRPCResponse
+		if err := json.NewDecoder(body).Decode(&response); err != nil {
+			return nil, fmt.Errorf("invalid json-rpc response: %w", err)
+		}
+		return &response, nil
+	}
+
+	// Streamed responses may carry server requests and notifications before the response
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), mcpDiscoveryMaxResponseBytes)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data.WriteString(strings.TrimPrefix(value, " "))
+			continue
+		}
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+		var response jsonRPCResponse
+		if err := json.Unmarshal([]byte(data.String()), &response); err == nil && response.ID != nil && *response.ID == id {
+			return &response, nil
+		}
+		data.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if data.Len() > 0 {
+		var response jsonRPCResponse
+		if err := json.Unmarshal([]byte(data.String()), &response); err == nil && response.ID != nil && *response.ID == id {
+			return &response, nil
+		}
+	}
+	return nil, errors.New("event stream ended without a response")
+}
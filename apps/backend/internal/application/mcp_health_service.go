@@ -0,0 +1,332 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// DefaultMCPHealthFailureThreshold is how many consecutive failed checks suspend an MCP server
+	DefaultMCPHealthFailureThreshold = 3
+
+	// mcpHealthCheckBatchSize is how many MCP servers the health check job loads at a time
+	mcpHealthCheckBatchSize = 100
+	// mcpHealthCheckTimeout bounds one probe of an MCP server
+	mcpHealthCheckTimeout = 10 * time.Second
+	// mcpHealthRecentChecks is how many checks the health endpoint returns
+	mcpHealthRecentChecks = 50
+)
+
+// ErrMCPHealthServerNotFound is returned when the MCP server does not exist in the organization
+var ErrMCPHealthServerNotFound = errors.New("mcp server not found")
+
+// MCPServerHealthReport is the health of an MCP server with its most recent checks
+type MCPServerHealthReport struct {
+	*domain.MCPServerHealth
+	Status       domain.MCPServerStatus   `json:"status"`
+	RecentChecks []*domain.MCPHealthCheck `json:"recentChecks"`
+}
+
+// MCPHealthCheckRunResult is the outcome of a health check run over every MCP server
+type MCPHealthCheckRunResult struct {
+	Checked    int `json:"checked"`
+	Unhealthy  int `json:"unhealthy"`
+	Downgraded int `json:"downgraded"`
+	Restored   int `json:"restored"`
+	Pruned     int `json:"pruned"`
+}
+
+// MCPHealthService probes the URL of every MCP server on a schedule and keeps the results as
+// uptime history. A server that fails FailureThreshold checks in a row is suspended and an alert
+// is raised; once a check passes again it gets back the status it had before. The uptime
+// percentages feed the Uptime factor of the trust score of agents that talk to the server.
+type MCPHealthService struct {
+	healthRepo       domain.MCPHealthRepository
+	mcpRepo          domain.MCPServerRepository
+	alertRepo        domain.AlertRepository // Optional: alerts when a server is suspended
+	failureThreshold int
+	// Probes do not go through the resilience circuit breaker: an open breaker would skip the
+	// probes that detect the server is back
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewMCPHealthService creates a new MCP health service; a non-positive failure threshold uses
+// DefaultMCPHealthFailureThreshold
+func NewMCPHealthService(
+	healthRepo domain.MCPHealthRepository,
+	mcpRepo domain.MCPServerRepository,
+	alertRepo domain.AlertRepository,
+	failureThreshold int,
+) *MCPHealthService {
+	if failureThreshold <= 0 {
+		failureThreshold = DefaultMCPHealthFailureThreshold
+	}
+	return &MCPHealthService{
+		healthRepo:       healthRepo,
+		mcpRepo:          mcpRepo,
+		alertRepo:        alertRepo,
+		failureThreshold: failureThreshold,
+		httpClient:       &http.Client{Timeout: mcpHealthCheckTimeout},
+		now:              time.Now,
+	}
+}
+
+// GetHealth returns the health state, uptime and recent checks of an MCP server of the organization
+func (s *MCPHealthService) GetHealth(ctx context.Context, orgID, mcpServerID uuid.UUID) (*MCPServerHealthReport, error) {
+	server, err := s.mcpRepo.GetByID(mcpServerID)
+	if err != nil || server.OrganizationID != orgID {
+		return nil, ErrMCPHealthServerNotFound
+	}
+	return s.report(ctx, server)
+}
+
+// CheckNow probes an MCP server of the organization immediately
+func (s *MCPHealthService) CheckNow(ctx context.Context, orgID, mcpServerID uuid.UUID) (*MCPServerHealthReport, error) {
+	server, err := s.mcpRepo.GetByID(mcpServerID)
+	if err != nil || server.OrganizationID != orgID {
+		return nil, ErrMCPHealthServerNotFound
+	}
+	if _, err := s.checkServer(ctx, server); err != nil {
+		return nil, err
+	}
+	return s.report(ctx, server)
+}
+
+// CheckAllServers probes every MCP server with a URL that is not revoked (background job), then
+// prunes checks older than the retention period. A server that cannot be checked does not stop
+// the others.
+func (s *MCPHealthService) CheckAllServers(ctx context.Context) (*MCPHealthCheckRunResult, error) {
+	result := &MCPHealthCheckRunResult{}
+	for offset := 0; ; offset += mcpHealthCheckBatchSize {
+		servers, err := s.mcpRepo.List(mcpHealthCheckBatchSize, offset)
+		if err != nil {
+			return result, fmt.Errorf("failed to list mcp servers: %w", err)
+		}
+
+		for _, server := range servers {
+			if server.URL == "" || server.Status == domain.MCPServerStatusRevoked {
+				continue
+			}
+			transition, err := s.checkServer(ctx, server)
+			if err != nil {
+				log.Printf("⚠️  Health check failed for MCP server %s (%s): %v", server.Name, server.ID, err)
+				continue
+			}
+			result.Checked++
+			if transition.health.State == domain.MCPHealthStateUnhealthy {
+				result.Unhealthy++
+			}
+			if transition.downgraded {
+				result.Downgraded++
+			}
+			if transition.restored {
+				result.Restored++
+			}
+		}
+
+		if len(servers) < mcpHealthCheckBatchSize {
+			break
+		}
+	}
+
+	pruned, err := s.healthRepo.DeleteChecksBefore(s.now().UTC().Add(-domain.MCPHealthCheckRetention))
+	if err != nil {
+		log.Printf("⚠️  Failed to prune MCP health checks: %v", err)
+	}
+	result.Pruned = pruned
+
+	if result.Downgraded > 0 || result.Restored > 0 {
+		fmt.Printf("⚠️  MCP health checks: %d servers suspended, %d restored\n", result.Downgraded, result.Restored)
+	}
+	return result, nil
+}
+
+// GetUptimes returns the uptime of each MCP server over the last 24 hours, 7 days and 30 days
+func (s *MCPHealthService) GetUptimes(ctx context.Context, mcpServerIDs []uuid.UUID) (map[uuid.UUID]*domain.MCPServerUptime, error) {
+	now := s.now().UTC()
+	day, err := s.healthRepo.CountChecks(mcpServerIDs, now.Add(-24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count mcp health checks: %w", err)
+	}
+	week, err := s.healthRepo.CountChecks(mcpServerIDs, now.AddDate(0, 0, -7))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count mcp health checks: %w", err)
+	}
+	month, err := s.healthRepo.CountChecks(mcpServerIDs, now.Add(-domain.MCPHealthCheckRetention))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count mcp health checks: %w", err)
+	}
+
+	uptimes := make(map[uuid.UUID]*domain.MCPServerUptime, len(mcpServerIDs))
+	for _, id := range mcpServerIDs {
+		uptimes[id] = &domain.MCPServerUptime{
+			Last24h: uptimePercent(day[id]),
+			Last7d:  uptimePercent(week[id]),
+			Last30d: uptimePercent(month[id]),
+		}
+	}
+	return uptimes, nil
+}
+
+// uptimePercent is the percentage of passed checks, nil without checks
+func uptimePercent(count domain.MCPHealthCheckCount) *float64 {
+	if count.Total == 0 {
+		return nil
+	}
+	uptime := float64(count.Passed) / float64(count.Total) * 100
+	return &uptime
+}
+
+// report assembles the health report of an MCP server
+func (s *MCPHealthService) report(ctx context.Context, server *domain.MCPServer) (*MCPServerHealthReport, error) {
+	health, err := s.healthRepo.GetState(server.ID)
+	if errors.Is(err, domain.ErrMCPServerHealthNotFound) {
+		health = &domain.MCPServerHealth{MCPServerID: server.ID, State: domain.MCPHealthStateUnknown}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get mcp server health: %w", err)
+	}
+
+	uptimes, err := s.GetUptimes(ctx, []uuid.UUID{server.ID})
+	if err != nil {
+		return nil, err
+	}
+	health.Uptime = *uptimes[server.ID]
+
+	checks, err := s.healthRepo.ListChecks(server.ID, mcpHealthRecentChecks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mcp health checks: %w", err)
+	}
+	return &MCPServerHealthReport{MCPServerHealth: health, Status: server.Status, RecentChecks: checks}, nil
+}
+
+// mcpHealthTransition is the health of a server after a check and the status change it caused
+type mcpHealthTransition struct {
+	health     *domain.MCPServerHealth
+	downgraded bool
+	restored   bool
+}
+
+// checkServer probes the server, records the check and applies the resulting status change.
+// server is updated in place when its status changes.
+func (s *MCPHealthService) checkServer(ctx context.Context, server *domain.MCPServer) (*mcpHealthTransition, error) {
+	check := s.probe(ctx, server)
+	if err := s.healthRepo.RecordCheck(check); err != nil {
+		return nil, fmt.Errorf("failed to record mcp health check: %w", err)
+	}
+
+	health, err := s.healthRepo.GetState(server.ID)
+	if errors.Is(err, domain.ErrMCPServerHealthNotFound) {
+		health = &domain.MCPServerHealth{MCPServerID: server.ID}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get mcp server health: %w", err)
+	}
+
+	transition := &mcpHealthTransition{health: health}
+	checkedAt := check.CheckedAt
+	health.LastCheckedAt = &checkedAt
+	health.UpdatedAt = checkedAt
+
+	if check.Healthy {
+		health.State = domain.MCPHealthStateHealthy
+		health.ConsecutiveFailures = 0
+		health.LastHealthyAt = &checkedAt
+		health.LastError = ""
+		if health.DowngradedAt != nil {
+			// Only undo our own suspension; an operator may have changed the status since
+			if server.Status == domain.MCPServerStatusSuspended && health.DowngradedFrom != "" {
+				server.Status = health.DowngradedFrom
+				if err := s.mcpRepo.Update(server); err != nil {
+					return nil, fmt.Errorf("failed to restore mcp server status: %w", err)
+				}
+				transition.restored = true
+			}
+			health.DowngradedAt = nil
+			health.DowngradedFrom = ""
+		}
+	} else {
+		health.State = domain.MCPHealthStateUnhealthy
+		health.ConsecutiveFailures++
+		health.LastError = check.Error
+		if health.ConsecutiveFailures >= s.failureThreshold && health.DowngradedAt == nil &&
+			(server.Status == domain.MCPServerStatusVerified || server.Status == domain.MCPServerStatusPending) {
+			previous := server.Status
+			server.Status = domain.MCPServerStatusSuspended
+			if err := s.mcpRepo.Update(server); err != nil {
+				return nil, fmt.Errorf("failed to suspend mcp server: %w", err)
+			}
+			health.DowngradedAt = &checkedAt
+			health.DowngradedFrom = previous
+			transition.downgraded = true
+			s.raiseUnhealthyAlert(server, health)
+		}
+	}
+
+	if err := s.healthRepo.UpsertState(health); err != nil {
+		return nil, fmt.Errorf("failed to save mcp server health: %w", err)
+	}
+	return transition, nil
+}
+
+// probe sends a GET to the server's URL; any response below 500 means the server is up
+func (s *MCPHealthService) probe(ctx context.Context, server *domain.MCPServer) *domain.MCPHealthCheck {
+	check := &domain.MCPHealthCheck{ID: uuid.New(), MCPServerID: server.ID}
+	start := s.now()
+	err := domain.CallDependency(ctx, domain.DependencyHTTP, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if err != nil {
+			return fmt.Errorf("invalid server URL: %w", err)
+		}
+		req.Header.Set("User-Agent", "AIM/1.0 (Agent Identity Management)")
+
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		check.StatusCode = resp.StatusCode
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("server returned status %d", resp.StatusCode)
+		}
+		return nil
+	})
+	check.CheckedAt = s.now().UTC()
+	check.LatencyMs = float64(check.CheckedAt.Sub(start.UTC()).Microseconds()) / 1000
+	check.Healthy = err == nil
+	if err != nil {
+		check.Error = err.Error()
+	}
+	return check
+}
+
+// raiseUnhealthyAlert tells the organization an MCP server was suspended for failing its health checks
+func (s *MCPHealthService) raiseUnhealthyAlert(server *domain.MCPServer, health *domain.MCPServerHealth) {
+	if s.alertRepo == nil {
+		return
+	}
+
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: server.OrganizationID,
+		AlertType:      domain.AlertMCPServerUnhealthy,
+		Severity:       domain.AlertSeverityHigh,
+		Title:          fmt.Sprintf("MCP server unhealthy: %s", server.Name),
+		Description: fmt.Sprintf("MCP server '%s' (%s) failed %d health checks in a row (last error: %s) and was suspended. "+
+			"It returns to %s once a health check passes.",
+			server.Name, server.URL, health.ConsecutiveFailures, health.LastError, health.DowngradedFrom),
+		ResourceType: "mcp_server",
+		ResourceID:   server.ID,
+		CreatedAt:    s.now().UTC(),
+	}
+	if err := s.alertRepo.Create(alert); err != nil {
+		log.Printf("⚠️  Failed to create health alert for MCP server %s: %v", server.ID, err)
+	}
+}
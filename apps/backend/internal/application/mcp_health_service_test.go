@@ -0,0 +1,86 @@
+package application
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPServersFailingHealthChecksAreSuspendedUntilTheyRecover(t *testing.T) {
+	var failing atomic.Bool
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed) // A running server that only speaks POST is up
+	}))
+	defer upstream.Close()
+
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	server := testsupport.NewMCPServer(org.ID, func(s *domain.MCPServer) { s.URL = upstream.URL })
+	require.NoError(t, repos.MCPServer.Create(server))
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.TalksTo = []string{server.Name} })
+	require.NoError(t, repos.Agent.Create(agent))
+	ctx := context.Background()
+
+	health := NewMCPHealthService(repos.MCPHealth, repos.MCPServer, repos.Alert, 2)
+	report, err := health.GetHealth(ctx, org.ID, server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MCPHealthStateUnknown, report.State)
+	assert.Nil(t, report.Uptime.Last24h, "no uptime before the first check")
+	_, err = health.GetHealth(ctx, uuid.New(), server.ID)
+	assert.ErrorIs(t, err, ErrMCPHealthServerNotFound, "servers of other organizations are hidden")
+
+	result, err := health.CheckAllServers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Checked)
+
+	// One failure is tolerated; the threshold of consecutive failures suspends the server
+	failing.Store(true)
+	report, err = health.CheckNow(ctx, org.ID, server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MCPHealthStateUnhealthy, report.State)
+	assert.Equal(t, domain.MCPServerStatusVerified, report.Status)
+	result, err = health.CheckAllServers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Downgraded)
+
+	stored, err := repos.MCPServer.GetByID(server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MCPServerStatusSuspended, stored.Status)
+	alerts, err := repos.Alert.GetByResourceID(server.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domain.AlertMCPServerUnhealthy, alerts[0].AlertType)
+
+	// A passing check gives the server back the status it had before
+	failing.Store(false)
+	report, err = health.CheckNow(ctx, org.ID, server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MCPHealthStateHealthy, report.State)
+	assert.Equal(t, domain.MCPServerStatusVerified, report.Status)
+	assert.Nil(t, report.DowngradedAt)
+	assert.Equal(t, 0, report.ConsecutiveFailures)
+	require.Len(t, report.RecentChecks, 4)
+	assert.Equal(t, http.StatusMethodNotAllowed, report.RecentChecks[0].StatusCode)
+	require.NotNil(t, report.Uptime.Last24h)
+	assert.InDelta(t, 50, *report.Uptime.Last24h, 0.01)
+
+	// The uptime of the servers an agent talks to is its Uptime factor
+	trust := NewTrustCalculator(repos.TrustScore, nil, nil, repos.Capability, repos.Agent, repos.Alert)
+	trust.UseMCPHealth(repos.MCPServer, repos.MCPHealth)
+	factors, err := trust.CalculateFactors(agent)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, factors.Uptime, 0.01)
+}
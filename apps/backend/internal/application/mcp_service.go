@@ -15,6 +15,7 @@ import (
 	"github.com/opena2a/identity/backend/internal/domain"
 	infracrypto "github.com/opena2a/identity/backend/internal/infrastructure/crypto"
 	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
 )
 
 type MCPService struct {
@@ -27,7 +28,9 @@ type MCPService struct {
 	capabilityRepo        *repository.MCPServerCapabilityRepository // ✅ For creating SDK capabilities
 	connectionRepo        *repository.AgentMCPConnectionRepository  // ✅ For tracking agent-MCP connections
 	httpClient            *http.Client           // ✅ For real MCP server communication
-	agentRepo             *repository.AgentRepository // ✅ For querying connected agents
+	agentRepo             domain.AgentRepository // ✅ For querying connected agents
+	attestationRepo       domain.MCPAttestationRepository // ✅ For private network relay checks
+	namingPolicies        *NamingPolicyService                 // ✅ For the organization's naming policies
 	// In-memory challenge storage (in production, use Redis)
 	challenges map[string]ChallengeData
 }
@@ -40,7 +43,7 @@ type ChallengeData struct {
 	ExpiresAt time.Time
 }
 
-func NewMCPService(mcpRepo *repository.MCPServerRepository, verificationEventRepo domain.VerificationEventRepository, userRepo *repository.UserRepository, keyVault *crypto.KeyVault, capabilityService *MCPCapabilityService, capabilityRepo *repository.MCPServerCapabilityRepository, connectionRepo *repository.AgentMCPConnectionRepository, agentRepo *repository.AgentRepository) *MCPService {
+func NewMCPService(mcpRepo *repository.MCPServerRepository, verificationEventRepo domain.VerificationEventRepository, userRepo *repository.UserRepository, keyVault *crypto.KeyVault, capabilityService *MCPCapabilityService, capabilityRepo *repository.MCPServerCapabilityRepository, connectionRepo *repository.AgentMCPConnectionRepository, agentRepo domain.AgentRepository, attestationRepo domain.MCPAttestationRepository, namingPolicies *NamingPolicyService) *MCPService {
 	return &MCPService{
 		mcpRepo:               mcpRepo,
 		verificationEventRepo: verificationEventRepo,
@@ -50,11 +53,13 @@ func NewMCPService(mcpRepo *repository.MCPServerRepository, verificationEventRep
 		capabilityService:     capabilityService,
 		capabilityRepo:        capabilityRepo,
 		connectionRepo:        connectionRepo,
-		httpClient: &http.Client{
+		httpClient: resilience.WrapClient(&http.Client{
 			Timeout: 30 * time.Second, // 30 second timeout for MCP server communication
-		},
-		challenges: make(map[string]ChallengeData),
-		agentRepo:  agentRepo,
+		}, resilience.DependencyMCPServers),
+		challenges:      make(map[string]ChallengeData),
+		agentRepo:       agentRepo,
+		attestationRepo: attestationRepo,
+		namingPolicies:  namingPolicies,
 	}
 }
 
@@ -86,13 +91,71 @@ type AddPublicKeyRequest struct {
 	KeyType   string `json:"key_type" validate:"required"` // e.g., "rsa", "ed25519"
 }
 
+// validateMCPServerRegistration runs every check an MCP server registration must pass:
+// required fields, naming policies, URL format and uniqueness, key format and capability names
+func (s *MCPService) validateMCPServerRegistration(ctx context.Context, req *CreateMCPServerRequest, orgID uuid.UUID) (*RegistrationValidation, error) {
+	validation := newRegistrationValidation()
+
+	validateRegistrationName(validation, "name", req.Name)
+	if err := validateRegistrationNamingPolicies(ctx, validation, s.namingPolicies, orgID, domain.NamingResourceMCPServer, req.Name); err != nil {
+		return nil, err
+	}
+
+	serverURL := strings.TrimSpace(req.URL)
+	validateRegistrationURL(validation, "url", serverURL)
+	if serverURL != "" {
+		// Check if MCP server with this URL already exists
+		if existing, _ := s.mcpRepo.GetByURL(serverURL); existing != nil {
+			validation.addError("url", RegistrationIssueURLTaken, "mcp server with this URL already exists")
+		}
+	}
+
+	if req.PublicKey != "" {
+		// MCP servers sign with Ed25519 keys; the other algorithms are for agent keys
+		validateRegistrationPublicKey(validation, domain.KeyAlgorithmEd25519, req.PublicKey)
+	}
+
+	validateRegistrationCapabilities(validation, req.Capabilities, false)
+
+	return validation, nil
+}
+
+// ValidateMCPServerRegistration runs every registration check without persisting anything and
+// returns the MCP server that CreateMCPServer would create. Used by validate=true pre-flight requests.
+func (s *MCPService) ValidateMCPServerRegistration(ctx context.Context, req *CreateMCPServerRequest, orgID, userID uuid.UUID) (*domain.MCPServer, *RegistrationValidation, error) {
+	validation, err := s.validateMCPServerRegistration(ctx, req, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	server := &domain.MCPServer{
+		OrganizationID:  orgID,
+		Name:            strings.TrimSpace(req.Name),
+		Description:     req.Description,
+		URL:             strings.TrimSpace(req.URL),
+		Version:         req.Version,
+		PublicKey:       req.PublicKey,
+		Status:          domain.MCPServerStatusPending,
+		IsVerified:      false,
+		VerificationURL: strings.TrimSpace(req.VerificationURL),
+		Capabilities:    req.Capabilities,
+		TrustScore:      0.0,
+		CreatedBy:       userID,
+	}
+
+	return server, validation, nil
+}
+
 // CreateMCPServer creates a new MCP server
 // agentID is optional - if provided (SDK registration), creates agent-MCP connection automatically
 func (s *MCPService) CreateMCPServer(ctx context.Context, req *CreateMCPServerRequest, orgID, userID uuid.UUID, agentID *uuid.UUID) (*domain.MCPServer, error) {
-	// Check if MCP server with this URL already exists
-	existing, _ := s.mcpRepo.GetByURL(req.URL)
-	if existing != nil {
-		return nil, fmt.Errorf("mcp server with this URL already exists")
+	// Validate inputs (the same checks a validate=true dry run reports)
+	validation, err := s.validateMCPServerRegistration(ctx, req, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !validation.Valid {
+		return nil, &RegistrationValidationError{Validation: validation}
 	}
 
 	// ✅ AUTOMATIC KEY GENERATION - Zero effort for developers
@@ -216,6 +279,28 @@ func (s *MCPService) GetMCPServer(ctx context.Context, id uuid.UUID) (*domain.MC
 	return s.mcpRepo.GetByID(id)
 }
 
+// GetMCPServersByIDs returns the organization's MCP servers with the given IDs in one query.
+// IDs that do not exist or belong to another organization are skipped.
+func (s *MCPService) GetMCPServersByIDs(ctx context.Context, orgID uuid.UUID, ids []uuid.UUID) ([]*domain.MCPServer, error) {
+	ids, err := uniqueBatchIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	servers, err := s.mcpRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mcp servers: %w", err)
+	}
+
+	result := make([]*domain.MCPServer, 0, len(servers))
+	for _, server := range servers {
+		if server.OrganizationID == orgID {
+			result = append(result, server)
+		}
+	}
+	return result, nil
+}
+
 // ListMCPServers lists all MCP servers for an organization
 func (s *MCPService) ListMCPServers(ctx context.Context, orgID uuid.UUID) ([]*domain.MCPServer, error) {
 	return s.mcpRepo.GetByOrganization(orgID)
@@ -229,8 +314,13 @@ func (s *MCPService) UpdateMCPServer(ctx context.Context, id uuid.UUID, req *Upd
 	}
 
 	// Update fields
-	if req.Name != "" {
-		server.Name = strings.TrimSpace(req.Name)
+	if name := strings.TrimSpace(req.Name); name != "" {
+		if name != server.Name && s.namingPolicies != nil {
+			if err := s.namingPolicies.EnforceName(ctx, server.OrganizationID, domain.NamingResourceMCPServer, server.ID, name, nil); err != nil {
+				return nil, err
+			}
+		}
+		server.Name = name
 	}
 	if req.Description != "" {
 		server.Description = req.Description
@@ -272,6 +362,18 @@ func (s *MCPService) VerifyMCPServer(ctx context.Context, id uuid.UUID, userID u
 		return err
 	}
 
+	// Private network MCPs are only reachable by the agents relaying for them;
+	// they are verified through agent attestations, so don't attempt (and fail) a direct check
+	if s.attestationRepo != nil {
+		private, err := s.attestationRepo.HasActiveRelays(id)
+		if err != nil {
+			return err
+		}
+		if private {
+			return ErrPrivateNetworkMCP
+		}
+	}
+
 	// Fetch user information for audit trail
 	var initiatorName *string
 	if s.userRepo != nil {
@@ -315,27 +417,34 @@ func (s *MCPService) VerifyMCPServer(ctx context.Context, id uuid.UUID, userID u
 		return fmt.Errorf("failed to marshal challenge request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", verificationURL, bytes.NewBuffer(reqBody))
-	if err != nil {
-		return fmt.Errorf("failed to create verification request: %w", err)
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "application/json")
+	// The MCP server must answer within the HTTP probe budget
+	var respBody []byte
+	err = domain.CallDependency(ctx, domain.DependencyHTTP, func(ctx context.Context) error {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", verificationURL, bytes.NewBuffer(reqBody))
+		if err != nil {
+			return fmt.Errorf("failed to create verification request: %w", err)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Accept", "application/json")
 
-	resp, err := s.httpClient.Do(httpReq)
-	if err != nil {
-		return fmt.Errorf("failed to contact MCP server verification endpoint: %w", err)
-	}
-	defer resp.Body.Close()
+		resp, err := s.httpClient.Do(httpReq)
+		if err != nil {
+			return fmt.Errorf("failed to contact MCP server verification endpoint: %w", err)
+		}
+		defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("MCP server verification endpoint returned non-200 status: %d", resp.StatusCode)
-	}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("MCP server verification endpoint returned non-200 status: %d", resp.StatusCode)
+		}
 
-	// Step 3b: Parse signed challenge response
-	respBody, err := io.ReadAll(resp.Body)
+		// Step 3b: Parse signed challenge response
+		if respBody, err = io.ReadAll(resp.Body); err != nil {
+			return fmt.Errorf("failed to read verification response: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to read verification response: %w", err)
+		return err
 	}
 
 	var verifyResp struct {
@@ -380,7 +489,7 @@ func (s *MCPService) VerifyMCPServer(ctx context.Context, id uuid.UUID, userID u
 			go func() {
 				// Run asynchronously to avoid blocking verification
 				bgCtx := context.Background()
-				if err := s.capabilityService.DetectCapabilities(bgCtx, id); err != nil {
+				if _, err := s.capabilityService.DiscoverCapabilities(bgCtx, id); err != nil {
 					fmt.Printf("⚠️  Failed to detect capabilities for MCP server %s: %v\n", server.Name, err)
 				}
 			}()
@@ -424,6 +533,8 @@ func (s *MCPService) VerifyMCPServer(ctx context.Context, id uuid.UUID, userID u
 		CompletedAt:      &completedAt,
 		CreatedAt:        time.Now(),
 	}
+	event.RecordCaller(ctx)
+	event.RiskLevel = ClassifyVerificationRisk(event, VerificationRiskContext{})
 
 	// Store the verification event
 	if s.verificationEventRepo != nil {
@@ -590,6 +701,8 @@ func (s *MCPService) VerifyMCPAction(
 		Metadata:         metadata,
 		CreatedAt:        now,
 	}
+	verificationEvent.RecordCaller(ctx)
+	verificationEvent.RiskLevel = ClassifyVerificationRisk(verificationEvent, VerificationRiskContext{})
 
 	// Non-blocking - don't fail the action if audit fails
 	go func() {
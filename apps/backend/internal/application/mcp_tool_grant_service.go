@@ -0,0 +1,109 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrToolGrantAgentNotFound is returned when the agent of a tool grant is not in the organization
+	ErrToolGrantAgentNotFound = errors.New("agent not found")
+	// ErrInvalidMCPToolGrant is returned for tool grants on servers the agent doesn't talk to or with malformed tools
+	ErrInvalidMCPToolGrant = errors.New("invalid MCP tool grant")
+)
+
+// SetMCPToolGrantRequest lists the tools an agent may call on an MCP server; an empty list allows none
+type SetMCPToolGrantRequest struct {
+	Tools []string `json:"tools"`
+}
+
+// MCPToolGrantService manages the tool grants narrowing an agent's talks_to MCP servers to
+// individual tools. Drift detection flags tool calls outside the granted set.
+type MCPToolGrantService struct {
+	grantRepo domain.MCPToolGrantRepository
+	agentRepo domain.AgentRepository
+	now       func() time.Time
+}
+
+// NewMCPToolGrantService creates a new MCP tool grant service
+func NewMCPToolGrantService(grantRepo domain.MCPToolGrantRepository, agentRepo domain.AgentRepository) *MCPToolGrantService {
+	return &MCPToolGrantService{
+		grantRepo: grantRepo,
+		agentRepo: agentRepo,
+		now:       time.Now,
+	}
+}
+
+// ListGrants returns the agent's tool grants
+func (s *MCPToolGrantService) ListGrants(ctx context.Context, orgID, agentID uuid.UUID) ([]*domain.MCPToolGrant, error) {
+	if _, err := s.getAgent(orgID, agentID); err != nil {
+		return nil, err
+	}
+	grants, err := s.grantRepo.ListByAgent(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MCP tool grants: %w", err)
+	}
+	return grants, nil
+}
+
+// SetGrant limits the agent to the tools on an MCP server of its talks_to list, replacing the
+// tools of an existing grant
+func (s *MCPToolGrantService) SetGrant(ctx context.Context, orgID, agentID, userID uuid.UUID, mcpServer string, req *SetMCPToolGrantRequest) (*domain.MCPToolGrant, error) {
+	agent, err := s.getAgent(orgID, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(agent.TalksTo, mcpServer) {
+		return nil, fmt.Errorf("%w: agent doesn't talk to MCP server '%s'", ErrInvalidMCPToolGrant, mcpServer)
+	}
+	tools := normalizePeerActions(req.Tools)
+	for _, tool := range tools {
+		if strings.Contains(tool, ":") {
+			return nil, fmt.Errorf("%w: tool names can't contain ':'", ErrInvalidMCPToolGrant)
+		}
+	}
+
+	now := s.now().UTC()
+	grant := &domain.MCPToolGrant{
+		OrganizationID: orgID,
+		AgentID:        agentID,
+		MCPServer:      mcpServer,
+		Tools:          tools,
+		CreatedBy:      userID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.grantRepo.Upsert(grant); err != nil {
+		return nil, fmt.Errorf("failed to store MCP tool grant: %w", err)
+	}
+	return grant, nil
+}
+
+// DeleteGrant removes the agent's grant for an MCP server, allowing every tool of it again
+func (s *MCPToolGrantService) DeleteGrant(ctx context.Context, orgID, agentID uuid.UUID, mcpServer string) error {
+	if _, err := s.getAgent(orgID, agentID); err != nil {
+		return err
+	}
+	if err := s.grantRepo.Delete(agentID, mcpServer); err != nil {
+		if errors.Is(err, domain.ErrMCPToolGrantNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete MCP tool grant: %w", err)
+	}
+	return nil
+}
+
+func (s *MCPToolGrantService) getAgent(orgID, agentID uuid.UUID) (*domain.Agent, error) {
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil || agent.OrganizationID != orgID {
+		return nil, ErrToolGrantAgentNotFound
+	}
+	return agent, nil
+}
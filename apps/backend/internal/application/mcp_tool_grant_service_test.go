@@ -0,0 +1,78 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPToolGrantsFlagToolCallsOutsideTheGrantedSet(t *testing.T) {
+	ctx := context.Background()
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	admin := uuid.New()
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.TalksTo = []string{"filesystem-mcp", "github-mcp"} })
+	require.NoError(t, repos.Agent.Create(agent))
+
+	grants := NewMCPToolGrantService(repos.MCPToolGrant, repos.Agent)
+	drift := NewDriftDetectionService(repos.Agent, repos.Alert)
+	drift.UseToolGrants(repos.MCPToolGrant)
+	events := NewVerificationEventService(repos.VerificationEvent, repos.Agent, drift, nil, nil, nil)
+
+	// Grants narrow servers the agent talks to, and only for agents of the organization
+	_, err := grants.SetGrant(ctx, org.ID, agent.ID, admin, "slack-mcp", &SetMCPToolGrantRequest{Tools: []string{"post_message"}})
+	assert.ErrorIs(t, err, ErrInvalidMCPToolGrant)
+	_, err = grants.SetGrant(ctx, uuid.New(), agent.ID, admin, "filesystem-mcp", &SetMCPToolGrantRequest{Tools: []string{"read_file"}})
+	assert.ErrorIs(t, err, ErrToolGrantAgentNotFound)
+	first, err := grants.SetGrant(ctx, org.ID, agent.ID, admin, "filesystem-mcp", &SetMCPToolGrantRequest{Tools: []string{"read_file"}})
+	require.NoError(t, err)
+	grant, err := grants.SetGrant(ctx, org.ID, agent.ID, admin, "filesystem-mcp", &SetMCPToolGrantRequest{Tools: []string{" read_file", "list_directory", "read_file"}})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, grant.ID, "setting a grant again replaces its tools")
+	assert.Equal(t, []string{"read_file", "list_directory"}, grant.Tools)
+
+	verify := func(tools ...string) *domain.VerificationEvent {
+		event, err := events.CreateVerificationEvent(ctx, &CreateVerificationEventRequest{
+			OrganizationID:   org.ID,
+			AgentID:          agent.ID,
+			Protocol:         domain.VerificationProtocolMCP,
+			VerificationType: domain.VerificationTypePermission,
+			Status:           domain.VerificationEventStatusSuccess,
+			InitiatorType:    domain.InitiatorTypeAgent,
+			CurrentMCPTools:  tools,
+		})
+		require.NoError(t, err)
+		return event
+	}
+
+	// Granted tools and tools of servers without a grant don't drift; write_file does
+	event := verify("filesystem-mcp:read_file", "filesystem-mcp:write_file", "github-mcp:create_issue")
+	assert.True(t, event.DriftDetected)
+	assert.Equal(t, []string{"filesystem-mcp:write_file"}, event.MCPToolDrift)
+	assert.Empty(t, event.MCPServerDrift)
+	assert.Equal(t, []string{"filesystem-mcp", "github-mcp"}, event.CurrentMCPServers)
+	alerts, err := repos.Alert.GetUnacknowledgedByResourceID(agent.ID)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domain.AlertTypeMCPToolDrift, alerts[0].AlertType)
+	assert.Contains(t, alerts[0].Description, "`filesystem-mcp:write_file`")
+
+	// Tools of servers the agent doesn't talk to are MCP server drift
+	event = verify("slack-mcp:post_message")
+	assert.Equal(t, []string{"slack-mcp"}, event.MCPServerDrift)
+	assert.Empty(t, event.MCPToolDrift)
+
+	// Deleting the grant allows every tool of the server again
+	listed, err := grants.ListGrants(ctx, org.ID, agent.ID)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.NoError(t, grants.DeleteGrant(ctx, org.ID, agent.ID, "filesystem-mcp"))
+	assert.ErrorIs(t, grants.DeleteGrant(ctx, org.ID, agent.ID, "filesystem-mcp"), domain.ErrMCPToolGrantNotFound)
+	event = verify("filesystem-mcp:write_file")
+	assert.False(t, event.DriftDetected)
+}
@@ -0,0 +1,298 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	ErrMFAAlreadyEnabled       = errors.New("MFA is already enabled")
+	ErrMFANotEnabled           = errors.New("MFA is not enabled")
+	ErrMFAEnrollmentNotStarted = errors.New("MFA enrollment has not been started")
+	ErrInvalidMFACode          = errors.New("invalid MFA code")
+	ErrMFARequired             = errors.New("your organization requires MFA for your role")
+)
+
+// mfaIssuer is the account issuer shown in authenticator apps
+const mfaIssuer = "Agent Identity Management"
+
+// mfaRecoveryCodeCount is the number of recovery codes issued at enrollment and on regeneration
+const mfaRecoveryCodeCount = 10
+
+// MFAService manages TOTP enrollment, verification and recovery codes for local users
+type MFAService struct {
+	userRepo domain.UserRepository
+	orgRepo  domain.OrganizationRepository
+	keyVault *crypto.KeyVault
+	now      func() time.Time
+}
+
+// NewMFAService creates a new MFA service; TOTP secrets are stored encrypted with the key vault
+func NewMFAService(
+	userRepo domain.UserRepository,
+	orgRepo domain.OrganizationRepository,
+	keyVault *crypto.KeyVault,
+) *MFAService {
+	return &MFAService{
+		userRepo: userRepo,
+		orgRepo:  orgRepo,
+		keyVault: keyVault,
+		now:      time.Now,
+	}
+}
+
+// MFAEnrollment is the secret an authenticator app is set up with
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"` // otpauth:// URI to render as a QR code
+}
+
+// MFAStatus describes a user's MFA state
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"`
+	EnrollmentPending      bool       `json:"enrollmentPending"`
+	EnrolledAt             *time.Time `json:"enrolledAt,omitempty"`
+	RecoveryCodesRemaining int        `json:"recoveryCodesRemaining"`
+}
+
+// Required reports whether the user's organization requires MFA for the user's role
+func (s *MFAService) Required(ctx context.Context, user *domain.User) (bool, error) {
+	org, err := s.orgRepo.GetByID(user.OrganizationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get organization: %w", err)
+	}
+	return org.RequiresMFA(user.Role), nil
+}
+
+// Status returns the user's MFA state
+func (s *MFAService) Status(ctx context.Context, userID uuid.UUID) (*MFAStatus, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.Required(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAStatus{
+		Enabled:                user.MFAEnabled,
+		Required:               required,
+		EnrollmentPending:      !user.MFAEnabled && user.MFASecret != nil,
+		EnrolledAt:             user.MFAEnrolledAt,
+		RecoveryCodesRemaining: len(user.MFARecoveryCodes),
+	}, nil
+}
+
+// BeginEnrollment generates a new TOTP secret for the user. MFA is not enabled until the user
+// confirms the enrollment with a code from the authenticator app; starting again replaces the secret.
+func (s *MFAService) BeginEnrollment(ctx context.Context, userID uuid.UUID) (*MFAEnrollment, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := crypto.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.keyVault.EncryptPrivateKey(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt TOTP secret: %w", err)
+	}
+
+	user.MFASecret = &encrypted
+	user.MFALastUsedStep = 0
+	if err := s.userRepo.UpdateMFA(user); err != nil {
+		return nil, fmt.Errorf("failed to store TOTP secret: %w", err)
+	}
+
+	return &MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: crypto.TOTPProvisioningURI(mfaIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables MFA once the user proves the authenticator app produces valid codes.
+// It returns the recovery codes, which are only shown this once.
+func (s *MFAService) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == nil {
+		return nil, ErrMFAEnrollmentNotStarted
+	}
+	if err := s.verifyTOTP(user, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	user.MFAEnabled = true
+	user.MFAEnrolledAt = &now
+	user.MFARecoveryCodes = hashes
+	if err := s.userRepo.UpdateMFA(user); err != nil {
+		return nil, fmt.Errorf("failed to enable MFA: %w", err)
+	}
+	return codes, nil
+}
+
+// Verify checks a TOTP code or, failing that, a recovery code, which is then used up.
+// It returns whether a recovery code was used.
+func (s *MFAService) Verify(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return false, err
+	}
+	if !user.MFAEnabled || user.MFASecret == nil {
+		return false, ErrMFANotEnabled
+	}
+
+	if err := s.verifyTOTP(user, code); err == nil {
+		return false, nil
+	} else if !errors.Is(err, ErrInvalidMFACode) {
+		return false, err
+	}
+
+	hash := hashRecoveryCode(code)
+	index := slices.Index(user.MFARecoveryCodes, hash)
+	if index < 0 {
+		return false, ErrInvalidMFACode
+	}
+	user.MFARecoveryCodes = slices.Delete(slices.Clone(user.MFARecoveryCodes), index, index+1)
+	if err := s.userRepo.UpdateMFA(user); err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if len(user.MFARecoveryCodes) == 0 {
+		log.Printf("⚠️  User %s used their last MFA recovery code", user.ID)
+	}
+	return true, nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a current code
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	if _, err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	user.MFARecoveryCodes = hashes
+	if err := s.userRepo.UpdateMFA(user); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// Disable turns MFA off after checking a current code. Users whose role requires MFA cannot
+// turn it off; an admin can reset it so they enroll again.
+func (s *MFAService) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	required, err := s.Required(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequired
+	}
+	if _, err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+	return s.clear(user)
+}
+
+// Reset removes a user's MFA enrollment, e.g. after the user lost the authenticator and the
+// recovery codes. The user must enroll again at the next login if their role requires MFA.
+func (s *MFAService) Reset(ctx context.Context, orgID, userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.OrganizationID != orgID {
+		return fmt.Errorf("user not found")
+	}
+	return s.clear(user)
+}
+
+func (s *MFAService) clear(user *domain.User) error {
+	user.MFAEnabled = false
+	user.MFASecret = nil
+	user.MFARecoveryCodes = nil
+	user.MFALastUsedStep = 0
+	user.MFAEnrolledAt = nil
+	if err := s.userRepo.UpdateMFA(user); err != nil {
+		return fmt.Errorf("failed to disable MFA: %w", err)
+	}
+	return nil
+}
+
+// verifyTOTP checks the code against the user's secret and records its step so it cannot be reused
+func (s *MFAService) verifyTOTP(user *domain.User, code string) error {
+	secret, err := s.keyVault.DecryptPrivateKey(*user.MFASecret)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+	}
+	step, ok := crypto.ValidateTOTPCode(secret, code, s.now(), user.MFALastUsedStep)
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	user.MFALastUsedStep = step
+	if err := s.userRepo.UpdateMFA(user); err != nil {
+		return fmt.Errorf("failed to record TOTP code: %w", err)
+	}
+	return nil
+}
+
+// generateRecoveryCodes returns new recovery codes formatted as xxxxx-xxxxx and their hashes
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, mfaRecoveryCodeCount)
+	hashes := make([]string, mfaRecoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		encoded := hex.EncodeToString(raw)
+		codes[i] = encoded[:5] + "-" + encoded[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes so codes can be typed as printed or not
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,113 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFAEnrollmentVerificationAndRecoveryCodes(t *testing.T) {
+	t.Setenv("JWT_SECRET", "mfa-test-secret")
+	jwtService := auth.NewJWTService()
+
+	repos := testsupport.NewRepositories()
+	ctx := context.Background()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	user := testsupport.NewUser(org.ID, func(u *domain.User) { u.Role = domain.RoleAdmin })
+	require.NoError(t, repos.User.Create(user))
+
+	masterKey := make([]byte, 32)
+	_, err := rand.Read(masterKey)
+	require.NoError(t, err)
+	vault, err := crypto.NewKeyVault(base64.StdEncoding.EncodeToString(masterKey))
+	require.NoError(t, err)
+	service := NewMFAService(repos.User, repos.Organization, vault)
+
+	enrollment, err := service.BeginEnrollment(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.ProvisioningURI, "secret="+enrollment.Secret)
+	stored, err := repos.User.GetByID(user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.MFASecret)
+	assert.NotEqual(t, enrollment.Secret, *stored.MFASecret, "the secret is stored encrypted")
+	assert.False(t, stored.MFAEnabled, "MFA is enabled only once confirmed")
+
+	code := func(offset time.Duration) string {
+		c, err := crypto.TOTPCode(enrollment.Secret, time.Now().Add(offset))
+		require.NoError(t, err)
+		return c
+	}
+	_, err = service.ConfirmEnrollment(ctx, user.ID, "000000")
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+	recoveryCodes, err := service.ConfirmEnrollment(ctx, user.ID, code(-crypto.TOTPPeriod))
+	require.NoError(t, err)
+	assert.Len(t, recoveryCodes, 10)
+
+	// A plain user update (e.g. recording a login) keeps the MFA state
+	stored, err = repos.User.GetByID(user.ID)
+	require.NoError(t, err)
+	stored.MFAEnabled = false
+	require.NoError(t, repos.User.Update(stored))
+	status, err := service.Status(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, 10, status.RecoveryCodesRemaining)
+
+	current := code(0)
+	usedRecovery, err := service.Verify(ctx, user.ID, current)
+	require.NoError(t, err)
+	assert.False(t, usedRecovery)
+	_, err = service.Verify(ctx, user.ID, current)
+	assert.ErrorIs(t, err, ErrInvalidMFACode, "a TOTP code cannot be replayed")
+
+	usedRecovery, err = service.Verify(ctx, user.ID, strings.ToUpper(recoveryCodes[0]))
+	require.NoError(t, err)
+	assert.True(t, usedRecovery)
+	_, err = service.Verify(ctx, user.ID, recoveryCodes[0])
+	assert.ErrorIs(t, err, ErrInvalidMFACode, "recovery codes are single-use")
+	status, err = service.Status(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 9, status.RecoveryCodesRemaining)
+
+	// Organizations can require MFA by role; those users cannot turn it off
+	org.MFARequiredRoles = []domain.UserRole{domain.RoleAdmin, domain.RoleManager}
+	require.NoError(t, repos.Organization.Update(org))
+	assert.ErrorIs(t, service.Disable(ctx, user.ID, recoveryCodes[1]), ErrMFARequired)
+	member := testsupport.NewUser(org.ID, func(u *domain.User) { u.Role = domain.RoleMember })
+	require.NoError(t, repos.User.Create(member))
+	required, err := service.Required(ctx, member)
+	require.NoError(t, err)
+	assert.False(t, required)
+
+	require.NoError(t, service.Reset(ctx, org.ID, user.ID))
+	status, err = service.Status(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, status.Enabled)
+	assert.True(t, status.Required)
+	assert.Error(t, service.Reset(ctx, uuid.New(), user.ID), "admins can only reset users in their organization")
+
+	// The login challenge token is not accepted as a session token, and vice versa
+	challenge, err := jwtService.GenerateMFAChallengeToken(user.ID.String(), org.ID.String(), time.Minute)
+	require.NoError(t, err)
+	claims, err := jwtService.ValidateMFAChallengeToken(challenge)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID.String(), claims.UserID)
+	_, err = jwtService.ValidateToken(challenge)
+	assert.Error(t, err)
+	accessToken, err := jwtService.GenerateAccessToken(user.ID.String(), org.ID.String(), user.Email, string(user.Role))
+	require.NoError(t, err)
+	_, err = jwtService.ValidateMFAChallengeToken(accessToken)
+	assert.Error(t, err)
+}
@@ -0,0 +1,405 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrInvalidNamingPolicy is returned when a naming policy's scope or rules are invalid
+	ErrInvalidNamingPolicy = errors.New("invalid naming policy")
+	// ErrNamingPolicyViolation is returned when a name breaks one of the organization's naming policies
+	ErrNamingPolicyViolation = errors.New("name violates the organization's naming policies")
+)
+
+// NamingPolicyViolationError lists the naming policy rules a rename or tagging breaks
+type NamingPolicyViolationError struct {
+	Violations []domain.NamingViolation
+}
+
+func (e *NamingPolicyViolationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *NamingPolicyViolationError) Unwrap() error {
+	return ErrNamingPolicyViolation
+}
+
+// nameSeparators are ignored when names are compared for normalized uniqueness
+var nameSeparators = strings.NewReplacer("-", "", "_", "", ".", "", " ", "")
+
+// NamingPolicyService manages an organization's naming policies and checks agent and MCP server
+// names against them
+type NamingPolicyService struct {
+	policyRepo domain.NamingPolicyRepository
+	agentRepo  domain.AgentRepository
+	mcpRepo    domain.MCPServerRepository
+	tagRepo    domain.TagRepository
+}
+
+// NewNamingPolicyService creates a new naming policy service
+func NewNamingPolicyService(
+	policyRepo domain.NamingPolicyRepository,
+	agentRepo domain.AgentRepository,
+	mcpRepo domain.MCPServerRepository,
+	tagRepo domain.TagRepository,
+) *NamingPolicyService {
+	return &NamingPolicyService{
+		policyRepo: policyRepo,
+		agentRepo:  agentRepo,
+		mcpRepo:    mcpRepo,
+		tagRepo:    tagRepo,
+	}
+}
+
+// NamingPolicyRequest creates or replaces a naming policy
+type NamingPolicyRequest struct {
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	ResourceType     string   `json:"resourceType"` // agent, mcp_server or empty for both
+	TagKey           string   `json:"tagKey"`
+	TagValue         string   `json:"tagValue"` // Empty matches any value of TagKey
+	Pattern          string   `json:"pattern"`
+	RequiredPrefix   string   `json:"requiredPrefix"`
+	ReservedNames    []string `json:"reservedNames"`
+	NormalizedUnique bool     `json:"normalizedUnique"`
+	IsActive         *bool    `json:"isActive,omitempty"` // Defaults to true
+}
+
+// NamingTag is a tag a previewed resource carries
+type NamingTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// NamingPreviewRequest asks whether a name would be accepted
+type NamingPreviewRequest struct {
+	ResourceType string `json:"resourceType"`
+	Name         string `json:"name"`
+	// ResourceID previews a rename: the resource's current tags apply, and its own name is not a conflict
+	ResourceID *uuid.UUID  `json:"resourceId,omitempty"`
+	Tags       []NamingTag `json:"tags,omitempty"` // Tags the resource will carry in addition to its current ones
+}
+
+// NamingPreview is the outcome of checking a name against the organization's naming policies
+type NamingPreview struct {
+	Valid           bool                     `json:"valid"`
+	Violations      []domain.NamingViolation `json:"violations"`
+	AppliedPolicies []string                 `json:"appliedPolicies"` // Names of the policies whose scope matched
+}
+
+// CreatePolicy creates a naming policy
+func (s *NamingPolicyService) CreatePolicy(ctx context.Context, req *NamingPolicyRequest, orgID, userID uuid.UUID) (*domain.NamingPolicy, error) {
+	policy := &domain.NamingPolicy{
+		OrganizationID: orgID,
+		IsActive:       true,
+		CreatedBy:      userID,
+	}
+	if err := applyNamingPolicyRequest(policy, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.policyRepo.Create(policy); err != nil {
+		return nil, fmt.Errorf("failed to create naming policy: %w", err)
+	}
+	return policy, nil
+}
+
+// ListPolicies lists the organization's naming policies
+func (s *NamingPolicyService) ListPolicies(ctx context.Context, orgID uuid.UUID) ([]*domain.NamingPolicy, error) {
+	policies, err := s.policyRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list naming policies: %w", err)
+	}
+	if policies == nil {
+		policies = []*domain.NamingPolicy{}
+	}
+	return policies, nil
+}
+
+// GetPolicy returns one of the organization's naming policies
+func (s *NamingPolicyService) GetPolicy(ctx context.Context, orgID, id uuid.UUID) (*domain.NamingPolicy, error) {
+	policy, err := s.policyRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if policy.OrganizationID != orgID {
+		return nil, domain.ErrNamingPolicyNotFound
+	}
+	return policy, nil
+}
+
+// UpdatePolicy replaces a naming policy's scope and rules. Existing names are not rechecked.
+func (s *NamingPolicyService) UpdatePolicy(ctx context.Context, orgID, id uuid.UUID, req *NamingPolicyRequest) (*domain.NamingPolicy, error) {
+	policy, err := s.GetPolicy(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyNamingPolicyRequest(policy, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.policyRepo.Update(policy); err != nil {
+		return nil, fmt.Errorf("failed to update naming policy: %w", err)
+	}
+	return policy, nil
+}
+
+// DeletePolicy deletes a naming policy
+func (s *NamingPolicyService) DeletePolicy(ctx context.Context, orgID, id uuid.UUID) error {
+	if _, err := s.GetPolicy(ctx, orgID, id); err != nil {
+		return err
+	}
+	if err := s.policyRepo.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete naming policy: %w", err)
+	}
+	return nil
+}
+
+// PreviewName checks a name against the organization's active naming policies without changing anything
+func (s *NamingPolicyService) PreviewName(ctx context.Context, orgID uuid.UUID, req *NamingPreviewRequest) (*NamingPreview, error) {
+	if req.ResourceType != domain.NamingResourceAgent && req.ResourceType != domain.NamingResourceMCPServer {
+		return nil, fmt.Errorf("%w: resourceType must be %q or %q", ErrInvalidNamingPolicy, domain.NamingResourceAgent, domain.NamingResourceMCPServer)
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidNamingPolicy)
+	}
+
+	resourceID := uuid.Nil
+	if req.ResourceID != nil {
+		resourceID = *req.ResourceID
+	}
+	tags := make([]*domain.Tag, 0, len(req.Tags))
+	for _, tag := range req.Tags {
+		tags = append(tags, &domain.Tag{Key: tag.Key, Value: tag.Value})
+	}
+
+	applied, violations, err := s.evaluate(ctx, orgID, req.ResourceType, resourceID, strings.TrimSpace(req.Name), tags)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &NamingPreview{
+		Valid:           len(violations) == 0,
+		Violations:      violations,
+		AppliedPolicies: make([]string, 0, len(applied)),
+	}
+	for _, policy := range applied {
+		preview.AppliedPolicies = append(preview.AppliedPolicies, policy.Name)
+	}
+	return preview, nil
+}
+
+// CheckName returns the naming policy rules a name breaks. resourceID is uuid.Nil for a resource
+// being registered; for an existing resource its current tags apply in addition to extraTags.
+func (s *NamingPolicyService) CheckName(ctx context.Context, orgID uuid.UUID, resourceType string, resourceID uuid.UUID, name string, extraTags []*domain.Tag) ([]domain.NamingViolation, error) {
+	_, violations, err := s.evaluate(ctx, orgID, resourceType, resourceID, name, extraTags)
+	return violations, err
+}
+
+// EnforceName is CheckName returning a *NamingPolicyViolationError when a rule is broken
+func (s *NamingPolicyService) EnforceName(ctx context.Context, orgID uuid.UUID, resourceType string, resourceID uuid.UUID, name string, extraTags []*domain.Tag) error {
+	violations, err := s.CheckName(ctx, orgID, resourceType, resourceID, name, extraTags)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &NamingPolicyViolationError{Violations: violations}
+	}
+	return nil
+}
+
+func (s *NamingPolicyService) evaluate(
+	ctx context.Context,
+	orgID uuid.UUID,
+	resourceType string,
+	resourceID uuid.UUID,
+	name string,
+	extraTags []*domain.Tag,
+) ([]*domain.NamingPolicy, []domain.NamingViolation, error) {
+	policies, err := s.policyRepo.GetActiveByOrganization(orgID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get naming policies: %w", err)
+	}
+	violations := make([]domain.NamingViolation, 0)
+	if len(policies) == 0 {
+		return nil, violations, nil
+	}
+
+	tags := extraTags
+	if resourceID != uuid.Nil {
+		current, err := s.resourceTags(ctx, resourceType, resourceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		tags = append(current, extraTags...)
+	}
+
+	var taken map[string]bool
+	var applied []*domain.NamingPolicy
+	for _, policy := range policies {
+		if !namingPolicyApplies(policy, resourceType, tags) {
+			continue
+		}
+		applied = append(applied, policy)
+
+		violate := func(code, message string) {
+			violations = append(violations, domain.NamingViolation{
+				PolicyID:   policy.ID,
+				PolicyName: policy.Name,
+				Code:       code,
+				Message:    message,
+			})
+		}
+		if policy.RequiredPrefix != "" && !strings.HasPrefix(name, policy.RequiredPrefix) {
+			violate(domain.NamingViolationPrefix, fmt.Sprintf("%s: name must start with %q", policy.Name, policy.RequiredPrefix))
+		}
+		if policy.Pattern != "" {
+			pattern, err := compileNamingPattern(policy.Pattern)
+			if err != nil {
+				return nil, nil, fmt.Errorf("naming policy %s has an invalid pattern: %w", policy.ID, err)
+			}
+			if !pattern.MatchString(name) {
+				violate(domain.NamingViolationPattern, fmt.Sprintf("%s: name must match %s", policy.Name, policy.Pattern))
+			}
+		}
+		for _, reserved := range policy.ReservedNames {
+			if strings.EqualFold(name, reserved) {
+				violate(domain.NamingViolationReserved, fmt.Sprintf("%s: %q is a reserved name", policy.Name, reserved))
+				break
+			}
+		}
+		if policy.NormalizedUnique {
+			if taken == nil {
+				if taken, err = s.takenNames(orgID, resourceID); err != nil {
+					return nil, nil, err
+				}
+			}
+			if taken[normalizeResourceName(name)] {
+				violate(domain.NamingViolationNotUnique, fmt.Sprintf("%s: %q differs from an existing agent or MCP server name only in case or separators", policy.Name, name))
+			}
+		}
+	}
+
+	return applied, violations, nil
+}
+
+// takenNames returns the normalized names of the organization's agents and MCP servers, except the
+// resource being renamed
+func (s *NamingPolicyService) takenNames(orgID, exceptID uuid.UUID) (map[string]bool, error) {
+	taken := make(map[string]bool)
+
+	agents, err := s.agentRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agents: %w", err)
+	}
+	for _, agent := range agents {
+		if agent.ID != exceptID {
+			taken[normalizeResourceName(agent.Name)] = true
+		}
+	}
+
+	servers, err := s.mcpRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mcp servers: %w", err)
+	}
+	for _, server := range servers {
+		if server.ID != exceptID {
+			taken[normalizeResourceName(server.Name)] = true
+		}
+	}
+
+	return taken, nil
+}
+
+func (s *NamingPolicyService) resourceTags(ctx context.Context, resourceType string, resourceID uuid.UUID) ([]*domain.Tag, error) {
+	var tags []*domain.Tag
+	var err error
+	if resourceType == domain.NamingResourceAgent {
+		tags, err = s.tagRepo.GetAgentTags(ctx, resourceID)
+	} else {
+		tags, err = s.tagRepo.GetMCPServerTags(ctx, resourceID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource tags: %w", err)
+	}
+	return tags, nil
+}
+
+// namingPolicyApplies reports whether the policy's resource type and tag scope match the resource
+func namingPolicyApplies(policy *domain.NamingPolicy, resourceType string, tags []*domain.Tag) bool {
+	if policy.ResourceType != "" && policy.ResourceType != resourceType {
+		return false
+	}
+	if policy.TagKey == "" {
+		return true
+	}
+	for _, tag := range tags {
+		if strings.EqualFold(tag.Key, policy.TagKey) && (policy.TagValue == "" || strings.EqualFold(tag.Value, policy.TagValue)) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeResourceName compares names ignoring case and separators, so "Payments-Bot" and
+// "payments_bot" are the same name
+func normalizeResourceName(name string) string {
+	return nameSeparators.Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// compileNamingPattern anchors a policy pattern so it must match the whole name
+func compileNamingPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+func applyNamingPolicyRequest(policy *domain.NamingPolicy, req *NamingPolicyRequest) error {
+	name := strings.TrimSpace(req.Name)
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidNamingPolicy)
+	case req.ResourceType != "" && req.ResourceType != domain.NamingResourceAgent && req.ResourceType != domain.NamingResourceMCPServer:
+		return fmt.Errorf("%w: resourceType must be %q, %q or empty", ErrInvalidNamingPolicy, domain.NamingResourceAgent, domain.NamingResourceMCPServer)
+	case strings.TrimSpace(req.TagKey) == "" && strings.TrimSpace(req.TagValue) != "":
+		return fmt.Errorf("%w: tagValue requires tagKey", ErrInvalidNamingPolicy)
+	}
+	if req.Pattern != "" {
+		if _, err := compileNamingPattern(req.Pattern); err != nil {
+			return fmt.Errorf("%w: pattern is not a valid regular expression: %v", ErrInvalidNamingPolicy, err)
+		}
+	}
+
+	reserved := make([]string, 0, len(req.ReservedNames))
+	for _, reservedName := range req.ReservedNames {
+		if reservedName = strings.TrimSpace(reservedName); reservedName != "" {
+			reserved = append(reserved, reservedName)
+		}
+	}
+	if req.Pattern == "" && req.RequiredPrefix == "" && len(reserved) == 0 && !req.NormalizedUnique {
+		return fmt.Errorf("%w: set a pattern, requiredPrefix, reservedNames or normalizedUnique", ErrInvalidNamingPolicy)
+	}
+
+	policy.Name = name
+	policy.Description = req.Description
+	policy.ResourceType = req.ResourceType
+	policy.TagKey = strings.TrimSpace(req.TagKey)
+	policy.TagValue = strings.TrimSpace(req.TagValue)
+	policy.Pattern = req.Pattern
+	policy.RequiredPrefix = req.RequiredPrefix
+	policy.ReservedNames = reserved
+	policy.NormalizedUnique = req.NormalizedUnique
+	if req.IsActive != nil {
+		policy.IsActive = *req.IsActive
+	}
+	return nil
+}
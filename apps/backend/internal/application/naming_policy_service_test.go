@@ -0,0 +1,98 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamingPoliciesAreEnforcedOnRegistrationAndTagging(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	admin := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.MCPServer.Create(testsupport.NewMCPServer(org.ID, func(s *domain.MCPServer) { s.Name = "Payments-Gateway" })))
+	ctx := context.Background()
+
+	naming := NewNamingPolicyService(repos.NamingPolicy, repos.Agent, repos.MCPServer, repos.Tag)
+	agentService := NewAgentService(repos.Agent, nil, repos.TrustScore, nil, repos.Alert, nil, repos.Capability, nil, nil, repos.Organization, nil, nil, nil, naming)
+	tagService := NewTagService(repos.Tag, repos.Agent, repos.MCPServer, naming)
+
+	_, err := naming.CreatePolicy(ctx, &NamingPolicyRequest{Name: "broken", Pattern: "("}, org.ID, admin.ID)
+	assert.ErrorIs(t, err, ErrInvalidNamingPolicy)
+	_, err = naming.CreatePolicy(ctx, &NamingPolicyRequest{
+		Name:             "org-wide",
+		Pattern:          `[a-z0-9-]+`,
+		ReservedNames:    []string{"admin"},
+		NormalizedUnique: true,
+	}, org.ID, admin.ID)
+	require.NoError(t, err)
+	_, err = naming.CreatePolicy(ctx, &NamingPolicyRequest{
+		Name:           "production prefix",
+		ResourceType:   domain.NamingResourceAgent,
+		TagKey:         "environment",
+		TagValue:       "production",
+		RequiredPrefix: "prod-",
+	}, org.ID, admin.ID)
+	require.NoError(t, err)
+
+	// Registration reports each broken rule; a separator-only difference counts as a taken name
+	validate := func(name string) *RegistrationValidation {
+		_, validation, err := agentService.ValidateAgentRegistration(ctx, &CreateAgentRequest{
+			Name:        name,
+			DisplayName: "Agent",
+			AgentType:   domain.AgentTypeAI,
+		}, org.ID, admin.ID)
+		require.NoError(t, err)
+		return validation
+	}
+	assert.True(t, validate("Billing_Agent").HasError(RegistrationIssueNamingPolicy))
+	assert.True(t, validate("ADMIN").HasError(RegistrationIssueNamingPolicy))
+	assert.True(t, validate("payments-gateway").HasError(RegistrationIssueNameTaken))
+	assert.True(t, validate("billing-agent").Valid, "tag-scoped policies do not apply to an untagged agent")
+
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.Name = "billing-agent" })
+	require.NoError(t, repos.Agent.Create(agent))
+
+	// Tagging the agent for production brings the prefix rule into scope
+	production, err := tagService.CreateTag(ctx, CreateTagInput{
+		OrganizationID: org.ID,
+		Key:            "environment",
+		Value:          "production",
+		Category:       domain.TagCategoryEnvironment,
+		CreatedBy:      admin.ID,
+	})
+	require.NoError(t, err)
+	err = tagService.AddTagsToAgent(ctx, agent.ID, []uuid.UUID{production.ID}, admin.ID)
+	var violation *NamingPolicyViolationError
+	require.ErrorAs(t, err, &violation)
+	require.Len(t, violation.Violations, 1)
+	assert.Equal(t, domain.NamingViolationPrefix, violation.Violations[0].Code)
+	tags, err := repos.Tag.GetAgentTags(ctx, agent.ID)
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+
+	// The preview shows which policies apply to a rename of the tagged resource
+	preview, err := naming.PreviewName(ctx, org.ID, &NamingPreviewRequest{
+		ResourceType: domain.NamingResourceAgent,
+		Name:         "prod-billing-agent",
+		Tags:         []NamingTag{{Key: "Environment", Value: "Production"}},
+	})
+	require.NoError(t, err)
+	assert.True(t, preview.Valid, "%v", preview.Violations)
+	assert.Equal(t, []string{"org-wide", "production prefix"}, preview.AppliedPolicies)
+
+	preview, err = naming.PreviewName(ctx, org.ID, &NamingPreviewRequest{
+		ResourceType: domain.NamingResourceAgent,
+		Name:         "billing.agent",
+		ResourceID:   &agent.ID,
+	})
+	require.NoError(t, err)
+	assert.Len(t, preview.Violations, 1, "an agent's own name is not a conflict, but the pattern still applies")
+	assert.Equal(t, domain.NamingViolationPattern, preview.Violations[0].Code)
+}
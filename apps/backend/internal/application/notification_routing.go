@@ -0,0 +1,416 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// maxNotificationDigestInterval caps how long a route may hold alerts back for its digest
+const maxNotificationDigestInterval = 7 * 24 * 60 // minutes
+
+// NotificationRouteRequest represents the request to create or update a notification route
+type NotificationRouteRequest struct {
+	Name                  string               `json:"name"`
+	AlertTypes            []domain.AlertType   `json:"alertTypes"`
+	MinSeverity           domain.AlertSeverity `json:"minSeverity"`
+	MaxSeverity           domain.AlertSeverity `json:"maxSeverity"`
+	ResourceTags          []string             `json:"resourceTags"`
+	ChannelIDs            []uuid.UUID          `json:"channelIds"`
+	DigestIntervalMinutes int                  `json:"digestIntervalMinutes"`
+	IsActive              *bool                `json:"isActive,omitempty"` // Pointer to distinguish between false and not provided
+}
+
+// routedChannel is a channel an alert is delivered to, right away or at the end of a digest window
+type routedChannel struct {
+	channel  *domain.NotificationChannel
+	digestAt time.Time // Zero delivers right away
+}
+
+// UseRoutes routes alerts by the organizations' notification routes. tagRepo resolves the tags
+// of alert resources for routes that match on tags; without it, such routes match no alert.
+func (s *NotificationService) UseRoutes(routeRepo domain.NotificationRouteRepository, tagRepo domain.TagRepository) {
+	s.routeRepo = routeRepo
+	s.tagRepo = tagRepo
+}
+
+// CreateRoute creates a new notification route
+func (s *NotificationService) CreateRoute(ctx context.Context, req *NotificationRouteRequest, orgID, userID uuid.UUID) (*domain.NotificationRoute, error) {
+	route := &domain.NotificationRoute{
+		OrganizationID: orgID,
+		IsActive:       true,
+		CreatedBy:      userID,
+	}
+	if err := s.applyRouteRequest(route, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.routeRepo.Create(route); err != nil {
+		return nil, fmt.Errorf("failed to create notification route: %w", err)
+	}
+
+	return route, nil
+}
+
+// ListRoutes lists all notification routes of an organization
+func (s *NotificationService) ListRoutes(ctx context.Context, orgID uuid.UUID) ([]*domain.NotificationRoute, error) {
+	return s.routeRepo.GetByOrganization(orgID)
+}
+
+// GetRoute retrieves a notification route by ID
+func (s *NotificationService) GetRoute(ctx context.Context, id uuid.UUID) (*domain.NotificationRoute, error) {
+	return s.routeRepo.GetByID(id)
+}
+
+// UpdateRoute updates an existing notification route
+func (s *NotificationService) UpdateRoute(ctx context.Context, id uuid.UUID, req *NotificationRouteRequest) (*domain.NotificationRoute, error) {
+	route, err := s.routeRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyRouteRequest(route, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.routeRepo.Update(route); err != nil {
+		return nil, fmt.Errorf("failed to update notification route: %w", err)
+	}
+
+	return route, nil
+}
+
+// DeleteRoute deletes a notification route
+func (s *NotificationService) DeleteRoute(ctx context.Context, id uuid.UUID) error {
+	return s.routeRepo.Delete(id)
+}
+
+// applyRouteRequest validates a request and copies it onto the route
+func (s *NotificationService) applyRouteRequest(route *domain.NotificationRoute, req *NotificationRouteRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	minSeverity := req.MinSeverity
+	if minSeverity == "" {
+		minSeverity = domain.AlertSeverityInfo
+	}
+	if severityRank(minSeverity) < 0 {
+		return fmt.Errorf("invalid minimum severity: %s", minSeverity)
+	}
+	if req.MaxSeverity != "" {
+		if severityRank(req.MaxSeverity) < 0 {
+			return fmt.Errorf("invalid maximum severity: %s", req.MaxSeverity)
+		}
+		if severityRank(req.MaxSeverity) < severityRank(minSeverity) {
+			return fmt.Errorf("maximum severity is below the minimum severity")
+		}
+	}
+
+	if req.DigestIntervalMinutes < 0 || req.DigestIntervalMinutes > maxNotificationDigestInterval {
+		return fmt.Errorf("digest interval must be between 0 and %d minutes", maxNotificationDigestInterval)
+	}
+
+	if len(req.ChannelIDs) == 0 {
+		return fmt.Errorf("at least one channel is required")
+	}
+	for _, id := range req.ChannelIDs {
+		channel, err := s.notificationRepo.GetChannelByID(id)
+		if err != nil || channel.OrganizationID != route.OrganizationID {
+			return fmt.Errorf("notification channel not found: %s", id)
+		}
+	}
+
+	alertTypes := make([]domain.AlertType, 0, len(req.AlertTypes))
+	for _, alertType := range req.AlertTypes {
+		if alertType = domain.AlertType(strings.TrimSpace(string(alertType))); alertType != "" {
+			alertTypes = append(alertTypes, alertType)
+		}
+	}
+
+	tags := make([]string, 0, len(req.ResourceTags))
+	for _, tag := range req.ResourceTags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if !strings.Contains(tag, ":") {
+			return fmt.Errorf("resource tags must be key:value, got %q", tag)
+		}
+		tags = append(tags, tag)
+	}
+
+	route.Name = strings.TrimSpace(req.Name)
+	route.AlertTypes = alertTypes
+	route.MinSeverity = minSeverity
+	route.MaxSeverity = req.MaxSeverity
+	route.ResourceTags = tags
+	route.ChannelIDs = req.ChannelIDs
+	route.DigestIntervalMinutes = req.DigestIntervalMinutes
+	if req.IsActive != nil {
+		route.IsActive = *req.IsActive
+	}
+
+	return nil
+}
+
+// routeAlert returns the channels the organization's routes send the alert to. It reports false
+// when the organization has no active routes, so the channels' own event filters apply instead.
+func (s *NotificationService) routeAlert(ctx context.Context, alert *domain.Alert) ([]routedChannel, bool, error) {
+	if s.routeRepo == nil {
+		return nil, false, nil
+	}
+	routes, err := s.routeRepo.GetActiveByOrganization(alert.OrganizationID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load notification routes: %w", err)
+	}
+	if len(routes) == 0 {
+		return nil, false, nil
+	}
+
+	var tags []string
+	for _, route := range routes {
+		if len(route.ResourceTags) > 0 {
+			tags = s.alertResourceTags(ctx, alert)
+			break
+		}
+	}
+
+	now := time.Now().UTC()
+	byChannel := make(map[uuid.UUID]int) // Index into routed
+	var routed []routedChannel
+	for _, route := range routes {
+		if !routeMatches(route, alert, tags) {
+			continue
+		}
+
+		var digestAt time.Time
+		if route.DigestIntervalMinutes > 0 {
+			interval := time.Duration(route.DigestIntervalMinutes) * time.Minute
+			digestAt = now.Truncate(interval).Add(interval)
+		}
+
+		for _, id := range route.ChannelIDs {
+			if i, ok := byChannel[id]; ok {
+				// Another route already sends the alert there; the earliest delivery wins
+				if routed[i].digestAt.IsZero() || (!digestAt.IsZero() && !digestAt.Before(routed[i].digestAt)) {
+					continue
+				}
+				routed[i].digestAt = digestAt
+				continue
+			}
+
+			channel, err := s.notificationRepo.GetChannelByID(id)
+			if err != nil {
+				log.Printf("⚠️  Skipping notification channel %s of route %s: %v", id, route.Name, err)
+				continue
+			}
+			if !channel.IsActive || channel.OrganizationID != alert.OrganizationID {
+				continue
+			}
+			byChannel[id] = len(routed)
+			routed = append(routed, routedChannel{channel: channel, digestAt: digestAt})
+		}
+	}
+
+	return routed, true, nil
+}
+
+// alertResourceTags returns the lowercase "key:value" tags of the agent or MCP server an alert is about
+func (s *NotificationService) alertResourceTags(ctx context.Context, alert *domain.Alert) []string {
+	if s.tagRepo == nil || alert.ResourceID == uuid.Nil {
+		return nil
+	}
+
+	var tags []*domain.Tag
+	var err error
+	switch alert.ResourceType {
+	case "agent":
+		tags, err = s.tagRepo.GetAgentTags(ctx, alert.ResourceID)
+	case "mcp_server":
+		tags, err = s.tagRepo.GetMCPServerTags(ctx, alert.ResourceID)
+	default:
+		return nil
+	}
+	if err != nil {
+		log.Printf("⚠️  Failed to load tags of %s %s for notification routing: %v", alert.ResourceType, alert.ResourceID, err)
+		return nil
+	}
+
+	values := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		values = append(values, strings.ToLower(tag.Key+":"+tag.Value))
+	}
+	return values
+}
+
+// routeMatches reports whether a route covers the alert; tags are the alert resource's tags
+func routeMatches(route *domain.NotificationRoute, alert *domain.Alert, tags []string) bool {
+	rank := severityRank(alert.Severity)
+	if rank < severityRank(route.MinSeverity) {
+		return false
+	}
+	if route.MaxSeverity != "" && rank > severityRank(route.MaxSeverity) {
+		return false
+	}
+
+	if len(route.AlertTypes) > 0 {
+		matched := false
+		for _, alertType := range route.AlertTypes {
+			if alertType == alert.AlertType {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(route.ResourceTags) == 0 {
+		return true
+	}
+	for _, want := range route.ResourceTags {
+		for _, tag := range tags {
+			if tag == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// attemptDigests sends each recipient's due digest deliveries as one message
+func (s *NotificationService) attemptDigests(
+	ctx context.Context,
+	deliveries []*domain.NotificationDelivery,
+	channels map[uuid.UUID]*domain.NotificationChannel,
+	notifications map[uuid.UUID]*domain.Notification,
+) {
+	type digestKey struct {
+		channelID uuid.UUID
+		recipient string
+	}
+	var keys []digestKey
+	groups := make(map[digestKey][]*domain.NotificationDelivery)
+	for _, delivery := range deliveries {
+		key := digestKey{channelID: delivery.ChannelID, recipient: delivery.Recipient}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], delivery)
+	}
+
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			// Unprocessed claims are released when their lease expires
+			return
+		}
+		s.attemptDigest(ctx, groups[key], channels, notifications)
+	}
+}
+
+// attemptDigest sends deliveries of one channel to one recipient as one message; they succeed or
+// fail together
+func (s *NotificationService) attemptDigest(
+	ctx context.Context,
+	deliveries []*domain.NotificationDelivery,
+	channels map[uuid.UUID]*domain.NotificationChannel,
+	notifications map[uuid.UUID]*domain.Notification,
+) {
+	first := deliveries[0]
+	failAll := func(err error, permanent bool) {
+		for _, delivery := range deliveries {
+			s.recordFailure(delivery, err, permanent)
+		}
+	}
+
+	channel, ok := channels[first.ChannelID]
+	if !ok {
+		var err error
+		if channel, err = s.notificationRepo.GetChannelByID(first.ChannelID); err != nil {
+			failAll(fmt.Errorf("channel unavailable: %w", err), true)
+			return
+		}
+		channels[first.ChannelID] = channel
+	}
+
+	sender, ok := s.senders[first.ChannelType]
+	if !ok {
+		failAll(fmt.Errorf("no sender registered for channel type %s", first.ChannelType), true)
+		return
+	}
+
+	included := make([]*domain.Notification, 0, len(deliveries))
+	sendable := make([]*domain.NotificationDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		notification, ok := notifications[delivery.NotificationID]
+		if !ok {
+			var err error
+			if notification, err = s.notificationRepo.GetNotificationByID(delivery.NotificationID); err != nil {
+				s.recordFailure(delivery, fmt.Errorf("notification unavailable: %w", err), true)
+				continue
+			}
+			notifications[delivery.NotificationID] = notification
+		}
+		included = append(included, notification)
+		sendable = append(sendable, delivery)
+	}
+	if len(sendable) == 0 {
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, notificationSendTimeout)
+	err := sender.Send(sendCtx, channel, first.Recipient, digestNotification(channel, sendable[0].ID, included))
+	cancel()
+
+	for _, delivery := range sendable {
+		if err != nil {
+			s.recordFailure(delivery, err, false)
+			continue
+		}
+		if err := s.notificationRepo.MarkDelivered(delivery.ID); err != nil {
+			log.Printf("⚠️  Failed to mark notification delivery %s as delivered: %v", delivery.ID, err)
+		}
+	}
+}
+
+// digestNotification summarizes notifications in one message at the severity of the most severe.
+// It takes the ID of a delivery it covers, so retries of the digest keep the same ID.
+func digestNotification(channel *domain.NotificationChannel, id uuid.UUID, notifications []*domain.Notification) *domain.Notification {
+	severity := domain.AlertSeverityInfo
+	lines := make([]string, 0, len(notifications))
+	ids := make([]uuid.UUID, 0, len(notifications))
+	for _, n := range notifications {
+		if severityRank(n.Severity) > severityRank(severity) {
+			severity = n.Severity
+		}
+		lines = append(lines, fmt.Sprintf("[%s] %s (%s)", n.Severity, n.Title, n.CreatedAt.UTC().Format("2006-01-02 15:04 MST")))
+		ids = append(ids, n.ID)
+	}
+
+	title := "1 alert"
+	if len(notifications) != 1 {
+		title = fmt.Sprintf("%d alerts", len(notifications))
+	}
+
+	return &domain.Notification{
+		ID:             id,
+		OrganizationID: channel.OrganizationID,
+		EventType:      "notification.digest",
+		Severity:       severity,
+		Title:          "Digest: " + title,
+		Message:        strings.Join(lines, "\n"),
+		ResourceType:   "notification_channel",
+		ResourceID:     &channel.ID,
+		Payload: map[string]interface{}{
+			"notificationIds": ids,
+		},
+		CreatedAt: time.Now().UTC(),
+	}
+}
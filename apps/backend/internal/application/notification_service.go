@@ -0,0 +1,502 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// defaultNotificationMaxAttempts is the retry budget of every delivery
+	defaultNotificationMaxAttempts = 5
+	// notificationBaseBackoff is the delay before the first retry; it doubles per attempt
+	notificationBaseBackoff = 30 * time.Second
+	// notificationMaxBackoff caps the delay between retries
+	notificationMaxBackoff = time.Hour
+	// notificationClaimLease is how long a worker owns a claimed delivery
+	notificationClaimLease = 2 * time.Minute
+	// notificationBatchSize is the number of deliveries claimed per poll
+	notificationBatchSize = 50
+	// notificationPollInterval is how often the worker polls the queue
+	notificationPollInterval = 5 * time.Second
+	// notificationSendTimeout bounds a single send to a downstream service
+	notificationSendTimeout = 15 * time.Second
+)
+
+// NotificationService fans alerts and events out to notification channels through
+// a persistent delivery queue, so downstream outages delay notifications instead of dropping them
+type NotificationService struct {
+	notificationRepo domain.NotificationRepository
+	senders          map[domain.NotificationChannelType]domain.NotificationSender
+	routeRepo        domain.NotificationRouteRepository // Optional: routes alerts by alert type, severity and resource tags
+	tagRepo          domain.TagRepository               // Optional: tags of alert resources for routes
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(
+	notificationRepo domain.NotificationRepository,
+	senders ...domain.NotificationSender,
+) *NotificationService {
+	s := &NotificationService{
+		notificationRepo: notificationRepo,
+		senders:          make(map[domain.NotificationChannelType]domain.NotificationSender),
+	}
+	for _, sender := range senders {
+		s.senders[sender.ChannelType()] = sender
+	}
+	return s
+}
+
+// NotificationChannelRequest represents the request to create or update a notification channel
+type NotificationChannelRequest struct {
+	Name        string                         `json:"name"`
+	ChannelType domain.NotificationChannelType `json:"channelType"`
+	Recipients  []string                       `json:"recipients"`
+	Config      map[string]interface{}         `json:"config"`
+	EventTypes  []string                       `json:"eventTypes"`
+	MinSeverity domain.AlertSeverity           `json:"minSeverity"`
+	IsActive    *bool                          `json:"isActive,omitempty"` // Pointer to distinguish between false and not provided
+}
+
+// CreateChannel creates a new notification channel
+func (s *NotificationService) CreateChannel(ctx context.Context, req *NotificationChannelRequest, orgID, userID uuid.UUID) (*domain.NotificationChannel, error) {
+	channel := &domain.NotificationChannel{
+		OrganizationID: orgID,
+		IsActive:       true,
+		CreatedBy:      userID,
+	}
+	if err := s.applyChannelRequest(channel, req, true); err != nil {
+		return nil, err
+	}
+
+	if err := s.notificationRepo.CreateChannel(channel); err != nil {
+		return nil, fmt.Errorf("failed to create notification channel: %w", err)
+	}
+
+	return channel, nil
+}
+
+// ListChannels lists all notification channels of an organization
+func (s *NotificationService) ListChannels(ctx context.Context, orgID uuid.UUID) ([]*domain.NotificationChannel, error) {
+	return s.notificationRepo.GetChannelsByOrganization(orgID)
+}
+
+// GetChannel retrieves a notification channel by ID
+func (s *NotificationService) GetChannel(ctx context.Context, id uuid.UUID) (*domain.NotificationChannel, error) {
+	return s.notificationRepo.GetChannelByID(id)
+}
+
+// UpdateChannel updates an existing notification channel. The channel type cannot change.
+func (s *NotificationService) UpdateChannel(ctx context.Context, id uuid.UUID, req *NotificationChannelRequest) (*domain.NotificationChannel, error) {
+	channel, err := s.notificationRepo.GetChannelByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyChannelRequest(channel, req, false); err != nil {
+		return nil, err
+	}
+
+	if err := s.notificationRepo.UpdateChannel(channel); err != nil {
+		return nil, fmt.Errorf("failed to update notification channel: %w", err)
+	}
+
+	return channel, nil
+}
+
+// DeleteChannel deletes a notification channel
+func (s *NotificationService) DeleteChannel(ctx context.Context, id uuid.UUID) error {
+	return s.notificationRepo.DeleteChannel(id)
+}
+
+// applyChannelRequest validates a request and copies it onto the channel
+func (s *NotificationService) applyChannelRequest(channel *domain.NotificationChannel, req *NotificationChannelRequest, isCreate bool) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if isCreate {
+		if _, ok := s.senders[req.ChannelType]; !ok {
+			return fmt.Errorf("unsupported channel type: %s", req.ChannelType)
+		}
+		channel.ChannelType = req.ChannelType
+	}
+
+	recipients := make([]string, 0, len(req.Recipients))
+	for _, r := range req.Recipients {
+		if r = strings.TrimSpace(r); r != "" {
+			recipients = append(recipients, r)
+		}
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+
+	minSeverity := req.MinSeverity
+	if minSeverity == "" {
+		minSeverity = domain.AlertSeverityInfo
+	}
+	if severityRank(minSeverity) < 0 {
+		return fmt.Errorf("invalid minimum severity: %s", minSeverity)
+	}
+
+	channel.Name = strings.TrimSpace(req.Name)
+	channel.Recipients = recipients
+	channel.Config = req.Config
+	if channel.Config == nil {
+		channel.Config = map[string]interface{}{}
+	}
+	channel.EventTypes = req.EventTypes
+	if channel.EventTypes == nil {
+		channel.EventTypes = []string{}
+	}
+	channel.MinSeverity = minSeverity
+	if req.IsActive != nil {
+		channel.IsActive = *req.IsActive
+	}
+
+	return nil
+}
+
+// Dispatch stores a notification and enqueues one delivery per recipient of every matching channel.
+// Delivery itself happens asynchronously in the queue worker.
+func (s *NotificationService) Dispatch(ctx context.Context, notification *domain.Notification) (*domain.Notification, error) {
+	channels, err := s.notificationRepo.GetActiveChannelsByOrganization(notification.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification channels: %w", err)
+	}
+
+	var matching []*domain.NotificationChannel
+	for _, channel := range channels {
+		if channelMatches(channel, notification) {
+			matching = append(matching, channel)
+		}
+	}
+
+	return s.enqueue(notification, matching)
+}
+
+// DispatchAlert fans an alert out to the channels of the organization's matching notification
+// routes or, without routes, to all matching channels
+func (s *NotificationService) DispatchAlert(ctx context.Context, alert *domain.Alert) (*domain.Notification, error) {
+	var resourceID *uuid.UUID
+	if alert.ResourceID != uuid.Nil {
+		id := alert.ResourceID
+		resourceID = &id
+	}
+
+	notification := &domain.Notification{
+		OrganizationID: alert.OrganizationID,
+		EventType:      "alert." + string(alert.AlertType),
+		Severity:       alert.Severity,
+		Title:          alert.Title,
+		Message:        alert.Description,
+		ResourceType:   alert.ResourceType,
+		ResourceID:     resourceID,
+		Payload: map[string]interface{}{
+			"alertId":   alert.ID,
+			"alertType": alert.AlertType,
+		},
+	}
+
+	routed, ok, err := s.routeAlert(ctx, alert)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return s.Dispatch(ctx, notification)
+	}
+	return s.enqueueRouted(notification, routed)
+}
+
+// TestChannel enqueues a test notification for a single channel, regardless of its filters
+func (s *NotificationService) TestChannel(ctx context.Context, channel *domain.NotificationChannel) (*domain.Notification, error) {
+	return s.enqueue(&domain.Notification{
+		OrganizationID: channel.OrganizationID,
+		EventType:      "notification.test",
+		Severity:       domain.AlertSeverityInfo,
+		Title:          "Test notification",
+		Message:        fmt.Sprintf("This is a test notification for channel '%s'", channel.Name),
+		ResourceType:   "notification_channel",
+		ResourceID:     &channel.ID,
+	}, []*domain.NotificationChannel{channel})
+}
+
+// DispatchToChannels enqueues a notification for specific channels, bypassing their event filters.
+// Channels that are disabled or belong to another organization are skipped.
+func (s *NotificationService) DispatchToChannels(ctx context.Context, notification *domain.Notification, channelIDs []uuid.UUID) (*domain.Notification, error) {
+	var channels []*domain.NotificationChannel
+	for _, id := range channelIDs {
+		channel, err := s.notificationRepo.GetChannelByID(id)
+		if err != nil {
+			log.Printf("⚠️  Skipping notification channel %s: %v", id, err)
+			continue
+		}
+		if !channel.IsActive || channel.OrganizationID != notification.OrganizationID {
+			continue
+		}
+		channels = append(channels, channel)
+	}
+
+	return s.enqueue(notification, channels)
+}
+
+func (s *NotificationService) enqueue(notification *domain.Notification, channels []*domain.NotificationChannel) (*domain.Notification, error) {
+	routed := make([]routedChannel, len(channels))
+	for i, channel := range channels {
+		routed[i] = routedChannel{channel: channel}
+	}
+	return s.enqueueRouted(notification, routed)
+}
+
+func (s *NotificationService) enqueueRouted(notification *domain.Notification, channels []routedChannel) (*domain.Notification, error) {
+	if notification.Payload == nil {
+		notification.Payload = map[string]interface{}{}
+	}
+
+	var deliveries []*domain.NotificationDelivery
+	for _, routed := range channels {
+		for _, recipient := range routed.channel.Recipients {
+			deliveries = append(deliveries, &domain.NotificationDelivery{
+				ChannelID:     routed.channel.ID,
+				ChannelType:   routed.channel.ChannelType,
+				Recipient:     recipient,
+				Status:        domain.NotificationDeliveryPending,
+				MaxAttempts:   defaultNotificationMaxAttempts,
+				NextAttemptAt: routed.digestAt,
+				Digest:        !routed.digestAt.IsZero(),
+			})
+		}
+	}
+
+	if err := s.notificationRepo.CreateNotification(notification, deliveries); err != nil {
+		return nil, fmt.Errorf("failed to enqueue notification: %w", err)
+	}
+
+	notification.Deliveries = deliveries
+	return notification, nil
+}
+
+// channelMatches reports whether a channel subscribes to a notification.
+// Event types match exactly, by "prefix.*" wildcard or by "*".
+func channelMatches(channel *domain.NotificationChannel, notification *domain.Notification) bool {
+	if severityRank(notification.Severity) < severityRank(channel.MinSeverity) {
+		return false
+	}
+	if len(channel.EventTypes) == 0 {
+		return true
+	}
+
+	for _, pattern := range channel.EventTypes {
+		if pattern == "*" || pattern == notification.EventType {
+			return true
+		}
+		if strings.HasSuffix(pattern, ".*") && strings.HasPrefix(notification.EventType, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// severityRank orders alert severities; unknown severities rank -1
+func severityRank(severity domain.AlertSeverity) int {
+	switch severity {
+	case domain.AlertSeverityInfo:
+		return 0
+	case domain.AlertSeverityWarning:
+		return 1
+	case domain.AlertSeverityHigh:
+		return 2
+	case domain.AlertSeverityCritical:
+		return 3
+	default:
+		return -1
+	}
+}
+
+// notificationRetryBackoff returns the delay after the given (1-based) failed attempt
+func notificationRetryBackoff(attempt int) time.Duration {
+	backoff := notificationBaseBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= notificationMaxBackoff {
+			return notificationMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// Start runs the delivery worker until the context is cancelled
+func (s *NotificationService) Start(ctx context.Context) {
+	ticker := time.NewTicker(notificationPollInterval)
+	defer ticker.Stop()
+
+	log.Println("✅ Notification delivery worker started")
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Notification delivery worker stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.ProcessQueue(ctx); err != nil {
+				log.Printf("⚠️  Notification queue processing failed: %v", err)
+			}
+		}
+	}
+}
+
+// ProcessQueue claims due deliveries and attempts each once; due digest deliveries are sent as
+// one message per recipient. Returns the number of deliveries attempted.
+func (s *NotificationService) ProcessQueue(ctx context.Context) (int, error) {
+	deliveries, err := s.notificationRepo.ClaimDueDeliveries(notificationBatchSize, notificationClaimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	channels := make(map[uuid.UUID]*domain.NotificationChannel)
+	notifications := make(map[uuid.UUID]*domain.Notification)
+
+	var digests []*domain.NotificationDelivery
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			// Unprocessed claims are released when their lease expires
+			return 0, ctx.Err()
+		}
+		if delivery.Digest {
+			digests = append(digests, delivery)
+			continue
+		}
+		s.attemptDelivery(ctx, delivery, channels, notifications)
+	}
+	s.attemptDigests(ctx, digests, channels, notifications)
+
+	return len(deliveries), nil
+}
+
+func (s *NotificationService) attemptDelivery(
+	ctx context.Context,
+	delivery *domain.NotificationDelivery,
+	channels map[uuid.UUID]*domain.NotificationChannel,
+	notifications map[uuid.UUID]*domain.Notification,
+) {
+	channel, ok := channels[delivery.ChannelID]
+	if !ok {
+		var err error
+		if channel, err = s.notificationRepo.GetChannelByID(delivery.ChannelID); err != nil {
+			s.recordFailure(delivery, fmt.Errorf("channel unavailable: %w", err), true)
+			return
+		}
+		channels[delivery.ChannelID] = channel
+	}
+
+	notification, ok := notifications[delivery.NotificationID]
+	if !ok {
+		var err error
+		if notification, err = s.notificationRepo.GetNotificationByID(delivery.NotificationID); err != nil {
+			s.recordFailure(delivery, fmt.Errorf("notification unavailable: %w", err), true)
+			return
+		}
+		notifications[delivery.NotificationID] = notification
+	}
+
+	sender, ok := s.senders[delivery.ChannelType]
+	if !ok {
+		s.recordFailure(delivery, fmt.Errorf("no sender registered for channel type %s", delivery.ChannelType), true)
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, notificationSendTimeout)
+	err := sender.Send(sendCtx, channel, delivery.Recipient, notification)
+	cancel()
+
+	if err != nil {
+		s.recordFailure(delivery, err, false)
+		return
+	}
+
+	if err := s.notificationRepo.MarkDelivered(delivery.ID); err != nil {
+		log.Printf("⚠️  Failed to mark notification delivery %s as delivered: %v", delivery.ID, err)
+	}
+}
+
+// recordFailure schedules a retry, or marks the delivery failed once retries are exhausted
+func (s *NotificationService) recordFailure(delivery *domain.NotificationDelivery, sendErr error, permanent bool) {
+	attempt := delivery.Attempts + 1
+	exhausted := permanent || attempt >= delivery.MaxAttempts
+	nextAttemptAt := time.Now().UTC().Add(notificationRetryBackoff(attempt))
+
+	if exhausted {
+		log.Printf("❌ Notification delivery %s (%s → %s) failed permanently: %v",
+			delivery.ID, delivery.ChannelType, delivery.Recipient, sendErr)
+	}
+
+	if err := s.notificationRepo.MarkAttemptFailed(delivery.ID, sendErr.Error(), nextAttemptAt, exhausted); err != nil {
+		log.Printf("⚠️  Failed to record notification delivery failure %s: %v", delivery.ID, err)
+	}
+}
+
+// ListNotifications lists notifications of an organization with pagination
+func (s *NotificationService) ListNotifications(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*domain.Notification, int, error) {
+	return s.notificationRepo.GetNotificationsByOrganization(orgID, limit, offset)
+}
+
+// GetNotification retrieves a notification with its per-channel per-recipient delivery state
+func (s *NotificationService) GetNotification(ctx context.Context, id uuid.UUID) (*domain.Notification, error) {
+	notification, err := s.notificationRepo.GetNotificationByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.notificationRepo.GetDeliveriesByNotification(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deliveries: %w", err)
+	}
+	notification.Deliveries = deliveries
+
+	return notification, nil
+}
+
+// GetDelivery retrieves a single delivery
+func (s *NotificationService) GetDelivery(ctx context.Context, id uuid.UUID) (*domain.NotificationDelivery, error) {
+	return s.notificationRepo.GetDeliveryByID(id)
+}
+
+// ResendDelivery puts a single delivery back on the queue with a fresh retry budget
+func (s *NotificationService) ResendDelivery(ctx context.Context, id uuid.UUID) error {
+	delivery, err := s.notificationRepo.GetDeliveryByID(id)
+	if err != nil {
+		return err
+	}
+	if delivery.Status == domain.NotificationDeliverySending {
+		return fmt.Errorf("delivery is currently being sent")
+	}
+
+	return s.notificationRepo.RequeueDelivery(id)
+}
+
+// ResendNotification requeues every failed delivery of a notification.
+// Returns the number of requeued deliveries.
+func (s *NotificationService) ResendNotification(ctx context.Context, id uuid.UUID) (int, error) {
+	deliveries, err := s.notificationRepo.GetDeliveriesByNotification(id)
+	if err != nil {
+		return 0, err
+	}
+
+	requeued := 0
+	for _, delivery := range deliveries {
+		if delivery.Status != domain.NotificationDeliveryFailed {
+			continue
+		}
+		if err := s.notificationRepo.RequeueDelivery(delivery.ID); err != nil {
+			return requeued, fmt.Errorf("failed to requeue delivery %s: %w", delivery.ID, err)
+		}
+		requeued++
+	}
+
+	return requeued, nil
+}
@@ -0,0 +1,376 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/notification"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockNotificationRepository mocks the NotificationRepository interface
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) CreateChannel(channel *domain.NotificationChannel) error {
+	args := m.Called(channel)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetChannelByID(id uuid.UUID) (*domain.NotificationChannel, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NotificationChannel), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetChannelsByOrganization(orgID uuid.UUID) ([]*domain.NotificationChannel, error) {
+	args := m.Called(orgID)
+	return args.Get(0).([]*domain.NotificationChannel), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetActiveChannelsByOrganization(orgID uuid.UUID) ([]*domain.NotificationChannel, error) {
+	args := m.Called(orgID)
+	return args.Get(0).([]*domain.NotificationChannel), args.Error(1)
+}
+
+func (m *MockNotificationRepository) UpdateChannel(channel *domain.NotificationChannel) error {
+	args := m.Called(channel)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) DeleteChannel(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) CreateNotification(notification *domain.Notification, deliveries []*domain.NotificationDelivery) error {
+	args := m.Called(notification, deliveries)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetNotificationByID(id uuid.UUID) (*domain.Notification, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetNotificationsByOrganization(orgID uuid.UUID, limit, offset int) ([]*domain.Notification, int, error) {
+	args := m.Called(orgID, limit, offset)
+	return args.Get(0).([]*domain.Notification), args.Int(1), args.Error(2)
+}
+
+func (m *MockNotificationRepository) GetDeliveriesByNotification(notificationID uuid.UUID) ([]*domain.NotificationDelivery, error) {
+	args := m.Called(notificationID)
+	return args.Get(0).([]*domain.NotificationDelivery), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetDeliveryByID(id uuid.UUID) (*domain.NotificationDelivery, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NotificationDelivery), args.Error(1)
+}
+
+func (m *MockNotificationRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]*domain.NotificationDelivery, error) {
+	args := m.Called(limit, lease)
+	return args.Get(0).([]*domain.NotificationDelivery), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkDelivered(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) MarkAttemptFailed(id uuid.UUID, errMsg string, nextAttemptAt time.Time, exhausted bool) error {
+	args := m.Called(id, errMsg, nextAttemptAt, exhausted)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) RequeueDelivery(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// fakeNotificationSender records sends and fails while err is set
+type fakeNotificationSender struct {
+	channelType domain.NotificationChannelType
+	err         error
+	sent        []string
+}
+
+func (f *fakeNotificationSender) ChannelType() domain.NotificationChannelType {
+	return f.channelType
+}
+
+func (f *fakeNotificationSender) Send(ctx context.Context, channel *domain.NotificationChannel, recipient string, n *domain.Notification) error {
+	f.sent = append(f.sent, recipient)
+	return f.err
+}
+
+func TestDispatch_FansOutToMatchingChannels(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	service := NewNotificationService(mockRepo,
+		&fakeNotificationSender{channelType: domain.NotificationChannelEmail},
+		&fakeNotificationSender{channelType: domain.NotificationChannelSlack},
+	)
+
+	orgID := uuid.New()
+	emailChannel := &domain.NotificationChannel{
+		ID:          uuid.New(),
+		ChannelType: domain.NotificationChannelEmail,
+		Recipients:  []string{"a@example.com", "b@example.com"},
+		MinSeverity: domain.AlertSeverityInfo,
+	}
+	slackChannel := &domain.NotificationChannel{
+		ID:          uuid.New(),
+		ChannelType: domain.NotificationChannelSlack,
+		Recipients:  []string{"https://hooks.slack.com/services/x"},
+		EventTypes:  []string{"alert.*"},
+		MinSeverity: domain.AlertSeverityHigh,
+	}
+	driftOnlyChannel := &domain.NotificationChannel{
+		ID:          uuid.New(),
+		ChannelType: domain.NotificationChannelSlack,
+		Recipients:  []string{"https://hooks.slack.com/services/y"},
+		EventTypes:  []string{"drift.detected"},
+	}
+
+	mockRepo.On("GetActiveChannelsByOrganization", orgID).
+		Return([]*domain.NotificationChannel{emailChannel, slackChannel, driftOnlyChannel}, nil)
+	mockRepo.On("CreateNotification", mock.Anything, mock.Anything).Return(nil)
+
+	notification, err := service.DispatchAlert(context.Background(), &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		AlertType:      domain.AlertSecurityBreach,
+		Severity:       domain.AlertSeverityCritical,
+		Title:          "Breach",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "alert.security_breach", notification.EventType)
+	require.Len(t, notification.Deliveries, 3, "two email recipients and one matching Slack channel")
+	for _, d := range notification.Deliveries {
+		assert.Equal(t, domain.NotificationDeliveryPending, d.Status)
+		assert.NotEqual(t, driftOnlyChannel.ID, d.ChannelID)
+	}
+}
+
+func TestDispatch_SeverityBelowThreshold(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	service := NewNotificationService(mockRepo)
+
+	orgID := uuid.New()
+	mockRepo.On("GetActiveChannelsByOrganization", orgID).Return([]*domain.NotificationChannel{{
+		ID:          uuid.New(),
+		ChannelType: domain.NotificationChannelPagerDuty,
+		Recipients:  []string{"routing-key"},
+		MinSeverity: domain.AlertSeverityCritical,
+	}}, nil)
+	mockRepo.On("CreateNotification", mock.Anything, mock.Anything).Return(nil)
+
+	notification, err := service.Dispatch(context.Background(), &domain.Notification{
+		OrganizationID: orgID,
+		EventType:      "alert.trust_score_low",
+		Severity:       domain.AlertSeverityWarning,
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, notification.Deliveries)
+}
+
+func TestProcessQueue_RetriesThenFails(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	sender := &fakeNotificationSender{channelType: domain.NotificationChannelWebhook, err: errors.New("503")}
+	service := NewNotificationService(mockRepo, sender)
+
+	channel := &domain.NotificationChannel{ID: uuid.New(), ChannelType: domain.NotificationChannelWebhook}
+	notification := &domain.Notification{ID: uuid.New()}
+	retrying := &domain.NotificationDelivery{
+		ID: uuid.New(), NotificationID: notification.ID, ChannelID: channel.ID,
+		ChannelType: channel.ChannelType, Recipient: "https://a", Attempts: 0, MaxAttempts: 5,
+	}
+	lastAttempt := &domain.NotificationDelivery{
+		ID: uuid.New(), NotificationID: notification.ID, ChannelID: channel.ID,
+		ChannelType: channel.ChannelType, Recipient: "https://b", Attempts: 4, MaxAttempts: 5,
+	}
+
+	mockRepo.On("ClaimDueDeliveries", notificationBatchSize, notificationClaimLease).
+		Return([]*domain.NotificationDelivery{retrying, lastAttempt}, nil)
+	mockRepo.On("GetChannelByID", channel.ID).Return(channel, nil).Once()
+	mockRepo.On("GetNotificationByID", notification.ID).Return(notification, nil).Once()
+	mockRepo.On("MarkAttemptFailed", retrying.ID, "503", mock.Anything, false).Return(nil)
+	mockRepo.On("MarkAttemptFailed", lastAttempt.ID, "503", mock.Anything, true).Return(nil)
+
+	processed, err := service.ProcessQueue(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	assert.Equal(t, []string{"https://a", "https://b"}, sender.sent)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessQueue_MarksDelivered(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	sender := &fakeNotificationSender{channelType: domain.NotificationChannelSlack}
+	service := NewNotificationService(mockRepo, sender)
+
+	channel := &domain.NotificationChannel{ID: uuid.New(), ChannelType: domain.NotificationChannelSlack}
+	notification := &domain.Notification{ID: uuid.New()}
+	delivery := &domain.NotificationDelivery{
+		ID: uuid.New(), NotificationID: notification.ID, ChannelID: channel.ID,
+		ChannelType: channel.ChannelType, Recipient: "https://hooks.slack.com/x", MaxAttempts: 5,
+	}
+
+	mockRepo.On("ClaimDueDeliveries", notificationBatchSize, notificationClaimLease).
+		Return([]*domain.NotificationDelivery{delivery}, nil)
+	mockRepo.On("GetChannelByID", channel.ID).Return(channel, nil)
+	mockRepo.On("GetNotificationByID", notification.ID).Return(notification, nil)
+	mockRepo.On("MarkDelivered", delivery.ID).Return(nil)
+
+	_, err := service.ProcessQueue(context.Background())
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResendNotification_RequeuesOnlyFailed(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	service := NewNotificationService(mockRepo)
+
+	notificationID := uuid.New()
+	failed := &domain.NotificationDelivery{ID: uuid.New(), Status: domain.NotificationDeliveryFailed}
+	delivered := &domain.NotificationDelivery{ID: uuid.New(), Status: domain.NotificationDeliveryDelivered}
+
+	mockRepo.On("GetDeliveriesByNotification", notificationID).
+		Return([]*domain.NotificationDelivery{failed, delivered}, nil)
+	mockRepo.On("RequeueDelivery", failed.ID).Return(nil)
+
+	requeued, err := service.ResendNotification(context.Background(), notificationID)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, requeued)
+	mockRepo.AssertNotCalled(t, "RequeueDelivery", delivered.ID)
+}
+
+func TestNotificationRetryBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, notificationRetryBackoff(1))
+	assert.Equal(t, 60*time.Second, notificationRetryBackoff(2))
+	assert.Equal(t, 4*time.Minute, notificationRetryBackoff(4))
+	assert.Equal(t, time.Hour, notificationRetryBackoff(20))
+}
+
+func TestNotificationRoutesPageHighSeverityAlertsAndDigestTheRest(t *testing.T) {
+	ctx := context.Background()
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	prod := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(prod))
+	staging := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(staging))
+	tag := &domain.Tag{OrganizationID: org.ID, Key: "Environment", Value: "Prod", Category: domain.TagCategoryEnvironment}
+	require.NoError(t, repos.Tag.Create(ctx, tag))
+	require.NoError(t, repos.Tag.AddTagsToAgent(ctx, prod.ID, []uuid.UUID{tag.ID}))
+
+	emails := &recordingEmailService{}
+	service := NewNotificationService(repos.Notification, notification.NewEmailSender(emails))
+	service.UseRoutes(repos.NotificationRoute, repos.Tag)
+	userID := uuid.New()
+
+	onCall, err := service.CreateChannel(ctx, &NotificationChannelRequest{
+		Name: "on-call", ChannelType: domain.NotificationChannelEmail, Recipients: []string{"oncall@example.com"},
+	}, org.ID, userID)
+	require.NoError(t, err)
+	digest, err := service.CreateChannel(ctx, &NotificationChannelRequest{
+		Name: "security digest", ChannelType: domain.NotificationChannelEmail, Recipients: []string{"security@example.com"},
+	}, org.ID, userID)
+	require.NoError(t, err)
+
+	_, err = service.CreateRoute(ctx, &NotificationRouteRequest{
+		Name: "page on prod drift", AlertTypes: []domain.AlertType{domain.AlertTypeConfigurationDrift},
+		MinSeverity: domain.AlertSeverityHigh, ResourceTags: []string{"environment:prod"}, ChannelIDs: []uuid.UUID{onCall.ID},
+	}, org.ID, userID)
+	require.NoError(t, err)
+	_, err = service.CreateRoute(ctx, &NotificationRouteRequest{
+		Name: "drift digest", AlertTypes: []domain.AlertType{domain.AlertTypeConfigurationDrift},
+		MaxSeverity: domain.AlertSeverityWarning, ChannelIDs: []uuid.UUID{digest.ID}, DigestIntervalMinutes: 60,
+	}, org.ID, userID)
+	require.NoError(t, err)
+
+	// Invalid routes are refused
+	_, err = service.CreateRoute(ctx, &NotificationRouteRequest{
+		Name: "inverted", MinSeverity: domain.AlertSeverityCritical, MaxSeverity: domain.AlertSeverityInfo, ChannelIDs: []uuid.UUID{onCall.ID},
+	}, org.ID, userID)
+	assert.Error(t, err)
+	_, err = service.CreateRoute(ctx, &NotificationRouteRequest{
+		Name: "foreign channel", ChannelIDs: []uuid.UUID{onCall.ID},
+	}, uuid.New(), userID)
+	assert.Error(t, err)
+
+	alert := func(agent *domain.Agent, severity domain.AlertSeverity, title string) *domain.Notification {
+		n, err := service.DispatchAlert(ctx, &domain.Alert{
+			ID: uuid.New(), OrganizationID: org.ID, AlertType: domain.AlertTypeConfigurationDrift,
+			Severity: severity, Title: title, ResourceType: "agent", ResourceID: agent.ID,
+		})
+		require.NoError(t, err)
+		return n
+	}
+
+	// High severity drift on a prod agent pages right away; on other agents no route matches and
+	// the channels' own filters no longer apply
+	paged := alert(prod, domain.AlertSeverityHigh, "Prod agent drifted")
+	require.Len(t, paged.Deliveries, 1)
+	assert.Equal(t, onCall.ID, paged.Deliveries[0].ChannelID)
+	assert.False(t, paged.Deliveries[0].Digest)
+	assert.Empty(t, alert(staging, domain.AlertSeverityHigh, "Staging agent drifted").Deliveries)
+
+	// Low severity drift waits for the end of the digest window
+	first := alert(staging, domain.AlertSeverityWarning, "Undeclared MCP server")
+	second := alert(prod, domain.AlertSeverityInfo, "Unused capability")
+	var held []*domain.NotificationDelivery
+	for _, n := range []*domain.Notification{first, second} {
+		require.Len(t, n.Deliveries, 1)
+		assert.Equal(t, digest.ID, n.Deliveries[0].ChannelID)
+		assert.True(t, n.Deliveries[0].Digest)
+		assert.True(t, n.Deliveries[0].NextAttemptAt.After(time.Now()))
+		held = append(held, n.Deliveries[0])
+	}
+
+	processed, err := service.ProcessQueue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	require.Len(t, emails.emails, 1)
+	assert.Equal(t, "oncall@example.com", emails.emails[0].to)
+
+	// Once the window ends, both alerts go out as one email
+	for _, delivery := range held {
+		require.NoError(t, repos.Notification.RequeueDelivery(delivery.ID))
+	}
+	processed, err = service.ProcessQueue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	require.Len(t, emails.emails, 2)
+	sent := emails.emails[1]
+	assert.Equal(t, "security@example.com", sent.to)
+	assert.Contains(t, sent.subject, "Digest: 2 alerts")
+	assert.Contains(t, sent.body, "Undeclared MCP server")
+	assert.Contains(t, sent.body, "Unused capability")
+	for _, delivery := range held {
+		stored, err := repos.Notification.GetDeliveryByID(delivery.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.NotificationDeliveryDelivered, stored.Status)
+	}
+}
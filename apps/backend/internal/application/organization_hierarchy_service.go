@@ -0,0 +1,450 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// maxSubOrganizationDepth is how many levels of sub-organizations a top-level organization can have
+const maxSubOrganizationDepth = 4
+
+var (
+	// ErrInvalidSubOrganization is returned for sub-organizations without a name or domain, with a
+	// domain already in use, or nested too deep
+	ErrInvalidSubOrganization = errors.New("invalid sub-organization")
+	// ErrOrganizationNotInHierarchy is returned when an organization asks about an organization
+	// that is not itself or one of its sub-organizations, such as its parent or a sibling
+	ErrOrganizationNotInHierarchy = errors.New("organization is not in this organization's hierarchy")
+)
+
+// CreateSubOrganizationRequest creates a business unit under the caller's organization
+type CreateSubOrganizationRequest struct {
+	Name      string `json:"name"`
+	Domain    string `json:"domain"`              // Users signing up with this email domain join the sub-organization
+	MaxAgents int    `json:"maxAgents,omitempty"` // Default: the parent's limit
+	MaxUsers  int    `json:"maxUsers,omitempty"`  // Default: the parent's limit
+}
+
+// OrganizationHierarchyService lets enterprises run business units as sub-organizations under
+// one tenant. Visibility only flows down: an organization can see itself and every organization
+// below it, never its parent or siblings. Sub-organizations inherit the security policies of all
+// of their ancestors and, unless they set their own, the trust boundaries of the nearest one.
+type OrganizationHierarchyService struct {
+	orgRepo      domain.OrganizationRepository
+	agentRepo    domain.AgentRepository
+	securityRepo domain.SecurityRepository
+}
+
+// NewOrganizationHierarchyService creates a new organization hierarchy service
+func NewOrganizationHierarchyService(
+	orgRepo domain.OrganizationRepository,
+	agentRepo domain.AgentRepository,
+	securityRepo domain.SecurityRepository,
+) *OrganizationHierarchyService {
+	return &OrganizationHierarchyService{
+		orgRepo:      orgRepo,
+		agentRepo:    agentRepo,
+		securityRepo: securityRepo,
+	}
+}
+
+// CreateSubOrganization creates an organization under the parent. It starts with the parent's
+// plan, limits, sign-in requirements and data residency; its parent can never change afterwards.
+func (s *OrganizationHierarchyService) CreateSubOrganization(
+	ctx context.Context,
+	parentID uuid.UUID,
+	req *CreateSubOrganizationRequest,
+) (*domain.Organization, error) {
+	name := strings.TrimSpace(req.Name)
+	domainName := strings.ToLower(strings.TrimSpace(req.Domain))
+	if name == "" || domainName == "" {
+		return nil, fmt.Errorf("%w: name and domain are required", ErrInvalidSubOrganization)
+	}
+	if req.MaxAgents < 0 || req.MaxUsers < 0 {
+		return nil, fmt.Errorf("%w: maxAgents and maxUsers can't be negative", ErrInvalidSubOrganization)
+	}
+
+	parent, err := s.orgRepo.GetByID(parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load parent organization: %w", err)
+	}
+	ancestors, err := s.ancestors(parent)
+	if err != nil {
+		return nil, err
+	}
+	if len(ancestors)+1 > maxSubOrganizationDepth {
+		return nil, fmt.Errorf("%w: sub-organizations can be nested at most %d levels deep", ErrInvalidSubOrganization, maxSubOrganizationDepth)
+	}
+	existing, err := s.orgRepo.GetByDomain(domainName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check organization domain: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: domain %s is already in use", ErrInvalidSubOrganization, domainName)
+	}
+
+	child := &domain.Organization{
+		Name:                    name,
+		Domain:                  domainName,
+		PlanType:                parent.PlanType,
+		MaxAgents:               parent.MaxAgents,
+		MaxUsers:                parent.MaxUsers,
+		IsActive:                true,
+		Settings:                map[string]interface{}{},
+		ClientSideKeyGeneration: parent.ClientSideKeyGeneration,
+		MFARequiredRoles:        parent.MFARequiredRoles,
+		ParentID:                &parent.ID,
+		DataResidency:           parent.DataResidency,
+	}
+	if req.MaxAgents > 0 {
+		child.MaxAgents = req.MaxAgents
+	}
+	if req.MaxUsers > 0 {
+		child.MaxUsers = req.MaxUsers
+	}
+	if err := s.orgRepo.Create(child); err != nil {
+		return nil, fmt.Errorf("failed to create sub-organization: %w", err)
+	}
+	return child, nil
+}
+
+// GetHierarchy returns the organization's parents and the tree of its sub-organizations
+func (s *OrganizationHierarchyService) GetHierarchy(ctx context.Context, orgID uuid.UUID) (*domain.OrganizationHierarchy, error) {
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load organization: %w", err)
+	}
+	ancestors, err := s.ancestors(org)
+	if err != nil {
+		return nil, err
+	}
+
+	hierarchy := &domain.OrganizationHierarchy{Ancestors: make([]*domain.OrganizationNode, 0, len(ancestors))}
+	for _, ancestor := range ancestors {
+		hierarchy.Ancestors = append(hierarchy.Ancestors, organizationNode(ancestor))
+	}
+	if hierarchy.Organization, err = s.tree(org, map[uuid.UUID]bool{}); err != nil {
+		return nil, err
+	}
+	return hierarchy, nil
+}
+
+// tree returns the organization with its sub-organizations, skipping any it already visited
+func (s *OrganizationHierarchyService) tree(org *domain.Organization, visited map[uuid.UUID]bool) (*domain.OrganizationNode, error) {
+	visited[org.ID] = true
+	node := organizationNode(org)
+	children, err := s.orgRepo.GetChildren(org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sub-organizations: %w", err)
+	}
+	for _, child := range children {
+		if visited[child.ID] {
+			continue
+		}
+		childNode, err := s.tree(child, visited)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+	return node, nil
+}
+
+func organizationNode(org *domain.Organization) *domain.OrganizationNode {
+	return &domain.OrganizationNode{
+		ID:       org.ID,
+		Name:     org.Name,
+		Domain:   org.Domain,
+		IsActive: org.IsActive,
+		Children: []*domain.OrganizationNode{},
+	}
+}
+
+// CanView reports whether the viewer may see the target organization's resources: the target
+// is the viewer itself or one of its sub-organizations
+func (s *OrganizationHierarchyService) CanView(ctx context.Context, viewerOrgID, targetOrgID uuid.UUID) (bool, error) {
+	if viewerOrgID == targetOrgID {
+		return true, nil
+	}
+	target, err := s.orgRepo.GetByID(targetOrgID)
+	if err != nil {
+		return false, nil
+	}
+	ancestors, err := s.ancestors(target)
+	if err != nil {
+		return false, err
+	}
+	for _, ancestor := range ancestors {
+		if ancestor.ID == viewerOrgID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListAgents returns the agents of the organization or one of its sub-organizations
+func (s *OrganizationHierarchyService) ListAgents(ctx context.Context, viewerOrgID, orgID uuid.UUID) ([]*domain.Agent, error) {
+	allowed, err := s.CanView(ctx, viewerOrgID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrOrganizationNotInHierarchy
+	}
+	agents, err := s.agentRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agents: %w", err)
+	}
+	return agents, nil
+}
+
+// SecurityRollup returns the security metrics of the organization and each of its
+// sub-organizations, with totals across all of them. Counts add up, the average trust score is
+// weighted by agents, and the security score is the lowest of the organizations: a hierarchy is
+// as secure as its weakest business unit.
+func (s *OrganizationHierarchyService) SecurityRollup(ctx context.Context, orgID uuid.UUID) (*domain.OrganizationSecurityRollup, error) {
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load organization: %w", err)
+	}
+	members, err := s.descendants(org)
+	if err != nil {
+		return nil, err
+	}
+
+	rollup := &domain.OrganizationSecurityRollup{
+		OrganizationID: orgID,
+		Totals:         &domain.SecurityMetrics{SecurityScore: 100},
+		Organizations:  make([]*domain.OrganizationSecurityMetrics, 0, len(members)),
+	}
+	severities := make(map[string]int)
+	trend := make(map[string]int)
+	var trustSum float64
+	for _, member := range members {
+		metrics, err := s.securityRepo.GetSecurityMetrics(member.org.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load security metrics of organization %s: %w", member.org.ID, err)
+		}
+		agents, err := s.agentRepo.GetByOrganization(member.org.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load agents of organization %s: %w", member.org.ID, err)
+		}
+		rollup.Organizations = append(rollup.Organizations, &domain.OrganizationSecurityMetrics{
+			OrganizationID: member.org.ID,
+			Name:           member.org.Name,
+			Depth:          member.depth,
+			AgentCount:     len(agents),
+			Metrics:        metrics,
+		})
+
+		totals := rollup.Totals
+		totals.TotalThreats += metrics.TotalThreats
+		totals.ActiveThreats += metrics.ActiveThreats
+		totals.BlockedThreats += metrics.BlockedThreats
+		totals.TotalAnomalies += metrics.TotalAnomalies
+		totals.HighSeverityCount += metrics.HighSeverityCount
+		totals.OpenIncidents += metrics.OpenIncidents
+		totals.SecurityScore = min(totals.SecurityScore, metrics.SecurityScore)
+		trustSum += metrics.AverageTrustScore * float64(len(agents))
+		rollup.AgentCount += len(agents)
+		for _, severity := range metrics.SeverityDistribution {
+			severities[severity.Severity] += severity.Count
+		}
+		for _, day := range metrics.ThreatTrend {
+			trend[day.Date] += day.Count
+		}
+	}
+
+	if rollup.AgentCount > 0 {
+		rollup.Totals.AverageTrustScore = trustSum / float64(rollup.AgentCount)
+	}
+	rollup.Totals.SeverityDistribution = make([]domain.SeverityDistribution, 0, len(severities))
+	for severity, count := range severities {
+		rollup.Totals.SeverityDistribution = append(rollup.Totals.SeverityDistribution, domain.SeverityDistribution{Severity: severity, Count: count})
+	}
+	sort.Slice(rollup.Totals.SeverityDistribution, func(i, j int) bool {
+		return rollup.Totals.SeverityDistribution[i].Severity < rollup.Totals.SeverityDistribution[j].Severity
+	})
+	rollup.Totals.ThreatTrend = make([]domain.ThreatTrendData, 0, len(trend))
+	for date, count := range trend {
+		rollup.Totals.ThreatTrend = append(rollup.Totals.ThreatTrend, domain.ThreatTrendData{Date: date, Count: count})
+	}
+	sort.Slice(rollup.Totals.ThreatTrend, func(i, j int) bool {
+		return rollup.Totals.ThreatTrend[i].Date < rollup.Totals.ThreatTrend[j].Date
+	})
+	return rollup, nil
+}
+
+// ancestors returns the organization's parents, nearest first
+func (s *OrganizationHierarchyService) ancestors(org *domain.Organization) ([]*domain.Organization, error) {
+	ancestors := make([]*domain.Organization, 0)
+	visited := map[uuid.UUID]bool{org.ID: true}
+	for parentID := org.ParentID; parentID != nil && !visited[*parentID]; {
+		parent, err := s.orgRepo.GetByID(*parentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load parent organization %s: %w", *parentID, err)
+		}
+		visited[parent.ID] = true
+		ancestors = append(ancestors, parent)
+		parentID = parent.ParentID
+	}
+	return ancestors, nil
+}
+
+// hierarchyMember is an organization at a depth below the organization a walk started from
+type hierarchyMember struct {
+	org   *domain.Organization
+	depth int
+}
+
+// descendants returns the organization followed by all of its sub-organizations, level by level
+func (s *OrganizationHierarchyService) descendants(org *domain.Organization) ([]hierarchyMember, error) {
+	members := []hierarchyMember{{org: org}}
+	visited := map[uuid.UUID]bool{org.ID: true}
+	for i := 0; i < len(members); i++ {
+		children, err := s.orgRepo.GetChildren(members[i].org.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load sub-organizations: %w", err)
+		}
+		for _, child := range children {
+			if visited[child.ID] {
+				continue
+			}
+			visited[child.ID] = true
+			members = append(members, hierarchyMember{org: child, depth: members[i].depth + 1})
+		}
+	}
+	return members, nil
+}
+
+// ancestorsOf returns the parents of the organization, nearest first
+func (s *OrganizationHierarchyService) ancestorsOf(orgID uuid.UUID) ([]*domain.Organization, error) {
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load organization: %w", err)
+	}
+	return s.ancestors(org)
+}
+
+// SecurityPolicyRepository wraps a security policy repository so the enabled policies of an
+// organization also include those of all of its ancestors: a parent's policies govern the
+// agents of its business units, which can add policies but not opt out of inherited ones
+func (s *OrganizationHierarchyService) SecurityPolicyRepository(policyRepo domain.SecurityPolicyRepository) domain.SecurityPolicyRepository {
+	return &inheritedSecurityPolicyRepository{SecurityPolicyRepository: policyRepo, hierarchy: s}
+}
+
+type inheritedSecurityPolicyRepository struct {
+	domain.SecurityPolicyRepository
+	hierarchy *OrganizationHierarchyService
+}
+
+func (r *inheritedSecurityPolicyRepository) GetActiveByOrganization(orgID uuid.UUID) ([]*domain.SecurityPolicy, error) {
+	return r.withInherited(orgID, r.SecurityPolicyRepository.GetActiveByOrganization)
+}
+
+func (r *inheritedSecurityPolicyRepository) GetByType(orgID uuid.UUID, policyType domain.PolicyType) ([]*domain.SecurityPolicy, error) {
+	return r.withInherited(orgID, func(id uuid.UUID) ([]*domain.SecurityPolicy, error) {
+		return r.SecurityPolicyRepository.GetByType(id, policyType)
+	})
+}
+
+// withInherited returns the organization's policies and its ancestors', highest priority first
+func (r *inheritedSecurityPolicyRepository) withInherited(
+	orgID uuid.UUID,
+	get func(uuid.UUID) ([]*domain.SecurityPolicy, error),
+) ([]*domain.SecurityPolicy, error) {
+	policies, err := get(orgID)
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := r.hierarchy.ancestorsOf(orgID)
+	if err != nil || len(ancestors) == 0 {
+		// Policies of an organization that can't be placed in a hierarchy are its own
+		return policies, nil
+	}
+	for _, ancestor := range ancestors {
+		inherited, err := get(ancestor.ID)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, inherited...)
+	}
+	sort.SliceStable(policies, func(i, j int) bool {
+		return policies[i].Priority > policies[j].Priority
+	})
+	return policies, nil
+}
+
+// TrustBoundaryRepository wraps a trust boundary repository so organizations without their own
+// policy get the policy of their nearest ancestor that has one, marked with InheritedFrom
+func (s *OrganizationHierarchyService) TrustBoundaryRepository(boundaryRepo domain.TrustBoundaryRepository) domain.TrustBoundaryRepository {
+	return &inheritedTrustBoundaryRepository{TrustBoundaryRepository: boundaryRepo, hierarchy: s}
+}
+
+type inheritedTrustBoundaryRepository struct {
+	domain.TrustBoundaryRepository
+	hierarchy *OrganizationHierarchyService
+}
+
+func (r *inheritedTrustBoundaryRepository) GetPolicy(orgID uuid.UUID) (*domain.TrustBoundaryPolicy, error) {
+	policy, err := r.TrustBoundaryRepository.GetPolicy(orgID)
+	if err != nil || policy != nil {
+		return policy, err
+	}
+	ancestors, err := r.hierarchy.ancestorsOf(orgID)
+	if err != nil {
+		return nil, nil
+	}
+	for _, ancestor := range ancestors {
+		policy, err := r.TrustBoundaryRepository.GetPolicy(ancestor.ID)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			inherited := *policy
+			inherited.ID = uuid.Nil
+			inherited.OrganizationID = orgID
+			inherited.InheritedFrom = &ancestor.ID
+			return &inherited, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetEnabledPolicies also returns the inherited policy of every sub-organization that takes an
+// enabled policy from an ancestor, so evaluation covers the agents of business units
+func (r *inheritedTrustBoundaryRepository) GetEnabledPolicies() ([]*domain.TrustBoundaryPolicy, error) {
+	policies, err := r.TrustBoundaryRepository.GetEnabledPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	effective := make([]*domain.TrustBoundaryPolicy, 0, len(policies))
+	for _, policy := range policies {
+		effective = append(effective, policy)
+		org, err := r.hierarchy.orgRepo.GetByID(policy.OrganizationID)
+		if err != nil {
+			continue
+		}
+		members, err := r.hierarchy.descendants(org)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members[1:] {
+			inherited, err := r.GetPolicy(member.org.ID)
+			if err != nil {
+				return nil, err
+			}
+			if inherited != nil && inherited.InheritedFrom != nil && *inherited.InheritedFrom == policy.OrganizationID {
+				effective = append(effective, inherited)
+			}
+		}
+	}
+	return effective, nil
+}
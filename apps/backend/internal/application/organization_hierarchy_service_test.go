@@ -0,0 +1,149 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubOrganizationsInheritPoliciesAndOnlyParentsSeeDown(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	root := testsupport.NewOrganization(func(o *domain.Organization) { o.MFARequiredRoles = []domain.UserRole{domain.RoleAdmin} })
+	require.NoError(t, repos.Organization.Create(root))
+	admin := testsupport.NewUser(root.ID)
+	require.NoError(t, repos.User.Create(admin))
+
+	hierarchy := NewOrganizationHierarchyService(repos.Organization, repos.Agent, repos.Security)
+	ctx := context.Background()
+
+	payments, err := hierarchy.CreateSubOrganization(ctx, root.ID, &CreateSubOrganizationRequest{Name: "Payments", Domain: "Payments.Example.com", MaxAgents: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "payments.example.com", payments.Domain)
+	assert.Equal(t, root.ID, *payments.ParentID)
+	assert.Equal(t, 10, payments.MaxAgents)
+	assert.Equal(t, root.MaxUsers, payments.MaxUsers)
+	assert.Equal(t, root.MFARequiredRoles, payments.MFARequiredRoles)
+	retail, err := hierarchy.CreateSubOrganization(ctx, root.ID, &CreateSubOrganizationRequest{Name: "Retail", Domain: "retail.example.com"})
+	require.NoError(t, err)
+	cards, err := hierarchy.CreateSubOrganization(ctx, payments.ID, &CreateSubOrganizationRequest{Name: "Cards", Domain: "cards.example.com"})
+	require.NoError(t, err)
+
+	_, err = hierarchy.CreateSubOrganization(ctx, root.ID, &CreateSubOrganizationRequest{Name: "Again", Domain: "payments.example.com"})
+	assert.ErrorIs(t, err, ErrInvalidSubOrganization)
+	_, err = hierarchy.CreateSubOrganization(ctx, root.ID, &CreateSubOrganizationRequest{Domain: "nameless.example.com"})
+	assert.ErrorIs(t, err, ErrInvalidSubOrganization)
+	parent := cards
+	for depth := 3; depth <= 4; depth++ {
+		parent, err = hierarchy.CreateSubOrganization(ctx, parent.ID, &CreateSubOrganizationRequest{Name: "Level", Domain: fmt.Sprintf("level%d.example.com", depth)})
+		require.NoError(t, err)
+	}
+	_, err = hierarchy.CreateSubOrganization(ctx, parent.ID, &CreateSubOrganizationRequest{Name: "Too deep", Domain: "level5.example.com"})
+	assert.ErrorIs(t, err, ErrInvalidSubOrganization)
+
+	// The root sees the whole tree; a business unit sees its parents and children, never its siblings
+	tree, err := hierarchy.GetHierarchy(ctx, root.ID)
+	require.NoError(t, err)
+	assert.Empty(t, tree.Ancestors)
+	require.Len(t, tree.Organization.Children, 2)
+	assert.Equal(t, payments.ID, tree.Organization.Children[0].ID)
+	assert.Equal(t, cards.ID, tree.Organization.Children[0].Children[0].ID)
+	tree, err = hierarchy.GetHierarchy(ctx, payments.ID)
+	require.NoError(t, err)
+	require.Len(t, tree.Ancestors, 1)
+	assert.Equal(t, root.ID, tree.Ancestors[0].ID)
+	require.Len(t, tree.Organization.Children, 1)
+	assert.Equal(t, cards.ID, tree.Organization.Children[0].ID)
+
+	cardAgent := testsupport.NewAgent(cards.ID, func(a *domain.Agent) { a.TrustScore = 0.2 })
+	retailAgent := testsupport.NewAgent(retail.ID, func(a *domain.Agent) { a.TrustScore = 0.8 })
+	require.NoError(t, repos.Agent.Create(cardAgent))
+	require.NoError(t, repos.Agent.Create(retailAgent))
+
+	agents, err := hierarchy.ListAgents(ctx, root.ID, cards.ID)
+	require.NoError(t, err)
+	require.Len(t, agents, 1)
+	assert.Equal(t, cardAgent.ID, agents[0].ID)
+	_, err = hierarchy.ListAgents(ctx, payments.ID, cards.ID)
+	require.NoError(t, err)
+	_, err = hierarchy.ListAgents(ctx, payments.ID, retail.ID)
+	assert.ErrorIs(t, err, ErrOrganizationNotInHierarchy)
+	_, err = hierarchy.ListAgents(ctx, cards.ID, root.ID)
+	assert.ErrorIs(t, err, ErrOrganizationNotInHierarchy)
+
+	// Enabled security policies of every ancestor apply to a business unit's agents
+	for _, policy := range []*domain.SecurityPolicy{
+		{OrganizationID: root.ID, Name: "root", PolicyType: domain.PolicyTypeSensitiveTool, IsEnabled: true, Priority: 10},
+		{OrganizationID: payments.ID, Name: "payments", PolicyType: domain.PolicyTypeSensitiveTool, IsEnabled: true, Priority: 50},
+		{OrganizationID: retail.ID, Name: "retail", PolicyType: domain.PolicyTypeSensitiveTool, IsEnabled: true, Priority: 90},
+	} {
+		policy.ID = uuid.New()
+		require.NoError(t, repos.SecurityPolicy.Create(policy))
+	}
+	policies, err := hierarchy.SecurityPolicyRepository(repos.SecurityPolicy).GetByType(cards.ID, domain.PolicyTypeSensitiveTool)
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.Equal(t, "payments", policies[0].Name)
+	assert.Equal(t, "root", policies[1].Name)
+	policies, err = hierarchy.SecurityPolicyRepository(repos.SecurityPolicy).GetByType(root.ID, domain.PolicyTypeSensitiveTool)
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+
+	// Business units without trust boundaries of their own use the nearest ancestor's
+	boundaries := NewTrustBoundaryService(hierarchy.TrustBoundaryRepository(repos.TrustBoundary), repos.Agent, repos.APIKey, repos.Security,
+		NewAuditService(repos.AuditLog))
+	_, err = boundaries.UpdatePolicy(ctx, &TrustBoundaryPolicyRequest{
+		Enabled: true, FloorScore: 0.3, FloorSuspend: true, CeilingScore: 0.9, CeilingSustainedDays: 14, KeyExtensionDays: 30,
+	}, root.ID, admin.ID)
+	require.NoError(t, err)
+	_, err = boundaries.UpdatePolicy(ctx, &TrustBoundaryPolicyRequest{
+		FloorScore: 0.3, CeilingScore: 0.9, CeilingSustainedDays: 14, KeyExtensionDays: 30,
+	}, retail.ID, admin.ID)
+	require.NoError(t, err)
+	inherited, err := boundaries.GetPolicy(ctx, cards.ID)
+	require.NoError(t, err)
+	require.NotNil(t, inherited.InheritedFrom)
+	assert.Equal(t, root.ID, *inherited.InheritedFrom)
+	assert.True(t, inherited.Enabled)
+	own, err := boundaries.GetPolicy(ctx, retail.ID)
+	require.NoError(t, err)
+	assert.Nil(t, own.InheritedFrom)
+	assert.False(t, own.Enabled)
+
+	_, err = boundaries.EvaluateAll(ctx)
+	require.NoError(t, err)
+	suspended, err := repos.Agent.GetByID(cardAgent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AgentStatusSuspended, suspended.Status)
+
+	// Security metrics roll up across the hierarchy
+	for _, orgID := range []uuid.UUID{payments.ID, retail.ID, retail.ID} {
+		require.NoError(t, repos.Alert.Create(&domain.Alert{
+			ID: uuid.New(), OrganizationID: orgID, AlertType: domain.AlertTypeConfigurationDrift, Severity: domain.AlertSeverityHigh,
+			Title: "alert", ResourceType: "agent", ResourceID: uuid.New(), CreatedAt: time.Now(),
+		}))
+	}
+	rollup, err := hierarchy.SecurityRollup(ctx, root.ID)
+	require.NoError(t, err)
+	require.Len(t, rollup.Organizations, 6)
+	assert.Equal(t, 3, rollup.Totals.TotalThreats)
+	assert.Equal(t, 3, rollup.Totals.HighSeverityCount)
+	assert.Equal(t, 2, rollup.AgentCount)
+	assert.InDelta(t, 0.5, rollup.Totals.AverageTrustScore, 0.001)
+	lowest := 100.0
+	for _, org := range rollup.Organizations {
+		lowest = min(lowest, org.Metrics.SecurityScore)
+	}
+	assert.Equal(t, lowest, rollup.Totals.SecurityScore)
+	assert.Less(t, rollup.Totals.SecurityScore, 100.0)
+
+	rollup, err = hierarchy.SecurityRollup(ctx, payments.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rollup.Totals.TotalThreats, "siblings are not rolled up")
+}
@@ -0,0 +1,190 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// DefaultPersonalAccessTokenDays is how long a personal access token lasts when no expiry is given
+	DefaultPersonalAccessTokenDays = 30
+	// MaxPersonalAccessTokenDays caps how long a personal access token can last
+	MaxPersonalAccessTokenDays = 365
+	// personalAccessTokenUsageInterval throttles last-used tracking: a token used again from the
+	// same address within it is not recorded again
+	personalAccessTokenUsageInterval = time.Minute
+)
+
+var (
+	// ErrInvalidPersonalAccessToken is returned for token requests without a name or scopes, with
+	// an unknown scope, or with an expiry out of range
+	ErrInvalidPersonalAccessToken = errors.New("invalid personal access token request")
+	// ErrPersonalAccessTokenRejected is returned when authenticating with an unknown, revoked or
+	// expired token, or the token of a user who can no longer sign in
+	ErrPersonalAccessTokenRejected = errors.New("invalid or expired personal access token")
+)
+
+// CreatePersonalAccessTokenRequest names a new personal access token and what it may do
+type CreatePersonalAccessTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expiresInDays"` // Defaults to 30, at most 365
+}
+
+// PersonalAccessTokenPrincipal is who a personal access token authenticates
+type PersonalAccessTokenPrincipal struct {
+	Token *domain.PersonalAccessToken
+	User  *domain.User
+}
+
+// PersonalAccessTokenService manages the scoped tokens users create to script against the API
+type PersonalAccessTokenService struct {
+	tokenRepo domain.PersonalAccessTokenRepository
+	userRepo  domain.UserRepository
+	now       func() time.Time
+}
+
+// NewPersonalAccessTokenService creates a new personal access token service
+func NewPersonalAccessTokenService(tokenRepo domain.PersonalAccessTokenRepository, userRepo domain.UserRepository) *PersonalAccessTokenService {
+	return &PersonalAccessTokenService{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Scopes lists the scopes personal access tokens can be created with
+func (s *PersonalAccessTokenService) Scopes() []string {
+	return domain.PersonalAccessTokenScopes
+}
+
+// CreateToken creates a personal access token for the user. The token is returned once, with
+// its record; only its hash is stored.
+func (s *PersonalAccessTokenService) CreateToken(ctx context.Context, orgID, userID uuid.UUID, req *CreatePersonalAccessTokenRequest) (string, *domain.PersonalAccessToken, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 255 {
+		return "", nil, fmt.Errorf("%w: name is required and must be at most 255 characters", ErrInvalidPersonalAccessToken)
+	}
+	if len(req.Scopes) == 0 {
+		return "", nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidPersonalAccessToken)
+	}
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !domain.IsValidPersonalAccessTokenScope(scope) {
+			return "", nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidPersonalAccessToken, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = DefaultPersonalAccessTokenDays
+	}
+	if days < 0 || days > MaxPersonalAccessTokenDays {
+		return "", nil, fmt.Errorf("%w: expiresInDays must be between 1 and %d", ErrInvalidPersonalAccessToken, MaxPersonalAccessTokenDays)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate personal access token: %w", err)
+	}
+	fullToken := domain.PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	now := s.now()
+	token := &domain.PersonalAccessToken{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		UserID:         userID,
+		Name:           name,
+		TokenHash:      hashPersonalAccessToken(fullToken),
+		Prefix:         fullToken[:len(domain.PersonalAccessTokenPrefix)+8],
+		Scopes:         scopes,
+		ExpiresAt:      now.AddDate(0, 0, days),
+		CreatedAt:      now,
+	}
+	if err := s.tokenRepo.Create(token); err != nil {
+		return "", nil, err
+	}
+	return fullToken, token, nil
+}
+
+// ListUserTokens lists the user's tokens, newest first, revoked and expired ones included
+func (s *PersonalAccessTokenService) ListUserTokens(ctx context.Context, userID uuid.UUID) ([]*domain.PersonalAccessToken, error) {
+	return s.tokenRepo.ListByUser(userID)
+}
+
+// ListOrganizationTokens lists the tokens of every user of the organization, newest first
+func (s *PersonalAccessTokenService) ListOrganizationTokens(ctx context.Context, orgID uuid.UUID) ([]*domain.PersonalAccessToken, error) {
+	return s.tokenRepo.ListByOrganization(orgID)
+}
+
+// RevokeUserToken revokes one of the user's own tokens
+func (s *PersonalAccessTokenService) RevokeUserToken(ctx context.Context, userID, tokenID uuid.UUID, reason string) error {
+	token, err := s.tokenRepo.GetByID(tokenID)
+	if err != nil || token.UserID != userID {
+		return domain.ErrPersonalAccessTokenNotFound
+	}
+	return s.tokenRepo.Revoke(tokenID, userID, reason)
+}
+
+// RevokeToken revokes any token of the organization; admins use it to cut off a user's automation
+func (s *PersonalAccessTokenService) RevokeToken(ctx context.Context, orgID, tokenID, revokedBy uuid.UUID, reason string) (*domain.PersonalAccessToken, error) {
+	token, err := s.tokenRepo.GetByID(tokenID)
+	if err != nil || token.OrganizationID != orgID {
+		return nil, domain.ErrPersonalAccessTokenNotFound
+	}
+	if err := s.tokenRepo.Revoke(tokenID, revokedBy, reason); err != nil {
+		return nil, err
+	}
+	return s.tokenRepo.GetByID(tokenID)
+}
+
+// Authenticate resolves a personal access token to its user and records where it was used from.
+// Tokens stop working when they are revoked or expire, and when their user is no longer active.
+func (s *PersonalAccessTokenService) Authenticate(ctx context.Context, fullToken, ipAddress string) (*PersonalAccessTokenPrincipal, error) {
+	if !strings.HasPrefix(fullToken, domain.PersonalAccessTokenPrefix) {
+		return nil, ErrPersonalAccessTokenRejected
+	}
+	token, err := s.tokenRepo.GetByHash(hashPersonalAccessToken(fullToken))
+	if err != nil {
+		return nil, ErrPersonalAccessTokenRejected
+	}
+
+	now := s.now()
+	if !token.IsActive(now) {
+		return nil, ErrPersonalAccessTokenRejected
+	}
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil || user == nil || user.Status != domain.UserStatusActive || user.OrganizationID != token.OrganizationID {
+		return nil, ErrPersonalAccessTokenRejected
+	}
+
+	recentlyRecorded := token.LastUsedAt != nil && now.Sub(*token.LastUsedAt) < personalAccessTokenUsageInterval &&
+		token.LastUsedIP != nil && *token.LastUsedIP == ipAddress
+	if !recentlyRecorded {
+		if err := s.tokenRepo.RecordUsage(token.ID, ipAddress, now); err == nil {
+			token.LastUsedAt = &now
+			token.LastUsedIP = &ipAddress
+		}
+	}
+
+	return &PersonalAccessTokenPrincipal{Token: token, User: user}, nil
+}
+
+// hashPersonalAccessToken hashes a token the way API keys are hashed
+func hashPersonalAccessToken(fullToken string) string {
+	hash := sha256.Sum256([]byte(fullToken))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
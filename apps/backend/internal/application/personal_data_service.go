@@ -0,0 +1,303 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrDataSubjectNotFound is returned when the user whose data is exported or erased is not in the organization
+	ErrDataSubjectNotFound = errors.New("user not found in organization")
+	// ErrDataErasureRequestDecided is returned when an erasure request was already rejected or completed
+	ErrDataErasureRequestDecided = errors.New("data erasure request was already decided")
+	// ErrDataErasureSelfApproval is returned when the data subject or the requester tries to approve the request
+	ErrDataErasureSelfApproval = errors.New("an erasure request must be approved by an admin other than the user and the requester")
+	// ErrDataErasureLastAdmin is returned when erasure would leave the organization without an active admin
+	ErrDataErasureLastAdmin = errors.New("the organization's last active admin cannot be erased")
+	// ErrUserAlreadyErased is returned when the user's data was already erased
+	ErrUserAlreadyErased = errors.New("the user's personal data was already erased")
+)
+
+// auditExportPageSize is how many audit entries are read at a time for a data export
+const auditExportPageSize = 500
+
+// PersonalDataService answers data subject requests: it exports the personal data held about a
+// user, and erases it once an admin approved an erasure request. Erasure anonymizes rather than
+// deletes, so the audit trail and verification history keep their integrity.
+type PersonalDataService struct {
+	userRepo         domain.UserRepository
+	sdkTokenRepo     domain.SDKTokenRepository
+	personalTokens   domain.PersonalAccessTokenRepository
+	auditRepo        domain.AuditLogRepository
+	approvalRepo     domain.ApprovalRequestRepository
+	erasureRepo      domain.DataErasureRequestRepository
+	personalDataRepo domain.PersonalDataRepository
+}
+
+// NewPersonalDataService creates a new personal data service
+func NewPersonalDataService(
+	userRepo domain.UserRepository,
+	sdkTokenRepo domain.SDKTokenRepository,
+	personalTokens domain.PersonalAccessTokenRepository,
+	auditRepo domain.AuditLogRepository,
+	approvalRepo domain.ApprovalRequestRepository,
+	erasureRepo domain.DataErasureRequestRepository,
+	personalDataRepo domain.PersonalDataRepository,
+) *PersonalDataService {
+	return &PersonalDataService{
+		userRepo:         userRepo,
+		sdkTokenRepo:     sdkTokenRepo,
+		personalTokens:   personalTokens,
+		auditRepo:        auditRepo,
+		approvalRepo:     approvalRepo,
+		erasureRepo:      erasureRepo,
+		personalDataRepo: personalDataRepo,
+	}
+}
+
+// ExportUserData bundles the personal data held about a user of the organization
+func (s *PersonalDataService) ExportUserData(ctx context.Context, orgID, userID uuid.UUID) (*domain.UserDataExport, error) {
+	user, err := s.subject(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &domain.UserDataExport{ExportedAt: time.Now().UTC(), Profile: user}
+	if export.SDKTokens, err = s.sdkTokenRepo.GetByUserID(userID, true); err != nil {
+		return nil, fmt.Errorf("failed to get SDK tokens: %w", err)
+	}
+	if export.PersonalAccessTokens, err = s.personalTokens.ListByUser(userID); err != nil {
+		return nil, fmt.Errorf("failed to get personal access tokens: %w", err)
+	}
+	export.AuditEntries = make([]*domain.AuditLog, 0)
+	for offset := 0; ; offset += auditExportPageSize {
+		entries, err := s.auditRepo.GetByUser(userID, auditExportPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get audit entries: %w", err)
+		}
+		export.AuditEntries = append(export.AuditEntries, entries...)
+		if len(entries) < auditExportPageSize {
+			break
+		}
+	}
+	if export.Approvals, err = s.approvalRepo.GetByUser(orgID, userID); err != nil {
+		return nil, fmt.Errorf("failed to get approvals: %w", err)
+	}
+	if export.ErasureRequests, err = s.erasureRepo.ListByUser(userID); err != nil {
+		return nil, fmt.Errorf("failed to get erasure requests: %w", err)
+	}
+	return export, nil
+}
+
+// RequestErasure files a request to erase a user's personal data; it waits for an admin's approval
+func (s *PersonalDataService) RequestErasure(ctx context.Context, orgID, userID, requestedBy uuid.UUID, reason string) (*domain.DataErasureRequest, error) {
+	user, err := s.subject(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Email == erasedAlias(user.ID) {
+		return nil, ErrUserAlreadyErased
+	}
+
+	existing, err := s.erasureRepo.ListByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get erasure requests: %w", err)
+	}
+	for _, request := range existing {
+		if request.Status == domain.DataErasureStatusPending || request.Status == domain.DataErasureStatusApproved {
+			return nil, domain.ErrDataErasureRequestOpen
+		}
+	}
+
+	request := &domain.DataErasureRequest{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		UserID:         userID,
+		RequestedBy:    requestedBy,
+		Reason:         reason,
+		Status:         domain.DataErasureStatusPending,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.erasureRepo.Create(request); err != nil {
+		return nil, fmt.Errorf("failed to create erasure request: %w", err)
+	}
+	return request, nil
+}
+
+// ListErasureRequests returns the organization's erasure requests, newest first; an empty status lists all
+func (s *PersonalDataService) ListErasureRequests(ctx context.Context, orgID uuid.UUID, status domain.DataErasureStatus, limit, offset int) ([]*domain.DataErasureRequest, error) {
+	requests, err := s.erasureRepo.ListByOrganization(orgID, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list erasure requests: %w", err)
+	}
+	return requests, nil
+}
+
+// GetErasureRequest returns an erasure request of the organization, with its report once completed
+func (s *PersonalDataService) GetErasureRequest(ctx context.Context, orgID, id uuid.UUID) (*domain.DataErasureRequest, error) {
+	request, err := s.erasureRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.OrganizationID != orgID {
+		return nil, domain.ErrDataErasureRequestNotFound
+	}
+	return request, nil
+}
+
+// ApproveErasure approves a pending request and erases the user's personal data. A request that
+// was approved but did not complete, because erasure failed, is erased again.
+func (s *PersonalDataService) ApproveErasure(ctx context.Context, orgID, id, adminID uuid.UUID, reason string) (*domain.DataErasureRequest, error) {
+	request, err := s.GetErasureRequest(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if adminID == request.UserID || adminID == request.RequestedBy {
+		return nil, ErrDataErasureSelfApproval
+	}
+
+	switch request.Status {
+	case domain.DataErasureStatusPending:
+		if err := s.checkNotLastAdmin(request); err != nil {
+			return nil, err
+		}
+		request.Status = domain.DataErasureStatusApproved
+		if err := s.decide(request, adminID, reason); err != nil {
+			return nil, err
+		}
+	case domain.DataErasureStatusApproved:
+	default:
+		return nil, ErrDataErasureRequestDecided
+	}
+
+	report, err := s.erase(request, adminID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	request.Status = domain.DataErasureStatusCompleted
+	request.Report = report
+	request.CompletedAt = &now
+	if err := s.erasureRepo.Complete(request); err != nil {
+		return nil, fmt.Errorf("failed to store erasure report: %w", err)
+	}
+	return request, nil
+}
+
+// RejectErasure rejects a pending request; nothing is erased
+func (s *PersonalDataService) RejectErasure(ctx context.Context, orgID, id, adminID uuid.UUID, reason string) (*domain.DataErasureRequest, error) {
+	request, err := s.GetErasureRequest(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != domain.DataErasureStatusPending {
+		return nil, ErrDataErasureRequestDecided
+	}
+	request.Status = domain.DataErasureStatusRejected
+	if err := s.decide(request, adminID, reason); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// erase revokes the user's tokens and anonymizes the user and the records referring to them
+func (s *PersonalDataService) erase(request *domain.DataErasureRequest, adminID uuid.UUID) (*domain.DataErasureReport, error) {
+	user, err := s.userRepo.GetByID(request.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	revoked := 0
+	sdkTokens, err := s.sdkTokenRepo.GetByUserID(user.ID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SDK tokens: %w", err)
+	}
+	if len(sdkTokens) > 0 {
+		if err := s.sdkTokenRepo.RevokeAllForUser(user.ID, "personal data erased"); err != nil {
+			return nil, fmt.Errorf("failed to revoke SDK tokens: %w", err)
+		}
+		revoked += len(sdkTokens)
+	}
+	personalTokens, err := s.personalTokens.ListByUser(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get personal access tokens: %w", err)
+	}
+	for _, token := range personalTokens {
+		if token.RevokedAt != nil {
+			continue
+		}
+		if err := s.personalTokens.Revoke(token.ID, adminID, "personal data erased"); err != nil {
+			return nil, fmt.Errorf("failed to revoke personal access token: %w", err)
+		}
+		revoked++
+	}
+
+	alias := erasedAlias(user.ID)
+	anonymized, err := s.personalDataRepo.Anonymize(user, alias)
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize personal data: %w", err)
+	}
+	return &domain.DataErasureReport{
+		UserID:            user.ID,
+		Alias:             alias,
+		RevokedTokens:     revoked,
+		AnonymizedRecords: anonymized,
+		CompletedAt:       time.Now().UTC(),
+	}, nil
+}
+
+// checkNotLastAdmin refuses to erase the organization's only active admin
+func (s *PersonalDataService) checkNotLastAdmin(request *domain.DataErasureRequest) error {
+	user, err := s.subject(request.OrganizationID, request.UserID)
+	if err != nil {
+		return err
+	}
+	if user.Role != domain.RoleAdmin || user.Status != domain.UserStatusActive {
+		return nil
+	}
+	active, err := s.userRepo.GetByOrganizationAndStatus(request.OrganizationID, domain.UserStatusActive)
+	if err != nil {
+		return fmt.Errorf("failed to get active users: %w", err)
+	}
+	for _, other := range active {
+		if other.ID != user.ID && other.Role == domain.RoleAdmin {
+			return nil
+		}
+	}
+	return ErrDataErasureLastAdmin
+}
+
+// decide records the decision; only one of concurrent decisions wins
+func (s *PersonalDataService) decide(request *domain.DataErasureRequest, adminID uuid.UUID, reason string) error {
+	now := time.Now()
+	request.DecidedBy = &adminID
+	request.DecidedAt = &now
+	request.DecisionReason = reason
+	decided, err := s.erasureRepo.Decide(request)
+	if err != nil {
+		return fmt.Errorf("failed to record erasure decision: %w", err)
+	}
+	if !decided {
+		return ErrDataErasureRequestDecided
+	}
+	return nil
+}
+
+// subject returns the user of the organization whose data is exported or erased
+func (s *PersonalDataService) subject(orgID, userID uuid.UUID) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || user == nil || user.OrganizationID != orgID {
+		return nil, ErrDataSubjectNotFound
+	}
+	return user, nil
+}
+
+// erasedAlias is the address that replaces the email of an erased user; it stays unique per user
+func erasedAlias(userID uuid.UUID) string {
+	return fmt.Sprintf("erased-%s@erased.invalid", userID)
+}
@@ -0,0 +1,143 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonalDataIsExportedAndErasedAfterAnotherAdminApproves(t *testing.T) {
+	ctx := context.Background()
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	admin := testsupport.NewUser(org.ID, func(u *domain.User) { u.Role = domain.RoleAdmin })
+	approver := testsupport.NewUser(org.ID, func(u *domain.User) { u.Role = domain.RoleAdmin })
+	subject := testsupport.NewUser(org.ID)
+	for _, user := range []*domain.User{admin, approver, subject} {
+		require.NoError(t, repos.User.Create(user))
+	}
+	agent := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(agent))
+
+	ip, userAgent, device := "203.0.113.7", "aim-sdk/1.0", "laptop"
+	require.NoError(t, repos.SDKToken.Create(&domain.SDKToken{
+		UserID: subject.ID, OrganizationID: org.ID, TokenHash: "hash", TokenID: "sdk-1",
+		DeviceName: &device, IPAddress: &ip, UserAgent: &userAgent, ExpiresAt: time.Now().Add(time.Hour),
+	}))
+	require.NoError(t, repos.PersonalAccessToken.Create(&domain.PersonalAccessToken{
+		OrganizationID: org.ID, UserID: subject.ID, Name: "ci", TokenHash: "hash", Prefix: "aimpat_ab",
+		LastUsedIP: &ip, ExpiresAt: time.Now().Add(time.Hour),
+	}))
+	require.NoError(t, repos.AuditLog.Create(&domain.AuditLog{
+		OrganizationID: org.ID, UserID: subject.ID, Action: domain.AuditActionCreate, ResourceType: "agent",
+		ResourceID: agent.ID, IPAddress: ip, UserAgent: userAgent,
+	}))
+	require.NoError(t, repos.AuditLog.Create(&domain.AuditLog{
+		OrganizationID: org.ID, UserID: admin.ID, Action: domain.AuditActionUpdate, ResourceType: "user",
+		ResourceID: subject.ID, IPAddress: "198.51.100.1",
+		Metadata: map[string]interface{}{"email": subject.Email, "name": subject.Name, "role": "member"},
+	}))
+	name := subject.Name
+	require.NoError(t, repos.VerificationEvent.Create(testsupport.NewVerificationEvent(agent, func(e *domain.VerificationEvent) {
+		e.InitiatorType, e.InitiatorID, e.InitiatorName = domain.InitiatorTypeUser, &subject.ID, &name
+		e.InitiatorIP, e.InitiatorUserAgent = &ip, &userAgent
+	})))
+	require.NoError(t, repos.ApprovalRequest.Create(&domain.ApprovalRequest{
+		OrganizationID: org.ID, Action: domain.ApprovalActionAgentVerification, ResourceType: "agent",
+		ResourceID: agent.ID, Status: domain.ApprovalRequestStatusApproved, RequestedBy: &admin.ID,
+		Decisions: []domain.ApprovalDecision{{UserID: subject.ID, UserEmail: subject.Email, Role: domain.RoleMember, Approved: true}},
+	}))
+	require.NoError(t, repos.Tombstone.Create(&domain.Tombstone{
+		OrganizationID: org.ID, EntityType: domain.TombstoneEntityAgent, EntityID: uuid.New(), Name: "old-agent",
+		DeletedBy: &subject.ID, DeletedByEmail: subject.Email,
+	}))
+	require.NoError(t, repos.Invitation.Create(&domain.Invitation{
+		OrganizationID: org.ID, Email: subject.Email, Role: domain.RoleMember, InvitedBy: admin.ID,
+		Status: domain.InvitationStatusAccepted, AcceptedBy: &subject.ID, ExpiresAt: time.Now(),
+	}))
+
+	service := NewPersonalDataService(repos.User, repos.SDKToken, repos.PersonalAccessToken, repos.AuditLog,
+		repos.ApprovalRequest, repos.DataErasureRequest, repos.PersonalData)
+
+	export, err := service.ExportUserData(ctx, org.ID, subject.ID)
+	require.NoError(t, err)
+	assert.Equal(t, subject.Email, export.Profile.Email)
+	assert.Len(t, export.SDKTokens, 1)
+	assert.Len(t, export.PersonalAccessTokens, 1)
+	assert.Len(t, export.AuditEntries, 1, "only the entries the user performed")
+	assert.Len(t, export.Approvals, 1, "approvals the user decided")
+	_, err = service.ExportUserData(ctx, uuid.New(), subject.ID)
+	assert.ErrorIs(t, err, ErrDataSubjectNotFound)
+
+	// The subject and the requester cannot approve; one request is open at a time
+	request, err := service.RequestErasure(ctx, org.ID, subject.ID, admin.ID, "user left the company")
+	require.NoError(t, err)
+	assert.Equal(t, domain.DataErasureStatusPending, request.Status)
+	_, err = service.RequestErasure(ctx, org.ID, subject.ID, subject.ID, "")
+	assert.ErrorIs(t, err, domain.ErrDataErasureRequestOpen)
+	for _, self := range []uuid.UUID{subject.ID, admin.ID} {
+		_, err = service.ApproveErasure(ctx, org.ID, request.ID, self, "")
+		assert.ErrorIs(t, err, ErrDataErasureSelfApproval)
+	}
+
+	completed, err := service.ApproveErasure(ctx, org.ID, request.ID, approver.ID, "verified identity")
+	require.NoError(t, err)
+	assert.Equal(t, domain.DataErasureStatusCompleted, completed.Status)
+	require.NotNil(t, completed.Report)
+	assert.Equal(t, 2, completed.Report.RevokedTokens)
+	assert.Equal(t, map[string]int{
+		"users": 1, "audit_logs": 2, "verification_events": 1, "approval_requests": 1,
+		"entity_tombstones": 1, "invitations": 1, "sdk_tokens": 1, "personal_access_tokens": 1,
+	}, completed.Report.AnonymizedRecords)
+	stored, err := service.GetErasureRequest(ctx, org.ID, request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, completed.Report, stored.Report)
+
+	// The user row stays as an anonymous placeholder and the records referring to it are scrubbed
+	erased, err := repos.User.GetByID(subject.ID)
+	require.NoError(t, err)
+	assert.Equal(t, completed.Report.Alias, erased.Email)
+	assert.Equal(t, domain.ErasedUserName, erased.Name)
+	assert.Equal(t, domain.UserStatusDeactivated, erased.Status)
+	tokens, err := repos.SDKToken.GetByUserID(subject.ID, false)
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+	logs, err := repos.AuditLog.GetByOrganization(org.ID, 10, 0)
+	require.NoError(t, err)
+	for _, log := range logs {
+		assert.NotContains(t, log.Metadata, subject.Email)
+		if log.UserID == subject.ID {
+			assert.Empty(t, log.IPAddress)
+			assert.Empty(t, log.UserAgent)
+		} else {
+			assert.Equal(t, map[string]interface{}{"email": erased.Email, "name": domain.ErasedUserName, "role": "member"}, log.Metadata)
+			assert.Equal(t, "198.51.100.1", log.IPAddress, "other users' entries keep their own details")
+		}
+	}
+	approvals, err := repos.ApprovalRequest.GetByUser(org.ID, subject.ID)
+	require.NoError(t, err)
+	require.Len(t, approvals, 1)
+	assert.Equal(t, erased.Email, approvals[0].Decisions[0].UserEmail)
+
+	_, err = service.RequestErasure(ctx, org.ID, subject.ID, admin.ID, "")
+	assert.ErrorIs(t, err, ErrUserAlreadyErased)
+	_, err = service.RejectErasure(ctx, org.ID, request.ID, approver.ID, "")
+	assert.ErrorIs(t, err, ErrDataErasureRequestDecided)
+
+	// The organization keeps an active admin
+	approverRequest, err := service.RequestErasure(ctx, org.ID, approver.ID, approver.ID, "")
+	require.NoError(t, err)
+	admin.Status = domain.UserStatusSuspended
+	require.NoError(t, repos.User.Update(admin))
+	_, err = service.ApproveErasure(ctx, org.ID, approverRequest.ID, admin.ID, "")
+	assert.ErrorIs(t, err, ErrDataErasureLastAdmin)
+	rejected, err := service.RejectErasure(ctx, org.ID, approverRequest.ID, admin.ID, "still needed")
+	require.NoError(t, err)
+	assert.Equal(t, domain.DataErasureStatusRejected, rejected.Status)
+}
@@ -0,0 +1,828 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// DefaultImpersonationMinutes is the session length when an impersonation request gives none
+	DefaultImpersonationMinutes = 60
+	// MaxImpersonationMinutes caps the session length of an impersonation
+	MaxImpersonationMinutes = 240
+	// MaxOperatorPageSize caps the page size of operator listings
+	MaxOperatorPageSize = 200
+	// organizationHealthMinVerifications is how many verifications the last 24 hours need before
+	// their success rate counts towards an organization's health
+	organizationHealthMinVerifications = 20
+	// operatorUsageInterval throttles last-used tracking of operator tokens
+	operatorUsageInterval = time.Minute
+)
+
+// Health of an organization in the operator overview
+const (
+	OrganizationHealthHealthy   = "healthy"
+	OrganizationHealthDegraded  = "degraded"
+	OrganizationHealthUnhealthy = "unhealthy"
+	OrganizationHealthInactive  = "inactive"
+)
+
+var (
+	// ErrOperatorTokenRejected is returned for unknown operator tokens and tokens of deactivated operators
+	ErrOperatorTokenRejected = errors.New("invalid operator token")
+	// ErrInvalidOperatorRequest is returned for invalid operator, throttle and impersonation requests
+	ErrInvalidOperatorRequest = errors.New("invalid operator request")
+	// ErrOperatorOrganizationNotFound is returned when an operator names an unknown organization
+	ErrOperatorOrganizationNotFound = errors.New("organization not found")
+	// ErrImpersonationConflict is returned when an impersonation request is not in a state that
+	// allows the change
+	ErrImpersonationConflict = errors.New("impersonation request cannot change")
+	// ErrImpersonationTokenRejected is returned for unknown, expired, ended and revoked sessions
+	ErrImpersonationTokenRejected = errors.New("invalid or expired impersonation session")
+)
+
+// OrganizationOverview is an organization as platform operators see it: its plan, usage and health
+type OrganizationOverview struct {
+	ID        uuid.UUID                    `json:"id"`
+	Name      string                       `json:"name"`
+	Domain    string                       `json:"domain"`
+	PlanType  string                       `json:"planType"`
+	IsActive  bool                         `json:"isActive"`
+	ParentID  *uuid.UUID                   `json:"parentId,omitempty"`
+	CreatedAt time.Time                    `json:"createdAt"`
+	Usage     OrganizationUsage            `json:"usage"`
+	Health    OrganizationHealth           `json:"health"`
+	Throttle  *domain.OrganizationThrottle `json:"throttle,omitempty"`
+}
+
+// OrganizationUsage counts what an organization uses of the platform
+type OrganizationUsage struct {
+	Users                  int `json:"users"`
+	MaxUsers               int `json:"maxUsers"`
+	ActiveUsers24h         int `json:"activeUsers24h"`
+	Agents                 int `json:"agents"`
+	MaxAgents              int `json:"maxAgents"`
+	VerifiedAgents         int `json:"verifiedAgents"`
+	SuspendedAgents        int `json:"suspendedAgents"`
+	MCPServers             int `json:"mcpServers"`
+	Verifications24h       int `json:"verifications24h"`
+	FailedVerifications24h int `json:"failedVerifications24h"`
+}
+
+// OrganizationHealth summarizes whether an organization needs the hosting team's attention
+type OrganizationHealth struct {
+	Status                  string   `json:"status"`            // healthy, degraded, unhealthy or inactive
+	Reasons                 []string `json:"reasons,omitempty"` // Why the organization is not healthy
+	OpenIncidents           int      `json:"openIncidents"`
+	UnacknowledgedAlerts    int      `json:"unacknowledgedAlerts"`
+	VerificationSuccessRate *float64 `json:"verificationSuccessRate,omitempty"` // Percent over the last 24 hours
+}
+
+// JobsOverview is the state of the background jobs on the instance answering the request. Only
+// the leader runs jobs, so other instances report no runs.
+type JobsOverview struct {
+	InstanceID string      `json:"instanceId"`
+	Leader     bool        `json:"leader"`
+	Jobs       []JobStatus `json:"jobs"`
+}
+
+// CreateOperatorRequest adds a platform operator
+type CreateOperatorRequest struct {
+	Email string              `json:"email"`
+	Name  string              `json:"name"`
+	Role  domain.OperatorRole `json:"role"`
+}
+
+// UpdateOperatorRequest changes an operator; empty fields keep their value
+type UpdateOperatorRequest struct {
+	Name        string              `json:"name"`
+	Role        domain.OperatorRole `json:"role"`
+	IsActive    *bool               `json:"isActive,omitempty"`
+	RotateToken bool                `json:"rotateToken"` // Issue a new token; the current one stops working
+}
+
+// ThrottleRequest caps an organization's rate limits
+type ThrottleRequest struct {
+	RequestsPerMinute int    `json:"requestsPerMinute"`
+	Reason            string `json:"reason"`
+	DurationMinutes   int    `json:"durationMinutes"` // 0 throttles until lifted
+}
+
+// ImpersonationRequestInput asks an organization's admins for consent to act in the organization
+type ImpersonationRequestInput struct {
+	OrganizationID  uuid.UUID       `json:"organizationId"`
+	Reason          string          `json:"reason"`
+	Role            domain.UserRole `json:"role"`            // Defaults to viewer
+	DurationMinutes int             `json:"durationMinutes"` // Defaults to 60, at most 240
+}
+
+// ImpersonationPrincipal is who an impersonation session authenticates: the approving admin,
+// with the role of the request
+type ImpersonationPrincipal struct {
+	Request *domain.ImpersonationRequest
+	User    *domain.User
+}
+
+// PlatformOperatorService backs the operator API the hosting team runs the deployment with:
+// operators and their log, cross-organization overviews, throttles, background jobs and
+// impersonation with an organization admin's consent
+type PlatformOperatorService struct {
+	repo         domain.PlatformOperatorRepository
+	orgRepo      domain.OrganizationRepository
+	userRepo     domain.UserRepository
+	agentRepo    domain.AgentRepository
+	mcpRepo      domain.MCPServerRepository
+	alertRepo    domain.AlertRepository
+	securityRepo domain.SecurityRepository
+	eventRepo    domain.VerificationEventRepository
+	rateLimits   *RateLimitService
+	scheduler    *JobScheduler
+	now          func() time.Time
+}
+
+// NewPlatformOperatorService creates a new platform operator service
+func NewPlatformOperatorService(
+	repo domain.PlatformOperatorRepository,
+	orgRepo domain.OrganizationRepository,
+	userRepo domain.UserRepository,
+	agentRepo domain.AgentRepository,
+	mcpRepo domain.MCPServerRepository,
+	alertRepo domain.AlertRepository,
+	securityRepo domain.SecurityRepository,
+	eventRepo domain.VerificationEventRepository,
+	rateLimits *RateLimitService,
+) *PlatformOperatorService {
+	return &PlatformOperatorService{
+		repo:         repo,
+		orgRepo:      orgRepo,
+		userRepo:     userRepo,
+		agentRepo:    agentRepo,
+		mcpRepo:      mcpRepo,
+		alertRepo:    alertRepo,
+		securityRepo: securityRepo,
+		eventRepo:    eventRepo,
+		rateLimits:   rateLimits,
+		now:          func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// UseScheduler sets the job scheduler operators inspect and control. It is created after the
+// services its jobs call.
+func (s *PlatformOperatorService) UseScheduler(scheduler *JobScheduler) {
+	s.scheduler = scheduler
+}
+
+// Bootstrap creates the first operator, an admin, with the given token when there are no
+// operators yet. Once operators exist the token is ignored; rotate or deactivate the bootstrap
+// operator after creating named operators.
+func (s *PlatformOperatorService) Bootstrap(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	if !strings.HasPrefix(token, domain.OperatorTokenPrefix) || len(token) < len(domain.OperatorTokenPrefix)+32 {
+		return fmt.Errorf("%w: the bootstrap token must start with %s and have at least 32 more characters",
+			ErrInvalidOperatorRequest, domain.OperatorTokenPrefix)
+	}
+
+	operators, err := s.repo.ListOperators()
+	if err != nil {
+		return err
+	}
+	if len(operators) > 0 {
+		return nil
+	}
+
+	operator := &domain.PlatformOperator{
+		Email:       "bootstrap@operators.local",
+		Name:        "Bootstrap operator",
+		Role:        domain.OperatorRoleAdmin,
+		TokenHash:   hashOperatorToken(token),
+		TokenPrefix: token[:len(domain.OperatorTokenPrefix)+8],
+		IsActive:    true,
+	}
+	if err := s.repo.CreateOperator(operator); err != nil {
+		return fmt.Errorf("failed to create bootstrap operator: %w", err)
+	}
+	log.Printf("✅ Bootstrap platform operator created; create named operators and deactivate it")
+	return nil
+}
+
+// Authenticate resolves an operator token to its active operator
+func (s *PlatformOperatorService) Authenticate(ctx context.Context, token string) (*domain.PlatformOperator, error) {
+	if !strings.HasPrefix(token, domain.OperatorTokenPrefix) {
+		return nil, ErrOperatorTokenRejected
+	}
+	operator, err := s.repo.GetOperatorByTokenHash(hashOperatorToken(token))
+	if err != nil || !operator.IsActive {
+		return nil, ErrOperatorTokenRejected
+	}
+
+	now := s.now()
+	if operator.LastUsedAt == nil || now.Sub(*operator.LastUsedAt) >= operatorUsageInterval {
+		if err := s.repo.TouchOperator(operator.ID, now); err == nil {
+			operator.LastUsedAt = &now
+		}
+	}
+	return operator, nil
+}
+
+// CreateOperator adds an operator and returns their token, which is not shown again
+func (s *PlatformOperatorService) CreateOperator(ctx context.Context, actor *domain.PlatformOperator, ip string, req *CreateOperatorRequest) (string, *domain.PlatformOperator, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if _, err := mail.ParseAddress(email); err != nil {
+		return "", nil, fmt.Errorf("%w: a valid email is required", ErrInvalidOperatorRequest)
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return "", nil, fmt.Errorf("%w: name is required", ErrInvalidOperatorRequest)
+	}
+	if !req.Role.IsValid() {
+		return "", nil, fmt.Errorf("%w: role must be viewer, support or admin", ErrInvalidOperatorRequest)
+	}
+
+	token, err := generateOperatorToken(domain.OperatorTokenPrefix)
+	if err != nil {
+		return "", nil, err
+	}
+	operator := &domain.PlatformOperator{
+		Email:       email,
+		Name:        strings.TrimSpace(req.Name),
+		Role:        req.Role,
+		TokenHash:   hashOperatorToken(token),
+		TokenPrefix: token[:len(domain.OperatorTokenPrefix)+8],
+		IsActive:    true,
+		CreatedBy:   &actor.ID,
+	}
+	if err := s.repo.CreateOperator(operator); err != nil {
+		return "", nil, fmt.Errorf("failed to create operator: %w", err)
+	}
+
+	s.record(actor, ip, "operator.create", nil, map[string]interface{}{
+		"operatorId": operator.ID, "email": operator.Email, "role": operator.Role,
+	})
+	return token, operator, nil
+}
+
+// ListOperators lists every operator, oldest first
+func (s *PlatformOperatorService) ListOperators(ctx context.Context) ([]*domain.PlatformOperator, error) {
+	return s.repo.ListOperators()
+}
+
+// UpdateOperator changes an operator's name, role or status, or rotates their token. The new
+// token, if any, is returned once. Operators cannot demote or deactivate themselves, so the
+// deployment always keeps an admin.
+func (s *PlatformOperatorService) UpdateOperator(ctx context.Context, actor *domain.PlatformOperator, ip string, id uuid.UUID, req *UpdateOperatorRequest) (string, *domain.PlatformOperator, error) {
+	operator, err := s.repo.GetOperator(id)
+	if err != nil {
+		return "", nil, err
+	}
+	if req.Role != "" && !req.Role.IsValid() {
+		return "", nil, fmt.Errorf("%w: role must be viewer, support or admin", ErrInvalidOperatorRequest)
+	}
+	if operator.ID == actor.ID && ((req.Role != "" && req.Role != operator.Role) || (req.IsActive != nil && !*req.IsActive)) {
+		return "", nil, fmt.Errorf("%w: operators cannot change their own role or deactivate themselves", ErrInvalidOperatorRequest)
+	}
+
+	details := map[string]interface{}{"operatorId": operator.ID}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		operator.Name = name
+	}
+	if req.Role != "" && req.Role != operator.Role {
+		details["role"] = req.Role
+		operator.Role = req.Role
+	}
+	if req.IsActive != nil && *req.IsActive != operator.IsActive {
+		details["isActive"] = *req.IsActive
+		operator.IsActive = *req.IsActive
+	}
+	var token string
+	if req.RotateToken {
+		if token, err = generateOperatorToken(domain.OperatorTokenPrefix); err != nil {
+			return "", nil, err
+		}
+		operator.TokenHash = hashOperatorToken(token)
+		operator.TokenPrefix = token[:len(domain.OperatorTokenPrefix)+8]
+		details["tokenRotated"] = true
+	}
+
+	if err := s.repo.UpdateOperator(operator); err != nil {
+		return "", nil, fmt.Errorf("failed to update operator: %w", err)
+	}
+	s.record(actor, ip, "operator.update", nil, details)
+	return token, operator, nil
+}
+
+// ListActions returns the operator log, newest first
+func (s *PlatformOperatorService) ListActions(ctx context.Context, limit, offset int) ([]*domain.OperatorAction, error) {
+	return s.repo.ListActions(operatorPageSize(limit), max(offset, 0))
+}
+
+// ListOrganizations returns a page of every organization's overview and the number of organizations
+func (s *PlatformOperatorService) ListOrganizations(ctx context.Context, limit, offset int) ([]*OrganizationOverview, int, error) {
+	orgs, err := s.orgRepo.List()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	limit, offset = operatorPageSize(limit), max(offset, 0)
+	total := len(orgs)
+	orgs = orgs[min(offset, total):min(offset+limit, total)]
+
+	overviews := make([]*OrganizationOverview, 0, len(orgs))
+	for _, org := range orgs {
+		overview, err := s.overview(org)
+		if err != nil {
+			return nil, 0, err
+		}
+		overviews = append(overviews, overview)
+	}
+	return overviews, total, nil
+}
+
+// GetOrganization returns one organization's overview
+func (s *PlatformOperatorService) GetOrganization(ctx context.Context, orgID uuid.UUID) (*OrganizationOverview, error) {
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil || org == nil {
+		return nil, ErrOperatorOrganizationNotFound
+	}
+	return s.overview(org)
+}
+
+// overview gathers the organization's usage and derives its health
+func (s *PlatformOperatorService) overview(org *domain.Organization) (*OrganizationOverview, error) {
+	overview := &OrganizationOverview{
+		ID:        org.ID,
+		Name:      org.Name,
+		Domain:    org.Domain,
+		PlanType:  org.PlanType,
+		IsActive:  org.IsActive,
+		ParentID:  org.ParentID,
+		CreatedAt: org.CreatedAt,
+		Usage:     OrganizationUsage{MaxUsers: org.MaxUsers, MaxAgents: org.MaxAgents},
+	}
+	usage := &overview.Usage
+
+	users, err := s.userRepo.GetByOrganization(org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users of organization %s: %w", org.ID, err)
+	}
+	usage.Users = len(users)
+	if usage.ActiveUsers24h, err = s.userRepo.CountActiveUsers(org.ID, 24*60); err != nil {
+		return nil, fmt.Errorf("failed to count active users of organization %s: %w", org.ID, err)
+	}
+
+	agents, err := s.agentRepo.GetByOrganization(org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count agents of organization %s: %w", org.ID, err)
+	}
+	usage.Agents = len(agents)
+	for _, agent := range agents {
+		switch agent.Status {
+		case domain.AgentStatusVerified:
+			usage.VerifiedAgents++
+		case domain.AgentStatusSuspended:
+			usage.SuspendedAgents++
+		}
+	}
+
+	servers, err := s.mcpRepo.GetByOrganization(org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count MCP servers of organization %s: %w", org.ID, err)
+	}
+	usage.MCPServers = len(servers)
+
+	now := s.now()
+	stats, err := s.eventRepo.GetStatistics(org.ID, now.Add(-24*time.Hour), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verifications of organization %s: %w", org.ID, err)
+	}
+	usage.Verifications24h = stats.TotalVerifications
+	usage.FailedVerifications24h = stats.FailedCount + stats.TimeoutCount
+
+	health := &overview.Health
+	if health.OpenIncidents, err = s.securityRepo.CountOpenIncidents(org.ID); err != nil {
+		return nil, fmt.Errorf("failed to count incidents of organization %s: %w", org.ID, err)
+	}
+	if health.UnacknowledgedAlerts, err = s.alertRepo.CountByOrganizationFiltered(org.ID, "unacknowledged"); err != nil {
+		return nil, fmt.Errorf("failed to count alerts of organization %s: %w", org.ID, err)
+	}
+	if throttle, err := s.repo.GetThrottle(org.ID); err == nil && throttle.IsActive(now) {
+		overview.Throttle = throttle
+	}
+
+	health.Status = OrganizationHealthHealthy
+	degraded := func(reason string) {
+		health.Reasons = append(health.Reasons, reason)
+		if health.Status == OrganizationHealthHealthy {
+			health.Status = OrganizationHealthDegraded
+		}
+	}
+	if decided := stats.SuccessCount + usage.FailedVerifications24h; decided > 0 {
+		rate := math.Round(float64(stats.SuccessCount)/float64(decided)*1000) / 10
+		health.VerificationSuccessRate = &rate
+		if decided >= organizationHealthMinVerifications && rate < 90 {
+			degraded(fmt.Sprintf("%.1f%% of verifications succeeded over the last 24 hours", rate))
+			if rate < 50 {
+				health.Status = OrganizationHealthUnhealthy
+			}
+		}
+	}
+	if health.OpenIncidents > 0 {
+		degraded(fmt.Sprintf("%d open security incidents", health.OpenIncidents))
+	}
+	if org.MaxAgents > 0 && usage.Agents > org.MaxAgents {
+		degraded(fmt.Sprintf("%d agents, over its limit of %d", usage.Agents, org.MaxAgents))
+	}
+	if overview.Throttle != nil {
+		degraded(fmt.Sprintf("throttled to %d requests per minute", overview.Throttle.RequestsPerMinute))
+	}
+	if !org.IsActive {
+		health.Status = OrganizationHealthInactive
+	}
+	return overview, nil
+}
+
+// SetThrottle caps every rate limit of the organization. It applies right away on this instance
+// and within a minute on the others.
+func (s *PlatformOperatorService) SetThrottle(ctx context.Context, actor *domain.PlatformOperator, ip string, orgID uuid.UUID, req *ThrottleRequest) (*domain.OrganizationThrottle, error) {
+	if org, err := s.orgRepo.GetByID(orgID); err != nil || org == nil {
+		return nil, ErrOperatorOrganizationNotFound
+	}
+	if req.RequestsPerMinute < 1 {
+		return nil, fmt.Errorf("%w: requestsPerMinute must be at least 1", ErrInvalidOperatorRequest)
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidOperatorRequest)
+	}
+	if req.DurationMinutes < 0 {
+		return nil, fmt.Errorf("%w: durationMinutes cannot be negative", ErrInvalidOperatorRequest)
+	}
+
+	throttle := &domain.OrganizationThrottle{
+		OrganizationID:    orgID,
+		RequestsPerMinute: req.RequestsPerMinute,
+		Reason:            strings.TrimSpace(req.Reason),
+		CreatedBy:         actor.ID,
+	}
+	if req.DurationMinutes > 0 {
+		expiresAt := s.now().Add(time.Duration(req.DurationMinutes) * time.Minute)
+		throttle.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.SetThrottle(throttle); err != nil {
+		return nil, fmt.Errorf("failed to throttle organization: %w", err)
+	}
+	if s.rateLimits != nil {
+		s.rateLimits.ForgetOrganization(orgID)
+	}
+
+	s.record(actor, ip, "throttle.set", &orgID, map[string]interface{}{
+		"requestsPerMinute": throttle.RequestsPerMinute, "reason": throttle.Reason, "expiresAt": throttle.ExpiresAt,
+	})
+	return throttle, nil
+}
+
+// RemoveThrottle lifts the organization's throttle
+func (s *PlatformOperatorService) RemoveThrottle(ctx context.Context, actor *domain.PlatformOperator, ip string, orgID uuid.UUID) error {
+	if _, err := s.repo.GetThrottle(orgID); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteThrottle(orgID); err != nil {
+		return fmt.Errorf("failed to lift throttle: %w", err)
+	}
+	if s.rateLimits != nil {
+		s.rateLimits.ForgetOrganization(orgID)
+	}
+
+	s.record(actor, ip, "throttle.remove", &orgID, nil)
+	return nil
+}
+
+// ListThrottles lists the throttles in force
+func (s *PlatformOperatorService) ListThrottles(ctx context.Context) ([]*domain.OrganizationThrottle, error) {
+	throttles, err := s.repo.ListThrottles()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	active := make([]*domain.OrganizationThrottle, 0, len(throttles))
+	for _, throttle := range throttles {
+		if throttle.IsActive(now) {
+			active = append(active, throttle)
+		}
+	}
+	return active, nil
+}
+
+// Jobs returns the state of the background jobs on this instance
+func (s *PlatformOperatorService) Jobs(ctx context.Context) *JobsOverview {
+	if s.scheduler == nil {
+		return &JobsOverview{Jobs: []JobStatus{}}
+	}
+	return &JobsOverview{
+		InstanceID: s.scheduler.InstanceID(),
+		Leader:     s.scheduler.IsLeader(),
+		Jobs:       s.scheduler.Statuses(),
+	}
+}
+
+// CancelJob cancels the job's run in progress on this instance
+func (s *PlatformOperatorService) CancelJob(ctx context.Context, actor *domain.PlatformOperator, ip, name string) error {
+	if s.scheduler == nil {
+		return ErrJobNotFound
+	}
+	if err := s.scheduler.CancelJob(name); err != nil {
+		return err
+	}
+	s.record(actor, ip, "job.cancel", nil, map[string]interface{}{"job": name})
+	return nil
+}
+
+// TriggerJob starts a run of the job now on this instance, which must be the leader
+func (s *PlatformOperatorService) TriggerJob(ctx context.Context, actor *domain.PlatformOperator, ip, name string) error {
+	if s.scheduler == nil {
+		return ErrJobNotFound
+	}
+	if err := s.scheduler.TriggerJob(name); err != nil {
+		return err
+	}
+	s.record(actor, ip, "job.trigger", nil, map[string]interface{}{"job": name})
+	return nil
+}
+
+// RequestImpersonation asks the organization's admins for consent to act in the organization.
+// They are alerted; nothing can be accessed until one of them approves.
+func (s *PlatformOperatorService) RequestImpersonation(ctx context.Context, actor *domain.PlatformOperator, ip string, req *ImpersonationRequestInput) (*domain.ImpersonationRequest, error) {
+	if org, err := s.orgRepo.GetByID(req.OrganizationID); err != nil || org == nil {
+		return nil, ErrOperatorOrganizationNotFound
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidOperatorRequest)
+	}
+	role := req.Role
+	if role == "" {
+		role = domain.RoleViewer
+	}
+	if role != domain.RoleViewer && role != domain.RoleMember && role != domain.RoleManager && role != domain.RoleAdmin {
+		return nil, fmt.Errorf("%w: role must be viewer, member, manager or admin", ErrInvalidOperatorRequest)
+	}
+	duration := req.DurationMinutes
+	if duration == 0 {
+		duration = DefaultImpersonationMinutes
+	}
+	if duration < 1 || duration > MaxImpersonationMinutes {
+		return nil, fmt.Errorf("%w: durationMinutes must be between 1 and %d", ErrInvalidOperatorRequest, MaxImpersonationMinutes)
+	}
+
+	request := &domain.ImpersonationRequest{
+		OrganizationID:  req.OrganizationID,
+		OperatorID:      actor.ID,
+		OperatorEmail:   actor.Email,
+		Reason:          strings.TrimSpace(req.Reason),
+		Role:            role,
+		DurationMinutes: duration,
+		Status:          domain.ImpersonationStatusPending,
+		CreatedAt:       s.now(),
+	}
+	if err := s.repo.CreateImpersonation(request); err != nil {
+		return nil, fmt.Errorf("failed to create impersonation request: %w", err)
+	}
+
+	alert := &domain.Alert{
+		OrganizationID: request.OrganizationID,
+		AlertType:      domain.AlertImpersonationRequested,
+		Severity:       domain.AlertSeverityWarning,
+		Title:          "Platform support requests access",
+		Description: fmt.Sprintf("%s asks to act as %s in your organization for %d minutes: %s. Nothing is accessed unless an admin approves the request.",
+			request.OperatorEmail, request.Role, request.DurationMinutes, request.Reason),
+		ResourceType: "impersonation_request",
+		ResourceID:   request.ID,
+	}
+	if err := s.alertRepo.Create(alert); err != nil {
+		log.Printf("⚠️  Failed to alert organization %s about impersonation request %s: %v", request.OrganizationID, request.ID, err)
+	}
+
+	s.record(actor, ip, "impersonation.request", &request.OrganizationID, map[string]interface{}{
+		"impersonationId": request.ID, "role": request.Role, "durationMinutes": request.DurationMinutes, "reason": request.Reason,
+	})
+	return request, nil
+}
+
+// ListImpersonations lists the impersonation requests of every organization, newest first
+func (s *PlatformOperatorService) ListImpersonations(ctx context.Context, limit, offset int) ([]*domain.ImpersonationRequest, error) {
+	return s.listImpersonations(nil, limit, offset)
+}
+
+// StartImpersonation starts the session of the operator's approved request and returns its
+// token, which is not shown again. The session acts as the approving admin with the requested
+// role until it expires, the operator ends it or an admin revokes it.
+func (s *PlatformOperatorService) StartImpersonation(ctx context.Context, actor *domain.PlatformOperator, ip string, id uuid.UUID) (string, *domain.ImpersonationRequest, error) {
+	request, err := s.repo.GetImpersonation(id)
+	if err != nil {
+		return "", nil, err
+	}
+	if request.OperatorID != actor.ID {
+		return "", nil, domain.ErrImpersonationNotFound
+	}
+	now := s.now()
+	if status := request.CurrentStatus(now); status != domain.ImpersonationStatusApproved {
+		return "", nil, fmt.Errorf("%w: the request is %s", ErrImpersonationConflict, status)
+	}
+	if _, err := s.consentingAdmin(request); err != nil {
+		return "", nil, fmt.Errorf("%w: the approving admin can no longer consent", ErrImpersonationConflict)
+	}
+
+	token, err := generateOperatorToken(domain.ImpersonationTokenPrefix)
+	if err != nil {
+		return "", nil, err
+	}
+	expiresAt := now.Add(time.Duration(request.DurationMinutes) * time.Minute)
+	request.Status = domain.ImpersonationStatusActive
+	request.TokenHash = hashOperatorToken(token)
+	request.StartedAt = &now
+	request.ExpiresAt = &expiresAt
+	if err := s.repo.UpdateImpersonation(request); err != nil {
+		return "", nil, fmt.Errorf("failed to start impersonation: %w", err)
+	}
+
+	s.record(actor, ip, "impersonation.start", &request.OrganizationID, map[string]interface{}{
+		"impersonationId": request.ID, "expiresAt": expiresAt,
+	})
+	return token, request, nil
+}
+
+// EndImpersonation ends the operator's session early
+func (s *PlatformOperatorService) EndImpersonation(ctx context.Context, actor *domain.PlatformOperator, ip string, id uuid.UUID) (*domain.ImpersonationRequest, error) {
+	request, err := s.repo.GetImpersonation(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.OperatorID != actor.ID {
+		return nil, domain.ErrImpersonationNotFound
+	}
+	now := s.now()
+	if status := request.CurrentStatus(now); status != domain.ImpersonationStatusActive {
+		return nil, fmt.Errorf("%w: the request is %s", ErrImpersonationConflict, status)
+	}
+
+	request.Status = domain.ImpersonationStatusEnded
+	request.EndedAt = &now
+	if err := s.repo.UpdateImpersonation(request); err != nil {
+		return nil, fmt.Errorf("failed to end impersonation: %w", err)
+	}
+	s.record(actor, ip, "impersonation.end", &request.OrganizationID, map[string]interface{}{"impersonationId": request.ID})
+	return request, nil
+}
+
+// ListOrganizationImpersonations lists the organization's impersonation requests, newest first
+func (s *PlatformOperatorService) ListOrganizationImpersonations(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*domain.ImpersonationRequest, error) {
+	return s.listImpersonations(&orgID, limit, offset)
+}
+
+// DecideImpersonation approves or denies a pending request of the organization. An approved
+// session acts as the admin who approved it.
+func (s *PlatformOperatorService) DecideImpersonation(ctx context.Context, orgID, id, adminID uuid.UUID, approve bool) (*domain.ImpersonationRequest, error) {
+	request, err := s.organizationImpersonation(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if status := request.CurrentStatus(now); status != domain.ImpersonationStatusPending {
+		return nil, fmt.Errorf("%w: the request is %s", ErrImpersonationConflict, status)
+	}
+
+	request.DecidedBy = &adminID
+	request.DecidedAt = &now
+	request.Status = domain.ImpersonationStatusApproved
+	if !approve {
+		request.Status = domain.ImpersonationStatusDenied
+		request.EndedAt = &now
+	}
+	if err := s.repo.UpdateImpersonation(request); err != nil {
+		return nil, fmt.Errorf("failed to decide impersonation request: %w", err)
+	}
+	return request, nil
+}
+
+// RevokeImpersonation withdraws the organization's consent to an approved or active request;
+// an active session stops working on its next request
+func (s *PlatformOperatorService) RevokeImpersonation(ctx context.Context, orgID, id uuid.UUID) (*domain.ImpersonationRequest, error) {
+	request, err := s.organizationImpersonation(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if status := request.CurrentStatus(now); status != domain.ImpersonationStatusApproved && status != domain.ImpersonationStatusActive {
+		return nil, fmt.Errorf("%w: the request is %s", ErrImpersonationConflict, status)
+	}
+
+	request.Status = domain.ImpersonationStatusRevoked
+	request.EndedAt = &now
+	if err := s.repo.UpdateImpersonation(request); err != nil {
+		return nil, fmt.Errorf("failed to revoke impersonation: %w", err)
+	}
+	return request, nil
+}
+
+// AuthenticateImpersonation resolves a session token to the approving admin, with the role of
+// the request. Sessions stop working when they expire, end or are revoked, and when the
+// approving admin is no longer an active admin of the organization.
+func (s *PlatformOperatorService) AuthenticateImpersonation(ctx context.Context, token string) (*ImpersonationPrincipal, error) {
+	if !strings.HasPrefix(token, domain.ImpersonationTokenPrefix) {
+		return nil, ErrImpersonationTokenRejected
+	}
+	request, err := s.repo.GetImpersonationByTokenHash(hashOperatorToken(token))
+	if err != nil || request.CurrentStatus(s.now()) != domain.ImpersonationStatusActive {
+		return nil, ErrImpersonationTokenRejected
+	}
+	user, err := s.consentingAdmin(request)
+	if err != nil {
+		return nil, ErrImpersonationTokenRejected
+	}
+	return &ImpersonationPrincipal{Request: request, User: user}, nil
+}
+
+// consentingAdmin returns the admin who approved the request, if they are still an active admin
+// of its organization
+func (s *PlatformOperatorService) consentingAdmin(request *domain.ImpersonationRequest) (*domain.User, error) {
+	if request.DecidedBy == nil {
+		return nil, ErrImpersonationTokenRejected
+	}
+	user, err := s.userRepo.GetByID(*request.DecidedBy)
+	if err != nil || user == nil || user.OrganizationID != request.OrganizationID ||
+		user.Role != domain.RoleAdmin || user.Status != domain.UserStatusActive {
+		return nil, ErrImpersonationTokenRejected
+	}
+	return user, nil
+}
+
+func (s *PlatformOperatorService) organizationImpersonation(orgID, id uuid.UUID) (*domain.ImpersonationRequest, error) {
+	request, err := s.repo.GetImpersonation(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.OrganizationID != orgID {
+		return nil, domain.ErrImpersonationNotFound
+	}
+	return request, nil
+}
+
+// listImpersonations lists requests with expiry applied to their status
+func (s *PlatformOperatorService) listImpersonations(orgID *uuid.UUID, limit, offset int) ([]*domain.ImpersonationRequest, error) {
+	requests, err := s.repo.ListImpersonations(orgID, operatorPageSize(limit), max(offset, 0))
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	for _, request := range requests {
+		request.Status = request.CurrentStatus(now)
+	}
+	return requests, nil
+}
+
+// record appends an entry to the operator log; failures are logged and do not undo the change
+func (s *PlatformOperatorService) record(actor *domain.PlatformOperator, ip, action string, orgID *uuid.UUID, details map[string]interface{}) {
+	entry := &domain.OperatorAction{
+		OperatorID:     actor.ID,
+		Action:         action,
+		OrganizationID: orgID,
+		Details:        details,
+		IPAddress:      ip,
+		CreatedAt:      s.now(),
+	}
+	if err := s.repo.RecordAction(entry); err != nil {
+		log.Printf("⚠️  Failed to record operator action %s by %s: %v", action, actor.Email, err)
+	}
+}
+
+func operatorPageSize(limit int) int {
+	if limit <= 0 {
+		return 50
+	}
+	return min(limit, MaxOperatorPageSize)
+}
+
+// generateOperatorToken returns a random token with the prefix
+func generateOperatorToken(prefix string) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func hashOperatorToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,592 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// defaultPlaybookSuppressMinutes is how long suppress_alerts drops alerts when the step does not say
+const defaultPlaybookSuppressMinutes = 60
+
+var (
+	// ErrInvalidPlaybook is returned when a playbook request is malformed
+	ErrInvalidPlaybook = errors.New("invalid playbook")
+	// ErrPlaybookIncidentNotFound is returned when the incident to run a playbook against does not exist
+	ErrPlaybookIncidentNotFound = errors.New("incident not found")
+	// ErrPlaybookNotAwaitingApproval is returned when approving or rejecting an execution that is not paused
+	ErrPlaybookNotAwaitingApproval = errors.New("playbook execution is not awaiting approval")
+	// ErrPlaybookExecutionFinished is returned when cancelling an execution that already ended
+	ErrPlaybookExecutionFinished = errors.New("playbook execution already finished")
+)
+
+// PlaybookService manages containment playbooks and runs them against security incidents.
+// Steps run in order; a step requiring approval pauses the execution until a user decides.
+type PlaybookService struct {
+	playbookRepo        domain.PlaybookRepository
+	securityRepo        domain.SecurityRepository
+	agentService        *AgentService
+	suppressionService  *AlertSuppressionService
+	notificationService *NotificationService
+	tickets             domain.TicketCreator
+	now                 func() time.Time
+}
+
+// NewPlaybookService creates a new playbook service.
+// Optional dependencies may be nil; the steps that need them fail when they run.
+func NewPlaybookService(
+	playbookRepo domain.PlaybookRepository,
+	securityRepo domain.SecurityRepository,
+	agentService *AgentService,
+	suppressionService *AlertSuppressionService,
+	notificationService *NotificationService,
+	tickets domain.TicketCreator,
+) *PlaybookService {
+	return &PlaybookService{
+		playbookRepo:        playbookRepo,
+		securityRepo:        securityRepo,
+		agentService:        agentService,
+		suppressionService:  suppressionService,
+		notificationService: notificationService,
+		tickets:             tickets,
+		now:                 time.Now,
+	}
+}
+
+// PlaybookRequest represents the request to create or update a playbook
+type PlaybookRequest struct {
+	Name          string                `json:"name"`
+	Description   string                `json:"description"`
+	IncidentTypes []string              `json:"incidentTypes"`
+	MinSeverity   domain.AlertSeverity  `json:"minSeverity"`
+	Steps         []domain.PlaybookStep `json:"steps"`
+	IsEnabled     *bool                 `json:"isEnabled,omitempty"` // Defaults to true on create
+}
+
+// CreatePlaybook creates a new playbook
+func (s *PlaybookService) CreatePlaybook(ctx context.Context, req *PlaybookRequest, orgID, userID uuid.UUID) (*domain.Playbook, error) {
+	playbook := &domain.Playbook{
+		OrganizationID: orgID,
+		IsEnabled:      true,
+		CreatedBy:      userID,
+	}
+	if err := applyPlaybookRequest(playbook, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.playbookRepo.CreatePlaybook(playbook); err != nil {
+		return nil, fmt.Errorf("failed to create playbook: %w", err)
+	}
+	return playbook, nil
+}
+
+// ListPlaybooks lists an organization's playbooks
+func (s *PlaybookService) ListPlaybooks(ctx context.Context, orgID uuid.UUID) ([]*domain.Playbook, error) {
+	return s.playbookRepo.GetPlaybooksByOrganization(orgID)
+}
+
+// GetPlaybook retrieves a playbook of the organization
+func (s *PlaybookService) GetPlaybook(ctx context.Context, orgID, id uuid.UUID) (*domain.Playbook, error) {
+	playbook, err := s.playbookRepo.GetPlaybook(id)
+	if err != nil {
+		return nil, err
+	}
+	if playbook.OrganizationID != orgID {
+		return nil, domain.ErrPlaybookNotFound
+	}
+	return playbook, nil
+}
+
+// UpdatePlaybook replaces a playbook's definition; executions already started keep their steps
+func (s *PlaybookService) UpdatePlaybook(ctx context.Context, orgID, id uuid.UUID, req *PlaybookRequest) (*domain.Playbook, error) {
+	playbook, err := s.GetPlaybook(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPlaybookRequest(playbook, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.playbookRepo.UpdatePlaybook(playbook); err != nil {
+		return nil, fmt.Errorf("failed to update playbook: %w", err)
+	}
+	return playbook, nil
+}
+
+// DeletePlaybook deletes a playbook; its executions are kept
+func (s *PlaybookService) DeletePlaybook(ctx context.Context, orgID, id uuid.UUID) error {
+	if _, err := s.GetPlaybook(ctx, orgID, id); err != nil {
+		return err
+	}
+	return s.playbookRepo.DeletePlaybook(id)
+}
+
+// applyPlaybookRequest validates a request and copies it onto the playbook
+func applyPlaybookRequest(playbook *domain.Playbook, req *PlaybookRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidPlaybook)
+	}
+	if req.MinSeverity != "" && severityRank(req.MinSeverity) < 0 {
+		return fmt.Errorf("%w: minSeverity must be info, warning, high or critical", ErrInvalidPlaybook)
+	}
+	if len(req.Steps) == 0 {
+		return fmt.Errorf("%w: at least one step is required", ErrInvalidPlaybook)
+	}
+	for i, step := range req.Steps {
+		if !step.Action.IsValid() {
+			return fmt.Errorf("%w: step %d has unknown action %q", ErrInvalidPlaybook, i+1, step.Action)
+		}
+		if step.SuppressMinutes < 0 {
+			return fmt.Errorf("%w: step %d suppressMinutes must not be negative", ErrInvalidPlaybook, i+1)
+		}
+		if step.Action == domain.PlaybookActionCreateTicket {
+			switch step.TicketProvider {
+			case "jira":
+				if step.TicketProject == "" {
+					return fmt.Errorf("%w: step %d needs the Jira project key as ticketProject", ErrInvalidPlaybook, i+1)
+				}
+			case "servicenow":
+			default:
+				return fmt.Errorf("%w: step %d ticketProvider must be jira or servicenow", ErrInvalidPlaybook, i+1)
+			}
+		}
+	}
+
+	incidentTypes := make([]string, 0, len(req.IncidentTypes))
+	for _, incidentType := range req.IncidentTypes {
+		if incidentType = strings.TrimSpace(incidentType); incidentType != "" {
+			incidentTypes = append(incidentTypes, incidentType)
+		}
+	}
+
+	playbook.Name = strings.TrimSpace(req.Name)
+	playbook.Description = req.Description
+	playbook.IncidentTypes = incidentTypes
+	playbook.MinSeverity = req.MinSeverity
+	playbook.Steps = req.Steps
+	if req.IsEnabled != nil {
+		playbook.IsEnabled = *req.IsEnabled
+	}
+	return nil
+}
+
+// playbookMatches reports whether an incident starts the playbook
+func playbookMatches(playbook *domain.Playbook, incident *domain.SecurityIncident) bool {
+	if !playbook.IsEnabled {
+		return false
+	}
+	if playbook.MinSeverity != "" && severityRank(incident.Severity) < severityRank(playbook.MinSeverity) {
+		return false
+	}
+	if len(playbook.IncidentTypes) == 0 {
+		return true
+	}
+	for _, incidentType := range playbook.IncidentTypes {
+		if incidentType == incident.IncidentType {
+			return true
+		}
+	}
+	return false
+}
+
+// RunForIncident starts every enabled playbook of the incident's organization that matches it.
+// Failures are logged; a failing playbook does not keep the others from running.
+func (s *PlaybookService) RunForIncident(ctx context.Context, incident *domain.SecurityIncident) []*domain.PlaybookExecution {
+	playbooks, err := s.playbookRepo.GetPlaybooksByOrganization(incident.OrganizationID)
+	if err != nil {
+		log.Printf("⚠️  Failed to load playbooks for incident %s: %v", incident.ID, err)
+		return nil
+	}
+
+	var executions []*domain.PlaybookExecution
+	for _, playbook := range playbooks {
+		if !playbookMatches(playbook, incident) {
+			continue
+		}
+		execution, err := s.start(ctx, playbook, incident, nil)
+		if err != nil {
+			log.Printf("⚠️  Failed to run playbook %s for incident %s: %v", playbook.ID, incident.ID, err)
+			continue
+		}
+		executions = append(executions, execution)
+	}
+	return executions
+}
+
+// Run starts a playbook against an incident on a user's request, whether or not it matches
+func (s *PlaybookService) Run(ctx context.Context, orgID, playbookID, incidentID, userID uuid.UUID) (*domain.PlaybookExecution, error) {
+	playbook, err := s.GetPlaybook(ctx, orgID, playbookID)
+	if err != nil {
+		return nil, err
+	}
+	incident, err := s.securityRepo.GetIncidentByID(incidentID)
+	if err != nil || incident.OrganizationID != orgID {
+		return nil, ErrPlaybookIncidentNotFound
+	}
+	return s.start(ctx, playbook, incident, &userID)
+}
+
+// ListExecutions lists a page of the organization's executions, optionally of one playbook
+func (s *PlaybookService) ListExecutions(ctx context.Context, orgID uuid.UUID, playbookID *uuid.UUID, limit, offset int) ([]*domain.PlaybookExecution, int, error) {
+	return s.playbookRepo.GetExecutionsByOrganization(orgID, playbookID, limit, offset)
+}
+
+// GetExecution retrieves an execution of the organization with its log
+func (s *PlaybookService) GetExecution(ctx context.Context, orgID, id uuid.UUID) (*domain.PlaybookExecution, error) {
+	execution, err := s.playbookRepo.GetExecution(id)
+	if err != nil {
+		return nil, err
+	}
+	if execution.OrganizationID != orgID {
+		return nil, domain.ErrPlaybookExecutionNotFound
+	}
+	return execution, nil
+}
+
+// Approve runs the step the execution is waiting on and continues with the steps after it
+func (s *PlaybookService) Approve(ctx context.Context, orgID, executionID, userID uuid.UUID, comment string) (*domain.PlaybookExecution, error) {
+	execution, incident, err := s.pending(ctx, orgID, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	step := execution.CurrentStep
+	execution.Steps[step].DecidedBy = &userID
+	execution.Steps[step].Status = domain.PlaybookStepPending
+	s.logf(execution, &step, &userID, "Step approved%s", commentSuffix(comment))
+
+	return execution, s.advance(ctx, execution, incident, userID)
+}
+
+// Reject skips the step the execution is waiting on and continues with the steps after it
+func (s *PlaybookService) Reject(ctx context.Context, orgID, executionID, userID uuid.UUID, comment string) (*domain.PlaybookExecution, error) {
+	execution, incident, err := s.pending(ctx, orgID, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	step := execution.CurrentStep
+	now := s.now().UTC()
+	execution.Steps[step].DecidedBy = &userID
+	execution.Steps[step].Status = domain.PlaybookStepSkipped
+	execution.Steps[step].Detail = "Rejected by approver"
+	execution.Steps[step].CompletedAt = &now
+	s.logf(execution, &step, &userID, "Step rejected%s", commentSuffix(comment))
+	execution.CurrentStep++
+
+	return execution, s.advance(ctx, execution, incident, userID)
+}
+
+// Cancel stops an execution; the remaining steps are skipped
+func (s *PlaybookService) Cancel(ctx context.Context, orgID, executionID, userID uuid.UUID, comment string) (*domain.PlaybookExecution, error) {
+	execution, err := s.GetExecution(ctx, orgID, executionID)
+	if err != nil {
+		return nil, err
+	}
+	if execution.Status.IsFinished() {
+		return nil, ErrPlaybookExecutionFinished
+	}
+
+	now := s.now().UTC()
+	for i := execution.CurrentStep; i < len(execution.Steps); i++ {
+		execution.Steps[i].Status = domain.PlaybookStepSkipped
+		execution.Steps[i].Detail = "Execution cancelled"
+	}
+	execution.Status = domain.PlaybookExecutionCancelled
+	execution.CompletedAt = &now
+	s.logf(execution, nil, &userID, "Execution cancelled%s", commentSuffix(comment))
+
+	if err := s.playbookRepo.UpdateExecution(execution); err != nil {
+		return nil, fmt.Errorf("failed to update playbook execution: %w", err)
+	}
+	return execution, nil
+}
+
+// pending loads an execution that is waiting on approval together with its incident
+func (s *PlaybookService) pending(ctx context.Context, orgID, executionID uuid.UUID) (*domain.PlaybookExecution, *domain.SecurityIncident, error) {
+	execution, err := s.GetExecution(ctx, orgID, executionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if execution.Status != domain.PlaybookExecutionAwaitingApproval {
+		return nil, nil, ErrPlaybookNotAwaitingApproval
+	}
+	incident, err := s.securityRepo.GetIncidentByID(execution.IncidentID)
+	if err != nil {
+		return nil, nil, ErrPlaybookIncidentNotFound
+	}
+	return execution, incident, nil
+}
+
+// start creates an execution of the playbook and runs it up to the first approval gate
+func (s *PlaybookService) start(ctx context.Context, playbook *domain.Playbook, incident *domain.SecurityIncident, triggeredBy *uuid.UUID) (*domain.PlaybookExecution, error) {
+	now := s.now().UTC()
+	execution := &domain.PlaybookExecution{
+		ID:             uuid.New(),
+		OrganizationID: playbook.OrganizationID,
+		PlaybookID:     playbook.ID,
+		PlaybookName:   playbook.Name,
+		IncidentID:     incident.ID,
+		Status:         domain.PlaybookExecutionRunning,
+		Steps:          make([]domain.PlaybookStepResult, len(playbook.Steps)),
+		TriggeredBy:    triggeredBy,
+		StartedAt:      now,
+		UpdatedAt:      now,
+	}
+	for i, step := range playbook.Steps {
+		execution.Steps[i] = domain.PlaybookStepResult{Step: step, Status: domain.PlaybookStepPending}
+	}
+	if triggeredBy != nil {
+		s.logf(execution, nil, triggeredBy, "Started manually for incident %q", incident.Title)
+	} else {
+		s.logf(execution, nil, nil, "Started by %s incident %q", incident.IncidentType, incident.Title)
+	}
+
+	if err := s.playbookRepo.CreateExecution(execution); err != nil {
+		return nil, fmt.Errorf("failed to create playbook execution: %w", err)
+	}
+
+	// Automated actions are attributed to whoever started the run, else the playbook's author
+	actor := playbook.CreatedBy
+	if triggeredBy != nil {
+		actor = *triggeredBy
+	}
+	return execution, s.advance(ctx, execution, incident, actor)
+}
+
+// advance runs steps from the current one until the execution pauses for approval or ends,
+// then stores it
+func (s *PlaybookService) advance(ctx context.Context, execution *domain.PlaybookExecution, incident *domain.SecurityIncident, actor uuid.UUID) error {
+	execution.Status = domain.PlaybookExecutionRunning
+
+	for execution.CurrentStep < len(execution.Steps) {
+		index := execution.CurrentStep
+		result := &execution.Steps[index]
+
+		if result.Step.RequiresApproval && result.DecidedBy == nil {
+			result.Status = domain.PlaybookStepAwaitingApproval
+			execution.Status = domain.PlaybookExecutionAwaitingApproval
+			s.logf(execution, &index, nil, "Waiting for approval to %s", result.Step.Action)
+			break
+		}
+
+		startedAt := s.now().UTC()
+		count, detail, err := s.runStep(ctx, execution, incident, result.Step, actor)
+		completedAt := s.now().UTC()
+		result.Count = count
+		result.Detail = detail
+		result.StartedAt = &startedAt
+		result.CompletedAt = &completedAt
+		execution.CurrentStep++
+
+		if err != nil {
+			msg := err.Error()
+			result.Status = domain.PlaybookStepFailed
+			result.Error = &msg
+			s.logf(execution, &index, nil, "%s failed: %s", result.Step.Action, msg)
+			if !result.Step.ContinueOnFailure {
+				for i := execution.CurrentStep; i < len(execution.Steps); i++ {
+					execution.Steps[i].Status = domain.PlaybookStepSkipped
+					execution.Steps[i].Detail = "Skipped after an earlier step failed"
+				}
+				execution.Status = domain.PlaybookExecutionFailed
+				execution.CompletedAt = &completedAt
+				break
+			}
+			continue
+		}
+
+		result.Status = domain.PlaybookStepCompleted
+		s.logf(execution, &index, nil, "%s completed: %s", result.Step.Action, detail)
+	}
+
+	if execution.Status == domain.PlaybookExecutionRunning {
+		now := s.now().UTC()
+		execution.Status = domain.PlaybookExecutionCompleted
+		execution.CompletedAt = &now
+		s.logf(execution, nil, nil, "Execution completed")
+	}
+
+	if err := s.playbookRepo.UpdateExecution(execution); err != nil {
+		return fmt.Errorf("failed to update playbook execution: %w", err)
+	}
+	log.Printf("✅ Playbook %q on incident %s: %s", execution.PlaybookName, execution.IncidentID, execution.Status)
+	return nil
+}
+
+// runStep performs one action and returns how many resources it acted on and a summary
+func (s *PlaybookService) runStep(
+	ctx context.Context,
+	execution *domain.PlaybookExecution,
+	incident *domain.SecurityIncident,
+	step domain.PlaybookStep,
+	actor uuid.UUID,
+) (int, string, error) {
+	switch step.Action {
+	case domain.PlaybookActionQuarantineAgents:
+		return s.forEachAgent(ctx, incident, "Suspended", func(agent *domain.Agent) error {
+			if agent.Status == domain.AgentStatusSuspended {
+				return nil
+			}
+			return s.agentService.SuspendAgent(ctx, agent.ID)
+		})
+
+	case domain.PlaybookActionRotateKeys:
+		noGrace := 0
+		return s.forEachAgent(ctx, incident, "Rotated the keys of", func(agent *domain.Agent) error {
+			_, err := s.agentService.RotateKey(ctx, agent.ID, &RotateKeyRequest{GracePeriodMinutes: &noGrace})
+			return err
+		})
+
+	case domain.PlaybookActionSuppressAlerts:
+		if s.suppressionService == nil {
+			return 0, "", fmt.Errorf("alert suppression is not available on this server")
+		}
+		minutes := step.SuppressMinutes
+		if minutes == 0 {
+			minutes = defaultPlaybookSuppressMinutes
+		}
+		expiresAt := s.now().Add(time.Duration(minutes) * time.Minute)
+		return s.forEachAgent(ctx, incident, "Suppressed alerts about", func(agent *domain.Agent) error {
+			agentID := agent.ID
+			_, err := s.suppressionService.CreateRule(ctx, &AlertSuppressionRuleRequest{
+				Name:         fmt.Sprintf("Playbook %s: %s", execution.PlaybookName, agent.Name),
+				Description:  fmt.Sprintf("Containment of incident %s", incident.ID),
+				ResourceType: "agent",
+				ResourceID:   &agentID,
+				Action:       domain.AlertSuppressionDrop,
+				ExpiresAt:    expiresAt,
+			}, incident.OrganizationID, actor)
+			return err
+		})
+
+	case domain.PlaybookActionNotifyChannels:
+		if s.notificationService == nil {
+			return 0, "", fmt.Errorf("notifications are not available on this server")
+		}
+		incidentID := incident.ID
+		notification := &domain.Notification{
+			OrganizationID: incident.OrganizationID,
+			EventType:      "incident.playbook",
+			Severity:       incident.Severity,
+			Title:          fmt.Sprintf("Playbook %s running: %s", execution.PlaybookName, incident.Title),
+			Message:        incident.Description,
+			ResourceType:   "incident",
+			ResourceID:     &incidentID,
+			Payload: map[string]interface{}{
+				"executionId":       execution.ID.String(),
+				"playbookId":        execution.PlaybookID.String(),
+				"incidentType":      incident.IncidentType,
+				"affectedResources": incident.AffectedResources,
+			},
+		}
+		var err error
+		if len(step.ChannelIDs) > 0 {
+			notification, err = s.notificationService.DispatchToChannels(ctx, notification, step.ChannelIDs)
+		} else {
+			notification, err = s.notificationService.Dispatch(ctx, notification)
+		}
+		if err != nil {
+			return 0, "", err
+		}
+		return len(notification.Deliveries), fmt.Sprintf("Queued %d notification delivery(ies)", len(notification.Deliveries)), nil
+
+	case domain.PlaybookActionCreateTicket:
+		if s.tickets == nil {
+			return 0, "", fmt.Errorf("ticketing is not available on this server")
+		}
+		reference, err := s.tickets.CreateTicket(ctx, &domain.Ticket{
+			Provider: step.TicketProvider,
+			Project:  step.TicketProject,
+			Summary:  fmt.Sprintf("[AIM] %s", incident.Title),
+			Description: fmt.Sprintf("%s\n\nIncident: %s (%s, %s)\nAffected resources: %s\nPlaybook: %s (execution %s)",
+				incident.Description, incident.ID, incident.IncidentType, incident.Severity,
+				strings.Join(incident.AffectedResources, ", "), execution.PlaybookName, execution.ID),
+			Severity: incident.Severity,
+			Labels:   []string{"aim", "security-incident"},
+		})
+		if err != nil {
+			return 0, "", err
+		}
+		return 1, fmt.Sprintf("Created %s ticket %s: %s", reference.Provider, reference.Key, reference.URL), nil
+	}
+
+	return 0, "", fmt.Errorf("unknown action %q", step.Action)
+}
+
+// forEachAgent applies fn to every agent the incident affects and summarizes the outcome.
+// It stops at the first failure.
+func (s *PlaybookService) forEachAgent(ctx context.Context, incident *domain.SecurityIncident, verb string, fn func(agent *domain.Agent) error) (int, string, error) {
+	if s.agentService == nil {
+		return 0, "", fmt.Errorf("agent management is not available on this server")
+	}
+
+	done := 0
+	for _, resource := range incident.AffectedResources {
+		id, ok := strings.CutPrefix(resource, "agent:")
+		if !ok {
+			continue
+		}
+		agentID, err := uuid.Parse(id)
+		if err != nil {
+			continue
+		}
+		agent, err := s.agentService.GetAgent(ctx, agentID)
+		if err != nil || agent.OrganizationID != incident.OrganizationID {
+			continue
+		}
+		if err := fn(agent); err != nil {
+			return done, fmt.Sprintf("%s %d agent(s) before failing on %s", verb, done, agent.Name), err
+		}
+		done++
+	}
+
+	return done, fmt.Sprintf("%s %d agent(s)", verb, done), nil
+}
+
+// logf appends a line to the execution's log
+func (s *PlaybookService) logf(execution *domain.PlaybookExecution, step *int, userID *uuid.UUID, format string, args ...interface{}) {
+	entry := domain.PlaybookLogEntry{
+		Time:    s.now().UTC(),
+		UserID:  userID,
+		Message: fmt.Sprintf(format, args...),
+	}
+	if step != nil {
+		index := *step
+		entry.Step = &index
+	}
+	execution.Log = append(execution.Log, entry)
+	execution.UpdatedAt = entry.Time
+}
+
+func commentSuffix(comment string) string {
+	if comment = strings.TrimSpace(comment); comment != "" {
+		return ": " + comment
+	}
+	return ""
+}
+
+// SecurityRepository wraps a security repository so incidents that services open through it
+// (compromised agents, trust boundary violations, manual incidents) start matching playbooks
+func (s *PlaybookService) SecurityRepository(securityRepo domain.SecurityRepository) domain.SecurityRepository {
+	return &playbookTriggeringSecurityRepository{SecurityRepository: securityRepo, playbookService: s}
+}
+
+type playbookTriggeringSecurityRepository struct {
+	domain.SecurityRepository
+	playbookService *PlaybookService
+}
+
+func (r *playbookTriggeringSecurityRepository) CreateIncident(incident *domain.SecurityIncident) error {
+	if err := r.SecurityRepository.CreateIncident(incident); err != nil {
+		return err
+	}
+	r.playbookService.RunForIncident(context.Background(), incident)
+	return nil
+}
@@ -0,0 +1,162 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/ticketing"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaybooksContainIncidentsWithApprovalGates(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	admin := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(admin))
+	agent := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(agent))
+	bystander := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(bystander))
+	ctx := context.Background()
+
+	var issues []map[string]interface{}
+	jira := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/2/issue", r.URL.Path)
+		username, password, _ := r.BasicAuth()
+		assert.Equal(t, "soc@example.com:token", username+":"+password)
+		var issue map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&issue))
+		issues = append(issues, issue)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"10001","key":"SEC-42"}`))
+	}))
+	defer jira.Close()
+	tickets := ticketing.NewClient(ticketing.Config{JiraURL: jira.URL, JiraEmail: "soc@example.com", JiraAPIToken: "token"})
+
+	trustCalc := NewTrustCalculator(repos.TrustScore, repos.APIKey, repos.AuditLog, repos.Capability, repos.Agent, repos.Alert)
+	agentService := NewAgentService(repos.Agent, trustCalc, repos.TrustScore, nil, repos.Alert, nil, repos.Capability, nil, nil, repos.Organization, nil, nil, nil, nil)
+	suppression := NewAlertSuppressionService(repos.AlertSuppression, repos.Tag)
+	playbooks := NewPlaybookService(repos.Playbook, repos.Security, agentService, suppression, nil, tickets)
+	incidents := playbooks.SecurityRepository(repos.Security)
+
+	_, err := playbooks.CreatePlaybook(ctx, &PlaybookRequest{
+		Name:  "Broken",
+		Steps: []domain.PlaybookStep{{Action: "delete_everything"}},
+	}, org.ID, admin.ID)
+	assert.ErrorIs(t, err, ErrInvalidPlaybook)
+
+	playbook, err := playbooks.CreatePlaybook(ctx, &PlaybookRequest{
+		Name:          "Contain compromised agents",
+		IncidentTypes: []string{"agent_compromised"},
+		MinSeverity:   domain.AlertSeverityHigh,
+		Steps: []domain.PlaybookStep{
+			{Action: domain.PlaybookActionQuarantineAgents},
+			{Action: domain.PlaybookActionCreateTicket, RequiresApproval: true, TicketProvider: "jira", TicketProject: "SEC"},
+			{Action: domain.PlaybookActionSuppressAlerts, SuppressMinutes: 30},
+			{Action: domain.PlaybookActionNotifyChannels, ContinueOnFailure: true},
+		},
+	}, org.ID, admin.ID)
+	require.NoError(t, err)
+
+	openIncident := func(incidentType string, severity domain.AlertSeverity) *domain.SecurityIncident {
+		incident := &domain.SecurityIncident{
+			ID:                uuid.New(),
+			OrganizationID:    org.ID,
+			IncidentType:      incidentType,
+			Severity:          severity,
+			Title:             "Agent compromised: " + agent.Name,
+			AffectedResources: []string{"agent:" + agent.ID.String()},
+		}
+		require.NoError(t, incidents.CreateIncident(incident))
+		return incident
+	}
+
+	// Incidents of other types or below the minimum severity do not start the playbook
+	openIncident("trust_boundary_violation", domain.AlertSeverityCritical)
+	openIncident("agent_compromised", domain.AlertSeverityWarning)
+	_, total, err := playbooks.ListExecutions(ctx, org.ID, nil, 10, 0)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+
+	// A matching incident quarantines the agent right away and pauses at the approval gate
+	incident := openIncident("agent_compromised", domain.AlertSeverityCritical)
+	executions, total, err := playbooks.ListExecutions(ctx, org.ID, &playbook.ID, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	execution := executions[0]
+	assert.Equal(t, incident.ID, execution.IncidentID)
+	assert.Equal(t, domain.PlaybookExecutionAwaitingApproval, execution.Status)
+	assert.Equal(t, 1, execution.CurrentStep)
+	assert.Equal(t, domain.PlaybookStepCompleted, execution.Steps[0].Status)
+	assert.Equal(t, 1, execution.Steps[0].Count)
+	assert.Equal(t, domain.PlaybookStepAwaitingApproval, execution.Steps[1].Status)
+	assert.Empty(t, issues)
+
+	stored, err := repos.Agent.GetByID(agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AgentStatusSuspended, stored.Status)
+	untouched, err := repos.Agent.GetByID(bystander.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, domain.AgentStatusSuspended, untouched.Status)
+
+	_, err = playbooks.Cancel(ctx, uuid.New(), execution.ID, admin.ID, "")
+	assert.ErrorIs(t, err, domain.ErrPlaybookExecutionNotFound)
+
+	// Approval opens the ticket and runs the rest; a failing step marked continueOnFailure does
+	// not fail the run
+	execution, err = playbooks.Approve(ctx, org.ID, execution.ID, admin.ID, "confirmed by SOC")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PlaybookExecutionCompleted, execution.Status)
+	require.Len(t, issues, 1)
+	fields := issues[0]["fields"].(map[string]interface{})
+	assert.Equal(t, "SEC", fields["project"].(map[string]interface{})["key"])
+	assert.Contains(t, execution.Steps[1].Detail, jira.URL+"/browse/SEC-42")
+	assert.Equal(t, &admin.ID, execution.Steps[1].DecidedBy)
+	assert.Equal(t, domain.PlaybookStepCompleted, execution.Steps[2].Status)
+	assert.Equal(t, domain.PlaybookStepFailed, execution.Steps[3].Status)
+	assert.NotNil(t, execution.CompletedAt)
+
+	rules, err := suppression.ListRules(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, agent.ID, *rules[0].ResourceID)
+	assert.Equal(t, domain.AlertSuppressionDrop, rules[0].Action)
+
+	var messages []string
+	for _, entry := range execution.Log {
+		messages = append(messages, entry.Message)
+	}
+	assert.Contains(t, messages, "Step approved: confirmed by SOC")
+	assert.Contains(t, messages, "Execution completed")
+
+	_, err = playbooks.Approve(ctx, org.ID, execution.ID, admin.ID, "")
+	assert.ErrorIs(t, err, ErrPlaybookNotAwaitingApproval)
+
+	// A manual run can reject the gated step; the run skips it and carries on
+	execution, err = playbooks.Run(ctx, org.ID, playbook.ID, incident.ID, admin.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &admin.ID, execution.TriggeredBy)
+	execution, err = playbooks.Reject(ctx, org.ID, execution.ID, admin.ID, "ticket already open")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PlaybookStepSkipped, execution.Steps[1].Status)
+	assert.Equal(t, domain.PlaybookExecutionCompleted, execution.Status)
+	assert.Len(t, issues, 1)
+
+	// Cancelling skips the remaining steps
+	execution, err = playbooks.Run(ctx, org.ID, playbook.ID, incident.ID, admin.ID)
+	require.NoError(t, err)
+	execution, err = playbooks.Cancel(ctx, org.ID, execution.ID, admin.ID, "false positive")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PlaybookExecutionCancelled, execution.Status)
+	assert.Equal(t, domain.PlaybookStepSkipped, execution.Steps[3].Status)
+	_, err = playbooks.Cancel(ctx, org.ID, execution.ID, admin.ID, "")
+	assert.ErrorIs(t, err, ErrPlaybookExecutionFinished)
+}
@@ -0,0 +1,306 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// defaultPDPTimeoutMs bounds a decision request when none is configured
+	defaultPDPTimeoutMs = 2000
+	minPDPTimeoutMs     = 100
+	maxPDPTimeoutMs     = 30000
+)
+
+// PolicyDecisionService delegates authorization decisions to an organization's external
+// policy decision point (OPA) and records them next to the native policy result
+type PolicyDecisionService struct {
+	decisionRepo domain.PolicyDecisionRepository
+	evaluator    domain.ExternalPolicyEvaluator
+}
+
+// NewPolicyDecisionService creates a new policy decision service
+func NewPolicyDecisionService(
+	decisionRepo domain.PolicyDecisionRepository,
+	evaluator domain.ExternalPolicyEvaluator,
+) *PolicyDecisionService {
+	return &PolicyDecisionService{
+		decisionRepo: decisionRepo,
+		evaluator:    evaluator,
+	}
+}
+
+// PolicyDecisionPointRequest represents the request to configure an organization's external PDP
+type PolicyDecisionPointRequest struct {
+	Provider     domain.PolicyDecisionPointProvider `json:"provider"`
+	Endpoint     string                             `json:"endpoint"`
+	DecisionPath string                             `json:"decisionPath"`
+	AuthToken    *string                            `json:"authToken,omitempty"` // Omit to keep the current token, "" to clear it
+	Mode         domain.PolicyDecisionPointMode     `json:"mode"`
+	FailOpen     bool                               `json:"failOpen"`
+	TimeoutMs    int                                `json:"timeoutMs"`
+	IsEnabled    *bool                              `json:"isEnabled,omitempty"`
+}
+
+// ActionDecisionInput carries a verify-action request and its native decision to the external PDP
+type ActionDecisionInput struct {
+	Agent         *domain.Agent
+	ActionType    string
+	Resource      string
+	Metadata      map[string]interface{}
+	AuditID       uuid.UUID
+	NativeAllowed bool
+	NativeReason  string
+}
+
+// GetPDP returns the organization's external PDP, or nil if none is configured
+func (s *PolicyDecisionService) GetPDP(ctx context.Context, orgID uuid.UUID) (*domain.ExternalPolicyDecisionPoint, error) {
+	return s.decisionRepo.GetPDPByOrganization(orgID)
+}
+
+// ConfigurePDP creates or replaces the organization's external PDP
+func (s *PolicyDecisionService) ConfigurePDP(ctx context.Context, req *PolicyDecisionPointRequest, orgID, userID uuid.UUID) (*domain.ExternalPolicyDecisionPoint, error) {
+	if req.Provider == "" {
+		req.Provider = domain.PolicyDecisionPointOPA
+	}
+	if req.Provider != domain.PolicyDecisionPointOPA {
+		return nil, fmt.Errorf("unsupported provider: %s", req.Provider)
+	}
+
+	endpoint := strings.TrimSpace(req.Endpoint)
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("endpoint must be an http(s) URL")
+	}
+
+	decisionPath := strings.Trim(strings.TrimSpace(req.DecisionPath), "/")
+	if decisionPath == "" {
+		return nil, fmt.Errorf("decisionPath is required")
+	}
+
+	if req.Mode == "" {
+		req.Mode = domain.PolicyDecisionPointEnforce
+	}
+	if req.Mode != domain.PolicyDecisionPointEnforce && req.Mode != domain.PolicyDecisionPointAdvisory {
+		return nil, fmt.Errorf("unsupported mode: %s", req.Mode)
+	}
+
+	if req.TimeoutMs == 0 {
+		req.TimeoutMs = defaultPDPTimeoutMs
+	}
+	if req.TimeoutMs < minPDPTimeoutMs || req.TimeoutMs > maxPDPTimeoutMs {
+		return nil, fmt.Errorf("timeoutMs must be between %d and %d", minPDPTimeoutMs, maxPDPTimeoutMs)
+	}
+
+	existing, err := s.decisionRepo.GetPDPByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	pdp := &domain.ExternalPolicyDecisionPoint{
+		OrganizationID: orgID,
+		Provider:       req.Provider,
+		Endpoint:       endpoint,
+		DecisionPath:   decisionPath,
+		Mode:           req.Mode,
+		FailOpen:       req.FailOpen,
+		TimeoutMs:      req.TimeoutMs,
+		IsEnabled:      true,
+		CreatedBy:      userID,
+	}
+	if existing != nil {
+		pdp.ID = existing.ID
+		pdp.AuthToken = existing.AuthToken
+		pdp.IsEnabled = existing.IsEnabled
+	}
+	if req.AuthToken != nil {
+		pdp.AuthToken = strings.TrimSpace(*req.AuthToken)
+	}
+	if req.IsEnabled != nil {
+		pdp.IsEnabled = *req.IsEnabled
+	}
+
+	if err := s.decisionRepo.UpsertPDP(pdp); err != nil {
+		return nil, fmt.Errorf("failed to save policy decision point: %w", err)
+	}
+
+	return pdp, nil
+}
+
+// DeletePDP removes the organization's external PDP; authorization falls back to native policies
+func (s *PolicyDecisionService) DeletePDP(ctx context.Context, orgID uuid.UUID) error {
+	return s.decisionRepo.DeletePDP(orgID)
+}
+
+// TestPDP sends a sample input document to the configured PDP without recording a decision
+func (s *PolicyDecisionService) TestPDP(ctx context.Context, orgID uuid.UUID, input map[string]interface{}) (*domain.ExternalPolicyResult, error) {
+	pdp, err := s.decisionRepo.GetPDPByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if pdp == nil {
+		return nil, fmt.Errorf("no policy decision point configured")
+	}
+
+	if input == nil {
+		input = buildPolicyDecisionInput(&ActionDecisionInput{
+			Agent:         &domain.Agent{OrganizationID: orgID, Name: "test-agent", Status: domain.AgentStatusVerified},
+			ActionType:    "test:action",
+			AuditID:       uuid.New(),
+			NativeAllowed: true,
+			NativeReason:  "Test request",
+		})
+	}
+
+	evalCtx, cancel := context.WithTimeout(ctx, time.Duration(pdp.TimeoutMs)*time.Millisecond)
+	defer cancel()
+	return s.evaluator.Evaluate(evalCtx, pdp, input)
+}
+
+// ListDecisions lists recorded decisions of an organization, optionally for one agent
+func (s *PolicyDecisionService) ListDecisions(ctx context.Context, orgID uuid.UUID, agentID *uuid.UUID, limit, offset int) ([]*domain.PolicyDecisionRecord, int, error) {
+	return s.decisionRepo.GetDecisionsByOrganization(orgID, agentID, limit, offset)
+}
+
+// DecideAction consults the organization's external PDP for a verify-action request.
+// Without an enabled PDP the native decision is returned unchanged and nothing is recorded.
+// In enforce mode the external decision is honored; in advisory mode it is only recorded.
+// When the PDP fails, fail-open keeps the native decision and fail-closed denies.
+func (s *PolicyDecisionService) DecideAction(ctx context.Context, in *ActionDecisionInput) (bool, string) {
+	pdp, err := s.decisionRepo.GetPDPByOrganization(in.Agent.OrganizationID)
+	if err != nil {
+		log.Printf("⚠️  Failed to load policy decision point for org %s: %v", in.Agent.OrganizationID, err)
+		return in.NativeAllowed, in.NativeReason
+	}
+	if pdp == nil || !pdp.IsEnabled {
+		return in.NativeAllowed, in.NativeReason
+	}
+
+	evalCtx, cancel := context.WithTimeout(ctx, time.Duration(pdp.TimeoutMs)*time.Millisecond)
+	start := time.Now()
+	result, evalErr := s.evaluator.Evaluate(evalCtx, pdp, buildPolicyDecisionInput(in))
+	cancel()
+
+	record := &domain.PolicyDecisionRecord{
+		OrganizationID:     in.Agent.OrganizationID,
+		AgentID:            in.Agent.ID,
+		AuditID:            in.AuditID,
+		ActionType:         in.ActionType,
+		Resource:           in.Resource,
+		NativeAllowed:      in.NativeAllowed,
+		NativeReason:       in.NativeReason,
+		PDPID:              &pdp.ID,
+		PDPMode:            &pdp.Mode,
+		ExternalDurationMs: int(time.Since(start).Milliseconds()),
+	}
+
+	allowed, reason := resolvePolicyDecision(pdp, in, result, evalErr, record)
+
+	if err := s.decisionRepo.CreateDecision(record); err != nil {
+		log.Printf("⚠️  Failed to record policy decision for audit %s: %v", in.AuditID, err)
+	}
+
+	return allowed, reason
+}
+
+// resolvePolicyDecision combines the native and external results and fills in the record
+func resolvePolicyDecision(
+	pdp *domain.ExternalPolicyDecisionPoint,
+	in *ActionDecisionInput,
+	result *domain.ExternalPolicyResult,
+	evalErr error,
+	record *domain.PolicyDecisionRecord,
+) (bool, string) {
+	if evalErr != nil {
+		msg := evalErr.Error()
+		record.ExternalError = &msg
+
+		if pdp.Mode == domain.PolicyDecisionPointAdvisory {
+			record.FinalAllowed = in.NativeAllowed
+			record.DecisionSource = domain.PolicyDecisionSourceNative
+			return in.NativeAllowed, in.NativeReason
+		}
+
+		record.DecisionSource = domain.PolicyDecisionSourceFallback
+		if pdp.FailOpen {
+			record.FinalAllowed = in.NativeAllowed
+			return in.NativeAllowed, in.NativeReason
+		}
+		record.FinalAllowed = false
+		return false, "External policy decision point unavailable - action denied (fail closed)"
+	}
+
+	record.ExternalAllowed = &result.Allowed
+	if result.Reason != "" {
+		record.ExternalReason = &result.Reason
+	}
+
+	if pdp.Mode == domain.PolicyDecisionPointAdvisory {
+		record.FinalAllowed = in.NativeAllowed
+		record.DecisionSource = domain.PolicyDecisionSourceNative
+		return in.NativeAllowed, in.NativeReason
+	}
+
+	record.FinalAllowed = result.Allowed
+	record.DecisionSource = domain.PolicyDecisionSourceExternal
+
+	verdict := "denied"
+	if result.Allowed {
+		verdict = "allowed"
+	}
+	reason := fmt.Sprintf("Action %s by external policy decision point", verdict)
+	if result.Reason != "" {
+		reason += ": " + result.Reason
+	}
+	return result.Allowed, reason
+}
+
+// buildPolicyDecisionInput builds the input document sent to the PDP
+func buildPolicyDecisionInput(in *ActionDecisionInput) map[string]interface{} {
+	agent := in.Agent
+
+	tags := make([]string, 0, len(agent.Tags))
+	for _, tag := range agent.Tags {
+		tags = append(tags, tag.Key+":"+tag.Value)
+	}
+
+	requestContext := in.Metadata
+	if requestContext == nil {
+		requestContext = map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		"agent": map[string]interface{}{
+			"id":                         agent.ID,
+			"organization_id":            agent.OrganizationID,
+			"name":                       agent.Name,
+			"display_name":               agent.DisplayName,
+			"agent_type":                 agent.AgentType,
+			"status":                     agent.Status,
+			"trust_score":                agent.TrustScore,
+			"is_compromised":             agent.IsCompromised,
+			"capability_violation_count": agent.CapabilityViolationCount,
+			"declared_capabilities":      agent.Capabilities,
+			"talks_to":                   agent.TalksTo,
+			"tags":                       tags,
+		},
+		"action": map[string]interface{}{
+			"type":     in.ActionType,
+			"resource": in.Resource,
+		},
+		"context": requestContext,
+		"native_decision": map[string]interface{}{
+			"allowed": in.NativeAllowed,
+			"reason":  in.NativeReason,
+		},
+		"audit_id":  in.AuditID,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,203 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/opa"
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPolicyDecisionRepository is a mock implementation of domain.PolicyDecisionRepository
+type MockPolicyDecisionRepository struct {
+	mock.Mock
+}
+
+func (m *MockPolicyDecisionRepository) GetPDPByOrganization(orgID uuid.UUID) (*domain.ExternalPolicyDecisionPoint, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ExternalPolicyDecisionPoint), args.Error(1)
+}
+
+func (m *MockPolicyDecisionRepository) UpsertPDP(pdp *domain.ExternalPolicyDecisionPoint) error {
+	args := m.Called(pdp)
+	return args.Error(0)
+}
+
+func (m *MockPolicyDecisionRepository) DeletePDP(orgID uuid.UUID) error {
+	args := m.Called(orgID)
+	return args.Error(0)
+}
+
+func (m *MockPolicyDecisionRepository) CreateDecision(decision *domain.PolicyDecisionRecord) error {
+	args := m.Called(decision)
+	return args.Error(0)
+}
+
+func (m *MockPolicyDecisionRepository) GetDecisionsByOrganization(orgID uuid.UUID, agentID *uuid.UUID, limit, offset int) ([]*domain.PolicyDecisionRecord, int, error) {
+	args := m.Called(orgID, agentID, limit, offset)
+	return args.Get(0).([]*domain.PolicyDecisionRecord), args.Int(1), args.Error(2)
+}
+
+// fakePolicyEvaluator returns a fixed result and captures the input document
+type fakePolicyEvaluator struct {
+	result *domain.ExternalPolicyResult
+	err    error
+	input  map[string]interface{}
+}
+
+func (f *fakePolicyEvaluator) Evaluate(ctx context.Context, pdp *domain.ExternalPolicyDecisionPoint, input map[string]interface{}) (*domain.ExternalPolicyResult, error) {
+	f.input = input
+	return f.result, f.err
+}
+
+func TestDecideAction(t *testing.T) {
+	orgID := uuid.New()
+	agent := &domain.Agent{ID: uuid.New(), OrganizationID: orgID, Name: "billing-bot", Status: domain.AgentStatusVerified}
+
+	tests := []struct {
+		name            string
+		mode            domain.PolicyDecisionPointMode
+		failOpen        bool
+		nativeAllowed   bool
+		result          *domain.ExternalPolicyResult
+		evalErr         error
+		expectedAllowed bool
+		expectedSource  domain.PolicyDecisionSource
+	}{
+		{"enforce honors external deny", domain.PolicyDecisionPointEnforce, false, true,
+			&domain.ExternalPolicyResult{Allowed: false, Reason: "outside business hours"}, nil, false, domain.PolicyDecisionSourceExternal},
+		{"enforce honors external allow", domain.PolicyDecisionPointEnforce, false, false,
+			&domain.ExternalPolicyResult{Allowed: true}, nil, true, domain.PolicyDecisionSourceExternal},
+		{"advisory keeps native", domain.PolicyDecisionPointAdvisory, false, true,
+			&domain.ExternalPolicyResult{Allowed: false}, nil, true, domain.PolicyDecisionSourceNative},
+		{"fail closed denies", domain.PolicyDecisionPointEnforce, false, true,
+			nil, errors.New("connection refused"), false, domain.PolicyDecisionSourceFallback},
+		{"fail open keeps native", domain.PolicyDecisionPointEnforce, true, true,
+			nil, errors.New("connection refused"), true, domain.PolicyDecisionSourceFallback},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockPolicyDecisionRepository)
+			evaluator := &fakePolicyEvaluator{result: tt.result, err: tt.evalErr}
+			service := NewPolicyDecisionService(repo, evaluator)
+
+			pdp := &domain.ExternalPolicyDecisionPoint{
+				ID: uuid.New(), OrganizationID: orgID, Mode: tt.mode, FailOpen: tt.failOpen, TimeoutMs: 1000, IsEnabled: true,
+			}
+			repo.On("GetPDPByOrganization", orgID).Return(pdp, nil)
+
+			var recorded *domain.PolicyDecisionRecord
+			repo.On("CreateDecision", mock.Anything).Run(func(args mock.Arguments) {
+				recorded = args.Get(0).(*domain.PolicyDecisionRecord)
+			}).Return(nil)
+
+			allowed, reason := service.DecideAction(context.Background(), &ActionDecisionInput{
+				Agent:         agent,
+				ActionType:    "file:read",
+				Resource:      "/reports/q3.csv",
+				AuditID:       uuid.New(),
+				NativeAllowed: tt.nativeAllowed,
+				NativeReason:  "native reason",
+			})
+
+			assert.Equal(t, tt.expectedAllowed, allowed)
+			assert.NotEmpty(t, reason)
+			if assert.NotNil(t, recorded) {
+				assert.Equal(t, tt.nativeAllowed, recorded.NativeAllowed)
+				assert.Equal(t, tt.expectedAllowed, recorded.FinalAllowed)
+				assert.Equal(t, tt.expectedSource, recorded.DecisionSource)
+				assert.Equal(t, tt.evalErr != nil, recorded.ExternalError != nil)
+			}
+
+			native := evaluator.input["native_decision"].(map[string]interface{})
+			assert.Equal(t, tt.nativeAllowed, native["allowed"])
+			assert.Equal(t, "file:read", evaluator.input["action"].(map[string]interface{})["type"])
+		})
+	}
+}
+
+func TestDecideActionWithoutPDP(t *testing.T) {
+	repo := new(MockPolicyDecisionRepository)
+	evaluator := &fakePolicyEvaluator{}
+	service := NewPolicyDecisionService(repo, evaluator)
+
+	agent := &domain.Agent{ID: uuid.New(), OrganizationID: uuid.New()}
+	repo.On("GetPDPByOrganization", agent.OrganizationID).Return(nil, nil)
+
+	allowed, reason := service.DecideAction(context.Background(), &ActionDecisionInput{
+		Agent: agent, ActionType: "file:read", NativeAllowed: true, NativeReason: "native reason",
+	})
+
+	assert.True(t, allowed)
+	assert.Equal(t, "native reason", reason)
+	assert.Nil(t, evaluator.input, "PDP must not be queried")
+	repo.AssertNotCalled(t, "CreateDecision", mock.Anything)
+}
+
+func TestAuthorizationDegradesToTheFailOpenRuleWhileThePolicyDecisionPointIsDown(t *testing.T) {
+	var hits atomic.Int32
+	pdpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer pdpServer.Close()
+
+	repos := testsupport.NewRepositories()
+	service := NewPolicyDecisionService(repos.PolicyDecision, opa.NewClient())
+	ctx := context.Background()
+	agent := testsupport.NewAgent(uuid.New())
+	require.NoError(t, repos.PolicyDecision.UpsertPDP(&domain.ExternalPolicyDecisionPoint{
+		OrganizationID: agent.OrganizationID,
+		Provider:       domain.PolicyDecisionPointOPA,
+		Endpoint:       pdpServer.URL,
+		DecisionPath:   "aim/authz",
+		Mode:           domain.PolicyDecisionPointEnforce,
+		FailOpen:       true,
+		TimeoutMs:      1000,
+		IsEnabled:      true,
+	}))
+
+	// Every decision falls back to the native one; once the circuit opens the PDP is not called
+	threshold := resilience.DefaultSettings.FailureThreshold
+	for i := 0; i < threshold+3; i++ {
+		allowed, reason := service.DecideAction(ctx, &ActionDecisionInput{
+			Agent:         agent,
+			ActionType:    "read_file",
+			AuditID:       uuid.New(),
+			NativeAllowed: true,
+			NativeReason:  "Capability granted",
+		})
+		assert.True(t, allowed)
+		assert.Equal(t, "Capability granted", reason)
+	}
+	assert.Equal(t, int32(threshold), hits.Load())
+
+	decisions, _, err := repos.PolicyDecision.GetDecisionsByOrganization(agent.OrganizationID, nil, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, domain.PolicyDecisionSourceFallback, decisions[0].DecisionSource)
+	assert.Contains(t, *decisions[0].ExternalError, "circuit open")
+
+	var pdpHealth *resilience.DependencyHealth
+	for _, dependency := range resilience.Default.Health() {
+		if dependency.Name == resilience.DependencyPolicyDecisionPoint {
+			pdpHealth = &dependency
+		}
+	}
+	require.NotNil(t, pdpHealth)
+	assert.NotEqual(t, resilience.HealthHealthy, pdpHealth.Status)
+}
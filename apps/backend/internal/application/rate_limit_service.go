@@ -0,0 +1,212 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ErrOrganizationRateLimited is returned when a caller has used up its organization's rate limit
+var ErrOrganizationRateLimited = errors.New("organization rate limit exceeded")
+
+const (
+	// rateLimitSettingsTTL is how long an organization's limits are cached before they are looked up again
+	rateLimitSettingsTTL = time.Minute
+	// rateLimitBreachWindow and rateLimitBreachThreshold define repeated breaches: this many
+	// rejected requests of one caller within the window record a rate limit violation anomaly
+	rateLimitBreachWindow    = 5 * time.Minute
+	rateLimitBreachThreshold = 10
+)
+
+// RateLimitService enforces each organization's API rate limits. Every API key of the
+// organization has its own token bucket per endpoint class; requests authenticated otherwise
+// share the organization's buckets. The buckets live in the rate limit store, so the limits are
+// shared by all server instances when it is backed by Redis. Platform operators can throttle an
+// organization, capping all of its limits.
+type RateLimitService struct {
+	orgRepo         domain.OrganizationRepository
+	throttleRepo    domain.PlatformOperatorRepository // Optional: without it organizations are never throttled
+	securityService *SecurityService
+	store           domain.RateLimitStore
+	defaults        map[string]domain.RateLimit
+	orgs            map[uuid.UUID]*orgRateLimits
+	breaches        map[string]*rateLimitBreaches
+	mu              sync.Mutex
+	now             func() time.Time
+}
+
+// orgRateLimits is an organization's cached rate limit settings
+type orgRateLimits struct {
+	limits    map[string]domain.RateLimit
+	throttle  *domain.OrganizationThrottle
+	checkedAt time.Time
+}
+
+// rateLimitBreaches counts the rejected requests of one caller
+type rateLimitBreaches struct {
+	count       int
+	windowStart time.Time
+	recorded    bool // A violation anomaly was recorded for the current window
+}
+
+// NewRateLimitService creates a new rate limit service. Classes missing from defaults use
+// domain.DefaultRateLimits; organizations override them in their settings.
+func NewRateLimitService(
+	orgRepo domain.OrganizationRepository,
+	throttleRepo domain.PlatformOperatorRepository,
+	securityService *SecurityService,
+	store domain.RateLimitStore,
+	defaults map[string]domain.RateLimit,
+) *RateLimitService {
+	merged := make(map[string]domain.RateLimit, len(domain.DefaultRateLimits))
+	for class, limit := range domain.DefaultRateLimits {
+		merged[class] = limit
+	}
+	for class, limit := range defaults {
+		merged[class] = limit
+	}
+
+	return &RateLimitService{
+		orgRepo:         orgRepo,
+		throttleRepo:    throttleRepo,
+		securityService: securityService,
+		store:           store,
+		defaults:        merged,
+		orgs:            make(map[uuid.UUID]*orgRateLimits),
+		breaches:        make(map[string]*rateLimitBreaches),
+		now:             time.Now,
+	}
+}
+
+// Allow counts a request of the endpoint class against the organization's limit. apiKeyID is
+// the API key the request was authenticated with, if any. The result is nil when the class is
+// unlimited or the store is unavailable; requests are let through rather than failed then.
+func (s *RateLimitService) Allow(ctx context.Context, orgID uuid.UUID, apiKeyID *uuid.UUID, class string) (*domain.RateLimitResult, error) {
+	limit := s.limitFor(orgID, class)
+	if limit.RequestsPerMinute <= 0 {
+		return nil, nil
+	}
+
+	principal := "org"
+	if apiKeyID != nil {
+		principal = "key:" + apiKeyID.String()
+	}
+	result, err := s.store.Take(ctx, fmt.Sprintf("%s:%s:%s", orgID, principal, class), limit)
+	if err != nil {
+		log.Printf("⚠️  Rate limit store unavailable, allowing request: %v", err)
+		return nil, nil
+	}
+	if result.Allowed {
+		return result, nil
+	}
+
+	s.recordBreach(ctx, orgID, apiKeyID, class)
+	return result, fmt.Errorf("%w: %d %s requests per minute", ErrOrganizationRateLimited, limit.RequestsPerMinute, class)
+}
+
+// ForgetOrganization drops the organization's cached limits, so a new throttle applies right away
+// on this instance; other instances pick it up within a minute
+func (s *RateLimitService) ForgetOrganization(orgID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.orgs, orgID)
+}
+
+// limitFor returns the organization's limit of the endpoint class, capped by its throttle
+func (s *RateLimitService) limitFor(orgID uuid.UUID, class string) domain.RateLimit {
+	s.mu.Lock()
+	cached, ok := s.orgs[orgID]
+	stale := !ok || s.now().Sub(cached.checkedAt) > rateLimitSettingsTTL
+	s.mu.Unlock()
+
+	// Look up the settings outside the lock; the repository may be slow. A failed refresh
+	// keeps the limits already known.
+	if stale {
+		if org, err := s.orgRepo.GetByID(orgID); err == nil && org != nil {
+			cached = &orgRateLimits{limits: domain.OrganizationRateLimits(org.Settings), throttle: s.throttleOf(orgID), checkedAt: s.now()}
+		} else if !ok {
+			cached = &orgRateLimits{checkedAt: s.now()}
+		}
+		s.mu.Lock()
+		s.orgs[orgID] = cached
+		s.mu.Unlock()
+	}
+
+	limit, ok := cached.limits[class]
+	if !ok {
+		limit = s.defaults[class]
+	}
+	if cached.throttle != nil && cached.throttle.IsActive(s.now()) {
+		limit = cached.throttle.Cap(limit)
+	}
+	return limit
+}
+
+// throttleOf returns the organization's throttle; nil when it is not throttled or the lookup fails
+func (s *RateLimitService) throttleOf(orgID uuid.UUID) *domain.OrganizationThrottle {
+	if s.throttleRepo == nil {
+		return nil
+	}
+	throttle, err := s.throttleRepo.GetThrottle(orgID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrOrganizationThrottleNotFound) {
+			log.Printf("⚠️  Failed to look up throttle of organization %s: %v", orgID, err)
+		}
+		return nil
+	}
+	return throttle
+}
+
+// recordBreach counts a rejected request and records a rate limit violation anomaly the first
+// time the caller reaches the threshold within the window
+func (s *RateLimitService) recordBreach(ctx context.Context, orgID uuid.UUID, apiKeyID *uuid.UUID, class string) {
+	resourceType, resourceID := "organization", orgID
+	if apiKeyID != nil {
+		resourceType, resourceID = "api_key", *apiKeyID
+	}
+	key := orgID.String() + ":" + resourceID.String()
+
+	s.mu.Lock()
+	now := s.now()
+	breaches, ok := s.breaches[key]
+	if !ok || now.Sub(breaches.windowStart) > rateLimitBreachWindow {
+		breaches = &rateLimitBreaches{windowStart: now}
+		s.breaches[key] = breaches
+	}
+	breaches.count++
+	record := breaches.count >= rateLimitBreachThreshold && !breaches.recorded
+	if record {
+		breaches.recorded = true
+	}
+	count := breaches.count
+	s.mu.Unlock()
+
+	if !record || s.securityService == nil {
+		return
+	}
+
+	caller := "Requests authenticated without an API key"
+	if apiKeyID != nil {
+		caller = "API key " + apiKeyID.String()
+	}
+	anomaly := &domain.Anomaly{
+		OrganizationID: orgID,
+		AnomalyType:    domain.AnomalyTypeRateLimitViolation,
+		Severity:       domain.AlertSeverityWarning,
+		Title:          "Repeated rate limit breaches",
+		Description: fmt.Sprintf("%s exceeded the organization's %s rate limit %d times within %s",
+			caller, class, count, rateLimitBreachWindow),
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Confidence:   100,
+	}
+	if err := s.securityService.CreateAnomaly(ctx, anomaly); err != nil {
+		log.Printf("⚠️  Failed to record rate limit violation: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/cache"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationRateLimitsArePerAPIKeyAndRepeatedBreachesRecordAnAnomaly(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	ctx := context.Background()
+
+	// Organizations override the server's limits per endpoint class in their settings
+	admins := NewAdminService(repos.User, repos.Organization, repos.Agent)
+	_, err := admins.UpdateOrganizationSettings(ctx, org.ID, &UpdateOrganizationSettingsRequest{
+		RateLimits: map[string]domain.RateLimit{"bogus": {RequestsPerMinute: 1}},
+	})
+	assert.ErrorIs(t, err, ErrInvalidOrganizationSettings)
+	updated, err := admins.UpdateOrganizationSettings(ctx, org.ID, &UpdateOrganizationSettingsRequest{
+		RateLimits: map[string]domain.RateLimit{domain.EndpointClassWrite: {RequestsPerMinute: 2, Burst: 1}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]domain.RateLimit{domain.EndpointClassWrite: {RequestsPerMinute: 2, Burst: 1}}, domain.OrganizationRateLimits(updated.Settings))
+
+	security := NewSecurityService(repos.Security, repos.Agent, repos.Alert)
+	service := NewRateLimitService(repos.Organization, nil, security, cache.NewMemoryRateLimitStore(), nil)
+	keyA, keyB := uuid.New(), uuid.New()
+
+	// An idle key can send the per-minute rate plus the burst at once
+	for i := 0; i < 3; i++ {
+		result, err := service.Allow(ctx, org.ID, &keyA, domain.EndpointClassWrite)
+		require.NoError(t, err)
+		assert.Equal(t, 2-i, result.Remaining)
+	}
+	result, err := service.Allow(ctx, org.ID, &keyA, domain.EndpointClassWrite)
+	assert.ErrorIs(t, err, ErrOrganizationRateLimited)
+	require.NotNil(t, result)
+	assert.False(t, result.Allowed)
+	assert.Greater(t, result.RetryAfter, 25*time.Second)
+
+	// Other keys, requests without a key and other endpoint classes have their own buckets
+	_, err = service.Allow(ctx, org.ID, &keyB, domain.EndpointClassWrite)
+	assert.NoError(t, err)
+	_, err = service.Allow(ctx, org.ID, nil, domain.EndpointClassWrite)
+	assert.NoError(t, err)
+	result, err = service.Allow(ctx, org.ID, &keyA, domain.EndpointClassRead)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DefaultRateLimits[domain.EndpointClassRead], result.Limit)
+
+	// Repeated breaches record one rate limit violation for the key
+	for i := 0; i < 15; i++ {
+		_, err := service.Allow(ctx, org.ID, &keyA, domain.EndpointClassWrite)
+		assert.ErrorIs(t, err, ErrOrganizationRateLimited)
+	}
+	anomalies, total, err := security.SearchAnomalies(ctx, org.ID, domain.AnomalyQueryParams{
+		AnomalyTypes: []domain.AnomalyType{domain.AnomalyTypeRateLimitViolation},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, "api_key", anomalies[0].ResourceType)
+	assert.Equal(t, keyA, anomalies[0].ResourceID)
+	assert.Contains(t, anomalies[0].Description, "write rate limit 10 times")
+}
@@ -431,7 +431,7 @@ func (s *RegistrationService) ApproveRegistrationRequest(
 			},
 		}
 
-		if err := s.emailService.SendTemplatedEmail(domain.TemplateUserApproved, user.Email, templateData); err != nil {
+		if err := organizationEmail(s.emailService, orgID).SendTemplatedEmail(domain.TemplateUserApproved, user.Email, templateData); err != nil {
 			// Log error but don't fail the request (email is non-critical)
 			fmt.Printf("⚠️  Failed to send approval email to %s: %v\n", user.Email, err)
 		} else {
@@ -532,7 +532,7 @@ func (s *RegistrationService) RequestPasswordReset(
 			},
 		}
 
-		if err := s.emailService.SendTemplatedEmail(domain.TemplatePasswordReset, user.Email, templateData); err != nil {
+		if err := organizationEmail(s.emailService, user.OrganizationID).SendTemplatedEmail(domain.TemplatePasswordReset, user.Email, templateData); err != nil {
 			// Log error but don't fail the request (email is non-critical)
 			fmt.Printf("⚠️ Failed to send password reset email to %s: %v\n", email, err)
 		}
@@ -0,0 +1,222 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// Registration validation issue codes
+const (
+	RegistrationIssueRequired          = "required"
+	RegistrationIssueTooLong           = "too_long"
+	RegistrationIssueInvalidValue      = "invalid_value"
+	RegistrationIssueNameTaken         = "name_taken"
+	RegistrationIssueURLTaken          = "url_taken"
+	RegistrationIssueQuotaExceeded     = "quota_exceeded"
+	RegistrationIssueInvalidKey        = "invalid_key"
+	RegistrationIssueInvalidCapability = "invalid_capability"
+	RegistrationIssueNonStandard       = "non_standard_capability"
+	RegistrationIssueDuplicate         = "duplicate_capability"
+	RegistrationIssueKeyGenerated      = "key_generated_on_create"
+	RegistrationIssueInvalidProof      = "invalid_proof_of_possession"
+	RegistrationIssueClientSideKey     = "client_side_key_required"
+	RegistrationIssueKeyInUse          = "key_in_use"
+	RegistrationIssueNamingPolicy      = "naming_policy"
+	RegistrationIssueKeyAlgorithm      = "key_algorithm_not_allowed"
+)
+
+// maxRegistrationNameLength matches the name columns of the agents and mcp_servers tables
+const maxRegistrationNameLength = 255
+
+// maxCapabilityLength matches the capability_type column of agent_capabilities
+const maxCapabilityLength = 100
+
+// capabilityPattern accepts SDK capability names such as "file:read", "read_files" or "mcp:tool_use:search"
+var capabilityPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.\-]*(:[A-Za-z0-9_.\-]+)*$`)
+
+// standardCapabilities is the capability taxonomy agents are checked against
+var standardCapabilities = map[string]bool{
+	domain.CapabilityFileRead:        true,
+	domain.CapabilityFileWrite:       true,
+	domain.CapabilityFileDelete:      true,
+	domain.CapabilityNetworkAccess:   true,
+	domain.CapabilityAPICall:         true,
+	domain.CapabilityDBQuery:         true,
+	domain.CapabilityDBWrite:         true,
+	domain.CapabilityUserImpersonate: true,
+	domain.CapabilityDataExport:      true,
+	domain.CapabilitySystemAdmin:     true,
+	domain.CapabilityMCPToolUse:      true,
+}
+
+// ErrRegistrationInvalid is returned when an agent or MCP server registration fails validation
+var ErrRegistrationInvalid = errors.New("registration is invalid")
+
+// RegistrationIssue is one problem found while validating a registration
+type RegistrationIssue struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// RegistrationValidation is the outcome of validating an agent or MCP server registration.
+// Errors block the registration, warnings do not.
+type RegistrationValidation struct {
+	Valid    bool                `json:"valid"`
+	Errors   []RegistrationIssue `json:"errors"`
+	Warnings []RegistrationIssue `json:"warnings"`
+}
+
+func newRegistrationValidation() *RegistrationValidation {
+	return &RegistrationValidation{
+		Valid:    true,
+		Errors:   make([]RegistrationIssue, 0),
+		Warnings: make([]RegistrationIssue, 0),
+	}
+}
+
+func (v *RegistrationValidation) addError(field, code, message string) {
+	v.Valid = false
+	v.Errors = append(v.Errors, RegistrationIssue{Field: field, Code: code, Message: message})
+}
+
+func (v *RegistrationValidation) addWarning(field, code, message string) {
+	v.Warnings = append(v.Warnings, RegistrationIssue{Field: field, Code: code, Message: message})
+}
+
+// HasError reports whether validation failed with the given issue code
+func (v *RegistrationValidation) HasError(code string) bool {
+	for _, issue := range v.Errors {
+		if issue.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// RegistrationValidationError is returned by CreateAgent and CreateMCPServer when the
+// registration fails the same checks a validate=true dry run reports
+type RegistrationValidationError struct {
+	Validation *RegistrationValidation
+}
+
+func (e *RegistrationValidationError) Error() string {
+	messages := make([]string, 0, len(e.Validation.Errors))
+	for _, issue := range e.Validation.Errors {
+		messages = append(messages, issue.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *RegistrationValidationError) Unwrap() error {
+	return ErrRegistrationInvalid
+}
+
+// validateRegistrationName checks a required name field
+func validateRegistrationName(v *RegistrationValidation, field, value string) {
+	switch {
+	case strings.TrimSpace(value) == "":
+		v.addError(field, RegistrationIssueRequired, fmt.Sprintf("%s is required", field))
+	case len(value) > maxRegistrationNameLength:
+		v.addError(field, RegistrationIssueTooLong, fmt.Sprintf("%s must be at most %d characters", field, maxRegistrationNameLength))
+	}
+}
+
+// validateRegistrationNamingPolicies checks a new name against the organization's naming policies.
+// Names that only differ from an existing one in case or separators are reported as taken.
+func validateRegistrationNamingPolicies(ctx context.Context, v *RegistrationValidation, policies *NamingPolicyService, orgID uuid.UUID, resourceType, name string) error {
+	if policies == nil || strings.TrimSpace(name) == "" {
+		return nil
+	}
+	violations, err := policies.CheckName(ctx, orgID, resourceType, uuid.Nil, strings.TrimSpace(name), nil)
+	if err != nil {
+		return err
+	}
+	for _, violation := range violations {
+		code := RegistrationIssueNamingPolicy
+		if violation.Code == domain.NamingViolationNotUnique {
+			code = RegistrationIssueNameTaken
+		}
+		v.addError("name", code, violation.Message)
+	}
+	return nil
+}
+
+// validateRegistrationPublicKey checks that a caller-provided key is a base64 public key of the
+// requested algorithm, or of any supported algorithm when none is requested, and returns the algorithm
+func validateRegistrationPublicKey(v *RegistrationValidation, algorithm, publicKey string) string {
+	algorithm, err := publicKeyAlgorithm(algorithm, publicKey)
+	if err != nil {
+		v.addError("publicKey", RegistrationIssueInvalidKey, fmt.Sprintf("publicKey must be a base64-encoded %s public key: %v", strings.Join(domain.KeyAlgorithms, ", "), err))
+		return ""
+	}
+	return algorithm
+}
+
+// validateRegistrationKeyPossession checks the proof-of-possession signature of a caller-provided
+// key. Organizations that generate keys client-side require one.
+func validateRegistrationKeyPossession(v *RegistrationValidation, algorithm, name, publicKey, proof string, required bool) {
+	if proof == "" {
+		if required {
+			v.addError("proofOfPossession", RegistrationIssueClientSideKey,
+				"the organization generates agent keys client-side; proofOfPossession (a signature of the registration message) is required")
+		}
+		return
+	}
+	if err := crypto.VerifyProofOfPossession(algorithm, name, publicKey, proof); err != nil {
+		v.addError("proofOfPossession", RegistrationIssueInvalidProof,
+			fmt.Sprintf("proofOfPossession must be the base64 signature of the registration message made with the private key of publicKey: %v", err))
+	}
+}
+
+// validateRegistrationCapabilities checks capability names; with taxonomy set, names outside
+// the standard capability set are reported as warnings
+func validateRegistrationCapabilities(v *RegistrationValidation, capabilities []string, taxonomy bool) {
+	seen := make(map[string]bool, len(capabilities))
+	for _, capability := range capabilities {
+		switch {
+		case len(capability) > maxCapabilityLength:
+			v.addError("capabilities", RegistrationIssueInvalidCapability, fmt.Sprintf("capability %q must be at most %d characters", capability, maxCapabilityLength))
+			continue
+		case !capabilityPattern.MatchString(capability):
+			v.addError("capabilities", RegistrationIssueInvalidCapability, fmt.Sprintf("capability %q is not a valid capability name", capability))
+			continue
+		}
+
+		if seen[capability] {
+			v.addWarning("capabilities", RegistrationIssueDuplicate, fmt.Sprintf("capability %q is declared more than once", capability))
+			continue
+		}
+		seen[capability] = true
+
+		if taxonomy && !isStandardCapability(capability) {
+			v.addWarning("capabilities", RegistrationIssueNonStandard, fmt.Sprintf("capability %q is not in the standard capability taxonomy", capability))
+		}
+	}
+}
+
+// isStandardCapability reports whether a capability is a standard type or an MCP tool scoped under mcp:tool_use
+func isStandardCapability(capability string) bool {
+	return standardCapabilities[capability] || strings.HasPrefix(capability, domain.CapabilityMCPToolUse+":")
+}
+
+// validateRegistrationURL checks a required URL; http(s) URLs must also name a host
+func validateRegistrationURL(v *RegistrationValidation, field, value string) {
+	if value == "" {
+		v.addError(field, RegistrationIssueRequired, fmt.Sprintf("%s is required", field))
+		return
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme == "" || (strings.HasPrefix(parsed.Scheme, "http") && parsed.Host == "") {
+		v.addError(field, RegistrationIssueInvalidValue, fmt.Sprintf("%s must be an absolute URL", field))
+	}
+}
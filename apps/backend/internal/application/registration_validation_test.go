@@ -0,0 +1,121 @@
+package application
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRegistrationCapabilities(t *testing.T) {
+	validation := newRegistrationValidation()
+	validateRegistrationCapabilities(validation, []string{"file:read", "mcp:tool_use:search", "read_files", "file:read", "bad capability"}, true)
+
+	require.False(t, validation.Valid)
+	require.Len(t, validation.Errors, 1)
+	assert.Equal(t, RegistrationIssueInvalidCapability, validation.Errors[0].Code)
+
+	codes := make([]string, 0, len(validation.Warnings))
+	for _, warning := range validation.Warnings {
+		codes = append(codes, warning.Code)
+	}
+	assert.Equal(t, []string{RegistrationIssueNonStandard, RegistrationIssueDuplicate}, codes, "read_files is outside the taxonomy, file:read is repeated")
+
+	// MCP tool names are not checked against the agent taxonomy
+	validation = newRegistrationValidation()
+	validateRegistrationCapabilities(validation, []string{"read_file", "listDirectory"}, false)
+	assert.True(t, validation.Valid)
+	assert.Empty(t, validation.Warnings)
+}
+
+func TestValidateRegistrationPublicKey(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	validation := newRegistrationValidation()
+	algorithm := validateRegistrationPublicKey(validation, "", base64.StdEncoding.EncodeToString(publicKey))
+	assert.True(t, validation.Valid)
+	assert.Equal(t, domain.KeyAlgorithmEd25519, algorithm)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&ecdsaKey.PublicKey)
+	require.NoError(t, err)
+	validation = newRegistrationValidation()
+	algorithm = validateRegistrationPublicKey(validation, "", base64.StdEncoding.EncodeToString(der))
+	assert.True(t, validation.Valid)
+	assert.Equal(t, domain.KeyAlgorithmECDSAP256, algorithm)
+
+	validation = newRegistrationValidation()
+	validateRegistrationPublicKey(validation, domain.KeyAlgorithmEd25519, base64.StdEncoding.EncodeToString(der))
+	assert.True(t, validation.HasError(RegistrationIssueInvalidKey), "the key does not match the requested algorithm")
+
+	for _, key := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("too short"))} {
+		validation := newRegistrationValidation()
+		validateRegistrationPublicKey(validation, "", key)
+		assert.True(t, validation.HasError(RegistrationIssueInvalidKey), key)
+	}
+}
+
+func TestValidateRegistrationURL(t *testing.T) {
+	for value, valid := range map[string]bool{
+		"https://mcp.example.com/sse": true,
+		"stdio://filesystem":          true,
+		"mcp.example.com":             false,
+		"https://":                    false,
+		"":                            false,
+	} {
+		validation := newRegistrationValidation()
+		validateRegistrationURL(validation, "url", value)
+		assert.Equal(t, valid, validation.Valid, value)
+	}
+}
+
+func TestAgentRegistrationDryRunDoesNotPersist(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization(func(o *domain.Organization) { o.MaxAgents = 2 })
+	require.NoError(t, repos.Organization.Create(org))
+	existing := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(existing))
+
+	agentService := NewAgentService(repos.Agent, nil, repos.TrustScore, nil, repos.Alert, nil, repos.Capability, nil, nil, repos.Organization, nil, nil, nil, nil)
+	userID := uuid.New()
+
+	req := &CreateAgentRequest{
+		Name:         "ci-preflight-agent",
+		DisplayName:  "CI Preflight Agent",
+		AgentType:    domain.AgentTypeAI,
+		Capabilities: []string{domain.CapabilityFileRead},
+	}
+	agent, validation, err := agentService.ValidateAgentRegistration(context.Background(), req, org.ID, userID)
+	require.NoError(t, err)
+	assert.True(t, validation.Valid, "%v", validation.Errors)
+	assert.Equal(t, "ci-preflight-agent", agent.Name)
+	assert.Equal(t, uuid.Nil, agent.ID, "the would-be agent is never saved")
+
+	agents, err := repos.Agent.GetByOrganization(org.ID)
+	require.NoError(t, err)
+	assert.Len(t, agents, 1)
+
+	// Name taken and, once the quota is used up, quota exceeded
+	require.NoError(t, repos.Agent.Create(testsupport.NewAgent(org.ID)))
+	req.Name = existing.Name
+	_, validation, err = agentService.ValidateAgentRegistration(context.Background(), req, org.ID, userID)
+	require.NoError(t, err)
+	assert.True(t, validation.HasError(RegistrationIssueNameTaken))
+	assert.True(t, validation.HasError(RegistrationIssueQuotaExceeded))
+
+	// CreateAgent rejects the same registration before touching the repositories
+	_, err = agentService.CreateAgent(context.Background(), req, org.ID, userID)
+	assert.ErrorIs(t, err, ErrRegistrationInvalid)
+}
@@ -0,0 +1,475 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// reportPollInterval is how often the worker looks for due subscriptions
+	reportPollInterval = time.Minute
+	// reportClaimLease is how long a worker owns a claimed subscription
+	reportClaimLease = 15 * time.Minute
+	// reportBatchSize is the number of subscriptions generated per poll
+	reportBatchSize = 10
+	// reportLinkTTL is how long download links stay valid
+	reportLinkTTL = 7 * 24 * time.Hour
+	// reportInlineMaxBytes caps inline attachments; larger reports fall back to a download link
+	reportInlineMaxBytes = 256 * 1024
+	// reportRunHistoryLimit is the number of runs returned per subscription
+	reportRunHistoryLimit = 50
+)
+
+var (
+	// ErrReportLinkExpired is returned when a download link is past its expiry
+	ErrReportLinkExpired = errors.New("report download link has expired")
+)
+
+// ReportService generates scheduled reports and pushes them through notification channels
+type ReportService struct {
+	reportRepo          domain.ReportRepository
+	notificationService *NotificationService
+	artifacts           domain.ArtifactStore // Optional; rendered files are kept in the database without it
+	renderers           map[domain.ReportFormat]domain.ReportRenderer
+	publicURL           string // Base URL used to build download links
+}
+
+// NewReportService creates a new report service
+func NewReportService(
+	reportRepo domain.ReportRepository,
+	notificationService *NotificationService,
+	artifacts domain.ArtifactStore,
+	publicURL string,
+	renderers ...domain.ReportRenderer,
+) *ReportService {
+	s := &ReportService{
+		reportRepo:          reportRepo,
+		notificationService: notificationService,
+		artifacts:           artifacts,
+		renderers:           make(map[domain.ReportFormat]domain.ReportRenderer),
+		publicURL:           strings.TrimRight(publicURL, "/"),
+	}
+	for _, renderer := range renderers {
+		s.renderers[renderer.Format()] = renderer
+	}
+	return s
+}
+
+// ReportSubscriptionRequest represents the request to create or update a report subscription
+type ReportSubscriptionRequest struct {
+	Name       string                `json:"name"`
+	ReportType domain.ReportType     `json:"reportType"`
+	Cadence    domain.ReportCadence  `json:"cadence"`
+	Format     domain.ReportFormat   `json:"format"`
+	Delivery   domain.ReportDelivery `json:"delivery"`
+	ChannelIDs []uuid.UUID           `json:"channelIds"`
+	IsActive   *bool                 `json:"isActive,omitempty"` // Pointer to distinguish between false and not provided
+}
+
+// CreateSubscription creates a new report subscription, first run at the next cadence boundary
+func (s *ReportService) CreateSubscription(ctx context.Context, req *ReportSubscriptionRequest, orgID, userID uuid.UUID) (*domain.ReportSubscription, error) {
+	subscription := &domain.ReportSubscription{
+		OrganizationID: orgID,
+		IsActive:       true,
+		CreatedBy:      userID,
+	}
+	if err := s.applySubscriptionRequest(ctx, subscription, req); err != nil {
+		return nil, err
+	}
+	subscription.NextRunAt = nextReportRun(subscription.Cadence, time.Now().UTC())
+
+	if err := s.reportRepo.CreateSubscription(subscription); err != nil {
+		return nil, fmt.Errorf("failed to create report subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// ListSubscriptions lists all report subscriptions of an organization
+func (s *ReportService) ListSubscriptions(ctx context.Context, orgID uuid.UUID) ([]*domain.ReportSubscription, error) {
+	return s.reportRepo.GetSubscriptionsByOrganization(orgID)
+}
+
+// GetSubscription retrieves a report subscription by ID
+func (s *ReportService) GetSubscription(ctx context.Context, id uuid.UUID) (*domain.ReportSubscription, error) {
+	return s.reportRepo.GetSubscriptionByID(id)
+}
+
+// UpdateSubscription updates a report subscription. Changing the cadence reschedules the next run.
+func (s *ReportService) UpdateSubscription(ctx context.Context, id uuid.UUID, req *ReportSubscriptionRequest) (*domain.ReportSubscription, error) {
+	subscription, err := s.reportRepo.GetSubscriptionByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	previousCadence := subscription.Cadence
+	if err := s.applySubscriptionRequest(ctx, subscription, req); err != nil {
+		return nil, err
+	}
+	if subscription.Cadence != previousCadence {
+		subscription.NextRunAt = nextReportRun(subscription.Cadence, time.Now().UTC())
+	}
+
+	if err := s.reportRepo.UpdateSubscription(subscription); err != nil {
+		return nil, fmt.Errorf("failed to update report subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// DeleteSubscription deletes a report subscription and its run history
+func (s *ReportService) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	return s.reportRepo.DeleteSubscription(id)
+}
+
+// applySubscriptionRequest validates a request and copies it onto the subscription
+func (s *ReportService) applySubscriptionRequest(ctx context.Context, subscription *domain.ReportSubscription, req *ReportSubscriptionRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	switch req.ReportType {
+	case domain.ReportTypeSecurityPosture, domain.ReportTypeVerificationSummary,
+		domain.ReportTypeDriftDigest, domain.ReportTypeTrustChanges:
+	default:
+		return fmt.Errorf("unsupported report type: %s", req.ReportType)
+	}
+
+	switch req.Cadence {
+	case domain.ReportCadenceDaily, domain.ReportCadenceWeekly, domain.ReportCadenceMonthly:
+	default:
+		return fmt.Errorf("unsupported cadence: %s", req.Cadence)
+	}
+
+	if req.Format == "" {
+		req.Format = domain.ReportFormatCSV
+	}
+	if _, ok := s.renderers[req.Format]; !ok {
+		return fmt.Errorf("unsupported format: %s", req.Format)
+	}
+
+	if req.Delivery == "" {
+		req.Delivery = domain.ReportDeliveryLink
+	}
+	if req.Delivery != domain.ReportDeliveryInline && req.Delivery != domain.ReportDeliveryLink {
+		return fmt.Errorf("unsupported delivery: %s", req.Delivery)
+	}
+
+	if len(req.ChannelIDs) == 0 {
+		return fmt.Errorf("at least one notification channel is required")
+	}
+	for _, channelID := range req.ChannelIDs {
+		channel, err := s.notificationService.GetChannel(ctx, channelID)
+		if err != nil || channel.OrganizationID != subscription.OrganizationID {
+			return fmt.Errorf("notification channel %s not found", channelID)
+		}
+	}
+
+	subscription.Name = strings.TrimSpace(req.Name)
+	subscription.ReportType = req.ReportType
+	subscription.Cadence = req.Cadence
+	subscription.Format = req.Format
+	subscription.Delivery = req.Delivery
+	subscription.ChannelIDs = req.ChannelIDs
+	if req.IsActive != nil {
+		subscription.IsActive = *req.IsActive
+	}
+
+	return nil
+}
+
+// Start runs the reporting worker until the context is cancelled
+func (s *ReportService) Start(ctx context.Context) {
+	ticker := time.NewTicker(reportPollInterval)
+	defer ticker.Stop()
+
+	log.Println("✅ Report subscription worker started")
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Report subscription worker stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.ProcessDueReports(ctx); err != nil {
+				log.Printf("⚠️  Report processing failed: %v", err)
+			}
+		}
+	}
+}
+
+// ProcessDueReports generates every due subscription for the cadence period that just closed
+// and schedules its next run. Returns the number of subscriptions processed.
+func (s *ReportService) ProcessDueReports(ctx context.Context) (int, error) {
+	subscriptions, err := s.reportRepo.ClaimDueSubscriptions(reportBatchSize, reportClaimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, subscription := range subscriptions {
+		if ctx.Err() != nil {
+			// Unprocessed claims are retried when their lease expires
+			return i, ctx.Err()
+		}
+
+		now := time.Now().UTC()
+		periodEnd := reportPeriodEnd(subscription.Cadence, now)
+		periodStart := advanceReportPeriod(subscription.Cadence, periodEnd, -1)
+
+		if _, err := s.generate(ctx, subscription, periodStart, periodEnd); err != nil {
+			log.Printf("⚠️  Report subscription %s failed: %v", subscription.ID, err)
+		}
+		if err := s.reportRepo.UpdateSchedule(subscription.ID, now, nextReportRun(subscription.Cadence, now)); err != nil {
+			log.Printf("⚠️  Failed to reschedule report subscription %s: %v", subscription.ID, err)
+		}
+	}
+
+	return len(subscriptions), nil
+}
+
+// RunNow generates a subscription immediately for the cadence period ending now.
+// The regular schedule is not affected.
+func (s *ReportService) RunNow(ctx context.Context, subscription *domain.ReportSubscription) (*domain.ReportRun, error) {
+	periodEnd := time.Now().UTC()
+	periodStart := advanceReportPeriod(subscription.Cadence, periodEnd, -1)
+	return s.generate(ctx, subscription, periodStart, periodEnd)
+}
+
+// generate builds, renders and delivers one report. Every attempt is recorded as a run,
+// including failures, so the run history explains missing reports.
+func (s *ReportService) generate(ctx context.Context, subscription *domain.ReportSubscription, periodStart, periodEnd time.Time) (*domain.ReportRun, error) {
+	run := &domain.ReportRun{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		OrganizationID: subscription.OrganizationID,
+		Status:         domain.ReportRunSucceeded,
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+		Format:         subscription.Format,
+	}
+
+	genErr := s.render(ctx, subscription, run)
+	if genErr != nil {
+		msg := genErr.Error()
+		run.Status = domain.ReportRunFailed
+		run.Error = &msg
+		run.Content = nil
+		run.DownloadToken = ""
+		run.ExpiresAt = nil
+	} else {
+		s.storeContent(ctx, run)
+	}
+
+	if err := s.reportRepo.CreateRun(run); err != nil {
+		return nil, fmt.Errorf("failed to record report run: %w", err)
+	}
+
+	return run, genErr
+}
+
+// render fills the run with the rendered file and dispatches the notification
+func (s *ReportService) render(ctx context.Context, subscription *domain.ReportSubscription, run *domain.ReportRun) error {
+	table, err := s.reportData(subscription, run.PeriodStart, run.PeriodEnd)
+	if err != nil {
+		return fmt.Errorf("failed to load report data: %w", err)
+	}
+
+	renderer, ok := s.renderers[subscription.Format]
+	if !ok {
+		return fmt.Errorf("unsupported format: %s", subscription.Format)
+	}
+	content, err := renderer.Render(table, run.PeriodStart, run.PeriodEnd)
+	if err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+
+	run.Content = content
+	run.ContentType = renderer.ContentType()
+	run.RowCount = len(table.Rows)
+	run.Filename = fmt.Sprintf("%s-%s.%s", subscription.ReportType, run.PeriodEnd.Format("2006-01-02"), subscription.Format)
+
+	notification, err := s.notificationService.DispatchToChannels(ctx, s.buildNotification(subscription, run, table), subscription.ChannelIDs)
+	if err != nil {
+		return fmt.Errorf("failed to deliver report: %w", err)
+	}
+	run.NotificationID = &notification.ID
+
+	return nil
+}
+
+func (s *ReportService) reportData(subscription *domain.ReportSubscription, start, end time.Time) (*domain.ReportTable, error) {
+	switch subscription.ReportType {
+	case domain.ReportTypeSecurityPosture:
+		return s.reportRepo.GetSecurityPostureData(subscription.OrganizationID, start, end)
+	case domain.ReportTypeVerificationSummary:
+		return s.reportRepo.GetVerificationSummaryData(subscription.OrganizationID, start, end)
+	case domain.ReportTypeDriftDigest:
+		return s.reportRepo.GetDriftDigestData(subscription.OrganizationID, start, end)
+	case domain.ReportTypeTrustChanges:
+		return s.reportRepo.GetTrustChangesData(subscription.OrganizationID, start, end)
+	default:
+		return nil, fmt.Errorf("unsupported report type: %s", subscription.ReportType)
+	}
+}
+
+// buildNotification summarizes the report in the message and attaches the file inline or as a link.
+// Inline reports above reportInlineMaxBytes are sent as a link instead.
+func (s *ReportService) buildNotification(subscription *domain.ReportSubscription, run *domain.ReportRun, table *domain.ReportTable) *domain.Notification {
+	var message strings.Builder
+	fmt.Fprintf(&message, "%s for %s - %s",
+		table.Title, run.PeriodStart.Format("2006-01-02 15:04"), run.PeriodEnd.Format("2006-01-02 15:04 MST"))
+	for _, metric := range table.Metrics {
+		fmt.Fprintf(&message, "\n%s: %s", metric.Label, metric.Value)
+	}
+
+	payload := map[string]interface{}{
+		"reportRunId":    run.ID,
+		"subscriptionId": subscription.ID,
+		"reportType":     subscription.ReportType,
+		"periodStart":    run.PeriodStart,
+		"periodEnd":      run.PeriodEnd,
+		"format":         subscription.Format,
+		"filename":       run.Filename,
+		"contentType":    run.ContentType,
+		"rowCount":       run.RowCount,
+		"metrics":        table.Metrics,
+	}
+
+	if subscription.Delivery == domain.ReportDeliveryInline && len(run.Content) <= reportInlineMaxBytes {
+		if subscription.Format == domain.ReportFormatCSV {
+			payload["inlineText"] = string(run.Content)
+		} else {
+			payload["contentBase64"] = base64.StdEncoding.EncodeToString(run.Content)
+		}
+	} else {
+		token := make([]byte, 32)
+		if _, err := rand.Read(token); err == nil {
+			expiresAt := time.Now().UTC().Add(reportLinkTTL)
+			run.DownloadToken = hex.EncodeToString(token)
+			run.ExpiresAt = &expiresAt
+
+			downloadURL := fmt.Sprintf("%s/api/v1/public/reports/%s", s.publicURL, run.DownloadToken)
+			payload["downloadUrl"] = downloadURL
+			payload["expiresAt"] = expiresAt
+			fmt.Fprintf(&message, "\n\nDownload (%s, expires %s): %s",
+				strings.ToUpper(string(subscription.Format)), expiresAt.Format("2006-01-02"), downloadURL)
+		}
+	}
+
+	return &domain.Notification{
+		OrganizationID: subscription.OrganizationID,
+		EventType:      "report." + string(subscription.ReportType),
+		Severity:       domain.AlertSeverityInfo,
+		Title:          fmt.Sprintf("%s report: %s", reportCadenceTitle(subscription.Cadence), subscription.Name),
+		Message:        message.String(),
+		ResourceType:   "report_subscription",
+		ResourceID:     &subscription.ID,
+		Payload:        payload,
+	}
+}
+
+// ListRuns lists the most recent runs of a subscription
+func (s *ReportService) ListRuns(ctx context.Context, subscriptionID uuid.UUID) ([]*domain.ReportRun, error) {
+	return s.reportRepo.GetRunsBySubscription(subscriptionID, reportRunHistoryLimit)
+}
+
+// GetRun retrieves a report run including its content
+func (s *ReportService) GetRun(ctx context.Context, id uuid.UUID) (*domain.ReportRun, error) {
+	return s.reportRepo.GetRunByID(id)
+}
+
+// GetRunContent returns the rendered file of a run, reading it from object storage when it was kept there
+func (s *ReportService) GetRunContent(ctx context.Context, run *domain.ReportRun) ([]byte, error) {
+	if run.ArtifactName == "" {
+		return run.Content, nil
+	}
+	if s.artifacts == nil {
+		return nil, fmt.Errorf("report run %s is kept in object storage, which is not configured", run.ID)
+	}
+	artifact, err := s.artifacts.Get(ctx, run.OrganizationID, domain.ArtifactReport, run.ArtifactName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report file: %w", err)
+	}
+	return artifact.Data, nil
+}
+
+// storeContent moves the rendered file of a run to object storage. The file stays in the
+// database when storage is not configured or the upload fails.
+func (s *ReportService) storeContent(ctx context.Context, run *domain.ReportRun) {
+	if s.artifacts == nil || len(run.Content) == 0 {
+		return
+	}
+	name := fmt.Sprintf("%s/%s.%s", run.SubscriptionID, run.ID, run.Format)
+	if _, err := s.artifacts.Put(ctx, run.OrganizationID, domain.ArtifactReport, name, run.Content, run.ContentType); err != nil {
+		log.Printf("⚠️  Failed to store report run %s in object storage, keeping it in the database: %v", run.ID, err)
+		return
+	}
+	run.ArtifactName = name
+	run.Content = nil
+}
+
+// GetRunByDownloadToken resolves a download link to its report run
+func (s *ReportService) GetRunByDownloadToken(ctx context.Context, token string) (*domain.ReportRun, error) {
+	run, err := s.reportRepo.GetRunByDownloadToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if run.ExpiresAt == nil || time.Now().After(*run.ExpiresAt) {
+		return nil, ErrReportLinkExpired
+	}
+	return run, nil
+}
+
+// reportPeriodEnd returns the latest cadence boundary at or before t (UTC midnight,
+// Monday midnight or the first of the month)
+func reportPeriodEnd(cadence domain.ReportCadence, t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch cadence {
+	case domain.ReportCadenceWeekly:
+		offset := (int(day.Weekday()) + 6) % 7 // Days since Monday
+		return day.AddDate(0, 0, -offset)
+	case domain.ReportCadenceMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// advanceReportPeriod moves t by n cadence periods
+func advanceReportPeriod(cadence domain.ReportCadence, t time.Time, n int) time.Time {
+	switch cadence {
+	case domain.ReportCadenceWeekly:
+		return t.AddDate(0, 0, 7*n)
+	case domain.ReportCadenceMonthly:
+		return t.AddDate(0, n, 0)
+	default:
+		return t.AddDate(0, 0, n)
+	}
+}
+
+// nextReportRun returns the first cadence boundary after now
+func nextReportRun(cadence domain.ReportCadence, now time.Time) time.Time {
+	return advanceReportPeriod(cadence, reportPeriodEnd(cadence, now), 1)
+}
+
+func reportCadenceTitle(cadence domain.ReportCadence) string {
+	switch cadence {
+	case domain.ReportCadenceWeekly:
+		return "Weekly"
+	case domain.ReportCadenceMonthly:
+		return "Monthly"
+	default:
+		return "Daily"
+	}
+}
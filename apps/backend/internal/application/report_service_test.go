@@ -0,0 +1,94 @@
+package application
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportSchedule(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 11, 12, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		cadence     domain.ReportCadence
+		periodStart time.Time
+		periodEnd   time.Time
+		nextRun     time.Time
+	}{
+		{
+			cadence:     domain.ReportCadenceDaily,
+			periodStart: time.Date(2025, 11, 11, 0, 0, 0, 0, time.UTC),
+			periodEnd:   time.Date(2025, 11, 12, 0, 0, 0, 0, time.UTC),
+			nextRun:     time.Date(2025, 11, 13, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			cadence:     domain.ReportCadenceWeekly,
+			periodStart: time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC),
+			periodEnd:   time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC),
+			nextRun:     time.Date(2025, 11, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			cadence:     domain.ReportCadenceMonthly,
+			periodStart: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+			periodEnd:   time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+			nextRun:     time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.cadence), func(t *testing.T) {
+			end := reportPeriodEnd(tt.cadence, now)
+			assert.Equal(t, tt.periodEnd, end)
+			assert.Equal(t, tt.periodStart, advanceReportPeriod(tt.cadence, end, -1))
+			assert.Equal(t, tt.nextRun, nextReportRun(tt.cadence, now))
+		})
+	}
+
+	// A Monday boundary is its own weekly period end
+	monday := time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, monday, reportPeriodEnd(domain.ReportCadenceWeekly, monday))
+}
+
+func TestBuildReportNotification(t *testing.T) {
+	service := NewReportService(nil, nil, nil, "https://aim.example.com/")
+	table := &domain.ReportTable{
+		Title:   "Verification Summary",
+		Metrics: []domain.ReportMetric{{Label: "Verifications", Value: "3"}},
+	}
+	subscription := &domain.ReportSubscription{
+		ID:         uuid.New(),
+		Name:       "Weekly verifications",
+		ReportType: domain.ReportTypeVerificationSummary,
+		Cadence:    domain.ReportCadenceWeekly,
+		Format:     domain.ReportFormatCSV,
+		Delivery:   domain.ReportDeliveryInline,
+	}
+
+	t.Run("inline csv", func(t *testing.T) {
+		run := &domain.ReportRun{ID: uuid.New(), Content: []byte("Target\nbot\n")}
+
+		n := service.buildNotification(subscription, run, table)
+		assert.Equal(t, "report.verification_summary", n.EventType)
+		assert.Equal(t, "Weekly report: Weekly verifications", n.Title)
+		assert.Contains(t, n.Message, "Verifications: 3")
+		assert.Equal(t, "Target\nbot\n", n.Payload["inlineText"])
+		assert.NotContains(t, n.Payload, "downloadUrl")
+		assert.Empty(t, run.DownloadToken)
+	})
+
+	t.Run("oversized inline falls back to link", func(t *testing.T) {
+		run := &domain.ReportRun{ID: uuid.New(), Content: []byte(strings.Repeat("x", reportInlineMaxBytes+1))}
+
+		n := service.buildNotification(subscription, run, table)
+		assert.NotContains(t, n.Payload, "inlineText")
+		assert.NotEmpty(t, run.DownloadToken)
+		assert.NotNil(t, run.ExpiresAt)
+		assert.Equal(t, "https://aim.example.com/api/v1/public/reports/"+run.DownloadToken, n.Payload["downloadUrl"])
+		assert.Contains(t, n.Message, run.DownloadToken)
+	})
+}
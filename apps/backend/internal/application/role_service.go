@@ -0,0 +1,229 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrInvalidCustomRole is returned for a role without a name or with unknown permissions
+	ErrInvalidCustomRole = errors.New("invalid custom role")
+	// ErrRoleUserNotFound is returned when the user to assign a role to is not in the organization
+	ErrRoleUserNotFound = errors.New("user not found in organization")
+)
+
+const maxCustomRoleNameLength = 100
+
+// CustomRoleRequest defines a custom role
+type CustomRoleRequest struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Permissions []domain.Permission `json:"permissions"`
+}
+
+// RoleService manages the organization's custom roles and resolves the permissions a user
+// holds: those of the built-in role plus those of every custom role assigned to the user
+type RoleService struct {
+	repo     domain.CustomRoleRepository
+	userRepo domain.UserRepository
+}
+
+// NewRoleService creates a new role service
+func NewRoleService(repo domain.CustomRoleRepository, userRepo domain.UserRepository) *RoleService {
+	return &RoleService{repo: repo, userRepo: userRepo}
+}
+
+// ListPermissions returns the permission catalog
+func (s *RoleService) ListPermissions() []domain.PermissionDefinition {
+	return domain.PermissionCatalog
+}
+
+// Permissions resolves the permissions of a user with a built-in role. A nil user ID (e.g. an
+// impersonation session) resolves the built-in role's permissions only.
+func (s *RoleService) Permissions(ctx context.Context, orgID, userID uuid.UUID, role domain.UserRole) (domain.PermissionSet, error) {
+	permissions := domain.BuiltinRolePermissions(role)
+	if userID == uuid.Nil {
+		return permissions, nil
+	}
+
+	roles, err := s.repo.ListForUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom roles of user: %w", err)
+	}
+	for _, custom := range roles {
+		if custom.OrganizationID != orgID {
+			continue
+		}
+		for _, permission := range custom.Permissions {
+			permissions[permission] = true
+		}
+	}
+	return permissions, nil
+}
+
+// ListRoles returns the organization's custom roles by name
+func (s *RoleService) ListRoles(ctx context.Context, orgID uuid.UUID) ([]*domain.CustomRole, error) {
+	roles, err := s.repo.ListByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom roles: %w", err)
+	}
+	return roles, nil
+}
+
+// GetRole returns a custom role of the organization
+func (s *RoleService) GetRole(ctx context.Context, orgID, id uuid.UUID) (*domain.CustomRole, error) {
+	role, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if role.OrganizationID != orgID {
+		return nil, domain.ErrCustomRoleNotFound
+	}
+	return role, nil
+}
+
+// CreateRole defines a custom role
+func (s *RoleService) CreateRole(ctx context.Context, orgID, createdBy uuid.UUID, req *CustomRoleRequest) (*domain.CustomRole, error) {
+	role := &domain.CustomRole{OrganizationID: orgID, CreatedBy: &createdBy}
+	if err := s.apply(role, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(role); err != nil {
+		return nil, fmt.Errorf("failed to create custom role: %w", err)
+	}
+	return role, nil
+}
+
+// UpdateRole replaces a custom role's name, description and permissions; users the role is
+// assigned to have the new permissions from their next request
+func (s *RoleService) UpdateRole(ctx context.Context, orgID, id uuid.UUID, req *CustomRoleRequest) (*domain.CustomRole, error) {
+	role, err := s.GetRole(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(role, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(role); err != nil {
+		return nil, fmt.Errorf("failed to update custom role: %w", err)
+	}
+	return role, nil
+}
+
+// DeleteRole deletes a custom role; its users lose its permissions
+func (s *RoleService) DeleteRole(ctx context.Context, orgID, id uuid.UUID) error {
+	if _, err := s.GetRole(ctx, orgID, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete custom role: %w", err)
+	}
+	return nil
+}
+
+// ListRoleUsers returns the users a custom role is assigned to
+func (s *RoleService) ListRoleUsers(ctx context.Context, orgID, id uuid.UUID) ([]*domain.CustomRoleAssignment, error) {
+	if _, err := s.GetRole(ctx, orgID, id); err != nil {
+		return nil, err
+	}
+	assignments, err := s.repo.ListUsers(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom role users: %w", err)
+	}
+	return assignments, nil
+}
+
+// ListUserRoles returns the custom roles assigned to a user of the organization
+func (s *RoleService) ListUserRoles(ctx context.Context, orgID, userID uuid.UUID) ([]*domain.CustomRole, error) {
+	if _, err := s.user(orgID, userID); err != nil {
+		return nil, err
+	}
+	roles, err := s.repo.ListForUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom roles of user: %w", err)
+	}
+	return roles, nil
+}
+
+// AssignRole assigns a custom role to a user of the organization
+func (s *RoleService) AssignRole(ctx context.Context, orgID, roleID, userID, assignedBy uuid.UUID) (*domain.CustomRoleAssignment, error) {
+	if _, err := s.GetRole(ctx, orgID, roleID); err != nil {
+		return nil, err
+	}
+	if _, err := s.user(orgID, userID); err != nil {
+		return nil, err
+	}
+	assignment := &domain.CustomRoleAssignment{RoleID: roleID, UserID: userID, AssignedBy: &assignedBy}
+	if err := s.repo.Assign(assignment); err != nil {
+		return nil, fmt.Errorf("failed to assign custom role: %w", err)
+	}
+	return assignment, nil
+}
+
+// UnassignRole removes a custom role from a user
+func (s *RoleService) UnassignRole(ctx context.Context, orgID, roleID, userID uuid.UUID) error {
+	if _, err := s.GetRole(ctx, orgID, roleID); err != nil {
+		return err
+	}
+	if err := s.repo.Unassign(roleID, userID); err != nil {
+		return fmt.Errorf("failed to unassign custom role: %w", err)
+	}
+	return nil
+}
+
+// apply validates the request and sets it on the role
+func (s *RoleService) apply(role *domain.CustomRole, req *CustomRoleRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxCustomRoleNameLength {
+		return fmt.Errorf("%w: name is required and must be at most %d characters", ErrInvalidCustomRole, maxCustomRoleNameLength)
+	}
+	for _, builtin := range []domain.UserRole{domain.RoleAdmin, domain.RoleManager, domain.RoleMember, domain.RoleViewer} {
+		if strings.EqualFold(name, string(builtin)) {
+			return fmt.Errorf("%w: %s is a built-in role", ErrInvalidCustomRole, builtin)
+		}
+	}
+	if len(req.Permissions) == 0 {
+		return fmt.Errorf("%w: at least one permission is required", ErrInvalidCustomRole)
+	}
+
+	seen := make(map[domain.Permission]bool, len(req.Permissions))
+	permissions := make([]domain.Permission, 0, len(req.Permissions))
+	for _, permission := range req.Permissions {
+		if !domain.IsPermission(permission) {
+			return fmt.Errorf("%w: unknown permission %q", ErrInvalidCustomRole, permission)
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+	}
+
+	existing, err := s.repo.ListByOrganization(role.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("failed to list custom roles: %w", err)
+	}
+	for _, other := range existing {
+		if other.ID != role.ID && strings.EqualFold(other.Name, name) {
+			return domain.ErrCustomRoleNameTaken
+		}
+	}
+
+	role.Name = name
+	role.Description = strings.TrimSpace(req.Description)
+	role.Permissions = permissions
+	return nil
+}
+
+// user returns a user of the organization
+func (s *RoleService) user(orgID, userID uuid.UUID) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || user == nil || user.OrganizationID != orgID {
+		return nil, ErrRoleUserNotFound
+	}
+	return user, nil
+}
@@ -0,0 +1,436 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// DefaultRuntimeConfigRefreshInterval is how often instances pick up settings changed elsewhere
+const DefaultRuntimeConfigRefreshInterval = 30 * time.Second
+
+var (
+	// ErrRuntimeSettingNotFound is returned for keys that are not runtime settings
+	ErrRuntimeSettingNotFound = errors.New("runtime setting not found")
+	// ErrInvalidRuntimeSetting is returned for values a setting does not accept and changes without a reason
+	ErrInvalidRuntimeSetting = errors.New("invalid runtime setting")
+)
+
+// Kinds of runtime settings
+const (
+	RuntimeSettingInt      = "int"
+	RuntimeSettingDuration = "duration" // Go duration, e.g. "90s" or "5m"
+	RuntimeSettingBool     = "bool"
+	RuntimeSettingEnum     = "enum"
+)
+
+// RuntimeSettingDefinition describes an operational knob platform operators can change while
+// the backend runs. Default is the value configured at startup, in effect until an operator
+// sets another.
+type RuntimeSettingDefinition struct {
+	Key         string
+	Description string
+	Kind        string
+	Default     string
+	Range       string   // Accepted range of int and duration settings, e.g. "1-10000"
+	Values      []string // Accepted values of enum settings
+	// normalize validates a value and returns it in canonical form
+	normalize func(value string) (string, error)
+	// apply puts a normalized value into effect on this instance
+	apply func(value string)
+}
+
+// IntSetting defines a whole-number setting between min and max
+func IntSetting(key, description string, def, min, max int, apply func(int)) RuntimeSettingDefinition {
+	return RuntimeSettingDefinition{
+		Key:         key,
+		Description: description,
+		Kind:        RuntimeSettingInt,
+		Default:     strconv.Itoa(def),
+		Range:       fmt.Sprintf("%d-%d", min, max),
+		normalize: func(value string) (string, error) {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < min || n > max {
+				return "", fmt.Errorf("%w: %s must be a whole number from %d to %d", ErrInvalidRuntimeSetting, key, min, max)
+			}
+			return strconv.Itoa(n), nil
+		},
+		apply: func(value string) {
+			n, _ := strconv.Atoi(value)
+			apply(n)
+		},
+	}
+}
+
+// DurationSetting defines a duration setting between min and max
+func DurationSetting(key, description string, def, min, max time.Duration, apply func(time.Duration)) RuntimeSettingDefinition {
+	return RuntimeSettingDefinition{
+		Key:         key,
+		Description: description,
+		Kind:        RuntimeSettingDuration,
+		Default:     def.String(),
+		Range:       fmt.Sprintf("%s-%s", min, max),
+		normalize: func(value string) (string, error) {
+			d, err := time.ParseDuration(strings.TrimSpace(value))
+			if err != nil || d < min || d > max {
+				return "", fmt.Errorf("%w: %s must be a duration from %s to %s", ErrInvalidRuntimeSetting, key, min, max)
+			}
+			return d.String(), nil
+		},
+		apply: func(value string) {
+			d, _ := time.ParseDuration(value)
+			apply(d)
+		},
+	}
+}
+
+// BoolSetting defines an on/off setting
+func BoolSetting(key, description string, def bool, apply func(bool)) RuntimeSettingDefinition {
+	return RuntimeSettingDefinition{
+		Key:         key,
+		Description: description,
+		Kind:        RuntimeSettingBool,
+		Default:     strconv.FormatBool(def),
+		normalize: func(value string) (string, error) {
+			b, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return "", fmt.Errorf("%w: %s must be true or false", ErrInvalidRuntimeSetting, key)
+			}
+			return strconv.FormatBool(b), nil
+		},
+		apply: func(value string) {
+			b, _ := strconv.ParseBool(value)
+			apply(b)
+		},
+	}
+}
+
+// EnumSetting defines a setting taking one of values
+func EnumSetting(key, description, def string, values []string, apply func(string)) RuntimeSettingDefinition {
+	return RuntimeSettingDefinition{
+		Key:         key,
+		Description: description,
+		Kind:        RuntimeSettingEnum,
+		Default:     def,
+		Values:      values,
+		normalize: func(value string) (string, error) {
+			value = strings.ToLower(strings.TrimSpace(value))
+			for _, allowed := range values {
+				if value == allowed {
+					return value, nil
+				}
+			}
+			return "", fmt.Errorf("%w: %s must be one of %s", ErrInvalidRuntimeSetting, key, strings.Join(values, ", "))
+		},
+		apply: apply,
+	}
+}
+
+// RuntimeSettingState is a runtime setting as platform operators see it
+type RuntimeSettingState struct {
+	Key         string     `json:"key"`
+	Description string     `json:"description"`
+	Kind        string     `json:"kind"`
+	Value       string     `json:"value"`   // In effect on this instance
+	Default     string     `json:"default"` // Configured at startup
+	Overridden  bool       `json:"overridden"`
+	Range       string     `json:"range,omitempty"`
+	Values      []string   `json:"values,omitempty"`
+	UpdatedBy   *uuid.UUID `json:"updatedBy,omitempty"`
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+}
+
+// RuntimeSettingRequest changes or resets a runtime setting
+type RuntimeSettingRequest struct {
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+// runtimeSetting is a defined setting and the value in effect on this instance
+type runtimeSetting struct {
+	definition RuntimeSettingDefinition
+	applied    string
+}
+
+// RuntimeConfigService lets platform operators tune operational knobs (rate limits, job
+// intervals, cache TTLs, feature toggles, the log level) without a restart.
+//
+// Changes are stored, so every instance applies them: the instance taking the change right
+// away and the others on their next reload. Each change is kept in the setting's history and
+// in the operator log. Settings without a stored value keep the value configured at startup.
+type RuntimeConfigService struct {
+	repo         domain.RuntimeSettingRepository
+	operatorRepo domain.PlatformOperatorRepository
+	settings     map[string]*runtimeSetting
+	mu           sync.Mutex // Guards settings and serializes applying values
+	now          func() time.Time
+}
+
+// NewRuntimeConfigService creates a new runtime config service
+func NewRuntimeConfigService(repo domain.RuntimeSettingRepository, operatorRepo domain.PlatformOperatorRepository) *RuntimeConfigService {
+	return &RuntimeConfigService{
+		repo:         repo,
+		operatorRepo: operatorRepo,
+		settings:     make(map[string]*runtimeSetting),
+		now:          time.Now,
+	}
+}
+
+// Define adds settings. Their defaults are assumed to be in effect already; call Reload to
+// apply stored values.
+func (s *RuntimeConfigService) Define(definitions ...RuntimeSettingDefinition) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, definition := range definitions {
+		s.settings[definition.Key] = &runtimeSetting{definition: definition, applied: definition.Default}
+	}
+}
+
+// Reload applies the stored value of every setting, and the default of settings without one.
+// Invalid stored values, e.g. of a setting whose range has since narrowed, are logged and
+// the default applies instead.
+func (s *RuntimeConfigService) Reload(ctx context.Context) error {
+	stored, err := s.repo.List()
+	if err != nil {
+		return fmt.Errorf("failed to load runtime settings: %w", err)
+	}
+	values := make(map[string]string, len(stored))
+	for _, setting := range stored {
+		values[setting.Key] = setting.Value
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, setting := range s.settings {
+		value := setting.definition.Default
+		if storedValue, ok := values[key]; ok {
+			normalized, err := setting.definition.normalize(storedValue)
+			if err != nil {
+				log.Printf("⚠️  Ignoring stored runtime setting %s=%q: %v", key, storedValue, err)
+			} else {
+				value = normalized
+			}
+		}
+		s.applyLocked(setting, value)
+	}
+	return nil
+}
+
+// Watch reloads the settings every interval until ctx is cancelled, so changes made on other
+// instances take effect here
+func (s *RuntimeConfigService) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultRuntimeConfigRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reload(ctx); err != nil {
+				log.Printf("⚠️  Failed to reload runtime settings: %v", err)
+			}
+		}
+	}
+}
+
+// applyLocked puts the value into effect unless it already is; callers must hold s.mu
+func (s *RuntimeConfigService) applyLocked(setting *runtimeSetting, value string) {
+	if setting.applied == value {
+		return
+	}
+	setting.definition.apply(value)
+	log.Printf("🎚️  Runtime setting %s changed from %s to %s", setting.definition.Key, setting.applied, value)
+	setting.applied = value
+}
+
+// List returns every setting with the value in effect on this instance, ordered by key
+func (s *RuntimeConfigService) List(ctx context.Context) ([]*RuntimeSettingState, error) {
+	stored, err := s.stored()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states := make([]*RuntimeSettingState, 0, len(s.settings))
+	for _, setting := range s.settings {
+		states = append(states, s.stateLocked(setting, stored[setting.definition.Key]))
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Key < states[j].Key })
+	return states, nil
+}
+
+// Get returns the setting with the value in effect on this instance
+func (s *RuntimeConfigService) Get(ctx context.Context, key string) (*RuntimeSettingState, error) {
+	if _, err := s.setting(key); err != nil {
+		return nil, err
+	}
+	stored, err := s.stored()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stateLocked(s.settings[key], stored[key]), nil
+}
+
+// Set stores the setting's value and applies it on this instance right away; the other
+// instances apply it on their next reload
+func (s *RuntimeConfigService) Set(ctx context.Context, actor *domain.PlatformOperator, ip, key string, req *RuntimeSettingRequest) (*RuntimeSettingState, error) {
+	setting, err := s.setting(key)
+	if err != nil {
+		return nil, err
+	}
+	value, err := setting.definition.normalize(req.Value)
+	if err != nil {
+		return nil, err
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidRuntimeSetting)
+	}
+
+	stored, err := s.stored()
+	if err != nil {
+		return nil, err
+	}
+	var previous *string
+	if current, ok := stored[key]; ok {
+		previous = &current.Value
+	}
+
+	now := s.now()
+	if err := s.repo.Set(
+		&domain.RuntimeSetting{Key: key, Value: value, UpdatedBy: &actor.ID, UpdatedAt: now},
+		&domain.RuntimeSettingChange{Key: key, PreviousValue: previous, Value: &value, Reason: reason, ChangedBy: &actor.ID, IPAddress: ip, ChangedAt: now},
+	); err != nil {
+		return nil, fmt.Errorf("failed to store runtime setting: %w", err)
+	}
+
+	s.mu.Lock()
+	s.applyLocked(setting, value)
+	s.mu.Unlock()
+
+	s.record(actor, ip, "runtime_setting.set", map[string]interface{}{
+		"key": key, "previousValue": previous, "value": value, "reason": reason,
+	})
+	return s.Get(ctx, key)
+}
+
+// Reset removes the stored value, returning the setting to the value configured at startup
+func (s *RuntimeConfigService) Reset(ctx context.Context, actor *domain.PlatformOperator, ip, key, reason string) (*RuntimeSettingState, error) {
+	setting, err := s.setting(key)
+	if err != nil {
+		return nil, err
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidRuntimeSetting)
+	}
+
+	stored, err := s.stored()
+	if err != nil {
+		return nil, err
+	}
+	current, ok := stored[key]
+	if !ok {
+		// Nothing stored: the default is in effect already
+		return s.Get(ctx, key)
+	}
+
+	if err := s.repo.Delete(key, &domain.RuntimeSettingChange{
+		Key: key, PreviousValue: &current.Value, Reason: reason, ChangedBy: &actor.ID, IPAddress: ip, ChangedAt: s.now(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to reset runtime setting: %w", err)
+	}
+
+	s.mu.Lock()
+	s.applyLocked(setting, setting.definition.Default)
+	s.mu.Unlock()
+
+	s.record(actor, ip, "runtime_setting.reset", map[string]interface{}{
+		"key": key, "previousValue": current.Value, "reason": reason,
+	})
+	return s.Get(ctx, key)
+}
+
+// ListChanges returns a page of the changes of the setting, or of every setting when key is
+// empty, newest first. Changes of settings no longer defined are kept and listed.
+func (s *RuntimeConfigService) ListChanges(ctx context.Context, key string, limit, offset int) ([]*domain.RuntimeSettingChange, error) {
+	return s.repo.ListChanges(key, operatorPageSize(limit), max(offset, 0))
+}
+
+func (s *RuntimeConfigService) setting(key string) (*runtimeSetting, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	setting, ok := s.settings[key]
+	if !ok {
+		return nil, ErrRuntimeSettingNotFound
+	}
+	return setting, nil
+}
+
+// stored returns the stored settings by key
+func (s *RuntimeConfigService) stored() (map[string]*domain.RuntimeSetting, error) {
+	settings, err := s.repo.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load runtime settings: %w", err)
+	}
+	byKey := make(map[string]*domain.RuntimeSetting, len(settings))
+	for _, setting := range settings {
+		byKey[setting.Key] = setting
+	}
+	return byKey, nil
+}
+
+// stateLocked describes the setting; stored is nil without a stored value. Callers must hold s.mu.
+func (s *RuntimeConfigService) stateLocked(setting *runtimeSetting, stored *domain.RuntimeSetting) *RuntimeSettingState {
+	definition := setting.definition
+	state := &RuntimeSettingState{
+		Key:         definition.Key,
+		Description: definition.Description,
+		Kind:        definition.Kind,
+		Value:       setting.applied,
+		Default:     definition.Default,
+		Range:       definition.Range,
+		Values:      definition.Values,
+	}
+	if stored != nil {
+		updatedAt := stored.UpdatedAt
+		state.Overridden = true
+		state.UpdatedBy = stored.UpdatedBy
+		state.UpdatedAt = &updatedAt
+	}
+	return state
+}
+
+// record appends an entry to the operator log; failures are logged and do not undo the change
+func (s *RuntimeConfigService) record(actor *domain.PlatformOperator, ip, action string, details map[string]interface{}) {
+	if s.operatorRepo == nil {
+		return
+	}
+	entry := &domain.OperatorAction{
+		OperatorID: actor.ID,
+		Action:     action,
+		Details:    details,
+		IPAddress:  ip,
+		CreatedAt:  s.now(),
+	}
+	if err := s.operatorRepo.RecordAction(entry); err != nil {
+		log.Printf("⚠️  Failed to record operator action %s by %s: %v", action, actor.Email, err)
+	}
+}
@@ -0,0 +1,132 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeSettingsApplyWithoutRestartOnEveryInstance(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	ctx := context.Background()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	operator := &domain.PlatformOperator{Email: "ops@hosting.example.com", Role: domain.OperatorRoleAdmin, TokenHash: "hash", IsActive: true}
+	require.NoError(t, repos.PlatformOperator.CreateOperator(operator))
+	repos.FeatureFlag.CreateFlag(&domain.FeatureFlag{Key: "agent-graph"})
+	flags := NewFeatureFlagService(repos.FeatureFlag)
+
+	// Two instances sharing the settings store, each with its own knobs
+	type knobs struct {
+		perMinute int
+		interval  time.Duration
+	}
+	newInstance := func(k *knobs) *RuntimeConfigService {
+		*k = knobs{perMinute: 100, interval: time.Hour}
+		service := NewRuntimeConfigService(repos.RuntimeSetting, repos.PlatformOperator)
+		service.Define(
+			IntSetting("rate_limit.requests_per_minute", "Requests per minute", 100, 1, 1000, func(n int) { k.perMinute = n }),
+			DurationSetting("jobs.cleanup.interval", "Cleanup interval", time.Hour, 10*time.Second, 24*time.Hour, func(d time.Duration) { k.interval = d }),
+			EnumSetting("features.agent-graph", "Agent graph", "default", []string{"default", "on", "off"}, func(value string) {
+				enabled := value == "on"
+				if value == "default" {
+					flags.ForceFeature("agent-graph", nil)
+				} else {
+					flags.ForceFeature("agent-graph", &enabled)
+				}
+			}),
+		)
+		return service
+	}
+	var firstKnobs, secondKnobs knobs
+	first, second := newInstance(&firstKnobs), newInstance(&secondKnobs)
+
+	// Invalid values, missing reasons and unknown keys are rejected
+	_, err := first.Set(ctx, operator, "10.0.0.1", "rate_limit.requests_per_minute", &RuntimeSettingRequest{Value: "5000", Reason: "spike"})
+	assert.ErrorIs(t, err, ErrInvalidRuntimeSetting)
+	_, err = first.Set(ctx, operator, "10.0.0.1", "jobs.cleanup.interval", &RuntimeSettingRequest{Value: "1s", Reason: "spike"})
+	assert.ErrorIs(t, err, ErrInvalidRuntimeSetting)
+	_, err = first.Set(ctx, operator, "10.0.0.1", "rate_limit.requests_per_minute", &RuntimeSettingRequest{Value: "500"})
+	assert.ErrorIs(t, err, ErrInvalidRuntimeSetting)
+	_, err = first.Set(ctx, operator, "10.0.0.1", "cache.ttl", &RuntimeSettingRequest{Value: "1m", Reason: "spike"})
+	assert.ErrorIs(t, err, ErrRuntimeSettingNotFound)
+
+	// A change applies right away on the instance taking it, and on the others once they reload
+	state, err := first.Set(ctx, operator, "10.0.0.1", "rate_limit.requests_per_minute", &RuntimeSettingRequest{Value: " 500 ", Reason: "Launch traffic"})
+	require.NoError(t, err)
+	assert.Equal(t, "500", state.Value)
+	assert.Equal(t, "100", state.Default)
+	assert.True(t, state.Overridden)
+	assert.Equal(t, &operator.ID, state.UpdatedBy)
+	_, err = first.Set(ctx, operator, "10.0.0.1", "jobs.cleanup.interval", &RuntimeSettingRequest{Value: "90s", Reason: "Drain backlog"})
+	require.NoError(t, err)
+	assert.Equal(t, knobs{perMinute: 500, interval: 90 * time.Second}, firstKnobs)
+	assert.Equal(t, knobs{perMinute: 100, interval: time.Hour}, secondKnobs)
+
+	require.NoError(t, second.Reload(ctx))
+	assert.Equal(t, knobs{perMinute: 500, interval: 90 * time.Second}, secondKnobs)
+	settings, err := second.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, settings, 3)
+	assert.Equal(t, "features.agent-graph", settings[0].Key)
+	assert.Equal(t, "1m30s", settings[1].Value)
+	assert.Equal(t, []string{"default", "on", "off"}, settings[0].Values)
+
+	// Feature toggles force a feature for every organization, whatever the organization chose
+	_, err = flags.SetOrganizationFeature(ctx, org.ID, uuid.New(), "agent-graph", false)
+	require.NoError(t, err)
+	_, err = first.Set(ctx, operator, "10.0.0.1", "features.agent-graph", &RuntimeSettingRequest{Value: "ON", Reason: "Incident workaround"})
+	require.NoError(t, err)
+	assert.True(t, flags.IsEnabled(ctx, org.ID, nil, "agent-graph"))
+	features, err := flags.ListFeatures(ctx, org.ID, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, domain.FeatureSourceDeployment, features[0].Source)
+
+	// Resetting returns to the startup value; resetting a setting without a stored value changes nothing
+	state, err = first.Reset(ctx, operator, "10.0.0.2", "features.agent-graph", "Workaround no longer needed")
+	require.NoError(t, err)
+	assert.Equal(t, "default", state.Value)
+	assert.False(t, state.Overridden)
+	assert.False(t, flags.IsEnabled(ctx, org.ID, nil, "agent-graph"))
+	_, err = first.Reset(ctx, operator, "10.0.0.2", "features.agent-graph", "Again")
+	require.NoError(t, err)
+	_, err = first.Reset(ctx, operator, "10.0.0.2", "rate_limit.requests_per_minute", "")
+	assert.ErrorIs(t, err, ErrInvalidRuntimeSetting)
+
+	// Every change is kept with who made it, from and to which value, and why
+	changes, err := first.ListChanges(ctx, "", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+	reset := changes[0]
+	assert.Equal(t, "features.agent-graph", reset.Key)
+	assert.Equal(t, "on", *reset.PreviousValue)
+	assert.Nil(t, reset.Value)
+	assert.Equal(t, "Workaround no longer needed", reset.Reason)
+	assert.Equal(t, &operator.ID, reset.ChangedBy)
+	assert.Equal(t, "10.0.0.2", reset.IPAddress)
+	changes, err = first.ListChanges(ctx, "rate_limit.requests_per_minute", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Nil(t, changes[0].PreviousValue)
+	assert.Equal(t, "500", *changes[0].Value)
+
+	actions, err := repos.PlatformOperator.ListActions(10, 0)
+	require.NoError(t, err)
+	require.Len(t, actions, 4)
+	assert.Equal(t, "runtime_setting.reset", actions[0].Action)
+	assert.Equal(t, "runtime_setting.set", actions[1].Action)
+
+	// Stored values a setting no longer accepts fall back to the startup value
+	require.NoError(t, repos.RuntimeSetting.Set(
+		&domain.RuntimeSetting{Key: "jobs.cleanup.interval", Value: "forever", UpdatedAt: time.Now()},
+		&domain.RuntimeSettingChange{Key: "jobs.cleanup.interval", ChangedAt: time.Now()},
+	))
+	require.NoError(t, second.Reload(ctx))
+	assert.Equal(t, time.Hour, secondKnobs.interval)
+}
@@ -0,0 +1,323 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+)
+
+var (
+	// ErrSAMLNotConfigured is returned when the organization has no enabled SAML configuration
+	ErrSAMLNotConfigured = errors.New("SAML single sign-on is not enabled for this organization")
+	// ErrInvalidSAMLConfig is returned when a SAML configuration is malformed
+	ErrInvalidSAMLConfig = errors.New("invalid SAML configuration")
+	// ErrSAMLLoginFailed is returned when a SAML response is rejected or cannot be mapped to a user
+	ErrSAMLLoginFailed = errors.New("SAML login failed")
+)
+
+// SAMLConfigRequest creates or replaces an organization's SAML configuration
+type SAMLConfigRequest struct {
+	Enabled         bool                       `json:"enabled"`
+	IdPEntityID     string                     `json:"idpEntityId"`
+	IdPSSOURL       string                     `json:"idpSsoUrl"`
+	IdPCertificate  string                     `json:"idpCertificate"`
+	EmailAttribute  string                     `json:"emailAttribute"`
+	NameAttribute   string                     `json:"nameAttribute"`
+	RoleAttribute   string                     `json:"roleAttribute"`
+	RoleMappings    map[string]domain.UserRole `json:"roleMappings"`
+	DefaultRole     domain.UserRole            `json:"defaultRole"`     // Defaults to viewer
+	JITProvisioning *bool                      `json:"jitProvisioning"` // Defaults to true
+}
+
+// SAMLService signs users in through their organization's SAML 2.0 identity provider. Users
+// are provisioned just in time on their first login, and their role follows the configured
+// role attribute on every login.
+type SAMLService struct {
+	configRepo domain.SAMLConfigRepository
+	userRepo   domain.UserRepository
+	orgRepo    domain.OrganizationRepository
+	publicURL  string
+	state      *auth.SAMLStateStore
+}
+
+// NewSAMLService creates a new SAML service; publicURL is the externally reachable API base URL
+func NewSAMLService(
+	configRepo domain.SAMLConfigRepository,
+	userRepo domain.UserRepository,
+	orgRepo domain.OrganizationRepository,
+	publicURL string,
+) *SAMLService {
+	return &SAMLService{
+		configRepo: configRepo,
+		userRepo:   userRepo,
+		orgRepo:    orgRepo,
+		publicURL:  strings.TrimRight(publicURL, "/"),
+		state:      auth.NewSAMLStateStore(),
+	}
+}
+
+// serviceProvider describes AIM as the service provider of the organization. The entity ID and
+// ACS URL only depend on the organization, so metadata is available before the IdP is configured.
+func (s *SAMLService) serviceProvider(orgID uuid.UUID, config *domain.SAMLConfig) (*auth.SAMLServiceProvider, error) {
+	base := fmt.Sprintf("%s/api/v1/auth/saml/%s", s.publicURL, orgID)
+	sp := &auth.SAMLServiceProvider{
+		EntityID: base + "/metadata",
+		ACSURL:   base + "/acs",
+	}
+	if config != nil {
+		cert, err := auth.ParseSAMLCertificate(config.IdPCertificate)
+		if err != nil {
+			return nil, err
+		}
+		sp.IdPEntityID = config.IdPEntityID
+		sp.IdPSSOURL = config.IdPSSOURL
+		sp.IdPCertificate = cert
+	}
+	return sp, nil
+}
+
+// Metadata returns the service provider metadata to register with the organization's IdP
+func (s *SAMLService) Metadata(ctx context.Context, orgID uuid.UUID) ([]byte, error) {
+	if _, err := s.orgRepo.GetByID(orgID); err != nil {
+		return nil, fmt.Errorf("organization not found: %w", err)
+	}
+	sp, err := s.serviceProvider(orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return sp.Metadata(), nil
+}
+
+// GetConfig returns the organization's SAML configuration, or nil if none is configured
+func (s *SAMLService) GetConfig(ctx context.Context, orgID uuid.UUID) (*domain.SAMLConfig, error) {
+	return s.configRepo.GetByOrganization(orgID)
+}
+
+// UpdateConfig validates and stores the organization's SAML configuration
+func (s *SAMLService) UpdateConfig(ctx context.Context, orgID, userID uuid.UUID, req *SAMLConfigRequest) (*domain.SAMLConfig, error) {
+	req.IdPEntityID = strings.TrimSpace(req.IdPEntityID)
+	req.IdPSSOURL = strings.TrimSpace(req.IdPSSOURL)
+	if req.IdPEntityID == "" {
+		return nil, fmt.Errorf("%w: idpEntityId is required", ErrInvalidSAMLConfig)
+	}
+	if ssoURL, err := url.Parse(req.IdPSSOURL); err != nil || (ssoURL.Scheme != "https" && ssoURL.Scheme != "http") || ssoURL.Host == "" {
+		return nil, fmt.Errorf("%w: idpSsoUrl must be an absolute http(s) URL", ErrInvalidSAMLConfig)
+	}
+	cert, err := auth.ParseSAMLCertificate(req.IdPCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: idpCertificate: %v", ErrInvalidSAMLConfig, err)
+	}
+	if time.Now().After(cert.NotAfter) {
+		return nil, fmt.Errorf("%w: idpCertificate expired on %s", ErrInvalidSAMLConfig, cert.NotAfter.Format(time.RFC3339))
+	}
+
+	if req.DefaultRole == "" {
+		req.DefaultRole = domain.RoleViewer
+	}
+	if !isUserRole(req.DefaultRole) {
+		return nil, fmt.Errorf("%w: invalid defaultRole %q", ErrInvalidSAMLConfig, req.DefaultRole)
+	}
+	for value, role := range req.RoleMappings {
+		if !isUserRole(role) {
+			return nil, fmt.Errorf("%w: role mapping %q has an invalid role %q", ErrInvalidSAMLConfig, value, role)
+		}
+	}
+	if len(req.RoleMappings) > 0 && strings.TrimSpace(req.RoleAttribute) == "" {
+		return nil, fmt.Errorf("%w: roleMappings require a roleAttribute", ErrInvalidSAMLConfig)
+	}
+
+	config := &domain.SAMLConfig{
+		OrganizationID:  orgID,
+		Enabled:         req.Enabled,
+		IdPEntityID:     req.IdPEntityID,
+		IdPSSOURL:       req.IdPSSOURL,
+		IdPCertificate:  strings.TrimSpace(req.IdPCertificate),
+		EmailAttribute:  strings.TrimSpace(req.EmailAttribute),
+		NameAttribute:   strings.TrimSpace(req.NameAttribute),
+		RoleAttribute:   strings.TrimSpace(req.RoleAttribute),
+		RoleMappings:    req.RoleMappings,
+		DefaultRole:     req.DefaultRole,
+		JITProvisioning: req.JITProvisioning == nil || *req.JITProvisioning,
+		UpdatedBy:       &userID,
+	}
+	if config.RoleMappings == nil {
+		config.RoleMappings = map[string]domain.UserRole{}
+	}
+
+	if err := s.configRepo.Upsert(config); err != nil {
+		return nil, fmt.Errorf("failed to save SAML configuration: %w", err)
+	}
+	return config, nil
+}
+
+// DeleteConfig removes the organization's SAML configuration; SAML users keep their accounts
+func (s *SAMLService) DeleteConfig(ctx context.Context, orgID uuid.UUID) error {
+	return s.configRepo.Delete(orgID)
+}
+
+// LoginURL starts an SP-initiated login and returns the identity provider URL to redirect to
+func (s *SAMLService) LoginURL(ctx context.Context, orgID uuid.UUID, relayState string) (string, error) {
+	config, err := s.enabledConfig(orgID)
+	if err != nil {
+		return "", err
+	}
+	sp, err := s.serviceProvider(orgID, config)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidSAMLConfig, err)
+	}
+
+	now := time.Now()
+	redirectURL, requestID, err := sp.AuthnRequestURL(relayState, now)
+	if err != nil {
+		return "", err
+	}
+	s.state.TrackRequest(requestID, now)
+	return redirectURL, nil
+}
+
+// CompleteLogin validates a SAMLResponse posted to the organization's ACS URL and returns the
+// signed-in user, provisioning them if needed
+func (s *SAMLService) CompleteLogin(ctx context.Context, orgID uuid.UUID, samlResponse string) (*domain.User, error) {
+	config, err := s.enabledConfig(orgID)
+	if err != nil {
+		return nil, err
+	}
+	sp, err := s.serviceProvider(orgID, config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSAMLConfig, err)
+	}
+
+	now := time.Now()
+	assertion, err := sp.ParseResponse(samlResponse, now)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSAMLLoginFailed, err)
+	}
+	// IdP-initiated responses carry no InResponseTo; SP-initiated ones must answer our request
+	if assertion.InResponseTo != "" && !s.state.ConsumeRequest(assertion.InResponseTo, now) {
+		return nil, fmt.Errorf("%w: response does not answer a pending login request", ErrSAMLLoginFailed)
+	}
+	if !s.state.ConsumeAssertion(assertion.ID, assertion.NotOnOrAfter, now) {
+		return nil, fmt.Errorf("%w: assertion was already used", ErrSAMLLoginFailed)
+	}
+
+	return s.provisionUser(orgID, config, assertion)
+}
+
+func (s *SAMLService) enabledConfig(orgID uuid.UUID) (*domain.SAMLConfig, error) {
+	config, err := s.configRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SAML configuration: %w", err)
+	}
+	if config == nil || !config.Enabled {
+		return nil, ErrSAMLNotConfigured
+	}
+	return config, nil
+}
+
+// provisionUser maps the assertion to a user of the organization: existing users are signed in
+// (and their role follows the role attribute), unknown users are created when JIT is enabled
+func (s *SAMLService) provisionUser(orgID uuid.UUID, config *domain.SAMLConfig, assertion *auth.SAMLAssertion) (*domain.User, error) {
+	email := assertion.NameID
+	if config.EmailAttribute != "" {
+		email = assertion.Attribute(config.EmailAttribute)
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, fmt.Errorf("%w: assertion has no valid email address", ErrSAMLLoginFailed)
+	}
+
+	name := ""
+	if config.NameAttribute != "" {
+		name = strings.TrimSpace(assertion.Attribute(config.NameAttribute))
+	}
+	if name == "" {
+		name = strings.Split(email, "@")[0]
+	}
+
+	role := samlRole(config, assertion)
+	now := time.Now()
+
+	if user, err := s.userRepo.GetByEmail(email); err == nil && user != nil {
+		if user.OrganizationID != orgID {
+			return nil, fmt.Errorf("%w: %s belongs to another organization", ErrSAMLLoginFailed, email)
+		}
+		if user.Status != domain.UserStatusActive {
+			return nil, fmt.Errorf("%w: the account of %s is %s", ErrSAMLLoginFailed, email, user.Status)
+		}
+
+		if config.RoleAttribute != "" {
+			user.Role = role
+		}
+		if user.Provider == domain.UserProviderSAML {
+			user.Name = name
+			user.ProviderID = assertion.NameID
+		}
+		user.LastLoginAt = &now
+		user.UpdatedAt = now
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+		return user, nil
+	}
+
+	if !config.JITProvisioning {
+		return nil, fmt.Errorf("%w: %s has no account and just-in-time provisioning is disabled", ErrSAMLLoginFailed, email)
+	}
+
+	user := &domain.User{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Email:          email,
+		Name:           name,
+		Role:           role,
+		Provider:       domain.UserProviderSAML,
+		ProviderID:     assertion.NameID,
+		Status:         domain.UserStatusActive, // The organization's IdP already authorized the user
+		ApprovedAt:     &now,
+		LastLoginAt:    &now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, fmt.Errorf("failed to provision user: %w", err)
+	}
+	return user, nil
+}
+
+// samlRole maps the assertion's role attribute values to the highest matching role, falling
+// back to the default role
+func samlRole(config *domain.SAMLConfig, assertion *auth.SAMLAssertion) domain.UserRole {
+	var best domain.UserRole
+	if config.RoleAttribute != "" {
+		for _, value := range assertion.Attributes[config.RoleAttribute] {
+			for mappedValue, role := range config.RoleMappings {
+				if strings.EqualFold(strings.TrimSpace(mappedValue), value) && roleRank(role) > roleRank(best) {
+					best = role
+				}
+			}
+		}
+	}
+	if best != "" {
+		return best
+	}
+	if config.DefaultRole != "" {
+		return config.DefaultRole
+	}
+	return domain.RoleViewer
+}
+
+func isUserRole(role domain.UserRole) bool {
+	switch role {
+	case domain.RoleAdmin, domain.RoleManager, domain.RoleMember, domain.RoleViewer:
+		return true
+	}
+	return false
+}
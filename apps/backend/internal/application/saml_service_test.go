@@ -0,0 +1,131 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSAMLRoleMapping(t *testing.T) {
+	config := &domain.SAMLConfig{
+		RoleAttribute: "groups",
+		RoleMappings: map[string]domain.UserRole{
+			"aim-admins":   domain.RoleAdmin,
+			"AIM-Managers": domain.RoleManager,
+			"engineering":  domain.RoleMember,
+		},
+		DefaultRole: domain.RoleViewer,
+	}
+
+	tests := []struct {
+		name   string
+		groups []string
+		want   domain.UserRole
+	}{
+		{"no groups", nil, domain.RoleViewer},
+		{"unmapped group", []string{"sales"}, domain.RoleViewer},
+		{"case-insensitive match", []string{"aim-managers"}, domain.RoleManager},
+		{"highest role wins", []string{"engineering", "aim-admins", "aim-managers"}, domain.RoleAdmin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertion := &auth.SAMLAssertion{Attributes: map[string][]string{"groups": tt.groups}}
+			assert.Equal(t, tt.want, samlRole(config, assertion))
+		})
+	}
+
+	// Without a role attribute everyone gets the default role
+	assertion := &auth.SAMLAssertion{Attributes: map[string][]string{"groups": {"aim-admins"}}}
+	assert.Equal(t, domain.RoleViewer, samlRole(&domain.SAMLConfig{RoleMappings: config.RoleMappings}, assertion))
+}
+
+func TestSAMLConfigurationAndLogin(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	ctx := context.Background()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	admin := testsupport.NewUser(org.ID, func(u *domain.User) { u.Role = domain.RoleAdmin })
+	require.NoError(t, repos.User.Create(admin))
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	certificate := func(notAfter time.Time) string {
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "idp.example.com"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     notAfter,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		require.NoError(t, err)
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	}
+
+	saml := NewSAMLService(repos.SAMLConfig, repos.User, repos.Organization, "https://aim.example.com/")
+	metadata, err := saml.Metadata(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Contains(t, string(metadata), "https://aim.example.com/api/v1/auth/saml/"+org.ID.String()+"/acs")
+
+	_, err = saml.LoginURL(ctx, org.ID, "/dashboard")
+	assert.ErrorIs(t, err, ErrSAMLNotConfigured)
+
+	valid := func() *SAMLConfigRequest {
+		return &SAMLConfigRequest{
+			Enabled:        true,
+			IdPEntityID:    "https://idp.example.com",
+			IdPSSOURL:      "https://idp.example.com/sso",
+			IdPCertificate: certificate(time.Now().Add(24 * time.Hour)),
+			RoleAttribute:  "groups",
+			RoleMappings:   map[string]domain.UserRole{"aim-admins": domain.RoleAdmin},
+		}
+	}
+	invalid := []func(*SAMLConfigRequest){
+		func(r *SAMLConfigRequest) { r.IdPEntityID = "" },
+		func(r *SAMLConfigRequest) { r.IdPSSOURL = "javascript:alert(1)" },
+		func(r *SAMLConfigRequest) { r.IdPCertificate = "not a certificate" },
+		func(r *SAMLConfigRequest) { r.IdPCertificate = certificate(time.Now().Add(-time.Minute)) },
+		func(r *SAMLConfigRequest) { r.RoleMappings["owners"] = "owner" },
+		func(r *SAMLConfigRequest) { r.RoleAttribute = "" },
+	}
+	for _, mutate := range invalid {
+		req := valid()
+		mutate(req)
+		_, err := saml.UpdateConfig(ctx, org.ID, admin.ID, req)
+		assert.ErrorIs(t, err, ErrInvalidSAMLConfig)
+	}
+
+	config, err := saml.UpdateConfig(ctx, org.ID, admin.ID, valid())
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleViewer, config.DefaultRole)
+	assert.True(t, config.JITProvisioning)
+
+	loginURL, err := saml.LoginURL(ctx, org.ID, "/agents")
+	require.NoError(t, err)
+	redirect, err := url.Parse(loginURL)
+	require.NoError(t, err)
+	assert.Equal(t, "idp.example.com", redirect.Host)
+	assert.NotEmpty(t, redirect.Query().Get("SAMLRequest"))
+	assert.Equal(t, "/agents", redirect.Query().Get("RelayState"))
+
+	_, err = saml.CompleteLogin(ctx, org.ID, base64.StdEncoding.EncodeToString([]byte("<Response/>")))
+	assert.ErrorIs(t, err, ErrSAMLLoginFailed)
+
+	require.NoError(t, saml.DeleteConfig(ctx, org.ID))
+	stored, err := saml.GetConfig(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
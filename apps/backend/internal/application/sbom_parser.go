@@ -0,0 +1,126 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// MaxSBOMComponents caps the number of components accepted in one SBOM
+const MaxSBOMComponents = 10000
+
+// cycloneDXComponent is the subset of a CycloneDX component that is stored. Components
+// can nest sub-components (e.g. a package bundling its own dependencies).
+type cycloneDXComponent struct {
+	Name       string               `json:"name"`
+	Version    string               `json:"version"`
+	PURL       string               `json:"purl"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXDocument struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Components  []cycloneDXComponent `json:"components"`
+}
+
+type spdxDocument struct {
+	SPDXVersion string `json:"spdxVersion"`
+	Packages    []struct {
+		Name         string `json:"name"`
+		VersionInfo  string `json:"versionInfo"`
+		ExternalRefs []struct {
+			ReferenceCategory string `json:"referenceCategory"`
+			ReferenceType     string `json:"referenceType"`
+			ReferenceLocator  string `json:"referenceLocator"`
+		} `json:"externalRefs"`
+	} `json:"packages"`
+}
+
+// ParseSBOM detects whether a JSON document is a CycloneDX or SPDX SBOM and returns its
+// format, spec version and components. Duplicate components are listed once.
+func ParseSBOM(document []byte) (domain.SBOMFormat, string, []domain.SBOMComponent, error) {
+	var probe struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(document, &probe); err != nil {
+		return "", "", nil, fmt.Errorf("%w: document is not valid JSON", ErrInvalidSBOM)
+	}
+
+	components := &sbomComponentSet{seen: make(map[string]bool)}
+	switch {
+	case strings.EqualFold(probe.BOMFormat, "CycloneDX"):
+		var doc cycloneDXDocument
+		if err := json.Unmarshal(document, &doc); err != nil {
+			return "", "", nil, fmt.Errorf("%w: invalid CycloneDX document: %v", ErrInvalidSBOM, err)
+		}
+		components.addCycloneDX(doc.Components)
+		if components.overflow {
+			return "", "", nil, fmt.Errorf("%w: more than %d components", ErrInvalidSBOM, MaxSBOMComponents)
+		}
+		return domain.SBOMFormatCycloneDX, doc.SpecVersion, components.items, nil
+
+	case strings.HasPrefix(probe.SPDXVersion, "SPDX-"):
+		var doc spdxDocument
+		if err := json.Unmarshal(document, &doc); err != nil {
+			return "", "", nil, fmt.Errorf("%w: invalid SPDX document: %v", ErrInvalidSBOM, err)
+		}
+		for _, pkg := range doc.Packages {
+			component := domain.SBOMComponent{Name: pkg.Name, Version: pkg.VersionInfo}
+			for _, ref := range pkg.ExternalRefs {
+				// SPDX 2.2 spells the category PACKAGE_MANAGER, 2.3 PACKAGE-MANAGER
+				category := strings.ReplaceAll(strings.ToUpper(ref.ReferenceCategory), "_", "-")
+				if category == "PACKAGE-MANAGER" && strings.EqualFold(ref.ReferenceType, "purl") {
+					component.PURL = ref.ReferenceLocator
+					break
+				}
+			}
+			components.add(component)
+		}
+		if components.overflow {
+			return "", "", nil, fmt.Errorf("%w: more than %d components", ErrInvalidSBOM, MaxSBOMComponents)
+		}
+		return domain.SBOMFormatSPDX, strings.TrimPrefix(doc.SPDXVersion, "SPDX-"), components.items, nil
+
+	default:
+		return "", "", nil, fmt.Errorf("%w: only CycloneDX (bomFormat) and SPDX (spdxVersion) JSON documents are supported", ErrInvalidSBOM)
+	}
+}
+
+// sbomComponentSet collects components once each, keyed by purl or name and version
+type sbomComponentSet struct {
+	items    []domain.SBOMComponent
+	seen     map[string]bool
+	overflow bool
+}
+
+func (s *sbomComponentSet) add(component domain.SBOMComponent) {
+	component.Name = strings.TrimSpace(component.Name)
+	if component.Name == "" {
+		return
+	}
+
+	key := component.PURL
+	if key == "" {
+		key = component.Name + "@" + component.Version
+	}
+	if s.seen[key] {
+		return
+	}
+	if len(s.items) >= MaxSBOMComponents {
+		s.overflow = true
+		return
+	}
+	s.seen[key] = true
+	s.items = append(s.items, component)
+}
+
+func (s *sbomComponentSet) addCycloneDX(components []cycloneDXComponent) {
+	for _, component := range components {
+		s.add(domain.SBOMComponent{Name: component.Name, Version: component.Version, PURL: component.PURL})
+		s.addCycloneDX(component.Components)
+	}
+}
@@ -0,0 +1,337 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// MaxSBOMDocumentBytes caps the size of an uploaded or fetched SBOM document
+	MaxSBOMDocumentBytes = 4 << 20
+
+	// sbomRescanInterval is how often the latest SBOM of each agent is scanned again, so
+	// vulnerabilities published after the upload are picked up
+	sbomRescanInterval  = 24 * time.Hour
+	sbomRescanBatchSize = 100
+	sbomScanTimeout     = 2 * time.Minute
+	// sbomAlertListLimit caps the vulnerabilities listed in an alert description
+	sbomAlertListLimit = 10
+)
+
+var (
+	// ErrInvalidSBOM is returned for malformed upload requests and unsupported documents
+	ErrInvalidSBOM = errors.New("invalid sbom")
+	// ErrSBOMNotFound is returned for unknown SBOMs and SBOMs of another agent
+	ErrSBOMNotFound = errors.New("sbom not found")
+)
+
+// UploadSBOMRequest uploads an SBOM document, or a link to fetch it from, for a version of an agent
+type UploadSBOMRequest struct {
+	AgentVersion string          `json:"agent_version"` // Defaults to the agent's current version
+	Document     json.RawMessage `json:"document"`      // SPDX or CycloneDX JSON document
+	URL          string          `json:"url"`           // HTTPS link to the document, instead of document
+}
+
+// SBOMService ingests agent SBOMs and scans their components for known vulnerabilities.
+// Critical vulnerabilities raise a security alert; the latest scan of each agent also
+// counts against its compliance trust factor.
+type SBOMService struct {
+	sbomRepo   domain.AgentSBOMRepository
+	agentRepo  domain.AgentRepository
+	alertRepo  domain.AlertRepository
+	vulnDB     domain.VulnerabilityDatabase
+	httpClient *http.Client
+}
+
+// NewSBOMService creates a new SBOM service
+func NewSBOMService(
+	sbomRepo domain.AgentSBOMRepository,
+	agentRepo domain.AgentRepository,
+	alertRepo domain.AlertRepository,
+	vulnDB domain.VulnerabilityDatabase,
+) *SBOMService {
+	return &SBOMService{
+		sbomRepo:   sbomRepo,
+		agentRepo:  agentRepo,
+		alertRepo:  alertRepo,
+		vulnDB:     vulnDB,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// UploadSBOM stores an SBOM for the agent and scans it. A failed scan does not fail the
+// upload: the SBOM is kept with a failed status and scanned again by the rescan worker.
+func (s *SBOMService) UploadSBOM(ctx context.Context, agent *domain.Agent, req *UploadSBOMRequest, uploadedBy *uuid.UUID) (*domain.AgentSBOM, error) {
+	hasDocument := len(req.Document) > 0 && string(req.Document) != "null"
+	req.URL = strings.TrimSpace(req.URL)
+	if hasDocument == (req.URL != "") {
+		return nil, fmt.Errorf("%w: provide either document or url", ErrInvalidSBOM)
+	}
+
+	document := []byte(req.Document)
+	var sourceURL *string
+	if req.URL != "" {
+		fetched, err := s.fetchDocument(ctx, req.URL)
+		if err != nil {
+			return nil, err
+		}
+		document = fetched
+		sourceURL = &req.URL
+	}
+	if len(document) > MaxSBOMDocumentBytes {
+		return nil, fmt.Errorf("%w: document is larger than %d bytes", ErrInvalidSBOM, MaxSBOMDocumentBytes)
+	}
+
+	format, specVersion, components, err := ParseSBOM(document)
+	if err != nil {
+		return nil, err
+	}
+
+	agentVersion := strings.TrimSpace(req.AgentVersion)
+	if agentVersion == "" {
+		agentVersion = agent.Version
+	}
+
+	// The previous SBOM decides which critical vulnerabilities are new
+	previous, _ := s.sbomRepo.GetLatestByAgent(agent.ID)
+
+	hash := sha256.Sum256(document)
+	sbom := &domain.AgentSBOM{
+		ID:             uuid.New(),
+		OrganizationID: agent.OrganizationID,
+		AgentID:        agent.ID,
+		AgentVersion:   agentVersion,
+		Format:         format,
+		SpecVersion:    specVersion,
+		SourceURL:      sourceURL,
+		DocumentHash:   hex.EncodeToString(hash[:]),
+		Document:       document,
+		Components:     components,
+		ComponentCount: len(components),
+		ScanStatus:     domain.SBOMScanStatusPending,
+		UploadedBy:     uploadedBy,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := s.sbomRepo.Create(sbom); err != nil {
+		return nil, fmt.Errorf("failed to store sbom: %w", err)
+	}
+
+	s.scan(ctx, agent, sbom, previous)
+	return sbom, nil
+}
+
+// ListSBOMs lists an agent's SBOMs, newest first
+func (s *SBOMService) ListSBOMs(ctx context.Context, agentID uuid.UUID) ([]*domain.AgentSBOM, error) {
+	return s.sbomRepo.GetByAgent(agentID)
+}
+
+// GetSBOM retrieves an SBOM of the agent
+func (s *SBOMService) GetSBOM(ctx context.Context, agentID, sbomID uuid.UUID) (*domain.AgentSBOM, error) {
+	sbom, err := s.sbomRepo.GetByID(sbomID)
+	if err != nil || sbom.AgentID != agentID {
+		return nil, ErrSBOMNotFound
+	}
+	return sbom, nil
+}
+
+// RescanSBOM scans an SBOM of the agent again
+func (s *SBOMService) RescanSBOM(ctx context.Context, agent *domain.Agent, sbomID uuid.UUID) (*domain.AgentSBOM, error) {
+	sbom, err := s.GetSBOM(ctx, agent.ID, sbomID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *sbom
+	s.scan(ctx, agent, sbom, &previous)
+	return sbom, nil
+}
+
+// Start rescans the latest SBOM of each agent once a day until ctx is cancelled
+func (s *SBOMService) Start(ctx context.Context) {
+	ticker := time.NewTicker(sbomRescanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if count, err := s.RescanDue(ctx); err != nil {
+				fmt.Printf("⚠️  SBOM rescan failed: %v\n", err)
+			} else if count > 0 {
+				fmt.Printf("✅ Rescanned %d agent SBOMs\n", count)
+			}
+		}
+	}
+}
+
+// RescanDue scans the latest SBOMs that were not scanned within the rescan interval
+func (s *SBOMService) RescanDue(ctx context.Context) (int, error) {
+	due, err := s.sbomRepo.GetDueForRescan(time.Now().UTC().Add(-sbomRescanInterval), sbomRescanBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, sbom := range due {
+		agent, err := s.agentRepo.GetByID(sbom.AgentID)
+		if err != nil {
+			continue
+		}
+		previous := *sbom
+		s.scan(ctx, agent, sbom, &previous)
+	}
+	return len(due), nil
+}
+
+// scan queries the vulnerability database for the SBOM's components and stores the result.
+// An alert is raised when the scan finds critical vulnerabilities the previous scan did not.
+func (s *SBOMService) scan(ctx context.Context, agent *domain.Agent, sbom *domain.AgentSBOM, previous *domain.AgentSBOM) {
+	scanCtx, cancel := context.WithTimeout(ctx, sbomScanTimeout)
+	defer cancel()
+
+	now := time.Now().UTC()
+	vulnerabilities, err := s.vulnDB.QueryComponents(scanCtx, sbom.Components)
+	if err != nil {
+		// Keep the last successful result; only the status and error change
+		message := err.Error()
+		sbom.ScanStatus = domain.SBOMScanStatusFailed
+		sbom.ScanError = &message
+		sbom.ScannedAt = &now
+		if err := s.sbomRepo.UpdateScan(sbom); err != nil {
+			fmt.Printf("⚠️  Failed to record SBOM scan failure for %s: %v\n", sbom.ID, err)
+		}
+		return
+	}
+
+	sort.SliceStable(vulnerabilities, func(i, j int) bool {
+		return vulnerabilitySeverityRank[vulnerabilities[i].Severity] > vulnerabilitySeverityRank[vulnerabilities[j].Severity]
+	})
+	sbom.Vulnerabilities = vulnerabilities
+	sbom.VulnerabilityCount = len(vulnerabilities)
+	sbom.CriticalCount = 0
+	sbom.HighCount = 0
+	for _, vulnerability := range vulnerabilities {
+		switch vulnerability.Severity {
+		case domain.VulnerabilitySeverityCritical:
+			sbom.CriticalCount++
+		case domain.VulnerabilitySeverityHigh:
+			sbom.HighCount++
+		}
+	}
+	sbom.ScanStatus = domain.SBOMScanStatusScanned
+	sbom.ScanError = nil
+	sbom.ScannedAt = &now
+	if err := s.sbomRepo.UpdateScan(sbom); err != nil {
+		fmt.Printf("⚠️  Failed to store SBOM scan for %s: %v\n", sbom.ID, err)
+		return
+	}
+
+	known := make(map[string]bool)
+	if previous != nil {
+		for _, vulnerability := range previous.Vulnerabilities {
+			if vulnerability.Severity == domain.VulnerabilitySeverityCritical {
+				known[vulnerability.ID] = true
+			}
+		}
+	}
+	var introduced []domain.SBOMVulnerability
+	for _, vulnerability := range vulnerabilities {
+		if vulnerability.Severity == domain.VulnerabilitySeverityCritical && !known[vulnerability.ID] {
+			introduced = append(introduced, vulnerability)
+		}
+	}
+	if len(introduced) > 0 {
+		if err := s.raiseVulnerabilityAlert(agent, sbom, introduced); err != nil {
+			fmt.Printf("⚠️  Failed to create SBOM vulnerability alert: %v\n", err)
+		}
+	}
+}
+
+// vulnerabilitySeverityRank orders scan results most severe first
+var vulnerabilitySeverityRank = map[domain.VulnerabilitySeverity]int{
+	domain.VulnerabilitySeverityCritical: 4,
+	domain.VulnerabilitySeverityHigh:     3,
+	domain.VulnerabilitySeverityMedium:   2,
+	domain.VulnerabilitySeverityLow:      1,
+}
+
+// raiseVulnerabilityAlert tells admins which critical vulnerabilities the agent ships with
+func (s *SBOMService) raiseVulnerabilityAlert(agent *domain.Agent, sbom *domain.AgentSBOM, vulnerabilities []domain.SBOMVulnerability) error {
+	version := sbom.AgentVersion
+	if version == "" {
+		version = "unversioned"
+	}
+
+	message := fmt.Sprintf("The SBOM of agent '%s' (%s) contains %d component(s) with critical vulnerabilities.\n\n", agent.Name, version, len(vulnerabilities))
+	for i, vulnerability := range vulnerabilities {
+		if i == sbomAlertListLimit {
+			message += fmt.Sprintf("- ...and %d more\n", len(vulnerabilities)-sbomAlertListLimit)
+			break
+		}
+		message += fmt.Sprintf("- %s in %s %s", vulnerability.ID, vulnerability.ComponentName, vulnerability.ComponentVersion)
+		if vulnerability.Summary != "" {
+			message += ": " + vulnerability.Summary
+		}
+		message += "\n"
+	}
+	message += "\n**Recommended Actions:**\n"
+	message += "1. Upgrade the affected components and release a new agent version\n"
+	message += "2. Upload the SBOM of the new version\n"
+	message += "3. Restrict the agent's capabilities until it is patched\n"
+
+	return s.alertRepo.Create(&domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: agent.OrganizationID,
+		AlertType:      domain.AlertSBOMVulnerability,
+		Severity:       domain.AlertSeverityCritical,
+		Title:          fmt.Sprintf("Critical Vulnerabilities in Dependencies: %s", agent.Name),
+		Description:    message,
+		ResourceType:   "agent",
+		ResourceID:     agent.ID,
+		CreatedAt:      time.Now(),
+	})
+}
+
+// fetchDocument downloads an SBOM from an HTTPS link
+func (s *SBOMService) fetchDocument(ctx context.Context, link string) ([]byte, error) {
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an https link", ErrInvalidSBOM)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSBOM, err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to fetch document: %v", ErrInvalidSBOM, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%w: fetching document returned status %d", ErrInvalidSBOM, resp.StatusCode)
+	}
+
+	// Read one byte past the limit to tell a full-size document from an oversized one
+	document, err := io.ReadAll(io.LimitReader(resp.Body, MaxSBOMDocumentBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read document: %v", ErrInvalidSBOM, err)
+	}
+	return document, nil
+}
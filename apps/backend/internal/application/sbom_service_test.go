@@ -0,0 +1,120 @@
+package application
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVulnerabilityDatabase reports the vulnerabilities listed per purl
+type fakeVulnerabilityDatabase struct {
+	vulnerabilities map[string][]domain.SBOMVulnerability
+	err             error
+}
+
+func (f *fakeVulnerabilityDatabase) QueryComponents(ctx context.Context, components []domain.SBOMComponent) ([]domain.SBOMVulnerability, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var result []domain.SBOMVulnerability
+	for _, component := range components {
+		result = append(result, f.vulnerabilities[component.PURL]...)
+	}
+	return result, nil
+}
+
+func TestSBOMUploadFlagsCriticalVulnerabilities(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.Version = "1.4.0" })
+	require.NoError(t, repos.Agent.Create(agent))
+	ctx := context.Background()
+
+	vulnDB := &fakeVulnerabilityDatabase{vulnerabilities: map[string][]domain.SBOMVulnerability{
+		"pkg:npm/lodash@4.17.20":  {{ID: "GHSA-crit", Severity: domain.VulnerabilitySeverityCritical, ComponentName: "lodash", ComponentVersion: "4.17.20"}},
+		"pkg:pypi/requests@2.0.0": {{ID: "GHSA-high", Severity: domain.VulnerabilitySeverityHigh, ComponentName: "requests", ComponentVersion: "2.0.0"}},
+	}}
+	service := NewSBOMService(repos.SBOM, repos.Agent, repos.Alert, vulnDB)
+	calculator := NewTrustCalculatorWithVerification(
+		repos.TrustScore, repos.APIKey, repos.AuditLog, repos.Capability, repos.Agent, repos.Alert, repos.VerificationEvent, repos.SBOM,
+	)
+	compliance := func() float64 {
+		factors, err := calculator.CalculateFactors(agent)
+		require.NoError(t, err)
+		return factors.Compliance
+	}
+	assert.Equal(t, 1.0, compliance(), "agents without an SBOM keep the baseline")
+
+	_, err := service.UploadSBOM(ctx, agent, &UploadSBOMRequest{}, nil)
+	assert.ErrorIs(t, err, ErrInvalidSBOM, "a document or url is required")
+	_, err = service.UploadSBOM(ctx, agent, &UploadSBOMRequest{URL: "http://example.com/sbom.json"}, nil)
+	assert.ErrorIs(t, err, ErrInvalidSBOM, "links must be https")
+	_, err = service.UploadSBOM(ctx, agent, &UploadSBOMRequest{Document: []byte(`{"name":"not an sbom"}`)}, nil)
+	assert.ErrorIs(t, err, ErrInvalidSBOM)
+
+	cycloneDX := []byte(`{"bomFormat":"CycloneDX","specVersion":"1.5","components":[
+		{"name":"lodash","version":"4.17.20","purl":"pkg:npm/lodash@4.17.20","components":[
+			{"name":"left-pad","version":"1.3.0","purl":"pkg:npm/left-pad@1.3.0"}
+		]},
+		{"name":"lodash","version":"4.17.20","purl":"pkg:npm/lodash@4.17.20"}
+	]}`)
+	sbom, err := service.UploadSBOM(ctx, agent, &UploadSBOMRequest{Document: cycloneDX}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SBOMFormatCycloneDX, sbom.Format)
+	assert.Equal(t, "1.5", sbom.SpecVersion)
+	assert.Equal(t, "1.4.0", sbom.AgentVersion, "defaults to the agent's version")
+	assert.Equal(t, 2, sbom.ComponentCount, "nested components are included and duplicates dropped")
+	assert.Equal(t, domain.SBOMScanStatusScanned, sbom.ScanStatus)
+	assert.Equal(t, 1, sbom.CriticalCount)
+	assert.Equal(t, 0.0, compliance())
+
+	alerts, err := repos.Alert.GetUnacknowledgedByResourceID(agent.ID)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domain.AlertSBOMVulnerability, alerts[0].AlertType)
+	assert.Equal(t, domain.AlertSeverityCritical, alerts[0].Severity)
+	assert.Contains(t, alerts[0].Description, "GHSA-crit")
+
+	// Rescanning finds no new critical vulnerabilities, so no second alert
+	_, err = service.RescanSBOM(ctx, agent, sbom.ID)
+	require.NoError(t, err)
+	alerts, err = repos.Alert.GetUnacknowledgedByResourceID(agent.ID)
+	require.NoError(t, err)
+	assert.Len(t, alerts, 1)
+
+	// The patched version only has a high severity vulnerability
+	spdx := []byte(`{"spdxVersion":"SPDX-2.3","packages":[
+		{"name":"requests","versionInfo":"2.0.0","externalRefs":[
+			{"referenceCategory":"PACKAGE-MANAGER","referenceType":"purl","referenceLocator":"pkg:pypi/requests@2.0.0"}
+		]},
+		{"name":"internal-tool","versionInfo":"0.1.0"}
+	]}`)
+	patched, err := service.UploadSBOM(ctx, agent, &UploadSBOMRequest{AgentVersion: "1.5.0", Document: spdx}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SBOMFormatSPDX, patched.Format)
+	assert.Equal(t, "2.3", patched.SpecVersion)
+	assert.Equal(t, 2, patched.ComponentCount)
+	assert.Equal(t, 0, patched.CriticalCount)
+	assert.Equal(t, 1, patched.HighCount)
+	assert.Equal(t, 0.5, compliance())
+
+	sboms, err := service.ListSBOMs(ctx, agent.ID)
+	require.NoError(t, err)
+	require.Len(t, sboms, 2)
+	assert.Equal(t, patched.ID, sboms[0].ID)
+	_, err = service.GetSBOM(ctx, uuid.New(), sbom.ID)
+	assert.ErrorIs(t, err, ErrSBOMNotFound, "SBOMs are scoped to their agent")
+
+	// A failed scan keeps the SBOM and records the error
+	vulnDB.err = io.ErrUnexpectedEOF
+	failed, err := service.UploadSBOM(ctx, agent, &UploadSBOMRequest{Document: cycloneDX}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SBOMScanStatusFailed, failed.ScanStatus)
+	require.NotNil(t, failed.ScanError)
+}
@@ -0,0 +1,33 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// SchemaService reports the database schema version to operators, so upgrades can be checked
+// before and after they are rolled out
+type SchemaService struct {
+	migrator  domain.SchemaMigrator
+	autoApply bool // Whether the server applies pending migrations on startup
+}
+
+// NewSchemaService creates a new schema service
+func NewSchemaService(migrator domain.SchemaMigrator, autoApply bool) *SchemaService {
+	return &SchemaService{
+		migrator:  migrator,
+		autoApply: autoApply,
+	}
+}
+
+// GetStatus returns the current schema version, the pending migrations and the migration history
+func (s *SchemaService) GetStatus(ctx context.Context) (*domain.SchemaStatus, error) {
+	status, err := s.migrator.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema status: %w", err)
+	}
+	status.AutoApply = s.autoApply
+	return status, nil
+}
@@ -2,21 +2,28 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
+// ErrSDKDeviceNotFound is returned when the user has no active SDK tokens on a device
+var ErrSDKDeviceNotFound = errors.New("sdk device not found")
+
 // SDKTokenService handles SDK token business logic
 type SDKTokenService struct {
 	sdkTokenRepo domain.SDKTokenRepository
+	ipLocator    domain.IPLocator // Locates the addresses devices were last used from
 }
 
 // NewSDKTokenService creates a new SDK token service
-func NewSDKTokenService(sdkTokenRepo domain.SDKTokenRepository) *SDKTokenService {
+func NewSDKTokenService(sdkTokenRepo domain.SDKTokenRepository, ipLocator domain.IPLocator) *SDKTokenService {
 	return &SDKTokenService{
 		sdkTokenRepo: sdkTokenRepo,
+		ipLocator:    ipLocator,
 	}
 }
 
@@ -47,6 +54,106 @@ func (s *SDKTokenService) RevokeToken(ctx context.Context, tokenID uuid.UUID, us
 	return s.sdkTokenRepo.Revoke(tokenID, reason)
 }
 
+// ListUserDevices returns the devices holding the user's active SDK tokens, most recently used first
+func (s *SDKTokenService) ListUserDevices(ctx context.Context, userID uuid.UUID) ([]*domain.SDKDevice, error) {
+	tokens, err := s.activeTokens(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*domain.SDKDevice)
+	devices := make([]*domain.SDKDevice, 0)
+	for _, token := range tokens {
+		id := sdkDeviceID(token)
+		device, ok := byID[id]
+		if !ok {
+			device = &domain.SDKDevice{
+				ID:                id,
+				DeviceName:        token.DeviceName,
+				DeviceFingerprint: token.DeviceFingerprint,
+				FirstSeenAt:       token.CreatedAt,
+			}
+			byID[id] = device
+			devices = append(devices, device)
+		}
+
+		device.ActiveTokens++
+		device.UsageCount += token.UsageCount
+		if token.CreatedAt.Before(device.FirstSeenAt) {
+			device.FirstSeenAt = token.CreatedAt
+		}
+		if token.ExpiresAt.After(device.ExpiresAt) {
+			device.ExpiresAt = token.ExpiresAt
+		}
+		// The address of a token never refreshed is the one it was downloaded from
+		usedAt, usedFrom := token.LastUsedAt, token.LastIPAddress
+		if usedAt == nil {
+			usedAt, usedFrom = &token.CreatedAt, token.IPAddress
+		}
+		if device.LastUsedAt == nil || usedAt.After(*device.LastUsedAt) {
+			device.LastUsedAt = usedAt
+			device.LastIPAddress = usedFrom
+			device.UserAgent = token.UserAgent
+		}
+	}
+
+	for _, device := range devices {
+		if device.LastIPAddress != nil && s.ipLocator != nil {
+			device.LastLocation = s.ipLocator.Locate(*device.LastIPAddress)
+		}
+	}
+	sort.SliceStable(devices, func(i, j int) bool {
+		return devices[i].LastUsedAt.After(*devices[j].LastUsedAt)
+	})
+	return devices, nil
+}
+
+// RevokeDevice revokes every active SDK token of the user on the device
+func (s *SDKTokenService) RevokeDevice(ctx context.Context, userID uuid.UUID, deviceID string, reason string) (int, error) {
+	tokens, err := s.activeTokens(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, token := range tokens {
+		if sdkDeviceID(token) != deviceID {
+			continue
+		}
+		if err := s.sdkTokenRepo.Revoke(token.ID, reason); err != nil {
+			return revoked, fmt.Errorf("failed to revoke sdk token %s: %w", token.ID, err)
+		}
+		revoked++
+	}
+	if revoked == 0 {
+		return 0, ErrSDKDeviceNotFound
+	}
+	return revoked, nil
+}
+
+// activeTokens returns the user's tokens that are neither revoked nor expired
+func (s *SDKTokenService) activeTokens(userID uuid.UUID) ([]*domain.SDKToken, error) {
+	tokens, err := s.sdkTokenRepo.GetByUserID(userID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sdk tokens: %w", err)
+	}
+	active := make([]*domain.SDKToken, 0, len(tokens))
+	for _, token := range tokens {
+		if token.IsActive() {
+			active = append(active, token)
+		}
+	}
+	return active, nil
+}
+
+// sdkDeviceID identifies the device holding a token
+func sdkDeviceID(token *domain.SDKToken) string {
+	if token.DeviceFingerprint != nil && *token.DeviceFingerprint != "" {
+		return *token.DeviceFingerprint
+	}
+	return token.ID.String()
+}
+
 // RevokeByTokenHash revokes a token using its hash (for token rotation)
 func (s *SDKTokenService) RevokeByTokenHash(ctx context.Context, tokenHash string, reason string) error {
 	return s.sdkTokenRepo.RevokeByTokenHash(tokenHash, reason)
@@ -0,0 +1,83 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/geoip"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDKDevicesGroupTheUsersActiveTokensAndCanBeRevoked(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	locator, err := geoip.ReadLocator(strings.NewReader("81.2.69.0,81.2.69.255,GB\n"))
+	require.NoError(t, err)
+	service := NewSDKTokenService(repos.SDKToken, locator)
+	ctx := context.Background()
+	userID, otherUserID := uuid.New(), uuid.New()
+
+	now := time.Now()
+	track := func(userID uuid.UUID, fingerprint, ip string, createdAt time.Time) *domain.SDKToken {
+		name := "Python SDK (" + fingerprint + ")"
+		token := &domain.SDKToken{
+			UserID:            userID,
+			OrganizationID:    uuid.New(),
+			TokenHash:         uuid.NewString(),
+			TokenID:           uuid.NewString(),
+			DeviceName:        &name,
+			DeviceFingerprint: &fingerprint,
+			IPAddress:         &ip,
+			CreatedAt:         createdAt,
+			ExpiresAt:         createdAt.Add(90 * 24 * time.Hour),
+		}
+		require.NoError(t, repos.SDKToken.Create(token))
+		return token
+	}
+	// The laptop downloaded a token at the office and rotated it from home
+	downloaded := track(userID, "laptop", "10.0.0.5", now.Add(-48*time.Hour))
+	track(userID, "laptop", "10.0.0.5", now.Add(-24*time.Hour))
+	require.NoError(t, repos.SDKToken.RecordUsage(downloaded.TokenID, "81.2.69.142"))
+	track(userID, "ci-runner", "10.9.9.9", now.Add(-72*time.Hour))
+	revoked := track(userID, "old-desktop", "10.1.1.1", now.Add(-time.Hour))
+	require.NoError(t, repos.SDKToken.Revoke(revoked.ID, "replaced"))
+	track(otherUserID, "laptop", "10.0.0.5", now)
+
+	devices, err := service.ListUserDevices(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	laptop := devices[0]
+	assert.Equal(t, "laptop", laptop.ID)
+	assert.Equal(t, 2, laptop.ActiveTokens)
+	assert.Equal(t, 1, laptop.UsageCount)
+	assert.Equal(t, "81.2.69.142", *laptop.LastIPAddress)
+	assert.Equal(t, &domain.IPLocation{Country: "GB"}, laptop.LastLocation)
+	assert.WithinDuration(t, now.Add(-48*time.Hour), laptop.FirstSeenAt, time.Second)
+	// A device whose tokens were never refreshed was last seen where it downloaded them
+	assert.Equal(t, "ci-runner", devices[1].ID)
+	assert.Equal(t, "10.9.9.9", *devices[1].LastIPAddress)
+	assert.Equal(t, &domain.IPLocation{Network: domain.IPNetworkPrivate}, devices[1].LastLocation)
+
+	// Revoking a device revokes all of its tokens, and only the user's
+	revokedTokens, err := service.RevokeDevice(ctx, userID, "laptop", "lost")
+	require.NoError(t, err)
+	assert.Equal(t, 2, revokedTokens)
+	_, err = service.RevokeDevice(ctx, userID, "laptop", "lost")
+	assert.ErrorIs(t, err, ErrSDKDeviceNotFound)
+	_, err = service.RevokeDevice(ctx, userID, "old-desktop", "lost")
+	assert.ErrorIs(t, err, ErrSDKDeviceNotFound)
+	others, err := service.ListUserDevices(ctx, otherUserID)
+	require.NoError(t, err)
+	require.Len(t, others, 1)
+	assert.Equal(t, 1, others[0].ActiveTokens)
+
+	require.NoError(t, service.RevokeAllUserTokens(ctx, userID, "signed out everywhere"))
+	devices, err = service.ListUserDevices(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, devices)
+}
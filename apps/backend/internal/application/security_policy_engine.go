@@ -0,0 +1,214 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/cel"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/tracing"
+)
+
+// ErrInvalidPolicyExpression is returned when an expression policy has no valid rules.expression
+var ErrInvalidPolicyExpression = errors.New("invalid policy expression")
+
+// policyExpressionVariables are the variables an expression policy may reference
+var policyExpressionVariables = []string{"agent", "trustScore", "capabilities", "drift", "event"}
+
+// policyExpressions holds compiled policy expressions. An expression is compiled when the policy
+// is saved and reused for every verification.
+var policyExpressions = cel.NewCache(policyExpressionVariables...)
+
+// PolicyEvaluationContext is what expression policies are evaluated against
+type PolicyEvaluationContext struct {
+	Agent        *domain.Agent
+	Capabilities []string                  // Capabilities the agent reported; its declared ones otherwise
+	Drift        *DriftResult              // Optional: drift detected for the reported configuration
+	Event        *domain.VerificationEvent // Optional: the verification being recorded
+}
+
+// variables exposes the context to expressions. Structs are addressed by their JSON field names.
+func (c *PolicyEvaluationContext) variables() map[string]interface{} {
+	agent := map[string]interface{}{}
+	if data, err := cel.Normalize(c.Agent); err == nil {
+		agent, _ = data.(map[string]interface{})
+	}
+
+	capabilities := c.Capabilities
+	if len(capabilities) == 0 {
+		capabilities = c.Agent.Capabilities
+	}
+
+	drift := map[string]interface{}{
+		"detected":     false,
+		"mcpServers":   []interface{}{},
+		"capabilities": []interface{}{},
+	}
+	if c.Drift != nil {
+		drift["detected"] = c.Drift.DriftDetected
+		drift["mcpServers"] = stringsToInterfaces(c.Drift.MCPServerDrift)
+		drift["capabilities"] = stringsToInterfaces(c.Drift.CapabilityDrift)
+	}
+
+	event := map[string]interface{}{}
+	if c.Event != nil {
+		if data, err := cel.Normalize(c.Event); err == nil {
+			event, _ = data.(map[string]interface{})
+		}
+	}
+
+	return map[string]interface{}{
+		"agent":        agent,
+		"trustScore":   c.Agent.TrustScore,
+		"capabilities": stringsToInterfaces(capabilities),
+		"drift":        drift,
+		"event":        event,
+	}
+}
+
+// validatePolicyRules checks that an expression policy's rules.expression compiles
+func validatePolicyRules(policy *domain.SecurityPolicy) error {
+	if policy.PolicyType != domain.PolicyTypeExpression {
+		return nil
+	}
+	expression, _ := policy.Rules["expression"].(string)
+	if strings.TrimSpace(expression) == "" {
+		return fmt.Errorf("%w: rules.expression is required", ErrInvalidPolicyExpression)
+	}
+	if _, err := policyExpressions.Compile(expression); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPolicyExpression, err)
+	}
+	return nil
+}
+
+// EvaluateExpressionPolicies evaluates the organization's enabled expression policies that apply
+// to the agent, highest priority first, and returns one result per policy. A policy triggers when
+// its rules.expression is true; triggered policies that alert raise a policy_violation alert.
+//
+// An expression that fails to evaluate (e.g. it reads a missing field) does not trigger.
+func (s *SecurityPolicyService) EvaluateExpressionPolicies(
+	ctx context.Context,
+	evalCtx *PolicyEvaluationContext,
+) ([]*domain.PolicyEvaluationResult, error) {
+	agent := evalCtx.Agent
+	ctx, span := tracing.Start(ctx, "SecurityPolicyService.EvaluateExpressionPolicies",
+		tracing.String("aim.agent_id", agent.ID.String()))
+	defer span.End()
+
+	var policies []*domain.SecurityPolicy
+	err := traceRepository(ctx, "SecurityPolicyRepository", "GetByType", func() (err error) {
+		policies, err = s.policyRepo.GetByType(agent.OrganizationID, domain.PolicyTypeExpression)
+		return err
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to fetch expression policies: %w", err)
+	}
+
+	results := make([]*domain.PolicyEvaluationResult, 0, len(policies))
+	var vars map[string]interface{}
+	for _, policy := range policies {
+		if !policy.IsEnabled || !s.policyAppliesToAgent(policy, agent) {
+			continue
+		}
+		if vars == nil {
+			vars = evalCtx.variables()
+		}
+
+		result := &domain.PolicyEvaluationResult{
+			PolicyID:          policy.ID,
+			PolicyName:        policy.Name,
+			EnforcementAction: policy.EnforcementAction,
+		}
+		results = append(results, result)
+
+		expression, _ := policy.Rules["expression"].(string)
+		program, err := policyExpressions.Compile(expression)
+		if err != nil {
+			result.Reason = fmt.Sprintf("invalid expression: %v", err)
+			continue
+		}
+		triggered, err := program.EvalBool(vars)
+		if err != nil {
+			fmt.Printf("⚠️  Policy '%s' expression failed for agent %s: %v\n", policy.Name, agent.Name, err)
+			result.Reason = fmt.Sprintf("expression failed: %v", err)
+			continue
+		}
+		if !triggered {
+			continue
+		}
+
+		result.Triggered = true
+		result.Reason = policyTriggerReason(policy)
+		switch policy.EnforcementAction {
+		case domain.EnforcementBlockAndAlert:
+			result.ShouldBlock, result.ShouldAlert = true, true
+		case domain.EnforcementAlertOnly:
+			result.ShouldAlert = true
+		}
+		fmt.Printf("✅ Security Policy '%s' triggered for agent %s (action: %s)\n",
+			policy.Name, agent.Name, policy.EnforcementAction)
+
+		if result.ShouldAlert {
+			s.raisePolicyAlert(ctx, policy, agent, result)
+		}
+	}
+
+	triggered := 0
+	for _, result := range results {
+		if result.Triggered {
+			triggered++
+		}
+	}
+	span.SetAttributes(tracing.Int("aim.policies.evaluated", len(results)), tracing.Int("aim.policies.triggered", triggered))
+	return results, nil
+}
+
+// raisePolicyAlert records an alert for a triggered expression policy
+func (s *SecurityPolicyService) raisePolicyAlert(ctx context.Context, policy *domain.SecurityPolicy, agent *domain.Agent, result *domain.PolicyEvaluationResult) {
+	severity := domain.AlertSeverityHigh
+	switch policy.SeverityThreshold {
+	case domain.AlertSeverityInfo, domain.AlertSeverityWarning, domain.AlertSeverityCritical:
+		severity = policy.SeverityThreshold
+	}
+
+	outcome := "allowed"
+	if result.ShouldBlock {
+		outcome = "blocked"
+	}
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: agent.OrganizationID,
+		AlertType:      domain.AlertPolicyViolation,
+		Severity:       severity,
+		Title:          fmt.Sprintf("Policy '%s' triggered: %s", policy.Name, agent.Name),
+		Description:    fmt.Sprintf("%s (action %s)", result.Reason, outcome),
+		ResourceType:   "agent",
+		ResourceID:     agent.ID,
+		CreatedAt:      time.Now(),
+	}
+	if err := traceRepository(ctx, "AlertRepository", "Create", func() error { return s.alertRepo.Create(alert) }); err != nil {
+		fmt.Printf("⚠️  Failed to create alert for policy '%s': %v\n", policy.Name, err)
+	}
+}
+
+// policyTriggerReason explains a triggered policy, preferring its description
+func policyTriggerReason(policy *domain.SecurityPolicy) string {
+	if policy.Description != "" {
+		return policy.Description
+	}
+	expression, _ := policy.Rules["expression"].(string)
+	return fmt.Sprintf("Expression matched: %s", strings.TrimSpace(expression))
+}
+
+func stringsToInterfaces(values []string) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
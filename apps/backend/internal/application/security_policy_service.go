@@ -12,9 +12,11 @@ import (
 
 // SecurityPolicyService handles security policy evaluation and management
 type SecurityPolicyService struct {
-	policyRepo   domain.SecurityPolicyRepository
-	alertRepo    domain.AlertRepository
-	auditLogRepo domain.AuditLogRepository
+	policyRepo        domain.SecurityPolicyRepository
+	alertRepo         domain.AlertRepository
+	auditLogRepo      domain.AuditLogRepository
+	mcpCapabilityRepo domain.MCPServerCapabilityRepository // Optional: risk overrides of MCP tools
+	approvals         *ApprovalChainService                // Optional: approval chains for disabling policies
 }
 
 // NewSecurityPolicyService creates a new security policy service
@@ -22,11 +24,15 @@ func NewSecurityPolicyService(
 	policyRepo domain.SecurityPolicyRepository,
 	alertRepo domain.AlertRepository,
 	auditLogRepo domain.AuditLogRepository,
+	mcpCapabilityRepo domain.MCPServerCapabilityRepository,
+	approvals *ApprovalChainService,
 ) *SecurityPolicyService {
 	return &SecurityPolicyService{
-		policyRepo:   policyRepo,
-		alertRepo:    alertRepo,
-		auditLogRepo: auditLogRepo,
+		policyRepo:        policyRepo,
+		alertRepo:         alertRepo,
+		auditLogRepo:      auditLogRepo,
+		mcpCapabilityRepo: mcpCapabilityRepo,
+		approvals:         approvals,
 	}
 }
 
@@ -195,11 +201,17 @@ func (s *SecurityPolicyService) GetPolicy(ctx context.Context, id uuid.UUID) (*d
 
 // CreatePolicy creates a new security policy
 func (s *SecurityPolicyService) CreatePolicy(ctx context.Context, policy *domain.SecurityPolicy) error {
+	if err := validatePolicyRules(policy); err != nil {
+		return err
+	}
 	return s.policyRepo.Create(policy)
 }
 
 // UpdatePolicy updates a security policy
 func (s *SecurityPolicyService) UpdatePolicy(ctx context.Context, policy *domain.SecurityPolicy) error {
+	if err := validatePolicyRules(policy); err != nil {
+		return err
+	}
 	return s.policyRepo.Update(policy)
 }
 
@@ -219,15 +231,34 @@ func (s *SecurityPolicyService) EnablePolicy(ctx context.Context, id uuid.UUID)
 	return s.policyRepo.Update(policy)
 }
 
-// DisablePolicy disables a security policy
-func (s *SecurityPolicyService) DisablePolicy(ctx context.Context, id uuid.UUID) error {
+// DisablePolicy disables a security policy on behalf of the user. When an approval chain guards
+// policy disabling, the call records the user's approval and the policy stays enabled until the
+// returned request is approved.
+func (s *SecurityPolicyService) DisablePolicy(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*domain.ApprovalRequest, error) {
 	policy, err := s.policyRepo.GetByID(id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	var approval *domain.ApprovalRequest
+	if s.approvals != nil && policy.IsEnabled {
+		approval, err = s.approvals.Approve(ctx, ApprovalSubject{
+			OrganizationID: policy.OrganizationID,
+			Action:         domain.ApprovalActionPolicyDisable,
+			ResourceType:   "security_policy",
+			ResourceID:     policy.ID,
+			Summary:        fmt.Sprintf("Disable security policy '%s'", policy.Name),
+		}, userID, "")
+		if err != nil {
+			return approval, err
+		}
+		if approval != nil && approval.Status != domain.ApprovalRequestStatusApproved {
+			return approval, nil
+		}
 	}
 
 	policy.IsEnabled = false
-	return s.policyRepo.Update(policy)
+	return approval, s.policyRepo.Update(policy)
 }
 
 // EvaluateTrustScoreLow evaluates security policies for low trust score agents
@@ -654,6 +685,31 @@ func (s *SecurityPolicyService) EvaluateConfigDrift(
 	return false, false, "", nil
 }
 
+// ConfigDriftEnforcement returns the enforcement action of the highest priority enabled
+// config_drift policy applying to the agent, for drift an admin rejected. Without one the
+// safe default (block + alert) applies.
+func (s *SecurityPolicyService) ConfigDriftEnforcement(ctx context.Context, agent *domain.Agent) (domain.EnforcementAction, string, error) {
+	policies, err := s.policyRepo.GetByType(agent.OrganizationID, domain.PolicyTypeConfigDrift)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch config drift policies: %w", err)
+	}
+
+	for _, policy := range policies {
+		if !policy.IsEnabled || !s.policyAppliesToAgent(policy, agent) {
+			continue
+		}
+		switch policy.EnforcementAction {
+		case domain.EnforcementBlockAndAlert, domain.EnforcementAlertOnly, domain.EnforcementAllow:
+			return policy.EnforcementAction, policy.Name, nil
+		default:
+			// Unknown enforcement action - use safe default
+			return domain.EnforcementBlockAndAlert, policy.Name, nil
+		}
+	}
+
+	return domain.EnforcementBlockAndAlert, "default_policy", nil
+}
+
 // EvaluateUnauthorizedAccess evaluates security policies for unauthorized access attempts
 // Returns enforcement decision and whether to create an alert
 func (s *SecurityPolicyService) EvaluateUnauthorizedAccess(
@@ -853,3 +909,137 @@ func (s *SecurityPolicyService) EvaluateUnauthorizedAccess(
 
 	return false, false, "", nil
 }
+
+// mcpToolActionPrefix is the action type prefix agents use when calling an MCP tool
+const mcpToolActionPrefix = "mcp_tool:"
+
+// SensitiveToolDecision is the outcome of the sensitive_tool policy that matched an MCP tool call
+type SensitiveToolDecision struct {
+	Tool             string
+	Risk             domain.MCPToolRisk
+	PolicyName       string
+	ShouldBlock      bool
+	RequiresApproval bool // Held for just-in-time admin approval instead of being denied outright
+	ShouldAlert      bool
+	Reason           string
+}
+
+// EvaluateSensitiveTool evaluates sensitive_tool policies when an agent calls an MCP tool
+// (action type "mcp_tool:<name>"). A policy's rules may set:
+//   - risk_levels: tool risks it covers (default: destructive and exfiltration)
+//   - min_trust_score: agents below this trust score are blocked
+//   - require_approval: every call is held for admin approval
+//
+// A policy with neither condition enforces on every call of a covered tool.
+// Returns nil when the action is not a tool call or no policy triggered.
+func (s *SecurityPolicyService) EvaluateSensitiveTool(
+	ctx context.Context,
+	agent *domain.Agent,
+	actionType string,
+	metadata map[string]interface{},
+) (*SensitiveToolDecision, error) {
+	toolName, ok := strings.CutPrefix(actionType, mcpToolActionPrefix)
+	if !ok || toolName == "" {
+		return nil, nil
+	}
+
+	policies, err := s.policyRepo.GetByType(agent.OrganizationID, domain.PolicyTypeSensitiveTool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sensitive tool policies: %w", err)
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+
+	risk := s.resolveMCPToolRisk(toolName, metadata)
+
+	for _, policy := range policies {
+		if !policy.IsEnabled || !s.policyAppliesToAgent(policy, agent) {
+			continue
+		}
+		if !policyCoversToolRisk(policy, risk) {
+			continue
+		}
+
+		minTrust, hasMinTrust := policy.Rules["min_trust_score"].(float64)
+		requireApproval, _ := policy.Rules["require_approval"].(bool)
+		trustTooLow := hasMinTrust && agent.TrustScore < minTrust
+		if hasMinTrust && !trustTooLow && !requireApproval {
+			continue
+		}
+
+		decision := &SensitiveToolDecision{
+			Tool:       toolName,
+			Risk:       risk,
+			PolicyName: policy.Name,
+		}
+		switch {
+		case trustTooLow:
+			decision.Reason = fmt.Sprintf("%s tool '%s' requires trust score %.2f (agent: %.2f)", risk, toolName, minTrust, agent.TrustScore)
+		case requireApproval:
+			decision.Reason = fmt.Sprintf("%s tool '%s' requires just-in-time admin approval", risk, toolName)
+		default:
+			decision.Reason = fmt.Sprintf("%s tool '%s' is flagged as sensitive", risk, toolName)
+		}
+
+		fmt.Printf("✅ Sensitive Tool Policy '%s' triggered for agent %s (%s)\n", policy.Name, agent.Name, decision.Reason)
+
+		switch policy.EnforcementAction {
+		case domain.EnforcementBlockAndAlert:
+			if requireApproval && !trustTooLow {
+				decision.RequiresApproval = true
+			} else {
+				decision.ShouldBlock = true
+				decision.ShouldAlert = true
+			}
+		case domain.EnforcementAlertOnly:
+			decision.ShouldAlert = true
+		}
+		return decision, nil
+	}
+
+	return nil, nil
+}
+
+// resolveMCPToolRisk classifies the tool, honouring an admin override when the call names the
+// MCP server (metadata "mcp_server_id") and the server has the tool registered
+func (s *SecurityPolicyService) resolveMCPToolRisk(toolName string, metadata map[string]interface{}) domain.MCPToolRisk {
+	if s.mcpCapabilityRepo != nil {
+		if rawID, ok := metadata["mcp_server_id"].(string); ok {
+			if serverID, err := uuid.Parse(rawID); err == nil {
+				tools, err := s.mcpCapabilityRepo.GetByServerIDAndType(serverID, domain.MCPCapabilityTypeTool)
+				if err == nil {
+					for _, tool := range tools {
+						if tool.Name == toolName {
+							classifyMCPCapability(tool)
+							return tool.RiskLevel
+						}
+					}
+				}
+			}
+		}
+	}
+	return ClassifyMCPToolRisk(toolName, domain.MCPCapabilityTypeTool)
+}
+
+// policyCoversToolRisk checks the policy's risk_levels rule, defaulting to sensitive tools
+func policyCoversToolRisk(policy *domain.SecurityPolicy, risk domain.MCPToolRisk) bool {
+	switch levels := policy.Rules["risk_levels"].(type) {
+	case []interface{}: // Decoded from JSON
+		for _, level := range levels {
+			if value, ok := level.(string); ok && domain.MCPToolRisk(value) == risk {
+				return true
+			}
+		}
+		return false
+	case []string:
+		for _, level := range levels {
+			if domain.MCPToolRisk(level) == risk {
+				return true
+			}
+		}
+		return false
+	default:
+		return risk.IsSensitive()
+	}
+}
@@ -0,0 +1,153 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensitiveMCPToolPolicies(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	agent := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(agent))
+	require.NoError(t, repos.Capability.CreateCapability(testsupport.NewAgentCapability(agent, "mcp_tool:*")))
+
+	server := testsupport.NewMCPServer(org.ID)
+	require.NoError(t, repos.MCPServer.Create(server))
+	exfiltration := domain.MCPToolRiskExfiltration
+	for _, tool := range []*domain.MCPServerCapability{
+		{MCPServerID: server.ID, Name: "delete_records", CapabilityType: domain.MCPCapabilityTypeTool, IsActive: true},
+		{MCPServerID: server.ID, Name: "get_report", CapabilityType: domain.MCPCapabilityTypeTool, IsActive: true, RiskOverride: &exfiltration},
+	} {
+		require.NoError(t, repos.MCPServerCapability.Create(tool))
+	}
+
+	policyService := NewSecurityPolicyService(repos.SecurityPolicy, repos.Alert, repos.AuditLog, repos.MCPServerCapability, nil)
+	agentService := NewAgentService(repos.Agent, nil, repos.TrustScore, nil, repos.Alert, policyService, repos.Capability, nil, nil, repos.Organization, nil, nil, nil, nil)
+	ctx := context.Background()
+	onServer := map[string]interface{}{"mcp_server_id": server.ID.String()}
+
+	allowed, _, _, err := agentService.VerifyAction(ctx, agent.ID, "mcp_tool:delete_records", "", onServer)
+	require.NoError(t, err)
+	assert.True(t, allowed, "tools are not restricted until a sensitive_tool policy exists")
+
+	policy := &domain.SecurityPolicy{
+		OrganizationID:    org.ID,
+		Name:              "Trusted agents only",
+		PolicyType:        domain.PolicyTypeSensitiveTool,
+		EnforcementAction: domain.EnforcementBlockAndAlert,
+		Rules:             map[string]interface{}{"min_trust_score": 0.9},
+		AppliesTo:         "all",
+		IsEnabled:         true,
+	}
+	require.NoError(t, repos.SecurityPolicy.Create(policy))
+
+	allowed, reason, _, err := agentService.VerifyAction(ctx, agent.ID, "mcp_tool:delete_records", "", onServer)
+	require.NoError(t, err)
+	assert.False(t, allowed, "agents below the trust score cannot use destructive tools")
+	assert.False(t, IsApprovalRequired(reason))
+
+	allowed, _, _, err = agentService.VerifyAction(ctx, agent.ID, "mcp_tool:search_docs", "", onServer)
+	require.NoError(t, err)
+	assert.True(t, allowed, "read tools are not covered by default")
+
+	policy.Rules = map[string]interface{}{"require_approval": true}
+	require.NoError(t, repos.SecurityPolicy.Update(policy))
+
+	allowed, reason, _, err = agentService.VerifyAction(ctx, agent.ID, "mcp_tool:get_report", "", onServer)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.True(t, IsApprovalRequired(reason), "the admin override flags get_report as exfiltration-capable")
+
+	allowed, _, _, err = agentService.VerifyAction(ctx, agent.ID, "mcp_tool:get_report", "", nil)
+	require.NoError(t, err)
+	assert.True(t, allowed, "without the server the override is unknown and get_report classifies as read")
+}
+
+func TestExpressionPoliciesDenyVerifications(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) {
+		a.TalksTo = []string{"filesystem-mcp"}
+		a.Capabilities = []string{"file_read", "shell_exec"}
+		a.TrustScore = 0.4
+	})
+	require.NoError(t, repos.Agent.Create(agent))
+
+	policyService := NewSecurityPolicyService(repos.SecurityPolicy, repos.Alert, repos.AuditLog, repos.MCPServerCapability, nil)
+	ctx := context.Background()
+	newPolicy := func(name, expression string, action domain.EnforcementAction, priority int) *domain.SecurityPolicy {
+		return &domain.SecurityPolicy{
+			OrganizationID:    org.ID,
+			Name:              name,
+			PolicyType:        domain.PolicyTypeExpression,
+			EnforcementAction: action,
+			SeverityThreshold: domain.AlertSeverityCritical,
+			Rules:             map[string]interface{}{"expression": expression},
+			AppliesTo:         "all",
+			IsEnabled:         true,
+			Priority:          priority,
+		}
+	}
+
+	err := policyService.CreatePolicy(ctx, newPolicy("Typo", `agnet.trustScore < 0.5`, domain.EnforcementBlockAndAlert, 1))
+	assert.ErrorIs(t, err, ErrInvalidPolicyExpression, "unknown variables are rejected at save time")
+	err = policyService.CreatePolicy(ctx, newPolicy("Empty", "", domain.EnforcementBlockAndAlert, 1))
+	assert.ErrorIs(t, err, ErrInvalidPolicyExpression)
+
+	block := newPolicy("Untrusted drift", `drift.detected && trustScore < 0.5`, domain.EnforcementBlockAndAlert, 10)
+	block.Description = "Low-trust agents may not run with undeclared MCP servers"
+	require.NoError(t, policyService.CreatePolicy(ctx, block))
+	require.NoError(t, policyService.CreatePolicy(ctx, newPolicy("Shell use", `"shell_exec" in capabilities`, domain.EnforcementAlertOnly, 5)))
+
+	driftService := NewDriftDetectionService(repos.Agent, repos.Alert)
+	service := NewVerificationEventService(repos.VerificationEvent, repos.Agent, driftService, nil, policyService, nil)
+	verify := func(servers, capabilities []string) *domain.VerificationEvent {
+		event, err := service.CreateVerificationEvent(ctx, &CreateVerificationEventRequest{
+			OrganizationID:      org.ID,
+			AgentID:             agent.ID,
+			Protocol:            domain.VerificationProtocolMCP,
+			VerificationType:    domain.VerificationTypeIdentity,
+			Status:              domain.VerificationEventStatusSuccess,
+			InitiatorType:       domain.InitiatorTypeSystem,
+			CurrentMCPServers:   servers,
+			CurrentCapabilities: capabilities,
+		})
+		require.NoError(t, err)
+		return event
+	}
+
+	allowed := verify([]string{"filesystem-mcp"}, []string{"file_read"})
+	assert.Equal(t, domain.VerificationEventStatusSuccess, allowed.Status)
+	assert.NotContains(t, allowed.Metadata, "policyEvaluations")
+
+	denied := verify([]string{"filesystem-mcp", "shell-mcp"}, []string{"file_read"})
+	assert.Equal(t, domain.VerificationEventStatusFailed, denied.Status)
+	require.NotNil(t, denied.Result)
+	assert.Equal(t, domain.VerificationResultDenied, *denied.Result)
+	require.NotNil(t, denied.ErrorReason)
+	assert.Contains(t, *denied.ErrorReason, block.Description)
+
+	alerted := verify([]string{"filesystem-mcp"}, []string{"shell_exec"})
+	assert.Equal(t, domain.VerificationEventStatusSuccess, alerted.Status, "alert_only policies do not deny")
+	results, ok := alerted.Metadata["policyEvaluations"].([]*domain.PolicyEvaluationResult)
+	require.True(t, ok)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Shell use", results[0].PolicyName)
+
+	alerts, err := repos.Alert.GetByResourceID(agent.ID, 10, 0)
+	require.NoError(t, err)
+	policyAlerts := 0
+	for _, alert := range alerts {
+		if alert.AlertType == domain.AlertPolicyViolation {
+			policyAlerts++
+			assert.Equal(t, domain.AlertSeverityCritical, alert.Severity)
+		}
+	}
+	assert.Equal(t, 2, policyAlerts)
+}
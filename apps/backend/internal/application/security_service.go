@@ -2,22 +2,53 @@ package application
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
-	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
 )
 
+// MaxThreatAnnotationLength caps the length of a threat annotation note
+const MaxThreatAnnotationLength = 4000
+
+var (
+	// ErrThreatNotFound is returned for unknown threats and threats of another organization
+	ErrThreatNotFound = errors.New("threat not found")
+	// ErrInvalidThreatAnnotation is returned for empty or oversized annotation notes
+	ErrInvalidThreatAnnotation = errors.New("invalid threat annotation")
+)
+
+// threatAlertTypes lists the alert types that threats are read from, for filtering by threat type
+var threatAlertTypes = []domain.AlertType{
+	domain.AlertCertificateExpiring,
+	domain.AlertAPIKeyExpiring,
+	domain.AlertTrustScoreLow,
+	domain.AlertTrustScoreDrop,
+	domain.AlertAgentOffline,
+	domain.AlertSecurityBreach,
+	domain.AlertUnusualActivity,
+	domain.AlertTypeConfigurationDrift,
+	domain.AlertTypeCapabilityDrift,
+	domain.AlertMCPServerPendingReview,
+	domain.AlertEmergencyAccessUsed,
+	domain.AlertSBOMVulnerability,
+	domain.AlertSharedCredential,
+	domain.AlertTypePeerDrift,
+	domain.AlertTypeMCPToolDrift,
+}
+
 type SecurityService struct {
-	securityRepo *repository.SecurityRepository
-	agentRepo    *repository.AgentRepository
+	securityRepo domain.SecurityRepository
+	agentRepo    domain.AgentRepository
 	alertRepo    domain.AlertRepository  // ✅ NEW: For converting alerts to threats
 }
 
 func NewSecurityService(
-	securityRepo *repository.SecurityRepository,
-	agentRepo *repository.AgentRepository,
+	securityRepo domain.SecurityRepository,
+	agentRepo domain.AgentRepository,
 	alertRepo domain.AlertRepository,
 ) *SecurityService {
 	return &SecurityService{
@@ -39,33 +70,140 @@ func (s *SecurityService) GetThreats(ctx context.Context, orgID uuid.UUID, limit
 	// Convert alerts to threats for display in Security Dashboard
 	threats := make([]*domain.Threat, 0, len(alerts))
 	for _, alert := range alerts {
-		// Map alert type to threat type
-		threatType := mapAlertTypeToThreatType(alert.AlertType)
-
-		// Create target name (short ID for display)
-		targetName := alert.ResourceID.String()[:8] + "..."
-
-		// Create threat from alert
-		threat := &domain.Threat{
-			ID:             alert.ID,
-			OrganizationID: alert.OrganizationID,
-			ThreatType:     domain.ThreatType(threatType),
-			Severity:       alert.Severity,
-			Title:          alert.Title,
-			Description:    alert.Description,
-			Source:         alert.ResourceID.String(),
-			TargetType:     alert.ResourceType,
-			TargetID:       alert.ResourceID,
-			TargetName:     &targetName, // Pointer to short ID for display
-			IsBlocked:      false,        // Alerts don't have blocked status
-			CreatedAt:      alert.CreatedAt,
-			ResolvedAt:     alert.AcknowledgedAt, // Map acknowledged_at to resolved_at
+		threats = append(threats, alertToThreat(alert))
+	}
+
+	return threats, nil
+}
+
+// SearchThreats finds threats with filtering, sorting and pagination and returns the total
+// matching the filters. Threat types are matched through the alert types that map to them.
+func (s *SecurityService) SearchThreats(ctx context.Context, orgID uuid.UUID, params domain.ThreatQueryParams) ([]*domain.Threat, int, error) {
+	if len(params.ThreatTypes) > 0 {
+		params.AlertTypes = alertTypesForThreatTypes(params.ThreatTypes)
+		if len(params.AlertTypes) == 0 {
+			return []*domain.Threat{}, 0, nil
 		}
+	}
 
-		threats = append(threats, threat)
+	alerts, total, err := s.securityRepo.SearchThreatAlerts(orgID, params)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return threats, nil
+	threats := make([]*domain.Threat, 0, len(alerts))
+	for _, alert := range alerts {
+		threats = append(threats, alertToThreat(alert))
+	}
+	return threats, total, nil
+}
+
+// GetThreat retrieves a threat of the organization
+func (s *SecurityService) GetThreat(ctx context.Context, orgID, threatID uuid.UUID) (*domain.Threat, error) {
+	alert, err := s.getThreatAlert(orgID, threatID)
+	if err != nil {
+		return nil, err
+	}
+	return alertToThreat(alert), nil
+}
+
+// AcknowledgeThreat acknowledges the alert behind a threat, which marks the threat as blocked.
+// Acknowledging an already acknowledged threat is a no-op.
+func (s *SecurityService) AcknowledgeThreat(ctx context.Context, orgID, threatID, userID uuid.UUID) (*domain.Threat, error) {
+	alert, err := s.getThreatAlert(orgID, threatID)
+	if err != nil {
+		return nil, err
+	}
+	if alert.IsAcknowledged {
+		return alertToThreat(alert), nil
+	}
+
+	if err := s.alertRepo.Acknowledge(alert.ID, userID); err != nil {
+		return nil, fmt.Errorf("failed to acknowledge threat: %w", err)
+	}
+	return s.GetThreat(ctx, orgID, threatID)
+}
+
+// AnnotateThreat adds a triage note to a threat
+func (s *SecurityService) AnnotateThreat(ctx context.Context, orgID, threatID, userID uuid.UUID, note string) (*domain.ThreatAnnotation, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, fmt.Errorf("%w: note is required", ErrInvalidThreatAnnotation)
+	}
+	if len(note) > MaxThreatAnnotationLength {
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrInvalidThreatAnnotation, MaxThreatAnnotationLength)
+	}
+
+	if _, err := s.getThreatAlert(orgID, threatID); err != nil {
+		return nil, err
+	}
+
+	annotation := &domain.ThreatAnnotation{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		ThreatID:       threatID,
+		UserID:         userID,
+		Note:           note,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := s.securityRepo.CreateThreatAnnotation(annotation); err != nil {
+		return nil, err
+	}
+	return annotation, nil
+}
+
+// GetThreatAnnotations lists a threat's annotations, oldest first
+func (s *SecurityService) GetThreatAnnotations(ctx context.Context, orgID, threatID uuid.UUID) ([]*domain.ThreatAnnotation, error) {
+	if _, err := s.getThreatAlert(orgID, threatID); err != nil {
+		return nil, err
+	}
+	return s.securityRepo.GetThreatAnnotations(threatID)
+}
+
+// getThreatAlert loads the alert behind a threat and checks it belongs to the organization
+func (s *SecurityService) getThreatAlert(orgID, threatID uuid.UUID) (*domain.Alert, error) {
+	alert, err := s.alertRepo.GetByID(threatID)
+	if err != nil || alert.OrganizationID != orgID {
+		return nil, ErrThreatNotFound
+	}
+	return alert, nil
+}
+
+// alertToThreat converts an alert into the threat shown in the Security Dashboard.
+// Acknowledged alerts count as blocked threats, as in the security metrics.
+func alertToThreat(alert *domain.Alert) *domain.Threat {
+	// Create target name (short ID for display)
+	targetName := alert.ResourceID.String()[:8] + "..."
+
+	return &domain.Threat{
+		ID:             alert.ID,
+		OrganizationID: alert.OrganizationID,
+		ThreatType:     domain.ThreatType(mapAlertTypeToThreatType(alert.AlertType)),
+		Severity:       alert.Severity,
+		Title:          alert.Title,
+		Description:    alert.Description,
+		Source:         alert.ResourceID.String(),
+		TargetType:     alert.ResourceType,
+		TargetID:       alert.ResourceID,
+		TargetName:     &targetName, // Pointer to short ID for display
+		IsBlocked:      alert.IsAcknowledged,
+		CreatedAt:      alert.CreatedAt,
+		ResolvedAt:     alert.AcknowledgedAt, // Map acknowledged_at to resolved_at
+	}
+}
+
+// alertTypesForThreatTypes returns the alert types whose threats have one of the given types
+func alertTypesForThreatTypes(threatTypes []domain.ThreatType) []domain.AlertType {
+	var alertTypes []domain.AlertType
+	for _, alertType := range threatAlertTypes {
+		for _, threatType := range threatTypes {
+			if mapAlertTypeToThreatType(alertType) == string(threatType) {
+				alertTypes = append(alertTypes, alertType)
+				break
+			}
+		}
+	}
+	return alertTypes
 }
 
 // mapAlertTypeToThreatType converts alert types to threat types for display
@@ -75,12 +213,18 @@ func mapAlertTypeToThreatType(alertType domain.AlertType) string {
 		return "malicious_agent"
 	case domain.AlertCertificateExpiring:
 		return "certificate_expiry"
-	case domain.AlertAPIKeyExpiring:
+	case domain.AlertAPIKeyExpiring, domain.AlertSharedCredential:
 		return "credential_leak"
 	case domain.AlertTrustScoreLow:
 		return "suspicious_activity"
 	case domain.AlertTypeConfigurationDrift:
 		return "configuration_drift"
+	case domain.AlertTypeCapabilityDrift:
+		return "capability_drift"
+	case domain.AlertTypePeerDrift, domain.AlertTypeMCPToolDrift:
+		return "unauthorized_access"
+	case domain.AlertSBOMVulnerability:
+		return "vulnerable_dependency"
 	default:
 		return "suspicious_activity"
 	}
@@ -91,6 +235,11 @@ func (s *SecurityService) GetAnomalies(ctx context.Context, orgID uuid.UUID, lim
 	return s.securityRepo.GetAnomalies(orgID, limit, offset)
 }
 
+// SearchAnomalies finds anomalies with filtering, sorting and pagination and returns the total matching the filters
+func (s *SecurityService) SearchAnomalies(ctx context.Context, orgID uuid.UUID, params domain.AnomalyQueryParams) ([]*domain.Anomaly, int, error) {
+	return s.securityRepo.SearchAnomalies(orgID, params)
+}
+
 // GetSecurityMetrics retrieves overall security metrics
 func (s *SecurityService) GetSecurityMetrics(ctx context.Context, orgID uuid.UUID) (*domain.SecurityMetrics, error) {
 	return s.securityRepo.GetSecurityMetrics(orgID)
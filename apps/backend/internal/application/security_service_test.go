@@ -11,7 +11,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
 	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
+	"github.com/opena2a/identity/backend/internal/testsupport"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // setupSecurityTestDB creates a mock database for security service testing
@@ -585,3 +587,158 @@ func TestBlockThreat(t *testing.T) {
 		assert.NoError(t, dbMock.ExpectationsWereMet())
 	})
 }
+
+func TestThreatSearchAcknowledgeAndAnnotate(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	other := testsupport.NewOrganization()
+	analyst := testsupport.NewUser(org.ID)
+	agent := testsupport.NewAgent(org.ID)
+	server := testsupport.NewMCPServer(org.ID)
+	ctx := context.Background()
+	service := NewSecurityService(repos.Security, repos.Agent, repos.Alert)
+
+	now := time.Now().UTC()
+	alert := func(resourceID uuid.UUID, opts ...func(*domain.Alert)) *domain.Alert {
+		a := testsupport.NewAlert(org.ID, resourceID, opts...)
+		require.NoError(t, repos.Alert.Create(a))
+		return a
+	}
+	breach := alert(agent.ID, func(a *domain.Alert) {
+		a.AlertType = domain.AlertSecurityBreach
+		a.Severity = domain.AlertSeverityCritical
+		a.Title = "Credential exfiltration attempt"
+		a.CreatedAt = now.Add(-3 * time.Hour)
+	})
+	drift := alert(server.ID, func(a *domain.Alert) {
+		a.AlertType = domain.AlertTypeCapabilityDrift
+		a.Severity = domain.AlertSeverityHigh
+		a.ResourceType = "mcp_server"
+		a.CreatedAt = now.Add(-2 * time.Hour)
+	})
+	unusual := alert(agent.ID, func(a *domain.Alert) {
+		a.Description = "Burst of EXFILTRATION-like requests"
+		a.CreatedAt = now.Add(-time.Hour)
+	})
+	require.NoError(t, repos.Alert.Create(testsupport.NewAlert(other.ID, uuid.New())))
+
+	ids := func(threats []*domain.Threat) []uuid.UUID {
+		var result []uuid.UUID
+		for _, threat := range threats {
+			result = append(result, threat.ID)
+		}
+		return result
+	}
+
+	threats, total, err := service.SearchThreats(ctx, org.ID, domain.ThreatQueryParams{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 3, total, "other organizations' threats are excluded from the total")
+	assert.Equal(t, []uuid.UUID{unusual.ID, drift.ID}, ids(threats), "newest first by default")
+
+	threats, _, err = service.SearchThreats(ctx, org.ID, domain.ThreatQueryParams{SortBy: "severity"})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{breach.ID, drift.ID, unusual.ID}, ids(threats))
+
+	threats, total, err = service.SearchThreats(ctx, org.ID, domain.ThreatQueryParams{Search: "exfiltration"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total, "search matches titles and descriptions case-insensitively")
+	assert.ElementsMatch(t, []uuid.UUID{breach.ID, unusual.ID}, ids(threats))
+
+	threats, _, err = service.SearchThreats(ctx, org.ID, domain.ThreatQueryParams{
+		ThreatTypes: []domain.ThreatType{domain.ThreatTypeMaliciousAgent},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{breach.ID}, ids(threats), "threat types match through their alert types")
+
+	_, total, err = service.SearchThreats(ctx, org.ID, domain.ThreatQueryParams{
+		ThreatTypes: []domain.ThreatType{domain.ThreatTypeBruteForce},
+	})
+	require.NoError(t, err)
+	assert.Zero(t, total, "a threat type no alert maps to matches nothing")
+
+	from := now.Add(-150 * time.Minute)
+	threats, _, err = service.SearchThreats(ctx, org.ID, domain.ThreatQueryParams{
+		TargetType: "agent",
+		TargetID:   &agent.ID,
+		From:       &from,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{unusual.ID}, ids(threats))
+
+	// Acknowledging blocks the threat and is scoped to the organization
+	_, err = service.AcknowledgeThreat(ctx, other.ID, breach.ID, analyst.ID)
+	assert.ErrorIs(t, err, ErrThreatNotFound)
+
+	threat, err := service.AcknowledgeThreat(ctx, org.ID, breach.ID, analyst.ID)
+	require.NoError(t, err)
+	assert.True(t, threat.IsBlocked)
+	assert.NotNil(t, threat.ResolvedAt)
+
+	blocked := true
+	threats, _, err = service.SearchThreats(ctx, org.ID, domain.ThreatQueryParams{Blocked: &blocked})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{breach.ID}, ids(threats))
+
+	// Annotations
+	_, err = service.AnnotateThreat(ctx, org.ID, breach.ID, analyst.ID, "   ")
+	assert.ErrorIs(t, err, ErrInvalidThreatAnnotation)
+	_, err = service.AnnotateThreat(ctx, other.ID, breach.ID, analyst.ID, "not ours")
+	assert.ErrorIs(t, err, ErrThreatNotFound)
+
+	_, err = service.AnnotateThreat(ctx, org.ID, breach.ID, analyst.ID, "Rotated the agent's keys")
+	require.NoError(t, err)
+	_, err = service.AnnotateThreat(ctx, org.ID, breach.ID, analyst.ID, "  False positive ruled out  ")
+	require.NoError(t, err)
+
+	annotations, err := service.GetThreatAnnotations(ctx, org.ID, breach.ID)
+	require.NoError(t, err)
+	require.Len(t, annotations, 2)
+	assert.Equal(t, "Rotated the agent's keys", annotations[0].Note, "oldest first")
+	assert.Equal(t, "False positive ruled out", annotations[1].Note)
+	assert.Equal(t, analyst.ID, annotations[1].UserID)
+}
+
+func TestAnomalySearch(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	agent := testsupport.NewAgent(org.ID)
+	ctx := context.Background()
+	service := NewSecurityService(repos.Security, repos.Agent, repos.Alert)
+
+	anomaly := func(anomalyType domain.AnomalyType, severity domain.AlertSeverity, confidence float64) *domain.Anomaly {
+		a := &domain.Anomaly{
+			OrganizationID: org.ID,
+			AnomalyType:    anomalyType,
+			Severity:       severity,
+			Title:          string(anomalyType),
+			ResourceType:   "agent",
+			ResourceID:     agent.ID,
+			Confidence:     confidence,
+		}
+		require.NoError(t, service.CreateAnomaly(ctx, a))
+		return a
+	}
+	traffic := anomaly(domain.AnomalyTypeAbnormalTraffic, "medium", 60)
+	location := anomaly(domain.AnomalyTypeUnexpectedLocation, domain.AlertSeverityCritical, 95)
+	rate := anomaly(domain.AnomalyTypeRateLimitViolation, "low", 80)
+
+	anomalies, total, err := service.SearchAnomalies(ctx, org.ID, domain.AnomalyQueryParams{
+		MinConfidence: 70,
+		SortBy:        "confidence",
+		SortOrder:     "asc",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, anomalies, 2)
+	assert.Equal(t, rate.ID, anomalies[0].ID)
+	assert.Equal(t, location.ID, anomalies[1].ID)
+
+	anomalies, total, err = service.SearchAnomalies(ctx, org.ID, domain.AnomalyQueryParams{
+		Severities:   []domain.AlertSeverity{"medium", domain.AlertSeverityCritical},
+		AnomalyTypes: []domain.AnomalyType{domain.AnomalyTypeAbnormalTraffic},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, traffic.ID, anomalies[0].ID)
+}
@@ -0,0 +1,275 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrPublicKeyInUse is returned when a registration or key rotation reuses the current public
+	// key of another agent and the organization has not allowlisted it
+	ErrPublicKeyInUse = errors.New("public key is already registered to another agent")
+	// ErrInvalidSharedKeyAllowlistEntry is returned for allowlist entries without a key or reason
+	ErrInvalidSharedKeyAllowlistEntry = errors.New("invalid shared key allowlist entry")
+)
+
+// fingerprintPattern matches a domain.PublicKeyFingerprint
+var fingerprintPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// SharedKeyReport is a shared public key as one organization sees it: its own agents holding the
+// key, and only a count of the other organizations' agents
+type SharedKeyReport struct {
+	Fingerprint             string                   `json:"fingerprint"`
+	Agents                  []domain.SharedKeyHolder `json:"agents"`
+	OtherOrganizationAgents int                      `json:"otherOrganizationAgents"`
+	Allowlisted             bool                     `json:"allowlisted"`
+}
+
+// AddSharedKeyAllowlistEntryRequest allowlists a public key, given as the key or its fingerprint
+type AddSharedKeyAllowlistEntryRequest struct {
+	PublicKey   string `json:"publicKey,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Reason      string `json:"reason"`
+}
+
+// SharedKeyService detects public keys registered to several agents. One key held by two agents
+// makes them indistinguishable, so reuse is blocked at registration and key rotation, and keys
+// that are shared anyway raise shared_credential threats. Organizations allowlist the keys they
+// share on purpose.
+//
+// API keys need no such check: their hashes are unique in the database, so one API key can't be
+// registered to two agents.
+type SharedKeyService struct {
+	sharedKeyRepo domain.SharedKeyRepository
+	alertRepo     domain.AlertRepository
+	now           func() time.Time
+}
+
+// NewSharedKeyService creates a new shared key service
+func NewSharedKeyService(sharedKeyRepo domain.SharedKeyRepository, alertRepo domain.AlertRepository) *SharedKeyService {
+	return &SharedKeyService{
+		sharedKeyRepo: sharedKeyRepo,
+		alertRepo:     alertRepo,
+		now:           time.Now,
+	}
+}
+
+// CheckPublicKey returns ErrPublicKeyInUse when publicKey is the current key of an agent other
+// than agentID (uuid.Nil for a new agent), unless the organization allowlisted it
+func (s *SharedKeyService) CheckPublicKey(ctx context.Context, orgID, agentID uuid.UUID, publicKey string) error {
+	holders, err := s.sharedKeyRepo.FindPublicKeyHolders(publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to look up public key: %w", err)
+	}
+	inUse := false
+	for _, holder := range holders {
+		if holder.AgentID != agentID {
+			inUse = true
+			break
+		}
+	}
+	if !inUse {
+		return nil
+	}
+
+	allowlisted, err := s.allowlisted(orgID)
+	if err != nil {
+		return err
+	}
+	if allowlisted[domain.PublicKeyFingerprint(publicKey)] {
+		return nil
+	}
+	return ErrPublicKeyInUse
+}
+
+// ListSharedKeys returns the shared public keys held by the organization's agents
+func (s *SharedKeyService) ListSharedKeys(ctx context.Context, orgID uuid.UUID) ([]*SharedKeyReport, error) {
+	sharedKeys, err := s.sharedKeyRepo.FindSharedKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find shared keys: %w", err)
+	}
+	allowlisted, err := s.allowlisted(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*SharedKeyReport, 0)
+	for _, sharedKey := range sharedKeys {
+		if report := reportSharedKey(sharedKey, orgID, allowlisted); report != nil {
+			reports = append(reports, report)
+		}
+	}
+	return reports, nil
+}
+
+// reportSharedKey returns the organization's view of the shared key, or nil if none of its
+// agents hold it
+func reportSharedKey(sharedKey *domain.SharedKey, orgID uuid.UUID, allowlisted map[string]bool) *SharedKeyReport {
+	report := &SharedKeyReport{
+		Fingerprint: sharedKey.Fingerprint,
+		Agents:      make([]domain.SharedKeyHolder, 0),
+		Allowlisted: allowlisted[sharedKey.Fingerprint],
+	}
+	for _, holder := range sharedKey.Holders {
+		if holder.OrganizationID == orgID {
+			report.Agents = append(report.Agents, holder)
+		} else {
+			report.OtherOrganizationAgents++
+		}
+	}
+	if len(report.Agents) == 0 {
+		return nil
+	}
+	return report
+}
+
+// DetectSharedKeys raises a shared_credential alert for each agent holding a shared public key
+// its organization has not allowlisted. Keys shared across organizations are critical. An agent
+// with an unacknowledged shared_credential alert gets no new one. It returns the number of alerts raised.
+func (s *SharedKeyService) DetectSharedKeys(ctx context.Context) (int, error) {
+	sharedKeys, err := s.sharedKeyRepo.FindSharedKeys()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find shared keys: %w", err)
+	}
+
+	raised := 0
+	allowlists := make(map[uuid.UUID]map[string]bool)
+	for _, sharedKey := range sharedKeys {
+		for _, holder := range sharedKey.Holders {
+			allowlisted, ok := allowlists[holder.OrganizationID]
+			if !ok {
+				if allowlisted, err = s.allowlisted(holder.OrganizationID); err != nil {
+					return raised, err
+				}
+				allowlists[holder.OrganizationID] = allowlisted
+			}
+			if allowlisted[sharedKey.Fingerprint] {
+				continue
+			}
+
+			created, err := s.alertHolder(holder, reportSharedKey(sharedKey, holder.OrganizationID, allowlisted))
+			if err != nil {
+				log.Printf("⚠️  Failed to raise shared key alert for agent %s: %v", holder.AgentID, err)
+				continue
+			}
+			if created {
+				raised++
+			}
+		}
+	}
+	return raised, nil
+}
+
+func (s *SharedKeyService) alertHolder(holder domain.SharedKeyHolder, report *SharedKeyReport) (bool, error) {
+	existing, err := s.alertRepo.GetUnacknowledgedByResourceID(holder.AgentID)
+	if err != nil {
+		return false, err
+	}
+	for _, alert := range existing {
+		if alert.AlertType == domain.AlertSharedCredential {
+			return false, nil
+		}
+	}
+
+	severity := domain.AlertSeverityHigh
+	others := fmt.Sprintf("%d other agent(s) of this organization", len(report.Agents)-1)
+	if report.OtherOrganizationAgents > 0 {
+		severity = domain.AlertSeverityCritical
+		others = fmt.Sprintf("%d other agent(s) of this organization and %d agent(s) of other organizations",
+			len(report.Agents)-1, report.OtherOrganizationAgents)
+	}
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: holder.OrganizationID,
+		AlertType:      domain.AlertSharedCredential,
+		Severity:       severity,
+		Title:          fmt.Sprintf("Shared public key: %s", holder.AgentName),
+		Description: fmt.Sprintf("Agent '%s' uses public key %s, which is also registered to %s. "+
+			"Agents sharing a key can't be told apart; rotate the key, or allowlist it if the sharing is intended.",
+			holder.AgentName, report.Fingerprint[:16], others),
+		ResourceType: "agent",
+		ResourceID:   holder.AgentID,
+		CreatedAt:    s.now().UTC(),
+	}
+	if err := s.alertRepo.Create(alert); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AddAllowlistEntry allowlists a public key the organization registers to several agents on purpose
+func (s *SharedKeyService) AddAllowlistEntry(ctx context.Context, orgID, userID uuid.UUID, req *AddSharedKeyAllowlistEntryRequest) (*domain.SharedKeyAllowlistEntry, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidSharedKeyAllowlistEntry)
+	}
+
+	fingerprint := strings.ToLower(strings.TrimSpace(req.Fingerprint))
+	switch {
+	case req.PublicKey != "" && fingerprint != "":
+		return nil, fmt.Errorf("%w: give either publicKey or fingerprint", ErrInvalidSharedKeyAllowlistEntry)
+	case req.PublicKey != "":
+		if _, err := crypto.DetectKeyAlgorithm(req.PublicKey); err != nil {
+			return nil, fmt.Errorf("%w: publicKey must be a base64-encoded %s public key", ErrInvalidSharedKeyAllowlistEntry, strings.Join(domain.KeyAlgorithms, ", "))
+		}
+		fingerprint = domain.PublicKeyFingerprint(req.PublicKey)
+	case !fingerprintPattern.MatchString(fingerprint):
+		return nil, fmt.Errorf("%w: fingerprint must be the hex SHA-256 of the base64 public key", ErrInvalidSharedKeyAllowlistEntry)
+	}
+
+	allowlist, err := s.sharedKeyRepo.ListAllowlist(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shared key allowlist: %w", err)
+	}
+	for _, entry := range allowlist {
+		if entry.Fingerprint == fingerprint {
+			return entry, nil
+		}
+	}
+
+	entry := &domain.SharedKeyAllowlistEntry{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Fingerprint:    fingerprint,
+		Reason:         reason,
+		CreatedBy:      &userID,
+		CreatedAt:      s.now().UTC(),
+	}
+	if err := s.sharedKeyRepo.CreateAllowlistEntry(entry); err != nil {
+		return nil, fmt.Errorf("failed to create shared key allowlist entry: %w", err)
+	}
+	return entry, nil
+}
+
+// ListAllowlist returns the organization's allowlisted public keys
+func (s *SharedKeyService) ListAllowlist(ctx context.Context, orgID uuid.UUID) ([]*domain.SharedKeyAllowlistEntry, error) {
+	return s.sharedKeyRepo.ListAllowlist(orgID)
+}
+
+// DeleteAllowlistEntry removes a key from the allowlist; agents already sharing it are flagged
+// again by the next detection run
+func (s *SharedKeyService) DeleteAllowlistEntry(ctx context.Context, orgID, id uuid.UUID) error {
+	return s.sharedKeyRepo.DeleteAllowlistEntry(orgID, id)
+}
+
+// allowlisted returns the fingerprints the organization allowlisted
+func (s *SharedKeyService) allowlisted(orgID uuid.UUID) (map[string]bool, error) {
+	allowlist, err := s.sharedKeyRepo.ListAllowlist(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shared key allowlist: %w", err)
+	}
+	fingerprints := make(map[string]bool, len(allowlist))
+	for _, entry := range allowlist {
+		fingerprints[entry.Fingerprint] = true
+	}
+	return fingerprints, nil
+}
@@ -0,0 +1,91 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedPublicKeysAreDetectedBlockedAndAllowlisted(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	other := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	require.NoError(t, repos.Organization.Create(other))
+	admin := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(admin))
+	ctx := context.Background()
+
+	keyPair, err := crypto.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	publicKey := crypto.EncodeKeyPair(keyPair).PublicKeyBase64
+	withKey := func(a *domain.Agent) { a.PublicKey = &publicKey }
+	original := testsupport.NewAgent(org.ID, withKey)
+	copied := testsupport.NewAgent(other.ID, withKey)
+	unrelated := testsupport.NewAgent(org.ID)
+	for _, agent := range []*domain.Agent{original, copied, unrelated} {
+		require.NoError(t, repos.Agent.Create(agent))
+	}
+
+	service := NewSharedKeyService(repos.SharedKey, repos.Alert)
+
+	// Each organization sees its own holders and only a count of the other's
+	reports, err := service.ListSharedKeys(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, domain.PublicKeyFingerprint(publicKey), reports[0].Fingerprint)
+	require.Len(t, reports[0].Agents, 1)
+	assert.Equal(t, original.ID, reports[0].Agents[0].AgentID)
+	assert.Equal(t, 1, reports[0].OtherOrganizationAgents)
+
+	// A key shared across organizations is critical for both holders, and alerts aren't repeated
+	raised, err := service.DetectSharedKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, raised)
+	for _, agent := range []*domain.Agent{original, copied} {
+		alerts, err := repos.Alert.GetUnacknowledgedByResourceID(agent.ID)
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		assert.Equal(t, domain.AlertSharedCredential, alerts[0].AlertType)
+		assert.Equal(t, domain.AlertSeverityCritical, alerts[0].Severity)
+	}
+	raised, err = service.DetectSharedKeys(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, raised)
+
+	// Registering or rotating to a key another agent holds is refused
+	agentService := NewAgentService(repos.Agent, nil, repos.TrustScore, nil, repos.Alert, nil, repos.Capability, nil, nil, repos.Organization, nil, nil, service, nil)
+	register := func() *RegistrationValidation {
+		_, validation, err := agentService.ValidateAgentRegistration(ctx, &CreateAgentRequest{
+			Name: "key-reuser", DisplayName: "Key Reuser", AgentType: domain.AgentTypeAI, PublicKey: publicKey,
+		}, org.ID, admin.ID)
+		require.NoError(t, err)
+		return validation
+	}
+	validation := register()
+	assert.False(t, validation.Valid)
+	require.Len(t, validation.Errors, 1)
+	assert.Equal(t, RegistrationIssueKeyInUse, validation.Errors[0].Code)
+	_, err = agentService.RotateKey(ctx, unrelated.ID, &RotateKeyRequest{PublicKey: publicKey})
+	assert.ErrorIs(t, err, ErrInvalidKeyRotation)
+
+	// Allowlisting the key permits sharing it within the organization
+	_, err = service.AddAllowlistEntry(ctx, org.ID, admin.ID, &AddSharedKeyAllowlistEntryRequest{PublicKey: publicKey})
+	assert.ErrorIs(t, err, ErrInvalidSharedKeyAllowlistEntry, "a reason is required")
+	entry, err := service.AddAllowlistEntry(ctx, org.ID, admin.ID, &AddSharedKeyAllowlistEntryRequest{PublicKey: publicKey, Reason: "replicas of one agent"})
+	require.NoError(t, err)
+	assert.True(t, register().Valid)
+	reports, err = service.ListSharedKeys(ctx, org.ID)
+	require.NoError(t, err)
+	assert.True(t, reports[0].Allowlisted)
+
+	// Removing the entry blocks reuse again, and only the owning organization can remove it
+	assert.ErrorIs(t, service.DeleteAllowlistEntry(ctx, other.ID, entry.ID), domain.ErrSharedKeyAllowlistEntryNotFound)
+	require.NoError(t, service.DeleteAllowlistEntry(ctx, org.ID, entry.ID))
+	assert.False(t, register().Valid)
+}
@@ -0,0 +1,703 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// siemBufferSize bounds the events buffered per exporter; when the SIEM can't keep up the
+	// oldest are dropped and counted
+	siemBufferSize = 10000
+	// siemBatchSize caps the events sent per exporter per flush
+	siemBatchSize = 500
+	// siemFlushInterval is how often buffered events are sent
+	siemFlushInterval = time.Second
+	// siemBaseBackoff and siemMaxBackoff bound the wait before retrying an unreachable SIEM
+	siemBaseBackoff = time.Second
+	siemMaxBackoff  = time.Minute
+	// siemExporterCacheTTL is how long an organization's exporters are cached; changes made
+	// through another instance are picked up within it
+	siemExporterCacheTTL = time.Minute
+	// siemTestTimeout bounds a test delivery
+	siemTestTimeout = 15 * time.Second
+)
+
+var (
+	// ErrInvalidSIEMExporter is returned when a SIEM exporter's settings are invalid
+	ErrInvalidSIEMExporter = errors.New("invalid SIEM exporter")
+	// ErrSIEMDeliveryFailed is returned when a test event could not be delivered to the SIEM
+	ErrSIEMDeliveryFailed = errors.New("SIEM delivery failed")
+)
+
+// SIEMSinkFactory returns the sink delivering to an exporter's SIEM
+type SIEMSinkFactory func(exporter *domain.SIEMExporter) (domain.SIEMSink, error)
+
+// SIEMExportService manages organizations' SIEM exporters and streams their threats, anomalies,
+// alerts and verification failures to them. Events are buffered per exporter in memory and sent
+// in batches, so recording an event never waits on a SIEM.
+type SIEMExportService struct {
+	repo  domain.SIEMExporterRepository
+	sinks SIEMSinkFactory
+	now   func() time.Time
+
+	mu        sync.Mutex
+	exporters map[uuid.UUID]*siemExporterCache // By organization
+	queues    map[uuid.UUID]*siemQueue         // By exporter
+
+	flushMu sync.Mutex // One flush at a time; sinks are only used while it is held
+}
+
+type siemExporterCache struct {
+	exporters []*domain.SIEMExporter
+	loadedAt  time.Time
+}
+
+// siemQueue buffers an exporter's events until they are sent
+type siemQueue struct {
+	exporter *domain.SIEMExporter
+	events   []*domain.SIEMEvent
+	dropped  int64 // Dropped since last recorded
+
+	sink         domain.SIEMSink
+	sinkExporter *domain.SIEMExporter // Settings the sink was created with
+	failures     int
+	retryAt      time.Time
+}
+
+// NewSIEMExportService creates a new SIEM export service
+func NewSIEMExportService(repo domain.SIEMExporterRepository, sinks SIEMSinkFactory) *SIEMExportService {
+	return &SIEMExportService{
+		repo:      repo,
+		sinks:     sinks,
+		now:       func() time.Time { return time.Now().UTC() },
+		exporters: make(map[uuid.UUID]*siemExporterCache),
+		queues:    make(map[uuid.UUID]*siemQueue),
+	}
+}
+
+// SIEMExporterRequest represents the request to create or update a SIEM exporter
+type SIEMExporterRequest struct {
+	Name          string               `json:"name"`
+	Host          string               `json:"host"`
+	Port          int                  `json:"port"`
+	Transport     string               `json:"transport"`          // udp, tcp or tls
+	Format        string               `json:"format"`             // cef or leef
+	Facility      *int                 `json:"facility,omitempty"` // Defaults to 10 (authpriv)
+	CACertificate string               `json:"caCertificate,omitempty"`
+	EventTypes    []string             `json:"eventTypes"` // Empty streams every category
+	MinSeverity   domain.AlertSeverity `json:"minSeverity"`
+	IsActive      *bool                `json:"isActive,omitempty"` // Pointer to distinguish between false and not provided
+}
+
+// CreateExporter creates an exporter
+func (s *SIEMExportService) CreateExporter(ctx context.Context, req *SIEMExporterRequest, orgID, userID uuid.UUID) (*domain.SIEMExporter, error) {
+	exporter := &domain.SIEMExporter{
+		OrganizationID: orgID,
+		IsActive:       true,
+		CreatedBy:      userID,
+	}
+	if err := s.applyExporterRequest(exporter, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(exporter); err != nil {
+		return nil, fmt.Errorf("failed to create SIEM exporter: %w", err)
+	}
+
+	s.invalidate(orgID)
+	return exporter, nil
+}
+
+// ListExporters lists the organization's exporters, oldest first
+func (s *SIEMExportService) ListExporters(ctx context.Context, orgID uuid.UUID) ([]*domain.SIEMExporter, error) {
+	return s.repo.GetByOrganization(orgID)
+}
+
+// GetExporter returns one of the organization's exporters
+func (s *SIEMExportService) GetExporter(ctx context.Context, orgID, id uuid.UUID) (*domain.SIEMExporter, error) {
+	exporter, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if exporter.OrganizationID != orgID {
+		return nil, domain.ErrSIEMExporterNotFound
+	}
+	return exporter, nil
+}
+
+// UpdateExporter updates one of the organization's exporters. Buffered events are sent with the
+// new settings.
+func (s *SIEMExportService) UpdateExporter(ctx context.Context, orgID, id uuid.UUID, req *SIEMExporterRequest) (*domain.SIEMExporter, error) {
+	exporter, err := s.GetExporter(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyExporterRequest(exporter, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(exporter); err != nil {
+		return nil, fmt.Errorf("failed to update SIEM exporter: %w", err)
+	}
+
+	s.invalidate(orgID)
+	return exporter, nil
+}
+
+// DeleteExporter deletes one of the organization's exporters and discards its buffered events
+func (s *SIEMExportService) DeleteExporter(ctx context.Context, orgID, id uuid.UUID) error {
+	if _, err := s.GetExporter(ctx, orgID, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	s.invalidate(orgID)
+	return nil
+}
+
+// TestExporter sends a test event to one of the organization's exporters and waits for it to be
+// written, so a misconfigured exporter is reported right away
+func (s *SIEMExportService) TestExporter(ctx context.Context, orgID, id uuid.UUID) error {
+	exporter, err := s.GetExporter(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	sink, err := s.sinks(exporter)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSIEMDeliveryFailed, err)
+	}
+	defer sink.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, siemTestTimeout)
+	defer cancel()
+	event := &domain.SIEMEvent{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Category:       domain.SIEMEventAlert,
+		Type:           "siem_test",
+		Name:           "AIM SIEM exporter test",
+		Message:        fmt.Sprintf("Test event from SIEM exporter %s", exporter.Name),
+		Severity:       domain.AlertSeverityInfo,
+		ResourceType:   "siem_exporter",
+		ResourceID:     &exporter.ID,
+		OccurredAt:     s.now(),
+	}
+	if err := sink.Send(ctx, []*domain.SIEMEvent{event}); err != nil {
+		return fmt.Errorf("%w: %v", ErrSIEMDeliveryFailed, err)
+	}
+	return nil
+}
+
+// applyExporterRequest validates a request and copies it onto the exporter
+func (s *SIEMExportService) applyExporterRequest(exporter *domain.SIEMExporter, req *SIEMExporterRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidSIEMExporter)
+	}
+	host := strings.TrimSpace(req.Host)
+	if host == "" || strings.ContainsAny(host, " /") {
+		return fmt.Errorf("%w: host must be a hostname or IP address", ErrInvalidSIEMExporter)
+	}
+	if req.Port < 1 || req.Port > 65535 {
+		return fmt.Errorf("%w: port must be between 1 and 65535", ErrInvalidSIEMExporter)
+	}
+	if req.Transport != domain.SIEMTransportUDP && req.Transport != domain.SIEMTransportTCP && req.Transport != domain.SIEMTransportTLS {
+		return fmt.Errorf("%w: transport must be udp, tcp or tls", ErrInvalidSIEMExporter)
+	}
+	if req.Format != domain.SIEMFormatCEF && req.Format != domain.SIEMFormatLEEF {
+		return fmt.Errorf("%w: format must be cef or leef", ErrInvalidSIEMExporter)
+	}
+	facility := 10
+	if req.Facility != nil {
+		facility = *req.Facility
+	}
+	if facility < 0 || facility > 23 {
+		return fmt.Errorf("%w: facility must be between 0 and 23", ErrInvalidSIEMExporter)
+	}
+	if req.CACertificate != "" && req.Transport != domain.SIEMTransportTLS {
+		return fmt.Errorf("%w: caCertificate applies to the tls transport only", ErrInvalidSIEMExporter)
+	}
+	for _, eventType := range req.EventTypes {
+		if !slices.Contains(domain.SIEMEventCategories, eventType) {
+			return fmt.Errorf("%w: event types must be %s", ErrInvalidSIEMExporter, strings.Join(domain.SIEMEventCategories, ", "))
+		}
+	}
+	minSeverity := req.MinSeverity
+	if minSeverity == "" {
+		minSeverity = domain.AlertSeverityInfo
+	}
+	if severityRank(minSeverity) < 0 {
+		return fmt.Errorf("%w: minSeverity must be info, warning, high or critical", ErrInvalidSIEMExporter)
+	}
+
+	exporter.Name = strings.TrimSpace(req.Name)
+	exporter.Host = host
+	exporter.Port = req.Port
+	exporter.Transport = req.Transport
+	exporter.Format = req.Format
+	exporter.Facility = facility
+	exporter.CACertificate = strings.TrimSpace(req.CACertificate)
+	exporter.EventTypes = slices.Compact(slices.Sorted(slices.Values(req.EventTypes)))
+	exporter.MinSeverity = minSeverity
+	if req.IsActive != nil {
+		exporter.IsActive = *req.IsActive
+	}
+
+	// A sink that can't be created (e.g. an invalid CA certificate) would fail every delivery
+	sink, err := s.sinks(exporter)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSIEMExporter, err)
+	}
+	return sink.Close()
+}
+
+// Publish buffers the event for each active exporter of its organization streaming its category
+// and severity. It never blocks on a SIEM: when an exporter's buffer is full its oldest event is
+// dropped.
+func (s *SIEMExportService) Publish(event *domain.SIEMEvent) {
+	exporters, err := s.activeExporters(event.OrganizationID)
+	if err != nil {
+		log.Printf("⚠️  Failed to load SIEM exporters of organization %s: %v", event.OrganizationID, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, exporter := range exporters {
+		if !siemExporterStreams(exporter, event) {
+			continue
+		}
+		queue, ok := s.queues[exporter.ID]
+		if !ok {
+			queue = &siemQueue{}
+			s.queues[exporter.ID] = queue
+		}
+		queue.exporter = exporter
+		queue.events = append(queue.events, event)
+		queue.trim()
+	}
+}
+
+// siemExporterStreams reports whether the exporter streams the event's category and severity
+func siemExporterStreams(exporter *domain.SIEMExporter, event *domain.SIEMEvent) bool {
+	if len(exporter.EventTypes) > 0 && !slices.Contains(exporter.EventTypes, event.Category) {
+		return false
+	}
+	return severityRank(event.Severity) >= severityRank(exporter.MinSeverity)
+}
+
+// trim drops the oldest events beyond the buffer size
+func (q *siemQueue) trim() {
+	if overflow := len(q.events) - siemBufferSize; overflow > 0 {
+		q.events = slices.Delete(q.events, 0, overflow)
+		q.dropped += int64(overflow)
+	}
+}
+
+// activeExporters returns the organization's active exporters, cached for siemExporterCacheTTL
+func (s *SIEMExportService) activeExporters(orgID uuid.UUID) ([]*domain.SIEMExporter, error) {
+	s.mu.Lock()
+	cached, ok := s.exporters[orgID]
+	s.mu.Unlock()
+	if ok && s.now().Sub(cached.loadedAt) < siemExporterCacheTTL {
+		return cached.exporters, nil
+	}
+
+	exporters, err := s.repo.GetActiveByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.exporters[orgID] = &siemExporterCache{exporters: exporters, loadedAt: s.now()}
+	s.mu.Unlock()
+	return exporters, nil
+}
+
+// invalidate drops the organization's cached exporters after one of them changed. Buffered events
+// of exporters that were deleted or deactivated are discarded; the others pick up the new settings.
+func (s *SIEMExportService) invalidate(orgID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.exporters, orgID)
+	s.mu.Unlock()
+
+	exporters, err := s.activeExporters(orgID)
+	if err != nil {
+		log.Printf("⚠️  Failed to reload SIEM exporters of organization %s: %v", orgID, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, queue := range s.queues {
+		if queue.exporter == nil || queue.exporter.OrganizationID != orgID {
+			continue
+		}
+		index := slices.IndexFunc(exporters, func(e *domain.SIEMExporter) bool { return e.ID == id })
+		if index < 0 {
+			queue.exporter = nil // Closed and removed by the next flush
+			continue
+		}
+		queue.exporter = exporters[index]
+	}
+}
+
+// Start runs the export worker until the context is cancelled, then sends what is still buffered
+func (s *SIEMExportService) Start(ctx context.Context) {
+	ticker := time.NewTicker(siemFlushInterval)
+	defer ticker.Stop()
+
+	log.Println("✅ SIEM export worker started")
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), siemTestTimeout)
+			if _, err := s.Flush(flushCtx); err != nil {
+				log.Printf("⚠️  Final SIEM export flush failed: %v", err)
+			}
+			cancel()
+			log.Println("SIEM export worker stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.Flush(ctx); err != nil {
+				log.Printf("⚠️  SIEM export flush failed: %v", err)
+			}
+		}
+	}
+}
+
+// Flush sends a batch of each exporter's buffered events, skipping exporters backing off after a
+// failure, and records the deliveries. Returns the number of events delivered.
+func (s *SIEMExportService) Flush(ctx context.Context) (int, error) {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	now := s.now()
+	s.mu.Lock()
+	ids := make([]uuid.UUID, 0, len(s.queues))
+	for id := range s.queues {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+
+	delivered := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+
+		s.mu.Lock()
+		queue := s.queues[id]
+		if queue.exporter == nil {
+			delete(s.queues, id)
+			s.mu.Unlock()
+			if queue.sink != nil {
+				queue.sink.Close()
+			}
+			continue
+		}
+		if now.Before(queue.retryAt) || (len(queue.events) == 0 && queue.dropped == 0) {
+			s.mu.Unlock()
+			continue
+		}
+		exporter := queue.exporter
+		batch := queue.events[:min(len(queue.events), siemBatchSize)]
+		queue.events = slices.Clone(queue.events[len(batch):])
+		dropped := queue.dropped
+		queue.dropped = 0
+		s.mu.Unlock()
+
+		stats := domain.SIEMDeliveryStats{Dropped: dropped}
+		if len(batch) > 0 {
+			if err := s.send(ctx, queue, exporter, batch); err != nil {
+				log.Printf("⚠️  SIEM exporter %s failed to deliver %d events: %v", exporter.ID, len(batch), err)
+				message := err.Error()
+				stats.Error, stats.ErrorAt = &message, &now
+
+				s.mu.Lock()
+				queue.events = append(batch, queue.events...)
+				queue.trim()
+				queue.failures++
+				queue.retryAt = now.Add(siemRetryBackoff(queue.failures))
+				s.mu.Unlock()
+			} else {
+				stats.Delivered = int64(len(batch))
+				stats.DeliveredAt = &now
+				delivered += len(batch)
+
+				s.mu.Lock()
+				queue.failures = 0
+				queue.retryAt = time.Time{}
+				s.mu.Unlock()
+			}
+		}
+		if err := s.repo.RecordDelivery(exporter.ID, stats); err != nil {
+			log.Printf("⚠️  Failed to record deliveries of SIEM exporter %s: %v", exporter.ID, err)
+		}
+	}
+	return delivered, nil
+}
+
+// send delivers the batch through the queue's sink, recreating it after the exporter's settings
+// changed. A failed sink is closed so the next attempt reconnects.
+func (s *SIEMExportService) send(ctx context.Context, queue *siemQueue, exporter *domain.SIEMExporter, batch []*domain.SIEMEvent) error {
+	if queue.sink != nil && !queue.sinkExporter.UpdatedAt.Equal(exporter.UpdatedAt) {
+		queue.sink.Close()
+		queue.sink = nil
+	}
+	if queue.sink == nil {
+		sink, err := s.sinks(exporter)
+		if err != nil {
+			return err
+		}
+		queue.sink, queue.sinkExporter = sink, exporter
+	}
+	if err := queue.sink.Send(ctx, batch); err != nil {
+		queue.sink.Close()
+		queue.sink = nil
+		return err
+	}
+	return nil
+}
+
+// siemRetryBackoff returns the delay after the given number of consecutive failures
+func siemRetryBackoff(failures int) time.Duration {
+	backoff := siemBaseBackoff
+	for i := 1; i < failures; i++ {
+		backoff *= 2
+		if backoff >= siemMaxBackoff {
+			return siemMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// occurredAt is the event's recorded time, or now for repositories that don't set it
+func (s *SIEMExportService) occurredAt(createdAt time.Time) time.Time {
+	if createdAt.IsZero() {
+		return s.now()
+	}
+	return createdAt
+}
+
+// PublishThreat streams a detected threat
+func (s *SIEMExportService) PublishThreat(threat *domain.Threat) {
+	event := &domain.SIEMEvent{
+		ID:             threat.ID,
+		OrganizationID: threat.OrganizationID,
+		Category:       domain.SIEMEventThreat,
+		Type:           string(threat.ThreatType),
+		Name:           threat.Title,
+		Message:        threat.Description,
+		Severity:       threat.Severity,
+		ResourceType:   threat.TargetType,
+		SourceIP:       threat.Source,
+		OccurredAt:     s.occurredAt(threat.CreatedAt),
+		Fields:         map[string]string{"source": threat.Source, "blocked": strconv.FormatBool(threat.IsBlocked)},
+	}
+	if threat.TargetID != uuid.Nil {
+		event.ResourceID = &threat.TargetID
+	}
+	s.Publish(event)
+}
+
+// PublishAnomaly streams a detected anomaly
+func (s *SIEMExportService) PublishAnomaly(anomaly *domain.Anomaly) {
+	event := &domain.SIEMEvent{
+		ID:             anomaly.ID,
+		OrganizationID: anomaly.OrganizationID,
+		Category:       domain.SIEMEventAnomaly,
+		Type:           string(anomaly.AnomalyType),
+		Name:           anomaly.Title,
+		Message:        anomaly.Description,
+		Severity:       anomaly.Severity,
+		ResourceType:   anomaly.ResourceType,
+		OccurredAt:     s.occurredAt(anomaly.CreatedAt),
+		Fields:         map[string]string{"confidence": strconv.FormatFloat(anomaly.Confidence, 'f', -1, 64)},
+	}
+	if anomaly.ResourceID != uuid.Nil {
+		event.ResourceID = &anomaly.ResourceID
+	}
+	s.Publish(event)
+}
+
+// PublishAlert streams an alert, including drift alerts
+func (s *SIEMExportService) PublishAlert(alert *domain.Alert) {
+	event := &domain.SIEMEvent{
+		ID:             alert.ID,
+		OrganizationID: alert.OrganizationID,
+		Category:       domain.SIEMEventAlert,
+		Type:           string(alert.AlertType),
+		Name:           alert.Title,
+		Message:        alert.Description,
+		Severity:       alert.Severity,
+		ResourceType:   alert.ResourceType,
+		OccurredAt:     s.occurredAt(alert.CreatedAt),
+	}
+	if alert.ResourceID != uuid.Nil {
+		event.ResourceID = &alert.ResourceID
+	}
+	s.Publish(event)
+}
+
+// PublishVerificationFailure streams a verification event that failed, timed out or was denied;
+// other events are ignored
+func (s *SIEMExportService) PublishVerificationFailure(verification *domain.VerificationEvent) {
+	denied := verification.Result != nil && *verification.Result == domain.VerificationResultDenied
+	if !denied && verification.Status != domain.VerificationEventStatusFailed && verification.Status != domain.VerificationEventStatusTimeout {
+		return
+	}
+
+	event := &domain.SIEMEvent{
+		ID:             verification.ID,
+		OrganizationID: verification.OrganizationID,
+		Category:       domain.SIEMEventVerificationFailure,
+		Type:           string(verification.VerificationType),
+		Severity:       domain.AlertSeverityWarning,
+		OccurredAt:     s.occurredAt(verification.CreatedAt),
+		Fields: map[string]string{
+			"protocol": string(verification.Protocol),
+			"status":   string(verification.Status),
+		},
+	}
+	if denied {
+		event.Severity = domain.AlertSeverityHigh
+		event.Fields["result"] = string(*verification.Result)
+	}
+
+	target := "unknown target"
+	switch {
+	case verification.AgentID != nil:
+		event.ResourceType, event.ResourceID = "agent", verification.AgentID
+		if verification.AgentName != nil {
+			target = "agent " + *verification.AgentName
+		}
+	case verification.MCPServerID != nil:
+		event.ResourceType, event.ResourceID = "mcp_server", verification.MCPServerID
+		if verification.MCPServerName != nil {
+			target = "MCP server " + *verification.MCPServerName
+		}
+	}
+	event.Name = fmt.Sprintf("Verification %s for %s", event.Fields["status"], target)
+	if denied {
+		event.Name = "Verification denied for " + target
+	}
+
+	if verification.ErrorReason != nil {
+		event.Message = *verification.ErrorReason
+	}
+	if verification.ErrorCode != nil {
+		event.Fields["errorCode"] = *verification.ErrorCode
+	}
+	if verification.Action != nil {
+		event.Fields["action"] = *verification.Action
+	}
+	if verification.InitiatorIP != nil {
+		event.SourceIP = *verification.InitiatorIP
+	}
+	s.Publish(event)
+}
+
+// AlertRepository wraps an alert repository so alerts created through it are streamed
+func (s *SIEMExportService) AlertRepository(alertRepo domain.AlertRepository) domain.AlertRepository {
+	return &siemAlertRepository{AlertRepository: alertRepo, siemService: s}
+}
+
+type siemAlertRepository struct {
+	domain.AlertRepository
+	siemService *SIEMExportService
+}
+
+func (r *siemAlertRepository) Create(alert *domain.Alert) error {
+	if err := r.AlertRepository.Create(alert); err != nil {
+		return err
+	}
+	r.siemService.PublishAlert(alert)
+	return nil
+}
+
+// SecurityRepository wraps a security repository so threats and anomalies created through it
+// are streamed
+func (s *SIEMExportService) SecurityRepository(securityRepo domain.SecurityRepository) domain.SecurityRepository {
+	return &siemSecurityRepository{SecurityRepository: securityRepo, siemService: s}
+}
+
+type siemSecurityRepository struct {
+	domain.SecurityRepository
+	siemService *SIEMExportService
+}
+
+func (r *siemSecurityRepository) CreateThreat(threat *domain.Threat) error {
+	if err := r.SecurityRepository.CreateThreat(threat); err != nil {
+		return err
+	}
+	r.siemService.PublishThreat(threat)
+	return nil
+}
+
+func (r *siemSecurityRepository) CreateAnomaly(anomaly *domain.Anomaly) error {
+	if err := r.SecurityRepository.CreateAnomaly(anomaly); err != nil {
+		return err
+	}
+	r.siemService.PublishAnomaly(anomaly)
+	return nil
+}
+
+// VerificationEventRepository wraps a verification event repository so failed verifications
+// recorded through it, and verifications later denied, are streamed
+func (s *SIEMExportService) VerificationEventRepository(eventRepo domain.VerificationEventRepository) domain.VerificationEventRepository {
+	return &siemVerificationEventRepository{VerificationEventRepository: eventRepo, siemService: s}
+}
+
+type siemVerificationEventRepository struct {
+	domain.VerificationEventRepository
+	siemService *SIEMExportService
+}
+
+func (r *siemVerificationEventRepository) Create(event *domain.VerificationEvent) error {
+	if err := r.VerificationEventRepository.Create(event); err != nil {
+		return err
+	}
+	r.siemService.PublishVerificationFailure(event)
+	return nil
+}
+
+func (r *siemVerificationEventRepository) UpdateResult(id uuid.UUID, result domain.VerificationResult, reason *string, metadata map[string]interface{}) error {
+	if err := r.VerificationEventRepository.UpdateResult(id, result, reason, metadata); err != nil {
+		return err
+	}
+	if result == domain.VerificationResultDenied {
+		r.publishDenied(id)
+	}
+	return nil
+}
+
+func (r *siemVerificationEventRepository) UpdatePendingResults(ids []uuid.UUID, result domain.VerificationResult, reason *string, metadata map[string]interface{}) error {
+	if err := r.VerificationEventRepository.UpdatePendingResults(ids, result, reason, metadata); err != nil {
+		return err
+	}
+	if result == domain.VerificationResultDenied {
+		for _, id := range ids {
+			r.publishDenied(id)
+		}
+	}
+	return nil
+}
+
+func (r *siemVerificationEventRepository) publishDenied(id uuid.UUID) {
+	event, err := r.VerificationEventRepository.GetByID(id)
+	if err != nil {
+		log.Printf("⚠️  Denied verification %s not streamed to SIEMs: %v", id, err)
+		return
+	}
+	r.siemService.PublishVerificationFailure(event)
+}
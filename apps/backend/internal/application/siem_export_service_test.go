@@ -0,0 +1,182 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// siemTestSink records the events sent to it and fails while failing is set
+type siemTestSink struct {
+	mu      *sync.Mutex
+	events  *[]*domain.SIEMEvent
+	failing *bool
+}
+
+func (s *siemTestSink) Send(ctx context.Context, events []*domain.SIEMEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if *s.failing {
+		return fmt.Errorf("connection refused")
+	}
+	*s.events = append(*s.events, events...)
+	return nil
+}
+
+func (s *siemTestSink) Close() error { return nil }
+
+func TestSIEMExportStreamsFilteredSecurityEventsAndBuffersWhileUnreachable(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	admin := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(admin))
+	agent := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(agent))
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var sent []*domain.SIEMEvent
+	failing := false
+	service := NewSIEMExportService(repos.SIEMExporter,
+		func(exporter *domain.SIEMExporter) (domain.SIEMSink, error) {
+			return &siemTestSink{mu: &mu, events: &sent, failing: &failing}, nil
+		})
+	alertRepo := service.AlertRepository(repos.Alert)
+	securityRepo := service.SecurityRepository(repos.Security)
+	eventRepo := service.VerificationEventRepository(repos.VerificationEvent)
+	sentCategories := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		categories := make([]string, 0, len(sent))
+		for _, event := range sent {
+			categories = append(categories, event.Category)
+		}
+		sent = nil
+		return categories
+	}
+
+	// Settings are validated
+	_, err := service.CreateExporter(ctx, &SIEMExporterRequest{
+		Name: "Splunk", Host: "splunk.internal", Port: 514, Transport: "smtp", Format: domain.SIEMFormatCEF,
+	}, org.ID, admin.ID)
+	assert.ErrorIs(t, err, ErrInvalidSIEMExporter)
+	_, err = service.CreateExporter(ctx, &SIEMExporterRequest{
+		Name: "Splunk", Host: "splunk.internal", Port: 514, Transport: domain.SIEMTransportTCP, Format: domain.SIEMFormatCEF,
+		EventTypes: []string{"login"},
+	}, org.ID, admin.ID)
+	assert.ErrorIs(t, err, ErrInvalidSIEMExporter)
+
+	exporter, err := service.CreateExporter(ctx, &SIEMExporterRequest{
+		Name: "Splunk", Host: "splunk.internal", Port: 514, Transport: domain.SIEMTransportTCP, Format: domain.SIEMFormatCEF,
+		EventTypes:  []string{domain.SIEMEventThreat, domain.SIEMEventAlert, domain.SIEMEventVerificationFailure},
+		MinSeverity: domain.AlertSeverityWarning,
+	}, org.ID, admin.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 10, exporter.Facility)
+	assert.True(t, exporter.IsActive)
+
+	// Exporters of other organizations are not found
+	_, err = service.GetExporter(ctx, uuid.New(), exporter.ID)
+	assert.ErrorIs(t, err, domain.ErrSIEMExporterNotFound)
+
+	// Threats, alerts and failed or denied verifications are streamed; anomalies are not
+	// selected and info alerts are below the minimum severity
+	require.NoError(t, securityRepo.CreateThreat(&domain.Threat{
+		OrganizationID: org.ID, ThreatType: domain.ThreatTypeBruteForce, Severity: domain.AlertSeverityHigh,
+		Title: "Brute force", Source: "203.0.113.7", TargetType: "agent", TargetID: agent.ID,
+	}))
+	require.NoError(t, securityRepo.CreateAnomaly(&domain.Anomaly{
+		OrganizationID: org.ID, AnomalyType: domain.AnomalyTypeUnusualAPIUsage, Severity: domain.AlertSeverityCritical,
+		Title: "Unusual API usage", ResourceType: "agent", ResourceID: agent.ID,
+	}))
+	require.NoError(t, alertRepo.Create(&domain.Alert{
+		OrganizationID: org.ID, AlertType: domain.AlertTypeConfigurationDrift, Severity: domain.AlertSeverityWarning,
+		Title: "Drift", ResourceType: "agent", ResourceID: agent.ID,
+	}))
+	require.NoError(t, alertRepo.Create(&domain.Alert{
+		OrganizationID: org.ID, AlertType: domain.AlertAgentOffline, Severity: domain.AlertSeverityInfo, Title: "FYI",
+	}))
+	require.NoError(t, eventRepo.Create(&domain.VerificationEvent{
+		OrganizationID: org.ID, AgentID: &agent.ID, Status: domain.VerificationEventStatusSuccess,
+	}))
+	require.NoError(t, eventRepo.Create(&domain.VerificationEvent{
+		OrganizationID: org.ID, AgentID: &agent.ID, Status: domain.VerificationEventStatusFailed,
+	}))
+	pending := &domain.VerificationEvent{OrganizationID: org.ID, AgentID: &agent.ID, Status: domain.VerificationEventStatusPending}
+	require.NoError(t, eventRepo.Create(pending))
+	require.NoError(t, eventRepo.UpdateResult(pending.ID, domain.VerificationResultDenied, nil, nil))
+
+	delivered, err := service.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, delivered)
+	assert.Equal(t, []string{
+		domain.SIEMEventThreat, domain.SIEMEventAlert, domain.SIEMEventVerificationFailure, domain.SIEMEventVerificationFailure,
+	}, sentCategories())
+
+	stored, err := service.GetExporter(ctx, org.ID, exporter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), stored.DeliveredCount)
+	assert.NotNil(t, stored.LastDeliveredAt)
+
+	// While the SIEM is unreachable events stay buffered and the failure is recorded; the
+	// exporter backs off instead of retrying on every flush
+	mu.Lock()
+	failing = true
+	mu.Unlock()
+	require.NoError(t, alertRepo.Create(&domain.Alert{
+		OrganizationID: org.ID, AlertType: domain.AlertTypeConfigurationDrift, Severity: domain.AlertSeverityHigh, Title: "Drift",
+	}))
+	delivered, err = service.Flush(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+	stored, err = service.GetExporter(ctx, org.ID, exporter.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.LastError)
+	assert.Contains(t, *stored.LastError, "connection refused")
+
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	delivered, err = service.Flush(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, delivered, "the exporter is backing off")
+
+	// The buffered event goes out once the SIEM is reachable again
+	require.Eventually(t, func() bool {
+		delivered, err := service.Flush(ctx)
+		return err == nil && delivered == 1
+	}, 5*time.Second, 100*time.Millisecond)
+	assert.Equal(t, []string{domain.SIEMEventAlert}, sentCategories())
+	stored, err = service.GetExporter(ctx, org.ID, exporter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), stored.DeliveredCount)
+	assert.Nil(t, stored.LastError)
+
+	// Deactivated exporters stop streaming
+	inactive := false
+	_, err = service.UpdateExporter(ctx, org.ID, exporter.ID, &SIEMExporterRequest{
+		Name: "Splunk", Host: "splunk.internal", Port: 514, Transport: domain.SIEMTransportTCP, Format: domain.SIEMFormatCEF,
+		IsActive: &inactive,
+	})
+	require.NoError(t, err)
+	require.NoError(t, alertRepo.Create(&domain.Alert{
+		OrganizationID: org.ID, AlertType: domain.AlertTypeConfigurationDrift, Severity: domain.AlertSeverityCritical, Title: "Drift",
+	}))
+	delivered, err = service.Flush(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+	assert.Empty(t, sentCategories())
+
+	require.NoError(t, service.DeleteExporter(ctx, org.ID, exporter.ID))
+	_, err = service.GetExporter(ctx, org.ID, exporter.ID)
+	assert.ErrorIs(t, err, domain.ErrSIEMExporterNotFound)
+}
@@ -11,9 +11,10 @@ import (
 
 // TagService handles business logic for tag management
 type TagService struct {
-	tagRepo   domain.TagRepository
-	agentRepo domain.AgentRepository
-	mcpRepo   domain.MCPServerRepository
+	tagRepo        domain.TagRepository
+	agentRepo      domain.AgentRepository
+	mcpRepo        domain.MCPServerRepository
+	namingPolicies *NamingPolicyService // Optional: naming policies scoped to the added tags
 }
 
 // NewTagService creates a new tag service instance
@@ -21,11 +22,13 @@ func NewTagService(
 	tagRepo domain.TagRepository,
 	agentRepo domain.AgentRepository,
 	mcpRepo domain.MCPServerRepository,
+	namingPolicies *NamingPolicyService,
 ) *TagService {
 	return &TagService{
-		tagRepo:   tagRepo,
-		agentRepo: agentRepo,
-		mcpRepo:   mcpRepo,
+		tagRepo:        tagRepo,
+		agentRepo:      agentRepo,
+		mcpRepo:        mcpRepo,
+		namingPolicies: namingPolicies,
 	}
 }
 
@@ -163,6 +166,7 @@ func (s *TagService) AddTagsToAgent(ctx context.Context, agentID uuid.UUID, tagI
 	}
 
 	// Verify all tags exist and belong to same organization
+	tags := make([]*domain.Tag, 0, len(tagIDs))
 	for _, tagID := range tagIDs {
 		tag, err := s.tagRepo.GetByID(ctx, tagID)
 		if err != nil {
@@ -171,6 +175,14 @@ func (s *TagService) AddTagsToAgent(ctx context.Context, agentID uuid.UUID, tagI
 		if tag.OrganizationID != agent.OrganizationID {
 			return fmt.Errorf("tag %s does not belong to agent's organization", tagID)
 		}
+		tags = append(tags, tag)
+	}
+
+	// Naming policies scoped to the new tags (e.g. environment=production) must accept the agent's name
+	if s.namingPolicies != nil {
+		if err := s.namingPolicies.EnforceName(ctx, agent.OrganizationID, domain.NamingResourceAgent, agent.ID, agent.Name, tags); err != nil {
+			return err
+		}
 	}
 
 	// Add tags (database trigger enforces Community Edition 3-tag limit)
@@ -198,6 +210,15 @@ func (s *TagService) GetAgentTags(ctx context.Context, agentID uuid.UUID) ([]*do
 	return tags, nil
 }
 
+// GetTagsForAgents retrieves the tags of several agents in one query
+func (s *TagService) GetTagsForAgents(ctx context.Context, agentIDs []uuid.UUID) (map[uuid.UUID][]*domain.Tag, error) {
+	tags, err := s.tagRepo.GetTagsForAgents(ctx, agentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent tags: %w", err)
+	}
+	return tags, nil
+}
+
 // AddTagsToMCPServer adds tags to an MCP server with smart suggestions
 func (s *TagService) AddTagsToMCPServer(ctx context.Context, mcpServerID uuid.UUID, tagIDs []uuid.UUID, appliedBy uuid.UUID) error {
 	// Verify MCP server exists
@@ -207,6 +228,7 @@ func (s *TagService) AddTagsToMCPServer(ctx context.Context, mcpServerID uuid.UU
 	}
 
 	// Verify all tags exist and belong to same organization
+	tags := make([]*domain.Tag, 0, len(tagIDs))
 	for _, tagID := range tagIDs {
 		tag, err := s.tagRepo.GetByID(ctx, tagID)
 		if err != nil {
@@ -215,6 +237,14 @@ func (s *TagService) AddTagsToMCPServer(ctx context.Context, mcpServerID uuid.UU
 		if tag.OrganizationID != mcpServer.OrganizationID {
 			return fmt.Errorf("tag %s does not belong to mcp server's organization", tagID)
 		}
+		tags = append(tags, tag)
+	}
+
+	// Naming policies scoped to the new tags must accept the server's name
+	if s.namingPolicies != nil {
+		if err := s.namingPolicies.EnforceName(ctx, mcpServer.OrganizationID, domain.NamingResourceMCPServer, mcpServer.ID, mcpServer.Name, tags); err != nil {
+			return err
+		}
 	}
 
 	// Add tags (database trigger enforces Community Edition 3-tag limit)
@@ -242,6 +272,15 @@ func (s *TagService) GetMCPServerTags(ctx context.Context, mcpServerID uuid.UUID
 	return tags, nil
 }
 
+// GetTagsForMCPServers retrieves the tags of several MCP servers in one query
+func (s *TagService) GetTagsForMCPServers(ctx context.Context, mcpServerIDs []uuid.UUID) (map[uuid.UUID][]*domain.Tag, error) {
+	tags, err := s.tagRepo.GetTagsForMCPServers(ctx, mcpServerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mcp server tags: %w", err)
+	}
+	return tags, nil
+}
+
 // SuggestTagsForAgent suggests tags based on agent metadata
 // Smart suggestions based on agent type, capabilities, trust score, and MCP connections
 func (s *TagService) SuggestTagsForAgent(ctx context.Context, agentID uuid.UUID) ([]*domain.Tag, error) {
@@ -0,0 +1,286 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrInvalidTalksToRecommendationRequest is returned for a period or contact threshold out of range
+	ErrInvalidTalksToRecommendationRequest = errors.New("invalid talks_to recommendation request")
+	// ErrTalksToRecommendationAgentNotFound is returned when the agent is not in the organization
+	ErrTalksToRecommendationAgentNotFound = errors.New("agent not found")
+)
+
+const (
+	defaultTalksToRecommendationDays        = 30
+	maxTalksToRecommendationDays            = 365
+	defaultTalksToRecommendationMinContacts = 10
+)
+
+// Actions of a TalksToSuggestion
+const (
+	TalksToSuggestionRemove = "remove"
+	TalksToSuggestionAdd    = "add"
+)
+
+// TalksToRecommendationService reviews agents' talks_to allowlists against the MCP servers they
+// actually contact, as reported in verification events and shown by their attestations. It
+// suggests removing entries the agent has not contacted in the period, and adding servers it
+// contacts often without listing them, before drift detection penalizes it. Suggestions are only
+// returned; they are applied through the agent's MCP server endpoints.
+type TalksToRecommendationService struct {
+	agentRepo       domain.AgentRepository
+	eventRepo       domain.VerificationEventRepository
+	attestationRepo domain.MCPAttestationRepository
+	mcpRepo         domain.MCPServerRepository
+	now             func() time.Time
+}
+
+// NewTalksToRecommendationService creates a new talks_to recommendation service
+func NewTalksToRecommendationService(
+	agentRepo domain.AgentRepository,
+	eventRepo domain.VerificationEventRepository,
+	attestationRepo domain.MCPAttestationRepository,
+	mcpRepo domain.MCPServerRepository,
+) *TalksToRecommendationService {
+	return &TalksToRecommendationService{
+		agentRepo:       agentRepo,
+		eventRepo:       eventRepo,
+		attestationRepo: attestationRepo,
+		mcpRepo:         mcpRepo,
+		now:             time.Now,
+	}
+}
+
+// TalksToRecommendationRequest selects the usage the suggestions are based on. Zero values look
+// at the last 30 days and suggest servers contacted at least 10 times.
+type TalksToRecommendationRequest struct {
+	OrganizationID uuid.UUID
+	AgentID        uuid.UUID // Ignored by RecommendForOrganization
+	Days           int
+	MinContacts    int
+}
+
+// TalksToSuggestion is one proposed change to an agent's talks_to list
+type TalksToSuggestion struct {
+	Action        string     `json:"action"`                // "remove" or "add"
+	MCPServer     string     `json:"mcpServer"`             // The talks_to entry, or the server name as contacted
+	MCPServerID   *uuid.UUID `json:"mcpServerId,omitempty"` // Set when it is a registered MCP server
+	Contacts      int        `json:"contacts"`              // Verifications reporting the server plus attestations of it
+	LastContactAt *time.Time `json:"lastContactAt,omitempty"`
+	Reason        string     `json:"reason"`
+}
+
+// TalksToRecommendations are the suggestions for one agent
+type TalksToRecommendations struct {
+	AgentID     uuid.UUID           `json:"agentId"`
+	AgentName   string              `json:"agentName"`
+	TalksTo     []string            `json:"talksTo"`
+	Since       time.Time           `json:"since"`
+	Suggestions []TalksToSuggestion `json:"suggestions"`
+}
+
+// mcpContact is how often an agent contacted one MCP server in the period
+type mcpContact struct {
+	name     string
+	serverID *uuid.UUID
+	count    int
+	last     time.Time
+}
+
+// Recommend returns the suggestions for one agent
+func (s *TalksToRecommendationService) Recommend(ctx context.Context, req *TalksToRecommendationRequest) (*TalksToRecommendations, error) {
+	days, minContacts, err := talksToRecommendationBounds(req)
+	if err != nil {
+		return nil, err
+	}
+	agent, err := s.agentRepo.GetByID(req.AgentID)
+	if err != nil || agent.OrganizationID != req.OrganizationID {
+		return nil, ErrTalksToRecommendationAgentNotFound
+	}
+
+	recommendations, err := s.recommend(req.OrganizationID, []*domain.Agent{agent}, days, minContacts)
+	if err != nil {
+		return nil, err
+	}
+	return recommendations[0], nil
+}
+
+// RecommendForOrganization returns the suggestions for every agent of the organization that has any
+func (s *TalksToRecommendationService) RecommendForOrganization(ctx context.Context, req *TalksToRecommendationRequest) ([]*TalksToRecommendations, error) {
+	days, minContacts, err := talksToRecommendationBounds(req)
+	if err != nil {
+		return nil, err
+	}
+	agents, err := s.agentRepo.GetByOrganization(req.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+
+	recommendations, err := s.recommend(req.OrganizationID, agents, days, minContacts)
+	if err != nil {
+		return nil, err
+	}
+	withSuggestions := make([]*TalksToRecommendations, 0)
+	for _, r := range recommendations {
+		if len(r.Suggestions) > 0 {
+			withSuggestions = append(withSuggestions, r)
+		}
+	}
+	return withSuggestions, nil
+}
+
+func talksToRecommendationBounds(req *TalksToRecommendationRequest) (int, int, error) {
+	days, minContacts := req.Days, req.MinContacts
+	if days == 0 {
+		days = defaultTalksToRecommendationDays
+	}
+	if minContacts == 0 {
+		minContacts = defaultTalksToRecommendationMinContacts
+	}
+	if days < 1 || days > maxTalksToRecommendationDays {
+		return 0, 0, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidTalksToRecommendationRequest, maxTalksToRecommendationDays)
+	}
+	if minContacts < 1 {
+		return 0, 0, fmt.Errorf("%w: min_contacts must be positive", ErrInvalidTalksToRecommendationRequest)
+	}
+	return days, minContacts, nil
+}
+
+func (s *TalksToRecommendationService) recommend(orgID uuid.UUID, agents []*domain.Agent, days, minContacts int) ([]*TalksToRecommendations, error) {
+	now := s.now().UTC()
+	since := now.AddDate(0, 0, -days)
+
+	servers, err := s.mcpRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MCP servers: %w", err)
+	}
+	serverByKey := make(map[string]*domain.MCPServer, 2*len(servers))
+	serverByID := make(map[uuid.UUID]*domain.MCPServer, len(servers))
+	for _, server := range servers {
+		serverByKey[server.Name] = server
+		serverByKey[server.ID.String()] = server
+		serverByID[server.ID] = server
+	}
+
+	activity, err := s.eventRepo.GetHourlyAgentActivity(orgID, since, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent activity: %w", err)
+	}
+	reported := make(map[uuid.UUID][]*domain.AgentHourlyActivity)
+	for _, hour := range activity {
+		reported[hour.AgentID] = append(reported[hour.AgentID], hour)
+	}
+
+	recommendations := make([]*TalksToRecommendations, 0, len(agents))
+	for _, agent := range agents {
+		contacts := make(map[string]*mcpContact)
+		// Servers are keyed by ID when registered, so contacts by name and by ID add up
+		contact := func(name string, at time.Time, count int) {
+			key, serverID := name, (*uuid.UUID)(nil)
+			if server, ok := serverByKey[name]; ok {
+				key, name = server.ID.String(), server.Name
+				id := server.ID
+				serverID = &id
+			}
+			c, ok := contacts[key]
+			if !ok {
+				c = &mcpContact{name: name, serverID: serverID}
+				contacts[key] = c
+			}
+			c.count += count
+			if at.After(c.last) {
+				c.last = at
+			}
+		}
+		for _, hour := range reported[agent.ID] {
+			for name, count := range hour.MCPServers {
+				contact(name, hour.Hour.Add(time.Hour), count)
+			}
+		}
+		attestations, err := s.attestationRepo.GetAttestationsByAgent(agent.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load attestations of agent %s: %w", agent.ID, err)
+		}
+		for _, attestation := range attestations {
+			if server, ok := serverByID[attestation.MCPServerID]; ok && !attestation.CreatedAt.Before(since) {
+				contact(server.ID.String(), attestation.CreatedAt, 1)
+			}
+		}
+
+		recommendations = append(recommendations, &TalksToRecommendations{
+			AgentID:     agent.ID,
+			AgentName:   agent.Name,
+			TalksTo:     agent.TalksTo,
+			Since:       since,
+			Suggestions: talksToSuggestions(agent, contacts, serverByKey, since, days, minContacts),
+		})
+	}
+	return recommendations, nil
+}
+
+// talksToSuggestions compares the agent's talks_to entries to its contacts. Removals are only
+// suggested for agents registered before the period started, as younger agents may not have
+// needed every server yet.
+func talksToSuggestions(agent *domain.Agent, contacts map[string]*mcpContact, serverByKey map[string]*domain.MCPServer, since time.Time, days, minContacts int) []TalksToSuggestion {
+	suggestions := make([]TalksToSuggestion, 0)
+
+	listed := make(map[string]bool)
+	for _, entry := range agent.TalksTo {
+		key := entry
+		var serverID *uuid.UUID
+		if server, ok := serverByKey[entry]; ok {
+			key = server.ID.String()
+			id := server.ID
+			serverID = &id
+		}
+		listed[key] = true
+		if contacts[key] != nil || agent.CreatedAt.After(since) {
+			continue
+		}
+		suggestions = append(suggestions, TalksToSuggestion{
+			Action:      TalksToSuggestionRemove,
+			MCPServer:   entry,
+			MCPServerID: serverID,
+			Reason:      fmt.Sprintf("not contacted in the last %d days", days),
+		})
+	}
+
+	keys := make([]string, 0, len(contacts))
+	for key := range contacts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if contacts[keys[i]].count != contacts[keys[j]].count {
+			return contacts[keys[i]].count > contacts[keys[j]].count
+		}
+		return contacts[keys[i]].name < contacts[keys[j]].name
+	})
+	for _, key := range keys {
+		c := contacts[key]
+		if listed[key] || c.count < minContacts {
+			continue
+		}
+		last := c.last
+		reason := fmt.Sprintf("contacted %d times in the last %d days but not in talks_to", c.count, days)
+		if c.serverID == nil {
+			reason += "; not a registered MCP server"
+		}
+		suggestions = append(suggestions, TalksToSuggestion{
+			Action:        TalksToSuggestionAdd,
+			MCPServer:     c.name,
+			MCPServerID:   c.serverID,
+			Contacts:      c.count,
+			LastContactAt: &last,
+			Reason:        reason,
+		})
+	}
+	return suggestions
+}
@@ -0,0 +1,84 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTalksToRecommendationsFollowActualMCPServerUsage(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	filesystem := testsupport.NewMCPServer(org.ID, func(s *domain.MCPServer) { s.Name = "filesystem" })
+	github := testsupport.NewMCPServer(org.ID, func(s *domain.MCPServer) { s.Name = "github" })
+	slack := testsupport.NewMCPServer(org.ID, func(s *domain.MCPServer) { s.Name = "slack" })
+	for _, server := range []*domain.MCPServer{filesystem, github, slack} {
+		require.NoError(t, repos.MCPServer.Create(server))
+	}
+	established := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.TalksTo = []string{"filesystem", github.ID.String(), "slack"} })
+	recent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.TalksTo = []string{"slack"} })
+	for _, agent := range []*domain.Agent{established, recent} {
+		require.NoError(t, repos.Agent.Create(agent))
+	}
+	// Backdate the established agent's registration past every period
+	established.CreatedAt = time.Now().AddDate(0, 0, -120)
+	require.NoError(t, repos.Agent.Update(established))
+	ctx := context.Background()
+
+	verify := func(agent *domain.Agent, daysAgo, count int, servers ...string) {
+		for i := 0; i < count; i++ {
+			require.NoError(t, repos.VerificationEvent.Create(testsupport.NewVerificationEvent(agent, func(e *domain.VerificationEvent) {
+				e.CreatedAt = time.Now().AddDate(0, 0, -daysAgo).Add(time.Duration(i) * time.Minute)
+				e.CurrentMCPServers = servers
+			})))
+		}
+	}
+	// filesystem is used by name; github is only attested; slack was last used two months ago;
+	// postgres is used heavily without being listed or registered, and jira only twice
+	verify(established, 3, 4, "filesystem")
+	verify(established, 60, 20, "slack")
+	verify(established, 2, 15, "postgres")
+	verify(established, 1, 2, "jira")
+	require.NoError(t, repos.MCPAttestation.CreateAttestation(testsupport.NewMCPAttestation(github, established)))
+
+	service := NewTalksToRecommendationService(repos.Agent, repos.VerificationEvent, repos.MCPAttestation, repos.MCPServer)
+	recommendations, err := service.Recommend(ctx, &TalksToRecommendationRequest{OrganizationID: org.ID, AgentID: established.ID})
+	require.NoError(t, err)
+	require.Len(t, recommendations.Suggestions, 2)
+	removal, addition := recommendations.Suggestions[0], recommendations.Suggestions[1]
+	assert.Equal(t, TalksToSuggestionRemove, removal.Action)
+	assert.Equal(t, "slack", removal.MCPServer)
+	require.NotNil(t, removal.MCPServerID)
+	assert.Equal(t, slack.ID, *removal.MCPServerID)
+	assert.Equal(t, TalksToSuggestionAdd, addition.Action)
+	assert.Equal(t, "postgres", addition.MCPServer)
+	assert.Nil(t, addition.MCPServerID)
+	assert.Equal(t, 15, addition.Contacts)
+
+	// A longer period covers the old slack usage; a lower threshold suggests jira too
+	recommendations, err = service.Recommend(ctx, &TalksToRecommendationRequest{OrganizationID: org.ID, AgentID: established.ID, Days: 90, MinContacts: 2})
+	require.NoError(t, err)
+	var suggested []string
+	for _, suggestion := range recommendations.Suggestions {
+		suggested = append(suggested, suggestion.Action+":"+suggestion.MCPServer)
+	}
+	assert.Equal(t, []string{"add:postgres", "add:jira"}, suggested)
+
+	// Agents younger than the period get no removals, and agents without suggestions are left out
+	all, err := service.RecommendForOrganization(ctx, &TalksToRecommendationRequest{OrganizationID: org.ID})
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, established.ID, all[0].AgentID)
+
+	_, err = service.Recommend(ctx, &TalksToRecommendationRequest{OrganizationID: uuid.New(), AgentID: established.ID})
+	assert.ErrorIs(t, err, ErrTalksToRecommendationAgentNotFound)
+	_, err = service.Recommend(ctx, &TalksToRecommendationRequest{OrganizationID: org.ID, AgentID: established.ID, Days: 400})
+	assert.ErrorIs(t, err, ErrInvalidTalksToRecommendationRequest)
+}
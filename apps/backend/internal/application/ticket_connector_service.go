@@ -0,0 +1,567 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ticketSyncBatchSize caps the tickets one sync run reads back
+const ticketSyncBatchSize = 100
+
+var (
+	// ErrInvalidTicketConnector is returned when a ticket connector's settings are invalid
+	ErrInvalidTicketConnector = errors.New("invalid ticket connector")
+	// ErrInvalidTicketWebhookSecret is returned for webhook calls without the connector's secret
+	ErrInvalidTicketWebhookSecret = errors.New("invalid ticket webhook secret")
+)
+
+// TicketSystemFactory returns the ticketing system of a connector with its decrypted API token
+type TicketSystemFactory func(connector *domain.TicketConnector, apiToken string) domain.TicketSystem
+
+// TicketConnectorService manages organizations' Jira and ServiceNow connectors, opens tickets for
+// new capability requests and incidents, and syncs the tickets' status and comments back
+type TicketConnectorService struct {
+	repo               domain.TicketConnectorRepository
+	agentRepo          domain.AgentRepository
+	keyVault           *crypto.KeyVault
+	systems            TicketSystemFactory
+	capabilityRequests *CapabilityRequestService
+	incidents          *IncidentService
+	now                func() time.Time
+}
+
+// NewTicketConnectorService creates a new ticket connector service. API tokens are stored
+// encrypted with the key vault.
+func NewTicketConnectorService(
+	repo domain.TicketConnectorRepository,
+	agentRepo domain.AgentRepository,
+	keyVault *crypto.KeyVault,
+	systems TicketSystemFactory,
+) *TicketConnectorService {
+	return &TicketConnectorService{
+		repo:      repo,
+		agentRepo: agentRepo,
+		keyVault:  keyVault,
+		systems:   systems,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// UseWorkflows sets the services ticket statuses and comments act through. They are created
+// after the repositories this service wraps; until then tickets are opened but not synced.
+func (s *TicketConnectorService) UseWorkflows(capabilityRequests *CapabilityRequestService, incidents *IncidentService) {
+	s.capabilityRequests = capabilityRequests
+	s.incidents = incidents
+}
+
+// TicketConnectorRequest represents the request to create or update a ticket connector
+type TicketConnectorRequest struct {
+	Name             string                           `json:"name"`
+	Provider         string                           `json:"provider"` // "jira" or "servicenow"; cannot change
+	BaseURL          string                           `json:"baseUrl"`
+	Username         string                           `json:"username"`
+	APIToken         string                           `json:"apiToken,omitempty"` // Required on create; empty keeps the current token
+	Project          string                           `json:"project"`
+	IssueType        string                           `json:"issueType"`
+	ResourceTypes    []string                         `json:"resourceTypes"`
+	ApprovedStatuses []string                         `json:"approvedStatuses"`
+	RejectedStatuses []string                         `json:"rejectedStatuses"`
+	IncidentStatuses map[string]domain.IncidentStatus `json:"incidentStatuses"`
+	IsEnabled        *bool                            `json:"isEnabled,omitempty"` // Pointer to distinguish between false and not provided
+}
+
+// CreateConnector creates a connector. The returned connector carries its webhook secret,
+// which is not shown again.
+func (s *TicketConnectorService) CreateConnector(ctx context.Context, req *TicketConnectorRequest, orgID, userID uuid.UUID) (*domain.TicketConnector, error) {
+	if strings.TrimSpace(req.APIToken) == "" {
+		return nil, fmt.Errorf("%w: apiToken is required", ErrInvalidTicketConnector)
+	}
+
+	connector := &domain.TicketConnector{
+		OrganizationID: orgID,
+		Provider:       req.Provider,
+		IsEnabled:      true,
+		CreatedBy:      userID,
+	}
+	if err := s.applyConnectorRequest(connector, req); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	connector.WebhookSecretHash = hashTicketWebhookSecret(secret)
+
+	if err := s.repo.CreateConnector(connector); err != nil {
+		return nil, fmt.Errorf("failed to create ticket connector: %w", err)
+	}
+
+	connector.WebhookSecret = secret
+	return connector, nil
+}
+
+// ListConnectors lists the organization's connectors, oldest first
+func (s *TicketConnectorService) ListConnectors(ctx context.Context, orgID uuid.UUID) ([]*domain.TicketConnector, error) {
+	return s.repo.GetConnectorsByOrganization(orgID)
+}
+
+// GetConnector returns one of the organization's connectors
+func (s *TicketConnectorService) GetConnector(ctx context.Context, orgID, id uuid.UUID) (*domain.TicketConnector, error) {
+	connector, err := s.repo.GetConnector(id)
+	if err != nil {
+		return nil, err
+	}
+	if connector.OrganizationID != orgID {
+		return nil, domain.ErrTicketConnectorNotFound
+	}
+	return connector, nil
+}
+
+// UpdateConnector updates one of the organization's connectors
+func (s *TicketConnectorService) UpdateConnector(ctx context.Context, orgID, id uuid.UUID, req *TicketConnectorRequest) (*domain.TicketConnector, error) {
+	connector, err := s.GetConnector(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Provider != "" && req.Provider != connector.Provider {
+		return nil, fmt.Errorf("%w: provider cannot change", ErrInvalidTicketConnector)
+	}
+
+	if err := s.applyConnectorRequest(connector, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateConnector(connector); err != nil {
+		return nil, fmt.Errorf("failed to update ticket connector: %w", err)
+	}
+	return connector, nil
+}
+
+// DeleteConnector deletes one of the organization's connectors. Its tickets stay in the
+// ticketing system but are no longer synced.
+func (s *TicketConnectorService) DeleteConnector(ctx context.Context, orgID, id uuid.UUID) error {
+	if _, err := s.GetConnector(ctx, orgID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteConnector(id)
+}
+
+// ListLinks lists the tickets opened for one of the organization's capability requests or incidents
+func (s *TicketConnectorService) ListLinks(ctx context.Context, orgID uuid.UUID, resourceType string, resourceID uuid.UUID) ([]*domain.TicketLink, error) {
+	links, err := s.repo.GetLinksByResource(resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	owned := make([]*domain.TicketLink, 0, len(links))
+	for _, link := range links {
+		if link.OrganizationID == orgID {
+			owned = append(owned, link)
+		}
+	}
+	return owned, nil
+}
+
+// applyConnectorRequest validates a request and copies it onto the connector
+func (s *TicketConnectorService) applyConnectorRequest(connector *domain.TicketConnector, req *TicketConnectorRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTicketConnector)
+	}
+	if connector.Provider != "jira" && connector.Provider != "servicenow" {
+		return fmt.Errorf("%w: provider must be jira or servicenow", ErrInvalidTicketConnector)
+	}
+	baseURL, err := url.Parse(strings.TrimSpace(req.BaseURL))
+	if err != nil || (baseURL.Scheme != "https" && baseURL.Scheme != "http") || baseURL.Host == "" {
+		return fmt.Errorf("%w: baseUrl must be an http(s) URL", ErrInvalidTicketConnector)
+	}
+	if strings.TrimSpace(req.Username) == "" {
+		return fmt.Errorf("%w: username is required", ErrInvalidTicketConnector)
+	}
+	if connector.Provider == "jira" && strings.TrimSpace(req.Project) == "" {
+		return fmt.Errorf("%w: project must be the Jira project key", ErrInvalidTicketConnector)
+	}
+
+	if len(req.ResourceTypes) == 0 {
+		return fmt.Errorf("%w: at least one resource type is required", ErrInvalidTicketConnector)
+	}
+	for _, resourceType := range req.ResourceTypes {
+		if resourceType != domain.TicketResourceCapabilityRequest && resourceType != domain.TicketResourceIncident {
+			return fmt.Errorf("%w: resource types must be capability_request or incident", ErrInvalidTicketConnector)
+		}
+	}
+	incidentStatuses := make(map[string]domain.IncidentStatus, len(req.IncidentStatuses))
+	for ticketStatus, status := range req.IncidentStatuses {
+		if !status.IsValid() {
+			return fmt.Errorf("%w: invalid incident status %q for ticket status %q", ErrInvalidTicketConnector, status, ticketStatus)
+		}
+		incidentStatuses[strings.TrimSpace(ticketStatus)] = status
+	}
+
+	if req.APIToken != "" {
+		encrypted, err := s.keyVault.EncryptPrivateKey(req.APIToken)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt API token: %w", err)
+		}
+		connector.EncryptedAPIToken = encrypted
+	}
+
+	connector.Name = strings.TrimSpace(req.Name)
+	connector.BaseURL = strings.TrimRight(baseURL.String(), "/")
+	connector.Username = strings.TrimSpace(req.Username)
+	connector.Project = strings.TrimSpace(req.Project)
+	connector.IssueType = strings.TrimSpace(req.IssueType)
+	connector.ResourceTypes = slices.Compact(slices.Sorted(slices.Values(req.ResourceTypes)))
+	connector.ApprovedStatuses = trimmedStatuses(req.ApprovedStatuses)
+	connector.RejectedStatuses = trimmedStatuses(req.RejectedStatuses)
+	connector.IncidentStatuses = incidentStatuses
+	if req.IsEnabled != nil {
+		connector.IsEnabled = *req.IsEnabled
+	}
+	return nil
+}
+
+func trimmedStatuses(statuses []string) []string {
+	trimmed := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		if status = strings.TrimSpace(status); status != "" {
+			trimmed = append(trimmed, status)
+		}
+	}
+	return trimmed
+}
+
+func hashTicketWebhookSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// system returns the connector's ticketing system
+func (s *TicketConnectorService) system(connector *domain.TicketConnector) (domain.TicketSystem, error) {
+	apiToken, err := s.keyVault.DecryptPrivateKey(connector.EncryptedAPIToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt API token of ticket connector %s: %w", connector.ID, err)
+	}
+	return s.systems(connector, apiToken), nil
+}
+
+// OpenCapabilityRequestTickets opens a ticket for a new capability request with each enabled
+// connector of the agent's organization covering capability requests
+func (s *TicketConnectorService) OpenCapabilityRequestTickets(ctx context.Context, request *domain.CapabilityRequest) {
+	agent, err := s.agentRepo.GetByID(request.AgentID)
+	if err != nil {
+		log.Printf("⚠️  No tickets opened for capability request %s: %v", request.ID, err)
+		return
+	}
+
+	s.openTickets(ctx, agent.OrganizationID, domain.TicketResourceCapabilityRequest, request.ID, domain.Ticket{
+		Summary: fmt.Sprintf("[AIM] Capability request: %s for agent %s", request.CapabilityType, agent.Name),
+		Description: fmt.Sprintf("%s\n\nCapability request: %s\nAgent: %s (%s)\nCapability: %s",
+			request.Reason, request.ID, agent.Name, agent.ID, request.CapabilityType),
+		Severity: capabilityRiskSeverity(request.CapabilityType),
+		Labels:   []string{"aim", "capability-request"},
+	})
+}
+
+// OpenIncidentTickets opens a ticket for a new incident with each enabled connector of its
+// organization covering incidents
+func (s *TicketConnectorService) OpenIncidentTickets(ctx context.Context, incident *domain.SecurityIncident) {
+	s.openTickets(ctx, incident.OrganizationID, domain.TicketResourceIncident, incident.ID, domain.Ticket{
+		Summary: fmt.Sprintf("[AIM] %s", incident.Title),
+		Description: fmt.Sprintf("%s\n\nIncident: %s (%s, %s)\nAffected resources: %s",
+			incident.Description, incident.ID, incident.IncidentType, incident.Severity,
+			strings.Join(incident.AffectedResources, ", ")),
+		Severity: incident.Severity,
+		Labels:   []string{"aim", "security-incident"},
+	})
+}
+
+// openTickets opens the ticket with every enabled connector of the organization covering the
+// resource type and links it to the resource. Failures are logged; the resource is not held up.
+func (s *TicketConnectorService) openTickets(ctx context.Context, orgID uuid.UUID, resourceType string, resourceID uuid.UUID, ticket domain.Ticket) {
+	connectors, err := s.repo.GetConnectorsByOrganization(orgID)
+	if err != nil {
+		log.Printf("⚠️  Failed to load ticket connectors of organization %s: %v", orgID, err)
+		return
+	}
+
+	for _, connector := range connectors {
+		if !connector.IsEnabled || !connector.Covers(resourceType) {
+			continue
+		}
+		system, err := s.system(connector)
+		if err != nil {
+			log.Printf("⚠️  %v", err)
+			continue
+		}
+
+		ticket.Provider = connector.Provider
+		ticket.Project = connector.Project
+		reference, err := system.CreateTicket(ctx, &ticket)
+		if err != nil {
+			log.Printf("⚠️  Ticket connector %s failed to open a ticket for %s %s: %v", connector.Name, resourceType, resourceID, err)
+			continue
+		}
+
+		link := &domain.TicketLink{
+			OrganizationID:   orgID,
+			ConnectorID:      connector.ID,
+			ResourceType:     resourceType,
+			ResourceID:       resourceID,
+			Provider:         reference.Provider,
+			TicketKey:        reference.Key,
+			TicketID:         reference.ID,
+			TicketURL:        reference.URL,
+			SyncedCommentIDs: []string{},
+		}
+		if err := s.repo.CreateLink(link); err != nil {
+			log.Printf("⚠️  Failed to link %s ticket %s to %s %s: %v", reference.Provider, reference.Key, resourceType, resourceID, err)
+		}
+	}
+}
+
+// SyncOpenTickets reads back the tickets of undecided capability requests and open incidents,
+// least recently synced first, and returns how many changed their resource
+func (s *TicketConnectorService) SyncOpenTickets(ctx context.Context) (int, error) {
+	links, err := s.repo.GetOpenLinks(ticketSyncBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	connectors := make(map[uuid.UUID]*domain.TicketConnector)
+	changed := 0
+	for _, link := range links {
+		if ctx.Err() != nil {
+			return changed, ctx.Err()
+		}
+		connector, ok := connectors[link.ConnectorID]
+		if !ok {
+			if connector, err = s.repo.GetConnector(link.ConnectorID); err != nil {
+				log.Printf("⚠️  Failed to load ticket connector %s: %v", link.ConnectorID, err)
+				continue
+			}
+			connectors[link.ConnectorID] = connector
+		}
+		if !connector.IsEnabled {
+			continue
+		}
+		if s.syncLink(ctx, connector, link) {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// HandleWebhook syncs a connector's ticket right away when the ticketing system reports a
+// change. Tickets the connector did not open for AIM are ignored.
+func (s *TicketConnectorService) HandleWebhook(ctx context.Context, connectorID uuid.UUID, secret, ticketKey string) (*domain.TicketLink, error) {
+	connector, err := s.repo.GetConnector(connectorID)
+	if err != nil {
+		if errors.Is(err, domain.ErrTicketConnectorNotFound) {
+			return nil, ErrInvalidTicketWebhookSecret
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashTicketWebhookSecret(secret)), []byte(connector.WebhookSecretHash)) != 1 {
+		return nil, ErrInvalidTicketWebhookSecret
+	}
+	if !connector.IsEnabled {
+		return nil, domain.ErrTicketLinkNotFound
+	}
+
+	link, err := s.repo.GetLinkByTicket(connector.ID, ticketKey)
+	if err != nil {
+		return nil, err
+	}
+	if !link.Closed {
+		s.syncLink(ctx, connector, link)
+	}
+	return link, nil
+}
+
+// syncLink applies the ticket's new comments and status to its resource and records the sync.
+// Returns whether the resource changed. The ticket status is only recorded once it was
+// applied, so failures are retried on the next sync.
+func (s *TicketConnectorService) syncLink(ctx context.Context, connector *domain.TicketConnector, link *domain.TicketLink) bool {
+	changed, err := s.applyTicket(ctx, connector, link)
+	now := s.now()
+	link.LastSyncedAt = &now
+	link.LastSyncError = nil
+	if err != nil {
+		message := err.Error()
+		link.LastSyncError = &message
+		log.Printf("⚠️  Failed to sync %s ticket %s: %v", link.Provider, link.TicketKey, err)
+	}
+	if err := s.repo.UpdateLink(link); err != nil {
+		log.Printf("⚠️  Failed to record sync of %s ticket %s: %v", link.Provider, link.TicketKey, err)
+	}
+	return changed
+}
+
+func (s *TicketConnectorService) applyTicket(ctx context.Context, connector *domain.TicketConnector, link *domain.TicketLink) (bool, error) {
+	if s.capabilityRequests == nil || s.incidents == nil {
+		return false, fmt.Errorf("ticket sync is not available on this server")
+	}
+	system, err := s.system(connector)
+	if err != nil {
+		return false, err
+	}
+	state, err := system.GetTicket(ctx, &domain.TicketReference{
+		Provider: link.Provider,
+		Key:      link.TicketKey,
+		ID:       link.TicketID,
+		URL:      link.TicketURL,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	switch link.ResourceType {
+	case domain.TicketResourceCapabilityRequest:
+		return s.applyCapabilityRequestTicket(ctx, connector, link, state)
+	case domain.TicketResourceIncident:
+		return s.applyIncidentTicket(ctx, connector, link, state)
+	}
+	return false, fmt.Errorf("unknown ticket resource type %q", link.ResourceType)
+}
+
+// applyCapabilityRequestTicket approves or rejects the request once its ticket reaches an
+// approved or rejected status. Capability requests have no comments, so ticket comments stay
+// in the ticketing system.
+func (s *TicketConnectorService) applyCapabilityRequestTicket(ctx context.Context, connector *domain.TicketConnector, link *domain.TicketLink, state *domain.TicketState) (bool, error) {
+	request, err := s.capabilityRequests.GetRequest(ctx, link.ResourceID)
+	if err != nil {
+		return false, err
+	}
+	if request.Status != domain.CapabilityRequestStatusPending {
+		link.Closed = true
+		return false, nil
+	}
+	if strings.EqualFold(state.Status, link.TicketStatus) {
+		return false, nil
+	}
+
+	switch {
+	case containsFold(connector.ApprovedStatuses, state.Status):
+		if _, err := s.capabilityRequests.ApproveRequest(ctx, request.ID, connector.CreatedBy); err != nil {
+			return false, err
+		}
+	case containsFold(connector.RejectedStatuses, state.Status):
+		if err := s.capabilityRequests.RejectRequest(ctx, request.ID, connector.CreatedBy); err != nil {
+			return false, err
+		}
+	default:
+		link.TicketStatus = state.Status
+		return false, nil
+	}
+	link.TicketStatus = state.Status
+
+	// An approval chain may still be collecting approvals
+	if request, err = s.capabilityRequests.GetRequest(ctx, link.ResourceID); err == nil {
+		link.Closed = request.Status != domain.CapabilityRequestStatusPending
+	}
+	return true, nil
+}
+
+// applyIncidentTicket copies the ticket's new comments to the incident and moves the incident
+// to the status mapped to the ticket's status
+func (s *TicketConnectorService) applyIncidentTicket(ctx context.Context, connector *domain.TicketConnector, link *domain.TicketLink, state *domain.TicketState) (bool, error) {
+	changed := false
+	for _, comment := range state.Comments {
+		if slices.Contains(link.SyncedCommentIDs, comment.ID) || strings.TrimSpace(comment.Body) == "" {
+			continue
+		}
+		body := fmt.Sprintf("%s commented on %s: %s", comment.Author, link.TicketKey, strings.TrimSpace(comment.Body))
+		if len(body) > MaxIncidentCommentLength {
+			body = body[:MaxIncidentCommentLength]
+		}
+		if _, err := s.incidents.AddComment(ctx, link.OrganizationID, link.ResourceID, connector.CreatedBy, body); err != nil {
+			return changed, err
+		}
+		link.SyncedCommentIDs = append(link.SyncedCommentIDs, comment.ID)
+		changed = true
+	}
+
+	if !strings.EqualFold(state.Status, link.TicketStatus) {
+		if status, ok := mappedIncidentStatus(connector.IncidentStatuses, state.Status); ok {
+			notes := fmt.Sprintf("%s ticket %s moved to %s", link.Provider, link.TicketKey, state.Status)
+			before, err := s.incidents.GetIncident(ctx, link.OrganizationID, link.ResourceID)
+			if err != nil {
+				return changed, err
+			}
+			if _, err := s.incidents.ChangeStatus(ctx, link.OrganizationID, link.ResourceID, connector.CreatedBy, status, notes); err != nil && !errors.Is(err, ErrIncidentClosed) {
+				return changed, err
+			}
+			changed = changed || before.Status != status
+		}
+		link.TicketStatus = state.Status
+	}
+
+	incident, err := s.incidents.GetIncident(ctx, link.OrganizationID, link.ResourceID)
+	if err != nil {
+		return changed, err
+	}
+	link.Closed = incident.Status.IsClosed()
+	return changed, nil
+}
+
+func mappedIncidentStatus(statuses map[string]domain.IncidentStatus, ticketStatus string) (domain.IncidentStatus, bool) {
+	for mapped, status := range statuses {
+		if strings.EqualFold(mapped, ticketStatus) {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(candidate string) bool { return strings.EqualFold(candidate, value) })
+}
+
+// SecurityRepository wraps a security repository so incidents opened through it get tickets
+func (s *TicketConnectorService) SecurityRepository(securityRepo domain.SecurityRepository) domain.SecurityRepository {
+	return &ticketingSecurityRepository{SecurityRepository: securityRepo, ticketService: s}
+}
+
+type ticketingSecurityRepository struct {
+	domain.SecurityRepository
+	ticketService *TicketConnectorService
+}
+
+func (r *ticketingSecurityRepository) CreateIncident(incident *domain.SecurityIncident) error {
+	if err := r.SecurityRepository.CreateIncident(incident); err != nil {
+		return err
+	}
+	r.ticketService.OpenIncidentTickets(context.Background(), incident)
+	return nil
+}
+
+// CapabilityRequestRepository wraps a capability request repository so requests created through
+// it get tickets
+func (s *TicketConnectorService) CapabilityRequestRepository(requestRepo domain.CapabilityRequestRepository) domain.CapabilityRequestRepository {
+	return &ticketingCapabilityRequestRepository{CapabilityRequestRepository: requestRepo, ticketService: s}
+}
+
+type ticketingCapabilityRequestRepository struct {
+	domain.CapabilityRequestRepository
+	ticketService *TicketConnectorService
+}
+
+func (r *ticketingCapabilityRequestRepository) Create(request *domain.CapabilityRequest) error {
+	if err := r.CapabilityRequestRepository.Create(request); err != nil {
+		return err
+	}
+	r.ticketService.OpenCapabilityRequestTickets(context.Background(), request)
+	return nil
+}
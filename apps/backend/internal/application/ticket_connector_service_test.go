@@ -0,0 +1,199 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/ticketing"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTicketConnectorsOpenTicketsAndSyncThemBack(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	admin := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(admin))
+	agent := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(agent))
+	ctx := context.Background()
+
+	// A fake Jira hands out issue keys and reports the status and comments set by the test
+	var mu sync.Mutex
+	statuses := map[string]string{}
+	comments := map[string][]map[string]interface{}{}
+	created := 0
+	jira := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		username, password, _ := r.BasicAuth()
+		assert.Equal(t, "soc@example.com:secret-token", username+":"+password)
+		if r.Method == http.MethodPost {
+			created++
+			key := fmt.Sprintf("SEC-%d", created)
+			statuses[key] = "To Do"
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"key": key})
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/")
+		json.NewEncoder(w).Encode(map[string]interface{}{"fields": map[string]interface{}{
+			"status":  map[string]string{"name": statuses[key]},
+			"comment": map[string]interface{}{"comments": comments[key]},
+		}})
+	}))
+	defer jira.Close()
+	setTicket := func(key, status string, ticketComments ...map[string]interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		statuses[key] = status
+		comments[key] = ticketComments
+	}
+
+	masterKey := make([]byte, 32)
+	_, err := rand.Read(masterKey)
+	require.NoError(t, err)
+	vault, err := crypto.NewKeyVault(base64.StdEncoding.EncodeToString(masterKey))
+	require.NoError(t, err)
+
+	service := NewTicketConnectorService(repos.TicketConnector, repos.Agent, vault,
+		func(connector *domain.TicketConnector, apiToken string) domain.TicketSystem {
+			return ticketing.ForConnector(connector, apiToken)
+		})
+	requestRepo := service.CapabilityRequestRepository(repos.CapabilityRequest)
+	securityRepo := service.SecurityRepository(repos.Security)
+	capabilityRequests := NewCapabilityRequestService(requestRepo, repos.Capability, repos.Agent, nil)
+	incidents := NewIncidentService(securityRepo, repos.Incident, repos.Alert, repos.VerificationEvent, repos.User, nil, nil)
+	service.UseWorkflows(capabilityRequests, incidents)
+
+	_, err = service.CreateConnector(ctx, &TicketConnectorRequest{
+		Name: "No project", Provider: "jira", BaseURL: jira.URL, Username: "soc@example.com", APIToken: "secret-token",
+		ResourceTypes: []string{domain.TicketResourceIncident},
+	}, org.ID, admin.ID)
+	assert.ErrorIs(t, err, ErrInvalidTicketConnector, "Jira connectors need a project key")
+
+	connector, err := service.CreateConnector(ctx, &TicketConnectorRequest{
+		Name:             "SOC Jira",
+		Provider:         "jira",
+		BaseURL:          jira.URL + "/",
+		Username:         "soc@example.com",
+		APIToken:         "secret-token",
+		Project:          "SEC",
+		ResourceTypes:    []string{domain.TicketResourceIncident, domain.TicketResourceCapabilityRequest},
+		ApprovedStatuses: []string{"Approved"},
+		RejectedStatuses: []string{"Won't Do"},
+		IncidentStatuses: map[string]domain.IncidentStatus{
+			"In Progress": domain.IncidentStatusInvestigating,
+			"Done":        domain.IncidentStatusResolved,
+		},
+	}, org.ID, admin.ID)
+	require.NoError(t, err)
+	secret := connector.WebhookSecret
+	require.NotEmpty(t, secret)
+	stored, err := service.GetConnector(ctx, org.ID, connector.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.WebhookSecret, "the webhook secret is only returned on create")
+	assert.NotContains(t, stored.EncryptedAPIToken, "secret-token")
+
+	// New capability requests and incidents get a ticket each
+	request := &domain.CapabilityRequest{AgentID: agent.ID, CapabilityType: domain.CapabilityFileRead, Reason: "needed", RequestedBy: admin.ID}
+	require.NoError(t, requestRepo.Create(request))
+	incident := &domain.SecurityIncident{
+		ID: uuid.New(), OrganizationID: org.ID, IncidentType: "agent_compromised",
+		Severity: domain.AlertSeverityHigh, Title: "Agent compromised", Status: domain.IncidentStatusOpen,
+	}
+	require.NoError(t, securityRepo.CreateIncident(incident))
+
+	requestTickets, err := service.ListLinks(ctx, org.ID, domain.TicketResourceCapabilityRequest, request.ID)
+	require.NoError(t, err)
+	require.Len(t, requestTickets, 1)
+	assert.Equal(t, "SEC-1", requestTickets[0].TicketKey)
+	assert.Equal(t, jira.URL+"/browse/SEC-1", requestTickets[0].TicketURL)
+	incidentTickets, err := service.ListLinks(ctx, org.ID, domain.TicketResourceIncident, incident.ID)
+	require.NoError(t, err)
+	require.Len(t, incidentTickets, 1)
+	assert.Equal(t, "SEC-2", incidentTickets[0].TicketKey)
+	outsiders, err := service.ListLinks(ctx, uuid.New(), domain.TicketResourceIncident, incident.ID)
+	require.NoError(t, err)
+	assert.Empty(t, outsiders)
+
+	// Approving the ticket approves the request and stops syncing it
+	setTicket("SEC-1", "Approved")
+	changed, err := service.SyncOpenTickets(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+	decided, err := capabilityRequests.GetRequest(ctx, request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.CapabilityRequestStatusApproved, decided.Status)
+	requestTickets, err = service.ListLinks(ctx, org.ID, domain.TicketResourceCapabilityRequest, request.ID)
+	require.NoError(t, err)
+	assert.True(t, requestTickets[0].Closed)
+	assert.Equal(t, "Approved", requestTickets[0].TicketStatus)
+
+	// Webhooks need the connector's secret and sync the ticket right away
+	comment := map[string]interface{}{
+		"id": "10100", "author": map[string]string{"displayName": "Dana"},
+		"body": "Rotating the agent's keys", "created": "2025-11-13T10:00:00.000+0000",
+	}
+	setTicket("SEC-2", "In Progress", comment)
+	_, err = service.HandleWebhook(ctx, connector.ID, "wrong", "SEC-2")
+	assert.ErrorIs(t, err, ErrInvalidTicketWebhookSecret)
+	_, err = service.HandleWebhook(ctx, uuid.New(), secret, "SEC-2")
+	assert.ErrorIs(t, err, ErrInvalidTicketWebhookSecret)
+	_, err = service.HandleWebhook(ctx, connector.ID, secret, "OPS-9")
+	assert.ErrorIs(t, err, domain.ErrTicketLinkNotFound, "tickets AIM did not open are ignored")
+
+	link, err := service.HandleWebhook(ctx, connector.ID, secret, "SEC-2")
+	require.NoError(t, err)
+	assert.False(t, link.Closed)
+	detail, err := incidents.GetIncident(ctx, org.ID, incident.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.IncidentStatusInvestigating, detail.Status)
+
+	// Comments are copied once, however often the ticket is synced
+	_, err = service.HandleWebhook(ctx, connector.ID, secret, "SEC-2")
+	require.NoError(t, err)
+	incidentComments, err := incidents.ListComments(ctx, org.ID, incident.ID)
+	require.NoError(t, err)
+	require.Len(t, incidentComments, 1)
+	assert.Equal(t, "Dana commented on SEC-2: Rotating the agent's keys", incidentComments[0].Body)
+	assert.Equal(t, admin.ID, incidentComments[0].AuthorID)
+
+	// Closing the ticket resolves the incident and stops syncing it
+	setTicket("SEC-2", "Done", comment)
+	changed, err = service.SyncOpenTickets(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+	detail, err = incidents.GetIncident(ctx, org.ID, incident.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.IncidentStatusResolved, detail.Status)
+	open, err := repos.TicketConnector.GetOpenLinks(10)
+	require.NoError(t, err)
+	assert.Empty(t, open)
+
+	// Disabled connectors open no tickets
+	disabled := false
+	_, err = service.UpdateConnector(ctx, org.ID, connector.ID, &TicketConnectorRequest{
+		Name: "SOC Jira", BaseURL: jira.URL, Username: "soc@example.com", Project: "SEC",
+		ResourceTypes: []string{domain.TicketResourceIncident}, IsEnabled: &disabled,
+	})
+	require.NoError(t, err)
+	second := &domain.SecurityIncident{ID: uuid.New(), OrganizationID: org.ID, IncidentType: "policy_violation", Severity: domain.AlertSeverityWarning, Title: "Policy", Status: domain.IncidentStatusOpen}
+	require.NoError(t, securityRepo.CreateIncident(second))
+	incidentTickets, err = service.ListLinks(ctx, org.ID, domain.TicketResourceIncident, second.ID)
+	require.NoError(t, err)
+	assert.Empty(t, incidentTickets)
+}
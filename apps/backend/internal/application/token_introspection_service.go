@@ -0,0 +1,223 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+)
+
+// Token uses reported by introspection in the "token_use" member
+const (
+	TokenUseAccess     = "access_token"
+	TokenUseRefresh    = "refresh_token"
+	TokenUseSDKRefresh = "sdk_refresh_token"
+	TokenUseAPIKey     = "api_key"
+)
+
+// sdkTokenIssuer is the issuer of the refresh tokens embedded in downloaded SDKs
+const sdkTokenIssuer = "agent-identity-management-sdk"
+
+// TokenIntrospection is an RFC 7662 introspection response. Inactive tokens carry
+// nothing but Active=false, so relying parties learn nothing about them.
+type TokenIntrospection struct {
+	Active         bool   `json:"active"`
+	Scope          string `json:"scope,omitempty"`
+	ClientID       string `json:"client_id,omitempty"`
+	Username       string `json:"username,omitempty"`
+	TokenType      string `json:"token_type,omitempty"`
+	Exp            int64  `json:"exp,omitempty"`
+	Iat            int64  `json:"iat,omitempty"`
+	Nbf            int64  `json:"nbf,omitempty"`
+	Sub            string `json:"sub,omitempty"`
+	Iss            string `json:"iss,omitempty"`
+	Jti            string `json:"jti,omitempty"`
+	TokenUse       string `json:"token_use,omitempty"`
+	OrganizationID string `json:"organization_id,omitempty"`
+	Role           string `json:"role,omitempty"`
+	AgentID        string `json:"agent_id,omitempty"`
+}
+
+// inactiveToken is the response for unknown, expired, revoked and foreign tokens
+var inactiveToken = &TokenIntrospection{Active: false}
+
+// AuthorizationServerMetadata is the RFC 8414 metadata document
+type AuthorizationServerMetadata struct {
+	Issuer                                    string   `json:"issuer"`
+	TokenEndpoint                             string   `json:"token_endpoint"`
+	TokenEndpointAuthMethodsSupported         []string `json:"token_endpoint_auth_methods_supported"`
+	IntrospectionEndpoint                     string   `json:"introspection_endpoint"`
+	IntrospectionEndpointAuthMethodsSupported []string `json:"introspection_endpoint_auth_methods_supported"`
+	GrantTypesSupported                       []string `json:"grant_types_supported"`
+	ResponseTypesSupported                    []string `json:"response_types_supported"`
+	ScopesSupported                           []string `json:"scopes_supported"`
+}
+
+// TokenIntrospectionService lets relying parties validate session tokens, SDK tokens and
+// agent API keys issued by this server, including their revocation status
+type TokenIntrospectionService struct {
+	jwtService   *auth.JWTService
+	sdkTokenRepo domain.SDKTokenRepository
+	apiKeyRepo   domain.APIKeyRepository
+	agentRepo    domain.AgentRepository
+	userRepo     domain.UserRepository
+	issuerURL    string
+}
+
+// NewTokenIntrospectionService creates a new token introspection service.
+// issuerURL is the public base URL the metadata document advertises.
+func NewTokenIntrospectionService(
+	jwtService *auth.JWTService,
+	sdkTokenRepo domain.SDKTokenRepository,
+	apiKeyRepo domain.APIKeyRepository,
+	agentRepo domain.AgentRepository,
+	userRepo domain.UserRepository,
+	issuerURL string,
+) *TokenIntrospectionService {
+	return &TokenIntrospectionService{
+		jwtService:   jwtService,
+		sdkTokenRepo: sdkTokenRepo,
+		apiKeyRepo:   apiKeyRepo,
+		agentRepo:    agentRepo,
+		userRepo:     userRepo,
+		issuerURL:    strings.TrimRight(issuerURL, "/"),
+	}
+}
+
+// Metadata returns the authorization server metadata document
+func (s *TokenIntrospectionService) Metadata() *AuthorizationServerMetadata {
+	return &AuthorizationServerMetadata{
+		Issuer:                            s.issuerURL,
+		TokenEndpoint:                     s.issuerURL + "/api/v1/auth/refresh",
+		TokenEndpointAuthMethodsSupported: []string{"none"},
+		IntrospectionEndpoint:             s.issuerURL + "/oauth/introspect",
+		IntrospectionEndpointAuthMethodsSupported: []string{"bearer"},
+		GrantTypesSupported:                       []string{"refresh_token"},
+		ResponseTypesSupported:                    []string{},
+		ScopesSupported:                           domain.APIKeyScopes,
+	}
+}
+
+// Introspect reports whether token is active for a relying party of the caller's
+// organization. Tokens of other organizations are reported inactive. The token type
+// hint is accepted for RFC 7662 compatibility; the token's shape decides how it is checked.
+func (s *TokenIntrospectionService) Introspect(ctx context.Context, callerOrgID uuid.UUID, token, tokenTypeHint string) (*TokenIntrospection, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return inactiveToken, nil
+	}
+
+	if strings.HasPrefix(token, "aim_") {
+		return s.introspectAPIKey(callerOrgID, token)
+	}
+	return s.introspectJWT(callerOrgID, token)
+}
+
+// introspectAPIKey checks an agent API key. Disabled keys, expired keys and keys of
+// suspended or revoked agents are inactive.
+func (s *TokenIntrospectionService) introspectAPIKey(callerOrgID uuid.UUID, token string) (*TokenIntrospection, error) {
+	hash := sha256.Sum256([]byte(token))
+	key, err := s.apiKeyRepo.GetByHash(base64.StdEncoding.EncodeToString(hash[:]))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || !key.IsActive || key.OrganizationID != callerOrgID {
+		return inactiveToken, nil
+	}
+	if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+		return inactiveToken, nil
+	}
+
+	agent, err := s.agentRepo.GetByID(key.AgentID)
+	if err != nil || agent == nil {
+		return inactiveToken, nil
+	}
+	if agent.Status == domain.AgentStatusSuspended || agent.Status == domain.AgentStatusRevoked {
+		return inactiveToken, nil
+	}
+
+	result := &TokenIntrospection{
+		Active:         true,
+		Scope:          strings.Join(key.Scopes, " "),
+		ClientID:       agent.ID.String(),
+		Username:       agent.Name,
+		TokenType:      "Bearer",
+		Iat:            key.CreatedAt.Unix(),
+		Sub:            agent.ID.String(),
+		Jti:            key.ID.String(),
+		TokenUse:       TokenUseAPIKey,
+		OrganizationID: key.OrganizationID.String(),
+		AgentID:        agent.ID.String(),
+	}
+	if len(key.Scopes) == 0 {
+		result.Scope = strings.Join(domain.APIKeyLegacyScopes, " ")
+	}
+	if key.ExpiresAt != nil {
+		result.Exp = key.ExpiresAt.Unix()
+	}
+	return result, nil
+}
+
+// introspectJWT checks a session or SDK token. Tokens with a bad signature, expired
+// tokens, revoked SDK tokens and tokens of users who are no longer active are inactive.
+func (s *TokenIntrospectionService) introspectJWT(callerOrgID uuid.UUID, token string) (*TokenIntrospection, error) {
+	claims, err := s.jwtService.ValidateToken(token)
+	if err != nil {
+		return inactiveToken, nil
+	}
+	if claims.OrganizationID != callerOrgID.String() {
+		return inactiveToken, nil
+	}
+
+	// SDK tokens are tracked by hash; a tracked token that is revoked or expired is inactive
+	hasher := sha256.New()
+	hasher.Write([]byte(token))
+	if tracked, err := s.sdkTokenRepo.GetByTokenHash(hex.EncodeToString(hasher.Sum(nil))); err == nil && tracked != nil && !tracked.IsActive() {
+		return inactiveToken, nil
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return inactiveToken, nil
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || user == nil || user.Status != domain.UserStatusActive {
+		return inactiveToken, nil
+	}
+
+	result := &TokenIntrospection{
+		Active:         true,
+		ClientID:       claims.Issuer,
+		Username:       user.Email,
+		TokenType:      "Bearer",
+		Sub:            claims.Subject,
+		Iss:            claims.Issuer,
+		Jti:            claims.ID,
+		OrganizationID: claims.OrganizationID,
+		Role:           string(user.Role),
+	}
+	switch {
+	case claims.Issuer == sdkTokenIssuer:
+		result.TokenUse = TokenUseSDKRefresh
+	case claims.Email == "":
+		result.TokenUse = TokenUseRefresh
+	default:
+		result.TokenUse = TokenUseAccess
+	}
+	if claims.ExpiresAt != nil {
+		result.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		result.Iat = claims.IssuedAt.Unix()
+	}
+	if claims.NotBefore != nil {
+		result.Nbf = claims.NotBefore.Unix()
+	}
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenIntrospectionReportsRevocation(t *testing.T) {
+	t.Setenv("JWT_SECRET", "introspection-test-secret")
+	jwtService := auth.NewJWTService()
+
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	user := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(user))
+	agent := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(agent))
+
+	service := NewTokenIntrospectionService(jwtService, repos.SDKToken, repos.APIKey, repos.Agent, repos.User, "https://aim.example.com/")
+	ctx := context.Background()
+
+	// Session tokens
+	accessToken, err := jwtService.GenerateAccessToken(user.ID.String(), org.ID.String(), user.Email, string(user.Role))
+	require.NoError(t, err)
+	result, err := service.Introspect(ctx, org.ID, accessToken, "access_token")
+	require.NoError(t, err)
+	assert.True(t, result.Active)
+	assert.Equal(t, TokenUseAccess, result.TokenUse)
+	assert.Equal(t, user.ID.String(), result.Sub)
+	assert.NotZero(t, result.Exp)
+
+	result, err = service.Introspect(ctx, uuid.New(), accessToken, "")
+	require.NoError(t, err)
+	assert.False(t, result.Active, "tokens of other organizations are inactive")
+
+	result, err = service.Introspect(ctx, org.ID, accessToken+"x", "")
+	require.NoError(t, err)
+	assert.False(t, result.Active)
+
+	// Tracked SDK tokens report revocation
+	sdkToken, err := jwtService.GenerateSDKRefreshToken(user.ID.String(), org.ID.String(), user.Email, string(user.Role))
+	require.NoError(t, err)
+	tokenHash := sha256.Sum256([]byte(sdkToken))
+	tracked := &domain.SDKToken{
+		UserID:         user.ID,
+		OrganizationID: org.ID,
+		TokenHash:      hex.EncodeToString(tokenHash[:]),
+		TokenID:        uuid.NewString(),
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}
+	require.NoError(t, repos.SDKToken.Create(tracked))
+
+	result, err = service.Introspect(ctx, org.ID, sdkToken, "refresh_token")
+	require.NoError(t, err)
+	assert.True(t, result.Active)
+	assert.Equal(t, TokenUseSDKRefresh, result.TokenUse)
+
+	require.NoError(t, repos.SDKToken.Revoke(tracked.ID, "lost laptop"))
+	result, err = service.Introspect(ctx, org.ID, sdkToken, "refresh_token")
+	require.NoError(t, err)
+	assert.Equal(t, &TokenIntrospection{Active: false}, result)
+
+	// Agent API keys
+	apiKeyService := NewAPIKeyService(repos.APIKey, repos.Agent)
+	fullKey, key, err := apiKeyService.GenerateAPIKey(ctx, agent.ID, org.ID, user.ID, "relying-party", 30, []string{domain.APIKeyScopeVerifyRead})
+	require.NoError(t, err)
+
+	result, err = service.Introspect(ctx, org.ID, fullKey, "")
+	require.NoError(t, err)
+	assert.True(t, result.Active)
+	assert.Equal(t, TokenUseAPIKey, result.TokenUse)
+	assert.Equal(t, domain.APIKeyScopeVerifyRead, result.Scope)
+	assert.Equal(t, agent.ID.String(), result.Sub)
+
+	require.NoError(t, apiKeyService.RevokeAPIKey(ctx, key.ID, org.ID))
+	result, err = service.Introspect(ctx, org.ID, fullKey, "")
+	require.NoError(t, err)
+	assert.False(t, result.Active)
+
+	metadata := service.Metadata()
+	assert.Equal(t, "https://aim.example.com", metadata.Issuer)
+	assert.Equal(t, "https://aim.example.com/oauth/introspect", metadata.IntrospectionEndpoint)
+}
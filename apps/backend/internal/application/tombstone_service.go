@@ -0,0 +1,179 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ErrTombstoneNotFound is returned when no tombstone exists for an entity
+var ErrTombstoneNotFound = errors.New("tombstone not found")
+
+const (
+	defaultTombstoneSearchLimit = 50
+	maxTombstoneSearchLimit     = 200
+)
+
+// TombstoneService keeps an immutable, redacted record of deleted agents, MCP servers and
+// API keys so references in old events and incidents keep resolving
+type TombstoneService struct {
+	tombstoneRepo domain.TombstoneRepository
+	agentRepo     domain.AgentRepository
+	mcpRepo       domain.MCPServerRepository
+	apiKeyRepo    domain.APIKeyRepository
+	userRepo      domain.UserRepository
+}
+
+// NewTombstoneService creates a new tombstone service
+func NewTombstoneService(
+	tombstoneRepo domain.TombstoneRepository,
+	agentRepo domain.AgentRepository,
+	mcpRepo domain.MCPServerRepository,
+	apiKeyRepo domain.APIKeyRepository,
+	userRepo domain.UserRepository,
+) *TombstoneService {
+	return &TombstoneService{
+		tombstoneRepo: tombstoneRepo,
+		agentRepo:     agentRepo,
+		mcpRepo:       mcpRepo,
+		apiKeyRepo:    apiKeyRepo,
+		userRepo:      userRepo,
+	}
+}
+
+// DeleteWithTombstone captures the entity's tombstone, runs deleteFn and, once the entity is
+// gone, stores the tombstone. Linked records are counted before deletion because most of
+// them cascade. Failing to record the tombstone is logged and does not undo the deletion.
+func (s *TombstoneService) DeleteWithTombstone(
+	ctx context.Context,
+	entityType domain.TombstoneEntityType,
+	entityID uuid.UUID,
+	deletedBy uuid.UUID,
+	deleteFn func() error,
+) error {
+	tombstone, err := s.capture(entityType, entityID, deletedBy)
+	if err != nil {
+		log.Printf("⚠️  Failed to capture tombstone for %s %s: %v", entityType, entityID, err)
+	}
+
+	if err := deleteFn(); err != nil {
+		return err
+	}
+
+	if tombstone == nil {
+		return nil
+	}
+	tombstone.DeletedAt = time.Now().UTC()
+	if err := s.tombstoneRepo.Create(tombstone); err != nil {
+		log.Printf("⚠️  Failed to store tombstone for %s %s: %v", entityType, entityID, err)
+	}
+	return nil
+}
+
+// capture builds the tombstone of an entity that is about to be deleted
+func (s *TombstoneService) capture(entityType domain.TombstoneEntityType, entityID, deletedBy uuid.UUID) (*domain.Tombstone, error) {
+	tombstone := &domain.Tombstone{
+		EntityType: entityType,
+		EntityID:   entityID,
+		DeletedBy:  &deletedBy,
+		Details:    make(map[string]string),
+	}
+
+	switch entityType {
+	case domain.TombstoneEntityAgent:
+		agent, err := s.agentRepo.GetByID(entityID)
+		if err != nil {
+			return nil, err
+		}
+		tombstone.OrganizationID = agent.OrganizationID
+		tombstone.Name = agent.Name
+		tombstone.Details["displayName"] = agent.DisplayName
+		tombstone.Details["agentType"] = string(agent.AgentType)
+		tombstone.Details["status"] = string(agent.Status)
+	case domain.TombstoneEntityMCPServer:
+		server, err := s.mcpRepo.GetByID(entityID)
+		if err != nil {
+			return nil, err
+		}
+		tombstone.OrganizationID = server.OrganizationID
+		tombstone.Name = server.Name
+		tombstone.Details["url"] = server.URL
+		tombstone.Details["status"] = string(server.Status)
+	case domain.TombstoneEntityAPIKey:
+		key, err := s.apiKeyRepo.GetByID(entityID)
+		if err != nil {
+			return nil, err
+		}
+		tombstone.OrganizationID = key.OrganizationID
+		tombstone.Name = key.Name
+		tombstone.Details["keyPrefix"] = key.Prefix
+		tombstone.Details["agentId"] = key.AgentID.String()
+		if agent, err := s.agentRepo.GetByID(key.AgentID); err == nil {
+			tombstone.Details["agentName"] = agent.Name
+		}
+	default:
+		return nil, fmt.Errorf("unsupported tombstone entity type: %s", entityType)
+	}
+
+	if s.userRepo != nil {
+		if user, err := s.userRepo.GetByID(deletedBy); err == nil && user != nil {
+			tombstone.DeletedByEmail = user.Email
+		}
+	}
+
+	linked, err := s.tombstoneRepo.CountLinked(entityType, entityID)
+	if err != nil {
+		log.Printf("⚠️  Failed to count records linked to %s %s: %v", entityType, entityID, err)
+		linked = map[string]int{}
+	}
+	tombstone.LinkedCounts = linked
+
+	return tombstone, nil
+}
+
+// GetTombstone resolves a deleted entity's ID to its tombstone
+func (s *TombstoneService) GetTombstone(ctx context.Context, orgID, entityID uuid.UUID) (*domain.Tombstone, error) {
+	tombstone, err := s.tombstoneRepo.GetByEntityID(orgID, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if tombstone == nil {
+		return nil, ErrTombstoneNotFound
+	}
+	return tombstone, nil
+}
+
+// SearchTombstones lists the organization's tombstones matching the filter, newest first
+func (s *TombstoneService) SearchTombstones(ctx context.Context, filter *domain.TombstoneFilter) ([]*domain.Tombstone, int, error) {
+	switch filter.EntityType {
+	case "", domain.TombstoneEntityAgent, domain.TombstoneEntityMCPServer, domain.TombstoneEntityAPIKey:
+	default:
+		return nil, 0, fmt.Errorf("unsupported entity type: %s", filter.EntityType)
+	}
+
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Limit <= 0 {
+		filter.Limit = defaultTombstoneSearchLimit
+	}
+	if filter.Limit > maxTombstoneSearchLimit {
+		filter.Limit = maxTombstoneSearchLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	tombstones, total, err := s.tombstoneRepo.Search(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if tombstones == nil {
+		tombstones = []*domain.Tombstone{}
+	}
+	return tombstones, total, nil
+}
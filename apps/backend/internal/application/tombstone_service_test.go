@@ -0,0 +1,73 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeletedEntitiesLeaveSearchableTombstones(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	admin := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(admin))
+
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.Name = "billing-agent" })
+	require.NoError(t, repos.Agent.Create(agent))
+	require.NoError(t, repos.Capability.CreateCapability(testsupport.NewAgentCapability(agent, domain.CapabilityFileRead)))
+	require.NoError(t, repos.VerificationEvent.Create(testsupport.NewVerificationEvent(agent)))
+	key := &domain.APIKey{AgentID: agent.ID, OrganizationID: org.ID, Name: "ci", KeyHash: "hash", Prefix: "aim_abcd"}
+	require.NoError(t, repos.APIKey.Create(key))
+
+	service := NewTombstoneService(repos.Tombstone, repos.Agent, repos.MCPServer, repos.APIKey, repos.User)
+	ctx := context.Background()
+
+	require.NoError(t, service.DeleteWithTombstone(ctx, domain.TombstoneEntityAPIKey, key.ID, admin.ID, func() error {
+		return repos.APIKey.Delete(key.ID)
+	}))
+	require.NoError(t, service.DeleteWithTombstone(ctx, domain.TombstoneEntityAgent, agent.ID, admin.ID, func() error {
+		return repos.Agent.Delete(agent.ID)
+	}))
+
+	tombstone, err := service.GetTombstone(ctx, org.ID, agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "billing-agent", tombstone.Name)
+	assert.Equal(t, admin.Email, tombstone.DeletedByEmail)
+	assert.Equal(t, 1, tombstone.LinkedCounts["capabilities"])
+	assert.Equal(t, 1, tombstone.LinkedCounts["verificationEvents"])
+	assert.Equal(t, 0, tombstone.LinkedCounts["apiKeys"], "counted after the key was already deleted")
+
+	keyTombstone, err := service.GetTombstone(ctx, org.ID, key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "billing-agent", keyTombstone.Details["agentName"])
+	assert.NotContains(t, keyTombstone.Details, "keyHash")
+
+	// A failed deletion leaves no tombstone
+	server := testsupport.NewMCPServer(org.ID)
+	require.NoError(t, repos.MCPServer.Create(server))
+	err = service.DeleteWithTombstone(ctx, domain.TombstoneEntityMCPServer, server.ID, admin.ID, func() error {
+		return assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+	_, err = service.GetTombstone(ctx, org.ID, server.ID)
+	assert.ErrorIs(t, err, ErrTombstoneNotFound)
+
+	results, total, err := service.SearchTombstones(ctx, &domain.TombstoneFilter{OrganizationID: org.ID, Query: "BILLING"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, agent.ID, results[0].EntityID)
+
+	results, total, err = service.SearchTombstones(ctx, &domain.TombstoneFilter{OrganizationID: org.ID, EntityType: domain.TombstoneEntityAPIKey})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, key.ID, results[0].EntityID)
+
+	_, total, err = service.SearchTombstones(ctx, &domain.TombstoneFilter{OrganizationID: uuid.New()})
+	require.NoError(t, err)
+	assert.Zero(t, total, "tombstones are scoped to the organization")
+}
@@ -0,0 +1,17 @@
+package application
+
+import (
+	"context"
+
+	"github.com/opena2a/identity/backend/internal/infrastructure/tracing"
+)
+
+// traceRepository runs a repository call in a client span of the context's trace, e.g.
+// "AlertRepository.Create", recording the error it returns
+func traceRepository(ctx context.Context, repository, method string, call func() error) error {
+	_, span := tracing.StartRepository(ctx, repository, method)
+	defer span.End()
+	err := call()
+	span.RecordError(err)
+	return err
+}
@@ -0,0 +1,461 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// trustBoundaryPollInterval is how often the worker evaluates agents against their organization's boundaries
+	trustBoundaryPollInterval = 5 * time.Minute
+	// DefaultVerificationValidity is how long an auto-approved action verification stays valid
+	DefaultVerificationValidity = 24 * time.Hour
+	// RelaxedVerificationValidity replaces the default for agents whose verification was relaxed
+	RelaxedVerificationValidity = 7 * 24 * time.Hour
+)
+
+// ErrInvalidTrustBoundaryPolicy is returned when a trust boundary policy update is rejected
+var ErrInvalidTrustBoundaryPolicy = errors.New("invalid trust boundary policy")
+
+// TrustBoundaryService turns trust scores into automation: agents that drop below the
+// organization's floor are suspended and get an incident, agents that stay above the
+// ceiling have their API keys extended and re-verify less often. Every transition is
+// audit-logged.
+type TrustBoundaryService struct {
+	boundaryRepo domain.TrustBoundaryRepository
+	agentRepo    domain.AgentRepository
+	apiKeyRepo   domain.APIKeyRepository
+	securityRepo domain.SecurityRepository
+	auditService *AuditService
+}
+
+// NewTrustBoundaryService creates a new trust boundary service.
+// Optional dependencies may be nil; the actions that need them are reported as skipped.
+func NewTrustBoundaryService(
+	boundaryRepo domain.TrustBoundaryRepository,
+	agentRepo domain.AgentRepository,
+	apiKeyRepo domain.APIKeyRepository,
+	securityRepo domain.SecurityRepository,
+	auditService *AuditService,
+) *TrustBoundaryService {
+	return &TrustBoundaryService{
+		boundaryRepo: boundaryRepo,
+		agentRepo:    agentRepo,
+		apiKeyRepo:   apiKeyRepo,
+		securityRepo: securityRepo,
+		auditService: auditService,
+	}
+}
+
+// TrustBoundaryPolicyRequest represents the request to update an organization's trust boundaries
+type TrustBoundaryPolicyRequest struct {
+	Enabled                  bool                 `json:"enabled"`
+	FloorScore               float64              `json:"floorScore"`
+	FloorSuspend             bool                 `json:"floorSuspend"`
+	FloorOpenIncident        bool                 `json:"floorOpenIncident"`
+	IncidentSeverity         domain.AlertSeverity `json:"incidentSeverity"`
+	CeilingScore             float64              `json:"ceilingScore"`
+	CeilingSustainedDays     int                  `json:"ceilingSustainedDays"`
+	CeilingExtendKeyExpiry   bool                 `json:"ceilingExtendKeyExpiry"`
+	KeyExtensionDays         int                  `json:"keyExtensionDays"`
+	CeilingRelaxVerification bool                 `json:"ceilingRelaxVerification"`
+}
+
+// GetPolicy returns the organization's trust boundaries, or the (disabled) defaults if none are configured
+func (s *TrustBoundaryService) GetPolicy(ctx context.Context, orgID uuid.UUID) (*domain.TrustBoundaryPolicy, error) {
+	policy, err := s.boundaryRepo.GetPolicy(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return domain.DefaultTrustBoundaryPolicy(orgID), nil
+	}
+	return policy, nil
+}
+
+// UpdatePolicy creates or replaces the organization's trust boundaries
+func (s *TrustBoundaryService) UpdatePolicy(ctx context.Context, req *TrustBoundaryPolicyRequest, orgID, userID uuid.UUID) (*domain.TrustBoundaryPolicy, error) {
+	if req.IncidentSeverity == "" {
+		req.IncidentSeverity = domain.AlertSeverityHigh
+	}
+	switch req.IncidentSeverity {
+	case domain.AlertSeverityInfo, domain.AlertSeverityWarning, domain.AlertSeverityHigh, domain.AlertSeverityCritical:
+	default:
+		return nil, fmt.Errorf("%w: unsupported incident severity %s", ErrInvalidTrustBoundaryPolicy, req.IncidentSeverity)
+	}
+
+	switch {
+	case req.FloorScore < 0 || req.FloorScore > 1 || req.CeilingScore < 0 || req.CeilingScore > 1:
+		return nil, fmt.Errorf("%w: floorScore and ceilingScore must be between 0 and 1", ErrInvalidTrustBoundaryPolicy)
+	case req.FloorScore >= req.CeilingScore:
+		return nil, fmt.Errorf("%w: floorScore must be lower than ceilingScore", ErrInvalidTrustBoundaryPolicy)
+	case req.CeilingSustainedDays < 1 || req.CeilingSustainedDays > 365:
+		return nil, fmt.Errorf("%w: ceilingSustainedDays must be between 1 and 365", ErrInvalidTrustBoundaryPolicy)
+	case req.KeyExtensionDays < 1 || req.KeyExtensionDays > 365:
+		return nil, fmt.Errorf("%w: keyExtensionDays must be between 1 and 365", ErrInvalidTrustBoundaryPolicy)
+	}
+
+	policy := &domain.TrustBoundaryPolicy{
+		OrganizationID:           orgID,
+		Enabled:                  req.Enabled,
+		FloorScore:               req.FloorScore,
+		FloorSuspend:             req.FloorSuspend,
+		FloorOpenIncident:        req.FloorOpenIncident,
+		IncidentSeverity:         req.IncidentSeverity,
+		CeilingScore:             req.CeilingScore,
+		CeilingSustainedDays:     req.CeilingSustainedDays,
+		CeilingExtendKeyExpiry:   req.CeilingExtendKeyExpiry,
+		KeyExtensionDays:         req.KeyExtensionDays,
+		CeilingRelaxVerification: req.CeilingRelaxVerification,
+		UpdatedBy:                &userID,
+	}
+
+	if err := s.boundaryRepo.UpsertPolicy(policy); err != nil {
+		return nil, fmt.Errorf("failed to save trust boundary policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// VerificationValidity returns how long an auto-approved verification of the agent stays valid
+func (s *TrustBoundaryService) VerificationValidity(ctx context.Context, agent *domain.Agent) time.Duration {
+	if s.IsVerificationRelaxed(ctx, agent) {
+		return RelaxedVerificationValidity
+	}
+	return DefaultVerificationValidity
+}
+
+// IsVerificationRelaxed reports whether the agent earned relaxed verification by staying
+// above its organization's ceiling. Relaxation ends as soon as the policy stops allowing it.
+func (s *TrustBoundaryService) IsVerificationRelaxed(ctx context.Context, agent *domain.Agent) bool {
+	state, err := s.boundaryRepo.GetState(agent.ID)
+	if err != nil || state == nil || !state.VerificationRelaxed {
+		return false
+	}
+
+	policy, err := s.boundaryRepo.GetPolicy(agent.OrganizationID)
+	if err != nil || policy == nil {
+		return false
+	}
+	return policy.Enabled && policy.CeilingRelaxVerification && agent.TrustScore >= policy.CeilingScore
+}
+
+// Start evaluates every organization with enabled boundaries until ctx is cancelled
+func (s *TrustBoundaryService) Start(ctx context.Context) {
+	ticker := time.NewTicker(trustBoundaryPollInterval)
+	defer ticker.Stop()
+
+	log.Println("✅ Trust boundary worker started")
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Trust boundary worker stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.EvaluateAll(ctx); err != nil {
+				log.Printf("⚠️  Trust boundary evaluation failed: %v", err)
+			}
+		}
+	}
+}
+
+// EvaluateAll evaluates the agents of every organization with enabled boundaries.
+// Returns the number of transitions found.
+func (s *TrustBoundaryService) EvaluateAll(ctx context.Context) (int, error) {
+	policies, err := s.boundaryRepo.GetEnabledPolicies()
+	if err != nil {
+		return 0, err
+	}
+
+	transitions := 0
+	for _, policy := range policies {
+		if ctx.Err() != nil {
+			return transitions, ctx.Err()
+		}
+
+		events, err := s.evaluateOrganization(ctx, policy, time.Now().UTC())
+		if err != nil {
+			log.Printf("⚠️  Failed to evaluate trust boundaries for org %s: %v", policy.OrganizationID, err)
+			continue
+		}
+		transitions += len(events)
+	}
+
+	return transitions, nil
+}
+
+// EvaluateOrganization evaluates every agent of the organization now and returns the
+// transitions found. Nothing happens while the organization's policy is disabled.
+func (s *TrustBoundaryService) EvaluateOrganization(ctx context.Context, orgID uuid.UUID) ([]*domain.TrustBoundaryEvent, error) {
+	policy, err := s.GetPolicy(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.Enabled {
+		return []*domain.TrustBoundaryEvent{}, nil
+	}
+	return s.evaluateOrganization(ctx, policy, time.Now().UTC())
+}
+
+func (s *TrustBoundaryService) evaluateOrganization(ctx context.Context, policy *domain.TrustBoundaryPolicy, now time.Time) ([]*domain.TrustBoundaryEvent, error) {
+	agents, err := s.agentRepo.GetByOrganization(policy.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agents: %w", err)
+	}
+
+	states, err := s.boundaryRepo.GetStatesByOrganization(policy.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trust boundary states: %w", err)
+	}
+	statesByAgent := make(map[uuid.UUID]*domain.AgentTrustBoundaryState, len(states))
+	for _, state := range states {
+		statesByAgent[state.AgentID] = state
+	}
+
+	events := make([]*domain.TrustBoundaryEvent, 0)
+	for _, agent := range agents {
+		// Compromised and revoked agents are handled by the compromise response, not by scores
+		if agent.IsCompromised || agent.Status == domain.AgentStatusRevoked {
+			continue
+		}
+
+		state := statesByAgent[agent.ID]
+		if state == nil {
+			state = &domain.AgentTrustBoundaryState{AgentID: agent.ID, OrganizationID: agent.OrganizationID}
+		}
+
+		agentEvents := s.evaluateAgent(ctx, policy, agent, state, now)
+		if err := s.boundaryRepo.UpsertState(state); err != nil {
+			log.Printf("⚠️  Failed to store trust boundary state for agent %s: %v", agent.ID, err)
+		}
+		events = append(events, agentEvents...)
+	}
+
+	return events, nil
+}
+
+// evaluateAgent compares the agent's score with the boundaries, runs the actions of every
+// transition and updates state in place
+func (s *TrustBoundaryService) evaluateAgent(
+	ctx context.Context,
+	policy *domain.TrustBoundaryPolicy,
+	agent *domain.Agent,
+	state *domain.AgentTrustBoundaryState,
+	now time.Time,
+) []*domain.TrustBoundaryEvent {
+	var events []*domain.TrustBoundaryEvent
+	score := agent.TrustScore
+	state.LastScore = score
+
+	switch {
+	case score < policy.FloorScore && state.BelowFloorSince == nil:
+		since := now
+		state.BelowFloorSince = &since
+		events = append(events, s.crossFloor(policy, agent, now))
+	case score >= policy.FloorScore && state.BelowFloorSince != nil:
+		state.BelowFloorSince = nil
+		events = append(events, newTrustBoundaryEvent(agent, domain.TrustBoundaryRecoveredFloor, policy.FloorScore, now))
+	}
+
+	sustained := time.Duration(policy.CeilingSustainedDays) * 24 * time.Hour
+	switch {
+	case score >= policy.CeilingScore:
+		if state.AboveCeilingSince == nil {
+			since := now
+			state.AboveCeilingSince = &since
+		}
+		// The ceiling actions repeat once per sustained period so extended keys never lapse
+		due := now.Sub(*state.AboveCeilingSince) >= sustained &&
+			(state.CeilingActionsAt == nil || now.Sub(*state.CeilingActionsAt) >= sustained)
+		if due {
+			ranAt := now
+			state.CeilingActionsAt = &ranAt
+			events = append(events, s.sustainCeiling(policy, agent, state, now))
+		}
+	case state.AboveCeilingSince != nil:
+		state.AboveCeilingSince = nil
+		state.CeilingActionsAt = nil
+		event := newTrustBoundaryEvent(agent, domain.TrustBoundaryLeftCeiling, policy.CeilingScore, now)
+		if state.VerificationRelaxed {
+			state.VerificationRelaxed = false
+			event.Actions = append(event.Actions, domain.TrustBoundaryAction{
+				Action: domain.TrustBoundaryActionRestoreVerification,
+				Status: domain.CompromiseActionCompleted,
+				Count:  1,
+				Detail: fmt.Sprintf("Approved verifications stay valid for %s again", DefaultVerificationValidity),
+			})
+		}
+		events = append(events, event)
+	}
+
+	for _, event := range events {
+		s.logTransition(ctx, policy, agent, event)
+	}
+	return events
+}
+
+// crossFloor suspends the agent and opens an incident, as enabled by the policy
+func (s *TrustBoundaryService) crossFloor(policy *domain.TrustBoundaryPolicy, agent *domain.Agent, now time.Time) *domain.TrustBoundaryEvent {
+	event := newTrustBoundaryEvent(agent, domain.TrustBoundaryBelowFloor, policy.FloorScore, now)
+
+	event.Actions = append(event.Actions,
+		runTrustBoundaryAction(domain.TrustBoundaryActionSuspendAgent, policy.FloorSuspend, true, func() (int, string, error) {
+			if agent.Status == domain.AgentStatusSuspended {
+				return 0, "Agent was already suspended", nil
+			}
+			agent.Status = domain.AgentStatusSuspended
+			if err := s.agentRepo.Update(agent); err != nil {
+				return 0, "", err
+			}
+			return 1, "Agent suspended", nil
+		}),
+		runTrustBoundaryAction(domain.TrustBoundaryActionOpenIncident, policy.FloorOpenIncident, s.securityRepo != nil, func() (int, string, error) {
+			incident := &domain.SecurityIncident{
+				ID:             uuid.New(),
+				OrganizationID: agent.OrganizationID,
+				IncidentType:   "trust_score_floor",
+				Status:         domain.IncidentStatusOpen,
+				Severity:       policy.IncidentSeverity,
+				Title:          fmt.Sprintf("Trust score below floor: %s", agent.Name),
+				Description: fmt.Sprintf("Trust score of agent %s dropped to %.2f, below the organization's floor of %.2f",
+					agent.Name, agent.TrustScore, policy.FloorScore),
+				AffectedResources: []string{"agent:" + agent.ID.String()},
+			}
+			if err := s.securityRepo.CreateIncident(incident); err != nil {
+				return 0, "", err
+			}
+			event.IncidentID = &incident.ID
+			return 1, fmt.Sprintf("Opened incident %s", incident.ID), nil
+		}),
+	)
+
+	return event
+}
+
+// sustainCeiling extends the agent's expiring API keys and relaxes its verification, as enabled by the policy
+func (s *TrustBoundaryService) sustainCeiling(
+	policy *domain.TrustBoundaryPolicy,
+	agent *domain.Agent,
+	state *domain.AgentTrustBoundaryState,
+	now time.Time,
+) *domain.TrustBoundaryEvent {
+	event := newTrustBoundaryEvent(agent, domain.TrustBoundarySustainedCeiling, policy.CeilingScore, now)
+
+	event.Actions = append(event.Actions,
+		runTrustBoundaryAction(domain.TrustBoundaryActionExtendKeyExpiry, policy.CeilingExtendKeyExpiry, s.apiKeyRepo != nil, func() (int, string, error) {
+			return s.extendKeyExpiry(agent, now.AddDate(0, 0, policy.KeyExtensionDays))
+		}),
+		runTrustBoundaryAction(domain.TrustBoundaryActionRelaxVerification, policy.CeilingRelaxVerification, true, func() (int, string, error) {
+			if state.VerificationRelaxed {
+				return 0, "Verification was already relaxed", nil
+			}
+			state.VerificationRelaxed = true
+			return 1, fmt.Sprintf("Approved verifications stay valid for %s instead of %s", RelaxedVerificationValidity, DefaultVerificationValidity), nil
+		}),
+	)
+
+	return event
+}
+
+// extendKeyExpiry pushes the expiry of the agent's active, expiring API keys out to expiresAt.
+// Keys without an expiry or that already expire later are left alone.
+func (s *TrustBoundaryService) extendKeyExpiry(agent *domain.Agent, expiresAt time.Time) (int, string, error) {
+	keys, err := s.apiKeyRepo.GetByAgent(agent.ID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	extended := 0
+	for _, key := range keys {
+		if !key.IsActive || key.ExpiresAt == nil || !key.ExpiresAt.Before(expiresAt) {
+			continue
+		}
+		expiry := expiresAt
+		if err := s.apiKeyRepo.UpdateExpiry(key.ID, &expiry); err != nil {
+			return extended, fmt.Sprintf("Extended %d API key(s) before failing", extended), err
+		}
+		extended++
+	}
+
+	return extended, fmt.Sprintf("Extended %d API key(s) to %s", extended, expiresAt.Format(time.RFC3339)), nil
+}
+
+// logTransition records the transition and its actions in the audit log. Automated entries
+// are attributed to the administrator who configured the policy, or the agent's owner.
+func (s *TrustBoundaryService) logTransition(ctx context.Context, policy *domain.TrustBoundaryPolicy, agent *domain.Agent, event *domain.TrustBoundaryEvent) {
+	log.Printf("🎚️  Trust boundary %s for agent %s (%s): score %.2f, boundary %.2f",
+		event.Transition, agent.Name, agent.ID, event.Score, event.Boundary)
+
+	if s.auditService == nil {
+		return
+	}
+
+	actor := agent.CreatedBy
+	if policy.UpdatedBy != nil {
+		actor = *policy.UpdatedBy
+	}
+
+	err := s.auditService.LogAction(
+		ctx,
+		agent.OrganizationID,
+		actor,
+		domain.AuditActionUpdate,
+		"agent",
+		agent.ID,
+		"",
+		"trust-boundary-worker",
+		map[string]interface{}{
+			"action":     "trust_boundary_transition",
+			"automated":  true,
+			"agentName":  agent.Name,
+			"transition": event.Transition,
+			"score":      event.Score,
+			"boundary":   event.Boundary,
+			"incidentId": event.IncidentID,
+			"actions":    event.Actions,
+		},
+	)
+	if err != nil {
+		log.Printf("⚠️  Failed to audit trust boundary transition for agent %s: %v", agent.ID, err)
+	}
+}
+
+func newTrustBoundaryEvent(agent *domain.Agent, transition domain.TrustBoundaryTransition, boundary float64, now time.Time) *domain.TrustBoundaryEvent {
+	return &domain.TrustBoundaryEvent{
+		AgentID:    agent.ID,
+		AgentName:  agent.Name,
+		Transition: transition,
+		Score:      agent.TrustScore,
+		Boundary:   boundary,
+		Actions:    []domain.TrustBoundaryAction{},
+		OccurredAt: now,
+	}
+}
+
+// runTrustBoundaryAction runs one boundary action and converts its outcome into a report entry
+func runTrustBoundaryAction(
+	action domain.TrustBoundaryActionType,
+	enabled bool,
+	available bool,
+	fn func() (int, string, error),
+) domain.TrustBoundaryAction {
+	if !enabled {
+		return domain.TrustBoundaryAction{Action: action, Status: domain.CompromiseActionSkipped, Detail: "Disabled by policy"}
+	}
+	if !available {
+		return domain.TrustBoundaryAction{Action: action, Status: domain.CompromiseActionSkipped, Detail: "Not available on this server"}
+	}
+
+	count, detail, err := fn()
+	if err != nil {
+		msg := err.Error()
+		return domain.TrustBoundaryAction{Action: action, Status: domain.CompromiseActionFailed, Count: count, Detail: detail, Error: &msg}
+	}
+	return domain.TrustBoundaryAction{Action: action, Status: domain.CompromiseActionCompleted, Count: count, Detail: detail}
+}
@@ -0,0 +1,100 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustBoundaryFloorAndCeilingActions(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	admin := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(admin))
+
+	low := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.TrustScore = 0.2 })
+	high := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.TrustScore = 0.95 })
+	require.NoError(t, repos.Agent.Create(low))
+	require.NoError(t, repos.Agent.Create(high))
+
+	soon := time.Now().Add(24 * time.Hour)
+	key := &domain.APIKey{AgentID: high.ID, OrganizationID: org.ID, Name: "ci", KeyHash: "hash", IsActive: true, ExpiresAt: &soon}
+	require.NoError(t, repos.APIKey.Create(key))
+
+	service := NewTrustBoundaryService(repos.TrustBoundary, repos.Agent, repos.APIKey, repos.Security,
+		NewAuditService(repos.AuditLog))
+	ctx := context.Background()
+
+	events, err := service.EvaluateOrganization(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events, "the default policy is disabled")
+
+	_, err = service.UpdatePolicy(ctx, &TrustBoundaryPolicyRequest{FloorScore: 0.9, CeilingScore: 0.5, CeilingSustainedDays: 1, KeyExtensionDays: 1}, org.ID, admin.ID)
+	assert.ErrorIs(t, err, ErrInvalidTrustBoundaryPolicy)
+
+	_, err = service.UpdatePolicy(ctx, &TrustBoundaryPolicyRequest{
+		Enabled:                  true,
+		FloorScore:               0.3,
+		FloorSuspend:             true,
+		FloorOpenIncident:        true,
+		CeilingScore:             0.9,
+		CeilingSustainedDays:     14,
+		CeilingExtendKeyExpiry:   true,
+		KeyExtensionDays:         90,
+		CeilingRelaxVerification: true,
+	}, org.ID, admin.ID)
+	require.NoError(t, err)
+
+	events, err = service.EvaluateOrganization(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 1, "the ceiling actions wait for the sustained period")
+	assert.Equal(t, domain.TrustBoundaryBelowFloor, events[0].Transition)
+	assert.NotNil(t, events[0].IncidentID)
+
+	suspended, err := repos.Agent.GetByID(low.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AgentStatusSuspended, suspended.Status)
+
+	events, err = service.EvaluateOrganization(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events, "a transition triggers its actions once")
+
+	// Backdate the high-trust agent past the sustained period
+	state, err := repos.TrustBoundary.GetState(high.ID)
+	require.NoError(t, err)
+	since := time.Now().AddDate(0, 0, -15)
+	state.AboveCeilingSince = &since
+	require.NoError(t, repos.TrustBoundary.UpsertState(state))
+
+	events, err = service.EvaluateOrganization(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, domain.TrustBoundarySustainedCeiling, events[0].Transition)
+
+	extended, err := repos.APIKey.GetByID(key.ID)
+	require.NoError(t, err)
+	assert.True(t, extended.ExpiresAt.After(time.Now().AddDate(0, 0, 89)))
+	assert.Equal(t, RelaxedVerificationValidity, service.VerificationValidity(ctx, high))
+
+	// Dropping below the ceiling restores the default verification cadence
+	require.NoError(t, repos.Agent.UpdateTrustScore(high.ID, 0.6))
+	events, err = service.EvaluateOrganization(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, domain.TrustBoundaryLeftCeiling, events[0].Transition)
+
+	dropped, err := repos.Agent.GetByID(high.ID)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultVerificationValidity, service.VerificationValidity(ctx, dropped))
+
+	logs, err := repos.AuditLog.GetByResource("agent", low.ID)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, admin.ID, logs[0].UserID, "automated transitions are attributed to the policy owner")
+	assert.Equal(t, true, logs[0].Metadata["automated"])
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/metrics"
 )
 
 // TrustCalculator implements domain.TrustScoreCalculator
@@ -20,6 +21,9 @@ type TrustCalculator struct {
 	agentRepo              domain.AgentRepository
 	alertRepo              domain.AlertRepository
 	verificationEventRepo  domain.VerificationEventRepository
+	sbomRepo               domain.AgentSBOMRepository
+	mcpRepo                domain.MCPServerRepository // Optional: MCP servers the agent talks to
+	mcpHealthRepo          domain.MCPHealthRepository // Optional: health checks of those servers
 }
 
 // NewTrustCalculator creates a new trust calculator
@@ -41,7 +45,7 @@ func NewTrustCalculator(
 	}
 }
 
-// NewTrustCalculatorWithVerification creates a new trust calculator with verification event repo
+// NewTrustCalculatorWithVerification creates a new trust calculator with verification event and SBOM repos
 func NewTrustCalculatorWithVerification(
 	trustScoreRepo domain.TrustScoreRepository,
 	apiKeyRepo domain.APIKeyRepository,
@@ -50,6 +54,7 @@ func NewTrustCalculatorWithVerification(
 	agentRepo domain.AgentRepository,
 	alertRepo domain.AlertRepository,
 	verificationEventRepo domain.VerificationEventRepository,
+	sbomRepo domain.AgentSBOMRepository,
 ) *TrustCalculator {
 	return &TrustCalculator{
 		trustScoreRepo:         trustScoreRepo,
@@ -59,14 +64,23 @@ func NewTrustCalculatorWithVerification(
 		agentRepo:              agentRepo,
 		alertRepo:              alertRepo,
 		verificationEventRepo:  verificationEventRepo,
+		sbomRepo:               sbomRepo,
 	}
 }
 
+// UseMCPHealth feeds the uptime of the MCP servers an agent talks to into its Uptime factor
+func (c *TrustCalculator) UseMCPHealth(mcpRepo domain.MCPServerRepository, mcpHealthRepo domain.MCPHealthRepository) {
+	c.mcpRepo = mcpRepo
+	c.mcpHealthRepo = mcpHealthRepo
+}
+
 // Calculate calculates trust score for an agent
 // Implements the 8-factor algorithm with weighted average
 func (c *TrustCalculator) Calculate(agent *domain.Agent) (*domain.TrustScore, error) {
+	start := time.Now()
 	factors, err := c.CalculateFactors(agent)
 	if err != nil {
+		metrics.RecordTrustScoreRecalculation("error", time.Since(start).Seconds())
 		return nil, err
 	}
 
@@ -106,6 +120,7 @@ func (c *TrustCalculator) Calculate(agent *domain.Agent) (*domain.TrustScore, er
 
 	// Calculate confidence based on available data
 	confidence := c.calculateConfidence(agent, factors)
+	metrics.RecordTrustScoreRecalculation("success", time.Since(start).Seconds())
 
 	return &domain.TrustScore{
 		ID:             uuid.New(),
@@ -206,6 +221,9 @@ func (c *TrustCalculator) calculateVerificationStatus(agent *domain.Agent) float
 // Factor 2: Uptime & Availability (15% weight)
 // Measures how often agent responds to health checks
 func (c *TrustCalculator) calculateUptime(agent *domain.Agent) float64 {
+	// Health checks of the MCP servers the agent talks to measure the availability it depends on
+	serverUptime, hasServerUptime := c.mcpServerUptime(agent)
+
 	// Try to calculate uptime from verification event response times
 	if c.verificationEventRepo != nil {
 		endTime := time.Now()
@@ -225,9 +243,15 @@ func (c *TrustCalculator) calculateUptime(agent *domain.Agent) float64 {
 				uptime = uptime * 0.8
 			}
 
+			if hasServerUptime {
+				return (uptime + serverUptime) / 2
+			}
 			return uptime
 		}
 	}
+	if hasServerUptime {
+		return serverUptime
+	}
 
 	// Fallback: Return baseline based on agent status
 	if agent.Status == domain.AgentStatusVerified {
@@ -238,6 +262,42 @@ func (c *TrustCalculator) calculateUptime(agent *domain.Agent) float64 {
 	return 0.50
 }
 
+// mcpServerUptime pools the last 30 days of health checks of the MCP servers the agent talks to
+// into one ratio; false when none of them was checked
+func (c *TrustCalculator) mcpServerUptime(agent *domain.Agent) (float64, bool) {
+	if c.mcpRepo == nil || c.mcpHealthRepo == nil || len(agent.TalksTo) == 0 {
+		return 0, false
+	}
+
+	servers, err := c.mcpRepo.GetByOrganization(agent.OrganizationID)
+	if err != nil {
+		return 0, false
+	}
+	serverIDs := make([]uuid.UUID, 0)
+	for _, server := range servers {
+		if agentTalksToServer(agent, server) {
+			serverIDs = append(serverIDs, server.ID)
+		}
+	}
+	if len(serverIDs) == 0 {
+		return 0, false
+	}
+
+	counts, err := c.mcpHealthRepo.CountChecks(serverIDs, time.Now().Add(-domain.MCPHealthCheckRetention))
+	if err != nil {
+		return 0, false
+	}
+	passed, total := 0, 0
+	for _, count := range counts {
+		passed += count.Passed
+		total += count.Total
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(passed) / float64(total), true
+}
+
 // Factor 3: Action Success Rate (15% weight)
 // Measures percentage of actions that complete successfully
 func (c *TrustCalculator) calculateSuccessRate(agent *domain.Agent) float64 {
@@ -337,6 +397,18 @@ func (c *TrustCalculator) calculateSecurityAlerts(agent *domain.Agent) float64 {
 // Factor 5: Compliance Score (10% weight)
 // Measures adherence to compliance policies (SOC 2, HIPAA, GDPR)
 func (c *TrustCalculator) calculateCompliance(agent *domain.Agent) float64 {
+	// Known vulnerabilities in the agent's latest SBOM, as of its last successful scan
+	if c.sbomRepo != nil {
+		sbom, err := c.sbomRepo.GetLatestByAgent(agent.ID)
+		if err == nil {
+			if sbom.CriticalCount > 0 {
+				return 0.0
+			} else if sbom.HighCount > 0 {
+				return 0.50
+			}
+		}
+	}
+
 	// TODO: Query agent_compliance_events table
 	// Calculate: compliant_actions / total_actions_requiring_compliance
 	// For MVP: Return baseline score
@@ -471,6 +543,16 @@ func (c *TrustCalculator) GetLatestTrustScore(ctx context.Context, agentID uuid.
 	return c.trustScoreRepo.GetLatest(agentID)
 }
 
+// GetLatestTrustScores retrieves the latest trust score of several agents in one query.
+// Agents that were never scored are left out.
+func (c *TrustCalculator) GetLatestTrustScores(ctx context.Context, agentIDs []uuid.UUID) (map[uuid.UUID]*domain.TrustScore, error) {
+	scores, err := c.trustScoreRepo.GetLatestByAgents(agentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trust scores: %w", err)
+	}
+	return scores, nil
+}
+
 // GetTrustScoreHistory retrieves trust score history for an agent
 func (c *TrustCalculator) GetTrustScoreHistory(ctx context.Context, agentID uuid.UUID, limit int) ([]*domain.TrustScore, error) {
 	return c.trustScoreRepo.GetHistory(agentID, limit)
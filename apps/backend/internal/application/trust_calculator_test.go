@@ -61,6 +61,19 @@ func (m *MockCapabilityRepository) DeleteCapability(id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockCapabilityRepository) GetExpiredCapabilities(at time.Time) ([]*domain.AgentCapability, error) {
+	args := m.Called(at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.AgentCapability), args.Error(1)
+}
+
+func (m *MockCapabilityRepository) SetCapabilityExpiry(id uuid.UUID, expiresAt *time.Time) error {
+	args := m.Called(id, expiresAt)
+	return args.Error(0)
+}
+
 func (m *MockCapabilityRepository) CreateViolation(violation *domain.CapabilityViolation) error {
 	args := m.Called(violation)
 	return args.Error(0)
@@ -206,6 +219,14 @@ func (m *TrustCalcMockAgentRepository) GetByID(id uuid.UUID) (*domain.Agent, err
 	return args.Get(0).(*domain.Agent), args.Error(1)
 }
 
+func (m *TrustCalcMockAgentRepository) GetByIDs(ids []uuid.UUID) ([]*domain.Agent, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Agent), args.Error(1)
+}
+
 func (m *TrustCalcMockAgentRepository) GetByName(orgID uuid.UUID, name string) (*domain.Agent, error) {
 	args := m.Called(orgID, name)
 	if args.Get(0) == nil {
@@ -255,6 +276,26 @@ func (m *TrustCalcMockAgentRepository) UpdateLastActive(ctx context.Context, age
 	return args.Error(0)
 }
 
+func (m *TrustCalcMockAgentRepository) RotateKey(agent *domain.Agent) error {
+	args := m.Called(agent)
+	return args.Error(0)
+}
+
+func (m *TrustCalcMockAgentRepository) DropEscrowedPrivateKeys(orgID uuid.UUID) (int, error) {
+	args := m.Called(orgID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *TrustCalcMockAgentRepository) FreezeTrustScore(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *TrustCalcMockAgentRepository) AddTalksTo(id uuid.UUID, mcpServer string) (bool, error) {
+	args := m.Called(id, mcpServer)
+	return args.Bool(0), args.Error(1)
+}
+
 // TrustCalcMockAlertRepository mocks the AlertRepository for trust calculator tests
 type TrustCalcMockAlertRepository struct {
 	mock.Mock
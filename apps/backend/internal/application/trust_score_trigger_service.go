@@ -0,0 +1,426 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ErrInvalidTrustScoreTrigger is returned when a trust score trigger request is malformed
+var ErrInvalidTrustScoreTrigger = errors.New("invalid trust score trigger")
+
+// TrustScoreTriggerService manages the organization's trust score triggers and runs them. A
+// trigger fires when a stored score crosses its threshold in the trigger's direction, so a
+// score that stays below a "below 0.5" trigger does not fire it again until it recovers.
+// Scores are evaluated as they are stored: every service that stores trust scores goes
+// through the AgentRepository wrapper.
+type TrustScoreTriggerService struct {
+	triggerRepo    domain.TrustScoreTriggerRepository
+	agentRepo      domain.AgentRepository
+	apiKeyRepo     domain.APIKeyRepository
+	alertRepo      domain.AlertRepository
+	webhookService *WebhookService
+	auditService   *AuditService
+	securityRepo   domain.SecurityRepository
+	now            func() time.Time
+}
+
+// NewTrustScoreTriggerService creates a new trust score trigger service. agentRepo must not
+// be wrapped by the service's own AgentRepository, or suspending an agent evaluates it again.
+// Optional dependencies may be nil; the actions that need them are reported as skipped.
+func NewTrustScoreTriggerService(
+	triggerRepo domain.TrustScoreTriggerRepository,
+	agentRepo domain.AgentRepository,
+	apiKeyRepo domain.APIKeyRepository,
+	alertRepo domain.AlertRepository,
+	webhookService *WebhookService,
+	auditService *AuditService,
+) *TrustScoreTriggerService {
+	return &TrustScoreTriggerService{
+		triggerRepo:    triggerRepo,
+		agentRepo:      agentRepo,
+		apiKeyRepo:     apiKeyRepo,
+		alertRepo:      alertRepo,
+		webhookService: webhookService,
+		auditService:   auditService,
+		now:            time.Now,
+	}
+}
+
+// UseIncidents lets open_incident actions open security incidents. The repository is set after
+// construction because incidents start playbooks, which depend on services that store scores.
+func (s *TrustScoreTriggerService) UseIncidents(securityRepo domain.SecurityRepository) {
+	s.securityRepo = securityRepo
+}
+
+// TrustScoreTriggerRequest represents the request to create or update a trust score trigger
+type TrustScoreTriggerRequest struct {
+	Name        string                               `json:"name"`
+	Description string                               `json:"description"`
+	Condition   domain.TrustScoreTriggerCondition    `json:"condition"`
+	Threshold   float64                              `json:"threshold"`
+	Actions     []domain.TrustScoreTriggerActionType `json:"actions"`
+	Severity    domain.AlertSeverity                 `json:"severity"`            // Defaults to high
+	IsEnabled   *bool                                `json:"isEnabled,omitempty"` // Defaults to true on create
+}
+
+// CreateTrigger creates a new trust score trigger
+func (s *TrustScoreTriggerService) CreateTrigger(ctx context.Context, req *TrustScoreTriggerRequest, orgID, userID uuid.UUID) (*domain.TrustScoreTrigger, error) {
+	trigger := &domain.TrustScoreTrigger{
+		OrganizationID: orgID,
+		IsEnabled:      true,
+		CreatedBy:      userID,
+	}
+	if err := applyTrustScoreTriggerRequest(trigger, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.triggerRepo.CreateTrigger(trigger); err != nil {
+		return nil, fmt.Errorf("failed to create trust score trigger: %w", err)
+	}
+	return trigger, nil
+}
+
+// ListTriggers lists an organization's trust score triggers
+func (s *TrustScoreTriggerService) ListTriggers(ctx context.Context, orgID uuid.UUID) ([]*domain.TrustScoreTrigger, error) {
+	return s.triggerRepo.GetTriggersByOrganization(orgID)
+}
+
+// GetTrigger retrieves a trust score trigger of the organization
+func (s *TrustScoreTriggerService) GetTrigger(ctx context.Context, orgID, id uuid.UUID) (*domain.TrustScoreTrigger, error) {
+	trigger, err := s.triggerRepo.GetTrigger(id)
+	if err != nil {
+		return nil, err
+	}
+	if trigger.OrganizationID != orgID {
+		return nil, domain.ErrTrustScoreTriggerNotFound
+	}
+	return trigger, nil
+}
+
+// UpdateTrigger replaces a trust score trigger's definition
+func (s *TrustScoreTriggerService) UpdateTrigger(ctx context.Context, orgID, id uuid.UUID, req *TrustScoreTriggerRequest) (*domain.TrustScoreTrigger, error) {
+	trigger, err := s.GetTrigger(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyTrustScoreTriggerRequest(trigger, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.triggerRepo.UpdateTrigger(trigger); err != nil {
+		return nil, fmt.Errorf("failed to update trust score trigger: %w", err)
+	}
+	return trigger, nil
+}
+
+// DeleteTrigger deletes a trust score trigger; its firings are kept
+func (s *TrustScoreTriggerService) DeleteTrigger(ctx context.Context, orgID, id uuid.UUID) error {
+	if _, err := s.GetTrigger(ctx, orgID, id); err != nil {
+		return err
+	}
+	return s.triggerRepo.DeleteTrigger(id)
+}
+
+// ListFirings lists a page of the organization's trigger firings, newest first, optionally of one trigger
+func (s *TrustScoreTriggerService) ListFirings(ctx context.Context, orgID uuid.UUID, triggerID *uuid.UUID, limit, offset int) ([]*domain.TrustScoreTriggerFiring, int, error) {
+	return s.triggerRepo.GetFiringsByOrganization(orgID, triggerID, limit, offset)
+}
+
+// applyTrustScoreTriggerRequest validates a request and copies it onto the trigger
+func applyTrustScoreTriggerRequest(trigger *domain.TrustScoreTrigger, req *TrustScoreTriggerRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTrustScoreTrigger)
+	}
+	if !req.Condition.IsValid() {
+		return fmt.Errorf("%w: condition must be below or above", ErrInvalidTrustScoreTrigger)
+	}
+	if req.Threshold < 0 || req.Threshold > 1 {
+		return fmt.Errorf("%w: threshold must be between 0 and 1", ErrInvalidTrustScoreTrigger)
+	}
+	if req.Severity == "" {
+		req.Severity = domain.AlertSeverityHigh
+	}
+	if severityRank(req.Severity) < 0 {
+		return fmt.Errorf("%w: severity must be info, warning, high or critical", ErrInvalidTrustScoreTrigger)
+	}
+	if len(req.Actions) == 0 {
+		return fmt.Errorf("%w: at least one action is required", ErrInvalidTrustScoreTrigger)
+	}
+	seen := make(map[domain.TrustScoreTriggerActionType]bool, len(req.Actions))
+	for _, action := range req.Actions {
+		if !action.IsValid() {
+			return fmt.Errorf("%w: unknown action %q", ErrInvalidTrustScoreTrigger, action)
+		}
+		if seen[action] {
+			return fmt.Errorf("%w: action %q is listed more than once", ErrInvalidTrustScoreTrigger, action)
+		}
+		seen[action] = true
+	}
+
+	trigger.Name = strings.TrimSpace(req.Name)
+	trigger.Description = req.Description
+	trigger.Condition = req.Condition
+	trigger.Threshold = req.Threshold
+	trigger.Actions = req.Actions
+	trigger.Severity = req.Severity
+	if req.IsEnabled != nil {
+		trigger.IsEnabled = *req.IsEnabled
+	}
+	return nil
+}
+
+// Evaluate fires every enabled trigger of the agent's organization whose threshold the agent's
+// score crossed on its way from previousScore. Compromised and revoked agents are left to the
+// compromise response. Failures are logged and reported in the firing; they never fail the
+// score update that caused the evaluation.
+func (s *TrustScoreTriggerService) Evaluate(ctx context.Context, agent *domain.Agent, previousScore float64) []*domain.TrustScoreTriggerFiring {
+	if agent.TrustScore == previousScore || agent.IsCompromised || agent.Status == domain.AgentStatusRevoked {
+		return nil
+	}
+
+	triggers, err := s.triggerRepo.GetTriggersByOrganization(agent.OrganizationID)
+	if err != nil {
+		log.Printf("⚠️  Failed to load trust score triggers for org %s: %v", agent.OrganizationID, err)
+		return nil
+	}
+
+	var firings []*domain.TrustScoreTriggerFiring
+	for _, trigger := range triggers {
+		if !trigger.IsEnabled || !trigger.Condition.Crossed(previousScore, agent.TrustScore, trigger.Threshold) {
+			continue
+		}
+		firings = append(firings, s.fire(ctx, trigger, agent, previousScore))
+	}
+	return firings
+}
+
+// fire runs the trigger's actions in order, records the firing and audit-logs it
+func (s *TrustScoreTriggerService) fire(ctx context.Context, trigger *domain.TrustScoreTrigger, agent *domain.Agent, previousScore float64) *domain.TrustScoreTriggerFiring {
+	firing := &domain.TrustScoreTriggerFiring{
+		ID:             uuid.New(),
+		OrganizationID: agent.OrganizationID,
+		TriggerID:      trigger.ID,
+		TriggerName:    trigger.Name,
+		AgentID:        agent.ID,
+		AgentName:      agent.Name,
+		Condition:      trigger.Condition,
+		Threshold:      trigger.Threshold,
+		PreviousScore:  previousScore,
+		Score:          agent.TrustScore,
+		Actions:        []domain.TrustScoreTriggerActionResult{},
+		FiredAt:        s.now().UTC(),
+	}
+
+	for _, action := range trigger.Actions {
+		firing.Actions = append(firing.Actions, s.runAction(ctx, action, trigger, agent, firing))
+	}
+
+	if err := s.triggerRepo.CreateFiring(firing); err != nil {
+		log.Printf("⚠️  Failed to record trust score trigger %s firing for agent %s: %v", trigger.ID, agent.ID, err)
+	}
+	s.logFiring(ctx, trigger, agent, firing)
+	return firing
+}
+
+// runAction runs one action of a firing and converts its outcome into a report entry
+func (s *TrustScoreTriggerService) runAction(
+	ctx context.Context,
+	action domain.TrustScoreTriggerActionType,
+	trigger *domain.TrustScoreTrigger,
+	agent *domain.Agent,
+	firing *domain.TrustScoreTriggerFiring,
+) domain.TrustScoreTriggerActionResult {
+	var available bool
+	var fn func() (int, string, error)
+	switch action {
+	case domain.TrustScoreTriggerActionAlert:
+		available, fn = s.alertRepo != nil, func() (int, string, error) {
+			alert := &domain.Alert{
+				ID:             uuid.New(),
+				OrganizationID: agent.OrganizationID,
+				AlertType:      domain.AlertTrustScoreThreshold,
+				Severity:       trigger.Severity,
+				Title:          fmt.Sprintf("Trust score trigger %q fired for %s", trigger.Name, agent.Name),
+				Description:    describeTrustScoreFiring(firing),
+				ResourceType:   "agent",
+				ResourceID:     agent.ID,
+				CreatedAt:      firing.FiredAt,
+			}
+			if err := s.alertRepo.Create(alert); err != nil {
+				return 0, "", err
+			}
+			firing.AlertID = &alert.ID
+			return 1, fmt.Sprintf("Raised alert %s", alert.ID), nil
+		}
+	case domain.TrustScoreTriggerActionWebhook:
+		available, fn = s.webhookService != nil, func() (int, string, error) {
+			s.webhookService.PublishTrustScoreTrigger(ctx, firing)
+			return 1, fmt.Sprintf("Queued %s for subscribed webhooks", domain.WebhookEventTrustScoreThreshold), nil
+		}
+	case domain.TrustScoreTriggerActionSuspendAgent:
+		available, fn = true, func() (int, string, error) {
+			if agent.Status == domain.AgentStatusSuspended {
+				return 0, "Agent was already suspended", nil
+			}
+			agent.Status = domain.AgentStatusSuspended
+			if err := s.agentRepo.Update(agent); err != nil {
+				return 0, "", err
+			}
+			return 1, "Agent suspended", nil
+		}
+	case domain.TrustScoreTriggerActionRevokeAPIKeys:
+		available, fn = s.apiKeyRepo != nil, func() (int, string, error) {
+			return s.revokeAPIKeys(agent)
+		}
+	case domain.TrustScoreTriggerActionOpenIncident:
+		available, fn = s.securityRepo != nil, func() (int, string, error) {
+			incident := &domain.SecurityIncident{
+				ID:                uuid.New(),
+				OrganizationID:    agent.OrganizationID,
+				IncidentType:      "trust_score_trigger",
+				Status:            domain.IncidentStatusOpen,
+				Severity:          trigger.Severity,
+				Title:             fmt.Sprintf("Trust score trigger %q fired for %s", trigger.Name, agent.Name),
+				Description:       describeTrustScoreFiring(firing),
+				AffectedResources: []string{"agent:" + agent.ID.String()},
+			}
+			if err := s.securityRepo.CreateIncident(incident); err != nil {
+				return 0, "", err
+			}
+			firing.IncidentID = &incident.ID
+			return 1, fmt.Sprintf("Opened incident %s", incident.ID), nil
+		}
+	}
+
+	if !available {
+		return domain.TrustScoreTriggerActionResult{Action: action, Status: domain.CompromiseActionSkipped, Detail: "Not available on this server"}
+	}
+
+	count, detail, err := fn()
+	if err != nil {
+		msg := err.Error()
+		return domain.TrustScoreTriggerActionResult{Action: action, Status: domain.CompromiseActionFailed, Count: count, Detail: detail, Error: &msg}
+	}
+	return domain.TrustScoreTriggerActionResult{Action: action, Status: domain.CompromiseActionCompleted, Count: count, Detail: detail}
+}
+
+// revokeAPIKeys revokes every active API key of the agent
+func (s *TrustScoreTriggerService) revokeAPIKeys(agent *domain.Agent) (int, string, error) {
+	keys, err := s.apiKeyRepo.GetByAgent(agent.ID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	revoked := 0
+	for _, key := range keys {
+		if !key.IsActive {
+			continue
+		}
+		if err := s.apiKeyRepo.Revoke(key.ID); err != nil {
+			return revoked, fmt.Sprintf("Revoked %d API key(s) before failing", revoked), err
+		}
+		revoked++
+	}
+
+	return revoked, fmt.Sprintf("Revoked %d API key(s)", revoked), nil
+}
+
+// logFiring records the firing and its actions in the audit log. Automated entries are
+// attributed to the administrator who created the trigger, or the agent's owner.
+func (s *TrustScoreTriggerService) logFiring(ctx context.Context, trigger *domain.TrustScoreTrigger, agent *domain.Agent, firing *domain.TrustScoreTriggerFiring) {
+	log.Printf("🎯 Trust score trigger %q fired for agent %s (%s): score %.2f -> %.2f, %s %.2f",
+		trigger.Name, agent.Name, agent.ID, firing.PreviousScore, firing.Score, trigger.Condition, trigger.Threshold)
+
+	if s.auditService == nil {
+		return
+	}
+
+	actor := trigger.CreatedBy
+	if actor == uuid.Nil {
+		actor = agent.CreatedBy
+	}
+
+	err := s.auditService.LogAction(
+		ctx,
+		agent.OrganizationID,
+		actor,
+		domain.AuditActionUpdate,
+		"agent",
+		agent.ID,
+		"",
+		"trust-score-trigger",
+		map[string]interface{}{
+			"action":        "trust_score_trigger_fired",
+			"automated":     true,
+			"agentName":     agent.Name,
+			"triggerId":     trigger.ID,
+			"triggerName":   trigger.Name,
+			"condition":     trigger.Condition,
+			"threshold":     trigger.Threshold,
+			"previousScore": firing.PreviousScore,
+			"score":         firing.Score,
+			"alertId":       firing.AlertID,
+			"incidentId":    firing.IncidentID,
+			"actions":       firing.Actions,
+		},
+	)
+	if err != nil {
+		log.Printf("⚠️  Failed to audit trust score trigger firing for agent %s: %v", agent.ID, err)
+	}
+}
+
+func describeTrustScoreFiring(firing *domain.TrustScoreTriggerFiring) string {
+	movement := "dropped"
+	if firing.Condition == domain.TrustScoreTriggerAbove {
+		movement = "rose"
+	}
+	return fmt.Sprintf("Trust score of agent %s %s from %.2f to %.2f, %s the threshold of %.2f",
+		firing.AgentName, movement, firing.PreviousScore, firing.Score, firing.Condition, firing.Threshold)
+}
+
+// AgentRepository wraps an agent repository so every trust score stored through it, on its
+// own or with the rest of the agent, is evaluated against the organization's triggers
+func (s *TrustScoreTriggerService) AgentRepository(agentRepo domain.AgentRepository) domain.AgentRepository {
+	return &trustScoreTriggerAgentRepository{AgentRepository: agentRepo, triggers: s}
+}
+
+type trustScoreTriggerAgentRepository struct {
+	domain.AgentRepository
+	triggers *TrustScoreTriggerService
+}
+
+func (r *trustScoreTriggerAgentRepository) UpdateTrustScore(id uuid.UUID, newScore float64) error {
+	agent, err := r.AgentRepository.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := r.AgentRepository.UpdateTrustScore(id, newScore); err != nil {
+		return err
+	}
+
+	previousScore := agent.TrustScore
+	agent.TrustScore = newScore
+	r.triggers.Evaluate(context.Background(), agent, previousScore)
+	return nil
+}
+
+func (r *trustScoreTriggerAgentRepository) Update(agent *domain.Agent) error {
+	stored, err := r.AgentRepository.GetByID(agent.ID)
+	if err != nil {
+		// Let the repository report the missing agent
+		return r.AgentRepository.Update(agent)
+	}
+	if err := r.AgentRepository.Update(agent); err != nil {
+		return err
+	}
+
+	r.triggers.Evaluate(context.Background(), agent, stored.TrustScore)
+	return nil
+}
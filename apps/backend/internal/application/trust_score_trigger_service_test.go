@@ -0,0 +1,153 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustScoreTriggersFireOnceWhenScoresCrossTheirThreshold(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	admin := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(admin))
+
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.TrustScore = 0.7 })
+	require.NoError(t, repos.Agent.Create(agent))
+	key := &domain.APIKey{AgentID: agent.ID, OrganizationID: org.ID, Name: "ci", KeyHash: "hash", IsActive: true}
+	require.NoError(t, repos.APIKey.Create(key))
+
+	ctx := context.Background()
+	webhooks := NewWebhookService(repos.Webhook, repos.Tag, nil, "", nil)
+	webhook, err := webhooks.CreateWebhook(ctx, &CreateWebhookRequest{
+		Name:   "soc",
+		URL:    "https://soc.example.com/hooks",
+		Events: []domain.WebhookEvent{domain.WebhookEventTrustScoreThreshold},
+	}, org.ID, admin.ID)
+	require.NoError(t, err)
+
+	service := NewTrustScoreTriggerService(repos.TrustScoreTrigger, repos.Agent, repos.APIKey,
+		repos.Alert, webhooks, NewAuditService(repos.AuditLog))
+	service.UseIncidents(repos.Security)
+	scoredAgents := service.AgentRepository(repos.Agent)
+
+	_, err = service.CreateTrigger(ctx, &TrustScoreTriggerRequest{
+		Name: "too high", Condition: domain.TrustScoreTriggerBelow, Threshold: 1.5,
+		Actions: []domain.TrustScoreTriggerActionType{domain.TrustScoreTriggerActionAlert},
+	}, org.ID, admin.ID)
+	assert.ErrorIs(t, err, ErrInvalidTrustScoreTrigger)
+	_, err = service.CreateTrigger(ctx, &TrustScoreTriggerRequest{
+		Name: "no actions", Condition: domain.TrustScoreTriggerBelow, Threshold: 0.5,
+	}, org.ID, admin.ID)
+	assert.ErrorIs(t, err, ErrInvalidTrustScoreTrigger)
+
+	contain, err := service.CreateTrigger(ctx, &TrustScoreTriggerRequest{
+		Name:      "contain untrusted agents",
+		Condition: domain.TrustScoreTriggerBelow,
+		Threshold: 0.5,
+		Actions: []domain.TrustScoreTriggerActionType{
+			domain.TrustScoreTriggerActionSuspendAgent,
+			domain.TrustScoreTriggerActionRevokeAPIKeys,
+			domain.TrustScoreTriggerActionAlert,
+			domain.TrustScoreTriggerActionOpenIncident,
+			domain.TrustScoreTriggerActionWebhook,
+		},
+	}, org.ID, admin.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AlertSeverityHigh, contain.Severity)
+	recovered, err := service.CreateTrigger(ctx, &TrustScoreTriggerRequest{
+		Name:      "recovered",
+		Condition: domain.TrustScoreTriggerAbove,
+		Threshold: 0.8,
+		Actions:   []domain.TrustScoreTriggerActionType{domain.TrustScoreTriggerActionAlert},
+		Severity:  domain.AlertSeverityInfo,
+	}, org.ID, admin.ID)
+	require.NoError(t, err)
+
+	// Moving without crossing a threshold fires nothing
+	require.NoError(t, scoredAgents.UpdateTrustScore(agent.ID, 0.6))
+	_, total, err := service.ListFirings(ctx, org.ID, nil, 50, 0)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+
+	require.NoError(t, scoredAgents.UpdateTrustScore(agent.ID, 0.4))
+	firings, total, err := service.ListFirings(ctx, org.ID, nil, 50, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	firing := firings[0]
+	assert.Equal(t, contain.ID, firing.TriggerID)
+	assert.Equal(t, 0.6, firing.PreviousScore)
+	assert.Equal(t, 0.4, firing.Score)
+	require.Len(t, firing.Actions, 5)
+	for _, action := range firing.Actions {
+		assert.Equal(t, domain.CompromiseActionCompleted, action.Status, action.Action)
+	}
+
+	suspended, err := repos.Agent.GetByID(agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AgentStatusSuspended, suspended.Status)
+	assert.Equal(t, 0.4, suspended.TrustScore)
+	revoked, err := repos.APIKey.GetByID(key.ID)
+	require.NoError(t, err)
+	assert.False(t, revoked.IsActive)
+
+	require.NotNil(t, firing.AlertID)
+	alert, err := repos.Alert.GetByID(*firing.AlertID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AlertTrustScoreThreshold, alert.AlertType)
+	assert.Equal(t, agent.ID, alert.ResourceID)
+	require.NotNil(t, firing.IncidentID)
+	incident, err := repos.Security.GetIncidentByID(*firing.IncidentID)
+	require.NoError(t, err)
+	assert.Equal(t, "trust_score_trigger", incident.IncidentType)
+	assert.Equal(t, []string{"agent:" + agent.ID.String()}, incident.AffectedResources)
+
+	deliveries, err := repos.Webhook.GetDeliveries(webhook.ID, 100, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, domain.WebhookEventTrustScoreThreshold, deliveries[0].Event)
+	assert.Contains(t, deliveries[0].Payload, `"triggerName":"contain untrusted agents"`)
+
+	// Staying below the threshold does not fire the trigger again
+	require.NoError(t, scoredAgents.UpdateTrustScore(agent.ID, 0.3))
+	_, total, err = service.ListFirings(ctx, org.ID, nil, 50, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+
+	// Scores stored along with the rest of the agent are evaluated too
+	restored, err := scoredAgents.GetByID(agent.ID)
+	require.NoError(t, err)
+	restored.Status = domain.AgentStatusVerified
+	restored.TrustScore = 0.9
+	require.NoError(t, scoredAgents.Update(restored))
+	firings, total, err = service.ListFirings(ctx, org.ID, &recovered.ID, 50, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	assert.Equal(t, 0.3, firings[0].PreviousScore)
+	assert.Equal(t, domain.TrustScoreTriggerActionAlert, firings[0].Actions[0].Action)
+
+	// Disabled triggers do not fire
+	disabled := false
+	_, err = service.UpdateTrigger(ctx, org.ID, contain.ID, &TrustScoreTriggerRequest{
+		Name: contain.Name, Condition: contain.Condition, Threshold: contain.Threshold, Actions: contain.Actions, IsEnabled: &disabled,
+	})
+	require.NoError(t, err)
+	require.NoError(t, scoredAgents.UpdateTrustScore(agent.ID, 0.2))
+	_, total, err = service.ListFirings(ctx, org.ID, &contain.ID, 50, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+
+	logs, err := repos.AuditLog.GetByResource("agent", agent.ID)
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, admin.ID, logs[0].UserID, "automated firings are attributed to the trigger's creator")
+	assert.Equal(t, "trust_score_trigger_fired", logs[0].Metadata["action"])
+
+	_, err = service.GetTrigger(ctx, uuid.New(), contain.ID)
+	assert.ErrorIs(t, err, domain.ErrTrustScoreTriggerNotFound, "triggers of other organizations are not found")
+}
@@ -0,0 +1,295 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	// ErrInvalidUsageRequest is returned for an unsupported period or number of servers
+	ErrInvalidUsageRequest = errors.New("invalid usage request")
+	// ErrUsageSubjectNotFound is returned when the agent or API key is not in the organization
+	ErrUsageSubjectNotFound = errors.New("usage subject not found")
+)
+
+const (
+	defaultUsageDays       = 30
+	maxUsageDays           = 365
+	defaultUsageTopServers = 10
+	maxUsageTopServers     = 100
+	// usageBackfillDays is how far back the first aggregation rolls up
+	usageBackfillDays = 30
+	// usageSettleDays re-rolls the latest rolled up day and the one before it, so late events
+	// and pending verifications that completed since are counted
+	usageSettleDays = 2
+)
+
+// UsageAnalyticsService reports per-agent and per-API key usage (requests per day,
+// verification outcomes, MCP servers contacted, error rates) from daily rollups of the
+// verification events, which its aggregator job maintains
+type UsageAnalyticsService struct {
+	rollupRepo domain.UsageRollupRepository
+	eventRepo  domain.VerificationEventRepository
+	agentRepo  domain.AgentRepository
+	apiKeyRepo domain.APIKeyRepository
+	now        func() time.Time
+}
+
+// NewUsageAnalyticsService creates a new usage analytics service
+func NewUsageAnalyticsService(
+	rollupRepo domain.UsageRollupRepository,
+	eventRepo domain.VerificationEventRepository,
+	agentRepo domain.AgentRepository,
+	apiKeyRepo domain.APIKeyRepository,
+) *UsageAnalyticsService {
+	return &UsageAnalyticsService{
+		rollupRepo: rollupRepo,
+		eventRepo:  eventRepo,
+		agentRepo:  agentRepo,
+		apiKeyRepo: apiKeyRepo,
+		now:        time.Now,
+	}
+}
+
+// AggregateUsage rolls up the verification events of the days since the last aggregation,
+// through today, and returns how many rollups were written (background job)
+func (s *UsageAnalyticsService) AggregateUsage(ctx context.Context) (int, error) {
+	until := usageDay(s.now()).AddDate(0, 0, 1)
+	since := until.AddDate(0, 0, -usageBackfillDays)
+
+	latest, ok, err := s.rollupRepo.LatestDay()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest usage rollup: %w", err)
+	}
+	if ok {
+		since = latest.AddDate(0, 0, 1-usageSettleDays)
+		if earliest := until.AddDate(0, 0, -maxUsageDays); since.Before(earliest) {
+			since = earliest
+		}
+	}
+
+	rollups, err := s.eventRepo.GetDailyUsage(since, until)
+	if err != nil {
+		return 0, fmt.Errorf("failed to roll up usage: %w", err)
+	}
+	if err := s.rollupRepo.Upsert(rollups); err != nil {
+		return 0, fmt.Errorf("failed to store usage rollups: %w", err)
+	}
+	return len(rollups), nil
+}
+
+// GetAgentUsage reports an agent's usage over the last days (30 by default)
+func (s *UsageAnalyticsService) GetAgentUsage(ctx context.Context, orgID, agentID uuid.UUID, days, top int) (*domain.UsageReport, error) {
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil || agent.OrganizationID != orgID {
+		return nil, fmt.Errorf("%w: agent", ErrUsageSubjectNotFound)
+	}
+	report, err := s.report(orgID, domain.UsageSubjectAgent, agent.ID, days, top)
+	if err != nil {
+		return nil, err
+	}
+	report.Name = agent.DisplayName
+	return report, nil
+}
+
+// GetAPIKeyUsage reports the usage of an API key over the last days (30 by default)
+func (s *UsageAnalyticsService) GetAPIKeyUsage(ctx context.Context, orgID, keyID uuid.UUID, days, top int) (*domain.UsageReport, error) {
+	key, err := s.apiKeyRepo.GetByID(keyID)
+	if err != nil || key.OrganizationID != orgID {
+		return nil, fmt.Errorf("%w: API key", ErrUsageSubjectNotFound)
+	}
+	report, err := s.report(orgID, domain.UsageSubjectAPIKey, key.ID, days, top)
+	if err != nil {
+		return nil, err
+	}
+	report.Name = key.Name
+	report.LastUsedAt = key.LastUsedAt
+	return report, nil
+}
+
+// ListUsage summarizes the usage of every agent or API key of the organization over the last
+// days (30 by default), busiest first. Subjects without requests are listed as idle.
+func (s *UsageAnalyticsService) ListUsage(ctx context.Context, orgID uuid.UUID, subjectType domain.UsageSubjectType, days int) (*domain.UsageSummaryReport, error) {
+	since, until, err := usagePeriod(s.now(), days)
+	if err != nil {
+		return nil, err
+	}
+
+	var subjects []domain.UsageSummary
+	switch subjectType {
+	case domain.UsageSubjectAgent:
+		agents, err := s.agentRepo.GetByOrganization(orgID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list agents: %w", err)
+		}
+		for _, agent := range agents {
+			subjects = append(subjects, domain.UsageSummary{SubjectID: agent.ID, Name: agent.DisplayName})
+		}
+	case domain.UsageSubjectAPIKey:
+		keys, err := s.apiKeyRepo.GetByOrganization(orgID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list API keys: %w", err)
+		}
+		for _, key := range keys {
+			agentID := key.AgentID
+			subjects = append(subjects, domain.UsageSummary{SubjectID: key.ID, Name: key.Name, AgentID: &agentID, LastUsedAt: key.LastUsedAt})
+		}
+	default:
+		return nil, fmt.Errorf("%w: subject type must be agent or api_key", ErrInvalidUsageRequest)
+	}
+
+	rollups, err := s.rollupRepo.List(orgID, subjectType, nil, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage rollups: %w", err)
+	}
+	bySubject := make(map[uuid.UUID][]*domain.UsageRollup)
+	for _, rollup := range rollups {
+		bySubject[rollup.SubjectID] = append(bySubject[rollup.SubjectID], rollup)
+	}
+
+	for i := range subjects {
+		summary := &subjects[i]
+		for _, rollup := range bySubject[summary.SubjectID] {
+			addUsage(&summary.Totals, rollup)
+			if rollup.Requests > 0 {
+				summary.ActiveDays++
+				day := rollup.Day
+				summary.LastActiveDay = &day
+			}
+			if rollup.Requests > summary.PeakDayRequests {
+				summary.PeakDayRequests = rollup.Requests
+			}
+		}
+		finishUsageTotals(&summary.Totals)
+		summary.DailyAverage = float64(summary.Totals.Requests) / float64(len(usageDays(since, until)))
+		summary.Idle = summary.Totals.Requests == 0
+	}
+	sort.SliceStable(subjects, func(i, j int) bool {
+		if subjects[i].Totals.Requests != subjects[j].Totals.Requests {
+			return subjects[i].Totals.Requests > subjects[j].Totals.Requests
+		}
+		return subjects[i].Name < subjects[j].Name
+	})
+
+	return &domain.UsageSummaryReport{
+		SubjectType: subjectType,
+		PeriodStart: since,
+		PeriodEnd:   until,
+		Subjects:    subjects,
+	}, nil
+}
+
+// report builds the usage report of one subject from its rollups
+func (s *UsageAnalyticsService) report(orgID uuid.UUID, subjectType domain.UsageSubjectType, subjectID uuid.UUID, days, top int) (*domain.UsageReport, error) {
+	since, until, err := usagePeriod(s.now(), days)
+	if err != nil {
+		return nil, err
+	}
+	if top == 0 {
+		top = defaultUsageTopServers
+	}
+	if top < 1 || top > maxUsageTopServers {
+		return nil, fmt.Errorf("%w: top must be between 1 and %d", ErrInvalidUsageRequest, maxUsageTopServers)
+	}
+
+	rollups, err := s.rollupRepo.List(orgID, subjectType, &subjectID, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage rollups: %w", err)
+	}
+	byDay := make(map[time.Time]*domain.UsageRollup, len(rollups))
+	for _, rollup := range rollups {
+		byDay[rollup.Day] = rollup
+	}
+
+	report := &domain.UsageReport{
+		SubjectType:   subjectType,
+		SubjectID:     subjectID,
+		PeriodStart:   since,
+		PeriodEnd:     until,
+		Daily:         make([]domain.UsageDay, 0, days),
+		TopMCPServers: make([]domain.UsageServerStat, 0),
+	}
+	servers := make(map[string]int)
+	for _, day := range usageDays(since, until) {
+		point := domain.UsageDay{Day: day}
+		if rollup, ok := byDay[day]; ok {
+			addUsage(&point.UsageTotals, rollup)
+			addUsage(&report.Totals, rollup)
+			for server, count := range rollup.MCPServers {
+				servers[server] += count
+			}
+			if rollup.Requests > 0 {
+				report.ActiveDays++
+				lastActive := day
+				report.LastActiveDay = &lastActive
+			}
+		}
+		finishUsageTotals(&point.UsageTotals)
+		report.Daily = append(report.Daily, point)
+	}
+	finishUsageTotals(&report.Totals)
+
+	for server, requests := range servers {
+		report.TopMCPServers = append(report.TopMCPServers, domain.UsageServerStat{Server: server, Requests: requests})
+	}
+	sort.Slice(report.TopMCPServers, func(i, j int) bool {
+		if report.TopMCPServers[i].Requests != report.TopMCPServers[j].Requests {
+			return report.TopMCPServers[i].Requests > report.TopMCPServers[j].Requests
+		}
+		return report.TopMCPServers[i].Server < report.TopMCPServers[j].Server
+	})
+	if len(report.TopMCPServers) > top {
+		report.TopMCPServers = report.TopMCPServers[:top]
+	}
+
+	return report, nil
+}
+
+// usagePeriod returns the days reported: the last days through today (UTC)
+func usagePeriod(now time.Time, days int) (since, until time.Time, err error) {
+	if days == 0 {
+		days = defaultUsageDays
+	}
+	if days < 1 || days > maxUsageDays {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidUsageRequest, maxUsageDays)
+	}
+	until = usageDay(now).AddDate(0, 0, 1)
+	return until.AddDate(0, 0, -days), until, nil
+}
+
+// usageDay returns midnight UTC of the day t falls on
+func usageDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// usageDays lists the days in [since, until)
+func usageDays(since, until time.Time) []time.Time {
+	var days []time.Time
+	for day := since; day.Before(until); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+func addUsage(totals *domain.UsageTotals, rollup *domain.UsageRollup) {
+	totals.Requests += rollup.Requests
+	totals.Successes += rollup.Successes
+	totals.Failures += rollup.Failures
+	totals.Pending += rollup.Pending
+	totals.Timeouts += rollup.Timeouts
+	totals.Errors += rollup.Errors
+}
+
+func finishUsageTotals(totals *domain.UsageTotals) {
+	if totals.Requests > 0 {
+		totals.SuccessRate = float64(totals.Successes) / float64(totals.Requests)
+		totals.ErrorRate = float64(totals.Errors) / float64(totals.Requests)
+	}
+}
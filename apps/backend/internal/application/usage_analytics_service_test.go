@@ -0,0 +1,99 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageAnalyticsRollUpVerificationsPerAgentAndAPIKey(t *testing.T) {
+	ctx := context.Background()
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	busy := testsupport.NewAgent(org.ID)
+	idle := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(busy))
+	require.NoError(t, repos.Agent.Create(idle))
+	key := &domain.APIKey{OrganizationID: org.ID, AgentID: busy.ID, Name: "ci-key", IsActive: true}
+	abandoned := &domain.APIKey{OrganizationID: org.ID, AgentID: idle.ID, Name: "old-key", IsActive: true}
+	require.NoError(t, repos.APIKey.Create(key))
+	require.NoError(t, repos.APIKey.Create(abandoned))
+
+	record := func(status domain.VerificationEventStatus, at time.Time, apiKeyID *uuid.UUID, errorCode string, servers ...string) {
+		event := &domain.VerificationEvent{
+			OrganizationID:    org.ID,
+			AgentID:           &busy.ID,
+			Status:            status,
+			InitiatorAPIKeyID: apiKeyID,
+			CurrentMCPServers: servers,
+			CreatedAt:         at,
+		}
+		if errorCode != "" {
+			event.ErrorCode = &errorCode
+		}
+		require.NoError(t, repos.VerificationEvent.Create(event))
+	}
+	now := time.Now()
+	record(domain.VerificationEventStatusSuccess, now, &key.ID, "", "filesystem-mcp", "external-api-mcp")
+	record(domain.VerificationEventStatusSuccess, now, &key.ID, "", "filesystem-mcp", "external-api-mcp")
+	record(domain.VerificationEventStatusFailed, now, &key.ID, "SIGNATURE_INVALID")
+	record(domain.VerificationEventStatusSuccess, now.Add(-48*time.Hour), nil, "", "filesystem-mcp")
+
+	usage := NewUsageAnalyticsService(repos.UsageRollup, repos.VerificationEvent, repos.Agent, repos.APIKey)
+	count, err := usage.AggregateUsage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count, "two days of the agent and today of the key")
+
+	report, err := usage.GetAgentUsage(ctx, org.ID, busy.ID, 7, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Totals.Requests)
+	assert.Equal(t, 3, report.Totals.Successes)
+	assert.Equal(t, 1, report.Totals.Failures)
+	assert.Equal(t, 1, report.Totals.Errors)
+	assert.InDelta(t, 0.25, report.Totals.ErrorRate, 1e-9)
+	require.Len(t, report.Daily, 7, "idle days are reported with zero requests")
+	assert.Equal(t, 3, report.Daily[6].Requests)
+	assert.Equal(t, 0, report.Daily[5].Requests)
+	assert.Equal(t, 1, report.Daily[4].Requests)
+	assert.Equal(t, 2, report.ActiveDays)
+	assert.Equal(t, []domain.UsageServerStat{{Server: "filesystem-mcp", Requests: 3}, {Server: "external-api-mcp", Requests: 2}}, report.TopMCPServers)
+
+	// The next run rolls up today again, counting events recorded since
+	record(domain.VerificationEventStatusPending, now, &key.ID, "")
+	_, err = usage.AggregateUsage(ctx)
+	require.NoError(t, err)
+	keyReport, err := usage.GetAPIKeyUsage(ctx, org.ID, key.ID, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "ci-key", keyReport.Name)
+	assert.Equal(t, 4, keyReport.Totals.Requests)
+	assert.Equal(t, 1, keyReport.Totals.Pending)
+	assert.Len(t, keyReport.Daily, 30)
+	assert.Len(t, keyReport.TopMCPServers, 1)
+
+	// Organization listings flag the agents and keys without requests
+	keys, err := usage.ListUsage(ctx, org.ID, domain.UsageSubjectAPIKey, 7)
+	require.NoError(t, err)
+	require.Len(t, keys.Subjects, 2)
+	assert.Equal(t, key.ID, keys.Subjects[0].SubjectID)
+	assert.Equal(t, 4, keys.Subjects[0].PeakDayRequests)
+	assert.False(t, keys.Subjects[0].Idle)
+	assert.Equal(t, abandoned.ID, keys.Subjects[1].SubjectID)
+	assert.True(t, keys.Subjects[1].Idle)
+	agents, err := usage.ListUsage(ctx, org.ID, domain.UsageSubjectAgent, 7)
+	require.NoError(t, err)
+	require.Len(t, agents.Subjects, 2)
+	assert.Equal(t, 5, agents.Subjects[0].Totals.Requests)
+	assert.InDelta(t, 5.0/7, agents.Subjects[0].DailyAverage, 1e-9)
+	assert.True(t, agents.Subjects[1].Idle)
+
+	_, err = usage.GetAgentUsage(ctx, uuid.New(), busy.ID, 7, 0)
+	assert.ErrorIs(t, err, ErrUsageSubjectNotFound)
+	_, err = usage.GetAgentUsage(ctx, org.ID, busy.ID, 400, 0)
+	assert.ErrorIs(t, err, ErrInvalidUsageRequest)
+}
@@ -0,0 +1,109 @@
+package application
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// verificationStreamBuffer is how many events a stream subscriber may fall behind before events
+// are dropped for it
+const verificationStreamBuffer = 256
+
+// ErrVerificationStreamUnavailable is returned when no broker streams verification events
+var ErrVerificationStreamUnavailable = errors.New("verification event stream is not available")
+
+// VerificationEventBroker fans newly recorded verification events out to the streams of their
+// organization. It lives in process: each backend instance streams the events it records.
+// Publishing never blocks on a slow subscriber; events it has no room for are dropped and counted.
+type VerificationEventBroker struct {
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID]map[*VerificationEventSubscription]struct{}
+}
+
+// NewVerificationEventBroker creates an in-process verification event broker
+func NewVerificationEventBroker() *VerificationEventBroker {
+	return &VerificationEventBroker{
+		subscribers: make(map[uuid.UUID]map[*VerificationEventSubscription]struct{}),
+	}
+}
+
+// VerificationEventSubscription receives the events of one organization that match its filter
+type VerificationEventSubscription struct {
+	broker  *VerificationEventBroker
+	orgID   uuid.UUID
+	filter  domain.VerificationEventFilter
+	events  chan *domain.VerificationEvent
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+// Subscribe starts receiving the organization's events that match the filter. Close the
+// subscription when done.
+func (b *VerificationEventBroker) Subscribe(orgID uuid.UUID, filter domain.VerificationEventFilter) *VerificationEventSubscription {
+	subscription := &VerificationEventSubscription{
+		broker: b,
+		orgID:  orgID,
+		filter: filter,
+		events: make(chan *domain.VerificationEvent, verificationStreamBuffer),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subscribers[orgID] == nil {
+		b.subscribers[orgID] = make(map[*VerificationEventSubscription]struct{})
+	}
+	b.subscribers[orgID][subscription] = struct{}{}
+	return subscription
+}
+
+// Publish delivers the event to the matching subscribers of its organization
+func (b *VerificationEventBroker) Publish(event *domain.VerificationEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for subscription := range b.subscribers[event.OrganizationID] {
+		if !subscription.filter.Matches(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			subscription.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribers returns how many streams the organization has open
+func (b *VerificationEventBroker) Subscribers(orgID uuid.UUID) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers[orgID])
+}
+
+// Events receives the subscribed events; it is closed when the subscription is
+func (s *VerificationEventSubscription) Events() <-chan *domain.VerificationEvent {
+	return s.events
+}
+
+// Dropped returns and resets how many events were dropped since the last call because the
+// subscriber fell behind
+func (s *VerificationEventSubscription) Dropped() int64 {
+	return s.dropped.Swap(0)
+}
+
+// Close stops the subscription. It is safe to call more than once.
+func (s *VerificationEventSubscription) Close() {
+	s.once.Do(func() {
+		b := s.broker
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[s.orgID], s)
+		if len(b.subscribers[s.orgID]) == 0 {
+			delete(b.subscribers, s.orgID)
+		}
+		close(s.events)
+	})
+}
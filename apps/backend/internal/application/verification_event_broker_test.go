@@ -0,0 +1,90 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationEventStreamPushesRecordedEvents(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	other := testsupport.NewOrganization()
+	agent := testsupport.NewAgent(org.ID)
+	canary := testsupport.NewAgent(org.ID)
+	outsider := testsupport.NewAgent(other.ID)
+	for _, a := range []*domain.Agent{agent, canary, outsider} {
+		require.NoError(t, repos.Agent.Create(a))
+	}
+	ctx := context.Background()
+	service := application.NewVerificationEventService(repos.VerificationEvent, repos.Agent, nil, nil, nil, nil)
+
+	_, err := service.SubscribeVerificationEvents(ctx, org.ID, domain.VerificationEventFilter{})
+	assert.ErrorIs(t, err, application.ErrVerificationStreamUnavailable)
+
+	broker := application.NewVerificationEventBroker()
+	service.UseBroker(broker)
+	all, err := service.SubscribeVerificationEvents(ctx, org.ID, domain.VerificationEventFilter{})
+	require.NoError(t, err)
+	failed, err := service.SubscribeVerificationEvents(ctx, org.ID, domain.VerificationEventFilter{
+		AgentID:  &canary.ID,
+		Statuses: []domain.VerificationEventStatus{domain.VerificationEventStatusFailed},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, broker.Subscribers(org.ID))
+
+	record := func(agent *domain.Agent, status domain.VerificationEventStatus) *domain.VerificationEvent {
+		event, err := service.LogVerificationEvent(ctx, agent.OrganizationID, agent.ID,
+			domain.VerificationProtocolMCP, domain.VerificationTypeIdentity, status, 5, domain.InitiatorTypeSystem, nil, nil)
+		require.NoError(t, err)
+		return event
+	}
+	first := record(agent, domain.VerificationEventStatusFailed)
+	second := record(canary, domain.VerificationEventStatusSuccess)
+	third := record(canary, domain.VerificationEventStatusFailed)
+	record(outsider, domain.VerificationEventStatusFailed)
+
+	received := func(subscription *application.VerificationEventSubscription) []uuid.UUID {
+		var ids []uuid.UUID
+		for {
+			select {
+			case event := <-subscription.Events():
+				ids = append(ids, event.ID)
+			default:
+				return ids
+			}
+		}
+	}
+	assert.Equal(t, []uuid.UUID{first.ID, second.ID, third.ID}, received(all))
+	assert.Equal(t, []uuid.UUID{third.ID}, received(failed))
+
+	// Closing is idempotent and stops delivery
+	failed.Close()
+	failed.Close()
+	_, open := <-failed.Events()
+	assert.False(t, open)
+	all.Close()
+	assert.Zero(t, broker.Subscribers(org.ID))
+	record(canary, domain.VerificationEventStatusFailed)
+}
+
+func TestVerificationEventStreamDropsEventsForSlowSubscribers(t *testing.T) {
+	broker := application.NewVerificationEventBroker()
+	orgID := uuid.New()
+	subscription := broker.Subscribe(orgID, domain.VerificationEventFilter{})
+	defer subscription.Close()
+
+	// Publishing never blocks; what the subscriber has no room for is counted once
+	for i := 0; i < 300; i++ {
+		broker.Publish(&domain.VerificationEvent{ID: uuid.New(), OrganizationID: orgID})
+	}
+	assert.Len(t, subscription.Events(), cap(subscription.Events()))
+	assert.EqualValues(t, 300-cap(subscription.Events()), subscription.Dropped())
+	assert.Zero(t, subscription.Dropped())
+}
@@ -2,18 +2,32 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/metrics"
+	"github.com/opena2a/identity/backend/internal/infrastructure/tracing"
 )
 
+// MaxBulkVerificationDecisions caps how many verifications one bulk decision may cover
+const MaxBulkVerificationDecisions = 100
+
+// ErrInvalidBulkDecision is returned when a bulk verification decision request is malformed
+var ErrInvalidBulkDecision = errors.New("invalid bulk verification decision")
+
 // VerificationEventService handles verification event business logic
 type VerificationEventService struct {
 	eventRepo      domain.VerificationEventRepository
 	agentRepo      domain.AgentRepository
 	driftDetection *DriftDetectionService
+	webhookService *WebhookService
+	policyService  *SecurityPolicyService    // Optional: expression policies that may deny the verification
+	securityRepo   domain.SecurityRepository // Optional: the agent's recent anomalies raise the event's risk level
+	broker         *VerificationEventBroker  // Optional: streams recorded events to dashboard clients
 }
 
 // NewVerificationEventService creates a new verification event service
@@ -21,12 +35,37 @@ func NewVerificationEventService(
 	eventRepo domain.VerificationEventRepository,
 	agentRepo domain.AgentRepository,
 	driftDetection *DriftDetectionService,
+	webhookService *WebhookService,
+	policyService *SecurityPolicyService,
+	securityRepo domain.SecurityRepository,
 ) *VerificationEventService {
 	return &VerificationEventService{
 		eventRepo:      eventRepo,
 		agentRepo:      agentRepo,
 		driftDetection: driftDetection,
+		webhookService: webhookService,
+		policyService:  policyService,
+		securityRepo:   securityRepo,
+	}
+}
+
+// UseBroker publishes every recorded event to the broker, which streams it to the
+// organization's connected dashboard clients
+func (s *VerificationEventService) UseBroker(broker *VerificationEventBroker) {
+	s.broker = broker
+}
+
+// SubscribeVerificationEvents streams the organization's events that match the filter as they
+// are recorded. Close the subscription when done.
+func (s *VerificationEventService) SubscribeVerificationEvents(
+	ctx context.Context,
+	orgID uuid.UUID,
+	filter domain.VerificationEventFilter,
+) (*VerificationEventSubscription, error) {
+	if s.broker == nil {
+		return nil, ErrVerificationStreamUnavailable
 	}
+	return s.broker.Subscribe(orgID, filter), nil
 }
 
 // LogVerificationEvent creates a new verification event (for automatic logging)
@@ -42,9 +81,20 @@ func (s *VerificationEventService) LogVerificationEvent(
 	initiatorID *uuid.UUID,
 	metadata map[string]interface{},
 ) (*domain.VerificationEvent, error) {
+	ctx, span := tracing.Start(ctx, "VerificationEventService.LogVerificationEvent",
+		tracing.String("aim.agent_id", agentID.String()),
+		tracing.String("aim.verification.protocol", string(protocol)),
+		tracing.String("aim.verification.type", string(verificationType)))
+	defer span.End()
+
 	// Get agent details
-	agent, err := s.agentRepo.GetByID(agentID)
+	var agent *domain.Agent
+	err := traceRepository(ctx, "AgentRepository", "GetByID", func() (err error) {
+		agent, err = s.agentRepo.GetByID(agentID)
+		return err
+	})
 	if err != nil {
+		span.RecordError(err)
 		return nil, fmt.Errorf("agent not found: %w", err)
 	}
 
@@ -68,10 +118,22 @@ func (s *VerificationEventService) LogVerificationEvent(
 		CreatedAt:        now,
 		Metadata:         metadata,
 	}
+	event.RecordCaller(ctx)
+	s.assessRisk(ctx, event, agent)
 
-	if err := s.eventRepo.Create(event); err != nil {
+	if err := traceRepository(ctx, "VerificationEventRepository", "Create", func() error { return s.eventRepo.Create(event) }); err != nil {
+		span.RecordError(err)
 		return nil, fmt.Errorf("failed to create verification event: %w", err)
 	}
+	span.SetAttributes(
+		tracing.String("aim.verification_event_id", event.ID.String()),
+		tracing.String("aim.verification.status", string(event.Status)),
+		tracing.Bool("aim.drift_detected", event.DriftDetected),
+	)
+
+	recordVerificationMetrics(event)
+	s.publish(event)
+	s.notifyWebhooks(ctx, event, agent)
 
 	return event, nil
 }
@@ -81,9 +143,20 @@ func (s *VerificationEventService) CreateVerificationEvent(
 	ctx context.Context,
 	req *CreateVerificationEventRequest,
 ) (*domain.VerificationEvent, error) {
+	ctx, span := tracing.Start(ctx, "VerificationEventService.CreateVerificationEvent",
+		tracing.String("aim.agent_id", req.AgentID.String()),
+		tracing.String("aim.verification.protocol", string(req.Protocol)),
+		tracing.String("aim.verification.type", string(req.VerificationType)))
+	defer span.End()
+
 	// Validate agent exists
-	agent, err := s.agentRepo.GetByID(req.AgentID)
+	var agent *domain.Agent
+	err := traceRepository(ctx, "AgentRepository", "GetByID", func() (err error) {
+		agent, err = s.agentRepo.GetByID(req.AgentID)
+		return err
+	})
 	if err != nil {
+		span.RecordError(err)
 		return nil, fmt.Errorf("agent not found: %w", err)
 	}
 
@@ -97,6 +170,11 @@ func (s *VerificationEventService) CreateVerificationEvent(
 		startedAt = now
 	}
 
+	createdAt := now
+	if !req.RecordedAt.IsZero() {
+		createdAt = req.RecordedAt
+	}
+
 	completedAt := req.CompletedAt
 	if completedAt == nil && (req.Status == domain.VerificationEventStatusSuccess || req.Status == domain.VerificationEventStatusFailed) {
 		completedAt = &now
@@ -129,20 +207,26 @@ func (s *VerificationEventService) CreateVerificationEvent(
 		Location:         req.Location,
 		StartedAt:        startedAt,
 		CompletedAt:      completedAt,
-		CreatedAt:        now,
+		CreatedAt:        createdAt,
 		Details:          req.Details,
 		Metadata:         req.Metadata,
 
-		// Store runtime configuration for drift tracking
-		CurrentMCPServers:   req.CurrentMCPServers,
+		// Store runtime configuration for drift tracking; the servers of the tool calls count as
+		// servers being communicated with
+		CurrentMCPServers:   MergeMCPToolCallServers(req.CurrentMCPServers, req.CurrentMCPTools),
+		CurrentMCPTools:     req.CurrentMCPTools,
 		CurrentCapabilities: req.CurrentCapabilities,
 	}
+	event.RecordCaller(ctx)
 
 	// Perform drift detection if runtime configuration provided
-	if len(req.CurrentMCPServers) > 0 || len(req.CurrentCapabilities) > 0 {
-		driftResult, err := s.driftDetection.DetectDrift(
+	var drift *DriftResult
+	if len(req.CurrentMCPServers) > 0 || len(req.CurrentMCPTools) > 0 || len(req.CurrentCapabilities) > 0 {
+		driftResult, err := s.driftDetection.DetectDriftWithTools(
+			ctx,
 			req.AgentID,
 			req.CurrentMCPServers,
+			req.CurrentMCPTools,
 			req.CurrentCapabilities,
 		)
 
@@ -153,17 +237,138 @@ func (s *VerificationEventService) CreateVerificationEvent(
 			// Store drift detection results in the event
 			event.DriftDetected = driftResult.DriftDetected
 			event.MCPServerDrift = driftResult.MCPServerDrift
+			event.MCPToolDrift = driftResult.MCPToolDrift
 			event.CapabilityDrift = driftResult.CapabilityDrift
+			drift = driftResult
 		}
 	}
 
-	if err := s.eventRepo.Create(event); err != nil {
+	// Evaluate expression policies; a triggered block_and_alert policy denies the action
+	if s.policyService != nil {
+		results, err := s.policyService.EvaluateExpressionPolicies(ctx, &PolicyEvaluationContext{
+			Agent:        agent,
+			Capabilities: req.CurrentCapabilities,
+			Drift:        drift,
+			Event:        event,
+		})
+		if err != nil {
+			// Log error but don't fail the verification event creation
+			fmt.Printf("Policy evaluation failed: %v\n", err)
+		} else {
+			applyPolicyResults(event, results)
+		}
+	}
+	s.assessRisk(ctx, event, agent)
+
+	if err := traceRepository(ctx, "VerificationEventRepository", "Create", func() error { return s.eventRepo.Create(event) }); err != nil {
+		span.RecordError(err)
 		return nil, fmt.Errorf("failed to create verification event: %w", err)
 	}
+	span.SetAttributes(
+		tracing.String("aim.verification_event_id", event.ID.String()),
+		tracing.String("aim.verification.status", string(event.Status)),
+		tracing.Bool("aim.drift_detected", event.DriftDetected),
+	)
+
+	recordVerificationMetrics(event)
+	s.publish(event)
+	s.notifyWebhooks(ctx, event, agent)
 
 	return event, nil
 }
 
+// assessRisk sets the event's risk level, taking the agent's anomalies of the last day into account
+func (s *VerificationEventService) assessRisk(ctx context.Context, event *domain.VerificationEvent, agent *domain.Agent) {
+	riskCtx := VerificationRiskContext{AgentCompromised: agent.IsCompromised}
+	if s.securityRepo != nil {
+		since := time.Now().Add(-24 * time.Hour)
+		var anomalies []*domain.Anomaly
+		var total int
+		err := traceRepository(ctx, "SecurityRepository", "SearchAnomalies", func() (err error) {
+			anomalies, total, err = s.securityRepo.SearchAnomalies(event.OrganizationID, domain.AnomalyQueryParams{
+				ResourceType: "agent",
+				ResourceID:   &agent.ID,
+				From:         &since,
+				SortBy:       "severity",
+				Limit:        1,
+			})
+			return err
+		})
+		if err != nil {
+			// Log error but don't fail the verification event creation
+			fmt.Printf("Anomaly lookup for risk assessment failed: %v\n", err)
+		} else if total > 0 {
+			riskCtx.RecentAnomalies = total
+			severity := anomalies[0].Severity
+			riskCtx.SevereRecentAnomaly = severity == domain.AlertSeverityHigh || severity == domain.AlertSeverityCritical
+		}
+	}
+	event.RiskLevel = ClassifyVerificationRisk(event, riskCtx)
+}
+
+// applyPolicyResults records the policies that triggered in the event metadata and denies the
+// event when one of them blocks
+func applyPolicyResults(event *domain.VerificationEvent, results []*domain.PolicyEvaluationResult) {
+	var triggered []*domain.PolicyEvaluationResult
+	var blocking *domain.PolicyEvaluationResult
+	for _, result := range results {
+		if !result.Triggered {
+			continue
+		}
+		triggered = append(triggered, result)
+		if result.ShouldBlock && blocking == nil {
+			blocking = result
+		}
+	}
+	if len(triggered) == 0 {
+		return
+	}
+
+	if event.Metadata == nil {
+		event.Metadata = map[string]interface{}{}
+	}
+	event.Metadata["policyEvaluations"] = triggered
+	if blocking == nil {
+		return
+	}
+
+	denied := domain.VerificationResultDenied
+	code := "policy_denied"
+	reason := fmt.Sprintf("Denied by security policy '%s': %s", blocking.PolicyName, blocking.Reason)
+	event.Status = domain.VerificationEventStatusFailed
+	event.Result = &denied
+	event.ErrorCode = &code
+	event.ErrorReason = &reason
+	if event.CompletedAt == nil {
+		now := time.Now()
+		event.CompletedAt = &now
+	}
+}
+
+// recordVerificationMetrics counts the event by type and status, so operators can alert on failure spikes
+func recordVerificationMetrics(event *domain.VerificationEvent) {
+	metrics.RecordVerificationEvent(string(event.VerificationType), string(event.Status))
+	if event.DurationMs > 0 {
+		metrics.ObserveVerificationDuration(string(event.VerificationType), float64(event.DurationMs)/1000)
+	}
+}
+
+// publish streams the event to the organization's connected dashboard clients
+func (s *VerificationEventService) publish(event *domain.VerificationEvent) {
+	if s.broker == nil {
+		return
+	}
+	s.broker.Publish(event)
+}
+
+// notifyWebhooks delivers the event to subscribed webhooks whose filters match
+func (s *VerificationEventService) notifyWebhooks(ctx context.Context, event *domain.VerificationEvent, agent *domain.Agent) {
+	if s.webhookService == nil {
+		return
+	}
+	s.webhookService.TriggerVerificationEvent(ctx, event, agent)
+}
+
 // GetVerificationEvent retrieves a verification event by ID
 func (s *VerificationEventService) GetVerificationEvent(ctx context.Context, id uuid.UUID) (*domain.VerificationEvent, error) {
 	return s.eventRepo.GetByID(id)
@@ -228,6 +433,113 @@ func (s *VerificationEventService) UpdateVerificationResult(
 	return s.eventRepo.UpdateResult(id, result, reason, metadata)
 }
 
+// BulkVerificationDecisionRequest applies one approve/deny decision to several pending verifications
+type BulkVerificationDecisionRequest struct {
+	EventIDs []uuid.UUID               `json:"event_ids"`
+	Result   domain.VerificationResult `json:"result"` // verified or denied
+	Reason   string                    `json:"reason"` // Required when denying
+}
+
+// BulkVerificationDecisionItem reports the outcome for one verification of a bulk decision
+type BulkVerificationDecisionItem struct {
+	EventID uuid.UUID `json:"event_id"`
+	Applied bool      `json:"applied"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// BulkVerificationDecisionResult is the outcome of a bulk decision. Decisions are all-or-nothing:
+// when any item fails, Applied is false, no verification is changed and the failing items carry an error.
+type BulkVerificationDecisionResult struct {
+	Result  domain.VerificationResult      `json:"result"`
+	Applied bool                           `json:"applied"`
+	Items   []BulkVerificationDecisionItem `json:"items"`
+}
+
+// BulkDecideVerifications approves or denies several pending verifications of the organization
+// in one transaction. Every event is checked first; if any is missing, belongs to another
+// organization or was already decided, nothing is applied and the per-item errors are returned.
+func (s *VerificationEventService) BulkDecideVerifications(
+	ctx context.Context,
+	orgID, userID uuid.UUID,
+	userName string,
+	req *BulkVerificationDecisionRequest,
+) (*BulkVerificationDecisionResult, error) {
+	if req.Result != domain.VerificationResultVerified && req.Result != domain.VerificationResultDenied {
+		return nil, fmt.Errorf("%w: result must be %q or %q", ErrInvalidBulkDecision, domain.VerificationResultVerified, domain.VerificationResultDenied)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if req.Result == domain.VerificationResultDenied && reason == "" {
+		return nil, fmt.Errorf("%w: reason is required when denying verifications", ErrInvalidBulkDecision)
+	}
+	if len(req.EventIDs) == 0 {
+		return nil, fmt.Errorf("%w: event_ids is required", ErrInvalidBulkDecision)
+	}
+	if len(req.EventIDs) > MaxBulkVerificationDecisions {
+		return nil, fmt.Errorf("%w: at most %d verifications per request", ErrInvalidBulkDecision, MaxBulkVerificationDecisions)
+	}
+
+	result := &BulkVerificationDecisionResult{Result: req.Result}
+	seen := make(map[uuid.UUID]bool, len(req.EventIDs))
+	var ids []uuid.UUID
+	failed := false
+	for _, id := range req.EventIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		item := BulkVerificationDecisionItem{EventID: id}
+		event, err := s.eventRepo.GetByID(id)
+		switch {
+		case err != nil || event.OrganizationID != orgID:
+			item.Error = "verification not found"
+		case event.Status != domain.VerificationEventStatusPending:
+			item.Error = fmt.Sprintf("verification is not pending (status: %s)", event.Status)
+		}
+		if item.Error != "" {
+			failed = true
+		}
+		result.Items = append(result.Items, item)
+		ids = append(ids, id)
+	}
+	if failed {
+		return result, nil
+	}
+
+	// Same metadata the single approve/deny endpoints record
+	now := time.Now().Format(time.RFC3339)
+	var reasonPtr *string
+	metadata := map[string]interface{}{"bulk_decision": true}
+	if req.Result == domain.VerificationResultVerified {
+		metadata["approved_by"] = userName
+		metadata["approved_by_id"] = userID.String()
+		metadata["approved_at"] = now
+		metadata["approval_reason"] = reason
+		metadata["manual_approval"] = true
+	} else {
+		reasonPtr = &reason
+		metadata["denied_by"] = userName
+		metadata["denied_by_id"] = userID.String()
+		metadata["denied_at"] = now
+		metadata["denial_reason"] = reason
+		metadata["manual_denial"] = true
+	}
+
+	if err := s.eventRepo.UpdatePendingResults(ids, req.Result, reasonPtr, metadata); err != nil {
+		// A verification was decided concurrently; the transaction was rolled back
+		for i := range result.Items {
+			result.Items[i].Error = err.Error()
+		}
+		return result, nil
+	}
+
+	result.Applied = true
+	for i := range result.Items {
+		result.Items[i].Applied = true
+	}
+	return result, nil
+}
+
 // DeleteVerificationEvent deletes a verification event
 func (s *VerificationEventService) DeleteVerificationEvent(ctx context.Context, id uuid.UUID) error {
 	return s.eventRepo.Delete(id)
@@ -273,10 +585,12 @@ type CreateVerificationEventRequest struct {
 	Location         *string
 	StartedAt        time.Time
 	CompletedAt      *time.Time
+	RecordedAt       time.Time // Zero records the event now; set when replaying past events
 	Details          *string
 	Metadata         map[string]interface{}
 
 	// Configuration Drift Detection (WHO and WHAT)
 	CurrentMCPServers   []string // Runtime: MCP servers being communicated with
+	CurrentMCPTools     []string // Runtime: MCP tools being called, as server:tool
 	CurrentCapabilities []string // Runtime: Capabilities being used
 }
@@ -0,0 +1,80 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkVerificationDecisionIsAllOrNothing(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	other := testsupport.NewOrganization()
+	admin := testsupport.NewUser(org.ID)
+	agent := testsupport.NewAgent(org.ID)
+	outsider := testsupport.NewAgent(other.ID)
+	ctx := context.Background()
+	service := NewVerificationEventService(repos.VerificationEvent, repos.Agent, nil, nil, nil, nil)
+
+	pending := func(agent *domain.Agent) *domain.VerificationEvent {
+		event := testsupport.NewVerificationEvent(agent, func(e *domain.VerificationEvent) {
+			e.Status = domain.VerificationEventStatusPending
+			e.Result = nil
+			e.CompletedAt = nil
+		})
+		require.NoError(t, repos.VerificationEvent.Create(event))
+		return event
+	}
+	first, second := pending(agent), pending(agent)
+	foreign := pending(outsider)
+	decided := testsupport.NewVerificationEvent(agent)
+	require.NoError(t, repos.VerificationEvent.Create(decided))
+
+	_, err := service.BulkDecideVerifications(ctx, org.ID, admin.ID, admin.Name, &BulkVerificationDecisionRequest{
+		EventIDs: []uuid.UUID{first.ID},
+		Result:   domain.VerificationResultDenied,
+	})
+	assert.ErrorIs(t, err, ErrInvalidBulkDecision, "denials need a reason")
+
+	// One bad item blocks the whole batch and is reported individually
+	result, err := service.BulkDecideVerifications(ctx, org.ID, admin.ID, admin.Name, &BulkVerificationDecisionRequest{
+		EventIDs: []uuid.UUID{first.ID, foreign.ID, decided.ID, second.ID},
+		Result:   domain.VerificationResultDenied,
+		Reason:   "unexpected production writes",
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Applied)
+	require.Len(t, result.Items, 4)
+	assert.Empty(t, result.Items[0].Error)
+	assert.Equal(t, "verification not found", result.Items[1].Error)
+	assert.Contains(t, result.Items[2].Error, "not pending")
+	stored, err := repos.VerificationEvent.GetByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.VerificationEventStatusPending, stored.Status)
+
+	result, err = service.BulkDecideVerifications(ctx, org.ID, admin.ID, admin.Name, &BulkVerificationDecisionRequest{
+		EventIDs: []uuid.UUID{first.ID, second.ID, first.ID},
+		Result:   domain.VerificationResultDenied,
+		Reason:   "unexpected production writes",
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Applied)
+	require.Len(t, result.Items, 2, "duplicates are decided once")
+	for _, id := range []uuid.UUID{first.ID, second.ID} {
+		stored, err := repos.VerificationEvent.GetByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.VerificationEventStatusFailed, stored.Status)
+		require.NotNil(t, stored.ErrorReason)
+		assert.Equal(t, "unexpected production writes", *stored.ErrorReason)
+		assert.Equal(t, true, stored.Metadata["bulk_decision"])
+	}
+
+	stored, err = repos.VerificationEvent.GetByID(foreign.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.VerificationEventStatusPending, stored.Status)
+}
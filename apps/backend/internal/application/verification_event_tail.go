@@ -0,0 +1,107 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	defaultTailBatchSize = 100
+	maxTailBatchSize     = 500
+)
+
+// ErrInvalidResumeToken is returned when a live tail resume token cannot be decoded
+var ErrInvalidResumeToken = errors.New("invalid resume token")
+
+// VerificationTailCursor is the position of a live tail: the last emitted event
+type VerificationTailCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// VerificationTailCursorOf is the position right after the event
+func VerificationTailCursorOf(event *domain.VerificationEvent) VerificationTailCursor {
+	return VerificationTailCursor{CreatedAt: event.CreatedAt, ID: event.ID}
+}
+
+// NewVerificationTailCursor starts a live tail at the given time
+func NewVerificationTailCursor(from time.Time) VerificationTailCursor {
+	return VerificationTailCursor{CreatedAt: from}
+}
+
+// After reports whether the cursor is past the other one
+func (c VerificationTailCursor) After(other VerificationTailCursor) bool {
+	if !c.CreatedAt.Equal(other.CreatedAt) {
+		return c.CreatedAt.After(other.CreatedAt)
+	}
+	return c.ID.String() > other.ID.String()
+}
+
+// Token encodes the cursor as an opaque resume token
+func (c VerificationTailCursor) Token() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + "." + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseVerificationTailToken decodes a resume token returned by the live tail
+func ParseVerificationTailToken(token string) (VerificationTailCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return VerificationTailCursor{}, ErrInvalidResumeToken
+	}
+	nanos, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return VerificationTailCursor{}, ErrInvalidResumeToken
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return VerificationTailCursor{}, ErrInvalidResumeToken
+	}
+	eventID, err := uuid.Parse(id)
+	if err != nil {
+		return VerificationTailCursor{}, ErrInvalidResumeToken
+	}
+	return VerificationTailCursor{CreatedAt: time.Unix(0, unixNano), ID: eventID}, nil
+}
+
+// TailVerificationEvents returns the next recorded events after the cursor that match the filter,
+// oldest first, with the cursor to continue from. The live tail reads them before it follows new
+// events on the broker.
+func (s *VerificationEventService) TailVerificationEvents(
+	ctx context.Context,
+	orgID uuid.UUID,
+	cursor VerificationTailCursor,
+	filter domain.VerificationEventFilter,
+	limit int,
+) ([]*domain.VerificationEvent, VerificationTailCursor, error) {
+	if limit <= 0 {
+		limit = defaultTailBatchSize
+	}
+	if limit > maxTailBatchSize {
+		limit = maxTailBatchSize
+	}
+
+	query := domain.VerificationEventTailQuery{
+		AfterCreatedAt:          cursor.CreatedAt,
+		AfterID:                 cursor.ID,
+		VerificationEventFilter: filter,
+		Limit:                   limit,
+	}
+
+	events, err := s.eventRepo.ListAfter(orgID, query)
+	if err != nil {
+		return nil, cursor, fmt.Errorf("failed to tail verification events: %w", err)
+	}
+	if len(events) > 0 {
+		cursor = VerificationTailCursorOf(events[len(events)-1])
+	}
+	return events, cursor, nil
+}
@@ -0,0 +1,70 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationEventTailResumesWithoutGapsOrDuplicates(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	other := testsupport.NewOrganization()
+	agent := testsupport.NewAgent(org.ID)
+	canary := testsupport.NewAgent(org.ID)
+	ctx := context.Background()
+	service := NewVerificationEventService(repos.VerificationEvent, repos.Agent, nil, nil, nil, nil)
+
+	start := time.Now().Add(-time.Minute)
+	record := func(agent *domain.Agent, at time.Time, status domain.VerificationEventStatus) *domain.VerificationEvent {
+		event := testsupport.NewVerificationEvent(agent, func(e *domain.VerificationEvent) {
+			e.CreatedAt = at
+			e.Status = status
+		})
+		require.NoError(t, repos.VerificationEvent.Create(event))
+		return event
+	}
+	first := record(canary, start.Add(time.Second), domain.VerificationEventStatusFailed)
+	record(agent, start.Add(2*time.Second), domain.VerificationEventStatusFailed)
+	second := record(canary, start.Add(3*time.Second), domain.VerificationEventStatusSuccess)
+	third := record(canary, start.Add(3*time.Second), domain.VerificationEventStatusFailed) // Same timestamp
+	record(testsupport.NewAgent(other.ID), start.Add(4*time.Second), domain.VerificationEventStatusFailed)
+
+	filter := domain.VerificationEventFilter{AgentID: &canary.ID}
+	cursor := NewVerificationTailCursor(start)
+	var seen []uuid.UUID
+	for {
+		events, next, err := service.TailVerificationEvents(ctx, org.ID, cursor, filter, 1)
+		require.NoError(t, err)
+		if len(events) == 0 {
+			assert.Equal(t, cursor, next)
+			break
+		}
+		seen = append(seen, events[0].ID)
+
+		// Every batch resumes from its token, as a reconnecting client would
+		cursor, err = ParseVerificationTailToken(next.Token())
+		require.NoError(t, err)
+	}
+	expected := []uuid.UUID{first.ID, second.ID, third.ID}
+	if third.ID.String() < second.ID.String() {
+		expected = []uuid.UUID{first.ID, third.ID, second.ID}
+	}
+	assert.Equal(t, expected, seen)
+
+	filter.Statuses = []domain.VerificationEventStatus{domain.VerificationEventStatusFailed}
+	events, _, err := service.TailVerificationEvents(ctx, org.ID, NewVerificationTailCursor(start), filter, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, first.ID, events[0].ID)
+	assert.Equal(t, third.ID, events[1].ID)
+
+	_, err = ParseVerificationTailToken("not-a-token")
+	assert.ErrorIs(t, err, ErrInvalidResumeToken)
+}
@@ -0,0 +1,314 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// verificationExportBatchSize is the number of events read and written at a time
+	verificationExportBatchSize = 5000
+	// verificationExportClaimLimit is the number of exports processed per run of the job
+	verificationExportClaimLimit = 5
+	// verificationExportStaleAfter is when a running export whose worker stopped is picked up again
+	verificationExportStaleAfter = 30 * time.Minute
+	// verificationExportTTL is how long a finished export can be downloaded
+	verificationExportTTL = 7 * 24 * time.Hour
+	// maxVerificationExportRange caps the period of one export
+	maxVerificationExportRange = 366 * 24 * time.Hour
+	// verificationExportHistoryLimit is the number of exports listed per organization
+	verificationExportHistoryLimit = 50
+)
+
+var (
+	// ErrInvalidVerificationExportRequest is returned for an unknown format, filter or date range
+	ErrInvalidVerificationExportRequest = errors.New("invalid verification export request")
+	// ErrVerificationExportNotReady is returned when downloading an export that has not completed
+	ErrVerificationExportNotReady = errors.New("verification export is not ready")
+	// ErrVerificationExportExpired is returned when downloading an export past its expiry
+	ErrVerificationExportExpired = errors.New("verification export has expired")
+)
+
+// VerificationExportService exports verification events to files in the background. A request
+// is queued; the verification export job pages through the matching events, writes them to a
+// CSV or Parquet file in object storage and emails the requester when it can be downloaded.
+type VerificationExportService struct {
+	exportRepo   domain.VerificationExportRepository
+	eventRepo    domain.VerificationEventRepository
+	userRepo     domain.UserRepository
+	artifacts    domain.ArtifactStore
+	emailService domain.EmailService // Optional; requesters are not emailed without it
+	encoders     map[domain.VerificationExportFormat]domain.VerificationExportEncoder
+	publicURL    string // Base URL used to build download links
+	now          func() time.Time
+}
+
+// NewVerificationExportService creates a new verification export service
+func NewVerificationExportService(
+	exportRepo domain.VerificationExportRepository,
+	eventRepo domain.VerificationEventRepository,
+	userRepo domain.UserRepository,
+	artifacts domain.ArtifactStore,
+	emailService domain.EmailService,
+	publicURL string,
+	encoders ...domain.VerificationExportEncoder,
+) *VerificationExportService {
+	s := &VerificationExportService{
+		exportRepo:   exportRepo,
+		eventRepo:    eventRepo,
+		userRepo:     userRepo,
+		artifacts:    artifacts,
+		emailService: emailService,
+		encoders:     make(map[domain.VerificationExportFormat]domain.VerificationExportEncoder),
+		publicURL:    strings.TrimRight(publicURL, "/"),
+		now:          time.Now,
+	}
+	for _, encoder := range encoders {
+		s.encoders[encoder.Format()] = encoder
+	}
+	return s
+}
+
+// VerificationExportRequest selects the events to export. Events created in [Start, End) are
+// exported; the other filters are optional. Format defaults to CSV.
+type VerificationExportRequest struct {
+	Format           domain.VerificationExportFormat  `json:"format"`
+	Start            time.Time                        `json:"start"`
+	End              time.Time                        `json:"end"`
+	AgentID          *uuid.UUID                       `json:"agentId,omitempty"`
+	MCPServerID      *uuid.UUID                       `json:"mcpServerId,omitempty"`
+	Statuses         []domain.VerificationEventStatus `json:"statuses,omitempty"`
+	Protocol         domain.VerificationProtocol      `json:"protocol,omitempty"`
+	VerificationType domain.VerificationType          `json:"verificationType,omitempty"`
+	DriftOnly        bool                             `json:"driftOnly,omitempty"`
+}
+
+// RequestExport queues an export for the verification export job
+func (s *VerificationExportService) RequestExport(ctx context.Context, orgID, userID uuid.UUID, req *VerificationExportRequest) (*domain.VerificationExport, error) {
+	format := req.Format
+	if format == "" {
+		format = domain.VerificationExportCSV
+	}
+	if _, ok := s.encoders[format]; !ok {
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidVerificationExportRequest, format)
+	}
+	if req.Start.IsZero() || req.End.IsZero() || !req.Start.Before(req.End) {
+		return nil, fmt.Errorf("%w: start must be before end", ErrInvalidVerificationExportRequest)
+	}
+	if req.End.Sub(req.Start) > maxVerificationExportRange {
+		return nil, fmt.Errorf("%w: the date range may span at most %d days", ErrInvalidVerificationExportRequest, int(maxVerificationExportRange.Hours()/24))
+	}
+	for _, status := range req.Statuses {
+		switch status {
+		case domain.VerificationEventStatusSuccess, domain.VerificationEventStatusFailed,
+			domain.VerificationEventStatusPending, domain.VerificationEventStatusTimeout:
+		default:
+			return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidVerificationExportRequest, status)
+		}
+	}
+
+	export := &domain.VerificationExport{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		RequestedBy:    userID,
+		Format:         format,
+		Filter: domain.VerificationExportFilter{
+			Start:            req.Start.UTC(),
+			End:              req.End.UTC(),
+			AgentID:          req.AgentID,
+			MCPServerID:      req.MCPServerID,
+			Statuses:         req.Statuses,
+			Protocol:         req.Protocol,
+			VerificationType: req.VerificationType,
+			DriftOnly:        req.DriftOnly,
+		},
+		Status:    domain.VerificationExportPending,
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.exportRepo.Create(export); err != nil {
+		return nil, fmt.Errorf("failed to create verification export: %w", err)
+	}
+	return export, nil
+}
+
+// ListExports lists the organization's most recent exports
+func (s *VerificationExportService) ListExports(ctx context.Context, orgID uuid.UUID) ([]*domain.VerificationExport, error) {
+	return s.exportRepo.ListByOrganization(orgID, verificationExportHistoryLimit)
+}
+
+// GetExport retrieves an export of the organization
+func (s *VerificationExportService) GetExport(ctx context.Context, orgID, id uuid.UUID) (*domain.VerificationExport, error) {
+	export, err := s.exportRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if export.OrganizationID != orgID {
+		return nil, domain.ErrVerificationExportNotFound
+	}
+	return export, nil
+}
+
+// GetExportContent returns the file of a completed export
+func (s *VerificationExportService) GetExportContent(ctx context.Context, export *domain.VerificationExport) ([]byte, error) {
+	if export.Status != domain.VerificationExportCompleted {
+		return nil, ErrVerificationExportNotReady
+	}
+	if export.ExpiresAt != nil && s.now().After(*export.ExpiresAt) {
+		return nil, ErrVerificationExportExpired
+	}
+	artifact, err := s.artifacts.Get(ctx, export.OrganizationID, domain.ArtifactExport, export.ArtifactName)
+	if errors.Is(err, domain.ErrArtifactNotFound) {
+		return nil, ErrVerificationExportExpired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export file: %w", err)
+	}
+	return artifact.Data, nil
+}
+
+// ProcessPendingExports writes the files of queued exports and emails their requesters.
+// Returns the number of exports processed.
+func (s *VerificationExportService) ProcessPendingExports(ctx context.Context) (int, error) {
+	exports, err := s.exportRepo.ClaimPending(verificationExportClaimLimit, s.now().Add(-verificationExportStaleAfter))
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim verification exports: %w", err)
+	}
+
+	for i, export := range exports {
+		runErr := s.run(ctx, export)
+		if ctx.Err() != nil {
+			// Interrupted exports are picked up again once they are stale
+			return i, ctx.Err()
+		}
+
+		completedAt := s.now().UTC()
+		export.CompletedAt = &completedAt
+		if runErr != nil {
+			log.Printf("⚠️  Verification export %s failed: %v", export.ID, runErr)
+			msg := runErr.Error()
+			export.Status = domain.VerificationExportFailed
+			export.Error = &msg
+		} else {
+			expiresAt := completedAt.Add(verificationExportTTL)
+			export.Status = domain.VerificationExportCompleted
+			export.Error = nil
+			export.ExpiresAt = &expiresAt
+		}
+		if err := s.exportRepo.Finish(export); err != nil {
+			log.Printf("⚠️  Failed to record verification export %s: %v", export.ID, err)
+			continue
+		}
+		s.notifyRequester(export)
+	}
+	return len(exports), nil
+}
+
+// run writes the export file a batch of events at a time and stores it in object storage
+func (s *VerificationExportService) run(ctx context.Context, export *domain.VerificationExport) error {
+	encoder, ok := s.encoders[export.Format]
+	if !ok {
+		return fmt.Errorf("unsupported format: %s", export.Format)
+	}
+
+	filter := export.Filter
+	query := domain.VerificationEventTailQuery{
+		AfterCreatedAt: filter.Start,
+		Before:         filter.End,
+		VerificationEventFilter: domain.VerificationEventFilter{
+			AgentID:          filter.AgentID,
+			MCPServerID:      filter.MCPServerID,
+			Statuses:         filter.Statuses,
+			Protocol:         filter.Protocol,
+			VerificationType: filter.VerificationType,
+			DriftOnly:        filter.DriftOnly,
+		},
+		Limit: verificationExportBatchSize,
+	}
+
+	var file bytes.Buffer
+	writer := encoder.NewWriter(&file)
+	rows := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		events, err := s.eventRepo.ListAfter(export.OrganizationID, query)
+		if err != nil {
+			return fmt.Errorf("failed to load verification events: %w", err)
+		}
+		if len(events) == 0 {
+			break
+		}
+		if err := writer.Write(events); err != nil {
+			return fmt.Errorf("failed to write export file: %w", err)
+		}
+		rows += len(events)
+		last := events[len(events)-1]
+		query.AfterCreatedAt, query.AfterID = last.CreatedAt, last.ID
+		if len(events) < verificationExportBatchSize {
+			break
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	name := fmt.Sprintf("verification-events/%s.%s", export.ID, export.Format)
+	if _, err := s.artifacts.Put(ctx, export.OrganizationID, domain.ArtifactExport, name, file.Bytes(), encoder.ContentType()); err != nil {
+		return fmt.Errorf("failed to store export file: %w", err)
+	}
+
+	export.RowCount = rows
+	export.Size = int64(file.Len())
+	export.ArtifactName = name
+	export.ContentType = encoder.ContentType()
+	export.Filename = fmt.Sprintf("verification-events-%s-%s.%s",
+		filter.Start.Format("20060102"), filter.End.Format("20060102"), export.Format)
+	return nil
+}
+
+// notifyRequester emails the requester that the export can be downloaded, or why it failed
+func (s *VerificationExportService) notifyRequester(export *domain.VerificationExport) {
+	if s.emailService == nil || s.userRepo == nil {
+		return
+	}
+	requester, err := s.userRepo.GetByID(export.RequestedBy)
+	if err != nil || requester.Email == "" {
+		return
+	}
+
+	period := fmt.Sprintf("%s to %s", export.Filter.Start.Format(time.RFC3339), export.Filter.End.Format(time.RFC3339))
+	var subject, body string
+	if export.Status == domain.VerificationExportCompleted {
+		downloadURL := fmt.Sprintf("%s/api/v1/verifications/exports/%s/download", s.publicURL, export.ID)
+		subject = "[AIM] Your verification event export is ready"
+		body = fmt.Sprintf(
+			"<h2>Verification event export ready</h2><p>%d verification events from %s were exported to %s.</p><p><a href=\"%s\">Download %s</a> (sign-in required). The file is available until %s.</p>",
+			export.RowCount,
+			html.EscapeString(period),
+			strings.ToUpper(string(export.Format)),
+			html.EscapeString(downloadURL),
+			html.EscapeString(export.Filename),
+			export.ExpiresAt.Format(time.RFC3339),
+		)
+	} else {
+		subject = "[AIM] Your verification event export failed"
+		body = fmt.Sprintf(
+			"<h2>Verification event export failed</h2><p>The export of verification events from %s could not be completed: %s</p><p>Request the export again.</p>",
+			html.EscapeString(period),
+			html.EscapeString(*export.Error),
+		)
+	}
+	if err := organizationEmail(s.emailService, export.OrganizationID).SendEmail(requester.Email, subject, body, true); err != nil {
+		log.Printf("⚠️  Failed to email %s about verification export %s: %v", requester.Email, export.ID, err)
+	}
+}
@@ -0,0 +1,200 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/email"
+	"github.com/opena2a/identity/backend/internal/infrastructure/export"
+	"github.com/opena2a/identity/backend/internal/infrastructure/storage"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedEmail struct {
+	to, subject, body string
+}
+
+// recordingEmailService records the emails it is asked to send; only SendEmail is implemented
+type recordingEmailService struct {
+	domain.EmailService
+	mu     sync.Mutex
+	emails []recordedEmail
+}
+
+func (r *recordingEmailService) SendEmail(to, subject, body string, isHTML bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emails = append(r.emails, recordedEmail{to: to, subject: subject, body: body})
+	return nil
+}
+
+// SendTemplatedEmail renders the template with the embedded templates and records the email
+func (r *recordingEmailService) SendTemplatedEmail(template domain.EmailTemplate, to string, data interface{}) error {
+	renderer, err := email.NewTemplateRenderer("")
+	if err != nil {
+		return err
+	}
+	subject, body, err := renderer.Render(template, data)
+	if err != nil {
+		return err
+	}
+	return r.SendEmail(to, subject, body, true)
+}
+
+func TestVerificationEventExportsAreWrittenAsynchronouslyAndRequesterIsEmailed(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	user := testsupport.NewUser(org.ID)
+	require.NoError(t, repos.User.Create(user))
+	agent := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(agent))
+	other := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(other))
+	ctx := context.Background()
+
+	start := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Hour)
+	end := start.Add(24 * time.Hour)
+	var inRange []uuid.UUID
+	for i, at := range []time.Time{start.Add(-time.Minute), start, start.Add(time.Hour), end.Add(-time.Second), end} {
+		event := testsupport.NewVerificationEvent(agent, func(e *domain.VerificationEvent) {
+			e.CreatedAt = at
+			e.DriftDetected = i == 2
+			e.MCPServerDrift = []string{"unexpected-server"}
+		})
+		require.NoError(t, repos.VerificationEvent.Create(event))
+		if !at.Before(start) && at.Before(end) {
+			inRange = append(inRange, event.ID)
+		}
+	}
+	require.NoError(t, repos.VerificationEvent.Create(testsupport.NewVerificationEvent(other, func(e *domain.VerificationEvent) {
+		e.CreatedAt = start.Add(2 * time.Hour)
+	})))
+
+	artifacts, err := storage.NewArtifactStore(storage.Config{Provider: storage.ProviderLocal, Bucket: "artifacts", LocalRoot: t.TempDir()})
+	require.NoError(t, err)
+	emails := &recordingEmailService{}
+	service := NewVerificationExportService(
+		repos.VerificationExport, repos.VerificationEvent, repos.User, artifacts, emails, "https://aim.example.com/",
+		export.NewCSVEncoder(), export.NewParquetEncoder(),
+	)
+
+	_, err = service.RequestExport(ctx, org.ID, user.ID, &VerificationExportRequest{Start: end, End: start})
+	assert.ErrorIs(t, err, ErrInvalidVerificationExportRequest)
+	_, err = service.RequestExport(ctx, org.ID, user.ID, &VerificationExportRequest{Format: "xlsx", Start: start, End: end})
+	assert.ErrorIs(t, err, ErrInvalidVerificationExportRequest)
+
+	csvExport, err := service.RequestExport(ctx, org.ID, user.ID, &VerificationExportRequest{
+		Start: start, End: end, AgentID: &agent.ID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.VerificationExportCSV, csvExport.Format)
+	assert.Equal(t, domain.VerificationExportPending, csvExport.Status)
+	_, err = service.GetExportContent(ctx, csvExport)
+	assert.ErrorIs(t, err, ErrVerificationExportNotReady)
+
+	parquetExport, err := service.RequestExport(ctx, org.ID, user.ID, &VerificationExportRequest{
+		Format: domain.VerificationExportParquet, Start: start, End: end, DriftOnly: true,
+	})
+	require.NoError(t, err)
+
+	processed, err := service.ProcessPendingExports(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	processed, err = service.ProcessPendingExports(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+
+	csvExport, err = service.GetExport(ctx, org.ID, csvExport.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.VerificationExportCompleted, csvExport.Status)
+	assert.Equal(t, len(inRange), csvExport.RowCount)
+	require.NotNil(t, csvExport.ExpiresAt)
+	content, err := service.GetExportContent(ctx, csvExport)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), csvExport.Size)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, len(inRange)+1)
+	assert.True(t, strings.HasPrefix(lines[0], "id,created_at,started_at,completed_at,agent_id,agent_name"))
+	for i, id := range inRange {
+		assert.True(t, strings.HasPrefix(lines[i+1], id.String()+","), "events are exported oldest first")
+	}
+	assert.Contains(t, string(content), `"[""unexpected-server""]"`)
+
+	parquetExport, err = service.GetExport(ctx, org.ID, parquetExport.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.VerificationExportCompleted, parquetExport.Status)
+	assert.Equal(t, 1, parquetExport.RowCount)
+	assert.Equal(t, "application/vnd.apache.parquet", parquetExport.ContentType)
+	content, err = service.GetExportContent(ctx, parquetExport)
+	require.NoError(t, err)
+	assert.Equal(t, parquetExport.Size, int64(len(content)))
+
+	// A Parquet reader opens the file with the columns in CSV order and the event's values
+	file, err := parquet.OpenFile(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	var names []string
+	for _, field := range file.Schema().Fields() {
+		names = append(names, field.Name())
+	}
+	assert.Equal(t, strings.Split(lines[0], ","), names)
+	createdAt, ok := file.Schema().Lookup("created_at")
+	require.True(t, ok)
+	assert.Equal(t, parquet.Int64, createdAt.Node.Type().Kind())
+	assert.NotNil(t, createdAt.Node.Type().LogicalType().Timestamp)
+	completedAt, ok := file.Schema().Lookup("completed_at")
+	require.True(t, ok)
+	assert.True(t, completedAt.Node.Optional())
+	assert.Equal(t, int64(1), file.NumRows())
+
+	type exportedEvent struct {
+		ID             string  `parquet:"id"`
+		CreatedAt      int64   `parquet:"created_at"` // Milliseconds since the epoch
+		CompletedAt    *int64  `parquet:"completed_at,optional"`
+		AgentName      *string `parquet:"agent_name,optional"`
+		MCPServerName  *string `parquet:"mcp_server_name,optional"`
+		Confidence     float64 `parquet:"confidence"`
+		DurationMs     int64   `parquet:"duration_ms"`
+		DriftDetected  bool    `parquet:"drift_detected"`
+		MCPServerDrift *string `parquet:"mcp_server_drift,optional"`
+	}
+	rows, err := parquet.Read[exportedEvent](bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	drifted, err := repos.VerificationEvent.GetByID(inRange[1])
+	require.NoError(t, err)
+	row := rows[0]
+	assert.Equal(t, drifted.ID.String(), row.ID)
+	assert.Equal(t, drifted.CreatedAt.UnixMilli(), row.CreatedAt)
+	require.NotNil(t, row.CompletedAt)
+	assert.Equal(t, drifted.CompletedAt.UnixMilli(), *row.CompletedAt)
+	require.NotNil(t, row.AgentName)
+	assert.Equal(t, agent.Name, *row.AgentName)
+	assert.Nil(t, row.MCPServerName, "missing values are null")
+	assert.Equal(t, drifted.Confidence, row.Confidence)
+	assert.Equal(t, int64(drifted.DurationMs), row.DurationMs)
+	assert.True(t, row.DriftDetected)
+	require.NotNil(t, row.MCPServerDrift)
+	assert.JSONEq(t, `["unexpected-server"]`, *row.MCPServerDrift)
+
+	_, err = service.GetExport(ctx, uuid.New(), csvExport.ID)
+	assert.ErrorIs(t, err, domain.ErrVerificationExportNotFound)
+	exports, err := service.ListExports(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Len(t, exports, 2)
+
+	require.Len(t, emails.emails, 2)
+	for _, email := range emails.emails {
+		assert.Equal(t, user.Email, email.to)
+		assert.Contains(t, email.subject, "ready")
+	}
+	assert.Contains(t, emails.emails[0].body, "https://aim.example.com/api/v1/verifications/exports/"+csvExport.ID.String()+"/download")
+}
@@ -0,0 +1,120 @@
+package application
+
+import (
+	"strings"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// verificationRiskLevels orders risk levels; an event's points pick the level, from low (0 points)
+// to critical (5 or more)
+var verificationRiskLevels = []struct {
+	level     domain.VerificationRiskLevel
+	minPoints int
+}{
+	{domain.VerificationRiskCritical, 5},
+	{domain.VerificationRiskHigh, 3},
+	{domain.VerificationRiskMedium, 1},
+	{domain.VerificationRiskLow, 0},
+}
+
+// VerificationRiskContext is what is known about the agent beyond the event itself
+type VerificationRiskContext struct {
+	AgentCompromised bool
+	// Anomalies recorded on the agent in the last day, and whether one was high or critical
+	RecentAnomalies     int
+	SevereRecentAnomaly bool
+}
+
+// ClassifyVerificationRisk assesses a verification event from its confidence, drift and the trust
+// score it was recorded with, plus the agent's recent anomalies. Each signal adds points:
+//   - trust score below 0.7, 0.5 or 0.3: 1, 2 or 3
+//   - confidence below 0.8 or 0.5: 1 or 2 (zero confidence means it was not measured)
+//   - drift: 2, or 3 when more than one kind (MCP server, MCP tool, capability, peer agent) drifted
+//   - a failed verification: 1
+//   - recent anomalies: 1, or 2 when one of them was high or critical
+//
+// Compromised agents are always critical. A level the caller declared in the metadata's
+// risk_level (or context.risk_level) is a floor: signals can raise it but never lower it.
+func ClassifyVerificationRisk(event *domain.VerificationEvent, riskCtx VerificationRiskContext) domain.VerificationRiskLevel {
+	if riskCtx.AgentCompromised {
+		return domain.VerificationRiskCritical
+	}
+
+	points := 0
+	switch {
+	case event.TrustScore < 0.3:
+		points += 3
+	case event.TrustScore < 0.5:
+		points += 2
+	case event.TrustScore < 0.7:
+		points++
+	}
+	switch {
+	case event.Confidence <= 0:
+	case event.Confidence < 0.5:
+		points += 2
+	case event.Confidence < 0.8:
+		points++
+	}
+
+	drifted := 0
+	for _, drift := range [][]string{event.MCPServerDrift, event.MCPToolDrift, event.CapabilityDrift, event.PeerAgentDrift} {
+		if len(drift) > 0 {
+			drifted++
+		}
+	}
+	switch {
+	case drifted > 1:
+		points += 3
+	case drifted == 1 || event.DriftDetected:
+		points += 2
+	}
+
+	if event.Status == domain.VerificationEventStatusFailed {
+		points++
+	}
+	switch {
+	case riskCtx.SevereRecentAnomaly:
+		points += 2
+	case riskCtx.RecentAnomalies > 0:
+		points++
+	}
+
+	level := domain.VerificationRiskLow
+	for _, candidate := range verificationRiskLevels {
+		if points >= candidate.minPoints {
+			level = candidate.level
+			break
+		}
+	}
+	if declared := declaredRiskLevel(event.Metadata); riskRank(declared) > riskRank(level) {
+		level = declared
+	}
+	return level
+}
+
+// declaredRiskLevel reads the risk level the caller reported in the event metadata or its nested
+// context, if it is a known level
+func declaredRiskLevel(metadata map[string]interface{}) domain.VerificationRiskLevel {
+	level, ok := metadata["risk_level"].(string)
+	if !ok {
+		if ctx, isMap := metadata["context"].(map[string]interface{}); isMap {
+			level, _ = ctx["risk_level"].(string)
+		}
+	}
+	if declared := domain.VerificationRiskLevel(strings.ToLower(level)); declared.IsValid() {
+		return declared
+	}
+	return ""
+}
+
+// riskRank orders risk levels from low (0) to critical (3); unknown levels rank below low
+func riskRank(level domain.VerificationRiskLevel) int {
+	for i, candidate := range verificationRiskLevels {
+		if candidate.level == level {
+			return len(verificationRiskLevels) - 1 - i
+		}
+	}
+	return -1
+}
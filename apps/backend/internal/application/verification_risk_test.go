@@ -0,0 +1,112 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyVerificationRisk(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    domain.VerificationEvent
+		riskCtx  VerificationRiskContext
+		expected domain.VerificationRiskLevel
+	}{
+		{"trusted and confident", domain.VerificationEvent{TrustScore: 0.9, Confidence: 1}, VerificationRiskContext{}, domain.VerificationRiskLow},
+		{"unmeasured confidence", domain.VerificationEvent{TrustScore: 0.9}, VerificationRiskContext{}, domain.VerificationRiskLow},
+		{"low confidence", domain.VerificationEvent{TrustScore: 0.9, Confidence: 0.6}, VerificationRiskContext{}, domain.VerificationRiskMedium},
+		{"drift", domain.VerificationEvent{TrustScore: 0.9, MCPServerDrift: []string{"unknown-mcp"}, DriftDetected: true}, VerificationRiskContext{}, domain.VerificationRiskMedium},
+		{"drift of an untrusted agent", domain.VerificationEvent{TrustScore: 0.6, MCPServerDrift: []string{"unknown-mcp"}, DriftDetected: true}, VerificationRiskContext{}, domain.VerificationRiskHigh},
+		{"failed with two kinds of drift", domain.VerificationEvent{
+			TrustScore:      0.8,
+			Status:          domain.VerificationEventStatusFailed,
+			CapabilityDrift: []string{"shell"},
+			PeerAgentDrift:  []string{"billing-agent"},
+			DriftDetected:   true,
+		}, VerificationRiskContext{}, domain.VerificationRiskHigh},
+		{"severe anomaly on a distrusted agent", domain.VerificationEvent{TrustScore: 0.2}, VerificationRiskContext{RecentAnomalies: 2, SevereRecentAnomaly: true}, domain.VerificationRiskCritical},
+		{"recent anomaly", domain.VerificationEvent{TrustScore: 0.9}, VerificationRiskContext{RecentAnomalies: 1}, domain.VerificationRiskMedium},
+		{"compromised agent", domain.VerificationEvent{TrustScore: 0.9}, VerificationRiskContext{AgentCompromised: true}, domain.VerificationRiskCritical},
+		{"declared level is a floor", domain.VerificationEvent{TrustScore: 0.9, Metadata: map[string]interface{}{
+			"context": map[string]interface{}{"risk_level": "HIGH"},
+		}}, VerificationRiskContext{}, domain.VerificationRiskHigh},
+		{"declared level never lowers", domain.VerificationEvent{TrustScore: 0.2, Metadata: map[string]interface{}{"risk_level": "low"}}, VerificationRiskContext{}, domain.VerificationRiskHigh},
+		{"unknown declared level", domain.VerificationEvent{TrustScore: 0.9, Metadata: map[string]interface{}{"risk_level": "extreme"}}, VerificationRiskContext{}, domain.VerificationRiskLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassifyVerificationRisk(&tt.event, tt.riskCtx))
+		})
+	}
+}
+
+func TestVerificationEventsRecordARiskLevelTheSearchFiltersOn(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	trusted := testsupport.NewAgent(org.ID, func(a *domain.Agent) {
+		a.TalksTo = []string{"filesystem-mcp"}
+	})
+	anomalous := testsupport.NewAgent(org.ID)
+	compromised := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.IsCompromised = true })
+	for _, agent := range []*domain.Agent{trusted, anomalous, compromised} {
+		require.NoError(t, repos.Agent.Create(agent))
+	}
+	require.NoError(t, repos.Security.CreateAnomaly(&domain.Anomaly{
+		ID:             uuid.New(),
+		OrganizationID: org.ID,
+		AnomalyType:    domain.AnomalyTypeAbnormalTraffic,
+		Severity:       domain.AlertSeverityHigh,
+		Title:          "Verification rate spike",
+		ResourceType:   "agent",
+		ResourceID:     anomalous.ID,
+		Confidence:     90,
+		CreatedAt:      time.Now(),
+	}))
+
+	driftService := NewDriftDetectionService(repos.Agent, repos.Alert)
+	service := NewVerificationEventService(repos.VerificationEvent, repos.Agent, driftService, nil, nil, repos.Security)
+	ctx := context.Background()
+	record := func(agent *domain.Agent, mcpServers []string, metadata map[string]interface{}) *domain.VerificationEvent {
+		event, err := service.CreateVerificationEvent(ctx, &CreateVerificationEventRequest{
+			OrganizationID:    org.ID,
+			AgentID:           agent.ID,
+			Protocol:          domain.VerificationProtocolMCP,
+			VerificationType:  domain.VerificationTypeIdentity,
+			Status:            domain.VerificationEventStatusSuccess,
+			Confidence:        1.0,
+			InitiatorType:     domain.InitiatorTypeAgent,
+			CurrentMCPServers: mcpServers,
+			Metadata:          metadata,
+		})
+		require.NoError(t, err)
+		return event
+	}
+
+	assert.Equal(t, domain.VerificationRiskLow, record(trusted, []string{"filesystem-mcp"}, nil).RiskLevel)
+	assert.Equal(t, domain.VerificationRiskMedium, record(trusted, []string{"unknown-mcp"}, nil).RiskLevel, "drift")
+	assert.Equal(t, domain.VerificationRiskHigh, record(trusted, nil, map[string]interface{}{"risk_level": "high"}).RiskLevel, "declared by the caller")
+	assert.Equal(t, domain.VerificationRiskMedium, record(anomalous, nil, nil).RiskLevel, "a high anomaly in the last day")
+	critical := record(compromised, nil, nil)
+	assert.Equal(t, domain.VerificationRiskCritical, critical.RiskLevel)
+
+	// The search filters on the recorded level, not on metadata
+	events, total, _, err := repos.VerificationEvent.SearchAdminVerifications(org.ID, domain.VerificationQueryParams{RiskLevel: "CRITICAL", Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	assert.Equal(t, critical.ID, events[0].ID)
+	_, total, _, err = repos.VerificationEvent.SearchAdminVerifications(org.ID, domain.VerificationQueryParams{RiskLevel: "medium", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	_, total, _, err = repos.VerificationEvent.SearchAdminVerifications(org.ID, domain.VerificationQueryParams{RiskLevel: "all", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+}
@@ -0,0 +1,333 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
+)
+
+var (
+	// ErrInvalidWebhookEgress is returned when webhook egress settings are invalid
+	ErrInvalidWebhookEgress = errors.New("invalid webhook egress settings")
+	// ErrWebhookMTLSNotConfigured is returned when a webhook requires mTLS but the organization has no client certificate
+	ErrWebhookMTLSNotConfigured = errors.New("webhook requires mTLS but no client certificate is configured")
+)
+
+// UpdateWebhookEgressRequest changes an organization's webhook egress settings. Omitted fields keep
+// their current value; an empty clientCertificate removes the certificate and its key.
+type UpdateWebhookEgressRequest struct {
+	ClientCertificate *string   `json:"clientCertificate,omitempty"` // PEM certificate chain, leaf first
+	ClientKey         *string   `json:"clientKey,omitempty"`         // PEM private key; required with a new certificate
+	ProxyURL          *string   `json:"proxyUrl,omitempty"`          // http, https or socks5 proxy; "" uses the shared egress
+	EgressIPs         *[]string `json:"egressIps,omitempty"`         // Source addresses or CIDR ranges of the proxy
+}
+
+// WebhookClientCertificate describes the client certificate presented to webhook receivers
+type WebhookClientCertificate struct {
+	Subject           string    `json:"subject"`
+	Issuer            string    `json:"issuer"`
+	NotBefore         time.Time `json:"notBefore"`
+	NotAfter          time.Time `json:"notAfter"`
+	FingerprintSHA256 string    `json:"fingerprintSha256"`
+	PEM               string    `json:"pem"`
+}
+
+// WebhookEgress is what receivers need to network-authenticate an organization's webhooks
+type WebhookEgress struct {
+	EgressIPs         []string                  `json:"egressIps"`          // Addresses to allowlist; empty when not published
+	Dedicated         bool                      `json:"dedicated"`          // Deliveries leave through the organization's own proxy
+	ProxyURL          string                    `json:"proxyUrl,omitempty"` // Password redacted
+	ClientCertificate *WebhookClientCertificate `json:"clientCertificate"`  // Nil when mTLS is not configured
+	UpdatedAt         *time.Time                `json:"updatedAt,omitempty"`
+}
+
+// webhookEgressClient is the HTTP client built from an organization's egress settings
+type webhookEgressClient struct {
+	updatedAt      time.Time
+	client         *http.Client
+	hasCertificate bool
+}
+
+// GetWebhookEgress returns the egress addresses and client certificate of the organization's webhooks
+func (s *WebhookService) GetWebhookEgress(ctx context.Context, orgID uuid.UUID) (*WebhookEgress, error) {
+	settings, err := s.webhookRepo.GetEgressSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return s.webhookEgress(settings)
+}
+
+// UpdateWebhookEgress validates and stores the organization's webhook egress settings. The
+// client key is encrypted with the key vault and never returned.
+func (s *WebhookService) UpdateWebhookEgress(ctx context.Context, orgID, userID uuid.UUID, req *UpdateWebhookEgressRequest) (*WebhookEgress, error) {
+	settings, err := s.webhookRepo.GetEgressSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &domain.WebhookEgressSettings{OrganizationID: orgID}
+	}
+
+	if req.ClientCertificate != nil {
+		certPEM := strings.TrimSpace(*req.ClientCertificate)
+		if certPEM == "" {
+			settings.ClientCertificate, settings.ClientKey = "", ""
+		} else {
+			if req.ClientKey == nil || strings.TrimSpace(*req.ClientKey) == "" {
+				return nil, fmt.Errorf("%w: clientKey is required with clientCertificate", ErrInvalidWebhookEgress)
+			}
+			if _, info, err := parseWebhookClientCertificate(certPEM, *req.ClientKey); err != nil {
+				return nil, err
+			} else if time.Now().After(info.NotAfter) {
+				return nil, fmt.Errorf("%w: client certificate expired at %s", ErrInvalidWebhookEgress, info.NotAfter.Format(time.RFC3339))
+			}
+			if s.keyVault == nil {
+				return nil, fmt.Errorf("%w: no key vault is available to store the client key", ErrInvalidWebhookEgress)
+			}
+			encryptedKey, err := s.keyVault.EncryptPrivateKey(*req.ClientKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt client key: %w", err)
+			}
+			settings.ClientCertificate, settings.ClientKey = certPEM, encryptedKey
+		}
+	} else if req.ClientKey != nil {
+		return nil, fmt.Errorf("%w: clientKey can only be changed together with clientCertificate", ErrInvalidWebhookEgress)
+	}
+
+	if req.ProxyURL != nil {
+		proxyURL := strings.TrimSpace(*req.ProxyURL)
+		if err := validateWebhookProxyURL(proxyURL); err != nil {
+			return nil, err
+		}
+		settings.ProxyURL = proxyURL
+	}
+
+	if req.EgressIPs != nil {
+		egressIPs := make([]string, 0, len(*req.EgressIPs))
+		for _, value := range *req.EgressIPs {
+			value = strings.TrimSpace(value)
+			if net.ParseIP(value) == nil {
+				if _, _, err := net.ParseCIDR(value); err != nil {
+					return nil, fmt.Errorf("%w: %q is not an IP address or CIDR range", ErrInvalidWebhookEgress, value)
+				}
+			}
+			egressIPs = append(egressIPs, value)
+		}
+		settings.EgressIPs = egressIPs
+	}
+
+	settings.UpdatedBy = &userID
+	if err := s.webhookRepo.UpsertEgressSettings(settings); err != nil {
+		return nil, fmt.Errorf("failed to store webhook egress settings: %w", err)
+	}
+	return s.webhookEgress(settings)
+}
+
+func (s *WebhookService) webhookEgress(settings *domain.WebhookEgressSettings) (*WebhookEgress, error) {
+	egress := &WebhookEgress{EgressIPs: s.egressIPs}
+	if settings == nil {
+		return egress, nil
+	}
+
+	egress.UpdatedAt = &settings.UpdatedAt
+	if settings.ProxyURL != "" {
+		egress.Dedicated = true
+		egress.EgressIPs = settings.EgressIPs
+		if proxyURL, err := url.Parse(settings.ProxyURL); err == nil {
+			egress.ProxyURL = proxyURL.Redacted()
+		}
+	}
+	if settings.ClientCertificate != "" {
+		info, err := describeWebhookClientCertificate(settings.ClientCertificate)
+		if err != nil {
+			return nil, err
+		}
+		egress.ClientCertificate = info
+	}
+	if egress.EgressIPs == nil {
+		egress.EgressIPs = []string{}
+	}
+	return egress, nil
+}
+
+// egressClient returns the HTTP client for the organization's deliveries. Clients are cached until
+// the settings change so connections to receivers are reused across deliveries.
+func (s *WebhookService) egressClient(orgID uuid.UUID) (*webhookEgressClient, error) {
+	settings, err := s.webhookRepo.GetEgressSettings(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook egress settings: %w", err)
+	}
+	if settings == nil || (settings.ProxyURL == "" && settings.ClientCertificate == "") {
+		return &webhookEgressClient{client: s.httpClient}, nil
+	}
+
+	s.egressMu.Lock()
+	defer s.egressMu.Unlock()
+	if cached, ok := s.egressClients[orgID]; ok && cached.updatedAt.Equal(settings.UpdatedAt) {
+		return cached, nil
+	}
+
+	var certificate *tls.Certificate
+	if settings.ClientCertificate != "" {
+		if s.keyVault == nil {
+			return nil, errors.New("no key vault is available to decrypt the client key")
+		}
+		keyPEM, err := s.keyVault.DecryptPrivateKey(settings.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt client key: %w", err)
+		}
+		cert, _, err := parseWebhookClientCertificate(settings.ClientCertificate, keyPEM)
+		if err != nil {
+			return nil, err
+		}
+		certificate = &cert
+	}
+
+	proxyURL := settings.ProxyURL
+	if proxyURL == "" {
+		proxyURL = s.egressProxyURL
+	}
+	transport, err := newWebhookTransport(proxyURL, certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &webhookEgressClient{
+		updatedAt:      settings.UpdatedAt,
+		client:         &http.Client{Timeout: webhookSendTimeout, Transport: resilience.NewTransport(resilience.DependencyWebhooks, transport)},
+		hasCertificate: certificate != nil,
+	}
+	if previous, ok := s.egressClients[orgID]; ok {
+		if previousTransport, ok := previous.client.Transport.(interface{ CloseIdleConnections() }); ok {
+			previousTransport.CloseIdleConnections()
+		}
+	}
+	s.egressClients[orgID] = client
+	return client, nil
+}
+
+// deliveryClient returns the client a webhook is delivered with. A webhook that requires mTLS
+// is never sent without the organization's client certificate.
+func (s *WebhookService) deliveryClient(webhook *domain.Webhook, clients map[uuid.UUID]*webhookEgressClient) (*http.Client, error) {
+	client, ok := clients[webhook.OrganizationID]
+	if !ok {
+		var err error
+		if client, err = s.egressClient(webhook.OrganizationID); err != nil {
+			return nil, err
+		}
+		clients[webhook.OrganizationID] = client
+	}
+	if webhook.RequireMTLS && !client.hasCertificate {
+		return nil, ErrWebhookMTLSNotConfigured
+	}
+	return client.client, nil
+}
+
+// checkRequireMTLS rejects requiring mTLS for a webhook that cannot be delivered with it
+func (s *WebhookService) checkRequireMTLS(orgID uuid.UUID, webhookURL string) error {
+	if parsed, err := url.Parse(webhookURL); err != nil || parsed.Scheme != "https" {
+		return fmt.Errorf("%w: require_mtls needs an https URL", ErrInvalidWebhookEgress)
+	}
+	settings, err := s.webhookRepo.GetEgressSettings(orgID)
+	if err != nil {
+		return err
+	}
+	if settings == nil || settings.ClientCertificate == "" {
+		return ErrWebhookMTLSNotConfigured
+	}
+	return nil
+}
+
+// newWebhookTransport returns a transport that sends through proxyURL, or directly when it is
+// empty, and presents the certificate to receivers that ask for one
+func newWebhookTransport(proxyURL string, certificate *tls.Certificate) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	if transport.TLSClientConfig.MinVersion < tls.VersionTLS12 {
+		transport.TLSClientConfig.MinVersion = tls.VersionTLS12
+	}
+	if certificate != nil {
+		transport.TLSClientConfig.Certificates = []tls.Certificate{*certificate}
+	}
+	if proxyURL != "" {
+		proxy, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook egress proxy: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return transport, nil
+}
+
+func validateWebhookProxyURL(proxyURL string) error {
+	if proxyURL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(proxyURL)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("%w: proxyUrl must be an absolute URL", ErrInvalidWebhookEgress)
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+		return nil
+	default:
+		return fmt.Errorf("%w: proxyUrl scheme must be http, https or socks5", ErrInvalidWebhookEgress)
+	}
+}
+
+// parseWebhookClientCertificate checks that the key matches the certificate and that the
+// certificate may be used for client authentication
+func parseWebhookClientCertificate(certPEM, keyPEM string) (tls.Certificate, *WebhookClientCertificate, error) {
+	cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("%w: %v", ErrInvalidWebhookEgress, err)
+	}
+	info, err := describeWebhookClientCertificate(certPEM)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	return cert, info, nil
+}
+
+func describeWebhookClientCertificate(certPEM string) (*WebhookClientCertificate, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("%w: clientCertificate must be a PEM encoded certificate", ErrInvalidWebhookEgress)
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookEgress, err)
+	}
+	if len(leaf.ExtKeyUsage) > 0 &&
+		!slices.Contains(leaf.ExtKeyUsage, x509.ExtKeyUsageClientAuth) &&
+		!slices.Contains(leaf.ExtKeyUsage, x509.ExtKeyUsageAny) {
+		return nil, fmt.Errorf("%w: certificate is not valid for client authentication", ErrInvalidWebhookEgress)
+	}
+
+	fingerprint := sha256.Sum256(leaf.Raw)
+	return &WebhookClientCertificate{
+		Subject:           leaf.Subject.String(),
+		Issuer:            leaf.Issuer.String(),
+		NotBefore:         leaf.NotBefore,
+		NotAfter:          leaf.NotAfter,
+		FingerprintSHA256: hex.EncodeToString(fingerprint[:]),
+		PEM:               certPEM,
+	}, nil
+}
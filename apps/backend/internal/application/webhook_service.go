@@ -8,36 +8,153 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"net"
 	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/cel"
+	"github.com/opena2a/identity/backend/internal/crypto"
 	"github.com/opena2a/identity/backend/internal/domain"
-	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
 )
 
+const (
+	// defaultWebhookMaxAttempts is the retry budget of every webhook delivery
+	defaultWebhookMaxAttempts = 5
+	// webhookBaseBackoff is the delay before the first retry; it doubles per attempt
+	webhookBaseBackoff = 30 * time.Second
+	// webhookMaxBackoff caps the delay between retries
+	webhookMaxBackoff = time.Hour
+	// webhookClaimLease is how long a worker owns a claimed delivery
+	webhookClaimLease = 2 * time.Minute
+	// webhookBatchSize is the number of deliveries claimed per poll
+	webhookBatchSize = 50
+	// webhookPollInterval is how often the worker polls the queue
+	webhookPollInterval = 5 * time.Second
+	// webhookSendTimeout bounds a single POST to a receiver
+	webhookSendTimeout = 10 * time.Second
+	// webhookResponseLimit caps how much of a receiver's response body is stored
+	webhookResponseLimit = 4096
+	// attestationCallbackAttempts and attestationCallbackBackoff bound deliveries to an SDK's
+	// attestation callback URL. They are not queued: the SDK is waiting for them.
+	attestationCallbackAttempts = 3
+	attestationCallbackBackoff  = 2 * time.Second
+)
+
+// ErrInvalidWebhookFilter is returned when a webhook filter expression does not compile
+var ErrInvalidWebhookFilter = errors.New("invalid webhook filter")
+
+// webhookFilterVariables are the variables a webhook filter expression may reference
+var webhookFilterVariables = []string{"event", "agent"}
+
+// webhookFilters holds compiled webhook filters. A filter is compiled when the webhook is saved
+// and reused for every delivery.
+var webhookFilters = cel.NewCache(webhookFilterVariables...)
+
+// alertWebhookEvents maps alert types to the specific webhook event they are also published as.
+// Every alert is published as alert.created; receivers that only care about one class of
+// signal subscribe to the specific event instead.
+var alertWebhookEvents = map[domain.AlertType]domain.WebhookEvent{
+	domain.AlertTypeConfigurationDrift: domain.WebhookEventDriftDetected,
+	domain.AlertTypeCapabilityDrift:    domain.WebhookEventDriftDetected,
+	domain.AlertCapabilityDeprecated:   domain.WebhookEventDriftDetected,
+	domain.AlertTypePeerDrift:          domain.WebhookEventDriftDetected,
+	domain.AlertTypeMCPToolDrift:       domain.WebhookEventDriftDetected,
+	domain.AlertSecurityBreach:         domain.WebhookEventThreatDetected,
+	domain.AlertUnusualActivity:        domain.WebhookEventThreatDetected,
+	domain.AlertSBOMVulnerability:      domain.WebhookEventThreatDetected,
+	domain.AlertTrustScoreDrop:         domain.WebhookEventTrustScoreDropped,
+	domain.AlertTrustScoreLow:          domain.WebhookEventTrustScoreDropped,
+}
+
+// WebhookService manages webhook subscriptions and delivers events to them through a
+// persistent queue. Deliveries are signed with the subscription secret (HMAC-SHA256)
+// and retried with exponential backoff, so receiver outages delay events instead of dropping them.
+// Deliveries leave through the shared egress proxy, or the organization's dedicated proxy, and
+// present the organization's client certificate to receivers that require mTLS.
 type WebhookService struct {
-	webhookRepo *repository.WebhookRepository
+	webhookRepo    domain.WebhookRepository
+	tagRepo        domain.TagRepository
+	keyVault       *crypto.KeyVault
+	httpClient     *http.Client
+	egressProxyURL string
+	egressIPs      []string
+	lookupIP       func(ctx context.Context, network, host string) ([]netip.Addr, error) // Resolves attestation callback hosts
+
+	egressMu      sync.Mutex
+	egressClients map[uuid.UUID]*webhookEgressClient
 }
 
-func NewWebhookService(webhookRepo *repository.WebhookRepository) *WebhookService {
+// NewWebhookService creates a new webhook service. egressProxyURL routes every delivery through a
+// proxy with stable source addresses, published to receivers as egressIPs; empty sends directly.
+func NewWebhookService(
+	webhookRepo domain.WebhookRepository,
+	tagRepo domain.TagRepository,
+	keyVault *crypto.KeyVault,
+	egressProxyURL string,
+	egressIPs []string,
+) *WebhookService {
+	httpClient := &http.Client{Timeout: webhookSendTimeout}
+	if egressProxyURL != "" {
+		transport, err := newWebhookTransport(egressProxyURL, nil)
+		if err != nil {
+			log.Printf("⚠️  Webhook egress proxy ignored: %v", err)
+			egressProxyURL = ""
+		} else {
+			httpClient.Transport = transport
+		}
+	}
+	resilience.WrapClient(httpClient, resilience.DependencyWebhooks)
+
 	return &WebhookService{
-		webhookRepo: webhookRepo,
+		webhookRepo:    webhookRepo,
+		tagRepo:        tagRepo,
+		keyVault:       keyVault,
+		httpClient:     httpClient,
+		egressProxyURL: egressProxyURL,
+		egressIPs:      egressIPs,
+		lookupIP:       net.DefaultResolver.LookupNetIP,
+		egressClients:  make(map[uuid.UUID]*webhookEgressClient),
 	}
 }
 
 // CreateWebhookRequest represents the request to create a webhook
 type CreateWebhookRequest struct {
-	Name     string                 `json:"name" validate:"required"`
-	URL      string                 `json:"url" validate:"required,url"`
-	Events   []domain.WebhookEvent  `json:"events" validate:"required"`
-	IsActive *bool                  `json:"is_active,omitempty"` // Pointer to distinguish between false and not provided
+	Name     string                `json:"name" validate:"required"`
+	URL      string                `json:"url" validate:"required,url"`
+	Events   []domain.WebhookEvent `json:"events" validate:"required"`
+	Filter       string                     `json:"filter,omitempty"`        // Optional CEL expression over event and agent
+	TemplateType domain.WebhookTemplateType `json:"template_type,omitempty"` // go_template or jq; empty delivers the platform payload
+	Template     string                     `json:"template,omitempty"`      // Transformation applied to the payload before delivery
+	IsActive     *bool                      `json:"is_active,omitempty"`     // Pointer to distinguish between false and not provided
+	RequireMTLS  bool                       `json:"require_mtls,omitempty"`  // Only deliver with the organization's client certificate
 }
 
 // CreateWebhook creates a new webhook subscription
 func (s *WebhookService) CreateWebhook(ctx context.Context, req *CreateWebhookRequest, orgID, userID uuid.UUID) (*domain.Webhook, error) {
+	filter, err := compileWebhookFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	templateType, template, err := compileWebhookTemplate(req.TemplateType, req.Template)
+	if err != nil {
+		return nil, err
+	}
+	if req.RequireMTLS {
+		if err := s.checkRequireMTLS(orgID, req.URL); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate secret for webhook signature
 	secret, err := generateSecret()
 	if err != nil {
@@ -50,7 +167,11 @@ func (s *WebhookService) CreateWebhook(ctx context.Context, req *CreateWebhookRe
 		Name:           req.Name,
 		URL:            req.URL,
 		Events:         req.Events,
+		Filter:         filter,
+		TemplateType:   templateType,
+		Template:       template,
 		Secret:         secret,
+		RequireMTLS:    req.RequireMTLS,
 		IsActive:       true,
 		FailureCount:   0,
 		CreatedBy:      userID,
@@ -82,16 +203,34 @@ func (s *WebhookService) DeleteWebhook(ctx context.Context, id uuid.UUID) error
 
 // UpdateWebhook updates an existing webhook
 func (s *WebhookService) UpdateWebhook(ctx context.Context, id uuid.UUID, req *CreateWebhookRequest) (*domain.Webhook, error) {
+	filter, err := compileWebhookFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	templateType, template, err := compileWebhookTemplate(req.TemplateType, req.Template)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get existing webhook
 	webhook, err := s.webhookRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
+	if req.RequireMTLS {
+		if err := s.checkRequireMTLS(webhook.OrganizationID, req.URL); err != nil {
+			return nil, err
+		}
+	}
 
 	// Update fields
 	webhook.Name = req.Name
 	webhook.URL = req.URL
 	webhook.Events = req.Events
+	webhook.Filter = filter
+	webhook.TemplateType = templateType
+	webhook.Template = template
+	webhook.RequireMTLS = req.RequireMTLS
 
 	// Update IsActive if provided
 	if req.IsActive != nil {
@@ -108,6 +247,340 @@ func (s *WebhookService) UpdateWebhook(ctx context.Context, id uuid.UUID, req *C
 	return webhook, nil
 }
 
+// TriggerEvent queues an event for every active webhook in the organization
+// that subscribes to it and whose filter matches. filterData supplies the
+// filter variables (event, agent); payload is what subscribers receive.
+// Deliveries are made by the delivery worker so callers are never blocked on egress.
+func (s *WebhookService) TriggerEvent(ctx context.Context, orgID uuid.UUID, event domain.WebhookEvent, filterData map[string]interface{}, payload interface{}) {
+	s.triggerEvent(ctx, orgID, event, filterData, payload, nil)
+}
+
+// triggerEvent is TriggerEvent limited to the webhooks include accepts; nil includes every webhook
+func (s *WebhookService) triggerEvent(ctx context.Context, orgID uuid.UUID, event domain.WebhookEvent, filterData map[string]interface{}, payload interface{}, include func(*domain.Webhook) bool) {
+	webhooks, err := s.webhookRepo.GetByOrganization(orgID)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to load webhooks for event %s: %v\n", event, err)
+		return
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"event":     string(event),
+		"timestamp": time.Now().UTC(),
+		"data":      payload,
+	})
+	if err != nil {
+		fmt.Printf("⚠️  Failed to encode webhook event %s: %v\n", event, err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if !webhook.IsActive || !subscribesTo(webhook, event) || (include != nil && !include(webhook)) {
+			continue
+		}
+
+		matched, err := matchesWebhookFilter(webhook.Filter, filterData)
+		if err != nil {
+			// A filter that cannot be evaluated against this event is treated as a non-match
+			fmt.Printf("⚠️  Webhook %s filter evaluation failed: %v\n", webhook.ID, err)
+			continue
+		}
+		if !matched {
+			continue
+		}
+
+		s.enqueueDelivery(webhook, event, body)
+	}
+}
+
+// enqueueDelivery stores a pending delivery of the rendered payload. A template that cannot
+// render the payload is recorded as a failed delivery so it shows up in the delivery history.
+func (s *WebhookService) enqueueDelivery(webhook *domain.Webhook, event domain.WebhookEvent, body []byte) {
+	delivery := &domain.WebhookDelivery{
+		ID:            uuid.New(),
+		WebhookID:     webhook.ID,
+		Event:         event,
+		Status:        domain.WebhookDeliveryPending,
+		MaxAttempts:   defaultWebhookMaxAttempts,
+		NextAttemptAt: time.Now().UTC(),
+	}
+
+	// Shape the payload for the consumer once; every retry sends (and signs) the same bytes
+	rendered, err := renderWebhookTemplate(webhook, body)
+	if err != nil {
+		errMsg := err.Error()
+		delivery.Payload = string(body)
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.LastError = &errMsg
+	} else {
+		delivery.Payload = string(rendered)
+	}
+
+	if err := s.webhookRepo.RecordDelivery(delivery); err != nil {
+		fmt.Printf("⚠️  Failed to queue webhook %s delivery of %s: %v\n", webhook.ID, event, err)
+	}
+}
+
+// PublishAlert publishes a newly created alert as alert.created and, for drift, threat and
+// trust score alerts, as the matching specific event. The alert's resource is exposed to
+// filters as the agent when it is one.
+func (s *WebhookService) PublishAlert(ctx context.Context, alert *domain.Alert) {
+	eventData := map[string]interface{}{}
+	if data, err := cel.Normalize(alert); err == nil {
+		eventData, _ = data.(map[string]interface{})
+	}
+
+	agentData := map[string]interface{}{}
+	if alert.ResourceType == "agent" {
+		agentData["id"] = alert.ResourceID.String()
+	}
+
+	events := []domain.WebhookEvent{domain.WebhookEventAlertCreated}
+	if event, ok := alertWebhookEvents[alert.AlertType]; ok {
+		events = append(events, event)
+	}
+
+	for _, event := range events {
+		filterEvent := make(map[string]interface{}, len(eventData)+1)
+		for k, v := range eventData {
+			filterEvent[k] = v
+		}
+		filterEvent["type"] = string(event)
+
+		s.TriggerEvent(ctx, alert.OrganizationID, event, map[string]interface{}{
+			"event": filterEvent,
+			"agent": agentData,
+		}, alert)
+	}
+}
+
+// PublishAttestationExpired publishes an MCP attestation that passed its expiry
+func (s *WebhookService) PublishAttestationExpired(ctx context.Context, orgID uuid.UUID, attestation *domain.MCPAttestation) {
+	eventData := map[string]interface{}{}
+	if data, err := cel.Normalize(attestation); err == nil {
+		eventData, _ = data.(map[string]interface{})
+	}
+	eventData["type"] = string(domain.WebhookEventAttestationExpired)
+
+	agentData := map[string]interface{}{}
+	if attestation.AgentID != nil {
+		agentData["id"] = attestation.AgentID.String()
+	}
+
+	s.TriggerEvent(ctx, orgID, domain.WebhookEventAttestationExpired, map[string]interface{}{
+		"event": eventData,
+		"agent": agentData,
+	}, attestation)
+}
+
+// PublishTrustScoreTrigger publishes a trust score trigger firing as trust_score.threshold_crossed
+func (s *WebhookService) PublishTrustScoreTrigger(ctx context.Context, firing *domain.TrustScoreTriggerFiring) {
+	eventData := map[string]interface{}{}
+	if data, err := cel.Normalize(firing); err == nil {
+		eventData, _ = data.(map[string]interface{})
+	}
+	eventData["type"] = string(domain.WebhookEventTrustScoreThreshold)
+
+	s.TriggerEvent(ctx, firing.OrganizationID, domain.WebhookEventTrustScoreThreshold, map[string]interface{}{
+		"event": eventData,
+		"agent": map[string]interface{}{"id": firing.AgentID.String()},
+	}, firing)
+}
+
+// PublishAttestationResult publishes the result of an agent's attestation as attestation.verified
+// or attestation.rejected to the webhooks created by the agent's owner. When the SDK supplied a
+// callback URL the result is also POSTed there, signed with the attestation's nonce, which only
+// the agent and the platform know; attestations without a nonce get no callback.
+func (s *WebhookService) PublishAttestationResult(ctx context.Context, orgID uuid.UUID, result *domain.AttestationResult, callbackURL, nonce string) {
+	event := domain.WebhookEventAttestationVerified
+	if result.Status == domain.AttestationResultRejected {
+		event = domain.WebhookEventAttestationRejected
+	}
+
+	eventData := map[string]interface{}{}
+	if data, err := cel.Normalize(result); err == nil {
+		eventData, _ = data.(map[string]interface{})
+	}
+	eventData["type"] = string(event)
+
+	s.triggerEvent(ctx, orgID, event, map[string]interface{}{
+		"event": eventData,
+		"agent": map[string]interface{}{"id": result.AgentID.String()},
+	}, result, func(webhook *domain.Webhook) bool {
+		return result.OwnerID != uuid.Nil && webhook.CreatedBy == result.OwnerID
+	})
+
+	if callbackURL == "" {
+		return
+	}
+	if nonce == "" {
+		log.Printf("⚠️  Attestation callback to %s not sent: the attestation has no nonce to sign it with", callbackURL)
+		return
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"event":     string(event),
+		"timestamp": time.Now().UTC(),
+		"data":      result,
+	})
+	if err != nil {
+		log.Printf("⚠️  Failed to encode attestation callback: %v", err)
+		return
+	}
+	go s.deliverAttestationCallback(orgID, callbackURL, event, body, nonce)
+}
+
+// deliverAttestationCallback POSTs an attestation result to the SDK's callback URL through the
+// organization's webhook egress, retrying a few times
+func (s *WebhookService) deliverAttestationCallback(orgID uuid.UUID, callbackURL string, event domain.WebhookEvent, body []byte, nonce string) {
+	if err := s.checkAttestationCallbackHost(callbackURL); err != nil {
+		log.Printf("⚠️  Attestation callback to %s not sent: %v", callbackURL, err)
+		return
+	}
+	client, err := s.egressClient(orgID)
+	if err != nil {
+		log.Printf("⚠️  Attestation callback to %s not sent: %v", callbackURL, err)
+		return
+	}
+
+	deliveryID := uuid.New().String()
+	for attempt := 1; attempt <= attestationCallbackAttempts; attempt++ {
+		err = s.postAttestationCallback(client.client, callbackURL, event, body, nonce, deliveryID)
+		if err == nil {
+			return
+		}
+		if attempt < attestationCallbackAttempts {
+			time.Sleep(attestationCallbackBackoff * time.Duration(attempt))
+		}
+	}
+	log.Printf("⚠️  Attestation callback to %s failed: %v", callbackURL, err)
+}
+
+// checkAttestationCallbackHost resolves the callback URL's host and rejects it if any of its
+// addresses is internal: the URL is chosen by the SDK, not by an administrator
+func (s *WebhookService) checkAttestationCallbackHost(callbackURL string) error {
+	parsed, err := url.Parse(callbackURL)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhookSendTimeout)
+	defer cancel()
+
+	addrs, err := s.lookupIP(ctx, "ip", parsed.Hostname())
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", parsed.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if !attestationCallbackAddressAllowed(addr) {
+			return fmt.Errorf("%s resolves to internal address %s", parsed.Hostname(), addr)
+		}
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which also holds cloud metadata
+// endpoints such as 100.100.100.200
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// attestationCallbackAddressAllowed reports whether an attestation callback may be sent to addr.
+// Loopback, private, link-local (including the 169.254.169.254 metadata endpoint) and other
+// non-public addresses are refused.
+func attestationCallbackAddressAllowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+func (s *WebhookService) postAttestationCallback(client *http.Client, callbackURL string, event domain.WebhookEvent, body []byte, nonce, deliveryID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookSendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if nonce != "" {
+		req.Header.Set("X-Webhook-Signature", createSignature(body, nonce))
+	}
+	req.Header.Set("X-Webhook-Event", string(event))
+	req.Header.Set("X-Webhook-Delivery", deliveryID)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseLimit))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// AlertRepository wraps an alert repository so alerts that services create directly
+// through it (drift, security and trust score alerts) are also published to webhooks
+func (s *WebhookService) AlertRepository(alertRepo domain.AlertRepository) domain.AlertRepository {
+	return &webhookPublishingAlertRepository{AlertRepository: alertRepo, webhookService: s}
+}
+
+type webhookPublishingAlertRepository struct {
+	domain.AlertRepository
+	webhookService *WebhookService
+}
+
+func (r *webhookPublishingAlertRepository) Create(alert *domain.Alert) error {
+	if err := r.AlertRepository.Create(alert); err != nil {
+		return err
+	}
+	r.webhookService.PublishAlert(context.Background(), alert)
+	return nil
+}
+
+// TriggerVerificationEvent delivers a completed verification event to subscribed webhooks
+func (s *WebhookService) TriggerVerificationEvent(ctx context.Context, event *domain.VerificationEvent, agent *domain.Agent) {
+	eventData := map[string]interface{}{}
+	if data, err := cel.Normalize(event); err == nil {
+		eventData, _ = data.(map[string]interface{})
+	}
+	eventData["type"] = string(domain.WebhookEventVerificationCompleted)
+
+	agentData := map[string]interface{}{}
+	if agent != nil {
+		if data, err := cel.Normalize(agent); err == nil {
+			agentData, _ = data.(map[string]interface{})
+		}
+		agentData["tags"] = s.agentTagValues(ctx, agent)
+	}
+
+	s.TriggerEvent(ctx, event.OrganizationID, domain.WebhookEventVerificationCompleted, map[string]interface{}{
+		"event": eventData,
+		"agent": agentData,
+	}, event)
+}
+
+// agentTagValues flattens agent tags for filters: each tag is exposed both as
+// its value ("prod") and as "key:value" ("environment:prod")
+func (s *WebhookService) agentTagValues(ctx context.Context, agent *domain.Agent) []string {
+	tags := agent.Tags
+	if len(tags) == 0 && s.tagRepo != nil {
+		agentTags, err := s.tagRepo.GetAgentTags(ctx, agent.ID)
+		if err == nil {
+			for _, tag := range agentTags {
+				tags = append(tags, *tag)
+			}
+		}
+	}
+
+	values := make([]string, 0, len(tags)*2)
+	for _, tag := range tags {
+		values = append(values, tag.Value, tag.Key+":"+tag.Value)
+	}
+	return values
+}
+
 // WebhookTestResult contains the result of a webhook test
 type WebhookTestResult struct {
 	Success      bool
@@ -115,7 +588,7 @@ type WebhookTestResult struct {
 	ErrorMessage string
 }
 
-// TestWebhook sends a test payload to a webhook
+// TestWebhook sends a test payload to a webhook synchronously, without retries
 func (s *WebhookService) TestWebhook(ctx context.Context, id uuid.UUID) (*WebhookTestResult, error) {
 	webhook, err := s.webhookRepo.GetByID(id)
 	if err != nil {
@@ -123,21 +596,54 @@ func (s *WebhookService) TestWebhook(ctx context.Context, id uuid.UUID) (*Webhoo
 	}
 
 	// Create test payload
-	payload := map[string]interface{}{
+	payload, err := json.Marshal(map[string]interface{}{
 		"event":      "webhook.test",
 		"webhook_id": webhook.ID.String(),
 		"timestamp":  time.Now().UTC(),
 		"data": map[string]string{
 			"message": "This is a test webhook delivery",
 		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &domain.WebhookDelivery{
+		ID:           uuid.New(),
+		WebhookID:    webhook.ID,
+		Event:        "webhook.test",
+		Payload:      string(payload),
+		AttemptCount: 1,
+		MaxAttempts:  1,
 	}
 
 	// Send webhook and capture result
-	statusCode, deliveryErr := s.sendWebhookWithResult(webhook, "webhook.test", payload)
+	var responseBody string
+	body, deliveryErr := renderWebhookTemplate(webhook, payload)
+	if deliveryErr == nil {
+		delivery.Payload = string(body)
+		var client *http.Client
+		client, deliveryErr = s.deliveryClient(webhook, make(map[uuid.UUID]*webhookEgressClient))
+		if deliveryErr == nil {
+			delivery.StatusCode, responseBody, deliveryErr = s.postWebhook(ctx, client, webhook, delivery)
+		}
+	}
+
+	delivery.ResponseBody = responseBody
+	delivery.Success = deliveryErr == nil
+	delivery.Status = domain.WebhookDeliveryDelivered
+	if deliveryErr != nil {
+		errMsg := deliveryErr.Error()
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.LastError = &errMsg
+	}
+	if err := s.webhookRepo.RecordDelivery(delivery); err != nil {
+		fmt.Printf("⚠️  Failed to record webhook %s test delivery: %v\n", webhook.ID, err)
+	}
 
 	result := &WebhookTestResult{
-		Success:    statusCode >= 200 && statusCode < 300,
-		StatusCode: statusCode,
+		Success:    delivery.Success,
+		StatusCode: delivery.StatusCode,
 	}
 
 	if deliveryErr != nil {
@@ -147,65 +653,169 @@ func (s *WebhookService) TestWebhook(ctx context.Context, id uuid.UUID) (*Webhoo
 	return result, nil
 }
 
-// sendWebhookWithResult sends a webhook payload and returns status code and error
-func (s *WebhookService) sendWebhookWithResult(webhook *domain.Webhook, event string, payload interface{}) (int, error) {
-	jsonData, err := json.Marshal(payload)
+// webhookRetryBackoff returns the delay before the next attempt after the given (1-based) failed attempt
+func webhookRetryBackoff(attempt int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// Start runs the delivery worker until the context is cancelled
+func (s *WebhookService) Start(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	log.Println("✅ Webhook delivery worker started")
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Webhook delivery worker stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.ProcessQueue(ctx); err != nil {
+				log.Printf("⚠️  Webhook queue processing failed: %v", err)
+			}
+		}
+	}
+}
+
+// ProcessQueue claims due deliveries and attempts each once. Returns the number of deliveries attempted.
+func (s *WebhookService) ProcessQueue(ctx context.Context) (int, error) {
+	deliveries, err := s.webhookRepo.ClaimDueDeliveries(webhookBatchSize, webhookClaimLease)
 	if err != nil {
 		return 0, err
 	}
 
-	// Create signature
-	signature := createSignature(jsonData, webhook.Secret)
+	webhooks := make(map[uuid.UUID]*domain.Webhook)
+	clients := make(map[uuid.UUID]*webhookEgressClient)
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			// Unprocessed claims are released when their lease expires
+			return 0, ctx.Err()
+		}
+		s.attemptDelivery(ctx, delivery, webhooks, clients)
+	}
+
+	return len(deliveries), nil
+}
+
+func (s *WebhookService) attemptDelivery(ctx context.Context, delivery *domain.WebhookDelivery, webhooks map[uuid.UUID]*domain.Webhook, clients map[uuid.UUID]*webhookEgressClient) {
+	webhook, ok := webhooks[delivery.WebhookID]
+	if !ok {
+		var err error
+		if webhook, err = s.webhookRepo.GetByID(delivery.WebhookID); err != nil {
+			s.recordFailure(delivery, 0, "", fmt.Errorf("webhook unavailable: %w", err), true)
+			return
+		}
+		webhooks[delivery.WebhookID] = webhook
+	}
+	if !webhook.IsActive {
+		s.recordFailure(delivery, 0, "", errors.New("webhook is disabled"), true)
+		return
+	}
+
+	// Retried so deliveries resume once egress settings are fixed
+	client, err := s.deliveryClient(webhook, clients)
+	if err != nil {
+		s.recordFailure(delivery, 0, "", err, false)
+		return
+	}
 
-	// Send HTTP request
-	req, err := http.NewRequest("POST", webhook.URL, bytes.NewBuffer(jsonData))
+	statusCode, responseBody, err := s.postWebhook(ctx, client, webhook, delivery)
 	if err != nil {
-		return 0, err
+		s.recordFailure(delivery, statusCode, responseBody, err, false)
+		return
+	}
+
+	if err := s.webhookRepo.RecordAttempt(delivery.ID, domain.WebhookDeliveryDelivered, statusCode, responseBody, "", time.Now().UTC()); err != nil {
+		log.Printf("⚠️  Failed to mark webhook delivery %s as delivered: %v", delivery.ID, err)
+	}
+}
+
+// recordFailure schedules a retry, or marks the delivery failed once retries are exhausted
+func (s *WebhookService) recordFailure(delivery *domain.WebhookDelivery, statusCode int, responseBody string, sendErr error, permanent bool) {
+	attempt := delivery.AttemptCount + 1
+	status := domain.WebhookDeliveryPending
+	if permanent || attempt >= delivery.MaxAttempts {
+		status = domain.WebhookDeliveryFailed
+		log.Printf("❌ Webhook delivery %s (%s → %s) failed permanently: %v",
+			delivery.ID, delivery.Event, delivery.WebhookID, sendErr)
+	}
+
+	nextAttemptAt := time.Now().UTC().Add(webhookRetryBackoff(attempt))
+	if err := s.webhookRepo.RecordAttempt(delivery.ID, status, statusCode, responseBody, sendErr.Error(), nextAttemptAt); err != nil {
+		log.Printf("⚠️  Failed to record webhook delivery failure %s: %v", delivery.ID, err)
+	}
+}
+
+// postWebhook POSTs a delivery's payload signed with the webhook secret. Any non-2xx response is an error.
+func (s *WebhookService) postWebhook(ctx context.Context, client *http.Client, webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, string, error) {
+	payload := []byte(delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, "", err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Webhook-Signature", signature)
-	req.Header.Set("X-Webhook-Event", event)
+	req.Header.Set("X-Webhook-Signature", createSignature(payload, webhook.Secret))
+	req.Header.Set("X-Webhook-Event", string(delivery.Event))
+	req.Header.Set("X-Webhook-Delivery", delivery.ID.String()) // Stable across retries so receivers can deduplicate
 
-	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return 0, err
+		return 0, "", err
 	}
 	defer resp.Body.Close()
 
-	// Read response
-	body, _ := io.ReadAll(resp.Body)
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
 
-	// Record delivery
-	delivery := &domain.WebhookDelivery{
-		ID:           uuid.New(),
-		WebhookID:    webhook.ID,
-		Event:        domain.WebhookEvent(event),
-		Payload:      string(jsonData),
-		StatusCode:   resp.StatusCode,
-		ResponseBody: string(body),
-		Success:      resp.StatusCode >= 200 && resp.StatusCode < 300,
-		AttemptCount: 1,
-		CreatedAt:    time.Now().UTC(),
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(body), fmt.Errorf("webhook delivery failed with status %d", resp.StatusCode)
 	}
 
-	s.webhookRepo.RecordDelivery(delivery)
+	return resp.StatusCode, string(body), nil
+}
 
-	if !delivery.Success {
-		return resp.StatusCode, fmt.Errorf("webhook delivery failed with status %d", resp.StatusCode)
-	}
+// Helper functions
 
-	return resp.StatusCode, nil
+// compileWebhookFilter validates a filter expression and returns it trimmed
+func compileWebhookFilter(filter string) (string, error) {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return "", nil
+	}
+	if _, err := webhookFilters.Compile(filter); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidWebhookFilter, err)
+	}
+	return filter, nil
 }
 
-// sendWebhook sends a webhook payload
-func (s *WebhookService) sendWebhook(webhook *domain.Webhook, event string, payload interface{}) error {
-	_, err := s.sendWebhookWithResult(webhook, event, payload)
-	return err
+// matchesWebhookFilter reports whether the event satisfies the filter; an empty filter matches everything
+func matchesWebhookFilter(filter string, data map[string]interface{}) (bool, error) {
+	if strings.TrimSpace(filter) == "" {
+		return true, nil
+	}
+	program, err := webhookFilters.Compile(filter)
+	if err != nil {
+		return false, err
+	}
+	return program.EvalBool(data)
 }
 
-// Helper functions
+func subscribesTo(webhook *domain.Webhook, event domain.WebhookEvent) bool {
+	for _, e := range webhook.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
 
 func generateSecret() (string, error) {
 	b := make([]byte, 32)
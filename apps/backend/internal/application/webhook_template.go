@@ -0,0 +1,145 @@
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/jq"
+)
+
+// ErrInvalidWebhookTemplate is returned when a webhook transformation template does not compile
+var ErrInvalidWebhookTemplate = errors.New("invalid webhook template")
+
+const (
+	// maxWebhookTemplateLength bounds stored templates
+	maxWebhookTemplateLength = 16 * 1024
+	// maxWebhookTemplateOutput bounds rendered payloads so a template cannot amplify deliveries
+	maxWebhookTemplateOutput = 1024 * 1024
+)
+
+// webhookTemplateFuncs are available to Go templates in addition to the text/template builtins.
+// json renders any value as a JSON literal, which is the safe way to embed strings in a JSON body.
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join": func(sep string, items []interface{}) string {
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, sep)
+	},
+	"default": func(fallback, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return fallback
+		}
+		return v
+	},
+}
+
+// compileWebhookTemplate validates a transformation template and returns it trimmed.
+// A template type without a template (or vice versa) is rejected rather than silently ignored.
+func compileWebhookTemplate(templateType domain.WebhookTemplateType, source string) (domain.WebhookTemplateType, string, error) {
+	source = strings.TrimSpace(source)
+	if templateType == domain.WebhookTemplateNone && source == "" {
+		return domain.WebhookTemplateNone, "", nil
+	}
+	if source == "" {
+		return "", "", fmt.Errorf("%w: template is empty", ErrInvalidWebhookTemplate)
+	}
+	if len(source) > maxWebhookTemplateLength {
+		return "", "", fmt.Errorf("%w: template exceeds %d characters", ErrInvalidWebhookTemplate, maxWebhookTemplateLength)
+	}
+
+	switch templateType {
+	case domain.WebhookTemplateGoTemplate:
+		if _, err := parseGoWebhookTemplate(source); err != nil {
+			return "", "", fmt.Errorf("%w: %v", ErrInvalidWebhookTemplate, err)
+		}
+	case domain.WebhookTemplateJQ:
+		if _, err := jq.Compile(source); err != nil {
+			return "", "", fmt.Errorf("%w: %v", ErrInvalidWebhookTemplate, err)
+		}
+	default:
+		return "", "", fmt.Errorf("%w: template_type must be %q or %q", ErrInvalidWebhookTemplate,
+			domain.WebhookTemplateGoTemplate, domain.WebhookTemplateJQ)
+	}
+
+	return templateType, source, nil
+}
+
+// renderWebhookTemplate applies the webhook's transformation to the JSON payload
+// ({event, timestamp, data}); webhooks without a template get the payload unchanged.
+// The result must be a single JSON document.
+func renderWebhookTemplate(webhook *domain.Webhook, payload []byte) ([]byte, error) {
+	var rendered []byte
+
+	switch webhook.TemplateType {
+	case domain.WebhookTemplateNone:
+		return payload, nil
+
+	case domain.WebhookTemplateGoTemplate:
+		tmpl, err := parseGoWebhookTemplate(webhook.Template)
+		if err != nil {
+			return nil, fmt.Errorf("webhook template: %w", err)
+		}
+
+		var data interface{}
+		if err := json.Unmarshal(payload, &data); err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&limitedWriter{w: &buf, remaining: maxWebhookTemplateOutput}, data); err != nil {
+			return nil, fmt.Errorf("webhook template: %w", err)
+		}
+		rendered = bytes.TrimSpace(buf.Bytes())
+
+	case domain.WebhookTemplateJQ:
+		program, err := jq.Compile(webhook.Template)
+		if err != nil {
+			return nil, fmt.Errorf("webhook template: %w", err)
+		}
+		if rendered, err = program.RunJSON(payload); err != nil {
+			return nil, fmt.Errorf("webhook template: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("webhook template: unsupported template type %q", webhook.TemplateType)
+	}
+
+	if len(rendered) > maxWebhookTemplateOutput {
+		return nil, fmt.Errorf("webhook template: output exceeds %d bytes", maxWebhookTemplateOutput)
+	}
+	if !json.Valid(rendered) {
+		return nil, fmt.Errorf("webhook template: output is not valid JSON")
+	}
+	return rendered, nil
+}
+
+func parseGoWebhookTemplate(source string) (*template.Template, error) {
+	return template.New("webhook").Funcs(webhookTemplateFuncs).Option("missingkey=zero").Parse(source)
+}
+
+// limitedWriter fails once more than remaining bytes have been written, stopping runaway templates early
+type limitedWriter struct {
+	w         *bytes.Buffer
+	remaining int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.remaining {
+		return 0, fmt.Errorf("output exceeds %d bytes", maxWebhookTemplateOutput)
+	}
+	l.remaining -= len(p)
+	return l.w.Write(p)
+}
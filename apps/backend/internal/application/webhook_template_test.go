@@ -0,0 +1,111 @@
+package application
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const webhookTemplatePayload = `{"event":"alert.created","timestamp":"2025-11-12T10:00:00Z","data":{"title":"Configuration drift","severity":"high","agentName":"billing \"bot\"","tags":["prod","pci"]}}`
+
+func TestRenderWebhookTemplate(t *testing.T) {
+	tests := []struct {
+		name         string
+		templateType domain.WebhookTemplateType
+		template     string
+		expected     string
+	}{
+		{
+			name:         "no template delivers the payload unchanged",
+			templateType: domain.WebhookTemplateNone,
+			expected:     webhookTemplatePayload,
+		},
+		{
+			name:         "go template slack message",
+			templateType: domain.WebhookTemplateGoTemplate,
+			template:     `{"text": {{ json (printf "[%s] %s: %s" (upper .data.severity) .data.title .data.agentName) }}}`,
+			expected:     `{"text":"[HIGH] Configuration drift: billing \"bot\""}`,
+		},
+		{
+			name:         "go template helpers",
+			templateType: domain.WebhookTemplateGoTemplate,
+			template:     `{"labels": {{ json (join "," .data.tags) }}, "assignee": {{ json (default "unassigned" .data.owner) }}}`,
+			expected:     `{"labels":"prod,pci","assignee":"unassigned"}`,
+		},
+		{
+			name:         "jq jira issue",
+			templateType: domain.WebhookTemplateJQ,
+			template:     `{fields: {project: {key: "SEC"}, summary: "\(.data.title) (\(.data.agentName))", labels: .data.tags, priority: {name: (if .data.severity == "high" then "High" else "Medium" end)}}}`,
+			expected:     `{"fields":{"project":{"key":"SEC"},"summary":"Configuration drift (billing \"bot\")","labels":["prod","pci"],"priority":{"name":"High"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateType, template, err := compileWebhookTemplate(tt.templateType, tt.template)
+			require.NoError(t, err)
+
+			webhook := &domain.Webhook{TemplateType: templateType, Template: template}
+			rendered, err := renderWebhookTemplate(webhook, []byte(webhookTemplatePayload))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(rendered))
+		})
+	}
+}
+
+func TestRenderWebhookTemplateRejectsInvalidOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		webhook  *domain.Webhook
+		errorMsg string
+	}{
+		{
+			name:     "go template producing invalid JSON",
+			webhook:  &domain.Webhook{TemplateType: domain.WebhookTemplateGoTemplate, Template: `{"text": {{ .data.title }}}`},
+			errorMsg: "not valid JSON",
+		},
+		{
+			name:     "jq producing several values",
+			webhook:  &domain.Webhook{TemplateType: domain.WebhookTemplateJQ, Template: `.data.tags[]`},
+			errorMsg: "exactly one value",
+		},
+		{
+			name:     "go template output too large",
+			webhook:  &domain.Webhook{TemplateType: domain.WebhookTemplateGoTemplate, Template: `{{ range .data.tags }}` + strings.Repeat("x", maxWebhookTemplateOutput/2+1) + `{{ end }}`},
+			errorMsg: "exceeds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := renderWebhookTemplate(tt.webhook, []byte(webhookTemplatePayload))
+			assert.ErrorContains(t, err, tt.errorMsg)
+		})
+	}
+}
+
+func TestCompileWebhookTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		templateType domain.WebhookTemplateType
+		template     string
+	}{
+		{"type without template", domain.WebhookTemplateJQ, "  "},
+		{"template without type", domain.WebhookTemplateNone, `{"text": "hi"}`},
+		{"unknown type", "xslt", `<xsl/>`},
+		{"go template syntax", domain.WebhookTemplateGoTemplate, `{{ .data.title `},
+		{"go template unknown function", domain.WebhookTemplateGoTemplate, `{{ shout .data.title }}`},
+		{"jq syntax", domain.WebhookTemplateJQ, `{text: .data.title`},
+		{"too long", domain.WebhookTemplateJQ, strings.Repeat(" .", maxWebhookTemplateLength)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := compileWebhookTemplate(tt.templateType, tt.template)
+			assert.ErrorIs(t, err, ErrInvalidWebhookTemplate)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+// Package cel compiles and evaluates the Common Expression Language rules that
+// organizations configure (webhook filters, policy conditions) with cel-go.
+//
+// Besides the standard library, expressions can use the cel-go string
+// extensions (lowerAscii, upperAscii, split, ...) and contains on lists, which
+// stored filters rely on. Inputs are plain Go values, normalized through JSON so
+// structs are addressed by their json field names.
+package cel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	celgo "github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/ext"
+)
+
+// MaxExpressionLength bounds stored expressions to keep evaluation cheap
+const MaxExpressionLength = 4096
+
+// maxEvaluationCost bounds the work a single evaluation may do, e.g. nested
+// comprehensions over large lists
+const maxEvaluationCost = 1_000_000
+
+// maxCachedPrograms bounds a Cache; it is emptied when full
+const maxCachedPrograms = 1024
+
+// Program is a compiled expression that can be evaluated many times
+type Program struct {
+	source  string
+	program celgo.Program
+}
+
+// Compile compiles an expression. When variables are given, the expression is
+// type-checked against them, so typos and unknown functions surface at save
+// time instead of silently never matching. Without variables it is only parsed.
+func Compile(source string, variables ...string) (*Program, error) {
+	env, err := newEnv(variables)
+	if err != nil {
+		return nil, err
+	}
+	return compile(env, source, len(variables) > 0)
+}
+
+func newEnv(variables []string) (*celgo.Env, error) {
+	options := []celgo.EnvOption{
+		ext.Strings(),
+		celgo.CrossTypeNumericComparisons(true),
+		celgo.Function("contains",
+			celgo.MemberOverload("list_contains_dyn",
+				[]*celgo.Type{celgo.ListType(celgo.DynType), celgo.DynType}, celgo.BoolType,
+				celgo.BinaryBinding(listContains))),
+	}
+	for _, v := range variables {
+		options = append(options, celgo.Variable(v, celgo.DynType))
+	}
+	return celgo.NewEnv(options...)
+}
+
+func listContains(list, value ref.Val) ref.Val {
+	container, ok := list.(traits.Container)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(list)
+	}
+	return container.Contains(value)
+}
+
+func compile(env *celgo.Env, source string, check bool) (*Program, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	if len(source) > MaxExpressionLength {
+		return nil, fmt.Errorf("expression exceeds %d characters", MaxExpressionLength)
+	}
+
+	var ast *celgo.Ast
+	var issues *celgo.Issues
+	if check {
+		ast, issues = env.Compile(source)
+	} else {
+		ast, issues = env.Parse(source)
+	}
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	program, err := env.Program(ast, celgo.CostLimit(maxEvaluationCost))
+	if err != nil {
+		return nil, err
+	}
+	return &Program{source: source, program: program}, nil
+}
+
+// Source returns the expression text the program was compiled from
+func (p *Program) Source() string {
+	return p.source
+}
+
+// Eval evaluates the program against the given variables
+func (p *Program) Eval(vars map[string]interface{}) (interface{}, error) {
+	result, err := p.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return result.Value(), nil
+}
+
+// EvalBool evaluates the program and requires a boolean result
+func (p *Program) EvalBool(vars map[string]interface{}) (bool, error) {
+	result, err := p.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := result.(types.Bool)
+	if !ok {
+		return false, fmt.Errorf("expression must evaluate to bool, got %s", result.Type().TypeName())
+	}
+	return bool(b), nil
+}
+
+func (p *Program) eval(vars map[string]interface{}) (ref.Val, error) {
+	normalized := make(map[string]interface{}, len(vars))
+	for name, value := range vars {
+		v, err := Normalize(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for '%s': %w", name, err)
+		}
+		normalized[name] = v
+	}
+
+	result, _, err := p.program.Eval(normalized)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Cache compiles expressions against a fixed set of variables and keeps the
+// programs, so a stored rule is compiled when it is saved and reused on every
+// evaluation instead of being compiled again
+type Cache struct {
+	env      *celgo.Env
+	envErr   error
+	mu       sync.RWMutex
+	programs map[string]*Program
+}
+
+// NewCache creates a cache whose expressions are checked against variables
+func NewCache(variables ...string) *Cache {
+	env, err := newEnv(variables)
+	return &Cache{env: env, envErr: err, programs: make(map[string]*Program)}
+}
+
+// Compile returns the cached program for source, compiling it on first use.
+// Expressions that fail to compile are not cached.
+func (c *Cache) Compile(source string) (*Program, error) {
+	if c.envErr != nil {
+		return nil, c.envErr
+	}
+	source = strings.TrimSpace(source)
+
+	c.mu.RLock()
+	program, ok := c.programs[source]
+	c.mu.RUnlock()
+	if ok {
+		return program, nil
+	}
+
+	program, err := compile(c.env, source, true)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if len(c.programs) >= maxCachedPrograms {
+		c.programs = make(map[string]*Program)
+	}
+	c.programs[source] = program
+	c.mu.Unlock()
+	return program, nil
+}
+
+// Normalize converts a Go value into the evaluator's value model: nil, bool,
+// int64, float64, string, []interface{} and map[string]interface{}
+func Normalize(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	return convertNumbers(decoded), nil
+}
+
+func convertNumbers(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		f, _ := value.Float64()
+		return f
+	case []interface{}:
+		for i := range value {
+			value[i] = convertNumbers(value[i])
+		}
+		return value
+	case map[string]interface{}:
+		for k := range value {
+			value[k] = convertNumbers(value[k])
+		}
+		return value
+	}
+	return v
+}
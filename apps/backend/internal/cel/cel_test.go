@@ -0,0 +1,145 @@
+package cel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testVars() map[string]interface{} {
+	return map[string]interface{}{
+		"event": map[string]interface{}{
+			"type":          "verification.completed",
+			"driftDetected": true,
+			"trustScore":    0.42,
+			"durationMs":    120,
+		},
+		"agent": map[string]interface{}{
+			"name": "billing-bot",
+			"tags": []string{"prod", "environment:prod"},
+		},
+	}
+}
+
+func TestEvalBool(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected bool
+	}{
+		{"drift and tag", `event.driftDetected && agent.tags.contains("prod")`, true},
+		{"missing tag", `agent.tags.contains("staging")`, false},
+		{"in operator", `"prod" in agent.tags`, true},
+		{"numeric comparison", `event.trustScore < 0.5 && event.durationMs >= 100`, true},
+		{"int and double equality", `event.durationMs == 120.0`, true},
+		{"string functions", `agent.name.startsWith("billing") && !agent.name.endsWith("-dev")`, true},
+		{"matches", `agent.name.matches("^[a-z]+-bot$")`, true},
+		{"size", `size(agent.tags) == 2 && agent.name.size() == 11`, true},
+		{"has present", `has(event.driftDetected)`, true},
+		{"has missing", `has(event.capabilityDrift)`, false},
+		{"exists", `agent.tags.exists(t, t.startsWith("environment:"))`, true},
+		{"all", `agent.tags.all(t, t.contains("prod"))`, true},
+		{"ternary", `(event.trustScore > 0.8 ? "high" : "low") == "low"`, true},
+		{"short circuit avoids missing key", `false && event.missing`, false},
+		{"arithmetic precedence", `1 + 2 * 3 == 7 && 7 % 4 == 3`, true},
+		{"list literal", `event.type in ["verification.completed", "verification.failed"]`, true},
+		{"index", `agent.tags[0] == "prod" && event["type"].contains("verification")`, true},
+		{"string extensions", `agent.name.upperAscii() == "BILLING-BOT" && agent.name.split("-").size() == 2`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.expr, "event", "agent")
+			require.NoError(t, err)
+
+			result, err := program.EvalBool(testVars())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"empty", "   "},
+		{"unbalanced", `(event.driftDetected`},
+		{"dangling operator", `event.driftDetected &&`},
+		{"unknown function", `lookup(agent.name)`},
+		{"unknown method", `agent.name.shout()`},
+		{"undeclared variable", `evnt.driftDetected`},
+		{"unterminated string", `agent.name == "bot`},
+		{"has without selection", `has(agent)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.expr, "event", "agent")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestComprehensionVariableIsScoped(t *testing.T) {
+	_, err := Compile(`agent.tags.exists(t, t == "prod") && t == "x"`, "agent")
+	assert.Error(t, err)
+
+	_, err = Compile(`agent.tags.exists(t, t == "prod")`, "agent")
+	assert.NoError(t, err)
+}
+
+func TestEvalErrors(t *testing.T) {
+	program, err := Compile(`event.missing == true`)
+	require.NoError(t, err)
+
+	_, err = program.EvalBool(testVars())
+	assert.Error(t, err)
+
+	program, err = Compile(`agent.name`)
+	require.NoError(t, err)
+
+	_, err = program.EvalBool(testVars())
+	assert.Error(t, err, "non-bool result must be rejected")
+}
+
+func TestNormalizeStruct(t *testing.T) {
+	type sample struct {
+		Name  string   `json:"name"`
+		Count int      `json:"count"`
+		Tags  []string `json:"tags"`
+	}
+
+	program, err := Compile(`s.name == "x" && s.count == 3 && s.tags.contains("a")`, "s")
+	require.NoError(t, err)
+
+	result, err := program.EvalBool(map[string]interface{}{
+		"s": sample{Name: "x", Count: 3, Tags: []string{"a"}},
+	})
+	require.NoError(t, err)
+	assert.True(t, result)
+}
+
+func TestCacheReusesPrograms(t *testing.T) {
+	cache := NewCache("event", "agent")
+
+	first, err := cache.Compile(`event.driftDetected`)
+	require.NoError(t, err)
+	second, err := cache.Compile("  event.driftDetected ")
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	_, err = cache.Compile(`evnt.driftDetected`)
+	assert.Error(t, err, "the cache checks against its variables")
+}
+
+func TestEvaluationCostIsBounded(t *testing.T) {
+	program, err := Compile(`items.all(a, items.all(b, items.all(c, a + b + c >= 0)))`, "items")
+	require.NoError(t, err)
+
+	items := make([]int, 200)
+	_, err = program.EvalBool(map[string]interface{}{"items": items})
+	assert.Error(t, err)
+}
@@ -2,9 +2,14 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
 )
 
 // Config holds all configuration for the application
@@ -14,6 +19,171 @@ type Config struct {
 	Redis    RedisConfig
 	JWT      JWTConfig
 	OAuth    OAuthConfig
+	Jobs     JobsConfig
+
+	// Per-agent limits by organization plan tier (AGENT_QUOTA_<TIER>)
+	AgentQuotas map[string]domain.AgentQuota
+
+	// Per-organization API rate limits by endpoint class (ORG_RATE_LIMIT_<CLASS>), unless an
+	// organization sets its own
+	OrgRateLimits map[string]domain.RateLimit
+
+	// Request deadlines by endpoint class (REQUEST_TIMEOUT_<CLASS>) and call budgets by
+	// dependency (DEPENDENCY_TIMEOUT_<DEPENDENCY>)
+	Timeouts domain.TimeoutBudgets
+
+	// Request body limits by endpoint class (REQUEST_BODY_LIMIT_<CLASS>) and the response budget
+	// of list endpoints (RESPONSE_BUDGET_LIST), in bytes
+	Payloads domain.PayloadBudgets
+
+	// Response compression: default, best_speed, best_compression or disabled
+	ResponseCompression string
+
+	// Lifetime of the X.509 certificates issued to verified agents
+	AgentCertificateValidity time.Duration
+
+	// How long the response of a request sent with an Idempotency-Key is replayed to retries
+	IdempotencyWindow time.Duration
+
+	// GeoIP CSV file locating the addresses SDK devices were last used from; empty locates
+	// private and loopback addresses only
+	GeoIPDatabasePath string
+
+	Webhooks WebhooksConfig
+	GRPC     GRPCConfig
+	GraphQL  GraphQLConfig
+
+	CircuitBreakers CircuitBreakerConfig
+
+	MTLS MTLSConfig
+
+	IdentityCache IdentityCacheConfig
+
+	Operator OperatorConfig
+
+	EventBus EventBusConfig
+
+	Tracing TracingConfig
+
+	EmailQueue EmailQueueConfig
+
+	LoginProtection LoginProtectionConfig
+
+	Residency ResidencyConfig
+}
+
+// GRPCConfig holds the gRPC agent verification API. It is disabled without a port; gRPC is
+// served over TLS with the given certificate, as HTTP/2 requires it.
+type GRPCConfig struct {
+	Port        string
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+// GraphQLConfig holds the limits of the dashboard GraphQL API; queries exceeding them are
+// rejected before they run
+type GraphQLConfig struct {
+	MaxDepth      int // Nesting of selections
+	MaxComplexity int // Field costs multiplied by the sizes of the lists they are selected in
+}
+
+// WebhooksConfig holds the shared egress of webhook deliveries. Organizations without a
+// dedicated proxy send through EgressProxyURL, whose source addresses are published as EgressIPs.
+type WebhooksConfig struct {
+	EgressProxyURL string   // Forward proxy for all deliveries; empty sends directly
+	EgressIPs      []string // Addresses or CIDR ranges receivers can allowlist
+}
+
+// EventBusConfig holds the message broker verification, drift, trust score and alert events are
+// published to. Publishing is off without a driver.
+type EventBusConfig struct {
+	Driver      string        // none, nats or kafka
+	URL         string        // NATS server URL, or the Kafka REST Proxy URL
+	TopicPrefix string        // Prepended to event types to name subjects and topics
+	Retention   time.Duration // How long published events are kept in the outbox
+}
+
+// EmailQueueConfig holds the transactional email send queue. The email provider itself is
+// configured through the EMAIL_* variables read by the email package.
+type EmailQueueConfig struct {
+	MaxAttempts         int           // Sends attempted before a message is given up on
+	Retention           time.Duration // How long sent and failed messages are kept in the log
+	BounceWebhookSecret string        // Shared secret of the provider bounce webhooks; they are refused without one
+}
+
+// LoginProtectionConfig holds the brute force and credential stuffing protection of password
+// logins. Failures per email and per IP address back off, then need a CAPTCHA when a secret is
+// set, and finally lock the account or block the address.
+type LoginProtectionConfig struct {
+	Window            time.Duration // Failures older than this no longer count
+	EmailBackoffAfter int
+	EmailCaptchaAfter int
+	EmailLockoutAfter int
+	IPBackoffAfter    int
+	IPCaptchaAfter    int
+	IPLockoutAfter    int
+	BackoffBase       time.Duration // First wait once backing off; doubles with each further failure
+	BackoffMax        time.Duration
+	LockoutDuration   time.Duration
+	Retention         time.Duration // How long login attempts are kept
+	CaptchaSecret     string        // Secret of the CAPTCHA site; CAPTCHA escalation is off without one
+	CaptchaVerifyURL  string        // siteverify endpoint of reCAPTCHA (default), hCaptcha or Turnstile
+}
+
+// ResidencyConfig holds the database clusters of the regions organizations can be resident in.
+// The control plane's database is the cluster of the home region; without other clusters every
+// organization's data is stored there.
+type ResidencyConfig struct {
+	HomeRegion     domain.DataRegion
+	RegionClusters map[domain.DataRegion]string // PostgreSQL connection string of each other region's cluster
+}
+
+// Enabled reports whether organizations can be resident outside the home region
+func (c ResidencyConfig) Enabled() bool {
+	return len(c.RegionClusters) > 0
+}
+
+// TracingConfig holds the OTLP collector spans are exported to, read from the standard OTEL_*
+// variables. Tracing is off without an endpoint.
+type TracingConfig struct {
+	Endpoint    string            // Collector base URL; spans are posted to <endpoint>/v1/traces
+	Headers     map[string]string // key=value pairs sent with every export
+	ServiceName string
+	SampleRatio float64 // Share of new traces recorded; traces continued from a caller follow its decision
+}
+
+// CircuitBreakerConfig tunes the circuit breakers of outbound calls to external dependencies
+type CircuitBreakerConfig struct {
+	FailureThreshold int           // Consecutive failures of an endpoint that open its circuit
+	Cooldown         time.Duration // How long an open circuit fails calls before a trial call
+}
+
+// MTLSConfig holds client certificate (mTLS) authentication of agents on the HTTP API. With a
+// certificate and key the API is served over TLS and asks agents for the certificate the platform
+// CA issued them; behind a TLS-terminating proxy, the proxy forwards it in ClientCertHeader.
+type MTLSConfig struct {
+	TLSCertFile      string
+	TLSKeyFile       string
+	ClientCertHeader string // Header the proxy sets to the URL-encoded PEM client certificate it verified
+}
+
+// IdentityCacheConfig holds how long the records looked up on every verification stay cached, in
+// Redis when it is available and in memory otherwise. A TTL of zero turns that cache off.
+type IdentityCacheConfig struct {
+	AgentTTL     time.Duration // Agents by ID
+	APIKeyTTL    time.Duration // API keys by hash
+	MCPServerTTL time.Duration // MCP servers by ID
+}
+
+// OperatorConfig holds the platform operator API the hosting team runs the deployment with. It
+// is served on its own port, apart from the tenant API, and is disabled without one.
+type OperatorConfig struct {
+	Port            string
+	AllowedNetworks []*net.IPNet // Networks that can reach the operator API; empty allows every network
+	BootstrapToken  string       // Token of the first operator, created while there are no operators
+	// How often each instance applies runtime settings operators changed, when the change was
+	// made on another instance
+	SettingsRefreshInterval time.Duration
 }
 
 // ServerConfig holds server configuration
@@ -34,6 +204,10 @@ type DatabaseConfig struct {
 	SSLMode         string
 	MaxConnections  int
 	ConnMaxLifetime time.Duration
+
+	// Schema migrations
+	AutoMigrate          bool          // Apply pending migrations on startup; otherwise run cmd/migrate
+	MigrationLockTimeout time.Duration // How long each statement of an online migration waits for locks
 }
 
 // RedisConfig holds Redis configuration
@@ -73,6 +247,34 @@ type OktaProvider struct {
 	RedirectURL  string
 }
 
+// JobsConfig holds background job configuration. An interval of zero disables the job.
+type JobsConfig struct {
+	LeaseTTL                        time.Duration // How long the leader instance holds the job lease without renewing it
+	AttestationExpiryInterval       time.Duration
+	ConfidenceRecalculationInterval time.Duration
+	ConfidenceAlertThreshold        float64 // MCP servers whose confidence score falls below this raise an alert
+	ArtifactLifecycleInterval       time.Duration
+	MCPDiscoveryInterval            time.Duration // How often registered MCP servers are asked for their capabilities
+	ChangeWindowInterval            time.Duration // How often staged configuration changes are applied, monitored and rolled back
+	AnomalyDetectionInterval        time.Duration // How often agent activity is compared to its behavioral baseline
+	AnomalyBaselineWindow           time.Duration // How much verification history agent baselines are learned from
+	AnomalyZScoreThreshold          float64       // Activity more standard deviations above the baseline is an anomaly
+	SharedKeyDetectionInterval      time.Duration // How often agents are checked for public keys they share
+	LatencySLOInterval              time.Duration // How often agent-MCP connection latencies are compared to their SLOs
+	VerificationExportInterval      time.Duration // How often queued verification event exports are written
+	IncidentSLAInterval             time.Duration // How often unresolved incidents are checked against their severity SLAs
+	AttestationCadenceInterval      time.Duration // How often verified MCP servers are checked against the attestation cadence of their criticality
+	TicketSyncInterval              time.Duration // How often Jira/ServiceNow tickets of open capability requests and incidents are synced back
+	CapabilityExpiryInterval        time.Duration // How often time-bound capabilities past their expiry are revoked
+	MCPHealthCheckInterval          time.Duration // How often the URLs of MCP servers are probed
+	MCPHealthFailureThreshold       int           // Consecutive failed health checks that suspend an MCP server
+	IdempotencyPurgeInterval        time.Duration // How often idempotency records past their replay window are deleted
+	UsageRollupInterval             time.Duration // How often verification events are rolled up into daily per-agent and per-API key usage
+	DomainEventRelayInterval        time.Duration // How often recorded domain events are relayed to the event bus
+	EmailQueueInterval              time.Duration // How often queued transactional emails are sent
+	LoginAttemptPurgeInterval       time.Duration // How often login attempts past their retention are deleted
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	config := &Config{
@@ -91,6 +293,9 @@ func Load() (*Config, error) {
 		SSLMode:         getEnv("POSTGRES_SSL_MODE", "disable"),
 		MaxConnections:  getEnvAsInt("POSTGRES_MAX_CONNECTIONS", 25),
 		ConnMaxLifetime: getEnvAsDuration("POSTGRES_CONN_MAX_LIFETIME", 5*time.Minute),
+
+		AutoMigrate:          getEnvAsBool("MIGRATIONS_AUTO_APPLY", true),
+		MigrationLockTimeout: getEnvAsDuration("MIGRATIONS_LOCK_TIMEOUT", 5*time.Second),
 	},
 		Redis: RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
@@ -121,7 +326,122 @@ func Load() (*Config, error) {
 				RedirectURL:  getEnv("OKTA_REDIRECT_URL", "http://localhost:8080/api/v1/auth/callback/okta"),
 			},
 		},
+		Jobs: JobsConfig{
+			LeaseTTL:                        getEnvAsDuration("JOBS_LEASE_TTL", 30*time.Second),
+			AttestationExpiryInterval:       getEnvAsDuration("JOBS_ATTESTATION_EXPIRY_INTERVAL", time.Hour),
+			ConfidenceRecalculationInterval: getEnvAsDuration("JOBS_CONFIDENCE_RECALCULATION_INTERVAL", time.Hour),
+			ConfidenceAlertThreshold:        getEnvAsFloat("MCP_CONFIDENCE_ALERT_THRESHOLD", 50),
+			ArtifactLifecycleInterval:       getEnvAsDuration("JOBS_ARTIFACT_LIFECYCLE_INTERVAL", 24*time.Hour),
+			MCPDiscoveryInterval:            getEnvAsDuration("JOBS_MCP_DISCOVERY_INTERVAL", 6*time.Hour),
+			ChangeWindowInterval:            getEnvAsDuration("JOBS_CHANGE_WINDOW_INTERVAL", time.Minute),
+			AnomalyDetectionInterval:        getEnvAsDuration("JOBS_ANOMALY_DETECTION_INTERVAL", time.Hour),
+			AnomalyBaselineWindow:           getEnvAsDuration("ANOMALY_BASELINE_WINDOW", 14*24*time.Hour),
+			AnomalyZScoreThreshold:          getEnvAsFloat("ANOMALY_ZSCORE_THRESHOLD", 3),
+			SharedKeyDetectionInterval:      getEnvAsDuration("JOBS_SHARED_KEY_DETECTION_INTERVAL", time.Hour),
+			LatencySLOInterval:              getEnvAsDuration("JOBS_LATENCY_SLO_INTERVAL", 5*time.Minute),
+			VerificationExportInterval:      getEnvAsDuration("JOBS_VERIFICATION_EXPORT_INTERVAL", 30*time.Second),
+			IncidentSLAInterval:             getEnvAsDuration("JOBS_INCIDENT_SLA_INTERVAL", 5*time.Minute),
+			AttestationCadenceInterval:      getEnvAsDuration("JOBS_ATTESTATION_CADENCE_INTERVAL", time.Hour),
+			TicketSyncInterval:              getEnvAsDuration("JOBS_TICKET_SYNC_INTERVAL", 5*time.Minute),
+			CapabilityExpiryInterval:        getEnvAsDuration("JOBS_CAPABILITY_EXPIRY_INTERVAL", 5*time.Minute),
+			MCPHealthCheckInterval:          getEnvAsDuration("JOBS_MCP_HEALTH_CHECK_INTERVAL", 5*time.Minute),
+			MCPHealthFailureThreshold:       getEnvAsInt("MCP_HEALTH_FAILURE_THRESHOLD", 3),
+			IdempotencyPurgeInterval:        getEnvAsDuration("JOBS_IDEMPOTENCY_PURGE_INTERVAL", time.Hour),
+			UsageRollupInterval:             getEnvAsDuration("JOBS_USAGE_ROLLUP_INTERVAL", 15*time.Minute),
+			DomainEventRelayInterval:        getEnvAsDuration("JOBS_DOMAIN_EVENT_RELAY_INTERVAL", 5*time.Second),
+			EmailQueueInterval:              getEnvAsDuration("JOBS_EMAIL_QUEUE_INTERVAL", 10*time.Second),
+			LoginAttemptPurgeInterval:       getEnvAsDuration("JOBS_LOGIN_ATTEMPT_PURGE_INTERVAL", time.Hour),
+		},
+		AgentCertificateValidity: getEnvAsDuration("AGENT_CERTIFICATE_VALIDITY", 24*time.Hour),
+		IdempotencyWindow:        getEnvAsDuration("IDEMPOTENCY_WINDOW", 24*time.Hour),
+		GeoIPDatabasePath:        getEnv("GEOIP_DATABASE_PATH", ""),
+		Webhooks: WebhooksConfig{
+			EgressProxyURL: getEnv("WEBHOOK_EGRESS_PROXY_URL", ""),
+			EgressIPs:      getEnvAsList("WEBHOOK_EGRESS_IPS"),
+		},
+		GRPC: GRPCConfig{
+			Port:        getEnv("GRPC_PORT", ""),
+			TLSCertFile: getEnv("GRPC_TLS_CERT_FILE", ""),
+			TLSKeyFile:  getEnv("GRPC_TLS_KEY_FILE", ""),
+		},
+		GraphQL: GraphQLConfig{
+			MaxDepth:      getEnvAsInt("GRAPHQL_MAX_DEPTH", 8),
+			MaxComplexity: getEnvAsInt("GRAPHQL_MAX_COMPLEXITY", 10000),
+		},
+		CircuitBreakers: CircuitBreakerConfig{
+			FailureThreshold: getEnvAsInt("CIRCUIT_BREAKER_FAILURE_THRESHOLD", 5),
+			Cooldown:         getEnvAsDuration("CIRCUIT_BREAKER_COOLDOWN", 30*time.Second),
+		},
+		MTLS: MTLSConfig{
+			TLSCertFile:      getEnv("HTTP_TLS_CERT_FILE", ""),
+			TLSKeyFile:       getEnv("HTTP_TLS_KEY_FILE", ""),
+			ClientCertHeader: getEnv("MTLS_CLIENT_CERT_HEADER", ""),
+		},
+		IdentityCache: IdentityCacheConfig{
+			AgentTTL:     getEnvAsDuration("CACHE_AGENT_TTL", 5*time.Minute),
+			APIKeyTTL:    getEnvAsDuration("CACHE_API_KEY_TTL", 5*time.Minute),
+			MCPServerTTL: getEnvAsDuration("CACHE_MCP_SERVER_TTL", 5*time.Minute),
+		},
+		EmailQueue: EmailQueueConfig{
+			MaxAttempts:         getEnvAsInt("EMAIL_MAX_ATTEMPTS", 8),
+			Retention:           getEnvAsDuration("EMAIL_RETENTION", 30*24*time.Hour),
+			BounceWebhookSecret: getEnv("EMAIL_WEBHOOK_SECRET", ""),
+		},
+		LoginProtection: LoginProtectionConfig{
+			Window:            getEnvAsDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			EmailBackoffAfter: getEnvAsInt("LOGIN_EMAIL_BACKOFF_AFTER", 3),
+			EmailCaptchaAfter: getEnvAsInt("LOGIN_EMAIL_CAPTCHA_AFTER", 3),
+			EmailLockoutAfter: getEnvAsInt("LOGIN_EMAIL_LOCKOUT_AFTER", 10),
+			IPBackoffAfter:    getEnvAsInt("LOGIN_IP_BACKOFF_AFTER", 20),
+			IPCaptchaAfter:    getEnvAsInt("LOGIN_IP_CAPTCHA_AFTER", 10),
+			IPLockoutAfter:    getEnvAsInt("LOGIN_IP_LOCKOUT_AFTER", 50),
+			BackoffBase:       getEnvAsDuration("LOGIN_BACKOFF_BASE", time.Second),
+			BackoffMax:        getEnvAsDuration("LOGIN_BACKOFF_MAX", time.Minute),
+			LockoutDuration:   getEnvAsDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			Retention:         getEnvAsDuration("LOGIN_ATTEMPT_RETENTION", 30*24*time.Hour),
+			CaptchaSecret:     getEnv("CAPTCHA_SECRET", ""),
+			CaptchaVerifyURL:  getEnv("CAPTCHA_VERIFY_URL", ""),
+		},
+		EventBus: EventBusConfig{
+			Driver:      getEnv("EVENT_BUS_DRIVER", "none"),
+			URL:         getEnv("EVENT_BUS_URL", ""),
+			TopicPrefix: getEnv("EVENT_BUS_TOPIC_PREFIX", "aim.events"),
+			Retention:   getEnvAsDuration("EVENT_BUS_RETENTION", 7*24*time.Hour),
+		},
+		Tracing: TracingConfig{
+			Endpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+			Headers:     getEnvAsMap("OTEL_EXPORTER_OTLP_HEADERS"),
+			ServiceName: getEnv("OTEL_SERVICE_NAME", "aim-backend"),
+			SampleRatio: getEnvAsFloat("OTEL_TRACES_SAMPLER_ARG", 1.0),
+		},
+		ResponseCompression: getEnv("RESPONSE_COMPRESSION", "default"),
+	}
+
+	agentQuotas, err := getAgentQuotas()
+	if err != nil {
+		return nil, err
+	}
+	config.AgentQuotas = agentQuotas
+
+	orgRateLimits, err := getOrgRateLimits()
+	if err != nil {
+		return nil, err
 	}
+	config.OrgRateLimits = orgRateLimits
+	config.Timeouts = getTimeoutBudgets()
+	config.Payloads = getPayloadBudgets()
+
+	operator, err := getOperatorConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.Operator = operator
+
+	residency, err := getResidencyConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.Residency = residency
 
 	// Validate required fields
 	if err := config.Validate(); err != nil {
@@ -144,6 +464,22 @@ func (c *Config) Validate() error {
 	// OAuth providers are now optional since we support email/password authentication
 	// Validation removed - OAuth configuration is checked at runtime when needed
 
+	if c.GRPC.Port != "" && (c.GRPC.TLSCertFile == "" || c.GRPC.TLSKeyFile == "") {
+		return fmt.Errorf("GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE are required when GRPC_PORT is set")
+	}
+
+	if (c.MTLS.TLSCertFile == "") != (c.MTLS.TLSKeyFile == "") {
+		return fmt.Errorf("HTTP_TLS_CERT_FILE and HTTP_TLS_KEY_FILE must be set together")
+	}
+
+	if c.EventBus.Driver != "" && c.EventBus.Driver != "none" && c.EventBus.URL == "" {
+		return fmt.Errorf("EVENT_BUS_URL is required when EVENT_BUS_DRIVER is set")
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("OTEL_TRACES_SAMPLER_ARG must be between 0 and 1")
+	}
+
 	return nil
 }
 
@@ -168,6 +504,18 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return value
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
@@ -180,6 +528,175 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	return value
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvAsList reads a comma-separated list, skipping empty entries
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvAsMap reads comma-separated key=value pairs, as in OTEL_EXPORTER_OTLP_HEADERS; values
+// may be percent-encoded
+func getEnvAsMap(key string) map[string]string {
+	values := make(map[string]string)
+	for _, pair := range getEnvAsList(key) {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(strings.TrimSpace(value)); err == nil {
+			value = unescaped
+		}
+		values[strings.TrimSpace(name)] = value
+	}
+	return values
+}
+
+// getAgentQuotas reads AGENT_QUOTA_FREE, AGENT_QUOTA_PRO and AGENT_QUOTA_ENTERPRISE, each
+// "<verifications per minute>,<burst>,<max concurrent>" (0 is unlimited). Unset tiers keep
+// their default limits.
+func getAgentQuotas() (map[string]domain.AgentQuota, error) {
+	quotas := make(map[string]domain.AgentQuota)
+	for tier := range domain.DefaultAgentQuotas {
+		key := "AGENT_QUOTA_" + strings.ToUpper(tier)
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		parts := strings.Split(value, ",")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%s must be <verifications per minute>,<burst>,<max concurrent>", key)
+		}
+		limits := make([]int, len(parts))
+		for i, part := range parts {
+			limit, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || limit < 0 {
+				return nil, fmt.Errorf("%s must be <verifications per minute>,<burst>,<max concurrent>", key)
+			}
+			limits[i] = limit
+		}
+		quotas[tier] = domain.AgentQuota{VerificationsPerMinute: limits[0], Burst: limits[1], MaxConcurrent: limits[2]}
+	}
+	return quotas, nil
+}
+
+// getOperatorConfig reads OPERATOR_PORT, OPERATOR_ALLOWED_CIDRS (comma-separated CIDR ranges),
+// OPERATOR_BOOTSTRAP_TOKEN and RUNTIME_SETTINGS_REFRESH_INTERVAL
+func getOperatorConfig() (OperatorConfig, error) {
+	config := OperatorConfig{
+		Port:                    getEnv("OPERATOR_PORT", ""),
+		BootstrapToken:          getEnv("OPERATOR_BOOTSTRAP_TOKEN", ""),
+		SettingsRefreshInterval: getEnvAsDuration("RUNTIME_SETTINGS_REFRESH_INTERVAL", 30*time.Second),
+	}
+	for _, cidr := range getEnvAsList("OPERATOR_ALLOWED_CIDRS") {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return config, fmt.Errorf("OPERATOR_ALLOWED_CIDRS must be comma-separated CIDR ranges: %w", err)
+		}
+		config.AllowedNetworks = append(config.AllowedNetworks, network)
+	}
+	return config, nil
+}
+
+// getResidencyConfig reads DATA_RESIDENCY_HOME_REGION, the region of the control plane's database,
+// and DATABASE_URL_<REGION> for the clusters of the other regions (for example DATABASE_URL_EU)
+func getResidencyConfig() (ResidencyConfig, error) {
+	config := ResidencyConfig{
+		HomeRegion:     domain.DataRegion(strings.ToLower(getEnv("DATA_RESIDENCY_HOME_REGION", string(domain.DataRegionUS)))),
+		RegionClusters: make(map[domain.DataRegion]string),
+	}
+	if !config.HomeRegion.IsValid() {
+		return config, fmt.Errorf("DATA_RESIDENCY_HOME_REGION must be one of %v", domain.DataRegions)
+	}
+	for _, region := range domain.DataRegions {
+		url := getEnv("DATABASE_URL_"+strings.ToUpper(string(region)), "")
+		if url == "" {
+			continue
+		}
+		if region == config.HomeRegion {
+			return config, fmt.Errorf("DATABASE_URL_%s must not be set: %s is the home region, stored in the control plane's database",
+				strings.ToUpper(string(region)), region)
+		}
+		config.RegionClusters[region] = url
+	}
+	return config, nil
+}
+
+// getOrgRateLimits reads ORG_RATE_LIMIT_READ, ORG_RATE_LIMIT_WRITE and
+// ORG_RATE_LIMIT_VERIFICATION, each "<requests per minute>,<burst>" (0 is unlimited). Unset
+// classes keep their default limits.
+func getOrgRateLimits() (map[string]domain.RateLimit, error) {
+	limits := make(map[string]domain.RateLimit)
+	for class := range domain.DefaultRateLimits {
+		key := "ORG_RATE_LIMIT_" + strings.ToUpper(class)
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		parts := strings.Split(value, ",")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s must be <requests per minute>,<burst>", key)
+		}
+		values := make([]int, len(parts))
+		for i, part := range parts {
+			limit, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || limit < 0 {
+				return nil, fmt.Errorf("%s must be <requests per minute>,<burst>", key)
+			}
+			values[i] = limit
+		}
+		limits[class] = domain.RateLimit{RequestsPerMinute: values[0], Burst: values[1]}
+	}
+	return limits, nil
+}
+
+// getTimeoutBudgets reads REQUEST_TIMEOUT_READ, REQUEST_TIMEOUT_WRITE,
+// REQUEST_TIMEOUT_VERIFICATION, DEPENDENCY_TIMEOUT_DATABASE and DEPENDENCY_TIMEOUT_HTTP
+// (durations, 0 is unlimited). Unset budgets keep their defaults.
+func getTimeoutBudgets() domain.TimeoutBudgets {
+	budgets := domain.TimeoutBudgets{
+		Requests:     make(map[string]time.Duration),
+		Dependencies: make(map[string]time.Duration),
+	}
+	for class, budget := range domain.DefaultTimeoutBudgets.Requests {
+		budgets.Requests[class] = getEnvAsDuration("REQUEST_TIMEOUT_"+strings.ToUpper(class), budget)
+	}
+	for dependency, budget := range domain.DefaultTimeoutBudgets.Dependencies {
+		budgets.Dependencies[dependency] = getEnvAsDuration("DEPENDENCY_TIMEOUT_"+strings.ToUpper(dependency), budget)
+	}
+	return budgets
+}
+
+// getPayloadBudgets reads REQUEST_BODY_LIMIT_READ, REQUEST_BODY_LIMIT_WRITE,
+// REQUEST_BODY_LIMIT_VERIFICATION, REQUEST_BODY_LIMIT_ATTESTATION, REQUEST_BODY_LIMIT_BULK
+// and RESPONSE_BUDGET_LIST, in bytes
+func getPayloadBudgets() domain.PayloadBudgets {
+	budgets := domain.PayloadBudgets{
+		RequestBodies: make(map[string]int),
+		ListResponse:  getEnvAsInt("RESPONSE_BUDGET_LIST", domain.DefaultPayloadBudgets.ListResponse),
+	}
+	for class, limit := range domain.DefaultPayloadBudgets.RequestBodies {
+		budgets.RequestBodies[class] = getEnvAsInt("REQUEST_BODY_LIMIT_"+strings.ToUpper(class), limit)
+	}
+	return budgets
+}
+
 // getEnvRequired gets environment variable and panics if not set
 func getEnvRequired(key string) string {
 	value := os.Getenv(key)
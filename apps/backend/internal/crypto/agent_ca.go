@@ -0,0 +1,197 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"time"
+)
+
+// agentCAPurpose derives the agent CA key from the KeyVault master key when no
+// dedicated key is configured
+const agentCAPurpose = "aim-agent-ca-v1"
+
+// The CA certificate has a fixed validity so that every server instance deriving the
+// same key produces the same CA certificate
+var (
+	agentCANotBefore = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	agentCANotAfter  = time.Date(2045, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// AgentCertificateRequest describes the certificate issued to an agent
+type AgentCertificateRequest struct {
+	SerialNumber *big.Int
+	Subject      pkix.Name
+	URIs         []*url.URL
+	PublicKey    crypto.PublicKey // ed25519.PublicKey, *ecdsa.PublicKey or *rsa.PublicKey (see ParsePublicKey)
+	NotBefore    time.Time
+	NotAfter     time.Time
+	CRLURL       string // Where relying parties fetch the revocation list, if published
+}
+
+// AgentCA is the internal certificate authority that issues short-lived X.509 client
+// certificates bound to the Ed25519 keys of verified agents, and signs their revocation list
+type AgentCA struct {
+	key  ed25519.PrivateKey
+	cert *x509.Certificate
+}
+
+// NewAgentCA creates the CA from a 32-byte Ed25519 seed with a self-signed CA certificate
+func NewAgentCA(seed []byte) (*AgentCA, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("agent CA key must be %d bytes, got %d bytes", ed25519.SeedSize, len(seed))
+	}
+
+	key := ed25519.NewKeyFromSeed(seed)
+	publicKey := key.Public().(ed25519.PublicKey)
+	keyID := sha1.Sum(publicKey)
+
+	template := &x509.Certificate{
+		SerialNumber: new(big.Int).SetBytes(keyID[:8]),
+		Subject: pkix.Name{
+			CommonName:   "AIM Agent CA",
+			Organization: []string{"OpenA2A Agent Identity Management"},
+		},
+		NotBefore:             agentCANotBefore,
+		NotAfter:              agentCANotAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+		SubjectKeyId:          keyID[:],
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, publicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent CA certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse agent CA certificate: %w", err)
+	}
+
+	return &AgentCA{key: key, cert: cert}, nil
+}
+
+// NewAgentCAFromEnv creates the CA from AGENT_CA_KEY (base64 Ed25519 seed). Without a
+// configured key, the CA key is derived from the KeyVault master key, if one is given.
+func NewAgentCAFromEnv(keyVault *KeyVault) (*AgentCA, error) {
+	encoded := os.Getenv("AGENT_CA_KEY")
+	if encoded == "" {
+		if keyVault == nil {
+			return nil, fmt.Errorf("AGENT_CA_KEY is not set")
+		}
+		return NewAgentCA(keyVault.DeriveKey(agentCAPurpose))
+	}
+	seed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode agent CA key: %w", err)
+	}
+	return NewAgentCA(seed)
+}
+
+// Certificate returns the CA certificate
+func (ca *AgentCA) Certificate() *x509.Certificate {
+	return ca.cert
+}
+
+// CertificatePEM returns the PEM-encoded CA certificate
+func (ca *AgentCA) CertificatePEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+}
+
+// Issue signs a client certificate for the agent's public key and returns it PEM-encoded
+func (ca *AgentCA) Issue(req *AgentCertificateRequest) ([]byte, error) {
+	var keyID [sha1.Size]byte
+	switch key := req.PublicKey.(type) {
+	case ed25519.PublicKey:
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key size: expected %d bytes, got %d bytes", ed25519.PublicKeySize, len(key))
+		}
+		keyID = sha1.Sum(key)
+	case *ecdsa.PublicKey, *rsa.PublicKey:
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid agent public key: %w", err)
+		}
+		keyID = sha1.Sum(der)
+	default:
+		return nil, fmt.Errorf("unsupported agent public key type %T", req.PublicKey)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          req.SerialNumber,
+		Subject:               req.Subject,
+		URIs:                  req.URIs,
+		NotBefore:             req.NotBefore,
+		NotAfter:              req.NotAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		SubjectKeyId:          keyID[:],
+	}
+	if req.CRLURL != "" {
+		template.CRLDistributionPoints = []string{req.CRLURL}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, req.PublicKey, ca.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue agent certificate: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+// Verify parses a PEM-encoded certificate and checks that this CA issued it and that it
+// is valid at the given time. Revocation is not checked here.
+func (ca *AgentCA) Verify(certificatePEM []byte, at time.Time) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certificatePEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("certificate is not PEM-encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	if err := ca.VerifyCertificate(cert, at); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// VerifyCertificate checks that a parsed certificate was issued by the CA for client
+// authentication and is valid at the given time
+func (ca *AgentCA) VerifyCertificate(cert *x509.Certificate, at time.Time) error {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:       roots,
+		CurrentTime: at,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err
+}
+
+// CreateCRL signs a DER-encoded certificate revocation list of the given entries
+func (ca *AgentCA) CreateCRL(entries []x509.RevocationListEntry, number *big.Int, thisUpdate, nextUpdate time.Time) ([]byte, error) {
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		RevokedCertificateEntries: entries,
+		Number:                    number,
+		ThisUpdate:                thisUpdate,
+		NextUpdate:                nextUpdate,
+	}, ca.cert, ca.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate revocation list: %w", err)
+	}
+	return der, nil
+}
@@ -0,0 +1,79 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// agentCredentialPurpose derives the agent credential signing key from the KeyVault master key
+// when no dedicated key is configured
+const agentCredentialPurpose = "aim-agent-credential-v1"
+
+// AgentCredentialSigner signs the verifiable credentials issued to agents as EdDSA JWTs. Third
+// parties verify them offline with the public key the deployment publishes in its DID document.
+type AgentCredentialSigner struct {
+	key ed25519.PrivateKey
+}
+
+// NewAgentCredentialSigner creates a signer from a 32-byte Ed25519 seed
+func NewAgentCredentialSigner(seed []byte) (*AgentCredentialSigner, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("agent credential signing key must be %d bytes, got %d bytes", ed25519.SeedSize, len(seed))
+	}
+	return &AgentCredentialSigner{key: ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+// NewAgentCredentialSignerFromEnv creates a signer from AGENT_CREDENTIAL_SIGNING_KEY (base64
+// Ed25519 seed). Without it, the signing key is derived from the KeyVault master key, if one is given.
+func NewAgentCredentialSignerFromEnv(keyVault *KeyVault) (*AgentCredentialSigner, error) {
+	encoded := os.Getenv("AGENT_CREDENTIAL_SIGNING_KEY")
+	if encoded == "" {
+		if keyVault == nil {
+			return nil, fmt.Errorf("AGENT_CREDENTIAL_SIGNING_KEY is not set")
+		}
+		return NewAgentCredentialSigner(keyVault.DeriveKey(agentCredentialPurpose))
+	}
+	seed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode agent credential signing key: %w", err)
+	}
+	return NewAgentCredentialSigner(seed)
+}
+
+// PublicKey returns the public key credentials are verified with
+func (s *AgentCredentialSigner) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// KeyID names the signing key; it changes whenever the key does, so verifiers holding an old key
+// can tell they need to fetch the DID document again
+func (s *AgentCredentialSigner) KeyID() string {
+	sum := sha256.Sum256(s.PublicKey())
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
+
+// SignJWT returns the claims as a JWT signed with EdDSA, naming the key under keyRef in its kid header
+func (s *AgentCredentialSigner) SignJWT(claims jwt.Claims, keyRef string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = keyRef
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign credential: %w", err)
+	}
+	return signed, nil
+}
+
+// ParseJWT verifies the signature and validity period of a JWT signed by SignJWT and decodes its
+// claims into claims
+func (s *AgentCredentialSigner) ParseJWT(signed string, claims jwt.Claims, opts ...jwt.ParserOption) error {
+	opts = append(opts, jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}), jwt.WithExpirationRequired())
+	_, err := jwt.ParseWithClaims(signed, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.PublicKey(), nil
+	}, opts...)
+	return err
+}
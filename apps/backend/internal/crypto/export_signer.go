@@ -0,0 +1,131 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ExportSignatureAlgorithm is the MAC export files are signed with
+const ExportSignatureAlgorithm = "HMAC-SHA256"
+
+// Headers carrying the signature of a downloaded export file
+const (
+	ExportSignatureHeader          = "X-AIM-Export-Signature"
+	ExportSignatureKeyIDHeader     = "X-AIM-Export-Key-Id"
+	ExportSignatureAlgorithmHeader = "X-AIM-Export-Signature-Algorithm"
+)
+
+// exportSigningPurpose derives the export signing key from the KeyVault master key
+// when no dedicated key is configured
+const exportSigningPurpose = "aim-export-signing-v1"
+
+// ExportSigner signs export files (CSV, JSON, archives) with a platform key so that
+// recipients can confirm a file was not modified after download.
+// Retired keys keep verifying files signed before a rotation.
+type ExportSigner struct {
+	keyID    string
+	key      []byte
+	previous map[string][]byte // Key ID -> retired key
+}
+
+// NewExportSigner creates an export signer from the current key and any retired keys
+func NewExportSigner(key []byte, previous ...[]byte) (*ExportSigner, error) {
+	if len(key) < 32 {
+		return nil, fmt.Errorf("export signing key must be at least 32 bytes, got %d bytes", len(key))
+	}
+
+	signer := &ExportSigner{
+		keyID:    ExportKeyID(key),
+		key:      key,
+		previous: make(map[string][]byte, len(previous)),
+	}
+	for _, retired := range previous {
+		if len(retired) < 32 {
+			return nil, fmt.Errorf("previous export signing key must be at least 32 bytes, got %d bytes", len(retired))
+		}
+		signer.previous[ExportKeyID(retired)] = retired
+	}
+	return signer, nil
+}
+
+// NewExportSignerFromEnv creates an export signer from EXPORT_SIGNING_KEY (base64) and the
+// comma-separated EXPORT_SIGNING_PREVIOUS_KEYS. Without a configured key, the signing key is
+// derived from the KeyVault master key, if one is given.
+func NewExportSignerFromEnv(keyVault *KeyVault) (*ExportSigner, error) {
+	var previous [][]byte
+	if retired := os.Getenv("EXPORT_SIGNING_PREVIOUS_KEYS"); retired != "" {
+		for _, encoded := range strings.Split(retired, ",") {
+			key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode previous export signing key: %w", err)
+			}
+			previous = append(previous, key)
+		}
+	}
+
+	encoded := os.Getenv("EXPORT_SIGNING_KEY")
+	if encoded == "" {
+		if keyVault == nil {
+			return nil, fmt.Errorf("EXPORT_SIGNING_KEY is not set")
+		}
+		return NewExportSigner(keyVault.DeriveKey(exportSigningPurpose), previous...)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode export signing key: %w", err)
+	}
+	return NewExportSigner(key, previous...)
+}
+
+// ExportKeyID identifies a signing key without revealing it
+func ExportKeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// SignExport returns the hex-encoded HMAC-SHA256 of the data under the key
+func SignExport(key, data []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// KeyID returns the ID of the current signing key
+func (s *ExportSigner) KeyID() string {
+	return s.keyID
+}
+
+// Sign signs the data with the current key
+func (s *ExportSigner) Sign(data []byte) string {
+	return SignExport(s.key, data)
+}
+
+// Verify checks a signature against the key with the given ID, or every known key when
+// keyID is empty. It returns the ID of the key that produced the signature.
+func (s *ExportSigner) Verify(data []byte, signature, keyID string) (string, bool) {
+	expected, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return "", false
+	}
+
+	keys := map[string][]byte{s.keyID: s.key}
+	for id, key := range s.previous {
+		keys[id] = key
+	}
+	for id, key := range keys {
+		if keyID != "" && id != keyID {
+			continue
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write(data)
+		if hmac.Equal(mac.Sum(nil), expected) {
+			return id, true
+		}
+	}
+	return "", false
+}
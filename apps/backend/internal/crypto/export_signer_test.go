@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportSignerVerifiesAcrossKeyRotation(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+	export := []byte("agent_id,agent_name\n1,reporter\n")
+
+	old, err := NewExportSigner(oldKey)
+	require.NoError(t, err)
+	oldSignature := old.Sign(export)
+
+	signer, err := NewExportSigner(newKey, oldKey)
+	require.NoError(t, err)
+	signature := signer.Sign(export)
+	assert.Equal(t, SignExport(newKey, export), signature)
+
+	keyID, ok := signer.Verify(export, signature, signer.KeyID())
+	assert.True(t, ok)
+	assert.Equal(t, signer.KeyID(), keyID)
+
+	keyID, ok = signer.Verify(export, oldSignature, "")
+	assert.True(t, ok, "files signed before a rotation still verify")
+	assert.Equal(t, old.KeyID(), keyID)
+
+	_, ok = signer.Verify(export, oldSignature, signer.KeyID())
+	assert.False(t, ok, "the signature does not match the named key")
+
+	tampered := append([]byte{}, export...)
+	tampered[len(tampered)-2] = 'X'
+	_, ok = signer.Verify(tampered, signature, "")
+	assert.False(t, ok)
+	_, ok = signer.Verify(export, "not-hex", "")
+	assert.False(t, ok)
+
+	_, err = NewExportSigner([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestExportSignerFromEnv(t *testing.T) {
+	vault, err := NewKeyVault(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, 32)))
+	require.NoError(t, err)
+
+	t.Setenv("EXPORT_SIGNING_KEY", "")
+	derived, err := NewExportSignerFromEnv(vault)
+	require.NoError(t, err)
+	again, err := NewExportSignerFromEnv(vault)
+	require.NoError(t, err)
+	assert.Equal(t, derived.KeyID(), again.KeyID(), "the derived key is stable across restarts")
+
+	_, err = NewExportSignerFromEnv(nil)
+	assert.Error(t, err, "without a vault the key must be configured")
+
+	configured := bytes.Repeat([]byte{4}, 32)
+	t.Setenv("EXPORT_SIGNING_KEY", base64.StdEncoding.EncodeToString(configured))
+	signer, err := NewExportSignerFromEnv(nil)
+	require.NoError(t, err)
+	assert.Equal(t, ExportKeyID(configured), signer.KeyID())
+}
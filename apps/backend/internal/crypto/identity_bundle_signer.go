@@ -0,0 +1,98 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// identityBundlePurpose derives the identity bundle signing key from the KeyVault master key
+// when no dedicated key is configured
+const identityBundlePurpose = "aim-identity-bundle-v1"
+
+// IdentityBundleSigner signs agent identity bundles with the deployment's Ed25519 key, and
+// decides which other deployments' bundles are trusted on import. Unlike export files, bundles
+// are verified by a different deployment, so they are signed with a key pair: the importing
+// deployment lists the exporting deployment's public key as trusted.
+type IdentityBundleSigner struct {
+	key     ed25519.PrivateKey
+	trusted map[string]bool // Base64 public keys whose bundles are accepted, this deployment's included
+}
+
+// NewIdentityBundleSigner creates a signer from a 32-byte Ed25519 seed that also trusts the
+// bundles of the given base64 public keys
+func NewIdentityBundleSigner(seed []byte, trustedKeys ...string) (*IdentityBundleSigner, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("identity bundle signing key must be %d bytes, got %d bytes", ed25519.SeedSize, len(seed))
+	}
+
+	signer := &IdentityBundleSigner{
+		key:     ed25519.NewKeyFromSeed(seed),
+		trusted: make(map[string]bool, len(trustedKeys)+1),
+	}
+	signer.trusted[signer.PublicKey()] = true
+	for _, key := range trustedKeys {
+		if _, err := DecodePublicKey(key); err != nil {
+			return nil, fmt.Errorf("invalid trusted identity bundle key: %w", err)
+		}
+		signer.trusted[key] = true
+	}
+	return signer, nil
+}
+
+// NewIdentityBundleSignerFromEnv creates a signer from IDENTITY_BUNDLE_SIGNING_KEY (base64
+// Ed25519 seed) trusting the comma-separated base64 public keys of IDENTITY_BUNDLE_TRUSTED_KEYS.
+// Without a configured key, the signing key is derived from the KeyVault master key, if one is given.
+func NewIdentityBundleSignerFromEnv(keyVault *KeyVault) (*IdentityBundleSigner, error) {
+	var trusted []string
+	if keys := os.Getenv("IDENTITY_BUNDLE_TRUSTED_KEYS"); keys != "" {
+		for _, key := range strings.Split(keys, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				trusted = append(trusted, key)
+			}
+		}
+	}
+
+	encoded := os.Getenv("IDENTITY_BUNDLE_SIGNING_KEY")
+	if encoded == "" {
+		if keyVault == nil {
+			return nil, fmt.Errorf("IDENTITY_BUNDLE_SIGNING_KEY is not set")
+		}
+		return NewIdentityBundleSigner(keyVault.DeriveKey(identityBundlePurpose), trusted...)
+	}
+	seed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode identity bundle signing key: %w", err)
+	}
+	return NewIdentityBundleSigner(seed, trusted...)
+}
+
+// PublicKey returns the base64 public key other deployments trust this deployment's bundles with
+func (s *IdentityBundleSigner) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.key.Public().(ed25519.PublicKey))
+}
+
+// Sign returns the base64 Ed25519 signature of the bundle
+func (s *IdentityBundleSigner) Sign(bundle []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, bundle))
+}
+
+// Trusts reports whether bundles signed with the base64 public key are accepted
+func (s *IdentityBundleSigner) Trusts(publicKey string) bool {
+	return s.trusted[publicKey]
+}
+
+// VerifyIdentityBundle checks the base64 signature of a bundle against the issuer's base64 public key
+func VerifyIdentityBundle(publicKey string, bundle []byte, signature string) bool {
+	key, err := DecodePublicKey(publicKey)
+	if err != nil {
+		return false
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(key, bundle, sig)
+}
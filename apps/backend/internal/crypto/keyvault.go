@@ -3,7 +3,9 @@ package crypto
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -116,6 +118,14 @@ func (kv *KeyVault) DecryptPrivateKey(encryptedPrivateKey string) (string, error
 	return string(plaintext), nil
 }
 
+// DeriveKey derives a 32-byte key for a single purpose (e.g. signing exports) from the
+// master key, so that other secrets never need the master key itself
+func (kv *KeyVault) DeriveKey(purpose string) []byte {
+	mac := hmac.New(sha256.New, kv.masterKey)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
 // RotatePrivateKey decrypts with old key, re-encrypts with new key
 func (kv *KeyVault) RotatePrivateKey(encryptedPrivateKey string, newMasterKeyBase64 string) (string, error) {
 	// Decrypt with current master key
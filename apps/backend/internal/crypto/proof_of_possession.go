@@ -0,0 +1,32 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidProofOfPossession is returned when a proof-of-possession signature does not verify
+var ErrInvalidProofOfPossession = errors.New("invalid proof of possession")
+
+// ProofOfPossessionMessage is the message a client signs with its new private key to prove that
+// it holds the key it registers or rotates to. It binds the key to the agent's name.
+func ProofOfPossessionMessage(agentName, publicKeyBase64 string) []byte {
+	return []byte("aim-proof-of-possession:v1\n" + agentName + "\n" + publicKeyBase64)
+}
+
+// VerifyProofOfPossession checks a base64-encoded signature of ProofOfPossessionMessage made with
+// a key of the algorithm (see VerifyAlgorithmSignature)
+func VerifyProofOfPossession(algorithm, agentName, publicKeyBase64, signatureBase64 string) error {
+	if _, err := ParsePublicKey(algorithm, publicKeyBase64); err != nil {
+		return err
+	}
+	signature, err := base64.StdEncoding.DecodeString(signatureBase64)
+	if err != nil {
+		return fmt.Errorf("%w: signature is not base64: %v", ErrInvalidProofOfPossession, err)
+	}
+	if err := VerifyAlgorithmSignature(algorithm, publicKeyBase64, ProofOfPossessionMessage(agentName, publicKeyBase64), signature); err != nil {
+		return ErrInvalidProofOfPossession
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+)
+
+// publicStatusPurpose derives the public status signing key from the KeyVault master key when
+// no dedicated key is configured
+const publicStatusPurpose = "aim-public-status-v1"
+
+// Response headers carrying the signature of a public status response and the key it was made with
+const (
+	PublicStatusSignatureHeader = "X-AIM-Signature"
+	PublicStatusKeyIDHeader     = "X-AIM-Signature-Key-Id"
+)
+
+// PublicStatusSigner signs the unauthenticated responses about publicly listed agents: their
+// verification lookup, badges and status pages. A site or relying party that fetched the
+// deployment's public key can tell a genuine response from a forged or edited one.
+type PublicStatusSigner struct {
+	key ed25519.PrivateKey
+}
+
+// NewPublicStatusSigner creates a signer from a 32-byte Ed25519 seed
+func NewPublicStatusSigner(seed []byte) (*PublicStatusSigner, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("public status signing key must be %d bytes, got %d bytes", ed25519.SeedSize, len(seed))
+	}
+	return &PublicStatusSigner{key: ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+// NewPublicStatusSignerFromEnv creates a signer from PUBLIC_STATUS_SIGNING_KEY (base64 Ed25519
+// seed). Without it, the signing key is derived from the KeyVault master key, if one is given.
+func NewPublicStatusSignerFromEnv(keyVault *KeyVault) (*PublicStatusSigner, error) {
+	encoded := os.Getenv("PUBLIC_STATUS_SIGNING_KEY")
+	if encoded == "" {
+		if keyVault == nil {
+			return nil, fmt.Errorf("PUBLIC_STATUS_SIGNING_KEY is not set")
+		}
+		return NewPublicStatusSigner(keyVault.DeriveKey(publicStatusPurpose))
+	}
+	seed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public status signing key: %w", err)
+	}
+	return NewPublicStatusSigner(seed)
+}
+
+// PublicKey returns the base64 public key responses are verified with
+func (s *PublicStatusSigner) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.key.Public().(ed25519.PublicKey))
+}
+
+// KeyID names the signing key; it changes whenever the key does, so verifiers holding an old key
+// can tell they need to fetch it again
+func (s *PublicStatusSigner) KeyID() string {
+	sum := sha256.Sum256(s.key.Public().(ed25519.PublicKey))
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
+
+// Sign returns the base64 Ed25519 signature of a response body
+func (s *PublicStatusSigner) Sign(body []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, body))
+}
+
+// VerifyPublicStatus checks the base64 signature of a response body against the base64 public key
+func VerifyPublicStatus(publicKey string, body []byte, signature string) bool {
+	key, err := DecodePublicKey(publicKey)
+	if err != nil {
+		return false
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(key, body, sig)
+}
@@ -0,0 +1,135 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// minRSAKeyBits is the smallest RSA key accepted
+const minRSAKeyBits = 2048
+
+var (
+	// ErrInvalidSignature is returned when a signature does not verify
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrUnsupportedKeyAlgorithm is returned for key algorithms other than domain.KeyAlgorithms
+	ErrUnsupportedKeyAlgorithm = errors.New("unsupported key algorithm")
+)
+
+// ParsePublicKey decodes a base64 public key of the algorithm:
+//   - Ed25519: the raw 32-byte key
+//   - ECDSA-P256: a DER SubjectPublicKeyInfo, or the uncompressed 65-byte point
+//   - RSA-PSS: a DER SubjectPublicKeyInfo or PKCS #1 key of at least 2048 bits
+func ParsePublicKey(algorithm, publicKeyBase64 string) (crypto.PublicKey, error) {
+	normalized, ok := domain.NormalizeKeyAlgorithm(algorithm)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedKeyAlgorithm, algorithm)
+	}
+	der, err := base64.StdEncoding.DecodeString(publicKeyBase64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+
+	switch normalized {
+	case domain.KeyAlgorithmEd25519:
+		return DecodePublicKey(publicKeyBase64)
+	case domain.KeyAlgorithmECDSAP256:
+		if len(der) == 65 && der[0] == 4 {
+			x, y := elliptic.Unmarshal(elliptic.P256(), der)
+			if x == nil {
+				return nil, fmt.Errorf("invalid P-256 public key: point is not on the curve")
+			}
+			return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+		}
+		key, err := x509.ParsePKIXPublicKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("invalid P-256 public key: %w", err)
+		}
+		ecdsaKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || ecdsaKey.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("invalid P-256 public key: not an ECDSA key on P-256")
+		}
+		return ecdsaKey, nil
+	default: // RSA-PSS
+		key, err := x509.ParsePKIXPublicKey(der)
+		if err != nil {
+			if pkcs1, pkcs1Err := x509.ParsePKCS1PublicKey(der); pkcs1Err == nil {
+				key, err = pkcs1, nil
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA public key: %w", err)
+		}
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("invalid RSA public key: not an RSA key")
+		}
+		if rsaKey.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("invalid RSA public key: %d bits, at least %d required", rsaKey.N.BitLen(), minRSAKeyBits)
+		}
+		return rsaKey, nil
+	}
+}
+
+// DetectKeyAlgorithm returns the algorithm of a base64 public key in one of the encodings
+// ParsePublicKey accepts
+func DetectKeyAlgorithm(publicKeyBase64 string) (string, error) {
+	der, err := base64.StdEncoding.DecodeString(publicKeyBase64)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode public key: %w", err)
+	}
+	switch {
+	case len(der) == ed25519.PublicKeySize:
+		return domain.KeyAlgorithmEd25519, nil
+	case len(der) == 65 && der[0] == 4:
+		return domain.KeyAlgorithmECDSAP256, nil
+	}
+	for _, algorithm := range []string{domain.KeyAlgorithmECDSAP256, domain.KeyAlgorithmRSAPSS} {
+		if _, err := ParsePublicKey(algorithm, publicKeyBase64); err == nil {
+			return algorithm, nil
+		}
+	}
+	return "", fmt.Errorf("%w: the public key is not a raw Ed25519 key, a P-256 key or an RSA key of at least %d bits", ErrUnsupportedKeyAlgorithm, minRSAKeyBits)
+}
+
+// VerifyAlgorithmSignature checks a signature of message made with the private key of a base64
+// public key of the algorithm. ECDSA signatures may be ASN.1 DER or the 64-byte r||s form JOSE
+// and WebCrypto produce; ECDSA and RSA-PSS sign the SHA-256 digest of the message, and RSA-PSS
+// signatures may use any salt length.
+func VerifyAlgorithmSignature(algorithm, publicKeyBase64 string, message, signature []byte) error {
+	key, err := ParsePublicKey(algorithm, publicKeyBase64)
+	if err != nil {
+		return err
+	}
+
+	valid := false
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, message, signature)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		if len(signature) == 64 {
+			r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+			valid = ecdsa.Verify(key, digest[:], r, s)
+		} else {
+			valid = ecdsa.VerifyASN1(key, digest[:], signature)
+		}
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(message)
+		valid = rsa.VerifyPSS(key, crypto.SHA256, digest[:], signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto}) == nil
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyAlgorithmSignature(t *testing.T) {
+	message := []byte("aim-proof-of-possession:v1\nagent\nkey")
+	digest := sha256.Sum256(message)
+
+	keyPair, err := GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	edPublicKey := EncodeKeyPair(keyPair).PublicKeyBase64
+	edSignature := SignMessage(keyPair.PrivateKey, message)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecdsaDER, err := x509.MarshalPKIXPublicKey(&ecdsaKey.PublicKey)
+	require.NoError(t, err)
+	ecdsaPublicKey := base64.StdEncoding.EncodeToString(ecdsaDER)
+	ecdsaPoint := base64.StdEncoding.EncodeToString(elliptic.Marshal(elliptic.P256(), ecdsaKey.X, ecdsaKey.Y))
+	ecdsaSignature, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, digest[:])
+	require.NoError(t, err)
+	r, s, err := ecdsa.Sign(rand.Reader, ecdsaKey, digest[:])
+	require.NoError(t, err)
+	rawSignature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPublicKey := base64.StdEncoding.EncodeToString(x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey))
+	rsaSignature, err := rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, digest[:], nil)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		algorithm, publicKey string
+		signature            []byte
+	}{
+		{domain.KeyAlgorithmEd25519, edPublicKey, edSignature},
+		{domain.KeyAlgorithmECDSAP256, ecdsaPublicKey, ecdsaSignature},
+		{"ES256", ecdsaPoint, rawSignature},
+		{domain.KeyAlgorithmRSAPSS, rsaPublicKey, rsaSignature},
+	} {
+		assert.NoError(t, VerifyAlgorithmSignature(tc.algorithm, tc.publicKey, message, tc.signature), tc.algorithm)
+		assert.ErrorIs(t, VerifyAlgorithmSignature(tc.algorithm, tc.publicKey, []byte("tampered"), tc.signature), ErrInvalidSignature, tc.algorithm)
+
+		detected, err := DetectKeyAlgorithm(tc.publicKey)
+		require.NoError(t, err)
+		normalized, _ := domain.NormalizeKeyAlgorithm(tc.algorithm)
+		assert.Equal(t, normalized, detected)
+	}
+
+	_, err = ParsePublicKey(domain.KeyAlgorithmRSAPSS, ecdsaPublicKey)
+	assert.Error(t, err, "the key does not match the algorithm")
+	_, err = ParsePublicKey("DSA", edPublicKey)
+	assert.ErrorIs(t, err, ErrUnsupportedKeyAlgorithm)
+
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = DetectKeyAlgorithm(base64.StdEncoding.EncodeToString(x509.MarshalPKCS1PublicKey(&weakKey.PublicKey)))
+	assert.ErrorIs(t, err, ErrUnsupportedKeyAlgorithm, "RSA keys under 2048 bits are refused")
+}
@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPMatchesRFC6238AndRejectsReplays(t *testing.T) {
+	// RFC 6238 appendix B: SHA-1 with the ASCII key "12345678901234567890", truncated to 6 digits
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range vectors {
+		code, err := TOTPCode(secret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, want, code, "time %d", unix)
+	}
+
+	now := time.Unix(1111111111, 0)
+	previous, err := TOTPCode(secret, now.Add(-TOTPPeriod))
+	require.NoError(t, err)
+	step, ok := ValidateTOTPCode(secret, previous, now, 0)
+	assert.True(t, ok, "codes from the previous step are accepted for clock drift")
+	assert.Equal(t, TOTPStep(now)-1, step)
+
+	_, ok = ValidateTOTPCode(secret, previous, now, step)
+	assert.False(t, ok, "a code cannot be used twice")
+
+	stale, err := TOTPCode(secret, now.Add(-3*TOTPPeriod))
+	require.NoError(t, err)
+	_, ok = ValidateTOTPCode(secret, stale, now, 0)
+	assert.False(t, ok)
+
+	generated, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+	uri := TOTPProvisioningURI("AIM", "alice@example.com", generated)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/AIM:alice@example.com?"))
+	assert.Contains(t, uri, "secret="+generated)
+}
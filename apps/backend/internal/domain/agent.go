@@ -54,6 +54,7 @@ type Agent struct {
 	KeyExpiresAt             *time.Time  `json:"keyExpiresAt"`
 	KeyRotationGraceUntil    *time.Time  `json:"keyRotationGraceUntil,omitempty"`
 	PreviousPublicKey        *string     `json:"-"` // Not exposed in API, used for grace period verification
+	PreviousKeyAlgorithm     string      `json:"-"` // Algorithm of PreviousPublicKey
 	RotationCount            int         `json:"rotationCount"`
 	CreatedAt                time.Time   `json:"createdAt"`
 	UpdatedAt                time.Time   `json:"updatedAt"`
@@ -68,12 +69,55 @@ type Agent struct {
 type AgentRepository interface {
 	Create(agent *Agent) error
 	GetByID(id uuid.UUID) (*Agent, error)
+	GetByIDs(ids []uuid.UUID) ([]*Agent, error)
 	GetByName(orgID uuid.UUID, name string) (*Agent, error)
 	GetByOrganization(orgID uuid.UUID) ([]*Agent, error)
 	Update(agent *Agent) error
 	Delete(id uuid.UUID) error
 	List(limit, offset int) ([]*Agent, error)
 	UpdateTrustScore(id uuid.UUID, newScore float64) error
+	// FreezeTrustScore stops automatic trust score updates for the agent (keeps the first freeze time)
+	FreezeTrustScore(id uuid.UUID) error
 	MarkAsCompromised(id uuid.UUID) error
+	// AddTalksTo adds the MCP server to the agent's TalksTo and reports whether it was missing
+	AddTalksTo(id uuid.UUID, mcpServer string) (bool, error)
 	UpdateLastActive(ctx context.Context, agentID uuid.UUID) error
+	// RotateKey stores the agent's new key material; the replaced public key becomes
+	// PreviousPublicKey and RotationCount is incremented (both are set on the agent)
+	RotateKey(agent *Agent) error
+	// DropEscrowedPrivateKeys deletes the encrypted private keys of the organization's agents and
+	// returns how many were deleted
+	DropEscrowedPrivateKeys(orgID uuid.UUID) (int, error)
+}
+
+// MCPServerAgentRepository also finds the agents whose TalksTo lists an MCP server, by its ID or
+// by its name
+type MCPServerAgentRepository interface {
+	AgentRepository
+	GetByMCPServer(mcpServerID uuid.UUID, orgID uuid.UUID) ([]*Agent, error)
+	GetByMCPServerName(mcpServerName string, orgID uuid.UUID) ([]*Agent, error)
+}
+
+// VerificationPublicKeys returns the public keys signatures are checked against: the current key,
+// followed by the previous key while the rotation grace period has not ended
+func (a *Agent) VerificationPublicKeys(at time.Time) []string {
+	keys := make([]string, 0, 2)
+	if a.PublicKey != nil && *a.PublicKey != "" {
+		keys = append(keys, *a.PublicKey)
+	}
+	if a.PreviousPublicKey != nil && *a.PreviousPublicKey != "" &&
+		a.KeyRotationGraceUntil != nil && at.Before(*a.KeyRotationGraceUntil) {
+		keys = append(keys, *a.PreviousPublicKey)
+	}
+	return keys
+}
+
+// AcceptsPublicKey reports whether signatures made with publicKey are accepted for the agent
+func (a *Agent) AcceptsPublicKey(publicKey string, at time.Time) bool {
+	for _, key := range a.VerificationPublicKeys(at) {
+		if key == publicKey {
+			return true
+		}
+	}
+	return false
 }
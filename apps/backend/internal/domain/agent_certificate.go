@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrAgentCertificateNotFound is returned when an agent has no certificate
+	ErrAgentCertificateNotFound = errors.New("agent certificate not found")
+	// ErrAgentCertificateRevoked is returned when an agent presents a key whose certificate was revoked
+	ErrAgentCertificateRevoked = errors.New("agent certificate revoked")
+)
+
+// CertificateRevocationReason is why an agent certificate was revoked (RFC 5280 reason codes)
+type CertificateRevocationReason string
+
+const (
+	CertificateRevocationUnspecified          CertificateRevocationReason = "unspecified"
+	CertificateRevocationKeyCompromise        CertificateRevocationReason = "key_compromise"         // Agent marked compromised
+	CertificateRevocationSuperseded           CertificateRevocationReason = "superseded"             // Renewed or replaced after a key rotation
+	CertificateRevocationCessationOfOperation CertificateRevocationReason = "cessation_of_operation" // Agent suspended or deleted
+)
+
+// ReasonCode returns the RFC 5280 CRLReason code of the revocation reason
+func (r CertificateRevocationReason) ReasonCode() int {
+	switch r {
+	case CertificateRevocationKeyCompromise:
+		return 1
+	case CertificateRevocationSuperseded:
+		return 4
+	case CertificateRevocationCessationOfOperation:
+		return 5
+	}
+	return 0
+}
+
+// Statuses of an agent certificate, derived on read
+const (
+	AgentCertificateStatusValid   = "valid"
+	AgentCertificateStatusExpired = "expired"
+	AgentCertificateStatusRevoked = "revoked"
+)
+
+// AgentCertificate is a short-lived X.509 client certificate issued by the platform CA,
+// binding a verified agent's identity to its Ed25519 public key
+type AgentCertificate struct {
+	ID               uuid.UUID                    `json:"id"`
+	OrganizationID   uuid.UUID                    `json:"organizationId"`
+	AgentID          uuid.UUID                    `json:"agentId"`
+	SerialNumber     string                       `json:"serialNumber"` // Hex-encoded
+	PublicKey        string                       `json:"publicKey"`    // Base64 Ed25519 key the certificate is bound to
+	CertificatePEM   string                       `json:"certificatePem"`
+	NotBefore        time.Time                    `json:"notBefore"`
+	NotAfter         time.Time                    `json:"notAfter"`
+	RevokedAt        *time.Time                   `json:"revokedAt,omitempty"`
+	RevocationReason *CertificateRevocationReason `json:"revocationReason,omitempty"`
+	CreatedAt        time.Time                    `json:"createdAt"`
+}
+
+// Status reports whether the certificate is valid, expired or revoked at the given time
+func (c *AgentCertificate) Status(at time.Time) string {
+	if c.RevokedAt != nil {
+		return AgentCertificateStatusRevoked
+	}
+	if !at.Before(c.NotAfter) {
+		return AgentCertificateStatusExpired
+	}
+	return AgentCertificateStatusValid
+}
+
+// AgentCertificateRepository defines the interface for agent certificate persistence
+type AgentCertificateRepository interface {
+	Create(certificate *AgentCertificate) error
+	GetBySerialNumber(serialNumber string) (*AgentCertificate, error)
+	// GetByAgent returns the agent's certificates, newest first
+	GetByAgent(agentID uuid.UUID) ([]*AgentCertificate, error)
+	// RevokeByAgent revokes every unrevoked certificate of the agent and returns how many were revoked
+	RevokeByAgent(agentID uuid.UUID, reason CertificateRevocationReason, at time.Time) (int, error)
+	// ListRevoked returns the revoked certificates that have not expired at the given time
+	ListRevoked(at time.Time) ([]*AgentCertificate, error)
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// W3C Verifiable Credentials data model (v1.1) vocabulary of agent credentials
+const (
+	CredentialsContextV1        = "https://www.w3.org/2018/credentials/v1"
+	VerifiableCredentialType    = "VerifiableCredential"
+	AgentIdentityCredentialType = "AgentIdentityCredential"
+
+	DIDContextV1           = "https://www.w3.org/ns/did/v1"
+	JWS2020ContextV1       = "https://w3id.org/security/suites/jws-2020/v1"
+	JSONWebKey2020Type     = "JsonWebKey2020"
+	AgentCredentialKeyFrag = "credentials" // Fragment of the issuer DID naming the signing key
+)
+
+// VerifiableCredential asserts an agent's identity, verification status, trust score band and
+// capabilities as of its issuance. It is issued as a VC-JWT: the credential is the vc claim of an
+// EdDSA JWT signed by the issuer DID's key.
+type VerifiableCredential struct {
+	Context           []string               `json:"@context"`
+	ID                string                 `json:"id"` // urn:uuid:<credential id>, the JWT's jti
+	Type              []string               `json:"type"`
+	Issuer            string                 `json:"issuer"` // did:web of the deployment
+	IssuanceDate      time.Time              `json:"issuanceDate"`
+	ExpirationDate    time.Time              `json:"expirationDate"`
+	CredentialSubject AgentCredentialSubject `json:"credentialSubject"`
+}
+
+// AgentCredentialSubject is the agent a credential is about
+type AgentCredentialSubject struct {
+	ID                 string      `json:"id"` // urn:uuid:<agent id>, the JWT's sub
+	Name               string      `json:"name"`
+	DisplayName        string      `json:"displayName"`
+	OrganizationID     uuid.UUID   `json:"organizationId"`
+	AgentType          AgentType   `json:"agentType"`
+	VerificationStatus AgentStatus `json:"verificationStatus"`
+	VerifiedAt         *time.Time  `json:"verifiedAt,omitempty"`
+	TrustScoreBand     string      `json:"trustScoreBand"` // Trust tier of the agent's trust score
+	Capabilities       []string    `json:"capabilities"`
+	PublicKey          string      `json:"publicKey,omitempty"` // The agent's Ed25519 public key
+}
+
+// IssuedAgentCredential is a credential as handed to the agent: the signed JWT to present to
+// third parties, and its decoded credential
+type IssuedAgentCredential struct {
+	JWT        string                `json:"jwt"`
+	Credential *VerifiableCredential `json:"credential"`
+}
+
+// AgentCredentialVerification is the outcome of checking a presented credential
+type AgentCredentialVerification struct {
+	Valid      bool                  `json:"valid"`
+	Reason     string                `json:"reason,omitempty"` // Why the credential is not valid
+	Credential *VerifiableCredential `json:"credential,omitempty"`
+	CheckedAt  time.Time             `json:"checkedAt"`
+}
+
+// DIDDocument describes the issuer DID of agent credentials, so third parties can resolve the
+// key credentials are signed with (did:web resolves to https://<host>/.well-known/did.json)
+type DIDDocument struct {
+	Context            []string                `json:"@context"`
+	ID                 string                  `json:"id"`
+	VerificationMethod []DIDVerificationMethod `json:"verificationMethod"`
+	AssertionMethod    []string                `json:"assertionMethod"`
+}
+
+// DIDVerificationMethod is a public key of a DID document
+type DIDVerificationMethod struct {
+	ID           string            `json:"id"`
+	Type         string            `json:"type"`
+	Controller   string            `json:"controller"`
+	PublicKeyJWK map[string]string `json:"publicKeyJwk"`
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrAgentDelegationNotFound is returned when an agent was not delegated by another agent
+var ErrAgentDelegationNotFound = errors.New("agent delegation not found")
+
+const (
+	// DelegationTrustPenalty is subtracted from the parent's trust score to give a delegated
+	// child its starting trust score; it compounds down the chain
+	DelegationTrustPenalty = 0.1
+	// MaxDelegationDepth is how many levels of sub-agents a root agent can delegate to
+	MaxDelegationDepth = 5
+)
+
+// AgentDelegation records that a parent agent spawned a child agent with a scoped identity. The
+// child's capabilities are a subset of the parent's, and revoking the parent revokes the child.
+type AgentDelegation struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	ParentAgentID  uuid.UUID `json:"parentAgentId"`
+	ChildAgentID   uuid.UUID `json:"childAgentId"`
+	Depth          int       `json:"depth"` // 1 for a child of an agent that was not delegated itself
+	// Capabilities the child was delegated; each is covered by a capability of the parent
+	Capabilities     []string      `json:"capabilities"`
+	TalksTo          []string      `json:"talksTo"`
+	Purpose          string        `json:"purpose,omitempty"`
+	InheritedTrust   float64       `json:"inheritedTrust"` // The child's trust score at delegation
+	RequestedBy      InitiatorType `json:"requestedBy"`    // agent when the parent requested it itself
+	CreatedBy        uuid.UUID     `json:"createdBy"`
+	CreatedAt        time.Time     `json:"createdAt"`
+	RevokedAt        *time.Time    `json:"revokedAt,omitempty"`
+	RevocationReason string        `json:"revocationReason,omitempty"`
+}
+
+// AgentDelegationChain is an agent's place in its delegation tree: the delegations from the root
+// agent down to it, and the delegations it made itself
+type AgentDelegationChain struct {
+	AgentID     uuid.UUID          `json:"agentId"`
+	RootAgentID uuid.UUID          `json:"rootAgentId"`
+	Depth       int                `json:"depth"`
+	Chain       []*AgentDelegation `json:"chain"` // Root first; empty for an agent that was not delegated
+	Children    []*AgentDelegation `json:"children"`
+}
+
+// AgentDelegationRepository stores agent delegations
+type AgentDelegationRepository interface {
+	Create(delegation *AgentDelegation) error
+	// GetByChild returns the delegation that created the agent; ErrAgentDelegationNotFound if none did
+	GetByChild(childAgentID uuid.UUID) (*AgentDelegation, error)
+	// ListByParent returns the delegations the agent made, oldest first
+	ListByParent(parentAgentID uuid.UUID) ([]*AgentDelegation, error)
+	MarkRevoked(childAgentID uuid.UUID, revokedAt time.Time, reason string) error
+}
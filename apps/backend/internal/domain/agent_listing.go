@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrAgentListingNotFound is returned when an agent is not publicly discoverable
+var ErrAgentListingNotFound = errors.New("agent listing not found")
+
+// Trust tiers reported for trust scores; the ranges match the trust score analytics
+const (
+	TrustTierExcellent = "excellent" // 0.90 and above
+	TrustTierGood      = "good"      // 0.70 to 0.90
+	TrustTierFair      = "fair"      // 0.50 to 0.70
+	TrustTierPoor      = "poor"      // Below 0.50
+)
+
+// TrustTierOf returns the trust tier of a trust score
+func TrustTierOf(score float64) string {
+	switch {
+	case score >= 0.90:
+		return TrustTierExcellent
+	case score >= 0.70:
+		return TrustTierGood
+	case score >= 0.50:
+		return TrustTierFair
+	}
+	return TrustTierPoor
+}
+
+// AgentListing marks an agent as publicly discoverable: anyone may look up its verification
+// status by <org-domain>/<agent-name>
+type AgentListing struct {
+	AgentID        uuid.UUID  `json:"agentId"`
+	OrganizationID uuid.UUID  `json:"organizationId"`
+	ListedBy       *uuid.UUID `json:"listedBy,omitempty"`
+	ListedAt       time.Time  `json:"listedAt"`
+}
+
+// PublicAgentVerification is the standing of a publicly discoverable agent as shown to third parties
+type PublicAgentVerification struct {
+	Agent          string      `json:"agent"` // <org-domain>/<agent-name>
+	DisplayName    string      `json:"displayName"`
+	Organization   string      `json:"organization"`
+	Status         AgentStatus `json:"status"`
+	Verified       bool        `json:"verified"` // Verified and not compromised
+	Compromised    bool        `json:"compromised"`
+	TrustScore     float64     `json:"trustScore"`
+	TrustTier      string      `json:"trustTier"`
+	LastVerifiedAt *time.Time  `json:"lastVerifiedAt"`
+	CheckedAt      time.Time   `json:"checkedAt"`
+}
+
+// PublicAgentBadge is the "Verified by OpenA2A" badge publishers embed for a publicly
+// discoverable agent, linking to its status page
+type PublicAgentBadge struct {
+	Label        string                  `json:"label"`
+	Message      string                  `json:"message"` // e.g. "verified | good trust"
+	Color        string                  `json:"color"`   // Hex color of the message
+	StatusURL    string                  `json:"statusUrl"`
+	Verification PublicAgentVerification `json:"verification"`
+}
+
+// Badge colors of agent standings; verified agents are colored by trust tier
+const (
+	BadgeColorExcellent   = "#2ea44f"
+	BadgeColorGood        = "#57ab5a"
+	BadgeColorFair        = "#dbab09"
+	BadgeColorPoor        = "#e36209"
+	BadgeColorNotVerified = "#6e7781"
+	BadgeColorCompromised = "#cf222e"
+)
+
+// AgentListingRepository defines the interface for agent listing persistence
+type AgentListingRepository interface {
+	// Upsert lists the agent, keeping the original listing if it is already listed
+	Upsert(listing *AgentListing) error
+	Get(agentID uuid.UUID) (*AgentListing, error)
+	Delete(agentID uuid.UUID) error
+}
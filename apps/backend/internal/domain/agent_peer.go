@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrAgentPeerPolicyNotFound is returned when an agent peer policy does not exist
+	ErrAgentPeerPolicyNotFound = errors.New("agent peer policy not found")
+	// ErrAgentPeerPolicyExists is returned when the agents already have a peer policy in that direction
+	ErrAgentPeerPolicyExists = errors.New("agent peer policy already exists")
+)
+
+// AgentPeerPolicy declares that one agent may call another (agent-to-agent, A2A). Agent.TalksTo
+// only covers MCP servers; calls between agents need a peer policy, and both agents must meet
+// the policy's trust score minimums at the time of the call.
+type AgentPeerPolicy struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	SourceAgentID  uuid.UUID `json:"sourceAgentId"` // The calling agent
+	TargetAgentID  uuid.UUID `json:"targetAgentId"` // The agent being called
+	Bidirectional  bool      `json:"bidirectional"` // The target may call the source as well
+	// AllowedActions restricts the actions the caller may request; empty allows any action
+	AllowedActions      []string  `json:"allowedActions"`
+	MinSourceTrustScore float64   `json:"minSourceTrustScore"` // Minimum trust score of the calling agent (0-1)
+	MinTargetTrustScore float64   `json:"minTargetTrustScore"` // Minimum trust score of the called agent (0-1)
+	Description         string    `json:"description,omitempty"`
+	IsEnabled           bool      `json:"isEnabled"`
+	CreatedBy           uuid.UUID `json:"createdBy"`
+	CreatedAt           time.Time `json:"createdAt"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+}
+
+// Declares reports whether the enabled policy lets callerID call targetID with the action
+func (p *AgentPeerPolicy) Declares(callerID, targetID uuid.UUID, action string) bool {
+	if !p.IsEnabled {
+		return false
+	}
+	forward := p.SourceAgentID == callerID && p.TargetAgentID == targetID
+	reverse := p.Bidirectional && p.SourceAgentID == targetID && p.TargetAgentID == callerID
+	if !forward && !reverse {
+		return false
+	}
+	return len(p.AllowedActions) == 0 || slices.Contains(p.AllowedActions, action)
+}
+
+// AgentPeerPolicyRepository stores agent peer policies
+type AgentPeerPolicyRepository interface {
+	// Create stores a policy; ErrAgentPeerPolicyExists when the source already has one for the target
+	Create(policy *AgentPeerPolicy) error
+	GetByID(id uuid.UUID) (*AgentPeerPolicy, error)
+	ListByOrganization(orgID uuid.UUID) ([]*AgentPeerPolicy, error)
+	// ListByAgent returns the policies the agent is the source or the target of
+	ListByAgent(agentID uuid.UUID) ([]*AgentPeerPolicy, error)
+	Update(policy *AgentPeerPolicy) error
+	Delete(id uuid.UUID) error
+}
@@ -0,0 +1,23 @@
+package domain
+
+// Plan tiers of an organization (Organization.PlanType)
+const (
+	PlanTierFree       = "free"
+	PlanTierPro        = "pro"
+	PlanTierEnterprise = "enterprise"
+)
+
+// AgentQuota limits how much of an organization's capacity a single agent can use. A zero
+// limit is unlimited.
+type AgentQuota struct {
+	VerificationsPerMinute int `json:"verificationsPerMinute"` // Sustained verification rate per agent
+	Burst                  int `json:"burst"`                  // Verifications an idle agent may send on top of the per-minute rate
+	MaxConcurrent          int `json:"maxConcurrent"`          // Requests of one agent processed at the same time
+}
+
+// DefaultAgentQuotas are the per-agent limits of each plan tier unless configured otherwise
+var DefaultAgentQuotas = map[string]AgentQuota{
+	PlanTierFree:       {VerificationsPerMinute: 10, Burst: 5, MaxConcurrent: 2},
+	PlanTierPro:        {VerificationsPerMinute: 100, Burst: 50, MaxConcurrent: 10},
+	PlanTierEnterprise: {VerificationsPerMinute: 1000, Burst: 500, MaxConcurrent: 50},
+}
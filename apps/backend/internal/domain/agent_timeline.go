@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AgentTimelineEntryType is the kind of record an agent timeline entry comes from
+type AgentTimelineEntryType string
+
+const (
+	TimelineVerification     AgentTimelineEntryType = "verification"      // A verification event of the agent
+	TimelineAttestation      AgentTimelineEntryType = "attestation"       // An MCP server attestation the agent made
+	TimelineDrift            AgentTimelineEntryType = "drift"             // A verification that detected configuration drift
+	TimelineTrustChange      AgentTimelineEntryType = "trust_change"      // A recorded trust score change
+	TimelineKeyRotation      AgentTimelineEntryType = "key_rotation"      // A key or credential rotation
+	TimelineCapabilityChange AgentTimelineEntryType = "capability_change" // A capability granted, revoked or deprecated
+	TimelineAlert            AgentTimelineEntryType = "alert"             // An alert raised about the agent
+)
+
+// AgentTimelineEntryTypes lists every timeline entry type
+var AgentTimelineEntryTypes = []AgentTimelineEntryType{
+	TimelineVerification,
+	TimelineAttestation,
+	TimelineDrift,
+	TimelineTrustChange,
+	TimelineKeyRotation,
+	TimelineCapabilityChange,
+	TimelineAlert,
+}
+
+// AgentTimelineEntry is one thing that happened to an agent. SourceID is the ID of the
+// record it was read from; Details carries the fields specific to its type.
+type AgentTimelineEntry struct {
+	Type       AgentTimelineEntryType `json:"type"`
+	SourceID   uuid.UUID              `json:"sourceId"`
+	OccurredAt time.Time              `json:"occurredAt"`
+	Summary    string                 `json:"summary"`
+	Details    map[string]interface{} `json:"details"`
+}
+
+// AgentTimelineQuery selects a page of an agent's timeline
+type AgentTimelineQuery struct {
+	Types  []AgentTimelineEntryType // Empty means every type
+	Start  *time.Time               // Inclusive
+	End    *time.Time               // Exclusive
+	Limit  int
+	Offset int
+}
+
+// AgentTimelineRepository reads the records that make up an agent's timeline
+type AgentTimelineRepository interface {
+	// GetTimeline returns a page of the agent's timeline entries, newest first, and the total
+	// number of entries matching the query
+	GetTimeline(agentID uuid.UUID, query AgentTimelineQuery) ([]*AgentTimelineEntry, int, error)
+}
@@ -18,6 +18,25 @@ const (
 	AlertSecurityBreach         AlertType = "security_breach"
 	AlertUnusualActivity        AlertType = "unusual_activity"
 	AlertTypeConfigurationDrift AlertType = "configuration_drift"
+	AlertTypeCapabilityDrift    AlertType = "capability_drift"          // Agent used capabilities it never declared
+	AlertMCPServerPendingReview AlertType = "mcp_server_pending_review" // MCP server auto-registered from an attestation
+	AlertEmergencyAccessUsed    AlertType = "emergency_access_used"     // Break-glass credential activated for an agent
+	AlertSBOMVulnerability      AlertType = "sbom_vulnerability"        // Agent SBOM contains components with critical CVEs
+	AlertCapabilityDeprecated   AlertType = "capability_deprecated"     // Agent capabilities refer to tools an MCP server dropped
+	AlertPolicyViolation        AlertType = "policy_violation"          // An expression security policy triggered
+	AlertMCPConfidenceLow       AlertType = "mcp_confidence_low"        // MCP server confidence score fell below the attestation threshold
+	AlertMCPToolsChanged        AlertType = "mcp_tools_changed"         // Capability discovery found a verified MCP server's tool list changed
+	AlertConfigChangeRolledBack AlertType = "config_change_rolled_back" // A scheduled configuration change was rolled back after errors rose
+	AlertSharedCredential       AlertType = "shared_credential"         // The agent's public key is also registered to other agents
+	AlertTypePeerDrift          AlertType = "peer_drift"                // Agent called another agent no peer policy declares
+	AlertTypeMCPToolDrift       AlertType = "mcp_tool_drift"            // Agent called MCP tools outside its tool grants
+	AlertConnectionLatencySLO   AlertType = "connection_latency_slo"    // An agent-MCP connection's p95 latency stayed above its SLO
+	AlertIncidentSLABreached    AlertType = "incident_sla_breached"     // A security incident was not acknowledged or resolved in time
+	AlertAttestationCadence     AlertType = "attestation_cadence"       // A verified MCP server is not attested as often as its criticality requires
+	AlertImpersonationRequested AlertType = "impersonation_requested"   // A platform operator asks an admin to consent to impersonation
+	AlertCapabilityExpired      AlertType = "capability_expired"        // A time-bound capability of the agent expired and was removed
+	AlertMCPServerUnhealthy     AlertType = "mcp_server_unhealthy"      // An MCP server failed its health checks repeatedly and was suspended
+	AlertTrustScoreThreshold    AlertType = "trust_score_threshold"     // A trust score trigger of the organization fired
 )
 
 // AlertSeverity represents alert severity level
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlertSuppressionAction is what a suppression rule does to the alerts it matches
+type AlertSuppressionAction string
+
+const (
+	AlertSuppressionDrop      AlertSuppressionAction = "drop"      // Record the alert but send no notification
+	AlertSuppressionDowngrade AlertSuppressionAction = "downgrade" // Record and notify at a lower severity
+)
+
+// AlertSuppressionRule silences a known-noisy signal. Matched alerts are always recorded;
+// the rule only changes how they are notified. Every rule expires so none stays silent forever.
+type AlertSuppressionRule struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Enabled        bool      `json:"enabled"`
+
+	// Matching - empty or nil conditions match every alert
+	AlertTypes   []AlertType `json:"alertTypes"`
+	ResourceType string      `json:"resourceType,omitempty"`
+	ResourceID   *uuid.UUID  `json:"resourceId,omitempty"`
+	TagID        *uuid.UUID  `json:"tagId,omitempty"` // Alerts about agents carrying the tag
+	// Daily window in UTC as "HH:MM"; a window whose end is before its start spans midnight
+	WindowStart string `json:"windowStart,omitempty"`
+	WindowEnd   string `json:"windowEnd,omitempty"`
+	// Only match an alert once its fingerprint (type, resource and title) repeated at least
+	// RepeatThreshold times, this one included, within RepeatWindowMinutes
+	RepeatThreshold     int `json:"repeatThreshold"`
+	RepeatWindowMinutes int `json:"repeatWindowMinutes"`
+
+	Action      AlertSuppressionAction `json:"action"`
+	DowngradeTo AlertSeverity          `json:"downgradeTo,omitempty"`
+
+	HitCount  int64      `json:"hitCount"`
+	LastHitAt *time.Time `json:"lastHitAt,omitempty"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	CreatedBy uuid.UUID  `json:"createdBy"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// IsActive reports whether the rule is enabled and not expired at the given time
+func (r *AlertSuppressionRule) IsActive(at time.Time) bool {
+	return r.Enabled && at.Before(r.ExpiresAt)
+}
+
+// AlertSuppressionHit records one alert a rule matched
+type AlertSuppressionHit struct {
+	ID               uuid.UUID              `json:"id"`
+	RuleID           uuid.UUID              `json:"ruleId"`
+	OrganizationID   uuid.UUID              `json:"organizationId"`
+	AlertID          uuid.UUID              `json:"alertId"`
+	Action           AlertSuppressionAction `json:"action"`
+	OriginalSeverity AlertSeverity          `json:"originalSeverity"`
+	CreatedAt        time.Time              `json:"createdAt"`
+}
+
+// AlertSuppressionRepository defines the interface for alert suppression rule persistence
+type AlertSuppressionRepository interface {
+	Create(rule *AlertSuppressionRule) error
+	GetByID(id uuid.UUID) (*AlertSuppressionRule, error)
+	GetByOrganization(orgID uuid.UUID) ([]*AlertSuppressionRule, error)
+	// GetActiveByOrganization returns enabled, unexpired rules, oldest first
+	GetActiveByOrganization(orgID uuid.UUID, at time.Time) ([]*AlertSuppressionRule, error)
+	Update(rule *AlertSuppressionRule) error
+	Delete(id uuid.UUID) error
+
+	// RecordHit stores the hit and bumps the rule's hit counter
+	RecordHit(hit *AlertSuppressionHit) error
+	GetHits(ruleID uuid.UUID, limit, offset int) ([]*AlertSuppressionHit, error)
+
+	// CountMatchingAlerts counts the organization's alerts with the same type, resource
+	// and title as alert that were raised since the given time
+	CountMatchingAlerts(alert *Alert, since time.Time) (int, error)
+}
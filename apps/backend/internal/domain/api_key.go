@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,6 +21,68 @@ type APIKey struct {
 	IsActive       bool       `json:"isActive"`
 	CreatedAt      time.Time  `json:"createdAt"`
 	CreatedBy      uuid.UUID  `json:"createdBy"`
+	Scopes         []string   `json:"scopes"` // Empty = APIKeyLegacyScopes (keys created before scoping)
+}
+
+// API key scopes. A scope is "resource:action"; "resource:*" grants every action on a
+// resource and "*" grants everything.
+const (
+	APIKeyScopeAll          = "*"
+	APIKeyScopeAgentsRead   = "agents:read"
+	APIKeyScopeAgentsWrite  = "agents:write"
+	APIKeyScopeVerifyCreate = "verify:create"
+	APIKeyScopeVerifyRead   = "verify:read"
+	APIKeyScopeIntrospect   = "tokens:introspect"
+)
+
+// APIKeyScopes lists the scopes an API key can be created with
+var APIKeyScopes = []string{
+	APIKeyScopeAgentsRead,
+	APIKeyScopeAgentsWrite,
+	APIKeyScopeVerifyCreate,
+	APIKeyScopeVerifyRead,
+	APIKeyScopeIntrospect,
+}
+
+// APIKeyLegacyScopes are the scopes of a key without scopes, created before scoping: what the
+// SDK used keys for, verifications and one-line agent registration
+var APIKeyLegacyScopes = []string{"verify:*", APIKeyScopeAgentsWrite}
+
+// IsValidAPIKeyScope reports whether scope is a known scope, a resource wildcard or "*"
+func IsValidAPIKeyScope(scope string) bool {
+	if scope == APIKeyScopeAll {
+		return true
+	}
+	for _, known := range APIKeyScopes {
+		if scope == known || scope == apiKeyScopeResource(known)+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyScopesAllow reports whether a key carrying scopes may perform required.
+// A key without scopes carries APIKeyLegacyScopes.
+func APIKeyScopesAllow(scopes []string, required string) bool {
+	if len(scopes) == 0 {
+		scopes = APIKeyLegacyScopes
+	}
+	for _, scope := range scopes {
+		if scope == APIKeyScopeAll || scope == required || scope == apiKeyScopeResource(required)+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the key may perform the scoped action
+func (k *APIKey) HasScope(scope string) bool {
+	return APIKeyScopesAllow(k.Scopes, scope)
+}
+
+func apiKeyScopeResource(scope string) string {
+	resource, _, _ := strings.Cut(scope, ":")
+	return resource
 }
 
 // APIKeyRepository defines the interface for API key persistence
@@ -32,4 +95,5 @@ type APIKeyRepository interface {
 	Revoke(id uuid.UUID) error
 	Delete(id uuid.UUID) error
 	UpdateLastUsed(id uuid.UUID) error
+	UpdateExpiry(id uuid.UUID, expiresAt *time.Time) error
 }
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ApprovalAction is a sensitive action that an organization can put behind an approval chain
+type ApprovalAction string
+
+const (
+	ApprovalActionCapabilityGrant   ApprovalAction = "capability_grant"   // Approving a capability request
+	ApprovalActionAgentVerification ApprovalAction = "agent_verification" // Verifying an agent
+	ApprovalActionPolicyDisable     ApprovalAction = "policy_disable"     // Disabling a security policy
+	ApprovalActionConfigChange      ApprovalAction = "config_change"      // Scheduling a staged configuration change
+)
+
+// IsValid reports whether the action is one that approval chains can guard
+func (a ApprovalAction) IsValid() bool {
+	switch a {
+	case ApprovalActionCapabilityGrant, ApprovalActionAgentVerification, ApprovalActionPolicyDisable, ApprovalActionConfigChange:
+		return true
+	}
+	return false
+}
+
+// ApprovalStep is one approval in a chain. An empty role accepts any user who may perform the
+// action; otherwise the approver needs at least that role. Every step of a chain must be
+// approved by a different user.
+type ApprovalStep struct {
+	Role UserRole `json:"role,omitempty"`
+}
+
+// ApprovalChain requires an action to be approved by several distinct users, in order, before
+// it takes effect. Chains can be scoped: capability grants by the risk of the capability, agent
+// verification by agent tags (e.g. "environment:prod").
+type ApprovalChain struct {
+	ID             uuid.UUID      `json:"id"`
+	OrganizationID uuid.UUID      `json:"organizationId"`
+	Name           string         `json:"name"`
+	Action         ApprovalAction `json:"action"`
+	Steps          []ApprovalStep `json:"steps"`
+	// MinRiskSeverity limits a capability_grant chain to capabilities at least this risky
+	MinRiskSeverity AlertSeverity `json:"minRiskSeverity,omitempty"`
+	// AgentTags limits an agent_verification chain to agents with any of these "key:value" tags
+	AgentTags []string  `json:"agentTags"`
+	IsEnabled bool      `json:"isEnabled"`
+	CreatedBy uuid.UUID `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ApprovalRequestStatus represents the state of an action waiting on its approval chain
+type ApprovalRequestStatus string
+
+const (
+	ApprovalRequestStatusPending  ApprovalRequestStatus = "pending"
+	ApprovalRequestStatusApproved ApprovalRequestStatus = "approved"
+	ApprovalRequestStatusRejected ApprovalRequestStatus = "rejected"
+)
+
+// ApprovalDecision records one user's decision on an approval request
+type ApprovalDecision struct {
+	Step      int       `json:"step"` // Index of the approved step; rejections use the step they stopped at
+	UserID    uuid.UUID `json:"userId"`
+	UserEmail string    `json:"userEmail"`
+	Role      UserRole  `json:"role"`
+	Approved  bool      `json:"approved"`
+	Comment   string    `json:"comment,omitempty"`
+	DecidedAt time.Time `json:"decidedAt"`
+}
+
+// ApprovalRequest tracks the approvals collected for one action on one resource. The chain's
+// steps are copied when the request is opened, so editing a chain does not change requests
+// already in flight.
+type ApprovalRequest struct {
+	ID             uuid.UUID             `json:"id"`
+	OrganizationID uuid.UUID             `json:"organizationId"`
+	ChainID        uuid.UUID             `json:"chainId"`
+	ChainName      string                `json:"chainName"`
+	Action         ApprovalAction        `json:"action"`
+	ResourceType   string                `json:"resourceType"`
+	ResourceID     uuid.UUID             `json:"resourceId"`
+	Summary        string                `json:"summary"`
+	Steps          []ApprovalStep        `json:"steps"`
+	Decisions      []ApprovalDecision    `json:"decisions"`
+	Status         ApprovalRequestStatus `json:"status"`
+	RequestedBy    *uuid.UUID            `json:"requestedBy,omitempty"` // Initiator; may not approve their own request
+	CreatedAt      time.Time             `json:"createdAt"`
+	CompletedAt    *time.Time            `json:"completedAt,omitempty"`
+}
+
+// NextStep returns the index of the next step to approve, or -1 once every step is approved
+func (r *ApprovalRequest) NextStep() int {
+	approved := 0
+	for _, decision := range r.Decisions {
+		if decision.Approved {
+			approved++
+		}
+	}
+	if approved >= len(r.Steps) {
+		return -1
+	}
+	return approved
+}
+
+// ApprovalChainRepository defines the interface for approval chain persistence
+type ApprovalChainRepository interface {
+	Create(chain *ApprovalChain) error
+	GetByID(id uuid.UUID) (*ApprovalChain, error)
+	GetByOrganization(orgID uuid.UUID) ([]*ApprovalChain, error)
+	// GetEnabledByAction returns the organization's enabled chains guarding the action
+	GetEnabledByAction(orgID uuid.UUID, action ApprovalAction) ([]*ApprovalChain, error)
+	Update(chain *ApprovalChain) error
+	Delete(id uuid.UUID) error
+}
+
+// ApprovalRequestRepository defines the interface for approval request persistence
+type ApprovalRequestRepository interface {
+	// Create opens a request; it fails if one is already pending for the same action and resource
+	Create(request *ApprovalRequest) error
+	GetByID(id uuid.UUID) (*ApprovalRequest, error)
+	// GetPending returns the pending request for an action on a resource
+	GetPending(orgID uuid.UUID, action ApprovalAction, resourceID uuid.UUID) (*ApprovalRequest, error)
+	GetByOrganization(orgID uuid.UUID, status ApprovalRequestStatus, limit, offset int) ([]*ApprovalRequest, error)
+	// GetByUser returns the organization's requests the user opened or decided, newest first
+	GetByUser(orgID, userID uuid.UUID) ([]*ApprovalRequest, error)
+
+	// RecordDecision stores the request's decisions, status and completion time if it still has
+	// expectedDecisions decisions and is pending. It returns false when another decision won the race.
+	RecordDecision(request *ApprovalRequest, expectedDecisions int) (bool, error)
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrArtifactNotFound is returned when an artifact does not exist in object storage
+var ErrArtifactNotFound = errors.New("artifact not found")
+
+// ArtifactKind is the subsystem a stored artifact belongs to. Each kind has its own
+// key prefix and retention.
+type ArtifactKind string
+
+const (
+	ArtifactExport        ArtifactKind = "exports"         // Data exports
+	ArtifactArchivedEvent ArtifactKind = "archived-events" // Verification events moved out of the database
+	ArtifactAttestation   ArtifactKind = "attestations"    // Raw attestation payloads
+	ArtifactReport        ArtifactKind = "reports"         // Rendered report files (CSV, PDF)
+	ArtifactSBOM          ArtifactKind = "sboms"           // SBOM documents
+)
+
+// ArtifactKinds lists every artifact kind
+var ArtifactKinds = []ArtifactKind{
+	ArtifactExport,
+	ArtifactArchivedEvent,
+	ArtifactAttestation,
+	ArtifactReport,
+	ArtifactSBOM,
+}
+
+// Artifact is a file kept in object storage for an organization. Data is only set when the
+// artifact was read.
+type Artifact struct {
+	OrganizationID uuid.UUID    `json:"organizationId"`
+	Kind           ArtifactKind `json:"kind"`
+	Name           string       `json:"name"`
+	ContentType    string       `json:"contentType,omitempty"`
+	Size           int64        `json:"size"`
+	ModifiedAt     time.Time    `json:"modifiedAt"`
+	Data           []byte       `json:"-"`
+}
+
+// ArtifactStore keeps artifacts in object storage, isolated per organization
+type ArtifactStore interface {
+	// Put writes the artifact, replacing an existing one with the same name
+	Put(ctx context.Context, orgID uuid.UUID, kind ArtifactKind, name string, data []byte, contentType string) (*Artifact, error)
+	// Get reads the artifact including its data; ErrArtifactNotFound when it does not exist
+	Get(ctx context.Context, orgID uuid.UUID, kind ArtifactKind, name string) (*Artifact, error)
+	// Delete removes the artifact; deleting a missing artifact is not an error
+	Delete(ctx context.Context, orgID uuid.UUID, kind ArtifactKind, name string) error
+	// List returns the artifacts of a kind without their data
+	List(ctx context.Context, orgID uuid.UUID, kind ArtifactKind) ([]*Artifact, error)
+	// ApplyLifecycle deletes the organization's artifacts that are past their kind's retention
+	// and returns how many were deleted
+	ApplyLifecycle(ctx context.Context, orgID uuid.UUID) (int, error)
+}
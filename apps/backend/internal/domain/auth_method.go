@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// How the caller of a request authenticated
+const (
+	AuthMethodJWT                 = "jwt"                   // User session
+	AuthMethodAPIKey              = "api_key"               // Agent or organization API key
+	AuthMethodEd25519             = "ed25519"               // Request signed with the agent's key
+	AuthMethodMTLS                = "mtls"                  // TLS client certificate issued by the platform CA
+	AuthMethodPersonalAccessToken = "personal_access_token" // User automation token
+	AuthMethodImpersonation       = "impersonation"         // Platform operator session consented to by an admin
+)
+
+type authMethodKey struct{}
+
+// WithAuthMethod returns a context recording how the caller authenticated
+func WithAuthMethod(ctx context.Context, method string) context.Context {
+	return context.WithValue(ctx, authMethodKey{}, method)
+}
+
+// AuthMethodFromContext returns how the caller authenticated; empty for internal calls
+func AuthMethodFromContext(ctx context.Context) string {
+	method, _ := ctx.Value(authMethodKey{}).(string)
+	return method
+}
+
+// Caller is the credential behind a request: the user, agent and API key it acts as, and where
+// the request came from. IDs the credential does not carry are nil.
+type Caller struct {
+	UserID          *uuid.UUID
+	AgentID         *uuid.UUID
+	APIKeyID        *uuid.UUID
+	ImpersonationID *uuid.UUID // Consented impersonation the operator acts through
+	IP              string
+	UserAgent       string
+}
+
+type callerKey struct{}
+
+// WithCaller returns a context recording the credential behind the request
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the credential behind the request; false for internal calls
+func CallerFromContext(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(Caller)
+	return caller, ok
+}
@@ -15,8 +15,21 @@ type AgentCapability struct {
 	GrantedBy       *uuid.UUID             `json:"grantedBy,omitempty"`
 	GrantedAt       time.Time              `json:"grantedAt"`
 	RevokedAt       *time.Time             `json:"revokedAt,omitempty"`
+	ExpiresAt       *time.Time             `json:"expiresAt,omitempty"` // nil for capabilities that never expire
 	CreatedAt       time.Time              `json:"createdAt"`
 	UpdatedAt       time.Time              `json:"updatedAt"`
+	// Set on reads when the MCP tool this capability refers to is no longer exposed
+	Deprecation *CapabilityDeprecation `json:"deprecation,omitempty"`
+}
+
+// Expired reports whether the capability's expiry has passed at the given time
+func (c *AgentCapability) Expired(at time.Time) bool {
+	return c.ExpiresAt != nil && !c.ExpiresAt.After(at)
+}
+
+// Active reports whether the capability is neither revoked nor expired at the given time
+func (c *AgentCapability) Active(at time.Time) bool {
+	return c.RevokedAt == nil && !c.Expired(at)
 }
 
 // CapabilityViolation represents an attempt to perform an action outside capability scope
@@ -44,6 +57,10 @@ type CapabilityRepository interface {
 	RevokeCapability(id uuid.UUID, revokedAt time.Time) error
 	DeleteCapability(id uuid.UUID) error
 
+	// Capability expiry
+	GetExpiredCapabilities(at time.Time) ([]*AgentCapability, error) // Non-revoked capabilities expired at the given time
+	SetCapabilityExpiry(id uuid.UUID, expiresAt *time.Time) error
+
 	// Violation tracking
 	CreateViolation(violation *CapabilityViolation) error
 	GetViolationByID(id uuid.UUID) (*CapabilityViolation, error)
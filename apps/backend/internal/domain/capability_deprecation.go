@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CapabilityDeprecationSource is what showed that an MCP server stopped exposing a tool
+type CapabilityDeprecationSource string
+
+const (
+	CapabilityDeprecationSourceDiscovery   CapabilityDeprecationSource = "discovery"   // Capability auto-discovery
+	CapabilityDeprecationSourceAttestation CapabilityDeprecationSource = "attestation" // An agent attestation
+)
+
+// CapabilityDeprecation marks an agent capability that refers to a tool its MCP server no
+// longer exposes. It is resolved when the tool comes back or the capability is revoked.
+type CapabilityDeprecation struct {
+	ID             uuid.UUID                   `json:"id"`
+	OrganizationID uuid.UUID                   `json:"organizationId"`
+	AgentID        uuid.UUID                   `json:"agentId"`
+	AgentName      string                      `json:"agentName"`
+	CapabilityID   uuid.UUID                   `json:"capabilityId"`
+	CapabilityType string                      `json:"capabilityType"`
+	MCPServerID    uuid.UUID                   `json:"mcpServerId"`
+	MCPServerName  string                      `json:"mcpServerName"`
+	ToolName       string                      `json:"toolName"`
+	Source         CapabilityDeprecationSource `json:"source"`
+	DeprecatedAt   time.Time                   `json:"deprecatedAt"`
+	ResolvedAt     *time.Time                  `json:"resolvedAt,omitempty"`
+}
+
+// CapabilityDeprecationRepository persists deprecated capability references
+type CapabilityDeprecationRepository interface {
+	Create(deprecation *CapabilityDeprecation) error
+	// GetActiveByServer returns the unresolved deprecations caused by the MCP server
+	GetActiveByServer(serverID uuid.UUID) ([]*CapabilityDeprecation, error)
+	// GetActiveByAgent returns the agent's unresolved deprecations
+	GetActiveByAgent(agentID uuid.UUID) ([]*CapabilityDeprecation, error)
+	Resolve(id uuid.UUID, resolvedAt time.Time) error
+}
@@ -17,17 +17,19 @@ const (
 
 // CapabilityRequest represents a request for additional agent capabilities after registration
 type CapabilityRequest struct {
-	ID             uuid.UUID               `json:"id" db:"id"`
-	AgentID        uuid.UUID               `json:"agentId" db:"agent_id"`
-	CapabilityType string                  `json:"capabilityType" db:"capability_type"`
-	Reason         string                  `json:"reason" db:"reason"`
-	Status         CapabilityRequestStatus `json:"status" db:"status"`
-	RequestedBy    uuid.UUID               `json:"requestedBy" db:"requested_by"`
-	ReviewedBy     *uuid.UUID              `json:"reviewedBy,omitempty" db:"reviewed_by"`
-	RequestedAt    time.Time               `json:"requestedAt" db:"requested_at"`
-	ReviewedAt     *time.Time              `json:"reviewedAt,omitempty" db:"reviewed_at"`
-	CreatedAt      time.Time               `json:"createdAt" db:"created_at"`
-	UpdatedAt      time.Time               `json:"updatedAt" db:"updated_at"`
+	ID                 uuid.UUID               `json:"id" db:"id"`
+	AgentID            uuid.UUID               `json:"agentId" db:"agent_id"`
+	CapabilityType     string                  `json:"capabilityType" db:"capability_type"`
+	Reason             string                  `json:"reason" db:"reason"`
+	Status             CapabilityRequestStatus `json:"status" db:"status"`
+	RequestedBy        uuid.UUID               `json:"requestedBy" db:"requested_by"`
+	ReviewedBy         *uuid.UUID              `json:"reviewedBy,omitempty" db:"reviewed_by"`
+	RequestedAt        time.Time               `json:"requestedAt" db:"requested_at"`
+	ReviewedAt         *time.Time              `json:"reviewedAt,omitempty" db:"reviewed_at"`
+	ExpiresAt          *time.Time              `json:"expiresAt,omitempty" db:"expires_at"`                    // When the granted capability expires; nil grants it permanently
+	RenewsCapabilityID *uuid.UUID              `json:"renewsCapabilityId,omitempty" db:"renews_capability_id"` // Set on renewal requests to the capability they extend
+	CreatedAt          time.Time               `json:"createdAt" db:"created_at"`
+	UpdatedAt          time.Time               `json:"updatedAt" db:"updated_at"`
 }
 
 // CapabilityRequestWithDetails includes agent and user details for API responses
@@ -41,10 +43,22 @@ type CapabilityRequestWithDetails struct {
 
 // CreateCapabilityRequestInput represents input for creating a new capability request
 type CreateCapabilityRequestInput struct {
-	AgentID        uuid.UUID `json:"agentId" validate:"required"`
-	CapabilityType string    `json:"capabilityType" validate:"required"`
-	Reason         string    `json:"reason" validate:"required,min=10"`
-	RequestedBy    uuid.UUID `json:"-"` // Set from authenticated user context
+	AgentID        uuid.UUID  `json:"agentId" validate:"required"`
+	CapabilityType string     `json:"capabilityType" validate:"required"`
+	Reason         string     `json:"reason" validate:"required,min=10"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"` // Optional: the granted capability expires at this time
+	RequestedBy    uuid.UUID  `json:"-"`                   // Set from authenticated user context
+}
+
+// RenewCapabilityInput represents input for requesting a later expiry for a time-bound capability.
+// The renewal request carries the justification of the request that originally granted it.
+type RenewCapabilityInput struct {
+	AgentID      uuid.UUID `json:"-"`
+	CapabilityID uuid.UUID `json:"-"`
+	ExpiresAt    time.Time `json:"expiresAt" validate:"required"`
+	// Only used when the capability was not granted through a capability request
+	Reason      string    `json:"reason,omitempty"`
+	RequestedBy uuid.UUID `json:"-"` // Set from authenticated user context
 }
 
 // CapabilityRequestRepository defines the interface for capability request data access
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrChangeRequestNotFound is returned when a change request does not exist
+var ErrChangeRequestNotFound = errors.New("change request not found")
+
+// ChangeTargetType is the kind of configuration a change request modifies
+type ChangeTargetType string
+
+const (
+	ChangeTargetAgent          ChangeTargetType = "agent"
+	ChangeTargetSecurityPolicy ChangeTargetType = "security_policy"
+)
+
+// ChangeRequestStatus tracks a staged configuration change from approval to its outcome
+type ChangeRequestStatus string
+
+const (
+	ChangeRequestStatusPendingApproval ChangeRequestStatus = "pending_approval"
+	ChangeRequestStatusScheduled       ChangeRequestStatus = "scheduled"   // Approved, waiting for its change window
+	ChangeRequestStatusApplied         ChangeRequestStatus = "applied"     // In effect and monitored for errors
+	ChangeRequestStatusCompleted       ChangeRequestStatus = "completed"   // Monitoring ended without a rollback
+	ChangeRequestStatusRolledBack      ChangeRequestStatus = "rolled_back" // Previous values restored
+	ChangeRequestStatusRejected        ChangeRequestStatus = "rejected"
+	ChangeRequestStatusCancelled       ChangeRequestStatus = "cancelled"
+	ChangeRequestStatusExpired         ChangeRequestStatus = "expired" // The change window ended before it was applied
+	ChangeRequestStatusFailed          ChangeRequestStatus = "failed"
+)
+
+// IsOpen reports whether the change has not been applied or closed yet
+func (s ChangeRequestStatus) IsOpen() bool {
+	return s == ChangeRequestStatusPendingApproval || s == ChangeRequestStatusScheduled
+}
+
+// ChangeRequest stages a configuration change to an agent or security policy. Once approved it
+// is applied when its change window opens, then watched for MonitorMinutes: if the verification
+// error rate rises above RollbackErrorRate (and above the rate before the change), the previous
+// values are restored.
+type ChangeRequest struct {
+	ID             uuid.UUID        `json:"id"`
+	OrganizationID uuid.UUID        `json:"organizationId"`
+	TargetType     ChangeTargetType `json:"targetType"`
+	TargetID       uuid.UUID        `json:"targetId"`
+	Summary        string           `json:"summary"`
+	// Changes maps the target's JSON field names to their new values
+	Changes map[string]interface{} `json:"changes"`
+	// Previous holds the values the changed fields had when the change was applied
+	Previous          map[string]interface{} `json:"previous,omitempty"`
+	EffectiveAt       time.Time              `json:"effectiveAt"`
+	WindowEndsAt      *time.Time             `json:"windowEndsAt,omitempty"`
+	MonitorMinutes    int                    `json:"monitorMinutes"`    // 0 disables automatic rollback
+	RollbackErrorRate float64                `json:"rollbackErrorRate"` // 0-1
+	BaselineErrorRate *float64               `json:"baselineErrorRate,omitempty"`
+	Status            ChangeRequestStatus    `json:"status"`
+	ApprovalRequestID *uuid.UUID             `json:"approvalRequestId,omitempty"` // Set when an approval chain guards the change
+	RequestedBy       uuid.UUID              `json:"requestedBy"`
+	ApprovedBy        *uuid.UUID             `json:"approvedBy,omitempty"`
+	ApprovedAt        *time.Time             `json:"approvedAt,omitempty"`
+	AppliedAt         *time.Time             `json:"appliedAt,omitempty"`
+	ClosedAt          *time.Time             `json:"closedAt,omitempty"`
+	Reason            string                 `json:"reason,omitempty"` // Why the change was rejected, failed or rolled back
+	CreatedAt         time.Time              `json:"createdAt"`
+	UpdatedAt         time.Time              `json:"updatedAt"`
+}
+
+// ChangeRequestRepository defines the interface for change request persistence
+type ChangeRequestRepository interface {
+	Create(change *ChangeRequest) error
+	GetByID(id uuid.UUID) (*ChangeRequest, error)
+	// GetByOrganization lists a page of an organization's change requests, newest first; an empty
+	// status lists all of them
+	GetByOrganization(orgID uuid.UUID, status ChangeRequestStatus, limit, offset int) ([]*ChangeRequest, int, error)
+	// GetByStatus returns the change requests of every organization in one of the statuses,
+	// oldest effective time first
+	GetByStatus(statuses ...ChangeRequestStatus) ([]*ChangeRequest, error)
+	Update(change *ChangeRequest) error
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CompromiseTrigger records why an agent was marked compromised
+type CompromiseTrigger string
+
+const (
+	CompromiseTriggerManual              CompromiseTrigger = "manual"               // Marked by an administrator
+	CompromiseTriggerCapabilityViolation CompromiseTrigger = "capability_violation" // Repeated violations / trust below threshold
+)
+
+// CompromiseActionType identifies one step of the response bundle
+type CompromiseActionType string
+
+const (
+	CompromiseActionMarkCompromised        CompromiseActionType = "mark_compromised"
+	CompromiseActionRevokeAPIKeys          CompromiseActionType = "revoke_api_keys"
+	CompromiseActionRevokeSDKTokens        CompromiseActionType = "revoke_owner_sdk_tokens"
+	CompromiseActionInvalidateAttestations CompromiseActionType = "invalidate_attestations"
+	CompromiseActionNotifyMCPOwners        CompromiseActionType = "notify_mcp_owners"
+	CompromiseActionOpenIncident           CompromiseActionType = "open_incident"
+	CompromiseActionFreezeTrustScore       CompromiseActionType = "freeze_trust_score"
+	CompromiseActionRevokeCertificates     CompromiseActionType = "revoke_certificates"
+)
+
+// CompromiseActionStatus is the outcome of one step of the response bundle
+type CompromiseActionStatus string
+
+const (
+	CompromiseActionCompleted CompromiseActionStatus = "completed"
+	CompromiseActionSkipped   CompromiseActionStatus = "skipped" // Disabled by policy or not available
+	CompromiseActionFailed    CompromiseActionStatus = "failed"
+)
+
+// CompromiseResponsePolicy configures which steps run when an agent of the organization is marked compromised
+type CompromiseResponsePolicy struct {
+	ID                     uuid.UUID     `json:"id"`
+	OrganizationID         uuid.UUID     `json:"organizationId"`
+	RevokeAPIKeys          bool          `json:"revokeApiKeys"`
+	RevokeOwnerSDKTokens   bool          `json:"revokeOwnerSdkTokens"` // Affects every agent of the owner, off by default
+	InvalidateAttestations bool          `json:"invalidateAttestations"`
+	NotifyMCPOwners        bool          `json:"notifyMcpOwners"`
+	OpenIncident           bool          `json:"openIncident"`
+	FreezeTrustScore       bool          `json:"freezeTrustScore"`
+	IncidentSeverity       AlertSeverity `json:"incidentSeverity"`
+	UpdatedBy              *uuid.UUID    `json:"updatedBy,omitempty"`
+	CreatedAt              time.Time     `json:"createdAt"`
+	UpdatedAt              time.Time     `json:"updatedAt"`
+}
+
+// DefaultCompromiseResponsePolicy is used for organizations that have not configured a policy
+func DefaultCompromiseResponsePolicy(orgID uuid.UUID) *CompromiseResponsePolicy {
+	return &CompromiseResponsePolicy{
+		OrganizationID:         orgID,
+		RevokeAPIKeys:          true,
+		RevokeOwnerSDKTokens:   false,
+		InvalidateAttestations: true,
+		NotifyMCPOwners:        true,
+		OpenIncident:           true,
+		FreezeTrustScore:       true,
+		IncidentSeverity:       AlertSeverityCritical,
+	}
+}
+
+// CompromiseAction is the outcome of one step of the response bundle
+type CompromiseAction struct {
+	Action CompromiseActionType   `json:"action"`
+	Status CompromiseActionStatus `json:"status"`
+	Count  int                    `json:"count"`            // Items affected (keys revoked, owners notified, ...)
+	Detail string                 `json:"detail,omitempty"` // Human readable summary
+	Error  *string                `json:"error,omitempty"`
+}
+
+// CompromiseResponse is the report of the response bundle executed for a compromised agent
+type CompromiseResponse struct {
+	ID             uuid.UUID          `json:"id"`
+	OrganizationID uuid.UUID          `json:"organizationId"`
+	AgentID        uuid.UUID          `json:"agentId"`
+	Trigger        CompromiseTrigger  `json:"trigger"`
+	Reason         string             `json:"reason"`
+	TriggeredBy    *uuid.UUID         `json:"triggeredBy,omitempty"`
+	IncidentID     *uuid.UUID         `json:"incidentId,omitempty"`
+	Actions        []CompromiseAction `json:"actions"`
+	CreatedAt      time.Time          `json:"createdAt"`
+}
+
+// CompromiseResponseRepository defines the interface for compromise response persistence
+type CompromiseResponseRepository interface {
+	// GetPolicy returns the organization's policy, or nil if none is configured
+	GetPolicy(orgID uuid.UUID) (*CompromiseResponsePolicy, error)
+	UpsertPolicy(policy *CompromiseResponsePolicy) error
+	CreateResponse(response *CompromiseResponse) error
+	GetResponsesByAgent(agentID uuid.UUID) ([]*CompromiseResponse, error)
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrConnectionLatencySLONotFound is returned when an agent-MCP connection has no latency SLO
+var ErrConnectionLatencySLONotFound = errors.New("connection latency slo not found")
+
+// LatencyAttribution says where a latency regression of an agent-MCP connection comes from
+type LatencyAttribution string
+
+const (
+	// LatencyAttributionServer means most other agents of the MCP server are slow too
+	LatencyAttributionServer LatencyAttribution = "server"
+	// LatencyAttributionAgentPath means the other agents of the MCP server are fine, so the
+	// regression is on the network path of this agent
+	LatencyAttributionAgentPath LatencyAttribution = "agent_path"
+	// LatencyAttributionUndetermined means no other agent attested the MCP server in the window
+	LatencyAttributionUndetermined LatencyAttribution = "undetermined"
+)
+
+// ConnectionLatencySLO is the latency objective of an agent's connection to an MCP server. The
+// connection's p95 latency is taken from the agent's attestations of the server within the
+// window; attestations whose connection or health check failed count as slower than any
+// objective. An alert is raised once p95 has been above the objective for SustainedMinutes.
+type ConnectionLatencySLO struct {
+	ID               uuid.UUID `json:"id"`
+	OrganizationID   uuid.UUID `json:"organizationId"`
+	AgentID          uuid.UUID `json:"agentId"`
+	MCPServerID      uuid.UUID `json:"mcpServerId"`
+	P95ThresholdMs   float64   `json:"p95ThresholdMs"`
+	WindowMinutes    int       `json:"windowMinutes"`    // How far back attestations are sampled
+	SustainedMinutes int       `json:"sustainedMinutes"` // How long p95 must stay above the objective before alerting
+	IsEnabled        bool      `json:"isEnabled"`
+
+	// Evaluation state, updated by the latency SLO job
+	LastP95Ms       *float64           `json:"lastP95Ms"`       // p95 of successful connections; nil without samples
+	LastSamples     int                `json:"lastSamples"`     // Attestations in the last window
+	LastFailures    int                `json:"lastFailures"`    // Of which failed to connect or failed the health check
+	LastEvaluatedAt *time.Time         `json:"lastEvaluatedAt"` // nil until the job first evaluates the SLO
+	BreachedSince   *time.Time         `json:"breachedSince"`   // Set while p95 is above the objective
+	AlertedAt       *time.Time         `json:"alertedAt"`       // Set once the current breach raised an alert
+	Attribution     LatencyAttribution `json:"attribution,omitempty"`
+
+	CreatedBy uuid.UUID `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ConnectionLatencySLORepository stores the latency SLOs of agent-MCP connections
+type ConnectionLatencySLORepository interface {
+	// Upsert stores the SLO of its agent and MCP server, replacing the existing one
+	Upsert(slo *ConnectionLatencySLO) error
+	// GetByConnection returns ErrConnectionLatencySLONotFound when the connection has no SLO
+	GetByConnection(agentID, mcpServerID uuid.UUID) (*ConnectionLatencySLO, error)
+	ListByMCPServer(mcpServerID uuid.UUID) ([]*ConnectionLatencySLO, error)
+	ListEnabled() ([]*ConnectionLatencySLO, error)
+	// UpdateState stores the evaluation state of the SLO
+	UpdateState(slo *ConnectionLatencySLO) error
+	Delete(agentID, mcpServerID uuid.UUID) error
+}
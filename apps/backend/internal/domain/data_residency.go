@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrCrossRegionQuery is returned for a query that would read or write the records of
+	// organizations resident in different regions together
+	ErrCrossRegionQuery = errors.New("cross-region queries are not allowed")
+	// ErrRegionUnavailable is returned when no database cluster is configured for a region
+	ErrRegionUnavailable = errors.New("no database cluster is configured for the region")
+)
+
+// DataRegion is a region with its own database cluster. The agents, verification events and MCP
+// attestations of an organization are stored in the cluster of the region it is resident in.
+type DataRegion string
+
+const (
+	DataRegionUS DataRegion = "us"
+	DataRegionEU DataRegion = "eu"
+)
+
+// DataRegions lists the supported regions
+var DataRegions = []DataRegion{DataRegionUS, DataRegionEU}
+
+// IsValid reports whether the region is supported
+func (r DataRegion) IsValid() bool {
+	for _, region := range DataRegions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
+// DataPlacementKind is the kind of a record stored in a regional cluster
+type DataPlacementKind string
+
+const (
+	DataPlacementAgent             DataPlacementKind = "agent"
+	DataPlacementVerificationEvent DataPlacementKind = "verification_event"
+	DataPlacementMCPAttestation    DataPlacementKind = "mcp_attestation"
+)
+
+// DataPlacement records in the control plane which region a regional record was stored in, so
+// lookups by ID go straight to its cluster
+type DataPlacement struct {
+	ResourceID     uuid.UUID         `json:"resourceId"`
+	Kind           DataPlacementKind `json:"kind"`
+	OrganizationID uuid.UUID         `json:"organizationId"`
+	Region         DataRegion        `json:"region"`
+	CreatedAt      time.Time         `json:"createdAt"`
+}
+
+// DataPlacementRepository stores the placements of regional records in the control plane
+type DataPlacementRepository interface {
+	// Record stores a placement; recording a placed record again changes nothing
+	Record(placement *DataPlacement) error
+	// Get returns nil without an error for records stored before their placement was tracked
+	Get(resourceID uuid.UUID) (*DataPlacement, error)
+	// GetMany omits the records without a placement
+	GetMany(resourceIDs []uuid.UUID) ([]*DataPlacement, error)
+	Delete(resourceID uuid.UUID) error
+	// CountByOrganization counts the organization's placed records of each kind
+	CountByOrganization(orgID uuid.UUID) (map[DataPlacementKind]int, error)
+}
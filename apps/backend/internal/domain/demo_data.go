@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DemoResourceType identifies the kind of resource the demo data generator created
+type DemoResourceType string
+
+const (
+	DemoResourceAgent     DemoResourceType = "agent"
+	DemoResourceMCPServer DemoResourceType = "mcp_server"
+	DemoResourceIncident  DemoResourceType = "incident"
+)
+
+// DemoRecord marks a resource the demo data generator created in an organization, so teardown
+// removes exactly what was seeded and nothing the organization created itself. Records of
+// attestations, events, alerts and trust history are not kept: they go with their agent or
+// MCP server.
+type DemoRecord struct {
+	ID             uuid.UUID        `json:"id"`
+	OrganizationID uuid.UUID        `json:"organizationId"`
+	ResourceType   DemoResourceType `json:"resourceType"`
+	ResourceID     uuid.UUID        `json:"resourceId"`
+	CreatedBy      uuid.UUID        `json:"createdBy"`
+	CreatedAt      time.Time        `json:"createdAt"`
+}
+
+// DemoDataSummary counts the demo data of an organization: what a seed created, what is
+// currently seeded, or what a teardown removed
+type DemoDataSummary struct {
+	OrganizationID     uuid.UUID  `json:"organizationId"`
+	Seeded             bool       `json:"seeded"`
+	SeededAt           *time.Time `json:"seededAt,omitempty"`
+	Agents             int        `json:"agents"`
+	MCPServers         int        `json:"mcpServers"`
+	Incidents          int        `json:"incidents"`
+	Attestations       int        `json:"attestations,omitempty"`
+	Connections        int        `json:"connections,omitempty"`
+	VerificationEvents int        `json:"verificationEvents,omitempty"`
+	TrustScores        int        `json:"trustScores,omitempty"`
+	Alerts             int        `json:"alerts,omitempty"`
+}
+
+// DemoRecordRepository defines the interface for demo record persistence
+type DemoRecordRepository interface {
+	Create(record *DemoRecord) error
+	// GetByOrganization returns the organization's demo records, oldest first
+	GetByOrganization(orgID uuid.UUID) ([]*DemoRecord, error)
+	DeleteByOrganization(orgID uuid.UUID) error
+}
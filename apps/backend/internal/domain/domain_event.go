@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DomainEventType names what happened; it is the last part of the NATS subject or Kafka topic
+// the event is published to
+type DomainEventType string
+
+const (
+	DomainEventVerificationCreated DomainEventType = "verification.created"
+	DomainEventDriftDetected       DomainEventType = "drift.detected"
+	DomainEventTrustScoreChanged   DomainEventType = "trust_score.changed"
+	DomainEventAlertCreated        DomainEventType = "alert.created"
+)
+
+// DomainEventStatus is where an event is in the outbox
+type DomainEventStatus string
+
+const (
+	DomainEventPending    DomainEventStatus = "pending"    // Waiting to be published, or to be retried
+	DomainEventPublishing DomainEventStatus = "publishing" // Claimed by the relay
+	DomainEventPublished  DomainEventStatus = "published"
+)
+
+// DomainEvent is something that happened to an entity, published to the event bus so that
+// consumers (notifications, analytics, SIEM) can react to it outside the API process. Events are
+// written to an outbox first and relayed to the bus from there, at least once: consumers
+// deduplicate by ID.
+type DomainEvent struct {
+	ID             uuid.UUID       `json:"id"`
+	Type           DomainEventType `json:"type"`
+	OrganizationID uuid.UUID       `json:"organizationId"`
+	AggregateType  string          `json:"aggregateType"` // Kind of entity: verification_event, alert or agent
+	AggregateID    uuid.UUID       `json:"aggregateId"`
+	Payload        json.RawMessage `json:"payload"`
+	OccurredAt     time.Time       `json:"occurredAt"`
+
+	// Outbox delivery state, not published
+	Status        DomainEventStatus `json:"-"`
+	Attempts      int               `json:"-"`
+	NextAttemptAt time.Time         `json:"-"`
+	LastError     string            `json:"-"`
+	PublishedAt   *time.Time        `json:"-"`
+}
+
+// TrustScoreChange is the payload of a trust_score.changed event
+type TrustScoreChange struct {
+	AgentID       uuid.UUID `json:"agentId"`
+	AgentName     string    `json:"agentName"`
+	PreviousScore float64   `json:"previousScore"`
+	NewScore      float64   `json:"newScore"`
+}
+
+// DomainEventOutboxRepository stores domain events until the relay has published them
+type DomainEventOutboxRepository interface {
+	Append(event *DomainEvent) error
+	// ClaimDue claims up to limit pending events due for (another) attempt, oldest first. Claimed
+	// events are held for the lease; events held by a relay that died are claimed again after it.
+	ClaimDue(limit int, lease time.Duration) ([]*DomainEvent, error)
+	MarkPublished(ids []uuid.UUID, publishedAt time.Time) error
+	// MarkFailed returns the events to pending, to be retried at nextAttemptAt
+	MarkFailed(ids []uuid.UUID, lastError string, nextAttemptAt time.Time) error
+	// DeletePublished removes the events published before the given time and returns how many
+	DeletePublished(before time.Time) (int, error)
+}
+
+// DomainEventBus publishes domain events to a message broker
+type DomainEventBus interface {
+	// Name identifies the broker, e.g. "nats" or "kafka"
+	Name() string
+	// Publish returns once the broker accepted every event, or an error if it did not
+	Publish(ctx context.Context, events []*DomainEvent) error
+	Close() error
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DriftTrendInterval is the width of the buckets a drift trend is grouped into
+type DriftTrendInterval string
+
+const (
+	DriftTrendIntervalDay   DriftTrendInterval = "day"
+	DriftTrendIntervalWeek  DriftTrendInterval = "week"
+	DriftTrendIntervalMonth DriftTrendInterval = "month"
+)
+
+// DriftTrendScope identifies the level a drift trend report was computed at
+type DriftTrendScope string
+
+const (
+	DriftTrendScopeAgent        DriftTrendScope = "agent"        // A single agent
+	DriftTrendScopeFleet        DriftTrendScope = "fleet"        // A group of agents, compared side by side
+	DriftTrendScopeOrganization DriftTrendScope = "organization" // Every agent in the organization
+)
+
+// DriftAnalyticsFilter selects the drift history a report is computed from
+type DriftAnalyticsFilter struct {
+	OrganizationID uuid.UUID
+	AgentID        *uuid.UUID // Only this agent
+	TagID          *uuid.UUID // Only agents carrying this tag (a fleet)
+	Start          time.Time  // Inclusive
+	End            time.Time  // Exclusive
+}
+
+// DriftOccurrence is a verification of an agent that detected configuration drift
+type DriftOccurrence struct {
+	AgentID         uuid.UUID `json:"agentId"`
+	AgentName       string    `json:"agentName"`
+	MCPServerDrift  []string  `json:"mcpServerDrift"`
+	CapabilityDrift []string  `json:"capabilityDrift"`
+	DetectedAt      time.Time `json:"detectedAt"`
+}
+
+// DriftAlertRecord is a configuration or capability drift alert; acknowledging it (directly or by
+// approving the drift) is what counts as remediation
+type DriftAlertRecord struct {
+	AlertID        uuid.UUID  `json:"alertId"`
+	AgentID        uuid.UUID  `json:"agentId"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	AcknowledgedAt *time.Time `json:"acknowledgedAt,omitempty"`
+}
+
+// DriftTrendPoint summarizes drift in one bucket of a trend
+type DriftTrendPoint struct {
+	PeriodStart         time.Time `json:"periodStart"`
+	DriftEvents         int       `json:"driftEvents"`
+	AgentsAffected      int       `json:"agentsAffected"`
+	UnauthorizedServers int       `json:"unauthorizedServers"` // Distinct unregistered MCP servers seen
+	AlertsRaised        int       `json:"alertsRaised"`
+	AlertsRemediated    int       `json:"alertsRemediated"` // Alerts acknowledged within this bucket
+}
+
+// DriftRemediationStats describes how long drift alerts stayed open
+type DriftRemediationStats struct {
+	AlertsRaised    int      `json:"alertsRaised"`
+	Remediated      int      `json:"remediated"`
+	Open            int      `json:"open"`
+	MeanHours       *float64 `json:"meanHours"` // nil when nothing was remediated
+	MedianHours     *float64 `json:"medianHours"`
+	P90Hours        *float64 `json:"p90Hours"`
+	OldestOpenHours *float64 `json:"oldestOpenHours"` // Age of the oldest unacknowledged alert
+	RemediationRate float64  `json:"remediationRate"` // Remediated / raised, 0-1
+}
+
+// DriftServerStat counts how often an unregistered MCP server showed up in drift
+type DriftServerStat struct {
+	Server      string    `json:"server"`
+	Occurrences int       `json:"occurrences"`
+	Agents      int       `json:"agents"`
+	LastSeen    time.Time `json:"lastSeen"`
+}
+
+// DriftAgentStat summarizes one agent's drift over the report period
+type DriftAgentStat struct {
+	AgentID             uuid.UUID `json:"agentId"`
+	AgentName           string    `json:"agentName"`
+	DriftEvents         int       `json:"driftEvents"`
+	ActivePeriods       int       `json:"activePeriods"` // Trend buckets with at least one drift event
+	UnauthorizedServers []string  `json:"unauthorizedServers"`
+	OpenAlerts          int       `json:"openAlerts"`
+	FirstDetected       time.Time `json:"firstDetected"`
+	LastDetected        time.Time `json:"lastDetected"`
+}
+
+// DriftTrendReport answers drift trend questions for an agent, a fleet or an organization.
+// StabilityScore (0-100, higher is better) condenses the report into a single number that
+// dashboards and the security score can consume.
+type DriftTrendReport struct {
+	Scope                  DriftTrendScope       `json:"scope"`
+	AgentID                *uuid.UUID            `json:"agentId,omitempty"`
+	TagID                  *uuid.UUID            `json:"tagId,omitempty"`
+	Interval               DriftTrendInterval    `json:"interval"`
+	PeriodStart            time.Time             `json:"periodStart"`
+	PeriodEnd              time.Time             `json:"periodEnd"`
+	TotalDriftEvents       int                   `json:"totalDriftEvents"`
+	AgentsAffected         int                   `json:"agentsAffected"`
+	StabilityScore         float64               `json:"stabilityScore"`
+	Trend                  []DriftTrendPoint     `json:"trend"`
+	Remediation            DriftRemediationStats `json:"remediation"`
+	TopUnauthorizedServers []DriftServerStat     `json:"topUnauthorizedServers"`
+	RepeatOffenders        []DriftAgentStat      `json:"repeatOffenders"`
+	Agents                 []DriftAgentStat      `json:"agents,omitempty"` // Every drifting agent; fleet scope only
+	// Capabilities in scope that still refer to tools their MCP server dropped, regardless of period
+	DeprecatedCapabilities []*CapabilityDeprecation `json:"deprecatedCapabilities"`
+}
+
+// DriftAnalyticsRepository reads the drift history trend reports are computed from
+type DriftAnalyticsRepository interface {
+	// GetDriftOccurrences returns agent verifications in the filter's period that detected drift, oldest first
+	GetDriftOccurrences(filter *DriftAnalyticsFilter) ([]*DriftOccurrence, error)
+	// GetDriftAlerts returns configuration and capability drift alerts raised in the filter's period, oldest first
+	GetDriftAlerts(filter *DriftAnalyticsFilter) ([]*DriftAlertRecord, error)
+	// GetDeprecatedCapabilities returns the unresolved capability deprecations of the filter's agents, oldest first
+	GetDeprecatedCapabilities(filter *DriftAnalyticsFilter) ([]*CapabilityDeprecation, error)
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrDriftRemediationNotFound is returned when a drift remediation does not exist
+var ErrDriftRemediationNotFound = errors.New("drift remediation not found")
+
+// DriftRemediationStatus represents the decision on a drift remediation
+type DriftRemediationStatus string
+
+const (
+	DriftRemediationStatusPending  DriftRemediationStatus = "pending"
+	DriftRemediationStatusApproved DriftRemediationStatus = "approved" // The MCP servers were added to the agent's talks_to
+	DriftRemediationStatusRejected DriftRemediationStatus = "rejected" // The config_drift enforcement action was applied to the agent
+)
+
+// DriftRemediation is the change set proposed for a configuration drift alert: the MCP servers
+// the agent talked to without registering them. Approving it adds them to the agent's talks_to;
+// rejecting it enforces the organization's config_drift policy against the agent.
+type DriftRemediation struct {
+	ID                uuid.UUID              `json:"id"`
+	OrganizationID    uuid.UUID              `json:"organizationId"`
+	AgentID           uuid.UUID              `json:"agentId"`
+	AlertID           uuid.UUID              `json:"alertId"`    // The configuration drift alert the change set was proposed for
+	MCPServers        []string               `json:"mcpServers"` // Undeclared MCP servers the agent talked to
+	Status            DriftRemediationStatus `json:"status"`
+	EnforcementAction EnforcementAction      `json:"enforcementAction,omitempty"` // Set on rejection to the action applied
+	PolicyName        string                 `json:"policyName,omitempty"`        // Config drift policy that chose the action
+	Reason            string                 `json:"reason,omitempty"`
+	DecidedBy         *uuid.UUID             `json:"decidedBy,omitempty"`
+	DecidedAt         *time.Time             `json:"decidedAt,omitempty"`
+	CreatedAt         time.Time              `json:"createdAt"`
+}
+
+// DriftRemediationRepository defines the interface for drift remediation persistence
+type DriftRemediationRepository interface {
+	Create(remediation *DriftRemediation) error
+	// GetByID and GetByAlertID return ErrDriftRemediationNotFound when no remediation exists
+	GetByID(id uuid.UUID) (*DriftRemediation, error)
+	GetByAlertID(alertID uuid.UUID) (*DriftRemediation, error)
+	// ListByOrganization returns the organization's remediations, newest first; an empty status lists all
+	ListByOrganization(orgID uuid.UUID, status DriftRemediationStatus, limit, offset int) ([]*DriftRemediation, error)
+	// Decide stores the decision of a pending remediation and reports whether it was still pending
+	Decide(remediation *DriftRemediation) (bool, error)
+}
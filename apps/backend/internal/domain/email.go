@@ -26,6 +26,7 @@ const (
 	TemplateUserApproved  EmailTemplate = "user_approved"
 	TemplateUserRejected  EmailTemplate = "user_rejected"
 	TemplatePasswordReset EmailTemplate = "password_reset"
+	TemplateInvitation    EmailTemplate = "invitation"
 
 	// Agent-related templates
 	TemplateAgentRegistered     EmailTemplate = "agent_registered"
@@ -37,6 +38,11 @@ const (
 	TemplateAlertCritical EmailTemplate = "alert_critical"
 	TemplateAlertWarning  EmailTemplate = "alert_warning"
 	TemplateAlertInfo     EmailTemplate = "alert_info"
+	TemplateAlertDigest   EmailTemplate = "alert_digest"
+
+	// Capability request templates
+	TemplateCapabilityRequestApproved EmailTemplate = "capability_request_approved"
+	TemplateCapabilityRequestRejected EmailTemplate = "capability_request_rejected"
 
 	// MCP Server templates
 	TemplateMCPServerRegistered EmailTemplate = "mcp_server_registered"
@@ -89,7 +95,7 @@ type EmailTemplateData struct {
 
 // EmailConfig holds email service configuration
 type EmailConfig struct {
-	// Provider: "azure" or "smtp"
+	// Provider: "azure", "smtp", "ses", "sendgrid" or "console"
 	Provider string
 
 	// Common configuration
@@ -102,6 +108,12 @@ type EmailConfig struct {
 	// SMTP configuration
 	SMTP SMTPConfig
 
+	// Amazon SES configuration
+	SES SESConfig
+
+	// SendGrid configuration
+	SendGrid SendGridConfig
+
 	// Template directory (optional)
 	TemplateDir string
 
@@ -133,6 +145,18 @@ type SMTPConfig struct {
 	IdleTimeout    time.Duration
 }
 
+// SESConfig holds Amazon SES configuration
+type SESConfig struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// SendGridConfig holds SendGrid configuration
+type SendGridConfig struct {
+	APIKey string
+}
+
 // EmailMetrics tracks email sending metrics
 type EmailMetrics struct {
 	TotalSent       int64
@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrEmailSenderNotFound is returned when an organization has no sender configuration of its own
+var ErrEmailSenderNotFound = errors.New("email sender configuration not found")
+
+// ErrEmailSuppressionNotFound is returned when an address is not on the suppression list
+var ErrEmailSuppressionNotFound = errors.New("email suppression not found")
+
+// OrganizationEmailSender is the address an organization's transactional mail is sent from,
+// instead of the platform's EMAIL_FROM_ADDRESS. The provider has to accept the address, e.g. a
+// verified SES identity or SendGrid sender.
+type OrganizationEmailSender struct {
+	OrganizationID uuid.UUID `json:"organizationId"`
+	FromAddress    string    `json:"fromAddress"`
+	FromName       string    `json:"fromName"`
+	ReplyTo        string    `json:"replyTo,omitempty"`
+	UpdatedBy      uuid.UUID `json:"updatedBy"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// OrganizationEmailSenderRepository stores organizations' sender configurations
+type OrganizationEmailSenderRepository interface {
+	// Get returns the organization's sender; ErrEmailSenderNotFound when it uses the platform's
+	Get(orgID uuid.UUID) (*OrganizationEmailSender, error)
+	Upsert(sender *OrganizationEmailSender) error
+	// Delete removes the organization's sender; ErrEmailSenderNotFound when it has none
+	Delete(orgID uuid.UUID) error
+}
+
+// OrganizationEmailService is implemented by email services that can send on behalf of an
+// organization, from the organization's sender
+type OrganizationEmailService interface {
+	ForOrganization(orgID uuid.UUID) EmailService
+}
+
+// SenderEmailService is implemented by email services that can send from a sender other than
+// the configured one. The email queue sends organizations' mail through it.
+type SenderEmailService interface {
+	SendEmailAs(sender *OrganizationEmailSender, to, subject, body string, isHTML bool) error
+}
+
+// EmailTemplateRenderer renders an email template into its subject and HTML body
+type EmailTemplateRenderer interface {
+	Render(template EmailTemplate, data interface{}) (subject, body string, err error)
+}
+
+// EmailMessageStatus is where a message is in the send queue
+type EmailMessageStatus string
+
+const (
+	EmailMessageQueued     EmailMessageStatus = "queued"     // Waiting to be sent, or to be retried
+	EmailMessageSending    EmailMessageStatus = "sending"    // Claimed by the queue worker
+	EmailMessageSent       EmailMessageStatus = "sent"       // Accepted by the provider
+	EmailMessageFailed     EmailMessageStatus = "failed"     // Given up on after the last attempt
+	EmailMessageBounced    EmailMessageStatus = "bounced"    // Sent, then reported bounced by the provider
+	EmailMessageSuppressed EmailMessageStatus = "suppressed" // Not sent: the recipient bounced or complained before
+)
+
+// EmailMessage is a transactional email in the send queue. The sender and the rendered subject
+// and body are fixed when it is queued, so retries send the same message.
+type EmailMessage struct {
+	ID             uuid.UUID          `json:"id"`
+	OrganizationID *uuid.UUID         `json:"organizationId,omitempty"` // Nil for platform mail sent from the default sender
+	To             string             `json:"to"`
+	FromAddress    string             `json:"fromAddress,omitempty"` // Empty sends from the provider's default sender
+	FromName       string             `json:"fromName,omitempty"`
+	ReplyTo        string             `json:"replyTo,omitempty"`
+	Subject        string             `json:"subject"`
+	Body           string             `json:"-"`
+	IsHTML         bool               `json:"isHtml"`
+	Template       EmailTemplate      `json:"template,omitempty"`
+	Status         EmailMessageStatus `json:"status"`
+	Attempts       int                `json:"attempts"`
+	NextAttemptAt  time.Time          `json:"nextAttemptAt"`
+	LastError      string             `json:"lastError,omitempty"`
+	SentAt         *time.Time         `json:"sentAt,omitempty"`
+	BouncedAt      *time.Time         `json:"bouncedAt,omitempty"`
+	BounceReason   string             `json:"bounceReason,omitempty"`
+	CreatedAt      time.Time          `json:"createdAt"`
+}
+
+// Sender returns the organization sender the message is sent from; nil for the default sender
+func (m *EmailMessage) Sender() *OrganizationEmailSender {
+	if m.FromAddress == "" {
+		return nil
+	}
+	sender := &OrganizationEmailSender{FromAddress: m.FromAddress, FromName: m.FromName, ReplyTo: m.ReplyTo}
+	if m.OrganizationID != nil {
+		sender.OrganizationID = *m.OrganizationID
+	}
+	return sender
+}
+
+// EmailMessageFilter narrows the messages listed
+type EmailMessageFilter struct {
+	OrganizationID uuid.UUID
+	Status         EmailMessageStatus // Empty lists all
+	Limit          int
+	Offset         int
+}
+
+// EmailQueueRepository stores the email send queue
+type EmailQueueRepository interface {
+	Enqueue(message *EmailMessage) error
+	// ClaimDue claims up to limit queued messages due for (another) attempt, oldest first. Claimed
+	// messages are held for the lease; messages held by a worker that died are claimed again after it.
+	ClaimDue(limit int, lease time.Duration) ([]*EmailMessage, error)
+	MarkSent(id uuid.UUID, sentAt time.Time) error
+	// MarkRetry returns the message to the queue, to be retried at nextAttemptAt
+	MarkRetry(id uuid.UUID, lastError string, nextAttemptAt time.Time) error
+	// MarkFinal records an attempt that ended the message unsent: failed or suppressed
+	MarkFinal(id uuid.UUID, status EmailMessageStatus, lastError string) error
+	// MarkBounced marks the latest message sent to the address (case-insensitively) as bounced
+	// and reports whether there was one
+	MarkBounced(address, reason string, bouncedAt time.Time) (bool, error)
+	// List returns the organization's messages, newest first, and how many match the filter
+	List(filter EmailMessageFilter) ([]*EmailMessage, int, error)
+	// DeleteFinishedBefore removes sent, failed, bounced and suppressed messages created before
+	// the given time and returns how many
+	DeleteFinishedBefore(before time.Time) (int, error)
+}
+
+// EmailBounceType is why a provider reported an address undeliverable
+type EmailBounceType string
+
+const (
+	EmailBounceHard      EmailBounceType = "hard"      // Permanent: the mailbox doesn't exist or rejects mail
+	EmailBounceSoft      EmailBounceType = "soft"      // Transient: mailbox full, greylisting; not suppressed
+	EmailBounceComplaint EmailBounceType = "complaint" // The recipient marked a message as spam
+)
+
+// EmailBounce is a bounce or complaint reported by the provider for an address
+type EmailBounce struct {
+	Address string          `json:"address"`
+	Type    EmailBounceType `json:"type"`
+	Reason  string          `json:"reason,omitempty"`
+}
+
+// EmailSuppression is an address no more mail is sent to after it hard bounced or complained,
+// protecting the sender's reputation with the provider
+type EmailSuppression struct {
+	Address   string          `json:"address"` // Lowercased
+	Type      EmailBounceType `json:"type"`
+	Reason    string          `json:"reason,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// EmailSuppressionRepository stores the suppression list
+type EmailSuppressionRepository interface {
+	// Add puts the address on the list, replacing the type and reason of an existing entry
+	Add(suppression *EmailSuppression) error
+	IsSuppressed(address string) (bool, error)
+	// List returns the suppressed addresses, newest first, and how many there are
+	List(limit, offset int) ([]*EmailSuppression, int, error)
+	// Remove takes the address off the list; ErrEmailSuppressionNotFound when it isn't on it
+	Remove(address string) error
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmergencyCredentialStatus represents the lifecycle state of an emergency credential
+type EmergencyCredentialStatus string
+
+const (
+	EmergencyCredentialStatusActive    EmergencyCredentialStatus = "active"    // Sealed and waiting for a break-glass
+	EmergencyCredentialStatusActivated EmergencyCredentialStatus = "activated" // Used once; can never be used again
+	EmergencyCredentialStatusRevoked   EmergencyCredentialStatus = "revoked"   // Revoked by an admin or rotated out
+	EmergencyCredentialStatusExpired   EmergencyCredentialStatus = "expired"   // Derived on read once ExpiresAt has passed
+)
+
+// EmergencyCredential is a pre-generated break-glass credential for a critical agent.
+// The secret is sealed to an organization-held X25519 public key when it is generated
+// and only its hash is stored, so the server alone can never activate it.
+type EmergencyCredential struct {
+	ID               uuid.UUID                 `json:"id"`
+	OrganizationID   uuid.UUID                 `json:"organizationId"`
+	AgentID          uuid.UUID                 `json:"agentId"`
+	UserID           uuid.UUID                 `json:"userId"` // Emergency access tokens are issued to this user
+	SecretHash       string                    `json:"-"`      // Never expose in JSON
+	SealingPublicKey string                    `json:"sealingPublicKey"`
+	KeyFingerprint   string                    `json:"keyFingerprint"`
+	Status           EmergencyCredentialStatus `json:"status"`
+	ExpiresAt        time.Time                 `json:"expiresAt"`
+	ActivatedAt      *time.Time                `json:"activatedAt,omitempty"`
+	ActivatedIP      *string                   `json:"activatedIp,omitempty"`
+	ActivationReason *string                   `json:"activationReason,omitempty"`
+	AccessExpiresAt  *time.Time                `json:"accessExpiresAt,omitempty"`
+	RevokedAt        *time.Time                `json:"revokedAt,omitempty"`
+	RevokeReason     *string                   `json:"revokeReason,omitempty"`
+	RotatedFrom      *uuid.UUID                `json:"rotatedFrom,omitempty"` // Credential this one replaced
+	CreatedBy        uuid.UUID                 `json:"createdBy"`
+	CreatedAt        time.Time                 `json:"createdAt"`
+}
+
+// EffectiveStatus reports expired for active credentials past their expiry
+func (c *EmergencyCredential) EffectiveStatus(at time.Time) EmergencyCredentialStatus {
+	if c.Status == EmergencyCredentialStatusActive && !at.Before(c.ExpiresAt) {
+		return EmergencyCredentialStatusExpired
+	}
+	return c.Status
+}
+
+// EmergencyCredentialRepository defines the interface for emergency credential persistence
+type EmergencyCredentialRepository interface {
+	Create(credential *EmergencyCredential) error
+	GetByID(id uuid.UUID) (*EmergencyCredential, error)
+	GetBySecretHash(secretHash string) (*EmergencyCredential, error)
+	GetByAgent(agentID uuid.UUID) ([]*EmergencyCredential, error)
+
+	// Activate atomically moves an active, unexpired credential to activated.
+	// It returns false when the credential was already used, revoked or expired.
+	Activate(id uuid.UUID, at, accessExpiresAt time.Time, ipAddress, reason string) (bool, error)
+
+	// Revoke revokes an active credential
+	Revoke(id uuid.UUID, reason string) error
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "net/http"
+
+// Endpoint classes API requests are grouped into for rate limits and timeout budgets
+const (
+	EndpointClassRead         = "read"         // GET requests
+	EndpointClassWrite        = "write"        // Requests that change state
+	EndpointClassVerification = "verification" // Action verifications of agents
+)
+
+// EndpointClasses lists the endpoint classes
+var EndpointClasses = []string{EndpointClassRead, EndpointClassWrite, EndpointClassVerification}
+
+// IsEndpointClass reports whether class is a known endpoint class
+func IsEndpointClass(class string) bool {
+	for _, c := range EndpointClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// EndpointClassOf returns the class of a request made with the HTTP method, for endpoints not
+// assigned a class: GET and HEAD requests are reads, other methods writes
+func EndpointClassOf(method string) string {
+	if method == http.MethodGet || method == http.MethodHead {
+		return EndpointClassRead
+	}
+	return EndpointClassWrite
+}
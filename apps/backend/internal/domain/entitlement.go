@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EntitlementResourceType identifies what an entitlement review covers
+type EntitlementResourceType string
+
+const (
+	EntitlementResourceMCPServer  EntitlementResourceType = "mcp_server"
+	EntitlementResourceCapability EntitlementResourceType = "capability"
+)
+
+// EntitlementGrantType records how an agent obtained access
+type EntitlementGrantType string
+
+const (
+	EntitlementGrantRegistration      EntitlementGrantType = "registration"       // Declared when the agent was registered or updated by its owner
+	EntitlementGrantCapabilityRequest EntitlementGrantType = "capability_request" // Approved capability request
+	EntitlementGrantManual            EntitlementGrantType = "manual_grant"       // Granted directly by an administrator
+	EntitlementGrantAutoDetected      EntitlementGrantType = "auto_detected"      // Detected from SDK usage or agent configuration
+	EntitlementGrantAttestation       EntitlementGrantType = "attestation"        // Agent cryptographically attested the MCP server
+)
+
+// EntitlementGrant is one path through which an agent holds access
+type EntitlementGrant struct {
+	GrantType     EntitlementGrantType `json:"grantType"`
+	GrantedAt     time.Time            `json:"grantedAt"`
+	GrantedBy     *uuid.UUID           `json:"grantedBy,omitempty"`
+	GrantedByName string               `json:"grantedByName,omitempty"`
+	ReferenceID   *uuid.UUID           `json:"referenceId,omitempty"` // Capability request or MCP connection behind the grant
+}
+
+// Entitlement is an agent currently authorized for the reviewed resource, with its owner
+type Entitlement struct {
+	AgentID          uuid.UUID          `json:"agentId"`
+	AgentName        string             `json:"agentName"`
+	AgentDisplayName string             `json:"agentDisplayName"`
+	AgentStatus      AgentStatus        `json:"agentStatus"`
+	TrustScore       float64            `json:"trustScore"`
+	OwnerID          *uuid.UUID         `json:"ownerId,omitempty"`
+	OwnerName        string             `json:"ownerName,omitempty"`
+	OwnerEmail       string             `json:"ownerEmail,omitempty"`
+	Grants           []EntitlementGrant `json:"grants"`
+	LastUsedAt       *time.Time         `json:"lastUsedAt"` // nil when never used
+}
+
+// EntitlementReview answers "who can access what" for one MCP server or capability
+type EntitlementReview struct {
+	OrganizationID uuid.UUID               `json:"organizationId"`
+	ResourceType   EntitlementResourceType `json:"resourceType"`
+	ResourceID     *uuid.UUID              `json:"resourceId,omitempty"`
+	ResourceName   string                  `json:"resourceName"`
+	Entitlements   []*Entitlement          `json:"entitlements"`
+	Total          int                     `json:"total"`
+	GeneratedAt    time.Time               `json:"generatedAt"`
+}
+
+// EntitlementRepository defines the interface for entitlement review queries.
+// Both queries return one entry per grant (a single grant in Grants); callers merge by agent.
+type EntitlementRepository interface {
+	GetMCPServerEntitlements(orgID uuid.UUID, mcpServer *MCPServer) ([]*Entitlement, error)
+	GetCapabilityEntitlements(orgID uuid.UUID, capabilityType string) ([]*Entitlement, error)
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrFeatureFlagNotFound is returned when no feature flag has the key
+var ErrFeatureFlagNotFound = errors.New("feature flag not found")
+
+// Where a feature's state for a user comes from
+const (
+	FeatureSourceDefault      = "default"
+	FeatureSourceOrganization = "organization"
+	FeatureSourceUser         = "user"
+	FeatureSourceDeployment   = "deployment" // Forced by a platform operator for every organization
+)
+
+// FeatureFlag gates a preview feature. Flags are added by migrations as previews ship;
+// organizations and, where the flag allows it, users then turn them on or off.
+type FeatureFlag struct {
+	Key            string    `json:"key"`
+	Description    string    `json:"description"`
+	DefaultEnabled bool      `json:"defaultEnabled"` // For organizations that have not chosen
+	UserOptIn      bool      `json:"userOptIn"`      // Users may turn the preview on or off for themselves
+	SDKVisible     bool      `json:"sdkVisible"`     // A protocol-level preview that SDKs are told about
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// FeatureFlagOverride is an organization's choice for a feature flag, or one of its users' when
+// UserID is set
+type FeatureFlagOverride struct {
+	FlagKey        string     `json:"flagKey"`
+	OrganizationID uuid.UUID  `json:"organizationId"`
+	UserID         *uuid.UUID `json:"userId,omitempty"`
+	Enabled        bool       `json:"enabled"`
+	UpdatedBy      *uuid.UUID `json:"updatedBy,omitempty"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// FeatureState is whether a feature flag is on for an organization or user, and why
+type FeatureState struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Source      string `json:"source"` // default, organization, user or deployment
+	UserOptIn   bool   `json:"userOptIn"`
+	SDKVisible  bool   `json:"sdkVisible"`
+}
+
+// FeatureFlagRepository stores feature flags and the overrides of organizations and users
+type FeatureFlagRepository interface {
+	ListFlags() ([]*FeatureFlag, error)
+	// ListOverrides returns the overrides of the organization and of its users
+	ListOverrides(orgID uuid.UUID) ([]*FeatureFlagOverride, error)
+	// SetOverride creates or replaces the override of the organization or user
+	SetOverride(override *FeatureFlagOverride) error
+	// DeleteOverride removes the organization's override, or the user's when userID is set
+	DeleteOverride(flagKey string, orgID uuid.UUID, userID *uuid.UUID) error
+}
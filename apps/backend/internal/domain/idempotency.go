@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyHeader is the request header clients send to make a mutating request safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyLockTimeout is how long a key stays reserved for a request that has not completed.
+// A retry after it runs the request again, so an instance dying mid-request does not block the
+// key for the whole replay window.
+const IdempotencyLockTimeout = 2 * time.Minute
+
+// ErrIdempotencyRecordNotFound is returned when no request was made with the key
+var ErrIdempotencyRecordNotFound = errors.New("idempotency record not found")
+
+// IdempotencyRecord is a request made with an idempotency key and, once it completed, its
+// response. Keys are scoped to the organization and endpoint (method and path).
+type IdempotencyRecord struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	Key            string    `json:"key"`
+	Endpoint       string    `json:"endpoint"`    // Method and request path, e.g. "POST /api/v1/agents/<id>/capability-requests"
+	RequestHash    string    `json:"requestHash"` // SHA-256 of the request body; a replay must match it
+	Completed      bool      `json:"completed"`
+	StatusCode     int       `json:"statusCode"`
+	ContentType    string    `json:"contentType"`
+	ResponseBody   []byte    `json:"-"`
+	CreatedAt      time.Time `json:"createdAt"`
+	ExpiresAt      time.Time `json:"expiresAt"` // The lock timeout while in progress, the replay window once completed
+}
+
+// IdempotencyRepository stores the requests made with idempotency keys
+type IdempotencyRepository interface {
+	// Reserve stores the record unless a record for the same organization, key and endpoint
+	// exists and has not expired; it reports whether the record was stored. An expired record
+	// is replaced.
+	Reserve(record *IdempotencyRecord) (bool, error)
+	// Get returns ErrIdempotencyRecordNotFound when no record exists
+	Get(orgID uuid.UUID, key, endpoint string) (*IdempotencyRecord, error)
+	// Complete stores the response and expiry of a reserved record
+	Complete(record *IdempotencyRecord) error
+	Delete(id uuid.UUID) error
+	// DeleteExpired removes the records that expired before the given time and returns how many
+	DeleteExpired(before time.Time) (int, error)
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdentityBundleFormat identifies version 1 of the agent identity bundle
+const IdentityBundleFormat = "aim-identity-bundle/v1"
+
+var (
+	// ErrIdentityBundleInvalid is returned for bundles that are malformed, expired or whose
+	// signature does not verify
+	ErrIdentityBundleInvalid = errors.New("invalid identity bundle")
+	// ErrIdentityBundleUntrusted is returned for bundles signed by a deployment whose public key
+	// is not trusted
+	ErrIdentityBundleUntrusted = errors.New("identity bundle issuer is not trusted")
+)
+
+// SignedIdentityBundle is an IdentityBundle as exported: the bundle's JSON exactly as signed, and
+// the issuing deployment's Ed25519 signature of it
+type SignedIdentityBundle struct {
+	Bundle    json.RawMessage `json:"bundle"`
+	Signature string          `json:"signature"` // Base64
+}
+
+// IdentityBundle carries agents from one deployment to another (self-hosted, cloud or another
+// region) with what they need to keep their verification lineage: their public keys, a summary of
+// their trust history, their own signed attestations and the deployments they came through.
+// Escrowed private keys never leave the deployment.
+type IdentityBundle struct {
+	Format               string               `json:"format"`
+	Issuer               IdentityBundleIssuer `json:"issuer"`
+	SourceOrganizationID uuid.UUID            `json:"sourceOrganizationId"`
+	ExportedAt           time.Time            `json:"exportedAt"`
+	Agents               []PortableAgent      `json:"agents"`
+}
+
+// IdentityBundleIssuer is the deployment that exported and signed a bundle
+type IdentityBundleIssuer struct {
+	Name      string `json:"name"`      // The deployment's URL
+	PublicKey string `json:"publicKey"` // Base64 Ed25519 key the bundle is signed with
+}
+
+// PortableAgent is an agent's identity in an identity bundle
+type PortableAgent struct {
+	SourceID          uuid.UUID   `json:"sourceId"` // The agent's ID at the issuer, which its attestations name
+	Name              string      `json:"name"`
+	DisplayName       string      `json:"displayName"`
+	Description       string      `json:"description"`
+	AgentType         AgentType   `json:"agentType"`
+	Status            AgentStatus `json:"status"`
+	Version           string      `json:"version"`
+	PublicKey         string      `json:"publicKey,omitempty"`
+	PreviousPublicKey string      `json:"previousPublicKey,omitempty"` // Replaced by the last rotation; older attestations are signed with it
+	KeyAlgorithm      string      `json:"keyAlgorithm"`
+	RotationCount     int         `json:"rotationCount"`
+	RepositoryURL     string      `json:"repositoryUrl,omitempty"`
+	DocumentationURL  string      `json:"documentationUrl,omitempty"`
+	TalksTo           []string    `json:"talksTo"`
+	Capabilities      []string    `json:"capabilities"`
+	IsCompromised     bool        `json:"isCompromised"`
+	RegisteredAt      time.Time   `json:"registeredAt"`
+	VerifiedAt        *time.Time  `json:"verifiedAt,omitempty"`
+
+	TrustHistory PortableTrustHistory  `json:"trustHistory"`
+	Attestations []PortableAttestation `json:"attestations"`
+	// Lineage lists the deployments the agent was imported from before the issuer, oldest first
+	Lineage []AgentLineageHop `json:"lineage"`
+}
+
+// PortableTrustHistory summarizes an agent's trust score history
+type PortableTrustHistory struct {
+	CurrentScore    float64            `json:"currentScore"`
+	Confidence      float64            `json:"confidence"`
+	Factors         *TrustScoreFactors `json:"factors,omitempty"` // Of the latest calculation
+	Samples         int                `json:"samples"`
+	MinScore        float64            `json:"minScore"`
+	MaxScore        float64            `json:"maxScore"`
+	AverageScore    float64            `json:"averageScore"`
+	FirstRecordedAt *time.Time         `json:"firstRecordedAt,omitempty"`
+	LastRecordedAt  *time.Time         `json:"lastRecordedAt,omitempty"`
+}
+
+// PortableAttestation is an attestation the agent signed, identified by the MCP server's URL
+// since server IDs differ between deployments. Its signature still verifies with the agent's key.
+type PortableAttestation struct {
+	MCPServerURL string             `json:"mcpServerUrl"`
+	Payload      AttestationPayload `json:"payload"`
+	Signature    string             `json:"signature"`
+	VerifiedAt   *time.Time         `json:"verifiedAt,omitempty"`
+	ExpiresAt    time.Time          `json:"expiresAt"`
+	IsValid      bool               `json:"isValid"`
+	CreatedAt    time.Time          `json:"createdAt"`
+}
+
+// AgentLineageHop is one move of an agent between deployments
+type AgentLineageHop struct {
+	IssuerName           string    `json:"issuerName"`
+	IssuerKeyFingerprint string    `json:"issuerKeyFingerprint"` // Hex SHA-256 of the issuer's base64 public key
+	SourceAgentID        uuid.UUID `json:"sourceAgentId"`
+	SourceOrganizationID uuid.UUID `json:"sourceOrganizationId"`
+	RegisteredAt         time.Time `json:"registeredAt"` // When the agent was registered at the issuer
+	ExportedAt           time.Time `json:"exportedAt"`
+	ImportedAt           time.Time `json:"importedAt"`
+}
+
+// AgentLineageEntry records a deployment an agent was imported from
+type AgentLineageEntry struct {
+	ID             uuid.UUID `json:"id"`
+	AgentID        uuid.UUID `json:"agentId"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	AgentLineageHop
+}
+
+// AgentLineageRepository stores where imported agents came from
+type AgentLineageRepository interface {
+	Create(entry *AgentLineageEntry) error
+	// ListByAgent returns the agent's lineage, oldest hop first
+	ListByAgent(agentID uuid.UUID) ([]*AgentLineageEntry, error)
+}
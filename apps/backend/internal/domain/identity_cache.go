@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCacheMiss is returned by an IdentityCache holding no live entry for a key
+var ErrCacheMiss = errors.New("cache miss")
+
+// IdentityCache keeps copies of the identity records looked up on every verification (agents by
+// ID, API keys by hash, MCP servers by ID), so repeated lookups skip the database. Entries are
+// copies: changing a record read from the cache does not change the cached entry.
+type IdentityCache interface {
+	// Get reads the entry under key into dest, or returns ErrCacheMiss
+	Get(ctx context.Context, key string, dest interface{}) error
+	// Set stores value under key until ttl has passed
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	// Delete drops the entries under keys; missing keys are ignored
+	Delete(ctx context.Context, keys ...string) error
+}
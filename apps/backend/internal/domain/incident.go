@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrIncidentLinkExists is returned when the resource is already linked to the incident
+	ErrIncidentLinkExists = errors.New("resource is already linked to the incident")
+	// ErrIncidentLinkNotFound is returned when the resource is not linked to the incident
+	ErrIncidentLinkNotFound = errors.New("resource is not linked to the incident")
+)
+
+// IsClosed reports whether an incident in this status is finished
+func (s IncidentStatus) IsClosed() bool {
+	return s == IncidentStatusResolved || s == IncidentStatusFalsePositive
+}
+
+// IsValid reports whether the status is one of the known incident statuses
+func (s IncidentStatus) IsValid() bool {
+	switch s {
+	case IncidentStatusOpen, IncidentStatusInvestigating, IncidentStatusResolved, IncidentStatusFalsePositive:
+		return true
+	}
+	return false
+}
+
+// IncidentSLAPolicy is how quickly incidents of a severity must be acknowledged and resolved
+type IncidentSLAPolicy struct {
+	RespondWithin time.Duration
+	ResolveWithin time.Duration
+}
+
+// IncidentSLAPolicies are the response and resolution objectives by incident severity
+var IncidentSLAPolicies = map[AlertSeverity]IncidentSLAPolicy{
+	AlertSeverityCritical: {RespondWithin: 15 * time.Minute, ResolveWithin: 4 * time.Hour},
+	AlertSeverityHigh:     {RespondWithin: time.Hour, ResolveWithin: 24 * time.Hour},
+	AlertSeverityWarning:  {RespondWithin: 4 * time.Hour, ResolveWithin: 3 * 24 * time.Hour},
+	AlertSeverityInfo:     {RespondWithin: 24 * time.Hour, ResolveWithin: 7 * 24 * time.Hour},
+}
+
+// IncidentSLAKind names the timer of an incident SLA
+type IncidentSLAKind string
+
+const (
+	IncidentSLAResponse   IncidentSLAKind = "response"   // Until the incident leaves open
+	IncidentSLAResolution IncidentSLAKind = "resolution" // Until the incident is resolved or dismissed
+)
+
+// IncidentSLATimer is the state of one SLA timer of an incident
+type IncidentSLATimer struct {
+	DueAt       time.Time  `json:"dueAt"`
+	MetAt       *time.Time `json:"metAt,omitempty"` // When the incident was acknowledged or closed
+	Breached    bool       `json:"breached"`        // Met late, or still running past its due time
+	RemainingMs int64      `json:"remainingMs"`     // Until due while running; negative once overdue
+}
+
+// IncidentSLA is the state of an incident's response and resolution timers
+type IncidentSLA struct {
+	Response   IncidentSLATimer `json:"response"`
+	Resolution IncidentSLATimer `json:"resolution"`
+}
+
+// IncidentComment is a note left on an incident by a responder
+type IncidentComment struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	IncidentID     uuid.UUID `json:"incidentId"`
+	AuthorID       uuid.UUID `json:"authorId"`
+	Body           string    `json:"body"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// IncidentLinkType is the kind of resource linked to an incident
+type IncidentLinkType string
+
+const (
+	IncidentLinkThreat            IncidentLinkType = "threat"
+	IncidentLinkAnomaly           IncidentLinkType = "anomaly"
+	IncidentLinkVerificationEvent IncidentLinkType = "verification_event"
+)
+
+// IsValid reports whether resources of this type can be linked to incidents
+func (t IncidentLinkType) IsValid() bool {
+	return t == IncidentLinkThreat || t == IncidentLinkAnomaly || t == IncidentLinkVerificationEvent
+}
+
+// IncidentLink relates a threat, anomaly or verification event to an incident
+type IncidentLink struct {
+	IncidentID     uuid.UUID        `json:"incidentId"`
+	OrganizationID uuid.UUID        `json:"organizationId"`
+	ResourceType   IncidentLinkType `json:"resourceType"`
+	ResourceID     uuid.UUID        `json:"resourceId"`
+	Summary        string           `json:"summary"` // Title of the threat or anomaly, or the event's action, when linked
+	LinkedBy       *uuid.UUID       `json:"linkedBy,omitempty"`
+	CreatedAt      time.Time        `json:"createdAt"`
+}
+
+// IncidentTimelineEventType is what happened to an incident
+type IncidentTimelineEventType string
+
+const (
+	IncidentTimelineStatusChanged IncidentTimelineEventType = "status_changed"
+	IncidentTimelineAssigned      IncidentTimelineEventType = "assigned"
+	IncidentTimelineUnassigned    IncidentTimelineEventType = "unassigned"
+	IncidentTimelineCommented     IncidentTimelineEventType = "commented"
+	IncidentTimelineLinked        IncidentTimelineEventType = "linked"
+	IncidentTimelineUnlinked      IncidentTimelineEventType = "unlinked"
+	IncidentTimelineSLABreached   IncidentTimelineEventType = "sla_breached"
+)
+
+// IncidentTimelineEvent is an entry of an incident's timeline, appended as the incident is worked
+type IncidentTimelineEvent struct {
+	ID             uuid.UUID                 `json:"id"`
+	OrganizationID uuid.UUID                 `json:"organizationId"`
+	IncidentID     uuid.UUID                 `json:"incidentId"`
+	EventType      IncidentTimelineEventType `json:"eventType"`
+	ActorID        *uuid.UUID                `json:"actorId,omitempty"` // Empty for events the system recorded
+	Summary        string                    `json:"summary"`
+	Details        map[string]interface{}    `json:"details,omitempty"`
+	CreatedAt      time.Time                 `json:"createdAt"`
+}
+
+// IncidentRepository stores the comments, links and timeline of security incidents
+type IncidentRepository interface {
+	CreateComment(comment *IncidentComment) error
+	// ListComments returns the incident's comments, oldest first
+	ListComments(incidentID uuid.UUID) ([]*IncidentComment, error)
+
+	// CreateLink returns ErrIncidentLinkExists when the resource is already linked
+	CreateLink(link *IncidentLink) error
+	// DeleteLink returns ErrIncidentLinkNotFound when the resource is not linked
+	DeleteLink(incidentID uuid.UUID, resourceType IncidentLinkType, resourceID uuid.UUID) error
+	// ListLinks returns the incident's links, oldest first
+	ListLinks(incidentID uuid.UUID) ([]*IncidentLink, error)
+
+	AppendTimeline(event *IncidentTimelineEvent) error
+	// ListTimeline returns the incident's timeline, oldest first
+	ListTimeline(incidentID uuid.UUID) ([]*IncidentTimelineEvent, error)
+}
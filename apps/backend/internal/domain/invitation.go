@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvitationNotFound is returned when an invitation does not exist
+	ErrInvitationNotFound = errors.New("invitation not found")
+)
+
+const (
+	// DefaultInvitationTTL is how long an invitation link works unless the admin chose otherwise
+	DefaultInvitationTTL = 7 * 24 * time.Hour
+	// MaxInvitationTTL is the longest an invitation link may work
+	MaxInvitationTTL = 30 * 24 * time.Hour
+)
+
+// OrganizationSettingInviteesRequireApproval is the Organization.Settings key that makes users
+// who accept an invitation wait for an admin's approval before they can sign in
+const OrganizationSettingInviteesRequireApproval = "inviteesRequireApproval"
+
+// InviteesRequireApproval reports whether users joining through an invitation start pending
+// rather than active
+func (o *Organization) InviteesRequireApproval() bool {
+	required, _ := o.Settings[OrganizationSettingInviteesRequireApproval].(bool)
+	return required
+}
+
+// InvitationStatus represents where an invitation is in its lifecycle
+type InvitationStatus string
+
+const (
+	InvitationStatusPending  InvitationStatus = "pending"  // Sent; the link can still be used
+	InvitationStatusAccepted InvitationStatus = "accepted" // The invitee created their account
+	InvitationStatusRevoked  InvitationStatus = "revoked"  // An admin withdrew it
+	InvitationStatusExpired  InvitationStatus = "expired"  // Never accepted; only reported, never stored
+)
+
+// Invitation invites someone to join an organization with a role. The invitee receives a signed
+// link that expires; accepting it creates their account.
+type Invitation struct {
+	ID             uuid.UUID        `json:"id"`
+	OrganizationID uuid.UUID        `json:"organizationId"`
+	Email          string           `json:"email"`
+	Role           UserRole         `json:"role"`
+	InvitedBy      uuid.UUID        `json:"invitedBy"`
+	Status         InvitationStatus `json:"status"`
+	ExpiresAt      time.Time        `json:"expiresAt"`
+	AcceptedBy     *uuid.UUID       `json:"acceptedBy,omitempty"` // The user created on acceptance
+	AcceptedAt     *time.Time       `json:"acceptedAt,omitempty"`
+	RevokedBy      *uuid.UUID       `json:"revokedBy,omitempty"`
+	RevokedAt      *time.Time       `json:"revokedAt,omitempty"`
+	CreatedAt      time.Time        `json:"createdAt"`
+}
+
+// IsOpen reports whether the invitation can still be accepted
+func (i *Invitation) IsOpen(now time.Time) bool {
+	return i.Status == InvitationStatusPending && now.Before(i.ExpiresAt)
+}
+
+// InvitationRepository defines the interface for invitation persistence. Reads report pending
+// invitations past their expiry as expired.
+type InvitationRepository interface {
+	Create(invitation *Invitation) error
+	// GetByID returns ErrInvitationNotFound when no invitation exists
+	GetByID(id uuid.UUID) (*Invitation, error)
+	// ListByOrganization returns the organization's invitations, newest first; an empty status lists all
+	ListByOrganization(orgID uuid.UUID, status InvitationStatus, limit, offset int) ([]*Invitation, error)
+	// ListByEmail returns the organization's invitations of the address (case-insensitive), newest first
+	ListByEmail(orgID uuid.UUID, email string) ([]*Invitation, error)
+	// Accept marks a pending invitation that has not expired at the given time accepted; false
+	// when it was not open
+	Accept(id, userID uuid.UUID, at time.Time) (bool, error)
+	// Revoke withdraws a pending invitation; false when it was not pending
+	Revoke(id, revokedBy uuid.UUID, at time.Time) (bool, error)
+}
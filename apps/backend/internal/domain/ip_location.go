@@ -0,0 +1,21 @@
+package domain
+
+// Networks of addresses that have no geographic location
+const (
+	IPNetworkPrivate  = "private"
+	IPNetworkLoopback = "loopback"
+)
+
+// IPLocation is where an IP address is registered, as far as the GeoIP database knows
+type IPLocation struct {
+	Country string `json:"country,omitempty"` // ISO 3166-1 alpha-2 code
+	Region  string `json:"region,omitempty"`
+	City    string `json:"city,omitempty"`
+	Network string `json:"network,omitempty"` // private or loopback, for addresses without a location
+}
+
+// IPLocator looks up where IP addresses are
+type IPLocator interface {
+	// Locate returns the location of the address, or nil when it is unknown or not an address
+	Locate(ip string) *IPLocation
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// JobLease is held by the server instance that runs a set of background jobs. Instances
+// renew the lease while they are alive; another instance takes over once it expires.
+type JobLease struct {
+	Name       string    `json:"name"`
+	HolderID   string    `json:"holderId"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// JobLeaseRepository defines the interface for job lease persistence
+type JobLeaseRepository interface {
+	// Acquire takes the named lease for holderID, or renews it when holderID already holds it,
+	// until ttl from now. Returns false while another holder's lease has not expired.
+	Acquire(name, holderID string, ttl time.Duration) (bool, error)
+	// Release gives up the named lease if holderID holds it
+	Release(name, holderID string) error
+}
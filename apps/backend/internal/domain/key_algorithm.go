@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Signature algorithms of agent keys
+const (
+	KeyAlgorithmEd25519   = "Ed25519"
+	KeyAlgorithmECDSAP256 = "ECDSA-P256" // ECDSA on NIST P-256 with SHA-256
+	KeyAlgorithmRSAPSS    = "RSA-PSS"    // RSASSA-PSS with SHA-256, keys of at least 2048 bits
+)
+
+// KeyAlgorithms lists the supported key algorithms
+var KeyAlgorithms = []string{KeyAlgorithmEd25519, KeyAlgorithmECDSAP256, KeyAlgorithmRSAPSS}
+
+// keyAlgorithmAliases maps the names SDKs and JOSE use to the supported algorithms
+var keyAlgorithmAliases = map[string]string{
+	"ed25519":    KeyAlgorithmEd25519,
+	"eddsa":      KeyAlgorithmEd25519,
+	"ecdsa-p256": KeyAlgorithmECDSAP256,
+	"ecdsa":      KeyAlgorithmECDSAP256,
+	"p-256":      KeyAlgorithmECDSAP256,
+	"es256":      KeyAlgorithmECDSAP256,
+	"rsa-pss":    KeyAlgorithmRSAPSS,
+	"rsa":        KeyAlgorithmRSAPSS,
+	"ps256":      KeyAlgorithmRSAPSS,
+}
+
+// ErrKeyAlgorithmNotAllowed is returned for keys of an algorithm the organization does not allow
+var ErrKeyAlgorithmNotAllowed = errors.New("key algorithm is not allowed by the organization")
+
+// NormalizeKeyAlgorithm returns the supported algorithm a name stands for, case-insensitively.
+// Agents stored before algorithms were recorded have none; their keys are Ed25519.
+func NormalizeKeyAlgorithm(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return KeyAlgorithmEd25519, true
+	}
+	algorithm, ok := keyAlgorithmAliases[name]
+	return algorithm, ok
+}
+
+// OrganizationSettingKeyAlgorithms is the Organization.Settings key holding the key algorithms
+// the organization's agents may use
+const OrganizationSettingKeyAlgorithms = "allowedKeyAlgorithms"
+
+// AllowedKeyAlgorithms returns the key algorithms the organization's agents may register and
+// sign with; every supported algorithm unless the organization restricted them
+func (o *Organization) AllowedKeyAlgorithms() []string {
+	value, ok := o.Settings[OrganizationSettingKeyAlgorithms]
+	if !ok || value == nil {
+		return KeyAlgorithms
+	}
+	// Settings read from the database are plain JSON values
+	data, err := json.Marshal(value)
+	if err != nil {
+		return KeyAlgorithms
+	}
+	var algorithms []string
+	if err := json.Unmarshal(data, &algorithms); err != nil || len(algorithms) == 0 {
+		return KeyAlgorithms
+	}
+	return algorithms
+}
+
+// AllowsKeyAlgorithm reports whether the organization's agents may use keys of the algorithm
+func (o *Organization) AllowsKeyAlgorithm(algorithm string) bool {
+	algorithm, ok := NormalizeKeyAlgorithm(algorithm)
+	return ok && slices.Contains(o.AllowedKeyAlgorithms(), algorithm)
+}
+
+// AgentKey is a public key an agent's signatures are verified with
+type AgentKey struct {
+	PublicKey string // Base64
+	Algorithm string
+	Previous  bool // The key replaced by the last rotation, accepted until the grace period ends
+}
+
+// VerificationKeys returns the keys signatures are checked against: the current key, followed by
+// the previous key while the rotation grace period has not ended. Each key keeps the algorithm it
+// was registered with, so an agent can switch algorithms by rotating its key.
+func (a *Agent) VerificationKeys(at time.Time) []AgentKey {
+	keys := make([]AgentKey, 0, 2)
+	for _, publicKey := range a.VerificationPublicKeys(at) {
+		key := AgentKey{PublicKey: publicKey, Algorithm: a.KeyAlgorithm}
+		if a.PublicKey == nil || publicKey != *a.PublicKey {
+			key.Algorithm, key.Previous = a.PreviousKeyAlgorithm, true
+		}
+		key.Algorithm, _ = NormalizeKeyAlgorithm(key.Algorithm)
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// VerificationKey returns the key matching publicKey if signatures made with it are accepted
+func (a *Agent) VerificationKey(publicKey string, at time.Time) (AgentKey, bool) {
+	for _, key := range a.VerificationKeys(at) {
+		if key.PublicKey == publicKey {
+			return key, true
+		}
+	}
+	return AgentKey{}, false
+}
+
+// KeyAlgorithmUsage counts an organization's agents by the algorithm of their key and lists the
+// agents that still have to rotate to an allowed algorithm
+type KeyAlgorithmUsage struct {
+	AllowedAlgorithms []string                `json:"allowedAlgorithms"`
+	Agents            map[string]int          `json:"agents"` // Agents with a key, by algorithm
+	NeedsMigration    []KeyAlgorithmMigration `json:"needsMigration"`
+}
+
+// KeyAlgorithmMigration is an agent whose key uses an algorithm the organization does not allow.
+// Its signatures are rejected until it rotates to a key of an allowed algorithm.
+type KeyAlgorithmMigration struct {
+	AgentID      uuid.UUID `json:"agentId"`
+	AgentName    string    `json:"agentName"`
+	KeyAlgorithm string    `json:"keyAlgorithm"`
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginAttemptOutcome is what became of a password login
+type LoginAttemptOutcome string
+
+const (
+	LoginAttemptSucceeded LoginAttemptOutcome = "succeeded" // The password was right; earlier failures of the email are cleared
+	LoginAttemptFailed    LoginAttemptOutcome = "failed"    // Unknown email or wrong password
+	LoginAttemptBlocked   LoginAttemptOutcome = "blocked"   // Refused before the password was checked: locked, backing off or missing a CAPTCHA
+	LoginAttemptUnlocked  LoginAttemptOutcome = "unlocked"  // Not a login: an administrator lifted the lockout, clearing earlier failures
+)
+
+// LoginAttempt is one password login to an email from an IP address. Attempts to unknown emails
+// are tracked too, so they are throttled and locked like any other.
+type LoginAttempt struct {
+	ID             uuid.UUID           `json:"id"`
+	Email          string              `json:"email"` // Lowercased
+	IPAddress      string              `json:"ipAddress"`
+	UserID         *uuid.UUID          `json:"userId,omitempty"`         // Nil for emails without a user
+	OrganizationID *uuid.UUID          `json:"organizationId,omitempty"` // The user's organization
+	Outcome        LoginAttemptOutcome `json:"outcome"`
+
+	// Set on the failure that reached a lockout threshold
+	AccountLockedUntil *time.Time `json:"accountLockedUntil,omitempty"`
+	AddressLockedUntil *time.Time `json:"addressLockedUntil,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// LoginFailures summarizes the failed logins to an email or from an IP address
+type LoginFailures struct {
+	Count        int        // Failures since the time asked for
+	Emails       int        // Distinct emails among them
+	LastFailedAt *time.Time // Latest of them
+	LockedUntil  *time.Time // Latest lockout of the email or address, however old the failure that set it
+}
+
+// LoginAttemptFilter narrows an organization's login attempts
+type LoginAttemptFilter struct {
+	OrganizationID uuid.UUID
+	Email          string
+	Outcome        LoginAttemptOutcome
+	Limit          int
+	Offset         int
+}
+
+// LoginAttemptRepository stores password logins
+type LoginAttemptRepository interface {
+	Record(attempt *LoginAttempt) error
+	// EmailFailures counts the failures of the email since the given time that no later
+	// success or unlock cleared; LockedUntil is the account lockout they set
+	EmailFailures(email string, since time.Time) (*LoginFailures, error)
+	// IPFailures counts the failures from the address since the given time; successes do not
+	// clear them, as credential stuffing succeeds now and then. LockedUntil is the address block.
+	IPFailures(ipAddress string, since time.Time) (*LoginFailures, error)
+	// TargetedOrganizations returns the organizations whose users the address failed to log in
+	// as since the given time
+	TargetedOrganizations(ipAddress string, since time.Time) ([]uuid.UUID, error)
+	// List returns a page of the organization's attempts, newest first, and the total matching
+	List(filter LoginAttemptFilter) ([]*LoginAttempt, int, error)
+	DeleteBefore(before time.Time) (int, error)
+}
+
+// CaptchaVerifier checks the CAPTCHA response a login was sent with once it has to prove it
+// comes from a person
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) (bool, error)
+}
@@ -36,14 +36,27 @@ type AgentMCPConnection struct {
 // SDK uses Python's json.dumps(sort_keys=True) which produces alphabetically sorted keys
 type AttestationPayload struct {
 	AgentID              string   `json:"agent_id"`                // 1. agent_id
-	CapabilitiesFound    []string `json:"capabilities_found"`      // 2. capabilities_found
-	ConnectionLatencyMs  float64  `json:"connection_latency_ms"`   // 3. connection_latency_ms
-	ConnectionSuccessful bool     `json:"connection_successful"`   // 4. connection_successful
-	HealthCheckPassed    bool     `json:"health_check_passed"`     // 5. health_check_passed
-	MCPName              string   `json:"mcp_name"`                // 6. mcp_name
-	MCPURL               string   `json:"mcp_url"`                 // 7. mcp_url
-	SDKVersion           string   `json:"sdk_version"`             // 8. sdk_version
-	Timestamp            string   `json:"timestamp"`               // 9. timestamp
+	CallbackURL          string   `json:"callback_url,omitempty"`  // 2. callback_url (optional, receives the verified result)
+	CapabilitiesFound    []string `json:"capabilities_found"`      // 3. capabilities_found
+	ConnectionLatencyMs  float64  `json:"connection_latency_ms"`   // 4. connection_latency_ms
+	ConnectionSuccessful bool     `json:"connection_successful"`   // 5. connection_successful
+	HealthCheckPassed    bool     `json:"health_check_passed"`     // 6. health_check_passed
+	MCPName              string   `json:"mcp_name"`                // 7. mcp_name
+	MCPURL               string   `json:"mcp_url"`                 // 8. mcp_url
+	NetworkContext       *NetworkContext `json:"network_context,omitempty"` // 9. network_context (optional, private network evidence)
+	Nonce                string   `json:"nonce,omitempty"`         // 10. nonce (server-issued, single use)
+	SDKVersion           string   `json:"sdk_version"`             // 11. sdk_version
+	Timestamp            string   `json:"timestamp"`               // 12. timestamp
+}
+
+// NetworkContext is evidence the SDK includes when the MCP server was reached over a
+// private network the backend cannot see. Fields are alphabetical for canonical JSON;
+// the SDK omits empty strings so both sides serialize identically.
+type NetworkContext struct {
+	NetworkZone     string `json:"network_zone,omitempty"`     // 1. network_zone (VPC, datacenter or site)
+	PrivateNetwork  bool   `json:"private_network"`            // 2. private_network
+	RelayID         string `json:"relay_id,omitempty"`         // 3. relay_id (registered relay record, if known)
+	ResolvedAddress string `json:"resolved_address,omitempty"` // 4. resolved_address (address the agent connected to)
 }
 
 // ToCanonicalJSON converts attestation payload to canonical JSON for signature verification
@@ -71,6 +84,13 @@ type MCPAttestation struct {
 	IsValid           bool               `json:"isValid"`
 	CreatedAt         time.Time          `json:"createdAt"`
 
+	// AgentVerifiedOnly marks attestations of private-network MCPs the backend cannot reach itself
+	AgentVerifiedOnly bool `json:"agentVerifiedOnly"`
+
+	// CapabilityDiscrepancy is how the capabilities found differed from the server's registered
+	// and discovered capabilities when the attestation was recorded; nil when they were not compared
+	CapabilityDiscrepancy *CapabilityDiscrepancy `json:"capabilityDiscrepancy,omitempty"`
+
 	// Populated via JOIN queries
 	AgentName       string  `json:"agentName,omitempty"`
 	AgentTrustScore float64 `json:"agentTrustScore,omitempty"`
@@ -98,6 +118,37 @@ type AttestationWithAgentDetails struct {
 	ConnectionSuccessful bool      `json:"connectionSuccessful"`      // Whether connection test succeeded
 	AgentOwnerName       string    `json:"agentOwnerName,omitempty"`  // Name of user who owns the agent (for SDK attestations)
 	AgentOwnerID         uuid.UUID `json:"agentOwnerId,omitempty"`    // ID of user who owns the agent (for SDK attestations)
+	AgentVerifiedOnly    bool      `json:"agentVerifiedOnly"`         // Private-network MCP, backend could not verify independently
+
+	CapabilityDiscrepancy *CapabilityDiscrepancy `json:"capabilityDiscrepancy,omitempty"` // Capabilities found vs the server's
+}
+
+// CapabilityDiscrepancy is how the capabilities an attestation found differ from the MCP
+// server's registered and discovered capabilities
+type CapabilityDiscrepancy struct {
+	Unexposed []string `json:"unexposed"` // Extra: found, but neither registered nor discovered on the server
+	Missing   []string `json:"missing"`   // Tools discovery found on the server that the attestation did not
+}
+
+// Empty reports whether the attestation agrees with the server
+func (d *CapabilityDiscrepancy) Empty() bool {
+	return len(d.Unexposed) == 0 && len(d.Missing) == 0
+}
+
+// MCPNetworkRelay records that an agent reaches an MCP server over a private network.
+// While an MCP server has an active relay the backend skips its own connectivity checks.
+type MCPNetworkRelay struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organizationId"`
+	MCPServerID    uuid.UUID  `json:"mcpServerId"`
+	AgentID        uuid.UUID  `json:"agentId"`
+	NetworkZone    string     `json:"networkZone"`
+	Description    string     `json:"description"`
+	IsActive       bool       `json:"isActive"`
+	LastAttestedAt *time.Time `json:"lastAttestedAt"`
+	CreatedBy      *uuid.UUID `json:"createdBy"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
 }
 
 // VerificationMethod represents how an MCP server was verified
@@ -118,7 +169,7 @@ type MCPAttestationRepository interface {
 	GetValidAttestationsByMCP(mcpServerID uuid.UUID) ([]*MCPAttestation, error)
 	GetAttestationsByAgent(agentID uuid.UUID) ([]*MCPAttestation, error)
 	InvalidateAttestation(id uuid.UUID) error
-	InvalidateExpiredAttestations() error // Background job
+	InvalidateExpiredAttestations() ([]*MCPAttestation, error) // Background job; returns the attestations it expired
 
 	// Connection operations
 	CreateConnection(connection *AgentMCPConnection) error
@@ -129,6 +180,83 @@ type MCPAttestationRepository interface {
 	UpdateConnection(connection *AgentMCPConnection) error
 	DeleteConnection(id uuid.UUID) error
 
-	// Confidence score operations
-	UpdateMCPConfidenceScore(mcpServerID uuid.UUID, score float64, attestationCount int, lastAttestedAt time.Time) error
+	// Private network relay operations
+	CreateRelay(relay *MCPNetworkRelay) error
+	GetRelayByID(id uuid.UUID) (*MCPNetworkRelay, error)
+	GetRelaysByMCP(mcpServerID uuid.UUID) ([]*MCPNetworkRelay, error)
+	GetActiveRelay(mcpServerID, agentID uuid.UUID) (*MCPNetworkRelay, error)
+	HasActiveRelays(mcpServerID uuid.UUID) (bool, error)
+	TouchRelay(id uuid.UUID, attestedAt time.Time) error
+	DeleteRelay(id uuid.UUID) error
+}
+
+// AttestationNonce is a single-use challenge issued to an agent. The agent signs it into its
+// next attestation, so a captured attestation cannot be submitted a second time.
+type AttestationNonce struct {
+	ID             uuid.UUID  `json:"id"`
+	Nonce          string     `json:"nonce"`
+	AgentID        uuid.UUID  `json:"agentId"`
+	OrganizationID uuid.UUID  `json:"organizationId"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	ConsumedAt     *time.Time `json:"consumedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// OrganizationSettingRequireAttestationNonce is the Organization.Settings key that rejects
+// attestations without a nonce. Until it is on, attestations from SDKs that predate nonces are
+// accepted and logged, so organizations can upgrade their agents before enforcing it.
+const OrganizationSettingRequireAttestationNonce = "requireAttestationNonce"
+
+// RequiresAttestationNonce reports whether the organization's agents must sign a server-issued
+// nonce into every attestation
+func (o *Organization) RequiresAttestationNonce() bool {
+	required, _ := o.Settings[OrganizationSettingRequireAttestationNonce].(bool)
+	return required
+}
+
+// AttestationNonceRepository defines the interface for attestation nonce persistence
+type AttestationNonceRepository interface {
+	Create(nonce *AttestationNonce) error
+	GetByNonce(nonce string) (*AttestationNonce, error)
+	// Consume marks the agent's unexpired, unused nonce as used at the given time and reports
+	// whether it did. It is atomic, so only one of several concurrent submissions can succeed.
+	Consume(nonce string, agentID uuid.UUID, at time.Time) (bool, error)
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+// Statuses of attestation results
+const (
+	AttestationResultVerified = "verified"
+	AttestationResultRejected = "rejected"
+)
+
+// Reasons an attestation is rejected
+const (
+	AttestationRejectedSchema            = "schema_invalid"       // Payload fields are missing or malformed
+	AttestationRejectedAgentNotFound     = "agent_not_found"      // agent_id names no agent
+	AttestationRejectedAgentNotVerified  = "agent_not_verified"   // Only verified agents can attest
+	AttestationRejectedNoPublicKey       = "no_public_key"        // The agent has no public key to verify against
+	AttestationRejectedSignatureMismatch = "signature_mismatch"   // The signature does not match the payload and key
+	AttestationRejectedExpired           = "expired"              // The attestation is older than 5 minutes
+	AttestationRejectedNonce             = "nonce_rejected"       // The nonce is missing, unknown, expired or already used
+	AttestationRejectedServerNotFound    = "mcp_server_not_found" // The attested MCP server is not registered
+	AttestationRejectedInternal          = "internal_error"       // The attestation could not be recorded
+)
+
+// AttestationResult reports the outcome of an agent's attestation to the agent's owner and to the
+// SDK's callback URL
+type AttestationResult struct {
+	Status            string     `json:"status"`                  // verified or rejected
+	Reason            string     `json:"reason,omitempty"`        // Rejection reason code
+	Message           string     `json:"message"`                 // Human readable outcome, e.g. the rejection cause
+	AttestationID     *uuid.UUID `json:"attestationId,omitempty"` // Set when verified
+	AgentID           uuid.UUID  `json:"agentId"`
+	AgentName         string     `json:"agentName"`
+	OwnerID           uuid.UUID  `json:"ownerId"`
+	MCPServerID       *uuid.UUID `json:"mcpServerId,omitempty"`
+	MCPURL            string     `json:"mcpUrl,omitempty"`
+	ConfidenceScore   float64    `json:"confidenceScore,omitempty"`
+	AgentVerifiedOnly bool       `json:"agentVerifiedOnly,omitempty"`
+	SubmittedAt       string     `json:"submittedAt,omitempty"` // The attestation's own timestamp
+	CompletedAt       time.Time  `json:"completedAt"`
 }
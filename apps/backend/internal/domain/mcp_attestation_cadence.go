@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MCPServerCriticality is how critical an MCP server is to the organization. More critical
+// servers must be attested more often and by more agents.
+type MCPServerCriticality string
+
+const (
+	MCPServerCriticalityLow      MCPServerCriticality = "low"
+	MCPServerCriticalityMedium   MCPServerCriticality = "medium"
+	MCPServerCriticalityHigh     MCPServerCriticality = "high"
+	MCPServerCriticalityCritical MCPServerCriticality = "critical"
+)
+
+// DefaultMCPServerCriticality is the criticality of servers nobody tagged
+const DefaultMCPServerCriticality = MCPServerCriticalityMedium
+
+// MCPServerCriticalities are the criticality levels from least to most critical
+var MCPServerCriticalities = []MCPServerCriticality{
+	MCPServerCriticalityLow,
+	MCPServerCriticalityMedium,
+	MCPServerCriticalityHigh,
+	MCPServerCriticalityCritical,
+}
+
+// IsValid reports whether the criticality is a known level
+func (c MCPServerCriticality) IsValid() bool {
+	for _, level := range MCPServerCriticalities {
+		if c == level {
+			return true
+		}
+	}
+	return false
+}
+
+// AttestationCadencePolicy is how fresh the attestations of an organization's MCP servers of a
+// criticality must be: at least MinIndependentAgents different agents must each have attested
+// the server within the last MaxAttestationAgeHours.
+type AttestationCadencePolicy struct {
+	OrganizationID         uuid.UUID            `json:"organizationId"`
+	Criticality            MCPServerCriticality `json:"criticality"`
+	MaxAttestationAgeHours int                  `json:"maxAttestationAgeHours"`
+	MinIndependentAgents   int                  `json:"minIndependentAgents"`
+	IsDefault              bool                 `json:"isDefault"` // Not customized by the organization
+	UpdatedBy              *uuid.UUID           `json:"updatedBy,omitempty"`
+	UpdatedAt              *time.Time           `json:"updatedAt,omitempty"`
+}
+
+// MaxAttestationAge is how old an attestation may be and still count towards the cadence
+func (p *AttestationCadencePolicy) MaxAttestationAge() time.Duration {
+	return time.Duration(p.MaxAttestationAgeHours) * time.Hour
+}
+
+// DefaultAttestationCadencePolicies are the cadences of organizations that did not set their own
+var DefaultAttestationCadencePolicies = map[MCPServerCriticality]AttestationCadencePolicy{
+	MCPServerCriticalityLow:      {Criticality: MCPServerCriticalityLow, MaxAttestationAgeHours: 30 * 24, MinIndependentAgents: 1},
+	MCPServerCriticalityMedium:   {Criticality: MCPServerCriticalityMedium, MaxAttestationAgeHours: 7 * 24, MinIndependentAgents: 1},
+	MCPServerCriticalityHigh:     {Criticality: MCPServerCriticalityHigh, MaxAttestationAgeHours: 72, MinIndependentAgents: 2},
+	MCPServerCriticalityCritical: {Criticality: MCPServerCriticalityCritical, MaxAttestationAgeHours: 24, MinIndependentAgents: 2},
+}
+
+// AttestationCadenceStatus is whether an MCP server's attestations keep up with the cadence of
+// its criticality
+type AttestationCadenceStatus struct {
+	MCPServerID            uuid.UUID            `json:"mcpServerId"`
+	Criticality            MCPServerCriticality `json:"criticality"`
+	MaxAttestationAgeHours int                  `json:"maxAttestationAgeHours"`
+	RequiredAgents         int                  `json:"requiredAgents"`
+	FreshAgents            int                  `json:"freshAgents"` // Agents with a verified attestation within the maximum age
+	Compliant              bool                 `json:"compliant"`
+	// When the server falls out of cadence unless attested again: the time the attestation of
+	// the RequiredAgents-th freshest agent gets too old. nil when the server is out of cadence.
+	DueAt           *time.Time `json:"dueAt,omitempty"`
+	ConfidenceScale float64    `json:"confidenceScale"` // Factor applied to the server's confidence score
+	EvaluatedAt     time.Time  `json:"evaluatedAt"`
+}
+
+// AttestationCadencePolicyRepository stores the attestation cadences organizations set
+type AttestationCadencePolicyRepository interface {
+	// ListByOrganization returns the organization's customized cadences
+	ListByOrganization(orgID uuid.UUID) ([]*AttestationCadencePolicy, error)
+	// Upsert stores the cadence of its organization and criticality, replacing the existing one
+	Upsert(policy *AttestationCadencePolicy) error
+	// Delete removes the organization's cadence of the criticality, restoring the default
+	Delete(orgID uuid.UUID, criticality MCPServerCriticality) error
+}
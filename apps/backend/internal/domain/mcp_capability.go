@@ -16,6 +16,30 @@ const (
 	MCPCapabilityTypePrompt   MCPCapabilityType = "prompt"
 )
 
+// MCPToolRisk classifies what an MCP capability can do when an agent uses it
+type MCPToolRisk string
+
+const (
+	MCPToolRiskRead         MCPToolRisk = "read"         // Reads or queries data
+	MCPToolRiskWrite        MCPToolRisk = "write"        // Creates or modifies data
+	MCPToolRiskDestructive  MCPToolRisk = "destructive"  // Deletes data or executes commands
+	MCPToolRiskExfiltration MCPToolRisk = "exfiltration" // Can send data outside the organization
+)
+
+// IsValid reports whether the risk is one of the known classifications
+func (r MCPToolRisk) IsValid() bool {
+	switch r {
+	case MCPToolRiskRead, MCPToolRiskWrite, MCPToolRiskDestructive, MCPToolRiskExfiltration:
+		return true
+	}
+	return false
+}
+
+// IsSensitive reports whether tools of this risk are flagged for extra policy conditions
+func (r MCPToolRisk) IsSensitive() bool {
+	return r == MCPToolRiskDestructive || r == MCPToolRiskExfiltration
+}
+
 // MCPServerCapability represents an individual capability exposed by an MCP server
 type MCPServerCapability struct {
 	ID               uuid.UUID         `json:"id"`
@@ -29,6 +53,12 @@ type MCPServerCapability struct {
 	IsActive         bool              `json:"isActive"`
 	CreatedAt        time.Time         `json:"createdAt"`
 	UpdatedAt        time.Time         `json:"updatedAt"`
+
+	// Risk classification - RiskOverride is set by an admin and persisted; RiskLevel and
+	// Sensitive are derived when the capability is read (the override wins over heuristics)
+	RiskOverride *MCPToolRisk `json:"riskOverride,omitempty"`
+	RiskLevel    MCPToolRisk  `json:"riskLevel"`
+	Sensitive    bool         `json:"sensitive"`
 }
 
 // MCPServerCapabilityRepository defines the interface for MCP capability persistence
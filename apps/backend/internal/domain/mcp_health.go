@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrMCPServerHealthNotFound is returned when an MCP server has not been health checked yet
+var ErrMCPServerHealthNotFound = errors.New("mcp server health not found")
+
+// MCPHealthCheckRetention is how long individual health checks are kept for uptime history
+const MCPHealthCheckRetention = 30 * 24 * time.Hour
+
+// MCPHealthState is the health of an MCP server according to its latest checks
+type MCPHealthState string
+
+const (
+	MCPHealthStateUnknown   MCPHealthState = "unknown"
+	MCPHealthStateHealthy   MCPHealthState = "healthy"
+	MCPHealthStateUnhealthy MCPHealthState = "unhealthy"
+)
+
+// MCPHealthCheck is one probe of an MCP server's URL
+type MCPHealthCheck struct {
+	ID          uuid.UUID `json:"id"`
+	MCPServerID uuid.UUID `json:"mcpServerId"`
+	Healthy     bool      `json:"healthy"`
+	StatusCode  int       `json:"statusCode,omitempty"` // 0 when the server could not be reached
+	LatencyMs   float64   `json:"latencyMs"`
+	Error       string    `json:"error,omitempty"`
+	CheckedAt   time.Time `json:"checkedAt"`
+}
+
+// MCPServerUptime is the percentage of passed health checks of an MCP server per window; a
+// window is nil when the server was not checked in it
+type MCPServerUptime struct {
+	Last24h *float64 `json:"last24h"`
+	Last7d  *float64 `json:"last7d"`
+	Last30d *float64 `json:"last30d"`
+}
+
+// MCPServerHealth is the health state of an MCP server, maintained by the health check job
+type MCPServerHealth struct {
+	MCPServerID         uuid.UUID      `json:"mcpServerId"`
+	State               MCPHealthState `json:"state"`
+	ConsecutiveFailures int            `json:"consecutiveFailures"`
+	LastCheckedAt       *time.Time     `json:"lastCheckedAt"`
+	LastHealthyAt       *time.Time     `json:"lastHealthyAt"`
+	LastError           string         `json:"lastError,omitempty"`
+	// Set while the server is suspended because of sustained failures; the status it had before
+	// is restored once a check passes again
+	DowngradedAt   *time.Time      `json:"downgradedAt"`
+	DowngradedFrom MCPServerStatus `json:"downgradedFrom,omitempty"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+
+	// Computed from the stored checks, not persisted
+	Uptime MCPServerUptime `json:"uptime"`
+}
+
+// MCPHealthCheckCount is how many of an MCP server's health checks passed
+type MCPHealthCheckCount struct {
+	Passed int `json:"passed"`
+	Total  int `json:"total"`
+}
+
+// MCPHealthRepository stores the health checks and health state of MCP servers
+type MCPHealthRepository interface {
+	RecordCheck(check *MCPHealthCheck) error
+	// ListChecks returns the most recent checks of the server, newest first
+	ListChecks(mcpServerID uuid.UUID, limit int) ([]*MCPHealthCheck, error)
+	// CountChecks counts the checks of each server since the given time; servers without
+	// checks are left out
+	CountChecks(mcpServerIDs []uuid.UUID, since time.Time) (map[uuid.UUID]MCPHealthCheckCount, error)
+	// DeleteChecksBefore prunes the checks older than the given time and returns how many were deleted
+	DeleteChecksBefore(before time.Time) (int, error)
+	// GetState returns ErrMCPServerHealthNotFound when the server has not been checked yet
+	GetState(mcpServerID uuid.UUID) (*MCPServerHealth, error)
+	UpsertState(health *MCPServerHealth) error
+}
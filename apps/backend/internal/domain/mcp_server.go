@@ -43,6 +43,8 @@ type MCPServer struct {
 	AttestationCount     int        `json:"attestationCount"`   // Number of verified agent attestations
 	ConfidenceScore      float64    `json:"confidenceScore"`    // Calculated from attestations (0-100)
 	LastAttestedAt       *time.Time `json:"lastAttestedAt"`     // Most recent attestation timestamp
+	// Criticality sets the attestation cadence the server is held to
+	Criticality MCPServerCriticality `json:"criticality"`
 	// Populated via JOIN queries
 	AttestedBy           []string `json:"attestedBy,omitempty"`           // Agent names that have attested
 	ConnectedAgentsCount int      `json:"connectedAgentsCount,omitempty"` // Number of connected agents
@@ -53,12 +55,15 @@ type MCPServer struct {
 type MCPServerRepository interface {
 	Create(server *MCPServer) error
 	GetByID(id uuid.UUID) (*MCPServer, error)
+	GetByIDs(ids []uuid.UUID) ([]*MCPServer, error)
 	GetByOrganization(orgID uuid.UUID) ([]*MCPServer, error)
 	GetByURL(url string) (*MCPServer, error)
 	Update(server *MCPServer) error
 	Delete(id uuid.UUID) error
 	List(limit, offset int) ([]*MCPServer, error)
 	GetVerificationStatus(id uuid.UUID) (*MCPServerVerificationStatus, error)
+	// UpdateConfidenceScore records the server's attestation confidence score
+	UpdateConfidenceScore(id uuid.UUID, score float64, attestationCount int, lastAttestedAt time.Time) error
 }
 
 // MCPServerVerificationStatus represents the verification status details
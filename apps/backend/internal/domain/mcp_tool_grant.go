@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrMCPToolGrantNotFound is returned when an agent has no tool grant for an MCP server
+var ErrMCPToolGrantNotFound = errors.New("MCP tool grant not found")
+
+// MCPToolGrant narrows an agent's access to an MCP server of its talks_to list to the listed
+// tools (agent X may call filesystem-mcp:read_file but not filesystem-mcp:write_file). Servers
+// the agent has no grant for allow every tool, so agents registered before tool grants keep
+// working; a grant without tools allows none.
+type MCPToolGrant struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	AgentID        uuid.UUID `json:"agentId"`
+	MCPServer      string    `json:"mcpServer"` // The talks_to entry (MCP server ID or name) the grant narrows
+	Tools          []string  `json:"tools"`
+	CreatedBy      uuid.UUID `json:"createdBy"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// Allows reports whether the grant lists the tool
+func (g *MCPToolGrant) Allows(tool string) bool {
+	return slices.Contains(g.Tools, tool)
+}
+
+// MCPToolCall formats a call of an MCP server's tool as "server:tool", the form verification
+// requests and events report tool calls in
+func MCPToolCall(server, tool string) string {
+	return server + ":" + tool
+}
+
+// ParseMCPToolCall splits a "server:tool" call at its last colon; ok is false when either part is empty
+func ParseMCPToolCall(call string) (server, tool string, ok bool) {
+	i := strings.LastIndex(call, ":")
+	if i <= 0 || i == len(call)-1 {
+		return "", "", false
+	}
+	return call[:i], call[i+1:], true
+}
+
+// MCPToolGrantRepository stores agents' MCP tool grants
+type MCPToolGrantRepository interface {
+	// Upsert stores the agent's grant for the MCP server, replacing the tools of an existing one
+	Upsert(grant *MCPToolGrant) error
+	// ListByAgent returns the agent's grants, oldest first
+	ListByAgent(agentID uuid.UUID) ([]*MCPToolGrant, error)
+	// Delete removes the agent's grant for the MCP server; ErrMCPToolGrantNotFound when it has none
+	Delete(agentID uuid.UUID, mcpServer string) error
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNamingPolicyNotFound is returned for unknown naming policies and policies of another organization
+var ErrNamingPolicyNotFound = errors.New("naming policy not found")
+
+// Resources naming policies apply to
+const (
+	NamingResourceAgent     = "agent"
+	NamingResourceMCPServer = "mcp_server"
+)
+
+// Naming policy violation codes
+const (
+	NamingViolationPattern   = "pattern_mismatch"
+	NamingViolationPrefix    = "prefix_required"
+	NamingViolationReserved  = "reserved_name"
+	NamingViolationNotUnique = "name_not_unique"
+)
+
+// NamingPolicy constrains the names of an organization's agents and MCP servers. A policy scoped
+// to a tag (e.g. environment=production or team=payments) only applies to resources carrying it.
+type NamingPolicy struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	// Scope - empty ResourceType applies to agents and MCP servers, empty TagKey to every resource
+	ResourceType string `json:"resourceType"`
+	TagKey       string `json:"tagKey,omitempty"`
+	TagValue     string `json:"tagValue,omitempty"`
+	// Rules - each one is optional
+	Pattern        string   `json:"pattern,omitempty"`        // RE2 expression the whole name must match
+	RequiredPrefix string   `json:"requiredPrefix,omitempty"` // Case-sensitive
+	ReservedNames  []string `json:"reservedNames"`            // Refused, compared ignoring case
+	// NormalizedUnique refuses names that equal another agent or MCP server name of the
+	// organization when compared ignoring case and the separators - _ . and space
+	NormalizedUnique bool      `json:"normalizedUnique"`
+	IsActive         bool      `json:"isActive"`
+	CreatedBy        uuid.UUID `json:"createdBy"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// NamingViolation is one naming policy rule a name breaks
+type NamingViolation struct {
+	PolicyID   uuid.UUID `json:"policyId"`
+	PolicyName string    `json:"policyName"`
+	Code       string    `json:"code"`
+	Message    string    `json:"message"`
+}
+
+// NamingPolicyRepository defines the interface for naming policy persistence
+type NamingPolicyRepository interface {
+	Create(policy *NamingPolicy) error
+	GetByID(id uuid.UUID) (*NamingPolicy, error)
+	// GetByOrganization returns the organization's policies, oldest first
+	GetByOrganization(orgID uuid.UUID) ([]*NamingPolicy, error)
+	GetActiveByOrganization(orgID uuid.UUID) ([]*NamingPolicy, error)
+	Update(policy *NamingPolicy) error
+	Delete(id uuid.UUID) error
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationChannelType represents the transport used to deliver a notification
+type NotificationChannelType string
+
+const (
+	NotificationChannelEmail     NotificationChannelType = "email"
+	NotificationChannelSlack     NotificationChannelType = "slack"
+	NotificationChannelWebhook   NotificationChannelType = "webhook"
+	NotificationChannelPagerDuty NotificationChannelType = "pagerduty"
+)
+
+// NotificationDeliveryStatus represents the state of a single queued delivery
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliveryPending   NotificationDeliveryStatus = "pending"   // Waiting in queue (or waiting for retry)
+	NotificationDeliverySending   NotificationDeliveryStatus = "sending"   // Claimed by a worker
+	NotificationDeliveryDelivered NotificationDeliveryStatus = "delivered" // Accepted by the downstream service
+	NotificationDeliveryFailed    NotificationDeliveryStatus = "failed"    // Retries exhausted, needs manual resend
+)
+
+// NotificationChannel represents an organization-configured destination for notifications
+type NotificationChannel struct {
+	ID             uuid.UUID               `json:"id"`
+	OrganizationID uuid.UUID               `json:"organizationId"`
+	Name           string                  `json:"name"`
+	ChannelType    NotificationChannelType `json:"channelType"`
+	// Recipients are transport specific: email addresses, Slack incoming webhook URLs,
+	// webhook endpoint URLs or PagerDuty routing keys. Each recipient gets its own delivery.
+	Recipients []string               `json:"recipients"`
+	Config     map[string]interface{} `json:"config"` // Transport options (e.g. webhook secret, Slack username)
+	// Matching - empty EventTypes matches every event
+	EventTypes  []string      `json:"eventTypes"`
+	MinSeverity AlertSeverity `json:"minSeverity"`
+	IsActive    bool          `json:"isActive"`
+	CreatedBy   uuid.UUID     `json:"createdBy"`
+	CreatedAt   time.Time     `json:"createdAt"`
+	UpdatedAt   time.Time     `json:"updatedAt"`
+}
+
+// Notification represents a single alert or event that is fanned out to channels
+type Notification struct {
+	ID             uuid.UUID              `json:"id"`
+	OrganizationID uuid.UUID              `json:"organizationId"`
+	EventType      string                 `json:"eventType"` // e.g. "alert.created", "drift.detected"
+	Severity       AlertSeverity          `json:"severity"`
+	Title          string                 `json:"title"`
+	Message        string                 `json:"message"`
+	ResourceType   string                 `json:"resourceType"`
+	ResourceID     *uuid.UUID             `json:"resourceId,omitempty"`
+	Payload        map[string]interface{} `json:"payload,omitempty"`
+	CreatedAt      time.Time              `json:"createdAt"`
+	// Populated on detail reads
+	Deliveries []*NotificationDelivery `json:"deliveries,omitempty"`
+}
+
+// NotificationDelivery tracks delivery of one notification to one recipient of one channel
+type NotificationDelivery struct {
+	ID             uuid.UUID                  `json:"id"`
+	NotificationID uuid.UUID                  `json:"notificationId"`
+	ChannelID      uuid.UUID                  `json:"channelId"`
+	ChannelType    NotificationChannelType    `json:"channelType"`
+	Recipient      string                     `json:"recipient"`
+	Status         NotificationDeliveryStatus `json:"status"`
+	Attempts       int                        `json:"attempts"`
+	MaxAttempts    int                        `json:"maxAttempts"`
+	LastError      *string                    `json:"lastError,omitempty"`
+	NextAttemptAt  time.Time                  `json:"nextAttemptAt"`
+	DeliveredAt    *time.Time                 `json:"deliveredAt,omitempty"`
+	Digest         bool                       `json:"digest"` // Sent with the recipient's other digest deliveries at the end of the digest window (NextAttemptAt)
+	CreatedAt      time.Time                  `json:"createdAt"`
+	UpdatedAt      time.Time                  `json:"updatedAt"`
+}
+
+// NotificationRoute routes alerts to channels by alert type, severity and the tags of the alert's
+// resource. Once an organization has active routes, its alerts follow the routes instead of the
+// channels' own event filters; other notifications are unaffected.
+type NotificationRoute struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	Name           string    `json:"name"`
+	// Matching - empty AlertTypes and ResourceTags match every alert
+	AlertTypes   []AlertType   `json:"alertTypes"`
+	MinSeverity  AlertSeverity `json:"minSeverity"`
+	MaxSeverity  AlertSeverity `json:"maxSeverity,omitempty"` // Empty has no upper bound
+	ResourceTags []string      `json:"resourceTags"`          // Any of these "key:value" tags of the alert's agent or MCP server
+	ChannelIDs   []uuid.UUID   `json:"channelIds"`
+	// DigestIntervalMinutes batches matching alerts into one message per recipient every so many
+	// minutes; 0 delivers each alert right away
+	DigestIntervalMinutes int       `json:"digestIntervalMinutes"`
+	IsActive              bool      `json:"isActive"`
+	CreatedBy             uuid.UUID `json:"createdBy"`
+	CreatedAt             time.Time `json:"createdAt"`
+	UpdatedAt             time.Time `json:"updatedAt"`
+}
+
+// NotificationRouteRepository defines the interface for notification route persistence
+type NotificationRouteRepository interface {
+	Create(route *NotificationRoute) error
+	GetByID(id uuid.UUID) (*NotificationRoute, error)
+	GetByOrganization(orgID uuid.UUID) ([]*NotificationRoute, error)
+	GetActiveByOrganization(orgID uuid.UUID) ([]*NotificationRoute, error)
+	Update(route *NotificationRoute) error
+	Delete(id uuid.UUID) error
+}
+
+// NotificationSender delivers a notification to a single recipient over one transport
+type NotificationSender interface {
+	ChannelType() NotificationChannelType
+	Send(ctx context.Context, channel *NotificationChannel, recipient string, notification *Notification) error
+}
+
+// NotificationRepository defines the interface for notification persistence and the delivery queue
+type NotificationRepository interface {
+	// Channels
+	CreateChannel(channel *NotificationChannel) error
+	GetChannelByID(id uuid.UUID) (*NotificationChannel, error)
+	GetChannelsByOrganization(orgID uuid.UUID) ([]*NotificationChannel, error)
+	GetActiveChannelsByOrganization(orgID uuid.UUID) ([]*NotificationChannel, error)
+	UpdateChannel(channel *NotificationChannel) error
+	DeleteChannel(id uuid.UUID) error
+
+	// Notifications and their deliveries (created atomically)
+	CreateNotification(notification *Notification, deliveries []*NotificationDelivery) error
+	GetNotificationByID(id uuid.UUID) (*Notification, error)
+	GetNotificationsByOrganization(orgID uuid.UUID, limit, offset int) ([]*Notification, int, error)
+	GetDeliveriesByNotification(notificationID uuid.UUID) ([]*NotificationDelivery, error)
+	GetDeliveryByID(id uuid.UUID) (*NotificationDelivery, error)
+
+	// Queue operations
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]*NotificationDelivery, error)
+	MarkDelivered(id uuid.UUID) error
+	MarkAttemptFailed(id uuid.UUID, errMsg string, nextAttemptAt time.Time, exhausted bool) error
+	RequeueDelivery(id uuid.UUID) error
+}
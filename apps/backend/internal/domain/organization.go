@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,16 +9,26 @@ import (
 
 // Organization represents a tenant organization
 type Organization struct {
-	ID        uuid.UUID              `json:"id"`
-	Name      string                 `json:"name"`
-	Domain    string                 `json:"domain"`
-	PlanType  string                 `json:"-"` // internal use only, not exposed via API
-	MaxAgents int                    `json:"maxAgents"`
-	MaxUsers  int                    `json:"maxUsers"`
-	IsActive  bool                   `json:"isActive"`
-	Settings  map[string]interface{} `json:"settings"` // Additional org settings
-	CreatedAt time.Time              `json:"createdAt"`
-	UpdatedAt time.Time              `json:"updatedAt"`
+	ID                       uuid.UUID              `json:"id"`
+	Name                     string                 `json:"name"`
+	Domain                   string                 `json:"domain"`
+	PlanType                 string                 `json:"-"` // internal use only, not exposed via API
+	MaxAgents                int                    `json:"maxAgents"`
+	MaxUsers                 int                    `json:"maxUsers"`
+	IsActive                 bool                   `json:"isActive"`
+	Settings                 map[string]interface{} `json:"settings"`                 // Additional org settings
+	AutoRegisterAttestedMCPs bool                   `json:"autoRegisterAttestedMcps"` // Attestations of unregistered MCP URLs create pending servers for admin review
+	ClientSideKeyGeneration  bool                   `json:"clientSideKeyGeneration"`  // Agent keys are generated by the SDK; the platform holds no private keys
+	MFARequiredRoles         []UserRole             `json:"mfaRequiredRoles"`         // Users with these roles must sign in with a TOTP code
+	ParentID                 *uuid.UUID             `json:"parentId,omitempty"`       // Set for sub-organizations (business units); fixed at creation
+	DataResidency            DataRegion             `json:"dataResidency,omitempty"`  // Region storing the agents, verification events and MCP attestations; empty for the control plane's region
+	CreatedAt                time.Time              `json:"createdAt"`
+	UpdatedAt                time.Time              `json:"updatedAt"`
+}
+
+// RequiresMFA reports whether users with the role must sign in with a second factor
+func (o *Organization) RequiresMFA(role UserRole) bool {
+	return slices.Contains(o.MFARequiredRoles, role)
 }
 
 // OrganizationRepository defines the interface for organization persistence
@@ -27,4 +38,41 @@ type OrganizationRepository interface {
 	GetByDomain(domain string) (*Organization, error)
 	Update(org *Organization) error
 	Delete(id uuid.UUID) error
+	List() ([]*Organization, error)
+	// GetChildren returns the organizations directly under the parent
+	GetChildren(parentID uuid.UUID) ([]*Organization, error)
+}
+
+// OrganizationNode is an organization in the hierarchy under the organization viewing it
+type OrganizationNode struct {
+	ID       uuid.UUID           `json:"id"`
+	Name     string              `json:"name"`
+	Domain   string              `json:"domain"`
+	IsActive bool                `json:"isActive"`
+	Children []*OrganizationNode `json:"children"`
+}
+
+// OrganizationHierarchy is an organization's place in its hierarchy: the chain of parents above
+// it and the sub-organizations below it. Siblings are never included.
+type OrganizationHierarchy struct {
+	Organization *OrganizationNode   `json:"organization"`
+	Ancestors    []*OrganizationNode `json:"ancestors"` // Nearest first, without their children
+}
+
+// OrganizationSecurityMetrics are the security metrics of one organization of a hierarchy
+type OrganizationSecurityMetrics struct {
+	OrganizationID uuid.UUID        `json:"organizationId"`
+	Name           string           `json:"name"`
+	Depth          int              `json:"depth"` // 0 for the organization the roll-up is for
+	AgentCount     int              `json:"agentCount"`
+	Metrics        *SecurityMetrics `json:"metrics"`
+}
+
+// OrganizationSecurityRollup rolls the security metrics of an organization and all of its
+// sub-organizations up into one view
+type OrganizationSecurityRollup struct {
+	OrganizationID uuid.UUID                      `json:"organizationId"`
+	AgentCount     int                            `json:"agentCount"`
+	Totals         *SecurityMetrics               `json:"totals"`
+	Organizations  []*OrganizationSecurityMetrics `json:"organizations"`
 }
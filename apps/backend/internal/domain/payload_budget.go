@@ -0,0 +1,47 @@
+package domain
+
+// Endpoint classes requests are additionally grouped into for request body limits only; rate
+// limits and timeouts count them as writes
+const (
+	EndpointClassAttestation = "attestation" // MCP attestations and connection reports of agents
+	EndpointClassBulk        = "bulk"        // Bulk ingest: identity bundle imports, SBOM uploads and detection reports
+)
+
+// BodyLimitClasses lists the endpoint classes with a request body limit
+var BodyLimitClasses = []string{
+	EndpointClassRead,
+	EndpointClassWrite,
+	EndpointClassVerification,
+	EndpointClassAttestation,
+	EndpointClassBulk,
+}
+
+// PayloadBudgets are the largest request body each endpoint class accepts and the largest
+// response a list endpoint returns, in bytes. A zero budget is unlimited; request bodies remain
+// held to the server-wide limit (see MaxRequestBody).
+type PayloadBudgets struct {
+	RequestBodies map[string]int
+	ListResponse  int
+}
+
+// DefaultPayloadBudgets are the budgets unless configured otherwise
+var DefaultPayloadBudgets = PayloadBudgets{
+	RequestBodies: map[string]int{
+		EndpointClassRead:         1 << 20,   // 1 MiB
+		EndpointClassWrite:        1 << 20,   // 1 MiB
+		EndpointClassVerification: 256 << 10, // 256 KiB
+		EndpointClassAttestation:  256 << 10, // 256 KiB
+		EndpointClassBulk:         16 << 20,  // 16 MiB
+	},
+	ListResponse: 8 << 20, // 8 MiB
+}
+
+// MaxRequestBody returns the server-wide request body limit: the largest class budget, and at
+// least minimum, which also bounds the classes without a budget
+func (b PayloadBudgets) MaxRequestBody(minimum int) int {
+	largest := minimum
+	for _, limit := range b.RequestBodies {
+		largest = max(largest, limit)
+	}
+	return largest
+}
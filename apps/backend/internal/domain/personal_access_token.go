@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PersonalAccessTokenPrefix starts every personal access token. It differs from the "aim_"
+// prefix of agent API keys, so the API key middleware leaves these tokens alone.
+const PersonalAccessTokenPrefix = "aimpat_"
+
+// ErrPersonalAccessTokenNotFound is returned for unknown tokens and tokens of another user or organization
+var ErrPersonalAccessTokenNotFound = errors.New("personal access token not found")
+
+// Personal access token scopes. Like API key scopes, a scope is "resource:action";
+// "resource:*" grants both actions on a resource and "*" grants everything the user can do.
+const (
+	PersonalAccessTokenScopeAll                = "*"
+	PersonalAccessTokenScopeAgentsRead         = "agents:read"
+	PersonalAccessTokenScopeAgentsWrite        = "agents:write"
+	PersonalAccessTokenScopeMCPServersRead     = "mcp_servers:read"
+	PersonalAccessTokenScopeMCPServersWrite    = "mcp_servers:write"
+	PersonalAccessTokenScopeVerificationsRead  = "verifications:read"
+	PersonalAccessTokenScopeVerificationsWrite = "verifications:write"
+	PersonalAccessTokenScopeSecurityRead       = "security:read"
+	PersonalAccessTokenScopeSecurityWrite      = "security:write"
+	PersonalAccessTokenScopeAnalyticsRead      = "analytics:read"
+	PersonalAccessTokenScopeAuditRead          = "audit:read"
+	PersonalAccessTokenScopeTagsRead           = "tags:read"
+	PersonalAccessTokenScopeTagsWrite          = "tags:write"
+	PersonalAccessTokenScopeWebhooksRead       = "webhooks:read"
+	PersonalAccessTokenScopeWebhooksWrite      = "webhooks:write"
+	PersonalAccessTokenScopeReportsRead        = "reports:read"
+	PersonalAccessTokenScopeReportsWrite       = "reports:write"
+)
+
+// PersonalAccessTokenScopes lists the scopes a personal access token can be created with
+var PersonalAccessTokenScopes = []string{
+	PersonalAccessTokenScopeAgentsRead,
+	PersonalAccessTokenScopeAgentsWrite,
+	PersonalAccessTokenScopeMCPServersRead,
+	PersonalAccessTokenScopeMCPServersWrite,
+	PersonalAccessTokenScopeVerificationsRead,
+	PersonalAccessTokenScopeVerificationsWrite,
+	PersonalAccessTokenScopeSecurityRead,
+	PersonalAccessTokenScopeSecurityWrite,
+	PersonalAccessTokenScopeAnalyticsRead,
+	PersonalAccessTokenScopeAuditRead,
+	PersonalAccessTokenScopeTagsRead,
+	PersonalAccessTokenScopeTagsWrite,
+	PersonalAccessTokenScopeWebhooksRead,
+	PersonalAccessTokenScopeWebhooksWrite,
+	PersonalAccessTokenScopeReportsRead,
+	PersonalAccessTokenScopeReportsWrite,
+}
+
+// IsValidPersonalAccessTokenScope reports whether scope is a known scope, a resource wildcard or "*"
+func IsValidPersonalAccessTokenScope(scope string) bool {
+	if scope == PersonalAccessTokenScopeAll {
+		return true
+	}
+	for _, known := range PersonalAccessTokenScopes {
+		if scope == known || scope == apiKeyScopeResource(known)+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// PersonalAccessToken lets a user script against the API with a subset of their own permissions.
+// Unlike SDK tokens, which are issued with the downloaded SDK for agents, they are created by the
+// user with named scopes and always expire.
+type PersonalAccessToken struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organizationId"`
+	UserID         uuid.UUID  `json:"userId"`
+	Name           string     `json:"name"`
+	TokenHash      string     `json:"-"`      // SHA-256 hash; the token itself is only shown once
+	Prefix         string     `json:"prefix"` // "aimpat_" and the first characters, to recognize the token
+	Scopes         []string   `json:"scopes"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	LastUsedAt     *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedIP     *string    `json:"lastUsedIp,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+	RevokedBy      *uuid.UUID `json:"revokedBy,omitempty"`
+	RevokeReason   *string    `json:"revokeReason,omitempty"`
+}
+
+// IsActive reports whether the token is neither revoked nor expired at now
+func (t *PersonalAccessToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// HasScope reports whether the token may perform the scoped action. Unlike API keys, a token
+// without scopes may do nothing.
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	return len(t.Scopes) > 0 && APIKeyScopesAllow(t.Scopes, scope)
+}
+
+// PersonalAccessTokenRepository defines the interface for personal access token persistence
+type PersonalAccessTokenRepository interface {
+	Create(token *PersonalAccessToken) error
+	GetByID(id uuid.UUID) (*PersonalAccessToken, error)
+	// GetByHash returns ErrPersonalAccessTokenNotFound when no token has the hash
+	GetByHash(tokenHash string) (*PersonalAccessToken, error)
+	// ListByUser and ListByOrganization return tokens newest first, revoked ones included
+	ListByUser(userID uuid.UUID) ([]*PersonalAccessToken, error)
+	ListByOrganization(orgID uuid.UUID) ([]*PersonalAccessToken, error)
+	RecordUsage(id uuid.UUID, ipAddress string, usedAt time.Time) error
+	Revoke(id, revokedBy uuid.UUID, reason string) error
+}
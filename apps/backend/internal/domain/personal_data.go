@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrDataErasureRequestNotFound is returned when a data erasure request does not exist
+	ErrDataErasureRequestNotFound = errors.New("data erasure request not found")
+	// ErrDataErasureRequestOpen is returned when the user already has an erasure request that is
+	// pending or has not completed
+	ErrDataErasureRequestOpen = errors.New("an erasure request for this user is already open")
+)
+
+// ErasedUserName replaces the name of a user whose personal data was erased
+const ErasedUserName = "Erased user"
+
+// UserDataExport is the personal data held about a user (GDPR Art. 15 and 20): their profile,
+// tokens, audit trail and the approvals they requested or decided. Token hashes and secrets are
+// never included.
+type UserDataExport struct {
+	ExportedAt           time.Time              `json:"exportedAt"`
+	Profile              *User                  `json:"profile"`
+	SDKTokens            []*SDKToken            `json:"sdkTokens"`
+	PersonalAccessTokens []*PersonalAccessToken `json:"personalAccessTokens"`
+	AuditEntries         []*AuditLog            `json:"auditEntries"` // Actions the user performed, newest first
+	Approvals            []*ApprovalRequest     `json:"approvals"`    // Requests the user opened or decided
+	ErasureRequests      []*DataErasureRequest  `json:"erasureRequests"`
+}
+
+// DataErasureStatus represents where an erasure request is in its workflow
+type DataErasureStatus string
+
+const (
+	DataErasureStatusPending   DataErasureStatus = "pending"   // Waiting for an admin to approve it
+	DataErasureStatusApproved  DataErasureStatus = "approved"  // Approved; erasure has not completed yet
+	DataErasureStatusCompleted DataErasureStatus = "completed" // The user's personal data was erased
+	DataErasureStatusRejected  DataErasureStatus = "rejected"
+)
+
+// DataErasureRequest asks for a user's personal data to be erased (GDPR Art. 17). Users request
+// it for themselves or an admin files it for them; another admin approves it before anything is
+// erased.
+type DataErasureRequest struct {
+	ID             uuid.UUID          `json:"id"`
+	OrganizationID uuid.UUID          `json:"organizationId"`
+	UserID         uuid.UUID          `json:"userId"` // The data subject
+	RequestedBy    uuid.UUID          `json:"requestedBy"`
+	Reason         string             `json:"reason,omitempty"`
+	Status         DataErasureStatus  `json:"status"`
+	DecidedBy      *uuid.UUID         `json:"decidedBy,omitempty"`
+	DecidedAt      *time.Time         `json:"decidedAt,omitempty"`
+	DecisionReason string             `json:"decisionReason,omitempty"`
+	Report         *DataErasureReport `json:"report,omitempty"` // Set once erasure completed
+	CreatedAt      time.Time          `json:"createdAt"`
+	CompletedAt    *time.Time         `json:"completedAt,omitempty"`
+}
+
+// DataErasureReport records what erasure changed. Records referring to the user are anonymized
+// rather than deleted: the user row stays as an anonymous placeholder, so references and counts
+// stay intact, and the signatures and hashes of verification events are left untouched.
+type DataErasureReport struct {
+	UserID            uuid.UUID      `json:"userId"`
+	Alias             string         `json:"alias"`             // Address that replaced the user's email
+	RevokedTokens     int            `json:"revokedTokens"`     // SDK and personal access tokens revoked
+	AnonymizedRecords map[string]int `json:"anonymizedRecords"` // Records scrubbed, by table
+	CompletedAt       time.Time      `json:"completedAt"`
+}
+
+// DataErasureRequestRepository defines the interface for data erasure request persistence
+type DataErasureRequestRepository interface {
+	Create(request *DataErasureRequest) error
+	// GetByID returns ErrDataErasureRequestNotFound when no request exists
+	GetByID(id uuid.UUID) (*DataErasureRequest, error)
+	// ListByOrganization returns the organization's requests, newest first; an empty status lists all
+	ListByOrganization(orgID uuid.UUID, status DataErasureStatus, limit, offset int) ([]*DataErasureRequest, error)
+	// ListByUser returns the requests to erase the user's data, newest first
+	ListByUser(userID uuid.UUID) ([]*DataErasureRequest, error)
+	// Decide stores the decision of a pending request and reports whether it was still pending
+	Decide(request *DataErasureRequest) (bool, error)
+	// Complete stores the report of an approved request
+	Complete(request *DataErasureRequest) error
+}
+
+// PersonalDataRepository rewrites the records that hold a user's personal data
+type PersonalDataRepository interface {
+	// Anonymize scrubs the user's profile, and their email, name, IP addresses and user agents from
+	// audit logs, verification events, approval decisions and tombstones, in one transaction. It
+	// returns how many records of each table were changed. Running it again changes nothing more.
+	Anonymize(user *User, alias string) (map[string]int, error)
+}
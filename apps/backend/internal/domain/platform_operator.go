@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OperatorTokenPrefix starts every platform operator token. Operator tokens are only accepted
+// by the operator API; the tenant API's JWT middleware rejects them.
+const OperatorTokenPrefix = "aimop_"
+
+// ImpersonationTokenPrefix starts the session token of a consented impersonation. It differs
+// from the "aim_" prefix of agent API keys, so the API key middleware leaves these tokens alone.
+const ImpersonationTokenPrefix = "aimimp_"
+
+var (
+	// ErrPlatformOperatorNotFound is returned for unknown operators
+	ErrPlatformOperatorNotFound = errors.New("platform operator not found")
+	// ErrOrganizationThrottleNotFound is returned when an organization is not throttled
+	ErrOrganizationThrottleNotFound = errors.New("organization is not throttled")
+	// ErrImpersonationNotFound is returned for unknown impersonation requests and requests of
+	// another organization
+	ErrImpersonationNotFound = errors.New("impersonation request not found")
+)
+
+// OperatorRole is the role of a platform operator. Each role includes the ones before it.
+type OperatorRole string
+
+const (
+	OperatorRoleViewer  OperatorRole = "viewer"  // Organization overviews, job status and the operator log
+	OperatorRoleSupport OperatorRole = "support" // Throttles, job control and impersonation
+	OperatorRoleAdmin   OperatorRole = "admin"   // Manages operators
+)
+
+var operatorRoleRanks = map[OperatorRole]int{
+	OperatorRoleViewer:  1,
+	OperatorRoleSupport: 2,
+	OperatorRoleAdmin:   3,
+}
+
+// IsValid reports whether the role is a known operator role
+func (r OperatorRole) IsValid() bool {
+	_, ok := operatorRoleRanks[r]
+	return ok
+}
+
+// Allows reports whether an operator with this role may act where required is needed
+func (r OperatorRole) Allows(required OperatorRole) bool {
+	return r.IsValid() && operatorRoleRanks[r] >= operatorRoleRanks[required]
+}
+
+// PlatformOperator is a member of the team hosting the deployment. Operators are not users of
+// any organization; they authenticate with their own token on the operator API.
+type PlatformOperator struct {
+	ID          uuid.UUID    `json:"id"`
+	Email       string       `json:"email"`
+	Name        string       `json:"name"`
+	Role        OperatorRole `json:"role"`
+	TokenHash   string       `json:"-"`
+	TokenPrefix string       `json:"tokenPrefix"` // First characters of the token, to recognize it
+	IsActive    bool         `json:"isActive"`
+	LastUsedAt  *time.Time   `json:"lastUsedAt,omitempty"`
+	CreatedBy   *uuid.UUID   `json:"createdBy,omitempty"` // Nil for the bootstrap operator
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+// OperatorAction is an entry of the operator log, which records every change an operator makes
+type OperatorAction struct {
+	ID             uuid.UUID              `json:"id"`
+	OperatorID     uuid.UUID              `json:"operatorId"`
+	Action         string                 `json:"action"` // e.g. "throttle.set", "impersonation.start"
+	OrganizationID *uuid.UUID             `json:"organizationId,omitempty"`
+	Details        map[string]interface{} `json:"details,omitempty"`
+	IPAddress      string                 `json:"ipAddress"`
+	CreatedAt      time.Time              `json:"createdAt"`
+}
+
+// OrganizationThrottle caps every rate limit of an abusive organization, whatever the
+// organization's own settings allow, until it expires or an operator lifts it
+type OrganizationThrottle struct {
+	OrganizationID    uuid.UUID  `json:"organizationId"`
+	RequestsPerMinute int        `json:"requestsPerMinute"`
+	Reason            string     `json:"reason"`
+	ExpiresAt         *time.Time `json:"expiresAt,omitempty"` // Nil until lifted
+	CreatedBy         uuid.UUID  `json:"createdBy"`           // Operator who set the throttle
+	CreatedAt         time.Time  `json:"createdAt"`
+}
+
+// IsActive reports whether the throttle still applies at now
+func (t *OrganizationThrottle) IsActive(now time.Time) bool {
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
+
+// Cap lowers a limit to the throttle; unlimited classes are throttled too
+func (t *OrganizationThrottle) Cap(limit RateLimit) RateLimit {
+	if limit.RequestsPerMinute <= 0 || limit.RequestsPerMinute > t.RequestsPerMinute {
+		limit.RequestsPerMinute = t.RequestsPerMinute
+	}
+	if limit.Burst > limit.RequestsPerMinute {
+		limit.Burst = limit.RequestsPerMinute
+	}
+	return limit
+}
+
+// ImpersonationStatus is the state of an impersonation request
+type ImpersonationStatus string
+
+const (
+	ImpersonationStatusPending  ImpersonationStatus = "pending"  // Waiting for an admin of the organization
+	ImpersonationStatusApproved ImpersonationStatus = "approved" // Consented; the operator has not started the session
+	ImpersonationStatusActive   ImpersonationStatus = "active"   // Session started and not expired
+	ImpersonationStatusDenied   ImpersonationStatus = "denied"
+	ImpersonationStatusRevoked  ImpersonationStatus = "revoked" // Consent withdrawn by an admin
+	ImpersonationStatusEnded    ImpersonationStatus = "ended"   // Ended early by the operator
+	ImpersonationStatusExpired  ImpersonationStatus = "expired"
+)
+
+// ImpersonationRequestTTL is how long a request waits for consent, and how long an approved
+// request waits for the operator to start the session
+const ImpersonationRequestTTL = 24 * time.Hour
+
+// ImpersonationRequest asks an organization's admins to let a platform operator act in the
+// organization. Once an admin approves it, the operator can start one session, which acts as
+// the approving admin with the requested role until it expires or an admin revokes it.
+type ImpersonationRequest struct {
+	ID              uuid.UUID           `json:"id"`
+	OrganizationID  uuid.UUID           `json:"organizationId"`
+	OperatorID      uuid.UUID           `json:"operatorId"`
+	OperatorEmail   string              `json:"operatorEmail"`
+	Reason          string              `json:"reason"`
+	Role            UserRole            `json:"role"`            // Role of the session
+	DurationMinutes int                 `json:"durationMinutes"` // Session length
+	Status          ImpersonationStatus `json:"status"`
+	DecidedBy       *uuid.UUID          `json:"decidedBy,omitempty"` // Admin who approved or denied; the session acts as them
+	DecidedAt       *time.Time          `json:"decidedAt,omitempty"`
+	TokenHash       string              `json:"-"`
+	StartedAt       *time.Time          `json:"startedAt,omitempty"`
+	ExpiresAt       *time.Time          `json:"expiresAt,omitempty"` // End of the session
+	EndedAt         *time.Time          `json:"endedAt,omitempty"`   // Revoked, ended or denied
+	CreatedAt       time.Time           `json:"createdAt"`
+}
+
+// CurrentStatus returns the status at now, with waiting requests and sessions past their time
+// reported as expired
+func (r *ImpersonationRequest) CurrentStatus(now time.Time) ImpersonationStatus {
+	switch r.Status {
+	case ImpersonationStatusPending:
+		if now.Sub(r.CreatedAt) > ImpersonationRequestTTL {
+			return ImpersonationStatusExpired
+		}
+	case ImpersonationStatusApproved:
+		if r.DecidedAt != nil && now.Sub(*r.DecidedAt) > ImpersonationRequestTTL {
+			return ImpersonationStatusExpired
+		}
+	case ImpersonationStatusActive:
+		if r.ExpiresAt != nil && !now.Before(*r.ExpiresAt) {
+			return ImpersonationStatusExpired
+		}
+	}
+	return r.Status
+}
+
+// PlatformOperatorRepository defines the interface for operator, operator log, throttle and
+// impersonation persistence
+type PlatformOperatorRepository interface {
+	CreateOperator(operator *PlatformOperator) error
+	GetOperator(id uuid.UUID) (*PlatformOperator, error)
+	GetOperatorByTokenHash(tokenHash string) (*PlatformOperator, error)
+	// ListOperators returns every operator, oldest first
+	ListOperators() ([]*PlatformOperator, error)
+	UpdateOperator(operator *PlatformOperator) error
+	TouchOperator(id uuid.UUID, usedAt time.Time) error
+
+	RecordAction(action *OperatorAction) error
+	// ListActions returns the operator log, newest first
+	ListActions(limit, offset int) ([]*OperatorAction, error)
+
+	// SetThrottle creates or replaces the organization's throttle
+	SetThrottle(throttle *OrganizationThrottle) error
+	GetThrottle(orgID uuid.UUID) (*OrganizationThrottle, error)
+	ListThrottles() ([]*OrganizationThrottle, error)
+	DeleteThrottle(orgID uuid.UUID) error
+
+	CreateImpersonation(request *ImpersonationRequest) error
+	GetImpersonation(id uuid.UUID) (*ImpersonationRequest, error)
+	GetImpersonationByTokenHash(tokenHash string) (*ImpersonationRequest, error)
+	// ListImpersonations returns requests newest first, of one organization when orgID is set
+	ListImpersonations(orgID *uuid.UUID, limit, offset int) ([]*ImpersonationRequest, error)
+	UpdateImpersonation(request *ImpersonationRequest) error
+}
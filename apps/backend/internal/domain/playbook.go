@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPlaybookNotFound is returned when a playbook does not exist
+	ErrPlaybookNotFound = errors.New("playbook not found")
+	// ErrPlaybookExecutionNotFound is returned when a playbook execution does not exist
+	ErrPlaybookExecutionNotFound = errors.New("playbook execution not found")
+)
+
+// PlaybookActionType is an automated containment action a playbook step performs
+type PlaybookActionType string
+
+const (
+	PlaybookActionQuarantineAgents PlaybookActionType = "quarantine_agents" // Suspend the agents the incident affects
+	PlaybookActionRotateKeys       PlaybookActionType = "rotate_keys"       // Rotate their keys without a grace period
+	PlaybookActionSuppressAlerts   PlaybookActionType = "suppress_alerts"   // Drop further alerts about them for a while
+	PlaybookActionNotifyChannels   PlaybookActionType = "notify_channels"   // Notify notification channels
+	PlaybookActionCreateTicket     PlaybookActionType = "create_ticket"     // Open a ticket in an external tracker
+)
+
+// IsValid reports whether the action is one playbooks can run
+func (a PlaybookActionType) IsValid() bool {
+	switch a {
+	case PlaybookActionQuarantineAgents, PlaybookActionRotateKeys, PlaybookActionSuppressAlerts,
+		PlaybookActionNotifyChannels, PlaybookActionCreateTicket:
+		return true
+	}
+	return false
+}
+
+// PlaybookStep is one action of a playbook. A step requiring approval pauses the execution
+// until a user approves (runs) or rejects (skips) it.
+type PlaybookStep struct {
+	Action            PlaybookActionType `json:"action"`
+	RequiresApproval  bool               `json:"requiresApproval"`
+	ContinueOnFailure bool               `json:"continueOnFailure"` // Otherwise a failed step fails the execution
+	// ChannelIDs are the channels notify_channels dispatches to; empty uses the organization's routing
+	ChannelIDs []uuid.UUID `json:"channelIds,omitempty"`
+	// SuppressMinutes is how long suppress_alerts drops alerts (default 60)
+	SuppressMinutes int `json:"suppressMinutes,omitempty"`
+	// TicketProvider ("jira" or "servicenow") and TicketProject configure create_ticket
+	TicketProvider string `json:"ticketProvider,omitempty"`
+	TicketProject  string `json:"ticketProject,omitempty"`
+}
+
+// Playbook binds incident types to an ordered list of containment steps. An incident of a
+// listed type (any type when empty) at or above MinSeverity starts an execution.
+type Playbook struct {
+	ID             uuid.UUID      `json:"id"`
+	OrganizationID uuid.UUID      `json:"organizationId"`
+	Name           string         `json:"name"`
+	Description    string         `json:"description"`
+	IncidentTypes  []string       `json:"incidentTypes"`
+	MinSeverity    AlertSeverity  `json:"minSeverity,omitempty"`
+	Steps          []PlaybookStep `json:"steps"`
+	IsEnabled      bool           `json:"isEnabled"`
+	CreatedBy      uuid.UUID      `json:"createdBy"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+}
+
+// PlaybookExecutionStatus represents the state of a playbook execution
+type PlaybookExecutionStatus string
+
+const (
+	PlaybookExecutionRunning          PlaybookExecutionStatus = "running"
+	PlaybookExecutionAwaitingApproval PlaybookExecutionStatus = "awaiting_approval"
+	PlaybookExecutionCompleted        PlaybookExecutionStatus = "completed"
+	PlaybookExecutionFailed           PlaybookExecutionStatus = "failed"
+	PlaybookExecutionCancelled        PlaybookExecutionStatus = "cancelled"
+)
+
+// IsFinished reports whether the execution can no longer make progress
+func (s PlaybookExecutionStatus) IsFinished() bool {
+	return s == PlaybookExecutionCompleted || s == PlaybookExecutionFailed || s == PlaybookExecutionCancelled
+}
+
+// PlaybookStepStatus represents the outcome of one step of an execution
+type PlaybookStepStatus string
+
+const (
+	PlaybookStepPending          PlaybookStepStatus = "pending"
+	PlaybookStepAwaitingApproval PlaybookStepStatus = "awaiting_approval"
+	PlaybookStepCompleted        PlaybookStepStatus = "completed"
+	PlaybookStepFailed           PlaybookStepStatus = "failed"
+	PlaybookStepSkipped          PlaybookStepStatus = "skipped"
+)
+
+// PlaybookStepResult is the state of one step of an execution
+type PlaybookStepResult struct {
+	Step        PlaybookStep       `json:"step"`
+	Status      PlaybookStepStatus `json:"status"`
+	Count       int                `json:"count"` // Number of resources acted on
+	Detail      string             `json:"detail,omitempty"`
+	Error       *string            `json:"error,omitempty"`
+	DecidedBy   *uuid.UUID         `json:"decidedBy,omitempty"` // Approver or rejecter of a gated step
+	StartedAt   *time.Time         `json:"startedAt,omitempty"`
+	CompletedAt *time.Time         `json:"completedAt,omitempty"`
+}
+
+// PlaybookLogEntry is one line of an execution's log
+type PlaybookLogEntry struct {
+	Time    time.Time  `json:"time"`
+	Step    *int       `json:"step,omitempty"` // Index of the step the entry is about
+	UserID  *uuid.UUID `json:"userId,omitempty"`
+	Message string     `json:"message"`
+}
+
+// PlaybookExecution is one run of a playbook against an incident. The playbook's steps are
+// copied when the execution starts, so editing a playbook does not change runs in flight.
+type PlaybookExecution struct {
+	ID             uuid.UUID               `json:"id"`
+	OrganizationID uuid.UUID               `json:"organizationId"`
+	PlaybookID     uuid.UUID               `json:"playbookId"`
+	PlaybookName   string                  `json:"playbookName"`
+	IncidentID     uuid.UUID               `json:"incidentId"`
+	Status         PlaybookExecutionStatus `json:"status"`
+	CurrentStep    int                     `json:"currentStep"` // Index of the next step to run
+	Steps          []PlaybookStepResult    `json:"steps"`
+	Log            []PlaybookLogEntry      `json:"log"`
+	TriggeredBy    *uuid.UUID              `json:"triggeredBy,omitempty"` // Nil when started by the incident
+	StartedAt      time.Time               `json:"startedAt"`
+	UpdatedAt      time.Time               `json:"updatedAt"`
+	CompletedAt    *time.Time              `json:"completedAt,omitempty"`
+}
+
+// Ticket is an issue to open in an external ticketing system
+type Ticket struct {
+	Provider    string        `json:"provider"` // "jira" or "servicenow"
+	Project     string        `json:"project"`  // Jira project key or ServiceNow assignment group
+	Summary     string        `json:"summary"`
+	Description string        `json:"description"`
+	Severity    AlertSeverity `json:"severity"`
+	Labels      []string      `json:"labels,omitempty"`
+}
+
+// TicketReference identifies a ticket created in an external ticketing system
+type TicketReference struct {
+	Provider string `json:"provider"`
+	Key      string `json:"key"`
+	ID       string `json:"id,omitempty"` // ServiceNow sys_id
+	URL      string `json:"url"`
+}
+
+// TicketCreator opens tickets in external ticketing systems
+type TicketCreator interface {
+	CreateTicket(ctx context.Context, ticket *Ticket) (*TicketReference, error)
+}
+
+// PlaybookRepository defines the interface for playbook and execution persistence
+type PlaybookRepository interface {
+	CreatePlaybook(playbook *Playbook) error
+	GetPlaybook(id uuid.UUID) (*Playbook, error)
+	// GetPlaybooksByOrganization returns the organization's playbooks, oldest first
+	GetPlaybooksByOrganization(orgID uuid.UUID) ([]*Playbook, error)
+	UpdatePlaybook(playbook *Playbook) error
+	DeletePlaybook(id uuid.UUID) error
+
+	CreateExecution(execution *PlaybookExecution) error
+	GetExecution(id uuid.UUID) (*PlaybookExecution, error)
+	// GetExecutionsByOrganization returns a page of executions, newest first, optionally for
+	// one playbook, and the total count
+	GetExecutionsByOrganization(orgID uuid.UUID, playbookID *uuid.UUID, limit, offset int) ([]*PlaybookExecution, int, error)
+	UpdateExecution(execution *PlaybookExecution) error
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PolicyDecisionPointProvider identifies the external policy engine
+type PolicyDecisionPointProvider string
+
+const (
+	PolicyDecisionPointOPA PolicyDecisionPointProvider = "opa"
+)
+
+// PolicyDecisionPointMode controls whether the external decision is honored
+type PolicyDecisionPointMode string
+
+const (
+	PolicyDecisionPointEnforce  PolicyDecisionPointMode = "enforce"  // External decision replaces the native one
+	PolicyDecisionPointAdvisory PolicyDecisionPointMode = "advisory" // External decision is recorded only (rollout / comparison)
+)
+
+// PolicyDecisionSource records which engine produced the final decision
+type PolicyDecisionSource string
+
+const (
+	PolicyDecisionSourceNative   PolicyDecisionSource = "native"   // Native policies (no PDP or advisory mode)
+	PolicyDecisionSourceExternal PolicyDecisionSource = "external" // External PDP decision honored
+	PolicyDecisionSourceFallback PolicyDecisionSource = "fallback" // External PDP failed; fail-open/closed rule applied
+)
+
+// ExternalPolicyDecisionPoint is an organization's external authorization service (one per organization)
+type ExternalPolicyDecisionPoint struct {
+	ID             uuid.UUID                   `json:"id"`
+	OrganizationID uuid.UUID                   `json:"organizationId"`
+	Provider       PolicyDecisionPointProvider `json:"provider"`
+	Endpoint       string                      `json:"endpoint"`
+	DecisionPath   string                      `json:"decisionPath"` // e.g. "aim/authz" queried at /v1/data/aim/authz
+	AuthToken      string                      `json:"-"`
+	HasAuthToken   bool                        `json:"hasAuthToken"`
+	Mode           PolicyDecisionPointMode     `json:"mode"`
+	FailOpen       bool                        `json:"failOpen"`
+	TimeoutMs      int                         `json:"timeoutMs"`
+	IsEnabled      bool                        `json:"isEnabled"`
+	CreatedBy      uuid.UUID                   `json:"createdBy"`
+	CreatedAt      time.Time                   `json:"createdAt"`
+	UpdatedAt      time.Time                   `json:"updatedAt"`
+}
+
+// ExternalPolicyResult is the decision returned by an external policy decision point
+type ExternalPolicyResult struct {
+	Allowed bool        `json:"allowed"`
+	Reason  string      `json:"reason,omitempty"`
+	Raw     interface{} `json:"raw,omitempty"` // Unmodified OPA result document
+}
+
+// ExternalPolicyEvaluator queries an external policy decision point with an input document
+type ExternalPolicyEvaluator interface {
+	Evaluate(ctx context.Context, pdp *ExternalPolicyDecisionPoint, input map[string]interface{}) (*ExternalPolicyResult, error)
+}
+
+// PolicyDecisionRecord stores an authorization decision where an external PDP was consulted
+type PolicyDecisionRecord struct {
+	ID                 uuid.UUID                `json:"id"`
+	OrganizationID     uuid.UUID                `json:"organizationId"`
+	AgentID            uuid.UUID                `json:"agentId"`
+	AuditID            uuid.UUID                `json:"auditId"`
+	ActionType         string                   `json:"actionType"`
+	Resource           string                   `json:"resource"`
+	NativeAllowed      bool                     `json:"nativeAllowed"`
+	NativeReason       string                   `json:"nativeReason"`
+	PDPID              *uuid.UUID               `json:"pdpId,omitempty"`
+	PDPMode            *PolicyDecisionPointMode `json:"pdpMode,omitempty"`
+	ExternalAllowed    *bool                    `json:"externalAllowed,omitempty"`
+	ExternalReason     *string                  `json:"externalReason,omitempty"`
+	ExternalError      *string                  `json:"externalError,omitempty"`
+	ExternalDurationMs int                      `json:"externalDurationMs"`
+	FinalAllowed       bool                     `json:"finalAllowed"`
+	DecisionSource     PolicyDecisionSource     `json:"decisionSource"`
+	CreatedAt          time.Time                `json:"createdAt"`
+}
+
+// PolicyDecisionRepository defines the interface for external PDP configuration and the decision log
+type PolicyDecisionRepository interface {
+	// GetPDPByOrganization returns nil (and no error) when the organization has no PDP configured
+	GetPDPByOrganization(orgID uuid.UUID) (*ExternalPolicyDecisionPoint, error)
+	UpsertPDP(pdp *ExternalPolicyDecisionPoint) error
+	DeletePDP(orgID uuid.UUID) error
+
+	CreateDecision(decision *PolicyDecisionRecord) error
+	GetDecisionsByOrganization(orgID uuid.UUID, agentID *uuid.UUID, limit, offset int) ([]*PolicyDecisionRecord, int, error)
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// OrganizationSettingRateLimits is the Organization.Settings key holding the organization's
+// rate limits by endpoint class
+const OrganizationSettingRateLimits = "rateLimits"
+
+// RateLimit is a token bucket: a sustained rate plus a burst an idle caller may send on top
+// of it. A zero rate is unlimited.
+type RateLimit struct {
+	RequestsPerMinute int `json:"requestsPerMinute"`
+	Burst             int `json:"burst"`
+}
+
+// DefaultRateLimits are the limits of each endpoint class unless configured otherwise
+var DefaultRateLimits = map[string]RateLimit{
+	EndpointClassRead:         {RequestsPerMinute: 600, Burst: 300},
+	EndpointClassWrite:        {RequestsPerMinute: 120, Burst: 60},
+	EndpointClassVerification: {RequestsPerMinute: 300, Burst: 150},
+}
+
+// OrganizationRateLimits returns the rate limits stored in an organization's settings. Classes
+// missing from the result use the server's limits.
+func OrganizationRateLimits(settings map[string]interface{}) map[string]RateLimit {
+	value, ok := settings[OrganizationSettingRateLimits]
+	if !ok || value == nil {
+		return nil
+	}
+	// Settings read from the database are plain JSON values
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var limits map[string]RateLimit
+	if err := json.Unmarshal(data, &limits); err != nil {
+		return nil
+	}
+	return limits
+}
+
+// RateLimitResult is the state of a token bucket after a request took, or failed to take, a token
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      RateLimit
+	Remaining  int           // Requests that can be sent right now
+	ResetAfter time.Duration // Until the bucket is full again
+	RetryAfter time.Duration // Until the next request is allowed, when rejected
+}
+
+// RateLimitStore keeps the token buckets of rate limited callers
+type RateLimitStore interface {
+	// Take takes a token from the bucket under key, creating a full bucket for a new key
+	Take(ctx context.Context, key string, limit RateLimit) (*RateLimitResult, error)
+}
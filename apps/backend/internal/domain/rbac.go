@@ -0,0 +1,209 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrCustomRoleNotFound is returned when a custom role does not exist
+	ErrCustomRoleNotFound = errors.New("custom role not found")
+	// ErrCustomRoleNameTaken is returned when the organization already has a role of the name
+	ErrCustomRoleNameTaken = errors.New("a role with this name already exists")
+)
+
+// Permission is a granular action that routes require instead of a fixed role
+type Permission string
+
+const (
+	PermissionAgentsCreate              Permission = "agents:create"
+	PermissionAgentsUpdate              Permission = "agents:update"
+	PermissionAgentsDelete              Permission = "agents:delete"
+	PermissionAgentsLifecycle           Permission = "agents:lifecycle"
+	PermissionAgentsImportExport        Permission = "agents:import_export"
+	PermissionTrustScoresOverride       Permission = "trust_scores:override"
+	PermissionPeerPoliciesManage        Permission = "peer_policies:manage"
+	PermissionCapabilitiesGrant         Permission = "capabilities:grant"
+	PermissionCapabilitiesRenew         Permission = "capabilities:renew"
+	PermissionCapabilityRequestsApprove Permission = "capability_requests:approve"
+	PermissionAPIKeysManage             Permission = "api_keys:manage"
+	PermissionMCPServersWrite           Permission = "mcp_servers:write"
+	PermissionMCPServersManage          Permission = "mcp_servers:manage"
+	PermissionTagsWrite                 Permission = "tags:write"
+	PermissionTagsDelete                Permission = "tags:delete"
+	PermissionVerificationEventsCreate  Permission = "verification_events:create"
+	PermissionVerificationEventsDelete  Permission = "verification_events:delete"
+	PermissionVerificationsApprove      Permission = "verifications:approve"
+	PermissionSecurityManage            Permission = "security:manage"
+	PermissionSharedKeysManage          Permission = "shared_keys:manage"
+	PermissionSecurityPoliciesManage    Permission = "security_policies:manage"
+	PermissionAlertsManage              Permission = "alerts:manage"
+	PermissionApprovalsDecide           Permission = "approvals:decide"
+	PermissionChangeRequestsCreate      Permission = "change_requests:create"
+	PermissionChangeRequestsApprove     Permission = "change_requests:approve"
+	PermissionPlaybooksManage           Permission = "playbooks:manage"
+	PermissionWebhooksWrite             Permission = "webhooks:write"
+	PermissionWebhookEgressManage       Permission = "webhook_egress:manage"
+	PermissionNotificationsManage       Permission = "notifications:manage"
+	PermissionNotificationsResend       Permission = "notifications:resend"
+	PermissionReportsManage             Permission = "reports:manage"
+	PermissionReportsRun                Permission = "reports:run"
+	PermissionIntegrationsManage        Permission = "integrations:manage"
+	PermissionComplianceRead            Permission = "compliance:read"
+	PermissionAuditLogsRead             Permission = "audit_logs:read"
+	PermissionEmergencyAccessManage     Permission = "emergency_access:manage"
+	PermissionUsersManage               Permission = "users:manage"
+	PermissionRolesManage               Permission = "roles:manage"
+	PermissionPrivacyManage             Permission = "privacy:manage"
+	PermissionOrganizationManage        Permission = "organization:manage"
+	PermissionSystemManage              Permission = "system:manage"
+)
+
+// PermissionDefinition describes a permission of the catalog and the least built-in role that
+// has it: admins have every permission, managers those of managers and members, and so on
+type PermissionDefinition struct {
+	Permission  Permission `json:"permission"`
+	Description string     `json:"description"`
+	MinimumRole UserRole   `json:"minimumRole"`
+}
+
+// PermissionCatalog lists every permission, the routes each one opens and its built-in default
+var PermissionCatalog = []PermissionDefinition{
+	{PermissionAgentsCreate, "Register agents", RoleMember},
+	{PermissionAgentsUpdate, "Edit agents: keys, credentials, MCP server mappings and SBOMs", RoleMember},
+	{PermissionAgentsDelete, "Delete agents", RoleManager},
+	{PermissionAgentsLifecycle, "Verify, suspend, reactivate, compromise and revoke agents, list them publicly and recalculate trust scores", RoleManager},
+	{PermissionAgentsImportExport, "Export and import agent identities", RoleAdmin},
+	{PermissionTrustScoresOverride, "Set an agent's trust score manually", RoleAdmin},
+	{PermissionPeerPoliciesManage, "Manage agent-to-agent peer policies", RoleManager},
+	{PermissionCapabilitiesGrant, "Grant and revoke agent capabilities", RoleManager},
+	{PermissionCapabilitiesRenew, "Request the renewal of expiring capabilities", RoleMember},
+	{PermissionCapabilityRequestsApprove, "Review, approve and reject capability requests", RoleAdmin},
+	{PermissionAPIKeysManage, "Create, disable and delete API keys", RoleMember},
+	{PermissionMCPServersWrite, "Register and edit MCP servers, attest them and check their health", RoleMember},
+	{PermissionMCPServersManage, "Delete and verify MCP servers, classify capability risk, manage relays, latency SLOs and criticality", RoleManager},
+	{PermissionTagsWrite, "Create and edit tags and tag agents and MCP servers", RoleMember},
+	{PermissionTagsDelete, "Delete tags", RoleManager},
+	{PermissionVerificationEventsCreate, "Record verification events", RoleMember},
+	{PermissionVerificationEventsDelete, "Delete verification events", RoleManager},
+	{PermissionVerificationsApprove, "Approve and deny pending verifications", RoleAdmin},
+	{PermissionSecurityManage, "Security dashboard, threats, anomalies, shared keys and incidents", RoleManager},
+	{PermissionSharedKeysManage, "Allowlist keys shared by several agents", RoleAdmin},
+	{PermissionSecurityPoliciesManage, "Security, naming, trust boundary, compromise response, attestation cadence and external decision policies, and approval chains", RoleAdmin},
+	{PermissionAlertsManage, "Acknowledge and resolve alerts, decide drift remediations and manage alert suppression rules", RoleAdmin},
+	{PermissionApprovalsDecide, "Decide actions waiting on an approval chain", RoleManager},
+	{PermissionChangeRequestsCreate, "Propose change requests and cancel one's own", RoleMember},
+	{PermissionChangeRequestsApprove, "Approve, reject, cancel and roll back change requests", RoleManager},
+	{PermissionPlaybooksManage, "Manage and run response playbooks and decide their steps", RoleManager},
+	{PermissionWebhooksWrite, "Create, edit and delete webhooks", RoleMember},
+	{PermissionWebhookEgressManage, "Set the webhook egress settings", RoleAdmin},
+	{PermissionNotificationsManage, "Manage notification channels and routes", RoleManager},
+	{PermissionNotificationsResend, "Resend notifications", RoleMember},
+	{PermissionReportsManage, "Manage report subscriptions", RoleManager},
+	{PermissionReportsRun, "Generate and deliver subscribed reports now", RoleMember},
+	{PermissionIntegrationsManage, "Manage ticketing connectors and SIEM exporters", RoleAdmin},
+	{PermissionComplianceRead, "Compliance status, metrics, checks, access reviews and exports", RoleAdmin},
+	{PermissionAuditLogsRead, "Audit logs, tombstones of deleted resources and entitlement reviews", RoleAdmin},
+	{PermissionEmergencyAccessManage, "Generate and revoke emergency credentials", RoleAdmin},
+	{PermissionUsersManage, "Approve, deactivate and delete users, change their built-in role, and manage their tokens, MFA and impersonation consent", RoleAdmin},
+	{PermissionRolesManage, "Define custom roles and assign them to users (which can grant any permission)", RoleAdmin},
+	{PermissionPrivacyManage, "Export users' personal data, file erasure requests for them and approve or reject erasure requests", RoleAdmin},
+	{PermissionOrganizationManage, "Organization settings, sub-organizations, preview features and SAML", RoleAdmin},
+	{PermissionSystemManage, "Admin dashboard, dependency health, schema status and demo data", RoleAdmin},
+}
+
+// roleRank orders the built-in roles from the least to the most privileged
+var roleRank = map[UserRole]int{
+	RoleViewer:  1,
+	RoleMember:  2,
+	RoleManager: 3,
+	RoleAdmin:   4,
+}
+
+// IsPermission reports whether p is in the catalog
+func IsPermission(p Permission) bool {
+	for _, definition := range PermissionCatalog {
+		if definition.Permission == p {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionSet is the set of permissions a user holds
+type PermissionSet map[Permission]bool
+
+// Has reports whether the set holds p
+func (s PermissionSet) Has(p Permission) bool {
+	return s[p]
+}
+
+// List returns the permissions of the set in catalog order
+func (s PermissionSet) List() []Permission {
+	permissions := make([]Permission, 0, len(s))
+	for _, definition := range PermissionCatalog {
+		if s[definition.Permission] {
+			permissions = append(permissions, definition.Permission)
+		}
+	}
+	return permissions
+}
+
+// BuiltinRolePermissions returns the default permissions of a built-in role; viewers only read,
+// so they have none
+func BuiltinRolePermissions(role UserRole) PermissionSet {
+	set := make(PermissionSet)
+	rank, ok := roleRank[role]
+	if !ok {
+		return set
+	}
+	for _, definition := range PermissionCatalog {
+		if rank >= roleRank[definition.MinimumRole] {
+			set[definition.Permission] = true
+		}
+	}
+	return set
+}
+
+// CustomRole is an organization-defined set of permissions. Users keep their built-in role and
+// gain the permissions of every custom role assigned to them, e.g. a member who may also approve
+// capability requests without being able to manage users.
+type CustomRole struct {
+	ID             uuid.UUID    `json:"id"`
+	OrganizationID uuid.UUID    `json:"organizationId"`
+	Name           string       `json:"name"`
+	Description    string       `json:"description"`
+	Permissions    []Permission `json:"permissions"`
+	CreatedBy      *uuid.UUID   `json:"createdBy,omitempty"`
+	CreatedAt      time.Time    `json:"createdAt"`
+	UpdatedAt      time.Time    `json:"updatedAt"`
+	UserCount      int          `json:"userCount"` // Users the role is assigned to
+}
+
+// CustomRoleAssignment assigns a custom role to a user
+type CustomRoleAssignment struct {
+	RoleID     uuid.UUID  `json:"roleId"`
+	UserID     uuid.UUID  `json:"userId"`
+	AssignedBy *uuid.UUID `json:"assignedBy,omitempty"`
+	AssignedAt time.Time  `json:"assignedAt"`
+}
+
+// CustomRoleRepository stores custom roles and their assignments
+type CustomRoleRepository interface {
+	Create(role *CustomRole) error
+	GetByID(id uuid.UUID) (*CustomRole, error)
+	// ListByOrganization returns the organization's roles by name, with their user counts
+	ListByOrganization(orgID uuid.UUID) ([]*CustomRole, error)
+	Update(role *CustomRole) error
+	// Delete removes the role and its assignments
+	Delete(id uuid.UUID) error
+	// Assign assigns the role to the user; assigning it again changes nothing
+	Assign(assignment *CustomRoleAssignment) error
+	Unassign(roleID, userID uuid.UUID) error
+	// ListUsers returns the role's assignments, oldest first
+	ListUsers(roleID uuid.UUID) ([]*CustomRoleAssignment, error)
+	// ListForUser returns the custom roles assigned to the user
+	ListForUser(userID uuid.UUID) ([]*CustomRole, error)
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReportType identifies the dataset a scheduled report is built from
+type ReportType string
+
+const (
+	ReportTypeSecurityPosture     ReportType = "security_posture"     // Open alerts by severity and type
+	ReportTypeVerificationSummary ReportType = "verification_summary" // Verification outcomes per agent / MCP server
+	ReportTypeDriftDigest         ReportType = "drift_digest"         // Configuration drift detected during verification
+	ReportTypeTrustChanges        ReportType = "trust_changes"        // Trust score changes per agent
+)
+
+// ReportCadence is how often a subscription is generated
+type ReportCadence string
+
+const (
+	ReportCadenceDaily   ReportCadence = "daily"
+	ReportCadenceWeekly  ReportCadence = "weekly"
+	ReportCadenceMonthly ReportCadence = "monthly"
+)
+
+// ReportFormat is the file format a report is rendered to
+type ReportFormat string
+
+const (
+	ReportFormatCSV ReportFormat = "csv"
+	ReportFormatPDF ReportFormat = "pdf"
+)
+
+// ReportDelivery controls how the rendered file reaches the channel
+type ReportDelivery string
+
+const (
+	ReportDeliveryInline ReportDelivery = "inline" // File content embedded in the notification
+	ReportDeliveryLink   ReportDelivery = "link"   // Notification carries an expiring download URL
+)
+
+// ReportRunStatus is the outcome of a single report generation
+type ReportRunStatus string
+
+const (
+	ReportRunSucceeded ReportRunStatus = "succeeded"
+	ReportRunFailed    ReportRunStatus = "failed"
+)
+
+// ReportSubscription is a recurring report pushed to notification channels
+type ReportSubscription struct {
+	ID             uuid.UUID      `json:"id"`
+	OrganizationID uuid.UUID      `json:"organizationId"`
+	Name           string         `json:"name"`
+	ReportType     ReportType     `json:"reportType"`
+	Cadence        ReportCadence  `json:"cadence"`
+	Format         ReportFormat   `json:"format"`
+	Delivery       ReportDelivery `json:"delivery"`
+	ChannelIDs     []uuid.UUID    `json:"channelIds"`
+	IsActive       bool           `json:"isActive"`
+	LastRunAt      *time.Time     `json:"lastRunAt,omitempty"`
+	NextRunAt      time.Time      `json:"nextRunAt"`
+	CreatedBy      uuid.UUID      `json:"createdBy"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+}
+
+// ReportRun records one generation of a subscription. Content is kept so link
+// deliveries can be downloaded until the run expires; when object storage is configured
+// it is kept there under ArtifactName instead.
+type ReportRun struct {
+	ID             uuid.UUID       `json:"id"`
+	SubscriptionID uuid.UUID       `json:"subscriptionId"`
+	OrganizationID uuid.UUID       `json:"organizationId"`
+	Status         ReportRunStatus `json:"status"`
+	PeriodStart    time.Time       `json:"periodStart"`
+	PeriodEnd      time.Time       `json:"periodEnd"`
+	Format         ReportFormat    `json:"format"`
+	Filename       string          `json:"filename,omitempty"`
+	ContentType    string          `json:"contentType,omitempty"`
+	Content        []byte          `json:"-"`
+	ArtifactName   string          `json:"-"`
+	RowCount       int             `json:"rowCount"`
+	NotificationID *uuid.UUID      `json:"notificationId,omitempty"`
+	DownloadToken  string          `json:"-"`
+	ExpiresAt      *time.Time      `json:"expiresAt,omitempty"`
+	Error          *string         `json:"error,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+// ReportMetric is a headline figure shown above the report table
+type ReportMetric struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// ReportTable is the format-independent content of a report
+type ReportTable struct {
+	Title   string         `json:"title"`
+	Metrics []ReportMetric `json:"metrics"`
+	Columns []string       `json:"columns"`
+	Rows    [][]string     `json:"rows"`
+}
+
+// ReportRenderer renders a report table into a file format
+type ReportRenderer interface {
+	Format() ReportFormat
+	ContentType() string
+	Render(table *ReportTable, periodStart, periodEnd time.Time) ([]byte, error)
+}
+
+// ReportRepository defines the interface for report subscription persistence and report data queries
+type ReportRepository interface {
+	// Subscriptions
+	CreateSubscription(subscription *ReportSubscription) error
+	GetSubscriptionByID(id uuid.UUID) (*ReportSubscription, error)
+	GetSubscriptionsByOrganization(orgID uuid.UUID) ([]*ReportSubscription, error)
+	UpdateSubscription(subscription *ReportSubscription) error
+	DeleteSubscription(id uuid.UUID) error
+
+	// Scheduling - claimed subscriptions have next_run_at pushed out by the lease
+	ClaimDueSubscriptions(limit int, lease time.Duration) ([]*ReportSubscription, error)
+	UpdateSchedule(id uuid.UUID, lastRunAt, nextRunAt time.Time) error
+
+	// Runs
+	CreateRun(run *ReportRun) error
+	GetRunByID(id uuid.UUID) (*ReportRun, error)
+	GetRunByDownloadToken(token string) (*ReportRun, error)
+	GetRunsBySubscription(subscriptionID uuid.UUID, limit int) ([]*ReportRun, error)
+
+	// Report data for the [start, end) period
+	GetSecurityPostureData(orgID uuid.UUID, start, end time.Time) (*ReportTable, error)
+	GetVerificationSummaryData(orgID uuid.UUID, start, end time.Time) (*ReportTable, error)
+	GetDriftDigestData(orgID uuid.UUID, start, end time.Time) (*ReportTable, error)
+	GetTrustChangesData(orgID uuid.UUID, start, end time.Time) (*ReportTable, error)
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Downstream dependencies whose calls are held to a timeout budget
+const (
+	DependencyDatabase = "database" // Enforced by PostgreSQL as the connections' statement timeout
+	DependencyHTTP     = "http"     // Outbound HTTP probes: MCP server verification and capability discovery
+)
+
+// TimeoutBudgets are how long a request may take, by endpoint class, and how long a single
+// call to each dependency may take within it. A zero budget is unlimited.
+type TimeoutBudgets struct {
+	Requests     map[string]time.Duration
+	Dependencies map[string]time.Duration
+}
+
+// DefaultTimeoutBudgets are the budgets unless configured otherwise
+var DefaultTimeoutBudgets = TimeoutBudgets{
+	Requests: map[string]time.Duration{
+		EndpointClassRead:         15 * time.Second,
+		EndpointClassWrite:        30 * time.Second,
+		EndpointClassVerification: 5 * time.Second,
+	},
+	Dependencies: map[string]time.Duration{
+		DependencyDatabase: 30 * time.Second,
+		DependencyHTTP:     10 * time.Second,
+	},
+}
+
+// DependencyTimeoutError reports a dependency that did not respond within its budget
+type DependencyTimeoutError struct {
+	Dependency string
+	Budget     time.Duration // The time the call had; the rest of the request's budget when that was shorter
+	Err        error
+}
+
+func (e *DependencyTimeoutError) Error() string {
+	return fmt.Sprintf("%s did not respond within %s: %v", e.Dependency, e.Budget, e.Err)
+}
+
+func (e *DependencyTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+type requestBudgetKey struct{}
+
+// requestBudget is the dependency budgets of a request and the first dependency that exceeded its budget
+type requestBudget struct {
+	dependencies map[string]time.Duration
+	exceeded     *DependencyTimeoutError
+	mu           sync.Mutex
+}
+
+// WithDependencyBudgets returns a context holding calls to each dependency to its budget. A
+// context that already holds budgets is returned as is.
+func WithDependencyBudgets(ctx context.Context, budgets map[string]time.Duration) context.Context {
+	if _, ok := ctx.Value(requestBudgetKey{}).(*requestBudget); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, requestBudgetKey{}, &requestBudget{dependencies: budgets})
+}
+
+// ExceededDependency returns the first dependency call made with the context, or a context
+// derived from it, that ran out of time; nil when none did
+func ExceededDependency(ctx context.Context) *DependencyTimeoutError {
+	budget, ok := ctx.Value(requestBudgetKey{}).(*requestBudget)
+	if !ok {
+		return nil
+	}
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+	return budget.exceeded
+}
+
+// CallDependency calls a dependency with a context limited to the dependency's budget, on top
+// of any deadline the context already has. A call that runs out of time returns a
+// *DependencyTimeoutError, which is also recorded for ExceededDependency.
+func CallDependency(ctx context.Context, dependency string, call func(ctx context.Context) error) error {
+	budget, _ := ctx.Value(requestBudgetKey{}).(*requestBudget)
+	callCtx, cancel := ctx, context.CancelFunc(func() {})
+	if budget != nil && budget.dependencies[dependency] > 0 {
+		callCtx, cancel = context.WithTimeout(ctx, budget.dependencies[dependency])
+	}
+	defer cancel()
+
+	start := time.Now()
+	err := call(callCtx)
+	if err == nil || !errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+
+	timeout := &DependencyTimeoutError{Dependency: dependency, Err: err}
+	if deadline, ok := callCtx.Deadline(); ok {
+		timeout.Budget = deadline.Sub(start).Round(time.Millisecond)
+	}
+	if budget != nil {
+		budget.mu.Lock()
+		if budget.exceeded == nil {
+			budget.exceeded = timeout
+		}
+		budget.mu.Unlock()
+	}
+	return timeout
+}
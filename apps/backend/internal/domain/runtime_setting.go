@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RuntimeSetting is an operator's value for an operational knob, applied by every instance
+// without a restart. Knobs without a stored value keep the value configured at startup.
+type RuntimeSetting struct {
+	Key       string     `json:"key"`
+	Value     string     `json:"value"`
+	UpdatedBy *uuid.UUID `json:"updatedBy,omitempty"` // Platform operator
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// RuntimeSettingChange records a change of a runtime setting
+type RuntimeSettingChange struct {
+	ID            uuid.UUID  `json:"id"`
+	Key           string     `json:"key"`
+	PreviousValue *string    `json:"previousValue"` // Nil when the startup value was in effect
+	Value         *string    `json:"value"`         // Nil when the setting was reset to the startup value
+	Reason        string     `json:"reason"`
+	ChangedBy     *uuid.UUID `json:"changedBy,omitempty"` // Platform operator
+	IPAddress     string     `json:"ipAddress"`
+	ChangedAt     time.Time  `json:"changedAt"`
+}
+
+// RuntimeSettingRepository stores runtime settings and their history
+type RuntimeSettingRepository interface {
+	List() ([]*RuntimeSetting, error)
+	// Set creates or replaces the setting and records the change in one transaction
+	Set(setting *RuntimeSetting, change *RuntimeSettingChange) error
+	// Delete removes the setting, if stored, and records the change in one transaction
+	Delete(key string, change *RuntimeSettingChange) error
+	// ListChanges returns the changes of the setting, or of every setting when key is empty,
+	// newest first
+	ListChanges(key string, limit, offset int) ([]*RuntimeSettingChange, error)
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserProviderSAML marks users who sign in through their organization's SAML identity provider
+const UserProviderSAML = "saml"
+
+// SAMLConfig configures SAML 2.0 single sign-on for an organization. AIM is the service
+// provider; the identity provider (Okta, Entra ID, ADFS, ...) signs the assertions.
+type SAMLConfig struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	Enabled        bool      `json:"enabled"`
+	IdPEntityID    string    `json:"idpEntityId"`
+	IdPSSOURL      string    `json:"idpSsoUrl"`      // HTTP-Redirect single sign-on endpoint
+	IdPCertificate string    `json:"idpCertificate"` // PEM or base64 DER signing certificate
+
+	// Attribute names read from the assertion; an empty EmailAttribute uses the NameID
+	EmailAttribute string `json:"emailAttribute"`
+	NameAttribute  string `json:"nameAttribute"`
+	RoleAttribute  string `json:"roleAttribute"`
+	// RoleMappings maps values of the role attribute (e.g. IdP group names) to AIM roles.
+	// When several values match, the highest role wins; no match yields DefaultRole.
+	RoleMappings map[string]UserRole `json:"roleMappings"`
+	DefaultRole  UserRole            `json:"defaultRole"`
+
+	// JITProvisioning creates unknown users on their first SAML login
+	JITProvisioning bool       `json:"jitProvisioning"`
+	UpdatedBy       *uuid.UUID `json:"updatedBy,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// SAMLConfigRepository defines the interface for SAML configuration persistence
+type SAMLConfigRepository interface {
+	// GetByOrganization returns the organization's configuration, or nil if none is configured
+	GetByOrganization(orgID uuid.UUID) (*SAMLConfig, error)
+	Upsert(config *SAMLConfig) error
+	Delete(orgID uuid.UUID) error
+}
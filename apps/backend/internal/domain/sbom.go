@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SBOMFormat is the standard an SBOM document follows
+type SBOMFormat string
+
+const (
+	SBOMFormatSPDX      SBOMFormat = "spdx"
+	SBOMFormatCycloneDX SBOMFormat = "cyclonedx"
+)
+
+// SBOMScanStatus is the state of an SBOM's vulnerability scan
+type SBOMScanStatus string
+
+const (
+	SBOMScanStatusPending SBOMScanStatus = "pending" // Stored, not scanned yet
+	SBOMScanStatusScanned SBOMScanStatus = "scanned"
+	SBOMScanStatusFailed  SBOMScanStatus = "failed" // The vulnerability database could not be queried
+)
+
+// VulnerabilitySeverity is the normalized severity of a known vulnerability
+type VulnerabilitySeverity string
+
+const (
+	VulnerabilitySeverityCritical VulnerabilitySeverity = "critical"
+	VulnerabilitySeverityHigh     VulnerabilitySeverity = "high"
+	VulnerabilitySeverityMedium   VulnerabilitySeverity = "medium"
+	VulnerabilitySeverityLow      VulnerabilitySeverity = "low"
+	VulnerabilitySeverityUnknown  VulnerabilitySeverity = "unknown"
+)
+
+// SBOMComponent is a package listed in an SBOM
+type SBOMComponent struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"` // Package URL; components without one cannot be scanned
+}
+
+// SBOMVulnerability is a known vulnerability affecting a component of an SBOM
+type SBOMVulnerability struct {
+	ID               string                `json:"id"` // OSV identifier, e.g. GHSA-xxxx or PYSEC-xxxx
+	Aliases          []string              `json:"aliases,omitempty"`
+	Summary          string                `json:"summary,omitempty"`
+	Severity         VulnerabilitySeverity `json:"severity"`
+	Score            *float64              `json:"score,omitempty"` // CVSS v3 base score when one is published
+	ComponentName    string                `json:"componentName"`
+	ComponentVersion string                `json:"componentVersion,omitempty"`
+	PURL             string                `json:"purl"`
+}
+
+// AgentSBOM is a software bill of materials uploaded for a version of an agent, together
+// with the known vulnerabilities of its components
+type AgentSBOM struct {
+	ID                 uuid.UUID           `json:"id"`
+	OrganizationID     uuid.UUID           `json:"organizationId"`
+	AgentID            uuid.UUID           `json:"agentId"`
+	AgentVersion       string              `json:"agentVersion"`
+	Format             SBOMFormat          `json:"format"`
+	SpecVersion        string              `json:"specVersion"`
+	SourceURL          *string             `json:"sourceUrl,omitempty"` // Set when the SBOM was fetched from a link
+	DocumentHash       string              `json:"documentHash"`        // SHA-256 of the document
+	Document           json.RawMessage     `json:"-"`
+	Components         []SBOMComponent     `json:"components"`
+	ComponentCount     int                 `json:"componentCount"`
+	Vulnerabilities    []SBOMVulnerability `json:"vulnerabilities"`
+	VulnerabilityCount int                 `json:"vulnerabilityCount"`
+	CriticalCount      int                 `json:"criticalCount"`
+	HighCount          int                 `json:"highCount"`
+	ScanStatus         SBOMScanStatus      `json:"scanStatus"`
+	ScanError          *string             `json:"scanError,omitempty"`
+	ScannedAt          *time.Time          `json:"scannedAt,omitempty"`
+	UploadedBy         *uuid.UUID          `json:"uploadedBy,omitempty"` // Nil when uploaded by the agent itself
+	CreatedAt          time.Time           `json:"createdAt"`
+}
+
+// AgentSBOMRepository defines the interface for SBOM persistence
+type AgentSBOMRepository interface {
+	Create(sbom *AgentSBOM) error
+	GetByID(id uuid.UUID) (*AgentSBOM, error)
+	// GetByAgent lists an agent's SBOMs newest first, without their documents
+	GetByAgent(agentID uuid.UUID) ([]*AgentSBOM, error)
+	// GetLatestByAgent returns the agent's most recently uploaded SBOM
+	GetLatestByAgent(agentID uuid.UUID) (*AgentSBOM, error)
+	// UpdateScan stores the scan status, error, vulnerabilities and counts of an SBOM
+	UpdateScan(sbom *AgentSBOM) error
+	// GetDueForRescan returns the latest SBOM of each agent that was never scanned or last scanned before the cutoff
+	GetDueForRescan(scannedBefore time.Time, limit int) ([]*AgentSBOM, error)
+}
+
+// VulnerabilityDatabase looks up the known vulnerabilities of SBOM components
+type VulnerabilityDatabase interface {
+	QueryComponents(ctx context.Context, components []SBOMComponent) ([]SBOMVulnerability, error)
+}
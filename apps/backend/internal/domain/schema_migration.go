@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SchemaMigration is a migration file and, once applied, how it was applied
+type SchemaMigration struct {
+	Version    string     `json:"version"` // The migration's name, e.g. 075_create_agent_peer_policies
+	Online     bool       `json:"online"`  // Applied statement by statement outside a transaction (-- aim:online)
+	Checksum   string     `json:"checksum,omitempty"`
+	AppliedAt  *time.Time `json:"appliedAt,omitempty"`
+	DurationMs int64      `json:"durationMs,omitempty"`
+	// Modified reports that the file changed after it was applied; applied files must not be edited
+	Modified bool `json:"modified,omitempty"`
+	// Missing reports an applied migration whose file is no longer shipped
+	Missing bool `json:"missing,omitempty"`
+}
+
+// SchemaStatus is the state of the database schema compared to the migrations this build ships
+type SchemaStatus struct {
+	CurrentVersion string            `json:"currentVersion"` // Migration at the schema version; empty for an empty database
+	LatestVersion  string            `json:"latestVersion"`  // Latest migration this build ships
+	UpToDate       bool              `json:"upToDate"`
+	Dirty          bool              `json:"dirty"`     // The migration at the current version failed part way; fix it and run cmd/migrate force
+	AutoApply      bool              `json:"autoApply"` // Whether the server applies pending migrations on startup
+	Pending        []SchemaMigration `json:"pending"`   // Oldest first, in the order they will be applied
+	History        []SchemaMigration `json:"history"`   // Applied migrations, newest first
+}
+
+// SchemaMigrator reports and applies database schema migrations
+type SchemaMigrator interface {
+	Status(ctx context.Context) (*SchemaStatus, error)
+	// Apply applies the pending migrations in order and returns those it applied
+	Apply(ctx context.Context) ([]SchemaMigration, error)
+}
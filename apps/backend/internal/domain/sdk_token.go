@@ -53,6 +53,22 @@ func (t *SDKToken) RecordUsage(ipAddress string) {
 	t.UsageCount++
 }
 
+// SDKDevice is a machine running the SDK: the active tokens downloaded to it, and rotated from
+// those, share its fingerprint
+type SDKDevice struct {
+	ID                string      `json:"id"` // The device fingerprint; the token's ID for tokens without one
+	DeviceName        *string     `json:"deviceName,omitempty"`
+	DeviceFingerprint *string     `json:"deviceFingerprint,omitempty"`
+	UserAgent         *string     `json:"userAgent,omitempty"`
+	FirstSeenAt       time.Time   `json:"firstSeenAt"`
+	LastUsedAt        *time.Time  `json:"lastUsedAt,omitempty"`
+	LastIPAddress     *string     `json:"lastIpAddress,omitempty"`
+	LastLocation      *IPLocation `json:"lastLocation,omitempty"` // Where LastIPAddress is
+	UsageCount        int         `json:"usageCount"`
+	ActiveTokens      int         `json:"activeTokens"`
+	ExpiresAt         time.Time   `json:"expiresAt"` // When the device's last token expires
+}
+
 // SDKTokenRepository defines the interface for SDK token persistence
 type SDKTokenRepository interface {
 	// Create stores a new SDK token
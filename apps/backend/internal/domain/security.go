@@ -27,6 +27,7 @@ const (
 	AnomalyTypeUnexpectedLocation   AnomalyType = "unexpected_location"
 	AnomalyTypeRateLimitViolation   AnomalyType = "rate_limit_violation"
 	AnomalyTypeUnusualAccessPattern AnomalyType = "unusual_access_pattern"
+	AnomalyTypeCapabilityMismatch   AnomalyType = "capability_mismatch" // Attestations disagree with the MCP server's capabilities
 )
 
 // IncidentStatus represents the status of a security incident
@@ -83,6 +84,7 @@ type SecurityIncident struct {
 	AssignedTo        *uuid.UUID     `json:"assignedTo"`
 	CreatedAt         time.Time      `json:"createdAt"`
 	UpdatedAt         time.Time      `json:"updatedAt"`
+	AcknowledgedAt    *time.Time     `json:"acknowledgedAt"` // When the incident first left open
 	ResolvedAt        *time.Time     `json:"resolvedAt"`
 	ResolvedBy        *uuid.UUID     `json:"resolvedBy"`
 	ResolutionNotes   string         `json:"resolutionNotes"`
@@ -128,6 +130,51 @@ type SecurityScanResult struct {
 	CompletedAt          *time.Time `json:"completedAt"`
 }
 
+// ThreatQueryParams defines filters, sorting and pagination for threat searches.
+// Threats are read from alerts, so threat types are matched through the alert types
+// that map to them and an acknowledged alert counts as blocked, as in SecurityMetrics.
+type ThreatQueryParams struct {
+	Severities  []AlertSeverity
+	ThreatTypes []ThreatType // Resolved to AlertTypes by the security service
+	AlertTypes  []AlertType
+	Blocked     *bool
+	TargetType  string
+	TargetID    *uuid.UUID
+	From        *time.Time
+	To          *time.Time
+	Search      string // Case-insensitive match on title and description
+	SortBy      string // created_at (default), severity or title
+	SortOrder   string // desc (default) or asc
+	Limit       int
+	Offset      int
+}
+
+// AnomalyQueryParams defines filters, sorting and pagination for anomaly searches
+type AnomalyQueryParams struct {
+	Severities    []AlertSeverity
+	AnomalyTypes  []AnomalyType
+	ResourceType  string
+	ResourceID    *uuid.UUID
+	MinConfidence float64
+	From          *time.Time
+	To            *time.Time
+	Search        string // Case-insensitive match on title and description
+	SortBy        string // created_at (default), severity, confidence or title
+	SortOrder     string // desc (default) or asc
+	Limit         int
+	Offset        int
+}
+
+// ThreatAnnotation is a triage note left on a threat by an analyst
+type ThreatAnnotation struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	ThreatID       uuid.UUID `json:"threatId"`
+	UserID         uuid.UUID `json:"userId"`
+	Note           string    `json:"note"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
 // SecurityRepository defines the interface for security persistence
 type SecurityRepository interface {
 	// Threats
@@ -137,16 +184,33 @@ type SecurityRepository interface {
 	BlockThreat(id uuid.UUID) error
 	ResolveThreat(id uuid.UUID) error
 
+	// SearchThreatAlerts returns the alerts behind a page of threats and the total matching the filters
+	SearchThreatAlerts(orgID uuid.UUID, params ThreatQueryParams) ([]*Alert, int, error)
+
+	// Threat annotations
+	CreateThreatAnnotation(annotation *ThreatAnnotation) error
+	GetThreatAnnotations(threatID uuid.UUID) ([]*ThreatAnnotation, error)
+
 	// Anomalies
 	CreateAnomaly(anomaly *Anomaly) error
 	GetAnomalies(orgID uuid.UUID, limit, offset int) ([]*Anomaly, error)
 	GetAnomalyByID(id uuid.UUID) (*Anomaly, error)
 
+	// SearchAnomalies returns a page of anomalies and the total matching the filters
+	SearchAnomalies(orgID uuid.UUID, params AnomalyQueryParams) ([]*Anomaly, int, error)
+
 	// Incidents
 	CreateIncident(incident *SecurityIncident) error
 	GetIncidents(orgID uuid.UUID, status IncidentStatus, limit, offset int) ([]*SecurityIncident, error)
 	GetIncidentByID(id uuid.UUID) (*SecurityIncident, error)
 	UpdateIncidentStatus(id uuid.UUID, status IncidentStatus, resolvedBy *uuid.UUID, notes string) error
+	// AssignIncident sets the incident's assignee; nil unassigns it
+	AssignIncident(id uuid.UUID, assignee *uuid.UUID) error
+	CountOpenIncidents(orgID uuid.UUID) (int, error)
+	// ListUnresolvedIncidents returns the open and investigating incidents of every organization
+	ListUnresolvedIncidents() ([]*SecurityIncident, error)
+	// DeleteIncident removes an incident along with its comments, timeline and links
+	DeleteIncident(id uuid.UUID) error
 
 	// Metrics
 	GetSecurityMetrics(orgID uuid.UUID) (*SecurityMetrics, error)
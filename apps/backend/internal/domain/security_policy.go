@@ -16,6 +16,8 @@ const (
 	PolicyTypeUnauthorizedAccess  PolicyType = "unauthorized_access"
 	PolicyTypeDataExfiltration    PolicyType = "data_exfiltration"
 	PolicyTypeConfigDrift         PolicyType = "config_drift"
+	PolicyTypeSensitiveTool       PolicyType = "sensitive_tool" // Extra conditions for flagged MCP tools
+	PolicyTypeExpression          PolicyType = "expression"     // CEL condition (rules.expression) checked on every verification
 )
 
 // EnforcementAction defines what action to take when policy is triggered
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrSharedKeyAllowlistEntryNotFound is returned when an allowlist entry does not exist in the organization
+var ErrSharedKeyAllowlistEntryNotFound = errors.New("shared key allowlist entry not found")
+
+// PublicKeyFingerprint identifies a public key without repeating it: the hex SHA-256 of its
+// base64 encoding
+func PublicKeyFingerprint(publicKey string) string {
+	sum := sha256.Sum256([]byte(publicKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// SharedKeyHolder is an agent whose current public key is also another agent's
+type SharedKeyHolder struct {
+	AgentID        uuid.UUID `json:"agentId"`
+	AgentName      string    `json:"agentName"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+}
+
+// SharedKey is a public key registered to more than one agent, in one or several organizations
+type SharedKey struct {
+	Fingerprint string            `json:"fingerprint"`
+	Holders     []SharedKeyHolder `json:"holders"`
+}
+
+// SharedKeyAllowlistEntry lets an organization register one public key to several agents on purpose
+type SharedKeyAllowlistEntry struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organizationId"`
+	Fingerprint    string     `json:"fingerprint"`
+	Reason         string     `json:"reason"`
+	CreatedBy      *uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// SharedKeyRepository finds public keys held by several agents and stores the allowlist
+type SharedKeyRepository interface {
+	// FindSharedKeys returns every public key that is the current key of more than one agent,
+	// across organizations
+	FindSharedKeys() ([]*SharedKey, error)
+	// FindPublicKeyHolders returns the agents whose current public key is publicKey
+	FindPublicKeyHolders(publicKey string) ([]SharedKeyHolder, error)
+
+	CreateAllowlistEntry(entry *SharedKeyAllowlistEntry) error
+	ListAllowlist(orgID uuid.UUID) ([]*SharedKeyAllowlistEntry, error)
+	DeleteAllowlistEntry(orgID, id uuid.UUID) error
+}
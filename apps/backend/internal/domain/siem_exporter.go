@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrSIEMExporterNotFound is returned for unknown SIEM exporters and exporters of another organization
+var ErrSIEMExporterNotFound = errors.New("SIEM exporter not found")
+
+// Categories of security events streamed to SIEMs
+const (
+	SIEMEventThreat              = "threat"
+	SIEMEventAnomaly             = "anomaly"
+	SIEMEventAlert               = "alert" // Every alert, including drift alerts
+	SIEMEventVerificationFailure = "verification_failure"
+)
+
+// SIEMEventCategories lists every category, in the order they are documented
+var SIEMEventCategories = []string{SIEMEventThreat, SIEMEventAnomaly, SIEMEventAlert, SIEMEventVerificationFailure}
+
+// Message formats of SIEM exporters
+const (
+	SIEMFormatCEF  = "cef"  // ArcSight Common Event Format, read by Splunk and most SIEMs
+	SIEMFormatLEEF = "leef" // IBM QRadar Log Event Extended Format
+)
+
+// Syslog transports of SIEM exporters
+const (
+	SIEMTransportUDP = "udp"
+	SIEMTransportTCP = "tcp" // Octet-counted frames (RFC 6587)
+	SIEMTransportTLS = "tls" // Octet-counted frames over TLS (RFC 5425)
+)
+
+// SIEMExporter streams an organization's security events to a SIEM as RFC 5424 syslog messages
+type SIEMExporter struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	Name           string    `json:"name"`
+	Host           string    `json:"host"`
+	Port           int       `json:"port"`
+	Transport      string    `json:"transport"` // udp, tcp or tls
+	Format         string    `json:"format"`    // cef or leef
+	Facility       int       `json:"facility"`  // Syslog facility, 0-23
+	// CACertificate is the PEM CA the SIEM's TLS certificate chains to; empty trusts the system roots
+	CACertificate string `json:"caCertificate,omitempty"`
+	// Filtering - empty EventTypes streams every category
+	EventTypes  []string      `json:"eventTypes"`
+	MinSeverity AlertSeverity `json:"minSeverity"`
+	IsActive    bool          `json:"isActive"`
+	// Delivery - counted by the instances streaming events
+	DeliveredCount  int64      `json:"deliveredCount"`
+	DroppedCount    int64      `json:"droppedCount"` // Dropped while the SIEM was unreachable and the buffer was full
+	LastDeliveredAt *time.Time `json:"lastDeliveredAt,omitempty"`
+	LastError       *string    `json:"lastError,omitempty"`
+	LastErrorAt     *time.Time `json:"lastErrorAt,omitempty"`
+	CreatedBy       uuid.UUID  `json:"createdBy"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// SIEMEvent is a security event as streamed to SIEMs
+type SIEMEvent struct {
+	ID             uuid.UUID // Of the threat, anomaly, alert or verification event
+	OrganizationID uuid.UUID
+	Category       string // threat, anomaly, alert or verification_failure
+	Type           string // e.g. the threat, anomaly or alert type
+	Name           string
+	Message        string
+	Severity       AlertSeverity
+	ResourceType   string
+	ResourceID     *uuid.UUID
+	SourceIP       string
+	OccurredAt     time.Time
+	Fields         map[string]string // Category-specific details
+}
+
+// SIEMSink delivers events to one exporter's SIEM
+type SIEMSink interface {
+	Send(ctx context.Context, events []*SIEMEvent) error
+	Close() error
+}
+
+// SIEMDeliveryStats are the deliveries of an exporter since the stats were last recorded
+type SIEMDeliveryStats struct {
+	Delivered   int64
+	Dropped     int64
+	DeliveredAt *time.Time
+	Error       *string // Last error, when the last attempt failed
+	ErrorAt     *time.Time
+}
+
+// SIEMExporterRepository defines the interface for SIEM exporter persistence
+type SIEMExporterRepository interface {
+	Create(exporter *SIEMExporter) error
+	GetByID(id uuid.UUID) (*SIEMExporter, error)
+	// GetByOrganization returns the organization's exporters, oldest first
+	GetByOrganization(orgID uuid.UUID) ([]*SIEMExporter, error)
+	GetActiveByOrganization(orgID uuid.UUID) ([]*SIEMExporter, error)
+	Update(exporter *SIEMExporter) error
+	Delete(id uuid.UUID) error
+	// RecordDelivery adds the stats to the exporter's counters and sets its last delivery and error
+	RecordDelivery(id uuid.UUID, stats SIEMDeliveryStats) error
+}
@@ -57,9 +57,13 @@ type TagRepository interface {
 	AddTagsToAgent(ctx context.Context, agentID uuid.UUID, tagIDs []uuid.UUID) error
 	RemoveTagFromAgent(ctx context.Context, agentID uuid.UUID, tagID uuid.UUID) error
 	GetAgentTags(ctx context.Context, agentID uuid.UUID) ([]*Tag, error)
+	// GetTagsForAgents returns the tags of several agents in one query, keyed by agent
+	GetTagsForAgents(ctx context.Context, agentIDs []uuid.UUID) (map[uuid.UUID][]*Tag, error)
 
 	// MCP Server Tag Relationships
 	AddTagsToMCPServer(ctx context.Context, mcpServerID uuid.UUID, tagIDs []uuid.UUID) error
 	RemoveTagFromMCPServer(ctx context.Context, mcpServerID uuid.UUID, tagID uuid.UUID) error
 	GetMCPServerTags(ctx context.Context, mcpServerID uuid.UUID) ([]*Tag, error)
+	// GetTagsForMCPServers returns the tags of several MCP servers in one query, keyed by server
+	GetTagsForMCPServers(ctx context.Context, mcpServerIDs []uuid.UUID) (map[uuid.UUID][]*Tag, error)
 }
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTicketConnectorNotFound is returned when a ticket connector does not exist
+	ErrTicketConnectorNotFound = errors.New("ticket connector not found")
+	// ErrTicketLinkNotFound is returned when no ticket is linked to a resource
+	ErrTicketLinkNotFound = errors.New("ticket link not found")
+)
+
+// Resources ticket connectors open tickets for
+const (
+	TicketResourceCapabilityRequest = "capability_request"
+	TicketResourceIncident          = "incident"
+)
+
+// TicketConnector opens a ticket in an organization's Jira or ServiceNow whenever a capability
+// request or incident is opened, and syncs the ticket's status and comments back. Ticket
+// statuses approve or reject capability requests and move incidents, acting as the user who
+// configured the connector.
+type TicketConnector struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	Name           string    `json:"name"`
+	Provider       string    `json:"provider"` // "jira" or "servicenow"
+	BaseURL        string    `json:"baseUrl"`
+	Username       string    `json:"username"` // Jira account email or ServiceNow user
+	// EncryptedAPIToken is the Jira API token or ServiceNow password, encrypted with the key vault
+	EncryptedAPIToken string `json:"-"`
+	Project           string `json:"project"`             // Jira project key or ServiceNow assignment group
+	IssueType         string `json:"issueType,omitempty"` // Jira issue type (default Task)
+	// ResourceTypes are the resources tickets are opened for: capability_request, incident
+	ResourceTypes []string `json:"resourceTypes"`
+	// ApprovedStatuses and RejectedStatuses are the ticket statuses (case-insensitive) that
+	// approve or reject the capability request
+	ApprovedStatuses []string `json:"approvedStatuses"`
+	RejectedStatuses []string `json:"rejectedStatuses"`
+	// IncidentStatuses moves the incident when its ticket reaches a status (case-insensitive),
+	// e.g. {"In Progress": "investigating", "Done": "resolved"}
+	IncidentStatuses map[string]IncidentStatus `json:"incidentStatuses"`
+	// WebhookSecret authenticates the ticketing system's webhook calls. Only its hash is stored;
+	// the secret is returned once, when the connector is created.
+	WebhookSecret     string    `json:"webhookSecret,omitempty"`
+	WebhookSecretHash string    `json:"-"`
+	IsEnabled         bool      `json:"isEnabled"`
+	CreatedBy         uuid.UUID `json:"createdBy"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// Covers reports whether the connector opens tickets for the resource type
+func (c *TicketConnector) Covers(resourceType string) bool {
+	return slices.Contains(c.ResourceTypes, resourceType)
+}
+
+// TicketLink ties a capability request or incident to the ticket a connector opened for it.
+// Links are synced until their resource is decided or closed.
+type TicketLink struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	ConnectorID    uuid.UUID `json:"connectorId"`
+	ResourceType   string    `json:"resourceType"`
+	ResourceID     uuid.UUID `json:"resourceId"`
+	Provider       string    `json:"provider"`
+	TicketKey      string    `json:"ticketKey"`          // Jira issue key or ServiceNow incident number
+	TicketID       string    `json:"ticketId,omitempty"` // ServiceNow sys_id
+	TicketURL      string    `json:"ticketUrl"`
+	TicketStatus   string    `json:"ticketStatus,omitempty"` // Status seen on the last sync
+	// SyncedCommentIDs are the ticket comments already copied to the resource
+	SyncedCommentIDs []string   `json:"-"`
+	Closed           bool       `json:"closed"`
+	LastSyncedAt     *time.Time `json:"lastSyncedAt,omitempty"`
+	LastSyncError    *string    `json:"lastSyncError,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+// TicketComment is a comment left on a ticket in the ticketing system
+type TicketComment struct {
+	ID        string    `json:"id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TicketState is a ticket's current status and comments, oldest first
+type TicketState struct {
+	Status   string          `json:"status"`
+	Comments []TicketComment `json:"comments"`
+}
+
+// TicketSystem is the ticketing system of one connector
+type TicketSystem interface {
+	TicketCreator
+	GetTicket(ctx context.Context, ticket *TicketReference) (*TicketState, error)
+}
+
+// TicketConnectorRepository defines the interface for ticket connector and link persistence
+type TicketConnectorRepository interface {
+	CreateConnector(connector *TicketConnector) error
+	GetConnector(id uuid.UUID) (*TicketConnector, error)
+	// GetConnectorsByOrganization returns the organization's connectors, oldest first
+	GetConnectorsByOrganization(orgID uuid.UUID) ([]*TicketConnector, error)
+	UpdateConnector(connector *TicketConnector) error
+	DeleteConnector(id uuid.UUID) error
+
+	CreateLink(link *TicketLink) error
+	GetLinkByTicket(connectorID uuid.UUID, ticketKey string) (*TicketLink, error)
+	// GetLinksByResource returns the tickets opened for a resource, oldest first
+	GetLinksByResource(resourceType string, resourceID uuid.UUID) ([]*TicketLink, error)
+	// GetOpenLinks returns up to limit links still being synced, least recently synced first
+	GetOpenLinks(limit int) ([]*TicketLink, error)
+	UpdateLink(link *TicketLink) error
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TombstoneEntityType identifies the kind of entity a tombstone stands in for
+type TombstoneEntityType string
+
+const (
+	TombstoneEntityAgent     TombstoneEntityType = "agent"
+	TombstoneEntityMCPServer TombstoneEntityType = "mcp_server"
+	TombstoneEntityAPIKey    TombstoneEntityType = "api_key"
+)
+
+// Tombstone is the immutable, redacted record kept when an agent, MCP server or API key is
+// deleted, so references in old events and incidents still resolve to something meaningful.
+// It never holds key material, hashes or configuration.
+type Tombstone struct {
+	ID             uuid.UUID           `json:"id"`
+	OrganizationID uuid.UUID           `json:"organizationId"`
+	EntityType     TombstoneEntityType `json:"entityType"`
+	EntityID       uuid.UUID           `json:"entityId"`
+	Name           string              `json:"name"`
+	DeletedBy      *uuid.UUID          `json:"deletedBy,omitempty"`
+	DeletedByEmail string              `json:"deletedByEmail,omitempty"` // Kept so the actor resolves after the user is deleted too
+	DeletedAt      time.Time           `json:"deletedAt"`
+	LinkedCounts   map[string]int      `json:"linkedCounts"` // Records that referenced the entity when it was deleted
+	Details        map[string]string   `json:"details"`      // Descriptive, non-sensitive fields (type, status, owning agent, ...)
+}
+
+// TombstoneFilter selects tombstones for the admin search
+type TombstoneFilter struct {
+	OrganizationID uuid.UUID
+	EntityType     TombstoneEntityType // Empty for every type
+	Query          string              // Matches the name (case-insensitive) or the exact entity ID
+	DeletedBy      *uuid.UUID
+	Limit          int
+	Offset         int
+}
+
+// TombstoneRepository defines the interface for tombstone persistence. Tombstones are
+// append-only: there is no update or delete.
+type TombstoneRepository interface {
+	Create(tombstone *Tombstone) error
+	// GetByEntityID returns the organization's tombstone of a deleted entity, or nil if there is none
+	GetByEntityID(orgID, entityID uuid.UUID) (*Tombstone, error)
+	// Search returns a page of matching tombstones, newest first, and the total number of matches
+	Search(filter *TombstoneFilter) ([]*Tombstone, int, error)
+	// CountLinked counts the records that reference the entity (before it is deleted)
+	CountLinked(entityType TombstoneEntityType, entityID uuid.UUID) (map[string]int, error)
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrustBoundaryTransition identifies an agent's trust score crossing one of the organization's boundaries
+type TrustBoundaryTransition string
+
+const (
+	TrustBoundaryBelowFloor       TrustBoundaryTransition = "below_floor"       // Score dropped below the floor
+	TrustBoundaryRecoveredFloor   TrustBoundaryTransition = "recovered_floor"   // Score climbed back to the floor or above
+	TrustBoundarySustainedCeiling TrustBoundaryTransition = "sustained_ceiling" // Score stayed at or above the ceiling for the sustained period
+	TrustBoundaryLeftCeiling      TrustBoundaryTransition = "left_ceiling"      // Score dropped below the ceiling
+)
+
+// TrustBoundaryActionType identifies an automatic action taken on a boundary transition
+type TrustBoundaryActionType string
+
+const (
+	TrustBoundaryActionSuspendAgent        TrustBoundaryActionType = "suspend_agent"
+	TrustBoundaryActionOpenIncident        TrustBoundaryActionType = "open_incident"
+	TrustBoundaryActionExtendKeyExpiry     TrustBoundaryActionType = "extend_key_expiry"
+	TrustBoundaryActionRelaxVerification   TrustBoundaryActionType = "relax_verification"
+	TrustBoundaryActionRestoreVerification TrustBoundaryActionType = "restore_verification"
+)
+
+// TrustBoundaryPolicy configures the automatic actions taken when an agent's trust score
+// (0-1) drops below the organization's floor or stays above its ceiling
+type TrustBoundaryPolicy struct {
+	ID                       uuid.UUID     `json:"id"`
+	OrganizationID           uuid.UUID     `json:"organizationId"`
+	Enabled                  bool          `json:"enabled"`
+	FloorScore               float64       `json:"floorScore"`
+	FloorSuspend             bool          `json:"floorSuspend"`
+	FloorOpenIncident        bool          `json:"floorOpenIncident"`
+	IncidentSeverity         AlertSeverity `json:"incidentSeverity"`
+	CeilingScore             float64       `json:"ceilingScore"`
+	CeilingSustainedDays     int           `json:"ceilingSustainedDays"` // Days at or above the ceiling before the ceiling actions run
+	CeilingExtendKeyExpiry   bool          `json:"ceilingExtendKeyExpiry"`
+	KeyExtensionDays         int           `json:"keyExtensionDays"`         // Expiring API keys are extended to now + this many days
+	CeilingRelaxVerification bool          `json:"ceilingRelaxVerification"` // Approved verifications stay valid longer
+	UpdatedBy                *uuid.UUID    `json:"updatedBy,omitempty"`
+	CreatedAt                time.Time     `json:"createdAt"`
+	UpdatedAt                time.Time     `json:"updatedAt"`
+	InheritedFrom            *uuid.UUID    `json:"inheritedFrom,omitempty"` // Ancestor organization whose policy applies; not stored
+}
+
+// DefaultTrustBoundaryPolicy is used for organizations that have not configured a policy.
+// It is disabled: scores drive no automation until an administrator opts in.
+func DefaultTrustBoundaryPolicy(orgID uuid.UUID) *TrustBoundaryPolicy {
+	return &TrustBoundaryPolicy{
+		OrganizationID:           orgID,
+		Enabled:                  false,
+		FloorScore:               0.3,
+		FloorSuspend:             true,
+		FloorOpenIncident:        true,
+		IncidentSeverity:         AlertSeverityHigh,
+		CeilingScore:             0.9,
+		CeilingSustainedDays:     30,
+		CeilingExtendKeyExpiry:   true,
+		KeyExtensionDays:         90,
+		CeilingRelaxVerification: false,
+	}
+}
+
+// AgentTrustBoundaryState tracks where an agent's score sits relative to the boundaries,
+// so each transition triggers its actions once
+type AgentTrustBoundaryState struct {
+	AgentID             uuid.UUID  `json:"agentId"`
+	OrganizationID      uuid.UUID  `json:"organizationId"`
+	LastScore           float64    `json:"lastScore"`
+	BelowFloorSince     *time.Time `json:"belowFloorSince,omitempty"`
+	AboveCeilingSince   *time.Time `json:"aboveCeilingSince,omitempty"`
+	CeilingActionsAt    *time.Time `json:"ceilingActionsAt,omitempty"` // Last time the ceiling actions ran
+	VerificationRelaxed bool       `json:"verificationRelaxed"`
+	UpdatedAt           time.Time  `json:"updatedAt"`
+}
+
+// TrustBoundaryAction is the outcome of one automatic action
+type TrustBoundaryAction struct {
+	Action TrustBoundaryActionType `json:"action"`
+	Status CompromiseActionStatus  `json:"status"`
+	Count  int                     `json:"count"`
+	Detail string                  `json:"detail,omitempty"`
+	Error  *string                 `json:"error,omitempty"`
+}
+
+// TrustBoundaryEvent reports a boundary transition of an agent and the actions it triggered
+type TrustBoundaryEvent struct {
+	AgentID    uuid.UUID               `json:"agentId"`
+	AgentName  string                  `json:"agentName"`
+	Transition TrustBoundaryTransition `json:"transition"`
+	Score      float64                 `json:"score"`
+	Boundary   float64                 `json:"boundary"` // The floor or ceiling that was crossed
+	IncidentID *uuid.UUID              `json:"incidentId,omitempty"`
+	Actions    []TrustBoundaryAction   `json:"actions"`
+	OccurredAt time.Time               `json:"occurredAt"`
+}
+
+// TrustBoundaryRepository defines the interface for trust boundary persistence
+type TrustBoundaryRepository interface {
+	// GetPolicy returns the organization's policy, or nil if none is configured
+	GetPolicy(orgID uuid.UUID) (*TrustBoundaryPolicy, error)
+	UpsertPolicy(policy *TrustBoundaryPolicy) error
+	GetEnabledPolicies() ([]*TrustBoundaryPolicy, error)
+	// GetState returns the agent's boundary state, or nil if it was never evaluated
+	GetState(agentID uuid.UUID) (*AgentTrustBoundaryState, error)
+	GetStatesByOrganization(orgID uuid.UUID) ([]*AgentTrustBoundaryState, error)
+	UpsertState(state *AgentTrustBoundaryState) error
+}
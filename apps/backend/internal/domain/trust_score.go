@@ -50,6 +50,9 @@ type TrustScoreRepository interface {
 	Create(score *TrustScore) error
 	GetByAgent(agentID uuid.UUID) (*TrustScore, error)
 	GetLatest(agentID uuid.UUID) (*TrustScore, error)
+	// GetLatestByAgents returns the latest score of each agent in one query; agents that were
+	// never scored are left out
+	GetLatestByAgents(agentIDs []uuid.UUID) (map[uuid.UUID]*TrustScore, error)
 	GetHistory(agentID uuid.UUID, limit int) ([]*TrustScore, error)
 	GetHistoryAuditTrail(agentID uuid.UUID, limit int) ([]*TrustScoreHistoryEntry, error)
 }
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTrustScoreTriggerNotFound is returned when a trust score trigger does not exist
+	ErrTrustScoreTriggerNotFound = errors.New("trust score trigger not found")
+)
+
+// TrustScoreTriggerCondition is the direction in which a score has to cross a trigger's threshold
+type TrustScoreTriggerCondition string
+
+const (
+	TrustScoreTriggerBelow TrustScoreTriggerCondition = "below" // Fires when the score drops below the threshold
+	TrustScoreTriggerAbove TrustScoreTriggerCondition = "above" // Fires when the score rises above the threshold
+)
+
+// IsValid reports whether the condition is one triggers support
+func (c TrustScoreTriggerCondition) IsValid() bool {
+	return c == TrustScoreTriggerBelow || c == TrustScoreTriggerAbove
+}
+
+// Crossed reports whether a score change from previous to current crosses the threshold in the
+// condition's direction. A score that stays past the threshold does not cross it again.
+func (c TrustScoreTriggerCondition) Crossed(previous, current, threshold float64) bool {
+	switch c {
+	case TrustScoreTriggerBelow:
+		return previous >= threshold && current < threshold
+	case TrustScoreTriggerAbove:
+		return previous <= threshold && current > threshold
+	}
+	return false
+}
+
+// TrustScoreTriggerActionType is an automated action a trust score trigger performs
+type TrustScoreTriggerActionType string
+
+const (
+	TrustScoreTriggerActionAlert         TrustScoreTriggerActionType = "alert"           // Raise an alert
+	TrustScoreTriggerActionWebhook       TrustScoreTriggerActionType = "webhook"         // Deliver trust_score.threshold_crossed to subscribed webhooks
+	TrustScoreTriggerActionSuspendAgent  TrustScoreTriggerActionType = "suspend_agent"   // Suspend the agent
+	TrustScoreTriggerActionRevokeAPIKeys TrustScoreTriggerActionType = "revoke_api_keys" // Revoke the agent's active API keys
+	TrustScoreTriggerActionOpenIncident  TrustScoreTriggerActionType = "open_incident"   // Open a security incident, which starts matching playbooks
+)
+
+// IsValid reports whether the action is one triggers can run
+func (a TrustScoreTriggerActionType) IsValid() bool {
+	switch a {
+	case TrustScoreTriggerActionAlert, TrustScoreTriggerActionWebhook, TrustScoreTriggerActionSuspendAgent,
+		TrustScoreTriggerActionRevokeAPIKeys, TrustScoreTriggerActionOpenIncident:
+		return true
+	}
+	return false
+}
+
+// TrustScoreTrigger runs actions when the trust score (0-1) of one of the organization's agents
+// crosses a threshold, e.g. "if trust score falls below 0.5, suspend the agent and notify".
+// Triggers are evaluated every time a score is stored.
+type TrustScoreTrigger struct {
+	ID             uuid.UUID                     `json:"id"`
+	OrganizationID uuid.UUID                     `json:"organizationId"`
+	Name           string                        `json:"name"`
+	Description    string                        `json:"description"`
+	Condition      TrustScoreTriggerCondition    `json:"condition"`
+	Threshold      float64                       `json:"threshold"`
+	Actions        []TrustScoreTriggerActionType `json:"actions"`  // Run in order
+	Severity       AlertSeverity                 `json:"severity"` // Of the alert and incident the trigger raises
+	IsEnabled      bool                          `json:"isEnabled"`
+	CreatedBy      uuid.UUID                     `json:"createdBy"`
+	CreatedAt      time.Time                     `json:"createdAt"`
+	UpdatedAt      time.Time                     `json:"updatedAt"`
+}
+
+// TrustScoreTriggerActionResult is the outcome of one action of a firing
+type TrustScoreTriggerActionResult struct {
+	Action TrustScoreTriggerActionType `json:"action"`
+	Status CompromiseActionStatus      `json:"status"`
+	Count  int                         `json:"count"`
+	Detail string                      `json:"detail,omitempty"`
+	Error  *string                     `json:"error,omitempty"`
+}
+
+// TrustScoreTriggerFiring records a trigger firing for an agent and the outcome of its actions
+type TrustScoreTriggerFiring struct {
+	ID             uuid.UUID                       `json:"id"`
+	OrganizationID uuid.UUID                       `json:"organizationId"`
+	TriggerID      uuid.UUID                       `json:"triggerId"`
+	TriggerName    string                          `json:"triggerName"`
+	AgentID        uuid.UUID                       `json:"agentId"`
+	AgentName      string                          `json:"agentName"`
+	Condition      TrustScoreTriggerCondition      `json:"condition"`
+	Threshold      float64                         `json:"threshold"`
+	PreviousScore  float64                         `json:"previousScore"`
+	Score          float64                         `json:"score"`
+	AlertID        *uuid.UUID                      `json:"alertId,omitempty"`
+	IncidentID     *uuid.UUID                      `json:"incidentId,omitempty"`
+	Actions        []TrustScoreTriggerActionResult `json:"actions"`
+	FiredAt        time.Time                       `json:"firedAt"`
+}
+
+// TrustScoreTriggerRepository defines the interface for trust score trigger persistence
+type TrustScoreTriggerRepository interface {
+	CreateTrigger(trigger *TrustScoreTrigger) error
+	// GetTrigger returns ErrTrustScoreTriggerNotFound when no trigger exists
+	GetTrigger(id uuid.UUID) (*TrustScoreTrigger, error)
+	// GetTriggersByOrganization returns the organization's triggers, oldest first
+	GetTriggersByOrganization(orgID uuid.UUID) ([]*TrustScoreTrigger, error)
+	UpdateTrigger(trigger *TrustScoreTrigger) error
+	DeleteTrigger(id uuid.UUID) error
+
+	CreateFiring(firing *TrustScoreTriggerFiring) error
+	// GetFiringsByOrganization returns a page of firings, newest first, optionally of one
+	// trigger, and the total count
+	GetFiringsByOrganization(orgID uuid.UUID, triggerID *uuid.UUID, limit, offset int) ([]*TrustScoreTriggerFiring, int, error)
+}
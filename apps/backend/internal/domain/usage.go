@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UsageSubjectType identifies whose requests a usage rollup counts
+type UsageSubjectType string
+
+const (
+	UsageSubjectAgent  UsageSubjectType = "agent"   // Verifications of the agent
+	UsageSubjectAPIKey UsageSubjectType = "api_key" // Verifications recorded by requests authenticated with the key
+)
+
+// UsageRollup counts the verification requests of an agent or API key on one UTC day, by
+// outcome and by the MCP servers reported at runtime. The usage aggregator job maintains the
+// rollups from the verification events.
+type UsageRollup struct {
+	OrganizationID uuid.UUID        `json:"organizationId"`
+	SubjectType    UsageSubjectType `json:"subjectType"`
+	SubjectID      uuid.UUID        `json:"subjectId"`
+	Day            time.Time        `json:"day"` // Midnight UTC
+	Requests       int              `json:"requests"`
+	Successes      int              `json:"successes"`
+	Failures       int              `json:"failures"`
+	Pending        int              `json:"pending"`
+	Timeouts       int              `json:"timeouts"`
+	Errors         int              `json:"errors"`     // Requests recorded with an error code
+	MCPServers     map[string]int   `json:"mcpServers"` // Requests per MCP server contacted
+	UpdatedAt      time.Time        `json:"updatedAt"`
+}
+
+// UsageTotals sums the rolled up requests of a day or a period
+type UsageTotals struct {
+	Requests    int     `json:"requests"`
+	Successes   int     `json:"successes"`
+	Failures    int     `json:"failures"`
+	Pending     int     `json:"pending"`
+	Timeouts    int     `json:"timeouts"`
+	Errors      int     `json:"errors"`
+	SuccessRate float64 `json:"successRate"` // Successes / requests, 0-1
+	ErrorRate   float64 `json:"errorRate"`   // Errors / requests, 0-1
+}
+
+// UsageDay is the usage of one UTC day
+type UsageDay struct {
+	Day time.Time `json:"day"`
+	UsageTotals
+}
+
+// UsageServerStat counts the requests that reported an MCP server
+type UsageServerStat struct {
+	Server   string `json:"server"`
+	Requests int    `json:"requests"`
+}
+
+// UsageReport is the usage of one agent or API key over a period, with every day of the
+// period (idle days count zero) and the MCP servers it contacted most
+type UsageReport struct {
+	SubjectType   UsageSubjectType  `json:"subjectType"`
+	SubjectID     uuid.UUID         `json:"subjectId"`
+	Name          string            `json:"name"`
+	PeriodStart   time.Time         `json:"periodStart"`
+	PeriodEnd     time.Time         `json:"periodEnd"`
+	Totals        UsageTotals       `json:"totals"`
+	Daily         []UsageDay        `json:"daily"`
+	TopMCPServers []UsageServerStat `json:"topMcpServers"`
+	ActiveDays    int               `json:"activeDays"`
+	LastActiveDay *time.Time        `json:"lastActiveDay,omitempty"`
+	LastUsedAt    *time.Time        `json:"lastUsedAt,omitempty"` // API keys only: last authenticated request of any kind
+}
+
+// UsageSummary is one agent's or API key's usage in an organization-wide usage listing.
+// Idle subjects made no requests in the period (abandoned keys); a peak day far above the
+// daily average points at a runaway agent.
+type UsageSummary struct {
+	SubjectID       uuid.UUID   `json:"subjectId"`
+	Name            string      `json:"name"`
+	AgentID         *uuid.UUID  `json:"agentId,omitempty"` // API keys only: the agent the key belongs to
+	Totals          UsageTotals `json:"totals"`
+	ActiveDays      int         `json:"activeDays"`
+	LastActiveDay   *time.Time  `json:"lastActiveDay,omitempty"`
+	PeakDayRequests int         `json:"peakDayRequests"`
+	DailyAverage    float64     `json:"dailyAverage"`
+	Idle            bool        `json:"idle"`
+	LastUsedAt      *time.Time  `json:"lastUsedAt,omitempty"` // API keys only
+}
+
+// UsageSummaryReport lists the usage of every agent or API key of an organization, busiest first
+type UsageSummaryReport struct {
+	SubjectType UsageSubjectType `json:"subjectType"`
+	PeriodStart time.Time        `json:"periodStart"`
+	PeriodEnd   time.Time        `json:"periodEnd"`
+	Subjects    []UsageSummary   `json:"subjects"`
+}
+
+// UsageRollupRepository stores the daily usage rollups
+type UsageRollupRepository interface {
+	// Upsert stores the rollups, replacing those of the same subject and day
+	Upsert(rollups []*UsageRollup) error
+	// List returns the organization's rollups of a subject type for the days in [since, until),
+	// oldest first; a nil subject ID lists every subject of the type
+	List(orgID uuid.UUID, subjectType UsageSubjectType, subjectID *uuid.UUID, since, until time.Time) ([]*UsageRollup, error)
+	// LatestDay returns the most recent day rolled up; ok is false before the first aggregation
+	LatestDay() (day time.Time, ok bool, err error)
+}
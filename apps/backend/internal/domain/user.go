@@ -44,6 +44,11 @@ type User struct {
 	ApprovedBy             *uuid.UUID `json:"approvedBy,omitempty"` // Admin who approved this user
 	ApprovedAt             *time.Time `json:"approvedAt,omitempty"` // When user was approved
 	LastLoginAt            *time.Time `json:"lastLoginAt"`
+	MFAEnabled             bool       `json:"mfaEnabled"`              // TOTP enrollment confirmed; login requires a code
+	MFASecret              *string    `json:"-"`                       // Encrypted TOTP secret, set from the start of enrollment
+	MFARecoveryCodes       []string   `json:"-"`                       // SHA-256 hashes of the unused recovery codes
+	MFALastUsedStep        int64      `json:"-"`                       // TOTP time step of the last accepted code, to reject replays
+	MFAEnrolledAt          *time.Time `json:"mfaEnrolledAt,omitempty"` // When enrollment was confirmed
 	DeletedAt              *time.Time `json:"deletedAt,omitempty"` // When user was soft-deleted (deactivated)
 	CreatedAt              time.Time  `json:"createdAt"`
 	UpdatedAt              time.Time  `json:"updatedAt"`
@@ -53,11 +58,14 @@ type User struct {
 type UserRepository interface {
 	Create(user *User) error
 	GetByID(id uuid.UUID) (*User, error)
+	GetByIDs(ids []uuid.UUID) ([]*User, error)
 	GetByEmail(email string) (*User, error)
 	GetByPasswordResetToken(resetToken string) (*User, error)
 	GetByOrganization(orgID uuid.UUID) ([]*User, error)
 	GetByOrganizationAndStatus(orgID uuid.UUID, status UserStatus) ([]*User, error)
 	Update(user *User) error
+	// UpdateMFA stores the user's MFA fields only; Update leaves them untouched
+	UpdateMFA(user *User) error
 	UpdateRole(id uuid.UUID, role UserRole) error
 	Delete(id uuid.UUID) error
 	CountActiveUsers(orgID uuid.UUID, withinMinutes int) (int, error)
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"context"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -57,6 +59,25 @@ const (
 	InitiatorTypeScheduler InitiatorType = "scheduler"
 )
 
+// VerificationRiskLevel is how risky a verification event is, assessed when it is recorded
+type VerificationRiskLevel string
+
+const (
+	VerificationRiskLow      VerificationRiskLevel = "low"
+	VerificationRiskMedium   VerificationRiskLevel = "medium"
+	VerificationRiskHigh     VerificationRiskLevel = "high"
+	VerificationRiskCritical VerificationRiskLevel = "critical"
+)
+
+// IsValid reports whether the level is one of the known risk levels
+func (l VerificationRiskLevel) IsValid() bool {
+	switch l {
+	case VerificationRiskLow, VerificationRiskMedium, VerificationRiskHigh, VerificationRiskCritical:
+		return true
+	}
+	return false
+}
+
 // VerificationEvent represents a real-time verification event for monitoring
 type VerificationEvent struct {
 	ID             uuid.UUID `json:"id"`
@@ -95,6 +116,13 @@ type VerificationEvent struct {
 	InitiatorName *string       `json:"initiatorName,omitempty"`
 	InitiatorIP   *string       `json:"initiatorIp,omitempty"`
 
+	// Credential behind the request that recorded the event, so investigations can pivot from
+	// the event to it; unset for events the platform records itself
+	InitiatorUserID    *uuid.UUID `json:"initiatorUserId,omitempty"`
+	InitiatorAgentID   *uuid.UUID `json:"initiatorAgentId,omitempty"`
+	InitiatorAPIKeyID  *uuid.UUID `json:"initiatorApiKeyId,omitempty"`
+	InitiatorUserAgent *string    `json:"initiatorUserAgent,omitempty"`
+
 	// Context
 	Action       *string `json:"action,omitempty"`
 	ResourceType *string `json:"resourceType,omitempty"`
@@ -103,10 +131,20 @@ type VerificationEvent struct {
 
 	// Configuration Drift Detection (WHO and WHAT)
 	CurrentMCPServers   []string `json:"currentMcpServers,omitempty"`   // Runtime: MCP servers being communicated with
+	CurrentMCPTools     []string `json:"currentMcpTools,omitempty"`     // Runtime: MCP tools being called, as server:tool
 	CurrentCapabilities []string `json:"currentCapabilities,omitempty"` // Runtime: Capabilities being used
 	DriftDetected       bool     `json:"driftDetected"`                 // Whether configuration drift was detected
 	MCPServerDrift      []string `json:"mcpServerDrift,omitempty"`      // Unregistered MCP servers detected
+	MCPToolDrift        []string `json:"mcpToolDrift,omitempty"`        // MCP tools called outside the agent's tool grants
 	CapabilityDrift     []string `json:"capabilityDrift,omitempty"`     // Undeclared capabilities detected
+	PeerAgentDrift      []string `json:"peerAgentDrift,omitempty"`      // Agents called without a peer policy (A2A)
+
+	// Risk assessed from confidence, drift, trust and recent anomalies when the event was recorded
+	RiskLevel VerificationRiskLevel `json:"riskLevel"`
+
+	// How the caller that recorded the event authenticated (jwt, api_key, ed25519, mtls,
+	// personal_access_token); empty for events the platform records itself
+	AuthMethod string `json:"authMethod,omitempty"`
 
 	// Timestamps
 	StartedAt   time.Time  `json:"startedAt"`
@@ -118,14 +156,85 @@ type VerificationEvent struct {
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// RecordCaller records how the request recording the event authenticated and the credential
+// behind it, from the request context. The initiator IP is kept when the event already has one.
+func (e *VerificationEvent) RecordCaller(ctx context.Context) {
+	e.AuthMethod = AuthMethodFromContext(ctx)
+
+	caller, ok := CallerFromContext(ctx)
+	if !ok {
+		return
+	}
+	e.InitiatorUserID = caller.UserID
+	e.InitiatorAgentID = caller.AgentID
+	e.InitiatorAPIKeyID = caller.APIKeyID
+	if e.InitiatorIP == nil && caller.IP != "" {
+		ip := caller.IP
+		e.InitiatorIP = &ip
+	}
+	if caller.UserAgent != "" {
+		userAgent := caller.UserAgent
+		e.InitiatorUserAgent = &userAgent
+	}
+}
+
 // VerificationQueryParams defines filters for admin verification queries
 type VerificationQueryParams struct {
 	Status      string
-	RiskLevel   string
+	RiskLevel   string // low, medium, high or critical; matches the event's recorded risk level
 	Search      string
 	SearchField string
 	Limit       int
 	Offset      int
+	AgentID     *uuid.UUID // Events of this agent only
+
+	// Initiator filters match the credential behind the request that recorded the event
+	InitiatorUserID   *uuid.UUID
+	InitiatorAgentID  *uuid.UUID
+	InitiatorAPIKeyID *uuid.UUID
+	InitiatorIP       string
+}
+
+// VerificationEventFilter selects an organization's verification events by what they verified
+type VerificationEventFilter struct {
+	AgentID          *uuid.UUID
+	MCPServerID      *uuid.UUID
+	Statuses         []VerificationEventStatus
+	Protocol         VerificationProtocol
+	VerificationType VerificationType
+	DriftOnly        bool
+}
+
+// Matches reports whether the event passes the filter
+func (f VerificationEventFilter) Matches(event *VerificationEvent) bool {
+	if f.AgentID != nil && (event.AgentID == nil || *event.AgentID != *f.AgentID) {
+		return false
+	}
+	if f.MCPServerID != nil && (event.MCPServerID == nil || *event.MCPServerID != *f.MCPServerID) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, event.Status) {
+		return false
+	}
+	if f.Protocol != "" && event.Protocol != f.Protocol {
+		return false
+	}
+	if f.VerificationType != "" && event.VerificationType != f.VerificationType {
+		return false
+	}
+	return !f.DriftOnly || event.DriftDetected
+}
+
+// VerificationEventTailQuery selects the events created after a cursor, oldest first
+type VerificationEventTailQuery struct {
+	// Cursor: events are ordered by (CreatedAt, ID) and only those after it are returned
+	AfterCreatedAt time.Time
+	AfterID        uuid.UUID
+	// Before excludes events created at or after it; zero has no upper bound
+	Before time.Time
+
+	VerificationEventFilter
+	Limit int
 }
 
 // VerificationStatusCounts represents counts per verification status bucket
@@ -145,9 +254,19 @@ type VerificationEventRepository interface {
 	GetRecentEvents(orgID uuid.UUID, minutes int) ([]*VerificationEvent, error)
 	GetPendingVerifications(orgID uuid.UUID) ([]*VerificationEvent, error)
 	SearchAdminVerifications(orgID uuid.UUID, params VerificationQueryParams) ([]*VerificationEvent, int, *VerificationStatusCounts, error)
+	ListAfter(orgID uuid.UUID, query VerificationEventTailQuery) ([]*VerificationEvent, error)
 	GetStatistics(orgID uuid.UUID, startTime, endTime time.Time) (*VerificationStatistics, error)
 	GetAgentStatistics(agentID uuid.UUID, startTime, endTime time.Time) (*AgentVerificationStatistics, error)
+	// GetHourlyAgentActivity counts each agent's verifications per hour in [since, until), with
+	// the MCP servers they reported. Hours without verifications are omitted.
+	GetHourlyAgentActivity(orgID uuid.UUID, since, until time.Time) ([]*AgentHourlyActivity, error)
+	// GetDailyUsage rolls up the verifications of every organization's agents and API keys per UTC
+	// day in [since, until). Days without verifications are omitted.
+	GetDailyUsage(since, until time.Time) ([]*UsageRollup, error)
 	UpdateResult(id uuid.UUID, result VerificationResult, reason *string, metadata map[string]interface{}) error
+	// UpdatePendingResults applies one result to several pending events in a single transaction.
+	// Nothing is applied if any of the events is missing or no longer pending.
+	UpdatePendingResults(ids []uuid.UUID, result VerificationResult, reason *string, metadata map[string]interface{}) error
 	Delete(id uuid.UUID) error
 }
 
@@ -180,3 +299,11 @@ type AgentVerificationStatistics struct {
 	AvgConfidence      float64   `json:"avgConfidence"`
 	LastVerification   time.Time `json:"lastVerification"`
 }
+
+// AgentHourlyActivity is an agent's verification activity within one hour
+type AgentHourlyActivity struct {
+	AgentID       uuid.UUID
+	Hour          time.Time // Start of the hour (UTC)
+	Verifications int
+	MCPServers    map[string]int // Verifications per MCP server reported at runtime
+}
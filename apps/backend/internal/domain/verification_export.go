@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrVerificationExportNotFound is returned when a verification export does not exist
+var ErrVerificationExportNotFound = errors.New("verification export not found")
+
+// VerificationExportFormat is the file format of a verification event export
+type VerificationExportFormat string
+
+const (
+	VerificationExportCSV     VerificationExportFormat = "csv"
+	VerificationExportParquet VerificationExportFormat = "parquet"
+)
+
+// VerificationExportStatus tracks an export from request to download
+type VerificationExportStatus string
+
+const (
+	VerificationExportPending   VerificationExportStatus = "pending"
+	VerificationExportRunning   VerificationExportStatus = "running"
+	VerificationExportCompleted VerificationExportStatus = "completed"
+	VerificationExportFailed    VerificationExportStatus = "failed"
+)
+
+// VerificationExportFilter selects the events of an export, created in [Start, End)
+type VerificationExportFilter struct {
+	Start            time.Time                 `json:"start"`
+	End              time.Time                 `json:"end"`
+	AgentID          *uuid.UUID                `json:"agentId,omitempty"`
+	MCPServerID      *uuid.UUID                `json:"mcpServerId,omitempty"`
+	Statuses         []VerificationEventStatus `json:"statuses,omitempty"`
+	Protocol         VerificationProtocol      `json:"protocol,omitempty"`
+	VerificationType VerificationType          `json:"verificationType,omitempty"`
+	DriftOnly        bool                      `json:"driftOnly,omitempty"`
+}
+
+// VerificationExport is an asynchronous export of verification events. The file is kept in
+// object storage under ArtifactName once the export completed.
+type VerificationExport struct {
+	ID             uuid.UUID                `json:"id"`
+	OrganizationID uuid.UUID                `json:"organizationId"`
+	RequestedBy    uuid.UUID                `json:"requestedBy"`
+	Format         VerificationExportFormat `json:"format"`
+	Filter         VerificationExportFilter `json:"filter"`
+	Status         VerificationExportStatus `json:"status"`
+	RowCount       int                      `json:"rowCount"`
+	Size           int64                    `json:"size"`
+	Filename       string                   `json:"filename,omitempty"`
+	ContentType    string                   `json:"contentType,omitempty"`
+	ArtifactName   string                   `json:"-"`
+	Error          *string                  `json:"error,omitempty"`
+	CreatedAt      time.Time                `json:"createdAt"`
+	StartedAt      *time.Time               `json:"startedAt,omitempty"`
+	CompletedAt    *time.Time               `json:"completedAt,omitempty"`
+	ExpiresAt      *time.Time               `json:"expiresAt,omitempty"`
+}
+
+// VerificationExportEncoder writes verification events in one file format
+type VerificationExportEncoder interface {
+	Format() VerificationExportFormat
+	ContentType() string
+	// NewWriter starts a file on w
+	NewWriter(w io.Writer) VerificationExportWriter
+}
+
+// VerificationExportWriter writes an export file in batches of events, so large exports
+// never hold every event in memory
+type VerificationExportWriter interface {
+	Write(events []*VerificationEvent) error
+	// Close finishes the file; nothing may be written after it
+	Close() error
+}
+
+// VerificationExportRepository stores verification exports
+type VerificationExportRepository interface {
+	Create(export *VerificationExport) error
+	GetByID(id uuid.UUID) (*VerificationExport, error)
+	// ListByOrganization returns the organization's most recent exports, newest first
+	ListByOrganization(orgID uuid.UUID, limit int) ([]*VerificationExport, error)
+	// ClaimPending marks up to limit pending exports, and running exports started before
+	// staleBefore, as running and returns them oldest first
+	ClaimPending(limit int, staleBefore time.Time) ([]*VerificationExport, error)
+	// Finish stores the outcome of a claimed export: status, counts, file and error
+	Finish(export *VerificationExport) error
+}
@@ -10,41 +10,91 @@ import (
 type WebhookEvent string
 
 const (
-	WebhookEventAgentCreated      WebhookEvent = "agent.created"
-	WebhookEventAgentVerified     WebhookEvent = "agent.verified"
-	WebhookEventAgentSuspended    WebhookEvent = "agent.suspended"
-	WebhookEventTrustScoreChanged WebhookEvent = "trust_score.changed"
-	WebhookEventAlertCreated      WebhookEvent = "alert.created"
-	WebhookEventComplianceViolation WebhookEvent = "compliance.violation"
+	WebhookEventAgentCreated          WebhookEvent = "agent.created"
+	WebhookEventAgentVerified         WebhookEvent = "agent.verified"
+	WebhookEventAgentSuspended        WebhookEvent = "agent.suspended"
+	WebhookEventTrustScoreChanged     WebhookEvent = "trust_score.changed"
+	WebhookEventAlertCreated          WebhookEvent = "alert.created"
+	WebhookEventComplianceViolation   WebhookEvent = "compliance.violation"
+	WebhookEventVerificationCompleted WebhookEvent = "verification.completed"
+	WebhookEventDriftDetected         WebhookEvent = "drift.detected"                // Configuration or capability drift alert
+	WebhookEventThreatDetected        WebhookEvent = "threat.detected"               // Security breach or unusual activity alert
+	WebhookEventTrustScoreDropped     WebhookEvent = "trust_score.dropped"           // Trust score drop or low trust score alert
+	WebhookEventAttestationExpired    WebhookEvent = "attestation.expired"           // MCP attestation passed its expiry
+	WebhookEventTrustScoreThreshold   WebhookEvent = "trust_score.threshold_crossed" // A trust score trigger with the webhook action fired
+	// Results of an agent's attestation, delivered to the webhooks of the agent's owner
+	WebhookEventAttestationVerified WebhookEvent = "attestation.verified"
+	WebhookEventAttestationRejected WebhookEvent = "attestation.rejected"
+)
+
+// WebhookDeliveryStatus represents the state of a queued webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"   // Waiting in queue (or waiting for retry)
+	WebhookDeliverySending   WebhookDeliveryStatus = "sending"   // Claimed by a worker
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered" // Receiver answered with a 2xx status
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"    // Retries exhausted
+)
+
+// WebhookTemplateType selects the language of a webhook transformation template
+type WebhookTemplateType string
+
+const (
+	WebhookTemplateNone       WebhookTemplateType = ""            // Deliver the platform payload unchanged
+	WebhookTemplateGoTemplate WebhookTemplateType = "go_template" // Go text/template rendering to JSON
+	WebhookTemplateJQ         WebhookTemplateType = "jq"          // jq filter producing a single JSON value
 )
 
 // Webhook represents a webhook subscription
 type Webhook struct {
-	ID             uuid.UUID      `json:"id"`
-	OrganizationID uuid.UUID      `json:"organizationId"`
-	Name           string         `json:"name"`
-	URL            string         `json:"url"`
-	Events         []WebhookEvent `json:"events"`
-	Secret         string         `json:"secret"` // For webhook signature verification
-	IsActive       bool           `json:"isActive"`
-	LastTriggered  *time.Time     `json:"lastTriggered"`
-	FailureCount   int            `json:"failureCount"`
-	CreatedAt      time.Time      `json:"createdAt"`
-	UpdatedAt      time.Time      `json:"updatedAt"`
-	CreatedBy      uuid.UUID      `json:"createdBy"`
+	ID             uuid.UUID           `json:"id"`
+	OrganizationID uuid.UUID           `json:"organizationId"`
+	Name           string              `json:"name"`
+	URL            string              `json:"url"`
+	Events         []WebhookEvent      `json:"events"`
+	Filter         string              `json:"filter"`       // Optional CEL expression; only matching events are delivered
+	TemplateType   WebhookTemplateType `json:"templateType"` // Optional transformation applied to the payload before delivery
+	Template       string              `json:"template"`
+	Secret         string              `json:"secret"`      // For webhook signature verification
+	RequireMTLS    bool                `json:"requireMtls"` // Deliver only with the organization's client certificate
+	IsActive       bool                `json:"isActive"`
+	LastTriggered  *time.Time          `json:"lastTriggered"`
+	FailureCount   int                 `json:"failureCount"`
+	CreatedAt      time.Time           `json:"createdAt"`
+	UpdatedAt      time.Time           `json:"updatedAt"`
+	CreatedBy      uuid.UUID           `json:"createdBy"`
+}
+
+// WebhookEgressSettings holds how an organization's webhooks leave the platform: the client
+// certificate presented to receivers that require mTLS, and an optional dedicated egress proxy
+type WebhookEgressSettings struct {
+	OrganizationID    uuid.UUID  `json:"organizationId"`
+	ClientCertificate string     `json:"clientCertificate"` // PEM certificate chain; empty when mTLS is not configured
+	ClientKey         string     `json:"-"`                 // PEM private key, encrypted with the key vault
+	ProxyURL          string     `json:"proxyUrl"`          // Dedicated egress proxy; empty uses the shared egress
+	EgressIPs         []string   `json:"egressIps"`         // Source addresses of the dedicated proxy, published to receivers
+	UpdatedBy         *uuid.UUID `json:"updatedBy,omitempty"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
 }
 
-// WebhookDelivery represents a webhook delivery attempt
+// WebhookDelivery represents the delivery of one event to one webhook. Deliveries are queued
+// and retried with backoff; StatusCode and ResponseBody describe the latest attempt.
 type WebhookDelivery struct {
-	ID           uuid.UUID    `json:"id"`
-	WebhookID    uuid.UUID    `json:"webhookId"`
-	Event        WebhookEvent `json:"event"`
-	Payload      string       `json:"payload"`
-	StatusCode   int          `json:"statusCode"`
-	ResponseBody string       `json:"responseBody"`
-	Success      bool         `json:"success"`
-	AttemptCount int          `json:"attemptCount"`
-	CreatedAt    time.Time    `json:"createdAt"`
+	ID            uuid.UUID             `json:"id"`
+	WebhookID     uuid.UUID             `json:"webhookId"`
+	Event         WebhookEvent          `json:"event"`
+	Payload       string                `json:"payload"`
+	StatusCode    int                   `json:"statusCode"`
+	ResponseBody  string                `json:"responseBody"`
+	Success       bool                  `json:"success"`
+	AttemptCount  int                   `json:"attemptCount"`
+	Status        WebhookDeliveryStatus `json:"status"`
+	MaxAttempts   int                   `json:"maxAttempts"`
+	NextAttemptAt time.Time             `json:"nextAttemptAt"`
+	LastError     *string               `json:"lastError,omitempty"`
+	CreatedAt     time.Time             `json:"createdAt"`
+	UpdatedAt     time.Time             `json:"updatedAt"`
 }
 
 // WebhookRepository defines the interface for webhook persistence
@@ -56,4 +106,14 @@ type WebhookRepository interface {
 	Delete(id uuid.UUID) error
 	RecordDelivery(delivery *WebhookDelivery) error
 	GetDeliveries(webhookID uuid.UUID, limit, offset int) ([]*WebhookDelivery, error)
+
+	// Queue operations
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]*WebhookDelivery, error)
+	// RecordAttempt stores the outcome of a delivery attempt: delivered, pending with a
+	// retry at nextAttemptAt, or failed once retries are exhausted
+	RecordAttempt(id uuid.UUID, status WebhookDeliveryStatus, statusCode int, responseBody, errMsg string, nextAttemptAt time.Time) error
+
+	// GetEgressSettings returns nil when the organization has not configured webhook egress
+	GetEgressSettings(orgID uuid.UUID) (*WebhookEgressSettings, error)
+	UpsertEgressSettings(settings *WebhookEgressSettings) error
 }
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"time"
@@ -96,6 +98,12 @@ func (s *JWTService) GenerateTokenPair(userID, orgID, email, role string) (acces
 
 // GenerateAccessToken generates an access token
 func (s *JWTService) GenerateAccessToken(userID, orgID, email, role string) (string, error) {
+	return s.GenerateAccessTokenWithTTL(userID, orgID, email, role, s.accessExpiry)
+}
+
+// GenerateAccessTokenWithTTL generates an access token that expires after ttl instead of
+// the configured access token lifetime (used for short-lived emergency access)
+func (s *JWTService) GenerateAccessTokenWithTTL(userID, orgID, email, role string, ttl time.Duration) (string, error) {
 	now := time.Now()
 	claims := JWTClaims{
 		UserID:         userID,
@@ -103,7 +111,7 @@ func (s *JWTService) GenerateAccessToken(userID, orgID, email, role string) (str
 		Email:          email,
 		Role:           role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessExpiry)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "agent-identity-management",
@@ -202,6 +210,110 @@ func (s *JWTService) RefreshTokenPair(refreshToken string) (string, string, erro
 	return newAccessToken, newRefreshToken, nil
 }
 
+// mfaChallengeIssuer identifies MFA challenge tokens
+const mfaChallengeIssuer = "agent-identity-management-mfa"
+
+// GenerateMFAChallengeToken issues the token a user exchanges for a session after passing the
+// password step of a login that needs a second factor. It is signed with a key derived from the
+// JWT secret, so it is never accepted where an access or refresh token is expected.
+func (s *JWTService) GenerateMFAChallengeToken(userID, orgID string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := JWTClaims{
+		UserID:         userID,
+		OrganizationID: orgID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    mfaChallengeIssuer,
+			Subject:   userID,
+			ID:        uuid.New().String(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.mfaChallengeKey())
+}
+
+// ValidateMFAChallengeToken validates a token issued by GenerateMFAChallengeToken
+func (s *JWTService) ValidateMFAChallengeToken(tokenString string) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.mfaChallengeKey(), nil
+	}, jwt.WithIssuer(mfaChallengeIssuer))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+		return claims, nil
+	}
+
+	return nil, fmt.Errorf("invalid token")
+}
+
+func (s *JWTService) mfaChallengeKey() []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("mfa-challenge"))
+	return mac.Sum(nil)
+}
+
+// invitationIssuer identifies organization invitation tokens
+const invitationIssuer = "agent-identity-management-invitation"
+
+// GenerateInvitationToken issues the token of an invitation link. The invitation ID is the
+// subject, so the invitation's stored status decides whether the link still works; the token
+// only proves the link was issued by AIM and has not expired. Like MFA challenge tokens it is
+// signed with a key derived from the JWT secret.
+func (s *JWTService) GenerateInvitationToken(invitationID, orgID, email, role string, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	claims := JWTClaims{
+		OrganizationID: orgID,
+		Email:          email,
+		Role:           role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    invitationIssuer,
+			Subject:   invitationID,
+			ID:        uuid.New().String(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.invitationKey())
+}
+
+// ValidateInvitationToken validates a token issued by GenerateInvitationToken
+func (s *JWTService) ValidateInvitationToken(tokenString string) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.invitationKey(), nil
+	}, jwt.WithIssuer(invitationIssuer))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+		return claims, nil
+	}
+
+	return nil, fmt.Errorf("invalid token")
+}
+
+func (s *JWTService) invitationKey() []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("invitation"))
+	return mac.Sum(nil)
+}
+
 // GetTokenID extracts the JTI (token ID) from a JWT without full validation
 // Useful for token revocation checks before full validation
 func (s *JWTService) GetTokenID(tokenString string) (string, error) {
@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseXMLDocumentRejectsDTD(t *testing.T) {
+	_, err := parseXMLDocument([]byte(`<!DOCTYPE r [<!ENTITY e "x">]><r>&e;</r>`))
+	assert.Error(t, err)
+}
+
+// testIdP signs SAML responses the way an identity provider does
+type testIdP struct {
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testIdP{key: key, cert: cert}
+}
+
+func (idp *testIdP) pem() string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: idp.cert.Raw}))
+}
+
+// sign adds an enveloped signature to the element with the given ID, using the signature
+// method when one is given
+func (idp *testIdP) sign(t *testing.T, document, id string, signatureMethod ...string) string {
+	root, err := parseXMLDocument([]byte(document))
+	require.NoError(t, err)
+	element := findByID(root, id)
+	require.NotNil(t, element)
+
+	signer, err := dsig.NewSigningContext(idp.key, [][]byte{idp.cert.Raw})
+	require.NoError(t, err)
+	for _, method := range signatureMethod {
+		require.NoError(t, signer.SetSignatureMethod(method))
+	}
+	nsContext, err := etreeutils.NSBuildParentContext(element)
+	require.NoError(t, err)
+	detached, err := etreeutils.NSDetatch(nsContext, element)
+	require.NoError(t, err)
+	signed, err := signer.SignEnveloped(detached)
+	require.NoError(t, err)
+
+	if element == root {
+		root = signed
+	} else {
+		parent := element.Parent()
+		parent.InsertChildAt(element.Index(), signed)
+		parent.RemoveChild(element)
+	}
+	result, err := etree.NewDocumentWithRoot(root).WriteToString()
+	require.NoError(t, err)
+	return result
+}
+
+func findByID(e *etree.Element, id string) *etree.Element {
+	if xmlAttr(e, "ID") == id {
+		return e
+	}
+	for _, child := range e.ChildElements() {
+		if found := findByID(child, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+type testResponse struct {
+	audience     string
+	recipient    string
+	nameID       string
+	notOnOrAfter time.Time
+	inResponseTo string
+}
+
+func (r testResponse) xml() string {
+	now := time.Now().UTC()
+	expires := r.notOnOrAfter.UTC().Format(time.RFC3339)
+	return `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion"` +
+		` ID="_response" Version="2.0" IssueInstant="` + now.Format(time.RFC3339) + `" Destination="https://aim.example.com/acs" InResponseTo="` + r.inResponseTo + `">` +
+		`<saml:Issuer>https://idp.example.com</saml:Issuer>` +
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>` +
+		`<saml:Assertion ID="_assertion" Version="2.0" IssueInstant="` + now.Format(time.RFC3339) + `">` +
+		`<saml:Issuer>https://idp.example.com</saml:Issuer>` +
+		`<saml:Subject><saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress">` + r.nameID + `</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">` +
+		`<saml:SubjectConfirmationData Recipient="` + r.recipient + `" NotOnOrAfter="` + expires + `" InResponseTo="` + r.inResponseTo + `"/>` +
+		`</saml:SubjectConfirmation></saml:Subject>` +
+		`<saml:Conditions NotBefore="` + now.Add(-time.Minute).Format(time.RFC3339) + `" NotOnOrAfter="` + expires + `">` +
+		`<saml:AudienceRestriction><saml:Audience>` + r.audience + `</saml:Audience></saml:AudienceRestriction></saml:Conditions>` +
+		`<saml:AuthnStatement AuthnInstant="` + now.Format(time.RFC3339) + `" SessionIndex="_session"/>` +
+		`<saml:AttributeStatement>` +
+		`<saml:Attribute Name="http://schemas.xmlsoap.org/claims/Group" FriendlyName="groups">` +
+		`<saml:AttributeValue>aim-admins</saml:AttributeValue><saml:AttributeValue>engineering</saml:AttributeValue></saml:Attribute>` +
+		`<saml:Attribute Name="displayName"><saml:AttributeValue>Ada Lovelace</saml:AttributeValue></saml:Attribute>` +
+		`</saml:AttributeStatement></saml:Assertion></samlp:Response>`
+}
+
+func validTestResponse() testResponse {
+	return testResponse{
+		audience:     "https://aim.example.com/metadata",
+		recipient:    "https://aim.example.com/acs",
+		nameID:       "ada@example.com",
+		notOnOrAfter: time.Now().Add(5 * time.Minute),
+		inResponseTo: "_request",
+	}
+}
+
+func newTestServiceProvider(idp *testIdP) *SAMLServiceProvider {
+	return &SAMLServiceProvider{
+		EntityID:       "https://aim.example.com/metadata",
+		ACSURL:         "https://aim.example.com/acs",
+		IdPEntityID:    "https://idp.example.com",
+		IdPSSOURL:      "https://idp.example.com/sso?tenant=acme",
+		IdPCertificate: idp.cert,
+	}
+}
+
+func encodeResponse(document string) string {
+	return base64.StdEncoding.EncodeToString([]byte(document))
+}
+
+func TestParseResponseAcceptsSignedAssertion(t *testing.T) {
+	idp := newTestIdP(t)
+	cert, err := ParseSAMLCertificate(idp.pem())
+	require.NoError(t, err)
+	sp := newTestServiceProvider(idp)
+	sp.IdPCertificate = cert
+
+	for name, id := range map[string]string{"assertion signed": "_assertion", "response signed": "_response"} {
+		t.Run(name, func(t *testing.T) {
+			assertion, err := sp.ParseResponse(encodeResponse(idp.sign(t, validTestResponse().xml(), id)), time.Now())
+			require.NoError(t, err)
+			assert.Equal(t, "_assertion", assertion.ID)
+			assert.Equal(t, "ada@example.com", assertion.NameID)
+			assert.Equal(t, "_request", assertion.InResponseTo)
+			assert.Equal(t, "_session", assertion.SessionIndex)
+			assert.Equal(t, []string{"aim-admins", "engineering"}, assertion.Attributes["groups"])
+			assert.Equal(t, assertion.Attributes["groups"], assertion.Attributes["http://schemas.xmlsoap.org/claims/Group"])
+			assert.Equal(t, "Ada Lovelace", assertion.Attribute("displayName"))
+		})
+	}
+}
+
+func TestParseResponseRejectsInvalidResponses(t *testing.T) {
+	idp := newTestIdP(t)
+	sp := newTestServiceProvider(idp)
+	now := time.Now()
+
+	signed := idp.sign(t, validTestResponse().xml(), "_assertion")
+	withResponse := func(mutate func(*testResponse)) string {
+		response := validTestResponse()
+		mutate(&response)
+		return idp.sign(t, response.xml(), "_assertion")
+	}
+
+	cases := map[string]string{
+		"unsigned":         validTestResponse().xml(),
+		"tampered subject": strings.Replace(signed, "ada@example.com", "admin@example.com", 1),
+		"other audience":   withResponse(func(r *testResponse) { r.audience = "https://other.example.com" }),
+		"other recipient":  withResponse(func(r *testResponse) { r.recipient = "https://other.example.com/acs" }),
+		"expired":          withResponse(func(r *testResponse) { r.notOnOrAfter = now.Add(-10 * time.Minute) }),
+		// A second, unsigned assertion must not be picked up in place of the signed one
+		"wrapped assertion": strings.Replace(signed, "<saml:Assertion ",
+			`<saml:Assertion ID="_evil"><saml:Issuer>https://idp.example.com</saml:Issuer></saml:Assertion><saml:Assertion `, 1),
+	}
+	for name, document := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := sp.ParseResponse(encodeResponse(document), now)
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("SHA-1 signature", func(t *testing.T) {
+		_, err := sp.ParseResponse(encodeResponse(idp.sign(t, validTestResponse().xml(), "_assertion", dsig.RSASHA1SignatureMethod)), now)
+		assert.ErrorContains(t, err, "unsupported signature algorithm")
+	})
+
+	t.Run("other identity provider", func(t *testing.T) {
+		other := newTestIdP(t)
+		_, err := sp.ParseResponse(encodeResponse(other.sign(t, validTestResponse().xml(), "_assertion")), now)
+		assert.ErrorContains(t, err, "signature verification failed")
+	})
+}
+
+func TestAuthnRequestURL(t *testing.T) {
+	sp := newTestServiceProvider(newTestIdP(t))
+
+	redirect, requestID, err := sp.AuthnRequestURL("/dashboard", time.Now())
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(requestID, "_"))
+
+	parsed, err := url.Parse(redirect)
+	require.NoError(t, err)
+	assert.Equal(t, "idp.example.com", parsed.Host)
+	assert.Equal(t, "acme", parsed.Query().Get("tenant"), "existing query parameters are kept")
+	assert.Equal(t, "/dashboard", parsed.Query().Get("RelayState"))
+
+	deflated, err := base64.StdEncoding.DecodeString(parsed.Query().Get("SAMLRequest"))
+	require.NoError(t, err)
+	request, err := io.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+	require.NoError(t, err)
+	assert.Contains(t, string(request), `ID="`+requestID+`"`)
+	assert.Contains(t, string(request), `AssertionConsumerServiceURL="https://aim.example.com/acs"`)
+
+	assert.Contains(t, string(sp.Metadata()), `entityID="https://aim.example.com/metadata"`)
+}
+
+func TestSAMLStateStore(t *testing.T) {
+	store := NewSAMLStateStore()
+	now := time.Now()
+
+	store.TrackRequest("_request", now)
+	assert.True(t, store.ConsumeRequest("_request", now))
+	assert.False(t, store.ConsumeRequest("_request", now), "requests are answered once")
+	store.TrackRequest("_stale", now)
+	assert.False(t, store.ConsumeRequest("_stale", now.Add(time.Hour)))
+
+	assert.True(t, store.ConsumeAssertion("_assertion", now.Add(time.Minute), now))
+	assert.False(t, store.ConsumeAssertion("_assertion", now.Add(time.Minute), now), "assertions cannot be replayed")
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// identityCacheSweepInterval is how often the in-memory cache drops expired entries
+const identityCacheSweepInterval = time.Minute
+
+// Identity records are gob encoded rather than JSON encoded: fields kept out of API responses
+// (json:"-"), such as an agent's encrypted private key, must survive the round trip.
+func encodeIdentity(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeIdentity(data []byte, dest interface{}) error {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(dest); err != nil {
+		return fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+	return nil
+}
+
+// MemoryIdentityCache keeps identity records in memory. Each server instance caches on its own
+// and only sees the invalidations it made itself, so it suits single node deployments.
+type MemoryIdentityCache struct {
+	entries map[string]memoryEntry
+	sweptAt time.Time
+	mu      sync.Mutex
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// NewMemoryIdentityCache creates a new in-memory identity cache
+func NewMemoryIdentityCache() *MemoryIdentityCache {
+	return &MemoryIdentityCache{
+		entries: make(map[string]memoryEntry),
+		sweptAt: time.Now(),
+		now:     time.Now,
+	}
+}
+
+// Get reads the entry under key into dest
+func (c *MemoryIdentityCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if !ok || !c.now().Before(entry.expiresAt) {
+		return domain.ErrCacheMiss
+	}
+	return decodeIdentity(entry.data, dest)
+}
+
+// Set stores value under key until ttl has passed
+func (c *MemoryIdentityCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := encodeIdentity(value)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if now.Sub(c.sweptAt) > identityCacheSweepInterval {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.sweptAt = now
+	}
+	c.entries[key] = memoryEntry{data: data, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Delete drops the entries under keys
+func (c *MemoryIdentityCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+// RedisIdentityCache keeps identity records in Redis, so all server instances share the entries
+// and each other's invalidations
+type RedisIdentityCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisIdentityCache creates a new identity cache on the cache's Redis connection
+func NewRedisIdentityCache(cache *RedisCache) *RedisIdentityCache {
+	return &RedisIdentityCache{client: cache.client, prefix: "aim:identity:"}
+}
+
+// Get reads the entry under key into dest
+func (c *RedisIdentityCache) Get(ctx context.Context, key string, dest interface{}) error {
+	data, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if err == redis.Nil {
+		return domain.ErrCacheMiss
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cache entry: %w", err)
+	}
+	return decodeIdentity(data, dest)
+}
+
+// Set stores value under key until ttl has passed
+func (c *RedisIdentityCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := encodeIdentity(value)
+	if err != nil {
+		return err
+	}
+	if err := c.client.Set(ctx, c.prefix+key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Delete drops the entries under keys
+func (c *RedisIdentityCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	if err := c.client.Del(ctx, prefixed...).Err(); err != nil {
+		return fmt.Errorf("failed to delete cache entries: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityCacheKeepsCopiesOfRecordsUntilTheyExpireOrAreDropped(t *testing.T) {
+	ctx := context.Background()
+	identityCache := NewMemoryIdentityCache()
+
+	privateKey := "encrypted-private-key"
+	agent := &domain.Agent{
+		ID:                  uuid.New(),
+		Name:                "billing-agent",
+		Status:              domain.AgentStatusVerified,
+		EncryptedPrivateKey: &privateKey,
+		Capabilities:        []string{"api:call"},
+		CreatedAt:           time.Now().UTC().Truncate(time.Second),
+	}
+	key := "agent:" + agent.ID.String()
+
+	var cached domain.Agent
+	assert.ErrorIs(t, identityCache.Get(ctx, key, &cached), domain.ErrCacheMiss)
+
+	// Fields kept out of API responses survive the round trip
+	require.NoError(t, identityCache.Set(ctx, key, agent, time.Minute))
+	require.NoError(t, identityCache.Get(ctx, key, &cached))
+	assert.Equal(t, agent.Name, cached.Name)
+	require.NotNil(t, cached.EncryptedPrivateKey)
+	assert.Equal(t, privateKey, *cached.EncryptedPrivateKey)
+	assert.True(t, agent.CreatedAt.Equal(cached.CreatedAt))
+
+	// Changing a record read from the cache, or the record that was cached, leaves the entry alone
+	cached.Capabilities[0] = "file:write"
+	agent.Status = domain.AgentStatusRevoked
+	var again domain.Agent
+	require.NoError(t, identityCache.Get(ctx, key, &again))
+	assert.Equal(t, []string{"api:call"}, again.Capabilities)
+	assert.Equal(t, domain.AgentStatusVerified, again.Status)
+
+	// Dropped and expired entries are misses
+	require.NoError(t, identityCache.Delete(ctx, key, "agent:missing"))
+	assert.ErrorIs(t, identityCache.Get(ctx, key, &again), domain.ErrCacheMiss)
+
+	require.NoError(t, identityCache.Set(ctx, key, agent, time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	assert.ErrorIs(t, identityCache.Get(ctx, key, &again), domain.ErrCacheMiss)
+}
@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// rateLimitSweepInterval is how often the in-memory store drops the buckets of idle callers
+const rateLimitSweepInterval = 5 * time.Minute
+
+// MemoryRateLimitStore keeps token buckets in memory. Each server instance limits on its own,
+// so it suits single node deployments.
+type MemoryRateLimitStore struct {
+	buckets map[string]*memoryBucket
+	sweptAt time.Time
+	mu      sync.Mutex
+	now     func() time.Time
+}
+
+type memoryBucket struct {
+	tokens     float64
+	refilledAt time.Time
+	fullAt     time.Time // When the bucket is full again; it can be dropped from then on
+}
+
+// NewMemoryRateLimitStore creates a new in-memory rate limit store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*memoryBucket),
+		sweptAt: time.Now(),
+		now:     time.Now,
+	}
+}
+
+// Take takes a token from the bucket under key
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, limit domain.RateLimit) (*domain.RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.sweptAt) > rateLimitSweepInterval {
+		for k, bucket := range s.buckets {
+			if now.After(bucket.fullAt) {
+				delete(s.buckets, k)
+			}
+		}
+		s.sweptAt = now
+	}
+
+	capacity := float64(limit.RequestsPerMinute + limit.Burst)
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: capacity}
+		s.buckets[key] = bucket
+	} else {
+		perSecond := float64(limit.RequestsPerMinute) / 60
+		bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.refilledAt).Seconds()*perSecond)
+	}
+	bucket.refilledAt = now
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+	result := tokenBucketResult(limit, bucket.tokens, allowed)
+	bucket.fullAt = now.Add(result.ResetAfter)
+	return result, nil
+}
+
+// takeTokenScript refills and takes from a token bucket stored as a hash, using the Redis
+// server's clock so every instance sees the same time. The bucket expires once it is full again.
+var takeTokenScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local per_ms = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+if tokens == nil then
+  tokens = capacity
+else
+  tokens = math.min(capacity, tokens + math.max(0, now - tonumber(bucket[2])) * per_ms)
+end
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.max(1000, math.ceil((capacity - tokens) / per_ms)))
+return {allowed, tostring(tokens)}
+`)
+
+// RedisRateLimitStore keeps token buckets in Redis, so all server instances share them
+type RedisRateLimitStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisRateLimitStore creates a new rate limit store on the cache's Redis connection
+func NewRedisRateLimitStore(cache *RedisCache) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: cache.client, prefix: "aim:ratelimit:"}
+}
+
+// Take takes a token from the bucket under key
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, limit domain.RateLimit) (*domain.RateLimitResult, error) {
+	capacity := limit.RequestsPerMinute + limit.Burst
+	perMs := float64(limit.RequestsPerMinute) / float64(time.Minute/time.Millisecond)
+
+	reply, err := takeTokenScript.Run(ctx, s.client, []string{s.prefix + key},
+		capacity,
+		strconv.FormatFloat(perMs, 'f', -1, 64),
+	).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	if len(reply) != 2 {
+		return nil, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+	}
+	allowed, _ := reply[0].(int64)
+	tokensText, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(tokensText, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+	}
+	return tokenBucketResult(limit, tokens, allowed == 1), nil
+}
+
+// tokenBucketResult describes a bucket holding tokens after a request was allowed or rejected
+func tokenBucketResult(limit domain.RateLimit, tokens float64, allowed bool) *domain.RateLimitResult {
+	result := &domain.RateLimitResult{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: int(math.Floor(tokens)),
+	}
+	perSecond := float64(limit.RequestsPerMinute) / 60
+	if perSecond > 0 {
+		capacity := float64(limit.RequestsPerMinute + limit.Burst)
+		result.ResetAfter = time.Duration((capacity - tokens) / perSecond * float64(time.Second))
+		if !allowed {
+			result.RetryAfter = time.Duration((1 - tokens) / perSecond * float64(time.Second))
+		}
+	}
+	return result
+}
@@ -0,0 +1,645 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// versionTable is where golang-migrate records the schema version. schema_migrations predates
+	// it and keeps the history of applied migrations, with their checksums and durations.
+	versionTable = "schema_version"
+
+	// onlineDirective marks a migration that runs statement by statement outside a transaction.
+	// Online migrations may use CREATE INDEX CONCURRENTLY, and each statement waits at most the
+	// lock timeout for its locks, so busy tables like verification_events keep serving traffic.
+	// A failed online migration leaves the schema dirty; its statements must be safe to run again.
+	onlineDirective = "-- aim:online"
+
+	// repeatDirective marks a statement of an online migration that is run again until it affects
+	// no rows, for backfilling large tables in batches
+	repeatDirective = "-- aim:repeat"
+
+	// lockNotAvailable is the SQLSTATE of a statement that gave up waiting for a lock
+	lockNotAvailable = "55P03"
+
+	// undefinedTable is the SQLSTATE of a query on a table that doesn't exist
+	undefinedTable = "42P01"
+)
+
+// Migrator applies the SQL migrations in files with golang-migrate. Online migrations are run
+// statement by statement, and every applied migration is recorded in the schema_migrations
+// table, with the checksum of the file and how long it took.
+type Migrator struct {
+	db          *sql.DB
+	files       fs.FS
+	lockTimeout time.Duration // How long each online statement waits for its locks
+	lockRetries int           // How often an online statement is retried after its lock wait timed out
+	now         func() time.Time
+}
+
+// NewMigrator creates a migrator for the migration files in files, named
+// {version}_{title}.up.sql
+func NewMigrator(db *sql.DB, files fs.FS, lockTimeout time.Duration) *Migrator {
+	return &Migrator{
+		db:          db,
+		files:       files,
+		lockTimeout: lockTimeout,
+		lockRetries: 5,
+		now:         time.Now,
+	}
+}
+
+type migrationFile struct {
+	version  uint
+	name     string // The filename without .up.sql, e.g. 075_create_agent_peer_policies
+	checksum string
+	online   bool
+}
+
+type migrationStatement struct {
+	sql    string
+	repeat bool
+}
+
+// sqlExecer is satisfied by *sql.Conn and *sql.Tx
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Status compares the schema version and the applied migrations to the migration files
+func (m *Migrator) Status(ctx context.Context) (*domain.SchemaStatus, error) {
+	if err := ensureMigrationsTable(ctx, m.db); err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	files, err := readMigrationFiles(m.files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration files: %w", err)
+	}
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	version, dirty, err := m.schemaVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema version: %w", err)
+	}
+
+	status := &domain.SchemaStatus{
+		Dirty:   dirty,
+		Pending: []domain.SchemaMigration{},
+		History: applied,
+	}
+	if version != migratedb.NilVersion {
+		// A version this build doesn't ship was applied by a newer one
+		status.CurrentVersion = strconv.Itoa(version)
+	}
+
+	checksums := make(map[string]string, len(files))
+	for _, file := range files {
+		checksums[file.name] = file.checksum
+		status.LatestVersion = file.name
+		if version != migratedb.NilVersion && file.version <= uint(version) {
+			if file.version == uint(version) {
+				status.CurrentVersion = file.name
+			}
+			continue
+		}
+		status.Pending = append(status.Pending, domain.SchemaMigration{
+			Version:  file.name,
+			Online:   file.online,
+			Checksum: file.checksum,
+		})
+	}
+	for i := range status.History {
+		record := &status.History[i]
+		checksum, shipped := checksums[record.Version]
+		record.Missing = !shipped
+		// Migrations applied before checksums were recorded can't be compared
+		record.Modified = shipped && record.Checksum != "" && record.Checksum != checksum
+	}
+	status.UpToDate = len(status.Pending) == 0 && !dirty
+
+	return status, nil
+}
+
+// Apply applies the pending migrations in version order. It holds golang-migrate's advisory lock
+// while doing so; instances calling it together wait for each other and find nothing left to
+// apply. A migration that fails leaves the schema dirty at its version until it is fixed and the
+// version forced.
+func (m *Migrator) Apply(ctx context.Context) ([]domain.SchemaMigration, error) {
+	if err := ensureMigrationsTable(ctx, m.db); err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	migration, runner, err := m.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer migration.Close()
+
+	// golang-migrate gives up waiting for the lock after a few seconds, while another instance's
+	// migrations may take far longer; the lock is taken here and Up runs under it
+	if err := runner.Driver.Lock(); err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	runner.locked = true
+	defer func() {
+		runner.locked = false
+		runner.Driver.Unlock()
+	}()
+
+	if err := runner.adoptLegacyVersion(ctx); err != nil {
+		return nil, fmt.Errorf("failed to adopt schema version: %w", err)
+	}
+	if err := migration.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return runner.applied, err
+	}
+	return runner.applied, nil
+}
+
+// Force records version as the clean schema version without running any migration, after the
+// migration that left the schema dirty was completed or undone by hand
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	migration, _, err := m.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer migration.Close()
+
+	return migration.Force(version)
+}
+
+// open sets up golang-migrate on a connection of its own, which it closes
+func (m *Migrator) open(ctx context.Context) (*migrate.Migrate, *migrationRunner, error) {
+	files, err := readMigrationFiles(m.files)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read migration files: %w", err)
+	}
+	src, err := iofs.New(m.files, ".")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read migration files: %w", err)
+	}
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		src.Close()
+		return nil, nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	// Migrations and waiting for another instance's may take longer than the statement timeout
+	// the server sets for requests
+	if _, err := conn.ExecContext(ctx, "SET statement_timeout = 0"); err != nil {
+		conn.Close()
+		src.Close()
+		return nil, nil, fmt.Errorf("failed to disable statement timeout: %w", err)
+	}
+	runner := &migrationRunner{
+		migrator: m,
+		ctx:      ctx,
+		conn:     conn,
+		files:    make(map[uint]migrationFile, len(files)),
+	}
+	for _, file := range files {
+		runner.files[file.version] = file
+	}
+
+	runner.Driver, err = postgres.WithConnection(ctx, conn, &postgres.Config{MigrationsTable: versionTable})
+	if err != nil {
+		conn.ExecContext(context.Background(), "RESET statement_timeout")
+		conn.Close()
+		src.Close()
+		return nil, nil, fmt.Errorf("failed to prepare schema version table: %w", err)
+	}
+	migration, err := migrate.NewWithInstance("iofs", src, "postgres", runner)
+	if err != nil {
+		runner.Close()
+		src.Close()
+		return nil, nil, err
+	}
+	return migration, runner, nil
+}
+
+// migrationRunner is the database driver golang-migrate runs migrations with. The postgres
+// driver keeps the schema version and the lock; migrations are run here, online migrations
+// statement by statement, and recorded in schema_migrations.
+type migrationRunner struct {
+	migratedb.Driver
+	migrator *Migrator
+	ctx      context.Context
+	conn     *sql.Conn
+	files    map[uint]migrationFile
+	locked   bool // Apply holds the lock
+
+	current migrationFile // The migration being applied
+	started time.Time
+	applied []domain.SchemaMigration
+}
+
+// Lock takes the lock unless Apply already holds it
+func (r *migrationRunner) Lock() error {
+	if r.locked {
+		return nil
+	}
+	return r.Driver.Lock()
+}
+
+// Unlock releases the lock unless Apply holds it
+func (r *migrationRunner) Unlock() error {
+	if r.locked {
+		return nil
+	}
+	return r.Driver.Unlock()
+}
+
+// Close returns the connection to the pool without the migration settings
+func (r *migrationRunner) Close() error {
+	r.conn.ExecContext(context.Background(), "RESET statement_timeout")
+	return r.Driver.Close()
+}
+
+// SetVersion is called with dirty set before a migration runs and cleared after it ran, when
+// the migration is recorded
+func (r *migrationRunner) SetVersion(version int, dirty bool) error {
+	if err := r.Driver.SetVersion(version, dirty); err != nil {
+		return err
+	}
+	file, ok := r.files[uint(version)]
+	if version == migratedb.NilVersion || !ok {
+		return nil
+	}
+
+	if dirty {
+		mode := ""
+		if file.online {
+			mode = " (online)"
+		}
+		log.Printf("🔄 Applying %s%s...", file.name, mode)
+		r.current, r.started = file, r.migrator.now()
+		return nil
+	}
+	// Force sets a clean version without applying anything
+	if r.started.IsZero() || r.current.version != file.version {
+		return nil
+	}
+
+	appliedAt := r.migrator.now().UTC()
+	migration := domain.SchemaMigration{
+		Version:    file.name,
+		Online:     file.online,
+		Checksum:   file.checksum,
+		AppliedAt:  &appliedAt,
+		DurationMs: appliedAt.Sub(r.started).Milliseconds(),
+	}
+	r.started = time.Time{}
+	if err := record(r.ctx, r.conn, migration); err != nil {
+		return err
+	}
+	r.applied = append(r.applied, migration)
+	log.Printf("✅ Applied %s", file.name)
+	return nil
+}
+
+// Run runs the migration golang-migrate read from the file
+func (r *migrationRunner) Run(migration io.Reader) error {
+	body, err := io.ReadAll(migration)
+	if err != nil {
+		return err
+	}
+	if hasDirective(string(body), onlineDirective) {
+		err = r.migrator.applyOnline(r.ctx, r.conn, string(body))
+	} else {
+		err = applyInTransaction(r.ctx, r.conn, string(body))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply %s: %w", r.current.name, err)
+	}
+	return nil
+}
+
+// adoptLegacyVersion gives a database last migrated by the runner AIM used before golang-migrate
+// the version of the latest migration in its history. The caller holds the lock.
+func (r *migrationRunner) adoptLegacyVersion(ctx context.Context) error {
+	version, _, err := r.Driver.Version()
+	if err != nil || version != migratedb.NilVersion {
+		return err
+	}
+	legacy, err := r.migrator.historyVersion(ctx)
+	if err != nil || legacy == migratedb.NilVersion {
+		return err
+	}
+	log.Printf("ℹ️  Adopting schema version %d from the migration history", legacy)
+	return r.Driver.SetVersion(legacy, false)
+}
+
+// applyInTransaction runs the whole migration in one transaction
+func applyInTransaction(ctx context.Context, conn *sql.Conn, body string) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// applyOnline runs the migration's statements one at a time, each waiting at most the lock
+// timeout for its locks and retried with backoff when the wait times out
+func (m *Migrator) applyOnline(ctx context.Context, conn *sql.Conn, body string) error {
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("SET lock_timeout = '%dms'", m.lockTimeout.Milliseconds())); err != nil {
+		return fmt.Errorf("failed to set lock timeout: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "RESET lock_timeout")
+
+	for i, statement := range splitStatements(body) {
+		for batch := 1; ; batch++ {
+			affected, err := m.execWithLockRetry(ctx, conn, statement.sql)
+			if err != nil {
+				return fmt.Errorf("statement %d: %w", i+1, err)
+			}
+			if !statement.repeat || affected == 0 {
+				break
+			}
+			if batch%100 == 0 {
+				log.Printf("   … statement %d: %d batches applied", i+1, batch)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) execWithLockRetry(ctx context.Context, conn *sql.Conn, statement string) (int64, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := conn.ExecContext(ctx, statement)
+		if err == nil {
+			affected, _ := result.RowsAffected()
+			return affected, nil
+		}
+
+		var pqErr *pq.Error
+		if !errors.As(err, &pqErr) || pqErr.Code != lockNotAvailable || attempt > m.lockRetries {
+			return 0, err
+		}
+		backoff := time.Duration(attempt) * time.Second
+		log.Printf("⚠️  Lock wait timed out, retrying in %s (attempt %d of %d)", backoff, attempt, m.lockRetries)
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// record adds the migration to the history. A migration run again after its version was forced
+// replaces its earlier record.
+func record(ctx context.Context, exec sqlExecer, migration domain.SchemaMigration) error {
+	_, err := exec.ExecContext(ctx, `
+		INSERT INTO schema_migrations (version, applied_at, checksum, duration_ms, online)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (version) DO UPDATE SET
+			applied_at = EXCLUDED.applied_at,
+			checksum = EXCLUDED.checksum,
+			duration_ms = EXCLUDED.duration_ms,
+			online = EXCLUDED.online
+	`, migration.Version, *migration.AppliedAt, migration.Checksum, migration.DurationMs, migration.Online)
+	if err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+	return nil
+}
+
+// schemaVersion returns the schema version golang-migrate recorded. It reads the version table
+// directly, as golang-migrate's driver waits for the lock a running migration holds. A database
+// golang-migrate hasn't migrated yet has the version of its migration history.
+func (m *Migrator) schemaVersion(ctx context.Context) (int, bool, error) {
+	var version int64
+	var dirty bool
+	err := m.db.QueryRowContext(ctx, `SELECT version, dirty FROM `+versionTable+` LIMIT 1`).Scan(&version, &dirty)
+	if err == nil {
+		return int(version), dirty, nil
+	}
+
+	var pqErr *pq.Error
+	if !errors.Is(err, sql.ErrNoRows) && !(errors.As(err, &pqErr) && pqErr.Code == undefinedTable) {
+		return 0, false, err
+	}
+	legacy, err := m.historyVersion(ctx)
+	return legacy, false, err
+}
+
+// historyVersion returns the highest version in schema_migrations, or NilVersion for an empty
+// history
+func (m *Migrator) historyVersion(ctx context.Context) (int, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	highest := migratedb.NilVersion
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return 0, err
+		}
+		prefix, _, _ := strings.Cut(name, "_")
+		if version, err := strconv.Atoi(prefix); err == nil && version > highest {
+			highest = version
+		}
+	}
+	return highest, rows.Err()
+}
+
+// appliedMigrations returns the recorded migrations, newest first
+func (m *Migrator) appliedMigrations(ctx context.Context) ([]domain.SchemaMigration, error) {
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT version, applied_at, checksum, duration_ms, online
+		FROM schema_migrations
+		ORDER BY applied_at DESC, version DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	migrations := []domain.SchemaMigration{}
+	for rows.Next() {
+		var migration domain.SchemaMigration
+		var appliedAt time.Time
+		var checksum sql.NullString
+		var durationMs sql.NullInt64
+		if err := rows.Scan(&migration.Version, &appliedAt, &checksum, &durationMs, &migration.Online); err != nil {
+			return nil, err
+		}
+		// The runner before golang-migrate recorded filenames, with the .sql extension
+		migration.Version = strings.TrimSuffix(migration.Version, ".sql")
+		migration.AppliedAt = &appliedAt
+		migration.Checksum = checksum.String
+		migration.DurationMs = durationMs.Int64
+		migrations = append(migrations, migration)
+	}
+	return migrations, rows.Err()
+}
+
+func ensureMigrationsTable(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			id SERIAL PRIMARY KEY,
+			version VARCHAR(255) NOT NULL UNIQUE,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS checksum VARCHAR(64);
+		ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS duration_ms BIGINT;
+		ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS online BOOLEAN NOT NULL DEFAULT false;
+	`)
+	return err
+}
+
+// readMigrationFiles returns the up migrations sorted by version, parsed like golang-migrate
+// parses them. A missing directory has no migrations.
+func readMigrationFiles(files fs.FS) ([]migrationFile, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var migrations []migrationFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		parsed, err := source.DefaultParse(name)
+		if err != nil || parsed.Direction != source.Up {
+			continue
+		}
+		content, err := fs.ReadFile(files, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		sum := sha256.Sum256(content)
+		migrations = append(migrations, migrationFile{
+			version:  parsed.Version,
+			name:     strings.TrimSuffix(name, ".up.sql"),
+			checksum: hex.EncodeToString(sum[:]),
+			online:   hasDirective(string(content), onlineDirective),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	return migrations, nil
+}
+
+func hasDirective(text, directive string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == directive {
+			return true
+		}
+	}
+	return false
+}
+
+var dollarQuoteTag = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+// splitStatements splits a migration into its statements at the semicolons outside string
+// literals, quoted identifiers, dollar-quoted bodies and comments. A statement preceded by a
+// repeatDirective comment is marked to repeat.
+func splitStatements(text string) []migrationStatement {
+	var statements []migrationStatement
+	var current strings.Builder
+	hasCode, repeat := false, false
+
+	flush := func() {
+		if hasCode {
+			statements = append(statements, migrationStatement{sql: strings.TrimSpace(current.String()), repeat: repeat})
+		}
+		current.Reset()
+		hasCode, repeat = false, false
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch {
+		case strings.HasPrefix(rest, "--"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			if strings.TrimSpace(rest[:end]) == repeatDirective {
+				repeat = true
+			}
+			current.WriteString(rest[:end])
+			i += end
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 4
+			}
+			current.WriteString(rest[:end])
+			i += end
+		case rest[0] == '\'' || rest[0] == '"':
+			// Doubled quotes inside the literal end it and start another one, which keeps the text intact
+			end := strings.IndexByte(rest[1:], rest[0])
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 2
+			}
+			current.WriteString(rest[:end])
+			hasCode = true
+			i += end
+		case rest[0] == '$' && dollarQuoteTag.MatchString(rest):
+			tag := dollarQuoteTag.FindString(rest)
+			end := strings.Index(rest[len(tag):], tag)
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 2 * len(tag)
+			}
+			current.WriteString(rest[:end])
+			hasCode = true
+			i += end
+		case rest[0] == ';':
+			current.WriteByte(';')
+			flush()
+			i++
+		default:
+			if rest[0] != ' ' && rest[0] != '\t' && rest[0] != '\n' && rest[0] != '\r' {
+				hasCode = true
+			}
+			current.WriteByte(rest[0])
+			i++
+		}
+	}
+	flush()
+
+	return statements
+}
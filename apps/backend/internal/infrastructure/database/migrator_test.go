@@ -0,0 +1,92 @@
+package database
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/opena2a/identity/backend/migrations"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitStatements(t *testing.T) {
+	statements := splitStatements(`-- Migration: Backfill
+-- aim:online
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_events_kind ON verification_events(kind);
+COMMENT ON COLUMN verification_events.kind IS 'Kind; one of a, b';
+
+CREATE OR REPLACE FUNCTION touch() RETURNS trigger AS $body$
+BEGIN
+    NEW.updated_at = NOW(); -- not the end of the statement
+    RETURN NEW;
+END;
+$body$ LANGUAGE plpgsql;
+
+/* batches of 10000; */
+-- aim:repeat
+UPDATE verification_events SET kind = 'a'
+WHERE id IN (SELECT id FROM verification_events WHERE kind IS NULL LIMIT 10000);
+-- trailing comment`)
+
+	require.Len(t, statements, 4)
+	assert.True(t, strings.HasSuffix(statements[0].sql, "\nCREATE INDEX CONCURRENTLY IF NOT EXISTS idx_events_kind ON verification_events(kind);"))
+	assert.Equal(t, "COMMENT ON COLUMN verification_events.kind IS 'Kind; one of a, b';", statements[1].sql)
+	assert.Contains(t, statements[2].sql, "RETURN NEW;")
+	assert.True(t, strings.HasSuffix(statements[2].sql, "$body$ LANGUAGE plpgsql;"))
+	assert.False(t, statements[2].repeat)
+	assert.True(t, statements[3].repeat)
+	assert.Contains(t, statements[3].sql, "LIMIT 10000);")
+}
+
+func TestReadMigrationFiles(t *testing.T) {
+	files := fstest.MapFS{
+		"002_backfill.up.sql":      {Data: []byte("-- aim:online\nUPDATE agents SET x = 1;")},
+		"001_initial.up.sql":       {Data: []byte("CREATE TABLE agents (id UUID);")},
+		"001_initial.down.sql":     {Data: []byte("DROP TABLE agents;")},
+		"README.md":                {Data: []byte("not a migration")},
+		"archive/000_old.up.sql":   {Data: []byte("SELECT 1;")},
+		"003_comment_only.up.sql":  {Data: []byte("-- mentions -- aim:online inline only\nSELECT 1;")},
+		"010_after_three.up.sql":   {Data: []byte("SELECT 1;")},
+		"004_another_table.up.sql": {Data: []byte("CREATE TABLE other (id UUID);")},
+	}
+
+	migrations, err := readMigrationFiles(files)
+	require.NoError(t, err)
+
+	var names []string
+	var versions []uint
+	for _, migration := range migrations {
+		names = append(names, migration.name)
+		versions = append(versions, migration.version)
+	}
+	assert.Equal(t, []string{"001_initial", "002_backfill", "003_comment_only", "004_another_table", "010_after_three"}, names)
+	assert.Equal(t, []uint{1, 2, 3, 4, 10}, versions)
+	assert.False(t, migrations[0].online)
+	assert.True(t, migrations[1].online)
+	assert.False(t, migrations[2].online)
+	assert.Len(t, migrations[0].checksum, 64)
+	assert.NotEqual(t, migrations[0].checksum, migrations[1].checksum)
+
+	missing, err := readMigrationFiles(os.DirFS(filepath.Join(t.TempDir(), "migrations")))
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	entries, err := fs.ReadDir(migrations.Files, ".")
+	require.NoError(t, err)
+
+	// Every embedded file is an up migration golang-migrate reads, with a version of its own
+	files, err := readMigrationFiles(migrations.Files)
+	require.NoError(t, err)
+	assert.Len(t, files, len(entries))
+	_, err = iofs.New(migrations.Files, ".")
+	require.NoError(t, err)
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// RegionalTables are the tables whose rows are stored in the cluster of their organization's
+// region once regional clusters are configured
+var RegionalTables = []string{"agents", "verification_events", "mcp_attestations"}
+
+// DetachRegionalForeignKeys drops the foreign keys that cannot hold once rows are split between
+// the control plane and the regional clusters. On the control plane these are the keys referencing
+// regional tables, whose rows may be in another cluster; on a regional cluster they are the keys
+// of regional tables referencing control-plane tables, which stay empty there. It is idempotent
+// and run on every start with regional clusters configured.
+func DetachRegionalForeignKeys(ctx context.Context, db *sql.DB, controlPlane bool) (int, error) {
+	tables := "'" + strings.Join(RegionalTables, "', '") + "'"
+	condition := fmt.Sprintf(`c.confrelid::regclass::text IN (%[1]s) AND c.conrelid::regclass::text NOT IN (%[1]s)`, tables)
+	if !controlPlane {
+		condition = fmt.Sprintf(`c.conrelid::regclass::text IN (%[1]s) AND c.confrelid::regclass::text NOT IN (%[1]s)`, tables)
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT c.conrelid::regclass::text, c.conname
+		FROM pg_constraint c
+		WHERE c.contype = 'f' AND `+condition)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find regional foreign keys: %w", err)
+	}
+	type foreignKey struct{ table, name string }
+	var keys []foreignKey
+	for rows.Next() {
+		var key foreignKey
+		if err := rows.Scan(&key.table, &key.name); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		// regclass::text is already quoted where the table name needs it
+		statement := fmt.Sprintf(`ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s`, key.table, pq.QuoteIdentifier(key.name))
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return 0, fmt.Errorf("failed to drop foreign key %s of %s: %w", key.name, key.table, err)
+		}
+	}
+	return len(keys), nil
+}
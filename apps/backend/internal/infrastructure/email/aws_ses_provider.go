@@ -1,16 +1,32 @@
 package email
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
 )
 
-// AWSSESProvider implements email sending via AWS SES
+// AWSSESProvider implements email sending via the Amazon SES v2 API, signed with AWS Signature
+// Version 4
 type AWSSESProvider struct {
 	region          string
 	accessKeyID     string
 	secretAccessKey string
 	from            string
+	endpoint        string
+	httpClient      *http.Client
+	now             func() time.Time
 }
 
 // NewAWSSESProvider creates a new AWS SES email provider
@@ -19,7 +35,10 @@ func NewAWSSESProvider(config EmailConfig) (*AWSSESProvider, error) {
 		region:          config.AWSRegion,
 		accessKeyID:     config.AWSAccessKeyID,
 		secretAccessKey: config.AWSSecretAccessKey,
-		from:            fmt.Sprintf("%s <%s>", config.FromName, config.FromAddress),
+		from:            formatAddress(config.FromName, config.FromAddress),
+		endpoint:        fmt.Sprintf("https://email.%s.amazonaws.com", config.AWSRegion),
+		httpClient:      resilience.WrapClient(&http.Client{Timeout: 30 * time.Second}, resilience.DependencyEmail),
+		now:             time.Now,
 	}
 
 	if err := provider.ValidateConfig(); err != nil {
@@ -51,16 +70,156 @@ func (p *AWSSESProvider) GetProviderName() string {
 	return "AWS SES"
 }
 
-// SendEmail sends an email via AWS SES
+// sesContent is a subject or body part of an SES message
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+type sesHeader struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+// sesSendEmailRequest is the body of an SES v2 SendEmail request with simple content
+type sesSendEmailRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses  []string `json:"ToAddresses"`
+		CcAddresses  []string `json:"CcAddresses,omitempty"`
+		BccAddresses []string `json:"BccAddresses,omitempty"`
+	} `json:"Destination"`
+	ReplyToAddresses []string `json:"ReplyToAddresses,omitempty"`
+	Content          struct {
+		Simple struct {
+			Subject sesContent `json:"Subject"`
+			Body    struct {
+				Text *sesContent `json:"Text,omitempty"`
+				Html *sesContent `json:"Html,omitempty"`
+			} `json:"Body"`
+			Headers []sesHeader `json:"Headers,omitempty"`
+		} `json:"Simple"`
+	} `json:"Content"`
+}
+
+// sesErrorResponse is the error document of the SES v2 API
+type sesErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// SendEmail sends an email via SES. SES accepts the message for delivery; bounces and
+// complaints are reported later through SNS notifications.
 func (p *AWSSESProvider) SendEmail(ctx context.Context, params EmailParams) error {
-	// TODO: Implement AWS SES integration
-	// See: https://docs.aws.amazon.com/sdk-for-go/v1/developer-guide/ses-example-send-email.html
-	//
-	// Implementation steps:
-	// 1. Import AWS SDK: github.com/aws/aws-sdk-go/service/ses
-	// 2. Create SES client with credentials
-	// 3. Build SendEmailInput with params
-	// 4. Call client.SendEmail()
-
-	return fmt.Errorf("AWS SES provider not yet implemented - contributions welcome!")
+	if len(params.To) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+	if len(params.Attachments) > 0 {
+		return fmt.Errorf("attachments are not supported by the SES provider")
+	}
+
+	var request sesSendEmailRequest
+	request.FromEmailAddress = params.From
+	if request.FromEmailAddress == "" {
+		request.FromEmailAddress = p.from
+	}
+	request.Destination.ToAddresses = params.To
+	request.Destination.CcAddresses = params.CC
+	request.Destination.BccAddresses = params.BCC
+	if params.ReplyTo != "" {
+		request.ReplyToAddresses = []string{params.ReplyTo}
+	}
+	simple := &request.Content.Simple
+	simple.Subject = sesContent{Data: params.Subject, Charset: "UTF-8"}
+	if params.TextBody != "" {
+		simple.Body.Text = &sesContent{Data: params.TextBody, Charset: "UTF-8"}
+	}
+	if params.HTMLBody != "" {
+		simple.Body.Html = &sesContent{Data: params.HTMLBody, Charset: "UTF-8"}
+	}
+	if simple.Body.Text == nil && simple.Body.Html == nil {
+		return fmt.Errorf("email body is required")
+	}
+	for name, value := range params.Headers {
+		simple.Headers = append(simple.Headers, sesHeader{Name: name, Value: value})
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode SES request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build SES request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	signAWSRequest(req, body, "ses", p.region, p.accessKeyID, p.secretAccessKey, p.now())
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("SES request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr sesErrorResponse
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(detail, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("SES rejected the message (status %d): %s", resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("SES rejected the message (status %d)", resp.StatusCode)
+	}
+	return nil
+}
+
+// signAWSRequest adds the X-Amz-Date and Authorization headers of an AWS Signature Version 4
+// for the service. Every header already on the request is signed; the request has no query.
+func signAWSRequest(req *http.Request, body []byte, service, region, accessKeyID, secretAccessKey string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := awsHMAC([]byte("AWS4"+secretAccessKey), date)
+	key = awsHMAC(key, region)
+	key = awsHMAC(key, service)
+	key = awsHMAC(key, "aws4_request")
+	signature := hex.EncodeToString(awsHMAC(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+func awsHMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
 }
@@ -0,0 +1,118 @@
+package email
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// sendGridEvent is one event of a SendGrid event webhook post
+type sendGridEvent struct {
+	Email  string `json:"email"`
+	Event  string `json:"event"`
+	Type   string `json:"type"` // "bounce" or "blocked" on bounce events
+	Reason string `json:"reason"`
+}
+
+// ParseSendGridEvents returns the bounces and spam complaints among the events of a SendGrid
+// event webhook post. Blocked messages are soft bounces; other event types are ignored.
+func ParseSendGridEvents(body []byte) ([]domain.EmailBounce, error) {
+	var events []sendGridEvent
+	if err := json.Unmarshal(body, &events); err != nil {
+		return nil, fmt.Errorf("invalid SendGrid event payload: %w", err)
+	}
+
+	bounces := make([]domain.EmailBounce, 0, len(events))
+	for _, event := range events {
+		if event.Email == "" {
+			continue
+		}
+		switch event.Event {
+		case "bounce":
+			bounceType := domain.EmailBounceHard
+			if event.Type == "blocked" {
+				bounceType = domain.EmailBounceSoft
+			}
+			bounces = append(bounces, domain.EmailBounce{Address: event.Email, Type: bounceType, Reason: event.Reason})
+		case "spamreport":
+			bounces = append(bounces, domain.EmailBounce{Address: event.Email, Type: domain.EmailBounceComplaint, Reason: "marked as spam"})
+		}
+	}
+	return bounces, nil
+}
+
+// snsEnvelope is an Amazon SNS HTTP(S) delivery
+type snsEnvelope struct {
+	Type         string `json:"Type"` // "Notification" or "SubscriptionConfirmation"
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+// sesNotification is an SES bounce or complaint notification, as published to SNS by identity
+// notifications (notificationType) or configuration set event destinations (eventType)
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Bounce           struct {
+		BounceType        string `json:"bounceType"` // Permanent, Transient or Undetermined
+		BounceSubType     string `json:"bounceSubType"`
+		BouncedRecipients []struct {
+			EmailAddress   string `json:"emailAddress"`
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplaintFeedbackType string `json:"complaintFeedbackType"`
+		ComplainedRecipients  []struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"complainedRecipients"`
+	} `json:"complaint"`
+}
+
+// ParseSESNotification returns the bounces and complaints of an SNS delivery of SES
+// notifications. For a subscription confirmation it returns the URL that confirms the
+// subscription instead; other notification types return no bounces.
+func ParseSESNotification(body []byte) (bounces []domain.EmailBounce, subscribeURL string, err error) {
+	var envelope snsEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, "", fmt.Errorf("invalid SNS payload: %w", err)
+	}
+	if envelope.Type == "SubscriptionConfirmation" {
+		return nil, envelope.SubscribeURL, nil
+	}
+
+	var notification sesNotification
+	if err := json.Unmarshal([]byte(envelope.Message), &notification); err != nil {
+		return nil, "", fmt.Errorf("invalid SES notification: %w", err)
+	}
+
+	kind := notification.NotificationType
+	if kind == "" {
+		kind = notification.EventType
+	}
+	switch kind {
+	case "Bounce":
+		bounceType := domain.EmailBounceSoft
+		if notification.Bounce.BounceType == "Permanent" {
+			bounceType = domain.EmailBounceHard
+		}
+		for _, recipient := range notification.Bounce.BouncedRecipients {
+			reason := recipient.DiagnosticCode
+			if reason == "" {
+				reason = strings.TrimSpace(notification.Bounce.BounceType + " " + notification.Bounce.BounceSubType)
+			}
+			bounces = append(bounces, domain.EmailBounce{Address: recipient.EmailAddress, Type: bounceType, Reason: reason})
+		}
+	case "Complaint":
+		reason := "marked as spam"
+		if notification.Complaint.ComplaintFeedbackType != "" {
+			reason = notification.Complaint.ComplaintFeedbackType
+		}
+		for _, recipient := range notification.Complaint.ComplainedRecipients {
+			bounces = append(bounces, domain.EmailBounce{Address: recipient.EmailAddress, Type: domain.EmailBounceComplaint, Reason: reason})
+		}
+	}
+	return bounces, "", nil
+}
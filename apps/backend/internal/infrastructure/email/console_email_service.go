@@ -31,10 +31,24 @@ func NewConsoleEmailService(config domain.EmailConfig) (domain.EmailService, err
 
 // SendEmail sends a plain text or HTML email by printing to console
 func (s *ConsoleEmailService) SendEmail(to, subject, body string, isHTML bool) error {
+	return s.SendEmailAs(nil, to, subject, body, isHTML)
+}
+
+// SendEmailAs prints an email sent from the organization's sender; a nil sender prints the
+// configured one
+func (s *ConsoleEmailService) SendEmailAs(sender *domain.OrganizationEmailSender, to, subject, body string, isHTML bool) error {
+	fromName, fromAddress := s.fromName, s.fromAddress
+	if sender != nil {
+		fromName, fromAddress = sender.FromName, sender.FromAddress
+	}
+
 	fmt.Println("\n" + strings.Repeat("=", 80))
 	fmt.Println("📧 EMAIL (Console Provider - Development Only)")
 	fmt.Println(strings.Repeat("=", 80))
-	fmt.Printf("From: %s <%s>\n", s.fromName, s.fromAddress)
+	fmt.Printf("From: %s <%s>\n", fromName, fromAddress)
+	if sender != nil && sender.ReplyTo != "" {
+		fmt.Printf("Reply-To: %s\n", sender.ReplyTo)
+	}
 	fmt.Printf("To: %s\n", to)
 	fmt.Printf("Subject: %s\n", subject)
 	fmt.Println(strings.Repeat("-", 80))
@@ -28,8 +28,30 @@ func NewEmailServiceWithConfig(config domain.EmailConfig) (domain.EmailService,
 		return NewAzureEmailService(config)
 	case "smtp":
 		return NewSMTPEmailService(config)
+	case "ses":
+		provider, err := NewAWSSESProvider(EmailConfig{
+			AWSRegion:          config.SES.Region,
+			AWSAccessKeyID:     config.SES.AccessKeyID,
+			AWSSecretAccessKey: config.SES.SecretAccessKey,
+			FromAddress:        config.FromAddress,
+			FromName:           config.FromName,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return NewProviderEmailService(provider, config)
+	case "sendgrid":
+		provider, err := NewSendGridProvider(EmailConfig{
+			SendGridAPIKey: config.SendGrid.APIKey,
+			FromAddress:    config.FromAddress,
+			FromName:       config.FromName,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return NewProviderEmailService(provider, config)
 	default:
-		return nil, fmt.Errorf("unsupported email provider: %s (use 'console', 'azure' or 'smtp')", config.Provider)
+		return nil, fmt.Errorf("unsupported email provider: %s (use 'console', 'azure', 'smtp', 'ses' or 'sendgrid')", config.Provider)
 	}
 }
 
@@ -84,8 +106,33 @@ func LoadEmailConfigFromEnv() (domain.EmailConfig, error) {
 			return config, fmt.Errorf("SMTP_PORT is required for SMTP provider")
 		}
 
+	case "ses":
+		// Falls back to the standard AWS variables, like the SDKs do
+		config.SES = domain.SESConfig{
+			Region:          getEnv("SES_REGION", getEnv("AWS_REGION", "")),
+			AccessKeyID:     getEnv("SES_ACCESS_KEY_ID", getEnv("AWS_ACCESS_KEY_ID", "")),
+			SecretAccessKey: getEnv("SES_SECRET_ACCESS_KEY", getEnv("AWS_SECRET_ACCESS_KEY", "")),
+		}
+
+		if config.SES.Region == "" {
+			return config, fmt.Errorf("SES_REGION is required for SES provider")
+		}
+
+		if config.SES.AccessKeyID == "" || config.SES.SecretAccessKey == "" {
+			return config, fmt.Errorf("SES_ACCESS_KEY_ID and SES_SECRET_ACCESS_KEY are required for SES provider")
+		}
+
+	case "sendgrid":
+		config.SendGrid = domain.SendGridConfig{
+			APIKey: getEnv("SENDGRID_API_KEY", ""),
+		}
+
+		if config.SendGrid.APIKey == "" {
+			return config, fmt.Errorf("SENDGRID_API_KEY is required for SendGrid provider")
+		}
+
 	default:
-		return config, fmt.Errorf("unsupported EMAIL_PROVIDER: %s (use 'console', 'azure' or 'smtp')", provider)
+		return config, fmt.Errorf("unsupported EMAIL_PROVIDER: %s (use 'console', 'azure', 'smtp', 'ses' or 'sendgrid')", provider)
 	}
 
 	return config, nil
@@ -153,6 +200,16 @@ func ValidateEmailConfig(config domain.EmailConfig) error {
 			return fmt.Errorf("smtp port is required")
 		}
 
+	case "ses":
+		if config.SES.Region == "" {
+			return fmt.Errorf("ses region is required")
+		}
+
+	case "sendgrid":
+		if config.SendGrid.APIKey == "" {
+			return fmt.Errorf("sendgrid api key is required")
+		}
+
 	default:
 		return fmt.Errorf("unsupported provider: %s", config.Provider)
 	}
@@ -0,0 +1,101 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// providerSendTimeout bounds a single send through an HTTP email provider
+const providerSendTimeout = 30 * time.Second
+
+// ProviderEmailService implements domain.EmailService on top of an EmailProvider, for the
+// providers reached over their HTTP APIs (SendGrid and Amazon SES)
+type ProviderEmailService struct {
+	provider         EmailProvider
+	fromAddress      string
+	fromName         string
+	templateRenderer *TemplateRenderer
+}
+
+// NewProviderEmailService creates an email service sending through the provider
+func NewProviderEmailService(provider EmailProvider, config domain.EmailConfig) (*ProviderEmailService, error) {
+	templateRenderer, err := NewTemplateRenderer(config.TemplateDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize template renderer: %w", err)
+	}
+
+	return &ProviderEmailService{
+		provider:         provider,
+		fromAddress:      config.FromAddress,
+		fromName:         config.FromName,
+		templateRenderer: templateRenderer,
+	}, nil
+}
+
+// SendEmail sends a plain text or HTML email from the configured sender
+func (s *ProviderEmailService) SendEmail(to, subject, body string, isHTML bool) error {
+	return s.SendEmailAs(nil, to, subject, body, isHTML)
+}
+
+// SendEmailAs sends an email from the organization's sender; a nil sender sends from the
+// configured one
+func (s *ProviderEmailService) SendEmailAs(sender *domain.OrganizationEmailSender, to, subject, body string, isHTML bool) error {
+	params := EmailParams{
+		To:      []string{to},
+		From:    formatAddress(s.fromName, s.fromAddress),
+		Subject: subject,
+	}
+	if sender != nil {
+		params.From = formatAddress(sender.FromName, sender.FromAddress)
+		params.ReplyTo = sender.ReplyTo
+	}
+	if isHTML {
+		params.HTMLBody = body
+	} else {
+		params.TextBody = body
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerSendTimeout)
+	defer cancel()
+	if err := s.provider.SendEmail(ctx, params); err != nil {
+		return fmt.Errorf("%s: %w", s.provider.GetProviderName(), err)
+	}
+	return nil
+}
+
+// SendTemplatedEmail sends an email using a predefined template
+func (s *ProviderEmailService) SendTemplatedEmail(template domain.EmailTemplate, to string, data interface{}) error {
+	subject, body, err := s.templateRenderer.Render(template, data)
+	if err != nil {
+		return fmt.Errorf("failed to render template %s: %w", template, err)
+	}
+	return s.SendEmail(to, subject, body, true)
+}
+
+// SendBulkEmail sends the same email to each recipient separately, so recipients don't see
+// each other
+func (s *ProviderEmailService) SendBulkEmail(recipients []string, subject, body string, isHTML bool) error {
+	failed := 0
+	var firstErr error
+	for _, recipient := range recipients {
+		if err := s.SendEmail(recipient, subject, body, isHTML); err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to send %d/%d emails: %w", failed, len(recipients), firstErr)
+	}
+	return nil
+}
+
+// ValidateConnection checks the provider's configuration; the HTTP providers have no
+// connection to open ahead of the first send
+func (s *ProviderEmailService) ValidateConnection() error {
+	return s.provider.ValidateConfig()
+}
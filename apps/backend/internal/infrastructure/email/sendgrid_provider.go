@@ -1,21 +1,37 @@
 package email
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
 )
 
-// SendGridProvider implements email sending via SendGrid
+// sendGridAPIURL is the SendGrid v3 mail send endpoint
+const sendGridAPIURL = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridProvider implements email sending via the SendGrid v3 API
 type SendGridProvider struct {
-	apiKey string
-	from   string
+	apiKey     string
+	from       string
+	apiURL     string
+	httpClient *http.Client
 }
 
 // NewSendGridProvider creates a new SendGrid email provider
 func NewSendGridProvider(config EmailConfig) (*SendGridProvider, error) {
 	provider := &SendGridProvider{
-		apiKey: config.SendGridAPIKey,
-		from:   fmt.Sprintf("%s <%s>", config.FromName, config.FromAddress),
+		apiKey:     config.SendGridAPIKey,
+		from:       formatAddress(config.FromName, config.FromAddress),
+		apiURL:     sendGridAPIURL,
+		httpClient: resilience.WrapClient(&http.Client{Timeout: 30 * time.Second}, resilience.DependencyEmail),
 	}
 
 	if err := provider.ValidateConfig(); err != nil {
@@ -41,16 +57,140 @@ func (p *SendGridProvider) GetProviderName() string {
 	return "SendGrid"
 }
 
-// SendEmail sends an email via SendGrid
+// sendGridAddress is an email address in a SendGrid request
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// sendGridMessage is the body of a v3 mail send request
+type sendGridMessage struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyTo          *sendGridAddress          `json:"reply_to,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+	Headers          map[string]string         `json:"headers,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To  []sendGridAddress `json:"to"`
+	CC  []sendGridAddress `json:"cc,omitempty"`
+	BCC []sendGridAddress `json:"bcc,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content  string `json:"content"`
+	Type     string `json:"type,omitempty"`
+	Filename string `json:"filename"`
+}
+
+// SendEmail sends an email via SendGrid. SendGrid answers 202 once it accepted the message;
+// bounces are reported later through its event webhook.
 func (p *SendGridProvider) SendEmail(ctx context.Context, params EmailParams) error {
-	// TODO: Implement SendGrid integration
-	// See: https://github.com/sendgrid/sendgrid-go
-	//
-	// Implementation steps:
-	// 1. Import SendGrid SDK: github.com/sendgrid/sendgrid-go
-	// 2. Create message with mail.NewV3Mail()
-	// 3. Set personalization (to, cc, bcc)
-	// 4. Send via sendgrid.NewSendClient(apiKey).Send()
-
-	return fmt.Errorf("SendGrid provider not yet implemented - contributions welcome!")
+	from := params.From
+	if from == "" {
+		from = p.from
+	}
+	sender, err := parseSendGridAddress(from)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+
+	personalization := sendGridPersonalization{}
+	for _, list := range []struct {
+		addresses []string
+		into      *[]sendGridAddress
+	}{
+		{params.To, &personalization.To},
+		{params.CC, &personalization.CC},
+		{params.BCC, &personalization.BCC},
+	} {
+		for _, address := range list.addresses {
+			parsed, err := parseSendGridAddress(address)
+			if err != nil {
+				return fmt.Errorf("invalid recipient %q: %w", address, err)
+			}
+			*list.into = append(*list.into, parsed)
+		}
+	}
+	if len(personalization.To) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+
+	message := sendGridMessage{
+		Personalizations: []sendGridPersonalization{personalization},
+		From:             sender,
+		Subject:          params.Subject,
+		Headers:          params.Headers,
+	}
+	if params.ReplyTo != "" {
+		replyTo, err := parseSendGridAddress(params.ReplyTo)
+		if err != nil {
+			return fmt.Errorf("invalid reply-to address: %w", err)
+		}
+		message.ReplyTo = &replyTo
+	}
+	// SendGrid requires text/plain before text/html
+	if params.TextBody != "" {
+		message.Content = append(message.Content, sendGridContent{Type: "text/plain", Value: params.TextBody})
+	}
+	if params.HTMLBody != "" {
+		message.Content = append(message.Content, sendGridContent{Type: "text/html", Value: params.HTMLBody})
+	}
+	if len(message.Content) == 0 {
+		return fmt.Errorf("email body is required")
+	}
+	for _, attachment := range params.Attachments {
+		message.Attachments = append(message.Attachments, sendGridAttachment{
+			Content:  base64.StdEncoding.EncodeToString(attachment.Data),
+			Type:     attachment.ContentType,
+			Filename: attachment.Filename,
+		})
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode SendGrid request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.apiURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build SendGrid request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("SendGrid request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("SendGrid rejected the message (status %d): %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+func parseSendGridAddress(address string) (sendGridAddress, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return sendGridAddress{}, err
+	}
+	return sendGridAddress{Email: parsed.Address, Name: parsed.Name}, nil
+}
+
+// formatAddress formats an address with its display name, as in a From header
+func formatAddress(name, address string) string {
+	if address == "" {
+		return ""
+	}
+	return (&mail.Address{Name: name, Address: address}).String()
 }
@@ -62,16 +62,26 @@ func NewSMTPEmailService(config domain.EmailConfig) (*SMTPEmailService, error) {
 
 // SendEmail sends a plain text or HTML email via SMTP
 func (s *SMTPEmailService) SendEmail(to, subject, body string, isHTML bool) error {
+	return s.SendEmailAs(nil, to, subject, body, isHTML)
+}
+
+// SendEmailAs sends an email from the organization's sender instead of the configured one; a
+// nil sender sends from the configured one
+func (s *SMTPEmailService) SendEmailAs(sender *domain.OrganizationEmailSender, to, subject, body string, isHTML bool) error {
 	startTime := time.Now()
 
 	// Build email message
-	from := s.fromAddress
-	if s.fromName != "" {
-		from = fmt.Sprintf("%s <%s>", s.fromName, s.fromAddress)
+	fromAddress, fromName, replyTo := s.fromAddress, s.fromName, ""
+	if sender != nil {
+		fromAddress, fromName, replyTo = sender.FromAddress, sender.FromName, sender.ReplyTo
+	}
+	from := fromAddress
+	if fromName != "" {
+		from = fmt.Sprintf("%s <%s>", fromName, fromAddress)
 	}
 
 	// Construct email headers and body
-	message := s.buildMessage(from, to, subject, body, isHTML)
+	message := s.buildMessage(from, to, replyTo, subject, body, isHTML)
 
 	// Connect to SMTP server
 	addr := fmt.Sprintf("%s:%d", s.host, s.port)
@@ -112,7 +122,7 @@ func (s *SMTPEmailService) SendEmail(to, subject, body string, isHTML bool) erro
 		}
 
 		// Send the message
-		if err := client.Mail(s.fromAddress); err != nil {
+		if err := client.Mail(fromAddress); err != nil {
 			s.recordFailure("mail_from_error")
 			return fmt.Errorf("MAIL FROM failed: %w", err)
 		}
@@ -141,7 +151,7 @@ func (s *SMTPEmailService) SendEmail(to, subject, body string, isHTML bool) erro
 		}
 	} else {
 		// Use plain connection (less secure, mainly for local testing)
-		err = smtp.SendMail(addr, auth, s.fromAddress, []string{to}, []byte(message))
+		err = smtp.SendMail(addr, auth, fromAddress, []string{to}, []byte(message))
 		if err != nil {
 			s.recordFailure("send_mail_error")
 			return fmt.Errorf("failed to send email: %w", err)
@@ -252,12 +262,15 @@ func (s *SMTPEmailService) ValidateConnection() error {
 }
 
 // buildMessage constructs a RFC 822-compliant email message
-func (s *SMTPEmailService) buildMessage(from, to, subject, body string, isHTML bool) string {
+func (s *SMTPEmailService) buildMessage(from, to, replyTo, subject, body string, isHTML bool) string {
 	var builder strings.Builder
 
 	// Headers
 	builder.WriteString(fmt.Sprintf("From: %s\r\n", from))
 	builder.WriteString(fmt.Sprintf("To: %s\r\n", to))
+	if replyTo != "" {
+		builder.WriteString(fmt.Sprintf("Reply-To: %s\r\n", replyTo))
+	}
 	builder.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
 	builder.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().Format(time.RFC1123Z)))
 	builder.WriteString("MIME-Version: 1.0\r\n")
@@ -10,7 +10,7 @@ import (
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
-//go:embed templates/*.html
+//go:embed templates/*.html templates/*.subject.txt
 var embeddedTemplates embed.FS
 
 // TemplateRenderer renders email templates
@@ -54,6 +54,7 @@ func (r *TemplateRenderer) loadEmbeddedTemplates() error {
 		domain.TemplateUserApproved,
 		domain.TemplateUserRejected,
 		domain.TemplatePasswordReset,
+		domain.TemplateInvitation,
 		domain.TemplateAgentRegistered,
 		domain.TemplateAgentVerified,
 		domain.TemplateVerificationReminder,
@@ -61,6 +62,9 @@ func (r *TemplateRenderer) loadEmbeddedTemplates() error {
 		domain.TemplateAlertCritical,
 		domain.TemplateAlertWarning,
 		domain.TemplateAlertInfo,
+		domain.TemplateAlertDigest,
+		domain.TemplateCapabilityRequestApproved,
+		domain.TemplateCapabilityRequestRejected,
 		domain.TemplateMCPServerRegistered,
 		domain.TemplateMCPServerExpiring,
 		domain.TemplateAPIKeyCreated,
@@ -178,22 +182,26 @@ func (r *TemplateRenderer) getDefaultTemplate(name domain.EmailTemplate) string
 // getDefaultSubject returns a simple default subject if file doesn't exist
 func (r *TemplateRenderer) getDefaultSubject(name domain.EmailTemplate) string {
 	subjects := map[domain.EmailTemplate]string{
-		domain.TemplateWelcome:              "Welcome to Agent Identity Management",
-		domain.TemplateUserApproved:         "Your account has been approved",
-		domain.TemplateUserRejected:         "Account registration update",
-		domain.TemplatePasswordReset:        "Reset your password",
-		domain.TemplateAgentRegistered:      "Agent registered successfully",
-		domain.TemplateAgentVerified:        "Agent verified successfully",
-		domain.TemplateVerificationReminder: "Agent verification required",
-		domain.TemplateVerificationFailed:   "Agent verification failed",
-		domain.TemplateAlertCritical:        "🚨 Critical Alert",
-		domain.TemplateAlertWarning:         "⚠️ Warning Alert",
-		domain.TemplateAlertInfo:            "ℹ️ Information Alert",
-		domain.TemplateMCPServerRegistered:  "MCP Server registered successfully",
-		domain.TemplateMCPServerExpiring:    "MCP Server certificate expiring soon",
-		domain.TemplateAPIKeyCreated:        "New API key created",
-		domain.TemplateAPIKeyExpiring:       "API key expiring soon",
-		domain.TemplateAPIKeyRevoked:        "API key revoked",
+		domain.TemplateWelcome:                   "Welcome to Agent Identity Management",
+		domain.TemplateUserApproved:              "Your account has been approved",
+		domain.TemplateUserRejected:              "Account registration update",
+		domain.TemplatePasswordReset:             "Reset your password",
+		domain.TemplateInvitation:                "You've been invited to Agent Identity Management",
+		domain.TemplateAgentRegistered:           "Agent registered successfully",
+		domain.TemplateAgentVerified:             "Agent verified successfully",
+		domain.TemplateVerificationReminder:      "Agent verification required",
+		domain.TemplateVerificationFailed:        "Agent verification failed",
+		domain.TemplateAlertCritical:             "🚨 Critical Alert",
+		domain.TemplateAlertWarning:              "⚠️ Warning Alert",
+		domain.TemplateAlertInfo:                 "ℹ️ Information Alert",
+		domain.TemplateAlertDigest:               "Alert digest",
+		domain.TemplateCapabilityRequestApproved: "Capability request approved",
+		domain.TemplateCapabilityRequestRejected: "Capability request rejected",
+		domain.TemplateMCPServerRegistered:       "MCP Server registered successfully",
+		domain.TemplateMCPServerExpiring:         "MCP Server certificate expiring soon",
+		domain.TemplateAPIKeyCreated:             "New API key created",
+		domain.TemplateAPIKeyExpiring:            "API key expiring soon",
+		domain.TemplateAPIKeyRevoked:             "API key revoked",
 	}
 
 	if subject, ok := subjects[name]; ok {
@@ -0,0 +1,50 @@
+// Package eventbus publishes domain events to NATS or Kafka
+package eventbus
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	DriverNone  = "none"
+	DriverNATS  = "nats"
+	DriverKafka = "kafka"
+
+	// DefaultTopicPrefix is prepended to event types to name NATS subjects and Kafka topics
+	DefaultTopicPrefix = "aim.events"
+)
+
+// Config selects and addresses the message broker
+type Config struct {
+	Driver      string // none, nats or kafka
+	URL         string // nats://[user:pass@]host:4222 (tls:// for TLS), or the Kafka REST Proxy URL
+	TopicPrefix string
+}
+
+// New returns the bus of the configured driver, or nil when publishing is turned off
+func New(config Config) (domain.DomainEventBus, error) {
+	prefix := strings.Trim(config.TopicPrefix, ".")
+	if prefix == "" {
+		prefix = DefaultTopicPrefix
+	}
+
+	switch strings.ToLower(config.Driver) {
+	case "", DriverNone:
+		return nil, nil
+	case DriverNATS:
+		return NewNATSBus(config.URL, prefix)
+	case DriverKafka:
+		return NewKafkaBus(config.URL, prefix)
+	default:
+		return nil, fmt.Errorf("unknown event bus driver %q (none, nats or kafka)", config.Driver)
+	}
+}
+
+// topic is the NATS subject or Kafka topic an event is published to, e.g.
+// aim.events.verification.created
+func topic(prefix string, event *domain.DomainEvent) string {
+	return prefix + "." + string(event.Type)
+}
@@ -0,0 +1,142 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	kafkaRequestTimeout = 30 * time.Second
+	kafkaContentType    = "application/vnd.kafka.json.v2+json"
+	// maxKafkaResponseBytes bounds the produce responses read from the REST Proxy
+	maxKafkaResponseBytes = 1 << 20
+)
+
+// KafkaBus publishes events to Kafka through a Confluent REST Proxy (v2 API), one topic per event
+// type. Events are keyed by organization, so each organization's events keep their order within
+// a partition. A batch counts as published once the proxy reports an offset for every record.
+type KafkaBus struct {
+	baseURL    string
+	username   string
+	password   string
+	prefix     string
+	httpClient *http.Client
+}
+
+var _ domain.DomainEventBus = (*KafkaBus)(nil)
+
+// NewKafkaBus creates a Kafka bus for the REST Proxy at the URL; basic auth credentials may be
+// given in it
+func NewKafkaBus(rawURL, prefix string) (*KafkaBus, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid Kafka REST Proxy URL %q", rawURL)
+	}
+	bus := &KafkaBus{prefix: prefix, httpClient: &http.Client{Timeout: kafkaRequestTimeout}}
+	if u.User != nil {
+		bus.username = u.User.Username()
+		bus.password, _ = u.User.Password()
+		u.User = nil
+	}
+	bus.baseURL = strings.TrimRight(u.String(), "/")
+	return bus, nil
+}
+
+// kafkaRecord is one record of a REST Proxy produce request
+type kafkaRecord struct {
+	Key   string              `json:"key"`
+	Value *domain.DomainEvent `json:"value"`
+}
+
+// kafkaProduceResponse reports the outcome of each record of a produce request
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition *int   `json:"partition"`
+		Offset    *int64 `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// Name identifies the broker
+func (b *KafkaBus) Name() string {
+	return DriverKafka
+}
+
+// Publish produces the events, one request per topic in the order the topics first appear
+func (b *KafkaBus) Publish(ctx context.Context, events []*domain.DomainEvent) error {
+	var topics []string
+	records := make(map[string][]kafkaRecord)
+	for _, event := range events {
+		name := topic(b.prefix, event)
+		if _, ok := records[name]; !ok {
+			topics = append(topics, name)
+		}
+		records[name] = append(records[name], kafkaRecord{Key: event.OrganizationID.String(), Value: event})
+	}
+
+	for _, name := range topics {
+		if err := b.produce(ctx, name, records[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close releases idle connections to the REST Proxy
+func (b *KafkaBus) Close() error {
+	b.httpClient.CloseIdleConnections()
+	return nil
+}
+
+func (b *KafkaBus) produce(ctx context.Context, topicName string, records []kafkaRecord) error {
+	body, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.baseURL+"/topics/"+url.PathEscape(topicName), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	if b.username != "" {
+		req.SetBasicAuth(b.username, b.password)
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Kafka REST Proxy: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxKafkaResponseBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read Kafka REST Proxy response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Kafka REST Proxy returned %d for topic %s: %s", resp.StatusCode, topicName, strings.TrimSpace(string(data)))
+	}
+
+	var produced kafkaProduceResponse
+	if err := json.Unmarshal(data, &produced); err != nil {
+		return fmt.Errorf("invalid Kafka REST Proxy response: %w", err)
+	}
+	if len(produced.Offsets) != len(records) {
+		return fmt.Errorf("Kafka REST Proxy acknowledged %d of %d records to %s", len(produced.Offsets), len(records), topicName)
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil || offset.Offset == nil {
+			return fmt.Errorf("Kafka rejected a record to %s: %s", topicName, offset.Error)
+		}
+	}
+	return nil
+}
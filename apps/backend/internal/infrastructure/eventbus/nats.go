@@ -0,0 +1,115 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	natsDialTimeout = 10 * time.Second
+	// natsPublishTimeout bounds publishing one batch and waiting for JetStream to acknowledge it
+	natsPublishTimeout = 30 * time.Second
+)
+
+// NATSBus publishes events to JetStream. Each event is published with its ID as Nats-Msg-Id, so
+// the stream capturing the subjects drops redeliveries, and counts as published once the stream
+// acknowledged storing it. Subjects no stream captures are not acknowledged and fail the batch.
+// The connection is opened on the first batch and reconnects by itself afterwards.
+type NATSBus struct {
+	url    string
+	prefix string
+
+	mu   sync.Mutex
+	conn *nats.Conn
+	js   jetstream.JetStream
+}
+
+var _ domain.DomainEventBus = (*NATSBus)(nil)
+
+// NewNATSBus creates a NATS bus for a nats:// or tls:// URL. Credentials are taken from the URL:
+// user and password, or a token as the user alone.
+func NewNATSBus(rawURL, prefix string) (*NATSBus, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q", rawURL)
+	}
+	if u.Scheme != "nats" && u.Scheme != "tls" {
+		return nil, fmt.Errorf("NATS URL must start with nats:// or tls://")
+	}
+	return &NATSBus{url: rawURL, prefix: prefix}, nil
+}
+
+// Name identifies the broker
+func (b *NATSBus) Name() string {
+	return DriverNATS
+}
+
+// Publish sends the events and waits until JetStream acknowledged each of them
+func (b *NATSBus) Publish(ctx context.Context, events []*domain.DomainEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.js == nil {
+		if err := b.connect(); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, natsPublishTimeout)
+	defer cancel()
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+		}
+		subject := topic(b.prefix, event)
+		ack, err := b.js.Publish(ctx, subject, data, jetstream.WithMsgID(event.ID.String()))
+		if err != nil {
+			return fmt.Errorf("failed to publish event %s to %s: %w", event.ID, subject, err)
+		}
+		if ack == nil || ack.Stream == "" {
+			return fmt.Errorf("event %s to %s was not acknowledged by a stream", event.ID, subject)
+		}
+	}
+	return nil
+}
+
+// Close drains the connection to the server
+func (b *NATSBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.conn == nil {
+		return nil
+	}
+	err := b.conn.Drain()
+	b.conn, b.js = nil, nil
+	return err
+}
+
+// connect opens the connection and its JetStream context
+func (b *NATSBus) connect() error {
+	conn, err := nats.Connect(b.url,
+		nats.Name("aim-backend"),
+		nats.Timeout(natsDialTimeout),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to open JetStream: %w", err)
+	}
+	b.conn, b.js = conn, js
+	return nil
+}
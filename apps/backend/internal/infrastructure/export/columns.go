@@ -0,0 +1,125 @@
+package export
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// columnKind is the type of an export column
+type columnKind int
+
+const (
+	kindString    columnKind = iota
+	kindJSON                 // Lists and maps, written as JSON text
+	kindTimestamp            // UTC, millisecond precision in Parquet
+	kindInt
+	kindDouble
+	kindBool
+)
+
+// column is one field of a verification event in an export. value returns nil for a missing
+// value; required columns always have one.
+type column struct {
+	name     string
+	kind     columnKind
+	required bool
+	value    func(e *domain.VerificationEvent) interface{}
+}
+
+// columns are the fields of an exported verification event, in file order. Signatures, nonces
+// and public keys are left out; the message hash identifies what was signed.
+var columns = []column{
+	{"id", kindString, true, func(e *domain.VerificationEvent) interface{} { return e.ID.String() }},
+	{"created_at", kindTimestamp, true, func(e *domain.VerificationEvent) interface{} { return e.CreatedAt }},
+	{"started_at", kindTimestamp, true, func(e *domain.VerificationEvent) interface{} { return e.StartedAt }},
+	{"completed_at", kindTimestamp, false, func(e *domain.VerificationEvent) interface{} { return optionalTime(e.CompletedAt) }},
+	{"agent_id", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalUUID(e.AgentID) }},
+	{"agent_name", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalString(e.AgentName) }},
+	{"mcp_server_id", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalUUID(e.MCPServerID) }},
+	{"mcp_server_name", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalString(e.MCPServerName) }},
+	{"protocol", kindString, true, func(e *domain.VerificationEvent) interface{} { return string(e.Protocol) }},
+	{"verification_type", kindString, true, func(e *domain.VerificationEvent) interface{} { return string(e.VerificationType) }},
+	{"status", kindString, true, func(e *domain.VerificationEvent) interface{} { return string(e.Status) }},
+	{"result", kindString, false, func(e *domain.VerificationEvent) interface{} {
+		if e.Result == nil {
+			return nil
+		}
+		return string(*e.Result)
+	}},
+	{"confidence", kindDouble, true, func(e *domain.VerificationEvent) interface{} { return e.Confidence }},
+	{"trust_score", kindDouble, true, func(e *domain.VerificationEvent) interface{} { return e.TrustScore }},
+	{"duration_ms", kindInt, true, func(e *domain.VerificationEvent) interface{} { return int64(e.DurationMs) }},
+	{"error_code", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalString(e.ErrorCode) }},
+	{"error_reason", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalString(e.ErrorReason) }},
+	{"initiator_type", kindString, true, func(e *domain.VerificationEvent) interface{} { return string(e.InitiatorType) }},
+	{"initiator_id", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalUUID(e.InitiatorID) }},
+	{"initiator_name", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalString(e.InitiatorName) }},
+	{"initiator_ip", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalString(e.InitiatorIP) }},
+	{"initiator_user_id", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalUUID(e.InitiatorUserID) }},
+	{"initiator_agent_id", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalUUID(e.InitiatorAgentID) }},
+	{"initiator_api_key_id", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalUUID(e.InitiatorAPIKeyID) }},
+	{"initiator_user_agent", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalString(e.InitiatorUserAgent) }},
+	{"action", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalString(e.Action) }},
+	{"resource_type", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalString(e.ResourceType) }},
+	{"resource_id", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalString(e.ResourceID) }},
+	{"location", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalString(e.Location) }},
+	{"message_hash", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalString(e.MessageHash) }},
+	{"current_mcp_servers", kindJSON, false, func(e *domain.VerificationEvent) interface{} { return optionalList(e.CurrentMCPServers) }},
+	{"current_mcp_tools", kindJSON, false, func(e *domain.VerificationEvent) interface{} { return optionalList(e.CurrentMCPTools) }},
+	{"current_capabilities", kindJSON, false, func(e *domain.VerificationEvent) interface{} { return optionalList(e.CurrentCapabilities) }},
+	{"drift_detected", kindBool, true, func(e *domain.VerificationEvent) interface{} { return e.DriftDetected }},
+	{"mcp_server_drift", kindJSON, false, func(e *domain.VerificationEvent) interface{} { return optionalList(e.MCPServerDrift) }},
+	{"mcp_tool_drift", kindJSON, false, func(e *domain.VerificationEvent) interface{} { return optionalList(e.MCPToolDrift) }},
+	{"capability_drift", kindJSON, false, func(e *domain.VerificationEvent) interface{} { return optionalList(e.CapabilityDrift) }},
+	{"peer_agent_drift", kindJSON, false, func(e *domain.VerificationEvent) interface{} { return optionalList(e.PeerAgentDrift) }},
+	{"details", kindString, false, func(e *domain.VerificationEvent) interface{} { return optionalString(e.Details) }},
+	{"metadata", kindJSON, false, func(e *domain.VerificationEvent) interface{} {
+		if len(e.Metadata) == 0 {
+			return nil
+		}
+		return e.Metadata
+	}},
+}
+
+func optionalString(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+func optionalUUID[T interface{ String() string }](id *T) interface{} {
+	if id == nil {
+		return nil
+	}
+	return (*id).String()
+}
+
+func optionalList(values []string) interface{} {
+	if values == nil {
+		return nil
+	}
+	return values
+}
+
+// text renders a non-nil column value as it appears in CSV and in Parquet string columns
+func text(kind columnKind, value interface{}) (string, error) {
+	switch kind {
+	case kindJSON:
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	case kindTimestamp:
+		return value.(time.Time).UTC().Format(time.RFC3339Nano), nil
+	default:
+		return value.(string), nil
+	}
+}
@@ -0,0 +1,111 @@
+package geoip
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// Locator looks up IP addresses in a table of address ranges loaded from a GeoIP CSV file.
+// Without a file it only recognizes private and loopback addresses.
+//
+// The file holds one range per row: first address, last address and country code (the DB-IP
+// Lite country format), optionally followed by continent, country, region and city columns
+// after the addresses (the DB-IP Lite city format). IPv4 and IPv6 ranges may be mixed.
+type Locator struct {
+	ranges []ipRange // Sorted by first address
+}
+
+type ipRange struct {
+	first, last netip.Addr
+	location    domain.IPLocation
+}
+
+// NewLocator creates a locator from the GeoIP CSV file at path; an empty path locates nothing
+// but private and loopback addresses
+func NewLocator(path string) (*Locator, error) {
+	if path == "" {
+		return &Locator{}, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geoip database: %w", err)
+	}
+	defer file.Close()
+	return ReadLocator(file)
+}
+
+// ReadLocator creates a locator from GeoIP CSV rows
+func ReadLocator(r io.Reader) (*Locator, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	locator := &Locator{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read geoip database: %w", err)
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("geoip database line %d: expected first address, last address and country", line)
+		}
+		first, err := netip.ParseAddr(strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("geoip database line %d: %w", line, err)
+		}
+		last, err := netip.ParseAddr(strings.TrimSpace(record[1]))
+		if err != nil {
+			return nil, fmt.Errorf("geoip database line %d: %w", line, err)
+		}
+
+		var location domain.IPLocation
+		if len(record) >= 6 {
+			location = domain.IPLocation{Country: record[3], Region: record[4], City: record[5]}
+		} else {
+			location = domain.IPLocation{Country: record[2]}
+		}
+		locator.ranges = append(locator.ranges, ipRange{first: first.Unmap(), last: last.Unmap(), location: location})
+	}
+
+	sort.Slice(locator.ranges, func(i, j int) bool {
+		return locator.ranges[i].first.Less(locator.ranges[j].first)
+	})
+	return locator, nil
+}
+
+// Locate returns the location of the address; nil when the address is not in the database
+func (l *Locator) Locate(ip string) *domain.IPLocation {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return nil
+	}
+	addr = addr.Unmap()
+
+	switch {
+	case addr.IsLoopback():
+		return &domain.IPLocation{Network: domain.IPNetworkLoopback}
+	case addr.IsPrivate() || addr.IsLinkLocalUnicast():
+		return &domain.IPLocation{Network: domain.IPNetworkPrivate}
+	}
+
+	// The last range starting at or before the address is the only one that can hold it
+	i := sort.Search(len(l.ranges), func(i int) bool {
+		return addr.Less(l.ranges[i].first)
+	}) - 1
+	if i < 0 || l.ranges[i].last.Less(addr) || l.ranges[i].first.Is4() != addr.Is4() {
+		return nil
+	}
+	location := l.ranges[i].location
+	return &location
+}
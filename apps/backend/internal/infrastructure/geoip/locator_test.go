@@ -0,0 +1,42 @@
+package geoip
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocatorReadsCountryAndCityRanges(t *testing.T) {
+	locator, err := ReadLocator(strings.NewReader(strings.Join([]string{
+		"81.2.69.0,81.2.69.255,GB",
+		"2001:db8::,2001:db8::ffff,NL",
+		`8.8.8.0,8.8.8.255,NA,US,California,"Mountain View",37.4,-122.1`,
+	}, "\n")))
+	require.NoError(t, err)
+
+	assert.Equal(t, &domain.IPLocation{Country: "GB"}, locator.Locate("81.2.69.142"))
+	assert.Equal(t, &domain.IPLocation{Country: "US", Region: "California", City: "Mountain View"}, locator.Locate("8.8.8.8"))
+	assert.Equal(t, &domain.IPLocation{Country: "NL"}, locator.Locate("2001:db8::1"))
+	assert.Equal(t, &domain.IPLocation{Country: "US", Region: "California", City: "Mountain View"}, locator.Locate("::ffff:8.8.8.8"))
+
+	// Addresses between ranges, and text that is not an address, have no location
+	assert.Nil(t, locator.Locate("81.2.70.1"))
+	assert.Nil(t, locator.Locate("1.1.1.1"))
+	assert.Nil(t, locator.Locate("unknown"))
+}
+
+func TestLocatorWithoutDatabaseOnlyKnowsLocalNetworks(t *testing.T) {
+	locator, err := NewLocator("")
+	require.NoError(t, err)
+
+	assert.Equal(t, &domain.IPLocation{Network: domain.IPNetworkPrivate}, locator.Locate("10.1.2.3"))
+	assert.Equal(t, &domain.IPLocation{Network: domain.IPNetworkPrivate}, locator.Locate("fd00::1"))
+	assert.Equal(t, &domain.IPLocation{Network: domain.IPNetworkLoopback}, locator.Locate("127.0.0.1"))
+	assert.Nil(t, locator.Locate("8.8.8.8"))
+
+	_, err = ReadLocator(strings.NewReader("first,last,country\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+// Package logging filters the backend's log output by level. The backend logs through the
+// standard library logger and marks the severity of a line with an emoji: "❌" for errors and
+// "⚠️" for warnings. The level writer classifies each line by its marker, so the log level can
+// be raised or lowered while the backend runs without touching the call sites.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+// Level is a log level; lines below the current level are dropped
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+// LevelNames lists the accepted level names, most verbose first
+func LevelNames() []string {
+	return []string{"debug", "info", "warn", "error"}
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel parses a level name; "warning" is accepted for "warn"
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q (use %s)", name, strings.Join(LevelNames(), ", "))
+}
+
+var current atomic.Int32
+
+func init() {
+	current.Store(int32(LevelInfo))
+}
+
+// SetLevel changes the level of every LevelWriter
+func SetLevel(level Level) {
+	current.Store(int32(level))
+}
+
+// CurrentLevel returns the level in effect
+func CurrentLevel() Level {
+	return Level(current.Load())
+}
+
+// Enabled reports whether lines of the level are written
+func Enabled(level Level) bool {
+	return level >= CurrentLevel()
+}
+
+// LevelWriter drops log lines below the current level before writing them to the underlying
+// writer. It expects one line per Write, as the standard library logger does.
+type LevelWriter struct {
+	out io.Writer
+}
+
+// NewLevelWriter wraps out, e.g. for log.SetOutput
+func NewLevelWriter(out io.Writer) *LevelWriter {
+	return &LevelWriter{out: out}
+}
+
+func (w *LevelWriter) Write(p []byte) (int, error) {
+	if !Enabled(Classify(string(p))) {
+		// Report the line as written; the logger treats short writes as errors
+		return len(p), nil
+	}
+	return w.out.Write(p)
+}
+
+// Classify returns the level of a log line from its marker. Unmarked lines reporting a
+// failure count as warnings, so raising the level never hides them; other lines are info.
+func Classify(line string) Level {
+	switch {
+	case strings.Contains(line, "❌"):
+		return LevelError
+	case strings.Contains(line, "⚠️"):
+		return LevelWarn
+	}
+	lower := strings.ToLower(line)
+	if strings.Contains(lower, "failed") || strings.Contains(lower, "error") || strings.Contains(lower, "panic") {
+		return LevelWarn
+	}
+	return LevelInfo
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelWriterDropsLinesBelowTheCurrentLevel(t *testing.T) {
+	defer SetLevel(CurrentLevel())
+
+	var out bytes.Buffer
+	logger := log.New(NewLevelWriter(&out), "", 0)
+	logAll := func() {
+		logger.Printf("✅ Started")
+		logger.Printf("⚠️  Cache unavailable")
+		logger.Printf("Failed to send webhook: timeout")
+		logger.Printf("❌ Database unreachable")
+	}
+
+	SetLevel(LevelInfo)
+	logAll()
+	assert.Equal(t, "✅ Started\n⚠️  Cache unavailable\nFailed to send webhook: timeout\n❌ Database unreachable\n", out.String())
+
+	out.Reset()
+	SetLevel(LevelWarn)
+	logAll()
+	assert.Equal(t, "⚠️  Cache unavailable\nFailed to send webhook: timeout\n❌ Database unreachable\n", out.String())
+
+	out.Reset()
+	SetLevel(LevelError)
+	logAll()
+	assert.Equal(t, "❌ Database unreachable\n", out.String())
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]Level{"debug": LevelDebug, "INFO": LevelInfo, "warning": LevelWarn, " error ": LevelError} {
+		level, err := ParseLevel(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, level, name)
+	}
+
+	_, err := ParseLevel("verbose")
+	assert.EqualError(t, err, `unknown log level "verbose" (use debug, info, warn, error)`)
+}
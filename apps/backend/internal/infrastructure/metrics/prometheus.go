@@ -29,6 +29,11 @@ func init() {
 		mcpAttestationsTotal,
 		verificationEventsTotal,
 		verificationDuration,
+		attestationSignatureFailuresTotal,
+		driftDetectionsTotal,
+		authRefreshDuration,
+		trustScoreRecalculationsTotal,
+		trustScoreRecalculationDuration,
 		complianceChecksTotal,
 		complianceViolationsTotal,
 		databaseConnectionsActive,
@@ -142,6 +147,51 @@ var (
 		[]string{"event_type"},
 	)
 
+	// Attestation metrics
+	attestationSignatureFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "aim_attestation_signature_failures_total",
+			Help: "Total number of MCP attestations rejected for an invalid signature",
+		},
+		[]string{"reason"},
+	)
+
+	// Drift metrics
+	driftDetectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "aim_drift_detections_total",
+			Help: "Total number of runtime configuration drifts detected",
+		},
+		[]string{"drift_type"},
+	)
+
+	// Auth metrics
+	authRefreshDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "aim_auth_refresh_duration_seconds",
+			Help:    "Duration of access token refreshes in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"token_type", "outcome"},
+	)
+
+	// Trust score recalculation metrics
+	trustScoreRecalculationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "aim_trust_score_recalculations_total",
+			Help: "Total number of trust score recalculations",
+		},
+		[]string{"status"},
+	)
+
+	trustScoreRecalculationDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "aim_trust_score_recalculation_duration_seconds",
+			Help:    "Duration of trust score recalculations in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+	)
+
 	// Compliance metrics
 	complianceChecksTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -308,6 +358,27 @@ func ObserveVerificationDuration(eventType string, duration float64) {
 	verificationDuration.WithLabelValues(eventType).Observe(duration)
 }
 
+// RecordAttestationSignatureFailure records an attestation rejected for its signature
+func RecordAttestationSignatureFailure(reason string) {
+	attestationSignatureFailuresTotal.WithLabelValues(reason).Inc()
+}
+
+// RecordDriftDetection records a detected drift of the given type (mcp_server, capability or peer_agent)
+func RecordDriftDetection(driftType string) {
+	driftDetectionsTotal.WithLabelValues(driftType).Inc()
+}
+
+// ObserveAuthRefresh observes the duration and outcome of an access token refresh
+func ObserveAuthRefresh(tokenType, outcome string, duration float64) {
+	authRefreshDuration.WithLabelValues(tokenType, outcome).Observe(duration)
+}
+
+// RecordTrustScoreRecalculation records a trust score recalculation and its duration
+func RecordTrustScoreRecalculation(status string, duration float64) {
+	trustScoreRecalculationsTotal.WithLabelValues(status).Inc()
+	trustScoreRecalculationDuration.Observe(duration)
+}
+
 // RecordComplianceCheck records a compliance check
 func RecordComplianceCheck(checkType, status string) {
 	complianceChecksTotal.WithLabelValues(checkType, status).Inc()
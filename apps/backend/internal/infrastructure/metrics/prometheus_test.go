@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityOperationMetricsAreExposed(t *testing.T) {
+	RecordVerificationEvent("identity", "failed")
+	ObserveVerificationDuration("identity", 0.2)
+	RecordAttestationSignatureFailure("invalid")
+	RecordDriftDetection("capability")
+	ObserveAuthRefresh("sdk", "success", 0.05)
+	RecordTrustScoreRecalculation("success", 0.01)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(verificationEventsTotal.WithLabelValues("identity", "failed")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(attestationSignatureFailuresTotal.WithLabelValues("invalid")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(driftDetectionsTotal.WithLabelValues("capability")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(trustScoreRecalculationsTotal.WithLabelValues("success")))
+
+	app := fiber.New()
+	app.Get("/metrics", PrometheusHandler())
+	resp, err := app.Test(httptest.NewRequest("GET", "/metrics", nil))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	for _, name := range []string{
+		`aim_verification_events_total{event_type="identity",status="failed"} 1`,
+		`aim_verification_duration_seconds_count{event_type="identity"} 1`,
+		`aim_attestation_signature_failures_total{reason="invalid"} 1`,
+		`aim_drift_detections_total{drift_type="capability"} 1`,
+		`aim_auth_refresh_duration_seconds_count{outcome="success",token_type="sdk"} 1`,
+		`aim_trust_score_recalculations_total{status="success"} 1`,
+		`aim_trust_score_recalculation_duration_seconds_count 1`,
+	} {
+		assert.Contains(t, string(body), name)
+	}
+}
@@ -0,0 +1,75 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// EmailSender delivers notifications through the configured email service
+type EmailSender struct {
+	emailService domain.EmailService
+}
+
+// NewEmailSender creates a new email sender
+func NewEmailSender(emailService domain.EmailService) *EmailSender {
+	return &EmailSender{emailService: emailService}
+}
+
+// ChannelType returns the channel type handled by this sender
+func (s *EmailSender) ChannelType() domain.NotificationChannelType {
+	return domain.NotificationChannelEmail
+}
+
+// Send emails the notification to the address given as recipient, from the sender of the
+// channel's organization when the email service supports organization senders
+func (s *EmailSender) Send(ctx context.Context, channel *domain.NotificationChannel, recipient string, n *domain.Notification) error {
+	if s.emailService == nil {
+		return fmt.Errorf("email service is not configured")
+	}
+
+	emailService := s.emailService
+	if orgEmail, ok := emailService.(domain.OrganizationEmailService); ok {
+		emailService = orgEmail.ForOrganization(channel.OrganizationID)
+	}
+
+	// Digests list one notification per line, rendered with the alert digest template
+	if n.EventType == "notification.digest" {
+		frontendURL := os.Getenv("FRONTEND_URL")
+		if frontendURL == "" {
+			frontendURL = "http://localhost:3000"
+		}
+		return emailService.SendTemplatedEmail(domain.TemplateAlertDigest, recipient, domain.EmailTemplateData{
+			UserEmail:     recipient,
+			AlertTitle:    n.Title,
+			AlertSeverity: string(n.Severity),
+			DashboardURL:  frontendURL,
+			Timestamp:     time.Now(),
+			CustomData: map[string]interface{}{
+				"Alerts": strings.Split(n.Message, "\n"),
+			},
+		})
+	}
+
+	subject := fmt.Sprintf("[AIM %s] %s", n.Severity, n.Title)
+	body := fmt.Sprintf(
+		"<h2>%s</h2><p>%s</p><p><small>Event: %s &middot; Severity: %s &middot; %s</small></p>",
+		html.EscapeString(n.Title),
+		strings.ReplaceAll(html.EscapeString(n.Message), "\n", "<br>"),
+		html.EscapeString(n.EventType),
+		html.EscapeString(string(n.Severity)),
+		n.CreatedAt.UTC().Format("2006-01-02 15:04:05 MST"),
+	)
+
+	// Text attachments (e.g. inline CSV reports) are embedded since the email service has no attachment support
+	if text, ok := n.Payload["inlineText"].(string); ok && text != "" {
+		body += fmt.Sprintf("<pre>%s</pre>", html.EscapeString(text))
+	}
+
+	return emailService.SendEmail(recipient, subject, body, true)
+}
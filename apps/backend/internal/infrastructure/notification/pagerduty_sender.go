@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
+)
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutySender triggers PagerDuty incidents through the Events API v2
+type PagerDutySender struct {
+	client    *http.Client
+	eventsURL string
+}
+
+// NewPagerDutySender creates a new PagerDuty sender
+func NewPagerDutySender() *PagerDutySender {
+	return &PagerDutySender{
+		client:    resilience.WrapClient(&http.Client{Timeout: 10 * time.Second}, resilience.DependencyNotifications),
+		eventsURL: pagerDutyEventsURL,
+	}
+}
+
+// ChannelType returns the channel type handled by this sender
+func (s *PagerDutySender) ChannelType() domain.NotificationChannelType {
+	return domain.NotificationChannelPagerDuty
+}
+
+// Send triggers an event using the routing key given as recipient.
+// The notification ID is used as dedup key so retries never open duplicate incidents.
+func (s *PagerDutySender) Send(ctx context.Context, channel *domain.NotificationChannel, recipient string, n *domain.Notification) error {
+	event := map[string]interface{}{
+		"routing_key":  recipient,
+		"event_action": "trigger",
+		"dedup_key":    n.ID.String(),
+		"payload": map[string]interface{}{
+			"summary":        n.Title,
+			"source":         "agent-identity-management",
+			"severity":       pagerDutySeverity(n.Severity),
+			"timestamp":      n.CreatedAt.Format(time.RFC3339),
+			"class":          n.EventType,
+			"custom_details": n.Payload,
+		},
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, s.client, s.eventsURL, body, nil)
+}
+
+// pagerDutySeverity maps AIM severities onto the PagerDuty severity set
+func pagerDutySeverity(severity domain.AlertSeverity) string {
+	switch severity {
+	case domain.AlertSeverityCritical:
+		return "critical"
+	case domain.AlertSeverityHigh:
+		return "error"
+	case domain.AlertSeverityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
@@ -0,0 +1,83 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
+)
+
+// SlackSender posts notifications to Slack incoming webhook URLs
+type SlackSender struct {
+	client *http.Client
+}
+
+// NewSlackSender creates a new Slack sender
+func NewSlackSender() *SlackSender {
+	return &SlackSender{client: resilience.WrapClient(&http.Client{Timeout: 10 * time.Second}, resilience.DependencyNotifications)}
+}
+
+// ChannelType returns the channel type handled by this sender
+func (s *SlackSender) ChannelType() domain.NotificationChannelType {
+	return domain.NotificationChannelSlack
+}
+
+// Send posts the notification to the Slack incoming webhook URL given as recipient
+func (s *SlackSender) Send(ctx context.Context, channel *domain.NotificationChannel, recipient string, n *domain.Notification) error {
+	message := map[string]interface{}{
+		"text": fmt.Sprintf("%s *[%s] %s*\n%s", severityEmoji(n.Severity), n.Severity, n.Title, n.Message),
+	}
+	if username, ok := channel.Config["username"].(string); ok && username != "" {
+		message["username"] = username
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, s.client, recipient, body, nil)
+}
+
+func severityEmoji(severity domain.AlertSeverity) string {
+	switch severity {
+	case domain.AlertSeverityCritical:
+		return ":rotating_light:"
+	case domain.AlertSeverityHigh:
+		return ":red_circle:"
+	case domain.AlertSeverityWarning:
+		return ":warning:"
+	default:
+		return ":information_source:"
+	}
+}
+
+// postJSON sends a JSON body and treats any non-2xx response as a delivery failure
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
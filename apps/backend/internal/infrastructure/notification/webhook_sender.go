@@ -0,0 +1,56 @@
+package notification
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
+)
+
+// WebhookSender posts notifications as signed JSON to arbitrary HTTP endpoints
+type WebhookSender struct {
+	client *http.Client
+}
+
+// NewWebhookSender creates a new webhook sender
+func NewWebhookSender() *WebhookSender {
+	return &WebhookSender{client: resilience.WrapClient(&http.Client{Timeout: 10 * time.Second}, resilience.DependencyNotifications)}
+}
+
+// ChannelType returns the channel type handled by this sender
+func (s *WebhookSender) ChannelType() domain.NotificationChannelType {
+	return domain.NotificationChannelWebhook
+}
+
+// Send posts the notification to the endpoint URL given as recipient.
+// When the channel config carries a "secret", the body is HMAC-SHA256 signed
+// using the same X-Webhook-Signature header as webhook subscriptions.
+func (s *WebhookSender) Send(ctx context.Context, channel *domain.NotificationChannel, recipient string, n *domain.Notification) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"event":     n.EventType,
+		"id":        n.ID,
+		"timestamp": n.CreatedAt,
+		"data":      n,
+	})
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{
+		"X-Webhook-Event":   n.EventType,
+		"X-Notification-ID": n.ID.String(),
+	}
+	if secret, ok := channel.Config["secret"].(string); ok && secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		headers["X-Webhook-Signature"] = hex.EncodeToString(mac.Sum(nil))
+	}
+
+	return postJSON(ctx, s.client, recipient, body, headers)
+}
@@ -0,0 +1,104 @@
+package opa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
+)
+
+// maxResponseBytes bounds the decision document read from OPA
+const maxResponseBytes = 1 << 20
+
+// Client queries Open Policy Agent through its Data API (POST /v1/data/<path>)
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient creates a new OPA client. Per-request timeouts come from the PDP configuration.
+func NewClient() *Client {
+	return &Client{httpClient: resilience.WrapClient(&http.Client{Timeout: 30 * time.Second}, resilience.DependencyPolicyDecisionPoint)}
+}
+
+// Evaluate posts {"input": input} to the configured decision path and interprets the result.
+// The rule may return a bare boolean or an object with "allow" (or "allowed") and an optional
+// "reason" / "reasons". An undefined decision is an error so the fail-open/closed rule applies.
+func (c *Client) Evaluate(ctx context.Context, pdp *domain.ExternalPolicyDecisionPoint, input map[string]interface{}) (*domain.ExternalPolicyResult, error) {
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, err
+	}
+
+	url := strings.TrimRight(pdp.Endpoint, "/") + "/v1/data/" + strings.Trim(strings.ReplaceAll(pdp.DecisionPath, ".", "/"), "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "AIM-PDP/1.0")
+	if pdp.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+pdp.AuthToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("opa request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read opa response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("opa returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var decoded struct {
+		Result interface{} `json:"result"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid opa response: %w", err)
+	}
+
+	return ParseResult(decoded.Result)
+}
+
+// ParseResult converts an OPA result document into a decision
+func ParseResult(result interface{}) (*domain.ExternalPolicyResult, error) {
+	switch v := result.(type) {
+	case nil:
+		return nil, fmt.Errorf("opa decision is undefined (check the decision path)")
+	case bool:
+		return &domain.ExternalPolicyResult{Allowed: v, Raw: result}, nil
+	case map[string]interface{}:
+		allow, ok := v["allow"].(bool)
+		if !ok {
+			allow, ok = v["allowed"].(bool)
+		}
+		if !ok {
+			return nil, fmt.Errorf("opa result has no boolean 'allow' field")
+		}
+
+		decision := &domain.ExternalPolicyResult{Allowed: allow, Raw: result}
+		if reason, ok := v["reason"].(string); ok {
+			decision.Reason = reason
+		} else if reasons, ok := v["reasons"].([]interface{}); ok {
+			parts := make([]string, 0, len(reasons))
+			for _, r := range reasons {
+				parts = append(parts, fmt.Sprint(r))
+			}
+			decision.Reason = strings.Join(parts, "; ")
+		}
+		return decision, nil
+	default:
+		return nil, fmt.Errorf("unsupported opa result type %T", result)
+	}
+}
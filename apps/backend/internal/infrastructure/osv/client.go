@@ -0,0 +1,255 @@
+package osv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
+)
+
+const (
+	// DefaultBaseURL is the public OSV.dev API
+	DefaultBaseURL = "https://api.osv.dev"
+
+	// maxBatchQueries is the most queries OSV accepts in one /v1/querybatch request
+	maxBatchQueries = 1000
+	// maxResponseBytes bounds the documents read from OSV
+	maxResponseBytes = 8 << 20
+)
+
+// Client looks up known vulnerabilities in the OSV database (https://osv.dev)
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a new OSV client; an empty baseURL uses DefaultBaseURL
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: resilience.WrapClient(&http.Client{Timeout: 30 * time.Second}, resilience.DependencyVulnerabilityFeed),
+	}
+}
+
+type packageQuery struct {
+	Package struct {
+		PURL string `json:"purl"`
+	} `json:"package"`
+	Version string `json:"version,omitempty"`
+}
+
+type batchResponse struct {
+	Results []struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+	} `json:"results"`
+}
+
+type severityScore struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// vulnerability is the subset of an OSV record used to describe and rate a vulnerability
+type vulnerability struct {
+	ID               string                 `json:"id"`
+	Summary          string                 `json:"summary"`
+	Aliases          []string               `json:"aliases"`
+	Severity         []severityScore        `json:"severity"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific"`
+	Affected         []struct {
+		Severity          []severityScore        `json:"severity"`
+		EcosystemSpecific map[string]interface{} `json:"ecosystem_specific"`
+		DatabaseSpecific  map[string]interface{} `json:"database_specific"`
+	} `json:"affected"`
+}
+
+// QueryComponents implements domain.VulnerabilityDatabase. Components are matched by package
+// URL through /v1/querybatch, which only returns IDs, so the record of each distinct
+// vulnerability is then read from /v1/vulns/{id}. Components without a purl are skipped.
+func (c *Client) QueryComponents(ctx context.Context, components []domain.SBOMComponent) ([]domain.SBOMVulnerability, error) {
+	var queryable []domain.SBOMComponent
+	for _, component := range components {
+		if component.PURL != "" {
+			queryable = append(queryable, component)
+		}
+	}
+
+	records := make(map[string]*vulnerability)
+	var result []domain.SBOMVulnerability
+	for start := 0; start < len(queryable); start += maxBatchQueries {
+		batch := queryable[start:min(start+maxBatchQueries, len(queryable))]
+
+		matches, err := c.queryBatch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, ids := range matches {
+			for _, id := range ids {
+				record, ok := records[id]
+				if !ok {
+					if record, err = c.getVulnerability(ctx, id); err != nil {
+						return nil, err
+					}
+					records[id] = record
+				}
+
+				severity, score := record.rate()
+				result = append(result, domain.SBOMVulnerability{
+					ID:               record.ID,
+					Aliases:          record.Aliases,
+					Summary:          record.Summary,
+					Severity:         severity,
+					Score:            score,
+					ComponentName:    batch[i].Name,
+					ComponentVersion: batch[i].Version,
+					PURL:             batch[i].PURL,
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// queryBatch returns the IDs of the vulnerabilities affecting each component, in order
+func (c *Client) queryBatch(ctx context.Context, components []domain.SBOMComponent) ([][]string, error) {
+	queries := make([]packageQuery, len(components))
+	for i, component := range components {
+		queries[i].Package.PURL = component.PURL
+		// OSV rejects a separate version when the purl already pins one
+		if !strings.Contains(component.PURL, "@") {
+			queries[i].Version = component.Version
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"queries": queries})
+	if err != nil {
+		return nil, err
+	}
+
+	var response batchResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/querybatch", body, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Results) != len(components) {
+		return nil, fmt.Errorf("osv returned %d results for %d queries", len(response.Results), len(components))
+	}
+
+	matches := make([][]string, len(components))
+	for i, result := range response.Results {
+		for _, vuln := range result.Vulns {
+			matches[i] = append(matches[i], vuln.ID)
+		}
+	}
+	return matches, nil
+}
+
+func (c *Client) getVulnerability(ctx context.Context, id string) (*vulnerability, error) {
+	var record vulnerability
+	if err := c.do(ctx, http.MethodGet, "/v1/vulns/"+id, nil, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "AIM-SBOM/1.0")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("osv request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read osv response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("osv returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid osv response: %w", err)
+	}
+	return nil
+}
+
+// rate prefers a CVSS v3 base score and falls back to the severity label the source
+// database publishes (GitHub advisories use database_specific.severity)
+func (v *vulnerability) rate() (domain.VulnerabilitySeverity, *float64) {
+	scores := v.Severity
+	for _, affected := range v.Affected {
+		scores = append(scores, affected.Severity...)
+	}
+	for _, score := range scores {
+		if score.Type != "CVSS_V3" {
+			continue
+		}
+		if base, ok := CVSSv3BaseScore(score.Score); ok {
+			return SeverityFromScore(base), &base
+		}
+	}
+
+	labels := []map[string]interface{}{v.DatabaseSpecific}
+	for _, affected := range v.Affected {
+		labels = append(labels, affected.EcosystemSpecific, affected.DatabaseSpecific)
+	}
+	for _, fields := range labels {
+		if label, ok := fields["severity"].(string); ok {
+			if severity := severityFromLabel(label); severity != domain.VulnerabilitySeverityUnknown {
+				return severity, nil
+			}
+		}
+	}
+
+	return domain.VulnerabilitySeverityUnknown, nil
+}
+
+// SeverityFromScore maps a CVSS base score to its qualitative rating
+func SeverityFromScore(score float64) domain.VulnerabilitySeverity {
+	switch {
+	case score >= 9.0:
+		return domain.VulnerabilitySeverityCritical
+	case score >= 7.0:
+		return domain.VulnerabilitySeverityHigh
+	case score >= 4.0:
+		return domain.VulnerabilitySeverityMedium
+	case score > 0:
+		return domain.VulnerabilitySeverityLow
+	default:
+		return domain.VulnerabilitySeverityUnknown
+	}
+}
+
+func severityFromLabel(label string) domain.VulnerabilitySeverity {
+	switch strings.ToUpper(strings.TrimSpace(label)) {
+	case "CRITICAL":
+		return domain.VulnerabilitySeverityCritical
+	case "HIGH", "IMPORTANT":
+		return domain.VulnerabilitySeverityHigh
+	case "MODERATE", "MEDIUM":
+		return domain.VulnerabilitySeverityMedium
+	case "LOW", "NEGLIGIBLE":
+		return domain.VulnerabilitySeverityLow
+	default:
+		return domain.VulnerabilitySeverityUnknown
+	}
+}
@@ -0,0 +1,99 @@
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCVSSv3BaseScore(t *testing.T) {
+	cases := map[string]float64{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H": 9.8,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H": 10.0,
+		"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N": 6.5,
+		"CVSS:3.0/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N": 6.1,
+		"CVSS:3.1/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N": 1.8,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N": 0,
+	}
+	for vector, expected := range cases {
+		score, ok := CVSSv3BaseScore(vector)
+		require.True(t, ok, vector)
+		assert.Equal(t, expected, score, vector)
+	}
+
+	_, ok := CVSSv3BaseScore("CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N")
+	assert.False(t, ok, "CVSS v4 vectors are not scored")
+	_, ok = CVSSv3BaseScore("CVSS:3.1/AV:N/AC:L/PR:N")
+	assert.False(t, ok, "incomplete vectors are not scored")
+}
+
+func TestQueryComponents(t *testing.T) {
+	var detailRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/querybatch":
+			var body struct {
+				Queries []struct {
+					Package struct {
+						PURL string `json:"purl"`
+					} `json:"package"`
+					Version string `json:"version"`
+				} `json:"queries"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Len(t, body.Queries, 2, "components without a purl are not queried")
+			assert.Empty(t, body.Queries[0].Version, "versioned purls are sent as is")
+			assert.Equal(t, "1.0.0", body.Queries[1].Version)
+
+			w.Write([]byte(`{"results":[
+				{"vulns":[{"id":"GHSA-crit"},{"id":"GHSA-label"}]},
+				{"vulns":[{"id":"GHSA-crit"}]}
+			]}`))
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/vulns/"):
+			detailRequests.Add(1)
+			switch strings.TrimPrefix(r.URL.Path, "/v1/vulns/") {
+			case "GHSA-crit":
+				w.Write([]byte(`{"id":"GHSA-crit","summary":"RCE","aliases":["CVE-2024-0001"],
+					"severity":[{"type":"CVSS_V3","score":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}]}`))
+			case "GHSA-label":
+				w.Write([]byte(`{"id":"GHSA-label","database_specific":{"severity":"MODERATE","cwe_ids":["CWE-400"]}}`))
+			default:
+				http.NotFound(w, r)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	vulns, err := client.QueryComponents(context.Background(), []domain.SBOMComponent{
+		{Name: "lodash", Version: "4.17.20", PURL: "pkg:npm/lodash@4.17.20"},
+		{Name: "internal-tool", Version: "2.0.0"},
+		{Name: "requests", Version: "1.0.0", PURL: "pkg:pypi/requests"},
+	})
+	require.NoError(t, err)
+	require.Len(t, vulns, 3)
+	assert.Equal(t, int32(2), detailRequests.Load(), "each vulnerability record is fetched once")
+
+	assert.Equal(t, "GHSA-crit", vulns[0].ID)
+	assert.Equal(t, domain.VulnerabilitySeverityCritical, vulns[0].Severity)
+	require.NotNil(t, vulns[0].Score)
+	assert.Equal(t, 9.8, *vulns[0].Score)
+	assert.Equal(t, []string{"CVE-2024-0001"}, vulns[0].Aliases)
+	assert.Equal(t, "lodash", vulns[0].ComponentName)
+
+	assert.Equal(t, domain.VulnerabilitySeverityMedium, vulns[1].Severity)
+	assert.Nil(t, vulns[1].Score)
+
+	assert.Equal(t, "requests", vulns[2].ComponentName)
+	assert.Equal(t, "pkg:pypi/requests", vulns[2].PURL)
+}
@@ -0,0 +1,78 @@
+package osv
+
+import (
+	"math"
+	"strings"
+)
+
+// CVSS v3 base metric weights (CVSS v3.1 specification, section 7.4)
+var (
+	cvssAttackVector       = map[string]float64{"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2}
+	cvssAttackComplexity   = map[string]float64{"L": 0.77, "H": 0.44}
+	cvssUserInteraction    = map[string]float64{"N": 0.85, "R": 0.62}
+	cvssImpact             = map[string]float64{"H": 0.56, "L": 0.22, "N": 0}
+	cvssPrivilegesUnscoped = map[string]float64{"N": 0.85, "L": 0.62, "H": 0.27}
+	cvssPrivilegesScoped   = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}
+)
+
+// CVSSv3BaseScore computes the base score of a CVSS v3.0 or v3.1 vector such as
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H". It reports false for other
+// versions and for vectors missing a base metric.
+func CVSSv3BaseScore(vector string) (float64, bool) {
+	parts := strings.Split(strings.TrimSpace(vector), "/")
+	if len(parts) == 0 || (parts[0] != "CVSS:3.0" && parts[0] != "CVSS:3.1") {
+		return 0, false
+	}
+
+	metrics := make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		if key, value, ok := strings.Cut(part, ":"); ok {
+			metrics[key] = value
+		}
+	}
+
+	scope := metrics["S"]
+	if scope != "U" && scope != "C" {
+		return 0, false
+	}
+	privileges := cvssPrivilegesUnscoped
+	if scope == "C" {
+		privileges = cvssPrivilegesScoped
+	}
+
+	av, okAV := cvssAttackVector[metrics["AV"]]
+	ac, okAC := cvssAttackComplexity[metrics["AC"]]
+	pr, okPR := privileges[metrics["PR"]]
+	ui, okUI := cvssUserInteraction[metrics["UI"]]
+	c, okC := cvssImpact[metrics["C"]]
+	i, okI := cvssImpact[metrics["I"]]
+	a, okA := cvssImpact[metrics["A"]]
+	if !okAV || !okAC || !okPR || !okUI || !okC || !okI || !okA {
+		return 0, false
+	}
+
+	iss := 1 - (1-c)*(1-i)*(1-a)
+	impact := 6.42 * iss
+	if scope == "C" {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+
+	exploitability := 8.22 * av * ac * pr * ui
+	if scope == "C" {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// roundUp is the CVSS v3.1 Roundup function: the smallest one-decimal number >= value,
+// computed on integers to avoid floating point artifacts
+func roundUp(value float64) float64 {
+	scaled := int64(math.Round(value * 100000))
+	if scaled%10000 == 0 {
+		return float64(scaled) / 100000
+	}
+	return float64(scaled/10000+1) / 10
+}
@@ -0,0 +1,49 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// CSVRenderer renders report tables as RFC 4180 CSV
+type CSVRenderer struct{}
+
+// NewCSVRenderer creates a new CSV renderer
+func NewCSVRenderer() *CSVRenderer {
+	return &CSVRenderer{}
+}
+
+// Format returns the format produced by this renderer
+func (r *CSVRenderer) Format() domain.ReportFormat {
+	return domain.ReportFormatCSV
+}
+
+// ContentType returns the MIME type of rendered files
+func (r *CSVRenderer) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+// Render writes the table header and rows. Metrics and the period are left out
+// so the file loads cleanly into spreadsheets; they are part of the notification text.
+func (r *CSVRenderer) Render(table *domain.ReportTable, periodStart, periodEnd time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(table.Columns); err != nil {
+		return nil, err
+	}
+	for _, row := range table.Rows {
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,205 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	pdfFontSize   = 9.0
+	pdfLineHeight = 12.0
+	pdfMargin     = 40.0
+	// pdfCharWidth is the advance of one Courier glyph at pdfFontSize (600/1000 em)
+	pdfCharWidth = pdfFontSize * 0.6
+	// pdfMaxColumnWidth caps a single column so one long value can't push the rest off the page
+	pdfMaxColumnWidth = 40
+)
+
+// PDFRenderer renders report tables as a plain monospaced PDF document.
+// It writes the PDF objects directly (Courier is one of the standard 14 fonts,
+// so nothing needs to be embedded) to avoid pulling in a PDF library.
+type PDFRenderer struct{}
+
+// NewPDFRenderer creates a new PDF renderer
+func NewPDFRenderer() *PDFRenderer {
+	return &PDFRenderer{}
+}
+
+// Format returns the format produced by this renderer
+func (r *PDFRenderer) Format() domain.ReportFormat {
+	return domain.ReportFormatPDF
+}
+
+// ContentType returns the MIME type of rendered files
+func (r *PDFRenderer) ContentType() string {
+	return "application/pdf"
+}
+
+// Render lays the report out as text lines and paginates them
+func (r *PDFRenderer) Render(table *domain.ReportTable, periodStart, periodEnd time.Time) ([]byte, error) {
+	lines := []string{
+		table.Title,
+		fmt.Sprintf("Period: %s - %s", periodStart.UTC().Format("2006-01-02 15:04 MST"), periodEnd.UTC().Format("2006-01-02 15:04 MST")),
+		"",
+	}
+	for _, metric := range table.Metrics {
+		lines = append(lines, fmt.Sprintf("%-28s %s", metric.Label+":", metric.Value))
+	}
+	lines = append(lines, "")
+
+	tableLines := formatTextTable(table.Columns, table.Rows)
+	if len(table.Rows) == 0 {
+		tableLines = append(tableLines, "No data for this period.")
+	}
+
+	// Switch to landscape when the table does not fit portrait width
+	width, height := 595.0, 842.0
+	maxChars := int((width - 2*pdfMargin) / pdfCharWidth)
+	if longestLine(tableLines) > maxChars {
+		width, height = height, width
+		maxChars = int((width - 2*pdfMargin) / pdfCharWidth)
+	}
+	for _, line := range tableLines {
+		if len(line) > maxChars {
+			line = line[:maxChars]
+		}
+		lines = append(lines, line)
+	}
+
+	linesPerPage := int((height - 2*pdfMargin) / pdfLineHeight)
+	var pages [][]string
+	for len(lines) > 0 {
+		n := linesPerPage
+		if n > len(lines) {
+			n = len(lines)
+		}
+		pages = append(pages, lines[:n])
+		lines = lines[n:]
+	}
+
+	return writePDF(pages, width, height), nil
+}
+
+// formatTextTable pads columns to a common width and adds a header rule
+func formatTextTable(columns []string, rows [][]string) []string {
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = len(column)
+	}
+	for _, row := range rows {
+		for i := 0; i < len(row) && i < len(widths); i++ {
+			if len(row[i]) > widths[i] {
+				widths[i] = len(row[i])
+			}
+		}
+	}
+	for i := range widths {
+		if widths[i] > pdfMaxColumnWidth {
+			widths[i] = pdfMaxColumnWidth
+		}
+	}
+
+	format := func(cells []string) string {
+		parts := make([]string, len(widths))
+		for i, w := range widths {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			if len(cell) > w {
+				cell = cell[:w-1] + "~"
+			}
+			parts[i] = fmt.Sprintf("%-*s", w, cell)
+		}
+		return strings.TrimRight(strings.Join(parts, "  "), " ")
+	}
+
+	rule := make([]string, len(widths))
+	for i, w := range widths {
+		rule[i] = strings.Repeat("-", w)
+	}
+
+	lines := []string{format(columns), strings.Join(rule, "  ")}
+	for _, row := range rows {
+		lines = append(lines, format(row))
+	}
+	return lines
+}
+
+func longestLine(lines []string) int {
+	longest := 0
+	for _, line := range lines {
+		if len(line) > longest {
+			longest = len(line)
+		}
+	}
+	return longest
+}
+
+// writePDF serializes pages of text lines into a PDF 1.4 document
+func writePDF(pages [][]string, width, height float64) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+
+	// Object numbers: 1 catalog, 2 page tree, 3 font, then a page + content stream pair per page
+	writeObject := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+i*2)
+	}
+
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %.1f Tf\n%.1f TL\n%.1f %.1f Td\n", pdfFontSize, pdfLineHeight, pdfMargin, height-pdfMargin)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) '\n", escapePDFText(line))
+		}
+		content.WriteString("ET")
+
+		writeObject(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			width, height, 5+i*2,
+		))
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// escapePDFText escapes string delimiters and replaces characters outside printable ASCII
+func escapePDFText(s string) string {
+	var b strings.Builder
+	for _, ch := range s {
+		switch {
+		case ch == '(' || ch == ')' || ch == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(ch)
+		case ch < 0x20 || ch > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(ch)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// AgentCertificateRepository implements domain.AgentCertificateRepository
+type AgentCertificateRepository struct {
+	db *sql.DB
+}
+
+// NewAgentCertificateRepository creates a new agent certificate repository
+func NewAgentCertificateRepository(db *sql.DB) *AgentCertificateRepository {
+	return &AgentCertificateRepository{db: db}
+}
+
+const agentCertificateColumns = `id, organization_id, agent_id, serial_number, public_key, certificate_pem,
+	not_before, not_after, revoked_at, revocation_reason, created_at`
+
+// Create stores a newly issued certificate
+func (r *AgentCertificateRepository) Create(certificate *domain.AgentCertificate) error {
+	if certificate.ID == uuid.Nil {
+		certificate.ID = uuid.New()
+	}
+	if certificate.CreatedAt.IsZero() {
+		certificate.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO agent_certificates (`+agentCertificateColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		certificate.ID,
+		certificate.OrganizationID,
+		certificate.AgentID,
+		certificate.SerialNumber,
+		certificate.PublicKey,
+		certificate.CertificatePEM,
+		certificate.NotBefore,
+		certificate.NotAfter,
+		certificate.RevokedAt,
+		certificate.RevocationReason,
+		certificate.CreatedAt,
+	)
+	return err
+}
+
+// GetBySerialNumber retrieves a certificate by its hex-encoded serial number
+func (r *AgentCertificateRepository) GetBySerialNumber(serialNumber string) (*domain.AgentCertificate, error) {
+	query := `SELECT ` + agentCertificateColumns + ` FROM agent_certificates WHERE serial_number = $1`
+
+	certificate, err := scanAgentCertificate(r.db.QueryRow(query, serialNumber))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAgentCertificateNotFound
+	}
+	return certificate, err
+}
+
+// GetByAgent retrieves all certificates of an agent, newest first
+func (r *AgentCertificateRepository) GetByAgent(agentID uuid.UUID) ([]*domain.AgentCertificate, error) {
+	rows, err := r.db.Query(`
+		SELECT `+agentCertificateColumns+`
+		FROM agent_certificates
+		WHERE agent_id = $1
+		ORDER BY created_at DESC
+	`, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAgentCertificates(rows)
+}
+
+// RevokeByAgent revokes every unrevoked certificate of the agent
+func (r *AgentCertificateRepository) RevokeByAgent(agentID uuid.UUID, reason domain.CertificateRevocationReason, at time.Time) (int, error) {
+	result, err := r.db.Exec(`
+		UPDATE agent_certificates
+		SET revoked_at = $1, revocation_reason = $2
+		WHERE agent_id = $3 AND revoked_at IS NULL
+	`, at, reason, agentID)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(rows), nil
+}
+
+// ListRevoked retrieves the revoked certificates that have not expired yet
+func (r *AgentCertificateRepository) ListRevoked(at time.Time) ([]*domain.AgentCertificate, error) {
+	rows, err := r.db.Query(`
+		SELECT `+agentCertificateColumns+`
+		FROM agent_certificates
+		WHERE revoked_at IS NOT NULL AND not_after > $1
+		ORDER BY revoked_at
+	`, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAgentCertificates(rows)
+}
+
+func scanAgentCertificates(rows *sql.Rows) ([]*domain.AgentCertificate, error) {
+	var certificates []*domain.AgentCertificate
+	for rows.Next() {
+		certificate, err := scanAgentCertificate(rows)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+	return certificates, rows.Err()
+}
+
+func scanAgentCertificate(row interface{ Scan(...interface{}) error }) (*domain.AgentCertificate, error) {
+	certificate := &domain.AgentCertificate{}
+	var revokedAt sql.NullTime
+	var revocationReason sql.NullString
+
+	err := row.Scan(
+		&certificate.ID,
+		&certificate.OrganizationID,
+		&certificate.AgentID,
+		&certificate.SerialNumber,
+		&certificate.PublicKey,
+		&certificate.CertificatePEM,
+		&certificate.NotBefore,
+		&certificate.NotAfter,
+		&revokedAt,
+		&revocationReason,
+		&certificate.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		certificate.RevokedAt = &revokedAt.Time
+	}
+	if revocationReason.Valid {
+		reason := domain.CertificateRevocationReason(revocationReason.String)
+		certificate.RevocationReason = &reason
+	}
+
+	return certificate, nil
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// AgentDelegationRepository implements domain.AgentDelegationRepository
+type AgentDelegationRepository struct {
+	db *sql.DB
+}
+
+// NewAgentDelegationRepository creates a new agent delegation repository
+func NewAgentDelegationRepository(db *sql.DB) *AgentDelegationRepository {
+	return &AgentDelegationRepository{db: db}
+}
+
+const agentDelegationColumns = `id, organization_id, parent_agent_id, child_agent_id, depth, capabilities, talks_to, purpose, inherited_trust, requested_by, created_by, created_at, revoked_at, revocation_reason`
+
+// Create stores a new delegation
+func (r *AgentDelegationRepository) Create(delegation *domain.AgentDelegation) error {
+	query := `
+		INSERT INTO agent_delegations (` + agentDelegationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+	if delegation.ID == uuid.Nil {
+		delegation.ID = uuid.New()
+	}
+	if delegation.CreatedAt.IsZero() {
+		delegation.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.Exec(query,
+		delegation.ID,
+		delegation.OrganizationID,
+		delegation.ParentAgentID,
+		delegation.ChildAgentID,
+		delegation.Depth,
+		pq.Array(delegation.Capabilities),
+		pq.Array(delegation.TalksTo),
+		nullString(delegation.Purpose),
+		delegation.InheritedTrust,
+		delegation.RequestedBy,
+		uuid.NullUUID{UUID: delegation.CreatedBy, Valid: delegation.CreatedBy != uuid.Nil},
+		delegation.CreatedAt,
+		delegation.RevokedAt,
+		nullString(delegation.RevocationReason),
+	)
+	return err
+}
+
+// GetByChild returns the delegation that created the agent
+func (r *AgentDelegationRepository) GetByChild(childAgentID uuid.UUID) (*domain.AgentDelegation, error) {
+	query := `SELECT ` + agentDelegationColumns + ` FROM agent_delegations WHERE child_agent_id = $1`
+
+	delegation, err := scanAgentDelegation(r.db.QueryRow(query, childAgentID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAgentDelegationNotFound
+	}
+	return delegation, err
+}
+
+// ListByParent returns the delegations the agent made, oldest first
+func (r *AgentDelegationRepository) ListByParent(parentAgentID uuid.UUID) ([]*domain.AgentDelegation, error) {
+	query := `
+		SELECT ` + agentDelegationColumns + `
+		FROM agent_delegations
+		WHERE parent_agent_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(query, parentAgentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	delegations := make([]*domain.AgentDelegation, 0)
+	for rows.Next() {
+		delegation, err := scanAgentDelegation(rows)
+		if err != nil {
+			return nil, err
+		}
+		delegations = append(delegations, delegation)
+	}
+	return delegations, rows.Err()
+}
+
+// MarkRevoked records that the child agent was revoked; an earlier revocation is kept
+func (r *AgentDelegationRepository) MarkRevoked(childAgentID uuid.UUID, revokedAt time.Time, reason string) error {
+	query := `
+		UPDATE agent_delegations
+		SET revoked_at = $1, revocation_reason = $2
+		WHERE child_agent_id = $3 AND revoked_at IS NULL
+	`
+	_, err := r.db.Exec(query, revokedAt, nullString(reason), childAgentID)
+	return err
+}
+
+func scanAgentDelegation(row rowScanner) (*domain.AgentDelegation, error) {
+	delegation := &domain.AgentDelegation{}
+	var purpose, revocationReason sql.NullString
+	var createdBy uuid.NullUUID
+	var revokedAt sql.NullTime
+
+	err := row.Scan(
+		&delegation.ID,
+		&delegation.OrganizationID,
+		&delegation.ParentAgentID,
+		&delegation.ChildAgentID,
+		&delegation.Depth,
+		pq.Array(&delegation.Capabilities),
+		pq.Array(&delegation.TalksTo),
+		&purpose,
+		&delegation.InheritedTrust,
+		&delegation.RequestedBy,
+		&createdBy,
+		&delegation.CreatedAt,
+		&revokedAt,
+		&revocationReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delegation.Purpose = purpose.String
+	delegation.RevocationReason = revocationReason.String
+	delegation.CreatedBy = createdBy.UUID
+	if revokedAt.Valid {
+		delegation.RevokedAt = &revokedAt.Time
+	}
+	if delegation.Capabilities == nil {
+		delegation.Capabilities = []string{}
+	}
+	if delegation.TalksTo == nil {
+		delegation.TalksTo = []string{}
+	}
+	return delegation, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// AgentLineageRepository implements domain.AgentLineageRepository
+type AgentLineageRepository struct {
+	db *sql.DB
+}
+
+// NewAgentLineageRepository creates a new agent lineage repository
+func NewAgentLineageRepository(db *sql.DB) *AgentLineageRepository {
+	return &AgentLineageRepository{db: db}
+}
+
+// Create stores a deployment an agent was imported from
+func (r *AgentLineageRepository) Create(entry *domain.AgentLineageEntry) error {
+	query := `
+		INSERT INTO agent_lineage (id, agent_id, organization_id, issuer_name, issuer_key_fingerprint,
+			source_agent_id, source_organization_id, registered_at, exported_at, imported_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+
+	_, err := r.db.Exec(query,
+		entry.ID,
+		entry.AgentID,
+		entry.OrganizationID,
+		entry.IssuerName,
+		entry.IssuerKeyFingerprint,
+		entry.SourceAgentID,
+		entry.SourceOrganizationID,
+		entry.RegisteredAt,
+		entry.ExportedAt,
+		entry.ImportedAt,
+	)
+	return err
+}
+
+// ListByAgent returns the agent's lineage, oldest hop first
+func (r *AgentLineageRepository) ListByAgent(agentID uuid.UUID) ([]*domain.AgentLineageEntry, error) {
+	query := `
+		SELECT id, agent_id, organization_id, issuer_name, issuer_key_fingerprint,
+			source_agent_id, source_organization_id, registered_at, exported_at, imported_at
+		FROM agent_lineage
+		WHERE agent_id = $1
+		ORDER BY imported_at
+	`
+	rows, err := r.db.Query(query, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.AgentLineageEntry, 0)
+	for rows.Next() {
+		entry := &domain.AgentLineageEntry{}
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.AgentID,
+			&entry.OrganizationID,
+			&entry.IssuerName,
+			&entry.IssuerKeyFingerprint,
+			&entry.SourceAgentID,
+			&entry.SourceOrganizationID,
+			&entry.RegisteredAt,
+			&entry.ExportedAt,
+			&entry.ImportedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// AgentListingRepository implements domain.AgentListingRepository
+type AgentListingRepository struct {
+	db *sql.DB
+}
+
+// NewAgentListingRepository creates a new agent listing repository
+func NewAgentListingRepository(db *sql.DB) *AgentListingRepository {
+	return &AgentListingRepository{db: db}
+}
+
+// Upsert lists the agent; an existing listing is returned unchanged
+func (r *AgentListingRepository) Upsert(listing *domain.AgentListing) error {
+	if listing.ListedAt.IsZero() {
+		listing.ListedAt = time.Now().UTC()
+	}
+
+	var listedBy uuid.NullUUID
+	err := r.db.QueryRow(`
+		INSERT INTO agent_listings (agent_id, organization_id, listed_by, listed_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (agent_id) DO UPDATE SET agent_id = agent_listings.agent_id
+		RETURNING listed_by, listed_at
+	`, listing.AgentID, listing.OrganizationID, listing.ListedBy, listing.ListedAt).Scan(&listedBy, &listing.ListedAt)
+	if err != nil {
+		return err
+	}
+	listing.ListedBy = nil
+	if listedBy.Valid {
+		listing.ListedBy = &listedBy.UUID
+	}
+	return nil
+}
+
+// Get retrieves the listing of an agent
+func (r *AgentListingRepository) Get(agentID uuid.UUID) (*domain.AgentListing, error) {
+	listing := &domain.AgentListing{}
+	var listedBy uuid.NullUUID
+	err := r.db.QueryRow(`
+		SELECT agent_id, organization_id, listed_by, listed_at
+		FROM agent_listings
+		WHERE agent_id = $1
+	`, agentID).Scan(&listing.AgentID, &listing.OrganizationID, &listedBy, &listing.ListedAt)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAgentListingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if listedBy.Valid {
+		listing.ListedBy = &listedBy.UUID
+	}
+	return listing, nil
+}
+
+// Delete unlists an agent; unlisting an agent that is not listed is not an error
+func (r *AgentListingRepository) Delete(agentID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM agent_listings WHERE agent_id = $1`, agentID)
+	return err
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// AgentPeerPolicyRepository implements domain.AgentPeerPolicyRepository
+type AgentPeerPolicyRepository struct {
+	db *sql.DB
+}
+
+// NewAgentPeerPolicyRepository creates a new agent peer policy repository
+func NewAgentPeerPolicyRepository(db *sql.DB) *AgentPeerPolicyRepository {
+	return &AgentPeerPolicyRepository{db: db}
+}
+
+const agentPeerPolicyColumns = `id, organization_id, source_agent_id, target_agent_id, bidirectional, allowed_actions, min_source_trust_score, min_target_trust_score, description, is_enabled, created_by, created_at, updated_at`
+
+// Create stores a new peer policy
+func (r *AgentPeerPolicyRepository) Create(policy *domain.AgentPeerPolicy) error {
+	query := `
+		INSERT INTO agent_peer_policies (` + agentPeerPolicyColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (source_agent_id, target_agent_id) DO NOTHING
+	`
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+
+	result, err := r.db.Exec(query,
+		policy.ID,
+		policy.OrganizationID,
+		policy.SourceAgentID,
+		policy.TargetAgentID,
+		policy.Bidirectional,
+		pq.Array(policy.AllowedActions),
+		policy.MinSourceTrustScore,
+		policy.MinTargetTrustScore,
+		nullString(policy.Description),
+		policy.IsEnabled,
+		policy.CreatedBy,
+		policy.CreatedAt,
+		policy.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrAgentPeerPolicyExists
+	}
+	return nil
+}
+
+// GetByID retrieves a peer policy by ID
+func (r *AgentPeerPolicyRepository) GetByID(id uuid.UUID) (*domain.AgentPeerPolicy, error) {
+	query := `SELECT ` + agentPeerPolicyColumns + ` FROM agent_peer_policies WHERE id = $1`
+
+	policy, err := scanAgentPeerPolicy(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAgentPeerPolicyNotFound
+	}
+	return policy, err
+}
+
+// ListByOrganization returns the organization's peer policies, oldest first
+func (r *AgentPeerPolicyRepository) ListByOrganization(orgID uuid.UUID) ([]*domain.AgentPeerPolicy, error) {
+	query := `
+		SELECT ` + agentPeerPolicyColumns + `
+		FROM agent_peer_policies
+		WHERE organization_id = $1
+		ORDER BY created_at
+	`
+	return r.query(query, orgID)
+}
+
+// ListByAgent returns the peer policies the agent is the source or the target of
+func (r *AgentPeerPolicyRepository) ListByAgent(agentID uuid.UUID) ([]*domain.AgentPeerPolicy, error) {
+	query := `
+		SELECT ` + agentPeerPolicyColumns + `
+		FROM agent_peer_policies
+		WHERE source_agent_id = $1 OR target_agent_id = $1
+		ORDER BY created_at
+	`
+	return r.query(query, agentID)
+}
+
+// Update stores the changed settings of a peer policy
+func (r *AgentPeerPolicyRepository) Update(policy *domain.AgentPeerPolicy) error {
+	query := `
+		UPDATE agent_peer_policies
+		SET bidirectional = $1, allowed_actions = $2, min_source_trust_score = $3, min_target_trust_score = $4,
+			description = $5, is_enabled = $6, updated_at = $7
+		WHERE id = $8
+	`
+	result, err := r.db.Exec(query,
+		policy.Bidirectional,
+		pq.Array(policy.AllowedActions),
+		policy.MinSourceTrustScore,
+		policy.MinTargetTrustScore,
+		nullString(policy.Description),
+		policy.IsEnabled,
+		policy.UpdatedAt,
+		policy.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrAgentPeerPolicyNotFound
+	}
+	return nil
+}
+
+// Delete removes a peer policy
+func (r *AgentPeerPolicyRepository) Delete(id uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM agent_peer_policies WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrAgentPeerPolicyNotFound
+	}
+	return nil
+}
+
+func (r *AgentPeerPolicyRepository) query(query string, args ...interface{}) ([]*domain.AgentPeerPolicy, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := make([]*domain.AgentPeerPolicy, 0)
+	for rows.Next() {
+		policy, err := scanAgentPeerPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
+
+func scanAgentPeerPolicy(row rowScanner) (*domain.AgentPeerPolicy, error) {
+	policy := &domain.AgentPeerPolicy{}
+	var description sql.NullString
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&policy.ID,
+		&policy.OrganizationID,
+		&policy.SourceAgentID,
+		&policy.TargetAgentID,
+		&policy.Bidirectional,
+		pq.Array(&policy.AllowedActions),
+		&policy.MinSourceTrustScore,
+		&policy.MinTargetTrustScore,
+		&description,
+		&policy.IsEnabled,
+		&createdBy,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	policy.Description = description.String
+	policy.CreatedBy = createdBy.UUID
+	if policy.AllowedActions == nil {
+		policy.AllowedActions = []string{}
+	}
+	return policy, nil
+}
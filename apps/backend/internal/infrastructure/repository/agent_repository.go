@@ -8,12 +8,14 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
 // AgentRepository implements domain.AgentRepository
 type AgentRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	cache *recordCache
 }
 
 // NewAgentRepository creates a new agent repository
@@ -21,6 +23,17 @@ func NewAgentRepository(db *sql.DB) *AgentRepository {
 	return &AgentRepository{db: db}
 }
 
+// UseCache caches GetByID lookups for ttl; a ttl of zero turns caching off
+func (r *AgentRepository) UseCache(cache domain.IdentityCache, ttl time.Duration) {
+	r.cache = newRecordCache(cache, ttl)
+}
+
+// SetCacheTTL changes the TTL of UseCache while the repository is in use; a ttl of zero turns
+// caching off
+func (r *AgentRepository) SetCacheTTL(ttl time.Duration) {
+	r.cache.setTTL(ttl)
+}
+
 // Create creates a new agent
 func (r *AgentRepository) Create(agent *domain.Agent) error {
 	query := `
@@ -83,16 +96,70 @@ func (r *AgentRepository) Create(agent *domain.Agent) error {
 	return err
 }
 
+// agentColumns are the columns read by scanAgent
+const agentColumns = `id, organization_id, name, display_name, description, agent_type, status, version,
+		       public_key, encrypted_private_key, key_algorithm, certificate_url, repository_url, documentation_url,
+		       trust_score, verified_at, talks_to, capabilities, created_at, updated_at, created_by, last_active,
+		       key_created_at, key_expires_at, key_rotation_grace_until, previous_public_key, previous_key_algorithm, rotation_count`
+
 // GetByID retrieves an agent by ID
 func (r *AgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
+	cached := &domain.Agent{}
+	if r.cache.get(agentCacheKey(id), cached) {
+		return cached, nil
+	}
+
 	query := `
-		SELECT id, organization_id, name, display_name, description, agent_type, status, version,
-		       public_key, encrypted_private_key, key_algorithm, certificate_url, repository_url, documentation_url,
-		       trust_score, verified_at, talks_to, capabilities, created_at, updated_at, created_by, last_active
+		SELECT ` + agentColumns + `
 		FROM agents
 		WHERE id = $1
 	`
 
+	agent, err := scanAgent(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("agent not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.set(agentCacheKey(id), agent)
+	return agent, nil
+}
+
+// GetByIDs retrieves the agents with the given IDs in one query; IDs that do not exist are skipped
+func (r *AgentRepository) GetByIDs(ids []uuid.UUID) ([]*domain.Agent, error) {
+	agents := make([]*domain.Agent, 0, len(ids))
+	if len(ids) == 0 {
+		return agents, nil
+	}
+
+	query := `
+		SELECT ` + agentColumns + `
+		FROM agents
+		WHERE id = ANY($1::uuid[])
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, pq.Array(uuidStrings(ids)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		agent, err := scanAgent(rows)
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, agent)
+	}
+
+	return agents, rows.Err()
+}
+
+// scanAgent reads a row selected with agentColumns
+func scanAgent(row rowScanner) (*domain.Agent, error) {
 	agent := &domain.Agent{}
 	var publicKey sql.NullString
 	var encryptedPrivateKey sql.NullString
@@ -103,8 +170,11 @@ func (r *AgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
 	var talksToJSON []byte
 	var capabilitiesJSON []byte
 	var lastActive sql.NullTime
+	var previousPublicKey sql.NullString
+	var previousKeyAlgorithm sql.NullString
+	var rotationCount sql.NullInt32
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := row.Scan(
 		&agent.ID,
 		&agent.OrganizationID,
 		&agent.Name,
@@ -127,11 +197,13 @@ func (r *AgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
 		&agent.UpdatedAt,
 		&agent.CreatedBy,
 		&lastActive,
+		&agent.KeyCreatedAt,
+		&agent.KeyExpiresAt,
+		&agent.KeyRotationGraceUntil,
+		&previousPublicKey,
+		&previousKeyAlgorithm,
+		&rotationCount,
 	)
-
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("agent not found")
-	}
 	if err != nil {
 		return nil, err
 	}
@@ -158,6 +230,11 @@ func (r *AgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
 	if lastActive.Valid {
 		agent.LastActive = &lastActive.Time
 	}
+	if previousPublicKey.Valid {
+		agent.PreviousPublicKey = &previousPublicKey.String
+	}
+	agent.PreviousKeyAlgorithm = previousKeyAlgorithm.String
+	agent.RotationCount = int(rotationCount.Int32)
 
 	// Unmarshal talks_to from JSONB
 	if len(talksToJSON) > 0 {
@@ -296,15 +373,54 @@ func (r *AgentRepository) Update(agent *domain.Agent) error {
 		agent.UpdatedAt,
 		agent.ID,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	r.cache.invalidate(agentCacheKey(agent.ID))
+	return nil
 }
 
-// Delete deletes an agent
+// Delete deletes an agent, along with its API keys
 func (r *AgentRepository) Delete(id uuid.UUID) error {
+	keys := []string{agentCacheKey(id)}
+	if r.cache != nil {
+		// The agent's API keys are deleted with it; their cached lookups must go too
+		hashes, err := r.apiKeyHashes(id)
+		if err != nil {
+			return err
+		}
+		for _, hash := range hashes {
+			keys = append(keys, apiKeyCacheKey(hash))
+		}
+	}
+
 	query := `DELETE FROM agents WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	return err
+	if _, err := r.db.Exec(query, id); err != nil {
+		return err
+	}
+
+	r.cache.invalidate(keys...)
+	return nil
+}
+
+// apiKeyHashes returns the key hashes of the agent's API keys
+func (r *AgentRepository) apiKeyHashes(agentID uuid.UUID) ([]string, error) {
+	rows, err := r.db.Query(`SELECT key_hash FROM api_keys WHERE agent_id = $1`, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, rows.Err()
 }
 
 // List lists all agents with pagination
@@ -384,25 +500,71 @@ func (r *AgentRepository) List(limit, offset int) ([]*domain.Agent, error) {
 }
 
 // UpdateTrustScore updates an agent's trust score
+// Frozen trust scores (see compromise responses) are left unchanged
 func (r *AgentRepository) UpdateTrustScore(id uuid.UUID, newScore float64) error {
 	query := `
 		UPDATE agents
 		SET trust_score = $1, updated_at = $2
-		WHERE id = $3
+		WHERE id = $3 AND trust_score_frozen_at IS NULL
 	`
-	_, err := r.db.Exec(query, newScore, time.Now(), id)
-	return err
+	if _, err := r.db.Exec(query, newScore, time.Now(), id); err != nil {
+		return err
+	}
+
+	r.cache.invalidate(agentCacheKey(id))
+	return nil
 }
 
-// MarkAsCompromised marks an agent as potentially compromised by setting status to suspended
+// FreezeTrustScore stops automatic trust score updates for the agent (keeps the first freeze time)
+func (r *AgentRepository) FreezeTrustScore(id uuid.UUID) error {
+	query := `
+		UPDATE agents
+		SET trust_score_frozen_at = COALESCE(trust_score_frozen_at, $1), updated_at = $1
+		WHERE id = $2
+	`
+	if _, err := r.db.Exec(query, time.Now().UTC(), id); err != nil {
+		return err
+	}
+
+	r.cache.invalidate(agentCacheKey(id))
+	return nil
+}
+
+// AddTalksTo appends the MCP server to the agent's talks_to unless it is already listed
+func (r *AgentRepository) AddTalksTo(id uuid.UUID, mcpServer string) (bool, error) {
+	query := `
+		UPDATE agents
+		SET talks_to = COALESCE(talks_to, '[]'::jsonb) || jsonb_build_array($2::text), updated_at = NOW()
+		WHERE id = $1 AND NOT COALESCE(talks_to, '[]'::jsonb) @> jsonb_build_array($2::text)
+	`
+	result, err := r.db.Exec(query, id, mcpServer)
+	if err != nil {
+		return false, err
+	}
+	added, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if added > 0 {
+		r.cache.invalidate(agentCacheKey(id))
+	}
+	return added > 0, nil
+}
+
+// MarkAsCompromised flags an agent as compromised and suspends it
 func (r *AgentRepository) MarkAsCompromised(id uuid.UUID) error {
 	query := `
 		UPDATE agents
-		SET status = $1, updated_at = $2
+		SET status = $1, is_compromised = true, updated_at = $2
 		WHERE id = $3
 	`
-	_, err := r.db.Exec(query, domain.AgentStatusSuspended, time.Now(), id)
-	return err
+	if _, err := r.db.Exec(query, domain.AgentStatusSuspended, time.Now(), id); err != nil {
+		return err
+	}
+
+	r.cache.invalidate(agentCacheKey(id))
+	return nil
 }
 
 // GetByMCPServer retrieves all agents that talk to a specific MCP server
@@ -575,7 +737,7 @@ func (r *AgentRepository) GetByName(orgID uuid.UUID, name string) (*domain.Agent
 		SELECT id, organization_id, name, display_name, description, agent_type, status, version,
 		       public_key, certificate_url, repository_url, documentation_url, trust_score, verified_at,
 		       created_at, updated_at, created_by, encrypted_private_key, key_algorithm,
-		       key_created_at, key_expires_at, key_rotation_grace_until, previous_public_key, rotation_count,
+		       key_created_at, key_expires_at, key_rotation_grace_until, previous_public_key, previous_key_algorithm, rotation_count,
 		       talks_to, capabilities
 		FROM agents
 		WHERE organization_id = $1 AND name = $2
@@ -595,6 +757,7 @@ func (r *AgentRepository) GetByName(orgID uuid.UUID, name string) (*domain.Agent
 	var keyExpiresAt sql.NullTime
 	var keyRotationGraceUntil sql.NullTime
 	var previousPublicKey sql.NullString
+	var previousKeyAlgorithm sql.NullString
 	var rotationCount sql.NullInt32
 	var talksToJSON []byte
 	var capabilitiesJSON []byte
@@ -623,6 +786,7 @@ func (r *AgentRepository) GetByName(orgID uuid.UUID, name string) (*domain.Agent
 		&keyExpiresAt,
 		&keyRotationGraceUntil,
 		&previousPublicKey,
+		&previousKeyAlgorithm,
 		&rotationCount,
 		&talksToJSON,
 		&capabilitiesJSON,
@@ -672,6 +836,7 @@ func (r *AgentRepository) GetByName(orgID uuid.UUID, name string) (*domain.Agent
 	if previousPublicKey.Valid {
 		agent.PreviousPublicKey = &previousPublicKey.String
 	}
+	agent.PreviousKeyAlgorithm = previousKeyAlgorithm.String
 	if rotationCount.Valid {
 		agent.RotationCount = int(rotationCount.Int32)
 	}
@@ -692,7 +857,80 @@ func (r *AgentRepository) GetByName(orgID uuid.UUID, name string) (*domain.Agent
 }
 
 
-// UpdateLastActive updates the last_active timestamp for an agent
+// RotateKey stores new key material; previous_public_key is taken from the stored row so that
+// concurrent rotations cannot leave a stale previous key behind
+func (r *AgentRepository) RotateKey(agent *domain.Agent) error {
+	query := `
+		UPDATE agents
+		SET previous_public_key = public_key, previous_key_algorithm = key_algorithm,
+		    public_key = $1, encrypted_private_key = $2, key_algorithm = $3,
+		    key_created_at = $4, key_expires_at = $5, key_rotation_grace_until = $6,
+		    rotation_count = COALESCE(rotation_count, 0) + 1, updated_at = $7
+		WHERE id = $8
+		RETURNING previous_public_key, previous_key_algorithm, rotation_count
+	`
+
+	agent.UpdatedAt = time.Now()
+
+	var previousPublicKey, previousKeyAlgorithm sql.NullString
+	err := r.db.QueryRow(query,
+		agent.PublicKey,
+		agent.EncryptedPrivateKey,
+		agent.KeyAlgorithm,
+		agent.KeyCreatedAt,
+		agent.KeyExpiresAt,
+		agent.KeyRotationGraceUntil,
+		agent.UpdatedAt,
+		agent.ID,
+	).Scan(&previousPublicKey, &previousKeyAlgorithm, &agent.RotationCount)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("agent not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to rotate agent key: %w", err)
+	}
+
+	agent.PreviousPublicKey = nil
+	if previousPublicKey.Valid {
+		agent.PreviousPublicKey = &previousPublicKey.String
+	}
+	agent.PreviousKeyAlgorithm = previousKeyAlgorithm.String
+
+	r.cache.invalidate(agentCacheKey(agent.ID))
+	return nil
+}
+
+// DropEscrowedPrivateKeys deletes the encrypted private keys of an organization's agents
+func (r *AgentRepository) DropEscrowedPrivateKeys(orgID uuid.UUID) (int, error) {
+	rows, err := r.db.Query(`
+		UPDATE agents
+		SET encrypted_private_key = NULL, updated_at = NOW()
+		WHERE organization_id = $1 AND encrypted_private_key IS NOT NULL
+		RETURNING id
+	`, orgID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to drop escrowed private keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return 0, err
+		}
+		keys = append(keys, agentCacheKey(id))
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	r.cache.invalidate(keys...)
+	return len(keys), nil
+}
+
+// UpdateLastActive updates the last_active timestamp for an agent. It runs on every verification,
+// so it leaves a cached agent in place: the cached LastActive lags until the entry expires.
 func (r *AgentRepository) UpdateLastActive(ctx context.Context, agentID uuid.UUID) error {
 	query := `
 		UPDATE agents
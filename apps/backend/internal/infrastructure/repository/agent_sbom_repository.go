@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// AgentSBOMRepository implements domain.AgentSBOMRepository
+type AgentSBOMRepository struct {
+	db *sql.DB
+}
+
+// NewAgentSBOMRepository creates a new agent SBOM repository
+func NewAgentSBOMRepository(db *sql.DB) *AgentSBOMRepository {
+	return &AgentSBOMRepository{db: db}
+}
+
+// agentSBOMColumns excludes the document, which is only loaded by GetByID
+const agentSBOMColumns = `id, organization_id, agent_id, agent_version, format, spec_version, source_url, document_hash,
+	components, component_count, vulnerabilities, vulnerability_count, critical_count, high_count,
+	scan_status, scan_error, scanned_at, uploaded_by, created_at`
+
+// Create stores an uploaded SBOM with its parsed components
+func (r *AgentSBOMRepository) Create(sbom *domain.AgentSBOM) error {
+	if sbom.ID == uuid.Nil {
+		sbom.ID = uuid.New()
+	}
+	if sbom.CreatedAt.IsZero() {
+		sbom.CreatedAt = time.Now().UTC()
+	}
+	if sbom.ScanStatus == "" {
+		sbom.ScanStatus = domain.SBOMScanStatusPending
+	}
+
+	componentsJSON, err := json.Marshal(nonNilSlice(sbom.Components))
+	if err != nil {
+		return fmt.Errorf("failed to marshal components: %w", err)
+	}
+	vulnerabilitiesJSON, err := json.Marshal(nonNilSlice(sbom.Vulnerabilities))
+	if err != nil {
+		return fmt.Errorf("failed to marshal vulnerabilities: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO agent_sboms (`+agentSBOMColumns+`, document)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+	`,
+		sbom.ID,
+		sbom.OrganizationID,
+		sbom.AgentID,
+		sbom.AgentVersion,
+		sbom.Format,
+		sbom.SpecVersion,
+		sbom.SourceURL,
+		sbom.DocumentHash,
+		componentsJSON,
+		sbom.ComponentCount,
+		vulnerabilitiesJSON,
+		sbom.VulnerabilityCount,
+		sbom.CriticalCount,
+		sbom.HighCount,
+		sbom.ScanStatus,
+		sbom.ScanError,
+		sbom.ScannedAt,
+		sbom.UploadedBy,
+		sbom.CreatedAt,
+		[]byte(sbom.Document),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create agent SBOM: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves an SBOM including its document
+func (r *AgentSBOMRepository) GetByID(id uuid.UUID) (*domain.AgentSBOM, error) {
+	var document []byte
+	sbom, err := scanAgentSBOM(r.db.QueryRow(`
+		SELECT `+agentSBOMColumns+`, document FROM agent_sboms WHERE id = $1
+	`, id), &document)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("sbom not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	sbom.Document = document
+	return sbom, nil
+}
+
+// GetByAgent lists an agent's SBOMs newest first, without their documents
+func (r *AgentSBOMRepository) GetByAgent(agentID uuid.UUID) ([]*domain.AgentSBOM, error) {
+	return r.query(`
+		SELECT `+agentSBOMColumns+`
+		FROM agent_sboms
+		WHERE agent_id = $1
+		ORDER BY created_at DESC
+	`, agentID)
+}
+
+// GetLatestByAgent returns the agent's most recently uploaded SBOM
+func (r *AgentSBOMRepository) GetLatestByAgent(agentID uuid.UUID) (*domain.AgentSBOM, error) {
+	sbom, err := scanAgentSBOM(r.db.QueryRow(`
+		SELECT `+agentSBOMColumns+`
+		FROM agent_sboms
+		WHERE agent_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, agentID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("sbom not found")
+	}
+	return sbom, err
+}
+
+// UpdateScan stores the scan status, error, vulnerabilities and counts of an SBOM
+func (r *AgentSBOMRepository) UpdateScan(sbom *domain.AgentSBOM) error {
+	vulnerabilitiesJSON, err := json.Marshal(nonNilSlice(sbom.Vulnerabilities))
+	if err != nil {
+		return fmt.Errorf("failed to marshal vulnerabilities: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		UPDATE agent_sboms
+		SET vulnerabilities = $1, vulnerability_count = $2, critical_count = $3, high_count = $4,
+			scan_status = $5, scan_error = $6, scanned_at = $7
+		WHERE id = $8
+	`,
+		vulnerabilitiesJSON,
+		sbom.VulnerabilityCount,
+		sbom.CriticalCount,
+		sbom.HighCount,
+		sbom.ScanStatus,
+		sbom.ScanError,
+		sbom.ScannedAt,
+		sbom.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update agent SBOM scan: %w", err)
+	}
+	return nil
+}
+
+// GetDueForRescan returns the latest SBOM of each agent that was never scanned or last scanned before the cutoff
+func (r *AgentSBOMRepository) GetDueForRescan(scannedBefore time.Time, limit int) ([]*domain.AgentSBOM, error) {
+	return r.query(`
+		SELECT `+agentSBOMColumns+`
+		FROM (
+			SELECT DISTINCT ON (agent_id) *
+			FROM agent_sboms
+			ORDER BY agent_id, created_at DESC
+		) latest
+		WHERE scanned_at IS NULL OR scanned_at < $1
+		ORDER BY scanned_at ASC NULLS FIRST
+		LIMIT $2
+	`, scannedBefore, limit)
+}
+
+func (r *AgentSBOMRepository) query(query string, args ...interface{}) ([]*domain.AgentSBOM, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sboms []*domain.AgentSBOM
+	for rows.Next() {
+		sbom, err := scanAgentSBOM(rows)
+		if err != nil {
+			return nil, err
+		}
+		sboms = append(sboms, sbom)
+	}
+	return sboms, rows.Err()
+}
+
+// scanAgentSBOM scans agentSBOMColumns followed by any extra destinations
+func scanAgentSBOM(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*domain.AgentSBOM, error) {
+	sbom := &domain.AgentSBOM{}
+	var componentsJSON, vulnerabilitiesJSON []byte
+	var sourceURL, scanError sql.NullString
+	var scannedAt sql.NullTime
+	var uploadedBy uuid.NullUUID
+
+	dest := []interface{}{
+		&sbom.ID,
+		&sbom.OrganizationID,
+		&sbom.AgentID,
+		&sbom.AgentVersion,
+		&sbom.Format,
+		&sbom.SpecVersion,
+		&sourceURL,
+		&sbom.DocumentHash,
+		&componentsJSON,
+		&sbom.ComponentCount,
+		&vulnerabilitiesJSON,
+		&sbom.VulnerabilityCount,
+		&sbom.CriticalCount,
+		&sbom.HighCount,
+		&sbom.ScanStatus,
+		&scanError,
+		&scannedAt,
+		&uploadedBy,
+		&sbom.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(componentsJSON, &sbom.Components); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal components: %w", err)
+	}
+	if err := json.Unmarshal(vulnerabilitiesJSON, &sbom.Vulnerabilities); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal vulnerabilities: %w", err)
+	}
+	if sourceURL.Valid {
+		sbom.SourceURL = &sourceURL.String
+	}
+	if scanError.Valid {
+		sbom.ScanError = &scanError.String
+	}
+	if scannedAt.Valid {
+		sbom.ScannedAt = &scannedAt.Time
+	}
+	if uploadedBy.Valid {
+		sbom.UploadedBy = &uploadedBy.UUID
+	}
+
+	return sbom, nil
+}
+
+// nonNilSlice marshals nil slices as [] to satisfy the NOT NULL JSONB columns
+func nonNilSlice[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// AgentTimelineRepository implements domain.AgentTimelineRepository
+type AgentTimelineRepository struct {
+	db *sql.DB
+}
+
+// NewAgentTimelineRepository creates a new agent timeline repository
+func NewAgentTimelineRepository(db *sql.DB) *AgentTimelineRepository {
+	return &AgentTimelineRepository{db: db}
+}
+
+// agentTimelineSources selects the entries of each type as (type, source_id, occurred_at, details)
+// for the agent in $1. Timestamps without a time zone are stored in UTC.
+var agentTimelineSources = map[domain.AgentTimelineEntryType]string{
+	domain.TimelineVerification: `
+		SELECT 'verification', id, started_at, jsonb_build_object(
+			'status', status, 'result', result, 'protocol', protocol, 'verificationType', verification_type,
+			'action', action, 'resourceType', resource_type, 'errorReason', error_reason,
+			'driftDetected', COALESCE(drift_detected, false))
+		FROM verification_events WHERE agent_id = $1`,
+	domain.TimelineDrift: `
+		SELECT 'drift', id, started_at, jsonb_build_object(
+			'status', status,
+			'mcpServerDrift', COALESCE(mcp_server_drift, '[]'::jsonb),
+			'mcpToolDrift', COALESCE(mcp_tool_drift, '[]'::jsonb),
+			'capabilityDrift', COALESCE(capability_drift, '[]'::jsonb))
+		FROM verification_events WHERE agent_id = $1 AND drift_detected = true`,
+	domain.TimelineAttestation: `
+		SELECT 'attestation', a.id, COALESCE(a.verified_at, a.created_at), jsonb_build_object(
+			'mcpServerId', a.mcp_server_id, 'mcpServerName', m.name, 'mcpUrl', a.attestation_data->>'mcp_url',
+			'capabilitiesFound', COALESCE(a.attestation_data->'capabilities_found', '[]'::jsonb),
+			'isValid', a.is_valid, 'expiresAt', a.expires_at)
+		FROM mcp_attestations a LEFT JOIN mcp_servers m ON m.id = a.mcp_server_id
+		WHERE a.agent_id = $1`,
+	domain.TimelineTrustChange: `
+		SELECT 'trust_change', id, recorded_at, jsonb_build_object(
+			'trustScore', trust_score, 'previousScore', previous_score, 'reason', change_reason)
+		FROM trust_score_history WHERE agent_id = $1`,
+	domain.TimelineKeyRotation: `
+		SELECT 'key_rotation', id, "timestamp" AT TIME ZONE 'UTC', jsonb_build_object(
+			'action', metadata->>'action', 'userId', user_id, 'rotationCount', metadata->'rotationCount',
+			'keyRotationGraceUntil', metadata->'keyRotationGraceUntil')
+		FROM audit_logs
+		WHERE resource_type = 'agent' AND resource_id = $1
+			AND metadata->>'action' IN ('rotate_key', 'rotate_credentials')`,
+	domain.TimelineCapabilityChange: `
+		SELECT 'capability_change', id, granted_at AT TIME ZONE 'UTC', jsonb_build_object(
+			'change', 'granted', 'capabilityType', capability_type, 'grantedBy', granted_by)
+		FROM agent_capabilities WHERE agent_id = $1
+		UNION ALL
+		SELECT 'capability_change', id, revoked_at AT TIME ZONE 'UTC', jsonb_build_object(
+			'change', 'revoked', 'capabilityType', capability_type)
+		FROM agent_capabilities WHERE agent_id = $1 AND revoked_at IS NOT NULL
+		UNION ALL
+		SELECT 'capability_change', id, deprecated_at, jsonb_build_object(
+			'change', 'deprecated', 'capabilityType', capability_type,
+			'mcpServerId', mcp_server_id, 'toolName', tool_name, 'resolvedAt', resolved_at)
+		FROM capability_deprecations WHERE agent_id = $1`,
+	domain.TimelineAlert: `
+		SELECT 'alert', id, created_at AT TIME ZONE 'UTC', jsonb_build_object(
+			'alertType', alert_type, 'severity', severity, 'title', title, 'description', description,
+			'isAcknowledged', is_acknowledged)
+		FROM alerts WHERE resource_id = $1`,
+}
+
+// GetTimeline merges the requested sources in one query, so the page is ordered and counted
+// across every type rather than per source
+func (r *AgentTimelineRepository) GetTimeline(agentID uuid.UUID, query domain.AgentTimelineQuery) ([]*domain.AgentTimelineEntry, int, error) {
+	types := query.Types
+	if len(types) == 0 {
+		types = domain.AgentTimelineEntryTypes
+	}
+
+	sources := make([]string, 0, len(types))
+	for _, entryType := range types {
+		source, ok := agentTimelineSources[entryType]
+		if !ok {
+			return nil, 0, fmt.Errorf("unknown timeline entry type %q", entryType)
+		}
+		sources = append(sources, source)
+	}
+
+	args := []interface{}{agentID}
+	var conditions []string
+	if query.Start != nil {
+		args = append(args, *query.Start)
+		conditions = append(conditions, fmt.Sprintf("occurred_at >= $%d", len(args)))
+	}
+	if query.End != nil {
+		args = append(args, *query.End)
+		conditions = append(conditions, fmt.Sprintf("occurred_at < $%d", len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	timeline := fmt.Sprintf(`
+		FROM (%s) AS timeline (type, source_id, occurred_at, details)
+		%s`, strings.Join(sources, "\n\t\tUNION ALL"), where)
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) "+timeline, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count agent timeline: %w", err)
+	}
+
+	args = append(args, query.Limit, query.Offset)
+	rows, err := r.db.Query(fmt.Sprintf(`
+		SELECT type, source_id, occurred_at, details %s
+		ORDER BY occurred_at DESC, type, source_id
+		LIMIT $%d OFFSET $%d`, timeline, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query agent timeline: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.AgentTimelineEntry, 0, query.Limit)
+	for rows.Next() {
+		entry := &domain.AgentTimelineEntry{}
+		var details []byte
+		if err := rows.Scan(&entry.Type, &entry.SourceID, &entry.OccurredAt, &details); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan agent timeline entry: %w", err)
+		}
+		if err := json.Unmarshal(details, &entry.Details); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode agent timeline entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, total, rows.Err()
+}
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// AlertSuppressionRepository implements domain.AlertSuppressionRepository
+type AlertSuppressionRepository struct {
+	db *sql.DB
+}
+
+// NewAlertSuppressionRepository creates a new alert suppression repository
+func NewAlertSuppressionRepository(db *sql.DB) *AlertSuppressionRepository {
+	return &AlertSuppressionRepository{db: db}
+}
+
+const alertSuppressionRuleColumns = `id, organization_id, name, description, enabled, alert_types, resource_type, resource_id, tag_id,
+	window_start, window_end, repeat_threshold, repeat_window_minutes, action, downgrade_to,
+	hit_count, last_hit_at, expires_at, created_by, created_at, updated_at`
+
+// Create creates a new suppression rule
+func (r *AlertSuppressionRepository) Create(rule *domain.AlertSuppressionRule) error {
+	query := `
+		INSERT INTO alert_suppression_rules (` + alertSuppressionRuleColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+	`
+
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	_, err := r.db.Exec(query,
+		rule.ID,
+		rule.OrganizationID,
+		rule.Name,
+		rule.Description,
+		rule.Enabled,
+		pq.Array(alertTypeStrings(rule.AlertTypes)),
+		rule.ResourceType,
+		rule.ResourceID,
+		rule.TagID,
+		rule.WindowStart,
+		rule.WindowEnd,
+		rule.RepeatThreshold,
+		rule.RepeatWindowMinutes,
+		rule.Action,
+		rule.DowngradeTo,
+		rule.HitCount,
+		rule.LastHitAt,
+		rule.ExpiresAt,
+		rule.CreatedBy,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	)
+	return err
+}
+
+// GetByID retrieves a suppression rule by ID
+func (r *AlertSuppressionRepository) GetByID(id uuid.UUID) (*domain.AlertSuppressionRule, error) {
+	query := `SELECT ` + alertSuppressionRuleColumns + ` FROM alert_suppression_rules WHERE id = $1`
+
+	rule, err := scanAlertSuppressionRule(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("alert suppression rule not found")
+	}
+	return rule, err
+}
+
+// GetByOrganization retrieves all suppression rules of an organization, newest first
+func (r *AlertSuppressionRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.AlertSuppressionRule, error) {
+	query := `
+		SELECT ` + alertSuppressionRuleColumns + `
+		FROM alert_suppression_rules
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+	`
+	return r.queryRules(query, orgID)
+}
+
+// GetActiveByOrganization retrieves enabled, unexpired suppression rules, oldest first
+func (r *AlertSuppressionRepository) GetActiveByOrganization(orgID uuid.UUID, at time.Time) ([]*domain.AlertSuppressionRule, error) {
+	query := `
+		SELECT ` + alertSuppressionRuleColumns + `
+		FROM alert_suppression_rules
+		WHERE organization_id = $1 AND enabled = true AND expires_at > $2
+		ORDER BY created_at ASC
+	`
+	return r.queryRules(query, orgID, at)
+}
+
+func (r *AlertSuppressionRepository) queryRules(query string, args ...interface{}) ([]*domain.AlertSuppressionRule, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*domain.AlertSuppressionRule
+	for rows.Next() {
+		rule, err := scanAlertSuppressionRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// Update updates a suppression rule's configuration; hit counters are left untouched
+func (r *AlertSuppressionRepository) Update(rule *domain.AlertSuppressionRule) error {
+	query := `
+		UPDATE alert_suppression_rules
+		SET name = $1, description = $2, enabled = $3, alert_types = $4, resource_type = $5,
+			resource_id = $6, tag_id = $7, window_start = $8, window_end = $9, repeat_threshold = $10,
+			repeat_window_minutes = $11, action = $12, downgrade_to = $13, expires_at = $14, updated_at = $15
+		WHERE id = $16
+	`
+
+	rule.UpdatedAt = time.Now().UTC()
+
+	_, err := r.db.Exec(query,
+		rule.Name,
+		rule.Description,
+		rule.Enabled,
+		pq.Array(alertTypeStrings(rule.AlertTypes)),
+		rule.ResourceType,
+		rule.ResourceID,
+		rule.TagID,
+		rule.WindowStart,
+		rule.WindowEnd,
+		rule.RepeatThreshold,
+		rule.RepeatWindowMinutes,
+		rule.Action,
+		rule.DowngradeTo,
+		rule.ExpiresAt,
+		rule.UpdatedAt,
+		rule.ID,
+	)
+	return err
+}
+
+// Delete deletes a suppression rule and its hits
+func (r *AlertSuppressionRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM alert_suppression_rules WHERE id = $1`, id)
+	return err
+}
+
+// RecordHit stores the hit and bumps the rule's hit counter in one transaction
+func (r *AlertSuppressionRepository) RecordHit(hit *domain.AlertSuppressionHit) error {
+	if hit.ID == uuid.Nil {
+		hit.ID = uuid.New()
+	}
+	if hit.CreatedAt.IsZero() {
+		hit.CreatedAt = time.Now().UTC()
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO alert_suppression_hits (id, rule_id, organization_id, alert_id, action, original_severity, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, hit.ID, hit.RuleID, hit.OrganizationID, hit.AlertID, hit.Action, hit.OriginalSeverity, hit.CreatedAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE alert_suppression_rules SET hit_count = hit_count + 1, last_hit_at = $1 WHERE id = $2
+	`, hit.CreatedAt, hit.RuleID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetHits retrieves a page of a rule's hits, newest first
+func (r *AlertSuppressionRepository) GetHits(ruleID uuid.UUID, limit, offset int) ([]*domain.AlertSuppressionHit, error) {
+	rows, err := r.db.Query(`
+		SELECT id, rule_id, organization_id, alert_id, action, original_severity, created_at
+		FROM alert_suppression_hits
+		WHERE rule_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, ruleID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []*domain.AlertSuppressionHit
+	for rows.Next() {
+		hit := &domain.AlertSuppressionHit{}
+		if err := rows.Scan(&hit.ID, &hit.RuleID, &hit.OrganizationID, &hit.AlertID, &hit.Action, &hit.OriginalSeverity, &hit.CreatedAt); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+// CountMatchingAlerts counts the organization's alerts sharing the alert's fingerprint since the given time
+func (r *AlertSuppressionRepository) CountMatchingAlerts(alert *domain.Alert, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM alerts
+		WHERE organization_id = $1 AND alert_type = $2 AND resource_type = $3 AND resource_id = $4
+			AND title = $5 AND created_at >= $6
+	`, alert.OrganizationID, alert.AlertType, alert.ResourceType, alert.ResourceID, alert.Title, since).Scan(&count)
+	return count, err
+}
+
+func scanAlertSuppressionRule(row interface{ Scan(...interface{}) error }) (*domain.AlertSuppressionRule, error) {
+	rule := &domain.AlertSuppressionRule{}
+	var alertTypes []string
+	var lastHitAt sql.NullTime
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.OrganizationID,
+		&rule.Name,
+		&rule.Description,
+		&rule.Enabled,
+		pq.Array(&alertTypes),
+		&rule.ResourceType,
+		&rule.ResourceID,
+		&rule.TagID,
+		&rule.WindowStart,
+		&rule.WindowEnd,
+		&rule.RepeatThreshold,
+		&rule.RepeatWindowMinutes,
+		&rule.Action,
+		&rule.DowngradeTo,
+		&rule.HitCount,
+		&lastHitAt,
+		&rule.ExpiresAt,
+		&createdBy,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.AlertTypes = make([]domain.AlertType, 0, len(alertTypes))
+	for _, alertType := range alertTypes {
+		rule.AlertTypes = append(rule.AlertTypes, domain.AlertType(alertType))
+	}
+	if lastHitAt.Valid {
+		rule.LastHitAt = &lastHitAt.Time
+	}
+	rule.CreatedBy = createdBy.UUID
+
+	return rule, nil
+}
+
+func alertTypeStrings(alertTypes []domain.AlertType) []string {
+	values := make([]string, 0, len(alertTypes))
+	for _, alertType := range alertTypes {
+		values = append(values, string(alertType))
+	}
+	return values
+}
@@ -6,21 +6,34 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
 type APIKeyRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	cache *recordCache
 }
 
 func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
 	return &APIKeyRepository{db: db}
 }
 
+// UseCache caches GetByHash lookups of active keys for ttl; a ttl of zero turns caching off
+func (r *APIKeyRepository) UseCache(cache domain.IdentityCache, ttl time.Duration) {
+	r.cache = newRecordCache(cache, ttl)
+}
+
+// SetCacheTTL changes the TTL of UseCache while the repository is in use; a ttl of zero turns
+// caching off
+func (r *APIKeyRepository) SetCacheTTL(ttl time.Duration) {
+	r.cache.setTTL(ttl)
+}
+
 func (r *APIKeyRepository) Create(key *domain.APIKey) error {
 	query := `
-		INSERT INTO api_keys (id, organization_id, agent_id, name, key_hash, prefix, expires_at, is_active, created_at, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO api_keys (id, organization_id, agent_id, name, key_hash, prefix, expires_at, is_active, created_at, created_by, scopes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	if key.ID == uuid.Nil {
@@ -29,6 +42,9 @@ func (r *APIKeyRepository) Create(key *domain.APIKey) error {
 	if key.CreatedAt.IsZero() {
 		key.CreatedAt = time.Now()
 	}
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
 
 	_, err := r.db.Exec(query,
 		key.ID,
@@ -41,13 +57,14 @@ func (r *APIKeyRepository) Create(key *domain.APIKey) error {
 		key.IsActive,
 		key.CreatedAt,
 		key.CreatedBy,
+		pq.Array(key.Scopes),
 	)
 	return err
 }
 
 func (r *APIKeyRepository) GetByID(id uuid.UUID) (*domain.APIKey, error) {
 	query := `
-		SELECT id, organization_id, agent_id, name, key_hash, prefix, last_used_at, expires_at, is_active, created_at, created_by
+		SELECT id, organization_id, agent_id, name, key_hash, prefix, last_used_at, expires_at, is_active, created_at, created_by, scopes
 		FROM api_keys
 		WHERE id = $1
 	`
@@ -65,6 +82,7 @@ func (r *APIKeyRepository) GetByID(id uuid.UUID) (*domain.APIKey, error) {
 		&key.IsActive,
 		&key.CreatedAt,
 		&key.CreatedBy,
+		pq.Array(&key.Scopes),
 	)
 
 	if err == sql.ErrNoRows {
@@ -74,8 +92,13 @@ func (r *APIKeyRepository) GetByID(id uuid.UUID) (*domain.APIKey, error) {
 }
 
 func (r *APIKeyRepository) GetByHash(hash string) (*domain.APIKey, error) {
+	cached := &domain.APIKey{}
+	if r.cache.get(apiKeyCacheKey(hash), cached) {
+		return cached, nil
+	}
+
 	query := `
-		SELECT id, organization_id, agent_id, name, key_hash, prefix, last_used_at, expires_at, is_active, created_at, created_by
+		SELECT id, organization_id, agent_id, name, key_hash, prefix, last_used_at, expires_at, is_active, created_at, created_by, scopes
 		FROM api_keys
 		WHERE key_hash = $1 AND is_active = true
 	`
@@ -93,17 +116,23 @@ func (r *APIKeyRepository) GetByHash(hash string) (*domain.APIKey, error) {
 		&key.IsActive,
 		&key.CreatedAt,
 		&key.CreatedBy,
+		pq.Array(&key.Scopes),
 	)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	return key, err
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.set(apiKeyCacheKey(hash), key)
+	return key, nil
 }
 
 func (r *APIKeyRepository) GetByAgent(agentID uuid.UUID) ([]*domain.APIKey, error) {
 	query := `
-		SELECT id, organization_id, agent_id, name, key_hash, prefix, last_used_at, expires_at, is_active, created_at, created_by
+		SELECT id, organization_id, agent_id, name, key_hash, prefix, last_used_at, expires_at, is_active, created_at, created_by, scopes
 		FROM api_keys
 		WHERE agent_id = $1
 		ORDER BY created_at DESC
@@ -130,6 +159,7 @@ func (r *APIKeyRepository) GetByAgent(agentID uuid.UUID) ([]*domain.APIKey, erro
 			&key.IsActive,
 			&key.CreatedAt,
 			&key.CreatedBy,
+			pq.Array(&key.Scopes),
 		)
 		if err != nil {
 			return nil, err
@@ -143,7 +173,7 @@ func (r *APIKeyRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.APIKey,
 	query := `
 		SELECT
 			k.id, k.organization_id, k.agent_id, k.name, k.key_hash, k.prefix,
-			k.last_used_at, k.expires_at, k.is_active, k.created_at, k.created_by,
+			k.last_used_at, k.expires_at, k.is_active, k.created_at, k.created_by, k.scopes,
 			a.name as agent_name
 		FROM api_keys k
 		LEFT JOIN agents a ON k.agent_id = a.id
@@ -173,6 +203,7 @@ func (r *APIKeyRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.APIKey,
 			&key.IsActive,
 			&key.CreatedAt,
 			&key.CreatedBy,
+			pq.Array(&key.Scopes),
 			&agentName,
 		)
 		if err != nil {
@@ -187,19 +218,41 @@ func (r *APIKeyRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.APIKey,
 }
 
 func (r *APIKeyRepository) Revoke(id uuid.UUID) error {
-	query := `UPDATE api_keys SET is_active = false WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	return err
+	query := `UPDATE api_keys SET is_active = false WHERE id = $1 RETURNING key_hash`
+	return r.execAndInvalidate(query, id)
 }
 
 func (r *APIKeyRepository) Delete(id uuid.UUID) error {
-	query := `DELETE FROM api_keys WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	return err
+	query := `DELETE FROM api_keys WHERE id = $1 RETURNING key_hash`
+	return r.execAndInvalidate(query, id)
 }
 
+// UpdateLastUsed runs on every authentication, so it leaves a cached key in place: the cached
+// LastUsedAt lags until the entry expires
 func (r *APIKeyRepository) UpdateLastUsed(id uuid.UUID) error {
 	query := `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`
 	_, err := r.db.Exec(query, id)
 	return err
 }
+
+// UpdateExpiry changes when the key expires; nil means it never expires
+func (r *APIKeyRepository) UpdateExpiry(id uuid.UUID, expiresAt *time.Time) error {
+	query := `UPDATE api_keys SET expires_at = $2 WHERE id = $1 RETURNING key_hash`
+	return r.execAndInvalidate(query, id, expiresAt)
+}
+
+// execAndInvalidate runs a write on the key with the given ID that returns its key_hash, and
+// drops the cached lookup of that hash. Cached keys are looked up by hash, not by ID.
+func (r *APIKeyRepository) execAndInvalidate(query string, id uuid.UUID, args ...interface{}) error {
+	var hash string
+	err := r.db.QueryRow(query, append([]interface{}{id}, args...)...).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	r.cache.invalidate(apiKeyCacheKey(hash))
+	return nil
+}
@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ApprovalChainRepository implements domain.ApprovalChainRepository
+type ApprovalChainRepository struct {
+	db *sql.DB
+}
+
+// NewApprovalChainRepository creates a new approval chain repository
+func NewApprovalChainRepository(db *sql.DB) *ApprovalChainRepository {
+	return &ApprovalChainRepository{db: db}
+}
+
+const approvalChainColumns = `id, organization_id, name, action, steps, min_risk_severity, agent_tags,
+	is_enabled, created_by, created_at, updated_at`
+
+// Create stores a new approval chain
+func (r *ApprovalChainRepository) Create(chain *domain.ApprovalChain) error {
+	if chain.ID == uuid.Nil {
+		chain.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	chain.CreatedAt = now
+	chain.UpdatedAt = now
+
+	stepsJSON, err := json.Marshal(nonNilSlice(chain.Steps))
+	if err != nil {
+		return fmt.Errorf("failed to marshal steps: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO approval_chains (`+approvalChainColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		chain.ID,
+		chain.OrganizationID,
+		chain.Name,
+		chain.Action,
+		stepsJSON,
+		chain.MinRiskSeverity,
+		pq.Array(nonNilSlice(chain.AgentTags)),
+		chain.IsEnabled,
+		chain.CreatedBy,
+		chain.CreatedAt,
+		chain.UpdatedAt,
+	)
+	return err
+}
+
+// GetByID retrieves an approval chain by ID
+func (r *ApprovalChainRepository) GetByID(id uuid.UUID) (*domain.ApprovalChain, error) {
+	chain, err := scanApprovalChain(r.db.QueryRow(`SELECT `+approvalChainColumns+` FROM approval_chains WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("approval chain not found")
+	}
+	return chain, err
+}
+
+// GetByOrganization lists an organization's approval chains, oldest first
+func (r *ApprovalChainRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.ApprovalChain, error) {
+	return r.list(`
+		SELECT `+approvalChainColumns+`
+		FROM approval_chains
+		WHERE organization_id = $1
+		ORDER BY created_at ASC
+	`, orgID)
+}
+
+// GetEnabledByAction returns the organization's enabled chains guarding an action, oldest first
+func (r *ApprovalChainRepository) GetEnabledByAction(orgID uuid.UUID, action domain.ApprovalAction) ([]*domain.ApprovalChain, error) {
+	return r.list(`
+		SELECT `+approvalChainColumns+`
+		FROM approval_chains
+		WHERE organization_id = $1 AND action = $2 AND is_enabled = TRUE
+		ORDER BY created_at ASC
+	`, orgID, action)
+}
+
+// Update stores the editable fields of an approval chain
+func (r *ApprovalChainRepository) Update(chain *domain.ApprovalChain) error {
+	stepsJSON, err := json.Marshal(nonNilSlice(chain.Steps))
+	if err != nil {
+		return fmt.Errorf("failed to marshal steps: %w", err)
+	}
+	chain.UpdatedAt = time.Now().UTC()
+
+	result, err := r.db.Exec(`
+		UPDATE approval_chains
+		SET name = $1, steps = $2, min_risk_severity = $3, agent_tags = $4, is_enabled = $5, updated_at = $6
+		WHERE id = $7
+	`, chain.Name, stepsJSON, chain.MinRiskSeverity, pq.Array(nonNilSlice(chain.AgentTags)), chain.IsEnabled, chain.UpdatedAt, chain.ID)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("approval chain not found")
+	}
+	return nil
+}
+
+// Delete removes an approval chain; requests it opened keep their copy of its steps
+func (r *ApprovalChainRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM approval_chains WHERE id = $1`, id)
+	return err
+}
+
+func (r *ApprovalChainRepository) list(query string, args ...interface{}) ([]*domain.ApprovalChain, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chains []*domain.ApprovalChain
+	for rows.Next() {
+		chain, err := scanApprovalChain(rows)
+		if err != nil {
+			return nil, err
+		}
+		chains = append(chains, chain)
+	}
+	return chains, rows.Err()
+}
+
+func scanApprovalChain(row interface{ Scan(...interface{}) error }) (*domain.ApprovalChain, error) {
+	chain := &domain.ApprovalChain{}
+	var stepsJSON []byte
+
+	err := row.Scan(
+		&chain.ID,
+		&chain.OrganizationID,
+		&chain.Name,
+		&chain.Action,
+		&stepsJSON,
+		&chain.MinRiskSeverity,
+		pq.Array(&chain.AgentTags),
+		&chain.IsEnabled,
+		&chain.CreatedBy,
+		&chain.CreatedAt,
+		&chain.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(stepsJSON, &chain.Steps); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal steps: %w", err)
+	}
+	if chain.AgentTags == nil {
+		chain.AgentTags = []string{}
+	}
+	return chain, nil
+}
+
+// ApprovalRequestRepository implements domain.ApprovalRequestRepository
+type ApprovalRequestRepository struct {
+	db *sql.DB
+}
+
+// NewApprovalRequestRepository creates a new approval request repository
+func NewApprovalRequestRepository(db *sql.DB) *ApprovalRequestRepository {
+	return &ApprovalRequestRepository{db: db}
+}
+
+const approvalRequestColumns = `id, organization_id, chain_id, chain_name, action, resource_type, resource_id,
+	summary, steps, decisions, status, requested_by, created_at, completed_at`
+
+// Create opens an approval request. The partial unique index on pending requests rejects a
+// second pending request for the same action and resource.
+func (r *ApprovalRequestRepository) Create(request *domain.ApprovalRequest) error {
+	if request.ID == uuid.Nil {
+		request.ID = uuid.New()
+	}
+	if request.CreatedAt.IsZero() {
+		request.CreatedAt = time.Now().UTC()
+	}
+
+	stepsJSON, err := json.Marshal(nonNilSlice(request.Steps))
+	if err != nil {
+		return fmt.Errorf("failed to marshal steps: %w", err)
+	}
+	decisionsJSON, err := json.Marshal(nonNilSlice(request.Decisions))
+	if err != nil {
+		return fmt.Errorf("failed to marshal decisions: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO approval_requests (`+approvalRequestColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`,
+		request.ID,
+		request.OrganizationID,
+		request.ChainID,
+		request.ChainName,
+		request.Action,
+		request.ResourceType,
+		request.ResourceID,
+		request.Summary,
+		stepsJSON,
+		decisionsJSON,
+		request.Status,
+		request.RequestedBy,
+		request.CreatedAt,
+		request.CompletedAt,
+	)
+	return err
+}
+
+// GetByID retrieves an approval request by ID
+func (r *ApprovalRequestRepository) GetByID(id uuid.UUID) (*domain.ApprovalRequest, error) {
+	request, err := scanApprovalRequest(r.db.QueryRow(`SELECT `+approvalRequestColumns+` FROM approval_requests WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("approval request not found")
+	}
+	return request, err
+}
+
+// GetPending returns the pending request for an action on a resource
+func (r *ApprovalRequestRepository) GetPending(orgID uuid.UUID, action domain.ApprovalAction, resourceID uuid.UUID) (*domain.ApprovalRequest, error) {
+	request, err := scanApprovalRequest(r.db.QueryRow(`
+		SELECT `+approvalRequestColumns+`
+		FROM approval_requests
+		WHERE organization_id = $1 AND action = $2 AND resource_id = $3 AND status = 'pending'
+	`, orgID, action, resourceID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("approval request not found")
+	}
+	return request, err
+}
+
+// GetByOrganization lists an organization's approval requests newest first; an empty status lists all
+func (r *ApprovalRequestRepository) GetByOrganization(orgID uuid.UUID, status domain.ApprovalRequestStatus, limit, offset int) ([]*domain.ApprovalRequest, error) {
+	rows, err := r.db.Query(`
+		SELECT `+approvalRequestColumns+`
+		FROM approval_requests
+		WHERE organization_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`, orgID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []*domain.ApprovalRequest
+	for rows.Next() {
+		request, err := scanApprovalRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	return requests, rows.Err()
+}
+
+// GetByUser lists the organization's approval requests the user opened or decided, newest first
+func (r *ApprovalRequestRepository) GetByUser(orgID, userID uuid.UUID) ([]*domain.ApprovalRequest, error) {
+	rows, err := r.db.Query(`
+		SELECT `+approvalRequestColumns+`
+		FROM approval_requests
+		WHERE organization_id = $1
+		  AND (requested_by = $2 OR decisions @> jsonb_build_array(jsonb_build_object('userId', $2::text)))
+		ORDER BY created_at DESC
+	`, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []*domain.ApprovalRequest
+	for rows.Next() {
+		request, err := scanApprovalRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	return requests, rows.Err()
+}
+
+// RecordDecision stores the decisions, status and completion time in a single conditional update,
+// so two approvers deciding at once cannot both fill the same step
+func (r *ApprovalRequestRepository) RecordDecision(request *domain.ApprovalRequest, expectedDecisions int) (bool, error) {
+	decisionsJSON, err := json.Marshal(nonNilSlice(request.Decisions))
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal decisions: %w", err)
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE approval_requests
+		SET decisions = $1, status = $2, completed_at = $3
+		WHERE id = $4 AND status = 'pending' AND jsonb_array_length(decisions) = $5
+	`, decisionsJSON, request.Status, request.CompletedAt, request.ID, expectedDecisions)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func scanApprovalRequest(row interface{ Scan(...interface{}) error }) (*domain.ApprovalRequest, error) {
+	request := &domain.ApprovalRequest{}
+	var chainID, requestedBy uuid.NullUUID
+	var stepsJSON, decisionsJSON []byte
+	var completedAt sql.NullTime
+
+	err := row.Scan(
+		&request.ID,
+		&request.OrganizationID,
+		&chainID,
+		&request.ChainName,
+		&request.Action,
+		&request.ResourceType,
+		&request.ResourceID,
+		&request.Summary,
+		&stepsJSON,
+		&decisionsJSON,
+		&request.Status,
+		&requestedBy,
+		&request.CreatedAt,
+		&completedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	request.ChainID = chainID.UUID
+	if requestedBy.Valid {
+		request.RequestedBy = &requestedBy.UUID
+	}
+	if completedAt.Valid {
+		request.CompletedAt = &completedAt.Time
+	}
+	if err := json.Unmarshal(stepsJSON, &request.Steps); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal steps: %w", err)
+	}
+	if err := json.Unmarshal(decisionsJSON, &request.Decisions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal decisions: %w", err)
+	}
+	return request, nil
+}
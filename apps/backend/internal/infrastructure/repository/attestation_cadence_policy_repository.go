@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// AttestationCadencePolicyRepository implements domain.AttestationCadencePolicyRepository
+type AttestationCadencePolicyRepository struct {
+	db *sql.DB
+}
+
+// NewAttestationCadencePolicyRepository creates a new attestation cadence policy repository
+func NewAttestationCadencePolicyRepository(db *sql.DB) *AttestationCadencePolicyRepository {
+	return &AttestationCadencePolicyRepository{db: db}
+}
+
+// ListByOrganization returns the organization's customized cadences
+func (r *AttestationCadencePolicyRepository) ListByOrganization(orgID uuid.UUID) ([]*domain.AttestationCadencePolicy, error) {
+	query := `
+		SELECT organization_id, criticality, max_attestation_age_hours, min_independent_agents, updated_by, updated_at
+		FROM mcp_attestation_cadence_policies
+		WHERE organization_id = $1
+	`
+	rows, err := r.db.Query(query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := make([]*domain.AttestationCadencePolicy, 0)
+	for rows.Next() {
+		policy := &domain.AttestationCadencePolicy{}
+		var updatedBy uuid.NullUUID
+		var updatedAt sql.NullTime
+		if err := rows.Scan(
+			&policy.OrganizationID,
+			&policy.Criticality,
+			&policy.MaxAttestationAgeHours,
+			&policy.MinIndependentAgents,
+			&updatedBy,
+			&updatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if updatedBy.Valid {
+			policy.UpdatedBy = &updatedBy.UUID
+		}
+		if updatedAt.Valid {
+			policy.UpdatedAt = &updatedAt.Time
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
+
+// Upsert stores the cadence of its organization and criticality, replacing the existing one
+func (r *AttestationCadencePolicyRepository) Upsert(policy *domain.AttestationCadencePolicy) error {
+	query := `
+		INSERT INTO mcp_attestation_cadence_policies (
+			organization_id, criticality, max_attestation_age_hours, min_independent_agents, updated_by, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (organization_id, criticality) DO UPDATE SET
+			max_attestation_age_hours = EXCLUDED.max_attestation_age_hours,
+			min_independent_agents = EXCLUDED.min_independent_agents,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.Exec(query,
+		policy.OrganizationID,
+		policy.Criticality,
+		policy.MaxAttestationAgeHours,
+		policy.MinIndependentAgents,
+		policy.UpdatedBy,
+		policy.UpdatedAt,
+	)
+	return err
+}
+
+// Delete removes the organization's cadence of the criticality, restoring the default
+func (r *AttestationCadencePolicyRepository) Delete(orgID uuid.UUID, criticality domain.MCPServerCriticality) error {
+	_, err := r.db.Exec(
+		`DELETE FROM mcp_attestation_cadence_policies WHERE organization_id = $1 AND criticality = $2`,
+		orgID, criticality,
+	)
+	return err
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// AttestationNonceRepository implements domain.AttestationNonceRepository
+type AttestationNonceRepository struct {
+	db *sql.DB
+}
+
+// NewAttestationNonceRepository creates a new attestation nonce repository
+func NewAttestationNonceRepository(db *sql.DB) *AttestationNonceRepository {
+	return &AttestationNonceRepository{db: db}
+}
+
+// Create stores a newly issued nonce
+func (r *AttestationNonceRepository) Create(nonce *domain.AttestationNonce) error {
+	if nonce.ID == uuid.Nil {
+		nonce.ID = uuid.New()
+	}
+	if nonce.CreatedAt.IsZero() {
+		nonce.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO attestation_nonces (id, nonce, agent_id, organization_id, expires_at, consumed_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, nonce.ID, nonce.Nonce, nonce.AgentID, nonce.OrganizationID, nonce.ExpiresAt, nonce.ConsumedAt, nonce.CreatedAt)
+	return err
+}
+
+// GetByNonce retrieves a nonce by its value
+func (r *AttestationNonceRepository) GetByNonce(value string) (*domain.AttestationNonce, error) {
+	nonce := &domain.AttestationNonce{}
+	var consumedAt sql.NullTime
+
+	err := r.db.QueryRow(`
+		SELECT id, nonce, agent_id, organization_id, expires_at, consumed_at, created_at
+		FROM attestation_nonces
+		WHERE nonce = $1
+	`, value).Scan(
+		&nonce.ID,
+		&nonce.Nonce,
+		&nonce.AgentID,
+		&nonce.OrganizationID,
+		&nonce.ExpiresAt,
+		&consumedAt,
+		&nonce.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("attestation nonce not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if consumedAt.Valid {
+		nonce.ConsumedAt = &consumedAt.Time
+	}
+	return nonce, nil
+}
+
+// Consume marks the agent's unexpired, unused nonce as used in a single conditional update
+func (r *AttestationNonceRepository) Consume(value string, agentID uuid.UUID, at time.Time) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE attestation_nonces
+		SET consumed_at = $1
+		WHERE nonce = $2 AND agent_id = $3 AND consumed_at IS NULL AND expires_at > $1
+	`, at, value, agentID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// DeleteExpired removes nonces that expired before the given time, used or not
+func (r *AttestationNonceRepository) DeleteExpired(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM attestation_nonces WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// CapabilityDeprecationRepository implements domain.CapabilityDeprecationRepository
+type CapabilityDeprecationRepository struct {
+	db *sql.DB
+}
+
+// NewCapabilityDeprecationRepository creates a new capability deprecation repository
+func NewCapabilityDeprecationRepository(db *sql.DB) *CapabilityDeprecationRepository {
+	return &CapabilityDeprecationRepository{db: db}
+}
+
+// capabilityDeprecationSelect joins in the agent and MCP server names; callers append the WHERE clause
+const capabilityDeprecationSelect = `
+	SELECT cd.id, cd.organization_id, cd.agent_id, COALESCE(a.display_name, ''), cd.capability_id,
+		cd.capability_type, cd.mcp_server_id, COALESCE(m.name, ''), cd.tool_name, cd.source,
+		cd.deprecated_at, cd.resolved_at
+	FROM capability_deprecations cd
+	LEFT JOIN agents a ON a.id = cd.agent_id
+	LEFT JOIN mcp_servers m ON m.id = cd.mcp_server_id
+`
+
+// Create records a deprecated capability reference
+func (r *CapabilityDeprecationRepository) Create(deprecation *domain.CapabilityDeprecation) error {
+	if deprecation.ID == uuid.Nil {
+		deprecation.ID = uuid.New()
+	}
+	if deprecation.DeprecatedAt.IsZero() {
+		deprecation.DeprecatedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO capability_deprecations (
+			id, organization_id, agent_id, capability_id, capability_type, mcp_server_id,
+			tool_name, source, deprecated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`,
+		deprecation.ID,
+		deprecation.OrganizationID,
+		deprecation.AgentID,
+		deprecation.CapabilityID,
+		deprecation.CapabilityType,
+		deprecation.MCPServerID,
+		deprecation.ToolName,
+		deprecation.Source,
+		deprecation.DeprecatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create capability deprecation: %w", err)
+	}
+	return nil
+}
+
+// GetActiveByServer returns the unresolved deprecations caused by the MCP server, oldest first
+func (r *CapabilityDeprecationRepository) GetActiveByServer(serverID uuid.UUID) ([]*domain.CapabilityDeprecation, error) {
+	return queryCapabilityDeprecations(r.db, capabilityDeprecationSelect+`
+		WHERE cd.mcp_server_id = $1 AND cd.resolved_at IS NULL
+		ORDER BY cd.deprecated_at ASC
+	`, serverID)
+}
+
+// GetActiveByAgent returns the agent's unresolved deprecations, oldest first
+func (r *CapabilityDeprecationRepository) GetActiveByAgent(agentID uuid.UUID) ([]*domain.CapabilityDeprecation, error) {
+	return queryCapabilityDeprecations(r.db, capabilityDeprecationSelect+`
+		WHERE cd.agent_id = $1 AND cd.resolved_at IS NULL
+		ORDER BY cd.deprecated_at ASC
+	`, agentID)
+}
+
+// Resolve closes a deprecation
+func (r *CapabilityDeprecationRepository) Resolve(id uuid.UUID, resolvedAt time.Time) error {
+	result, err := r.db.Exec(`
+		UPDATE capability_deprecations SET resolved_at = $2 WHERE id = $1 AND resolved_at IS NULL
+	`, id, resolvedAt)
+	if err != nil {
+		return fmt.Errorf("failed to resolve capability deprecation: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("capability deprecation not found")
+	}
+	return nil
+}
+
+func queryCapabilityDeprecations(db *sql.DB, query string, args ...interface{}) ([]*domain.CapabilityDeprecation, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query capability deprecations: %w", err)
+	}
+	defer rows.Close()
+
+	deprecations := make([]*domain.CapabilityDeprecation, 0)
+	for rows.Next() {
+		deprecation := &domain.CapabilityDeprecation{}
+		var resolvedAt sql.NullTime
+		if err := rows.Scan(
+			&deprecation.ID,
+			&deprecation.OrganizationID,
+			&deprecation.AgentID,
+			&deprecation.AgentName,
+			&deprecation.CapabilityID,
+			&deprecation.CapabilityType,
+			&deprecation.MCPServerID,
+			&deprecation.MCPServerName,
+			&deprecation.ToolName,
+			&deprecation.Source,
+			&deprecation.DeprecatedAt,
+			&resolvedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan capability deprecation: %w", err)
+		}
+		if resolvedAt.Valid {
+			deprecation.ResolvedAt = &resolvedAt.Time
+		}
+		deprecations = append(deprecations, deprecation)
+	}
+	return deprecations, rows.Err()
+}
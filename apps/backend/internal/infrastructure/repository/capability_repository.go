@@ -26,8 +26,8 @@ func (r *CapabilityRepositoryPostgres) CreateCapability(capability *domain.Agent
 
 	query := `
 		INSERT INTO agent_capabilities (
-			id, agent_id, capability_type, capability_scope, granted_by, granted_at, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			id, agent_id, capability_type, capability_scope, granted_by, granted_at, expires_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
 	capability.ID = uuid.New()
@@ -41,6 +41,7 @@ func (r *CapabilityRepositoryPostgres) CreateCapability(capability *domain.Agent
 		scopeJSON,
 		capability.GrantedBy,
 		capability.GrantedAt,
+		capability.ExpiresAt,
 		capability.CreatedAt,
 		capability.UpdatedAt,
 	)
@@ -51,7 +52,7 @@ func (r *CapabilityRepositoryPostgres) CreateCapability(capability *domain.Agent
 // GetCapabilityByID retrieves a capability by ID
 func (r *CapabilityRepositoryPostgres) GetCapabilityByID(id uuid.UUID) (*domain.AgentCapability, error) {
 	query := `
-		SELECT id, agent_id, capability_type, capability_scope, granted_by, granted_at, revoked_at, created_at, updated_at
+		SELECT id, agent_id, capability_type, capability_scope, granted_by, granted_at, revoked_at, expires_at, created_at, updated_at
 		FROM agent_capabilities
 		WHERE id = $1
 	`
@@ -59,7 +60,7 @@ func (r *CapabilityRepositoryPostgres) GetCapabilityByID(id uuid.UUID) (*domain.
 	var capability domain.AgentCapability
 	var scopeJSON []byte
 	var grantedBy uuid.NullUUID
-	var revokedAt sql.NullTime
+	var revokedAt, expiresAt sql.NullTime
 
 	err := r.db.QueryRow(query, id).Scan(
 		&capability.ID,
@@ -69,6 +70,7 @@ func (r *CapabilityRepositoryPostgres) GetCapabilityByID(id uuid.UUID) (*domain.
 		&grantedBy,
 		&capability.GrantedAt,
 		&revokedAt,
+		&expiresAt,
 		&capability.CreatedAt,
 		&capability.UpdatedAt,
 	)
@@ -83,6 +85,9 @@ func (r *CapabilityRepositoryPostgres) GetCapabilityByID(id uuid.UUID) (*domain.
 	if revokedAt.Valid {
 		capability.RevokedAt = &revokedAt.Time
 	}
+	if expiresAt.Valid {
+		capability.ExpiresAt = &expiresAt.Time
+	}
 	if len(scopeJSON) > 0 {
 		json.Unmarshal(scopeJSON, &capability.CapabilityScope)
 	}
@@ -93,7 +98,7 @@ func (r *CapabilityRepositoryPostgres) GetCapabilityByID(id uuid.UUID) (*domain.
 // GetCapabilitiesByAgentID retrieves all capabilities for an agent
 func (r *CapabilityRepositoryPostgres) GetCapabilitiesByAgentID(agentID uuid.UUID) ([]*domain.AgentCapability, error) {
 	query := `
-		SELECT id, agent_id, capability_type, capability_scope, granted_by, granted_at, revoked_at, created_at, updated_at
+		SELECT id, agent_id, capability_type, capability_scope, granted_by, granted_at, revoked_at, expires_at, created_at, updated_at
 		FROM agent_capabilities
 		WHERE agent_id = $1
 		ORDER BY created_at DESC
@@ -110,7 +115,7 @@ func (r *CapabilityRepositoryPostgres) GetCapabilitiesByAgentID(agentID uuid.UUI
 		var capability domain.AgentCapability
 		var scopeJSON []byte
 		var grantedBy uuid.NullUUID
-		var revokedAt sql.NullTime
+		var revokedAt, expiresAt sql.NullTime
 
 		err := rows.Scan(
 			&capability.ID,
@@ -120,6 +125,7 @@ func (r *CapabilityRepositoryPostgres) GetCapabilitiesByAgentID(agentID uuid.UUI
 			&grantedBy,
 			&capability.GrantedAt,
 			&revokedAt,
+			&expiresAt,
 			&capability.CreatedAt,
 			&capability.UpdatedAt,
 		)
@@ -134,6 +140,9 @@ func (r *CapabilityRepositoryPostgres) GetCapabilitiesByAgentID(agentID uuid.UUI
 		if revokedAt.Valid {
 			capability.RevokedAt = &revokedAt.Time
 		}
+		if expiresAt.Valid {
+			capability.ExpiresAt = &expiresAt.Time
+		}
 		if len(scopeJSON) > 0 {
 			json.Unmarshal(scopeJSON, &capability.CapabilityScope)
 		}
@@ -147,9 +156,9 @@ func (r *CapabilityRepositoryPostgres) GetCapabilitiesByAgentID(agentID uuid.UUI
 // GetActiveCapabilitiesByAgentID retrieves only non-revoked capabilities
 func (r *CapabilityRepositoryPostgres) GetActiveCapabilitiesByAgentID(agentID uuid.UUID) ([]*domain.AgentCapability, error) {
 	query := `
-		SELECT id, agent_id, capability_type, capability_scope, granted_by, granted_at, revoked_at, created_at, updated_at
+		SELECT id, agent_id, capability_type, capability_scope, granted_by, granted_at, revoked_at, expires_at, created_at, updated_at
 		FROM agent_capabilities
-		WHERE agent_id = $1 AND revoked_at IS NULL
+		WHERE agent_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
 		ORDER BY created_at DESC
 	`
 
@@ -164,7 +173,7 @@ func (r *CapabilityRepositoryPostgres) GetActiveCapabilitiesByAgentID(agentID uu
 		var capability domain.AgentCapability
 		var scopeJSON []byte
 		var grantedBy uuid.NullUUID
-		var revokedAt sql.NullTime
+		var revokedAt, expiresAt sql.NullTime
 
 		err := rows.Scan(
 			&capability.ID,
@@ -174,6 +183,7 @@ func (r *CapabilityRepositoryPostgres) GetActiveCapabilitiesByAgentID(agentID uu
 			&grantedBy,
 			&capability.GrantedAt,
 			&revokedAt,
+			&expiresAt,
 			&capability.CreatedAt,
 			&capability.UpdatedAt,
 		)
@@ -188,6 +198,9 @@ func (r *CapabilityRepositoryPostgres) GetActiveCapabilitiesByAgentID(agentID uu
 		if revokedAt.Valid {
 			capability.RevokedAt = &revokedAt.Time
 		}
+		if expiresAt.Valid {
+			capability.ExpiresAt = &expiresAt.Time
+		}
 		if len(scopeJSON) > 0 {
 			json.Unmarshal(scopeJSON, &capability.CapabilityScope)
 		}
@@ -217,6 +230,68 @@ func (r *CapabilityRepositoryPostgres) DeleteCapability(id uuid.UUID) error {
 	return err
 }
 
+// GetExpiredCapabilities retrieves non-revoked capabilities whose expiry is at or before the given time
+func (r *CapabilityRepositoryPostgres) GetExpiredCapabilities(at time.Time) ([]*domain.AgentCapability, error) {
+	query := `
+		SELECT id, agent_id, capability_type, capability_scope, granted_by, granted_at, expires_at, created_at, updated_at
+		FROM agent_capabilities
+		WHERE revoked_at IS NULL AND expires_at IS NOT NULL AND expires_at <= $1
+		ORDER BY agent_id, expires_at
+	`
+
+	rows, err := r.db.Query(query, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var capabilities []*domain.AgentCapability
+	for rows.Next() {
+		var capability domain.AgentCapability
+		var scopeJSON []byte
+		var grantedBy uuid.NullUUID
+		var expiresAt time.Time
+
+		if err := rows.Scan(
+			&capability.ID,
+			&capability.AgentID,
+			&capability.CapabilityType,
+			&scopeJSON,
+			&grantedBy,
+			&capability.GrantedAt,
+			&expiresAt,
+			&capability.CreatedAt,
+			&capability.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if grantedBy.Valid {
+			capability.GrantedBy = &grantedBy.UUID
+		}
+		capability.ExpiresAt = &expiresAt
+		if len(scopeJSON) > 0 {
+			json.Unmarshal(scopeJSON, &capability.CapabilityScope)
+		}
+
+		capabilities = append(capabilities, &capability)
+	}
+
+	return capabilities, rows.Err()
+}
+
+// SetCapabilityExpiry changes when a capability expires; nil makes it permanent
+func (r *CapabilityRepositoryPostgres) SetCapabilityExpiry(id uuid.UUID, expiresAt *time.Time) error {
+	query := `
+		UPDATE agent_capabilities
+		SET expires_at = $1, updated_at = $2
+		WHERE id = $3
+	`
+
+	_, err := r.db.Exec(query, expiresAt, time.Now(), id)
+	return err
+}
+
 // CreateViolation creates a new capability violation record
 func (r *CapabilityRepositoryPostgres) CreateViolation(violation *domain.CapabilityViolation) error {
 	registeredJSON, _ := json.Marshal(violation.RegisteredCapabilities)
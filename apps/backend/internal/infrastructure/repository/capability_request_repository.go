@@ -23,9 +23,10 @@ func (r *capabilityRequestRepository) Create(req *domain.CapabilityRequest) erro
 	query := `
 		INSERT INTO capability_requests (
 			id, agent_id, capability_type, reason, status,
-			requested_by, requested_at, created_at, updated_at
+			requested_by, requested_at, expires_at, renews_capability_id,
+			created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 		)
 	`
 
@@ -45,6 +46,8 @@ func (r *capabilityRequestRepository) Create(req *domain.CapabilityRequest) erro
 		req.Status,
 		req.RequestedBy,
 		req.RequestedAt,
+		req.ExpiresAt,
+		req.RenewsCapabilityID,
 		req.CreatedAt,
 		req.UpdatedAt,
 	)
@@ -64,6 +67,8 @@ func (r *capabilityRequestRepository) GetByID(id uuid.UUID) (*domain.CapabilityR
 			cr.reviewed_by,
 			cr.requested_at,
 			cr.reviewed_at,
+			cr.expires_at,
+			cr.renews_capability_id,
 			cr.created_at,
 			cr.updated_at,
 			a.name AS agent_name,
@@ -101,6 +106,8 @@ func (r *capabilityRequestRepository) List(filter domain.CapabilityRequestFilter
 			cr.reviewed_by,
 			cr.requested_at,
 			cr.reviewed_at,
+			cr.expires_at,
+			cr.renews_capability_id,
 			cr.created_at,
 			cr.updated_at,
 			a.name AS agent_name,
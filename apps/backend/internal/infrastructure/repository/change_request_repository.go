@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ChangeRequestRepository implements domain.ChangeRequestRepository
+type ChangeRequestRepository struct {
+	db *sql.DB
+}
+
+// NewChangeRequestRepository creates a new change request repository
+func NewChangeRequestRepository(db *sql.DB) *ChangeRequestRepository {
+	return &ChangeRequestRepository{db: db}
+}
+
+const changeRequestColumns = `id, organization_id, target_type, target_id, summary, changes, previous,
+	effective_at, window_ends_at, monitor_minutes, rollback_error_rate, baseline_error_rate, status,
+	approval_request_id, requested_by, approved_by, approved_at, applied_at, closed_at, reason,
+	created_at, updated_at`
+
+// Create stores a new change request
+func (r *ChangeRequestRepository) Create(change *domain.ChangeRequest) error {
+	if change.ID == uuid.Nil {
+		change.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	change.CreatedAt = now
+	change.UpdatedAt = now
+
+	changesJSON, previousJSON, err := marshalChangeRequest(change)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO change_requests (`+changeRequestColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+	`,
+		change.ID,
+		change.OrganizationID,
+		change.TargetType,
+		change.TargetID,
+		change.Summary,
+		changesJSON,
+		previousJSON,
+		change.EffectiveAt,
+		change.WindowEndsAt,
+		change.MonitorMinutes,
+		change.RollbackErrorRate,
+		change.BaselineErrorRate,
+		change.Status,
+		change.ApprovalRequestID,
+		change.RequestedBy,
+		change.ApprovedBy,
+		change.ApprovedAt,
+		change.AppliedAt,
+		change.ClosedAt,
+		change.Reason,
+		change.CreatedAt,
+		change.UpdatedAt,
+	)
+	return err
+}
+
+// GetByID retrieves a change request by ID
+func (r *ChangeRequestRepository) GetByID(id uuid.UUID) (*domain.ChangeRequest, error) {
+	change, err := scanChangeRequest(r.db.QueryRow(`SELECT `+changeRequestColumns+` FROM change_requests WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrChangeRequestNotFound
+	}
+	return change, err
+}
+
+// GetByOrganization lists a page of an organization's change requests, newest first
+func (r *ChangeRequestRepository) GetByOrganization(orgID uuid.UUID, status domain.ChangeRequestStatus, limit, offset int) ([]*domain.ChangeRequest, int, error) {
+	where := `WHERE organization_id = $1`
+	args := []interface{}{orgID}
+	if status != "" {
+		where += ` AND status = $2`
+		args = append(args, status)
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM change_requests `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM change_requests %s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`,
+		changeRequestColumns, where, len(args)+1, len(args)+2)
+	changes, err := r.query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return changes, total, nil
+}
+
+// GetByStatus returns the change requests in any of the statuses, oldest effective time first
+func (r *ChangeRequestRepository) GetByStatus(statuses ...domain.ChangeRequestStatus) ([]*domain.ChangeRequest, error) {
+	values := make([]string, len(statuses))
+	for i, status := range statuses {
+		values[i] = string(status)
+	}
+	return r.query(`
+		SELECT `+changeRequestColumns+`
+		FROM change_requests
+		WHERE status = ANY($1)
+		ORDER BY effective_at ASC
+	`, pq.Array(values))
+}
+
+// Update stores the progress of a change request; the target and the change itself are immutable
+func (r *ChangeRequestRepository) Update(change *domain.ChangeRequest) error {
+	_, previousJSON, err := marshalChangeRequest(change)
+	if err != nil {
+		return err
+	}
+	change.UpdatedAt = time.Now().UTC()
+
+	result, err := r.db.Exec(`
+		UPDATE change_requests
+		SET previous = $1, baseline_error_rate = $2, status = $3, approval_request_id = $4, approved_by = $5,
+			approved_at = $6, applied_at = $7, closed_at = $8, reason = $9, updated_at = $10
+		WHERE id = $11
+	`, previousJSON, change.BaselineErrorRate, change.Status, change.ApprovalRequestID, change.ApprovedBy,
+		change.ApprovedAt, change.AppliedAt, change.ClosedAt, change.Reason, change.UpdatedAt, change.ID)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrChangeRequestNotFound
+	}
+	return nil
+}
+
+func (r *ChangeRequestRepository) query(query string, args ...interface{}) ([]*domain.ChangeRequest, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*domain.ChangeRequest
+	for rows.Next() {
+		change, err := scanChangeRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// marshalChangeRequest encodes the changes and the previous values; previous stays NULL until
+// the change is applied
+func marshalChangeRequest(change *domain.ChangeRequest) ([]byte, interface{}, error) {
+	changes := change.Changes
+	if changes == nil {
+		changes = map[string]interface{}{}
+	}
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal changes: %w", err)
+	}
+	if change.Previous == nil {
+		return changesJSON, nil, nil
+	}
+	previousJSON, err := json.Marshal(change.Previous)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal previous values: %w", err)
+	}
+	return changesJSON, previousJSON, nil
+}
+
+func scanChangeRequest(row interface{ Scan(...interface{}) error }) (*domain.ChangeRequest, error) {
+	change := &domain.ChangeRequest{}
+	var changesJSON, previousJSON []byte
+	var windowEndsAt, approvedAt, appliedAt, closedAt sql.NullTime
+	var baseline sql.NullFloat64
+	var approvalRequestID, approvedBy uuid.NullUUID
+
+	err := row.Scan(
+		&change.ID,
+		&change.OrganizationID,
+		&change.TargetType,
+		&change.TargetID,
+		&change.Summary,
+		&changesJSON,
+		&previousJSON,
+		&change.EffectiveAt,
+		&windowEndsAt,
+		&change.MonitorMinutes,
+		&change.RollbackErrorRate,
+		&baseline,
+		&change.Status,
+		&approvalRequestID,
+		&change.RequestedBy,
+		&approvedBy,
+		&approvedAt,
+		&appliedAt,
+		&closedAt,
+		&change.Reason,
+		&change.CreatedAt,
+		&change.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(changesJSON, &change.Changes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal changes: %w", err)
+	}
+	if len(previousJSON) > 0 {
+		if err := json.Unmarshal(previousJSON, &change.Previous); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal previous values: %w", err)
+		}
+	}
+	if windowEndsAt.Valid {
+		change.WindowEndsAt = &windowEndsAt.Time
+	}
+	if baseline.Valid {
+		change.BaselineErrorRate = &baseline.Float64
+	}
+	if approvalRequestID.Valid {
+		change.ApprovalRequestID = &approvalRequestID.UUID
+	}
+	if approvedBy.Valid {
+		change.ApprovedBy = &approvedBy.UUID
+	}
+	if approvedAt.Valid {
+		change.ApprovedAt = &approvedAt.Time
+	}
+	if appliedAt.Valid {
+		change.AppliedAt = &appliedAt.Time
+	}
+	if closedAt.Valid {
+		change.ClosedAt = &closedAt.Time
+	}
+	return change, nil
+}
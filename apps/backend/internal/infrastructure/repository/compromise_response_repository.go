@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// CompromiseResponseRepository implements domain.CompromiseResponseRepository
+type CompromiseResponseRepository struct {
+	db *sql.DB
+}
+
+// NewCompromiseResponseRepository creates a new compromise response repository
+func NewCompromiseResponseRepository(db *sql.DB) *CompromiseResponseRepository {
+	return &CompromiseResponseRepository{db: db}
+}
+
+// GetPolicy retrieves the organization's compromise response policy, or nil if none is configured
+func (r *CompromiseResponseRepository) GetPolicy(orgID uuid.UUID) (*domain.CompromiseResponsePolicy, error) {
+	query := `
+		SELECT id, organization_id, revoke_api_keys, revoke_owner_sdk_tokens, invalidate_attestations,
+			notify_mcp_owners, open_incident, freeze_trust_score, incident_severity, updated_by, created_at, updated_at
+		FROM compromise_response_policies
+		WHERE organization_id = $1
+	`
+
+	policy := &domain.CompromiseResponsePolicy{}
+	err := r.db.QueryRow(query, orgID).Scan(
+		&policy.ID,
+		&policy.OrganizationID,
+		&policy.RevokeAPIKeys,
+		&policy.RevokeOwnerSDKTokens,
+		&policy.InvalidateAttestations,
+		&policy.NotifyMCPOwners,
+		&policy.OpenIncident,
+		&policy.FreezeTrustScore,
+		&policy.IncidentSeverity,
+		&policy.UpdatedBy,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// UpsertPolicy creates or replaces the organization's compromise response policy
+func (r *CompromiseResponseRepository) UpsertPolicy(policy *domain.CompromiseResponsePolicy) error {
+	query := `
+		INSERT INTO compromise_response_policies (
+			id, organization_id, revoke_api_keys, revoke_owner_sdk_tokens, invalidate_attestations,
+			notify_mcp_owners, open_incident, freeze_trust_score, incident_severity, updated_by, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			revoke_api_keys = EXCLUDED.revoke_api_keys,
+			revoke_owner_sdk_tokens = EXCLUDED.revoke_owner_sdk_tokens,
+			invalidate_attestations = EXCLUDED.invalidate_attestations,
+			notify_mcp_owners = EXCLUDED.notify_mcp_owners,
+			open_incident = EXCLUDED.open_incident,
+			freeze_trust_score = EXCLUDED.freeze_trust_score,
+			incident_severity = EXCLUDED.incident_severity,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at, updated_at
+	`
+
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+
+	return r.db.QueryRow(query,
+		policy.ID,
+		policy.OrganizationID,
+		policy.RevokeAPIKeys,
+		policy.RevokeOwnerSDKTokens,
+		policy.InvalidateAttestations,
+		policy.NotifyMCPOwners,
+		policy.OpenIncident,
+		policy.FreezeTrustScore,
+		policy.IncidentSeverity,
+		policy.UpdatedBy,
+		time.Now().UTC(),
+	).Scan(&policy.ID, &policy.CreatedAt, &policy.UpdatedAt)
+}
+
+// CreateResponse stores the report of an executed response bundle
+func (r *CompromiseResponseRepository) CreateResponse(response *domain.CompromiseResponse) error {
+	query := `
+		INSERT INTO compromise_responses (
+			id, organization_id, agent_id, trigger, reason, triggered_by, incident_id, actions, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	if response.ID == uuid.Nil {
+		response.ID = uuid.New()
+	}
+	if response.CreatedAt.IsZero() {
+		response.CreatedAt = time.Now().UTC()
+	}
+
+	actionsJSON, err := json.Marshal(response.Actions)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query,
+		response.ID,
+		response.OrganizationID,
+		response.AgentID,
+		response.Trigger,
+		nullString(response.Reason),
+		response.TriggeredBy,
+		response.IncidentID,
+		actionsJSON,
+		response.CreatedAt,
+	)
+	return err
+}
+
+// GetResponsesByAgent retrieves the response reports of an agent, newest first
+func (r *CompromiseResponseRepository) GetResponsesByAgent(agentID uuid.UUID) ([]*domain.CompromiseResponse, error) {
+	query := `
+		SELECT id, organization_id, agent_id, trigger, reason, triggered_by, incident_id, actions, created_at
+		FROM compromise_responses
+		WHERE agent_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var responses []*domain.CompromiseResponse
+	for rows.Next() {
+		response := &domain.CompromiseResponse{}
+		var reason sql.NullString
+		var actionsJSON []byte
+
+		err := rows.Scan(
+			&response.ID,
+			&response.OrganizationID,
+			&response.AgentID,
+			&response.Trigger,
+			&reason,
+			&response.TriggeredBy,
+			&response.IncidentID,
+			&actionsJSON,
+			&response.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		response.Reason = reason.String
+		if err := json.Unmarshal(actionsJSON, &response.Actions); err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, rows.Err()
+}
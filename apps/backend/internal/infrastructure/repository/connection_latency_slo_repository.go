@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ConnectionLatencySLORepository implements domain.ConnectionLatencySLORepository
+type ConnectionLatencySLORepository struct {
+	db *sql.DB
+}
+
+// NewConnectionLatencySLORepository creates a new connection latency SLO repository
+func NewConnectionLatencySLORepository(db *sql.DB) *ConnectionLatencySLORepository {
+	return &ConnectionLatencySLORepository{db: db}
+}
+
+const connectionLatencySLOColumns = `id, organization_id, agent_id, mcp_server_id, p95_threshold_ms, window_minutes, sustained_minutes, is_enabled, last_p95_ms, last_samples, last_failures, last_evaluated_at, breached_since, alerted_at, attribution, created_by, created_at, updated_at`
+
+// Upsert stores the SLO of its agent and MCP server, replacing the existing one's objective and state
+func (r *ConnectionLatencySLORepository) Upsert(slo *domain.ConnectionLatencySLO) error {
+	query := `
+		INSERT INTO connection_latency_slos (` + connectionLatencySLOColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		ON CONFLICT (agent_id, mcp_server_id) DO UPDATE SET
+			p95_threshold_ms = EXCLUDED.p95_threshold_ms,
+			window_minutes = EXCLUDED.window_minutes,
+			sustained_minutes = EXCLUDED.sustained_minutes,
+			is_enabled = EXCLUDED.is_enabled,
+			last_p95_ms = EXCLUDED.last_p95_ms,
+			last_samples = EXCLUDED.last_samples,
+			last_failures = EXCLUDED.last_failures,
+			last_evaluated_at = EXCLUDED.last_evaluated_at,
+			breached_since = EXCLUDED.breached_since,
+			alerted_at = EXCLUDED.alerted_at,
+			attribution = EXCLUDED.attribution,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_by, created_at
+	`
+	if slo.ID == uuid.Nil {
+		slo.ID = uuid.New()
+	}
+
+	var createdBy uuid.NullUUID
+	err := r.db.QueryRow(query,
+		slo.ID,
+		slo.OrganizationID,
+		slo.AgentID,
+		slo.MCPServerID,
+		slo.P95ThresholdMs,
+		slo.WindowMinutes,
+		slo.SustainedMinutes,
+		slo.IsEnabled,
+		slo.LastP95Ms,
+		slo.LastSamples,
+		slo.LastFailures,
+		slo.LastEvaluatedAt,
+		slo.BreachedSince,
+		slo.AlertedAt,
+		nullString(string(slo.Attribution)),
+		slo.CreatedBy,
+		slo.CreatedAt,
+		slo.UpdatedAt,
+	).Scan(&slo.ID, &createdBy, &slo.CreatedAt)
+	if err != nil {
+		return err
+	}
+	slo.CreatedBy = createdBy.UUID
+	return nil
+}
+
+// GetByConnection retrieves the SLO of an agent's connection to an MCP server
+func (r *ConnectionLatencySLORepository) GetByConnection(agentID, mcpServerID uuid.UUID) (*domain.ConnectionLatencySLO, error) {
+	query := `SELECT ` + connectionLatencySLOColumns + ` FROM connection_latency_slos WHERE agent_id = $1 AND mcp_server_id = $2`
+
+	slo, err := scanConnectionLatencySLO(r.db.QueryRow(query, agentID, mcpServerID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrConnectionLatencySLONotFound
+	}
+	return slo, err
+}
+
+// ListByMCPServer returns the SLOs of the MCP server's connections, oldest first
+func (r *ConnectionLatencySLORepository) ListByMCPServer(mcpServerID uuid.UUID) ([]*domain.ConnectionLatencySLO, error) {
+	query := `
+		SELECT ` + connectionLatencySLOColumns + `
+		FROM connection_latency_slos
+		WHERE mcp_server_id = $1
+		ORDER BY created_at
+	`
+	return r.query(query, mcpServerID)
+}
+
+// ListEnabled returns every enabled SLO, for the evaluation job
+func (r *ConnectionLatencySLORepository) ListEnabled() ([]*domain.ConnectionLatencySLO, error) {
+	query := `
+		SELECT ` + connectionLatencySLOColumns + `
+		FROM connection_latency_slos
+		WHERE is_enabled = true
+		ORDER BY mcp_server_id, created_at
+	`
+	return r.query(query)
+}
+
+// UpdateState stores the evaluation state of an SLO
+func (r *ConnectionLatencySLORepository) UpdateState(slo *domain.ConnectionLatencySLO) error {
+	query := `
+		UPDATE connection_latency_slos
+		SET last_p95_ms = $1, last_samples = $2, last_failures = $3, last_evaluated_at = $4,
+			breached_since = $5, alerted_at = $6, attribution = $7
+		WHERE id = $8
+	`
+	result, err := r.db.Exec(query,
+		slo.LastP95Ms,
+		slo.LastSamples,
+		slo.LastFailures,
+		slo.LastEvaluatedAt,
+		slo.BreachedSince,
+		slo.AlertedAt,
+		nullString(string(slo.Attribution)),
+		slo.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrConnectionLatencySLONotFound
+	}
+	return nil
+}
+
+// Delete removes the SLO of an agent's connection to an MCP server
+func (r *ConnectionLatencySLORepository) Delete(agentID, mcpServerID uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM connection_latency_slos WHERE agent_id = $1 AND mcp_server_id = $2`, agentID, mcpServerID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrConnectionLatencySLONotFound
+	}
+	return nil
+}
+
+func (r *ConnectionLatencySLORepository) query(query string, args ...interface{}) ([]*domain.ConnectionLatencySLO, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slos := make([]*domain.ConnectionLatencySLO, 0)
+	for rows.Next() {
+		slo, err := scanConnectionLatencySLO(rows)
+		if err != nil {
+			return nil, err
+		}
+		slos = append(slos, slo)
+	}
+	return slos, rows.Err()
+}
+
+func scanConnectionLatencySLO(row rowScanner) (*domain.ConnectionLatencySLO, error) {
+	slo := &domain.ConnectionLatencySLO{}
+	var lastP95 sql.NullFloat64
+	var lastEvaluatedAt, breachedSince, alertedAt sql.NullTime
+	var attribution sql.NullString
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&slo.ID,
+		&slo.OrganizationID,
+		&slo.AgentID,
+		&slo.MCPServerID,
+		&slo.P95ThresholdMs,
+		&slo.WindowMinutes,
+		&slo.SustainedMinutes,
+		&slo.IsEnabled,
+		&lastP95,
+		&slo.LastSamples,
+		&slo.LastFailures,
+		&lastEvaluatedAt,
+		&breachedSince,
+		&alertedAt,
+		&attribution,
+		&createdBy,
+		&slo.CreatedAt,
+		&slo.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastP95.Valid {
+		slo.LastP95Ms = &lastP95.Float64
+	}
+	if lastEvaluatedAt.Valid {
+		slo.LastEvaluatedAt = &lastEvaluatedAt.Time
+	}
+	if breachedSince.Valid {
+		slo.BreachedSince = &breachedSince.Time
+	}
+	if alertedAt.Valid {
+		slo.AlertedAt = &alertedAt.Time
+	}
+	slo.Attribution = domain.LatencyAttribution(attribution.String)
+	slo.CreatedBy = createdBy.UUID
+	return slo, nil
+}
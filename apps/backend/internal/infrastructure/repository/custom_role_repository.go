@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// CustomRoleRepository implements domain.CustomRoleRepository
+type CustomRoleRepository struct {
+	db *sql.DB
+}
+
+// NewCustomRoleRepository creates a new custom role repository
+func NewCustomRoleRepository(db *sql.DB) *CustomRoleRepository {
+	return &CustomRoleRepository{db: db}
+}
+
+const customRoleColumns = `r.id, r.organization_id, r.name, r.description, r.permissions, r.created_by, r.created_at, r.updated_at,
+	(SELECT COUNT(*) FROM custom_role_assignments a WHERE a.role_id = r.id)`
+
+// Create stores a new custom role
+func (r *CustomRoleRepository) Create(role *domain.CustomRole) error {
+	if role.ID == uuid.Nil {
+		role.ID = uuid.New()
+	}
+	now := time.Now()
+	role.CreatedAt, role.UpdatedAt = now, now
+	permissionsJSON, err := json.Marshal(role.Permissions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal permissions: %w", err)
+	}
+
+	query := `
+		INSERT INTO custom_roles (id, organization_id, name, description, permissions, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = r.db.Exec(query,
+		role.ID,
+		role.OrganizationID,
+		role.Name,
+		role.Description,
+		permissionsJSON,
+		role.CreatedBy,
+		role.CreatedAt,
+		role.UpdatedAt,
+	)
+	return err
+}
+
+// GetByID retrieves a custom role by ID
+func (r *CustomRoleRepository) GetByID(id uuid.UUID) (*domain.CustomRole, error) {
+	query := `SELECT ` + customRoleColumns + ` FROM custom_roles r WHERE r.id = $1`
+	role, err := r.scan(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrCustomRoleNotFound
+	}
+	return role, err
+}
+
+// ListByOrganization retrieves an organization's custom roles by name
+func (r *CustomRoleRepository) ListByOrganization(orgID uuid.UUID) ([]*domain.CustomRole, error) {
+	query := `SELECT ` + customRoleColumns + ` FROM custom_roles r WHERE r.organization_id = $1 ORDER BY r.name`
+	return r.list(query, orgID)
+}
+
+// Update stores a role's name, description and permissions
+func (r *CustomRoleRepository) Update(role *domain.CustomRole) error {
+	role.UpdatedAt = time.Now()
+	permissionsJSON, err := json.Marshal(role.Permissions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal permissions: %w", err)
+	}
+
+	query := `
+		UPDATE custom_roles
+		SET name = $1, description = $2, permissions = $3, updated_at = $4
+		WHERE id = $5
+	`
+	result, err := r.db.Exec(query, role.Name, role.Description, permissionsJSON, role.UpdatedAt, role.ID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrCustomRoleNotFound
+	}
+	return nil
+}
+
+// Delete removes a custom role; its assignments cascade
+func (r *CustomRoleRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM custom_roles WHERE id = $1`, id)
+	return err
+}
+
+// Assign assigns a custom role to a user
+func (r *CustomRoleRepository) Assign(assignment *domain.CustomRoleAssignment) error {
+	if assignment.AssignedAt.IsZero() {
+		assignment.AssignedAt = time.Now()
+	}
+	query := `
+		INSERT INTO custom_role_assignments (role_id, user_id, assigned_by, assigned_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (role_id, user_id) DO NOTHING
+	`
+	_, err := r.db.Exec(query, assignment.RoleID, assignment.UserID, assignment.AssignedBy, assignment.AssignedAt)
+	return err
+}
+
+// Unassign removes a custom role from a user
+func (r *CustomRoleRepository) Unassign(roleID, userID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM custom_role_assignments WHERE role_id = $1 AND user_id = $2`, roleID, userID)
+	return err
+}
+
+// ListUsers retrieves the assignments of a custom role, oldest first
+func (r *CustomRoleRepository) ListUsers(roleID uuid.UUID) ([]*domain.CustomRoleAssignment, error) {
+	query := `
+		SELECT role_id, user_id, assigned_by, assigned_at
+		FROM custom_role_assignments
+		WHERE role_id = $1
+		ORDER BY assigned_at
+	`
+	rows, err := r.db.Query(query, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := make([]*domain.CustomRoleAssignment, 0)
+	for rows.Next() {
+		assignment := &domain.CustomRoleAssignment{}
+		if err := rows.Scan(&assignment.RoleID, &assignment.UserID, &assignment.AssignedBy, &assignment.AssignedAt); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments, rows.Err()
+}
+
+// ListForUser retrieves the custom roles assigned to a user
+func (r *CustomRoleRepository) ListForUser(userID uuid.UUID) ([]*domain.CustomRole, error) {
+	query := `
+		SELECT ` + customRoleColumns + `
+		FROM custom_roles r
+		JOIN custom_role_assignments ura ON ura.role_id = r.id
+		WHERE ura.user_id = $1
+		ORDER BY r.name
+	`
+	return r.list(query, userID)
+}
+
+func (r *CustomRoleRepository) list(query string, arg interface{}) ([]*domain.CustomRole, error) {
+	rows, err := r.db.Query(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := make([]*domain.CustomRole, 0)
+	for rows.Next() {
+		role, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+func (r *CustomRoleRepository) scan(row interface{ Scan(...interface{}) error }) (*domain.CustomRole, error) {
+	role := &domain.CustomRole{}
+	var permissionsJSON []byte
+	err := row.Scan(
+		&role.ID,
+		&role.OrganizationID,
+		&role.Name,
+		&role.Description,
+		&permissionsJSON,
+		&role.CreatedBy,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+		&role.UserCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(permissionsJSON, &role.Permissions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal permissions: %w", err)
+	}
+	return role, nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// DataErasureRequestRepository implements domain.DataErasureRequestRepository
+type DataErasureRequestRepository struct {
+	db *sql.DB
+}
+
+// NewDataErasureRequestRepository creates a new data erasure request repository
+func NewDataErasureRequestRepository(db *sql.DB) *DataErasureRequestRepository {
+	return &DataErasureRequestRepository{db: db}
+}
+
+const dataErasureRequestColumns = `id, organization_id, user_id, requested_by, reason, status, decided_by, decided_at,
+	decision_reason, report, created_at, completed_at`
+
+// Create stores a new data erasure request
+func (r *DataErasureRequestRepository) Create(request *domain.DataErasureRequest) error {
+	if request.ID == uuid.Nil {
+		request.ID = uuid.New()
+	}
+	if request.CreatedAt.IsZero() {
+		request.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO data_erasure_requests (id, organization_id, user_id, requested_by, reason, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(query,
+		request.ID,
+		request.OrganizationID,
+		request.UserID,
+		request.RequestedBy,
+		request.Reason,
+		request.Status,
+		request.CreatedAt,
+	)
+	return err
+}
+
+// GetByID retrieves a data erasure request by ID
+func (r *DataErasureRequestRepository) GetByID(id uuid.UUID) (*domain.DataErasureRequest, error) {
+	query := `SELECT ` + dataErasureRequestColumns + ` FROM data_erasure_requests WHERE id = $1`
+	request, err := r.scan(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrDataErasureRequestNotFound
+	}
+	return request, err
+}
+
+// ListByOrganization retrieves an organization's data erasure requests, newest first
+func (r *DataErasureRequestRepository) ListByOrganization(orgID uuid.UUID, status domain.DataErasureStatus, limit, offset int) ([]*domain.DataErasureRequest, error) {
+	query := `
+		SELECT ` + dataErasureRequestColumns + `
+		FROM data_erasure_requests
+		WHERE organization_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+	return r.list(query, orgID, status, limit, offset)
+}
+
+// ListByUser retrieves the requests to erase a user's data, newest first
+func (r *DataErasureRequestRepository) ListByUser(userID uuid.UUID) ([]*domain.DataErasureRequest, error) {
+	query := `
+		SELECT ` + dataErasureRequestColumns + `
+		FROM data_erasure_requests
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	return r.list(query, userID)
+}
+
+// Decide stores the decision of a request that is still pending
+func (r *DataErasureRequestRepository) Decide(request *domain.DataErasureRequest) (bool, error) {
+	query := `
+		UPDATE data_erasure_requests
+		SET status = $1, decided_by = $2, decided_at = $3, decision_reason = $4
+		WHERE id = $5 AND status = 'pending'
+	`
+	result, err := r.db.Exec(query,
+		request.Status,
+		request.DecidedBy,
+		request.DecidedAt,
+		request.DecisionReason,
+		request.ID,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// Complete stores the completion report of an approved request
+func (r *DataErasureRequestRepository) Complete(request *domain.DataErasureRequest) error {
+	reportJSON, err := json.Marshal(request.Report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	query := `
+		UPDATE data_erasure_requests
+		SET status = $1, report = $2, completed_at = $3
+		WHERE id = $4 AND status = 'approved'
+	`
+	_, err = r.db.Exec(query, request.Status, reportJSON, request.CompletedAt, request.ID)
+	return err
+}
+
+func (r *DataErasureRequestRepository) list(query string, args ...interface{}) ([]*domain.DataErasureRequest, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := make([]*domain.DataErasureRequest, 0)
+	for rows.Next() {
+		request, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	return requests, rows.Err()
+}
+
+func (r *DataErasureRequestRepository) scan(row interface{ Scan(...interface{}) error }) (*domain.DataErasureRequest, error) {
+	request := &domain.DataErasureRequest{}
+	var requestedBy uuid.NullUUID
+	var reportJSON []byte
+	err := row.Scan(
+		&request.ID,
+		&request.OrganizationID,
+		&request.UserID,
+		&requestedBy,
+		&request.Reason,
+		&request.Status,
+		&request.DecidedBy,
+		&request.DecidedAt,
+		&request.DecisionReason,
+		&reportJSON,
+		&request.CreatedAt,
+		&request.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	request.RequestedBy = requestedBy.UUID
+	if len(reportJSON) > 0 {
+		if err := json.Unmarshal(reportJSON, &request.Report); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal report: %w", err)
+		}
+	}
+	return request, nil
+}
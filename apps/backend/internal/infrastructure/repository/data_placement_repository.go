@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// DataPlacementRepository implements domain.DataPlacementRepository
+type DataPlacementRepository struct {
+	db *sql.DB
+}
+
+// NewDataPlacementRepository creates a new data placement repository
+func NewDataPlacementRepository(db *sql.DB) *DataPlacementRepository {
+	return &DataPlacementRepository{db: db}
+}
+
+func (r *DataPlacementRepository) Record(placement *domain.DataPlacement) error {
+	_, err := r.db.Exec(`
+		INSERT INTO data_placements (resource_id, kind, organization_id, region, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (resource_id) DO NOTHING
+	`, placement.ResourceID, placement.Kind, placement.OrganizationID, placement.Region, placement.CreatedAt)
+	return err
+}
+
+func (r *DataPlacementRepository) Get(resourceID uuid.UUID) (*domain.DataPlacement, error) {
+	placement := &domain.DataPlacement{}
+	err := r.db.QueryRow(`
+		SELECT resource_id, kind, organization_id, region, created_at
+		FROM data_placements
+		WHERE resource_id = $1
+	`, resourceID).Scan(&placement.ResourceID, &placement.Kind, &placement.OrganizationID, &placement.Region, &placement.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return placement, nil
+}
+
+func (r *DataPlacementRepository) GetMany(resourceIDs []uuid.UUID) ([]*domain.DataPlacement, error) {
+	placements := []*domain.DataPlacement{}
+	if len(resourceIDs) == 0 {
+		return placements, nil
+	}
+	rows, err := r.db.Query(`
+		SELECT resource_id, kind, organization_id, region, created_at
+		FROM data_placements
+		WHERE resource_id = ANY($1::uuid[])
+	`, pq.Array(uuidStrings(resourceIDs)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		placement := &domain.DataPlacement{}
+		if err := rows.Scan(&placement.ResourceID, &placement.Kind, &placement.OrganizationID, &placement.Region, &placement.CreatedAt); err != nil {
+			return nil, err
+		}
+		placements = append(placements, placement)
+	}
+	return placements, rows.Err()
+}
+
+func (r *DataPlacementRepository) Delete(resourceID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM data_placements WHERE resource_id = $1`, resourceID)
+	return err
+}
+
+func (r *DataPlacementRepository) CountByOrganization(orgID uuid.UUID) (map[domain.DataPlacementKind]int, error) {
+	rows, err := r.db.Query(`
+		SELECT kind, COUNT(*) FROM data_placements WHERE organization_id = $1 GROUP BY kind
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[domain.DataPlacementKind]int)
+	for rows.Next() {
+		var kind domain.DataPlacementKind
+		var count int
+		if err := rows.Scan(&kind, &count); err != nil {
+			return nil, err
+		}
+		counts[kind] = count
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// DemoRecordRepository implements domain.DemoRecordRepository
+type DemoRecordRepository struct {
+	db *sql.DB
+}
+
+// NewDemoRecordRepository creates a new demo record repository
+func NewDemoRecordRepository(db *sql.DB) *DemoRecordRepository {
+	return &DemoRecordRepository{db: db}
+}
+
+// Create records a resource the demo data generator created
+func (r *DemoRecordRepository) Create(record *domain.DemoRecord) error {
+	query := `
+		INSERT INTO demo_records (id, organization_id, resource_type, resource_id, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	if record.ID == uuid.Nil {
+		record.ID = uuid.New()
+	}
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.Exec(query,
+		record.ID,
+		record.OrganizationID,
+		record.ResourceType,
+		record.ResourceID,
+		uuid.NullUUID{UUID: record.CreatedBy, Valid: record.CreatedBy != uuid.Nil},
+		record.CreatedAt,
+	)
+	return err
+}
+
+// GetByOrganization returns the organization's demo records, oldest first
+func (r *DemoRecordRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.DemoRecord, error) {
+	query := `
+		SELECT id, organization_id, resource_type, resource_id, created_by, created_at
+		FROM demo_records
+		WHERE organization_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*domain.DemoRecord
+	for rows.Next() {
+		record := &domain.DemoRecord{}
+		var createdBy uuid.NullUUID
+		if err := rows.Scan(
+			&record.ID,
+			&record.OrganizationID,
+			&record.ResourceType,
+			&record.ResourceID,
+			&createdBy,
+			&record.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if createdBy.Valid {
+			record.CreatedBy = createdBy.UUID
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// DeleteByOrganization forgets the organization's demo records
+func (r *DemoRecordRepository) DeleteByOrganization(orgID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM demo_records WHERE organization_id = $1`, orgID)
+	return err
+}
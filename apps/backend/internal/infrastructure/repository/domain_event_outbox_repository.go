@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// DomainEventOutboxRepository implements domain.DomainEventOutboxRepository
+type DomainEventOutboxRepository struct {
+	db *sql.DB
+}
+
+// NewDomainEventOutboxRepository creates a new domain event outbox repository
+func NewDomainEventOutboxRepository(db *sql.DB) *DomainEventOutboxRepository {
+	return &DomainEventOutboxRepository{db: db}
+}
+
+const domainEventColumns = `id, event_type, organization_id, aggregate_type, aggregate_id, payload, occurred_at,
+	status, attempt_count, next_attempt_at, last_error, published_at`
+
+func (r *DomainEventOutboxRepository) Append(event *domain.DomainEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.Status == "" {
+		event.Status = domain.DomainEventPending
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO domain_event_outbox (id, event_type, organization_id, aggregate_type, aggregate_id,
+			payload, occurred_at, status, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, event.ID, event.Type, event.OrganizationID, event.AggregateType, event.AggregateID,
+		[]byte(event.Payload), event.OccurredAt, event.Status, event.OccurredAt)
+	return err
+}
+
+// ClaimDue claims due events in the order they were appended. Claimed rows move to "publishing"
+// and their next_attempt_at becomes the lease expiry.
+func (r *DomainEventOutboxRepository) ClaimDue(limit int, lease time.Duration) ([]*domain.DomainEvent, error) {
+	rows, err := r.db.Query(`
+		WITH claimed AS (
+			UPDATE domain_event_outbox
+			SET status = 'publishing', next_attempt_at = $2
+			WHERE id IN (
+				SELECT id FROM domain_event_outbox
+				WHERE status IN ('pending', 'publishing') AND next_attempt_at <= NOW()
+				ORDER BY sequence ASC
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING sequence, `+domainEventColumns+`
+		)
+		SELECT `+domainEventColumns+` FROM claimed ORDER BY sequence ASC
+	`, limit, time.Now().UTC().Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*domain.DomainEvent{}
+	for rows.Next() {
+		event := &domain.DomainEvent{}
+		var payload []byte
+		var lastError sql.NullString
+		var publishedAt sql.NullTime
+		if err := rows.Scan(&event.ID, &event.Type, &event.OrganizationID, &event.AggregateType,
+			&event.AggregateID, &payload, &event.OccurredAt, &event.Status, &event.Attempts,
+			&event.NextAttemptAt, &lastError, &publishedAt); err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		event.LastError = lastError.String
+		if publishedAt.Valid {
+			event.PublishedAt = &publishedAt.Time
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (r *DomainEventOutboxRepository) MarkPublished(ids []uuid.UUID, publishedAt time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE domain_event_outbox
+		SET status = 'published', published_at = $2, attempt_count = attempt_count + 1, last_error = NULL
+		WHERE id = ANY($1::uuid[])
+	`, pq.Array(uuidStrings(ids)), publishedAt)
+	return err
+}
+
+func (r *DomainEventOutboxRepository) MarkFailed(ids []uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE domain_event_outbox
+		SET status = 'pending', next_attempt_at = $2, attempt_count = attempt_count + 1, last_error = $3
+		WHERE id = ANY($1::uuid[])
+	`, pq.Array(uuidStrings(ids)), nextAttemptAt, lastError)
+	return err
+}
+
+func (r *DomainEventOutboxRepository) DeletePublished(before time.Time) (int, error) {
+	result, err := r.db.Exec(`
+		DELETE FROM domain_event_outbox WHERE status = 'published' AND published_at < $1
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
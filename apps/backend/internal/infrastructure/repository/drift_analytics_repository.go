@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// DriftAnalyticsRepository implements domain.DriftAnalyticsRepository
+type DriftAnalyticsRepository struct {
+	db *sql.DB
+}
+
+// NewDriftAnalyticsRepository creates a new drift analytics repository
+func NewDriftAnalyticsRepository(db *sql.DB) *DriftAnalyticsRepository {
+	return &DriftAnalyticsRepository{db: db}
+}
+
+// driftAgentFilter appends the agent and fleet conditions shared by the queries.
+// column is the agent ID column of the queried table.
+func driftAgentFilter(filter *domain.DriftAnalyticsFilter, column string, args []interface{}) (string, []interface{}) {
+	clause := ""
+	if filter.AgentID != nil {
+		args = append(args, *filter.AgentID)
+		clause += fmt.Sprintf(" AND %s = $%d", column, len(args))
+	}
+	if filter.TagID != nil {
+		args = append(args, *filter.TagID)
+		clause += fmt.Sprintf(" AND %s IN (SELECT agent_id FROM agent_tags WHERE tag_id = $%d)", column, len(args))
+	}
+	return clause, args
+}
+
+// GetDriftOccurrences returns agent verifications in the period that detected drift, oldest first
+func (r *DriftAnalyticsRepository) GetDriftOccurrences(filter *domain.DriftAnalyticsFilter) ([]*domain.DriftOccurrence, error) {
+	args := []interface{}{filter.OrganizationID, filter.Start, filter.End}
+	agentClause, args := driftAgentFilter(filter, "ve.agent_id", args)
+
+	rows, err := r.db.Query(`
+		SELECT ve.agent_id, COALESCE(a.display_name, ve.agent_name, 'unknown'),
+			COALESCE(ve.mcp_server_drift, '[]'::jsonb), COALESCE(ve.capability_drift, '[]'::jsonb),
+			ve.created_at
+		FROM verification_events ve
+		LEFT JOIN agents a ON a.id = ve.agent_id
+		WHERE ve.organization_id = $1 AND ve.drift_detected = true AND ve.agent_id IS NOT NULL
+			AND ve.created_at >= $2 AND ve.created_at < $3`+agentClause+`
+		ORDER BY ve.created_at ASC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query drift occurrences: %w", err)
+	}
+	defer rows.Close()
+
+	occurrences := make([]*domain.DriftOccurrence, 0)
+	for rows.Next() {
+		occurrence := &domain.DriftOccurrence{}
+		var serverDrift, capabilityDrift []byte
+		if err := rows.Scan(&occurrence.AgentID, &occurrence.AgentName, &serverDrift, &capabilityDrift, &occurrence.DetectedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan drift occurrence: %w", err)
+		}
+		if err := json.Unmarshal(serverDrift, &occurrence.MCPServerDrift); err != nil {
+			return nil, fmt.Errorf("failed to decode MCP server drift: %w", err)
+		}
+		if err := json.Unmarshal(capabilityDrift, &occurrence.CapabilityDrift); err != nil {
+			return nil, fmt.Errorf("failed to decode capability drift: %w", err)
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	return occurrences, rows.Err()
+}
+
+// GetDriftAlerts returns configuration and capability drift alerts raised in the period, oldest first
+func (r *DriftAnalyticsRepository) GetDriftAlerts(filter *domain.DriftAnalyticsFilter) ([]*domain.DriftAlertRecord, error) {
+	args := []interface{}{filter.OrganizationID, domain.AlertTypeConfigurationDrift, filter.Start, filter.End, domain.AlertTypeCapabilityDrift}
+	agentClause, args := driftAgentFilter(filter, "resource_id", args)
+
+	rows, err := r.db.Query(`
+		SELECT id, resource_id, created_at, acknowledged_at
+		FROM alerts
+		WHERE organization_id = $1 AND alert_type IN ($2, $5) AND resource_type = 'agent'
+			AND created_at >= $3 AND created_at < $4`+agentClause+`
+		ORDER BY created_at ASC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query drift alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := make([]*domain.DriftAlertRecord, 0)
+	for rows.Next() {
+		var alertID, agentID uuid.UUID
+		var createdAt time.Time
+		var acknowledgedAt sql.NullTime
+		if err := rows.Scan(&alertID, &agentID, &createdAt, &acknowledgedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan drift alert: %w", err)
+		}
+
+		alert := &domain.DriftAlertRecord{AlertID: alertID, AgentID: agentID, CreatedAt: createdAt}
+		if acknowledgedAt.Valid {
+			alert.AcknowledgedAt = &acknowledgedAt.Time
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, rows.Err()
+}
+
+// GetDeprecatedCapabilities returns the unresolved capability deprecations of the filter's agents, oldest first
+func (r *DriftAnalyticsRepository) GetDeprecatedCapabilities(filter *domain.DriftAnalyticsFilter) ([]*domain.CapabilityDeprecation, error) {
+	agentClause, args := driftAgentFilter(filter, "cd.agent_id", []interface{}{filter.OrganizationID})
+
+	return queryCapabilityDeprecations(r.db, capabilityDeprecationSelect+`
+		WHERE cd.organization_id = $1 AND cd.resolved_at IS NULL`+agentClause+`
+		ORDER BY cd.deprecated_at ASC
+	`, args...)
+}
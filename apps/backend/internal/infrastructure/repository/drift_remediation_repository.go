@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// DriftRemediationRepository implements domain.DriftRemediationRepository
+type DriftRemediationRepository struct {
+	db *sql.DB
+}
+
+// NewDriftRemediationRepository creates a new drift remediation repository
+func NewDriftRemediationRepository(db *sql.DB) *DriftRemediationRepository {
+	return &DriftRemediationRepository{db: db}
+}
+
+const driftRemediationColumns = `id, organization_id, agent_id, alert_id, mcp_servers, status, enforcement_action,
+	policy_name, reason, decided_by, decided_at, created_at`
+
+// Create stores a new drift remediation
+func (r *DriftRemediationRepository) Create(remediation *domain.DriftRemediation) error {
+	if remediation.ID == uuid.Nil {
+		remediation.ID = uuid.New()
+	}
+	if remediation.CreatedAt.IsZero() {
+		remediation.CreatedAt = time.Now()
+	}
+	mcpServersJSON, err := json.Marshal(remediation.MCPServers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mcp_servers: %w", err)
+	}
+
+	query := `
+		INSERT INTO drift_remediations (` + driftRemediationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err = r.db.Exec(query,
+		remediation.ID,
+		remediation.OrganizationID,
+		remediation.AgentID,
+		remediation.AlertID,
+		mcpServersJSON,
+		remediation.Status,
+		remediation.EnforcementAction,
+		remediation.PolicyName,
+		remediation.Reason,
+		remediation.DecidedBy,
+		remediation.DecidedAt,
+		remediation.CreatedAt,
+	)
+	return err
+}
+
+// GetByID retrieves a drift remediation by ID
+func (r *DriftRemediationRepository) GetByID(id uuid.UUID) (*domain.DriftRemediation, error) {
+	query := `SELECT ` + driftRemediationColumns + ` FROM drift_remediations WHERE id = $1`
+	return r.scanOne(r.db.QueryRow(query, id))
+}
+
+// GetByAlertID retrieves the drift remediation proposed for an alert
+func (r *DriftRemediationRepository) GetByAlertID(alertID uuid.UUID) (*domain.DriftRemediation, error) {
+	query := `SELECT ` + driftRemediationColumns + ` FROM drift_remediations WHERE alert_id = $1`
+	return r.scanOne(r.db.QueryRow(query, alertID))
+}
+
+// ListByOrganization retrieves an organization's drift remediations, newest first
+func (r *DriftRemediationRepository) ListByOrganization(orgID uuid.UUID, status domain.DriftRemediationStatus, limit, offset int) ([]*domain.DriftRemediation, error) {
+	query := `
+		SELECT ` + driftRemediationColumns + `
+		FROM drift_remediations
+		WHERE organization_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(query, orgID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	remediations := make([]*domain.DriftRemediation, 0)
+	for rows.Next() {
+		remediation, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		remediations = append(remediations, remediation)
+	}
+	return remediations, rows.Err()
+}
+
+// Decide stores the decision of a remediation that is still pending
+func (r *DriftRemediationRepository) Decide(remediation *domain.DriftRemediation) (bool, error) {
+	query := `
+		UPDATE drift_remediations
+		SET status = $1, enforcement_action = $2, policy_name = $3, reason = $4, decided_by = $5, decided_at = $6
+		WHERE id = $7 AND status = 'pending'
+	`
+	result, err := r.db.Exec(query,
+		remediation.Status,
+		remediation.EnforcementAction,
+		remediation.PolicyName,
+		remediation.Reason,
+		remediation.DecidedBy,
+		remediation.DecidedAt,
+		remediation.ID,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *DriftRemediationRepository) scanOne(row *sql.Row) (*domain.DriftRemediation, error) {
+	remediation, err := r.scan(row)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrDriftRemediationNotFound
+	}
+	return remediation, err
+}
+
+func (r *DriftRemediationRepository) scan(row interface{ Scan(...interface{}) error }) (*domain.DriftRemediation, error) {
+	remediation := &domain.DriftRemediation{}
+	var mcpServersJSON []byte
+	err := row.Scan(
+		&remediation.ID,
+		&remediation.OrganizationID,
+		&remediation.AgentID,
+		&remediation.AlertID,
+		&mcpServersJSON,
+		&remediation.Status,
+		&remediation.EnforcementAction,
+		&remediation.PolicyName,
+		&remediation.Reason,
+		&remediation.DecidedBy,
+		&remediation.DecidedAt,
+		&remediation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(mcpServersJSON, &remediation.MCPServers); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal mcp_servers: %w", err)
+	}
+	return remediation, nil
+}
@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// EmailQueueRepository implements domain.EmailQueueRepository
+type EmailQueueRepository struct {
+	db *sql.DB
+}
+
+// NewEmailQueueRepository creates a new email queue repository
+func NewEmailQueueRepository(db *sql.DB) *EmailQueueRepository {
+	return &EmailQueueRepository{db: db}
+}
+
+const emailMessageColumns = `id, organization_id, to_address, from_address, from_name, reply_to, subject, body, is_html,
+	template, status, attempt_count, next_attempt_at, last_error, sent_at, bounced_at, bounce_reason, created_at`
+
+func (r *EmailQueueRepository) Enqueue(message *domain.EmailMessage) error {
+	if message.ID == uuid.Nil {
+		message.ID = uuid.New()
+	}
+	if message.Status == "" {
+		message.Status = domain.EmailMessageQueued
+	}
+	var orgID uuid.NullUUID
+	if message.OrganizationID != nil {
+		orgID = uuid.NullUUID{UUID: *message.OrganizationID, Valid: true}
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO email_messages (id, organization_id, to_address, from_address, from_name, reply_to,
+			subject, body, is_html, template, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, message.ID, orgID, message.To, nullString(message.FromAddress), nullString(message.FromName),
+		nullString(message.ReplyTo), message.Subject, message.Body, message.IsHTML, nullString(string(message.Template)),
+		message.Status, message.NextAttemptAt, message.CreatedAt)
+	return err
+}
+
+// ClaimDue claims due messages in the order they were queued. Claimed rows move to "sending"
+// and their next_attempt_at becomes the lease expiry.
+func (r *EmailQueueRepository) ClaimDue(limit int, lease time.Duration) ([]*domain.EmailMessage, error) {
+	rows, err := r.db.Query(`
+		WITH claimed AS (
+			UPDATE email_messages
+			SET status = 'sending', next_attempt_at = $2
+			WHERE id IN (
+				SELECT id FROM email_messages
+				WHERE status IN ('queued', 'sending') AND next_attempt_at <= NOW()
+				ORDER BY created_at ASC
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING `+emailMessageColumns+`
+		)
+		SELECT `+emailMessageColumns+` FROM claimed ORDER BY created_at ASC
+	`, limit, time.Now().UTC().Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []*domain.EmailMessage{}
+	for rows.Next() {
+		message, err := scanEmailMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+func (r *EmailQueueRepository) MarkSent(id uuid.UUID, sentAt time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE email_messages
+		SET status = 'sent', sent_at = $2, attempt_count = attempt_count + 1, last_error = NULL
+		WHERE id = $1
+	`, id, sentAt)
+	return err
+}
+
+func (r *EmailQueueRepository) MarkRetry(id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE email_messages
+		SET status = 'queued', next_attempt_at = $2, attempt_count = attempt_count + 1, last_error = $3
+		WHERE id = $1
+	`, id, nextAttemptAt, lastError)
+	return err
+}
+
+func (r *EmailQueueRepository) MarkFinal(id uuid.UUID, status domain.EmailMessageStatus, lastError string) error {
+	// A suppressed message was never attempted
+	_, err := r.db.Exec(`
+		UPDATE email_messages
+		SET status = $2, last_error = $3,
+			attempt_count = attempt_count + CASE WHEN $2 = 'suppressed' THEN 0 ELSE 1 END
+		WHERE id = $1
+	`, id, status, lastError)
+	return err
+}
+
+func (r *EmailQueueRepository) MarkBounced(address, reason string, bouncedAt time.Time) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE email_messages
+		SET status = 'bounced', bounced_at = $3, bounce_reason = $2
+		WHERE id = (
+			SELECT id FROM email_messages
+			WHERE LOWER(to_address) = LOWER($1) AND status = 'sent'
+			ORDER BY sent_at DESC
+			LIMIT 1
+		)
+	`, address, nullString(reason), bouncedAt)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *EmailQueueRepository) List(filter domain.EmailMessageFilter) ([]*domain.EmailMessage, int, error) {
+	where := `WHERE organization_id = $1`
+	args := []interface{}{filter.OrganizationID}
+	if filter.Status != "" {
+		where += ` AND status = $2`
+		args = append(args, filter.Status)
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM email_messages `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM email_messages %s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`,
+		emailMessageColumns, where, len(args)+1, len(args)+2)
+	rows, err := r.db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	messages := []*domain.EmailMessage{}
+	for rows.Next() {
+		message, err := scanEmailMessage(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, total, rows.Err()
+}
+
+func (r *EmailQueueRepository) DeleteFinishedBefore(before time.Time) (int, error) {
+	result, err := r.db.Exec(`
+		DELETE FROM email_messages
+		WHERE status IN ('sent', 'failed', 'bounced', 'suppressed') AND created_at < $1
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
+
+func scanEmailMessage(row rowScanner) (*domain.EmailMessage, error) {
+	message := &domain.EmailMessage{}
+	var orgID uuid.NullUUID
+	var fromAddress, fromName, replyTo, template, lastError, bounceReason sql.NullString
+	var sentAt, bouncedAt sql.NullTime
+
+	err := row.Scan(
+		&message.ID,
+		&orgID,
+		&message.To,
+		&fromAddress,
+		&fromName,
+		&replyTo,
+		&message.Subject,
+		&message.Body,
+		&message.IsHTML,
+		&template,
+		&message.Status,
+		&message.Attempts,
+		&message.NextAttemptAt,
+		&lastError,
+		&sentAt,
+		&bouncedAt,
+		&bounceReason,
+		&message.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if orgID.Valid {
+		message.OrganizationID = &orgID.UUID
+	}
+	message.FromAddress = fromAddress.String
+	message.FromName = fromName.String
+	message.ReplyTo = replyTo.String
+	message.Template = domain.EmailTemplate(template.String)
+	message.LastError = lastError.String
+	message.BounceReason = bounceReason.String
+	if sentAt.Valid {
+		message.SentAt = &sentAt.Time
+	}
+	if bouncedAt.Valid {
+		message.BouncedAt = &bouncedAt.Time
+	}
+	return message, nil
+}
+
+// EmailSuppressionRepository implements domain.EmailSuppressionRepository
+type EmailSuppressionRepository struct {
+	db *sql.DB
+}
+
+// NewEmailSuppressionRepository creates a new email suppression repository
+func NewEmailSuppressionRepository(db *sql.DB) *EmailSuppressionRepository {
+	return &EmailSuppressionRepository{db: db}
+}
+
+func (r *EmailSuppressionRepository) Add(suppression *domain.EmailSuppression) error {
+	_, err := r.db.Exec(`
+		INSERT INTO email_suppressions (address, bounce_type, reason, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (address) DO UPDATE
+		SET bounce_type = EXCLUDED.bounce_type, reason = EXCLUDED.reason
+	`, suppression.Address, suppression.Type, nullString(suppression.Reason), suppression.CreatedAt)
+	return err
+}
+
+func (r *EmailSuppressionRepository) IsSuppressed(address string) (bool, error) {
+	var suppressed bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM email_suppressions WHERE address = $1)`, address).Scan(&suppressed)
+	return suppressed, err
+}
+
+func (r *EmailSuppressionRepository) List(limit, offset int) ([]*domain.EmailSuppression, int, error) {
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM email_suppressions`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT address, bounce_type, reason, created_at
+		FROM email_suppressions
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	suppressions := []*domain.EmailSuppression{}
+	for rows.Next() {
+		suppression := &domain.EmailSuppression{}
+		var reason sql.NullString
+		if err := rows.Scan(&suppression.Address, &suppression.Type, &reason, &suppression.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		suppression.Reason = reason.String
+		suppressions = append(suppressions, suppression)
+	}
+	return suppressions, total, rows.Err()
+}
+
+func (r *EmailSuppressionRepository) Remove(address string) error {
+	result, err := r.db.Exec(`DELETE FROM email_suppressions WHERE address = $1`, address)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrEmailSuppressionNotFound
+	}
+	return nil
+}
+
+// OrganizationEmailSenderRepository implements domain.OrganizationEmailSenderRepository
+type OrganizationEmailSenderRepository struct {
+	db *sql.DB
+}
+
+// NewOrganizationEmailSenderRepository creates a new organization email sender repository
+func NewOrganizationEmailSenderRepository(db *sql.DB) *OrganizationEmailSenderRepository {
+	return &OrganizationEmailSenderRepository{db: db}
+}
+
+func (r *OrganizationEmailSenderRepository) Get(orgID uuid.UUID) (*domain.OrganizationEmailSender, error) {
+	sender := &domain.OrganizationEmailSender{}
+	var replyTo sql.NullString
+	var updatedBy uuid.NullUUID
+	err := r.db.QueryRow(`
+		SELECT organization_id, from_address, from_name, reply_to, updated_by, updated_at
+		FROM organization_email_senders
+		WHERE organization_id = $1
+	`, orgID).Scan(&sender.OrganizationID, &sender.FromAddress, &sender.FromName, &replyTo, &updatedBy, &sender.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrEmailSenderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	sender.ReplyTo = replyTo.String
+	sender.UpdatedBy = updatedBy.UUID
+	return sender, nil
+}
+
+func (r *OrganizationEmailSenderRepository) Upsert(sender *domain.OrganizationEmailSender) error {
+	_, err := r.db.Exec(`
+		INSERT INTO organization_email_senders (organization_id, from_address, from_name, reply_to, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (organization_id) DO UPDATE
+		SET from_address = EXCLUDED.from_address, from_name = EXCLUDED.from_name, reply_to = EXCLUDED.reply_to,
+			updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`, sender.OrganizationID, sender.FromAddress, sender.FromName, nullString(sender.ReplyTo),
+		uuid.NullUUID{UUID: sender.UpdatedBy, Valid: sender.UpdatedBy != uuid.Nil}, sender.UpdatedAt)
+	return err
+}
+
+func (r *OrganizationEmailSenderRepository) Delete(orgID uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM organization_email_senders WHERE organization_id = $1`, orgID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrEmailSenderNotFound
+	}
+	return nil
+}
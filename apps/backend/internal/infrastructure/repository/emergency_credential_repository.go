@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// EmergencyCredentialRepository implements domain.EmergencyCredentialRepository
+type EmergencyCredentialRepository struct {
+	db *sql.DB
+}
+
+// NewEmergencyCredentialRepository creates a new emergency credential repository
+func NewEmergencyCredentialRepository(db *sql.DB) *EmergencyCredentialRepository {
+	return &EmergencyCredentialRepository{db: db}
+}
+
+const emergencyCredentialColumns = `id, organization_id, agent_id, user_id, secret_hash, sealing_public_key, key_fingerprint,
+	status, expires_at, activated_at, activated_ip, activation_reason, access_expires_at, revoked_at, revoke_reason,
+	rotated_from, created_by, created_at`
+
+// Create stores a newly generated emergency credential
+func (r *EmergencyCredentialRepository) Create(credential *domain.EmergencyCredential) error {
+	if credential.ID == uuid.Nil {
+		credential.ID = uuid.New()
+	}
+	if credential.CreatedAt.IsZero() {
+		credential.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO emergency_credentials (`+emergencyCredentialColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+	`,
+		credential.ID,
+		credential.OrganizationID,
+		credential.AgentID,
+		credential.UserID,
+		credential.SecretHash,
+		credential.SealingPublicKey,
+		credential.KeyFingerprint,
+		credential.Status,
+		credential.ExpiresAt,
+		credential.ActivatedAt,
+		credential.ActivatedIP,
+		credential.ActivationReason,
+		credential.AccessExpiresAt,
+		credential.RevokedAt,
+		credential.RevokeReason,
+		credential.RotatedFrom,
+		credential.CreatedBy,
+		credential.CreatedAt,
+	)
+	return err
+}
+
+// GetByID retrieves an emergency credential by ID
+func (r *EmergencyCredentialRepository) GetByID(id uuid.UUID) (*domain.EmergencyCredential, error) {
+	query := `SELECT ` + emergencyCredentialColumns + ` FROM emergency_credentials WHERE id = $1`
+
+	credential, err := scanEmergencyCredential(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("emergency credential not found")
+	}
+	return credential, err
+}
+
+// GetBySecretHash retrieves an emergency credential by the hash of its secret
+func (r *EmergencyCredentialRepository) GetBySecretHash(secretHash string) (*domain.EmergencyCredential, error) {
+	query := `SELECT ` + emergencyCredentialColumns + ` FROM emergency_credentials WHERE secret_hash = $1`
+
+	credential, err := scanEmergencyCredential(r.db.QueryRow(query, secretHash))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("emergency credential not found")
+	}
+	return credential, err
+}
+
+// GetByAgent retrieves all emergency credentials of an agent, newest first
+func (r *EmergencyCredentialRepository) GetByAgent(agentID uuid.UUID) ([]*domain.EmergencyCredential, error) {
+	rows, err := r.db.Query(`
+		SELECT `+emergencyCredentialColumns+`
+		FROM emergency_credentials
+		WHERE agent_id = $1
+		ORDER BY created_at DESC
+	`, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var credentials []*domain.EmergencyCredential
+	for rows.Next() {
+		credential, err := scanEmergencyCredential(rows)
+		if err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, credential)
+	}
+	return credentials, rows.Err()
+}
+
+// Activate marks an active, unexpired credential as activated in a single conditional update
+func (r *EmergencyCredentialRepository) Activate(id uuid.UUID, at, accessExpiresAt time.Time, ipAddress, reason string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE emergency_credentials
+		SET status = 'activated', activated_at = $1, access_expires_at = $2, activated_ip = $3, activation_reason = $4
+		WHERE id = $5 AND status = 'active' AND expires_at > $1
+	`, at, accessExpiresAt, ipAddress, reason, id)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// Revoke revokes an active credential; used and already revoked credentials are left untouched
+func (r *EmergencyCredentialRepository) Revoke(id uuid.UUID, reason string) error {
+	_, err := r.db.Exec(`
+		UPDATE emergency_credentials
+		SET status = 'revoked', revoked_at = $1, revoke_reason = $2
+		WHERE id = $3 AND status = 'active'
+	`, time.Now().UTC(), reason, id)
+	return err
+}
+
+func scanEmergencyCredential(row interface{ Scan(...interface{}) error }) (*domain.EmergencyCredential, error) {
+	credential := &domain.EmergencyCredential{}
+	var activatedAt, accessExpiresAt, revokedAt sql.NullTime
+	var activatedIP, activationReason, revokeReason sql.NullString
+	var rotatedFrom uuid.NullUUID
+
+	err := row.Scan(
+		&credential.ID,
+		&credential.OrganizationID,
+		&credential.AgentID,
+		&credential.UserID,
+		&credential.SecretHash,
+		&credential.SealingPublicKey,
+		&credential.KeyFingerprint,
+		&credential.Status,
+		&credential.ExpiresAt,
+		&activatedAt,
+		&activatedIP,
+		&activationReason,
+		&accessExpiresAt,
+		&revokedAt,
+		&revokeReason,
+		&rotatedFrom,
+		&credential.CreatedBy,
+		&credential.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if activatedAt.Valid {
+		credential.ActivatedAt = &activatedAt.Time
+	}
+	if activatedIP.Valid {
+		credential.ActivatedIP = &activatedIP.String
+	}
+	if activationReason.Valid {
+		credential.ActivationReason = &activationReason.String
+	}
+	if accessExpiresAt.Valid {
+		credential.AccessExpiresAt = &accessExpiresAt.Time
+	}
+	if revokedAt.Valid {
+		credential.RevokedAt = &revokedAt.Time
+	}
+	if revokeReason.Valid {
+		credential.RevokeReason = &revokeReason.String
+	}
+	if rotatedFrom.Valid {
+		credential.RotatedFrom = &rotatedFrom.UUID
+	}
+
+	return credential, nil
+}
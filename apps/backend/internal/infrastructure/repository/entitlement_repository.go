@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// EntitlementRepository implements domain.EntitlementRepository
+type EntitlementRepository struct {
+	db *sql.DB
+}
+
+// NewEntitlementRepository creates a new entitlement repository
+func NewEntitlementRepository(db *sql.DB) *EntitlementRepository {
+	return &EntitlementRepository{db: db}
+}
+
+// entitlementColumns is the select list shared by both review queries; g is the grant source
+const entitlementColumns = `
+	a.id, a.name, COALESCE(a.display_name, ''), a.status, COALESCE(a.trust_score, 0),
+	owner.id, COALESCE(owner.name, ''), COALESCE(owner.email, ''),
+	g.grant_type, g.granted_at, g.granted_by, COALESCE(granter.name, ''), g.reference_id`
+
+// GetMCPServerEntitlements lists the agents authorized to talk to an MCP server: agents declaring it in
+// talks_to (by ID or name) and agents with an active connection (registered, detected or attested)
+func (r *EntitlementRepository) GetMCPServerEntitlements(orgID uuid.UUID, mcpServer *domain.MCPServer) ([]*domain.Entitlement, error) {
+	query := `
+		WITH g AS (
+			SELECT a.id AS agent_id, 'registration' AS grant_type, a.created_at AS granted_at,
+				a.created_by AS granted_by, NULL::uuid AS reference_id
+			FROM agents a
+			WHERE a.organization_id = $1
+			  AND (a.talks_to @> jsonb_build_array($3::text) OR a.talks_to @> jsonb_build_array($4::text))
+			UNION ALL
+			SELECT c.agent_id,
+				CASE c.connection_type
+					WHEN 'attested' THEN 'attestation'
+					WHEN 'auto_detected' THEN 'auto_detected'
+					ELSE 'registration'
+				END,
+				c.first_connected_at, NULL::uuid, c.id
+			FROM agent_mcp_connections c
+			WHERE c.mcp_server_id = $2 AND c.is_active = true
+		)
+		SELECT ` + entitlementColumns + `,
+			GREATEST(
+				conn.last_attested_at,
+				(SELECT MAX(ma.verified_at) FROM mcp_attestations ma
+				 WHERE ma.agent_id = a.id AND ma.mcp_server_id = $2)
+			) AS last_used_at
+		FROM g
+		JOIN agents a ON a.id = g.agent_id
+		LEFT JOIN users owner ON owner.id = a.created_by
+		LEFT JOIN users granter ON granter.id = g.granted_by
+		LEFT JOIN agent_mcp_connections conn ON conn.agent_id = a.id AND conn.mcp_server_id = $2
+		WHERE a.organization_id = $1 AND a.status <> 'revoked'
+		ORDER BY a.name, g.granted_at
+	`
+
+	rows, err := r.db.Query(query, orgID, mcpServer.ID, mcpServer.ID.String(), mcpServer.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEntitlements(rows)
+}
+
+// GetCapabilityEntitlements lists the agents holding an active grant of a capability. Last use is the
+// most recent allowed verify-action audit entry for that action type.
+func (r *EntitlementRepository) GetCapabilityEntitlements(orgID uuid.UUID, capabilityType string) ([]*domain.Entitlement, error) {
+	query := `
+		SELECT ` + entitlementColumns + `,
+			(SELECT MAX(al.timestamp) FROM audit_logs al
+			 WHERE al.resource_id = a.id AND al.resource_type = 'agent_action' AND al.action = 'verify'
+			   AND al.metadata->>'action_type' = g.capability_type
+			   AND al.metadata->>'allowed' = 'true') AS last_used_at
+		FROM (
+			SELECT ac.agent_id, ac.capability_type, ac.granted_at, ac.granted_by, cr.id AS reference_id,
+				CASE
+					WHEN cr.id IS NOT NULL THEN 'capability_request'
+					WHEN ac.granted_by IS NULL THEN 'auto_detected'
+					WHEN ac.granted_by = ag.created_by THEN 'registration'
+					ELSE 'manual_grant'
+				END AS grant_type
+			FROM agent_capabilities ac
+			JOIN agents ag ON ag.id = ac.agent_id
+			LEFT JOIN LATERAL (
+				SELECT r.id FROM capability_requests r
+				WHERE r.agent_id = ac.agent_id AND r.capability_type = ac.capability_type AND r.status = 'approved'
+				ORDER BY r.reviewed_at DESC NULLS LAST
+				LIMIT 1
+			) cr ON true
+			WHERE ac.capability_type = $2 AND ac.revoked_at IS NULL
+				AND (ac.expires_at IS NULL OR ac.expires_at > NOW())
+		) g
+		JOIN agents a ON a.id = g.agent_id
+		LEFT JOIN users owner ON owner.id = a.created_by
+		LEFT JOIN users granter ON granter.id = g.granted_by
+		WHERE a.organization_id = $1 AND a.status <> 'revoked'
+		ORDER BY a.name, g.granted_at
+	`
+
+	rows, err := r.db.Query(query, orgID, capabilityType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEntitlements(rows)
+}
+
+// scanEntitlements reads rows of entitlementColumns followed by last_used_at
+func scanEntitlements(rows *sql.Rows) ([]*domain.Entitlement, error) {
+	var entitlements []*domain.Entitlement
+	for rows.Next() {
+		entitlement := &domain.Entitlement{}
+		var grant domain.EntitlementGrant
+		var ownerID uuid.NullUUID
+		var lastUsed sql.NullTime
+
+		err := rows.Scan(
+			&entitlement.AgentID,
+			&entitlement.AgentName,
+			&entitlement.AgentDisplayName,
+			&entitlement.AgentStatus,
+			&entitlement.TrustScore,
+			&ownerID,
+			&entitlement.OwnerName,
+			&entitlement.OwnerEmail,
+			&grant.GrantType,
+			&grant.GrantedAt,
+			&grant.GrantedBy,
+			&grant.GrantedByName,
+			&grant.ReferenceID,
+			&lastUsed,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if ownerID.Valid {
+			entitlement.OwnerID = &ownerID.UUID
+		}
+		if lastUsed.Valid {
+			entitlement.LastUsedAt = &lastUsed.Time
+		}
+		entitlement.Grants = []domain.EntitlementGrant{grant}
+		entitlements = append(entitlements, entitlement)
+	}
+
+	return entitlements, rows.Err()
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// FeatureFlagRepository implements domain.FeatureFlagRepository
+type FeatureFlagRepository struct {
+	db *sql.DB
+}
+
+// NewFeatureFlagRepository creates a new feature flag repository
+func NewFeatureFlagRepository(db *sql.DB) *FeatureFlagRepository {
+	return &FeatureFlagRepository{db: db}
+}
+
+// ListFlags returns every feature flag by key
+func (r *FeatureFlagRepository) ListFlags() ([]*domain.FeatureFlag, error) {
+	rows, err := r.db.Query(`
+		SELECT key, description, default_enabled, user_opt_in, sdk_visible, created_at, updated_at
+		FROM feature_flags
+		ORDER BY key
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := make([]*domain.FeatureFlag, 0)
+	for rows.Next() {
+		flag := &domain.FeatureFlag{}
+		if err := rows.Scan(&flag.Key, &flag.Description, &flag.DefaultEnabled, &flag.UserOptIn,
+			&flag.SDKVisible, &flag.CreatedAt, &flag.UpdatedAt); err != nil {
+			return nil, err
+		}
+		flags = append(flags, flag)
+	}
+	return flags, rows.Err()
+}
+
+// ListOverrides returns the overrides of the organization and of its users
+func (r *FeatureFlagRepository) ListOverrides(orgID uuid.UUID) ([]*domain.FeatureFlagOverride, error) {
+	rows, err := r.db.Query(`
+		SELECT flag_key, organization_id, user_id, enabled, updated_by, updated_at
+		FROM feature_flag_overrides
+		WHERE organization_id = $1
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := make([]*domain.FeatureFlagOverride, 0)
+	for rows.Next() {
+		override := &domain.FeatureFlagOverride{}
+		var userID, updatedBy uuid.NullUUID
+		if err := rows.Scan(&override.FlagKey, &override.OrganizationID, &userID, &override.Enabled,
+			&updatedBy, &override.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if userID.Valid {
+			override.UserID = &userID.UUID
+		}
+		if updatedBy.Valid {
+			override.UpdatedBy = &updatedBy.UUID
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, rows.Err()
+}
+
+// SetOverride creates or replaces the override of the organization or user
+func (r *FeatureFlagRepository) SetOverride(override *domain.FeatureFlagOverride) error {
+	// The unique indexes are partial, so the conflict target names the one that applies
+	conflict := `(flag_key, organization_id) WHERE user_id IS NULL`
+	if override.UserID != nil {
+		conflict = `(flag_key, organization_id, user_id) WHERE user_id IS NOT NULL`
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO feature_flag_overrides (flag_key, organization_id, user_id, enabled, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT `+conflict+` DO UPDATE
+		SET enabled = EXCLUDED.enabled, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`, override.FlagKey, override.OrganizationID, override.UserID, override.Enabled, override.UpdatedBy, override.UpdatedAt)
+	return err
+}
+
+// DeleteOverride removes the organization's override, or the user's when userID is set.
+// Removing an override that does not exist is not an error.
+func (r *FeatureFlagRepository) DeleteOverride(flagKey string, orgID uuid.UUID, userID *uuid.UUID) error {
+	if userID == nil {
+		_, err := r.db.Exec(`
+			DELETE FROM feature_flag_overrides
+			WHERE flag_key = $1 AND organization_id = $2 AND user_id IS NULL
+		`, flagKey, orgID)
+		return err
+	}
+	_, err := r.db.Exec(`
+		DELETE FROM feature_flag_overrides
+		WHERE flag_key = $1 AND organization_id = $2 AND user_id = $3
+	`, flagKey, orgID, *userID)
+	return err
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// IdempotencyRepository implements domain.IdempotencyRepository
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+// NewIdempotencyRepository creates a new idempotency repository
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve stores the record unless a live record with the same organization, key and endpoint
+// exists. The conflict update only replaces expired records, so concurrent requests with the
+// same key reserve it once.
+func (r *IdempotencyRepository) Reserve(record *domain.IdempotencyRecord) (bool, error) {
+	query := `
+		INSERT INTO idempotency_records (id, organization_id, idempotency_key, endpoint, request_hash,
+			completed, status_code, content_type, response_body, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, false, 0, '', NULL, $6, $7)
+		ON CONFLICT (organization_id, idempotency_key, endpoint) DO UPDATE SET
+			id = EXCLUDED.id,
+			request_hash = EXCLUDED.request_hash,
+			completed = false,
+			status_code = 0,
+			content_type = '',
+			response_body = NULL,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_records.expires_at <= EXCLUDED.created_at
+		RETURNING id
+	`
+	if record.ID == uuid.Nil {
+		record.ID = uuid.New()
+	}
+
+	var id uuid.UUID
+	err := r.db.QueryRow(query,
+		record.ID,
+		record.OrganizationID,
+		record.Key,
+		record.Endpoint,
+		record.RequestHash,
+		record.CreatedAt,
+		record.ExpiresAt,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Get retrieves the record of an organization's key on an endpoint
+func (r *IdempotencyRepository) Get(orgID uuid.UUID, key, endpoint string) (*domain.IdempotencyRecord, error) {
+	query := `
+		SELECT id, organization_id, idempotency_key, endpoint, request_hash, completed, status_code,
+			content_type, response_body, created_at, expires_at
+		FROM idempotency_records
+		WHERE organization_id = $1 AND idempotency_key = $2 AND endpoint = $3
+	`
+	record := &domain.IdempotencyRecord{}
+	err := r.db.QueryRow(query, orgID, key, endpoint).Scan(
+		&record.ID,
+		&record.OrganizationID,
+		&record.Key,
+		&record.Endpoint,
+		&record.RequestHash,
+		&record.Completed,
+		&record.StatusCode,
+		&record.ContentType,
+		&record.ResponseBody,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrIdempotencyRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Complete stores the response of a reserved record
+func (r *IdempotencyRepository) Complete(record *domain.IdempotencyRecord) error {
+	query := `
+		UPDATE idempotency_records
+		SET completed = true, status_code = $1, content_type = $2, response_body = $3, expires_at = $4
+		WHERE id = $5
+	`
+	result, err := r.db.Exec(query,
+		record.StatusCode,
+		record.ContentType,
+		record.ResponseBody,
+		record.ExpiresAt,
+		record.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrIdempotencyRecordNotFound
+	}
+	return nil
+}
+
+// Delete removes a record, releasing its key
+func (r *IdempotencyRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM idempotency_records WHERE id = $1`, id)
+	return err
+}
+
+// DeleteExpired removes the records that expired before the given time
+func (r *IdempotencyRepository) DeleteExpired(before time.Time) (int, error) {
+	result, err := r.db.Exec(`DELETE FROM idempotency_records WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// identityCacheTimeout bounds each cache call, so a slow cache can't slow a lookup down by more
+const identityCacheTimeout = 100 * time.Millisecond
+
+// recordCache caches the single-record lookups of a repository. Cache failures are logged and the
+// database answers instead, so an unavailable cache never fails a lookup. A nil recordCache
+// caches nothing.
+//
+// Writes through the repository drop the entries they change, so every write to a cached table
+// must go through its repository: a write made elsewhere would only show once the entry expires.
+//
+// The TTL can change while the cache is in use. With a TTL of zero nothing is read or cached,
+// but writes still drop entries, so turning caching back on never serves stale records.
+type recordCache struct {
+	cache domain.IdentityCache
+	ttl   atomic.Int64 // time.Duration
+}
+
+// newRecordCache returns nil, caching nothing, without a cache
+func newRecordCache(cache domain.IdentityCache, ttl time.Duration) *recordCache {
+	if cache == nil {
+		return nil
+	}
+	c := &recordCache{cache: cache}
+	c.setTTL(ttl)
+	return c
+}
+
+// setTTL changes how long new entries are kept; a TTL of zero turns caching off
+func (c *recordCache) setTTL(ttl time.Duration) {
+	if c != nil {
+		c.ttl.Store(int64(ttl))
+	}
+}
+
+func (c *recordCache) enabled() bool {
+	return c != nil && c.ttl.Load() > 0
+}
+
+// get reads the entry under key into dest and reports whether there was one
+func (c *recordCache) get(key string, dest interface{}) bool {
+	if !c.enabled() {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), identityCacheTimeout)
+	defer cancel()
+
+	err := c.cache.Get(ctx, key, dest)
+	if err != nil && !errors.Is(err, domain.ErrCacheMiss) {
+		log.Printf("⚠️  Identity cache read of %s failed: %v", key, err)
+	}
+	return err == nil
+}
+
+func (c *recordCache) set(key string, value interface{}) {
+	ttl := time.Duration(0)
+	if c != nil {
+		ttl = time.Duration(c.ttl.Load())
+	}
+	if ttl <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), identityCacheTimeout)
+	defer cancel()
+
+	if err := c.cache.Set(ctx, key, value, ttl); err != nil {
+		log.Printf("⚠️  Identity cache write of %s failed: %v", key, err)
+	}
+}
+
+// invalidate drops the entries under keys. A failure leaves the entries until they expire.
+func (c *recordCache) invalidate(keys ...string) {
+	if c == nil || len(keys) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), identityCacheTimeout)
+	defer cancel()
+
+	if err := c.cache.Delete(ctx, keys...); err != nil {
+		log.Printf("⚠️  Identity cache invalidation of %v failed, entries stay until they expire: %v", keys, err)
+	}
+}
+
+func agentCacheKey(id uuid.UUID) string {
+	return "agent:" + id.String()
+}
+
+func apiKeyCacheKey(hash string) string {
+	return "api_key:" + hash
+}
+
+func mcpServerCacheKey(id uuid.UUID) string {
+	return "mcp_server:" + id.String()
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// IncidentRepository implements domain.IncidentRepository
+type IncidentRepository struct {
+	db *sql.DB
+}
+
+// NewIncidentRepository creates a new incident repository
+func NewIncidentRepository(db *sql.DB) *IncidentRepository {
+	return &IncidentRepository{db: db}
+}
+
+// CreateComment stores a comment on an incident
+func (r *IncidentRepository) CreateComment(comment *domain.IncidentComment) error {
+	query := `
+		INSERT INTO incident_comments (id, organization_id, incident_id, author_id, body, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	if comment.ID == uuid.Nil {
+		comment.ID = uuid.New()
+	}
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.Exec(query,
+		comment.ID,
+		comment.OrganizationID,
+		comment.IncidentID,
+		comment.AuthorID,
+		comment.Body,
+		comment.CreatedAt,
+	)
+	return err
+}
+
+// ListComments returns the incident's comments, oldest first
+func (r *IncidentRepository) ListComments(incidentID uuid.UUID) ([]*domain.IncidentComment, error) {
+	query := `
+		SELECT id, organization_id, incident_id, author_id, body, created_at
+		FROM incident_comments
+		WHERE incident_id = $1
+		ORDER BY created_at
+	`
+	rows, err := r.db.Query(query, incidentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := make([]*domain.IncidentComment, 0)
+	for rows.Next() {
+		comment := &domain.IncidentComment{}
+		if err := rows.Scan(
+			&comment.ID,
+			&comment.OrganizationID,
+			&comment.IncidentID,
+			&comment.AuthorID,
+			&comment.Body,
+			&comment.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+// CreateLink links a resource to an incident, failing with domain.ErrIncidentLinkExists when it already is
+func (r *IncidentRepository) CreateLink(link *domain.IncidentLink) error {
+	query := `
+		INSERT INTO incident_links (incident_id, organization_id, resource_type, resource_id, summary, linked_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (incident_id, resource_type, resource_id) DO NOTHING
+	`
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now().UTC()
+	}
+
+	result, err := r.db.Exec(query,
+		link.IncidentID,
+		link.OrganizationID,
+		link.ResourceType,
+		link.ResourceID,
+		link.Summary,
+		link.LinkedBy,
+		link.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrIncidentLinkExists
+	}
+	return nil
+}
+
+// DeleteLink unlinks a resource from an incident, failing with domain.ErrIncidentLinkNotFound when it is not linked
+func (r *IncidentRepository) DeleteLink(incidentID uuid.UUID, resourceType domain.IncidentLinkType, resourceID uuid.UUID) error {
+	result, err := r.db.Exec(`
+		DELETE FROM incident_links
+		WHERE incident_id = $1 AND resource_type = $2 AND resource_id = $3
+	`, incidentID, resourceType, resourceID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrIncidentLinkNotFound
+	}
+	return nil
+}
+
+// ListLinks returns the incident's links, oldest first
+func (r *IncidentRepository) ListLinks(incidentID uuid.UUID) ([]*domain.IncidentLink, error) {
+	query := `
+		SELECT incident_id, organization_id, resource_type, resource_id, summary, linked_by, created_at
+		FROM incident_links
+		WHERE incident_id = $1
+		ORDER BY created_at
+	`
+	rows, err := r.db.Query(query, incidentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make([]*domain.IncidentLink, 0)
+	for rows.Next() {
+		link := &domain.IncidentLink{}
+		if err := rows.Scan(
+			&link.IncidentID,
+			&link.OrganizationID,
+			&link.ResourceType,
+			&link.ResourceID,
+			&link.Summary,
+			&link.LinkedBy,
+			&link.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// AppendTimeline records an event on the incident's timeline
+func (r *IncidentRepository) AppendTimeline(event *domain.IncidentTimelineEvent) error {
+	query := `
+		INSERT INTO incident_timeline_events (id, organization_id, incident_id, event_type, actor_id, summary, details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	details := event.Details
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query,
+		event.ID,
+		event.OrganizationID,
+		event.IncidentID,
+		event.EventType,
+		event.ActorID,
+		event.Summary,
+		detailsJSON,
+		event.CreatedAt,
+	)
+	return err
+}
+
+// ListTimeline returns the incident's timeline, oldest first
+func (r *IncidentRepository) ListTimeline(incidentID uuid.UUID) ([]*domain.IncidentTimelineEvent, error) {
+	query := `
+		SELECT id, organization_id, incident_id, event_type, actor_id, summary, details, created_at
+		FROM incident_timeline_events
+		WHERE incident_id = $1
+		ORDER BY created_at, id
+	`
+	rows, err := r.db.Query(query, incidentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*domain.IncidentTimelineEvent, 0)
+	for rows.Next() {
+		event := &domain.IncidentTimelineEvent{}
+		var detailsJSON []byte
+		if err := rows.Scan(
+			&event.ID,
+			&event.OrganizationID,
+			&event.IncidentID,
+			&event.EventType,
+			&event.ActorID,
+			&event.Summary,
+			&detailsJSON,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if len(detailsJSON) > 0 {
+			if err := json.Unmarshal(detailsJSON, &event.Details); err != nil {
+				return nil, err
+			}
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// InvitationRepository implements domain.InvitationRepository
+type InvitationRepository struct {
+	db *sql.DB
+}
+
+// NewInvitationRepository creates a new invitation repository
+func NewInvitationRepository(db *sql.DB) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+// invitationColumns reports pending invitations past their expiry as expired
+const invitationColumns = `id, organization_id, email, role, invited_by,
+	CASE WHEN status = 'pending' AND expires_at <= NOW() THEN 'expired' ELSE status END,
+	expires_at, accepted_by, accepted_at, revoked_by, revoked_at, created_at`
+
+// Create stores a new invitation
+func (r *InvitationRepository) Create(invitation *domain.Invitation) error {
+	if invitation.ID == uuid.Nil {
+		invitation.ID = uuid.New()
+	}
+	if invitation.CreatedAt.IsZero() {
+		invitation.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO invitations (id, organization_id, email, role, invited_by, status, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.Exec(query,
+		invitation.ID,
+		invitation.OrganizationID,
+		invitation.Email,
+		invitation.Role,
+		invitation.InvitedBy,
+		invitation.Status,
+		invitation.ExpiresAt,
+		invitation.CreatedAt,
+	)
+	return err
+}
+
+// GetByID retrieves an invitation by ID
+func (r *InvitationRepository) GetByID(id uuid.UUID) (*domain.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE id = $1`
+	invitation, err := r.scan(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrInvitationNotFound
+	}
+	return invitation, err
+}
+
+// ListByOrganization retrieves an organization's invitations, newest first
+func (r *InvitationRepository) ListByOrganization(orgID uuid.UUID, status domain.InvitationStatus, limit, offset int) ([]*domain.Invitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM invitations
+		WHERE organization_id = $1
+		  AND ($2 = ''
+		       OR ($2 = 'pending' AND status = 'pending' AND expires_at > NOW())
+		       OR ($2 = 'expired' AND status = 'pending' AND expires_at <= NOW())
+		       OR ($2 NOT IN ('pending', 'expired') AND status = $2))
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+	return r.list(query, orgID, status, limit, offset)
+}
+
+// ListByEmail retrieves the organization's invitations of an address, newest first
+func (r *InvitationRepository) ListByEmail(orgID uuid.UUID, email string) ([]*domain.Invitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM invitations
+		WHERE organization_id = $1 AND LOWER(email) = LOWER($2)
+		ORDER BY created_at DESC
+	`
+	return r.list(query, orgID, email)
+}
+
+// Accept marks a pending invitation accepted unless it had expired
+func (r *InvitationRepository) Accept(id, userID uuid.UUID, at time.Time) (bool, error) {
+	query := `
+		UPDATE invitations
+		SET status = 'accepted', accepted_by = $1, accepted_at = $2
+		WHERE id = $3 AND status = 'pending' AND expires_at > $2
+	`
+	return r.exec(query, userID, at, id)
+}
+
+// Revoke withdraws a pending invitation
+func (r *InvitationRepository) Revoke(id, revokedBy uuid.UUID, at time.Time) (bool, error) {
+	query := `
+		UPDATE invitations
+		SET status = 'revoked', revoked_by = $1, revoked_at = $2
+		WHERE id = $3 AND status = 'pending'
+	`
+	return r.exec(query, revokedBy, at, id)
+}
+
+func (r *InvitationRepository) exec(query string, args ...interface{}) (bool, error) {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *InvitationRepository) list(query string, args ...interface{}) ([]*domain.Invitation, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := make([]*domain.Invitation, 0)
+	for rows.Next() {
+		invitation, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+	return invitations, rows.Err()
+}
+
+func (r *InvitationRepository) scan(row interface{ Scan(...interface{}) error }) (*domain.Invitation, error) {
+	invitation := &domain.Invitation{}
+	var invitedBy uuid.NullUUID
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.OrganizationID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitedBy,
+		&invitation.Status,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedBy,
+		&invitation.AcceptedAt,
+		&invitation.RevokedBy,
+		&invitation.RevokedAt,
+		&invitation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	invitation.InvitedBy = invitedBy.UUID
+	return invitation, nil
+}
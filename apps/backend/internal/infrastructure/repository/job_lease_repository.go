@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// JobLeaseRepository implements domain.JobLeaseRepository
+type JobLeaseRepository struct {
+	db *sql.DB
+}
+
+// NewJobLeaseRepository creates a new job lease repository
+func NewJobLeaseRepository(db *sql.DB) *JobLeaseRepository {
+	return &JobLeaseRepository{db: db}
+}
+
+// Acquire takes or renews the lease in a single conditional upsert. Expiry is computed with
+// the database clock so instances with skewed clocks agree on when a lease has expired.
+func (r *JobLeaseRepository) Acquire(name, holderID string, ttl time.Duration) (bool, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO job_leases (name, holder_id, acquired_at, expires_at)
+		VALUES ($1, $2, NOW(), NOW() + make_interval(secs => $3))
+		ON CONFLICT (name) DO UPDATE
+		SET holder_id = EXCLUDED.holder_id,
+			acquired_at = CASE WHEN job_leases.holder_id = EXCLUDED.holder_id
+				THEN job_leases.acquired_at ELSE EXCLUDED.acquired_at END,
+			expires_at = EXCLUDED.expires_at
+		WHERE job_leases.holder_id = EXCLUDED.holder_id OR job_leases.expires_at < NOW()
+	`, name, holderID, ttl.Seconds())
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// Release expires the lease so another instance can take it over without waiting
+func (r *JobLeaseRepository) Release(name, holderID string) error {
+	_, err := r.db.Exec(`
+		UPDATE job_leases SET expires_at = NOW()
+		WHERE name = $1 AND holder_id = $2
+	`, name, holderID)
+	return err
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// LoginAttemptRepository implements domain.LoginAttemptRepository
+type LoginAttemptRepository struct {
+	db *sql.DB
+}
+
+// NewLoginAttemptRepository creates a new login attempt repository
+func NewLoginAttemptRepository(db *sql.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+const loginAttemptColumns = `id, email, ip_address, user_id, organization_id, outcome,
+	account_locked_until, address_locked_until, created_at`
+
+func (r *LoginAttemptRepository) Record(attempt *domain.LoginAttempt) error {
+	if attempt.ID == uuid.Nil {
+		attempt.ID = uuid.New()
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO login_attempts (id, email, ip_address, user_id, organization_id, outcome,
+			account_locked_until, address_locked_until, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, attempt.ID, attempt.Email, attempt.IPAddress, attempt.UserID, attempt.OrganizationID, attempt.Outcome,
+		attempt.AccountLockedUntil, attempt.AddressLockedUntil, attempt.CreatedAt)
+	return err
+}
+
+func (r *LoginAttemptRepository) EmailFailures(email string, since time.Time) (*domain.LoginFailures, error) {
+	return r.failures(`
+		WITH cleared AS (
+			SELECT MAX(created_at) AS at FROM login_attempts
+			WHERE email = $1 AND outcome IN ('succeeded', 'unlocked')
+		)
+		SELECT
+			COUNT(*) FILTER (WHERE a.created_at >= $2),
+			COUNT(DISTINCT a.email) FILTER (WHERE a.created_at >= $2),
+			MAX(a.created_at) FILTER (WHERE a.created_at >= $2),
+			MAX(a.account_locked_until)
+		FROM login_attempts a, cleared
+		WHERE a.email = $1 AND a.outcome = 'failed' AND (cleared.at IS NULL OR a.created_at > cleared.at)
+	`, email, since)
+}
+
+func (r *LoginAttemptRepository) IPFailures(ipAddress string, since time.Time) (*domain.LoginFailures, error) {
+	return r.failures(`
+		SELECT
+			COUNT(*) FILTER (WHERE created_at >= $2),
+			COUNT(DISTINCT email) FILTER (WHERE created_at >= $2),
+			MAX(created_at) FILTER (WHERE created_at >= $2),
+			MAX(address_locked_until)
+		FROM login_attempts
+		WHERE ip_address = $1 AND outcome = 'failed'
+	`, ipAddress, since)
+}
+
+func (r *LoginAttemptRepository) failures(query string, args ...interface{}) (*domain.LoginFailures, error) {
+	failures := &domain.LoginFailures{}
+	var lastFailedAt, lockedUntil sql.NullTime
+	if err := r.db.QueryRow(query, args...).Scan(&failures.Count, &failures.Emails, &lastFailedAt, &lockedUntil); err != nil {
+		return nil, err
+	}
+	if lastFailedAt.Valid {
+		failures.LastFailedAt = &lastFailedAt.Time
+	}
+	if lockedUntil.Valid {
+		failures.LockedUntil = &lockedUntil.Time
+	}
+	return failures, nil
+}
+
+func (r *LoginAttemptRepository) TargetedOrganizations(ipAddress string, since time.Time) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT organization_id FROM login_attempts
+		WHERE ip_address = $1 AND outcome = 'failed' AND created_at >= $2 AND organization_id IS NOT NULL
+	`, ipAddress, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orgIDs []uuid.UUID
+	for rows.Next() {
+		var orgID uuid.UUID
+		if err := rows.Scan(&orgID); err != nil {
+			return nil, err
+		}
+		orgIDs = append(orgIDs, orgID)
+	}
+	return orgIDs, rows.Err()
+}
+
+func (r *LoginAttemptRepository) List(filter domain.LoginAttemptFilter) ([]*domain.LoginAttempt, int, error) {
+	where := `WHERE organization_id = $1`
+	args := []interface{}{filter.OrganizationID}
+	if filter.Email != "" {
+		args = append(args, filter.Email)
+		where += fmt.Sprintf(` AND email = $%d`, len(args))
+	}
+	if filter.Outcome != "" {
+		args = append(args, filter.Outcome)
+		where += fmt.Sprintf(` AND outcome = $%d`, len(args))
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM login_attempts `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM login_attempts %s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`,
+		loginAttemptColumns, where, len(args)+1, len(args)+2)
+	rows, err := r.db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	attempts := []*domain.LoginAttempt{}
+	for rows.Next() {
+		attempt := &domain.LoginAttempt{}
+		var userID, orgID uuid.NullUUID
+		var accountLockedUntil, addressLockedUntil sql.NullTime
+		if err := rows.Scan(
+			&attempt.ID,
+			&attempt.Email,
+			&attempt.IPAddress,
+			&userID,
+			&orgID,
+			&attempt.Outcome,
+			&accountLockedUntil,
+			&addressLockedUntil,
+			&attempt.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		if userID.Valid {
+			attempt.UserID = &userID.UUID
+		}
+		if orgID.Valid {
+			attempt.OrganizationID = &orgID.UUID
+		}
+		if accountLockedUntil.Valid {
+			attempt.AccountLockedUntil = &accountLockedUntil.Time
+		}
+		if addressLockedUntil.Valid {
+			attempt.AddressLockedUntil = &addressLockedUntil.Time
+		}
+		attempts = append(attempts, attempt)
+	}
+	return attempts, total, rows.Err()
+}
+
+func (r *LoginAttemptRepository) DeleteBefore(before time.Time) (int, error) {
+	result, err := r.db.Exec(`DELETE FROM login_attempts WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
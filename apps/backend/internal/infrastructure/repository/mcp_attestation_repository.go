@@ -24,8 +24,9 @@ func (r *MCPAttestationRepository) CreateAttestation(attestation *domain.MCPAtte
 	query := `
 		INSERT INTO mcp_attestations (
 			id, mcp_server_id, agent_id, attestation_data, signature,
-			signature_verified, verified_at, expires_at, is_valid, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			signature_verified, verified_at, expires_at, is_valid, created_at,
+			agent_verified_only, capability_discrepancy
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, created_at
 	`
 
@@ -34,6 +35,12 @@ func (r *MCPAttestationRepository) CreateAttestation(attestation *domain.MCPAtte
 	if err != nil {
 		return fmt.Errorf("failed to marshal attestation data: %w", err)
 	}
+	var discrepancyJSON []byte
+	if attestation.CapabilityDiscrepancy != nil {
+		if discrepancyJSON, err = json.Marshal(attestation.CapabilityDiscrepancy); err != nil {
+			return fmt.Errorf("failed to marshal capability discrepancy: %w", err)
+		}
+	}
 
 	err = r.db.QueryRow(
 		query,
@@ -47,6 +54,8 @@ func (r *MCPAttestationRepository) CreateAttestation(attestation *domain.MCPAtte
 		attestation.ExpiresAt,
 		attestation.IsValid,
 		time.Now().UTC(),
+		attestation.AgentVerifiedOnly,
+		discrepancyJSON,
 	).Scan(&attestation.ID, &attestation.CreatedAt)
 
 	if err != nil {
@@ -60,13 +69,14 @@ func (r *MCPAttestationRepository) GetAttestationByID(id uuid.UUID) (*domain.MCP
 	query := `
 		SELECT
 			id, mcp_server_id, agent_id, attestation_data, signature,
-			signature_verified, verified_at, expires_at, is_valid, created_at
+			signature_verified, verified_at, expires_at, is_valid, created_at,
+			agent_verified_only, capability_discrepancy
 		FROM mcp_attestations
 		WHERE id = $1
 	`
 
 	attestation := &domain.MCPAttestation{}
-	var attestationJSON []byte
+	var attestationJSON, discrepancyJSON []byte
 
 	err := r.db.QueryRow(query, id).Scan(
 		&attestation.ID,
@@ -79,6 +89,8 @@ func (r *MCPAttestationRepository) GetAttestationByID(id uuid.UUID) (*domain.MCP
 		&attestation.ExpiresAt,
 		&attestation.IsValid,
 		&attestation.CreatedAt,
+		&attestation.AgentVerifiedOnly,
+		&discrepancyJSON,
 	)
 
 	if err == sql.ErrNoRows {
@@ -92,6 +104,9 @@ func (r *MCPAttestationRepository) GetAttestationByID(id uuid.UUID) (*domain.MCP
 	if err := json.Unmarshal(attestationJSON, &attestation.AttestationData); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal attestation data: %w", err)
 	}
+	if err := unmarshalCapabilityDiscrepancy(attestation, discrepancyJSON); err != nil {
+		return nil, err
+	}
 
 	return attestation, nil
 }
@@ -101,6 +116,7 @@ func (r *MCPAttestationRepository) GetAttestationsByMCP(mcpServerID uuid.UUID) (
 		SELECT
 			a.id, a.mcp_server_id, a.agent_id, a.attestation_data, a.signature,
 			a.signature_verified, a.verified_at, a.expires_at, a.is_valid, a.created_at,
+			a.agent_verified_only, a.capability_discrepancy,
 			ag.name AS agent_name,
 			ag.trust_score AS agent_trust_score
 		FROM mcp_attestations a
@@ -118,7 +134,7 @@ func (r *MCPAttestationRepository) GetAttestationsByMCP(mcpServerID uuid.UUID) (
 	var attestations []*domain.MCPAttestation
 	for rows.Next() {
 		attestation := &domain.MCPAttestation{}
-		var attestationJSON []byte
+		var attestationJSON, discrepancyJSON []byte
 		var agentName sql.NullString
 		var agentTrustScore sql.NullFloat64
 
@@ -133,6 +149,8 @@ func (r *MCPAttestationRepository) GetAttestationsByMCP(mcpServerID uuid.UUID) (
 			&attestation.ExpiresAt,
 			&attestation.IsValid,
 			&attestation.CreatedAt,
+			&attestation.AgentVerifiedOnly,
+			&discrepancyJSON,
 			&agentName,
 			&agentTrustScore,
 		)
@@ -152,6 +170,9 @@ func (r *MCPAttestationRepository) GetAttestationsByMCP(mcpServerID uuid.UUID) (
 		if err := json.Unmarshal(attestationJSON, &attestation.AttestationData); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal attestation data: %w", err)
 		}
+		if err := unmarshalCapabilityDiscrepancy(attestation, discrepancyJSON); err != nil {
+			return nil, err
+		}
 
 		attestations = append(attestations, attestation)
 	}
@@ -164,6 +185,7 @@ func (r *MCPAttestationRepository) GetValidAttestationsByMCP(mcpServerID uuid.UU
 		SELECT
 			a.id, a.mcp_server_id, a.agent_id, a.attestation_data, a.signature,
 			a.signature_verified, a.verified_at, a.expires_at, a.is_valid, a.created_at,
+			a.agent_verified_only, a.capability_discrepancy,
 			ag.name AS agent_name,
 			ag.trust_score AS agent_trust_score
 		FROM mcp_attestations a
@@ -183,7 +205,7 @@ func (r *MCPAttestationRepository) GetValidAttestationsByMCP(mcpServerID uuid.UU
 	var attestations []*domain.MCPAttestation
 	for rows.Next() {
 		attestation := &domain.MCPAttestation{}
-		var attestationJSON []byte
+		var attestationJSON, discrepancyJSON []byte
 		var agentName sql.NullString
 		var agentTrustScore sql.NullFloat64
 
@@ -198,6 +220,8 @@ func (r *MCPAttestationRepository) GetValidAttestationsByMCP(mcpServerID uuid.UU
 			&attestation.ExpiresAt,
 			&attestation.IsValid,
 			&attestation.CreatedAt,
+			&attestation.AgentVerifiedOnly,
+			&discrepancyJSON,
 			&agentName,
 			&agentTrustScore,
 		)
@@ -217,6 +241,9 @@ func (r *MCPAttestationRepository) GetValidAttestationsByMCP(mcpServerID uuid.UU
 		if err := json.Unmarshal(attestationJSON, &attestation.AttestationData); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal attestation data: %w", err)
 		}
+		if err := unmarshalCapabilityDiscrepancy(attestation, discrepancyJSON); err != nil {
+			return nil, err
+		}
 
 		attestations = append(attestations, attestation)
 	}
@@ -228,7 +255,8 @@ func (r *MCPAttestationRepository) GetAttestationsByAgent(agentID uuid.UUID) ([]
 	query := `
 		SELECT
 			a.id, a.mcp_server_id, a.agent_id, a.attestation_data, a.signature,
-			a.signature_verified, a.verified_at, a.expires_at, a.is_valid, a.created_at
+			a.signature_verified, a.verified_at, a.expires_at, a.is_valid, a.created_at,
+			a.agent_verified_only, a.capability_discrepancy
 		FROM mcp_attestations a
 		WHERE a.agent_id = $1
 		ORDER BY a.verified_at DESC
@@ -243,7 +271,7 @@ func (r *MCPAttestationRepository) GetAttestationsByAgent(agentID uuid.UUID) ([]
 	var attestations []*domain.MCPAttestation
 	for rows.Next() {
 		attestation := &domain.MCPAttestation{}
-		var attestationJSON []byte
+		var attestationJSON, discrepancyJSON []byte
 
 		err := rows.Scan(
 			&attestation.ID,
@@ -256,6 +284,8 @@ func (r *MCPAttestationRepository) GetAttestationsByAgent(agentID uuid.UUID) ([]
 			&attestation.ExpiresAt,
 			&attestation.IsValid,
 			&attestation.CreatedAt,
+			&attestation.AgentVerifiedOnly,
+			&discrepancyJSON,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan attestation: %w", err)
@@ -265,6 +295,9 @@ func (r *MCPAttestationRepository) GetAttestationsByAgent(agentID uuid.UUID) ([]
 		if err := json.Unmarshal(attestationJSON, &attestation.AttestationData); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal attestation data: %w", err)
 		}
+		if err := unmarshalCapabilityDiscrepancy(attestation, discrepancyJSON); err != nil {
+			return nil, err
+		}
 
 		attestations = append(attestations, attestation)
 	}
@@ -292,19 +325,57 @@ func (r *MCPAttestationRepository) InvalidateAttestation(id uuid.UUID) error {
 	return nil
 }
 
-func (r *MCPAttestationRepository) InvalidateExpiredAttestations() error {
+// InvalidateExpiredAttestations invalidates valid attestations past their expiry and returns them
+func (r *MCPAttestationRepository) InvalidateExpiredAttestations() ([]*domain.MCPAttestation, error) {
 	query := `
 		UPDATE mcp_attestations
 		SET is_valid = false
 		WHERE expires_at < NOW() AND is_valid = true
+		RETURNING id, mcp_server_id, agent_id, attestation_data, signature,
+			signature_verified, verified_at, expires_at, is_valid, created_at, agent_verified_only,
+			capability_discrepancy
 	`
 
-	_, err := r.db.Exec(query)
+	rows, err := r.db.Query(query)
 	if err != nil {
-		return fmt.Errorf("failed to invalidate expired attestations: %w", err)
+		return nil, fmt.Errorf("failed to invalidate expired attestations: %w", err)
 	}
+	defer rows.Close()
 
-	return nil
+	var attestations []*domain.MCPAttestation
+	for rows.Next() {
+		attestation := &domain.MCPAttestation{}
+		var attestationJSON, discrepancyJSON []byte
+
+		err := rows.Scan(
+			&attestation.ID,
+			&attestation.MCPServerID,
+			&attestation.AgentID,
+			&attestationJSON,
+			&attestation.Signature,
+			&attestation.SignatureVerified,
+			&attestation.VerifiedAt,
+			&attestation.ExpiresAt,
+			&attestation.IsValid,
+			&attestation.CreatedAt,
+			&attestation.AgentVerifiedOnly,
+			&discrepancyJSON,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan expired attestation: %w", err)
+		}
+
+		if err := json.Unmarshal(attestationJSON, &attestation.AttestationData); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal attestation data: %w", err)
+		}
+		if err := unmarshalCapabilityDiscrepancy(attestation, discrepancyJSON); err != nil {
+			return nil, err
+		}
+
+		attestations = append(attestations, attestation)
+	}
+
+	return attestations, rows.Err()
 }
 
 // ==================== Connection Operations ====================
@@ -550,41 +621,177 @@ func (r *MCPAttestationRepository) DeleteConnection(id uuid.UUID) error {
 	return nil
 }
 
-// ==================== Confidence Score Operations ====================
+// ==================== Network Relay Operations ====================
+
+const mcpNetworkRelayColumns = `
+	id, organization_id, mcp_server_id, agent_id, network_zone, description,
+	is_active, last_attested_at, created_by, created_at, updated_at
+`
+
+func scanMCPNetworkRelay(scanner rowScanner) (*domain.MCPNetworkRelay, error) {
+	relay := &domain.MCPNetworkRelay{}
+	err := scanner.Scan(
+		&relay.ID,
+		&relay.OrganizationID,
+		&relay.MCPServerID,
+		&relay.AgentID,
+		&relay.NetworkZone,
+		&relay.Description,
+		&relay.IsActive,
+		&relay.LastAttestedAt,
+		&relay.CreatedBy,
+		&relay.CreatedAt,
+		&relay.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return relay, nil
+}
 
-func (r *MCPAttestationRepository) UpdateMCPConfidenceScore(
-	mcpServerID uuid.UUID,
-	score float64,
-	attestationCount int,
-	lastAttestedAt time.Time,
-) error {
+func (r *MCPAttestationRepository) CreateRelay(relay *domain.MCPNetworkRelay) error {
 	query := `
-		UPDATE mcp_servers
-		SET
-			confidence_score = $1,
-			attestation_count = $2,
-			last_attested_at = $3,
-			updated_at = $4
-		WHERE id = $5
+		INSERT INTO mcp_network_relays (
+			id, organization_id, mcp_server_id, agent_id, network_zone, description,
+			is_active, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (mcp_server_id, agent_id) DO UPDATE SET
+			network_zone = EXCLUDED.network_zone,
+			description = EXCLUDED.description,
+			is_active = EXCLUDED.is_active,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
 	`
 
-	result, err := r.db.Exec(
+	now := time.Now().UTC()
+	err := r.db.QueryRow(
 		query,
-		score,
-		attestationCount,
-		lastAttestedAt,
-		time.Now().UTC(),
-		mcpServerID,
-	)
+		relay.ID,
+		relay.OrganizationID,
+		relay.MCPServerID,
+		relay.AgentID,
+		relay.NetworkZone,
+		relay.Description,
+		relay.IsActive,
+		relay.CreatedBy,
+		now,
+		now,
+	).Scan(&relay.ID, &relay.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create network relay: %w", err)
+	}
 
+	relay.UpdatedAt = now
+	return nil
+}
+
+func (r *MCPAttestationRepository) GetRelayByID(id uuid.UUID) (*domain.MCPNetworkRelay, error) {
+	query := `SELECT ` + mcpNetworkRelayColumns + ` FROM mcp_network_relays WHERE id = $1`
+
+	relay, err := scanMCPNetworkRelay(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("network relay not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network relay: %w", err)
+	}
+
+	return relay, nil
+}
+
+func (r *MCPAttestationRepository) GetRelaysByMCP(mcpServerID uuid.UUID) ([]*domain.MCPNetworkRelay, error) {
+	query := `
+		SELECT ` + mcpNetworkRelayColumns + `
+		FROM mcp_network_relays
+		WHERE mcp_server_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, mcpServerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network relays: %w", err)
+	}
+	defer rows.Close()
+
+	relays := []*domain.MCPNetworkRelay{}
+	for rows.Next() {
+		relay, err := scanMCPNetworkRelay(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan network relay: %w", err)
+		}
+		relays = append(relays, relay)
+	}
+
+	return relays, rows.Err()
+}
+
+// GetActiveRelay returns the active relay record for an agent and MCP server, or nil if none exists
+func (r *MCPAttestationRepository) GetActiveRelay(mcpServerID, agentID uuid.UUID) (*domain.MCPNetworkRelay, error) {
+	query := `
+		SELECT ` + mcpNetworkRelayColumns + `
+		FROM mcp_network_relays
+		WHERE mcp_server_id = $1 AND agent_id = $2 AND is_active = true
+	`
+
+	relay, err := scanMCPNetworkRelay(r.db.QueryRow(query, mcpServerID, agentID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to update confidence score: %w", err)
+		return nil, fmt.Errorf("failed to get network relay: %w", err)
+	}
+
+	return relay, nil
+}
+
+// HasActiveRelays reports whether the MCP server is reached through at least one private network relay
+func (r *MCPAttestationRepository) HasActiveRelays(mcpServerID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM mcp_network_relays WHERE mcp_server_id = $1 AND is_active = true)`
+
+	var exists bool
+	if err := r.db.QueryRow(query, mcpServerID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check network relays: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *MCPAttestationRepository) TouchRelay(id uuid.UUID, attestedAt time.Time) error {
+	query := `
+		UPDATE mcp_network_relays
+		SET last_attested_at = $1, updated_at = $2
+		WHERE id = $3
+	`
+
+	if _, err := r.db.Exec(query, attestedAt, time.Now().UTC(), id); err != nil {
+		return fmt.Errorf("failed to update network relay: %w", err)
+	}
+
+	return nil
+}
+
+func (r *MCPAttestationRepository) DeleteRelay(id uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM mcp_network_relays WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete network relay: %w", err)
 	}
 
 	rows, _ := result.RowsAffected()
 	if rows == 0 {
-		return fmt.Errorf("mcp server not found")
+		return fmt.Errorf("network relay not found")
 	}
 
 	return nil
 }
+
+// unmarshalCapabilityDiscrepancy reads the capability discrepancy recorded on an attestation, if any
+func unmarshalCapabilityDiscrepancy(attestation *domain.MCPAttestation, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	attestation.CapabilityDiscrepancy = &domain.CapabilityDiscrepancy{}
+	if err := json.Unmarshal(data, attestation.CapabilityDiscrepancy); err != nil {
+		return fmt.Errorf("failed to unmarshal capability discrepancy: %w", err)
+	}
+	return nil
+}
@@ -17,13 +17,21 @@ func NewMCPServerCapabilityRepository(db *sql.DB) *MCPServerCapabilityRepository
 	return &MCPServerCapabilityRepository{db: db}
 }
 
+// Create stores a capability. A capability the server already has (same name and type) is
+// refreshed and reactivated instead, keeping its ID and risk override.
 func (r *MCPServerCapabilityRepository) Create(capability *domain.MCPServerCapability) error {
 	query := `
 		INSERT INTO mcp_server_capabilities (
 			id, mcp_server_id, name, capability_type, description,
 			capability_schema, detected_at, last_verified_at, is_active,
-			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			created_at, updated_at, risk_override
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (mcp_server_id, name, capability_type) DO UPDATE SET
+			description = EXCLUDED.description,
+			capability_schema = EXCLUDED.capability_schema,
+			last_verified_at = EXCLUDED.detected_at,
+			is_active = EXCLUDED.is_active,
+			updated_at = EXCLUDED.updated_at
 		RETURNING id, created_at, updated_at
 	`
 
@@ -40,6 +48,7 @@ func (r *MCPServerCapabilityRepository) Create(capability *domain.MCPServerCapab
 		capability.IsActive,
 		time.Now().UTC(),
 		time.Now().UTC(),
+		capability.RiskOverride,
 	).Scan(&capability.ID, &capability.CreatedAt, &capability.UpdatedAt)
 
 	if err != nil {
@@ -54,7 +63,7 @@ func (r *MCPServerCapabilityRepository) GetByID(id uuid.UUID) (*domain.MCPServer
 		SELECT
 			id, mcp_server_id, name, capability_type, description,
 			capability_schema, detected_at, last_verified_at, is_active,
-			created_at, updated_at
+			created_at, updated_at, risk_override
 		FROM mcp_server_capabilities
 		WHERE id = $1
 	`
@@ -73,6 +82,7 @@ func (r *MCPServerCapabilityRepository) GetByID(id uuid.UUID) (*domain.MCPServer
 		&capability.IsActive,
 		&capability.CreatedAt,
 		&capability.UpdatedAt,
+		&capability.RiskOverride,
 	)
 
 	if err == sql.ErrNoRows {
@@ -90,7 +100,7 @@ func (r *MCPServerCapabilityRepository) GetByServerID(serverID uuid.UUID) ([]*do
 		SELECT
 			id, mcp_server_id, name, capability_type, description,
 			capability_schema, detected_at, last_verified_at, is_active,
-			created_at, updated_at
+			created_at, updated_at, risk_override
 		FROM mcp_server_capabilities
 		WHERE mcp_server_id = $1 AND is_active = true
 		ORDER BY capability_type, name
@@ -118,6 +128,7 @@ func (r *MCPServerCapabilityRepository) GetByServerID(serverID uuid.UUID) ([]*do
 			&capability.IsActive,
 			&capability.CreatedAt,
 			&capability.UpdatedAt,
+			&capability.RiskOverride,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan mcp server capability: %w", err)
@@ -134,7 +145,7 @@ func (r *MCPServerCapabilityRepository) GetByServerIDAndType(serverID uuid.UUID,
 		SELECT
 			id, mcp_server_id, name, capability_type, description,
 			capability_schema, detected_at, last_verified_at, is_active,
-			created_at, updated_at
+			created_at, updated_at, risk_override
 		FROM mcp_server_capabilities
 		WHERE mcp_server_id = $1 AND capability_type = $2 AND is_active = true
 		ORDER BY name
@@ -162,6 +173,7 @@ func (r *MCPServerCapabilityRepository) GetByServerIDAndType(serverID uuid.UUID,
 			&capability.IsActive,
 			&capability.CreatedAt,
 			&capability.UpdatedAt,
+			&capability.RiskOverride,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan mcp server capability: %w", err)
@@ -182,8 +194,9 @@ func (r *MCPServerCapabilityRepository) Update(capability *domain.MCPServerCapab
 			capability_schema = $3,
 			last_verified_at = $4,
 			is_active = $5,
-			updated_at = $6
-		WHERE id = $7
+			risk_override = $6,
+			updated_at = $7
+		WHERE id = $8
 		RETURNING updated_at
 	`
 
@@ -194,6 +207,7 @@ func (r *MCPServerCapabilityRepository) Update(capability *domain.MCPServerCapab
 		capability.CapabilitySchema,
 		capability.LastVerifiedAt,
 		capability.IsActive,
+		capability.RiskOverride,
 		time.Now().UTC(),
 		capability.ID,
 	).Scan(&capability.UpdatedAt)
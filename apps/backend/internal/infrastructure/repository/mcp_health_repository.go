@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// MCPHealthRepository implements domain.MCPHealthRepository
+type MCPHealthRepository struct {
+	db *sql.DB
+}
+
+// NewMCPHealthRepository creates a new MCP health repository
+func NewMCPHealthRepository(db *sql.DB) *MCPHealthRepository {
+	return &MCPHealthRepository{db: db}
+}
+
+// RecordCheck stores one health check of an MCP server
+func (r *MCPHealthRepository) RecordCheck(check *domain.MCPHealthCheck) error {
+	query := `
+		INSERT INTO mcp_health_checks (id, mcp_server_id, healthy, status_code, latency_ms, error, checked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	if check.ID == uuid.Nil {
+		check.ID = uuid.New()
+	}
+	_, err := r.db.Exec(query,
+		check.ID,
+		check.MCPServerID,
+		check.Healthy,
+		check.StatusCode,
+		check.LatencyMs,
+		nullString(check.Error),
+		check.CheckedAt,
+	)
+	return err
+}
+
+// ListChecks returns the most recent health checks of an MCP server, newest first
+func (r *MCPHealthRepository) ListChecks(mcpServerID uuid.UUID, limit int) ([]*domain.MCPHealthCheck, error) {
+	query := `
+		SELECT id, mcp_server_id, healthy, status_code, latency_ms, error, checked_at
+		FROM mcp_health_checks
+		WHERE mcp_server_id = $1
+		ORDER BY checked_at DESC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(query, mcpServerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := make([]*domain.MCPHealthCheck, 0)
+	for rows.Next() {
+		check := &domain.MCPHealthCheck{}
+		var checkErr sql.NullString
+		if err := rows.Scan(
+			&check.ID,
+			&check.MCPServerID,
+			&check.Healthy,
+			&check.StatusCode,
+			&check.LatencyMs,
+			&checkErr,
+			&check.CheckedAt,
+		); err != nil {
+			return nil, err
+		}
+		check.Error = checkErr.String
+		checks = append(checks, check)
+	}
+	return checks, rows.Err()
+}
+
+// CountChecks counts the passed and total health checks of each MCP server since the given time
+func (r *MCPHealthRepository) CountChecks(mcpServerIDs []uuid.UUID, since time.Time) (map[uuid.UUID]domain.MCPHealthCheckCount, error) {
+	counts := make(map[uuid.UUID]domain.MCPHealthCheckCount, len(mcpServerIDs))
+	if len(mcpServerIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT mcp_server_id, COUNT(*) FILTER (WHERE healthy), COUNT(*)
+		FROM mcp_health_checks
+		WHERE mcp_server_id = ANY($1::uuid[]) AND checked_at >= $2
+		GROUP BY mcp_server_id
+	`
+	rows, err := r.db.Query(query, pq.Array(uuidStrings(mcpServerIDs)), since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var serverID uuid.UUID
+		var count domain.MCPHealthCheckCount
+		if err := rows.Scan(&serverID, &count.Passed, &count.Total); err != nil {
+			return nil, err
+		}
+		counts[serverID] = count
+	}
+	return counts, rows.Err()
+}
+
+// DeleteChecksBefore prunes the health checks older than the given time
+func (r *MCPHealthRepository) DeleteChecksBefore(before time.Time) (int, error) {
+	result, err := r.db.Exec(`DELETE FROM mcp_health_checks WHERE checked_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
+
+// GetState retrieves the health state of an MCP server
+func (r *MCPHealthRepository) GetState(mcpServerID uuid.UUID) (*domain.MCPServerHealth, error) {
+	query := `
+		SELECT mcp_server_id, state, consecutive_failures, last_checked_at, last_healthy_at,
+			last_error, downgraded_at, downgraded_from, updated_at
+		FROM mcp_server_health
+		WHERE mcp_server_id = $1
+	`
+	health := &domain.MCPServerHealth{}
+	var lastCheckedAt, lastHealthyAt, downgradedAt sql.NullTime
+	var lastError, downgradedFrom sql.NullString
+	err := r.db.QueryRow(query, mcpServerID).Scan(
+		&health.MCPServerID,
+		&health.State,
+		&health.ConsecutiveFailures,
+		&lastCheckedAt,
+		&lastHealthyAt,
+		&lastError,
+		&downgradedAt,
+		&downgradedFrom,
+		&health.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrMCPServerHealthNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if lastCheckedAt.Valid {
+		health.LastCheckedAt = &lastCheckedAt.Time
+	}
+	if lastHealthyAt.Valid {
+		health.LastHealthyAt = &lastHealthyAt.Time
+	}
+	if downgradedAt.Valid {
+		health.DowngradedAt = &downgradedAt.Time
+	}
+	health.LastError = lastError.String
+	health.DowngradedFrom = domain.MCPServerStatus(downgradedFrom.String)
+	return health, nil
+}
+
+// UpsertState stores the health state of an MCP server
+func (r *MCPHealthRepository) UpsertState(health *domain.MCPServerHealth) error {
+	query := `
+		INSERT INTO mcp_server_health (mcp_server_id, state, consecutive_failures, last_checked_at,
+			last_healthy_at, last_error, downgraded_at, downgraded_from, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (mcp_server_id) DO UPDATE SET
+			state = EXCLUDED.state,
+			consecutive_failures = EXCLUDED.consecutive_failures,
+			last_checked_at = EXCLUDED.last_checked_at,
+			last_healthy_at = EXCLUDED.last_healthy_at,
+			last_error = EXCLUDED.last_error,
+			downgraded_at = EXCLUDED.downgraded_at,
+			downgraded_from = EXCLUDED.downgraded_from,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.Exec(query,
+		health.MCPServerID,
+		health.State,
+		health.ConsecutiveFailures,
+		health.LastCheckedAt,
+		health.LastHealthyAt,
+		nullString(health.LastError),
+		health.DowngradedAt,
+		nullString(string(health.DowngradedFrom)),
+		health.UpdatedAt,
+	)
+	return err
+}
@@ -8,24 +8,38 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
 type MCPServerRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	cache *recordCache
 }
 
 func NewMCPServerRepository(db *sql.DB) *MCPServerRepository {
 	return &MCPServerRepository{db: db}
 }
 
+// UseCache caches GetByID lookups for ttl; a ttl of zero turns caching off
+func (r *MCPServerRepository) UseCache(cache domain.IdentityCache, ttl time.Duration) {
+	r.cache = newRecordCache(cache, ttl)
+}
+
+// SetCacheTTL changes the TTL of UseCache while the repository is in use; a ttl of zero turns
+// caching off
+func (r *MCPServerRepository) SetCacheTTL(ttl time.Duration) {
+	r.cache.setTTL(ttl)
+}
+
 func (r *MCPServerRepository) Create(server *domain.MCPServer) error {
 	query := `
 		INSERT INTO mcp_servers (
 			id, organization_id, name, description, url, version,
 			public_key, status, is_verified, verification_url,
-			capabilities, trust_score, registered_by_agent, created_by, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			capabilities, trust_score, registered_by_agent, created_by, created_at, updated_at,
+			criticality
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -53,6 +67,7 @@ func (r *MCPServerRepository) Create(server *domain.MCPServer) error {
 		server.CreatedBy,          // ✅ FIXED: Added created_by field
 		time.Now().UTC(),
 		time.Now().UTC(),
+		mcpServerCriticality(server),
 	).Scan(&server.ID, &server.CreatedAt, &server.UpdatedAt)
 
 	if err != nil {
@@ -63,20 +78,73 @@ func (r *MCPServerRepository) Create(server *domain.MCPServer) error {
 }
 
 func (r *MCPServerRepository) GetByID(id uuid.UUID) (*domain.MCPServer, error) {
+	cached := &domain.MCPServer{}
+	if r.cache.get(mcpServerCacheKey(id), cached) {
+		return cached, nil
+	}
+
 	query := `
-		SELECT
-			id, organization_id, name, description, url, version,
-			public_key, status, is_verified, last_verified_at, verification_url,
-			capabilities, trust_score, registered_by_agent, created_by, created_at, updated_at,
-			verification_method, attestation_count, confidence_score, last_attested_at
+		SELECT ` + mcpServerColumns + `
 		FROM mcp_servers
 		WHERE id = $1
 	`
 
+	server, err := scanMCPServer(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("mcp server not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mcp server: %w", err)
+	}
+
+	r.cache.set(mcpServerCacheKey(id), server)
+	return server, nil
+}
+
+// GetByIDs retrieves the MCP servers with the given IDs in one query; IDs that do not exist are skipped
+func (r *MCPServerRepository) GetByIDs(ids []uuid.UUID) ([]*domain.MCPServer, error) {
+	servers := make([]*domain.MCPServer, 0, len(ids))
+	if len(ids) == 0 {
+		return servers, nil
+	}
+
+	query := `
+		SELECT ` + mcpServerColumns + `
+		FROM mcp_servers
+		WHERE id = ANY($1::uuid[])
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, pq.Array(uuidStrings(ids)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mcp servers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		server, err := scanMCPServer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan mcp server: %w", err)
+		}
+		servers = append(servers, server)
+	}
+
+	return servers, rows.Err()
+}
+
+// mcpServerColumns are the columns read by scanMCPServer
+const mcpServerColumns = `
+			id, organization_id, name, description, url, version,
+			public_key, status, is_verified, last_verified_at, verification_url,
+			capabilities, trust_score, registered_by_agent, created_by, created_at, updated_at,
+			verification_method, attestation_count, confidence_score, last_attested_at, criticality`
+
+// scanMCPServer reads a row selected with mcpServerColumns
+func scanMCPServer(row rowScanner) (*domain.MCPServer, error) {
 	server := &domain.MCPServer{}
 	var capabilitiesJSON []byte
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := row.Scan(
 		&server.ID,
 		&server.OrganizationID,
 		&server.Name,
@@ -98,13 +166,10 @@ func (r *MCPServerRepository) GetByID(id uuid.UUID) (*domain.MCPServer, error) {
 		&server.AttestationCount,
 		&server.ConfidenceScore,
 		&server.LastAttestedAt,
+		&server.Criticality,
 	)
-
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("mcp server not found")
-	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get mcp server: %w", err)
+		return nil, err
 	}
 
 	// Unmarshal capabilities from JSONB
@@ -123,7 +188,7 @@ func (r *MCPServerRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.MCPS
 			m.id, m.organization_id, m.name, m.description, m.url, m.version,
 			m.public_key, m.status, m.is_verified, m.last_verified_at, m.verification_url,
 			m.capabilities, m.trust_score, m.registered_by_agent, m.created_by, m.created_at, m.updated_at,
-			m.verification_method, m.attestation_count, m.confidence_score, m.last_attested_at,
+			m.verification_method, m.attestation_count, m.confidence_score, m.last_attested_at, m.criticality,
 			COALESCE(COUNT(v.id), 0) AS verification_count
 		FROM mcp_servers m
 		LEFT JOIN verification_events v ON v.mcp_server_id = m.id
@@ -131,7 +196,7 @@ func (r *MCPServerRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.MCPS
 		GROUP BY m.id, m.organization_id, m.name, m.description, m.url, m.version,
 			m.public_key, m.status, m.is_verified, m.last_verified_at, m.verification_url,
 			m.capabilities, m.trust_score, m.registered_by_agent, m.created_by, m.created_at, m.updated_at,
-			m.verification_method, m.attestation_count, m.confidence_score, m.last_attested_at
+			m.verification_method, m.attestation_count, m.confidence_score, m.last_attested_at, m.criticality
 		ORDER BY m.created_at DESC
 	`
 
@@ -168,6 +233,7 @@ func (r *MCPServerRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.MCPS
 			&server.AttestationCount,
 			&server.ConfidenceScore,
 			&server.LastAttestedAt,
+			&server.Criticality,
 			&server.VerificationCount,
 		)
 		if err != nil {
@@ -193,7 +259,7 @@ func (r *MCPServerRepository) GetByURL(url string) (*domain.MCPServer, error) {
 			id, organization_id, name, description, url, version,
 			public_key, status, is_verified, last_verified_at, verification_url,
 			capabilities, trust_score, registered_by_agent, created_by, created_at, updated_at,
-			verification_method, attestation_count, confidence_score, last_attested_at
+			verification_method, attestation_count, confidence_score, last_attested_at, criticality
 		FROM mcp_servers
 		WHERE url = $1
 	`
@@ -223,6 +289,7 @@ func (r *MCPServerRepository) GetByURL(url string) (*domain.MCPServer, error) {
 		&server.AttestationCount,
 		&server.ConfidenceScore,
 		&server.LastAttestedAt,
+		&server.Criticality,
 	)
 
 	if err == sql.ErrNoRows {
@@ -257,8 +324,9 @@ func (r *MCPServerRepository) Update(server *domain.MCPServer) error {
 			verification_url = $9,
 			capabilities = $10,
 			trust_score = $11,
-			updated_at = $12
-		WHERE id = $13
+			criticality = $12,
+			updated_at = $13
+		WHERE id = $14
 		RETURNING updated_at
 	`
 
@@ -281,6 +349,7 @@ func (r *MCPServerRepository) Update(server *domain.MCPServer) error {
 		server.VerificationURL,
 		capabilitiesJSON, // Use JSON bytes
 		server.TrustScore,
+		mcpServerCriticality(server),
 		time.Now().UTC(),
 		server.ID,
 	).Scan(&server.UpdatedAt)
@@ -289,9 +358,18 @@ func (r *MCPServerRepository) Update(server *domain.MCPServer) error {
 		return fmt.Errorf("failed to update mcp server: %w", err)
 	}
 
+	r.cache.invalidate(mcpServerCacheKey(server.ID))
 	return nil
 }
 
+// mcpServerCriticality is the criticality stored for the server; untagged servers are medium
+func mcpServerCriticality(server *domain.MCPServer) domain.MCPServerCriticality {
+	if server.Criticality == "" {
+		return domain.DefaultMCPServerCriticality
+	}
+	return server.Criticality
+}
+
 func (r *MCPServerRepository) Delete(id uuid.UUID) error {
 	query := `DELETE FROM mcp_servers WHERE id = $1`
 
@@ -305,15 +383,13 @@ func (r *MCPServerRepository) Delete(id uuid.UUID) error {
 		return fmt.Errorf("mcp server not found")
 	}
 
+	r.cache.invalidate(mcpServerCacheKey(id))
 	return nil
 }
 
 func (r *MCPServerRepository) List(limit, offset int) ([]*domain.MCPServer, error) {
 	query := `
-		SELECT
-			id, organization_id, name, description, url, version,
-			public_key, status, is_verified, last_verified_at, verification_url,
-			capabilities, trust_score, registered_by_agent, created_by, created_at, updated_at
+		SELECT ` + mcpServerColumns + `
 		FROM mcp_servers
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
@@ -327,43 +403,14 @@ func (r *MCPServerRepository) List(limit, offset int) ([]*domain.MCPServer, erro
 
 	var servers []*domain.MCPServer
 	for rows.Next() {
-		server := &domain.MCPServer{}
-		var capabilitiesJSON []byte
-
-		err := rows.Scan(
-			&server.ID,
-			&server.OrganizationID,
-			&server.Name,
-			&server.Description,
-			&server.URL,
-			&server.Version,
-			&server.PublicKey,
-			&server.Status,
-			&server.IsVerified,
-			&server.LastVerifiedAt,
-			&server.VerificationURL,
-			&capabilitiesJSON, // Read as JSON bytes
-			&server.TrustScore,
-			&server.RegisteredByAgent,
-			&server.CreatedBy,
-			&server.CreatedAt,
-			&server.UpdatedAt,
-		)
+		server, err := scanMCPServer(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan mcp server: %w", err)
 		}
-
-		// Unmarshal capabilities from JSONB
-		if len(capabilitiesJSON) > 0 {
-			if err := json.Unmarshal(capabilitiesJSON, &server.Capabilities); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal capabilities: %w", err)
-			}
-		}
-
 		servers = append(servers, server)
 	}
 
-	return servers, nil
+	return servers, rows.Err()
 }
 
 func (r *MCPServerRepository) GetVerificationStatus(id uuid.UUID) (*domain.MCPServerVerificationStatus, error) {
@@ -424,6 +471,32 @@ func (r *MCPServerRepository) AddPublicKey(ctx context.Context, serverID uuid.UU
 	return nil
 }
 
+// UpdateConfidenceScore records the server's attestation confidence score
+func (r *MCPServerRepository) UpdateConfidenceScore(id uuid.UUID, score float64, attestationCount int, lastAttestedAt time.Time) error {
+	query := `
+		UPDATE mcp_servers
+		SET
+			confidence_score = $1,
+			attestation_count = $2,
+			last_attested_at = $3,
+			updated_at = $4
+		WHERE id = $5
+	`
+
+	result, err := r.db.Exec(query, score, attestationCount, lastAttestedAt, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update confidence score: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("mcp server not found")
+	}
+
+	r.cache.invalidate(mcpServerCacheKey(id))
+	return nil
+}
+
 // VerifyServer performs cryptographic verification of an MCP server
 func (r *MCPServerRepository) VerifyServer(ctx context.Context, serverID uuid.UUID) error {
 	// Update server verification status
@@ -449,5 +522,6 @@ func (r *MCPServerRepository) VerifyServer(ctx context.Context, serverID uuid.UU
 		return fmt.Errorf("failed to verify server: %w", err)
 	}
 
+	r.cache.invalidate(mcpServerCacheKey(serverID))
 	return nil
 }
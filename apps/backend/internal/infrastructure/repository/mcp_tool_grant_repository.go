@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// MCPToolGrantRepository implements domain.MCPToolGrantRepository
+type MCPToolGrantRepository struct {
+	db *sql.DB
+}
+
+// NewMCPToolGrantRepository creates a new MCP tool grant repository
+func NewMCPToolGrantRepository(db *sql.DB) *MCPToolGrantRepository {
+	return &MCPToolGrantRepository{db: db}
+}
+
+const mcpToolGrantColumns = `id, organization_id, agent_id, mcp_server, tools, created_by, created_at, updated_at`
+
+// Upsert stores the agent's grant for the MCP server, replacing the tools of an existing one.
+// The grant's ID and creation time are those of the stored row.
+func (r *MCPToolGrantRepository) Upsert(grant *domain.MCPToolGrant) error {
+	query := `
+		INSERT INTO mcp_tool_grants (` + mcpToolGrantColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (agent_id, mcp_server) DO UPDATE
+		SET tools = EXCLUDED.tools, updated_at = EXCLUDED.updated_at
+		RETURNING id, created_by, created_at
+	`
+	if grant.ID == uuid.Nil {
+		grant.ID = uuid.New()
+	}
+
+	var createdBy uuid.NullUUID
+	err := r.db.QueryRow(query,
+		grant.ID,
+		grant.OrganizationID,
+		grant.AgentID,
+		grant.MCPServer,
+		pq.Array(grant.Tools),
+		uuid.NullUUID{UUID: grant.CreatedBy, Valid: grant.CreatedBy != uuid.Nil},
+		grant.CreatedAt,
+		grant.UpdatedAt,
+	).Scan(&grant.ID, &createdBy, &grant.CreatedAt)
+	if err != nil {
+		return err
+	}
+	grant.CreatedBy = createdBy.UUID
+	return nil
+}
+
+// ListByAgent returns the agent's grants, oldest first
+func (r *MCPToolGrantRepository) ListByAgent(agentID uuid.UUID) ([]*domain.MCPToolGrant, error) {
+	query := `
+		SELECT ` + mcpToolGrantColumns + `
+		FROM mcp_tool_grants
+		WHERE agent_id = $1
+		ORDER BY created_at
+	`
+	rows, err := r.db.Query(query, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := make([]*domain.MCPToolGrant, 0)
+	for rows.Next() {
+		grant, err := scanMCPToolGrant(rows)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+	return grants, rows.Err()
+}
+
+// Delete removes the agent's grant for the MCP server
+func (r *MCPToolGrantRepository) Delete(agentID uuid.UUID, mcpServer string) error {
+	result, err := r.db.Exec(`DELETE FROM mcp_tool_grants WHERE agent_id = $1 AND mcp_server = $2`, agentID, mcpServer)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrMCPToolGrantNotFound
+	}
+	return nil
+}
+
+func scanMCPToolGrant(row rowScanner) (*domain.MCPToolGrant, error) {
+	grant := &domain.MCPToolGrant{}
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&grant.ID,
+		&grant.OrganizationID,
+		&grant.AgentID,
+		&grant.MCPServer,
+		pq.Array(&grant.Tools),
+		&createdBy,
+		&grant.CreatedAt,
+		&grant.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	grant.CreatedBy = createdBy.UUID
+	if grant.Tools == nil {
+		grant.Tools = []string{}
+	}
+	return grant, nil
+}
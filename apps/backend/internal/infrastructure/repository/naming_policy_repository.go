@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// NamingPolicyRepository implements domain.NamingPolicyRepository
+type NamingPolicyRepository struct {
+	db *sql.DB
+}
+
+// NewNamingPolicyRepository creates a new naming policy repository
+func NewNamingPolicyRepository(db *sql.DB) *NamingPolicyRepository {
+	return &NamingPolicyRepository{db: db}
+}
+
+const namingPolicyColumns = `id, organization_id, name, description, resource_type, tag_key, tag_value, pattern, required_prefix, reserved_names, normalized_unique, is_active, created_by, created_at, updated_at`
+
+// Create creates a new naming policy
+func (r *NamingPolicyRepository) Create(policy *domain.NamingPolicy) error {
+	query := `
+		INSERT INTO naming_policies (` + namingPolicyColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	policy.CreatedAt = now
+	policy.UpdatedAt = now
+
+	_, err := r.db.Exec(query,
+		policy.ID,
+		policy.OrganizationID,
+		policy.Name,
+		policy.Description,
+		policy.ResourceType,
+		policy.TagKey,
+		policy.TagValue,
+		policy.Pattern,
+		policy.RequiredPrefix,
+		pq.Array(policy.ReservedNames),
+		policy.NormalizedUnique,
+		policy.IsActive,
+		policy.CreatedBy,
+		policy.CreatedAt,
+		policy.UpdatedAt,
+	)
+	return err
+}
+
+// GetByID retrieves a naming policy by ID
+func (r *NamingPolicyRepository) GetByID(id uuid.UUID) (*domain.NamingPolicy, error) {
+	query := `SELECT ` + namingPolicyColumns + ` FROM naming_policies WHERE id = $1`
+
+	policy, err := scanNamingPolicy(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNamingPolicyNotFound
+	}
+	return policy, err
+}
+
+// GetByOrganization retrieves all naming policies of an organization
+func (r *NamingPolicyRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.NamingPolicy, error) {
+	query := `
+		SELECT ` + namingPolicyColumns + `
+		FROM naming_policies
+		WHERE organization_id = $1
+		ORDER BY created_at ASC
+	`
+	return r.queryPolicies(query, orgID)
+}
+
+// GetActiveByOrganization retrieves the enforced naming policies of an organization
+func (r *NamingPolicyRepository) GetActiveByOrganization(orgID uuid.UUID) ([]*domain.NamingPolicy, error) {
+	query := `
+		SELECT ` + namingPolicyColumns + `
+		FROM naming_policies
+		WHERE organization_id = $1 AND is_active = true
+		ORDER BY created_at ASC
+	`
+	return r.queryPolicies(query, orgID)
+}
+
+// Update updates a naming policy
+func (r *NamingPolicyRepository) Update(policy *domain.NamingPolicy) error {
+	query := `
+		UPDATE naming_policies
+		SET name = $1, description = $2, resource_type = $3, tag_key = $4, tag_value = $5, pattern = $6,
+			required_prefix = $7, reserved_names = $8, normalized_unique = $9, is_active = $10, updated_at = $11
+		WHERE id = $12
+	`
+
+	policy.UpdatedAt = time.Now().UTC()
+	_, err := r.db.Exec(query,
+		policy.Name,
+		policy.Description,
+		policy.ResourceType,
+		policy.TagKey,
+		policy.TagValue,
+		policy.Pattern,
+		policy.RequiredPrefix,
+		pq.Array(policy.ReservedNames),
+		policy.NormalizedUnique,
+		policy.IsActive,
+		policy.UpdatedAt,
+		policy.ID,
+	)
+	return err
+}
+
+// Delete deletes a naming policy
+func (r *NamingPolicyRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM naming_policies WHERE id = $1`, id)
+	return err
+}
+
+func (r *NamingPolicyRepository) queryPolicies(query string, args ...interface{}) ([]*domain.NamingPolicy, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []*domain.NamingPolicy
+	for rows.Next() {
+		policy, err := scanNamingPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, rows.Err()
+}
+
+func scanNamingPolicy(row rowScanner) (*domain.NamingPolicy, error) {
+	policy := &domain.NamingPolicy{}
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&policy.ID,
+		&policy.OrganizationID,
+		&policy.Name,
+		&policy.Description,
+		&policy.ResourceType,
+		&policy.TagKey,
+		&policy.TagValue,
+		&policy.Pattern,
+		&policy.RequiredPrefix,
+		pq.Array(&policy.ReservedNames),
+		&policy.NormalizedUnique,
+		&policy.IsActive,
+		&createdBy,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.ReservedNames == nil {
+		policy.ReservedNames = []string{}
+	}
+	if createdBy.Valid {
+		policy.CreatedBy = createdBy.UUID
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,465 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// NotificationRepository implements domain.NotificationRepository
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+const notificationChannelColumns = `id, organization_id, name, channel_type, recipients, config, event_types, min_severity, is_active, created_by, created_at, updated_at`
+
+const notificationDeliveryColumns = `id, notification_id, channel_id, channel_type, recipient, status, attempts, max_attempts, last_error, next_attempt_at, delivered_at, created_at, updated_at, digest`
+
+// CreateChannel creates a new notification channel
+func (r *NotificationRepository) CreateChannel(channel *domain.NotificationChannel) error {
+	query := `
+		INSERT INTO notification_channels (` + notificationChannelColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	if channel.ID == uuid.Nil {
+		channel.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	channel.CreatedAt = now
+	channel.UpdatedAt = now
+
+	configJSON, err := json.Marshal(channel.Config)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query,
+		channel.ID,
+		channel.OrganizationID,
+		channel.Name,
+		channel.ChannelType,
+		pq.Array(channel.Recipients),
+		configJSON,
+		pq.Array(channel.EventTypes),
+		channel.MinSeverity,
+		channel.IsActive,
+		channel.CreatedBy,
+		channel.CreatedAt,
+		channel.UpdatedAt,
+	)
+	return err
+}
+
+// GetChannelByID retrieves a notification channel by ID
+func (r *NotificationRepository) GetChannelByID(id uuid.UUID) (*domain.NotificationChannel, error) {
+	query := `SELECT ` + notificationChannelColumns + ` FROM notification_channels WHERE id = $1`
+
+	channel, err := scanNotificationChannel(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("notification channel not found")
+	}
+	return channel, err
+}
+
+// GetChannelsByOrganization retrieves all notification channels for an organization
+func (r *NotificationRepository) GetChannelsByOrganization(orgID uuid.UUID) ([]*domain.NotificationChannel, error) {
+	query := `
+		SELECT ` + notificationChannelColumns + `
+		FROM notification_channels
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+	`
+	return r.queryChannels(query, orgID)
+}
+
+// GetActiveChannelsByOrganization retrieves enabled notification channels for an organization
+func (r *NotificationRepository) GetActiveChannelsByOrganization(orgID uuid.UUID) ([]*domain.NotificationChannel, error) {
+	query := `
+		SELECT ` + notificationChannelColumns + `
+		FROM notification_channels
+		WHERE organization_id = $1 AND is_active = true
+		ORDER BY created_at ASC
+	`
+	return r.queryChannels(query, orgID)
+}
+
+// UpdateChannel updates a notification channel
+func (r *NotificationRepository) UpdateChannel(channel *domain.NotificationChannel) error {
+	query := `
+		UPDATE notification_channels
+		SET name = $1, recipients = $2, config = $3, event_types = $4, min_severity = $5, is_active = $6, updated_at = $7
+		WHERE id = $8
+	`
+
+	configJSON, err := json.Marshal(channel.Config)
+	if err != nil {
+		return err
+	}
+
+	channel.UpdatedAt = time.Now().UTC()
+	_, err = r.db.Exec(query,
+		channel.Name,
+		pq.Array(channel.Recipients),
+		configJSON,
+		pq.Array(channel.EventTypes),
+		channel.MinSeverity,
+		channel.IsActive,
+		channel.UpdatedAt,
+		channel.ID,
+	)
+	return err
+}
+
+// DeleteChannel deletes a notification channel (and its deliveries)
+func (r *NotificationRepository) DeleteChannel(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM notification_channels WHERE id = $1`, id)
+	return err
+}
+
+// CreateNotification stores a notification together with its fanned-out deliveries in one transaction
+func (r *NotificationRepository) CreateNotification(notification *domain.Notification, deliveries []*domain.NotificationDelivery) error {
+	if notification.ID == uuid.Nil {
+		notification.ID = uuid.New()
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now().UTC()
+	}
+
+	payloadJSON, err := json.Marshal(notification.Payload)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO notifications (id, organization_id, event_type, severity, title, message, resource_type, resource_id, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`,
+		notification.ID,
+		notification.OrganizationID,
+		notification.EventType,
+		notification.Severity,
+		notification.Title,
+		notification.Message,
+		notification.ResourceType,
+		notification.ResourceID,
+		payloadJSON,
+		notification.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert notification: %w", err)
+	}
+
+	for _, d := range deliveries {
+		if d.ID == uuid.Nil {
+			d.ID = uuid.New()
+		}
+		d.NotificationID = notification.ID
+		d.CreatedAt = notification.CreatedAt
+		d.UpdatedAt = notification.CreatedAt
+		if d.NextAttemptAt.IsZero() {
+			d.NextAttemptAt = notification.CreatedAt
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO notification_deliveries (`+notificationDeliveryColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		`,
+			d.ID,
+			d.NotificationID,
+			d.ChannelID,
+			d.ChannelType,
+			d.Recipient,
+			d.Status,
+			d.Attempts,
+			d.MaxAttempts,
+			d.LastError,
+			d.NextAttemptAt,
+			d.DeliveredAt,
+			d.CreatedAt,
+			d.UpdatedAt,
+			d.Digest,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert notification delivery: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetNotificationByID retrieves a notification by ID
+func (r *NotificationRepository) GetNotificationByID(id uuid.UUID) (*domain.Notification, error) {
+	query := `
+		SELECT id, organization_id, event_type, severity, title, message, resource_type, resource_id, payload, created_at
+		FROM notifications
+		WHERE id = $1
+	`
+
+	notification, err := scanNotification(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("notification not found")
+	}
+	return notification, err
+}
+
+// GetNotificationsByOrganization retrieves notifications for an organization with pagination
+func (r *NotificationRepository) GetNotificationsByOrganization(orgID uuid.UUID, limit, offset int) ([]*domain.Notification, int, error) {
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM notifications WHERE organization_id = $1`, orgID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, organization_id, event_type, severity, title, message, resource_type, resource_id, payload, created_at
+		FROM notifications
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(query, orgID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var notifications []*domain.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, total, rows.Err()
+}
+
+// GetDeliveriesByNotification retrieves all deliveries of a notification
+func (r *NotificationRepository) GetDeliveriesByNotification(notificationID uuid.UUID) ([]*domain.NotificationDelivery, error) {
+	query := `
+		SELECT ` + notificationDeliveryColumns + `
+		FROM notification_deliveries
+		WHERE notification_id = $1
+		ORDER BY created_at ASC, channel_type ASC
+	`
+	return r.queryDeliveries(query, notificationID)
+}
+
+// GetDeliveryByID retrieves a single delivery
+func (r *NotificationRepository) GetDeliveryByID(id uuid.UUID) (*domain.NotificationDelivery, error) {
+	query := `SELECT ` + notificationDeliveryColumns + ` FROM notification_deliveries WHERE id = $1`
+
+	delivery, err := scanNotificationDelivery(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("notification delivery not found")
+	}
+	return delivery, err
+}
+
+// ClaimDueDeliveries atomically claims due deliveries for a worker.
+// Claimed rows move to "sending" and their next_attempt_at becomes the lease expiry,
+// so deliveries held by a crashed worker are picked up again once the lease runs out.
+func (r *NotificationRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]*domain.NotificationDelivery, error) {
+	query := `
+		UPDATE notification_deliveries
+		SET status = 'sending', next_attempt_at = $2, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM notification_deliveries
+			WHERE status IN ('pending', 'sending') AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + notificationDeliveryColumns
+
+	return r.queryDeliveries(query, limit, time.Now().UTC().Add(lease))
+}
+
+// MarkDelivered records a successful delivery attempt
+func (r *NotificationRepository) MarkDelivered(id uuid.UUID) error {
+	query := `
+		UPDATE notification_deliveries
+		SET status = 'delivered', attempts = attempts + 1, last_error = NULL, delivered_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// MarkAttemptFailed records a failed attempt and either schedules a retry or marks the delivery failed
+func (r *NotificationRepository) MarkAttemptFailed(id uuid.UUID, errMsg string, nextAttemptAt time.Time, exhausted bool) error {
+	status := domain.NotificationDeliveryPending
+	if exhausted {
+		status = domain.NotificationDeliveryFailed
+	}
+
+	query := `
+		UPDATE notification_deliveries
+		SET status = $1, attempts = attempts + 1, last_error = $2, next_attempt_at = $3, updated_at = NOW()
+		WHERE id = $4
+	`
+	_, err := r.db.Exec(query, status, errMsg, nextAttemptAt, id)
+	return err
+}
+
+// RequeueDelivery puts a delivery back on the queue with a fresh retry budget
+func (r *NotificationRepository) RequeueDelivery(id uuid.UUID) error {
+	query := `
+		UPDATE notification_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+func (r *NotificationRepository) queryChannels(query string, args ...interface{}) ([]*domain.NotificationChannel, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var channels []*domain.NotificationChannel
+	for rows.Next() {
+		channel, err := scanNotificationChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+
+	return channels, rows.Err()
+}
+
+func (r *NotificationRepository) queryDeliveries(query string, args ...interface{}) ([]*domain.NotificationDelivery, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.NotificationDelivery
+	for rows.Next() {
+		delivery, err := scanNotificationDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanNotificationChannel(row rowScanner) (*domain.NotificationChannel, error) {
+	channel := &domain.NotificationChannel{}
+	var configJSON []byte
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&channel.ID,
+		&channel.OrganizationID,
+		&channel.Name,
+		&channel.ChannelType,
+		pq.Array(&channel.Recipients),
+		&configJSON,
+		pq.Array(&channel.EventTypes),
+		&channel.MinSeverity,
+		&channel.IsActive,
+		&createdBy,
+		&channel.CreatedAt,
+		&channel.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if createdBy.Valid {
+		channel.CreatedBy = createdBy.UUID
+	}
+	if len(configJSON) > 0 {
+		if err := json.Unmarshal(configJSON, &channel.Config); err != nil {
+			return nil, err
+		}
+	}
+
+	return channel, nil
+}
+
+func scanNotification(row rowScanner) (*domain.Notification, error) {
+	notification := &domain.Notification{}
+	var payloadJSON []byte
+
+	err := row.Scan(
+		&notification.ID,
+		&notification.OrganizationID,
+		&notification.EventType,
+		&notification.Severity,
+		&notification.Title,
+		&notification.Message,
+		&notification.ResourceType,
+		&notification.ResourceID,
+		&payloadJSON,
+		&notification.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payloadJSON) > 0 {
+		if err := json.Unmarshal(payloadJSON, &notification.Payload); err != nil {
+			return nil, err
+		}
+	}
+
+	return notification, nil
+}
+
+func scanNotificationDelivery(row rowScanner) (*domain.NotificationDelivery, error) {
+	delivery := &domain.NotificationDelivery{}
+
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.NotificationID,
+		&delivery.ChannelID,
+		&delivery.ChannelType,
+		&delivery.Recipient,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.MaxAttempts,
+		&delivery.LastError,
+		&delivery.NextAttemptAt,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+		&delivery.Digest,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// NotificationRouteRepository implements domain.NotificationRouteRepository
+type NotificationRouteRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRouteRepository creates a new notification route repository
+func NewNotificationRouteRepository(db *sql.DB) *NotificationRouteRepository {
+	return &NotificationRouteRepository{db: db}
+}
+
+const notificationRouteColumns = `id, organization_id, name, alert_types, min_severity, max_severity, resource_tags, channel_ids, digest_interval_minutes, is_active, created_by, created_at, updated_at`
+
+// Create creates a new notification route
+func (r *NotificationRouteRepository) Create(route *domain.NotificationRoute) error {
+	query := `
+		INSERT INTO notification_routes (` + notificationRouteColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	if route.ID == uuid.Nil {
+		route.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	route.CreatedAt = now
+	route.UpdatedAt = now
+
+	_, err := r.db.Exec(query,
+		route.ID,
+		route.OrganizationID,
+		route.Name,
+		pq.Array(alertTypeStrings(route.AlertTypes)),
+		route.MinSeverity,
+		route.MaxSeverity,
+		pq.Array(route.ResourceTags),
+		pq.Array(uuidStrings(route.ChannelIDs)),
+		route.DigestIntervalMinutes,
+		route.IsActive,
+		route.CreatedBy,
+		route.CreatedAt,
+		route.UpdatedAt,
+	)
+	return err
+}
+
+// GetByID retrieves a notification route by ID
+func (r *NotificationRouteRepository) GetByID(id uuid.UUID) (*domain.NotificationRoute, error) {
+	query := `SELECT ` + notificationRouteColumns + ` FROM notification_routes WHERE id = $1`
+
+	route, err := scanNotificationRoute(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("notification route not found")
+	}
+	return route, err
+}
+
+// GetByOrganization retrieves all notification routes of an organization
+func (r *NotificationRouteRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.NotificationRoute, error) {
+	query := `
+		SELECT ` + notificationRouteColumns + `
+		FROM notification_routes
+		WHERE organization_id = $1
+		ORDER BY created_at ASC
+	`
+	return r.queryRoutes(query, orgID)
+}
+
+// GetActiveByOrganization retrieves the enabled notification routes of an organization
+func (r *NotificationRouteRepository) GetActiveByOrganization(orgID uuid.UUID) ([]*domain.NotificationRoute, error) {
+	query := `
+		SELECT ` + notificationRouteColumns + `
+		FROM notification_routes
+		WHERE organization_id = $1 AND is_active = true
+		ORDER BY created_at ASC
+	`
+	return r.queryRoutes(query, orgID)
+}
+
+// Update updates a notification route
+func (r *NotificationRouteRepository) Update(route *domain.NotificationRoute) error {
+	query := `
+		UPDATE notification_routes
+		SET name = $1, alert_types = $2, min_severity = $3, max_severity = $4, resource_tags = $5,
+			channel_ids = $6, digest_interval_minutes = $7, is_active = $8, updated_at = $9
+		WHERE id = $10
+	`
+
+	route.UpdatedAt = time.Now().UTC()
+	_, err := r.db.Exec(query,
+		route.Name,
+		pq.Array(alertTypeStrings(route.AlertTypes)),
+		route.MinSeverity,
+		route.MaxSeverity,
+		pq.Array(route.ResourceTags),
+		pq.Array(uuidStrings(route.ChannelIDs)),
+		route.DigestIntervalMinutes,
+		route.IsActive,
+		route.UpdatedAt,
+		route.ID,
+	)
+	return err
+}
+
+// Delete deletes a notification route
+func (r *NotificationRouteRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM notification_routes WHERE id = $1`, id)
+	return err
+}
+
+func (r *NotificationRouteRepository) queryRoutes(query string, args ...interface{}) ([]*domain.NotificationRoute, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var routes []*domain.NotificationRoute
+	for rows.Next() {
+		route, err := scanNotificationRoute(rows)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+
+	return routes, rows.Err()
+}
+
+func scanNotificationRoute(row rowScanner) (*domain.NotificationRoute, error) {
+	route := &domain.NotificationRoute{}
+	var alertTypes, channelIDs []string
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&route.ID,
+		&route.OrganizationID,
+		&route.Name,
+		pq.Array(&alertTypes),
+		&route.MinSeverity,
+		&route.MaxSeverity,
+		pq.Array(&route.ResourceTags),
+		pq.Array(&channelIDs),
+		&route.DigestIntervalMinutes,
+		&route.IsActive,
+		&createdBy,
+		&route.CreatedAt,
+		&route.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	route.AlertTypes = make([]domain.AlertType, 0, len(alertTypes))
+	for _, alertType := range alertTypes {
+		route.AlertTypes = append(route.AlertTypes, domain.AlertType(alertType))
+	}
+	for _, raw := range channelIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		route.ChannelIDs = append(route.ChannelIDs, id)
+	}
+	if route.ResourceTags == nil {
+		route.ResourceTags = []string{}
+	}
+	if createdBy.Valid {
+		route.CreatedBy = createdBy.UUID
+	}
+
+	return route, nil
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
@@ -22,16 +24,21 @@ func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
 // Create creates a new organization
 func (r *OrganizationRepository) Create(org *domain.Organization) error {
 	query := `
-		INSERT INTO organizations (id, name, domain, plan_type, max_agents, max_users, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO organizations (id, name, domain, plan_type, max_agents, max_users, is_active, auto_register_attested_mcps, client_side_key_generation, mfa_required_roles, settings, created_at, updated_at, parent_organization_id, data_residency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
 
+	settings, err := organizationSettingsJSON(org.Settings)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	org.ID = uuid.New()
 	org.CreatedAt = now
 	org.UpdatedAt = now
 
-	_, err := r.db.Exec(query,
+	_, err = r.db.Exec(query,
 		org.ID,
 		org.Name,
 		org.Domain,
@@ -39,8 +46,14 @@ func (r *OrganizationRepository) Create(org *domain.Organization) error {
 		org.MaxAgents,
 		org.MaxUsers,
 		org.IsActive,
+		org.AutoRegisterAttestedMCPs,
+		org.ClientSideKeyGeneration,
+		pq.Array(userRoleStrings(org.MFARequiredRoles)),
+		settings,
 		org.CreatedAt,
 		org.UpdatedAt,
+		org.ParentID,
+		organizationResidencyValue(org.DataResidency),
 	)
 
 	return err
@@ -49,12 +62,16 @@ func (r *OrganizationRepository) Create(org *domain.Organization) error {
 // GetByID retrieves an organization by ID
 func (r *OrganizationRepository) GetByID(id uuid.UUID) (*domain.Organization, error) {
 	query := `
-		SELECT id, name, domain, plan_type, max_agents, max_users, is_active, created_at, updated_at
+		SELECT id, name, domain, plan_type, max_agents, max_users, is_active, auto_register_attested_mcps, client_side_key_generation, mfa_required_roles, settings, created_at, updated_at, parent_organization_id, data_residency
 		FROM organizations
 		WHERE id = $1
 	`
 
 	org := &domain.Organization{}
+	var mfaRoles []string
+	var settings []byte
+	var parentID uuid.NullUUID
+	var residency sql.NullString
 	err := r.db.QueryRow(query, id).Scan(
 		&org.ID,
 		&org.Name,
@@ -63,8 +80,14 @@ func (r *OrganizationRepository) GetByID(id uuid.UUID) (*domain.Organization, er
 		&org.MaxAgents,
 		&org.MaxUsers,
 		&org.IsActive,
+		&org.AutoRegisterAttestedMCPs,
+		&org.ClientSideKeyGeneration,
+		pq.Array(&mfaRoles),
+		&settings,
 		&org.CreatedAt,
 		&org.UpdatedAt,
+		&parentID,
+		&residency,
 	)
 
 	if err == sql.ErrNoRows {
@@ -73,6 +96,12 @@ func (r *OrganizationRepository) GetByID(id uuid.UUID) (*domain.Organization, er
 	if err != nil {
 		return nil, err
 	}
+	org.MFARequiredRoles = userRoles(mfaRoles)
+	org.ParentID = organizationParent(parentID)
+	org.DataResidency = domain.DataRegion(residency.String)
+	if err := json.Unmarshal(settings, &org.Settings); err != nil {
+		return nil, fmt.Errorf("failed to decode organization settings: %w", err)
+	}
 
 	return org, nil
 }
@@ -80,12 +109,16 @@ func (r *OrganizationRepository) GetByID(id uuid.UUID) (*domain.Organization, er
 // GetByDomain retrieves an organization by domain
 func (r *OrganizationRepository) GetByDomain(domainName string) (*domain.Organization, error) {
 	query := `
-		SELECT id, name, domain, plan_type, max_agents, max_users, is_active, created_at, updated_at
+		SELECT id, name, domain, plan_type, max_agents, max_users, is_active, auto_register_attested_mcps, client_side_key_generation, mfa_required_roles, settings, created_at, updated_at, parent_organization_id, data_residency
 		FROM organizations
 		WHERE domain = $1
 	`
 
 	org := &domain.Organization{}
+	var mfaRoles []string
+	var settings []byte
+	var parentID uuid.NullUUID
+	var residency sql.NullString
 	err := r.db.QueryRow(query, domainName).Scan(
 		&org.ID,
 		&org.Name,
@@ -94,8 +127,14 @@ func (r *OrganizationRepository) GetByDomain(domainName string) (*domain.Organiz
 		&org.MaxAgents,
 		&org.MaxUsers,
 		&org.IsActive,
+		&org.AutoRegisterAttestedMCPs,
+		&org.ClientSideKeyGeneration,
+		pq.Array(&mfaRoles),
+		&settings,
 		&org.CreatedAt,
 		&org.UpdatedAt,
+		&parentID,
+		&residency,
 	)
 
 	if err == sql.ErrNoRows {
@@ -104,6 +143,12 @@ func (r *OrganizationRepository) GetByDomain(domainName string) (*domain.Organiz
 	if err != nil {
 		return nil, err
 	}
+	org.MFARequiredRoles = userRoles(mfaRoles)
+	org.ParentID = organizationParent(parentID)
+	org.DataResidency = domain.DataRegion(residency.String)
+	if err := json.Unmarshal(settings, &org.Settings); err != nil {
+		return nil, fmt.Errorf("failed to decode organization settings: %w", err)
+	}
 
 	return org, nil
 }
@@ -112,19 +157,31 @@ func (r *OrganizationRepository) GetByDomain(domainName string) (*domain.Organiz
 func (r *OrganizationRepository) Update(org *domain.Organization) error {
 	query := `
 		UPDATE organizations
-		SET name = $1, plan_type = $2, max_agents = $3, max_users = $4, is_active = $5, updated_at = $6
-		WHERE id = $7
+		SET name = $1, plan_type = $2, max_agents = $3, max_users = $4, is_active = $5,
+			auto_register_attested_mcps = $6, client_side_key_generation = $7, mfa_required_roles = $8,
+			settings = $9, updated_at = $10, data_residency = $11
+		WHERE id = $12
 	`
 
+	settings, err := organizationSettingsJSON(org.Settings)
+	if err != nil {
+		return err
+	}
+
 	org.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(query,
+	_, err = r.db.Exec(query,
 		org.Name,
 		org.PlanType,
 		org.MaxAgents,
 		org.MaxUsers,
 		org.IsActive,
+		org.AutoRegisterAttestedMCPs,
+		org.ClientSideKeyGeneration,
+		pq.Array(userRoleStrings(org.MFARequiredRoles)),
+		settings,
 		org.UpdatedAt,
+		organizationResidencyValue(org.DataResidency),
 		org.ID,
 	)
 
@@ -137,3 +194,152 @@ func (r *OrganizationRepository) Delete(id uuid.UUID) error {
 	_, err := r.db.Exec(query, id)
 	return err
 }
+
+// List retrieves all organizations
+func (r *OrganizationRepository) List() ([]*domain.Organization, error) {
+	query := `
+		SELECT id, name, domain, plan_type, max_agents, max_users, is_active, auto_register_attested_mcps, client_side_key_generation, mfa_required_roles, settings, created_at, updated_at, parent_organization_id, data_residency
+		FROM organizations
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orgs []*domain.Organization
+	for rows.Next() {
+		org := &domain.Organization{}
+		var mfaRoles []string
+		var settings []byte
+		var parentID uuid.NullUUID
+		var residency sql.NullString
+		if err := rows.Scan(
+			&org.ID,
+			&org.Name,
+			&org.Domain,
+			&org.PlanType,
+			&org.MaxAgents,
+			&org.MaxUsers,
+			&org.IsActive,
+			&org.AutoRegisterAttestedMCPs,
+			&org.ClientSideKeyGeneration,
+			pq.Array(&mfaRoles),
+			&settings,
+			&org.CreatedAt,
+			&org.UpdatedAt,
+			&parentID,
+			&residency,
+		); err != nil {
+			return nil, err
+		}
+		org.MFARequiredRoles = userRoles(mfaRoles)
+		org.ParentID = organizationParent(parentID)
+		org.DataResidency = domain.DataRegion(residency.String)
+		if err := json.Unmarshal(settings, &org.Settings); err != nil {
+			return nil, fmt.Errorf("failed to decode organization settings: %w", err)
+		}
+		orgs = append(orgs, org)
+	}
+
+	return orgs, rows.Err()
+}
+
+// GetChildren returns the organizations directly under the parent, oldest first
+func (r *OrganizationRepository) GetChildren(parentID uuid.UUID) ([]*domain.Organization, error) {
+	query := `
+		SELECT id, name, domain, plan_type, max_agents, max_users, is_active, auto_register_attested_mcps, client_side_key_generation, mfa_required_roles, settings, created_at, updated_at, parent_organization_id, data_residency
+		FROM organizations
+		WHERE parent_organization_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(query, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := make([]*domain.Organization, 0)
+	for rows.Next() {
+		org := &domain.Organization{}
+		var mfaRoles []string
+		var settings []byte
+		var parentID uuid.NullUUID
+		var residency sql.NullString
+		if err := rows.Scan(
+			&org.ID,
+			&org.Name,
+			&org.Domain,
+			&org.PlanType,
+			&org.MaxAgents,
+			&org.MaxUsers,
+			&org.IsActive,
+			&org.AutoRegisterAttestedMCPs,
+			&org.ClientSideKeyGeneration,
+			pq.Array(&mfaRoles),
+			&settings,
+			&org.CreatedAt,
+			&org.UpdatedAt,
+			&parentID,
+			&residency,
+		); err != nil {
+			return nil, err
+		}
+		org.MFARequiredRoles = userRoles(mfaRoles)
+		org.ParentID = organizationParent(parentID)
+		org.DataResidency = domain.DataRegion(residency.String)
+		if err := json.Unmarshal(settings, &org.Settings); err != nil {
+			return nil, fmt.Errorf("failed to decode organization settings: %w", err)
+		}
+		orgs = append(orgs, org)
+	}
+
+	return orgs, rows.Err()
+}
+
+// organizationParent returns the parent of an organization row; nil for top-level organizations
+func organizationParent(parentID uuid.NullUUID) *uuid.UUID {
+	if !parentID.Valid {
+		return nil
+	}
+	return &parentID.UUID
+}
+
+// organizationResidencyValue stores the control plane's region, the empty residency, as NULL
+func organizationResidencyValue(region domain.DataRegion) sql.NullString {
+	return sql.NullString{String: string(region), Valid: region != ""}
+}
+
+// organizationSettingsJSON encodes an organization's settings; no settings are stored as {}
+func organizationSettingsJSON(settings map[string]interface{}) ([]byte, error) {
+	if settings == nil {
+		return []byte("{}"), nil
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode organization settings: %w", err)
+	}
+	return data, nil
+}
+
+func userRoleStrings(roles []domain.UserRole) []string {
+	values := make([]string, len(roles))
+	for i, role := range roles {
+		values[i] = string(role)
+	}
+	return values
+}
+
+func userRoles(values []string) []domain.UserRole {
+	if len(values) == 0 {
+		return nil
+	}
+	roles := make([]domain.UserRole, len(values))
+	for i, value := range values {
+		roles[i] = domain.UserRole(value)
+	}
+	return roles
+}
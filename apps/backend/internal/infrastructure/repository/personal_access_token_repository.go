@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// PersonalAccessTokenRepository implements domain.PersonalAccessTokenRepository
+type PersonalAccessTokenRepository struct {
+	db *sql.DB
+}
+
+// NewPersonalAccessTokenRepository creates a new personal access token repository
+func NewPersonalAccessTokenRepository(db *sql.DB) *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{db: db}
+}
+
+const personalAccessTokenColumns = `id, organization_id, user_id, name, token_hash, prefix, scopes, expires_at,
+	last_used_at, last_used_ip, created_at, revoked_at, revoked_by, revoke_reason`
+
+// Create stores a new personal access token
+func (r *PersonalAccessTokenRepository) Create(token *domain.PersonalAccessToken) error {
+	query := `
+		INSERT INTO personal_access_tokens (id, organization_id, user_id, name, token_hash, prefix, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.Exec(query,
+		token.ID,
+		token.OrganizationID,
+		token.UserID,
+		token.Name,
+		token.TokenHash,
+		token.Prefix,
+		pq.Array(token.Scopes),
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create personal access token: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a personal access token by ID
+func (r *PersonalAccessTokenRepository) GetByID(id uuid.UUID) (*domain.PersonalAccessToken, error) {
+	row := r.db.QueryRow(`SELECT `+personalAccessTokenColumns+` FROM personal_access_tokens WHERE id = $1`, id)
+	return scanPersonalAccessToken(row)
+}
+
+// GetByHash returns the personal access token with the token hash
+func (r *PersonalAccessTokenRepository) GetByHash(tokenHash string) (*domain.PersonalAccessToken, error) {
+	row := r.db.QueryRow(`SELECT `+personalAccessTokenColumns+` FROM personal_access_tokens WHERE token_hash = $1`, tokenHash)
+	return scanPersonalAccessToken(row)
+}
+
+// ListByUser returns the user's tokens, newest first
+func (r *PersonalAccessTokenRepository) ListByUser(userID uuid.UUID) ([]*domain.PersonalAccessToken, error) {
+	return r.list(`SELECT `+personalAccessTokenColumns+` FROM personal_access_tokens WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+}
+
+// ListByOrganization returns the tokens of every user of the organization, newest first
+func (r *PersonalAccessTokenRepository) ListByOrganization(orgID uuid.UUID) ([]*domain.PersonalAccessToken, error) {
+	return r.list(`SELECT `+personalAccessTokenColumns+` FROM personal_access_tokens WHERE organization_id = $1 ORDER BY created_at DESC`, orgID)
+}
+
+// RecordUsage records when and from where the token was last used
+func (r *PersonalAccessTokenRepository) RecordUsage(id uuid.UUID, ipAddress string, usedAt time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE personal_access_tokens
+		SET last_used_at = $1, last_used_ip = $2
+		WHERE id = $3
+	`, usedAt, ipAddress, id)
+	if err != nil {
+		return fmt.Errorf("failed to record personal access token usage: %w", err)
+	}
+	return nil
+}
+
+// Revoke revokes the token; revoking an already revoked token keeps the first revocation
+func (r *PersonalAccessTokenRepository) Revoke(id, revokedBy uuid.UUID, reason string) error {
+	result, err := r.db.Exec(`
+		UPDATE personal_access_tokens
+		SET revoked_at = COALESCE(revoked_at, $1),
+			revoked_by = COALESCE(revoked_by, $2),
+			revoke_reason = COALESCE(revoke_reason, $3)
+		WHERE id = $4
+	`, time.Now().UTC(), revokedBy, reason, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke personal access token: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrPersonalAccessTokenNotFound
+	}
+	return nil
+}
+
+func (r *PersonalAccessTokenRepository) list(query string, id uuid.UUID) ([]*domain.PersonalAccessToken, error) {
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list personal access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := make([]*domain.PersonalAccessToken, 0)
+	for rows.Next() {
+		token, err := scanPersonalAccessToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+func scanPersonalAccessToken(row interface{ Scan(...interface{}) error }) (*domain.PersonalAccessToken, error) {
+	token := &domain.PersonalAccessToken{}
+	err := row.Scan(
+		&token.ID,
+		&token.OrganizationID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenHash,
+		&token.Prefix,
+		pq.Array(&token.Scopes),
+		&token.ExpiresAt,
+		&token.LastUsedAt,
+		&token.LastUsedIP,
+		&token.CreatedAt,
+		&token.RevokedAt,
+		&token.RevokedBy,
+		&token.RevokeReason,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrPersonalAccessTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan personal access token: %w", err)
+	}
+	return token, nil
+}
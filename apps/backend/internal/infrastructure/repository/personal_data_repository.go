@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// PersonalDataRepository implements domain.PersonalDataRepository
+type PersonalDataRepository struct {
+	db *sql.DB
+}
+
+// NewPersonalDataRepository creates a new personal data repository
+func NewPersonalDataRepository(db *sql.DB) *PersonalDataRepository {
+	return &PersonalDataRepository{db: db}
+}
+
+// anonymizeStatements scrub a user's personal data, keyed by the table they change. Each takes the
+// user ID ($1), organization ID ($2), email ($3), name ($4), alias ($5) and erased name ($6).
+// Rows are rewritten, never deleted, so foreign keys and counts are unchanged; verification
+// event signatures and hashes are not touched.
+var anonymizeStatements = []struct {
+	table string
+	query string
+}{
+	{"users", `
+		UPDATE users
+		SET email = $5, name = $6, avatar_url = NULL, provider_id = $1::text, password_hash = NULL,
+		    password_reset_token = NULL, password_reset_expires_at = NULL,
+		    mfa_enabled = FALSE, mfa_secret = NULL, mfa_recovery_codes = '{}', mfa_enrolled_at = NULL,
+		    status = 'deactivated', deleted_at = COALESCE(deleted_at, NOW()), updated_at = NOW()
+		WHERE id = $1 AND email <> $5`},
+	// The user's own entries lose their IP address and user agent; any entry of the organization
+	// naming them in its metadata has the email and name replaced
+	{"audit_logs", `
+		UPDATE audit_logs
+		SET ip_address = CASE WHEN user_id = $1 THEN NULL ELSE ip_address END,
+		    user_agent = CASE WHEN user_id = $1 THEN NULL ELSE user_agent END,
+		    metadata = CASE WHEN jsonb_typeof(metadata) = 'object' THEN (
+		        SELECT COALESCE(jsonb_object_agg(key, CASE
+		            WHEN value = to_jsonb($3::text) THEN to_jsonb($5::text)
+		            WHEN $4::text <> '' AND value = to_jsonb($4::text) THEN to_jsonb($6::text)
+		            ELSE value END), '{}'::jsonb)
+		        FROM jsonb_each(metadata)) ELSE metadata END
+		WHERE organization_id = $2
+		  AND ((user_id = $1 AND (ip_address IS NOT NULL OR user_agent IS NOT NULL))
+		       OR (jsonb_typeof(metadata) = 'object' AND EXISTS (
+		           SELECT 1 FROM jsonb_each(metadata)
+		           WHERE value = to_jsonb($3::text) OR ($4::text <> '' AND value = to_jsonb($4::text))))))`},
+	{"verification_events", `
+		UPDATE verification_events
+		SET initiator_name = CASE WHEN initiator_name IS NULL THEN NULL ELSE $6 END,
+		    initiator_ip = NULL, initiator_user_agent = NULL
+		WHERE organization_id = $2
+		  AND (initiator_user_id = $1 OR (initiator_type = 'user' AND initiator_id = $1))
+		  AND ((initiator_name IS NOT NULL AND initiator_name <> $6)
+		       OR initiator_ip IS NOT NULL OR initiator_user_agent IS NOT NULL)`},
+	{"approval_requests", `
+		UPDATE approval_requests
+		SET decisions = (
+		    SELECT jsonb_agg(CASE WHEN decision->>'userId' = $1::text
+		        THEN jsonb_set(decision, '{userEmail}', to_jsonb($5::text)) ELSE decision END ORDER BY position)
+		    FROM jsonb_array_elements(decisions) WITH ORDINALITY AS d(decision, position))
+		WHERE organization_id = $2
+		  AND EXISTS (
+		      SELECT 1 FROM jsonb_array_elements(decisions) AS d(decision)
+		      WHERE decision->>'userId' = $1::text AND decision->>'userEmail' IS DISTINCT FROM $5)`},
+	{"entity_tombstones", `
+		UPDATE entity_tombstones SET deleted_by_email = $5
+		WHERE organization_id = $2 AND deleted_by = $1 AND deleted_by_email IS DISTINCT FROM $5`},
+	{"invitations", `
+		UPDATE invitations SET email = $5
+		WHERE organization_id = $2 AND LOWER(email) = LOWER($3)`},
+	{"sdk_tokens", `
+		UPDATE sdk_tokens
+		SET device_name = NULL, device_fingerprint = NULL, ip_address = NULL, user_agent = NULL, last_ip_address = NULL
+		WHERE user_id = $1
+		  AND (device_name IS NOT NULL OR device_fingerprint IS NOT NULL OR ip_address IS NOT NULL
+		       OR user_agent IS NOT NULL OR last_ip_address IS NOT NULL)`},
+	{"personal_access_tokens", `
+		UPDATE personal_access_tokens SET last_used_ip = NULL
+		WHERE user_id = $1 AND last_used_ip IS NOT NULL`},
+}
+
+// Anonymize runs every statement in one transaction and counts the rows each changed
+func (r *PersonalDataRepository) Anonymize(user *domain.User, alias string) (map[string]int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	counts := make(map[string]int, len(anonymizeStatements))
+	for _, statement := range anonymizeStatements {
+		result, err := tx.Exec(statement.query, user.ID, user.OrganizationID, user.Email, user.Name, alias, domain.ErasedUserName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to anonymize %s: %w", statement.table, err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		counts[statement.table] = int(affected)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,460 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// PlatformOperatorRepository implements domain.PlatformOperatorRepository
+type PlatformOperatorRepository struct {
+	db *sql.DB
+}
+
+// NewPlatformOperatorRepository creates a new platform operator repository
+func NewPlatformOperatorRepository(db *sql.DB) *PlatformOperatorRepository {
+	return &PlatformOperatorRepository{db: db}
+}
+
+const platformOperatorColumns = `id, email, name, role, token_hash, token_prefix, is_active, last_used_at, created_by, created_at, updated_at`
+
+const impersonationColumns = `id, organization_id, operator_id, operator_email, reason, role, duration_minutes, status, decided_by, decided_at, token_hash, started_at, expires_at, ended_at, created_at`
+
+// CreateOperator stores a new operator
+func (r *PlatformOperatorRepository) CreateOperator(operator *domain.PlatformOperator) error {
+	query := `
+		INSERT INTO platform_operators (` + platformOperatorColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	if operator.ID == uuid.Nil {
+		operator.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	operator.CreatedAt = now
+	operator.UpdatedAt = now
+
+	_, err := r.db.Exec(query,
+		operator.ID,
+		operator.Email,
+		operator.Name,
+		operator.Role,
+		operator.TokenHash,
+		operator.TokenPrefix,
+		operator.IsActive,
+		operator.LastUsedAt,
+		operator.CreatedBy,
+		operator.CreatedAt,
+		operator.UpdatedAt,
+	)
+	return err
+}
+
+// GetOperator retrieves an operator by ID
+func (r *PlatformOperatorRepository) GetOperator(id uuid.UUID) (*domain.PlatformOperator, error) {
+	query := `SELECT ` + platformOperatorColumns + ` FROM platform_operators WHERE id = $1`
+	return r.getOperator(query, id)
+}
+
+// GetOperatorByTokenHash retrieves the operator a token belongs to
+func (r *PlatformOperatorRepository) GetOperatorByTokenHash(tokenHash string) (*domain.PlatformOperator, error) {
+	query := `SELECT ` + platformOperatorColumns + ` FROM platform_operators WHERE token_hash = $1`
+	return r.getOperator(query, tokenHash)
+}
+
+func (r *PlatformOperatorRepository) getOperator(query string, arg interface{}) (*domain.PlatformOperator, error) {
+	operator, err := scanPlatformOperator(r.db.QueryRow(query, arg))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrPlatformOperatorNotFound
+	}
+	return operator, err
+}
+
+// ListOperators returns every operator, oldest first
+func (r *PlatformOperatorRepository) ListOperators() ([]*domain.PlatformOperator, error) {
+	rows, err := r.db.Query(`SELECT ` + platformOperatorColumns + ` FROM platform_operators ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	operators := make([]*domain.PlatformOperator, 0)
+	for rows.Next() {
+		operator, err := scanPlatformOperator(rows)
+		if err != nil {
+			return nil, err
+		}
+		operators = append(operators, operator)
+	}
+	return operators, rows.Err()
+}
+
+// UpdateOperator saves the operator's name, role, token and status
+func (r *PlatformOperatorRepository) UpdateOperator(operator *domain.PlatformOperator) error {
+	query := `
+		UPDATE platform_operators
+		SET name = $1, role = $2, token_hash = $3, token_prefix = $4, is_active = $5, updated_at = $6
+		WHERE id = $7
+	`
+	operator.UpdatedAt = time.Now().UTC()
+
+	result, err := r.db.Exec(query,
+		operator.Name,
+		operator.Role,
+		operator.TokenHash,
+		operator.TokenPrefix,
+		operator.IsActive,
+		operator.UpdatedAt,
+		operator.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrPlatformOperatorNotFound
+	}
+	return nil
+}
+
+// TouchOperator records when the operator last used their token
+func (r *PlatformOperatorRepository) TouchOperator(id uuid.UUID, usedAt time.Time) error {
+	_, err := r.db.Exec(`UPDATE platform_operators SET last_used_at = $1 WHERE id = $2`, usedAt, id)
+	return err
+}
+
+// RecordAction appends an entry to the operator log
+func (r *PlatformOperatorRepository) RecordAction(action *domain.OperatorAction) error {
+	query := `
+		INSERT INTO operator_actions (id, operator_id, action, organization_id, details, ip_address, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	if action.ID == uuid.Nil {
+		action.ID = uuid.New()
+	}
+	if action.CreatedAt.IsZero() {
+		action.CreatedAt = time.Now().UTC()
+	}
+
+	details, err := json.Marshal(action.Details)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query,
+		action.ID,
+		action.OperatorID,
+		action.Action,
+		action.OrganizationID,
+		details,
+		action.IPAddress,
+		action.CreatedAt,
+	)
+	return err
+}
+
+// ListActions returns the operator log, newest first
+func (r *PlatformOperatorRepository) ListActions(limit, offset int) ([]*domain.OperatorAction, error) {
+	query := `
+		SELECT id, operator_id, action, organization_id, details, ip_address, created_at
+		FROM operator_actions
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := r.db.Query(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := make([]*domain.OperatorAction, 0)
+	for rows.Next() {
+		action := &domain.OperatorAction{}
+		var orgID uuid.NullUUID
+		var details []byte
+		if err := rows.Scan(
+			&action.ID,
+			&action.OperatorID,
+			&action.Action,
+			&orgID,
+			&details,
+			&action.IPAddress,
+			&action.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if orgID.Valid {
+			action.OrganizationID = &orgID.UUID
+		}
+		if err := json.Unmarshal(details, &action.Details); err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, rows.Err()
+}
+
+// SetThrottle creates or replaces the organization's throttle
+func (r *PlatformOperatorRepository) SetThrottle(throttle *domain.OrganizationThrottle) error {
+	query := `
+		INSERT INTO organization_throttles (organization_id, requests_per_minute, reason, expires_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (organization_id) DO UPDATE
+		SET requests_per_minute = EXCLUDED.requests_per_minute, reason = EXCLUDED.reason,
+			expires_at = EXCLUDED.expires_at, created_by = EXCLUDED.created_by, created_at = EXCLUDED.created_at
+	`
+	throttle.CreatedAt = time.Now().UTC()
+
+	_, err := r.db.Exec(query,
+		throttle.OrganizationID,
+		throttle.RequestsPerMinute,
+		throttle.Reason,
+		throttle.ExpiresAt,
+		throttle.CreatedBy,
+		throttle.CreatedAt,
+	)
+	return err
+}
+
+// GetThrottle retrieves the organization's throttle
+func (r *PlatformOperatorRepository) GetThrottle(orgID uuid.UUID) (*domain.OrganizationThrottle, error) {
+	query := `
+		SELECT organization_id, requests_per_minute, reason, expires_at, created_by, created_at
+		FROM organization_throttles
+		WHERE organization_id = $1
+	`
+	throttle, err := scanOrganizationThrottle(r.db.QueryRow(query, orgID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrOrganizationThrottleNotFound
+	}
+	return throttle, err
+}
+
+// ListThrottles returns every throttle, newest first
+func (r *PlatformOperatorRepository) ListThrottles() ([]*domain.OrganizationThrottle, error) {
+	query := `
+		SELECT organization_id, requests_per_minute, reason, expires_at, created_by, created_at
+		FROM organization_throttles
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	throttles := make([]*domain.OrganizationThrottle, 0)
+	for rows.Next() {
+		throttle, err := scanOrganizationThrottle(rows)
+		if err != nil {
+			return nil, err
+		}
+		throttles = append(throttles, throttle)
+	}
+	return throttles, rows.Err()
+}
+
+// DeleteThrottle lifts the organization's throttle
+func (r *PlatformOperatorRepository) DeleteThrottle(orgID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM organization_throttles WHERE organization_id = $1`, orgID)
+	return err
+}
+
+// CreateImpersonation stores a new impersonation request
+func (r *PlatformOperatorRepository) CreateImpersonation(request *domain.ImpersonationRequest) error {
+	query := `
+		INSERT INTO impersonation_requests (` + impersonationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+	if request.ID == uuid.Nil {
+		request.ID = uuid.New()
+	}
+	if request.CreatedAt.IsZero() {
+		request.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.Exec(query,
+		request.ID,
+		request.OrganizationID,
+		request.OperatorID,
+		request.OperatorEmail,
+		request.Reason,
+		request.Role,
+		request.DurationMinutes,
+		request.Status,
+		request.DecidedBy,
+		request.DecidedAt,
+		nullString(request.TokenHash),
+		request.StartedAt,
+		request.ExpiresAt,
+		request.EndedAt,
+		request.CreatedAt,
+	)
+	return err
+}
+
+// GetImpersonation retrieves an impersonation request by ID
+func (r *PlatformOperatorRepository) GetImpersonation(id uuid.UUID) (*domain.ImpersonationRequest, error) {
+	query := `SELECT ` + impersonationColumns + ` FROM impersonation_requests WHERE id = $1`
+	return r.getImpersonation(query, id)
+}
+
+// GetImpersonationByTokenHash retrieves the impersonation a session token belongs to
+func (r *PlatformOperatorRepository) GetImpersonationByTokenHash(tokenHash string) (*domain.ImpersonationRequest, error) {
+	query := `SELECT ` + impersonationColumns + ` FROM impersonation_requests WHERE token_hash = $1`
+	return r.getImpersonation(query, tokenHash)
+}
+
+func (r *PlatformOperatorRepository) getImpersonation(query string, arg interface{}) (*domain.ImpersonationRequest, error) {
+	request, err := scanImpersonation(r.db.QueryRow(query, arg))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrImpersonationNotFound
+	}
+	return request, err
+}
+
+// ListImpersonations returns requests newest first, of one organization when orgID is set
+func (r *PlatformOperatorRepository) ListImpersonations(orgID *uuid.UUID, limit, offset int) ([]*domain.ImpersonationRequest, error) {
+	query := `
+		SELECT ` + impersonationColumns + `
+		FROM impersonation_requests
+		WHERE $1::uuid IS NULL OR organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(query, orgID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := make([]*domain.ImpersonationRequest, 0)
+	for rows.Next() {
+		request, err := scanImpersonation(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	return requests, rows.Err()
+}
+
+// UpdateImpersonation saves the request's decision and session
+func (r *PlatformOperatorRepository) UpdateImpersonation(request *domain.ImpersonationRequest) error {
+	query := `
+		UPDATE impersonation_requests
+		SET status = $1, decided_by = $2, decided_at = $3, token_hash = $4, started_at = $5, expires_at = $6, ended_at = $7
+		WHERE id = $8
+	`
+	_, err := r.db.Exec(query,
+		request.Status,
+		request.DecidedBy,
+		request.DecidedAt,
+		nullString(request.TokenHash),
+		request.StartedAt,
+		request.ExpiresAt,
+		request.EndedAt,
+		request.ID,
+	)
+	return err
+}
+
+func scanPlatformOperator(row rowScanner) (*domain.PlatformOperator, error) {
+	operator := &domain.PlatformOperator{}
+	var lastUsedAt sql.NullTime
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&operator.ID,
+		&operator.Email,
+		&operator.Name,
+		&operator.Role,
+		&operator.TokenHash,
+		&operator.TokenPrefix,
+		&operator.IsActive,
+		&lastUsedAt,
+		&createdBy,
+		&operator.CreatedAt,
+		&operator.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastUsedAt.Valid {
+		operator.LastUsedAt = &lastUsedAt.Time
+	}
+	if createdBy.Valid {
+		operator.CreatedBy = &createdBy.UUID
+	}
+	return operator, nil
+}
+
+func scanOrganizationThrottle(row rowScanner) (*domain.OrganizationThrottle, error) {
+	throttle := &domain.OrganizationThrottle{}
+	var expiresAt sql.NullTime
+
+	err := row.Scan(
+		&throttle.OrganizationID,
+		&throttle.RequestsPerMinute,
+		&throttle.Reason,
+		&expiresAt,
+		&throttle.CreatedBy,
+		&throttle.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid {
+		throttle.ExpiresAt = &expiresAt.Time
+	}
+	return throttle, nil
+}
+
+func scanImpersonation(row rowScanner) (*domain.ImpersonationRequest, error) {
+	request := &domain.ImpersonationRequest{}
+	var decidedBy uuid.NullUUID
+	var tokenHash sql.NullString
+	var decidedAt, startedAt, expiresAt, endedAt sql.NullTime
+
+	err := row.Scan(
+		&request.ID,
+		&request.OrganizationID,
+		&request.OperatorID,
+		&request.OperatorEmail,
+		&request.Reason,
+		&request.Role,
+		&request.DurationMinutes,
+		&request.Status,
+		&decidedBy,
+		&decidedAt,
+		&tokenHash,
+		&startedAt,
+		&expiresAt,
+		&endedAt,
+		&request.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if decidedBy.Valid {
+		request.DecidedBy = &decidedBy.UUID
+	}
+	request.TokenHash = tokenHash.String
+	if decidedAt.Valid {
+		request.DecidedAt = &decidedAt.Time
+	}
+	if startedAt.Valid {
+		request.StartedAt = &startedAt.Time
+	}
+	if expiresAt.Valid {
+		request.ExpiresAt = &expiresAt.Time
+	}
+	if endedAt.Valid {
+		request.EndedAt = &endedAt.Time
+	}
+	return request, nil
+}
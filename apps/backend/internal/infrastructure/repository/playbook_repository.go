@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// PlaybookRepository implements domain.PlaybookRepository
+type PlaybookRepository struct {
+	db *sql.DB
+}
+
+// NewPlaybookRepository creates a new playbook repository
+func NewPlaybookRepository(db *sql.DB) *PlaybookRepository {
+	return &PlaybookRepository{db: db}
+}
+
+const playbookColumns = `id, organization_id, name, description, incident_types, min_severity, steps,
+	is_enabled, created_by, created_at, updated_at`
+
+const playbookExecutionColumns = `id, organization_id, playbook_id, playbook_name, incident_id, status,
+	current_step, steps, log, triggered_by, started_at, updated_at, completed_at`
+
+// CreatePlaybook stores a new playbook
+func (r *PlaybookRepository) CreatePlaybook(playbook *domain.Playbook) error {
+	if playbook.ID == uuid.Nil {
+		playbook.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	playbook.CreatedAt = now
+	playbook.UpdatedAt = now
+
+	stepsJSON, err := json.Marshal(nonNilSlice(playbook.Steps))
+	if err != nil {
+		return fmt.Errorf("failed to marshal steps: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO playbooks (`+playbookColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		playbook.ID,
+		playbook.OrganizationID,
+		playbook.Name,
+		playbook.Description,
+		pq.Array(nonNilSlice(playbook.IncidentTypes)),
+		playbook.MinSeverity,
+		stepsJSON,
+		playbook.IsEnabled,
+		playbook.CreatedBy,
+		playbook.CreatedAt,
+		playbook.UpdatedAt,
+	)
+	return err
+}
+
+// GetPlaybook retrieves a playbook by ID
+func (r *PlaybookRepository) GetPlaybook(id uuid.UUID) (*domain.Playbook, error) {
+	playbook, err := scanPlaybook(r.db.QueryRow(`SELECT `+playbookColumns+` FROM playbooks WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrPlaybookNotFound
+	}
+	return playbook, err
+}
+
+// GetPlaybooksByOrganization lists an organization's playbooks, oldest first
+func (r *PlaybookRepository) GetPlaybooksByOrganization(orgID uuid.UUID) ([]*domain.Playbook, error) {
+	rows, err := r.db.Query(`
+		SELECT `+playbookColumns+`
+		FROM playbooks
+		WHERE organization_id = $1
+		ORDER BY created_at ASC
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var playbooks []*domain.Playbook
+	for rows.Next() {
+		playbook, err := scanPlaybook(rows)
+		if err != nil {
+			return nil, err
+		}
+		playbooks = append(playbooks, playbook)
+	}
+	return playbooks, rows.Err()
+}
+
+// UpdatePlaybook stores the editable fields of a playbook
+func (r *PlaybookRepository) UpdatePlaybook(playbook *domain.Playbook) error {
+	stepsJSON, err := json.Marshal(nonNilSlice(playbook.Steps))
+	if err != nil {
+		return fmt.Errorf("failed to marshal steps: %w", err)
+	}
+	playbook.UpdatedAt = time.Now().UTC()
+
+	result, err := r.db.Exec(`
+		UPDATE playbooks
+		SET name = $1, description = $2, incident_types = $3, min_severity = $4, steps = $5, is_enabled = $6, updated_at = $7
+		WHERE id = $8
+	`, playbook.Name, playbook.Description, pq.Array(nonNilSlice(playbook.IncidentTypes)), playbook.MinSeverity,
+		stepsJSON, playbook.IsEnabled, playbook.UpdatedAt, playbook.ID)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrPlaybookNotFound
+	}
+	return nil
+}
+
+// DeletePlaybook removes a playbook; its executions are kept
+func (r *PlaybookRepository) DeletePlaybook(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM playbooks WHERE id = $1`, id)
+	return err
+}
+
+// CreateExecution stores a new playbook execution
+func (r *PlaybookRepository) CreateExecution(execution *domain.PlaybookExecution) error {
+	if execution.ID == uuid.Nil {
+		execution.ID = uuid.New()
+	}
+
+	stepsJSON, logJSON, err := marshalPlaybookExecution(execution)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO playbook_executions (`+playbookExecutionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`,
+		execution.ID,
+		execution.OrganizationID,
+		execution.PlaybookID,
+		execution.PlaybookName,
+		execution.IncidentID,
+		execution.Status,
+		execution.CurrentStep,
+		stepsJSON,
+		logJSON,
+		execution.TriggeredBy,
+		execution.StartedAt,
+		execution.UpdatedAt,
+		execution.CompletedAt,
+	)
+	return err
+}
+
+// GetExecution retrieves a playbook execution by ID
+func (r *PlaybookRepository) GetExecution(id uuid.UUID) (*domain.PlaybookExecution, error) {
+	execution, err := scanPlaybookExecution(r.db.QueryRow(`SELECT `+playbookExecutionColumns+` FROM playbook_executions WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrPlaybookExecutionNotFound
+	}
+	return execution, err
+}
+
+// GetExecutionsByOrganization lists a page of an organization's executions, newest first
+func (r *PlaybookRepository) GetExecutionsByOrganization(orgID uuid.UUID, playbookID *uuid.UUID, limit, offset int) ([]*domain.PlaybookExecution, int, error) {
+	where := `WHERE organization_id = $1`
+	args := []interface{}{orgID}
+	if playbookID != nil {
+		where += ` AND playbook_id = $2`
+		args = append(args, *playbookID)
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM playbook_executions `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM playbook_executions %s ORDER BY started_at DESC LIMIT $%d OFFSET $%d`,
+		playbookExecutionColumns, where, len(args)+1, len(args)+2)
+	rows, err := r.db.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var executions []*domain.PlaybookExecution
+	for rows.Next() {
+		execution, err := scanPlaybookExecution(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		executions = append(executions, execution)
+	}
+	return executions, total, rows.Err()
+}
+
+// UpdateExecution stores the progress of a playbook execution
+func (r *PlaybookRepository) UpdateExecution(execution *domain.PlaybookExecution) error {
+	stepsJSON, logJSON, err := marshalPlaybookExecution(execution)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE playbook_executions
+		SET status = $1, current_step = $2, steps = $3, log = $4, updated_at = $5, completed_at = $6
+		WHERE id = $7
+	`, execution.Status, execution.CurrentStep, stepsJSON, logJSON, execution.UpdatedAt, execution.CompletedAt, execution.ID)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrPlaybookExecutionNotFound
+	}
+	return nil
+}
+
+func marshalPlaybookExecution(execution *domain.PlaybookExecution) ([]byte, []byte, error) {
+	stepsJSON, err := json.Marshal(nonNilSlice(execution.Steps))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal steps: %w", err)
+	}
+	logJSON, err := json.Marshal(nonNilSlice(execution.Log))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal log: %w", err)
+	}
+	return stepsJSON, logJSON, nil
+}
+
+func scanPlaybook(row interface{ Scan(...interface{}) error }) (*domain.Playbook, error) {
+	playbook := &domain.Playbook{}
+	var stepsJSON []byte
+
+	err := row.Scan(
+		&playbook.ID,
+		&playbook.OrganizationID,
+		&playbook.Name,
+		&playbook.Description,
+		pq.Array(&playbook.IncidentTypes),
+		&playbook.MinSeverity,
+		&stepsJSON,
+		&playbook.IsEnabled,
+		&playbook.CreatedBy,
+		&playbook.CreatedAt,
+		&playbook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(stepsJSON, &playbook.Steps); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal steps: %w", err)
+	}
+	if playbook.IncidentTypes == nil {
+		playbook.IncidentTypes = []string{}
+	}
+	return playbook, nil
+}
+
+func scanPlaybookExecution(row interface{ Scan(...interface{}) error }) (*domain.PlaybookExecution, error) {
+	execution := &domain.PlaybookExecution{}
+	var stepsJSON, logJSON []byte
+	var triggeredBy uuid.NullUUID
+	var completedAt sql.NullTime
+
+	err := row.Scan(
+		&execution.ID,
+		&execution.OrganizationID,
+		&execution.PlaybookID,
+		&execution.PlaybookName,
+		&execution.IncidentID,
+		&execution.Status,
+		&execution.CurrentStep,
+		&stepsJSON,
+		&logJSON,
+		&triggeredBy,
+		&execution.StartedAt,
+		&execution.UpdatedAt,
+		&completedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(stepsJSON, &execution.Steps); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal steps: %w", err)
+	}
+	if err := json.Unmarshal(logJSON, &execution.Log); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal log: %w", err)
+	}
+	if triggeredBy.Valid {
+		execution.TriggeredBy = &triggeredBy.UUID
+	}
+	if completedAt.Valid {
+		execution.CompletedAt = &completedAt.Time
+	}
+	return execution, nil
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// PolicyDecisionRepository implements domain.PolicyDecisionRepository
+type PolicyDecisionRepository struct {
+	db *sql.DB
+}
+
+// NewPolicyDecisionRepository creates a new policy decision repository
+func NewPolicyDecisionRepository(db *sql.DB) *PolicyDecisionRepository {
+	return &PolicyDecisionRepository{db: db}
+}
+
+const policyDecisionColumns = `id, organization_id, agent_id, audit_id, action_type, resource, native_allowed, native_reason, pdp_id, pdp_mode, external_allowed, external_reason, external_error, external_duration_ms, final_allowed, decision_source, created_at`
+
+// GetPDPByOrganization retrieves the organization's external policy decision point, or nil if none is configured
+func (r *PolicyDecisionRepository) GetPDPByOrganization(orgID uuid.UUID) (*domain.ExternalPolicyDecisionPoint, error) {
+	query := `
+		SELECT id, organization_id, provider, endpoint, decision_path, auth_token, mode, fail_open, timeout_ms,
+			is_enabled, created_by, created_at, updated_at
+		FROM external_policy_decision_points
+		WHERE organization_id = $1
+	`
+
+	pdp := &domain.ExternalPolicyDecisionPoint{}
+	var authToken sql.NullString
+	var createdBy uuid.NullUUID
+
+	err := r.db.QueryRow(query, orgID).Scan(
+		&pdp.ID,
+		&pdp.OrganizationID,
+		&pdp.Provider,
+		&pdp.Endpoint,
+		&pdp.DecisionPath,
+		&authToken,
+		&pdp.Mode,
+		&pdp.FailOpen,
+		&pdp.TimeoutMs,
+		&pdp.IsEnabled,
+		&createdBy,
+		&pdp.CreatedAt,
+		&pdp.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pdp.AuthToken = authToken.String
+	pdp.HasAuthToken = authToken.String != ""
+	if createdBy.Valid {
+		pdp.CreatedBy = createdBy.UUID
+	}
+
+	return pdp, nil
+}
+
+// UpsertPDP creates or replaces the organization's external policy decision point
+func (r *PolicyDecisionRepository) UpsertPDP(pdp *domain.ExternalPolicyDecisionPoint) error {
+	query := `
+		INSERT INTO external_policy_decision_points (
+			id, organization_id, provider, endpoint, decision_path, auth_token, mode, fail_open, timeout_ms,
+			is_enabled, created_by, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			provider = EXCLUDED.provider,
+			endpoint = EXCLUDED.endpoint,
+			decision_path = EXCLUDED.decision_path,
+			auth_token = EXCLUDED.auth_token,
+			mode = EXCLUDED.mode,
+			fail_open = EXCLUDED.fail_open,
+			timeout_ms = EXCLUDED.timeout_ms,
+			is_enabled = EXCLUDED.is_enabled,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at, updated_at
+	`
+
+	if pdp.ID == uuid.Nil {
+		pdp.ID = uuid.New()
+	}
+	pdp.HasAuthToken = pdp.AuthToken != ""
+
+	return r.db.QueryRow(query,
+		pdp.ID,
+		pdp.OrganizationID,
+		pdp.Provider,
+		pdp.Endpoint,
+		pdp.DecisionPath,
+		nullString(pdp.AuthToken),
+		pdp.Mode,
+		pdp.FailOpen,
+		pdp.TimeoutMs,
+		pdp.IsEnabled,
+		pdp.CreatedBy,
+		time.Now().UTC(),
+	).Scan(&pdp.ID, &pdp.CreatedAt, &pdp.UpdatedAt)
+}
+
+// DeletePDP removes the organization's external policy decision point (recorded decisions are kept)
+func (r *PolicyDecisionRepository) DeletePDP(orgID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM external_policy_decision_points WHERE organization_id = $1`, orgID)
+	return err
+}
+
+// CreateDecision records an authorization decision
+func (r *PolicyDecisionRepository) CreateDecision(decision *domain.PolicyDecisionRecord) error {
+	query := `
+		INSERT INTO policy_decisions (` + policyDecisionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`
+
+	if decision.ID == uuid.Nil {
+		decision.ID = uuid.New()
+	}
+	decision.CreatedAt = time.Now().UTC()
+
+	_, err := r.db.Exec(query,
+		decision.ID,
+		decision.OrganizationID,
+		decision.AgentID,
+		decision.AuditID,
+		decision.ActionType,
+		decision.Resource,
+		decision.NativeAllowed,
+		decision.NativeReason,
+		decision.PDPID,
+		decision.PDPMode,
+		decision.ExternalAllowed,
+		decision.ExternalReason,
+		decision.ExternalError,
+		decision.ExternalDurationMs,
+		decision.FinalAllowed,
+		decision.DecisionSource,
+		decision.CreatedAt,
+	)
+	return err
+}
+
+// GetDecisionsByOrganization retrieves recorded decisions, newest first, optionally for one agent
+func (r *PolicyDecisionRepository) GetDecisionsByOrganization(orgID uuid.UUID, agentID *uuid.UUID, limit, offset int) ([]*domain.PolicyDecisionRecord, int, error) {
+	where := `WHERE organization_id = $1`
+	args := []interface{}{orgID}
+	if agentID != nil {
+		where += ` AND agent_id = $2`
+		args = append(args, *agentID)
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM policy_decisions `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM policy_decisions %s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`,
+		policyDecisionColumns, where, len(args)+1, len(args)+2)
+	rows, err := r.db.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var decisions []*domain.PolicyDecisionRecord
+	for rows.Next() {
+		decision := &domain.PolicyDecisionRecord{}
+		var agent uuid.NullUUID
+		var nativeReason sql.NullString
+		var resource sql.NullString
+
+		err := rows.Scan(
+			&decision.ID,
+			&decision.OrganizationID,
+			&agent,
+			&decision.AuditID,
+			&decision.ActionType,
+			&resource,
+			&decision.NativeAllowed,
+			&nativeReason,
+			&decision.PDPID,
+			&decision.PDPMode,
+			&decision.ExternalAllowed,
+			&decision.ExternalReason,
+			&decision.ExternalError,
+			&decision.ExternalDurationMs,
+			&decision.FinalAllowed,
+			&decision.DecisionSource,
+			&decision.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		decision.AgentID = agent.UUID
+		decision.Resource = resource.String
+		decision.NativeReason = nativeReason.String
+		decisions = append(decisions, decision)
+	}
+
+	return decisions, total, rows.Err()
+}
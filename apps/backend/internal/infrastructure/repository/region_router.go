@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// RegionRouter finds the region an organization's agents, verification events and MCP
+// attestations are stored in. Organizations are routed by their residency; records looked up by
+// ID are routed by the placement the control plane recorded when they were created. Records
+// created before placements were tracked, and organizations without a residency, belong to the
+// home region: the region of the control plane's own database. MCP servers stay in the control
+// plane; records about one are routed by the residency of its organization.
+type RegionRouter struct {
+	home       domain.DataRegion
+	orgs       domain.OrganizationRepository
+	servers    domain.MCPServerRepository
+	placements domain.DataPlacementRepository
+}
+
+// NewRegionRouter creates a router over the control plane's organizations, MCP servers and
+// placements
+func NewRegionRouter(
+	home domain.DataRegion,
+	orgs domain.OrganizationRepository,
+	servers domain.MCPServerRepository,
+	placements domain.DataPlacementRepository,
+) *RegionRouter {
+	return &RegionRouter{home: home, orgs: orgs, servers: servers, placements: placements}
+}
+
+// organizationRegion returns the region the organization is resident in
+func (r *RegionRouter) organizationRegion(orgID uuid.UUID) (domain.DataRegion, error) {
+	org, err := r.orgs.GetByID(orgID)
+	if err != nil {
+		return "", err
+	}
+	if org.DataResidency == "" {
+		return r.home, nil
+	}
+	return org.DataResidency, nil
+}
+
+// mcpServerRegion returns the MCP server's organization and the region it is resident in
+func (r *RegionRouter) mcpServerRegion(serverID uuid.UUID) (uuid.UUID, domain.DataRegion, error) {
+	server, err := r.servers.GetByID(serverID)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	region, err := r.organizationRegion(server.OrganizationID)
+	return server.OrganizationID, region, err
+}
+
+// placedRegion returns the region the record was stored in
+func (r *RegionRouter) placedRegion(resourceID uuid.UUID) (domain.DataRegion, error) {
+	placement, err := r.placements.Get(resourceID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get placement of %s: %w", resourceID, err)
+	}
+	if placement == nil {
+		return r.home, nil
+	}
+	return placement.Region, nil
+}
+
+// placedRegionOfAll returns the one region all the records were stored in; records in different
+// regions are a cross-region query
+func (r *RegionRouter) placedRegionOfAll(resourceIDs []uuid.UUID) (domain.DataRegion, error) {
+	if len(resourceIDs) == 0 {
+		return r.home, nil
+	}
+	placements, err := r.placements.GetMany(resourceIDs)
+	if err != nil {
+		return "", fmt.Errorf("failed to get placements: %w", err)
+	}
+	region := r.home
+	if len(placements) > 0 {
+		region = placements[0].Region
+	}
+	for _, placement := range placements {
+		if placement.Region != region {
+			return "", domain.ErrCrossRegionQuery
+		}
+	}
+	// Records without a placement are in the home region
+	if len(placements) < len(uniqueIDs(resourceIDs)) && region != r.home {
+		return "", domain.ErrCrossRegionQuery
+	}
+	return region, nil
+}
+
+// place records the region a new record of the organization was stored in
+func (r *RegionRouter) place(kind domain.DataPlacementKind, resourceID, orgID uuid.UUID, region domain.DataRegion) error {
+	err := r.placements.Record(&domain.DataPlacement{
+		ResourceID:     resourceID,
+		Kind:           kind,
+		OrganizationID: orgID,
+		Region:         region,
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record placement of %s %s in region %s: %w", kind, resourceID, region, err)
+	}
+	return nil
+}
+
+// sameRegion checks that records about to be stored or read together are in one region
+func sameRegion(region domain.DataRegion, others ...domain.DataRegion) error {
+	for _, other := range others {
+		if other != region {
+			return fmt.Errorf("%w: %s and %s", domain.ErrCrossRegionQuery, region, other)
+		}
+	}
+	return nil
+}
+
+// regionalRepository returns the repository of the region's cluster
+func regionalRepository[T any](repos map[domain.DataRegion]T, region domain.DataRegion) (T, error) {
+	repo, ok := repos[region]
+	if !ok {
+		var none T
+		return none, fmt.Errorf("%w: %s", domain.ErrRegionUnavailable, region)
+	}
+	return repo, nil
+}
+
+func uniqueIDs(ids []uuid.UUID) map[uuid.UUID]bool {
+	unique := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+	return unique
+}
+
+// regionsOf returns the regions with a repository in a fixed order, for work done in every region
+func regionsOf[T any](repos map[domain.DataRegion]T) []domain.DataRegion {
+	regions := make([]domain.DataRegion, 0, len(repos))
+	for region := range repos {
+		regions = append(regions, region)
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i] < regions[j] })
+	return regions
+}
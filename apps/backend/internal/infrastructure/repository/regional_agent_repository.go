@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.MCPServerAgentRepository = (*RegionalAgentRepository)(nil)
+
+// RegionalAgentRepository stores each organization's agents in the cluster of its region
+type RegionalAgentRepository struct {
+	router  *RegionRouter
+	regions map[domain.DataRegion]domain.AgentRepository
+}
+
+// NewRegionalAgentRepository routes agents to the repository of their organization's region
+func NewRegionalAgentRepository(router *RegionRouter, regions map[domain.DataRegion]domain.AgentRepository) *RegionalAgentRepository {
+	return &RegionalAgentRepository{router: router, regions: regions}
+}
+
+func (r *RegionalAgentRepository) forOrganization(orgID uuid.UUID) (domain.AgentRepository, error) {
+	region, err := r.router.organizationRegion(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return regionalRepository(r.regions, region)
+}
+
+func (r *RegionalAgentRepository) forAgent(agentID uuid.UUID) (domain.AgentRepository, error) {
+	region, err := r.router.placedRegion(agentID)
+	if err != nil {
+		return nil, err
+	}
+	return regionalRepository(r.regions, region)
+}
+
+func (r *RegionalAgentRepository) Create(agent *domain.Agent) error {
+	region, err := r.router.organizationRegion(agent.OrganizationID)
+	if err != nil {
+		return err
+	}
+	repo, err := regionalRepository(r.regions, region)
+	if err != nil {
+		return err
+	}
+	if err := repo.Create(agent); err != nil {
+		return err
+	}
+	if err := r.router.place(domain.DataPlacementAgent, agent.ID, agent.OrganizationID, region); err != nil {
+		// An agent without a placement could not be found again
+		if deleteErr := repo.Delete(agent.ID); deleteErr != nil {
+			return fmt.Errorf("%w (and failed to remove the agent: %v)", err, deleteErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *RegionalAgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
+	repo, err := r.forAgent(id)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByID(id)
+}
+
+// GetByIDs returns ErrCrossRegionQuery for agents of different regions
+func (r *RegionalAgentRepository) GetByIDs(ids []uuid.UUID) ([]*domain.Agent, error) {
+	region, err := r.router.placedRegionOfAll(ids)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := regionalRepository(r.regions, region)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByIDs(ids)
+}
+
+func (r *RegionalAgentRepository) GetByName(orgID uuid.UUID, name string) (*domain.Agent, error) {
+	repo, err := r.forOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByName(orgID, name)
+}
+
+func (r *RegionalAgentRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Agent, error) {
+	repo, err := r.forOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByOrganization(orgID)
+}
+
+func (r *RegionalAgentRepository) Update(agent *domain.Agent) error {
+	repo, err := r.forAgent(agent.ID)
+	if err != nil {
+		return err
+	}
+	return repo.Update(agent)
+}
+
+func (r *RegionalAgentRepository) Delete(id uuid.UUID) error {
+	repo, err := r.forAgent(id)
+	if err != nil {
+		return err
+	}
+	if err := repo.Delete(id); err != nil {
+		return err
+	}
+	return r.router.placements.Delete(id)
+}
+
+// List pages through the agents of every organization, so it is only allowed with one region
+func (r *RegionalAgentRepository) List(limit, offset int) ([]*domain.Agent, error) {
+	if len(r.regions) != 1 {
+		return nil, domain.ErrCrossRegionQuery
+	}
+	return r.regions[regionsOf(r.regions)[0]].List(limit, offset)
+}
+
+func (r *RegionalAgentRepository) UpdateTrustScore(id uuid.UUID, newScore float64) error {
+	repo, err := r.forAgent(id)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateTrustScore(id, newScore)
+}
+
+func (r *RegionalAgentRepository) FreezeTrustScore(id uuid.UUID) error {
+	repo, err := r.forAgent(id)
+	if err != nil {
+		return err
+	}
+	return repo.FreezeTrustScore(id)
+}
+
+func (r *RegionalAgentRepository) AddTalksTo(id uuid.UUID, mcpServer string) (bool, error) {
+	repo, err := r.forAgent(id)
+	if err != nil {
+		return false, err
+	}
+	return repo.AddTalksTo(id, mcpServer)
+}
+
+func (r *RegionalAgentRepository) MarkAsCompromised(id uuid.UUID) error {
+	repo, err := r.forAgent(id)
+	if err != nil {
+		return err
+	}
+	return repo.MarkAsCompromised(id)
+}
+
+func (r *RegionalAgentRepository) UpdateLastActive(ctx context.Context, agentID uuid.UUID) error {
+	repo, err := r.forAgent(agentID)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateLastActive(ctx, agentID)
+}
+
+func (r *RegionalAgentRepository) RotateKey(agent *domain.Agent) error {
+	repo, err := r.forAgent(agent.ID)
+	if err != nil {
+		return err
+	}
+	return repo.RotateKey(agent)
+}
+
+func (r *RegionalAgentRepository) DropEscrowedPrivateKeys(orgID uuid.UUID) (int, error) {
+	repo, err := r.forOrganization(orgID)
+	if err != nil {
+		return 0, err
+	}
+	return repo.DropEscrowedPrivateKeys(orgID)
+}
+
+func (r *RegionalAgentRepository) GetByMCPServer(mcpServerID uuid.UUID, orgID uuid.UUID) ([]*domain.Agent, error) {
+	repo, err := r.forMCPServerLookup(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByMCPServer(mcpServerID, orgID)
+}
+
+func (r *RegionalAgentRepository) GetByMCPServerName(mcpServerName string, orgID uuid.UUID) ([]*domain.Agent, error) {
+	repo, err := r.forMCPServerLookup(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByMCPServerName(mcpServerName, orgID)
+}
+
+func (r *RegionalAgentRepository) forMCPServerLookup(orgID uuid.UUID) (domain.MCPServerAgentRepository, error) {
+	repo, err := r.forOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	lookup, ok := repo.(domain.MCPServerAgentRepository)
+	if !ok {
+		return nil, fmt.Errorf("agent repository of the organization's region cannot find agents by MCP server")
+	}
+	return lookup, nil
+}
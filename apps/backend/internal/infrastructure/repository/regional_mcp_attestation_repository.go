@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.MCPAttestationRepository = (*RegionalMCPAttestationRepository)(nil)
+
+// RegionalMCPAttestationRepository stores each organization's MCP attestations in the cluster of
+// its region. Agent-MCP connections, network relays and the confidence scores of MCP servers stay
+// in the control plane, alongside the servers.
+type RegionalMCPAttestationRepository struct {
+	router  *RegionRouter
+	regions map[domain.DataRegion]domain.MCPAttestationRepository
+}
+
+// NewRegionalMCPAttestationRepository routes MCP attestations to the repository of their
+// organization's region; the home region's repository is the control plane's
+func NewRegionalMCPAttestationRepository(router *RegionRouter, regions map[domain.DataRegion]domain.MCPAttestationRepository) *RegionalMCPAttestationRepository {
+	return &RegionalMCPAttestationRepository{router: router, regions: regions}
+}
+
+func (r *RegionalMCPAttestationRepository) controlPlane() (domain.MCPAttestationRepository, error) {
+	return regionalRepository(r.regions, r.router.home)
+}
+
+func (r *RegionalMCPAttestationRepository) forMCP(mcpServerID uuid.UUID) (domain.MCPAttestationRepository, error) {
+	_, region, err := r.router.mcpServerRegion(mcpServerID)
+	if err != nil {
+		return nil, err
+	}
+	return regionalRepository(r.regions, region)
+}
+
+// forPlaced routes by the placement of an attestation or an agent
+func (r *RegionalMCPAttestationRepository) forPlaced(resourceID uuid.UUID) (domain.MCPAttestationRepository, error) {
+	region, err := r.router.placedRegion(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	return regionalRepository(r.regions, region)
+}
+
+// CreateAttestation returns ErrCrossRegionQuery for an attestation by an agent stored in another
+// region than the MCP server's organization
+func (r *RegionalMCPAttestationRepository) CreateAttestation(attestation *domain.MCPAttestation) error {
+	orgID, region, err := r.router.mcpServerRegion(attestation.MCPServerID)
+	if err != nil {
+		return err
+	}
+	if attestation.AgentID != nil {
+		agentRegion, err := r.router.placedRegion(*attestation.AgentID)
+		if err != nil {
+			return err
+		}
+		if err := sameRegion(region, agentRegion); err != nil {
+			return err
+		}
+	}
+	repo, err := regionalRepository(r.regions, region)
+	if err != nil {
+		return err
+	}
+	if err := repo.CreateAttestation(attestation); err != nil {
+		return err
+	}
+	if err := r.router.place(domain.DataPlacementMCPAttestation, attestation.ID, orgID, region); err != nil {
+		// An attestation without a placement could not be found by ID, so it must not count
+		// towards the server's confidence either
+		if invalidateErr := repo.InvalidateAttestation(attestation.ID); invalidateErr != nil {
+			return fmt.Errorf("%w (and failed to invalidate the attestation: %v)", err, invalidateErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *RegionalMCPAttestationRepository) GetAttestationByID(id uuid.UUID) (*domain.MCPAttestation, error) {
+	repo, err := r.forPlaced(id)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAttestationByID(id)
+}
+
+func (r *RegionalMCPAttestationRepository) GetAttestationsByMCP(mcpServerID uuid.UUID) ([]*domain.MCPAttestation, error) {
+	repo, err := r.forMCP(mcpServerID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAttestationsByMCP(mcpServerID)
+}
+
+func (r *RegionalMCPAttestationRepository) GetValidAttestationsByMCP(mcpServerID uuid.UUID) ([]*domain.MCPAttestation, error) {
+	repo, err := r.forMCP(mcpServerID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetValidAttestationsByMCP(mcpServerID)
+}
+
+func (r *RegionalMCPAttestationRepository) GetAttestationsByAgent(agentID uuid.UUID) ([]*domain.MCPAttestation, error) {
+	repo, err := r.forPlaced(agentID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAttestationsByAgent(agentID)
+}
+
+func (r *RegionalMCPAttestationRepository) InvalidateAttestation(id uuid.UUID) error {
+	repo, err := r.forPlaced(id)
+	if err != nil {
+		return err
+	}
+	return repo.InvalidateAttestation(id)
+}
+
+// InvalidateExpiredAttestations expires the attestations of each region in turn
+func (r *RegionalMCPAttestationRepository) InvalidateExpiredAttestations() ([]*domain.MCPAttestation, error) {
+	var expired []*domain.MCPAttestation
+	for _, region := range regionsOf(r.regions) {
+		regional, err := r.regions[region].InvalidateExpiredAttestations()
+		if err != nil {
+			return expired, fmt.Errorf("failed to expire attestations in region %s: %w", region, err)
+		}
+		expired = append(expired, regional...)
+	}
+	return expired, nil
+}
+
+func (r *RegionalMCPAttestationRepository) CreateConnection(connection *domain.AgentMCPConnection) error {
+	repo, err := r.controlPlane()
+	if err != nil {
+		return err
+	}
+	return repo.CreateConnection(connection)
+}
+
+func (r *RegionalMCPAttestationRepository) GetConnectionByID(id uuid.UUID) (*domain.AgentMCPConnection, error) {
+	repo, err := r.controlPlane()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetConnectionByID(id)
+}
+
+func (r *RegionalMCPAttestationRepository) GetConnectionByAgentAndMCP(agentID, mcpServerID uuid.UUID) (*domain.AgentMCPConnection, error) {
+	repo, err := r.controlPlane()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetConnectionByAgentAndMCP(agentID, mcpServerID)
+}
+
+func (r *RegionalMCPAttestationRepository) GetConnectionsByAgent(agentID uuid.UUID) ([]*domain.AgentMCPConnection, error) {
+	repo, err := r.controlPlane()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetConnectionsByAgent(agentID)
+}
+
+func (r *RegionalMCPAttestationRepository) GetConnectionsByMCP(mcpServerID uuid.UUID) ([]*domain.AgentMCPConnection, error) {
+	repo, err := r.controlPlane()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetConnectionsByMCP(mcpServerID)
+}
+
+func (r *RegionalMCPAttestationRepository) UpdateConnection(connection *domain.AgentMCPConnection) error {
+	repo, err := r.controlPlane()
+	if err != nil {
+		return err
+	}
+	return repo.UpdateConnection(connection)
+}
+
+func (r *RegionalMCPAttestationRepository) DeleteConnection(id uuid.UUID) error {
+	repo, err := r.controlPlane()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteConnection(id)
+}
+
+func (r *RegionalMCPAttestationRepository) CreateRelay(relay *domain.MCPNetworkRelay) error {
+	repo, err := r.controlPlane()
+	if err != nil {
+		return err
+	}
+	return repo.CreateRelay(relay)
+}
+
+func (r *RegionalMCPAttestationRepository) GetRelayByID(id uuid.UUID) (*domain.MCPNetworkRelay, error) {
+	repo, err := r.controlPlane()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetRelayByID(id)
+}
+
+func (r *RegionalMCPAttestationRepository) GetRelaysByMCP(mcpServerID uuid.UUID) ([]*domain.MCPNetworkRelay, error) {
+	repo, err := r.controlPlane()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetRelaysByMCP(mcpServerID)
+}
+
+func (r *RegionalMCPAttestationRepository) GetActiveRelay(mcpServerID, agentID uuid.UUID) (*domain.MCPNetworkRelay, error) {
+	repo, err := r.controlPlane()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetActiveRelay(mcpServerID, agentID)
+}
+
+func (r *RegionalMCPAttestationRepository) HasActiveRelays(mcpServerID uuid.UUID) (bool, error) {
+	repo, err := r.controlPlane()
+	if err != nil {
+		return false, err
+	}
+	return repo.HasActiveRelays(mcpServerID)
+}
+
+func (r *RegionalMCPAttestationRepository) TouchRelay(id uuid.UUID, attestedAt time.Time) error {
+	repo, err := r.controlPlane()
+	if err != nil {
+		return err
+	}
+	return repo.TouchRelay(id, attestedAt)
+}
+
+func (r *RegionalMCPAttestationRepository) DeleteRelay(id uuid.UUID) error {
+	repo, err := r.controlPlane()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteRelay(id)
+}
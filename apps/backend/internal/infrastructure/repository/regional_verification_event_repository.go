@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.VerificationEventRepository = (*RegionalVerificationEventRepository)(nil)
+
+// RegionalVerificationEventRepository stores each organization's verification events in the
+// cluster of its region
+type RegionalVerificationEventRepository struct {
+	router  *RegionRouter
+	regions map[domain.DataRegion]domain.VerificationEventRepository
+}
+
+// NewRegionalVerificationEventRepository routes verification events to the repository of their
+// organization's region
+func NewRegionalVerificationEventRepository(router *RegionRouter, regions map[domain.DataRegion]domain.VerificationEventRepository) *RegionalVerificationEventRepository {
+	return &RegionalVerificationEventRepository{router: router, regions: regions}
+}
+
+func (r *RegionalVerificationEventRepository) forOrganization(orgID uuid.UUID) (domain.VerificationEventRepository, error) {
+	region, err := r.router.organizationRegion(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return regionalRepository(r.regions, region)
+}
+
+// forPlaced routes by the placement of an event or an agent
+func (r *RegionalVerificationEventRepository) forPlaced(resourceID uuid.UUID) (domain.VerificationEventRepository, error) {
+	region, err := r.router.placedRegion(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	return regionalRepository(r.regions, region)
+}
+
+// Create returns ErrCrossRegionQuery for an event of an agent stored in another region than the
+// event's organization
+func (r *RegionalVerificationEventRepository) Create(event *domain.VerificationEvent) error {
+	region, err := r.router.organizationRegion(event.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if event.AgentID != nil {
+		agentRegion, err := r.router.placedRegion(*event.AgentID)
+		if err != nil {
+			return err
+		}
+		if err := sameRegion(region, agentRegion); err != nil {
+			return err
+		}
+	}
+	repo, err := regionalRepository(r.regions, region)
+	if err != nil {
+		return err
+	}
+	if err := repo.Create(event); err != nil {
+		return err
+	}
+	if err := r.router.place(domain.DataPlacementVerificationEvent, event.ID, event.OrganizationID, region); err != nil {
+		// An event without a placement could not be found again
+		if deleteErr := repo.Delete(event.ID); deleteErr != nil {
+			return fmt.Errorf("%w (and failed to remove the event: %v)", err, deleteErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *RegionalVerificationEventRepository) GetByID(id uuid.UUID) (*domain.VerificationEvent, error) {
+	repo, err := r.forPlaced(id)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByID(id)
+}
+
+func (r *RegionalVerificationEventRepository) GetByOrganization(orgID uuid.UUID, limit, offset int) ([]*domain.VerificationEvent, int, error) {
+	repo, err := r.forOrganization(orgID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return repo.GetByOrganization(orgID, limit, offset)
+}
+
+func (r *RegionalVerificationEventRepository) GetByAgent(agentID uuid.UUID, limit, offset int) ([]*domain.VerificationEvent, int, error) {
+	repo, err := r.forPlaced(agentID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return repo.GetByAgent(agentID, limit, offset)
+}
+
+func (r *RegionalVerificationEventRepository) GetByMCPServer(mcpServerID uuid.UUID, limit, offset int) ([]*domain.VerificationEvent, int, error) {
+	_, region, err := r.router.mcpServerRegion(mcpServerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	repo, err := regionalRepository(r.regions, region)
+	if err != nil {
+		return nil, 0, err
+	}
+	return repo.GetByMCPServer(mcpServerID, limit, offset)
+}
+
+func (r *RegionalVerificationEventRepository) GetRecentEvents(orgID uuid.UUID, minutes int) ([]*domain.VerificationEvent, error) {
+	repo, err := r.forOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetRecentEvents(orgID, minutes)
+}
+
+func (r *RegionalVerificationEventRepository) GetPendingVerifications(orgID uuid.UUID) ([]*domain.VerificationEvent, error) {
+	repo, err := r.forOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetPendingVerifications(orgID)
+}
+
+func (r *RegionalVerificationEventRepository) SearchAdminVerifications(orgID uuid.UUID, params domain.VerificationQueryParams) ([]*domain.VerificationEvent, int, *domain.VerificationStatusCounts, error) {
+	repo, err := r.forOrganization(orgID)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return repo.SearchAdminVerifications(orgID, params)
+}
+
+func (r *RegionalVerificationEventRepository) ListAfter(orgID uuid.UUID, query domain.VerificationEventTailQuery) ([]*domain.VerificationEvent, error) {
+	repo, err := r.forOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListAfter(orgID, query)
+}
+
+func (r *RegionalVerificationEventRepository) GetStatistics(orgID uuid.UUID, startTime, endTime time.Time) (*domain.VerificationStatistics, error) {
+	repo, err := r.forOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetStatistics(orgID, startTime, endTime)
+}
+
+func (r *RegionalVerificationEventRepository) GetAgentStatistics(agentID uuid.UUID, startTime, endTime time.Time) (*domain.AgentVerificationStatistics, error) {
+	repo, err := r.forPlaced(agentID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAgentStatistics(agentID, startTime, endTime)
+}
+
+func (r *RegionalVerificationEventRepository) GetHourlyAgentActivity(orgID uuid.UUID, since, until time.Time) ([]*domain.AgentHourlyActivity, error) {
+	repo, err := r.forOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetHourlyAgentActivity(orgID, since, until)
+}
+
+// GetDailyUsage rolls up each region on its own; every rollup is of one organization's agent or
+// API key, so none spans regions
+func (r *RegionalVerificationEventRepository) GetDailyUsage(since, until time.Time) ([]*domain.UsageRollup, error) {
+	var rollups []*domain.UsageRollup
+	for _, region := range regionsOf(r.regions) {
+		regional, err := r.regions[region].GetDailyUsage(since, until)
+		if err != nil {
+			return nil, fmt.Errorf("failed to roll up usage in region %s: %w", region, err)
+		}
+		rollups = append(rollups, regional...)
+	}
+	return rollups, nil
+}
+
+func (r *RegionalVerificationEventRepository) UpdateResult(id uuid.UUID, result domain.VerificationResult, reason *string, metadata map[string]interface{}) error {
+	repo, err := r.forPlaced(id)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateResult(id, result, reason, metadata)
+}
+
+// UpdatePendingResults returns ErrCrossRegionQuery for events of different regions, as one
+// transaction cannot span clusters
+func (r *RegionalVerificationEventRepository) UpdatePendingResults(ids []uuid.UUID, result domain.VerificationResult, reason *string, metadata map[string]interface{}) error {
+	region, err := r.router.placedRegionOfAll(ids)
+	if err != nil {
+		return err
+	}
+	repo, err := regionalRepository(r.regions, region)
+	if err != nil {
+		return err
+	}
+	return repo.UpdatePendingResults(ids, result, reason, metadata)
+}
+
+func (r *RegionalVerificationEventRepository) Delete(id uuid.UUID) error {
+	repo, err := r.forPlaced(id)
+	if err != nil {
+		return err
+	}
+	if err := repo.Delete(id); err != nil {
+		return err
+	}
+	return r.router.placements.Delete(id)
+}
@@ -0,0 +1,560 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ReportRepository implements domain.ReportRepository
+type ReportRepository struct {
+	db *sql.DB
+}
+
+// NewReportRepository creates a new report repository
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+const reportSubscriptionColumns = `id, organization_id, name, report_type, cadence, format, delivery, channel_ids, is_active, last_run_at, next_run_at, created_by, created_at, updated_at`
+
+const reportRunColumns = `id, subscription_id, organization_id, status, period_start, period_end, format, filename, content_type, content, row_count, notification_id, download_token, expires_at, error, created_at, artifact_name`
+
+// CreateSubscription creates a new report subscription
+func (r *ReportRepository) CreateSubscription(subscription *domain.ReportSubscription) error {
+	query := `
+		INSERT INTO report_subscriptions (` + reportSubscriptionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	if subscription.ID == uuid.Nil {
+		subscription.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+
+	_, err := r.db.Exec(query,
+		subscription.ID,
+		subscription.OrganizationID,
+		subscription.Name,
+		subscription.ReportType,
+		subscription.Cadence,
+		subscription.Format,
+		subscription.Delivery,
+		pq.Array(uuidStrings(subscription.ChannelIDs)),
+		subscription.IsActive,
+		subscription.LastRunAt,
+		subscription.NextRunAt,
+		subscription.CreatedBy,
+		subscription.CreatedAt,
+		subscription.UpdatedAt,
+	)
+	return err
+}
+
+// GetSubscriptionByID retrieves a report subscription by ID
+func (r *ReportRepository) GetSubscriptionByID(id uuid.UUID) (*domain.ReportSubscription, error) {
+	query := `SELECT ` + reportSubscriptionColumns + ` FROM report_subscriptions WHERE id = $1`
+
+	subscription, err := scanReportSubscription(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("report subscription not found")
+	}
+	return subscription, err
+}
+
+// GetSubscriptionsByOrganization retrieves all report subscriptions for an organization
+func (r *ReportRepository) GetSubscriptionsByOrganization(orgID uuid.UUID) ([]*domain.ReportSubscription, error) {
+	query := `
+		SELECT ` + reportSubscriptionColumns + `
+		FROM report_subscriptions
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+	`
+	return r.querySubscriptions(query, orgID)
+}
+
+// UpdateSubscription updates a report subscription
+func (r *ReportRepository) UpdateSubscription(subscription *domain.ReportSubscription) error {
+	query := `
+		UPDATE report_subscriptions
+		SET name = $1, report_type = $2, cadence = $3, format = $4, delivery = $5, channel_ids = $6,
+			is_active = $7, next_run_at = $8, updated_at = $9
+		WHERE id = $10
+	`
+
+	subscription.UpdatedAt = time.Now().UTC()
+	_, err := r.db.Exec(query,
+		subscription.Name,
+		subscription.ReportType,
+		subscription.Cadence,
+		subscription.Format,
+		subscription.Delivery,
+		pq.Array(uuidStrings(subscription.ChannelIDs)),
+		subscription.IsActive,
+		subscription.NextRunAt,
+		subscription.UpdatedAt,
+		subscription.ID,
+	)
+	return err
+}
+
+// DeleteSubscription deletes a report subscription (and its runs)
+func (r *ReportRepository) DeleteSubscription(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM report_subscriptions WHERE id = $1`, id)
+	return err
+}
+
+// ClaimDueSubscriptions atomically claims active subscriptions whose next run is due.
+// Their next_run_at becomes the lease expiry so a crashed worker's claims are retried.
+func (r *ReportRepository) ClaimDueSubscriptions(limit int, lease time.Duration) ([]*domain.ReportSubscription, error) {
+	query := `
+		UPDATE report_subscriptions
+		SET next_run_at = $2
+		WHERE id IN (
+			SELECT id FROM report_subscriptions
+			WHERE is_active = true AND next_run_at <= NOW()
+			ORDER BY next_run_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + reportSubscriptionColumns
+
+	return r.querySubscriptions(query, limit, time.Now().UTC().Add(lease))
+}
+
+// UpdateSchedule records a completed run and schedules the next one
+func (r *ReportRepository) UpdateSchedule(id uuid.UUID, lastRunAt, nextRunAt time.Time) error {
+	query := `
+		UPDATE report_subscriptions
+		SET last_run_at = $1, next_run_at = $2, updated_at = NOW()
+		WHERE id = $3
+	`
+	_, err := r.db.Exec(query, lastRunAt, nextRunAt, id)
+	return err
+}
+
+// CreateRun stores a report run
+func (r *ReportRepository) CreateRun(run *domain.ReportRun) error {
+	query := `
+		INSERT INTO report_runs (` + reportRunColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`
+
+	if run.ID == uuid.Nil {
+		run.ID = uuid.New()
+	}
+	run.CreatedAt = time.Now().UTC()
+
+	_, err := r.db.Exec(query,
+		run.ID,
+		run.SubscriptionID,
+		run.OrganizationID,
+		run.Status,
+		run.PeriodStart,
+		run.PeriodEnd,
+		run.Format,
+		nullString(run.Filename),
+		nullString(run.ContentType),
+		run.Content,
+		run.RowCount,
+		run.NotificationID,
+		nullString(run.DownloadToken),
+		run.ExpiresAt,
+		run.Error,
+		run.CreatedAt,
+		nullString(run.ArtifactName),
+	)
+	return err
+}
+
+// GetRunByID retrieves a report run by ID
+func (r *ReportRepository) GetRunByID(id uuid.UUID) (*domain.ReportRun, error) {
+	query := `SELECT ` + reportRunColumns + ` FROM report_runs WHERE id = $1`
+
+	run, err := scanReportRun(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("report run not found")
+	}
+	return run, err
+}
+
+// GetRunByDownloadToken retrieves a report run by its download link token
+func (r *ReportRepository) GetRunByDownloadToken(token string) (*domain.ReportRun, error) {
+	query := `SELECT ` + reportRunColumns + ` FROM report_runs WHERE download_token = $1`
+
+	run, err := scanReportRun(r.db.QueryRow(query, token))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("report run not found")
+	}
+	return run, err
+}
+
+// GetRunsBySubscription retrieves the most recent runs of a subscription (without content)
+func (r *ReportRepository) GetRunsBySubscription(subscriptionID uuid.UUID, limit int) ([]*domain.ReportRun, error) {
+	query := `
+		SELECT id, subscription_id, organization_id, status, period_start, period_end, format, filename,
+			content_type, NULL::bytea, row_count, notification_id, download_token, expires_at, error, created_at, artifact_name
+		FROM report_runs
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(query, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*domain.ReportRun
+	for rows.Next() {
+		run, err := scanReportRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// GetSecurityPostureData summarizes agent health and alerts raised in the period
+func (r *ReportRepository) GetSecurityPostureData(orgID uuid.UUID, start, end time.Time) (*domain.ReportTable, error) {
+	var totalAgents, verifiedAgents, openAlerts int
+	var avgTrust sql.NullFloat64
+	err := r.db.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM agents WHERE organization_id = $1),
+			(SELECT COUNT(*) FROM agents WHERE organization_id = $1 AND status = 'verified'),
+			(SELECT AVG(trust_score) FROM agents WHERE organization_id = $1),
+			(SELECT COUNT(*) FROM alerts WHERE organization_id = $1 AND is_acknowledged = false)
+	`, orgID).Scan(&totalAgents, &verifiedAgents, &avgTrust, &openAlerts)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &domain.ReportTable{
+		Title: "Security Posture",
+		Metrics: []domain.ReportMetric{
+			{Label: "Agents", Value: strconv.Itoa(totalAgents)},
+			{Label: "Verified agents", Value: strconv.Itoa(verifiedAgents)},
+			{Label: "Average trust score", Value: formatReportFloat(avgTrust.Float64)},
+			{Label: "Unacknowledged alerts", Value: strconv.Itoa(openAlerts)},
+		},
+		Columns: []string{"Severity", "Alert Type", "Raised", "Unacknowledged"},
+	}
+
+	rows, err := r.db.Query(`
+		SELECT severity, alert_type, COUNT(*), COUNT(*) FILTER (WHERE is_acknowledged = false)
+		FROM alerts
+		WHERE organization_id = $1 AND created_at >= $2 AND created_at < $3
+		GROUP BY severity, alert_type
+		ORDER BY CASE severity WHEN 'critical' THEN 0 WHEN 'high' THEN 1 WHEN 'warning' THEN 2 ELSE 3 END, COUNT(*) DESC
+	`, orgID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var severity, alertType string
+		var raised, unacknowledged int
+		if err := rows.Scan(&severity, &alertType, &raised, &unacknowledged); err != nil {
+			return nil, err
+		}
+		table.Rows = append(table.Rows, []string{severity, alertType, strconv.Itoa(raised), strconv.Itoa(unacknowledged)})
+	}
+
+	return table, rows.Err()
+}
+
+// GetVerificationSummaryData aggregates verification outcomes per agent / MCP server in the period
+func (r *ReportRepository) GetVerificationSummaryData(orgID uuid.UUID, start, end time.Time) (*domain.ReportTable, error) {
+	table := &domain.ReportTable{
+		Title:   "Verification Summary",
+		Columns: []string{"Target", "Kind", "Verifications", "Succeeded", "Failed", "Success Rate", "Avg Duration (ms)"},
+	}
+
+	rows, err := r.db.Query(`
+		SELECT
+			COALESCE(agent_name, mcp_server_name, 'unknown'),
+			CASE WHEN agent_id IS NOT NULL THEN 'agent' ELSE 'mcp_server' END,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'success'),
+			COUNT(*) FILTER (WHERE status IN ('failed', 'timeout')),
+			COALESCE(AVG(duration_ms), 0)
+		FROM verification_events
+		WHERE organization_id = $1 AND created_at >= $2 AND created_at < $3
+		GROUP BY 1, 2
+		ORDER BY COUNT(*) DESC
+	`, orgID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var total, succeeded, failed int
+	for rows.Next() {
+		var target, kind string
+		var count, success, failure int
+		var avgDuration float64
+		if err := rows.Scan(&target, &kind, &count, &success, &failure, &avgDuration); err != nil {
+			return nil, err
+		}
+		total += count
+		succeeded += success
+		failed += failure
+		table.Rows = append(table.Rows, []string{
+			target, kind, strconv.Itoa(count), strconv.Itoa(success), strconv.Itoa(failure),
+			formatReportPercent(success, count), strconv.Itoa(int(avgDuration)),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	table.Metrics = []domain.ReportMetric{
+		{Label: "Verifications", Value: strconv.Itoa(total)},
+		{Label: "Succeeded", Value: strconv.Itoa(succeeded)},
+		{Label: "Failed", Value: strconv.Itoa(failed)},
+		{Label: "Success rate", Value: formatReportPercent(succeeded, total)},
+	}
+	return table, nil
+}
+
+// GetDriftDigestData lists verifications in the period that detected configuration drift
+func (r *ReportRepository) GetDriftDigestData(orgID uuid.UUID, start, end time.Time) (*domain.ReportTable, error) {
+	table := &domain.ReportTable{
+		Title:   "Configuration Drift Digest",
+		Columns: []string{"Detected At", "Agent", "Unexpected MCP Servers", "Unexpected Capabilities"},
+	}
+
+	rows, err := r.db.Query(`
+		SELECT created_at, COALESCE(agent_name, mcp_server_name, 'unknown'),
+			COALESCE(mcp_server_drift, '[]'::jsonb)::text, COALESCE(capability_drift, '[]'::jsonb)::text
+		FROM verification_events
+		WHERE organization_id = $1 AND drift_detected = true AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at DESC
+	`, orgID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	agents := make(map[string]bool)
+	for rows.Next() {
+		var detectedAt time.Time
+		var agent, serverDrift, capabilityDrift string
+		if err := rows.Scan(&detectedAt, &agent, &serverDrift, &capabilityDrift); err != nil {
+			return nil, err
+		}
+		agents[agent] = true
+		table.Rows = append(table.Rows, []string{
+			detectedAt.UTC().Format(time.RFC3339), agent, serverDrift, capabilityDrift,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	table.Metrics = []domain.ReportMetric{
+		{Label: "Drift detections", Value: strconv.Itoa(len(table.Rows))},
+		{Label: "Agents affected", Value: strconv.Itoa(len(agents))},
+	}
+	return table, nil
+}
+
+// GetTrustChangesData summarizes trust score movement per agent in the period
+func (r *ReportRepository) GetTrustChangesData(orgID uuid.UUID, start, end time.Time) (*domain.ReportTable, error) {
+	table := &domain.ReportTable{
+		Title:   "Trust Score Changes",
+		Columns: []string{"Agent", "Changes", "Starting Score", "Current Score", "Net Change", "Lowest Score"},
+	}
+
+	rows, err := r.db.Query(`
+		SELECT
+			COALESCE(a.display_name, a.name),
+			COUNT(*),
+			(ARRAY_AGG(COALESCE(h.previous_score, h.trust_score) ORDER BY h.recorded_at ASC))[1],
+			(ARRAY_AGG(h.trust_score ORDER BY h.recorded_at DESC))[1],
+			MIN(h.trust_score)
+		FROM trust_score_history h
+		JOIN agents a ON a.id = h.agent_id
+		WHERE h.organization_id = $1 AND h.recorded_at >= $2 AND h.recorded_at < $3
+		GROUP BY a.id, a.display_name, a.name
+		ORDER BY MIN(h.trust_score) ASC
+	`, orgID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var decreased, increased int
+	for rows.Next() {
+		var agent string
+		var changes int
+		var startScore, currentScore, lowest float64
+		if err := rows.Scan(&agent, &changes, &startScore, &currentScore, &lowest); err != nil {
+			return nil, err
+		}
+		delta := currentScore - startScore
+		switch {
+		case delta < 0:
+			decreased++
+		case delta > 0:
+			increased++
+		}
+		table.Rows = append(table.Rows, []string{
+			agent, strconv.Itoa(changes), formatReportFloat(startScore), formatReportFloat(currentScore),
+			fmt.Sprintf("%+.3f", delta), formatReportFloat(lowest),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	table.Metrics = []domain.ReportMetric{
+		{Label: "Agents with changes", Value: strconv.Itoa(len(table.Rows))},
+		{Label: "Increased", Value: strconv.Itoa(increased)},
+		{Label: "Decreased", Value: strconv.Itoa(decreased)},
+	}
+	return table, nil
+}
+
+func (r *ReportRepository) querySubscriptions(query string, args ...interface{}) ([]*domain.ReportSubscription, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []*domain.ReportSubscription
+	for rows.Next() {
+		subscription, err := scanReportSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func scanReportSubscription(row rowScanner) (*domain.ReportSubscription, error) {
+	subscription := &domain.ReportSubscription{}
+	var channelIDs []string
+	var lastRunAt sql.NullTime
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.OrganizationID,
+		&subscription.Name,
+		&subscription.ReportType,
+		&subscription.Cadence,
+		&subscription.Format,
+		&subscription.Delivery,
+		pq.Array(&channelIDs),
+		&subscription.IsActive,
+		&lastRunAt,
+		&subscription.NextRunAt,
+		&createdBy,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, raw := range channelIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		subscription.ChannelIDs = append(subscription.ChannelIDs, id)
+	}
+	if lastRunAt.Valid {
+		subscription.LastRunAt = &lastRunAt.Time
+	}
+	if createdBy.Valid {
+		subscription.CreatedBy = createdBy.UUID
+	}
+
+	return subscription, nil
+}
+
+func scanReportRun(row rowScanner) (*domain.ReportRun, error) {
+	run := &domain.ReportRun{}
+	var filename, contentType, downloadToken, errMsg, artifactName sql.NullString
+	var notificationID uuid.NullUUID
+	var expiresAt sql.NullTime
+
+	err := row.Scan(
+		&run.ID,
+		&run.SubscriptionID,
+		&run.OrganizationID,
+		&run.Status,
+		&run.PeriodStart,
+		&run.PeriodEnd,
+		&run.Format,
+		&filename,
+		&contentType,
+		&run.Content,
+		&run.RowCount,
+		&notificationID,
+		&downloadToken,
+		&expiresAt,
+		&errMsg,
+		&run.CreatedAt,
+		&artifactName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	run.Filename = filename.String
+	run.ContentType = contentType.String
+	run.DownloadToken = downloadToken.String
+	run.ArtifactName = artifactName.String
+	if notificationID.Valid {
+		run.NotificationID = &notificationID.UUID
+	}
+	if expiresAt.Valid {
+		run.ExpiresAt = &expiresAt.Time
+	}
+	if errMsg.Valid {
+		run.Error = &errMsg.String
+	}
+
+	return run, nil
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	return values
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func formatReportFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+func formatReportPercent(part, total int) string {
+	if total == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", float64(part)*100/float64(total))
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// RuntimeSettingRepository implements domain.RuntimeSettingRepository
+type RuntimeSettingRepository struct {
+	db *sql.DB
+}
+
+// NewRuntimeSettingRepository creates a new runtime setting repository
+func NewRuntimeSettingRepository(db *sql.DB) *RuntimeSettingRepository {
+	return &RuntimeSettingRepository{db: db}
+}
+
+// List returns every stored setting
+func (r *RuntimeSettingRepository) List() ([]*domain.RuntimeSetting, error) {
+	rows, err := r.db.Query(`SELECT key, value, updated_by, updated_at FROM runtime_settings ORDER BY key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := make([]*domain.RuntimeSetting, 0)
+	for rows.Next() {
+		setting := &domain.RuntimeSetting{}
+		if err := rows.Scan(&setting.Key, &setting.Value, &setting.UpdatedBy, &setting.UpdatedAt); err != nil {
+			return nil, err
+		}
+		settings = append(settings, setting)
+	}
+	return settings, rows.Err()
+}
+
+// Set creates or replaces the setting and records the change
+func (r *RuntimeSettingRepository) Set(setting *domain.RuntimeSetting, change *domain.RuntimeSettingChange) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO runtime_settings (key, value, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`, setting.Key, setting.Value, setting.UpdatedBy, setting.UpdatedAt); err != nil {
+		return err
+	}
+	if err := insertRuntimeSettingChange(tx, change); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes the setting and records the change
+func (r *RuntimeSettingRepository) Delete(key string, change *domain.RuntimeSettingChange) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM runtime_settings WHERE key = $1`, key); err != nil {
+		return err
+	}
+	if err := insertRuntimeSettingChange(tx, change); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertRuntimeSettingChange(tx *sql.Tx, change *domain.RuntimeSettingChange) error {
+	if change.ID == uuid.Nil {
+		change.ID = uuid.New()
+	}
+	_, err := tx.Exec(`
+		INSERT INTO runtime_setting_changes (id, key, previous_value, value, reason, changed_by, ip_address, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, change.ID, change.Key, change.PreviousValue, change.Value, change.Reason, change.ChangedBy, change.IPAddress, change.ChangedAt)
+	return err
+}
+
+// ListChanges returns a page of the changes of a setting, or of every setting, newest first
+func (r *RuntimeSettingRepository) ListChanges(key string, limit, offset int) ([]*domain.RuntimeSettingChange, error) {
+	rows, err := r.db.Query(`
+		SELECT id, key, previous_value, value, reason, changed_by, ip_address, changed_at
+		FROM runtime_setting_changes
+		WHERE $1 = '' OR key = $1
+		ORDER BY changed_at DESC
+		LIMIT $2 OFFSET $3
+	`, key, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]*domain.RuntimeSettingChange, 0)
+	for rows.Next() {
+		change := &domain.RuntimeSettingChange{}
+		if err := rows.Scan(
+			&change.ID,
+			&change.Key,
+			&change.PreviousValue,
+			&change.Value,
+			&change.Reason,
+			&change.ChangedBy,
+			&change.IPAddress,
+			&change.ChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// SAMLConfigRepository implements domain.SAMLConfigRepository
+type SAMLConfigRepository struct {
+	db *sql.DB
+}
+
+// NewSAMLConfigRepository creates a new SAML configuration repository
+func NewSAMLConfigRepository(db *sql.DB) *SAMLConfigRepository {
+	return &SAMLConfigRepository{db: db}
+}
+
+const samlConfigColumns = `
+	id, organization_id, enabled, idp_entity_id, idp_sso_url, idp_certificate,
+	email_attribute, name_attribute, role_attribute, role_mappings, default_role,
+	jit_provisioning, updated_by, created_at, updated_at
+`
+
+// GetByOrganization retrieves the organization's SAML configuration, or nil if none is configured
+func (r *SAMLConfigRepository) GetByOrganization(orgID uuid.UUID) (*domain.SAMLConfig, error) {
+	config := &domain.SAMLConfig{}
+	var roleMappings []byte
+
+	err := r.db.QueryRow(`SELECT `+samlConfigColumns+` FROM saml_configurations WHERE organization_id = $1`, orgID).Scan(
+		&config.ID,
+		&config.OrganizationID,
+		&config.Enabled,
+		&config.IdPEntityID,
+		&config.IdPSSOURL,
+		&config.IdPCertificate,
+		&config.EmailAttribute,
+		&config.NameAttribute,
+		&config.RoleAttribute,
+		&roleMappings,
+		&config.DefaultRole,
+		&config.JITProvisioning,
+		&config.UpdatedBy,
+		&config.CreatedAt,
+		&config.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(roleMappings, &config.RoleMappings); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal role mappings: %w", err)
+	}
+	return config, nil
+}
+
+// Upsert creates or replaces the organization's SAML configuration
+func (r *SAMLConfigRepository) Upsert(config *domain.SAMLConfig) error {
+	roleMappings, err := json.Marshal(config.RoleMappings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal role mappings: %w", err)
+	}
+	if config.RoleMappings == nil {
+		roleMappings = []byte("{}")
+	}
+	if config.ID == uuid.Nil {
+		config.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO saml_configurations (` + samlConfigColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $14)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			idp_entity_id = EXCLUDED.idp_entity_id,
+			idp_sso_url = EXCLUDED.idp_sso_url,
+			idp_certificate = EXCLUDED.idp_certificate,
+			email_attribute = EXCLUDED.email_attribute,
+			name_attribute = EXCLUDED.name_attribute,
+			role_attribute = EXCLUDED.role_attribute,
+			role_mappings = EXCLUDED.role_mappings,
+			default_role = EXCLUDED.default_role,
+			jit_provisioning = EXCLUDED.jit_provisioning,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(query,
+		config.ID,
+		config.OrganizationID,
+		config.Enabled,
+		config.IdPEntityID,
+		config.IdPSSOURL,
+		config.IdPCertificate,
+		config.EmailAttribute,
+		config.NameAttribute,
+		config.RoleAttribute,
+		roleMappings,
+		config.DefaultRole,
+		config.JITProvisioning,
+		config.UpdatedBy,
+		time.Now().UTC(),
+	).Scan(&config.ID, &config.CreatedAt, &config.UpdatedAt)
+}
+
+// Delete removes the organization's SAML configuration
+func (r *SAMLConfigRepository) Delete(orgID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM saml_configurations WHERE organization_id = $1`, orgID)
+	return err
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -296,7 +297,7 @@ func (r *SecurityRepository) GetIncidents(orgID uuid.UUID, status domain.Inciden
 		query = `
 			SELECT
 				id, organization_id, incident_type, status, severity, title, description,
-				affected_resources, assigned_to, created_at, updated_at, resolved_at, resolved_by, resolution_notes
+				affected_resources, assigned_to, created_at, updated_at, acknowledged_at, resolved_at, resolved_by, resolution_notes
 			FROM security_incidents
 			WHERE organization_id = $1 AND status = $2
 			ORDER BY created_at DESC
@@ -307,7 +308,7 @@ func (r *SecurityRepository) GetIncidents(orgID uuid.UUID, status domain.Inciden
 		query = `
 			SELECT
 				id, organization_id, incident_type, status, severity, title, description,
-				affected_resources, assigned_to, created_at, updated_at, resolved_at, resolved_by, resolution_notes
+				affected_resources, assigned_to, created_at, updated_at, acknowledged_at, resolved_at, resolved_by, resolution_notes
 			FROM security_incidents
 			WHERE organization_id = $1
 			ORDER BY created_at DESC
@@ -322,12 +323,42 @@ func (r *SecurityRepository) GetIncidents(orgID uuid.UUID, status domain.Inciden
 	}
 	defer rows.Close()
 
+	return scanIncidents(rows)
+}
+
+// ListUnresolvedIncidents returns the open and investigating incidents of every organization, oldest first
+func (r *SecurityRepository) ListUnresolvedIncidents() ([]*domain.SecurityIncident, error) {
+	rows, err := r.db.Query(`
+		SELECT
+			id, organization_id, incident_type, status, severity, title, description,
+			affected_resources, assigned_to, created_at, updated_at, acknowledged_at, resolved_at, resolved_by, resolution_notes
+		FROM security_incidents
+		WHERE status IN ('open', 'investigating')
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unresolved incidents: %w", err)
+	}
+	defer rows.Close()
+
+	return scanIncidents(rows)
+}
+
+// DeleteIncident removes an incident; its comments, timeline and links cascade
+func (r *SecurityRepository) DeleteIncident(id uuid.UUID) error {
+	if _, err := r.db.Exec(`DELETE FROM security_incidents WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete incident: %w", err)
+	}
+	return nil
+}
+
+func scanIncidents(rows *sql.Rows) ([]*domain.SecurityIncident, error) {
 	var incidents []*domain.SecurityIncident
 	for rows.Next() {
 		incident := &domain.SecurityIncident{}
 		var affectedResources []string
 		var assignedTo, resolvedBy, resolutionNotes sql.NullString
-		var resolvedAt sql.NullTime
+		var acknowledgedAt, resolvedAt sql.NullTime
 
 		err := rows.Scan(
 			&incident.ID,
@@ -341,6 +372,7 @@ func (r *SecurityRepository) GetIncidents(orgID uuid.UUID, status domain.Inciden
 			&assignedTo,
 			&incident.CreatedAt,
 			&incident.UpdatedAt,
+			&acknowledgedAt,
 			&resolvedAt,
 			&resolvedBy,
 			&resolutionNotes,
@@ -360,6 +392,9 @@ func (r *SecurityRepository) GetIncidents(orgID uuid.UUID, status domain.Inciden
 			uid, _ := uuid.Parse(resolvedBy.String)
 			incident.ResolvedBy = &uid
 		}
+		if acknowledgedAt.Valid {
+			incident.AcknowledgedAt = &acknowledgedAt.Time
+		}
 		if resolvedAt.Valid {
 			incident.ResolvedAt = &resolvedAt.Time
 		}
@@ -370,14 +405,14 @@ func (r *SecurityRepository) GetIncidents(orgID uuid.UUID, status domain.Inciden
 		incidents = append(incidents, incident)
 	}
 
-	return incidents, nil
+	return incidents, rows.Err()
 }
 
 func (r *SecurityRepository) GetIncidentByID(id uuid.UUID) (*domain.SecurityIncident, error) {
 	query := `
 		SELECT
 			id, organization_id, incident_type, status, severity, title, description,
-			affected_resources, assigned_to, created_at, updated_at, resolved_at, resolved_by, resolution_notes
+			affected_resources, assigned_to, created_at, updated_at, acknowledged_at, resolved_at, resolved_by, resolution_notes
 		FROM security_incidents
 		WHERE id = $1
 	`
@@ -385,7 +420,7 @@ func (r *SecurityRepository) GetIncidentByID(id uuid.UUID) (*domain.SecurityInci
 	incident := &domain.SecurityIncident{}
 	var affectedResources []string
 	var assignedTo, resolvedBy, resolutionNotes sql.NullString
-	var resolvedAt sql.NullTime
+	var acknowledgedAt, resolvedAt sql.NullTime
 
 	err := r.db.QueryRow(query, id).Scan(
 		&incident.ID,
@@ -399,6 +434,7 @@ func (r *SecurityRepository) GetIncidentByID(id uuid.UUID) (*domain.SecurityInci
 		&assignedTo,
 		&incident.CreatedAt,
 		&incident.UpdatedAt,
+		&acknowledgedAt,
 		&resolvedAt,
 		&resolvedBy,
 		&resolutionNotes,
@@ -422,6 +458,9 @@ func (r *SecurityRepository) GetIncidentByID(id uuid.UUID) (*domain.SecurityInci
 		uid, _ := uuid.Parse(resolvedBy.String)
 		incident.ResolvedBy = &uid
 	}
+	if acknowledgedAt.Valid {
+		incident.AcknowledgedAt = &acknowledgedAt.Time
+	}
 	if resolvedAt.Valid {
 		incident.ResolvedAt = &resolvedAt.Time
 	}
@@ -436,17 +475,20 @@ func (r *SecurityRepository) UpdateIncidentStatus(id uuid.UUID, status domain.In
 	var query string
 	var args []interface{}
 
+	// Leaving open acknowledges the incident; only the first acknowledgement is kept
 	if status == domain.IncidentStatusResolved {
 		query = `
 			UPDATE security_incidents
-			SET status = $1, resolved_at = $2, resolved_by = $3, resolution_notes = $4, updated_at = $5
+			SET status = $1, resolved_at = $2, resolved_by = $3, resolution_notes = $4, updated_at = $5,
+				acknowledged_at = COALESCE(acknowledged_at, $5)
 			WHERE id = $6
 		`
 		args = []interface{}{status, time.Now().UTC(), resolvedBy, notes, time.Now().UTC(), id}
 	} else {
 		query = `
 			UPDATE security_incidents
-			SET status = $1, updated_at = $2
+			SET status = $1, updated_at = $2,
+				acknowledged_at = CASE WHEN $1 = 'open' THEN acknowledged_at ELSE COALESCE(acknowledged_at, $2) END
 			WHERE id = $3
 		`
 		args = []interface{}{status, time.Now().UTC(), id}
@@ -460,6 +502,19 @@ func (r *SecurityRepository) UpdateIncidentStatus(id uuid.UUID, status domain.In
 	return nil
 }
 
+func (r *SecurityRepository) AssignIncident(id uuid.UUID, assignee *uuid.UUID) error {
+	_, err := r.db.Exec(`
+		UPDATE security_incidents
+		SET assigned_to = $1, updated_at = $2
+		WHERE id = $3
+	`, assignee, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to assign incident: %w", err)
+	}
+
+	return nil
+}
+
 // Metrics
 
 func (r *SecurityRepository) GetSecurityMetrics(orgID uuid.UUID) (*domain.SecurityMetrics, error) {
@@ -648,3 +703,281 @@ func (r *SecurityRepository) GetSecurityScan(scanID uuid.UUID) (*domain.Security
 
 	return scan, nil
 }
+
+// Threat and anomaly search
+
+// severityRankSQL orders alert and anomaly severities from least to most severe
+const severityRankSQL = `CASE severity WHEN 'critical' THEN 4 WHEN 'high' THEN 3 WHEN 'warning' THEN 2 WHEN 'medium' THEN 2 ELSE 1 END`
+
+var threatSortColumns = map[string]string{
+	"created_at": "created_at",
+	"severity":   severityRankSQL,
+	"title":      "title",
+}
+
+var anomalySortColumns = map[string]string{
+	"created_at": "created_at",
+	"severity":   severityRankSQL,
+	"confidence": "confidence",
+	"title":      "title",
+}
+
+// searchFilter builds a parameterized WHERE clause. Each condition is a format string
+// whose %[1]d verbs are replaced with the placeholder index of its value.
+type searchFilter struct {
+	conditions []string
+	args       []interface{}
+}
+
+func (f *searchFilter) add(condition string, value interface{}) {
+	f.args = append(f.args, value)
+	f.conditions = append(f.conditions, fmt.Sprintf(condition, len(f.args)))
+}
+
+func (f *searchFilter) where() string {
+	return strings.Join(f.conditions, " AND ")
+}
+
+// orderBy whitelists the sort column and direction; ties are broken by id so pages are stable
+func orderBy(columns map[string]string, sortBy, sortOrder string) string {
+	column, ok := columns[strings.ToLower(sortBy)]
+	if !ok {
+		column = "created_at"
+	}
+	direction := "DESC"
+	if strings.EqualFold(sortOrder, "asc") {
+		direction = "ASC"
+	}
+	return fmt.Sprintf("%s %s, id %s", column, direction, direction)
+}
+
+func severityStrings(severities []domain.AlertSeverity) []string {
+	values := make([]string, len(severities))
+	for i, severity := range severities {
+		values[i] = string(severity)
+	}
+	return values
+}
+
+// SearchThreatAlerts returns the alerts behind a page of threats and the total matching the filters
+func (r *SecurityRepository) SearchThreatAlerts(orgID uuid.UUID, params domain.ThreatQueryParams) ([]*domain.Alert, int, error) {
+	if params.Limit <= 0 {
+		params.Limit = 50
+	}
+	if params.Offset < 0 {
+		params.Offset = 0
+	}
+
+	filter := &searchFilter{}
+	filter.add("organization_id = $%[1]d", orgID)
+	if len(params.Severities) > 0 {
+		filter.add("severity = ANY($%[1]d)", pq.Array(severityStrings(params.Severities)))
+	}
+	if len(params.AlertTypes) > 0 {
+		alertTypes := make([]string, len(params.AlertTypes))
+		for i, alertType := range params.AlertTypes {
+			alertTypes[i] = string(alertType)
+		}
+		filter.add("alert_type = ANY($%[1]d)", pq.Array(alertTypes))
+	}
+	if params.Blocked != nil {
+		filter.add("is_acknowledged = $%[1]d", *params.Blocked)
+	}
+	if params.TargetType != "" {
+		filter.add("resource_type = $%[1]d", params.TargetType)
+	}
+	if params.TargetID != nil {
+		filter.add("resource_id = $%[1]d", *params.TargetID)
+	}
+	if params.From != nil {
+		filter.add("created_at >= $%[1]d", *params.From)
+	}
+	if params.To != nil {
+		filter.add("created_at <= $%[1]d", *params.To)
+	}
+	if params.Search != "" {
+		filter.add("(title ILIKE $%[1]d OR COALESCE(description, '') ILIKE $%[1]d)", "%"+params.Search+"%")
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM alerts WHERE `+filter.where(), filter.args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count threats: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at, created_at
+		FROM alerts
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, filter.where(), orderBy(threatSortColumns, params.SortBy, params.SortOrder), len(filter.args)+1, len(filter.args)+2)
+
+	rows, err := r.db.Query(query, append(filter.args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search threats: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []*domain.Alert
+	for rows.Next() {
+		alert := &domain.Alert{}
+		err := rows.Scan(
+			&alert.ID,
+			&alert.OrganizationID,
+			&alert.AlertType,
+			&alert.Severity,
+			&alert.Title,
+			&alert.Description,
+			&alert.ResourceType,
+			&alert.ResourceID,
+			&alert.IsAcknowledged,
+			&alert.AcknowledgedBy,
+			&alert.AcknowledgedAt,
+			&alert.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan threat: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, total, rows.Err()
+}
+
+// SearchAnomalies returns a page of anomalies and the total matching the filters
+func (r *SecurityRepository) SearchAnomalies(orgID uuid.UUID, params domain.AnomalyQueryParams) ([]*domain.Anomaly, int, error) {
+	if params.Limit <= 0 {
+		params.Limit = 50
+	}
+	if params.Offset < 0 {
+		params.Offset = 0
+	}
+
+	filter := &searchFilter{}
+	filter.add("organization_id = $%[1]d", orgID)
+	if len(params.Severities) > 0 {
+		filter.add("severity = ANY($%[1]d)", pq.Array(severityStrings(params.Severities)))
+	}
+	if len(params.AnomalyTypes) > 0 {
+		anomalyTypes := make([]string, len(params.AnomalyTypes))
+		for i, anomalyType := range params.AnomalyTypes {
+			anomalyTypes[i] = string(anomalyType)
+		}
+		filter.add("anomaly_type = ANY($%[1]d)", pq.Array(anomalyTypes))
+	}
+	if params.ResourceType != "" {
+		filter.add("resource_type = $%[1]d", params.ResourceType)
+	}
+	if params.ResourceID != nil {
+		filter.add("resource_id = $%[1]d", *params.ResourceID)
+	}
+	if params.MinConfidence > 0 {
+		filter.add("confidence >= $%[1]d", params.MinConfidence)
+	}
+	if params.From != nil {
+		filter.add("created_at >= $%[1]d", *params.From)
+	}
+	if params.To != nil {
+		filter.add("created_at <= $%[1]d", *params.To)
+	}
+	if params.Search != "" {
+		filter.add("(title ILIKE $%[1]d OR COALESCE(description, '') ILIKE $%[1]d)", "%"+params.Search+"%")
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM security_anomalies WHERE `+filter.where(), filter.args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count anomalies: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			id, organization_id, anomaly_type, severity, title, description,
+			resource_type, resource_id, confidence, created_at
+		FROM security_anomalies
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, filter.where(), orderBy(anomalySortColumns, params.SortBy, params.SortOrder), len(filter.args)+1, len(filter.args)+2)
+
+	rows, err := r.db.Query(query, append(filter.args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search anomalies: %w", err)
+	}
+	defer rows.Close()
+
+	var anomalies []*domain.Anomaly
+	for rows.Next() {
+		anomaly := &domain.Anomaly{}
+		err := rows.Scan(
+			&anomaly.ID,
+			&anomaly.OrganizationID,
+			&anomaly.AnomalyType,
+			&anomaly.Severity,
+			&anomaly.Title,
+			&anomaly.Description,
+			&anomaly.ResourceType,
+			&anomaly.ResourceID,
+			&anomaly.Confidence,
+			&anomaly.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan anomaly: %w", err)
+		}
+		anomalies = append(anomalies, anomaly)
+	}
+
+	return anomalies, total, rows.Err()
+}
+
+// Threat annotations
+
+func (r *SecurityRepository) CreateThreatAnnotation(annotation *domain.ThreatAnnotation) error {
+	if annotation.ID == uuid.Nil {
+		annotation.ID = uuid.New()
+	}
+	if annotation.CreatedAt.IsZero() {
+		annotation.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO threat_annotations (id, organization_id, threat_id, user_id, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, annotation.ID, annotation.OrganizationID, annotation.ThreatID, annotation.UserID, annotation.Note, annotation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create threat annotation: %w", err)
+	}
+
+	return nil
+}
+
+// GetThreatAnnotations returns a threat's annotations, oldest first
+func (r *SecurityRepository) GetThreatAnnotations(threatID uuid.UUID) ([]*domain.ThreatAnnotation, error) {
+	rows, err := r.db.Query(`
+		SELECT id, organization_id, threat_id, user_id, note, created_at
+		FROM threat_annotations
+		WHERE threat_id = $1
+		ORDER BY created_at ASC
+	`, threatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get threat annotations: %w", err)
+	}
+	defer rows.Close()
+
+	var annotations []*domain.ThreatAnnotation
+	for rows.Next() {
+		annotation := &domain.ThreatAnnotation{}
+		if err := rows.Scan(
+			&annotation.ID,
+			&annotation.OrganizationID,
+			&annotation.ThreatID,
+			&annotation.UserID,
+			&annotation.Note,
+			&annotation.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan threat annotation: %w", err)
+		}
+		annotations = append(annotations, annotation)
+	}
+
+	return annotations, rows.Err()
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// SharedKeyRepository implements domain.SharedKeyRepository
+type SharedKeyRepository struct {
+	db *sql.DB
+}
+
+// NewSharedKeyRepository creates a new shared key repository
+func NewSharedKeyRepository(db *sql.DB) *SharedKeyRepository {
+	return &SharedKeyRepository{db: db}
+}
+
+// FindSharedKeys returns every public key held by more than one agent, with its holders oldest first
+func (r *SharedKeyRepository) FindSharedKeys() ([]*domain.SharedKey, error) {
+	rows, err := r.db.Query(`
+		SELECT public_key, id, name, organization_id
+		FROM agents
+		WHERE public_key IN (
+			SELECT public_key FROM agents
+			WHERE public_key IS NOT NULL AND public_key <> ''
+			GROUP BY public_key
+			HAVING COUNT(*) > 1
+		)
+		ORDER BY public_key, created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sharedKeys []*domain.SharedKey
+	var current *domain.SharedKey
+	for rows.Next() {
+		var publicKey string
+		var holder domain.SharedKeyHolder
+		if err := rows.Scan(&publicKey, &holder.AgentID, &holder.AgentName, &holder.OrganizationID); err != nil {
+			return nil, err
+		}
+		fingerprint := domain.PublicKeyFingerprint(publicKey)
+		if current == nil || current.Fingerprint != fingerprint {
+			current = &domain.SharedKey{Fingerprint: fingerprint}
+			sharedKeys = append(sharedKeys, current)
+		}
+		current.Holders = append(current.Holders, holder)
+	}
+	return sharedKeys, rows.Err()
+}
+
+// FindPublicKeyHolders returns the agents whose current public key is publicKey
+func (r *SharedKeyRepository) FindPublicKeyHolders(publicKey string) ([]domain.SharedKeyHolder, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, organization_id
+		FROM agents
+		WHERE public_key = $1
+		ORDER BY created_at
+	`, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holders []domain.SharedKeyHolder
+	for rows.Next() {
+		var holder domain.SharedKeyHolder
+		if err := rows.Scan(&holder.AgentID, &holder.AgentName, &holder.OrganizationID); err != nil {
+			return nil, err
+		}
+		holders = append(holders, holder)
+	}
+	return holders, rows.Err()
+}
+
+// CreateAllowlistEntry stores an allowlist entry
+func (r *SharedKeyRepository) CreateAllowlistEntry(entry *domain.SharedKeyAllowlistEntry) error {
+	_, err := r.db.Exec(`
+		INSERT INTO shared_key_allowlist (id, organization_id, fingerprint, reason, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, entry.ID, entry.OrganizationID, entry.Fingerprint, entry.Reason, entry.CreatedBy, entry.CreatedAt)
+	return err
+}
+
+// ListAllowlist returns the organization's allowlist, newest first
+func (r *SharedKeyRepository) ListAllowlist(orgID uuid.UUID) ([]*domain.SharedKeyAllowlistEntry, error) {
+	rows, err := r.db.Query(`
+		SELECT id, organization_id, fingerprint, reason, created_by, created_at
+		FROM shared_key_allowlist
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.SharedKeyAllowlistEntry, 0)
+	for rows.Next() {
+		entry := &domain.SharedKeyAllowlistEntry{}
+		var createdBy uuid.NullUUID
+		if err := rows.Scan(&entry.ID, &entry.OrganizationID, &entry.Fingerprint, &entry.Reason, &createdBy, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		if createdBy.Valid {
+			entry.CreatedBy = &createdBy.UUID
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// DeleteAllowlistEntry removes an allowlist entry of the organization
+func (r *SharedKeyRepository) DeleteAllowlistEntry(orgID, id uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM shared_key_allowlist WHERE id = $1 AND organization_id = $2`, id, orgID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrSharedKeyAllowlistEntryNotFound
+	}
+	return nil
+}
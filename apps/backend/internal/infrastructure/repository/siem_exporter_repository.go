@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// SIEMExporterRepository implements domain.SIEMExporterRepository
+type SIEMExporterRepository struct {
+	db *sql.DB
+}
+
+// NewSIEMExporterRepository creates a new SIEM exporter repository
+func NewSIEMExporterRepository(db *sql.DB) *SIEMExporterRepository {
+	return &SIEMExporterRepository{db: db}
+}
+
+const siemExporterColumns = `id, organization_id, name, host, port, transport, format, facility, ca_certificate, event_types, min_severity, is_active, delivered_count, dropped_count, last_delivered_at, last_error, last_error_at, created_by, created_at, updated_at`
+
+// Create creates a new SIEM exporter
+func (r *SIEMExporterRepository) Create(exporter *domain.SIEMExporter) error {
+	query := `
+		INSERT INTO siem_exporters (id, organization_id, name, host, port, transport, format, facility, ca_certificate, event_types, min_severity, is_active, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+
+	if exporter.ID == uuid.Nil {
+		exporter.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	exporter.CreatedAt = now
+	exporter.UpdatedAt = now
+
+	_, err := r.db.Exec(query,
+		exporter.ID,
+		exporter.OrganizationID,
+		exporter.Name,
+		exporter.Host,
+		exporter.Port,
+		exporter.Transport,
+		exporter.Format,
+		exporter.Facility,
+		exporter.CACertificate,
+		pq.Array(exporter.EventTypes),
+		exporter.MinSeverity,
+		exporter.IsActive,
+		exporter.CreatedBy,
+		exporter.CreatedAt,
+		exporter.UpdatedAt,
+	)
+	return err
+}
+
+// GetByID retrieves a SIEM exporter by ID
+func (r *SIEMExporterRepository) GetByID(id uuid.UUID) (*domain.SIEMExporter, error) {
+	query := `SELECT ` + siemExporterColumns + ` FROM siem_exporters WHERE id = $1`
+
+	exporter, err := scanSIEMExporter(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrSIEMExporterNotFound
+	}
+	return exporter, err
+}
+
+// GetByOrganization retrieves all SIEM exporters of an organization
+func (r *SIEMExporterRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.SIEMExporter, error) {
+	query := `
+		SELECT ` + siemExporterColumns + `
+		FROM siem_exporters
+		WHERE organization_id = $1
+		ORDER BY created_at ASC
+	`
+	return r.queryExporters(query, orgID)
+}
+
+// GetActiveByOrganization retrieves the enabled SIEM exporters of an organization
+func (r *SIEMExporterRepository) GetActiveByOrganization(orgID uuid.UUID) ([]*domain.SIEMExporter, error) {
+	query := `
+		SELECT ` + siemExporterColumns + `
+		FROM siem_exporters
+		WHERE organization_id = $1 AND is_active = true
+		ORDER BY created_at ASC
+	`
+	return r.queryExporters(query, orgID)
+}
+
+// Update updates a SIEM exporter's settings; delivery counters are left to RecordDelivery
+func (r *SIEMExporterRepository) Update(exporter *domain.SIEMExporter) error {
+	query := `
+		UPDATE siem_exporters
+		SET name = $1, host = $2, port = $3, transport = $4, format = $5, facility = $6,
+			ca_certificate = $7, event_types = $8, min_severity = $9, is_active = $10, updated_at = $11
+		WHERE id = $12
+	`
+
+	exporter.UpdatedAt = time.Now().UTC()
+	_, err := r.db.Exec(query,
+		exporter.Name,
+		exporter.Host,
+		exporter.Port,
+		exporter.Transport,
+		exporter.Format,
+		exporter.Facility,
+		exporter.CACertificate,
+		pq.Array(exporter.EventTypes),
+		exporter.MinSeverity,
+		exporter.IsActive,
+		exporter.UpdatedAt,
+		exporter.ID,
+	)
+	return err
+}
+
+// Delete deletes a SIEM exporter
+func (r *SIEMExporterRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM siem_exporters WHERE id = $1`, id)
+	return err
+}
+
+// RecordDelivery adds delivered and dropped events to the counters. A delivery clears the last
+// error; a failure sets it.
+func (r *SIEMExporterRepository) RecordDelivery(id uuid.UUID, stats domain.SIEMDeliveryStats) error {
+	query := `
+		UPDATE siem_exporters
+		SET delivered_count = delivered_count + $1,
+			dropped_count = dropped_count + $2,
+			last_delivered_at = COALESCE($3, last_delivered_at),
+			last_error = CASE WHEN $4::text IS NOT NULL THEN $4 WHEN $3::timestamptz IS NOT NULL THEN NULL ELSE last_error END,
+			last_error_at = CASE WHEN $4::text IS NOT NULL THEN $5 WHEN $3::timestamptz IS NOT NULL THEN NULL ELSE last_error_at END
+		WHERE id = $6
+	`
+
+	_, err := r.db.Exec(query, stats.Delivered, stats.Dropped, stats.DeliveredAt, stats.Error, stats.ErrorAt, id)
+	return err
+}
+
+func (r *SIEMExporterRepository) queryExporters(query string, args ...interface{}) ([]*domain.SIEMExporter, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exporters []*domain.SIEMExporter
+	for rows.Next() {
+		exporter, err := scanSIEMExporter(rows)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, exporter)
+	}
+
+	return exporters, rows.Err()
+}
+
+func scanSIEMExporter(row rowScanner) (*domain.SIEMExporter, error) {
+	exporter := &domain.SIEMExporter{}
+	var lastDeliveredAt, lastErrorAt sql.NullTime
+	var lastError sql.NullString
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&exporter.ID,
+		&exporter.OrganizationID,
+		&exporter.Name,
+		&exporter.Host,
+		&exporter.Port,
+		&exporter.Transport,
+		&exporter.Format,
+		&exporter.Facility,
+		&exporter.CACertificate,
+		pq.Array(&exporter.EventTypes),
+		&exporter.MinSeverity,
+		&exporter.IsActive,
+		&exporter.DeliveredCount,
+		&exporter.DroppedCount,
+		&lastDeliveredAt,
+		&lastError,
+		&lastErrorAt,
+		&createdBy,
+		&exporter.CreatedAt,
+		&exporter.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if exporter.EventTypes == nil {
+		exporter.EventTypes = []string{}
+	}
+	if lastDeliveredAt.Valid {
+		exporter.LastDeliveredAt = &lastDeliveredAt.Time
+	}
+	if lastError.Valid {
+		exporter.LastError = &lastError.String
+	}
+	if lastErrorAt.Valid {
+		exporter.LastErrorAt = &lastErrorAt.Time
+	}
+	if createdBy.Valid {
+		exporter.CreatedBy = createdBy.UUID
+	}
+
+	return exporter, nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
@@ -350,6 +351,79 @@ func (r *TagRepository) GetMCPServerTags(ctx context.Context, mcpServerID uuid.U
 	return tags, nil
 }
 
+// GetTagsForAgents retrieves the tags of several agents in one query
+func (r *TagRepository) GetTagsForAgents(ctx context.Context, agentIDs []uuid.UUID) (map[uuid.UUID][]*domain.Tag, error) {
+	query := `
+		SELECT at.agent_id, t.id, t.organization_id, t.key, t.value, t.category, t.description, t.color, t.created_at, t.created_by
+		FROM tags t
+		INNER JOIN agent_tags at ON t.id = at.tag_id
+		WHERE at.agent_id = ANY($1::uuid[])
+		ORDER BY t.category, t.key, t.value
+	`
+	tags, err := r.getTagsByOwner(ctx, query, agentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent tags: %w", err)
+	}
+	return tags, nil
+}
+
+// GetTagsForMCPServers retrieves the tags of several MCP servers in one query
+func (r *TagRepository) GetTagsForMCPServers(ctx context.Context, mcpServerIDs []uuid.UUID) (map[uuid.UUID][]*domain.Tag, error) {
+	query := `
+		SELECT mst.mcp_server_id, t.id, t.organization_id, t.key, t.value, t.category, t.description, t.color, t.created_at, t.created_by
+		FROM tags t
+		INNER JOIN mcp_server_tags mst ON t.id = mst.tag_id
+		WHERE mst.mcp_server_id = ANY($1::uuid[])
+		ORDER BY t.category, t.key, t.value
+	`
+	tags, err := r.getTagsByOwner(ctx, query, mcpServerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mcp server tags: %w", err)
+	}
+	return tags, nil
+}
+
+// getTagsByOwner runs a query selecting the owner ID followed by the tag columns. Every
+// owner gets an entry, empty if it carries no tags.
+func (r *TagRepository) getTagsByOwner(ctx context.Context, query string, ownerIDs []uuid.UUID) (map[uuid.UUID][]*domain.Tag, error) {
+	tags := make(map[uuid.UUID][]*domain.Tag, len(ownerIDs))
+	for _, ownerID := range ownerIDs {
+		tags[ownerID] = make([]*domain.Tag, 0)
+	}
+	if len(ownerIDs) == 0 {
+		return tags, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(uuidStrings(ownerIDs)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ownerID uuid.UUID
+		tag := &domain.Tag{}
+		err := rows.Scan(
+			&ownerID,
+			&tag.ID,
+			&tag.OrganizationID,
+			&tag.Key,
+			&tag.Value,
+			&tag.Category,
+			&tag.Description,
+			&tag.Color,
+			&tag.CreatedAt,
+			&tag.CreatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags[ownerID] = append(tags[ownerID], tag)
+	}
+
+	return tags, rows.Err()
+}
+
 // GetPopularTags retrieves the most popular tags by usage count
 func (r *TagRepository) GetPopularTags(ctx context.Context, organizationID uuid.UUID, limit int) ([]*domain.Tag, error) {
 	query := `
@@ -0,0 +1,332 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// TicketConnectorRepository implements domain.TicketConnectorRepository
+type TicketConnectorRepository struct {
+	db *sql.DB
+}
+
+// NewTicketConnectorRepository creates a new ticket connector repository
+func NewTicketConnectorRepository(db *sql.DB) *TicketConnectorRepository {
+	return &TicketConnectorRepository{db: db}
+}
+
+const ticketConnectorColumns = `id, organization_id, name, provider, base_url, username, encrypted_api_token, project, issue_type, resource_types, approved_statuses, rejected_statuses, incident_statuses, webhook_secret_hash, is_enabled, created_by, created_at, updated_at`
+
+const ticketLinkColumns = `id, organization_id, connector_id, resource_type, resource_id, provider, ticket_key, ticket_id, ticket_url, ticket_status, synced_comment_ids, closed, last_synced_at, last_sync_error, created_at`
+
+// CreateConnector stores a new connector
+func (r *TicketConnectorRepository) CreateConnector(connector *domain.TicketConnector) error {
+	query := `
+		INSERT INTO ticket_connectors (` + ticketConnectorColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+	`
+	if connector.ID == uuid.Nil {
+		connector.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	connector.CreatedAt = now
+	connector.UpdatedAt = now
+
+	statuses, err := json.Marshal(connector.IncidentStatuses)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query,
+		connector.ID,
+		connector.OrganizationID,
+		connector.Name,
+		connector.Provider,
+		connector.BaseURL,
+		connector.Username,
+		connector.EncryptedAPIToken,
+		connector.Project,
+		connector.IssueType,
+		pq.Array(connector.ResourceTypes),
+		pq.Array(connector.ApprovedStatuses),
+		pq.Array(connector.RejectedStatuses),
+		statuses,
+		connector.WebhookSecretHash,
+		connector.IsEnabled,
+		connector.CreatedBy,
+		connector.CreatedAt,
+		connector.UpdatedAt,
+	)
+	return err
+}
+
+// GetConnector retrieves a connector by ID
+func (r *TicketConnectorRepository) GetConnector(id uuid.UUID) (*domain.TicketConnector, error) {
+	query := `SELECT ` + ticketConnectorColumns + ` FROM ticket_connectors WHERE id = $1`
+
+	connector, err := scanTicketConnector(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrTicketConnectorNotFound
+	}
+	return connector, err
+}
+
+// GetConnectorsByOrganization returns the organization's connectors, oldest first
+func (r *TicketConnectorRepository) GetConnectorsByOrganization(orgID uuid.UUID) ([]*domain.TicketConnector, error) {
+	query := `
+		SELECT ` + ticketConnectorColumns + `
+		FROM ticket_connectors
+		WHERE organization_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	connectors := make([]*domain.TicketConnector, 0)
+	for rows.Next() {
+		connector, err := scanTicketConnector(rows)
+		if err != nil {
+			return nil, err
+		}
+		connectors = append(connectors, connector)
+	}
+	return connectors, rows.Err()
+}
+
+// UpdateConnector saves the connector's settings and credentials
+func (r *TicketConnectorRepository) UpdateConnector(connector *domain.TicketConnector) error {
+	query := `
+		UPDATE ticket_connectors
+		SET name = $1, base_url = $2, username = $3, encrypted_api_token = $4, project = $5, issue_type = $6,
+			resource_types = $7, approved_statuses = $8, rejected_statuses = $9, incident_statuses = $10,
+			is_enabled = $11, updated_at = $12
+		WHERE id = $13
+	`
+	connector.UpdatedAt = time.Now().UTC()
+
+	statuses, err := json.Marshal(connector.IncidentStatuses)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(query,
+		connector.Name,
+		connector.BaseURL,
+		connector.Username,
+		connector.EncryptedAPIToken,
+		connector.Project,
+		connector.IssueType,
+		pq.Array(connector.ResourceTypes),
+		pq.Array(connector.ApprovedStatuses),
+		pq.Array(connector.RejectedStatuses),
+		statuses,
+		connector.IsEnabled,
+		connector.UpdatedAt,
+		connector.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrTicketConnectorNotFound
+	}
+	return nil
+}
+
+// DeleteConnector deletes a connector and its ticket links
+func (r *TicketConnectorRepository) DeleteConnector(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM ticket_connectors WHERE id = $1`, id)
+	return err
+}
+
+// CreateLink stores a new ticket link
+func (r *TicketConnectorRepository) CreateLink(link *domain.TicketLink) error {
+	query := `
+		INSERT INTO ticket_links (` + ticketLinkColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+	if link.ID == uuid.Nil {
+		link.ID = uuid.New()
+	}
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.Exec(query,
+		link.ID,
+		link.OrganizationID,
+		link.ConnectorID,
+		link.ResourceType,
+		link.ResourceID,
+		link.Provider,
+		link.TicketKey,
+		nullString(link.TicketID),
+		link.TicketURL,
+		nullString(link.TicketStatus),
+		pq.Array(link.SyncedCommentIDs),
+		link.Closed,
+		link.LastSyncedAt,
+		link.LastSyncError,
+		link.CreatedAt,
+	)
+	return err
+}
+
+// GetLinkByTicket returns the link of a connector's ticket
+func (r *TicketConnectorRepository) GetLinkByTicket(connectorID uuid.UUID, ticketKey string) (*domain.TicketLink, error) {
+	query := `SELECT ` + ticketLinkColumns + ` FROM ticket_links WHERE connector_id = $1 AND ticket_key = $2`
+
+	link, err := scanTicketLink(r.db.QueryRow(query, connectorID, ticketKey))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrTicketLinkNotFound
+	}
+	return link, err
+}
+
+// GetLinksByResource returns the tickets opened for a resource, oldest first
+func (r *TicketConnectorRepository) GetLinksByResource(resourceType string, resourceID uuid.UUID) ([]*domain.TicketLink, error) {
+	query := `
+		SELECT ` + ticketLinkColumns + `
+		FROM ticket_links
+		WHERE resource_type = $1 AND resource_id = $2
+		ORDER BY created_at
+	`
+	return r.queryLinks(query, resourceType, resourceID)
+}
+
+// GetOpenLinks returns up to limit links still being synced, least recently synced first
+func (r *TicketConnectorRepository) GetOpenLinks(limit int) ([]*domain.TicketLink, error) {
+	query := `
+		SELECT ` + ticketLinkColumns + `
+		FROM ticket_links
+		WHERE NOT closed
+		ORDER BY last_synced_at NULLS FIRST, created_at
+		LIMIT $1
+	`
+	return r.queryLinks(query, limit)
+}
+
+// UpdateLink saves the sync state of a link
+func (r *TicketConnectorRepository) UpdateLink(link *domain.TicketLink) error {
+	query := `
+		UPDATE ticket_links
+		SET ticket_status = $1, synced_comment_ids = $2, closed = $3, last_synced_at = $4, last_sync_error = $5
+		WHERE id = $6
+	`
+	_, err := r.db.Exec(query,
+		nullString(link.TicketStatus),
+		pq.Array(link.SyncedCommentIDs),
+		link.Closed,
+		link.LastSyncedAt,
+		link.LastSyncError,
+		link.ID,
+	)
+	return err
+}
+
+func (r *TicketConnectorRepository) queryLinks(query string, args ...interface{}) ([]*domain.TicketLink, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make([]*domain.TicketLink, 0)
+	for rows.Next() {
+		link, err := scanTicketLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+func scanTicketConnector(row rowScanner) (*domain.TicketConnector, error) {
+	connector := &domain.TicketConnector{}
+	var statuses []byte
+
+	err := row.Scan(
+		&connector.ID,
+		&connector.OrganizationID,
+		&connector.Name,
+		&connector.Provider,
+		&connector.BaseURL,
+		&connector.Username,
+		&connector.EncryptedAPIToken,
+		&connector.Project,
+		&connector.IssueType,
+		pq.Array(&connector.ResourceTypes),
+		pq.Array(&connector.ApprovedStatuses),
+		pq.Array(&connector.RejectedStatuses),
+		&statuses,
+		&connector.WebhookSecretHash,
+		&connector.IsEnabled,
+		&connector.CreatedBy,
+		&connector.CreatedAt,
+		&connector.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(statuses, &connector.IncidentStatuses); err != nil {
+		return nil, err
+	}
+	if connector.ResourceTypes == nil {
+		connector.ResourceTypes = []string{}
+	}
+	if connector.ApprovedStatuses == nil {
+		connector.ApprovedStatuses = []string{}
+	}
+	if connector.RejectedStatuses == nil {
+		connector.RejectedStatuses = []string{}
+	}
+	return connector, nil
+}
+
+func scanTicketLink(row rowScanner) (*domain.TicketLink, error) {
+	link := &domain.TicketLink{}
+	var ticketID, ticketStatus, lastSyncError sql.NullString
+	var lastSyncedAt sql.NullTime
+
+	err := row.Scan(
+		&link.ID,
+		&link.OrganizationID,
+		&link.ConnectorID,
+		&link.ResourceType,
+		&link.ResourceID,
+		&link.Provider,
+		&link.TicketKey,
+		&ticketID,
+		&link.TicketURL,
+		&ticketStatus,
+		pq.Array(&link.SyncedCommentIDs),
+		&link.Closed,
+		&lastSyncedAt,
+		&lastSyncError,
+		&link.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	link.TicketID = ticketID.String
+	link.TicketStatus = ticketStatus.String
+	if lastSyncedAt.Valid {
+		link.LastSyncedAt = &lastSyncedAt.Time
+	}
+	if lastSyncError.Valid {
+		link.LastSyncError = &lastSyncError.String
+	}
+	return link, nil
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// TombstoneRepository implements domain.TombstoneRepository
+type TombstoneRepository struct {
+	db *sql.DB
+}
+
+// NewTombstoneRepository creates a new tombstone repository
+func NewTombstoneRepository(db *sql.DB) *TombstoneRepository {
+	return &TombstoneRepository{db: db}
+}
+
+// linkedCountQueries counts, per entity type, the records that reference the entity
+var linkedCountQueries = map[domain.TombstoneEntityType]struct {
+	keys  []string
+	query string
+}{
+	domain.TombstoneEntityAgent: {
+		keys: []string{"apiKeys", "capabilities", "attestations", "mcpConnections", "verificationEvents", "alerts"},
+		query: `
+			SELECT
+				(SELECT COUNT(*) FROM api_keys WHERE agent_id = $1),
+				(SELECT COUNT(*) FROM agent_capabilities WHERE agent_id = $1),
+				(SELECT COUNT(*) FROM mcp_attestations WHERE agent_id = $1),
+				(SELECT COUNT(*) FROM agent_mcp_connections WHERE agent_id = $1),
+				(SELECT COUNT(*) FROM verification_events WHERE agent_id = $1),
+				(SELECT COUNT(*) FROM alerts WHERE resource_id = $1)
+		`,
+	},
+	domain.TombstoneEntityMCPServer: {
+		keys: []string{"attestations", "agentConnections", "capabilities", "verificationEvents", "alerts"},
+		query: `
+			SELECT
+				(SELECT COUNT(*) FROM mcp_attestations WHERE mcp_server_id = $1),
+				(SELECT COUNT(*) FROM agent_mcp_connections WHERE mcp_server_id = $1),
+				(SELECT COUNT(*) FROM mcp_server_capabilities WHERE mcp_server_id = $1),
+				(SELECT COUNT(*) FROM verification_events WHERE mcp_server_id = $1),
+				(SELECT COUNT(*) FROM alerts WHERE resource_id = $1)
+		`,
+	},
+	domain.TombstoneEntityAPIKey: {
+		keys:  []string{"auditEntries"},
+		query: `SELECT (SELECT COUNT(*) FROM audit_logs WHERE resource_id = $1)`,
+	},
+}
+
+// Create stores a tombstone
+func (r *TombstoneRepository) Create(tombstone *domain.Tombstone) error {
+	query := `
+		INSERT INTO entity_tombstones (
+			id, organization_id, entity_type, entity_id, name, deleted_by, deleted_by_email,
+			deleted_at, linked_counts, details
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	if tombstone.ID == uuid.Nil {
+		tombstone.ID = uuid.New()
+	}
+	if tombstone.DeletedAt.IsZero() {
+		tombstone.DeletedAt = time.Now().UTC()
+	}
+
+	linkedJSON, err := json.Marshal(tombstone.LinkedCounts)
+	if err != nil {
+		return err
+	}
+	detailsJSON, err := json.Marshal(tombstone.Details)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query,
+		tombstone.ID,
+		tombstone.OrganizationID,
+		tombstone.EntityType,
+		tombstone.EntityID,
+		tombstone.Name,
+		tombstone.DeletedBy,
+		nullString(tombstone.DeletedByEmail),
+		tombstone.DeletedAt,
+		linkedJSON,
+		detailsJSON,
+	)
+	return err
+}
+
+const tombstoneColumns = `
+	id, organization_id, entity_type, entity_id, name, deleted_by, deleted_by_email,
+	deleted_at, linked_counts, details
+`
+
+func scanTombstone(row interface{ Scan(...interface{}) error }) (*domain.Tombstone, error) {
+	tombstone := &domain.Tombstone{}
+	var deletedByEmail sql.NullString
+	var linkedJSON, detailsJSON []byte
+
+	err := row.Scan(
+		&tombstone.ID,
+		&tombstone.OrganizationID,
+		&tombstone.EntityType,
+		&tombstone.EntityID,
+		&tombstone.Name,
+		&tombstone.DeletedBy,
+		&deletedByEmail,
+		&tombstone.DeletedAt,
+		&linkedJSON,
+		&detailsJSON,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tombstone.DeletedByEmail = deletedByEmail.String
+	if err := json.Unmarshal(linkedJSON, &tombstone.LinkedCounts); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(detailsJSON, &tombstone.Details); err != nil {
+		return nil, err
+	}
+	return tombstone, nil
+}
+
+// GetByEntityID retrieves the organization's tombstone of a deleted entity, or nil if there is none
+func (r *TombstoneRepository) GetByEntityID(orgID, entityID uuid.UUID) (*domain.Tombstone, error) {
+	query := `SELECT ` + tombstoneColumns + `
+		FROM entity_tombstones
+		WHERE organization_id = $1 AND entity_id = $2
+		ORDER BY deleted_at DESC
+		LIMIT 1
+	`
+
+	tombstone, err := scanTombstone(r.db.QueryRow(query, orgID, entityID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tombstone, nil
+}
+
+// Search retrieves a page of the organization's tombstones matching the filter, newest first
+func (r *TombstoneRepository) Search(filter *domain.TombstoneFilter) ([]*domain.Tombstone, int, error) {
+	where := []string{"organization_id = $1"}
+	args := []interface{}{filter.OrganizationID}
+	argPos := 2
+
+	if filter.EntityType != "" {
+		where = append(where, fmt.Sprintf("entity_type = $%d", argPos))
+		args = append(args, filter.EntityType)
+		argPos++
+	}
+	if filter.DeletedBy != nil {
+		where = append(where, fmt.Sprintf("deleted_by = $%d", argPos))
+		args = append(args, *filter.DeletedBy)
+		argPos++
+	}
+	if filter.Query != "" {
+		if id, err := uuid.Parse(filter.Query); err == nil {
+			where = append(where, fmt.Sprintf("entity_id = $%d", argPos))
+			args = append(args, id)
+		} else {
+			where = append(where, fmt.Sprintf("LOWER(name) LIKE $%d", argPos))
+			args = append(args, "%"+strings.ToLower(filter.Query)+"%")
+		}
+		argPos++
+	}
+	whereClause := strings.Join(where, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM entity_tombstones WHERE `+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM entity_tombstones WHERE %s ORDER BY deleted_at DESC LIMIT $%d OFFSET $%d`,
+		tombstoneColumns, whereClause, argPos, argPos+1)
+	rows, err := r.db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var tombstones []*domain.Tombstone
+	for rows.Next() {
+		tombstone, err := scanTombstone(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		tombstones = append(tombstones, tombstone)
+	}
+
+	return tombstones, total, rows.Err()
+}
+
+// CountLinked counts the records that reference the entity, keyed by what they are
+func (r *TombstoneRepository) CountLinked(entityType domain.TombstoneEntityType, entityID uuid.UUID) (map[string]int, error) {
+	spec, ok := linkedCountQueries[entityType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported tombstone entity type: %s", entityType)
+	}
+
+	counts := make([]int, len(spec.keys))
+	dest := make([]interface{}, len(counts))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	if err := r.db.QueryRow(spec.query, entityID).Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	linked := make(map[string]int, len(spec.keys))
+	for i, key := range spec.keys {
+		linked[key] = counts[i]
+	}
+	return linked, nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// TrustBoundaryRepository implements domain.TrustBoundaryRepository
+type TrustBoundaryRepository struct {
+	db *sql.DB
+}
+
+// NewTrustBoundaryRepository creates a new trust boundary repository
+func NewTrustBoundaryRepository(db *sql.DB) *TrustBoundaryRepository {
+	return &TrustBoundaryRepository{db: db}
+}
+
+const trustBoundaryPolicyColumns = `
+	id, organization_id, enabled, floor_score, floor_suspend, floor_open_incident, incident_severity,
+	ceiling_score, ceiling_sustained_days, ceiling_extend_key_expiry, key_extension_days,
+	ceiling_relax_verification, updated_by, created_at, updated_at
+`
+
+func scanTrustBoundaryPolicy(row interface{ Scan(...interface{}) error }) (*domain.TrustBoundaryPolicy, error) {
+	policy := &domain.TrustBoundaryPolicy{}
+	err := row.Scan(
+		&policy.ID,
+		&policy.OrganizationID,
+		&policy.Enabled,
+		&policy.FloorScore,
+		&policy.FloorSuspend,
+		&policy.FloorOpenIncident,
+		&policy.IncidentSeverity,
+		&policy.CeilingScore,
+		&policy.CeilingSustainedDays,
+		&policy.CeilingExtendKeyExpiry,
+		&policy.KeyExtensionDays,
+		&policy.CeilingRelaxVerification,
+		&policy.UpdatedBy,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// GetPolicy retrieves the organization's trust boundary policy, or nil if none is configured
+func (r *TrustBoundaryRepository) GetPolicy(orgID uuid.UUID) (*domain.TrustBoundaryPolicy, error) {
+	query := `SELECT ` + trustBoundaryPolicyColumns + ` FROM trust_boundary_policies WHERE organization_id = $1`
+
+	policy, err := scanTrustBoundaryPolicy(r.db.QueryRow(query, orgID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// UpsertPolicy creates or replaces the organization's trust boundary policy
+func (r *TrustBoundaryRepository) UpsertPolicy(policy *domain.TrustBoundaryPolicy) error {
+	query := `
+		INSERT INTO trust_boundary_policies (
+			id, organization_id, enabled, floor_score, floor_suspend, floor_open_incident, incident_severity,
+			ceiling_score, ceiling_sustained_days, ceiling_extend_key_expiry, key_extension_days,
+			ceiling_relax_verification, updated_by, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $14)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			floor_score = EXCLUDED.floor_score,
+			floor_suspend = EXCLUDED.floor_suspend,
+			floor_open_incident = EXCLUDED.floor_open_incident,
+			incident_severity = EXCLUDED.incident_severity,
+			ceiling_score = EXCLUDED.ceiling_score,
+			ceiling_sustained_days = EXCLUDED.ceiling_sustained_days,
+			ceiling_extend_key_expiry = EXCLUDED.ceiling_extend_key_expiry,
+			key_extension_days = EXCLUDED.key_extension_days,
+			ceiling_relax_verification = EXCLUDED.ceiling_relax_verification,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at, updated_at
+	`
+
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+
+	return r.db.QueryRow(query,
+		policy.ID,
+		policy.OrganizationID,
+		policy.Enabled,
+		policy.FloorScore,
+		policy.FloorSuspend,
+		policy.FloorOpenIncident,
+		policy.IncidentSeverity,
+		policy.CeilingScore,
+		policy.CeilingSustainedDays,
+		policy.CeilingExtendKeyExpiry,
+		policy.KeyExtensionDays,
+		policy.CeilingRelaxVerification,
+		policy.UpdatedBy,
+		time.Now().UTC(),
+	).Scan(&policy.ID, &policy.CreatedAt, &policy.UpdatedAt)
+}
+
+// GetEnabledPolicies retrieves the policies of every organization that opted in to boundary actions
+func (r *TrustBoundaryRepository) GetEnabledPolicies() ([]*domain.TrustBoundaryPolicy, error) {
+	query := `SELECT ` + trustBoundaryPolicyColumns + ` FROM trust_boundary_policies WHERE enabled = true`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []*domain.TrustBoundaryPolicy
+	for rows.Next() {
+		policy, err := scanTrustBoundaryPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, rows.Err()
+}
+
+const trustBoundaryStateColumns = `
+	agent_id, organization_id, last_score, below_floor_since, above_ceiling_since,
+	ceiling_actions_at, verification_relaxed, updated_at
+`
+
+func scanTrustBoundaryState(row interface{ Scan(...interface{}) error }) (*domain.AgentTrustBoundaryState, error) {
+	state := &domain.AgentTrustBoundaryState{}
+	err := row.Scan(
+		&state.AgentID,
+		&state.OrganizationID,
+		&state.LastScore,
+		&state.BelowFloorSince,
+		&state.AboveCeilingSince,
+		&state.CeilingActionsAt,
+		&state.VerificationRelaxed,
+		&state.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// GetState retrieves the agent's boundary state, or nil if it was never evaluated
+func (r *TrustBoundaryRepository) GetState(agentID uuid.UUID) (*domain.AgentTrustBoundaryState, error) {
+	query := `SELECT ` + trustBoundaryStateColumns + ` FROM agent_trust_boundary_states WHERE agent_id = $1`
+
+	state, err := scanTrustBoundaryState(r.db.QueryRow(query, agentID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// GetStatesByOrganization retrieves the boundary state of every evaluated agent of the organization
+func (r *TrustBoundaryRepository) GetStatesByOrganization(orgID uuid.UUID) ([]*domain.AgentTrustBoundaryState, error) {
+	query := `SELECT ` + trustBoundaryStateColumns + ` FROM agent_trust_boundary_states WHERE organization_id = $1`
+
+	rows, err := r.db.Query(query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []*domain.AgentTrustBoundaryState
+	for rows.Next() {
+		state, err := scanTrustBoundaryState(rows)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+
+	return states, rows.Err()
+}
+
+// UpsertState creates or replaces the agent's boundary state
+func (r *TrustBoundaryRepository) UpsertState(state *domain.AgentTrustBoundaryState) error {
+	query := `
+		INSERT INTO agent_trust_boundary_states (
+			agent_id, organization_id, last_score, below_floor_since, above_ceiling_since,
+			ceiling_actions_at, verification_relaxed, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (agent_id) DO UPDATE SET
+			last_score = EXCLUDED.last_score,
+			below_floor_since = EXCLUDED.below_floor_since,
+			above_ceiling_since = EXCLUDED.above_ceiling_since,
+			ceiling_actions_at = EXCLUDED.ceiling_actions_at,
+			verification_relaxed = EXCLUDED.verification_relaxed,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	state.UpdatedAt = time.Now().UTC()
+	_, err := r.db.Exec(query,
+		state.AgentID,
+		state.OrganizationID,
+		state.LastScore,
+		state.BelowFloorSince,
+		state.AboveCeilingSince,
+		state.CeilingActionsAt,
+		state.VerificationRelaxed,
+		state.UpdatedAt,
+	)
+	return err
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/opena2a/identity/backend/internal/domain"
 	"time"
 )
@@ -98,6 +99,57 @@ func (r *TrustScoreRepository) GetLatest(agentID uuid.UUID) (*domain.TrustScore,
 	return score, err
 }
 
+// GetLatestByAgents returns the latest score of each agent in one query
+func (r *TrustScoreRepository) GetLatestByAgents(agentIDs []uuid.UUID) (map[uuid.UUID]*domain.TrustScore, error) {
+	scores := make(map[uuid.UUID]*domain.TrustScore, len(agentIDs))
+	if len(agentIDs) == 0 {
+		return scores, nil
+	}
+
+	query := `
+		SELECT DISTINCT ON (agent_id)
+			id, agent_id, score,
+			verification_status, uptime, success_rate, security_alerts,
+			compliance, age, drift_detection, user_feedback,
+			confidence, last_calculated, created_at
+		FROM trust_scores
+		WHERE agent_id = ANY($1::uuid[])
+		ORDER BY agent_id, created_at DESC
+	`
+
+	rows, err := r.db.Query(query, pq.Array(uuidStrings(agentIDs)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		score := &domain.TrustScore{}
+		err := rows.Scan(
+			&score.ID,
+			&score.AgentID,
+			&score.Score,
+			&score.Factors.VerificationStatus,
+			&score.Factors.Uptime,
+			&score.Factors.SuccessRate,
+			&score.Factors.SecurityAlerts,
+			&score.Factors.Compliance,
+			&score.Factors.Age,
+			&score.Factors.DriftDetection,
+			&score.Factors.UserFeedback,
+			&score.Confidence,
+			&score.LastCalculated,
+			&score.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		scores[score.AgentID] = score
+	}
+
+	return scores, rows.Err()
+}
+
 func (r *TrustScoreRepository) GetHistory(agentID uuid.UUID, limit int) ([]*domain.TrustScore, error) {
 	// 8-factor trust scoring system
 	query := `
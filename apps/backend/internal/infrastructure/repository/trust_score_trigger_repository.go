@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// TrustScoreTriggerRepository implements domain.TrustScoreTriggerRepository
+type TrustScoreTriggerRepository struct {
+	db *sql.DB
+}
+
+// NewTrustScoreTriggerRepository creates a new trust score trigger repository
+func NewTrustScoreTriggerRepository(db *sql.DB) *TrustScoreTriggerRepository {
+	return &TrustScoreTriggerRepository{db: db}
+}
+
+const trustScoreTriggerColumns = `id, organization_id, name, description, condition, threshold, actions, severity,
+	is_enabled, created_by, created_at, updated_at`
+
+const trustScoreTriggerFiringColumns = `id, organization_id, trigger_id, trigger_name, agent_id, agent_name, condition,
+	threshold, previous_score, score, alert_id, incident_id, actions, fired_at`
+
+// CreateTrigger stores a new trust score trigger
+func (r *TrustScoreTriggerRepository) CreateTrigger(trigger *domain.TrustScoreTrigger) error {
+	if trigger.ID == uuid.Nil {
+		trigger.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	trigger.CreatedAt = now
+	trigger.UpdatedAt = now
+
+	actionsJSON, err := json.Marshal(nonNilSlice(trigger.Actions))
+	if err != nil {
+		return fmt.Errorf("failed to marshal actions: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO trust_score_triggers (`+trustScoreTriggerColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`,
+		trigger.ID,
+		trigger.OrganizationID,
+		trigger.Name,
+		trigger.Description,
+		trigger.Condition,
+		trigger.Threshold,
+		actionsJSON,
+		trigger.Severity,
+		trigger.IsEnabled,
+		trigger.CreatedBy,
+		trigger.CreatedAt,
+		trigger.UpdatedAt,
+	)
+	return err
+}
+
+// GetTrigger retrieves a trust score trigger by ID
+func (r *TrustScoreTriggerRepository) GetTrigger(id uuid.UUID) (*domain.TrustScoreTrigger, error) {
+	trigger, err := scanTrustScoreTrigger(r.db.QueryRow(`SELECT `+trustScoreTriggerColumns+` FROM trust_score_triggers WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrTrustScoreTriggerNotFound
+	}
+	return trigger, err
+}
+
+// GetTriggersByOrganization lists an organization's trust score triggers, oldest first
+func (r *TrustScoreTriggerRepository) GetTriggersByOrganization(orgID uuid.UUID) ([]*domain.TrustScoreTrigger, error) {
+	rows, err := r.db.Query(`
+		SELECT `+trustScoreTriggerColumns+`
+		FROM trust_score_triggers
+		WHERE organization_id = $1
+		ORDER BY created_at ASC
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var triggers []*domain.TrustScoreTrigger
+	for rows.Next() {
+		trigger, err := scanTrustScoreTrigger(rows)
+		if err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, trigger)
+	}
+	return triggers, rows.Err()
+}
+
+// UpdateTrigger stores the editable fields of a trust score trigger
+func (r *TrustScoreTriggerRepository) UpdateTrigger(trigger *domain.TrustScoreTrigger) error {
+	actionsJSON, err := json.Marshal(nonNilSlice(trigger.Actions))
+	if err != nil {
+		return fmt.Errorf("failed to marshal actions: %w", err)
+	}
+	trigger.UpdatedAt = time.Now().UTC()
+
+	result, err := r.db.Exec(`
+		UPDATE trust_score_triggers
+		SET name = $1, description = $2, condition = $3, threshold = $4, actions = $5, severity = $6,
+		    is_enabled = $7, updated_at = $8
+		WHERE id = $9
+	`, trigger.Name, trigger.Description, trigger.Condition, trigger.Threshold, actionsJSON, trigger.Severity,
+		trigger.IsEnabled, trigger.UpdatedAt, trigger.ID)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return domain.ErrTrustScoreTriggerNotFound
+	}
+	return nil
+}
+
+// DeleteTrigger removes a trust score trigger; its firings are kept
+func (r *TrustScoreTriggerRepository) DeleteTrigger(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM trust_score_triggers WHERE id = $1`, id)
+	return err
+}
+
+// CreateFiring records a trigger firing
+func (r *TrustScoreTriggerRepository) CreateFiring(firing *domain.TrustScoreTriggerFiring) error {
+	if firing.ID == uuid.Nil {
+		firing.ID = uuid.New()
+	}
+
+	actionsJSON, err := json.Marshal(nonNilSlice(firing.Actions))
+	if err != nil {
+		return fmt.Errorf("failed to marshal actions: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO trust_score_trigger_firings (`+trustScoreTriggerFiringColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`,
+		firing.ID,
+		firing.OrganizationID,
+		firing.TriggerID,
+		firing.TriggerName,
+		firing.AgentID,
+		firing.AgentName,
+		firing.Condition,
+		firing.Threshold,
+		firing.PreviousScore,
+		firing.Score,
+		firing.AlertID,
+		firing.IncidentID,
+		actionsJSON,
+		firing.FiredAt,
+	)
+	return err
+}
+
+// GetFiringsByOrganization lists a page of an organization's trigger firings, newest first
+func (r *TrustScoreTriggerRepository) GetFiringsByOrganization(orgID uuid.UUID, triggerID *uuid.UUID, limit, offset int) ([]*domain.TrustScoreTriggerFiring, int, error) {
+	where := `WHERE organization_id = $1`
+	args := []interface{}{orgID}
+	if triggerID != nil {
+		where += ` AND trigger_id = $2`
+		args = append(args, *triggerID)
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM trust_score_trigger_firings `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM trust_score_trigger_firings %s ORDER BY fired_at DESC LIMIT $%d OFFSET $%d`,
+		trustScoreTriggerFiringColumns, where, len(args)+1, len(args)+2)
+	rows, err := r.db.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var firings []*domain.TrustScoreTriggerFiring
+	for rows.Next() {
+		firing, err := scanTrustScoreTriggerFiring(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		firings = append(firings, firing)
+	}
+	return firings, total, rows.Err()
+}
+
+func scanTrustScoreTrigger(row interface{ Scan(...interface{}) error }) (*domain.TrustScoreTrigger, error) {
+	trigger := &domain.TrustScoreTrigger{}
+	var actionsJSON []byte
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&trigger.ID,
+		&trigger.OrganizationID,
+		&trigger.Name,
+		&trigger.Description,
+		&trigger.Condition,
+		&trigger.Threshold,
+		&actionsJSON,
+		&trigger.Severity,
+		&trigger.IsEnabled,
+		&createdBy,
+		&trigger.CreatedAt,
+		&trigger.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(actionsJSON, &trigger.Actions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal actions: %w", err)
+	}
+	trigger.CreatedBy = createdBy.UUID
+	return trigger, nil
+}
+
+func scanTrustScoreTriggerFiring(row interface{ Scan(...interface{}) error }) (*domain.TrustScoreTriggerFiring, error) {
+	firing := &domain.TrustScoreTriggerFiring{}
+	var actionsJSON []byte
+	var alertID, incidentID uuid.NullUUID
+
+	err := row.Scan(
+		&firing.ID,
+		&firing.OrganizationID,
+		&firing.TriggerID,
+		&firing.TriggerName,
+		&firing.AgentID,
+		&firing.AgentName,
+		&firing.Condition,
+		&firing.Threshold,
+		&firing.PreviousScore,
+		&firing.Score,
+		&alertID,
+		&incidentID,
+		&actionsJSON,
+		&firing.FiredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(actionsJSON, &firing.Actions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal actions: %w", err)
+	}
+	if alertID.Valid {
+		firing.AlertID = &alertID.UUID
+	}
+	if incidentID.Valid {
+		firing.IncidentID = &incidentID.UUID
+	}
+	return firing, nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// UsageRollupRepository implements domain.UsageRollupRepository
+type UsageRollupRepository struct {
+	db *sql.DB
+}
+
+// NewUsageRollupRepository creates a new usage rollup repository
+func NewUsageRollupRepository(db *sql.DB) *UsageRollupRepository {
+	return &UsageRollupRepository{db: db}
+}
+
+// Upsert stores the rollups in one transaction, replacing those of the same subject and day
+func (r *UsageRollupRepository) Upsert(rollups []*domain.UsageRollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO usage_rollups (organization_id, subject_type, subject_id, day, requests, successes,
+			failures, pending, timeouts, errors, mcp_servers, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (subject_type, subject_id, day) DO UPDATE SET
+			organization_id = EXCLUDED.organization_id,
+			requests = EXCLUDED.requests,
+			successes = EXCLUDED.successes,
+			failures = EXCLUDED.failures,
+			pending = EXCLUDED.pending,
+			timeouts = EXCLUDED.timeouts,
+			errors = EXCLUDED.errors,
+			mcp_servers = EXCLUDED.mcp_servers,
+			updated_at = EXCLUDED.updated_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, rollup := range rollups {
+		mcpServersJSON, err := json.Marshal(rollup.MCPServers)
+		if err != nil {
+			return fmt.Errorf("failed to marshal mcp_servers: %w", err)
+		}
+		rollup.UpdatedAt = now
+		if _, err := stmt.Exec(
+			rollup.OrganizationID,
+			rollup.SubjectType,
+			rollup.SubjectID,
+			rollup.Day,
+			rollup.Requests,
+			rollup.Successes,
+			rollup.Failures,
+			rollup.Pending,
+			rollup.Timeouts,
+			rollup.Errors,
+			mcpServersJSON,
+			rollup.UpdatedAt,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// List retrieves the organization's rollups of a subject type, oldest first
+func (r *UsageRollupRepository) List(orgID uuid.UUID, subjectType domain.UsageSubjectType, subjectID *uuid.UUID, since, until time.Time) ([]*domain.UsageRollup, error) {
+	query := `
+		SELECT organization_id, subject_type, subject_id, day, requests, successes, failures, pending,
+			timeouts, errors, mcp_servers, updated_at
+		FROM usage_rollups
+		WHERE organization_id = $1 AND subject_type = $2 AND ($3::uuid IS NULL OR subject_id = $3)
+		AND day >= $4 AND day < $5
+		ORDER BY day ASC
+	`
+	rows, err := r.db.Query(query, orgID, subjectType, subjectID, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rollups := make([]*domain.UsageRollup, 0)
+	for rows.Next() {
+		rollup := &domain.UsageRollup{}
+		var mcpServersJSON []byte
+		if err := rows.Scan(
+			&rollup.OrganizationID,
+			&rollup.SubjectType,
+			&rollup.SubjectID,
+			&rollup.Day,
+			&rollup.Requests,
+			&rollup.Successes,
+			&rollup.Failures,
+			&rollup.Pending,
+			&rollup.Timeouts,
+			&rollup.Errors,
+			&mcpServersJSON,
+			&rollup.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(mcpServersJSON, &rollup.MCPServers); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal mcp_servers: %w", err)
+		}
+		rollup.Day = time.Date(rollup.Day.Year(), rollup.Day.Month(), rollup.Day.Day(), 0, 0, 0, 0, time.UTC)
+		rollups = append(rollups, rollup)
+	}
+	return rollups, rows.Err()
+}
+
+// LatestDay returns the most recent day rolled up
+func (r *UsageRollupRepository) LatestDay() (time.Time, bool, error) {
+	var day sql.NullTime
+	if err := r.db.QueryRow(`SELECT MAX(day) FROM usage_rollups`).Scan(&day); err != nil {
+		return time.Time{}, false, err
+	}
+	if !day.Valid {
+		return time.Time{}, false, nil
+	}
+	return time.Date(day.Time.Year(), day.Time.Month(), day.Time.Day(), 0, 0, 0, 0, time.UTC), true, nil
+}
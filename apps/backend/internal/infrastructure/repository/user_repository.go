@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
@@ -70,17 +71,65 @@ func (r *UserRepository) Create(user *domain.User) error {
 // GetByID retrieves a user by ID
 func (r *UserRepository) GetByID(id uuid.UUID) (*domain.User, error) {
 	query := `
-		SELECT id, organization_id, email, name, avatar_url, role,
-		       password_hash, force_password_change, last_login_at,
-		       status, created_at, updated_at, approved_by, approved_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1
 	`
 
+	user, err := scanUser(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// GetByIDs retrieves the users with the given IDs in one query; IDs that do not exist are skipped
+func (r *UserRepository) GetByIDs(ids []uuid.UUID) ([]*domain.User, error) {
+	users := make([]*domain.User, 0, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = ANY($1::uuid[])
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, pq.Array(uuidStrings(ids)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// userColumns are the columns read by scanUser
+const userColumns = `id, organization_id, email, name, avatar_url, role,
+		       password_hash, force_password_change, last_login_at,
+		       status, created_at, updated_at, approved_by, approved_at,
+		       mfa_enabled, mfa_secret, mfa_recovery_codes, mfa_last_used_step, mfa_enrolled_at`
+
+// scanUser reads a row selected with userColumns
+func scanUser(row rowScanner) (*domain.User, error) {
 	user := &domain.User{}
 	var status sql.NullString
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := row.Scan(
 		&user.ID,
 		&user.OrganizationID,
 		&user.Email,
@@ -95,11 +144,12 @@ func (r *UserRepository) GetByID(id uuid.UUID) (*domain.User, error) {
 		&user.UpdatedAt,
 		&user.ApprovedBy,
 		&user.ApprovedAt,
+		&user.MFAEnabled,
+		&user.MFASecret,
+		pq.Array(&user.MFARecoveryCodes),
+		&user.MFALastUsedStep,
+		&user.MFAEnrolledAt,
 	)
-
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
-	}
 	if err != nil {
 		return nil, err
 	}
@@ -114,36 +164,15 @@ func (r *UserRepository) GetByID(id uuid.UUID) (*domain.User, error) {
 	return user, nil
 }
 
-// GetByEmail retrieves a user by email (includes password_hash for authentication)
+// GetByEmail retrieves a user by email (includes password_hash and MFA state for authentication)
 func (r *UserRepository) GetByEmail(email string) (*domain.User, error) {
 	query := `
-		SELECT id, organization_id, email, name, avatar_url, role,
-		       password_hash, force_password_change, last_login_at,
-		       status, created_at, updated_at, approved_by, approved_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE email = $1
 	`
 
-	user := &domain.User{}
-	var status sql.NullString
-
-	err := r.db.QueryRow(query, email).Scan(
-		&user.ID,
-		&user.OrganizationID,
-		&user.Email,
-		&user.Name,
-		&user.AvatarURL,
-		&user.Role,
-		&user.PasswordHash,
-		&user.ForcePasswordChange,
-		&user.LastLoginAt,
-		&status,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.ApprovedBy,
-		&user.ApprovedAt,
-	)
-
+	user, err := scanUser(r.db.QueryRow(query, email))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("user not found")
 	}
@@ -151,13 +180,6 @@ func (r *UserRepository) GetByEmail(email string) (*domain.User, error) {
 		return nil, err
 	}
 
-	// Set status from database or default to active
-	if status.Valid {
-		user.Status = domain.UserStatus(status.String)
-	} else {
-		user.Status = domain.UserStatusActive
-	}
-
 	return user, nil
 }
 
@@ -218,7 +240,7 @@ func (r *UserRepository) GetByPasswordResetToken(resetToken string) (*domain.Use
 func (r *UserRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.User, error) {
 	query := `
 		SELECT id, organization_id, email, name, avatar_url, role,
-		       last_login_at, status, created_at, updated_at
+		       last_login_at, status, created_at, updated_at, mfa_enabled
 		FROM users
 		WHERE organization_id = $1
 		ORDER BY created_at DESC
@@ -246,6 +268,7 @@ func (r *UserRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.User, err
 			&status,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.MFAEnabled,
 		)
 		if err != nil {
 			return nil, err
@@ -314,6 +337,36 @@ func (r *UserRepository) Update(user *domain.User) error {
 	return err
 }
 
+// UpdateMFA stores a user's MFA enrollment, secret, recovery codes and last accepted TOTP step
+func (r *UserRepository) UpdateMFA(user *domain.User) error {
+	query := `
+		UPDATE users
+		SET mfa_enabled = $1, mfa_secret = $2, mfa_recovery_codes = $3,
+		    mfa_last_used_step = $4, mfa_enrolled_at = $5, updated_at = $6
+		WHERE id = $7
+	`
+
+	user.UpdatedAt = time.Now()
+
+	// A nil slice would be stored as NULL
+	recoveryCodes := user.MFARecoveryCodes
+	if recoveryCodes == nil {
+		recoveryCodes = []string{}
+	}
+
+	_, err := r.db.Exec(query,
+		user.MFAEnabled,
+		user.MFASecret,
+		pq.Array(recoveryCodes),
+		user.MFALastUsedStep,
+		user.MFAEnrolledAt,
+		user.UpdatedAt,
+		user.ID,
+	)
+
+	return err
+}
+
 // UpdateRole updates a user's role
 func (r *UserRepository) UpdateRole(id uuid.UUID, role domain.UserRole) error {
 	query := `UPDATE users SET role = $1, updated_at = $2 WHERE id = $3`
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
@@ -30,10 +31,16 @@ func (r *VerificationEventRepositorySimple) Create(event *domain.VerificationEve
 			confidence, trust_score, duration_ms, error_code, error_reason,
 			initiator_type, initiator_id, initiator_name, initiator_ip,
 			action, resource_type, resource_id, location,
-			started_at, completed_at, details, metadata
+			started_at, completed_at, details, metadata,
+			current_mcp_servers, current_capabilities, drift_detected, mcp_server_drift, capability_drift,
+			peer_agent_drift, current_mcp_tools, mcp_tool_drift, risk_level, auth_method,
+			initiator_user_id, initiator_agent_id, initiator_api_key_id, initiator_user_agent,
+			created_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16,
-			$17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28
+			$17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28,
+			$29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42,
+			COALESCE($43::timestamptz, CURRENT_TIMESTAMP)
 		) RETURNING id, created_at`
 
 	metadataJSON, err := json.Marshal(event.Metadata)
@@ -41,6 +48,28 @@ func (r *VerificationEventRepositorySimple) Create(event *domain.VerificationEve
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	// Drift columns are JSONB arrays; nil slices are stored as [] to match the column defaults
+	driftJSON := make([][]byte, 0, 7)
+	for _, values := range [][]string{event.CurrentMCPServers, event.CurrentCapabilities, event.MCPServerDrift, event.CapabilityDrift, event.PeerAgentDrift, event.CurrentMCPTools, event.MCPToolDrift} {
+		if values == nil {
+			values = []string{}
+		}
+		data, err := json.Marshal(values)
+		if err != nil {
+			return fmt.Errorf("failed to marshal drift details: %w", err)
+		}
+		driftJSON = append(driftJSON, data)
+	}
+
+	// Events without a recording time take the database's clock
+	createdAt := sql.NullTime{Time: event.CreatedAt, Valid: !event.CreatedAt.IsZero()}
+
+	// Events recorded without an assessment are low risk, like the column default
+	riskLevel := event.RiskLevel
+	if riskLevel == "" {
+		riskLevel = domain.VerificationRiskLow
+	}
+
 	return r.db.QueryRow(
 		query,
 		event.OrganizationID, event.AgentID, event.AgentName, event.Protocol, event.VerificationType,
@@ -49,6 +78,10 @@ func (r *VerificationEventRepositorySimple) Create(event *domain.VerificationEve
 		event.InitiatorType, event.InitiatorID, event.InitiatorName, event.InitiatorIP,
 		event.Action, event.ResourceType, event.ResourceID, event.Location,
 		event.StartedAt, event.CompletedAt, event.Details, metadataJSON,
+		driftJSON[0], driftJSON[1], event.DriftDetected, driftJSON[2], driftJSON[3],
+		driftJSON[4], driftJSON[5], driftJSON[6], riskLevel, event.AuthMethod,
+		event.InitiatorUserID, event.InitiatorAgentID, event.InitiatorAPIKeyID, event.InitiatorUserAgent,
+		createdAt,
 	).Scan(&event.ID, &event.CreatedAt)
 }
 
@@ -60,7 +93,8 @@ func (r *VerificationEventRepositorySimple) GetByID(id uuid.UUID) (*domain.Verif
 			confidence, trust_score, duration_ms, error_code, error_reason,
 			initiator_type, initiator_id, initiator_name, initiator_ip,
 			action, resource_type, resource_id, location,
-			started_at, completed_at, created_at, details, metadata
+			started_at, completed_at, created_at, details, metadata, risk_level, auth_method,
+			initiator_user_id, initiator_agent_id, initiator_api_key_id, initiator_user_agent
 		FROM verification_events WHERE id = $1`
 
 	event := &domain.VerificationEvent{}
@@ -81,7 +115,8 @@ func (r *VerificationEventRepositorySimple) GetByID(id uuid.UUID) (*domain.Verif
 		&errorReason, &initiatorType, &initiatorID, &initiatorName,
 		&initiatorIP, &action, &resourceType, &resourceID,
 		&location, &event.StartedAt, &completedAt, &event.CreatedAt,
-		&details, &metadataJSON,
+		&details, &metadataJSON, &event.RiskLevel, &event.AuthMethod,
+		&event.InitiatorUserID, &event.InitiatorAgentID, &event.InitiatorAPIKeyID, &event.InitiatorUserAgent,
 	)
 	if err != nil {
 		return nil, err
@@ -176,7 +211,8 @@ func (r *VerificationEventRepositorySimple) GetByOrganization(orgID uuid.UUID, l
 			confidence, trust_score, duration_ms, error_code, error_reason,
 			initiator_type, initiator_id, initiator_name, initiator_ip,
 			action, resource_type, resource_id, location,
-			started_at, completed_at, created_at, details, metadata
+			started_at, completed_at, created_at, details, metadata, risk_level, auth_method,
+			initiator_user_id, initiator_agent_id, initiator_api_key_id, initiator_user_agent
 		FROM verification_events
 		WHERE organization_id = $1
 		ORDER BY created_at DESC
@@ -208,7 +244,8 @@ func (r *VerificationEventRepositorySimple) GetByOrganization(orgID uuid.UUID, l
 			&errorReason, &initiatorType, &initiatorID, &initiatorName,
 			&initiatorIP, &action, &resourceType, &resourceID,
 			&location, &event.StartedAt, &completedAt, &event.CreatedAt,
-			&details, &metadataJSON,
+			&details, &metadataJSON, &event.RiskLevel, &event.AuthMethod,
+			&event.InitiatorUserID, &event.InitiatorAgentID, &event.InitiatorAPIKeyID, &event.InitiatorUserAgent,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -301,7 +338,8 @@ func (r *VerificationEventRepositorySimple) GetByAgent(agentID uuid.UUID, limit,
 			confidence, trust_score, duration_ms, error_code, error_reason,
 			initiator_type, initiator_id, initiator_name, initiator_ip,
 			action, resource_type, resource_id, location,
-			started_at, completed_at, created_at, details, metadata
+			started_at, completed_at, created_at, details, metadata, risk_level, auth_method,
+			initiator_user_id, initiator_agent_id, initiator_api_key_id, initiator_user_agent
 		FROM verification_events
 		WHERE agent_id = $1
 		ORDER BY created_at DESC
@@ -333,7 +371,8 @@ func (r *VerificationEventRepositorySimple) GetByAgent(agentID uuid.UUID, limit,
 			&errorReason, &initiatorType, &initiatorID, &initiatorName,
 			&initiatorIP, &action, &resourceType, &resourceID,
 			&location, &event.StartedAt, &completedAt, &event.CreatedAt,
-			&details, &metadataJSON,
+			&details, &metadataJSON, &event.RiskLevel, &event.AuthMethod,
+			&event.InitiatorUserID, &event.InitiatorAgentID, &event.InitiatorAPIKeyID, &event.InitiatorUserAgent,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -426,7 +465,8 @@ func (r *VerificationEventRepositorySimple) GetByMCPServer(mcpServerID uuid.UUID
 			confidence, trust_score, duration_ms, error_code, error_reason,
 			initiator_type, initiator_id, initiator_name, initiator_ip,
 			action, resource_type, resource_id, location,
-			started_at, completed_at, created_at, details, metadata
+			started_at, completed_at, created_at, details, metadata, risk_level, auth_method,
+			initiator_user_id, initiator_agent_id, initiator_api_key_id, initiator_user_agent
 		FROM verification_events
 		WHERE mcp_server_id = $1
 		ORDER BY created_at DESC
@@ -458,7 +498,8 @@ func (r *VerificationEventRepositorySimple) GetByMCPServer(mcpServerID uuid.UUID
 			&errorReason, &initiatorType, &initiatorID, &initiatorName,
 			&initiatorIP, &action, &resourceType, &resourceID,
 			&location, &event.StartedAt, &completedAt, &event.CreatedAt,
-			&details, &metadataJSON,
+			&details, &metadataJSON, &event.RiskLevel, &event.AuthMethod,
+			&event.InitiatorUserID, &event.InitiatorAgentID, &event.InitiatorAPIKeyID, &event.InitiatorUserAgent,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -546,7 +587,8 @@ func (r *VerificationEventRepositorySimple) GetRecentEvents(orgID uuid.UUID, min
 			confidence, trust_score, duration_ms, error_code, error_reason,
 			initiator_type, initiator_id, initiator_name, initiator_ip,
 			action, resource_type, resource_id, location,
-			started_at, completed_at, created_at, details, metadata
+			started_at, completed_at, created_at, details, metadata, risk_level, auth_method,
+			initiator_user_id, initiator_agent_id, initiator_api_key_id, initiator_user_agent
 		FROM verification_events
 		WHERE organization_id = $1
 		AND created_at >= NOW() - INTERVAL '1 minute' * $2
@@ -578,7 +620,8 @@ func (r *VerificationEventRepositorySimple) GetRecentEvents(orgID uuid.UUID, min
 			&errorReason, &initiatorType, &initiatorID, &initiatorName,
 			&initiatorIP, &action, &resourceType, &resourceID,
 			&location, &event.StartedAt, &completedAt, &event.CreatedAt,
-			&details, &metadataJSON,
+			&details, &metadataJSON, &event.RiskLevel, &event.AuthMethod,
+			&event.InitiatorUserID, &event.InitiatorAgentID, &event.InitiatorAPIKeyID, &event.InitiatorUserAgent,
 		)
 		if err != nil {
 			return nil, err
@@ -857,7 +900,8 @@ func (r *VerificationEventRepositorySimple) GetPendingVerifications(orgID uuid.U
 			confidence, trust_score, duration_ms, error_code, error_reason,
 			initiator_type, initiator_id, initiator_name, initiator_ip,
 			action, resource_type, resource_id, location,
-			started_at, completed_at, created_at, details, metadata
+			started_at, completed_at, created_at, details, metadata, risk_level, auth_method,
+			initiator_user_id, initiator_agent_id, initiator_api_key_id, initiator_user_agent
 		FROM verification_events
 		WHERE organization_id = $1
 		AND status = 'pending'
@@ -889,7 +933,8 @@ func (r *VerificationEventRepositorySimple) GetPendingVerifications(orgID uuid.U
 			&errorReason, &initiatorType, &initiatorID, &initiatorName,
 			&initiatorIP, &action, &resourceType, &resourceID,
 			&location, &event.StartedAt, &completedAt, &event.CreatedAt,
-			&details, &metadataJSON,
+			&details, &metadataJSON, &event.RiskLevel, &event.AuthMethod,
+			&event.InitiatorUserID, &event.InitiatorAgentID, &event.InitiatorAPIKeyID, &event.InitiatorUserAgent,
 		)
 		if err != nil {
 			return nil, err
@@ -966,6 +1011,51 @@ func (r *VerificationEventRepositorySimple) GetPendingVerifications(orgID uuid.U
 	return events, rows.Err()
 }
 
+// UpdatePendingResults applies one result to several pending verification events in a single transaction
+func (r *VerificationEventRepositorySimple) UpdatePendingResults(ids []uuid.UUID, result domain.VerificationResult, reason *string, metadata map[string]interface{}) error {
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	status := domain.VerificationEventStatusFailed
+	if result == domain.VerificationResultVerified {
+		status = domain.VerificationEventStatusSuccess
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		execResult, err := tx.Exec(`
+			UPDATE verification_events
+			SET
+				result = $1,
+				status = $2,
+				error_reason = COALESCE($3, error_reason),
+				metadata = COALESCE($4::jsonb, metadata),
+				completed_at = COALESCE(completed_at, NOW())
+			WHERE id = $5 AND status = 'pending'
+		`, string(result), string(status), reason, metadataJSON, id)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := execResult.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("verification event %s not found or no longer pending", id)
+		}
+	}
+
+	return tx.Commit()
+}
+
 // SearchAdminVerifications returns paginated verification events with filtering for admin UI
 func (r *VerificationEventRepositorySimple) SearchAdminVerifications(
 	orgID uuid.UUID,
@@ -992,10 +1082,28 @@ func (r *VerificationEventRepositorySimple) SearchAdminVerifications(
 	}
 
 	if params.RiskLevel != "" && strings.ToLower(params.RiskLevel) != "all" {
-		risk := strings.ToLower(params.RiskLevel)
 		placeholderIdx := len(args) + 1
-		filters = append(filters, fmt.Sprintf("((LOWER(metadata ->> 'risk_level') = $%d) OR (LOWER(metadata -> 'context' ->> 'risk_level') = $%d))", placeholderIdx, placeholderIdx))
-		args = append(args, risk)
+		filters = append(filters, fmt.Sprintf("risk_level = $%d", placeholderIdx))
+		args = append(args, strings.ToLower(params.RiskLevel))
+	}
+
+	// Agent and initiator filters; the initiator filters pivot to the events a credential recorded
+	columnFilters := []struct {
+		column string
+		value  interface{}
+		set    bool
+	}{
+		{"agent_id", params.AgentID, params.AgentID != nil},
+		{"initiator_user_id", params.InitiatorUserID, params.InitiatorUserID != nil},
+		{"initiator_agent_id", params.InitiatorAgentID, params.InitiatorAgentID != nil},
+		{"initiator_api_key_id", params.InitiatorAPIKeyID, params.InitiatorAPIKeyID != nil},
+		{"initiator_ip", params.InitiatorIP, params.InitiatorIP != ""},
+	}
+	for _, filter := range columnFilters {
+		if filter.set {
+			filters = append(filters, fmt.Sprintf("%s = $%d", filter.column, len(args)+1))
+			args = append(args, filter.value)
+		}
 	}
 
 	if params.Search != "" {
@@ -1061,7 +1169,8 @@ func (r *VerificationEventRepositorySimple) SearchAdminVerifications(
 			confidence, trust_score, duration_ms, error_code, error_reason,
 			initiator_type, initiator_id, initiator_name, initiator_ip,
 			action, resource_type, resource_id, location,
-			started_at, completed_at, created_at, details, metadata
+			started_at, completed_at, created_at, details, metadata, risk_level, auth_method,
+			initiator_user_id, initiator_agent_id, initiator_api_key_id, initiator_user_agent
 		FROM verification_events
 		WHERE %s
 		ORDER BY created_at DESC
@@ -1094,7 +1203,8 @@ func (r *VerificationEventRepositorySimple) SearchAdminVerifications(
 			&errorReason, &initiatorType, &initiatorID, &initiatorName,
 			&initiatorIP, &action, &resourceType, &resourceID,
 			&location, &event.StartedAt, &completedAt, &event.CreatedAt,
-			&details, &metadataJSON,
+			&details, &metadataJSON, &event.RiskLevel, &event.AuthMethod,
+			&event.InitiatorUserID, &event.InitiatorAgentID, &event.InitiatorAPIKeyID, &event.InitiatorUserAgent,
 		)
 		if err != nil {
 			return nil, 0, nil, err
@@ -1170,6 +1280,216 @@ func (r *VerificationEventRepositorySimple) SearchAdminVerifications(
 	return events, total, statusCounts, rows.Err()
 }
 
+// ListAfter returns the events created after the query cursor, oldest first
+func (r *VerificationEventRepositorySimple) ListAfter(orgID uuid.UUID, query domain.VerificationEventTailQuery) ([]*domain.VerificationEvent, error) {
+	filters := []string{
+		"organization_id = $1",
+		"(created_at, id) > ($2, $3)",
+	}
+	args := []interface{}{orgID, query.AfterCreatedAt, query.AfterID}
+
+	if query.AgentID != nil {
+		args = append(args, *query.AgentID)
+		filters = append(filters, fmt.Sprintf("agent_id = $%d", len(args)))
+	}
+	if query.MCPServerID != nil {
+		args = append(args, *query.MCPServerID)
+		filters = append(filters, fmt.Sprintf("mcp_server_id = $%d", len(args)))
+	}
+	if len(query.Statuses) > 0 {
+		statuses := make([]string, len(query.Statuses))
+		for i, status := range query.Statuses {
+			statuses[i] = string(status)
+		}
+		args = append(args, pq.Array(statuses))
+		filters = append(filters, fmt.Sprintf("status = ANY($%d)", len(args)))
+	}
+	if query.Protocol != "" {
+		args = append(args, query.Protocol)
+		filters = append(filters, fmt.Sprintf("protocol = $%d", len(args)))
+	}
+	if query.VerificationType != "" {
+		args = append(args, query.VerificationType)
+		filters = append(filters, fmt.Sprintf("verification_type = $%d", len(args)))
+	}
+	if query.DriftOnly {
+		filters = append(filters, "drift_detected = true")
+	}
+	if !query.Before.IsZero() {
+		args = append(args, query.Before)
+		filters = append(filters, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	args = append(args, query.Limit)
+
+	rows, err := r.db.Query(fmt.Sprintf(`
+		SELECT id, organization_id, agent_id, agent_name, mcp_server_id, mcp_server_name,
+			protocol, verification_type, status, result, signature, message_hash, nonce, public_key,
+			confidence, trust_score, duration_ms, error_code, error_reason,
+			initiator_type, initiator_id, initiator_name, initiator_ip,
+			action, resource_type, resource_id, location,
+			current_mcp_servers, current_capabilities, COALESCE(drift_detected, false), mcp_server_drift, capability_drift,
+			peer_agent_drift, current_mcp_tools, mcp_tool_drift, started_at, completed_at, created_at, details, metadata, risk_level, auth_method,
+			initiator_user_id, initiator_agent_id, initiator_api_key_id, initiator_user_agent
+		FROM verification_events
+		WHERE %s
+		ORDER BY created_at, id
+		LIMIT $%d
+	`, strings.Join(filters, " AND "), len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*domain.VerificationEvent{}
+	for rows.Next() {
+		event, err := scanTailedVerificationEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// scanTailedVerificationEvent reads a row selected by ListAfter, including the MCP server
+// target and drift details
+func scanTailedVerificationEvent(row rowScanner) (*domain.VerificationEvent, error) {
+	event := &domain.VerificationEvent{}
+	var agentID, mcpServerID, initiatorID uuid.NullUUID
+	var agentName, mcpServerName, resultStr, signature, messageHash, nonce, publicKey sql.NullString
+	var errorCode, errorReason, initiatorType, initiatorName, initiatorIP sql.NullString
+	var action, resourceType, resourceID, location, details sql.NullString
+	var completedAt sql.NullTime
+	var currentMCPServers, currentCapabilities, mcpServerDrift, capabilityDrift, peerAgentDrift, metadataJSON []byte
+	var currentMCPTools, mcpToolDrift []byte
+
+	err := row.Scan(
+		&event.ID, &event.OrganizationID, &agentID, &agentName, &mcpServerID, &mcpServerName,
+		&event.Protocol, &event.VerificationType, &event.Status, &resultStr,
+		&signature, &messageHash, &nonce, &publicKey,
+		&event.Confidence, &event.TrustScore, &event.DurationMs, &errorCode, &errorReason,
+		&initiatorType, &initiatorID, &initiatorName, &initiatorIP,
+		&action, &resourceType, &resourceID, &location,
+		&currentMCPServers, &currentCapabilities, &event.DriftDetected, &mcpServerDrift, &capabilityDrift,
+		&peerAgentDrift, &currentMCPTools, &mcpToolDrift, &event.StartedAt, &completedAt, &event.CreatedAt, &details, &metadataJSON, &event.RiskLevel, &event.AuthMethod,
+		&event.InitiatorUserID, &event.InitiatorAgentID, &event.InitiatorAPIKeyID, &event.InitiatorUserAgent,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if agentID.Valid {
+		event.AgentID = &agentID.UUID
+	}
+	if mcpServerID.Valid {
+		event.MCPServerID = &mcpServerID.UUID
+	}
+	if initiatorID.Valid {
+		event.InitiatorID = &initiatorID.UUID
+	}
+	if initiatorType.Valid {
+		event.InitiatorType = domain.InitiatorType(initiatorType.String)
+	} else {
+		event.InitiatorType = domain.InitiatorTypeSystem
+	}
+	if resultStr.Valid {
+		result := domain.VerificationResult(resultStr.String)
+		event.Result = &result
+	}
+	if completedAt.Valid {
+		event.CompletedAt = &completedAt.Time
+	}
+	if agentName.Valid {
+		event.AgentName = &agentName.String
+	}
+	if mcpServerName.Valid {
+		event.MCPServerName = &mcpServerName.String
+	}
+	if signature.Valid {
+		event.Signature = &signature.String
+	}
+	if messageHash.Valid {
+		event.MessageHash = &messageHash.String
+	}
+	if nonce.Valid {
+		event.Nonce = &nonce.String
+	}
+	if publicKey.Valid {
+		event.PublicKey = &publicKey.String
+	}
+	if errorCode.Valid {
+		event.ErrorCode = &errorCode.String
+	}
+	if errorReason.Valid {
+		event.ErrorReason = &errorReason.String
+	}
+	if initiatorName.Valid {
+		event.InitiatorName = &initiatorName.String
+	}
+	if initiatorIP.Valid {
+		event.InitiatorIP = &initiatorIP.String
+	}
+	if action.Valid {
+		event.Action = &action.String
+	}
+	if resourceType.Valid {
+		event.ResourceType = &resourceType.String
+	}
+	if resourceID.Valid {
+		event.ResourceID = &resourceID.String
+	}
+	if location.Valid {
+		event.Location = &location.String
+	}
+	if details.Valid {
+		event.Details = &details.String
+	}
+
+	// Drift columns are JSONB arrays
+	if len(currentMCPServers) > 0 {
+		if err := json.Unmarshal(currentMCPServers, &event.CurrentMCPServers); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal drift details: %w", err)
+		}
+	}
+	if len(currentCapabilities) > 0 {
+		if err := json.Unmarshal(currentCapabilities, &event.CurrentCapabilities); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal drift details: %w", err)
+		}
+	}
+	if len(mcpServerDrift) > 0 {
+		if err := json.Unmarshal(mcpServerDrift, &event.MCPServerDrift); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal drift details: %w", err)
+		}
+	}
+	if len(capabilityDrift) > 0 {
+		if err := json.Unmarshal(capabilityDrift, &event.CapabilityDrift); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal drift details: %w", err)
+		}
+	}
+	if len(peerAgentDrift) > 0 {
+		if err := json.Unmarshal(peerAgentDrift, &event.PeerAgentDrift); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal drift details: %w", err)
+		}
+	}
+	if len(currentMCPTools) > 0 {
+		if err := json.Unmarshal(currentMCPTools, &event.CurrentMCPTools); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal drift details: %w", err)
+		}
+	}
+	if len(mcpToolDrift) > 0 {
+		if err := json.Unmarshal(mcpToolDrift, &event.MCPToolDrift); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal drift details: %w", err)
+		}
+	}
+	if len(metadataJSON) > 0 {
+		if err := json.Unmarshal(metadataJSON, &event.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+	}
+
+	return event, nil
+}
+
 // GetAgentStatistics calculates per-agent verification statistics for trust scoring
 func (r *VerificationEventRepositorySimple) GetAgentStatistics(agentID uuid.UUID, startTime, endTime time.Time) (*domain.AgentVerificationStatistics, error) {
 	query := `
@@ -1223,3 +1543,140 @@ func (r *VerificationEventRepositorySimple) GetAgentStatistics(agentID uuid.UUID
 		LastVerification:   lastVerification,
 	}, nil
 }
+
+// GetHourlyAgentActivity counts each agent's verifications per hour, and per hour and reported
+// MCP server
+func (r *VerificationEventRepositorySimple) GetHourlyAgentActivity(orgID uuid.UUID, since, until time.Time) ([]*domain.AgentHourlyActivity, error) {
+	type key struct {
+		agentID uuid.UUID
+		hour    time.Time
+	}
+	var activity []*domain.AgentHourlyActivity
+	byHour := make(map[key]*domain.AgentHourlyActivity)
+
+	rows, err := r.db.Query(`
+		SELECT agent_id, date_trunc('hour', created_at AT TIME ZONE 'UTC') AS hour, COUNT(*)
+		FROM verification_events
+		WHERE organization_id = $1 AND agent_id IS NOT NULL
+		AND created_at >= $2 AND created_at < $3
+		GROUP BY agent_id, hour`, orgID, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count hourly agent verifications: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		hour := &domain.AgentHourlyActivity{MCPServers: map[string]int{}}
+		if err := rows.Scan(&hour.AgentID, &hour.Hour, &hour.Verifications); err != nil {
+			return nil, err
+		}
+		hour.Hour = time.Date(hour.Hour.Year(), hour.Hour.Month(), hour.Hour.Day(), hour.Hour.Hour(), 0, 0, 0, time.UTC)
+		byHour[key{agentID: hour.AgentID, hour: hour.Hour}] = hour
+		activity = append(activity, hour)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	serverRows, err := r.db.Query(`
+		SELECT e.agent_id, date_trunc('hour', e.created_at AT TIME ZONE 'UTC') AS hour, server.name, COUNT(*)
+		FROM verification_events e,
+			jsonb_array_elements_text(COALESCE(e.current_mcp_servers, '[]'::jsonb)) AS server(name)
+		WHERE e.organization_id = $1 AND e.agent_id IS NOT NULL
+		AND e.created_at >= $2 AND e.created_at < $3
+		GROUP BY e.agent_id, hour, server.name`, orgID, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count hourly agent MCP server usage: %w", err)
+	}
+	defer serverRows.Close()
+	for serverRows.Next() {
+		var agentID uuid.UUID
+		var hourStart time.Time
+		var server string
+		var count int
+		if err := serverRows.Scan(&agentID, &hourStart, &server, &count); err != nil {
+			return nil, err
+		}
+		hourStart = time.Date(hourStart.Year(), hourStart.Month(), hourStart.Day(), hourStart.Hour(), 0, 0, 0, time.UTC)
+		if hour, ok := byHour[key{agentID: agentID, hour: hourStart}]; ok {
+			hour.MCPServers[server] = count
+		}
+	}
+	return activity, serverRows.Err()
+}
+
+// verificationUsageSubjects selects each verification event once per subject it counts for: its
+// agent and the API key that recorded it
+const verificationUsageSubjects = `
+	SELECT organization_id, 'agent' AS subject_type, agent_id AS subject_id,
+		date_trunc('day', created_at AT TIME ZONE 'UTC') AS day, status, error_code, current_mcp_servers
+	FROM verification_events
+	WHERE agent_id IS NOT NULL AND created_at >= $1 AND created_at < $2
+	UNION ALL
+	SELECT organization_id, 'api_key', initiator_api_key_id,
+		date_trunc('day', created_at AT TIME ZONE 'UTC'), status, error_code, current_mcp_servers
+	FROM verification_events
+	WHERE initiator_api_key_id IS NOT NULL AND created_at >= $1 AND created_at < $2`
+
+// GetDailyUsage counts the verifications of each agent and API key per day and outcome, and per
+// day and reported MCP server
+func (r *VerificationEventRepositorySimple) GetDailyUsage(since, until time.Time) ([]*domain.UsageRollup, error) {
+	type key struct {
+		subjectType domain.UsageSubjectType
+		subjectID   uuid.UUID
+		day         time.Time
+	}
+	var rollups []*domain.UsageRollup
+	byDay := make(map[key]*domain.UsageRollup)
+
+	rows, err := r.db.Query(`
+		SELECT organization_id, subject_type, subject_id, day, COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'success'),
+			COUNT(*) FILTER (WHERE status = 'failed'),
+			COUNT(*) FILTER (WHERE status = 'pending'),
+			COUNT(*) FILTER (WHERE status = 'timeout'),
+			COUNT(*) FILTER (WHERE error_code IS NOT NULL)
+		FROM (`+verificationUsageSubjects+`) usage
+		GROUP BY organization_id, subject_type, subject_id, day`, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count daily usage: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rollup := &domain.UsageRollup{MCPServers: map[string]int{}}
+		if err := rows.Scan(&rollup.OrganizationID, &rollup.SubjectType, &rollup.SubjectID, &rollup.Day, &rollup.Requests,
+			&rollup.Successes, &rollup.Failures, &rollup.Pending, &rollup.Timeouts, &rollup.Errors); err != nil {
+			return nil, err
+		}
+		rollup.Day = time.Date(rollup.Day.Year(), rollup.Day.Month(), rollup.Day.Day(), 0, 0, 0, 0, time.UTC)
+		byDay[key{subjectType: rollup.SubjectType, subjectID: rollup.SubjectID, day: rollup.Day}] = rollup
+		rollups = append(rollups, rollup)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	serverRows, err := r.db.Query(`
+		SELECT usage.subject_type, usage.subject_id, usage.day, server.name, COUNT(*)
+		FROM (`+verificationUsageSubjects+`) usage,
+			jsonb_array_elements_text(COALESCE(usage.current_mcp_servers, '[]'::jsonb)) AS server(name)
+		GROUP BY usage.subject_type, usage.subject_id, usage.day, server.name`, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count daily MCP server usage: %w", err)
+	}
+	defer serverRows.Close()
+	for serverRows.Next() {
+		var subjectType domain.UsageSubjectType
+		var subjectID uuid.UUID
+		var day time.Time
+		var server string
+		var count int
+		if err := serverRows.Scan(&subjectType, &subjectID, &day, &server, &count); err != nil {
+			return nil, err
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+		if rollup, ok := byDay[key{subjectType: subjectType, subjectID: subjectID, day: day}]; ok {
+			rollup.MCPServers[server] = count
+		}
+	}
+	return rollups, serverRows.Err()
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// VerificationExportRepository implements domain.VerificationExportRepository
+type VerificationExportRepository struct {
+	db *sql.DB
+}
+
+// NewVerificationExportRepository creates a new verification export repository
+func NewVerificationExportRepository(db *sql.DB) *VerificationExportRepository {
+	return &VerificationExportRepository{db: db}
+}
+
+const verificationExportColumns = `id, organization_id, requested_by, format, filter, status, row_count, size, filename, content_type, artifact_name, error, created_at, started_at, completed_at, expires_at`
+
+// Create stores a new export request
+func (r *VerificationExportRepository) Create(export *domain.VerificationExport) error {
+	query := `
+		INSERT INTO verification_exports (` + verificationExportColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+
+	if export.ID == uuid.Nil {
+		export.ID = uuid.New()
+	}
+	if export.CreatedAt.IsZero() {
+		export.CreatedAt = time.Now().UTC()
+	}
+	filterJSON, err := json.Marshal(export.Filter)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query,
+		export.ID,
+		export.OrganizationID,
+		export.RequestedBy,
+		export.Format,
+		filterJSON,
+		export.Status,
+		export.RowCount,
+		export.Size,
+		nullString(export.Filename),
+		nullString(export.ContentType),
+		nullString(export.ArtifactName),
+		export.Error,
+		export.CreatedAt,
+		export.StartedAt,
+		export.CompletedAt,
+		export.ExpiresAt,
+	)
+	return err
+}
+
+// GetByID retrieves an export by ID
+func (r *VerificationExportRepository) GetByID(id uuid.UUID) (*domain.VerificationExport, error) {
+	query := `SELECT ` + verificationExportColumns + ` FROM verification_exports WHERE id = $1`
+
+	export, err := scanVerificationExport(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrVerificationExportNotFound
+	}
+	return export, err
+}
+
+// ListByOrganization returns the organization's most recent exports, newest first
+func (r *VerificationExportRepository) ListByOrganization(orgID uuid.UUID, limit int) ([]*domain.VerificationExport, error) {
+	query := `
+		SELECT ` + verificationExportColumns + `
+		FROM verification_exports
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	return r.query(query, orgID, limit)
+}
+
+// ClaimPending atomically marks pending exports, and running exports whose worker stopped
+// before finishing, as running
+func (r *VerificationExportRepository) ClaimPending(limit int, staleBefore time.Time) ([]*domain.VerificationExport, error) {
+	query := `
+		UPDATE verification_exports
+		SET status = 'running', started_at = NOW()
+		WHERE id IN (
+			SELECT id FROM verification_exports
+			WHERE status = 'pending' OR (status = 'running' AND started_at < $2)
+			ORDER BY created_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + verificationExportColumns
+
+	exports, err := r.query(query, limit, staleBefore)
+	if err != nil {
+		return nil, err
+	}
+	// RETURNING does not follow the subquery's order
+	sort.Slice(exports, func(i, j int) bool {
+		return exports[i].CreatedAt.Before(exports[j].CreatedAt)
+	})
+	return exports, nil
+}
+
+// Finish stores the outcome of a claimed export
+func (r *VerificationExportRepository) Finish(export *domain.VerificationExport) error {
+	query := `
+		UPDATE verification_exports
+		SET status = $1, row_count = $2, size = $3, filename = $4, content_type = $5, artifact_name = $6,
+			error = $7, completed_at = $8, expires_at = $9
+		WHERE id = $10
+	`
+	result, err := r.db.Exec(query,
+		export.Status,
+		export.RowCount,
+		export.Size,
+		nullString(export.Filename),
+		nullString(export.ContentType),
+		nullString(export.ArtifactName),
+		export.Error,
+		export.CompletedAt,
+		export.ExpiresAt,
+		export.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrVerificationExportNotFound
+	}
+	return nil
+}
+
+func (r *VerificationExportRepository) query(query string, args ...interface{}) ([]*domain.VerificationExport, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exports := make([]*domain.VerificationExport, 0)
+	for rows.Next() {
+		export, err := scanVerificationExport(rows)
+		if err != nil {
+			return nil, err
+		}
+		exports = append(exports, export)
+	}
+	return exports, rows.Err()
+}
+
+func scanVerificationExport(row rowScanner) (*domain.VerificationExport, error) {
+	export := &domain.VerificationExport{}
+	var filterJSON []byte
+	var filename, contentType, artifactName, errMsg sql.NullString
+	var startedAt, completedAt, expiresAt sql.NullTime
+
+	err := row.Scan(
+		&export.ID,
+		&export.OrganizationID,
+		&export.RequestedBy,
+		&export.Format,
+		&filterJSON,
+		&export.Status,
+		&export.RowCount,
+		&export.Size,
+		&filename,
+		&contentType,
+		&artifactName,
+		&errMsg,
+		&export.CreatedAt,
+		&startedAt,
+		&completedAt,
+		&expiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(filterJSON, &export.Filter); err != nil {
+		return nil, err
+	}
+	export.Filename = filename.String
+	export.ContentType = contentType.String
+	export.ArtifactName = artifactName.String
+	if errMsg.Valid {
+		export.Error = &errMsg.String
+	}
+	if startedAt.Valid {
+		export.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		export.CompletedAt = &completedAt.Time
+	}
+	if expiresAt.Valid {
+		export.ExpiresAt = &expiresAt.Time
+	}
+
+	return export, nil
+}
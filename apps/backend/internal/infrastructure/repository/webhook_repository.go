@@ -21,8 +21,8 @@ func NewWebhookRepository(db *sql.DB) *WebhookRepository {
 func (r *WebhookRepository) Create(webhook *domain.Webhook) error {
 	query := `
 		INSERT INTO webhooks (
-			id, organization_id, name, url, events, secret, is_active, created_by, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			id, organization_id, name, url, events, filter, template_type, template, secret, require_mtls, is_active, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	events := make([]string, len(webhook.Events))
@@ -37,7 +37,11 @@ func (r *WebhookRepository) Create(webhook *domain.Webhook) error {
 		webhook.Name,
 		webhook.URL,
 		pq.Array(events),
+		webhook.Filter,
+		webhook.TemplateType,
+		webhook.Template,
 		webhook.Secret,
+		webhook.RequireMTLS,
 		webhook.IsActive,
 		webhook.CreatedBy,
 		time.Now().UTC(),
@@ -49,7 +53,7 @@ func (r *WebhookRepository) Create(webhook *domain.Webhook) error {
 
 func (r *WebhookRepository) GetByID(id uuid.UUID) (*domain.Webhook, error) {
 	query := `
-		SELECT id, organization_id, name, url, events, secret, is_active, last_triggered, failure_count, created_by, created_at, updated_at
+		SELECT id, organization_id, name, url, events, filter, template_type, template, secret, require_mtls, is_active, last_triggered, failure_count, created_by, created_at, updated_at
 		FROM webhooks
 		WHERE id = $1
 	`
@@ -63,7 +67,11 @@ func (r *WebhookRepository) GetByID(id uuid.UUID) (*domain.Webhook, error) {
 		&webhook.Name,
 		&webhook.URL,
 		pq.Array(&events),
+		&webhook.Filter,
+		&webhook.TemplateType,
+		&webhook.Template,
 		&webhook.Secret,
+		&webhook.RequireMTLS,
 		&webhook.IsActive,
 		&webhook.LastTriggered,
 		&webhook.FailureCount,
@@ -89,7 +97,7 @@ func (r *WebhookRepository) GetByID(id uuid.UUID) (*domain.Webhook, error) {
 
 func (r *WebhookRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Webhook, error) {
 	query := `
-		SELECT id, organization_id, name, url, events, secret, is_active, last_triggered, failure_count, created_by, created_at, updated_at
+		SELECT id, organization_id, name, url, events, filter, template_type, template, secret, require_mtls, is_active, last_triggered, failure_count, created_by, created_at, updated_at
 		FROM webhooks
 		WHERE organization_id = $1
 		ORDER BY created_at DESC
@@ -112,7 +120,11 @@ func (r *WebhookRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Webhoo
 			&webhook.Name,
 			&webhook.URL,
 			pq.Array(&events),
+			&webhook.Filter,
+			&webhook.TemplateType,
+			&webhook.Template,
 			&webhook.Secret,
+			&webhook.RequireMTLS,
 			&webhook.IsActive,
 			&webhook.LastTriggered,
 			&webhook.FailureCount,
@@ -138,8 +150,9 @@ func (r *WebhookRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Webhoo
 func (r *WebhookRepository) Update(webhook *domain.Webhook) error {
 	query := `
 		UPDATE webhooks
-		SET name = $1, url = $2, events = $3, is_active = $4, updated_at = $5
-		WHERE id = $6
+		SET name = $1, url = $2, events = $3, filter = $4, template_type = $5, template = $6, require_mtls = $7,
+			is_active = $8, updated_at = $9
+		WHERE id = $10
 	`
 
 	events := make([]string, len(webhook.Events))
@@ -152,6 +165,10 @@ func (r *WebhookRepository) Update(webhook *domain.Webhook) error {
 		webhook.Name,
 		webhook.URL,
 		pq.Array(events),
+		webhook.Filter,
+		webhook.TemplateType,
+		webhook.Template,
+		webhook.RequireMTLS,
 		webhook.IsActive,
 		time.Now().UTC(),
 		webhook.ID,
@@ -166,24 +183,50 @@ func (r *WebhookRepository) Delete(id uuid.UUID) error {
 	return err
 }
 
+const webhookDeliveryColumns = `id, webhook_id, event, payload, status_code, response_body, success, attempt_count,
+	status, max_attempts, next_attempt_at, last_error, created_at, updated_at`
+
+// RecordDelivery stores a delivery; queued deliveries are stored as pending
 func (r *WebhookRepository) RecordDelivery(delivery *domain.WebhookDelivery) error {
+	now := time.Now().UTC()
+	if delivery.ID == uuid.Nil {
+		delivery.ID = uuid.New()
+	}
+	if delivery.Status == "" {
+		delivery.Status = domain.WebhookDeliveryPending
+	}
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = now
+	}
+	delivery.CreatedAt = now
+	delivery.UpdatedAt = now
+
 	query := `
-		INSERT INTO webhook_deliveries (
-			id, webhook_id, event, payload, status_code, response_body, success, attempt_count, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO webhook_deliveries (` + webhookDeliveryColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
+	var statusCode sql.NullInt64
+	if delivery.StatusCode != 0 {
+		statusCode = sql.NullInt64{Int64: int64(delivery.StatusCode), Valid: true}
+	}
+
 	_, err := r.db.Exec(
 		query,
 		delivery.ID,
 		delivery.WebhookID,
 		delivery.Event,
 		delivery.Payload,
-		delivery.StatusCode,
+		statusCode,
 		delivery.ResponseBody,
 		delivery.Success,
 		delivery.AttemptCount,
-		time.Now().UTC(),
+		delivery.Status,
+		delivery.MaxAttempts,
+		delivery.NextAttemptAt,
+		delivery.LastError,
+		delivery.CreatedAt,
+		delivery.UpdatedAt,
 	)
 
 	return err
@@ -191,14 +234,73 @@ func (r *WebhookRepository) RecordDelivery(delivery *domain.WebhookDelivery) err
 
 func (r *WebhookRepository) GetDeliveries(webhookID uuid.UUID, limit, offset int) ([]*domain.WebhookDelivery, error) {
 	query := `
-		SELECT id, webhook_id, event, payload, status_code, response_body, success, attempt_count, created_at
+		SELECT ` + webhookDeliveryColumns + `
 		FROM webhook_deliveries
 		WHERE webhook_id = $1
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Query(query, webhookID, limit, offset)
+	return r.queryDeliveries(query, webhookID, limit, offset)
+}
+
+// ClaimDueDeliveries atomically claims due deliveries for a worker.
+// Claimed rows move to "sending" and their next_attempt_at becomes the lease expiry,
+// so deliveries held by a crashed worker are picked up again once the lease runs out.
+func (r *WebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'sending', next_attempt_at = $2, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status IN ('pending', 'sending') AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns
+
+	return r.queryDeliveries(query, limit, time.Now().UTC().Add(lease))
+}
+
+// RecordAttempt stores the outcome of a delivery attempt and keeps the webhook's
+// last_triggered and failure_count in step with it
+func (r *WebhookRepository) RecordAttempt(id uuid.UUID, status domain.WebhookDeliveryStatus, statusCode int, responseBody, errMsg string, nextAttemptAt time.Time) error {
+	var code sql.NullInt64
+	if statusCode != 0 {
+		code = sql.NullInt64{Int64: int64(statusCode), Valid: true}
+	}
+	var lastError sql.NullString
+	if errMsg != "" {
+		lastError = sql.NullString{String: errMsg, Valid: true}
+	}
+
+	var webhookID uuid.UUID
+	err := r.db.QueryRow(`
+		UPDATE webhook_deliveries
+		SET status = $1, status_code = $2, response_body = $3, last_error = $4, next_attempt_at = $5,
+			success = ($1 = 'delivered'), attempt_count = attempt_count + 1, updated_at = NOW()
+		WHERE id = $6
+		RETURNING webhook_id
+	`, status, code, responseBody, lastError, nextAttemptAt, id).Scan(&webhookID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("webhook delivery not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case domain.WebhookDeliveryDelivered:
+		_, err = r.db.Exec(`UPDATE webhooks SET last_triggered = NOW() WHERE id = $1`, webhookID)
+	case domain.WebhookDeliveryFailed:
+		_, err = r.db.Exec(`UPDATE webhooks SET last_triggered = NOW(), failure_count = failure_count + 1 WHERE id = $1`, webhookID)
+	}
+	return err
+}
+
+func (r *WebhookRepository) queryDeliveries(query string, args ...interface{}) ([]*domain.WebhookDelivery, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -207,22 +309,95 @@ func (r *WebhookRepository) GetDeliveries(webhookID uuid.UUID, limit, offset int
 	var deliveries []*domain.WebhookDelivery
 	for rows.Next() {
 		delivery := &domain.WebhookDelivery{}
+		var statusCode sql.NullInt64
+		var responseBody, lastError sql.NullString
+
 		err := rows.Scan(
 			&delivery.ID,
 			&delivery.WebhookID,
 			&delivery.Event,
 			&delivery.Payload,
-			&delivery.StatusCode,
-			&delivery.ResponseBody,
+			&statusCode,
+			&responseBody,
 			&delivery.Success,
 			&delivery.AttemptCount,
+			&delivery.Status,
+			&delivery.MaxAttempts,
+			&delivery.NextAttemptAt,
+			&lastError,
 			&delivery.CreatedAt,
+			&delivery.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+
+		delivery.StatusCode = int(statusCode.Int64)
+		delivery.ResponseBody = responseBody.String
+		if lastError.Valid {
+			delivery.LastError = &lastError.String
+		}
 		deliveries = append(deliveries, delivery)
 	}
 
-	return deliveries, nil
+	return deliveries, rows.Err()
+}
+
+// GetEgressSettings returns the organization's webhook egress settings, or nil when none are stored
+func (r *WebhookRepository) GetEgressSettings(orgID uuid.UUID) (*domain.WebhookEgressSettings, error) {
+	query := `
+		SELECT organization_id, client_certificate, client_key, proxy_url, egress_ips, updated_by, updated_at
+		FROM webhook_egress_settings
+		WHERE organization_id = $1
+	`
+
+	settings := &domain.WebhookEgressSettings{}
+	err := r.db.QueryRow(query, orgID).Scan(
+		&settings.OrganizationID,
+		&settings.ClientCertificate,
+		&settings.ClientKey,
+		&settings.ProxyURL,
+		pq.Array(&settings.EgressIPs),
+		&settings.UpdatedBy,
+		&settings.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// UpsertEgressSettings stores the organization's webhook egress settings
+func (r *WebhookRepository) UpsertEgressSettings(settings *domain.WebhookEgressSettings) error {
+	query := `
+		INSERT INTO webhook_egress_settings (organization_id, client_certificate, client_key, proxy_url, egress_ips, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (organization_id) DO UPDATE
+		SET client_certificate = EXCLUDED.client_certificate, client_key = EXCLUDED.client_key,
+			proxy_url = EXCLUDED.proxy_url, egress_ips = EXCLUDED.egress_ips,
+			updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`
+
+	settings.UpdatedAt = time.Now().UTC()
+	egressIPs := settings.EgressIPs
+	if egressIPs == nil {
+		egressIPs = []string{}
+	}
+
+	_, err := r.db.Exec(
+		query,
+		settings.OrganizationID,
+		settings.ClientCertificate,
+		settings.ClientKey,
+		settings.ProxyURL,
+		pq.Array(egressIPs),
+		settings.UpdatedBy,
+		settings.UpdatedAt,
+	)
+
+	return err
 }
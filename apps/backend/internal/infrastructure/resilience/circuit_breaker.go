@@ -0,0 +1,276 @@
+// Package resilience keeps outages of external dependencies from cascading into the identity
+// plane. Outbound calls go through circuit breakers, one per dependency endpoint: after
+// repeated failures a breaker opens and calls to the endpoint fail immediately instead of
+// waiting out their timeouts, until a trial call after a cooldown shows it has recovered.
+package resilience
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// External dependencies whose calls go through circuit breakers
+const (
+	DependencyMCPServers          = "mcp_servers"           // MCP server verification, health and capability probes
+	DependencyWebhooks            = "webhooks"              // Webhook deliveries
+	DependencyVulnerabilityFeed   = "vulnerability_feed"    // OSV vulnerability lookups of agent SBOMs
+	DependencyPolicyDecisionPoint = "policy_decision_point" // External policy decision points (OPA)
+	DependencyTicketing           = "ticketing"             // Jira and ServiceNow
+	DependencyNotifications       = "notifications"         // Slack, PagerDuty and webhook notification channels
+	DependencyObjectStorage       = "object_storage"        // S3, GCS and Azure Blob Storage
+	DependencyEmail               = "email"                 // SendGrid and Amazon SES
+	DependencyCaptcha             = "captcha"               // CAPTCHA verification of logins (reCAPTCHA, hCaptcha, Turnstile)
+)
+
+// State is the state of a circuit breaker
+type State string
+
+const (
+	StateClosed   State = "closed"    // Calls go through
+	StateOpen     State = "open"      // Calls fail immediately until the cooldown is over
+	StateHalfOpen State = "half_open" // One trial call goes through; its outcome closes or reopens the circuit
+)
+
+// Dependency health, from the states of its endpoints' circuits
+const (
+	HealthHealthy     = "healthy"     // No circuit is open
+	HealthDegraded    = "degraded"    // Some endpoints are failing
+	HealthUnavailable = "unavailable" // Every endpoint called recently is failing
+)
+
+// ErrCircuitOpen is returned for calls to an endpoint whose circuit is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// OpenError reports a call rejected because the endpoint's circuit is open
+type OpenError struct {
+	Dependency string
+	Endpoint   string
+	RetryAt    time.Time // When a trial call will be let through
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s %s is unavailable (circuit open until %s)", e.Dependency, e.Endpoint, e.RetryAt.Format(time.RFC3339))
+}
+
+func (e *OpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// Settings tune when circuits open and how long they stay open
+type Settings struct {
+	FailureThreshold int           // Consecutive failures that open a circuit
+	Cooldown         time.Duration // How long a circuit stays open before a trial call
+}
+
+// DefaultSettings are the settings unless configured otherwise
+var DefaultSettings = Settings{
+	FailureThreshold: 5,
+	Cooldown:         30 * time.Second,
+}
+
+// Outcome is how a call went, as far as the health of the endpoint is concerned
+type Outcome int
+
+const (
+	OutcomeSuccess Outcome = iota
+	OutcomeFailure
+	OutcomeIgnored // The caller gave up; says nothing about the endpoint
+)
+
+// breaker is the circuit of one dependency endpoint
+type breaker struct {
+	state               State
+	consecutiveFailures int
+	lastError           string
+	lastFailureAt       time.Time
+	openedAt            time.Time
+	trialInFlight       bool
+}
+
+// Registry holds the circuit breakers of every dependency endpoint
+type Registry struct {
+	mu           sync.Mutex
+	settings     Settings
+	dependencies map[string]map[string]*breaker // Dependency -> endpoint -> circuit
+	now          func() time.Time
+}
+
+// Default is the registry outbound HTTP clients are wrapped with
+var Default = NewRegistry(DefaultSettings)
+
+// NewRegistry creates an empty registry
+func NewRegistry(settings Settings) *Registry {
+	r := &Registry{
+		dependencies: make(map[string]map[string]*breaker),
+		now:          time.Now,
+	}
+	r.Configure(settings)
+	return r
+}
+
+// Configure replaces the settings; zero values keep the defaults
+func (r *Registry) Configure(settings Settings) {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = DefaultSettings.FailureThreshold
+	}
+	if settings.Cooldown <= 0 {
+		settings.Cooldown = DefaultSettings.Cooldown
+	}
+	r.mu.Lock()
+	r.settings = settings
+	r.mu.Unlock()
+}
+
+// Register makes a dependency show in Health before it is first called
+func (r *Registry) Register(dependency string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.dependencies[dependency]; !ok {
+		r.dependencies[dependency] = make(map[string]*breaker)
+	}
+}
+
+// Allow reports whether a call to the endpoint may go ahead, returning an *OpenError when its
+// circuit is open. A call that is allowed must be followed by Record.
+func (r *Registry) Allow(dependency, endpoint string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := r.breaker(dependency, endpoint)
+	switch b.state {
+	case StateOpen:
+		retryAt := b.openedAt.Add(r.settings.Cooldown)
+		if r.now().Before(retryAt) {
+			return &OpenError{Dependency: dependency, Endpoint: endpoint, RetryAt: retryAt}
+		}
+		b.state = StateHalfOpen
+		b.trialInFlight = true
+		return nil
+	case StateHalfOpen:
+		if b.trialInFlight {
+			return &OpenError{Dependency: dependency, Endpoint: endpoint, RetryAt: r.now()}
+		}
+		b.trialInFlight = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record records the outcome of an allowed call. Failures opening the circuit and successes
+// closing it again are logged.
+func (r *Registry) Record(dependency, endpoint string, outcome Outcome, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := r.breaker(dependency, endpoint)
+	trial := b.state == StateHalfOpen
+	if trial {
+		b.trialInFlight = false
+	}
+
+	switch outcome {
+	case OutcomeSuccess:
+		if b.state != StateClosed {
+			log.Printf("✅ Circuit closed: %s %s recovered", dependency, endpoint)
+		}
+		b.state = StateClosed
+		b.consecutiveFailures = 0
+	case OutcomeFailure:
+		b.consecutiveFailures++
+		b.lastFailureAt = r.now()
+		if err != nil {
+			b.lastError = err.Error()
+		}
+		if trial || (b.state == StateClosed && b.consecutiveFailures >= r.settings.FailureThreshold) {
+			b.state = StateOpen
+			b.openedAt = r.now()
+			log.Printf("⚠️  Circuit opened: %s %s failed %d times in a row (last: %s); failing fast for %s",
+				dependency, endpoint, b.consecutiveFailures, b.lastError, r.settings.Cooldown)
+		}
+	}
+}
+
+// breaker returns the endpoint's circuit, creating a closed one; r.mu must be held
+func (r *Registry) breaker(dependency, endpoint string) *breaker {
+	endpoints, ok := r.dependencies[dependency]
+	if !ok {
+		endpoints = make(map[string]*breaker)
+		r.dependencies[dependency] = endpoints
+	}
+	b, ok := endpoints[endpoint]
+	if !ok {
+		b = &breaker{state: StateClosed}
+		endpoints[endpoint] = b
+	}
+	return b
+}
+
+// CircuitHealth is the state of one dependency endpoint's circuit
+type CircuitHealth struct {
+	Endpoint            string     `json:"endpoint"`
+	State               State      `json:"state"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastError           string     `json:"lastError,omitempty"`
+	LastFailureAt       *time.Time `json:"lastFailureAt,omitempty"`
+	RetryAt             *time.Time `json:"retryAt,omitempty"` // When an open circuit lets a trial call through
+}
+
+// DependencyHealth is the health of a dependency across its endpoints
+type DependencyHealth struct {
+	Name         string          `json:"name"`
+	Status       string          `json:"status"` // healthy, degraded or unavailable
+	Endpoints    int             `json:"endpoints"`
+	OpenCircuits int             `json:"openCircuits"`
+	Circuits     []CircuitHealth `json:"circuits,omitempty"`
+}
+
+// Health returns the health of every dependency, by name, with the circuits of their endpoints
+// (failing ones first)
+func (r *Registry) Health() []DependencyHealth {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	health := make([]DependencyHealth, 0, len(r.dependencies))
+	for name, endpoints := range r.dependencies {
+		dependency := DependencyHealth{Name: name, Status: HealthHealthy, Endpoints: len(endpoints)}
+		for endpoint, b := range endpoints {
+			circuit := CircuitHealth{
+				Endpoint:            endpoint,
+				State:               b.state,
+				ConsecutiveFailures: b.consecutiveFailures,
+				LastError:           b.lastError,
+			}
+			if !b.lastFailureAt.IsZero() {
+				lastFailureAt := b.lastFailureAt
+				circuit.LastFailureAt = &lastFailureAt
+			}
+			if b.state != StateClosed {
+				dependency.OpenCircuits++
+				retryAt := b.openedAt.Add(r.settings.Cooldown)
+				circuit.RetryAt = &retryAt
+			}
+			dependency.Circuits = append(dependency.Circuits, circuit)
+		}
+		switch {
+		case dependency.OpenCircuits > 0 && dependency.OpenCircuits == dependency.Endpoints:
+			dependency.Status = HealthUnavailable
+		case dependency.OpenCircuits > 0:
+			dependency.Status = HealthDegraded
+		}
+		sort.Slice(dependency.Circuits, func(i, j int) bool {
+			a, b := dependency.Circuits[i], dependency.Circuits[j]
+			if (a.State == StateClosed) != (b.State == StateClosed) {
+				return b.State == StateClosed
+			}
+			return a.Endpoint < b.Endpoint
+		})
+		health = append(health, dependency)
+	}
+	sort.Slice(health, func(i, j int) bool { return health[i].Name < health[j].Name })
+	return health
+}
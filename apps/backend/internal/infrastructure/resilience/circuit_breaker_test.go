@@ -0,0 +1,141 @@
+package resilience
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitOpensAfterConsecutiveFailuresAndClosesAfterATrialCall(t *testing.T) {
+	registry := NewRegistry(Settings{FailureThreshold: 3, Cooldown: time.Minute})
+	now := time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return now }
+	call := func(outcome Outcome) error {
+		if err := registry.Allow(DependencyWebhooks, "hooks.example.com"); err != nil {
+			return err
+		}
+		registry.Record(DependencyWebhooks, "hooks.example.com", outcome, assert.AnError)
+		return nil
+	}
+
+	// A success resets the count; only consecutive failures open the circuit
+	require.NoError(t, call(OutcomeFailure))
+	require.NoError(t, call(OutcomeFailure))
+	require.NoError(t, call(OutcomeSuccess))
+	require.NoError(t, call(OutcomeFailure))
+	require.NoError(t, call(OutcomeFailure))
+	require.NoError(t, call(OutcomeFailure))
+	err := call(OutcomeSuccess)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	var openErr *OpenError
+	require.ErrorAs(t, err, &openErr)
+	assert.Equal(t, now.Add(time.Minute), openErr.RetryAt)
+
+	// Other endpoints of the dependency are unaffected
+	require.NoError(t, registry.Allow(DependencyWebhooks, "other.example.com"))
+	registry.Record(DependencyWebhooks, "other.example.com", OutcomeSuccess, nil)
+
+	health := registry.Health()
+	require.Len(t, health, 1)
+	assert.Equal(t, HealthDegraded, health[0].Status)
+	assert.Equal(t, 2, health[0].Endpoints)
+	assert.Equal(t, 1, health[0].OpenCircuits)
+	assert.Equal(t, "hooks.example.com", health[0].Circuits[0].Endpoint)
+	assert.Equal(t, StateOpen, health[0].Circuits[0].State)
+	assert.Equal(t, assert.AnError.Error(), health[0].Circuits[0].LastError)
+
+	// After the cooldown one trial call goes through; a failed trial reopens the circuit
+	now = now.Add(time.Minute)
+	require.NoError(t, registry.Allow(DependencyWebhooks, "hooks.example.com"))
+	assert.ErrorIs(t, registry.Allow(DependencyWebhooks, "hooks.example.com"), ErrCircuitOpen)
+	registry.Record(DependencyWebhooks, "hooks.example.com", OutcomeFailure, assert.AnError)
+	assert.ErrorIs(t, call(OutcomeSuccess), ErrCircuitOpen)
+
+	// A cancelled trial says nothing about the endpoint; a successful one closes the circuit
+	now = now.Add(time.Minute)
+	require.NoError(t, call(OutcomeIgnored))
+	require.NoError(t, call(OutcomeSuccess))
+	require.NoError(t, call(OutcomeSuccess))
+	assert.Equal(t, HealthHealthy, registry.Health()[0].Status)
+}
+
+func TestTransportFailsFastWhileAnEndpointIsDown(t *testing.T) {
+	var hits atomic.Int32
+	var down atomic.Bool
+	down.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch {
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case down.Load():
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	registry := NewRegistry(Settings{FailureThreshold: 2, Cooldown: time.Minute})
+	now := time.Now()
+	registry.now = func() time.Time { return now }
+	registry.Register(DependencyPolicyDecisionPoint)
+	registry.Register(DependencyTicketing)
+	client := &http.Client{Transport: &Transport{Registry: registry, Dependency: DependencyPolicyDecisionPoint}}
+	get := func(path string) (int, error) {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	// Client errors are the caller's problem, not the dependency's
+	for i := 0; i < 3; i++ {
+		status, err := get("/missing")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	}
+
+	// Server errors open the circuit, after which requests never reach the server
+	for i := 0; i < 2; i++ {
+		status, err := get("/")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+	}
+	_, err := get("/")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(5), hits.Load())
+
+	// Requests the caller cancels are not counted against the endpoint
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	_, err = (&http.Client{Transport: &Transport{Registry: registry, Dependency: DependencyTicketing}}).Do(req)
+	require.Error(t, err)
+
+	health := registry.Health()
+	require.Len(t, health, 2)
+	assert.Equal(t, DependencyPolicyDecisionPoint, health[0].Name)
+	assert.Equal(t, HealthUnavailable, health[0].Status)
+	assert.True(t, strings.Contains(health[0].Circuits[0].LastError, "503"))
+	assert.Equal(t, DependencyTicketing, health[1].Name)
+	assert.Equal(t, HealthHealthy, health[1].Status)
+	assert.Zero(t, health[1].Circuits[0].ConsecutiveFailures)
+
+	// Once the endpoint recovers, the trial call after the cooldown closes the circuit
+	down.Store(false)
+	now = now.Add(time.Minute)
+	status, err := get("/")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, HealthHealthy, registry.Health()[0].Status)
+}
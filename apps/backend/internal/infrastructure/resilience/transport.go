@@ -0,0 +1,67 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Transport is an http.RoundTripper that sends requests through the circuit of their host.
+// Connection errors, timeouts, 5xx responses and 429 (throttled) responses count as failures;
+// requests the caller cancels count as neither.
+type Transport struct {
+	Registry   *Registry
+	Dependency string
+	Base       http.RoundTripper // http.DefaultTransport when nil
+}
+
+// NewTransport wraps base with the circuits of the dependency in the Default registry
+func NewTransport(dependency string, base http.RoundTripper) *Transport {
+	Default.Register(dependency)
+	return &Transport{Registry: Default, Dependency: dependency, Base: base}
+}
+
+// WrapClient sends the client's requests through the circuits of the dependency in the Default
+// registry and returns the client
+func WrapClient(client *http.Client, dependency string) *http.Client {
+	client.Transport = NewTransport(dependency, client.Transport)
+	return client
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := req.URL.Host
+	if err := t.Registry.Allow(t.Dependency, endpoint); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	resp, err := t.base().RoundTrip(req)
+	switch {
+	case err != nil && errors.Is(req.Context().Err(), context.Canceled):
+		t.Registry.Record(t.Dependency, endpoint, OutcomeIgnored, err)
+	case err != nil:
+		t.Registry.Record(t.Dependency, endpoint, OutcomeFailure, err)
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		t.Registry.Record(t.Dependency, endpoint, OutcomeFailure, errors.New(resp.Status))
+	default:
+		t.Registry.Record(t.Dependency, endpoint, OutcomeSuccess, nil)
+	}
+	return resp, err
+}
+
+// CloseIdleConnections closes the idle connections of the base transport
+func (t *Transport) CloseIdleConnections() {
+	if closer, ok := t.base().(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
@@ -0,0 +1,198 @@
+package siem
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// Device fields of CEF and LEEF headers
+const (
+	deviceVendor  = "OpenA2A"
+	deviceProduct = "AIM"
+	deviceVersion = "1.0"
+	appName       = "aim"
+)
+
+// syslogSeverities maps alert severities to syslog severities (RFC 5424 section 6.2.1)
+var syslogSeverities = map[domain.AlertSeverity]int{
+	domain.AlertSeverityCritical: 2, // Critical
+	domain.AlertSeverityHigh:     3, // Error
+	domain.AlertSeverityWarning:  4, // Warning
+	domain.AlertSeverityInfo:     6, // Informational
+}
+
+// eventSeverities maps alert severities to the 0-10 severity of CEF and the 1-10 severity of LEEF
+var eventSeverities = map[domain.AlertSeverity]int{
+	domain.AlertSeverityCritical: 10,
+	domain.AlertSeverityHigh:     8,
+	domain.AlertSeverityWarning:  5,
+	domain.AlertSeverityInfo:     3,
+}
+
+// Message renders the event as an RFC 5424 syslog message whose MSG is a CEF or LEEF record
+func Message(exporter *domain.SIEMExporter, hostname string, event *domain.SIEMEvent) string {
+	severity, ok := syslogSeverities[event.Severity]
+	if !ok {
+		severity = 5 // Notice
+	}
+	record := CEF(event)
+	if exporter.Format == domain.SIEMFormatLEEF {
+		record = LEEF(event)
+	}
+	return fmt.Sprintf("<%d>1 %s %s %s - %s - %s",
+		exporter.Facility*8+severity,
+		event.OccurredAt.UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		headerValue(hostname, 255),
+		appName,
+		headerValue(event.Category, 32),
+		record,
+	)
+}
+
+// CEF renders the event as a Common Event Format record
+func CEF(event *domain.SIEMEvent) string {
+	extensions := []string{
+		"rt=" + strconv.FormatInt(event.OccurredAt.UnixMilli(), 10),
+		"cat=" + cefExtension(event.Category),
+		"externalId=" + event.ID.String(),
+		"cs1Label=organizationId",
+		"cs1=" + event.OrganizationID.String(),
+	}
+	if event.ResourceType != "" {
+		extensions = append(extensions, "cs2Label=resourceType", "cs2="+cefExtension(event.ResourceType))
+	}
+	if event.ResourceID != nil {
+		extensions = append(extensions, "cs3Label=resourceId", "cs3="+event.ResourceID.String())
+	}
+	if net.ParseIP(event.SourceIP) != nil {
+		extensions = append(extensions, "src="+event.SourceIP)
+	}
+	if event.Message != "" {
+		extensions = append(extensions, "msg="+cefExtension(event.Message))
+	}
+	for _, key := range sortedKeys(event.Fields) {
+		extensions = append(extensions, cefKey(key)+"="+cefExtension(event.Fields[key]))
+	}
+
+	return fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s",
+		deviceVendor, deviceProduct, deviceVersion,
+		cefHeader(signatureID(event)),
+		cefHeader(event.Name),
+		eventSeverities[event.Severity],
+		strings.Join(extensions, " "),
+	)
+}
+
+// LEEF renders the event as a LEEF 1.0 record with tab-separated attributes; devTime is in epoch
+// milliseconds, which QRadar reads without a devTimeFormat
+func LEEF(event *domain.SIEMEvent) string {
+	attributes := []string{
+		"devTime=" + strconv.FormatInt(event.OccurredAt.UnixMilli(), 10),
+		"sev=" + strconv.Itoa(max(eventSeverities[event.Severity], 1)),
+		"cat=" + leefValue(event.Category),
+		"name=" + leefValue(event.Name),
+		"externalId=" + event.ID.String(),
+		"organizationId=" + event.OrganizationID.String(),
+	}
+	if event.ResourceType != "" {
+		attributes = append(attributes, "resourceType="+leefValue(event.ResourceType))
+	}
+	if event.ResourceID != nil {
+		attributes = append(attributes, "resourceId="+event.ResourceID.String())
+	}
+	if net.ParseIP(event.SourceIP) != nil {
+		attributes = append(attributes, "src="+event.SourceIP)
+	}
+	if event.Message != "" {
+		attributes = append(attributes, "msg="+leefValue(event.Message))
+	}
+	for _, key := range sortedKeys(event.Fields) {
+		attributes = append(attributes, cefKey(key)+"="+leefValue(event.Fields[key]))
+	}
+
+	return fmt.Sprintf("LEEF:1.0|%s|%s|%s|%s|%s",
+		deviceVendor, deviceProduct, deviceVersion,
+		leefHeader(signatureID(event)),
+		strings.Join(attributes, "\t"),
+	)
+}
+
+// signatureID identifies the kind of event, e.g. "threat:brute_force"
+func signatureID(event *domain.SIEMEvent) string {
+	if event.Type == "" {
+		return event.Category
+	}
+	return event.Category + ":" + event.Type
+}
+
+// cefHeader escapes backslashes and pipes in CEF header fields
+func cefHeader(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return singleLine(value)
+}
+
+// cefExtension escapes backslashes, equal signs and line breaks in CEF extension values
+func cefExtension(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "=", `\=`)
+	value = strings.ReplaceAll(value, "\r\n", `\n`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return strings.ReplaceAll(value, "\r", `\r`)
+}
+
+// cefKey keeps the letters and digits of a custom extension key
+func cefKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, key)
+}
+
+// leefHeader replaces pipes, which end LEEF header fields
+func leefHeader(value string) string {
+	return singleLine(strings.ReplaceAll(value, "|", "/"))
+}
+
+// leefValue replaces tabs, which separate LEEF attributes, and line breaks
+func leefValue(value string) string {
+	return singleLine(strings.ReplaceAll(value, "\t", " "))
+}
+
+func singleLine(value string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(value)
+}
+
+// headerValue makes a syslog header field: printable ASCII without spaces, at most limit
+// characters, or "-" when empty
+func headerValue(value string, limit int) string {
+	value = strings.Map(func(r rune) rune {
+		if r > ' ' && r < 127 {
+			return r
+		}
+		return -1
+	}, value)
+	if len(value) > limit {
+		value = value[:limit]
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func sortedKeys(fields map[string]string) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
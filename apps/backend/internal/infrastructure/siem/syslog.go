@@ -0,0 +1,128 @@
+package siem
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	// dialTimeout bounds connecting to a SIEM
+	dialTimeout = 10 * time.Second
+	// writeTimeout bounds writing one batch, so a stalled SIEM can't hold the exporter
+	writeTimeout = 30 * time.Second
+	// maxUDPMessage keeps datagrams below common path MTUs; longer messages are truncated
+	maxUDPMessage = 1400
+)
+
+// Sink streams events to one exporter's SIEM over syslog. It keeps its connection open between
+// batches and reconnects on the next batch after a failure.
+type Sink struct {
+	exporter  *domain.SIEMExporter
+	hostname  string
+	tlsConfig *tls.Config
+	conn      net.Conn
+}
+
+var _ domain.SIEMSink = (*Sink)(nil)
+
+// ForExporter returns a sink for the exporter
+func ForExporter(exporter *domain.SIEMExporter) (*Sink, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = ""
+	}
+	sink := &Sink{exporter: exporter, hostname: hostname}
+
+	if exporter.Transport == domain.SIEMTransportTLS {
+		sink.tlsConfig = &tls.Config{ServerName: exporter.Host, MinVersion: tls.VersionTLS12}
+		if exporter.CACertificate != "" {
+			roots := x509.NewCertPool()
+			if !roots.AppendCertsFromPEM([]byte(exporter.CACertificate)) {
+				return nil, errors.New("CA certificate is not a PEM certificate")
+			}
+			sink.tlsConfig.RootCAs = roots
+		}
+	}
+	return sink, nil
+}
+
+// Send writes the events as one syslog message each: one datagram per message over UDP, and
+// octet-counted frames over TCP and TLS
+func (s *Sink) Send(ctx context.Context, events []*domain.SIEMEvent) error {
+	if s.conn == nil {
+		if err := s.dial(ctx); err != nil {
+			return err
+		}
+	}
+
+	deadline := time.Now().Add(writeTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := s.conn.SetWriteDeadline(deadline); err != nil {
+		s.Close()
+		return err
+	}
+
+	for _, event := range events {
+		message := Message(s.exporter, s.hostname, event)
+		var frame []byte
+		if s.exporter.Transport == domain.SIEMTransportUDP {
+			if len(message) > maxUDPMessage {
+				message = message[:maxUDPMessage]
+			}
+			frame = []byte(message)
+		} else {
+			frame = []byte(strconv.Itoa(len(message)) + " " + message)
+		}
+		if _, err := s.conn.Write(frame); err != nil {
+			s.Close()
+			return fmt.Errorf("failed to write to %s: %w", s.address(), err)
+		}
+	}
+	return nil
+}
+
+// Close closes the connection to the SIEM
+func (s *Sink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func (s *Sink) dial(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var conn net.Conn
+	var err error
+	switch s.exporter.Transport {
+	case domain.SIEMTransportUDP:
+		conn, err = dialer.DialContext(ctx, "udp", s.address())
+	case domain.SIEMTransportTCP:
+		conn, err = dialer.DialContext(ctx, "tcp", s.address())
+	case domain.SIEMTransportTLS:
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: s.tlsConfig}).DialContext(ctx, "tcp", s.address())
+	default:
+		return fmt.Errorf("unsupported syslog transport %q", s.exporter.Transport)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", s.address(), err)
+	}
+	s.conn = conn
+	return nil
+}
+
+func (s *Sink) address() string {
+	return net.JoinHostPort(s.exporter.Host, strconv.Itoa(s.exporter.Port))
+}
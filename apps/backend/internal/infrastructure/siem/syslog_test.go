@@ -0,0 +1,144 @@
+package siem
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent() *domain.SIEMEvent {
+	resourceID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	return &domain.SIEMEvent{
+		ID:             uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		OrganizationID: uuid.MustParse("33333333-3333-3333-3333-333333333333"),
+		Category:       domain.SIEMEventThreat,
+		Type:           "brute_force",
+		Name:           "Brute force | agent",
+		Message:        "5 failed logins\nuser=admin\tfrom a\\b",
+		Severity:       domain.AlertSeverityHigh,
+		ResourceType:   "agent",
+		ResourceID:     &resourceID,
+		SourceIP:       "203.0.113.7",
+		OccurredAt:     time.Date(2025, 11, 13, 10, 30, 0, 0, time.UTC),
+		Fields:         map[string]string{"blocked": "true", "error-code": "a=b"},
+	}
+}
+
+func TestCEFEscapesHeaderAndExtensions(t *testing.T) {
+	record := CEF(testEvent())
+
+	assert.Equal(t, `CEF:0|OpenA2A|AIM|1.0|threat:brute_force|Brute force \| agent|8|`+
+		`rt=1763029800000 cat=threat externalId=11111111-1111-1111-1111-111111111111 `+
+		`cs1Label=organizationId cs1=33333333-3333-3333-3333-333333333333 `+
+		`cs2Label=resourceType cs2=agent cs3Label=resourceId cs3=22222222-2222-2222-2222-222222222222 `+
+		`src=203.0.113.7 msg=5 failed logins\nuser\=admin`+"\t"+`from a\\b blocked=true errorcode=a\=b`, record)
+	assert.NotContains(t, record, "\n")
+}
+
+func TestLEEFSeparatesAttributesWithTabs(t *testing.T) {
+	record := LEEF(testEvent())
+
+	fields := strings.SplitN(record, "|", 6)
+	require.Len(t, fields, 6)
+	assert.Equal(t, []string{"LEEF:1.0", "OpenA2A", "AIM", "1.0", "threat:brute_force"}, fields[:5])
+	assert.Equal(t, []string{
+		"devTime=1763029800000",
+		"sev=8",
+		"cat=threat",
+		"name=Brute force | agent",
+		"externalId=11111111-1111-1111-1111-111111111111",
+		"organizationId=33333333-3333-3333-3333-333333333333",
+		"resourceType=agent",
+		"resourceId=22222222-2222-2222-2222-222222222222",
+		"src=203.0.113.7",
+		`msg=5 failed logins user=admin from a\b`,
+		"blocked=true",
+		"errorcode=a=b",
+	}, strings.Split(fields[5], "\t"))
+}
+
+func TestMessageCarriesSyslogPriorityAndHeader(t *testing.T) {
+	exporter := &domain.SIEMExporter{Format: domain.SIEMFormatLEEF, Facility: 10}
+
+	message := Message(exporter, "aim host", testEvent())
+
+	// Facility 10 (authpriv) * 8 + severity 3 (error) for a high event
+	assert.True(t, strings.HasPrefix(message, "<83>1 2025-11-13T10:30:00.000Z aimhost aim - threat - LEEF:1.0|"), message)
+}
+
+func TestSinkSendsOctetCountedFramesOverTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		var messages []string
+		for len(messages) < 2 {
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(length))
+			if err != nil {
+				return
+			}
+			message := make([]byte, n)
+			if _, err := io.ReadFull(reader, message); err != nil {
+				return
+			}
+			messages = append(messages, string(message))
+		}
+		received <- messages
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	sink, err := ForExporter(&domain.SIEMExporter{
+		Host:      "127.0.0.1",
+		Port:      port,
+		Transport: domain.SIEMTransportTCP,
+		Format:    domain.SIEMFormatCEF,
+		Facility:  10,
+	})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	second := testEvent()
+	second.Name = "Second"
+	require.NoError(t, sink.Send(context.Background(), []*domain.SIEMEvent{testEvent(), second}))
+
+	select {
+	case messages := <-received:
+		require.Len(t, messages, 2)
+		assert.Contains(t, messages[0], "|Brute force \\| agent|8|")
+		assert.Contains(t, messages[1], "|Second|8|")
+	case <-time.After(5 * time.Second):
+		t.Fatal("syslog messages not received")
+	}
+}
+
+func TestForExporterRejectsInvalidCACertificate(t *testing.T) {
+	_, err := ForExporter(&domain.SIEMExporter{
+		Host:          "siem.example.com",
+		Port:          6514,
+		Transport:     domain.SIEMTransportTLS,
+		CACertificate: "not a certificate",
+	})
+	assert.Error(t, err)
+}
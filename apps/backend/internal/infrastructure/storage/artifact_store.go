@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.ArtifactStore = (*ArtifactStore)(nil)
+
+// artifactNamePattern restricts artifact names to characters every provider accepts unencoded
+var artifactNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*(/[A-Za-z0-9][A-Za-z0-9._-]*)*$`)
+
+// maxBucketNameLength is the shortest bucket name limit among the providers (S3, GCS and Azure: 63)
+const maxBucketNameLength = 63
+
+// LifecycleHook is consulted before an artifact past its retention is deleted. Returning
+// false keeps the artifact, e.g. while it is under legal hold or still referenced.
+type LifecycleHook func(ctx context.Context, artifact *domain.Artifact) bool
+
+// ArtifactStore implements domain.ArtifactStore on top of a provider's buckets. Artifacts of
+// each organization live under "<prefix>/orgs/<organization ID>/<kind>/" of the shared bucket,
+// or under "<prefix>/<kind>/" of the organization's own bucket.
+type ArtifactStore struct {
+	config  Config
+	buckets map[string]Bucket
+	hooks   map[domain.ArtifactKind][]LifecycleHook
+	mu      sync.Mutex
+}
+
+// NewArtifactStore creates an artifact store; the provider configuration is validated up front
+func NewArtifactStore(config Config) (*ArtifactStore, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("storage bucket is required")
+	}
+	if config.BucketPerOrganization && len(config.Bucket)+1+len(uuid.Nil.String()) > maxBucketNameLength {
+		return nil, fmt.Errorf("storage bucket %q is too long to prefix per-organization bucket names", config.Bucket)
+	}
+	if _, err := NewBucket(config, config.Bucket); err != nil {
+		return nil, err
+	}
+	return &ArtifactStore{
+		config:  config,
+		buckets: make(map[string]Bucket),
+		hooks:   make(map[domain.ArtifactKind][]LifecycleHook),
+	}, nil
+}
+
+// NewArtifactStoreFromEnv creates an artifact store from the STORAGE_* environment variables
+func NewArtifactStoreFromEnv() (*ArtifactStore, error) {
+	config, err := LoadConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load storage configuration: %w", err)
+	}
+	return NewArtifactStore(config)
+}
+
+// Provider returns the configured storage provider
+func (s *ArtifactStore) Provider() ProviderType {
+	return s.config.Provider
+}
+
+// OnExpire registers a hook consulted before artifacts of the kind are deleted by ApplyLifecycle
+func (s *ArtifactStore) OnExpire(kind domain.ArtifactKind, hook LifecycleHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks[kind] = append(s.hooks[kind], hook)
+}
+
+func (s *ArtifactStore) Put(ctx context.Context, orgID uuid.UUID, kind domain.ArtifactKind, name string, data []byte, contentType string) (*domain.Artifact, error) {
+	bucket, key, err := s.locate(ctx, orgID, kind, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := bucket.Put(ctx, key, data, contentType); err != nil {
+		return nil, err
+	}
+	return &domain.Artifact{
+		OrganizationID: orgID,
+		Kind:           kind,
+		Name:           name,
+		ContentType:    contentType,
+		Size:           int64(len(data)),
+		ModifiedAt:     time.Now().UTC(),
+	}, nil
+}
+
+func (s *ArtifactStore) Get(ctx context.Context, orgID uuid.UUID, kind domain.ArtifactKind, name string) (*domain.Artifact, error) {
+	bucket, key, err := s.locate(ctx, orgID, kind, name)
+	if err != nil {
+		return nil, err
+	}
+	object, err := bucket.Get(ctx, key)
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, domain.ErrArtifactNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Artifact{
+		OrganizationID: orgID,
+		Kind:           kind,
+		Name:           name,
+		ContentType:    object.ContentType,
+		Size:           object.Size,
+		ModifiedAt:     object.ModifiedAt,
+		Data:           object.Data,
+	}, nil
+}
+
+func (s *ArtifactStore) Delete(ctx context.Context, orgID uuid.UUID, kind domain.ArtifactKind, name string) error {
+	bucket, key, err := s.locate(ctx, orgID, kind, name)
+	if err != nil {
+		return err
+	}
+	return bucket.Delete(ctx, key)
+}
+
+func (s *ArtifactStore) List(ctx context.Context, orgID uuid.UUID, kind domain.ArtifactKind) ([]*domain.Artifact, error) {
+	bucket, err := s.bucket(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := s.kindPrefix(orgID, kind)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := bucket.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts := make([]*domain.Artifact, 0, len(objects))
+	for _, object := range objects {
+		artifacts = append(artifacts, &domain.Artifact{
+			OrganizationID: orgID,
+			Kind:           kind,
+			Name:           strings.TrimPrefix(object.Key, prefix),
+			ContentType:    object.ContentType,
+			Size:           object.Size,
+			ModifiedAt:     object.ModifiedAt,
+		})
+	}
+	return artifacts, nil
+}
+
+// ApplyLifecycle deletes the organization's artifacts older than their kind's retention,
+// unless a hook registered for the kind keeps them
+func (s *ArtifactStore) ApplyLifecycle(ctx context.Context, orgID uuid.UUID) (int, error) {
+	deleted := 0
+	for _, kind := range domain.ArtifactKinds {
+		retention := s.config.Retention[kind]
+		if retention <= 0 {
+			continue
+		}
+		artifacts, err := s.List(ctx, orgID, kind)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to list %s artifacts: %w", kind, err)
+		}
+
+		s.mu.Lock()
+		hooks := s.hooks[kind]
+		s.mu.Unlock()
+
+		cutoff := time.Now().Add(-retention)
+		for _, artifact := range artifacts {
+			if !artifact.ModifiedAt.Before(cutoff) || !expireAllowed(ctx, hooks, artifact) {
+				continue
+			}
+			if err := s.Delete(ctx, orgID, kind, artifact.Name); err != nil {
+				return deleted, fmt.Errorf("failed to delete expired %s artifact %s: %w", kind, artifact.Name, err)
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func expireAllowed(ctx context.Context, hooks []LifecycleHook, artifact *domain.Artifact) bool {
+	for _, hook := range hooks {
+		if !hook(ctx, artifact) {
+			return false
+		}
+	}
+	return true
+}
+
+// locate returns the bucket and object key of an artifact
+func (s *ArtifactStore) locate(ctx context.Context, orgID uuid.UUID, kind domain.ArtifactKind, name string) (Bucket, string, error) {
+	if !artifactNamePattern.MatchString(name) {
+		return nil, "", fmt.Errorf("invalid artifact name: %q", name)
+	}
+	prefix, err := s.kindPrefix(orgID, kind)
+	if err != nil {
+		return nil, "", err
+	}
+	bucket, err := s.bucket(ctx, orgID)
+	if err != nil {
+		return nil, "", err
+	}
+	return bucket, prefix + name, nil
+}
+
+// kindPrefix is the key prefix, ending in "/", of an organization's artifacts of a kind
+func (s *ArtifactStore) kindPrefix(orgID uuid.UUID, kind domain.ArtifactKind) (string, error) {
+	if orgID == uuid.Nil {
+		return "", fmt.Errorf("organization ID is required")
+	}
+	known := false
+	for _, k := range domain.ArtifactKinds {
+		known = known || k == kind
+	}
+	if !known {
+		return "", fmt.Errorf("unknown artifact kind: %q", kind)
+	}
+	if s.config.BucketPerOrganization {
+		return path.Join(s.config.Prefix, string(kind)) + "/", nil
+	}
+	return path.Join(s.config.Prefix, "orgs", orgID.String(), string(kind)) + "/", nil
+}
+
+// bucket opens (and, for per-organization buckets and local storage, creates) the bucket
+// holding an organization's artifacts
+func (s *ArtifactStore) bucket(ctx context.Context, orgID uuid.UUID) (Bucket, error) {
+	name := s.config.Bucket
+	if s.config.BucketPerOrganization {
+		name = s.config.Bucket + "-" + orgID.String()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bucket, ok := s.buckets[name]; ok {
+		return bucket, nil
+	}
+
+	bucket, err := NewBucket(s.config, name)
+	if err != nil {
+		return nil, err
+	}
+	if s.config.BucketPerOrganization || s.config.Provider == ProviderLocal {
+		if err := bucket.Create(ctx); err != nil {
+			return nil, err
+		}
+	}
+	s.buckets[name] = bucket
+	return bucket, nil
+}
@@ -0,0 +1,283 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
+)
+
+// azureStorageVersion is the Blob service REST API version requests are made against
+const azureStorageVersion = "2021-08-06"
+
+// AzureBucket talks to the Azure Blob Storage REST API with a shared account key. The
+// bucket is a container of the storage account.
+type AzureBucket struct {
+	account    string
+	accountKey []byte
+	container  string
+	endpoint   *url.URL
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewAzureBucket creates a client for the named container
+func NewAzureBucket(config Config, name string) (*AzureBucket, error) {
+	if config.AzureAccount == "" || config.AzureAccountKey == "" {
+		return nil, fmt.Errorf("azure storage account and account key are required")
+	}
+	if name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid container name: %q", name)
+	}
+	accountKey, err := base64.StdEncoding.DecodeString(config.AzureAccountKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode azure storage account key: %w", err)
+	}
+	endpoint := config.AzureEndpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", config.AzureAccount)
+	}
+	parsed, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid azure storage endpoint: %q", endpoint)
+	}
+
+	return &AzureBucket{
+		account:    config.AzureAccount,
+		accountKey: accountKey,
+		container:  name,
+		endpoint:   parsed,
+		httpClient: resilience.WrapClient(&http.Client{Timeout: s3RequestTimeout}, resilience.DependencyObjectStorage),
+		now:        time.Now,
+	}, nil
+}
+
+// azureErrorResponse is the error document returned by the Blob service
+type azureErrorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// azureListResponse is a page of List Blobs
+type azureListResponse struct {
+	Blobs []struct {
+		Name       string `xml:"Name"`
+		Properties struct {
+			LastModified  string `xml:"Last-Modified"`
+			ContentLength int64  `xml:"Content-Length"`
+			ContentType   string `xml:"Content-Type"`
+		} `xml:"Properties"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+func (b *AzureBucket) Create(ctx context.Context) error {
+	resp, err := b.do(ctx, http.MethodPut, "", url.Values{"restype": {"container"}}, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusCreated {
+		return nil
+	}
+	apiErr := readAzureError(resp)
+	if apiErr.Code == "ContainerAlreadyExists" {
+		return nil
+	}
+	return fmt.Errorf("failed to create container %s: %s", b.container, apiErr.describe(resp.StatusCode))
+}
+
+func (b *AzureBucket) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	headers := map[string]string{"x-ms-blob-type": "BlockBlob"}
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+	resp, err := b.do(ctx, http.MethodPut, key, nil, data, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to store blob: %s", readAzureError(resp).describe(resp.StatusCode))
+	}
+	return nil
+}
+
+func (b *AzureBucket) Get(ctx context.Context, key string) (*Object, error) {
+	resp, err := b.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read blob: %s", readAzureError(resp).describe(resp.StatusCode))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+
+	object := &Object{
+		ObjectInfo: ObjectInfo{Key: key, ContentType: resp.Header.Get("Content-Type"), Size: int64(len(data))},
+		Data:       data,
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		object.ModifiedAt = modified.UTC()
+	}
+	return object, nil
+}
+
+func (b *AzureBucket) Delete(ctx context.Context, key string) error {
+	resp, err := b.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete blob: %s", readAzureError(resp).describe(resp.StatusCode))
+	}
+	return nil
+}
+
+func (b *AzureBucket) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	marker := ""
+	for {
+		query := url.Values{"restype": {"container"}, "comp": {"list"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		resp, err := b.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			apiErr := readAzureError(resp)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list blobs: %s", apiErr.describe(resp.StatusCode))
+		}
+		var page azureListResponse
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode blob listing: %w", err)
+		}
+
+		for _, blob := range page.Blobs {
+			object := ObjectInfo{Key: blob.Name, ContentType: blob.Properties.ContentType, Size: blob.Properties.ContentLength}
+			if modified, err := http.ParseTime(blob.Properties.LastModified); err == nil {
+				object.ModifiedAt = modified.UTC()
+			}
+			objects = append(objects, object)
+		}
+		if page.NextMarker == "" {
+			return objects, nil
+		}
+		marker = page.NextMarker
+	}
+}
+
+// do sends a request signed with the shared key for the blob (or the container when key is empty)
+func (b *AzureBucket) do(ctx context.Context, method, key string, query url.Values, body []byte, headers map[string]string) (*http.Response, error) {
+	target := *b.endpoint
+	target.Path = b.endpoint.Path + "/" + b.container
+	if key != "" {
+		target.Path += "/" + key
+	}
+	target.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build storage request: %w", err)
+	}
+	req.ContentLength = int64(len(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("x-ms-date", b.now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureStorageVersion)
+	req.Header.Set("Authorization", "SharedKey "+b.account+":"+b.sign(req, target.Path, query))
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("storage request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// sign computes the Shared Key signature of a Blob service request
+func (b *AzureBucket) sign(req *http.Request, path string, query url.Values) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var msHeaders []string
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower+":"+strings.TrimSpace(req.Header.Get(name)))
+		}
+	}
+	sort.Strings(msHeaders)
+
+	resource := "/" + b.account + path
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		resource += "\n" + strings.ToLower(name) + ":" + strings.Join(query[name], ",")
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, superseded by x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}, "\n") + "\n" + strings.Join(msHeaders, "\n") + "\n" + resource
+
+	return base64.StdEncoding.EncodeToString(hmacSHA256(b.accountKey, stringToSign))
+}
+
+func readAzureError(resp *http.Response) azureErrorResponse {
+	var apiErr azureErrorResponse
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	_ = xml.Unmarshal(body, &apiErr)
+	return apiErr
+}
+
+func (e azureErrorResponse) describe(status int) string {
+	if e.Code == "" {
+		return fmt.Sprintf("status %d", status)
+	}
+	return fmt.Sprintf("%s: %s (status %d)", e.Code, strings.SplitN(e.Message, "\n", 2)[0], status)
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// localTempPattern names partially written files; they are skipped when listing
+const localTempPattern = ".tmp-*"
+
+// LocalBucket keeps objects as files in a directory. Content types are not stored; they are
+// derived from the key's file extension.
+type LocalBucket struct {
+	dir string
+}
+
+// NewLocalBucket creates a bucket in the directory <LocalRoot>/<name>
+func NewLocalBucket(config Config, name string) (*LocalBucket, error) {
+	if config.LocalRoot == "" {
+		return nil, fmt.Errorf("local storage root is required")
+	}
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return nil, fmt.Errorf("invalid local bucket name: %q", name)
+	}
+	return &LocalBucket{dir: filepath.Join(config.LocalRoot, name)}, nil
+}
+
+func (b *LocalBucket) Create(ctx context.Context) error {
+	return os.MkdirAll(b.dir, 0o750)
+}
+
+func (b *LocalBucket) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	target, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(target), localTempPattern)
+	if err != nil {
+		return fmt.Errorf("failed to create object file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to store object: %w", err)
+	}
+	return nil
+}
+
+func (b *LocalBucket) Get(ctx context.Context, key string) (*Object, error) {
+	target, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+	return &Object{ObjectInfo: b.objectInfo(key, info), Data: data}, nil
+}
+
+func (b *LocalBucket) Delete(ctx context.Context, key string) error {
+	target, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+func (b *LocalBucket) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := filepath.WalkDir(b.dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if matched, _ := filepath.Match(localTempPattern, entry.Name()); matched {
+			return nil
+		}
+		rel, err := filepath.Rel(b.dir, file)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, b.objectInfo(key, info))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	return objects, nil
+}
+
+// path maps a key to a file inside the bucket directory, rejecting keys that would escape it
+func (b *LocalBucket) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean != "/"+key || strings.Contains(key, `\`) {
+		return "", fmt.Errorf("invalid object key: %q", key)
+	}
+	return filepath.Join(b.dir, filepath.FromSlash(clean)), nil
+}
+
+func (b *LocalBucket) objectInfo(key string, info fs.FileInfo) ObjectInfo {
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return ObjectInfo{
+		Key:         key,
+		ContentType: contentType,
+		Size:        info.Size(),
+		ModifiedAt:  info.ModTime().UTC(),
+	}
+}
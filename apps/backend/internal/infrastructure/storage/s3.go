@@ -0,0 +1,355 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
+)
+
+// s3RequestTimeout bounds a single object storage request
+const s3RequestTimeout = 60 * time.Second
+
+// S3Bucket talks to the S3 REST API with Signature Version 4. Google Cloud Storage is
+// reached through the same client, since its XML API accepts S3 requests signed with HMAC keys.
+type S3Bucket struct {
+	name            string
+	endpoint        *url.URL
+	pathStyle       bool
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	httpClient      *http.Client
+	now             func() time.Time
+}
+
+// NewS3Bucket creates a client for the named S3 bucket
+func NewS3Bucket(config Config, name string) (*S3Bucket, error) {
+	if config.S3Region == "" {
+		return nil, fmt.Errorf("S3 region is required")
+	}
+	if config.S3AccessKeyID == "" || config.S3SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 access key ID and secret access key are required")
+	}
+	endpoint := config.S3Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.S3Region)
+	}
+	return newS3Bucket(name, endpoint, config.S3ForcePathStyle, config.S3Region,
+		config.S3AccessKeyID, config.S3SecretAccessKey, config.S3SessionToken)
+}
+
+// NewGCSBucket creates a client for the named Google Cloud Storage bucket. The HMAC key
+// belongs to a service account with access to the bucket.
+func NewGCSBucket(config Config, name string) (*S3Bucket, error) {
+	if config.GCSHMACAccessID == "" || config.GCSHMACSecret == "" {
+		return nil, fmt.Errorf("GCS HMAC access ID and secret are required")
+	}
+	endpoint := config.GCSEndpoint
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+	return newS3Bucket(name, endpoint, true, "auto", config.GCSHMACAccessID, config.GCSHMACSecret, "")
+}
+
+func newS3Bucket(name, endpoint string, pathStyle bool, region, accessKeyID, secretAccessKey, sessionToken string) (*S3Bucket, error) {
+	if name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid bucket name: %q", name)
+	}
+	parsed, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid storage endpoint: %q", endpoint)
+	}
+	return &S3Bucket{
+		name:            name,
+		endpoint:        parsed,
+		pathStyle:       pathStyle,
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		sessionToken:    sessionToken,
+		httpClient:      resilience.WrapClient(&http.Client{Timeout: s3RequestTimeout}, resilience.DependencyObjectStorage),
+		now:             time.Now,
+	}, nil
+}
+
+// s3ErrorResponse is the error document returned by S3 and GCS
+type s3ErrorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// s3ListResponse is a page of ListObjectsV2
+type s3ListResponse struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+}
+
+func (b *S3Bucket) Create(ctx context.Context) error {
+	var body []byte
+	if b.region != "us-east-1" && b.region != "auto" {
+		body = []byte(`<CreateBucketConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><LocationConstraint>` +
+			b.region + `</LocationConstraint></CreateBucketConfiguration>`)
+	}
+	resp, err := b.do(ctx, http.MethodPut, "", nil, body, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	apiErr := readS3Error(resp)
+	if apiErr.Code == "BucketAlreadyOwnedByYou" {
+		return nil
+	}
+	return fmt.Errorf("failed to create bucket %s: %s", b.name, apiErr.describe(resp.StatusCode))
+}
+
+func (b *S3Bucket) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	headers := map[string]string{}
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+	resp, err := b.do(ctx, http.MethodPut, key, nil, data, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to store object: %s", readS3Error(resp).describe(resp.StatusCode))
+	}
+	return nil
+}
+
+func (b *S3Bucket) Get(ctx context.Context, key string) (*Object, error) {
+	resp, err := b.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read object: %s", readS3Error(resp).describe(resp.StatusCode))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+
+	object := &Object{
+		ObjectInfo: ObjectInfo{Key: key, ContentType: resp.Header.Get("Content-Type"), Size: int64(len(data))},
+		Data:       data,
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		object.ModifiedAt = modified.UTC()
+	}
+	return object, nil
+}
+
+func (b *S3Bucket) Delete(ctx context.Context, key string) error {
+	resp, err := b.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete object: %s", readS3Error(resp).describe(resp.StatusCode))
+	}
+	return nil
+}
+
+func (b *S3Bucket) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := b.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			apiErr := readS3Error(resp)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list objects: %s", apiErr.describe(resp.StatusCode))
+		}
+		var page s3ListResponse
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object listing: %w", err)
+		}
+
+		for _, content := range page.Contents {
+			objects = append(objects, ObjectInfo{Key: content.Key, Size: content.Size, ModifiedAt: content.LastModified.UTC()})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// do sends a signed request for the object key (or the bucket itself when key is empty)
+func (b *S3Bucket) do(ctx context.Context, method, key string, query url.Values, body []byte, headers map[string]string) (*http.Response, error) {
+	target := *b.endpoint
+	objectPath := "/" + key
+	if b.pathStyle {
+		objectPath = "/" + b.name
+		if key != "" {
+			objectPath += "/" + key
+		}
+	} else {
+		target.Host = b.name + "." + target.Host
+	}
+	target.Path = b.endpoint.Path + objectPath
+	target.RawPath = awsURIEncode(target.Path, false)
+	target.RawQuery = awsCanonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build storage request: %w", err)
+	}
+	req.ContentLength = int64(len(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	payloadHash := sha256.Sum256(body)
+	signAWSV4(req, hex.EncodeToString(payloadHash[:]), b.region, b.accessKeyID, b.secretAccessKey, b.sessionToken, b.now())
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("storage request failed: %w", err)
+	}
+	return resp, nil
+}
+
+func readS3Error(resp *http.Response) s3ErrorResponse {
+	var apiErr s3ErrorResponse
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	_ = xml.Unmarshal(body, &apiErr)
+	return apiErr
+}
+
+func (e s3ErrorResponse) describe(status int) string {
+	if e.Code == "" {
+		return fmt.Sprintf("status %d", status)
+	}
+	return fmt.Sprintf("%s: %s (status %d)", e.Code, e.Message, status)
+}
+
+// signAWSV4 adds the X-Amz-Date, X-Amz-Content-Sha256 and Authorization headers of an AWS
+// Signature Version 4 for the s3 service. Every header already on the request is signed.
+func signAWSV4(req *http.Request, payloadHash, region, accessKeyID, secretAccessKey, sessionToken string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsURIEncode(req.URL.Path, false),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+// awsCanonicalQuery encodes query parameters sorted by name, as Signature Version 4 requires
+func awsCanonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pairs []string
+	for _, name := range names {
+		for _, value := range query[name] {
+			pairs = append(pairs, awsURIEncode(name, true)+"="+awsURIEncode(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode percent-encodes everything except unreserved characters (and "/" unless encodeSlash)
+func awsURIEncode(value string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			encoded.WriteByte(c)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return encoded.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
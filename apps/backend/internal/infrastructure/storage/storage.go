@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ErrObjectNotFound is returned when an object does not exist in the bucket
+var ErrObjectNotFound = errors.New("object not found")
+
+// Bucket is a flat namespace of objects in one provider. Keys use "/" as separator.
+// This allows AIM to keep artifacts in S3, GCS, Azure Blob Storage or on the local disk.
+type Bucket interface {
+	// Create creates the bucket; creating a bucket that already exists is not an error
+	Create(ctx context.Context) error
+
+	// Put writes an object, replacing an existing one with the same key
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+
+	// Get reads an object; ErrObjectNotFound when it does not exist
+	Get(ctx context.Context, key string) (*Object, error)
+
+	// Delete removes an object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+
+	// List returns the objects whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key         string
+	ContentType string // Not reported by every provider when listing
+	Size        int64
+	ModifiedAt  time.Time
+}
+
+// Object is a stored object and its content
+type Object struct {
+	ObjectInfo
+	Data []byte
+}
+
+// ProviderType represents the type of object storage provider
+type ProviderType string
+
+const (
+	ProviderLocal ProviderType = "local" // Directory on the local filesystem (development and single-node installs)
+	ProviderS3    ProviderType = "s3"    // Amazon S3 or an S3-compatible service such as MinIO
+	ProviderGCS   ProviderType = "gcs"   // Google Cloud Storage through its XML API with HMAC keys
+	ProviderAzure ProviderType = "azure" // Azure Blob Storage with a shared account key
+)
+
+// Config holds configuration for object storage
+type Config struct {
+	Provider ProviderType
+
+	// Bucket (container or directory) holding the artifacts. With BucketPerOrganization every
+	// organization gets its own bucket named "<Bucket>-<organization ID>" instead.
+	Bucket                string
+	BucketPerOrganization bool
+	Prefix                string // Optional key prefix inside the bucket
+
+	// Retention per artifact kind; zero keeps artifacts of that kind until they are deleted
+	Retention map[domain.ArtifactKind]time.Duration
+
+	// Local filesystem
+	LocalRoot string
+
+	// Amazon S3
+	S3Region          string
+	S3Endpoint        string // Overrides the AWS endpoint, e.g. for MinIO
+	S3ForcePathStyle  bool   // Address buckets in the path instead of the host name
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3SessionToken    string
+
+	// Google Cloud Storage
+	GCSEndpoint     string
+	GCSHMACAccessID string
+	GCSHMACSecret   string
+
+	// Azure Blob Storage
+	AzureAccount    string
+	AzureAccountKey string // Base64 shared key of the storage account
+	AzureEndpoint   string // Overrides https://<account>.blob.core.windows.net, e.g. for Azurite
+}
+
+// NewBucket opens the named bucket of the configured provider
+func NewBucket(config Config, name string) (Bucket, error) {
+	switch config.Provider {
+	case ProviderLocal:
+		return NewLocalBucket(config, name)
+	case ProviderS3:
+		return NewS3Bucket(config, name)
+	case ProviderGCS:
+		return NewGCSBucket(config, name)
+	case ProviderAzure:
+		return NewAzureBucket(config, name)
+	default:
+		return nil, fmt.Errorf("unsupported storage provider: %s", config.Provider)
+	}
+}
+
+// LoadConfigFromEnv loads object storage configuration from environment variables
+func LoadConfigFromEnv() (Config, error) {
+	config := Config{
+		Provider:              ProviderType(getEnv("STORAGE_PROVIDER", string(ProviderLocal))),
+		Bucket:                getEnv("STORAGE_BUCKET", "aim-artifacts"),
+		BucketPerOrganization: getEnvAsBool("STORAGE_BUCKET_PER_ORGANIZATION", false),
+		Prefix:                strings.Trim(getEnv("STORAGE_PREFIX", ""), "/"),
+		Retention:             make(map[domain.ArtifactKind]time.Duration),
+	}
+
+	// STORAGE_RETENTION_EXPORTS, STORAGE_RETENTION_ARCHIVED_EVENTS, STORAGE_RETENTION_REPORTS, ...
+	for _, kind := range domain.ArtifactKinds {
+		key := "STORAGE_RETENTION_" + strings.ToUpper(strings.ReplaceAll(string(kind), "-", "_"))
+		if value := os.Getenv(key); value != "" {
+			retention, err := time.ParseDuration(value)
+			if err != nil || retention < 0 {
+				return config, fmt.Errorf("%s must be a non-negative duration", key)
+			}
+			config.Retention[kind] = retention
+		}
+	}
+
+	switch config.Provider {
+	case ProviderLocal:
+		config.LocalRoot = getEnv("STORAGE_LOCAL_ROOT", "./data/artifacts")
+
+	case ProviderS3:
+		config.S3Region = getEnv("STORAGE_S3_REGION", getEnv("AWS_REGION", "us-east-1"))
+		config.S3Endpoint = getEnv("STORAGE_S3_ENDPOINT", "")
+		config.S3ForcePathStyle = getEnvAsBool("STORAGE_S3_FORCE_PATH_STYLE", config.S3Endpoint != "")
+		config.S3AccessKeyID = getEnv("AWS_ACCESS_KEY_ID", "")
+		config.S3SecretAccessKey = getEnv("AWS_SECRET_ACCESS_KEY", "")
+		config.S3SessionToken = getEnv("AWS_SESSION_TOKEN", "")
+
+	case ProviderGCS:
+		config.GCSEndpoint = getEnv("STORAGE_GCS_ENDPOINT", "")
+		config.GCSHMACAccessID = getEnv("STORAGE_GCS_HMAC_ACCESS_ID", "")
+		config.GCSHMACSecret = getEnv("STORAGE_GCS_HMAC_SECRET", "")
+
+	case ProviderAzure:
+		config.AzureAccount = getEnv("STORAGE_AZURE_ACCOUNT", "")
+		config.AzureAccountKey = getEnv("STORAGE_AZURE_ACCOUNT_KEY", "")
+		config.AzureEndpoint = getEnv("STORAGE_AZURE_ENDPOINT", "")
+
+	default:
+		return config, fmt.Errorf("unsupported STORAGE_PROVIDER: %s (use 'local', 's3', 'gcs' or 'azure')", config.Provider)
+	}
+
+	return config, nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,316 @@
+package ticketing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
+)
+
+const (
+	ProviderJira       = "jira"
+	ProviderServiceNow = "servicenow"
+
+	// maxResponseBytes bounds the documents read from ticketing systems
+	maxResponseBytes = 1 << 20
+)
+
+// Config holds the credentials of the ticketing systems; a system without a base URL is disabled
+type Config struct {
+	JiraURL       string
+	JiraEmail     string
+	JiraAPIToken  string
+	JiraIssueType string // Defaults to "Task"
+
+	ServiceNowURL      string
+	ServiceNowUsername string
+	ServiceNowPassword string
+}
+
+// ConfigFromEnv reads the ticketing credentials from JIRA_* and SERVICENOW_* variables
+func ConfigFromEnv() Config {
+	return Config{
+		JiraURL:            os.Getenv("JIRA_URL"),
+		JiraEmail:          os.Getenv("JIRA_EMAIL"),
+		JiraAPIToken:       os.Getenv("JIRA_API_TOKEN"),
+		JiraIssueType:      os.Getenv("JIRA_ISSUE_TYPE"),
+		ServiceNowURL:      os.Getenv("SERVICENOW_URL"),
+		ServiceNowUsername: os.Getenv("SERVICENOW_USERNAME"),
+		ServiceNowPassword: os.Getenv("SERVICENOW_PASSWORD"),
+	}
+}
+
+// ForConnector returns a client for an organization's ticket connector and its API token
+func ForConnector(connector *domain.TicketConnector, apiToken string) *Client {
+	config := Config{}
+	switch connector.Provider {
+	case ProviderJira:
+		config.JiraURL = connector.BaseURL
+		config.JiraEmail = connector.Username
+		config.JiraAPIToken = apiToken
+		config.JiraIssueType = connector.IssueType
+	case ProviderServiceNow:
+		config.ServiceNowURL = connector.BaseURL
+		config.ServiceNowUsername = connector.Username
+		config.ServiceNowPassword = apiToken
+	}
+	return NewClient(config)
+}
+
+// Client opens tickets in Jira (REST API v2) and ServiceNow (Table API) and reads them back
+type Client struct {
+	config     Config
+	httpClient *http.Client
+}
+
+// NewClient creates a new ticketing client
+func NewClient(config Config) *Client {
+	if config.JiraIssueType == "" {
+		config.JiraIssueType = "Task"
+	}
+	config.JiraURL = strings.TrimRight(config.JiraURL, "/")
+	config.ServiceNowURL = strings.TrimRight(config.ServiceNowURL, "/")
+	return &Client{
+		config:     config,
+		httpClient: resilience.WrapClient(&http.Client{Timeout: 30 * time.Second}, resilience.DependencyTicketing),
+	}
+}
+
+// CreateTicket implements domain.TicketCreator
+func (c *Client) CreateTicket(ctx context.Context, ticket *domain.Ticket) (*domain.TicketReference, error) {
+	switch ticket.Provider {
+	case ProviderJira:
+		return c.createJiraIssue(ctx, ticket)
+	case ProviderServiceNow:
+		return c.createServiceNowIncident(ctx, ticket)
+	}
+	return nil, fmt.Errorf("unsupported ticket provider %q", ticket.Provider)
+}
+
+// GetTicket implements domain.TicketSystem
+func (c *Client) GetTicket(ctx context.Context, ticket *domain.TicketReference) (*domain.TicketState, error) {
+	switch ticket.Provider {
+	case ProviderJira:
+		return c.getJiraIssue(ctx, ticket)
+	case ProviderServiceNow:
+		return c.getServiceNowIncident(ctx, ticket)
+	}
+	return nil, fmt.Errorf("unsupported ticket provider %q", ticket.Provider)
+}
+
+func (c *Client) createJiraIssue(ctx context.Context, ticket *domain.Ticket) (*domain.TicketReference, error) {
+	if c.config.JiraURL == "" {
+		return nil, fmt.Errorf("jira is not configured (JIRA_URL)")
+	}
+	if ticket.Project == "" {
+		return nil, fmt.Errorf("jira project key is required")
+	}
+
+	fields := map[string]interface{}{
+		"project":     map[string]string{"key": ticket.Project},
+		"issuetype":   map[string]string{"name": c.config.JiraIssueType},
+		"summary":     ticket.Summary,
+		"description": ticket.Description,
+	}
+	if len(ticket.Labels) > 0 {
+		fields["labels"] = ticket.Labels
+	}
+
+	var response struct {
+		Key string `json:"key"`
+	}
+	err := c.do(ctx, ProviderJira, http.MethodPost, c.config.JiraURL+"/rest/api/2/issue", c.config.JiraEmail, c.config.JiraAPIToken,
+		map[string]interface{}{"fields": fields}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TicketReference{
+		Provider: ProviderJira,
+		Key:      response.Key,
+		URL:      c.config.JiraURL + "/browse/" + response.Key,
+	}, nil
+}
+
+func (c *Client) createServiceNowIncident(ctx context.Context, ticket *domain.Ticket) (*domain.TicketReference, error) {
+	if c.config.ServiceNowURL == "" {
+		return nil, fmt.Errorf("servicenow is not configured (SERVICENOW_URL)")
+	}
+
+	// ServiceNow urgency and impact: 1 = high, 2 = medium, 3 = low
+	level := "3"
+	switch ticket.Severity {
+	case domain.AlertSeverityCritical:
+		level = "1"
+	case domain.AlertSeverityHigh:
+		level = "2"
+	}
+	record := map[string]string{
+		"short_description": ticket.Summary,
+		"description":       ticket.Description,
+		"urgency":           level,
+		"impact":            level,
+	}
+	if ticket.Project != "" {
+		record["assignment_group"] = ticket.Project
+	}
+
+	var response struct {
+		Result struct {
+			SysID  string `json:"sys_id"`
+			Number string `json:"number"`
+		} `json:"result"`
+	}
+	err := c.do(ctx, ProviderServiceNow, http.MethodPost, c.config.ServiceNowURL+"/api/now/table/incident", c.config.ServiceNowUsername, c.config.ServiceNowPassword,
+		record, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TicketReference{
+		Provider: ProviderServiceNow,
+		Key:      response.Result.Number,
+		ID:       response.Result.SysID,
+		URL:      c.config.ServiceNowURL + "/nav_to.do?uri=" + url.QueryEscape("incident.do?sys_id="+response.Result.SysID),
+	}, nil
+}
+
+func (c *Client) getJiraIssue(ctx context.Context, ticket *domain.TicketReference) (*domain.TicketState, error) {
+	if c.config.JiraURL == "" {
+		return nil, fmt.Errorf("jira is not configured (JIRA_URL)")
+	}
+
+	var response struct {
+		Fields struct {
+			Status struct {
+				Name string `json:"name"`
+			} `json:"status"`
+			Comment struct {
+				Comments []struct {
+					ID     string `json:"id"`
+					Author struct {
+						DisplayName string `json:"displayName"`
+					} `json:"author"`
+					Body    string `json:"body"`
+					Created string `json:"created"`
+				} `json:"comments"`
+			} `json:"comment"`
+		} `json:"fields"`
+	}
+	endpoint := c.config.JiraURL + "/rest/api/2/issue/" + url.PathEscape(ticket.Key) + "?fields=status,comment"
+	if err := c.do(ctx, ProviderJira, http.MethodGet, endpoint, c.config.JiraEmail, c.config.JiraAPIToken, nil, &response); err != nil {
+		return nil, err
+	}
+
+	state := &domain.TicketState{Status: response.Fields.Status.Name, Comments: []domain.TicketComment{}}
+	for _, comment := range response.Fields.Comment.Comments {
+		created, _ := time.Parse("2006-01-02T15:04:05.000-0700", comment.Created)
+		state.Comments = append(state.Comments, domain.TicketComment{
+			ID:        comment.ID,
+			Author:    comment.Author.DisplayName,
+			Body:      comment.Body,
+			CreatedAt: created,
+		})
+	}
+	return state, nil
+}
+
+func (c *Client) getServiceNowIncident(ctx context.Context, ticket *domain.TicketReference) (*domain.TicketState, error) {
+	if c.config.ServiceNowURL == "" {
+		return nil, fmt.Errorf("servicenow is not configured (SERVICENOW_URL)")
+	}
+	if ticket.ID == "" {
+		return nil, fmt.Errorf("servicenow incident %s has no sys_id", ticket.Key)
+	}
+
+	var incident struct {
+		Result struct {
+			State string `json:"state"`
+		} `json:"result"`
+	}
+	endpoint := c.config.ServiceNowURL + "/api/now/table/incident/" + url.PathEscape(ticket.ID) + "?sysparm_fields=state&sysparm_display_value=true"
+	if err := c.do(ctx, ProviderServiceNow, http.MethodGet, endpoint, c.config.ServiceNowUsername, c.config.ServiceNowPassword, nil, &incident); err != nil {
+		return nil, err
+	}
+
+	// Comments are journal entries of the incident's "comments" field
+	var journal struct {
+		Result []struct {
+			SysID     string `json:"sys_id"`
+			Value     string `json:"value"`
+			CreatedBy string `json:"sys_created_by"`
+			CreatedOn string `json:"sys_created_on"`
+		} `json:"result"`
+	}
+	query := url.Values{
+		"sysparm_query":  {"element_id=" + ticket.ID + "^element=comments^ORDERBYsys_created_on"},
+		"sysparm_fields": {"sys_id,value,sys_created_by,sys_created_on"},
+	}
+	endpoint = c.config.ServiceNowURL + "/api/now/table/sys_journal_field?" + query.Encode()
+	if err := c.do(ctx, ProviderServiceNow, http.MethodGet, endpoint, c.config.ServiceNowUsername, c.config.ServiceNowPassword, nil, &journal); err != nil {
+		return nil, err
+	}
+
+	state := &domain.TicketState{Status: incident.Result.State, Comments: []domain.TicketComment{}}
+	for _, entry := range journal.Result {
+		created, _ := time.Parse(time.DateTime, entry.CreatedOn)
+		state.Comments = append(state.Comments, domain.TicketComment{
+			ID:        entry.SysID,
+			Author:    entry.CreatedBy,
+			Body:      entry.Value,
+			CreatedAt: created,
+		})
+	}
+	return state, nil
+}
+
+// do sends a JSON document (none when body is nil) with basic authentication and decodes the
+// JSON response
+func (c *Client) do(ctx context.Context, provider, method, endpoint, username, password string, body, out interface{}) error {
+	var payload io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, payload)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "AIM-Playbooks/1.0")
+	req.SetBasicAuth(username, password)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", provider, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid %s response: %w", provider, err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// propagator reads and writes the W3C traceparent and tracestate headers
+var propagator = propagation.TraceContext{}
+
+// Extract makes the trace of an incoming request, read from its traceparent and tracestate
+// headers with header, the parent of the next span started. Missing or malformed headers are
+// ignored.
+func Extract(ctx context.Context, header func(key string) string) context.Context {
+	carrier := propagation.MapCarrier{}
+	for _, key := range propagator.Fields() {
+		if value := header(key); value != "" {
+			carrier[key] = value
+		}
+	}
+	return propagator.Extract(ctx, carrier)
+}
+
+// Inject sets the traceparent and tracestate headers of an outgoing request to continue the
+// context's trace; it sets nothing when there is no trace
+func Inject(ctx context.Context, set func(key, value string)) {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	for key, value := range carrier {
+		set(key, value)
+	}
+}
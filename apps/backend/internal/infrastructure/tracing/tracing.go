@@ -0,0 +1,162 @@
+// Package tracing records OpenTelemetry spans and exports them to an OTLP/HTTP collector. Trace
+// context arrives and leaves in W3C traceparent headers, so spans join the traces of SDK callers.
+package tracing
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const instrumentationScope = "github.com/opena2a/identity/backend"
+
+// Span kinds of the spans the backend starts
+const (
+	SpanKindInternal = trace.SpanKindInternal
+	SpanKindServer   = trace.SpanKindServer // An incoming request
+	SpanKindClient   = trace.SpanKindClient // A call to the database or another service
+)
+
+// Span statuses
+const (
+	StatusUnset = codes.Unset
+	StatusOK    = codes.Ok
+	StatusError = codes.Error
+)
+
+// Attribute is a key/value pair describing a span
+type Attribute = attribute.KeyValue
+
+func String(key, value string) Attribute        { return attribute.String(key, value) }
+func Int(key string, value int) Attribute       { return attribute.Int(key, value) }
+func Float(key string, value float64) Attribute { return attribute.Float64(key, value) }
+func Bool(key string, value bool) Attribute     { return attribute.Bool(key, value) }
+
+// Span is an operation of a trace. When tracing is turned off it is a no-op span, so callers
+// never check for it.
+type Span struct {
+	trace.Span
+}
+
+// RecordError records the error as an exception event and marks the span failed; a nil error is
+// ignored
+func (s Span) RecordError(err error, options ...trace.EventOption) {
+	if err == nil {
+		return
+	}
+	s.Span.RecordError(err, options...)
+	s.Span.SetStatus(codes.Error, err.Error())
+}
+
+// Config addresses the OTLP collector and sets how many traces are recorded
+type Config struct {
+	Endpoint    string            // Collector base URL, e.g. http://otel-collector:4318; empty turns tracing off
+	Headers     map[string]string // Sent with every export, e.g. an API key of a hosted backend
+	ServiceName string
+	SampleRatio float64 // Share of new traces recorded, 0 to 1
+}
+
+// Tracer starts spans and batches the sampled ones to its exporter
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+// New creates the tracer exporting to the configured collector, or returns nil when no endpoint
+// is configured
+func New(config Config) (*Tracer, error) {
+	if config.Endpoint == "" {
+		return nil, nil
+	}
+	exporter, err := NewExporter(context.Background(), config.Endpoint, config.Headers)
+	if err != nil {
+		return nil, err
+	}
+	return NewTracer(config, exporter)
+}
+
+// NewTracer creates a tracer batching spans to the exporter and sampling the configured ratio of
+// new traces. Traces continued from a caller follow the caller's sampling decision.
+func NewTracer(config Config, exporter sdktrace.SpanExporter) (*Tracer, error) {
+	res, err := newResource(context.Background(), config.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(options...)
+	return &Tracer{provider: provider, tracer: provider.Tracer(instrumentationScope)}, nil
+}
+
+// Shutdown exports the spans still queued; the tracer must not be used afterwards
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	return t.provider.Shutdown(ctx)
+}
+
+// current is the tracer Start uses; nil turns tracing off
+var current atomic.Pointer[Tracer]
+
+// disabled starts the spans of Start while tracing is off. They carry the caller's span context,
+// so it still propagates.
+var disabled = noop.NewTracerProvider().Tracer(instrumentationScope)
+
+// SetTracer makes the tracer the one the package functions and the global OpenTelemetry provider
+// use; nil turns tracing off
+func SetTracer(tracer *Tracer) {
+	current.Store(tracer)
+	otel.SetTextMapPropagator(propagator)
+	if tracer != nil {
+		otel.SetTracerProvider(tracer.provider)
+	} else {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	}
+}
+
+// Enabled reports whether spans are being recorded
+func Enabled() bool {
+	return current.Load() != nil
+}
+
+// SpanFromContext returns the current span, a no-op span when there is none
+func SpanFromContext(ctx context.Context) Span {
+	return Span{trace.SpanFromContext(ctx)}
+}
+
+// Start starts an internal span as a child of the context's span. The span must be ended.
+func Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span) {
+	return StartSpan(ctx, name, SpanKindInternal, attributes...)
+}
+
+// StartSpan starts a span of the given kind as a child of the context's span
+func StartSpan(ctx context.Context, name string, kind trace.SpanKind, attributes ...Attribute) (context.Context, Span) {
+	tracer := disabled
+	if t := current.Load(); t != nil {
+		tracer = t.tracer
+	}
+	ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attributes...))
+	return ctx, Span{span}
+}
+
+// StartRepository starts a client span around a database call, named after the repository
+// method, e.g. "VerificationEventRepository.Create"
+func StartRepository(ctx context.Context, repository, method string) (context.Context, Span) {
+	return StartSpan(ctx, repository+"."+method, SpanKindClient,
+		String("db.system", "postgresql"),
+		String("db.operation", method),
+		String("code.namespace", repository),
+	)
+}
@@ -0,0 +1,195 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// headers returns the header lookup Extract reads an incoming request's trace context with
+func headers(traceparent, tracestate string) func(string) string {
+	return func(key string) string {
+		return map[string]string{"traceparent": traceparent, "tracestate": tracestate}[key]
+	}
+}
+
+func TestExtractReadsTraceparent(t *testing.T) {
+	ctx := Extract(context.Background(), headers("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "vendor=abc"))
+	sc := trace.SpanContextFromContext(ctx)
+	require.True(t, sc.IsValid())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID().String())
+	assert.True(t, sc.IsSampled())
+	assert.True(t, sc.IsRemote())
+	assert.Equal(t, "vendor=abc", sc.TraceState().String())
+
+	var injected = map[string]string{}
+	Inject(ctx, func(key, value string) { injected[key] = value })
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", injected["traceparent"])
+	assert.Equal(t, "vendor=abc", injected["tracestate"])
+
+	ctx = Extract(context.Background(), headers("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", ""))
+	assert.False(t, trace.SpanContextFromContext(ctx).IsSampled())
+
+	for _, invalid := range []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01", // Zero trace ID
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", // Zero span ID
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", // Uppercase
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", // Forbidden version
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+	} {
+		ctx := Extract(context.Background(), headers(invalid, ""))
+		assert.False(t, trace.SpanContextFromContext(ctx).IsValid(), invalid)
+	}
+}
+
+func TestSpansContinueTheCallersTrace(t *testing.T) {
+	defer SetTracer(nil)
+
+	// Without a tracer spans record nothing, and every call on them is ignored
+	SetTracer(nil)
+	ctx, span := Start(context.Background(), "disabled")
+	assert.False(t, span.IsRecording())
+	span.SetAttributes(String("key", "value"))
+	span.RecordError(errors.New("ignored"))
+	span.End()
+	assert.False(t, trace.SpanContextFromContext(ctx).IsValid())
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracer, err := NewTracer(Config{ServiceName: "aim-test", SampleRatio: 0}, exporter)
+	require.NoError(t, err)
+	SetTracer(tracer)
+	remote := Extract(context.Background(), headers("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ""))
+	ctx, server := StartSpan(remote, "POST /verifications", SpanKindServer)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.True(t, server.SpanContext().IsSampled(), "the caller's sampling decision wins over the ratio")
+
+	_, child := StartRepository(ctx, "AlertRepository", "Create")
+	child.RecordError(nil)
+	child.End()
+	server.End()
+
+	var injected = map[string]string{}
+	Inject(ctx, func(key, value string) { injected[key] = value })
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+server.SpanContext().SpanID().String()+"-01", injected["traceparent"])
+
+	// New traces follow the sample ratio
+	_, root := Start(context.Background(), "job")
+	assert.False(t, root.SpanContext().IsSampled())
+	root.End()
+
+	require.NoError(t, tracer.provider.ForceFlush(context.Background())) // Shutting down would reset the in-memory exporter
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "AlertRepository.Create", spans[0].Name)
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind)
+	assert.Equal(t, server.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	assert.Equal(t, StatusUnset, spans[0].Status.Code, "nil errors are not recorded")
+	assert.Equal(t, "00f067aa0ba902b7", spans[1].Parent.SpanID().String())
+	assert.True(t, spans[1].Parent.IsRemote())
+
+	tracer, err = NewTracer(Config{SampleRatio: 1}, nil)
+	require.NoError(t, err)
+	SetTracer(tracer)
+	_, root = Start(context.Background(), "job")
+	assert.True(t, root.SpanContext().IsSampled())
+}
+
+func TestExporterPostsOTLP(t *testing.T) {
+	var mu sync.Mutex
+	var requests []*coltracepb.ExportTraceServiceRequest
+	var apiKeys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var request coltracepb.ExportTraceServiceRequest
+		require.NoError(t, proto.Unmarshal(body, &request))
+		mu.Lock()
+		requests = append(requests, &request)
+		apiKeys = append(apiKeys, r.Header.Get("X-Api-Key"))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	tracer, err := New(Config{Endpoint: server.URL + "/", Headers: map[string]string{"X-Api-Key": "secret"}, ServiceName: "aim-test", SampleRatio: 1})
+	require.NoError(t, err)
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	ctx, parent := Start(context.Background(), "VerificationEventService.CreateVerificationEvent", Int("attempt", 2))
+	_, child := StartRepository(ctx, "VerificationEventRepository", "Create")
+	child.RecordError(errors.New("connection refused"))
+	child.End()
+	parent.SetAttributes(Bool("aim.drift_detected", true), Float("aim.confidence", 0.9))
+	parent.End()
+	parent.End() // Ending twice exports once
+
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, tracer.Shutdown(shutdown))
+
+	require.Len(t, requests, 1)
+	assert.Equal(t, []string{"secret"}, apiKeys)
+	resourceSpans := requests[0].ResourceSpans[0]
+	assert.Contains(t, attributes(resourceSpans.Resource.Attributes), "service.name=aim-test")
+	spans := resourceSpans.ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+
+	exportedChild, exportedParent := spans[0], spans[1]
+	assert.Equal(t, "VerificationEventRepository.Create", exportedChild.Name)
+	assert.Equal(t, tracepb.Span_SPAN_KIND_CLIENT, exportedChild.Kind)
+	assert.Equal(t, exportedParent.SpanId, exportedChild.ParentSpanId)
+	assert.Equal(t, exportedParent.TraceId, exportedChild.TraceId)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, exportedChild.Status.Code)
+	assert.Equal(t, "connection refused", exportedChild.Status.Message)
+	assert.Len(t, exportedChild.Events, 1)
+
+	assert.Empty(t, exportedParent.ParentSpanId)
+	assert.Contains(t, attributes(exportedParent.Attributes), "attempt=2")
+	assert.Contains(t, attributes(exportedParent.Attributes), "aim.drift_detected=true")
+
+	_, err = New(Config{Endpoint: "otel-collector:4318"})
+	assert.Error(t, err)
+	tracer, err = New(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, tracer)
+}
+
+// attributes formats exported attributes as key=value
+func attributes(kvs []*commonpb.KeyValue) []string {
+	var formatted []string
+	for _, kv := range kvs {
+		var value string
+		switch v := kv.Value.Value.(type) {
+		case *commonpb.AnyValue_StringValue:
+			value = v.StringValue
+		case *commonpb.AnyValue_IntValue:
+			value = fmt.Sprint(v.IntValue)
+		case *commonpb.AnyValue_BoolValue:
+			value = fmt.Sprint(v.BoolValue)
+		case *commonpb.AnyValue_DoubleValue:
+			value = fmt.Sprint(v.DoubleValue)
+		}
+		formatted = append(formatted, kv.Key+"="+value)
+	}
+	return formatted
+}
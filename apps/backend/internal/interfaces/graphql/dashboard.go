@@ -0,0 +1,414 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	graphqlgo "github.com/graphql-go/graphql"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// Page sizes of the list fields taking limit and offset arguments
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+// Complexity of the fields making a query per parent; batched and scalar fields cost one
+const perParentQueryCost = 10
+
+// dashboardCosts are the complexity of the fields making a query per parent and the sizes
+// list fields are assumed to return
+var dashboardCosts = map[string]FieldCost{
+	"Query.agents":           {Cost: perParentQueryCost, ListSize: defaultPageSize},
+	"Query.agent":            {Cost: perParentQueryCost},
+	"Query.mcpServers":       {Cost: perParentQueryCost, ListSize: defaultPageSize},
+	"Query.mcpServer":        {Cost: perParentQueryCost},
+	"Query.alerts":           {Cost: perParentQueryCost, ListSize: defaultPageSize},
+	"Agent.tags":             {ListSize: 5},
+	"Agent.alerts":           {Cost: perParentQueryCost, ListSize: 10},
+	"Agent.mcpServers":       {Cost: perParentQueryCost, ListSize: 10},
+	"MCPServer.tags":         {ListSize: 5},
+	"MCPServer.attestations": {Cost: perParentQueryCost, ListSize: 10},
+}
+
+// Dashboard is the GraphQL API the admin dashboard queries agents, MCP servers, trust scores,
+// tags, alerts and attestations with
+type Dashboard struct {
+	schema       *Schema
+	agents       *application.AgentService
+	mcpServers   *application.MCPService
+	alerts       *application.AlertService
+	trust        *application.TrustCalculator
+	tags         *application.TagService
+	attestations *application.MCPAttestationService
+	health       *application.MCPHealthService // Optional: uptime of MCP servers
+}
+
+// NewDashboard creates the dashboard API; queries deeper or more complex than the limits are
+// rejected before anything is resolved
+func NewDashboard(
+	agents *application.AgentService,
+	mcpServers *application.MCPService,
+	alerts *application.AlertService,
+	trust *application.TrustCalculator,
+	tags *application.TagService,
+	attestations *application.MCPAttestationService,
+	health *application.MCPHealthService,
+	maxDepth int,
+	maxComplexity int,
+) *Dashboard {
+	d := &Dashboard{
+		agents:       agents,
+		mcpServers:   mcpServers,
+		alerts:       alerts,
+		trust:        trust,
+		tags:         tags,
+		attestations: attestations,
+		health:       health,
+	}
+	schema, err := NewSchema(d.queryType(), dashboardCosts, maxDepth, maxComplexity)
+	if err != nil {
+		panic(fmt.Sprintf("invalid dashboard GraphQL schema: %v", err))
+	}
+	d.schema = schema
+	return d
+}
+
+// Execute runs a query for an organization. Resolvers only return the organization's records:
+// agents and MCP servers of other organizations resolve to null.
+func (d *Dashboard) Execute(ctx context.Context, orgID uuid.UUID, req *Request) *Response {
+	state := &requestState{
+		orgID:       orgID,
+		trustScores: NewLoader(d.trust.GetLatestTrustScores),
+		agentTags:   NewLoader(d.tags.GetTagsForAgents),
+		serverTags:  NewLoader(d.tags.GetTagsForMCPServers),
+	}
+	if d.health != nil {
+		state.serverUptimes = NewLoader(d.health.GetUptimes)
+	}
+	return d.schema.Execute(context.WithValue(ctx, requestStateKey{}, state), req)
+}
+
+type requestStateKey struct{}
+
+// requestState is the organization a query runs for and its loaders, which batch and cache
+// lookups for the duration of the query
+type requestState struct {
+	orgID       uuid.UUID
+	trustScores *Loader[uuid.UUID, *domain.TrustScore]
+	agentTags   *Loader[uuid.UUID, []*domain.Tag]
+	serverTags  *Loader[uuid.UUID, []*domain.Tag]
+	// nil without the health service
+	serverUptimes *Loader[uuid.UUID, *domain.MCPServerUptime]
+}
+
+func stateFrom(ctx context.Context) *requestState {
+	return ctx.Value(requestStateKey{}).(*requestState)
+}
+
+func (d *Dashboard) queryType() *graphqlgo.Object {
+	tag := graphqlgo.NewObject(graphqlgo.ObjectConfig{Name: "Tag", Fields: graphqlgo.Fields{
+		"id":          scalar(graphqlgo.ID, func(t *domain.Tag) interface{} { return t.ID }),
+		"key":         scalar(graphqlgo.String, func(t *domain.Tag) interface{} { return t.Key }),
+		"value":       scalar(graphqlgo.String, func(t *domain.Tag) interface{} { return t.Value }),
+		"category":    scalar(graphqlgo.String, func(t *domain.Tag) interface{} { return t.Category }),
+		"description": scalar(graphqlgo.String, func(t *domain.Tag) interface{} { return t.Description }),
+		"color":       scalar(graphqlgo.String, func(t *domain.Tag) interface{} { return t.Color }),
+	}})
+
+	factors := graphqlgo.NewObject(graphqlgo.ObjectConfig{Name: "TrustScoreFactors", Fields: graphqlgo.Fields{
+		"verificationStatus": scalar(graphqlgo.Float, func(f *domain.TrustScoreFactors) interface{} { return f.VerificationStatus }),
+		"uptime":             scalar(graphqlgo.Float, func(f *domain.TrustScoreFactors) interface{} { return f.Uptime }),
+		"successRate":        scalar(graphqlgo.Float, func(f *domain.TrustScoreFactors) interface{} { return f.SuccessRate }),
+		"securityAlerts":     scalar(graphqlgo.Float, func(f *domain.TrustScoreFactors) interface{} { return f.SecurityAlerts }),
+		"compliance":         scalar(graphqlgo.Float, func(f *domain.TrustScoreFactors) interface{} { return f.Compliance }),
+		"age":                scalar(graphqlgo.Float, func(f *domain.TrustScoreFactors) interface{} { return f.Age }),
+		"driftDetection":     scalar(graphqlgo.Float, func(f *domain.TrustScoreFactors) interface{} { return f.DriftDetection }),
+		"userFeedback":       scalar(graphqlgo.Float, func(f *domain.TrustScoreFactors) interface{} { return f.UserFeedback }),
+	}})
+
+	trustScore := graphqlgo.NewObject(graphqlgo.ObjectConfig{Name: "TrustScore", Fields: graphqlgo.Fields{
+		"score":          scalar(graphqlgo.Float, func(s *domain.TrustScore) interface{} { return s.Score }),
+		"confidence":     scalar(graphqlgo.Float, func(s *domain.TrustScore) interface{} { return s.Confidence }),
+		"lastCalculated": scalar(graphqlgo.DateTime, func(s *domain.TrustScore) interface{} { return s.LastCalculated }),
+		"factors":        scalar(factors, func(s *domain.TrustScore) interface{} { return &s.Factors }),
+	}})
+
+	alert := graphqlgo.NewObject(graphqlgo.ObjectConfig{Name: "Alert", Fields: graphqlgo.Fields{
+		"id":             scalar(graphqlgo.ID, func(a *domain.Alert) interface{} { return a.ID }),
+		"alertType":      scalar(graphqlgo.String, func(a *domain.Alert) interface{} { return a.AlertType }),
+		"severity":       scalar(graphqlgo.String, func(a *domain.Alert) interface{} { return a.Severity }),
+		"title":          scalar(graphqlgo.String, func(a *domain.Alert) interface{} { return a.Title }),
+		"description":    scalar(graphqlgo.String, func(a *domain.Alert) interface{} { return a.Description }),
+		"resourceType":   scalar(graphqlgo.String, func(a *domain.Alert) interface{} { return a.ResourceType }),
+		"resourceId":     scalar(graphqlgo.ID, func(a *domain.Alert) interface{} { return a.ResourceID }),
+		"isAcknowledged": scalar(graphqlgo.Boolean, func(a *domain.Alert) interface{} { return a.IsAcknowledged }),
+		"acknowledgedAt": scalar(graphqlgo.DateTime, func(a *domain.Alert) interface{} { return a.AcknowledgedAt }),
+		"createdAt":      scalar(graphqlgo.DateTime, func(a *domain.Alert) interface{} { return a.CreatedAt }),
+	}})
+
+	attestation := graphqlgo.NewObject(graphqlgo.ObjectConfig{Name: "Attestation", Fields: graphqlgo.Fields{
+		"id":                    scalar(graphqlgo.ID, func(a *domain.AttestationWithAgentDetails) interface{} { return a.ID }),
+		"agentId":               scalar(graphqlgo.ID, func(a *domain.AttestationWithAgentDetails) interface{} { return a.AgentID }),
+		"agentName":             scalar(graphqlgo.String, func(a *domain.AttestationWithAgentDetails) interface{} { return a.AgentName }),
+		"agentTrustScore":       scalar(graphqlgo.Float, func(a *domain.AttestationWithAgentDetails) interface{} { return a.AgentTrustScore }),
+		"attestationType":       scalar(graphqlgo.String, func(a *domain.AttestationWithAgentDetails) interface{} { return a.AttestationType }),
+		"attestedBy":            scalar(graphqlgo.String, func(a *domain.AttestationWithAgentDetails) interface{} { return a.AttestedBy }),
+		"signatureVerified":     scalar(graphqlgo.Boolean, func(a *domain.AttestationWithAgentDetails) interface{} { return a.SignatureVerified }),
+		"capabilitiesConfirmed": scalar(graphqlgo.NewList(graphqlgo.String), func(a *domain.AttestationWithAgentDetails) interface{} { return a.CapabilitiesConfirmed }),
+		"healthCheckPassed":     scalar(graphqlgo.Boolean, func(a *domain.AttestationWithAgentDetails) interface{} { return a.HealthCheckPassed }),
+		"isValid":               scalar(graphqlgo.Boolean, func(a *domain.AttestationWithAgentDetails) interface{} { return a.IsValid }),
+		"verifiedAt":            scalar(graphqlgo.String, func(a *domain.AttestationWithAgentDetails) interface{} { return a.VerifiedAt }),
+		"expiresAt":             scalar(graphqlgo.String, func(a *domain.AttestationWithAgentDetails) interface{} { return a.ExpiresAt }),
+	}})
+
+	uptime := graphqlgo.NewObject(graphqlgo.ObjectConfig{Name: "MCPServerUptime", Fields: graphqlgo.Fields{
+		"last24h": scalar(graphqlgo.Float, func(u *domain.MCPServerUptime) interface{} { return u.Last24h }),
+		"last7d":  scalar(graphqlgo.Float, func(u *domain.MCPServerUptime) interface{} { return u.Last7d }),
+		"last30d": scalar(graphqlgo.Float, func(u *domain.MCPServerUptime) interface{} { return u.Last30d }),
+	}})
+
+	mcpServer := graphqlgo.NewObject(graphqlgo.ObjectConfig{Name: "MCPServer", Fields: graphqlgo.Fields{
+		"id":                 scalar(graphqlgo.ID, func(s *domain.MCPServer) interface{} { return s.ID }),
+		"name":               scalar(graphqlgo.String, func(s *domain.MCPServer) interface{} { return s.Name }),
+		"description":        scalar(graphqlgo.String, func(s *domain.MCPServer) interface{} { return s.Description }),
+		"url":                scalar(graphqlgo.String, func(s *domain.MCPServer) interface{} { return s.URL }),
+		"version":            scalar(graphqlgo.String, func(s *domain.MCPServer) interface{} { return s.Version }),
+		"status":             scalar(graphqlgo.String, func(s *domain.MCPServer) interface{} { return s.Status }),
+		"isVerified":         scalar(graphqlgo.Boolean, func(s *domain.MCPServer) interface{} { return s.IsVerified }),
+		"trustScore":         scalar(graphqlgo.Float, func(s *domain.MCPServer) interface{} { return s.TrustScore }),
+		"capabilities":       scalar(graphqlgo.NewList(graphqlgo.String), func(s *domain.MCPServer) interface{} { return s.Capabilities }),
+		"criticality":        scalar(graphqlgo.String, func(s *domain.MCPServer) interface{} { return s.Criticality }),
+		"verificationMethod": scalar(graphqlgo.String, func(s *domain.MCPServer) interface{} { return s.VerificationMethod }),
+		"attestationCount":   scalar(graphqlgo.Int, func(s *domain.MCPServer) interface{} { return s.AttestationCount }),
+		"confidenceScore":    scalar(graphqlgo.Float, func(s *domain.MCPServer) interface{} { return s.ConfidenceScore }),
+		"lastAttestedAt":     scalar(graphqlgo.DateTime, func(s *domain.MCPServer) interface{} { return s.LastAttestedAt }),
+		"lastVerifiedAt":     scalar(graphqlgo.DateTime, func(s *domain.MCPServer) interface{} { return s.LastVerifiedAt }),
+		"createdAt":          scalar(graphqlgo.DateTime, func(s *domain.MCPServer) interface{} { return s.CreatedAt }),
+		"tags":               {Type: graphqlgo.NewList(tag), Resolve: d.mcpServerTags},
+		"uptime":             {Type: uptime, Resolve: d.mcpServerUptime},
+		"attestations":       {Type: graphqlgo.NewList(attestation), Resolve: d.mcpServerAttestations},
+	}})
+
+	agent := graphqlgo.NewObject(graphqlgo.ObjectConfig{Name: "Agent", Fields: graphqlgo.Fields{
+		"id":               scalar(graphqlgo.ID, func(a *domain.Agent) interface{} { return a.ID }),
+		"name":             scalar(graphqlgo.String, func(a *domain.Agent) interface{} { return a.Name }),
+		"displayName":      scalar(graphqlgo.String, func(a *domain.Agent) interface{} { return a.DisplayName }),
+		"description":      scalar(graphqlgo.String, func(a *domain.Agent) interface{} { return a.Description }),
+		"agentType":        scalar(graphqlgo.String, func(a *domain.Agent) interface{} { return a.AgentType }),
+		"status":           scalar(graphqlgo.String, func(a *domain.Agent) interface{} { return a.Status }),
+		"version":          scalar(graphqlgo.String, func(a *domain.Agent) interface{} { return a.Version }),
+		"trustScore":       scalar(graphqlgo.Float, func(a *domain.Agent) interface{} { return a.TrustScore }),
+		"isCompromised":    scalar(graphqlgo.Boolean, func(a *domain.Agent) interface{} { return a.IsCompromised }),
+		"capabilities":     scalar(graphqlgo.NewList(graphqlgo.String), func(a *domain.Agent) interface{} { return a.Capabilities }),
+		"talksTo":          scalar(graphqlgo.NewList(graphqlgo.String), func(a *domain.Agent) interface{} { return a.TalksTo }),
+		"verifiedAt":       scalar(graphqlgo.DateTime, func(a *domain.Agent) interface{} { return a.VerifiedAt }),
+		"lastActive":       scalar(graphqlgo.DateTime, func(a *domain.Agent) interface{} { return a.LastActive }),
+		"createdAt":        scalar(graphqlgo.DateTime, func(a *domain.Agent) interface{} { return a.CreatedAt }),
+		"updatedAt":        scalar(graphqlgo.DateTime, func(a *domain.Agent) interface{} { return a.UpdatedAt }),
+		"latestTrustScore": {Type: trustScore, Resolve: d.agentTrustScore},
+		"tags":             {Type: graphqlgo.NewList(tag), Resolve: d.agentTags},
+		"alerts": {Type: graphqlgo.NewList(alert), Resolve: d.agentAlerts, Args: graphqlgo.FieldConfigArgument{
+			"limit": {Type: graphqlgo.Int, DefaultValue: 10},
+		}},
+		"mcpServers": {Type: graphqlgo.NewList(mcpServer), Resolve: d.agentMCPServers},
+	}})
+
+	return graphqlgo.NewObject(graphqlgo.ObjectConfig{Name: "Query", Fields: graphqlgo.Fields{
+		"agents":     {Type: graphqlgo.NewList(agent), Resolve: d.listAgents, Args: listArgs(graphqlgo.FieldConfigArgument{"status": {Type: graphqlgo.String}})},
+		"agent":      {Type: agent, Resolve: d.getAgent, Args: idArgs()},
+		"mcpServers": {Type: graphqlgo.NewList(mcpServer), Resolve: d.listMCPServers, Args: listArgs(graphqlgo.FieldConfigArgument{})},
+		"mcpServer":  {Type: mcpServer, Resolve: d.getMCPServer, Args: idArgs()},
+		"alerts":     {Type: graphqlgo.NewList(alert), Resolve: d.listAlerts, Args: listArgs(graphqlgo.FieldConfigArgument{"status": {Type: graphqlgo.String}})},
+	}})
+}
+
+// scalar is a field of type t read from the source value, which has type T
+func scalar[T any](t graphqlgo.Output, get func(T) interface{}) *graphqlgo.Field {
+	return &graphqlgo.Field{Type: t, Resolve: func(p graphqlgo.ResolveParams) (interface{}, error) {
+		return get(p.Source.(T)), nil
+	}}
+}
+
+// listArgs adds the limit and offset arguments of a list field
+func listArgs(args graphqlgo.FieldConfigArgument) graphqlgo.FieldConfigArgument {
+	args["limit"] = &graphqlgo.ArgumentConfig{Type: graphqlgo.Int, DefaultValue: defaultPageSize}
+	args["offset"] = &graphqlgo.ArgumentConfig{Type: graphqlgo.Int, DefaultValue: 0}
+	return args
+}
+
+// idArgs is the id argument of a field looking up one record. It is nullable so that
+// optional String variables can be passed; a missing id is rejected by the resolver.
+func idArgs() graphqlgo.FieldConfigArgument {
+	return graphqlgo.FieldConfigArgument{"id": {Type: graphqlgo.String}}
+}
+
+func (d *Dashboard) listAgents(p graphqlgo.ResolveParams) (interface{}, error) {
+	limit, offset, err := pageArgs(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	agents, err := d.agents.ListAgents(p.Context, stateFrom(p.Context).orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+	if status, _ := p.Args["status"].(string); status != "" {
+		filtered := make([]*domain.Agent, 0, len(agents))
+		for _, agent := range agents {
+			if string(agent.Status) == status {
+				filtered = append(filtered, agent)
+			}
+		}
+		agents = filtered
+	}
+	return page(agents, limit, offset), nil
+}
+
+func (d *Dashboard) getAgent(p graphqlgo.ResolveParams) (interface{}, error) {
+	id, err := idArg(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	agents, err := d.agents.GetAgentsByIDs(p.Context, stateFrom(p.Context).orgID, []uuid.UUID{id})
+	if err != nil || len(agents) == 0 {
+		return nil, err
+	}
+	return agents[0], nil
+}
+
+func (d *Dashboard) listMCPServers(p graphqlgo.ResolveParams) (interface{}, error) {
+	limit, offset, err := pageArgs(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	servers, err := d.mcpServers.ListMCPServers(p.Context, stateFrom(p.Context).orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mcp servers: %w", err)
+	}
+	return page(servers, limit, offset), nil
+}
+
+func (d *Dashboard) getMCPServer(p graphqlgo.ResolveParams) (interface{}, error) {
+	id, err := idArg(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	servers, err := d.mcpServers.GetMCPServersByIDs(p.Context, stateFrom(p.Context).orgID, []uuid.UUID{id})
+	if err != nil || len(servers) == 0 {
+		return nil, err
+	}
+	return servers[0], nil
+}
+
+func (d *Dashboard) listAlerts(p graphqlgo.ResolveParams) (interface{}, error) {
+	limit, offset, err := pageArgs(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	status, _ := p.Args["status"].(string)
+	alerts, _, err := d.alerts.GetAlerts(p.Context, stateFrom(p.Context).orgID, "", status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+func (d *Dashboard) agentTrustScore(p graphqlgo.ResolveParams) (interface{}, error) {
+	return stateFrom(p.Context).trustScores.Load(p.Context, p.Source.(*domain.Agent).ID), nil
+}
+
+func (d *Dashboard) agentTags(p graphqlgo.ResolveParams) (interface{}, error) {
+	return loadTags(stateFrom(p.Context).agentTags.LoadValue(p.Context, p.Source.(*domain.Agent).ID)), nil
+}
+
+func (d *Dashboard) mcpServerTags(p graphqlgo.ResolveParams) (interface{}, error) {
+	return loadTags(stateFrom(p.Context).serverTags.LoadValue(p.Context, p.Source.(*domain.MCPServer).ID)), nil
+}
+
+// mcpServerUptime resolves to null when health checks are not running
+func (d *Dashboard) mcpServerUptime(p graphqlgo.ResolveParams) (interface{}, error) {
+	uptimes := stateFrom(p.Context).serverUptimes
+	if uptimes == nil {
+		return nil, nil
+	}
+	return uptimes.Load(p.Context, p.Source.(*domain.MCPServer).ID), nil
+}
+
+// loadTags resolves untagged resources to an empty list rather than null
+func loadTags(load func() ([]*domain.Tag, error)) Thunk {
+	return func() (interface{}, error) {
+		tags, err := load()
+		if err == nil && tags == nil {
+			tags = []*domain.Tag{}
+		}
+		return tags, err
+	}
+}
+
+func (d *Dashboard) agentAlerts(p graphqlgo.ResolveParams) (interface{}, error) {
+	limit, _ := p.Args["limit"].(int)
+	if limit < 1 || limit > maxPageSize {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+	}
+	return d.alerts.GetResourceAlerts(p.Context, stateFrom(p.Context).orgID, p.Source.(*domain.Agent).ID, limit)
+}
+
+func (d *Dashboard) agentMCPServers(p graphqlgo.ResolveParams) (interface{}, error) {
+	servers, err := d.attestations.GetMCPServersForAgent(p.Context, p.Source.(*domain.Agent).ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mcp servers: %w", err)
+	}
+	orgID := stateFrom(p.Context).orgID
+	result := make([]*domain.MCPServer, 0, len(servers))
+	for _, server := range servers {
+		if server.OrganizationID == orgID {
+			result = append(result, server)
+		}
+	}
+	return result, nil
+}
+
+func (d *Dashboard) mcpServerAttestations(p graphqlgo.ResolveParams) (interface{}, error) {
+	attestations, _, _, err := d.attestations.GetMCPAttestations(p.Context, p.Source.(*domain.MCPServer).ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attestations: %w", err)
+	}
+	return attestations, nil
+}
+
+func idArg(args map[string]interface{}) (uuid.UUID, error) {
+	value, _ := args["id"].(string)
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("argument \"id\" must be a UUID")
+	}
+	return id, nil
+}
+
+// pageArgs reads the limit and offset arguments of a list field
+func pageArgs(args map[string]interface{}) (limit, offset int, err error) {
+	limit, _ = args["limit"].(int)
+	if limit < 1 || limit > maxPageSize {
+		return 0, 0, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+	}
+	offset, _ = args["offset"].(int)
+	if offset < 0 {
+		return 0, 0, fmt.Errorf("offset must not be negative")
+	}
+	return limit, offset, nil
+}
+
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
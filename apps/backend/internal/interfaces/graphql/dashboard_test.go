@@ -0,0 +1,114 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardGraphQLQueriesAreScopedToTheOrganization(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	other := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	require.NoError(t, repos.Organization.Create(other))
+	admin := testsupport.NewUser(org.ID)
+	ctx := context.Background()
+
+	alpha := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.Name = "alpha" })
+	beta := testsupport.NewAgent(org.ID, func(a *domain.Agent) { a.Name = "beta" })
+	gamma := testsupport.NewAgent(other.ID, func(a *domain.Agent) { a.Name = "gamma" })
+	for _, agent := range []*domain.Agent{alpha, beta, gamma} {
+		require.NoError(t, repos.Agent.Create(agent))
+	}
+	require.NoError(t, repos.TrustScore.Create(&domain.TrustScore{AgentID: alpha.ID, Score: 0.4, CreatedAt: time.Now().Add(-time.Hour)}))
+	require.NoError(t, repos.TrustScore.Create(&domain.TrustScore{AgentID: alpha.ID, Score: 0.9, Factors: domain.TrustScoreFactors{Uptime: 0.95}}))
+	require.NoError(t, repos.Alert.Create(testsupport.NewAlert(org.ID, alpha.ID, func(a *domain.Alert) { a.Title = "Capability violation" })))
+	require.NoError(t, repos.Alert.Create(testsupport.NewAlert(other.ID, gamma.ID, func(a *domain.Alert) { a.Title = "Other organization" })))
+
+	agentService := application.NewAgentService(repos.Agent, nil, repos.TrustScore, nil, repos.Alert, nil, repos.Capability, nil, nil, repos.Organization, nil, nil, nil, nil)
+	tagService := application.NewTagService(repos.Tag, repos.Agent, repos.MCPServer, nil)
+	production, err := tagService.CreateTag(ctx, application.CreateTagInput{
+		OrganizationID: org.ID,
+		Key:            "environment",
+		Value:          "production",
+		Category:       domain.TagCategoryEnvironment,
+		CreatedBy:      admin.ID,
+	})
+	require.NoError(t, err)
+	require.NoError(t, tagService.AddTagsToAgent(ctx, alpha.ID, []uuid.UUID{production.ID}, admin.ID))
+
+	dashboard := NewDashboard(
+		agentService,
+		nil,
+		application.NewAlertService(repos.Alert, repos.Agent, nil, nil, nil, nil),
+		application.NewTrustCalculator(repos.TrustScore, nil, nil, nil, repos.Agent, repos.Alert),
+		tagService,
+		nil,
+		nil,
+		8,
+		10000,
+	)
+
+	response := dashboard.Execute(ctx, org.ID, &Request{
+		Query: `query Dashboard($other: String) {
+			agents { name latestTrustScore { score factors { uptime } } tags { key value } alerts(limit: 5) { title } }
+			foreign: agent(id: $other) { name }
+			alerts { title }
+		}`,
+		Variables: map[string]interface{}{"other": gamma.ID.String()},
+	})
+	require.Empty(t, response.Errors)
+	body, err := json.Marshal(response)
+	require.NoError(t, err)
+
+	var result struct {
+		Data struct {
+			Agents []struct {
+				Name             string
+				LatestTrustScore *struct {
+					Score   float64
+					Factors struct{ Uptime float64 }
+				}
+				Tags   []struct{ Key, Value string }
+				Alerts []struct{ Title string }
+			}
+			Foreign *struct{ Name string }
+			Alerts  []struct{ Title string }
+		}
+	}
+	require.NoError(t, json.Unmarshal(body, &result))
+
+	agents := result.Data.Agents
+	require.Len(t, agents, 2, "agents of other organizations are not listed")
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Name < agents[j].Name })
+	assert.Equal(t, "alpha", agents[0].Name)
+	require.NotNil(t, agents[0].LatestTrustScore)
+	assert.Equal(t, 0.9, agents[0].LatestTrustScore.Score, "the latest score is returned")
+	assert.Equal(t, 0.95, agents[0].LatestTrustScore.Factors.Uptime)
+	assert.Equal(t, []struct{ Key, Value string }{{"environment", "production"}}, agents[0].Tags)
+	assert.Equal(t, []struct{ Title string }{{"Capability violation"}}, agents[0].Alerts)
+	assert.Equal(t, "beta", agents[1].Name)
+	assert.Nil(t, agents[1].LatestTrustScore, "unscored agents have no trust score")
+	assert.NotNil(t, agents[1].Tags)
+	assert.Empty(t, agents[1].Tags)
+	assert.Nil(t, result.Data.Foreign, "another organization's agent resolves to null")
+	assert.Equal(t, []struct{ Title string }{{"Capability violation"}}, result.Data.Alerts)
+
+	// Limits are enforced before anything is resolved
+	response = dashboard.Execute(ctx, org.ID, &Request{
+		Query: `{ agents(limit: 100) { mcpServers { attestations { id } tags { key } } } }`,
+	})
+	assert.Nil(t, response.Data)
+	require.Len(t, response.Errors, 1)
+	assert.Contains(t, response.Errors[0].Message, "exceeds the maximum of 10000")
+}
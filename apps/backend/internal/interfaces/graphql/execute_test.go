@@ -0,0 +1,203 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+
+	graphqlgo "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testItem struct {
+	ID      int
+	Name    string
+	OwnerID int
+}
+
+// testSchema serves items whose owners are batched through a loader; fetches records the
+// owner IDs of every batch
+func testSchema(fetches *[][]int) *Schema {
+	owners := NewLoader(func(ctx context.Context, ids []int) (map[int]string, error) {
+		batch := append([]int(nil), ids...)
+		sort.Ints(batch)
+		*fetches = append(*fetches, batch)
+		names := make(map[int]string)
+		for _, id := range ids {
+			if id != 0 {
+				names[id] = "owner-" + string(rune('a'+id-1))
+			}
+		}
+		return names, nil
+	})
+
+	person := graphqlgo.NewObject(graphqlgo.ObjectConfig{Name: "Person", Fields: graphqlgo.Fields{
+		"name": scalar(graphqlgo.String, func(name string) interface{} { return name }),
+	}})
+	item := graphqlgo.NewObject(graphqlgo.ObjectConfig{Name: "Item", Fields: graphqlgo.Fields{
+		"id":   scalar(graphqlgo.Int, func(i *testItem) interface{} { return i.ID }),
+		"name": scalar(graphqlgo.String, func(i *testItem) interface{} { return i.Name }),
+		"owner": {Type: person, Resolve: func(p graphqlgo.ResolveParams) (interface{}, error) {
+			load := owners.LoadValue(p.Context, p.Source.(*testItem).OwnerID)
+			return Thunk(func() (interface{}, error) {
+				name, err := load()
+				if name == "" {
+					return nil, err
+				}
+				return name, err
+			}), nil
+		}},
+		"broken": {Type: graphqlgo.String, Resolve: func(p graphqlgo.ResolveParams) (interface{}, error) {
+			return nil, errors.New("broken field")
+		}},
+	}})
+	item.AddFieldConfig("related", &graphqlgo.Field{Type: graphqlgo.NewList(item), Resolve: func(p graphqlgo.ResolveParams) (interface{}, error) {
+		return []*testItem{}, nil
+	}})
+
+	items := []*testItem{{ID: 1, Name: "one", OwnerID: 1}, {ID: 2, Name: "two", OwnerID: 2}, {ID: 3, Name: "three", OwnerID: 1}, {ID: 4, Name: "four"}}
+	query := graphqlgo.NewObject(graphqlgo.ObjectConfig{Name: "Query", Fields: graphqlgo.Fields{
+		"items": {Type: graphqlgo.NewList(item), Args: graphqlgo.FieldConfigArgument{
+			"limit": {Type: graphqlgo.Int, DefaultValue: len(items)},
+		}, Resolve: func(p graphqlgo.ResolveParams) (interface{}, error) {
+			limit := p.Args["limit"].(int)
+			if limit > len(items) {
+				limit = len(items)
+			}
+			return items[:limit], nil
+		}},
+		"item": {Type: item, Args: graphqlgo.FieldConfigArgument{
+			"id": {Type: graphqlgo.Int},
+		}, Resolve: func(p graphqlgo.ResolveParams) (interface{}, error) {
+			id, _ := p.Args["id"].(int)
+			for _, i := range items {
+				if i.ID == id {
+					return i, nil
+				}
+			}
+			return (*testItem)(nil), nil
+		}},
+	}})
+
+	schema, err := NewSchema(query, map[string]FieldCost{
+		"Query.items":  {ListSize: 20},
+		"Item.related": {ListSize: 10},
+	}, 4, 200)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+func execute(t *testing.T, schema *Schema, req *Request) (string, []gqlerrors.FormattedError) {
+	t.Helper()
+	response := schema.Execute(context.Background(), req)
+	if response.Data == nil {
+		return "", response.Errors
+	}
+	data, err := json.Marshal(response.Data)
+	require.NoError(t, err)
+	return string(data), response.Errors
+}
+
+func TestExecuteBatchesLoadsAcrossParents(t *testing.T) {
+	var fetches [][]int
+	schema := testSchema(&fetches)
+
+	data, errs := execute(t, schema, &Request{Query: `{ items(limit: 4) { id owner { name } } }`})
+	require.Empty(t, errs)
+	assert.JSONEq(t, `{"items":[
+		{"id":1,"owner":{"name":"owner-a"}},
+		{"id":2,"owner":{"name":"owner-b"}},
+		{"id":3,"owner":{"name":"owner-a"}},
+		{"id":4,"owner":null}]}`, data)
+	assert.Equal(t, [][]int{{0, 1, 2}}, fetches, "owners are fetched once for every item, each key once")
+}
+
+func TestExecuteResolvesAliasesFragmentsAndDirectives(t *testing.T) {
+	var fetches [][]int
+	data, errs := execute(t, testSchema(&fetches), &Request{Query: `
+		query Items($id: Int = 2, $withName: Boolean!) {
+			second: item(id: $id) { ...Fields name @include(if: $withName) __typename }
+			missing: item(id: 9) { id }
+		}
+		fragment Fields on Item { id ... on Item { label: name } }
+	`, Variables: map[string]interface{}{"withName": false}})
+	require.Empty(t, errs)
+	assert.JSONEq(t, `{"second":{"id":2,"label":"two","__typename":"Item"},"missing":null}`, data)
+}
+
+func TestExecuteReportsFieldErrorsWithPaths(t *testing.T) {
+	var fetches [][]int
+	data, errs := execute(t, testSchema(&fetches), &Request{Query: `{ items(limit: 2) { id broken } }`})
+	assert.JSONEq(t, `{"items":[{"id":1,"broken":null},{"id":2,"broken":null}]}`, data)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "broken field", errs[0].Message)
+	assert.Equal(t, []interface{}{"items", 0, "broken"}, errs[0].Path)
+	assert.Equal(t, []interface{}{"items", 1, "broken"}, errs[1].Path)
+}
+
+func TestExecuteRejectsMalformedAndInvalidQueries(t *testing.T) {
+	var fetches [][]int
+	schema := testSchema(&fetches)
+
+	tests := []struct {
+		name  string
+		query string
+		err   string
+	}{
+		{"syntax", `{ items { id }`, "Syntax Error"},
+		{"unterminated string", `{ item(id: "1) { id } }`, "Unterminated string"},
+		{"invalid number", `{ item(id: 1.) { id } }`, "Invalid number"},
+		{"empty", ``, "Must provide an operation"},
+		{"mutation", `mutation { deleteAgent(id: 1) }`, "Schema is not configured for mutations"},
+		{"unknown field", `{ items { secret } }`, `Cannot query field "secret" on type "Item"`},
+		{"scalar subfields", `{ items { id { x } } }`, `Field "id" of type "Int" must not have a sub selection`},
+		{"missing subfields", `{ items }`, `Field "items" of type "[Item]" must have a sub selection`},
+		{"wrong argument type", `{ item(id: "one") { id } }`, `Argument "id" has invalid value "one"`},
+		{"unknown fragment", `{ items { ...Missing } }`, `Unknown fragment "Missing"`},
+		{"fragment cycle", `{ items { ...A } } fragment A on Item { related { ...B } } fragment B on Item { ...A }`, `Cannot spread fragment "A" within itself`},
+		{"undefined variable", `{ item(id: $id) { id } }`, `Variable "$id" is not defined`},
+		{"conflicting aliases", `{ items { x: id x: name } }`, `Fields "x" conflict`},
+		{"too deep", `{ items { related { related { related { id } } } } }`, "query exceeds the maximum depth of 4"},
+		{"too deep through fragments", `{ items { ...A } } fragment A on Item { related { related { ...B } } } fragment B on Item { related { id } }`, "query exceeds the maximum depth of 4"},
+		{"deep introspection", `{ __schema { types { fields { type { ofType { name } } } } } }`, "query exceeds the maximum depth of 4"},
+		{"too complex", `{ items { id name related { id name } } }`, "query complexity 461 exceeds the maximum of 200; select fewer fields or lower the limit arguments"},
+		{"several operations", `query A { items { id } } query B { items { id } }`, "Must provide operation name if query contains multiple operations"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, errs := execute(t, schema, &Request{Query: tt.query})
+			assert.Empty(t, data, "nothing is resolved")
+			require.NotEmpty(t, errs)
+			assert.Contains(t, errs[0].Message, tt.err)
+		})
+	}
+	assert.Empty(t, fetches)
+}
+
+func TestExecuteComplexityUsesLimitArguments(t *testing.T) {
+	var fetches [][]int
+	schema := testSchema(&fetches)
+
+	// Without a limit items are assumed to return 20, each costing 1 + 1 + 1 + 10*(1+1)
+	_, errs := execute(t, schema, &Request{Query: `{ items { id name related { id name } } }`})
+	require.Len(t, errs, 1)
+
+	data, errs := execute(t, schema, &Request{
+		Query:     `query($n: Int) { items(limit: $n) { id name related { id name } } }`,
+		Variables: map[string]interface{}{"n": float64(2)},
+	})
+	require.Empty(t, errs)
+	assert.JSONEq(t, `{"items":[{"id":1,"name":"one","related":[]},{"id":2,"name":"two","related":[]}]}`, data)
+
+	data, errs = execute(t, schema, &Request{
+		Query: `query($n: Int = 1) { items(limit: $n) { id related { id name } } }`,
+	})
+	require.Empty(t, errs)
+	assert.JSONEq(t, `{"items":[{"id":1,"related":[]}]}`, data)
+}
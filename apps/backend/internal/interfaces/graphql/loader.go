@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// Loader batches the lookups of a field resolved for many parents. Load registers a key and
+// returns a Thunk; evaluating the first thunk fetches every key registered so far in one call.
+// Results are cached for the lifetime of the loader, which should be one request.
+type Loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	values  map[K]V
+	errs    map[K]error
+}
+
+// NewLoader creates a loader. fetch returns the values found for keys; keys it leaves out
+// resolve to the zero value.
+func NewLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:  fetch,
+		queued: make(map[K]bool),
+		values: make(map[K]V),
+		errs:   make(map[K]error),
+	}
+}
+
+// Load returns a thunk resolving to the value of key
+func (l *Loader[K, V]) Load(ctx context.Context, key K) Thunk {
+	l.mu.Lock()
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		value, err := l.get(ctx, key)
+		return value, err
+	}
+}
+
+// LoadValue is like Load for callers needing the typed value, e.g. to convert it
+func (l *Loader[K, V]) LoadValue(ctx context.Context, key K) func() (V, error) {
+	l.Load(ctx, key)
+	return func() (V, error) {
+		return l.get(ctx, key)
+	}
+}
+
+func (l *Loader[K, V]) get(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err, ok := l.errs[key]; ok {
+		var zero V
+		return zero, err
+	}
+	if value, ok := l.values[key]; ok {
+		return value, nil
+	}
+
+	batch := l.pending
+	l.pending = nil
+	found, err := l.fetch(ctx, batch)
+	for _, k := range batch {
+		if err != nil {
+			l.errs[k] = err
+			continue
+		}
+		l.values[k] = found[k]
+	}
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return l.values[key], nil
+}
@@ -0,0 +1,274 @@
+// Package graphql serves read-only GraphQL queries over the application services, so the
+// dashboard can fetch agents with their trust scores, tags, alerts and attestations in one
+// request.
+//
+// Queries are parsed, validated and executed by graphql-go. Only the query root is defined:
+// changes go through the REST API. Before anything is resolved, every query is also held to
+// depth and complexity limits. graphql-go resolves thunks breadth first, so a field resolved
+// for many parents can batch its lookups with a Loader.
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	graphqlgo "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// maxCost caps complexity sums so nested list multipliers cannot overflow
+const maxCost = 1 << 40
+
+// Schema is a GraphQL schema and the limits its queries are held to
+type Schema struct {
+	schema graphqlgo.Schema
+	costs  map[string]FieldCost
+	// maxDepth limits how deep selections nest; zero disables the limit
+	maxDepth int
+	// maxComplexity limits the sum of field costs, each multiplied by the size of the lists it
+	// is selected within; zero disables the limit
+	maxComplexity int
+}
+
+// FieldCost is the complexity of a field, keyed by "Type.field" in NewSchema. Fields without
+// one cost one, and list fields without one are assumed to return a single value.
+type FieldCost struct {
+	// Cost is the complexity of resolving the field once; zero counts as one
+	Cost int
+	// ListSize is the number of values a list field is assumed to return when the query gives
+	// no limit argument
+	ListSize int
+}
+
+// NewSchema creates a schema with the given query root
+func NewSchema(query *graphqlgo.Object, costs map[string]FieldCost, maxDepth, maxComplexity int) (*Schema, error) {
+	schema, err := graphqlgo.NewSchema(graphqlgo.SchemaConfig{Query: query})
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{schema: schema, costs: costs, maxDepth: maxDepth, maxComplexity: maxComplexity}, nil
+}
+
+// Thunk is a deferred field value, typically one a Loader fetches in a batch. graphql-go
+// evaluates it once the field was resolved for every parent.
+type Thunk = func() (interface{}, error)
+
+// Request is a GraphQL request as posted over HTTP
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is a GraphQL response. Data is absent when the query was rejected before execution.
+type Response struct {
+	Data   interface{}                `json:"data,omitempty"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"`
+}
+
+// Execute validates a query against the schema and its limits, then resolves it
+func (s *Schema) Execute(ctx context.Context, req *Request) *Response {
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(req.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		return &Response{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if result := graphqlgo.ValidateDocument(&s.schema, doc, nil); !result.IsValid {
+		return &Response{Errors: result.Errors}
+	}
+	if err := s.checkLimits(doc, req); err != nil {
+		return &Response{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	result := graphqlgo.Execute(graphqlgo.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+	return &Response{Data: result.Data, Errors: result.Errors}
+}
+
+// checkLimits rejects queries nesting deeper than the depth limit or more complex than the
+// complexity limit. Operations graphql-go will not run are left for it to report.
+func (s *Schema) checkLimits(doc *ast.Document, req *Request) error {
+	a := &analysis{schema: s, fragments: make(map[string]*ast.FragmentDefinition), variables: req.Variables}
+	var operations []*ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch definition := definition.(type) {
+		case *ast.OperationDefinition:
+			if req.OperationName == "" || (definition.Name != nil && definition.Name.Value == req.OperationName) {
+				operations = append(operations, definition)
+			}
+		case *ast.FragmentDefinition:
+			a.fragments[definition.Name.Value] = definition
+		}
+	}
+	if len(operations) != 1 || operations[0].Operation != ast.OperationTypeQuery {
+		return nil
+	}
+	a.defaults = make(map[string]ast.Value)
+	for _, variable := range operations[0].VariableDefinitions {
+		a.defaults[variable.Variable.Name.Value] = variable.DefaultValue
+	}
+
+	complexity, err := a.complexity(s.schema.QueryType(), operations[0].SelectionSet, 1)
+	if err != nil {
+		return err
+	}
+	if s.maxComplexity > 0 && complexity > s.maxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the maximum of %d; select fewer fields or lower the limit arguments", complexity, s.maxComplexity)
+	}
+	return nil
+}
+
+// analysis computes the complexity of a validated query
+type analysis struct {
+	schema    *Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	defaults  map[string]ast.Value
+}
+
+// complexity checks selections of parent against the depth limit and returns their complexity.
+// Fields are counted whether or not @include and @skip leave them out.
+func (a *analysis) complexity(parent *graphqlgo.Object, selections *ast.SelectionSet, depth int) (int, error) {
+	if a.schema.maxDepth > 0 && depth > a.schema.maxDepth {
+		return 0, fmt.Errorf("query exceeds the maximum depth of %d", a.schema.maxDepth)
+	}
+
+	complexity := 0
+	for _, selection := range selections.Selections {
+		var cost int
+		var err error
+		switch selection := selection.(type) {
+		case *ast.Field:
+			cost, err = a.fieldComplexity(parent, selection, depth)
+		case *ast.FragmentSpread:
+			cost, err = a.complexity(parent, a.fragments[selection.Name.Value].SelectionSet, depth)
+		case *ast.InlineFragment:
+			cost, err = a.complexity(parent, selection.SelectionSet, depth)
+		}
+		if err != nil {
+			return 0, err
+		}
+		complexity = addCost(complexity, cost)
+	}
+	return complexity, nil
+}
+
+func (a *analysis) fieldComplexity(parent *graphqlgo.Object, field *ast.Field, depth int) (int, error) {
+	name := field.Name.Value
+	var def *graphqlgo.FieldDefinition
+	switch name {
+	case "__schema":
+		def = graphqlgo.SchemaMetaFieldDef
+	case "__type":
+		def = graphqlgo.TypeMetaFieldDef
+	case "__typename":
+		def = graphqlgo.TypeNameMetaFieldDef
+	default:
+		def = parent.Fields()[name]
+	}
+
+	fieldCost := a.schema.costs[parent.Name()+"."+name]
+	cost := fieldCost.Cost
+	if cost == 0 {
+		cost = 1
+	}
+	object, list := objectType(def.Type)
+	if object == nil || field.SelectionSet == nil {
+		return cost, nil
+	}
+
+	children, err := a.complexity(object, field.SelectionSet, depth+1)
+	if err != nil {
+		return 0, err
+	}
+	if list {
+		children = mulCost(children, a.listSize(field, fieldCost))
+	}
+	return addCost(cost, children), nil
+}
+
+// objectType unwraps list and non-null types; object is nil for scalars and enums
+func objectType(t graphqlgo.Type) (object *graphqlgo.Object, list bool) {
+	for {
+		switch wrapped := t.(type) {
+		case *graphqlgo.NonNull:
+			t = wrapped.OfType
+		case *graphqlgo.List:
+			t, list = wrapped.OfType, true
+		case *graphqlgo.Object:
+			return wrapped, list
+		default:
+			return nil, list
+		}
+	}
+}
+
+// listSize is the number of values a list field is assumed to return, its limit if queried with one
+func (a *analysis) listSize(field *ast.Field, fieldCost FieldCost) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "limit" {
+			continue
+		}
+		if limit, ok := a.intValue(argument.Value); ok && limit > 0 {
+			return limit
+		}
+	}
+	if fieldCost.ListSize > 0 {
+		return fieldCost.ListSize
+	}
+	return 1
+}
+
+// intValue returns the value of an integer literal or variable
+func (a *analysis) intValue(value ast.Value) (int, bool) {
+	switch value := value.(type) {
+	case *ast.IntValue:
+		n, err := strconv.Atoi(value.Value)
+		return n, err == nil
+	case *ast.Variable:
+		name := value.Name.Value
+		switch v := a.variables[name].(type) {
+		case nil:
+			if fallback := a.defaults[name]; fallback != nil {
+				return a.intValue(fallback)
+			}
+		case int:
+			return v, true
+		case float64: // Variables decoded from JSON
+			if v == math.Trunc(v) && v >= math.MinInt32 && v <= math.MaxInt32 {
+				return int(v), true
+			}
+		case json.Number:
+			n, err := strconv.Atoi(v.String())
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
+
+func addCost(a, b int) int {
+	if a+b > maxCost {
+		return maxCost
+	}
+	return a + b
+}
+
+func mulCost(a, b int) int {
+	if a != 0 && b > maxCost/a {
+		return maxCost
+	}
+	return a * b
+}
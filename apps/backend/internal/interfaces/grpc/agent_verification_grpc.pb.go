@@ -0,0 +1,216 @@
+// gRPC API for high-throughput agent verification.
+//
+// Served next to the HTTP API on GRPC_PORT (TLS, HTTP/2). Calls authenticate with an agent API key
+// in the "x-api-key" (or "authorization: Bearer aim_...") metadata, or with the agent certificate
+// issued by the platform CA presented as the TLS client certificate (mTLS). A call may only act as
+// the agent it is authenticated as.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: aim/v1/agent_verification.proto
+
+package grpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AgentVerification_VerifyAction_FullMethodName      = "/aim.v1.AgentVerification/VerifyAction"
+	AgentVerification_CreateAttestation_FullMethodName = "/aim.v1.AgentVerification/CreateAttestation"
+	AgentVerification_Heartbeat_FullMethodName         = "/aim.v1.AgentVerification/Heartbeat"
+)
+
+// AgentVerificationClient is the client API for AgentVerification service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AgentVerificationClient interface {
+	// VerifyAction checks the agent's capabilities for an action, like POST /api/v1/agents/{id}/verify-action.
+	// A denied action is a successful call with allowed = false. Requires the verify:create scope.
+	VerifyAction(ctx context.Context, in *VerifyActionRequest, opts ...grpc.CallOption) (*VerifyActionResponse, error)
+	// CreateAttestation records the agent's signed attestation of an MCP server, like
+	// POST /api/v1/mcp-servers/{id}/attest. Requires the verify:create scope.
+	CreateAttestation(ctx context.Context, in *CreateAttestationRequest, opts ...grpc.CallOption) (*CreateAttestationResponse, error)
+	// Heartbeat marks the agent active and optionally issues the nonce for its next attestation.
+	// Requires the verify:read scope.
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+}
+
+type agentVerificationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAgentVerificationClient(cc grpc.ClientConnInterface) AgentVerificationClient {
+	return &agentVerificationClient{cc}
+}
+
+func (c *agentVerificationClient) VerifyAction(ctx context.Context, in *VerifyActionRequest, opts ...grpc.CallOption) (*VerifyActionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyActionResponse)
+	err := c.cc.Invoke(ctx, AgentVerification_VerifyAction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentVerificationClient) CreateAttestation(ctx context.Context, in *CreateAttestationRequest, opts ...grpc.CallOption) (*CreateAttestationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateAttestationResponse)
+	err := c.cc.Invoke(ctx, AgentVerification_CreateAttestation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentVerificationClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, AgentVerification_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AgentVerificationServer is the server API for AgentVerification service.
+// All implementations must embed UnimplementedAgentVerificationServer
+// for forward compatibility.
+type AgentVerificationServer interface {
+	// VerifyAction checks the agent's capabilities for an action, like POST /api/v1/agents/{id}/verify-action.
+	// A denied action is a successful call with allowed = false. Requires the verify:create scope.
+	VerifyAction(context.Context, *VerifyActionRequest) (*VerifyActionResponse, error)
+	// CreateAttestation records the agent's signed attestation of an MCP server, like
+	// POST /api/v1/mcp-servers/{id}/attest. Requires the verify:create scope.
+	CreateAttestation(context.Context, *CreateAttestationRequest) (*CreateAttestationResponse, error)
+	// Heartbeat marks the agent active and optionally issues the nonce for its next attestation.
+	// Requires the verify:read scope.
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	mustEmbedUnimplementedAgentVerificationServer()
+}
+
+// UnimplementedAgentVerificationServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAgentVerificationServer struct{}
+
+func (UnimplementedAgentVerificationServer) VerifyAction(context.Context, *VerifyActionRequest) (*VerifyActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyAction not implemented")
+}
+func (UnimplementedAgentVerificationServer) CreateAttestation(context.Context, *CreateAttestationRequest) (*CreateAttestationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAttestation not implemented")
+}
+func (UnimplementedAgentVerificationServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedAgentVerificationServer) mustEmbedUnimplementedAgentVerificationServer() {}
+func (UnimplementedAgentVerificationServer) testEmbeddedByValue()                           {}
+
+// UnsafeAgentVerificationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AgentVerificationServer will
+// result in compilation errors.
+type UnsafeAgentVerificationServer interface {
+	mustEmbedUnimplementedAgentVerificationServer()
+}
+
+func RegisterAgentVerificationServer(s grpc.ServiceRegistrar, srv AgentVerificationServer) {
+	// If the following call pancis, it indicates UnimplementedAgentVerificationServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AgentVerification_ServiceDesc, srv)
+}
+
+func _AgentVerification_VerifyAction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentVerificationServer).VerifyAction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentVerification_VerifyAction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentVerificationServer).VerifyAction(ctx, req.(*VerifyActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentVerification_CreateAttestation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAttestationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentVerificationServer).CreateAttestation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentVerification_CreateAttestation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentVerificationServer).CreateAttestation(ctx, req.(*CreateAttestationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentVerification_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentVerificationServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentVerification_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentVerificationServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AgentVerification_ServiceDesc is the grpc.ServiceDesc for AgentVerification service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AgentVerification_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "aim.v1.AgentVerification",
+	HandlerType: (*AgentVerificationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "VerifyAction",
+			Handler:    _AgentVerification_VerifyAction_Handler,
+		},
+		{
+			MethodName: "CreateAttestation",
+			Handler:    _AgentVerification_CreateAttestation_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _AgentVerification_Heartbeat_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "aim/v1/agent_verification.proto",
+}
@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// caller is the agent a call is authenticated as
+type caller struct {
+	organizationID uuid.UUID
+	agentID        uuid.UUID
+	userID         uuid.UUID // Creator of the API key; uuid.Nil for agent certificates
+	apiKeyID       uuid.UUID // uuid.Nil for agent certificates
+	authMethod     string    // "api_key" or "mtls"
+	ipAddress      string
+	userAgent      string
+}
+
+// authenticate identifies the calling agent from its TLS client certificate or else from its
+// API key
+func (s *Server) authenticate(ctx context.Context, md metadata.MD, scope string) (*caller, error) {
+	c := &caller{ipAddress: peerIP(ctx), userAgent: firstValue(md, "user-agent")}
+
+	if p, ok := peer.FromContext(ctx); ok && s.certificateService != nil {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+			agent, _, err := s.certificateService.AuthenticateClientCertificate(ctx, info.State.VerifiedChains[0][0])
+			if err != nil {
+				return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+			}
+
+			c.organizationID = agent.OrganizationID
+			c.agentID = agent.ID
+			c.authMethod = domain.AuthMethodMTLS
+			return c, nil
+		}
+	}
+
+	apiKey := firstValue(md, "x-api-key")
+	if bearer, ok := strings.CutPrefix(firstValue(md, "authorization"), "Bearer "); ok && apiKey == "" {
+		apiKey = bearer
+	}
+	if apiKey == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "an API key or agent client certificate is required")
+	}
+
+	key, err := s.apiKeyService.ValidateAPIKey(ctx, apiKey)
+	if err != nil || !key.IsActive {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid API key")
+	}
+	if !domain.APIKeyScopesAllow(key.Scopes, scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "API key is not allowed to perform this action (requires %s)", scope)
+	}
+
+	c.organizationID = key.OrganizationID
+	c.agentID = key.AgentID
+	c.userID = key.CreatedBy
+	c.apiKeyID = key.ID
+	c.authMethod = domain.AuthMethodAPIKey
+	return c, nil
+}
+
+// record returns a context recording how the caller authenticated and its credential, for the
+// verification events recorded by the call
+func (c *caller) record(ctx context.Context) context.Context {
+	recorded := domain.Caller{AgentID: &c.agentID, IP: c.ipAddress, UserAgent: c.userAgent}
+	if c.userID != uuid.Nil {
+		recorded.UserID = &c.userID
+	}
+	if c.apiKeyID != uuid.Nil {
+		recorded.APIKeyID = &c.apiKeyID
+	}
+	return domain.WithCaller(domain.WithAuthMethod(ctx, c.authMethod), recorded)
+}
+
+// actingAgent returns the agent a request is made for: the caller itself, as the caller may
+// not act for other agents
+func (c *caller) actingAgent(requested string) (uuid.UUID, error) {
+	if requested == "" {
+		return c.agentID, nil
+	}
+	agentID, err := uuid.Parse(requested)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "invalid agent_id")
+	}
+	if agentID != c.agentID {
+		return uuid.Nil, status.Errorf(codes.PermissionDenied, "credentials do not belong to agent %s", agentID)
+	}
+	return agentID, nil
+}
@@ -0,0 +1,200 @@
+// Package grpc serves the agent verification API of api/proto/aim/v1/agent_verification.proto
+// with grpc-go. The messages and the service interface are generated from the proto file.
+package grpc
+
+//go:generate protoc -I ../../../api/proto --go_out=../../.. --go_opt=module=github.com/opena2a/identity/backend --go-grpc_out=../../.. --go-grpc_opt=module=github.com/opena2a/identity/backend aim/v1/agent_verification.proto
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip" // Accept gzip-compressed requests
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// methodScopes are the API key scopes the RPCs require
+var methodScopes = map[string]string{
+	AgentVerification_VerifyAction_FullMethodName:      domain.APIKeyScopeVerifyCreate,
+	AgentVerification_CreateAttestation_FullMethodName: domain.APIKeyScopeVerifyCreate,
+	AgentVerification_Heartbeat_FullMethodName:         domain.APIKeyScopeVerifyRead,
+}
+
+// Server serves the gRPC agent verification API next to the Fiber HTTP API. It shares the
+// application services with the HTTP handlers, so calls are verified, audited and recorded
+// exactly like their HTTP counterparts.
+type Server struct {
+	UnimplementedAgentVerificationServer
+
+	agentService             *application.AgentService
+	attestationService       *application.MCPAttestationService // Optional: CreateAttestation is unimplemented without it
+	apiKeyService            *application.APIKeyService
+	certificateService       *application.AgentCertificateService // Optional: agent certificates (mTLS) are not accepted without it
+	quotaService             *application.AgentQuotaService       // Optional: per-agent plan tier quotas
+	auditService             *application.AuditService
+	verificationEventService *application.VerificationEventService
+	alertService             *application.AlertService
+}
+
+// NewServer creates the gRPC server
+func NewServer(
+	agentService *application.AgentService,
+	attestationService *application.MCPAttestationService,
+	apiKeyService *application.APIKeyService,
+	certificateService *application.AgentCertificateService,
+	quotaService *application.AgentQuotaService,
+	auditService *application.AuditService,
+	verificationEventService *application.VerificationEventService,
+	alertService *application.AlertService,
+) *Server {
+	return &Server{
+		agentService:             agentService,
+		attestationService:       attestationService,
+		apiKeyService:            apiKeyService,
+		certificateService:       certificateService,
+		quotaService:             quotaService,
+		auditService:             auditService,
+		verificationEventService: verificationEventService,
+		alertService:             alertService,
+	}
+}
+
+// TLSConfig returns the server TLS configuration. Agent certificates from the platform CA are
+// requested but optional, so agents can authenticate with an API key instead.
+func (s *Server) TLSConfig(certificates []tls.Certificate) *tls.Config {
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: certificates,
+		NextProtos:   []string{"h2"},
+	}
+	if s.certificateService != nil {
+		clientCAs := x509.NewCertPool()
+		clientCAs.AppendCertsFromPEM(s.certificateService.CACertificatePEM())
+		config.ClientCAs = clientCAs
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return config
+}
+
+// newGRPCServer creates a grpc-go server serving the AgentVerification service over TLS
+func (s *Server) newGRPCServer(certificates []tls.Certificate) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(s.TLSConfig(certificates))),
+		grpc.UnaryInterceptor(s.intercept),
+	)
+	RegisterAgentVerificationServer(server, s)
+	return server
+}
+
+// Serve accepts gRPC calls on addr until ctx is cancelled
+func (s *Server) Serve(ctx context.Context, addr string, certificate tls.Certificate) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	server := s.newGRPCServer([]tls.Certificate{certificate})
+
+	go func() {
+		<-ctx.Done()
+		// In-flight calls get 10 seconds to finish
+		timer := time.AfterFunc(10*time.Second, server.Stop)
+		defer timer.Stop()
+		server.GracefulStop()
+	}()
+
+	return server.Serve(listener)
+}
+
+// callerKey is the context key of the authenticated caller
+type callerKey struct{}
+
+// intercept traces and authenticates every call. The RPC handlers take the authenticated caller
+// from the context.
+func (s *Server) intercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (response interface{}, err error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	// Callers propagate their trace in the traceparent metadata
+	ctx = tracing.Extract(ctx, func(key string) string { return firstValue(md, key) })
+	service, method, _ := strings.Cut(strings.TrimPrefix(info.FullMethod, "/"), "/")
+	ctx, span := tracing.StartSpan(ctx, service+"/"+method, tracing.SpanKindServer,
+		tracing.String("rpc.system", "grpc"),
+		tracing.String("rpc.service", service),
+		tracing.String("rpc.method", method),
+		tracing.String("client.address", peerIP(ctx)),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	scope, ok := methodScopes[info.FullMethod]
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "unknown method %s", info.FullMethod)
+	}
+	c, err := s.authenticate(ctx, md, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err = handler(context.WithValue(c.record(ctx), callerKey{}, c), req)
+	return response, statusError(info.FullMethod, err)
+}
+
+// callerFromContext returns the caller intercept authenticated
+func callerFromContext(ctx context.Context) (*caller, error) {
+	c, ok := ctx.Value(callerKey{}).(*caller)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "call is not authenticated")
+	}
+	return c, nil
+}
+
+// statusError returns the error the client receives. Errors without a gRPC status are logged
+// and reported as internal errors.
+func statusError(fullMethod string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	log.Printf("❌ gRPC %s failed: %v", fullMethod, err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// firstValue returns the first value of a metadata key
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// peerIP returns the client address without the port
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,149 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCVerificationAuthenticatesWithAPIKeyOrAgentCertificate(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	ctx := context.Background()
+
+	keyPair, err := crypto.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	publicKey := crypto.EncodeKeyPair(keyPair).PublicKeyBase64
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) {
+		a.Status = domain.AgentStatusVerified
+		a.PublicKey = &publicKey
+	})
+	require.NoError(t, repos.Agent.Create(agent))
+	other := testsupport.NewAgent(org.ID)
+	require.NoError(t, repos.Agent.Create(other))
+	require.NoError(t, repos.Capability.CreateCapability(testsupport.NewAgentCapability(agent, domain.CapabilityFileRead)))
+
+	ca, err := crypto.NewAgentCA(make([]byte, 32))
+	require.NoError(t, err)
+	certificates := application.NewAgentCertificateService(repos.AgentCertificate, repos.Agent, ca, time.Hour, "")
+	apiKeys := application.NewAPIKeyService(repos.APIKey, repos.Agent)
+	policyService := application.NewSecurityPolicyService(repos.SecurityPolicy, repos.Alert, repos.AuditLog, repos.MCPServerCapability, nil)
+	server := NewServer(
+		application.NewAgentService(repos.Agent, nil, repos.TrustScore, nil, repos.Alert, policyService, repos.Capability, nil, nil, repos.Organization, nil, nil, nil, nil),
+		nil,
+		apiKeys,
+		certificates,
+		nil,
+		application.NewAuditService(repos.AuditLog),
+		application.NewVerificationEventService(repos.VerificationEvent, repos.Agent, nil, nil, nil, nil),
+		application.NewAlertService(repos.Alert, repos.Agent, nil, nil, nil, nil),
+	)
+
+	serverCertificate, roots := selfSignedCertificate(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := server.newGRPCServer([]tls.Certificate{serverCertificate})
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	dial := func(clientCertificates ...tls.Certificate) AgentVerificationClient {
+		conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			RootCAs:      roots,
+			Certificates: clientCertificates,
+		})))
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return NewAgentVerificationClient(conn)
+	}
+	client := dial()
+
+	// API keys authenticate the agent they belong to, within their scopes
+	rawKey, _, err := apiKeys.GenerateAPIKey(ctx, agent.ID, org.ID, agent.CreatedBy, "grpc", 30, []string{domain.APIKeyScopeVerifyCreate})
+	require.NoError(t, err)
+	withKey := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+rawKey)
+
+	_, err = client.VerifyAction(ctx, &VerifyActionRequest{ActionType: domain.CapabilityFileRead})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	verified, err := client.VerifyAction(withKey, &VerifyActionRequest{ActionType: domain.CapabilityFileRead, Resource: "/tmp/report.csv"})
+	require.NoError(t, err)
+	assert.True(t, verified.Allowed, verified.Reason)
+	assert.NotEmpty(t, verified.AuditId)
+
+	denied, err := client.VerifyAction(withKey, &VerifyActionRequest{AgentId: agent.ID.String(), ActionType: "db:write"})
+	require.NoError(t, err, "denied actions are answered, not failed")
+	assert.False(t, denied.Allowed)
+
+	_, err = client.VerifyAction(withKey, &VerifyActionRequest{AgentId: other.ID.String(), ActionType: domain.CapabilityFileRead})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "keys only act for their own agent")
+	_, err = client.VerifyAction(withKey, &VerifyActionRequest{AgentId: "not-a-uuid", ActionType: domain.CapabilityFileRead})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.Heartbeat(withKey, &HeartbeatRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "heartbeats need verify:read")
+	_, err = client.CreateAttestation(withKey, &CreateAttestationRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err), "attestations need the attestation service")
+
+	_, total, err := repos.VerificationEvent.GetByAgent(agent.ID, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+
+	// Agent certificates authenticate over mTLS without an API key
+	issued, err := certificates.Issue(ctx, agent)
+	require.NoError(t, err)
+	leaf, _ := pem.Decode([]byte(issued.CertificatePEM))
+	require.NotNil(t, leaf)
+	mtlsClient := dial(tls.Certificate{Certificate: [][]byte{leaf.Bytes}, PrivateKey: keyPair.PrivateKey})
+
+	heartbeat, err := mtlsClient.Heartbeat(ctx, &HeartbeatRequest{AgentId: agent.ID.String()})
+	require.NoError(t, err)
+	assert.Equal(t, string(domain.AgentStatusVerified), heartbeat.Status)
+	assert.NotEmpty(t, heartbeat.ServerTime)
+
+	_, err = certificates.Revoke(ctx, agent.ID, domain.CertificateRevocationKeyCompromise)
+	require.NoError(t, err)
+	_, err = mtlsClient.Heartbeat(ctx, &HeartbeatRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "revoked certificates are rejected")
+}
+
+// selfSignedCertificate returns a server certificate for 127.0.0.1 and a pool trusting it
+func selfSignedCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(certificate)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, roots
+}
@@ -0,0 +1,298 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// verificationProtocols maps the protocol SDKs declare to the recorded verification protocol
+var verificationProtocols = map[string]domain.VerificationProtocol{
+	"mcp":   domain.VerificationProtocolMCP,
+	"a2a":   domain.VerificationProtocolA2A,
+	"acp":   domain.VerificationProtocolACP,
+	"did":   domain.VerificationProtocolDID,
+	"oauth": domain.VerificationProtocolOAuth,
+	"saml":  domain.VerificationProtocolSAML,
+}
+
+// callerAgent loads the agent a request is made for
+func (s *Server) callerAgent(ctx context.Context, c *caller, requested string) (*domain.Agent, error) {
+	agentID, err := c.actingAgent(requested)
+	if err != nil {
+		return nil, err
+	}
+	agent, err := s.agentService.GetAgent(ctx, agentID)
+	if err != nil || agent.OrganizationID != c.organizationID {
+		return nil, status.Error(codes.NotFound, "agent not found")
+	}
+	return agent, nil
+}
+
+// acquireQuota holds the agent to its organization's plan tier quota for the duration of the call
+func (s *Server) acquireQuota(ctx context.Context, agentID uuid.UUID, verification bool) (func(), error) {
+	if s.quotaService == nil {
+		return func() {}, nil
+	}
+	usage, release, err := s.quotaService.Acquire(ctx, agentID, verification)
+	if err != nil {
+		release()
+		retryAfter := time.Duration(0)
+		if usage != nil {
+			retryAfter = usage.RetryAfter.Round(time.Second)
+		}
+		return nil, status.Errorf(codes.ResourceExhausted, "%v (retry after %s)", err, retryAfter)
+	}
+	return release, nil
+}
+
+// VerifyAction verifies the action and records it like the HTTP verify-action endpoint: an
+// audit entry, a verification event, an alert for capability violations and the agent's activity
+func (s *Server) VerifyAction(ctx context.Context, req *VerifyActionRequest) (*VerifyActionResponse, error) {
+	c, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	agent, err := s.callerAgent(ctx, c, req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+	release, err := s.acquireQuota(ctx, agent.ID, true)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	metadata := make(map[string]interface{}, len(req.Metadata))
+	for key, value := range req.Metadata {
+		metadata[key] = value
+	}
+
+	startTime := time.Now()
+	allowed, reason, auditID, err := s.agentService.VerifyAction(ctx, agent.ID, req.ActionType, req.Resource, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("verify action: %w", err)
+	}
+	durationMs := int(time.Since(startTime).Milliseconds())
+
+	auditMetadata := map[string]interface{}{
+		"action_type": req.ActionType,
+		"resource":    req.Resource,
+		"allowed":     allowed,
+		"reason":      reason,
+		"audit_id":    auditID,
+		"transport":   "grpc",
+		"auth_method": c.authMethod,
+	}
+	if len(metadata) > 0 {
+		auditMetadata["request_metadata"] = metadata
+	}
+	s.auditService.LogAction(ctx, agent.OrganizationID, c.userID, domain.AuditActionVerify, "agent_action", agent.ID, c.ipAddress, c.userAgent, auditMetadata)
+
+	status := domain.VerificationEventStatusSuccess
+	if !allowed {
+		status = domain.VerificationEventStatusFailed
+	}
+	protocol, ok := verificationProtocols[strings.ToLower(req.Protocol)]
+	if !ok {
+		protocol = domain.VerificationProtocolMCP
+	}
+	if _, err := s.verificationEventService.LogVerificationEvent(
+		ctx,
+		agent.OrganizationID,
+		agent.ID,
+		protocol,
+		domain.VerificationTypeCapability,
+		status,
+		durationMs,
+		domain.InitiatorTypeAgent,
+		nil,
+		map[string]interface{}{
+			"action_type": req.ActionType,
+			"resource":    req.Resource,
+			"allowed":     allowed,
+			"reason":      reason,
+		},
+	); err != nil {
+		log.Printf("⚠️  Failed to record verification event of agent %s: %v", agent.ID, err)
+	}
+
+	if !allowed && (reason == "capability_not_granted" || strings.HasPrefix(reason, "Agent has no granted capabilities")) {
+		alert := &domain.Alert{
+			OrganizationID: agent.OrganizationID,
+			AlertType:      domain.AlertSecurityBreach,
+			Severity:       domain.AlertSeverityHigh,
+			Title:          fmt.Sprintf("Capability Violation: %s attempted %s", agent.DisplayName, req.ActionType),
+			Description: fmt.Sprintf("Agent '%s' attempted action '%s' on resource '%s' without required capability. Reason: %s",
+				agent.DisplayName, req.ActionType, req.Resource, reason),
+			ResourceType: "agent",
+			ResourceID:   agent.ID,
+		}
+		if err := s.alertService.CreateAlert(ctx, alert); err != nil {
+			log.Printf("⚠️  Failed to create capability violation alert for agent %s: %v", agent.ID, err)
+		}
+	}
+
+	if err := s.agentService.UpdateLastActive(ctx, agent.ID); err != nil {
+		log.Printf("⚠️  Failed to update last_active of agent %s: %v", agent.ID, err)
+	}
+
+	return &VerifyActionResponse{Allowed: allowed, Reason: reason, AuditId: auditID.String()}, nil
+}
+
+// CreateAttestation records the agent's attestation of an MCP server by ID, or by the attested
+// mcp_url when no ID is given
+func (s *Server) CreateAttestation(ctx context.Context, req *CreateAttestationRequest) (*CreateAttestationResponse, error) {
+	if s.attestationService == nil {
+		return nil, status.Error(codes.Unimplemented, "attestations are not available")
+	}
+	c, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Attestation == nil {
+		return nil, status.Error(codes.InvalidArgument, "attestation is required")
+	}
+	agent, err := s.callerAgent(ctx, c, req.Attestation.AgentId)
+	if err != nil {
+		return nil, err
+	}
+	release, err := s.acquireQuota(ctx, agent.ID, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	attestation := req.Attestation
+	attestRequest := &application.AttestMCPRequest{
+		Attestation: domain.AttestationPayload{
+			AgentID:              attestation.AgentId,
+			CallbackURL:          req.CallbackUrl,
+			CapabilitiesFound:    attestation.CapabilitiesFound,
+			ConnectionLatencyMs:  attestation.ConnectionLatencyMs,
+			ConnectionSuccessful: attestation.ConnectionSuccessful,
+			HealthCheckPassed:    attestation.HealthCheckPassed,
+			MCPName:              attestation.McpName,
+			MCPURL:               attestation.McpUrl,
+			Nonce:                attestation.Nonce,
+			SDKVersion:           attestation.SdkVersion,
+			Timestamp:            attestation.Timestamp,
+		},
+		Signature: req.Signature,
+	}
+	if attestRequest.Attestation.CapabilitiesFound == nil {
+		attestRequest.Attestation.CapabilitiesFound = []string{}
+	}
+	if network := attestation.NetworkContext; network != nil {
+		attestRequest.Attestation.NetworkContext = &domain.NetworkContext{
+			NetworkZone:     network.NetworkZone,
+			PrivateNetwork:  network.PrivateNetwork,
+			RelayID:         network.RelayId,
+			ResolvedAddress: network.ResolvedAddress,
+		}
+	}
+
+	var response *application.AttestMCPResponse
+	if req.McpServerId != "" {
+		mcpServerID, parseErr := uuid.Parse(req.McpServerId)
+		if parseErr != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid mcp_server_id")
+		}
+		response, err = s.attestationService.VerifyAndRecordAttestation(ctx, mcpServerID, attestRequest)
+	} else {
+		response, err = s.attestationService.AttestMCPByURL(ctx, attestRequest)
+	}
+	if err != nil {
+		return nil, attestationError(err)
+	}
+
+	mcpServerID, _ := uuid.Parse(response.MCPServerID)
+	if mcpServerID == uuid.Nil {
+		mcpServerID, _ = uuid.Parse(req.McpServerId)
+	}
+	s.auditService.LogAction(ctx, agent.OrganizationID, c.userID, domain.AuditActionAttest, "mcp_server", mcpServerID, c.ipAddress, c.userAgent, map[string]interface{}{
+		"attestation_id":        response.AttestationID,
+		"confidence_score":      response.MCPConfidenceScore,
+		"attestation_count":     response.AttestationCount,
+		"agentId":               agent.ID,
+		"capabilities_found":    attestRequest.Attestation.CapabilitiesFound,
+		"connection_latency_ms": attestRequest.Attestation.ConnectionLatencyMs,
+		"transport":             "grpc",
+	})
+
+	return &CreateAttestationResponse{
+		AttestationId:      response.AttestationID,
+		McpConfidenceScore: response.MCPConfidenceScore,
+		AttestationCount:   int32(response.AttestationCount),
+		AgentVerifiedOnly:  response.AgentVerifiedOnly,
+		McpServerId:        response.MCPServerID,
+		AutoRegistered:     response.AutoRegistered,
+		Message:            response.Message,
+	}, nil
+}
+
+// attestationError maps attestation failures to the status codes matching the HTTP API
+func attestationError(err error) error {
+	message := err.Error()
+	switch {
+	case errors.Is(err, application.ErrMCPServerNotRegistered), strings.HasPrefix(message, "mcp server not found"):
+		return status.Errorf(codes.NotFound, "%s", message)
+	case errors.Is(err, application.ErrAttestationNonceRequired),
+		errors.Is(err, application.ErrAttestationNonceInvalid),
+		errors.Is(err, application.ErrAttestationReplayed),
+		strings.HasPrefix(message, "only verified agents can attest MCPs"),
+		message == "invalid attestation signature",
+		message == "attestation expired (older than 5 minutes)":
+		return status.Errorf(codes.PermissionDenied, "%s", message)
+	case message == "attestation has no mcp_url", strings.HasPrefix(message, "invalid agent_id"),
+		errors.Is(err, application.ErrInvalidAttestationCallback):
+		return status.Errorf(codes.InvalidArgument, "%s", message)
+	}
+	return err
+}
+
+// Heartbeat marks the agent active and reports its standing
+func (s *Server) Heartbeat(ctx context.Context, req *HeartbeatRequest) (*HeartbeatResponse, error) {
+	c, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	agent, err := s.callerAgent(ctx, c, req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.agentService.UpdateLastActive(ctx, agent.ID); err != nil {
+		return nil, fmt.Errorf("update last active: %w", err)
+	}
+
+	response := &HeartbeatResponse{
+		Status:     string(agent.Status),
+		TrustScore: agent.TrustScore,
+		ServerTime: time.Now().UTC().Format(time.RFC3339),
+	}
+	if req.RequestAttestationNonce {
+		if s.attestationService == nil {
+			return nil, status.Error(codes.Unimplemented, "attestations are not available")
+		}
+		nonce, err := s.attestationService.IssueAttestationNonce(ctx, agent.ID)
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "only verified agents can attest MCPs") {
+				return nil, status.Errorf(codes.PermissionDenied, "%s", err.Error())
+			}
+			return nil, err
+		}
+		response.AttestationNonce = nonce.Nonce
+		response.AttestationNonceExpiresAt = nonce.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	return response, nil
+}
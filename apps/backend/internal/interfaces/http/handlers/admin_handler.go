@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
@@ -78,7 +79,7 @@ func (h *AdminHandler) ListUsers(c fiber.Ctx) error {
 	}
 
 	// Get approved users
-	users, err := h.authService.GetUsersByOrganization(c.Context(), orgID)
+	users, err := h.authService.GetUsersByOrganization(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch users",
@@ -86,7 +87,7 @@ func (h *AdminHandler) ListUsers(c fiber.Ctx) error {
 	}
 
 	// Get pending registration requests (optional - table may not exist in all deployments)
-	pendingRequests, _, err := h.registrationService.ListPendingRegistrationRequests(c.Context(), orgID, 100, 0)
+	pendingRequests, _, err := h.registrationService.ListPendingRegistrationRequests(c.UserContext(), orgID, 100, 0)
 	if err != nil {
 		// ℹ️ If table doesn't exist or query fails, just show approved users
 		log.Printf("⚠️ Warning: Failed to fetch pending registration requests (table may not exist): %v", err)
@@ -158,7 +159,7 @@ func (h *AdminHandler) ListUsers(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionView,
@@ -219,8 +220,15 @@ func (h *AdminHandler) UpdateUserRole(c fiber.Ctx) error {
 		})
 	}
 
+	// users:manage may be held through a custom role; only admins make admins
+	if callerRole, _ := c.Locals("role").(string); role == domain.RoleAdmin && callerRole != string(domain.RoleAdmin) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only admins can grant the admin role",
+		})
+	}
+
 	// Update user role
-	user, err := h.authService.UpdateUserRole(c.Context(), targetUserID, orgID, role, adminID)
+	user, err := h.authService.UpdateUserRole(c.UserContext(), targetUserID, orgID, role, adminID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -229,7 +237,7 @@ func (h *AdminHandler) UpdateUserRole(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		adminID,
 		domain.AuditActionUpdate,
@@ -270,7 +278,7 @@ func (h *AdminHandler) DeactivateUser(c fiber.Ctx) error {
 
 	// Check if target user is the super admin (first admin user in the organization)
 	// Super admin is identified as the oldest admin user by created_at timestamp
-	isSuperAdmin, err := h.isSuperAdmin(c.Context(), targetUserID, orgID)
+	isSuperAdmin, err := h.isSuperAdmin(c.UserContext(), targetUserID, orgID)
 	if err != nil {
 		log.Printf("⚠️ Error checking super admin status: %v", err)
 	}
@@ -281,7 +289,7 @@ func (h *AdminHandler) DeactivateUser(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.authService.DeactivateUser(c.Context(), targetUserID, orgID, adminID); err != nil {
+	if err := h.authService.DeactivateUser(c.UserContext(), targetUserID, orgID, adminID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -289,7 +297,7 @@ func (h *AdminHandler) DeactivateUser(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		adminID,
 		domain.AuditActionUpdate,
@@ -320,7 +328,7 @@ func (h *AdminHandler) ActivateUser(c fiber.Ctx) error {
 	}
 
 	// Verify user belongs to the same organization
-	user, err := h.authService.GetUserByID(c.Context(), targetUserID)
+	user, err := h.authService.GetUserByID(c.UserContext(), targetUserID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "User not found",
@@ -334,7 +342,7 @@ func (h *AdminHandler) ActivateUser(c fiber.Ctx) error {
 	}
 
 	// Activate user using admin service
-	if err := h.adminService.ActivateUser(c.Context(), targetUserID, adminID); err != nil {
+	if err := h.adminService.ActivateUser(c.UserContext(), targetUserID, adminID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -342,7 +350,7 @@ func (h *AdminHandler) ActivateUser(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		adminID,
 		domain.AuditActionUpdate,
@@ -372,7 +380,7 @@ func (h *AdminHandler) PermanentlyDeleteUser(c fiber.Ctx) error {
 	}
 
 	// Verify user belongs to the same organization
-	user, err := h.authService.GetUserByID(c.Context(), targetUserID)
+	user, err := h.authService.GetUserByID(c.UserContext(), targetUserID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "User not found",
@@ -394,7 +402,7 @@ func (h *AdminHandler) PermanentlyDeleteUser(c fiber.Ctx) error {
 
 	// Check if target user is the super admin (first admin user in the organization)
 	// Super admin is identified as the oldest admin user by created_at timestamp
-	isSuperAdmin, err := h.isSuperAdmin(c.Context(), targetUserID, orgID)
+	isSuperAdmin, err := h.isSuperAdmin(c.UserContext(), targetUserID, orgID)
 	if err != nil {
 		log.Printf("⚠️ Error checking super admin status: %v", err)
 	}
@@ -410,7 +418,7 @@ func (h *AdminHandler) PermanentlyDeleteUser(c fiber.Ctx) error {
 	userName := user.Name
 
 	// Permanently delete user using admin service
-	if err := h.adminService.PermanentlyDeleteUser(c.Context(), targetUserID, adminID); err != nil {
+	if err := h.adminService.PermanentlyDeleteUser(c.UserContext(), targetUserID, adminID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -418,7 +426,7 @@ func (h *AdminHandler) PermanentlyDeleteUser(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		adminID,
 		domain.AuditActionDelete,
@@ -528,7 +536,7 @@ func (h *AdminHandler) GetAuditLogs(c fiber.Ctx) error {
 
 	// Get audit logs
 	logs, total, err := h.auditService.GetAuditLogs(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		filters.Action,
 		filters.EntityType,
@@ -574,7 +582,7 @@ func (h *AdminHandler) GetAuditLogs(c fiber.Ctx) error {
 	}
 
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionView,
@@ -645,7 +653,7 @@ func (h *AdminHandler) GetAlerts(c fiber.Ctx) error {
 
 	// Get alerts
 	alerts, total, err := h.alertService.GetAlerts(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		severity,
 		status,
@@ -659,7 +667,7 @@ func (h *AdminHandler) GetAlerts(c fiber.Ctx) error {
 	}
 
 	// Get alert counts (all, acknowledged, unacknowledged)
-	allCount, acknowledgedCount, unacknowledgedCount, err := h.alertService.CountUnacknowledged(c.Context(), orgID)
+	allCount, acknowledgedCount, unacknowledgedCount, err := h.alertService.CountUnacknowledged(c.UserContext(), orgID)
 	if err != nil {
 		// If count fails, set defaults but don't fail the request
 		allCount = total
@@ -684,7 +692,7 @@ func (h *AdminHandler) GetAlerts(c fiber.Ctx) error {
 	}
 
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionView,
@@ -717,7 +725,7 @@ func (h *AdminHandler) AcknowledgeAlert(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.alertService.AcknowledgeAlert(c.Context(), alertID, orgID, userID); err != nil {
+	if err := h.alertService.AcknowledgeAlert(c.UserContext(), alertID, orgID, userID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -725,7 +733,7 @@ func (h *AdminHandler) AcknowledgeAlert(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionAcknowledge,
@@ -769,7 +777,7 @@ func (h *AdminHandler) BulkAcknowledgeAlerts(c fiber.Ctx) error {
 		}
 	}
 
-	ackCount, err := h.alertService.BulkAcknowledgeAlerts(c.Context(), orgID, userID)
+	ackCount, err := h.alertService.BulkAcknowledgeAlerts(c.UserContext(), orgID, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -778,7 +786,7 @@ func (h *AdminHandler) BulkAcknowledgeAlerts(c fiber.Ctx) error {
 
 	// Log audit with metadata
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionAcknowledge,
@@ -819,7 +827,7 @@ func (h *AdminHandler) ResolveAlert(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.alertService.ResolveAlert(c.Context(), alertID, orgID, userID, req.Resolution); err != nil {
+	if err := h.alertService.ResolveAlert(c.UserContext(), alertID, orgID, userID, req.Resolution); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -827,7 +835,7 @@ func (h *AdminHandler) ResolveAlert(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionResolve,
@@ -849,7 +857,7 @@ func (h *AdminHandler) GetDashboardStats(c fiber.Ctx) error {
 	userID := c.Locals("user_id").(uuid.UUID)
 
 	// Get total agents
-	agents, err := h.agentService.ListAgents(c.Context(), orgID)
+	agents, err := h.agentService.ListAgents(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch agents",
@@ -857,7 +865,7 @@ func (h *AdminHandler) GetDashboardStats(c fiber.Ctx) error {
 	}
 
 	// Get total users
-	users, err := h.authService.GetUsersByOrganization(c.Context(), orgID)
+	users, err := h.authService.GetUsersByOrganization(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch users",
@@ -865,7 +873,7 @@ func (h *AdminHandler) GetDashboardStats(c fiber.Ctx) error {
 	}
 
 	// Get active alerts count
-	alerts, total, err := h.alertService.GetAlerts(c.Context(), orgID, "", "open", 1000, 0)
+	alerts, total, err := h.alertService.GetAlerts(c.UserContext(), orgID, "", "open", 1000, 0)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch alerts",
@@ -881,7 +889,7 @@ func (h *AdminHandler) GetDashboardStats(c fiber.Ctx) error {
 	}
 
 	// Get MCP servers from dedicated MCP service
-	mcpServersList, err := h.mcpService.ListMCPServers(c.Context(), orgID)
+	mcpServersList, err := h.mcpService.ListMCPServers(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch MCP servers",
@@ -926,7 +934,7 @@ func (h *AdminHandler) GetDashboardStats(c fiber.Ctx) error {
 	// Get security incidents count
 	securityIncidents := 0
 	if h.securityService != nil {
-		incidentCount, err := h.securityService.CountOpenIncidents(c.Context(), orgID)
+		incidentCount, err := h.securityService.CountOpenIncidents(c.UserContext(), orgID)
 		if err == nil {
 			securityIncidents = incidentCount
 		}
@@ -934,14 +942,14 @@ func (h *AdminHandler) GetDashboardStats(c fiber.Ctx) error {
 
 	// Get active users count (users who logged in within the last 60 minutes)
 	activeUsers := len(users) // Default to total users if count fails
-	activeUserCount, err := h.authService.CountActiveUsers(c.Context(), orgID, 60)
+	activeUserCount, err := h.authService.CountActiveUsers(c.UserContext(), orgID, 60)
 	if err == nil {
 		activeUsers = activeUserCount
 	}
 
 	// Log audit with dashboard metrics
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionView,
@@ -985,12 +993,47 @@ func (h *AdminHandler) GetDashboardStats(c fiber.Ctx) error {
 	})
 }
 
+// GetUsersBatch returns several users of the organization in one request
+// @Summary Batch get users
+// @Description Look up to 500 users by ID with a single query; IDs that are unknown or belong to another organization are listed in missing
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body BatchLookupRequest true "User IDs"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/users/batch [post]
+func (h *AdminHandler) GetUsersBatch(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	ids, err := parseBatchLookupIDs(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	users, err := h.authService.GetUsersByIDs(c.UserContext(), orgID, ids)
+	if err != nil {
+		return batchLookupError(c, err, "Failed to fetch users")
+	}
+
+	found := make(map[uuid.UUID]bool, len(users))
+	for _, user := range users {
+		found[user.ID] = true
+	}
+	return c.JSON(fiber.Map{
+		"users":   users,
+		"missing": missingBatchIDs(ids, found),
+	})
+}
+
 // GetPendingUsers returns users awaiting approval
 func (h *AdminHandler) GetPendingUsers(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 	userID := c.Locals("user_id").(uuid.UUID)
 
-	users, err := h.adminService.GetPendingUsers(c.Context(), orgID)
+	users, err := h.adminService.GetPendingUsers(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch pending users",
@@ -999,7 +1042,7 @@ func (h *AdminHandler) GetPendingUsers(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionView,
@@ -1029,7 +1072,7 @@ func (h *AdminHandler) ApproveUser(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.adminService.ApproveUser(c.Context(), targetUserID, adminID); err != nil {
+	if err := h.adminService.ApproveUser(c.UserContext(), targetUserID, adminID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -1037,7 +1080,7 @@ func (h *AdminHandler) ApproveUser(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		adminID,
 		domain.AuditActionUpdate,
@@ -1075,7 +1118,7 @@ func (h *AdminHandler) RejectUser(c fiber.Ctx) error {
 		req.Reason = ""
 	}
 
-	if err := h.adminService.RejectUser(c.Context(), targetUserID, adminID, req.Reason); err != nil {
+	if err := h.adminService.RejectUser(c.UserContext(), targetUserID, adminID, req.Reason); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -1083,7 +1126,7 @@ func (h *AdminHandler) RejectUser(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		adminID,
 		domain.AuditActionDelete,
@@ -1114,7 +1157,7 @@ func (h *AdminHandler) ApproveRegistrationRequest(c fiber.Ctx) error {
 	}
 
 	// Approve registration request
-	newUser, err := h.registrationService.ApproveRegistrationRequest(c.Context(), requestID, adminID, orgID)
+	newUser, err := h.registrationService.ApproveRegistrationRequest(c.UserContext(), requestID, adminID, orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to approve registration: %v", err),
@@ -1123,7 +1166,7 @@ func (h *AdminHandler) ApproveRegistrationRequest(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		adminID,
 		domain.AuditActionUpdate,
@@ -1165,7 +1208,7 @@ func (h *AdminHandler) RejectRegistrationRequest(c fiber.Ctx) error {
 	}
 
 	// Reject registration request
-	if err := h.registrationService.RejectRegistrationRequest(c.Context(), requestID, adminID, req.Reason); err != nil {
+	if err := h.registrationService.RejectRegistrationRequest(c.UserContext(), requestID, adminID, req.Reason); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to reject registration: %v", err),
 		})
@@ -1173,7 +1216,7 @@ func (h *AdminHandler) RejectRegistrationRequest(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		adminID,
 		domain.AuditActionDelete,
@@ -1223,7 +1266,7 @@ func (h *AdminHandler) GetOrganizationSettings(c fiber.Ctx) error {
 		})
 	}
 
-	org, err := h.adminService.GetOrganizationSettings(c.Context(), orgID)
+	org, err := h.adminService.GetOrganizationSettings(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch organization settings",
@@ -1232,7 +1275,7 @@ func (h *AdminHandler) GetOrganizationSettings(c fiber.Ctx) error {
 
 	// Log audit with settings viewed
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionView,
@@ -1246,14 +1289,125 @@ func (h *AdminHandler) GetOrganizationSettings(c fiber.Ctx) error {
 		},
 	)
 
-	return c.JSON(fiber.Map{
-		"id":        org.ID,
-		"name":      org.Name,
-		"domain":    org.Domain,
-		"maxAgents": org.MaxAgents,
-		"maxUsers":  org.MaxUsers,
-		"isActive":  org.IsActive,
-	})
+	return c.JSON(organizationSettingsResponse(org))
+}
+
+// UpdateOrganizationSettings updates organization settings
+// @Summary Update organization settings
+// @Description Update organization settings. autoRegisterAttestedMcps makes agent attestations of unregistered
+// @Description MCP URLs create pending servers for admin review instead of being rejected. clientSideKeyGeneration
+// @Description requires agents to register their own public key with a proof of possession and deletes escrowed private keys.
+// @Description mfaRequiredRoles lists the user roles that must sign in with a TOTP code.
+// @Description rateLimits sets the API rate limits (requestsPerMinute and burst) of the read, write and verification
+// @Description endpoint classes; each API key is limited separately. Changes apply within a minute.
+// @Description allowedKeyAlgorithms restricts agent keys to Ed25519, ECDSA-P256 and/or RSA-PSS; [] allows them all.
+// @Description inviteesRequireApproval makes users who accept an invitation wait for an admin's approval.
+// @Description requireAttestationNonce rejects MCP attestations without a nonce; while it is off, attestations from SDKs
+// @Description that predate nonces are accepted and logged.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body application.UpdateOrganizationSettingsRequest true "Settings to change"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/organization/settings [put]
+func (h *AdminHandler) UpdateOrganizationSettings(c fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Organization ID not found in context",
+		})
+	}
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User ID not found in context",
+		})
+	}
+
+	var req application.UpdateOrganizationSettingsRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	org, err := h.adminService.UpdateOrganizationSettings(c.UserContext(), orgID, &req)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidOrganizationSettings) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update organization settings",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"organization_settings",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"autoRegisterAttestedMcps": org.AutoRegisterAttestedMCPs,
+			"clientSideKeyGeneration":  org.ClientSideKeyGeneration,
+			"mfaRequiredRoles":         org.MFARequiredRoles,
+			"rateLimits":               domain.OrganizationRateLimits(org.Settings),
+			"allowedKeyAlgorithms":     org.AllowedKeyAlgorithms(),
+			"inviteesRequireApproval":  org.InviteesRequireApproval(),
+			"requireAttestationNonce":  org.RequiresAttestationNonce(),
+		},
+	)
+
+	return c.JSON(organizationSettingsResponse(org))
+}
+
+// GetKeyAlgorithmUsage reports the key algorithms of the organization's agents
+// @Summary Agent key algorithms
+// @Description Counts the organization's agents by key algorithm and lists the agents whose key uses an algorithm
+// @Description allowedKeyAlgorithms no longer allows. Their signatures are rejected until they rotate to an allowed algorithm.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} domain.KeyAlgorithmUsage
+// @Router /api/v1/admin/organization/key-algorithms [get]
+func (h *AdminHandler) GetKeyAlgorithmUsage(c fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Organization ID not found in context",
+		})
+	}
+
+	usage, err := h.agentService.KeyAlgorithmUsage(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch agent key algorithms",
+		})
+	}
+	return c.JSON(usage)
+}
+
+func organizationSettingsResponse(org *domain.Organization) fiber.Map {
+	return fiber.Map{
+		"id":                       org.ID,
+		"name":                     org.Name,
+		"domain":                   org.Domain,
+		"maxAgents":                org.MaxAgents,
+		"maxUsers":                 org.MaxUsers,
+		"isActive":                 org.IsActive,
+		"autoRegisterAttestedMcps": org.AutoRegisterAttestedMCPs,
+		"clientSideKeyGeneration":  org.ClientSideKeyGeneration,
+		"mfaRequiredRoles":         org.MFARequiredRoles,
+		"rateLimits":               domain.OrganizationRateLimits(org.Settings),
+		"allowedKeyAlgorithms":     org.AllowedKeyAlgorithms(),
+		"inviteesRequireApproval":  org.InviteesRequireApproval(),
+		"requireAttestationNonce":  org.RequiresAttestationNonce(),
+	}
 }
 
 // GetUnacknowledgedAlertCount returns the count of unacknowledged alerts for an organization
@@ -1274,7 +1428,7 @@ func (h *AdminHandler) GetUnacknowledgedAlertCount(c fiber.Ctx) error {
 	}
 
 	// Call alert service to count alerts
-	allCount, acknowledgedCount, unacknowledgedCount, err := h.alertService.CountUnacknowledged(c.Context(), orgID)
+	allCount, acknowledgedCount, unacknowledgedCount, err := h.alertService.CountUnacknowledged(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/url"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ========================================
+// Public Agent Badges and Status Pages
+// ========================================
+
+// badgeCacheControl lets browsers and image proxies cache badges briefly; the signed checkedAt
+// tells how fresh a copy is
+const badgeCacheControl = "public, max-age=300"
+
+// badgeSVG is a flat badge in the style of shields.io: the label on grey, the message on the
+// standing's color. Widths assume roughly 7 pixels per character of 11px Verdana.
+var badgeSVG = template.Must(template.New("badge").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{.Label}}: {{.Message}}">
+<title>{{.Label}}: {{.Message}}</title>
+<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="{{.Width}}" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="{{.LabelWidth}}" height="20" fill="#555"/><rect x="{{.LabelWidth}}" width="{{.MessageWidth}}" height="20" fill="{{.Color}}"/><rect width="{{.Width}}" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="{{.LabelX}}" y="14">{{.Label}}</text>
+<text x="{{.MessageX}}" y="14">{{.Message}}</text>
+</g>
+</svg>
+`))
+
+// statusPage is the public status page badges link to
+var statusPage = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Verification.DisplayName}} - Verified by OpenA2A</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; background: #f6f8fa; color: #1f2328; margin: 0; padding: 40px 16px; }
+main { max-width: 560px; margin: 0 auto; background: #fff; border: 1px solid #d0d7de; border-radius: 8px; padding: 32px; }
+h1 { font-size: 22px; margin: 0 0 4px; }
+.agent { color: #59636e; margin: 0 0 24px; font-family: monospace; }
+.standing { display: inline-block; color: #fff; background: {{.Color}}; border-radius: 4px; padding: 4px 10px; font-weight: 600; }
+dl { display: grid; grid-template-columns: max-content 1fr; gap: 8px 24px; margin: 24px 0; }
+dt { color: #59636e; }
+dd { margin: 0; }
+footer { color: #59636e; font-size: 12px; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Verification.DisplayName}}</h1>
+<p class="agent">{{.Verification.Agent}}</p>
+<span class="standing">{{.Message}}</span>
+<dl>
+<dt>Organization</dt><dd>{{.Verification.Organization}}</dd>
+<dt>Status</dt><dd>{{.Verification.Status}}</dd>
+<dt>Trust score</dt><dd>{{printf "%.2f" .Verification.TrustScore}} ({{.Verification.TrustTier}})</dd>
+<dt>Last verified</dt><dd>{{with .Verification.LastVerifiedAt}}{{.Format "2006-01-02 15:04 MST"}}{{else}}never{{end}}</dd>
+<dt>Checked</dt><dd>{{.Verification.CheckedAt.Format "2006-01-02 15:04:05 MST"}}</dd>
+</dl>
+<footer>Verified by OpenA2A Agent Identity Management. This page is signed with key {{.KeyID}}; a machine-readable, signed copy is at <a href="{{.JSONURL}}">badge.json</a>.</footer>
+</main>
+</body>
+</html>
+`))
+
+// renderBadgeSVG renders a badge with the given label, message and color
+func renderBadgeSVG(label, message, color string) ([]byte, error) {
+	labelWidth := utf8.RuneCountInString(label)*7 + 12
+	messageWidth := utf8.RuneCountInString(message)*7 + 12
+	var svg bytes.Buffer
+	err := badgeSVG.Execute(&svg, map[string]interface{}{
+		"Label":        label,
+		"Message":      message,
+		"Color":        color,
+		"Width":        labelWidth + messageWidth,
+		"LabelWidth":   labelWidth,
+		"MessageWidth": messageWidth,
+		"LabelX":       labelWidth / 2,
+		"MessageX":     labelWidth + messageWidth/2,
+	})
+	return svg.Bytes(), err
+}
+
+// agentReference returns the <org-domain>/<agent-name> reference of the agent in the path
+func agentReference(c fiber.Ctx) string {
+	orgDomain, _ := url.PathUnescape(c.Params("domain"))
+	name, _ := url.PathUnescape(c.Params("name"))
+	return orgDomain + "/" + name
+}
+
+// GetAgentBadgeSVG returns the embeddable badge of a publicly discoverable agent
+// @Summary Agent verification badge (SVG)
+// @Description Embed as <a href="{statusUrl}"><img src="/public/v1/agents/{domain}/{name}/badge.svg"></a>. Unlisted and missing agents get a grey "not found" badge with status 404.
+// @Description The body is signed: X-AIM-Signature is its base64 Ed25519 signature with the key of /public/v1/signing-key.
+// @Tags public
+// @Produce image/svg+xml
+// @Param domain path string true "Organization domain"
+// @Param name path string true "Agent name"
+// @Success 200 {string} string "SVG badge"
+// @Failure 404 {string} string "SVG badge"
+// @Router /public/v1/agents/{domain}/{name}/badge.svg [get]
+func (h *AgentListingHandler) GetAgentBadgeSVG(c fiber.Ctx) error {
+	status := fiber.StatusOK
+	label, message, color := "OpenA2A", "not found", domain.BadgeColorNotVerified
+	badge, err := h.listingService.Badge(c.UserContext(), agentReference(c))
+	switch {
+	case err == nil:
+		label, message, color = badge.Label, badge.Message, badge.Color
+	case errors.Is(err, application.ErrPublicAgentNotFound), errors.Is(err, application.ErrInvalidAgentReference):
+		status = fiber.StatusNotFound
+	default:
+		return publicAgentError(c, err)
+	}
+
+	svg, err := renderBadgeSVG(label, message, color)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to render badge",
+		})
+	}
+
+	c.Set(fiber.HeaderCacheControl, badgeCacheControl)
+	return h.sendSigned(c.Status(status), "image/svg+xml; charset=utf-8", svg)
+}
+
+// GetAgentBadgeJSON returns the badge of a publicly discoverable agent with its verification standing
+// @Summary Agent verification badge (JSON)
+// @Description For sites rendering the badge themselves. Can be fetched from any origin. The body is signed like the SVG badge's.
+// @Tags public
+// @Produce json
+// @Param domain path string true "Organization domain"
+// @Param name path string true "Agent name"
+// @Success 200 {object} domain.PublicAgentBadge
+// @Failure 404 {object} map[string]interface{}
+// @Router /public/v1/agents/{domain}/{name}/badge.json [get]
+func (h *AgentListingHandler) GetAgentBadgeJSON(c fiber.Ctx) error {
+	badge, err := h.listingService.Badge(c.UserContext(), agentReference(c))
+	if err != nil {
+		return publicAgentError(c, err)
+	}
+
+	body, err := json.Marshal(badge)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to encode badge",
+		})
+	}
+
+	c.Set(fiber.HeaderCacheControl, badgeCacheControl)
+	c.Set(fiber.HeaderAccessControlAllowOrigin, "*")
+	return h.sendSigned(c, fiber.MIMEApplicationJSONCharsetUTF8, body)
+}
+
+// GetAgentStatusPage returns the public status page of a publicly discoverable agent
+// @Summary Agent status page
+// @Description The page badges link to, showing the agent's verification status, trust tier and last verification. The body is signed like the SVG badge's.
+// @Tags public
+// @Produce html
+// @Param domain path string true "Organization domain"
+// @Param name path string true "Agent name"
+// @Success 200 {string} string "HTML page"
+// @Failure 404 {object} map[string]interface{}
+// @Router /public/v1/agents/{domain}/{name} [get]
+func (h *AgentListingHandler) GetAgentStatusPage(c fiber.Ctx) error {
+	badge, err := h.listingService.Badge(c.UserContext(), agentReference(c))
+	if err != nil {
+		return publicAgentError(c, err)
+	}
+
+	var page bytes.Buffer
+	if err := statusPage.Execute(&page, map[string]interface{}{
+		"Verification": badge.Verification,
+		"Message":      badge.Message,
+		"Color":        template.CSS(badge.Color),
+		"KeyID":        h.signer.KeyID(),
+		"JSONURL":      badge.StatusURL + "/badge.json",
+	}); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to render status page",
+		})
+	}
+
+	c.Set(fiber.HeaderCacheControl, badgeCacheControl)
+	return h.sendSigned(c, fiber.MIMETextHTMLCharsetUTF8, page.Bytes())
+}
+
+// GetSigningKey returns the public key the responses about publicly discoverable agents are signed with
+// @Summary Public status signing key
+// @Description Verify the X-AIM-Signature header of a response as the base64 Ed25519 signature of its body with this key. X-AIM-Signature-Key-Id names the key it was made with.
+// @Tags public
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /public/v1/signing-key [get]
+func (h *AgentListingHandler) GetSigningKey(c fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"algorithm": "Ed25519",
+		"keyId":     h.signer.KeyID(),
+		"publicKey": h.signer.PublicKey(),
+	})
+}
+
+// sendSigned sends the body with its signature, so copies of it can be told from forgeries
+func (h *AgentListingHandler) sendSigned(c fiber.Ctx, contentType string, body []byte) error {
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(crypto.PublicStatusSignatureHeader, h.signer.Sign(body))
+	c.Set(crypto.PublicStatusKeyIDHeader, h.signer.KeyID())
+	return c.Send(body)
+}
+
+// publicAgentError maps the errors of public agent lookups; unlisted and missing agents are
+// indistinguishable
+func publicAgentError(c fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, application.ErrInvalidAgentReference):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrPublicAgentNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Agent not found or not publicly discoverable",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Failed to look up agent",
+	})
+}
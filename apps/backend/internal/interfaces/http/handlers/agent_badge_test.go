@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicAgentBadgesAndStatusPagesAreSigned(t *testing.T) {
+	ctx := context.Background()
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	manager := testsupport.NewUser(org.ID)
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) {
+		verifiedAt := time.Now().Add(-time.Hour)
+		a.VerifiedAt = &verifiedAt
+		a.TrustScore = 0.93
+		a.DisplayName = "Billing <Agent>"
+	})
+	require.NoError(t, repos.Agent.Create(agent))
+
+	listings := application.NewAgentListingService(repos.AgentListing, repos.Agent, repos.Organization, "https://aim.example.com")
+	_, err := listings.List(ctx, org.ID, agent.ID, manager.ID)
+	require.NoError(t, err)
+	signer, err := crypto.NewPublicStatusSigner(bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+	handler := NewAgentListingHandler(listings, nil, signer)
+
+	app := fiber.New()
+	app.Get("/public/v1/verify", handler.VerifyPublicAgent)
+	app.Get("/public/v1/signing-key", handler.GetSigningKey)
+	app.Get("/public/v1/agents/:domain/:name/badge.svg", handler.GetAgentBadgeSVG)
+	app.Get("/public/v1/agents/:domain/:name/badge.json", handler.GetAgentBadgeJSON)
+	app.Get("/public/v1/agents/:domain/:name", handler.GetAgentStatusPage)
+	get := func(path string) (*http.Response, []byte) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), 5*time.Second)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+
+	_, body := get("/public/v1/signing-key")
+	var key struct {
+		Algorithm, KeyID, PublicKey string
+	}
+	require.NoError(t, json.Unmarshal(body, &key))
+	assert.Equal(t, "Ed25519", key.Algorithm)
+	assert.Equal(t, signer.KeyID(), key.KeyID)
+	verifies := func(resp *http.Response, body []byte) bool {
+		assert.Equal(t, key.KeyID, resp.Header.Get(crypto.PublicStatusKeyIDHeader))
+		return crypto.VerifyPublicStatus(key.PublicKey, body, resp.Header.Get(crypto.PublicStatusSignatureHeader))
+	}
+
+	base := "/public/v1/agents/" + org.Domain + "/" + agent.Name
+
+	// The JSON badge carries the standing and the status page it links to
+	resp, body := get(base + "/badge.json")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.True(t, verifies(resp, body))
+	assert.Equal(t, "*", resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	var badge domain.PublicAgentBadge
+	require.NoError(t, json.Unmarshal(body, &badge))
+	assert.Equal(t, "verified | excellent trust", badge.Message)
+	assert.Equal(t, domain.BadgeColorExcellent, badge.Color)
+	assert.Equal(t, "https://aim.example.com"+base, badge.StatusURL)
+	assert.Equal(t, org.Domain+"/"+agent.Name, badge.Verification.Agent)
+
+	// An edited body no longer verifies
+	assert.False(t, crypto.VerifyPublicStatus(key.PublicKey, bytes.Replace(body, []byte("excellent"), []byte("EXCELLENT"), 1), resp.Header.Get(crypto.PublicStatusSignatureHeader)))
+
+	resp, body = get(base + "/badge.svg")
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.True(t, verifies(resp, body))
+	assert.Contains(t, resp.Header.Get(fiber.HeaderContentType), "image/svg+xml")
+	assert.Contains(t, string(body), "verified | excellent trust")
+	assert.Contains(t, string(body), domain.BadgeColorExcellent)
+
+	// The status page escapes what the organization named the agent
+	resp, body = get(base)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.True(t, verifies(resp, body))
+	assert.Contains(t, string(body), "Billing &lt;Agent&gt;")
+	assert.NotContains(t, string(body), "<Agent>")
+
+	resp, body = get("/public/v1/verify?agent=" + org.Domain + "/" + agent.Name)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.True(t, verifies(resp, body))
+
+	// Compromised agents keep their badge, in red
+	require.NoError(t, repos.Agent.MarkAsCompromised(agent.ID))
+	_, body = get(base + "/badge.json")
+	require.NoError(t, json.Unmarshal(body, &badge))
+	assert.Equal(t, "compromised", badge.Message)
+	assert.Equal(t, domain.BadgeColorCompromised, badge.Color)
+
+	// Unlisted agents get a grey "not found" badge for the image and 404s elsewhere
+	require.NoError(t, listings.Unlist(ctx, org.ID, agent.ID))
+	resp, body = get(base + "/badge.svg")
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	assert.True(t, verifies(resp, body))
+	assert.Contains(t, string(body), "not found")
+	resp, _ = get(base + "/badge.json")
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	resp, _ = get(base)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
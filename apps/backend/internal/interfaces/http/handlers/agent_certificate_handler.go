@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type AgentCertificateHandler struct {
+	certificateService *application.AgentCertificateService
+}
+
+func NewAgentCertificateHandler(certificateService *application.AgentCertificateService) *AgentCertificateHandler {
+	return &AgentCertificateHandler{
+		certificateService: certificateService,
+	}
+}
+
+// GetAgentCertificate returns the agent's current X.509 certificate
+// @Summary Get agent certificate
+// @Description Short-lived X.509 client certificate binding the verified agent to its Ed25519 public key. Renewed when it nears expiry or the agent's key changed. Returns PEM with format=pem.
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param format query string false "pem for the PEM-encoded certificate only"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/certificate [get]
+func (h *AgentCertificateHandler) GetAgentCertificate(c fiber.Ctx) error {
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	certificate, err := h.certificateService.GetCurrent(c.UserContext(), c.Locals("organization_id").(uuid.UUID), agentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrCertificateAgentNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Agent not found",
+			})
+		case errors.Is(err, domain.ErrAgentCertificateNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Agent has no certificate; certificates are issued once the agent is verified",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get agent certificate",
+		})
+	}
+
+	if c.Query("format") == "pem" {
+		c.Set(fiber.HeaderContentType, "application/x-pem-file")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="agent-%s.pem"`, agentID))
+		return c.SendString(certificate.CertificatePEM)
+	}
+
+	return c.JSON(fiber.Map{
+		"certificate":      certificate,
+		"status":           certificate.Status(time.Now()),
+		"caCertificatePem": string(h.certificateService.CACertificatePEM()),
+	})
+}
+
+// GetCACertificate returns the certificate of the CA that issues agent certificates
+// @Summary Get agent CA certificate
+// @Description PEM-encoded certificate of the platform CA; relying parties trust it to validate agent certificates
+// @Tags public
+// @Produce application/x-pem-file
+// @Success 200 {string} string
+// @Router /api/v1/public/agent-ca/certificate [get]
+func (h *AgentCertificateHandler) GetCACertificate(c fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, "application/x-pem-file")
+	return c.Send(h.certificateService.CACertificatePEM())
+}
+
+// GetCRL returns the certificate revocation list of agent certificates
+// @Summary Get agent certificate revocation list
+// @Description DER-encoded X.509 CRL of agent certificates revoked before their expiry, signed by the agent CA
+// @Tags public
+// @Produce application/pkix-crl
+// @Success 200 {string} string
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/public/agent-ca/crl [get]
+func (h *AgentCertificateHandler) GetCRL(c fiber.Ctx) error {
+	crl, err := h.certificateService.CRL(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create certificate revocation list",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pkix-crl")
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Send(crl)
+}
+
+// GetCertificateStatus returns the revocation status of one agent certificate
+// @Summary Get agent certificate status
+// @Description OCSP-style status lookup: good, revoked (with time and reason) or unknown
+// @Tags public
+// @Produce json
+// @Param serial path string true "Hex-encoded certificate serial number"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/public/agent-ca/certificates/{serial}/status [get]
+func (h *AgentCertificateHandler) GetCertificateStatus(c fiber.Ctx) error {
+	serial := c.Params("serial")
+	certificate, err := h.certificateService.GetBySerialNumber(c.UserContext(), serial)
+	if err != nil {
+		if errors.Is(err, domain.ErrAgentCertificateNotFound) {
+			return c.JSON(fiber.Map{
+				"serialNumber": serial,
+				"status":       "unknown",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get certificate status",
+		})
+	}
+
+	response := fiber.Map{
+		"serialNumber": certificate.SerialNumber,
+		"status":       "good",
+		"notAfter":     certificate.NotAfter,
+		"producedAt":   time.Now().UTC(),
+	}
+	if certificate.RevokedAt != nil {
+		response["status"] = "revoked"
+		response["revokedAt"] = certificate.RevokedAt
+		response["revocationReason"] = certificate.RevocationReason
+	}
+	return c.JSON(response)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type AgentCredentialHandler struct {
+	credentialService *application.AgentCredentialService
+	auditService      *application.AuditService
+}
+
+func NewAgentCredentialHandler(
+	credentialService *application.AgentCredentialService,
+	auditService *application.AuditService,
+) *AgentCredentialHandler {
+	return &AgentCredentialHandler{
+		credentialService: credentialService,
+		auditService:      auditService,
+	}
+}
+
+// VerifyCredentialRequest carries the VC-JWT a third party was presented
+type VerifyCredentialRequest struct {
+	JWT string `json:"jwt"`
+}
+
+// IssueCredential issues a verifiable credential to the agent
+// @Summary Issue agent verifiable credential
+// @Description Signs a W3C verifiable credential (VC-JWT, EdDSA) asserting the agent's identity, verification status, trust score band and capabilities. Only verified agents that are not compromised get credentials; they are valid for 24 hours.
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 201 {object} domain.IssuedAgentCredential
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/credentials [post]
+func (h *AgentCredentialHandler) IssueCredential(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	issued, err := h.credentialService.Issue(c.UserContext(), orgID, agentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrCredentialAgentNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Agent not found",
+			})
+		case errors.Is(err, application.ErrAgentNotCredentialable):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to issue credential",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionGenerate,
+		"agent_credential",
+		agentID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"credential_id":    issued.Credential.ID,
+			"trust_score_band": issued.Credential.CredentialSubject.TrustScoreBand,
+			"expires_at":       issued.Credential.ExpirationDate,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(issued)
+}
+
+// VerifyCredential checks a credential an agent presented
+// @Summary Verify agent verifiable credential
+// @Description Unauthenticated check for third parties: the credential's signature and validity period, and that the agent has not since been revoked, suspended or marked compromised. Credentials can also be checked offline against the key of the issuer's DID document (/.well-known/did.json).
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param request body VerifyCredentialRequest true "Presented credential"
+// @Success 200 {object} domain.AgentCredentialVerification
+// @Failure 400 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /api/v1/public/credentials/verify [post]
+func (h *AgentCredentialHandler) VerifyCredential(c fiber.Ctx) error {
+	var req VerifyCredentialRequest
+	if err := c.Bind().JSON(&req); err != nil || req.JWT == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "jwt is required",
+		})
+	}
+
+	return c.JSON(h.credentialService.Verify(c.UserContext(), req.JWT))
+}
+
+// GetDIDDocument returns the DID document of the credential issuer
+// @Summary Credential issuer DID document
+// @Description The did:web document carrying the public key agent credentials are signed with
+// @Tags public
+// @Produce json
+// @Success 200 {object} domain.DIDDocument
+// @Router /.well-known/did.json [get]
+func (h *AgentCredentialHandler) GetDIDDocument(c fiber.Ctx) error {
+	return c.JSON(h.credentialService.DIDDocument())
+}
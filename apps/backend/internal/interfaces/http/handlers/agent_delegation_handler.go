@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type AgentDelegationHandler struct {
+	delegationService *application.AgentDelegationService
+	auditService      *application.AuditService
+}
+
+func NewAgentDelegationHandler(
+	delegationService *application.AgentDelegationService,
+	auditService *application.AuditService,
+) *AgentDelegationHandler {
+	return &AgentDelegationHandler{
+		delegationService: delegationService,
+		auditService:      auditService,
+	}
+}
+
+// DelegateAgent creates a scoped child agent of the agent
+// @Summary Delegate a sub-agent
+// @Description Creates a child identity of the agent. The child's capabilities must be covered by the parent's and its MCP servers must be ones the parent talks to. The child starts with the parent's trust score minus a penalty and is revoked whenever the parent is.
+// @Description Agents authenticated with Ed25519 or an agent API key may only delegate from themselves; users need the member role.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param id path string true "Parent agent ID"
+// @Param request body application.DelegateAgentRequest true "Child agent"
+// @Success 201 {object} application.AgentDelegationResult
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/delegations [post]
+func (h *AgentDelegationHandler) DelegateAgent(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	parentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	var req application.DelegateAgentRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// An agent requesting a child for itself acts on behalf of no user
+	requestedBy := uuid.Nil
+	if agentID, ok := c.Locals("agent_id").(uuid.UUID); ok && agentID != uuid.Nil {
+		if agentID != parentID {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Agents may only delegate from themselves",
+			})
+		}
+	} else if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		if role, _ := c.Locals("role").(string); role == string(domain.RoleViewer) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Member access required (viewers cannot perform this action)",
+			})
+		}
+		requestedBy = userID
+	}
+
+	result, err := h.delegationService.Delegate(c.UserContext(), orgID, parentID, &req, requestedBy)
+	if err != nil {
+		return delegationError(c, err)
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		result.Delegation.CreatedBy,
+		domain.AuditActionCreate,
+		"agent_delegation",
+		result.Agent.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"parent_agent_id": parentID,
+			"depth":           result.Delegation.Depth,
+			"capabilities":    result.Delegation.Capabilities,
+			"requested_by":    result.Delegation.RequestedBy,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+// GetDelegations returns the agent's delegation chain and the agents it delegated
+// @Summary Get agent delegation chain
+// @Description The delegations from the root agent down to the agent, and the child agents it delegated
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 200 {object} domain.AgentDelegationChain
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/delegations [get]
+func (h *AgentDelegationHandler) GetDelegations(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	chain, err := h.delegationService.GetChain(c.UserContext(), orgID, agentID)
+	if err != nil {
+		return delegationError(c, err)
+	}
+
+	return c.JSON(chain)
+}
+
+// RevokeAgent revokes the agent and every agent delegated below it
+// @Summary Revoke agent
+// @Description Permanently revokes the agent and, transitively, every child agent it delegated. Revoked agents fail verification, lose their certificates and can't be verified or reactivated again.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param request body object false "Optional reason"
+// @Success 200 {object} application.AgentRevocation
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/revoke [post]
+func (h *AgentDelegationHandler) RevokeAgent(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	revocation, err := h.delegationService.Revoke(c.UserContext(), orgID, agentID, req.Reason)
+	if err != nil {
+		return delegationError(c, err)
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionRevoke,
+		"agent",
+		agentID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"reason":            req.Reason,
+			"revoked_agent_ids": revocation.RevokedAgentIDs,
+		},
+	)
+
+	return c.JSON(revocation)
+}
+
+// delegationError responds with the HTTP status matching an agent delegation service error
+func delegationError(c fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, application.ErrDelegationAgentNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrDelegationScopeExceeded):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrDelegationNotAllowed):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		return registrationErrorResponse(c, err)
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/gofiber/fiber/v3"
@@ -20,6 +21,7 @@ type AgentHandler struct {
 	alertService             *application.AlertService
 	verificationEventService *application.VerificationEventService
 	capabilityService        *application.CapabilityService
+	tombstoneService         *application.TombstoneService
 }
 
 func NewAgentHandler(
@@ -31,6 +33,7 @@ func NewAgentHandler(
 	alertService *application.AlertService,
 	verificationEventService *application.VerificationEventService,
 	capabilityService *application.CapabilityService,
+	tombstoneService *application.TombstoneService,
 ) *AgentHandler {
 	return &AgentHandler{
 		agentService:             agentService,
@@ -41,12 +44,13 @@ func NewAgentHandler(
 		alertService:             alertService,
 		verificationEventService: verificationEventService,
 		capabilityService:        capabilityService,
+		tombstoneService:         tombstoneService,
 	}
 }
 
 func (h *AgentHandler) enrichAgentResponse(c fiber.Ctx, agent *domain.Agent) fiber.Map {
 	// Fetch capabilities from agent_capabilities table
-	capabilities, err := h.capabilityService.GetAgentCapabilities(c.Context(), agent.ID, true)
+	capabilities, err := h.capabilityService.GetAgentCapabilities(c.UserContext(), agent.ID, true)
 	if err != nil {
 		// Log error but don't fail - return empty capabilities
 		capabilities = []*domain.AgentCapability{}
@@ -89,11 +93,11 @@ func (h *AgentHandler) enrichAgentResponse(c fiber.Ctx, agent *domain.Agent) fib
 	}
 }
 
-// ListAgents returns all agents for the organization
+// ListAgents returns all agents for the organization, cut to the response budget
 func (h *AgentHandler) ListAgents(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 
-	agents, err := h.agentService.ListAgents(c.Context(), orgID)
+	agents, err := h.agentService.ListAgents(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch agents",
@@ -103,13 +107,59 @@ func (h *AgentHandler) ListAgents(c fiber.Ctx) error {
 	for _, agent := range agents {
 		enriched = append(enriched, h.enrichAgentResponse(c, agent))
 	}
+	return budgetedList(c, "agents", enriched)
+}
+
+// GetAgentsBatch returns several agents of the organization in one request
+// @Summary Batch get agents
+// @Description Look up to 500 agents by ID with a single query; IDs that are unknown or belong to another organization are listed in missing
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param request body BatchLookupRequest true "Agent IDs"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/agents/batch [post]
+func (h *AgentHandler) GetAgentsBatch(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	ids, err := parseBatchLookupIDs(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	agents, err := h.agentService.GetAgentsByIDs(c.UserContext(), orgID, ids)
+	if err != nil {
+		return batchLookupError(c, err, "Failed to fetch agents")
+	}
+
+	found := make(map[uuid.UUID]bool, len(agents))
+	for _, agent := range agents {
+		found[agent.ID] = true
+	}
 	return c.JSON(fiber.Map{
-		"agents": enriched,
-		"total":  len(enriched),
+		"agents":  agents,
+		"missing": missingBatchIDs(ids, found),
 	})
 }
 
 // CreateAgent creates a new agent
+// @Summary Create agent
+// @Description Register a new agent. With validate=true every registration check (name uniqueness, agent quota, capability taxonomy, key format) runs and the would-be agent is returned without being persisted; failed validation returns 422.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param validate query bool false "Validate only, do not create the agent"
+// @Param request body application.CreateAgentRequest true "Agent details"
+// @Success 201 {object} domain.Agent
+// @Success 200 {object} map[string]interface{} "validate=true and every check passed"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{} "Agent quota reached"
+// @Failure 409 {object} map[string]interface{} "Agent name already taken"
+// @Failure 422 {object} map[string]interface{} "validate=true and a check failed"
+// @Router /api/v1/agents [post]
 func (h *AgentHandler) CreateAgent(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 	userID := c.Locals("user_id").(uuid.UUID)
@@ -122,18 +172,27 @@ func (h *AgentHandler) CreateAgent(c fiber.Ctx) error {
 		})
 	}
 
-	agent, err := h.agentService.CreateAgent(c.Context(), &req, orgID, userID)
+	// Dry run: validate only, nothing is persisted or audited
+	if isRegistrationDryRun(c) {
+		agent, validation, err := h.agentService.ValidateAgentRegistration(c.UserContext(), &req, orgID, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to validate agent registration",
+			})
+		}
+		return registrationDryRunResponse(c, validation, "agent", agent)
+	}
+
+	agent, err := h.agentService.CreateAgent(c.UserContext(), &req, orgID, userID)
 	if err != nil {
 		// Log the full error for debugging
 		fmt.Printf("ERROR creating agent: %v\n", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return registrationErrorResponse(c, err)
 	}
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionCreate,
@@ -160,7 +219,7 @@ func (h *AgentHandler) GetAgent(c fiber.Ctx) error {
 		})
 	}
 
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -197,7 +256,7 @@ func (h *AgentHandler) UpdateAgent(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization first
-	existingAgent, err := h.agentService.GetAgent(c.Context(), agentID)
+	existingAgent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -209,7 +268,7 @@ func (h *AgentHandler) UpdateAgent(c fiber.Ctx) error {
 		})
 	}
 
-	agent, err := h.agentService.UpdateAgent(c.Context(), agentID, &req)
+	agent, err := h.agentService.UpdateAgent(c.UserContext(), agentID, &req)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -218,7 +277,7 @@ func (h *AgentHandler) UpdateAgent(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionUpdate,
@@ -246,7 +305,7 @@ func (h *AgentHandler) DeleteAgent(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization first
-	existingAgent, err := h.agentService.GetAgent(c.Context(), agentID)
+	existingAgent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -258,7 +317,11 @@ func (h *AgentHandler) DeleteAgent(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.agentService.DeleteAgent(c.Context(), agentID); err != nil {
+	// Keep a tombstone so old events and incidents still resolve the agent
+	err = h.tombstoneService.DeleteWithTombstone(c.UserContext(), domain.TombstoneEntityAgent, agentID, userID, func() error {
+		return h.agentService.DeleteAgent(c.UserContext(), agentID)
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -266,7 +329,7 @@ func (h *AgentHandler) DeleteAgent(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionDelete,
@@ -292,7 +355,7 @@ func (h *AgentHandler) VerifyAgent(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization first
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -304,18 +367,36 @@ func (h *AgentHandler) VerifyAgent(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.agentService.VerifyAgent(c.Context(), agentID); err != nil {
+	approval, err := h.agentService.VerifyAgent(c.UserContext(), agentID, userID)
+	if err != nil {
+		if errors.Is(err, application.ErrAgentRevoked) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if status := approvalErrorStatus(err); status != 0 {
+			return c.Status(status).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
+	if approvalPending(approval) {
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"verified":        false,
+			"message":         "verification recorded; further approvals are required",
+			"approvalRequest": approval,
+		})
+	}
 
 	// Get updated agent to return in response
-	agent, _ = h.agentService.GetAgent(c.Context(), agentID)
+	agent, _ = h.agentService.GetAgent(c.UserContext(), agentID)
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionVerify,
@@ -370,7 +451,7 @@ func (h *AgentHandler) VerifyAction(c fiber.Ctx) error {
 	}
 
 	// Get agent and organization details for logging
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -382,7 +463,7 @@ func (h *AgentHandler) VerifyAction(c fiber.Ctx) error {
 
 	// Fetch agent and verify capabilities
 	decision, reason, auditID, err := h.agentService.VerifyAction(
-		c.Context(),
+		c.UserContext(),
 		agentID,
 		req.ActionType,
 		req.Resource,
@@ -418,7 +499,7 @@ func (h *AgentHandler) VerifyAction(c fiber.Ctx) error {
 	}
 
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionVerify,
@@ -458,7 +539,7 @@ func (h *AgentHandler) VerifyAction(c fiber.Ctx) error {
 	}
 
 	h.verificationEventService.LogVerificationEvent(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		agentID,
 		protocol, // SDK auto-detects protocol or user explicitly declares in secure()
@@ -492,7 +573,7 @@ func (h *AgentHandler) VerifyAction(c fiber.Ctx) error {
 		}
 
 		// Create alert (non-blocking - don't fail the verification if alert creation fails)
-		if err := h.alertService.CreateAlert(c.Context(), alert); err != nil {
+		if err := h.alertService.CreateAlert(c.UserContext(), alert); err != nil {
 			fmt.Printf("WARNING: Failed to create security alert for capability violation: %v\n", err)
 		}
 	}
@@ -501,7 +582,7 @@ func (h *AgentHandler) VerifyAction(c fiber.Ctx) error {
 	// Update last_active regardless of whether action was allowed or denied
 	// This helps track when agents were last seen attempting actions
 	fmt.Printf("🔄 Updating last_active for agent %s...\n", agentID)
-	if err := h.agentService.UpdateLastActive(c.Context(), agentID); err != nil {
+	if err := h.agentService.UpdateLastActive(c.UserContext(), agentID); err != nil {
 		// Log but don't fail the request if timestamp update fails
 		fmt.Printf("❌ WARNING: Failed to update agent last_active: %v\n", err)
 	} else {
@@ -561,7 +642,7 @@ func (h *AgentHandler) LogActionResult(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.agentService.LogActionResult(c.Context(), agentID, auditID, req.Success, req.Error, req.Result); err != nil {
+	if err := h.agentService.LogActionResult(c.UserContext(), agentID, auditID, req.Success, req.Error, req.Result); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to log action result",
 		})
@@ -582,6 +663,7 @@ func (h *AgentHandler) LogActionResult(c fiber.Ctx) error {
 // @Success 200 {file} binary "SDK package as zip file"
 // @Failure 400 {object} ErrorResponse "Invalid agent ID or language"
 // @Failure 404 {object} ErrorResponse "Agent not found"
+// @Failure 409 {object} ErrorResponse "Private key held client-side"
 // @Router /agents/{id}/sdk [get]
 func (h *AgentHandler) DownloadSDK(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
@@ -601,7 +683,7 @@ func (h *AgentHandler) DownloadSDK(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -614,7 +696,12 @@ func (h *AgentHandler) DownloadSDK(c fiber.Ctx) error {
 	}
 
 	// Get agent credentials (decrypts private key)
-	publicKey, privateKey, err := h.agentService.GetAgentCredentials(c.Context(), agentID)
+	publicKey, privateKey, err := h.agentService.GetAgentCredentials(c.UserContext(), agentID)
+	if errors.Is(err, application.ErrPrivateKeyNotEscrowed) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "The agent's private key was generated client-side and is not held by AIM; configure the SDK with the agent's own key",
+		})
+	}
 	if err != nil {
 		fmt.Println(err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -665,7 +752,7 @@ func (h *AgentHandler) DownloadSDK(c fiber.Ctx) error {
 	// Log audit
 	userID := c.Locals("user_id").(uuid.UUID)
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionView,
@@ -692,6 +779,7 @@ func (h *AgentHandler) DownloadSDK(c fiber.Ctx) error {
 // @Failure 400 {object} ErrorResponse "Invalid agent ID"
 // @Failure 404 {object} ErrorResponse "Agent not found"
 // @Failure 403 {object} ErrorResponse "Access denied"
+// @Failure 409 {object} ErrorResponse "Private key held client-side"
 // @Router /agents/{id}/credentials [get]
 func (h *AgentHandler) GetCredentials(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
@@ -704,7 +792,7 @@ func (h *AgentHandler) GetCredentials(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -717,7 +805,12 @@ func (h *AgentHandler) GetCredentials(c fiber.Ctx) error {
 	}
 
 	// Get agent credentials (decrypts private key)
-	publicKey, privateKey, err := h.agentService.GetAgentCredentials(c.Context(), agentID)
+	publicKey, privateKey, err := h.agentService.GetAgentCredentials(c.UserContext(), agentID)
+	if errors.Is(err, application.ErrPrivateKeyNotEscrowed) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "The agent's private key was generated client-side and is not held by AIM",
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve agent credentials",
@@ -726,7 +819,7 @@ func (h *AgentHandler) GetCredentials(c fiber.Ctx) error {
 
 	// Log audit - viewing credentials is a sensitive action
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionView,
@@ -809,7 +902,7 @@ func (h *AgentHandler) AddMCPServersToAgent(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -823,7 +916,7 @@ func (h *AgentHandler) AddMCPServersToAgent(c fiber.Ctx) error {
 
 	// Add MCP servers to agent's talks_to list
 	updatedAgent, addedServers, err := h.agentService.AddMCPServers(
-		c.Context(),
+		c.UserContext(),
 		agentID,
 		req.MCPServerIDs,
 	)
@@ -841,7 +934,7 @@ func (h *AgentHandler) AddMCPServersToAgent(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		auditUserID,
 		domain.AuditActionUpdate,
@@ -895,7 +988,7 @@ func (h *AgentHandler) RemoveMCPServerFromAgent(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -909,7 +1002,7 @@ func (h *AgentHandler) RemoveMCPServerFromAgent(c fiber.Ctx) error {
 
 	// Remove MCP server from agent's talks_to list
 	updatedAgent, err := h.agentService.RemoveMCPServer(
-		c.Context(),
+		c.UserContext(),
 		agentID,
 		mcpServerID,
 	)
@@ -921,7 +1014,7 @@ func (h *AgentHandler) RemoveMCPServerFromAgent(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionUpdate,
@@ -973,7 +1066,7 @@ func (h *AgentHandler) GetAgentMCPServers(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -1039,7 +1132,7 @@ func (h *AgentHandler) DetectAndMapMCPServers(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -1053,7 +1146,7 @@ func (h *AgentHandler) DetectAndMapMCPServers(c fiber.Ctx) error {
 
 	// Call service with mcpService for auto-registration
 	result, err := h.agentService.DetectMCPServersFromConfig(
-		c.Context(),
+		c.UserContext(),
 		agentID,
 		&req,
 		h.mcpService, // ✅ Pass mcpService for auto-registration
@@ -1069,7 +1162,7 @@ func (h *AgentHandler) DetectAndMapMCPServers(c fiber.Ctx) error {
 	// Log audit
 	if !req.DryRun {
 		h.auditService.LogAction(
-			c.Context(),
+			c.UserContext(),
 			orgID,
 			userID,
 			domain.AuditActionUpdate,
@@ -1121,7 +1214,7 @@ func (h *AgentHandler) GetAgentByIdentifier(c fiber.Ctx) error {
 
 	if err == nil {
 		// It's a UUID, get by ID
-		agent, err = h.agentService.GetAgent(c.Context(), agentID)
+		agent, err = h.agentService.GetAgent(c.UserContext(), agentID)
 		if err != nil {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Agent not found",
@@ -1136,7 +1229,7 @@ func (h *AgentHandler) GetAgentByIdentifier(c fiber.Ctx) error {
 		}
 	} else {
 		// It's a name, get by name
-		agent, err = h.agentService.GetAgentByName(c.Context(), orgID, identifier)
+		agent, err = h.agentService.GetAgentByName(c.UserContext(), orgID, identifier)
 		if err != nil {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error":   "Agent not found",
@@ -1144,7 +1237,7 @@ func (h *AgentHandler) GetAgentByIdentifier(c fiber.Ctx) error {
 			})
 		}
 	}
-	capabilities, err := h.capabilityService.GetAgentCapabilities(c.Context(), agent.ID, true)
+	capabilities, err := h.capabilityService.GetAgentCapabilities(c.UserContext(), agent.ID, true)
 	if err != nil {
 		capabilities = []*domain.AgentCapability{}
 	}
@@ -1255,7 +1348,7 @@ func (h *AgentHandler) UpdateAgentTrustScore(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -1269,18 +1362,18 @@ func (h *AgentHandler) UpdateAgentTrustScore(c fiber.Ctx) error {
 	}
 
 	// Update trust score in database using agent repository
-	if err := h.agentService.UpdateTrustScore(c.Context(), agentID, req.Score); err != nil {
+	if err := h.agentService.UpdateTrustScore(c.UserContext(), agentID, req.Score); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update trust score",
 		})
 	}
 
 	// Get updated agent
-	updatedAgent, _ := h.agentService.GetAgent(c.Context(), agentID)
+	updatedAgent, _ := h.agentService.GetAgent(c.UserContext(), agentID)
 
 	// Log audit - manual trust score override is a sensitive admin action
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionUpdate,
@@ -1349,7 +1442,7 @@ func (h *AgentHandler) SuspendAgent(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization first
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -1362,18 +1455,18 @@ func (h *AgentHandler) SuspendAgent(c fiber.Ctx) error {
 	}
 
 	// Suspend the agent
-	if err := h.agentService.SuspendAgent(c.Context(), agentID); err != nil {
+	if err := h.agentService.SuspendAgent(c.UserContext(), agentID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
 	// Get updated agent to return in response
-	agent, _ = h.agentService.GetAgent(c.Context(), agentID)
+	agent, _ = h.agentService.GetAgent(c.UserContext(), agentID)
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionUpdate,
@@ -1408,6 +1501,7 @@ func (h *AgentHandler) SuspendAgent(c fiber.Ctx) error {
 // @Failure 400 {object} ErrorResponse "Invalid agent ID"
 // @Failure 404 {object} ErrorResponse "Agent not found"
 // @Failure 403 {object} ErrorResponse "Access denied"
+// @Failure 409 {object} ErrorResponse "Agent is revoked"
 // @Router /agents/{id}/reactivate [post]
 func (h *AgentHandler) ReactivateAgent(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
@@ -1420,7 +1514,7 @@ func (h *AgentHandler) ReactivateAgent(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization first
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -1433,18 +1527,23 @@ func (h *AgentHandler) ReactivateAgent(c fiber.Ctx) error {
 	}
 
 	// Reactivate the agent
-	if err := h.agentService.ReactivateAgent(c.Context(), agentID); err != nil {
+	if err := h.agentService.ReactivateAgent(c.UserContext(), agentID); err != nil {
+		if errors.Is(err, application.ErrAgentRevoked) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
 	// Get updated agent to return in response
-	agent, _ = h.agentService.GetAgent(c.Context(), agentID)
+	agent, _ = h.agentService.GetAgent(c.UserContext(), agentID)
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionUpdate,
@@ -1492,7 +1591,7 @@ func (h *AgentHandler) RotateCredentials(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization first
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -1505,7 +1604,7 @@ func (h *AgentHandler) RotateCredentials(c fiber.Ctx) error {
 	}
 
 	// Rotate credentials (generates new keypair)
-	publicKey, privateKey, err := h.agentService.RotateCredentials(c.Context(), agentID)
+	publicKey, privateKey, err := h.agentService.RotateCredentials(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -1513,11 +1612,11 @@ func (h *AgentHandler) RotateCredentials(c fiber.Ctx) error {
 	}
 
 	// Get updated agent to return in response
-	agent, _ = h.agentService.GetAgent(c.Context(), agentID)
+	agent, _ = h.agentService.GetAgent(c.UserContext(), agentID)
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionUpdate,
@@ -1547,6 +1646,99 @@ func (h *AgentHandler) RotateCredentials(c fiber.Ctx) error {
 	})
 }
 
+// RotateKey rotates an agent's key with a grace period for the previous key
+// @Summary Rotate agent key
+// @Description Generate a new Ed25519 keypair (or register the supplied public key). Signatures made with the previous key are accepted until the grace period ends.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param request body application.RotateKeyRequest false "Optional public key and grace period"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 404 {object} ErrorResponse "Agent not found"
+// @Failure 403 {object} ErrorResponse "Access denied"
+// @Router /agents/{id}/rotate-key [post]
+func (h *AgentHandler) RotateKey(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	var req application.RotateKeyRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	// Verify agent belongs to organization first
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Agent not found",
+		})
+	}
+	if agent.OrganizationID != orgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied",
+		})
+	}
+
+	result, err := h.agentService.RotateKey(c.UserContext(), agentID, &req)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidKeyRotation) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	agent = result.Agent
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"agent",
+		agent.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"action":                "rotate_key",
+			"agentName":             agent.Name,
+			"rotationCount":         agent.RotationCount,
+			"generated":             result.PrivateKey != "",
+			"keyRotationGraceUntil": agent.KeyRotationGraceUntil,
+		},
+	)
+
+	response := fiber.Map{
+		"success":               true,
+		"agentId":               agent.ID,
+		"publicKey":             agent.PublicKey,
+		"keyAlgorithm":          agent.KeyAlgorithm,
+		"rotationCount":         agent.RotationCount,
+		"keyCreatedAt":          agent.KeyCreatedAt,
+		"keyExpiresAt":          agent.KeyExpiresAt,
+		"keyRotationGraceUntil": agent.KeyRotationGraceUntil,
+	}
+	if result.PrivateKey != "" {
+		response["privateKey"] = result.PrivateKey // ⚠️ SENSITIVE: Only returned once during rotation
+		response["warning"] = "Store the private key securely. It will not be shown again."
+	}
+	return c.JSON(response)
+}
+
 // UpdateAgentKeys allows SDK to register its own public key
 // @Summary Update agent public key
 // @Description Register or update an agent's public key. Used by SDK during initialization.
@@ -1587,7 +1779,7 @@ func (h *AgentHandler) UpdateAgentKeys(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization first
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -1600,18 +1792,18 @@ func (h *AgentHandler) UpdateAgentKeys(c fiber.Ctx) error {
 	}
 
 	// Update public key
-	if err := h.agentService.UpdateAgentPublicKey(c.Context(), agentID, req.PublicKey); err != nil {
+	if err := h.agentService.UpdateAgentPublicKey(c.UserContext(), agentID, req.PublicKey); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
 	// Get updated agent
-	agent, _ = h.agentService.GetAgent(c.Context(), agentID)
+	agent, _ = h.agentService.GetAgent(c.UserContext(), agentID)
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionUpdate,
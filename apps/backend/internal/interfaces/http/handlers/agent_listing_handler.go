@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type AgentListingHandler struct {
+	listingService *application.AgentListingService
+	auditService   *application.AuditService
+	signer         *crypto.PublicStatusSigner // Signs the public lookup, badges and status pages
+}
+
+func NewAgentListingHandler(
+	listingService *application.AgentListingService,
+	auditService *application.AuditService,
+	signer *crypto.PublicStatusSigner,
+) *AgentListingHandler {
+	return &AgentListingHandler{
+		listingService: listingService,
+		auditService:   auditService,
+		signer:         signer,
+	}
+}
+
+// VerifyPublicAgent returns the verification standing of a publicly discoverable agent
+// @Summary Look up agent verification status
+// @Description Unauthenticated lookup for third parties checking an agent before interacting with it. Only agents their organization made publicly discoverable are found.
+// @Description The body is signed: X-AIM-Signature is its base64 Ed25519 signature with the key of /public/v1/signing-key.
+// @Tags public
+// @Produce json
+// @Param agent query string true "Agent as <org-domain>/<agent-name>"
+// @Success 200 {object} domain.PublicAgentVerification
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /public/v1/verify [get]
+func (h *AgentListingHandler) VerifyPublicAgent(c fiber.Ctx) error {
+	verification, err := h.listingService.Lookup(c.UserContext(), c.Query("agent"))
+	if err != nil {
+		return publicAgentError(c, err)
+	}
+
+	body, err := json.Marshal(verification)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to encode verification",
+		})
+	}
+
+	return h.sendSigned(c, fiber.MIMEApplicationJSONCharsetUTF8, body)
+}
+
+// GetAgentListing reports whether the agent is publicly discoverable
+// @Summary Get agent public listing
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/public-listing [get]
+func (h *AgentListingHandler) GetAgentListing(c fiber.Ctx) error {
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	listing, err := h.listingService.GetListing(c.UserContext(), c.Locals("organization_id").(uuid.UUID), agentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrAgentListingNotFound):
+			return c.JSON(fiber.Map{
+				"publiclyDiscoverable": false,
+			})
+		case errors.Is(err, application.ErrListingAgentNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Agent not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get agent listing",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"publiclyDiscoverable": true,
+		"listing":              listing,
+	})
+}
+
+// ListAgent makes the agent publicly discoverable
+// @Summary List agent publicly
+// @Description Anyone can then look up the agent's verification status, trust tier and last verification at /public/v1/verify
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 200 {object} domain.AgentListing
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/public-listing [put]
+func (h *AgentListingHandler) ListAgent(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	listing, err := h.listingService.List(c.UserContext(), orgID, agentID, userID)
+	if err != nil {
+		if errors.Is(err, application.ErrListingAgentNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Agent not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list agent",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"agent",
+		agentID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"publicly_discoverable": true,
+		},
+	)
+
+	return c.JSON(listing)
+}
+
+// UnlistAgent stops the agent from being publicly discoverable
+// @Summary Unlist agent
+// @Tags agents
+// @Param id path string true "Agent ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/public-listing [delete]
+func (h *AgentListingHandler) UnlistAgent(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	if err := h.listingService.Unlist(c.UserContext(), orgID, agentID); err != nil {
+		if errors.Is(err, application.ErrListingAgentNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Agent not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to unlist agent",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"agent",
+		agentID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"publicly_discoverable": false,
+		},
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type AgentPeerHandler struct {
+	peerService  *application.AgentPeerService
+	auditService *application.AuditService
+}
+
+func NewAgentPeerHandler(
+	peerService *application.AgentPeerService,
+	auditService *application.AuditService,
+) *AgentPeerHandler {
+	return &AgentPeerHandler{
+		peerService:  peerService,
+		auditService: auditService,
+	}
+}
+
+// ListPolicies lists the organization's agent peer policies
+// @Summary List agent peer policies
+// @Description List the policies declaring which agents may call which other agents (A2A)
+// @Tags agents
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/agents/peer-policies [get]
+func (h *AgentPeerHandler) ListPolicies(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	policies, err := h.peerService.ListPolicies(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list agent peer policies",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"policies": policies,
+		"total":    len(policies),
+	})
+}
+
+// CreatePolicy declares that one agent may call another
+// @Summary Create agent peer policy
+// @Description Declare that the source agent may call the target agent, optionally in both directions, for the listed actions and above the given trust score minimums (0.3 by default)
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param request body application.CreateAgentPeerPolicyRequest true "Peer policy"
+// @Success 201 {object} domain.AgentPeerPolicy
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/agents/peer-policies [post]
+func (h *AgentPeerHandler) CreatePolicy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.CreateAgentPeerPolicyRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	policy, err := h.peerService.CreatePolicy(c.UserContext(), orgID, userID, &req)
+	if err != nil {
+		return peerPolicyError(c, err, "Failed to create agent peer policy")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"agent_peer_policy",
+		policy.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"source_agent_id": policy.SourceAgentID,
+			"target_agent_id": policy.TargetAgentID,
+			"bidirectional":   policy.Bidirectional,
+			"allowed_actions": policy.AllowedActions,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(policy)
+}
+
+// UpdatePolicy changes an agent peer policy
+// @Summary Update agent peer policy
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param id path string true "Policy ID"
+// @Param request body application.UpdateAgentPeerPolicyRequest true "Changed fields"
+// @Success 200 {object} domain.AgentPeerPolicy
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/peer-policies/{id} [put]
+func (h *AgentPeerHandler) UpdatePolicy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	policyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid policy ID",
+		})
+	}
+
+	var req application.UpdateAgentPeerPolicyRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	policy, err := h.peerService.UpdatePolicy(c.UserContext(), orgID, policyID, &req)
+	if err != nil {
+		return peerPolicyError(c, err, "Failed to update agent peer policy")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"agent_peer_policy",
+		policy.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"is_enabled":      policy.IsEnabled,
+			"bidirectional":   policy.Bidirectional,
+			"allowed_actions": policy.AllowedActions,
+		},
+	)
+
+	return c.JSON(policy)
+}
+
+// DeletePolicy removes an agent peer policy
+// @Summary Delete agent peer policy
+// @Tags agents
+// @Param id path string true "Policy ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/peer-policies/{id} [delete]
+func (h *AgentPeerHandler) DeletePolicy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	policyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid policy ID",
+		})
+	}
+
+	if err := h.peerService.DeletePolicy(c.UserContext(), orgID, policyID); err != nil {
+		return peerPolicyError(c, err, "Failed to delete agent peer policy")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"agent_peer_policy",
+		policyID,
+		c.IP(),
+		c.Get("User-Agent"),
+		nil,
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetAgentPeers lists the peer policies an agent is part of
+// @Summary Get agent peers
+// @Description List the peer policies the agent is the source or the target of
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/peers [get]
+func (h *AgentPeerHandler) GetAgentPeers(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	policies, err := h.peerService.ListAgentPolicies(c.UserContext(), orgID, agentID)
+	if err != nil {
+		return peerPolicyError(c, err, "Failed to list agent peers")
+	}
+
+	return c.JSON(fiber.Map{
+		"agentId":  agentID,
+		"policies": policies,
+		"total":    len(policies),
+	})
+}
+
+// VerifyPeerCall authorizes a call from the agent to another agent
+// @Summary Verify agent-to-agent call
+// @Description Check whether the agent may call the target agent: an enabled peer policy must declare the call and both agents must be verified, not compromised and above the policy's trust score minimums.
+// @Description The decision is recorded as an A2A verification event; undeclared calls are denied and raise a peer_drift alert.
+// @Description Agents authenticated with Ed25519 may only verify their own calls.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param id path string true "Calling agent ID"
+// @Param request body object true "targetAgentId and optional action"
+// @Success 200 {object} application.AgentPeerDecision
+// @Failure 403 {object} application.AgentPeerDecision
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/peers/verify [post]
+func (h *AgentPeerHandler) VerifyPeerCall(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	callerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	var req struct {
+		TargetAgentID uuid.UUID `json:"targetAgentId"`
+		Action        string    `json:"action"`
+	}
+	if err := c.Bind().JSON(&req); err != nil || req.TargetAgentID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "targetAgentId is required",
+		})
+	}
+
+	call := &application.AgentPeerCall{
+		OrganizationID: orgID,
+		CallerAgentID:  callerID,
+		TargetAgentID:  req.TargetAgentID,
+		Action:         req.Action,
+		InitiatorType:  domain.InitiatorTypeUser,
+		InitiatorIP:    c.IP(),
+	}
+	if agentID, ok := c.Locals("agent_id").(uuid.UUID); ok {
+		if agentID != callerID {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Agents may only verify their own calls",
+			})
+		}
+		call.InitiatorType = domain.InitiatorTypeAgent
+		call.InitiatorID = &agentID
+	} else if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		call.InitiatorID = &userID
+	}
+
+	decision, err := h.peerService.AuthorizeCall(c.UserContext(), call)
+	if err != nil {
+		return peerPolicyError(c, err, "Failed to verify agent-to-agent call")
+	}
+
+	if !decision.Allowed {
+		return c.Status(fiber.StatusForbidden).JSON(decision)
+	}
+	return c.JSON(decision)
+}
+
+// peerPolicyError responds with the HTTP status matching an agent peer service error
+func peerPolicyError(c fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, application.ErrInvalidAgentPeerPolicy):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrPeerAgentNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Agent not found",
+		})
+	case errors.Is(err, domain.ErrAgentPeerPolicyNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Agent peer policy not found",
+		})
+	case errors.Is(err, domain.ErrAgentPeerPolicyExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": fallback,
+		})
+	}
+}
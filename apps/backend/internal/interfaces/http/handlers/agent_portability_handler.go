@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type AgentPortabilityHandler struct {
+	portabilityService *application.AgentPortabilityService
+	auditService       *application.AuditService
+}
+
+func NewAgentPortabilityHandler(
+	portabilityService *application.AgentPortabilityService,
+	auditService *application.AuditService,
+) *AgentPortabilityHandler {
+	return &AgentPortabilityHandler{
+		portabilityService: portabilityService,
+		auditService:       auditService,
+	}
+}
+
+// GetIssuer returns the key this deployment signs identity bundles with
+// @Summary Get the identity bundle issuer
+// @Description The name and public key other deployments add to IDENTITY_BUNDLE_TRUSTED_KEYS to import this deployment's bundles
+// @Tags agents
+// @Produce json
+// @Success 200 {object} application.IdentityBundleIssuerInfo
+// @Router /api/v1/agents/identity-bundles/issuer [get]
+func (h *AgentPortabilityHandler) GetIssuer(c fiber.Ctx) error {
+	return c.JSON(h.portabilityService.Issuer())
+}
+
+// ExportIdentities exports agents as a signed identity bundle
+// @Summary Export agent identities
+// @Description Signed bundle of the agents' metadata, public keys, trust history summary, attestations and lineage, to import into another deployment. Without agentIds, every agent of the organization is exported.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param request body application.ExportIdentitiesRequest false "Agents to export"
+// @Success 200 {object} domain.SignedIdentityBundle
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/export [post]
+func (h *AgentPortabilityHandler) ExportIdentities(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.ExportIdentitiesRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	bundle, err := h.portabilityService.ExportIdentities(c.UserContext(), orgID, &req)
+	if err != nil {
+		if errors.Is(err, application.ErrPortableAgentNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to export agent identities",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionExport,
+		"identity_bundle",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"agent_ids": req.AgentIDs,
+		},
+	)
+
+	return c.JSON(bundle)
+}
+
+// ImportIdentities imports the agents of an identity bundle signed by a trusted deployment
+// @Summary Import agent identities
+// @Description Recreates the bundle's agents with their keys, trust score, attestations of MCP servers registered here under the same URL, and lineage. Agents whose name or public key is taken are skipped.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param request body domain.SignedIdentityBundle true "Signed identity bundle"
+// @Success 200 {object} application.IdentityImportReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/agents/import [post]
+func (h *AgentPortabilityHandler) ImportIdentities(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var bundle domain.SignedIdentityBundle
+	if err := c.Bind().JSON(&bundle); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	report, err := h.portabilityService.ImportIdentities(c.UserContext(), orgID, userID, &bundle)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrIdentityBundleUntrusted):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, domain.ErrIdentityBundleInvalid):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to import agent identities",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"identity_bundle",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"issuer":   report.Issuer.Name,
+			"imported": report.Imported,
+			"skipped":  report.Skipped,
+		},
+	)
+
+	return c.JSON(report)
+}
+
+// GetLineage lists the deployments an agent was imported from
+// @Summary Get agent lineage
+// @Description The deployments the agent was exported from before it was imported here, oldest first
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/lineage [get]
+func (h *AgentPortabilityHandler) GetLineage(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	lineage, err := h.portabilityService.ListLineage(c.UserContext(), orgID, agentID)
+	if err != nil {
+		if errors.Is(err, application.ErrPortableAgentNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Agent not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch agent lineage",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"agentId": agentID,
+		"lineage": lineage,
+		"total":   len(lineage),
+	})
+}
@@ -34,7 +34,7 @@ func (h *AgentHandler) GetAgentKeyVault(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -48,7 +48,7 @@ func (h *AgentHandler) GetAgentKeyVault(c fiber.Ctx) error {
 
 	// Log audit - viewing key vault is a sensitive action
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionView,
@@ -118,7 +118,7 @@ func (h *AgentHandler) GetAgentAuditLogs(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -132,7 +132,7 @@ func (h *AgentHandler) GetAgentAuditLogs(c fiber.Ctx) error {
 
 	// Get audit logs filtered by agent ID (entity_id)
 	logs, total, err := h.auditService.GetAuditLogs(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		"",       // action filter (empty = all)
 		"agent",  // entity_type
@@ -151,7 +151,7 @@ func (h *AgentHandler) GetAgentAuditLogs(c fiber.Ctx) error {
 
 	// Log this audit log query
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionView,
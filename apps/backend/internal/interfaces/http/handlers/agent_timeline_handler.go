@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+)
+
+type AgentTimelineHandler struct {
+	timelineService *application.AgentTimelineService
+}
+
+func NewAgentTimelineHandler(timelineService *application.AgentTimelineService) *AgentTimelineHandler {
+	return &AgentTimelineHandler{
+		timelineService: timelineService,
+	}
+}
+
+// GetAgentTimeline returns everything that happened to an agent as one feed
+// @Summary Agent activity timeline
+// @Description Verification events, attestations made, drift findings, trust changes, key rotations, capability changes and alerts of an agent, newest first
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param types query string false "Comma-separated entry types (verification, attestation, drift, trust_change, key_rotation, capability_change, alert); every type when omitted"
+// @Param start query string false "Only entries at or after this time (RFC 3339)"
+// @Param end query string false "Only entries before this time (RFC 3339)"
+// @Param limit query int false "Page size (1-200)" default(50)
+// @Param offset query int false "Entries to skip" default(0)
+// @Success 200 {object} application.AgentTimeline
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/timeline [get]
+func (h *AgentTimelineHandler) GetAgentTimeline(c fiber.Ctx) error {
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	req := &application.AgentTimelineRequest{
+		OrganizationID: c.Locals("organization_id").(uuid.UUID),
+		AgentID:        agentID,
+	}
+	if types := c.Query("types"); types != "" {
+		req.Types = strings.Split(types, ",")
+	}
+	for name, target := range map[string]**time.Time{"start": &req.Start, "end": &req.End} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid " + name + " parameter: expected an RFC 3339 time",
+			})
+		}
+		*target = &parsed
+	}
+	for name, target := range map[string]*int{"limit": &req.Limit, "offset": &req.Offset} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid " + name + " parameter",
+			})
+		}
+		*target = parsed
+	}
+
+	timeline, err := h.timelineService.GetTimeline(c.UserContext(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrInvalidAgentTimelineRequest):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, application.ErrAgentTimelineAgentNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Agent not found",
+			})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to load agent timeline",
+			})
+		}
+	}
+
+	return c.JSON(timeline)
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type AlertSuppressionHandler struct {
+	suppressionService *application.AlertSuppressionService
+	auditService       *application.AuditService
+}
+
+func NewAlertSuppressionHandler(
+	suppressionService *application.AlertSuppressionService,
+	auditService *application.AuditService,
+) *AlertSuppressionHandler {
+	return &AlertSuppressionHandler{
+		suppressionService: suppressionService,
+		auditService:       auditService,
+	}
+}
+
+// CreateRule creates a new alert suppression rule
+// @Summary Create alert suppression rule
+// @Description Drop or downgrade matching alerts before notification. Matched alerts are still recorded, and every rule must expire within 90 days.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param request body application.AlertSuppressionRuleRequest true "Rule details"
+// @Success 201 {object} domain.AlertSuppressionRule
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/alert-suppression-rules [post]
+func (h *AlertSuppressionHandler) CreateRule(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.AlertSuppressionRuleRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	rule, err := h.suppressionService.CreateRule(c.UserContext(), &req, orgID, userID)
+	if err != nil {
+		return h.ruleError(c, err, "Failed to create suppression rule")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"alert_suppression_rule",
+		rule.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"rule_name":  rule.Name,
+			"action":     rule.Action,
+			"expires_at": rule.ExpiresAt,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(rule)
+}
+
+// ListRules lists the organization's alert suppression rules with their hit counters
+// @Summary List alert suppression rules
+// @Tags alerts
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/alert-suppression-rules [get]
+func (h *AlertSuppressionHandler) ListRules(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	rules, err := h.suppressionService.ListRules(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch suppression rules",
+		})
+	}
+
+	if rules == nil {
+		rules = []*domain.AlertSuppressionRule{}
+	}
+
+	return c.JSON(fiber.Map{
+		"rules": rules,
+		"total": len(rules),
+	})
+}
+
+// GetRule retrieves a single alert suppression rule
+// @Summary Get alert suppression rule
+// @Tags alerts
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} domain.AlertSuppressionRule
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/alert-suppression-rules/{id} [get]
+func (h *AlertSuppressionHandler) GetRule(c fiber.Ctx) error {
+	rule, status, message := h.getOwnedRule(c)
+	if rule == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	return c.JSON(rule)
+}
+
+// UpdateRule updates an alert suppression rule
+// @Summary Update alert suppression rule
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param request body application.AlertSuppressionRuleRequest true "Rule details"
+// @Success 200 {object} domain.AlertSuppressionRule
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/alert-suppression-rules/{id} [put]
+func (h *AlertSuppressionHandler) UpdateRule(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	existing, status, message := h.getOwnedRule(c)
+	if existing == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	var req application.AlertSuppressionRuleRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	rule, err := h.suppressionService.UpdateRule(c.UserContext(), existing.ID, &req)
+	if err != nil {
+		return h.ruleError(c, err, "Failed to update suppression rule")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"alert_suppression_rule",
+		rule.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"rule_name":  rule.Name,
+			"action":     rule.Action,
+			"enabled":    rule.Enabled,
+			"expires_at": rule.ExpiresAt,
+		},
+	)
+
+	return c.JSON(rule)
+}
+
+// DeleteRule deletes an alert suppression rule
+// @Summary Delete alert suppression rule
+// @Tags alerts
+// @Param id path string true "Rule ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/alert-suppression-rules/{id} [delete]
+func (h *AlertSuppressionHandler) DeleteRule(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	rule, status, message := h.getOwnedRule(c)
+	if rule == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	if err := h.suppressionService.DeleteRule(c.UserContext(), rule.ID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete suppression rule",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"alert_suppression_rule",
+		rule.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"rule_name": rule.Name,
+			"hit_count": rule.HitCount,
+		},
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetRuleHits lists the alerts a suppression rule matched
+// @Summary List alert suppression rule hits
+// @Tags alerts
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/alert-suppression-rules/{id}/hits [get]
+func (h *AlertSuppressionHandler) GetRuleHits(c fiber.Ctx) error {
+	rule, status, message := h.getOwnedRule(c)
+	if rule == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	hits, err := h.suppressionService.GetRuleHits(c.UserContext(), rule.ID, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch suppression rule hits",
+		})
+	}
+
+	if hits == nil {
+		hits = []*domain.AlertSuppressionHit{}
+	}
+
+	return c.JSON(fiber.Map{
+		"hits":     hits,
+		"hitCount": rule.HitCount,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// ruleError responds 400 for validation errors and 500 for everything else
+func (h *AlertSuppressionHandler) ruleError(c fiber.Ctx, err error, message string) error {
+	if errors.Is(err, application.ErrInvalidSuppressionRule) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
+
+// getOwnedRule loads the rule in the :id param and verifies it belongs to the caller's organization.
+// On failure it returns a nil rule with the HTTP status and message to respond with.
+func (h *AlertSuppressionHandler) getOwnedRule(c fiber.Ctx) (*domain.AlertSuppressionRule, int, string) {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	ruleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, fiber.StatusBadRequest, "Invalid rule ID"
+	}
+
+	rule, err := h.suppressionService.GetRule(c.UserContext(), ruleID)
+	if err != nil {
+		return nil, fiber.StatusNotFound, "Suppression rule not found"
+	}
+	if rule.OrganizationID != orgID {
+		return nil, fiber.StatusForbidden, "Access denied"
+	}
+
+	return rule, fiber.StatusOK, ""
+}
@@ -63,7 +63,7 @@ func (h *AnalyticsHandler) GetUsageStatistics(c fiber.Ctx) error {
 	daysStr := c.Query("days", "")
 	period := c.Query("period", "month")
 	
-	agents, err := h.agentService.ListAgents(c.Context(), orgID)
+	agents, err := h.agentService.ListAgents(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch usage statistics",
@@ -233,7 +233,7 @@ func (h *AnalyticsHandler) GetTrustScoreTrends(c fiber.Ctx) error {
 		rows, err := h.db.Query(query, orgID, weeks)
 		if err != nil {
 			// Fallback: Generate trend data based on agent creation dates (weekly)
-			agents, _ := h.agentService.ListAgents(c.Context(), orgID)
+			agents, _ := h.agentService.ListAgents(c.UserContext(), orgID)
 			
 			// Group agents by week and calculate average trust score
 			weekScores := make(map[string][]float64)
@@ -324,7 +324,7 @@ func (h *AnalyticsHandler) GetTrustScoreTrends(c fiber.Ctx) error {
 		}
 
 		// Get current average for comparison
-		agents, _ := h.agentService.ListAgents(c.Context(), orgID)
+		agents, _ := h.agentService.ListAgents(c.UserContext(), orgID)
 		totalScore := 0.0
 		for _, agent := range agents {
 			totalScore += agent.TrustScore
@@ -392,7 +392,7 @@ func (h *AnalyticsHandler) GetTrustScoreTrends(c fiber.Ctx) error {
 		rows, err := h.db.Query(query, orgID, days)
 		if err != nil {
 			// Fallback: Generate trend data based on agent creation dates
-			agents, _ := h.agentService.ListAgents(c.Context(), orgID)
+			agents, _ := h.agentService.ListAgents(c.UserContext(), orgID)
 			
 			// Group agents by creation date and calculate average trust score
 			dateScores := make(map[string][]float64)
@@ -479,7 +479,7 @@ func (h *AnalyticsHandler) GetTrustScoreTrends(c fiber.Ctx) error {
 		}
 
 		// Get current average
-		agents, _ := h.agentService.ListAgents(c.Context(), orgID)
+		agents, _ := h.agentService.ListAgents(c.UserContext(), orgID)
 		totalScore := 0.0
 		for _, agent := range agents {
 			totalScore += agent.TrustScore
@@ -549,7 +549,7 @@ func (h *AnalyticsHandler) GetVerificationActivity(c fiber.Ctx) error {
 	}
 	months, _ := strconv.Atoi(c.Query("months", "6"))
 
-	agents, err := h.agentService.ListAgents(c.Context(), orgID)
+	agents, err := h.agentService.ListAgents(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch verification activity",
@@ -785,7 +785,7 @@ func (h *AnalyticsHandler) GetAgentActivity(c fiber.Ctx) error {
 	rows, err := h.db.Query(query, orgID, limit, offset)
 	if err != nil {
 		// Fallback: if agent_activity_metrics table doesn't exist, use basic agent data
-		agents, err := h.agentService.ListAgents(c.Context(), orgID)
+		agents, err := h.agentService.ListAgents(c.UserContext(), orgID)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to fetch agent activity",
@@ -942,7 +942,7 @@ func (h *AnalyticsHandler) GetDashboardStats(c fiber.Ctx) error {
 	}
 
 	// Fetch agents
-	agents, err := h.agentService.ListAgents(c.Context(), orgID)
+	agents, err := h.agentService.ListAgents(c.UserContext(), orgID)
 	if err != nil {
 		// 🔍 LOG DETAILED ERROR for debugging
 		log.Printf("❌ Failed to fetch agents for org %s: %v", orgID.String(), err)
@@ -952,7 +952,7 @@ func (h *AnalyticsHandler) GetDashboardStats(c fiber.Ctx) error {
 	}
 
 	// Fetch MCP servers
-	mcpServers, err := h.mcpService.ListMCPServers(c.Context(), orgID)
+	mcpServers, err := h.mcpService.ListMCPServers(c.UserContext(), orgID)
 	if err != nil {
 		fmt.Printf("Error fetching MCP servers: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -995,7 +995,7 @@ func (h *AnalyticsHandler) GetDashboardStats(c fiber.Ctx) error {
 	}
 
 	// Fetch verification event statistics (last 24 hours)
-	stats, err := h.verificationEventService.GetLast24HoursStatistics(c.Context(), orgID)
+	stats, err := h.verificationEventService.GetLast24HoursStatistics(c.UserContext(), orgID)
 	if err != nil {
 		// If verification stats fail, use defaults
 		stats = &domain.VerificationStatistics{
@@ -1008,7 +1008,7 @@ func (h *AnalyticsHandler) GetDashboardStats(c fiber.Ctx) error {
 	}
 
 	// ✅ Fetch REAL user count from database
-	users, err := h.authService.GetUsersByOrganization(c.Context(), orgID)
+	users, err := h.authService.GetUsersByOrganization(c.UserContext(), orgID)
 	totalUsers := 0
 	activeUsers := 0
 	if err == nil {
@@ -1025,7 +1025,7 @@ func (h *AnalyticsHandler) GetDashboardStats(c fiber.Ctx) error {
 	activeAlerts := 0
 	criticalAlerts := 0
 	securityIncidents := 0
-	alerts, _, err := h.alertService.GetAlerts(c.Context(), orgID, "", "open", 1000, 0)
+	alerts, _, err := h.alertService.GetAlerts(c.UserContext(), orgID, "", "open", 1000, 0)
 	if err == nil {
 		activeAlerts = len(alerts)
 		// Count critical severity alerts
@@ -1036,7 +1036,7 @@ func (h *AnalyticsHandler) GetDashboardStats(c fiber.Ctx) error {
 		}
 	}
 	// Get open security incidents count
-	incidents, err := h.securityService.GetIncidents(c.Context(), orgID, domain.IncidentStatusOpen, 100, 0)
+	incidents, err := h.securityService.GetIncidents(c.UserContext(), orgID, domain.IncidentStatusOpen, 100, 0)
 	if err == nil {
 		securityIncidents = len(incidents)
 	}
@@ -1228,13 +1228,13 @@ func (h *AnalyticsHandler) GetActivitySummary(c fiber.Ctx) error {
 	}
 
 	// Get agent and MCP server counts
-	agents, err := h.agentService.ListAgents(c.Context(), orgID)
+	agents, err := h.agentService.ListAgents(c.UserContext(), orgID)
 	if err != nil {
 		log.Printf("❌ Error fetching agents: %v", err)
 		agents = []*domain.Agent{}
 	}
 
-	mcpServers, err := h.mcpService.ListMCPServers(c.Context(), orgID)
+	mcpServers, err := h.mcpService.ListMCPServers(c.UserContext(), orgID)
 	if err != nil {
 		log.Printf("❌ Error fetching MCP servers: %v", err)
 		mcpServers = []*domain.MCPServer{}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/application"
@@ -8,17 +10,20 @@ import (
 )
 
 type APIKeyHandler struct {
-	apiKeyService *application.APIKeyService
-	auditService  *application.AuditService
+	apiKeyService    *application.APIKeyService
+	auditService     *application.AuditService
+	tombstoneService *application.TombstoneService
 }
 
 func NewAPIKeyHandler(
 	apiKeyService *application.APIKeyService,
 	auditService *application.AuditService,
+	tombstoneService *application.TombstoneService,
 ) *APIKeyHandler {
 	return &APIKeyHandler{
-		apiKeyService: apiKeyService,
-		auditService:  auditService,
+		apiKeyService:    apiKeyService,
+		auditService:     auditService,
+		tombstoneService: tombstoneService,
 	}
 }
 
@@ -52,7 +57,7 @@ func (h *APIKeyHandler) ListAPIKeys(c fiber.Ctx) error {
 		agentID = &parsed
 	}
 
-	apiKeys, err := h.apiKeyService.ListAPIKeys(c.Context(), orgID)
+	apiKeys, err := h.apiKeyService.ListAPIKeys(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch API keys",
@@ -76,15 +81,17 @@ func (h *APIKeyHandler) ListAPIKeys(c fiber.Ctx) error {
 	})
 }
 
-// CreateAPIKey generates a new API key
+// CreateAPIKey generates a new API key.
+// An optional scopes array (e.g. "verify:create", "agents:read") limits what the key may do.
 func (h *APIKeyHandler) CreateAPIKey(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 	userID := c.Locals("user_id").(uuid.UUID)
 
 	var req struct {
-		AgentID   string  `json:"agent_id"`
-		Name      string  `json:"name"`
-		ExpiresAt *string `json:"expires_at"`
+		AgentID   string   `json:"agent_id"`
+		Name      string   `json:"name"`
+		ExpiresAt *string  `json:"expires_at"`
+		Scopes    []string `json:"scopes"`
 	}
 
 	if err := c.Bind().JSON(&req); err != nil {
@@ -114,14 +121,21 @@ func (h *APIKeyHandler) CreateAPIKey(c fiber.Ctx) error {
 	}
 
 	plainKey, apiKey, err := h.apiKeyService.GenerateAPIKey(
-		c.Context(),
+		c.UserContext(),
 		agentID,
 		orgID,
 		userID,
 		req.Name,
 		expiresInDays,
+		req.Scopes,
 	)
 	if err != nil {
+		if errors.Is(err, application.ErrInvalidAPIKeyScope) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":       err.Error(),
+				"validScopes": domain.APIKeyScopes,
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -129,7 +143,7 @@ func (h *APIKeyHandler) CreateAPIKey(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionCreate,
@@ -140,6 +154,7 @@ func (h *APIKeyHandler) CreateAPIKey(c fiber.Ctx) error {
 		map[string]interface{}{
 			"keyName": req.Name,
 			"agentId": agentID.String(),
+			"scopes":  apiKey.Scopes,
 		},
 	)
 
@@ -150,6 +165,7 @@ func (h *APIKeyHandler) CreateAPIKey(c fiber.Ctx) error {
 		"agentId":   apiKey.AgentID,
 		"expiresAt": apiKey.ExpiresAt,
 		"createdAt": apiKey.CreatedAt,
+		"scopes":    apiKey.Scopes,
 	})
 }
 
@@ -164,7 +180,7 @@ func (h *APIKeyHandler) DisableAPIKey(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.apiKeyService.RevokeAPIKey(c.Context(), keyID, orgID); err != nil {
+	if err := h.apiKeyService.RevokeAPIKey(c.UserContext(), keyID, orgID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -172,7 +188,7 @@ func (h *APIKeyHandler) DisableAPIKey(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionRevoke,
@@ -199,7 +215,11 @@ func (h *APIKeyHandler) DeleteAPIKey(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.apiKeyService.DeleteAPIKey(c.Context(), keyID, orgID); err != nil {
+	// Keep a tombstone so old audit entries still resolve the key
+	err = h.tombstoneService.DeleteWithTombstone(c.UserContext(), domain.TombstoneEntityAPIKey, keyID, userID, func() error {
+		return h.apiKeyService.DeleteAPIKey(c.UserContext(), keyID, orgID)
+	})
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -207,7 +227,7 @@ func (h *APIKeyHandler) DeleteAPIKey(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionDelete,
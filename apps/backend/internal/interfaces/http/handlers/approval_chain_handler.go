@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type ApprovalChainHandler struct {
+	approvalService *application.ApprovalChainService
+	auditService    *application.AuditService
+}
+
+func NewApprovalChainHandler(
+	approvalService *application.ApprovalChainService,
+	auditService *application.AuditService,
+) *ApprovalChainHandler {
+	return &ApprovalChainHandler{
+		approvalService: approvalService,
+		auditService:    auditService,
+	}
+}
+
+// RejectApprovalRequest is the optional body when rejecting an approval request
+type RejectApprovalRequest struct {
+	Comment string `json:"comment"`
+}
+
+// approvalErrorStatus maps approval engine errors to HTTP status codes; it returns 0 for other errors
+func approvalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrApprovalNotAllowed):
+		return fiber.StatusForbidden
+	case errors.Is(err, application.ErrApprovalConflict), errors.Is(err, application.ErrApprovalRequestClosed):
+		return fiber.StatusConflict
+	default:
+		return 0
+	}
+}
+
+// approvalPending reports whether a guarded action is still waiting on its approval chain
+func approvalPending(approval *domain.ApprovalRequest) bool {
+	return approval != nil && approval.Status == domain.ApprovalRequestStatusPending
+}
+
+// ListChains lists the organization's approval chains
+// @Summary List approval chains
+// @Tags approvals
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/approval-chains [get]
+func (h *ApprovalChainHandler) ListChains(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	chains, err := h.approvalService.ListChains(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch approval chains",
+		})
+	}
+
+	if chains == nil {
+		chains = []*domain.ApprovalChain{}
+	}
+
+	return c.JSON(fiber.Map{
+		"chains": chains,
+		"total":  len(chains),
+	})
+}
+
+// CreateChain creates an approval chain
+// @Summary Create approval chain
+// @Description Require approvals from distinct users, optionally with specific roles in order, before capability grants, agent verification or policy disabling take effect
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param request body application.ApprovalChainRequest true "Approval chain"
+// @Success 201 {object} domain.ApprovalChain
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/approval-chains [post]
+func (h *ApprovalChainHandler) CreateChain(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.ApprovalChainRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	chain, err := h.approvalService.CreateChain(c.UserContext(), orgID, userID, &req)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidApprovalChain) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create approval chain",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"approval_chain",
+		chain.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":   chain.Name,
+			"action": chain.Action,
+			"steps":  len(chain.Steps),
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(chain)
+}
+
+// UpdateChain replaces an approval chain's definition
+// @Summary Update approval chain
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path string true "Approval chain ID"
+// @Param request body application.ApprovalChainRequest true "Approval chain"
+// @Success 200 {object} domain.ApprovalChain
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/approval-chains/{id} [put]
+func (h *ApprovalChainHandler) UpdateChain(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	chainID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid approval chain ID",
+		})
+	}
+
+	var req application.ApprovalChainRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	chain, err := h.approvalService.UpdateChain(c.UserContext(), orgID, chainID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrApprovalChainNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Approval chain not found",
+			})
+		case errors.Is(err, application.ErrInvalidApprovalChain):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update approval chain",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"approval_chain",
+		chain.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":       chain.Name,
+			"steps":      len(chain.Steps),
+			"is_enabled": chain.IsEnabled,
+		},
+	)
+
+	return c.JSON(chain)
+}
+
+// DeleteChain deletes an approval chain; requests it opened keep collecting approvals
+// @Summary Delete approval chain
+// @Tags approvals
+// @Param id path string true "Approval chain ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/approval-chains/{id} [delete]
+func (h *ApprovalChainHandler) DeleteChain(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	chainID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid approval chain ID",
+		})
+	}
+
+	if err := h.approvalService.DeleteChain(c.UserContext(), orgID, chainID); err != nil {
+		if errors.Is(err, application.ErrApprovalChainNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Approval chain not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete approval chain",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"approval_chain",
+		chainID,
+		c.IP(),
+		c.Get("User-Agent"),
+		nil,
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListRequests lists the organization's approval requests
+// @Summary List approval requests
+// @Description Actions waiting on (or decided by) an approval chain. Approve a request by performing the guarded action again as another user.
+// @Tags approvals
+// @Produce json
+// @Param status query string false "pending, approved or rejected"
+// @Param limit query int false "Limit (default 50)"
+// @Param offset query int false "Offset (default 0)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/approval-requests [get]
+func (h *ApprovalChainHandler) ListRequests(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	status := domain.ApprovalRequestStatus(c.Query("status"))
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	requests, err := h.approvalService.ListRequests(c.UserContext(), orgID, status, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch approval requests",
+		})
+	}
+
+	if requests == nil {
+		requests = []*domain.ApprovalRequest{}
+	}
+
+	return c.JSON(fiber.Map{
+		"requests": requests,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// GetRequest retrieves an approval request with its decisions
+// @Summary Get approval request
+// @Tags approvals
+// @Produce json
+// @Param id path string true "Approval request ID"
+// @Success 200 {object} domain.ApprovalRequest
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/approval-requests/{id} [get]
+func (h *ApprovalChainHandler) GetRequest(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	requestID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid approval request ID",
+		})
+	}
+
+	request, err := h.approvalService.GetRequest(c.UserContext(), orgID, requestID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Approval request not found",
+		})
+	}
+
+	return c.JSON(request)
+}
+
+// RejectRequest rejects a pending approval request; the guarded action does not take effect
+// @Summary Reject approval request
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path string true "Approval request ID"
+// @Param request body RejectApprovalRequest false "Rejection comment"
+// @Success 200 {object} domain.ApprovalRequest
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/approval-requests/{id}/reject [post]
+func (h *ApprovalChainHandler) RejectRequest(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	requestID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid approval request ID",
+		})
+	}
+
+	var req RejectApprovalRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	request, err := h.approvalService.Reject(c.UserContext(), orgID, requestID, userID, req.Comment)
+	if err != nil {
+		if errors.Is(err, application.ErrApprovalRequestNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Approval request not found",
+			})
+		}
+		if status := approvalErrorStatus(err); status != 0 {
+			return c.Status(status).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to reject approval request",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"approval_request",
+		request.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"action":      request.Action,
+			"resource_id": request.ResourceID.String(),
+			"decision":    "rejected",
+			"comment":     req.Comment,
+		},
+	)
+
+	return c.JSON(request)
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type AttestationCadenceHandler struct {
+	cadenceService *application.MCPAttestationCadenceService
+	auditService   *application.AuditService
+}
+
+func NewAttestationCadenceHandler(
+	cadenceService *application.MCPAttestationCadenceService,
+	auditService *application.AuditService,
+) *AttestationCadenceHandler {
+	return &AttestationCadenceHandler{
+		cadenceService: cadenceService,
+		auditService:   auditService,
+	}
+}
+
+// GetAttestationCadence returns whether an MCP server keeps up with the attestation cadence of its criticality
+// @Summary Get an MCP server's attestation cadence status
+// @Description How many independent agents attested the MCP server within the maximum age its criticality allows, when it falls out of cadence, and the factor applied to its confidence score
+// @Tags mcp-servers
+// @Produce json
+// @Param id path string true "MCP Server ID"
+// @Success 200 {object} domain.AttestationCadenceStatus
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/{id}/attestation-cadence [get]
+func (h *AttestationCadenceHandler) GetAttestationCadence(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	mcpServerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid MCP server ID",
+		})
+	}
+
+	status, err := h.cadenceService.GetStatus(c.UserContext(), orgID, mcpServerID)
+	if err != nil {
+		if errors.Is(err, application.ErrCadenceMCPServerNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "MCP server not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to evaluate attestation cadence",
+		})
+	}
+
+	return c.JSON(status)
+}
+
+// SetCriticality tags an MCP server with a criticality level
+// @Summary Set an MCP server's criticality
+// @Description The criticality (low, medium, high or critical) selects the attestation cadence the server must keep
+// @Tags mcp-servers
+// @Accept json
+// @Produce json
+// @Param id path string true "MCP Server ID"
+// @Param request body map[string]string true "Criticality, e.g. {\"criticality\": \"critical\"}"
+// @Success 200 {object} domain.AttestationCadenceStatus
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/{id}/criticality [put]
+func (h *AttestationCadenceHandler) SetCriticality(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	mcpServerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid MCP server ID",
+		})
+	}
+
+	var req struct {
+		Criticality domain.MCPServerCriticality `json:"criticality"`
+	}
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	status, err := h.cadenceService.SetCriticality(c.UserContext(), orgID, mcpServerID, req.Criticality)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrInvalidAttestationCadencePolicy):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, application.ErrCadenceMCPServerNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "MCP server not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to set MCP server criticality",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"mcp_server",
+		mcpServerID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"criticality": req.Criticality,
+		},
+	)
+
+	return c.JSON(status)
+}
+
+// ListCadencePolicies lists the organization's attestation cadence of every criticality
+// @Summary List attestation cadence policies
+// @Description The maximum attestation age and minimum number of independent agents of each MCP server criticality; isDefault marks levels the organization did not customize
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/attestation-cadence-policies [get]
+func (h *AttestationCadenceHandler) ListCadencePolicies(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	policies, err := h.cadenceService.Policies(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch attestation cadence policies",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"policies": policies,
+		"total":    len(policies),
+	})
+}
+
+// SetCadencePolicy overrides the organization's attestation cadence of a criticality
+// @Summary Set an attestation cadence policy
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param criticality path string true "Criticality (low, medium, high, critical)"
+// @Param request body application.SetAttestationCadencePolicyRequest true "Attestation cadence"
+// @Success 200 {object} domain.AttestationCadencePolicy
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/attestation-cadence-policies/{criticality} [put]
+func (h *AttestationCadenceHandler) SetCadencePolicy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	criticality := domain.MCPServerCriticality(c.Params("criticality"))
+
+	var req application.SetAttestationCadencePolicyRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	policy, err := h.cadenceService.SetPolicy(c.UserContext(), orgID, userID, criticality, &req)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidAttestationCadencePolicy) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save attestation cadence policy",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"attestation_cadence_policy",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"criticality":               criticality,
+			"max_attestation_age_hours": policy.MaxAttestationAgeHours,
+			"min_independent_agents":    policy.MinIndependentAgents,
+		},
+	)
+
+	return c.JSON(policy)
+}
+
+// ResetCadencePolicy restores the default attestation cadence of a criticality
+// @Summary Reset an attestation cadence policy to its default
+// @Tags admin
+// @Param criticality path string true "Criticality (low, medium, high, critical)"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/attestation-cadence-policies/{criticality} [delete]
+func (h *AttestationCadenceHandler) ResetCadencePolicy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	criticality := domain.MCPServerCriticality(c.Params("criticality"))
+
+	if err := h.cadenceService.ResetPolicy(c.UserContext(), orgID, criticality); err != nil {
+		if errors.Is(err, application.ErrInvalidAttestationCadencePolicy) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to reset attestation cadence policy",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"attestation_cadence_policy",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"criticality": criticality,
+		},
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
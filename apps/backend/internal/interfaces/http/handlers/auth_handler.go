@@ -9,20 +9,26 @@ import (
 )
 
 type AuthHandler struct {
-	authService  *application.AuthService
-	jwtService   *auth.JWTService
-	orgRepo      domain.OrganizationRepository
+	authService     *application.AuthService
+	mfaService      *application.MFAService
+	jwtService      *auth.JWTService
+	orgRepo         domain.OrganizationRepository
+	loginProtection *application.LoginProtectionService
 }
 
 func NewAuthHandler(
 	authService *application.AuthService,
+	mfaService *application.MFAService,
 	jwtService *auth.JWTService,
 	orgRepo domain.OrganizationRepository,
+	loginProtection *application.LoginProtectionService,
 ) *AuthHandler {
 	return &AuthHandler{
-		authService:  authService,
-		jwtService:   jwtService,
-		orgRepo:      orgRepo,
+		authService:     authService,
+		mfaService:      mfaService,
+		jwtService:      jwtService,
+		orgRepo:         orgRepo,
+		loginProtection: loginProtection,
 	}
 }
 
@@ -43,7 +49,7 @@ func (h *AuthHandler) Me(c fiber.Ctx) error {
 		})
 	}
 
-	user, err := h.authService.GetUserByID(c.Context(), userID)
+	user, err := h.authService.GetUserByID(c.UserContext(), userID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "User not found",
@@ -62,11 +68,13 @@ func (h *AuthHandler) Me(c fiber.Ctx) error {
 	})
 }
 
-// LocalLogin handles email/password authentication
+// LocalLogin handles email/password authentication. Repeated failures back off, then need a
+// CAPTCHA (captchaToken) and finally lock the account for a while.
 func (h *AuthHandler) LocalLogin(c fiber.Ctx) error {
 	type LoginRequest struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email        string `json:"email"`
+		Password     string `json:"password"`
+		CaptchaToken string `json:"captchaToken"`
 	}
 
 	var req LoginRequest
@@ -83,11 +91,31 @@ func (h *AuthHandler) LocalLogin(c fiber.Ctx) error {
 		})
 	}
 
+	// Locked accounts and addresses are refused before the password is checked
+	if err := h.loginProtection.Check(c.UserContext(), req.Email, c.IP(), req.CaptchaToken); err != nil {
+		return loginBlocked(c, err)
+	}
+
 	// Authenticate user (this also updates last_login_at)
-	user, err := h.authService.LoginWithPassword(c.Context(), req.Email, req.Password)
+	user, err := h.authService.LoginWithPassword(c.UserContext(), req.Email, req.Password)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid email or password",
+		target, _ := h.authService.GetUserByEmail(c.UserContext(), req.Email)
+		return loginFailed(c, h.loginProtection, req.Email, target)
+	}
+	loginSucceeded(c, h.loginProtection, req.Email, user)
+
+	// A second factor is checked before any tokens are issued
+	mfaToken, enroll, err := loginMFAChallenge(c.UserContext(), h.mfaService, h.jwtService, user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check MFA requirement",
+		})
+	}
+	if mfaToken != "" {
+		return c.JSON(fiber.Map{
+			"mfa_required":            true,
+			"mfa_enrollment_required": enroll,
+			"mfa_token":               mfaToken,
 		})
 	}
 
@@ -172,7 +200,7 @@ func (h *AuthHandler) ChangePassword(c fiber.Ctx) error {
 	}
 
 	// Change password
-	err := h.authService.ChangePassword(c.Context(), userID, req.CurrentPassword, req.NewPassword)
+	err := h.authService.ChangePassword(c.UserContext(), userID, req.CurrentPassword, req.NewPassword)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
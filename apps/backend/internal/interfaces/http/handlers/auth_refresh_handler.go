@@ -10,6 +10,7 @@ import (
 	"github.com/opena2a/identity/backend/internal/application"
 	"github.com/opena2a/identity/backend/internal/domain"
 	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+	"github.com/opena2a/identity/backend/internal/infrastructure/metrics"
 )
 
 // AuthRefreshHandler handles token refresh operations
@@ -39,6 +40,13 @@ func NewAuthRefreshHandler(jwtService *auth.JWTService, sdkTokenService *applica
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/auth/refresh [post]
 func (h *AuthRefreshHandler) RefreshToken(c fiber.Ctx) error {
+	// Refresh latency by token type and outcome, recorded however the request ends
+	start := time.Now()
+	tokenType, outcome := "session", "invalid_request"
+	defer func() {
+		metrics.ObserveAuthRefresh(tokenType, outcome, time.Since(start).Seconds())
+	}()
+
 	var req RefreshTokenRequest
 	if err := c.Bind().JSON(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -55,15 +63,18 @@ func (h *AuthRefreshHandler) RefreshToken(c fiber.Ctx) error {
 	// Check if this is an SDK token and verify it's not revoked BEFORE rotating
 	tokenID, err := h.jwtService.GetTokenID(req.RefreshToken)
 	if err == nil && tokenID != "" {
+		tokenType = "sdk"
+
 		// Hash the token to check if it's tracked and revoked
 		hasher := sha256.New()
 		hasher.Write([]byte(req.RefreshToken))
 		tokenHash := hex.EncodeToString(hasher.Sum(nil))
 
 		// Check if token is tracked and not revoked
-		_, err := h.sdkTokenService.ValidateToken(c.Context(), tokenHash)
+		_, err := h.sdkTokenService.ValidateToken(c.UserContext(), tokenHash)
 		if err != nil {
 			// Token is revoked or invalid in database
+			outcome = "revoked"
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Token has been revoked or is invalid",
 			})
@@ -73,6 +84,7 @@ func (h *AuthRefreshHandler) RefreshToken(c fiber.Ctx) error {
 	// Validate refresh token and generate new tokens (with rotation)
 	newAccessToken, newRefreshToken, err := h.jwtService.RefreshTokenPair(req.RefreshToken)
 	if err != nil {
+		outcome = "invalid_token"
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Invalid or expired refresh token",
 		})
@@ -88,11 +100,11 @@ func (h *AuthRefreshHandler) RefreshToken(c fiber.Ctx) error {
 		oldTokenHash := hex.EncodeToString(hasher.Sum(nil))
 
 		// Get old token info for creating new token entry
-		oldToken, _ := h.sdkTokenService.ValidateToken(c.Context(), oldTokenHash)
+		oldToken, _ := h.sdkTokenService.ValidateToken(c.UserContext(), oldTokenHash)
 
 		// Record usage on the old token (updates last_used_at, usage_count)
 		ipAddress := c.IP()
-		_ = h.sdkTokenService.RecordTokenUsage(c.Context(), tokenID, ipAddress)
+		_ = h.sdkTokenService.RecordTokenUsage(c.UserContext(), tokenID, ipAddress)
 
 		// IMPORTANT: We do NOT revoke the old token anymore!
 		// This was causing issues with multiple SDK instances:
@@ -149,12 +161,13 @@ func (h *AuthRefreshHandler) RefreshToken(c fiber.Ctx) error {
 				}
 
 				// Save to database (critical for next rotation)
-				_ = h.sdkTokenService.CreateToken(c.Context(), newSDKToken)
+				_ = h.sdkTokenService.CreateToken(c.UserContext(), newSDKToken)
 			}
 		}
 	}
 
 	// Return new tokens
+	outcome = "success"
 	return c.JSON(RefreshTokenResponse{
 		AccessToken:  newAccessToken,
 		RefreshToken: newRefreshToken,
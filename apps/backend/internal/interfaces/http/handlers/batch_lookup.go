@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+)
+
+// BatchLookupRequest is the body of the batch lookup endpoints
+type BatchLookupRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// parseBatchLookupIDs reads and validates the IDs of a batch lookup request
+func parseBatchLookupIDs(c fiber.Ctx) ([]uuid.UUID, error) {
+	var req BatchLookupRequest
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return nil, fmt.Errorf("Invalid request body")
+	}
+	if len(req.IDs) == 0 {
+		return nil, fmt.Errorf("ids is required")
+	}
+	if len(req.IDs) > application.MaxBatchLookupIDs {
+		return nil, fmt.Errorf("at most %d ids may be requested at once", application.MaxBatchLookupIDs)
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.IDs))
+	for _, raw := range req.IDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ID: %s", raw)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// missingBatchIDs lists the requested IDs that were not found, once each and in request order
+func missingBatchIDs(requested []uuid.UUID, found map[uuid.UUID]bool) []uuid.UUID {
+	missing := make([]uuid.UUID, 0)
+	for _, id := range requested {
+		if !found[id] {
+			found[id] = true
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// batchLookupError maps a batch lookup service error to a response
+func batchLookupError(c fiber.Ctx, err error, message string) error {
+	if errors.Is(err, application.ErrBatchLookupTooLarge) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
@@ -3,7 +3,9 @@ package handlers
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
@@ -79,9 +81,15 @@ func (h *CapabilityHandler) GrantCapability(c fiber.Ctx) error {
 		req.CapabilityType,
 		req.Scope,
 		userIDPtr,
+		req.ExpiresAt,
 	)
 	if err != nil {
 		println("ERROR: GrantCapability service failed:", err.Error())
+		if errors.Is(err, application.ErrInvalidCapabilityExpiry) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: err.Error(),
 		})
@@ -453,6 +461,7 @@ func (h *CapabilityHandler) getUserIDFromContext(c fiber.Ctx) (uuid.UUID, error)
 type GrantCapabilityRequest struct {
 	CapabilityType string                 `json:"capabilityType" validate:"required"`
 	Scope          map[string]interface{} `json:"scope,omitempty"`
+	ExpiresAt      *time.Time             `json:"expiresAt,omitempty"` // Optional: the capability expires at this time
 }
 
 type VerifyActionRequest struct {
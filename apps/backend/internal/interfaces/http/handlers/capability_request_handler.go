@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
@@ -53,8 +56,9 @@ func (h *CapabilityRequestHandlers) CreateCapabilityRequest(c fiber.Ctx) error {
 
 	// Parse request body
 	type RequestBody struct {
-		CapabilityType string `json:"capability_type" validate:"required"`
-		Reason         string `json:"reason" validate:"required,min=10"`
+		CapabilityType string     `json:"capability_type" validate:"required"`
+		Reason         string     `json:"reason" validate:"required,min=10"`
+		ExpiresAt      *time.Time `json:"expires_at,omitempty"` // Optional: the granted capability expires at this time
 	}
 
 	var req RequestBody
@@ -83,12 +87,19 @@ func (h *CapabilityRequestHandlers) CreateCapabilityRequest(c fiber.Ctx) error {
 		AgentID:        agentID,
 		CapabilityType: req.CapabilityType,
 		Reason:         req.Reason,
+		ExpiresAt:      req.ExpiresAt,
 		RequestedBy:    agent.CreatedBy, // Using the agent's owner as the requester
 	}
 
 	// Create the request
-	request, err := h.service.CreateRequest(c.Context(), input)
+	request, err := h.service.CreateRequest(c.UserContext(), input)
 	if err != nil {
+		if errors.Is(err, application.ErrInvalidCapabilityExpiry) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
 		// Check for specific error types
 		errMsg := err.Error()
 		if errMsg == "agent not found" {
@@ -114,6 +125,91 @@ func (h *CapabilityRequestHandlers) CreateCapabilityRequest(c fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(request)
 }
 
+// RenewCapability godoc
+// @Summary Request the renewal of a time-bound capability
+// @Description Creates a pending capability request that extends the capability's expiry once approved. The request carries the justification of the request that originally granted the capability; a reason is only needed for capabilities that were not granted through a request. Expired capabilities can be renewed until they are granted again.
+// @Tags capability-requests
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param capabilityId path string true "Capability ID"
+// @Param request body domain.RenewCapabilityInput true "New expiry"
+// @Success 201 {object} domain.CapabilityRequest
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/agents/{id}/capabilities/{capabilityId}/renew [post]
+func (h *CapabilityRequestHandlers) RenewCapability(c fiber.Ctx) error {
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid agent ID",
+		})
+	}
+	capabilityID, err := uuid.Parse(c.Params("capabilityId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid capability ID",
+		})
+	}
+
+	agent, err := h.agentRepo.GetByID(agentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "agent not found",
+		})
+	}
+	if orgID, ok := c.Locals("organization_id").(uuid.UUID); ok && orgID != agent.OrganizationID {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "agent not found",
+		})
+	}
+
+	var input domain.RenewCapabilityInput
+	if err := c.Bind().JSON(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+	if input.ExpiresAt.IsZero() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "expiresAt is required",
+		})
+	}
+	input.AgentID = agentID
+	input.CapabilityID = capabilityID
+
+	// The signed-in user requests the renewal; SDK callers request it on behalf of the agent's owner
+	input.RequestedBy = agent.CreatedBy
+	if userID, ok := c.Locals("user_id").(uuid.UUID); ok && userID != uuid.Nil {
+		input.RequestedBy = userID
+	}
+
+	request, err := h.service.RenewCapability(c.UserContext(), &input)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrInvalidCapabilityExpiry), errors.Is(err, application.ErrRenewalReasonRequired):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, application.ErrCapabilityNotRenewable), strings.HasPrefix(err.Error(), "pending"):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case strings.HasPrefix(err.Error(), "capability not found"):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "capability not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to request capability renewal",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(request)
+}
+
 // ListCapabilityRequests godoc
 // @Summary List capability requests (Admin only)
 // @Description Get all capability requests with optional filtering
@@ -169,7 +265,7 @@ func (h *CapabilityRequestHandlers) ListCapabilityRequests(c fiber.Ctx) error {
 		}
 	}
 
-	requests, err := h.service.ListRequests(c.Context(), filter)
+	requests, err := h.service.ListRequests(c.UserContext(), filter)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "failed to list capability requests",
@@ -205,7 +301,7 @@ func (h *CapabilityRequestHandlers) GetCapabilityRequest(c fiber.Ctx) error {
 		})
 	}
 
-	request, err := h.service.GetRequest(c.Context(), id)
+	request, err := h.service.GetRequest(c.UserContext(), id)
 	if err != nil {
 		if err.Error() == "capability request not found" {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -229,6 +325,7 @@ func (h *CapabilityRequestHandlers) GetCapabilityRequest(c fiber.Ctx) error {
 // @Security Bearer
 // @Param id path string true "Capability Request ID"
 // @Success 200 {object} map[string]interface{}
+// @Success 202 {object} map[string]interface{} "Approval recorded, chain still pending"
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
@@ -250,16 +347,33 @@ func (h *CapabilityRequestHandlers) ApproveCapabilityRequest(c fiber.Ctx) error
 		})
 	}
 
-	if err := h.service.ApproveRequest(c.Context(), id, userID); err != nil {
+	approval, err := h.service.ApproveRequest(c.UserContext(), id, userID)
+	if err != nil {
 		if err.Error() == "capability request not found" {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "capability request not found",
 			})
 		}
+		if status := approvalErrorStatus(err); status != 0 {
+			return c.Status(status).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, application.ErrInvalidCapabilityExpiry) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
+	if approvalPending(approval) {
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message":         "approval recorded; further approvals are required",
+			"approvalRequest": approval,
+		})
+	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "capability request approved and capability granted",
@@ -296,7 +410,7 @@ func (h *CapabilityRequestHandlers) RejectCapabilityRequest(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.service.RejectRequest(c.Context(), id, userID); err != nil {
+	if err := h.service.RejectRequest(c.UserContext(), id, userID); err != nil {
 		if err.Error() == "capability request not found" {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "capability request not found",
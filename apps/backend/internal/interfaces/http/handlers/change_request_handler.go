@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type ChangeRequestHandler struct {
+	changeService *application.ChangeRequestService
+	auditService  *application.AuditService
+}
+
+func NewChangeRequestHandler(
+	changeService *application.ChangeRequestService,
+	auditService *application.AuditService,
+) *ChangeRequestHandler {
+	return &ChangeRequestHandler{
+		changeService: changeService,
+		auditService:  auditService,
+	}
+}
+
+// ChangeRequestDecisionRequest is the optional body when approving, rejecting, cancelling or rolling back a change
+type ChangeRequestDecisionRequest struct {
+	Comment string `json:"comment"`
+}
+
+// changeRequestErrorResponse maps change request errors to an HTTP response; it returns false for other errors
+func changeRequestErrorResponse(c fiber.Ctx, err error) (error, bool) {
+	var status int
+	message := err.Error()
+	switch {
+	case errors.Is(err, domain.ErrChangeRequestNotFound):
+		status, message = fiber.StatusNotFound, "Change request not found"
+	case errors.Is(err, application.ErrChangeTargetNotFound):
+		status, message = fiber.StatusNotFound, "Agent or security policy not found"
+	case errors.Is(err, application.ErrInvalidChangeRequest):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, application.ErrChangeRequestNotOpen), errors.Is(err, application.ErrChangeRequestNotApplied):
+		status = fiber.StatusConflict
+	default:
+		if status = approvalErrorStatus(err); status == 0 {
+			return nil, false
+		}
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"error": message,
+	}), true
+}
+
+// ListChangeRequests lists the organization's staged configuration changes
+// @Summary List change requests
+// @Tags change-requests
+// @Produce json
+// @Param status query string false "Only changes with this status"
+// @Param limit query int false "Limit (default 50)"
+// @Param offset query int false "Offset (default 0)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/change-requests [get]
+func (h *ChangeRequestHandler) ListChangeRequests(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	changes, total, err := h.changeService.ListChangeRequests(c.UserContext(), orgID, domain.ChangeRequestStatus(c.Query("status")), limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch change requests",
+		})
+	}
+
+	if changes == nil {
+		changes = []*domain.ChangeRequest{}
+	}
+
+	return c.JSON(fiber.Map{
+		"changeRequests": changes,
+		"total":          total,
+		"limit":          limit,
+		"offset":         offset,
+	})
+}
+
+// CreateChangeRequest stages a configuration change to an agent or security policy
+// @Summary Create change request
+// @Description Stage a change with an effective time and an optional window end. Once approved, it is applied when the window opens and rolled back automatically if the verification error rate reaches rollbackErrorRate within monitorMinutes.
+// @Tags change-requests
+// @Accept json
+// @Produce json
+// @Param request body application.CreateChangeRequestRequest true "Change"
+// @Success 201 {object} domain.ChangeRequest
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/change-requests [post]
+func (h *ChangeRequestHandler) CreateChangeRequest(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.CreateChangeRequestRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Policy changes need the same permission as editing a policy directly
+	permissions, _ := c.Locals("permissions").(domain.PermissionSet)
+	if req.TargetType == domain.ChangeTargetSecurityPolicy && !permissions.Has(domain.PermissionSecurityPoliciesManage) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Permission required: " + string(domain.PermissionSecurityPoliciesManage),
+		})
+	}
+
+	change, err := h.changeService.CreateChangeRequest(c.UserContext(), orgID, userID, &req)
+	if err != nil {
+		if resp, ok := changeRequestErrorResponse(c, err); ok {
+			return resp
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create change request",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"change_request",
+		change.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"target_type":  change.TargetType,
+			"target_id":    change.TargetID.String(),
+			"changes":      change.Changes,
+			"effective_at": change.EffectiveAt,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(change)
+}
+
+// GetChangeRequest retrieves a change request
+// @Summary Get change request
+// @Tags change-requests
+// @Produce json
+// @Param id path string true "Change request ID"
+// @Success 200 {object} domain.ChangeRequest
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/change-requests/{id} [get]
+func (h *ChangeRequestHandler) GetChangeRequest(c fiber.Ctx) error {
+	changeID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid change request ID",
+		})
+	}
+
+	change, err := h.changeService.GetChangeRequest(c.UserContext(), c.Locals("organization_id").(uuid.UUID), changeID)
+	if err != nil {
+		if resp, ok := changeRequestErrorResponse(c, err); ok {
+			return resp
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch change request",
+		})
+	}
+
+	return c.JSON(change)
+}
+
+// ApproveChangeRequest approves a change so it is applied when its window opens
+// @Summary Approve change request
+// @Description When an approval chain guards config_change, each call by a different user records the next approval and the change is scheduled once the chain is approved.
+// @Tags change-requests
+// @Accept json
+// @Produce json
+// @Param id path string true "Change request ID"
+// @Param request body ChangeRequestDecisionRequest false "Comment"
+// @Success 200 {object} domain.ChangeRequest
+// @Success 202 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/change-requests/{id}/approve [post]
+func (h *ChangeRequestHandler) ApproveChangeRequest(c fiber.Ctx) error {
+	var approval *domain.ApprovalRequest
+	return h.decide(c, "approved", func(ctx context.Context, orgID, id, userID uuid.UUID, comment string) (*domain.ChangeRequest, error) {
+		change, pending, err := h.changeService.ApproveChangeRequest(ctx, orgID, id, userID, comment)
+		approval = pending
+		return change, err
+	}, func(change *domain.ChangeRequest) error {
+		if approvalPending(approval) {
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+				"message":         "approval recorded; further approvals are required",
+				"changeRequest":   change,
+				"approvalRequest": approval,
+			})
+		}
+		return c.JSON(change)
+	})
+}
+
+// RejectChangeRequest rejects a change that has not been applied yet
+// @Summary Reject change request
+// @Tags change-requests
+// @Accept json
+// @Produce json
+// @Param id path string true "Change request ID"
+// @Param request body ChangeRequestDecisionRequest false "Reason"
+// @Success 200 {object} domain.ChangeRequest
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/change-requests/{id}/reject [post]
+func (h *ChangeRequestHandler) RejectChangeRequest(c fiber.Ctx) error {
+	return h.decide(c, "rejected", h.changeService.RejectChangeRequest, nil)
+}
+
+// CancelChangeRequest withdraws a change that has not been applied yet
+// @Summary Cancel change request
+// @Description Callers can cancel their own changes; those with change_requests:approve (managers and admins by default) can cancel any.
+// @Tags change-requests
+// @Accept json
+// @Produce json
+// @Param id path string true "Change request ID"
+// @Param request body ChangeRequestDecisionRequest false "Reason"
+// @Success 200 {object} domain.ChangeRequest
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/change-requests/{id}/cancel [post]
+func (h *ChangeRequestHandler) CancelChangeRequest(c fiber.Ctx) error {
+	// Without the permission to decide change requests, callers only cancel their own
+	if permissions, _ := c.Locals("permissions").(domain.PermissionSet); !permissions.Has(domain.PermissionChangeRequestsApprove) {
+		if changeID, err := uuid.Parse(c.Params("id")); err == nil {
+			change, err := h.changeService.GetChangeRequest(c.UserContext(), c.Locals("organization_id").(uuid.UUID), changeID)
+			if err == nil && change.RequestedBy != c.Locals("user_id").(uuid.UUID) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error": "You can only cancel your own change requests",
+				})
+			}
+		}
+	}
+	return h.decide(c, "cancelled", h.changeService.CancelChangeRequest, nil)
+}
+
+// RollbackChangeRequest restores the values an applied change replaced
+// @Summary Roll back change request
+// @Tags change-requests
+// @Accept json
+// @Produce json
+// @Param id path string true "Change request ID"
+// @Param request body ChangeRequestDecisionRequest false "Reason"
+// @Success 200 {object} domain.ChangeRequest
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/change-requests/{id}/rollback [post]
+func (h *ChangeRequestHandler) RollbackChangeRequest(c fiber.Ctx) error {
+	return h.decide(c, "rolled_back", h.changeService.RollbackChangeRequest, nil)
+}
+
+// decide applies a user's decision to a change request and audits it; respond, when set,
+// writes the response instead of the change request
+func (h *ChangeRequestHandler) decide(
+	c fiber.Ctx,
+	decision string,
+	fn func(ctx context.Context, orgID, id, userID uuid.UUID, comment string) (*domain.ChangeRequest, error),
+	respond func(change *domain.ChangeRequest) error,
+) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	changeID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid change request ID",
+		})
+	}
+
+	var req ChangeRequestDecisionRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	change, err := fn(c.UserContext(), orgID, changeID, userID, req.Comment)
+	if err != nil {
+		if resp, ok := changeRequestErrorResponse(c, err); ok {
+			return resp
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update change request",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"change_request",
+		change.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"decision":    decision,
+			"comment":     req.Comment,
+			"status":      change.Status,
+			"target_type": change.TargetType,
+			"target_id":   change.TargetID.String(),
+		},
+	)
+
+	if respond != nil {
+		return respond(change)
+	}
+	return c.JSON(change)
+}
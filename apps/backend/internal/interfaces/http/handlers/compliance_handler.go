@@ -29,7 +29,7 @@ func (h *ComplianceHandler) GetComplianceStatus(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 	userID := c.Locals("user_id").(uuid.UUID)
 
-	status, err := h.complianceService.GetComplianceStatus(c.Context(), orgID)
+	status, err := h.complianceService.GetComplianceStatus(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch compliance status",
@@ -38,7 +38,7 @@ func (h *ComplianceHandler) GetComplianceStatus(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionView,
@@ -95,7 +95,7 @@ func (h *ComplianceHandler) GetComplianceMetrics(c fiber.Ctx) error {
 
 	// Get metrics
 	metrics, err := h.complianceService.GetComplianceMetrics(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		startDate,
 		endDate,
@@ -109,7 +109,7 @@ func (h *ComplianceHandler) GetComplianceMetrics(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionView,
@@ -137,7 +137,7 @@ func (h *ComplianceHandler) GetAccessReview(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 	userID := c.Locals("user_id").(uuid.UUID)
 
-	review, err := h.complianceService.GetAccessReview(c.Context(), orgID)
+	review, err := h.complianceService.GetAccessReview(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to generate access review",
@@ -146,7 +146,7 @@ func (h *ComplianceHandler) GetAccessReview(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionView,
@@ -182,7 +182,7 @@ func (h *ComplianceHandler) RunComplianceCheck(c fiber.Ctx) error {
 
 	// Run compliance checks
 	results, err := h.complianceService.RunComplianceCheck(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		req.CheckType,
 	)
@@ -194,7 +194,7 @@ func (h *ComplianceHandler) RunComplianceCheck(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionCheck,
@@ -248,7 +248,7 @@ func (h *ComplianceHandler) ExportComplianceReport(c fiber.Ctx) error {
 	}
 
 	// Get compliance data
-	status, err := h.complianceService.GetComplianceStatus(c.Context(), orgID)
+	status, err := h.complianceService.GetComplianceStatus(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch compliance status",
@@ -256,7 +256,7 @@ func (h *ComplianceHandler) ExportComplianceReport(c fiber.Ctx) error {
 	}
 
 	// Get metrics
-	metricsData, err := h.complianceService.GetComplianceMetrics(c.Context(), orgID, startDate, endDate, "day")
+	metricsData, err := h.complianceService.GetComplianceMetrics(c.UserContext(), orgID, startDate, endDate, "day")
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch compliance metrics",
@@ -265,7 +265,7 @@ func (h *ComplianceHandler) ExportComplianceReport(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionView,
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type CompromiseResponseHandler struct {
+	compromiseService *application.CompromiseResponseService
+	agentService      *application.AgentService
+	auditService      *application.AuditService
+}
+
+func NewCompromiseResponseHandler(
+	compromiseService *application.CompromiseResponseService,
+	agentService *application.AgentService,
+	auditService *application.AuditService,
+) *CompromiseResponseHandler {
+	return &CompromiseResponseHandler{
+		compromiseService: compromiseService,
+		agentService:      agentService,
+		auditService:      auditService,
+	}
+}
+
+// MarkCompromisedRequest represents the request to mark an agent as compromised
+type MarkCompromisedRequest struct {
+	Reason string `json:"reason"`
+}
+
+// getOwnedAgent resolves the :id agent and checks it belongs to the caller's organization
+func (h *CompromiseResponseHandler) getOwnedAgent(c fiber.Ctx) (*domain.Agent, int, string) {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, fiber.StatusBadRequest, "Invalid agent ID"
+	}
+
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
+	if err != nil {
+		return nil, fiber.StatusNotFound, "Agent not found"
+	}
+	if agent.OrganizationID != orgID {
+		return nil, fiber.StatusForbidden, "Access denied"
+	}
+
+	return agent, 0, ""
+}
+
+// MarkCompromised marks an agent as compromised and runs the organization's response bundle
+// @Summary Mark agent as compromised
+// @Description Suspends the agent and runs the configured response bundle (revoke keys and tokens, invalidate attestations, notify MCP owners, open an incident, freeze trust score)
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param request body MarkCompromisedRequest false "Reason"
+// @Success 200 {object} domain.CompromiseResponse
+// @Failure 400 {object} ErrorResponse "Invalid agent ID"
+// @Failure 403 {object} ErrorResponse "Access denied"
+// @Failure 404 {object} ErrorResponse "Agent not found"
+// @Router /agents/{id}/compromise [post]
+func (h *CompromiseResponseHandler) MarkCompromised(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	agent, status, msg := h.getOwnedAgent(c)
+	if agent == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	var req MarkCompromisedRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	response, err := h.compromiseService.MarkAsCompromised(c.UserContext(), agent.ID, domain.CompromiseTriggerManual, req.Reason, &userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"agent",
+		agent.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"action":     "mark_compromised",
+			"agentName":  agent.Name,
+			"reason":     response.Reason,
+			"responseId": response.ID,
+			"incidentId": response.IncidentID,
+		},
+	)
+
+	return c.JSON(response)
+}
+
+// ListResponses lists the compromise response reports of an agent
+// @Summary List compromise responses
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /agents/{id}/compromise-responses [get]
+func (h *CompromiseResponseHandler) ListResponses(c fiber.Ctx) error {
+	agent, status, msg := h.getOwnedAgent(c)
+	if agent == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	responses, err := h.compromiseService.ListResponses(c.UserContext(), agent.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch compromise responses",
+		})
+	}
+
+	if responses == nil {
+		responses = []*domain.CompromiseResponse{}
+	}
+
+	return c.JSON(fiber.Map{
+		"responses": responses,
+		"total":     len(responses),
+	})
+}
+
+// GetPolicy returns the organization's compromise response bundle
+// @Summary Get compromise response policy
+// @Tags admin
+// @Produce json
+// @Success 200 {object} domain.CompromiseResponsePolicy
+// @Router /api/v1/admin/compromise-response-policy [get]
+func (h *CompromiseResponseHandler) GetPolicy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	policy, err := h.compromiseService.GetPolicy(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch compromise response policy",
+		})
+	}
+
+	return c.JSON(policy)
+}
+
+// UpdatePolicy configures which steps run when an agent is marked compromised
+// @Summary Update compromise response policy
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body application.CompromiseResponsePolicyRequest true "Response bundle"
+// @Success 200 {object} domain.CompromiseResponsePolicy
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/compromise-response-policy [put]
+func (h *CompromiseResponseHandler) UpdatePolicy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.CompromiseResponsePolicyRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	policy, err := h.compromiseService.UpdatePolicy(c.UserContext(), &req, orgID, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"compromise_response_policy",
+		policy.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"revoke_api_keys":         policy.RevokeAPIKeys,
+			"revoke_owner_sdk_tokens": policy.RevokeOwnerSDKTokens,
+			"invalidate_attestations": policy.InvalidateAttestations,
+			"notify_mcp_owners":       policy.NotifyMCPOwners,
+			"open_incident":           policy.OpenIncident,
+			"freeze_trust_score":      policy.FreezeTrustScore,
+			"incident_severity":       policy.IncidentSeverity,
+		},
+	)
+
+	return c.JSON(policy)
+}
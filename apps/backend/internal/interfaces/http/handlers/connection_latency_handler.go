@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type ConnectionLatencyHandler struct {
+	latencyService *application.ConnectionLatencyService
+	auditService   *application.AuditService
+}
+
+func NewConnectionLatencyHandler(
+	latencyService *application.ConnectionLatencyService,
+	auditService *application.AuditService,
+) *ConnectionLatencyHandler {
+	return &ConnectionLatencyHandler{
+		latencyService: latencyService,
+		auditService:   auditService,
+	}
+}
+
+// ListLatencySLOs lists the latency objectives of an MCP server's agent connections
+// @Summary List connection latency SLOs
+// @Description Latency objectives of the agents connected to the MCP server, with the p95 of the last evaluation and the attribution of a breach in progress
+// @Tags mcp-servers
+// @Produce json
+// @Param id path string true "MCP Server ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/{id}/latency-slos [get]
+func (h *ConnectionLatencyHandler) ListLatencySLOs(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	mcpServerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid MCP server ID",
+		})
+	}
+
+	slos, err := h.latencyService.ListSLOs(c.UserContext(), orgID, mcpServerID)
+	if err != nil {
+		if errors.Is(err, application.ErrLatencySLOConnectionNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "MCP server not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch latency SLOs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"slos":  slos,
+		"total": len(slos),
+	})
+}
+
+// SetLatencySLO sets the latency objective of an agent's connection to an MCP server
+// @Summary Set a connection latency SLO
+// @Description Alert when the connection's p95 latency, taken from the agent's attestations, stays above the objective for the sustained period
+// @Tags mcp-servers
+// @Accept json
+// @Produce json
+// @Param id path string true "MCP Server ID"
+// @Param agentId path string true "Agent ID"
+// @Param request body application.SetConnectionLatencySLORequest true "Latency objective"
+// @Success 200 {object} domain.ConnectionLatencySLO
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/{id}/latency-slos/{agentId} [put]
+func (h *ConnectionLatencyHandler) SetLatencySLO(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	mcpServerID, agentID, err := latencySLOConnection(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var req application.SetConnectionLatencySLORequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	slo, err := h.latencyService.SetSLO(c.UserContext(), orgID, userID, mcpServerID, agentID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrInvalidConnectionLatencySLO):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, application.ErrLatencySLOConnectionNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Agent is not connected to this MCP server",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save latency SLO",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"connection_latency_slo",
+		slo.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"mcp_server_id":     mcpServerID,
+			"agent_id":          agentID,
+			"p95_threshold_ms":  slo.P95ThresholdMs,
+			"window_minutes":    slo.WindowMinutes,
+			"sustained_minutes": slo.SustainedMinutes,
+			"enabled":           slo.IsEnabled,
+		},
+	)
+
+	return c.JSON(slo)
+}
+
+// DeleteLatencySLO removes the latency objective of an agent's connection to an MCP server
+// @Summary Delete a connection latency SLO
+// @Tags mcp-servers
+// @Param id path string true "MCP Server ID"
+// @Param agentId path string true "Agent ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/{id}/latency-slos/{agentId} [delete]
+func (h *ConnectionLatencyHandler) DeleteLatencySLO(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	mcpServerID, agentID, err := latencySLOConnection(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err := h.latencyService.DeleteSLO(c.UserContext(), orgID, mcpServerID, agentID); err != nil {
+		if errors.Is(err, domain.ErrConnectionLatencySLONotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Latency SLO not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete latency SLO",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"connection_latency_slo",
+		mcpServerID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"mcp_server_id": mcpServerID,
+			"agent_id":      agentID,
+		},
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// latencySLOConnection parses the MCP server and agent of a latency SLO route
+func latencySLOConnection(c fiber.Ctx) (mcpServerID, agentID uuid.UUID, err error) {
+	if mcpServerID, err = uuid.Parse(c.Params("id")); err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid MCP server ID")
+	}
+	if agentID, err = uuid.Parse(c.Params("agentId")); err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid agent ID")
+	}
+	return mcpServerID, agentID, nil
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type DataResidencyHandler struct {
+	residency    *application.DataResidencyService
+	auditService *application.AuditService
+}
+
+func NewDataResidencyHandler(
+	residency *application.DataResidencyService,
+	auditService *application.AuditService,
+) *DataResidencyHandler {
+	return &DataResidencyHandler{
+		residency:    residency,
+		auditService: auditService,
+	}
+}
+
+// GetResidency returns the region the organization's records are stored in
+// @Summary Get organization data residency
+// @Description The region the organization's agents, verification events and MCP attestations are stored in, the regions available, and how many records are placed in the region.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} application.OrganizationResidency
+// @Router /api/v1/admin/organization/residency [get]
+func (h *DataResidencyHandler) GetResidency(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	residency, err := h.residency.GetResidency(c.UserContext(), orgID)
+	if err != nil {
+		return residencyError(c, err, "Failed to fetch data residency")
+	}
+
+	return c.JSON(residency)
+}
+
+// SetResidency chooses the region the organization's records are stored in
+// @Summary Set organization data residency
+// @Description Only possible before the organization stores its first agent, verification event or MCP attestation; records are never moved between regions.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body application.SetDataResidencyRequest true "Region"
+// @Success 200 {object} application.OrganizationResidency
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/organization/residency [put]
+func (h *DataResidencyHandler) SetResidency(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.SetDataResidencyRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	residency, err := h.residency.SetResidency(c.UserContext(), orgID, req.Region)
+	if err != nil {
+		return residencyError(c, err, "Failed to set data residency")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"data_residency",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"region": residency.Region,
+		},
+	)
+
+	return c.JSON(residency)
+}
+
+func residencyError(c fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, application.ErrInvalidDataRegion),
+		errors.Is(err, domain.ErrRegionUnavailable):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrResidencyFixed):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
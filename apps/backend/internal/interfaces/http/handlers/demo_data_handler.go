@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type DemoDataHandler struct {
+	demoService  *application.DemoDataService
+	auditService *application.AuditService
+}
+
+func NewDemoDataHandler(
+	demoService *application.DemoDataService,
+	auditService *application.AuditService,
+) *DemoDataHandler {
+	return &DemoDataHandler{
+		demoService:  demoService,
+		auditService: auditService,
+	}
+}
+
+// GetDemoData reports whether the organization has demo data
+// @Summary Get demo data status
+// @Description Whether the organization has been seeded with demo data, when, and how many demo agents, MCP servers and incidents it holds
+// @Tags admin
+// @Produce json
+// @Success 200 {object} domain.DemoDataSummary
+// @Router /api/v1/admin/demo-data [get]
+func (h *DemoDataHandler) GetDemoData(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	summary, err := h.demoService.Status(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get demo data status",
+		})
+	}
+
+	return c.JSON(summary)
+}
+
+// SeedDemoData seeds the organization with demo data
+// @Summary Seed demo data
+// @Description Provisions a realistic demo dataset in the organization through the regular services: agents and MCP servers with attestations, a week of verification events including configuration drift, two weeks of trust history, alerts and incidents. Everything seeded is recorded so the teardown removes exactly that.
+// @Tags admin
+// @Produce json
+// @Success 201 {object} domain.DemoDataSummary
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/demo-data [post]
+func (h *DemoDataHandler) SeedDemoData(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	summary, err := h.demoService.Seed(c.UserContext(), orgID, userID)
+	if errors.Is(err, application.ErrDemoDataExists) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to seed demo data; tear down to remove what was seeded",
+			"details": err.Error(),
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"demo_data",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"agents":              summary.Agents,
+			"mcp_servers":         summary.MCPServers,
+			"incidents":           summary.Incidents,
+			"verification_events": summary.VerificationEvents,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(summary)
+}
+
+// TeardownDemoData removes the organization's demo data
+// @Summary Tear down demo data
+// @Description Removes the demo agents and MCP servers with their attestations, events, trust history and alerts, and the demo incidents. Resources the organization created itself are untouched.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} domain.DemoDataSummary
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/demo-data [delete]
+func (h *DemoDataHandler) TeardownDemoData(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	summary, err := h.demoService.Teardown(c.UserContext(), orgID)
+	if errors.Is(err, application.ErrNoDemoData) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to tear down demo data",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"demo_data",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"agents":      summary.Agents,
+			"mcp_servers": summary.MCPServers,
+			"incidents":   summary.Incidents,
+			"alerts":      summary.Alerts,
+		},
+	)
+
+	return c.JSON(summary)
+}
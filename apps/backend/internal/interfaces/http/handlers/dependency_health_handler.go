@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/infrastructure/resilience"
+)
+
+// DependencyHealthHandler reports the circuit breakers of outbound calls to external dependencies
+type DependencyHealthHandler struct {
+	registry *resilience.Registry
+}
+
+// NewDependencyHealthHandler creates a new dependency health handler
+func NewDependencyHealthHandler(registry *resilience.Registry) *DependencyHealthHandler {
+	return &DependencyHealthHandler{registry: registry}
+}
+
+// GetDependencies returns the health of each external dependency with the circuit of every
+// endpoint called since startup
+// @Summary Get external dependency health
+// @Description Circuit breaker state by dependency endpoint (MCP servers, webhooks, OSV, OPA, ticketing, notifications, object storage). Open circuits fail calls immediately until a trial call after the cooldown succeeds.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/dependencies [get]
+func (h *DependencyHealthHandler) GetDependencies(c fiber.Ctx) error {
+	dependencies := h.registry.Health()
+
+	status := resilience.HealthHealthy
+	for _, dependency := range dependencies {
+		if dependency.Status != resilience.HealthHealthy {
+			status = resilience.HealthDegraded
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"status":       status,
+		"dependencies": dependencies,
+	})
+}
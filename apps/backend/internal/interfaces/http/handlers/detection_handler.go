@@ -90,7 +90,7 @@ func (h *DetectionHandler) ReportDetection(c fiber.Ctx) error {
 
 	// Process detections
 	response, err := h.detectionService.ReportDetections(
-		c.Context(), agentID, orgID, &req)
+		c.UserContext(), agentID, orgID, &req)
 
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -100,7 +100,7 @@ func (h *DetectionHandler) ReportDetection(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionCreate,
@@ -148,7 +148,7 @@ func (h *DetectionHandler) GetDetectionStatus(c fiber.Ctx) error {
 	}
 
 	// Get detection status
-	status, err := h.detectionService.GetDetectionStatus(c.Context(), agentID, orgID)
+	status, err := h.detectionService.GetDetectionStatus(c.UserContext(), agentID, orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -224,7 +224,7 @@ func (h *DetectionHandler) ReportCapabilities(c fiber.Ctx) error {
 
 	// Process capability report
 	response, err := h.detectionService.ReportCapabilities(
-		c.Context(), agentID, orgID, &req)
+		c.UserContext(), agentID, orgID, &req)
 
 	if err != nil {
 		// Log the error for debugging
@@ -252,7 +252,7 @@ func (h *DetectionHandler) ReportCapabilities(c fiber.Ctx) error {
 	}
 
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionCreate,
@@ -296,7 +296,7 @@ func (h *DetectionHandler) GetLatestCapabilityReport(c fiber.Ctx) error {
 	}
 
 	// Get latest capability report
-	report, err := h.detectionService.GetLatestCapabilityReport(c.Context(), agentID, orgID)
+	report, err := h.detectionService.GetLatestCapabilityReport(c.UserContext(), agentID, orgID)
 	if err != nil {
 		if err.Error() == "agent not found" || err.Error() == "no capability reports found for this agent" {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type DriftAnalyticsHandler struct {
+	driftAnalyticsService *application.DriftAnalyticsService
+}
+
+func NewDriftAnalyticsHandler(driftAnalyticsService *application.DriftAnalyticsService) *DriftAnalyticsHandler {
+	return &DriftAnalyticsHandler{
+		driftAnalyticsService: driftAnalyticsService,
+	}
+}
+
+// GetOrganizationDriftTrends reports drift trends across every agent of the organization
+// @Summary Organization drift trends
+// @Description Drift frequency, time-to-remediation, most common unauthorized MCP servers and repeat-offender agents over time
+// @Tags analytics
+// @Produce json
+// @Param days query int false "Number of days (1-365)" default(30)
+// @Param interval query string false "Bucket interval (day, week, month); chosen from days when omitted"
+// @Param top query int false "Number of unauthorized servers to list (1-100)" default(10)
+// @Success 200 {object} domain.DriftTrendReport
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/analytics/drift [get]
+func (h *DriftAnalyticsHandler) GetOrganizationDriftTrends(c fiber.Ctx) error {
+	req, err := parseDriftTrendRequest(c, domain.DriftTrendScopeOrganization)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return h.respond(c, req)
+}
+
+// GetFleetDriftTrends reports drift trends with a per-agent breakdown, optionally for the agents carrying a tag
+// @Summary Fleet drift trends
+// @Description Drift trends for a fleet of agents (every agent, or those carrying tag_id) including a per-agent breakdown
+// @Tags analytics
+// @Produce json
+// @Param tag_id query string false "Only agents carrying this tag"
+// @Param days query int false "Number of days (1-365)" default(30)
+// @Param interval query string false "Bucket interval (day, week, month); chosen from days when omitted"
+// @Param top query int false "Number of unauthorized servers to list (1-100)" default(10)
+// @Success 200 {object} domain.DriftTrendReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/analytics/drift/fleet [get]
+func (h *DriftAnalyticsHandler) GetFleetDriftTrends(c fiber.Ctx) error {
+	req, err := parseDriftTrendRequest(c, domain.DriftTrendScopeFleet)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if tagID := c.Query("tag_id"); tagID != "" {
+		id, err := uuid.Parse(tagID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid tag ID",
+			})
+		}
+		req.TagID = &id
+	}
+
+	return h.respond(c, req)
+}
+
+// GetAgentDriftTrends reports drift trends for a single agent
+// @Summary Agent drift trends
+// @Description Drift frequency, time-to-remediation and unauthorized MCP servers over time for one agent
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param days query int false "Number of days (1-365)" default(30)
+// @Param interval query string false "Bucket interval (day, week, month); chosen from days when omitted"
+// @Param top query int false "Number of unauthorized servers to list (1-100)" default(10)
+// @Success 200 {object} domain.DriftTrendReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/drift/trends [get]
+func (h *DriftAnalyticsHandler) GetAgentDriftTrends(c fiber.Ctx) error {
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	req, err := parseDriftTrendRequest(c, domain.DriftTrendScopeAgent)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	req.AgentID = &agentID
+
+	return h.respond(c, req)
+}
+
+func (h *DriftAnalyticsHandler) respond(c fiber.Ctx, req *application.DriftTrendRequest) error {
+	report, err := h.driftAnalyticsService.GetDriftTrends(c.UserContext(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrInvalidDriftTrendRequest):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, application.ErrDriftTrendSubjectNotFound):
+			if req.Scope == domain.DriftTrendScopeAgent {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "Agent not found",
+				})
+			}
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Tag not found",
+			})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to compute drift trends",
+			})
+		}
+	}
+
+	return c.JSON(report)
+}
+
+// parseDriftTrendRequest reads the days, interval and top query parameters shared by every drift trend endpoint
+func parseDriftTrendRequest(c fiber.Ctx, scope domain.DriftTrendScope) (*application.DriftTrendRequest, error) {
+	req := &application.DriftTrendRequest{
+		OrganizationID: c.Locals("organization_id").(uuid.UUID),
+		Scope:          scope,
+		Interval:       domain.DriftTrendInterval(c.Query("interval")),
+	}
+
+	for name, target := range map[string]*int{"days": &req.Days, "top": &req.TopServers} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s parameter", name)
+		}
+		*target = parsed
+	}
+
+	return req, nil
+}
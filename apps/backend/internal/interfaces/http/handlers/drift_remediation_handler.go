@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type DriftRemediationHandler struct {
+	remediationService *application.DriftRemediationService
+	auditService       *application.AuditService
+}
+
+func NewDriftRemediationHandler(
+	remediationService *application.DriftRemediationService,
+	auditService *application.AuditService,
+) *DriftRemediationHandler {
+	return &DriftRemediationHandler{
+		remediationService: remediationService,
+		auditService:       auditService,
+	}
+}
+
+// ListRemediations lists the change sets proposed with configuration drift alerts
+// @Summary List drift remediations
+// @Description Change sets proposed with configuration drift alerts, newest first
+// @Tags admin
+// @Produce json
+// @Param status query string false "pending, approved or rejected"
+// @Param limit query int false "Limit" default(100)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/drift-remediations [get]
+func (h *DriftRemediationHandler) ListRemediations(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	status := domain.DriftRemediationStatus(c.Query("status"))
+	switch status {
+	case "", domain.DriftRemediationStatusPending, domain.DriftRemediationStatusApproved, domain.DriftRemediationStatusRejected:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "status must be pending, approved or rejected",
+		})
+	}
+
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
+			limit = parsedLimit
+		}
+	}
+	offset := 0
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil {
+			offset = parsedOffset
+		}
+	}
+
+	remediations, err := h.remediationService.ListRemediations(c.UserContext(), orgID, status, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list drift remediations",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"remediations": remediations,
+		"limit":        limit,
+		"offset":       offset,
+	})
+}
+
+// GetRemediation returns a drift remediation
+// @Summary Get drift remediation
+// @Tags admin
+// @Produce json
+// @Param id path string true "Drift remediation ID"
+// @Success 200 {object} domain.DriftRemediation
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/drift-remediations/{id} [get]
+func (h *DriftRemediationHandler) GetRemediation(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid drift remediation ID",
+		})
+	}
+
+	remediation, err := h.remediationService.GetRemediation(c.UserContext(), orgID, id)
+	if err != nil {
+		return driftRemediationError(c, err, "Failed to fetch drift remediation")
+	}
+	return c.JSON(remediation)
+}
+
+// GetAlertRemediation returns the change set proposed with a configuration drift alert
+// @Summary Get the drift remediation of an alert
+// @Tags admin
+// @Produce json
+// @Param id path string true "Alert ID"
+// @Success 200 {object} domain.DriftRemediation
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/alerts/{id}/remediation [get]
+func (h *DriftRemediationHandler) GetAlertRemediation(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	alertID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid alert ID",
+		})
+	}
+
+	remediation, err := h.remediationService.GetRemediationForAlert(c.UserContext(), orgID, alertID)
+	if err != nil {
+		return driftRemediationError(c, err, "Failed to fetch drift remediation")
+	}
+	return c.JSON(remediation)
+}
+
+// ApproveRemediation registers the change set's MCP servers for the agent
+// @Summary Approve drift remediation
+// @Description Adds the MCP servers to the agent's registered talks_to and acknowledges the drift alert
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Drift remediation ID"
+// @Param request body object false "Optional reason"
+// @Success 200 {object} domain.DriftRemediation
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/drift-remediations/{id}/approve [post]
+func (h *DriftRemediationHandler) ApproveRemediation(c fiber.Ctx) error {
+	return h.decide(c, true)
+}
+
+// RejectRemediation enforces the config drift policy against the agent
+// @Summary Reject drift remediation
+// @Description Leaves the agent's registration unchanged and applies the enforcement action of the organization's config_drift policy: block_and_alert (the default) suspends the agent, alert_only raises an alert
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Drift remediation ID"
+// @Param request body object false "Optional reason"
+// @Success 200 {object} domain.DriftRemediation
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/drift-remediations/{id}/reject [post]
+func (h *DriftRemediationHandler) RejectRemediation(c fiber.Ctx) error {
+	return h.decide(c, false)
+}
+
+func (h *DriftRemediationHandler) decide(c fiber.Ctx, approve bool) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid drift remediation ID",
+		})
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	var remediation *domain.DriftRemediation
+	if approve {
+		remediation, err = h.remediationService.Approve(c.UserContext(), orgID, id, userID, req.Reason)
+	} else {
+		remediation, err = h.remediationService.Reject(c.UserContext(), orgID, id, userID, req.Reason)
+	}
+	if err != nil {
+		return driftRemediationError(c, err, "Failed to decide drift remediation")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"drift_remediation",
+		remediation.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"status":             remediation.Status,
+			"agent_id":           remediation.AgentID,
+			"mcp_servers":        remediation.MCPServers,
+			"enforcement_action": remediation.EnforcementAction,
+			"policy_name":        remediation.PolicyName,
+			"reason":             remediation.Reason,
+		},
+	)
+
+	return c.JSON(remediation)
+}
+
+func driftRemediationError(c fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrDriftRemediationNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Drift remediation not found",
+		})
+	case errors.Is(err, application.ErrDriftRemediationDecided):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
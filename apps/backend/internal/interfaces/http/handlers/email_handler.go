@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/url"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/email"
+)
+
+type EmailHandler struct {
+	emailQueue   *application.EmailQueueService
+	auditService *application.AuditService
+}
+
+func NewEmailHandler(
+	emailQueue *application.EmailQueueService,
+	auditService *application.AuditService,
+) *EmailHandler {
+	return &EmailHandler{
+		emailQueue:   emailQueue,
+		auditService: auditService,
+	}
+}
+
+// GetSender returns the address the organization's mail is sent from
+// @Summary Get organization email sender
+// @Tags admin
+// @Produce json
+// @Success 200 {object} domain.OrganizationEmailSender
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/organization/email-sender [get]
+func (h *EmailHandler) GetSender(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	sender, err := h.emailQueue.GetSender(c.UserContext(), orgID)
+	if err != nil {
+		return emailError(c, err, "Failed to fetch email sender")
+	}
+
+	return c.JSON(sender)
+}
+
+// SetSender makes the organization's mail be sent from its own address
+// @Summary Set organization email sender
+// @Description Password resets, approvals, capability decisions and alert digests of the organization are sent from fromAddress instead of the platform's address.
+// @Description The email provider has to accept the address, e.g. a verified SES identity or SendGrid sender. Emails already queued keep their sender.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body application.SetEmailSenderRequest true "Email sender"
+// @Success 200 {object} domain.OrganizationEmailSender
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/organization/email-sender [put]
+func (h *EmailHandler) SetSender(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.SetEmailSenderRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	sender, err := h.emailQueue.SetSender(c.UserContext(), orgID, userID, &req)
+	if err != nil {
+		return emailError(c, err, "Failed to set email sender")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"email_sender",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"fromAddress": sender.FromAddress,
+			"fromName":    sender.FromName,
+			"replyTo":     sender.ReplyTo,
+		},
+	)
+
+	return c.JSON(sender)
+}
+
+// DeleteSender makes the organization's mail be sent from the platform's address again
+// @Summary Delete organization email sender
+// @Tags admin
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/organization/email-sender [delete]
+func (h *EmailHandler) DeleteSender(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.emailQueue.DeleteSender(c.UserContext(), orgID); err != nil {
+		return emailError(c, err, "Failed to delete email sender")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"email_sender",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		nil,
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListMessages lists the emails sent on behalf of the organization
+// @Summary List organization emails
+// @Description Queued, sent, failed, bounced and suppressed emails, newest first. Bodies are not returned.
+// @Tags admin
+// @Produce json
+// @Param status query string false "queued, sending, sent, failed, bounced or suppressed"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/emails [get]
+func (h *EmailHandler) ListMessages(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	messages, total, err := h.emailQueue.ListMessages(c.UserContext(), domain.EmailMessageFilter{
+		OrganizationID: orgID,
+		Status:         domain.EmailMessageStatus(c.Query("status")),
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch emails",
+		})
+	}
+
+	if messages == nil {
+		messages = []*domain.EmailMessage{}
+	}
+
+	return c.JSON(fiber.Map{
+		"emails": messages,
+		"total":  total,
+	})
+}
+
+// ReceiveSendGridEvents records the bounces and spam reports of a SendGrid event webhook
+// @Summary Receive SendGrid events
+// @Description Configure the SendGrid event webhook with ?secret=<EMAIL_WEBHOOK_SECRET>. Bounces and spam reports suppress the address; other events are ignored.
+// @Tags email
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/public/email/events/sendgrid [post]
+func (h *EmailHandler) ReceiveSendGridEvents(c fiber.Ctx) error {
+	bounces, err := email.ParseSendGridEvents(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	bounced, err := h.emailQueue.RecordBounces(c.UserContext(), webhookSecret(c), bounces)
+	if err != nil {
+		return emailError(c, err, "Failed to record email events")
+	}
+
+	return c.JSON(fiber.Map{
+		"bounced": bounced,
+	})
+}
+
+// ReceiveSESEvents records the bounces and complaints of an Amazon SNS topic SES publishes to
+// @Summary Receive Amazon SES notifications
+// @Description Subscribe https://<host>/api/v1/public/email/events/ses?secret=<EMAIL_WEBHOOK_SECRET> to the SNS topic of the SES bounce and complaint notifications.
+// @Description The subscription confirmation URL is logged for an operator to visit.
+// @Tags email
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/public/email/events/ses [post]
+func (h *EmailHandler) ReceiveSESEvents(c fiber.Ctx) error {
+	bounces, subscribeURL, err := email.ParseSESNotification(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	bounced, err := h.emailQueue.RecordBounces(c.UserContext(), webhookSecret(c), bounces)
+	if err != nil {
+		return emailError(c, err, "Failed to record email events")
+	}
+
+	// The confirmation URL is not fetched from here: only an operator can tell it comes from
+	// the AWS account SES sends with
+	if subscribeURL != "" {
+		log.Printf("ℹ️  Amazon SNS asks to confirm the SES notification subscription; visit %s", subscribeURL)
+	}
+
+	return c.JSON(fiber.Map{
+		"bounced": bounced,
+	})
+}
+
+// webhookSecret returns the shared secret a provider webhook authenticates with
+func webhookSecret(c fiber.Ctx) string {
+	if secret := c.Get("X-AIM-Webhook-Secret"); secret != "" {
+		return secret
+	}
+	return c.Query("secret")
+}
+
+// ListSuppressions lists the addresses no more mail is sent to
+// @Summary List email suppressions
+// @Tags operator
+// @Produce json
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /operator/v1/email/suppressions [get]
+func (h *EmailHandler) ListSuppressions(c fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	suppressions, total, err := h.emailQueue.ListSuppressions(c.UserContext(), limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch email suppressions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"suppressions": suppressions,
+		"total":        total,
+	})
+}
+
+// RemoveSuppression lets mail be sent to a suppressed address again
+// @Summary Remove email suppression
+// @Tags operator
+// @Param address path string true "Suppressed address"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /operator/v1/email/suppressions/{address} [delete]
+func (h *EmailHandler) RemoveSuppression(c fiber.Ctx) error {
+	address, err := url.PathUnescape(c.Params("address"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid address",
+		})
+	}
+
+	if err := h.emailQueue.RemoveSuppression(c.UserContext(), operatorOf(c), c.IP(), address); err != nil {
+		return emailError(c, err, "Failed to remove email suppression")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func emailError(c fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrEmailSenderNotFound),
+		errors.Is(err, domain.ErrEmailSuppressionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrInvalidEmailSender):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrInvalidEmailWebhookSecret):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type EmergencyAccessHandler struct {
+	emergencyService *application.EmergencyAccessService
+	agentService     *application.AgentService
+	auditService     *application.AuditService
+}
+
+func NewEmergencyAccessHandler(
+	emergencyService *application.EmergencyAccessService,
+	agentService *application.AgentService,
+	auditService *application.AuditService,
+) *EmergencyAccessHandler {
+	return &EmergencyAccessHandler{
+		emergencyService: emergencyService,
+		agentService:     agentService,
+		auditService:     auditService,
+	}
+}
+
+// ActivateEmergencyAccessRequest is the break-glass request body
+type ActivateEmergencyAccessRequest struct {
+	Credential string `json:"credential"` // Secret opened from the sealed credential
+	Reason     string `json:"reason"`
+}
+
+// RevokeEmergencyCredentialRequest is the optional body when revoking a credential
+type RevokeEmergencyCredentialRequest struct {
+	Reason string `json:"reason"`
+}
+
+// GenerateCredential pre-generates a sealed emergency credential for an agent
+// @Summary Generate emergency credential
+// @Description Generate a single-use break-glass credential for a critical agent. The secret is sealed to the organization's X25519 public key and is never stored; keep the sealed_secret somewhere the deployment can reach if token refresh breaks.
+// @Tags emergency-access
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param request body application.GenerateEmergencyCredentialRequest true "Organization sealing key and validity"
+// @Success 201 {object} application.SealedEmergencyCredential
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/agents/{id}/emergency-credentials [post]
+func (h *EmergencyAccessHandler) GenerateCredential(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	agent, status, message := h.getOwnedAgent(c)
+	if agent == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	var req application.GenerateEmergencyCredentialRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	sealed, err := h.emergencyService.GenerateCredential(c.UserContext(), agent, &req, userID)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidEmergencyCredentialRequest) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate emergency credential",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionGenerate,
+		"emergency_credential",
+		sealed.Credential.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"agent_id":        agent.ID.String(),
+			"agent_name":      agent.Name,
+			"key_fingerprint": sealed.Credential.KeyFingerprint,
+			"expires_at":      sealed.Credential.ExpiresAt,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(sealed)
+}
+
+// ListCredentials lists an agent's emergency credentials
+// @Summary List emergency credentials
+// @Tags emergency-access
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/agents/{id}/emergency-credentials [get]
+func (h *EmergencyAccessHandler) ListCredentials(c fiber.Ctx) error {
+	agent, status, message := h.getOwnedAgent(c)
+	if agent == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	credentials, err := h.emergencyService.ListCredentials(c.UserContext(), agent.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch emergency credentials",
+		})
+	}
+
+	if credentials == nil {
+		credentials = []*domain.EmergencyCredential{}
+	}
+
+	return c.JSON(fiber.Map{
+		"credentials": credentials,
+		"total":       len(credentials),
+	})
+}
+
+// RevokeCredential revokes an unused emergency credential
+// @Summary Revoke emergency credential
+// @Tags emergency-access
+// @Accept json
+// @Param id path string true "Credential ID"
+// @Param request body RevokeEmergencyCredentialRequest false "Revocation reason"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/emergency-credentials/{id} [delete]
+func (h *EmergencyAccessHandler) RevokeCredential(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	credentialID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid credential ID",
+		})
+	}
+
+	credential, err := h.emergencyService.GetCredential(c.UserContext(), credentialID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Emergency credential not found",
+		})
+	}
+	if credential.OrganizationID != orgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied",
+		})
+	}
+
+	// The body is optional
+	var req RevokeEmergencyCredentialRequest
+	_ = c.Bind().JSON(&req)
+
+	if err := h.emergencyService.RevokeCredential(c.UserContext(), credential.ID, req.Reason); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke emergency credential",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionRevoke,
+		"emergency_credential",
+		credential.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"agent_id": credential.AgentID.String(),
+			"reason":   req.Reason,
+		},
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Activate is the break-glass endpoint for deployments whose token refresh chain is broken
+// @Summary Activate emergency access
+// @Description Exchange an opened emergency credential for a one-hour access token. The credential is single-use: it is rotated to a new sealed credential, a critical alert is raised and every attempt is audited. No refresh token is issued, so fresh SDK credentials must be obtained before the token expires.
+// @Tags emergency-access
+// @Accept json
+// @Produce json
+// @Param request body ActivateEmergencyAccessRequest true "Credential and reason"
+// @Success 200 {object} application.EmergencyAccessGrant
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/auth/emergency-access [post]
+func (h *EmergencyAccessHandler) Activate(c fiber.Ctx) error {
+	var req ActivateEmergencyAccessRequest
+	if err := c.Bind().JSON(&req); err != nil || req.Credential == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "credential is required",
+		})
+	}
+
+	grant, credential, err := h.emergencyService.Activate(c.UserContext(), req.Credential, req.Reason, c.IP())
+
+	// Every attempt against a known credential is audited, successful or not
+	if credential != nil {
+		details := map[string]interface{}{
+			"agent_id": credential.AgentID.String(),
+			"reason":   req.Reason,
+			"success":  err == nil,
+		}
+		if err != nil {
+			details["error"] = err.Error()
+		} else if grant.Replacement != nil {
+			details["replacement_credential_id"] = grant.Replacement.Credential.ID.String()
+			details["access_expires_at"] = grant.ExpiresAt
+		}
+
+		h.auditService.LogAction(
+			c.UserContext(),
+			credential.OrganizationID,
+			credential.UserID,
+			domain.AuditActionLogin,
+			"emergency_credential",
+			credential.ID,
+			c.IP(),
+			c.Get("User-Agent"),
+			details,
+		)
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrEmergencyReasonRequired):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, application.ErrEmergencyCredentialInvalid), errors.Is(err, application.ErrEmergencyCredentialUsed):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to activate emergency access",
+			})
+		}
+	}
+
+	return c.JSON(grant)
+}
+
+// getOwnedAgent loads the agent in the :id param and verifies it belongs to the caller's organization.
+// On failure it returns a nil agent with the HTTP status and message to respond with.
+func (h *EmergencyAccessHandler) getOwnedAgent(c fiber.Ctx) (*domain.Agent, int, string) {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, fiber.StatusBadRequest, "Invalid agent ID"
+	}
+
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
+	if err != nil {
+		return nil, fiber.StatusNotFound, "Agent not found"
+	}
+	if agent.OrganizationID != orgID {
+		return nil, fiber.StatusForbidden, "Access denied"
+	}
+
+	return agent, fiber.StatusOK, ""
+}
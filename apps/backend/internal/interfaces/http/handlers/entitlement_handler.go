@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type EntitlementHandler struct {
+	entitlementService *application.EntitlementService
+	auditService       *application.AuditService
+}
+
+func NewEntitlementHandler(
+	entitlementService *application.EntitlementService,
+	auditService *application.AuditService,
+) *EntitlementHandler {
+	return &EntitlementHandler{
+		entitlementService: entitlementService,
+		auditService:       auditService,
+	}
+}
+
+// ReviewMCPServer lists every agent currently authorized to use an MCP server
+// @Summary Entitlement review for an MCP server
+// @Description Agents authorized to use the MCP server, their owners, how access was granted and when it was last used
+// @Tags entitlements
+// @Produce json,text/csv
+// @Param id path string true "MCP server ID"
+// @Param format query string false "Response format (json or csv)" default(json)
+// @Success 200 {object} domain.EntitlementReview
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/entitlements/mcp-servers/{id} [get]
+func (h *EntitlementHandler) ReviewMCPServer(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	mcpServerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid MCP server ID",
+		})
+	}
+
+	review, err := h.entitlementService.ReviewMCPServer(c.UserContext(), orgID, mcpServerID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "MCP server not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to build entitlement review",
+		})
+	}
+
+	return h.respond(c, review)
+}
+
+// ReviewCapability lists every agent currently holding a capability
+// @Summary Entitlement review for a capability
+// @Description Agents holding the capability, their owners, how it was granted and when it was last used
+// @Tags entitlements
+// @Produce json,text/csv
+// @Param capability path string true "Capability type (e.g. file:read)"
+// @Param format query string false "Response format (json or csv)" default(json)
+// @Success 200 {object} domain.EntitlementReview
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/entitlements/capabilities/{capability} [get]
+func (h *EntitlementHandler) ReviewCapability(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	capabilityType, err := url.PathUnescape(c.Params("capability"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid capability",
+		})
+	}
+
+	review, err := h.entitlementService.ReviewCapability(c.UserContext(), orgID, capabilityType)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return h.respond(c, review)
+}
+
+// respond audits the review and writes it as JSON or CSV
+func (h *EntitlementHandler) respond(c fiber.Ctx, review *domain.EntitlementReview) error {
+	format := c.Query("format", "json")
+	if format != "json" && format != "csv" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid format. Supported formats: json, csv",
+		})
+	}
+
+	resourceID := uuid.Nil
+	if review.ResourceID != nil {
+		resourceID = *review.ResourceID
+	}
+	h.auditService.LogAction(
+		c.UserContext(),
+		review.OrganizationID,
+		c.Locals("user_id").(uuid.UUID),
+		domain.AuditActionView,
+		"entitlement_review",
+		resourceID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"resource_type": review.ResourceType,
+			"resource_name": review.ResourceName,
+			"format":        format,
+			"total":         review.Total,
+		},
+	)
+
+	if format == "json" {
+		return c.JSON(review)
+	}
+
+	data, err := entitlementReviewCSV(review)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to render entitlement review",
+		})
+	}
+
+	c.Set("Content-Type", "text/csv")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=entitlements-%s-%s.csv",
+		review.ResourceType, review.GeneratedAt.Format("20060102")))
+	return c.Send(data)
+}
+
+// entitlementReviewCSV renders one row per agent; grants are joined into a single column
+func entitlementReviewCSV(review *domain.EntitlementReview) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := []string{"agent_id", "agent_name", "agent_status", "trust_score", "owner_name", "owner_email", "granted_via", "first_granted_at", "last_used_at"}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, e := range review.Entitlements {
+		grants := make([]string, 0, len(e.Grants))
+		var firstGranted time.Time
+		for _, g := range e.Grants {
+			grant := string(g.GrantType)
+			if g.GrantedByName != "" {
+				grant += " by " + g.GrantedByName
+			}
+			grants = append(grants, grant)
+			if firstGranted.IsZero() || g.GrantedAt.Before(firstGranted) {
+				firstGranted = g.GrantedAt
+			}
+		}
+
+		lastUsed := "never"
+		if e.LastUsedAt != nil {
+			lastUsed = e.LastUsedAt.UTC().Format(time.RFC3339)
+		}
+
+		err := w.Write([]string{
+			e.AgentID.String(),
+			e.AgentName,
+			string(e.AgentStatus),
+			fmt.Sprintf("%.3f", e.TrustScore),
+			e.OwnerName,
+			e.OwnerEmail,
+			strings.Join(grants, "; "),
+			firstGranted.UTC().Format(time.RFC3339),
+			lastUsed,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
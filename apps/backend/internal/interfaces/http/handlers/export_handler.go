@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/crypto"
+)
+
+// ExportHandler lets recipients of export files verify their signatures
+type ExportHandler struct {
+	signer *crypto.ExportSigner
+}
+
+func NewExportHandler(signer *crypto.ExportSigner) *ExportHandler {
+	return &ExportHandler{
+		signer: signer,
+	}
+}
+
+// VerifyExport checks that an export file is unchanged since it was downloaded
+// @Summary Verify export signature
+// @Description Check an export file (CSV, JSON or archive) against the signature it was downloaded with. Send the file as multipart "file" with "signature" and optional "key_id" fields, or as the raw request body with the X-AIM-Export-Signature and X-AIM-Export-Key-Id headers of the download.
+// @Tags exports
+// @Accept multipart/form-data
+// @Accept octet-stream
+// @Produce json
+// @Param file formData file false "Export file"
+// @Param signature formData string false "Hex signature from the X-AIM-Export-Signature header"
+// @Param key_id formData string false "Key ID from the X-AIM-Export-Key-Id header"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/public/exports/verify [post]
+func (h *ExportHandler) VerifyExport(c fiber.Ctx) error {
+	data := c.Body()
+	signature := c.Get(crypto.ExportSignatureHeader)
+	keyID := c.Get(crypto.ExportSignatureKeyIDHeader)
+
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Failed to read file",
+			})
+		}
+		defer f.Close()
+		if data, err = io.ReadAll(f); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Failed to read file",
+			})
+		}
+		signature = c.FormValue("signature")
+		keyID = c.FormValue("key_id")
+	}
+
+	if len(data) == 0 || signature == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "An export file and its signature are required",
+		})
+	}
+
+	signedBy, valid := h.signer.Verify(data, signature, keyID)
+	sum := sha256.Sum256(data)
+	response := fiber.Map{
+		"valid":     valid,
+		"algorithm": crypto.ExportSignatureAlgorithm,
+		"sha256":    hex.EncodeToString(sum[:]),
+		"size":      len(data),
+	}
+	if valid {
+		response["keyId"] = signedBy
+	}
+	return c.JSON(response)
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type FeatureFlagHandler struct {
+	featureFlagService *application.FeatureFlagService
+	auditService       *application.AuditService
+}
+
+func NewFeatureFlagHandler(
+	featureFlagService *application.FeatureFlagService,
+	auditService *application.AuditService,
+) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		featureFlagService: featureFlagService,
+		auditService:       auditService,
+	}
+}
+
+// ListFeatures lists the preview features and whether each is on for the current user
+// @Summary List preview features
+// @Description Every feature flag with its state for the user: source is default, organization or user.
+// @Tags features
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/features [get]
+func (h *FeatureFlagHandler) ListFeatures(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	features, err := h.featureFlagService.ListFeatures(c.UserContext(), orgID, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch features",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"features": features,
+		"total":    len(features),
+	})
+}
+
+// ListSDKFeatures lists the protocol-level previews and whether each is on for the agent's organization
+// @Summary List SDK preview features
+// @Tags features
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/sdk-api/features [get]
+func (h *FeatureFlagHandler) ListSDKFeatures(c fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	features, err := h.featureFlagService.ListSDKFeatures(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch features",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"features": features,
+		"total":    len(features),
+	})
+}
+
+// SetUserFeature opts the current user in or out of a preview feature
+// @Summary Opt in or out of a preview feature
+// @Description Only flags with userOptIn can be chosen by users. A feature the organization turned off stays off.
+// @Tags features
+// @Accept json
+// @Produce json
+// @Param key path string true "Feature flag key"
+// @Param request body application.SetFeatureRequest true "Whether the feature is on"
+// @Success 200 {object} domain.FeatureState
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/features/{key}/opt-in [put]
+func (h *FeatureFlagHandler) SetUserFeature(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	key := c.Params("key")
+
+	var req application.SetFeatureRequest
+	if err := c.Bind().JSON(&req); err != nil || req.Enabled == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "enabled is required",
+		})
+	}
+
+	state, err := h.featureFlagService.SetUserFeature(c.UserContext(), orgID, userID, key, *req.Enabled)
+	if err != nil {
+		return h.featureError(c, err, "Failed to save feature opt-in")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"feature_opt_in",
+		userID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"feature": key,
+			"enabled": *req.Enabled,
+		},
+	)
+
+	return c.JSON(state)
+}
+
+// ClearUserFeature drops the current user's choice for a preview feature
+// @Summary Clear a preview feature opt-in
+// @Tags features
+// @Param key path string true "Feature flag key"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/features/{key}/opt-in [delete]
+func (h *FeatureFlagHandler) ClearUserFeature(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	key := c.Params("key")
+
+	if err := h.featureFlagService.ClearUserFeature(c.UserContext(), orgID, userID, key); err != nil {
+		return h.featureError(c, err, "Failed to clear feature opt-in")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"feature_opt_in",
+		userID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"feature": key,
+		},
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// SetOrganizationFeature turns a preview feature on or off for the organization
+// @Summary Turn a preview feature on or off for the organization
+// @Description Turning a feature off overrides users' opt-ins.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key path string true "Feature flag key"
+// @Param request body application.SetFeatureRequest true "Whether the feature is on"
+// @Success 200 {object} domain.FeatureState
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/features/{key} [put]
+func (h *FeatureFlagHandler) SetOrganizationFeature(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	key := c.Params("key")
+
+	var req application.SetFeatureRequest
+	if err := c.Bind().JSON(&req); err != nil || req.Enabled == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "enabled is required",
+		})
+	}
+
+	state, err := h.featureFlagService.SetOrganizationFeature(c.UserContext(), orgID, userID, key, *req.Enabled)
+	if err != nil {
+		return h.featureError(c, err, "Failed to save feature")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"feature_flag",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"feature": key,
+			"enabled": *req.Enabled,
+		},
+	)
+
+	return c.JSON(state)
+}
+
+// ClearOrganizationFeature returns the organization to a preview feature's default
+// @Summary Reset a preview feature to its default
+// @Tags admin
+// @Param key path string true "Feature flag key"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/features/{key} [delete]
+func (h *FeatureFlagHandler) ClearOrganizationFeature(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	key := c.Params("key")
+
+	if err := h.featureFlagService.ClearOrganizationFeature(c.UserContext(), orgID, key); err != nil {
+		return h.featureError(c, err, "Failed to reset feature")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"feature_flag",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"feature": key,
+		},
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// featureError answers a failed feature flag change
+func (h *FeatureFlagHandler) featureError(c fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrFeatureFlagNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Feature not found",
+		})
+	case errors.Is(err, application.ErrFeatureOptInNotAllowed):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/interfaces/graphql"
+)
+
+type GraphQLHandler struct {
+	dashboard *graphql.Dashboard
+}
+
+func NewGraphQLHandler(dashboard *graphql.Dashboard) *GraphQLHandler {
+	return &GraphQLHandler{
+		dashboard: dashboard,
+	}
+}
+
+// Query runs a GraphQL query against the dashboard schema
+// @Summary Run GraphQL query
+// @Description Fetches agents, MCP servers, trust scores, tags, alerts and attestations of the caller's organization in one request. Only queries are supported; changes go through the REST API. Queries exceeding the depth or complexity limits are rejected before they run. Field errors are reported in "errors" next to the partial "data".
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param request body graphql.Request true "Query, operation name and variables"
+// @Success 200 {object} graphql.Response
+// @Failure 400 {object} graphql.Response
+// @Router /api/v1/graphql [post]
+func (h *GraphQLHandler) Query(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	var req graphql.Request
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.Query == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "query is required",
+		})
+	}
+
+	response := h.dashboard.Execute(c.UserContext(), orgID, &req)
+	if response.Data == nil {
+		// Rejected before execution: syntax, schema or limit errors
+		return c.Status(fiber.StatusBadRequest).JSON(response)
+	}
+	return c.JSON(response)
+}
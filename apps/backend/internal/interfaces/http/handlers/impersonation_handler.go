@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ImpersonationHandler lets organization admins decide on platform operators' requests to act
+// in their organization
+type ImpersonationHandler struct {
+	operatorService *application.PlatformOperatorService
+	auditService    *application.AuditService
+}
+
+func NewImpersonationHandler(
+	operatorService *application.PlatformOperatorService,
+	auditService *application.AuditService,
+) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		operatorService: operatorService,
+		auditService:    auditService,
+	}
+}
+
+// ListRequests lists the organization's impersonation requests
+// @Summary List impersonation requests
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/impersonation-requests [get]
+func (h *ImpersonationHandler) ListRequests(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	requests, err := h.operatorService.ListOrganizationImpersonations(c.UserContext(), orgID, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch impersonation requests",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"impersonations": requests,
+		"total":          len(requests),
+	})
+}
+
+// ApproveRequest consents to a platform operator acting in the organization
+// @Summary Approve impersonation request
+// @Description The operator's session acts as you, with the requested role, for the requested duration. Revoke it at any time.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Impersonation request ID"
+// @Success 200 {object} domain.ImpersonationRequest
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/impersonation-requests/{id}/approve [post]
+func (h *ImpersonationHandler) ApproveRequest(c fiber.Ctx) error {
+	return h.decide(c, true)
+}
+
+// DenyRequest refuses a platform operator's request
+// @Summary Deny impersonation request
+// @Tags admin
+// @Produce json
+// @Param id path string true "Impersonation request ID"
+// @Success 200 {object} domain.ImpersonationRequest
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/impersonation-requests/{id}/deny [post]
+func (h *ImpersonationHandler) DenyRequest(c fiber.Ctx) error {
+	return h.decide(c, false)
+}
+
+func (h *ImpersonationHandler) decide(c fiber.Ctx, approve bool) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid impersonation request ID",
+		})
+	}
+
+	request, err := h.operatorService.DecideImpersonation(c.UserContext(), orgID, id, userID, approve)
+	if err != nil {
+		return operatorError(c, err)
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"impersonation_request",
+		request.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"status":           request.Status,
+			"operator_email":   request.OperatorEmail,
+			"role":             request.Role,
+			"duration_minutes": request.DurationMinutes,
+		},
+	)
+
+	return c.JSON(request)
+}
+
+// RevokeRequest withdraws consent to an approved or active impersonation
+// @Summary Revoke impersonation
+// @Description An active session stops working on its next request.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Impersonation request ID"
+// @Success 200 {object} domain.ImpersonationRequest
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/impersonation-requests/{id}/revoke [post]
+func (h *ImpersonationHandler) RevokeRequest(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid impersonation request ID",
+		})
+	}
+
+	request, err := h.operatorService.RevokeImpersonation(c.UserContext(), orgID, id)
+	if err != nil {
+		return operatorError(c, err)
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionRevoke,
+		"impersonation_request",
+		request.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"operator_email": request.OperatorEmail,
+		},
+	)
+
+	return c.JSON(request)
+}
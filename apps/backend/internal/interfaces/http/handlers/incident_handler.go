@@ -0,0 +1,470 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type IncidentHandler struct {
+	incidentService *application.IncidentService
+	auditService    *application.AuditService
+}
+
+func NewIncidentHandler(
+	incidentService *application.IncidentService,
+	auditService *application.AuditService,
+) *IncidentHandler {
+	return &IncidentHandler{
+		incidentService: incidentService,
+		auditService:    auditService,
+	}
+}
+
+// ChangeIncidentStatusRequest moves an incident to a new status
+type ChangeIncidentStatusRequest struct {
+	Status domain.IncidentStatus `json:"status"`
+	Notes  string                `json:"notes"` // Kept as the resolution notes when resolving
+}
+
+// AssignIncidentRequest assigns an incident; a null assigneeId unassigns it
+type AssignIncidentRequest struct {
+	AssigneeID *uuid.UUID `json:"assigneeId"`
+}
+
+// AddIncidentCommentRequest is a comment on an incident
+type AddIncidentCommentRequest struct {
+	Body string `json:"body"`
+}
+
+// LinkIncidentResourceRequest links a threat, anomaly or verification event to an incident
+type LinkIncidentResourceRequest struct {
+	ResourceType domain.IncidentLinkType `json:"resourceType"`
+	ResourceID   uuid.UUID               `json:"resourceId"`
+}
+
+// ListIncidents lists security incidents with their SLA timers
+// @Summary List security incidents
+// @Tags security
+// @Produce json
+// @Param status query string false "Filter by status (open, investigating, resolved, false_positive)"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/security/incidents [get]
+func (h *IncidentHandler) ListIncidents(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > maxSecuritySearchLimit {
+		limit = maxSecuritySearchLimit
+	}
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	incidents, err := h.incidentService.ListIncidents(c.UserContext(), orgID, domain.IncidentStatus(c.Query("status")), limit, offset)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidIncidentStatus) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch incidents",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"incidents": incidents,
+		"limit":     limit,
+		"offset":    offset,
+	})
+}
+
+// GetIncident returns a security incident with its SLA timers and linked resources
+// @Summary Get security incident
+// @Tags security
+// @Produce json
+// @Param id path string true "Incident ID"
+// @Success 200 {object} application.IncidentDetail
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/security/incidents/{id} [get]
+func (h *IncidentHandler) GetIncident(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	incidentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid incident ID",
+		})
+	}
+
+	incident, err := h.incidentService.GetIncident(c.UserContext(), orgID, incidentID)
+	if err != nil {
+		return h.incidentError(c, err, "Failed to fetch incident")
+	}
+
+	return c.JSON(incident)
+}
+
+// ChangeStatus moves a security incident to a new status
+// @Summary Change security incident status
+// @Description Resolved and false positive incidents are closed and cannot change again. Leaving open acknowledges the incident and stops its response SLA timer.
+// @Tags security
+// @Accept json
+// @Produce json
+// @Param id path string true "Incident ID"
+// @Param request body ChangeIncidentStatusRequest true "New status"
+// @Success 200 {object} application.IncidentDetail
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/security/incidents/{id}/status [put]
+func (h *IncidentHandler) ChangeStatus(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	incidentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid incident ID",
+		})
+	}
+
+	var req ChangeIncidentStatusRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	incident, err := h.incidentService.ChangeStatus(c.UserContext(), orgID, incidentID, userID, req.Status, req.Notes)
+	if err != nil {
+		return h.incidentError(c, err, "Failed to change incident status")
+	}
+
+	action := domain.AuditActionUpdate
+	if req.Status == domain.IncidentStatusResolved {
+		action = domain.AuditActionResolve
+	}
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		action,
+		"security_incident",
+		incidentID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"status": req.Status,
+		},
+	)
+
+	return c.JSON(incident)
+}
+
+// AssignIncident assigns a security incident to a user of the organization
+// @Summary Assign security incident
+// @Description The assignee is emailed and an incident.assigned notification is dispatched. A null assigneeId unassigns the incident.
+// @Tags security
+// @Accept json
+// @Produce json
+// @Param id path string true "Incident ID"
+// @Param request body AssignIncidentRequest true "Assignee"
+// @Success 200 {object} application.IncidentDetail
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/security/incidents/{id}/assignee [put]
+func (h *IncidentHandler) AssignIncident(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	incidentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid incident ID",
+		})
+	}
+
+	var req AssignIncidentRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	incident, err := h.incidentService.AssignIncident(c.UserContext(), orgID, incidentID, userID, req.AssigneeID)
+	if err != nil {
+		return h.incidentError(c, err, "Failed to assign incident")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"security_incident",
+		incidentID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"assignee_id": req.AssigneeID,
+		},
+	)
+
+	return c.JSON(incident)
+}
+
+// ListComments lists the comments on a security incident
+// @Summary List security incident comments
+// @Tags security
+// @Produce json
+// @Param id path string true "Incident ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/security/incidents/{id}/comments [get]
+func (h *IncidentHandler) ListComments(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	incidentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid incident ID",
+		})
+	}
+
+	comments, err := h.incidentService.ListComments(c.UserContext(), orgID, incidentID)
+	if err != nil {
+		return h.incidentError(c, err, "Failed to fetch incident comments")
+	}
+
+	return c.JSON(fiber.Map{
+		"comments": comments,
+		"total":    len(comments),
+	})
+}
+
+// AddComment comments on a security incident
+// @Summary Comment on security incident
+// @Tags security
+// @Accept json
+// @Produce json
+// @Param id path string true "Incident ID"
+// @Param request body AddIncidentCommentRequest true "Comment"
+// @Success 201 {object} domain.IncidentComment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/security/incidents/{id}/comments [post]
+func (h *IncidentHandler) AddComment(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	incidentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid incident ID",
+		})
+	}
+
+	var req AddIncidentCommentRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	comment, err := h.incidentService.AddComment(c.UserContext(), orgID, incidentID, userID, req.Body)
+	if err != nil {
+		return h.incidentError(c, err, "Failed to comment on incident")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"incident_comment",
+		comment.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"incident_id": incidentID.String(),
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(comment)
+}
+
+// GetTimeline lists what happened to a security incident
+// @Summary Get security incident timeline
+// @Description Status changes, assignments, comments, linked and unlinked resources and SLA breaches, oldest first
+// @Tags security
+// @Produce json
+// @Param id path string true "Incident ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/security/incidents/{id}/timeline [get]
+func (h *IncidentHandler) GetTimeline(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	incidentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid incident ID",
+		})
+	}
+
+	timeline, err := h.incidentService.ListTimeline(c.UserContext(), orgID, incidentID)
+	if err != nil {
+		return h.incidentError(c, err, "Failed to fetch incident timeline")
+	}
+
+	return c.JSON(fiber.Map{
+		"timeline": timeline,
+		"total":    len(timeline),
+	})
+}
+
+// LinkResource links a threat, anomaly or verification event to a security incident
+// @Summary Link resource to security incident
+// @Tags security
+// @Accept json
+// @Produce json
+// @Param id path string true "Incident ID"
+// @Param request body LinkIncidentResourceRequest true "Resource to link"
+// @Success 201 {object} domain.IncidentLink
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/security/incidents/{id}/links [post]
+func (h *IncidentHandler) LinkResource(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	incidentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid incident ID",
+		})
+	}
+
+	var req LinkIncidentResourceRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	link, err := h.incidentService.LinkResource(c.UserContext(), orgID, incidentID, userID, req.ResourceType, req.ResourceID)
+	if err != nil {
+		return h.incidentError(c, err, "Failed to link resource to incident")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"security_incident",
+		incidentID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"linked_resource_type": req.ResourceType,
+			"linked_resource_id":   req.ResourceID.String(),
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(link)
+}
+
+// UnlinkResource removes a linked resource from a security incident
+// @Summary Unlink resource from security incident
+// @Tags security
+// @Param id path string true "Incident ID"
+// @Param type path string true "Resource type (threat, anomaly, verification_event)"
+// @Param resourceId path string true "Resource ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/security/incidents/{id}/links/{type}/{resourceId} [delete]
+func (h *IncidentHandler) UnlinkResource(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	incidentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid incident ID",
+		})
+	}
+	resourceID, err := uuid.Parse(c.Params("resourceId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid resource ID",
+		})
+	}
+	resourceType := domain.IncidentLinkType(c.Params("type"))
+
+	if err := h.incidentService.UnlinkResource(c.UserContext(), orgID, incidentID, userID, resourceType, resourceID); err != nil {
+		return h.incidentError(c, err, "Failed to unlink resource from incident")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"security_incident",
+		incidentID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"unlinked_resource_type": resourceType,
+			"unlinked_resource_id":   resourceID.String(),
+		},
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// incidentError maps incident workflow errors to responses
+func (h *IncidentHandler) incidentError(c fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, application.ErrIncidentNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Incident not found",
+		})
+	case errors.Is(err, application.ErrIncidentResourceNotFound),
+		errors.Is(err, domain.ErrIncidentLinkNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrIncidentClosed),
+		errors.Is(err, domain.ErrIncidentLinkExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrInvalidIncidentStatus),
+		errors.Is(err, application.ErrInvalidIncidentComment),
+		errors.Is(err, application.ErrInvalidIncidentLinkType),
+		errors.Is(err, application.ErrIncidentAssigneeNotFound):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
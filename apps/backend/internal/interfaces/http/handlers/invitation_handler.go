@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+)
+
+type InvitationHandler struct {
+	invitationService *application.InvitationService
+	auditService      *application.AuditService
+}
+
+func NewInvitationHandler(
+	invitationService *application.InvitationService,
+	auditService *application.AuditService,
+) *InvitationHandler {
+	return &InvitationHandler{
+		invitationService: invitationService,
+		auditService:      auditService,
+	}
+}
+
+// CreateInvitation invites an email address to the organization
+// @Summary Invite a user
+// @Description Emails the invitee a signed link that expires (7 days unless expiresInHours is set, at most 30 days).
+// @Description The link is also returned as inviteUrl, so it can be passed on when email is not configured.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body application.InviteUserRequest true "Invitee and role"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/invitations [post]
+func (h *InvitationHandler) CreateInvitation(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.InviteUserRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	invitation, link, err := h.invitationService.Invite(c.UserContext(), orgID, userID, &req)
+	if err != nil {
+		return invitationError(c, err, "Failed to create invitation")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"invitation",
+		invitation.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"email":      invitation.Email,
+			"role":       invitation.Role,
+			"expires_at": invitation.ExpiresAt,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"invitation": invitation,
+		"inviteUrl":  link,
+	})
+}
+
+// ListInvitations lists the organization's invitations
+// @Summary List invitations
+// @Tags admin
+// @Produce json
+// @Param status query string false "pending (default), accepted, revoked, expired or all"
+// @Param limit query int false "Limit" default(100)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/invitations [get]
+func (h *InvitationHandler) ListInvitations(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	status := domain.InvitationStatus(c.Query("status", string(domain.InvitationStatusPending)))
+	switch status {
+	case "all":
+		status = ""
+	case domain.InvitationStatusPending, domain.InvitationStatusAccepted,
+		domain.InvitationStatusRevoked, domain.InvitationStatusExpired:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "status must be pending, accepted, revoked, expired or all",
+		})
+	}
+
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
+			limit = parsedLimit
+		}
+	}
+	offset := 0
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil {
+			offset = parsedOffset
+		}
+	}
+
+	invitations, err := h.invitationService.ListInvitations(c.UserContext(), orgID, status, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list invitations",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"invitations": invitations,
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
+
+// RevokeInvitation withdraws a pending invitation
+// @Summary Revoke invitation
+// @Description The invitation's link stops working at once
+// @Tags admin
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} domain.Invitation
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/invitations/{id}/revoke [post]
+func (h *InvitationHandler) RevokeInvitation(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid invitation ID",
+		})
+	}
+
+	invitation, err := h.invitationService.RevokeInvitation(c.UserContext(), orgID, id, userID)
+	if err != nil {
+		return invitationError(c, err, "Failed to revoke invitation")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionRevoke,
+		"invitation",
+		invitation.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"email": invitation.Email,
+		},
+	)
+
+	return c.JSON(invitation)
+}
+
+// GetInvitation describes the invitation of a link before the invitee accepts it
+// @Summary Look up an invitation
+// @Tags public
+// @Produce json
+// @Param token path string true "Token of the invitation link"
+// @Success 200 {object} application.InvitationPreview
+// @Failure 400 {object} map[string]interface{}
+// @Failure 410 {object} map[string]interface{}
+// @Router /api/v1/public/invitations/{token} [get]
+func (h *InvitationHandler) GetInvitation(c fiber.Ctx) error {
+	preview, err := h.invitationService.PreviewInvitation(c.UserContext(), c.Params("token"))
+	if err != nil {
+		return invitationError(c, err, "Failed to fetch invitation")
+	}
+	return c.JSON(preview)
+}
+
+// AcceptInvitation creates the invitee's account
+// @Summary Accept an invitation
+// @Description Creates a password account with the invited role. It is active at once, or pending until an admin
+// @Description approves it when the organization's inviteesRequireApproval setting is on.
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param request body application.AcceptInvitationRequest true "Token of the invitation link, name and password"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 410 {object} map[string]interface{}
+// @Router /api/v1/public/invitations/accept [post]
+func (h *InvitationHandler) AcceptInvitation(c fiber.Ctx) error {
+	var req application.AcceptInvitationRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	user, err := h.invitationService.AcceptInvitation(c.UserContext(), &req)
+	if err != nil {
+		return invitationError(c, err, "Failed to accept invitation")
+	}
+
+	message := "Your account is ready. You can now sign in."
+	if user.Status == domain.UserStatusPending {
+		message = "Your account was created and is waiting for an administrator's approval."
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"userId":  user.ID,
+		"email":   user.Email,
+		"role":    user.Role,
+		"status":  user.Status,
+		"message": message,
+	})
+}
+
+func invitationError(c fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrInvitationNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Invitation not found",
+		})
+	case errors.Is(err, application.ErrInvalidInvitation),
+		errors.Is(err, application.ErrInvalidInvitationToken),
+		errors.Is(err, auth.ErrPasswordTooShort),
+		errors.Is(err, auth.ErrPasswordTooWeak):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrInvitationNotPending):
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrUserAlreadyExists),
+		errors.Is(err, application.ErrInvitationAlreadyPending):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// budgetedList responds with the list under key, cut to the leading items that fit the
+// response budget PayloadBudgetMiddleware gave the request. total counts every item; a cut
+// list also carries truncated and returned, and the X-Response-Truncated header.
+func budgetedList[T any](c fiber.Ctx, key string, items []T) error {
+	budget, _ := c.Locals("response_budget").(int)
+	if budget <= 0 {
+		return c.JSON(fiber.Map{
+			key:     items,
+			"total": len(items),
+		})
+	}
+
+	kept := make([]json.RawMessage, 0, len(items))
+	size := 0
+	for _, item := range items {
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to encode response",
+			})
+		}
+		// One byte for the separating comma
+		if size+len(encoded)+1 > budget {
+			break
+		}
+		size += len(encoded) + 1
+		kept = append(kept, encoded)
+	}
+
+	response := fiber.Map{
+		key:     kept,
+		"total": len(items),
+	}
+	if len(kept) < len(items) {
+		response["truncated"] = true
+		response["returned"] = len(kept)
+		c.Set("X-Response-Truncated", strconv.FormatBool(true))
+	}
+	return c.JSON(response)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type LoginProtectionHandler struct {
+	loginProtection *application.LoginProtectionService
+	auditService    *application.AuditService
+}
+
+func NewLoginProtectionHandler(
+	loginProtection *application.LoginProtectionService,
+	auditService *application.AuditService,
+) *LoginProtectionHandler {
+	return &LoginProtectionHandler{
+		loginProtection: loginProtection,
+		auditService:    auditService,
+	}
+}
+
+// ListAttempts lists the password logins to the organization's users
+// @Summary List login attempts
+// @Description Newest first. Failures that locked an account or blocked an address carry accountLockedUntil or addressLockedUntil. Logins to unknown emails are not listed, as they belong to no organization.
+// @Tags admin
+// @Produce json
+// @Param email query string false "Email the logins were for"
+// @Param outcome query string false "succeeded, failed, blocked or unlocked"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/login-attempts [get]
+func (h *LoginProtectionHandler) ListAttempts(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	attempts, total, err := h.loginProtection.ListAttempts(c.UserContext(), domain.LoginAttemptFilter{
+		OrganizationID: orgID,
+		Email:          c.Query("email"),
+		Outcome:        domain.LoginAttemptOutcome(c.Query("outcome")),
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch login attempts",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"attempts": attempts,
+		"total":    total,
+	})
+}
+
+// UnlockUser lifts the lockout of a user after too many failed logins
+// @Summary Unlock user login
+// @Description Clears the user's failed logins, so they can log in again right away. A block of the address the failures came from stays until it expires.
+// @Tags admin
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/unlock [post]
+func (h *LoginProtectionHandler) UnlockUser(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	adminID := c.Locals("user_id").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	user, err := h.loginProtection.Unlock(c.UserContext(), orgID, userID, c.IP())
+	if err != nil {
+		if errors.Is(err, application.ErrLoginUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to unlock user",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		adminID,
+		domain.AuditActionUpdate,
+		"user_login",
+		userID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"login_action": "unlock",
+			"email":        user.Email,
+		},
+	)
+
+	return c.JSON(fiber.Map{
+		"message": "User unlocked; their failed logins no longer count",
+	})
+}
+
+// loginBlocked responds to a login refused before its password was checked
+func loginBlocked(c fiber.Ctx, err error) error {
+	var blocked *application.LoginBlockedError
+	if !errors.As(err, &blocked) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to check login attempts",
+		})
+	}
+
+	if errors.Is(err, application.ErrCaptchaRequired) || errors.Is(err, application.ErrInvalidCaptcha) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success":         false,
+			"error":           err.Error(),
+			"captchaRequired": true,
+		})
+	}
+
+	retryAfter := int(math.Ceil(blocked.RetryAfter.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"success":    false,
+		"error":      err.Error(),
+		"retryAfter": retryAfter,
+	})
+}
+
+// loginFailed records a login with an unknown email or a wrong password and responds to it,
+// telling login forms whether the next attempt needs a CAPTCHA
+func loginFailed(c fiber.Ctx, loginProtection *application.LoginProtectionService, email string, user *domain.User) error {
+	if err := loginProtection.RecordFailure(c.UserContext(), email, c.IP(), user); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+
+	response := fiber.Map{
+		"success": false,
+		"error":   "Invalid email or password",
+	}
+	if required, err := loginProtection.CaptchaRequired(c.UserContext(), email, c.IP()); err == nil && required {
+		response["captchaRequired"] = true
+	}
+	return c.Status(fiber.StatusUnauthorized).JSON(response)
+}
+
+// loginSucceeded records a login with the right password, clearing the failures of its email
+func loginSucceeded(c fiber.Ctx, loginProtection *application.LoginProtectionService, email string, user *domain.User) {
+	if err := loginProtection.RecordSuccess(c.UserContext(), email, c.IP(), user); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/gofiber/fiber/v3"
@@ -27,6 +28,8 @@ func NewMCPAttestationHandler(
 // AttestMCP handles agent attestation of an MCP server
 // @Summary Attest MCP server
 // @Description Submit cryptographically signed attestation from a verified agent
+// @Description The result is also sent as attestation.verified or attestation.rejected to the agent owner's webhooks
+// @Description and, when the attestation sets callback_url and verifies, POSTed there signed with the attestation nonce.
 // @Tags mcp-servers
 // @Accept json
 // @Produce json
@@ -57,7 +60,7 @@ func (h *MCPAttestationHandler) AttestMCP(c fiber.Ctx) error {
 	}
 
 	// Verify and record attestation
-	response, err := h.attestationService.VerifyAndRecordAttestation(c.Context(), mcpServerID, &req)
+	response, err := h.attestationService.VerifyAndRecordAttestation(c.UserContext(), mcpServerID, &req)
 	if err != nil {
 		// Log the actual error for debugging
 		fmt.Printf("❌ Attestation failed for MCP %s: %v\n", mcpServerID, err)
@@ -66,8 +69,11 @@ func (h *MCPAttestationHandler) AttestMCP(c fiber.Ctx) error {
 		statusCode := fiber.StatusInternalServerError
 		if err.Error() == "only verified agents can attest MCPs" ||
 			err.Error() == "invalid attestation signature" ||
-			err.Error() == "attestation expired (older than 5 minutes)" {
+			err.Error() == "attestation expired (older than 5 minutes)" ||
+			isAttestationNonceError(err) {
 			statusCode = fiber.StatusForbidden
+		} else if errors.Is(err, application.ErrInvalidAttestationCallback) {
+			statusCode = fiber.StatusBadRequest
 		}
 
 		return c.Status(statusCode).JSON(fiber.Map{
@@ -81,7 +87,7 @@ func (h *MCPAttestationHandler) AttestMCP(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id")
 	if userID != nil && orgID != nil {
 		h.auditService.LogAction(
-			c.Context(),
+			c.UserContext(),
 			orgID.(uuid.UUID),  // Organization ID first
 			userID.(uuid.UUID), // Then user ID
 			domain.AuditActionAttest,
@@ -103,6 +109,123 @@ func (h *MCPAttestationHandler) AttestMCP(c fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
+// AttestMCPByURL handles agent attestation of an MCP server identified by the attested mcp_url
+// @Summary Attest MCP server by URL
+// @Description Submit a signed attestation for the MCP server at attestation.mcp_url in the agent's organization.
+// @Description Unregistered URLs are rejected unless the organization enables autoRegisterAttestedMcps, in which
+// @Description case a pending MCP server is created from the attestation and queued for admin review.
+// @Description The result is also sent as attestation.verified or attestation.rejected to the agent owner's webhooks
+// @Description and, when the attestation sets callback_url and verifies, POSTed there signed with the attestation nonce.
+// @Tags mcp-servers
+// @Accept json
+// @Produce json
+// @Param request body application.AttestMCPRequest true "Attestation data and signature"
+// @Success 200 {object} application.AttestMCPResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/attest [post]
+func (h *MCPAttestationHandler) AttestMCPByURL(c fiber.Ctx) error {
+	var req application.AttestMCPRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+	}
+
+	response, err := h.attestationService.AttestMCPByURL(c.UserContext(), &req)
+	if err != nil {
+		fmt.Printf("❌ Attestation failed for MCP URL %s: %v\n", req.Attestation.MCPURL, err)
+
+		statusCode := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, application.ErrMCPServerNotRegistered):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   "MCP server not registered",
+				"message": "Register the MCP server first or ask an admin to enable automatic registration of attested MCP servers",
+			})
+		case err.Error() == "only verified agents can attest MCPs" ||
+			err.Error() == "invalid attestation signature" ||
+			err.Error() == "attestation expired (older than 5 minutes)",
+			isAttestationNonceError(err):
+			statusCode = fiber.StatusForbidden
+		case err.Error() == "attestation has no mcp_url",
+			errors.Is(err, application.ErrInvalidAttestationCallback):
+			statusCode = fiber.StatusBadRequest
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Attestation failed",
+			"message": err.Error(),
+		})
+	}
+
+	userID := c.Locals("user_id")
+	orgID := c.Locals("organization_id")
+	if userID != nil && orgID != nil {
+		mcpServerID, _ := uuid.Parse(response.MCPServerID)
+		h.auditService.LogAction(
+			c.UserContext(),
+			orgID.(uuid.UUID),
+			userID.(uuid.UUID),
+			domain.AuditActionAttest,
+			"mcp_server",
+			mcpServerID,
+			c.IP(),
+			c.Get("User-Agent"),
+			fiber.Map{
+				"attestation_id":    response.AttestationID,
+				"confidence_score":  response.MCPConfidenceScore,
+				"attestation_count": response.AttestationCount,
+				"agentId":           req.Attestation.AgentID,
+				"mcp_url":           req.Attestation.MCPURL,
+				"auto_registered":   response.AutoRegistered,
+			},
+		)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// IssueAttestationNonce issues a single-use nonce for the authenticated agent's next attestation
+// @Summary Issue attestation nonce
+// @Description Issue a nonce the agent must include as attestation.nonce in its next signed attestation.
+// @Description Each nonce is valid for 5 minutes and is accepted once; resubmitting an attestation is rejected as a replay.
+// @Tags mcp-servers
+// @Produce json
+// @Success 201 {object} application.AttestationNonceResponse
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/attestation-nonce [post]
+func (h *MCPAttestationHandler) IssueAttestationNonce(c fiber.Ctx) error {
+	agentID, ok := c.Locals("agent_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Ed25519 agent authentication required",
+		})
+	}
+
+	response, err := h.attestationService.IssueAttestationNonce(c.UserContext(), agentID)
+	if err != nil {
+		fmt.Printf("❌ Failed to issue attestation nonce for agent %s: %v\n", agentID, err)
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   "Failed to issue attestation nonce",
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// isAttestationNonceError reports whether an attestation was rejected for a missing, invalid or reused nonce
+func isAttestationNonceError(err error) bool {
+	return errors.Is(err, application.ErrAttestationNonceRequired) ||
+		errors.Is(err, application.ErrAttestationNonceInvalid) ||
+		errors.Is(err, application.ErrAttestationReplayed)
+}
+
 // GetMCPAttestations retrieves all attestations for an MCP server
 // @Summary Get MCP attestations
 // @Description Retrieve all agent attestations for an MCP server
@@ -126,7 +249,7 @@ func (h *MCPAttestationHandler) GetMCPAttestations(c fiber.Ctx) error {
 	}
 
 	// Get attestations
-	attestations, confidenceScore, lastAttestedAt, err := h.attestationService.GetMCPAttestations(c.Context(), mcpServerID)
+	attestations, confidenceScore, lastAttestedAt, err := h.attestationService.GetMCPAttestations(c.UserContext(), mcpServerID)
 	if err != nil {
 		statusCode := fiber.StatusInternalServerError
 		if err.Error() == "mcp server not found" {
@@ -139,11 +262,22 @@ func (h *MCPAttestationHandler) GetMCPAttestations(c fiber.Ctx) error {
 		})
 	}
 
+	// The confidence score is "agent-verified only" when every attestation comes from
+	// inside a private network the backend cannot reach
+	agentVerifiedOnly := len(attestations) > 0
+	for _, att := range attestations {
+		if !att.AgentVerifiedOnly {
+			agentVerifiedOnly = false
+			break
+		}
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"attestations":       attestations,
-		"total":              len(attestations),
-		"confidence_score":   confidenceScore,
-		"last_attested_at":   lastAttestedAt,
+		"attestations":        attestations,
+		"total":               len(attestations),
+		"confidence_score":    confidenceScore,
+		"last_attested_at":    lastAttestedAt,
+		"agent_verified_only": agentVerifiedOnly,
 	})
 }
 
@@ -169,7 +303,7 @@ func (h *MCPAttestationHandler) GetConnectedAgents(c fiber.Ctx) error {
 	}
 
 	// Get connected agents
-	agents, err := h.attestationService.GetConnectedAgentsForMCP(c.Context(), mcpServerID)
+	agents, err := h.attestationService.GetConnectedAgentsForMCP(c.UserContext(), mcpServerID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   "Failed to get connected agents",
@@ -205,7 +339,7 @@ func (h *MCPAttestationHandler) GetAgentMCPServers(c fiber.Ctx) error {
 	}
 
 	// Get MCP servers
-	mcpServers, err := h.attestationService.GetMCPServersForAgent(c.Context(), agentID)
+	mcpServers, err := h.attestationService.GetMCPServersForAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   "Failed to get MCP servers",
@@ -276,7 +410,7 @@ func (h *MCPAttestationHandler) ManualAttestMCP(c fiber.Ctx) error {
 
 	// Call service method for manual attestation
 	response, err := h.attestationService.RecordManualAttestation(
-		c.Context(),
+		c.UserContext(),
 		mcpServerID,
 		userID,
 		orgID,
@@ -301,7 +435,7 @@ func (h *MCPAttestationHandler) ManualAttestMCP(c fiber.Ctx) error {
 
 	// Audit log
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionAttest,
@@ -384,7 +518,7 @@ func (h *MCPAttestationHandler) RecordMCPConnection(c fiber.Ctx) error {
 
 	// Record the connection
 	connection, err := h.attestationService.RecordAgentMCPConnection(
-		c.Context(),
+		c.UserContext(),
 		agentID,
 		mcpServerID,
 		req.ToolName,
@@ -399,7 +533,7 @@ func (h *MCPAttestationHandler) RecordMCPConnection(c fiber.Ctx) error {
 
 	// Audit log
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		uuid.Nil, // No organization context for SDK endpoints
 		agentID,
 		domain.AuditActionCreate,
@@ -427,3 +561,164 @@ func (h *MCPAttestationHandler) RecordMCPConnection(c fiber.Ctx) error {
 		"message":           "MCP connection recorded successfully",
 	})
 }
+
+// CreateNetworkRelay registers an agent as the relay for a private network MCP server
+// @Summary Register private network relay
+// @Description Record that an agent reaches this MCP server over a private network. The backend then skips its own connectivity checks and tags attestations as agent-verified only.
+// @Tags mcp-servers
+// @Accept json
+// @Produce json
+// @Param id path string true "MCP Server ID"
+// @Param request body application.CreateNetworkRelayRequest true "Relay details"
+// @Success 201 {object} domain.MCPNetworkRelay
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/{id}/relays [post]
+func (h *MCPAttestationHandler) CreateNetworkRelay(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	mcpServerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid MCP server ID",
+		})
+	}
+
+	var req application.CreateNetworkRelayRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.AgentID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "agent_id is required",
+		})
+	}
+
+	relay, err := h.attestationService.CreateNetworkRelay(c.UserContext(), mcpServerID, orgID, userID, &req)
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "mcp server not found" || err.Error() == "agent not found" {
+			statusCode = fiber.StatusNotFound
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"mcp_network_relay",
+		relay.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"mcp_server_id": mcpServerID,
+			"agent_id":      relay.AgentID,
+			"network_zone":  relay.NetworkZone,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(relay)
+}
+
+// ListNetworkRelays lists the private network relays for an MCP server
+// @Summary List private network relays
+// @Description Get the agents registered as private network relays for this MCP server
+// @Tags mcp-servers
+// @Produce json
+// @Param id path string true "MCP Server ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/{id}/relays [get]
+func (h *MCPAttestationHandler) ListNetworkRelays(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	mcpServerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid MCP server ID",
+		})
+	}
+
+	relays, err := h.attestationService.ListNetworkRelays(c.UserContext(), mcpServerID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch network relays",
+		})
+	}
+
+	// Relays carry the organization, so filtering here also enforces ownership
+	owned := []*domain.MCPNetworkRelay{}
+	for _, relay := range relays {
+		if relay.OrganizationID == orgID {
+			owned = append(owned, relay)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"relays": owned,
+		"total":  len(owned),
+	})
+}
+
+// DeleteNetworkRelay removes a private network relay
+// @Summary Delete private network relay
+// @Description Remove a relay record; once none remain the backend resumes its own connectivity checks
+// @Tags mcp-servers
+// @Param id path string true "MCP Server ID"
+// @Param relayId path string true "Relay ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/{id}/relays/{relayId} [delete]
+func (h *MCPAttestationHandler) DeleteNetworkRelay(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	relayID, err := uuid.Parse(c.Params("relayId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid relay ID",
+		})
+	}
+
+	relay, err := h.attestationService.GetNetworkRelay(c.UserContext(), relayID)
+	if err != nil || relay.MCPServerID.String() != c.Params("id") {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Network relay not found",
+		})
+	}
+	if relay.OrganizationID != orgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied",
+		})
+	}
+
+	if err := h.attestationService.DeleteNetworkRelay(c.UserContext(), relayID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"mcp_network_relay",
+		relayID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"mcp_server_id": relay.MCPServerID,
+			"agent_id":      relay.AgentID,
+		},
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
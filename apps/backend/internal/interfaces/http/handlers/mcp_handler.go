@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -8,23 +9,24 @@ import (
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/application"
 	"github.com/opena2a/identity/backend/internal/domain"
-	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
 )
 
 type MCPHandler struct {
 	mcpService                   *application.MCPService
 	mcpCapabilityService         *application.MCPCapabilityService
 	auditService                 *application.AuditService
-	agentRepository              *repository.AgentRepository
+	agentRepository              domain.MCPServerAgentRepository
 	verificationEventRepository  domain.VerificationEventRepository
+	tombstoneService             *application.TombstoneService
 }
 
 func NewMCPHandler(
 	mcpService *application.MCPService,
 	mcpCapabilityService *application.MCPCapabilityService,
 	auditService *application.AuditService,
-	agentRepository *repository.AgentRepository,
+	agentRepository domain.MCPServerAgentRepository,
 	verificationEventRepository domain.VerificationEventRepository,
+	tombstoneService *application.TombstoneService,
 ) *MCPHandler {
 	return &MCPHandler{
 		mcpService:                  mcpService,
@@ -32,18 +34,23 @@ func NewMCPHandler(
 		auditService:                auditService,
 		agentRepository:             agentRepository,
 		verificationEventRepository: verificationEventRepository,
+		tombstoneService:            tombstoneService,
 	}
 }
 
 // CreateMCPServer creates a new MCP server
 // @Summary Create MCP server
-// @Description Register a new Model Context Protocol server
+// @Description Register a new Model Context Protocol server. With validate=true every registration check (URL format and uniqueness, key format, capability names) runs and the would-be server is returned without being persisted; failed validation returns 422.
 // @Tags mcp-servers
 // @Accept json
 // @Produce json
+// @Param validate query bool false "Validate only, do not create the MCP server"
 // @Param request body application.CreateMCPServerRequest true "MCP server details"
 // @Success 201 {object} domain.MCPServer
+// @Success 200 {object} map[string]interface{} "validate=true and every check passed"
 // @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "MCP server URL already registered"
+// @Failure 422 {object} map[string]interface{} "validate=true and a check failed"
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/mcp-servers [post]
 func (h *MCPHandler) CreateMCPServer(c fiber.Ctx) error {
@@ -83,26 +90,29 @@ func (h *MCPHandler) CreateMCPServer(c fiber.Ctx) error {
 		})
 	}
 
-	server, err := h.mcpService.CreateMCPServer(c.Context(), &req, orgID, userID, agentID)
+	// Dry run: validate only, nothing is persisted or audited
+	if isRegistrationDryRun(c) {
+		server, validation, err := h.mcpService.ValidateMCPServerRegistration(c.UserContext(), &req, orgID, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to validate MCP server registration",
+			})
+		}
+		return registrationDryRunResponse(c, validation, "mcp_server", server)
+	}
+
+	server, err := h.mcpService.CreateMCPServer(c.UserContext(), &req, orgID, userID, agentID)
 	if err != nil {
 		// Log the actual error for debugging
 		fmt.Printf("❌ Error creating MCP server: %v\n", err)
 
-		// Return 409 Conflict for duplicate URL errors
-		if err.Error() == "mcp server with this URL already exists" {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		}
-
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		// 409 Conflict for duplicate URLs, 400 for other validation failures
+		return registrationErrorResponse(c, err)
 	}
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionCreate,
@@ -122,7 +132,7 @@ func (h *MCPHandler) CreateMCPServer(c fiber.Ctx) error {
 
 // ListMCPServers lists all MCP servers for the organization
 // @Summary List MCP servers
-// @Description Get all MCP servers for the authenticated organization
+// @Description Get all MCP servers for the authenticated organization. Lists larger than the response budget are cut and marked truncated.
 // @Tags mcp-servers
 // @Produce json
 // @Success 200 {object} map[string]interface{}
@@ -131,17 +141,14 @@ func (h *MCPHandler) CreateMCPServer(c fiber.Ctx) error {
 func (h *MCPHandler) ListMCPServers(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 
-	servers, err := h.mcpService.ListMCPServers(c.Context(), orgID)
+	servers, err := h.mcpService.ListMCPServers(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch MCP servers",
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"mcpServers": servers,
-		"total":       len(servers),
-	})
+	return budgetedList(c, "mcpServers", servers)
 }
 
 // GetMCPServer retrieves a single MCP server
@@ -163,7 +170,7 @@ func (h *MCPHandler) GetMCPServer(c fiber.Ctx) error {
 		})
 	}
 
-	server, err := h.mcpService.GetMCPServer(c.Context(), serverID)
+	server, err := h.mcpService.GetMCPServer(c.UserContext(), serverID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "MCP server not found",
@@ -180,6 +187,41 @@ func (h *MCPHandler) GetMCPServer(c fiber.Ctx) error {
 	return c.JSON(server)
 }
 
+// GetMCPServersBatch returns several MCP servers of the organization in one request
+// @Summary Batch get MCP servers
+// @Description Look up to 500 MCP servers by ID with a single query; IDs that are unknown or belong to another organization are listed in missing
+// @Tags mcp-servers
+// @Accept json
+// @Produce json
+// @Param request body BatchLookupRequest true "MCP server IDs"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/batch [post]
+func (h *MCPHandler) GetMCPServersBatch(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	ids, err := parseBatchLookupIDs(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	servers, err := h.mcpService.GetMCPServersByIDs(c.UserContext(), orgID, ids)
+	if err != nil {
+		return batchLookupError(c, err, "Failed to fetch MCP servers")
+	}
+
+	found := make(map[uuid.UUID]bool, len(servers))
+	for _, server := range servers {
+		found[server.ID] = true
+	}
+	return c.JSON(fiber.Map{
+		"mcp_servers": servers,
+		"missing":     missingBatchIDs(ids, found),
+	})
+}
+
 // UpdateMCPServer updates an MCP server
 // @Summary Update MCP server
 // @Description Update an existing MCP server
@@ -210,7 +252,7 @@ func (h *MCPHandler) UpdateMCPServer(c fiber.Ctx) error {
 	}
 
 	// Verify server belongs to organization first
-	existingServer, err := h.mcpService.GetMCPServer(c.Context(), serverID)
+	existingServer, err := h.mcpService.GetMCPServer(c.UserContext(), serverID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "MCP server not found",
@@ -222,8 +264,11 @@ func (h *MCPHandler) UpdateMCPServer(c fiber.Ctx) error {
 		})
 	}
 
-	server, err := h.mcpService.UpdateMCPServer(c.Context(), serverID, &req)
+	server, err := h.mcpService.UpdateMCPServer(c.UserContext(), serverID, &req)
 	if err != nil {
+		if errors.Is(err, application.ErrNamingPolicyViolation) {
+			return namingPolicyViolationResponse(c, err)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -231,7 +276,7 @@ func (h *MCPHandler) UpdateMCPServer(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionUpdate,
@@ -267,7 +312,7 @@ func (h *MCPHandler) DeleteMCPServer(c fiber.Ctx) error {
 	}
 
 	// Verify server belongs to organization first
-	existingServer, err := h.mcpService.GetMCPServer(c.Context(), serverID)
+	existingServer, err := h.mcpService.GetMCPServer(c.UserContext(), serverID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "MCP server not found",
@@ -279,7 +324,11 @@ func (h *MCPHandler) DeleteMCPServer(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.mcpService.DeleteMCPServer(c.Context(), serverID); err != nil {
+	// Keep a tombstone so old events and incidents still resolve the server
+	err = h.tombstoneService.DeleteWithTombstone(c.UserContext(), domain.TombstoneEntityMCPServer, serverID, userID, func() error {
+		return h.mcpService.DeleteMCPServer(c.UserContext(), serverID)
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -287,7 +336,7 @@ func (h *MCPHandler) DeleteMCPServer(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionDelete,
@@ -322,7 +371,7 @@ func (h *MCPHandler) VerifyMCPServer(c fiber.Ctx) error {
 	}
 
 	// Verify server belongs to organization first
-	server, err := h.mcpService.GetMCPServer(c.Context(), serverID)
+	server, err := h.mcpService.GetMCPServer(c.UserContext(), serverID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "MCP server not found",
@@ -335,7 +384,7 @@ func (h *MCPHandler) VerifyMCPServer(c fiber.Ctx) error {
 	}
 
 	// Generate verification challenge
-	challenge, err := h.mcpService.GenerateVerificationChallenge(c.Context(), serverID)
+	challenge, err := h.mcpService.GenerateVerificationChallenge(c.UserContext(), serverID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to generate verification challenge",
@@ -343,18 +392,23 @@ func (h *MCPHandler) VerifyMCPServer(c fiber.Ctx) error {
 	}
 
 	// Perform verification with user context
-	if err := h.mcpService.VerifyMCPServer(c.Context(), serverID, userID, c.IP()); err != nil {
+	if err := h.mcpService.VerifyMCPServer(c.UserContext(), serverID, userID, c.IP()); err != nil {
+		if errors.Is(err, application.ErrPrivateNetworkMCP) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
 	// Get updated server to return in response
-	server, _ = h.mcpService.GetMCPServer(c.Context(), serverID)
+	server, _ = h.mcpService.GetMCPServer(c.UserContext(), serverID)
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionVerify,
@@ -408,7 +462,7 @@ func (h *MCPHandler) AddPublicKey(c fiber.Ctx) error {
 	}
 
 	// Verify server belongs to organization first
-	server, err := h.mcpService.GetMCPServer(c.Context(), serverID)
+	server, err := h.mcpService.GetMCPServer(c.UserContext(), serverID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "MCP server not found",
@@ -420,7 +474,7 @@ func (h *MCPHandler) AddPublicKey(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.mcpService.AddPublicKey(c.Context(), serverID, &req); err != nil {
+	if err := h.mcpService.AddPublicKey(c.UserContext(), serverID, &req); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -428,7 +482,7 @@ func (h *MCPHandler) AddPublicKey(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionUpdate,
@@ -469,7 +523,7 @@ func (h *MCPHandler) GetVerificationStatus(c fiber.Ctx) error {
 	}
 
 	// Verify server belongs to organization first
-	server, err := h.mcpService.GetMCPServer(c.Context(), serverID)
+	server, err := h.mcpService.GetMCPServer(c.UserContext(), serverID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "MCP server not found",
@@ -481,7 +535,7 @@ func (h *MCPHandler) GetVerificationStatus(c fiber.Ctx) error {
 		})
 	}
 
-	status, err := h.mcpService.GetVerificationStatus(c.Context(), serverID)
+	status, err := h.mcpService.GetVerificationStatus(c.UserContext(), serverID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get verification status",
@@ -511,7 +565,7 @@ func (h *MCPHandler) GetMCPServerCapabilities(c fiber.Ctx) error {
 	}
 
 	// Verify server belongs to organization first
-	server, err := h.mcpService.GetMCPServer(c.Context(), serverID)
+	server, err := h.mcpService.GetMCPServer(c.UserContext(), serverID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "MCP server not found",
@@ -524,7 +578,7 @@ func (h *MCPHandler) GetMCPServerCapabilities(c fiber.Ctx) error {
 	}
 
 	// Fetch detailed capabilities from mcp_server_capabilities table
-	capabilities, err := h.mcpCapabilityService.GetCapabilities(c.Context(), serverID)
+	capabilities, err := h.mcpCapabilityService.GetCapabilities(c.UserContext(), serverID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch capabilities",
@@ -538,6 +592,129 @@ func (h *MCPHandler) GetMCPServerCapabilities(c fiber.Ctx) error {
 	})
 }
 
+// DiscoverMCPServerCapabilities connects to an MCP server and refreshes its capabilities
+// @Summary Discover MCP server capabilities
+// @Description Performs the MCP initialize handshake and lists the server's tools, resources and prompts (falling back to /.well-known/mcp/capabilities). Capabilities the server no longer exposes are marked inactive; tool changes on verified servers raise an alert.
+// @Tags mcp-servers
+// @Produce json
+// @Param id path string true "MCP Server ID"
+// @Success 200 {object} application.MCPDiscoveryResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 502 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/{id}/capabilities/discover [post]
+func (h *MCPHandler) DiscoverMCPServerCapabilities(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	serverID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid MCP server ID",
+		})
+	}
+
+	server, err := h.mcpService.GetMCPServer(c.UserContext(), serverID)
+	if err != nil || server.OrganizationID != orgID {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "MCP server not found",
+		})
+	}
+
+	result, err := h.mcpCapabilityService.DiscoverCapabilities(c.UserContext(), serverID)
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error": fmt.Sprintf("Capability discovery failed: %v", err),
+		})
+	}
+
+	return c.JSON(result)
+}
+
+// MCPCapabilityRiskRequest overrides a capability's risk classification
+type MCPCapabilityRiskRequest struct {
+	RiskLevel domain.MCPToolRisk `json:"riskLevel"` // read, write, destructive, exfiltration; empty clears the override
+}
+
+// SetMCPCapabilityRisk overrides the risk classification of an MCP server capability
+// @Summary Override MCP capability risk
+// @Description Override the heuristic risk classification of a capability. Destructive and exfiltration-capable tools are flagged as sensitive and are subject to sensitive_tool security policies. An empty riskLevel restores the heuristic classification.
+// @Tags mcp-servers
+// @Accept json
+// @Produce json
+// @Param id path string true "MCP Server ID"
+// @Param capabilityId path string true "Capability ID"
+// @Param request body MCPCapabilityRiskRequest true "Risk classification"
+// @Success 200 {object} domain.MCPServerCapability
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/{id}/capabilities/{capabilityId}/risk [put]
+func (h *MCPHandler) SetMCPCapabilityRisk(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	serverID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid MCP server ID",
+		})
+	}
+	capabilityID, err := uuid.Parse(c.Params("capabilityId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid capability ID",
+		})
+	}
+
+	server, err := h.mcpService.GetMCPServer(c.UserContext(), serverID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "MCP server not found",
+		})
+	}
+	if server.OrganizationID != orgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied",
+		})
+	}
+
+	var req MCPCapabilityRiskRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	capability, err := h.mcpCapabilityService.SetCapabilityRisk(c.UserContext(), serverID, capabilityID, req.RiskLevel)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidMCPToolRisk) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Capability not found",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"mcp_server_capability",
+		capability.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"mcp_server_id": serverID,
+			"capability":    capability.Name,
+			"risk_override": req.RiskLevel,
+			"risk_level":    capability.RiskLevel,
+		},
+	)
+
+	return c.JSON(capability)
+}
+
 // GetMCPServerAgents retrieves all agents that talk to an MCP server
 // @Summary Get agents for MCP server
 // @Description Get all agents that are configured to communicate with this MCP server
@@ -558,7 +735,7 @@ func (h *MCPHandler) GetMCPServerAgents(c fiber.Ctx) error {
 	}
 
 	// Verify server belongs to organization first
-	server, err := h.mcpService.GetMCPServer(c.Context(), serverID)
+	server, err := h.mcpService.GetMCPServer(c.UserContext(), serverID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "MCP server not found",
@@ -640,7 +817,7 @@ func (h *MCPHandler) GetMCPVerificationEvents(c fiber.Ctx) error {
 	}
 
 	// Verify server belongs to organization first
-	server, err := h.mcpService.GetMCPServer(c.Context(), serverID)
+	server, err := h.mcpService.GetMCPServer(c.UserContext(), serverID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "MCP server not found",
@@ -727,7 +904,7 @@ func (h *MCPHandler) VerifyMCPAction(c fiber.Ctx) error {
 
 	// Verify MCP action
 	decision, reason, auditID, err := h.mcpService.VerifyMCPAction(
-		c.Context(),
+		c.UserContext(),
 		mcpID,
 		req.ActionType,
 		req.Resource,
@@ -775,7 +952,7 @@ func (h *MCPHandler) GetConnectedAgents(c fiber.Ctx) error {
 	}
 
 	// Get connected agents
-	agents, err := h.mcpService.GetConnectedAgents(c.Context(), mcpServerID)
+	agents, err := h.mcpService.GetConnectedAgents(c.UserContext(), mcpServerID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+)
+
+type MCPHealthHandler struct {
+	healthService *application.MCPHealthService
+}
+
+func NewMCPHealthHandler(healthService *application.MCPHealthService) *MCPHealthHandler {
+	return &MCPHealthHandler{
+		healthService: healthService,
+	}
+}
+
+// GetMCPServerHealth returns the health of an MCP server
+// @Summary Get MCP server health
+// @Description Health state, consecutive failures, uptime over the last 24 hours, 7 days and 30 days, and the most recent health checks of the MCP server
+// @Tags mcp-servers
+// @Produce json
+// @Param id path string true "MCP Server ID"
+// @Success 200 {object} application.MCPServerHealthReport
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/{id}/health [get]
+func (h *MCPHealthHandler) GetMCPServerHealth(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	mcpServerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid MCP server ID",
+		})
+	}
+
+	report, err := h.healthService.GetHealth(c.UserContext(), orgID, mcpServerID)
+	if err != nil {
+		return h.healthError(c, err, "Failed to fetch MCP server health")
+	}
+	return c.JSON(report)
+}
+
+// CheckMCPServerHealth probes an MCP server now
+// @Summary Check MCP server health now
+// @Description Probes the MCP server's URL without waiting for the health check job. Sustained failures suspend the server; a passing check restores a server suspended by its health checks.
+// @Tags mcp-servers
+// @Produce json
+// @Param id path string true "MCP Server ID"
+// @Success 200 {object} application.MCPServerHealthReport
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/{id}/health/check [post]
+func (h *MCPHealthHandler) CheckMCPServerHealth(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	mcpServerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid MCP server ID",
+		})
+	}
+
+	report, err := h.healthService.CheckNow(c.UserContext(), orgID, mcpServerID)
+	if err != nil {
+		return h.healthError(c, err, "Failed to check MCP server health")
+	}
+	return c.JSON(report)
+}
+
+func (h *MCPHealthHandler) healthError(c fiber.Ctx, err error, message string) error {
+	if errors.Is(err, application.ErrMCPHealthServerNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "MCP server not found",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
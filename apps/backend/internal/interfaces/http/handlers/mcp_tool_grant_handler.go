@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type MCPToolGrantHandler struct {
+	grantService *application.MCPToolGrantService
+	auditService *application.AuditService
+}
+
+func NewMCPToolGrantHandler(
+	grantService *application.MCPToolGrantService,
+	auditService *application.AuditService,
+) *MCPToolGrantHandler {
+	return &MCPToolGrantHandler{
+		grantService: grantService,
+		auditService: auditService,
+	}
+}
+
+// ListGrants lists the agent's MCP tool grants
+// @Summary List agent MCP tool grants
+// @Description List the grants narrowing the agent's talks_to MCP servers to individual tools. Servers without a grant allow every tool.
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/mcp-tool-grants [get]
+func (h *MCPToolGrantHandler) ListGrants(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	grants, err := h.grantService.ListGrants(c.UserContext(), orgID, agentID)
+	if err != nil {
+		return mcpToolGrantError(c, err, "Failed to list MCP tool grants")
+	}
+
+	return c.JSON(fiber.Map{
+		"grants": grants,
+		"total":  len(grants),
+	})
+}
+
+// SetGrant limits the agent to the listed tools of an MCP server
+// @Summary Set agent MCP tool grant
+// @Description Limit the agent to the listed tools of an MCP server in its talks_to list, replacing the tools of an existing grant. An empty list allows no tool.
+// @Description Verification requests report tool calls as server:tool; calls outside the granted set raise an mcp_tool_drift alert.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param mcp_id path string true "MCP server ID or name, as in talks_to"
+// @Param request body application.SetMCPToolGrantRequest true "Granted tools"
+// @Success 200 {object} domain.MCPToolGrant
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/mcp-servers/{mcp_id}/tools [put]
+func (h *MCPToolGrantHandler) SetGrant(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	var req application.SetMCPToolGrantRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	grant, err := h.grantService.SetGrant(c.UserContext(), orgID, agentID, userID, c.Params("mcp_id"), &req)
+	if err != nil {
+		return mcpToolGrantError(c, err, "Failed to set MCP tool grant")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"mcp_tool_grant",
+		grant.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"agent_id":   agentID,
+			"mcp_server": grant.MCPServer,
+			"tools":      grant.Tools,
+		},
+	)
+
+	return c.JSON(grant)
+}
+
+// DeleteGrant removes the agent's grant for an MCP server
+// @Summary Delete agent MCP tool grant
+// @Description Remove the agent's tool grant for an MCP server, allowing every tool of it again
+// @Tags agents
+// @Param id path string true "Agent ID"
+// @Param mcp_id path string true "MCP server ID or name, as in talks_to"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/mcp-servers/{mcp_id}/tools [delete]
+func (h *MCPToolGrantHandler) DeleteGrant(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	mcpServer := c.Params("mcp_id")
+	if err := h.grantService.DeleteGrant(c.UserContext(), orgID, agentID, mcpServer); err != nil {
+		return mcpToolGrantError(c, err, "Failed to delete MCP tool grant")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"mcp_tool_grant",
+		agentID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"mcp_server": mcpServer,
+		},
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func mcpToolGrantError(c fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, application.ErrInvalidMCPToolGrant):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrToolGrantAgentNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Agent not found",
+		})
+	case errors.Is(err, domain.ErrMCPToolGrantNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "MCP tool grant not found",
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": fallback,
+		})
+	}
+}
@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+)
+
+// mfaChallengeTTL is how long a user has to enter a code after passing the password step
+const mfaChallengeTTL = 5 * time.Minute
+
+type MFAHandler struct {
+	mfaService   *application.MFAService
+	authService  *application.AuthService
+	jwtService   *auth.JWTService
+	auditService *application.AuditService
+}
+
+func NewMFAHandler(
+	mfaService *application.MFAService,
+	authService *application.AuthService,
+	jwtService *auth.JWTService,
+	auditService *application.AuditService,
+) *MFAHandler {
+	return &MFAHandler{
+		mfaService:   mfaService,
+		authService:  authService,
+		jwtService:   jwtService,
+		auditService: auditService,
+	}
+}
+
+// MFACodeRequest carries a TOTP code or a recovery code
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+// MFALoginRequest completes a login that needs a second factor
+type MFALoginRequest struct {
+	MFAToken string `json:"mfaToken"`
+	Code     string `json:"code"`
+}
+
+// loginMFAChallenge decides whether a login that passed the password step needs a second factor.
+// When it does, it returns the challenge token to exchange at POST /auth/login/mfa, and whether
+// the user must enroll first because their role requires MFA.
+func loginMFAChallenge(ctx context.Context, mfaService *application.MFAService, jwtService *auth.JWTService, user *domain.User) (string, bool, error) {
+	enroll := false
+	if !user.MFAEnabled {
+		required, err := mfaService.Required(ctx, user)
+		if err != nil || !required {
+			return "", false, err
+		}
+		enroll = true
+	}
+	token, err := jwtService.GenerateMFAChallengeToken(user.ID.String(), user.OrganizationID.String(), mfaChallengeTTL)
+	return token, enroll, err
+}
+
+// mfaErrorResponse maps MFA errors to an HTTP response; it returns false for other errors
+func mfaErrorResponse(c fiber.Ctx, err error) (error, bool) {
+	var status int
+	switch {
+	case errors.Is(err, application.ErrInvalidMFACode):
+		status = fiber.StatusUnauthorized
+	case errors.Is(err, application.ErrMFARequired):
+		status = fiber.StatusForbidden
+	case errors.Is(err, application.ErrMFAEnrollmentNotStarted):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, application.ErrMFAAlreadyEnabled), errors.Is(err, application.ErrMFANotEnabled):
+		status = fiber.StatusConflict
+	default:
+		return nil, false
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"error": err.Error(),
+	}), true
+}
+
+// challengeUser returns the active user a login challenge token was issued to
+func (h *MFAHandler) challengeUser(c fiber.Ctx, token string) (*domain.User, error) {
+	claims, err := h.jwtService.ValidateMFAChallengeToken(token)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := h.authService.GetUserByID(c.UserContext(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == domain.UserStatusDeactivated || user.DeletedAt != nil {
+		return nil, errors.New("user is deactivated")
+	}
+	return user, nil
+}
+
+// CompleteLogin exchanges a login challenge token and a code for a session
+// @Summary Complete login with a second factor
+// @Description Accepts a TOTP code or a recovery code. When the login response asked for enrollment,
+// @Description the code confirms the enrollment started at /auth/login/mfa/enroll and the response
+// @Description includes the new recovery codes, which are only shown once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body MFALoginRequest true "Challenge token and code"
+// @Success 200 {object} LoginResponse
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/auth/login/mfa [post]
+func (h *MFAHandler) CompleteLogin(c fiber.Ctx) error {
+	var req MFALoginRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.MFAToken == "" || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "mfaToken and code are required",
+		})
+	}
+
+	user, err := h.challengeUser(c, req.MFAToken)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid or expired MFA token",
+		})
+	}
+
+	var recoveryCodes []string
+	recoveryCodeUsed := false
+	if user.MFAEnabled {
+		recoveryCodeUsed, err = h.mfaService.Verify(c.UserContext(), user.ID, req.Code)
+	} else {
+		recoveryCodes, err = h.mfaService.ConfirmEnrollment(c.UserContext(), user.ID, req.Code)
+	}
+	if err != nil {
+		if resp, ok := mfaErrorResponse(c, err); ok {
+			return resp
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify MFA code",
+		})
+	}
+
+	// Reload so the response reflects a newly confirmed enrollment
+	if updated, err := h.authService.GetUserByID(c.UserContext(), user.ID); err == nil {
+		user = updated
+	}
+
+	if err := h.authService.UpdateLastLogin(c.UserContext(), user); err != nil {
+		log.Printf("⚠️  Failed to update last_login_at for user %s: %v", user.ID, err)
+	}
+
+	accessToken, refreshToken, err := h.jwtService.GenerateTokenPair(
+		user.ID.String(),
+		user.OrganizationID.String(),
+		user.Email,
+		string(user.Role),
+	)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate tokens",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		user.OrganizationID,
+		user.ID,
+		domain.AuditActionLogin,
+		"user",
+		user.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"mfa":                true,
+			"mfa_enrolled":       recoveryCodes != nil,
+			"recovery_code_used": recoveryCodeUsed,
+		},
+	)
+
+	c.Cookie(&fiber.Cookie{
+		Name:     "access_token",
+		Value:    accessToken,
+		HTTPOnly: true,
+		SameSite: "Lax",
+	})
+
+	c.Cookie(&fiber.Cookie{
+		Name:     "refresh_token",
+		Value:    refreshToken,
+		HTTPOnly: true,
+		SameSite: "Lax",
+	})
+
+	message := "Login successful"
+	if user.ForcePasswordChange {
+		message = "You must change your password before continuing"
+	}
+	return c.JSON(&LoginResponse{
+		Success:                true,
+		Message:                message,
+		User:                   user,
+		AccessToken:            &accessToken,
+		RefreshToken:           &refreshToken,
+		IsApproved:             true,
+		RequiresPasswordChange: user.ForcePasswordChange,
+		RecoveryCodes:          recoveryCodes,
+		RecoveryCodeUsed:       recoveryCodeUsed,
+	})
+}
+
+// BeginLoginEnrollment starts enrollment for a user whose role requires MFA but who has not enrolled
+// @Summary Enroll in MFA during login
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body MFALoginRequest true "Challenge token"
+// @Success 200 {object} application.MFAEnrollment
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/auth/login/mfa/enroll [post]
+func (h *MFAHandler) BeginLoginEnrollment(c fiber.Ctx) error {
+	var req MFALoginRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	user, err := h.challengeUser(c, req.MFAToken)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid or expired MFA token",
+		})
+	}
+
+	return h.beginEnrollment(c, user.ID)
+}
+
+// GetStatus returns the current user's MFA state
+// @Summary Get MFA status
+// @Tags auth
+// @Produce json
+// @Success 200 {object} application.MFAStatus
+// @Router /api/v1/auth/mfa [get]
+func (h *MFAHandler) GetStatus(c fiber.Ctx) error {
+	status, err := h.mfaService.Status(c.UserContext(), c.Locals("user_id").(uuid.UUID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch MFA status",
+		})
+	}
+	return c.JSON(status)
+}
+
+// BeginEnrollment generates a TOTP secret for the current user
+// @Summary Start MFA enrollment
+// @Description Returns the secret and an otpauth:// URI for an authenticator app. MFA is enabled once confirmed with a code.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} application.MFAEnrollment
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/auth/mfa/enroll [post]
+func (h *MFAHandler) BeginEnrollment(c fiber.Ctx) error {
+	return h.beginEnrollment(c, c.Locals("user_id").(uuid.UUID))
+}
+
+func (h *MFAHandler) beginEnrollment(c fiber.Ctx, userID uuid.UUID) error {
+	enrollment, err := h.mfaService.BeginEnrollment(c.UserContext(), userID)
+	if err != nil {
+		if resp, ok := mfaErrorResponse(c, err); ok {
+			return resp
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start MFA enrollment",
+		})
+	}
+	return c.JSON(enrollment)
+}
+
+// ConfirmEnrollment enables MFA for the current user
+// @Summary Confirm MFA enrollment
+// @Description Enables MFA and returns recovery codes, which are only shown once
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body MFACodeRequest true "Code from the authenticator app"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/auth/mfa/confirm [post]
+func (h *MFAHandler) ConfirmEnrollment(c fiber.Ctx) error {
+	return h.withCode(c, "enable", func(ctx context.Context, userID uuid.UUID, code string) (fiber.Map, error) {
+		codes, err := h.mfaService.ConfirmEnrollment(ctx, userID, code)
+		return fiber.Map{"enabled": true, "recoveryCodes": codes}, err
+	})
+}
+
+// Disable turns MFA off for the current user
+// @Summary Disable MFA
+// @Description Not allowed when the organization requires MFA for the user's role
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body MFACodeRequest true "TOTP code or recovery code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/auth/mfa/disable [post]
+func (h *MFAHandler) Disable(c fiber.Ctx) error {
+	return h.withCode(c, "disable", func(ctx context.Context, userID uuid.UUID, code string) (fiber.Map, error) {
+		return fiber.Map{"enabled": false}, h.mfaService.Disable(ctx, userID, code)
+	})
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes
+// @Summary Regenerate MFA recovery codes
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body MFACodeRequest true "TOTP code or recovery code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/auth/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c fiber.Ctx) error {
+	return h.withCode(c, "regenerate_recovery_codes", func(ctx context.Context, userID uuid.UUID, code string) (fiber.Map, error) {
+		codes, err := h.mfaService.RegenerateRecoveryCodes(ctx, userID, code)
+		return fiber.Map{"recoveryCodes": codes}, err
+	})
+}
+
+// ResetUserMFA removes a user's MFA enrollment
+// @Summary Reset a user's MFA
+// @Description For users who lost their authenticator and recovery codes. They enroll again at the next login if their role requires MFA.
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/mfa/reset [post]
+func (h *MFAHandler) ResetUserMFA(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	adminID := c.Locals("user_id").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	if err := h.mfaService.Reset(c.UserContext(), orgID, userID); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		adminID,
+		domain.AuditActionUpdate,
+		"user_mfa",
+		userID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"mfa_action": "reset",
+		},
+	)
+
+	return c.JSON(fiber.Map{
+		"message": "MFA reset; the user must enroll again if their role requires it",
+	})
+}
+
+// withCode runs an MFA change for the current user that needs a code, and audits it
+func (h *MFAHandler) withCode(
+	c fiber.Ctx,
+	action string,
+	fn func(ctx context.Context, userID uuid.UUID, code string) (fiber.Map, error),
+) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req MFACodeRequest
+	if err := c.Bind().JSON(&req); err != nil || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "code is required",
+		})
+	}
+
+	result, err := fn(c.UserContext(), userID, req.Code)
+	if err != nil {
+		if resp, ok := mfaErrorResponse(c, err); ok {
+			return resp
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update MFA",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"user_mfa",
+		userID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"mfa_action": action,
+		},
+	)
+
+	return c.JSON(result)
+}
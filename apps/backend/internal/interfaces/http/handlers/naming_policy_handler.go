@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type NamingPolicyHandler struct {
+	namingService *application.NamingPolicyService
+	auditService  *application.AuditService
+}
+
+func NewNamingPolicyHandler(
+	namingService *application.NamingPolicyService,
+	auditService *application.AuditService,
+) *NamingPolicyHandler {
+	return &NamingPolicyHandler{
+		namingService: namingService,
+		auditService:  auditService,
+	}
+}
+
+// CreatePolicy creates a naming policy
+// @Summary Create naming policy
+// @Description Constrains the names of agents and MCP servers with a pattern, a required prefix, reserved names and uniqueness ignoring case and separators. Scoping the policy to a tag (e.g. environment=production) applies it only to resources carrying the tag. Policies are checked on registration, rename and tagging; existing names are not rechecked.
+// @Tags naming-policies
+// @Accept json
+// @Produce json
+// @Param request body application.NamingPolicyRequest true "Policy details"
+// @Success 201 {object} domain.NamingPolicy
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/naming-policies [post]
+func (h *NamingPolicyHandler) CreatePolicy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.NamingPolicyRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	policy, err := h.namingService.CreatePolicy(c.UserContext(), &req, orgID, userID)
+	if err != nil {
+		return namingPolicyError(c, err)
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"naming_policy",
+		policy.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":           policy.Name,
+			"resourceType":   policy.ResourceType,
+			"tagKey":         policy.TagKey,
+			"tagValue":       policy.TagValue,
+			"pattern":        policy.Pattern,
+			"requiredPrefix": policy.RequiredPrefix,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(policy)
+}
+
+// ListPolicies lists the organization's naming policies
+// @Summary List naming policies
+// @Tags naming-policies
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/naming-policies [get]
+func (h *NamingPolicyHandler) ListPolicies(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	policies, err := h.namingService.ListPolicies(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch naming policies",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"policies": policies,
+		"total":    len(policies),
+	})
+}
+
+// GetPolicy retrieves a naming policy
+// @Summary Get naming policy
+// @Tags naming-policies
+// @Produce json
+// @Param id path string true "Policy ID"
+// @Success 200 {object} domain.NamingPolicy
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/naming-policies/{id} [get]
+func (h *NamingPolicyHandler) GetPolicy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid policy ID",
+		})
+	}
+
+	policy, err := h.namingService.GetPolicy(c.UserContext(), orgID, id)
+	if err != nil {
+		return namingPolicyError(c, err)
+	}
+
+	return c.JSON(policy)
+}
+
+// UpdatePolicy updates a naming policy
+// @Summary Update naming policy
+// @Description Replaces the policy's scope and rules. Existing names are not rechecked.
+// @Tags naming-policies
+// @Accept json
+// @Produce json
+// @Param id path string true "Policy ID"
+// @Param request body application.NamingPolicyRequest true "Policy details"
+// @Success 200 {object} domain.NamingPolicy
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/naming-policies/{id} [put]
+func (h *NamingPolicyHandler) UpdatePolicy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid policy ID",
+		})
+	}
+
+	var req application.NamingPolicyRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	policy, err := h.namingService.UpdatePolicy(c.UserContext(), orgID, id, &req)
+	if err != nil {
+		return namingPolicyError(c, err)
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"naming_policy",
+		policy.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":     policy.Name,
+			"isActive": policy.IsActive,
+		},
+	)
+
+	return c.JSON(policy)
+}
+
+// DeletePolicy deletes a naming policy
+// @Summary Delete naming policy
+// @Tags naming-policies
+// @Param id path string true "Policy ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/naming-policies/{id} [delete]
+func (h *NamingPolicyHandler) DeletePolicy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid policy ID",
+		})
+	}
+
+	if err := h.namingService.DeletePolicy(c.UserContext(), orgID, id); err != nil {
+		return namingPolicyError(c, err)
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"naming_policy",
+		id,
+		c.IP(),
+		c.Get("User-Agent"),
+		nil,
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// PreviewName checks a name against the organization's naming policies
+// @Summary Preview naming policies
+// @Description Reports whether a name would be accepted for a new agent or MCP server, or for renaming one (resourceId). Tags the resource will carry select the policies scoped to them. Nothing is changed.
+// @Tags naming-policies
+// @Accept json
+// @Produce json
+// @Param request body application.NamingPreviewRequest true "Name to check"
+// @Success 200 {object} application.NamingPreview
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/naming-policies/preview [post]
+func (h *NamingPolicyHandler) PreviewName(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	var req application.NamingPreviewRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	preview, err := h.namingService.PreviewName(c.UserContext(), orgID, &req)
+	if err != nil {
+		return namingPolicyError(c, err)
+	}
+
+	return c.JSON(preview)
+}
+
+func namingPolicyError(c fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNamingPolicyNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrInvalidNamingPolicy):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+}
+
+// namingPolicyViolationResponse answers a rename or tagging the organization's naming policies refuse
+func namingPolicyViolationResponse(c fiber.Ctx, err error) error {
+	response := fiber.Map{
+		"error": err.Error(),
+	}
+	var violationErr *application.NamingPolicyViolationError
+	if errors.As(err, &violationErr) {
+		response["violations"] = violationErr.Violations
+	}
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(response)
+}
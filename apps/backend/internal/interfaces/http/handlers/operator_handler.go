@@ -0,0 +1,451 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// OperatorHandler serves the platform operator API. It is mounted on its own listener, never on
+// the tenant API, and reads only the operator locals set by OperatorAuthMiddleware.
+type OperatorHandler struct {
+	operatorService *application.PlatformOperatorService
+}
+
+func NewOperatorHandler(operatorService *application.PlatformOperatorService) *OperatorHandler {
+	return &OperatorHandler{
+		operatorService: operatorService,
+	}
+}
+
+// Me returns the authenticated operator
+// @Summary Current operator
+// @Tags operator
+// @Produce json
+// @Success 200 {object} domain.PlatformOperator
+// @Router /operator/v1/me [get]
+func (h *OperatorHandler) Me(c fiber.Ctx) error {
+	return c.JSON(c.Locals("operator").(*domain.PlatformOperator))
+}
+
+// ListOrganizations lists every organization with its usage and health
+// @Summary List organizations
+// @Description Every organization with its plan, usage (users, agents, MCP servers, verifications in the last 24 hours) and health (open incidents, unacknowledged alerts, verification success rate, throttle).
+// @Tags operator
+// @Produce json
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /operator/v1/organizations [get]
+func (h *OperatorHandler) ListOrganizations(c fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	orgs, total, err := h.operatorService.ListOrganizations(c.UserContext(), limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch organizations",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"organizations": orgs,
+		"total":         total,
+	})
+}
+
+// GetOrganization returns one organization's usage and health
+// @Summary Get organization
+// @Tags operator
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} application.OrganizationOverview
+// @Failure 404 {object} map[string]interface{}
+// @Router /operator/v1/organizations/{id} [get]
+func (h *OperatorHandler) GetOrganization(c fiber.Ctx) error {
+	orgID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid organization ID",
+		})
+	}
+
+	overview, err := h.operatorService.GetOrganization(c.UserContext(), orgID)
+	if err != nil {
+		return operatorError(c, err)
+	}
+
+	return c.JSON(overview)
+}
+
+// SetThrottle caps an organization's rate limits
+// @Summary Throttle organization
+// @Description Caps every rate limit of the organization at requestsPerMinute, whatever its own settings allow, for durationMinutes or until lifted when 0.
+// @Tags operator
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body application.ThrottleRequest true "Throttle"
+// @Success 200 {object} domain.OrganizationThrottle
+// @Failure 400 {object} map[string]interface{}
+// @Router /operator/v1/organizations/{id}/throttle [put]
+func (h *OperatorHandler) SetThrottle(c fiber.Ctx) error {
+	orgID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid organization ID",
+		})
+	}
+
+	var req application.ThrottleRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	throttle, err := h.operatorService.SetThrottle(c.UserContext(), operatorOf(c), c.IP(), orgID, &req)
+	if err != nil {
+		return operatorError(c, err)
+	}
+
+	return c.JSON(throttle)
+}
+
+// RemoveThrottle lifts an organization's throttle
+// @Summary Lift throttle
+// @Tags operator
+// @Param id path string true "Organization ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /operator/v1/organizations/{id}/throttle [delete]
+func (h *OperatorHandler) RemoveThrottle(c fiber.Ctx) error {
+	orgID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid organization ID",
+		})
+	}
+
+	if err := h.operatorService.RemoveThrottle(c.UserContext(), operatorOf(c), c.IP(), orgID); err != nil {
+		return operatorError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListThrottles lists the throttles in force
+// @Summary List throttles
+// @Tags operator
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /operator/v1/throttles [get]
+func (h *OperatorHandler) ListThrottles(c fiber.Ctx) error {
+	throttles, err := h.operatorService.ListThrottles(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch throttles",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"throttles": throttles,
+		"total":     len(throttles),
+	})
+}
+
+// ListJobs returns the background jobs of the instance answering the request
+// @Summary List background jobs
+// @Description Only the leader instance runs background jobs; a job whose run takes more than three of its intervals is reported stuck.
+// @Tags operator
+// @Produce json
+// @Success 200 {object} application.JobsOverview
+// @Router /operator/v1/jobs [get]
+func (h *OperatorHandler) ListJobs(c fiber.Ctx) error {
+	return c.JSON(h.operatorService.Jobs(c.UserContext()))
+}
+
+// CancelJob cancels a job's run in progress
+// @Summary Cancel job run
+// @Tags operator
+// @Param name path string true "Job name"
+// @Success 202 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /operator/v1/jobs/{name}/cancel [post]
+func (h *OperatorHandler) CancelJob(c fiber.Ctx) error {
+	if err := h.operatorService.CancelJob(c.UserContext(), operatorOf(c), c.IP(), c.Params("name")); err != nil {
+		return operatorError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Job run cancelled",
+	})
+}
+
+// TriggerJob starts a job's run now
+// @Summary Trigger job run
+// @Tags operator
+// @Param name path string true "Job name"
+// @Success 202 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /operator/v1/jobs/{name}/trigger [post]
+func (h *OperatorHandler) TriggerJob(c fiber.Ctx) error {
+	if err := h.operatorService.TriggerJob(c.UserContext(), operatorOf(c), c.IP(), c.Params("name")); err != nil {
+		return operatorError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Job run started",
+	})
+}
+
+// RequestImpersonation asks an organization's admins for consent to act in the organization
+// @Summary Request impersonation
+// @Description The organization's admins are alerted and must approve the request before the session can be started. Requests wait 24 hours for consent.
+// @Tags operator
+// @Accept json
+// @Produce json
+// @Param request body application.ImpersonationRequestInput true "Impersonation request"
+// @Success 201 {object} domain.ImpersonationRequest
+// @Failure 400 {object} map[string]interface{}
+// @Router /operator/v1/impersonations [post]
+func (h *OperatorHandler) RequestImpersonation(c fiber.Ctx) error {
+	var req application.ImpersonationRequestInput
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	request, err := h.operatorService.RequestImpersonation(c.UserContext(), operatorOf(c), c.IP(), &req)
+	if err != nil {
+		return operatorError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(request)
+}
+
+// ListImpersonations lists impersonation requests of every organization
+// @Summary List impersonation requests
+// @Tags operator
+// @Produce json
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /operator/v1/impersonations [get]
+func (h *OperatorHandler) ListImpersonations(c fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	requests, err := h.operatorService.ListImpersonations(c.UserContext(), limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch impersonation requests",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"impersonations": requests,
+		"total":          len(requests),
+	})
+}
+
+// StartImpersonation starts the session of an approved request
+// @Summary Start impersonation
+// @Description Returns the session token, which is not shown again. Send it as "Authorization: Bearer aimimp_..." to the tenant API; it acts as the approving admin with the requested role until it expires, is ended or is revoked.
+// @Tags operator
+// @Produce json
+// @Param id path string true "Impersonation request ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /operator/v1/impersonations/{id}/start [post]
+func (h *OperatorHandler) StartImpersonation(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid impersonation request ID",
+		})
+	}
+
+	token, request, err := h.operatorService.StartImpersonation(c.UserContext(), operatorOf(c), c.IP(), id)
+	if err != nil {
+		return operatorError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"impersonation": request,
+		"token":         token,
+	})
+}
+
+// EndImpersonation ends a session early
+// @Summary End impersonation
+// @Tags operator
+// @Produce json
+// @Param id path string true "Impersonation request ID"
+// @Success 200 {object} domain.ImpersonationRequest
+// @Failure 409 {object} map[string]interface{}
+// @Router /operator/v1/impersonations/{id}/end [post]
+func (h *OperatorHandler) EndImpersonation(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid impersonation request ID",
+		})
+	}
+
+	request, err := h.operatorService.EndImpersonation(c.UserContext(), operatorOf(c), c.IP(), id)
+	if err != nil {
+		return operatorError(c, err)
+	}
+
+	return c.JSON(request)
+}
+
+// ListOperators lists the platform operators
+// @Summary List operators
+// @Tags operator
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /operator/v1/operators [get]
+func (h *OperatorHandler) ListOperators(c fiber.Ctx) error {
+	operators, err := h.operatorService.ListOperators(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch operators",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"operators": operators,
+		"total":     len(operators),
+	})
+}
+
+// CreateOperator adds a platform operator
+// @Summary Create operator
+// @Description The response carries the operator's token, which is not shown again.
+// @Tags operator
+// @Accept json
+// @Produce json
+// @Param request body application.CreateOperatorRequest true "Operator"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /operator/v1/operators [post]
+func (h *OperatorHandler) CreateOperator(c fiber.Ctx) error {
+	var req application.CreateOperatorRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	token, operator, err := h.operatorService.CreateOperator(c.UserContext(), operatorOf(c), c.IP(), &req)
+	if err != nil {
+		return operatorError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"operator": operator,
+		"token":    token,
+	})
+}
+
+// UpdateOperator changes an operator's name, role or status, or rotates their token
+// @Summary Update operator
+// @Tags operator
+// @Accept json
+// @Produce json
+// @Param id path string true "Operator ID"
+// @Param request body application.UpdateOperatorRequest true "Changes"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /operator/v1/operators/{id} [patch]
+func (h *OperatorHandler) UpdateOperator(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid operator ID",
+		})
+	}
+
+	var req application.UpdateOperatorRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	token, operator, err := h.operatorService.UpdateOperator(c.UserContext(), operatorOf(c), c.IP(), id, &req)
+	if err != nil {
+		return operatorError(c, err)
+	}
+
+	response := fiber.Map{"operator": operator}
+	if token != "" {
+		response["token"] = token
+	}
+	return c.JSON(response)
+}
+
+// ListActions returns the operator log
+// @Summary Operator log
+// @Description Every change made through the operator API, newest first.
+// @Tags operator
+// @Produce json
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /operator/v1/actions [get]
+func (h *OperatorHandler) ListActions(c fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	actions, err := h.operatorService.ListActions(c.UserContext(), limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch operator log",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"actions": actions,
+		"total":   len(actions),
+	})
+}
+
+func operatorOf(c fiber.Ctx) *domain.PlatformOperator {
+	return c.Locals("operator").(*domain.PlatformOperator)
+}
+
+func operatorError(c fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, application.ErrOperatorOrganizationNotFound),
+		errors.Is(err, domain.ErrPlatformOperatorNotFound),
+		errors.Is(err, domain.ErrOrganizationThrottleNotFound),
+		errors.Is(err, domain.ErrImpersonationNotFound),
+		errors.Is(err, application.ErrJobNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrInvalidOperatorRequest):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrImpersonationConflict),
+		errors.Is(err, application.ErrJobNotRunning),
+		errors.Is(err, application.ErrJobAlreadyRunning),
+		errors.Is(err, application.ErrNotJobLeader):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+}
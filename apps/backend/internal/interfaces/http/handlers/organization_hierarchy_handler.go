@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type OrganizationHierarchyHandler struct {
+	hierarchyService *application.OrganizationHierarchyService
+	auditService     *application.AuditService
+}
+
+func NewOrganizationHierarchyHandler(
+	hierarchyService *application.OrganizationHierarchyService,
+	auditService *application.AuditService,
+) *OrganizationHierarchyHandler {
+	return &OrganizationHierarchyHandler{
+		hierarchyService: hierarchyService,
+		auditService:     auditService,
+	}
+}
+
+// GetHierarchy returns the current organization's parents and sub-organizations
+// @Summary Get the organization hierarchy
+// @Description The chain of parent organizations and the tree of sub-organizations below the current organization. Sibling organizations are never included.
+// @Tags organizations
+// @Produce json
+// @Success 200 {object} domain.OrganizationHierarchy
+// @Router /api/v1/organizations/hierarchy [get]
+func (h *OrganizationHierarchyHandler) GetHierarchy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	hierarchy, err := h.hierarchyService.GetHierarchy(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch organization hierarchy",
+		})
+	}
+
+	return c.JSON(hierarchy)
+}
+
+// GetSecurityRollup rolls up the security metrics of the organization and its sub-organizations
+// @Summary Get roll-up security metrics
+// @Description Security metrics of the current organization and each sub-organization, with totals across the hierarchy. The total security score is the lowest of the organizations.
+// @Tags organizations
+// @Produce json
+// @Success 200 {object} domain.OrganizationSecurityRollup
+// @Router /api/v1/organizations/hierarchy/security-metrics [get]
+func (h *OrganizationHierarchyHandler) GetSecurityRollup(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	rollup, err := h.hierarchyService.SecurityRollup(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch security metrics",
+		})
+	}
+
+	return c.JSON(rollup)
+}
+
+// ListOrganizationAgents lists the agents of a sub-organization
+// @Summary List the agents of a sub-organization
+// @Description Admins can view the agents of any organization below theirs. Parent and sibling organizations are not visible.
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/organizations/{id}/agents [get]
+func (h *OrganizationHierarchyHandler) ListOrganizationAgents(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	targetOrgID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid organization ID",
+		})
+	}
+
+	agents, err := h.hierarchyService.ListAgents(c.UserContext(), orgID, targetOrgID)
+	if err != nil {
+		if errors.Is(err, application.ErrOrganizationNotInHierarchy) {
+			// Organizations outside the hierarchy are reported as missing, not forbidden
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Organization not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch agents",
+		})
+	}
+
+	if targetOrgID != orgID {
+		h.auditService.LogAction(
+			c.UserContext(),
+			orgID,
+			userID,
+			domain.AuditActionView,
+			"organization",
+			targetOrgID,
+			c.IP(),
+			c.Get("User-Agent"),
+			map[string]interface{}{
+				"resource": "agents",
+				"agents":   len(agents),
+			},
+		)
+	}
+
+	return c.JSON(fiber.Map{
+		"organizationId": targetOrgID,
+		"agents":         agents,
+		"total":          len(agents),
+	})
+}
+
+// CreateSubOrganization creates a business unit under the current organization
+// @Summary Create a sub-organization
+// @Description The sub-organization starts with this organization's plan, limits and MFA requirements, inherits its security policies and trust boundaries, and can't be moved to another parent. Users signing up with its email domain join it.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body application.CreateSubOrganizationRequest true "Sub-organization"
+// @Success 201 {object} domain.Organization
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/organization/sub-organizations [post]
+func (h *OrganizationHierarchyHandler) CreateSubOrganization(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.CreateSubOrganizationRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	child, err := h.hierarchyService.CreateSubOrganization(c.UserContext(), orgID, &req)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidSubOrganization) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create sub-organization",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"organization",
+		child.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":      child.Name,
+			"domain":    child.Domain,
+			"parent_id": orgID,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(child)
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type PersonalAccessTokenHandler struct {
+	tokenService *application.PersonalAccessTokenService
+	auditService *application.AuditService
+}
+
+func NewPersonalAccessTokenHandler(
+	tokenService *application.PersonalAccessTokenService,
+	auditService *application.AuditService,
+) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{
+		tokenService: tokenService,
+		auditService: auditService,
+	}
+}
+
+// RevokePersonalAccessTokenRequest optionally says why a token is revoked
+type RevokePersonalAccessTokenRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ListScopes lists the scopes personal access tokens can be created with
+// @Summary List personal access token scopes
+// @Tags users
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/users/me/tokens/scopes [get]
+func (h *PersonalAccessTokenHandler) ListScopes(c fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"scopes":         h.tokenService.Scopes(),
+		"defaultDays":    application.DefaultPersonalAccessTokenDays,
+		"maxDays":        application.MaxPersonalAccessTokenDays,
+		"wildcardScopes": []string{domain.PersonalAccessTokenScopeAll, "<resource>:*"},
+	})
+}
+
+// ListTokens lists the user's personal access tokens
+// @Summary List my personal access tokens
+// @Description Newest first, revoked and expired tokens included. Token values are never returned.
+// @Tags users
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/users/me/tokens [get]
+func (h *PersonalAccessTokenHandler) ListTokens(c fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	tokens, err := h.tokenService.ListUserTokens(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch personal access tokens",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"tokens": tokens,
+		"total":  len(tokens),
+	})
+}
+
+// CreateToken creates a personal access token
+// @Summary Create a personal access token
+// @Description Scoped, expiring token to script against the API as yourself. The token is only returned in this response.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body application.CreatePersonalAccessTokenRequest true "Token name, scopes and expiry"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/users/me/tokens [post]
+func (h *PersonalAccessTokenHandler) CreateToken(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.CreatePersonalAccessTokenRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	plainToken, token, err := h.tokenService.CreateToken(c.UserContext(), orgID, userID, &req)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidPersonalAccessToken) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":       err.Error(),
+				"validScopes": domain.PersonalAccessTokenScopes,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create personal access token",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"personal_access_token",
+		token.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":       token.Name,
+			"scopes":     token.Scopes,
+			"expires_at": token.ExpiresAt,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"id":        token.ID,
+		"token":     plainToken, // Only returned once!
+		"name":      token.Name,
+		"prefix":    token.Prefix,
+		"scopes":    token.Scopes,
+		"expiresAt": token.ExpiresAt,
+		"createdAt": token.CreatedAt,
+	})
+}
+
+// RevokeToken revokes one of the user's personal access tokens
+// @Summary Revoke my personal access token
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "Token ID"
+// @Param request body RevokePersonalAccessTokenRequest false "Reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/users/me/tokens/{id}/revoke [post]
+func (h *PersonalAccessTokenHandler) RevokeToken(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	tokenID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid token ID",
+		})
+	}
+
+	req := h.revokeRequest(c, "User-initiated revocation")
+	if err := h.tokenService.RevokeUserToken(c.UserContext(), userID, tokenID, req.Reason); err != nil {
+		if errors.Is(err, domain.ErrPersonalAccessTokenNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Personal access token not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke personal access token",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionRevoke,
+		"personal_access_token",
+		tokenID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"reason": req.Reason,
+		},
+	)
+
+	return c.JSON(fiber.Map{
+		"message": "Token revoked successfully",
+	})
+}
+
+// ListOrganizationTokens lists the personal access tokens of every user of the organization
+// @Summary List personal access tokens (admin)
+// @Description Newest first, revoked and expired tokens included, with when and from where each was last used
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/personal-access-tokens [get]
+func (h *PersonalAccessTokenHandler) ListOrganizationTokens(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	tokens, err := h.tokenService.ListOrganizationTokens(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch personal access tokens",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"tokens": tokens,
+		"total":  len(tokens),
+	})
+}
+
+// AdminRevokeToken revokes any personal access token of the organization
+// @Summary Revoke a personal access token (admin)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Token ID"
+// @Param request body RevokePersonalAccessTokenRequest false "Reason"
+// @Success 200 {object} domain.PersonalAccessToken
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/personal-access-tokens/{id}/revoke [post]
+func (h *PersonalAccessTokenHandler) AdminRevokeToken(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	tokenID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid token ID",
+		})
+	}
+
+	req := h.revokeRequest(c, "Revoked by an administrator")
+	token, err := h.tokenService.RevokeToken(c.UserContext(), orgID, tokenID, userID, req.Reason)
+	if err != nil {
+		if errors.Is(err, domain.ErrPersonalAccessTokenNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Personal access token not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke personal access token",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionRevoke,
+		"personal_access_token",
+		tokenID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"owner_id": token.UserID.String(),
+			"reason":   req.Reason,
+		},
+	)
+
+	return c.JSON(token)
+}
+
+// revokeRequest reads the optional revocation reason, falling back to a default
+func (h *PersonalAccessTokenHandler) revokeRequest(c fiber.Ctx, defaultReason string) RevokePersonalAccessTokenRequest {
+	var req RevokePersonalAccessTokenRequest
+	if len(c.Body()) > 0 {
+		_ = c.Bind().JSON(&req)
+	}
+	if req.Reason == "" {
+		req.Reason = defaultReason
+	}
+	return req
+}
@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type PersonalDataHandler struct {
+	personalDataService *application.PersonalDataService
+	auditService        *application.AuditService
+}
+
+func NewPersonalDataHandler(
+	personalDataService *application.PersonalDataService,
+	auditService *application.AuditService,
+) *PersonalDataHandler {
+	return &PersonalDataHandler{
+		personalDataService: personalDataService,
+		auditService:        auditService,
+	}
+}
+
+// ExportMyData downloads the personal data held about the current user
+// @Summary Export my personal data
+// @Description JSON bundle of the profile, SDK and personal access tokens (without secrets), audit entries, approvals and erasure requests
+// @Tags users
+// @Produce json
+// @Success 200 {object} domain.UserDataExport
+// @Router /api/v1/users/me/data/export [get]
+func (h *PersonalDataHandler) ExportMyData(c fiber.Ctx) error {
+	return h.export(c, c.Locals("user_id").(uuid.UUID))
+}
+
+// ExportUserData downloads the personal data held about a user of the organization
+// @Summary Export a user's personal data
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} domain.UserDataExport
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/data/export [get]
+func (h *PersonalDataHandler) ExportUserData(c fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+	return h.export(c, userID)
+}
+
+func (h *PersonalDataHandler) export(c fiber.Ctx, userID uuid.UUID) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	actorID := c.Locals("user_id").(uuid.UUID)
+
+	export, err := h.personalDataService.ExportUserData(c.UserContext(), orgID, userID)
+	if err != nil {
+		return personalDataError(c, err, "Failed to export personal data")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		actorID,
+		domain.AuditActionExport,
+		"user_data",
+		userID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"audit_entries": len(export.AuditEntries),
+			"approvals":     len(export.Approvals),
+		},
+	)
+
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=user-data-%s.json", userID))
+	return c.JSON(export)
+}
+
+// RequestMyErasure asks for the current user's personal data to be erased
+// @Summary Request erasure of my personal data
+// @Description Files an erasure request an admin must approve
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body object false "Optional reason"
+// @Success 201 {object} domain.DataErasureRequest
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/users/me/data/erasure [post]
+func (h *PersonalDataHandler) RequestMyErasure(c fiber.Ctx) error {
+	return h.requestErasure(c, c.Locals("user_id").(uuid.UUID))
+}
+
+// RequestUserErasure files a request to erase a user's personal data on their behalf
+// @Summary Request erasure of a user's personal data
+// @Description Another admin must approve the request
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body object false "Optional reason"
+// @Success 201 {object} domain.DataErasureRequest
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/data/erasure [post]
+func (h *PersonalDataHandler) RequestUserErasure(c fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+	return h.requestErasure(c, userID)
+}
+
+func (h *PersonalDataHandler) requestErasure(c fiber.Ctx, userID uuid.UUID) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	actorID := c.Locals("user_id").(uuid.UUID)
+
+	reason, err := bindReason(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	request, err := h.personalDataService.RequestErasure(c.UserContext(), orgID, userID, actorID, reason)
+	if err != nil {
+		return personalDataError(c, err, "Failed to request erasure")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		actorID,
+		domain.AuditActionCreate,
+		"data_erasure_request",
+		request.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"user_id": userID,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(request)
+}
+
+// ListErasureRequests lists the organization's data erasure requests
+// @Summary List data erasure requests
+// @Tags admin
+// @Produce json
+// @Param status query string false "pending, approved, completed or rejected"
+// @Param limit query int false "Limit" default(100)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/data-erasure-requests [get]
+func (h *PersonalDataHandler) ListErasureRequests(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	status := domain.DataErasureStatus(c.Query("status"))
+	switch status {
+	case "", domain.DataErasureStatusPending, domain.DataErasureStatusApproved,
+		domain.DataErasureStatusCompleted, domain.DataErasureStatusRejected:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "status must be pending, approved, completed or rejected",
+		})
+	}
+
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
+			limit = parsedLimit
+		}
+	}
+	offset := 0
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil {
+			offset = parsedOffset
+		}
+	}
+
+	requests, err := h.personalDataService.ListErasureRequests(c.UserContext(), orgID, status, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list erasure requests",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"requests": requests,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// GetErasureRequest returns a data erasure request and, once completed, its report
+// @Summary Get data erasure request
+// @Tags admin
+// @Produce json
+// @Param id path string true "Erasure request ID"
+// @Success 200 {object} domain.DataErasureRequest
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/data-erasure-requests/{id} [get]
+func (h *PersonalDataHandler) GetErasureRequest(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid erasure request ID",
+		})
+	}
+
+	request, err := h.personalDataService.GetErasureRequest(c.UserContext(), orgID, id)
+	if err != nil {
+		return personalDataError(c, err, "Failed to fetch erasure request")
+	}
+	return c.JSON(request)
+}
+
+// ApproveErasureRequest approves the request and erases the user's personal data
+// @Summary Approve data erasure request
+// @Description Revokes the user's tokens and anonymizes the user and the audit logs, verification events, approvals and tombstones referring to them. The response carries the completion report.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Erasure request ID"
+// @Param request body object false "Optional reason"
+// @Success 200 {object} domain.DataErasureRequest
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/data-erasure-requests/{id}/approve [post]
+func (h *PersonalDataHandler) ApproveErasureRequest(c fiber.Ctx) error {
+	return h.decide(c, true)
+}
+
+// RejectErasureRequest rejects the request; nothing is erased
+// @Summary Reject data erasure request
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Erasure request ID"
+// @Param request body object false "Optional reason"
+// @Success 200 {object} domain.DataErasureRequest
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/data-erasure-requests/{id}/reject [post]
+func (h *PersonalDataHandler) RejectErasureRequest(c fiber.Ctx) error {
+	return h.decide(c, false)
+}
+
+func (h *PersonalDataHandler) decide(c fiber.Ctx, approve bool) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid erasure request ID",
+		})
+	}
+
+	reason, err := bindReason(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	var request *domain.DataErasureRequest
+	if approve {
+		request, err = h.personalDataService.ApproveErasure(c.UserContext(), orgID, id, userID, reason)
+	} else {
+		request, err = h.personalDataService.RejectErasure(c.UserContext(), orgID, id, userID, reason)
+	}
+	if err != nil {
+		return personalDataError(c, err, "Failed to decide erasure request")
+	}
+
+	metadata := map[string]interface{}{
+		"status": request.Status,
+		"reason": request.DecisionReason,
+	}
+	if request.Report != nil {
+		metadata["revoked_tokens"] = request.Report.RevokedTokens
+		metadata["anonymized_records"] = request.Report.AnonymizedRecords
+	}
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"data_erasure_request",
+		request.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		metadata,
+	)
+
+	return c.JSON(request)
+}
+
+// bindReason reads the optional {"reason": "..."} body
+func bindReason(c fiber.Ctx) (string, error) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return "", err
+		}
+	}
+	return req.Reason, nil
+}
+
+func personalDataError(c fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrDataErasureRequestNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Erasure request not found",
+		})
+	case errors.Is(err, application.ErrDataSubjectNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	case errors.Is(err, application.ErrDataErasureSelfApproval):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrDataErasureRequestOpen),
+		errors.Is(err, application.ErrDataErasureRequestDecided),
+		errors.Is(err, application.ErrDataErasureLastAdmin),
+		errors.Is(err, application.ErrUserAlreadyErased):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
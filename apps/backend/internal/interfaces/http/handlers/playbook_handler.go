@@ -0,0 +1,506 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type PlaybookHandler struct {
+	playbookService *application.PlaybookService
+	auditService    *application.AuditService
+}
+
+func NewPlaybookHandler(
+	playbookService *application.PlaybookService,
+	auditService *application.AuditService,
+) *PlaybookHandler {
+	return &PlaybookHandler{
+		playbookService: playbookService,
+		auditService:    auditService,
+	}
+}
+
+// RunPlaybookRequest is the body when running a playbook manually
+type RunPlaybookRequest struct {
+	IncidentID uuid.UUID `json:"incidentId"`
+}
+
+// PlaybookDecisionRequest is the optional body when approving, rejecting or cancelling an execution
+type PlaybookDecisionRequest struct {
+	Comment string `json:"comment"`
+}
+
+// playbookErrorResponse maps playbook errors to an HTTP response; it returns false for other errors
+func playbookErrorResponse(c fiber.Ctx, err error) (error, bool) {
+	var status int
+	message := err.Error()
+	switch {
+	case errors.Is(err, domain.ErrPlaybookNotFound):
+		status, message = fiber.StatusNotFound, "Playbook not found"
+	case errors.Is(err, domain.ErrPlaybookExecutionNotFound):
+		status, message = fiber.StatusNotFound, "Playbook execution not found"
+	case errors.Is(err, application.ErrPlaybookIncidentNotFound):
+		status, message = fiber.StatusNotFound, "Incident not found"
+	case errors.Is(err, application.ErrInvalidPlaybook):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, application.ErrPlaybookNotAwaitingApproval), errors.Is(err, application.ErrPlaybookExecutionFinished):
+		status = fiber.StatusConflict
+	default:
+		return nil, false
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"error": message,
+	}), true
+}
+
+// ListPlaybooks lists the organization's containment playbooks
+// @Summary List playbooks
+// @Tags playbooks
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/playbooks [get]
+func (h *PlaybookHandler) ListPlaybooks(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	playbooks, err := h.playbookService.ListPlaybooks(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch playbooks",
+		})
+	}
+
+	if playbooks == nil {
+		playbooks = []*domain.Playbook{}
+	}
+
+	return c.JSON(fiber.Map{
+		"playbooks": playbooks,
+		"total":     len(playbooks),
+	})
+}
+
+// CreatePlaybook creates a containment playbook
+// @Summary Create playbook
+// @Description Bind incident types to ordered containment actions (quarantine_agents, rotate_keys, suppress_alerts, notify_channels, create_ticket). Steps with requiresApproval pause the run until a manager approves or rejects them.
+// @Tags playbooks
+// @Accept json
+// @Produce json
+// @Param request body application.PlaybookRequest true "Playbook"
+// @Success 201 {object} domain.Playbook
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/playbooks [post]
+func (h *PlaybookHandler) CreatePlaybook(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.PlaybookRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	playbook, err := h.playbookService.CreatePlaybook(c.UserContext(), &req, orgID, userID)
+	if err != nil {
+		if resp, ok := playbookErrorResponse(c, err); ok {
+			return resp
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create playbook",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"playbook",
+		playbook.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":           playbook.Name,
+			"incident_types": playbook.IncidentTypes,
+			"steps":          len(playbook.Steps),
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(playbook)
+}
+
+// GetPlaybook retrieves a playbook
+// @Summary Get playbook
+// @Tags playbooks
+// @Produce json
+// @Param id path string true "Playbook ID"
+// @Success 200 {object} domain.Playbook
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/playbooks/{id} [get]
+func (h *PlaybookHandler) GetPlaybook(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	playbookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid playbook ID",
+		})
+	}
+
+	playbook, err := h.playbookService.GetPlaybook(c.UserContext(), orgID, playbookID)
+	if err != nil {
+		if resp, ok := playbookErrorResponse(c, err); ok {
+			return resp
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch playbook",
+		})
+	}
+
+	return c.JSON(playbook)
+}
+
+// UpdatePlaybook replaces a playbook's definition; runs in progress keep their steps
+// @Summary Update playbook
+// @Tags playbooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Playbook ID"
+// @Param request body application.PlaybookRequest true "Playbook"
+// @Success 200 {object} domain.Playbook
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/playbooks/{id} [put]
+func (h *PlaybookHandler) UpdatePlaybook(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	playbookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid playbook ID",
+		})
+	}
+
+	var req application.PlaybookRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	playbook, err := h.playbookService.UpdatePlaybook(c.UserContext(), orgID, playbookID, &req)
+	if err != nil {
+		if resp, ok := playbookErrorResponse(c, err); ok {
+			return resp
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update playbook",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"playbook",
+		playbook.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":       playbook.Name,
+			"steps":      len(playbook.Steps),
+			"is_enabled": playbook.IsEnabled,
+		},
+	)
+
+	return c.JSON(playbook)
+}
+
+// DeletePlaybook deletes a playbook; its execution history is kept
+// @Summary Delete playbook
+// @Tags playbooks
+// @Param id path string true "Playbook ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/playbooks/{id} [delete]
+func (h *PlaybookHandler) DeletePlaybook(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	playbookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid playbook ID",
+		})
+	}
+
+	if err := h.playbookService.DeletePlaybook(c.UserContext(), orgID, playbookID); err != nil {
+		if resp, ok := playbookErrorResponse(c, err); ok {
+			return resp
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete playbook",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"playbook",
+		playbookID,
+		c.IP(),
+		c.Get("User-Agent"),
+		nil,
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RunPlaybook runs a playbook against an incident, whether or not the incident matches it
+// @Summary Run playbook
+// @Tags playbooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Playbook ID"
+// @Param request body RunPlaybookRequest true "Incident to contain"
+// @Success 201 {object} domain.PlaybookExecution
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/playbooks/{id}/run [post]
+func (h *PlaybookHandler) RunPlaybook(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	playbookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid playbook ID",
+		})
+	}
+
+	var req RunPlaybookRequest
+	if err := c.Bind().JSON(&req); err != nil || req.IncidentID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "incidentId is required",
+		})
+	}
+
+	execution, err := h.playbookService.Run(c.UserContext(), orgID, playbookID, req.IncidentID, userID)
+	if err != nil {
+		if resp, ok := playbookErrorResponse(c, err); ok {
+			return resp
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to run playbook",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"playbook_execution",
+		execution.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"playbook_id": playbookID.String(),
+			"incident_id": req.IncidentID.String(),
+			"status":      execution.Status,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(execution)
+}
+
+// ListExecutions lists the organization's playbook executions, newest first
+// @Summary List playbook executions
+// @Tags playbooks
+// @Produce json
+// @Param playbookId query string false "Only executions of this playbook"
+// @Param limit query int false "Limit (default 50)"
+// @Param offset query int false "Offset (default 0)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/playbooks/executions [get]
+func (h *PlaybookHandler) ListExecutions(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var playbookID *uuid.UUID
+	if raw := c.Query("playbookId"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid playbook ID",
+			})
+		}
+		playbookID = &id
+	}
+
+	executions, total, err := h.playbookService.ListExecutions(c.UserContext(), orgID, playbookID, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch playbook executions",
+		})
+	}
+
+	if executions == nil {
+		executions = []*domain.PlaybookExecution{}
+	}
+
+	return c.JSON(fiber.Map{
+		"executions": executions,
+		"total":      total,
+		"limit":      limit,
+		"offset":     offset,
+	})
+}
+
+// GetExecution retrieves a playbook execution with its step results and log
+// @Summary Get playbook execution
+// @Tags playbooks
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {object} domain.PlaybookExecution
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/playbooks/executions/{id} [get]
+func (h *PlaybookHandler) GetExecution(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	executionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid execution ID",
+		})
+	}
+
+	execution, err := h.playbookService.GetExecution(c.UserContext(), orgID, executionID)
+	if err != nil {
+		if resp, ok := playbookErrorResponse(c, err); ok {
+			return resp
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch playbook execution",
+		})
+	}
+
+	return c.JSON(execution)
+}
+
+// ApproveStep runs the step the execution is waiting on and continues the run
+// @Summary Approve playbook step
+// @Tags playbooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Param request body PlaybookDecisionRequest false "Comment"
+// @Success 200 {object} domain.PlaybookExecution
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/playbooks/executions/{id}/approve [post]
+func (h *PlaybookHandler) ApproveStep(c fiber.Ctx) error {
+	return h.decide(c, "approved", h.playbookService.Approve)
+}
+
+// RejectStep skips the step the execution is waiting on and continues the run
+// @Summary Reject playbook step
+// @Tags playbooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Param request body PlaybookDecisionRequest false "Comment"
+// @Success 200 {object} domain.PlaybookExecution
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/playbooks/executions/{id}/reject [post]
+func (h *PlaybookHandler) RejectStep(c fiber.Ctx) error {
+	return h.decide(c, "rejected", h.playbookService.Reject)
+}
+
+// CancelExecution stops a running or paused execution
+// @Summary Cancel playbook execution
+// @Tags playbooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Param request body PlaybookDecisionRequest false "Comment"
+// @Success 200 {object} domain.PlaybookExecution
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/playbooks/executions/{id}/cancel [post]
+func (h *PlaybookHandler) CancelExecution(c fiber.Ctx) error {
+	return h.decide(c, "cancelled", h.playbookService.Cancel)
+}
+
+// decide applies a user's decision to an execution and audits it
+func (h *PlaybookHandler) decide(
+	c fiber.Ctx,
+	decision string,
+	fn func(ctx context.Context, orgID, executionID, userID uuid.UUID, comment string) (*domain.PlaybookExecution, error),
+) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	executionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid execution ID",
+		})
+	}
+
+	var req PlaybookDecisionRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	execution, err := fn(c.UserContext(), orgID, executionID, userID, req.Comment)
+	if err != nil {
+		if resp, ok := playbookErrorResponse(c, err); ok {
+			return resp
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update playbook execution",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"playbook_execution",
+		execution.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"playbook_id": execution.PlaybookID.String(),
+			"decision":    decision,
+			"comment":     req.Comment,
+			"status":      execution.Status,
+		},
+	)
+
+	return c.JSON(execution)
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type PolicyDecisionHandler struct {
+	decisionService *application.PolicyDecisionService
+	auditService    *application.AuditService
+}
+
+func NewPolicyDecisionHandler(
+	decisionService *application.PolicyDecisionService,
+	auditService *application.AuditService,
+) *PolicyDecisionHandler {
+	return &PolicyDecisionHandler{
+		decisionService: decisionService,
+		auditService:    auditService,
+	}
+}
+
+// GetPDP returns the organization's external policy decision point
+// @Summary Get external policy decision point
+// @Tags policy-decisions
+// @Produce json
+// @Success 200 {object} domain.ExternalPolicyDecisionPoint
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/policy-decision-point [get]
+func (h *PolicyDecisionHandler) GetPDP(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	pdp, err := h.decisionService.GetPDP(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch policy decision point",
+		})
+	}
+	if pdp == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "No policy decision point configured",
+		})
+	}
+
+	return c.JSON(pdp)
+}
+
+// ConfigurePDP creates or replaces the organization's external policy decision point
+// @Summary Configure external policy decision point
+// @Description Delegate verify-action / A2A authorization decisions to an Open Policy Agent instance
+// @Tags policy-decisions
+// @Accept json
+// @Produce json
+// @Param request body application.PolicyDecisionPointRequest true "PDP configuration"
+// @Success 200 {object} domain.ExternalPolicyDecisionPoint
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/policy-decision-point [put]
+func (h *PolicyDecisionHandler) ConfigurePDP(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.PolicyDecisionPointRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	pdp, err := h.decisionService.ConfigurePDP(c.UserContext(), &req, orgID, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"policy_decision_point",
+		pdp.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"endpoint":      pdp.Endpoint,
+			"decision_path": pdp.DecisionPath,
+			"mode":          pdp.Mode,
+			"fail_open":     pdp.FailOpen,
+			"is_enabled":    pdp.IsEnabled,
+		},
+	)
+
+	return c.JSON(pdp)
+}
+
+// DeletePDP removes the organization's external policy decision point
+// @Summary Delete external policy decision point
+// @Tags policy-decisions
+// @Success 204
+// @Router /api/v1/admin/policy-decision-point [delete]
+func (h *PolicyDecisionHandler) DeletePDP(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	pdp, err := h.decisionService.GetPDP(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch policy decision point",
+		})
+	}
+	if pdp == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "No policy decision point configured",
+		})
+	}
+
+	if err := h.decisionService.DeletePDP(c.UserContext(), orgID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"policy_decision_point",
+		pdp.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		nil,
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// TestPDP queries the external policy decision point with a sample or supplied input document
+// @Summary Test external policy decision point
+// @Description Sends the request body (or a sample verify-action input) to OPA; nothing is recorded
+// @Tags policy-decisions
+// @Accept json
+// @Produce json
+// @Param request body map[string]interface{} false "Input document"
+// @Success 200 {object} domain.ExternalPolicyResult
+// @Failure 502 {object} map[string]interface{}
+// @Router /api/v1/admin/policy-decision-point/test [post]
+func (h *PolicyDecisionHandler) TestPDP(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	var input map[string]interface{}
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	result, err := h.decisionService.TestPDP(c.UserContext(), orgID, input)
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(result)
+}
+
+// ListDecisions lists recorded decisions where the external policy decision point was consulted
+// @Summary List policy decisions
+// @Tags policy-decisions
+// @Produce json
+// @Param agent_id query string false "Filter by agent ID"
+// @Param limit query int false "Limit (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/policy-decisions [get]
+func (h *PolicyDecisionHandler) ListDecisions(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var agentID *uuid.UUID
+	if raw := c.Query("agent_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid agent ID",
+			})
+		}
+		agentID = &id
+	}
+
+	decisions, total, err := h.decisionService.ListDecisions(c.UserContext(), orgID, agentID, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch policy decisions",
+		})
+	}
+
+	if decisions == nil {
+		decisions = []*domain.PolicyDecisionRecord{}
+	}
+
+	return c.JSON(fiber.Map{
+		"decisions": decisions,
+		"total":     total,
+		"limit":     limit,
+		"offset":    offset,
+	})
+}
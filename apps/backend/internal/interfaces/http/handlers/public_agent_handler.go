@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -41,6 +42,8 @@ type PublicRegisterRequest struct {
 	UserEmail           string           `json:"user_email"`          // Optional: for user association
 	RepositoryURL       string           `json:"repository_url"`
 	DocumentationURL    string           `json:"documentation_url"`
+	PublicKey           string           `json:"public_key"`          // Optional: key pair generated by the SDK; the private key never leaves the client
+	ProofOfPossession   string           `json:"proof_of_possession"` // Base64 signature of the registration message with that key
 }
 
 // PublicRegisterResponse includes credentials (private key only returned ONCE)
@@ -49,7 +52,7 @@ type PublicRegisterResponse struct {
 	Name        string  `json:"name"`
 	DisplayName string  `json:"display_name"`
 	PublicKey   string  `json:"public_key"`
-	PrivateKey  string  `json:"private_key"` // ⚠️ ONLY returned on registration
+	PrivateKey  string  `json:"private_key,omitempty"` // ⚠️ ONLY returned on registration, and only when AIM generated the key pair
 	AIMURL      string  `json:"aim_url"`
 	Status      string  `json:"status"`
 	TrustScore  float64 `json:"trust_score"`
@@ -58,13 +61,17 @@ type PublicRegisterResponse struct {
 
 // Register handles public agent self-registration
 // @Summary Public agent self-registration
-// @Description Register an agent without authentication. Returns credentials including private key (ONLY ONCE).
+// @Description Register an agent without authentication. Returns credentials including private key (ONLY ONCE) unless the SDK
+// @Description supplied its own public_key with a proof_of_possession signature.
 // @Tags public
 // @Accept json
 // @Produce json
+// @Param validate query bool false "Run every registration check and return the would-be agent without registering it"
 // @Param request body PublicRegisterRequest true "Registration request"
 // @Success 201 {object} PublicRegisterResponse "Agent registered successfully"
+// @Success 200 {object} map[string]interface{} "validate=true and every check passed"
 // @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 422 {object} map[string]interface{} "validate=true and a check failed"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /public/agents/register [post]
 func (h *PublicAgentHandler) Register(c fiber.Ctx) error {
@@ -97,39 +104,68 @@ func (h *PublicAgentHandler) Register(c fiber.Ctx) error {
 	}
 
 	// Validate API key and extract user identity
-	validation, err := h.authService.ValidateAPIKey(c.Context(), apiKey)
+	validation, err := h.authService.ValidateAPIKey(c.UserContext(), apiKey)
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": fmt.Sprintf("Invalid API key: %v", err),
 		})
 	}
 
+	// Scoped keys must be allowed to register agents
+	if !validation.APIKey.HasScope(domain.APIKeyScopeAgentsWrite) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":         "API key is not allowed to register agents",
+			"requiredScope": domain.APIKeyScopeAgentsWrite,
+		})
+	}
+
 	// Use real user and organization from API key
 	userID := validation.User.ID
 	orgID := validation.Organization.ID
 
+	createReq := &application.CreateAgentRequest{
+		Name:              req.Name,
+		DisplayName:       req.DisplayName,
+		Description:       req.Description,
+		AgentType:         req.AgentType,
+		Version:           req.Version,
+		RepositoryURL:     req.RepositoryURL,
+		DocumentationURL:  req.DocumentationURL,
+		PublicKey:         req.PublicKey,
+		ProofOfPossession: req.ProofOfPossession,
+	}
+
+	// Dry run for CI pre-flight checks: validate only, no agent or keys are created
+	if isRegistrationDryRun(c) {
+		agent, validation, err := h.agentService.ValidateAgentRegistration(c.UserContext(), createReq, orgID, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": fmt.Sprintf("Failed to validate agent registration: %v", err),
+			})
+		}
+		return registrationDryRunResponse(c, validation, "agent", agent)
+	}
+
 	// Create agent (keys generated automatically by AgentService)
-	agent, err := h.agentService.CreateAgent(c.Context(), &application.CreateAgentRequest{
-		Name:             req.Name,
-		DisplayName:      req.DisplayName,
-		Description:      req.Description,
-		AgentType:        req.AgentType,
-		Version:          req.Version,
-		RepositoryURL:    req.RepositoryURL,
-		DocumentationURL: req.DocumentationURL,
-	}, orgID, userID)
+	agent, err := h.agentService.CreateAgent(c.UserContext(), createReq, orgID, userID)
 	if err != nil {
+		if errors.Is(err, application.ErrRegistrationInvalid) {
+			return registrationErrorResponse(c, err)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to create agent: %v", err),
 		})
 	}
 
-	// Get the actual keys from the created agent
-	publicKey, privateKey, err := h.agentService.GetAgentCredentials(c.Context(), agent.ID)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to retrieve agent credentials: %v", err),
-		})
+	// Get the actual keys from the created agent; a client-side key pair has no private key to return
+	publicKey, privateKey := req.PublicKey, ""
+	if req.PublicKey == "" {
+		publicKey, privateKey, err = h.agentService.GetAgentCredentials(c.UserContext(), agent.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": fmt.Sprintf("Failed to retrieve agent credentials: %v", err),
+			})
+		}
 	}
 
 	// Calculate initial trust score
@@ -8,7 +8,6 @@ import (
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/application"
-	"github.com/opena2a/identity/backend/internal/crypto"
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
@@ -107,7 +106,7 @@ func (h *PublicMCPHandler) RegisterMCPServer(c fiber.Ctx) error {
 	}
 
 	// Get agent to verify signature
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -132,14 +131,6 @@ func (h *PublicMCPHandler) RegisterMCPServer(c fiber.Ctx) error {
 	// Message format: "register_mcp_server:{agent_id}:{server_name}:{server_url}:{timestamp}"
 	message := "register_mcp_server:" + req.AgentID + ":" + req.ServerName + ":" + req.ServerURL + ":" + strconv.FormatInt(req.Timestamp, 10)
 
-	// Decode hex public key
-	publicKeyBytes, err := crypto.DecodePublicKey(*agent.PublicKey)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid agent public key",
-		})
-	}
-
 	// Decode base64 signature
 	signatureBytes, err := base64.StdEncoding.DecodeString(req.Signature)
 	if err != nil {
@@ -148,8 +139,8 @@ func (h *PublicMCPHandler) RegisterMCPServer(c fiber.Ctx) error {
 		})
 	}
 
-	isValid := crypto.VerifySignature(publicKeyBytes, []byte(message), signatureBytes)
-	if !isValid {
+	// The agent's key verifies with its own algorithm (Ed25519, ECDSA P-256 or RSA-PSS)
+	if _, err := h.agentService.VerifyAgentSignature(c.UserContext(), agent, "", []byte(message), signatureBytes); err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Invalid signature - authentication failed",
 		})
@@ -165,7 +156,7 @@ func (h *PublicMCPHandler) RegisterMCPServer(c fiber.Ctx) error {
 		Capabilities: req.Capabilities,
 	}
 
-	server, err := h.mcpService.CreateMCPServer(c.Context(), createReq, agent.OrganizationID, agentID, &agentID)
+	server, err := h.mcpService.CreateMCPServer(c.UserContext(), createReq, agent.OrganizationID, agentID, &agentID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -174,7 +165,7 @@ func (h *PublicMCPHandler) RegisterMCPServer(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		agent.OrganizationID,
 		agentID,
 		domain.AuditActionCreate,
@@ -224,7 +215,7 @@ func (h *PublicMCPHandler) ListMCPServersForAgent(c fiber.Ctx) error {
 	}
 
 	// Get agent to verify signature
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -238,14 +229,6 @@ func (h *PublicMCPHandler) ListMCPServersForAgent(c fiber.Ctx) error {
 		})
 	}
 
-	// Decode hex public key
-	publicKeyBytes, err := crypto.DecodePublicKey(*agent.PublicKey)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid agent public key",
-		})
-	}
-
 	// Decode base64 signature
 	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
 	if err != nil {
@@ -256,15 +239,15 @@ func (h *PublicMCPHandler) ListMCPServersForAgent(c fiber.Ctx) error {
 
 	// Verify signature
 	message := "list_mcp_servers:" + agentID.String() + ":" + timestamp
-	isValid := crypto.VerifySignature(publicKeyBytes, []byte(message), signatureBytes)
-	if !isValid {
+	// The agent's key verifies with its own algorithm (Ed25519, ECDSA P-256 or RSA-PSS)
+	if _, err := h.agentService.VerifyAgentSignature(c.UserContext(), agent, "", []byte(message), signatureBytes); err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Invalid signature - authentication failed",
 		})
 	}
 
 	// List MCP servers for this agent's organization
-	servers, err := h.mcpService.ListMCPServers(c.Context(), agent.OrganizationID)
+	servers, err := h.mcpService.ListMCPServers(c.UserContext(), agent.OrganizationID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch MCP servers",
@@ -330,7 +313,7 @@ func (h *PublicMCPHandler) VerifyMCPAction(c fiber.Ctx) error {
 	}
 
 	// Get agent
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -344,14 +327,6 @@ func (h *PublicMCPHandler) VerifyMCPAction(c fiber.Ctx) error {
 		})
 	}
 
-	// Decode hex public key
-	publicKeyBytes, err := crypto.DecodePublicKey(*agent.PublicKey)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid agent public key",
-		})
-	}
-
 	// Decode base64 signature
 	signatureBytes, err := base64.StdEncoding.DecodeString(req.Signature)
 	if err != nil {
@@ -362,8 +337,8 @@ func (h *PublicMCPHandler) VerifyMCPAction(c fiber.Ctx) error {
 
 	// Verify signature
 	message := "verify_mcp_action:" + req.AgentID + ":" + serverID.String() + ":" + req.ActionType + ":" + strconv.FormatInt(req.Timestamp, 10)
-	isValid := crypto.VerifySignature(publicKeyBytes, []byte(message), signatureBytes)
-	if !isValid {
+	// The agent's key verifies with its own algorithm (Ed25519, ECDSA P-256 or RSA-PSS)
+	if _, err := h.agentService.VerifyAgentSignature(c.UserContext(), agent, "", []byte(message), signatureBytes); err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Invalid signature - authentication failed",
 		})
@@ -371,7 +346,7 @@ func (h *PublicMCPHandler) VerifyMCPAction(c fiber.Ctx) error {
 
 	// ✅ SIMPLE CAPABILITY CHECK (MVP)
 	// Get MCP server to check if agent is allowed to talk to it
-	mcpServer, err := h.mcpService.GetMCPServer(c.Context(), serverID)
+	mcpServer, err := h.mcpService.GetMCPServer(c.UserContext(), serverID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "MCP server not found",
@@ -15,19 +15,25 @@ import (
 type PublicRegistrationHandler struct {
 	registrationService *application.RegistrationService
 	authService         *application.AuthService
+	mfaService          *application.MFAService
 	jwtService          *auth.JWTService
+	loginProtection     *application.LoginProtectionService
 }
 
 // NewPublicRegistrationHandler creates a new public registration handler
 func NewPublicRegistrationHandler(
 	registrationService *application.RegistrationService,
 	authService *application.AuthService,
+	mfaService *application.MFAService,
 	jwtService *auth.JWTService,
+	loginProtection *application.LoginProtectionService,
 ) *PublicRegistrationHandler {
 	return &PublicRegistrationHandler{
 		registrationService: registrationService,
 		authService:         authService,
+		mfaService:          mfaService,
 		jwtService:          jwtService,
+		loginProtection:     loginProtection,
 	}
 }
 
@@ -84,7 +90,7 @@ func (h *PublicRegistrationHandler) RegisterUser(c fiber.Ctx) error {
 
 	// Create manual registration request with password
 	registrationRequest, err := h.registrationService.CreateManualRegistrationRequest(
-		c.Context(),
+		c.UserContext(),
 		email,
 		firstName,
 		lastName,
@@ -150,7 +156,7 @@ func (h *PublicRegistrationHandler) CheckRegistrationStatus(c fiber.Ctx) error {
 		})
 	}
 
-	registrationRequest, err := h.registrationService.GetRegistrationRequest(c.Context(), requestID)
+	registrationRequest, err := h.registrationService.GetRegistrationRequest(c.UserContext(), requestID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"success": false,
@@ -188,8 +194,9 @@ func (h *PublicRegistrationHandler) CheckRegistrationStatus(c fiber.Ctx) error {
 
 // LoginRequest represents the public login request
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email        string `json:"email" validate:"required,email"`
+	Password     string `json:"password" validate:"required"`
+	CaptchaToken string `json:"captchaToken,omitempty"` // Required once failed logins ask for a CAPTCHA (captchaRequired)
 }
 
 // LoginResponse represents the login response
@@ -201,11 +208,17 @@ type LoginResponse struct {
 	RefreshToken           *string       `json:"refreshToken,omitempty"`
 	IsApproved             bool          `json:"isApproved"`
 	RequiresPasswordChange bool          `json:"requiresPasswordChange,omitempty"`
+	MFARequired            bool          `json:"mfaRequired,omitempty"`           // Send mfaToken and a code to /auth/login/mfa
+	MFAEnrollmentRequired  bool          `json:"mfaEnrollmentRequired,omitempty"` // Enroll at /auth/login/mfa/enroll first
+	MFAToken               *string       `json:"mfaToken,omitempty"`
+	RecoveryCodes          []string      `json:"recoveryCodes,omitempty"`    // Issued when MFA enrollment is confirmed at login
+	RecoveryCodeUsed       bool          `json:"recoveryCodeUsed,omitempty"` // The login used up a recovery code
 }
 
 // Login handles public user login with email and password
 // @Summary Public user login
 // @Description Login with email and password, returns user info and tokens if approved
+// @Description Repeated failures per email or IP address back off (429 with Retry-After), then need a CAPTCHA (401 with captchaRequired), and finally lock the account or block the address for a while (429).
 // @Tags public
 // @Accept json
 // @Produce json
@@ -213,6 +226,7 @@ type LoginResponse struct {
 // @Success 200 {object} LoginResponse
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
 // @Router /api/v1/public/login [post]
 func (h *PublicRegistrationHandler) Login(c fiber.Ctx) error {
 	var req LoginRequest
@@ -234,8 +248,13 @@ func (h *PublicRegistrationHandler) Login(c fiber.Ctx) error {
 	// Normalize email
 	email := strings.ToLower(strings.TrimSpace(req.Email))
 
+	// Locked accounts and addresses are refused before the password is checked
+	if err := h.loginProtection.Check(c.UserContext(), email, c.IP(), req.CaptchaToken); err != nil {
+		return loginBlocked(c, err)
+	}
+
 	// Check users table first - if user exists there, they are automatically approved
-	user, err := h.authService.GetUserByEmail(c.Context(), email)
+	user, err := h.authService.GetUserByEmail(c.UserContext(), email)
 	fmt.Printf("🔍 DEBUG: GetUserByEmail result for %s: user=%v, err=%v\n", email, user, err)
 	if user != nil {
 		fmt.Printf("🔍 DEBUG: User loaded - ID: %s, Email: %s, Role: '%s' (type: %T)\n", user.ID, user.Email, user.Role, user.Role)
@@ -259,6 +278,31 @@ func (h *PublicRegistrationHandler) Login(c fiber.Ctx) error {
 			if err := passwordHasher.VerifyPassword(req.Password, *user.PasswordHash); err == nil {
 				// Check if user must change password (e.g., default admin on first login)
 				fmt.Printf("✅ DEBUG: Password verification PASSED for %s\n", user.Email)
+				loginSucceeded(c, h.loginProtection, email, user)
+
+				// A second factor is checked before any tokens are issued
+				mfaToken, enroll, err := loginMFAChallenge(c.UserContext(), h.mfaService, h.jwtService, user)
+				if err != nil {
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+						"success": false,
+						"error":   "Failed to check MFA requirement",
+					})
+				}
+				if mfaToken != "" {
+					message := "Enter the code from your authenticator app"
+					if enroll {
+						message = "Your organization requires MFA; set up an authenticator app to continue"
+					}
+					return c.JSON(&LoginResponse{
+						Success:               true,
+						Message:               message,
+						IsApproved:            true,
+						MFARequired:           true,
+						MFAEnrollmentRequired: enroll,
+						MFAToken:              &mfaToken,
+					})
+				}
+
 				if user.ForcePasswordChange {
 					// Generate tokens even for forced password change
 					// so user can access the change password page
@@ -275,13 +319,15 @@ func (h *PublicRegistrationHandler) Login(c fiber.Ctx) error {
 	}
 
 	// Not found in users table or password mismatch, check registration_requests table
-	regRequest, err := h.registrationService.GetRegistrationRequestByEmail(c.Context(), email)
+	regRequest, err := h.registrationService.GetRegistrationRequestByEmail(c.UserContext(), email)
 	if err == nil && regRequest != nil {
 		// Check if registration request has password hash
 		if regRequest.PasswordHash != nil && *regRequest.PasswordHash != "" {
 			// Found in registration requests - verify password
 			passwordHasher := auth.NewPasswordHasher()
 			if err := passwordHasher.VerifyPassword(req.Password, *regRequest.PasswordHash); err == nil {
+				loginSucceeded(c, h.loginProtection, email, nil)
+
 				// Password correct - check status
 				if regRequest.Status == domain.RegistrationStatusApproved {
 					// Status = approved - this should not happen if approval process worked correctly
@@ -326,16 +372,13 @@ func (h *PublicRegistrationHandler) Login(c fiber.Ctx) error {
 	}
 
 	// User not found in either table or password incorrect
-	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-		"success": false,
-		"error":   "Invalid email or password",
-	})
+	return loginFailed(c, h.loginProtection, email, user)
 }
 
 // generateApprovedLoginResponse generates tokens and response for approved users
 func (h *PublicRegistrationHandler) generateApprovedLoginResponse(c fiber.Ctx, user *domain.User) error {
 	// Update last login timestamp
-	if err := h.authService.UpdateLastLogin(c.Context(), user); err != nil {
+	if err := h.authService.UpdateLastLogin(c.UserContext(), user); err != nil {
 		// Log warning but continue - this is non-critical
 		fmt.Printf("Warning: failed to update last_login_at for user %s: %v\n", user.ID, err)
 	}
@@ -500,7 +543,7 @@ func (h *PublicRegistrationHandler) RequestAccess(c fiber.Ctx) error {
 	}
 
 	// Check if user already exists
-	existingUser, err := h.authService.GetUserByEmail(c.Context(), email)
+	existingUser, err := h.authService.GetUserByEmail(c.UserContext(), email)
 	if err == nil && existingUser != nil {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 			"success": false,
@@ -509,7 +552,7 @@ func (h *PublicRegistrationHandler) RequestAccess(c fiber.Ctx) error {
 	}
 
 	// Check if registration request already exists
-	existingRequest, err := h.registrationService.GetRegistrationRequestByEmail(c.Context(), email)
+	existingRequest, err := h.registrationService.GetRegistrationRequestByEmail(c.UserContext(), email)
 	if err == nil && existingRequest != nil && existingRequest.Status == domain.RegistrationStatusPending {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 			"success": false,
@@ -520,7 +563,7 @@ func (h *PublicRegistrationHandler) RequestAccess(c fiber.Ctx) error {
 	// Create access request (stored as registration request with no password)
 	// This uses the existing registration request infrastructure
 	registrationRequest, err := h.registrationService.CreateAccessRequest(
-		c.Context(),
+		c.UserContext(),
 		email,
 		firstName,
 		lastName,
@@ -599,7 +642,7 @@ func (h *PublicRegistrationHandler) ChangePassword(c fiber.Ctx) error {
 	email := strings.ToLower(strings.TrimSpace(req.Email))
 
 	// Get user from database
-	user, err := h.authService.GetUserByEmail(c.Context(), email)
+	user, err := h.authService.GetUserByEmail(c.UserContext(), email)
 	if err != nil || user == nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
@@ -616,7 +659,7 @@ func (h *PublicRegistrationHandler) ChangePassword(c fiber.Ctx) error {
 	}
 
 	// Use AuthService.ChangePassword (handles validation, hashing, and force_password_change flag)
-	if err := h.authService.ChangePassword(c.Context(), user.ID, req.OldPassword, req.NewPassword); err != nil {
+	if err := h.authService.ChangePassword(c.UserContext(), user.ID, req.OldPassword, req.NewPassword); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error":   err.Error(),
@@ -624,7 +667,7 @@ func (h *PublicRegistrationHandler) ChangePassword(c fiber.Ctx) error {
 	}
 
 	// Fetch updated user (password was changed)
-	user, err = h.authService.GetUserByEmail(c.Context(), email)
+	user, err = h.authService.GetUserByEmail(c.UserContext(), email)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -675,7 +718,7 @@ func (h *PublicRegistrationHandler) ForgotPassword(c fiber.Ctx) error {
 	}
 
 	// Request password reset (always succeeds for security - don't reveal if email exists)
-	if err := h.registrationService.RequestPasswordReset(c.Context(), req.Email); err != nil {
+	if err := h.registrationService.RequestPasswordReset(c.UserContext(), req.Email); err != nil {
 		// Log error but don't reveal to user
 		fmt.Printf("ERROR in ForgotPassword: %v\n", err)
 	}
@@ -757,7 +800,7 @@ func (h *PublicRegistrationHandler) ResetPassword(c fiber.Ctx) error {
 
 	// Reset password
 	if err := h.registrationService.ResetPassword(
-		c.Context(),
+		c.UserContext(),
 		req.ResetToken,
 		req.NewPassword,
 		req.ConfirmPassword,
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/application"
+)
+
+// isRegistrationDryRun reports whether a registration request only asks for validation (?validate=true)
+func isRegistrationDryRun(c fiber.Ctx) bool {
+	return c.Query("validate", "false") == "true"
+}
+
+// registrationDryRunResponse answers a validate=true registration with the would-be record.
+// Failed validation is reported with 422 so CI pre-flight checks fail without parsing the body.
+func registrationDryRunResponse(c fiber.Ctx, validation *application.RegistrationValidation, key string, record interface{}) error {
+	status := fiber.StatusOK
+	if !validation.Valid {
+		status = fiber.StatusUnprocessableEntity
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"valid":    validation.Valid,
+		"errors":   validation.Errors,
+		"warnings": validation.Warnings,
+		key:        record,
+	})
+}
+
+// registrationErrorResponse maps a failed registration to a status code: conflicts with an
+// existing record are 409, an exhausted quota is 403, other validation failures are 400
+func registrationErrorResponse(c fiber.Ctx, err error) error {
+	var validationErr *application.RegistrationValidationError
+	if !errors.As(err, &validationErr) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	validation := validationErr.Validation
+	status := fiber.StatusBadRequest
+	switch {
+	case validation.HasError(application.RegistrationIssueNameTaken), validation.HasError(application.RegistrationIssueURLTaken):
+		status = fiber.StatusConflict
+	case validation.HasError(application.RegistrationIssueQuotaExceeded):
+		status = fiber.StatusForbidden
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"error":  err.Error(),
+		"errors": validation.Errors,
+	})
+}
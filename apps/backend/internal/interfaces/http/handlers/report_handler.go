@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type ReportHandler struct {
+	reportService *application.ReportService
+	auditService  *application.AuditService
+}
+
+func NewReportHandler(
+	reportService *application.ReportService,
+	auditService *application.AuditService,
+) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+		auditService:  auditService,
+	}
+}
+
+// CreateSubscription creates a new report subscription
+// @Summary Create report subscription
+// @Description Schedule a security posture, verification summary, drift digest or trust changes report for delivery through notification channels
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param request body application.ReportSubscriptionRequest true "Subscription details"
+// @Success 201 {object} domain.ReportSubscription
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/reports/subscriptions [post]
+func (h *ReportHandler) CreateSubscription(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.ReportSubscriptionRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	subscription, err := h.reportService.CreateSubscription(c.UserContext(), &req, orgID, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"report_subscription",
+		subscription.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":        subscription.Name,
+			"report_type": subscription.ReportType,
+			"cadence":     subscription.Cadence,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(subscription)
+}
+
+// ListSubscriptions lists all report subscriptions for the organization
+// @Summary List report subscriptions
+// @Tags reports
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/reports/subscriptions [get]
+func (h *ReportHandler) ListSubscriptions(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	subscriptions, err := h.reportService.ListSubscriptions(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch report subscriptions",
+		})
+	}
+
+	if subscriptions == nil {
+		subscriptions = []*domain.ReportSubscription{}
+	}
+
+	return c.JSON(fiber.Map{
+		"subscriptions": subscriptions,
+		"total":         len(subscriptions),
+	})
+}
+
+// GetSubscription retrieves a single report subscription
+// @Summary Get report subscription
+// @Tags reports
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} domain.ReportSubscription
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/reports/subscriptions/{id} [get]
+func (h *ReportHandler) GetSubscription(c fiber.Ctx) error {
+	subscription, status, message := h.getOwnedSubscription(c)
+	if subscription == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	return c.JSON(subscription)
+}
+
+// UpdateSubscription updates a report subscription
+// @Summary Update report subscription
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param request body application.ReportSubscriptionRequest true "Subscription details"
+// @Success 200 {object} domain.ReportSubscription
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/reports/subscriptions/{id} [put]
+func (h *ReportHandler) UpdateSubscription(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	existing, status, message := h.getOwnedSubscription(c)
+	if existing == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	var req application.ReportSubscriptionRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	subscription, err := h.reportService.UpdateSubscription(c.UserContext(), existing.ID, &req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"report_subscription",
+		subscription.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":     subscription.Name,
+			"isActive": subscription.IsActive,
+		},
+	)
+
+	return c.JSON(subscription)
+}
+
+// DeleteSubscription deletes a report subscription
+// @Summary Delete report subscription
+// @Tags reports
+// @Param id path string true "Subscription ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/reports/subscriptions/{id} [delete]
+func (h *ReportHandler) DeleteSubscription(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	subscription, status, message := h.getOwnedSubscription(c)
+	if subscription == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	if err := h.reportService.DeleteSubscription(c.UserContext(), subscription.ID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"report_subscription",
+		subscription.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		nil,
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RunSubscription generates and delivers a report immediately
+// @Summary Run report now
+// @Description Generate the report for the cadence period ending now and deliver it to the subscription's channels
+// @Tags reports
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 202 {object} domain.ReportRun
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/reports/subscriptions/{id}/run [post]
+func (h *ReportHandler) RunSubscription(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	subscription, status, message := h.getOwnedSubscription(c)
+	if subscription == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	run, err := h.reportService.RunNow(c.UserContext(), subscription)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionGenerate,
+		"report_subscription",
+		subscription.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"report_run_id": run.ID,
+		},
+	)
+
+	return c.Status(fiber.StatusAccepted).JSON(run)
+}
+
+// ListRuns lists the run history of a report subscription
+// @Summary List report runs
+// @Tags reports
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/reports/subscriptions/{id}/runs [get]
+func (h *ReportHandler) ListRuns(c fiber.Ctx) error {
+	subscription, status, message := h.getOwnedSubscription(c)
+	if subscription == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	runs, err := h.reportService.ListRuns(c.UserContext(), subscription.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch report runs",
+		})
+	}
+
+	if runs == nil {
+		runs = []*domain.ReportRun{}
+	}
+
+	return c.JSON(fiber.Map{
+		"runs":  runs,
+		"total": len(runs),
+	})
+}
+
+// DownloadRun downloads the file produced by a report run
+// @Summary Download report run
+// @Tags reports
+// @Produce octet-stream
+// @Param id path string true "Report run ID"
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/reports/runs/{id}/download [get]
+func (h *ReportHandler) DownloadRun(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	runID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid report run ID",
+		})
+	}
+
+	run, err := h.reportService.GetRun(c.UserContext(), runID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Report run not found",
+		})
+	}
+	if run.OrganizationID != orgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied",
+		})
+	}
+
+	return h.sendReportFile(c, run)
+}
+
+// DownloadByToken downloads a report through the link sent in a notification
+// @Summary Download report by link
+// @Description Unauthenticated download using the expiring token from a report notification
+// @Tags reports
+// @Produce octet-stream
+// @Param token path string true "Download token"
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]interface{}
+// @Failure 410 {object} map[string]interface{}
+// @Router /api/v1/public/reports/{token} [get]
+func (h *ReportHandler) DownloadByToken(c fiber.Ctx) error {
+	run, err := h.reportService.GetRunByDownloadToken(c.UserContext(), c.Params("token"))
+	if err != nil {
+		if errors.Is(err, application.ErrReportLinkExpired) {
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Report not found",
+		})
+	}
+
+	return h.sendReportFile(c, run)
+}
+
+func (h *ReportHandler) sendReportFile(c fiber.Ctx, run *domain.ReportRun) error {
+	if run.Status != domain.ReportRunSucceeded {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Report run has no content",
+		})
+	}
+	content, err := h.reportService.GetRunContent(c.UserContext(), run)
+	if err != nil {
+		if errors.Is(err, domain.ErrArtifactNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Report run has no content",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to read report file",
+		})
+	}
+	if len(content) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Report run has no content",
+		})
+	}
+
+	c.Set("Content-Type", run.ContentType)
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", run.Filename))
+	return c.Send(content)
+}
+
+// getOwnedSubscription loads the subscription in the :id param and verifies it belongs to the caller's organization.
+// On failure it returns a nil subscription with the HTTP status and message to respond with.
+func (h *ReportHandler) getOwnedSubscription(c fiber.Ctx) (*domain.ReportSubscription, int, string) {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	subscriptionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, fiber.StatusBadRequest, "Invalid subscription ID"
+	}
+
+	subscription, err := h.reportService.GetSubscription(c.UserContext(), subscriptionID)
+	if err != nil {
+		return nil, fiber.StatusNotFound, "Report subscription not found"
+	}
+	if subscription.OrganizationID != orgID {
+		return nil, fiber.StatusForbidden, "Access denied"
+	}
+
+	return subscription, fiber.StatusOK, ""
+}
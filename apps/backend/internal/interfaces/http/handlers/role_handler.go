@@ -0,0 +1,431 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type RoleHandler struct {
+	roleService  *application.RoleService
+	auditService *application.AuditService
+}
+
+func NewRoleHandler(
+	roleService *application.RoleService,
+	auditService *application.AuditService,
+) *RoleHandler {
+	return &RoleHandler{
+		roleService:  roleService,
+		auditService: auditService,
+	}
+}
+
+// ListPermissions returns the permission catalog
+// @Summary List permissions
+// @Description Every permission routes require, with the least built-in role that has it by default
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/permissions [get]
+func (h *RoleHandler) ListPermissions(c fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"permissions": h.roleService.ListPermissions(),
+	})
+}
+
+// GetMyPermissions returns the permissions of the caller
+// @Summary Get my permissions
+// @Description The permissions of the caller's built-in role and custom roles, e.g. to show only the actions they can take
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/me/permissions [get]
+func (h *RoleHandler) GetMyPermissions(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	role, _ := c.Locals("role").(string)
+	if _, impersonating := c.Locals("impersonation_id").(uuid.UUID); impersonating {
+		userID = uuid.Nil
+	}
+
+	permissions, err := h.roleService.Permissions(c.UserContext(), orgID, userID, domain.UserRole(role))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to resolve permissions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"role":        role,
+		"permissions": permissions.List(),
+	})
+}
+
+// ListRoles lists the organization's custom roles
+// @Summary List custom roles
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/roles [get]
+func (h *RoleHandler) ListRoles(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	roles, err := h.roleService.ListRoles(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list roles",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"roles": roles,
+		"total": len(roles),
+	})
+}
+
+// CreateRole defines a custom role
+// @Summary Create custom role
+// @Description Define a role as a set of permissions from the catalog. Users keep their built-in role and gain the permissions of the custom roles assigned to them.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body application.CustomRoleRequest true "Custom role"
+// @Success 201 {object} domain.CustomRole
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/roles [post]
+func (h *RoleHandler) CreateRole(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.CustomRoleRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	role, err := h.roleService.CreateRole(c.UserContext(), orgID, userID, &req)
+	if err != nil {
+		return roleError(c, err, "Failed to create role")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"custom_role",
+		role.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":        role.Name,
+			"permissions": role.Permissions,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(role)
+}
+
+// GetRole returns a custom role
+// @Summary Get custom role
+// @Tags admin
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} domain.CustomRole
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/roles/{id} [get]
+func (h *RoleHandler) GetRole(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	role, err := h.roleService.GetRole(c.UserContext(), orgID, id)
+	if err != nil {
+		return roleError(c, err, "Failed to fetch role")
+	}
+	return c.JSON(role)
+}
+
+// UpdateRole replaces a custom role's name, description and permissions
+// @Summary Update custom role
+// @Description Users the role is assigned to have the new permissions from their next request
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param request body application.CustomRoleRequest true "Custom role"
+// @Success 200 {object} domain.CustomRole
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/roles/{id} [put]
+func (h *RoleHandler) UpdateRole(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	var req application.CustomRoleRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	role, err := h.roleService.UpdateRole(c.UserContext(), orgID, id, &req)
+	if err != nil {
+		return roleError(c, err, "Failed to update role")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"custom_role",
+		role.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":        role.Name,
+			"permissions": role.Permissions,
+		},
+	)
+
+	return c.JSON(role)
+}
+
+// DeleteRole deletes a custom role
+// @Summary Delete custom role
+// @Description The users the role was assigned to lose its permissions
+// @Tags admin
+// @Param id path string true "Role ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/roles/{id} [delete]
+func (h *RoleHandler) DeleteRole(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	if err := h.roleService.DeleteRole(c.UserContext(), orgID, id); err != nil {
+		return roleError(c, err, "Failed to delete role")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"custom_role",
+		id,
+		c.IP(),
+		c.Get("User-Agent"),
+		nil,
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListRoleUsers lists the users a custom role is assigned to
+// @Summary List custom role users
+// @Tags admin
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/roles/{id}/users [get]
+func (h *RoleHandler) ListRoleUsers(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	assignments, err := h.roleService.ListRoleUsers(c.UserContext(), orgID, id)
+	if err != nil {
+		return roleError(c, err, "Failed to list role users")
+	}
+
+	return c.JSON(fiber.Map{
+		"users": assignments,
+		"total": len(assignments),
+	})
+}
+
+// AssignRole assigns a custom role to a user
+// @Summary Assign custom role
+// @Description The user keeps their built-in role and gains the role's permissions
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param request body object true "User to assign the role to (userId)"
+// @Success 200 {object} domain.CustomRoleAssignment
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/roles/{id}/users [post]
+func (h *RoleHandler) AssignRole(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	var req struct {
+		UserID uuid.UUID `json:"userId"`
+	}
+	if err := c.Bind().JSON(&req); err != nil || req.UserID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "userId is required",
+		})
+	}
+
+	assignment, err := h.roleService.AssignRole(c.UserContext(), orgID, id, req.UserID, userID)
+	if err != nil {
+		return roleError(c, err, "Failed to assign role")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"user",
+		req.UserID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"custom_role_assigned": id,
+		},
+	)
+
+	return c.JSON(assignment)
+}
+
+// UnassignRole removes a custom role from a user
+// @Summary Unassign custom role
+// @Tags admin
+// @Param id path string true "Role ID"
+// @Param userId path string true "User ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/roles/{id}/users/{userId} [delete]
+func (h *RoleHandler) UnassignRole(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+	targetID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	if err := h.roleService.UnassignRole(c.UserContext(), orgID, id, targetID); err != nil {
+		return roleError(c, err, "Failed to unassign role")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"user",
+		targetID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"custom_role_unassigned": id,
+		},
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListUserRoles lists the custom roles assigned to a user
+// @Summary List a user's custom roles
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/roles [get]
+func (h *RoleHandler) ListUserRoles(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	roles, err := h.roleService.ListUserRoles(c.UserContext(), orgID, userID)
+	if err != nil {
+		return roleError(c, err, "Failed to list user roles")
+	}
+
+	return c.JSON(fiber.Map{
+		"roles": roles,
+		"total": len(roles),
+	})
+}
+
+func roleError(c fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrCustomRoleNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Role not found",
+		})
+	case errors.Is(err, application.ErrRoleUserNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	case errors.Is(err, application.ErrInvalidCustomRole):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrCustomRoleNameTaken):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/application"
+)
+
+// RuntimeConfigHandler serves the runtime settings of the platform operator API
+type RuntimeConfigHandler struct {
+	runtimeConfig *application.RuntimeConfigService
+}
+
+func NewRuntimeConfigHandler(runtimeConfig *application.RuntimeConfigService) *RuntimeConfigHandler {
+	return &RuntimeConfigHandler{
+		runtimeConfig: runtimeConfig,
+	}
+}
+
+// ListSettings returns every runtime setting
+// @Summary List runtime settings
+// @Description Operational knobs (rate limits, job intervals, cache TTLs, feature toggles, log level) with the value in effect on the instance answering the request, the value configured at startup and whether an operator overrode it.
+// @Tags operator
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /operator/v1/settings [get]
+func (h *RuntimeConfigHandler) ListSettings(c fiber.Ctx) error {
+	settings, err := h.runtimeConfig.List(c.UserContext())
+	if err != nil {
+		return runtimeConfigError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"settings": settings,
+		"total":    len(settings),
+	})
+}
+
+// SetSetting changes a runtime setting
+// @Summary Change runtime setting
+// @Description Applies right away on the instance answering the request and on the others within the refresh interval, without a restart. The change is kept in the setting's history and the operator log.
+// @Tags operator
+// @Accept json
+// @Produce json
+// @Param key path string true "Setting key"
+// @Param request body application.RuntimeSettingRequest true "Value and reason"
+// @Success 200 {object} application.RuntimeSettingState
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /operator/v1/settings/{key} [put]
+func (h *RuntimeConfigHandler) SetSetting(c fiber.Ctx) error {
+	var req application.RuntimeSettingRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	setting, err := h.runtimeConfig.Set(c.UserContext(), operatorOf(c), c.IP(), c.Params("key"), &req)
+	if err != nil {
+		return runtimeConfigError(c, err)
+	}
+	return c.JSON(setting)
+}
+
+// ResetSetting returns a runtime setting to the value configured at startup
+// @Summary Reset runtime setting
+// @Tags operator
+// @Produce json
+// @Param key path string true "Setting key"
+// @Param reason query string true "Reason for the change"
+// @Success 200 {object} application.RuntimeSettingState
+// @Failure 404 {object} map[string]interface{}
+// @Router /operator/v1/settings/{key} [delete]
+func (h *RuntimeConfigHandler) ResetSetting(c fiber.Ctx) error {
+	setting, err := h.runtimeConfig.Reset(c.UserContext(), operatorOf(c), c.IP(), c.Params("key"), c.Query("reason"))
+	if err != nil {
+		return runtimeConfigError(c, err)
+	}
+	return c.JSON(setting)
+}
+
+// ListChanges returns the history of one runtime setting, or of every setting
+// @Summary Runtime setting history
+// @Description Who changed which setting, from and to which value, and why, newest first.
+// @Tags operator
+// @Produce json
+// @Param key path string false "Setting key; every setting when omitted"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /operator/v1/settings/history [get]
+// @Router /operator/v1/settings/{key}/history [get]
+func (h *RuntimeConfigHandler) ListChanges(c fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	changes, err := h.runtimeConfig.ListChanges(c.UserContext(), c.Params("key"), limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch runtime setting history",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"changes": changes,
+		"total":   len(changes),
+	})
+}
+
+// Reload applies the stored runtime settings now on the instance answering the request
+// @Summary Reload runtime settings
+// @Description Instances reload the settings on their own every refresh interval and on SIGHUP; this reloads without waiting.
+// @Tags operator
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /operator/v1/settings/reload [post]
+func (h *RuntimeConfigHandler) Reload(c fiber.Ctx) error {
+	if err := h.runtimeConfig.Reload(c.UserContext()); err != nil {
+		return runtimeConfigError(c, err)
+	}
+
+	settings, err := h.runtimeConfig.List(c.UserContext())
+	if err != nil {
+		return runtimeConfigError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"message":  "Runtime settings reloaded",
+		"settings": settings,
+	})
+}
+
+func runtimeConfigError(c fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, application.ErrRuntimeSettingNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrInvalidRuntimeSetting):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+)
+
+type SAMLHandler struct {
+	samlService  *application.SAMLService
+	jwtService   *auth.JWTService
+	auditService *application.AuditService
+}
+
+func NewSAMLHandler(
+	samlService *application.SAMLService,
+	jwtService *auth.JWTService,
+	auditService *application.AuditService,
+) *SAMLHandler {
+	return &SAMLHandler{
+		samlService:  samlService,
+		jwtService:   jwtService,
+		auditService: auditService,
+	}
+}
+
+func samlFrontendURL() string {
+	frontendURL := os.Getenv("FRONTEND_URL")
+	if frontendURL == "" {
+		frontendURL = "http://localhost:3000"
+	}
+	return strings.TrimRight(frontendURL, "/")
+}
+
+// samlRelayPath only lets relative frontend paths through, so the relay state cannot be used
+// as an open redirect
+func samlRelayPath(relayState string) string {
+	if !strings.HasPrefix(relayState, "/") || strings.HasPrefix(relayState, "//") || strings.Contains(relayState, "\\") {
+		return "/dashboard"
+	}
+	return relayState
+}
+
+// Metadata returns the service provider metadata of the organization
+// @Summary Get SAML service provider metadata
+// @Tags auth
+// @Produce xml
+// @Param orgId path string true "Organization ID"
+// @Success 200 {string} string
+// @Router /api/v1/auth/saml/{orgId}/metadata [get]
+func (h *SAMLHandler) Metadata(c fiber.Ctx) error {
+	orgID, err := uuid.Parse(c.Params("orgId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid organization ID",
+		})
+	}
+
+	metadata, err := h.samlService.Metadata(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Organization not found",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/samlmetadata+xml")
+	return c.Send(metadata)
+}
+
+// Login redirects the browser to the organization's identity provider
+// @Summary Start SAML login
+// @Tags auth
+// @Param orgId path string true "Organization ID"
+// @Param redirect query string false "Frontend path to return to after login"
+// @Success 302
+// @Router /api/v1/auth/saml/{orgId}/login [get]
+func (h *SAMLHandler) Login(c fiber.Ctx) error {
+	orgID, err := uuid.Parse(c.Params("orgId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid organization ID",
+		})
+	}
+
+	redirectURL, err := h.samlService.LoginURL(c.UserContext(), orgID, samlRelayPath(c.Query("redirect")))
+	if err != nil {
+		if errors.Is(err, application.ErrSAMLNotConfigured) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start SAML login",
+		})
+	}
+
+	return c.Redirect().To(redirectURL)
+}
+
+// ACS consumes the SAMLResponse posted by the identity provider and signs the user in
+// @Summary SAML assertion consumer service
+// @Tags auth
+// @Accept x-www-form-urlencoded
+// @Param orgId path string true "Organization ID"
+// @Param SAMLResponse formData string true "Base64-encoded SAML response"
+// @Param RelayState formData string false "Frontend path to return to"
+// @Success 302
+// @Router /api/v1/auth/saml/{orgId}/acs [post]
+func (h *SAMLHandler) ACS(c fiber.Ctx) error {
+	frontendURL := samlFrontendURL()
+	failed := fmt.Sprintf("%s/auth/login?error=saml_failed", frontendURL)
+
+	orgID, err := uuid.Parse(c.Params("orgId"))
+	if err != nil {
+		return c.Redirect().To(failed)
+	}
+
+	user, err := h.samlService.CompleteLogin(c.UserContext(), orgID, c.FormValue("SAMLResponse"))
+	if err != nil {
+		log.Printf("SAML login failed for organization %s: %v", orgID, err)
+		return c.Redirect().To(failed)
+	}
+
+	accessToken, refreshToken, err := h.jwtService.GenerateTokenPair(
+		user.ID.String(),
+		user.OrganizationID.String(),
+		user.Email,
+		string(user.Role),
+	)
+	if err != nil {
+		return c.Redirect().To(failed)
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     "access_token",
+		Value:    accessToken,
+		HTTPOnly: true,
+		Secure:   false, // Set to true in production with HTTPS
+		SameSite: "Lax",
+	})
+
+	c.Cookie(&fiber.Cookie{
+		Name:     "refresh_token",
+		Value:    refreshToken,
+		HTTPOnly: true,
+		Secure:   false,
+		SameSite: "Lax",
+	})
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		user.OrganizationID,
+		user.ID,
+		domain.AuditActionLogin,
+		"user",
+		user.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"provider": domain.UserProviderSAML,
+		},
+	)
+
+	return c.Redirect().To(frontendURL + samlRelayPath(c.FormValue("RelayState")))
+}
+
+// GetConfig returns the organization's SAML configuration
+// @Summary Get SAML configuration
+// @Tags admin
+// @Produce json
+// @Success 200 {object} domain.SAMLConfig
+// @Router /api/v1/admin/saml [get]
+func (h *SAMLHandler) GetConfig(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	config, err := h.samlService.GetConfig(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch SAML configuration",
+		})
+	}
+	if config == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "SAML is not configured",
+		})
+	}
+
+	return c.JSON(config)
+}
+
+// UpdateConfig creates or replaces the organization's SAML configuration
+// @Summary Update SAML configuration
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body application.SAMLConfigRequest true "SAML configuration"
+// @Success 200 {object} domain.SAMLConfig
+// @Router /api/v1/admin/saml [put]
+func (h *SAMLHandler) UpdateConfig(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.SAMLConfigRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	config, err := h.samlService.UpdateConfig(c.UserContext(), orgID, userID, &req)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidSAMLConfig) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update SAML configuration",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"saml_config",
+		config.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"enabled":          config.Enabled,
+			"idp_entity_id":    config.IdPEntityID,
+			"jit_provisioning": config.JITProvisioning,
+		},
+	)
+
+	return c.JSON(config)
+}
+
+// DeleteConfig removes the organization's SAML configuration
+// @Summary Delete SAML configuration
+// @Tags admin
+// @Success 204
+// @Router /api/v1/admin/saml [delete]
+func (h *SAMLHandler) DeleteConfig(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.samlService.DeleteConfig(c.UserContext(), orgID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete SAML configuration",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"saml_config",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		nil,
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type SBOMHandler struct {
+	sbomService  *application.SBOMService
+	agentService *application.AgentService
+	auditService *application.AuditService
+}
+
+func NewSBOMHandler(
+	sbomService *application.SBOMService,
+	agentService *application.AgentService,
+	auditService *application.AuditService,
+) *SBOMHandler {
+	return &SBOMHandler{
+		sbomService:  sbomService,
+		agentService: agentService,
+		auditService: auditService,
+	}
+}
+
+// UploadSBOM stores an SBOM for a version of an agent and scans it for known vulnerabilities
+// @Summary Upload agent SBOM
+// @Description Upload an SPDX or CycloneDX JSON SBOM, or an HTTPS link to one, for an agent version. Components are checked against OSV; critical vulnerabilities raise a security alert and lower the agent's compliance and security alert trust factors.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param request body application.UploadSBOMRequest true "SBOM document or link"
+// @Success 201 {object} domain.AgentSBOM
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/sboms [post]
+func (h *SBOMHandler) UploadSBOM(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID, _ := c.Locals("user_id").(uuid.UUID)
+
+	agent, status, message := h.getOwnedAgent(c)
+	if agent == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	var req application.UploadSBOMRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	var uploadedBy *uuid.UUID
+	if userID != uuid.Nil {
+		uploadedBy = &userID
+	}
+
+	sbom, err := h.sbomService.UploadSBOM(c.UserContext(), agent, &req, uploadedBy)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidSBOM) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to store SBOM",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"agent_sbom",
+		sbom.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"agent_id":       agent.ID.String(),
+			"agent_name":     agent.Name,
+			"agent_version":  sbom.AgentVersion,
+			"format":         sbom.Format,
+			"document_hash":  sbom.DocumentHash,
+			"components":     sbom.ComponentCount,
+			"critical_count": sbom.CriticalCount,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(sbom)
+}
+
+// ListSBOMs lists an agent's SBOMs, newest first
+// @Summary List agent SBOMs
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/sboms [get]
+func (h *SBOMHandler) ListSBOMs(c fiber.Ctx) error {
+	agent, status, message := h.getOwnedAgent(c)
+	if agent == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	sboms, err := h.sbomService.ListSBOMs(c.UserContext(), agent.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch SBOMs",
+		})
+	}
+
+	if sboms == nil {
+		sboms = []*domain.AgentSBOM{}
+	}
+
+	return c.JSON(fiber.Map{
+		"sboms": sboms,
+		"total": len(sboms),
+	})
+}
+
+// GetSBOM retrieves an agent SBOM with its components and vulnerabilities
+// @Summary Get agent SBOM
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param sbom_id path string true "SBOM ID"
+// @Success 200 {object} domain.AgentSBOM
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/sboms/{sbom_id} [get]
+func (h *SBOMHandler) GetSBOM(c fiber.Ctx) error {
+	agent, status, message := h.getOwnedAgent(c)
+	if agent == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	sbomID, err := uuid.Parse(c.Params("sbom_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid SBOM ID",
+		})
+	}
+
+	sbom, err := h.sbomService.GetSBOM(c.UserContext(), agent.ID, sbomID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "SBOM not found",
+		})
+	}
+
+	return c.JSON(sbom)
+}
+
+// RescanSBOM checks an agent SBOM against the vulnerability database again
+// @Summary Rescan agent SBOM
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param sbom_id path string true "SBOM ID"
+// @Success 200 {object} domain.AgentSBOM
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/sboms/{sbom_id}/rescan [post]
+func (h *SBOMHandler) RescanSBOM(c fiber.Ctx) error {
+	agent, status, message := h.getOwnedAgent(c)
+	if agent == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	sbomID, err := uuid.Parse(c.Params("sbom_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid SBOM ID",
+		})
+	}
+
+	sbom, err := h.sbomService.RescanSBOM(c.UserContext(), agent, sbomID)
+	if err != nil {
+		if errors.Is(err, application.ErrSBOMNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "SBOM not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to rescan SBOM",
+		})
+	}
+
+	return c.JSON(sbom)
+}
+
+func (h *SBOMHandler) getOwnedAgent(c fiber.Ctx) (*domain.Agent, int, string) {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, fiber.StatusBadRequest, "Invalid agent ID"
+	}
+
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
+	if err != nil {
+		return nil, fiber.StatusNotFound, "Agent not found"
+	}
+	if agent.OrganizationID != orgID {
+		return nil, fiber.StatusForbidden, "Access denied"
+	}
+
+	return agent, fiber.StatusOK, ""
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/application"
+)
+
+type SchemaHandler struct {
+	schemaService *application.SchemaService
+}
+
+func NewSchemaHandler(schemaService *application.SchemaService) *SchemaHandler {
+	return &SchemaHandler{
+		schemaService: schemaService,
+	}
+}
+
+// GetSchemaStatus reports the database schema version
+// @Summary Get database schema status
+// @Description Report the current schema version, the migrations this build ships that are not applied yet, and the history of applied migrations with their checksums and durations.
+// @Description Applied migrations whose file changed since are flagged as modified.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} domain.SchemaStatus
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/schema [get]
+func (h *SchemaHandler) GetSchemaStatus(c fiber.Ctx) error {
+	status, err := h.schemaService.GetStatus(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get schema status",
+		})
+	}
+
+	return c.JSON(status)
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/application"
@@ -47,7 +49,7 @@ func (h *SDKTokenHandler) ListUserTokens(c fiber.Ctx) error {
 
 	includeRevoked := c.Query("include_revoked", "false") == "true"
 
-	tokens, err := h.sdkTokenService.GetUserTokens(c.Context(), userID, includeRevoked)
+	tokens, err := h.sdkTokenService.GetUserTokens(c.UserContext(), userID, includeRevoked)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to list SDK tokens",
@@ -77,7 +79,7 @@ func (h *SDKTokenHandler) GetActiveTokenCount(c fiber.Ctx) error {
 		})
 	}
 
-	count, err := h.sdkTokenService.GetActiveTokenCount(c.Context(), userID)
+	count, err := h.sdkTokenService.GetActiveTokenCount(c.UserContext(), userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get token count",
@@ -130,7 +132,7 @@ func (h *SDKTokenHandler) RevokeToken(c fiber.Ctx) error {
 		req.Reason = "User-initiated revocation"
 	}
 
-	err = h.sdkTokenService.RevokeToken(c.Context(), tokenID, userID, req.Reason)
+	err = h.sdkTokenService.RevokeToken(c.UserContext(), tokenID, userID, req.Reason)
 	if err != nil {
 		if err.Error() == "unauthorized: token belongs to different user" {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
@@ -177,7 +179,7 @@ func (h *SDKTokenHandler) RevokeAllTokens(c fiber.Ctx) error {
 		req.Reason = "User revoked all SDK tokens"
 	}
 
-	err := h.sdkTokenService.RevokeAllUserTokens(c.Context(), userID, req.Reason)
+	err := h.sdkTokenService.RevokeAllUserTokens(c.UserContext(), userID, req.Reason)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to revoke tokens",
@@ -189,6 +191,83 @@ func (h *SDKTokenHandler) RevokeAllTokens(c fiber.Ctx) error {
 	})
 }
 
+// ListDevices godoc
+// @Summary List SDK devices
+// @Description Get the devices holding active SDK tokens of the authenticated user, with where each was last used from
+// @Tags sdk-tokens
+// @Produce json
+// @Success 200 {array} domain.SDKDevice
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/users/me/sdk-devices [get]
+// @Security BearerAuth
+func (h *SDKTokenHandler) ListDevices(c fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	devices, err := h.sdkTokenService.ListUserDevices(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list SDK devices",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"devices": devices,
+		"total":   len(devices),
+	})
+}
+
+// RevokeDevice godoc
+// @Summary Revoke an SDK device
+// @Description Revoke every active SDK token of the authenticated user on a device
+// @Tags sdk-tokens
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param body body RevokeTokenRequest false "Revocation reason"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/users/me/sdk-devices/{id}/revoke [post]
+// @Security BearerAuth
+func (h *SDKTokenHandler) RevokeDevice(c fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	// Parse optional reason
+	var req RevokeTokenRequest
+	if err := c.Bind().Body(&req); err != nil || req.Reason == "" {
+		req.Reason = "User revoked SDK device"
+	}
+
+	revoked, err := h.sdkTokenService.RevokeDevice(c.UserContext(), userID, c.Params("id"), req.Reason)
+	if err != nil {
+		if errors.Is(err, application.ErrSDKDeviceNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Device not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke device",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":       "Device revoked successfully",
+		"revokedTokens": revoked,
+	})
+}
+
 // Request types
 type RevokeTokenRequest struct {
 	Reason string `json:"reason,omitempty"`
@@ -64,7 +64,7 @@ func (h *SDKTokenRecoveryHandler) RecoverRevokedToken(c fiber.Ctx) error {
 	oldTokenHash := hex.EncodeToString(hasher.Sum(nil))
 
 	// Get old token info from database (even if revoked)
-	oldToken, err := h.sdkTokenService.GetByTokenHash(c.Context(), oldTokenHash)
+	oldToken, err := h.sdkTokenService.GetByTokenHash(c.UserContext(), oldTokenHash)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Token not found - it may have been deleted",
@@ -129,7 +129,7 @@ func (h *SDKTokenRecoveryHandler) RecoverRevokedToken(c fiber.Ctx) error {
 	}
 
 	// Save new token to database
-	if err := h.sdkTokenService.CreateToken(c.Context(), newSDKToken); err != nil {
+	if err := h.sdkTokenService.CreateToken(c.UserContext(), newSDKToken); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to save new token",
 		})
@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
@@ -30,23 +35,160 @@ func NewSecurityHandler(
 	}
 }
 
-// GetThreats retrieves detected security threats
+// maxSecuritySearchLimit caps the page size of threat and anomaly searches
+const maxSecuritySearchLimit = 200
+
+// AnnotateThreatRequest is the body for adding a triage note to a threat
+type AnnotateThreatRequest struct {
+	Note string `json:"note"`
+}
+
+// securitySearchQuery holds the query parameters shared by threat and anomaly searches
+type securitySearchQuery struct {
+	severities []domain.AlertSeverity
+	from       *time.Time
+	to         *time.Time
+	search     string
+	sortBy     string
+	sortOrder  string
+	limit      int
+	offset     int
+}
+
+// parseSecuritySearchQuery reads the shared search parameters; sortFields lists the accepted sort values
+func parseSecuritySearchQuery(c fiber.Ctx, sortFields ...string) (*securitySearchQuery, error) {
+	query := &securitySearchQuery{
+		search:    strings.TrimSpace(c.Query("search")),
+		sortBy:    strings.ToLower(c.Query("sort", "created_at")),
+		sortOrder: strings.ToLower(c.Query("order", "desc")),
+	}
+
+	query.limit, _ = strconv.Atoi(c.Query("limit", "50"))
+	if query.limit <= 0 {
+		query.limit = 50
+	}
+	if query.limit > maxSecuritySearchLimit {
+		query.limit = maxSecuritySearchLimit
+	}
+	query.offset, _ = strconv.Atoi(c.Query("offset", "0"))
+	if query.offset < 0 {
+		query.offset = 0
+	}
+
+	for _, severity := range splitQueryList(c.Query("severity")) {
+		query.severities = append(query.severities, domain.AlertSeverity(severity))
+	}
+
+	if !slices.Contains(sortFields, query.sortBy) {
+		return nil, fmt.Errorf("sort must be one of: %s", strings.Join(sortFields, ", "))
+	}
+	if query.sortOrder != "asc" && query.sortOrder != "desc" {
+		return nil, fmt.Errorf("order must be asc or desc")
+	}
+
+	var err error
+	if query.from, err = parseQueryTime(c, "from"); err != nil {
+		return nil, err
+	}
+	if query.to, err = parseQueryTime(c, "to"); err != nil {
+		return nil, err
+	}
+	if query.from != nil && query.to != nil && query.to.Before(*query.from) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+
+	return query, nil
+}
+
+// splitQueryList splits a comma-separated query value into lowercase, non-empty items
+func splitQueryList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseQueryTime parses an optional RFC 3339 timestamp query parameter
+func parseQueryTime(c fiber.Ctx, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", key)
+	}
+	return &parsed, nil
+}
+
+// GetThreats searches detected security threats
 // @Summary List security threats
-// @Description Get all detected security threats for the organization
+// @Description Search the organization's security threats with filtering, sorting and pagination. Acknowledged threats are blocked.
 // @Tags security
 // @Produce json
-// @Param limit query int false "Limit" default(50)
+// @Param severity query string false "Comma-separated severities (info, warning, high, critical)"
+// @Param type query string false "Comma-separated threat types"
+// @Param blocked query bool false "Only blocked (true) or active (false) threats"
+// @Param target_type query string false "Target resource type, e.g. agent or mcp_server"
+// @Param target_id query string false "Target resource ID"
+// @Param from query string false "Created at or after (RFC 3339)"
+// @Param to query string false "Created at or before (RFC 3339)"
+// @Param search query string false "Case-insensitive search in title and description"
+// @Param sort query string false "created_at, severity or title" default(created_at)
+// @Param order query string false "asc or desc" default(desc)
+// @Param limit query int false "Limit (max 200)" default(50)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/security/threats [get]
 func (h *SecurityHandler) GetThreats(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 
-	limit, _ := strconv.Atoi(c.Query("limit", "50"))
-	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	query, err := parseSecuritySearchQuery(c, "created_at", "severity", "title")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	params := domain.ThreatQueryParams{
+		Severities: query.severities,
+		TargetType: c.Query("target_type"),
+		From:       query.from,
+		To:         query.to,
+		Search:     query.search,
+		SortBy:     query.sortBy,
+		SortOrder:  query.sortOrder,
+		Limit:      query.limit,
+		Offset:     query.offset,
+	}
+	for _, threatType := range splitQueryList(c.Query("type")) {
+		params.ThreatTypes = append(params.ThreatTypes, domain.ThreatType(threatType))
+	}
+	if value := c.Query("blocked"); value != "" {
+		blocked, err := strconv.ParseBool(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "blocked must be true or false",
+			})
+		}
+		params.Blocked = &blocked
+	}
+	if value := c.Query("target_id"); value != "" {
+		targetID, err := uuid.Parse(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid target_id",
+			})
+		}
+		params.TargetID = &targetID
+	}
 
-	threats, err := h.securityService.GetThreats(c.Context(), orgID, limit, offset)
+	threats, total, err := h.securityService.SearchThreats(c.UserContext(), orgID, params)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch security threats",
@@ -55,29 +197,230 @@ func (h *SecurityHandler) GetThreats(c fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{
 		"threats": threats,
-		"total":   len(threats),
-		"limit":   limit,
-		"offset":  offset,
+		"total":   total,
+		"limit":   query.limit,
+		"offset":  query.offset,
 	})
 }
 
-// GetAnomalies retrieves detected anomalies
+// AcknowledgeThreat acknowledges a threat, marking it as blocked
+// @Summary Acknowledge security threat
+// @Tags security
+// @Produce json
+// @Param id path string true "Threat ID"
+// @Success 200 {object} domain.Threat
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/security/threats/{id}/acknowledge [post]
+func (h *SecurityHandler) AcknowledgeThreat(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	threatID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid threat ID",
+		})
+	}
+
+	threat, err := h.securityService.AcknowledgeThreat(c.UserContext(), orgID, threatID, userID)
+	if err != nil {
+		if errors.Is(err, application.ErrThreatNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Threat not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to acknowledge threat",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionAcknowledge,
+		"threat",
+		threat.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"threat_type": threat.ThreatType,
+			"severity":    threat.Severity,
+		},
+	)
+
+	return c.JSON(threat)
+}
+
+// ListThreatAnnotations lists a threat's triage notes
+// @Summary List threat annotations
+// @Tags security
+// @Produce json
+// @Param id path string true "Threat ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/security/threats/{id}/annotations [get]
+func (h *SecurityHandler) ListThreatAnnotations(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	threatID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid threat ID",
+		})
+	}
+
+	annotations, err := h.securityService.GetThreatAnnotations(c.UserContext(), orgID, threatID)
+	if err != nil {
+		if errors.Is(err, application.ErrThreatNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Threat not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch threat annotations",
+		})
+	}
+
+	if annotations == nil {
+		annotations = []*domain.ThreatAnnotation{}
+	}
+
+	return c.JSON(fiber.Map{
+		"annotations": annotations,
+		"total":       len(annotations),
+	})
+}
+
+// AnnotateThreat adds a triage note to a threat
+// @Summary Annotate security threat
+// @Tags security
+// @Accept json
+// @Produce json
+// @Param id path string true "Threat ID"
+// @Param request body AnnotateThreatRequest true "Annotation"
+// @Success 201 {object} domain.ThreatAnnotation
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/security/threats/{id}/annotations [post]
+func (h *SecurityHandler) AnnotateThreat(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	threatID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid threat ID",
+		})
+	}
+
+	var req AnnotateThreatRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	annotation, err := h.securityService.AnnotateThreat(c.UserContext(), orgID, threatID, userID, req.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrInvalidThreatAnnotation):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, application.ErrThreatNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Threat not found",
+			})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to annotate threat",
+			})
+		}
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"threat_annotation",
+		annotation.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"threat_id": threatID.String(),
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(annotation)
+}
+
+// GetAnomalies searches detected anomalies
 // @Summary List anomalies
-// @Description Get all detected anomalies for the organization
+// @Description Search the organization's anomalies with filtering, sorting and pagination
 // @Tags security
 // @Produce json
-// @Param limit query int false "Limit" default(50)
+// @Param severity query string false "Comma-separated severities (low, medium, high, critical)"
+// @Param type query string false "Comma-separated anomaly types"
+// @Param resource_type query string false "Resource type, e.g. agent"
+// @Param resource_id query string false "Resource ID"
+// @Param min_confidence query number false "Minimum confidence (0-100)"
+// @Param from query string false "Created at or after (RFC 3339)"
+// @Param to query string false "Created at or before (RFC 3339)"
+// @Param search query string false "Case-insensitive search in title and description"
+// @Param sort query string false "created_at, severity, confidence or title" default(created_at)
+// @Param order query string false "asc or desc" default(desc)
+// @Param limit query int false "Limit (max 200)" default(50)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/security/anomalies [get]
 func (h *SecurityHandler) GetAnomalies(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 
-	limit, _ := strconv.Atoi(c.Query("limit", "50"))
-	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	query, err := parseSecuritySearchQuery(c, "created_at", "severity", "confidence", "title")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	params := domain.AnomalyQueryParams{
+		Severities:   query.severities,
+		ResourceType: c.Query("resource_type"),
+		From:         query.from,
+		To:           query.to,
+		Search:       query.search,
+		SortBy:       query.sortBy,
+		SortOrder:    query.sortOrder,
+		Limit:        query.limit,
+		Offset:       query.offset,
+	}
+	for _, anomalyType := range splitQueryList(c.Query("type")) {
+		params.AnomalyTypes = append(params.AnomalyTypes, domain.AnomalyType(anomalyType))
+	}
+	if value := c.Query("resource_id"); value != "" {
+		resourceID, err := uuid.Parse(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid resource_id",
+			})
+		}
+		params.ResourceID = &resourceID
+	}
+	if value := c.Query("min_confidence"); value != "" {
+		minConfidence, err := strconv.ParseFloat(value, 64)
+		if err != nil || minConfidence < 0 || minConfidence > 100 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "min_confidence must be a number between 0 and 100",
+			})
+		}
+		params.MinConfidence = minConfidence
+	}
 
-	anomalies, err := h.securityService.GetAnomalies(c.Context(), orgID, limit, offset)
+	anomalies, total, err := h.securityService.SearchAnomalies(c.UserContext(), orgID, params)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch anomalies",
@@ -86,9 +429,9 @@ func (h *SecurityHandler) GetAnomalies(c fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{
 		"anomalies": anomalies,
-		"total":     len(anomalies),
-		"limit":     limit,
-		"offset":    offset,
+		"total":     total,
+		"limit":     query.limit,
+		"offset":    query.offset,
 	})
 }
 
@@ -103,7 +446,7 @@ func (h *SecurityHandler) GetAnomalies(c fiber.Ctx) error {
 func (h *SecurityHandler) GetSecurityMetrics(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 
-	metrics, err := h.securityService.GetSecurityMetrics(c.Context(), orgID)
+	metrics, err := h.securityService.GetSecurityMetrics(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch security metrics",
@@ -125,7 +468,7 @@ func (h *SecurityHandler) GetSecurityDashboard(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 
 	// Get security metrics
-	metrics, err := h.securityService.GetSecurityMetrics(c.Context(), orgID)
+	metrics, err := h.securityService.GetSecurityMetrics(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch security metrics",
@@ -133,31 +476,31 @@ func (h *SecurityHandler) GetSecurityDashboard(c fiber.Ctx) error {
 	}
 
 	// Get recent threats (limit 10)
-	threats, err := h.securityService.GetThreats(c.Context(), orgID, 10, 0)
+	threats, err := h.securityService.GetThreats(c.UserContext(), orgID, 10, 0)
 	if err != nil || threats == nil {
 		threats = make([]*domain.Threat, 0)
 	}
 
 	// Get recent anomalies (limit 10)
-	anomalies, err := h.securityService.GetAnomalies(c.Context(), orgID, 10, 0)
+	anomalies, err := h.securityService.GetAnomalies(c.UserContext(), orgID, 10, 0)
 	if err != nil || anomalies == nil {
 		anomalies = make([]*domain.Anomaly, 0)
 	}
 
 	// Get unacknowledged alerts count
-	_, _, unacknowledgedAlerts, err := h.alertService.CountUnacknowledged(c.Context(), orgID)
+	_, _, unacknowledgedAlerts, err := h.alertService.CountUnacknowledged(c.UserContext(), orgID)
 	if err != nil {
 		unacknowledgedAlerts = 0
 	}
 
 	// Get recent alerts (limit 5)
-	recentAlerts, _, err := h.alertService.GetAlerts(c.Context(), orgID, "", "", 5, 0)
+	recentAlerts, _, err := h.alertService.GetAlerts(c.UserContext(), orgID, "", "", 5, 0)
 	if err != nil || recentAlerts == nil {
 		recentAlerts = make([]*domain.Alert, 0)
 	}
 
 	// Get agent security status
-	agents, err := h.agentService.ListAgents(c.Context(), orgID)
+	agents, err := h.agentService.ListAgents(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch agents",
@@ -225,7 +568,7 @@ func (h *SecurityHandler) ListSecurityAlerts(c fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "20"))
 	offset, _ := strconv.Atoi(c.Query("offset", "0"))
 
-	alerts, total, err := h.alertService.GetAlerts(c.Context(), orgID, "", "", limit, offset)
+	alerts, total, err := h.alertService.GetAlerts(c.UserContext(), orgID, "", "", limit, offset)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch security alerts",
@@ -233,7 +576,7 @@ func (h *SecurityHandler) ListSecurityAlerts(c fiber.Ctx) error {
 	}
 
 	// Get alert counts (all, acknowledged, unacknowledged)
-	allCount, acknowledgedCount, unacknowledgedCount, err := h.alertService.CountUnacknowledged(c.Context(), orgID)
+	allCount, acknowledgedCount, unacknowledgedCount, err := h.alertService.CountUnacknowledged(c.UserContext(), orgID)
 	if err != nil {
 		// If count fails, set defaults but don't fail the request
 		allCount = total
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/gofiber/fiber/v3"
@@ -39,7 +40,7 @@ func (h *SecurityPolicyHandler) ListPolicies(c fiber.Ctx) error {
 	// 🔍 DEBUG: Log the organization ID being queried
 	fmt.Printf("🔍 DEBUG ListPolicies: Querying policies for org_id=%s\n", orgID)
 
-	policies, err := h.policyService.ListPolicies(c.Context(), orgID)
+	policies, err := h.policyService.ListPolicies(c.UserContext(), orgID)
 	if err != nil {
 		fmt.Printf("❌ DEBUG ListPolicies: Error fetching policies: %v\n", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -60,7 +61,7 @@ func (h *SecurityPolicyHandler) GetPolicy(c fiber.Ctx) error {
 		})
 	}
 
-	policy, err := h.policyService.GetPolicy(c.Context(), policyID)
+	policy, err := h.policyService.GetPolicy(c.UserContext(), policyID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Policy not found",
@@ -109,7 +110,12 @@ func (h *SecurityPolicyHandler) CreatePolicy(c fiber.Ctx) error {
 		CreatedBy:         userID,
 	}
 
-	if err := h.policyService.CreatePolicy(c.Context(), policy); err != nil {
+	if err := h.policyService.CreatePolicy(c.UserContext(), policy); err != nil {
+		if errors.Is(err, application.ErrInvalidPolicyExpression) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create policy",
 		})
@@ -134,7 +140,7 @@ func (h *SecurityPolicyHandler) UpdatePolicy(c fiber.Ctx) error {
 		})
 	}
 
-	policy, err := h.policyService.GetPolicy(c.Context(), policyID)
+	policy, err := h.policyService.GetPolicy(c.UserContext(), policyID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Policy not found",
@@ -152,7 +158,12 @@ func (h *SecurityPolicyHandler) UpdatePolicy(c fiber.Ctx) error {
 	policy.IsEnabled = req.IsEnabled
 	policy.Priority = req.Priority
 
-	if err := h.policyService.UpdatePolicy(c.Context(), policy); err != nil {
+	if err := h.policyService.UpdatePolicy(c.UserContext(), policy); err != nil {
+		if errors.Is(err, application.ErrInvalidPolicyExpression) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update policy",
 		})
@@ -170,7 +181,7 @@ func (h *SecurityPolicyHandler) DeletePolicy(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.policyService.DeletePolicy(c.Context(), policyID); err != nil {
+	if err := h.policyService.DeletePolicy(c.UserContext(), policyID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete policy",
 		})
@@ -184,7 +195,8 @@ type TogglePolicyRequest struct {
 	IsEnabled bool `json:"isEnabled"`
 }
 
-// TogglePolicy enables or disables a security policy (admin only)
+// TogglePolicy enables or disables a security policy (admin only).
+// Disabling answers 202 while an approval chain still needs other approvers.
 func (h *SecurityPolicyHandler) TogglePolicy(c fiber.Ctx) error {
 	policyID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
@@ -201,19 +213,32 @@ func (h *SecurityPolicyHandler) TogglePolicy(c fiber.Ctx) error {
 	}
 
 	if req.IsEnabled {
-		if err := h.policyService.EnablePolicy(c.Context(), policyID); err != nil {
+		if err := h.policyService.EnablePolicy(c.UserContext(), policyID); err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to enable policy",
 			})
 		}
 	} else {
-		if err := h.policyService.DisablePolicy(c.Context(), policyID); err != nil {
+		userID := c.Locals("user_id").(uuid.UUID)
+		approval, err := h.policyService.DisablePolicy(c.UserContext(), policyID, userID)
+		if err != nil {
+			if status := approvalErrorStatus(err); status != 0 {
+				return c.Status(status).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to disable policy",
 			})
 		}
+		if approvalPending(approval) {
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+				"message":         "disable recorded; further approvals are required",
+				"approvalRequest": approval,
+			})
+		}
 	}
 
-	policy, _ := h.policyService.GetPolicy(c.Context(), policyID)
+	policy, _ := h.policyService.GetPolicy(c.UserContext(), policyID)
 	return c.JSON(policy)
 }
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type SharedKeyHandler struct {
+	sharedKeyService *application.SharedKeyService
+	auditService     *application.AuditService
+}
+
+func NewSharedKeyHandler(
+	sharedKeyService *application.SharedKeyService,
+	auditService *application.AuditService,
+) *SharedKeyHandler {
+	return &SharedKeyHandler{
+		sharedKeyService: sharedKeyService,
+		auditService:     auditService,
+	}
+}
+
+// ListSharedKeys lists the public keys the organization's agents share with other agents
+// @Summary List shared public keys
+// @Description Public keys registered to more than one agent. Agents of other organizations holding the same key are only counted.
+// @Tags security
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/security/shared-keys [get]
+func (h *SharedKeyHandler) ListSharedKeys(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	sharedKeys, err := h.sharedKeyService.ListSharedKeys(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch shared keys",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"sharedKeys": sharedKeys,
+		"total":      len(sharedKeys),
+	})
+}
+
+// ListAllowlist lists the public keys the organization shares between agents on purpose
+// @Summary List shared key allowlist
+// @Tags security
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/security/shared-keys/allowlist [get]
+func (h *SharedKeyHandler) ListAllowlist(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	entries, err := h.sharedKeyService.ListAllowlist(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch shared key allowlist",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"entries": entries,
+		"total":   len(entries),
+	})
+}
+
+// AddAllowlistEntry allowlists a public key so several of the organization's agents may hold it
+// @Summary Allowlist a shared public key
+// @Description Give the base64 public key or its fingerprint (hex SHA-256 of the base64 key), and the reason it is shared.
+// @Tags security
+// @Accept json
+// @Produce json
+// @Param request body application.AddSharedKeyAllowlistEntryRequest true "Key and reason"
+// @Success 201 {object} domain.SharedKeyAllowlistEntry
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/security/shared-keys/allowlist [post]
+func (h *SharedKeyHandler) AddAllowlistEntry(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.AddSharedKeyAllowlistEntryRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	entry, err := h.sharedKeyService.AddAllowlistEntry(c.UserContext(), orgID, userID, &req)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidSharedKeyAllowlistEntry) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to allowlist shared key",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"shared_key_allowlist",
+		entry.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"fingerprint": entry.Fingerprint,
+			"reason":      entry.Reason,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(entry)
+}
+
+// DeleteAllowlistEntry removes a public key from the allowlist
+// @Summary Remove a shared key allowlist entry
+// @Description Agents still sharing the key are flagged again by the next detection run.
+// @Tags security
+// @Param id path string true "Allowlist entry ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/security/shared-keys/allowlist/{id} [delete]
+func (h *SharedKeyHandler) DeleteAllowlistEntry(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid allowlist entry ID",
+		})
+	}
+
+	if err := h.sharedKeyService.DeleteAllowlistEntry(c.UserContext(), orgID, id); err != nil {
+		if errors.Is(err, domain.ErrSharedKeyAllowlistEntryNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Allowlist entry not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to remove allowlist entry",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"shared_key_allowlist",
+		id,
+		c.IP(),
+		c.Get("User-Agent"),
+		nil,
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type SIEMExporterHandler struct {
+	siemService  *application.SIEMExportService
+	auditService *application.AuditService
+}
+
+func NewSIEMExporterHandler(
+	siemService *application.SIEMExportService,
+	auditService *application.AuditService,
+) *SIEMExporterHandler {
+	return &SIEMExporterHandler{
+		siemService:  siemService,
+		auditService: auditService,
+	}
+}
+
+// CreateExporter streams the organization's security events to a SIEM
+// @Summary Create SIEM exporter
+// @Description Streams threats, anomalies, alerts (including drift alerts) and verification failures to a SIEM as RFC 5424 syslog messages carrying CEF or LEEF records. eventTypes limits the categories streamed; minSeverity drops less severe events. Events are buffered while the SIEM is unreachable; when the buffer is full the oldest are dropped and counted in droppedCount.
+// @Tags siem
+// @Accept json
+// @Produce json
+// @Param request body application.SIEMExporterRequest true "Exporter details"
+// @Success 201 {object} domain.SIEMExporter
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/siem/exporters [post]
+func (h *SIEMExporterHandler) CreateExporter(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.SIEMExporterRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	exporter, err := h.siemService.CreateExporter(c.UserContext(), &req, orgID, userID)
+	if err != nil {
+		return siemExporterError(c, err)
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"siem_exporter",
+		exporter.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":        exporter.Name,
+			"host":        exporter.Host,
+			"port":        exporter.Port,
+			"transport":   exporter.Transport,
+			"format":      exporter.Format,
+			"event_types": exporter.EventTypes,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(exporter)
+}
+
+// ListExporters lists the organization's SIEM exporters
+// @Summary List SIEM exporters
+// @Tags siem
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/siem/exporters [get]
+func (h *SIEMExporterHandler) ListExporters(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	exporters, err := h.siemService.ListExporters(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch SIEM exporters",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"exporters": exporters,
+		"total":     len(exporters),
+	})
+}
+
+// GetExporter retrieves a SIEM exporter with its delivery counters
+// @Summary Get SIEM exporter
+// @Tags siem
+// @Produce json
+// @Param id path string true "Exporter ID"
+// @Success 200 {object} domain.SIEMExporter
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/siem/exporters/{id} [get]
+func (h *SIEMExporterHandler) GetExporter(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid exporter ID",
+		})
+	}
+
+	exporter, err := h.siemService.GetExporter(c.UserContext(), orgID, id)
+	if err != nil {
+		return siemExporterError(c, err)
+	}
+
+	return c.JSON(exporter)
+}
+
+// UpdateExporter updates a SIEM exporter
+// @Summary Update SIEM exporter
+// @Description Replaces the exporter's settings. Buffered events are sent with the new settings.
+// @Tags siem
+// @Accept json
+// @Produce json
+// @Param id path string true "Exporter ID"
+// @Param request body application.SIEMExporterRequest true "Exporter details"
+// @Success 200 {object} domain.SIEMExporter
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/siem/exporters/{id} [put]
+func (h *SIEMExporterHandler) UpdateExporter(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid exporter ID",
+		})
+	}
+
+	var req application.SIEMExporterRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	exporter, err := h.siemService.UpdateExporter(c.UserContext(), orgID, id, &req)
+	if err != nil {
+		return siemExporterError(c, err)
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"siem_exporter",
+		exporter.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":      exporter.Name,
+			"host":      exporter.Host,
+			"port":      exporter.Port,
+			"transport": exporter.Transport,
+			"isActive":  exporter.IsActive,
+		},
+	)
+
+	return c.JSON(exporter)
+}
+
+// DeleteExporter deletes a SIEM exporter
+// @Summary Delete SIEM exporter
+// @Description Events still buffered for the exporter are discarded
+// @Tags siem
+// @Param id path string true "Exporter ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/siem/exporters/{id} [delete]
+func (h *SIEMExporterHandler) DeleteExporter(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid exporter ID",
+		})
+	}
+
+	if err := h.siemService.DeleteExporter(c.UserContext(), orgID, id); err != nil {
+		return siemExporterError(c, err)
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"siem_exporter",
+		id,
+		c.IP(),
+		c.Get("User-Agent"),
+		nil,
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// TestExporter sends a test event to a SIEM exporter
+// @Summary Test SIEM exporter
+// @Description Sends one informational event and waits until it is written to the SIEM's connection. UDP deliveries cannot be confirmed by the SIEM.
+// @Tags siem
+// @Produce json
+// @Param id path string true "Exporter ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 502 {object} map[string]interface{}
+// @Router /api/v1/siem/exporters/{id}/test [post]
+func (h *SIEMExporterHandler) TestExporter(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid exporter ID",
+		})
+	}
+
+	if err := h.siemService.TestExporter(c.UserContext(), orgID, id); err != nil {
+		return siemExporterError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"delivered": true,
+	})
+}
+
+func siemExporterError(c fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrSIEMExporterNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrInvalidSIEMExporter):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrSIEMDeliveryFailed):
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+}
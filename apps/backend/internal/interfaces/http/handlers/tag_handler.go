@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v3"
@@ -72,7 +73,7 @@ func (h *TagHandler) CreateTag(c fiber.Ctx) error {
 	}
 
 	// Create tag
-	tag, err := h.tagService.CreateTag(c.Context(), application.CreateTagInput{
+	tag, err := h.tagService.CreateTag(c.UserContext(), application.CreateTagInput{
 		OrganizationID: orgID,
 		Key:            req.Key,
 		Value:          req.Value,
@@ -117,7 +118,7 @@ func (h *TagHandler) GetTags(c fiber.Ctx) error {
 		categoryFilter = &cat
 	}
 
-	tags, err := h.tagService.GetTagsByOrganization(c.Context(), orgID, categoryFilter)
+	tags, err := h.tagService.GetTagsByOrganization(c.UserContext(), orgID, categoryFilter)
 
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
@@ -184,7 +185,7 @@ func (h *TagHandler) UpdateTag(c fiber.Ctx) error {
 	}
 
 	// Update tag
-	tag, err := h.tagService.UpdateTag(c.Context(), tagID, orgID, application.UpdateTagInput{
+	tag, err := h.tagService.UpdateTag(c.UserContext(), tagID, orgID, application.UpdateTagInput{
 		Key:         req.Key,
 		Value:       req.Value,
 		Category:    req.Category,
@@ -227,7 +228,7 @@ func (h *TagHandler) DeleteTag(c fiber.Ctx) error {
 	}
 
 	// Delete tag
-	if err := h.tagService.DeleteTag(c.Context(), tagID); err != nil {
+	if err := h.tagService.DeleteTag(c.UserContext(), tagID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: err.Error(),
 		})
@@ -288,7 +289,10 @@ func (h *TagHandler) AddTagsToAgent(c fiber.Ctx) error {
 	}
 
 	// Add tags to agent
-	if err := h.tagService.AddTagsToAgent(c.Context(), agentID, tagIDs, userID); err != nil {
+	if err := h.tagService.AddTagsToAgent(c.UserContext(), agentID, tagIDs, userID); err != nil {
+		if errors.Is(err, application.ErrNamingPolicyViolation) {
+			return namingPolicyViolationResponse(c, err)
+		}
 		// Check if it's a Community Edition limit error
 		if contains(err.Error(), "Community Edition limited to 3 tags") {
 			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
@@ -333,7 +337,7 @@ func (h *TagHandler) RemoveTagFromAgent(c fiber.Ctx) error {
 	}
 
 	// Remove tag from agent
-	if err := h.tagService.RemoveTagFromAgent(c.Context(), agentID, tagID); err != nil {
+	if err := h.tagService.RemoveTagFromAgent(c.UserContext(), agentID, tagID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: err.Error(),
 		})
@@ -364,7 +368,7 @@ func (h *TagHandler) GetAgentTags(c fiber.Ctx) error {
 	}
 
 	// Get agent tags
-	tags, err := h.tagService.GetAgentTags(c.Context(), agentID)
+	tags, err := h.tagService.GetAgentTags(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: err.Error(),
@@ -396,7 +400,7 @@ func (h *TagHandler) SuggestTagsForAgent(c fiber.Ctx) error {
 	}
 
 	// Get tag suggestions
-	suggestions, err := h.tagService.SuggestTagsForAgent(c.Context(), agentID)
+	suggestions, err := h.tagService.SuggestTagsForAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: err.Error(),
@@ -458,7 +462,10 @@ func (h *TagHandler) AddTagsToMCPServer(c fiber.Ctx) error {
 	}
 
 	// Add tags to MCP server
-	if err := h.tagService.AddTagsToMCPServer(c.Context(), mcpServerID, tagIDs, userID); err != nil {
+	if err := h.tagService.AddTagsToMCPServer(c.UserContext(), mcpServerID, tagIDs, userID); err != nil {
+		if errors.Is(err, application.ErrNamingPolicyViolation) {
+			return namingPolicyViolationResponse(c, err)
+		}
 		// Check if it's a Community Edition limit error
 		if contains(err.Error(), "Community Edition limited to 3 tags") {
 			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
@@ -503,7 +510,7 @@ func (h *TagHandler) RemoveTagFromMCPServer(c fiber.Ctx) error {
 	}
 
 	// Remove tag from MCP server
-	if err := h.tagService.RemoveTagFromMCPServer(c.Context(), mcpServerID, tagID); err != nil {
+	if err := h.tagService.RemoveTagFromMCPServer(c.UserContext(), mcpServerID, tagID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: err.Error(),
 		})
@@ -534,7 +541,7 @@ func (h *TagHandler) GetMCPServerTags(c fiber.Ctx) error {
 	}
 
 	// Get MCP server tags
-	tags, err := h.tagService.GetMCPServerTags(c.Context(), mcpServerID)
+	tags, err := h.tagService.GetMCPServerTags(c.UserContext(), mcpServerID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: err.Error(),
@@ -566,7 +573,7 @@ func (h *TagHandler) SuggestTagsForMCPServer(c fiber.Ctx) error {
 	}
 
 	// Get tag suggestions
-	suggestions, err := h.tagService.SuggestTagsForMCPServer(c.Context(), mcpServerID)
+	suggestions, err := h.tagService.SuggestTagsForMCPServer(c.UserContext(), mcpServerID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: err.Error(),
@@ -608,7 +615,7 @@ func (h *TagHandler) GetPopularTags(c fiber.Ctx) error {
 		}
 	}
 
-	tags, err := h.tagService.GetPopularTags(c.Context(), orgID, limit)
+	tags, err := h.tagService.GetPopularTags(c.UserContext(), orgID, limit)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: err.Error(),
@@ -649,7 +656,7 @@ func (h *TagHandler) SearchTags(c fiber.Ctx) error {
 	// Optional category filter
 	categoryFilter := c.Query("category", "")
 
-	tags, err := h.tagService.SearchTags(c.Context(), orgID, query, categoryFilter)
+	tags, err := h.tagService.SearchTags(c.UserContext(), orgID, query, categoryFilter)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: err.Error(),
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+)
+
+type TalksToRecommendationHandler struct {
+	recommendationService *application.TalksToRecommendationService
+}
+
+func NewTalksToRecommendationHandler(recommendationService *application.TalksToRecommendationService) *TalksToRecommendationHandler {
+	return &TalksToRecommendationHandler{
+		recommendationService: recommendationService,
+	}
+}
+
+// GetAgentSuggestions suggests changes to an agent's talks_to list from its actual MCP server usage
+// @Summary Suggest talks_to changes for an agent
+// @Description Entries the agent has not contacted in the period are suggested for removal; servers it contacts at least min_contacts times without listing them are suggested for addition. Apply them with PUT /agents/{id}/mcp-servers and DELETE /agents/{id}/mcp-servers/{mcp_id}.
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param days query int false "Period of usage to consider (1-365)" default(30)
+// @Param min_contacts query int false "Contacts needed to suggest adding a server" default(10)
+// @Success 200 {object} application.TalksToRecommendations
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/mcp-servers/suggestions [get]
+func (h *TalksToRecommendationHandler) GetAgentSuggestions(c fiber.Ctx) error {
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	req, err := talksToRecommendationRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	req.AgentID = agentID
+
+	recommendations, err := h.recommendationService.Recommend(c.UserContext(), req)
+	if err != nil {
+		return h.recommendationError(c, err)
+	}
+
+	return c.JSON(recommendations)
+}
+
+// ListSuggestions lists the talks_to suggestions of every agent in the organization that has any
+// @Summary Suggest talks_to changes across agents
+// @Tags agents
+// @Produce json
+// @Param days query int false "Period of usage to consider (1-365)" default(30)
+// @Param min_contacts query int false "Contacts needed to suggest adding a server" default(10)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/agents/mcp-servers/suggestions [get]
+func (h *TalksToRecommendationHandler) ListSuggestions(c fiber.Ctx) error {
+	req, err := talksToRecommendationRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	recommendations, err := h.recommendationService.RecommendForOrganization(c.UserContext(), req)
+	if err != nil {
+		return h.recommendationError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"agents": recommendations,
+		"total":  len(recommendations),
+	})
+}
+
+func talksToRecommendationRequest(c fiber.Ctx) (*application.TalksToRecommendationRequest, error) {
+	req := &application.TalksToRecommendationRequest{
+		OrganizationID: c.Locals("organization_id").(uuid.UUID),
+	}
+	for name, target := range map[string]*int{"days": &req.Days, "min_contacts": &req.MinContacts} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New("Invalid " + name + " parameter")
+		}
+		*target = parsed
+	}
+	return req, nil
+}
+
+func (h *TalksToRecommendationHandler) recommendationError(c fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, application.ErrInvalidTalksToRecommendationRequest):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrTalksToRecommendationAgentNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Agent not found",
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compute talks_to suggestions",
+		})
+	}
+}
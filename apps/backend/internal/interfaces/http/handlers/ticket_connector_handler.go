@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type TicketConnectorHandler struct {
+	ticketService *application.TicketConnectorService
+	auditService  *application.AuditService
+}
+
+func NewTicketConnectorHandler(
+	ticketService *application.TicketConnectorService,
+	auditService *application.AuditService,
+) *TicketConnectorHandler {
+	return &TicketConnectorHandler{
+		ticketService: ticketService,
+		auditService:  auditService,
+	}
+}
+
+// CreateConnector connects the organization's Jira or ServiceNow
+// @Summary Create ticket connector
+// @Description Opens a ticket for every new capability request and incident of the organization and syncs the ticket's status and comments back. Ticket statuses listed in approvedStatuses or rejectedStatuses decide capability requests; incidentStatuses moves incidents. The response carries the webhook secret, which is not shown again.
+// @Tags ticketing
+// @Accept json
+// @Produce json
+// @Param request body application.TicketConnectorRequest true "Connector details"
+// @Success 201 {object} domain.TicketConnector
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/ticketing/connectors [post]
+func (h *TicketConnectorHandler) CreateConnector(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.TicketConnectorRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	connector, err := h.ticketService.CreateConnector(c.UserContext(), &req, orgID, userID)
+	if err != nil {
+		return ticketConnectorError(c, err)
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"ticket_connector",
+		connector.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":           connector.Name,
+			"provider":       connector.Provider,
+			"base_url":       connector.BaseURL,
+			"resource_types": connector.ResourceTypes,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(connector)
+}
+
+// ListConnectors lists the organization's ticket connectors
+// @Summary List ticket connectors
+// @Tags ticketing
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/ticketing/connectors [get]
+func (h *TicketConnectorHandler) ListConnectors(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	connectors, err := h.ticketService.ListConnectors(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch ticket connectors",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"connectors": connectors,
+		"total":      len(connectors),
+	})
+}
+
+// GetConnector retrieves a ticket connector
+// @Summary Get ticket connector
+// @Tags ticketing
+// @Produce json
+// @Param id path string true "Connector ID"
+// @Success 200 {object} domain.TicketConnector
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/ticketing/connectors/{id} [get]
+func (h *TicketConnectorHandler) GetConnector(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid connector ID",
+		})
+	}
+
+	connector, err := h.ticketService.GetConnector(c.UserContext(), orgID, id)
+	if err != nil {
+		return ticketConnectorError(c, err)
+	}
+
+	return c.JSON(connector)
+}
+
+// UpdateConnector updates a ticket connector
+// @Summary Update ticket connector
+// @Description Replaces the connector's settings. An empty apiToken keeps the current token; the provider cannot change.
+// @Tags ticketing
+// @Accept json
+// @Produce json
+// @Param id path string true "Connector ID"
+// @Param request body application.TicketConnectorRequest true "Connector details"
+// @Success 200 {object} domain.TicketConnector
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/ticketing/connectors/{id} [put]
+func (h *TicketConnectorHandler) UpdateConnector(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid connector ID",
+		})
+	}
+
+	var req application.TicketConnectorRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	connector, err := h.ticketService.UpdateConnector(c.UserContext(), orgID, id, &req)
+	if err != nil {
+		return ticketConnectorError(c, err)
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"ticket_connector",
+		connector.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":          connector.Name,
+			"isEnabled":     connector.IsEnabled,
+			"token_changed": req.APIToken != "",
+		},
+	)
+
+	return c.JSON(connector)
+}
+
+// DeleteConnector deletes a ticket connector
+// @Summary Delete ticket connector
+// @Description Its tickets stay in the ticketing system but are no longer synced
+// @Tags ticketing
+// @Param id path string true "Connector ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/ticketing/connectors/{id} [delete]
+func (h *TicketConnectorHandler) DeleteConnector(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid connector ID",
+		})
+	}
+
+	if err := h.ticketService.DeleteConnector(c.UserContext(), orgID, id); err != nil {
+		return ticketConnectorError(c, err)
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"ticket_connector",
+		id,
+		c.IP(),
+		c.Get("User-Agent"),
+		nil,
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListLinks lists the tickets opened for a capability request or incident
+// @Summary List tickets of a resource
+// @Tags ticketing
+// @Produce json
+// @Param resourceType query string true "capability_request or incident"
+// @Param resourceId query string true "Capability request or incident ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/ticketing/links [get]
+func (h *TicketConnectorHandler) ListLinks(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	resourceType := c.Query("resourceType")
+	if resourceType != domain.TicketResourceCapabilityRequest && resourceType != domain.TicketResourceIncident {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "resourceType must be capability_request or incident",
+		})
+	}
+	resourceID, err := uuid.Parse(c.Query("resourceId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid resourceId",
+		})
+	}
+
+	links, err := h.ticketService.ListLinks(c.UserContext(), orgID, resourceType, resourceID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch tickets",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"tickets": links,
+		"total":   len(links),
+	})
+}
+
+// ReceiveWebhook syncs a ticket right away when Jira or ServiceNow reports a change
+// @Summary Ticketing system webhook
+// @Description Called by Jira webhooks (issue updated, comment created) or a ServiceNow business rule. Authenticate with the connector's webhook secret in the X-AIM-Webhook-Secret header or the secret query parameter. The body names the ticket as issue.key (Jira), number (ServiceNow) or ticketKey. Tickets AIM did not open are ignored.
+// @Tags ticketing
+// @Accept json
+// @Produce json
+// @Param connectorId path string true "Connector ID"
+// @Success 202 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/public/ticketing/{connectorId}/webhook [post]
+func (h *TicketConnectorHandler) ReceiveWebhook(c fiber.Ctx) error {
+	connectorID, err := uuid.Parse(c.Params("connectorId"))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": application.ErrInvalidTicketWebhookSecret.Error(),
+		})
+	}
+
+	secret := c.Get("X-AIM-Webhook-Secret")
+	if secret == "" {
+		secret = c.Query("secret")
+	}
+
+	var payload struct {
+		Issue struct {
+			Key string `json:"key"`
+		} `json:"issue"`
+		Number    string `json:"number"`
+		TicketKey string `json:"ticketKey"`
+	}
+	if err := c.Bind().JSON(&payload); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	ticketKey := payload.TicketKey
+	if ticketKey == "" {
+		ticketKey = payload.Issue.Key
+	}
+	if ticketKey == "" {
+		ticketKey = payload.Number
+	}
+
+	link, err := h.ticketService.HandleWebhook(c.UserContext(), connectorID, secret, ticketKey)
+	switch {
+	case errors.Is(err, application.ErrInvalidTicketWebhookSecret):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrTicketLinkNotFound):
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"synced": false,
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to sync ticket",
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"synced": true,
+		"ticket": link,
+	})
+}
+
+// ticketConnectorError responds with the HTTP status matching a ticket connector service error
+func ticketConnectorError(c fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrTicketConnectorNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrInvalidTicketConnector):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+)
+
+type TokenIntrospectionHandler struct {
+	introspectionService *application.TokenIntrospectionService
+}
+
+func NewTokenIntrospectionHandler(introspectionService *application.TokenIntrospectionService) *TokenIntrospectionHandler {
+	return &TokenIntrospectionHandler{
+		introspectionService: introspectionService,
+	}
+}
+
+// Introspect validates a token for a relying party (RFC 7662)
+// @Summary Introspect token
+// @Description Reports whether a session token, SDK token or agent API key issued by this server is active, including revocation status. The relying party authenticates with an API key carrying the tokens:introspect scope; tokens of other organizations are reported inactive.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Token to introspect"
+// @Param token_type_hint formData string false "access_token, refresh_token or api_key"
+// @Success 200 {object} application.TokenIntrospection
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /oauth/introspect [post]
+func (h *TokenIntrospectionHandler) Introspect(c fiber.Ctx) error {
+	token := c.FormValue("token")
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":             "invalid_request",
+			"error_description": "token is required",
+		})
+	}
+
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	result, err := h.introspectionService.Introspect(c.UserContext(), orgID, token, c.FormValue("token_type_hint"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to introspect token",
+		})
+	}
+
+	c.Set("Cache-Control", "no-store")
+	return c.JSON(result)
+}
+
+// Metadata returns the authorization server metadata document (RFC 8414)
+// @Summary Authorization server metadata
+// @Tags oauth
+// @Produce json
+// @Success 200 {object} application.AuthorizationServerMetadata
+// @Router /.well-known/oauth-authorization-server [get]
+func (h *TokenIntrospectionHandler) Metadata(c fiber.Ctx) error {
+	return c.JSON(h.introspectionService.Metadata())
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type TombstoneHandler struct {
+	tombstoneService *application.TombstoneService
+}
+
+func NewTombstoneHandler(tombstoneService *application.TombstoneService) *TombstoneHandler {
+	return &TombstoneHandler{
+		tombstoneService: tombstoneService,
+	}
+}
+
+// SearchTombstones searches the records of deleted agents, MCP servers and API keys
+// @Summary Search deleted entities
+// @Description Redacted, immutable records of deleted agents, MCP servers and API keys (ID, name, type, deletion actor, timestamp, linked counts)
+// @Tags admin
+// @Produce json
+// @Param type query string false "Entity type (agent, mcp_server, api_key)"
+// @Param q query string false "Name (partial, case-insensitive) or exact entity ID"
+// @Param deleted_by query string false "User who deleted the entity"
+// @Param limit query int false "Page size (max 200)" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/tombstones [get]
+func (h *TombstoneHandler) SearchTombstones(c fiber.Ctx) error {
+	filter := &domain.TombstoneFilter{
+		OrganizationID: c.Locals("organization_id").(uuid.UUID),
+		EntityType:     domain.TombstoneEntityType(c.Query("type")),
+		Query:          c.Query("q"),
+	}
+
+	if deletedBy := c.Query("deleted_by"); deletedBy != "" {
+		id, err := uuid.Parse(deletedBy)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid deleted_by user ID",
+			})
+		}
+		filter.DeletedBy = &id
+	}
+
+	filter.Limit, _ = strconv.Atoi(c.Query("limit", "50"))
+	filter.Offset, _ = strconv.Atoi(c.Query("offset", "0"))
+
+	tombstones, total, err := h.tombstoneService.SearchTombstones(c.UserContext(), filter)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"tombstones": tombstones,
+		"total":      total,
+		"limit":      filter.Limit,
+		"offset":     filter.Offset,
+	})
+}
+
+// GetTombstone resolves the ID of a deleted entity to its tombstone
+// @Summary Resolve deleted entity
+// @Tags admin
+// @Produce json
+// @Param entityId path string true "ID of the deleted agent, MCP server or API key"
+// @Success 200 {object} domain.Tombstone
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/tombstones/{entityId} [get]
+func (h *TombstoneHandler) GetTombstone(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	entityID, err := uuid.Parse(c.Params("entityId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid entity ID",
+		})
+	}
+
+	tombstone, err := h.tombstoneService.GetTombstone(c.UserContext(), orgID, entityID)
+	if err != nil {
+		if errors.Is(err, application.ErrTombstoneNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "No deleted entity with this ID",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch tombstone",
+		})
+	}
+
+	return c.JSON(tombstone)
+}
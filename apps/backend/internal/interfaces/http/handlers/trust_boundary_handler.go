@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type TrustBoundaryHandler struct {
+	trustBoundaryService *application.TrustBoundaryService
+	auditService         *application.AuditService
+}
+
+func NewTrustBoundaryHandler(
+	trustBoundaryService *application.TrustBoundaryService,
+	auditService *application.AuditService,
+) *TrustBoundaryHandler {
+	return &TrustBoundaryHandler{
+		trustBoundaryService: trustBoundaryService,
+		auditService:         auditService,
+	}
+}
+
+// GetPolicy returns the organization's trust score floor and ceiling actions
+// @Summary Get trust boundary policy
+// @Tags admin
+// @Produce json
+// @Success 200 {object} domain.TrustBoundaryPolicy
+// @Router /api/v1/admin/trust-boundary-policy [get]
+func (h *TrustBoundaryHandler) GetPolicy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	policy, err := h.trustBoundaryService.GetPolicy(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch trust boundary policy",
+		})
+	}
+
+	return c.JSON(policy)
+}
+
+// UpdatePolicy configures what happens automatically when trust scores cross the floor or ceiling
+// @Summary Update trust boundary policy
+// @Description Below the floor agents are suspended and an incident is opened; after a sustained period above the ceiling expiring API keys are extended and verifications stay valid longer
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body application.TrustBoundaryPolicyRequest true "Trust boundaries"
+// @Success 200 {object} domain.TrustBoundaryPolicy
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/trust-boundary-policy [put]
+func (h *TrustBoundaryHandler) UpdatePolicy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.TrustBoundaryPolicyRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	policy, err := h.trustBoundaryService.UpdatePolicy(c.UserContext(), &req, orgID, userID)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidTrustBoundaryPolicy) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save trust boundary policy",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"trust_boundary_policy",
+		policy.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"enabled":                    policy.Enabled,
+			"floor_score":                policy.FloorScore,
+			"floor_suspend":              policy.FloorSuspend,
+			"floor_open_incident":        policy.FloorOpenIncident,
+			"incident_severity":          policy.IncidentSeverity,
+			"ceiling_score":              policy.CeilingScore,
+			"ceiling_sustained_days":     policy.CeilingSustainedDays,
+			"ceiling_extend_key_expiry":  policy.CeilingExtendKeyExpiry,
+			"key_extension_days":         policy.KeyExtensionDays,
+			"ceiling_relax_verification": policy.CeilingRelaxVerification,
+		},
+	)
+
+	return c.JSON(policy)
+}
+
+// EvaluateNow evaluates every agent of the organization against its trust boundaries without
+// waiting for the background worker
+// @Summary Evaluate trust boundaries now
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/trust-boundary-policy/evaluate [post]
+func (h *TrustBoundaryHandler) EvaluateNow(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	events, err := h.trustBoundaryService.EvaluateOrganization(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to evaluate trust boundaries",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"transitions": events,
+		"total":       len(events),
+	})
+}
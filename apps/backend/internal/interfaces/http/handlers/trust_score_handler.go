@@ -39,7 +39,7 @@ func (h *TrustScoreHandler) CalculateTrustScore(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -53,7 +53,7 @@ func (h *TrustScoreHandler) CalculateTrustScore(c fiber.Ctx) error {
 	}
 
 	// Calculate trust score
-	score, err := h.trustCalculator.CalculateTrustScore(c.Context(), agentID)
+	score, err := h.trustCalculator.CalculateTrustScore(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to calculate trust score",
@@ -62,7 +62,7 @@ func (h *TrustScoreHandler) CalculateTrustScore(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionCalculate,
@@ -96,7 +96,7 @@ func (h *TrustScoreHandler) GetTrustScore(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -110,7 +110,7 @@ func (h *TrustScoreHandler) GetTrustScore(c fiber.Ctx) error {
 	}
 
 	// Get latest trust score
-	score, err := h.trustCalculator.GetLatestTrustScore(c.Context(), agentID)
+	score, err := h.trustCalculator.GetLatestTrustScore(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "No trust score found",
@@ -137,7 +137,7 @@ func (h *TrustScoreHandler) GetTrustScoreBreakdown(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -151,7 +151,7 @@ func (h *TrustScoreHandler) GetTrustScoreBreakdown(c fiber.Ctx) error {
 	}
 
 	// Get latest trust score
-	score, err := h.trustCalculator.GetLatestTrustScore(c.Context(), agentID)
+	score, err := h.trustCalculator.GetLatestTrustScore(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "No trust score found",
@@ -215,7 +215,7 @@ func (h *TrustScoreHandler) GetTrustScoreHistory(c fiber.Ctx) error {
 	}
 
 	// Verify agent belongs to organization
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -237,7 +237,7 @@ func (h *TrustScoreHandler) GetTrustScoreHistory(c fiber.Ctx) error {
 	}
 
 	// Get trust score audit trail from trust_score_history table
-	history, err := h.trustCalculator.GetTrustScoreHistoryAuditTrail(c.Context(), agentID, limit)
+	history, err := h.trustCalculator.GetTrustScoreHistoryAuditTrail(c.UserContext(), agentID, limit)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch trust score history",
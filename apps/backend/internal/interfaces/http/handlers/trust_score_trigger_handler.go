@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type TrustScoreTriggerHandler struct {
+	triggerService *application.TrustScoreTriggerService
+	auditService   *application.AuditService
+}
+
+func NewTrustScoreTriggerHandler(
+	triggerService *application.TrustScoreTriggerService,
+	auditService *application.AuditService,
+) *TrustScoreTriggerHandler {
+	return &TrustScoreTriggerHandler{
+		triggerService: triggerService,
+		auditService:   auditService,
+	}
+}
+
+// ListTriggers lists the organization's trust score triggers
+// @Summary List trust score triggers
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/trust-score-triggers [get]
+func (h *TrustScoreTriggerHandler) ListTriggers(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	triggers, err := h.triggerService.ListTriggers(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch trust score triggers",
+		})
+	}
+
+	if triggers == nil {
+		triggers = []*domain.TrustScoreTrigger{}
+	}
+
+	return c.JSON(fiber.Map{
+		"triggers": triggers,
+		"total":    len(triggers),
+	})
+}
+
+// CreateTrigger creates a trust score trigger
+// @Summary Create trust score trigger
+// @Description Runs actions (alert, webhook, suspend_agent, revoke_api_keys, open_incident) in order when an agent's trust score (0-1) crosses the threshold in the condition's direction (below or above).
+// @Description Triggers are evaluated every time a score is stored and fire once per crossing.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body application.TrustScoreTriggerRequest true "Trust score trigger"
+// @Success 201 {object} domain.TrustScoreTrigger
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/trust-score-triggers [post]
+func (h *TrustScoreTriggerHandler) CreateTrigger(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.TrustScoreTriggerRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	trigger, err := h.triggerService.CreateTrigger(c.UserContext(), &req, orgID, userID)
+	if err != nil {
+		return trustScoreTriggerError(c, err, "Failed to create trust score trigger")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"trust_score_trigger",
+		trigger.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":      trigger.Name,
+			"condition": trigger.Condition,
+			"threshold": trigger.Threshold,
+			"actions":   trigger.Actions,
+		},
+	)
+
+	return c.Status(fiber.StatusCreated).JSON(trigger)
+}
+
+// GetTrigger retrieves a trust score trigger
+// @Summary Get trust score trigger
+// @Tags admin
+// @Produce json
+// @Param id path string true "Trigger ID"
+// @Success 200 {object} domain.TrustScoreTrigger
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/trust-score-triggers/{id} [get]
+func (h *TrustScoreTriggerHandler) GetTrigger(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	triggerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid trigger ID",
+		})
+	}
+
+	trigger, err := h.triggerService.GetTrigger(c.UserContext(), orgID, triggerID)
+	if err != nil {
+		return trustScoreTriggerError(c, err, "Failed to fetch trust score trigger")
+	}
+
+	return c.JSON(trigger)
+}
+
+// UpdateTrigger replaces a trust score trigger's definition
+// @Summary Update trust score trigger
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Trigger ID"
+// @Param request body application.TrustScoreTriggerRequest true "Trust score trigger"
+// @Success 200 {object} domain.TrustScoreTrigger
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/trust-score-triggers/{id} [put]
+func (h *TrustScoreTriggerHandler) UpdateTrigger(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	triggerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid trigger ID",
+		})
+	}
+
+	var req application.TrustScoreTriggerRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	trigger, err := h.triggerService.UpdateTrigger(c.UserContext(), orgID, triggerID, &req)
+	if err != nil {
+		return trustScoreTriggerError(c, err, "Failed to update trust score trigger")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"trust_score_trigger",
+		trigger.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"name":       trigger.Name,
+			"condition":  trigger.Condition,
+			"threshold":  trigger.Threshold,
+			"actions":    trigger.Actions,
+			"is_enabled": trigger.IsEnabled,
+		},
+	)
+
+	return c.JSON(trigger)
+}
+
+// DeleteTrigger deletes a trust score trigger; its firing history is kept
+// @Summary Delete trust score trigger
+// @Tags admin
+// @Param id path string true "Trigger ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/trust-score-triggers/{id} [delete]
+func (h *TrustScoreTriggerHandler) DeleteTrigger(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	triggerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid trigger ID",
+		})
+	}
+
+	if err := h.triggerService.DeleteTrigger(c.UserContext(), orgID, triggerID); err != nil {
+		return trustScoreTriggerError(c, err, "Failed to delete trust score trigger")
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionDelete,
+		"trust_score_trigger",
+		triggerID,
+		c.IP(),
+		c.Get("User-Agent"),
+		nil,
+	)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListFirings lists the organization's trust score trigger firings, newest first
+// @Summary List trust score trigger firings
+// @Tags admin
+// @Produce json
+// @Param triggerId query string false "Only firings of this trigger"
+// @Param limit query int false "Limit (default 50)"
+// @Param offset query int false "Offset (default 0)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/trust-score-triggers/firings [get]
+func (h *TrustScoreTriggerHandler) ListFirings(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var triggerID *uuid.UUID
+	if raw := c.Query("triggerId"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid trigger ID",
+			})
+		}
+		triggerID = &id
+	}
+
+	firings, total, err := h.triggerService.ListFirings(c.UserContext(), orgID, triggerID, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch trust score trigger firings",
+		})
+	}
+
+	if firings == nil {
+		firings = []*domain.TrustScoreTriggerFiring{}
+	}
+
+	return c.JSON(fiber.Map{
+		"firings": firings,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+func trustScoreTriggerError(c fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrTrustScoreTriggerNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Trust score trigger not found",
+		})
+	case errors.Is(err, application.ErrInvalidTrustScoreTrigger):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
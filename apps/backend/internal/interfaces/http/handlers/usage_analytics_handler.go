@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type UsageAnalyticsHandler struct {
+	usageService *application.UsageAnalyticsService
+}
+
+func NewUsageAnalyticsHandler(usageService *application.UsageAnalyticsService) *UsageAnalyticsHandler {
+	return &UsageAnalyticsHandler{
+		usageService: usageService,
+	}
+}
+
+// GetAgentUsage reports an agent's usage
+// @Summary Agent usage
+// @Description Requests per day, verification outcomes, error rate and the MCP servers contacted most, from the daily usage rollups
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param days query int false "Number of days (1-365)" default(30)
+// @Param top query int false "Number of MCP servers to list (1-100)" default(10)
+// @Success 200 {object} domain.UsageReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/usage [get]
+func (h *UsageAnalyticsHandler) GetAgentUsage(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+	days, top, err := parseUsageQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	report, err := h.usageService.GetAgentUsage(c.UserContext(), orgID, agentID, days, top)
+	if err != nil {
+		return usageError(c, err, "Agent not found")
+	}
+	return c.JSON(report)
+}
+
+// GetAPIKeyUsage reports an API key's usage
+// @Summary API key usage
+// @Description Requests per day, verification outcomes, error rate and the MCP servers contacted most by requests authenticated with the key
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Param days query int false "Number of days (1-365)" default(30)
+// @Param top query int false "Number of MCP servers to list (1-100)" default(10)
+// @Success 200 {object} domain.UsageReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/api-keys/{id}/usage [get]
+func (h *UsageAnalyticsHandler) GetAPIKeyUsage(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	keyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid API key ID",
+		})
+	}
+	days, top, err := parseUsageQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	report, err := h.usageService.GetAPIKeyUsage(c.UserContext(), orgID, keyID, days, top)
+	if err != nil {
+		return usageError(c, err, "API key not found")
+	}
+	return c.JSON(report)
+}
+
+// ListAgentUsage summarizes the usage of every agent of the organization
+// @Summary Usage by agent
+// @Description Every agent's requests, outcomes, error rate, peak day and daily average over the period, busiest first; agents without requests are marked idle
+// @Tags analytics
+// @Produce json
+// @Param days query int false "Number of days (1-365)" default(30)
+// @Success 200 {object} domain.UsageSummaryReport
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/analytics/usage/agents [get]
+func (h *UsageAnalyticsHandler) ListAgentUsage(c fiber.Ctx) error {
+	return h.list(c, domain.UsageSubjectAgent)
+}
+
+// ListAPIKeyUsage summarizes the usage of every API key of the organization
+// @Summary Usage by API key
+// @Description Every API key's requests, outcomes, error rate, peak day and daily average over the period, busiest first; keys without requests are marked idle
+// @Tags analytics
+// @Produce json
+// @Param days query int false "Number of days (1-365)" default(30)
+// @Success 200 {object} domain.UsageSummaryReport
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/analytics/usage/api-keys [get]
+func (h *UsageAnalyticsHandler) ListAPIKeyUsage(c fiber.Ctx) error {
+	return h.list(c, domain.UsageSubjectAPIKey)
+}
+
+func (h *UsageAnalyticsHandler) list(c fiber.Ctx, subjectType domain.UsageSubjectType) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	days, _, err := parseUsageQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	report, err := h.usageService.ListUsage(c.UserContext(), orgID, subjectType, days)
+	if err != nil {
+		return usageError(c, err, "")
+	}
+	return c.JSON(report)
+}
+
+// parseUsageQuery reads the days and top query parameters of the usage endpoints
+func parseUsageQuery(c fiber.Ctx) (days, top int, err error) {
+	for name, target := range map[string]*int{"days": &days, "top": &top} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid %s parameter", name)
+		}
+		*target = parsed
+	}
+	return days, top, nil
+}
+
+func usageError(c fiber.Ctx, err error, notFound string) error {
+	switch {
+	case errors.Is(err, application.ErrInvalidUsageRequest):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrUsageSubjectNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": notFound,
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Failed to compute usage",
+	})
+}
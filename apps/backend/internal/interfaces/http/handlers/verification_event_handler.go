@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"strconv"
 	"time"
 
@@ -51,6 +52,10 @@ func (h *VerificationEventHandler) RegisterRoutes(app *fiber.App, authMiddleware
 // @Param limit query int false "Number of events to return" default(50)
 // @Param offset query int false "Number of events to skip" default(0)
 // @Param agent_id query string false "Filter by agent ID"
+// @Param initiator_user_id query string false "Filter by the user the recording request acted as"
+// @Param initiator_agent_id query string false "Filter by the agent whose credential recorded the event"
+// @Param initiator_api_key_id query string false "Filter by the API key that recorded the event"
+// @Param initiator_ip query string false "Filter by the IP address the recording request came from"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
@@ -78,20 +83,40 @@ func (h *VerificationEventHandler) ListVerificationEvents(c fiber.Ctx) error {
 
 	agentIDStr := c.Query("agent_id")
 
+	params := domain.VerificationQueryParams{Limit: limit, Offset: offset}
+	filtered, err := parseInitiatorFilters(c, &params)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	var events []*domain.VerificationEvent
 	var total int
 
-	// Filter by agent if specified
-	if agentIDStr != "" {
+	// Initiator filters search the organization's events, narrowed to the agent if specified
+	if filtered {
+		if agentIDStr != "" {
+			agentID, err := uuid.Parse(agentIDStr)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid agent ID format",
+				})
+			}
+			params.AgentID = &agentID
+		}
+		events, total, _, err = h.service.SearchVerifications(c.UserContext(), orgID, params)
+	} else if agentIDStr != "" {
+		// Filter by agent if specified
 		agentID, err := uuid.Parse(agentIDStr)
 		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "Invalid agent ID format",
 			})
 		}
-		events, total, err = h.service.ListAgentVerificationEvents(c.Context(), agentID, limit, offset)
+		events, total, err = h.service.ListAgentVerificationEvents(c.UserContext(), agentID, limit, offset)
 	} else {
-		events, total, err = h.service.ListVerificationEvents(c.Context(), orgID, limit, offset)
+		events, total, err = h.service.ListVerificationEvents(c.UserContext(), orgID, limit, offset)
 	}
 
 	if err != nil {
@@ -139,7 +164,7 @@ func (h *VerificationEventHandler) GetVerificationEvent(c fiber.Ctx) error {
 	}
 
 	// Get event
-	event, err := h.service.GetVerificationEvent(c.Context(), eventID)
+	event, err := h.service.GetVerificationEvent(c.UserContext(), eventID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Verification event not found",
@@ -179,6 +204,7 @@ type CreateVerificationEventRequest struct {
 
 	// Configuration Drift Detection (WHO and WHAT)
 	CurrentMCPServers   []string `json:"currentMcpServers,omitempty"`   // Runtime: MCP servers being communicated with
+	CurrentMCPTools     []string `json:"currentMcpTools,omitempty"`     // Runtime: MCP tools being called, as server:tool
 	CurrentCapabilities []string `json:"currentCapabilities,omitempty"` // Runtime: Capabilities being used
 }
 
@@ -219,6 +245,15 @@ func (h *VerificationEventHandler) CreateVerificationEvent(c fiber.Ctx) error {
 		})
 	}
 
+	// Tool calls name the MCP server and the tool, so they can be checked against tool grants
+	for _, call := range req.CurrentMCPTools {
+		if _, _, ok := domain.ParseMCPToolCall(call); !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("Invalid MCP tool call '%s', expected server:tool", call),
+			})
+		}
+	}
+
 	// Parse optional initiator ID
 	var initiatorID *uuid.UUID
 	if req.InitiatorID != nil {
@@ -261,11 +296,12 @@ func (h *VerificationEventHandler) CreateVerificationEvent(c fiber.Ctx) error {
 
 		// Configuration Drift Detection
 		CurrentMCPServers:   req.CurrentMCPServers,
+		CurrentMCPTools:     req.CurrentMCPTools,
 		CurrentCapabilities: req.CurrentCapabilities,
 	}
 
 	// Create event
-	event, err := h.service.CreateVerificationEvent(c.Context(), serviceReq)
+	event, err := h.service.CreateVerificationEvent(c.UserContext(), serviceReq)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create verification event",
@@ -303,7 +339,7 @@ func (h *VerificationEventHandler) GetRecentEvents(c fiber.Ctx) error {
 	}
 
 	// Get recent events
-	events, err := h.service.GetRecentEvents(c.Context(), orgID, minutes)
+	events, err := h.service.GetRecentEvents(c.UserContext(), orgID, minutes)
 	if err != nil {
 		// Log the actual error for debugging
 		println("ERROR in GetRecentEvents:", err.Error())
@@ -386,7 +422,7 @@ func (h *VerificationEventHandler) GetStatistics(c fiber.Ctx) error {
 	}
 
 	// Get statistics
-	stats, err := h.service.GetStatistics(c.Context(), orgID, startTime, endTime)
+	stats, err := h.service.GetStatistics(c.UserContext(), orgID, startTime, endTime)
 	if err != nil {
 		// Log the actual error for debugging
 		println("ERROR in GetStatistics:", err.Error())
@@ -441,7 +477,7 @@ func (h *VerificationEventHandler) GetAgentVerificationEvents(c fiber.Ctx) error
 	}
 
 	// Get verification events for this agent
-	events, total, err := h.service.ListAgentVerificationEvents(c.Context(), agentID, limit, offset)
+	events, total, err := h.service.ListAgentVerificationEvents(c.UserContext(), agentID, limit, offset)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve verification events",
@@ -500,7 +536,7 @@ func (h *VerificationEventHandler) GetMCPVerificationEvents(c fiber.Ctx) error {
 
 	// Get verification events for this MCP server
 	// We need to add this method to the service layer
-	events, total, err := h.service.ListMCPVerificationEvents(c.Context(), mcpServerID, limit, offset)
+	events, total, err := h.service.ListMCPVerificationEvents(c.UserContext(), mcpServerID, limit, offset)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve verification events",
@@ -555,7 +591,7 @@ func (h *VerificationEventHandler) GetVerificationStats(c fiber.Ctx) error {
 	}
 
 	// Get statistics from service
-	stats, err := h.service.GetStatistics(c.Context(), orgID, startTime, endTime)
+	stats, err := h.service.GetStatistics(c.UserContext(), orgID, startTime, endTime)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve verification statistics",
@@ -596,7 +632,7 @@ func (h *VerificationEventHandler) DeleteVerificationEvent(c fiber.Ctx) error {
 	}
 
 	// Delete event
-	if err := h.service.DeleteVerificationEvent(c.Context(), eventID); err != nil {
+	if err := h.service.DeleteVerificationEvent(c.UserContext(), eventID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete verification event",
 		})
@@ -604,3 +640,30 @@ func (h *VerificationEventHandler) DeleteVerificationEvent(c fiber.Ctx) error {
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
+
+// parseInitiatorFilters reads the initiator_user_id, initiator_agent_id, initiator_api_key_id and
+// initiator_ip query parameters into params and reports whether any was given
+func parseInitiatorFilters(c fiber.Ctx, params *domain.VerificationQueryParams) (bool, error) {
+	filtered := false
+	for name, dest := range map[string]**uuid.UUID{
+		"initiator_user_id":    &params.InitiatorUserID,
+		"initiator_agent_id":   &params.InitiatorAgentID,
+		"initiator_api_key_id": &params.InitiatorAPIKeyID,
+	} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return false, fiber.NewError(fiber.StatusBadRequest, "Invalid "+name)
+		}
+		*dest = &id
+		filtered = true
+	}
+	if ip := c.Query("initiator_ip"); ip != "" {
+		params.InitiatorIP = ip
+		filtered = true
+	}
+	return filtered, nil
+}
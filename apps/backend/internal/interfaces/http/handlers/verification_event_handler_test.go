@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/interfaces/http/middleware"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationEventsRecordTheCredentialBehindThemAndFilterByIt(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	ctx := context.Background()
+
+	keyPair, err := crypto.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	publicKey := crypto.EncodeKeyPair(keyPair).PublicKeyBase64
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) {
+		a.Status = domain.AgentStatusVerified
+		a.PublicKey = &publicKey
+	})
+	require.NoError(t, repos.Agent.Create(agent))
+
+	ca, err := crypto.NewAgentCA(make([]byte, 32))
+	require.NoError(t, err)
+	certificates := application.NewAgentCertificateService(repos.AgentCertificate, repos.Agent, ca, time.Hour, "")
+	events := application.NewVerificationEventService(repos.VerificationEvent, repos.Agent, nil, nil, nil, nil)
+	issued, err := certificates.Issue(ctx, agent)
+	require.NoError(t, err)
+
+	// An agent certificate records the agent, the owner it acts for and where the request came from
+	app := fiber.New()
+	app.Use(middleware.AgentCertificateMiddleware(certificates, "X-Client-Cert"))
+	app.Post("/api/v1/verifications", func(c fiber.Ctx) error {
+		event, err := events.LogVerificationEvent(c.UserContext(), org.ID, agent.ID, domain.VerificationProtocolMCP,
+			domain.VerificationTypeCapability, domain.VerificationEventStatusSuccess, 5, domain.InitiatorTypeAgent, nil, nil)
+		if err != nil {
+			return err
+		}
+		return c.JSON(event)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/verifications", nil)
+	req.Header.Set("X-Client-Cert", url.QueryEscape(issued.CertificatePEM))
+	req.Header.Set("User-Agent", "aim-sdk-python/1.4")
+	resp, err := app.Test(req, 5*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var certificateEvent domain.VerificationEvent
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&certificateEvent))
+	require.NotNil(t, certificateEvent.InitiatorAgentID)
+	assert.Equal(t, agent.ID, *certificateEvent.InitiatorAgentID)
+	require.NotNil(t, certificateEvent.InitiatorUserID)
+	assert.Equal(t, agent.CreatedBy, *certificateEvent.InitiatorUserID)
+	assert.Nil(t, certificateEvent.InitiatorAPIKeyID)
+	require.NotNil(t, certificateEvent.InitiatorIP)
+	require.NotNil(t, certificateEvent.InitiatorUserAgent)
+	assert.Equal(t, "aim-sdk-python/1.4", *certificateEvent.InitiatorUserAgent)
+
+	// An API key records the key; an IP the event already names is kept
+	apiKeyID := uuid.New()
+	keyContext := domain.WithCaller(domain.WithAuthMethod(ctx, domain.AuthMethodAPIKey), domain.Caller{
+		UserID:   &agent.CreatedBy,
+		AgentID:  &agent.ID,
+		APIKeyID: &apiKeyID,
+		IP:       "203.0.113.7",
+	})
+	keyEvent, err := events.LogVerificationEvent(keyContext, org.ID, agent.ID, domain.VerificationProtocolMCP,
+		domain.VerificationTypeCapability, domain.VerificationEventStatusFailed, 5, domain.InitiatorTypeAgent, nil, nil)
+	require.NoError(t, err)
+	require.NotNil(t, keyEvent.InitiatorAPIKeyID)
+	assert.Equal(t, apiKeyID, *keyEvent.InitiatorAPIKeyID)
+	assert.Nil(t, keyEvent.InitiatorUserAgent)
+
+	// Events recorded by the platform itself have no credential
+	systemEvent, err := events.LogVerificationEvent(ctx, org.ID, agent.ID, domain.VerificationProtocolMCP,
+		domain.VerificationTypeCapability, domain.VerificationEventStatusSuccess, 5, domain.InitiatorTypeSystem, nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, systemEvent.InitiatorUserID)
+	assert.Nil(t, systemEvent.InitiatorAgentID)
+
+	// The event list pivots from a credential to the events it recorded
+	list := fiber.New()
+	list.Use(func(c fiber.Ctx) error {
+		c.Locals("organization_id", org.ID)
+		return c.Next()
+	})
+	list.Get("/api/v1/verification-events", NewVerificationEventHandler(events).ListVerificationEvents)
+	search := func(query string) (int, []uuid.UUID) {
+		resp, err := list.Test(httptest.NewRequest(http.MethodGet, "/api/v1/verification-events?"+query, nil), 5*time.Second)
+		require.NoError(t, err)
+		var page struct {
+			Events []*domain.VerificationEvent `json:"events"`
+			Total  int                         `json:"total"`
+		}
+		if resp.StatusCode == fiber.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		}
+		var ids []uuid.UUID
+		for _, event := range page.Events {
+			ids = append(ids, event.ID)
+		}
+		return resp.StatusCode, ids
+	}
+
+	status, ids := search("initiator_api_key_id=" + apiKeyID.String())
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, []uuid.UUID{keyEvent.ID}, ids)
+	_, ids = search("initiator_agent_id=" + agent.ID.String())
+	assert.ElementsMatch(t, []uuid.UUID{certificateEvent.ID, keyEvent.ID}, ids)
+	_, ids = search("initiator_user_id=" + agent.CreatedBy.String() + "&initiator_ip=203.0.113.7")
+	assert.Equal(t, []uuid.UUID{keyEvent.ID}, ids)
+	_, ids = search("initiator_agent_id=" + agent.ID.String() + "&agent_id=" + uuid.NewString())
+	assert.Empty(t, ids)
+	_, ids = search("")
+	assert.Len(t, ids, 3)
+	status, _ = search("initiator_api_key_id=not-a-uuid")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const (
+	streamHeartbeat = 15 * time.Second
+	// streamMaxDuration ends a stream so that connections are rebalanced; EventSource clients
+	// reconnect by themselves
+	streamMaxDuration = 10 * time.Minute
+	// streamWriteTimeout replaces the server write timeout, which would otherwise cut the stream
+	streamWriteTimeout = 30 * time.Second
+)
+
+// StreamVerificationEvents pushes verification events to a dashboard as they are recorded
+// @Summary Stream new verification events
+// @Description Pushes the organization's verification events as Server-Sent Events the moment they are recorded, so dashboards no longer poll the admin verification search. Each event's id is a live tail resume token: events missed while disconnected can be fetched from /api/v1/verification-events/tail with it. A "lagged" event reports events dropped because the client fell behind.
+// @Tags verifications
+// @Produce text/event-stream
+// @Param agent_id query string false "Filter by agent ID"
+// @Param mcp_server_id query string false "Filter by MCP server ID"
+// @Param status query string false "Comma-separated statuses (success, failed, pending, timeout)"
+// @Param protocol query string false "Filter by protocol (MCP, A2A, ...)"
+// @Param type query string false "Filter by verification type"
+// @Param drift query bool false "Only events with configuration drift"
+// @Success 200 {string} string
+// @Failure 400 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /api/v1/verifications/stream [get]
+func (h *VerificationEventHandler) StreamVerificationEvents(c fiber.Ctx) error {
+	orgID, err := getOrganizationID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	filter, err := parseVerificationEventFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	subscription, err := h.service.SubscribeVerificationEvents(c.Context(), orgID, filter)
+	if errors.Is(err, application.ErrVerificationStreamUnavailable) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to subscribe to verification events",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+
+	// The stream writer runs after this handler returns, so it only uses the fasthttp context
+	requestCtx := c.Context()
+	conn := requestCtx.Conn()
+	requestCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer subscription.Close()
+
+		flush := func() error {
+			if err := conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+
+		// Reconnect quickly when the stream ends
+		fmt.Fprint(w, "retry: 1000\n\n: connected\n\n")
+		if err := flush(); err != nil {
+			return
+		}
+
+		deadline := time.NewTimer(streamMaxDuration)
+		defer deadline.Stop()
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-requestCtx.Done(): // Server shutdown
+				return
+			case <-deadline.C:
+				fmt.Fprint(w, "event: end\ndata: {}\n\n")
+				flush()
+				return
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			case event, ok := <-subscription.Events():
+				if !ok {
+					return
+				}
+				if dropped := subscription.Dropped(); dropped > 0 {
+					fmt.Fprintf(w, "event: lagged\ndata: {\"dropped\":%d}\n\n", dropped)
+				}
+				data, err := json.Marshal(event)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "id: %s\nevent: verification\ndata: %s\n\n", application.VerificationTailCursorOf(event).Token(), data)
+			}
+			if err := flush(); err != nil {
+				return // Client disconnected
+			}
+		}
+	})
+
+	return nil
+}
+
+// parseVerificationEventFilter reads a verification event filter from the query string
+func parseVerificationEventFilter(c fiber.Ctx) (domain.VerificationEventFilter, error) {
+	var filter domain.VerificationEventFilter
+
+	if value := c.Query("agent_id"); value != "" {
+		agentID, err := uuid.Parse(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid agent_id")
+		}
+		filter.AgentID = &agentID
+	}
+	if value := c.Query("mcp_server_id"); value != "" {
+		mcpServerID, err := uuid.Parse(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid mcp_server_id")
+		}
+		filter.MCPServerID = &mcpServerID
+	}
+	if value := c.Query("status"); value != "" {
+		for _, status := range strings.Split(value, ",") {
+			switch s := domain.VerificationEventStatus(strings.ToLower(strings.TrimSpace(status))); s {
+			case domain.VerificationEventStatusSuccess, domain.VerificationEventStatusFailed,
+				domain.VerificationEventStatusPending, domain.VerificationEventStatusTimeout:
+				filter.Statuses = append(filter.Statuses, s)
+			default:
+				return filter, fmt.Errorf("invalid status %q", status)
+			}
+		}
+	}
+	filter.Protocol = domain.VerificationProtocol(c.Query("protocol"))
+	filter.VerificationType = domain.VerificationType(c.Query("type"))
+	filter.DriftOnly = c.Query("drift") == "true"
+
+	return filter, nil
+}
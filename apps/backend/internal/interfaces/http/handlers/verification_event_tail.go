@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+const tailMaxBackfill = 24 * time.Hour
+
+// tailEnvelope is one line of the NDJSON tail format
+type tailEnvelope struct {
+	Type        string                    `json:"type"` // "event", "heartbeat" or "end"
+	ResumeToken string                    `json:"resumeToken"`
+	Event       *domain.VerificationEvent `json:"event,omitempty"`
+}
+
+// TailVerificationEvents streams verification events matching a filter as they are recorded
+// @Summary Live tail of verification events
+// @Description Streams matching verification events in real time as Server-Sent Events (default) or NDJSON (format=ndjson). Events recorded since the resume token (or `since`) are read first; new events then come from the same broker as /api/v1/verifications/stream. Each event carries a resume token; pass it as resume_token (or Last-Event-ID) to continue where a stream ended. Without a token the tail starts now, or `since` ago.
+// @Tags verification-events
+// @Produce text/event-stream
+// @Param agent_id query string false "Filter by agent ID"
+// @Param mcp_server_id query string false "Filter by MCP server ID"
+// @Param status query string false "Comma-separated statuses (success, failed, pending, timeout)"
+// @Param protocol query string false "Filter by protocol (MCP, A2A, ...)"
+// @Param type query string false "Filter by verification type"
+// @Param drift query bool false "Only events with configuration drift"
+// @Param since query string false "Replay events from this long ago, e.g. 5m (max 24h)"
+// @Param resume_token query string false "Resume after the event with this token"
+// @Param format query string false "sse (default) or ndjson"
+// @Success 200 {string} string
+// @Failure 400 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /api/v1/verification-events/tail [get]
+func (h *VerificationEventHandler) TailVerificationEvents(c fiber.Ctx) error {
+	orgID, err := getOrganizationID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	filter, err := parseVerificationEventFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	cursor := application.NewVerificationTailCursor(time.Now())
+	token := c.Query("resume_token", c.Get("Last-Event-ID"))
+	switch {
+	case token != "":
+		if cursor, err = application.ParseVerificationTailToken(token); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	case c.Query("since") != "":
+		since, err := time.ParseDuration(c.Query("since"))
+		if err != nil || since <= 0 || since > tailMaxBackfill {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "since must be a positive duration of at most 24h (e.g. 5m)",
+			})
+		}
+		cursor = application.NewVerificationTailCursor(time.Now().Add(-since))
+	}
+
+	ndjson := c.Query("format") == "ndjson"
+	switch {
+	case ndjson:
+		c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	case c.Query("format", "sse") == "sse":
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "format must be sse or ndjson",
+		})
+	}
+
+	// Subscribe before reading recorded events, so none recorded in between is missed
+	subscription, err := h.service.SubscribeVerificationEvents(c.Context(), orgID, filter)
+	if errors.Is(err, application.ErrVerificationStreamUnavailable) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to subscribe to verification events",
+		})
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+
+	// The stream writer runs after this handler returns, so it only uses the fasthttp context
+	requestCtx := c.Context()
+	conn := requestCtx.Conn()
+	requestCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer subscription.Close()
+
+		write := func(envelope tailEnvelope) error {
+			if err := conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+				return err
+			}
+			if ndjson {
+				data, err := json.Marshal(envelope)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(w, "%s\n", data)
+			} else {
+				switch envelope.Type {
+				case "event":
+					data, err := json.Marshal(envelope.Event)
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(w, "id: %s\nevent: verification\ndata: %s\n\n", envelope.ResumeToken, data)
+				case "heartbeat":
+					fmt.Fprint(w, ": heartbeat\n\n")
+				default:
+					fmt.Fprintf(w, "event: end\ndata: {\"resumeToken\":%q}\n\n", envelope.ResumeToken)
+				}
+			}
+			return w.Flush()
+		}
+
+		// An event can be both read back and delivered by the broker; it is emitted once
+		emitted := make(map[uuid.UUID]struct{})
+		emit := func(event *domain.VerificationEvent) error {
+			if _, ok := emitted[event.ID]; ok {
+				return nil
+			}
+			emitted[event.ID] = struct{}{}
+			position := application.VerificationTailCursorOf(event)
+			if position.After(cursor) {
+				cursor = position
+			}
+			return write(tailEnvelope{Type: "event", ResumeToken: position.Token(), Event: event})
+		}
+		// catchUp reads the events recorded after the cursor
+		catchUp := func() error {
+			for {
+				events, next, err := h.service.TailVerificationEvents(requestCtx, orgID, cursor, filter, 0)
+				if err != nil {
+					log.Printf("verification event tail failed for organization %s: %v", orgID, err)
+					return err
+				}
+				if len(events) == 0 {
+					return nil
+				}
+				for _, event := range events {
+					if err := emit(event); err != nil {
+						return err
+					}
+				}
+				if next.After(cursor) {
+					cursor = next
+				}
+			}
+		}
+
+		if !ndjson {
+			// Reconnect quickly when the stream ends
+			fmt.Fprint(w, "retry: 1000\n\n")
+		}
+		if err := write(tailEnvelope{Type: "heartbeat", ResumeToken: cursor.Token()}); err != nil {
+			return
+		}
+		if err := catchUp(); err != nil {
+			return
+		}
+
+		deadline := time.NewTimer(streamMaxDuration)
+		defer deadline.Stop()
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			var err error
+			select {
+			case <-requestCtx.Done(): // Server shutdown
+				return
+			case <-deadline.C:
+				write(tailEnvelope{Type: "end", ResumeToken: cursor.Token()})
+				return
+			case <-heartbeat.C:
+				err = write(tailEnvelope{Type: "heartbeat", ResumeToken: cursor.Token()})
+			case event, ok := <-subscription.Events():
+				if !ok {
+					return
+				}
+				if subscription.Dropped() > 0 {
+					// The broker dropped events while the client fell behind; read them back
+					err = catchUp()
+				}
+				if err == nil {
+					err = emit(event)
+				}
+			}
+			if err != nil {
+				return // Client disconnected
+			}
+		}
+	})
+
+	return nil
+}
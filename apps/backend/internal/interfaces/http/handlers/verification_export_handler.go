@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+type VerificationExportHandler struct {
+	exportService *application.VerificationExportService
+	auditService  *application.AuditService
+}
+
+func NewVerificationExportHandler(
+	exportService *application.VerificationExportService,
+	auditService *application.AuditService,
+) *VerificationExportHandler {
+	return &VerificationExportHandler{
+		exportService: exportService,
+		auditService:  auditService,
+	}
+}
+
+// RequestExport queues an export of verification events
+// @Summary Export verification events
+// @Description Queue an export of the verification events created in [start, end) to CSV or Parquet. The file is written in the background and the requester is emailed when it can be downloaded.
+// @Tags verifications
+// @Accept json
+// @Produce json
+// @Param request body application.VerificationExportRequest true "Date range, filters and format"
+// @Success 202 {object} domain.VerificationExport
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/verifications/export [post]
+func (h *VerificationExportHandler) RequestExport(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.VerificationExportRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	export, err := h.exportService.RequestExport(c.UserContext(), orgID, userID, &req)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidVerificationExportRequest) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to request verification export",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionExport,
+		"verification_export",
+		export.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"format": export.Format,
+			"start":  export.Filter.Start,
+			"end":    export.Filter.End,
+		},
+	)
+
+	return c.Status(fiber.StatusAccepted).JSON(export)
+}
+
+// ListExports lists the organization's recent verification event exports
+// @Summary List verification exports
+// @Tags verifications
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/verifications/exports [get]
+func (h *VerificationExportHandler) ListExports(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	exports, err := h.exportService.ListExports(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list verification exports",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"exports": exports,
+		"total":   len(exports),
+	})
+}
+
+// GetExport returns the status of a verification event export
+// @Summary Get verification export
+// @Tags verifications
+// @Produce json
+// @Param id path string true "Export ID"
+// @Success 200 {object} domain.VerificationExport
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/verifications/exports/{id} [get]
+func (h *VerificationExportHandler) GetExport(c fiber.Ctx) error {
+	export, status, msg := h.getOwnedExport(c)
+	if export == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	return c.JSON(export)
+}
+
+// DownloadExport downloads the file of a completed verification event export
+// @Summary Download verification export
+// @Tags verifications
+// @Produce octet-stream
+// @Param id path string true "Export ID"
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 410 {object} map[string]interface{}
+// @Router /api/v1/verifications/exports/{id}/download [get]
+func (h *VerificationExportHandler) DownloadExport(c fiber.Ctx) error {
+	export, status, msg := h.getOwnedExport(c)
+	if export == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	content, err := h.exportService.GetExportContent(c.UserContext(), export)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrVerificationExportNotReady):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":  err.Error(),
+				"status": export.Status,
+			})
+		case errors.Is(err, application.ErrVerificationExportExpired):
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to read export file",
+			})
+		}
+	}
+
+	c.Set("Content-Type", export.ContentType)
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", export.Filename))
+	return c.Send(content)
+}
+
+// getOwnedExport loads the export in the :id param of the caller's organization.
+// On failure it returns a nil export with the HTTP status and message to respond with.
+func (h *VerificationExportHandler) getOwnedExport(c fiber.Ctx) (*domain.VerificationExport, int, string) {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	exportID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, fiber.StatusBadRequest, "Invalid export ID"
+	}
+
+	export, err := h.exportService.GetExport(c.UserContext(), orgID, exportID)
+	if err != nil {
+		if errors.Is(err, domain.ErrVerificationExportNotFound) {
+			return nil, fiber.StatusNotFound, "Verification export not found"
+		}
+		return nil, fiber.StatusInternalServerError, "Failed to get verification export"
+	}
+	return export, 0, ""
+}
@@ -3,9 +3,9 @@ package handlers
 import (
 	"bytes"
 	"context"
-	"crypto/ed25519"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"strconv"
@@ -15,7 +15,9 @@ import (
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/crypto"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/tracing"
 )
 
 // VerificationHandler handles agent action verification requests
@@ -25,6 +27,7 @@ type VerificationHandler struct {
 	alertService             *application.AlertService
 	trustService             *application.TrustCalculator
 	verificationEventService *application.VerificationEventService
+	trustBoundaryService     *application.TrustBoundaryService
 }
 
 // NewVerificationHandler creates a new verification handler
@@ -34,6 +37,7 @@ func NewVerificationHandler(
 	alertService *application.AlertService,
 	trustService *application.TrustCalculator,
 	verificationEventService *application.VerificationEventService,
+	trustBoundaryService *application.TrustBoundaryService,
 ) *VerificationHandler {
 	return &VerificationHandler{
 		agentService:             agentService,
@@ -41,9 +45,19 @@ func NewVerificationHandler(
 		alertService:             alertService,
 		trustService:             trustService,
 		verificationEventService: verificationEventService,
+		trustBoundaryService:     trustBoundaryService,
 	}
 }
 
+// approvalValidity is how long an auto-approved verification of the agent stays valid; agents
+// that stayed above their organization's trust ceiling re-verify less often
+func (h *VerificationHandler) approvalValidity(c fiber.Ctx, agent *domain.Agent) time.Duration {
+	if h.trustBoundaryService == nil || agent == nil {
+		return application.DefaultVerificationValidity
+	}
+	return h.trustBoundaryService.VerificationValidity(c.UserContext(), agent)
+}
+
 // VerificationRequest represents an action verification request from an agent
 type VerificationRequest struct {
 	AgentID    string                 `json:"agent_id" validate:"required"`
@@ -54,6 +68,9 @@ type VerificationRequest struct {
 	RiskLevel  string                 `json:"risk_level,omitempty"` // Optional risk assessment
 	Signature  string                 `json:"signature" validate:"required"`
 	PublicKey  string                 `json:"public_key" validate:"required"`
+	// Optional: the MCP tool the action calls, checked against the agent's tool grants; signed when set
+	MCPServer string `json:"mcp_server,omitempty"`
+	ToolName  string `json:"tool_name,omitempty"`
 }
 
 // VerificationResponse represents the verification result
@@ -93,6 +110,11 @@ func (h *VerificationHandler) CreateVerification(c fiber.Ctx) error {
 			"error": "agent_id, action_type, signature, and public_key are required",
 		})
 	}
+	if (req.MCPServer == "") != (req.ToolName == "") || strings.Contains(req.ToolName, ":") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "mcp_server and tool_name must be set together, and tool_name can't contain ':'",
+		})
+	}
 
 	// Parse agent ID
 	agentID, err := uuid.Parse(req.AgentID)
@@ -103,7 +125,7 @@ func (h *VerificationHandler) CreateVerification(c fiber.Ctx) error {
 	}
 
 	// Get agent from database
-	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	agent, err := h.agentService.GetAgent(c.UserContext(), agentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -117,17 +139,29 @@ func (h *VerificationHandler) CreateVerification(c fiber.Ctx) error {
 		})
 	}
 
-	// Verify public key matches
-	publicKeyMatched := agent.PublicKey != nil && *agent.PublicKey == req.PublicKey
+	// Verify public key matches (the previous key is accepted until its rotation grace period ends)
+	publicKeyMatched := agent.AcceptsPublicKey(req.PublicKey, time.Now())
 	if !publicKeyMatched {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Public key mismatch",
 		})
 	}
 
+	// Keys whose certificate is on the revocation list never verify
+	if err := h.agentService.CheckCertificateRevocation(c.UserContext(), agent, req.PublicKey); err != nil {
+		if errors.Is(err, domain.ErrAgentCertificateRevoked) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Agent certificate revoked",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check certificate revocation",
+		})
+	}
+
 	// Verify signature
 	signatureVerified := false
-	if err := h.verifySignature(req); err != nil {
+	if err := h.verifySignature(c.UserContext(), agent, req); err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": fmt.Sprintf("Signature verification failed: %v", err),
 		})
@@ -143,7 +177,7 @@ func (h *VerificationHandler) CreateVerification(c fiber.Ctx) error {
 	// The old determineVerificationStatus() only checked trust scores, not capabilities!
 	// ============================================================================
 	allowed, denialReason, auditIDFromVerify, err := h.agentService.VerifyAction(
-		c.Context(),
+		c.UserContext(),
 		agentID,
 		req.ActionType,
 		req.Resource,
@@ -160,6 +194,9 @@ func (h *VerificationHandler) CreateVerification(c fiber.Ctx) error {
 		}
 	} else if allowed {
 		status = "approved"
+	} else if application.IsApprovalRequired(denialReason) {
+		// Sensitive tool held for just-in-time approval - an admin approves or denies the pending verification
+		status = "pending"
 	} else {
 		status = "denied"
 	}
@@ -172,7 +209,7 @@ func (h *VerificationHandler) CreateVerification(c fiber.Ctx) error {
 	// Low-risk actions: No alerts needed, just tracking (better UX for demos)
 	// Medium/High-risk actions: Alert if action is denied or lacks capability
 	shouldCreateAlert := false
-	hasCapability, err := h.agentService.HasCapability(c.Context(), agentID, req.ActionType, req.Resource)
+	hasCapability, err := h.agentService.HasCapability(c.UserContext(), agentID, req.ActionType, req.Resource)
 	if err != nil {
 		fmt.Printf("⚠️  Error checking capability: %v\n", err)
 	} else if !hasCapability {
@@ -223,7 +260,7 @@ func (h *VerificationHandler) CreateVerification(c fiber.Ctx) error {
 	}
 
 	// Save audit log
-	if err := h.auditService.Log(c.Context(), auditEntry); err != nil {
+	if err := h.auditService.Log(c.UserContext(), auditEntry); err != nil {
 		// Log error but don't fail the request
 		fmt.Printf("Failed to create audit log: %v\n", err)
 	}
@@ -275,7 +312,7 @@ func (h *VerificationHandler) CreateVerification(c fiber.Ctx) error {
 		}
 
 		// Save alert to database using AgentService's alert repository
-		if err := h.agentService.CreateSecurityAlert(c.Context(), alert); err != nil {
+		if err := h.agentService.CreateSecurityAlert(c.UserContext(), alert); err != nil {
 			fmt.Printf("⚠️  Warning: failed to create security alert: %v\n", err)
 		} else {
 			fmt.Printf("✅ Security alert created (severity: %s): %s\n", severity, alert.ID.String())
@@ -329,6 +366,8 @@ func (h *VerificationHandler) CreateVerification(c fiber.Ctx) error {
 	}
 	if status == "denied" {
 		eventMetadata["denial_reason"] = denialReason
+	} else if status == "pending" {
+		eventMetadata["pending_reason"] = denialReason
 	}
 
 	// Create verification event using service
@@ -362,9 +401,13 @@ func (h *VerificationHandler) CreateVerification(c fiber.Ctx) error {
 		CompletedAt:      &completedAt,
 		Metadata:         eventMetadata,
 	}
+	if req.ToolName != "" {
+		// The tool call is checked against the agent's talks_to and tool grants by drift detection
+		verificationEventReq.CurrentMCPTools = []string{domain.MCPToolCall(req.MCPServer, req.ToolName)}
+	}
 
 	// Save verification event using service
-	event, err := h.verificationEventService.CreateVerificationEvent(c.Context(), verificationEventReq)
+	event, err := h.verificationEventService.CreateVerificationEvent(c.UserContext(), verificationEventReq)
 	if err != nil {
 		// Log error but don't fail the request
 		fmt.Printf("❌ Failed to create verification event: %v\n", err)
@@ -404,7 +447,7 @@ func (h *VerificationHandler) CreateVerification(c fiber.Ctx) error {
 
 	if status == "approved" {
 		response.ApprovedBy = "system" // Auto-approved
-		response.ExpiresAt = time.Now().Add(24 * time.Hour)
+		response.ExpiresAt = time.Now().Add(h.approvalValidity(c, agent))
 	} else if status == "denied" {
 		response.DenialReason = denialReason
 	}
@@ -457,8 +500,15 @@ func customJSONFormat(jsonStr string) string {
 	return result.String()
 }
 
-// verifySignature verifies the Ed25519 signature
-func (h *VerificationHandler) verifySignature(req VerificationRequest) error {
+// verifySignature verifies the signature with the agent's key it was made with, using the key's algorithm
+func (h *VerificationHandler) verifySignature(ctx context.Context, agent *domain.Agent, req VerificationRequest) (err error) {
+	_, span := tracing.Start(ctx, "VerificationHandler.verifySignature",
+		tracing.String("aim.agent_id", agent.ID.String()))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	// Recreate the signature message (same as SDK)
 	// MUST use same approach as Python SDK: json.dumps(sort_keys=True)
 
@@ -482,6 +532,12 @@ func (h *VerificationHandler) verifySignature(req VerificationRequest) error {
 	}
 	signaturePayload["timestamp"] = req.Timestamp
 
+	// The MCP tool fields are newer than the SDK signature format, so they are only signed when set
+	if req.ToolName != "" {
+		signaturePayload["mcp_server"] = req.MCPServer
+		signaturePayload["tool_name"] = req.ToolName
+	}
+
 	// DEBUG: risk_level is NEVER sent as separate field by SDK - it's inside context
 	// Don't include it in signature payload unless SDK changes
 	// if req.RiskLevel != "" {
@@ -522,26 +578,18 @@ func (h *VerificationHandler) verifySignature(req VerificationRequest) error {
 	messageStr := customJSONFormat(string(messageBytes))
 	messageBytes = []byte(messageStr)
 
-	// Decode public key
-	publicKeyBytes, err := base64.StdEncoding.DecodeString(req.PublicKey)
-	if err != nil {
-		return fmt.Errorf("invalid public key encoding: %w", err)
-	}
-
-	if len(publicKeyBytes) != ed25519.PublicKeySize {
-		return fmt.Errorf("invalid public key size: expected %d, got %d", ed25519.PublicKeySize, len(publicKeyBytes))
-	}
-
 	// Decode signature
 	signatureBytes, err := base64.StdEncoding.DecodeString(req.Signature)
 	if err != nil {
 		return fmt.Errorf("invalid signature encoding: %w", err)
 	}
 
-	// Verify signature
-	publicKey := ed25519.PublicKey(publicKeyBytes)
-	if !ed25519.Verify(publicKey, messageBytes, signatureBytes) {
-		return fmt.Errorf("signature verification failed")
+	// Verify signature (Ed25519, ECDSA P-256 or RSA-PSS, as the key was registered)
+	if _, err := h.agentService.VerifyAgentSignature(ctx, agent, req.PublicKey, messageBytes, signatureBytes); err != nil {
+		if errors.Is(err, crypto.ErrInvalidSignature) {
+			return fmt.Errorf("signature verification failed")
+		}
+		return err
 	}
 
 	return nil
@@ -757,7 +805,7 @@ func (h *VerificationHandler) GetVerification(c fiber.Ctx) error {
 	}
 
 	// Query verification event from database
-	event, err := h.verificationEventService.GetVerificationEvent(c.Context(), vid)
+	event, err := h.verificationEventService.GetVerificationEvent(c.UserContext(), vid)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Verification not found or expired",
@@ -776,7 +824,11 @@ func (h *VerificationHandler) GetVerification(c fiber.Ctx) error {
 		case domain.VerificationResultVerified:
 			response.Status = "approved"
 			response.ApprovedBy = "system"
-			response.ExpiresAt = event.CreatedAt.Add(24 * time.Hour)
+			var agent *domain.Agent
+			if event.AgentID != nil {
+				agent, _ = h.agentService.GetAgent(c.UserContext(), *event.AgentID)
+			}
+			response.ExpiresAt = event.CreatedAt.Add(h.approvalValidity(c, agent))
 		case domain.VerificationResultDenied:
 			response.Status = "denied"
 			if event.ErrorReason != nil {
@@ -859,7 +911,7 @@ func (h *VerificationHandler) SubmitVerificationResult(c fiber.Ctx) error {
 	}
 
 	// Update verification event in database
-	err = h.verificationEventService.UpdateVerificationResult(c.Context(), vid, result, reasonPtr, req.Metadata)
+	err = h.verificationEventService.UpdateVerificationResult(c.UserContext(), vid, result, reasonPtr, req.Metadata)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Verification not found or update failed",
@@ -1073,8 +1125,13 @@ func (h *VerificationHandler) ListPendingVerifications(c fiber.Ctx) error {
 		Limit:       pageSize,
 		Offset:      (page - 1) * pageSize,
 	}
+	if _, err := parseInitiatorFilters(c, &params); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 
-	events, total, counts, err := h.verificationEventService.SearchVerifications(c.Context(), orgID, params)
+	events, total, counts, err := h.verificationEventService.SearchVerifications(c.UserContext(), orgID, params)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to get verification requests: %v", err),
@@ -1089,11 +1146,33 @@ func (h *VerificationHandler) ListPendingVerifications(c fiber.Ctx) error {
 		}
 	}
 
+	// Agents are loaded in one query to name requests that have no initiator name
+	var agentIDs []uuid.UUID
+	for _, event := range events {
+		if event.AgentID != nil && event.InitiatorName == nil {
+			agentIDs = append(agentIDs, *event.AgentID)
+		}
+	}
+	agentNames := make(map[uuid.UUID]string, len(agentIDs))
+	if len(agentIDs) > 0 {
+		agents, err := h.agentService.GetAgentsByIDs(c.UserContext(), orgID, agentIDs)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": fmt.Sprintf("Failed to get verification requests: %v", err),
+			})
+		}
+		for _, agent := range agents {
+			agentNames[agent.ID] = agent.DisplayName
+		}
+	}
+
 	var responseItems []PendingVerificationResponse
 	for _, event := range events {
 		agentName := ""
 		if event.InitiatorName != nil {
 			agentName = *event.InitiatorName
+		} else if event.AgentID != nil {
+			agentName = agentNames[*event.AgentID]
 		}
 
 		actionType := ""
@@ -1106,19 +1185,6 @@ func (h *VerificationHandler) ListPendingVerifications(c fiber.Ctx) error {
 			resource = *event.ResourceType
 		}
 
-		riskLevel := "medium"
-		if event.Metadata != nil {
-			if rl, ok := event.Metadata["risk_level"].(string); ok {
-				riskLevel = rl
-			}
-			// Also check inside context
-			if ctx, ok := event.Metadata["context"].(map[string]interface{}); ok {
-				if rl, ok := ctx["risk_level"].(string); ok {
-					riskLevel = rl
-				}
-			}
-		}
-
 		agentIDStr := ""
 		if event.AgentID != nil {
 			agentIDStr = event.AgentID.String()
@@ -1131,7 +1197,7 @@ func (h *VerificationHandler) ListPendingVerifications(c fiber.Ctx) error {
 			ActionType:  actionType,
 			Resource:    resource,
 			Context:     event.Metadata,
-			RiskLevel:   riskLevel,
+			RiskLevel:   string(event.RiskLevel), // Assessed when the event was recorded
 			TrustScore:  event.TrustScore,
 			Status:      normalizeVerificationStatus(event.Status),
 			RequestedAt: event.CreatedAt,
@@ -1211,7 +1277,7 @@ func (h *VerificationHandler) ApproveVerification(c fiber.Ctx) error {
 		"manual_approval": true,
 	}
 
-	err = h.verificationEventService.UpdateVerificationResult(c.Context(), vid, result, nil, metadata)
+	err = h.verificationEventService.UpdateVerificationResult(c.UserContext(), vid, result, nil, metadata)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Verification not found or update failed",
@@ -1236,7 +1302,7 @@ func (h *VerificationHandler) ApproveVerification(c fiber.Ctx) error {
 		},
 		Timestamp: time.Now(),
 	}
-	_ = h.auditService.Log(c.Context(), auditEntry)
+	_ = h.auditService.Log(c.UserContext(), auditEntry)
 
 	fmt.Printf("✅ Verification %s APPROVED by %s\n", vid.String(), userName)
 
@@ -1314,7 +1380,7 @@ func (h *VerificationHandler) DenyVerification(c fiber.Ctx) error {
 		"manual_denial": true,
 	}
 
-	err = h.verificationEventService.UpdateVerificationResult(c.Context(), vid, result, &req.Reason, metadata)
+	err = h.verificationEventService.UpdateVerificationResult(c.UserContext(), vid, result, &req.Reason, metadata)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Verification not found or update failed",
@@ -1339,7 +1405,7 @@ func (h *VerificationHandler) DenyVerification(c fiber.Ctx) error {
 		},
 		Timestamp: time.Now(),
 	}
-	_ = h.auditService.Log(c.Context(), auditEntry)
+	_ = h.auditService.Log(c.UserContext(), auditEntry)
 
 	fmt.Printf("❌ Verification %s DENIED by %s: %s\n", vid.String(), userName, req.Reason)
 
@@ -1352,3 +1418,74 @@ func (h *VerificationHandler) DenyVerification(c fiber.Ctx) error {
 		"message":       "Verification denied - agent action blocked",
 	})
 }
+
+// BulkDecideVerifications approves or denies several pending verifications at once
+// @Summary Bulk approve or deny verifications
+// @Description Apply one decision (verified or denied) to up to 100 pending verifications in a single transaction. If any verification is missing or no longer pending, nothing is applied and the per-item errors are returned with 409.
+// @Tags admin,verifications
+// @Accept json
+// @Produce json
+// @Param request body application.BulkVerificationDecisionRequest true "Verification IDs and decision"
+// @Success 200 {object} application.BulkVerificationDecisionResult "All verifications decided"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 409 {object} application.BulkVerificationDecisionResult "Nothing applied; see per-item errors"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/verifications/bulk-decision [post]
+func (h *VerificationHandler) BulkDecideVerifications(c fiber.Ctx) error {
+	orgID, _ := c.Locals("organization_id").(uuid.UUID)
+	userID, _ := c.Locals("user_id").(uuid.UUID)
+	userName := "admin"
+	if name, ok := c.Locals("user_name").(string); ok {
+		userName = name
+	}
+
+	var req application.BulkVerificationDecisionRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	result, err := h.verificationEventService.BulkDecideVerifications(c.UserContext(), orgID, userID, userName, &req)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidBulkDecision) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to apply bulk verification decision",
+		})
+	}
+
+	if !result.Applied {
+		return c.Status(fiber.StatusConflict).JSON(result)
+	}
+
+	action := "approve_verification"
+	if result.Result == domain.VerificationResultDenied {
+		action = "deny_verification"
+	}
+	for _, item := range result.Items {
+		h.auditService.LogAction(
+			c.UserContext(),
+			orgID,
+			userID,
+			domain.AuditActionUpdate,
+			"verification",
+			item.EventID,
+			c.IP(),
+			c.Get("User-Agent"),
+			map[string]interface{}{
+				"action":          action,
+				"verification_id": item.EventID.String(),
+				"reason":          req.Reason,
+				"bulk_decision":   true,
+			},
+		)
+	}
+
+	fmt.Printf("✅ %d verifications %s in bulk by %s\n", len(result.Items), result.Result, userName)
+
+	return c.JSON(result)
+}
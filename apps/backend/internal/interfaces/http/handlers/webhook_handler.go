@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/gofiber/fiber/v3"
@@ -26,7 +27,10 @@ func NewWebhookHandler(
 
 // CreateWebhook creates a new webhook subscription
 // @Summary Create webhook
-// @Description Create a new webhook subscription for event notifications
+// @Description Create a new webhook subscription for event notifications. An optional CEL filter
+// @Description (e.g. event.driftDetected && agent.tags.contains("prod")) limits deliveries to matching events.
+// @Description An optional template (template_type "go_template" or "jq") reshapes the {event, timestamp, data}
+// @Description payload into the consumer's format, e.g. Slack blocks or a Jira issue.
 // @Tags webhooks
 // @Accept json
 // @Produce json
@@ -45,7 +49,12 @@ func (h *WebhookHandler) CreateWebhook(c fiber.Ctx) error {
 		})
 	}
 
-	webhook, err := h.webhookService.CreateWebhook(c.Context(), &req, orgID, userID)
+	webhook, err := h.webhookService.CreateWebhook(c.UserContext(), &req, orgID, userID)
+	if isWebhookValidationError(err) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -54,7 +63,7 @@ func (h *WebhookHandler) CreateWebhook(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionCreate,
@@ -63,14 +72,25 @@ func (h *WebhookHandler) CreateWebhook(c fiber.Ctx) error {
 		c.IP(),
 		c.Get("User-Agent"),
 		map[string]interface{}{
-			"webhook_name": webhook.Name,
-			"webhook_url":  webhook.URL,
+			"webhook_name":  webhook.Name,
+			"webhook_url":   webhook.URL,
+			"filter":        webhook.Filter,
+			"template_type": webhook.TemplateType,
+			"require_mtls":  webhook.RequireMTLS,
 		},
 	)
 
 	return c.Status(fiber.StatusCreated).JSON(webhook)
 }
 
+// isWebhookValidationError reports whether a webhook create or update failed on invalid input
+func isWebhookValidationError(err error) bool {
+	return errors.Is(err, application.ErrInvalidWebhookFilter) ||
+		errors.Is(err, application.ErrInvalidWebhookTemplate) ||
+		errors.Is(err, application.ErrInvalidWebhookEgress) ||
+		errors.Is(err, application.ErrWebhookMTLSNotConfigured)
+}
+
 // ListWebhooks lists all webhooks for the organization
 // @Summary List webhooks
 // @Description Get all webhook subscriptions for the organization
@@ -81,7 +101,7 @@ func (h *WebhookHandler) CreateWebhook(c fiber.Ctx) error {
 func (h *WebhookHandler) ListWebhooks(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 
-	webhooks, err := h.webhookService.ListWebhooks(c.Context(), orgID)
+	webhooks, err := h.webhookService.ListWebhooks(c.UserContext(), orgID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch webhooks",
@@ -117,7 +137,7 @@ func (h *WebhookHandler) GetWebhook(c fiber.Ctx) error {
 		})
 	}
 
-	webhook, err := h.webhookService.GetWebhook(c.Context(), webhookID)
+	webhook, err := h.webhookService.GetWebhook(c.UserContext(), webhookID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Webhook not found",
@@ -153,7 +173,7 @@ func (h *WebhookHandler) DeleteWebhook(c fiber.Ctx) error {
 	}
 
 	// Verify webhook belongs to organization
-	webhook, err := h.webhookService.GetWebhook(c.Context(), webhookID)
+	webhook, err := h.webhookService.GetWebhook(c.UserContext(), webhookID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Webhook not found",
@@ -165,7 +185,7 @@ func (h *WebhookHandler) DeleteWebhook(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.webhookService.DeleteWebhook(c.Context(), webhookID); err != nil {
+	if err := h.webhookService.DeleteWebhook(c.UserContext(), webhookID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -173,7 +193,7 @@ func (h *WebhookHandler) DeleteWebhook(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionDelete,
@@ -209,7 +229,7 @@ func (h *WebhookHandler) UpdateWebhook(c fiber.Ctx) error {
 	}
 
 	// Verify webhook belongs to organization
-	existingWebhook, err := h.webhookService.GetWebhook(c.Context(), webhookID)
+	existingWebhook, err := h.webhookService.GetWebhook(c.UserContext(), webhookID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Webhook not found",
@@ -229,7 +249,12 @@ func (h *WebhookHandler) UpdateWebhook(c fiber.Ctx) error {
 	}
 
 	// Update webhook
-	webhook, err := h.webhookService.UpdateWebhook(c.Context(), webhookID, &req)
+	webhook, err := h.webhookService.UpdateWebhook(c.UserContext(), webhookID, &req)
+	if isWebhookValidationError(err) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -238,7 +263,7 @@ func (h *WebhookHandler) UpdateWebhook(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionUpdate,
@@ -248,7 +273,8 @@ func (h *WebhookHandler) UpdateWebhook(c fiber.Ctx) error {
 		c.Get("User-Agent"),
 		map[string]interface{}{
 			"webhook_name": webhook.Name,
-			"isActive":    webhook.IsActive,
+			"isActive":     webhook.IsActive,
+			"require_mtls": webhook.RequireMTLS,
 		},
 	)
 
@@ -275,7 +301,7 @@ func (h *WebhookHandler) TestWebhook(c fiber.Ctx) error {
 	}
 
 	// Verify webhook belongs to organization
-	webhook, err := h.webhookService.GetWebhook(c.Context(), webhookID)
+	webhook, err := h.webhookService.GetWebhook(c.UserContext(), webhookID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Webhook not found",
@@ -288,7 +314,7 @@ func (h *WebhookHandler) TestWebhook(c fiber.Ctx) error {
 	}
 
 	// Send test payload
-	result, err := h.webhookService.TestWebhook(c.Context(), webhookID)
+	result, err := h.webhookService.TestWebhook(c.UserContext(), webhookID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   "Failed to send test payload",
@@ -298,7 +324,7 @@ func (h *WebhookHandler) TestWebhook(c fiber.Ctx) error {
 
 	// Log audit
 	h.auditService.LogAction(
-		c.Context(),
+		c.UserContext(),
 		orgID,
 		userID,
 		domain.AuditActionUpdate,
@@ -330,3 +356,81 @@ func (h *WebhookHandler) TestWebhook(c fiber.Ctx) error {
 		},
 	})
 }
+
+// GetEgressSettings returns how the organization's webhooks reach receivers
+// @Summary Get webhook egress settings
+// @Description Get the source IP addresses to allowlist for the organization's webhook deliveries and the
+// @Description client certificate presented to receivers that require mTLS. The private key is never returned.
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} application.WebhookEgress
+// @Router /api/v1/webhooks/egress [get]
+func (h *WebhookHandler) GetEgressSettings(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	egress, err := h.webhookService.GetWebhookEgress(c.UserContext(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch webhook egress settings",
+		})
+	}
+
+	return c.JSON(egress)
+}
+
+// UpdateEgressSettings changes the organization's webhook client certificate and egress proxy
+// @Summary Update webhook egress settings
+// @Description Upload the client certificate and key presented to receivers that require mTLS, and optionally
+// @Description route deliveries through a dedicated egress proxy whose source addresses are published to receivers.
+// @Description Omitted fields are unchanged; an empty clientCertificate removes the certificate.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body application.UpdateWebhookEgressRequest true "Egress settings"
+// @Success 200 {object} application.WebhookEgress
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/webhooks/egress [put]
+func (h *WebhookHandler) UpdateEgressSettings(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req application.UpdateWebhookEgressRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	egress, err := h.webhookService.UpdateWebhookEgress(c.UserContext(), orgID, userID, &req)
+	if errors.Is(err, application.ErrInvalidWebhookEgress) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update webhook egress settings",
+		})
+	}
+
+	details := map[string]interface{}{
+		"dedicated":  egress.Dedicated,
+		"egress_ips": egress.EgressIPs,
+	}
+	if egress.ClientCertificate != nil {
+		details["certificate_fingerprint"] = egress.ClientCertificate.FingerprintSHA256
+	}
+	h.auditService.LogAction(
+		c.UserContext(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"webhook_egress_settings",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		details,
+	)
+
+	return c.JSON(egress)
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/url"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// AgentCertificateMiddleware authenticates agents by the TLS client certificate the platform CA
+// issued them, as an alternative to API keys and signed requests. The certificate comes from the
+// TLS handshake or, behind a TLS-terminating proxy, from clientCertHeader: the URL-encoded PEM
+// certificate the proxy verified. Only set clientCertHeader when the proxy overwrites the header
+// on every request, as anyone could send it otherwise.
+//
+// Requests without a certificate, and requests already authenticated by an API key or carrying
+// an Authorization header, pass through for the other middlewares. A certificate that does not
+// authenticate an agent is rejected.
+func AgentCertificateMiddleware(certificates *application.AgentCertificateService, clientCertHeader string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if c.Locals("authenticated_via") != nil || c.Get("Authorization") != "" {
+			return c.Next()
+		}
+
+		certificate, err := clientCertificate(c, clientCertHeader)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid client certificate",
+			})
+		}
+		if certificate == nil {
+			return c.Next()
+		}
+
+		agent, issued, err := certificates.AuthenticateClientCertificate(c.UserContext(), certificate)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		c.Locals("agent_id", agent.ID)
+		c.Locals("organization_id", agent.OrganizationID)
+		c.Locals("user_id", agent.CreatedBy) // Acts for the agent's owner, like the owner's API keys
+		c.Locals("agent_certificate_serial", issued.SerialNumber)
+		c.Locals("auth_method", "mtls")
+		c.Locals("authenticated_via", "mtls")
+		recordCaller(c, domain.AuthMethodMTLS)
+
+		return c.Next()
+	}
+}
+
+// clientCertificate returns the client certificate of the request; nil when there is none
+func clientCertificate(c fiber.Ctx, clientCertHeader string) (*x509.Certificate, error) {
+	if state := c.Context().TLSConnectionState(); state != nil && len(state.PeerCertificates) > 0 {
+		return state.PeerCertificates[0], nil
+	}
+	if clientCertHeader == "" {
+		return nil, nil
+	}
+
+	value := c.Get(clientCertHeader)
+	if value == "" {
+		return nil, nil
+	}
+	decoded, err := url.QueryUnescape(value)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode([]byte(decoded))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("client certificate is not PEM-encoded")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
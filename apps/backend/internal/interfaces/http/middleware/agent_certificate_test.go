@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentsAuthenticateWithTheirCertificatesAndEventsRecordTheAuthMethod(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	ctx := context.Background()
+
+	keyPair, err := crypto.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	publicKey := crypto.EncodeKeyPair(keyPair).PublicKeyBase64
+	agent := testsupport.NewAgent(org.ID, func(a *domain.Agent) {
+		a.Status = domain.AgentStatusVerified
+		a.PublicKey = &publicKey
+	})
+	require.NoError(t, repos.Agent.Create(agent))
+
+	ca, err := crypto.NewAgentCA(make([]byte, 32))
+	require.NoError(t, err)
+	certificates := application.NewAgentCertificateService(repos.AgentCertificate, repos.Agent, ca, time.Hour, "")
+	events := application.NewVerificationEventService(repos.VerificationEvent, repos.Agent, nil, nil, nil, nil)
+	issued, err := certificates.Issue(ctx, agent)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(AgentCertificateMiddleware(certificates, "X-Client-Cert"))
+	app.Post("/api/v1/verifications", func(c fiber.Ctx) error {
+		agentID, ok := c.Locals("agent_id").(uuid.UUID)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).SendString("no agent")
+		}
+		event, err := events.LogVerificationEvent(c.UserContext(), c.Locals("organization_id").(uuid.UUID), agentID,
+			domain.VerificationProtocolMCP, domain.VerificationTypeCapability, domain.VerificationEventStatusSuccess, 5, domain.InitiatorTypeAgent, nil, nil)
+		if err != nil {
+			return err
+		}
+		return c.SendString(event.AuthMethod + " " + c.Locals("user_id").(uuid.UUID).String())
+	})
+	call := func(certificatePEM, authorization string) (int, string) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/verifications", nil)
+		if certificatePEM != "" {
+			req.Header.Set("X-Client-Cert", url.QueryEscape(certificatePEM))
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := app.Test(req, 5*time.Second)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	// The certificate authenticates the agent, acting for its owner, and the event records mTLS
+	status, body := call(issued.CertificatePEM, "")
+	require.Equal(t, fiber.StatusOK, status, body)
+	assert.Equal(t, domain.AuthMethodMTLS+" "+agent.CreatedBy.String(), body)
+	recorded, total, err := repos.VerificationEvent.GetByAgent(agent.ID, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	assert.Equal(t, domain.AuthMethodMTLS, recorded[0].AuthMethod)
+
+	// Requests without a certificate, or with credentials of their own, are left to the other middlewares
+	status, _ = call("", "")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	status, _ = call(issued.CertificatePEM, "Bearer user-session")
+	assert.Equal(t, fiber.StatusUnauthorized, status, "the certificate is ignored next to an Authorization header")
+	status, _ = call("not a certificate", "")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	// Certificates of another CA are rejected even when they name the agent
+	rogueCA, err := crypto.NewAgentCA(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	rogue, err := application.NewAgentCertificateService(testsupport.NewRepositories().AgentCertificate, repos.Agent, rogueCA, time.Hour, "").Issue(ctx, agent)
+	require.NoError(t, err)
+	status, _ = call(rogue.CertificatePEM, "")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	// A renewal supersedes the certificate, which stays usable until it expires
+	renewed, err := certificates.Issue(ctx, agent)
+	require.NoError(t, err)
+	status, _ = call(issued.CertificatePEM, "")
+	assert.Equal(t, fiber.StatusOK, status)
+
+	// Certificates must carry the agent's registered key, so rotating the key retires them
+	rotated, err := crypto.GenerateEd25519KeyPair()
+	require.NoError(t, err)
+	rotatedKey := crypto.EncodeKeyPair(rotated).PublicKeyBase64
+	agent.PublicKey = &rotatedKey
+	require.NoError(t, repos.Agent.Update(agent))
+	leaf, _ := pem.Decode([]byte(renewed.CertificatePEM))
+	require.NotNil(t, leaf)
+	certificate, err := x509.ParseCertificate(leaf.Bytes)
+	require.NoError(t, err)
+	_, _, err = certificates.AuthenticateClientCertificate(ctx, certificate)
+	assert.ErrorIs(t, err, application.ErrClientCertificateRejected)
+	agent.PublicKey = &publicKey
+	require.NoError(t, repos.Agent.Update(agent))
+	authenticated, current, err := certificates.AuthenticateClientCertificate(ctx, certificate)
+	require.NoError(t, err)
+	assert.Equal(t, agent.ID, authenticated.ID)
+	assert.Equal(t, renewed.SerialNumber, current.SerialNumber)
+
+	// Revoked certificates are rejected
+	_, err = certificates.Revoke(ctx, agent.ID, domain.CertificateRevocationKeyCompromise)
+	require.NoError(t, err)
+	status, body = call(renewed.CertificatePEM, "")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Contains(t, body, "revoked")
+
+	// Events recorded outside a request carry no auth method
+	event, err := events.LogVerificationEvent(ctx, org.ID, agent.ID, domain.VerificationProtocolMCP, domain.VerificationTypeCapability,
+		domain.VerificationEventStatusSuccess, 5, domain.InitiatorTypeSystem, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, event.AuthMethod)
+	assert.Equal(t, domain.AuthMethodAPIKey, domain.AuthMethodFromContext(domain.WithAuthMethod(ctx, domain.AuthMethodAPIKey)))
+}
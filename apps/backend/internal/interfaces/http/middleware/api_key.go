@@ -1,19 +1,21 @@
 package middleware
 
 import (
-	"crypto/sha256"
-	"database/sql"
-	"encoding/base64"
+	"errors"
 	"strings"
-	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
 )
 
+// apiKeyPrefix starts every key generated by APIKeyService
+const apiKeyPrefix = "aim_"
+
 // APIKeyMiddleware validates API keys from Authorization header or X-API-Key header
 // Used for SDK authentication and direct API calls
-func APIKeyMiddleware(db *sql.DB) fiber.Handler {
+func APIKeyMiddleware(apiKeys *application.APIKeyService) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		path := c.Path()
 		println("DEBUG: APIKeyMiddleware checking path:", path)
@@ -30,12 +32,10 @@ func APIKeyMiddleware(db *sql.DB) fiber.Handler {
 
 		// Try Authorization header first (Bearer token format)
 		authHeader := c.Get("Authorization")
-		println("DEBUG: Authorization header:", authHeader)
 		if authHeader != "" {
 			parts := strings.Split(authHeader, " ")
 			if len(parts) == 2 && parts[0] == "Bearer" {
 				apiKey = parts[1]
-				println("DEBUG: Extracted API key:", apiKey[:10]+"...")
 			}
 		}
 
@@ -50,84 +50,36 @@ func APIKeyMiddleware(db *sql.DB) fiber.Handler {
 			})
 		}
 
-		// Hash the API key
-		hash := sha256.Sum256([]byte(apiKey))
-		keyHash := base64.StdEncoding.EncodeToString(hash[:])
-
-	// Look up API key in database and get the user who created it
-	var keyData struct {
-		ID             uuid.UUID  `db:"id"`
-		OrganizationID uuid.UUID  `db:"organization_id"`
-		AgentID        uuid.UUID  `db:"agent_id"`
-		UserID         uuid.UUID  `db:"user_id"`
-		Name           string     `db:"name"`
-		IsActive       bool       `db:"is_active"`
-		ExpiresAt      *time.Time `db:"expires_at"`
-	}
-
-	query := `
-		SELECT ak.id, ak.organization_id, ak.agent_id, ak.created_by as user_id, ak.name, ak.is_active, ak.expires_at
-		FROM api_keys ak
-		WHERE ak.key_hash = $1
-		LIMIT 1
-	`
-
-	err := db.QueryRow(query, keyHash).Scan(
-		&keyData.ID,
-		&keyData.OrganizationID,
-		&keyData.AgentID,
-		&keyData.UserID,
-		&keyData.Name,
-		&keyData.IsActive,
-		&keyData.ExpiresAt,
-	)
-
-		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid API key",
-			})
-		}
-
-		// Check if key is active
-		if !keyData.IsActive {
+		// Revoked keys are not found; the service updates last_used_at
+		key, err := apiKeys.ValidateAPIKey(c.Context(), apiKey)
+		if errors.Is(err, application.ErrAPIKeyExpired) {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "API key is inactive",
+				"error": "API key has expired",
 			})
 		}
-
-		// Check if key is expired
-		if keyData.ExpiresAt != nil && keyData.ExpiresAt.Before(time.Now()) {
+		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "API key has expired",
+				"error": "Invalid API key",
 			})
 		}
 
-		// Update last_used_at timestamp
-		updateQuery := `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`
-		_, _ = db.Exec(updateQuery, keyData.ID)
-
-	// Set context for downstream handlers
-	c.Locals("api_key_id", keyData.ID)
-	c.Locals("organization_id", keyData.OrganizationID)
-	c.Locals("agent_id", keyData.AgentID)
-	c.Locals("user_id", keyData.UserID) // ✅ Set user_id for capability requests
-	c.Locals("auth_method", "api_key")
-
-	return c.Next()
+		setAPIKeyContext(c, key)
+		return c.Next()
 	}
 }
 
 // OptionalAPIKeyMiddleware is like APIKeyMiddleware but doesn't fail if no API key
 // Useful for endpoints that work both authenticated and unauthenticated
-func OptionalAPIKeyMiddleware(db *sql.DB) fiber.Handler {
+func OptionalAPIKeyMiddleware(apiKeys *application.APIKeyService) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		var apiKey string
 
-		// Try Authorization header first
+		// Try Authorization header first; only "aim_" bearer tokens are API keys,
+		// anything else (JWTs) is left to the JWT middleware
 		authHeader := c.Get("Authorization")
 		if authHeader != "" {
 			parts := strings.Split(authHeader, " ")
-			if len(parts) == 2 && parts[0] == "Bearer" {
+			if len(parts) == 2 && parts[0] == "Bearer" && strings.HasPrefix(parts[1], apiKeyPrefix) {
 				apiKey = parts[1]
 			}
 		}
@@ -142,59 +94,115 @@ func OptionalAPIKeyMiddleware(db *sql.DB) fiber.Handler {
 			return c.Next()
 		}
 
-		// Hash the API key
-		hash := sha256.Sum256([]byte(apiKey))
-		keyHash := base64.StdEncoding.EncodeToString(hash[:])
-
-	// Look up API key
-	var keyData struct {
-		ID             uuid.UUID  `db:"id"`
-		OrganizationID uuid.UUID  `db:"organization_id"`
-		AgentID        uuid.UUID  `db:"agent_id"`
-		UserID         uuid.UUID  `db:"user_id"`
-		Name           string     `db:"name"`
-		IsActive       bool       `db:"is_active"`
-		ExpiresAt      *time.Time `db:"expires_at"`
-	}
-
-	query := `
-		SELECT ak.id, ak.organization_id, ak.agent_id, ak.created_by as user_id, ak.name, ak.is_active, ak.expires_at
-		FROM api_keys ak
-		WHERE ak.key_hash = $1
-		LIMIT 1
-	`
-
-	err := db.QueryRow(query, keyHash).Scan(
-		&keyData.ID,
-		&keyData.OrganizationID,
-		&keyData.AgentID,
-		&keyData.UserID,
-		&keyData.Name,
-		&keyData.IsActive,
-		&keyData.ExpiresAt,
-	)
-
-		// If key not found or invalid, continue without auth
+		// If key not found, revoked or expired, continue without auth
+		key, err := apiKeys.ValidateAPIKey(c.Context(), apiKey)
 		if err != nil {
 			return c.Next()
 		}
 
-		// Check if key is active and not expired
-		if !keyData.IsActive || (keyData.ExpiresAt != nil && keyData.ExpiresAt.Before(time.Now())) {
+		setAPIKeyContext(c, key)
+		return c.Next()
+	}
+}
+
+// setAPIKeyContext sets the context of a request authenticated with the key for downstream handlers
+func setAPIKeyContext(c fiber.Ctx, key *domain.APIKey) {
+	c.Locals("api_key_id", key.ID)
+	c.Locals("organization_id", key.OrganizationID)
+	c.Locals("agent_id", key.AgentID)
+	c.Locals("user_id", key.CreatedBy) // ✅ Set user_id for capability requests
+	c.Locals("auth_method", "api_key")
+	c.Locals("authenticated_via", "api_key")
+	recordCaller(c, domain.AuthMethodAPIKey)
+	c.Locals("api_key_scopes", key.Scopes) // ✅ Checked by RequireAPIKeyScope
+	// No role: a key is not its creator and can't act with the creator's permissions
+}
+
+// RequireAPIKeyScope rejects requests authenticated with an API key that does not carry scope.
+// Requests authenticated another way (JWT, Ed25519) pass through unchanged.
+func RequireAPIKeyScope(scope string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		return checkAPIKeyScope(c, scope)
+	}
+}
+
+// apiKeyRoutes lists the routes of the agent and verification groups API keys can be used on,
+// with the scope each needs. A ":id" segment matches a UUID. The groups' other routes, which
+// manage the organization, can't be used with API keys.
+var apiKeyRoutes = []struct {
+	method string
+	path   string
+	scope  string
+}{
+	{fiber.MethodGet, "/api/v1/agents", domain.APIKeyScopeAgentsRead},
+	{fiber.MethodGet, "/api/v1/agents/:id", domain.APIKeyScopeAgentsRead},
+	{fiber.MethodPost, "/api/v1/verifications", domain.APIKeyScopeVerifyCreate},
+	{fiber.MethodGet, "/api/v1/verifications/stream", domain.APIKeyScopeVerifyRead},
+	{fiber.MethodGet, "/api/v1/verifications/:id", domain.APIKeyScopeVerifyRead},
+	{fiber.MethodPost, "/api/v1/verifications/:id/result", domain.APIKeyScopeVerifyCreate},
+}
+
+// APIKeyScopeFor returns the scope an API key needs for a request. It reports false for routes
+// API keys can't be used on. Paths are matched case-insensitively, as the router does.
+func APIKeyScopeFor(method, path string) (string, bool) {
+	segments := strings.Split(strings.TrimSuffix(strings.ToLower(path), "/"), "/")
+	for _, route := range apiKeyRoutes {
+		if method != route.method && !(method == fiber.MethodHead && route.method == fiber.MethodGet) {
+			continue
+		}
+		if matchAPIKeyRoute(strings.Split(route.path, "/"), segments) {
+			return route.scope, true
+		}
+	}
+	return "", false
+}
+
+func matchAPIKeyRoute(pattern, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+	for i, segment := range pattern {
+		if segment == ":id" {
+			if _, err := uuid.Parse(segments[i]); err != nil {
+				return false
+			}
+		} else if segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RequireAPIKeyRoute rejects requests authenticated with an API key unless apiKeyRoutes lists
+// the route and the key carries its scope. Requests authenticated another way pass through.
+func RequireAPIKeyRoute() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if c.Locals("auth_method") != "api_key" {
 			return c.Next()
 		}
 
-		// Update last_used_at
-		updateQuery := `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`
-		_, _ = db.Exec(updateQuery, keyData.ID)
+		scope, usable := APIKeyScopeFor(c.Method(), c.Path())
+		if !usable {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "API keys cannot be used for this endpoint",
+			})
+		}
+		return checkAPIKeyScope(c, scope)
+	}
+}
 
-	// Set context
-	c.Locals("api_key_id", keyData.ID)
-	c.Locals("organization_id", keyData.OrganizationID)
-	c.Locals("agent_id", keyData.AgentID)
-	c.Locals("user_id", keyData.UserID)
-	c.Locals("auth_method", "api_key")
+func checkAPIKeyScope(c fiber.Ctx, scope string) error {
+	if c.Locals("auth_method") != "api_key" {
+		return c.Next()
+	}
 
-	return c.Next()
+	scopes, _ := c.Locals("api_key_scopes").([]string)
+	if !domain.APIKeyScopesAllow(scopes, scope) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":         "API key is not allowed to perform this action",
+			"requiredScope": scope,
+		})
 	}
+
+	return c.Next()
 }
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyScopeFor(t *testing.T) {
+	id := uuid.New().String()
+	cases := []struct {
+		method, path string
+		scope        string
+		usable       bool
+	}{
+		{fiber.MethodGet, "/api/v1/agents", domain.APIKeyScopeAgentsRead, true},
+		{fiber.MethodGet, "/api/v1/agents/", domain.APIKeyScopeAgentsRead, true},
+		{fiber.MethodGet, "/api/v1/agents/" + id, domain.APIKeyScopeAgentsRead, true},
+		{fiber.MethodGet, "/API/v1/Agents/" + id, domain.APIKeyScopeAgentsRead, true},
+		{fiber.MethodHead, "/api/v1/agents/" + id, domain.APIKeyScopeAgentsRead, true},
+		{fiber.MethodPost, "/api/v1/verifications", domain.APIKeyScopeVerifyCreate, true},
+		{fiber.MethodGet, "/api/v1/verifications/stream", domain.APIKeyScopeVerifyRead, true},
+		{fiber.MethodGet, "/api/v1/verifications/" + id, domain.APIKeyScopeVerifyRead, true},
+		{fiber.MethodPost, "/api/v1/verifications/" + id + "/result", domain.APIKeyScopeVerifyCreate, true},
+
+		// Organization management stays with users
+		{fiber.MethodPost, "/api/v1/agents", "", false},
+		{fiber.MethodDelete, "/api/v1/agents/" + id, "", false},
+		{fiber.MethodGet, "/api/v1/agents/peer-policies", "", false},
+		{fiber.MethodPost, "/api/v1/agents/export", "", false},
+		{fiber.MethodPost, "/api/v1/verifications/bulk-decision", "", false},
+		{fiber.MethodGet, "/api/v1/verifications/exports", "", false},
+		{fiber.MethodGet, "/api/v1/users/me", "", false},
+	}
+	for _, tc := range cases {
+		scope, usable := APIKeyScopeFor(tc.method, tc.path)
+		assert.Equal(t, tc.usable, usable, "%s %s", tc.method, tc.path)
+		assert.Equal(t, tc.scope, scope, "%s %s", tc.method, tc.path)
+	}
+}
+
+func TestRequireAPIKeyRoute(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		if scopes := c.Get("X-Test-Scopes"); scopes != "" {
+			c.Locals("auth_method", "api_key")
+			if scopes != "none" {
+				c.Locals("api_key_scopes", []string{scopes})
+			}
+		}
+		return c.Next()
+	})
+	app.Use(RequireAPIKeyRoute())
+	app.All("/*", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	status := func(method, path, scopes string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if scopes != "" {
+			req.Header.Set("X-Test-Scopes", scopes)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	id := uuid.New().String()
+	// Users (JWT) are not affected
+	assert.Equal(t, fiber.StatusOK, status(fiber.MethodDelete, "/api/v1/agents/"+id, ""))
+
+	assert.Equal(t, fiber.StatusOK, status(fiber.MethodGet, "/api/v1/agents/"+id, domain.APIKeyScopeAgentsRead))
+	assert.Equal(t, fiber.StatusForbidden, status(fiber.MethodGet, "/api/v1/agents/"+id, domain.APIKeyScopeVerifyRead))
+	// agents:* doesn't open agent management to keys
+	assert.Equal(t, fiber.StatusForbidden, status(fiber.MethodDelete, "/api/v1/agents/"+id, "agents:*"))
+	assert.Equal(t, fiber.StatusForbidden, status(fiber.MethodPost, "/api/v1/agents", domain.APIKeyScopeAll))
+
+	// Keys without scopes carry the legacy SDK scopes, not full access
+	assert.Equal(t, fiber.StatusOK, status(fiber.MethodPost, "/api/v1/verifications", "none"))
+	assert.Equal(t, fiber.StatusOK, status(fiber.MethodGet, "/api/v1/verifications/"+id, "none"))
+	assert.Equal(t, fiber.StatusForbidden, status(fiber.MethodGet, "/api/v1/agents", "none"))
+}
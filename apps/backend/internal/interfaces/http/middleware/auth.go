@@ -6,6 +6,7 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
 	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
 )
 
@@ -15,9 +16,9 @@ func AuthMiddleware(jwtService *auth.JWTService) fiber.Handler {
 		// Check if already authenticated by Ed25519 middleware
 		authenticatedVia := c.Locals("authenticated_via")
 		fmt.Printf("🔒 JWT middleware: authenticated_via = %v\n", authenticatedVia)
-		if authenticatedVia == "ed25519" {
+		if authenticatedVia == "ed25519" || authenticatedVia == "api_key" || authenticatedVia == "personal_access_token" || authenticatedVia == "mtls" || authenticatedVia == "impersonation" {
 			// Already authenticated - skip JWT validation
-			fmt.Printf("✅ JWT middleware: Skipping JWT - Ed25519 already authenticated\n")
+			fmt.Printf("✅ JWT middleware: Skipping JWT - %v already authenticated\n", authenticatedVia)
 			return c.Next()
 		}
 
@@ -74,6 +75,7 @@ func AuthMiddleware(jwtService *auth.JWTService) fiber.Handler {
 		c.Locals("organization_id", organizationID)
 		c.Locals("email", claims.Email)
 		c.Locals("role", claims.Role)
+		recordCaller(c, domain.AuthMethodJWT)
 
 		return c.Next()
 	}
@@ -125,3 +127,25 @@ func OptionalAuthMiddleware(jwtService *auth.JWTService) fiber.Handler {
 		return c.Next()
 	}
 }
+
+// recordCaller records how the caller authenticated, and the user, agent and API key behind the
+// request, on the request context for the verification events recorded downstream. Call it once
+// the credential's locals are set.
+func recordCaller(c fiber.Ctx, authMethod string) {
+	caller := domain.Caller{IP: c.IP(), UserAgent: c.Get("User-Agent")}
+	if id, ok := c.Locals("user_id").(uuid.UUID); ok && id != uuid.Nil {
+		caller.UserID = &id
+	}
+	if id, ok := c.Locals("agent_id").(uuid.UUID); ok && id != uuid.Nil {
+		caller.AgentID = &id
+	}
+	if id, ok := c.Locals("api_key_id").(uuid.UUID); ok && id != uuid.Nil {
+		caller.APIKeyID = &id
+	}
+	if id, ok := c.Locals("impersonation_id").(uuid.UUID); ok && id != uuid.Nil {
+		caller.ImpersonationID = &id
+	}
+
+	ctx := domain.WithAuthMethod(c.UserContext(), authMethod)
+	c.SetUserContext(domain.WithCaller(ctx, caller))
+}
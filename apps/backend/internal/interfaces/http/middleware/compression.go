@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/compress"
+)
+
+// CompressionMiddleware compresses responses with brotli or gzip, whichever the client accepts.
+// Level is default, best_speed, best_compression or disabled. Small bodies and content types
+// that don't compress (images, archives) are sent as they are; live tails and event streams are
+// never compressed so events reach the client as they happen.
+func CompressionMiddleware(level string) fiber.Handler {
+	return compress.New(compress.Config{
+		Next: func(c fiber.Ctx) bool {
+			return strings.HasSuffix(c.Path(), "/tail") || strings.HasSuffix(c.Path(), "/stream")
+		},
+		Level: compressionLevel(level),
+	})
+}
+
+func compressionLevel(level string) compress.Level {
+	switch strings.ToLower(level) {
+	case "", "default":
+		return compress.LevelDefault
+	case "best_speed":
+		return compress.LevelBestSpeed
+	case "best_compression":
+		return compress.LevelBestCompression
+	case "disabled", "none", "off":
+		return compress.LevelDisabled
+	default:
+		log.Printf("⚠️  Unknown response compression level %q, using default", level)
+		return compress.LevelDefault
+	}
+}
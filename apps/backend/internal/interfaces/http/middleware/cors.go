@@ -12,7 +12,8 @@ func CORSMiddleware(allowedOrigins []string) fiber.Handler {
 	return cors.New(cors.Config{
 		AllowOrigins:     strings.Join(allowedOrigins, ","),
 		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,Idempotency-Key,traceparent,tracestate",
+		ExposeHeaders:    strings.Join(append(exportSignatureHeaders, "Idempotent-Replayed"), ","), // Lets the web UI save export signatures and spot replays
 		AllowCredentials: true,
 		MaxAge:           3600,
 	})
@@ -1,9 +1,9 @@
 package middleware
 
 import (
-	"crypto/ed25519"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
@@ -14,6 +14,8 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
 )
 
 // sortedJSONMarshal marshals JSON with sorted keys to match Python's json.dumps(sort_keys=True)
@@ -70,12 +72,13 @@ func sortValue(v interface{}) interface{} {
 	}
 }
 
-// Ed25519AgentMiddleware validates Ed25519 signed requests from SDK agents
+// Ed25519AgentMiddleware validates signed requests from SDK agents. Despite the name, signatures
+// verify with the algorithm of the agent's key: Ed25519, ECDSA P-256 or RSA-PSS.
 // This middleware checks for:
 // - X-Agent-ID: Agent UUID
-// - X-Signature: Base64-encoded Ed25519 signature
+// - X-Signature: Base64-encoded signature
 // - X-Timestamp: Unix timestamp of request
-// - X-Public-Key: Agent's Ed25519 public key (base64)
+// - X-Public-Key: Agent's public key (base64)
 func Ed25519AgentMiddleware(agentService *application.AgentService) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		// If Authorization header is present (JWT), skip Ed25519 and let JWT middleware handle it
@@ -85,6 +88,11 @@ func Ed25519AgentMiddleware(agentService *application.AgentService) fiber.Handle
 			return c.Next()
 		}
 
+		// Agents that presented a client certificate are already authenticated
+		if c.Locals("authenticated_via") == "mtls" {
+			return c.Next()
+		}
+
 		// Extract headers
 		agentIDStr := c.Get("X-Agent-ID")
 		signatureB64 := c.Get("X-Signature")
@@ -123,7 +131,7 @@ func Ed25519AgentMiddleware(agentService *application.AgentService) fiber.Handle
 		}
 
 		// Load agent from database
-		agent, err := agentService.GetAgent(c.Context(), agentID)
+		agent, err := agentService.GetAgent(c.UserContext(), agentID)
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Agent not found",
@@ -133,8 +141,12 @@ func Ed25519AgentMiddleware(agentService *application.AgentService) fiber.Handle
 		// Check if agent has a registered public key
 		var verifyPublicKey string
 		if agent.PublicKey != nil && *agent.PublicKey != "" {
-			// Use registered key from database
+			// Use registered key from database; during a key rotation grace period the SDK
+			// may still sign with (and send) the previous key
 			verifyPublicKey = *agent.PublicKey
+			if agent.AcceptsPublicKey(publicKeyB64, time.Now()) {
+				verifyPublicKey = publicKeyB64
+			}
 			fmt.Printf("🔑 Using REGISTERED public key from database (first 20): %s...\n", verifyPublicKey[:20])
 		} else {
 			// Agent hasn't registered a key yet, use the one from request
@@ -144,22 +156,13 @@ func Ed25519AgentMiddleware(agentService *application.AgentService) fiber.Handle
 		}
 		fmt.Printf("🔑 Request sent public key (first 20): %s...\n", publicKeyB64[:20])
 
-		// Decode public key
-		publicKeyBytes, err := base64.StdEncoding.DecodeString(verifyPublicKey)
-		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid public key format",
-			})
-		}
-
-		if len(publicKeyBytes) != ed25519.PublicKeySize {
+		// Keys whose certificate is on the revocation list never authenticate
+		if err := agentService.CheckCertificateRevocation(c.UserContext(), agent, verifyPublicKey); err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": fmt.Sprintf("Invalid public key size: expected %d bytes, got %d", ed25519.PublicKeySize, len(publicKeyBytes)),
+				"error": "Agent certificate revoked or revocation status unavailable",
 			})
 		}
 
-		publicKey := ed25519.PublicKey(publicKeyBytes)
-
 		// Decode signature
 		signatureBytes, err := base64.StdEncoding.DecodeString(signatureB64)
 		if err != nil {
@@ -191,28 +194,42 @@ func Ed25519AgentMiddleware(agentService *application.AgentService) fiber.Handle
 		}
 		fmt.Printf("🔍 Backend verifying message (first 500 chars):\n%s\n", msgPreview)
 
-		// Verify Ed25519 signature
-		if !ed25519.Verify(publicKey, []byte(message), signatureBytes) {
+		// Verify the signature with the key's algorithm (Ed25519, ECDSA P-256 or RSA-PSS)
+		var verifyErr error
+		if agent.PublicKey != nil && *agent.PublicKey != "" {
+			_, verifyErr = agentService.VerifyAgentSignature(c.UserContext(), agent, verifyPublicKey, []byte(message), signatureBytes)
+		} else if algorithm, err := crypto.DetectKeyAlgorithm(verifyPublicKey); err != nil {
+			verifyErr = err
+		} else {
+			verifyErr = crypto.VerifyAlgorithmSignature(algorithm, verifyPublicKey, []byte(message), signatureBytes)
+		}
+		if verifyErr != nil {
 			// Debug logging for signature verification failure
-			fmt.Printf("❌ Ed25519 signature verification FAILED\n")
+			fmt.Printf("❌ Agent signature verification FAILED: %v\n", verifyErr)
 			fmt.Printf("   Agent ID: %s\n", agentID)
 			fmt.Printf("   Timestamp: %s\n", timestampStr)
 			fmt.Printf("   Message to verify:\n%s\n", message)
 			fmt.Printf("   Public key (first 20 chars): %s...\n", verifyPublicKey[:20])
 			fmt.Printf("   Signature (first 20 chars): %s...\n", signatureB64[:20])
 
+			if errors.Is(verifyErr, domain.ErrKeyAlgorithmNotAllowed) {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": verifyErr.Error(),
+				})
+			}
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Invalid signature",
 			})
 		}
 
-		fmt.Printf("✅ Ed25519 signature verification PASSED for agent %s\n", agentID)
+		fmt.Printf("✅ Agent signature verification PASSED for agent %s\n", agentID)
 
 		// Signature is valid! Set agent context for handlers
 		c.Locals("agent_id", agentID)
 		c.Locals("organization_id", agent.OrganizationID)
 		c.Locals("authenticated_via", "ed25519")
 		c.Locals("auth_method", "ed25519") // Set auth_method so handlers can recognize Ed25519 auth
+		recordCaller(c, domain.AuthMethodEd25519)
 
 		return c.Next()
 	}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"bytes"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/crypto"
+)
+
+// exportSignatureHeaders are exposed to browsers through CORS
+var exportSignatureHeaders = []string{
+	crypto.ExportSignatureHeader,
+	crypto.ExportSignatureKeyIDHeader,
+	crypto.ExportSignatureAlgorithmHeader,
+}
+
+// ExportSigningMiddleware signs every file download (a successful response with an attachment
+// Content-Disposition) so that recipients can verify it was not modified later
+func ExportSigningMiddleware(signer *crypto.ExportSigner) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		resp := c.Response()
+		if resp.StatusCode() != fiber.StatusOK || resp.IsBodyStream() {
+			return nil
+		}
+		if !bytes.HasPrefix(resp.Header.Peek(fiber.HeaderContentDisposition), []byte("attachment")) {
+			return nil
+		}
+
+		c.Set(crypto.ExportSignatureHeader, signer.Sign(resp.Body()))
+		c.Set(crypto.ExportSignatureKeyIDHeader, signer.KeyID())
+		c.Set(crypto.ExportSignatureAlgorithmHeader, crypto.ExportSignatureAlgorithm)
+		return nil
+	}
+}
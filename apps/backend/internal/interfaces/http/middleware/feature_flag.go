@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+)
+
+// FeatureFlagMiddleware hides a preview route unless its feature flag is on for the caller: the
+// user, when the request has one, otherwise the organization. It must run after authentication.
+func FeatureFlagMiddleware(flags *application.FeatureFlagService, key string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		orgID, _ := c.Locals("organization_id").(uuid.UUID)
+		var userID *uuid.UUID
+		if id, ok := c.Locals("user_id").(uuid.UUID); ok && id != uuid.Nil {
+			userID = &id
+		}
+
+		if orgID == uuid.Nil || !flags.IsEnabled(c.UserContext(), orgID, userID, key) {
+			// Previews not turned on look like routes that do not exist
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Not found",
+			})
+		}
+		return c.Next()
+	}
+}
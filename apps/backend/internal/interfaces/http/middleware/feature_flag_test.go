@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureFlagsAreOnPerOrganizationWithUserOptInAndGatePreviewRoutes(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	repos.FeatureFlag.CreateFlag(&domain.FeatureFlag{Key: "agent-graph", Description: "Agent graph view", UserOptIn: true})
+	repos.FeatureFlag.CreateFlag(&domain.FeatureFlag{Key: "signed-receipts", SDKVisible: true})
+	repos.FeatureFlag.CreateFlag(&domain.FeatureFlag{Key: "new-dashboard", DefaultEnabled: true})
+	service := application.NewFeatureFlagService(repos.FeatureFlag)
+	ctx := context.Background()
+	orgID, otherOrgID := uuid.New(), uuid.New()
+	alice, bob := uuid.New(), uuid.New()
+
+	states := func(userID uuid.UUID) map[string]*domain.FeatureState {
+		features, err := service.ListFeatures(ctx, orgID, userID)
+		require.NoError(t, err)
+		byKey := make(map[string]*domain.FeatureState)
+		for _, feature := range features {
+			byKey[feature.Key] = feature
+		}
+		return byKey
+	}
+
+	// Flags start at their default
+	assert.False(t, states(alice)["agent-graph"].Enabled)
+	assert.True(t, states(alice)["new-dashboard"].Enabled)
+	assert.Equal(t, domain.FeatureSourceDefault, states(alice)["new-dashboard"].Source)
+
+	// Users opt in to flags that allow it, for themselves only
+	state, err := service.SetUserFeature(ctx, orgID, alice, "agent-graph", true)
+	require.NoError(t, err)
+	assert.True(t, state.Enabled)
+	assert.Equal(t, domain.FeatureSourceUser, state.Source)
+	assert.False(t, states(bob)["agent-graph"].Enabled)
+	_, err = service.SetUserFeature(ctx, orgID, alice, "signed-receipts", true)
+	assert.ErrorIs(t, err, application.ErrFeatureOptInNotAllowed)
+	_, err = service.SetUserFeature(ctx, orgID, alice, "missing", true)
+	assert.ErrorIs(t, err, domain.ErrFeatureFlagNotFound)
+
+	// Organizations turn flags on for everyone; turning one off overrides opt-ins
+	_, err = service.SetOrganizationFeature(ctx, orgID, bob, "agent-graph", true)
+	require.NoError(t, err)
+	assert.Equal(t, domain.FeatureSourceOrganization, states(bob)["agent-graph"].Source)
+	assert.True(t, states(bob)["agent-graph"].Enabled)
+	_, err = service.SetOrganizationFeature(ctx, orgID, bob, "agent-graph", false)
+	require.NoError(t, err)
+	assert.False(t, states(alice)["agent-graph"].Enabled)
+	require.NoError(t, service.ClearOrganizationFeature(ctx, orgID, "agent-graph"))
+	assert.True(t, states(alice)["agent-graph"].Enabled)
+	require.NoError(t, service.ClearUserFeature(ctx, orgID, alice, "agent-graph"))
+	assert.False(t, states(alice)["agent-graph"].Enabled)
+
+	// SDKs see the organization's state of SDK-visible flags only
+	_, err = service.SetOrganizationFeature(ctx, orgID, bob, "signed-receipts", true)
+	require.NoError(t, err)
+	sdkFeatures, err := service.ListSDKFeatures(ctx, orgID)
+	require.NoError(t, err)
+	require.Len(t, sdkFeatures, 1)
+	assert.Equal(t, "signed-receipts", sdkFeatures[0].Key)
+	assert.True(t, sdkFeatures[0].Enabled)
+	assert.False(t, service.IsEnabled(ctx, otherOrgID, nil, "signed-receipts"))
+
+	// Preview routes are hidden from callers the flag is off for
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("organization_id", orgID)
+		c.Locals("user_id", uuid.MustParse(c.Get("X-User")))
+		return c.Next()
+	})
+	app.Get("/graph", func(c fiber.Ctx) error { return c.SendString("graph") }, FeatureFlagMiddleware(service, "agent-graph"))
+	_, err = service.SetUserFeature(ctx, orgID, alice, "agent-graph", true)
+	require.NoError(t, err)
+	for user, want := range map[uuid.UUID]int{alice: fiber.StatusOK, bob: fiber.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodGet, "/graph", nil)
+		req.Header.Set("X-User", user.String())
+		resp, err := app.Test(req, 5*time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, want, resp.StatusCode)
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// IdempotencyMiddleware makes POST, PUT, PATCH and DELETE requests sent with an Idempotency-Key
+// header safe to retry. The first request with a key runs and its response is recorded for the
+// organization, key and endpoint; a retry with the same body within the replay window gets the
+// recorded response with Idempotent-Replayed: true. It must run after authentication; requests
+// without an organization or key pass through. A request is handled once, even when groups
+// sharing a path prefix each use the middleware.
+//
+// A key reused with a different body gets 422; a retry while the first request still runs gets 409.
+// Failed requests (errors and 5xx responses) are not recorded, so their retries run again.
+func IdempotencyMiddleware(idempotency *application.IdempotencyService) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		key := c.Get(domain.IdempotencyKeyHeader)
+		if key == "" || c.Locals("idempotency_checked") != nil {
+			return c.Next()
+		}
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		default:
+			return c.Next()
+		}
+		orgID, ok := c.Locals("organization_id").(uuid.UUID)
+		if !ok || orgID == uuid.Nil {
+			return c.Next()
+		}
+		c.Locals("idempotency_checked", true)
+
+		endpoint := c.Method() + " " + c.Path()
+		record, replay, err := idempotency.Begin(c.UserContext(), orgID, key, endpoint, c.Body())
+		if err != nil {
+			switch {
+			case errors.Is(err, application.ErrInvalidIdempotencyKey):
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			case errors.Is(err, application.ErrIdempotencyKeyReused):
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			case errors.Is(err, application.ErrIdempotentRequestInProgress):
+				c.Set("Retry-After", "1")
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check idempotency key",
+			})
+		}
+
+		if replay {
+			c.Set("Idempotent-Replayed", "true")
+			if record.ContentType != "" {
+				c.Set(fiber.HeaderContentType, record.ContentType)
+			}
+			return c.Status(record.StatusCode).Send(record.ResponseBody)
+		}
+
+		if err := c.Next(); err != nil {
+			idempotency.Release(c.UserContext(), record)
+			return err
+		}
+		response := c.Response()
+		idempotency.Complete(c.UserContext(), record, response.StatusCode(), string(response.Header.ContentType()), response.Body())
+		return nil
+	}
+}
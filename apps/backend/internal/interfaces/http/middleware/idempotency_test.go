@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetriesWithAnIdempotencyKeyReplayTheOriginalResponse(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	idempotency := application.NewIdempotencyService(repos.Idempotency, time.Hour)
+	org, otherOrg := uuid.New(), uuid.New()
+
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		orgID := org
+		if c.Get("X-Org") == "other" {
+			orgID = otherOrg
+		}
+		c.Locals("organization_id", orgID)
+		return c.Next()
+	})
+	app.Use(IdempotencyMiddleware(idempotency))
+	var created, failures atomic.Int32
+	app.Post("/events", func(c fiber.Ctx) error {
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"event": created.Add(1)})
+	})
+	app.Post("/flaky", func(c fiber.Ctx) error {
+		if failures.Add(1) == 1 {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "try again"})
+		}
+		return c.JSON(fiber.Map{"attempt": failures.Load()})
+	})
+
+	send := func(path, key, body string, header ...string) (*http.Response, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(domain.IdempotencyKeyHeader, key)
+		}
+		if len(header) == 2 {
+			req.Header.Set(header[0], header[1])
+		}
+		resp, err := app.Test(req, 5*time.Second)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var decoded map[string]interface{}
+		_ = json.NewDecoder(resp.Body).Decode(&decoded)
+		return resp, decoded
+	}
+
+	resp, body := send("/events", "evt-1", `{"action":"read"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, float64(1), body["event"])
+	assert.Empty(t, resp.Header.Get("Idempotent-Replayed"))
+
+	// The retry gets the original response without running the handler again
+	resp, body = send("/events", "evt-1", `{"action":"read"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, float64(1), body["event"])
+	assert.Equal(t, "true", resp.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(1), created.Load())
+
+	// The key is scoped to the organization and endpoint, and must not change the request
+	resp, _ = send("/events", "evt-1", `{"action":"write"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	resp, body = send("/events", "evt-1", `{"action":"read"}`, "X-Org", "other")
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, float64(2), body["event"])
+	resp, _ = send("/events", "", `{"action":"read"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, int32(3), created.Load(), "requests without a key always run")
+	resp, _ = send("/events", strings.Repeat("k", 256), `{}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// A request still running holds the key
+	_, _, err := idempotency.Begin(context.Background(), org, "evt-2", "POST /events", []byte(`{}`))
+	require.NoError(t, err)
+	resp, _ = send("/events", "evt-2", `{}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	// Server errors are not replayed, so the retry runs again
+	resp, _ = send("/flaky", "flaky-1", `{}`)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	resp, body = send("/flaky", "flaky-1", `{}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, float64(2), body["attempt"])
+
+	// Nothing is purged within the replay window; once it ends the key runs the request again
+	purged, err := idempotency.PurgeExpired(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+	_, err = repos.Idempotency.DeleteExpired(time.Now().Add(2 * time.Hour))
+	require.NoError(t, err)
+	_, body = send("/events", "evt-1", `{"action":"read"}`)
+	assert.Equal(t, float64(4), body["event"])
+}
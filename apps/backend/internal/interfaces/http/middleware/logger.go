@@ -5,11 +5,16 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/logger"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 )
 
-// LoggerMiddleware configures request logging
+// LoggerMiddleware configures request logging. Requests are logged at info level, so raising
+// the log level to warn or error silences them.
 func LoggerMiddleware() fiber.Handler {
 	return logger.New(logger.Config{
+		Next: func(c fiber.Ctx) bool {
+			return !logging.Enabled(logging.LevelInfo)
+		},
 		Format:     "[${time}] ${status} - ${latency} ${method} ${path}\n",
 		TimeFormat: time.RFC3339,
 		TimeZone:   "UTC",
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// OperatorNetworkMiddleware only lets requests from the allowed networks reach the operator
+// API. An empty list allows every network.
+func OperatorNetworkMiddleware(allowed []*net.IPNet) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if len(allowed) == 0 {
+			return c.Next()
+		}
+		if ip := net.ParseIP(c.IP()); ip != nil {
+			for _, network := range allowed {
+				if network.Contains(ip) {
+					return c.Next()
+				}
+			}
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Operator API is not reachable from this network",
+		})
+	}
+}
+
+// OperatorAuthMiddleware authenticates platform operators by their operator token
+// ("Bearer aimop_..."). It only sets operator locals, never the organization or user locals
+// tenant handlers read, so an operator token can't act in an organization.
+func OperatorAuthMiddleware(operators *application.PlatformOperatorService) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		bearer, found := strings.CutPrefix(c.Get("Authorization"), "Bearer ")
+		if !found {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Operator token required",
+			})
+		}
+
+		operator, err := operators.Authenticate(c.UserContext(), bearer)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		c.Locals("operator", operator)
+		c.Locals("operator_id", operator.ID)
+		c.Locals("operator_role", operator.Role)
+		return c.Next()
+	}
+}
+
+// OperatorRoleMiddleware checks the operator has at least the given role
+// Must be used AFTER OperatorAuthMiddleware
+func OperatorRoleMiddleware(required domain.OperatorRole) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		role, ok := c.Locals("operator_role").(domain.OperatorRole)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Operator token required",
+			})
+		}
+
+		if !role.Allows(required) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Operator role " + string(required) + " required",
+			})
+		}
+
+		return c.Next()
+	}
+}
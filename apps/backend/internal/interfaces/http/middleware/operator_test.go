@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/cache"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlatformOperatorsThrottleAndImpersonateWithConsent(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	ctx := context.Background()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	admin := testsupport.NewUser(org.ID, func(u *domain.User) { u.Role = domain.RoleAdmin })
+	require.NoError(t, repos.User.Create(admin))
+	require.NoError(t, repos.Agent.Create(testsupport.NewAgent(org.ID)))
+
+	security := application.NewSecurityService(repos.Security, repos.Agent, repos.Alert)
+	rateLimits := application.NewRateLimitService(repos.Organization, repos.PlatformOperator, security, cache.NewMemoryRateLimitStore(), nil)
+	service := application.NewPlatformOperatorService(
+		repos.PlatformOperator, repos.Organization, repos.User, repos.Agent, repos.MCPServer,
+		repos.Alert, repos.Security, repos.VerificationEvent, rateLimits,
+	)
+
+	// The bootstrap token creates the first admin operator once
+	bootstrapToken := domain.OperatorTokenPrefix + strings.Repeat("b", 40)
+	assert.ErrorIs(t, service.Bootstrap(ctx, "short"), application.ErrInvalidOperatorRequest)
+	require.NoError(t, service.Bootstrap(ctx, bootstrapToken))
+	require.NoError(t, service.Bootstrap(ctx, domain.OperatorTokenPrefix+strings.Repeat("c", 40)))
+	operators, err := service.ListOperators(ctx)
+	require.NoError(t, err)
+	require.Len(t, operators, 1)
+	bootstrap, err := service.Authenticate(ctx, bootstrapToken)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OperatorRoleAdmin, bootstrap.Role)
+
+	viewerToken, _, err := service.CreateOperator(ctx, bootstrap, "10.0.0.1", &application.CreateOperatorRequest{
+		Email: "viewer@hosting.example.com", Name: "Viewer", Role: domain.OperatorRoleViewer,
+	})
+	require.NoError(t, err)
+	supportToken, supporter, err := service.CreateOperator(ctx, bootstrap, "10.0.0.1", &application.CreateOperatorRequest{
+		Email: "support@hosting.example.com", Name: "Support", Role: domain.OperatorRoleSupport,
+	})
+	require.NoError(t, err)
+	_, _, err = service.UpdateOperator(ctx, bootstrap, "10.0.0.1", bootstrap.ID, &application.UpdateOperatorRequest{Role: domain.OperatorRoleViewer})
+	assert.ErrorIs(t, err, application.ErrInvalidOperatorRequest)
+
+	// The operator API authenticates operator tokens only, and enforces operator roles
+	app := fiber.New()
+	app.Use(OperatorNetworkMiddleware(nil))
+	operatorAPI := app.Group("/operator/v1", OperatorAuthMiddleware(service))
+	ok := func(c fiber.Ctx) error {
+		assert.Nil(t, c.Locals("organization_id"))
+		return c.SendString(c.Locals("operator_id").(uuid.UUID).String())
+	}
+	operatorAPI.Get("/organizations", ok, OperatorRoleMiddleware(domain.OperatorRoleViewer))
+	operatorAPI.Put("/organizations/:id/throttle", ok, OperatorRoleMiddleware(domain.OperatorRoleSupport))
+	call := func(router *fiber.App, method, path, bearer string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		resp, err := router.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusOK, call(app, http.MethodGet, "/operator/v1/organizations", viewerToken))
+	assert.Equal(t, http.StatusForbidden, call(app, http.MethodPut, "/operator/v1/organizations/x/throttle", viewerToken))
+	assert.Equal(t, http.StatusOK, call(app, http.MethodPut, "/operator/v1/organizations/x/throttle", supportToken))
+	assert.Equal(t, http.StatusUnauthorized, call(app, http.MethodGet, "/operator/v1/organizations", "aimpat_notanoperator"))
+	_, allowed, _ := net.ParseCIDR("10.20.0.0/16")
+	restricted := fiber.New()
+	restricted.Use(OperatorNetworkMiddleware([]*net.IPNet{allowed}))
+	restricted.Get("/operator/v1/me", ok)
+	assert.Equal(t, http.StatusForbidden, call(restricted, http.MethodGet, "/operator/v1/me", supportToken))
+
+	// Overviews carry usage and health; a throttle caps every limit of the organization
+	overview, err := service.GetOrganization(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, overview.Usage.Users)
+	assert.Equal(t, 1, overview.Usage.Agents)
+	assert.Equal(t, application.OrganizationHealthHealthy, overview.Health.Status)
+
+	_, err = service.SetThrottle(ctx, supporter, "10.0.0.2", org.ID, &application.ThrottleRequest{RequestsPerMinute: 1})
+	assert.ErrorIs(t, err, application.ErrInvalidOperatorRequest)
+	result, err := rateLimits.Allow(ctx, org.ID, nil, domain.EndpointClassRead)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DefaultRateLimits[domain.EndpointClassRead], result.Limit)
+	_, err = service.SetThrottle(ctx, supporter, "10.0.0.2", org.ID, &application.ThrottleRequest{
+		RequestsPerMinute: 1, Reason: "scraping the agent list", DurationMinutes: 30,
+	})
+	require.NoError(t, err)
+	result, err = rateLimits.Allow(ctx, org.ID, nil, domain.EndpointClassRead)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RateLimit{RequestsPerMinute: 1, Burst: 1}, result.Limit)
+	overview, err = service.GetOrganization(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Equal(t, application.OrganizationHealthDegraded, overview.Health.Status)
+	require.NotNil(t, overview.Throttle)
+	require.NoError(t, service.RemoveThrottle(ctx, supporter, "10.0.0.2", org.ID))
+	assert.ErrorIs(t, service.RemoveThrottle(ctx, supporter, "10.0.0.2", org.ID), domain.ErrOrganizationThrottleNotFound)
+
+	// Impersonation needs an admin's consent; the organization is alerted of the request
+	request, err := service.RequestImpersonation(ctx, supporter, "10.0.0.2", &application.ImpersonationRequestInput{
+		OrganizationID: org.ID, Reason: "customer ticket 4411",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleViewer, request.Role)
+	assert.Equal(t, application.DefaultImpersonationMinutes, request.DurationMinutes)
+	alerts, err := repos.Alert.GetByOrganization(org.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domain.AlertImpersonationRequested, alerts[0].AlertType)
+
+	_, _, err = service.StartImpersonation(ctx, supporter, "10.0.0.2", request.ID)
+	assert.ErrorIs(t, err, application.ErrImpersonationConflict)
+	_, err = service.DecideImpersonation(ctx, uuid.New(), request.ID, admin.ID, true)
+	assert.ErrorIs(t, err, domain.ErrImpersonationNotFound)
+	_, err = service.DecideImpersonation(ctx, org.ID, request.ID, admin.ID, true)
+	require.NoError(t, err)
+	_, _, err = service.StartImpersonation(ctx, bootstrap, "10.0.0.1", request.ID)
+	assert.ErrorIs(t, err, domain.ErrImpersonationNotFound)
+	sessionToken, started, err := service.StartImpersonation(ctx, supporter, "10.0.0.2", request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ImpersonationStatusActive, started.Status)
+	assert.True(t, strings.HasPrefix(sessionToken, domain.ImpersonationTokenPrefix))
+
+	// The session acts as the consenting admin with the requested role, and stops when revoked
+	tenant := fiber.New()
+	tenant.Use(ImpersonationMiddleware(service))
+	audit := application.NewAuditService(repos.AuditLog)
+	tenant.Get("/api/v1/agents", func(c fiber.Ctx) error {
+		assert.Equal(t, admin.ID, c.Locals("user_id"))
+		assert.Equal(t, org.ID, c.Locals("organization_id"))
+		assert.Equal(t, string(domain.RoleViewer), c.Locals("role"))
+		assert.Equal(t, domain.AuthMethodImpersonation, domain.AuthMethodFromContext(c.UserContext()))
+		return audit.LogAction(c.UserContext(), org.ID, admin.ID, domain.AuditActionView, "agent", uuid.New(), c.IP(), "", nil)
+	})
+	tenant.Post("/api/v1/users/me/tokens", ok)
+	assert.Equal(t, http.StatusOK, call(tenant, http.MethodGet, "/api/v1/agents", sessionToken))
+	assert.Equal(t, http.StatusForbidden, call(tenant, http.MethodPost, "/api/v1/users/me/tokens", sessionToken))
+	// Routing ignores case, so blocked paths do too
+	assert.Equal(t, http.StatusForbidden, call(tenant, http.MethodPost, "/api/v1/Users/me/tokens", sessionToken))
+	assert.Equal(t, http.StatusForbidden, call(tenant, http.MethodPost, "/API/V1/USERS/ME/TOKENS/", sessionToken))
+	logs, err := repos.AuditLog.GetByOrganization(org.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, request.ID, logs[0].Metadata["impersonation_id"])
+
+	_, err = service.RevokeImpersonation(ctx, org.ID, request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, call(tenant, http.MethodGet, "/api/v1/agents", sessionToken))
+	_, err = service.EndImpersonation(ctx, supporter, "10.0.0.2", request.ID)
+	assert.ErrorIs(t, err, application.ErrImpersonationConflict)
+
+	// Every change is in the operator log
+	actions, err := service.ListActions(ctx, 50, 0)
+	require.NoError(t, err)
+	var logged []string
+	for _, action := range actions {
+		logged = append(logged, action.Action)
+	}
+	assert.ElementsMatch(t, []string{
+		"operator.create", "operator.create", "throttle.set", "throttle.remove",
+		"impersonation.request", "impersonation.start",
+	}, logged)
+}
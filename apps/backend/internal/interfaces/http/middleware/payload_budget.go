@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// PayloadBudgetMiddleware holds request bodies to the limit of their endpoint class and gives
+// list endpoints their response budget. An empty class counts GET and HEAD requests as reads
+// and other methods as writes, unless a class was already set for the request; register
+// prefixes of other classes before the default. A class set on a route can only lower the
+// limit the request already passed.
+//
+// A request over its limit gets 413 with the limit and endpoint class.
+func PayloadBudgetMiddleware(budgets domain.PayloadBudgets, class string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		endpointClass := class
+		if endpointClass == "" {
+			if c.Locals("body_limit_class") != nil {
+				return c.Next()
+			}
+			endpointClass = domain.EndpointClassOf(c.Method())
+		}
+		c.Locals("body_limit_class", endpointClass)
+		c.Locals("response_budget", budgets.ListResponse)
+
+		if limit := budgets.RequestBodies[endpointClass]; limit > 0 && len(c.Body()) > limit {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error": "Request body too large",
+				"limit": limit,
+				"class": endpointClass,
+			})
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadBudgetsLimitRequestBodiesByEndpointClass(t *testing.T) {
+	budgets := domain.PayloadBudgets{
+		RequestBodies: map[string]int{
+			domain.EndpointClassRead:         64,
+			domain.EndpointClassWrite:        64,
+			domain.EndpointClassVerification: 16,
+			domain.EndpointClassAttestation:  128,
+			domain.EndpointClassBulk:         1024,
+		},
+		ListResponse: 256,
+	}
+	assert.Equal(t, 4096, budgets.MaxRequestBody(4096))
+	assert.Equal(t, 1024, budgets.MaxRequestBody(0))
+
+	app := fiber.New(fiber.Config{BodyLimit: budgets.MaxRequestBody(0)})
+	app.Use([]string{"/agents/:id/sboms"}, PayloadBudgetMiddleware(budgets, domain.EndpointClassBulk))
+	app.Use(PayloadBudgetMiddleware(budgets, ""))
+	app.Use(CompressionMiddleware("default"))
+	echo := func(c fiber.Ctx) error {
+		return c.JSON(fiber.Map{"class": c.Locals("body_limit_class"), "budget": c.Locals("response_budget")})
+	}
+	app.Post("/agents/:id/sboms", echo)
+	app.Post("/agents", echo)
+	app.Post("/verify", echo, PayloadBudgetMiddleware(budgets, domain.EndpointClassVerification))
+	app.Get("/report", func(c fiber.Ctx) error { return c.SendString(strings.Repeat("compressible ", 100)) })
+
+	send := func(path string, size int) (int, map[string]interface{}) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, path, strings.NewReader(strings.Repeat("x", size))))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var body map[string]interface{}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+
+	// Bulk paths are claimed before the default class
+	status, body := send("/agents/"+uuid.NewString()+"/sboms", 512)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, domain.EndpointClassBulk, body["class"])
+	assert.EqualValues(t, 256, body["budget"])
+
+	status, body = send("/agents", 512)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+	assert.Equal(t, domain.EndpointClassWrite, body["class"])
+	assert.EqualValues(t, 64, body["limit"])
+
+	// A route's class lowers the limit
+	status, _ = send("/verify", 32)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+	status, _ = send("/verify", 8)
+	assert.Equal(t, http.StatusOK, status)
+
+	req := httptest.NewRequest(http.MethodGet, "/report", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+}
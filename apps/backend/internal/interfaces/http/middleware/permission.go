@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// RequirePermission lets the request through when the caller holds the permission, through
+// their built-in role or a custom role assigned to them. The resolved permissions are kept in
+// the "permissions" local for later checks of the same request.
+// Must be used AFTER AuthMiddleware
+func RequirePermission(roles *application.RoleService, permission domain.Permission) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		permissions, ok := c.Locals("permissions").(domain.PermissionSet)
+		if !ok {
+			role, ok := c.Locals("role").(string)
+			if !ok {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Authentication required",
+				})
+			}
+			orgID, _ := c.Locals("organization_id").(uuid.UUID)
+			userID, _ := c.Locals("user_id").(uuid.UUID)
+			if _, impersonating := c.Locals("impersonation_id").(uuid.UUID); impersonating {
+				// Operators act with the role the organization consented to, never more
+				userID = uuid.Nil
+			}
+
+			var err error
+			permissions, err = roles.Permissions(c.UserContext(), orgID, userID, domain.UserRole(role))
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to resolve permissions",
+				})
+			}
+			c.Locals("permissions", permissions)
+		}
+
+		if !permissions.Has(permission) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":      "Permission required: " + string(permission),
+				"permission": permission,
+			})
+		}
+
+		return c.Next()
+	}
+}
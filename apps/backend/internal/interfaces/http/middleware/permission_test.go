@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomRolesGrantPermissionsOnTopOfTheBuiltInRole(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	service := application.NewRoleService(repos.CustomRole, repos.User)
+	ctx := context.Background()
+	org := testsupport.NewOrganization()
+	require.NoError(t, repos.Organization.Create(org))
+	admin := testsupport.NewUser(org.ID, func(u *domain.User) { u.Role = domain.RoleAdmin })
+	member := testsupport.NewUser(org.ID, func(u *domain.User) { u.Role = domain.RoleMember })
+	outsider := testsupport.NewUser(uuid.New())
+	for _, user := range []*domain.User{admin, member, outsider} {
+		require.NoError(t, repos.User.Create(user))
+	}
+
+	// Built-in roles keep the access they had: admins hold every permission, viewers none
+	assert.Len(t, domain.BuiltinRolePermissions(domain.RoleAdmin), len(domain.PermissionCatalog))
+	assert.Empty(t, domain.BuiltinRolePermissions(domain.RoleViewer))
+	managerPermissions := domain.BuiltinRolePermissions(domain.RoleManager)
+	assert.True(t, managerPermissions.Has(domain.PermissionAgentsDelete))
+	assert.False(t, managerPermissions.Has(domain.PermissionCapabilityRequestsApprove))
+
+	// Roles only hold catalog permissions, under a unique name that is not a built-in role
+	_, err := service.CreateRole(ctx, org.ID, admin.ID, &application.CustomRoleRequest{Name: "Approver", Permissions: []domain.Permission{"capability_requests:everything"}})
+	assert.ErrorIs(t, err, application.ErrInvalidCustomRole)
+	_, err = service.CreateRole(ctx, org.ID, admin.ID, &application.CustomRoleRequest{Name: "Manager", Permissions: []domain.Permission{domain.PermissionAgentsDelete}})
+	assert.ErrorIs(t, err, application.ErrInvalidCustomRole)
+	approver, err := service.CreateRole(ctx, org.ID, admin.ID, &application.CustomRoleRequest{
+		Name:        " Capability approver ",
+		Permissions: []domain.Permission{domain.PermissionCapabilityRequestsApprove, domain.PermissionCapabilityRequestsApprove},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Capability approver", approver.Name)
+	assert.Equal(t, []domain.Permission{domain.PermissionCapabilityRequestsApprove}, approver.Permissions)
+	_, err = service.CreateRole(ctx, org.ID, admin.ID, &application.CustomRoleRequest{Name: "capability APPROVER", Permissions: []domain.Permission{domain.PermissionAgentsDelete}})
+	assert.ErrorIs(t, err, domain.ErrCustomRoleNameTaken)
+
+	// Roles are assigned to users of the organization only
+	_, err = service.AssignRole(ctx, org.ID, approver.ID, outsider.ID, admin.ID)
+	assert.ErrorIs(t, err, application.ErrRoleUserNotFound)
+	_, err = service.AssignRole(ctx, uuid.New(), approver.ID, member.ID, admin.ID)
+	assert.ErrorIs(t, err, domain.ErrCustomRoleNotFound)
+	_, err = service.AssignRole(ctx, org.ID, approver.ID, member.ID, admin.ID)
+	require.NoError(t, err)
+	_, err = service.AssignRole(ctx, org.ID, approver.ID, member.ID, admin.ID)
+	require.NoError(t, err)
+	role, err := service.GetRole(ctx, org.ID, approver.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, role.UserCount)
+
+	permissions, err := service.Permissions(ctx, org.ID, member.ID, domain.RoleMember)
+	require.NoError(t, err)
+	assert.True(t, permissions.Has(domain.PermissionCapabilityRequestsApprove))
+	assert.True(t, permissions.Has(domain.PermissionAgentsCreate))
+	assert.False(t, permissions.Has(domain.PermissionUsersManage))
+
+	// The middleware lets the member approve capability requests without managing users
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("organization_id", org.ID)
+		c.Locals("user_id", uuid.MustParse(c.Get("X-User")))
+		c.Locals("role", c.Get("X-Role"))
+		if c.Get("X-Impersonation") != "" {
+			c.Locals("impersonation_id", uuid.New())
+		}
+		return c.Next()
+	})
+	ok := func(c fiber.Ctx) error { return c.SendString("ok") }
+	app.Post("/capability-requests/approve", ok, RequirePermission(service, domain.PermissionCapabilityRequestsApprove))
+	app.Get("/users", ok, RequirePermission(service, domain.PermissionUsersManage))
+	call := func(path string, user *domain.User, impersonating bool) int {
+		method := http.MethodGet
+		if strings.HasPrefix(path, "/capability") {
+			method = http.MethodPost
+		}
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-User", user.ID.String())
+		req.Header.Set("X-Role", string(user.Role))
+		if impersonating {
+			req.Header.Set("X-Impersonation", "1")
+		}
+		resp, err := app.Test(req, 5*time.Second)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+	assert.Equal(t, fiber.StatusOK, call("/capability-requests/approve", member, false))
+	assert.Equal(t, fiber.StatusForbidden, call("/users", member, false))
+	assert.Equal(t, fiber.StatusOK, call("/users", admin, false))
+	// Impersonation sessions act with the consented built-in role only
+	assert.Equal(t, fiber.StatusForbidden, call("/capability-requests/approve", member, true))
+
+	// Changing or deleting the role changes what its users hold
+	_, err = service.UpdateRole(ctx, org.ID, approver.ID, &application.CustomRoleRequest{Name: "Capability approver", Permissions: []domain.Permission{domain.PermissionUsersManage}})
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, call("/capability-requests/approve", member, false))
+	assert.Equal(t, fiber.StatusOK, call("/users", member, false))
+	require.NoError(t, service.DeleteRole(ctx, org.ID, approver.ID))
+	assert.Equal(t, fiber.StatusForbidden, call("/users", member, false))
+	roles, err := service.ListUserRoles(ctx, org.ID, member.ID)
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// personalAccessTokenRoutes maps API paths to the scope resource a personal access token needs
+// for them. Paths not listed, such as token and user management, can't be used with these tokens.
+var personalAccessTokenRoutes = []struct {
+	prefix   string
+	resource string
+}{
+	{"/api/v1/agents", "agents"},
+	{"/api/v1/detection", "agents"},
+	{"/api/v1/mcp-servers", "mcp_servers"},
+	{"/api/v1/verifications", "verifications"},
+	{"/api/v1/verification-events", "verifications"},
+	{"/api/v1/security", "security"},
+	{"/api/v1/analytics", "analytics"},
+	{"/api/v1/admin/audit-logs", "audit"},
+	{"/api/v1/tags", "tags"},
+	{"/api/v1/webhooks", "webhooks"},
+	{"/api/v1/reports", "reports"},
+}
+
+// PersonalAccessTokenScopeFor returns the scope a personal access token needs for a request:
+// "resource:read" for GET and HEAD, "resource:write" otherwise. It reports false for paths
+// personal access tokens can't be used on.
+func PersonalAccessTokenScopeFor(method, path string) (string, bool) {
+	for _, route := range personalAccessTokenRoutes {
+		if path != route.prefix && !strings.HasPrefix(path, route.prefix+"/") {
+			continue
+		}
+		if method == fiber.MethodGet || method == fiber.MethodHead {
+			return route.resource + ":read", true
+		}
+		return route.resource + ":write", true
+	}
+	return "", false
+}
+
+// PersonalAccessTokenMiddleware authenticates requests carrying a personal access token
+// ("Bearer aimpat_...") as the token's user, with the user's role, and rejects those the token's
+// scopes do not allow. Other requests pass through untouched for the JWT, API key and Ed25519
+// middlewares; the JWT middleware skips requests authenticated here.
+func PersonalAccessTokenMiddleware(tokens *application.PersonalAccessTokenService) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		bearer, found := strings.CutPrefix(c.Get("Authorization"), "Bearer ")
+		if !found || !strings.HasPrefix(bearer, domain.PersonalAccessTokenPrefix) {
+			return c.Next()
+		}
+
+		principal, err := tokens.Authenticate(c.UserContext(), bearer, c.IP())
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		scope, usable := PersonalAccessTokenScopeFor(c.Method(), c.Path())
+		if !usable {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Personal access tokens cannot be used for this endpoint",
+			})
+		}
+		if !principal.Token.HasScope(scope) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":         "Personal access token is not allowed to perform this action",
+				"requiredScope": scope,
+			})
+		}
+
+		c.Locals("user_id", principal.User.ID)
+		c.Locals("organization_id", principal.User.OrganizationID)
+		c.Locals("email", principal.User.Email)
+		c.Locals("role", string(principal.User.Role))
+		c.Locals("personal_access_token_id", principal.Token.ID)
+		c.Locals("auth_method", "personal_access_token")
+		c.Locals("authenticated_via", "personal_access_token")
+		recordCaller(c, domain.AuthMethodPersonalAccessToken)
+
+		return c.Next()
+	}
+}
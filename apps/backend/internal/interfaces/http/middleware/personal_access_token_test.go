@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonalAccessTokensAreScopedExpiringAndRevocable(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	service := application.NewPersonalAccessTokenService(repos.PersonalAccessToken, repos.User)
+	ctx := context.Background()
+	org := testsupport.NewOrganization()
+	user := testsupport.NewUser(org.ID)
+	admin := testsupport.NewUser(org.ID, func(u *domain.User) { u.Role = domain.RoleAdmin })
+	require.NoError(t, repos.User.Create(user))
+	require.NoError(t, repos.User.Create(admin))
+
+	// Tokens need a name and known scopes, and expire within a year
+	for _, req := range []application.CreatePersonalAccessTokenRequest{
+		{Name: "", Scopes: []string{"agents:read"}},
+		{Name: "ci", Scopes: nil},
+		{Name: "ci", Scopes: []string{"users:write"}},
+		{Name: "ci", Scopes: []string{"agents:read"}, ExpiresInDays: 400},
+	} {
+		_, _, err := service.CreateToken(ctx, org.ID, user.ID, &req)
+		assert.ErrorIs(t, err, application.ErrInvalidPersonalAccessToken)
+	}
+
+	plain, token, err := service.CreateToken(ctx, org.ID, user.ID, &application.CreatePersonalAccessTokenRequest{
+		Name:   "inventory script",
+		Scopes: []string{"agents:read", "security:*", "agents:read"},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(plain, domain.PersonalAccessTokenPrefix))
+	assert.True(t, strings.HasPrefix(plain, token.Prefix))
+	assert.NotContains(t, token.TokenHash, plain)
+	assert.Equal(t, []string{"agents:read", "security:*"}, token.Scopes)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, application.DefaultPersonalAccessTokenDays), token.ExpiresAt, time.Minute)
+
+	// Authenticating records where the token was last used, at most once a minute per address
+	principal, err := service.Authenticate(ctx, plain, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, principal.User.ID)
+	stored, err := repos.PersonalAccessToken.GetByID(token.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.LastUsedAt)
+	firstUse := *stored.LastUsedAt
+	_, err = service.Authenticate(ctx, plain, "10.0.0.1")
+	require.NoError(t, err)
+	stored, _ = repos.PersonalAccessToken.GetByID(token.ID)
+	assert.Equal(t, firstUse, *stored.LastUsedAt)
+	_, err = service.Authenticate(ctx, plain, "10.0.0.2")
+	require.NoError(t, err)
+	stored, _ = repos.PersonalAccessToken.GetByID(token.ID)
+	assert.Equal(t, "10.0.0.2", *stored.LastUsedIP)
+	_, err = service.Authenticate(ctx, plain+"x", "10.0.0.1")
+	assert.ErrorIs(t, err, application.ErrPersonalAccessTokenRejected)
+
+	// Requests are limited to the token's scopes and to the endpoints tokens may be used on
+	app := fiber.New()
+	app.Use(PersonalAccessTokenMiddleware(service))
+	ok := func(c fiber.Ctx) error { return c.SendString(c.Locals("user_id").(uuid.UUID).String()) }
+	app.Get("/api/v1/agents", ok)
+	app.Post("/api/v1/agents", ok)
+	app.Post("/api/v1/security/incidents/:id/comments", ok)
+	app.Post("/api/v1/users/me/tokens", ok)
+	call := func(method, path, bearer string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		resp, err := app.Test(req, 5*time.Second)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+	assert.Equal(t, fiber.StatusOK, call(http.MethodGet, "/api/v1/agents", plain))
+	assert.Equal(t, fiber.StatusForbidden, call(http.MethodPost, "/api/v1/agents", plain))
+	assert.Equal(t, fiber.StatusOK, call(http.MethodPost, "/api/v1/security/incidents/1/comments", plain))
+	assert.Equal(t, fiber.StatusForbidden, call(http.MethodPost, "/api/v1/users/me/tokens", plain))
+	assert.Equal(t, fiber.StatusUnauthorized, call(http.MethodGet, "/api/v1/agents", domain.PersonalAccessTokenPrefix+"unknown"))
+	scope, usable := PersonalAccessTokenScopeFor(http.MethodGet, "/api/v1/admin/audit-logs")
+	assert.True(t, usable)
+	assert.Equal(t, domain.PersonalAccessTokenScopeAuditRead, scope)
+
+	// Tokens stop working once they expire or their user is suspended
+	expired := &domain.PersonalAccessToken{
+		OrganizationID: org.ID,
+		UserID:         user.ID,
+		Name:           "old",
+		TokenHash:      "expired-hash",
+		Scopes:         []string{"*"},
+		ExpiresAt:      time.Now().Add(-time.Hour),
+	}
+	require.NoError(t, repos.PersonalAccessToken.Create(expired))
+	assert.False(t, expired.IsActive(time.Now()))
+	user.Status = domain.UserStatusSuspended
+	require.NoError(t, repos.User.Update(user))
+	_, err = service.Authenticate(ctx, plain, "10.0.0.1")
+	assert.ErrorIs(t, err, application.ErrPersonalAccessTokenRejected)
+	user.Status = domain.UserStatusActive
+	require.NoError(t, repos.User.Update(user))
+
+	// Users revoke their own tokens only; admins revoke any token of their organization
+	assert.ErrorIs(t, service.RevokeUserToken(ctx, admin.ID, token.ID, "not mine"), domain.ErrPersonalAccessTokenNotFound)
+	_, err = service.RevokeToken(ctx, uuid.New(), token.ID, admin.ID, "other org")
+	assert.ErrorIs(t, err, domain.ErrPersonalAccessTokenNotFound)
+	revoked, err := service.RevokeToken(ctx, org.ID, token.ID, admin.ID, "left the team")
+	require.NoError(t, err)
+	require.NotNil(t, revoked.RevokedAt)
+	assert.Equal(t, admin.ID, *revoked.RevokedBy)
+	assert.Equal(t, "left the team", *revoked.RevokeReason)
+	_, err = service.Authenticate(ctx, plain, "10.0.0.1")
+	assert.ErrorIs(t, err, application.ErrPersonalAccessTokenRejected)
+
+	tokens, err := service.ListOrganizationTokens(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Len(t, tokens, 2)
+	mine, err := service.ListUserTokens(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, mine, 2)
+	mine, err = service.ListUserTokens(ctx, admin.ID)
+	require.NoError(t, err)
+	assert.Empty(t, mine)
+}
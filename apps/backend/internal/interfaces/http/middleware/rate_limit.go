@@ -1,18 +1,75 @@
 package middleware
 
 import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/limiter"
 	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
 )
 
+// Requests per minute of RateLimitMiddleware and StrictRateLimitMiddleware; runtime settings
+// change them without a restart
+var (
+	rateLimitPerMinute       atomic.Int64
+	strictRateLimitPerMinute atomic.Int64
+)
+
+func init() {
+	SetRateLimit(100)
+	SetStrictRateLimit(10)
+}
+
+// SetRateLimit changes the requests per minute of RateLimitMiddleware; counters restart
+func SetRateLimit(perMinute int) {
+	rateLimitPerMinute.Store(int64(perMinute))
+}
+
+// SetStrictRateLimit changes the requests per minute of StrictRateLimitMiddleware; counters restart
+func SetStrictRateLimit(perMinute int) {
+	strictRateLimitPerMinute.Store(int64(perMinute))
+}
+
+// RateLimits returns the requests per minute of RateLimitMiddleware and StrictRateLimitMiddleware
+func RateLimits() (perMinute, strictPerMinute int) {
+	return int(rateLimitPerMinute.Load()), int(strictRateLimitPerMinute.Load())
+}
+
 // RateLimitMiddleware implements rate limiting
 func RateLimitMiddleware() fiber.Handler {
+	return adjustableLimiter(&rateLimitPerMinute)
+}
+
+// StrictRateLimitMiddleware implements stricter rate limiting for sensitive endpoints
+func StrictRateLimitMiddleware() fiber.Handler {
+	return adjustableLimiter(&strictRateLimitPerMinute)
+}
+
+// adjustableLimiter limits requests per minute to the current value of max, keeping a limiter
+// for each value it has seen
+func adjustableLimiter(max *atomic.Int64) fiber.Handler {
+	var limiters sync.Map // int64 -> fiber.Handler
+	return func(c fiber.Ctx) error {
+		current := max.Load()
+		handler, ok := limiters.Load(current)
+		if !ok {
+			handler, _ = limiters.LoadOrStore(current, newLimiter(int(current)))
+		}
+		return handler.(fiber.Handler)(c)
+	}
+}
+
+func newLimiter(max int) fiber.Handler {
 	return limiter.New(limiter.Config{
-		Max:        100,                  // 100 requests
-		Expiration: 1 * time.Minute,      // per minute
+		Max:        max,             // requests
+		Expiration: 1 * time.Minute, // per minute
 		KeyGenerator: func(c fiber.Ctx) string {
 			// Rate limit by user if authenticated, otherwise by IP
 			if userID := c.Locals("user_id"); userID != nil {
@@ -30,23 +87,106 @@ func RateLimitMiddleware() fiber.Handler {
 	})
 }
 
-// StrictRateLimitMiddleware implements stricter rate limiting for sensitive endpoints
-func StrictRateLimitMiddleware() fiber.Handler {
-	return limiter.New(limiter.Config{
-		Max:        10,                   // 10 requests
-		Expiration: 1 * time.Minute,      // per minute
-		KeyGenerator: func(c fiber.Ctx) string {
-			if userID := c.Locals("user_id"); userID != nil {
-				if id, ok := userID.(uuid.UUID); ok {
-					return id.String()
-				}
-			}
-			return c.IP()
-		},
-		LimitReached: func(c fiber.Ctx) error {
+// AgentQuotaMiddleware limits the requests one agent has in flight to its plan tier's
+// concurrency. The agent comes from the authenticated agent or API key.
+func AgentQuotaMiddleware(quotas *application.AgentQuotaService) fiber.Handler {
+	return agentQuota(quotas, false)
+}
+
+// AgentVerificationQuotaMiddleware also counts each request against the agent's verification
+// throughput. The agent comes from the authenticated agent or API key, or else the agent_id of
+// the request body.
+func AgentVerificationQuotaMiddleware(quotas *application.AgentQuotaService) fiber.Handler {
+	return agentQuota(quotas, true)
+}
+
+func agentQuota(quotas *application.AgentQuotaService, verification bool) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		agentID, ok := quotaAgentID(c, verification)
+		if !ok {
+			return c.Next()
+		}
+
+		usage, release, err := quotas.Acquire(c.UserContext(), agentID, verification)
+		defer release()
+		if usage != nil {
+			c.Set("X-Agent-RateLimit-Limit", strconv.Itoa(usage.Quota.VerificationsPerMinute))
+			c.Set("X-Agent-RateLimit-Burst", strconv.Itoa(usage.Quota.Burst))
+			c.Set("X-Agent-RateLimit-Remaining", strconv.Itoa(usage.Remaining))
+			c.Set("X-Agent-RateLimit-Reset", strconv.Itoa(int(math.Ceil(usage.ResetAfter.Seconds()))))
+			c.Set("X-Agent-Concurrency-Limit", strconv.Itoa(usage.Quota.MaxConcurrent))
+		}
+		if err != nil {
+			retryAfter := int(math.Ceil(usage.RetryAfter.Seconds()))
+			c.Set("Retry-After", strconv.Itoa(retryAfter))
 			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error": "Rate limit exceeded. Please try again later.",
+				"error":      err.Error(),
+				"limit":      usage.Quota,
+				"retryAfter": retryAfter,
 			})
-		},
-	})
+		}
+
+		return c.Next()
+	}
+}
+
+// quotaAgentID returns the agent a request is made by
+func quotaAgentID(c fiber.Ctx, fromBody bool) (uuid.UUID, bool) {
+	if id, ok := c.Locals("agent_id").(uuid.UUID); ok && id != uuid.Nil {
+		return id, true
+	}
+	if !fromBody {
+		return uuid.Nil, false
+	}
+	var body struct {
+		AgentID string `json:"agent_id"`
+	}
+	if err := json.Unmarshal(c.Body(), &body); err != nil {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(body.AgentID)
+	return id, err == nil
+}
+
+// OrganizationRateLimitMiddleware holds requests to the organization's rate limit of the
+// endpoint class. An empty class counts GET and HEAD requests as reads and other methods as
+// writes. It must run after authentication; requests without an organization pass through.
+// A request is counted once, even when groups sharing a path prefix each use the middleware.
+func OrganizationRateLimitMiddleware(limiter *application.RateLimitService, class string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		orgID, ok := c.Locals("organization_id").(uuid.UUID)
+		if !ok || orgID == uuid.Nil || c.Locals("org_rate_limited") != nil {
+			return c.Next()
+		}
+		c.Locals("org_rate_limited", true)
+
+		endpointClass := class
+		if endpointClass == "" {
+			endpointClass = domain.EndpointClassOf(c.Method())
+		}
+		var apiKeyID *uuid.UUID
+		if id, ok := c.Locals("api_key_id").(uuid.UUID); ok && id != uuid.Nil {
+			apiKeyID = &id
+		}
+
+		result, err := limiter.Allow(c.UserContext(), orgID, apiKeyID, endpointClass)
+		if result != nil {
+			c.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit.RequestsPerMinute))
+			c.Set("X-RateLimit-Burst", strconv.Itoa(result.Limit.Burst))
+			c.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			c.Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(result.ResetAfter.Seconds()))))
+		}
+		if err != nil {
+			retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+			c.Set("Retry-After", strconv.Itoa(retryAfter))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error":      err.Error(),
+				"class":      endpointClass,
+				"limit":      result.Limit,
+				"retryAfter": retryAfter,
+			})
+		}
+
+		return c.Next()
+	}
 }
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// RequestTimeoutMiddleware gives the request a deadline from the budget of its endpoint class
+// and holds calls to dependencies to their budgets. An empty class counts GET and HEAD
+// requests as reads and other methods as writes; a class set on a route group can only
+// shorten the deadline set before it. Handlers must pass c.UserContext() on for the deadline
+// to apply.
+//
+// A request whose dependency ran out of time gets 504 naming the dependency, instead of the
+// handler's response; "request" when the request ran out of its own budget.
+func RequestTimeoutMiddleware(budgets domain.TimeoutBudgets, class string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		endpointClass := class
+		if endpointClass == "" {
+			endpointClass = domain.EndpointClassOf(c.Method())
+		}
+
+		nested := c.Locals("request_budget") != nil
+		c.Locals("request_budget", endpointClass)
+		start := time.Now()
+
+		ctx := c.UserContext()
+		if budget := budgets.Requests[endpointClass]; budget > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, budget)
+			defer cancel()
+		}
+		ctx = domain.WithDependencyBudgets(ctx, budgets.Dependencies)
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		// The outermost middleware answers for the request
+		if nested {
+			return err
+		}
+		timeout := domain.ExceededDependency(ctx)
+		// A failed request that ran out of its own budget was waiting on something not held to a
+		// budget of its own. Route middleware may have shortened the deadline.
+		requestCtx := c.UserContext()
+		if timeout == nil && errors.Is(requestCtx.Err(), context.DeadlineExceeded) &&
+			(err != nil || c.Response().StatusCode() >= fiber.StatusInternalServerError) {
+			timeout = &domain.DependencyTimeoutError{Dependency: "request", Err: requestCtx.Err()}
+			if deadline, ok := requestCtx.Deadline(); ok {
+				timeout.Budget = deadline.Sub(start).Round(time.Millisecond)
+			}
+		}
+		if timeout == nil {
+			return err
+		}
+
+		log.Printf("⚠️  %s %s timed out: %v", c.Method(), c.Path(), timeout)
+		c.Response().ResetBody()
+		return c.Status(fiber.StatusGatewayTimeout).JSON(fiber.Map{
+			"error":      "A dependency did not respond in time",
+			"dependency": timeout.Dependency,
+			"budget":     timeout.Budget.String(),
+		})
+	}
+}
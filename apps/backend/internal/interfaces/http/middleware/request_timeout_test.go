@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowDependenciesRunOutOfTheirTimeoutBudgetAndAnswer504(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	probe := func(ctx context.Context) error {
+		return domain.CallDependency(ctx, domain.DependencyHTTP, func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, slow.URL, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+			return resp.Body.Close()
+		})
+	}
+
+	budgets := domain.TimeoutBudgets{
+		Requests: map[string]time.Duration{
+			domain.EndpointClassRead:         time.Second,
+			domain.EndpointClassWrite:        50 * time.Millisecond,
+			domain.EndpointClassVerification: 30 * time.Millisecond,
+		},
+		Dependencies: map[string]time.Duration{domain.DependencyHTTP: 20 * time.Millisecond},
+	}
+
+	// The call gets the dependency's budget, and the request remembers what ran out of time
+	ctx := domain.WithDependencyBudgets(context.Background(), budgets.Dependencies)
+	err := probe(ctx)
+	var timeout *domain.DependencyTimeoutError
+	require.ErrorAs(t, err, &timeout)
+	assert.Equal(t, domain.DependencyHTTP, timeout.Dependency)
+	assert.Equal(t, 20*time.Millisecond, timeout.Budget)
+	assert.Same(t, timeout, domain.ExceededDependency(ctx))
+
+	app := fiber.New()
+	app.Use(RequestTimeoutMiddleware(budgets, ""))
+	// Handlers that hide the dependency's error still answer 504 naming it
+	app.Get("/probe", func(c fiber.Ctx) error {
+		if err := probe(c.UserContext()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to probe"})
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	// Waiting on something without a budget of its own runs out of the request's budget
+	wait := func(c fiber.Ctx) error {
+		<-c.UserContext().Done()
+		return c.UserContext().Err()
+	}
+	app.Post("/wait", wait)
+	app.Post("/verify", wait, RequestTimeoutMiddleware(budgets, domain.EndpointClassVerification))
+	app.Get("/fast", func(c fiber.Ctx) error { return c.SendString("ok") })
+
+	send := func(method, path string) (int, map[string]interface{}) {
+		resp, err := app.Test(httptest.NewRequest(method, path, nil), 5*time.Second)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var body map[string]interface{}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+
+	status, body := send(http.MethodGet, "/probe")
+	assert.Equal(t, http.StatusGatewayTimeout, status)
+	assert.Equal(t, domain.DependencyHTTP, body["dependency"])
+	assert.Equal(t, "20ms", body["budget"])
+
+	status, body = send(http.MethodPost, "/wait")
+	assert.Equal(t, http.StatusGatewayTimeout, status)
+	assert.Equal(t, "request", body["dependency"])
+	assert.Equal(t, "50ms", body["budget"])
+
+	// A route's class shortens the deadline
+	status, body = send(http.MethodPost, "/verify")
+	assert.Equal(t, http.StatusGatewayTimeout, status)
+	assert.Equal(t, "30ms", body["budget"])
+
+	status, _ = send(http.MethodGet, "/fast")
+	assert.Equal(t, http.StatusOK, status)
+}
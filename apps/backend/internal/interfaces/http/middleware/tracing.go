@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/infrastructure/tracing"
+)
+
+// TracingMiddleware starts a server span for the request and puts it in c.UserContext(), so the
+// spans of services and repositories the handler calls become its children. A W3C traceparent
+// header sent by the SDK makes the span part of the caller's trace. The span is named after the
+// route that matched, e.g. "POST /api/v1/sdk-api/verifications".
+func TracingMiddleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if !tracing.Enabled() || c.Path() == "/metrics" || c.Path() == "/health" {
+			return c.Next()
+		}
+
+		ctx := tracing.Extract(c.UserContext(), func(key string) string { return c.Get(key) })
+		ctx, span := tracing.StartSpan(ctx, c.Method(), tracing.SpanKindServer,
+			tracing.String("http.request.method", c.Method()),
+			tracing.String("url.path", c.Path()),
+			tracing.String("client.address", c.IP()),
+			tracing.String("user_agent.original", c.Get("User-Agent")),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		route := c.Route().Path
+		span.SetName(c.Method() + " " + route)
+		status := c.Response().StatusCode()
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			status = fiberErr.Code
+		} else if err != nil {
+			status = fiber.StatusInternalServerError
+		}
+		span.SetAttributes(
+			tracing.String("http.route", route),
+			tracing.Int("http.response.status_code", status),
+		)
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(tracing.StatusError, http.StatusText(status))
+		}
+		if orgID, ok := c.Locals("organization_id").(uuid.UUID); ok {
+			span.SetAttributes(tracing.String("aim.organization_id", orgID.String()))
+		}
+		return err
+	}
+}
@@ -58,7 +58,7 @@ func CacheMiddleware(config CacheConfig) fiber.Handler {
 
 		// Try to get cached response
 		var cachedResponse []byte
-		err := config.Cache.Get(c.Context(), key, &cachedResponse)
+		err := config.Cache.Get(c.UserContext(), key, &cachedResponse)
 		if err == nil {
 			// Cache hit
 			c.Set("X-Cache", "HIT")
@@ -78,7 +78,7 @@ func CacheMiddleware(config CacheConfig) fiber.Handler {
 			// Cache the response body
 			responseBody := c.Response().Body()
 			if len(responseBody) > 0 {
-				config.Cache.Set(c.Context(), key, responseBody, config.Expiration)
+				config.Cache.Set(c.UserContext(), key, responseBody, config.Expiration)
 			}
 		}
 
@@ -49,7 +49,7 @@ func RateLimiter(config RateLimitConfig) fiber.Handler {
 		}
 
 		key := config.KeyGenerator(c)
-		allowed, err := config.Cache.RateLimit(c.Context(), key, config.MaxRequests, config.Window)
+		allowed, err := config.Cache.RateLimit(c.UserContext(), key, config.MaxRequests, config.Window)
 		if err != nil {
 			// On error, allow request to proceed but log error
 			fmt.Printf("Rate limit error: %v\n", err)
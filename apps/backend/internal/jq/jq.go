@@ -0,0 +1,105 @@
+// Package jq compiles and runs jq filters that reshape JSON payloads (webhook
+// transformation templates), using gojq. Inputs are JSON values: nil, bool,
+// float64, string, []interface{} and map[string]interface{}.
+//
+// Filters see nothing but their input: $ENV is empty and input/inputs have no
+// further inputs to read. Runs are bounded by RunTimeout and maxOutputs.
+package jq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/itchyny/gojq"
+)
+
+// MaxFilterLength bounds stored filters to keep compilation and evaluation cheap
+const MaxFilterLength = 8192
+
+// RunTimeout bounds how long a single run may take, so a filter that loops or
+// builds huge values cannot hold up webhook delivery
+const RunTimeout = 250 * time.Millisecond
+
+// maxOutputs bounds how many values a single run may produce
+const maxOutputs = 10000
+
+// Program is a compiled filter that can be run many times
+type Program struct {
+	source string
+	code   *gojq.Code
+}
+
+// Compile parses and compiles a filter
+func Compile(source string) (*Program, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, fmt.Errorf("filter is empty")
+	}
+	if len(source) > MaxFilterLength {
+		return nil, fmt.Errorf("filter exceeds %d characters", MaxFilterLength)
+	}
+
+	query, err := gojq.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	code, err := gojq.Compile(query, gojq.WithEnvironLoader(func() []string { return nil }))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Program{source: source, code: code}, nil
+}
+
+// Source returns the filter text the program was compiled from
+func (p *Program) Source() string {
+	return p.source
+}
+
+// Run applies the filter to a JSON value and returns every output
+func (p *Program) Run(input interface{}) ([]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), RunTimeout)
+	defer cancel()
+
+	var outputs []interface{}
+	iter := p.code.RunWithContext(ctx, input)
+	for {
+		value, ok := iter.Next()
+		if !ok {
+			return outputs, nil
+		}
+		if err, ok := value.(error); ok {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil, fmt.Errorf("filter ran longer than %s", RunTimeout)
+			}
+			return nil, err
+		}
+		if len(outputs) == maxOutputs {
+			return nil, fmt.Errorf("filter produced more than %d values", maxOutputs)
+		}
+		outputs = append(outputs, value)
+	}
+}
+
+// RunJSON applies the filter to a JSON document and requires exactly one
+// output, returned encoded as JSON
+func (p *Program) RunJSON(data []byte) ([]byte, error) {
+	var input interface{}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, fmt.Errorf("invalid JSON input: %w", err)
+	}
+
+	outputs, err := p.Run(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(outputs) != 1 {
+		return nil, fmt.Errorf("filter must produce exactly one value, got %d", len(outputs))
+	}
+
+	return json.Marshal(outputs[0])
+}
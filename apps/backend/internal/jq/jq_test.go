@@ -0,0 +1,160 @@
+package jq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPayload = `{
+	"event": "verification.completed",
+	"timestamp": "2025-11-20T10:00:00Z",
+	"data": {
+		"agentName": "billing-bot",
+		"status": "failed",
+		"trustScore": 0.42,
+		"driftDetected": true,
+		"mcpServerDrift": ["external-api-mcp", "shell-mcp"],
+		"metadata": {"region": "eu-west-1"}
+	}
+}`
+
+func TestRunJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   string
+		expected string
+	}{
+		{"identity field", `.event`, `"verification.completed"`},
+		{"nested field", `.data.metadata.region`, `"eu-west-1"`},
+		{"missing field is null", `.data.missing.deeper`, `null`},
+		{"quoted field", `.data."agentName"`, `"billing-bot"`},
+		{"index", `.data.mcpServerDrift[1]`, `"shell-mcp"`},
+		{"negative index", `.data.mcpServerDrift[-1]`, `"shell-mcp"`},
+		{"object construction", `{event, agent: .data.agentName}`, `{"agent":"billing-bot","event":"verification.completed"}`},
+		{"computed key", `{(.data.status): true}`, `{"failed":true}`},
+		{"array collection", `[.data.mcpServerDrift[] | ascii_upcase]`, `["EXTERNAL-API-MCP","SHELL-MCP"]`},
+		{"interpolation", `"\(.data.agentName) is \(.data.status) (\(.data.trustScore))"`, `"billing-bot is failed (0.42)"`},
+		{"nested interpolation", `"drift: \(.data.mcpServerDrift | join(", "))"`, `"drift: external-api-mcp, shell-mcp"`},
+		{"alternative", `.data.owner // "unassigned"`, `"unassigned"`},
+		{"if elif else", `if .data.trustScore > 0.8 then "low" elif .data.trustScore > 0.4 then "medium" else "high" end`, `"medium"`},
+		{"boolean logic", `.data.driftDetected and (.data.status == "failed" or false)`, `true`},
+		{"arithmetic", `(.data.trustScore * 100) + 1`, `43`},
+		{"variable binding", `.data as $d | {agent: $d.agentName, count: ($d.mcpServerDrift | length)}`, `{"agent":"billing-bot","count":2}`},
+		{"map and select", `.data.mcpServerDrift | map(select(startswith("shell")))`, `["shell-mcp"]`},
+		{"with_entries", `.data.metadata | with_entries({key: ("aws_" + .key), value})`, `{"aws_region":"eu-west-1"}`},
+		{"tojson", `.data.metadata | tojson`, `"{\"region\":\"eu-west-1\"}"`},
+		{"optional suppresses error", `[.event.nope?]`, `[]`},
+		{"has and contains", `[(.data | has("status")), (.data.agentName | contains("bill"))]`, `[true,true]`},
+		{"test regex", `.data.agentName | test("^[a-z]+-bot$")`, `true`},
+		{"comments", "# Slack message\n{text: .event}", `{"text":"verification.completed"}`},
+		{
+			"slack blocks",
+			`{blocks: [{type: "section", text: {type: "mrkdwn", text: "*\(.data.agentName)* \(.event)"}}]}`,
+			`{"blocks":[{"text":{"text":"*billing-bot* verification.completed","type":"mrkdwn"},"type":"section"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.filter)
+			require.NoError(t, err)
+
+			out, err := program.RunJSON([]byte(testPayload))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(out))
+		})
+	}
+}
+
+func TestRunJSONRequiresSingleOutput(t *testing.T) {
+	program, err := Compile(`.data.mcpServerDrift[]`)
+	require.NoError(t, err)
+
+	_, err = program.RunJSON([]byte(testPayload))
+	assert.ErrorContains(t, err, "exactly one value, got 2")
+
+	program, err = Compile(`empty`)
+	require.NoError(t, err)
+
+	_, err = program.RunJSON([]byte(testPayload))
+	assert.ErrorContains(t, err, "got 0")
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+	}{
+		{"empty", "   "},
+		{"unknown function", `.data | frobnicate`},
+		{"wrong arity", `map`},
+		{"unterminated string", `"abc`},
+		{"unterminated interpolation", `"\(.a"`},
+		{"unbalanced parens", `(.a`},
+		{"missing end", `if .a then 1 else 2`},
+		{"computed key without value", `{(.a)}`},
+		{"trailing tokens", `.a )`},
+		{"undefined variable", `$missing`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.filter)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+	}{
+		{"index string", `.event.name`},
+		{"iterate number", `.data.trustScore[]`},
+		{"add mismatched", `.event + 1`},
+		{"division by zero", `1 / 0`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.filter)
+			require.NoError(t, err)
+
+			_, err = program.RunJSON([]byte(testPayload))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRunLimits(t *testing.T) {
+	program, err := Compile(`range(20000)`)
+	require.NoError(t, err)
+
+	_, err = program.Run(nil)
+	assert.ErrorContains(t, err, "more than 10000 values")
+
+	program, err = Compile(`last(range(1e12))`)
+	require.NoError(t, err)
+
+	_, err = program.Run(nil)
+	assert.ErrorContains(t, err, "ran longer than")
+}
+
+func TestFiltersSeeOnlyTheirInput(t *testing.T) {
+	t.Setenv("AIM_TEST_SECRET", "s3cr3t")
+
+	for _, filter := range []string{`$ENV.AIM_TEST_SECRET`, `env.AIM_TEST_SECRET`} {
+		program, err := Compile(filter)
+		require.NoError(t, err)
+
+		out, err := program.RunJSON([]byte(testPayload))
+		require.NoError(t, err)
+		assert.JSONEq(t, `null`, string(out))
+	}
+
+	_, err := Compile(`input`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,938 @@
+package testsupport
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	_ domain.AgentRepository                 = (*AgentRepository)(nil)
+	_ domain.AgentPeerPolicyRepository       = (*AgentPeerPolicyRepository)(nil)
+	_ domain.MCPToolGrantRepository          = (*MCPToolGrantRepository)(nil)
+	_ domain.AgentLineageRepository          = (*AgentLineageRepository)(nil)
+	_ domain.AgentDelegationRepository       = (*AgentDelegationRepository)(nil)
+	_ domain.AgentSBOMRepository             = (*AgentSBOMRepository)(nil)
+	_ domain.APIKeyRepository                = (*APIKeyRepository)(nil)
+	_ domain.CapabilityRepository            = (*CapabilityRepository)(nil)
+	_ domain.CapabilityRequestRepository     = (*CapabilityRequestRepository)(nil)
+	_ domain.CapabilityDeprecationRepository = (*CapabilityDeprecationRepository)(nil)
+	_ domain.TrustScoreRepository            = (*TrustScoreRepository)(nil)
+)
+
+// AgentRepository is an in-memory domain.AgentRepository
+type AgentRepository struct {
+	agents *table[domain.Agent]
+
+	mu     sync.Mutex
+	frozen map[uuid.UUID]time.Time
+}
+
+// NewAgentRepository creates an empty in-memory agent repository
+func NewAgentRepository() *AgentRepository {
+	return &AgentRepository{
+		agents: newTable[domain.Agent](),
+		frozen: make(map[uuid.UUID]time.Time),
+	}
+}
+
+// Create stores the agent, applying the same defaults as the SQL repository
+func (r *AgentRepository) Create(agent *domain.Agent) error {
+	now := time.Now()
+	agent.ID = newID(agent.ID)
+	agent.CreatedAt = now
+	agent.UpdatedAt = now
+	if agent.TrustScore == 0 {
+		agent.TrustScore = 0.5
+	}
+	if agent.Status == "" {
+		agent.Status = domain.AgentStatusPending
+	}
+	if agent.KeyAlgorithm == "" {
+		agent.KeyAlgorithm = "Ed25519"
+	}
+
+	r.agents.put(agent.ID, *agent)
+	return nil
+}
+
+func (r *AgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
+	agent, ok := r.agents.get(id)
+	if !ok {
+		return nil, fmt.Errorf("agent not found")
+	}
+	return agent, nil
+}
+
+func (r *AgentRepository) GetByIDs(ids []uuid.UUID) ([]*domain.Agent, error) {
+	return r.agents.getMany(ids), nil
+}
+
+func (r *AgentRepository) GetByName(orgID uuid.UUID, name string) (*domain.Agent, error) {
+	agent, ok := r.agents.first(func(a *domain.Agent) bool {
+		return a.OrganizationID == orgID && a.Name == name
+	})
+	if !ok {
+		return nil, fmt.Errorf("agent not found")
+	}
+	return agent, nil
+}
+
+func (r *AgentRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Agent, error) {
+	return r.agents.find(func(a *domain.Agent) bool {
+		return a.OrganizationID == orgID
+	}), nil
+}
+
+func (r *AgentRepository) Update(agent *domain.Agent) error {
+	agent.UpdatedAt = time.Now()
+	if !r.agents.replace(agent.ID, *agent) {
+		return fmt.Errorf("agent not found")
+	}
+	return nil
+}
+
+func (r *AgentRepository) Delete(id uuid.UUID) error {
+	r.agents.remove(id)
+	return nil
+}
+
+func (r *AgentRepository) List(limit, offset int) ([]*domain.Agent, error) {
+	return paginate(r.agents.find(nil), limit, offset), nil
+}
+
+// UpdateTrustScore updates the score unless it was frozen by the compromise response
+func (r *AgentRepository) UpdateTrustScore(id uuid.UUID, newScore float64) error {
+	if r.IsTrustScoreFrozen(id) {
+		return nil
+	}
+	r.agents.update(id, func(a *domain.Agent) {
+		a.TrustScore = newScore
+		a.UpdatedAt = time.Now()
+	})
+	return nil
+}
+
+// MarkAsCompromised flags an agent as compromised and suspends it
+func (r *AgentRepository) MarkAsCompromised(id uuid.UUID) error {
+	r.agents.update(id, func(a *domain.Agent) {
+		a.Status = domain.AgentStatusSuspended
+		a.IsCompromised = true
+		a.UpdatedAt = time.Now()
+	})
+	return nil
+}
+
+func (r *AgentRepository) AddTalksTo(id uuid.UUID, mcpServer string) (bool, error) {
+	added := false
+	r.agents.update(id, func(a *domain.Agent) {
+		for _, existing := range a.TalksTo {
+			if existing == mcpServer {
+				return
+			}
+		}
+		a.TalksTo = append(a.TalksTo, mcpServer)
+		a.UpdatedAt = time.Now()
+		added = true
+	})
+	return added, nil
+}
+
+func (r *AgentRepository) UpdateLastActive(ctx context.Context, agentID uuid.UUID) error {
+	now := time.Now()
+	r.agents.update(agentID, func(a *domain.Agent) {
+		a.LastActive = &now
+	})
+	return nil
+}
+
+// RotateKey replaces the key material; like the SQL repository, the stored public key (not the
+// caller's copy) becomes the previous key
+func (r *AgentRepository) RotateKey(agent *domain.Agent) error {
+	ok := r.agents.update(agent.ID, func(a *domain.Agent) {
+		a.PreviousPublicKey = a.PublicKey
+		a.PreviousKeyAlgorithm = a.KeyAlgorithm
+		a.PublicKey = agent.PublicKey
+		a.EncryptedPrivateKey = agent.EncryptedPrivateKey
+		a.KeyAlgorithm = agent.KeyAlgorithm
+		a.KeyCreatedAt = agent.KeyCreatedAt
+		a.KeyExpiresAt = agent.KeyExpiresAt
+		a.KeyRotationGraceUntil = agent.KeyRotationGraceUntil
+		a.RotationCount++
+		a.UpdatedAt = time.Now()
+
+		agent.PreviousPublicKey = a.PreviousPublicKey
+		agent.PreviousKeyAlgorithm = a.PreviousKeyAlgorithm
+		agent.RotationCount = a.RotationCount
+		agent.UpdatedAt = a.UpdatedAt
+	})
+	if !ok {
+		return fmt.Errorf("agent not found")
+	}
+	return nil
+}
+
+func (r *AgentRepository) DropEscrowedPrivateKeys(orgID uuid.UUID) (int, error) {
+	return r.agents.updateWhere(func(a *domain.Agent) bool {
+		return a.OrganizationID == orgID && a.EncryptedPrivateKey != nil
+	}, func(a *domain.Agent) {
+		a.EncryptedPrivateKey = nil
+		a.UpdatedAt = time.Now()
+	}), nil
+}
+
+// FreezeTrustScore stops automatic trust score updates for the agent
+func (r *AgentRepository) FreezeTrustScore(id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.frozen[id]; !ok {
+		r.frozen[id] = time.Now()
+	}
+	return nil
+}
+
+// IsTrustScoreFrozen reports whether FreezeTrustScore was called for the agent
+func (r *AgentRepository) IsTrustScoreFrozen(id uuid.UUID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.frozen[id]
+	return ok
+}
+
+// organizationOf returns the organization of an agent, if the agent is known
+func (r *AgentRepository) organizationOf(agentID uuid.UUID) (uuid.UUID, bool) {
+	if r == nil {
+		return uuid.Nil, false
+	}
+	agent, ok := r.agents.get(agentID)
+	if !ok {
+		return uuid.Nil, false
+	}
+	return agent.OrganizationID, true
+}
+
+// APIKeyRepository is an in-memory domain.APIKeyRepository
+type APIKeyRepository struct {
+	keys   *table[domain.APIKey]
+	agents *AgentRepository
+}
+
+// NewAPIKeyRepository creates an empty in-memory API key repository. When agents is
+// set, listed keys carry the agent name like the SQL join does.
+func NewAPIKeyRepository(agents *AgentRepository) *APIKeyRepository {
+	return &APIKeyRepository{keys: newTable[domain.APIKey](), agents: agents}
+}
+
+func (r *APIKeyRepository) Create(key *domain.APIKey) error {
+	key.ID = newID(key.ID)
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+	r.keys.put(key.ID, *key)
+	return nil
+}
+
+func (r *APIKeyRepository) GetByID(id uuid.UUID) (*domain.APIKey, error) {
+	key, ok := r.keys.get(id)
+	if !ok {
+		return nil, fmt.Errorf("api key not found")
+	}
+	return key, nil
+}
+
+// GetByHash returns nil (and no error) for an active key that does not exist
+func (r *APIKeyRepository) GetByHash(hash string) (*domain.APIKey, error) {
+	key, ok := r.keys.first(func(k *domain.APIKey) bool {
+		return k.KeyHash == hash && k.IsActive
+	})
+	if !ok {
+		return nil, nil
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepository) GetByAgent(agentID uuid.UUID) ([]*domain.APIKey, error) {
+	return r.withAgentNames(r.keys.find(func(k *domain.APIKey) bool {
+		return k.AgentID == agentID
+	})), nil
+}
+
+func (r *APIKeyRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.APIKey, error) {
+	return r.withAgentNames(r.keys.find(func(k *domain.APIKey) bool {
+		return k.OrganizationID == orgID
+	})), nil
+}
+
+func (r *APIKeyRepository) Revoke(id uuid.UUID) error {
+	r.keys.update(id, func(k *domain.APIKey) {
+		k.IsActive = false
+	})
+	return nil
+}
+
+func (r *APIKeyRepository) Delete(id uuid.UUID) error {
+	r.keys.remove(id)
+	return nil
+}
+
+func (r *APIKeyRepository) UpdateLastUsed(id uuid.UUID) error {
+	now := time.Now()
+	r.keys.update(id, func(k *domain.APIKey) {
+		k.LastUsedAt = &now
+	})
+	return nil
+}
+
+func (r *APIKeyRepository) UpdateExpiry(id uuid.UUID, expiresAt *time.Time) error {
+	r.keys.update(id, func(k *domain.APIKey) {
+		k.ExpiresAt = expiresAt
+	})
+	return nil
+}
+
+func (r *APIKeyRepository) withAgentNames(keys []*domain.APIKey) []*domain.APIKey {
+	if r.agents == nil {
+		return keys
+	}
+	for _, key := range keys {
+		if agent, ok := r.agents.agents.get(key.AgentID); ok {
+			key.AgentName = agent.Name
+		}
+	}
+	return keys
+}
+
+// CapabilityRepository is an in-memory domain.CapabilityRepository
+type CapabilityRepository struct {
+	capabilities *table[domain.AgentCapability]
+	violations   *table[domain.CapabilityViolation]
+	agents       *AgentRepository
+}
+
+// NewCapabilityRepository creates an empty in-memory capability repository. The
+// organization-scoped violation queries need agents to resolve each violation's organization.
+func NewCapabilityRepository(agents *AgentRepository) *CapabilityRepository {
+	return &CapabilityRepository{
+		capabilities: newTable[domain.AgentCapability](),
+		violations:   newTable[domain.CapabilityViolation](),
+		agents:       agents,
+	}
+}
+
+func (r *CapabilityRepository) CreateCapability(capability *domain.AgentCapability) error {
+	now := time.Now()
+	capability.ID = newID(capability.ID)
+	if capability.GrantedAt.IsZero() {
+		capability.GrantedAt = now
+	}
+	capability.CreatedAt = now
+	capability.UpdatedAt = now
+	r.capabilities.put(capability.ID, *capability)
+	return nil
+}
+
+func (r *CapabilityRepository) GetCapabilityByID(id uuid.UUID) (*domain.AgentCapability, error) {
+	capability, ok := r.capabilities.get(id)
+	if !ok {
+		return nil, fmt.Errorf("capability not found")
+	}
+	return capability, nil
+}
+
+func (r *CapabilityRepository) GetCapabilitiesByAgentID(agentID uuid.UUID) ([]*domain.AgentCapability, error) {
+	return r.capabilities.find(func(c *domain.AgentCapability) bool {
+		return c.AgentID == agentID
+	}), nil
+}
+
+func (r *CapabilityRepository) GetActiveCapabilitiesByAgentID(agentID uuid.UUID) ([]*domain.AgentCapability, error) {
+	return r.capabilities.find(func(c *domain.AgentCapability) bool {
+		return c.AgentID == agentID && c.Active(time.Now())
+	}), nil
+}
+
+func (r *CapabilityRepository) RevokeCapability(id uuid.UUID, revokedAt time.Time) error {
+	if !r.capabilities.update(id, func(c *domain.AgentCapability) {
+		c.RevokedAt = &revokedAt
+		c.UpdatedAt = time.Now()
+	}) {
+		return fmt.Errorf("capability not found")
+	}
+	return nil
+}
+
+func (r *CapabilityRepository) DeleteCapability(id uuid.UUID) error {
+	if !r.capabilities.remove(id) {
+		return fmt.Errorf("capability not found")
+	}
+	return nil
+}
+
+func (r *CapabilityRepository) GetExpiredCapabilities(at time.Time) ([]*domain.AgentCapability, error) {
+	return r.capabilities.find(func(c *domain.AgentCapability) bool {
+		return c.RevokedAt == nil && c.Expired(at)
+	}), nil
+}
+
+func (r *CapabilityRepository) SetCapabilityExpiry(id uuid.UUID, expiresAt *time.Time) error {
+	if !r.capabilities.update(id, func(c *domain.AgentCapability) {
+		c.ExpiresAt = expiresAt
+		c.UpdatedAt = time.Now()
+	}) {
+		return fmt.Errorf("capability not found")
+	}
+	return nil
+}
+
+func (r *CapabilityRepository) CreateViolation(violation *domain.CapabilityViolation) error {
+	violation.ID = newID(violation.ID)
+	if violation.CreatedAt.IsZero() {
+		violation.CreatedAt = time.Now()
+	}
+	r.violations.put(violation.ID, *violation)
+	return nil
+}
+
+func (r *CapabilityRepository) GetViolationByID(id uuid.UUID) (*domain.CapabilityViolation, error) {
+	violation, ok := r.violations.get(id)
+	if !ok {
+		return nil, fmt.Errorf("violation not found")
+	}
+	return violation, nil
+}
+
+func (r *CapabilityRepository) GetViolationsByAgentID(agentID uuid.UUID, limit, offset int) ([]*domain.CapabilityViolation, int, error) {
+	violations := r.violations.find(func(v *domain.CapabilityViolation) bool {
+		return v.AgentID == agentID
+	})
+	return paginate(violations, limit, offset), len(violations), nil
+}
+
+func (r *CapabilityRepository) GetRecentViolations(orgID uuid.UUID, minutes int) ([]*domain.CapabilityViolation, error) {
+	since := time.Now().Add(-time.Duration(minutes) * time.Minute)
+	return r.violations.find(func(v *domain.CapabilityViolation) bool {
+		return v.CreatedAt.After(since) && r.inOrganization(v.AgentID, orgID)
+	}), nil
+}
+
+func (r *CapabilityRepository) GetViolationsByOrganization(orgID uuid.UUID, limit, offset int) ([]*domain.CapabilityViolation, int, error) {
+	violations := r.violations.find(func(v *domain.CapabilityViolation) bool {
+		return r.inOrganization(v.AgentID, orgID)
+	})
+	return paginate(violations, limit, offset), len(violations), nil
+}
+
+func (r *CapabilityRepository) inOrganization(agentID, orgID uuid.UUID) bool {
+	agentOrg, ok := r.agents.organizationOf(agentID)
+	return ok && agentOrg == orgID
+}
+
+// CapabilityDeprecationRepository is an in-memory domain.CapabilityDeprecationRepository
+type CapabilityDeprecationRepository struct {
+	deprecations *table[domain.CapabilityDeprecation]
+	agents       *AgentRepository
+	servers      *MCPServerRepository
+}
+
+// NewCapabilityDeprecationRepository creates an empty in-memory capability deprecation repository.
+// Agents and servers fill in the joined names; either may be nil.
+func NewCapabilityDeprecationRepository(agents *AgentRepository, servers *MCPServerRepository) *CapabilityDeprecationRepository {
+	return &CapabilityDeprecationRepository{
+		deprecations: newTable[domain.CapabilityDeprecation](),
+		agents:       agents,
+		servers:      servers,
+	}
+}
+
+func (r *CapabilityDeprecationRepository) Create(deprecation *domain.CapabilityDeprecation) error {
+	deprecation.ID = newID(deprecation.ID)
+	if deprecation.DeprecatedAt.IsZero() {
+		deprecation.DeprecatedAt = time.Now().UTC()
+	}
+	r.deprecations.put(deprecation.ID, *deprecation)
+	return nil
+}
+
+func (r *CapabilityDeprecationRepository) GetActiveByServer(serverID uuid.UUID) ([]*domain.CapabilityDeprecation, error) {
+	return r.active(func(d *domain.CapabilityDeprecation) bool {
+		return d.MCPServerID == serverID
+	}), nil
+}
+
+func (r *CapabilityDeprecationRepository) GetActiveByAgent(agentID uuid.UUID) ([]*domain.CapabilityDeprecation, error) {
+	return r.active(func(d *domain.CapabilityDeprecation) bool {
+		return d.AgentID == agentID
+	}), nil
+}
+
+func (r *CapabilityDeprecationRepository) Resolve(id uuid.UUID, resolvedAt time.Time) error {
+	resolved := false
+	r.deprecations.update(id, func(d *domain.CapabilityDeprecation) {
+		if d.ResolvedAt == nil {
+			d.ResolvedAt = &resolvedAt
+			resolved = true
+		}
+	})
+	if !resolved {
+		return fmt.Errorf("capability deprecation not found")
+	}
+	return nil
+}
+
+// active returns the matching unresolved deprecations oldest first, with the joined names
+func (r *CapabilityDeprecationRepository) active(match func(*domain.CapabilityDeprecation) bool) []*domain.CapabilityDeprecation {
+	deprecations := oldestFirst(r.deprecations.find(func(d *domain.CapabilityDeprecation) bool {
+		return d.ResolvedAt == nil && match(d)
+	}))
+	for _, d := range deprecations {
+		if r.agents != nil {
+			if agent, ok := r.agents.agents.get(d.AgentID); ok {
+				d.AgentName = agent.DisplayName
+			}
+		}
+		if r.servers != nil {
+			if server, ok := r.servers.servers.get(d.MCPServerID); ok {
+				d.MCPServerName = server.Name
+			}
+		}
+	}
+	return deprecations
+}
+
+// CapabilityRequestRepository is an in-memory domain.CapabilityRequestRepository
+type CapabilityRequestRepository struct {
+	requests *table[domain.CapabilityRequest]
+	agents   *AgentRepository
+	users    *UserRepository
+}
+
+// NewCapabilityRequestRepository creates an empty in-memory capability request repository.
+// Agents and users fill in the joined details and the organization filter; either may be nil.
+func NewCapabilityRequestRepository(agents *AgentRepository, users *UserRepository) *CapabilityRequestRepository {
+	return &CapabilityRequestRepository{
+		requests: newTable[domain.CapabilityRequest](),
+		agents:   agents,
+		users:    users,
+	}
+}
+
+func (r *CapabilityRequestRepository) Create(req *domain.CapabilityRequest) error {
+	now := time.Now()
+	req.ID = newID(req.ID)
+	if req.Status == "" {
+		req.Status = domain.CapabilityRequestStatusPending
+	}
+	if req.RequestedAt.IsZero() {
+		req.RequestedAt = now
+	}
+	req.CreatedAt = now
+	req.UpdatedAt = now
+	r.requests.put(req.ID, *req)
+	return nil
+}
+
+func (r *CapabilityRequestRepository) GetByID(id uuid.UUID) (*domain.CapabilityRequestWithDetails, error) {
+	req, ok := r.requests.get(id)
+	if !ok {
+		return nil, fmt.Errorf("capability request not found")
+	}
+	return r.withDetails(req), nil
+}
+
+func (r *CapabilityRequestRepository) List(filter domain.CapabilityRequestFilter) ([]*domain.CapabilityRequestWithDetails, error) {
+	requests := r.requests.find(func(req *domain.CapabilityRequest) bool {
+		if filter.Status != nil && req.Status != *filter.Status {
+			return false
+		}
+		if filter.AgentID != nil && req.AgentID != *filter.AgentID {
+			return false
+		}
+		if filter.OrganizationID != nil {
+			agentOrg, ok := r.agents.organizationOf(req.AgentID)
+			if !ok || agentOrg != *filter.OrganizationID {
+				return false
+			}
+		}
+		return true
+	})
+
+	requests = paginate(requests, filter.Limit, filter.Offset)
+	result := make([]*domain.CapabilityRequestWithDetails, 0, len(requests))
+	for _, req := range requests {
+		result = append(result, r.withDetails(req))
+	}
+	return result, nil
+}
+
+func (r *CapabilityRequestRepository) UpdateStatus(id uuid.UUID, status domain.CapabilityRequestStatus, reviewedBy uuid.UUID) error {
+	now := time.Now()
+	if !r.requests.update(id, func(req *domain.CapabilityRequest) {
+		req.Status = status
+		req.ReviewedBy = &reviewedBy
+		req.ReviewedAt = &now
+		req.UpdatedAt = now
+	}) {
+		return fmt.Errorf("capability request not found")
+	}
+	return nil
+}
+
+func (r *CapabilityRequestRepository) Delete(id uuid.UUID) error {
+	if !r.requests.remove(id) {
+		return fmt.Errorf("capability request not found")
+	}
+	return nil
+}
+
+func (r *CapabilityRequestRepository) withDetails(req *domain.CapabilityRequest) *domain.CapabilityRequestWithDetails {
+	details := &domain.CapabilityRequestWithDetails{CapabilityRequest: *req}
+	if r.agents != nil {
+		if agent, ok := r.agents.agents.get(req.AgentID); ok {
+			details.AgentName = agent.Name
+			details.AgentDisplayName = agent.DisplayName
+		}
+	}
+	if r.users != nil {
+		if user, ok := r.users.users.get(req.RequestedBy); ok {
+			details.RequestedByEmail = user.Email
+		}
+		if req.ReviewedBy != nil {
+			if user, ok := r.users.users.get(*req.ReviewedBy); ok {
+				email := user.Email
+				details.ReviewedByEmail = &email
+			}
+		}
+	}
+	return details
+}
+
+// TrustScoreRepository is an in-memory domain.TrustScoreRepository
+type TrustScoreRepository struct {
+	scores *table[domain.TrustScore]
+	agents *AgentRepository
+}
+
+// NewTrustScoreRepository creates an empty in-memory trust score repository. When agents
+// is set, audit trail entries carry the agent's organization.
+func NewTrustScoreRepository(agents *AgentRepository) *TrustScoreRepository {
+	return &TrustScoreRepository{scores: newTable[domain.TrustScore](), agents: agents}
+}
+
+func (r *TrustScoreRepository) Create(score *domain.TrustScore) error {
+	score.ID = newID(score.ID)
+	if score.CreatedAt.IsZero() {
+		score.CreatedAt = time.Now()
+	}
+	if score.LastCalculated.IsZero() {
+		score.LastCalculated = score.CreatedAt
+	}
+	r.scores.put(score.ID, *score)
+	return nil
+}
+
+// GetByAgent returns the latest score, or nil if the agent has never been scored
+func (r *TrustScoreRepository) GetByAgent(agentID uuid.UUID) (*domain.TrustScore, error) {
+	return r.GetLatest(agentID)
+}
+
+// GetLatest returns the latest score, or nil if the agent has never been scored
+func (r *TrustScoreRepository) GetLatest(agentID uuid.UUID) (*domain.TrustScore, error) {
+	score, ok := r.scores.first(func(s *domain.TrustScore) bool {
+		return s.AgentID == agentID
+	})
+	if !ok {
+		return nil, nil
+	}
+	return score, nil
+}
+
+func (r *TrustScoreRepository) GetLatestByAgents(agentIDs []uuid.UUID) (map[uuid.UUID]*domain.TrustScore, error) {
+	scores := make(map[uuid.UUID]*domain.TrustScore, len(agentIDs))
+	for _, agentID := range agentIDs {
+		score, err := r.GetLatest(agentID)
+		if err != nil {
+			return nil, err
+		}
+		if score != nil {
+			scores[agentID] = score
+		}
+	}
+	return scores, nil
+}
+
+func (r *TrustScoreRepository) GetHistory(agentID uuid.UUID, limit int) ([]*domain.TrustScore, error) {
+	scores := r.scores.find(func(s *domain.TrustScore) bool {
+		return s.AgentID == agentID
+	})
+	return paginate(scores, limit, 0), nil
+}
+
+// GetHistoryAuditTrail derives the audit trail from the stored scores, each entry
+// pointing back at the score it replaced
+func (r *TrustScoreRepository) GetHistoryAuditTrail(agentID uuid.UUID, limit int) ([]*domain.TrustScoreHistoryEntry, error) {
+	scores := r.scores.find(func(s *domain.TrustScore) bool {
+		return s.AgentID == agentID
+	})
+	orgID, _ := r.agents.organizationOf(agentID)
+
+	entries := make([]*domain.TrustScoreHistoryEntry, 0, len(scores))
+	for i, score := range scores {
+		entry := &domain.TrustScoreHistoryEntry{
+			ID:             score.ID,
+			AgentID:        agentID,
+			OrganizationID: orgID,
+			TrustScore:     score.Score,
+			ChangeReason:   "recalculated",
+			RecordedAt:     score.LastCalculated,
+			CreatedAt:      score.CreatedAt,
+		}
+		if i+1 < len(scores) {
+			previous := scores[i+1].Score
+			entry.PreviousScore = &previous
+		}
+		entries = append(entries, entry)
+	}
+	return paginate(entries, limit, 0), nil
+}
+
+// AgentSBOMRepository is an in-memory domain.AgentSBOMRepository
+type AgentSBOMRepository struct {
+	sboms *table[domain.AgentSBOM]
+}
+
+// NewAgentSBOMRepository creates an empty in-memory agent SBOM repository
+func NewAgentSBOMRepository() *AgentSBOMRepository {
+	return &AgentSBOMRepository{sboms: newTable[domain.AgentSBOM]()}
+}
+
+func (r *AgentSBOMRepository) Create(sbom *domain.AgentSBOM) error {
+	sbom.ID = newID(sbom.ID)
+	if sbom.CreatedAt.IsZero() {
+		sbom.CreatedAt = time.Now().UTC()
+	}
+	r.sboms.put(sbom.ID, *sbom)
+	return nil
+}
+
+func (r *AgentSBOMRepository) GetByID(id uuid.UUID) (*domain.AgentSBOM, error) {
+	sbom, ok := r.sboms.get(id)
+	if !ok {
+		return nil, fmt.Errorf("sbom not found")
+	}
+	return sbom, nil
+}
+
+func (r *AgentSBOMRepository) GetByAgent(agentID uuid.UUID) ([]*domain.AgentSBOM, error) {
+	sboms := r.sboms.find(func(s *domain.AgentSBOM) bool { return s.AgentID == agentID })
+	for _, sbom := range sboms {
+		sbom.Document = nil
+	}
+	return sboms, nil
+}
+
+func (r *AgentSBOMRepository) GetLatestByAgent(agentID uuid.UUID) (*domain.AgentSBOM, error) {
+	sbom, ok := r.sboms.first(func(s *domain.AgentSBOM) bool { return s.AgentID == agentID })
+	if !ok {
+		return nil, fmt.Errorf("sbom not found")
+	}
+	sbom.Document = nil
+	return sbom, nil
+}
+
+func (r *AgentSBOMRepository) UpdateScan(sbom *domain.AgentSBOM) error {
+	if !r.sboms.update(sbom.ID, func(s *domain.AgentSBOM) {
+		s.Vulnerabilities = sbom.Vulnerabilities
+		s.VulnerabilityCount = sbom.VulnerabilityCount
+		s.CriticalCount = sbom.CriticalCount
+		s.HighCount = sbom.HighCount
+		s.ScanStatus = sbom.ScanStatus
+		s.ScanError = sbom.ScanError
+		s.ScannedAt = sbom.ScannedAt
+	}) {
+		return fmt.Errorf("sbom not found")
+	}
+	return nil
+}
+
+func (r *AgentSBOMRepository) GetDueForRescan(scannedBefore time.Time, limit int) ([]*domain.AgentSBOM, error) {
+	latest := make(map[uuid.UUID]bool)
+	due := r.sboms.find(func(s *domain.AgentSBOM) bool {
+		if latest[s.AgentID] {
+			return false
+		}
+		latest[s.AgentID] = true
+		return s.ScannedAt == nil || s.ScannedAt.Before(scannedBefore)
+	})
+	return paginate(due, limit, 0), nil
+}
+
+// AgentPeerPolicyRepository is an in-memory domain.AgentPeerPolicyRepository
+type AgentPeerPolicyRepository struct {
+	policies *table[domain.AgentPeerPolicy]
+}
+
+// NewAgentPeerPolicyRepository creates an empty in-memory agent peer policy repository
+func NewAgentPeerPolicyRepository() *AgentPeerPolicyRepository {
+	return &AgentPeerPolicyRepository{policies: newTable[domain.AgentPeerPolicy]()}
+}
+
+func (r *AgentPeerPolicyRepository) Create(policy *domain.AgentPeerPolicy) error {
+	if _, exists := r.policies.first(func(p *domain.AgentPeerPolicy) bool {
+		return p.SourceAgentID == policy.SourceAgentID && p.TargetAgentID == policy.TargetAgentID
+	}); exists {
+		return domain.ErrAgentPeerPolicyExists
+	}
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+	r.policies.put(policy.ID, *policy)
+	return nil
+}
+
+func (r *AgentPeerPolicyRepository) GetByID(id uuid.UUID) (*domain.AgentPeerPolicy, error) {
+	policy, ok := r.policies.get(id)
+	if !ok {
+		return nil, domain.ErrAgentPeerPolicyNotFound
+	}
+	return policy, nil
+}
+
+func (r *AgentPeerPolicyRepository) ListByOrganization(orgID uuid.UUID) ([]*domain.AgentPeerPolicy, error) {
+	return r.oldestFirst(func(p *domain.AgentPeerPolicy) bool { return p.OrganizationID == orgID }), nil
+}
+
+func (r *AgentPeerPolicyRepository) ListByAgent(agentID uuid.UUID) ([]*domain.AgentPeerPolicy, error) {
+	return r.oldestFirst(func(p *domain.AgentPeerPolicy) bool {
+		return p.SourceAgentID == agentID || p.TargetAgentID == agentID
+	}), nil
+}
+
+func (r *AgentPeerPolicyRepository) Update(policy *domain.AgentPeerPolicy) error {
+	if !r.policies.replace(policy.ID, *policy) {
+		return domain.ErrAgentPeerPolicyNotFound
+	}
+	return nil
+}
+
+func (r *AgentPeerPolicyRepository) Delete(id uuid.UUID) error {
+	if !r.policies.remove(id) {
+		return domain.ErrAgentPeerPolicyNotFound
+	}
+	return nil
+}
+
+func (r *AgentPeerPolicyRepository) oldestFirst(match func(*domain.AgentPeerPolicy) bool) []*domain.AgentPeerPolicy {
+	policies := r.policies.find(match)
+	slices.Reverse(policies)
+	return policies
+}
+
+// MCPToolGrantRepository is an in-memory domain.MCPToolGrantRepository
+type MCPToolGrantRepository struct {
+	grants *table[domain.MCPToolGrant]
+}
+
+// NewMCPToolGrantRepository creates an empty in-memory MCP tool grant repository
+func NewMCPToolGrantRepository() *MCPToolGrantRepository {
+	return &MCPToolGrantRepository{grants: newTable[domain.MCPToolGrant]()}
+}
+
+func (r *MCPToolGrantRepository) Upsert(grant *domain.MCPToolGrant) error {
+	if existing, ok := r.grants.first(func(g *domain.MCPToolGrant) bool {
+		return g.AgentID == grant.AgentID && g.MCPServer == grant.MCPServer
+	}); ok {
+		grant.ID = existing.ID
+		grant.CreatedBy = existing.CreatedBy
+		grant.CreatedAt = existing.CreatedAt
+	}
+	grant.ID = newID(grant.ID)
+	stored := *grant
+	stored.Tools = slices.Clone(grant.Tools)
+	r.grants.put(grant.ID, stored)
+	return nil
+}
+
+func (r *MCPToolGrantRepository) ListByAgent(agentID uuid.UUID) ([]*domain.MCPToolGrant, error) {
+	return oldestFirst(r.grants.find(func(g *domain.MCPToolGrant) bool { return g.AgentID == agentID })), nil
+}
+
+func (r *MCPToolGrantRepository) Delete(agentID uuid.UUID, mcpServer string) error {
+	if r.grants.removeWhere(func(g *domain.MCPToolGrant) bool {
+		return g.AgentID == agentID && g.MCPServer == mcpServer
+	}) == 0 {
+		return domain.ErrMCPToolGrantNotFound
+	}
+	return nil
+}
+
+// AgentLineageRepository is an in-memory domain.AgentLineageRepository
+type AgentLineageRepository struct {
+	entries *table[domain.AgentLineageEntry]
+}
+
+// NewAgentLineageRepository creates an empty in-memory agent lineage repository
+func NewAgentLineageRepository() *AgentLineageRepository {
+	return &AgentLineageRepository{entries: newTable[domain.AgentLineageEntry]()}
+}
+
+func (r *AgentLineageRepository) Create(entry *domain.AgentLineageEntry) error {
+	entry.ID = newID(entry.ID)
+	r.entries.put(entry.ID, *entry)
+	return nil
+}
+
+func (r *AgentLineageRepository) ListByAgent(agentID uuid.UUID) ([]*domain.AgentLineageEntry, error) {
+	entries := r.entries.find(func(e *domain.AgentLineageEntry) bool { return e.AgentID == agentID })
+	slices.SortStableFunc(entries, func(a, b *domain.AgentLineageEntry) int { return a.ImportedAt.Compare(b.ImportedAt) })
+	return entries, nil
+}
+
+// AgentDelegationRepository is an in-memory domain.AgentDelegationRepository
+type AgentDelegationRepository struct {
+	delegations *table[domain.AgentDelegation]
+}
+
+// NewAgentDelegationRepository creates an empty in-memory agent delegation repository
+func NewAgentDelegationRepository() *AgentDelegationRepository {
+	return &AgentDelegationRepository{delegations: newTable[domain.AgentDelegation]()}
+}
+
+func (r *AgentDelegationRepository) Create(delegation *domain.AgentDelegation) error {
+	if _, exists := r.delegations.first(func(d *domain.AgentDelegation) bool {
+		return d.ChildAgentID == delegation.ChildAgentID
+	}); exists {
+		return fmt.Errorf("agent %s was already delegated", delegation.ChildAgentID)
+	}
+	delegation.ID = newID(delegation.ID)
+	r.delegations.put(delegation.ID, *delegation)
+	return nil
+}
+
+func (r *AgentDelegationRepository) GetByChild(childAgentID uuid.UUID) (*domain.AgentDelegation, error) {
+	delegation, ok := r.delegations.first(func(d *domain.AgentDelegation) bool { return d.ChildAgentID == childAgentID })
+	if !ok {
+		return nil, domain.ErrAgentDelegationNotFound
+	}
+	return delegation, nil
+}
+
+func (r *AgentDelegationRepository) ListByParent(parentAgentID uuid.UUID) ([]*domain.AgentDelegation, error) {
+	delegations := r.delegations.find(func(d *domain.AgentDelegation) bool { return d.ParentAgentID == parentAgentID })
+	slices.Reverse(delegations)
+	return delegations, nil
+}
+
+func (r *AgentDelegationRepository) MarkRevoked(childAgentID uuid.UUID, revokedAt time.Time, reason string) error {
+	r.delegations.updateWhere(func(d *domain.AgentDelegation) bool {
+		return d.ChildAgentID == childAgentID && d.RevokedAt == nil
+	}, func(d *domain.AgentDelegation) {
+		d.RevokedAt = &revokedAt
+		d.RevocationReason = reason
+	})
+	return nil
+}
@@ -0,0 +1,181 @@
+package testsupport
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.AgentTimelineRepository = (*AgentTimelineRepository)(nil)
+
+// AgentTimelineRepository is an in-memory domain.AgentTimelineRepository reading the
+// records of the other in-memory repositories
+type AgentTimelineRepository struct {
+	events       *VerificationEventRepository
+	attestations *MCPAttestationRepository
+	servers      *MCPServerRepository
+	trustScores  *TrustScoreRepository
+	auditLogs    *AuditLogRepository
+	capabilities *CapabilityRepository
+	deprecations *CapabilityDeprecationRepository
+	alerts       *AlertRepository
+}
+
+// NewAgentTimelineRepository creates an agent timeline repository over the given repositories
+func NewAgentTimelineRepository(
+	events *VerificationEventRepository,
+	attestations *MCPAttestationRepository,
+	servers *MCPServerRepository,
+	trustScores *TrustScoreRepository,
+	auditLogs *AuditLogRepository,
+	capabilities *CapabilityRepository,
+	deprecations *CapabilityDeprecationRepository,
+	alerts *AlertRepository,
+) *AgentTimelineRepository {
+	return &AgentTimelineRepository{
+		events:       events,
+		attestations: attestations,
+		servers:      servers,
+		trustScores:  trustScores,
+		auditLogs:    auditLogs,
+		capabilities: capabilities,
+		deprecations: deprecations,
+		alerts:       alerts,
+	}
+}
+
+func (r *AgentTimelineRepository) GetTimeline(agentID uuid.UUID, query domain.AgentTimelineQuery) ([]*domain.AgentTimelineEntry, int, error) {
+	types := query.Types
+	if len(types) == 0 {
+		types = domain.AgentTimelineEntryTypes
+	}
+
+	var entries []*domain.AgentTimelineEntry
+	add := func(entryType domain.AgentTimelineEntryType, sourceID uuid.UUID, at time.Time, details map[string]interface{}) {
+		if query.Start != nil && at.Before(*query.Start) {
+			return
+		}
+		if query.End != nil && !at.Before(*query.End) {
+			return
+		}
+		// Round-trip the details so they hold the same JSON types the SQL repository decodes
+		data, _ := json.Marshal(details)
+		decoded := map[string]interface{}{}
+		_ = json.Unmarshal(data, &decoded)
+		entries = append(entries, &domain.AgentTimelineEntry{Type: entryType, SourceID: sourceID, OccurredAt: at, Details: decoded})
+	}
+
+	for _, entryType := range types {
+		switch entryType {
+		case domain.TimelineVerification, domain.TimelineDrift:
+			for _, event := range r.events.events.find(func(e *domain.VerificationEvent) bool {
+				return e.AgentID != nil && *e.AgentID == agentID
+			}) {
+				if entryType == domain.TimelineVerification {
+					add(entryType, event.ID, event.StartedAt, map[string]interface{}{
+						"status": event.Status, "result": event.Result, "protocol": event.Protocol,
+						"verificationType": event.VerificationType, "action": event.Action,
+						"resourceType": event.ResourceType, "errorReason": event.ErrorReason,
+						"driftDetected": event.DriftDetected,
+					})
+				} else if event.DriftDetected {
+					add(entryType, event.ID, event.StartedAt, map[string]interface{}{
+						"status": event.Status, "mcpServerDrift": nonNilStrings(event.MCPServerDrift),
+						"mcpToolDrift":    nonNilStrings(event.MCPToolDrift),
+						"capabilityDrift": nonNilStrings(event.CapabilityDrift),
+					})
+				}
+			}
+		case domain.TimelineAttestation:
+			for _, attestation := range r.attestations.attestations.find(func(a *domain.MCPAttestation) bool {
+				return a.AgentID != nil && *a.AgentID == agentID
+			}) {
+				var serverName interface{}
+				if server, err := r.servers.GetByID(attestation.MCPServerID); err == nil {
+					serverName = server.Name
+				}
+				at := attestation.CreatedAt
+				if attestation.VerifiedAt != nil {
+					at = *attestation.VerifiedAt
+				}
+				add(entryType, attestation.ID, at, map[string]interface{}{
+					"mcpServerId": attestation.MCPServerID, "mcpServerName": serverName,
+					"mcpUrl": attestation.AttestationData.MCPURL, "capabilitiesFound": nonNilStrings(attestation.AttestationData.CapabilitiesFound),
+					"isValid": attestation.IsValid, "expiresAt": attestation.ExpiresAt,
+				})
+			}
+		case domain.TimelineTrustChange:
+			history, _ := r.trustScores.GetHistoryAuditTrail(agentID, 0)
+			for _, change := range history {
+				add(entryType, change.ID, change.RecordedAt, map[string]interface{}{
+					"trustScore": change.TrustScore, "previousScore": change.PreviousScore, "reason": change.ChangeReason,
+				})
+			}
+		case domain.TimelineKeyRotation:
+			for _, log := range r.auditLogs.logs.find(func(l *domain.AuditLog) bool {
+				action, _ := l.Metadata["action"].(string)
+				return l.ResourceType == "agent" && l.ResourceID == agentID && (action == "rotate_key" || action == "rotate_credentials")
+			}) {
+				add(entryType, log.ID, log.Timestamp, map[string]interface{}{
+					"action": log.Metadata["action"], "userId": log.UserID, "rotationCount": log.Metadata["rotationCount"],
+					"keyRotationGraceUntil": log.Metadata["keyRotationGraceUntil"],
+				})
+			}
+		case domain.TimelineCapabilityChange:
+			for _, capability := range r.capabilities.capabilities.find(func(c *domain.AgentCapability) bool {
+				return c.AgentID == agentID
+			}) {
+				add(entryType, capability.ID, capability.GrantedAt, map[string]interface{}{
+					"change": "granted", "capabilityType": capability.CapabilityType, "grantedBy": capability.GrantedBy,
+				})
+				if capability.RevokedAt != nil {
+					add(entryType, capability.ID, *capability.RevokedAt, map[string]interface{}{
+						"change": "revoked", "capabilityType": capability.CapabilityType,
+					})
+				}
+			}
+			for _, deprecation := range r.deprecations.deprecations.find(func(d *domain.CapabilityDeprecation) bool {
+				return d.AgentID == agentID
+			}) {
+				add(entryType, deprecation.ID, deprecation.DeprecatedAt, map[string]interface{}{
+					"change": "deprecated", "capabilityType": deprecation.CapabilityType,
+					"mcpServerId": deprecation.MCPServerID, "toolName": deprecation.ToolName, "resolvedAt": deprecation.ResolvedAt,
+				})
+			}
+		case domain.TimelineAlert:
+			for _, alert := range r.alerts.alerts.find(func(a *domain.Alert) bool {
+				return a.ResourceID == agentID
+			}) {
+				add(entryType, alert.ID, alert.CreatedAt, map[string]interface{}{
+					"alertType": alert.AlertType, "severity": alert.Severity, "title": alert.Title,
+					"description": alert.Description, "isAcknowledged": alert.IsAcknowledged,
+				})
+			}
+		default:
+			return nil, 0, fmt.Errorf("unknown timeline entry type %q", entryType)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].OccurredAt.Equal(entries[j].OccurredAt) {
+			return entries[i].OccurredAt.After(entries[j].OccurredAt)
+		}
+		if entries[i].Type != entries[j].Type {
+			return entries[i].Type < entries[j].Type
+		}
+		return entries[i].SourceID.String() < entries[j].SourceID.String()
+	})
+	return paginate(entries, query.Limit, query.Offset), len(entries), nil
+}
+
+// nonNilStrings returns an empty list for nil, matching the SQL repository's COALESCE to '[]'
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
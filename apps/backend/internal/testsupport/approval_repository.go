@@ -0,0 +1,162 @@
+package testsupport
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	_ domain.ApprovalChainRepository   = (*ApprovalChainRepository)(nil)
+	_ domain.ApprovalRequestRepository = (*ApprovalRequestRepository)(nil)
+)
+
+// ApprovalChainRepository is an in-memory domain.ApprovalChainRepository
+type ApprovalChainRepository struct {
+	chains *table[domain.ApprovalChain]
+}
+
+// NewApprovalChainRepository creates an empty in-memory approval chain repository
+func NewApprovalChainRepository() *ApprovalChainRepository {
+	return &ApprovalChainRepository{chains: newTable[domain.ApprovalChain]()}
+}
+
+func (r *ApprovalChainRepository) Create(chain *domain.ApprovalChain) error {
+	now := time.Now()
+	chain.ID = newID(chain.ID)
+	chain.CreatedAt = now
+	chain.UpdatedAt = now
+	r.chains.put(chain.ID, cloneApprovalChain(chain))
+	return nil
+}
+
+func (r *ApprovalChainRepository) GetByID(id uuid.UUID) (*domain.ApprovalChain, error) {
+	chain, ok := r.chains.get(id)
+	if !ok {
+		return nil, fmt.Errorf("approval chain not found")
+	}
+	return chain, nil
+}
+
+// GetByOrganization lists the organization's chains oldest first, like the SQL repository
+func (r *ApprovalChainRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.ApprovalChain, error) {
+	return oldestFirst(r.chains.find(func(c *domain.ApprovalChain) bool {
+		return c.OrganizationID == orgID
+	})), nil
+}
+
+func (r *ApprovalChainRepository) GetEnabledByAction(orgID uuid.UUID, action domain.ApprovalAction) ([]*domain.ApprovalChain, error) {
+	return oldestFirst(r.chains.find(func(c *domain.ApprovalChain) bool {
+		return c.OrganizationID == orgID && c.Action == action && c.IsEnabled
+	})), nil
+}
+
+func (r *ApprovalChainRepository) Update(chain *domain.ApprovalChain) error {
+	chain.UpdatedAt = time.Now()
+	if !r.chains.replace(chain.ID, cloneApprovalChain(chain)) {
+		return fmt.Errorf("approval chain not found")
+	}
+	return nil
+}
+
+func (r *ApprovalChainRepository) Delete(id uuid.UUID) error {
+	r.chains.remove(id)
+	return nil
+}
+
+// ApprovalRequestRepository is an in-memory domain.ApprovalRequestRepository
+type ApprovalRequestRepository struct {
+	requests *table[domain.ApprovalRequest]
+}
+
+// NewApprovalRequestRepository creates an empty in-memory approval request repository
+func NewApprovalRequestRepository() *ApprovalRequestRepository {
+	return &ApprovalRequestRepository{requests: newTable[domain.ApprovalRequest]()}
+}
+
+// Create opens the request, rejecting a second pending request for the same action and
+// resource like the partial unique index does
+func (r *ApprovalRequestRepository) Create(request *domain.ApprovalRequest) error {
+	if _, err := r.GetPending(request.OrganizationID, request.Action, request.ResourceID); err == nil {
+		return fmt.Errorf("approval request already pending for %s %s", request.Action, request.ResourceID)
+	}
+	request.ID = newID(request.ID)
+	if request.CreatedAt.IsZero() {
+		request.CreatedAt = time.Now()
+	}
+	r.requests.put(request.ID, cloneApprovalRequest(request))
+	return nil
+}
+
+func (r *ApprovalRequestRepository) GetByID(id uuid.UUID) (*domain.ApprovalRequest, error) {
+	request, ok := r.requests.get(id)
+	if !ok {
+		return nil, fmt.Errorf("approval request not found")
+	}
+	return request, nil
+}
+
+func (r *ApprovalRequestRepository) GetPending(orgID uuid.UUID, action domain.ApprovalAction, resourceID uuid.UUID) (*domain.ApprovalRequest, error) {
+	request, ok := r.requests.first(func(req *domain.ApprovalRequest) bool {
+		return req.OrganizationID == orgID && req.Action == action && req.ResourceID == resourceID &&
+			req.Status == domain.ApprovalRequestStatusPending
+	})
+	if !ok {
+		return nil, fmt.Errorf("approval request not found")
+	}
+	return request, nil
+}
+
+func (r *ApprovalRequestRepository) GetByOrganization(orgID uuid.UUID, status domain.ApprovalRequestStatus, limit, offset int) ([]*domain.ApprovalRequest, error) {
+	return paginate(r.requests.find(func(req *domain.ApprovalRequest) bool {
+		return req.OrganizationID == orgID && (status == "" || req.Status == status)
+	}), limit, offset), nil
+}
+
+func (r *ApprovalRequestRepository) GetByUser(orgID, userID uuid.UUID) ([]*domain.ApprovalRequest, error) {
+	return r.requests.find(func(req *domain.ApprovalRequest) bool {
+		if req.OrganizationID != orgID {
+			return false
+		}
+		if req.RequestedBy != nil && *req.RequestedBy == userID {
+			return true
+		}
+		return slices.ContainsFunc(req.Decisions, func(decision domain.ApprovalDecision) bool {
+			return decision.UserID == userID
+		})
+	}), nil
+}
+
+// RecordDecision applies the same pending/decision-count guard as the SQL conditional update
+func (r *ApprovalRequestRepository) RecordDecision(request *domain.ApprovalRequest, expectedDecisions int) (bool, error) {
+	recorded := false
+	r.requests.update(request.ID, func(stored *domain.ApprovalRequest) {
+		if stored.Status != domain.ApprovalRequestStatusPending || len(stored.Decisions) != expectedDecisions {
+			return
+		}
+		stored.Decisions = slices.Clone(request.Decisions)
+		stored.Status = request.Status
+		stored.CompletedAt = request.CompletedAt
+		recorded = true
+	})
+	return recorded, nil
+}
+
+// cloneApprovalChain copies the chain so stored slices are not shared with the caller
+func cloneApprovalChain(chain *domain.ApprovalChain) domain.ApprovalChain {
+	stored := *chain
+	stored.Steps = slices.Clone(chain.Steps)
+	stored.AgentTags = slices.Clone(chain.AgentTags)
+	return stored
+}
+
+// cloneApprovalRequest copies the request so stored slices are not shared with the caller
+func cloneApprovalRequest(request *domain.ApprovalRequest) domain.ApprovalRequest {
+	stored := *request
+	stored.Steps = slices.Clone(request.Steps)
+	stored.Decisions = slices.Clone(request.Decisions)
+	return stored
+}
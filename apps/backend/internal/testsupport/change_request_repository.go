@@ -0,0 +1,81 @@
+package testsupport
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.ChangeRequestRepository = (*ChangeRequestRepository)(nil)
+
+// ChangeRequestRepository is an in-memory domain.ChangeRequestRepository
+type ChangeRequestRepository struct {
+	changes *table[domain.ChangeRequest]
+}
+
+// NewChangeRequestRepository creates an empty in-memory change request repository
+func NewChangeRequestRepository() *ChangeRequestRepository {
+	return &ChangeRequestRepository{changes: newTable[domain.ChangeRequest]()}
+}
+
+func (r *ChangeRequestRepository) Create(change *domain.ChangeRequest) error {
+	now := time.Now()
+	change.ID = newID(change.ID)
+	change.CreatedAt = now
+	change.UpdatedAt = now
+	r.changes.put(change.ID, cloneChangeRequest(change))
+	return nil
+}
+
+func (r *ChangeRequestRepository) GetByID(id uuid.UUID) (*domain.ChangeRequest, error) {
+	change, ok := r.changes.get(id)
+	if !ok {
+		return nil, domain.ErrChangeRequestNotFound
+	}
+	return change, nil
+}
+
+func (r *ChangeRequestRepository) GetByOrganization(orgID uuid.UUID, status domain.ChangeRequestStatus, limit, offset int) ([]*domain.ChangeRequest, int, error) {
+	changes := r.changes.find(func(c *domain.ChangeRequest) bool {
+		return c.OrganizationID == orgID && (status == "" || c.Status == status)
+	})
+	return paginate(changes, limit, offset), len(changes), nil
+}
+
+// GetByStatus orders the changes by effective time, like the SQL repository
+func (r *ChangeRequestRepository) GetByStatus(statuses ...domain.ChangeRequestStatus) ([]*domain.ChangeRequest, error) {
+	changes := r.changes.find(func(c *domain.ChangeRequest) bool {
+		return slices.Contains(statuses, c.Status)
+	})
+	slices.SortStableFunc(changes, func(a, b *domain.ChangeRequest) int {
+		return a.EffectiveAt.Compare(b.EffectiveAt)
+	})
+	return changes, nil
+}
+
+func (r *ChangeRequestRepository) Update(change *domain.ChangeRequest) error {
+	change.UpdatedAt = time.Now()
+	if !r.changes.update(change.ID, func(stored *domain.ChangeRequest) {
+		// The target and the change itself are immutable, as in the SQL repository
+		updated := cloneChangeRequest(change)
+		updated.TargetType = stored.TargetType
+		updated.TargetID = stored.TargetID
+		updated.Changes = stored.Changes
+		updated.CreatedAt = stored.CreatedAt
+		*stored = updated
+	}) {
+		return domain.ErrChangeRequestNotFound
+	}
+	return nil
+}
+
+// cloneChangeRequest copies the change request so stored maps are not shared with the caller
+func cloneChangeRequest(change *domain.ChangeRequest) domain.ChangeRequest {
+	stored := *change
+	stored.Changes = maps.Clone(change.Changes)
+	stored.Previous = maps.Clone(change.Previous)
+	return stored
+}
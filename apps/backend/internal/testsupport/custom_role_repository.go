@@ -0,0 +1,110 @@
+package testsupport
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.CustomRoleRepository = (*CustomRoleRepository)(nil)
+
+// CustomRoleRepository is an in-memory domain.CustomRoleRepository
+type CustomRoleRepository struct {
+	roles       *table[domain.CustomRole]
+	assignments *table[domain.CustomRoleAssignment]
+}
+
+// NewCustomRoleRepository creates an empty in-memory custom role repository
+func NewCustomRoleRepository() *CustomRoleRepository {
+	return &CustomRoleRepository{
+		roles:       newTable[domain.CustomRole](),
+		assignments: newTable[domain.CustomRoleAssignment](),
+	}
+}
+
+func (r *CustomRoleRepository) Create(role *domain.CustomRole) error {
+	role.ID = newID(role.ID)
+	now := time.Now()
+	role.CreatedAt, role.UpdatedAt = now, now
+	stored := *role
+	stored.Permissions = append([]domain.Permission{}, role.Permissions...)
+	r.roles.put(stored.ID, stored)
+	return nil
+}
+
+func (r *CustomRoleRepository) GetByID(id uuid.UUID) (*domain.CustomRole, error) {
+	role, ok := r.roles.get(id)
+	if !ok {
+		return nil, domain.ErrCustomRoleNotFound
+	}
+	return r.withUserCount(role), nil
+}
+
+func (r *CustomRoleRepository) ListByOrganization(orgID uuid.UUID) ([]*domain.CustomRole, error) {
+	roles := r.roles.find(func(role *domain.CustomRole) bool { return role.OrganizationID == orgID })
+	return r.byName(roles), nil
+}
+
+func (r *CustomRoleRepository) Update(role *domain.CustomRole) error {
+	role.UpdatedAt = time.Now()
+	if !r.roles.update(role.ID, func(stored *domain.CustomRole) {
+		stored.Name, stored.Description, stored.UpdatedAt = role.Name, role.Description, role.UpdatedAt
+		stored.Permissions = append([]domain.Permission{}, role.Permissions...)
+	}) {
+		return domain.ErrCustomRoleNotFound
+	}
+	return nil
+}
+
+func (r *CustomRoleRepository) Delete(id uuid.UUID) error {
+	r.roles.remove(id)
+	r.assignments.removeWhere(func(a *domain.CustomRoleAssignment) bool { return a.RoleID == id })
+	return nil
+}
+
+func (r *CustomRoleRepository) Assign(assignment *domain.CustomRoleAssignment) error {
+	if _, ok := r.assignments.first(func(a *domain.CustomRoleAssignment) bool {
+		return a.RoleID == assignment.RoleID && a.UserID == assignment.UserID
+	}); ok {
+		return nil
+	}
+	if assignment.AssignedAt.IsZero() {
+		assignment.AssignedAt = time.Now()
+	}
+	r.assignments.put(uuid.New(), *assignment)
+	return nil
+}
+
+func (r *CustomRoleRepository) Unassign(roleID, userID uuid.UUID) error {
+	r.assignments.removeWhere(func(a *domain.CustomRoleAssignment) bool { return a.RoleID == roleID && a.UserID == userID })
+	return nil
+}
+
+func (r *CustomRoleRepository) ListUsers(roleID uuid.UUID) ([]*domain.CustomRoleAssignment, error) {
+	return oldestFirst(r.assignments.find(func(a *domain.CustomRoleAssignment) bool { return a.RoleID == roleID })), nil
+}
+
+func (r *CustomRoleRepository) ListForUser(userID uuid.UUID) ([]*domain.CustomRole, error) {
+	roles := make([]*domain.CustomRole, 0)
+	for _, assignment := range r.assignments.find(func(a *domain.CustomRoleAssignment) bool { return a.UserID == userID }) {
+		if role, ok := r.roles.get(assignment.RoleID); ok {
+			roles = append(roles, role)
+		}
+	}
+	return r.byName(roles), nil
+}
+
+func (r *CustomRoleRepository) byName(roles []*domain.CustomRole) []*domain.CustomRole {
+	for _, role := range roles {
+		r.withUserCount(role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles
+}
+
+func (r *CustomRoleRepository) withUserCount(role *domain.CustomRole) *domain.CustomRole {
+	role.UserCount = len(r.assignments.find(func(a *domain.CustomRoleAssignment) bool { return a.RoleID == role.ID }))
+	return role
+}
@@ -0,0 +1,52 @@
+package testsupport
+
+import (
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.DataPlacementRepository = (*DataPlacementRepository)(nil)
+
+// DataPlacementRepository is an in-memory domain.DataPlacementRepository
+type DataPlacementRepository struct {
+	placements *table[domain.DataPlacement]
+}
+
+// NewDataPlacementRepository creates an empty in-memory data placement repository
+func NewDataPlacementRepository() *DataPlacementRepository {
+	return &DataPlacementRepository{placements: newTable[domain.DataPlacement]()}
+}
+
+func (r *DataPlacementRepository) Record(placement *domain.DataPlacement) error {
+	if _, ok := r.placements.get(placement.ResourceID); !ok {
+		r.placements.put(placement.ResourceID, *placement)
+	}
+	return nil
+}
+
+func (r *DataPlacementRepository) Get(resourceID uuid.UUID) (*domain.DataPlacement, error) {
+	placement, ok := r.placements.get(resourceID)
+	if !ok {
+		return nil, nil
+	}
+	return placement, nil
+}
+
+func (r *DataPlacementRepository) GetMany(resourceIDs []uuid.UUID) ([]*domain.DataPlacement, error) {
+	return r.placements.getMany(resourceIDs), nil
+}
+
+func (r *DataPlacementRepository) Delete(resourceID uuid.UUID) error {
+	r.placements.remove(resourceID)
+	return nil
+}
+
+func (r *DataPlacementRepository) CountByOrganization(orgID uuid.UUID) (map[domain.DataPlacementKind]int, error) {
+	counts := make(map[domain.DataPlacementKind]int)
+	for _, placement := range r.placements.find(func(placement *domain.DataPlacement) bool {
+		return placement.OrganizationID == orgID
+	}) {
+		counts[placement.Kind]++
+	}
+	return counts, nil
+}
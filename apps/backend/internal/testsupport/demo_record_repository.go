@@ -0,0 +1,42 @@
+package testsupport
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.DemoRecordRepository = (*DemoRecordRepository)(nil)
+
+// DemoRecordRepository is an in-memory domain.DemoRecordRepository
+type DemoRecordRepository struct {
+	records *table[domain.DemoRecord]
+}
+
+// NewDemoRecordRepository creates an empty in-memory demo record repository
+func NewDemoRecordRepository() *DemoRecordRepository {
+	return &DemoRecordRepository{records: newTable[domain.DemoRecord]()}
+}
+
+func (r *DemoRecordRepository) Create(record *domain.DemoRecord) error {
+	record.ID = newID(record.ID)
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now().UTC()
+	}
+	r.records.put(record.ID, *record)
+	return nil
+}
+
+func (r *DemoRecordRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.DemoRecord, error) {
+	return oldestFirst(r.records.find(func(record *domain.DemoRecord) bool {
+		return record.OrganizationID == orgID
+	})), nil
+}
+
+func (r *DemoRecordRepository) DeleteByOrganization(orgID uuid.UUID) error {
+	r.records.removeWhere(func(record *domain.DemoRecord) bool {
+		return record.OrganizationID == orgID
+	})
+	return nil
+}
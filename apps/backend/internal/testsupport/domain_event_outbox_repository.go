@@ -0,0 +1,95 @@
+package testsupport
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.DomainEventOutboxRepository = (*DomainEventOutboxRepository)(nil)
+
+// DomainEventOutboxRepository is an in-memory domain.DomainEventOutboxRepository. ClaimDue claims
+// under one lock, so concurrent relays never claim the same event like they do against the database.
+type DomainEventOutboxRepository struct {
+	mu     sync.Mutex
+	events *table[domain.DomainEvent]
+}
+
+// NewDomainEventOutboxRepository creates an empty in-memory domain event outbox
+func NewDomainEventOutboxRepository() *DomainEventOutboxRepository {
+	return &DomainEventOutboxRepository{events: newTable[domain.DomainEvent]()}
+}
+
+func (r *DomainEventOutboxRepository) Append(event *domain.DomainEvent) error {
+	event.ID = newID(event.ID)
+	if event.Status == "" {
+		event.Status = domain.DomainEventPending
+	}
+	if event.NextAttemptAt.IsZero() {
+		event.NextAttemptAt = event.OccurredAt
+	}
+	stored := *event
+	stored.Payload = append([]byte(nil), event.Payload...)
+	r.events.put(stored.ID, stored)
+	return nil
+}
+
+func (r *DomainEventOutboxRepository) ClaimDue(limit int, lease time.Duration) ([]*domain.DomainEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	due := r.events.find(func(event *domain.DomainEvent) bool {
+		return event.Status != domain.DomainEventPublished && !event.NextAttemptAt.After(now)
+	})
+	slices.Reverse(due)
+	due = paginate(due, limit, 0)
+	for _, event := range due {
+		event.Status = domain.DomainEventPublishing
+		event.NextAttemptAt = now.Add(lease)
+		r.events.update(event.ID, func(stored *domain.DomainEvent) {
+			stored.Status, stored.NextAttemptAt = event.Status, event.NextAttemptAt
+		})
+	}
+	return due, nil
+}
+
+func (r *DomainEventOutboxRepository) MarkPublished(ids []uuid.UUID, publishedAt time.Time) error {
+	for _, id := range ids {
+		r.events.update(id, func(event *domain.DomainEvent) {
+			event.Status = domain.DomainEventPublished
+			event.PublishedAt = &publishedAt
+			event.Attempts++
+			event.LastError = ""
+		})
+	}
+	return nil
+}
+
+func (r *DomainEventOutboxRepository) MarkFailed(ids []uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	for _, id := range ids {
+		r.events.update(id, func(event *domain.DomainEvent) {
+			event.Status = domain.DomainEventPending
+			event.NextAttemptAt = nextAttemptAt
+			event.Attempts++
+			event.LastError = lastError
+		})
+	}
+	return nil
+}
+
+func (r *DomainEventOutboxRepository) DeletePublished(before time.Time) (int, error) {
+	return r.events.removeWhere(func(event *domain.DomainEvent) bool {
+		return event.Status == domain.DomainEventPublished && event.PublishedAt.Before(before)
+	}), nil
+}
+
+// List returns the recorded events, oldest first
+func (r *DomainEventOutboxRepository) List() []*domain.DomainEvent {
+	events := r.events.find(nil)
+	slices.Reverse(events)
+	return events
+}
@@ -0,0 +1,91 @@
+package testsupport
+
+import (
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.DriftAnalyticsRepository = (*DriftAnalyticsRepository)(nil)
+
+// DriftAnalyticsRepository is an in-memory domain.DriftAnalyticsRepository reading the
+// verification events, alerts, tags and capability deprecations of the other in-memory repositories
+type DriftAnalyticsRepository struct {
+	events       *VerificationEventRepository
+	alerts       *AlertRepository
+	agents       *AgentRepository
+	tags         *TagRepository
+	deprecations *CapabilityDeprecationRepository
+}
+
+// NewDriftAnalyticsRepository creates a drift analytics repository over the given repositories
+func NewDriftAnalyticsRepository(
+	events *VerificationEventRepository,
+	alerts *AlertRepository,
+	agents *AgentRepository,
+	tags *TagRepository,
+	deprecations *CapabilityDeprecationRepository,
+) *DriftAnalyticsRepository {
+	return &DriftAnalyticsRepository{events: events, alerts: alerts, agents: agents, tags: tags, deprecations: deprecations}
+}
+
+func (r *DriftAnalyticsRepository) GetDriftOccurrences(filter *domain.DriftAnalyticsFilter) ([]*domain.DriftOccurrence, error) {
+	events := r.events.events.find(func(e *domain.VerificationEvent) bool {
+		return e.OrganizationID == filter.OrganizationID && e.DriftDetected && e.AgentID != nil &&
+			!e.CreatedAt.Before(filter.Start) && e.CreatedAt.Before(filter.End) &&
+			r.matchesAgent(filter, *e.AgentID)
+	})
+
+	occurrences := make([]*domain.DriftOccurrence, 0, len(events))
+	for _, e := range oldestFirst(events) {
+		name := "unknown"
+		if agent, err := r.agents.GetByID(*e.AgentID); err == nil {
+			name = agent.DisplayName
+		} else if e.AgentName != nil {
+			name = *e.AgentName
+		}
+		occurrences = append(occurrences, &domain.DriftOccurrence{
+			AgentID:         *e.AgentID,
+			AgentName:       name,
+			MCPServerDrift:  append([]string{}, e.MCPServerDrift...),
+			CapabilityDrift: append([]string{}, e.CapabilityDrift...),
+			DetectedAt:      e.CreatedAt,
+		})
+	}
+	return occurrences, nil
+}
+
+func (r *DriftAnalyticsRepository) GetDriftAlerts(filter *domain.DriftAnalyticsFilter) ([]*domain.DriftAlertRecord, error) {
+	alerts := r.alerts.alerts.find(func(a *domain.Alert) bool {
+		return a.OrganizationID == filter.OrganizationID && (a.AlertType == domain.AlertTypeConfigurationDrift || a.AlertType == domain.AlertTypeCapabilityDrift) &&
+			a.ResourceType == "agent" && !a.CreatedAt.Before(filter.Start) && a.CreatedAt.Before(filter.End) &&
+			r.matchesAgent(filter, a.ResourceID)
+	})
+
+	records := make([]*domain.DriftAlertRecord, 0, len(alerts))
+	for _, a := range oldestFirst(alerts) {
+		records = append(records, &domain.DriftAlertRecord{
+			AlertID:        a.ID,
+			AgentID:        a.ResourceID,
+			CreatedAt:      a.CreatedAt,
+			AcknowledgedAt: a.AcknowledgedAt,
+		})
+	}
+	return records, nil
+}
+
+func (r *DriftAnalyticsRepository) GetDeprecatedCapabilities(filter *domain.DriftAnalyticsFilter) ([]*domain.CapabilityDeprecation, error) {
+	return r.deprecations.active(func(d *domain.CapabilityDeprecation) bool {
+		return d.OrganizationID == filter.OrganizationID && r.matchesAgent(filter, d.AgentID)
+	}), nil
+}
+
+// matchesAgent applies the filter's agent and fleet (tag) conditions
+func (r *DriftAnalyticsRepository) matchesAgent(filter *domain.DriftAnalyticsFilter, agentID uuid.UUID) bool {
+	if filter.AgentID != nil && *filter.AgentID != agentID {
+		return false
+	}
+	if filter.TagID != nil && !r.tags.hasLink(r.tags.agentTags, agentID, *filter.TagID) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package testsupport
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.DriftRemediationRepository = (*DriftRemediationRepository)(nil)
+
+// DriftRemediationRepository is an in-memory domain.DriftRemediationRepository
+type DriftRemediationRepository struct {
+	remediations *table[domain.DriftRemediation]
+}
+
+// NewDriftRemediationRepository creates an empty in-memory drift remediation repository
+func NewDriftRemediationRepository() *DriftRemediationRepository {
+	return &DriftRemediationRepository{remediations: newTable[domain.DriftRemediation]()}
+}
+
+func (r *DriftRemediationRepository) Create(remediation *domain.DriftRemediation) error {
+	remediation.ID = newID(remediation.ID)
+	if remediation.CreatedAt.IsZero() {
+		remediation.CreatedAt = time.Now()
+	}
+	stored := *remediation
+	stored.MCPServers = append([]string{}, remediation.MCPServers...)
+	r.remediations.put(stored.ID, stored)
+	return nil
+}
+
+func (r *DriftRemediationRepository) GetByID(id uuid.UUID) (*domain.DriftRemediation, error) {
+	remediation, ok := r.remediations.get(id)
+	if !ok {
+		return nil, domain.ErrDriftRemediationNotFound
+	}
+	return remediation, nil
+}
+
+func (r *DriftRemediationRepository) GetByAlertID(alertID uuid.UUID) (*domain.DriftRemediation, error) {
+	remediation, ok := r.remediations.first(func(d *domain.DriftRemediation) bool { return d.AlertID == alertID })
+	if !ok {
+		return nil, domain.ErrDriftRemediationNotFound
+	}
+	return remediation, nil
+}
+
+func (r *DriftRemediationRepository) ListByOrganization(orgID uuid.UUID, status domain.DriftRemediationStatus, limit, offset int) ([]*domain.DriftRemediation, error) {
+	return paginate(r.remediations.find(func(d *domain.DriftRemediation) bool {
+		return d.OrganizationID == orgID && (status == "" || d.Status == status)
+	}), limit, offset), nil
+}
+
+func (r *DriftRemediationRepository) Decide(remediation *domain.DriftRemediation) (bool, error) {
+	decided := false
+	r.remediations.update(remediation.ID, func(stored *domain.DriftRemediation) {
+		if stored.Status != domain.DriftRemediationStatusPending {
+			return
+		}
+		stored.Status, stored.EnforcementAction, stored.PolicyName = remediation.Status, remediation.EnforcementAction, remediation.PolicyName
+		stored.Reason, stored.DecidedBy, stored.DecidedAt = remediation.Reason, remediation.DecidedBy, remediation.DecidedAt
+		decided = true
+	})
+	return decided, nil
+}
@@ -0,0 +1,215 @@
+package testsupport
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	_ domain.EmailQueueRepository              = (*EmailQueueRepository)(nil)
+	_ domain.EmailSuppressionRepository        = (*EmailSuppressionRepository)(nil)
+	_ domain.OrganizationEmailSenderRepository = (*OrganizationEmailSenderRepository)(nil)
+)
+
+// EmailQueueRepository is an in-memory domain.EmailQueueRepository. ClaimDue claims under one
+// lock, so concurrent workers never claim the same message like they do against the database.
+type EmailQueueRepository struct {
+	mu       sync.Mutex
+	messages *table[domain.EmailMessage]
+}
+
+// NewEmailQueueRepository creates an empty in-memory email queue
+func NewEmailQueueRepository() *EmailQueueRepository {
+	return &EmailQueueRepository{messages: newTable[domain.EmailMessage]()}
+}
+
+func (r *EmailQueueRepository) Enqueue(message *domain.EmailMessage) error {
+	message.ID = newID(message.ID)
+	if message.Status == "" {
+		message.Status = domain.EmailMessageQueued
+	}
+	if message.NextAttemptAt.IsZero() {
+		message.NextAttemptAt = message.CreatedAt
+	}
+	r.messages.put(message.ID, *message)
+	return nil
+}
+
+func (r *EmailQueueRepository) ClaimDue(limit int, lease time.Duration) ([]*domain.EmailMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	due := oldestFirst(r.messages.find(func(message *domain.EmailMessage) bool {
+		return (message.Status == domain.EmailMessageQueued || message.Status == domain.EmailMessageSending) &&
+			!message.NextAttemptAt.After(now)
+	}))
+	due = paginate(due, limit, 0)
+	for _, message := range due {
+		message.Status = domain.EmailMessageSending
+		message.NextAttemptAt = now.Add(lease)
+		r.messages.update(message.ID, func(stored *domain.EmailMessage) {
+			stored.Status, stored.NextAttemptAt = message.Status, message.NextAttemptAt
+		})
+	}
+	return due, nil
+}
+
+// Reschedule moves the next attempt of a queued message, so tests need not wait out the backoff
+func (r *EmailQueueRepository) Reschedule(id uuid.UUID, nextAttemptAt time.Time) {
+	r.messages.update(id, func(message *domain.EmailMessage) {
+		message.NextAttemptAt = nextAttemptAt
+	})
+}
+
+func (r *EmailQueueRepository) MarkSent(id uuid.UUID, sentAt time.Time) error {
+	r.messages.update(id, func(message *domain.EmailMessage) {
+		message.Status = domain.EmailMessageSent
+		message.SentAt = &sentAt
+		message.Attempts++
+		message.LastError = ""
+	})
+	return nil
+}
+
+func (r *EmailQueueRepository) MarkRetry(id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	r.messages.update(id, func(message *domain.EmailMessage) {
+		message.Status = domain.EmailMessageQueued
+		message.NextAttemptAt = nextAttemptAt
+		message.Attempts++
+		message.LastError = lastError
+	})
+	return nil
+}
+
+func (r *EmailQueueRepository) MarkFinal(id uuid.UUID, status domain.EmailMessageStatus, lastError string) error {
+	r.messages.update(id, func(message *domain.EmailMessage) {
+		message.Status = status
+		message.LastError = lastError
+		if status != domain.EmailMessageSuppressed {
+			message.Attempts++
+		}
+	})
+	return nil
+}
+
+func (r *EmailQueueRepository) MarkBounced(address, reason string, bouncedAt time.Time) (bool, error) {
+	sent := r.messages.find(func(message *domain.EmailMessage) bool {
+		return message.Status == domain.EmailMessageSent && strings.EqualFold(message.To, address)
+	})
+	if len(sent) == 0 {
+		return false, nil
+	}
+	sort.SliceStable(sent, func(i, j int) bool { return sent[i].SentAt.After(*sent[j].SentAt) })
+	r.messages.update(sent[0].ID, func(message *domain.EmailMessage) {
+		message.Status = domain.EmailMessageBounced
+		message.BouncedAt = &bouncedAt
+		message.BounceReason = reason
+	})
+	return true, nil
+}
+
+func (r *EmailQueueRepository) List(filter domain.EmailMessageFilter) ([]*domain.EmailMessage, int, error) {
+	messages := r.messages.find(func(message *domain.EmailMessage) bool {
+		return message.OrganizationID != nil && *message.OrganizationID == filter.OrganizationID &&
+			(filter.Status == "" || message.Status == filter.Status)
+	})
+	return paginate(messages, filter.Limit, filter.Offset), len(messages), nil
+}
+
+func (r *EmailQueueRepository) DeleteFinishedBefore(before time.Time) (int, error) {
+	return r.messages.removeWhere(func(message *domain.EmailMessage) bool {
+		return message.Status != domain.EmailMessageQueued && message.Status != domain.EmailMessageSending &&
+			message.CreatedAt.Before(before)
+	}), nil
+}
+
+// EmailSuppressionRepository is an in-memory domain.EmailSuppressionRepository
+type EmailSuppressionRepository struct {
+	mu           sync.RWMutex
+	suppressions map[string]domain.EmailSuppression
+}
+
+// NewEmailSuppressionRepository creates an empty in-memory suppression list
+func NewEmailSuppressionRepository() *EmailSuppressionRepository {
+	return &EmailSuppressionRepository{suppressions: make(map[string]domain.EmailSuppression)}
+}
+
+func (r *EmailSuppressionRepository) Add(suppression *domain.EmailSuppression) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *suppression
+	if existing, ok := r.suppressions[suppression.Address]; ok {
+		stored.CreatedAt = existing.CreatedAt
+	}
+	r.suppressions[suppression.Address] = stored
+	return nil
+}
+
+func (r *EmailSuppressionRepository) IsSuppressed(address string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.suppressions[address]
+	return ok, nil
+}
+
+func (r *EmailSuppressionRepository) List(limit, offset int) ([]*domain.EmailSuppression, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	suppressions := make([]*domain.EmailSuppression, 0, len(r.suppressions))
+	for _, suppression := range r.suppressions {
+		suppression := suppression
+		suppressions = append(suppressions, &suppression)
+	}
+	sort.Slice(suppressions, func(i, j int) bool { return suppressions[i].CreatedAt.After(suppressions[j].CreatedAt) })
+	return paginate(suppressions, limit, offset), len(suppressions), nil
+}
+
+func (r *EmailSuppressionRepository) Remove(address string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.suppressions[address]; !ok {
+		return domain.ErrEmailSuppressionNotFound
+	}
+	delete(r.suppressions, address)
+	return nil
+}
+
+// OrganizationEmailSenderRepository is an in-memory domain.OrganizationEmailSenderRepository
+type OrganizationEmailSenderRepository struct {
+	senders *table[domain.OrganizationEmailSender]
+}
+
+// NewOrganizationEmailSenderRepository creates an empty in-memory organization sender repository
+func NewOrganizationEmailSenderRepository() *OrganizationEmailSenderRepository {
+	return &OrganizationEmailSenderRepository{senders: newTable[domain.OrganizationEmailSender]()}
+}
+
+func (r *OrganizationEmailSenderRepository) Get(orgID uuid.UUID) (*domain.OrganizationEmailSender, error) {
+	sender, ok := r.senders.get(orgID)
+	if !ok {
+		return nil, domain.ErrEmailSenderNotFound
+	}
+	return sender, nil
+}
+
+func (r *OrganizationEmailSenderRepository) Upsert(sender *domain.OrganizationEmailSender) error {
+	r.senders.put(sender.OrganizationID, *sender)
+	return nil
+}
+
+func (r *OrganizationEmailSenderRepository) Delete(orgID uuid.UUID) error {
+	if !r.senders.remove(orgID) {
+		return domain.ErrEmailSenderNotFound
+	}
+	return nil
+}
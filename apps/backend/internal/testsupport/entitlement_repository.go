@@ -0,0 +1,263 @@
+package testsupport
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.EntitlementRepository = (*EntitlementRepository)(nil)
+
+// EntitlementRepository is an in-memory domain.EntitlementRepository. Like the SQL
+// queries it derives entitlements from the other repositories rather than storing them;
+// any source left nil contributes no grants.
+type EntitlementRepository struct {
+	agents       *AgentRepository
+	users        *UserRepository
+	attestations *MCPAttestationRepository
+	capabilities *CapabilityRepository
+	requests     *CapabilityRequestRepository
+	auditLogs    *AuditLogRepository
+}
+
+// NewEntitlementRepository creates an entitlement repository reading from the given repositories
+func NewEntitlementRepository(
+	agents *AgentRepository,
+	users *UserRepository,
+	attestations *MCPAttestationRepository,
+	capabilities *CapabilityRepository,
+	requests *CapabilityRequestRepository,
+	auditLogs *AuditLogRepository,
+) *EntitlementRepository {
+	return &EntitlementRepository{
+		agents:       agents,
+		users:        users,
+		attestations: attestations,
+		capabilities: capabilities,
+		requests:     requests,
+		auditLogs:    auditLogs,
+	}
+}
+
+// GetMCPServerEntitlements lists agents declaring the MCP server in talks_to (by ID or name)
+// and agents with an active connection to it
+func (r *EntitlementRepository) GetMCPServerEntitlements(orgID uuid.UUID, mcpServer *domain.MCPServer) ([]*domain.Entitlement, error) {
+	var entitlements []*domain.Entitlement
+
+	for _, agent := range r.orgAgents(orgID) {
+		for _, target := range agent.TalksTo {
+			if target == mcpServer.ID.String() || target == mcpServer.Name {
+				createdBy := agent.CreatedBy
+				entitlements = append(entitlements, r.entitlement(agent, domain.EntitlementGrant{
+					GrantType: domain.EntitlementGrantRegistration,
+					GrantedAt: agent.CreatedAt,
+					GrantedBy: &createdBy,
+				}, r.lastAttested(agent.ID, mcpServer.ID)))
+				break
+			}
+		}
+	}
+
+	if r.attestations != nil {
+		connections, _ := r.attestations.GetConnectionsByMCP(mcpServer.ID)
+		for _, connection := range connections {
+			if !connection.IsActive {
+				continue
+			}
+			agent, ok := r.orgAgent(orgID, connection.AgentID)
+			if !ok {
+				continue
+			}
+
+			grantType := domain.EntitlementGrantRegistration
+			switch connection.ConnectionType {
+			case domain.ConnectionTypeAttested:
+				grantType = domain.EntitlementGrantAttestation
+			case domain.ConnectionTypeAutoDetected:
+				grantType = domain.EntitlementGrantAutoDetected
+			}
+			connectionID := connection.ID
+			entitlements = append(entitlements, r.entitlement(agent, domain.EntitlementGrant{
+				GrantType:   grantType,
+				GrantedAt:   connection.FirstConnectedAt,
+				ReferenceID: &connectionID,
+			}, r.lastAttested(agent.ID, mcpServer.ID)))
+		}
+	}
+
+	return sortEntitlements(entitlements), nil
+}
+
+// GetCapabilityEntitlements lists agents holding an active grant of the capability. Last use is
+// the most recent allowed verify-action audit entry for that action type.
+func (r *EntitlementRepository) GetCapabilityEntitlements(orgID uuid.UUID, capabilityType string) ([]*domain.Entitlement, error) {
+	if r.capabilities == nil {
+		return nil, nil
+	}
+
+	var entitlements []*domain.Entitlement
+	for _, capability := range r.capabilities.capabilities.find(func(c *domain.AgentCapability) bool {
+		return c.CapabilityType == capabilityType && c.Active(time.Now())
+	}) {
+		agent, ok := r.orgAgent(orgID, capability.AgentID)
+		if !ok {
+			continue
+		}
+
+		grant := domain.EntitlementGrant{
+			GrantedAt: capability.GrantedAt,
+			GrantedBy: capability.GrantedBy,
+		}
+		switch requestID := r.approvedRequest(agent.ID, capabilityType); {
+		case requestID != nil:
+			grant.GrantType = domain.EntitlementGrantCapabilityRequest
+			grant.ReferenceID = requestID
+		case capability.GrantedBy == nil:
+			grant.GrantType = domain.EntitlementGrantAutoDetected
+		case *capability.GrantedBy == agent.CreatedBy:
+			grant.GrantType = domain.EntitlementGrantRegistration
+		default:
+			grant.GrantType = domain.EntitlementGrantManual
+		}
+
+		entitlements = append(entitlements, r.entitlement(agent, grant, r.lastAllowedAction(agent.ID, capabilityType)))
+	}
+
+	return sortEntitlements(entitlements), nil
+}
+
+// entitlement builds a single-grant row for the agent, resolving the owner and granter names
+func (r *EntitlementRepository) entitlement(agent *domain.Agent, grant domain.EntitlementGrant, lastUsed *time.Time) *domain.Entitlement {
+	entitlement := &domain.Entitlement{
+		AgentID:          agent.ID,
+		AgentName:        agent.Name,
+		AgentDisplayName: agent.DisplayName,
+		AgentStatus:      agent.Status,
+		TrustScore:       agent.TrustScore,
+		LastUsedAt:       lastUsed,
+	}
+
+	if r.users != nil {
+		if owner, ok := r.users.users.get(agent.CreatedBy); ok {
+			entitlement.OwnerID = &owner.ID
+			entitlement.OwnerName = owner.Name
+			entitlement.OwnerEmail = owner.Email
+		}
+		if grant.GrantedBy != nil {
+			if granter, ok := r.users.users.get(*grant.GrantedBy); ok {
+				grant.GrantedByName = granter.Name
+			}
+		}
+	}
+
+	entitlement.Grants = []domain.EntitlementGrant{grant}
+	return entitlement
+}
+
+// orgAgents returns the organization's agents that have not been revoked
+func (r *EntitlementRepository) orgAgents(orgID uuid.UUID) []*domain.Agent {
+	if r.agents == nil {
+		return nil
+	}
+	return r.agents.agents.find(func(a *domain.Agent) bool {
+		return a.OrganizationID == orgID && a.Status != domain.AgentStatusRevoked
+	})
+}
+
+func (r *EntitlementRepository) orgAgent(orgID, agentID uuid.UUID) (*domain.Agent, bool) {
+	if r.agents == nil {
+		return nil, false
+	}
+	agent, ok := r.agents.agents.get(agentID)
+	if !ok || agent.OrganizationID != orgID || agent.Status == domain.AgentStatusRevoked {
+		return nil, false
+	}
+	return agent, true
+}
+
+// lastAttested is the latest of the connection's last attestation and the agent's attestations of the server
+func (r *EntitlementRepository) lastAttested(agentID, mcpServerID uuid.UUID) *time.Time {
+	if r.attestations == nil {
+		return nil
+	}
+
+	var last *time.Time
+	later := func(t *time.Time) {
+		if t != nil && (last == nil || t.After(*last)) {
+			last = t
+		}
+	}
+
+	if connection, _ := r.attestations.GetConnectionByAgentAndMCP(agentID, mcpServerID); connection != nil {
+		later(connection.LastAttestedAt)
+	}
+	attestations, _ := r.attestations.GetAttestationsByAgent(agentID)
+	for _, attestation := range attestations {
+		if attestation.MCPServerID == mcpServerID {
+			later(attestation.VerifiedAt)
+		}
+	}
+	return last
+}
+
+// approvedRequest returns the most recently reviewed approved request for the capability
+func (r *EntitlementRepository) approvedRequest(agentID uuid.UUID, capabilityType string) *uuid.UUID {
+	if r.requests == nil {
+		return nil
+	}
+
+	var latest *domain.CapabilityRequest
+	for _, req := range r.requests.requests.find(func(req *domain.CapabilityRequest) bool {
+		return req.AgentID == agentID && req.CapabilityType == capabilityType &&
+			req.Status == domain.CapabilityRequestStatusApproved
+	}) {
+		if latest == nil || (req.ReviewedAt != nil && (latest.ReviewedAt == nil || req.ReviewedAt.After(*latest.ReviewedAt))) {
+			latest = req
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	return &latest.ID
+}
+
+// lastAllowedAction returns the time of the agent's latest allowed verify-action for the action type
+func (r *EntitlementRepository) lastAllowedAction(agentID uuid.UUID, actionType string) *time.Time {
+	if r.auditLogs == nil {
+		return nil
+	}
+
+	log, ok := r.auditLogs.logs.first(func(l *domain.AuditLog) bool {
+		return l.ResourceID == agentID && l.ResourceType == "agent_action" && l.Action == domain.AuditActionVerify &&
+			l.Metadata["action_type"] == actionType && isAllowed(l.Metadata["allowed"])
+	})
+	if !ok {
+		return nil
+	}
+	return &log.Timestamp
+}
+
+func isAllowed(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
+
+// sortEntitlements orders rows by agent name, then grant time
+func sortEntitlements(entitlements []*domain.Entitlement) []*domain.Entitlement {
+	sort.SliceStable(entitlements, func(i, j int) bool {
+		a, b := entitlements[i], entitlements[j]
+		if a.AgentName != b.AgentName {
+			return a.AgentName < b.AgentName
+		}
+		return a.Grants[0].GrantedAt.Before(b.Grants[0].GrantedAt)
+	})
+	return entitlements
+}
@@ -0,0 +1,598 @@
+package testsupport
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	_ domain.AuditLogRepository           = (*AuditLogRepository)(nil)
+	_ domain.VerificationRepository       = (*VerificationRepository)(nil)
+	_ domain.VerificationEventRepository  = (*VerificationEventRepository)(nil)
+	_ domain.VerificationExportRepository = (*VerificationExportRepository)(nil)
+)
+
+// AuditLogRepository is an in-memory domain.AuditLogRepository
+type AuditLogRepository struct {
+	logs *table[domain.AuditLog]
+}
+
+// NewAuditLogRepository creates an empty in-memory audit log repository
+func NewAuditLogRepository() *AuditLogRepository {
+	return &AuditLogRepository{logs: newTable[domain.AuditLog]()}
+}
+
+// Create stores the entry; a preset Timestamp is kept so tests can backdate entries
+func (r *AuditLogRepository) Create(log *domain.AuditLog) error {
+	log.ID = newID(log.ID)
+	if log.Timestamp.IsZero() {
+		log.Timestamp = time.Now()
+	}
+	r.logs.put(log.ID, *log)
+	return nil
+}
+
+func (r *AuditLogRepository) GetByOrganization(orgID uuid.UUID, limit, offset int) ([]*domain.AuditLog, error) {
+	return paginate(r.logs.find(func(l *domain.AuditLog) bool {
+		return l.OrganizationID == orgID
+	}), limit, offset), nil
+}
+
+func (r *AuditLogRepository) GetByUser(userID uuid.UUID, limit, offset int) ([]*domain.AuditLog, error) {
+	return paginate(r.logs.find(func(l *domain.AuditLog) bool {
+		return l.UserID == userID
+	}), limit, offset), nil
+}
+
+func (r *AuditLogRepository) GetByResource(resourceType string, resourceID uuid.UUID) ([]*domain.AuditLog, error) {
+	return r.logs.find(func(l *domain.AuditLog) bool {
+		return l.ResourceType == resourceType && l.ResourceID == resourceID
+	}), nil
+}
+
+func (r *AuditLogRepository) Search(query string, limit, offset int) ([]*domain.AuditLog, error) {
+	return paginate(r.logs.find(func(l *domain.AuditLog) bool {
+		return strings.Contains(string(l.Action), query) || strings.Contains(l.ResourceType, query)
+	}), limit, offset), nil
+}
+
+func (r *AuditLogRepository) CountActionsByAgentInTimeWindow(agentID uuid.UUID, action domain.AuditAction, windowMinutes int) (int, error) {
+	since := time.Now().Add(-time.Duration(windowMinutes) * time.Minute)
+	return len(r.logs.find(func(l *domain.AuditLog) bool {
+		return l.ResourceID == agentID && l.ResourceType == "agent" && l.Action == action && !l.Timestamp.Before(since)
+	})), nil
+}
+
+func (r *AuditLogRepository) GetRecentActionsByAgent(agentID uuid.UUID, limit int) ([]*domain.AuditLog, error) {
+	return paginate(r.logs.find(func(l *domain.AuditLog) bool {
+		return l.ResourceID == agentID && l.ResourceType == "agent"
+	}), limit, 0), nil
+}
+
+func (r *AuditLogRepository) GetAgentActionsByIPAddress(agentID uuid.UUID, ipAddress string, limit int) ([]*domain.AuditLog, error) {
+	return paginate(r.logs.find(func(l *domain.AuditLog) bool {
+		return l.ResourceID == agentID && l.ResourceType == "agent" && l.IPAddress == ipAddress
+	}), limit, 0), nil
+}
+
+// VerificationRepository is an in-memory domain.VerificationRepository
+type VerificationRepository struct {
+	verifications *table[domain.Verification]
+}
+
+// NewVerificationRepository creates an empty in-memory verification repository
+func NewVerificationRepository() *VerificationRepository {
+	return &VerificationRepository{verifications: newTable[domain.Verification]()}
+}
+
+func (r *VerificationRepository) Create(ctx context.Context, verification *domain.Verification) error {
+	now := time.Now()
+	verification.ID = newID(verification.ID)
+	if verification.CreatedAt.IsZero() {
+		verification.CreatedAt = now
+	}
+	verification.UpdatedAt = now
+	r.verifications.put(verification.ID, *verification)
+	return nil
+}
+
+func (r *VerificationRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Verification, error) {
+	verification, ok := r.verifications.get(id)
+	if !ok {
+		return nil, fmt.Errorf("verification not found")
+	}
+	return verification, nil
+}
+
+func (r *VerificationRepository) GetByOrganization(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*domain.Verification, int, error) {
+	verifications := r.verifications.find(func(v *domain.Verification) bool {
+		return v.OrganizationID == orgID
+	})
+	return paginate(verifications, limit, offset), len(verifications), nil
+}
+
+func (r *VerificationRepository) GetByAgent(ctx context.Context, agentID uuid.UUID, limit, offset int) ([]*domain.Verification, int, error) {
+	verifications := r.verifications.find(func(v *domain.Verification) bool {
+		return v.AgentID == agentID
+	})
+	return paginate(verifications, limit, offset), len(verifications), nil
+}
+
+func (r *VerificationRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.VerificationStatus) error {
+	if !r.verifications.update(id, func(v *domain.Verification) {
+		v.Status = status
+		v.UpdatedAt = time.Now()
+	}) {
+		return fmt.Errorf("verification not found")
+	}
+	return nil
+}
+
+func (r *VerificationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if !r.verifications.remove(id) {
+		return fmt.Errorf("verification not found")
+	}
+	return nil
+}
+
+// VerificationEventRepository is an in-memory domain.VerificationEventRepository
+type VerificationEventRepository struct {
+	events *table[domain.VerificationEvent]
+}
+
+// NewVerificationEventRepository creates an empty in-memory verification event repository
+func NewVerificationEventRepository() *VerificationEventRepository {
+	return &VerificationEventRepository{events: newTable[domain.VerificationEvent]()}
+}
+
+// Create stores the event; a preset CreatedAt is kept so tests can backdate events
+func (r *VerificationEventRepository) Create(event *domain.VerificationEvent) error {
+	event.ID = newID(event.ID)
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	if event.RiskLevel == "" {
+		event.RiskLevel = domain.VerificationRiskLow
+	}
+	r.events.put(event.ID, *event)
+	return nil
+}
+
+func (r *VerificationEventRepository) GetByID(id uuid.UUID) (*domain.VerificationEvent, error) {
+	event, ok := r.events.get(id)
+	if !ok {
+		return nil, fmt.Errorf("verification event not found")
+	}
+	return event, nil
+}
+
+func (r *VerificationEventRepository) GetByOrganization(orgID uuid.UUID, limit, offset int) ([]*domain.VerificationEvent, int, error) {
+	events := r.events.find(func(e *domain.VerificationEvent) bool {
+		return e.OrganizationID == orgID
+	})
+	return paginate(events, limit, offset), len(events), nil
+}
+
+func (r *VerificationEventRepository) GetByAgent(agentID uuid.UUID, limit, offset int) ([]*domain.VerificationEvent, int, error) {
+	events := r.events.find(func(e *domain.VerificationEvent) bool {
+		return e.AgentID != nil && *e.AgentID == agentID
+	})
+	return paginate(events, limit, offset), len(events), nil
+}
+
+func (r *VerificationEventRepository) GetByMCPServer(mcpServerID uuid.UUID, limit, offset int) ([]*domain.VerificationEvent, int, error) {
+	events := r.events.find(func(e *domain.VerificationEvent) bool {
+		return e.MCPServerID != nil && *e.MCPServerID == mcpServerID
+	})
+	return paginate(events, limit, offset), len(events), nil
+}
+
+func (r *VerificationEventRepository) GetRecentEvents(orgID uuid.UUID, minutes int) ([]*domain.VerificationEvent, error) {
+	since := time.Now().Add(-time.Duration(minutes) * time.Minute)
+	return r.events.find(func(e *domain.VerificationEvent) bool {
+		return e.OrganizationID == orgID && !e.CreatedAt.Before(since)
+	}), nil
+}
+
+func (r *VerificationEventRepository) GetPendingVerifications(orgID uuid.UUID) ([]*domain.VerificationEvent, error) {
+	return r.events.find(func(e *domain.VerificationEvent) bool {
+		return e.OrganizationID == orgID && e.Status == domain.VerificationEventStatusPending
+	}), nil
+}
+
+// ListAfter returns the events after the cursor, oldest first
+func (r *VerificationEventRepository) ListAfter(orgID uuid.UUID, query domain.VerificationEventTailQuery) ([]*domain.VerificationEvent, error) {
+	events := r.events.find(func(e *domain.VerificationEvent) bool {
+		if e.OrganizationID != orgID {
+			return false
+		}
+		if !query.Before.IsZero() && !e.CreatedAt.Before(query.Before) {
+			return false
+		}
+		if e.CreatedAt.Before(query.AfterCreatedAt) ||
+			(e.CreatedAt.Equal(query.AfterCreatedAt) && e.ID.String() <= query.AfterID.String()) {
+			return false
+		}
+		return query.Matches(e)
+	})
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].CreatedAt.Equal(events[j].CreatedAt) {
+			return events[i].CreatedAt.Before(events[j].CreatedAt)
+		}
+		return events[i].ID.String() < events[j].ID.String()
+	})
+	return paginate(events, query.Limit, 0), nil
+}
+
+// SearchAdminVerifications applies the same status buckets, risk level and search filters as the SQL repository
+func (r *VerificationEventRepository) SearchAdminVerifications(orgID uuid.UUID, params domain.VerificationQueryParams) ([]*domain.VerificationEvent, int, *domain.VerificationStatusCounts, error) {
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	var status domain.VerificationEventStatus
+	switch strings.ToLower(params.Status) {
+	case "pending":
+		status = domain.VerificationEventStatusPending
+	case "approved":
+		status = domain.VerificationEventStatusSuccess
+	case "denied":
+		status = domain.VerificationEventStatusFailed
+	}
+	risk := strings.ToLower(params.RiskLevel)
+	if risk == "all" {
+		risk = ""
+	}
+	search := strings.ToLower(params.Search)
+
+	counts := &domain.VerificationStatusCounts{}
+	events := r.events.find(func(e *domain.VerificationEvent) bool {
+		if e.OrganizationID != orgID {
+			return false
+		}
+		switch e.Status {
+		case domain.VerificationEventStatusPending:
+			counts.Pending++
+		case domain.VerificationEventStatusSuccess:
+			counts.Approved++
+		case domain.VerificationEventStatusFailed:
+			counts.Denied++
+		}
+
+		if status != "" && e.Status != status {
+			return false
+		}
+		if risk != "" && string(e.RiskLevel) != risk {
+			return false
+		}
+		if !sameID(params.AgentID, e.AgentID) ||
+			!sameID(params.InitiatorUserID, e.InitiatorUserID) ||
+			!sameID(params.InitiatorAgentID, e.InitiatorAgentID) ||
+			!sameID(params.InitiatorAPIKeyID, e.InitiatorAPIKeyID) {
+			return false
+		}
+		if params.InitiatorIP != "" && (e.InitiatorIP == nil || *e.InitiatorIP != params.InitiatorIP) {
+			return false
+		}
+		return search == "" || eventMatchesSearch(e, search, strings.ToLower(params.SearchField))
+	})
+
+	return paginate(events, params.Limit, params.Offset), len(events), counts, nil
+}
+
+// sameID reports whether an event's ID matches a filter; a nil filter matches every event
+func sameID(filter, id *uuid.UUID) bool {
+	return filter == nil || (id != nil && *id == *filter)
+}
+
+func (r *VerificationEventRepository) GetStatistics(orgID uuid.UUID, startTime, endTime time.Time) (*domain.VerificationStatistics, error) {
+	events := r.events.find(func(e *domain.VerificationEvent) bool {
+		return e.OrganizationID == orgID && !e.CreatedAt.Before(startTime) && !e.CreatedAt.After(endTime)
+	})
+
+	stats := &domain.VerificationStatistics{
+		TotalVerifications:    len(events),
+		ProtocolDistribution:  make(map[string]int),
+		TypeDistribution:      make(map[string]int),
+		InitiatorDistribution: make(map[string]int),
+	}
+	agents := make(map[uuid.UUID]bool)
+	for _, e := range events {
+		switch e.Status {
+		case domain.VerificationEventStatusSuccess:
+			stats.SuccessCount++
+		case domain.VerificationEventStatusFailed:
+			stats.FailedCount++
+		case domain.VerificationEventStatusPending:
+			stats.PendingCount++
+		case domain.VerificationEventStatusTimeout:
+			stats.TimeoutCount++
+		}
+		stats.AvgDurationMs += float64(e.DurationMs)
+		stats.AvgConfidence += e.Confidence
+		stats.AvgTrustScore += e.TrustScore
+		if e.AgentID != nil {
+			agents[*e.AgentID] = true
+		}
+		stats.ProtocolDistribution[string(e.Protocol)]++
+		stats.TypeDistribution[string(e.VerificationType)]++
+		if e.InitiatorType != "" {
+			stats.InitiatorDistribution[string(e.InitiatorType)]++
+		}
+	}
+
+	if total := float64(len(events)); total > 0 {
+		stats.AvgDurationMs /= total
+		stats.AvgConfidence /= total
+		stats.AvgTrustScore /= total
+		stats.SuccessRate = float64(stats.SuccessCount) / total * 100
+	}
+	if minutes := endTime.Sub(startTime).Minutes(); minutes > 0 {
+		stats.VerificationsPerMinute = float64(len(events)) / minutes
+	}
+	stats.UniqueAgentsVerified = len(agents)
+
+	return stats, nil
+}
+
+func (r *VerificationEventRepository) GetAgentStatistics(agentID uuid.UUID, startTime, endTime time.Time) (*domain.AgentVerificationStatistics, error) {
+	events := r.events.find(func(e *domain.VerificationEvent) bool {
+		return e.AgentID != nil && *e.AgentID == agentID &&
+			!e.CreatedAt.Before(startTime) && !e.CreatedAt.After(endTime)
+	})
+
+	stats := &domain.AgentVerificationStatistics{
+		AgentID:            agentID,
+		TotalVerifications: len(events),
+		LastVerification:   time.Now(),
+	}
+	for i, e := range events {
+		switch e.Status {
+		case domain.VerificationEventStatusSuccess:
+			stats.SuccessCount++
+		case domain.VerificationEventStatusFailed:
+			stats.FailedCount++
+		}
+		stats.AvgDurationMs += float64(e.DurationMs)
+		stats.AvgConfidence += e.Confidence
+		if i == 0 || e.CreatedAt.After(stats.LastVerification) {
+			stats.LastVerification = e.CreatedAt
+		}
+	}
+
+	if total := float64(len(events)); total > 0 {
+		stats.AvgDurationMs /= total
+		stats.AvgConfidence /= total
+		stats.SuccessRate = float64(stats.SuccessCount) / total
+	}
+
+	return stats, nil
+}
+
+func (r *VerificationEventRepository) GetHourlyAgentActivity(orgID uuid.UUID, since, until time.Time) ([]*domain.AgentHourlyActivity, error) {
+	events := r.events.find(func(e *domain.VerificationEvent) bool {
+		return e.OrganizationID == orgID && e.AgentID != nil &&
+			!e.CreatedAt.Before(since) && e.CreatedAt.Before(until)
+	})
+
+	type key struct {
+		agentID uuid.UUID
+		hour    time.Time
+	}
+	var activity []*domain.AgentHourlyActivity
+	byHour := make(map[key]*domain.AgentHourlyActivity)
+	for _, e := range events {
+		k := key{agentID: *e.AgentID, hour: e.CreatedAt.UTC().Truncate(time.Hour)}
+		hour, ok := byHour[k]
+		if !ok {
+			hour = &domain.AgentHourlyActivity{AgentID: k.agentID, Hour: k.hour, MCPServers: map[string]int{}}
+			byHour[k] = hour
+			activity = append(activity, hour)
+		}
+		hour.Verifications++
+		for _, server := range e.CurrentMCPServers {
+			hour.MCPServers[server]++
+		}
+	}
+	return activity, nil
+}
+
+func (r *VerificationEventRepository) GetDailyUsage(since, until time.Time) ([]*domain.UsageRollup, error) {
+	events := r.events.find(func(e *domain.VerificationEvent) bool {
+		return !e.CreatedAt.Before(since) && e.CreatedAt.Before(until)
+	})
+
+	type key struct {
+		subjectType domain.UsageSubjectType
+		subjectID   uuid.UUID
+		day         time.Time
+	}
+	var rollups []*domain.UsageRollup
+	byDay := make(map[key]*domain.UsageRollup)
+	count := func(e *domain.VerificationEvent, subjectType domain.UsageSubjectType, subjectID uuid.UUID) {
+		day := e.CreatedAt.UTC().Truncate(24 * time.Hour)
+		k := key{subjectType: subjectType, subjectID: subjectID, day: day}
+		rollup, ok := byDay[k]
+		if !ok {
+			rollup = &domain.UsageRollup{
+				OrganizationID: e.OrganizationID,
+				SubjectType:    subjectType,
+				SubjectID:      subjectID,
+				Day:            day,
+				MCPServers:     map[string]int{},
+			}
+			byDay[k] = rollup
+			rollups = append(rollups, rollup)
+		}
+		rollup.Requests++
+		switch e.Status {
+		case domain.VerificationEventStatusSuccess:
+			rollup.Successes++
+		case domain.VerificationEventStatusFailed:
+			rollup.Failures++
+		case domain.VerificationEventStatusPending:
+			rollup.Pending++
+		case domain.VerificationEventStatusTimeout:
+			rollup.Timeouts++
+		}
+		if e.ErrorCode != nil {
+			rollup.Errors++
+		}
+		for _, server := range e.CurrentMCPServers {
+			rollup.MCPServers[server]++
+		}
+	}
+	for _, e := range oldestFirst(events) {
+		if e.AgentID != nil {
+			count(e, domain.UsageSubjectAgent, *e.AgentID)
+		}
+		if e.InitiatorAPIKeyID != nil {
+			count(e, domain.UsageSubjectAPIKey, *e.InitiatorAPIKeyID)
+		}
+	}
+	return rollups, nil
+}
+
+// UpdateResult records the result, moving the event to success (verified) or failed
+func (r *VerificationEventRepository) UpdateResult(id uuid.UUID, result domain.VerificationResult, reason *string, metadata map[string]interface{}) error {
+	status := domain.VerificationEventStatusFailed
+	if result == domain.VerificationResultVerified {
+		status = domain.VerificationEventStatusSuccess
+	}
+
+	if !r.events.update(id, func(e *domain.VerificationEvent) {
+		e.Result = &result
+		e.Status = status
+		if reason != nil {
+			e.ErrorReason = reason
+		}
+		if metadata != nil {
+			e.Metadata = metadata
+		}
+		if e.CompletedAt == nil {
+			now := time.Now()
+			e.CompletedAt = &now
+		}
+	}) {
+		return fmt.Errorf("verification event not found")
+	}
+	return nil
+}
+
+// UpdatePendingResults applies the result to every event, or to none if any is missing or not pending
+func (r *VerificationEventRepository) UpdatePendingResults(ids []uuid.UUID, result domain.VerificationResult, reason *string, metadata map[string]interface{}) error {
+	for _, id := range ids {
+		event, ok := r.events.get(id)
+		if !ok || event.Status != domain.VerificationEventStatusPending {
+			return fmt.Errorf("verification event %s not found or no longer pending", id)
+		}
+	}
+	for _, id := range ids {
+		if err := r.UpdateResult(id, result, reason, metadata); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *VerificationEventRepository) Delete(id uuid.UUID) error {
+	r.events.remove(id)
+	return nil
+}
+
+// eventMatchesSearch matches a lower-cased search term against the agent name, action or resource type
+func eventMatchesSearch(e *domain.VerificationEvent, search, field string) bool {
+	contains := func(value *string) bool {
+		return value != nil && strings.Contains(strings.ToLower(*value), search)
+	}
+
+	switch field {
+	case "agent":
+		return contains(e.AgentName)
+	case "action":
+		return contains(e.Action)
+	case "resource":
+		return contains(e.ResourceType)
+	default:
+		return contains(e.AgentName) || contains(e.Action) || contains(e.ResourceType)
+	}
+}
+
+// VerificationExportRepository is an in-memory domain.VerificationExportRepository
+type VerificationExportRepository struct {
+	exports *table[domain.VerificationExport]
+}
+
+// NewVerificationExportRepository creates an empty in-memory verification export repository
+func NewVerificationExportRepository() *VerificationExportRepository {
+	return &VerificationExportRepository{exports: newTable[domain.VerificationExport]()}
+}
+
+func (r *VerificationExportRepository) Create(export *domain.VerificationExport) error {
+	export.ID = newID(export.ID)
+	if export.CreatedAt.IsZero() {
+		export.CreatedAt = time.Now().UTC()
+	}
+	r.exports.put(export.ID, *export)
+	return nil
+}
+
+func (r *VerificationExportRepository) GetByID(id uuid.UUID) (*domain.VerificationExport, error) {
+	export, ok := r.exports.get(id)
+	if !ok {
+		return nil, domain.ErrVerificationExportNotFound
+	}
+	return export, nil
+}
+
+func (r *VerificationExportRepository) ListByOrganization(orgID uuid.UUID, limit int) ([]*domain.VerificationExport, error) {
+	exports := r.exports.find(func(e *domain.VerificationExport) bool {
+		return e.OrganizationID == orgID
+	})
+	return paginate(exports, limit, 0), nil
+}
+
+func (r *VerificationExportRepository) ClaimPending(limit int, staleBefore time.Time) ([]*domain.VerificationExport, error) {
+	claimable := r.exports.find(func(e *domain.VerificationExport) bool {
+		return e.Status == domain.VerificationExportPending ||
+			(e.Status == domain.VerificationExportRunning && e.StartedAt != nil && e.StartedAt.Before(staleBefore))
+	})
+	slices.Reverse(claimable)
+	claimable = paginate(claimable, limit, 0)
+
+	now := time.Now().UTC()
+	claimed := make([]*domain.VerificationExport, 0, len(claimable))
+	for _, e := range claimable {
+		r.exports.update(e.ID, func(stored *domain.VerificationExport) {
+			stored.Status = domain.VerificationExportRunning
+			stored.StartedAt = &now
+			copied := *stored
+			claimed = append(claimed, &copied)
+		})
+	}
+	return claimed, nil
+}
+
+func (r *VerificationExportRepository) Finish(export *domain.VerificationExport) error {
+	if !r.exports.update(export.ID, func(stored *domain.VerificationExport) {
+		stored.Status = export.Status
+		stored.RowCount = export.RowCount
+		stored.Size = export.Size
+		stored.Filename = export.Filename
+		stored.ContentType = export.ContentType
+		stored.ArtifactName = export.ArtifactName
+		stored.Error = export.Error
+		stored.CompletedAt = export.CompletedAt
+		stored.ExpiresAt = export.ExpiresAt
+	}) {
+		return domain.ErrVerificationExportNotFound
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package testsupport
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.FeatureFlagRepository = (*FeatureFlagRepository)(nil)
+
+// FeatureFlagRepository is an in-memory domain.FeatureFlagRepository
+type FeatureFlagRepository struct {
+	mu        sync.RWMutex
+	flags     map[string]domain.FeatureFlag
+	overrides map[featureOverrideKey]domain.FeatureFlagOverride
+}
+
+// featureOverrideKey identifies an organization's override, or a user's when user is set
+type featureOverrideKey struct {
+	flag string
+	org  uuid.UUID
+	user uuid.UUID
+}
+
+// NewFeatureFlagRepository creates an in-memory feature flag repository without flags
+func NewFeatureFlagRepository() *FeatureFlagRepository {
+	return &FeatureFlagRepository{
+		flags:     make(map[string]domain.FeatureFlag),
+		overrides: make(map[featureOverrideKey]domain.FeatureFlagOverride),
+	}
+}
+
+// CreateFlag adds a flag, as the migration of a preview would
+func (r *FeatureFlagRepository) CreateFlag(flag *domain.FeatureFlag) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	flag.CreatedAt, flag.UpdatedAt = now, now
+	r.flags[flag.Key] = *flag
+}
+
+func (r *FeatureFlagRepository) ListFlags() ([]*domain.FeatureFlag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	flags := make([]*domain.FeatureFlag, 0, len(r.flags))
+	for _, flag := range r.flags {
+		flag := flag
+		flags = append(flags, &flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+	return flags, nil
+}
+
+func (r *FeatureFlagRepository) ListOverrides(orgID uuid.UUID) ([]*domain.FeatureFlagOverride, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	overrides := make([]*domain.FeatureFlagOverride, 0)
+	for key, override := range r.overrides {
+		if key.org == orgID {
+			override := override
+			overrides = append(overrides, &override)
+		}
+	}
+	return overrides, nil
+}
+
+func (r *FeatureFlagRepository) SetOverride(override *domain.FeatureFlagOverride) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.overrides[overrideKey(override.FlagKey, override.OrganizationID, override.UserID)] = *override
+	return nil
+}
+
+func (r *FeatureFlagRepository) DeleteOverride(flagKey string, orgID uuid.UUID, userID *uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.overrides, overrideKey(flagKey, orgID, userID))
+	return nil
+}
+
+func overrideKey(flagKey string, orgID uuid.UUID, userID *uuid.UUID) featureOverrideKey {
+	key := featureOverrideKey{flag: flagKey, org: orgID}
+	if userID != nil {
+		key.user = *userID
+	}
+	return key
+}
@@ -0,0 +1,224 @@
+package testsupport
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// Factories build valid domain entities with unique names and sensible defaults.
+// Options run last, so any field can be overridden:
+//
+//	agent := testsupport.NewAgent(orgID, func(a *domain.Agent) {
+//		a.TalksTo = []string{"filesystem-mcp"}
+//	})
+
+var fixtureSeq atomic.Uint64
+
+// uniqueName returns prefix-N with N unique across the test binary
+func uniqueName(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, fixtureSeq.Add(1))
+}
+
+// NewOrganization builds an active organization
+func NewOrganization(opts ...func(*domain.Organization)) *domain.Organization {
+	now := time.Now()
+	name := uniqueName("org")
+	org := &domain.Organization{
+		ID:        uuid.New(),
+		Name:      name,
+		Domain:    name + ".example.com",
+		PlanType:  "free",
+		MaxAgents: 100,
+		MaxUsers:  10,
+		IsActive:  true,
+		Settings:  map[string]interface{}{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for _, opt := range opts {
+		opt(org)
+	}
+	return org
+}
+
+// NewUser builds an active member of the organization
+func NewUser(orgID uuid.UUID, opts ...func(*domain.User)) *domain.User {
+	now := time.Now()
+	name := uniqueName("user")
+	user := &domain.User{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Email:          name + "@example.com",
+		Name:           name,
+		Role:           domain.RoleMember,
+		Provider:       "local",
+		Status:         domain.UserStatusActive,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	for _, opt := range opts {
+		opt(user)
+	}
+	return user
+}
+
+// NewAgent builds a verified AI agent with a public key and a 0.8 trust score
+func NewAgent(orgID uuid.UUID, opts ...func(*domain.Agent)) *domain.Agent {
+	now := time.Now()
+	name := uniqueName("agent")
+	publicKey := "pk-" + name
+	agent := &domain.Agent{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Name:           name,
+		DisplayName:    name,
+		Description:    "Test agent " + name,
+		AgentType:      domain.AgentTypeAI,
+		Status:         domain.AgentStatusVerified,
+		Version:        "1.0.0",
+		PublicKey:      &publicKey,
+		KeyAlgorithm:   "Ed25519",
+		TrustScore:     0.8,
+		VerifiedAt:     &now,
+		TalksTo:        []string{},
+		Capabilities:   []string{},
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		CreatedBy:      uuid.New(),
+	}
+	for _, opt := range opts {
+		opt(agent)
+	}
+	return agent
+}
+
+// NewMCPServer builds a verified MCP server
+func NewMCPServer(orgID uuid.UUID, opts ...func(*domain.MCPServer)) *domain.MCPServer {
+	now := time.Now()
+	name := uniqueName("mcp")
+	server := &domain.MCPServer{
+		ID:                 uuid.New(),
+		OrganizationID:     orgID,
+		Name:               name,
+		Description:        "Test MCP server " + name,
+		URL:                "https://" + name + ".example.com",
+		Version:            "1.0.0",
+		Status:             domain.MCPServerStatusVerified,
+		IsVerified:         true,
+		LastVerifiedAt:     &now,
+		Capabilities:       []string{"tools"},
+		TrustScore:         0.8,
+		VerificationMethod: "agent_attestation",
+		CreatedBy:          uuid.New(),
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	for _, opt := range opts {
+		opt(server)
+	}
+	return server
+}
+
+// NewVerificationEvent builds a successful, completed identity verification of the agent
+func NewVerificationEvent(agent *domain.Agent, opts ...func(*domain.VerificationEvent)) *domain.VerificationEvent {
+	now := time.Now()
+	agentID := agent.ID
+	agentName := agent.Name
+	result := domain.VerificationResultVerified
+	event := &domain.VerificationEvent{
+		ID:               uuid.New(),
+		OrganizationID:   agent.OrganizationID,
+		AgentID:          &agentID,
+		AgentName:        &agentName,
+		Protocol:         domain.VerificationProtocolMCP,
+		VerificationType: domain.VerificationTypeIdentity,
+		Status:           domain.VerificationEventStatusSuccess,
+		Result:           &result,
+		Confidence:       1.0,
+		TrustScore:       agent.TrustScore,
+		DurationMs:       10,
+		InitiatorType:    domain.InitiatorTypeAgent,
+		StartedAt:        now,
+		CompletedAt:      &now,
+		CreatedAt:        now,
+		Metadata:         map[string]interface{}{},
+	}
+	for _, opt := range opts {
+		opt(event)
+	}
+	return event
+}
+
+// NewMCPAttestation builds a valid, signature-verified attestation of the server by the agent,
+// expiring in 30 days
+func NewMCPAttestation(server *domain.MCPServer, agent *domain.Agent, opts ...func(*domain.MCPAttestation)) *domain.MCPAttestation {
+	now := time.Now()
+	agentID := agent.ID
+	attestation := &domain.MCPAttestation{
+		ID:          uuid.New(),
+		MCPServerID: server.ID,
+		AgentID:     &agentID,
+		AttestationData: domain.AttestationPayload{
+			AgentID:              agent.ID.String(),
+			CapabilitiesFound:    server.Capabilities,
+			ConnectionLatencyMs:  15,
+			ConnectionSuccessful: true,
+			HealthCheckPassed:    true,
+			MCPName:              server.Name,
+			MCPURL:               server.URL,
+			Timestamp:            now.UTC().Format(time.RFC3339),
+		},
+		Signature:         "test-signature",
+		SignatureVerified: true,
+		VerifiedAt:        &now,
+		ExpiresAt:         now.Add(30 * 24 * time.Hour),
+		IsValid:           true,
+		CreatedAt:         now,
+	}
+	for _, opt := range opts {
+		opt(attestation)
+	}
+	return attestation
+}
+
+// NewAgentCapability builds an active capability granted to the agent by its owner
+func NewAgentCapability(agent *domain.Agent, capabilityType string, opts ...func(*domain.AgentCapability)) *domain.AgentCapability {
+	now := time.Now()
+	grantedBy := agent.CreatedBy
+	capability := &domain.AgentCapability{
+		ID:             uuid.New(),
+		AgentID:        agent.ID,
+		CapabilityType: capabilityType,
+		GrantedBy:      &grantedBy,
+		GrantedAt:      now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	for _, opt := range opts {
+		opt(capability)
+	}
+	return capability
+}
+
+// NewAlert builds an unacknowledged warning alert about the resource
+func NewAlert(orgID, resourceID uuid.UUID, opts ...func(*domain.Alert)) *domain.Alert {
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		AlertType:      domain.AlertUnusualActivity,
+		Severity:       domain.AlertSeverityWarning,
+		Title:          uniqueName("alert"),
+		Description:    "Test alert",
+		ResourceType:   "agent",
+		ResourceID:     resourceID,
+		CreatedAt:      time.Now(),
+	}
+	for _, opt := range opts {
+		opt(alert)
+	}
+	return alert
+}
@@ -0,0 +1,78 @@
+package testsupport
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.IdempotencyRepository = (*IdempotencyRepository)(nil)
+
+// IdempotencyRepository is an in-memory domain.IdempotencyRepository. Reserve checks and
+// stores under one lock, so concurrent requests with the same key reserve it once like they
+// do against the database.
+type IdempotencyRepository struct {
+	mu      sync.Mutex
+	records *table[domain.IdempotencyRecord]
+}
+
+// NewIdempotencyRepository creates an empty in-memory idempotency repository
+func NewIdempotencyRepository() *IdempotencyRepository {
+	return &IdempotencyRepository{records: newTable[domain.IdempotencyRecord]()}
+}
+
+func (r *IdempotencyRepository) Reserve(record *domain.IdempotencyRecord) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.find(record.OrganizationID, record.Key, record.Endpoint); ok {
+		if existing.ExpiresAt.After(record.CreatedAt) {
+			return false, nil
+		}
+		r.records.remove(existing.ID)
+	}
+	record.ID = newID(record.ID)
+	reserved := *record
+	reserved.Completed, reserved.StatusCode, reserved.ContentType, reserved.ResponseBody = false, 0, "", nil
+	r.records.put(reserved.ID, reserved)
+	return true, nil
+}
+
+func (r *IdempotencyRepository) Get(orgID uuid.UUID, key, endpoint string) (*domain.IdempotencyRecord, error) {
+	record, ok := r.find(orgID, key, endpoint)
+	if !ok {
+		return nil, domain.ErrIdempotencyRecordNotFound
+	}
+	return record, nil
+}
+
+func (r *IdempotencyRepository) Complete(record *domain.IdempotencyRecord) error {
+	if !r.records.update(record.ID, func(stored *domain.IdempotencyRecord) {
+		stored.Completed = true
+		stored.StatusCode, stored.ContentType = record.StatusCode, record.ContentType
+		stored.ResponseBody = append([]byte(nil), record.ResponseBody...)
+		stored.ExpiresAt = record.ExpiresAt
+	}) {
+		return domain.ErrIdempotencyRecordNotFound
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) Delete(id uuid.UUID) error {
+	r.records.remove(id)
+	return nil
+}
+
+func (r *IdempotencyRepository) DeleteExpired(before time.Time) (int, error) {
+	return r.records.removeWhere(func(record *domain.IdempotencyRecord) bool {
+		return record.ExpiresAt.Before(before)
+	}), nil
+}
+
+func (r *IdempotencyRepository) find(orgID uuid.UUID, key, endpoint string) (*domain.IdempotencyRecord, bool) {
+	return r.records.first(func(record *domain.IdempotencyRecord) bool {
+		return record.OrganizationID == orgID && record.Key == key && record.Endpoint == endpoint
+	})
+}
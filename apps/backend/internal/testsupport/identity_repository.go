@@ -0,0 +1,608 @@
+package testsupport
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	_ domain.UserRepository         = (*UserRepository)(nil)
+	_ domain.OrganizationRepository = (*OrganizationRepository)(nil)
+	_ domain.SDKTokenRepository     = (*SDKTokenRepository)(nil)
+	_ domain.SAMLConfigRepository   = (*SAMLConfigRepository)(nil)
+
+	_ domain.PersonalAccessTokenRepository = (*PersonalAccessTokenRepository)(nil)
+
+	_ domain.EmergencyCredentialRepository = (*EmergencyCredentialRepository)(nil)
+	_ domain.AgentCertificateRepository    = (*AgentCertificateRepository)(nil)
+	_ domain.AgentListingRepository        = (*AgentListingRepository)(nil)
+)
+
+// UserRepository is an in-memory domain.UserRepository
+type UserRepository struct {
+	users *table[domain.User]
+}
+
+// NewUserRepository creates an empty in-memory user repository
+func NewUserRepository() *UserRepository {
+	return &UserRepository{users: newTable[domain.User]()}
+}
+
+func (r *UserRepository) Create(user *domain.User) error {
+	if _, ok := r.users.first(func(u *domain.User) bool { return u.Email == user.Email }); ok {
+		return fmt.Errorf("user with email %s already exists", user.Email)
+	}
+
+	now := time.Now()
+	user.ID = newID(user.ID)
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	r.users.put(user.ID, *user)
+	return nil
+}
+
+func (r *UserRepository) GetByID(id uuid.UUID) (*domain.User, error) {
+	user, ok := r.users.get(id)
+	if !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+	return user, nil
+}
+
+func (r *UserRepository) GetByIDs(ids []uuid.UUID) ([]*domain.User, error) {
+	return r.users.getMany(ids), nil
+}
+
+func (r *UserRepository) GetByEmail(email string) (*domain.User, error) {
+	user, ok := r.users.first(func(u *domain.User) bool {
+		return u.Email == email
+	})
+	if !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+	return user, nil
+}
+
+func (r *UserRepository) GetByPasswordResetToken(resetToken string) (*domain.User, error) {
+	now := time.Now()
+	user, ok := r.users.first(func(u *domain.User) bool {
+		return u.PasswordResetToken != nil && *u.PasswordResetToken == resetToken &&
+			u.PasswordResetExpiresAt != nil && u.PasswordResetExpiresAt.After(now) &&
+			u.DeletedAt == nil
+	})
+	if !ok {
+		return nil, fmt.Errorf("invalid or expired reset token")
+	}
+	return user, nil
+}
+
+func (r *UserRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.User, error) {
+	return r.users.find(func(u *domain.User) bool {
+		return u.OrganizationID == orgID
+	}), nil
+}
+
+func (r *UserRepository) GetByOrganizationAndStatus(orgID uuid.UUID, status domain.UserStatus) ([]*domain.User, error) {
+	return r.users.find(func(u *domain.User) bool {
+		return u.OrganizationID == orgID && u.Status == status
+	}), nil
+}
+
+// Update leaves the MFA fields as stored, like the SQL repository
+func (r *UserRepository) Update(user *domain.User) error {
+	user.UpdatedAt = time.Now()
+	if !r.users.update(user.ID, func(stored *domain.User) {
+		updated := *user
+		updated.MFAEnabled = stored.MFAEnabled
+		updated.MFASecret = stored.MFASecret
+		updated.MFARecoveryCodes = stored.MFARecoveryCodes
+		updated.MFALastUsedStep = stored.MFALastUsedStep
+		updated.MFAEnrolledAt = stored.MFAEnrolledAt
+		*stored = updated
+	}) {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+func (r *UserRepository) UpdateMFA(user *domain.User) error {
+	user.UpdatedAt = time.Now()
+	if !r.users.update(user.ID, func(stored *domain.User) {
+		stored.MFAEnabled = user.MFAEnabled
+		stored.MFASecret = user.MFASecret
+		stored.MFARecoveryCodes = slices.Clone(user.MFARecoveryCodes)
+		stored.MFALastUsedStep = user.MFALastUsedStep
+		stored.MFAEnrolledAt = user.MFAEnrolledAt
+		stored.UpdatedAt = user.UpdatedAt
+	}) {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+func (r *UserRepository) UpdateRole(id uuid.UUID, role domain.UserRole) error {
+	r.users.update(id, func(u *domain.User) {
+		u.Role = role
+		u.UpdatedAt = time.Now()
+	})
+	return nil
+}
+
+func (r *UserRepository) Delete(id uuid.UUID) error {
+	r.users.remove(id)
+	return nil
+}
+
+func (r *UserRepository) CountActiveUsers(orgID uuid.UUID, withinMinutes int) (int, error) {
+	since := time.Now().Add(-time.Duration(withinMinutes) * time.Minute)
+	return len(r.users.find(func(u *domain.User) bool {
+		return u.OrganizationID == orgID && u.Status == domain.UserStatusActive &&
+			u.LastLoginAt != nil && !u.LastLoginAt.Before(since)
+	})), nil
+}
+
+// OrganizationRepository is an in-memory domain.OrganizationRepository
+type OrganizationRepository struct {
+	orgs *table[domain.Organization]
+}
+
+// NewOrganizationRepository creates an empty in-memory organization repository
+func NewOrganizationRepository() *OrganizationRepository {
+	return &OrganizationRepository{orgs: newTable[domain.Organization]()}
+}
+
+func (r *OrganizationRepository) Create(org *domain.Organization) error {
+	now := time.Now()
+	org.ID = newID(org.ID)
+	org.CreatedAt = now
+	org.UpdatedAt = now
+	r.orgs.put(org.ID, *org)
+	return nil
+}
+
+func (r *OrganizationRepository) GetByID(id uuid.UUID) (*domain.Organization, error) {
+	org, ok := r.orgs.get(id)
+	if !ok {
+		return nil, fmt.Errorf("organization not found")
+	}
+	return org, nil
+}
+
+// GetByDomain returns nil (and no error) when no organization uses the domain yet
+func (r *OrganizationRepository) GetByDomain(domainName string) (*domain.Organization, error) {
+	org, ok := r.orgs.first(func(o *domain.Organization) bool {
+		return o.Domain == domainName
+	})
+	if !ok {
+		return nil, nil
+	}
+	return org, nil
+}
+
+func (r *OrganizationRepository) Update(org *domain.Organization) error {
+	org.UpdatedAt = time.Now()
+	if !r.orgs.replace(org.ID, *org) {
+		return fmt.Errorf("organization not found")
+	}
+	return nil
+}
+
+func (r *OrganizationRepository) Delete(id uuid.UUID) error {
+	r.orgs.remove(id)
+	return nil
+}
+
+func (r *OrganizationRepository) List() ([]*domain.Organization, error) {
+	return r.orgs.find(nil), nil
+}
+
+// GetChildren returns the organizations directly under the parent, oldest first
+func (r *OrganizationRepository) GetChildren(parentID uuid.UUID) ([]*domain.Organization, error) {
+	children := r.orgs.find(func(o *domain.Organization) bool {
+		return o.ParentID != nil && *o.ParentID == parentID
+	})
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].CreatedAt.Before(children[j].CreatedAt)
+	})
+	return children, nil
+}
+
+// SDKTokenRepository is an in-memory domain.SDKTokenRepository
+type SDKTokenRepository struct {
+	tokens *table[domain.SDKToken]
+}
+
+// NewSDKTokenRepository creates an empty in-memory SDK token repository
+func NewSDKTokenRepository() *SDKTokenRepository {
+	return &SDKTokenRepository{tokens: newTable[domain.SDKToken]()}
+}
+
+func (r *SDKTokenRepository) Create(token *domain.SDKToken) error {
+	token.ID = newID(token.ID)
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	r.tokens.put(token.ID, *token)
+	return nil
+}
+
+func (r *SDKTokenRepository) GetByID(id uuid.UUID) (*domain.SDKToken, error) {
+	token, ok := r.tokens.get(id)
+	if !ok {
+		return nil, fmt.Errorf("SDK token not found")
+	}
+	return token, nil
+}
+
+func (r *SDKTokenRepository) GetByTokenID(tokenID string) (*domain.SDKToken, error) {
+	token, ok := r.tokens.first(func(t *domain.SDKToken) bool {
+		return t.TokenID == tokenID
+	})
+	if !ok {
+		return nil, fmt.Errorf("SDK token not found")
+	}
+	return token, nil
+}
+
+func (r *SDKTokenRepository) GetByTokenHash(tokenHash string) (*domain.SDKToken, error) {
+	token, ok := r.tokens.first(func(t *domain.SDKToken) bool {
+		return t.TokenHash == tokenHash
+	})
+	if !ok {
+		return nil, fmt.Errorf("SDK token not found")
+	}
+	return token, nil
+}
+
+func (r *SDKTokenRepository) GetByUserID(userID uuid.UUID, includeRevoked bool) ([]*domain.SDKToken, error) {
+	return r.tokens.find(func(t *domain.SDKToken) bool {
+		return t.UserID == userID && (includeRevoked || t.RevokedAt == nil)
+	}), nil
+}
+
+func (r *SDKTokenRepository) GetByOrganizationID(organizationID uuid.UUID, includeRevoked bool) ([]*domain.SDKToken, error) {
+	return r.tokens.find(func(t *domain.SDKToken) bool {
+		return t.OrganizationID == organizationID && (includeRevoked || t.RevokedAt == nil)
+	}), nil
+}
+
+func (r *SDKTokenRepository) Update(token *domain.SDKToken) error {
+	if !r.tokens.replace(token.ID, *token) {
+		return fmt.Errorf("SDK token not found")
+	}
+	return nil
+}
+
+func (r *SDKTokenRepository) Revoke(id uuid.UUID, reason string) error {
+	return r.revokeWhere(func(t *domain.SDKToken) bool { return t.ID == id }, reason)
+}
+
+func (r *SDKTokenRepository) RevokeByTokenHash(tokenHash string, reason string) error {
+	return r.revokeWhere(func(t *domain.SDKToken) bool { return t.TokenHash == tokenHash }, reason)
+}
+
+func (r *SDKTokenRepository) RevokeAllForUser(userID uuid.UUID, reason string) error {
+	now := time.Now()
+	r.tokens.updateWhere(func(t *domain.SDKToken) bool {
+		return t.UserID == userID && t.RevokedAt == nil
+	}, func(t *domain.SDKToken) {
+		t.RevokedAt = &now
+		t.RevokeReason = &reason
+	})
+	return nil
+}
+
+func (r *SDKTokenRepository) RecordUsage(tokenID string, ipAddress string) error {
+	now := time.Now()
+	r.tokens.updateWhere(func(t *domain.SDKToken) bool {
+		return t.TokenID == tokenID
+	}, func(t *domain.SDKToken) {
+		t.LastUsedAt = &now
+		t.LastIPAddress = &ipAddress
+		t.UsageCount++
+	})
+	return nil
+}
+
+func (r *SDKTokenRepository) DeleteExpired() error {
+	now := time.Now()
+	r.tokens.removeWhere(func(t *domain.SDKToken) bool {
+		return t.ExpiresAt.Before(now)
+	})
+	return nil
+}
+
+func (r *SDKTokenRepository) GetActiveCount(userID uuid.UUID) (int, error) {
+	now := time.Now()
+	return len(r.tokens.find(func(t *domain.SDKToken) bool {
+		return t.UserID == userID && t.RevokedAt == nil && t.ExpiresAt.After(now)
+	})), nil
+}
+
+func (r *SDKTokenRepository) revokeWhere(match func(*domain.SDKToken) bool, reason string) error {
+	now := time.Now()
+	revoked := r.tokens.updateWhere(func(t *domain.SDKToken) bool {
+		return match(t) && t.RevokedAt == nil
+	}, func(t *domain.SDKToken) {
+		t.RevokedAt = &now
+		t.RevokeReason = &reason
+	})
+	if revoked == 0 {
+		return fmt.Errorf("SDK token not found or already revoked")
+	}
+	return nil
+}
+
+// PersonalAccessTokenRepository is an in-memory domain.PersonalAccessTokenRepository
+type PersonalAccessTokenRepository struct {
+	tokens *table[domain.PersonalAccessToken]
+}
+
+// NewPersonalAccessTokenRepository creates an empty in-memory personal access token repository
+func NewPersonalAccessTokenRepository() *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{tokens: newTable[domain.PersonalAccessToken]()}
+}
+
+func (r *PersonalAccessTokenRepository) Create(token *domain.PersonalAccessToken) error {
+	token.ID = newID(token.ID)
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	token.Scopes = slices.Clone(token.Scopes)
+	r.tokens.put(token.ID, *token)
+	return nil
+}
+
+func (r *PersonalAccessTokenRepository) GetByID(id uuid.UUID) (*domain.PersonalAccessToken, error) {
+	token, ok := r.tokens.get(id)
+	if !ok {
+		return nil, domain.ErrPersonalAccessTokenNotFound
+	}
+	return token, nil
+}
+
+func (r *PersonalAccessTokenRepository) GetByHash(tokenHash string) (*domain.PersonalAccessToken, error) {
+	token, ok := r.tokens.first(func(t *domain.PersonalAccessToken) bool {
+		return t.TokenHash == tokenHash
+	})
+	if !ok {
+		return nil, domain.ErrPersonalAccessTokenNotFound
+	}
+	return token, nil
+}
+
+func (r *PersonalAccessTokenRepository) ListByUser(userID uuid.UUID) ([]*domain.PersonalAccessToken, error) {
+	return r.tokens.find(func(t *domain.PersonalAccessToken) bool {
+		return t.UserID == userID
+	}), nil
+}
+
+func (r *PersonalAccessTokenRepository) ListByOrganization(orgID uuid.UUID) ([]*domain.PersonalAccessToken, error) {
+	return r.tokens.find(func(t *domain.PersonalAccessToken) bool {
+		return t.OrganizationID == orgID
+	}), nil
+}
+
+func (r *PersonalAccessTokenRepository) RecordUsage(id uuid.UUID, ipAddress string, usedAt time.Time) error {
+	r.tokens.update(id, func(t *domain.PersonalAccessToken) {
+		t.LastUsedAt = &usedAt
+		t.LastUsedIP = &ipAddress
+	})
+	return nil
+}
+
+func (r *PersonalAccessTokenRepository) Revoke(id, revokedBy uuid.UUID, reason string) error {
+	now := time.Now()
+	found := r.tokens.update(id, func(t *domain.PersonalAccessToken) {
+		if t.RevokedAt != nil {
+			return
+		}
+		t.RevokedAt = &now
+		t.RevokedBy = &revokedBy
+		t.RevokeReason = &reason
+	})
+	if !found {
+		return domain.ErrPersonalAccessTokenNotFound
+	}
+	return nil
+}
+
+// EmergencyCredentialRepository is an in-memory domain.EmergencyCredentialRepository
+type EmergencyCredentialRepository struct {
+	credentials *table[domain.EmergencyCredential]
+}
+
+// NewEmergencyCredentialRepository creates an empty in-memory emergency credential repository
+func NewEmergencyCredentialRepository() *EmergencyCredentialRepository {
+	return &EmergencyCredentialRepository{credentials: newTable[domain.EmergencyCredential]()}
+}
+
+func (r *EmergencyCredentialRepository) Create(credential *domain.EmergencyCredential) error {
+	credential.ID = newID(credential.ID)
+	if credential.CreatedAt.IsZero() {
+		credential.CreatedAt = time.Now().UTC()
+	}
+	r.credentials.put(credential.ID, *credential)
+	return nil
+}
+
+func (r *EmergencyCredentialRepository) GetByID(id uuid.UUID) (*domain.EmergencyCredential, error) {
+	credential, ok := r.credentials.get(id)
+	if !ok {
+		return nil, fmt.Errorf("emergency credential not found")
+	}
+	return credential, nil
+}
+
+func (r *EmergencyCredentialRepository) GetBySecretHash(secretHash string) (*domain.EmergencyCredential, error) {
+	credential, ok := r.credentials.first(func(c *domain.EmergencyCredential) bool {
+		return c.SecretHash == secretHash
+	})
+	if !ok {
+		return nil, fmt.Errorf("emergency credential not found")
+	}
+	return credential, nil
+}
+
+func (r *EmergencyCredentialRepository) GetByAgent(agentID uuid.UUID) ([]*domain.EmergencyCredential, error) {
+	return r.credentials.find(func(c *domain.EmergencyCredential) bool {
+		return c.AgentID == agentID
+	}), nil
+}
+
+func (r *EmergencyCredentialRepository) Activate(id uuid.UUID, at, accessExpiresAt time.Time, ipAddress, reason string) (bool, error) {
+	activated := r.credentials.updateWhere(func(c *domain.EmergencyCredential) bool {
+		return c.ID == id && c.Status == domain.EmergencyCredentialStatusActive && at.Before(c.ExpiresAt)
+	}, func(c *domain.EmergencyCredential) {
+		c.Status = domain.EmergencyCredentialStatusActivated
+		c.ActivatedAt = &at
+		c.AccessExpiresAt = &accessExpiresAt
+		c.ActivatedIP = &ipAddress
+		c.ActivationReason = &reason
+	})
+	return activated == 1, nil
+}
+
+func (r *EmergencyCredentialRepository) Revoke(id uuid.UUID, reason string) error {
+	r.credentials.updateWhere(func(c *domain.EmergencyCredential) bool {
+		return c.ID == id && c.Status == domain.EmergencyCredentialStatusActive
+	}, func(c *domain.EmergencyCredential) {
+		now := time.Now().UTC()
+		c.Status = domain.EmergencyCredentialStatusRevoked
+		c.RevokedAt = &now
+		c.RevokeReason = &reason
+	})
+	return nil
+}
+
+// SAMLConfigRepository is an in-memory domain.SAMLConfigRepository
+type SAMLConfigRepository struct {
+	configs *table[domain.SAMLConfig] // keyed by organization
+}
+
+// NewSAMLConfigRepository creates an empty in-memory SAML configuration repository
+func NewSAMLConfigRepository() *SAMLConfigRepository {
+	return &SAMLConfigRepository{configs: newTable[domain.SAMLConfig]()}
+}
+
+// GetByOrganization returns nil (and no error) when the organization has no SAML configuration
+func (r *SAMLConfigRepository) GetByOrganization(orgID uuid.UUID) (*domain.SAMLConfig, error) {
+	config, ok := r.configs.get(orgID)
+	if !ok {
+		return nil, nil
+	}
+	return config, nil
+}
+
+func (r *SAMLConfigRepository) Upsert(config *domain.SAMLConfig) error {
+	now := time.Now().UTC()
+	if existing, ok := r.configs.get(config.OrganizationID); ok {
+		config.ID = existing.ID
+		config.CreatedAt = existing.CreatedAt
+	} else {
+		config.ID = newID(config.ID)
+		config.CreatedAt = now
+	}
+	config.UpdatedAt = now
+	r.configs.put(config.OrganizationID, *config)
+	return nil
+}
+
+func (r *SAMLConfigRepository) Delete(orgID uuid.UUID) error {
+	r.configs.remove(orgID)
+	return nil
+}
+
+// AgentCertificateRepository is an in-memory domain.AgentCertificateRepository
+type AgentCertificateRepository struct {
+	certificates *table[domain.AgentCertificate]
+}
+
+// NewAgentCertificateRepository creates an empty in-memory agent certificate repository
+func NewAgentCertificateRepository() *AgentCertificateRepository {
+	return &AgentCertificateRepository{certificates: newTable[domain.AgentCertificate]()}
+}
+
+func (r *AgentCertificateRepository) Create(certificate *domain.AgentCertificate) error {
+	certificate.ID = newID(certificate.ID)
+	if certificate.CreatedAt.IsZero() {
+		certificate.CreatedAt = time.Now().UTC()
+	}
+	r.certificates.put(certificate.ID, *certificate)
+	return nil
+}
+
+func (r *AgentCertificateRepository) GetBySerialNumber(serialNumber string) (*domain.AgentCertificate, error) {
+	certificate, ok := r.certificates.first(func(c *domain.AgentCertificate) bool {
+		return c.SerialNumber == serialNumber
+	})
+	if !ok {
+		return nil, domain.ErrAgentCertificateNotFound
+	}
+	return certificate, nil
+}
+
+func (r *AgentCertificateRepository) GetByAgent(agentID uuid.UUID) ([]*domain.AgentCertificate, error) {
+	certificates := r.certificates.find(func(c *domain.AgentCertificate) bool {
+		return c.AgentID == agentID
+	})
+	sort.SliceStable(certificates, func(i, j int) bool {
+		return certificates[i].CreatedAt.After(certificates[j].CreatedAt)
+	})
+	return certificates, nil
+}
+
+func (r *AgentCertificateRepository) RevokeByAgent(agentID uuid.UUID, reason domain.CertificateRevocationReason, at time.Time) (int, error) {
+	return r.certificates.updateWhere(func(c *domain.AgentCertificate) bool {
+		return c.AgentID == agentID && c.RevokedAt == nil
+	}, func(c *domain.AgentCertificate) {
+		c.RevokedAt = &at
+		c.RevocationReason = &reason
+	}), nil
+}
+
+func (r *AgentCertificateRepository) ListRevoked(at time.Time) ([]*domain.AgentCertificate, error) {
+	return r.certificates.find(func(c *domain.AgentCertificate) bool {
+		return c.RevokedAt != nil && c.NotAfter.After(at)
+	}), nil
+}
+
+// AgentListingRepository is an in-memory domain.AgentListingRepository, keyed by agent ID
+type AgentListingRepository struct {
+	listings *table[domain.AgentListing]
+}
+
+// NewAgentListingRepository creates an empty in-memory agent listing repository
+func NewAgentListingRepository() *AgentListingRepository {
+	return &AgentListingRepository{listings: newTable[domain.AgentListing]()}
+}
+
+func (r *AgentListingRepository) Upsert(listing *domain.AgentListing) error {
+	if existing, ok := r.listings.get(listing.AgentID); ok {
+		*listing = *existing
+		return nil
+	}
+	if listing.ListedAt.IsZero() {
+		listing.ListedAt = time.Now().UTC()
+	}
+	r.listings.put(listing.AgentID, *listing)
+	return nil
+}
+
+func (r *AgentListingRepository) Get(agentID uuid.UUID) (*domain.AgentListing, error) {
+	listing, ok := r.listings.get(agentID)
+	if !ok {
+		return nil, domain.ErrAgentListingNotFound
+	}
+	return listing, nil
+}
+
+func (r *AgentListingRepository) Delete(agentID uuid.UUID) error {
+	r.listings.remove(agentID)
+	return nil
+}
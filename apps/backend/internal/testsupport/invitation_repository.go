@@ -0,0 +1,93 @@
+package testsupport
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.InvitationRepository = (*InvitationRepository)(nil)
+
+// InvitationRepository is an in-memory domain.InvitationRepository
+type InvitationRepository struct {
+	invitations *table[domain.Invitation]
+}
+
+// NewInvitationRepository creates an empty in-memory invitation repository
+func NewInvitationRepository() *InvitationRepository {
+	return &InvitationRepository{invitations: newTable[domain.Invitation]()}
+}
+
+func (r *InvitationRepository) Create(invitation *domain.Invitation) error {
+	invitation.ID = newID(invitation.ID)
+	if invitation.CreatedAt.IsZero() {
+		invitation.CreatedAt = time.Now()
+	}
+	r.invitations.put(invitation.ID, *invitation)
+	return nil
+}
+
+func (r *InvitationRepository) GetByID(id uuid.UUID) (*domain.Invitation, error) {
+	invitation, ok := r.invitations.get(id)
+	if !ok {
+		return nil, domain.ErrInvitationNotFound
+	}
+	return reportExpiry(invitation, time.Now()), nil
+}
+
+func (r *InvitationRepository) ListByOrganization(orgID uuid.UUID, status domain.InvitationStatus, limit, offset int) ([]*domain.Invitation, error) {
+	now := time.Now()
+	return paginate(r.list(func(invitation *domain.Invitation) bool {
+		return invitation.OrganizationID == orgID && (status == "" || reportExpiry(invitation, now).Status == status)
+	}), limit, offset), nil
+}
+
+func (r *InvitationRepository) ListByEmail(orgID uuid.UUID, email string) ([]*domain.Invitation, error) {
+	return r.list(func(invitation *domain.Invitation) bool {
+		return invitation.OrganizationID == orgID && strings.EqualFold(invitation.Email, email)
+	}), nil
+}
+
+func (r *InvitationRepository) Accept(id, userID uuid.UUID, at time.Time) (bool, error) {
+	accepted := false
+	r.invitations.update(id, func(stored *domain.Invitation) {
+		if !stored.IsOpen(at) {
+			return
+		}
+		stored.Status, stored.AcceptedBy, stored.AcceptedAt = domain.InvitationStatusAccepted, &userID, &at
+		accepted = true
+	})
+	return accepted, nil
+}
+
+func (r *InvitationRepository) Revoke(id, revokedBy uuid.UUID, at time.Time) (bool, error) {
+	revoked := false
+	r.invitations.update(id, func(stored *domain.Invitation) {
+		if stored.Status != domain.InvitationStatusPending {
+			return
+		}
+		stored.Status, stored.RevokedBy, stored.RevokedAt = domain.InvitationStatusRevoked, &revokedBy, &at
+		revoked = true
+	})
+	return revoked, nil
+}
+
+func (r *InvitationRepository) list(match func(*domain.Invitation) bool) []*domain.Invitation {
+	now := time.Now()
+	invitations := r.invitations.find(match)
+	for i, invitation := range invitations {
+		invitations[i] = reportExpiry(invitation, now)
+	}
+	return invitations
+}
+
+// reportExpiry reports a pending invitation past its expiry as expired, like the SQL repository;
+// invitations are copies, so the stored one stays pending
+func reportExpiry(invitation *domain.Invitation, now time.Time) *domain.Invitation {
+	if invitation.Status == domain.InvitationStatusPending && !now.Before(invitation.ExpiresAt) {
+		invitation.Status = domain.InvitationStatusExpired
+	}
+	return invitation
+}
@@ -0,0 +1,52 @@
+package testsupport
+
+import (
+	"sync"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.JobLeaseRepository = (*JobLeaseRepository)(nil)
+
+// JobLeaseRepository is an in-memory domain.JobLeaseRepository. Leases are keyed by name,
+// so several schedulers sharing one repository elect a single leader like instances
+// sharing a database do.
+type JobLeaseRepository struct {
+	mu     sync.Mutex
+	leases map[string]domain.JobLease
+}
+
+// NewJobLeaseRepository creates an empty in-memory job lease repository
+func NewJobLeaseRepository() *JobLeaseRepository {
+	return &JobLeaseRepository{leases: make(map[string]domain.JobLease)}
+}
+
+func (r *JobLeaseRepository) Acquire(name, holderID string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	lease, ok := r.leases[name]
+	switch {
+	case ok && lease.HolderID == holderID:
+	case ok && now.Before(lease.ExpiresAt):
+		return false, nil
+	default:
+		lease = domain.JobLease{Name: name, HolderID: holderID, AcquiredAt: now}
+	}
+	lease.ExpiresAt = now.Add(ttl)
+	r.leases[name] = lease
+	return true, nil
+}
+
+func (r *JobLeaseRepository) Release(name, holderID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if lease, ok := r.leases[name]; ok && lease.HolderID == holderID {
+		lease.ExpiresAt = time.Now()
+		r.leases[name] = lease
+	}
+	return nil
+}
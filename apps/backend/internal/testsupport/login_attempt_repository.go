@@ -0,0 +1,115 @@
+package testsupport
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.LoginAttemptRepository = (*LoginAttemptRepository)(nil)
+
+// LoginAttemptRepository is an in-memory domain.LoginAttemptRepository
+type LoginAttemptRepository struct {
+	attempts *table[domain.LoginAttempt]
+}
+
+// NewLoginAttemptRepository creates an empty in-memory login attempt repository
+func NewLoginAttemptRepository() *LoginAttemptRepository {
+	return &LoginAttemptRepository{attempts: newTable[domain.LoginAttempt]()}
+}
+
+func (r *LoginAttemptRepository) Record(attempt *domain.LoginAttempt) error {
+	attempt.ID = newID(attempt.ID)
+	r.attempts.put(attempt.ID, *attempt)
+	return nil
+}
+
+// Backdate moves every attempt and lockout back by d, so tests need not wait out backoffs and
+// lockouts
+func (r *LoginAttemptRepository) Backdate(d time.Duration) {
+	r.attempts.updateWhere(func(*domain.LoginAttempt) bool { return true }, func(attempt *domain.LoginAttempt) {
+		attempt.CreatedAt = attempt.CreatedAt.Add(-d)
+		if attempt.AccountLockedUntil != nil {
+			lockedUntil := attempt.AccountLockedUntil.Add(-d)
+			attempt.AccountLockedUntil = &lockedUntil
+		}
+		if attempt.AddressLockedUntil != nil {
+			lockedUntil := attempt.AddressLockedUntil.Add(-d)
+			attempt.AddressLockedUntil = &lockedUntil
+		}
+	})
+}
+
+func (r *LoginAttemptRepository) EmailFailures(email string, since time.Time) (*domain.LoginFailures, error) {
+	var cleared time.Time
+	for _, attempt := range r.attempts.find(func(attempt *domain.LoginAttempt) bool {
+		return attempt.Email == email &&
+			(attempt.Outcome == domain.LoginAttemptSucceeded || attempt.Outcome == domain.LoginAttemptUnlocked)
+	}) {
+		if attempt.CreatedAt.After(cleared) {
+			cleared = attempt.CreatedAt
+		}
+	}
+	return summarizeLoginFailures(r.attempts.find(func(attempt *domain.LoginAttempt) bool {
+		return attempt.Email == email && attempt.Outcome == domain.LoginAttemptFailed && attempt.CreatedAt.After(cleared)
+	}), since, func(attempt *domain.LoginAttempt) *time.Time { return attempt.AccountLockedUntil }), nil
+}
+
+func (r *LoginAttemptRepository) IPFailures(ipAddress string, since time.Time) (*domain.LoginFailures, error) {
+	return summarizeLoginFailures(r.attempts.find(func(attempt *domain.LoginAttempt) bool {
+		return attempt.IPAddress == ipAddress && attempt.Outcome == domain.LoginAttemptFailed
+	}), since, func(attempt *domain.LoginAttempt) *time.Time { return attempt.AddressLockedUntil }), nil
+}
+
+// summarizeLoginFailures counts the failures since the given time and finds the latest lockout of all
+func summarizeLoginFailures(failed []*domain.LoginAttempt, since time.Time, lockedUntil func(*domain.LoginAttempt) *time.Time) *domain.LoginFailures {
+	failures := &domain.LoginFailures{}
+	emails := make(map[string]bool)
+	for _, attempt := range failed {
+		if until := lockedUntil(attempt); until != nil && (failures.LockedUntil == nil || until.After(*failures.LockedUntil)) {
+			failures.LockedUntil = until
+		}
+		if attempt.CreatedAt.Before(since) {
+			continue
+		}
+		failures.Count++
+		emails[attempt.Email] = true
+		if failures.LastFailedAt == nil || attempt.CreatedAt.After(*failures.LastFailedAt) {
+			createdAt := attempt.CreatedAt
+			failures.LastFailedAt = &createdAt
+		}
+	}
+	failures.Emails = len(emails)
+	return failures
+}
+
+func (r *LoginAttemptRepository) TargetedOrganizations(ipAddress string, since time.Time) ([]uuid.UUID, error) {
+	var orgIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, attempt := range r.attempts.find(func(attempt *domain.LoginAttempt) bool {
+		return attempt.IPAddress == ipAddress && attempt.Outcome == domain.LoginAttemptFailed &&
+			!attempt.CreatedAt.Before(since) && attempt.OrganizationID != nil
+	}) {
+		if !seen[*attempt.OrganizationID] {
+			seen[*attempt.OrganizationID] = true
+			orgIDs = append(orgIDs, *attempt.OrganizationID)
+		}
+	}
+	return orgIDs, nil
+}
+
+func (r *LoginAttemptRepository) List(filter domain.LoginAttemptFilter) ([]*domain.LoginAttempt, int, error) {
+	attempts := r.attempts.find(func(attempt *domain.LoginAttempt) bool {
+		return attempt.OrganizationID != nil && *attempt.OrganizationID == filter.OrganizationID &&
+			(filter.Email == "" || attempt.Email == filter.Email) &&
+			(filter.Outcome == "" || attempt.Outcome == filter.Outcome)
+	})
+	return paginate(attempts, filter.Limit, filter.Offset), len(attempts), nil
+}
+
+func (r *LoginAttemptRepository) DeleteBefore(before time.Time) (int, error) {
+	return r.attempts.removeWhere(func(attempt *domain.LoginAttempt) bool {
+		return attempt.CreatedAt.Before(before)
+	}), nil
+}
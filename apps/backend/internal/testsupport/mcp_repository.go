@@ -0,0 +1,637 @@
+package testsupport
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	_ domain.MCPServerRepository            = (*MCPServerRepository)(nil)
+	_ domain.MCPServerCapabilityRepository  = (*MCPServerCapabilityRepository)(nil)
+	_ domain.MCPAttestationRepository       = (*MCPAttestationRepository)(nil)
+	_ domain.AttestationNonceRepository     = (*AttestationNonceRepository)(nil)
+	_ domain.ConnectionLatencySLORepository = (*ConnectionLatencySLORepository)(nil)
+	_ domain.MCPHealthRepository            = (*MCPHealthRepository)(nil)
+
+	_ domain.AttestationCadencePolicyRepository = (*AttestationCadencePolicyRepository)(nil)
+)
+
+// MCPServerRepository is an in-memory domain.MCPServerRepository
+type MCPServerRepository struct {
+	servers *table[domain.MCPServer]
+}
+
+// NewMCPServerRepository creates an empty in-memory MCP server repository
+func NewMCPServerRepository() *MCPServerRepository {
+	return &MCPServerRepository{servers: newTable[domain.MCPServer]()}
+}
+
+func (r *MCPServerRepository) Create(server *domain.MCPServer) error {
+	now := time.Now()
+	server.ID = newID(server.ID)
+	server.CreatedAt = now
+	server.UpdatedAt = now
+	if server.Status == "" {
+		server.Status = domain.MCPServerStatusPending
+	}
+	if server.Criticality == "" {
+		server.Criticality = domain.DefaultMCPServerCriticality
+	}
+	r.servers.put(server.ID, *server)
+	return nil
+}
+
+func (r *MCPServerRepository) GetByID(id uuid.UUID) (*domain.MCPServer, error) {
+	server, ok := r.servers.get(id)
+	if !ok {
+		return nil, fmt.Errorf("mcp server not found")
+	}
+	return server, nil
+}
+
+func (r *MCPServerRepository) GetByIDs(ids []uuid.UUID) ([]*domain.MCPServer, error) {
+	return r.servers.getMany(ids), nil
+}
+
+func (r *MCPServerRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.MCPServer, error) {
+	return r.servers.find(func(s *domain.MCPServer) bool {
+		return s.OrganizationID == orgID
+	}), nil
+}
+
+func (r *MCPServerRepository) GetByURL(url string) (*domain.MCPServer, error) {
+	server, ok := r.servers.first(func(s *domain.MCPServer) bool {
+		return s.URL == url
+	})
+	if !ok {
+		return nil, fmt.Errorf("mcp server not found")
+	}
+	return server, nil
+}
+
+func (r *MCPServerRepository) Update(server *domain.MCPServer) error {
+	server.UpdatedAt = time.Now()
+	if !r.servers.replace(server.ID, *server) {
+		return fmt.Errorf("mcp server not found")
+	}
+	return nil
+}
+
+func (r *MCPServerRepository) Delete(id uuid.UUID) error {
+	if !r.servers.remove(id) {
+		return fmt.Errorf("mcp server not found")
+	}
+	return nil
+}
+
+func (r *MCPServerRepository) List(limit, offset int) ([]*domain.MCPServer, error) {
+	return paginate(r.servers.find(nil), limit, offset), nil
+}
+
+// UpdateConfidenceScore records the server's attestation confidence score
+func (r *MCPServerRepository) UpdateConfidenceScore(id uuid.UUID, score float64, attestationCount int, lastAttestedAt time.Time) error {
+	if !r.servers.update(id, func(s *domain.MCPServer) {
+		s.ConfidenceScore = score
+		s.AttestationCount = attestationCount
+		s.LastAttestedAt = &lastAttestedAt
+		s.UpdatedAt = time.Now().UTC()
+	}) {
+		return fmt.Errorf("mcp server not found")
+	}
+	return nil
+}
+
+// GetVerificationStatus reports the server's verification state; server keys are not
+// modelled, so PublicKeyCount is 1 when the server has a public key
+func (r *MCPServerRepository) GetVerificationStatus(id uuid.UUID) (*domain.MCPServerVerificationStatus, error) {
+	server, ok := r.servers.get(id)
+	if !ok {
+		return nil, fmt.Errorf("mcp server not found")
+	}
+
+	status := &domain.MCPServerVerificationStatus{
+		ServerID:       server.ID,
+		IsVerified:     server.IsVerified,
+		LastVerifiedAt: server.LastVerifiedAt,
+		TrustScore:     server.TrustScore,
+		Status:         server.Status,
+	}
+	if server.PublicKey != "" {
+		status.PublicKeyCount = 1
+	}
+	return status, nil
+}
+
+// MCPServerCapabilityRepository is an in-memory domain.MCPServerCapabilityRepository
+type MCPServerCapabilityRepository struct {
+	capabilities *table[domain.MCPServerCapability]
+}
+
+// NewMCPServerCapabilityRepository creates an empty in-memory MCP capability repository
+func NewMCPServerCapabilityRepository() *MCPServerCapabilityRepository {
+	return &MCPServerCapabilityRepository{capabilities: newTable[domain.MCPServerCapability]()}
+}
+
+// Create stores the capability; like the SQL upsert, a capability the server already has (same
+// name and type) is refreshed and reactivated, keeping its ID and risk override
+func (r *MCPServerCapabilityRepository) Create(capability *domain.MCPServerCapability) error {
+	now := time.Now()
+	existing, ok := r.capabilities.first(func(c *domain.MCPServerCapability) bool {
+		return c.MCPServerID == capability.MCPServerID && c.Name == capability.Name && c.CapabilityType == capability.CapabilityType
+	})
+	if ok {
+		detectedAt := capability.DetectedAt
+		if detectedAt.IsZero() {
+			detectedAt = now
+		}
+		existing.Description = capability.Description
+		existing.CapabilitySchema = capability.CapabilitySchema
+		existing.LastVerifiedAt = &detectedAt
+		existing.IsActive = capability.IsActive
+		existing.UpdatedAt = now
+		r.capabilities.put(existing.ID, *existing)
+		capability.ID, capability.CreatedAt, capability.UpdatedAt = existing.ID, existing.CreatedAt, existing.UpdatedAt
+		return nil
+	}
+
+	capability.ID = newID(capability.ID)
+	if capability.DetectedAt.IsZero() {
+		capability.DetectedAt = now
+	}
+	capability.CreatedAt = now
+	capability.UpdatedAt = now
+	r.capabilities.put(capability.ID, *capability)
+	return nil
+}
+
+func (r *MCPServerCapabilityRepository) GetByID(id uuid.UUID) (*domain.MCPServerCapability, error) {
+	capability, ok := r.capabilities.get(id)
+	if !ok {
+		return nil, fmt.Errorf("mcp server capability not found")
+	}
+	return capability, nil
+}
+
+func (r *MCPServerCapabilityRepository) GetByServerID(serverID uuid.UUID) ([]*domain.MCPServerCapability, error) {
+	return r.capabilities.find(func(c *domain.MCPServerCapability) bool {
+		return c.MCPServerID == serverID && c.IsActive
+	}), nil
+}
+
+func (r *MCPServerCapabilityRepository) GetByServerIDAndType(serverID uuid.UUID, capType domain.MCPCapabilityType) ([]*domain.MCPServerCapability, error) {
+	return r.capabilities.find(func(c *domain.MCPServerCapability) bool {
+		return c.MCPServerID == serverID && c.CapabilityType == capType && c.IsActive
+	}), nil
+}
+
+func (r *MCPServerCapabilityRepository) Update(capability *domain.MCPServerCapability) error {
+	capability.UpdatedAt = time.Now()
+	if !r.capabilities.replace(capability.ID, *capability) {
+		return fmt.Errorf("mcp server capability not found")
+	}
+	return nil
+}
+
+func (r *MCPServerCapabilityRepository) Delete(id uuid.UUID) error {
+	if !r.capabilities.remove(id) {
+		return fmt.Errorf("mcp server capability not found")
+	}
+	return nil
+}
+
+func (r *MCPServerCapabilityRepository) DeleteByServerID(serverID uuid.UUID) error {
+	r.capabilities.removeWhere(func(c *domain.MCPServerCapability) bool {
+		return c.MCPServerID == serverID
+	})
+	return nil
+}
+
+// MCPAttestationRepository is an in-memory domain.MCPAttestationRepository
+type MCPAttestationRepository struct {
+	attestations *table[domain.MCPAttestation]
+	connections  *table[domain.AgentMCPConnection]
+	relays       *table[domain.MCPNetworkRelay]
+	agents       *AgentRepository
+}
+
+// NewMCPAttestationRepository creates an empty in-memory attestation repository.
+// agents fills in the joined agent name and trust score, and may be nil.
+func NewMCPAttestationRepository(agents *AgentRepository) *MCPAttestationRepository {
+	return &MCPAttestationRepository{
+		attestations: newTable[domain.MCPAttestation](),
+		connections:  newTable[domain.AgentMCPConnection](),
+		relays:       newTable[domain.MCPNetworkRelay](),
+		agents:       agents,
+	}
+}
+
+func (r *MCPAttestationRepository) CreateAttestation(attestation *domain.MCPAttestation) error {
+	attestation.ID = newID(attestation.ID)
+	if attestation.CreatedAt.IsZero() {
+		attestation.CreatedAt = time.Now().UTC()
+	}
+	r.attestations.put(attestation.ID, *attestation)
+	return nil
+}
+
+func (r *MCPAttestationRepository) GetAttestationByID(id uuid.UUID) (*domain.MCPAttestation, error) {
+	attestation, ok := r.attestations.get(id)
+	if !ok {
+		return nil, fmt.Errorf("attestation not found")
+	}
+	return r.withAgent(attestation), nil
+}
+
+func (r *MCPAttestationRepository) GetAttestationsByMCP(mcpServerID uuid.UUID) ([]*domain.MCPAttestation, error) {
+	return r.withAgents(r.attestations.find(func(a *domain.MCPAttestation) bool {
+		return a.MCPServerID == mcpServerID
+	})), nil
+}
+
+func (r *MCPAttestationRepository) GetValidAttestationsByMCP(mcpServerID uuid.UUID) ([]*domain.MCPAttestation, error) {
+	now := time.Now()
+	return r.withAgents(r.attestations.find(func(a *domain.MCPAttestation) bool {
+		return a.MCPServerID == mcpServerID && a.IsValid && a.ExpiresAt.After(now)
+	})), nil
+}
+
+func (r *MCPAttestationRepository) GetAttestationsByAgent(agentID uuid.UUID) ([]*domain.MCPAttestation, error) {
+	return r.withAgents(r.attestations.find(func(a *domain.MCPAttestation) bool {
+		return a.AgentID != nil && *a.AgentID == agentID
+	})), nil
+}
+
+func (r *MCPAttestationRepository) InvalidateAttestation(id uuid.UUID) error {
+	if !r.attestations.update(id, func(a *domain.MCPAttestation) {
+		a.IsValid = false
+	}) {
+		return fmt.Errorf("attestation not found")
+	}
+	return nil
+}
+
+func (r *MCPAttestationRepository) InvalidateExpiredAttestations() ([]*domain.MCPAttestation, error) {
+	now := time.Now()
+	var expired []*domain.MCPAttestation
+	r.attestations.updateWhere(func(a *domain.MCPAttestation) bool {
+		return a.IsValid && a.ExpiresAt.Before(now)
+	}, func(a *domain.MCPAttestation) {
+		a.IsValid = false
+		copied := *a
+		expired = append(expired, &copied)
+	})
+	return expired, nil
+}
+
+func (r *MCPAttestationRepository) CreateConnection(connection *domain.AgentMCPConnection) error {
+	now := time.Now().UTC()
+	connection.ID = newID(connection.ID)
+	if connection.FirstConnectedAt.IsZero() {
+		connection.FirstConnectedAt = now
+	}
+	connection.CreatedAt = now
+	connection.UpdatedAt = now
+	r.connections.put(connection.ID, *connection)
+	return nil
+}
+
+func (r *MCPAttestationRepository) GetConnectionByID(id uuid.UUID) (*domain.AgentMCPConnection, error) {
+	connection, ok := r.connections.get(id)
+	if !ok {
+		return nil, fmt.Errorf("connection not found")
+	}
+	return connection, nil
+}
+
+// GetConnectionByAgentAndMCP returns nil (and no error) when the agent is not connected
+func (r *MCPAttestationRepository) GetConnectionByAgentAndMCP(agentID, mcpServerID uuid.UUID) (*domain.AgentMCPConnection, error) {
+	connection, ok := r.connections.first(func(c *domain.AgentMCPConnection) bool {
+		return c.AgentID == agentID && c.MCPServerID == mcpServerID
+	})
+	if !ok {
+		return nil, nil
+	}
+	return connection, nil
+}
+
+func (r *MCPAttestationRepository) GetConnectionsByAgent(agentID uuid.UUID) ([]*domain.AgentMCPConnection, error) {
+	return r.connections.find(func(c *domain.AgentMCPConnection) bool {
+		return c.AgentID == agentID
+	}), nil
+}
+
+func (r *MCPAttestationRepository) GetConnectionsByMCP(mcpServerID uuid.UUID) ([]*domain.AgentMCPConnection, error) {
+	return r.connections.find(func(c *domain.AgentMCPConnection) bool {
+		return c.MCPServerID == mcpServerID
+	}), nil
+}
+
+// UpdateConnection updates the attestation tracking fields, like the SQL repository
+func (r *MCPAttestationRepository) UpdateConnection(connection *domain.AgentMCPConnection) error {
+	connection.UpdatedAt = time.Now().UTC()
+	if !r.connections.update(connection.ID, func(c *domain.AgentMCPConnection) {
+		c.LastAttestedAt = connection.LastAttestedAt
+		c.AttestationCount = connection.AttestationCount
+		c.IsActive = connection.IsActive
+		c.UpdatedAt = connection.UpdatedAt
+	}) {
+		return fmt.Errorf("failed to update connection: connection not found")
+	}
+	return nil
+}
+
+func (r *MCPAttestationRepository) DeleteConnection(id uuid.UUID) error {
+	if !r.connections.remove(id) {
+		return fmt.Errorf("connection not found")
+	}
+	return nil
+}
+
+// CreateRelay stores the relay; an existing relay for the same MCP server and agent is updated instead
+func (r *MCPAttestationRepository) CreateRelay(relay *domain.MCPNetworkRelay) error {
+	now := time.Now().UTC()
+	if existing, ok := r.relays.first(func(rl *domain.MCPNetworkRelay) bool {
+		return rl.MCPServerID == relay.MCPServerID && rl.AgentID == relay.AgentID
+	}); ok {
+		relay.ID = existing.ID
+		relay.CreatedAt = existing.CreatedAt
+		relay.LastAttestedAt = existing.LastAttestedAt
+	} else {
+		relay.ID = newID(relay.ID)
+		relay.CreatedAt = now
+	}
+	relay.UpdatedAt = now
+	r.relays.put(relay.ID, *relay)
+	return nil
+}
+
+func (r *MCPAttestationRepository) GetRelayByID(id uuid.UUID) (*domain.MCPNetworkRelay, error) {
+	relay, ok := r.relays.get(id)
+	if !ok {
+		return nil, fmt.Errorf("network relay not found")
+	}
+	return relay, nil
+}
+
+func (r *MCPAttestationRepository) GetRelaysByMCP(mcpServerID uuid.UUID) ([]*domain.MCPNetworkRelay, error) {
+	return r.relays.find(func(rl *domain.MCPNetworkRelay) bool {
+		return rl.MCPServerID == mcpServerID
+	}), nil
+}
+
+// GetActiveRelay returns nil (and no error) when the agent is not an active relay for the MCP server
+func (r *MCPAttestationRepository) GetActiveRelay(mcpServerID, agentID uuid.UUID) (*domain.MCPNetworkRelay, error) {
+	relay, ok := r.relays.first(func(rl *domain.MCPNetworkRelay) bool {
+		return rl.MCPServerID == mcpServerID && rl.AgentID == agentID && rl.IsActive
+	})
+	if !ok {
+		return nil, nil
+	}
+	return relay, nil
+}
+
+func (r *MCPAttestationRepository) HasActiveRelays(mcpServerID uuid.UUID) (bool, error) {
+	_, ok := r.relays.first(func(rl *domain.MCPNetworkRelay) bool {
+		return rl.MCPServerID == mcpServerID && rl.IsActive
+	})
+	return ok, nil
+}
+
+func (r *MCPAttestationRepository) TouchRelay(id uuid.UUID, attestedAt time.Time) error {
+	r.relays.update(id, func(rl *domain.MCPNetworkRelay) {
+		rl.LastAttestedAt = &attestedAt
+		rl.UpdatedAt = time.Now().UTC()
+	})
+	return nil
+}
+
+func (r *MCPAttestationRepository) DeleteRelay(id uuid.UUID) error {
+	if !r.relays.remove(id) {
+		return fmt.Errorf("network relay not found")
+	}
+	return nil
+}
+
+func (r *MCPAttestationRepository) withAgents(attestations []*domain.MCPAttestation) []*domain.MCPAttestation {
+	for _, attestation := range attestations {
+		r.withAgent(attestation)
+	}
+	return attestations
+}
+
+func (r *MCPAttestationRepository) withAgent(attestation *domain.MCPAttestation) *domain.MCPAttestation {
+	if r.agents == nil || attestation.AgentID == nil {
+		return attestation
+	}
+	if agent, ok := r.agents.agents.get(*attestation.AgentID); ok {
+		attestation.AgentName = agent.Name
+		attestation.AgentTrustScore = agent.TrustScore
+	}
+	return attestation
+}
+
+// AttestationNonceRepository is an in-memory domain.AttestationNonceRepository
+type AttestationNonceRepository struct {
+	nonces *table[domain.AttestationNonce]
+}
+
+// NewAttestationNonceRepository creates an empty in-memory attestation nonce repository
+func NewAttestationNonceRepository() *AttestationNonceRepository {
+	return &AttestationNonceRepository{nonces: newTable[domain.AttestationNonce]()}
+}
+
+func (r *AttestationNonceRepository) Create(nonce *domain.AttestationNonce) error {
+	nonce.ID = newID(nonce.ID)
+	if nonce.CreatedAt.IsZero() {
+		nonce.CreatedAt = time.Now().UTC()
+	}
+	r.nonces.put(nonce.ID, *nonce)
+	return nil
+}
+
+func (r *AttestationNonceRepository) GetByNonce(value string) (*domain.AttestationNonce, error) {
+	nonce, ok := r.nonces.first(func(n *domain.AttestationNonce) bool {
+		return n.Nonce == value
+	})
+	if !ok {
+		return nil, fmt.Errorf("attestation nonce not found")
+	}
+	return nonce, nil
+}
+
+func (r *AttestationNonceRepository) Consume(value string, agentID uuid.UUID, at time.Time) (bool, error) {
+	consumed := r.nonces.updateWhere(func(n *domain.AttestationNonce) bool {
+		return n.Nonce == value && n.AgentID == agentID && n.ConsumedAt == nil && at.Before(n.ExpiresAt)
+	}, func(n *domain.AttestationNonce) {
+		n.ConsumedAt = &at
+	})
+	return consumed == 1, nil
+}
+
+func (r *AttestationNonceRepository) DeleteExpired(before time.Time) (int64, error) {
+	return int64(r.nonces.removeWhere(func(n *domain.AttestationNonce) bool {
+		return n.ExpiresAt.Before(before)
+	})), nil
+}
+
+// ConnectionLatencySLORepository is an in-memory domain.ConnectionLatencySLORepository
+type ConnectionLatencySLORepository struct {
+	slos *table[domain.ConnectionLatencySLO]
+}
+
+// NewConnectionLatencySLORepository creates an empty in-memory connection latency SLO repository
+func NewConnectionLatencySLORepository() *ConnectionLatencySLORepository {
+	return &ConnectionLatencySLORepository{slos: newTable[domain.ConnectionLatencySLO]()}
+}
+
+func (r *ConnectionLatencySLORepository) Upsert(slo *domain.ConnectionLatencySLO) error {
+	if existing, ok := r.slos.first(func(s *domain.ConnectionLatencySLO) bool {
+		return s.AgentID == slo.AgentID && s.MCPServerID == slo.MCPServerID
+	}); ok {
+		slo.ID, slo.CreatedBy, slo.CreatedAt = existing.ID, existing.CreatedBy, existing.CreatedAt
+	}
+	slo.ID = newID(slo.ID)
+	r.slos.put(slo.ID, *slo)
+	return nil
+}
+
+func (r *ConnectionLatencySLORepository) GetByConnection(agentID, mcpServerID uuid.UUID) (*domain.ConnectionLatencySLO, error) {
+	slo, ok := r.slos.first(func(s *domain.ConnectionLatencySLO) bool {
+		return s.AgentID == agentID && s.MCPServerID == mcpServerID
+	})
+	if !ok {
+		return nil, domain.ErrConnectionLatencySLONotFound
+	}
+	return slo, nil
+}
+
+func (r *ConnectionLatencySLORepository) ListByMCPServer(mcpServerID uuid.UUID) ([]*domain.ConnectionLatencySLO, error) {
+	return oldestFirst(r.slos.find(func(s *domain.ConnectionLatencySLO) bool {
+		return s.MCPServerID == mcpServerID
+	})), nil
+}
+
+func (r *ConnectionLatencySLORepository) ListEnabled() ([]*domain.ConnectionLatencySLO, error) {
+	return oldestFirst(r.slos.find(func(s *domain.ConnectionLatencySLO) bool {
+		return s.IsEnabled
+	})), nil
+}
+
+func (r *ConnectionLatencySLORepository) UpdateState(slo *domain.ConnectionLatencySLO) error {
+	if !r.slos.update(slo.ID, func(s *domain.ConnectionLatencySLO) {
+		s.LastP95Ms, s.LastSamples, s.LastFailures = slo.LastP95Ms, slo.LastSamples, slo.LastFailures
+		s.LastEvaluatedAt, s.BreachedSince, s.AlertedAt = slo.LastEvaluatedAt, slo.BreachedSince, slo.AlertedAt
+		s.Attribution = slo.Attribution
+	}) {
+		return domain.ErrConnectionLatencySLONotFound
+	}
+	return nil
+}
+
+func (r *ConnectionLatencySLORepository) Delete(agentID, mcpServerID uuid.UUID) error {
+	if r.slos.removeWhere(func(s *domain.ConnectionLatencySLO) bool {
+		return s.AgentID == agentID && s.MCPServerID == mcpServerID
+	}) == 0 {
+		return domain.ErrConnectionLatencySLONotFound
+	}
+	return nil
+}
+
+// AttestationCadencePolicyRepository is an in-memory domain.AttestationCadencePolicyRepository
+type AttestationCadencePolicyRepository struct {
+	policies *table[domain.AttestationCadencePolicy]
+}
+
+// NewAttestationCadencePolicyRepository creates an empty in-memory attestation cadence policy repository
+func NewAttestationCadencePolicyRepository() *AttestationCadencePolicyRepository {
+	return &AttestationCadencePolicyRepository{policies: newTable[domain.AttestationCadencePolicy]()}
+}
+
+// cadencePolicyKey keys the policies by organization and criticality
+func cadencePolicyKey(orgID uuid.UUID, criticality domain.MCPServerCriticality) uuid.UUID {
+	return uuid.NewSHA1(orgID, []byte(criticality))
+}
+
+func (r *AttestationCadencePolicyRepository) ListByOrganization(orgID uuid.UUID) ([]*domain.AttestationCadencePolicy, error) {
+	return r.policies.find(func(p *domain.AttestationCadencePolicy) bool {
+		return p.OrganizationID == orgID
+	}), nil
+}
+
+func (r *AttestationCadencePolicyRepository) Upsert(policy *domain.AttestationCadencePolicy) error {
+	r.policies.put(cadencePolicyKey(policy.OrganizationID, policy.Criticality), *policy)
+	return nil
+}
+
+func (r *AttestationCadencePolicyRepository) Delete(orgID uuid.UUID, criticality domain.MCPServerCriticality) error {
+	r.policies.remove(cadencePolicyKey(orgID, criticality))
+	return nil
+}
+
+// MCPHealthRepository is an in-memory domain.MCPHealthRepository
+type MCPHealthRepository struct {
+	checks *table[domain.MCPHealthCheck]
+	states *table[domain.MCPServerHealth]
+}
+
+// NewMCPHealthRepository creates an empty in-memory MCP health repository
+func NewMCPHealthRepository() *MCPHealthRepository {
+	return &MCPHealthRepository{
+		checks: newTable[domain.MCPHealthCheck](),
+		states: newTable[domain.MCPServerHealth](),
+	}
+}
+
+func (r *MCPHealthRepository) RecordCheck(check *domain.MCPHealthCheck) error {
+	check.ID = newID(check.ID)
+	r.checks.put(check.ID, *check)
+	return nil
+}
+
+func (r *MCPHealthRepository) ListChecks(mcpServerID uuid.UUID, limit int) ([]*domain.MCPHealthCheck, error) {
+	return paginate(r.checks.find(func(c *domain.MCPHealthCheck) bool {
+		return c.MCPServerID == mcpServerID
+	}), limit, 0), nil
+}
+
+func (r *MCPHealthRepository) CountChecks(mcpServerIDs []uuid.UUID, since time.Time) (map[uuid.UUID]domain.MCPHealthCheckCount, error) {
+	wanted := make(map[uuid.UUID]bool, len(mcpServerIDs))
+	for _, id := range mcpServerIDs {
+		wanted[id] = true
+	}
+
+	counts := make(map[uuid.UUID]domain.MCPHealthCheckCount, len(mcpServerIDs))
+	for _, check := range r.checks.find(func(c *domain.MCPHealthCheck) bool {
+		return wanted[c.MCPServerID] && !c.CheckedAt.Before(since)
+	}) {
+		count := counts[check.MCPServerID]
+		count.Total++
+		if check.Healthy {
+			count.Passed++
+		}
+		counts[check.MCPServerID] = count
+	}
+	return counts, nil
+}
+
+func (r *MCPHealthRepository) DeleteChecksBefore(before time.Time) (int, error) {
+	return r.checks.removeWhere(func(c *domain.MCPHealthCheck) bool {
+		return c.CheckedAt.Before(before)
+	}), nil
+}
+
+func (r *MCPHealthRepository) GetState(mcpServerID uuid.UUID) (*domain.MCPServerHealth, error) {
+	health, ok := r.states.get(mcpServerID)
+	if !ok {
+		return nil, domain.ErrMCPServerHealthNotFound
+	}
+	return health, nil
+}
+
+func (r *MCPHealthRepository) UpsertState(health *domain.MCPServerHealth) error {
+	r.states.put(health.MCPServerID, *health)
+	return nil
+}
@@ -0,0 +1,74 @@
+package testsupport
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.NamingPolicyRepository = (*NamingPolicyRepository)(nil)
+
+// NamingPolicyRepository is an in-memory domain.NamingPolicyRepository
+type NamingPolicyRepository struct {
+	policies *table[domain.NamingPolicy]
+}
+
+// NewNamingPolicyRepository creates an empty in-memory naming policy repository
+func NewNamingPolicyRepository() *NamingPolicyRepository {
+	return &NamingPolicyRepository{policies: newTable[domain.NamingPolicy]()}
+}
+
+func (r *NamingPolicyRepository) Create(policy *domain.NamingPolicy) error {
+	now := time.Now()
+	policy.ID = newID(policy.ID)
+	policy.CreatedAt = now
+	policy.UpdatedAt = now
+	r.policies.put(policy.ID, cloneNamingPolicy(*policy))
+	return nil
+}
+
+func (r *NamingPolicyRepository) GetByID(id uuid.UUID) (*domain.NamingPolicy, error) {
+	policy, ok := r.policies.get(id)
+	if !ok {
+		return nil, domain.ErrNamingPolicyNotFound
+	}
+	*policy = cloneNamingPolicy(*policy)
+	return policy, nil
+}
+
+// GetByOrganization lists the organization's policies oldest first, like the SQL repository
+func (r *NamingPolicyRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.NamingPolicy, error) {
+	return r.list(func(p *domain.NamingPolicy) bool { return p.OrganizationID == orgID }), nil
+}
+
+func (r *NamingPolicyRepository) GetActiveByOrganization(orgID uuid.UUID) ([]*domain.NamingPolicy, error) {
+	return r.list(func(p *domain.NamingPolicy) bool { return p.OrganizationID == orgID && p.IsActive }), nil
+}
+
+func (r *NamingPolicyRepository) Update(policy *domain.NamingPolicy) error {
+	policy.UpdatedAt = time.Now()
+	r.policies.update(policy.ID, func(stored *domain.NamingPolicy) {
+		*stored = cloneNamingPolicy(*policy)
+	})
+	return nil
+}
+
+func (r *NamingPolicyRepository) Delete(id uuid.UUID) error {
+	r.policies.remove(id)
+	return nil
+}
+
+func (r *NamingPolicyRepository) list(match func(*domain.NamingPolicy) bool) []*domain.NamingPolicy {
+	policies := oldestFirst(r.policies.find(match))
+	for _, policy := range policies {
+		*policy = cloneNamingPolicy(*policy)
+	}
+	return policies
+}
+
+func cloneNamingPolicy(policy domain.NamingPolicy) domain.NamingPolicy {
+	policy.ReservedNames = slices.Clone(policy.ReservedNames)
+	return policy
+}
@@ -0,0 +1,551 @@
+package testsupport
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	_ domain.NotificationRepository      = (*NotificationRepository)(nil)
+	_ domain.NotificationRouteRepository = (*NotificationRouteRepository)(nil)
+	_ domain.WebhookRepository           = (*WebhookRepository)(nil)
+	_ domain.ReportRepository            = (*ReportRepository)(nil)
+)
+
+// NotificationRepository is an in-memory domain.NotificationRepository
+type NotificationRepository struct {
+	channels      *table[domain.NotificationChannel]
+	notifications *table[domain.Notification]
+	deliveries    *table[domain.NotificationDelivery]
+}
+
+// NewNotificationRepository creates an empty in-memory notification repository
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{
+		channels:      newTable[domain.NotificationChannel](),
+		notifications: newTable[domain.Notification](),
+		deliveries:    newTable[domain.NotificationDelivery](),
+	}
+}
+
+func (r *NotificationRepository) CreateChannel(channel *domain.NotificationChannel) error {
+	now := time.Now().UTC()
+	channel.ID = newID(channel.ID)
+	channel.CreatedAt = now
+	channel.UpdatedAt = now
+	r.channels.put(channel.ID, *channel)
+	return nil
+}
+
+func (r *NotificationRepository) GetChannelByID(id uuid.UUID) (*domain.NotificationChannel, error) {
+	channel, ok := r.channels.get(id)
+	if !ok {
+		return nil, fmt.Errorf("notification channel not found")
+	}
+	return channel, nil
+}
+
+func (r *NotificationRepository) GetChannelsByOrganization(orgID uuid.UUID) ([]*domain.NotificationChannel, error) {
+	return r.channels.find(func(c *domain.NotificationChannel) bool {
+		return c.OrganizationID == orgID
+	}), nil
+}
+
+// GetActiveChannelsByOrganization returns active channels oldest first, the order they are dispatched in
+func (r *NotificationRepository) GetActiveChannelsByOrganization(orgID uuid.UUID) ([]*domain.NotificationChannel, error) {
+	return oldestFirst(r.channels.find(func(c *domain.NotificationChannel) bool {
+		return c.OrganizationID == orgID && c.IsActive
+	})), nil
+}
+
+func (r *NotificationRepository) UpdateChannel(channel *domain.NotificationChannel) error {
+	channel.UpdatedAt = time.Now().UTC()
+	r.channels.replace(channel.ID, *channel)
+	return nil
+}
+
+func (r *NotificationRepository) DeleteChannel(id uuid.UUID) error {
+	if !r.channels.remove(id) {
+		return fmt.Errorf("notification channel not found")
+	}
+	return nil
+}
+
+// CreateNotification stores the notification and its deliveries
+func (r *NotificationRepository) CreateNotification(notification *domain.Notification, deliveries []*domain.NotificationDelivery) error {
+	notification.ID = newID(notification.ID)
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now().UTC()
+	}
+
+	for _, d := range deliveries {
+		d.ID = newID(d.ID)
+		d.NotificationID = notification.ID
+		d.CreatedAt = notification.CreatedAt
+		d.UpdatedAt = notification.CreatedAt
+		if d.NextAttemptAt.IsZero() {
+			d.NextAttemptAt = notification.CreatedAt
+		}
+		r.deliveries.put(d.ID, *d)
+	}
+
+	stored := *notification
+	stored.Deliveries = nil
+	r.notifications.put(notification.ID, stored)
+	return nil
+}
+
+func (r *NotificationRepository) GetNotificationByID(id uuid.UUID) (*domain.Notification, error) {
+	notification, ok := r.notifications.get(id)
+	if !ok {
+		return nil, fmt.Errorf("notification not found")
+	}
+	return notification, nil
+}
+
+func (r *NotificationRepository) GetNotificationsByOrganization(orgID uuid.UUID, limit, offset int) ([]*domain.Notification, int, error) {
+	notifications := r.notifications.find(func(n *domain.Notification) bool {
+		return n.OrganizationID == orgID
+	})
+	return paginate(notifications, limit, offset), len(notifications), nil
+}
+
+func (r *NotificationRepository) GetDeliveriesByNotification(notificationID uuid.UUID) ([]*domain.NotificationDelivery, error) {
+	return oldestFirst(r.deliveries.find(func(d *domain.NotificationDelivery) bool {
+		return d.NotificationID == notificationID
+	})), nil
+}
+
+func (r *NotificationRepository) GetDeliveryByID(id uuid.UUID) (*domain.NotificationDelivery, error) {
+	delivery, ok := r.deliveries.get(id)
+	if !ok {
+		return nil, fmt.Errorf("notification delivery not found")
+	}
+	return delivery, nil
+}
+
+// ClaimDueDeliveries claims due pending (or lease-expired sending) deliveries, earliest first,
+// moving them to sending with next_attempt_at pushed out by the lease
+func (r *NotificationRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]*domain.NotificationDelivery, error) {
+	now := time.Now().UTC()
+	due := r.deliveries.find(func(d *domain.NotificationDelivery) bool {
+		return (d.Status == domain.NotificationDeliveryPending || d.Status == domain.NotificationDeliverySending) &&
+			!d.NextAttemptAt.After(now)
+	})
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	due = paginate(due, limit, 0)
+
+	claimed := make([]*domain.NotificationDelivery, 0, len(due))
+	for _, d := range due {
+		r.deliveries.update(d.ID, func(stored *domain.NotificationDelivery) {
+			stored.Status = domain.NotificationDeliverySending
+			stored.NextAttemptAt = now.Add(lease)
+			stored.UpdatedAt = now
+			copied := *stored
+			claimed = append(claimed, &copied)
+		})
+	}
+	return claimed, nil
+}
+
+func (r *NotificationRepository) MarkDelivered(id uuid.UUID) error {
+	now := time.Now().UTC()
+	r.deliveries.update(id, func(d *domain.NotificationDelivery) {
+		d.Status = domain.NotificationDeliveryDelivered
+		d.Attempts++
+		d.LastError = nil
+		d.DeliveredAt = &now
+		d.UpdatedAt = now
+	})
+	return nil
+}
+
+func (r *NotificationRepository) MarkAttemptFailed(id uuid.UUID, errMsg string, nextAttemptAt time.Time, exhausted bool) error {
+	status := domain.NotificationDeliveryPending
+	if exhausted {
+		status = domain.NotificationDeliveryFailed
+	}
+
+	r.deliveries.update(id, func(d *domain.NotificationDelivery) {
+		d.Status = status
+		d.Attempts++
+		d.LastError = &errMsg
+		d.NextAttemptAt = nextAttemptAt
+		d.UpdatedAt = time.Now().UTC()
+	})
+	return nil
+}
+
+func (r *NotificationRepository) RequeueDelivery(id uuid.UUID) error {
+	now := time.Now().UTC()
+	r.deliveries.update(id, func(d *domain.NotificationDelivery) {
+		d.Status = domain.NotificationDeliveryPending
+		d.Attempts = 0
+		d.NextAttemptAt = now
+		d.UpdatedAt = now
+	})
+	return nil
+}
+
+// NotificationRouteRepository is an in-memory domain.NotificationRouteRepository
+type NotificationRouteRepository struct {
+	routes *table[domain.NotificationRoute]
+}
+
+// NewNotificationRouteRepository creates an empty in-memory notification route repository
+func NewNotificationRouteRepository() *NotificationRouteRepository {
+	return &NotificationRouteRepository{routes: newTable[domain.NotificationRoute]()}
+}
+
+func (r *NotificationRouteRepository) Create(route *domain.NotificationRoute) error {
+	now := time.Now().UTC()
+	route.ID = newID(route.ID)
+	route.CreatedAt = now
+	route.UpdatedAt = now
+	r.routes.put(route.ID, *route)
+	return nil
+}
+
+func (r *NotificationRouteRepository) GetByID(id uuid.UUID) (*domain.NotificationRoute, error) {
+	route, ok := r.routes.get(id)
+	if !ok {
+		return nil, fmt.Errorf("notification route not found")
+	}
+	return route, nil
+}
+
+func (r *NotificationRouteRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.NotificationRoute, error) {
+	return oldestFirst(r.routes.find(func(route *domain.NotificationRoute) bool {
+		return route.OrganizationID == orgID
+	})), nil
+}
+
+func (r *NotificationRouteRepository) GetActiveByOrganization(orgID uuid.UUID) ([]*domain.NotificationRoute, error) {
+	return oldestFirst(r.routes.find(func(route *domain.NotificationRoute) bool {
+		return route.OrganizationID == orgID && route.IsActive
+	})), nil
+}
+
+func (r *NotificationRouteRepository) Update(route *domain.NotificationRoute) error {
+	route.UpdatedAt = time.Now().UTC()
+	r.routes.replace(route.ID, *route)
+	return nil
+}
+
+func (r *NotificationRouteRepository) Delete(id uuid.UUID) error {
+	if !r.routes.remove(id) {
+		return fmt.Errorf("notification route not found")
+	}
+	return nil
+}
+
+// WebhookRepository is an in-memory domain.WebhookRepository
+type WebhookRepository struct {
+	webhooks   *table[domain.Webhook]
+	deliveries *table[domain.WebhookDelivery]
+	egress     *table[domain.WebhookEgressSettings] // Keyed by organization ID
+}
+
+// NewWebhookRepository creates an empty in-memory webhook repository
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{
+		webhooks:   newTable[domain.Webhook](),
+		deliveries: newTable[domain.WebhookDelivery](),
+		egress:     newTable[domain.WebhookEgressSettings](),
+	}
+}
+
+func (r *WebhookRepository) Create(webhook *domain.Webhook) error {
+	now := time.Now()
+	webhook.ID = newID(webhook.ID)
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+	r.webhooks.put(webhook.ID, *webhook)
+	return nil
+}
+
+func (r *WebhookRepository) GetByID(id uuid.UUID) (*domain.Webhook, error) {
+	webhook, ok := r.webhooks.get(id)
+	if !ok {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	return webhook, nil
+}
+
+func (r *WebhookRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Webhook, error) {
+	return r.webhooks.find(func(w *domain.Webhook) bool {
+		return w.OrganizationID == orgID
+	}), nil
+}
+
+func (r *WebhookRepository) Update(webhook *domain.Webhook) error {
+	webhook.UpdatedAt = time.Now()
+	r.webhooks.replace(webhook.ID, *webhook)
+	return nil
+}
+
+func (r *WebhookRepository) Delete(id uuid.UUID) error {
+	r.webhooks.remove(id)
+	return nil
+}
+
+func (r *WebhookRepository) RecordDelivery(delivery *domain.WebhookDelivery) error {
+	now := time.Now().UTC()
+	delivery.ID = newID(delivery.ID)
+	if delivery.Status == "" {
+		delivery.Status = domain.WebhookDeliveryPending
+	}
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = now
+	}
+	delivery.CreatedAt = now
+	delivery.UpdatedAt = now
+	r.deliveries.put(delivery.ID, *delivery)
+	return nil
+}
+
+func (r *WebhookRepository) GetDeliveries(webhookID uuid.UUID, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	return paginate(r.deliveries.find(func(d *domain.WebhookDelivery) bool {
+		return d.WebhookID == webhookID
+	}), limit, offset), nil
+}
+
+// ClaimDueDeliveries claims due pending (or lease-expired sending) deliveries, earliest first,
+// moving them to sending with next_attempt_at pushed out by the lease
+func (r *WebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	now := time.Now().UTC()
+	due := r.deliveries.find(func(d *domain.WebhookDelivery) bool {
+		return (d.Status == domain.WebhookDeliveryPending || d.Status == domain.WebhookDeliverySending) &&
+			!d.NextAttemptAt.After(now)
+	})
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	due = paginate(due, limit, 0)
+
+	claimed := make([]*domain.WebhookDelivery, 0, len(due))
+	for _, d := range due {
+		r.deliveries.update(d.ID, func(stored *domain.WebhookDelivery) {
+			stored.Status = domain.WebhookDeliverySending
+			stored.NextAttemptAt = now.Add(lease)
+			stored.UpdatedAt = now
+			copied := *stored
+			claimed = append(claimed, &copied)
+		})
+	}
+	return claimed, nil
+}
+
+func (r *WebhookRepository) RecordAttempt(id uuid.UUID, status domain.WebhookDeliveryStatus, statusCode int, responseBody, errMsg string, nextAttemptAt time.Time) error {
+	now := time.Now().UTC()
+	var webhookID uuid.UUID
+	found := r.deliveries.update(id, func(d *domain.WebhookDelivery) {
+		d.Status = status
+		d.StatusCode = statusCode
+		d.ResponseBody = responseBody
+		d.Success = status == domain.WebhookDeliveryDelivered
+		d.AttemptCount++
+		d.LastError = nil
+		if errMsg != "" {
+			d.LastError = &errMsg
+		}
+		d.NextAttemptAt = nextAttemptAt
+		d.UpdatedAt = now
+		webhookID = d.WebhookID
+	})
+	if !found {
+		return fmt.Errorf("webhook delivery not found")
+	}
+
+	if status == domain.WebhookDeliveryDelivered || status == domain.WebhookDeliveryFailed {
+		r.webhooks.update(webhookID, func(w *domain.Webhook) {
+			w.LastTriggered = &now
+			if status == domain.WebhookDeliveryFailed {
+				w.FailureCount++
+			}
+		})
+	}
+	return nil
+}
+
+func (r *WebhookRepository) GetEgressSettings(orgID uuid.UUID) (*domain.WebhookEgressSettings, error) {
+	settings, ok := r.egress.get(orgID)
+	if !ok {
+		return nil, nil
+	}
+	return settings, nil
+}
+
+func (r *WebhookRepository) UpsertEgressSettings(settings *domain.WebhookEgressSettings) error {
+	settings.UpdatedAt = time.Now().UTC()
+	stored := *settings
+	stored.EgressIPs = slices.Clone(settings.EgressIPs)
+	r.egress.put(settings.OrganizationID, stored)
+	return nil
+}
+
+// ReportRepository is an in-memory domain.ReportRepository. Report data is not
+// computed; tests provide it per report type with SetReportData.
+type ReportRepository struct {
+	subscriptions *table[domain.ReportSubscription]
+	runs          *table[domain.ReportRun]
+
+	mu   sync.RWMutex
+	data map[domain.ReportType]*domain.ReportTable
+}
+
+// NewReportRepository creates an empty in-memory report repository
+func NewReportRepository() *ReportRepository {
+	return &ReportRepository{
+		subscriptions: newTable[domain.ReportSubscription](),
+		runs:          newTable[domain.ReportRun](),
+		data:          make(map[domain.ReportType]*domain.ReportTable),
+	}
+}
+
+// SetReportData sets the table returned for a report type, regardless of organization and period
+func (r *ReportRepository) SetReportData(reportType domain.ReportType, data *domain.ReportTable) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.data[reportType] = data
+}
+
+func (r *ReportRepository) CreateSubscription(subscription *domain.ReportSubscription) error {
+	now := time.Now().UTC()
+	subscription.ID = newID(subscription.ID)
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+	r.subscriptions.put(subscription.ID, *subscription)
+	return nil
+}
+
+func (r *ReportRepository) GetSubscriptionByID(id uuid.UUID) (*domain.ReportSubscription, error) {
+	subscription, ok := r.subscriptions.get(id)
+	if !ok {
+		return nil, fmt.Errorf("report subscription not found")
+	}
+	return subscription, nil
+}
+
+func (r *ReportRepository) GetSubscriptionsByOrganization(orgID uuid.UUID) ([]*domain.ReportSubscription, error) {
+	return r.subscriptions.find(func(s *domain.ReportSubscription) bool {
+		return s.OrganizationID == orgID
+	}), nil
+}
+
+func (r *ReportRepository) UpdateSubscription(subscription *domain.ReportSubscription) error {
+	subscription.UpdatedAt = time.Now().UTC()
+	r.subscriptions.replace(subscription.ID, *subscription)
+	return nil
+}
+
+func (r *ReportRepository) DeleteSubscription(id uuid.UUID) error {
+	if !r.subscriptions.remove(id) {
+		return fmt.Errorf("report subscription not found")
+	}
+	return nil
+}
+
+// ClaimDueSubscriptions claims due active subscriptions, earliest first, pushing next_run_at out by the lease
+func (r *ReportRepository) ClaimDueSubscriptions(limit int, lease time.Duration) ([]*domain.ReportSubscription, error) {
+	now := time.Now().UTC()
+	due := r.subscriptions.find(func(s *domain.ReportSubscription) bool {
+		return s.IsActive && !s.NextRunAt.After(now)
+	})
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextRunAt.Before(due[j].NextRunAt)
+	})
+	due = paginate(due, limit, 0)
+
+	claimed := make([]*domain.ReportSubscription, 0, len(due))
+	for _, s := range due {
+		r.subscriptions.update(s.ID, func(stored *domain.ReportSubscription) {
+			stored.NextRunAt = now.Add(lease)
+			copied := *stored
+			claimed = append(claimed, &copied)
+		})
+	}
+	return claimed, nil
+}
+
+func (r *ReportRepository) UpdateSchedule(id uuid.UUID, lastRunAt, nextRunAt time.Time) error {
+	r.subscriptions.update(id, func(s *domain.ReportSubscription) {
+		s.LastRunAt = &lastRunAt
+		s.NextRunAt = nextRunAt
+		s.UpdatedAt = time.Now().UTC()
+	})
+	return nil
+}
+
+func (r *ReportRepository) CreateRun(run *domain.ReportRun) error {
+	run.ID = newID(run.ID)
+	run.CreatedAt = time.Now().UTC()
+	r.runs.put(run.ID, *run)
+	return nil
+}
+
+func (r *ReportRepository) GetRunByID(id uuid.UUID) (*domain.ReportRun, error) {
+	run, ok := r.runs.get(id)
+	if !ok {
+		return nil, fmt.Errorf("report run not found")
+	}
+	return run, nil
+}
+
+func (r *ReportRepository) GetRunByDownloadToken(token string) (*domain.ReportRun, error) {
+	run, ok := r.runs.first(func(run *domain.ReportRun) bool {
+		return token != "" && run.DownloadToken == token
+	})
+	if !ok {
+		return nil, fmt.Errorf("report run not found")
+	}
+	return run, nil
+}
+
+// GetRunsBySubscription returns the most recent runs without their content
+func (r *ReportRepository) GetRunsBySubscription(subscriptionID uuid.UUID, limit int) ([]*domain.ReportRun, error) {
+	runs := paginate(r.runs.find(func(run *domain.ReportRun) bool {
+		return run.SubscriptionID == subscriptionID
+	}), limit, 0)
+	for _, run := range runs {
+		run.Content = nil
+	}
+	return runs, nil
+}
+
+func (r *ReportRepository) GetSecurityPostureData(orgID uuid.UUID, start, end time.Time) (*domain.ReportTable, error) {
+	return r.reportData(domain.ReportTypeSecurityPosture), nil
+}
+
+func (r *ReportRepository) GetVerificationSummaryData(orgID uuid.UUID, start, end time.Time) (*domain.ReportTable, error) {
+	return r.reportData(domain.ReportTypeVerificationSummary), nil
+}
+
+func (r *ReportRepository) GetDriftDigestData(orgID uuid.UUID, start, end time.Time) (*domain.ReportTable, error) {
+	return r.reportData(domain.ReportTypeDriftDigest), nil
+}
+
+func (r *ReportRepository) GetTrustChangesData(orgID uuid.UUID, start, end time.Time) (*domain.ReportTable, error) {
+	return r.reportData(domain.ReportTypeTrustChanges), nil
+}
+
+// reportData returns a copy of the configured table, or an empty one
+func (r *ReportRepository) reportData(reportType domain.ReportType) *domain.ReportTable {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, ok := r.data[reportType]
+	if !ok {
+		return &domain.ReportTable{Title: string(reportType), Columns: []string{}, Rows: [][]string{}}
+	}
+	copied := *data
+	return &copied
+}
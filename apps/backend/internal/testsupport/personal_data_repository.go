@@ -0,0 +1,231 @@
+package testsupport
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	_ domain.DataErasureRequestRepository = (*DataErasureRequestRepository)(nil)
+	_ domain.PersonalDataRepository       = (*PersonalDataRepository)(nil)
+)
+
+// DataErasureRequestRepository is an in-memory domain.DataErasureRequestRepository
+type DataErasureRequestRepository struct {
+	requests *table[domain.DataErasureRequest]
+}
+
+// NewDataErasureRequestRepository creates an empty in-memory data erasure request repository
+func NewDataErasureRequestRepository() *DataErasureRequestRepository {
+	return &DataErasureRequestRepository{requests: newTable[domain.DataErasureRequest]()}
+}
+
+func (r *DataErasureRequestRepository) Create(request *domain.DataErasureRequest) error {
+	open := r.requests.find(func(stored *domain.DataErasureRequest) bool {
+		return stored.UserID == request.UserID &&
+			(stored.Status == domain.DataErasureStatusPending || stored.Status == domain.DataErasureStatusApproved)
+	})
+	if len(open) > 0 {
+		return domain.ErrDataErasureRequestOpen
+	}
+	request.ID = newID(request.ID)
+	if request.CreatedAt.IsZero() {
+		request.CreatedAt = time.Now()
+	}
+	r.requests.put(request.ID, *request)
+	return nil
+}
+
+func (r *DataErasureRequestRepository) GetByID(id uuid.UUID) (*domain.DataErasureRequest, error) {
+	request, ok := r.requests.get(id)
+	if !ok {
+		return nil, domain.ErrDataErasureRequestNotFound
+	}
+	return request, nil
+}
+
+func (r *DataErasureRequestRepository) ListByOrganization(orgID uuid.UUID, status domain.DataErasureStatus, limit, offset int) ([]*domain.DataErasureRequest, error) {
+	return paginate(r.requests.find(func(request *domain.DataErasureRequest) bool {
+		return request.OrganizationID == orgID && (status == "" || request.Status == status)
+	}), limit, offset), nil
+}
+
+func (r *DataErasureRequestRepository) ListByUser(userID uuid.UUID) ([]*domain.DataErasureRequest, error) {
+	return r.requests.find(func(request *domain.DataErasureRequest) bool { return request.UserID == userID }), nil
+}
+
+func (r *DataErasureRequestRepository) Decide(request *domain.DataErasureRequest) (bool, error) {
+	decided := false
+	r.requests.update(request.ID, func(stored *domain.DataErasureRequest) {
+		if stored.Status != domain.DataErasureStatusPending {
+			return
+		}
+		stored.Status, stored.DecidedBy, stored.DecidedAt, stored.DecisionReason = request.Status, request.DecidedBy, request.DecidedAt, request.DecisionReason
+		decided = true
+	})
+	return decided, nil
+}
+
+func (r *DataErasureRequestRepository) Complete(request *domain.DataErasureRequest) error {
+	r.requests.update(request.ID, func(stored *domain.DataErasureRequest) {
+		if stored.Status != domain.DataErasureStatusApproved {
+			return
+		}
+		stored.Status, stored.Report, stored.CompletedAt = request.Status, request.Report, request.CompletedAt
+	})
+	return nil
+}
+
+// PersonalDataRepository is an in-memory domain.PersonalDataRepository. It rewrites the records
+// of the other in-memory repositories like the SQL repository's statements do.
+type PersonalDataRepository struct {
+	users          *UserRepository
+	auditLogs      *AuditLogRepository
+	events         *VerificationEventRepository
+	approvals      *ApprovalRequestRepository
+	tombstones     *TombstoneRepository
+	sdkTokens      *SDKTokenRepository
+	personalTokens *PersonalAccessTokenRepository
+	invitations    *InvitationRepository
+}
+
+// NewPersonalDataRepository creates a personal data repository scrubbing the given repositories
+func NewPersonalDataRepository(
+	users *UserRepository,
+	auditLogs *AuditLogRepository,
+	events *VerificationEventRepository,
+	approvals *ApprovalRequestRepository,
+	tombstones *TombstoneRepository,
+	sdkTokens *SDKTokenRepository,
+	personalTokens *PersonalAccessTokenRepository,
+	invitations *InvitationRepository,
+) *PersonalDataRepository {
+	return &PersonalDataRepository{
+		users:          users,
+		auditLogs:      auditLogs,
+		events:         events,
+		approvals:      approvals,
+		tombstones:     tombstones,
+		sdkTokens:      sdkTokens,
+		personalTokens: personalTokens,
+		invitations:    invitations,
+	}
+}
+
+func (r *PersonalDataRepository) Anonymize(user *domain.User, alias string) (map[string]int, error) {
+	counts := make(map[string]int)
+
+	counts["users"] = r.users.users.updateWhere(func(u *domain.User) bool {
+		return u.ID == user.ID && u.Email != alias
+	}, func(u *domain.User) {
+		now := time.Now()
+		u.Email, u.Name, u.AvatarURL, u.ProviderID = alias, domain.ErasedUserName, nil, user.ID.String()
+		u.PasswordHash, u.PasswordResetToken, u.PasswordResetExpiresAt = nil, nil, nil
+		u.MFAEnabled, u.MFASecret, u.MFARecoveryCodes, u.MFAEnrolledAt = false, nil, []string{}, nil
+		u.Status = domain.UserStatusDeactivated
+		if u.DeletedAt == nil {
+			u.DeletedAt = &now
+		}
+		u.UpdatedAt = now
+	})
+
+	// The user's own entries lose their IP address and user agent; any entry of the organization
+	// naming them in its metadata has the email and name replaced
+	names := func(value interface{}) bool {
+		return value == user.Email || (user.Name != "" && value == user.Name)
+	}
+	counts["audit_logs"] = r.auditLogs.logs.updateWhere(func(log *domain.AuditLog) bool {
+		if log.OrganizationID != user.OrganizationID {
+			return false
+		}
+		if log.UserID == user.ID && (log.IPAddress != "" || log.UserAgent != "") {
+			return true
+		}
+		for _, value := range log.Metadata {
+			if names(value) {
+				return true
+			}
+		}
+		return false
+	}, func(log *domain.AuditLog) {
+		if log.UserID == user.ID {
+			log.IPAddress, log.UserAgent = "", ""
+		}
+		metadata := make(map[string]interface{}, len(log.Metadata))
+		for key, value := range log.Metadata {
+			switch {
+			case value == user.Email:
+				value = alias
+			case user.Name != "" && value == user.Name:
+				value = domain.ErasedUserName
+			}
+			metadata[key] = value
+		}
+		log.Metadata = metadata
+	})
+
+	counts["verification_events"] = r.events.events.updateWhere(func(event *domain.VerificationEvent) bool {
+		initiatedByUser := (event.InitiatorUserID != nil && *event.InitiatorUserID == user.ID) ||
+			(event.InitiatorType == domain.InitiatorTypeUser && event.InitiatorID != nil && *event.InitiatorID == user.ID)
+		return event.OrganizationID == user.OrganizationID && initiatedByUser &&
+			((event.InitiatorName != nil && *event.InitiatorName != domain.ErasedUserName) ||
+				event.InitiatorIP != nil || event.InitiatorUserAgent != nil)
+	}, func(event *domain.VerificationEvent) {
+		if event.InitiatorName != nil {
+			name := domain.ErasedUserName
+			event.InitiatorName = &name
+		}
+		event.InitiatorIP, event.InitiatorUserAgent = nil, nil
+	})
+
+	counts["approval_requests"] = r.approvals.requests.updateWhere(func(request *domain.ApprovalRequest) bool {
+		if request.OrganizationID != user.OrganizationID {
+			return false
+		}
+		for _, decision := range request.Decisions {
+			if decision.UserID == user.ID && decision.UserEmail != alias {
+				return true
+			}
+		}
+		return false
+	}, func(request *domain.ApprovalRequest) {
+		decisions := append([]domain.ApprovalDecision(nil), request.Decisions...)
+		for i := range decisions {
+			if decisions[i].UserID == user.ID {
+				decisions[i].UserEmail = alias
+			}
+		}
+		request.Decisions = decisions
+	})
+
+	counts["entity_tombstones"] = r.tombstones.tombstones.updateWhere(func(tombstone *domain.Tombstone) bool {
+		return tombstone.OrganizationID == user.OrganizationID && tombstone.DeletedBy != nil &&
+			*tombstone.DeletedBy == user.ID && tombstone.DeletedByEmail != alias
+	}, func(tombstone *domain.Tombstone) {
+		tombstone.DeletedByEmail = alias
+	})
+
+	counts["invitations"] = r.invitations.invitations.updateWhere(func(invitation *domain.Invitation) bool {
+		return invitation.OrganizationID == user.OrganizationID && strings.EqualFold(invitation.Email, user.Email)
+	}, func(invitation *domain.Invitation) {
+		invitation.Email = alias
+	})
+
+	counts["sdk_tokens"] = r.sdkTokens.tokens.updateWhere(func(token *domain.SDKToken) bool {
+		return token.UserID == user.ID && (token.DeviceName != nil || token.DeviceFingerprint != nil ||
+			token.IPAddress != nil || token.UserAgent != nil || token.LastIPAddress != nil)
+	}, func(token *domain.SDKToken) {
+		token.DeviceName, token.DeviceFingerprint, token.IPAddress, token.UserAgent, token.LastIPAddress = nil, nil, nil, nil, nil
+	})
+
+	counts["personal_access_tokens"] = r.personalTokens.tokens.updateWhere(func(token *domain.PersonalAccessToken) bool {
+		return token.UserID == user.ID && token.LastUsedIP != nil
+	}, func(token *domain.PersonalAccessToken) {
+		token.LastUsedIP = nil
+	})
+
+	return counts, nil
+}
@@ -0,0 +1,155 @@
+package testsupport
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.PlatformOperatorRepository = (*PlatformOperatorRepository)(nil)
+
+// PlatformOperatorRepository is an in-memory domain.PlatformOperatorRepository
+type PlatformOperatorRepository struct {
+	operators      *table[domain.PlatformOperator]
+	actions        *table[domain.OperatorAction]
+	throttles      *table[domain.OrganizationThrottle] // Keyed by organization ID
+	impersonations *table[domain.ImpersonationRequest]
+}
+
+// NewPlatformOperatorRepository creates an empty in-memory platform operator repository
+func NewPlatformOperatorRepository() *PlatformOperatorRepository {
+	return &PlatformOperatorRepository{
+		operators:      newTable[domain.PlatformOperator](),
+		actions:        newTable[domain.OperatorAction](),
+		throttles:      newTable[domain.OrganizationThrottle](),
+		impersonations: newTable[domain.ImpersonationRequest](),
+	}
+}
+
+// CreateOperator stores the operator; emails and token hashes are unique, like the SQL constraints
+func (r *PlatformOperatorRepository) CreateOperator(operator *domain.PlatformOperator) error {
+	if _, exists := r.operators.first(func(o *domain.PlatformOperator) bool {
+		return o.Email == operator.Email || o.TokenHash == operator.TokenHash
+	}); exists {
+		return fmt.Errorf("platform operator %s already exists", operator.Email)
+	}
+	now := time.Now()
+	operator.ID = newID(operator.ID)
+	operator.CreatedAt = now
+	operator.UpdatedAt = now
+	r.operators.put(operator.ID, *operator)
+	return nil
+}
+
+func (r *PlatformOperatorRepository) GetOperator(id uuid.UUID) (*domain.PlatformOperator, error) {
+	operator, ok := r.operators.get(id)
+	if !ok {
+		return nil, domain.ErrPlatformOperatorNotFound
+	}
+	return operator, nil
+}
+
+func (r *PlatformOperatorRepository) GetOperatorByTokenHash(tokenHash string) (*domain.PlatformOperator, error) {
+	operator, ok := r.operators.first(func(o *domain.PlatformOperator) bool { return o.TokenHash == tokenHash })
+	if !ok {
+		return nil, domain.ErrPlatformOperatorNotFound
+	}
+	return operator, nil
+}
+
+// ListOperators lists every operator oldest first, like the SQL repository
+func (r *PlatformOperatorRepository) ListOperators() ([]*domain.PlatformOperator, error) {
+	return oldestFirst(r.operators.find(func(*domain.PlatformOperator) bool { return true })), nil
+}
+
+func (r *PlatformOperatorRepository) UpdateOperator(operator *domain.PlatformOperator) error {
+	operator.UpdatedAt = time.Now()
+	if !r.operators.replace(operator.ID, *operator) {
+		return domain.ErrPlatformOperatorNotFound
+	}
+	return nil
+}
+
+func (r *PlatformOperatorRepository) TouchOperator(id uuid.UUID, usedAt time.Time) error {
+	r.operators.update(id, func(o *domain.PlatformOperator) { o.LastUsedAt = &usedAt })
+	return nil
+}
+
+func (r *PlatformOperatorRepository) RecordAction(action *domain.OperatorAction) error {
+	action.ID = newID(action.ID)
+	if action.CreatedAt.IsZero() {
+		action.CreatedAt = time.Now()
+	}
+	stored := *action
+	stored.Details = maps.Clone(action.Details)
+	r.actions.put(action.ID, stored)
+	return nil
+}
+
+func (r *PlatformOperatorRepository) ListActions(limit, offset int) ([]*domain.OperatorAction, error) {
+	return paginate(r.actions.find(func(*domain.OperatorAction) bool { return true }), limit, offset), nil
+}
+
+func (r *PlatformOperatorRepository) SetThrottle(throttle *domain.OrganizationThrottle) error {
+	throttle.CreatedAt = time.Now()
+	r.throttles.put(throttle.OrganizationID, *throttle)
+	return nil
+}
+
+func (r *PlatformOperatorRepository) GetThrottle(orgID uuid.UUID) (*domain.OrganizationThrottle, error) {
+	throttle, ok := r.throttles.get(orgID)
+	if !ok {
+		return nil, domain.ErrOrganizationThrottleNotFound
+	}
+	return throttle, nil
+}
+
+func (r *PlatformOperatorRepository) ListThrottles() ([]*domain.OrganizationThrottle, error) {
+	return r.throttles.find(func(*domain.OrganizationThrottle) bool { return true }), nil
+}
+
+func (r *PlatformOperatorRepository) DeleteThrottle(orgID uuid.UUID) error {
+	r.throttles.remove(orgID)
+	return nil
+}
+
+func (r *PlatformOperatorRepository) CreateImpersonation(request *domain.ImpersonationRequest) error {
+	request.ID = newID(request.ID)
+	if request.CreatedAt.IsZero() {
+		request.CreatedAt = time.Now()
+	}
+	r.impersonations.put(request.ID, *request)
+	return nil
+}
+
+func (r *PlatformOperatorRepository) GetImpersonation(id uuid.UUID) (*domain.ImpersonationRequest, error) {
+	request, ok := r.impersonations.get(id)
+	if !ok {
+		return nil, domain.ErrImpersonationNotFound
+	}
+	return request, nil
+}
+
+func (r *PlatformOperatorRepository) GetImpersonationByTokenHash(tokenHash string) (*domain.ImpersonationRequest, error) {
+	request, ok := r.impersonations.first(func(i *domain.ImpersonationRequest) bool {
+		return tokenHash != "" && i.TokenHash == tokenHash
+	})
+	if !ok {
+		return nil, domain.ErrImpersonationNotFound
+	}
+	return request, nil
+}
+
+func (r *PlatformOperatorRepository) ListImpersonations(orgID *uuid.UUID, limit, offset int) ([]*domain.ImpersonationRequest, error) {
+	return paginate(r.impersonations.find(func(i *domain.ImpersonationRequest) bool {
+		return orgID == nil || i.OrganizationID == *orgID
+	}), limit, offset), nil
+}
+
+func (r *PlatformOperatorRepository) UpdateImpersonation(request *domain.ImpersonationRequest) error {
+	r.impersonations.replace(request.ID, *request)
+	return nil
+}
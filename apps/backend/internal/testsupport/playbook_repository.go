@@ -0,0 +1,106 @@
+package testsupport
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.PlaybookRepository = (*PlaybookRepository)(nil)
+
+// PlaybookRepository is an in-memory domain.PlaybookRepository
+type PlaybookRepository struct {
+	playbooks  *table[domain.Playbook]
+	executions *table[domain.PlaybookExecution]
+}
+
+// NewPlaybookRepository creates an empty in-memory playbook repository
+func NewPlaybookRepository() *PlaybookRepository {
+	return &PlaybookRepository{
+		playbooks:  newTable[domain.Playbook](),
+		executions: newTable[domain.PlaybookExecution](),
+	}
+}
+
+func (r *PlaybookRepository) CreatePlaybook(playbook *domain.Playbook) error {
+	now := time.Now()
+	playbook.ID = newID(playbook.ID)
+	playbook.CreatedAt = now
+	playbook.UpdatedAt = now
+	r.playbooks.put(playbook.ID, clonePlaybook(playbook))
+	return nil
+}
+
+func (r *PlaybookRepository) GetPlaybook(id uuid.UUID) (*domain.Playbook, error) {
+	playbook, ok := r.playbooks.get(id)
+	if !ok {
+		return nil, domain.ErrPlaybookNotFound
+	}
+	return playbook, nil
+}
+
+// GetPlaybooksByOrganization lists the organization's playbooks oldest first, like the SQL repository
+func (r *PlaybookRepository) GetPlaybooksByOrganization(orgID uuid.UUID) ([]*domain.Playbook, error) {
+	return oldestFirst(r.playbooks.find(func(p *domain.Playbook) bool {
+		return p.OrganizationID == orgID
+	})), nil
+}
+
+func (r *PlaybookRepository) UpdatePlaybook(playbook *domain.Playbook) error {
+	playbook.UpdatedAt = time.Now()
+	if !r.playbooks.replace(playbook.ID, clonePlaybook(playbook)) {
+		return domain.ErrPlaybookNotFound
+	}
+	return nil
+}
+
+func (r *PlaybookRepository) DeletePlaybook(id uuid.UUID) error {
+	r.playbooks.remove(id)
+	return nil
+}
+
+func (r *PlaybookRepository) CreateExecution(execution *domain.PlaybookExecution) error {
+	execution.ID = newID(execution.ID)
+	r.executions.put(execution.ID, clonePlaybookExecution(execution))
+	return nil
+}
+
+func (r *PlaybookRepository) GetExecution(id uuid.UUID) (*domain.PlaybookExecution, error) {
+	execution, ok := r.executions.get(id)
+	if !ok {
+		return nil, domain.ErrPlaybookExecutionNotFound
+	}
+	return execution, nil
+}
+
+func (r *PlaybookRepository) GetExecutionsByOrganization(orgID uuid.UUID, playbookID *uuid.UUID, limit, offset int) ([]*domain.PlaybookExecution, int, error) {
+	executions := r.executions.find(func(e *domain.PlaybookExecution) bool {
+		return e.OrganizationID == orgID && (playbookID == nil || e.PlaybookID == *playbookID)
+	})
+	return paginate(executions, limit, offset), len(executions), nil
+}
+
+func (r *PlaybookRepository) UpdateExecution(execution *domain.PlaybookExecution) error {
+	if !r.executions.replace(execution.ID, clonePlaybookExecution(execution)) {
+		return domain.ErrPlaybookExecutionNotFound
+	}
+	return nil
+}
+
+// clonePlaybook copies the playbook so stored slices are not shared with the caller
+func clonePlaybook(playbook *domain.Playbook) domain.Playbook {
+	stored := *playbook
+	stored.IncidentTypes = slices.Clone(playbook.IncidentTypes)
+	stored.Steps = slices.Clone(playbook.Steps)
+	return stored
+}
+
+// clonePlaybookExecution copies the execution so stored slices are not shared with the caller
+func clonePlaybookExecution(execution *domain.PlaybookExecution) domain.PlaybookExecution {
+	stored := *execution
+	stored.Steps = slices.Clone(execution.Steps)
+	stored.Log = slices.Clone(execution.Log)
+	return stored
+}
@@ -0,0 +1,180 @@
+package testsupport
+
+// Repositories holds one in-memory implementation of every domain repository,
+// wired to each other where the SQL repositories rely on joins
+type Repositories struct {
+	Agent                 *AgentRepository
+	AgentCertificate      *AgentCertificateRepository
+	AgentDelegation       *AgentDelegationRepository
+	AgentLineage          *AgentLineageRepository
+	AgentListing          *AgentListingRepository
+	AgentPeerPolicy       *AgentPeerPolicyRepository
+	AgentTimeline         *AgentTimelineRepository
+	Alert                 *AlertRepository
+	AlertSuppression      *AlertSuppressionRepository
+	APIKey                *APIKeyRepository
+	ApprovalChain         *ApprovalChainRepository
+	ApprovalRequest       *ApprovalRequestRepository
+	AttestationNonce      *AttestationNonceRepository
+	AttestationCadence    *AttestationCadencePolicyRepository
+	AuditLog              *AuditLogRepository
+	Capability            *CapabilityRepository
+	CapabilityDeprecation *CapabilityDeprecationRepository
+	CapabilityRequest     *CapabilityRequestRepository
+	ChangeRequest         *ChangeRequestRepository
+	CompromiseResponse    *CompromiseResponseRepository
+	ConnectionLatencySLO  *ConnectionLatencySLORepository
+	CustomRole            *CustomRoleRepository
+	DataErasureRequest    *DataErasureRequestRepository
+	DataPlacement         *DataPlacementRepository
+	DemoRecord            *DemoRecordRepository
+	DomainEventOutbox     *DomainEventOutboxRepository
+	DriftAnalytics        *DriftAnalyticsRepository
+	DriftRemediation      *DriftRemediationRepository
+	EmailQueue            *EmailQueueRepository
+	EmailSender           *OrganizationEmailSenderRepository
+	EmailSuppression      *EmailSuppressionRepository
+	EmergencyCredential   *EmergencyCredentialRepository
+	FeatureFlag           *FeatureFlagRepository
+	Idempotency           *IdempotencyRepository
+	Entitlement           *EntitlementRepository
+	Incident              *IncidentRepository
+	Invitation            *InvitationRepository
+	JobLease              *JobLeaseRepository
+	LoginAttempt          *LoginAttemptRepository
+	MCPAttestation        *MCPAttestationRepository
+	MCPHealth             *MCPHealthRepository
+	MCPServer             *MCPServerRepository
+	MCPServerCapability   *MCPServerCapabilityRepository
+	MCPToolGrant          *MCPToolGrantRepository
+	NamingPolicy          *NamingPolicyRepository
+	Notification          *NotificationRepository
+	NotificationRoute     *NotificationRouteRepository
+	Organization          *OrganizationRepository
+	PersonalAccessToken   *PersonalAccessTokenRepository
+	PersonalData          *PersonalDataRepository
+	PlatformOperator      *PlatformOperatorRepository
+	Playbook              *PlaybookRepository
+	PolicyDecision        *PolicyDecisionRepository
+	Report                *ReportRepository
+	RuntimeSetting        *RuntimeSettingRepository
+	SAMLConfig            *SAMLConfigRepository
+	SBOM                  *AgentSBOMRepository
+	SDKToken              *SDKTokenRepository
+	Security              *SecurityRepository
+	SecurityPolicy        *SecurityPolicyRepository
+	SharedKey             *SharedKeyRepository
+	SIEMExporter          *SIEMExporterRepository
+	Tag                   *TagRepository
+	TicketConnector       *TicketConnectorRepository
+	Tombstone             *TombstoneRepository
+	TrustBoundary         *TrustBoundaryRepository
+	TrustScoreTrigger     *TrustScoreTriggerRepository
+	TrustScore            *TrustScoreRepository
+	UsageRollup           *UsageRollupRepository
+	User                  *UserRepository
+	Verification          *VerificationRepository
+	VerificationEvent     *VerificationEventRepository
+	VerificationExport    *VerificationExportRepository
+	Webhook               *WebhookRepository
+}
+
+// NewRepositories creates an empty, fully wired set of in-memory repositories
+func NewRepositories() *Repositories {
+	agents := NewAgentRepository()
+	users := NewUserRepository()
+	alerts := NewAlertRepository()
+	auditLogs := NewAuditLogRepository()
+	servers := NewMCPServerRepository()
+	attestations := NewMCPAttestationRepository(agents)
+	capabilities := NewCapabilityRepository(agents)
+	requests := NewCapabilityRequestRepository(agents, users)
+	events := NewVerificationEventRepository()
+	tags := NewTagRepository()
+	apiKeys := NewAPIKeyRepository(agents)
+	serverCapabilities := NewMCPServerCapabilityRepository()
+	deprecations := NewCapabilityDeprecationRepository(agents, servers)
+	trustScores := NewTrustScoreRepository(agents)
+	approvalRequests := NewApprovalRequestRepository()
+	tombstones := NewTombstoneRepository(apiKeys, capabilities, attestations, serverCapabilities, events, alerts, auditLogs)
+	sdkTokens := NewSDKTokenRepository()
+	personalTokens := NewPersonalAccessTokenRepository()
+	invitations := NewInvitationRepository()
+
+	return &Repositories{
+		Agent:                 agents,
+		AgentCertificate:      NewAgentCertificateRepository(),
+		AgentDelegation:       NewAgentDelegationRepository(),
+		AgentLineage:          NewAgentLineageRepository(),
+		AgentListing:          NewAgentListingRepository(),
+		AgentPeerPolicy:       NewAgentPeerPolicyRepository(),
+		AgentTimeline:         NewAgentTimelineRepository(events, attestations, servers, trustScores, auditLogs, capabilities, deprecations, alerts),
+		Alert:                 alerts,
+		AlertSuppression:      NewAlertSuppressionRepository(alerts),
+		APIKey:                apiKeys,
+		ApprovalChain:         NewApprovalChainRepository(),
+		ApprovalRequest:       approvalRequests,
+		AttestationNonce:      NewAttestationNonceRepository(),
+		AttestationCadence:    NewAttestationCadencePolicyRepository(),
+		AuditLog:              auditLogs,
+		Capability:            capabilities,
+		CapabilityDeprecation: deprecations,
+		CapabilityRequest:     requests,
+		ChangeRequest:         NewChangeRequestRepository(),
+		CompromiseResponse:    NewCompromiseResponseRepository(),
+		ConnectionLatencySLO:  NewConnectionLatencySLORepository(),
+		CustomRole:            NewCustomRoleRepository(),
+		DataErasureRequest:    NewDataErasureRequestRepository(),
+		DataPlacement:         NewDataPlacementRepository(),
+		DemoRecord:            NewDemoRecordRepository(),
+		DomainEventOutbox:     NewDomainEventOutboxRepository(),
+		DriftAnalytics:        NewDriftAnalyticsRepository(events, alerts, agents, tags, deprecations),
+		DriftRemediation:      NewDriftRemediationRepository(),
+		EmailQueue:            NewEmailQueueRepository(),
+		EmailSender:           NewOrganizationEmailSenderRepository(),
+		EmailSuppression:      NewEmailSuppressionRepository(),
+		EmergencyCredential:   NewEmergencyCredentialRepository(),
+		FeatureFlag:           NewFeatureFlagRepository(),
+		Idempotency:           NewIdempotencyRepository(),
+		Entitlement:           NewEntitlementRepository(agents, users, attestations, capabilities, requests, auditLogs),
+		Incident:              NewIncidentRepository(),
+		Invitation:            invitations,
+		JobLease:              NewJobLeaseRepository(),
+		LoginAttempt:          NewLoginAttemptRepository(),
+		MCPAttestation:        attestations,
+		MCPHealth:             NewMCPHealthRepository(),
+		MCPServer:             servers,
+		MCPServerCapability:   serverCapabilities,
+		MCPToolGrant:          NewMCPToolGrantRepository(),
+		NamingPolicy:          NewNamingPolicyRepository(),
+		Notification:          NewNotificationRepository(),
+		NotificationRoute:     NewNotificationRouteRepository(),
+		Organization:          NewOrganizationRepository(),
+		PersonalAccessToken:   personalTokens,
+		PersonalData:          NewPersonalDataRepository(users, auditLogs, events, approvalRequests, tombstones, sdkTokens, personalTokens, invitations),
+		PlatformOperator:      NewPlatformOperatorRepository(),
+		Playbook:              NewPlaybookRepository(),
+		PolicyDecision:        NewPolicyDecisionRepository(),
+		Report:                NewReportRepository(),
+		RuntimeSetting:        NewRuntimeSettingRepository(),
+		SAMLConfig:            NewSAMLConfigRepository(),
+		SBOM:                  NewAgentSBOMRepository(),
+		SDKToken:              sdkTokens,
+		Security:              NewSecurityRepository(alerts, agents),
+		SecurityPolicy:        NewSecurityPolicyRepository(),
+		SharedKey:             NewSharedKeyRepository(agents),
+		SIEMExporter:          NewSIEMExporterRepository(),
+		Tag:                   tags,
+		TicketConnector:       NewTicketConnectorRepository(),
+		Tombstone:             tombstones,
+		TrustBoundary:         NewTrustBoundaryRepository(),
+		TrustScoreTrigger:     NewTrustScoreTriggerRepository(),
+		TrustScore:            trustScores,
+		UsageRollup:           NewUsageRollupRepository(),
+		User:                  users,
+		Verification:          NewVerificationRepository(),
+		VerificationEvent:     events,
+		VerificationExport:    NewVerificationExportRepository(),
+		Webhook:               NewWebhookRepository(),
+	}
+}
@@ -0,0 +1,69 @@
+package testsupport_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoriesCopyRecords(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	agent := testsupport.NewAgent(uuid.New())
+	require.NoError(t, repos.Agent.Create(agent))
+
+	agent.Name = "changed-after-create"
+	fetched, err := repos.Agent.GetByID(agent.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, "changed-after-create", fetched.Name)
+
+	fetched.Status = domain.AgentStatusRevoked
+	again, err := repos.Agent.GetByID(agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AgentStatusVerified, again.Status)
+
+	_, err = repos.Agent.GetByID(uuid.New())
+	assert.EqualError(t, err, "agent not found")
+}
+
+func TestListingsAreNewestFirstAndPaginated(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	agent := testsupport.NewAgent(uuid.New())
+
+	var ids []uuid.UUID
+	for i := 0; i < 3; i++ {
+		event := testsupport.NewVerificationEvent(agent, func(e *domain.VerificationEvent) {
+			e.CreatedAt = time.Now().Add(-time.Duration(2*(3-i)) * time.Minute)
+		})
+		require.NoError(t, repos.VerificationEvent.Create(event))
+		ids = append(ids, event.ID)
+	}
+
+	page, total, err := repos.VerificationEvent.GetByOrganization(agent.OrganizationID, 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, page, 2)
+	assert.Equal(t, ids[2], page[0].ID)
+	assert.Equal(t, ids[1], page[1].ID)
+
+	recent, err := repos.VerificationEvent.GetRecentEvents(agent.OrganizationID, 5)
+	require.NoError(t, err)
+	assert.Len(t, recent, 2, "backdated events outside the window are excluded")
+}
+
+func TestCompromiseFreezeStopsTrustScoreUpdates(t *testing.T) {
+	repos := testsupport.NewRepositories()
+	agent := testsupport.NewAgent(uuid.New())
+	require.NoError(t, repos.Agent.Create(agent))
+
+	require.NoError(t, repos.Agent.FreezeTrustScore(agent.ID))
+	require.NoError(t, repos.Agent.UpdateTrustScore(agent.ID, 0.1))
+
+	stored, err := repos.Agent.GetByID(agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, agent.TrustScore, stored.TrustScore)
+}
@@ -0,0 +1,67 @@
+package testsupport
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.RuntimeSettingRepository = (*RuntimeSettingRepository)(nil)
+
+// RuntimeSettingRepository is an in-memory domain.RuntimeSettingRepository
+type RuntimeSettingRepository struct {
+	mu       sync.RWMutex
+	settings map[string]domain.RuntimeSetting
+	changes  *table[domain.RuntimeSettingChange]
+}
+
+// NewRuntimeSettingRepository creates an empty in-memory runtime setting repository
+func NewRuntimeSettingRepository() *RuntimeSettingRepository {
+	return &RuntimeSettingRepository{
+		settings: make(map[string]domain.RuntimeSetting),
+		changes:  newTable[domain.RuntimeSettingChange](),
+	}
+}
+
+// List returns every stored setting ordered by key, like the SQL repository
+func (r *RuntimeSettingRepository) List() ([]*domain.RuntimeSetting, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	settings := make([]*domain.RuntimeSetting, 0, len(r.settings))
+	for _, setting := range r.settings {
+		setting := setting
+		settings = append(settings, &setting)
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings, nil
+}
+
+func (r *RuntimeSettingRepository) Set(setting *domain.RuntimeSetting, change *domain.RuntimeSettingChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.settings[setting.Key] = *setting
+	r.recordChange(change)
+	return nil
+}
+
+func (r *RuntimeSettingRepository) Delete(key string, change *domain.RuntimeSettingChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.settings, key)
+	r.recordChange(change)
+	return nil
+}
+
+func (r *RuntimeSettingRepository) recordChange(change *domain.RuntimeSettingChange) {
+	change.ID = newID(change.ID)
+	r.changes.put(change.ID, *change)
+}
+
+func (r *RuntimeSettingRepository) ListChanges(key string, limit, offset int) ([]*domain.RuntimeSettingChange, error) {
+	changes := r.changes.find(func(c *domain.RuntimeSettingChange) bool { return key == "" || c.Key == key })
+	return paginate(changes, limit, offset), nil
+}
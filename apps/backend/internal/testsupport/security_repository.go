@@ -0,0 +1,968 @@
+package testsupport
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var (
+	_ domain.AlertRepository              = (*AlertRepository)(nil)
+	_ domain.SecurityRepository           = (*SecurityRepository)(nil)
+	_ domain.SecurityPolicyRepository     = (*SecurityPolicyRepository)(nil)
+	_ domain.CompromiseResponseRepository = (*CompromiseResponseRepository)(nil)
+	_ domain.PolicyDecisionRepository     = (*PolicyDecisionRepository)(nil)
+	_ domain.TrustBoundaryRepository      = (*TrustBoundaryRepository)(nil)
+	_ domain.AlertSuppressionRepository   = (*AlertSuppressionRepository)(nil)
+	_ domain.SharedKeyRepository          = (*SharedKeyRepository)(nil)
+	_ domain.IncidentRepository           = (*IncidentRepository)(nil)
+)
+
+// AlertRepository is an in-memory domain.AlertRepository
+type AlertRepository struct {
+	alerts *table[domain.Alert]
+}
+
+// NewAlertRepository creates an empty in-memory alert repository
+func NewAlertRepository() *AlertRepository {
+	return &AlertRepository{alerts: newTable[domain.Alert]()}
+}
+
+// Create stores the alert; a preset CreatedAt is kept so tests can backdate alerts
+func (r *AlertRepository) Create(alert *domain.Alert) error {
+	alert.ID = newID(alert.ID)
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+	r.alerts.put(alert.ID, *alert)
+	return nil
+}
+
+func (r *AlertRepository) GetByID(id uuid.UUID) (*domain.Alert, error) {
+	alert, ok := r.alerts.get(id)
+	if !ok {
+		return nil, fmt.Errorf("alert not found")
+	}
+	return alert, nil
+}
+
+func (r *AlertRepository) GetByOrganization(orgID uuid.UUID, limit, offset int) ([]*domain.Alert, error) {
+	return r.GetByOrganizationFiltered(orgID, "", limit, offset)
+}
+
+// GetByOrganizationFiltered filters by "acknowledged" or "unacknowledged"; any other status returns every alert
+func (r *AlertRepository) GetByOrganizationFiltered(orgID uuid.UUID, status string, limit, offset int) ([]*domain.Alert, error) {
+	return paginate(r.alerts.find(alertStatusFilter(orgID, status)), limit, offset), nil
+}
+
+func (r *AlertRepository) CountByOrganization(orgID uuid.UUID) (int, error) {
+	return r.CountByOrganizationFiltered(orgID, "")
+}
+
+func (r *AlertRepository) CountByOrganizationFiltered(orgID uuid.UUID, status string) (int, error) {
+	return len(r.alerts.find(alertStatusFilter(orgID, status))), nil
+}
+
+func (r *AlertRepository) GetUnacknowledged(orgID uuid.UUID) ([]*domain.Alert, error) {
+	return r.alerts.find(alertStatusFilter(orgID, "unacknowledged")), nil
+}
+
+func (r *AlertRepository) GetByResourceID(resourceID uuid.UUID, limit, offset int) ([]*domain.Alert, error) {
+	return paginate(r.alerts.find(func(a *domain.Alert) bool {
+		return a.ResourceID == resourceID
+	}), limit, offset), nil
+}
+
+func (r *AlertRepository) GetUnacknowledgedByResourceID(resourceID uuid.UUID) ([]*domain.Alert, error) {
+	return r.alerts.find(func(a *domain.Alert) bool {
+		return a.ResourceID == resourceID && !a.IsAcknowledged
+	}), nil
+}
+
+func (r *AlertRepository) Acknowledge(id, userID uuid.UUID) error {
+	now := time.Now()
+	r.alerts.update(id, func(a *domain.Alert) {
+		a.IsAcknowledged = true
+		a.AcknowledgedBy = &userID
+		a.AcknowledgedAt = &now
+	})
+	return nil
+}
+
+func (r *AlertRepository) BulkAcknowledge(orgID uuid.UUID, userID uuid.UUID) (int, error) {
+	now := time.Now()
+	return r.alerts.updateWhere(alertStatusFilter(orgID, "unacknowledged"), func(a *domain.Alert) {
+		a.IsAcknowledged = true
+		a.AcknowledgedBy = &userID
+		a.AcknowledgedAt = &now
+	}), nil
+}
+
+func (r *AlertRepository) Delete(id uuid.UUID) error {
+	r.alerts.remove(id)
+	return nil
+}
+
+func alertStatusFilter(orgID uuid.UUID, status string) func(*domain.Alert) bool {
+	return func(a *domain.Alert) bool {
+		if a.OrganizationID != orgID {
+			return false
+		}
+		switch status {
+		case "acknowledged":
+			return a.IsAcknowledged
+		case "unacknowledged":
+			return !a.IsAcknowledged
+		default:
+			return true
+		}
+	}
+}
+
+// SecurityRepository is an in-memory domain.SecurityRepository
+type SecurityRepository struct {
+	threats     *table[domain.Threat]
+	anomalies   *table[domain.Anomaly]
+	incidents   *table[domain.SecurityIncident]
+	scans       *table[domain.SecurityScanResult]
+	annotations *table[domain.ThreatAnnotation]
+	alerts      *AlertRepository
+	agents      *AgentRepository
+}
+
+// NewSecurityRepository creates an empty in-memory security repository. Like the SQL
+// repository, metrics count threats from alerts and average trust scores over agents;
+// either may be nil.
+func NewSecurityRepository(alerts *AlertRepository, agents *AgentRepository) *SecurityRepository {
+	return &SecurityRepository{
+		threats:     newTable[domain.Threat](),
+		anomalies:   newTable[domain.Anomaly](),
+		incidents:   newTable[domain.SecurityIncident](),
+		scans:       newTable[domain.SecurityScanResult](),
+		annotations: newTable[domain.ThreatAnnotation](),
+		alerts:      alerts,
+		agents:      agents,
+	}
+}
+
+func (r *SecurityRepository) CreateThreat(threat *domain.Threat) error {
+	threat.ID = newID(threat.ID)
+	if threat.CreatedAt.IsZero() {
+		threat.CreatedAt = time.Now().UTC()
+	}
+	r.threats.put(threat.ID, *threat)
+	return nil
+}
+
+func (r *SecurityRepository) GetThreats(orgID uuid.UUID, limit, offset int) ([]*domain.Threat, error) {
+	return paginate(r.threats.find(func(t *domain.Threat) bool {
+		return t.OrganizationID == orgID
+	}), limit, offset), nil
+}
+
+func (r *SecurityRepository) GetThreatByID(id uuid.UUID) (*domain.Threat, error) {
+	threat, ok := r.threats.get(id)
+	if !ok {
+		return nil, fmt.Errorf("threat not found")
+	}
+	return threat, nil
+}
+
+func (r *SecurityRepository) BlockThreat(id uuid.UUID) error {
+	r.threats.update(id, func(t *domain.Threat) {
+		t.IsBlocked = true
+	})
+	return nil
+}
+
+func (r *SecurityRepository) ResolveThreat(id uuid.UUID) error {
+	now := time.Now().UTC()
+	r.threats.update(id, func(t *domain.Threat) {
+		t.ResolvedAt = &now
+	})
+	return nil
+}
+
+// SearchThreatAlerts applies the same filters and sorting as the SQL repository to the alerts
+// repository; it finds nothing when the repository was created without one
+func (r *SecurityRepository) SearchThreatAlerts(orgID uuid.UUID, params domain.ThreatQueryParams) ([]*domain.Alert, int, error) {
+	if r.alerts == nil {
+		return make([]*domain.Alert, 0), 0, nil
+	}
+	if params.Limit <= 0 {
+		params.Limit = 50
+	}
+
+	search := strings.ToLower(params.Search)
+	alerts := r.alerts.alerts.find(func(a *domain.Alert) bool {
+		return a.OrganizationID == orgID &&
+			(len(params.Severities) == 0 || slices.Contains(params.Severities, a.Severity)) &&
+			(len(params.AlertTypes) == 0 || slices.Contains(params.AlertTypes, a.AlertType)) &&
+			(params.Blocked == nil || a.IsAcknowledged == *params.Blocked) &&
+			(params.TargetType == "" || a.ResourceType == params.TargetType) &&
+			(params.TargetID == nil || a.ResourceID == *params.TargetID) &&
+			(params.From == nil || !a.CreatedAt.Before(*params.From)) &&
+			(params.To == nil || !a.CreatedAt.After(*params.To)) &&
+			(search == "" || strings.Contains(strings.ToLower(a.Title), search) ||
+				strings.Contains(strings.ToLower(a.Description), search))
+	})
+
+	sortSearchResults(alerts, params.SortBy, params.SortOrder, func(a *domain.Alert) searchSortKey {
+		return searchSortKey{createdAt: a.CreatedAt, severity: a.Severity, title: a.Title}
+	})
+	return paginate(alerts, params.Limit, params.Offset), len(alerts), nil
+}
+
+func (r *SecurityRepository) CreateThreatAnnotation(annotation *domain.ThreatAnnotation) error {
+	annotation.ID = newID(annotation.ID)
+	if annotation.CreatedAt.IsZero() {
+		annotation.CreatedAt = time.Now().UTC()
+	}
+	r.annotations.put(annotation.ID, *annotation)
+	return nil
+}
+
+// GetThreatAnnotations returns a threat's annotations, oldest first
+func (r *SecurityRepository) GetThreatAnnotations(threatID uuid.UUID) ([]*domain.ThreatAnnotation, error) {
+	return oldestFirst(r.annotations.find(func(a *domain.ThreatAnnotation) bool {
+		return a.ThreatID == threatID
+	})), nil
+}
+
+func (r *SecurityRepository) CreateAnomaly(anomaly *domain.Anomaly) error {
+	anomaly.ID = newID(anomaly.ID)
+	if anomaly.CreatedAt.IsZero() {
+		anomaly.CreatedAt = time.Now().UTC()
+	}
+	r.anomalies.put(anomaly.ID, *anomaly)
+	return nil
+}
+
+func (r *SecurityRepository) GetAnomalies(orgID uuid.UUID, limit, offset int) ([]*domain.Anomaly, error) {
+	return paginate(r.anomalies.find(func(a *domain.Anomaly) bool {
+		return a.OrganizationID == orgID
+	}), limit, offset), nil
+}
+
+func (r *SecurityRepository) GetAnomalyByID(id uuid.UUID) (*domain.Anomaly, error) {
+	anomaly, ok := r.anomalies.get(id)
+	if !ok {
+		return nil, fmt.Errorf("anomaly not found")
+	}
+	return anomaly, nil
+}
+
+// SearchAnomalies applies the same filters and sorting as the SQL repository
+func (r *SecurityRepository) SearchAnomalies(orgID uuid.UUID, params domain.AnomalyQueryParams) ([]*domain.Anomaly, int, error) {
+	if params.Limit <= 0 {
+		params.Limit = 50
+	}
+
+	search := strings.ToLower(params.Search)
+	anomalies := r.anomalies.find(func(a *domain.Anomaly) bool {
+		return a.OrganizationID == orgID &&
+			(len(params.Severities) == 0 || slices.Contains(params.Severities, a.Severity)) &&
+			(len(params.AnomalyTypes) == 0 || slices.Contains(params.AnomalyTypes, a.AnomalyType)) &&
+			(params.ResourceType == "" || a.ResourceType == params.ResourceType) &&
+			(params.ResourceID == nil || a.ResourceID == *params.ResourceID) &&
+			a.Confidence >= params.MinConfidence &&
+			(params.From == nil || !a.CreatedAt.Before(*params.From)) &&
+			(params.To == nil || !a.CreatedAt.After(*params.To)) &&
+			(search == "" || strings.Contains(strings.ToLower(a.Title), search) ||
+				strings.Contains(strings.ToLower(a.Description), search))
+	})
+
+	sortSearchResults(anomalies, params.SortBy, params.SortOrder, func(a *domain.Anomaly) searchSortKey {
+		return searchSortKey{createdAt: a.CreatedAt, severity: a.Severity, confidence: a.Confidence, title: a.Title}
+	})
+	return paginate(anomalies, params.Limit, params.Offset), len(anomalies), nil
+}
+
+func (r *SecurityRepository) CreateIncident(incident *domain.SecurityIncident) error {
+	now := time.Now().UTC()
+	incident.ID = newID(incident.ID)
+	if incident.Status == "" {
+		incident.Status = domain.IncidentStatusOpen
+	}
+	if incident.CreatedAt.IsZero() {
+		incident.CreatedAt = now
+	}
+	incident.UpdatedAt = now
+	r.incidents.put(incident.ID, *incident)
+	return nil
+}
+
+// GetIncidents filters by status unless it is empty
+func (r *SecurityRepository) GetIncidents(orgID uuid.UUID, status domain.IncidentStatus, limit, offset int) ([]*domain.SecurityIncident, error) {
+	return paginate(r.incidents.find(func(i *domain.SecurityIncident) bool {
+		return i.OrganizationID == orgID && (status == "" || i.Status == status)
+	}), limit, offset), nil
+}
+
+func (r *SecurityRepository) GetIncidentByID(id uuid.UUID) (*domain.SecurityIncident, error) {
+	incident, ok := r.incidents.get(id)
+	if !ok {
+		return nil, fmt.Errorf("incident not found")
+	}
+	return incident, nil
+}
+
+// UpdateIncidentStatus records the resolver and notes only when the incident is resolved
+func (r *SecurityRepository) UpdateIncidentStatus(id uuid.UUID, status domain.IncidentStatus, resolvedBy *uuid.UUID, notes string) error {
+	now := time.Now().UTC()
+	r.incidents.update(id, func(i *domain.SecurityIncident) {
+		i.Status = status
+		i.UpdatedAt = now
+		if status != domain.IncidentStatusOpen && i.AcknowledgedAt == nil {
+			i.AcknowledgedAt = &now
+		}
+		if status == domain.IncidentStatusResolved {
+			i.ResolvedAt = &now
+			i.ResolvedBy = resolvedBy
+			i.ResolutionNotes = notes
+		}
+	})
+	return nil
+}
+
+func (r *SecurityRepository) AssignIncident(id uuid.UUID, assignee *uuid.UUID) error {
+	r.incidents.update(id, func(i *domain.SecurityIncident) {
+		i.AssignedTo = assignee
+		i.UpdatedAt = time.Now().UTC()
+	})
+	return nil
+}
+
+// ListUnresolvedIncidents returns the open and investigating incidents of every organization, oldest first
+func (r *SecurityRepository) ListUnresolvedIncidents() ([]*domain.SecurityIncident, error) {
+	incidents := r.incidents.find(func(i *domain.SecurityIncident) bool {
+		return i.Status == domain.IncidentStatusOpen || i.Status == domain.IncidentStatusInvestigating
+	})
+	slices.Reverse(incidents)
+	return incidents, nil
+}
+
+func (r *SecurityRepository) DeleteIncident(id uuid.UUID) error {
+	r.incidents.remove(id)
+	return nil
+}
+
+// GetSecurityMetrics computes the same counts and score as the SQL repository; the threat trend is not populated
+func (r *SecurityRepository) GetSecurityMetrics(orgID uuid.UUID) (*domain.SecurityMetrics, error) {
+	metrics := &domain.SecurityMetrics{}
+
+	severities := make(map[domain.AlertSeverity]int)
+	if r.alerts != nil {
+		for _, alert := range r.alerts.alerts.find(alertStatusFilter(orgID, "")) {
+			metrics.TotalThreats++
+			if alert.IsAcknowledged {
+				metrics.BlockedThreats++
+			}
+			if alert.Severity == domain.AlertSeverityHigh {
+				metrics.HighSeverityCount++
+			}
+			severities[alert.Severity]++
+		}
+	}
+	metrics.ActiveThreats = metrics.TotalThreats - metrics.BlockedThreats
+
+	for _, anomaly := range r.anomalies.find(func(a *domain.Anomaly) bool { return a.OrganizationID == orgID }) {
+		metrics.TotalAnomalies++
+		if anomaly.Severity == domain.AlertSeverityCritical {
+			metrics.HighSeverityCount++
+		}
+	}
+
+	metrics.OpenIncidents = len(r.incidents.find(func(i *domain.SecurityIncident) bool {
+		return i.OrganizationID == orgID &&
+			(i.Status == domain.IncidentStatusOpen || i.Status == domain.IncidentStatusInvestigating)
+	}))
+
+	if r.agents != nil {
+		agents, _ := r.agents.GetByOrganization(orgID)
+		for _, agent := range agents {
+			metrics.AverageTrustScore += agent.TrustScore
+		}
+		if len(agents) > 0 {
+			metrics.AverageTrustScore /= float64(len(agents))
+		}
+	}
+
+	metrics.SecurityScore = 100.0
+	if metrics.TotalThreats > 0 {
+		metrics.SecurityScore -= float64(metrics.ActiveThreats) / float64(metrics.TotalThreats) * 30
+	}
+	metrics.SecurityScore -= float64(metrics.HighSeverityCount) * 10
+	metrics.SecurityScore -= float64(metrics.OpenIncidents) * 5
+	if metrics.SecurityScore < 0 {
+		metrics.SecurityScore = 0
+	}
+
+	for _, severity := range []domain.AlertSeverity{
+		domain.AlertSeverityCritical, domain.AlertSeverityHigh, domain.AlertSeverityWarning, domain.AlertSeverityInfo,
+	} {
+		if count := severities[severity]; count > 0 {
+			metrics.SeverityDistribution = append(metrics.SeverityDistribution, domain.SeverityDistribution{
+				Severity: strings.ToUpper(string(severity[:1])) + string(severity[1:]),
+				Count:    count,
+			})
+		}
+	}
+
+	return metrics, nil
+}
+
+func (r *SecurityRepository) CountOpenIncidents(orgID uuid.UUID) (int, error) {
+	return len(r.incidents.find(func(i *domain.SecurityIncident) bool {
+		return i.OrganizationID == orgID &&
+			(i.Status == domain.IncidentStatusOpen || i.Status == domain.IncidentStatusInvestigating)
+	})), nil
+}
+
+func (r *SecurityRepository) CreateSecurityScan(scan *domain.SecurityScanResult) error {
+	scan.ScanID = newID(scan.ScanID)
+	if scan.StartedAt.IsZero() {
+		scan.StartedAt = time.Now().UTC()
+	}
+	r.scans.put(scan.ScanID, *scan)
+	return nil
+}
+
+func (r *SecurityRepository) GetSecurityScan(scanID uuid.UUID) (*domain.SecurityScanResult, error) {
+	scan, ok := r.scans.get(scanID)
+	if !ok {
+		return nil, fmt.Errorf("scan not found")
+	}
+	return scan, nil
+}
+
+// SecurityPolicyRepository is an in-memory domain.SecurityPolicyRepository
+type SecurityPolicyRepository struct {
+	policies *table[domain.SecurityPolicy]
+}
+
+// NewSecurityPolicyRepository creates an empty in-memory security policy repository
+func NewSecurityPolicyRepository() *SecurityPolicyRepository {
+	return &SecurityPolicyRepository{policies: newTable[domain.SecurityPolicy]()}
+}
+
+func (r *SecurityPolicyRepository) Create(policy *domain.SecurityPolicy) error {
+	now := time.Now()
+	policy.ID = newID(policy.ID)
+	policy.CreatedAt = now
+	policy.UpdatedAt = now
+	r.policies.put(policy.ID, *policy)
+	return nil
+}
+
+func (r *SecurityPolicyRepository) GetByID(id uuid.UUID) (*domain.SecurityPolicy, error) {
+	policy, ok := r.policies.get(id)
+	if !ok {
+		return nil, fmt.Errorf("security policy not found")
+	}
+	return policy, nil
+}
+
+func (r *SecurityPolicyRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.SecurityPolicy, error) {
+	return r.byPriority(func(p *domain.SecurityPolicy) bool {
+		return p.OrganizationID == orgID
+	}), nil
+}
+
+func (r *SecurityPolicyRepository) GetActiveByOrganization(orgID uuid.UUID) ([]*domain.SecurityPolicy, error) {
+	return r.byPriority(func(p *domain.SecurityPolicy) bool {
+		return p.OrganizationID == orgID && p.IsEnabled
+	}), nil
+}
+
+func (r *SecurityPolicyRepository) GetByType(orgID uuid.UUID, policyType domain.PolicyType) ([]*domain.SecurityPolicy, error) {
+	return r.byPriority(func(p *domain.SecurityPolicy) bool {
+		return p.OrganizationID == orgID && p.PolicyType == policyType && p.IsEnabled
+	}), nil
+}
+
+func (r *SecurityPolicyRepository) Update(policy *domain.SecurityPolicy) error {
+	policy.UpdatedAt = time.Now()
+	if !r.policies.replace(policy.ID, *policy) {
+		return fmt.Errorf("security policy not found")
+	}
+	return nil
+}
+
+func (r *SecurityPolicyRepository) Delete(id uuid.UUID) error {
+	if !r.policies.remove(id) {
+		return fmt.Errorf("security policy not found")
+	}
+	return nil
+}
+
+// byPriority returns matching policies highest priority first, newest first within a priority
+func (r *SecurityPolicyRepository) byPriority(match func(*domain.SecurityPolicy) bool) []*domain.SecurityPolicy {
+	policies := r.policies.find(match)
+	sort.SliceStable(policies, func(i, j int) bool {
+		return policies[i].Priority > policies[j].Priority
+	})
+	return policies
+}
+
+// CompromiseResponseRepository is an in-memory domain.CompromiseResponseRepository
+type CompromiseResponseRepository struct {
+	policies  *table[domain.CompromiseResponsePolicy] // keyed by organization
+	responses *table[domain.CompromiseResponse]
+}
+
+// NewCompromiseResponseRepository creates an empty in-memory compromise response repository
+func NewCompromiseResponseRepository() *CompromiseResponseRepository {
+	return &CompromiseResponseRepository{
+		policies:  newTable[domain.CompromiseResponsePolicy](),
+		responses: newTable[domain.CompromiseResponse](),
+	}
+}
+
+// GetPolicy returns the organization's policy, or nil if none is configured
+func (r *CompromiseResponseRepository) GetPolicy(orgID uuid.UUID) (*domain.CompromiseResponsePolicy, error) {
+	policy, ok := r.policies.get(orgID)
+	if !ok {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+func (r *CompromiseResponseRepository) UpsertPolicy(policy *domain.CompromiseResponsePolicy) error {
+	now := time.Now().UTC()
+	if existing, ok := r.policies.get(policy.OrganizationID); ok {
+		policy.ID = existing.ID
+		policy.CreatedAt = existing.CreatedAt
+	} else {
+		policy.ID = newID(policy.ID)
+		policy.CreatedAt = now
+	}
+	policy.UpdatedAt = now
+	r.policies.put(policy.OrganizationID, *policy)
+	return nil
+}
+
+func (r *CompromiseResponseRepository) CreateResponse(response *domain.CompromiseResponse) error {
+	response.ID = newID(response.ID)
+	if response.CreatedAt.IsZero() {
+		response.CreatedAt = time.Now().UTC()
+	}
+	r.responses.put(response.ID, *response)
+	return nil
+}
+
+func (r *CompromiseResponseRepository) GetResponsesByAgent(agentID uuid.UUID) ([]*domain.CompromiseResponse, error) {
+	return r.responses.find(func(resp *domain.CompromiseResponse) bool {
+		return resp.AgentID == agentID
+	}), nil
+}
+
+// TrustBoundaryRepository is an in-memory domain.TrustBoundaryRepository
+type TrustBoundaryRepository struct {
+	policies *table[domain.TrustBoundaryPolicy]     // keyed by organization
+	states   *table[domain.AgentTrustBoundaryState] // keyed by agent
+}
+
+// NewTrustBoundaryRepository creates an empty in-memory trust boundary repository
+func NewTrustBoundaryRepository() *TrustBoundaryRepository {
+	return &TrustBoundaryRepository{
+		policies: newTable[domain.TrustBoundaryPolicy](),
+		states:   newTable[domain.AgentTrustBoundaryState](),
+	}
+}
+
+// GetPolicy returns the organization's policy, or nil if none is configured
+func (r *TrustBoundaryRepository) GetPolicy(orgID uuid.UUID) (*domain.TrustBoundaryPolicy, error) {
+	policy, ok := r.policies.get(orgID)
+	if !ok {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+func (r *TrustBoundaryRepository) UpsertPolicy(policy *domain.TrustBoundaryPolicy) error {
+	now := time.Now().UTC()
+	if existing, ok := r.policies.get(policy.OrganizationID); ok {
+		policy.ID = existing.ID
+		policy.CreatedAt = existing.CreatedAt
+	} else {
+		policy.ID = newID(policy.ID)
+		policy.CreatedAt = now
+	}
+	policy.UpdatedAt = now
+	r.policies.put(policy.OrganizationID, *policy)
+	return nil
+}
+
+func (r *TrustBoundaryRepository) GetEnabledPolicies() ([]*domain.TrustBoundaryPolicy, error) {
+	return r.policies.find(func(p *domain.TrustBoundaryPolicy) bool {
+		return p.Enabled
+	}), nil
+}
+
+// GetState returns nil (and no error) for an agent that was never evaluated
+func (r *TrustBoundaryRepository) GetState(agentID uuid.UUID) (*domain.AgentTrustBoundaryState, error) {
+	state, ok := r.states.get(agentID)
+	if !ok {
+		return nil, nil
+	}
+	return state, nil
+}
+
+func (r *TrustBoundaryRepository) GetStatesByOrganization(orgID uuid.UUID) ([]*domain.AgentTrustBoundaryState, error) {
+	return r.states.find(func(s *domain.AgentTrustBoundaryState) bool {
+		return s.OrganizationID == orgID
+	}), nil
+}
+
+func (r *TrustBoundaryRepository) UpsertState(state *domain.AgentTrustBoundaryState) error {
+	state.UpdatedAt = time.Now().UTC()
+	r.states.put(state.AgentID, *state)
+	return nil
+}
+
+// PolicyDecisionRepository is an in-memory domain.PolicyDecisionRepository
+type PolicyDecisionRepository struct {
+	pdps      *table[domain.ExternalPolicyDecisionPoint] // keyed by organization
+	decisions *table[domain.PolicyDecisionRecord]
+}
+
+// NewPolicyDecisionRepository creates an empty in-memory policy decision repository
+func NewPolicyDecisionRepository() *PolicyDecisionRepository {
+	return &PolicyDecisionRepository{
+		pdps:      newTable[domain.ExternalPolicyDecisionPoint](),
+		decisions: newTable[domain.PolicyDecisionRecord](),
+	}
+}
+
+// GetPDPByOrganization returns nil (and no error) when the organization has no PDP configured
+func (r *PolicyDecisionRepository) GetPDPByOrganization(orgID uuid.UUID) (*domain.ExternalPolicyDecisionPoint, error) {
+	pdp, ok := r.pdps.get(orgID)
+	if !ok {
+		return nil, nil
+	}
+	pdp.HasAuthToken = pdp.AuthToken != ""
+	return pdp, nil
+}
+
+func (r *PolicyDecisionRepository) UpsertPDP(pdp *domain.ExternalPolicyDecisionPoint) error {
+	now := time.Now().UTC()
+	if existing, ok := r.pdps.get(pdp.OrganizationID); ok {
+		pdp.ID = existing.ID
+		pdp.CreatedAt = existing.CreatedAt
+	} else {
+		pdp.ID = newID(pdp.ID)
+		pdp.CreatedAt = now
+	}
+	pdp.UpdatedAt = now
+	r.pdps.put(pdp.OrganizationID, *pdp)
+	return nil
+}
+
+func (r *PolicyDecisionRepository) DeletePDP(orgID uuid.UUID) error {
+	r.pdps.remove(orgID)
+	return nil
+}
+
+func (r *PolicyDecisionRepository) CreateDecision(decision *domain.PolicyDecisionRecord) error {
+	decision.ID = newID(decision.ID)
+	if decision.CreatedAt.IsZero() {
+		decision.CreatedAt = time.Now().UTC()
+	}
+	r.decisions.put(decision.ID, *decision)
+	return nil
+}
+
+func (r *PolicyDecisionRepository) GetDecisionsByOrganization(orgID uuid.UUID, agentID *uuid.UUID, limit, offset int) ([]*domain.PolicyDecisionRecord, int, error) {
+	decisions := r.decisions.find(func(d *domain.PolicyDecisionRecord) bool {
+		return d.OrganizationID == orgID && (agentID == nil || d.AgentID == *agentID)
+	})
+	return paginate(decisions, limit, offset), len(decisions), nil
+}
+
+// AlertSuppressionRepository is an in-memory domain.AlertSuppressionRepository. Repeated
+// fingerprints are counted in the alert repository, like the SQL repository's query.
+type AlertSuppressionRepository struct {
+	rules  *table[domain.AlertSuppressionRule]
+	hits   *table[domain.AlertSuppressionHit]
+	alerts *AlertRepository
+}
+
+// NewAlertSuppressionRepository creates an empty suppression repository counting alerts in alerts
+func NewAlertSuppressionRepository(alerts *AlertRepository) *AlertSuppressionRepository {
+	return &AlertSuppressionRepository{
+		rules:  newTable[domain.AlertSuppressionRule](),
+		hits:   newTable[domain.AlertSuppressionHit](),
+		alerts: alerts,
+	}
+}
+
+func (r *AlertSuppressionRepository) Create(rule *domain.AlertSuppressionRule) error {
+	now := time.Now().UTC()
+	rule.ID = newID(rule.ID)
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	r.rules.put(rule.ID, *rule)
+	return nil
+}
+
+func (r *AlertSuppressionRepository) GetByID(id uuid.UUID) (*domain.AlertSuppressionRule, error) {
+	rule, ok := r.rules.get(id)
+	if !ok {
+		return nil, fmt.Errorf("alert suppression rule not found")
+	}
+	return rule, nil
+}
+
+func (r *AlertSuppressionRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.AlertSuppressionRule, error) {
+	return r.rules.find(func(rule *domain.AlertSuppressionRule) bool {
+		return rule.OrganizationID == orgID
+	}), nil
+}
+
+func (r *AlertSuppressionRepository) GetActiveByOrganization(orgID uuid.UUID, at time.Time) ([]*domain.AlertSuppressionRule, error) {
+	return oldestFirst(r.rules.find(func(rule *domain.AlertSuppressionRule) bool {
+		return rule.OrganizationID == orgID && rule.IsActive(at)
+	})), nil
+}
+
+// Update replaces the rule's configuration and keeps its hit counter, like the SQL repository
+func (r *AlertSuppressionRepository) Update(rule *domain.AlertSuppressionRule) error {
+	existing, ok := r.rules.get(rule.ID)
+	if !ok {
+		return fmt.Errorf("alert suppression rule not found")
+	}
+	rule.HitCount = existing.HitCount
+	rule.LastHitAt = existing.LastHitAt
+	rule.UpdatedAt = time.Now().UTC()
+	r.rules.replace(rule.ID, *rule)
+	return nil
+}
+
+func (r *AlertSuppressionRepository) Delete(id uuid.UUID) error {
+	r.rules.remove(id)
+	r.hits.removeWhere(func(h *domain.AlertSuppressionHit) bool { return h.RuleID == id })
+	return nil
+}
+
+func (r *AlertSuppressionRepository) RecordHit(hit *domain.AlertSuppressionHit) error {
+	hit.ID = newID(hit.ID)
+	if hit.CreatedAt.IsZero() {
+		hit.CreatedAt = time.Now().UTC()
+	}
+	r.hits.put(hit.ID, *hit)
+	r.rules.update(hit.RuleID, func(rule *domain.AlertSuppressionRule) {
+		rule.HitCount++
+		hitAt := hit.CreatedAt
+		rule.LastHitAt = &hitAt
+	})
+	return nil
+}
+
+func (r *AlertSuppressionRepository) GetHits(ruleID uuid.UUID, limit, offset int) ([]*domain.AlertSuppressionHit, error) {
+	hits := r.hits.find(func(h *domain.AlertSuppressionHit) bool { return h.RuleID == ruleID })
+	return paginate(hits, limit, offset), nil
+}
+
+func (r *AlertSuppressionRepository) CountMatchingAlerts(alert *domain.Alert, since time.Time) (int, error) {
+	return len(r.alerts.alerts.find(func(a *domain.Alert) bool {
+		return a.OrganizationID == alert.OrganizationID && a.AlertType == alert.AlertType &&
+			a.ResourceType == alert.ResourceType && a.ResourceID == alert.ResourceID &&
+			a.Title == alert.Title && !a.CreatedAt.Before(since)
+	})), nil
+}
+
+// searchSortKey holds the fields threat and anomaly searches can sort on
+type searchSortKey struct {
+	createdAt  time.Time
+	severity   domain.AlertSeverity
+	confidence float64
+	title      string
+}
+
+// severityRank orders severities like the SQL repository's severityRankSQL
+var severityRank = map[domain.AlertSeverity]int{
+	domain.AlertSeverityCritical: 4,
+	domain.AlertSeverityHigh:     3,
+	domain.AlertSeverityWarning:  2,
+	"medium":                     2,
+}
+
+// sortSearchResults sorts by created_at, severity, confidence or title, newest first by default
+func sortSearchResults[T any](rows []*T, sortBy, sortOrder string, key func(*T) searchSortKey) {
+	ascending := strings.EqualFold(sortOrder, "asc")
+	less := func(a, b searchSortKey) bool {
+		switch strings.ToLower(sortBy) {
+		case "severity":
+			return severityRank[a.severity] < severityRank[b.severity]
+		case "confidence":
+			return a.confidence < b.confidence
+		case "title":
+			return a.title < b.title
+		default:
+			return a.createdAt.Before(b.createdAt)
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := key(rows[i]), key(rows[j])
+		if ascending {
+			return less(a, b)
+		}
+		return less(b, a)
+	})
+}
+
+// SharedKeyRepository is an in-memory domain.SharedKeyRepository over the agents of an AgentRepository
+type SharedKeyRepository struct {
+	agents    *AgentRepository
+	allowlist *table[domain.SharedKeyAllowlistEntry]
+}
+
+// NewSharedKeyRepository creates an in-memory shared key repository with an empty allowlist
+func NewSharedKeyRepository(agents *AgentRepository) *SharedKeyRepository {
+	return &SharedKeyRepository{agents: agents, allowlist: newTable[domain.SharedKeyAllowlistEntry]()}
+}
+
+func (r *SharedKeyRepository) FindSharedKeys() ([]*domain.SharedKey, error) {
+	byKey := make(map[string][]domain.SharedKeyHolder)
+	var keys []string
+	for _, agent := range r.holders(func(a *domain.Agent) bool { return a.PublicKey != nil && *a.PublicKey != "" }) {
+		if _, ok := byKey[*agent.PublicKey]; !ok {
+			keys = append(keys, *agent.PublicKey)
+		}
+		byKey[*agent.PublicKey] = append(byKey[*agent.PublicKey], sharedKeyHolder(agent))
+	}
+	sort.Strings(keys)
+
+	var sharedKeys []*domain.SharedKey
+	for _, key := range keys {
+		if len(byKey[key]) > 1 {
+			sharedKeys = append(sharedKeys, &domain.SharedKey{Fingerprint: domain.PublicKeyFingerprint(key), Holders: byKey[key]})
+		}
+	}
+	return sharedKeys, nil
+}
+
+func (r *SharedKeyRepository) FindPublicKeyHolders(publicKey string) ([]domain.SharedKeyHolder, error) {
+	var holders []domain.SharedKeyHolder
+	for _, agent := range r.holders(func(a *domain.Agent) bool { return a.PublicKey != nil && *a.PublicKey == publicKey }) {
+		holders = append(holders, sharedKeyHolder(agent))
+	}
+	return holders, nil
+}
+
+// holders returns the matching agents, oldest first
+func (r *SharedKeyRepository) holders(match func(*domain.Agent) bool) []*domain.Agent {
+	agents := r.agents.agents.find(match)
+	slices.Reverse(agents)
+	return agents
+}
+
+func sharedKeyHolder(agent *domain.Agent) domain.SharedKeyHolder {
+	return domain.SharedKeyHolder{AgentID: agent.ID, AgentName: agent.Name, OrganizationID: agent.OrganizationID}
+}
+
+func (r *SharedKeyRepository) CreateAllowlistEntry(entry *domain.SharedKeyAllowlistEntry) error {
+	if _, exists := r.allowlist.first(func(e *domain.SharedKeyAllowlistEntry) bool {
+		return e.OrganizationID == entry.OrganizationID && e.Fingerprint == entry.Fingerprint
+	}); exists {
+		return fmt.Errorf("duplicate key value violates unique constraint")
+	}
+	r.allowlist.put(entry.ID, *entry)
+	return nil
+}
+
+func (r *SharedKeyRepository) ListAllowlist(orgID uuid.UUID) ([]*domain.SharedKeyAllowlistEntry, error) {
+	return r.allowlist.find(func(e *domain.SharedKeyAllowlistEntry) bool { return e.OrganizationID == orgID }), nil
+}
+
+func (r *SharedKeyRepository) DeleteAllowlistEntry(orgID, id uuid.UUID) error {
+	entry, ok := r.allowlist.get(id)
+	if !ok || entry.OrganizationID != orgID {
+		return domain.ErrSharedKeyAllowlistEntryNotFound
+	}
+	r.allowlist.remove(id)
+	return nil
+}
+
+// IncidentRepository is an in-memory domain.IncidentRepository
+type IncidentRepository struct {
+	comments *table[domain.IncidentComment]
+	links    *table[domain.IncidentLink]
+	timeline *table[domain.IncidentTimelineEvent]
+}
+
+// NewIncidentRepository creates an empty in-memory incident repository
+func NewIncidentRepository() *IncidentRepository {
+	return &IncidentRepository{
+		comments: newTable[domain.IncidentComment](),
+		links:    newTable[domain.IncidentLink](),
+		timeline: newTable[domain.IncidentTimelineEvent](),
+	}
+}
+
+// incidentLinkKey identifies a link by its incident and resource, like the SQL primary key
+func incidentLinkKey(incidentID uuid.UUID, resourceType domain.IncidentLinkType, resourceID uuid.UUID) uuid.UUID {
+	return uuid.NewSHA1(incidentID, []byte(string(resourceType)+":"+resourceID.String()))
+}
+
+func (r *IncidentRepository) CreateComment(comment *domain.IncidentComment) error {
+	comment.ID = newID(comment.ID)
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = time.Now().UTC()
+	}
+	r.comments.put(comment.ID, *comment)
+	return nil
+}
+
+func (r *IncidentRepository) ListComments(incidentID uuid.UUID) ([]*domain.IncidentComment, error) {
+	comments := r.comments.find(func(c *domain.IncidentComment) bool { return c.IncidentID == incidentID })
+	slices.Reverse(comments)
+	return comments, nil
+}
+
+func (r *IncidentRepository) CreateLink(link *domain.IncidentLink) error {
+	key := incidentLinkKey(link.IncidentID, link.ResourceType, link.ResourceID)
+	if _, exists := r.links.get(key); exists {
+		return domain.ErrIncidentLinkExists
+	}
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now().UTC()
+	}
+	r.links.put(key, *link)
+	return nil
+}
+
+func (r *IncidentRepository) DeleteLink(incidentID uuid.UUID, resourceType domain.IncidentLinkType, resourceID uuid.UUID) error {
+	if !r.links.remove(incidentLinkKey(incidentID, resourceType, resourceID)) {
+		return domain.ErrIncidentLinkNotFound
+	}
+	return nil
+}
+
+func (r *IncidentRepository) ListLinks(incidentID uuid.UUID) ([]*domain.IncidentLink, error) {
+	links := r.links.find(func(l *domain.IncidentLink) bool { return l.IncidentID == incidentID })
+	slices.Reverse(links)
+	return links, nil
+}
+
+func (r *IncidentRepository) AppendTimeline(event *domain.IncidentTimelineEvent) error {
+	event.ID = newID(event.ID)
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	r.timeline.put(event.ID, *event)
+	return nil
+}
+
+func (r *IncidentRepository) ListTimeline(incidentID uuid.UUID) ([]*domain.IncidentTimelineEvent, error) {
+	events := r.timeline.find(func(e *domain.IncidentTimelineEvent) bool { return e.IncidentID == incidentID })
+	slices.Reverse(events)
+	return events, nil
+}
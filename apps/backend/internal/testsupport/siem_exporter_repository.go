@@ -0,0 +1,98 @@
+package testsupport
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.SIEMExporterRepository = (*SIEMExporterRepository)(nil)
+
+// SIEMExporterRepository is an in-memory domain.SIEMExporterRepository
+type SIEMExporterRepository struct {
+	exporters *table[domain.SIEMExporter]
+}
+
+// NewSIEMExporterRepository creates an empty in-memory SIEM exporter repository
+func NewSIEMExporterRepository() *SIEMExporterRepository {
+	return &SIEMExporterRepository{exporters: newTable[domain.SIEMExporter]()}
+}
+
+func (r *SIEMExporterRepository) Create(exporter *domain.SIEMExporter) error {
+	now := time.Now()
+	exporter.ID = newID(exporter.ID)
+	exporter.CreatedAt = now
+	exporter.UpdatedAt = now
+	r.exporters.put(exporter.ID, cloneSIEMExporter(*exporter))
+	return nil
+}
+
+func (r *SIEMExporterRepository) GetByID(id uuid.UUID) (*domain.SIEMExporter, error) {
+	exporter, ok := r.exporters.get(id)
+	if !ok {
+		return nil, domain.ErrSIEMExporterNotFound
+	}
+	*exporter = cloneSIEMExporter(*exporter)
+	return exporter, nil
+}
+
+// GetByOrganization lists the organization's exporters oldest first, like the SQL repository
+func (r *SIEMExporterRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.SIEMExporter, error) {
+	return r.list(func(e *domain.SIEMExporter) bool { return e.OrganizationID == orgID }), nil
+}
+
+func (r *SIEMExporterRepository) GetActiveByOrganization(orgID uuid.UUID) ([]*domain.SIEMExporter, error) {
+	return r.list(func(e *domain.SIEMExporter) bool { return e.OrganizationID == orgID && e.IsActive }), nil
+}
+
+// Update replaces the exporter's settings and keeps its delivery counters, like the SQL repository
+func (r *SIEMExporterRepository) Update(exporter *domain.SIEMExporter) error {
+	exporter.UpdatedAt = time.Now()
+	r.exporters.update(exporter.ID, func(stored *domain.SIEMExporter) {
+		updated := cloneSIEMExporter(*exporter)
+		updated.DeliveredCount = stored.DeliveredCount
+		updated.DroppedCount = stored.DroppedCount
+		updated.LastDeliveredAt = stored.LastDeliveredAt
+		updated.LastError = stored.LastError
+		updated.LastErrorAt = stored.LastErrorAt
+		*stored = updated
+	})
+	return nil
+}
+
+func (r *SIEMExporterRepository) Delete(id uuid.UUID) error {
+	r.exporters.remove(id)
+	return nil
+}
+
+func (r *SIEMExporterRepository) RecordDelivery(id uuid.UUID, stats domain.SIEMDeliveryStats) error {
+	r.exporters.update(id, func(e *domain.SIEMExporter) {
+		e.DeliveredCount += stats.Delivered
+		e.DroppedCount += stats.Dropped
+		if stats.DeliveredAt != nil {
+			e.LastDeliveredAt = stats.DeliveredAt
+		}
+		switch {
+		case stats.Error != nil:
+			e.LastError, e.LastErrorAt = stats.Error, stats.ErrorAt
+		case stats.DeliveredAt != nil:
+			e.LastError, e.LastErrorAt = nil, nil
+		}
+	})
+	return nil
+}
+
+func (r *SIEMExporterRepository) list(match func(*domain.SIEMExporter) bool) []*domain.SIEMExporter {
+	exporters := oldestFirst(r.exporters.find(match))
+	for _, exporter := range exporters {
+		*exporter = cloneSIEMExporter(*exporter)
+	}
+	return exporters
+}
+
+func cloneSIEMExporter(exporter domain.SIEMExporter) domain.SIEMExporter {
+	exporter.EventTypes = slices.Clone(exporter.EventTypes)
+	return exporter
+}
@@ -0,0 +1,216 @@
+// Package testsupport provides in-memory implementations of the domain repository
+// interfaces and factories for domain entities, for service-level tests that need
+// working persistence without a database.
+//
+// The repositories mirror the behaviour of the SQL repositories closely enough for
+// services to run against them: Create assigns an ID (unless one is already set) and
+// timestamps, lookups of missing records return the same "not found" errors, and
+// listing methods return records newest first. Stored records are copied on write and
+// on read, so tests cannot mutate repository state through a returned pointer.
+package testsupport
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// table is a concurrency-safe, insertion-ordered set of records keyed by ID
+type table[T any] struct {
+	mu    sync.RWMutex
+	seq   uint64
+	order map[uuid.UUID]uint64
+	rows  map[uuid.UUID]T
+}
+
+func newTable[T any]() *table[T] {
+	return &table[T]{
+		order: make(map[uuid.UUID]uint64),
+		rows:  make(map[uuid.UUID]T),
+	}
+}
+
+// put stores a copy of the record, replacing any existing record with the same ID
+func (t *table[T]) put(id uuid.UUID, row T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.rows[id]; !ok {
+		t.seq++
+		t.order[id] = t.seq
+	}
+	t.rows[id] = row
+}
+
+// replace overwrites an existing record; it reports false if there is none
+func (t *table[T]) replace(id uuid.UUID, row T) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.rows[id]; !ok {
+		return false
+	}
+	t.rows[id] = row
+	return true
+}
+
+// get returns a copy of the record
+func (t *table[T]) get(id uuid.UUID) (*T, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	row, ok := t.rows[id]
+	if !ok {
+		return nil, false
+	}
+	return &row, true
+}
+
+// remove deletes the record; it reports false if there was none
+func (t *table[T]) remove(id uuid.UUID) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.rows[id]; !ok {
+		return false
+	}
+	delete(t.rows, id)
+	delete(t.order, id)
+	return true
+}
+
+// update applies fn to the stored record in place; it reports false if there is none
+func (t *table[T]) update(id uuid.UUID, fn func(*T)) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	row, ok := t.rows[id]
+	if !ok {
+		return false
+	}
+	fn(&row)
+	t.rows[id] = row
+	return true
+}
+
+// updateWhere applies fn to every stored record matching match and returns how many were updated
+func (t *table[T]) updateWhere(match func(*T) bool, fn func(*T)) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	updated := 0
+	for _, id := range t.sortedIDs() {
+		row := t.rows[id]
+		if !match(&row) {
+			continue
+		}
+		fn(&row)
+		t.rows[id] = row
+		updated++
+	}
+	return updated
+}
+
+// removeWhere deletes every record matching match and returns how many were deleted
+func (t *table[T]) removeWhere(match func(*T) bool) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	removed := 0
+	for id, row := range t.rows {
+		if match(&row) {
+			delete(t.rows, id)
+			delete(t.order, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// find returns copies of the records matching match, newest first. A nil match selects every record.
+func (t *table[T]) find(match func(*T) bool) []*T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := make([]*T, 0)
+	for _, id := range t.sortedIDs() {
+		row := t.rows[id]
+		if match == nil || match(&row) {
+			result = append(result, &row)
+		}
+	}
+	return result
+}
+
+// getMany returns copies of the records with the given IDs, newest first; unknown IDs are skipped
+func (t *table[T]) getMany(ids []uuid.UUID) []*T {
+	wanted := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := make([]*T, 0, len(wanted))
+	for _, id := range t.sortedIDs() {
+		if wanted[id] {
+			row := t.rows[id]
+			result = append(result, &row)
+		}
+	}
+	return result
+}
+
+// first returns a copy of the newest record matching match
+func (t *table[T]) first(match func(*T) bool) (*T, bool) {
+	rows := t.find(match)
+	if len(rows) == 0 {
+		return nil, false
+	}
+	return rows[0], true
+}
+
+// sortedIDs returns the stored IDs newest first; callers must hold the lock
+func (t *table[T]) sortedIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(t.rows))
+	for id := range t.rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return t.order[ids[i]] > t.order[ids[j]]
+	})
+	return ids
+}
+
+// paginate applies LIMIT/OFFSET semantics; a non-positive limit returns every remaining record
+func paginate[T any](rows []*T, limit, offset int) []*T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(rows) {
+		return make([]*T, 0)
+	}
+	rows = rows[offset:]
+	if limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// newID returns id, or a fresh ID when id is unset
+func newID(id uuid.UUID) uuid.UUID {
+	if id == uuid.Nil {
+		return uuid.New()
+	}
+	return id
+}
+
+// oldestFirst reverses a newest-first listing in place
+func oldestFirst[T any](rows []*T) []*T {
+	for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+		rows[i], rows[j] = rows[j], rows[i]
+	}
+	return rows
+}
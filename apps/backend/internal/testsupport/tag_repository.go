@@ -0,0 +1,214 @@
+package testsupport
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.TagRepository = (*TagRepository)(nil)
+
+// TagRepository is an in-memory domain.TagRepository
+type TagRepository struct {
+	tags *table[domain.Tag]
+
+	mu         sync.RWMutex
+	agentTags  map[uuid.UUID]map[uuid.UUID]bool // agent ID -> tag IDs
+	serverTags map[uuid.UUID]map[uuid.UUID]bool // MCP server ID -> tag IDs
+}
+
+// NewTagRepository creates an empty in-memory tag repository
+func NewTagRepository() *TagRepository {
+	return &TagRepository{
+		tags:       newTable[domain.Tag](),
+		agentTags:  make(map[uuid.UUID]map[uuid.UUID]bool),
+		serverTags: make(map[uuid.UUID]map[uuid.UUID]bool),
+	}
+}
+
+func (r *TagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	tag.ID = newID(tag.ID)
+	tag.CreatedAt = time.Now()
+	r.tags.put(tag.ID, *tag)
+	return nil
+}
+
+func (r *TagRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tag, error) {
+	tag, ok := r.tags.get(id)
+	if !ok {
+		return nil, fmt.Errorf("tag not found")
+	}
+	return tag, nil
+}
+
+func (r *TagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	if !r.tags.replace(tag.ID, *tag) {
+		return fmt.Errorf("tag not found")
+	}
+	return nil
+}
+
+func (r *TagRepository) List(ctx context.Context, organizationID uuid.UUID, category *domain.TagCategory) ([]*domain.Tag, error) {
+	return sortTags(r.tags.find(func(t *domain.Tag) bool {
+		return t.OrganizationID == organizationID && (category == nil || t.Category == *category)
+	})), nil
+}
+
+// Delete removes the tag and detaches it from every agent and MCP server
+func (r *TagRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.tags.remove(id)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, tagIDs := range r.agentTags {
+		delete(tagIDs, id)
+	}
+	for _, tagIDs := range r.serverTags {
+		delete(tagIDs, id)
+	}
+	return nil
+}
+
+// GetPopularTags orders tags by how many agents and MCP servers use them
+func (r *TagRepository) GetPopularTags(ctx context.Context, organizationID uuid.UUID, limit int) ([]*domain.Tag, error) {
+	tags := r.tags.find(func(t *domain.Tag) bool {
+		return t.OrganizationID == organizationID
+	})
+
+	r.mu.RLock()
+	usage := make(map[uuid.UUID]int)
+	for _, links := range []map[uuid.UUID]map[uuid.UUID]bool{r.agentTags, r.serverTags} {
+		for _, tagIDs := range links {
+			for tagID := range tagIDs {
+				usage[tagID]++
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return usage[tags[i].ID] > usage[tags[j].ID]
+	})
+	return paginate(tags, limit, 0), nil
+}
+
+func (r *TagRepository) SearchTags(ctx context.Context, organizationID uuid.UUID, query string, category *domain.TagCategory) ([]*domain.Tag, error) {
+	query = strings.ToLower(query)
+	tags := r.tags.find(func(t *domain.Tag) bool {
+		if t.OrganizationID != organizationID || (category != nil && t.Category != *category) {
+			return false
+		}
+		return strings.Contains(strings.ToLower(t.Key), query) ||
+			strings.Contains(strings.ToLower(t.Value), query) ||
+			strings.Contains(strings.ToLower(t.Description), query)
+	})
+	sort.SliceStable(tags, func(i, j int) bool {
+		if tags[i].Key != tags[j].Key {
+			return tags[i].Key < tags[j].Key
+		}
+		return tags[i].Value < tags[j].Value
+	})
+	return tags, nil
+}
+
+func (r *TagRepository) AddTagsToAgent(ctx context.Context, agentID uuid.UUID, tagIDs []uuid.UUID) error {
+	r.link(r.agentTags, agentID, tagIDs)
+	return nil
+}
+
+func (r *TagRepository) RemoveTagFromAgent(ctx context.Context, agentID uuid.UUID, tagID uuid.UUID) error {
+	r.unlink(r.agentTags, agentID, tagID)
+	return nil
+}
+
+func (r *TagRepository) GetAgentTags(ctx context.Context, agentID uuid.UUID) ([]*domain.Tag, error) {
+	return r.linked(r.agentTags, agentID), nil
+}
+
+func (r *TagRepository) GetTagsForAgents(ctx context.Context, agentIDs []uuid.UUID) (map[uuid.UUID][]*domain.Tag, error) {
+	tags := make(map[uuid.UUID][]*domain.Tag, len(agentIDs))
+	for _, agentID := range agentIDs {
+		tags[agentID] = r.linked(r.agentTags, agentID)
+	}
+	return tags, nil
+}
+
+func (r *TagRepository) AddTagsToMCPServer(ctx context.Context, mcpServerID uuid.UUID, tagIDs []uuid.UUID) error {
+	r.link(r.serverTags, mcpServerID, tagIDs)
+	return nil
+}
+
+func (r *TagRepository) RemoveTagFromMCPServer(ctx context.Context, mcpServerID uuid.UUID, tagID uuid.UUID) error {
+	r.unlink(r.serverTags, mcpServerID, tagID)
+	return nil
+}
+
+func (r *TagRepository) GetMCPServerTags(ctx context.Context, mcpServerID uuid.UUID) ([]*domain.Tag, error) {
+	return r.linked(r.serverTags, mcpServerID), nil
+}
+
+func (r *TagRepository) GetTagsForMCPServers(ctx context.Context, mcpServerIDs []uuid.UUID) (map[uuid.UUID][]*domain.Tag, error) {
+	tags := make(map[uuid.UUID][]*domain.Tag, len(mcpServerIDs))
+	for _, mcpServerID := range mcpServerIDs {
+		tags[mcpServerID] = r.linked(r.serverTags, mcpServerID)
+	}
+	return tags, nil
+}
+
+func (r *TagRepository) link(links map[uuid.UUID]map[uuid.UUID]bool, ownerID uuid.UUID, tagIDs []uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if links[ownerID] == nil {
+		links[ownerID] = make(map[uuid.UUID]bool)
+	}
+	for _, tagID := range tagIDs {
+		links[ownerID][tagID] = true
+	}
+}
+
+func (r *TagRepository) unlink(links map[uuid.UUID]map[uuid.UUID]bool, ownerID, tagID uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(links[ownerID], tagID)
+}
+
+// hasLink reports whether the owner carries the tag
+func (r *TagRepository) hasLink(links map[uuid.UUID]map[uuid.UUID]bool, ownerID, tagID uuid.UUID) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return links[ownerID][tagID]
+}
+
+func (r *TagRepository) linked(links map[uuid.UUID]map[uuid.UUID]bool, ownerID uuid.UUID) []*domain.Tag {
+	r.mu.RLock()
+	tagIDs := links[ownerID]
+	r.mu.RUnlock()
+
+	return sortTags(r.tags.find(func(t *domain.Tag) bool {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		return tagIDs[t.ID]
+	}))
+}
+
+// sortTags orders tags by category, key and value like the SQL listings
+func sortTags(tags []*domain.Tag) []*domain.Tag {
+	sort.SliceStable(tags, func(i, j int) bool {
+		a, b := tags[i], tags[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Value < b.Value
+	})
+	return tags
+}
@@ -0,0 +1,136 @@
+package testsupport
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.TicketConnectorRepository = (*TicketConnectorRepository)(nil)
+
+// TicketConnectorRepository is an in-memory domain.TicketConnectorRepository
+type TicketConnectorRepository struct {
+	connectors *table[domain.TicketConnector]
+	links      *table[domain.TicketLink]
+}
+
+// NewTicketConnectorRepository creates an empty in-memory ticket connector repository
+func NewTicketConnectorRepository() *TicketConnectorRepository {
+	return &TicketConnectorRepository{
+		connectors: newTable[domain.TicketConnector](),
+		links:      newTable[domain.TicketLink](),
+	}
+}
+
+func (r *TicketConnectorRepository) CreateConnector(connector *domain.TicketConnector) error {
+	now := time.Now()
+	connector.ID = newID(connector.ID)
+	connector.CreatedAt = now
+	connector.UpdatedAt = now
+	r.connectors.put(connector.ID, cloneTicketConnector(connector))
+	return nil
+}
+
+func (r *TicketConnectorRepository) GetConnector(id uuid.UUID) (*domain.TicketConnector, error) {
+	connector, ok := r.connectors.get(id)
+	if !ok {
+		return nil, domain.ErrTicketConnectorNotFound
+	}
+	return connector, nil
+}
+
+// GetConnectorsByOrganization lists the organization's connectors oldest first, like the SQL repository
+func (r *TicketConnectorRepository) GetConnectorsByOrganization(orgID uuid.UUID) ([]*domain.TicketConnector, error) {
+	return oldestFirst(r.connectors.find(func(c *domain.TicketConnector) bool {
+		return c.OrganizationID == orgID
+	})), nil
+}
+
+func (r *TicketConnectorRepository) UpdateConnector(connector *domain.TicketConnector) error {
+	connector.UpdatedAt = time.Now()
+	if !r.connectors.replace(connector.ID, cloneTicketConnector(connector)) {
+		return domain.ErrTicketConnectorNotFound
+	}
+	return nil
+}
+
+// DeleteConnector deletes the connector and, like the SQL foreign key, its links
+func (r *TicketConnectorRepository) DeleteConnector(id uuid.UUID) error {
+	r.connectors.remove(id)
+	r.links.removeWhere(func(l *domain.TicketLink) bool { return l.ConnectorID == id })
+	return nil
+}
+
+func (r *TicketConnectorRepository) CreateLink(link *domain.TicketLink) error {
+	if _, exists := r.links.first(func(l *domain.TicketLink) bool {
+		return l.ConnectorID == link.ConnectorID && l.TicketKey == link.TicketKey
+	}); exists {
+		return fmt.Errorf("ticket %s is already linked", link.TicketKey)
+	}
+	link.ID = newID(link.ID)
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now()
+	}
+	r.links.put(link.ID, cloneTicketLink(link))
+	return nil
+}
+
+func (r *TicketConnectorRepository) GetLinkByTicket(connectorID uuid.UUID, ticketKey string) (*domain.TicketLink, error) {
+	link, ok := r.links.first(func(l *domain.TicketLink) bool {
+		return l.ConnectorID == connectorID && l.TicketKey == ticketKey
+	})
+	if !ok {
+		return nil, domain.ErrTicketLinkNotFound
+	}
+	return link, nil
+}
+
+func (r *TicketConnectorRepository) GetLinksByResource(resourceType string, resourceID uuid.UUID) ([]*domain.TicketLink, error) {
+	return oldestFirst(r.links.find(func(l *domain.TicketLink) bool {
+		return l.ResourceType == resourceType && l.ResourceID == resourceID
+	})), nil
+}
+
+// GetOpenLinks lists open links never synced first, then by their last sync, like the SQL repository
+func (r *TicketConnectorRepository) GetOpenLinks(limit int) ([]*domain.TicketLink, error) {
+	links := oldestFirst(r.links.find(func(l *domain.TicketLink) bool { return !l.Closed }))
+	slices.SortStableFunc(links, func(a, b *domain.TicketLink) int {
+		switch {
+		case a.LastSyncedAt == nil && b.LastSyncedAt == nil:
+			return 0
+		case a.LastSyncedAt == nil:
+			return -1
+		case b.LastSyncedAt == nil:
+			return 1
+		}
+		return a.LastSyncedAt.Compare(*b.LastSyncedAt)
+	})
+	return paginate(links, limit, 0), nil
+}
+
+func (r *TicketConnectorRepository) UpdateLink(link *domain.TicketLink) error {
+	r.links.replace(link.ID, cloneTicketLink(link))
+	return nil
+}
+
+// cloneTicketConnector copies the connector so stored slices and maps are not shared with the caller
+func cloneTicketConnector(connector *domain.TicketConnector) domain.TicketConnector {
+	stored := *connector
+	stored.WebhookSecret = ""
+	stored.ResourceTypes = slices.Clone(connector.ResourceTypes)
+	stored.ApprovedStatuses = slices.Clone(connector.ApprovedStatuses)
+	stored.RejectedStatuses = slices.Clone(connector.RejectedStatuses)
+	stored.IncidentStatuses = maps.Clone(connector.IncidentStatuses)
+	return stored
+}
+
+// cloneTicketLink copies the link so its synced comment IDs are not shared with the caller
+func cloneTicketLink(link *domain.TicketLink) domain.TicketLink {
+	stored := *link
+	stored.SyncedCommentIDs = slices.Clone(link.SyncedCommentIDs)
+	return stored
+}
@@ -0,0 +1,124 @@
+package testsupport
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.TombstoneRepository = (*TombstoneRepository)(nil)
+
+// TombstoneRepository is an in-memory domain.TombstoneRepository. Linked records are
+// counted in the other in-memory repositories, like the SQL repository's subqueries.
+type TombstoneRepository struct {
+	tombstones         *table[domain.Tombstone]
+	apiKeys            *APIKeyRepository
+	capabilities       *CapabilityRepository
+	attestations       *MCPAttestationRepository
+	serverCapabilities *MCPServerCapabilityRepository
+	events             *VerificationEventRepository
+	alerts             *AlertRepository
+	auditLogs          *AuditLogRepository
+}
+
+// NewTombstoneRepository creates an empty tombstone repository counting links in the given repositories
+func NewTombstoneRepository(
+	apiKeys *APIKeyRepository,
+	capabilities *CapabilityRepository,
+	attestations *MCPAttestationRepository,
+	serverCapabilities *MCPServerCapabilityRepository,
+	events *VerificationEventRepository,
+	alerts *AlertRepository,
+	auditLogs *AuditLogRepository,
+) *TombstoneRepository {
+	return &TombstoneRepository{
+		tombstones:         newTable[domain.Tombstone](),
+		apiKeys:            apiKeys,
+		capabilities:       capabilities,
+		attestations:       attestations,
+		serverCapabilities: serverCapabilities,
+		events:             events,
+		alerts:             alerts,
+		auditLogs:          auditLogs,
+	}
+}
+
+func (r *TombstoneRepository) Create(tombstone *domain.Tombstone) error {
+	tombstone.ID = newID(tombstone.ID)
+	if tombstone.DeletedAt.IsZero() {
+		tombstone.DeletedAt = time.Now().UTC()
+	}
+	r.tombstones.put(tombstone.ID, *tombstone)
+	return nil
+}
+
+// GetByEntityID returns nil (and no error) when the entity has no tombstone
+func (r *TombstoneRepository) GetByEntityID(orgID, entityID uuid.UUID) (*domain.Tombstone, error) {
+	tombstone, ok := r.tombstones.first(func(t *domain.Tombstone) bool {
+		return t.OrganizationID == orgID && t.EntityID == entityID
+	})
+	if !ok {
+		return nil, nil
+	}
+	return tombstone, nil
+}
+
+func (r *TombstoneRepository) Search(filter *domain.TombstoneFilter) ([]*domain.Tombstone, int, error) {
+	queryID, queryErr := uuid.Parse(filter.Query)
+	matches := r.tombstones.find(func(t *domain.Tombstone) bool {
+		if t.OrganizationID != filter.OrganizationID {
+			return false
+		}
+		if filter.EntityType != "" && t.EntityType != filter.EntityType {
+			return false
+		}
+		if filter.DeletedBy != nil && (t.DeletedBy == nil || *t.DeletedBy != *filter.DeletedBy) {
+			return false
+		}
+		if filter.Query == "" {
+			return true
+		}
+		if queryErr == nil {
+			return t.EntityID == queryID
+		}
+		return strings.Contains(strings.ToLower(t.Name), strings.ToLower(filter.Query))
+	})
+
+	return paginate(matches, filter.Limit, filter.Offset), len(matches), nil
+}
+
+func (r *TombstoneRepository) CountLinked(entityType domain.TombstoneEntityType, entityID uuid.UUID) (map[string]int, error) {
+	alerts := len(r.alerts.alerts.find(func(a *domain.Alert) bool { return a.ResourceID == entityID }))
+
+	switch entityType {
+	case domain.TombstoneEntityAgent:
+		return map[string]int{
+			"apiKeys":        len(r.apiKeys.keys.find(func(k *domain.APIKey) bool { return k.AgentID == entityID })),
+			"capabilities":   len(r.capabilities.capabilities.find(func(c *domain.AgentCapability) bool { return c.AgentID == entityID })),
+			"attestations":   len(r.attestations.attestations.find(func(a *domain.MCPAttestation) bool { return a.AgentID != nil && *a.AgentID == entityID })),
+			"mcpConnections": len(r.attestations.connections.find(func(c *domain.AgentMCPConnection) bool { return c.AgentID == entityID })),
+			"verificationEvents": len(r.events.events.find(func(e *domain.VerificationEvent) bool {
+				return e.AgentID != nil && *e.AgentID == entityID
+			})),
+			"alerts": alerts,
+		}, nil
+	case domain.TombstoneEntityMCPServer:
+		return map[string]int{
+			"attestations":     len(r.attestations.attestations.find(func(a *domain.MCPAttestation) bool { return a.MCPServerID == entityID })),
+			"agentConnections": len(r.attestations.connections.find(func(c *domain.AgentMCPConnection) bool { return c.MCPServerID == entityID })),
+			"capabilities":     len(r.serverCapabilities.capabilities.find(func(c *domain.MCPServerCapability) bool { return c.MCPServerID == entityID })),
+			"verificationEvents": len(r.events.events.find(func(e *domain.VerificationEvent) bool {
+				return e.MCPServerID != nil && *e.MCPServerID == entityID
+			})),
+			"alerts": alerts,
+		}, nil
+	case domain.TombstoneEntityAPIKey:
+		return map[string]int{
+			"auditEntries": len(r.auditLogs.logs.find(func(l *domain.AuditLog) bool { return l.ResourceID == entityID })),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported tombstone entity type: %s", entityType)
+}
@@ -0,0 +1,84 @@
+package testsupport
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.TrustScoreTriggerRepository = (*TrustScoreTriggerRepository)(nil)
+
+// TrustScoreTriggerRepository is an in-memory domain.TrustScoreTriggerRepository
+type TrustScoreTriggerRepository struct {
+	triggers *table[domain.TrustScoreTrigger]
+	firings  *table[domain.TrustScoreTriggerFiring]
+}
+
+// NewTrustScoreTriggerRepository creates an empty in-memory trust score trigger repository
+func NewTrustScoreTriggerRepository() *TrustScoreTriggerRepository {
+	return &TrustScoreTriggerRepository{
+		triggers: newTable[domain.TrustScoreTrigger](),
+		firings:  newTable[domain.TrustScoreTriggerFiring](),
+	}
+}
+
+func (r *TrustScoreTriggerRepository) CreateTrigger(trigger *domain.TrustScoreTrigger) error {
+	now := time.Now()
+	trigger.ID = newID(trigger.ID)
+	trigger.CreatedAt = now
+	trigger.UpdatedAt = now
+	r.triggers.put(trigger.ID, cloneTrustScoreTrigger(trigger))
+	return nil
+}
+
+func (r *TrustScoreTriggerRepository) GetTrigger(id uuid.UUID) (*domain.TrustScoreTrigger, error) {
+	trigger, ok := r.triggers.get(id)
+	if !ok {
+		return nil, domain.ErrTrustScoreTriggerNotFound
+	}
+	return trigger, nil
+}
+
+// GetTriggersByOrganization lists the organization's triggers oldest first, like the SQL repository
+func (r *TrustScoreTriggerRepository) GetTriggersByOrganization(orgID uuid.UUID) ([]*domain.TrustScoreTrigger, error) {
+	return oldestFirst(r.triggers.find(func(t *domain.TrustScoreTrigger) bool {
+		return t.OrganizationID == orgID
+	})), nil
+}
+
+func (r *TrustScoreTriggerRepository) UpdateTrigger(trigger *domain.TrustScoreTrigger) error {
+	trigger.UpdatedAt = time.Now()
+	if !r.triggers.replace(trigger.ID, cloneTrustScoreTrigger(trigger)) {
+		return domain.ErrTrustScoreTriggerNotFound
+	}
+	return nil
+}
+
+func (r *TrustScoreTriggerRepository) DeleteTrigger(id uuid.UUID) error {
+	r.triggers.remove(id)
+	return nil
+}
+
+func (r *TrustScoreTriggerRepository) CreateFiring(firing *domain.TrustScoreTriggerFiring) error {
+	firing.ID = newID(firing.ID)
+	stored := *firing
+	stored.Actions = slices.Clone(firing.Actions)
+	r.firings.put(firing.ID, stored)
+	return nil
+}
+
+func (r *TrustScoreTriggerRepository) GetFiringsByOrganization(orgID uuid.UUID, triggerID *uuid.UUID, limit, offset int) ([]*domain.TrustScoreTriggerFiring, int, error) {
+	firings := r.firings.find(func(f *domain.TrustScoreTriggerFiring) bool {
+		return f.OrganizationID == orgID && (triggerID == nil || f.TriggerID == *triggerID)
+	})
+	return paginate(firings, limit, offset), len(firings), nil
+}
+
+// cloneTrustScoreTrigger copies the trigger so its stored actions are not shared with the caller
+func cloneTrustScoreTrigger(trigger *domain.TrustScoreTrigger) domain.TrustScoreTrigger {
+	stored := *trigger
+	stored.Actions = slices.Clone(trigger.Actions)
+	return stored
+}
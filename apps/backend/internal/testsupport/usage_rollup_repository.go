@@ -0,0 +1,73 @@
+package testsupport
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+var _ domain.UsageRollupRepository = (*UsageRollupRepository)(nil)
+
+// UsageRollupRepository is an in-memory domain.UsageRollupRepository
+type UsageRollupRepository struct {
+	mu      sync.Mutex
+	rollups *table[domain.UsageRollup]
+	keys    map[usageRollupKey]uuid.UUID // Row ID of each subject and day
+}
+
+type usageRollupKey struct {
+	subjectType domain.UsageSubjectType
+	subjectID   uuid.UUID
+	day         time.Time
+}
+
+// NewUsageRollupRepository creates an empty in-memory usage rollup repository
+func NewUsageRollupRepository() *UsageRollupRepository {
+	return &UsageRollupRepository{rollups: newTable[domain.UsageRollup](), keys: make(map[usageRollupKey]uuid.UUID)}
+}
+
+func (r *UsageRollupRepository) Upsert(rollups []*domain.UsageRollup) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, rollup := range rollups {
+		rollup.UpdatedAt = now
+		stored := *rollup
+		stored.MCPServers = make(map[string]int, len(rollup.MCPServers))
+		for server, count := range rollup.MCPServers {
+			stored.MCPServers[server] = count
+		}
+		k := usageRollupKey{subjectType: rollup.SubjectType, subjectID: rollup.SubjectID, day: rollup.Day.UTC()}
+		id, ok := r.keys[k]
+		if !ok {
+			id = uuid.New()
+			r.keys[k] = id
+		}
+		r.rollups.put(id, stored)
+	}
+	return nil
+}
+
+func (r *UsageRollupRepository) List(orgID uuid.UUID, subjectType domain.UsageSubjectType, subjectID *uuid.UUID, since, until time.Time) ([]*domain.UsageRollup, error) {
+	rollups := r.rollups.find(func(u *domain.UsageRollup) bool {
+		return u.OrganizationID == orgID && u.SubjectType == subjectType && (subjectID == nil || u.SubjectID == *subjectID) &&
+			!u.Day.Before(since) && u.Day.Before(until)
+	})
+	sort.SliceStable(rollups, func(i, j int) bool { return rollups[i].Day.Before(rollups[j].Day) })
+	return rollups, nil
+}
+
+func (r *UsageRollupRepository) LatestDay() (time.Time, bool, error) {
+	var latest time.Time
+	found := false
+	for _, rollup := range r.rollups.find(func(*domain.UsageRollup) bool { return true }) {
+		if !found || rollup.Day.After(latest) {
+			latest, found = rollup.Day, true
+		}
+	}
+	return latest, found, nil
+}
@@ -0,0 +1,65 @@
+-- Migration: Create notification channels, notifications and delivery queue tables
+-- Created: 2025-11-12
+-- Purpose: Queue-backed fan-out of alerts/events to email, Slack, webhook and PagerDuty
+--          with per-channel per-recipient delivery state, retries and manual resend
+
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    channel_type VARCHAR(50) NOT NULL,
+    recipients TEXT[] NOT NULL DEFAULT '{}', -- Email addresses, Slack/webhook URLs or PagerDuty routing keys
+    config JSONB NOT NULL DEFAULT '{}'::jsonb,
+    event_types TEXT[] NOT NULL DEFAULT '{}', -- Empty array matches every event
+    min_severity VARCHAR(20) NOT NULL DEFAULT 'info',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT notification_channels_name_unique_per_org UNIQUE (organization_id, name),
+    CONSTRAINT notification_channels_type_check CHECK (channel_type IN ('email', 'slack', 'webhook', 'pagerduty')),
+    CONSTRAINT notification_channels_recipients_not_empty CHECK (array_length(recipients, 1) > 0)
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    event_type VARCHAR(100) NOT NULL,
+    severity VARCHAR(20) NOT NULL DEFAULT 'info',
+    title VARCHAR(500) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    resource_type VARCHAR(100) NOT NULL DEFAULT '',
+    resource_id UUID,
+    payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    channel_id UUID NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+    channel_type VARCHAR(50) NOT NULL,
+    recipient TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Doubles as the claim lease while status = 'sending'
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT notification_deliveries_status_check CHECK (status IN ('pending', 'sending', 'delivered', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_channels_organization_id ON notification_channels(organization_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_organization_created ON notifications(organization_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_notification_id ON notification_deliveries(notification_id);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_queue ON notification_deliveries(next_attempt_at)
+    WHERE status IN ('pending', 'sending');
+
+COMMENT ON TABLE notification_channels IS 'Organization notification destinations (email, Slack, webhook, PagerDuty)';
+COMMENT ON TABLE notifications IS 'Alerts and events fanned out to notification channels';
+COMMENT ON TABLE notification_deliveries IS 'Per-channel per-recipient delivery queue with retry state';
+COMMENT ON COLUMN notification_deliveries.next_attempt_at IS 'When the delivery becomes due; while sending, when the worker lease expires';
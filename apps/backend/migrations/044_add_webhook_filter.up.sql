@@ -0,0 +1,8 @@
+-- Migration: Add filter expression to webhook subscriptions
+-- Created: 2025-11-12
+-- Purpose: Let subscribers narrow deliveries with a CEL filter evaluated against each event
+--          (e.g. event.driftDetected && agent.tags.contains("prod"))
+
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS filter TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN webhooks.filter IS 'Optional CEL expression over event and agent; empty delivers every subscribed event';
@@ -0,0 +1,31 @@
+-- Migration: Private network attestation relays
+-- Created: 2025-11-12
+-- Purpose: Let agents attest MCP servers that are only reachable on private networks.
+--          A relay records that an agent reaches the MCP server from inside a network the
+--          backend cannot, so the backend skips its own connectivity checks and treats the
+--          resulting attestations as "agent-verified only".
+
+CREATE TABLE IF NOT EXISTS mcp_network_relays (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    mcp_server_id UUID NOT NULL REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    network_zone VARCHAR(255) NOT NULL DEFAULT '', -- e.g. VPC, datacenter or site name
+    description TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    last_attested_at TIMESTAMPTZ,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT mcp_network_relays_unique_agent UNIQUE (mcp_server_id, agent_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mcp_network_relays_mcp ON mcp_network_relays(mcp_server_id) WHERE is_active = true;
+CREATE INDEX IF NOT EXISTS idx_mcp_network_relays_agent ON mcp_network_relays(agent_id);
+
+ALTER TABLE mcp_attestations
+    ADD COLUMN IF NOT EXISTS agent_verified_only BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON TABLE mcp_network_relays IS 'Agents that reach private-network MCP servers on behalf of the backend';
+COMMENT ON COLUMN mcp_attestations.agent_verified_only IS 'Attestation of a private-network MCP the backend cannot independently reach';
@@ -0,0 +1,57 @@
+-- Migration: Create report subscriptions and report runs tables
+-- Created: 2025-11-12
+-- Purpose: Scheduled push delivery of security posture, verification summary, drift digest
+--          and trust change reports (CSV/PDF) through notification channels
+
+CREATE TABLE IF NOT EXISTS report_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    report_type VARCHAR(50) NOT NULL,
+    cadence VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL DEFAULT 'csv',
+    delivery VARCHAR(10) NOT NULL DEFAULT 'link', -- 'inline' embeds the file in the notification, 'link' sends a download URL
+    channel_ids UUID[] NOT NULL DEFAULT '{}',     -- Notification channels the report is delivered to
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    last_run_at TIMESTAMPTZ,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT report_subscriptions_name_unique_per_org UNIQUE (organization_id, name),
+    CONSTRAINT report_subscriptions_type_check CHECK (report_type IN ('security_posture', 'verification_summary', 'drift_digest', 'trust_changes')),
+    CONSTRAINT report_subscriptions_cadence_check CHECK (cadence IN ('daily', 'weekly', 'monthly')),
+    CONSTRAINT report_subscriptions_format_check CHECK (format IN ('csv', 'pdf')),
+    CONSTRAINT report_subscriptions_delivery_check CHECK (delivery IN ('inline', 'link')),
+    CONSTRAINT report_subscriptions_channels_not_empty CHECK (array_length(channel_ids, 1) > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_subscriptions_org ON report_subscriptions(organization_id);
+CREATE INDEX IF NOT EXISTS idx_report_subscriptions_due ON report_subscriptions(next_run_at) WHERE is_active = true;
+
+CREATE TABLE IF NOT EXISTS report_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES report_subscriptions(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    period_start TIMESTAMPTZ NOT NULL,
+    period_end TIMESTAMPTZ NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    filename VARCHAR(255),
+    content_type VARCHAR(100),
+    content BYTEA,
+    row_count INTEGER NOT NULL DEFAULT 0,
+    notification_id UUID REFERENCES notifications(id) ON DELETE SET NULL,
+    download_token VARCHAR(64) UNIQUE,
+    expires_at TIMESTAMPTZ,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT report_runs_status_check CHECK (status IN ('succeeded', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_runs_subscription ON report_runs(subscription_id, created_at DESC);
+
+COMMENT ON TABLE report_subscriptions IS 'Scheduled reports pushed to notification channels on a daily, weekly or monthly cadence';
+COMMENT ON COLUMN report_runs.download_token IS 'Unguessable token for the unauthenticated download link sent with link delivery';
@@ -0,0 +1,53 @@
+-- Migration: Create external policy decision point (OPA) configuration and decision log
+-- Created: 2025-11-12
+-- Purpose: Let organizations delegate verify-action / A2A authorization to an external
+--          Open Policy Agent instance and record its decision next to the native policy result
+
+CREATE TABLE IF NOT EXISTS external_policy_decision_points (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL UNIQUE REFERENCES organizations(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL DEFAULT 'opa',
+    endpoint TEXT NOT NULL,                  -- OPA base URL, e.g. https://opa.internal:8181
+    decision_path VARCHAR(500) NOT NULL,     -- Rule path queried via POST /v1/data/<decision_path>
+    auth_token TEXT,                         -- Optional bearer token sent to OPA
+    mode VARCHAR(20) NOT NULL DEFAULT 'enforce',
+    fail_open BOOLEAN NOT NULL DEFAULT false, -- When OPA is unreachable: false = deny, true = use native decision
+    timeout_ms INTEGER NOT NULL DEFAULT 2000,
+    is_enabled BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT external_pdp_provider_check CHECK (provider IN ('opa')),
+    CONSTRAINT external_pdp_mode_check CHECK (mode IN ('enforce', 'advisory')),
+    CONSTRAINT external_pdp_timeout_check CHECK (timeout_ms BETWEEN 100 AND 30000)
+);
+
+CREATE TABLE IF NOT EXISTS policy_decisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    agent_id UUID REFERENCES agents(id) ON DELETE CASCADE,
+    audit_id UUID NOT NULL,
+    action_type VARCHAR(255) NOT NULL,
+    resource TEXT,
+    native_allowed BOOLEAN NOT NULL,
+    native_reason TEXT,
+    pdp_id UUID REFERENCES external_policy_decision_points(id) ON DELETE SET NULL,
+    pdp_mode VARCHAR(20),
+    external_allowed BOOLEAN,                -- NULL when the external call failed
+    external_reason TEXT,
+    external_error TEXT,
+    external_duration_ms INTEGER NOT NULL DEFAULT 0,
+    final_allowed BOOLEAN NOT NULL,
+    decision_source VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT policy_decisions_source_check CHECK (decision_source IN ('native', 'external', 'fallback'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_decisions_org_time ON policy_decisions(organization_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_policy_decisions_agent ON policy_decisions(agent_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_policy_decisions_disagreement ON policy_decisions(organization_id, created_at DESC)
+    WHERE external_allowed IS NOT NULL AND external_allowed <> native_allowed;
+
+COMMENT ON TABLE policy_decisions IS 'Authorization decisions where an external PDP was consulted, with the native policy result for comparison';
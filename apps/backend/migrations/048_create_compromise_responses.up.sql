@@ -0,0 +1,65 @@
+-- Migration: Create compromise response policies and reports
+-- Created: 2025-11-12
+-- Purpose: Run a configurable incident response bundle when an agent is marked compromised
+--          (revoke credentials, invalidate attestations, notify MCP owners, open an incident,
+--          freeze the trust score) and keep a report of the actions taken
+
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS trust_score_frozen_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN agents.trust_score_frozen_at IS 'Set when the trust score is frozen by a compromise response; automatic trust updates are skipped while set';
+
+-- Incidents are written by SecurityRepository.CreateIncident; the table was never migrated
+CREATE TABLE IF NOT EXISTS security_incidents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    incident_type VARCHAR(100) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'open',
+    severity VARCHAR(20) NOT NULL,
+    title VARCHAR(500) NOT NULL,
+    description TEXT,
+    affected_resources TEXT[] NOT NULL DEFAULT '{}',
+    assigned_to UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMPTZ,
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolution_notes TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_incidents_org_status ON security_incidents(organization_id, status, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS compromise_response_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL UNIQUE REFERENCES organizations(id) ON DELETE CASCADE,
+    revoke_api_keys BOOLEAN NOT NULL DEFAULT true,
+    revoke_owner_sdk_tokens BOOLEAN NOT NULL DEFAULT false, -- SDK tokens are per user and may be shared by other agents
+    invalidate_attestations BOOLEAN NOT NULL DEFAULT true,
+    notify_mcp_owners BOOLEAN NOT NULL DEFAULT true,
+    open_incident BOOLEAN NOT NULL DEFAULT true,
+    freeze_trust_score BOOLEAN NOT NULL DEFAULT true,
+    incident_severity VARCHAR(20) NOT NULL DEFAULT 'critical',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT compromise_policy_severity_check CHECK (incident_severity IN ('info', 'warning', 'high', 'critical'))
+);
+
+CREATE TABLE IF NOT EXISTS compromise_responses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    trigger VARCHAR(50) NOT NULL,
+    reason TEXT,
+    triggered_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    incident_id UUID REFERENCES security_incidents(id) ON DELETE SET NULL,
+    actions JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT compromise_responses_trigger_check CHECK (trigger IN ('manual', 'capability_violation'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_compromise_responses_agent ON compromise_responses(agent_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_compromise_responses_org_time ON compromise_responses(organization_id, created_at DESC);
+
+COMMENT ON TABLE compromise_responses IS 'Report of the response bundle executed each time an agent was marked compromised';
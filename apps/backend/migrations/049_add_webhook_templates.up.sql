@@ -0,0 +1,11 @@
+-- Migration: Add transformation templates to webhook subscriptions
+-- Created: 2025-11-12
+-- Purpose: Let subscribers reshape delivered payloads (Slack blocks, Jira issues, ServiceNow events)
+--          with a Go template or jq filter instead of deploying middleware
+
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS template_type VARCHAR(20) NOT NULL DEFAULT ''
+    CHECK (template_type IN ('', 'go_template', 'jq'));
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS template TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN webhooks.template_type IS 'Template language: go_template, jq, or empty to deliver the platform payload unchanged';
+COMMENT ON COLUMN webhooks.template IS 'Transformation applied to the {event, timestamp, data} payload before signing and delivery';
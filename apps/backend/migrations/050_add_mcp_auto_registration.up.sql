@@ -0,0 +1,11 @@
+-- Migration: Add attestation-driven MCP auto-registration setting
+-- Created: 2025-11-12
+-- Purpose: Let organizations opt in to creating a pending MCP server (queued for admin review)
+--          when a verified agent attests an MCP URL nobody registered, instead of rejecting it
+
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS auto_register_attested_mcps BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN organizations.auto_register_attested_mcps IS 'Create pending MCP servers from attestations of unregistered URLs';
+
+-- Auto-registered servers are looked up by URL within the organization
+CREATE INDEX IF NOT EXISTS idx_mcp_servers_org_url ON mcp_servers(organization_id, url);
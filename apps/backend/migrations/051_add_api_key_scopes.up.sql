@@ -0,0 +1,8 @@
+-- Migration: Add scopes to API keys
+-- Created: 2025-11-12
+-- Purpose: Let API keys carry scopes (e.g. "verify:create", "agents:read") instead of granting
+--          full agent access. Existing keys keep an empty scope list, which means full access.
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN api_keys.scopes IS 'Scopes granted to the key; empty means full access';
@@ -0,0 +1,46 @@
+-- Migration: Create trust boundary policies and agent boundary state
+-- Created: 2025-11-12
+-- Purpose: Let organizations attach automatic actions to trust score boundaries
+--          (below the floor: suspend and open an incident; sustained above the ceiling:
+--          extend API key expiry or relax the verification cadence) and track each agent's position
+--          so every transition triggers its actions once
+
+CREATE TABLE IF NOT EXISTS trust_boundary_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL UNIQUE REFERENCES organizations(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    floor_score DECIMAL(5,4) NOT NULL DEFAULT 0.3,
+    floor_suspend BOOLEAN NOT NULL DEFAULT true,
+    floor_open_incident BOOLEAN NOT NULL DEFAULT true,
+    incident_severity VARCHAR(20) NOT NULL DEFAULT 'high',
+    ceiling_score DECIMAL(5,4) NOT NULL DEFAULT 0.9,
+    ceiling_sustained_days INTEGER NOT NULL DEFAULT 30,
+    ceiling_extend_key_expiry BOOLEAN NOT NULL DEFAULT true,
+    key_extension_days INTEGER NOT NULL DEFAULT 90,
+    ceiling_relax_verification BOOLEAN NOT NULL DEFAULT false,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT trust_boundary_scores_check CHECK (floor_score >= 0 AND ceiling_score <= 1 AND floor_score < ceiling_score),
+    CONSTRAINT trust_boundary_days_check CHECK (ceiling_sustained_days > 0 AND key_extension_days > 0),
+    CONSTRAINT trust_boundary_severity_check CHECK (incident_severity IN ('info', 'warning', 'high', 'critical'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_trust_boundary_policies_enabled ON trust_boundary_policies(enabled) WHERE enabled = true;
+
+CREATE TABLE IF NOT EXISTS agent_trust_boundary_states (
+    agent_id UUID PRIMARY KEY REFERENCES agents(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    last_score DECIMAL(5,4) NOT NULL,
+    below_floor_since TIMESTAMPTZ,
+    above_ceiling_since TIMESTAMPTZ,
+    ceiling_actions_at TIMESTAMPTZ,
+    verification_relaxed BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_trust_boundary_states_org ON agent_trust_boundary_states(organization_id);
+
+COMMENT ON TABLE agent_trust_boundary_states IS 'Position of each agent relative to its organization''s trust boundaries; transitions are audit-logged';
+COMMENT ON COLUMN agent_trust_boundary_states.verification_relaxed IS 'Set by the ceiling action; approved verifications of the agent stay valid longer while set';
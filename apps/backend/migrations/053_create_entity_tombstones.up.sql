@@ -0,0 +1,38 @@
+-- Migration: Create entity tombstones
+-- Created: 2025-11-12
+-- Purpose: Keep an immutable, redacted record of deleted agents, MCP servers and API keys
+--          (ID, name, type, deletion actor, timestamp, linked counts) so references in old
+--          events and incidents resolve to something meaningful instead of a dangling UUID
+
+CREATE TABLE IF NOT EXISTS entity_tombstones (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- No foreign keys: tombstones outlive the organization, entity and user they describe
+    organization_id UUID NOT NULL,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    deleted_by UUID,
+    deleted_by_email VARCHAR(255),
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    linked_counts JSONB NOT NULL DEFAULT '{}'::jsonb,
+    details JSONB NOT NULL DEFAULT '{}'::jsonb,
+
+    CONSTRAINT entity_tombstones_type_check CHECK (entity_type IN ('agent', 'mcp_server', 'api_key'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_tombstones_org_time ON entity_tombstones(organization_id, deleted_at DESC);
+CREATE INDEX IF NOT EXISTS idx_entity_tombstones_entity ON entity_tombstones(entity_id);
+CREATE INDEX IF NOT EXISTS idx_entity_tombstones_name ON entity_tombstones(organization_id, LOWER(name));
+
+CREATE OR REPLACE FUNCTION prevent_entity_tombstone_changes() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'entity_tombstones is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS entity_tombstones_immutable ON entity_tombstones;
+CREATE TRIGGER entity_tombstones_immutable
+    BEFORE UPDATE OR DELETE ON entity_tombstones
+    FOR EACH ROW EXECUTE FUNCTION prevent_entity_tombstone_changes();
+
+COMMENT ON TABLE entity_tombstones IS 'Immutable, redacted record of deleted agents, MCP servers and API keys';
@@ -0,0 +1,57 @@
+-- Migration: Create alert suppression rules
+-- Created: 2025-11-12
+-- Purpose: Let organizations drop or downgrade known-noisy alerts before notification.
+--          Matched alerts are still recorded; every rule expires and counts its hits so
+--          silenced signals stay visible
+
+CREATE TABLE IF NOT EXISTS alert_suppression_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+
+    -- Matching (empty / NULL matches every alert)
+    alert_types TEXT[] NOT NULL DEFAULT '{}',
+    resource_type VARCHAR(100) NOT NULL DEFAULT '',
+    resource_id UUID,
+    tag_id UUID REFERENCES tags(id) ON DELETE CASCADE,
+    window_start VARCHAR(5) NOT NULL DEFAULT '', -- Daily window in UTC ("HH:MM")
+    window_end VARCHAR(5) NOT NULL DEFAULT '',
+    repeat_threshold INTEGER NOT NULL DEFAULT 0,
+    repeat_window_minutes INTEGER NOT NULL DEFAULT 0,
+
+    action VARCHAR(20) NOT NULL,
+    downgrade_to VARCHAR(20) NOT NULL DEFAULT '',
+
+    hit_count BIGINT NOT NULL DEFAULT 0,
+    last_hit_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT alert_suppression_rules_name_unique_per_org UNIQUE (organization_id, name),
+    CONSTRAINT alert_suppression_rules_action_check CHECK (action IN ('drop', 'downgrade'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_suppression_rules_active ON alert_suppression_rules(organization_id, expires_at) WHERE enabled = true;
+
+CREATE TABLE IF NOT EXISTS alert_suppression_hits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rule_id UUID NOT NULL REFERENCES alert_suppression_rules(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    alert_id UUID NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    original_severity VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_suppression_hits_rule ON alert_suppression_hits(rule_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_alert_suppression_hits_alert ON alert_suppression_hits(alert_id);
+
+-- Repeated fingerprint matching counts recent alerts of the same type, resource and title
+CREATE INDEX IF NOT EXISTS idx_alerts_fingerprint ON alerts(organization_id, alert_type, resource_id, created_at DESC);
+
+COMMENT ON TABLE alert_suppression_rules IS 'Expiring rules that drop or downgrade known-noisy alerts before notification';
+COMMENT ON TABLE alert_suppression_hits IS 'Alerts matched by a suppression rule';
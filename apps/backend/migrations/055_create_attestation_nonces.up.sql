@@ -0,0 +1,20 @@
+-- Migration: Create attestation nonces
+-- Created: 2025-11-12
+-- Purpose: Server-issued, single-use nonces that agents sign into MCP attestations so a
+--          captured signed attestation cannot be replayed
+
+CREATE TABLE IF NOT EXISTS attestation_nonces (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    nonce VARCHAR(64) NOT NULL UNIQUE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    consumed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_attestation_nonces_agent ON attestation_nonces(agent_id);
+CREATE INDEX IF NOT EXISTS idx_attestation_nonces_expires_at ON attestation_nonces(expires_at);
+
+COMMENT ON TABLE attestation_nonces IS 'Single-use challenges agents must sign into MCP attestations';
+COMMENT ON COLUMN attestation_nonces.consumed_at IS 'Set when an attestation used the nonce; a second use is a replay';
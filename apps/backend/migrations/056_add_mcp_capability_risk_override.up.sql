@@ -0,0 +1,16 @@
+-- Migration: Add MCP capability risk override
+-- Created: 2025-11-12
+-- Purpose: Let admins override the heuristic risk classification (read, write, destructive,
+--          exfiltration) of an MCP server capability. The heuristic classification itself
+--          is derived from the capability name when read, so only overrides are stored
+
+ALTER TABLE mcp_server_capabilities
+    ADD COLUMN IF NOT EXISTS risk_override VARCHAR(20);
+
+ALTER TABLE mcp_server_capabilities
+    DROP CONSTRAINT IF EXISTS mcp_server_capabilities_risk_override_check;
+ALTER TABLE mcp_server_capabilities
+    ADD CONSTRAINT mcp_server_capabilities_risk_override_check
+    CHECK (risk_override IS NULL OR risk_override IN ('read', 'write', 'destructive', 'exfiltration'));
+
+COMMENT ON COLUMN mcp_server_capabilities.risk_override IS 'Admin-set risk classification; NULL uses the heuristic classification';
@@ -0,0 +1,33 @@
+-- Migration: Create emergency credentials
+-- Created: 2025-11-12
+-- Purpose: Pre-generated, single-use break-glass credentials for critical agents. The secret is
+--          sealed to an organization-held key at generation time; only its hash is stored.
+
+CREATE TABLE IF NOT EXISTS emergency_credentials (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    secret_hash VARCHAR(64) NOT NULL UNIQUE,
+    sealing_public_key TEXT NOT NULL,
+    key_fingerprint VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'activated', 'revoked')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    activated_at TIMESTAMPTZ,
+    activated_ip VARCHAR(64),
+    activation_reason TEXT,
+    access_expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    revoke_reason TEXT,
+    rotated_from UUID REFERENCES emergency_credentials(id) ON DELETE SET NULL,
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_emergency_credentials_agent ON emergency_credentials(agent_id);
+CREATE INDEX IF NOT EXISTS idx_emergency_credentials_org ON emergency_credentials(organization_id);
+
+COMMENT ON TABLE emergency_credentials IS 'Sealed break-glass credentials that restore access when SDK token refresh is broken';
+COMMENT ON COLUMN emergency_credentials.secret_hash IS 'SHA-256 of the secret; the plaintext is only ever released sealed to the organization key';
+COMMENT ON COLUMN emergency_credentials.rotated_from IS 'Credential this one replaced after it was activated or rotated out';
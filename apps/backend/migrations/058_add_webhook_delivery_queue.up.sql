@@ -0,0 +1,25 @@
+-- Migration: Turn webhook deliveries into a retrying delivery queue
+-- Created: 2025-11-12
+-- Purpose: Webhook events are queued and delivered by a background worker with exponential
+--          backoff, so receiver outages delay events instead of dropping them
+
+ALTER TABLE webhook_deliveries
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'sending', 'delivered', 'failed')),
+    ADD COLUMN IF NOT EXISTS max_attempts INTEGER NOT NULL DEFAULT 5,
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS last_error TEXT,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- Deliveries recorded before the queue existed were single synchronous attempts
+UPDATE webhook_deliveries
+SET status = CASE WHEN success THEN 'delivered' ELSE 'failed' END
+WHERE status = 'pending' AND created_at < CURRENT_TIMESTAMP;
+
+ALTER TABLE webhook_deliveries ALTER COLUMN attempt_count SET DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_queue ON webhook_deliveries(status, next_attempt_at)
+    WHERE status IN ('pending', 'sending');
+
+COMMENT ON COLUMN webhook_deliveries.status IS 'pending, sending (claimed by a worker), delivered or failed (retries exhausted)';
+COMMENT ON COLUMN webhook_deliveries.next_attempt_at IS 'When a pending delivery is next due; for sending deliveries, when the worker lease expires';
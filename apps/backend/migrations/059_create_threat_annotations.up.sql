@@ -0,0 +1,18 @@
+-- Migration: Create threat annotations
+-- Created: 2025-11-12
+-- Purpose: Triage notes on security threats. Threats are read from alerts, so annotations
+--          reference the alert behind the threat.
+
+CREATE TABLE IF NOT EXISTS threat_annotations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    threat_id UUID NOT NULL REFERENCES alerts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    note TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_threat_annotations_threat ON threat_annotations(threat_id, created_at);
+
+-- Threat searches filter alerts by target
+CREATE INDEX IF NOT EXISTS idx_alerts_resource ON alerts(organization_id, resource_type, resource_id);
@@ -0,0 +1,31 @@
+-- Migration: Create agent SBOMs
+-- Created: 2025-11-12
+-- Purpose: SPDX and CycloneDX SBOMs uploaded per agent version, with the known vulnerabilities
+--          of their components from the last OSV scan.
+
+CREATE TABLE IF NOT EXISTS agent_sboms (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    agent_version VARCHAR(100) NOT NULL DEFAULT '',
+    format VARCHAR(20) NOT NULL CHECK (format IN ('spdx', 'cyclonedx')),
+    spec_version VARCHAR(50) NOT NULL DEFAULT '',
+    source_url TEXT,
+    document_hash VARCHAR(64) NOT NULL,
+    document JSONB NOT NULL,
+    components JSONB NOT NULL DEFAULT '[]',
+    component_count INTEGER NOT NULL DEFAULT 0,
+    vulnerabilities JSONB NOT NULL DEFAULT '[]',
+    vulnerability_count INTEGER NOT NULL DEFAULT 0,
+    critical_count INTEGER NOT NULL DEFAULT 0,
+    high_count INTEGER NOT NULL DEFAULT 0,
+    scan_status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (scan_status IN ('pending', 'scanned', 'failed')),
+    scan_error TEXT,
+    scanned_at TIMESTAMPTZ,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_sboms_agent ON agent_sboms(agent_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_agent_sboms_org ON agent_sboms(organization_id);
@@ -0,0 +1,48 @@
+-- Migration: Create approval chains
+-- Created: 2025-11-12
+-- Purpose: Multi-step approvals for sensitive actions (capability grants, agent verification,
+--          policy disabling). A chain lists the approvals required from distinct users; an
+--          approval request tracks the decisions collected for one action on one resource.
+
+CREATE TABLE IF NOT EXISTS approval_chains (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    action VARCHAR(50) NOT NULL
+        CHECK (action IN ('capability_grant', 'agent_verification', 'policy_disable')),
+    steps JSONB NOT NULL DEFAULT '[]',
+    min_risk_severity VARCHAR(20) NOT NULL DEFAULT '',
+    agent_tags TEXT[] NOT NULL DEFAULT '{}',
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_approval_chains_org_action ON approval_chains(organization_id, action);
+
+CREATE TABLE IF NOT EXISTS approval_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    chain_id UUID REFERENCES approval_chains(id) ON DELETE SET NULL,
+    chain_name VARCHAR(255) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id UUID NOT NULL,
+    summary TEXT NOT NULL DEFAULT '',
+    steps JSONB NOT NULL DEFAULT '[]',
+    decisions JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected')),
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ
+);
+
+-- Only one request per action and resource collects approvals at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_approval_requests_pending
+    ON approval_requests(organization_id, action, resource_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_approval_requests_org ON approval_requests(organization_id, status, created_at DESC);
+
+COMMENT ON TABLE approval_chains IS 'Approvals from distinct users required before a sensitive action takes effect';
+COMMENT ON COLUMN approval_requests.steps IS 'Copy of the chain steps when the request was opened';
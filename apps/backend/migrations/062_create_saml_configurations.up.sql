@@ -0,0 +1,26 @@
+-- Migration: Create SAML configurations
+-- Created: 2025-11-12
+-- Purpose: Per-organization SAML 2.0 single sign-on. AIM acts as the service provider; users
+--          are provisioned just in time and their role is mapped from an assertion attribute.
+
+CREATE TABLE IF NOT EXISTS saml_configurations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL UNIQUE REFERENCES organizations(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    idp_entity_id TEXT NOT NULL,
+    idp_sso_url TEXT NOT NULL,
+    idp_certificate TEXT NOT NULL,
+    email_attribute VARCHAR(255) NOT NULL DEFAULT '',
+    name_attribute VARCHAR(255) NOT NULL DEFAULT '',
+    role_attribute VARCHAR(255) NOT NULL DEFAULT '',
+    role_mappings JSONB NOT NULL DEFAULT '{}',
+    default_role VARCHAR(50) NOT NULL DEFAULT 'viewer'
+        CHECK (default_role IN ('admin', 'manager', 'member', 'viewer')),
+    jit_provisioning BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE saml_configurations IS 'SAML 2.0 identity provider settings, one per organization';
+COMMENT ON COLUMN saml_configurations.role_mappings IS 'Role attribute value -> AIM role';
@@ -0,0 +1,24 @@
+-- Migration: Create capability deprecations
+-- Created: 2025-11-13
+-- Purpose: Agent capabilities that refer to a tool their MCP server stopped exposing, as seen by
+--          capability auto-discovery or agent attestations. A deprecation is resolved when the
+--          tool comes back or the capability is revoked.
+
+CREATE TABLE IF NOT EXISTS capability_deprecations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    capability_id UUID NOT NULL REFERENCES agent_capabilities(id) ON DELETE CASCADE,
+    capability_type VARCHAR(255) NOT NULL,
+    mcp_server_id UUID NOT NULL REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    tool_name VARCHAR(255) NOT NULL,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('discovery', 'attestation')),
+    deprecated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMPTZ
+);
+
+-- At most one open deprecation per capability and server
+CREATE UNIQUE INDEX IF NOT EXISTS idx_capability_deprecations_open
+    ON capability_deprecations(capability_id, mcp_server_id) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_capability_deprecations_server ON capability_deprecations(mcp_server_id) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_capability_deprecations_agent ON capability_deprecations(agent_id) WHERE resolved_at IS NULL;
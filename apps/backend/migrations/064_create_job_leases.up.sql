@@ -0,0 +1,11 @@
+-- Migration: Create job leases
+-- Created: 2025-11-13
+-- Purpose: Leader election for background jobs. Only the server instance holding a lease runs
+--          the jobs behind it, so multi-instance deployments do not run each job once per instance.
+
+CREATE TABLE IF NOT EXISTS job_leases (
+    name VARCHAR(100) PRIMARY KEY,
+    holder_id VARCHAR(255) NOT NULL,
+    acquired_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL
+);
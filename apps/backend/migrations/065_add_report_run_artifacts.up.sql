@@ -0,0 +1,6 @@
+-- Migration: Keep rendered report files in object storage
+-- Created: 2025-11-13
+-- Purpose: Report runs rendered while object storage is configured keep their file there
+--          instead of in the content column; artifact_name locates it.
+
+ALTER TABLE report_runs ADD COLUMN IF NOT EXISTS artifact_name VARCHAR(255);
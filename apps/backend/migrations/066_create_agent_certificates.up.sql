@@ -0,0 +1,23 @@
+-- Migration: Create agent certificates
+-- Created: 2025-11-13
+-- Purpose: Short-lived X.509 certificates issued by the platform CA to verified agents,
+--          bound to the agent's Ed25519 public key. Revoked certificates make up the CRL.
+--          Certificates outlive their agent (no foreign key) so that deleted agents stay on the CRL
+--          until their certificates expire.
+
+CREATE TABLE IF NOT EXISTS agent_certificates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL,
+    serial_number VARCHAR(64) NOT NULL UNIQUE,
+    public_key TEXT NOT NULL,
+    certificate_pem TEXT NOT NULL,
+    not_before TIMESTAMPTZ NOT NULL,
+    not_after TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    revocation_reason VARCHAR(50),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_certificates_agent ON agent_certificates(agent_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_agent_certificates_revoked ON agent_certificates(not_after) WHERE revoked_at IS NOT NULL;
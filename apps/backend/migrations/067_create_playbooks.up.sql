@@ -0,0 +1,46 @@
+-- Migration: Create containment playbooks
+-- Created: 2025-11-13
+-- Purpose: Incident-driven containment playbooks. A playbook binds incident types to ordered
+--          automated actions (quarantine agents, rotate keys, suppress alerts, notify channels,
+--          create tickets), each optionally gated by manual approval. An execution records the
+--          outcome of every step and a full log of one run against one incident.
+
+CREATE TABLE IF NOT EXISTS playbooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    incident_types TEXT[] NOT NULL DEFAULT '{}',
+    min_severity VARCHAR(20) NOT NULL DEFAULT '',
+    steps JSONB NOT NULL DEFAULT '[]',
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_playbooks_org ON playbooks(organization_id);
+
+CREATE TABLE IF NOT EXISTS playbook_executions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    playbook_id UUID NOT NULL,
+    playbook_name VARCHAR(255) NOT NULL,
+    incident_id UUID NOT NULL REFERENCES security_incidents(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'running'
+        CHECK (status IN ('running', 'awaiting_approval', 'completed', 'failed', 'cancelled')),
+    current_step INTEGER NOT NULL DEFAULT 0,
+    steps JSONB NOT NULL DEFAULT '[]',
+    log JSONB NOT NULL DEFAULT '[]',
+    triggered_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_playbook_executions_org ON playbook_executions(organization_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_playbook_executions_playbook ON playbook_executions(playbook_id, started_at DESC);
+
+COMMENT ON TABLE playbooks IS 'Ordered containment actions run automatically when a matching security incident opens';
+COMMENT ON COLUMN playbook_executions.playbook_id IS 'No foreign key: executions outlive deleted playbooks';
+COMMENT ON COLUMN playbook_executions.steps IS 'Copy of the playbook steps with the outcome of each';
@@ -0,0 +1,14 @@
+-- Migration: Create agent listings
+-- Created: 2025-11-13
+-- Purpose: Agents an organization has made publicly discoverable. Their verification status,
+--          trust tier and last verification can be looked up without authentication by
+--          <org-domain>/<agent-name>.
+
+CREATE TABLE IF NOT EXISTS agent_listings (
+    agent_id UUID PRIMARY KEY REFERENCES agents(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    listed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    listed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_listings_organization ON agent_listings(organization_id);
@@ -0,0 +1,9 @@
+-- Migration: Add client-side key generation setting
+-- Created: 2025-11-13
+-- Purpose: Let organizations opt out of private key escrow. Their agents' key pairs are generated
+--          by the SDK; registrations and key rotations carry only the public key and a
+--          proof-of-possession signature, and no encrypted private key is stored.
+
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS client_side_key_generation BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN organizations.client_side_key_generation IS 'Agent private keys are generated client-side and never stored';
@@ -0,0 +1,49 @@
+-- Migration: Create change requests
+-- Created: 2025-11-13
+-- Purpose: Staged configuration changes to agents and security policies. A change is approved
+--          in advance (optionally through a config_change approval chain), applied when its
+--          change window opens, and rolled back automatically when the verification error rate
+--          rises above its threshold while it is monitored.
+
+CREATE TABLE IF NOT EXISTS change_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    target_type VARCHAR(50) NOT NULL CHECK (target_type IN ('agent', 'security_policy')),
+    target_id UUID NOT NULL,
+    summary TEXT NOT NULL DEFAULT '',
+    changes JSONB NOT NULL DEFAULT '{}',
+    previous JSONB,
+    effective_at TIMESTAMPTZ NOT NULL,
+    window_ends_at TIMESTAMPTZ,
+    monitor_minutes INTEGER NOT NULL DEFAULT 60 CHECK (monitor_minutes >= 0),
+    rollback_error_rate DOUBLE PRECISION NOT NULL DEFAULT 0.25
+        CHECK (rollback_error_rate > 0 AND rollback_error_rate <= 1),
+    baseline_error_rate DOUBLE PRECISION,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending_approval'
+        CHECK (status IN ('pending_approval', 'scheduled', 'applied', 'completed', 'rolled_back',
+                          'rejected', 'cancelled', 'expired', 'failed')),
+    approval_request_id UUID REFERENCES approval_requests(id) ON DELETE SET NULL,
+    requested_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    approved_at TIMESTAMPTZ,
+    applied_at TIMESTAMPTZ,
+    closed_at TIMESTAMPTZ,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_change_requests_org ON change_requests(organization_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_change_requests_open ON change_requests(status, effective_at)
+    WHERE status IN ('scheduled', 'applied');
+
+-- Approval chains can now guard scheduling a configuration change
+ALTER TABLE approval_chains
+    DROP CONSTRAINT IF EXISTS approval_chains_action_check;
+ALTER TABLE approval_chains
+    ADD CONSTRAINT approval_chains_action_check
+    CHECK (action IN ('capability_grant', 'agent_verification', 'policy_disable', 'config_change'));
+
+COMMENT ON TABLE change_requests IS 'Configuration changes staged for a change window, with automatic rollback';
+COMMENT ON COLUMN change_requests.target_id IS 'No foreign key: the target is an agent or a security policy';
+COMMENT ON COLUMN change_requests.previous IS 'Values of the changed fields before the change was applied, restored on rollback';
@@ -0,0 +1,19 @@
+-- Migration: Add TOTP multi-factor authentication
+-- Created: 2025-11-13
+-- Purpose: Local users can enroll an authenticator app (TOTP) and receive single-use recovery
+--          codes. Organizations choose which roles must sign in with a second factor.
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS mfa_secret TEXT,
+    ADD COLUMN IF NOT EXISTS mfa_recovery_codes TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS mfa_last_used_step BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS mfa_enrolled_at TIMESTAMPTZ;
+
+ALTER TABLE organizations
+    ADD COLUMN IF NOT EXISTS mfa_required_roles TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN users.mfa_secret IS 'TOTP secret encrypted with the key vault; set while enrollment is pending and after it is confirmed';
+COMMENT ON COLUMN users.mfa_recovery_codes IS 'SHA-256 hashes of the unused recovery codes';
+COMMENT ON COLUMN users.mfa_last_used_step IS 'TOTP time step of the last accepted code; older and equal steps are rejected as replays';
+COMMENT ON COLUMN organizations.mfa_required_roles IS 'User roles that must sign in with a second factor';
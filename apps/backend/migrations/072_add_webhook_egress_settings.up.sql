@@ -0,0 +1,22 @@
+-- Migration: Add webhook mTLS and egress settings
+-- Created: 2025-11-13
+-- Purpose: Organizations can present a client certificate to webhook receivers that require
+--          mutual TLS, and route their deliveries through a dedicated egress proxy with known
+--          source addresses. Webhooks can require mTLS so they are never delivered without it.
+
+ALTER TABLE webhooks
+    ADD COLUMN IF NOT EXISTS require_mtls BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS webhook_egress_settings (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    client_certificate TEXT NOT NULL DEFAULT '',
+    client_key TEXT NOT NULL DEFAULT '',
+    proxy_url TEXT NOT NULL DEFAULT '',
+    egress_ips TEXT[] NOT NULL DEFAULT '{}',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE webhook_egress_settings IS 'Per-organization client certificate and egress proxy for webhook deliveries';
+COMMENT ON COLUMN webhook_egress_settings.client_key IS 'PEM private key of the client certificate, encrypted with the key vault';
+COMMENT ON COLUMN webhook_egress_settings.egress_ips IS 'Source addresses of the dedicated proxy, published to receivers for allowlisting';
@@ -0,0 +1,20 @@
+-- Migration: Create shared key allowlist
+-- Created: 2025-11-13
+-- Purpose: Public keys an organization registers to several agents on purpose. Registrations and
+--          key rotations reusing another agent's public key are otherwise rejected, and keys that
+--          are shared anyway raise shared_credential threats.
+
+CREATE TABLE IF NOT EXISTS shared_key_allowlist (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    fingerprint VARCHAR(64) NOT NULL,
+    reason TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (organization_id, fingerprint)
+);
+
+-- Registrations look up the agents holding a public key
+CREATE INDEX IF NOT EXISTS idx_agents_public_key ON agents(public_key) WHERE public_key IS NOT NULL;
+
+COMMENT ON COLUMN shared_key_allowlist.fingerprint IS 'Hex SHA-256 of the base64-encoded public key';
@@ -0,0 +1,29 @@
+-- Migration: Create verification exports
+-- Created: 2025-11-13
+-- Purpose: Asynchronous exports of verification events to CSV or Parquet. A background job
+--          writes the file to object storage and emails the requester a download link.
+
+CREATE TABLE IF NOT EXISTS verification_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    requested_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    format VARCHAR(20) NOT NULL,
+    filter JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    row_count INTEGER NOT NULL DEFAULT 0,
+    size BIGINT NOT NULL DEFAULT 0,
+    filename VARCHAR(255),
+    content_type VARCHAR(100),
+    artifact_name TEXT,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_exports_org ON verification_exports(organization_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_verification_exports_pending ON verification_exports(created_at) WHERE status IN ('pending', 'running');
+
+COMMENT ON COLUMN verification_exports.filter IS 'Date range and filters selecting the exported events';
+COMMENT ON COLUMN verification_exports.artifact_name IS 'Object storage key of the file, under the exports artifact kind';
@@ -0,0 +1,33 @@
+-- Migration: Create agent peer policies
+-- Created: 2025-11-13
+-- Purpose: Agent-to-agent (A2A) authorization. A peer policy declares that one agent may call
+--          another, subject to trust score minimums; calls no policy declares are recorded as
+--          peer drift on the verification event.
+
+CREATE TABLE IF NOT EXISTS agent_peer_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    source_agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    target_agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    bidirectional BOOLEAN NOT NULL DEFAULT false,
+    allowed_actions TEXT[] NOT NULL DEFAULT '{}',
+    min_source_trust_score DOUBLE PRECISION NOT NULL DEFAULT 0.3,
+    min_target_trust_score DOUBLE PRECISION NOT NULL DEFAULT 0.3,
+    description TEXT,
+    is_enabled BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (source_agent_id, target_agent_id),
+    CHECK (source_agent_id <> target_agent_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_peer_policies_org ON agent_peer_policies(organization_id);
+CREATE INDEX IF NOT EXISTS idx_agent_peer_policies_target ON agent_peer_policies(target_agent_id);
+
+COMMENT ON COLUMN agent_peer_policies.bidirectional IS 'The target agent may call the source agent as well';
+COMMENT ON COLUMN agent_peer_policies.allowed_actions IS 'Actions the caller may request; empty allows any action';
+
+ALTER TABLE verification_events ADD COLUMN IF NOT EXISTS peer_agent_drift JSONB DEFAULT '[]'::jsonb;
+
+COMMENT ON COLUMN verification_events.peer_agent_drift IS 'JSONB array of agents called without a peer policy';
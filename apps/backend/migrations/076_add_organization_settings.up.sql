@@ -0,0 +1,7 @@
+-- Migration: Add organization settings
+-- Created: 2025-11-13
+-- Purpose: Store additional organization settings such as per-organization API rate limits
+
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN organizations.settings IS 'Additional organization settings, e.g. rateLimits by endpoint class';
@@ -0,0 +1,34 @@
+-- Migration: Create feature flags
+-- Created: 2025-11-13
+-- Purpose: Preview features rolled out per organization, with user opt-in where the flag
+--          allows it. Flags are added by the migration of the preview they gate.
+
+CREATE TABLE IF NOT EXISTS feature_flags (
+    key VARCHAR(100) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    default_enabled BOOLEAN NOT NULL DEFAULT false,
+    user_opt_in BOOLEAN NOT NULL DEFAULT false,
+    sdk_visible BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS feature_flag_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    flag_key VARCHAR(100) NOT NULL REFERENCES feature_flags(key) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One override per organization, and one per user, for each flag
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feature_flag_overrides_org
+    ON feature_flag_overrides(flag_key, organization_id) WHERE user_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feature_flag_overrides_user
+    ON feature_flag_overrides(flag_key, organization_id, user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_feature_flag_overrides_organization ON feature_flag_overrides(organization_id);
+
+COMMENT ON COLUMN feature_flags.default_enabled IS 'Whether the preview is on for organizations that have not chosen';
+COMMENT ON COLUMN feature_flags.sdk_visible IS 'Protocol-level preview reported to SDKs at /api/v1/sdk-api/features';
@@ -0,0 +1,33 @@
+-- Migration: Create connection latency SLOs
+-- Created: 2025-11-13
+-- Purpose: Latency objectives of agent-MCP connections. A job computes each connection's p95
+--          latency from the agent's attestations and alerts when it stays above the objective,
+--          attributing the regression to the MCP server or to the agent's network path.
+
+CREATE TABLE IF NOT EXISTS connection_latency_slos (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    mcp_server_id UUID NOT NULL REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    p95_threshold_ms DOUBLE PRECISION NOT NULL CHECK (p95_threshold_ms > 0),
+    window_minutes INTEGER NOT NULL DEFAULT 15 CHECK (window_minutes > 0),
+    sustained_minutes INTEGER NOT NULL DEFAULT 30 CHECK (sustained_minutes >= 0),
+    is_enabled BOOLEAN NOT NULL DEFAULT true,
+    last_p95_ms DOUBLE PRECISION,
+    last_samples INTEGER NOT NULL DEFAULT 0,
+    last_failures INTEGER NOT NULL DEFAULT 0,
+    last_evaluated_at TIMESTAMPTZ,
+    breached_since TIMESTAMPTZ,
+    alerted_at TIMESTAMPTZ,
+    attribution VARCHAR(20),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (agent_id, mcp_server_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_connection_latency_slos_mcp_server ON connection_latency_slos(mcp_server_id);
+CREATE INDEX IF NOT EXISTS idx_connection_latency_slos_enabled ON connection_latency_slos(is_enabled) WHERE is_enabled = true;
+
+COMMENT ON COLUMN connection_latency_slos.last_p95_ms IS 'p95 latency of the successful attestations in the last evaluated window';
+COMMENT ON COLUMN connection_latency_slos.attribution IS 'server, agent_path or undetermined while the SLO is breached';
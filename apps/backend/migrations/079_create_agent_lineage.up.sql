@@ -0,0 +1,23 @@
+-- Migration: Create agent lineage
+-- Created: 2025-11-13
+-- Purpose: Agents imported from another deployment (self-hosted, cloud or another region) with a
+--          signed identity bundle keep a record of every deployment they came through, so their
+--          verification lineage survives the move.
+
+CREATE TABLE IF NOT EXISTS agent_lineage (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    issuer_name TEXT NOT NULL,
+    issuer_key_fingerprint VARCHAR(64) NOT NULL,
+    source_agent_id UUID NOT NULL,
+    source_organization_id UUID NOT NULL,
+    registered_at TIMESTAMPTZ NOT NULL,
+    exported_at TIMESTAMPTZ NOT NULL,
+    imported_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_lineage_agent ON agent_lineage(agent_id, imported_at);
+
+COMMENT ON COLUMN agent_lineage.issuer_key_fingerprint IS 'Hex SHA-256 of the base64 public key the exporting deployment signed the bundle with';
+COMMENT ON COLUMN agent_lineage.source_agent_id IS 'The agent''s ID at the exporting deployment; its attestations from there name it';
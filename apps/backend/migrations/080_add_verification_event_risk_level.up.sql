@@ -0,0 +1,73 @@
+-- Migration: Add risk level to verification events
+-- Created: 2025-11-13
+-- Purpose: Persist the risk level assessed when a verification event is recorded (from its
+--          confidence, drift, the agent's trust score and recent anomalies), so the risk level
+--          filter of the verification search queries an indexed column instead of metadata.
+
+ALTER TABLE verification_events ADD COLUMN IF NOT EXISTS risk_level VARCHAR(16) NOT NULL DEFAULT 'low';
+
+-- Backfill existing events with the same points the application assigns at ingest. Anomalies
+-- and compromise are not known for past events; a level declared in the metadata is a floor.
+WITH assessed AS (
+    SELECT id,
+        (CASE
+            WHEN trust_score < 0.3 THEN 3
+            WHEN trust_score < 0.5 THEN 2
+            WHEN trust_score < 0.7 THEN 1
+            ELSE 0
+        END)
+        + (CASE
+            WHEN confidence <= 0 THEN 0
+            WHEN confidence < 0.5 THEN 2
+            WHEN confidence < 0.8 THEN 1
+            ELSE 0
+        END)
+        + (CASE
+            WHEN (CASE WHEN jsonb_array_length(COALESCE(mcp_server_drift, '[]'::jsonb)) > 0 THEN 1 ELSE 0 END)
+               + (CASE WHEN jsonb_array_length(COALESCE(capability_drift, '[]'::jsonb)) > 0 THEN 1 ELSE 0 END)
+               + (CASE WHEN jsonb_array_length(COALESCE(peer_agent_drift, '[]'::jsonb)) > 0 THEN 1 ELSE 0 END) > 1 THEN 3
+            WHEN COALESCE(drift_detected, false)
+               OR jsonb_array_length(COALESCE(mcp_server_drift, '[]'::jsonb)) > 0
+               OR jsonb_array_length(COALESCE(capability_drift, '[]'::jsonb)) > 0
+               OR jsonb_array_length(COALESCE(peer_agent_drift, '[]'::jsonb)) > 0 THEN 2
+            ELSE 0
+        END)
+        + (CASE WHEN status = 'failed' THEN 1 ELSE 0 END) AS points,
+        LOWER(COALESCE(metadata ->> 'risk_level', metadata -> 'context' ->> 'risk_level', '')) AS declared
+    FROM verification_events
+),
+leveled AS (
+    SELECT id, declared,
+        CASE
+            WHEN points >= 5 THEN 4
+            WHEN points >= 3 THEN 3
+            WHEN points >= 1 THEN 2
+            ELSE 1
+        END AS computed,
+        CASE declared
+            WHEN 'critical' THEN 4
+            WHEN 'high' THEN 3
+            WHEN 'medium' THEN 2
+            WHEN 'low' THEN 1
+            ELSE 0
+        END AS declared_rank
+    FROM assessed
+)
+UPDATE verification_events ve
+SET risk_level = CASE GREATEST(leveled.computed, leveled.declared_rank)
+    WHEN 4 THEN 'critical'
+    WHEN 3 THEN 'high'
+    WHEN 2 THEN 'medium'
+    ELSE 'low'
+END
+FROM leveled
+WHERE ve.id = leveled.id;
+
+ALTER TABLE verification_events DROP CONSTRAINT IF EXISTS verification_events_risk_level_check;
+ALTER TABLE verification_events ADD CONSTRAINT verification_events_risk_level_check
+    CHECK (risk_level IN ('low', 'medium', 'high', 'critical'));
+
+CREATE INDEX IF NOT EXISTS idx_verification_events_org_risk_level
+    ON verification_events(organization_id, risk_level, created_at DESC);
+
+COMMENT ON COLUMN verification_events.risk_level IS 'Risk assessed at ingest from confidence, drift, trust score and recent anomalies: low, medium, high or critical';
@@ -0,0 +1,57 @@
+-- Migration: Incident management workflow
+-- Created: 2025-11-13
+-- Purpose: Let responders work security incidents: comment on them, link the threats, anomalies
+--          and verification events they cover, and follow a timeline of what happened. The
+--          acknowledgement time stops the response SLA timer of the incident's severity.
+
+ALTER TABLE security_incidents ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMPTZ;
+
+-- Incidents that already left open were acknowledged no later than their last update
+UPDATE security_incidents
+SET acknowledged_at = updated_at
+WHERE acknowledged_at IS NULL AND status <> 'open';
+
+CREATE INDEX IF NOT EXISTS idx_security_incidents_unresolved
+    ON security_incidents(created_at)
+    WHERE status IN ('open', 'investigating');
+
+CREATE TABLE IF NOT EXISTS incident_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    incident_id UUID NOT NULL REFERENCES security_incidents(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_incident_comments_incident ON incident_comments(incident_id, created_at);
+
+CREATE TABLE IF NOT EXISTS incident_links (
+    incident_id UUID NOT NULL REFERENCES security_incidents(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    resource_type VARCHAR(32) NOT NULL CHECK (resource_type IN ('threat', 'anomaly', 'verification_event')),
+    resource_id UUID NOT NULL,
+    summary TEXT NOT NULL DEFAULT '',
+    linked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (incident_id, resource_type, resource_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_incident_links_resource ON incident_links(resource_type, resource_id);
+
+CREATE TABLE IF NOT EXISTS incident_timeline_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    incident_id UUID NOT NULL REFERENCES security_incidents(id) ON DELETE CASCADE,
+    event_type VARCHAR(32) NOT NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    summary TEXT NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_incident_timeline_events_incident ON incident_timeline_events(incident_id, created_at);
+
+COMMENT ON COLUMN security_incidents.acknowledged_at IS 'When the incident first left open; stops the response SLA timer';
+COMMENT ON TABLE incident_links IS 'Threats, anomalies and verification events covered by a security incident';
+COMMENT ON TABLE incident_timeline_events IS 'What happened to a security incident: status changes, assignments, comments, links and SLA breaches';
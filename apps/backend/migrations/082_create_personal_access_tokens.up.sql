@@ -0,0 +1,28 @@
+-- Migration: Personal access tokens
+-- Created: 2025-11-13
+-- Purpose: Let users script against the API with tokens of their own, limited to named scopes and
+--          always expiring, instead of reusing interactive JWTs or full-access SDK tokens.
+
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(255) NOT NULL UNIQUE,
+    prefix VARCHAR(32) NOT NULL,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    last_used_ip VARCHAR(64),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMPTZ,
+    revoked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    revoke_reason TEXT,
+    CONSTRAINT personal_access_tokens_scopes_check CHECK (cardinality(scopes) > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user ON personal_access_tokens(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_org ON personal_access_tokens(organization_id, created_at DESC);
+
+COMMENT ON TABLE personal_access_tokens IS 'Scoped, expiring tokens users create to script against the API as themselves';
+COMMENT ON COLUMN personal_access_tokens.token_hash IS 'Base64 SHA-256 of the token; the token itself is only shown when it is created';
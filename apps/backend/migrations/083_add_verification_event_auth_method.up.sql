@@ -0,0 +1,9 @@
+-- Migration: Record how verification events were authenticated
+-- Created: 2025-11-13
+-- Purpose: Agents can authenticate with API keys, signed requests or client certificates issued
+--          by the platform CA. Record which one each verification event came in with, so audits
+--          can tell certificate-authenticated traffic apart from key-authenticated traffic.
+
+ALTER TABLE verification_events ADD COLUMN IF NOT EXISTS auth_method VARCHAR(32) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN verification_events.auth_method IS 'How the caller authenticated: jwt, api_key, ed25519, mtls or personal_access_token; empty for events recorded by the platform';
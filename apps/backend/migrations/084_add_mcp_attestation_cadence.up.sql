@@ -0,0 +1,24 @@
+-- Migration: Add MCP server criticality and attestation cadence policies
+-- Created: 2025-11-13
+-- Purpose: Tag MCP servers with a criticality level and let organizations set, per level, how
+--          fresh attestations must be and from how many independent agents. Servers out of
+--          cadence get a lower confidence score and raise an alert.
+
+ALTER TABLE mcp_servers
+    ADD COLUMN IF NOT EXISTS criticality VARCHAR(16) NOT NULL DEFAULT 'medium'
+    CHECK (criticality IN ('low', 'medium', 'high', 'critical'));
+
+CREATE INDEX IF NOT EXISTS idx_mcp_servers_criticality ON mcp_servers(organization_id, criticality);
+
+CREATE TABLE IF NOT EXISTS mcp_attestation_cadence_policies (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    criticality VARCHAR(16) NOT NULL CHECK (criticality IN ('low', 'medium', 'high', 'critical')),
+    max_attestation_age_hours INTEGER NOT NULL CHECK (max_attestation_age_hours > 0),
+    min_independent_agents INTEGER NOT NULL CHECK (min_independent_agents > 0),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, criticality)
+);
+
+COMMENT ON COLUMN mcp_servers.criticality IS 'low, medium, high or critical; selects the attestation cadence the server must keep';
+COMMENT ON TABLE mcp_attestation_cadence_policies IS 'Organization overrides of the default attestation cadence of each criticality';
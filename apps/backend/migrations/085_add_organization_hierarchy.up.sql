@@ -0,0 +1,13 @@
+-- Migration: Add organization hierarchy
+-- Created: 2025-11-13
+-- Purpose: Let enterprises run business units as sub-organizations under one tenant. Children
+--          inherit their ancestors' security policies and trust boundaries, and parent admins
+--          can view the agents and roll up the security metrics of every organization below them.
+
+ALTER TABLE organizations
+    ADD COLUMN IF NOT EXISTS parent_organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT
+    CHECK (parent_organization_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_organizations_parent ON organizations(parent_organization_id) WHERE parent_organization_id IS NOT NULL;
+
+COMMENT ON COLUMN organizations.parent_organization_id IS 'Parent of a sub-organization; set when the sub-organization is created and never changed, so the hierarchy has no cycles';
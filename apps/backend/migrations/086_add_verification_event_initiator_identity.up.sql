@@ -0,0 +1,51 @@
+-- aim:online
+-- Migration: Record the credential behind each verification event
+-- Created: 2025-11-13
+-- Purpose: Verification events only kept an initiator type and a loosely typed initiator ID.
+--          Record the user, agent and API key of the request that recorded each event, with
+--          its user agent, so investigations can pivot from an event to the exact credential that
+--          triggered it. The IDs are not foreign keys: events outlive deleted and rotated keys.
+
+ALTER TABLE verification_events
+    ADD COLUMN IF NOT EXISTS initiator_user_id UUID,
+    ADD COLUMN IF NOT EXISTS initiator_agent_id UUID,
+    ADD COLUMN IF NOT EXISTS initiator_api_key_id UUID,
+    ADD COLUMN IF NOT EXISTS initiator_user_agent TEXT;
+
+-- Past events only know their initiator ID; it is the user or agent of its initiator type
+-- aim:repeat
+UPDATE verification_events SET initiator_user_id = initiator_id
+WHERE id IN (
+    SELECT id FROM verification_events
+    WHERE initiator_type = 'user' AND initiator_id IS NOT NULL AND initiator_user_id IS NULL
+    LIMIT 10000
+);
+
+-- aim:repeat
+UPDATE verification_events SET initiator_agent_id = initiator_id
+WHERE id IN (
+    SELECT id FROM verification_events
+    WHERE initiator_type = 'agent' AND initiator_id IS NOT NULL AND initiator_agent_id IS NULL
+    LIMIT 10000
+);
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_verification_events_org_initiator_user
+    ON verification_events(organization_id, initiator_user_id, created_at DESC)
+    WHERE initiator_user_id IS NOT NULL;
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_verification_events_org_initiator_agent
+    ON verification_events(organization_id, initiator_agent_id, created_at DESC)
+    WHERE initiator_agent_id IS NOT NULL;
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_verification_events_org_initiator_api_key
+    ON verification_events(organization_id, initiator_api_key_id, created_at DESC)
+    WHERE initiator_api_key_id IS NOT NULL;
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_verification_events_org_initiator_ip
+    ON verification_events(organization_id, initiator_ip, created_at DESC)
+    WHERE initiator_ip IS NOT NULL;
+
+COMMENT ON COLUMN verification_events.initiator_user_id IS 'User the request that recorded the event acted as (JWT, personal access token, or the owner of an API key or agent certificate)';
+COMMENT ON COLUMN verification_events.initiator_agent_id IS 'Agent whose credential recorded the event (API key, signed request or agent certificate)';
+COMMENT ON COLUMN verification_events.initiator_api_key_id IS 'API key that authenticated the request that recorded the event';
+COMMENT ON COLUMN verification_events.initiator_user_agent IS 'User-Agent header of the request that recorded the event';
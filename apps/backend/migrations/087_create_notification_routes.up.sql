@@ -0,0 +1,34 @@
+-- Migration: Create notification routes and digest deliveries
+-- Created: 2025-11-13
+-- Purpose: Route alerts to notification channels by alert type, severity and the tags of the
+--          alert's agent or MCP server, so high-severity alerts page on-call while low-severity
+--          ones are batched into periodic digests
+
+CREATE TABLE IF NOT EXISTS notification_routes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    alert_types TEXT[] NOT NULL DEFAULT '{}',     -- Empty array matches every alert type
+    min_severity VARCHAR(20) NOT NULL DEFAULT 'info',
+    max_severity VARCHAR(20) NOT NULL DEFAULT '', -- Empty has no upper bound
+    resource_tags TEXT[] NOT NULL DEFAULT '{}',   -- Any of these key:value tags; empty matches every resource
+    channel_ids UUID[] NOT NULL DEFAULT '{}',
+    digest_interval_minutes INTEGER NOT NULL DEFAULT 0, -- 0 delivers each alert right away
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT notification_routes_name_unique_per_org UNIQUE (organization_id, name),
+    CONSTRAINT notification_routes_channels_not_empty CHECK (array_length(channel_ids, 1) > 0),
+    CONSTRAINT notification_routes_digest_interval_check CHECK (digest_interval_minutes >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_routes_organization_id ON notification_routes(organization_id);
+
+ALTER TABLE notification_deliveries
+    ADD COLUMN IF NOT EXISTS digest BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON TABLE notification_routes IS 'Alert routing rules: alert type, severity range and resource tags to notification channels';
+COMMENT ON COLUMN notification_routes.digest_interval_minutes IS 'Batch matching alerts into one message per recipient every so many minutes; 0 sends each alert right away';
+COMMENT ON COLUMN notification_deliveries.digest IS 'Held until next_attempt_at, the end of its digest window, and sent together with the recipient''s other due digest deliveries';
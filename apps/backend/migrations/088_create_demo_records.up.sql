@@ -0,0 +1,19 @@
+-- Migration: Create demo records
+-- Created: 2025-11-13
+-- Purpose: Track the agents, MCP servers and incidents the demo data generator seeds into an
+--          organization, so teardown removes exactly the demo data and nothing else
+
+CREATE TABLE IF NOT EXISTS demo_records (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    resource_type VARCHAR(50) NOT NULL, -- agent, mcp_server, incident
+    resource_id UUID NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT demo_records_resource_unique UNIQUE (resource_type, resource_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_demo_records_organization_id ON demo_records(organization_id);
+
+COMMENT ON TABLE demo_records IS 'Resources created by the demo data generator; attestations, events, alerts and trust history hang off the recorded agents and MCP servers';
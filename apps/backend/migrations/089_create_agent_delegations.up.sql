@@ -0,0 +1,30 @@
+-- Migration: Create agent delegations
+-- Created: 2025-11-13
+-- Purpose: Agents can spawn sub-agents with a scoped identity. Each delegation links a child agent
+--          to its parent, records the subset of the parent's capabilities it was given, and is
+--          revoked along with the parent.
+
+CREATE TABLE IF NOT EXISTS agent_delegations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    parent_agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    child_agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    depth INTEGER NOT NULL CHECK (depth > 0),
+    capabilities TEXT[] NOT NULL DEFAULT '{}',
+    talks_to TEXT[] NOT NULL DEFAULT '{}',
+    purpose TEXT,
+    inherited_trust DECIMAL(5,3) NOT NULL,
+    requested_by VARCHAR(50) NOT NULL, -- agent, user
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMPTZ,
+    revocation_reason TEXT,
+
+    CONSTRAINT agent_delegations_child_unique UNIQUE (child_agent_id),
+    CONSTRAINT agent_delegations_not_self CHECK (parent_agent_id <> child_agent_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_delegations_parent ON agent_delegations(parent_agent_id, created_at);
+
+COMMENT ON COLUMN agent_delegations.depth IS '1 for a child of an agent that was not delegated itself';
+COMMENT ON COLUMN agent_delegations.inherited_trust IS 'The parent''s trust score minus the delegation penalty, given to the child at delegation';
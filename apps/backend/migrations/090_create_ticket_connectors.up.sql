@@ -0,0 +1,54 @@
+-- Migration: Create ticket connectors
+-- Created: 2025-11-13
+-- Purpose: Organizations connect their Jira or ServiceNow. Connectors open a ticket for every new
+--          capability request and incident, and ticket links track those tickets so their status
+--          and comments can be synced back.
+
+CREATE TABLE IF NOT EXISTS ticket_connectors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    provider VARCHAR(50) NOT NULL, -- jira, servicenow
+    base_url TEXT NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    encrypted_api_token TEXT NOT NULL,
+    project VARCHAR(255) NOT NULL DEFAULT '',
+    issue_type VARCHAR(100) NOT NULL DEFAULT '',
+    resource_types TEXT[] NOT NULL DEFAULT '{}',
+    approved_statuses TEXT[] NOT NULL DEFAULT '{}',
+    rejected_statuses TEXT[] NOT NULL DEFAULT '{}',
+    incident_statuses JSONB NOT NULL DEFAULT '{}',
+    webhook_secret_hash VARCHAR(64) NOT NULL,
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_connectors_org ON ticket_connectors(organization_id, created_at);
+
+CREATE TABLE IF NOT EXISTS ticket_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    connector_id UUID NOT NULL REFERENCES ticket_connectors(id) ON DELETE CASCADE,
+    resource_type VARCHAR(50) NOT NULL, -- capability_request, incident
+    resource_id UUID NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    ticket_key VARCHAR(255) NOT NULL,
+    ticket_id VARCHAR(255),
+    ticket_url TEXT NOT NULL,
+    ticket_status VARCHAR(255),
+    synced_comment_ids TEXT[] NOT NULL DEFAULT '{}',
+    closed BOOLEAN NOT NULL DEFAULT FALSE,
+    last_synced_at TIMESTAMPTZ,
+    last_sync_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT ticket_links_ticket_unique UNIQUE (connector_id, ticket_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_links_resource ON ticket_links(resource_type, resource_id);
+CREATE INDEX IF NOT EXISTS idx_ticket_links_open ON ticket_links(last_synced_at NULLS FIRST) WHERE NOT closed;
+
+COMMENT ON COLUMN ticket_connectors.created_by IS 'Ticket-driven approvals and incident changes act as this user';
+COMMENT ON COLUMN ticket_links.synced_comment_ids IS 'Ticket comments already copied to the resource';
@@ -0,0 +1,63 @@
+-- Migration: Create platform operator tables
+-- Created: 2025-11-13
+-- Purpose: The team hosting the deployment works through a separate operator API. Operators
+--          authenticate with their own tokens, every change they make is logged, throttles cap
+--          the rate limits of abusive organizations, and impersonation requests record an
+--          organization admin's consent before an operator can act in the organization.
+
+CREATE TABLE IF NOT EXISTS platform_operators (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL, -- viewer, support, admin
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    token_prefix VARCHAR(20) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_used_at TIMESTAMPTZ,
+    created_by UUID REFERENCES platform_operators(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Operator log; kept when the organization is deleted
+CREATE TABLE IF NOT EXISTS operator_actions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    operator_id UUID NOT NULL REFERENCES platform_operators(id),
+    action VARCHAR(100) NOT NULL,
+    organization_id UUID,
+    details JSONB NOT NULL DEFAULT '{}',
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_operator_actions_created ON operator_actions(created_at DESC);
+
+CREATE TABLE IF NOT EXISTS organization_throttles (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    requests_per_minute INTEGER NOT NULL CHECK (requests_per_minute > 0),
+    reason TEXT NOT NULL,
+    expires_at TIMESTAMPTZ,
+    created_by UUID NOT NULL REFERENCES platform_operators(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS impersonation_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    operator_id UUID NOT NULL REFERENCES platform_operators(id),
+    operator_email VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    duration_minutes INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, approved, active, denied, revoked, ended
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ,
+    token_hash VARCHAR(64) UNIQUE,
+    started_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    ended_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonation_requests_org ON impersonation_requests(organization_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_impersonation_requests_created ON impersonation_requests(created_at DESC);
@@ -0,0 +1,31 @@
+-- Migration: Create SIEM exporters
+-- Created: 2025-11-13
+-- Purpose: Organizations stream threats, anomalies, alerts and verification failures to their
+--          SIEM (Splunk, QRadar, ...) as RFC 5424 syslog messages in CEF or LEEF format.
+--          Events are buffered in memory by the instance that records them; the counters
+--          record what was delivered and what was dropped while the SIEM was unreachable.
+
+CREATE TABLE IF NOT EXISTS siem_exporters (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    host VARCHAR(255) NOT NULL,
+    port INTEGER NOT NULL CHECK (port BETWEEN 1 AND 65535),
+    transport VARCHAR(10) NOT NULL, -- udp, tcp, tls
+    format VARCHAR(10) NOT NULL,    -- cef, leef
+    facility INTEGER NOT NULL DEFAULT 10 CHECK (facility BETWEEN 0 AND 23),
+    ca_certificate TEXT NOT NULL DEFAULT '',
+    event_types TEXT[] NOT NULL DEFAULT '{}', -- Empty streams every category
+    min_severity VARCHAR(20) NOT NULL DEFAULT 'info',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    delivered_count BIGINT NOT NULL DEFAULT 0,
+    dropped_count BIGINT NOT NULL DEFAULT 0,
+    last_delivered_at TIMESTAMPTZ,
+    last_error TEXT,
+    last_error_at TIMESTAMPTZ,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_siem_exporters_org ON siem_exporters(organization_id, is_active);
@@ -0,0 +1,26 @@
+-- Migration: Create naming policies
+-- Created: 2025-11-13
+-- Purpose: Organizations constrain the names of their agents and MCP servers with patterns,
+--          required prefixes per environment or team tag, reserved names and uniqueness
+--          ignoring case and separators. Policies are checked on registration, rename and
+--          tagging.
+
+CREATE TABLE IF NOT EXISTS naming_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    resource_type VARCHAR(20) NOT NULL DEFAULT '', -- agent, mcp_server; empty applies to both
+    tag_key VARCHAR(100) NOT NULL DEFAULT '',      -- Empty applies to every resource
+    tag_value VARCHAR(255) NOT NULL DEFAULT '',
+    pattern TEXT NOT NULL DEFAULT '',
+    required_prefix VARCHAR(255) NOT NULL DEFAULT '',
+    reserved_names TEXT[] NOT NULL DEFAULT '{}',
+    normalized_unique BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_naming_policies_org ON naming_policies(organization_id, is_active);
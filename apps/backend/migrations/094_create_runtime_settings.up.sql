@@ -0,0 +1,26 @@
+-- Migration: Create runtime settings
+-- Created: 2025-11-13
+-- Purpose: Platform operators tune operational knobs (rate limits, job intervals, cache TTLs,
+--          feature toggles, log level) without a redeploy. Every instance polls the stored
+--          values and applies them; each change is kept with its previous value and reason.
+
+CREATE TABLE IF NOT EXISTS runtime_settings (
+    key VARCHAR(255) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_by UUID REFERENCES platform_operators(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS runtime_setting_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    key VARCHAR(255) NOT NULL,
+    previous_value TEXT, -- NULL when the startup value was in effect
+    value TEXT,          -- NULL when the setting was reset to the startup value
+    reason TEXT NOT NULL DEFAULT '',
+    changed_by UUID REFERENCES platform_operators(id) ON DELETE SET NULL,
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_runtime_setting_changes_key ON runtime_setting_changes(key, changed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_runtime_setting_changes_changed ON runtime_setting_changes(changed_at DESC);
@@ -0,0 +1,16 @@
+-- Migration: Key algorithm agility for agent keys
+-- Created: 2025-11-13
+-- Purpose: Agents sign with Ed25519, ECDSA P-256 or RSA-PSS keys. The previous key keeps the
+--          algorithm it was registered with during the rotation grace period, so agents can
+--          switch algorithms by rotating. Organizations restrict the allowed algorithms with the
+--          allowedKeyAlgorithms setting.
+
+-- Until now every key was Ed25519, whatever the column said (it defaulted to RSA-4096)
+UPDATE agents SET key_algorithm = 'Ed25519'
+WHERE key_algorithm IS NULL OR key_algorithm NOT IN ('Ed25519', 'ECDSA-P256', 'RSA-PSS');
+
+ALTER TABLE agents ALTER COLUMN key_algorithm SET DEFAULT 'Ed25519';
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS previous_key_algorithm VARCHAR(50);
+
+COMMENT ON COLUMN agents.key_algorithm IS 'Algorithm of public_key: Ed25519, ECDSA-P256 or RSA-PSS';
+COMMENT ON COLUMN agents.previous_key_algorithm IS 'Algorithm of previous_public_key, accepted until key_rotation_grace_until';
@@ -0,0 +1,20 @@
+-- Migration: Time-bound capabilities
+-- Created: 2025-11-13
+-- Purpose: Capabilities can be granted until an expiry time. A background job revokes expired
+--          capabilities and notifies the agent owners; renewal requests extend the expiry of an
+--          existing capability and keep the justification of the original request.
+
+ALTER TABLE agent_capabilities ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+-- The expiry job only looks at capabilities that are still granted and have an expiry
+CREATE INDEX IF NOT EXISTS idx_agent_capabilities_expires_at
+    ON agent_capabilities(expires_at)
+    WHERE revoked_at IS NULL AND expires_at IS NOT NULL;
+
+ALTER TABLE capability_requests ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+ALTER TABLE capability_requests ADD COLUMN IF NOT EXISTS renews_capability_id UUID
+    REFERENCES agent_capabilities(id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN agent_capabilities.expires_at IS 'When the capability expires; NULL for capabilities that never expire';
+COMMENT ON COLUMN capability_requests.expires_at IS 'Expiry of the capability granted on approval; NULL grants it permanently';
+COMMENT ON COLUMN capability_requests.renews_capability_id IS 'For renewal requests, the capability whose expiry is extended on approval';
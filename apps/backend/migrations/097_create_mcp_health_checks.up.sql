@@ -0,0 +1,33 @@
+-- Migration: MCP server health checks
+-- Created: 2025-11-13
+-- Purpose: A background job probes the URL of every MCP server. Each probe is kept for 30 days of
+--          uptime history, and the health state tracks consecutive failures so a server that
+--          keeps failing is suspended and restored to its previous status once it recovers.
+
+CREATE TABLE IF NOT EXISTS mcp_health_checks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    mcp_server_id UUID NOT NULL REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    healthy BOOLEAN NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    latency_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+    error TEXT,
+    checked_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_mcp_health_checks_server ON mcp_health_checks(mcp_server_id, checked_at DESC);
+CREATE INDEX IF NOT EXISTS idx_mcp_health_checks_checked_at ON mcp_health_checks(checked_at);
+
+CREATE TABLE IF NOT EXISTS mcp_server_health (
+    mcp_server_id UUID PRIMARY KEY REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    state VARCHAR(20) NOT NULL DEFAULT 'unknown', -- unknown, healthy, unhealthy
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    last_checked_at TIMESTAMPTZ,
+    last_healthy_at TIMESTAMPTZ,
+    last_error TEXT,
+    downgraded_at TIMESTAMPTZ,
+    downgraded_from VARCHAR(50),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN mcp_health_checks.status_code IS 'HTTP status of the probe; 0 when the server could not be reached';
+COMMENT ON COLUMN mcp_server_health.downgraded_from IS 'Status the server had before sustained failures suspended it; restored on recovery';
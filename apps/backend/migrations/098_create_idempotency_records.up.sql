@@ -0,0 +1,26 @@
+-- Migration: Idempotency keys
+-- Created: 2025-11-13
+-- Purpose: Mutating requests sent with an Idempotency-Key header are recorded with their response.
+--          A retry with the same key, endpoint and body within the replay window gets the original
+--          response instead of creating a duplicate verification event, attestation or request.
+
+CREATE TABLE IF NOT EXISTS idempotency_records (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL,
+    endpoint TEXT NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    response_body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+
+    CONSTRAINT idempotency_records_key_unique UNIQUE (organization_id, idempotency_key, endpoint)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_records_expires_at ON idempotency_records(expires_at);
+
+COMMENT ON COLUMN idempotency_records.endpoint IS 'Method and path of the request; keys are scoped to it';
+COMMENT ON COLUMN idempotency_records.expires_at IS 'Lock timeout while the request runs, end of the replay window once it completed';
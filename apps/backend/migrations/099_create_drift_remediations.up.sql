@@ -0,0 +1,27 @@
+-- Migration: Drift remediations
+-- Created: 2025-11-13
+-- Purpose: A configuration drift alert links to a proposed change set: the MCP servers the agent talked
+--          to without registering them. Admins approve it to add them to the agent's talks_to, or reject
+--          it to enforce the organization's config_drift policy against the agent.
+
+CREATE TABLE IF NOT EXISTS drift_remediations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    alert_id UUID NOT NULL REFERENCES alerts(id) ON DELETE CASCADE,
+    mcp_servers JSONB NOT NULL DEFAULT '[]'::jsonb,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    enforcement_action VARCHAR(50) NOT NULL DEFAULT '',
+    policy_name VARCHAR(255) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT drift_remediations_alert_unique UNIQUE (alert_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_drift_remediations_org_status ON drift_remediations(organization_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_drift_remediations_agent ON drift_remediations(agent_id);
+
+COMMENT ON COLUMN drift_remediations.enforcement_action IS 'Config drift enforcement action applied when the change set was rejected';
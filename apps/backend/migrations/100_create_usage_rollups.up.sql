@@ -0,0 +1,29 @@
+-- Migration: Usage rollups
+-- Created: 2025-11-13
+-- Purpose: The usage aggregator job rolls up the verifications of each agent and API key per day,
+--          by outcome and by MCP server contacted, so the usage analytics endpoints don't scan the
+--          verification events. Owners use them to spot abandoned keys and runaway agents.
+
+CREATE TABLE IF NOT EXISTS usage_rollups (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    subject_type VARCHAR(20) NOT NULL CHECK (subject_type IN ('agent', 'api_key')),
+    subject_id UUID NOT NULL,
+    day DATE NOT NULL,
+    requests INTEGER NOT NULL DEFAULT 0,
+    successes INTEGER NOT NULL DEFAULT 0,
+    failures INTEGER NOT NULL DEFAULT 0,
+    pending INTEGER NOT NULL DEFAULT 0,
+    timeouts INTEGER NOT NULL DEFAULT 0,
+    errors INTEGER NOT NULL DEFAULT 0,
+    mcp_servers JSONB NOT NULL DEFAULT '{}'::jsonb,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (subject_type, subject_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_rollups_org_subject_day ON usage_rollups(organization_id, subject_type, day);
+CREATE INDEX IF NOT EXISTS idx_usage_rollups_day ON usage_rollups(day);
+
+COMMENT ON COLUMN usage_rollups.subject_id IS 'Agent or API key; not a foreign key so usage outlives deleted keys';
+COMMENT ON COLUMN usage_rollups.errors IS 'Verifications recorded with an error code';
+COMMENT ON COLUMN usage_rollups.mcp_servers IS 'Verifications per MCP server reported at runtime';
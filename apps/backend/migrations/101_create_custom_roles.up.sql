@@ -0,0 +1,31 @@
+-- Migration: Custom roles
+-- Created: 2025-11-13
+-- Purpose: Organizations define roles as sets of granular permissions (e.g. approve capability requests
+--          without managing users) and assign them to users. Users keep their built-in role and gain
+--          the permissions of every custom role assigned to them.
+
+CREATE TABLE IF NOT EXISTS custom_roles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    permissions JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT custom_roles_org_name_unique UNIQUE (organization_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS custom_role_assignments (
+    role_id UUID NOT NULL REFERENCES custom_roles(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (role_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_custom_role_assignments_user ON custom_role_assignments(user_id);
+
+COMMENT ON COLUMN custom_roles.permissions IS 'Permissions of the catalog the role grants, e.g. ["capability_requests:approve"]';
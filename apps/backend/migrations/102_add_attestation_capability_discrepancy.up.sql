@@ -0,0 +1,10 @@
+-- Migration: Attestation capability discrepancies
+-- Created: 2025-11-13
+-- Purpose: Record on each attestation how the capabilities it found differ from the MCP server's
+--          registered and discovered capabilities (extra and missing tools). Servers whose attestors
+--          consistently report the same discrepancies get a lower confidence score.
+
+ALTER TABLE mcp_attestations
+    ADD COLUMN IF NOT EXISTS capability_discrepancy JSONB;
+
+COMMENT ON COLUMN mcp_attestations.capability_discrepancy IS 'Capabilities found vs the server''s when recorded: {"unexposed": [...], "missing": [...]}; NULL when not compared';
@@ -0,0 +1,30 @@
+-- Migration: Domain event outbox
+-- Created: 2025-11-13
+-- Purpose: Verification, drift, trust score and alert events are written to an outbox as they happen
+--          and relayed from there to NATS or Kafka, so a broker outage delays events instead of losing
+--          them and consumers (notifications, analytics, SIEM) can scale apart from the API.
+
+CREATE TABLE IF NOT EXISTS domain_event_outbox (
+    id UUID PRIMARY KEY,
+    sequence BIGSERIAL NOT NULL,
+    organization_id UUID NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'publishing', 'published')),
+    attempt_count INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    published_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_domain_event_outbox_due
+    ON domain_event_outbox(next_attempt_at, sequence) WHERE status IN ('pending', 'publishing');
+CREATE INDEX IF NOT EXISTS idx_domain_event_outbox_published_at
+    ON domain_event_outbox(published_at) WHERE status = 'published';
+
+COMMENT ON COLUMN domain_event_outbox.sequence IS 'Order events are relayed in';
+COMMENT ON COLUMN domain_event_outbox.next_attempt_at IS 'When a pending event is due, or when the lease of a claimed event runs out';
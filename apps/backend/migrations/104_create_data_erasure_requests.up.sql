@@ -0,0 +1,30 @@
+-- Migration: Data erasure requests
+-- Created: 2025-11-14
+-- Purpose: Users, or admins on their behalf, request the erasure of their personal data (GDPR Art. 17).
+--          Another admin approves the request; erasure then anonymizes the user and the records that
+--          refer to them, and the completion report is kept on the request.
+
+CREATE TABLE IF NOT EXISTS data_erasure_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    -- The anonymized user row is kept, so the request keeps pointing at it
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'completed', 'rejected')),
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ,
+    decision_reason TEXT NOT NULL DEFAULT '',
+    report JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ
+);
+
+-- One open (pending or approved) request per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_erasure_requests_open_user
+    ON data_erasure_requests(user_id) WHERE status IN ('pending', 'approved');
+CREATE INDEX IF NOT EXISTS idx_data_erasure_requests_org_status
+    ON data_erasure_requests(organization_id, status, created_at DESC);
+
+COMMENT ON COLUMN data_erasure_requests.report IS 'Completion report: alias, revoked tokens and anonymized records by table';
@@ -0,0 +1,29 @@
+-- Migration: Organization invitations
+-- Created: 2025-11-17
+-- Purpose: Admins invite an email address to their organization with a role. The invitee gets a
+--          signed link that expires; accepting it creates their account. The link only carries
+--          the invitation's ID, so revoking the invitation here stops the link from working.
+
+CREATE TABLE IF NOT EXISTS invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL CHECK (role IN ('admin', 'manager', 'member', 'viewer')),
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    -- Expiry is not stored: pending invitations past expires_at are reported as expired
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'revoked')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    accepted_at TIMESTAMPTZ,
+    revoked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_invitations_org_status
+    ON invitations(organization_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_invitations_org_email
+    ON invitations(organization_id, LOWER(email));
+
+COMMENT ON COLUMN invitations.accepted_by IS 'The user created when the invitation was accepted';
@@ -0,0 +1,47 @@
+-- Migration: Trust score triggers
+-- Created: 2025-11-18
+-- Purpose: Admin-defined triggers such as "if trust score falls below 0.5, suspend the agent and
+--          notify". A trigger fires when a stored trust score crosses its threshold in the chosen
+--          direction and runs its actions in order (alert, webhook, suspend_agent,
+--          revoke_api_keys, open_incident). Every firing is recorded with the outcome of each action.
+
+CREATE TABLE IF NOT EXISTS trust_score_triggers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    condition VARCHAR(10) NOT NULL CHECK (condition IN ('below', 'above')),
+    threshold DOUBLE PRECISION NOT NULL CHECK (threshold >= 0 AND threshold <= 1),
+    actions JSONB NOT NULL DEFAULT '[]',
+    severity VARCHAR(20) NOT NULL DEFAULT 'high',
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_trust_score_triggers_org ON trust_score_triggers(organization_id);
+
+CREATE TABLE IF NOT EXISTS trust_score_trigger_firings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    trigger_id UUID NOT NULL,
+    trigger_name VARCHAR(255) NOT NULL,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    agent_name VARCHAR(255) NOT NULL,
+    condition VARCHAR(10) NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    previous_score DOUBLE PRECISION NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    alert_id UUID,
+    incident_id UUID,
+    actions JSONB NOT NULL DEFAULT '[]',
+    fired_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_trust_score_trigger_firings_org ON trust_score_trigger_firings(organization_id, fired_at DESC);
+CREATE INDEX IF NOT EXISTS idx_trust_score_trigger_firings_trigger ON trust_score_trigger_firings(trigger_id, fired_at DESC);
+
+COMMENT ON TABLE trust_score_triggers IS 'Actions run when an agent''s trust score crosses a threshold';
+COMMENT ON COLUMN trust_score_trigger_firings.trigger_id IS 'No foreign key: firings outlive deleted triggers';
+COMMENT ON COLUMN trust_score_trigger_firings.actions IS 'Outcome of each action of the trigger';
@@ -0,0 +1,28 @@
+-- Migration: MCP tool grants
+-- Created: 2025-11-18
+-- Purpose: Tool-level authorization. talks_to grants an agent a whole MCP server; a tool grant
+--          narrows that to the listed tools. Verification requests report the tools called as
+--          "server:tool", and calls outside the granted set are recorded as tool drift.
+
+CREATE TABLE IF NOT EXISTS mcp_tool_grants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    mcp_server VARCHAR(255) NOT NULL,
+    tools TEXT[] NOT NULL DEFAULT '{}',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (agent_id, mcp_server)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mcp_tool_grants_org ON mcp_tool_grants(organization_id);
+
+COMMENT ON COLUMN mcp_tool_grants.mcp_server IS 'The talks_to entry (MCP server ID or name) the grant narrows';
+COMMENT ON COLUMN mcp_tool_grants.tools IS 'Tools the agent may call on the server; servers without a grant allow every tool';
+
+ALTER TABLE verification_events ADD COLUMN IF NOT EXISTS current_mcp_tools JSONB DEFAULT '[]'::jsonb;
+ALTER TABLE verification_events ADD COLUMN IF NOT EXISTS mcp_tool_drift JSONB DEFAULT '[]'::jsonb;
+
+COMMENT ON COLUMN verification_events.current_mcp_tools IS 'JSONB array of MCP tools called at runtime, as server:tool';
+COMMENT ON COLUMN verification_events.mcp_tool_drift IS 'JSONB array of MCP tools called outside the agent''s tool grants';
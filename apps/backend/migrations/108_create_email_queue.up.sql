@@ -0,0 +1,55 @@
+-- Migration: Email send queue, suppression list and organization senders
+-- Created: 2025-11-19
+-- Purpose: Transactional mail (password resets, approvals, capability decisions, alert digests)
+--          is queued and sent in the background with retries. Providers report bounces and
+--          complaints through a webhook; hard bounced addresses are suppressed. Organizations
+--          can send from their own address.
+
+CREATE TABLE IF NOT EXISTS email_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    to_address VARCHAR(320) NOT NULL,
+    from_address VARCHAR(320),
+    from_name VARCHAR(255),
+    reply_to VARCHAR(320),
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    is_html BOOLEAN NOT NULL DEFAULT TRUE,
+    template VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'queued'
+        CHECK (status IN ('queued', 'sending', 'sent', 'failed', 'bounced', 'suppressed')),
+    attempt_count INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    sent_at TIMESTAMPTZ,
+    bounced_at TIMESTAMPTZ,
+    bounce_reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_messages_due
+    ON email_messages(next_attempt_at, created_at) WHERE status IN ('queued', 'sending');
+CREATE INDEX IF NOT EXISTS idx_email_messages_org ON email_messages(organization_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_email_messages_to_sent
+    ON email_messages(LOWER(to_address), sent_at DESC) WHERE status = 'sent';
+
+COMMENT ON COLUMN email_messages.organization_id IS 'Organization the mail is sent on behalf of; NULL for platform mail';
+COMMENT ON COLUMN email_messages.from_address IS 'Organization sender fixed when queued; NULL sends from EMAIL_FROM_ADDRESS';
+
+CREATE TABLE IF NOT EXISTS email_suppressions (
+    address VARCHAR(320) PRIMARY KEY,
+    bounce_type VARCHAR(20) NOT NULL CHECK (bounce_type IN ('hard', 'complaint')),
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN email_suppressions.address IS 'Lowercased address no more mail is sent to';
+
+CREATE TABLE IF NOT EXISTS organization_email_senders (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    from_address VARCHAR(320) NOT NULL,
+    from_name VARCHAR(255) NOT NULL DEFAULT '',
+    reply_to VARCHAR(320),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,25 @@
+-- Migration: Login attempts
+-- Created: 2025-11-20
+-- Purpose: Password logins are recorded per email and IP address. Repeated failures back off
+--          exponentially, escalate to a CAPTCHA, and lock the account or block the address for
+--          a while; reaching a lockout opens a brute_force security threat.
+
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(320) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('succeeded', 'failed', 'blocked', 'unlocked')),
+    account_locked_until TIMESTAMPTZ,
+    address_locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_email ON login_attempts(email, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip ON login_attempts(ip_address, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_org ON login_attempts(organization_id, created_at DESC);
+
+COMMENT ON COLUMN login_attempts.email IS 'Lowercased email the login was for; attempts to unknown emails are recorded too';
+COMMENT ON COLUMN login_attempts.account_locked_until IS 'Set on the failure that locked the email';
+COMMENT ON COLUMN login_attempts.address_locked_until IS 'Set on the failure that blocked the IP address';
@@ -0,0 +1,23 @@
+-- Migration: Organization data residency
+-- Created: 2025-11-21
+-- Purpose: An organization can be resident in a region (us, eu) with its own database cluster.
+--          Its agents, verification events and MCP attestations are stored in that cluster; the
+--          control plane keeps the organizations and records where each regional record was
+--          placed, so lookups by ID are routed without asking every region.
+
+ALTER TABLE organizations
+    ADD COLUMN IF NOT EXISTS data_residency VARCHAR(8) CHECK (data_residency IN ('us', 'eu'));
+
+COMMENT ON COLUMN organizations.data_residency IS 'Region storing the organization''s agents, verification events and MCP attestations; NULL for the region of the control plane''s database';
+
+CREATE TABLE IF NOT EXISTS data_placements (
+    resource_id UUID PRIMARY KEY,
+    kind VARCHAR(32) NOT NULL CHECK (kind IN ('agent', 'verification_event', 'mcp_attestation')),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    region VARCHAR(8) NOT NULL CHECK (region IN ('us', 'eu')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_placements_org ON data_placements(organization_id, kind);
+
+COMMENT ON TABLE data_placements IS 'Region of each agent, verification event and MCP attestation stored through the region router; records without a placement are in the control plane''s region';
@@ -0,0 +1,6 @@
+-- Migration: Keys without scopes carry the legacy SDK scopes
+-- Created: 2025-11-21
+-- Purpose: An API key with an empty scope list no longer has full access. It carries what the SDK
+--          used keys for before scoping: verify:* and agents:write (one-line agent registration).
+
+COMMENT ON COLUMN api_keys.scopes IS 'Scopes granted to the key; empty means the legacy SDK scopes (verify:*, agents:write)';
@@ -2,6 +2,8 @@
 -- Description: Track WHO changed the trust score for complete audit trail
 -- Required for frontend Trust Score History UI
 -- Created: 2025-10-22
+-- Note: Shipped as 032 alongside 032_sync_trust_scores; golang-migrate needs unique versions.
+--       It only adds what is missing, so databases that applied it as 032 apply it again safely.
 
 -- Add changed_by column to track the user who triggered the change
 ALTER TABLE trust_score_history
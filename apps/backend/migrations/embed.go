@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL schema migrations, so the server and cmd/migrate ship the
+// migrations they were built with
+package migrations
+
+import "embed"
+
+// Files holds the migrations, named {version}_{title}.up.sql as golang-migrate expects
+//
+//go:embed *.up.sql
+var Files embed.FS
@@ -18,10 +18,10 @@ echo ""
 
 # Critical migrations that must exist
 CRITICAL_MIGRATIONS=(
-    "001_initial_schema.sql"
-    "028_add_factors_column_to_trust_scores.sql"
-    "029_set_default_values_for_trust_score_factors.sql"
-    "030_fix_agents_trust_score_scale.sql"
+    "001_initial_schema.up.sql"
+    "028_add_factors_column_to_trust_scores.up.sql"
+    "029_set_default_values_for_trust_score_factors.up.sql"
+    "030_fix_agents_trust_score_scale.up.sql"
 )
 
 MISSING=0
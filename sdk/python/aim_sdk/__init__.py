@@ -56,7 +56,7 @@ Agent Management (Generic SDK Pattern):
     client.delete_agent(agent_id)
 """
 
-from .client import AIMClient, register_agent
+from .client import AIMClient, register_agent, generate_agent_keypair, proof_of_possession
 
 # Alias for enterprise security
 secure = register_agent
@@ -70,6 +70,8 @@ __version__ = "1.0.0"
 __all__ = [
     "AIMClient",
     "register_agent",
+    "generate_agent_keypair",
+    "proof_of_possession",
     "secure",
     "AIMError",
     "AuthenticationError",
@@ -24,6 +24,18 @@ from .oauth import OAuthTokenManager, load_sdk_credentials
 from .capability_detection import auto_detect_capabilities
 
 
+def _inject_trace_context(headers: Dict[str, str]) -> None:
+    """
+    Add W3C traceparent/tracestate headers for the current OpenTelemetry span, so AIM's
+    verification spans join the agent's trace. Does nothing unless opentelemetry-api is installed.
+    """
+    try:
+        from opentelemetry import propagate
+    except ImportError:
+        return
+    propagate.inject(headers)
+
+
 class AIMClient:
     """
     AIM SDK Client for automatic identity verification.
@@ -237,6 +249,7 @@ class AIMClient:
 
         # Merge session headers with additional headers (additional_headers take precedence)
         merged_headers = {**self.session.headers, **additional_headers}
+        _inject_trace_context(merged_headers)
 
         try:
             # CRITICAL: If we have pre-serialized JSON (for Ed25519 signing), use it directly
@@ -1072,18 +1085,8 @@ class AIMClient:
         if not name or not isinstance(name, str):
             raise ConfigurationError("name is required and must be a non-empty string")
 
-        # Generate Ed25519 keypair for the new agent
-        from nacl.signing import SigningKey
-        from nacl.encoding import Base64Encoder
-
-        signing_key = SigningKey.generate()
-        private_key_bytes = bytes(signing_key)  # 32-byte seed
-        public_key_bytes = signing_key.verify_key.encode()  # 32-byte public key
-
-        # For Go compatibility, create 64-byte private key (seed + public key)
-        private_key_full = private_key_bytes + public_key_bytes
-        private_key_b64 = base64.b64encode(private_key_full).decode('utf-8')
-        public_key_b64 = base64.b64encode(public_key_bytes).decode('utf-8')
+        # Generate Ed25519 keypair for the new agent; only the public key is sent
+        signing_key, public_key_b64, private_key_b64 = generate_agent_keypair()
 
         # Prepare registration payload
         registration_data = {
@@ -1091,7 +1094,8 @@ class AIMClient:
             "displayName": display_name or name,
             "description": description or f"Agent {name} created via AIM SDK",
             "agentType": agent_type,
-            "publicKey": public_key_b64
+            "publicKey": public_key_b64,
+            "proofOfPossession": proof_of_possession(signing_key, name, public_key_b64)
         }
 
         if version:
@@ -1743,6 +1747,38 @@ import os
 import pathlib
 
 
+def generate_agent_keypair():
+    """
+    Generate an agent's Ed25519 key pair client-side.
+
+    The private key never leaves the client: registration sends only the public key and a
+    proof_of_possession signature, so AIM holds no copy of it.
+
+    Returns:
+        (signing_key, public_key_b64, private_key_b64); the private key is the 64-byte
+        seed + public key form used by Go
+    """
+    signing_key = SigningKey.generate()
+    public_key_bytes = bytes(signing_key.verify_key)
+    private_key_b64 = base64.b64encode(bytes(signing_key) + public_key_bytes).decode('utf-8')
+    public_key_b64 = base64.b64encode(public_key_bytes).decode('utf-8')
+    return signing_key, public_key_b64, private_key_b64
+
+
+def proof_of_possession(signing_key: SigningKey, agent_name: str, public_key_b64: str) -> str:
+    """
+    Prove that the client holds the private key it registers.
+
+    Signs AIM's proof-of-possession message, which binds the public key to the agent's name:
+    "aim-proof-of-possession:v1", the agent's name and the base64 public key, one per line.
+
+    Returns:
+        Base64 Ed25519 signature to send as proofOfPossession / proof_of_possession
+    """
+    message = f"aim-proof-of-possession:v1\n{agent_name}\n{public_key_b64}".encode('utf-8')
+    return base64.b64encode(signing_key.sign(message).signature).decode('utf-8')
+
+
 def _get_credentials_path():
     """Get path to credentials file (~/.aim/credentials.json)."""
     home = pathlib.Path.home()
@@ -2033,16 +2069,11 @@ def _register_via_oauth(
 ) -> AIMClient:
     """Register agent using OAuth token from SDK credentials"""
     # Generate Ed25519 keypair client-side (for OAuth mode)
-    
-    signing_key = SigningKey.generate()
-    private_key_bytes = bytes(signing_key) + bytes(signing_key.verify_key)  # 64 bytes (seed + public)
-    public_key_bytes = bytes(signing_key.verify_key)
-
-    private_key_b64 = base64.b64encode(private_key_bytes).decode('utf-8')
-    public_key_b64 = base64.b64encode(public_key_bytes).decode('utf-8')
+    signing_key, public_key_b64, private_key_b64 = generate_agent_keypair()
 
-    # Add public key to registration data (use camelCase)
+    # Add public key and proof of possession to registration data (use camelCase)
     registration_data["publicKey"] = public_key_b64
+    registration_data["proofOfPossession"] = proof_of_possession(signing_key, name, public_key_b64)
 
 
     # Initialize OAuth token manager - let it discover credentials automatically
@@ -2140,6 +2171,12 @@ def _register_via_api_key(
     talks_to: Optional[List[str]]
 ) -> AIMClient:
     """Register agent using API key (manual mode)"""
+    # Generate Ed25519 keypair client-side; AIM only receives the public key and the proof
+    # that we hold its private key (organizations that opt out of key escrow require both)
+    signing_key, public_key_b64, private_key_b64 = generate_agent_keypair()
+    registration_data["public_key"] = public_key_b64
+    registration_data["proof_of_possession"] = proof_of_possession(signing_key, name, public_key_b64)
+
     # Call public registration endpoint
     url = f"{aim_url.rstrip('/')}/api/v1/public/agents/register"
 
@@ -2164,6 +2201,10 @@ def _register_via_api_key(
 
     credentials = response.json()
 
+    # The backend doesn't return a private key it never saw
+    credentials["private_key"] = private_key_b64
+    credentials["public_key"] = credentials.get("public_key") or public_key_b64
+
     # Save credentials locally
     _save_credentials(name, credentials)
 
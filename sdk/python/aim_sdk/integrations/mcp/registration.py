@@ -5,6 +5,9 @@ Helper functions for registering and managing MCP servers with AIM.
 """
 
 from typing import Any, Dict, List, Optional
+from urllib.parse import urlparse
+import ipaddress
+import socket
 import requests
 
 from aim_sdk.client import AIMClient
@@ -319,7 +322,9 @@ def attest_mcp_server(
     capabilities_found: List[str],
     connection_successful: bool = True,
     health_check_passed: bool = True,
-    connection_latency_ms: float = 0.0
+    connection_latency_ms: float = 0.0,
+    network_context: Optional[Dict[str, Any]] = None,
+    detect_network: bool = True
 ) -> Dict[str, Any]:
     """
     Submit cryptographically signed attestation for an MCP server.
@@ -337,6 +342,10 @@ def attest_mcp_server(
         connection_successful: Whether connection to MCP was successful (default: True)
         health_check_passed: Whether health check passed (default: True)
         connection_latency_ms: Connection latency in milliseconds (default: 0.0)
+        network_context: Evidence that the MCP server was reached over a private network
+            the backend cannot see, built with build_network_context (default: None)
+        detect_network: When no network_context is given, resolve mcp_url and send private
+            network evidence if it points at a private address (default: True)
 
     Returns:
         Dictionary containing attestation response:
@@ -345,6 +354,7 @@ def attest_mcp_server(
             "attestation_id": "attestation-uuid",
             "mcp_confidence_score": 85.5,
             "attestation_count": 3,
+            "agent_verified_only": False,
             ...
         }
 
@@ -377,9 +387,17 @@ def attest_mcp_server(
     if not server_id:
         raise ValueError("server_id cannot be empty")
 
+    # Fetch a single-use nonce; the backend rejects attestations without one
+    # so a captured signed attestation cannot be replayed
+    nonce_response = aim_client._make_request(
+        method="POST",
+        endpoint="/api/v1/mcp-servers/attestation-nonce"
+    )
+
     # Build attestation payload
     attestation_data = {
         "agent_id": str(aim_client.agent_id),
+        "nonce": nonce_response["nonce"],
         "mcp_url": mcp_url,
         "mcp_name": mcp_name,
         "capabilities_found": capabilities_found,
@@ -390,6 +408,13 @@ def attest_mcp_server(
         "sdk_version": "1.0.0"
     }
 
+    # Private network MCP servers can't be checked by the backend; the evidence is signed with
+    # the rest of the attestation, which is then tagged "agent-verified only"
+    if network_context is None and detect_network:
+        network_context = detect_network_context(mcp_url)
+    if network_context:
+        attestation_data["network_context"] = network_context
+
     # Sign the attestation data using the agent's Ed25519 private key
     # The signature is computed over the canonical JSON representation
     import json
@@ -418,3 +443,72 @@ def attest_mcp_server(
     )
 
     return response
+
+
+def build_network_context(
+    private_network: bool = True,
+    network_zone: Optional[str] = None,
+    relay_id: Optional[str] = None,
+    resolved_address: Optional[str] = None
+) -> Dict[str, Any]:
+    """
+    Build the network_context evidence of an attestation.
+
+    Empty values are left out, as the backend leaves them out of the canonical JSON the
+    signature is checked against.
+
+    Args:
+        private_network: Whether the MCP server was reached over a private network (default: True)
+        network_zone: VPC, datacenter or site the agent reached the server from
+        relay_id: ID of the relay record registering this agent for the server, if known
+        resolved_address: Address the agent connected to
+
+    Returns:
+        Dictionary to pass as attest_mcp_server's network_context
+
+    Example:
+        response = attest_mcp_server(
+            aim_client=aim_client,
+            server_id=server_id,
+            mcp_url="http://mcp.internal:3000",
+            mcp_name="inventory-mcp",
+            capabilities_found=["query_stock"],
+            network_context=build_network_context(network_zone="vpc-prod")
+        )
+    """
+    context: Dict[str, Any] = {"private_network": private_network}
+    if network_zone:
+        context["network_zone"] = network_zone
+    if relay_id:
+        context["relay_id"] = relay_id
+    if resolved_address:
+        context["resolved_address"] = resolved_address
+    return context
+
+
+def detect_network_context(mcp_url: str) -> Optional[Dict[str, Any]]:
+    """
+    Detect whether an MCP server URL points at a private network address.
+
+    Args:
+        mcp_url: URL of the MCP server
+
+    Returns:
+        Private network evidence for the attestation, or None when the host resolves to a
+        public address or can't be resolved
+    """
+    host = urlparse(mcp_url).hostname
+    if not host:
+        return None
+
+    try:
+        address = ipaddress.ip_address(host)
+    except ValueError:
+        try:
+            address = ipaddress.ip_address(socket.getaddrinfo(host, None)[0][4][0])
+        except (OSError, ValueError, IndexError):
+            return None
+
+    if address.is_private or address.is_loopback or address.is_link_local:
+        return build_network_context(resolved_address=str(address))
+    return None
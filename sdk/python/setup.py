@@ -45,7 +45,10 @@ setup(
             "black>=23.0.0",
             "flake8>=6.0.0",
             "mypy>=1.0.0",
-        ]
+        ],
+        "tracing": [
+            "opentelemetry-api>=1.20.0",
+        ],
     },
     keywords="aim agent identity management verification security cryptography ed25519",
     project_urls={
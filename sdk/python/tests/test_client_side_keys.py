@@ -0,0 +1,91 @@
+"""
+Tests for client-side key generation and proof-of-possession on agent registration
+"""
+
+import base64
+import json
+
+import pytest
+import responses
+from nacl.signing import VerifyKey
+
+from aim_sdk import AIMClient, generate_agent_keypair, proof_of_possession, register_agent
+
+AIM_URL = "https://aim.example.com"
+
+
+def proof_message(agent_name, public_key_b64):
+    return f"aim-proof-of-possession:v1\n{agent_name}\n{public_key_b64}".encode('utf-8')
+
+
+@pytest.fixture
+def saved_credentials(monkeypatch):
+    """Keep registrations from writing to ~/.aim"""
+    saved = {}
+    monkeypatch.setattr("aim_sdk.client._save_credentials", lambda name, creds: saved.update({name: creds}))
+    return saved
+
+
+def test_generate_agent_keypair():
+    signing_key, public_key_b64, private_key_b64 = generate_agent_keypair()
+
+    assert base64.b64decode(public_key_b64) == bytes(signing_key.verify_key)
+    private_key = base64.b64decode(private_key_b64)
+    assert len(private_key) == 64
+    assert private_key[:32] == bytes(signing_key)
+    assert private_key[32:] == bytes(signing_key.verify_key)
+
+    _, other_public_key, _ = generate_agent_keypair()
+    assert other_public_key != public_key_b64
+
+
+def test_proof_of_possession_signs_the_registration_message():
+    signing_key, public_key_b64, _ = generate_agent_keypair()
+    proof = proof_of_possession(signing_key, "billing-agent", public_key_b64)
+
+    verify_key = VerifyKey(base64.b64decode(public_key_b64))
+    verify_key.verify(proof_message("billing-agent", public_key_b64), base64.b64decode(proof))
+
+    # The proof is bound to the agent's name
+    with pytest.raises(Exception):
+        verify_key.verify(proof_message("other-agent", public_key_b64), base64.b64decode(proof))
+
+
+@responses.activate
+def test_api_key_registration_never_sends_the_private_key(saved_credentials):
+    responses.add(
+        responses.POST,
+        f"{AIM_URL}/api/v1/public/agents/register",
+        json={
+            "agent_id": "550e8400-e29b-41d4-a716-446655440000",
+            "name": "billing-agent",
+            "display_name": "billing-agent",
+            "aim_url": AIM_URL,
+            "status": "verified",
+            "trust_score": 75.0,
+        },
+        status=201,
+    )
+
+    client = register_agent(
+        "billing-agent",
+        aim_url=AIM_URL,
+        api_key="aim_test_key",
+        auto_detect=False,
+        force_new=True,
+        sdk_token_id=None,
+    )
+
+    body = json.loads(responses.calls[0].request.body)
+    assert "private_key" not in body and "privateKey" not in body
+    public_key_b64 = body["public_key"]
+    VerifyKey(base64.b64decode(public_key_b64)).verify(
+        proof_message("billing-agent", public_key_b64),
+        base64.b64decode(body["proof_of_possession"]),
+    )
+
+    # The client signs with the key pair it generated
+    assert isinstance(client, AIMClient)
+    assert client.public_key == public_key_b64
+    assert client.signing_key.verify_key.encode() == base64.b64decode(public_key_b64)
+    assert saved_credentials["billing-agent"]["private_key"]
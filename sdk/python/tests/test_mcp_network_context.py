@@ -0,0 +1,111 @@
+"""
+Tests for private network evidence (network_context) in MCP attestations
+"""
+
+import base64
+import json
+from unittest.mock import patch
+
+import pytest
+from nacl.signing import SigningKey
+
+from aim_sdk.integrations.mcp import (
+    attest_mcp_server,
+    build_network_context,
+    detect_network_context,
+)
+
+
+class FakeAIMClient:
+    """Records the requests attest_mcp_server makes instead of sending them"""
+
+    def __init__(self):
+        self.agent_id = "550e8400-e29b-41d4-a716-446655440000"
+        self.signing_key = SigningKey.generate()
+        self.requests = []
+
+    def _make_request(self, method, endpoint, data=None):
+        self.requests.append((method, endpoint, data))
+        if endpoint.endswith("/attestation-nonce"):
+            return {"nonce": "nonce-1"}
+        return {"success": True, "agent_verified_only": True}
+
+
+def submitted_attestation(client):
+    method, endpoint, payload = client.requests[-1]
+    assert (method, endpoint) == ("POST", "/api/v1/mcp-servers/server-1/attest")
+    return payload
+
+
+def test_build_network_context_leaves_out_empty_values():
+    assert build_network_context() == {"private_network": True}
+    assert build_network_context(network_zone="vpc-prod", relay_id="", resolved_address="10.0.0.7") == {
+        "private_network": True,
+        "network_zone": "vpc-prod",
+        "resolved_address": "10.0.0.7",
+    }
+
+
+@pytest.mark.parametrize("url, expected", [
+    ("http://10.0.4.2:3000/sse", {"private_network": True, "resolved_address": "10.0.4.2"}),
+    ("http://192.168.1.20", {"private_network": True, "resolved_address": "192.168.1.20"}),
+    ("http://[fd00::1]:8080", {"private_network": True, "resolved_address": "fd00::1"}),
+    ("http://127.0.0.1:3000", {"private_network": True, "resolved_address": "127.0.0.1"}),
+    ("https://8.8.8.8", None),
+    ("not a url", None),
+])
+def test_detect_network_context(url, expected):
+    assert detect_network_context(url) == expected
+
+
+def test_detect_network_context_resolves_host_names():
+    with patch("socket.getaddrinfo", return_value=[(2, 1, 6, "", ("10.20.0.5", 0))]):
+        assert detect_network_context("http://inventory.internal:3000") == {
+            "private_network": True,
+            "resolved_address": "10.20.0.5",
+        }
+    with patch("socket.getaddrinfo", side_effect=OSError("unknown host")):
+        assert detect_network_context("http://gone.internal") is None
+
+
+def test_attestation_sends_signed_network_context():
+    client = FakeAIMClient()
+    context = build_network_context(network_zone="vpc-prod", relay_id="relay-1")
+
+    response = attest_mcp_server(
+        aim_client=client,
+        server_id="server-1",
+        mcp_url="https://mcp.example.com",
+        mcp_name="inventory-mcp",
+        capabilities_found=["query_stock"],
+        network_context=context,
+    )
+
+    assert response["agent_verified_only"] is True
+    payload = submitted_attestation(client)
+    attestation = payload["attestation"]
+    assert attestation["network_context"] == context
+    assert attestation["nonce"] == "nonce-1"
+
+    # The evidence is covered by the signature
+    canonical = json.dumps(attestation, sort_keys=True, separators=(',', ':')).encode('utf-8')
+    client.signing_key.verify_key.verify(canonical, base64.b64decode(payload["signature"]))
+
+
+def test_attestation_detects_private_network():
+    client = FakeAIMClient()
+    attest_mcp_server(client, "server-1", "http://10.0.4.2:3000", "inventory-mcp", ["query_stock"])
+    assert submitted_attestation(client)["attestation"]["network_context"] == {
+        "private_network": True,
+        "resolved_address": "10.0.4.2",
+    }
+
+
+def test_attestation_of_public_server_has_no_network_context():
+    client = FakeAIMClient()
+    attest_mcp_server(client, "server-1", "https://8.8.8.8", "public-mcp", ["search"])
+    assert "network_context" not in submitted_attestation(client)["attestation"]
+
+    client = FakeAIMClient()
+    attest_mcp_server(client, "server-1", "http://10.0.4.2:3000", "inventory-mcp", ["query_stock"], detect_network=False)
+    assert "network_context" not in submitted_attestation(client)["attestation"]